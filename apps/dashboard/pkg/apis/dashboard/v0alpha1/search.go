@@ -73,6 +73,8 @@ type DashboardHit struct {
 	Score float64 `json:"score,omitempty"`
 	// Explain the score (if possible)
 	Explain *common.Unstructured `json:"explain,omitempty"`
+	// Fragments of the matched text by field, with the matched terms marked (when searching with a query)
+	Highlight *common.Unstructured `json:"highlight,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -84,6 +84,10 @@ func (in *DashboardHit) DeepCopyInto(out *DashboardHit) {
 		in, out := &in.Explain, &out.Explain
 		*out = (*in).DeepCopy()
 	}
+	if in.Highlight != nil {
+		in, out := &in.Highlight, &out.Highlight
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
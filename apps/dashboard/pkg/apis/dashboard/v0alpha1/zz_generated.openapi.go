@@ -330,6 +330,12 @@ func schema_pkg_apis_dashboard_v0alpha1_DashboardHit(ref common.ReferenceCallbac
 							Ref:         ref("github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1.Unstructured"),
 						},
 					},
+					"highlight": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Fragments of the matched text by field, with the matched terms marked (when searching with a query)",
+							Ref:         ref("github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1.Unstructured"),
+						},
+					},
 				},
 				Required: []string{"resource", "name", "title"},
 			},
@@ -168,6 +168,8 @@ export interface CurrentUserDTO {
   permissions?: Record<string, boolean>;
   analytics: AnalyticsSettings;
   authenticatedBy: string;
+  /** Login of the user impersonating the signed in user */
+  impersonatedBy?: string;
 
   /** @deprecated Use theme instead */
   lightTheme: boolean;
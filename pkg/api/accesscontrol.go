@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginaccesscontrol"
 	"github.com/grafana/grafana/pkg/tsdb/grafanads"
@@ -644,6 +645,32 @@ func (hs *HTTPServer) declareFixedRoles() error {
 		Grants: []string{string(org.RoleViewer)},
 	}
 
+	liveChannelsSubscriberRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Name:        "fixed:live.channels:subscriber",
+			DisplayName: "Channel subscriber",
+			Description: "Subscribe to all Grafana Live channels of scopes using the rbac authorization policy.",
+			Group:       "Live",
+			Permissions: []ac.Permission{
+				{Action: live.ActionChannelsSubscribe, Scope: live.ScopeChannelsAll},
+			},
+		},
+		Grants: []string{string(org.RoleViewer)},
+	}
+
+	liveChannelsPublisherRole := ac.RoleRegistration{
+		Role: ac.RoleDTO{
+			Name:        "fixed:live.channels:publisher",
+			DisplayName: "Channel publisher",
+			Description: "Publish to all Grafana Live channels of scopes using the rbac authorization policy.",
+			Group:       "Live",
+			Permissions: []ac.Permission{
+				{Action: live.ActionChannelsPublish, Scope: live.ScopeChannelsAll},
+			},
+		},
+		Grants: []string{string(org.RoleEditor)},
+	}
+
 	roles := []ac.RoleRegistration{provisioningWriterRole, datasourcesReaderRole, builtInDatasourceReader, datasourcesWriterRole,
 		datasourcesIdReaderRole, datasourcesCreatorRole, orgReaderRole, orgWriterRole,
 		orgMaintainerRole, teamsCreatorRole, teamsWriterRole, teamsReaderRole, datasourcesExplorerRole,
@@ -652,7 +679,7 @@ func (hs *HTTPServer) declareFixedRoles() error {
 		foldersCreatorRole, foldersReaderRole, generalFolderReaderRole, foldersWriterRole, apikeyReaderRole, apikeyWriterRole,
 		publicDashboardsWriterRole, featuremgmtReaderRole, featuremgmtWriterRole, libraryPanelsCreatorRole,
 		libraryPanelsReaderRole, libraryPanelsWriterRole, libraryPanelsGeneralReaderRole, libraryPanelsGeneralWriterRole,
-		snapshotsCreatorRole, snapshotsDeleterRole, snapshotsReaderRole}
+		snapshotsCreatorRole, snapshotsDeleterRole, snapshotsReaderRole, liveChannelsSubscriberRole, liveChannelsPublisherRole}
 
 	if hs.Features.IsEnabled(context.Background(), featuremgmt.FlagAnnotationPermissionUpdate) {
 		allAnnotationsReaderRole := ac.RoleRegistration{
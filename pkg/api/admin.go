@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/stats"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -74,6 +75,36 @@ func (hs *HTTPServer) AdminGetStats(c *contextmodel.ReqContext) response.Respons
 	return response.JSON(http.StatusOK, adminStats)
 }
 
+// swagger:route GET /admin/stats/db admin adminGetDatabaseStats
+//
+// Fetch the database query statistics.
+//
+// Returns the store functions running the most expensive queries since Grafana started. The statistics are recorded when `instrument_queries` is enabled or `slow_query_threshold` is set in the `[database]` section.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `server:stats:read`.
+//
+// Responses:
+// 200: adminGetDatabaseStatsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminGetDatabaseStats(c *contextmodel.ReqContext) response.Response {
+	provider, ok := hs.SQLStore.(sqlstore.QueryStatsProvider)
+	if !ok {
+		return response.Error(http.StatusNotImplemented, "Database query statistics are not supported", nil)
+	}
+
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = 10
+	}
+	calls, err := provider.QueryStats(limit, c.Query("sort"))
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get database query statistics", err)
+	}
+	return response.JSON(http.StatusOK, calls)
+}
+
 func (hs *HTTPServer) getAuthorizedSettings(ctx context.Context, user identity.Requester, bag setting.SettingsBag) (setting.SettingsBag, error) {
 	eval := func(scope string) (bool, error) {
 		return hs.AccessControl.Evaluate(ctx, user, ac.EvalPermission(ac.ActionSettingsRead, scope))
@@ -171,3 +202,23 @@ type GetStatsResponse struct {
 	// in:body
 	Body stats.AdminStats `json:"body"`
 }
+
+// swagger:parameters adminGetDatabaseStats
+type AdminGetDatabaseStatsParams struct {
+	// Maximum number of store functions to return
+	// in:query
+	// required:false
+	// default:10
+	Limit int64 `json:"limit"`
+	// Order of the store functions: total, max, count, rows or slow
+	// in:query
+	// required:false
+	// default:total
+	Sort string `json:"sort"`
+}
+
+// swagger:response adminGetDatabaseStatsResponse
+type GetDatabaseStatsResponse struct {
+	// in:body
+	Body []sqlstore.CallStats `json:"body"`
+}
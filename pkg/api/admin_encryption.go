@@ -1,11 +1,13 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/secrets"
 )
 
 func (hs *HTTPServer) AdminRotateDataEncryptionKeys(c *contextmodel.ReqContext) response.Response {
@@ -49,3 +51,58 @@ func (hs *HTTPServer) AdminRollbackSecrets(c *contextmodel.ReqContext) response.
 
 	return response.Respond(http.StatusOK, "Secrets rolled back successfully")
 }
+
+func (hs *HTTPServer) AdminStartSecretsRotation(c *contextmodel.ReqContext) response.Response {
+	job, err := hs.SecretsMigrator.StartRotationJob(c.Req.Context())
+	if err != nil {
+		return secretsRotationErrorResponse("Failed to start the secrets rotation", err)
+	}
+
+	return response.JSON(http.StatusAccepted, job)
+}
+
+func (hs *HTTPServer) AdminGetSecretsRotationStatus(c *contextmodel.ReqContext) response.Response {
+	job, err := hs.SecretsMigrator.GetRotationJob(c.Req.Context())
+	if err != nil {
+		return secretsRotationErrorResponse("Failed to get the secrets rotation status", err)
+	}
+
+	return response.JSON(http.StatusOK, job)
+}
+
+func (hs *HTTPServer) AdminResumeSecretsRotation(c *contextmodel.ReqContext) response.Response {
+	job, err := hs.SecretsMigrator.ResumeRotationJob(c.Req.Context())
+	if err != nil {
+		return secretsRotationErrorResponse("Failed to resume the secrets rotation", err)
+	}
+
+	return response.JSON(http.StatusAccepted, job)
+}
+
+func (hs *HTTPServer) AdminRollBackSecretsRotation(c *contextmodel.ReqContext) response.Response {
+	job, err := hs.SecretsMigrator.RollBackRotationJob(c.Req.Context())
+	if err != nil {
+		return secretsRotationErrorResponse("Failed to roll back the secrets rotation", err)
+	}
+
+	return response.JSON(http.StatusAccepted, job)
+}
+
+func (hs *HTTPServer) AdminCutOverSecretsRotation(c *contextmodel.ReqContext) response.Response {
+	job, err := hs.SecretsMigrator.CutOverRotationJob(c.Req.Context())
+	if err != nil {
+		return secretsRotationErrorResponse("Failed to cut over the secrets rotation", err)
+	}
+
+	return response.JSON(http.StatusOK, job)
+}
+
+func secretsRotationErrorResponse(message string, err error) response.Response {
+	switch {
+	case errors.Is(err, secrets.ErrRotationJobNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, secrets.ErrRotationJobInvalidState):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	defaultLogLevelDuration = 10 * time.Minute
+	maxLogLevelDuration     = 24 * time.Hour
+	defaultLogEntriesLimit  = 100
+	maxLogEntriesLimit      = 1000
+)
+
+// swagger:route GET /admin/logging/levels admin adminGetLogLevels
+//
+// Fetch the log levels set at runtime.
+//
+// Returns the names of the loggers and the levels set at runtime with their expiration.
+// Only works with Basic Authentication (username and password). See introduction for an explanation.
+//
+// Responses:
+// 200: adminGetLogLevelsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+func (hs *HTTPServer) AdminGetLogLevels(c *contextmodel.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, LogLevels{
+		Loggers:   log.LoggerNames(),
+		Overrides: log.LevelOverrides(),
+	})
+}
+
+// swagger:route PUT /admin/logging/levels/{logger} admin adminSetLogLevel
+//
+// Set the level of a logger.
+//
+// The level takes precedence over the level and the filters of the configuration of all the log modes, the configured level is restored after the duration. The duration defaults to 10 minutes and is limited to 24 hours.
+// Only works with Basic Authentication (username and password). See introduction for an explanation.
+//
+// Responses:
+// 200: adminSetLogLevelResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+func (hs *HTTPServer) AdminSetLogLevel(c *contextmodel.ReqContext) response.Response {
+	cmd := SetLogLevelCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	duration := defaultLogLevelDuration
+	if cmd.Duration != "" {
+		var err error
+		if duration, err = time.ParseDuration(cmd.Duration); err != nil || duration <= 0 {
+			return response.Error(http.StatusBadRequest, "The duration must be a positive duration such as 10m", err)
+		}
+	}
+	if duration > maxLogLevelDuration {
+		return response.Error(http.StatusBadRequest, "The duration must not exceed 24h", nil)
+	}
+
+	override, err := log.SetLevelOverride(web.Params(c.Req)[":logger"], cmd.Level, duration)
+	if errors.Is(err, log.ErrUnknownLevel) {
+		return response.Error(http.StatusBadRequest, "The level must be one of debug, info, warn or error", err)
+	}
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to set the log level", err)
+	}
+
+	hs.log.Info("Log level set", "logger", override.Logger, "level", override.Level, "expires", *override.Expires, "userID", c.UserID)
+	return response.JSON(http.StatusOK, override)
+}
+
+// swagger:route DELETE /admin/logging/levels/{logger} admin adminResetLogLevel
+//
+// Restore the configured level of a logger.
+//
+// Only works with Basic Authentication (username and password). See introduction for an explanation.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+func (hs *HTTPServer) AdminResetLogLevel(c *contextmodel.ReqContext) response.Response {
+	logger := web.Params(c.Req)[":logger"]
+	if !log.RemoveLevelOverride(logger) {
+		return response.Error(http.StatusNotFound, "The level of the logger isn't overridden", nil)
+	}
+
+	hs.log.Info("Log level restored", "logger", logger, "userID", c.UserID)
+	return response.Success("Log level restored")
+}
+
+// swagger:route GET /admin/logging/entries admin adminGetLogEntries
+//
+// Fetch the recent log entries.
+//
+// Returns the log entries kept in memory, oldest first. Pass the `lastId` of the response as the `after` parameter of the next request to get the entries logged since. The entries are kept when the `[log.memory]` mode is enabled.
+// Only works with Basic Authentication (username and password). See introduction for an explanation.
+//
+// Responses:
+// 200: adminGetLogEntriesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 501: internalServerError
+func (hs *HTTPServer) AdminGetLogEntries(c *contextmodel.ReqContext) response.Response {
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = defaultLogEntriesLimit
+	}
+	after := c.QueryInt64("after")
+
+	entries, enabled := log.RecentEntries(log.EntryQuery{
+		After:  after,
+		Level:  c.Query("level"),
+		Logger: c.Query("logger"),
+		Query:  c.Query("query"),
+		Limit:  min(limit, maxLogEntriesLimit),
+	})
+	if !enabled {
+		return response.Error(http.StatusNotImplemented, "The in-memory log is disabled", nil)
+	}
+
+	result := LogEntries{Entries: entries, LastID: after}
+	if len(entries) > 0 {
+		result.LastID = entries[len(entries)-1].ID
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// LogLevels are the loggers and the levels set at runtime
+type LogLevels struct {
+	Loggers   []string            `json:"loggers"`
+	Overrides []log.LevelOverride `json:"overrides"`
+}
+
+// SetLogLevelCommand sets the level of a logger
+type SetLogLevelCommand struct {
+	// Level is one of debug, info, warn or error
+	Level string `json:"level" binding:"Required"`
+	// Duration is how long the level is kept, such as 10m
+	Duration string `json:"duration"`
+}
+
+// LogEntries are the recent log entries
+type LogEntries struct {
+	Entries []log.Entry `json:"entries"`
+	// LastID is the cursor to get the entries logged since
+	LastID int64 `json:"lastId"`
+}
+
+// swagger:response adminGetLogLevelsResponse
+type AdminGetLogLevelsResponse struct {
+	// in:body
+	Body LogLevels `json:"body"`
+}
+
+// swagger:parameters adminSetLogLevel
+type AdminSetLogLevelParams struct {
+	// in:body
+	// required:true
+	Body SetLogLevelCommand `json:"body"`
+	// in:path
+	// required:true
+	Logger string `json:"logger"`
+}
+
+// swagger:response adminSetLogLevelResponse
+type AdminSetLogLevelResponse struct {
+	// in:body
+	Body log.LevelOverride `json:"body"`
+}
+
+// swagger:parameters adminResetLogLevel
+type AdminResetLogLevelParams struct {
+	// in:path
+	// required:true
+	Logger string `json:"logger"`
+}
+
+// swagger:parameters adminGetLogEntries
+type AdminGetLogEntriesParams struct {
+	// ID of the last entry already read
+	// in:query
+	// required:false
+	After int64 `json:"after"`
+	// Minimum level of the entries
+	// in:query
+	// required:false
+	Level string `json:"level"`
+	// Prefix of the logger name
+	// in:query
+	// required:false
+	Logger string `json:"logger"`
+	// Text the message or a field value contains
+	// in:query
+	// required:false
+	Query string `json:"query"`
+	// Maximum number of entries, the most recent ones are returned
+	// in:query
+	// required:false
+	// default:100
+	Limit int64 `json:"limit"`
+}
+
+// swagger:response adminGetLogEntriesResponse
+type AdminGetLogEntriesResponse struct {
+	// in:body
+	Body LogEntries `json:"body"`
+}
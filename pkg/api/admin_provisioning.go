@@ -6,7 +6,10 @@ import (
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/api/response"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/provisioning/plan"
+	"github.com/grafana/grafana/pkg/services/provisioning/status"
 )
 
 // swagger:route POST /admin/provisioning/dashboards/reload admin_provisioning adminProvisioningReloadDashboards
@@ -85,3 +88,138 @@ func (hs *HTTPServer) AdminProvisioningReloadAlerting(c *contextmodel.ReqContext
 	}
 	return response.Success("Alerting config reloaded")
 }
+
+// swagger:route POST /admin/provisioning/dashboards/plan admin_provisioning adminProvisioningPlanDashboards
+//
+// Plan dashboard provisioning configurations.
+//
+// Reads the provisioning config files for dashboards and returns what would be created, updated or deleted with a diff of the updated entities, without applying the changes.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `provisioning:reload` and scope `provisioners:dashboards`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminProvisioningPlanResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminProvisioningPlanDashboards(c *contextmodel.ReqContext) response.Response {
+	return provisioningPlanResponse(hs.ProvisioningService.PlanDashboards(c.Req.Context()))
+}
+
+// swagger:route POST /admin/provisioning/datasources/plan admin_provisioning adminProvisioningPlanDatasources
+//
+// Plan datasource provisioning configurations.
+//
+// Reads the provisioning config files for datasources and returns what would be created, updated or deleted with a diff of the updated entities, without applying the changes.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `provisioning:reload` and scope `provisioners:datasources`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminProvisioningPlanResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminProvisioningPlanDatasources(c *contextmodel.ReqContext) response.Response {
+	return provisioningPlanResponse(hs.ProvisioningService.PlanDatasources(c.Req.Context()))
+}
+
+// swagger:route POST /admin/provisioning/plugins/plan admin_provisioning adminProvisioningPlanPlugins
+//
+// Plan plugin provisioning configurations.
+//
+// Reads the provisioning config files for plugins and returns what would be created, updated or deleted with a diff of the updated entities, without applying the changes.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `provisioning:reload` and scope `provisioners:plugins`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminProvisioningPlanResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminProvisioningPlanPlugins(c *contextmodel.ReqContext) response.Response {
+	return provisioningPlanResponse(hs.ProvisioningService.PlanPlugins(c.Req.Context()))
+}
+
+// swagger:route POST /admin/provisioning/alerting/plan admin_provisioning adminProvisioningPlanAlerting
+//
+// Plan alerting provisioning configurations.
+//
+// Reads the provisioning config files for alerting and returns what would be created, updated or deleted with a diff of the updated entities, without applying the changes.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `provisioning:reload` and scope `provisioners:alerting`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminProvisioningPlanResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminProvisioningPlanAlerting(c *contextmodel.ReqContext) response.Response {
+	return provisioningPlanResponse(hs.ProvisioningService.PlanAlerting(c.Req.Context()))
+}
+
+func provisioningPlanResponse(p *plan.Plan, err error) response.Response {
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to plan provisioning", err)
+	}
+	return response.JSON(http.StatusOK, p)
+}
+
+// swagger:response adminProvisioningPlanResponse
+type AdminProvisioningPlanResponse struct {
+	// in: body
+	Body plan.Plan `json:"body"`
+}
+
+// swagger:route GET /admin/provisioning/status admin_provisioning adminProvisioningStatus
+//
+// Get the provisioning status.
+//
+// Returns the last run time, the applied resources and the error of the last run of each provisioner. The errors of the provisioning files include the file and the line of the error when they are known.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `provisioning:reload` and scope `provisioners:*`, only the provisioners of the scopes of your permissions are returned.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminProvisioningStatusResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminProvisioningStatus(c *contextmodel.ReqContext) response.Response {
+	statuses, err := hs.ProvisioningService.GetStatus(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get the provisioning status", err)
+	}
+
+	scopes := map[string]string{
+		"dashboards":  ScopeProvisionersDashboards,
+		"datasources": ScopeProvisionersDatasources,
+		"plugins":     ScopeProvisionersPlugins,
+		"alerting":    ScopeProvisionersAlertRules,
+	}
+	allowed := make([]status.Status, 0, len(statuses))
+	for _, s := range statuses {
+		ok, err := hs.AccessControl.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalPermission(ActionProvisioningReload, scopes[s.Provisioner]))
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+		}
+		if ok {
+			allowed = append(allowed, s)
+		}
+	}
+	return response.JSON(http.StatusOK, allowed)
+}
+
+// swagger:response adminProvisioningStatusResponse
+type AdminProvisioningStatusResponse struct {
+	// in: body
+	Body []status.Status `json:"body"`
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"testing"
@@ -11,6 +12,8 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/services/provisioning/plan"
+	"github.com/grafana/grafana/pkg/services/provisioning/status"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web/webtest"
 )
@@ -136,6 +139,33 @@ func TestAPI_AdminProvisioningReload_AccessControl(t *testing.T) {
 			expectedCode: http.StatusForbidden,
 			url:          "/api/admin/provisioning/alerting/reload",
 		},
+		{
+			desc:         "should plan datasources with specific scope",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"provisioner":"datasources","changes":[]}`,
+			permissions: []accesscontrol.Permission{
+				{
+					Action: ActionProvisioningReload,
+					Scope:  ScopeProvisionersDatasources,
+				},
+			},
+			url: "/api/admin/provisioning/datasources/plan",
+			checkCall: func(mock provisioning.ProvisioningServiceMock) {
+				assert.Len(t, mock.Calls.PlanDatasources, 1)
+				assert.Len(t, mock.Calls.ProvisionDatasources, 0)
+			},
+		},
+		{
+			desc:         "should fail to plan dashboards with wrong scope",
+			expectedCode: http.StatusForbidden,
+			permissions: []accesscontrol.Permission{
+				{
+					Action: ActionProvisioningReload,
+					Scope:  ScopeProvisionersDatasources,
+				},
+			},
+			url: "/api/admin/provisioning/dashboards/plan",
+		},
 	}
 
 	for _, tt := range tests {
@@ -165,3 +195,39 @@ func TestAPI_AdminProvisioningReload_AccessControl(t *testing.T) {
 		})
 	}
 }
+
+func TestAPI_AdminProvisioningStatus(t *testing.T) {
+	pService := provisioning.NewProvisioningServiceMock(context.Background())
+	pService.GetStatusFunc = func() ([]status.Status, error) {
+		return []status.Status{
+			{Provisioner: "dashboards", Success: true, Resources: []plan.Change{}},
+			{Provisioner: "datasources", Error: "yaml: line 3: did not find expected key", File: "datasources.yaml", Line: 3, Resources: []plan.Change{}},
+		}, nil
+	}
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.Cfg = setting.NewCfg()
+		hs.ProvisioningService = pService
+	})
+
+	t.Run("should only return the provisioners of the scopes of the user", func(t *testing.T) {
+		permissions := []accesscontrol.Permission{{Action: ActionProvisioningReload, Scope: ScopeProvisionersDatasources}}
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/admin/provisioning/status"), userWithPermissions(1, permissions)))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var statuses []status.Status
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&statuses))
+		require.NoError(t, res.Body.Close())
+		require.Len(t, statuses, 1)
+		assert.Equal(t, "datasources", statuses[0].Provisioner)
+		assert.Equal(t, "datasources.yaml", statuses[0].File)
+		assert.Equal(t, 3, statuses[0].Line)
+	})
+
+	t.Run("should fail without permission", func(t *testing.T) {
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/admin/provisioning/status"), userWithPermissions(1, nil)))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+)
+
+const maxSnapshotsPerPage = 1000
+
+// swagger:route GET /admin/snapshots admin adminSearchSnapshots
+//
+// Search the snapshots of all the organizations.
+//
+// Returns the snapshots matching all the filters, the oldest first.
+// Only works with Basic Authentication (username and password). See introduction for an explanation.
+//
+// Responses:
+// 200: adminSearchSnapshotsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminSearchSnapshots(c *contextmodel.ReqContext) response.Response {
+	filter, errRsp := snapshotsFilter(c)
+	if errRsp != nil {
+		return errRsp
+	}
+	perPage := c.QueryInt("perpage")
+	if perPage <= 0 || perPage > maxSnapshotsPerPage {
+		perPage = maxSnapshotsPerPage
+	}
+
+	result, err := hs.dashboardsnapshotsService.FindSnapshots(c.Req.Context(), &dashboardsnapshots.FindSnapshotsQuery{
+		SnapshotsFilter: filter,
+		Page:            c.QueryInt("page"),
+		PerPage:         perPage,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to search snapshots", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route DELETE /admin/snapshots admin adminDeleteSnapshots
+//
+// Delete the snapshots of all the organizations.
+//
+// Deletes the snapshots matching all the filters, olderThan or userId must be set. The external snapshots are only deleted from this instance.
+// Only works with Basic Authentication (username and password). See introduction for an explanation.
+//
+// Responses:
+// 200: adminDeleteSnapshotsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminDeleteSnapshots(c *contextmodel.ReqContext) response.Response {
+	filter, errRsp := snapshotsFilter(c)
+	if errRsp != nil {
+		return errRsp
+	}
+	if filter.CreatedBefore.IsZero() && filter.UserID == 0 {
+		return response.Error(http.StatusBadRequest, "olderThan or userId must be set", nil)
+	}
+
+	cmd := &dashboardsnapshots.DeleteSnapshotsCommand{SnapshotsFilter: filter}
+	if err := hs.dashboardsnapshotsService.DeleteSnapshots(c.Req.Context(), cmd); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to delete snapshots", err)
+	}
+
+	hs.log.Info("Snapshots deleted", "orgID", filter.OrgID, "userID", filter.UserID, "createdBefore", filter.CreatedBefore, "count", cmd.DeletedRows, "adminID", c.UserID)
+	return response.JSON(http.StatusOK, DeleteSnapshotsResult{Message: "Snapshots deleted", DeletedCount: cmd.DeletedRows})
+}
+
+func snapshotsFilter(c *contextmodel.ReqContext) (dashboardsnapshots.SnapshotsFilter, response.Response) {
+	filter := dashboardsnapshots.SnapshotsFilter{
+		OrgID:  c.QueryInt64("orgId"),
+		UserID: c.QueryInt64("userId"),
+	}
+	if olderThan := c.Query("olderThan"); olderThan != "" {
+		age, err := gtime.ParseDuration(olderThan)
+		if err != nil || age <= 0 {
+			return filter, response.Error(http.StatusBadRequest, "olderThan must be a positive duration such as 30d", err)
+		}
+		filter.CreatedBefore = time.Now().Add(-age)
+	}
+	return filter, nil
+}
+
+// swagger:model
+type DeleteSnapshotsResult struct {
+	Message      string `json:"message"`
+	DeletedCount int64  `json:"deletedCount"`
+}
+
+// swagger:parameters adminSearchSnapshots
+type AdminSearchSnapshotsParams struct {
+	AdminDeleteSnapshotsParams
+	// in:query
+	// required:false
+	// default:1
+	Page int `json:"page"`
+	// in:query
+	// required:false
+	// default:1000
+	PerPage int `json:"perpage"`
+}
+
+// swagger:parameters adminDeleteSnapshots
+type AdminDeleteSnapshotsParams struct {
+	// Only the snapshots of this organization
+	// in:query
+	// required:false
+	OrgID int64 `json:"orgId"`
+	// Only the snapshots created by this user
+	// in:query
+	// required:false
+	UserID int64 `json:"userId"`
+	// Only the snapshots created before this duration, such as 30d
+	// in:query
+	// required:false
+	OlderThan string `json:"olderThan"`
+}
+
+// swagger:response adminSearchSnapshotsResponse
+type AdminSearchSnapshotsResponse struct {
+	// in:body
+	Body dashboardsnapshots.FindSnapshotsResult `json:"body"`
+}
+
+// swagger:response adminDeleteSnapshotsResponse
+type AdminDeleteSnapshotsResponse struct {
+	// in:body
+	Body DeleteSnapshotsResult `json:"body"`
+}
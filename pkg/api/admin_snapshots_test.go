@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestAPI_AdminDeleteSnapshots(t *testing.T) {
+	admin := &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleAdmin, IsGrafanaAdmin: true}
+	setup := func(t *testing.T) (*webtest.Server, *dashboardsnapshots.MockService) {
+		svc := dashboardsnapshots.NewMockService(t)
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.Cfg = setting.NewCfg()
+			hs.dashboardsnapshotsService = svc
+		})
+		return server, svc
+	}
+
+	t.Run("should delete the snapshots of the filter", func(t *testing.T) {
+		server, svc := setup(t)
+		svc.On("DeleteSnapshots", mock.Anything, mock.MatchedBy(func(cmd *dashboardsnapshots.DeleteSnapshotsCommand) bool {
+			return cmd.OrgID == 2 && cmd.UserID == 0 && !cmd.CreatedBefore.IsZero()
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*dashboardsnapshots.DeleteSnapshotsCommand).DeletedRows = 3
+		}).Return(nil)
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewRequest(http.MethodDelete, "/api/admin/snapshots?orgId=2&olderThan=30d", nil), admin))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("should require olderThan or userId", func(t *testing.T) {
+		server, _ := setup(t)
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewRequest(http.MethodDelete, "/api/admin/snapshots?orgId=2", nil), admin))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("should reject an invalid olderThan", func(t *testing.T) {
+		server, _ := setup(t)
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewRequest(http.MethodDelete, "/api/admin/snapshots?olderThan=soon", nil), admin))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("should be forbidden for the users who aren't server admins", func(t *testing.T) {
+		server, _ := setup(t)
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewRequest(http.MethodDelete, "/api/admin/snapshots?olderThan=30d", nil),
+			&user.SignedInUser{UserID: 2, OrgID: 1, OrgRole: org.RoleAdmin}))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+}
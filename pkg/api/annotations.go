@@ -18,22 +18,66 @@ import (
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/pagination"
 	"github.com/grafana/grafana/pkg/web"
 )
 
-const defaultAnnotationsLimit = 100
+const (
+	defaultAnnotationsLimit = 100
+	maxAnnotationsLimit     = 5000
+)
 
 // swagger:route GET /annotations annotations getAnnotations
 //
 // Find Annotations.
 //
 // Starting in Grafana v6.4 regions annotations are now returned in one entity that now includes the timeEnd property.
+// The scope parameters select the annotations of any of several dashboards or panels of dashboards, and the region type only the region annotations.
 //
 // Responses:
 // 200: getAnnotationsResponse
 // 401: unauthorisedError
 // 500: internalServerError
 func (hs *HTTPServer) GetAnnotations(c *contextmodel.ReqContext) response.Response {
+	paging, err := pagination.FromQuery(c.Req.URL.Query(), defaultAnnotationsLimit, maxAnnotationsLimit)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	query, errRsp := hs.annotationsQuery(c)
+	if errRsp != nil {
+		return errRsp
+	}
+	query.Limit = int64(paging.Limit)
+	query.After = paging.After
+
+	items, err := hs.annotationsRepo.Find(c.Req.Context(), query)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get annotations", err)
+	}
+
+	for _, item := range items {
+		if item.Email != "" {
+			item.AvatarURL = dtos.GetGravatarUrl(hs.Cfg, item.Email)
+		}
+	}
+
+	// the limit of the query is lowered when the user can't read all the dashboards, the page is full with the
+	// requested limit
+	rsp := response.JSON(http.StatusOK, items)
+	if next := pagination.Next(len(items), paging.Limit, func() []any { return items[len(items)-1].SortValues() }); next != nil {
+		rsp.SetHeader("Link", pagination.LinkHeader(c.Req.URL, next, paging.Limit))
+	}
+	return rsp
+}
+
+// annotationsQuery returns the query of the filters of the request, shared by the search and the export
+func (hs *HTTPServer) annotationsQuery(c *contextmodel.ReqContext) (*annotations.ItemQuery, response.Response) {
+	scopes, err := parseAnnotationScopes(c.QueryStrings("scope"))
+	if err != nil {
+		return nil, response.Error(http.StatusBadRequest, "Invalid scope in annotation request", err)
+	}
+
 	query := &annotations.ItemQuery{
 		From:         c.QueryInt64("from"),
 		To:           c.QueryInt64("to"),
@@ -44,22 +88,19 @@ func (hs *HTTPServer) GetAnnotations(c *contextmodel.ReqContext) response.Respon
 		DashboardID:  c.QueryInt64("dashboardId"),
 		DashboardUID: c.Query("dashboardUID"),
 		PanelID:      c.QueryInt64("panelId"),
-		Limit:        c.QueryInt64("limit"),
+		Scopes:       scopes,
 		Tags:         c.QueryStrings("tags"),
 		Type:         c.Query("type"),
 		MatchAny:     c.QueryBool("matchAny"),
 		SignedInUser: c.SignedInUser,
 	}
-	if query.Limit == 0 {
-		query.Limit = defaultAnnotationsLimit
-	}
 
 	// When dashboard UID present in the request, we ignore dashboard ID
 	if query.DashboardUID != "" {
 		dq := dashboards.GetDashboardQuery{UID: query.DashboardUID, OrgID: c.GetOrgID()}
 		dqResult, err := hs.DashboardService.GetDashboard(c.Req.Context(), &dq)
 		if err != nil {
-			return response.Error(http.StatusBadRequest, "Invalid dashboard UID in annotation request", err)
+			return nil, response.Error(http.StatusBadRequest, "Invalid dashboard UID in annotation request", err)
 		} else {
 			query.DashboardID = dqResult.ID // nolint:staticcheck
 		}
@@ -69,23 +110,33 @@ func (hs *HTTPServer) GetAnnotations(c *contextmodel.ReqContext) response.Respon
 		dq := dashboards.GetDashboardQuery{ID: query.DashboardID, OrgID: c.GetOrgID()} // nolint:staticcheck
 		dqResult, err := hs.DashboardService.GetDashboard(c.Req.Context(), &dq)
 		if err != nil {
-			return response.Error(http.StatusBadRequest, "Invalid dashboard ID in annotation request", err)
+			return nil, response.Error(http.StatusBadRequest, "Invalid dashboard ID in annotation request", err)
 		}
 		query.DashboardUID = dqResult.UID
 	}
 
-	items, err := hs.annotationsRepo.Find(c.Req.Context(), query)
-	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to get annotations", err)
-	}
+	return query, nil
+}
 
-	for _, item := range items {
-		if item.Email != "" {
-			item.AvatarURL = dtos.GetGravatarUrl(hs.Cfg, item.Email)
+// parseAnnotationScopes parses the scopes formatted as <dashboard UID> or <dashboard UID>:<panel ID>
+func parseAnnotationScopes(values []string) ([]annotations.Scope, error) {
+	scopes := make([]annotations.Scope, 0, len(values))
+	for _, value := range values {
+		dashboardUID, panel, hasPanel := strings.Cut(value, ":")
+		if dashboardUID == "" {
+			return nil, &AnnotationError{"scope should start with a dashboard UID"}
 		}
+		scope := annotations.Scope{DashboardUID: dashboardUID}
+		if hasPanel {
+			panelID, err := strconv.ParseInt(panel, 10, 64)
+			if err != nil || panelID <= 0 {
+				return nil, &AnnotationError{"scope should be formatted as <dashboard UID>:<panel ID>"}
+			}
+			scope.PanelID = panelID
+		}
+		scopes = append(scopes, scope)
 	}
-
-	return response.JSON(http.StatusOK, items)
+	return scopes, nil
 }
 
 type AnnotationError struct {
@@ -774,6 +825,12 @@ type GetAnnotationsParams struct {
 	// in:query
 	// required:false
 	PanelID int64 `json:"panelId"`
+	// Find annotations that are scoped to any of the dashboards or panels, formatted as `<dashboard UID>` or `<dashboard UID>:<panel ID>`. You can filter by multiple scopes.
+	// in:query
+	// required:false
+	// type: array
+	// collectionFormat: multi
+	Scope []string `json:"scope"`
 	// Max limit for results returned.
 	// in:query
 	// required:false
@@ -784,13 +841,14 @@ type GetAnnotationsParams struct {
 	// type: array
 	// collectionFormat: multi
 	Tags []string `json:"tags"`
-	// Return alerts or user created annotations
+	// Return alerts, user created annotations or region annotations
 	// in:query
 	// required:false
 	// Description:
 	// * `alert`
 	// * `annotation`
-	// enum: alert,annotation
+	// * `region`
+	// enum: alert,annotation,region
 	Type string `json:"type"`
 	// Match any or all tags
 	// in:query
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// maxBulkAnnotations is the maximum number of annotations created, updated and deleted by a bulk request
+const maxBulkAnnotations = 1000
+
+var (
+	errBulkAnnotationsInvalid   = errutil.BadRequest("annotations.bulk-invalid")
+	errBulkAnnotationsTooLarge  = errutil.BadRequest("annotations.bulk-too-large", errutil.WithPublicMessage("A bulk request can't have more than 1000 annotations"))
+	errBulkAnnotationsNotFound  = errutil.NotFound("annotations.bulk-not-found")
+	errBulkAnnotationsForbidden = errutil.Forbidden("annotations.bulk-forbidden")
+)
+
+// swagger:route POST /annotations/bulk annotations bulkAnnotations
+//
+// Create, update and delete annotations.
+//
+// Creates, updates and deletes up to 1000 annotations at once. The permissions are checked for each annotation like with the operations on a single annotation, and the request fails without changing any annotation when one of them can't be changed. The changes are saved in a single transaction.
+//
+// Responses:
+// 200: bulkAnnotationsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) BulkAnnotations(c *contextmodel.ReqContext) response.Response {
+	cmd := dtos.BulkAnnotationsCmd{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Err(errBulkAnnotationsInvalid.Errorf("bad request data: %w", err))
+	}
+	total := len(cmd.Create) + len(cmd.Update) + len(cmd.Delete)
+	if total == 0 {
+		return response.Err(errBulkAnnotationsInvalid.Errorf("the bulk request has no annotations"))
+	}
+	if total > maxBulkAnnotations {
+		return response.Err(errBulkAnnotationsTooLarge.Errorf("the bulk request has %d annotations", total))
+	}
+
+	// all the annotations are checked before any change, so that the request is applied entirely or not at all
+	userID, _ := identity.UserIdentifier(c.GetID())
+	creates := make([]annotations.Item, 0, len(cmd.Create))
+	for i, create := range cmd.Create {
+		if create.Text == "" {
+			return response.Err(errBulkAnnotationsInvalid.Errorf("create %d: text field should not be empty", i))
+		}
+		dashboardUID, dashboardID, err := hs.annotationDashboard(c, create.DashboardUID, create.DashboardId)
+		if err != nil {
+			return response.Err(errBulkAnnotationsInvalid.Errorf("create %d: %w", i, err))
+		}
+		canSave, err := hs.canCreateAnnotation(c, dashboardUID)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Error while checking annotation permissions", err)
+		}
+		if !canSave {
+			return response.Err(errBulkAnnotationsForbidden.Errorf("create %d: access denied to save the annotation", i))
+		}
+		creates = append(creates, annotations.Item{
+			OrgID:        c.GetOrgID(),
+			UserID:       userID,
+			DashboardID:  dashboardID,
+			DashboardUID: dashboardUID,
+			PanelID:      create.PanelId,
+			Epoch:        create.Time,
+			EpochEnd:     create.TimeEnd,
+			Text:         create.Text,
+			Data:         create.Data,
+			Tags:         create.Tags,
+		})
+	}
+
+	updates := make([]annotations.Item, 0, len(cmd.Update))
+	for i, update := range cmd.Update {
+		annotation, err := hs.bulkAnnotation(c, update.Id, accesscontrol.ActionAnnotationsWrite)
+		if err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "Error while checking annotation permissions", fmt.Errorf("update %d: %w", i, err))
+		}
+		item := annotations.Item{
+			OrgID:    c.GetOrgID(),
+			UserID:   userID,
+			ID:       update.Id,
+			Epoch:    update.Time,
+			EpochEnd: update.TimeEnd,
+			Text:     update.Text,
+			Tags:     update.Tags,
+			Data:     annotation.Data,
+		}
+		if update.Data != nil {
+			item.Data = update.Data
+		}
+		updates = append(updates, item)
+	}
+
+	for i, id := range cmd.Delete {
+		if _, err := hs.bulkAnnotation(c, id, accesscontrol.ActionAnnotationsDelete); err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "Error while checking annotation permissions", fmt.Errorf("delete %d: %w", i, err))
+		}
+	}
+
+	result := dtos.BulkAnnotationsResult{Created: make([]int64, 0, len(creates))}
+	err := hs.SQLStore.InTransaction(c.Req.Context(), func(ctx context.Context) error {
+		for i := range creates {
+			if err := hs.annotationsRepo.Save(ctx, &creates[i]); err != nil {
+				return err
+			}
+			result.Created = append(result.Created, creates[i].ID)
+		}
+		for i := range updates {
+			if err := hs.annotationsRepo.Update(ctx, &updates[i]); err != nil {
+				return err
+			}
+		}
+		for _, id := range cmd.Delete {
+			if err := hs.annotationsRepo.Delete(ctx, &annotations.DeleteParams{OrgID: c.GetOrgID(), ID: id}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, annotations.ErrTimerangeMissing) {
+			return response.Error(http.StatusBadRequest, "Failed to save annotations", err)
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to save annotations", err)
+	}
+
+	result.Updated = len(updates)
+	result.Deleted = len(cmd.Delete)
+	return response.JSON(http.StatusOK, result)
+}
+
+// annotationDashboard returns the UID and ID of the dashboard of an annotation given by UID or ID, they're empty for
+// the organization annotations
+func (hs *HTTPServer) annotationDashboard(c *contextmodel.ReqContext, dashboardUID string, dashboardID int64) (string, int64, error) {
+	if dashboardUID == "" && dashboardID == 0 {
+		return "", 0, nil
+	}
+	query := dashboards.GetDashboardQuery{OrgID: c.GetOrgID(), UID: dashboardUID}
+	if dashboardUID == "" {
+		query.ID = dashboardID // nolint:staticcheck
+	}
+	dashboard, err := hs.DashboardService.GetDashboard(c.Req.Context(), &query)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid dashboard: %w", err)
+	}
+	return dashboard.UID, dashboard.ID, nil
+}
+
+// bulkAnnotation returns the annotation of a bulk request once the user is allowed to do the action on it
+func (hs *HTTPServer) bulkAnnotation(c *contextmodel.ReqContext, annotationID int64, action string) (*annotations.ItemDTO, error) {
+	if annotationID <= 0 {
+		return nil, errBulkAnnotationsInvalid.Errorf("annotation ID is missing")
+	}
+	items, err := hs.annotationsRepo.Find(c.Req.Context(), &annotations.ItemQuery{
+		AnnotationID: annotationID,
+		OrgID:        c.GetOrgID(),
+		SignedInUser: c.SignedInUser,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, errBulkAnnotationsNotFound.Errorf("annotation %d not found", annotationID)
+	}
+
+	scope := accesscontrol.ScopeAnnotationsProvider.GetResourceScope(strconv.FormatInt(annotationID, 10))
+	canSave, err := hs.AccessControl.Evaluate(c.Req.Context(), c.SignedInUser, accesscontrol.EvalPermission(action, scope))
+	if err == nil && canSave && !hs.Features.IsEnabled(c.Req.Context(), featuremgmt.FlagAnnotationPermissionUpdate) {
+		canSave, err = hs.canSaveAnnotation(c, hs.AccessControl, items[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !canSave {
+		return nil, errBulkAnnotationsForbidden.Errorf("access denied to annotation %d", annotationID)
+	}
+	return items[0], nil
+}
+
+// swagger:parameters bulkAnnotations
+type BulkAnnotationsParams struct {
+	// in:body
+	// required:true
+	Body dtos.BulkAnnotationsCmd `json:"body"`
+}
+
+// swagger:response bulkAnnotationsResponse
+type BulkAnnotationsResponse struct {
+	// in: body
+	Body dtos.BulkAnnotationsResult `json:"body"`
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+var annotationsCSVHeader = []string{"id", "time", "timeEnd", "dashboardUID", "panelId", "alertName", "prevState", "newState", "text", "tags", "login"}
+
+// swagger:route GET /annotations/export annotations exportAnnotations
+//
+// Export Annotations.
+//
+// Exports the annotations matching the filters of the Find Annotations operation to a JSON or CSV file, the oldest first, to build incident timelines. Up to 5000 annotations are exported, the most recent ones.
+//
+// Responses:
+// 200: exportAnnotationsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+func (hs *HTTPServer) ExportAnnotations(c *contextmodel.ReqContext) response.Response {
+	format := c.Query("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		return response.Error(http.StatusBadRequest, "The format must be json or csv", nil)
+	}
+
+	query, errRsp := hs.annotationsQuery(c)
+	if errRsp != nil {
+		return errRsp
+	}
+	query.Limit = maxAnnotationsLimit
+	if limit := c.QueryInt64("limit"); limit > 0 && limit < maxAnnotationsLimit {
+		query.Limit = limit
+	}
+
+	items, err := hs.annotationsRepo.Find(c.Req.Context(), query)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get annotations", err)
+	}
+	// a timeline reads from the oldest annotation
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Time != items[j].Time {
+			return items[i].Time < items[j].Time
+		}
+		return items[i].ID < items[j].ID
+	})
+
+	filename := fmt.Sprintf("annotations-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	if format == "json" {
+		return response.JSONDownload(http.StatusOK, items, filename)
+	}
+
+	data, err := annotationsCSV(items)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to export annotations", err)
+	}
+	return response.Respond(http.StatusOK, data).
+		SetHeader("Content-Type", "text/csv; charset=utf-8").
+		SetHeader("Content-Disposition", fmt.Sprintf(`attachment;filename="%s"`, filename))
+}
+
+func annotationsCSV(items []*annotations.ItemDTO) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(annotationsCSVHeader); err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		dashboardUID := ""
+		if item.DashboardUID != nil {
+			dashboardUID = *item.DashboardUID
+		}
+		timeEnd := ""
+		if item.TimeEnd > item.Time {
+			timeEnd = formatAnnotationTime(item.TimeEnd)
+		}
+		record := []string{
+			strconv.FormatInt(item.ID, 10),
+			formatAnnotationTime(item.Time),
+			timeEnd,
+			dashboardUID,
+			strconv.FormatInt(item.PanelID, 10),
+			csvCell(item.AlertName),
+			item.PrevState,
+			item.NewState,
+			csvCell(item.Text),
+			csvCell(strings.Join(item.Tags, ",")),
+			csvCell(item.Login),
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func formatAnnotationTime(epochMs int64) string {
+	return time.UnixMilli(epochMs).UTC().Format(time.RFC3339)
+}
+
+// csvCell prevents the spreadsheets from reading the text of the users as a formula
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// swagger:parameters exportAnnotations
+type ExportAnnotationsParams struct {
+	GetAnnotationsParams
+	// Format of the exported file
+	// in:query
+	// required:false
+	// enum: json,csv
+	// default: json
+	Format string `json:"format"`
+}
+
+// swagger:response exportAnnotationsResponse
+type ExportAnnotationsResponse struct {
+	// in: body
+	Body []byte `json:"body"`
+}
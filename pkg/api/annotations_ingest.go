@@ -0,0 +1,59 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationingest"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route POST /annotations/ingest/{integration} annotations ingestAnnotation
+//
+// Ingest an event of an external system as an annotation.
+//
+// Maps an event posted by an external system, such as a GitHub deployment, a Jenkins build or a PagerDuty incident, to an organization annotation with the templates of the integration configured in the [annotations.ingest.<integration>] section. The request is authenticated by the HMAC-SHA256 signature of its body, keyed with the secret of the integration. The events with the same dedup key update the annotation of the first one.
+//
+// Responses:
+// 200: ingestAnnotationResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) IngestAnnotation(c *contextmodel.ReqContext) response.Response {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Resp, c.Req.Body, annotationingest.MaxBodySize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return response.Error(http.StatusRequestEntityTooLarge, "The event is too large", err)
+		}
+		return response.Error(http.StatusBadRequest, "Failed to read the event", err)
+	}
+
+	result, err := hs.annotationIngest.Ingest(c.Req.Context(), web.Params(c.Req)[":integration"], c.Req.Header, body)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to ingest the event", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:parameters ingestAnnotation
+type IngestAnnotationParams struct {
+	// Name of the integration
+	// in:path
+	// required:true
+	Integration string `json:"integration"`
+	// The event, as sent by the external system
+	// in:body
+	// required:true
+	Body map[string]any `json:"body"`
+}
+
+// swagger:response ingestAnnotationResponse
+type IngestAnnotationResponse struct {
+	// in: body
+	Body annotationingest.Result `json:"body"`
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/infra/db/dbtest"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
 	"github.com/grafana/grafana/pkg/services/annotations"
@@ -392,6 +393,68 @@ func TestAPI_Annotations(t *testing.T) {
 			expectedCode: http.StatusForbidden,
 			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionAnnotationsDelete, Scope: accesscontrol.ScopeAnnotationsTypeDashboard}},
 		},
+		{
+			desc:         "should be able to create organization annotations in bulk with correct permission",
+			path:         "/api/annotations/bulk",
+			body:         "{\"create\": [{\"text\": \"deploy\"}, {\"text\": \"rollback\"}]}",
+			method:       http.MethodPost,
+			expectedCode: http.StatusOK,
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionAnnotationsCreate, Scope: accesscontrol.ScopeAnnotationsTypeOrganization}},
+		},
+		{
+			desc:         "should not be able to create organization annotations in bulk without correct permission",
+			path:         "/api/annotations/bulk",
+			body:         "{\"create\": [{\"text\": \"deploy\"}]}",
+			method:       http.MethodPost,
+			expectedCode: http.StatusForbidden,
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionAnnotationsCreate, Scope: accesscontrol.ScopeAnnotationsTypeDashboard}},
+		},
+		{
+			desc:         "should not be able to update dashboard annotations in bulk without correct permission",
+			path:         "/api/annotations/bulk",
+			body:         "{\"update\": [{\"id\": 2, \"text\": \"updated\"}]}",
+			method:       http.MethodPost,
+			expectedCode: http.StatusForbidden,
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionAnnotationsWrite, Scope: accesscontrol.ScopeAnnotationsTypeOrganization}},
+		},
+		{
+			desc:         "should be able to delete dashboard annotations in bulk with correct dashboard scope with annotationPermissionUpdate enabled",
+			path:         "/api/annotations/bulk",
+			body:         "{\"delete\": [2]}",
+			method:       http.MethodPost,
+			featureFlags: []any{featuremgmt.FlagAnnotationPermissionUpdate},
+			expectedCode: http.StatusOK,
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionAnnotationsDelete, Scope: dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dashUID)}},
+		},
+		{
+			desc:         "should not be able to send an empty bulk request",
+			path:         "/api/annotations/bulk",
+			body:         "{}",
+			method:       http.MethodPost,
+			expectedCode: http.StatusBadRequest,
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionAnnotationsCreate, Scope: accesscontrol.ScopeAnnotationsTypeOrganization}},
+		},
+		{
+			desc:         "should be able to export annotations with correct permission",
+			path:         "/api/annotations/export?format=csv&scope=dashuid1:1",
+			method:       http.MethodGet,
+			expectedCode: http.StatusOK,
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionAnnotationsRead, Scope: accesscontrol.ScopeAnnotationsAll}},
+		},
+		{
+			desc:         "should not be able to export annotations in an unknown format",
+			path:         "/api/annotations/export?format=xml",
+			method:       http.MethodGet,
+			expectedCode: http.StatusBadRequest,
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionAnnotationsRead, Scope: accesscontrol.ScopeAnnotationsAll}},
+		},
+		{
+			desc:         "should not be able to fetch annotations with an invalid scope",
+			path:         "/api/annotations?scope=dashuid1:panel",
+			method:       http.MethodGet,
+			expectedCode: http.StatusBadRequest,
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionAnnotationsRead, Scope: accesscontrol.ScopeAnnotationsAll}},
+		},
 	}
 
 	for _, tt := range tests {
@@ -402,6 +465,7 @@ func TestAPI_Annotations(t *testing.T) {
 				_ = repo.Save(context.Background(), &annotations.Item{ID: 1, DashboardID: 0, DashboardUID: ""})
 				_ = repo.Save(context.Background(), &annotations.Item{ID: 2, DashboardID: 1, DashboardUID: "dashuid1"})
 				hs.annotationsRepo = repo
+				hs.SQLStore = transactionalFakeDB{dbtest.NewFakeDB()}
 				hs.Features = featuremgmt.WithFeatures(tt.featureFlags...)
 				dashService := &dashboards.FakeDashboardService{}
 				dashService.On("GetDashboard", mock.Anything, mock.Anything).Return(&dashboards.Dashboard{UID: dashUID, FolderUID: folderUID, FolderID: 1}, nil)
@@ -429,6 +493,46 @@ func TestAPI_Annotations(t *testing.T) {
 	}
 }
 
+// transactionalFakeDB runs the functions of the transactions
+type transactionalFakeDB struct {
+	*dbtest.FakeDB
+}
+
+func (db transactionalFakeDB) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestParseAnnotationScopes(t *testing.T) {
+	t.Run("should parse dashboard and panel scopes", func(t *testing.T) {
+		scopes, err := parseAnnotationScopes([]string{"dash-1", "dash-2:4"})
+		require.NoError(t, err)
+		assert.Equal(t, []annotations.Scope{{DashboardUID: "dash-1"}, {DashboardUID: "dash-2", PanelID: 4}}, scopes)
+	})
+
+	t.Run("should fail without dashboard UID", func(t *testing.T) {
+		_, err := parseAnnotationScopes([]string{":4"})
+		require.Error(t, err)
+	})
+
+	t.Run("should fail with an invalid panel ID", func(t *testing.T) {
+		_, err := parseAnnotationScopes([]string{"dash-1:panel"})
+		require.Error(t, err)
+	})
+}
+
+func TestAnnotationsCSV(t *testing.T) {
+	dashboardUID := "dash-1"
+	data, err := annotationsCSV([]*annotations.ItemDTO{
+		{ID: 1, Time: 1714564800000, TimeEnd: 1714564800000, Text: "deploy", Tags: []string{"deploy", "env:prod"}, Login: "admin"},
+		{ID: 2, Time: 1714564800000, TimeEnd: 1714568400000, DashboardUID: &dashboardUID, PanelID: 3, Text: "=HYPERLINK(\"x\")"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `id,time,timeEnd,dashboardUID,panelId,alertName,prevState,newState,text,tags,login
+1,2024-05-01T12:00:00Z,,,0,,,,deploy,"deploy,env:prod",admin
+2,2024-05-01T12:00:00Z,2024-05-01T13:00:00Z,dash-1,3,,,,"'=HYPERLINK(""x"")",,
+`, string(data))
+}
+
 func TestService_AnnotationTypeScopeResolver(t *testing.T) {
 	rootDashUID := "root-dashboard"
 	folderDashUID := "folder-dashboard"
@@ -35,7 +35,10 @@ import (
 
 	"go.opentelemetry.io/otel"
 
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/openapi"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/middleware/requestmeta"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -52,6 +55,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginaccesscontrol"
 	publicdashboardsapi "github.com/grafana/grafana/pkg/services/publicdashboards/api"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -251,6 +255,9 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Post("/api/user/auth-tokens/rotate", routing.Wrap(hs.RotateUserAuthToken))
 	r.Get("/user/auth-tokens/rotate", routing.Wrap(hs.RotateUserAuthTokenRedirect))
 
+	// authenticated by the signature of the events
+	r.Post("/api/annotations/ingest/:integration", routing.Wrap(hs.IngestAnnotation))
+
 	adminAuthPageEvaluator := func() ac.Evaluator {
 		authnSettingsEval := ssoutils.EvalAuthenticationSettings(hs.Cfg)
 
@@ -286,6 +293,11 @@ func (hs *HTTPServer) registerRoutes() {
 
 			userRoute.Post("/stars/dashboard/uid/:uid", routing.Wrap(hs.starApi.StarDashboardByUID))
 			userRoute.Delete("/stars/dashboard/uid/:uid", routing.Wrap(hs.starApi.UnstarDashboardByUID))
+			userRoute.Get("/stars/resources", routing.Wrap(hs.starApi.GetStarredResources))
+			for _, kind := range star.ResourceKinds {
+				userRoute.Post("/stars/"+kind+"/uid/:uid", routing.Wrap(hs.starApi.StarResource(kind)))
+				userRoute.Delete("/stars/"+kind+"/uid/:uid", routing.Wrap(hs.starApi.UnstarResource(kind)))
+			}
 
 			userRoute.Put("/password", routing.Wrap(hs.ChangeUserPassword))
 			userRoute.Get("/quotas", routing.Wrap(hs.GetUserQuotas))
@@ -296,9 +308,24 @@ func (hs *HTTPServer) registerRoutes() {
 			userRoute.Get("/preferences", routing.Wrap(hs.GetUserPreferences))
 			userRoute.Put("/preferences", routing.Wrap(hs.UpdateUserPreferences))
 			userRoute.Patch("/preferences", routing.Wrap(hs.PatchUserPreferences))
+			userRoute.Get("/preferences/resolved", routing.Wrap(hs.GetResolvedUserPreferences))
 
 			userRoute.Get("/auth-tokens", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.GetUserAuthTokens))
 			userRoute.Post("/revoke-auth-token", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.RevokeUserAuthToken))
+			userRoute.Post("/revoke-all-auth-tokens", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.RevokeAllUserAuthTokens))
+			userRoute.Get("/oauth-tokens/health", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.GetUserOAuthTokensHealth))
+
+			if hs.Cfg.MFAAuth.Enabled {
+				userRoute.Get("/mfa", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.GetUserMFAStatus))
+				userRoute.Post("/mfa/enroll", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.EnrollUserMFA))
+				userRoute.Post("/mfa/confirm", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.ConfirmUserMFA))
+				userRoute.Post("/mfa/disable", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.DisableUserMFA))
+				userRoute.Post("/mfa/recovery-codes", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.RegenerateUserMFARecoveryCodes))
+			}
+
+			if hs.Cfg.ImpersonationAuth.Enabled {
+				userRoute.Post("/impersonation/stop", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.StopImpersonation))
+			}
 		}, reqSignedInNoAnonymous)
 
 		apiRoute.Group("/users", func(usersRoute routing.RouteRegister) {
@@ -349,6 +376,23 @@ func (hs *HTTPServer) registerRoutes() {
 			orgRoute.Get("/preferences", authorize(ac.EvalPermission(ac.ActionOrgsPreferencesRead)), routing.Wrap(hs.GetOrgPreferences))
 			orgRoute.Put("/preferences", authorize(ac.EvalPermission(ac.ActionOrgsPreferencesWrite)), routing.Wrap(hs.UpdateOrgPreferences))
 			orgRoute.Patch("/preferences", authorize(ac.EvalPermission(ac.ActionOrgsPreferencesWrite)), routing.Wrap(hs.PatchOrgPreferences))
+			orgRoute.Get("/preferences/locks", authorize(ac.EvalPermission(ac.ActionOrgsPreferencesRead)), routing.Wrap(hs.GetOrgPreferenceLocks))
+			orgRoute.Put("/preferences/locks", authorize(ac.EvalPermission(ac.ActionOrgsPreferencesWrite)), routing.Wrap(hs.UpdateOrgPreferenceLocks))
+
+			// navigation
+			orgRoute.Get("/navigation", authorize(ac.EvalPermission(ac.ActionOrgsPreferencesRead)), routing.Wrap(hs.GetOrgNavigation))
+			orgRoute.Put("/navigation", authorize(ac.EvalPermission(ac.ActionOrgsPreferencesWrite)), routing.Wrap(hs.UpdateOrgNavigation))
+			orgRoute.Delete("/navigation", authorize(ac.EvalPermission(ac.ActionOrgsPreferencesWrite)), routing.Wrap(hs.DeleteOrgNavigation))
+
+			if hs.Cfg.MFAAuth.Enabled {
+				orgRoute.Get("/mfa", requestmeta.SetOwner(requestmeta.TeamAuth), authorize(ac.EvalPermission(ac.ActionOrgsRead)), routing.Wrap(hs.GetCurrentOrgMFAPolicy))
+				orgRoute.Put("/mfa", requestmeta.SetOwner(requestmeta.TeamAuth), authorize(ac.EvalPermission(ac.ActionOrgsWrite)), routing.Wrap(hs.UpdateCurrentOrgMFAPolicy))
+			}
+
+			if hs.Cfg.ImpersonationAuth.Enabled {
+				orgRoute.Get("/impersonation", requestmeta.SetOwner(requestmeta.TeamAuth), authorize(ac.EvalPermission(ac.ActionOrgsRead)), routing.Wrap(hs.GetCurrentOrgImpersonationSettings))
+				orgRoute.Put("/impersonation", requestmeta.SetOwner(requestmeta.TeamAuth), authorize(ac.EvalPermission(ac.ActionOrgsWrite)), routing.Wrap(hs.UpdateCurrentOrgImpersonationSettings))
+			}
 		})
 
 		// current org without requirement of user to be org admin
@@ -382,6 +426,7 @@ func (hs *HTTPServer) registerRoutes() {
 			orgsRoute.Put("/", authorizeInOrg(ac.UseOrgFromContextParams, ac.EvalPermission(ac.ActionOrgsWrite)), routing.Wrap(hs.UpdateOrg))
 			orgsRoute.Put("/address", authorizeInOrg(ac.UseOrgFromContextParams, ac.EvalPermission(ac.ActionOrgsWrite)), routing.Wrap(hs.UpdateOrgAddress))
 			orgsRoute.Delete("/", authorizeInOrg(ac.UseOrgFromContextParams, ac.EvalPermission(ac.ActionOrgsDelete)), routing.Wrap(hs.DeleteOrgByID))
+			orgsRoute.Post("/logout", requestmeta.SetOwner(requestmeta.TeamAuth), authorizeInOrg(ac.UseGlobalOrg, ac.EvalPermission(ac.ActionUsersLogout, ac.ScopeGlobalUsersAll)), routing.Wrap(hs.LogoutOrgUsers))
 			orgsRoute.Get("/users", requestmeta.SetOwner(requestmeta.TeamAuth), authorizeInOrg(ac.UseOrgFromContextParams, ac.EvalPermission(ac.ActionOrgUsersRead)), routing.Wrap(hs.GetOrgUsers))
 			orgsRoute.Get("/users/search", requestmeta.SetOwner(requestmeta.TeamAuth), authorizeInOrg(ac.UseOrgFromContextParams, ac.EvalPermission(ac.ActionOrgUsersRead)), routing.Wrap(hs.SearchOrgUsers))
 			orgsRoute.Post("/users", requestmeta.SetOwner(requestmeta.TeamAuth), authorizeInOrg(ac.UseOrgFromContextParams, ac.EvalPermission(ac.ActionOrgUsersAdd, ac.ScopeUsersAll)), routing.Wrap(hs.AddOrgUser))
@@ -472,6 +517,7 @@ func (hs *HTTPServer) registerRoutes() {
 				dashUidRoute.Get("/versions", authorize(ac.EvalPermission(dashboards.ActionDashboardsWrite, dashUIDScope)), routing.Wrap(hs.GetDashboardVersions))
 				dashUidRoute.Post("/restore", authorize(ac.EvalPermission(dashboards.ActionDashboardsWrite, dashUIDScope)), routing.Wrap(hs.RestoreDashboardVersion))
 				dashUidRoute.Get("/versions/:id", authorize(ac.EvalPermission(dashboards.ActionDashboardsWrite, dashUIDScope)), routing.Wrap(hs.GetDashboardVersion))
+				dashUidRoute.Get("/pdf", requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow), authorize(ac.EvalPermission(dashboards.ActionDashboardsRead, dashUIDScope)), routing.Wrap(hs.ExportDashboardPDF))
 
 				dashUidRoute.Group("/permissions", func(dashboardPermissionRoute routing.RouteRegister) {
 					dashboardPermissionRoute.Get("/", authorize(ac.EvalPermission(dashboards.ActionDashboardsPermissionsRead)), routing.Wrap(hs.GetDashboardPermissionList))
@@ -534,10 +580,25 @@ func (hs *HTTPServer) registerRoutes() {
 			annotationsRoute.Patch("/:annotationId", authorize(ac.EvalPermission(ac.ActionAnnotationsWrite, ac.ScopeAnnotationsID)), routing.Wrap(hs.PatchAnnotation))
 			annotationsRoute.Post("/graphite", authorize(ac.EvalPermission(ac.ActionAnnotationsCreate, ac.ScopeAnnotationsTypeOrganization)), routing.Wrap(hs.PostGraphiteAnnotation))
 			annotationsRoute.Get("/tags", authorize(ac.EvalPermission(ac.ActionAnnotationsRead)), routing.Wrap(hs.GetAnnotationTags))
+			annotationsRoute.Get("/export", authorize(ac.EvalPermission(ac.ActionAnnotationsRead)), routing.Wrap(hs.ExportAnnotations))
+			annotationsRoute.Post("/bulk", authorize(ac.EvalAny(
+				ac.EvalPermission(ac.ActionAnnotationsCreate),
+				ac.EvalPermission(ac.ActionAnnotationsWrite),
+				ac.EvalPermission(ac.ActionAnnotationsDelete),
+			)), routing.Wrap(hs.BulkAnnotations))
 		})
 
 		apiRoute.Post("/frontend-metrics", routing.Wrap(hs.PostFrontendMetrics))
 
+		apiRoute.Post("/batch", openapi.Describe(openapi.Operation{
+			Summary: "Execute a batch of API requests.",
+			Description: "The requests are executed in order with the identity of the caller, the response of each one is returned with its status. " +
+				"The requests of a transactional batch target the same resource type and share a database transaction, which is rolled back when one of them fails.",
+			Request:  dtos.BatchCmd{},
+			Response: dtos.BatchResult{},
+			Errors:   []errutil.Base{errBatchInvalid, errBatchTooLarge, errBatchInvalidRequest, errBatchMixedResourceType},
+		}, routing.Wrap(hs.Batch)))
+
 		apiRoute.Group("/live", func(liveRoute routing.RouteRegister) {
 			// the channel path is in the name
 			liveRoute.Post("/publish", routing.Wrap(hs.Live.HandleHTTPPublish))
@@ -559,16 +620,40 @@ func (hs *HTTPServer) registerRoutes() {
 		adminRoute.Get("/settings", authorize(ac.EvalPermission(ac.ActionSettingsRead)), routing.Wrap(hs.AdminGetSettings))
 		adminRoute.Get("/settings-verbose", authorize(ac.EvalPermission(ac.ActionSettingsRead)), routing.Wrap(hs.AdminGetVerboseSettings))
 		adminRoute.Get("/stats", authorize(ac.EvalPermission(ac.ActionServerStatsRead)), routing.Wrap(hs.AdminGetStats))
+		adminRoute.Get("/stats/db", authorize(ac.EvalPermission(ac.ActionServerStatsRead)), routing.Wrap(hs.AdminGetDatabaseStats))
 
 		adminRoute.Post("/encryption/rotate-data-keys", reqGrafanaAdmin, routing.Wrap(hs.AdminRotateDataEncryptionKeys))
 		adminRoute.Post("/encryption/reencrypt-data-keys", reqGrafanaAdmin, routing.Wrap(hs.AdminReEncryptEncryptionKeys))
 		adminRoute.Post("/encryption/reencrypt-secrets", reqGrafanaAdmin, routing.Wrap(hs.AdminReEncryptSecrets))
 		adminRoute.Post("/encryption/rollback-secrets", reqGrafanaAdmin, routing.Wrap(hs.AdminRollbackSecrets))
+		adminRoute.Post("/secrets/rotation", reqGrafanaAdmin, routing.Wrap(hs.AdminStartSecretsRotation))
+		adminRoute.Get("/secrets/rotation/status", reqGrafanaAdmin, routing.Wrap(hs.AdminGetSecretsRotationStatus))
+		adminRoute.Post("/secrets/rotation/resume", reqGrafanaAdmin, routing.Wrap(hs.AdminResumeSecretsRotation))
+		adminRoute.Post("/secrets/rotation/rollback", reqGrafanaAdmin, routing.Wrap(hs.AdminRollBackSecretsRotation))
+		adminRoute.Post("/secrets/rotation/cutover", reqGrafanaAdmin, routing.Wrap(hs.AdminCutOverSecretsRotation))
+
+		adminRoute.Get("/logging/levels", reqGrafanaAdmin, routing.Wrap(hs.AdminGetLogLevels))
+		adminRoute.Put("/logging/levels/:logger", reqGrafanaAdmin, routing.Wrap(hs.AdminSetLogLevel))
+		adminRoute.Delete("/logging/levels/:logger", reqGrafanaAdmin, routing.Wrap(hs.AdminResetLogLevel))
+		adminRoute.Get("/logging/entries", reqGrafanaAdmin, routing.Wrap(hs.AdminGetLogEntries))
+
+		adminRoute.Get("/snapshots", reqGrafanaAdmin, routing.Wrap(hs.AdminSearchSnapshots))
+		adminRoute.Delete("/snapshots", reqGrafanaAdmin, routing.Wrap(hs.AdminDeleteSnapshots))
 
 		adminRoute.Post("/provisioning/dashboards/reload", authorize(ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersDashboards)), routing.Wrap(hs.AdminProvisioningReloadDashboards))
 		adminRoute.Post("/provisioning/plugins/reload", authorize(ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersPlugins)), routing.Wrap(hs.AdminProvisioningReloadPlugins))
 		adminRoute.Post("/provisioning/datasources/reload", authorize(ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersDatasources)), routing.Wrap(hs.AdminProvisioningReloadDatasources))
 		adminRoute.Post("/provisioning/alerting/reload", authorize(ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersAlertRules)), routing.Wrap(hs.AdminProvisioningReloadAlerting))
+		adminRoute.Post("/provisioning/dashboards/plan", authorize(ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersDashboards)), routing.Wrap(hs.AdminProvisioningPlanDashboards))
+		adminRoute.Post("/provisioning/plugins/plan", authorize(ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersPlugins)), routing.Wrap(hs.AdminProvisioningPlanPlugins))
+		adminRoute.Post("/provisioning/datasources/plan", authorize(ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersDatasources)), routing.Wrap(hs.AdminProvisioningPlanDatasources))
+		adminRoute.Post("/provisioning/alerting/plan", authorize(ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersAlertRules)), routing.Wrap(hs.AdminProvisioningPlanAlerting))
+		adminRoute.Get("/provisioning/status", authorize(ac.EvalAny(
+			ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersDashboards),
+			ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersDatasources),
+			ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersPlugins),
+			ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersAlertRules),
+		)), routing.Wrap(hs.AdminProvisioningStatus))
 	}, reqSignedIn)
 
 	// Administering users
@@ -587,8 +672,21 @@ func (hs *HTTPServer) registerRoutes() {
 		adminUserRoute.Post("/:id/logout", userUIDResolver, authorizeInOrg(ac.UseGlobalOrg, ac.EvalPermission(ac.ActionUsersLogout, userIDScope)), routing.Wrap(hs.AdminLogoutUser))
 		adminUserRoute.Get("/:id/auth-tokens", userUIDResolver, authorizeInOrg(ac.UseGlobalOrg, ac.EvalPermission(ac.ActionUsersAuthTokenList, userIDScope)), routing.Wrap(hs.AdminGetUserAuthTokens))
 		adminUserRoute.Post("/:id/revoke-auth-token", userUIDResolver, authorizeInOrg(ac.UseGlobalOrg, ac.EvalPermission(ac.ActionUsersAuthTokenUpdate, userIDScope)), routing.Wrap(hs.AdminRevokeUserAuthToken))
+		if hs.Cfg.MFAAuth.Enabled {
+			adminUserRoute.Delete("/:id/mfa", userUIDResolver, authorizeInOrg(ac.UseGlobalOrg, ac.EvalPermission(ac.ActionUsersWrite, userIDScope)), routing.Wrap(hs.AdminResetUserMFA))
+		}
+		if hs.Cfg.ImpersonationAuth.Enabled {
+			adminUserRoute.Post("/:id/impersonate", userUIDResolver, authorizeInOrg(ac.UseGlobalOrg, ac.EvalPermission(ac.ActionUsersImpersonate, userIDScope)), routing.Wrap(hs.AdminStartImpersonation))
+		}
 	}, reqSignedIn)
 
+	if hs.Cfg.ImpersonationAuth.Enabled {
+		r.Group("/api/admin/impersonations", func(impersonationRoute routing.RouteRegister) {
+			impersonationRoute.Get("/", authorizeInOrg(ac.UseGlobalOrg, ac.EvalPermission(ac.ActionUsersRead, ac.ScopeGlobalUsersAll)), routing.Wrap(hs.AdminSearchImpersonations))
+			impersonationRoute.Delete("/:uid", authorizeInOrg(ac.UseGlobalOrg, ac.EvalPermission(ac.ActionUsersLogout, ac.ScopeGlobalUsersAll)), routing.Wrap(hs.AdminTerminateImpersonation))
+		}, reqSignedIn, requestmeta.SetOwner(requestmeta.TeamAuth))
+	}
+
 	// rendering
 	r.Get("/render/*", requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow), reqSignedIn, hs.RenderHandler)
 
@@ -602,6 +700,7 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Get("/api/snapshot/shared-options/", reqSignedIn, hs.GetSharingOptions)
 
 	r.Post("/api/snapshots/", reqSnapshotPublicModeOrCreate, hs.getCreatedSnapshotHandler())
+	r.Post("/api/snapshots/sanitize-preview", reqSignedIn, routing.Wrap(hs.PreviewSnapshotSanitization))
 	r.Get("/api/snapshots/:key", routing.Wrap(hs.GetDashboardSnapshot))
 	r.Delete("/api/snapshots/:key", authorize(ac.EvalPermission(dashboards.ActionSnapshotsDelete)), routing.Wrap(hs.DeleteDashboardSnapshot))
 
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	// maxBatchRequests is the maximum number of requests of a batch
+	maxBatchRequests = 50
+	// maxBatchResponseSize is the maximum size of the body of the response of a request of a batch
+	maxBatchResponseSize = 5 << 20
+)
+
+var (
+	errBatchInvalid           = errutil.BadRequest("batch.invalid")
+	errBatchTooLarge          = errutil.BadRequest("batch.too-large", errutil.WithPublicMessage("A batch can't have more than 50 requests"))
+	errBatchInvalidRequest    = errutil.BadRequest("batch.invalid-request")
+	errBatchMixedResourceType = errutil.BadRequest("batch.mixed-resource-types", errutil.WithPublicMessage("The requests of a transactional batch must target the same resource type"))
+
+	// errBatchResponseTooLarge stops recording the response of a request of the batch once it's too large
+	errBatchResponseTooLarge = errors.New("the response is larger than 5 MiB")
+	// errBatchRolledBack rolls back the transaction of a batch when one of its requests failed
+	errBatchRolledBack = errors.New("a request of the batch failed")
+)
+
+var batchMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// Batch executes the requests of the batch in order with the identity of the caller and returns their responses.
+// The requests go through the same middlewares and handlers as the requests of the HTTP API, the batch fails only
+// when it's invalid. The requests of a transactional batch share a database transaction, once one of them fails it's
+// rolled back and the remaining requests aren't executed.
+func (hs *HTTPServer) Batch(c *contextmodel.ReqContext) response.Response {
+	cmd := dtos.BatchCmd{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Err(errBatchInvalid.Errorf("bad request data: %w", err))
+	}
+	if len(cmd.Requests) == 0 {
+		return response.Err(errBatchInvalid.Errorf("batch has no requests"))
+	}
+	if len(cmd.Requests) > maxBatchRequests {
+		return response.Err(errBatchTooLarge.Errorf("batch has %d requests", len(cmd.Requests)))
+	}
+
+	resourceType := ""
+	for i, req := range cmd.Requests {
+		reqResourceType, err := validateBatchRequest(req)
+		if err != nil {
+			return response.Err(invalidBatchRequest(i, err))
+		}
+		if !cmd.Transactional {
+			continue
+		}
+		if i == 0 {
+			resourceType = reqResourceType
+		} else if reqResourceType != resourceType {
+			return response.Err(errBatchMixedResourceType.Errorf("request %d targets %q instead of %q", i, reqResourceType, resourceType))
+		}
+	}
+
+	result := dtos.BatchResult{Responses: make([]dtos.BatchResponse, 0, len(cmd.Requests))}
+	if !cmd.Transactional {
+		for _, req := range cmd.Requests {
+			result.Responses = append(result.Responses, hs.executeBatchRequest(c.Req.Context(), c.Req, req))
+		}
+		return response.JSON(http.StatusOK, result)
+	}
+
+	err := hs.SQLStore.InTransaction(c.Req.Context(), func(ctx context.Context) error {
+		for _, req := range cmd.Requests {
+			rsp := hs.executeBatchRequest(ctx, c.Req, req)
+			result.Responses = append(result.Responses, rsp)
+			if rsp.Status >= http.StatusBadRequest {
+				return errBatchRolledBack
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBatchRolledBack) {
+		return response.Error(http.StatusInternalServerError, "Failed to commit the batch", err)
+	}
+	if err != nil {
+		result.RolledBack = true
+		for _, req := range cmd.Requests[len(result.Responses):] {
+			result.Responses = append(result.Responses, dtos.BatchResponse{ID: req.ID, Status: http.StatusFailedDependency})
+		}
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// executeBatchRequest serves the request of the batch with the headers of the batch, so that it's authenticated as
+// the caller, and records its response
+func (hs *HTTPServer) executeBatchRequest(ctx context.Context, batch *http.Request, req dtos.BatchRequest) dtos.BatchResponse {
+	r, err := http.NewRequestWithContext(ctx, strings.ToUpper(req.Method), req.Path, bytes.NewReader(req.Body))
+	if err != nil {
+		return batchErrorResponse(req.ID, http.StatusBadRequest, err.Error())
+	}
+	r.Header = batch.Header.Clone()
+	r.Header.Del("Content-Length")
+	// the response is recorded as is, it mustn't be compressed
+	r.Header.Del("Accept-Encoding")
+	r.Header.Set("Content-Type", "application/json")
+	r.Host = batch.Host
+	r.RemoteAddr = batch.RemoteAddr
+	r.RequestURI = req.Path
+
+	recorder := &batchResponseRecorder{ResponseRecorder: httptest.NewRecorder()}
+	hs.web.ServeHTTP(recorder, r)
+	if recorder.tooLarge {
+		return batchErrorResponse(req.ID, http.StatusInternalServerError, errBatchResponseTooLarge.Error())
+	}
+
+	body := recorder.Body.Bytes()
+	if len(body) > 0 && !json.Valid(body) {
+		body, _ = json.Marshal(string(body))
+	}
+	return dtos.BatchResponse{ID: req.ID, Status: recorder.Code, Body: body}
+}
+
+// batchResponseRecorder records the response of a request of a batch, up to maxBatchResponseSize
+type batchResponseRecorder struct {
+	*httptest.ResponseRecorder
+	tooLarge bool
+}
+
+func (r *batchResponseRecorder) Write(b []byte) (int, error) {
+	if r.tooLarge || r.Body.Len()+len(b) > maxBatchResponseSize {
+		r.tooLarge = true
+		return 0, errBatchResponseTooLarge
+	}
+	return r.ResponseRecorder.Write(b)
+}
+
+func (r *batchResponseRecorder) WriteString(s string) (int, error) {
+	return r.Write([]byte(s))
+}
+
+// validateBatchRequest returns the resource type of the request. It's read from the decoded path, which is the path
+// the request is routed with.
+func validateBatchRequest(req dtos.BatchRequest) (string, error) {
+	if !batchMethods[strings.ToUpper(req.Method)] {
+		return "", errors.New("method must be one of GET, POST, PUT, PATCH or DELETE")
+	}
+	u, err := url.Parse(req.Path)
+	if err != nil {
+		return "", errors.New("path is invalid")
+	}
+	if u.Scheme != "" || u.Host != "" || !strings.HasPrefix(u.Path, "/api/") {
+		return "", errors.New("path must be an API path starting with /api/")
+	}
+	if strings.Contains(u.Path+"/", "/../") || strings.Contains(u.Path+"/", "/./") {
+		return "", errors.New("path mustn't have relative segments")
+	}
+	resourceType := batchResourceType(u.Path)
+	if strings.EqualFold(resourceType, "batch") {
+		return "", errors.New("batches can't be nested")
+	}
+	if len(req.Body) > 0 && !json.Valid(req.Body) {
+		return "", errors.New("body must be JSON")
+	}
+	return resourceType, nil
+}
+
+// batchResourceType is the first segment of the path after /api/, for example dashboards for /api/dashboards/db
+func batchResourceType(path string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/api/"), "/")
+	return segment
+}
+
+// invalidBatchRequest tells the caller which request of the batch to fix
+func invalidBatchRequest(i int, reason error) error {
+	err := errBatchInvalidRequest.Errorf("request %d: %w", i, reason)
+	err.PublicMessage = fmt.Sprintf("Request %d of the batch is invalid: %s", i, reason)
+	return err
+}
+
+func batchErrorResponse(id string, status int, message string) dtos.BatchResponse {
+	body, _ := json.Marshal(map[string]string{"message": message})
+	return dtos.BatchResponse{ID: id, Status: status, Body: body}
+}
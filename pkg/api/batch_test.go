@@ -0,0 +1,174 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func setupBatchTestServer(t *testing.T, opts ...APITestServerOption) *webtest.Server {
+	t.Helper()
+
+	var hs *HTTPServer
+	server := SetupAPITestServer(t, append(opts, func(h *HTTPServer) {
+		hs = h
+		hs.RouteRegister.Post("/api/test-items", routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+			item := map[string]any{}
+			if err := web.Bind(c.Req, &item); err != nil {
+				return response.Error(http.StatusBadRequest, "bad request data", err)
+			}
+			item["orgId"] = c.SignedInUser.GetOrgID()
+			return response.JSON(http.StatusCreated, item)
+		}))
+		hs.RouteRegister.Get("/api/test-items/text", func(c *contextmodel.ReqContext) {
+			c.Resp.WriteHeader(http.StatusOK)
+			_, _ = c.Resp.Write([]byte("not json"))
+		})
+		hs.RouteRegister.Get("/api/test-items/large", func(c *contextmodel.ReqContext) {
+			c.Resp.WriteHeader(http.StatusOK)
+			_, _ = c.Resp.Write([]byte(strings.Repeat("a", maxBatchResponseSize/2)))
+			_, _ = c.Resp.Write([]byte(strings.Repeat("a", maxBatchResponseSize/2+1)))
+		})
+	})...)
+	hs.web = server.Mux
+	return server
+}
+
+func sendBatch(t *testing.T, server *webtest.Server, body string) (*http.Response, dtos.BatchResult) {
+	t.Helper()
+
+	req := server.NewPostRequest("/api/batch", strings.NewReader(body))
+	res, err := server.SendJSON(webtest.RequestWithSignedInUser(req, userWithPermissions(2, nil)))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, res.Body.Close()) })
+
+	result := dtos.BatchResult{}
+	if res.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+	}
+	return res, result
+}
+
+func TestBatch(t *testing.T) {
+	server := setupBatchTestServer(t)
+
+	t.Run("should execute the requests in order with the identity of the caller", func(t *testing.T) {
+		res, result := sendBatch(t, server, `{"requests": [
+			{"id": "a", "method": "POST", "path": "/api/test-items", "body": {"name": "a"}},
+			{"id": "b", "method": "GET", "path": "/api/test-items/unknown"},
+			{"id": "c", "method": "get", "path": "/api/test-items/text"}
+		]}`)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.False(t, result.RolledBack)
+		require.Len(t, result.Responses, 3)
+
+		assert.Equal(t, "a", result.Responses[0].ID)
+		assert.Equal(t, http.StatusCreated, result.Responses[0].Status)
+		assert.JSONEq(t, `{"name": "a", "orgId": 2}`, string(result.Responses[0].Body))
+
+		assert.Equal(t, "b", result.Responses[1].ID)
+		assert.Equal(t, http.StatusNotFound, result.Responses[1].Status)
+
+		assert.Equal(t, http.StatusOK, result.Responses[2].Status)
+		assert.Equal(t, `"not json"`, string(result.Responses[2].Body))
+	})
+
+	t.Run("should not record the responses larger than the limit", func(t *testing.T) {
+		res, result := sendBatch(t, server, `{"requests": [{"id": "large", "method": "GET", "path": "/api/test-items/large"}]}`)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Len(t, result.Responses, 1)
+		assert.Equal(t, http.StatusInternalServerError, result.Responses[0].Status)
+		assert.Less(t, len(result.Responses[0].Body), 1024)
+	})
+
+	t.Run("should reject invalid batches", func(t *testing.T) {
+		for name, body := range map[string]string{
+			"no requests":               `{"requests": []}`,
+			"invalid method":            `{"requests": [{"method": "OPTIONS", "path": "/api/test-items"}]}`,
+			"frontend path":             `{"requests": [{"method": "GET", "path": "/login"}]}`,
+			"absolute URL":              `{"requests": [{"method": "GET", "path": "https://example.com/api/test-items"}]}`,
+			"relative segments":         `{"requests": [{"method": "GET", "path": "/api/test-items/../batch"}]}`,
+			"nested batch":              `{"requests": [{"method": "POST", "path": "/api/batch", "body": {"requests": []}}]}`,
+			"encoded nested batch":      `{"requests": [{"method": "POST", "path": "/api/%62atch", "body": {"requests": []}}]}`,
+			"encoded relative segments": `{"requests": [{"method": "GET", "path": "/api/test-items/%2e%2e/batch"}]}`,
+			"mixed resource types": `{"transactional": true, "requests": [
+				{"method": "POST", "path": "/api/test-items"},
+				{"method": "POST", "path": "/api/folders"}
+			]}`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				res, _ := sendBatch(t, server, body)
+				assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+			})
+		}
+
+		requests := make([]string, maxBatchRequests+1)
+		for i := range requests {
+			requests[i] = `{"method": "GET", "path": "/api/test-items/text"}`
+		}
+		res, _ := sendBatch(t, server, `{"requests": [`+strings.Join(requests, ",")+`]}`)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+}
+
+func TestIntegrationBatchTransactional(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	kv := kvstore.WithNamespace(kvstore.ProvideService(sqlStore), 2, "test-batch")
+	server := setupBatchTestServer(t, func(hs *HTTPServer) {
+		hs.SQLStore = sqlStore
+		hs.RouteRegister.Put("/api/test-keys/:key", routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+			if err := kv.Set(c.Req.Context(), web.Params(c.Req)[":key"], "value"); err != nil {
+				return response.Error(http.StatusInternalServerError, "failed to set key", err)
+			}
+			return response.Success("Key set")
+		}))
+	})
+
+	t.Run("should keep the changes when all the requests succeed", func(t *testing.T) {
+		res, result := sendBatch(t, server, `{"transactional": true, "requests": [
+			{"method": "PUT", "path": "/api/test-keys/a"},
+			{"method": "PUT", "path": "/api/test-keys/b"}
+		]}`)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.False(t, result.RolledBack)
+
+		keys, err := kv.Keys(t.Context(), "")
+		require.NoError(t, err)
+		assert.Len(t, keys, 2)
+	})
+
+	t.Run("should roll back the changes and skip the remaining requests when a request fails", func(t *testing.T) {
+		res, result := sendBatch(t, server, `{"transactional": true, "requests": [
+			{"id": "c", "method": "PUT", "path": "/api/test-keys/c"},
+			{"id": "unknown", "method": "PUT", "path": "/api/test-keys/c/unknown"},
+			{"id": "d", "method": "PUT", "path": "/api/test-keys/d"}
+		]}`)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.True(t, result.RolledBack)
+		require.Len(t, result.Responses, 3)
+		assert.Equal(t, http.StatusOK, result.Responses[0].Status)
+		assert.Equal(t, http.StatusNotFound, result.Responses[1].Status)
+		assert.Equal(t, dtos.BatchResponse{ID: "d", Status: http.StatusFailedDependency}, result.Responses[2])
+
+		_, found, err := kv.Get(t.Context(), "c")
+		require.NoError(t, err)
+		assert.False(t, found, "the change of the first request is rolled back")
+	})
+}
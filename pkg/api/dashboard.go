@@ -36,7 +36,9 @@ import (
 	publicdashboardModels "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/useractivity"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/etag"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -275,8 +277,15 @@ func (hs *HTTPServer) GetDashboard(c *contextmodel.ReqContext) response.Response
 		Meta:      meta,
 	}
 
+	useractivity.RecordRequest(hs.userActivityService, c, &useractivity.Activity{
+		Type:        useractivity.TypeDashboardView,
+		ResourceUID: dash.UID,
+		Title:       dash.Title,
+		URL:         meta.Url,
+	})
+
 	c.TimeRequest(metrics.MApiDashboardGet)
-	return response.JSON(http.StatusOK, dto)
+	return response.JSON(http.StatusOK, dto).SetHeader(etag.Header, etag.FromVersion(int64(dash.Version)))
 }
 
 func (hs *HTTPServer) getAnnotationPermissionsByScope(c *contextmodel.ReqContext, actions *dashboardsV1.AnnotationActions, scope string) {
@@ -356,6 +365,7 @@ func (hs *HTTPServer) getDashboardHelper(ctx context.Context, orgID int64, id in
 // 401: unauthorisedError
 // 403: forbiddenError
 // 404: notFoundError
+// 412: preconditionFailedError
 // 500: internalServerError
 func (hs *HTTPServer) DeleteDashboardByUID(c *contextmodel.ReqContext) response.Response {
 	return hs.deleteDashboard(c)
@@ -376,6 +386,9 @@ func (hs *HTTPServer) deleteDashboard(c *contextmodel.ReqContext) response.Respo
 	if dash.IsFolder {
 		return response.Error(http.StatusBadRequest, "Use folders endpoint for deleting folders.", nil)
 	}
+	if err := etag.Check(c.Req, etag.FromVersion(int64(dash.Version))); err != nil {
+		return response.Err(err)
+	}
 
 	// disconnect all library elements for this dashboard
 	err := hs.LibraryElementService.DisconnectElementsFromDashboard(c.Req.Context(), dash.ID)
@@ -478,6 +491,14 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		}
 	}
 
+	if etag.IfMatch(c.Req) != nil {
+		if rsp := hs.checkDashboardIfMatch(c, dash); rsp != nil {
+			return rsp
+		}
+		// the dashboard is saved only if it's still the version checked
+		cmd.Overwrite = false
+	}
+
 	var provisioningData *dashboards.DashboardProvisioning
 	if dash.ID != 0 {
 		data, err := hs.dashboardProvisioningService.GetProvisionedDashboardDataByDashboardID(c.Req.Context(), dash.ID)
@@ -542,6 +563,13 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		return response.Error(http.StatusInternalServerError, "Error while connecting library panels", err)
 	}
 
+	useractivity.RecordRequest(hs.userActivityService, c, &useractivity.Activity{
+		Type:        useractivity.TypeDashboardEdit,
+		ResourceUID: dashboard.UID,
+		Title:       dashboard.Title,
+		URL:         dashboard.GetURL(),
+	})
+
 	c.TimeRequest(metrics.MApiDashboardSave)
 	return response.JSON(http.StatusOK, util.DynMap{
 		"status":    "success",
@@ -551,7 +579,28 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		"uid":       dashboard.UID,
 		"url":       dashboard.GetURL(),
 		"folderUid": dashboard.FolderUID,
-	})
+	}).SetHeader(etag.Header, etag.FromVersion(int64(dashboard.Version)))
+}
+
+// checkDashboardIfMatch checks the If-Match header of a request saving the dashboard against the saved dashboard,
+// and sets the version of the dashboard to the checked version
+func (hs *HTTPServer) checkDashboardIfMatch(c *contextmodel.ReqContext, dash *dashboards.Dashboard) response.Response {
+	if dash.ID == 0 && dash.UID == "" {
+		return response.Err(etag.ErrPreconditionFailed.Errorf("new dashboards have no ETag"))
+	}
+	current, err := hs.DashboardService.GetDashboard(c.Req.Context(), &dashboards.GetDashboardQuery{ID: dash.ID, UID: dash.UID, OrgID: c.GetOrgID()})
+	if errors.Is(err, dashboards.ErrDashboardNotFound) {
+		return response.Err(etag.ErrPreconditionFailed.Errorf("dashboard not found: %w", err))
+	}
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard", err)
+	}
+	if err := etag.Check(c.Req, etag.FromVersion(int64(current.Version))); err != nil {
+		return response.Err(err)
+	}
+	dash.Version = current.Version
+	dash.Data.Set("version", current.Version)
+	return nil
 }
 
 // swagger:route GET /dashboards/home dashboards getHomeDashboard
@@ -0,0 +1,309 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/rendering/pdfcomposer"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// maxPDFPanels is the maximum number of panels rendered for a PDF, every panel is a request to the image renderer
+const maxPDFPanels = 100
+
+type dashboardPDFPanel struct {
+	ID   int64
+	Info rendering.PanelInfo
+}
+
+// swagger:route GET /dashboards/uid/{uid}/pdf dashboards exportDashboardPDF
+//
+// Export a dashboard as PDF.
+//
+// Renders every panel of the dashboard, including the panels of the collapsed rows, as an image and composes the
+// images in a paginated PDF. The first page has the details of the dashboard and the table of contents.
+//
+// Produces:
+// - application/pdf
+//
+// Responses:
+// 200: exportDashboardPDFResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 406: notAcceptableError
+// 429: genericError
+// 500: internalServerError
+func (hs *HTTPServer) ExportDashboardPDF(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "api.ExportDashboardPDF")
+	defer span.End()
+	c.Req = c.Req.WithContext(ctx)
+
+	uid := web.Params(c.Req)[":uid"]
+	dash, rsp := hs.getDashboardHelper(ctx, c.GetOrgID(), 0, uid)
+	if rsp != nil {
+		return rsp
+	}
+	if strings.HasPrefix(dash.APIVersion, "v2") {
+		return response.Error(http.StatusNotAcceptable, "dashboard api version not supported", nil)
+	}
+
+	query := c.Req.URL.Query()
+	width := c.QueryInt("width")
+	if width == 0 {
+		width = hs.Cfg.RendererDefaultImageWidth
+	}
+	height := c.QueryInt("height")
+	if height == 0 {
+		height = hs.Cfg.RendererDefaultImageHeight
+	}
+	scale := c.QueryFloat64("scale")
+	if scale == 0 {
+		scale = hs.Cfg.RendererDefaultImageScale
+	}
+	timeout := c.QueryIntWithDefault("timeout", 60)
+	if timeout <= 0 {
+		return response.Error(http.StatusBadRequest, "Render parameters error: timeout must be a positive integer", nil)
+	}
+
+	theme := models.ThemeLight
+	if value := query.Get("theme"); value != "" {
+		parsed, err := models.ParseTheme(value)
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "Render parameters error: theme can only be light or dark", err)
+		}
+		theme = parsed
+	}
+
+	orientation := pdfcomposer.Portrait
+	switch value := pdfcomposer.Orientation(query.Get("orientation")); value {
+	case "", pdfcomposer.Portrait:
+	case pdfcomposer.Landscape:
+		orientation = value
+	default:
+		return response.Error(http.StatusBadRequest, "Render parameters error: orientation can only be portrait or landscape", nil)
+	}
+
+	pageSize := query.Get("pageSize")
+	switch pageSize {
+	case "", "A4", "A3", "Letter", "Legal":
+	default:
+		return response.Error(http.StatusBadRequest, "Render parameters error: pageSize can only be A4, A3, Letter or Legal", nil)
+	}
+
+	panels := dashboardPDFPanels(dash.Data)
+	if len(panels) == 0 {
+		return response.Error(http.StatusBadRequest, "Dashboard has no panels", nil)
+	}
+	if len(panels) > maxPDFPanels {
+		return response.Error(http.StatusBadRequest, fmt.Sprintf("Dashboard has more than %d panels", maxPDFPanels), nil)
+	}
+
+	from, to := query.Get("from"), query.Get("to")
+	if from == "" || to == "" {
+		from, to = "now-6h", "now"
+	}
+
+	doc := &pdfcomposer.Document{
+		Title:           dash.Title,
+		Description:     dash.Data.Get("description").MustString(),
+		TimeRange:       fmt.Sprintf("%s to %s", from, to),
+		PageSize:        pageSize,
+		Orientation:     orientation,
+		OnePanelPerPage: c.QueryBool("onePanelPerPage"),
+		TableOfContents: query.Get("toc") != "false",
+		Panels:          make([]pdfcomposer.Panel, 0, len(panels)),
+	}
+
+	// the variables are passed to every panel as they are in the URL of the dashboard
+	params := url.Values{}
+	params.Set("orgId", strconv.FormatInt(dash.OrgID, 10))
+	params.Set("from", from)
+	params.Set("to", to)
+	names := make([]string, 0)
+	for name, values := range query {
+		if strings.HasPrefix(name, "var-") {
+			params[name] = values
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		doc.Variables = append(doc.Variables, pdfcomposer.Variable{Name: strings.TrimPrefix(name, "var-"), Value: strings.Join(query[name], ", ")})
+	}
+
+	userID, err := identity.UserIdentifier(c.GetID())
+	if err != nil {
+		hs.log.Debug("Failed to parse user id", "err", err)
+	}
+	headers := http.Header{}
+	if acceptLanguage := c.Req.Header.Values("Accept-Language"); len(acceptLanguage) > 0 {
+		headers["Accept-Language"] = acceptLanguage
+	}
+
+	for _, panel := range panels {
+		params.Set("panelId", strconv.FormatInt(panel.ID, 10))
+		info := panel.Info
+		result, err := hs.RenderService.Render(ctx, rendering.RenderPNG, rendering.Opts{
+			CommonOpts: rendering.CommonOpts{
+				TimeoutOpts: rendering.TimeoutOpts{
+					Timeout: time.Duration(timeout) * time.Second,
+				},
+				AuthOpts: rendering.AuthOpts{
+					OrgID:   c.GetOrgID(),
+					UserID:  userID,
+					OrgRole: c.GetOrgRole(),
+				},
+				Path:            path.Join("d-solo", dash.UID, dash.Slug) + "?" + params.Encode(),
+				Timezone:        query.Get("tz"),
+				ConcurrentLimit: hs.Cfg.RendererConcurrentRequestLimit,
+				Headers:         headers,
+			},
+			ErrorOpts: rendering.ErrorOpts{
+				ErrorConcurrentLimitReached: true,
+				ErrorRenderUnavailable:      true,
+			},
+			Width:             width,
+			Height:            height,
+			DeviceScaleFactor: scale,
+			Theme:             theme,
+			Panel:             &info,
+		}, nil)
+		if err != nil {
+			return renderErrorResponse(err)
+		}
+
+		image, err := os.ReadFile(result.FilePath)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to read the rendered panel", err)
+		}
+		doc.Panels = append(doc.Panels, pdfcomposer.Panel{Title: info.Title, Image: image})
+	}
+
+	var buf bytes.Buffer
+	if err := pdfcomposer.Compose(&buf, doc); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to compose the PDF", err)
+	}
+
+	filename := dash.Slug
+	if filename == "" {
+		filename = dash.UID
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/pdf")
+	header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, filename))
+	header.Set("Cache-Control", "private")
+	return response.CreateNormalResponse(header, buf.Bytes(), http.StatusOK)
+}
+
+func renderErrorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, rendering.ErrTooManyRequests), errors.Is(err, rendering.ErrConcurrentLimitReached):
+		return response.Error(http.StatusTooManyRequests, "Too many rendering requests", err)
+	case errors.Is(err, rendering.ErrRenderUnavailable):
+		return response.Error(http.StatusInternalServerError, "Image renderer not available", err)
+	case errors.Is(err, rendering.ErrTimeout):
+		return response.Error(http.StatusInternalServerError, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, "Rendering failed.", err)
+}
+
+// dashboardPDFPanels returns the panels of the dashboard in the order they appear, the rows are replaced by their
+// collapsed panels
+func dashboardPDFPanels(data *simplejson.Json) []dashboardPDFPanel {
+	if data == nil {
+		return nil
+	}
+	var panels []dashboardPDFPanel
+	for _, item := range data.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(item)
+		if panel.Get("type").MustString() == "row" {
+			panels = append(panels, dashboardPDFPanels(panel)...)
+			continue
+		}
+		id, err := panel.Get("id").Int64()
+		if err != nil {
+			continue
+		}
+		panels = append(panels, dashboardPDFPanel{
+			ID: id,
+			Info: rendering.PanelInfo{
+				Type:    panel.Get("type").MustString(),
+				Title:   panel.Get("title").MustString(),
+				Options: panel.Get("options").MustMap(),
+			},
+		})
+	}
+	return panels
+}
+
+// swagger:parameters exportDashboardPDF
+type ExportDashboardPDFParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// The start of the time range of the panels
+	// in:query
+	// required:false
+	// default:now-6h
+	From string `json:"from"`
+	// The end of the time range of the panels
+	// in:query
+	// required:false
+	// default:now
+	To string `json:"to"`
+	// The width of the panel images in pixels
+	// in:query
+	// required:false
+	Width int `json:"width"`
+	// The height of the panel images in pixels
+	// in:query
+	// required:false
+	Height int `json:"height"`
+	// in:query
+	// required:false
+	// enum: portrait,landscape
+	// default:portrait
+	Orientation string `json:"orientation"`
+	// in:query
+	// required:false
+	// enum: A4,A3,Letter,Legal
+	// default:A4
+	PageSize string `json:"pageSize"`
+	// Start every panel on a new page
+	// in:query
+	// required:false
+	OnePanelPerPage bool `json:"onePanelPerPage"`
+	// Add the table of contents to the first page
+	// in:query
+	// required:false
+	// default:true
+	TOC bool `json:"toc"`
+	// in:query
+	// required:false
+	// enum: light,dark
+	// default:light
+	Theme string `json:"theme"`
+}
+
+// swagger:response exportDashboardPDFResponse
+type ExportDashboardPDFResponse struct {
+	// in: body
+	Body []byte `json:"body"`
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestHTTPServer_ExportDashboardPDF(t *testing.T) {
+	dash := &dashboards.Dashboard{ID: 1, UID: "abc", OrgID: 1, Slug: "ops", Title: "Ops", Data: simplejson.NewFromAny(map[string]any{
+		"title":       "Ops",
+		"description": "The ops dashboard",
+		"panels": []any{
+			map[string]any{"id": 1, "type": "timeseries", "title": "Requests"},
+			map[string]any{"id": 2, "type": "row", "title": "Details", "collapsed": true, "panels": []any{
+				map[string]any{"id": 3, "type": "stat", "title": "Errors"},
+			}},
+			map[string]any{"id": 4, "type": "text", "title": "Notes"},
+		},
+	})}
+	canRead := []accesscontrol.Permission{{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:abc"}}
+
+	imagePath := filepath.Join(t.TempDir(), "panel.png")
+	var img bytes.Buffer
+	require.NoError(t, png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 40, 20))))
+	require.NoError(t, os.WriteFile(imagePath, img.Bytes(), 0o600))
+
+	setup := func(t *testing.T, renderService rendering.Service) *webtest.Server {
+		return SetupAPITestServer(t, func(hs *HTTPServer) {
+			svc := dashboards.NewFakeDashboardService(t)
+			svc.On("GetDashboard", mock.Anything, mock.Anything).Return(dash, nil).Maybe()
+			hs.DashboardService = svc
+			hs.RenderService = renderService
+		})
+	}
+
+	t.Run("should require the permission to read the dashboard", func(t *testing.T) {
+		server := setup(t, rendering.NewMockService(gomock.NewController(t)))
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/abc/pdf"), userWithPermissions(1, []accesscontrol.Permission{
+			{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:other"},
+		})))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should render every panel and compose them in a PDF", func(t *testing.T) {
+		renderService := rendering.NewMockService(gomock.NewController(t))
+		var rendered []string
+		renderService.EXPECT().Render(gomock.Any(), rendering.RenderPNG, gomock.Any(), nil).Times(3).DoAndReturn(
+			func(_ context.Context, _ rendering.RenderType, opts rendering.Opts, _ rendering.Session) (*rendering.RenderResult, error) {
+				u, err := url.Parse(opts.Path)
+				require.NoError(t, err)
+				assert.Equal(t, "d-solo/abc/ops", u.Path)
+				assert.Equal(t, "now-1h", u.Query().Get("from"))
+				assert.Equal(t, "now", u.Query().Get("to"))
+				assert.Equal(t, "prod", u.Query().Get("var-env"))
+				assert.Equal(t, int64(1), opts.OrgID)
+				assert.True(t, opts.ErrorRenderUnavailable)
+				require.NotNil(t, opts.Panel)
+				rendered = append(rendered, u.Query().Get("panelId")+":"+opts.Panel.Title)
+				return &rendering.RenderResult{FilePath: imagePath}, nil
+			})
+		server := setup(t, renderService)
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/abc/pdf?from=now-1h&to=now&var-env=prod&orientation=landscape"), userWithPermissions(1, canRead)))
+		require.NoError(t, err)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+
+		require.Equal(t, http.StatusOK, res.StatusCode, string(body))
+		assert.Equal(t, "application/pdf", res.Header.Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="ops.pdf"`, res.Header.Get("Content-Disposition"))
+		assert.True(t, bytes.HasPrefix(body, []byte("%PDF-")))
+		assert.Equal(t, []string{"1:Requests", "3:Errors", "4:Notes"}, rendered, "the rows are replaced by their panels")
+	})
+
+	t.Run("should reject the invalid parameters before rendering", func(t *testing.T) {
+		server := setup(t, rendering.NewMockService(gomock.NewController(t)))
+
+		for _, query := range []string{"orientation=sideways", "pageSize=A0", "theme=blue", "timeout=-1"} {
+			res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/abc/pdf?"+query), userWithPermissions(1, canRead)))
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, res.StatusCode, query)
+			require.NoError(t, res.Body.Close())
+		}
+	})
+
+	t.Run("should fail when the image renderer is not available", func(t *testing.T) {
+		renderService := rendering.NewMockService(gomock.NewController(t))
+		renderService.EXPECT().Render(gomock.Any(), rendering.RenderPNG, gomock.Any(), nil).Return(nil, rendering.ErrRenderUnavailable)
+		server := setup(t, renderService)
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/abc/pdf"), userWithPermissions(1, canRead)))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should answer too many requests when the rendering limit is reached", func(t *testing.T) {
+		renderService := rendering.NewMockService(gomock.NewController(t))
+		renderService.EXPECT().Render(gomock.Any(), rendering.RenderPNG, gomock.Any(), nil).Return(nil, rendering.ErrConcurrentLimitReached)
+		server := setup(t, renderService)
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/abc/pdf"), userWithPermissions(1, canRead)))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
+	common "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	dashboardsnapshot "github.com/grafana/grafana/pkg/apis/dashboardsnapshot/v0alpha1"
 	"github.com/grafana/grafana/pkg/infra/metrics"
@@ -301,6 +302,42 @@ func (hs *HTTPServer) SearchDashboardSnapshots(c *contextmodel.ReqContext) respo
 	return response.JSON(http.StatusOK, dto)
 }
 
+// swagger:route POST /snapshots/sanitize-preview snapshots previewSnapshotSanitization
+//
+// Preview the sanitization of a snapshot.
+//
+// Returns the dashboard of a snapshot as it would be stored or published to the external snapshot server, and the values removed by the sanitize rules of the [snapshots] section. Nothing is stored.
+//
+// Responses:
+// 200: previewSnapshotSanitizationResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+func (hs *HTTPServer) PreviewSnapshotSanitization(c *contextmodel.ReqContext) response.Response {
+	if !hs.Cfg.SnapshotEnabled {
+		return response.Error(http.StatusForbidden, "Dashboard Snapshots are disabled", nil)
+	}
+
+	cmd := dashboardsnapshots.CreateDashboardSnapshotCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if cmd.Dashboard == nil {
+		return response.Error(http.StatusBadRequest, "The dashboard must be set", nil)
+	}
+
+	removed := hs.dashboardsnapshotsService.SanitizeDashboard(cmd.Dashboard)
+	return response.JSON(http.StatusOK, SnapshotSanitizationPreview{Dashboard: cmd.Dashboard, Removed: removed})
+}
+
+// swagger:model
+type SnapshotSanitizationPreview struct {
+	// The dashboard without the removed values
+	Dashboard *common.Unstructured `json:"dashboard"`
+	// The removed values, in the order of the sanitize rules
+	Removed []dashboardsnapshots.SanitizedValue `json:"removed"`
+}
+
 // swagger:parameters createDashboardSnapshot
 type CreateSnapshotParams struct {
 	// in:body
@@ -308,6 +345,13 @@ type CreateSnapshotParams struct {
 	Body dashboardsnapshots.CreateDashboardSnapshotCommand `json:"body"`
 }
 
+// swagger:parameters previewSnapshotSanitization
+type PreviewSnapshotSanitizationParams struct {
+	// in:body
+	// required:true
+	Body dashboardsnapshots.CreateDashboardSnapshotCommand `json:"body"`
+}
+
 // swagger:parameters searchDashboardSnapshots
 type GetSnapshotsParams struct {
 	// Search Query
@@ -358,6 +402,12 @@ type SearchDashboardSnapshotsResponse struct {
 	Body []*dashboardsnapshots.DashboardSnapshotDTO `json:"body"`
 }
 
+// swagger:response previewSnapshotSanitizationResponse
+type PreviewSnapshotSanitizationResponse struct {
+	// in:body
+	Body SnapshotSanitizationPreview `json:"body"`
+}
+
 // swagger:response getDashboardSnapshotResponse
 type GetDashboardSnapshotResponse DashboardResponse
 
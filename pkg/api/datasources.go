@@ -20,8 +20,10 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/etag"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -52,6 +54,16 @@ func (hs *HTTPServer) GetDataSources(c *contextmodel.ReqContext) response.Respon
 		return response.Error(http.StatusInternalServerError, "Failed to query datasources", err)
 	}
 
+	stars, err := hs.starService.GetResourcesByUser(c.Req.Context(), &star.GetUserResourceStarsQuery{
+		UserID: c.SignedInUser.UserID,
+		OrgID:  c.SignedInUser.OrgID,
+		Kind:   star.KindDatasource,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get starred datasources", err)
+	}
+	starredUIDs := stars.UIDs(star.KindDatasource)
+
 	result := make(dtos.DataSourceList, 0)
 	for _, ds := range filtered {
 		dsItem := dtos.DataSourceListItemDTO{
@@ -69,6 +81,7 @@ func (hs *HTTPServer) GetDataSources(c *contextmodel.ReqContext) response.Respon
 			IsDefault: ds.IsDefault,
 			JsonData:  ds.JsonData,
 			ReadOnly:  ds.ReadOnly,
+			IsStarred: starredUIDs[ds.UID],
 		}
 
 		if plugin, exists := hs.pluginStore.Plugin(c.Req.Context(), ds.Type); exists {
@@ -131,7 +144,7 @@ func (hs *HTTPServer) GetDataSourceById(c *contextmodel.ReqContext) response.Res
 	// Add accesscontrol metadata
 	dto.AccessControl = getAccessControlMetadata(c, datasources.ScopePrefix, dto.UID)
 
-	return response.JSON(http.StatusOK, &dto)
+	return response.JSON(http.StatusOK, &dto).SetHeader(etag.Header, etag.FromVersion(int64(dto.Version)))
 }
 
 // swagger:route DELETE /datasources/{id} datasources deleteDataSourceByID
@@ -172,6 +185,9 @@ func (hs *HTTPServer) DeleteDataSourceById(c *contextmodel.ReqContext) response.
 	if ds.ReadOnly {
 		return response.Error(http.StatusForbidden, "Cannot delete read-only data source", nil)
 	}
+	if err := etag.Check(c.Req, etag.FromVersion(int64(ds.Version))); err != nil {
+		return response.Err(err)
+	}
 
 	cmd := &datasources.DeleteDataSourceCommand{ID: id, OrgID: c.GetOrgID(), Name: ds.Name}
 
@@ -214,7 +230,7 @@ func (hs *HTTPServer) GetDataSourceByUID(c *contextmodel.ReqContext) response.Re
 	// Add accesscontrol metadata
 	dto.AccessControl = getAccessControlMetadata(c, datasources.ScopePrefix, dto.UID)
 
-	return response.JSON(http.StatusOK, &dto)
+	return response.JSON(http.StatusOK, &dto).SetHeader(etag.Header, etag.FromVersion(int64(dto.Version)))
 }
 
 // swagger:route DELETE /datasources/uid/{uid} datasources deleteDataSourceByUID
@@ -229,6 +245,7 @@ func (hs *HTTPServer) GetDataSourceByUID(c *contextmodel.ReqContext) response.Re
 // 401: unauthorisedError
 // 403: forbiddenError
 // 404: notFoundError
+// 412: preconditionFailedError
 // 500: internalServerError
 func (hs *HTTPServer) DeleteDataSourceByUID(c *contextmodel.ReqContext) response.Response {
 	uid := web.Params(c.Req)[":uid"]
@@ -248,6 +265,9 @@ func (hs *HTTPServer) DeleteDataSourceByUID(c *contextmodel.ReqContext) response
 	if ds.ReadOnly {
 		return response.Error(http.StatusForbidden, "Cannot delete read-only data source", nil)
 	}
+	if err := etag.Check(c.Req, etag.FromVersion(int64(ds.Version))); err != nil {
+		return response.Err(err)
+	}
 
 	cmd := &datasources.DeleteDataSourceCommand{UID: uid, OrgID: c.GetOrgID(), Name: ds.Name}
 
@@ -296,6 +316,9 @@ func (hs *HTTPServer) DeleteDataSourceByName(c *contextmodel.ReqContext) respons
 	if dataSource.ReadOnly {
 		return response.Error(http.StatusForbidden, "Cannot delete read-only data source", nil)
 	}
+	if err := etag.Check(c.Req, etag.FromVersion(int64(dataSource.Version))); err != nil {
+		return response.Err(err)
+	}
 
 	cmd := &datasources.DeleteDataSourceCommand{Name: name, OrgID: c.GetOrgID()}
 	err = hs.DataSourcesService.DeleteDataSource(c.Req.Context(), cmd)
@@ -476,6 +499,7 @@ func (hs *HTTPServer) UpdateDataSourceByID(c *contextmodel.ReqContext) response.
 // 401: unauthorisedError
 // 403: forbiddenError
 // 409: conflictError
+// 412: preconditionFailedError
 // 500: internalServerError
 func (hs *HTTPServer) UpdateDataSourceByUID(c *contextmodel.ReqContext) response.Response {
 	cmd := datasources.UpdateDataSourceCommand{}
@@ -507,6 +531,14 @@ func (hs *HTTPServer) updateDataSourceByID(c *contextmodel.ReqContext, ds *datas
 	if ds.ReadOnly {
 		return response.Error(http.StatusForbidden, "Cannot update read-only data source", nil)
 	}
+	ifMatch := etag.IfMatch(c.Req) != nil
+	if ifMatch {
+		if err := etag.Check(c.Req, etag.FromVersion(int64(ds.Version))); err != nil {
+			return response.Err(err)
+		}
+		// the data source is updated only if it's still the version checked
+		cmd.Version = ds.Version
+	}
 
 	_, err := hs.DataSourcesService.UpdateDataSource(c.Req.Context(), &cmd)
 	if err != nil {
@@ -515,6 +547,9 @@ func (hs *HTTPServer) updateDataSourceByID(c *contextmodel.ReqContext, ds *datas
 		}
 
 		if errors.Is(err, datasources.ErrDataSourceUpdatingOldVersion) {
+			if ifMatch {
+				return response.Err(etag.ErrPreconditionFailed.Errorf("data source was updated since it was checked: %w", err))
+			}
 			return response.Error(http.StatusConflict, "Datasource has already been updated by someone else. Please reload and try again", err)
 		}
 
@@ -543,7 +578,7 @@ func (hs *HTTPServer) updateDataSourceByID(c *contextmodel.ReqContext, ds *datas
 		"id":         cmd.ID,
 		"name":       cmd.Name,
 		"datasource": datasourceDTO,
-	})
+	}).SetHeader(etag.Header, etag.FromVersion(int64(dataSource.Version)))
 }
 
 func (hs *HTTPServer) getRawDataSourceById(ctx context.Context, id int64, orgID int64) (*datasources.DataSource, error) {
@@ -598,7 +633,7 @@ func (hs *HTTPServer) GetDataSourceByName(c *contextmodel.ReqContext) response.R
 	}
 
 	dto := hs.convertModelToDtos(c.Req.Context(), dataSource)
-	return response.JSON(http.StatusOK, &dto)
+	return response.JSON(http.StatusOK, &dto).SetHeader(etag.Header, etag.FromVersion(int64(dto.Version)))
 }
 
 // swagger:route GET /datasources/id/{name} datasources getDataSourceIdByName
@@ -24,6 +24,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources/guardian"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/star"
+	"github.com/grafana/grafana/pkg/services/star/startest"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 	"github.com/grafana/grafana/pkg/web/webtest"
@@ -40,11 +42,15 @@ func TestDataSourcesProxy_userLoggedIn(t *testing.T) {
 	loggedInUserScenario(t, "When calling GET on", "/api/datasources/", "/api/datasources/", func(sc *scenarioContext) {
 		// Stubs the database query
 		ds := []*datasources.DataSource{
-			{Name: "mmm"},
-			{Name: "ZZZ"},
-			{Name: "BBB"},
-			{Name: "aaa"},
+			{Name: "mmm", UID: "mmm"},
+			{Name: "ZZZ", UID: "ZZZ"},
+			{Name: "BBB", UID: "BBB"},
+			{Name: "aaa", UID: "aaa"},
 		}
+		starService := startest.NewStarServiceFake()
+		starService.ExpectedUserResourceStars = &star.GetUserResourceStarsResult{Stars: []*star.ResourceStar{
+			{Kind: star.KindDatasource, ResourceUID: "BBB"},
+		}}
 
 		// handler func being tested
 		hs := &HTTPServer{
@@ -53,7 +59,8 @@ func TestDataSourcesProxy_userLoggedIn(t *testing.T) {
 			DataSourcesService: &dataSourcesServiceMock{
 				expectedDatasources: ds,
 			},
-			dsGuardian: guardian.ProvideGuardian(),
+			dsGuardian:  guardian.ProvideGuardian(),
+			starService: starService,
 		}
 		sc.handlerFunc = hs.GetDataSources
 		sc.fakeReq("GET", "/api/datasources").exec()
@@ -66,6 +73,8 @@ func TestDataSourcesProxy_userLoggedIn(t *testing.T) {
 		assert.Equal(t, "BBB", respJSON[1]["name"])
 		assert.Equal(t, "mmm", respJSON[2]["name"])
 		assert.Equal(t, "ZZZ", respJSON[3]["name"])
+		assert.Equal(t, true, respJSON[1]["isStarred"])
+		assert.Equal(t, false, respJSON[0]["isStarred"])
 	}, mockSQLStore)
 
 	loggedInUserScenario(t, "Should be able to save a data source when calling DELETE on non-existing",
@@ -446,6 +455,51 @@ func TestAPI_datasources_AccessControl(t *testing.T) {
 	}
 }
 
+func TestAPI_datasources_IfMatch(t *testing.T) {
+	var updated *datasources.UpdateDataSourceCommand
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.Cfg = setting.NewCfg()
+		hs.DataSourcesService = &dataSourcesServiceMock{
+			expectedDatasource: &datasources.DataSource{ID: 1, UID: "1", Name: "test", Version: 3},
+			mockUpdateDataSource: func(ctx context.Context, cmd *datasources.UpdateDataSourceCommand) (*datasources.DataSource, error) {
+				updated = cmd
+				return &datasources.DataSource{}, nil
+			},
+		}
+		hs.accesscontrolService = actest.FakeService{}
+		hs.Live = newTestLive(t, hs.SQLStore)
+	})
+	permissions := []ac.Permission{
+		{Action: datasources.ActionRead, Scope: datasources.ScopeProvider.GetResourceScopeUID("1")},
+		{Action: datasources.ActionWrite, Scope: datasources.ScopeProvider.GetResourceScopeUID("1")},
+		{Action: datasources.ActionDelete, Scope: datasources.ScopeProvider.GetResourceScopeUID("1")},
+	}
+	send := func(method, ifMatch, body string) *http.Response {
+		req := server.NewRequest(method, "/api/datasources/uid/1", strings.NewReader(body))
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		res, err := server.SendJSON(webtest.RequestWithSignedInUser(req, authedUserWithPermissions(1, 1, permissions)))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res
+	}
+	const body = `{"name": "test", "url": "http://localhost:5432", "type": "postgresql", "access": "Proxy"}`
+
+	res := send(http.MethodGet, "", "")
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, `"3"`, res.Header.Get("ETag"))
+
+	assert.Equal(t, http.StatusPreconditionFailed, send(http.MethodPut, `"2"`, body).StatusCode)
+	assert.Nil(t, updated, "the data source isn't updated when the ETag doesn't match")
+	assert.Equal(t, http.StatusPreconditionFailed, send(http.MethodDelete, `"2"`, "").StatusCode)
+
+	require.Equal(t, http.StatusOK, send(http.MethodPut, `"3"`, body).StatusCode)
+	require.NotNil(t, updated)
+	assert.Equal(t, 3, updated.Version, "the data source is updated only if it's still the version checked")
+	assert.Equal(t, http.StatusOK, send(http.MethodDelete, `"1", "3"`, "").StatusCode)
+}
+
 type dataSourcesServiceMock struct {
 	datasources.DataSourceService
 
@@ -17,6 +17,7 @@ import (
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/util/errhttp"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -73,12 +74,15 @@ func (hs *HTTPServer) QueryMetricsV2(c *contextmodel.ReqContext) response.Respon
 
 	handleTimeInQuery := c.Req.Header.Get("X-Query-V2") == "true"
 
+	// the plugin client attributes the requests to the dashboard and the panel of the queries
+	ctx := query.WithRequestAttribution(c.Req.Context(), query.RequestAttributionFromHeaders(c.Req.Header))
+
 	var resp *backend.QueryDataResponse
 	var err error
 	if handleTimeInQuery {
-		resp, err = hs.queryDataService.QueryDataNew(c.Req.Context(), c.SignedInUser, c.SkipDSCache, reqDTO)
+		resp, err = hs.queryDataService.QueryDataNew(ctx, c.SignedInUser, c.SkipDSCache, reqDTO)
 	} else {
-		resp, err = hs.queryDataService.QueryData(c.Req.Context(), c.SignedInUser, c.SkipDSCache, reqDTO)
+		resp, err = hs.queryDataService.QueryData(ctx, c.SignedInUser, c.SkipDSCache, reqDTO)
 	}
 
 	if err != nil {
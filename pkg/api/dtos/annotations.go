@@ -45,3 +45,19 @@ type PostGraphiteAnnotationsCmd struct {
 	Data string `json:"data"`
 	Tags any    `json:"tags"`
 }
+
+// BulkAnnotationsCmd creates, updates and deletes annotations at once
+type BulkAnnotationsCmd struct {
+	Create []PostAnnotationsCmd `json:"create"`
+	// Update are the annotations to update, all their properties are updated like with the update of an annotation
+	Update []UpdateAnnotationsCmd `json:"update"`
+	// Delete are the IDs of the annotations to delete
+	Delete []int64 `json:"delete"`
+}
+
+type BulkAnnotationsResult struct {
+	// Created are the IDs of the created annotations, in the order of the create commands
+	Created []int64 `json:"created"`
+	Updated int     `json:"updated"`
+	Deleted int     `json:"deleted"`
+}
@@ -0,0 +1,31 @@
+package dtos
+
+import "encoding/json"
+
+type BatchCmd struct {
+	// Transactional runs the requests in a database transaction which is rolled back when one of them fails, the
+	// requests must target the same resource type
+	Transactional bool `json:"transactional"`
+	// Requests are executed in order
+	Requests []BatchRequest `json:"requests" binding:"Required"`
+}
+
+type BatchRequest struct {
+	// ID is returned with the response of the request, to match them
+	ID     string          `json:"id,omitempty"`
+	Method string          `json:"method" binding:"Required"`
+	Path   string          `json:"path" binding:"Required"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+type BatchResult struct {
+	// RolledBack is set when a request of a transactional batch failed, none of its changes are kept
+	RolledBack bool            `json:"rolledBack"`
+	Responses  []BatchResponse `json:"responses"`
+}
+
+type BatchResponse struct {
+	ID     string          `json:"id,omitempty"`
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
@@ -48,6 +48,7 @@ type DataSourceListItemDTO struct {
 	IsDefault   bool                 `json:"isDefault"`
 	JsonData    *simplejson.Json     `json:"jsonData,omitempty"`
 	ReadOnly    bool                 `json:"readOnly"`
+	IsStarred   bool                 `json:"isStarred"`
 }
 
 type DataSourceList []DataSourceListItemDTO
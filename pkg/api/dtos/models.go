@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/transformations"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -25,6 +26,7 @@ type LoginCommand struct {
 	User     string `json:"user" binding:"Required"`
 	Password string `json:"password" binding:"Required"`
 	Remember bool   `json:"remember"`
+	MFACode  string `json:"mfaCode"`
 }
 
 type CurrentUser struct {
@@ -52,6 +54,8 @@ type CurrentUser struct {
 	AuthenticatedBy            string             `json:"authenticatedBy"`
 	Permissions                UserPermissionsMap `json:"permissions,omitempty"`
 	Analytics                  AnalyticsSettings  `json:"analytics"`
+	// ImpersonatedBy is the login of the user impersonating the signed in user
+	ImpersonatedBy string `json:"impersonatedBy,omitempty"`
 }
 
 type AnalyticsSettings struct {
@@ -80,6 +84,10 @@ type MetricRequest struct {
 	Queries []*simplejson.Json `json:"queries"`
 	// required: false
 	Debug bool `json:"debug"`
+	// Transformations applied to the frames of the response, in order. Only the organize, groupBy, joinByField and filterByValue transformations are supported.
+	// required: false
+	// example: [ { "id": "organize", "options": { "excludeByName": { "valueTwo": true } } } ]
+	Transformations []transformations.Config `json:"transformations,omitempty"`
 }
 
 func (mr *MetricRequest) GetUniqueDatasourceTypes() []string {
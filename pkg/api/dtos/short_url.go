@@ -1,11 +1,28 @@
 package dtos
 
 type ShortURL struct {
-	UID string `json:"uid"`
-	URL string `json:"url"`
+	UID        string `json:"uid"`
+	URL        string `json:"url"`
+	Path       string `json:"path,omitempty"`
+	CreatedAt  int64  `json:"createdAt,omitempty"`
+	LastSeenAt int64  `json:"lastSeenAt,omitempty"`
+	ExpiresAt  int64  `json:"expiresAt,omitempty"`
+	MaxUses    int64  `json:"maxUses,omitempty"`
+	UseCount   int64  `json:"useCount"`
 }
 
 type CreateShortURLCmd struct {
 	Path string `json:"path"`
 	UID  string `json:"uid,omitempty"`
+	// ExpiresIn is the duration after which the short URL expires, such as 1h or 7d
+	ExpiresIn string `json:"expiresIn,omitempty"`
+	// MaxUses is the number of times the short URL can be used before it expires
+	MaxUses int64 `json:"maxUses,omitempty"`
+}
+
+type ShortURLSearchResult struct {
+	TotalCount int64       `json:"totalCount"`
+	ShortURLs  []*ShortURL `json:"shortUrls"`
+	Page       int         `json:"page"`
+	PerPage    int         `json:"perPage"`
 }
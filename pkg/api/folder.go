@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/libraryelements/model"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/etag"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -120,7 +121,7 @@ func (hs *HTTPServer) GetFolderByUID(c *contextmodel.ReqContext) response.Respon
 		return response.Err(err)
 	}
 
-	return response.JSON(http.StatusOK, folderDTO)
+	return response.JSON(http.StatusOK, folderDTO).SetHeader(etag.Header, etag.FromVersion(int64(folder.Version)))
 }
 
 // swagger:route GET /folders/id/{folder_id} folders getFolderByID
@@ -235,6 +236,7 @@ func (hs *HTTPServer) MoveFolder(c *contextmodel.ReqContext) response.Response {
 // 403: forbiddenError
 // 404: notFoundError
 // 409: conflictError
+// 412: preconditionFailedError
 // 500: internalServerError
 func (hs *HTTPServer) UpdateFolder(c *contextmodel.ReqContext) response.Response {
 	cmd := folder.UpdateFolderCommand{}
@@ -245,6 +247,15 @@ func (hs *HTTPServer) UpdateFolder(c *contextmodel.ReqContext) response.Response
 	cmd.OrgID = c.GetOrgID()
 	cmd.UID = web.Params(c.Req)[":uid"]
 	cmd.SignedInUser = c.SignedInUser
+	if etag.IfMatch(c.Req) != nil {
+		current, rsp := hs.checkFolderIfMatch(c, cmd.UID)
+		if rsp != nil {
+			return rsp
+		}
+		// the folder is updated only if it's still the version checked
+		cmd.Version = current.Version
+		cmd.Overwrite = false
+	}
 	result, err := hs.folderService.Update(c.Req.Context(), &cmd)
 	if err != nil {
 		return apierrors.ToFolderErrorResponse(err)
@@ -254,7 +265,19 @@ func (hs *HTTPServer) UpdateFolder(c *contextmodel.ReqContext) response.Response
 		return response.Err(err)
 	}
 
-	return response.JSON(http.StatusOK, folderDTO)
+	return response.JSON(http.StatusOK, folderDTO).SetHeader(etag.Header, etag.FromVersion(int64(result.Version)))
+}
+
+// checkFolderIfMatch checks the If-Match header of a request changing the folder against the saved folder
+func (hs *HTTPServer) checkFolderIfMatch(c *contextmodel.ReqContext, uid string) (*folder.Folder, response.Response) {
+	current, err := hs.folderService.Get(c.Req.Context(), &folder.GetFolderQuery{OrgID: c.GetOrgID(), UID: &uid, SignedInUser: c.SignedInUser})
+	if err != nil {
+		return nil, apierrors.ToFolderErrorResponse(err)
+	}
+	if err := etag.Check(c.Req, etag.FromVersion(int64(current.Version))); err != nil {
+		return nil, response.Err(err)
+	}
+	return current, nil
 }
 
 // swagger:route DELETE /folders/{folder_uid} folders deleteFolder
@@ -270,8 +293,15 @@ func (hs *HTTPServer) UpdateFolder(c *contextmodel.ReqContext) response.Response
 // 401: unauthorisedError
 // 403: forbiddenError
 // 404: notFoundError
+// 412: preconditionFailedError
 // 500: internalServerError
 func (hs *HTTPServer) DeleteFolder(c *contextmodel.ReqContext) response.Response { // temporarily adding this function to HTTPServer, will be removed from HTTPServer when librarypanels featuretoggle is removed
+	if etag.IfMatch(c.Req) != nil {
+		if _, rsp := hs.checkFolderIfMatch(c, web.Params(c.Req)[":uid"]); rsp != nil {
+			return rsp
+		}
+	}
+
 	err := hs.LibraryElementService.DeleteLibraryElementsInFolder(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
 	if err != nil {
 		if errors.Is(err, model.ErrFolderHasConnectedLibraryElements) {
@@ -428,9 +428,58 @@ func (hs *HTTPServer) getFrontendSettings(c *contextmodel.ReqContext) (*dtos.Fro
 		frontendSettings.ListDashboardScopesEndpoint = hs.Cfg.ScopesListDashboardsURL
 	}
 
+	if frontendSettings.Whitelabeling == nil {
+		whitelabeling, err := hs.brandingWhitelabeling(c.Req.Context(), c.GetOrgID())
+		if err != nil {
+			hs.log.Warn("Failed to get the branding", "orgId", c.GetOrgID(), "error", err)
+		}
+		frontendSettings.Whitelabeling = whitelabeling
+	}
+
 	return frontendSettings, nil
 }
 
+// brandingWhitelabeling returns the branding of the instance, with the overrides of the organization, as the
+// whitelabeling settings of the frontend. It returns nil when nothing is customized.
+func (hs *HTTPServer) brandingWhitelabeling(ctx context.Context, orgID int64) (*dtos.FrontendSettingsWhitelabelingDTO, error) {
+	if hs.brandingService == nil {
+		return nil, nil
+	}
+	b, err := hs.brandingService.GetResolvedBranding(ctx, orgID)
+	if err != nil || b.Version == 0 {
+		return nil, err
+	}
+
+	whitelabeling := &dtos.FrontendSettingsWhitelabelingDTO{
+		Links:         []dtos.FrontendSettingsFooterConfigItemDTO{},
+		LoginTitle:    b.LoginTitle,
+		AppTitle:      optionalString(b.AppTitle),
+		LoginSubtitle: optionalString(b.LoginSubtitle),
+		LoginLogo:     optionalString(b.LoginLogo),
+		MenuLogo:      optionalString(b.MenuLogo),
+		LoadingLogo:   optionalString(b.LoadingLogo),
+	}
+	if b.LoginBackground != "" {
+		whitelabeling.LoginBackground = optionalString(fmt.Sprintf("url(%q)", b.LoginBackground))
+	}
+	for _, link := range b.FooterLinks {
+		whitelabeling.Links = append(whitelabeling.Links, dtos.FrontendSettingsFooterConfigItemDTO{
+			Text:   link.Text,
+			Url:    link.URL,
+			Icon:   link.Icon,
+			Target: link.Target,
+		})
+	}
+	return whitelabeling, nil
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
 func isSupportBundlesEnabled(hs *HTTPServer) bool {
 	return hs.Cfg.SectionWithEnvOverrides("support_bundles").Key("enabled").MustBool(true)
 }
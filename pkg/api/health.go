@@ -2,9 +2,14 @@ package api
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/health"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/web"
 )
 
 func (hs *HTTPServer) databaseHealthy(ctx context.Context) bool {
@@ -23,3 +28,109 @@ func (hs *HTTPServer) databaseHealthy(ctx context.Context) bool {
 	hs.CacheService.Set(cacheKey, healthy, time.Second*5)
 	return healthy
 }
+
+// encryptionHealth returns "ok" or "failing" depending on the health of the encryption providers that depend on
+// an external service, or an empty string when there's none.
+func (hs *HTTPServer) encryptionHealth(ctx context.Context) string {
+	const cacheKey = "encryption-health"
+
+	if cached, found := hs.CacheService.Get(cacheKey); found {
+		return cached.(string)
+	}
+
+	checker, ok := hs.SecretsService.(secrets.ProvidersHealthChecker)
+	if !ok {
+		return ""
+	}
+
+	health := ""
+	for id, err := range checker.CheckProvidersHealth(ctx) {
+		if err != nil {
+			hs.log.Warn("Encryption provider is unhealthy", "provider", id, "error", err)
+			health = "failing"
+			break
+		}
+		health = "ok"
+	}
+
+	hs.CacheService.Set(cacheKey, health, time.Second*30)
+	return health
+}
+
+// swagger:model healthReadyResponse
+type healthReadyResponse struct {
+	Status     health.Status            `json:"status"`
+	Components map[string]health.Status `json:"components"`
+}
+
+// swagger:route GET /health/ready health getHealthReady
+//
+// Returns the readiness of the instance for load balancers. The instance isn't
+// ready, with the http status code 503, when a component listed in the
+// `ready_components` setting of the `[health]` section is failing. The status
+// is degraded when other components are failing.
+//
+// Responses:
+// 200: healthReadyResponse
+// 503: healthReadyResponse
+
+// swagger:route GET /health/components health getHealthComponents
+//
+// Returns the health of each component with the latency of its check. The
+// errors of the checks are only returned when `show_errors` is enabled in the
+// `[health]` section. The http status code is 503 when the instance isn't ready.
+//
+// Responses:
+// 200: healthComponentsResponse
+// 503: healthComponentsResponse
+func (hs *HTTPServer) apiHealthComponentsHandler(ctx *web.Context) {
+	notHeadOrGet := ctx.Req.Method != http.MethodGet && ctx.Req.Method != http.MethodHead
+	path := ctx.Req.URL.Path
+	if notHeadOrGet || hs.healthService == nil || (path != "/api/health/ready" && path != "/api/health/components") {
+		return
+	}
+
+	report := hs.healthService.Check(ctx.Req.Context())
+
+	var data any
+	if path == "/api/health/ready" {
+		ready := healthReadyResponse{Status: report.Status, Components: make(map[string]health.Status, len(report.Components))}
+		for _, component := range report.Components {
+			ready.Components[component.Name] = component.Status
+		}
+		data = ready
+	} else {
+		components := make([]health.Component, len(report.Components))
+		for i, component := range report.Components {
+			// the errors can have details about the infrastructure, they're logged by the health service instead
+			if !hs.Cfg.Health.ShowErrors {
+				component.Error = ""
+			}
+			components[i] = component
+		}
+		data = health.Report{Status: report.Status, Components: components}
+	}
+
+	ctx.Resp.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	ctx.Resp.Header().Set("Cache-Control", "no-store")
+	if report.Ready() {
+		ctx.Resp.WriteHeader(http.StatusOK)
+	} else {
+		ctx.Resp.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	dataBytes, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		hs.log.Error("Failed to encode data", "err", err)
+		return
+	}
+	if _, err := ctx.Resp.Write(dataBytes); err != nil {
+		hs.log.Error("Failed to write to response", "err", err)
+	}
+}
+
+// swagger:response healthComponentsResponse
+type HealthComponentsResponse struct {
+	// in:body
+	Body health.Report `json:"body"`
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -11,6 +12,9 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/db/dbtest"
 	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/health"
+	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -170,6 +174,107 @@ func TestHealthAPI_DatabaseHealthCached(t *testing.T) {
 	require.True(t, healthy.(bool))
 }
 
+type fakeHealthCheckedSecrets struct {
+	secrets.Service
+	err error
+}
+
+func (f fakeHealthCheckedSecrets) CheckProvidersHealth(context.Context) map[secrets.ProviderID]error {
+	return map[secrets.ProviderID]error{"vault.v1": f.err}
+}
+
+func TestHealthAPI_Encryption(t *testing.T) {
+	m, hs := setupHealthAPITestEnvironment(t)
+	hs.Cfg.Anonymous.HideVersion = true
+	hs.log = log.NewNopLogger()
+	hs.SecretsService = fakeHealthCheckedSecrets{err: errors.New("vault is sealed")}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+
+	// the encryption providers don't change the status code
+	require.Equal(t, 200, rec.Code)
+	expectedBody := `
+		{
+			"database": "ok",
+			"encryption": "failing"
+		}
+	`
+	require.JSONEq(t, expectedBody, rec.Body.String())
+
+	hs.CacheService.Delete("encryption-health")
+	hs.SecretsService = fakeHealthCheckedSecrets{}
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+
+	require.Equal(t, 200, rec.Code)
+	expectedBody = `
+		{
+			"database": "ok",
+			"encryption": "ok"
+		}
+	`
+	require.JSONEq(t, expectedBody, rec.Body.String())
+}
+
+type fakeHealthService struct {
+	report *health.Report
+}
+
+func (f *fakeHealthService) Register(string, health.CheckFunc) {}
+
+func (f *fakeHealthService) Check(context.Context) *health.Report {
+	return f.report
+}
+
+func TestHealthAPI_Components(t *testing.T) {
+	m, hs := setupHealthAPITestEnvironment(t)
+	hs.healthService = &fakeHealthService{report: &health.Report{
+		Status: health.StatusDegraded,
+		Components: []health.Component{
+			{Name: health.ComponentDatabase, Status: health.StatusOK, Required: true, LatencyMs: 2},
+			{Name: health.ComponentRemoteCache, Status: health.StatusFailing, LatencyMs: 2000, Error: "dial tcp 10.0.0.1:6379: i/o timeout"},
+		},
+	}}
+
+	t.Run("should return the status of the components for the readiness", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/health/ready", nil))
+
+		require.Equal(t, 200, rec.Code)
+		require.JSONEq(t, `{"status": "degraded", "components": {"database": "ok", "remote_cache": "failing"}}`, rec.Body.String())
+	})
+
+	t.Run("should hide the errors of the components unless enabled", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/health/components", nil))
+
+		require.Equal(t, 200, rec.Code)
+		require.NotContains(t, rec.Body.String(), "i/o timeout")
+
+		hs.Cfg.Health.ShowErrors = true
+		rec = httptest.NewRecorder()
+		m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/health/components", nil))
+
+		require.Equal(t, 200, rec.Code)
+		require.Contains(t, rec.Body.String(), "i/o timeout")
+	})
+
+	t.Run("should return 503 when the instance isn't ready", func(t *testing.T) {
+		hs.healthService = &fakeHealthService{report: &health.Report{
+			Status:     health.StatusFailing,
+			Components: []health.Component{{Name: health.ComponentDatabase, Status: health.StatusFailing, Required: true}},
+		}}
+
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/health/ready", nil))
+
+		require.Equal(t, 503, rec.Code)
+		require.JSONEq(t, `{"status": "failing", "components": {"database": "failing"}}`, rec.Body.String())
+	})
+}
+
 func setupHealthAPITestEnvironment(t *testing.T, cbs ...func(*setting.Cfg)) (*web.Mux, *HTTPServer) {
 	t.Helper()
 
@@ -185,5 +290,7 @@ func setupHealthAPITestEnvironment(t *testing.T, cbs ...func(*setting.Cfg)) (*we
 	}
 
 	m.Get("/api/health", hs.apiHealthHandler)
+	m.Get("/api/health/ready", hs.apiHealthComponentsHandler)
+	m.Get("/api/health/components", hs.apiHealthComponentsHandler)
 	return m, hs
 }
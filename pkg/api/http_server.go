@@ -46,12 +46,15 @@ import (
 	"github.com/grafana/grafana/pkg/plugins/pluginscdn"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationingest"
 	"github.com/grafana/grafana/pkg/services/anonymous"
 	"github.com/grafana/grafana/pkg/services/apikey"
 	grafanaapiserver "github.com/grafana/grafana/pkg/services/apiserver"
 	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/branding"
 	"github.com/grafana/grafana/pkg/services/cleanup"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/correlations"
@@ -62,9 +65,12 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/datasources/guardian"
 	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/exploresession"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/health"
 	"github.com/grafana/grafana/pkg/services/hooks"
+	"github.com/grafana/grafana/pkg/services/impersonation"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/librarypanels"
 	"github.com/grafana/grafana/pkg/services/licensing"
@@ -72,6 +78,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/live/pushhttp"
 	"github.com/grafana/grafana/pkg/services/login"
 	loginAttempt "github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/mfa"
 	"github.com/grafana/grafana/pkg/services/navtree"
 	"github.com/grafana/grafana/pkg/services/ngalert"
 	"github.com/grafana/grafana/pkg/services/notifications"
@@ -91,6 +98,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
 	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/ratelimit"
+	"github.com/grafana/grafana/pkg/services/recenttraces"
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/services/searchV2"
@@ -109,6 +118,7 @@ import (
 	tempUser "github.com/grafana/grafana/pkg/services/temp_user"
 	"github.com/grafana/grafana/pkg/services/updatemanager"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/useractivity"
 	"github.com/grafana/grafana/pkg/services/validations"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
@@ -221,6 +231,16 @@ type HTTPServer struct {
 	namespacer           request.NamespaceMapper
 	anonService          anonymous.Service
 	userVerifier         user.Verifier
+	mfaService           mfa.Service
+	impersonationService impersonation.Service
+	auditLogService      auditlog.Service
+	rateLimitService     ratelimit.Service
+	recentTracesService  recenttraces.Service
+	healthService        health.Service
+	annotationIngest     *annotationingest.Service
+	userActivityService  useractivity.Service
+	exploreSessions      exploresession.Service
+	brandingService      branding.Service
 	tlsCerts             TLSCerts
 }
 
@@ -271,7 +291,10 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 	annotationRepo annotations.Repository, tagService tag.Service, searchv2HTTPService searchV2.SearchHTTPService, oauthTokenService oauthtoken.OAuthTokenService,
 	statsService stats.Service, authnService authn.Service, pluginsCDNService *pluginscdn.Service, promGatherer prometheus.Gatherer,
 	starApi *starApi.API, promRegister prometheus.Registerer, clientConfigProvider grafanaapiserver.DirectRestConfigProvider, anonService anonymous.Service,
-	userVerifier user.Verifier, pluginPreinstall pluginchecker.Preinstall,
+	userVerifier user.Verifier, pluginPreinstall pluginchecker.Preinstall, mfaService mfa.Service,
+	impersonationService impersonation.Service, auditLogService auditlog.Service, rateLimitService ratelimit.Service,
+	recentTracesService recenttraces.Service, healthService health.Service, annotationIngest *annotationingest.Service,
+	userActivityService useractivity.Service, exploreSessionService exploresession.Service, brandingService branding.Service,
 ) (*HTTPServer, error) {
 	web.Env = cfg.Env
 	m := web.New()
@@ -376,6 +399,16 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 		namespacer:                   request.GetNamespaceMapper(cfg),
 		anonService:                  anonService,
 		userVerifier:                 userVerifier,
+		mfaService:                   mfaService,
+		impersonationService:         impersonationService,
+		auditLogService:              auditLogService,
+		rateLimitService:             rateLimitService,
+		recentTracesService:          recentTracesService,
+		userActivityService:          userActivityService,
+		healthService:                healthService,
+		annotationIngest:             annotationIngest,
+		exploreSessions:              exploreSessionService,
+		brandingService:              brandingService,
 	}
 	if hs.Listener != nil {
 		hs.log.Debug("Using provided listener")
@@ -599,6 +632,10 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 	m.Use(middleware.RequestTracing(hs.tracer, middleware.SkipTracingPaths))
 	m.Use(middleware.RequestMetrics(hs.Features, hs.Cfg, hs.promRegister))
 
+	if hs.Cfg.RecentTraces.Size > 0 {
+		m.UseMiddleware(middleware.RecentTraces(hs.recentTracesService))
+	}
+
 	m.UseMiddleware(hs.LoggerMiddleware.Middleware())
 
 	if hs.Cfg.EnableGzip {
@@ -634,6 +671,7 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 	// and should not be redirected or rejected.
 	m.Use(hs.healthzHandler)
 	m.Use(hs.apiHealthHandler)
+	m.Use(hs.apiHealthComponentsHandler)
 	m.Use(hs.metricsEndpoint)
 	m.Use(hs.pluginMetricsEndpoint)
 	m.Use(hs.frontendLogEndpoints())
@@ -641,6 +679,18 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 	m.UseMiddleware(hs.ContextHandler.Middleware)
 	m.Use(middleware.OrgRedirect(hs.Cfg, hs.userService))
 
+	if hs.Cfg.TenantMetrics.Enabled {
+		m.UseMiddleware(middleware.TenantMetrics(hs.Cfg, hs.promRegister))
+	}
+
+	if hs.Cfg.RateLimit.Enabled {
+		m.UseMiddleware(middleware.RateLimit(hs.rateLimitService))
+	}
+
+	if hs.Cfg.AuditLog.Enabled {
+		m.UseMiddleware(middleware.AuditLog(hs.auditLogService))
+	}
+
 	// needs to be after context handler
 	if hs.Cfg.EnforceDomain {
 		m.Use(middleware.ValidateHostHeader(hs.Cfg))
@@ -698,13 +748,16 @@ type healthResponse struct {
 	Version          string `json:"version,omitempty"`
 	Commit           string `json:"commit,omitempty"`
 	EnterpriseCommit string `json:"enterpriseCommit,omitempty"`
+	Encryption       string `json:"encryption,omitempty"`
 }
 
 // swagger:route GET /health health getHealth
 //
 // apiHealthHandler will return ok if Grafana's web server is running and it
 // can access the database. If the database cannot be accessed it will return
-// http status code 503.
+// http status code 503. The health of the encryption providers that depend on an
+// external service, like HashiCorp Vault, is reported without changing the status
+// code, since the cached data keys can still be used while they're unavailable.
 //
 // Responses:
 // 200: healthResponse
@@ -725,6 +778,7 @@ func (hs *HTTPServer) apiHealthHandler(ctx *web.Context) {
 			data.EnterpriseCommit = hs.Cfg.EnterpriseBuildCommit
 		}
 	}
+	data.Encryption = hs.encryptionHealth(ctx.Req.Context())
 
 	if !hs.databaseHealthy(ctx.Req.Context()) {
 		data.Database = "failing"
@@ -922,6 +976,18 @@ func (hs *HTTPServer) configureTLS() error {
 		CipherSuites: tlsCiphers,
 	}
 
+	if hs.Cfg.MTLSAuth.Enabled {
+		clientCAs, err := loadClientCAs(hs.Cfg.MTLSAuth.CACertFile)
+		if err != nil {
+			return err
+		}
+		tlsCfg.ClientCAs = clientCAs
+		tlsCfg.ClientAuth = tls.VerifyClientCertIfGiven
+		if hs.Cfg.MTLSAuth.ClientAuth == setting.MTLSClientAuthRequired {
+			tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
 	hs.httpSrv.TLSConfig = tlsCfg
 
 	if hs.Cfg.Protocol == setting.HTTP2Scheme {
@@ -935,6 +1001,26 @@ func (hs *HTTPServer) configureTLS() error {
 	return nil
 }
 
+// loadClientCAs loads the CA bundle TLS client certificates are verified against
+func loadClientCAs(caCertFile string) (*x509.CertPool, error) {
+	if caCertFile == "" {
+		return nil, errors.New("cannot enable mTLS authentication without ca_cert_file")
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `caCertFile` comes from the [auth.mtls] config.
+	caCerts, err := os.ReadFile(caCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCerts) {
+		return nil, fmt.Errorf("no valid certificates found in client CA file %s", caCertFile)
+	}
+	return pool, nil
+}
+
 func (hs *HTTPServer) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
 	hs.tlsCerts.certLock.RLock()
 	defer hs.tlsCerts.certLock.RUnlock()
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	claims "github.com/grafana/authlib/types"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/network"
+	"github.com/grafana/grafana/pkg/services/authn"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/impersonation"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route POST /admin/users/{user_id}/impersonate admin_users adminStartImpersonation
+//
+// Sign in as a user of the current organization.
+//
+// Issues a session for the user and replaces the session of the caller until the impersonation stops or expires.
+// Requests made during the impersonation are limited to the current organization and are logged with both users.
+// You need to have a permission with action `users:impersonate` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminStartImpersonationResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) AdminStartImpersonation(c *contextmodel.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	// Impersonation replaces the session of the caller, so it requires one
+	if !c.IsIdentityType(claims.TypeUser) || c.UserToken == nil {
+		return response.Error(http.StatusForbidden, "impersonation requires a user session", nil)
+	}
+
+	form := impersonation.StartImpersonationForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	impersonatorID, err := c.GetInternalID()
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to parse user id", err)
+	}
+
+	session, token, err := hs.impersonationService.Start(c.Req.Context(), &impersonation.StartCommand{
+		OrgID:             c.GetOrgID(),
+		ImpersonatorID:    impersonatorID,
+		ImpersonatorLogin: c.GetLogin(),
+		ImpersonatorToken: c.UserToken,
+		TargetUserID:      userID,
+		Reason:            form.Reason,
+		ClientIP:          clientIP(c),
+		UserAgent:         c.Req.UserAgent(),
+	})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return response.Error(http.StatusNotFound, user.ErrUserNotFound.Error(), nil)
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to start impersonation", err)
+	}
+
+	authn.WriteSessionCookie(c.Resp, hs.Cfg, token)
+	return response.JSON(http.StatusOK, session)
+}
+
+// swagger:route POST /user/impersonation/stop signed_in_user stopImpersonation
+//
+// Stop impersonating the actual User and sign back in as the impersonator.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) StopImpersonation(c *contextmodel.ReqContext) response.Response {
+	if c.Impersonator == nil {
+		return response.Err(impersonation.ErrNotImpersonating.Errorf("user %s is not impersonated", c.GetID()))
+	}
+
+	token, err := hs.impersonationService.Stop(c.Req.Context(), &impersonation.StopCommand{
+		UID:       c.Impersonator.SessionUID,
+		ClientIP:  clientIP(c),
+		UserAgent: c.Req.UserAgent(),
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to stop impersonation", err)
+	}
+
+	authn.WriteSessionCookie(c.Resp, hs.Cfg, token)
+	return response.Success("Impersonation stopped")
+}
+
+// swagger:route GET /admin/impersonations admin_users adminSearchImpersonations
+//
+// List impersonation sessions, most recent first.
+//
+// You need to have a permission with action `users:read` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminSearchImpersonationsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminSearchImpersonations(c *contextmodel.ReqContext) response.Response {
+	sessions, err := hs.impersonationService.Search(c.Req.Context(), &impersonation.SearchQuery{
+		OrgID:          c.QueryInt64("orgId"),
+		ImpersonatorID: c.QueryInt64("impersonatorId"),
+		TargetUserID:   c.QueryInt64("userId"),
+		ActiveOnly:     c.QueryBool("active"),
+		Limit:          c.QueryInt("limit"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to search impersonation sessions", err)
+	}
+
+	return response.JSON(http.StatusOK, sessions)
+}
+
+// swagger:route DELETE /admin/impersonations/{impersonation_uid} admin_users adminTerminateImpersonation
+//
+// End an impersonation session.
+//
+// The impersonator is signed out. You need to have a permission with action `users:logout` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) AdminTerminateImpersonation(c *contextmodel.ReqContext) response.Response {
+	if err := hs.impersonationService.Terminate(c.Req.Context(), web.Params(c.Req)[":uid"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to end impersonation session", err)
+	}
+
+	return response.Success("Impersonation session ended")
+}
+
+// swagger:route GET /org/impersonation org getCurrentOrgImpersonationSettings
+//
+// Get the impersonation settings of the current organization.
+//
+// Responses:
+// 200: getCurrentOrgImpersonationSettingsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetCurrentOrgImpersonationSettings(c *contextmodel.ReqContext) response.Response {
+	settings, err := hs.impersonationService.GetOrgSettings(c.Req.Context(), c.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get impersonation settings", err)
+	}
+
+	return response.JSON(http.StatusOK, settings)
+}
+
+// swagger:route PUT /org/impersonation org updateCurrentOrgImpersonationSettings
+//
+// Update the impersonation settings of the current organization.
+//
+// Disabling impersonation ends the active impersonation sessions of the organization.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) UpdateCurrentOrgImpersonationSettings(c *contextmodel.ReqContext) response.Response {
+	settings := impersonation.OrgSettings{}
+	if err := web.Bind(c.Req, &settings); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	settings.OrgID = c.GetOrgID()
+
+	if err := hs.impersonationService.SetOrgSettings(c.Req.Context(), &settings); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to update impersonation settings", err)
+	}
+
+	return response.Success("Impersonation settings updated")
+}
+
+func clientIP(c *contextmodel.ReqContext) net.IP {
+	ip, err := network.GetIPFromAddress(c.RemoteAddr())
+	if err != nil {
+		return nil
+	}
+	return ip
+}
+
+// swagger:parameters adminStartImpersonation
+type AdminStartImpersonationParams struct {
+	// in:path
+	// required:true
+	UserID int64 `json:"user_id"`
+	// in:body
+	// required:true
+	Body impersonation.StartImpersonationForm `json:"body"`
+}
+
+// swagger:parameters adminSearchImpersonations
+type AdminSearchImpersonationsParams struct {
+	// in:query
+	// required:false
+	OrgID int64 `json:"orgId"`
+	// in:query
+	// required:false
+	ImpersonatorID int64 `json:"impersonatorId"`
+	// in:query
+	// required:false
+	UserID int64 `json:"userId"`
+	// Only list the sessions that have not ended or expired
+	// in:query
+	// required:false
+	Active bool `json:"active"`
+	// in:query
+	// required:false
+	// default:100
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters adminTerminateImpersonation
+type AdminTerminateImpersonationParams struct {
+	// in:path
+	// required:true
+	ImpersonationUID string `json:"impersonation_uid"`
+}
+
+// swagger:parameters updateCurrentOrgImpersonationSettings
+type UpdateCurrentOrgImpersonationSettingsParams struct {
+	// in:body
+	// required:true
+	Body impersonation.OrgSettings `json:"body"`
+}
+
+// swagger:response adminStartImpersonationResponse
+type AdminStartImpersonationResponse struct {
+	// in:body
+	Body impersonation.Session `json:"body"`
+}
+
+// swagger:response adminSearchImpersonationsResponse
+type AdminSearchImpersonationsResponse struct {
+	// in:body
+	Body []*impersonation.Session `json:"body"`
+}
+
+// swagger:response getCurrentOrgImpersonationSettingsResponse
+type GetCurrentOrgImpersonationSettingsResponse struct {
+	// in:body
+	Body impersonation.OrgSettings `json:"body"`
+}
@@ -194,6 +194,15 @@ func (hs *HTTPServer) setIndexViewData(c *contextmodel.ReqContext) (*dtos.IndexV
 		Assets:                              assets,
 	}
 
+	if whitelabeling := settings.Whitelabeling; whitelabeling != nil {
+		if whitelabeling.AppTitle != nil {
+			data.AppTitle = *whitelabeling.AppTitle
+		}
+		if whitelabeling.LoadingLogo != nil {
+			data.LoadingLogo = *whitelabeling.LoadingLogo
+		}
+	}
+
 	if hs.Cfg.CSPEnabled {
 		data.CSPEnabled = true
 		data.CSPContent = middleware.ReplacePolicyVariables(hs.Cfg.CSPTemplate, appURL, c.RequestNonce)
@@ -213,6 +222,10 @@ func (hs *HTTPServer) setIndexViewData(c *contextmodel.ReqContext) (*dtos.IndexV
 		data.User.Name = data.User.Login
 	}
 
+	if c.Impersonator != nil {
+		data.User.ImpersonatedBy = c.Impersonator.Login
+	}
+
 	hs.HooksService.RunIndexDataHooks(&data, c)
 
 	data.NavTree.ApplyCostManagementIA()
@@ -249,7 +262,7 @@ func (hs *HTTPServer) getUserOrgCount(c *contextmodel.ReqContext, userID int64)
 		return 1
 	}
 
-	userOrgs, err := hs.orgService.GetUserOrgList(c.Req.Context(), &org.GetUserOrgListQuery{UserID: userID})
+	userOrgs, err := hs.orgService.GetUserOrgList(c.Req.Context(), &org.GetUserOrgListQuery{UserID: userID, ExcludeArchived: true})
 	if err != nil {
 		hs.log.FromContext(c.Req.Context()).Error("Failed to count user orgs", "userId", userID, "error", err)
 		return 1
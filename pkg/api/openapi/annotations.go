@@ -0,0 +1,59 @@
+package openapi
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// annotationsServerPath is the path the operations of the annotations are relative to
+const annotationsServerPath = "/api"
+
+var pathParamPattern = regexp.MustCompile(`\{[^}]+\}`)
+
+// Annotations is the OpenAPI 3 document built from the swagger annotations of the handlers (public/openapi3.json).
+// The routes that aren't described with Describe are documented with their operations.
+type Annotations struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas   map[string]json.RawMessage `json:"schemas"`
+		Responses map[string]json.RawMessage `json:"responses"`
+	} `json:"components"`
+
+	// shapes indexes the paths by their shape, the names of their parameters left out
+	shapes map[string]string
+}
+
+// ParseAnnotations parses the OpenAPI 3 document built from the swagger annotations
+func ParseAnnotations(data []byte) (*Annotations, error) {
+	a := &Annotations{}
+	if err := json.Unmarshal(data, a); err != nil {
+		return nil, err
+	}
+	a.shapes = make(map[string]string, len(a.Paths))
+	for path := range a.Paths {
+		a.shapes[pathShape(path)] = path
+	}
+	return a, nil
+}
+
+// operation returns the annotated operation of a route and its path, whose parameters can be named differently
+// than in the route pattern
+func (a *Annotations) operation(path, method string) (json.RawMessage, string) {
+	if a == nil || !strings.HasPrefix(path, annotationsServerPath+"/") {
+		return nil, ""
+	}
+	annotated, ok := a.shapes[pathShape(strings.TrimPrefix(path, annotationsServerPath))]
+	if !ok {
+		return nil, ""
+	}
+	op, ok := a.Paths[annotated][strings.ToLower(method)]
+	if !ok {
+		return nil, ""
+	}
+	return op, annotationsServerPath + annotated
+}
+
+func pathShape(path string) string {
+	return pathParamPattern.ReplaceAllString(strings.TrimSuffix(path, "/"), "{}")
+}
@@ -0,0 +1,428 @@
+// Package openapi generates the OpenAPI 3 document of the HTTP API from the registered routes. The paths and the
+// permissions of the operations are taken from the route registrations, the models of their requests, responses
+// and typed errors from the operations the handlers are described with. The handlers that aren't described are
+// documented with the operations of their swagger annotations.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	version = "3.0.3"
+
+	publicErrorSchema = "PublicError"
+	errorSchema       = "ErrorResponse"
+)
+
+// paramPattern matches the named parameters of the route patterns, with their optional regular expression
+var paramPattern = regexp.MustCompile(`^:([A-Za-z0-9_]+)(\(.*\))?$`)
+
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []SecurityRequirement `json:"security,omitempty"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem holds the operations of a path by lower case method
+type PathItem map[string]*OperationObject
+
+type OperationObject struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	// AccessControl lists the permissions required by the operation
+	AccessControl *AccessControl `json:"x-grafana-access-control,omitempty"`
+
+	// annotated is the operation of the swagger annotations, encoded instead of the fields above but AccessControl
+	annotated json.RawMessage
+}
+
+func (op *OperationObject) MarshalJSON() ([]byte, error) {
+	type plain OperationObject
+	if op.annotated == nil {
+		return json.Marshal((*plain)(op))
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(op.annotated, &fields); err != nil {
+		return nil, err
+	}
+	if op.AccessControl != nil {
+		accessControl, err := json.Marshal(op.AccessControl)
+		if err != nil {
+			return nil, err
+		}
+		fields["x-grafana-access-control"] = accessControl
+	}
+	return json.Marshal(fields)
+}
+
+type AccessControl struct {
+	// Evaluator is how the actions are combined, for example "all of dashboards:read, dashboards:write"
+	Evaluator string   `json:"evaluator"`
+	Actions   []string `json:"actions"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+	// Responses are the responses of the swagger annotations
+	Responses       map[string]json.RawMessage `json:"responses,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme"`
+	Description string `json:"description,omitempty"`
+}
+
+type SecurityRequirement map[string][]string
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+
+	// annotated is the schema of the swagger annotations, encoded instead of the fields above
+	annotated json.RawMessage
+}
+
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.annotated != nil {
+		return s.annotated, nil
+	}
+	type plain Schema
+	return json.Marshal((*plain)(s))
+}
+
+// Generate returns the document of the API routes, the routes outside of /api/ serve the frontend and aren't
+// documented. Routes of any method proxy requests and aren't documented either. The routes that are neither
+// described nor annotated are documented with their path and permissions only.
+func Generate(info Info, serverURL string, routes []routing.Route, annotations *Annotations) *Document {
+	s := newSchemas()
+	s.components[publicErrorSchema] = s.object(reflect.TypeOf(errutil.PublicError{}))
+	s.components[errorSchema] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"message": {Type: "string"},
+		},
+	}
+
+	doc := &Document{
+		OpenAPI: version,
+		Info:    info,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: s.components,
+			SecuritySchemes: map[string]*SecurityScheme{
+				"basic": {Type: "http", Scheme: "basic"},
+				"bearer": {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "Service account token",
+				},
+			},
+		},
+		Security: []SecurityRequirement{{"basic": {}}, {"bearer": {}}},
+	}
+	if serverURL != "" {
+		doc.Servers = []Server{{URL: strings.TrimSuffix(serverURL, "/")}}
+	}
+
+	for _, route := range routes {
+		if route.Method == "*" || !strings.HasPrefix(route.Pattern, "/api/") {
+			continue
+		}
+		path, pathParams := convertPattern(route.Pattern)
+		annotated, annotatedPath := annotations.operation(path, route.Method)
+		if annotated != nil {
+			path = annotatedPath
+		}
+		method := strings.ToLower(route.Method)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = PathItem{}
+			doc.Paths[path] = item
+		}
+		if _, ok := item[method]; ok {
+			continue
+		}
+		item[method] = operation(s, route, path, pathParams, annotated)
+	}
+
+	if annotations != nil {
+		// the described models are named after their package, only the errors can have the same names
+		for name, schema := range annotations.Components.Schemas {
+			if _, ok := doc.Components.Schemas[name]; !ok {
+				doc.Components.Schemas[name] = &Schema{annotated: schema}
+			}
+		}
+		doc.Components.Responses = annotations.Components.Responses
+	}
+
+	return doc
+}
+
+func operation(s *schemas, route routing.Route, path string, pathParams []string, annotatedOp json.RawMessage) *OperationObject {
+	var described *Operation
+	var evaluators []ac.Evaluator
+	for _, handler := range route.Handlers {
+		for handler != nil {
+			switch h := handler.(type) {
+			case *describedHandler:
+				described = &h.operation
+			case *ac.AuthorizeHandler:
+				evaluators = append(evaluators, h.Evaluator())
+			}
+			annotated, ok := handler.(web.AnnotatedHandler)
+			if !ok {
+				break
+			}
+			handler = annotated.Unwrap()
+		}
+	}
+	if described == nil && annotatedOp != nil {
+		return annotatedOperation(route.Method, path, annotatedOp, evaluators)
+	}
+	if described == nil {
+		described = &Operation{}
+	}
+
+	op := &OperationObject{
+		OperationID: described.ID,
+		Summary:     described.Summary,
+		Description: described.Description,
+		Tags:        described.Tags,
+		Deprecated:  described.Deprecated,
+		Responses:   map[string]*Response{},
+	}
+	if op.OperationID == "" {
+		op.OperationID = operationID(route.Method, path)
+	}
+	if len(op.Tags) == 0 {
+		op.Tags = defaultTags(path)
+	}
+
+	for _, name := range pathParams {
+		op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	if described.Query != nil {
+		t := reflect.TypeOf(described.Query)
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		for _, f := range fields(t) {
+			if f.embedded != nil {
+				continue
+			}
+			op.Parameters = append(op.Parameters, Parameter{Name: f.name, In: "query", Required: f.required, Schema: s.schema(f.field.Type)})
+		}
+	}
+
+	if described.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: s.of(described.Request)}},
+		}
+	}
+
+	status := described.ResponseStatus
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &Response{Description: http.StatusText(status)}
+	if described.Response != nil {
+		success.Content = map[string]MediaType{"application/json": {Schema: s.of(described.Response)}}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+
+	addErrors(op, described.Errors)
+
+	op.AccessControl = accessControl(evaluators)
+	if op.AccessControl != nil {
+		setDefaultResponse(op, http.StatusForbidden, "Access denied, the request is missing permissions: "+op.AccessControl.Evaluator)
+	}
+	setDefaultResponse(op, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+	setDefaultResponse(op, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+
+	return op
+}
+
+// annotatedOperation documents a route with the operation of its swagger annotations, which already lists the
+// error responses
+func annotatedOperation(method, path string, annotated json.RawMessage, evaluators []ac.Evaluator) *OperationObject {
+	op := &OperationObject{annotated: annotated}
+	// the fields are decoded for the callers, the responses are left to the annotations
+	var fields struct {
+		OperationID string   `json:"operationId"`
+		Summary     string   `json:"summary"`
+		Description string   `json:"description"`
+		Tags        []string `json:"tags"`
+		Deprecated  bool     `json:"deprecated"`
+	}
+	if err := json.Unmarshal(annotated, &fields); err == nil {
+		op.OperationID = fields.OperationID
+		op.Summary = fields.Summary
+		op.Description = fields.Description
+		op.Tags = fields.Tags
+		op.Deprecated = fields.Deprecated
+	}
+	if op.OperationID == "" {
+		op.OperationID = operationID(method, path)
+	}
+	op.AccessControl = accessControl(evaluators)
+	return op
+}
+
+func accessControl(evaluators []ac.Evaluator) *AccessControl {
+	if len(evaluators) == 0 {
+		return nil
+	}
+	evaluator := evaluators[0]
+	if len(evaluators) > 1 {
+		evaluator = ac.EvalAll(evaluators...)
+	}
+	return &AccessControl{Evaluator: evaluator.String(), Actions: ac.EvaluatorActions(evaluator)}
+}
+
+// addErrors documents the typed errors with the envelope of errutil.PublicError, the message ids returned with a
+// status are listed in its schema
+func addErrors(op *OperationObject, errs []errutil.Base) {
+	messageIDs := map[int][]string{}
+	var statuses []int
+	for _, base := range errs {
+		public := base.Errorf("").Public()
+		if _, ok := messageIDs[public.StatusCode]; !ok {
+			statuses = append(statuses, public.StatusCode)
+		}
+		if !slices.Contains(messageIDs[public.StatusCode], public.MessageID) {
+			messageIDs[public.StatusCode] = append(messageIDs[public.StatusCode], public.MessageID)
+		}
+	}
+
+	for _, status := range statuses {
+		op.Responses[strconv.Itoa(status)] = &Response{
+			Description: fmt.Sprintf("%s: %s", http.StatusText(status), strings.Join(messageIDs[status], ", ")),
+			Content: map[string]MediaType{"application/json": {Schema: &Schema{
+				AllOf: []*Schema{
+					{Ref: "#/components/schemas/" + publicErrorSchema},
+					{Type: "object", Properties: map[string]*Schema{"messageId": {Type: "string", Enum: messageIDs[status]}}},
+				},
+			}}},
+		}
+	}
+}
+
+// setDefaultResponse documents an error returned by the middlewares or handlers with the legacy envelope, unless the
+// operation documents typed errors for the status
+func setDefaultResponse(op *OperationObject, status int, description string) {
+	code := strconv.Itoa(status)
+	if _, ok := op.Responses[code]; ok {
+		return
+	}
+	op.Responses[code] = &Response{
+		Description: description,
+		Content: map[string]MediaType{"application/json": {Schema: &Schema{
+			Ref: "#/components/schemas/" + errorSchema,
+		}}},
+	}
+}
+
+// convertPattern converts a route pattern to an OpenAPI path and returns the names of its parameters
+func convertPattern(pattern string) (string, []string) {
+	segments := strings.Split(pattern, "/")
+	var params []string
+	for i, segment := range segments {
+		if segment == "*" {
+			segments[i] = "{path}"
+			params = append(params, "path")
+			continue
+		}
+		if m := paramPattern.FindStringSubmatch(segment); m != nil {
+			segments[i] = "{" + m[1] + "}"
+			params = append(params, m[1])
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationID derives an id from the method and path, for example getApiWebhooksUidDeliveries
+func operationID(method, path string) string {
+	var id strings.Builder
+	id.WriteString(strings.ToLower(method))
+	for _, word := range strings.FieldsFunc(path, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		id.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return id.String()
+}
+
+// defaultTags tags the operation with the first segment of its path after /api/
+func defaultTags(path string) []string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/api/"), "/")
+	if segment == "" || strings.HasPrefix(segment, "{") {
+		return nil
+	}
+	return []string{segment}
+}
@@ -0,0 +1,232 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type testFolder struct {
+	UID     string      `json:"uid"`
+	Title   string      `json:"title,omitempty"`
+	Parent  *testFolder `json:"parent,omitempty"`
+	Created time.Time   `json:"created"`
+	Version int64       `json:"version,string"`
+}
+
+type testCreateCommand struct {
+	Title string            `json:"title" binding:"Required"`
+	Tags  []string          `json:"tags"`
+	Meta  map[string]string `json:"meta"`
+	OrgID int64             `json:"-"`
+}
+
+type testSearch struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit"`
+}
+
+var errTestInvalidTitle = errutil.BadRequest("test.invalid-title")
+var errTestTooMany = errutil.BadRequest("test.too-many")
+var errTestNotFound = errutil.NotFound("test.not-found")
+
+func TestGenerate(t *testing.T) {
+	handler := routing.Wrap(func(c *contextmodel.ReqContext) response.Response { return nil })
+	authorize := ac.Middleware(actest.FakeAccessControl{})
+
+	rr := routing.NewRouteRegister()
+	rr.Get("/login", handler)
+	rr.Any("/api/datasources/proxy/:id/*", handler)
+	rr.Group("/api/folders", func(folderRoute routing.RouteRegister) {
+		folderRoute.Get("/", authorize(ac.EvalPermission("folders:read")), Describe(Operation{
+			Query:    testSearch{},
+			Response: []testFolder{},
+		}, handler))
+		folderRoute.Post("/", authorize(ac.EvalAll(ac.EvalPermission("folders:create"), ac.EvalPermission("folders:read"))), Describe(Operation{
+			ID:       "createFolder",
+			Summary:  "Create a folder.",
+			Request:  testCreateCommand{},
+			Response: testFolder{},
+			Errors:   []errutil.Base{errTestInvalidTitle, errTestTooMany},
+		}, handler))
+		folderRoute.Get("/:uid([a-z]+)", handler)
+		folderRoute.Delete("/:uid([a-z]+)", Describe(Operation{Errors: []errutil.Base{errTestNotFound}}, handler))
+	})
+
+	doc := Generate(Info{Title: "Grafana HTTP API", Version: "11.0.0"}, "http://localhost:3000/", rr.Routes(), nil)
+
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Equal(t, []Server{{URL: "http://localhost:3000"}}, doc.Servers)
+	require.Len(t, doc.Paths, 2, "the frontend and proxy routes aren't documented")
+
+	t.Run("should document the permissions and models of the operations", func(t *testing.T) {
+		list := doc.Paths["/api/folders/"]["get"]
+		require.NotNil(t, list)
+		assert.Equal(t, "getApiFolders", list.OperationID)
+		assert.Equal(t, []string{"folders"}, list.Tags)
+		assert.Equal(t, &AccessControl{Evaluator: "folders:read", Actions: []string{"folders:read"}}, list.AccessControl)
+		assert.Equal(t, []Parameter{
+			{Name: "query", In: "query", Schema: &Schema{Type: "string"}},
+			{Name: "limit", In: "query", Schema: &Schema{Type: "integer", Format: "int64"}},
+		}, list.Parameters)
+		assert.Equal(t, &Schema{Type: "array", Nullable: true, Items: &Schema{Ref: "#/components/schemas/openapi.testFolder"}},
+			list.Responses["200"].Content["application/json"].Schema)
+		assert.Contains(t, list.Responses, "403")
+
+		create := doc.Paths["/api/folders/"]["post"]
+		require.NotNil(t, create)
+		assert.Equal(t, "createFolder", create.OperationID)
+		assert.Equal(t, []string{"folders:create", "folders:read"}, create.AccessControl.Actions)
+
+		command := create.RequestBody.Content["application/json"].Schema
+		assert.Equal(t, "#/components/schemas/openapi.testCreateCommand", command.Ref)
+		schema := doc.Components.Schemas["openapi.testCreateCommand"]
+		assert.Equal(t, []string{"title"}, schema.Required)
+		assert.NotContains(t, schema.Properties, "OrgID")
+		assert.Equal(t, &Schema{Type: "object", Nullable: true, AdditionalProperties: &Schema{Type: "string"}}, schema.Properties["meta"])
+	})
+
+	t.Run("should document the typed errors with their message ids", func(t *testing.T) {
+		create := doc.Paths["/api/folders/"]["post"]
+		badRequest := create.Responses["400"]
+		require.NotNil(t, badRequest)
+		errorSchema := badRequest.Content["application/json"].Schema
+		require.Len(t, errorSchema.AllOf, 2)
+		assert.Equal(t, "#/components/schemas/PublicError", errorSchema.AllOf[0].Ref)
+		assert.Equal(t, []string{"test.invalid-title", "test.too-many"}, errorSchema.AllOf[1].Properties["messageId"].Enum)
+
+		remove := doc.Paths["/api/folders/{uid}"]["delete"]
+		require.NotNil(t, remove)
+		assert.Equal(t, []Parameter{{Name: "uid", In: "path", Required: true, Schema: &Schema{Type: "string"}}}, remove.Parameters)
+		assert.Contains(t, remove.Responses["404"].Description, "test.not-found")
+		assert.Equal(t, "#/components/schemas/ErrorResponse", remove.Responses["500"].Content["application/json"].Schema.Ref)
+		assert.Nil(t, remove.AccessControl)
+	})
+
+	t.Run("should build the schemas of the models from their JSON encoding", func(t *testing.T) {
+		folder := doc.Components.Schemas["openapi.testFolder"]
+		require.NotNil(t, folder)
+		assert.Equal(t, &Schema{Ref: "#/components/schemas/openapi.testFolder"}, folder.Properties["parent"], "recursive types are referenced")
+		assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, folder.Properties["created"])
+		assert.Equal(t, &Schema{Type: "string"}, folder.Properties["version"])
+
+		_, err := json.Marshal(doc)
+		require.NoError(t, err)
+	})
+}
+
+func TestGenerateWithAnnotations(t *testing.T) {
+	handler := routing.Wrap(func(c *contextmodel.ReqContext) response.Response { return nil })
+	authorize := ac.Middleware(actest.FakeAccessControl{})
+
+	annotations, err := ParseAnnotations([]byte(`{
+		"paths": {
+			"/dashboards/uid/{uid}": {
+				"get": {
+					"operationId": "getDashboardByUID",
+					"summary": "Get dashboard by uid.",
+					"tags": ["dashboards"],
+					"parameters": [{"in": "path", "name": "uid", "required": true, "schema": {"type": "string"}}],
+					"responses": {"200": {"$ref": "#/components/responses/dashboardResponse"}}
+				},
+				"delete": {"operationId": "deleteDashboardByUID", "responses": {}}
+			},
+			"/folders": {
+				"post": {"operationId": "createFolder", "summary": "Annotated.", "responses": {}}
+			}
+		},
+		"components": {
+			"schemas": {
+				"DashboardFullWithMeta": {"type": "object", "properties": {"dashboard": {"type": "object"}}},
+				"PublicError": {"type": "object"}
+			},
+			"responses": {
+				"dashboardResponse": {"description": "", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/DashboardFullWithMeta"}}}}
+			}
+		}
+	}`))
+	require.NoError(t, err)
+
+	rr := routing.NewRouteRegister()
+	rr.Get("/api/dashboards/uid/:dashboardUid", authorize(ac.EvalPermission("dashboards:read")), handler)
+	rr.Post("/api/folders/", Describe(Operation{Summary: "Described.", Request: testCreateCommand{}}, handler))
+	rr.Get("/api/health", handler)
+
+	doc := Generate(Info{Title: "Grafana HTTP API", Version: "11.0.0"}, "", rr.Routes(), annotations)
+
+	t.Run("should document the routes with their annotated operations", func(t *testing.T) {
+		get := doc.Paths["/api/dashboards/uid/{uid}"]["get"]
+		require.NotNil(t, get, "the path is named after the annotations")
+		assert.Equal(t, "getDashboardByUID", get.OperationID)
+		assert.Equal(t, []string{"dashboards"}, get.Tags)
+		assert.Equal(t, []string{"dashboards:read"}, get.AccessControl.Actions)
+		assert.NotContains(t, doc.Paths["/api/dashboards/uid/{uid}"], "delete", "the operations of unregistered routes are left out")
+
+		encoded, err := json.Marshal(get)
+		require.NoError(t, err)
+		var fields map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(encoded, &fields))
+		assert.JSONEq(t, `{"200": {"$ref": "#/components/responses/dashboardResponse"}}`, string(fields["responses"]))
+		assert.JSONEq(t, `{"evaluator": "dashboards:read", "actions": ["dashboards:read"]}`, string(fields["x-grafana-access-control"]))
+	})
+
+	t.Run("should prefer the described operations", func(t *testing.T) {
+		create := doc.Paths["/api/folders"]["post"]
+		require.NotNil(t, create)
+		assert.Equal(t, "Described.", create.Summary)
+		assert.NotNil(t, create.RequestBody)
+	})
+
+	t.Run("should document the other routes with their path", func(t *testing.T) {
+		health := doc.Paths["/api/health"]["get"]
+		require.NotNil(t, health)
+		assert.Equal(t, "getApiHealth", health.OperationID)
+	})
+
+	t.Run("should include the annotated components", func(t *testing.T) {
+		encoded, err := json.Marshal(doc.Components)
+		require.NoError(t, err)
+		var components struct {
+			Schemas   map[string]json.RawMessage `json:"schemas"`
+			Responses map[string]json.RawMessage `json:"responses"`
+		}
+		require.NoError(t, json.Unmarshal(encoded, &components))
+		assert.JSONEq(t, `{"type": "object", "properties": {"dashboard": {"type": "object"}}}`, string(components.Schemas["DashboardFullWithMeta"]))
+		assert.NotEqual(t, `{"type":"object"}`, string(components.Schemas["PublicError"]), "the error envelope is built from errutil")
+		assert.Contains(t, components.Responses, "dashboardResponse")
+	})
+}
+
+func TestConvertPattern(t *testing.T) {
+	path, params := convertPattern("/api/dashboards/uid/:uid/versions/:id([0-9]+)")
+	assert.Equal(t, "/api/dashboards/uid/{uid}/versions/{id}", path)
+	assert.Equal(t, []string{"uid", "id"}, params)
+
+	path, params = convertPattern("/api/plugins/:pluginId/resources/*")
+	assert.Equal(t, "/api/plugins/{pluginId}/resources/{path}", path)
+	assert.Equal(t, []string{"pluginId", "path"}, params)
+}
+
+func TestDescribe(t *testing.T) {
+	called := false
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+
+	described := Describe(Operation{Summary: "Test."}, handler)
+	annotated, ok := described.(web.AnnotatedHandler)
+	require.True(t, ok, "the described handler is unwrapped by the router")
+
+	annotated.Unwrap().(http.HandlerFunc)(nil, nil)
+	assert.True(t, called, "the described handler serves the requests")
+}
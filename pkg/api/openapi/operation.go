@@ -0,0 +1,49 @@
+package openapi
+
+import (
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// Operation describes what the registration of a route doesn't tell about it: the models of its request and
+// responses and the typed errors it returns
+type Operation struct {
+	// ID is the operationId, derived from the method and path when empty
+	ID          string
+	Summary     string
+	Description string
+	// Tags group the operations, the first segment of the path after /api/ when empty
+	Tags []string
+	// Query is a struct whose fields are the query parameters, named by their json tag
+	Query any
+	// Request is the JSON body of the request
+	Request any
+	// Response is the JSON body of the successful response
+	Response any
+	// ResponseStatus is the status code of the successful response, 200 when 0
+	ResponseStatus int
+	// Errors are the typed errors returned by the handler, documented with the error envelope
+	Errors     []errutil.Base
+	Deprecated bool
+}
+
+// describedHandler is the handler returned by Describe
+type describedHandler struct {
+	operation Operation
+	handler   web.Handler
+}
+
+func (h *describedHandler) Unwrap() web.Handler {
+	return h.handler
+}
+
+// Describe annotates the handler of a route with its operation, the handler serves the requests unchanged:
+//
+//	route.Post("/", authorize(ac.EvalPermission(ActionWrite)), openapi.Describe(openapi.Operation{
+//		Request:  CreateCommand{},
+//		Response: Thing{},
+//		Errors:   []errutil.Base{ErrInvalidName},
+//	}, routing.Wrap(s.create)))
+func Describe(operation Operation, handler web.Handler) web.Handler {
+	return &describedHandler{operation: operation, handler: handler}
+}
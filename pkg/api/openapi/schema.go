@@ -0,0 +1,203 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	invalidNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// schemas builds the schemas of Go types from their JSON encoding, named structs are added to the components and
+// referenced
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{
+		components: map[string]*Schema{},
+		names:      map[reflect.Type]string{},
+	}
+}
+
+// of returns the schema of the JSON encoding of the value's type
+func (s *schemas) of(v any) *Schema {
+	return s.schema(reflect.TypeOf(v))
+}
+
+func (s *schemas) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.Kind() != reflect.Pointer && (t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType)):
+		// the encoding is custom, its shape is unknown
+		return &Schema{}
+	case t.Kind() != reflect.Pointer && (t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.schema(t.Elem()), Nullable: true}
+	case reflect.Array:
+		return &Schema{Type: "array", Items: s.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schema(t.Elem()), Nullable: true}
+	case reflect.Pointer:
+		schema := s.schema(t.Elem())
+		if schema.Ref != "" {
+			// siblings of $ref are ignored
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + s.component(t)}
+	}
+
+	// interfaces can be encoded as anything
+	return &Schema{}
+}
+
+// component adds the schema of the named struct to the components and returns its name
+func (s *schemas) component(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+
+	name := componentName(t, 1)
+	for i := 2; ; i++ {
+		if _, taken := s.components[name]; !taken {
+			break
+		}
+		name = componentName(t, i)
+	}
+
+	// the name is reserved before the properties are built so that recursive types reference it
+	s.names[t] = name
+	s.components[name] = &Schema{}
+	*s.components[name] = *s.object(t)
+	return name
+}
+
+// componentName names the type after the last segments of its package path
+func componentName(t reflect.Type, segments int) string {
+	path := strings.Split(t.PkgPath(), "/")
+	if segments > len(path) {
+		segments = len(path)
+	}
+	prefix := strings.Join(path[len(path)-segments:], "_")
+	name := t.Name()
+	if prefix != "" {
+		name = prefix + "." + name
+	}
+	return invalidNameChars.ReplaceAllString(name, "_")
+}
+
+func (s *schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.addFields(schema, t)
+	return schema
+}
+
+func (s *schemas) addFields(schema *Schema, t reflect.Type) {
+	for _, f := range fields(t) {
+		if f.embedded != nil {
+			s.addFields(schema, f.embedded)
+			continue
+		}
+
+		property := s.schema(f.field.Type)
+		if f.asString {
+			property = &Schema{Type: "string"}
+		}
+		schema.Properties[f.name] = property
+		if f.required {
+			schema.Required = append(schema.Required, f.name)
+		}
+	}
+}
+
+type field struct {
+	field reflect.StructField
+	name  string
+	// embedded is the struct whose fields are inlined, when the field is an embedded struct without a JSON name
+	embedded reflect.Type
+	asString bool
+	// required is set by the binding tag validated by web.Bind
+	required bool
+}
+
+// fields returns the fields of the struct encoded to JSON
+func fields(t reflect.Type) []field {
+	var result []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				result = append(result, field{field: f, embedded: embedded})
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		result = append(result, field{
+			field:    f,
+			name:     name,
+			asString: strings.Contains(","+options+",", ",string,"),
+			required: strings.Contains(f.Tag.Get("binding"), "Required"),
+		})
+	}
+	return result
+}
@@ -81,6 +81,7 @@ func (hs *HTTPServer) GetOrgByName(c *contextmodel.ReqContext) response.Response
 			State:    orga.State,
 			Country:  orga.Country,
 		},
+		Archived: orga.Archived,
 	}
 
 	return response.JSON(http.StatusOK, &result)
@@ -109,6 +110,7 @@ func (hs *HTTPServer) getOrgHelper(ctx context.Context, orgID int64) response.Re
 			State:    orga.State,
 			Country:  orga.Country,
 		},
+		Archived: orga.Archived,
 	}
 
 	return response.JSON(http.StatusOK, &result)
@@ -306,6 +308,70 @@ func (hs *HTTPServer) DeleteOrgByID(c *contextmodel.ReqContext) response.Respons
 	return response.Success("Organization deleted")
 }
 
+// swagger:route POST /orgs/{org_id}/logout orgs logoutOrgUsers
+//
+// Logout all users of an Organization.
+//
+// Revokes all auth tokens (devices) of every user in the organization, except the caller. Users will be required to authenticate again upon next activity.
+// The auth tokens aren't bound to an organization, so the users are also logged out of the other organizations they belong to.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users.logout` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) LogoutOrgUsers(c *contextmodel.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+
+	if _, err := hs.orgService.GetByID(c.Req.Context(), &org.GetOrgByIDQuery{ID: orgID}); err != nil {
+		if errors.Is(err, org.ErrOrgNotFound) {
+			return response.Error(http.StatusNotFound, "Organization not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get organization", err)
+	}
+
+	const pageSize = 1000
+	callerID, _ := c.GetInternalID()
+	userIDs := make([]int64, 0)
+	for page := 1; ; page++ {
+		result, err := hs.orgService.SearchOrgUsers(c.Req.Context(), &org.SearchOrgUsersQuery{
+			OrgID:                    orgID,
+			Page:                     page,
+			Limit:                    pageSize,
+			DontEnforceAccessControl: true,
+		})
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to get organization users", err)
+		}
+		for _, orgUser := range result.OrgUsers {
+			if orgUser.UserID != callerID {
+				userIDs = append(userIDs, orgUser.UserID)
+			}
+		}
+		if len(result.OrgUsers) < pageSize {
+			break
+		}
+	}
+
+	if err := hs.AuthTokenService.BatchRevokeAllUserTokens(c.Req.Context(), userIDs); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to logout organization users", err)
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message":   "Organization users logged out",
+		"userCount": len(userIDs),
+	})
+}
+
 // swagger:route GET /orgs orgs searchOrgs
 //
 // Search all Organizations.
@@ -390,6 +456,13 @@ type DeleteOrgByIDParams struct {
 	OrgID int64 `json:"org_id"`
 }
 
+// swagger:parameters logoutOrgUsers
+type LogoutOrgUsersParams struct {
+	// in:path
+	// required:true
+	OrgID int64 `json:"org_id"`
+}
+
 // swagger:parameters updateOrg
 type UpdateOrgParams struct {
 	// in:body
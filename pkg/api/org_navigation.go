@@ -0,0 +1,81 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/navtree"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /org/navigation org getOrgNavigation
+//
+// Get the navigation customization of the current organization.
+//
+// Responses:
+// 200: getOrgNavigationResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetOrgNavigation(c *contextmodel.ReqContext) response.Response {
+	customization, err := hs.navTreeService.GetCustomization(c.Req.Context(), c.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get navigation customization", err)
+	}
+	return response.JSON(http.StatusOK, customization)
+}
+
+// swagger:route PUT /org/navigation org updateOrgNavigation
+//
+// Customize the navigation of the current organization.
+//
+// Hides nav links, adds custom links and reorders the nav links of the navigation tree of all the users of the
+// organization, including the nav links contributed by plugins. Hiding a nav link doesn't restrict the access to its page.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) UpdateOrgNavigation(c *contextmodel.ReqContext) response.Response {
+	customization := navtree.Customization{}
+	if err := web.Bind(c.Req, &customization); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := hs.navTreeService.UpdateCustomization(c.Req.Context(), c.GetOrgID(), &customization); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update navigation customization", err)
+	}
+	return response.Success("Navigation updated")
+}
+
+// swagger:route DELETE /org/navigation org deleteOrgNavigation
+//
+// Restore the default navigation of the current organization.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) DeleteOrgNavigation(c *contextmodel.ReqContext) response.Response {
+	if err := hs.navTreeService.DeleteCustomization(c.Req.Context(), c.GetOrgID()); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to delete navigation customization", err)
+	}
+	return response.Success("Navigation restored")
+}
+
+// swagger:parameters updateOrgNavigation
+type UpdateOrgNavigationParams struct {
+	// in:body
+	// required:true
+	Body navtree.Customization `json:"body"`
+}
+
+// swagger:response getOrgNavigationResponse
+type GetOrgNavigationResponse struct {
+	// in:body
+	Body navtree.Customization `json:"body"`
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"strings"
 	"testing"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/auth/authtest"
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/authn/authntest"
 	"github.com/grafana/grafana/pkg/services/org"
@@ -306,3 +308,112 @@ func TestAPIEndpoint_GetOrg(t *testing.T) {
 		})
 	}
 }
+
+func TestAPIEndpoint_LogoutOrgUsers(t *testing.T) {
+	canLogout := []accesscontrol.Permission{{Action: accesscontrol.ActionUsersLogout, Scope: accesscontrol.ScopeGlobalUsersAll}}
+
+	setup := func(t *testing.T, permissions []accesscontrol.Permission, orgService *orgtest.FakeOrgService, revoked *[]int64) *webtest.Server {
+		t.Helper()
+		return SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.Cfg = setting.NewCfg()
+			hs.orgService = orgService
+			hs.userService = &usertest.FakeUserService{ExpectedSignedInUser: &user.SignedInUser{OrgID: 1}}
+			hs.accesscontrolService = &actest.FakeService{ExpectedPermissions: permissions}
+			hs.authnService = &authntest.FakeService{
+				ExpectedIdentity: &authn.Identity{
+					ID:    "1",
+					Type:  claims.TypeUser,
+					OrgID: 1,
+					Permissions: map[int64]map[string][]string{
+						0: accesscontrol.GroupScopesByActionContext(context.Background(), permissions),
+						1: accesscontrol.GroupScopesByActionContext(context.Background(), permissions),
+					},
+				},
+			}
+			tokenService := authtest.NewFakeUserAuthTokenService()
+			tokenService.BatchRevokedTokenProvider = func(_ context.Context, userIDs []int64) error {
+				*revoked = append(*revoked, userIDs...)
+				return nil
+			}
+			hs.AuthTokenService = tokenService
+		})
+	}
+
+	logout := func(t *testing.T, server *webtest.Server, permissions []accesscontrol.Permission, orgID string) (*http.Response, map[string]any) {
+		t.Helper()
+		req := webtest.RequestWithSignedInUser(server.NewRequest(http.MethodPost, "/api/orgs/"+orgID+"/logout", nil), authedUserWithPermissions(1, 1, permissions))
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+		require.NoError(t, res.Body.Close())
+		return res, body
+	}
+
+	orgUsers := func(userIDs ...int64) *org.SearchOrgUsersQueryResult {
+		result := &org.SearchOrgUsersQueryResult{}
+		for _, userID := range userIDs {
+			result.OrgUsers = append(result.OrgUsers, &org.OrgUserDTO{OrgID: 2, UserID: userID})
+		}
+		return result
+	}
+
+	t.Run("should not be able to logout the users without the users:logout permission", func(t *testing.T) {
+		var revoked []int64
+		permissions := []accesscontrol.Permission{{Action: accesscontrol.ActionOrgsRead}}
+		server := setup(t, permissions, &orgtest.FakeOrgService{ExpectedOrg: &org.Org{ID: 2}, ExpectedSearchOrgUsersResult: orgUsers(2, 3)}, &revoked)
+
+		res, _ := logout(t, server, permissions, "2")
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		assert.Empty(t, revoked)
+	})
+
+	t.Run("should revoke the tokens of every user of the org but the caller", func(t *testing.T) {
+		var revoked []int64
+		server := setup(t, canLogout, &orgtest.FakeOrgService{ExpectedOrg: &org.Org{ID: 2}, ExpectedSearchOrgUsersResult: orgUsers(1, 2, 3)}, &revoked)
+
+		res, body := logout(t, server, canLogout, "2")
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, []int64{2, 3}, revoked)
+		assert.EqualValues(t, 2, body["userCount"])
+	})
+
+	t.Run("should revoke the tokens of the users of every page", func(t *testing.T) {
+		var revoked []int64
+		var pages []int
+		orgService := &orgtest.FakeOrgService{ExpectedOrg: &org.Org{ID: 2}}
+		orgService.SearchOrgUsersFn = func(_ context.Context, query *org.SearchOrgUsersQuery) (*org.SearchOrgUsersQueryResult, error) {
+			assert.Equal(t, int64(2), query.OrgID)
+			pages = append(pages, query.Page)
+			if query.Page > 1 {
+				return orgUsers(5000), nil
+			}
+			result := orgUsers()
+			for userID := int64(2); len(result.OrgUsers) < query.Limit; userID++ {
+				result.OrgUsers = append(result.OrgUsers, &org.OrgUserDTO{OrgID: 2, UserID: userID})
+			}
+			return result, nil
+		}
+		server := setup(t, canLogout, orgService, &revoked)
+
+		res, body := logout(t, server, canLogout, "2")
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, []int{1, 2}, pages)
+		assert.Len(t, revoked, 1001)
+		assert.Contains(t, revoked, int64(5000))
+		assert.EqualValues(t, 1001, body["userCount"])
+	})
+
+	t.Run("should not revoke any token when the org doesn't exist", func(t *testing.T) {
+		var revoked []int64
+		server := setup(t, canLogout, &orgtest.FakeOrgService{ExpectedError: org.ErrOrgNotFound}, &revoked)
+
+		res, _ := logout(t, server, canLogout, "2")
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		assert.Empty(t, revoked)
+
+		res, _ = logout(t, server, canLogout, "abc")
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Empty(t, revoked)
+	})
+}
@@ -129,6 +129,35 @@ func (hs *HTTPServer) PatchUserPreferences(c *contextmodel.ReqContext) response.
 	return hs.patchPreferencesFor(c.Req.Context(), c.GetOrgID(), userID, 0, &dtoCmd)
 }
 
+// swagger:route GET /user/preferences/resolved user_preferences getResolvedUserPreferences
+//
+// Get the effective preferences of the user.
+//
+// Returns the value of each preference with the level which set it: the defaults of the server, the organization,
+// one of the teams of the user, or the user. The teams are applied by ascending ID, and the preferences locked by
+// the organization take precedence over all the levels.
+//
+// Responses:
+// 200: getResolvedPreferencesResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (hs *HTTPServer) GetResolvedUserPreferences(c *contextmodel.ReqContext) response.Response {
+	userID, err := identity.UserIdentifier(c.GetID())
+	if err != nil {
+		return response.Error(http.StatusUnauthorized, "Not a valid identity", err)
+	}
+
+	resolved, err := hs.preferenceService.GetResolved(c.Req.Context(), &pref.GetPreferenceWithDefaultsQuery{
+		OrgID:  c.GetOrgID(),
+		UserID: userID,
+		Teams:  c.GetTeams(),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get preferences", err)
+	}
+	return response.JSON(http.StatusOK, resolved)
+}
+
 func (hs *HTTPServer) patchPreferencesFor(ctx context.Context, orgID, userID, teamId int64, dtoCmd *dtos.PatchPrefsCmd) response.Response {
 	if dtoCmd.Theme != nil && !pref.IsValidThemeID(*dtoCmd.Theme) {
 		return response.Error(http.StatusBadRequest, "Invalid theme", nil)
@@ -235,6 +264,51 @@ func (hs *HTTPServer) PatchOrgPreferences(c *contextmodel.ReqContext) response.R
 	return hs.patchPreferencesFor(c.Req.Context(), c.GetOrgID(), 0, 0, &dtoCmd)
 }
 
+// swagger:route GET /org/preferences/locks org_preferences getOrgPreferenceLocks
+//
+// Get the preferences locked by the current organization.
+//
+// Responses:
+// 200: getPreferenceLocksResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetOrgPreferenceLocks(c *contextmodel.ReqContext) response.Response {
+	locked, err := hs.preferenceService.GetLocked(c.Req.Context(), c.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get locked preferences", err)
+	}
+	return response.JSON(http.StatusOK, &pref.LockedPreferences{Preferences: locked})
+}
+
+// swagger:route PUT /org/preferences/locks org_preferences updateOrgPreferenceLocks
+//
+// Lock preferences of the current organization.
+//
+// The teams and the users of the organization can't override the locked preferences, they get the values of
+// the organization preferences, or the defaults of the server when the organization doesn't set them.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) UpdateOrgPreferenceLocks(c *contextmodel.ReqContext) response.Response {
+	dto := pref.LockedPreferences{}
+	if err := web.Bind(c.Req, &dto); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := hs.preferenceService.SetLocked(c.Req.Context(), &pref.SetLockedPreferencesCommand{
+		OrgID:       c.GetOrgID(),
+		Preferences: dto.Preferences,
+	}); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to lock preferences", err)
+	}
+	return response.Success("Locked preferences updated")
+}
+
 // swagger:parameters  updateUserPreferences
 type UpdateUserPreferencesParams struct {
 	// in:body
@@ -268,3 +342,22 @@ type PatchOrgPreferencesParams struct {
 	// required:true
 	Body dtos.PatchPrefsCmd `json:"body"`
 }
+
+// swagger:parameters updateOrgPreferenceLocks
+type UpdateOrgPreferenceLocksParams struct {
+	// in:body
+	// required:true
+	Body pref.LockedPreferences `json:"body"`
+}
+
+// swagger:response getResolvedPreferencesResponse
+type GetResolvedPreferencesResponse struct {
+	// in:body
+	Body pref.ResolvedPreferences `json:"body"`
+}
+
+// swagger:response getPreferenceLocksResponse
+type GetPreferenceLocksResponse struct {
+	// in:body
+	Body pref.LockedPreferences `json:"body"`
+}
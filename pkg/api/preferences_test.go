@@ -202,3 +202,37 @@ func TestAPIEndpoint_PatchOrgPreferences(t *testing.T) {
 		require.NoError(t, response.Body.Close())
 	})
 }
+
+func TestAPIEndpoint_OrgPreferenceLocks(t *testing.T) {
+	prefService := preftest.NewPreferenceServiceFake()
+	prefService.ExpectedLocked = []string{pref.PreferenceHomeDashboard}
+
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.Cfg = setting.NewCfg()
+		hs.preferenceService = prefService
+	})
+
+	t.Run("AccessControl allows getting the locked preferences with correct permissions", func(t *testing.T) {
+		req := webtest.RequestWithSignedInUser(server.NewGetRequest("/api/org/preferences/locks"), userWithPermissions(1, []accesscontrol.Permission{{Action: accesscontrol.ActionOrgsPreferencesRead}}))
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+	t.Run("AccessControl allows locking preferences with correct permissions", func(t *testing.T) {
+		input := strings.NewReader(`{"preferences": ["homeDashboard", "theme"]}`)
+		req := webtest.RequestWithSignedInUser(server.NewRequest(http.MethodPut, "/api/org/preferences/locks", input), userWithPermissions(1, []accesscontrol.Permission{{Action: accesscontrol.ActionOrgsPreferencesWrite}}))
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+	t.Run("AccessControl prevents locking preferences with incorrect permissions", func(t *testing.T) {
+		input := strings.NewReader(`{"preferences": ["homeDashboard"]}`)
+		req := webtest.RequestWithSignedInUser(server.NewRequest(http.MethodPut, "/api/org/preferences/locks", input), userWithPermissions(1, []accesscontrol.Permission{{Action: accesscontrol.ActionOrgsPreferencesRead}}))
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+}
@@ -30,6 +30,8 @@ func (hs *HTTPServer) GetCurrentOrgQuotas(c *contextmodel.ReqContext) response.R
 //
 // Fetch Organization quota.
 //
+// Returns the limit, the soft limit and the usage of each quota of the organization. The status of a quota is `warning` when its usage reached the soft limit, and `reached` when it reached the limit.
+//
 // If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `orgs.quotas:read` and scope `org:id:1` (orgIDScope).
 //
 // Responses:
@@ -60,6 +62,7 @@ func (hs *HTTPServer) getOrgQuotasHelper(c *contextmodel.ReqContext, orgID int64
 //
 // Update user quota.
 //
+// The soft limit is the usage at which the quota warns before it's reached, `0` is the configured percentage of the limit and `-1` disables it. The soft limit is kept when it's not set.
 // If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `orgs.quotas:write` and scope `org:id:1` (orgIDScope).
 //
 // Security:
@@ -67,6 +70,7 @@ func (hs *HTTPServer) getOrgQuotasHelper(c *contextmodel.ReqContext, orgID int64
 //
 // Responses:
 // 200: okResponse
+// 400: badRequestError
 // 401: unauthorisedError
 // 403: forbiddenError
 // 404: notFoundError
@@ -89,10 +89,12 @@ func (r *NormalResponse) WriteTo(ctx *contextmodel.ReqContext) {
 			requestmeta.WithDownstreamStatusSource(ctx.Req.Context())
 		}
 
+		traceID := tracing.TraceIDFromContext(ctx.Req.Context(), false)
+		r.addTraceID(traceID)
 		if errutil.HasUnifiedLogging(ctx.Req.Context()) {
 			ctx.Error = r.err
 		} else {
-			r.writeLogLine(ctx)
+			r.writeLogLine(ctx, traceID)
 		}
 	}
 
@@ -106,16 +108,18 @@ func (r *NormalResponse) WriteTo(ctx *contextmodel.ReqContext) {
 	}
 }
 
-func (r *NormalResponse) writeLogLine(c *contextmodel.ReqContext) {
+// addTraceID adds the trace ID to the JSON body of an error response, so that the error can be found in the traces.
+func (r *NormalResponse) addTraceID(traceID string) {
 	v := map[string]any{}
-	traceID := tracing.TraceIDFromContext(c.Req.Context(), false)
 	if err := json.Unmarshal(r.body.Bytes(), &v); err == nil {
 		v["traceID"] = traceID
 		if b, err := json.Marshal(v); err == nil {
 			r.body = bytes.NewBuffer(b)
 		}
 	}
+}
 
+func (r *NormalResponse) writeLogLine(c *contextmodel.ReqContext, traceID string) {
 	logger := c.Logger.Error
 	var gfErr errutil.Error
 	if errors.As(r.err, &gfErr) {
@@ -171,3 +171,13 @@ func TestRespond(t *testing.T) {
 		})
 	}
 }
+
+func TestAddTraceID(t *testing.T) {
+	resp := Error(http.StatusBadRequest, "bad request", errors.New("invalid"))
+	resp.addTraceID("4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.JSONEq(t, `{"message":"bad request","traceID":"4bf92f3577b34da6a3ce929d0e0e4736"}`, string(resp.Body()))
+
+	resp = Respond(http.StatusInternalServerError, "not JSON")
+	resp.addTraceID("4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.Equal(t, "not JSON", string(resp.Body()))
+}
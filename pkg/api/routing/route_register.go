@@ -139,6 +139,26 @@ func (rr *RouteRegisterImpl) Register(router Router, namedMiddlewares ...Registe
 	}
 }
 
+// Route is a route added to a RouteRegister
+type Route struct {
+	Method  string
+	Pattern string
+	// Handlers are the middlewares and the handler of the route, in the order they're called
+	Handlers []web.Handler
+}
+
+// Routes returns the routes added to the RouteRegister and its groups, in the order they're registered
+func (rr *RouteRegisterImpl) Routes() []Route {
+	routes := make([]Route, 0, len(rr.routes))
+	for _, r := range rr.routes {
+		routes = append(routes, Route{Method: r.method, Pattern: r.pattern, Handlers: r.handlers})
+	}
+	for _, g := range rr.groups {
+		routes = append(routes, g.Routes()...)
+	}
+	return routes
+}
+
 func (rr *RouteRegisterImpl) route(pattern, method string, handlers ...web.Handler) {
 	h := make([]web.Handler, 0)
 	fullPattern := rr.prefix + pattern
@@ -261,3 +261,23 @@ func TestNamedMiddlewareRouteRegister(t *testing.T) {
 		}
 	}
 }
+
+func TestRoutes(t *testing.T) {
+	rr := NewRouteRegister()
+	rr.Get("/api/health", emptyHandler("health"))
+	rr.Group("/api/dashboards", func(dashboardRoute RouteRegister) {
+		dashboardRoute.Get("/uid/:uid", emptyHandler("get"))
+		dashboardRoute.Delete("/uid/:uid", emptyHandler("delete"))
+	}, emptyHandler("signedIn"))
+
+	routes := rr.Routes()
+	if len(routes) != 3 {
+		t.Fatalf("want 3 routes, got %d", len(routes))
+	}
+	if routes[1].Method != http.MethodGet || routes[1].Pattern != "/api/dashboards/uid/:uid" {
+		t.Errorf("want GET /api/dashboards/uid/:uid, got %s %s", routes[1].Method, routes[1].Pattern)
+	}
+	if len(routes[2].Handlers) != 2 {
+		t.Errorf("want the group and route handlers, got %d handlers", len(routes[2].Handlers))
+	}
+}
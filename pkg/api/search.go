@@ -13,6 +13,12 @@ import (
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/services/search/model"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/pagination"
+)
+
+const (
+	defaultSearchLimit = 1000
+	maxSearchLimit     = 5000
 )
 
 // swagger:route GET /search search search
@@ -29,8 +35,6 @@ func (hs *HTTPServer) Search(c *contextmodel.ReqContext) response.Response {
 	query := c.Query("query")
 	tags := c.QueryStrings("tag")
 	starred := c.Query("starred")
-	limit := c.QueryInt64("limit")
-	page := c.QueryInt64("page")
 	dashboardType := c.Query("type")
 	sort := c.Query("sort")
 	deleted := c.Query("deleted")
@@ -40,10 +44,26 @@ func (hs *HTTPServer) Search(c *contextmodel.ReqContext) response.Response {
 		return response.Error(http.StatusUnauthorized, "Unauthorized", nil)
 	}
 
-	if limit > 5000 {
+	if c.QueryInt64("limit") > maxSearchLimit {
 		return response.Error(http.StatusUnprocessableEntity, "Limit is above maximum allowed (5000), use page parameter to access hits beyond limit", nil)
 	}
 
+	// the search backends can only skip hits, the cursor of the search is the number of hits before the page
+	paging, err := pagination.FromQuery(c.Req.URL.Query(), defaultSearchLimit, maxSearchLimit)
+	if err != nil {
+		return response.Err(err)
+	}
+	page := c.QueryInt64("page")
+	if paging.After != nil {
+		if paging.After.Offset%int64(paging.Limit) != 0 {
+			return response.Err(pagination.ErrInvalidCursor.Errorf("offset %d isn't a multiple of the limit %d", paging.After.Offset, paging.Limit))
+		}
+		page = paging.After.Offset/int64(paging.Limit) + 1
+	}
+	if page < 1 {
+		page = 1
+	}
+
 	if c.Query("permission") == "Edit" {
 		permission = dashboardaccess.PERMISSION_EDIT
 	}
@@ -84,7 +104,7 @@ func (hs *HTTPServer) Search(c *contextmodel.ReqContext) response.Response {
 		Title:         query,
 		Tags:          tags,
 		SignedInUser:  c.SignedInUser,
-		Limit:         limit,
+		Limit:         int64(paging.Limit),
 		Page:          page,
 		IsStarred:     starred == "true",
 		IsDeleted:     deleted == "true",
@@ -105,7 +125,11 @@ func (hs *HTTPServer) Search(c *contextmodel.ReqContext) response.Response {
 
 	defer c.TimeRequest(metrics.MApiDashboardSearch)
 
-	return response.JSON(http.StatusOK, hits)
+	rsp := response.JSON(http.StatusOK, hits)
+	if next := pagination.NextOffset(len(hits), paging.Limit, &pagination.Cursor{Offset: (page - 1) * int64(paging.Limit)}); next != nil {
+		rsp.SetHeader("Link", pagination.LinkHeader(c.Req.URL, next, paging.Limit))
+	}
+	return rsp
 }
 
 // swagger:route GET /search/sorting search listSortOptions
@@ -189,6 +213,10 @@ type SearchParams struct {
 	// in:query
 	// required: false
 	Page int64 `json:"page"`
+	// Cursor of the page returned in the Link header of the previous page, replaces the page parameter
+	// in:query
+	// required: false
+	Cursor string `json:"cursor"`
 	// Set to `Edit` to return dashboards/folders that the user can edit
 	// in:query
 	// required: false
@@ -37,8 +37,10 @@ func (hs *HTTPServer) registerShortURLAPI(apiRoute routing.RouteRegister) {
 		apiRoute.Get("/api/short-urls/:uid", reqSignedIn, handler.getKubernetesShortURLsHandler)
 		apiRoute.Get("/goto/:uid", reqSignedIn, handler.getKubernetesRedirectFromShortURL, hs.Index)
 	} else {
+		apiRoute.Get("/api/short-urls", reqSignedIn, routing.Wrap(hs.searchShortURLs))
 		apiRoute.Post("/api/short-urls", reqSignedIn, hs.createShortURL)
 		apiRoute.Get("/api/short-urls/:uid", reqSignedIn, hs.getShortURL)
+		apiRoute.Delete("/api/short-urls/:uid", reqSignedIn, routing.Wrap(hs.deleteShortURL))
 		apiRoute.Get("/goto/:uid", reqSignedIn, hs.redirectFromShortURL, hs.Index)
 	}
 }
@@ -78,13 +80,23 @@ func (hs *HTTPServer) redirectFromShortURL(c *contextmodel.ReqContext) {
 			c.Redirect(hs.Cfg.AppURL, http.StatusPermanentRedirect)
 			return
 		}
+		if shorturls.ErrShortURLExpired.Is(err) {
+			hs.log.Debug("Not redirecting short URL since expired", "uid", shortURLUID)
+			c.Redirect(hs.Cfg.AppURL, http.StatusTemporaryRedirect)
+			return
+		}
 		hs.log.Error("Short URL redirection error", "err", err)
 		c.Redirect(hs.Cfg.AppURL, http.StatusTemporaryRedirect)
 		return
 	}
 
-	// Failure to update LastSeenAt should still allow to redirect
+	// Failure to update LastSeenAt should still allow to redirect, unless the short URL reached its max uses
 	if err := hs.ShortURLService.UpdateLastSeenAt(c.Req.Context(), shortURL); err != nil {
+		if shorturls.ErrShortURLExpired.Is(err) {
+			hs.log.Debug("Not redirecting short URL since it reached its max uses", "uid", shortURLUID)
+			c.Redirect(hs.Cfg.AppURL, http.StatusTemporaryRedirect)
+			return
+		}
 		hs.log.Error("Failed to update short URL last seen at", "error", err)
 	}
 
@@ -105,11 +117,50 @@ func (hs *HTTPServer) getShortURL(c *contextmodel.ReqContext) response.Response
 		if shorturls.ErrShortURLNotFound.Is(err) {
 			return response.Err(shorturls.ErrShortURLNotFound.Errorf("shorturl not found: %w", err))
 		}
+		return response.Err(err)
 	}
 
 	return response.JSON(http.StatusOK, shortURL)
 }
 
+// searchShortURLs lists the short URLs created by the signed in user, with their use counts.
+func (hs *HTTPServer) searchShortURLs(c *contextmodel.ReqContext) response.Response {
+	query := &shorturls.SearchShortURLsQuery{
+		OrgID:     c.SignedInUser.GetOrgID(),
+		CreatedBy: c.SignedInUser.UserID,
+		Page:      c.QueryInt("page"),
+		Limit:     c.QueryInt("perpage"),
+	}
+	result, err := hs.ShortURLService.SearchShortURLs(c.Req.Context(), query)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to search short URLs", err)
+	}
+
+	dto := dtos.ShortURLSearchResult{
+		TotalCount: result.TotalCount,
+		ShortURLs:  make([]*dtos.ShortURL, 0, len(result.ShortURLs)),
+		Page:       result.Page,
+		PerPage:    result.PerPage,
+	}
+	for _, shortURL := range result.ShortURLs {
+		dto.ShortURLs = append(dto.ShortURLs, hs.ShortURLService.ConvertShortURLToDTO(shortURL, hs.Cfg.AppURL))
+	}
+	return response.JSON(http.StatusOK, dto)
+}
+
+// deleteShortURL deletes a short URL of the signed in user. Org admins can delete every short URL of the org.
+func (hs *HTTPServer) deleteShortURL(c *contextmodel.ReqContext) response.Response {
+	shortURLUID := web.Params(c.Req)[":uid"]
+	if !util.IsValidShortUID(shortURLUID) {
+		return response.Err(shorturls.ErrShortURLBadRequest.Errorf("invalid uid"))
+	}
+
+	if err := hs.ShortURLService.DeleteShortURL(c.Req.Context(), c.SignedInUser, shortURLUID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete short URL", err)
+	}
+	return response.Success("Short URL deleted")
+}
+
 type shortURLK8sHandler struct {
 	namespacer           request.NamespaceMapper
 	gvr                  schema.GroupVersionResource
@@ -101,6 +101,14 @@ func (s *fakeShortURLService) UpdateLastSeenAt(ctx context.Context, shortURL *sh
 	return nil
 }
 
+func (s *fakeShortURLService) SearchShortURLs(ctx context.Context, query *shorturls.SearchShortURLsQuery) (*shorturls.SearchShortURLsResult, error) {
+	return nil, nil
+}
+
+func (s *fakeShortURLService) DeleteShortURL(ctx context.Context, user *user.SignedInUser, uid string) error {
+	return nil
+}
+
 func (s *fakeShortURLService) DeleteStaleShortURLs(ctx context.Context, cmd *shorturls.DeleteShortUrlCommand) error {
 	return nil
 }
@@ -1,8 +1,14 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
 
+	"github.com/grafana/grafana/pkg/api/openapi"
+	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/api/webassets"
 	"github.com/grafana/grafana/pkg/middleware"
@@ -42,4 +48,52 @@ func (hs *HTTPServer) registerSwaggerUI(r routing.RouteRegister) {
 
 		c.HTML(http.StatusOK, "swagger", data)
 	})
+
+	// The OpenAPI document generated from the registered routes, built once all the routes are registered
+	var once sync.Once
+	var document []byte
+	var documentErr error
+	r.Get("/api/openapi.json", openapi.Describe(openapi.Operation{
+		Summary: "Get the OpenAPI 3 document of the HTTP API.",
+		Tags:    []string{"openapi"},
+	}, routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+		once.Do(func() {
+			document, documentErr = hs.generateOpenAPIDocument()
+		})
+		if documentErr != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to generate the OpenAPI document", documentErr)
+		}
+		return response.Respond(http.StatusOK, document).SetHeader("Content-Type", "application/json")
+	})))
+}
+
+func (hs *HTTPServer) generateOpenAPIDocument() ([]byte, error) {
+	var routes []routing.Route
+	if register, ok := hs.RouteRegister.(interface{ Routes() []routing.Route }); ok {
+		routes = register.Routes()
+	}
+
+	doc := openapi.Generate(openapi.Info{
+		Title:   "Grafana HTTP API",
+		Version: hs.Cfg.BuildVersion,
+	}, hs.Cfg.AppURL, routes, hs.loadOpenAPIAnnotations())
+	return json.Marshal(doc)
+}
+
+// loadOpenAPIAnnotations loads the OpenAPI 3 document built from the swagger annotations by make swagger-gen,
+// without it the routes that aren't described are documented with their path and permissions only
+func (hs *HTTPServer) loadOpenAPIAnnotations() *openapi.Annotations {
+	// It's safe to ignore gosec warning G304 since the directory comes from a configuration variable
+	// nolint:gosec
+	data, err := os.ReadFile(filepath.Join(hs.Cfg.StaticRootPath, "openapi3.json"))
+	if err != nil {
+		hs.log.Warn("Failed to read the OpenAPI document of the swagger annotations", "err", err)
+		return nil
+	}
+	annotations, err := openapi.ParseAnnotations(data)
+	if err != nil {
+		hs.log.Warn("Failed to parse the OpenAPI document of the swagger annotations", "err", err)
+		return nil
+	}
+	return annotations
 }
@@ -145,6 +145,11 @@ type UnauthorisedPublicError PublicErrorResponse
 // swagger:response forbiddenPublicError
 type ForbiddenPublicError PublicErrorResponse
 
+// TooManyRequestsPublicError is returned when the client exceeded the rate limit of the requested resource.
+//
+// swagger:response tooManyRequestsPublicError
+type TooManyRequestsPublicError PublicErrorResponse
+
 // InternalServerPublicError is a general error indicating something went wrong internally.
 //
 // swagger:response internalServerPublicError
@@ -355,7 +355,8 @@ func (hs *HTTPServer) GetSignedInUserOrgList(c *contextmodel.ReqContext) respons
 		return errResponse
 	}
 
-	return hs.getUserOrgList(c.Req.Context(), userID)
+	// archived organizations are hidden from the organization switcher
+	return hs.getUserOrgList(c.Req.Context(), &org.GetUserOrgListQuery{UserID: userID, ExcludeArchived: true})
 }
 
 // swagger:route GET /user/teams signed_in_user getSignedInUserTeamList
@@ -429,13 +430,11 @@ func (hs *HTTPServer) GetUserOrgList(c *contextmodel.ReqContext) response.Respon
 	if err != nil {
 		return response.Error(http.StatusBadRequest, "id is invalid", err)
 	}
-	return hs.getUserOrgList(c.Req.Context(), id)
+	return hs.getUserOrgList(c.Req.Context(), &org.GetUserOrgListQuery{UserID: id})
 }
 
-func (hs *HTTPServer) getUserOrgList(ctx context.Context, userID int64) response.Response {
-	query := org.GetUserOrgListQuery{UserID: userID}
-
-	result, err := hs.orgService.GetUserOrgList(ctx, &query)
+func (hs *HTTPServer) getUserOrgList(ctx context.Context, query *org.GetUserOrgListQuery) response.Response {
+	result, err := hs.orgService.GetUserOrgList(ctx, query)
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to get user organizations", err)
 	}
@@ -444,7 +443,7 @@ func (hs *HTTPServer) getUserOrgList(ctx context.Context, userID int64) response
 }
 
 func (hs *HTTPServer) validateUsingOrg(ctx context.Context, userID int64, orgID int64) bool {
-	query := org.GetUserOrgListQuery{UserID: userID}
+	query := org.GetUserOrgListQuery{UserID: userID, ExcludeArchived: true}
 
 	result, err := hs.orgService.GetUserOrgList(ctx, &query)
 	if err != nil {
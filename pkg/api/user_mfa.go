@@ -0,0 +1,282 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	claims "github.com/grafana/authlib/types"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /user/mfa signed_in_user getUserMFAStatus
+//
+// Multi-factor authentication status of the actual User.
+//
+// Responses:
+// 200: getUserMFAStatusResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetUserMFAStatus(c *contextmodel.ReqContext) response.Response {
+	userID, errResp := mfaUserID(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	status, err := hs.mfaService.GetStatus(c.Req.Context(), userID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get multi-factor authentication status", err)
+	}
+
+	return response.JSON(http.StatusOK, status)
+}
+
+// swagger:route POST /user/mfa/enroll signed_in_user enrollUserMFA
+//
+// Start setting up multi-factor authentication for the actual User.
+//
+// Generates a TOTP secret and recovery codes. Multi-factor authentication is only enabled once the enrollment is confirmed with a code of the authenticator app.
+//
+// Responses:
+// 200: enrollUserMFAResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (hs *HTTPServer) EnrollUserMFA(c *contextmodel.ReqContext) response.Response {
+	userID, errResp := mfaUserID(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	enrollment, err := hs.mfaService.Enroll(c.Req.Context(), userID, c.SignedInUser.GetLogin())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to set up multi-factor authentication", err)
+	}
+
+	return response.JSON(http.StatusOK, enrollment)
+}
+
+// swagger:route POST /user/mfa/confirm signed_in_user confirmUserMFA
+//
+// Enable multi-factor authentication for the actual User by confirming their enrollment with a TOTP code.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (hs *HTTPServer) ConfirmUserMFA(c *contextmodel.ReqContext) response.Response {
+	userID, errResp := mfaUserID(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	cmd := mfa.CodeCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := hs.mfaService.Confirm(c.Req.Context(), userID, cmd.Code); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to enable multi-factor authentication", err)
+	}
+
+	return response.Success("Multi-factor authentication enabled")
+}
+
+// swagger:route POST /user/mfa/disable signed_in_user disableUserMFA
+//
+// Disable multi-factor authentication for the actual User.
+//
+// Not allowed when multi-factor authentication is required for the user.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) DisableUserMFA(c *contextmodel.ReqContext) response.Response {
+	userID, errResp := mfaUserID(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	cmd := mfa.CodeCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := hs.mfaService.Disable(c.Req.Context(), userID, cmd.Code); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to disable multi-factor authentication", err)
+	}
+
+	return response.Success("Multi-factor authentication disabled")
+}
+
+// swagger:route POST /user/mfa/recovery-codes signed_in_user regenerateUserMFARecoveryCodes
+//
+// Replace the recovery codes of the actual User.
+//
+// Responses:
+// 200: regenerateUserMFARecoveryCodesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) RegenerateUserMFARecoveryCodes(c *contextmodel.ReqContext) response.Response {
+	userID, errResp := mfaUserID(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	cmd := mfa.CodeCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	codes, err := hs.mfaService.RegenerateRecoveryCodes(c.Req.Context(), userID, cmd.Code)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to generate recovery codes", err)
+	}
+
+	return response.JSON(http.StatusOK, map[string][]string{"recoveryCodes": codes})
+}
+
+// swagger:route DELETE /admin/users/{user_id}/mfa admin_users adminResetUserMFA
+//
+// Reset multi-factor authentication of a user, for users that lost their authenticator and recovery codes.
+//
+// If multi-factor authentication is required for the user, they have to set it up again on their next login.
+// You need to have a permission with action `users:write` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminResetUserMFA(c *contextmodel.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	if err := hs.mfaService.Reset(c.Req.Context(), userID); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to reset multi-factor authentication", err)
+	}
+
+	return response.Success("Multi-factor authentication reset")
+}
+
+// swagger:route GET /org/mfa org getCurrentOrgMFAPolicy
+//
+// Get the multi-factor authentication policy of the current organization.
+//
+// Responses:
+// 200: getCurrentOrgMFAPolicyResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetCurrentOrgMFAPolicy(c *contextmodel.ReqContext) response.Response {
+	policy, err := hs.mfaService.GetOrgPolicy(c.Req.Context(), c.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get multi-factor authentication policy", err)
+	}
+
+	return response.JSON(http.StatusOK, policy)
+}
+
+// swagger:route PUT /org/mfa org updateCurrentOrgMFAPolicy
+//
+// Update the multi-factor authentication policy of the current organization.
+//
+// When required, members using the built-in password login have to set up multi-factor authentication on their next login.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) UpdateCurrentOrgMFAPolicy(c *contextmodel.ReqContext) response.Response {
+	policy := mfa.OrgPolicy{}
+	if err := web.Bind(c.Req, &policy); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	policy.OrgID = c.GetOrgID()
+
+	if err := hs.mfaService.SetOrgPolicy(c.Req.Context(), &policy); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to update multi-factor authentication policy", err)
+	}
+
+	return response.Success("Multi-factor authentication policy updated")
+}
+
+func mfaUserID(c *contextmodel.ReqContext) (int64, response.Response) {
+	if !c.IsIdentityType(claims.TypeUser) {
+		return 0, response.Error(http.StatusForbidden, "entity not allowed to use multi-factor authentication", nil)
+	}
+
+	userID, err := c.GetInternalID()
+	if err != nil {
+		return 0, response.Error(http.StatusInternalServerError, "failed to parse user id", err)
+	}
+	return userID, nil
+}
+
+// swagger:parameters confirmUserMFA disableUserMFA regenerateUserMFARecoveryCodes
+type UserMFACodeParams struct {
+	// in:body
+	// required:true
+	Body mfa.CodeCommand `json:"body"`
+}
+
+// swagger:parameters adminResetUserMFA
+type AdminResetUserMFAParams struct {
+	// in:path
+	// required:true
+	UserID int64 `json:"user_id"`
+}
+
+// swagger:parameters updateCurrentOrgMFAPolicy
+type UpdateCurrentOrgMFAPolicyParams struct {
+	// in:body
+	// required:true
+	Body mfa.OrgPolicy `json:"body"`
+}
+
+// swagger:response getUserMFAStatusResponse
+type GetUserMFAStatusResponse struct {
+	// in:body
+	Body mfa.Status `json:"body"`
+}
+
+// swagger:response enrollUserMFAResponse
+type EnrollUserMFAResponse struct {
+	// in:body
+	Body mfa.Enrollment `json:"body"`
+}
+
+// swagger:response regenerateUserMFARecoveryCodesResponse
+type RegenerateUserMFARecoveryCodesResponse struct {
+	// in:body
+	Body struct {
+		RecoveryCodes []string `json:"recoveryCodes"`
+	} `json:"body"`
+}
+
+// swagger:response getCurrentOrgMFAPolicyResponse
+type GetCurrentOrgMFAPolicyResponse struct {
+	// in:body
+	Body mfa.OrgPolicy `json:"body"`
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/authn"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/oauthtoken"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
@@ -75,6 +76,71 @@ func (hs *HTTPServer) RevokeUserAuthToken(c *contextmodel.ReqContext) response.R
 	return hs.revokeUserAuthTokenInternal(c, userID, cmd)
 }
 
+// swagger:route POST /user/revoke-all-auth-tokens signed_in_user revokeAllUserAuthTokens
+//
+// Revoke all other auth tokens of the actual User.
+//
+// Revokes all auth tokens (devices) of the actual user except the one used for this request. Users of those devices will be required to authenticate again upon next activity.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) RevokeAllUserAuthTokens(c *contextmodel.ReqContext) response.Response {
+	if !c.IsIdentityType(claims.TypeUser) {
+		return response.Error(http.StatusForbidden, "entity not allowed to revoke tokens", nil)
+	}
+
+	userID, err := c.GetInternalID()
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to parse user id", err)
+	}
+
+	tokens, err := hs.AuthTokenService.GetUserTokens(c.Req.Context(), userID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get user auth tokens", err)
+	}
+
+	for _, token := range tokens {
+		if c.UserToken != nil && c.UserToken.Id == token.Id {
+			continue
+		}
+		if err := hs.AuthTokenService.RevokeToken(c.Req.Context(), token, false); err != nil && !errors.Is(err, auth.ErrUserTokenNotFound) {
+			return response.Error(http.StatusInternalServerError, "Failed to revoke user auth token", err)
+		}
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message": "User auth tokens revoked",
+	})
+}
+
+// swagger:route GET /user/oauth-tokens/health signed_in_user getUserOAuthTokensHealth
+//
+// Health of the OAuth tokens of the actual User.
+//
+// Return the state of the OAuth tokens Grafana holds for the actual user, for each OAuth provider they signed in with.
+// These are the tokens forwarded to data sources with Forward OAuth Identity enabled.
+//
+// Responses:
+// 200: getUserOAuthTokensHealthResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetUserOAuthTokensHealth(c *contextmodel.ReqContext) response.Response {
+	if !c.IsIdentityType(claims.TypeUser) {
+		return response.Error(http.StatusForbidden, "entity not allowed to get tokens", nil)
+	}
+
+	health, err := hs.oauthTokenService.GetOAuthTokensHealth(c.Req.Context(), c.SignedInUser, c.UserToken)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get OAuth tokens health", err)
+	}
+
+	return response.JSON(http.StatusOK, health)
+}
+
 func (hs *HTTPServer) RotateUserAuthTokenRedirect(c *contextmodel.ReqContext) response.Response {
 	if err := hs.rotateToken(c); err != nil {
 		hs.log.FromContext(c.Req.Context()).Debug("Failed to rotate token", "error", err)
@@ -296,3 +362,9 @@ type GetUserAuthTokensResponse struct {
 	// in:body
 	Body []*auth.UserToken `json:"body"`
 }
+
+// swagger:response getUserOAuthTokensHealthResponse
+type GetUserOAuthTokensHealthResponse struct {
+	// in:body
+	Body []oauthtoken.TokenHealth `json:"body"`
+}
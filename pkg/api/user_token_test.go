@@ -94,6 +94,24 @@ func TestUserTokenAPIEndpoint(t *testing.T) {
 		}, mockUser)
 	})
 
+	t.Run("When revoking all other auth tokens of the current user", func(t *testing.T) {
+		currentToken := &auth.UserToken{Id: 2}
+		revokeAllUserAuthTokensScenario(t, "Should revoke all tokens except the active one", currentToken, func(sc *scenarioContext) {
+			sc.userAuthTokenService.GetUserTokensProvider = func(ctx context.Context, userId int64) ([]*auth.UserToken, error) {
+				return []*auth.UserToken{{Id: 1}, {Id: 2}, {Id: 3}}, nil
+			}
+			revoked := []int64{}
+			sc.userAuthTokenService.RevokeTokenProvider = func(ctx context.Context, token *auth.UserToken, soft bool) error {
+				revoked = append(revoked, token.Id)
+				return nil
+			}
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+
+			assert.Equal(t, 200, sc.resp.Code)
+			assert.Equal(t, []int64{1, 3}, revoked)
+		})
+	})
+
 	t.Run("When gets auth tokens for a user", func(t *testing.T) {
 		currentToken := &auth.UserToken{Id: 1}
 		mockUser := usertest.NewUserServiceFake()
@@ -434,6 +452,32 @@ func revokeUserAuthTokenInternalScenario(t *testing.T, desc string, cmd auth.Rev
 	})
 }
 
+func revokeAllUserAuthTokensScenario(t *testing.T, desc string, token *auth.UserToken, fn scenarioFunc) {
+	t.Run(desc, func(t *testing.T) {
+		fakeAuthTokenService := authtest.NewFakeUserAuthTokenService()
+
+		hs := HTTPServer{
+			AuthTokenService: fakeAuthTokenService,
+		}
+
+		sc := setupScenarioContext(t, "/")
+		sc.userAuthTokenService = fakeAuthTokenService
+		sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserID = testUserID
+			sc.context.OrgID = testOrgID
+			sc.context.OrgRole = org.RoleAdmin
+			sc.context.UserToken = token
+
+			return hs.RevokeAllUserAuthTokens(c)
+		})
+
+		sc.m.Post("/", sc.defaultHandler)
+
+		fn(sc)
+	})
+}
+
 func getUserAuthTokensInternalScenario(t *testing.T, desc string, token *auth.UserToken, fn scenarioFunc, userService user.Service) {
 	t.Run(desc, func(t *testing.T) {
 		fakeAuthTokenService := authtest.NewFakeUserAuthTokenService()
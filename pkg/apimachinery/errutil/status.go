@@ -37,6 +37,11 @@ const (
 	// there is a conflict in the current state of a resource
 	// HTTP status code 409.
 	StatusConflict CoreStatus = CoreStatus(metav1.StatusReasonConflict)
+	// StatusPreconditionFailed means that the server cannot fulfill
+	// the request since a precondition of the request, such as the
+	// If-Match header, doesn't match the current state of a resource.
+	// HTTP status code 412.
+	StatusPreconditionFailed CoreStatus = "Precondition failed"
 	// StatusTooManyRequests means that the client is rate limited
 	// by the server and should back-off before trying again.
 	// HTTP status code 429.
@@ -115,6 +120,8 @@ func (s CoreStatus) HTTPStatus() int {
 		return http.StatusUnsupportedMediaType
 	case StatusConflict:
 		return http.StatusConflict
+	case StatusPreconditionFailed:
+		return http.StatusPreconditionFailed
 	case StatusTooManyRequests:
 		return http.StatusTooManyRequests
 	case StatusBadRequest, StatusValidationFailed:
@@ -149,6 +156,8 @@ func (s CoreStatus) LogLevel() LogLevel {
 		return LevelInfo
 	case StatusConflict:
 		return LevelInfo
+	case StatusPreconditionFailed:
+		return LevelInfo
 	case StatusTooManyRequests:
 		return LevelInfo
 	case StatusBadRequest:
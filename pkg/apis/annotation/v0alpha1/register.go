@@ -0,0 +1,79 @@
+package v0alpha1
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+)
+
+const (
+	GROUP      = "annotation.grafana.app"
+	VERSION    = "v0alpha1"
+	APIVERSION = GROUP + "/" + VERSION
+)
+
+var AnnotationResourceInfo = utils.NewResourceInfo(GROUP, VERSION,
+	"annotations", "annotation", "Annotation",
+	func() runtime.Object { return &Annotation{} },
+	func() runtime.Object { return &AnnotationList{} },
+	utils.TableColumns{
+		Definition: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name"},
+			{Name: "Text", Type: "string"},
+			{Name: "Dashboard", Type: "string"},
+			{Name: "Time", Type: "date"},
+		},
+		Reader: func(obj any) ([]interface{}, error) {
+			m, ok := obj.(*Annotation)
+			if !ok {
+				return nil, fmt.Errorf("expected annotation")
+			}
+			return []interface{}{
+				m.Name,
+				m.Spec.Text,
+				m.Spec.DashboardUID,
+				time.UnixMilli(m.Spec.Time).UTC().Format(time.RFC3339),
+			}, nil
+		},
+	},
+)
+
+// The fields which can be used in field selectors, besides metadata.name and metadata.namespace
+var SelectableFields = []string{
+	"spec.dashboardUID",
+	"spec.panelID",
+}
+
+var (
+	// SchemeGroupVersion is group version used to register these objects
+	SchemeGroupVersion = schema.GroupVersion{Group: GROUP, Version: VERSION}
+
+	// SchemeBuilder is used by standard codegen
+	SchemeBuilder      runtime.SchemeBuilder
+	localSchemeBuilder = &SchemeBuilder
+	AddToScheme        = localSchemeBuilder.AddToScheme
+)
+
+func init() {
+	localSchemeBuilder.Register(addKnownTypes)
+}
+
+// Adds the list of known types to the given scheme.
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(SchemeGroupVersion,
+		&Annotation{},
+		&AnnotationList{},
+	)
+	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
+	return nil
+}
+
+// Resource takes an unqualified resource and returns a Group qualified GroupResource
+func Resource(resource string) schema.GroupResource {
+	return SchemeGroupVersion.WithResource(resource).GroupResource()
+}
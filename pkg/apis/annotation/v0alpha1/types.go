@@ -0,0 +1,42 @@
+package v0alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type Annotation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AnnotationSpec `json:"spec,omitempty"`
+}
+
+type AnnotationSpec struct {
+	// The annotation text
+	Text string `json:"text"`
+
+	// Time of the annotation in epoch milliseconds
+	Time int64 `json:"time"`
+
+	// End time of region annotations in epoch milliseconds
+	TimeEnd int64 `json:"timeEnd,omitempty"`
+
+	// The dashboard of the annotation, the annotation is shown on all dashboards when empty
+	DashboardUID string `json:"dashboardUID,omitempty"`
+
+	// The panel of the annotation, the annotation is shown on all panels of the dashboard when empty
+	PanelID int64 `json:"panelID,omitempty"`
+
+	// Tags of the annotation
+	// +listType=set
+	Tags []string `json:"tags,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type AnnotationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Annotation `json:"items"`
+}
@@ -0,0 +1,187 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+type request struct {
+	method string
+	path   string
+	body   map[string]any
+}
+
+// fakeGrafana serves the responses by method and path, and records the requests.
+type fakeGrafana struct {
+	responses map[string]string
+	requests  []request
+}
+
+func (f *fakeGrafana) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req := request{method: r.Method, path: r.URL.Path}
+	if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+		_ = json.Unmarshal(data, &req.body)
+		if r.Header.Get("X-Disable-Provenance") != "true" {
+			http.Error(w, `{"message":"provenance isn't disabled"}`, http.StatusBadRequest)
+			return
+		}
+	}
+	f.requests = append(f.requests, req)
+
+	response, ok := f.responses[r.Method+" "+r.URL.Path]
+	if !ok {
+		if r.Method == http.MethodGet {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		response = "{}"
+	}
+	_, _ = w.Write([]byte(response))
+}
+
+func newFakeClient(t *testing.T, f *fakeGrafana) *client {
+	t.Helper()
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	api, err := newClient(server.URL, "token", "", 0)
+	require.NoError(t, err)
+	return api
+}
+
+func TestExport(t *testing.T) {
+	api := newFakeClient(t, &fakeGrafana{responses: map[string]string{
+		"GET /api/v1/provisioning/alert-rules": `[
+			{"uid": "rule-1", "folderUID": "child", "ruleGroup": "group"},
+			{"uid": "rule-2", "folderUID": "child", "ruleGroup": "group"}
+		]`,
+		"GET /api/v1/provisioning/folder/child/rule-groups/group": `{"title": "group", "folderUid": "child", "interval": 60, "rules": [
+			{"uid": "rule-1", "folderUID": "child", "ruleGroup": "group", "title": "Rule 1"},
+			{"uid": "rule-2", "folderUID": "child", "ruleGroup": "group", "title": "Rule 2"}
+		]}`,
+		"GET /api/folders/child":  `{"uid": "child", "title": "Child", "parentUid": "parent"}`,
+		"GET /api/folders/parent": `{"uid": "parent", "title": "Parent"}`,
+		"GET /api/v1/provisioning/contact-points/export": `{"apiVersion": 1, "contactPoints": [
+			{"orgId": 1, "name": "Ops", "receivers": [{"uid": "cp-1", "type": "slack", "settings": {"url": "[REDACTED]"}}]}
+		]}`,
+		"GET /api/v1/provisioning/policies":     `{"receiver": "Ops"}`,
+		"GET /api/v1/provisioning/templates":    `[{"name": "tpl", "template": "{{ define \"tpl\" }}{{ end }}"}]`,
+		"GET /api/v1/provisioning/mute-timings": `[{"name": "weekends"}]`,
+	}})
+
+	bundle, err := export(context.Background(), api, false)
+	require.NoError(t, err)
+	require.Equal(t, bundleVersion, bundle.Version)
+	require.Equal(t, []Folder{{UID: "parent", Title: "Parent"}, {UID: "child", Title: "Child", ParentUID: "parent"}}, bundle.Folders)
+	require.Len(t, bundle.RuleGroups, 1)
+	require.Equal(t, int64(60), bundle.RuleGroups[0].Interval)
+	require.Len(t, bundle.RuleGroups[0].Rules, 2)
+	require.Len(t, bundle.ContactPoints, 1)
+	require.Equal(t, "cp-1", bundle.ContactPoints[0].UID)
+	require.Equal(t, "Ops", bundle.ContactPoints[0].Name)
+	require.JSONEq(t, `{"url":"[REDACTED]"}`, string(bundle.ContactPoints[0].Settings))
+	require.Equal(t, "Ops", bundle.Policies.Receiver)
+	require.Len(t, bundle.Templates, 1)
+	require.Len(t, bundle.MuteTimings, 1)
+}
+
+func TestImport(t *testing.T) {
+	bundle := &Bundle{
+		Version: bundleVersion,
+		Folders: []Folder{{UID: "alerts", Title: "Alerts"}},
+		RuleGroups: []definitions.AlertRuleGroup{{
+			Title:     "group",
+			FolderUID: "alerts",
+			Interval:  60,
+			Rules: []definitions.ProvisionedAlertRule{{
+				ID:        3,
+				UID:       "rule-1",
+				FolderUID: "alerts",
+				Title:     "Rule 1",
+				Data: []definitions.AlertQuery{
+					{RefID: "A", DatasourceUID: "prom-dev", Model: json.RawMessage(`{"datasource":{"type":"prometheus","uid":"prom-dev"},"expr":"up"}`)},
+					{RefID: "B", DatasourceUID: expressionDatasourceUID, Model: json.RawMessage(`{"type":"threshold"}`)},
+				},
+			}},
+		}},
+		ContactPoints: []ContactPoint{
+			{UID: "cp-1", Name: "Ops", Type: "slack", Settings: json.RawMessage(`{"url":"[REDACTED]"}`)},
+			{UID: "cp-2", Name: "Dev", Type: "email", Settings: json.RawMessage(`{"addresses":"dev@example.com"}`)},
+		},
+		Policies:    &definitions.Route{Receiver: "Ops"},
+		Templates:   []definitions.NotificationTemplate{{Name: "tpl", Template: "{{ end }}", ResourceVersion: "1"}},
+		MuteTimings: []definitions.MuteTimeInterval{{Version: "1"}},
+	}
+	bundle.MuteTimings[0].Name = "weekends"
+
+	dir := t.TempDir()
+	uidMapFile := filepath.Join(dir, "uid-map.json")
+	require.NoError(t, os.WriteFile(uidMapFile, []byte(`{"datasources": {"prom-dev": "prom-prod"}, "folders": {"alerts": "prod-alerts"}}`), 0o600))
+	uidMap, err := readUIDMap(uidMapFile)
+	require.NoError(t, err)
+	bundle.remap(uidMap, false)
+
+	f := &fakeGrafana{responses: map[string]string{
+		"GET /api/v1/provisioning/mute-timings":   `[{"name": "weekends"}]`,
+		"GET /api/v1/provisioning/contact-points": `[{"uid": "cp-1", "name": "Ops", "type": "slack"}]`,
+	}}
+	require.NoError(t, importBundle(context.Background(), newFakeClient(t, f), bundle))
+
+	var calls []string
+	byCall := map[string]map[string]any{}
+	for _, r := range f.requests {
+		calls = append(calls, r.method+" "+r.path)
+		byCall[r.method+" "+r.path] = r.body
+	}
+	require.Equal(t, []string{
+		"GET /api/folders/prod-alerts",
+		"POST /api/folders",
+		"PUT /api/v1/provisioning/templates/tpl",
+		"GET /api/v1/provisioning/mute-timings",
+		"PUT /api/v1/provisioning/mute-timings/weekends",
+		"GET /api/v1/provisioning/contact-points",
+		"PUT /api/v1/provisioning/contact-points/cp-1",
+		"POST /api/v1/provisioning/contact-points",
+		"PUT /api/v1/provisioning/policies",
+		"PUT /api/v1/provisioning/folder/prod-alerts/rule-groups/group",
+	}, calls)
+
+	require.Equal(t, "prod-alerts", byCall["POST /api/folders"]["uid"])
+	group := byCall["PUT /api/v1/provisioning/folder/prod-alerts/rule-groups/group"]
+	rule := group["rules"].([]any)[0].(map[string]any)
+	require.Equal(t, "rule-1", rule["uid"])
+	require.Equal(t, "prod-alerts", rule["folderUID"])
+	require.EqualValues(t, 0, rule["id"])
+	data := rule["data"].([]any)
+	require.Equal(t, "prom-prod", data[0].(map[string]any)["datasourceUid"])
+	require.Equal(t, "prom-prod", data[0].(map[string]any)["model"].(map[string]any)["datasource"].(map[string]any)["uid"])
+	require.Equal(t, expressionDatasourceUID, data[1].(map[string]any)["datasourceUid"])
+}
+
+func TestRemapNewUIDs(t *testing.T) {
+	bundle := &Bundle{
+		RuleGroups:    []definitions.AlertRuleGroup{{Rules: []definitions.ProvisionedAlertRule{{UID: "rule-1"}, {UID: "rule-2"}}}},
+		ContactPoints: []ContactPoint{{UID: "cp-1"}},
+	}
+	bundle.remap(UIDMap{Rules: map[string]string{"rule-1": "kept"}}, true)
+	require.Equal(t, "kept", bundle.RuleGroups[0].Rules[0].UID)
+	require.NotEqual(t, "rule-2", bundle.RuleGroups[0].Rules[1].UID)
+	require.NotEmpty(t, bundle.RuleGroups[0].Rules[1].UID)
+	require.NotEqual(t, "cp-1", bundle.ContactPoints[0].UID)
+}
+
+func TestReadBundle(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "bundle.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"version": 2}`), 0o600))
+	_, err := readBundle(file)
+	require.ErrorContains(t, err, "unsupported bundle version 2")
+}
@@ -0,0 +1,148 @@
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// bundleVersion is the version of the bundle format, increased when the format changes incompatibly
+const bundleVersion = 1
+
+// expressionDatasourceUID is the UID of the server side expressions, which isn't a data source
+const expressionDatasourceUID = "__expr__"
+
+// Bundle is the Grafana-managed alerting configuration of an organization.
+type Bundle struct {
+	Version       int                                `json:"version"`
+	ExportedAt    time.Time                          `json:"exportedAt"`
+	Folders       []Folder                           `json:"folders"`
+	RuleGroups    []definitions.AlertRuleGroup       `json:"ruleGroups"`
+	ContactPoints []ContactPoint                     `json:"contactPoints"`
+	Policies      *definitions.Route                 `json:"policies,omitempty"`
+	Templates     []definitions.NotificationTemplate `json:"templates"`
+	MuteTimings   []definitions.MuteTimeInterval     `json:"muteTimings"`
+}
+
+// Folder is a folder of alert rules. The parents of the folders are before them in the bundle.
+type Folder struct {
+	UID       string `json:"uid"`
+	Title     string `json:"title"`
+	ParentUID string `json:"parentUid,omitempty"`
+}
+
+// ContactPoint is an integration of a contact point. The secure settings are redacted unless the bundle was
+// exported with them.
+type ContactPoint struct {
+	UID                   string          `json:"uid"`
+	Name                  string          `json:"name"`
+	Type                  string          `json:"type"`
+	Settings              json.RawMessage `json:"settings"`
+	DisableResolveMessage bool            `json:"disableResolveMessage"`
+}
+
+// UIDMap maps the UIDs of the exported resources to the UIDs used in the instance the bundle is imported into.
+type UIDMap struct {
+	Datasources   map[string]string `json:"datasources"`
+	Folders       map[string]string `json:"folders"`
+	Rules         map[string]string `json:"rules"`
+	ContactPoints map[string]string `json:"contactPoints"`
+}
+
+func readBundle(file string) (*Bundle, error) {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning since the file is given by the user running the command.
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var bundle Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to decode the bundle %s: %w", file, err)
+	}
+	if bundle.Version < 1 || bundle.Version > bundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d, this version of Grafana supports bundles up to version %d", bundle.Version, bundleVersion)
+	}
+	return &bundle, nil
+}
+
+func readUIDMap(file string) (UIDMap, error) {
+	var m UIDMap
+	if file == "" {
+		return m, nil
+	}
+	// nolint:gosec
+	// We can ignore the gosec G304 warning since the file is given by the user running the command.
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("failed to decode the UID map %s: %w", file, err)
+	}
+	return m, nil
+}
+
+// remap replaces the UIDs of the bundle with the UIDs of the map. The rules and contact points which aren't in
+// the map get new UIDs when newUIDs is set, to import copies of them next to the originals.
+func (b *Bundle) remap(m UIDMap, newUIDs bool) {
+	uid := func(mapping map[string]string, value string, generate bool) string {
+		if mapped, ok := mapping[value]; ok {
+			return mapped
+		}
+		if generate && value != "" {
+			return util.GenerateShortUID()
+		}
+		return value
+	}
+
+	for i, f := range b.Folders {
+		b.Folders[i].UID = uid(m.Folders, f.UID, false)
+		b.Folders[i].ParentUID = uid(m.Folders, f.ParentUID, false)
+	}
+	for i, group := range b.RuleGroups {
+		b.RuleGroups[i].FolderUID = uid(m.Folders, group.FolderUID, false)
+		for j, rule := range group.Rules {
+			rule.UID = uid(m.Rules, rule.UID, newUIDs)
+			rule.FolderUID = b.RuleGroups[i].FolderUID
+			for k, query := range rule.Data {
+				if query.DatasourceUID != expressionDatasourceUID {
+					rule.Data[k].DatasourceUID = uid(m.Datasources, query.DatasourceUID, false)
+					rule.Data[k].Model = remapModelDatasource(query.Model, m.Datasources)
+				}
+			}
+			group.Rules[j] = rule
+		}
+	}
+	for i, cp := range b.ContactPoints {
+		b.ContactPoints[i].UID = uid(m.ContactPoints, cp.UID, newUIDs)
+	}
+}
+
+// remapModelDatasource replaces the data source referenced by the query model, which is sent to the data source
+// with the query.
+func remapModelDatasource(model json.RawMessage, datasources map[string]string) json.RawMessage {
+	var query map[string]any
+	if len(datasources) == 0 || json.Unmarshal(model, &query) != nil {
+		return model
+	}
+	ref, ok := query["datasource"].(map[string]any)
+	if !ok {
+		return model
+	}
+	current, _ := ref["uid"].(string)
+	mapped, ok := datasources[current]
+	if !ok {
+		return model
+	}
+	ref["uid"] = mapped
+	data, err := json.Marshal(query)
+	if err != nil {
+		return model
+	}
+	return data
+}
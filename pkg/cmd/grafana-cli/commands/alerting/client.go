@@ -0,0 +1,117 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// client calls the HTTP API of a Grafana server, with the permissions of the service account token or of the
+// user of the basic authentication.
+type client struct {
+	url      string
+	token    string
+	user     string
+	password string
+	orgID    int64
+	http     *http.Client
+}
+
+func newClient(grafanaURL, token, basicAuth string, orgID int64) (*client, error) {
+	u, err := url.Parse(grafanaURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid Grafana URL %q", grafanaURL)
+	}
+	c := &client{
+		url:   strings.TrimSuffix(grafanaURL, "/"),
+		token: token,
+		orgID: orgID,
+		http:  &http.Client{Timeout: 30 * time.Second},
+	}
+	if basicAuth != "" {
+		user, password, ok := strings.Cut(basicAuth, ":")
+		if !ok {
+			return nil, fmt.Errorf("the basic authentication should be formatted as <user>:<password>")
+		}
+		c.user, c.password = user, password
+	}
+	return c, nil
+}
+
+type httpError struct {
+	statusCode int
+	message    string
+}
+
+func (e *httpError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.statusCode, http.StatusText(e.statusCode), e.message)
+}
+
+func isNotFound(err error) bool {
+	httpErr, ok := err.(*httpError)
+	return ok && httpErr.statusCode == http.StatusNotFound
+}
+
+// do sends the request with the body encoded as JSON, and decodes the JSON response to out when it's not nil.
+// The imported resources aren't marked as provisioned, so that they can still be edited in the UI.
+func (c *client) do(ctx context.Context, method, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Disable-Provenance", "true")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	} else if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+	if c.orgID != 0 {
+		req.Header.Set("X-Grafana-Org-Id", strconv.FormatInt(c.orgID, 10))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		return &httpError{statusCode: resp.StatusCode, message: apiErr.Message}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode the response of %s %s: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const provisioningAPI = "/api/v1/provisioning"
+
+// ExportCommand exports the Grafana-managed alert rules, contact points, notification policies, notification
+// templates and mute timings of an organization to the bundle file given by the --file flag.
+func ExportCommand(c utils.CommandLine) error {
+	api, err := newClientFromFlags(c)
+	if err != nil {
+		return err
+	}
+	file := c.String("file")
+	if file == "" {
+		return errors.New("the bundle file is missing, set it with --file")
+	}
+
+	bundle, err := export(context.Background(), api, c.Bool("include-secrets"))
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		return err
+	}
+
+	logger.Infof("Exported %d rule groups, %d contact points, %d templates and %d mute timings to %s %s",
+		len(bundle.RuleGroups), len(bundle.ContactPoints), len(bundle.Templates), len(bundle.MuteTimings), file, color.GreenString("✔"))
+	if !c.Bool("include-secrets") {
+		logger.Warnf("The secure settings of the contact points are redacted, use --include-secrets to export them")
+	}
+	return nil
+}
+
+func newClientFromFlags(c utils.CommandLine) (*client, error) {
+	if c.String("url") == "" {
+		return nil, errors.New("the URL of the Grafana server is missing, set it with --url")
+	}
+	return newClient(c.String("url"), c.String("token"), c.String("basic-auth"), int64(c.Int("org-id")))
+}
+
+func export(ctx context.Context, api *client, includeSecrets bool) (*Bundle, error) {
+	bundle := &Bundle{Version: bundleVersion, ExportedAt: time.Now().UTC()}
+
+	var rules []definitions.ProvisionedAlertRule
+	if err := api.do(ctx, http.MethodGet, provisioningAPI+"/alert-rules", nil, &rules); err != nil {
+		return nil, fmt.Errorf("failed to get the alert rules: %w", err)
+	}
+	type groupKey struct{ folderUID, name string }
+	seen := map[groupKey]bool{}
+	folders := map[string]bool{}
+	for _, rule := range rules {
+		key := groupKey{rule.FolderUID, rule.RuleGroup}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		var group definitions.AlertRuleGroup
+		path := fmt.Sprintf("%s/folder/%s/rule-groups/%s", provisioningAPI, url.PathEscape(key.folderUID), url.PathEscape(key.name))
+		if err := api.do(ctx, http.MethodGet, path, nil, &group); err != nil {
+			return nil, fmt.Errorf("failed to get rule group %s: %w", key.name, err)
+		}
+		bundle.RuleGroups = append(bundle.RuleGroups, group)
+		if err := addFolder(ctx, api, bundle, folders, key.folderUID); err != nil {
+			return nil, err
+		}
+	}
+
+	var receivers definitions.AlertingFileExport
+	path := fmt.Sprintf("%s/contact-points/export?format=json&decrypt=%t", provisioningAPI, includeSecrets)
+	if err := api.do(ctx, http.MethodGet, path, nil, &receivers); err != nil {
+		return nil, fmt.Errorf("failed to get the contact points: %w", err)
+	}
+	for _, cp := range receivers.ContactPoints {
+		for _, r := range cp.Receivers {
+			bundle.ContactPoints = append(bundle.ContactPoints, ContactPoint{
+				UID:                   r.UID,
+				Name:                  cp.Name,
+				Type:                  r.Type,
+				Settings:              json.RawMessage(r.Settings),
+				DisableResolveMessage: r.DisableResolveMessage,
+			})
+		}
+	}
+
+	if err := api.do(ctx, http.MethodGet, provisioningAPI+"/policies", nil, &bundle.Policies); err != nil {
+		return nil, fmt.Errorf("failed to get the notification policies: %w", err)
+	}
+	if err := api.do(ctx, http.MethodGet, provisioningAPI+"/templates", nil, &bundle.Templates); err != nil {
+		return nil, fmt.Errorf("failed to get the notification templates: %w", err)
+	}
+	if err := api.do(ctx, http.MethodGet, provisioningAPI+"/mute-timings", nil, &bundle.MuteTimings); err != nil {
+		return nil, fmt.Errorf("failed to get the mute timings: %w", err)
+	}
+	return bundle, nil
+}
+
+// addFolder adds the folder and its parents to the bundle, the parents first.
+func addFolder(ctx context.Context, api *client, bundle *Bundle, added map[string]bool, uid string) error {
+	if uid == "" || added[uid] {
+		return nil
+	}
+	var folder Folder
+	if err := api.do(ctx, http.MethodGet, "/api/folders/"+url.PathEscape(uid), nil, &folder); err != nil {
+		return fmt.Errorf("failed to get folder %s: %w", uid, err)
+	}
+	if err := addFolder(ctx, api, bundle, added, folder.ParentUID); err != nil {
+		return err
+	}
+	added[uid] = true
+	bundle.Folders = append(bundle.Folders, folder)
+	return nil
+}
@@ -0,0 +1,131 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// ImportCommand imports the bundle file given by the --file flag, exported with ExportCommand. The UIDs of the
+// data sources, folders, rules and contact points are replaced with the ones of the JSON file given by the
+// --uid-map flag, and the rules and contact points get new UIDs with the --new-uids flag.
+func ImportCommand(c utils.CommandLine) error {
+	api, err := newClientFromFlags(c)
+	if err != nil {
+		return err
+	}
+	file := c.String("file")
+	if file == "" {
+		return errors.New("the bundle file is missing, set it with --file")
+	}
+	bundle, err := readBundle(file)
+	if err != nil {
+		return err
+	}
+	uidMap, err := readUIDMap(c.String("uid-map"))
+	if err != nil {
+		return err
+	}
+
+	bundle.remap(uidMap, c.Bool("new-uids"))
+	if err := importBundle(context.Background(), api, bundle); err != nil {
+		return err
+	}
+	logger.Infof("Imported %d rule groups, %d contact points, %d templates and %d mute timings from %s %s",
+		len(bundle.RuleGroups), len(bundle.ContactPoints), len(bundle.Templates), len(bundle.MuteTimings), file, color.GreenString("✔"))
+	return nil
+}
+
+// importBundle creates or updates the resources of the bundle, in the order of their dependencies: the
+// policies and rules reference the contact points and mute timings, and the contact points reference the
+// templates. The notification policy tree is replaced by the one of the bundle.
+func importBundle(ctx context.Context, api *client, bundle *Bundle) error {
+	for _, f := range bundle.Folders {
+		err := api.do(ctx, http.MethodGet, "/api/folders/"+url.PathEscape(f.UID), nil, nil)
+		if err == nil {
+			continue
+		}
+		if !isNotFound(err) {
+			return fmt.Errorf("failed to get folder %s: %w", f.Title, err)
+		}
+		if err := api.do(ctx, http.MethodPost, "/api/folders", f, nil); err != nil {
+			return fmt.Errorf("failed to create folder %s: %w", f.Title, err)
+		}
+	}
+
+	for _, t := range bundle.Templates {
+		body := definitions.NotificationTemplate{Name: t.Name, Template: t.Template}
+		if err := api.do(ctx, http.MethodPut, provisioningAPI+"/templates/"+url.PathEscape(t.Name), body, nil); err != nil {
+			return fmt.Errorf("failed to import template %s: %w", t.Name, err)
+		}
+	}
+
+	var muteTimings []definitions.MuteTimeInterval
+	if err := api.do(ctx, http.MethodGet, provisioningAPI+"/mute-timings", nil, &muteTimings); err != nil {
+		return fmt.Errorf("failed to get the mute timings: %w", err)
+	}
+	existing := map[string]bool{}
+	for _, mt := range muteTimings {
+		existing[mt.Name] = true
+	}
+	for _, mt := range bundle.MuteTimings {
+		mt.Version, mt.Provenance = "", ""
+		var err error
+		if existing[mt.Name] {
+			err = api.do(ctx, http.MethodPut, provisioningAPI+"/mute-timings/"+url.PathEscape(mt.Name), mt, nil)
+		} else {
+			err = api.do(ctx, http.MethodPost, provisioningAPI+"/mute-timings", mt, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to import mute timing %s: %w", mt.Name, err)
+		}
+	}
+
+	var contactPoints []ContactPoint
+	if err := api.do(ctx, http.MethodGet, provisioningAPI+"/contact-points", nil, &contactPoints); err != nil {
+		return fmt.Errorf("failed to get the contact points: %w", err)
+	}
+	existing = map[string]bool{}
+	for _, cp := range contactPoints {
+		existing[cp.UID] = true
+	}
+	for _, cp := range bundle.ContactPoints {
+		var err error
+		if existing[cp.UID] {
+			// the redacted secure settings keep their current values
+			err = api.do(ctx, http.MethodPut, provisioningAPI+"/contact-points/"+url.PathEscape(cp.UID), cp, nil)
+		} else {
+			err = api.do(ctx, http.MethodPost, provisioningAPI+"/contact-points", cp, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to import contact point %s: %w", cp.Name, err)
+		}
+	}
+
+	if bundle.Policies != nil {
+		policies := *bundle.Policies
+		policies.Provenance = ""
+		if err := api.do(ctx, http.MethodPut, provisioningAPI+"/policies", policies, nil); err != nil {
+			return fmt.Errorf("failed to import the notification policies: %w", err)
+		}
+	}
+
+	for _, group := range bundle.RuleGroups {
+		for i := range group.Rules {
+			group.Rules[i].ID, group.Rules[i].OrgID, group.Rules[i].Provenance = 0, 0, ""
+		}
+		path := fmt.Sprintf("%s/folder/%s/rule-groups/%s", provisioningAPI, url.PathEscape(group.FolderUID), url.PathEscape(group.Title))
+		if err := api.do(ctx, http.MethodPut, path, group, nil); err != nil {
+			return fmt.Errorf("failed to import rule group %s: %w", group.Title, err)
+		}
+	}
+	return nil
+}
@@ -7,9 +7,13 @@ import (
 
 	"github.com/urfave/cli/v2"
 
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/alerting"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/dashboards"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/datamigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/secretsconsolidation"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/secretsmigrations"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/usagereport"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/usermanagement"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
 	"github.com/grafana/grafana/pkg/infra/db"
@@ -51,6 +55,23 @@ func runDbCommand(command func(commandLine utils.CommandLine, cfg *setting.Cfg,
 	}
 }
 
+// runDashboardsCommand runs the command against the database of the Grafana instance when the --offline flag
+// is set, and otherwise against the HTTP API of a Grafana server, without reading the configuration.
+func runDashboardsCommand(command func(commandLine utils.CommandLine, sqlStore db.DB) error) func(context *cli.Context) error {
+	return func(context *cli.Context) error {
+		cmd := &utils.ContextCommandLine{Context: context}
+		var sqlStore db.DB
+		if cmd.Bool("offline") {
+			runner, err := initializeRunner(context.Context, cmd)
+			if err != nil {
+				return fmt.Errorf("%v: %w", "failed to initialize runner", err)
+			}
+			sqlStore = runner.SQLStore
+		}
+		return command(cmd, sqlStore)
+	}
+}
+
 func initializeRunner(ctx context.Context, cmd *utils.ContextCommandLine) (server.Runner, error) {
 	configOptions := strings.Split(cmd.String("configOverrides"), " ")
 	cfg, err := setting.NewCfgFromArgs(setting.CommandLineArgs{
@@ -165,6 +186,79 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:  "user-manager",
+		Usage: "Runs commands managing the users in bulk",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "import-users",
+				Usage:  "import-users <users file>. Creates the users of a CSV or JSON file and assigns them to their organizations and teams. Existing users are only assigned.",
+				Action: runRunnerCommand(usermanagement.ImportUsers),
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "org-id",
+						Usage: "ID of the organization of the users without organization",
+						Value: 1,
+					},
+				},
+			},
+			{
+				Name:   "deactivate-users",
+				Usage:  "Disables the users who haven't logged in since a date, and revokes their sessions",
+				Action: runRunnerCommand(usermanagement.DeactivateUsers),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "last-seen-before",
+						Usage: "Date before which the users were last seen, formatted as 2006-01-02 or in RFC 3339",
+					},
+					&cli.BoolFlag{
+						Name:  "include-admins",
+						Usage: "Also disable the Grafana server administrators",
+						Value: false,
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Only list the users who would be disabled",
+						Value: false,
+					},
+				},
+			},
+			{
+				Name:   "merge-users",
+				Usage:  "merge-users <duplicate user> <user to keep>. Reassigns the roles, teams, preferences and owned resources of a duplicate user to another user, and deletes the duplicate user. The users can be given by login, email or ID.",
+				Action: runRunnerCommand(usermanagement.MergeUsers),
+			},
+		},
+	},
+	{
+		Name:   "export-usage-report",
+		Usage:  "Export the anonymous usage report of a Grafana server, with the metrics of the registered collectors, to review or ingest it",
+		Action: runPluginCommand(usagereport.ExportCommand),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "file",
+				Usage: "Path of the exported file, defaults to the standard output",
+			},
+			&cli.BoolFlag{
+				Name:  "report-only",
+				Usage: "Only export the report, as Grafana sends it",
+			},
+			&cli.StringFlag{
+				Name:  "url",
+				Usage: "URL of the Grafana server",
+			},
+			&cli.StringFlag{
+				Name:    "token",
+				Usage:   "Service account token used to authenticate to the Grafana server",
+				EnvVars: []string{"GRAFANA_TOKEN"},
+			},
+			&cli.StringFlag{
+				Name:    "basic-auth",
+				Usage:   "User and password used to authenticate to the Grafana server, formatted as <user>:<password>",
+				EnvVars: []string{"GRAFANA_BASIC_AUTH"},
+			},
+		},
+	},
 	{
 		Name:  "secrets-migration",
 		Usage: "Runs a script that migrates secrets in your database",
@@ -199,6 +293,110 @@ var adminCommands = []*cli.Command{
 	},
 }
 
+var dashboardsFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "dir",
+		Usage: "Directory of the exported dashboards",
+	},
+	&cli.StringFlag{
+		Name:  "url",
+		Usage: "URL of the Grafana server",
+	},
+	&cli.StringFlag{
+		Name:    "token",
+		Usage:   "Service account token used to authenticate to the Grafana server",
+		EnvVars: []string{"GRAFANA_TOKEN"},
+	},
+	&cli.StringFlag{
+		Name:    "basic-auth",
+		Usage:   "User and password used to authenticate to the Grafana server, formatted as <user>:<password>",
+		EnvVars: []string{"GRAFANA_BASIC_AUTH"},
+	},
+	&cli.IntFlag{
+		Name:  "org-id",
+		Usage: "ID of the organization of the dashboards",
+		Value: 1,
+	},
+	&cli.BoolFlag{
+		Name:  "offline",
+		Usage: "Read and write the dashboards directly in the database of the configured Grafana instance, which shouldn't be running",
+	},
+}
+
+var dashboardsCommands = []*cli.Command{
+	{
+		Name:   "export",
+		Usage:  "Export the folders, dashboards and library panels of an organization to a directory",
+		Action: runDashboardsCommand(dashboards.ExportCommand),
+		Flags:  dashboardsFlags,
+	},
+	{
+		Name:   "import",
+		Usage:  "Import the folders, library panels and dashboards of a directory exported with the export command",
+		Action: runDashboardsCommand(dashboards.ImportCommand),
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:  "datasource-map",
+				Usage: "JSON file mapping the UIDs of the exported data sources to the UIDs of the data sources to use instead",
+			},
+		}, dashboardsFlags...),
+	},
+}
+
+var alertingFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "file",
+		Usage: "Path of the bundle file",
+	},
+	&cli.StringFlag{
+		Name:  "url",
+		Usage: "URL of the Grafana server",
+	},
+	&cli.StringFlag{
+		Name:    "token",
+		Usage:   "Service account token used to authenticate to the Grafana server",
+		EnvVars: []string{"GRAFANA_TOKEN"},
+	},
+	&cli.StringFlag{
+		Name:    "basic-auth",
+		Usage:   "User and password used to authenticate to the Grafana server, formatted as <user>:<password>",
+		EnvVars: []string{"GRAFANA_BASIC_AUTH"},
+	},
+	&cli.IntFlag{
+		Name:  "org-id",
+		Usage: "ID of the organization of the alerting configuration, defaults to the organization of the token or user",
+	},
+}
+
+var alertingCommands = []*cli.Command{
+	{
+		Name:   "export",
+		Usage:  "Export the alert rules, contact points, notification policies, templates and mute timings of an organization to a bundle file",
+		Action: runPluginCommand(alerting.ExportCommand),
+		Flags: append([]cli.Flag{
+			&cli.BoolFlag{
+				Name:  "include-secrets",
+				Usage: "Export the secure settings of the contact points decrypted, instead of redacted",
+			},
+		}, alertingFlags...),
+	},
+	{
+		Name:   "import",
+		Usage:  "Import a bundle file exported with the export command",
+		Action: runPluginCommand(alerting.ImportCommand),
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:  "uid-map",
+				Usage: "JSON file mapping the exported UIDs of data sources, folders, rules and contact points to the UIDs to use instead",
+			},
+			&cli.BoolFlag{
+				Name:  "new-uids",
+				Usage: "Give new UIDs to the rules and contact points which aren't in the UID map, to import copies of them",
+			},
+		}, alertingFlags...),
+	},
+}
+
 var Commands = []*cli.Command{
 	{
 		Name:        "plugins",
@@ -210,4 +408,14 @@ var Commands = []*cli.Command{
 		Usage:       "Grafana admin commands",
 		Subcommands: adminCommands,
 	},
+	{
+		Name:        "dashboards",
+		Usage:       "Export and import dashboards",
+		Subcommands: dashboardsCommands,
+	},
+	{
+		Name:        "alerting",
+		Usage:       "Export and import the Grafana-managed alerting configuration",
+		Subcommands: alertingCommands,
+	},
 }
@@ -0,0 +1,58 @@
+package dashboards
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// ExportCommand exports the folders, dashboards and library panels of an organization to the directory given
+// by the --dir flag. The dashboards are read through the HTTP API of the Grafana server given by the --url
+// flag, or directly in the database when sqlStore isn't nil.
+func ExportCommand(c utils.CommandLine, sqlStore db.DB) error {
+	s, err := newStore(c, sqlStore)
+	if err != nil {
+		return err
+	}
+	dir := c.String("dir")
+	if dir == "" {
+		return errors.New("the directory to export the dashboards to is missing, set it with --dir")
+	}
+	return export(context.Background(), s, dir)
+}
+
+// ImportCommand imports the folders, library panels and dashboards exported to the directory given by the --dir
+// flag, like ExportCommand.
+func ImportCommand(c utils.CommandLine, sqlStore db.DB) error {
+	s, err := newStore(c, sqlStore)
+	if err != nil {
+		return err
+	}
+	dir := c.String("dir")
+	if dir == "" {
+		return errors.New("the directory to import the dashboards from is missing, set it with --dir")
+	}
+	datasourceMap, err := readDatasourceMap(c.String("datasource-map"))
+	if err != nil {
+		return err
+	}
+	return importDir(context.Background(), s, dir, datasourceMap)
+}
+
+func newStore(c utils.CommandLine, sqlStore db.DB) (store, error) {
+	if sqlStore != nil {
+		return &dbStore{db: sqlStore, orgID: int64(c.Int("org-id"))}, nil
+	}
+	if c.String("url") == "" {
+		return nil, errors.New("the URL of the Grafana server is missing, set it with --url or use --offline")
+	}
+	// the organization of service account tokens can't be changed, so it's only switched when asked
+	var orgID int64
+	if slices.Contains(c.FlagNames(), "org-id") {
+		orgID = int64(c.Int("org-id"))
+	}
+	return newHTTPStore(c.String("url"), c.String("token"), c.String("basic-auth"), orgID)
+}
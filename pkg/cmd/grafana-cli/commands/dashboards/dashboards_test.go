@@ -0,0 +1,192 @@
+package dashboards
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+type fakeStore struct {
+	folders       []Folder
+	dashboards    []Dashboard
+	libraryPanels []LibraryPanel
+	datasources   []Datasource
+}
+
+func (s *fakeStore) Folders(context.Context) ([]Folder, error) { return s.folders, nil }
+
+func (s *fakeStore) Dashboards(context.Context) ([]Dashboard, error) { return s.dashboards, nil }
+
+func (s *fakeStore) LibraryPanels(context.Context) ([]LibraryPanel, error) {
+	return s.libraryPanels, nil
+}
+
+func (s *fakeStore) Datasources(context.Context) ([]Datasource, error) { return s.datasources, nil }
+
+func (s *fakeStore) SaveFolder(_ context.Context, folder Folder) error {
+	s.folders = append(s.folders, folder)
+	return nil
+}
+
+func (s *fakeStore) SaveLibraryPanel(_ context.Context, panel LibraryPanel) error {
+	s.libraryPanels = append(s.libraryPanels, panel)
+	return nil
+}
+
+func (s *fakeStore) SaveDashboard(_ context.Context, dashboard Dashboard) error {
+	s.dashboards = append(s.dashboards, dashboard)
+	return nil
+}
+
+func dashboardModel(uid, title, datasourceUID string) map[string]any {
+	return map[string]any{
+		"id":    float64(12),
+		"uid":   uid,
+		"title": title,
+		"panels": []any{
+			map[string]any{"id": float64(1), "datasource": map[string]any{"type": "prometheus", "uid": datasourceUID}},
+			map[string]any{"id": float64(2), "libraryPanel": map[string]any{"uid": "lib", "name": "Shared"}},
+			map[string]any{"id": float64(3), "datasource": map[string]any{"type": "datasource", "uid": "-- Mixed --"}},
+		},
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	source := &fakeStore{
+		folders: []Folder{
+			{UID: "child", Title: "Child", ParentUID: "root"},
+			{UID: "root", Title: "Team A"},
+			{UID: "other", Title: "Team A"},
+		},
+		dashboards: []Dashboard{
+			{UID: "home", Title: "Home", Model: dashboardModel("home", "Home", "prom")},
+			{UID: "nested", Title: "Nested", FolderUID: "child", Model: dashboardModel("nested", "Nested", "loki")},
+		},
+		libraryPanels: []LibraryPanel{
+			{UID: "lib", Name: "Shared", FolderUID: "root", Model: json.RawMessage(`{"type":"timeseries","datasource":{"uid":"prom"}}`)},
+		},
+		datasources: []Datasource{
+			{UID: "prom", Name: "Prometheus", Type: "prometheus"},
+			{UID: "loki", Name: "Loki", Type: "loki"},
+			{UID: "unused", Name: "Unused", Type: "mysql"},
+		},
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, export(context.Background(), source, dir))
+
+	var folders []Folder
+	require.NoError(t, readJSON(filepath.Join(dir, foldersFile), &folders))
+	require.Equal(t, []Folder{
+		{UID: "root", Title: "Team A", Path: "team-a"},
+		{UID: "other", Title: "Team A", Path: "team-a-other"},
+		{UID: "child", Title: "Child", ParentUID: "root", Path: "team-a/child"},
+	}, folders)
+	require.FileExists(t, filepath.Join(dir, dashboardsDir, "home-home.json"))
+	require.FileExists(t, filepath.Join(dir, dashboardsDir, "team-a", "child", "nested-nested.json"))
+	require.FileExists(t, filepath.Join(dir, libraryPanelsDir, "lib.json"))
+
+	var datasources []Datasource
+	require.NoError(t, readJSON(filepath.Join(dir, datasourcesFile), &datasources))
+	require.Equal(t, []Datasource{{UID: "loki", Name: "Loki", Type: "loki"}, {UID: "prom", Name: "Prometheus", Type: "prometheus"}}, datasources)
+
+	target := &fakeStore{
+		datasources: []Datasource{
+			{UID: "prometheus-b", Name: "Prometheus", Type: "prometheus"},
+			{UID: "loki-b", Name: "Logs", Type: "loki"},
+		},
+	}
+	require.NoError(t, importDir(context.Background(), target, dir, map[string]string{"loki": "loki-b"}))
+
+	require.Len(t, target.folders, 3)
+	require.Equal(t, "child", target.folders[2].UID, "the subfolders should be saved after their parents")
+	require.Equal(t, []LibraryPanel{
+		{UID: "lib", Name: "Shared", FolderUID: "root", Model: json.RawMessage(`{"datasource":{"uid":"prometheus-b"},"type":"timeseries"}`)},
+	}, target.libraryPanels)
+
+	require.Len(t, target.dashboards, 2)
+	byUID := map[string]Dashboard{}
+	for _, d := range target.dashboards {
+		byUID[d.UID] = d
+		require.NotContains(t, d.Model, "id")
+		require.Equal(t, []string{"lib"}, libraryPanelRefs(d.Model))
+	}
+	require.Equal(t, "", byUID["home"].FolderUID)
+	require.Equal(t, "child", byUID["nested"].FolderUID)
+
+	refs := map[string]struct{}{}
+	datasourceRefs(byUID["home"].Model, refs)
+	require.Equal(t, map[string]struct{}{"prometheus-b": {}}, refs, "the data sources should be mapped by name")
+	refs = map[string]struct{}{}
+	datasourceRefs(byUID["nested"].Model, refs)
+	require.Equal(t, map[string]struct{}{"loki-b": {}}, refs, "the data sources should be mapped by the data source map")
+}
+
+func TestImportRejectsUnknownFolders(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, writeJSON(filepath.Join(dir, dashboardsDir, "unknown", "dashboard.json"), dashboardModel("uid", "Title", "prom")))
+
+	err := importDir(context.Background(), &fakeStore{}, dir, nil)
+	require.ErrorContains(t, err, "isn't a folder")
+}
+
+func TestIntegrationDBStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	s := &dbStore{db: db.InitTestDB(t), orgID: 1}
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	require.NoError(t, writeJSON(filepath.Join(dir, foldersFile), []Folder{
+		{UID: "parent", Title: "Parent", Path: "parent"},
+		{UID: "child", Title: "Child", ParentUID: "parent", Path: "parent/child"},
+	}))
+	require.NoError(t, writeJSON(filepath.Join(dir, libraryPanelsDir, "lib.json"), LibraryPanel{
+		UID: "lib", Name: "Shared", FolderUID: "parent", Model: json.RawMessage(`{"type":"stat"}`),
+	}))
+	require.NoError(t, writeJSON(filepath.Join(dir, dashboardsDir, "parent", "child", "dash.json"), dashboardModel("dash", "Dash", "prom")))
+
+	// importing twice updates the existing folders, library panels and dashboards
+	require.NoError(t, importDir(ctx, s, dir, nil))
+	require.NoError(t, importDir(ctx, s, dir, nil))
+
+	folders, err := s.Folders(ctx)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []Folder{{UID: "parent", Title: "Parent"}, {UID: "child", Title: "Child", ParentUID: "parent"}}, folders)
+
+	dashboards, err := s.Dashboards(ctx)
+	require.NoError(t, err)
+	require.Len(t, dashboards, 1)
+	require.Equal(t, "child", dashboards[0].FolderUID)
+	require.Equal(t, json.Number("2"), dashboards[0].Model["version"])
+
+	panels, err := s.LibraryPanels(ctx)
+	require.NoError(t, err)
+	require.Len(t, panels, 1)
+	require.Equal(t, "parent", panels[0].FolderUID)
+
+	var connections int64
+	err = s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		connections, err = sess.Table("library_element_connection").Count()
+		return err
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), connections)
+
+	out := t.TempDir()
+	require.NoError(t, export(ctx, s, out))
+	_, err = os.Stat(filepath.Join(out, dashboardsDir, "parent", "child", "dash-dash.json"))
+	require.NoError(t, err)
+}
@@ -0,0 +1,318 @@
+package dashboards
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/slugify"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+)
+
+const importMessage = "Imported with grafana cli"
+
+// dbStore reads and writes the dashboards directly in the database, when Grafana doesn't run. It doesn't
+// check permissions, and it only handles the dashboards kept in the legacy SQL tables.
+type dbStore struct {
+	db    db.DB
+	orgID int64
+}
+
+type folderRow struct {
+	UID       string `xorm:"uid"`
+	Title     string `xorm:"title"`
+	ParentUID string `xorm:"parent_uid"`
+}
+
+type datasourceRow struct {
+	UID  string `xorm:"uid"`
+	Name string `xorm:"name"`
+	Type string `xorm:"type"`
+}
+
+func (s *dbStore) Folders(ctx context.Context) ([]Folder, error) {
+	var rows []folderRow
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("folder").Where("org_id = ?", s.orgID).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	folders := make([]Folder, 0, len(rows))
+	for _, row := range rows {
+		folders = append(folders, Folder{UID: row.UID, Title: row.Title, ParentUID: row.ParentUID})
+	}
+	return folders, nil
+}
+
+func (s *dbStore) Dashboards(ctx context.Context) ([]Dashboard, error) {
+	var rows []*dashboards.Dashboard
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND is_folder = ? AND deleted IS NULL", s.orgID, s.db.GetDialect().BooleanValue(false)).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Dashboard, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, Dashboard{UID: row.UID, Title: row.Title, FolderUID: row.FolderUID, Model: row.Data.MustMap()})
+	}
+	return result, nil
+}
+
+func (s *dbStore) LibraryPanels(ctx context.Context) ([]LibraryPanel, error) {
+	var rows []model.LibraryElement
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND kind = ?", s.orgID, libraryPanelKind).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	panels := make([]LibraryPanel, 0, len(rows))
+	for _, row := range rows {
+		panels = append(panels, LibraryPanel{UID: row.UID, Name: row.Name, FolderUID: row.FolderUID, Model: row.Model})
+	}
+	return panels, nil
+}
+
+func (s *dbStore) Datasources(ctx context.Context) ([]Datasource, error) {
+	var rows []datasourceRow
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("data_source").Where("org_id = ?", s.orgID).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	datasources := make([]Datasource, 0, len(rows))
+	for _, row := range rows {
+		datasources = append(datasources, Datasource(row))
+	}
+	return datasources, nil
+}
+
+func (s *dbStore) inTransaction(ctx context.Context, fn func(sess *db.Session) error) error {
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		return s.db.WithDbSession(ctx, fn)
+	})
+}
+
+// folderID returns the ID of the dashboard row of the folder, which is still set in the deprecated folder_id
+// columns.
+func (s *dbStore) folderID(sess *db.Session, folderUID string) (int64, error) {
+	if folderUID == "" {
+		return 0, nil
+	}
+	var folder dashboards.Dashboard
+	has, err := sess.Where("org_id = ? AND uid = ? AND is_folder = ?", s.orgID, folderUID, s.db.GetDialect().BooleanValue(true)).Get(&folder)
+	if err != nil {
+		return 0, err
+	}
+	if !has {
+		return 0, dashboards.ErrFolderNotFound
+	}
+	return folder.ID, nil
+}
+
+func (s *dbStore) SaveFolder(ctx context.Context, folder Folder) error {
+	return s.inTransaction(ctx, func(sess *db.Session) error {
+		parentID, err := s.folderID(sess, folder.ParentUID)
+		if err != nil {
+			return err
+		}
+		var parentUID any
+		if folder.ParentUID != "" {
+			parentUID = folder.ParentUID
+		}
+		now := time.Now()
+
+		var existing dashboards.Dashboard
+		has, err := sess.Where("org_id = ? AND uid = ? AND is_folder = ?", s.orgID, folder.UID, s.db.GetDialect().BooleanValue(true)).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if has {
+			existing.Title = folder.Title
+			existing.Slug = slugify.Slugify(folder.Title)
+			existing.FolderID = parentID // nolint:staticcheck
+			existing.FolderUID = folder.ParentUID
+			existing.Updated = now
+			existing.Data.Set("title", folder.Title)
+			if _, err := sess.MustCols("folder_id", "folder_uid").Nullable("folder_uid").ID(existing.ID).Update(&existing); err != nil {
+				return err
+			}
+			_, err = sess.Exec("UPDATE folder SET title = ?, parent_uid = ?, updated = ? WHERE org_id = ? AND uid = ?",
+				folder.Title, parentUID, now, s.orgID, folder.UID)
+			return err
+		}
+
+		data := simplejson.New()
+		data.Set("uid", folder.UID)
+		data.Set("title", folder.Title)
+		dash := &dashboards.Dashboard{
+			UID:       folder.UID,
+			Slug:      slugify.Slugify(folder.Title),
+			OrgID:     s.orgID,
+			Version:   1,
+			Created:   now,
+			Updated:   now,
+			FolderID:  parentID, // nolint:staticcheck
+			FolderUID: folder.ParentUID,
+			IsFolder:  true,
+			Title:     folder.Title,
+			Data:      data,
+		}
+		if _, err := sess.Nullable("folder_uid").Insert(dash); err != nil {
+			return err
+		}
+		_, err = sess.Exec("INSERT INTO folder(org_id, uid, parent_uid, title, description, created, updated) VALUES(?, ?, ?, ?, ?, ?, ?)",
+			s.orgID, folder.UID, parentUID, folder.Title, "", now, now)
+		return err
+	})
+}
+
+func (s *dbStore) SaveLibraryPanel(ctx context.Context, panel LibraryPanel) error {
+	var props struct {
+		Type        string `json:"type"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(panel.Model, &props); err != nil {
+		return err
+	}
+
+	return s.inTransaction(ctx, func(sess *db.Session) error {
+		folderID, err := s.folderID(sess, panel.FolderUID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+
+		var existing model.LibraryElement
+		has, err := sess.Where("org_id = ? AND uid = ?", s.orgID, panel.UID).Get(&existing)
+		if err != nil {
+			return err
+		}
+		element := model.LibraryElement{
+			OrgID:       s.orgID,
+			FolderID:    folderID, // nolint:staticcheck
+			FolderUID:   panel.FolderUID,
+			UID:         panel.UID,
+			Name:        panel.Name,
+			Kind:        libraryPanelKind,
+			Type:        props.Type,
+			Description: props.Description,
+			Model:       panel.Model,
+			Version:     1,
+			Created:     now,
+			Updated:     now,
+		}
+		if has {
+			element.Version = existing.Version + 1
+			element.Created = existing.Created
+			element.CreatedBy = existing.CreatedBy
+			_, err = sess.MustCols("folder_id", "folder_uid", "description").ID(existing.ID).Update(&element)
+			return err
+		}
+		_, err = sess.Insert(&element)
+		return err
+	})
+}
+
+func (s *dbStore) SaveDashboard(ctx context.Context, dashboard Dashboard) error {
+	return s.inTransaction(ctx, func(sess *db.Session) error {
+		folderID, err := s.folderID(sess, dashboard.FolderUID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+
+		var existing dashboards.Dashboard
+		has, err := sess.Where("org_id = ? AND uid = ? AND is_folder = ? AND deleted IS NULL", s.orgID, dashboard.UID, s.db.GetDialect().BooleanValue(false)).Get(&existing)
+		if err != nil {
+			return err
+		}
+
+		dash := &dashboards.Dashboard{
+			UID:       dashboard.UID,
+			Slug:      slugify.Slugify(dashboard.Title),
+			OrgID:     s.orgID,
+			Version:   1,
+			Created:   now,
+			Updated:   now,
+			FolderID:  folderID, // nolint:staticcheck
+			FolderUID: dashboard.FolderUID,
+			Title:     dashboard.Title,
+			Data:      simplejson.NewFromAny(dashboard.Model),
+		}
+		if has {
+			dash.ID = existing.ID
+			dash.Version = existing.Version + 1
+			dash.Created = existing.Created
+			dash.CreatedBy = existing.CreatedBy
+			dash.Data.Set("id", dash.ID)
+			dash.Data.Set("version", dash.Version)
+			if _, err := sess.MustCols("folder_id", "folder_uid").Nullable("folder_uid").ID(dash.ID).Update(dash); err != nil {
+				return err
+			}
+		} else {
+			dash.Data.Set("version", dash.Version)
+			if _, err := sess.Nullable("folder_uid").Insert(dash); err != nil {
+				return err
+			}
+		}
+
+		if _, err := sess.Insert(&dashver.DashboardVersion{
+			DashboardID:   dash.ID,
+			ParentVersion: existing.Version,
+			Version:       dash.Version,
+			Created:       now,
+			Message:       importMessage,
+			Data:          dash.Data,
+		}); err != nil {
+			return err
+		}
+
+		if _, err := sess.Exec("DELETE FROM dashboard_tag WHERE dashboard_uid = ? AND org_id = ?", dash.UID, s.orgID); err != nil {
+			return err
+		}
+		for _, tag := range dash.GetTags() {
+			if _, err := sess.Exec("INSERT INTO dashboard_tag(dashboard_id, dashboard_uid, org_id, term) VALUES(?, ?, ?, ?)", dash.ID, dash.UID, s.orgID, tag); err != nil {
+				return err
+			}
+		}
+
+		return s.connectLibraryPanels(sess, dash, now)
+	})
+}
+
+// connectLibraryPanels replaces the connections of the dashboard to the library panels its panels use, which
+// the HTTP API makes when dashboards are saved.
+func (s *dbStore) connectLibraryPanels(sess *db.Session, dash *dashboards.Dashboard, now time.Time) error {
+	if _, err := sess.Exec("DELETE FROM "+model.LibraryElementConnectionTableName+" WHERE kind = ? AND connection_id = ?", libraryPanelKind, dash.ID); err != nil {
+		return err
+	}
+	connected := map[int64]bool{}
+	for _, uid := range libraryPanelRefs(dash.Data.MustMap()) {
+		var element model.LibraryElement
+		has, err := sess.Where("org_id = ? AND uid = ?", s.orgID, uid).Get(&element)
+		if err != nil {
+			return err
+		}
+		if !has || connected[element.ID] {
+			continue
+		}
+		connected[element.ID] = true
+		if _, err := sess.Insert(&model.LibraryElementConnection{
+			ElementID:    element.ID,
+			Kind:         libraryPanelKind,
+			ConnectionID: dash.ID,
+			Created:      now,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package dashboards
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/infra/slugify"
+)
+
+const (
+	foldersFile       = "folders.json"
+	datasourcesFile   = "datasources.json"
+	dashboardsDir     = "dashboards"
+	libraryPanelsDir  = "library-panels"
+	exportedFilesMode = 0o640
+	exportedDirsMode  = 0o750
+)
+
+// export writes the folders, dashboards and library panels of the store to dir:
+//
+//	folders.json                                the folders, with their parent folders
+//	dashboards/<folder path>/<slug>-<uid>.json  the dashboards, in the directories of their folders
+//	library-panels/<uid>.json                   the library panels
+//	datasources.json                            the data sources the dashboards and library panels refer to
+func export(ctx context.Context, s store, dir string) error {
+	folders, err := s.Folders(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list the folders: %w", err)
+	}
+	folders = folderPaths(folders)
+	paths := make(map[string]string, len(folders))
+	for _, f := range folders {
+		paths[f.UID] = f.Path
+	}
+
+	dashboards, err := s.Dashboards(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list the dashboards: %w", err)
+	}
+	panels, err := s.LibraryPanels(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list the library panels: %w", err)
+	}
+
+	refs := map[string]struct{}{}
+	for _, d := range dashboards {
+		folderPath, ok := paths[d.FolderUID]
+		if !ok && d.FolderUID != "" {
+			logger.Warnf("Dashboard %s is in folder %s which can't be read, exporting it in the root folder", d.UID, d.FolderUID)
+		}
+		delete(d.Model, "id")
+		datasourceRefs(d.Model, refs)
+		name := fmt.Sprintf("%s-%s.json", slugify.Slugify(d.Title), d.UID)
+		if err := writeJSON(filepath.Join(dir, dashboardsDir, filepath.FromSlash(folderPath), name), d.Model); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range panels {
+		if _, ok := paths[p.FolderUID]; !ok && p.FolderUID != "" {
+			logger.Warnf("Library panel %s is in folder %s which can't be read, exporting it in the root folder", p.UID, p.FolderUID)
+			p.FolderUID = ""
+		}
+		var model any
+		if err := json.Unmarshal(p.Model, &model); err != nil {
+			return fmt.Errorf("failed to decode the model of library panel %s: %w", p.UID, err)
+		}
+		datasourceRefs(model, refs)
+		if err := writeJSON(filepath.Join(dir, libraryPanelsDir, p.UID+".json"), p); err != nil {
+			return err
+		}
+	}
+
+	datasources, err := s.Datasources(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list the data sources: %w", err)
+	}
+	referenced := []Datasource{}
+	for _, ds := range datasources {
+		if _, ok := refs[ds.UID]; ok {
+			referenced = append(referenced, ds)
+		}
+	}
+	sort.Slice(referenced, func(i, j int) bool { return referenced[i].UID < referenced[j].UID })
+	if err := writeJSON(filepath.Join(dir, datasourcesFile), referenced); err != nil {
+		return err
+	}
+
+	if err := writeJSON(filepath.Join(dir, foldersFile), folders); err != nil {
+		return err
+	}
+	logger.Infof("Exported %d folders, %d dashboards and %d library panels to %s", len(folders), len(dashboards), len(panels), dir)
+	return nil
+}
+
+// folderPaths sets the paths of the folders from the slugs of their titles and of their parent folders
+// titles, and returns them sorted by path. The folders whose parent can't be read are exported as root folders.
+func folderPaths(folders []Folder) []Folder {
+	// the folders are sorted so that the folders with the same slugs get the same paths in every export
+	sort.Slice(folders, func(i, j int) bool { return folders[i].UID < folders[j].UID })
+	byUID := make(map[string]*Folder, len(folders))
+	for i := range folders {
+		byUID[folders[i].UID] = &folders[i]
+	}
+
+	used := map[string]bool{}
+	var setPath func(f *Folder, depth int) string
+	setPath = func(f *Folder, depth int) string {
+		if f.Path != "" {
+			return f.Path
+		}
+		parentPath := ""
+		if parent, ok := byUID[f.ParentUID]; ok && depth < len(folders) {
+			parentPath = setPath(parent, depth+1)
+		} else {
+			f.ParentUID = ""
+		}
+		p := path.Join(parentPath, slugify.Slugify(f.Title))
+		if used[p] {
+			p += "-" + f.UID
+		}
+		used[p] = true
+		f.Path = p
+		return p
+	}
+	for i := range folders {
+		setPath(&folders[i], 0)
+	}
+
+	sort.Slice(folders, func(i, j int) bool { return folders[i].Path < folders[j].Path })
+	return folders
+}
+
+func writeJSON(file string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), exportedDirsMode); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, data, exportedFilesMode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package dashboards
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	searchPageSize       = 1000
+	libraryPanelPageSize = 100
+	libraryPanelKind     = 1
+)
+
+// httpStore reads and writes the dashboards through the HTTP API of a Grafana server, with the permissions of
+// the service account token or of the user of the basic authentication.
+type httpStore struct {
+	url      string
+	token    string
+	user     string
+	password string
+	orgID    int64
+	client   *http.Client
+}
+
+func newHTTPStore(grafanaURL, token, basicAuth string, orgID int64) (*httpStore, error) {
+	u, err := url.Parse(grafanaURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid Grafana URL %q", grafanaURL)
+	}
+	s := &httpStore{
+		url:    strings.TrimSuffix(grafanaURL, "/"),
+		token:  token,
+		orgID:  orgID,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+	if basicAuth != "" {
+		user, password, ok := strings.Cut(basicAuth, ":")
+		if !ok {
+			return nil, fmt.Errorf("the basic authentication should be formatted as <user>:<password>")
+		}
+		s.user, s.password = user, password
+	}
+	return s, nil
+}
+
+type httpError struct {
+	statusCode int
+	message    string
+}
+
+func (e *httpError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.statusCode, http.StatusText(e.statusCode), e.message)
+}
+
+func isNotFound(err error) bool {
+	httpErr, ok := err.(*httpError)
+	return ok && httpErr.statusCode == http.StatusNotFound
+}
+
+// do sends the request with the body encoded as JSON, and decodes the JSON response to out when it's not nil.
+func (s *httpStore) do(ctx context.Context, method, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.url+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	} else if s.user != "" {
+		req.SetBasicAuth(s.user, s.password)
+	}
+	if s.orgID != 0 {
+		req.Header.Set("X-Grafana-Org-Id", strconv.FormatInt(s.orgID, 10))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		return &httpError{statusCode: resp.StatusCode, message: apiErr.Message}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode the response of %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+type searchHit struct {
+	UID       string `json:"uid"`
+	Title     string `json:"title"`
+	FolderUID string `json:"folderUid"`
+}
+
+func (s *httpStore) search(ctx context.Context, hitType string) ([]searchHit, error) {
+	var hits []searchHit
+	for page := 1; ; page++ {
+		var result []searchHit
+		path := fmt.Sprintf("/api/search?type=%s&limit=%d&page=%d", hitType, searchPageSize, page)
+		if err := s.do(ctx, http.MethodGet, path, nil, &result); err != nil {
+			return nil, err
+		}
+		hits = append(hits, result...)
+		if len(result) < searchPageSize {
+			return hits, nil
+		}
+	}
+}
+
+func (s *httpStore) Folders(ctx context.Context) ([]Folder, error) {
+	hits, err := s.search(ctx, "dash-folder")
+	if err != nil {
+		return nil, err
+	}
+	folders := make([]Folder, 0, len(hits))
+	for _, hit := range hits {
+		folders = append(folders, Folder{UID: hit.UID, Title: hit.Title, ParentUID: hit.FolderUID})
+	}
+	return folders, nil
+}
+
+func (s *httpStore) Dashboards(ctx context.Context) ([]Dashboard, error) {
+	hits, err := s.search(ctx, "dash-db")
+	if err != nil {
+		return nil, err
+	}
+	dashboards := make([]Dashboard, 0, len(hits))
+	for _, hit := range hits {
+		var result struct {
+			Dashboard map[string]any `json:"dashboard"`
+			Meta      struct {
+				FolderUID string `json:"folderUid"`
+			} `json:"meta"`
+		}
+		if err := s.do(ctx, http.MethodGet, "/api/dashboards/uid/"+url.PathEscape(hit.UID), nil, &result); err != nil {
+			return nil, fmt.Errorf("failed to get dashboard %s: %w", hit.UID, err)
+		}
+		dashboards = append(dashboards, Dashboard{
+			UID:       hit.UID,
+			Title:     hit.Title,
+			FolderUID: result.Meta.FolderUID,
+			Model:     result.Dashboard,
+		})
+	}
+	return dashboards, nil
+}
+
+func (s *httpStore) LibraryPanels(ctx context.Context) ([]LibraryPanel, error) {
+	var panels []LibraryPanel
+	for page := 1; ; page++ {
+		var result struct {
+			Result struct {
+				TotalCount int64          `json:"totalCount"`
+				Elements   []LibraryPanel `json:"elements"`
+			} `json:"result"`
+		}
+		path := fmt.Sprintf("/api/library-elements?kind=%d&perPage=%d&page=%d", libraryPanelKind, libraryPanelPageSize, page)
+		if err := s.do(ctx, http.MethodGet, path, nil, &result); err != nil {
+			return nil, err
+		}
+		panels = append(panels, result.Result.Elements...)
+		if len(result.Result.Elements) == 0 || int64(len(panels)) >= result.Result.TotalCount {
+			return panels, nil
+		}
+	}
+}
+
+func (s *httpStore) Datasources(ctx context.Context) ([]Datasource, error) {
+	var datasources []Datasource
+	if err := s.do(ctx, http.MethodGet, "/api/datasources", nil, &datasources); err != nil {
+		return nil, err
+	}
+	return datasources, nil
+}
+
+func (s *httpStore) SaveFolder(ctx context.Context, folder Folder) error {
+	var existing struct {
+		Title     string `json:"title"`
+		ParentUID string `json:"parentUid"`
+	}
+	err := s.do(ctx, http.MethodGet, "/api/folders/"+url.PathEscape(folder.UID), nil, &existing)
+	if isNotFound(err) {
+		return s.do(ctx, http.MethodPost, "/api/folders", map[string]any{
+			"uid":       folder.UID,
+			"title":     folder.Title,
+			"parentUid": folder.ParentUID,
+		}, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	if existing.Title != folder.Title {
+		err := s.do(ctx, http.MethodPut, "/api/folders/"+url.PathEscape(folder.UID), map[string]any{
+			"title":     folder.Title,
+			"overwrite": true,
+		}, nil)
+		if err != nil {
+			return err
+		}
+	}
+	if existing.ParentUID != folder.ParentUID {
+		return s.do(ctx, http.MethodPost, "/api/folders/"+url.PathEscape(folder.UID)+"/move", map[string]any{
+			"parentUid": folder.ParentUID,
+		}, nil)
+	}
+	return nil
+}
+
+func (s *httpStore) SaveLibraryPanel(ctx context.Context, panel LibraryPanel) error {
+	var existing struct {
+		Result struct {
+			Version int64 `json:"version"`
+		} `json:"result"`
+	}
+	err := s.do(ctx, http.MethodGet, "/api/library-elements/"+url.PathEscape(panel.UID), nil, &existing)
+	if isNotFound(err) {
+		return s.do(ctx, http.MethodPost, "/api/library-elements", map[string]any{
+			"uid":       panel.UID,
+			"folderUid": panel.FolderUID,
+			"name":      panel.Name,
+			"model":     panel.Model,
+			"kind":      libraryPanelKind,
+		}, nil)
+	}
+	if err != nil {
+		return err
+	}
+	return s.do(ctx, http.MethodPatch, "/api/library-elements/"+url.PathEscape(panel.UID), map[string]any{
+		"folderUid": panel.FolderUID,
+		"name":      panel.Name,
+		"model":     panel.Model,
+		"kind":      libraryPanelKind,
+		"version":   existing.Result.Version,
+	}, nil)
+}
+
+func (s *httpStore) SaveDashboard(ctx context.Context, dashboard Dashboard) error {
+	return s.do(ctx, http.MethodPost, "/api/dashboards/db", map[string]any{
+		"dashboard": dashboard.Model,
+		"folderUid": dashboard.FolderUID,
+		"overwrite": true,
+		"message":   "Imported with grafana cli",
+	}, nil)
+}
@@ -0,0 +1,186 @@
+package dashboards
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+)
+
+// importDir saves the folders, library panels and dashboards exported to dir in the store. The references to
+// the exported data sources are mapped to the data sources of the store with the same UIDs, or else with the
+// same names and types, unless datasourceMap maps their UIDs to other UIDs.
+func importDir(ctx context.Context, s store, dir string, datasourceMap map[string]string) error {
+	var folders []Folder
+	if err := readJSON(filepath.Join(dir, foldersFile), &folders); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	// the parent folders are saved before their subfolders
+	sort.SliceStable(folders, func(i, j int) bool {
+		return strings.Count(folders[i].Path, "/") < strings.Count(folders[j].Path, "/")
+	})
+	folderUIDs := map[string]string{"": ""}
+	for _, f := range folders {
+		if err := s.SaveFolder(ctx, f); err != nil {
+			return fmt.Errorf("failed to save folder %s: %w", f.UID, err)
+		}
+		folderUIDs[f.Path] = f.UID
+	}
+
+	mapping, err := datasourceMapping(ctx, s, dir, datasourceMap)
+	if err != nil {
+		return err
+	}
+	unmapped := map[string]struct{}{}
+
+	panelFiles, err := filepath.Glob(filepath.Join(dir, libraryPanelsDir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range panelFiles {
+		var panel LibraryPanel
+		if err := readJSON(file, &panel); err != nil {
+			return err
+		}
+		var model any
+		if err := json.Unmarshal(panel.Model, &model); err != nil {
+			return fmt.Errorf("failed to decode the model of library panel %s: %w", panel.UID, err)
+		}
+		mapDatasourceRefs(model, mapping, unmapped)
+		if panel.Model, err = json.Marshal(model); err != nil {
+			return err
+		}
+		if err := s.SaveLibraryPanel(ctx, panel); err != nil {
+			return fmt.Errorf("failed to save library panel %s: %w", panel.UID, err)
+		}
+	}
+
+	imported := 0
+	root := filepath.Join(dir, dashboardsDir)
+	err = filepath.WalkDir(root, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && file == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() || filepath.Ext(file) != ".json" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, filepath.Dir(file))
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			rel = ""
+		}
+		folderUID, ok := folderUIDs[rel]
+		if !ok {
+			return fmt.Errorf("dashboard %s is in directory %s which isn't a folder of %s", file, rel, foldersFile)
+		}
+
+		var model map[string]any
+		if err := readJSON(file, &model); err != nil {
+			return err
+		}
+		mapDatasourceRefs(model, mapping, unmapped)
+		delete(model, "id")
+		dashboard := Dashboard{FolderUID: folderUID, Model: model}
+		dashboard.UID, _ = model["uid"].(string)
+		dashboard.Title, _ = model["title"].(string)
+		if dashboard.UID == "" || dashboard.Title == "" {
+			return fmt.Errorf("dashboard %s has no uid or title", file)
+		}
+		if err := s.SaveDashboard(ctx, dashboard); err != nil {
+			return fmt.Errorf("failed to save dashboard %s: %w", dashboard.UID, err)
+		}
+		imported++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, uid := range sortedKeys(unmapped) {
+		logger.Warnf("Data source %s doesn't exist, the references to it were imported as they are", uid)
+	}
+	logger.Infof("Imported %d folders, %d dashboards and %d library panels from %s", len(folders), imported, len(panelFiles), dir)
+	return nil
+}
+
+// datasourceMapping maps the UIDs of the data sources referenced by the export to the UIDs of the data sources
+// of the store.
+func datasourceMapping(ctx context.Context, s store, dir string, datasourceMap map[string]string) (map[string]string, error) {
+	var exported []Datasource
+	if err := readJSON(filepath.Join(dir, datasourcesFile), &exported); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	datasources, err := s.Datasources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the data sources: %w", err)
+	}
+
+	mapping := map[string]string{}
+	byName := map[string]Datasource{}
+	for _, ds := range datasources {
+		mapping[ds.UID] = ds.UID
+		byName[ds.Name] = ds
+	}
+	for _, ds := range exported {
+		if _, ok := mapping[ds.UID]; ok {
+			continue
+		}
+		if match, ok := byName[ds.Name]; ok && match.Type == ds.Type {
+			logger.Infof("Mapping data source %s to %s with the same name %s", ds.UID, match.UID, ds.Name)
+			mapping[ds.UID] = match.UID
+		}
+	}
+	for from, to := range datasourceMap {
+		mapping[from] = to
+	}
+	return mapping, nil
+}
+
+// readDatasourceMap reads a JSON file mapping the UIDs of the exported data sources to the UIDs of the data
+// sources of the Grafana instance the dashboards are imported to.
+func readDatasourceMap(file string) (map[string]string, error) {
+	mapping := map[string]string{}
+	if file == "" {
+		return mapping, nil
+	}
+	if err := readJSON(file, &mapping); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+func readJSON(file string, v any) error {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning since the files are in the directory given by the user.
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", file, err)
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,80 @@
+package dashboards
+
+import "strings"
+
+// isBuiltinRef returns whether the data source UID is empty, a variable or one of the built-in data sources,
+// like -- Mixed --, which exist in every Grafana instance.
+func isBuiltinRef(uid string) bool {
+	return uid == "" || uid == "grafana" || strings.HasPrefix(uid, "$") || strings.HasPrefix(uid, "-- ")
+}
+
+// datasourceRefs adds the UIDs of the data sources referenced in the model to refs, from the datasource
+// properties of the panels, queries, annotations and variables.
+func datasourceRefs(v any, refs map[string]struct{}) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if ref, ok := value.(map[string]any); ok && key == "datasource" {
+				if uid, ok := ref["uid"].(string); ok && !isBuiltinRef(uid) {
+					refs[uid] = struct{}{}
+				}
+			}
+			datasourceRefs(value, refs)
+		}
+	case []any:
+		for _, value := range v {
+			datasourceRefs(value, refs)
+		}
+	}
+}
+
+// mapDatasourceRefs replaces the UIDs of the data sources referenced in the model by the UIDs they're mapped
+// to, and adds the referenced UIDs without mapping to unmapped.
+func mapDatasourceRefs(v any, mapping map[string]string, unmapped map[string]struct{}) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if ref, ok := value.(map[string]any); ok && key == "datasource" {
+				if uid, ok := ref["uid"].(string); ok && !isBuiltinRef(uid) {
+					if mapped, ok := mapping[uid]; ok {
+						ref["uid"] = mapped
+					} else {
+						unmapped[uid] = struct{}{}
+					}
+				}
+			}
+			mapDatasourceRefs(value, mapping, unmapped)
+		}
+	case []any:
+		for _, value := range v {
+			mapDatasourceRefs(value, mapping, unmapped)
+		}
+	}
+}
+
+// libraryPanelRefs returns the UIDs of the library panels the panels of the dashboard model are connected to.
+func libraryPanelRefs(model map[string]any) []string {
+	var uids []string
+	var walk func(panels any)
+	walk = func(panels any) {
+		list, ok := panels.([]any)
+		if !ok {
+			return
+		}
+		for _, p := range list {
+			panel, ok := p.(map[string]any)
+			if !ok {
+				continue
+			}
+			if ref, ok := panel["libraryPanel"].(map[string]any); ok {
+				if uid, ok := ref["uid"].(string); ok && uid != "" {
+					uids = append(uids, uid)
+				}
+			}
+			// the panels of collapsed rows are nested in the rows
+			walk(panel["panels"])
+		}
+	}
+	walk(model["panels"])
+	return uids
+}
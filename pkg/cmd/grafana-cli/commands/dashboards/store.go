@@ -0,0 +1,54 @@
+package dashboards
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Folder is a folder of the exported dashboards. ParentUID is empty for the root folders.
+type Folder struct {
+	UID       string `json:"uid"`
+	Title     string `json:"title"`
+	ParentUID string `json:"parentUid,omitempty"`
+	// Path is the directory of the folder dashboards, relative to the dashboards directory of the export
+	Path string `json:"path"`
+}
+
+// Dashboard is a dashboard and the folder it's in, empty for the dashboards of the root folder.
+type Dashboard struct {
+	UID       string
+	Title     string
+	FolderUID string
+	Model     map[string]any
+}
+
+// LibraryPanel is a library panel and the folder it's in.
+type LibraryPanel struct {
+	UID       string          `json:"uid"`
+	Name      string          `json:"name"`
+	FolderUID string          `json:"folderUid,omitempty"`
+	Model     json.RawMessage `json:"model"`
+}
+
+// Datasource identifies a data source the exported dashboards and library panels refer to.
+type Datasource struct {
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// store reads and writes the dashboards of an organization, through the HTTP API of a Grafana server or
+// directly in its database.
+type store interface {
+	Folders(ctx context.Context) ([]Folder, error)
+	Dashboards(ctx context.Context) ([]Dashboard, error)
+	LibraryPanels(ctx context.Context) ([]LibraryPanel, error)
+	Datasources(ctx context.Context) ([]Datasource, error)
+
+	// SaveFolder creates or updates the folder. The parent folder is saved first.
+	SaveFolder(ctx context.Context, folder Folder) error
+	// SaveLibraryPanel creates or updates the library panel, overwriting its current version.
+	SaveLibraryPanel(ctx context.Context, panel LibraryPanel) error
+	// SaveDashboard creates or updates the dashboard, overwriting its current version.
+	SaveDashboard(ctx context.Context, dashboard Dashboard) error
+}
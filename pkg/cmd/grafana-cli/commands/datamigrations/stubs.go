@@ -6,6 +6,8 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/provisioning"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
+	"github.com/grafana/grafana/pkg/services/provisioning/plan"
+	"github.com/grafana/grafana/pkg/services/provisioning/status"
 )
 
 var (
@@ -59,6 +61,36 @@ func (s *stubProvisioning) ProvisionPlugins(ctx context.Context) error {
 	panic("unimplemented")
 }
 
+// PlanAlerting implements provisioning.ProvisioningService.
+func (s *stubProvisioning) PlanAlerting(ctx context.Context) (*plan.Plan, error) {
+	panic("unimplemented")
+}
+
+// PlanDashboards implements provisioning.ProvisioningService.
+func (s *stubProvisioning) PlanDashboards(ctx context.Context) (*plan.Plan, error) {
+	panic("unimplemented")
+}
+
+// PlanDatasources implements provisioning.ProvisioningService.
+func (s *stubProvisioning) PlanDatasources(ctx context.Context) (*plan.Plan, error) {
+	panic("unimplemented")
+}
+
+// PlanPlugins implements provisioning.ProvisioningService.
+func (s *stubProvisioning) PlanPlugins(ctx context.Context) (*plan.Plan, error) {
+	panic("unimplemented")
+}
+
+// Plan implements provisioning.ProvisioningService.
+func (s *stubProvisioning) Plan(ctx context.Context) ([]*plan.Plan, error) {
+	panic("unimplemented")
+}
+
+// GetStatus implements provisioning.ProvisioningService.
+func (s *stubProvisioning) GetStatus(ctx context.Context) ([]status.Status, error) {
+	panic("unimplemented")
+}
+
 // Run implements provisioning.ProvisioningService.
 func (s *stubProvisioning) Run(ctx context.Context) error {
 	panic("unimplemented")
@@ -0,0 +1,111 @@
+package usagereport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/infra/usagestats"
+)
+
+const exportPath = "/api/admin/usage-report/export"
+
+// ExportCommand exports the anonymous usage report of a Grafana server, with the metrics of the registered
+// collectors, to the file given by the --file flag or to the standard output. With the --report-only flag, only the
+// report is exported, as Grafana sends it.
+func ExportCommand(c utils.CommandLine) error {
+	if c.String("url") == "" {
+		return errors.New("the URL of the Grafana server is missing, set it with --url")
+	}
+
+	export, err := fetchExport(context.Background(), c.String("url"), c.String("token"), c.String("basic-auth"))
+	if err != nil {
+		return err
+	}
+	data, err := marshalExport(export, c.Bool("report-only"))
+	if err != nil {
+		return err
+	}
+
+	file := c.String("file")
+	if file == "" {
+		_, err := os.Stdout.Write(append(data, '\n'))
+		return err
+	}
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		return err
+	}
+	logger.Infof("Exported the usage report to %s %s", file, color.GreenString("✔"))
+	if !export.ReportingEnabled {
+		logger.Infof("The reporting is disabled, the report isn't sent to Grafana Labs")
+	}
+	return nil
+}
+
+// marshalExport encodes the report like Grafana does when it sends it, so that the exported report is the same.
+func marshalExport(export *usagestats.Export, reportOnly bool) ([]byte, error) {
+	if reportOnly {
+		return json.MarshalIndent(export.Report, "", " ")
+	}
+	return json.MarshalIndent(export, "", "  ")
+}
+
+func fetchExport(ctx context.Context, grafanaURL, token, basicAuth string) (*usagestats.Export, error) {
+	u, err := url.Parse(grafanaURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid Grafana URL %q", grafanaURL)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(grafanaURL, "/")+exportPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if basicAuth != "" {
+		user, password, ok := strings.Cut(basicAuth, ":")
+		if !ok {
+			return nil, errors.New("the basic authentication should be formatted as <user>:<password>")
+		}
+		req.SetBasicAuth(user, password)
+	}
+
+	// collecting the metrics can take a while on large instances
+	client := &http.Client{Timeout: 5 * time.Minute}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		return nil, fmt.Errorf("failed to export the usage report: %d %s: %s", resp.StatusCode, http.StatusText(resp.StatusCode), apiErr.Message)
+	}
+
+	var export usagestats.Export
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("failed to decode the usage report: %w", err)
+	}
+	return &export, nil
+}
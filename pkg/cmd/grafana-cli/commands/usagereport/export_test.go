@@ -0,0 +1,71 @@
+package usagereport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/usagestats"
+)
+
+func TestFetchExport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != exportPath {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		if user, password, ok := r.BasicAuth(); !ok || user != "admin" || password != "secret" {
+			http.Error(w, `{"message":"invalid username or password"}`, http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{
+			"report": {"version": "12_0_0", "metrics": {"stats.dashboards.count": 2}, "usageStatsId": "id"},
+			"collectors": {"internal": {"teams.count": 3}},
+			"reportingEnabled": false
+		}`))
+	}))
+	t.Cleanup(server.Close)
+
+	t.Run("should return the export", func(t *testing.T) {
+		export, err := fetchExport(context.Background(), server.URL+"/", "", "admin:secret")
+		require.NoError(t, err)
+		require.Equal(t, "12_0_0", export.Report.Version)
+		require.EqualValues(t, 3, export.Collectors["internal"]["teams.count"])
+		require.False(t, export.ReportingEnabled)
+	})
+
+	t.Run("should return the error of the server", func(t *testing.T) {
+		_, err := fetchExport(context.Background(), server.URL, "", "admin:wrong")
+		require.ErrorContains(t, err, "401 Unauthorized: invalid username or password")
+	})
+
+	t.Run("should fail with an invalid URL", func(t *testing.T) {
+		_, err := fetchExport(context.Background(), "localhost:3000", "", "")
+		require.ErrorContains(t, err, "invalid Grafana URL")
+	})
+}
+
+func TestMarshalExport(t *testing.T) {
+	export := &usagestats.Export{
+		Report:     usagestats.Report{Version: "12_0_0", Metrics: map[string]any{"stats.dashboards.count": 2}},
+		Collectors: map[string]map[string]any{"internal": {"teams.count": 3}},
+	}
+
+	t.Run("should only encode the report as Grafana sends it", func(t *testing.T) {
+		data, err := marshalExport(export, true)
+		require.NoError(t, err)
+		expected, err := json.MarshalIndent(export.Report, "", " ")
+		require.NoError(t, err)
+		require.Equal(t, string(expected), string(data))
+	})
+
+	t.Run("should encode the collectors", func(t *testing.T) {
+		data, err := marshalExport(export, false)
+		require.NoError(t, err)
+		require.Contains(t, string(data), `"teams.count": 3`)
+	})
+}
@@ -0,0 +1,109 @@
+package usermanagement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/server"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+type inactiveUser struct {
+	ID         int64     `xorm:"id"`
+	Login      string    `xorm:"login"`
+	Email      string    `xorm:"email"`
+	LastSeenAt time.Time `xorm:"last_seen_at"`
+}
+
+// DeactivateUsers disables the users who haven't logged in since the date given by the --last-seen-before flag,
+// and revokes their sessions. The Grafana server administrators are only disabled with the --include-admins
+// flag, and the users are only listed with the --dry-run flag.
+func DeactivateUsers(c utils.CommandLine, runner server.Runner) error {
+	before, err := parseDate(c.String("last-seen-before"))
+	if err != nil {
+		return err
+	}
+
+	m := newManager(runner)
+	ctx := context.Background()
+	users, err := m.inactiveUsers(ctx, before, c.Bool("include-admins"))
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		logger.Infof("\t Login: %s Email: %s ID: %d Last seen: %s", u.Login, u.Email, u.ID, u.LastSeenAt.Format(time.DateOnly))
+	}
+	logger.Infof("\n")
+
+	if c.Bool("dry-run") {
+		logger.Infof("%d users would be deactivated", len(users))
+		return nil
+	}
+	if err := m.deactivateUsers(ctx, users); err != nil {
+		return err
+	}
+	logger.Infof("%d users deactivated %s", len(users), color.GreenString("✔"))
+	return nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, errors.New("the date is missing, set it with --last-seen-before")
+	}
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		return date, nil
+	}
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, the date should be formatted as 2006-01-02 or 2006-01-02T15:04:05Z07:00", value)
+	}
+	return date, nil
+}
+
+// inactiveUsers returns the enabled users who haven't been seen since before. The users created after before
+// haven't logged in yet, but aren't inactive.
+func (m *manager) inactiveUsers(ctx context.Context, before time.Time, includeAdmins bool) ([]inactiveUser, error) {
+	var users []inactiveUser
+	err := m.db.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Table("user").
+			Where("is_service_account = ? AND is_disabled = ?", m.db.GetDialect().BooleanValue(false), m.db.GetDialect().BooleanValue(false)).
+			And("last_seen_at < ? AND created < ?", before, before)
+		if !includeAdmins {
+			sess.And("is_admin = ?", m.db.GetDialect().BooleanValue(false))
+		}
+		return sess.OrderBy("login").Find(&users)
+	})
+	return users, err
+}
+
+// deactivateUsers disables the users and deletes their sessions, like the user administration API.
+func (m *manager) deactivateUsers(ctx context.Context, users []inactiveUser) error {
+	if len(users) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(users))
+	args := make([]any, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+		args = append(args, u.ID)
+	}
+
+	return m.db.InTransaction(ctx, func(ctx context.Context) error {
+		if err := m.userService.BatchDisableUsers(ctx, &user.BatchDisableUsersCommand{UserIDs: ids, IsDisabled: true}); err != nil {
+			return err
+		}
+		return m.db.WithDbSession(ctx, func(sess *db.Session) error {
+			placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+			_, err := sess.Exec(append([]any{"DELETE FROM user_auth_token WHERE user_id IN (" + placeholders + ")"}, args...)...)
+			return err
+		})
+	})
+}
@@ -0,0 +1,299 @@
+package usermanagement
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/server"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamimpl"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// importedUser is a user of the import file, with the organizations it's assigned to.
+type importedUser struct {
+	Login          string           `json:"login"`
+	Email          string           `json:"email"`
+	Name           string           `json:"name"`
+	Password       string           `json:"password"`
+	IsGrafanaAdmin bool             `json:"isGrafanaAdmin"`
+	Orgs           []userAssignment `json:"orgs"`
+}
+
+// userAssignment is the role of an imported user in an organization, and the teams of the organization it's
+// a member of.
+type userAssignment struct {
+	// Org is the name of the organization, the default organization when it's empty
+	Org   string   `json:"org"`
+	Role  string   `json:"role"`
+	Teams []string `json:"teams"`
+}
+
+// csvColumns are the columns of the CSV files, which have one row per user and organization.
+var csvColumns = []string{"login", "email", "name", "password", "grafana_admin", "org", "role", "teams"}
+
+// ImportUsers creates the users of the CSV or JSON file given as argument, and assigns them to the organizations
+// and teams of the file with their roles. The users who already exist are only assigned.
+func ImportUsers(c utils.CommandLine, runner server.Runner) error {
+	file := c.Args().First()
+	if file == "" {
+		return errors.New("the CSV or JSON file of the users to import is missing")
+	}
+	users, err := readUsers(file)
+	if err != nil {
+		return err
+	}
+
+	m := newManager(runner)
+	ctx := context.Background()
+	created, updated, failed := 0, 0, 0
+	for _, u := range users {
+		isNew, err := m.importUser(ctx, u, int64(c.Int("org-id")))
+		switch {
+		case err != nil:
+			failed++
+			logger.Errorf("Failed to import user %s: %s %s", util.StringsFallback2(u.Login, u.Email), err, color.RedString("✘"))
+		case isNew:
+			created++
+		default:
+			updated++
+		}
+	}
+
+	logger.Infof("\n")
+	logger.Infof("%d users created, %d existing users assigned, %d failed", created, updated, failed)
+	if failed > 0 {
+		return fmt.Errorf("failed to import %d users", failed)
+	}
+	return nil
+}
+
+// readUsers reads the users of a JSON file, with an array of users, or of a CSV file with a header naming its
+// columns. The rows of a CSV file with the same login are the assignments of the same user.
+func readUsers(file string) ([]importedUser, error) {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning since the file is given by the user running the command.
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		var users []importedUser
+		if err := json.NewDecoder(f).Decode(&users); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", file, err)
+		}
+		return users, nil
+	case ".csv":
+		return readCSVUsers(f)
+	default:
+		return nil, fmt.Errorf("unsupported file %s, the users should be in a .csv or .json file", file)
+	}
+}
+
+func readCSVUsers(r io.Reader) ([]importedUser, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CSV header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(csvColumns, name) {
+			return nil, fmt.Errorf("unknown CSV column %q, the columns can be %s", name, strings.Join(csvColumns, ", "))
+		}
+		columns[name] = i
+	}
+	value := func(record []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var users []importedUser
+	byLogin := map[string]int{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return users, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		u := importedUser{
+			Login:    value(record, "login"),
+			Email:    value(record, "email"),
+			Name:     value(record, "name"),
+			Password: value(record, "password"),
+		}
+		if admin := value(record, "grafana_admin"); admin != "" {
+			if u.IsGrafanaAdmin, err = strconv.ParseBool(admin); err != nil {
+				return nil, fmt.Errorf("invalid grafana_admin value %q of line %d", admin, line)
+			}
+		}
+		assignment := userAssignment{Org: value(record, "org"), Role: value(record, "role")}
+		for _, t := range strings.Split(value(record, "teams"), ";") {
+			if t = strings.TrimSpace(t); t != "" {
+				assignment.Teams = append(assignment.Teams, t)
+			}
+		}
+		if assignment.Org != "" || assignment.Role != "" || len(assignment.Teams) > 0 {
+			u.Orgs = append(u.Orgs, assignment)
+		}
+
+		key := strings.ToLower(util.StringsFallback2(u.Login, u.Email))
+		if i, ok := byLogin[key]; ok {
+			users[i].Orgs = append(users[i].Orgs, u.Orgs...)
+			continue
+		}
+		byLogin[key] = len(users)
+		users = append(users, u)
+	}
+}
+
+// resolvedAssignment is an assignment with the IDs of its organization and teams.
+type resolvedAssignment struct {
+	orgID   int64
+	role    org.RoleType
+	teamIDs []int64
+}
+
+// importUser creates the user when it doesn't exist, and assigns it to its organizations and teams. The users
+// without organizations are assigned to the default organization as viewers.
+func (m *manager) importUser(ctx context.Context, u importedUser, defaultOrgID int64) (bool, error) {
+	login := util.StringsFallback2(u.Login, u.Email)
+	if login == "" {
+		return false, errors.New("the login and the email are missing")
+	}
+
+	// the assignments are checked before the user is created, so that invalid users aren't partially imported
+	assignments := u.Orgs
+	if len(assignments) == 0 {
+		assignments = []userAssignment{{}}
+	}
+	resolved := make([]resolvedAssignment, 0, len(assignments))
+	for _, a := range assignments {
+		r, err := m.resolveAssignment(ctx, a, defaultOrgID)
+		if err != nil {
+			return false, err
+		}
+		resolved = append(resolved, r)
+	}
+
+	usr, err := m.userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: login})
+	isNew := errors.Is(err, user.ErrUserNotFound)
+	if err != nil && !isNew {
+		return false, err
+	}
+	if isNew {
+		password := u.Password
+		if password == "" {
+			if password, err = util.GetRandomString(32); err != nil {
+				return false, err
+			}
+			logger.Infof("User %s has a random password, reset it or use another authentication method to log in", login)
+		}
+		usr, err = m.userService.Create(ctx, &user.CreateUserCommand{
+			Login:        u.Login,
+			Email:        u.Email,
+			Name:         u.Name,
+			Password:     user.Password(password),
+			IsAdmin:      u.IsGrafanaAdmin,
+			SkipOrgSetup: true,
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	for _, r := range resolved {
+		if err := m.assign(ctx, usr.ID, r); err != nil {
+			return isNew, err
+		}
+	}
+	if isNew {
+		// the users are created without organizations, so their current organization is set afterwards
+		return true, m.userService.Update(ctx, &user.UpdateUserCommand{UserID: usr.ID, OrgID: &resolved[0].orgID})
+	}
+	return false, nil
+}
+
+func (m *manager) resolveAssignment(ctx context.Context, a userAssignment, defaultOrgID int64) (resolvedAssignment, error) {
+	r := resolvedAssignment{orgID: defaultOrgID}
+	// the roles aren't case sensitive, and default to Viewer
+	if err := r.role.UnmarshalText([]byte(a.Role)); err != nil {
+		return r, fmt.Errorf("invalid role %q, the role should be Viewer, Editor, Admin or None", a.Role)
+	}
+
+	if a.Org != "" {
+		o, err := m.orgService.GetByName(ctx, &org.GetOrgByNameQuery{Name: a.Org})
+		if err != nil {
+			return r, fmt.Errorf("failed to get organization %s: %w", a.Org, err)
+		}
+		r.orgID = o.ID
+	}
+
+	for _, name := range a.Teams {
+		var ids []int64
+		err := m.db.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table("team").Cols("id").Where("org_id = ? AND name = ?", r.orgID, name).Find(&ids)
+		})
+		if err != nil {
+			return r, err
+		}
+		if len(ids) == 0 {
+			return r, fmt.Errorf("team %s of organization %d doesn't exist", name, r.orgID)
+		}
+		r.teamIDs = append(r.teamIDs, ids[0])
+	}
+	return r, nil
+}
+
+func (m *manager) assign(ctx context.Context, userID int64, r resolvedAssignment) error {
+	err := m.orgService.AddOrgUser(ctx, &org.AddOrgUserCommand{Role: r.role, OrgID: r.orgID, UserID: userID})
+	if errors.Is(err, org.ErrOrgUserAlreadyAdded) {
+		err = m.orgService.UpdateOrgUser(ctx, &org.UpdateOrgUserCommand{Role: r.role, OrgID: r.orgID, UserID: userID})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to assign the user to organization %d: %w", r.orgID, err)
+	}
+
+	for _, teamID := range r.teamIDs {
+		isMember, err := m.teamService.IsTeamMember(ctx, r.orgID, teamID, userID)
+		if err != nil {
+			return err
+		}
+		if isMember {
+			continue
+		}
+		err = m.db.WithDbSession(ctx, func(sess *db.Session) error {
+			return teamimpl.AddOrUpdateTeamMemberHook(sess, userID, r.orgID, teamID, false, team.PermissionTypeMember)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add the user to team %d: %w", teamID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package usermanagement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/server"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// userColumn is a column referencing users, whose values are reassigned from the duplicate user to the user
+// it's merged into.
+type userColumn struct {
+	table  string
+	column string
+	// unique are the other columns of the unique indexes including the column. The rows of the duplicate user
+	// which would be duplicates of rows of the other user are deleted instead of reassigned.
+	unique [][]string
+}
+
+var mergedUserColumns = []userColumn{
+	{table: "team_member", column: "user_id", unique: [][]string{{"org_id", "team_id"}}},
+	{table: "user_role", column: "user_id", unique: [][]string{{"org_id", "role_id"}}},
+	{table: "preferences", column: "user_id", unique: [][]string{{"org_id", "team_id"}}},
+	{table: "star", column: "user_id", unique: [][]string{{"dashboard_id"}, {"dashboard_uid", "org_id"}}},
+	{table: "query_history_star", column: "user_id", unique: [][]string{{"query_uid"}}},
+	{table: "dashboard_acl", column: "user_id", unique: [][]string{{"dashboard_id"}}},
+	{table: "user_auth", column: "user_id"},
+	{table: "dashboard", column: "created_by"},
+	{table: "dashboard", column: "updated_by"},
+	{table: "dashboard_version", column: "created_by"},
+	{table: "library_element", column: "created_by"},
+	{table: "library_element", column: "updated_by"},
+	{table: "annotation", column: "user_id"},
+	{table: "dashboard_snapshot", column: "user_id"},
+	{table: "query_history", column: "created_by"},
+	{table: "short_url", column: "created_by"},
+	{table: "saved_search", column: "user_id"},
+	{table: "saved_search", column: "created_by"},
+}
+
+// MergeUsers merges the duplicate user given as first argument into the user given as second argument. The
+// organization roles, teams, preferences, stars, external logins and owned resources of the duplicate user are
+// reassigned to the other user, and the duplicate user is deleted.
+func MergeUsers(c utils.CommandLine, runner server.Runner) error {
+	if c.Args().Len() != 2 {
+		return errors.New("the duplicate user and the user to merge it into are missing")
+	}
+
+	m := newManager(runner)
+	ctx := context.Background()
+	from, err := m.getUser(ctx, c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	into, err := m.getUser(ctx, c.Args().Get(1))
+	if err != nil {
+		return err
+	}
+	if err := m.mergeUsers(ctx, from, into); err != nil {
+		return err
+	}
+
+	if from.IsAdmin && !into.IsAdmin {
+		logger.Warnf("User %s was a Grafana server administrator, but user %s wasn't made one", from.Login, into.Login)
+	}
+	logger.Infof("User %s merged into user %s %s", from.Login, into.Login, color.GreenString("✔"))
+	return nil
+}
+
+func (m *manager) mergeUsers(ctx context.Context, from, into *user.User) error {
+	if from.ID == into.ID {
+		return errors.New("a user can't be merged into itself")
+	}
+	if from.IsServiceAccount || into.IsServiceAccount {
+		return errors.New("service accounts can't be merged")
+	}
+
+	return m.db.InTransaction(ctx, func(ctx context.Context) error {
+		err := m.db.WithDbSession(ctx, func(sess *db.Session) error {
+			if err := mergeOrgUsers(sess, from.ID, into.ID); err != nil {
+				return err
+			}
+			for _, col := range mergedUserColumns {
+				if err := reassign(sess, col, from.ID, into.ID); err != nil {
+					return fmt.Errorf("failed to reassign %s.%s: %w", col.table, col.column, err)
+				}
+			}
+			// the sessions and quotas of the duplicate user aren't merged
+			for _, table := range []string{"user_auth_token", "quota"} {
+				if _, err := sess.Exec("DELETE FROM "+table+" WHERE user_id = ?", from.ID); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return m.userService.Delete(ctx, &user.DeleteUserCommand{UserID: from.ID})
+	})
+}
+
+// mergeOrgUsers moves the organization memberships of the duplicate user to the other user, which keeps the
+// highest role of both users in the organizations they're both members of.
+func mergeOrgUsers(sess *db.Session, fromID, intoID int64) error {
+	var memberships []org.OrgUser
+	if err := sess.Where("user_id IN (?, ?)", fromID, intoID).Find(&memberships); err != nil {
+		return err
+	}
+	intoRoles := map[int64]org.RoleType{}
+	for _, ou := range memberships {
+		if ou.UserID == intoID {
+			intoRoles[ou.OrgID] = ou.Role
+		}
+	}
+
+	for _, ou := range memberships {
+		if ou.UserID != fromID {
+			continue
+		}
+		role, ok := intoRoles[ou.OrgID]
+		if !ok {
+			if _, err := sess.Exec("UPDATE org_user SET user_id = ? WHERE id = ?", intoID, ou.ID); err != nil {
+				return err
+			}
+			continue
+		}
+		if !role.Includes(ou.Role) {
+			if _, err := sess.Exec("UPDATE org_user SET role = ? WHERE org_id = ? AND user_id = ?", ou.Role, ou.OrgID, intoID); err != nil {
+				return err
+			}
+		}
+		if _, err := sess.Exec("DELETE FROM org_user WHERE id = ?", ou.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reassign replaces the duplicate user by the other user in the column, and deletes the rows of the duplicate
+// user which would break the unique indexes.
+func reassign(sess *db.Session, col userColumn, fromID, intoID int64) error {
+	if len(col.unique) == 0 {
+		_, err := sess.Exec(fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s = ?", col.table, col.column, col.column), intoID, fromID)
+		return err
+	}
+
+	var cols []string
+	for _, index := range col.unique {
+		cols = append(cols, index...)
+	}
+	rows, err := sess.Query(fmt.Sprintf("SELECT id, %s, %s FROM %s WHERE %s IN (?, ?)", col.column, strings.Join(cols, ", "), col.table, col.column), fromID, intoID)
+	if err != nil {
+		return err
+	}
+
+	taken := map[string]bool{}
+	keys := func(row map[string][]byte) []string {
+		var keys []string
+		for i, index := range col.unique {
+			key := fmt.Sprint(i)
+			for _, c := range index {
+				key += "\x00" + string(row[c])
+			}
+			keys = append(keys, key)
+		}
+		return keys
+	}
+	intoValue := fmt.Sprint(intoID)
+	for _, row := range rows {
+		if string(row[col.column]) == intoValue {
+			for _, key := range keys(row) {
+				taken[key] = true
+			}
+		}
+	}
+
+	for _, row := range rows {
+		if string(row[col.column]) == intoValue {
+			continue
+		}
+		duplicate := false
+		for _, key := range keys(row) {
+			duplicate = duplicate || taken[key]
+		}
+		if duplicate {
+			if _, err := sess.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = ?", col.table), string(row["id"])); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := sess.Exec(fmt.Sprintf("UPDATE %s SET %s = ? WHERE id = ?", col.table, col.column), intoID, string(row["id"])); err != nil {
+			return err
+		}
+		for _, key := range keys(row) {
+			taken[key] = true
+		}
+	}
+	return nil
+}
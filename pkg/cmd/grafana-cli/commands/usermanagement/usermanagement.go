@@ -0,0 +1,48 @@
+package usermanagement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/server"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// manager runs the user management commands directly against the database of the Grafana instance.
+type manager struct {
+	cfg         *setting.Cfg
+	db          db.DB
+	userService user.Service
+	orgService  org.Service
+	teamService team.Service
+}
+
+func newManager(runner server.Runner) *manager {
+	return &manager{
+		cfg:         runner.Cfg,
+		db:          runner.SQLStore,
+		userService: runner.UserService,
+		orgService:  runner.OrgService,
+		teamService: runner.TeamService,
+	}
+}
+
+// getUser returns the user with the login, email or ID.
+func (m *manager) getUser(ctx context.Context, loginOrID string) (*user.User, error) {
+	usr, err := m.userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: loginOrID})
+	if errors.Is(err, user.ErrUserNotFound) {
+		if id, parseErr := strconv.ParseInt(loginOrID, 10, 64); parseErr == nil {
+			usr, err = m.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: id})
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user %s: %w", loginOrID, err)
+	}
+	return usr, nil
+}
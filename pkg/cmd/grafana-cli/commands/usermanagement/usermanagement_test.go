@@ -0,0 +1,191 @@
+package usermanagement
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/configprovider"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgimpl"
+	"github.com/grafana/grafana/pkg/services/quota/quotaimpl"
+	"github.com/grafana/grafana/pkg/services/supportbundles/supportbundlestest"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamimpl"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/userimpl"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestReadCSVUsers(t *testing.T) {
+	users, err := readCSVUsers(strings.NewReader(`login,email,Name,role,org,teams,grafana_admin
+alice,alice@example.com,Alice,Editor,Main Org.,SRE; Platform,true
+alice,alice@example.com,Alice,Viewer,Other,,
+bob,bob@example.com,Bob,,,,
+`))
+	require.NoError(t, err)
+	require.Equal(t, []importedUser{
+		{
+			Login:          "alice",
+			Email:          "alice@example.com",
+			Name:           "Alice",
+			IsGrafanaAdmin: true,
+			Orgs: []userAssignment{
+				{Org: "Main Org.", Role: "Editor", Teams: []string{"SRE", "Platform"}},
+				{Org: "Other", Role: "Viewer"},
+			},
+		},
+		{Login: "bob", Email: "bob@example.com", Name: "Bob"},
+	}, users)
+
+	_, err = readCSVUsers(strings.NewReader("login,department\nalice,sales\n"))
+	require.ErrorContains(t, err, "unknown CSV column")
+}
+
+func TestParseDate(t *testing.T) {
+	date, err := parseDate("2024-03-01")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), date)
+
+	_, err = parseDate("01/03/2024")
+	require.Error(t, err)
+}
+
+func TestIntegrationUserManagement(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.AutoAssignOrg = false
+	cfgProvider, err := configprovider.ProvideService(cfg)
+	require.NoError(t, err)
+	quotaService := quotaimpl.ProvideService(ctx, sqlStore, cfgProvider, nil)
+	orgService, err := orgimpl.ProvideService(sqlStore, cfg, quotaService)
+	require.NoError(t, err)
+	teamService, err := teamimpl.ProvideService(sqlStore, cfg, tracing.InitializeTracerForTest())
+	require.NoError(t, err)
+	userService, err := userimpl.ProvideService(
+		sqlStore, orgService, cfg, teamService, nil, tracing.InitializeTracerForTest(),
+		quotaService, supportbundlestest.NewFakeBundleService(),
+	)
+	require.NoError(t, err)
+	m := &manager{cfg: cfg, db: sqlStore, userService: userService, orgService: orgService, teamService: teamService}
+
+	mainOrgID, err := orgService.GetOrCreate(ctx, "Main")
+	require.NoError(t, err)
+	otherOrgID, err := orgService.GetOrCreate(ctx, "Other")
+	require.NoError(t, err)
+	sre, err := teamService.CreateTeam(ctx, &team.CreateTeamCommand{Name: "SRE", OrgID: otherOrgID})
+	require.NoError(t, err)
+
+	userRoles := func(userID int64) map[int64]org.RoleType {
+		orgs, err := orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: userID})
+		require.NoError(t, err)
+		roles := map[int64]org.RoleType{}
+		for _, o := range orgs {
+			roles[o.OrgID] = o.Role
+		}
+		return roles
+	}
+
+	alice := importedUser{
+		Login: "alice",
+		Email: "alice@example.com",
+		Orgs:  []userAssignment{{Org: "Other", Role: "editor", Teams: []string{"SRE"}}, {Role: "Admin"}},
+	}
+	isNew, err := m.importUser(ctx, alice, mainOrgID)
+	require.NoError(t, err)
+	require.True(t, isNew)
+	aliceUser, err := userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: "alice"})
+	require.NoError(t, err)
+	require.Equal(t, otherOrgID, aliceUser.OrgID, "the current organization should be the first organization")
+	require.Equal(t, map[int64]org.RoleType{mainOrgID: org.RoleAdmin, otherOrgID: org.RoleEditor}, userRoles(aliceUser.ID))
+	isMember, err := teamService.IsTeamMember(ctx, otherOrgID, sre.ID, aliceUser.ID)
+	require.NoError(t, err)
+	require.True(t, isMember)
+
+	t.Run("should only assign the existing users", func(t *testing.T) {
+		alice.Orgs[0].Role = "Viewer"
+		isNew, err := m.importUser(ctx, alice, mainOrgID)
+		require.NoError(t, err)
+		require.False(t, isNew)
+		require.Equal(t, org.RoleViewer, userRoles(aliceUser.ID)[otherOrgID])
+	})
+
+	t.Run("should not create the users with invalid assignments", func(t *testing.T) {
+		_, err := m.importUser(ctx, importedUser{Login: "bob", Orgs: []userAssignment{{Role: "Owner"}}}, mainOrgID)
+		require.ErrorContains(t, err, "invalid role")
+		_, err = m.importUser(ctx, importedUser{Login: "bob", Orgs: []userAssignment{{Org: "Other", Teams: []string{"Unknown"}}}}, mainOrgID)
+		require.ErrorContains(t, err, "doesn't exist")
+		_, err = userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: "bob"})
+		require.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+
+	t.Run("should merge the duplicate users", func(t *testing.T) {
+		duplicate := importedUser{
+			Login: "alice.smith",
+			Email: "alice.smith@example.com",
+			Orgs:  []userAssignment{{Role: "Editor"}, {Org: "Other", Role: "Admin", Teams: []string{"SRE"}}},
+		}
+		_, err := m.importUser(ctx, duplicate, mainOrgID)
+		require.NoError(t, err)
+		duplicateUser, err := userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: "alice.smith"})
+		require.NoError(t, err)
+		err = sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Exec("INSERT INTO star (user_id, dashboard_id, dashboard_uid, org_id) VALUES (?, ?, ?, ?)", duplicateUser.ID, 1, "dash", mainOrgID)
+			return err
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, m.mergeUsers(ctx, duplicateUser, aliceUser))
+
+		_, err = userService.GetByID(ctx, &user.GetUserByIDQuery{ID: duplicateUser.ID})
+		require.ErrorIs(t, err, user.ErrUserNotFound)
+		require.Equal(t, map[int64]org.RoleType{mainOrgID: org.RoleAdmin, otherOrgID: org.RoleAdmin}, userRoles(aliceUser.ID), "the highest roles should be kept")
+		var members, stars []int64
+		err = sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			if err := sess.Table("team_member").Cols("user_id").Where("team_id = ?", sre.ID).Find(&members); err != nil {
+				return err
+			}
+			return sess.Table("star").Cols("user_id").Find(&stars)
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int64{aliceUser.ID}, members, "the duplicate team membership should be deleted")
+		require.Equal(t, []int64{aliceUser.ID}, stars)
+	})
+
+	t.Run("should deactivate the inactive users", func(t *testing.T) {
+		lastSeen := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Exec("UPDATE "+sqlStore.GetDialect().Quote("user")+" SET last_seen_at = ?, created = ? WHERE id = ?", lastSeen, lastSeen, aliceUser.ID)
+			return err
+		})
+		require.NoError(t, err)
+
+		users, err := m.inactiveUsers(ctx, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), false)
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		require.Equal(t, "alice", users[0].Login)
+
+		require.NoError(t, m.deactivateUsers(ctx, users))
+		disabled, err := userService.GetByID(ctx, &user.GetUserByIDQuery{ID: aliceUser.ID})
+		require.NoError(t, err)
+		require.True(t, disabled.IsDisabled)
+
+		users, err = m.inactiveUsers(ctx, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), false)
+		require.NoError(t, err)
+		require.Empty(t, users)
+	})
+}
@@ -40,7 +40,7 @@ func ServerCommand(version, commit, enterpriseCommit, buildBranch, buildstamp st
 				BuildStamp:       buildstamp,
 			}, context)
 		},
-		Subcommands: []*cli.Command{TargetCommand(version, commit, buildBranch, buildstamp)},
+		Subcommands: []*cli.Command{TargetCommand(version, commit, buildBranch, buildstamp), MigrateDBCommand(), ValidateConfigCommand(), PrintConfigCommand()},
 	}
 }
 
@@ -126,6 +126,10 @@ func RunServer(opts standalone.BuildInfo, cli *cli.Context) error {
 		return err
 	}
 
+	if DryRun {
+		return s.PlanProvisioning(cli.Context, os.Stdout)
+	}
+
 	go listenToSystemSignals(cli.Context, s)
 	return s.Run()
 }
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func ValidateConfigCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "validate-config",
+		Usage: "check the configuration for unknown settings, invalid values and deprecated settings",
+		Flags: append([]cli.Flag{
+			&cli.BoolFlag{
+				Name:  "strict",
+				Usage: "Fail on warnings, eg: unknown or deprecated settings",
+			},
+		}, commonFlags...),
+		Action: RunValidateConfig,
+	}
+}
+
+func PrintConfigCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "print-config",
+		Usage: "print the effective configuration, merged from the defaults, the config file, the environment variables and the command line",
+		Flags: append([]cli.Flag{
+			&cli.BoolFlag{
+				Name:  "redact",
+				Usage: "Redact the passwords, secrets and URL credentials",
+			},
+		}, commonFlags...),
+		Action: func(c *cli.Context) error {
+			return setting.WriteEffectiveConfig(os.Stdout, configArgs(c), c.Bool("redact"))
+		},
+	}
+}
+
+func RunValidateConfig(c *cli.Context) error {
+	issues, err := setting.ValidateConfig(configArgs(c))
+	if err != nil {
+		return err
+	}
+
+	errors, warnings := 0, 0
+	for _, issue := range issues {
+		if issue.Severity == setting.ConfigIssueError {
+			errors++
+		} else {
+			warnings++
+		}
+		fmt.Printf("%-7s %s\n", strings.ToUpper(string(issue.Severity)), issue)
+	}
+
+	switch {
+	case errors > 0:
+		return fmt.Errorf("the configuration has %d errors and %d warnings", errors, warnings)
+	case warnings > 0 && c.Bool("strict"):
+		return fmt.Errorf("the configuration has %d warnings", warnings)
+	case warnings > 0:
+		fmt.Printf("\nThe configuration is valid, with %d warnings.\n", warnings)
+	default:
+		fmt.Println("The configuration is valid.")
+	}
+	return nil
+}
+
+func configArgs(c *cli.Context) setting.CommandLineArgs {
+	configOptions := strings.Split(ConfigOverrides, " ")
+	return setting.CommandLineArgs{
+		Config:   ConfigFile,
+		HomePath: HomePath,
+		// tailing arguments have precedence over the options string
+		Args: append(configOptions, c.Args().Slice()...),
+	}
+}
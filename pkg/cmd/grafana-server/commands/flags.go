@@ -23,6 +23,7 @@ var (
 	ProfileContention    bool
 	Tracing              bool
 	TracingFile          string
+	DryRun               bool
 )
 
 var commonFlags = []cli.Flag{
@@ -106,4 +107,10 @@ var commonFlags = []cli.Flag{
 		Usage:       "Define tracing output file",
 		Destination: &TracingFile,
 	},
+	&cli.BoolFlag{
+		Name:        "dry-run",
+		Value:       false,
+		Usage:       "Print the changes the provisioning would make and exit without applying them",
+		Destination: &DryRun,
+	},
 }
@@ -71,6 +71,50 @@ type DataSourceCreated struct {
 	OrgID     int64     `json:"org_id"`
 }
 
+// DataSourceUpdated is emitted when a data source is updated.
+type DataSourceUpdated struct {
+	Timestamp time.Time `json:"timestamp"`
+	Name      string    `json:"name"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+}
+
+// DashboardSaved is emitted when a dashboard is created or updated.
+type DashboardSaved struct {
+	Timestamp time.Time `json:"timestamp"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+	FolderUID string    `json:"folder_uid"`
+	Version   int       `json:"version"`
+}
+
+// DashboardDeleted is emitted when a dashboard is deleted.
+type DashboardDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+}
+
+// FolderSaved is emitted when a folder is created or updated.
+type FolderSaved struct {
+	Timestamp time.Time `json:"timestamp"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+	ParentUID string    `json:"parent_uid"`
+}
+
+// FolderDeleted is emitted when a folder is deleted, the dashboards it contained are deleted with it.
+type FolderDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	ID        int64     `json:"id"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+}
+
 // FolderFullPathUpdated is emitted when the full path of the folder(s) is updated.
 // For example, when the folder is renamed or moved to another folder.
 // It does not contain the full path of the folders because calculating
@@ -81,3 +125,53 @@ type FolderFullPathUpdated struct {
 	UIDs      []string  `json:"uids"`
 	OrgID     int64     `json:"org_id"`
 }
+
+// AlertRuleSaved is emitted when an alert rule is created or updated.
+type AlertRuleSaved struct {
+	Timestamp time.Time `json:"timestamp"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+	Title     string    `json:"title"`
+	FolderUID string    `json:"folder_uid"`
+	RuleGroup string    `json:"rule_group"`
+	Version   int64     `json:"version"`
+}
+
+// AlertRuleDeleted is emitted when an alert rule is deleted.
+type AlertRuleDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	UID       string    `json:"uid"`
+	OrgID     int64     `json:"org_id"`
+	Title     string    `json:"title"`
+	FolderUID string    `json:"folder_uid"`
+}
+
+// OrgUserAdded is emitted when a user is added to an organization.
+type OrgUserAdded struct {
+	Timestamp time.Time `json:"timestamp"`
+	OrgID     int64     `json:"org_id"`
+	UserID    int64     `json:"user_id"`
+	Role      string    `json:"role"`
+}
+
+// PermissionsChanged is emitted when the managed permissions of a user, a team or a basic role on a resource change.
+type PermissionsChanged struct {
+	Timestamp  time.Time `json:"timestamp"`
+	OrgID      int64     `json:"org_id"`
+	Resource   string    `json:"resource"`
+	ResourceID string    `json:"resource_id"`
+	// RoleName is the name of the managed role of the user, the team or the basic role
+	RoleName string `json:"role_name"`
+}
+
+// QuotaSoftLimitReached is emitted when the usage of a quota reaches its soft limit.
+type QuotaSoftLimitReached struct {
+	Timestamp time.Time `json:"timestamp"`
+	OrgID     int64     `json:"org_id"`
+	UserID    int64     `json:"user_id,omitempty"`
+	Target    string    `json:"target"`
+	Scope     string    `json:"scope"`
+	Limit     int64     `json:"limit"`
+	SoftLimit int64     `json:"soft_limit"`
+	Used      int64     `json:"used"`
+}
@@ -0,0 +1,250 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/metrics"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+const (
+	defaultAnomalyThreshold = 3.0
+
+	defaultForecastAlpha = 0.5
+	defaultForecastBeta  = 0.1
+	defaultForecastGamma = 0.1
+)
+
+// AnomalyCommand is an expression command detecting the unusual points of a time series.
+type AnomalyCommand struct {
+	VarToScore string
+	Method     mathexp.AnomalyMethod
+	Season     time.Duration
+	Threshold  float64
+	Score      bool
+	refID      string
+}
+
+// NewAnomalyCommand creates a new AnomalyCommand.
+func NewAnomalyCommand(refID, varToScore string, method mathexp.AnomalyMethod, rawSeason string, threshold *float64, score bool) (*AnomalyCommand, error) {
+	cmd := &AnomalyCommand{
+		VarToScore: varToScore,
+		Method:     method,
+		Threshold:  defaultAnomalyThreshold,
+		Score:      score,
+		refID:      refID,
+	}
+
+	switch method {
+	case mathexp.AnomalyZScore, mathexp.AnomalyMAD:
+	case mathexp.AnomalySeasonal:
+		if rawSeason == "" {
+			return nil, fmt.Errorf("the %q anomaly detection method requires a season", method)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported anomaly detection method %q", method)
+	}
+
+	if rawSeason != "" {
+		season, err := gtime.ParseDuration(rawSeason)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to parse anomaly "season" duration field %q: %w`, rawSeason, err)
+		}
+		cmd.Season = season
+	}
+
+	if threshold != nil {
+		if *threshold <= 0 {
+			return nil, fmt.Errorf("the anomaly threshold must be positive, got %v", *threshold)
+		}
+		cmd.Threshold = *threshold
+	}
+
+	return cmd, nil
+}
+
+// UnmarshalAnomalyCommand creates an AnomalyCommand from Grafana's frontend query.
+func UnmarshalAnomalyCommand(rn *rawNode) (*AnomalyCommand, error) {
+	q := AnomalyQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the anomaly command: %w", err)
+	}
+	varToScore, err := getReferenceVar(q.Expression, rn.RefID)
+	if err != nil {
+		return nil, err
+	}
+	return NewAnomalyCommand(rn.RefID, varToScore, q.Method, q.Season, q.Threshold, q.Score)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ac *AnomalyCommand) NeedsVars() []string {
+	return []string{ac.VarToScore}
+}
+
+// Execute runs the command and returns, for every series, a series of the anomaly scores
+// or a series of 1 for the outliers and 0 for the other points.
+func (ac *AnomalyCommand) Execute(ctx context.Context, now time.Time, vars mathexp.Vars, tracer tracing.Tracer, _ *metrics.ExprMetrics) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteAnomaly")
+	defer span.End()
+	newRes := mathexp.Results{}
+	for _, val := range vars[ac.VarToScore].Values {
+		if val == nil {
+			continue
+		}
+		switch v := val.(type) {
+		case mathexp.Series:
+			seasonLength := 0
+			if ac.Method == mathexp.AnomalySeasonal {
+				step, err := v.Step()
+				if err != nil {
+					return newRes, err
+				}
+				seasonLength = int(math.Round(float64(ac.Season) / float64(step)))
+			}
+
+			scores, err := v.AnomalyScores(ac.refID, ac.Method, seasonLength)
+			if err != nil {
+				return newRes, err
+			}
+			if !ac.Score {
+				for i := 0; i < scores.Len(); i++ {
+					t, score := scores.GetPoint(i)
+					if score == nil {
+						continue
+					}
+					outlier := 0.0
+					if math.Abs(*score) > ac.Threshold {
+						outlier = 1
+					}
+					scores.SetPoint(i, t, &outlier)
+				}
+			}
+			newRes.Values = append(newRes.Values, scores)
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+			return newRes, nil
+		default:
+			return newRes, fmt.Errorf("can only detect anomalies in type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+func (ac *AnomalyCommand) Type() string {
+	return TypeAnomaly.String()
+}
+
+// ForecastCommand is an expression command forecasting the next values of a time series.
+type ForecastCommand struct {
+	VarToForecast string
+	Season        time.Duration
+	Horizon       time.Duration
+	Params        mathexp.HoltWintersParams
+	refID         string
+}
+
+// NewForecastCommand creates a new ForecastCommand.
+func NewForecastCommand(refID, varToForecast, rawSeason, rawHorizon string, alpha, beta, gamma *float64) (*ForecastCommand, error) {
+	cmd := &ForecastCommand{
+		VarToForecast: varToForecast,
+		Params: mathexp.HoltWintersParams{
+			Alpha: defaultForecastAlpha,
+			Beta:  defaultForecastBeta,
+			Gamma: defaultForecastGamma,
+		},
+		refID: refID,
+	}
+
+	horizon, err := gtime.ParseDuration(rawHorizon)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse forecast "horizon" duration field %q: %w`, rawHorizon, err)
+	}
+	if horizon <= 0 {
+		return nil, fmt.Errorf("the forecast horizon must be positive, got %q", rawHorizon)
+	}
+	cmd.Horizon = horizon
+
+	if rawSeason != "" {
+		season, err := gtime.ParseDuration(rawSeason)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to parse forecast "season" duration field %q: %w`, rawSeason, err)
+		}
+		cmd.Season = season
+	}
+
+	for _, p := range []struct {
+		value *float64
+		param *float64
+	}{{alpha, &cmd.Params.Alpha}, {beta, &cmd.Params.Beta}, {gamma, &cmd.Params.Gamma}} {
+		if p.value != nil {
+			*p.param = *p.value
+		}
+	}
+
+	return cmd, nil
+}
+
+// UnmarshalForecastCommand creates a ForecastCommand from Grafana's frontend query.
+func UnmarshalForecastCommand(rn *rawNode) (*ForecastCommand, error) {
+	q := ForecastQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the forecast command: %w", err)
+	}
+	varToForecast, err := getReferenceVar(q.Expression, rn.RefID)
+	if err != nil {
+		return nil, err
+	}
+	return NewForecastCommand(rn.RefID, varToForecast, q.Season, q.Horizon, q.Alpha, q.Beta, q.Gamma)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (fc *ForecastCommand) NeedsVars() []string {
+	return []string{fc.VarToForecast}
+}
+
+// Execute runs the command and returns, for every series, a series of the forecast values
+// after the last point of the series.
+func (fc *ForecastCommand) Execute(ctx context.Context, now time.Time, vars mathexp.Vars, tracer tracing.Tracer, _ *metrics.ExprMetrics) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteForecast")
+	defer span.End()
+	newRes := mathexp.Results{}
+	for _, val := range vars[fc.VarToForecast].Values {
+		if val == nil {
+			continue
+		}
+		switch v := val.(type) {
+		case mathexp.Series:
+			step, err := v.Step()
+			if err != nil {
+				return newRes, err
+			}
+			seasonLength := int(math.Round(float64(fc.Season) / float64(step)))
+			horizon := int(math.Ceil(float64(fc.Horizon) / float64(step)))
+
+			forecast, err := v.HoltWinters(fc.refID, fc.Params, seasonLength, horizon)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, forecast)
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+			return newRes, nil
+		default:
+			return newRes, fmt.Errorf("can only forecast type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+func (fc *ForecastCommand) Type() string {
+	return TypeForecast.String()
+}
@@ -0,0 +1,154 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestUnmarshalAnomalyCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected *AnomalyCommand
+		isError  bool
+	}{
+		{
+			name:     "should use the default threshold",
+			query:    `{"expression": "$A", "method": "zscore"}`,
+			expected: &AnomalyCommand{VarToScore: "A", Method: mathexp.AnomalyZScore, Threshold: 3, refID: "B"},
+		},
+		{
+			name:  "should parse the season",
+			query: `{"expression": "$A", "method": "seasonal", "season": "1d", "threshold": 4.5, "score": true}`,
+			expected: &AnomalyCommand{
+				VarToScore: "A", Method: mathexp.AnomalySeasonal, Season: 24 * time.Hour, Threshold: 4.5, Score: true, refID: "B",
+			},
+		},
+		{
+			name:    "should fail when the seasonal method has no season",
+			query:   `{"expression": "$A", "method": "seasonal"}`,
+			isError: true,
+		},
+		{
+			name:    "should fail on unknown method",
+			query:   `{"expression": "$A", "method": "foo"}`,
+			isError: true,
+		},
+		{
+			name:    "should fail on negative threshold",
+			query:   `{"expression": "$A", "method": "mad", "threshold": -1}`,
+			isError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd, err := UnmarshalAnomalyCommand(&rawNode{RefID: "B", QueryRaw: []byte(test.query)})
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, cmd)
+		})
+	}
+}
+
+func TestAnomalyCommand_Execute(t *testing.T) {
+	varToScore := util.GenerateShortUID()
+	series := mathexp.NewSeries(varToScore, nil, 10)
+	for i := 0; i < 10; i++ {
+		v := float64(1 + i%2)
+		if i == 9 {
+			v = 20
+		}
+		series.SetPoint(i, time.Unix(int64(i)*60, 0), &v)
+	}
+
+	t.Run("should return 1 for the outliers and 0 for the other points", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand(util.GenerateShortUID(), varToScore, mathexp.AnomalyMAD, "", nil, false)
+		require.NoError(t, err)
+
+		result, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			varToScore: mathexp.Results{Values: mathexp.Values{series}},
+		}, tracing.InitializeTracerForTest(), nil)
+		require.NoError(t, err)
+		require.Len(t, result.Values, 1)
+
+		res := result.Values[0].(mathexp.Series)
+		for i := 0; i < 9; i++ {
+			assert.Equal(t, 0.0, *res.GetValue(i))
+		}
+		assert.Equal(t, 1.0, *res.GetValue(9))
+	})
+
+	t.Run("should return the scores", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand(util.GenerateShortUID(), varToScore, mathexp.AnomalyMAD, "", nil, true)
+		require.NoError(t, err)
+
+		result, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			varToScore: mathexp.Results{Values: mathexp.Values{series}},
+		}, tracing.InitializeTracerForTest(), nil)
+		require.NoError(t, err)
+		assert.Greater(t, *result.Values[0].(mathexp.Series).GetValue(9), 3.0)
+	})
+
+	t.Run("should return NoData when input NoData", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand(util.GenerateShortUID(), varToScore, mathexp.AnomalyZScore, "", nil, false)
+		require.NoError(t, err)
+
+		result, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			varToScore: mathexp.Results{Values: mathexp.Values{mathexp.NoData{}}},
+		}, tracing.InitializeTracerForTest(), nil)
+		require.NoError(t, err)
+		require.Len(t, result.Values, 1)
+		require.Equal(t, parse.TypeNoData, result.Values[0].Type())
+	})
+
+	t.Run("should return error when input Number", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand(util.GenerateShortUID(), varToScore, mathexp.AnomalyZScore, "", nil, false)
+		require.NoError(t, err)
+
+		_, err = cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			varToScore: mathexp.Results{Values: mathexp.Values{mathexp.NewNumber("test", nil)}},
+		}, tracing.InitializeTracerForTest(), nil)
+		require.Error(t, err)
+	})
+}
+
+func TestForecastCommand_Execute(t *testing.T) {
+	varToForecast := util.GenerateShortUID()
+	series := mathexp.NewSeries(varToForecast, nil, 10)
+	for i := 0; i < 10; i++ {
+		v := float64(i)
+		series.SetPoint(i, time.Unix(int64(i)*60, 0), &v)
+	}
+
+	cmd, err := NewForecastCommand(util.GenerateShortUID(), varToForecast, "", "3m", nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, mathexp.HoltWintersParams{Alpha: 0.5, Beta: 0.1, Gamma: 0.1}, cmd.Params)
+
+	result, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+		varToForecast: mathexp.Results{Values: mathexp.Values{series}},
+	}, tracing.InitializeTracerForTest(), nil)
+	require.NoError(t, err)
+	require.Len(t, result.Values, 1)
+
+	res := result.Values[0].(mathexp.Series)
+	require.Equal(t, 3, res.Len())
+	assert.Equal(t, time.Unix(12*60, 0), res.GetTime(2))
+	assert.InDelta(t, 12, *res.GetValue(2), 1e-9)
+
+	t.Run("should fail without horizon", func(t *testing.T) {
+		_, err := NewForecastCommand(util.GenerateShortUID(), varToForecast, "", "", nil, nil, nil)
+		require.Error(t, err)
+	})
+}
@@ -345,6 +345,12 @@ const (
 	TypeThreshold
 	// TypeSQL is the CMDType for running SQL expressions
 	TypeSQL
+	// TypeAnomaly is the CMDType for detecting anomalies in a timeseries
+	TypeAnomaly
+	// TypeForecast is the CMDType for forecasting a timeseries
+	TypeForecast
+	// TypeJoin is the CMDType for aligning timeseries on the same timestamps
+	TypeJoin
 )
 
 func (gt CommandType) String() string {
@@ -361,6 +367,12 @@ func (gt CommandType) String() string {
 		return "threshold"
 	case TypeSQL:
 		return "sql"
+	case TypeAnomaly:
+		return "anomaly"
+	case TypeForecast:
+		return "forecast"
+	case TypeJoin:
+		return "join"
 	default:
 		return "unknown"
 	}
@@ -381,6 +393,12 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeThreshold, nil
 	case "sql":
 		return TypeSQL, nil
+	case "anomaly":
+		return TypeAnomaly, nil
+	case "forecast":
+		return TypeForecast, nil
+	case "join":
+		return TypeJoin, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
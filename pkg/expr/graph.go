@@ -62,6 +62,7 @@ type DataPipeline []Node
 // map of the refId of the of each command
 func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (mathexp.Vars, error) {
 	vars := make(mathexp.Vars)
+	trace := PipelineTraceFromContext(c)
 
 	groupByDSFlag := s.features.IsEnabled(c, featuremgmt.FlagSseGroupByDatasource)
 	// Execute datasource nodes first, and grouped by datasource.
@@ -74,14 +75,29 @@ func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (m
 			dsNodes = append(dsNodes, node.(*DSNode))
 		}
 
+		start := time.Now()
 		executeDSNodesGrouped(c, now, vars, s, dsNodes)
+		duration := time.Since(start)
+		for _, node := range dsNodes {
+			trace.record(node, duration, vars[node.RefID()], false)
+		}
 	}
 
+	nodes := make([]Node, 0, len(*dp))
 	for _, node := range *dp {
 		if groupByDSFlag && node.NodeType() == TypeDatasourceNode {
 			continue // already executed via executeDSNodesGrouped
 		}
+		nodes = append(nodes, node)
+	}
+	return vars, executeNodes(c, now, vars, s, nodes)
+}
 
+// executeNodes executes the nodes in order and adds their results to vars. The nodes
+// depending on a failed node are not executed.
+func executeNodes(c context.Context, now time.Time, vars mathexp.Vars, s *Service, nodes []Node) error {
+	trace := PipelineTraceFromContext(c)
+	for _, node := range nodes {
 		// Don't execute nodes that have dependent nodes that have failed
 		var hasDepError bool
 		for _, neededVar := range node.NeedsVars() {
@@ -97,6 +113,7 @@ func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (m
 			}
 		}
 		if hasDepError {
+			trace.record(node, 0, vars[node.RefID()], true)
 			continue
 		}
 
@@ -110,17 +127,19 @@ func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (m
 
 		execNode, ok := node.(ExecutableNode)
 		if !ok {
-			return vars, makeUnexpectedNodeTypeError(node.RefID(), node.NodeType().String())
+			return makeUnexpectedNodeTypeError(node.RefID(), node.NodeType().String())
 		}
 
+		start := time.Now()
 		res, err := execNode.Execute(c, now, vars, s)
 		if err != nil {
 			res.Error = err
 		}
 
 		vars[node.RefID()] = res
+		trace.record(node, time.Since(start), res, false)
 	}
-	return vars, nil
+	return nil
 }
 
 // GetDatasourceTypes returns an unique list of data source types used in the query. Machine learning node is encoded as `ml_<type>`, e.g. ml_outlier
@@ -0,0 +1,127 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/metrics"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// JoinCommand is an expression command aligning the time series of several queries on the same timestamps.
+type JoinCommand struct {
+	VarsToJoin []string
+	Mode       mathexp.JoinMode
+	Fill       mathexp.FillPolicy
+	refID      string
+}
+
+// NewJoinCommand creates a new JoinCommand.
+func NewJoinCommand(refID string, expressions []string, mode mathexp.JoinMode, fill mathexp.FillPolicy) (*JoinCommand, error) {
+	if len(expressions) < 2 {
+		return nil, fmt.Errorf("join requires at least two expressions, got %d", len(expressions))
+	}
+
+	cmd := &JoinCommand{
+		VarsToJoin: make([]string, 0, len(expressions)),
+		Mode:       mode,
+		Fill:       fill,
+		refID:      refID,
+	}
+	for _, exp := range expressions {
+		v, err := getReferenceVar(exp, refID)
+		if err != nil {
+			return nil, err
+		}
+		cmd.VarsToJoin = append(cmd.VarsToJoin, v)
+	}
+
+	if cmd.Mode == "" {
+		cmd.Mode = mathexp.JoinOuter
+	}
+	switch cmd.Mode {
+	case mathexp.JoinInner, mathexp.JoinOuter:
+	default:
+		return nil, fmt.Errorf("unsupported join mode %q", mode)
+	}
+
+	if cmd.Fill == "" {
+		cmd.Fill = mathexp.FillNull
+	}
+	switch cmd.Fill {
+	case mathexp.FillNull, mathexp.FillPrevious, mathexp.FillLinear:
+	default:
+		return nil, fmt.Errorf("unsupported join fill policy %q", fill)
+	}
+
+	return cmd, nil
+}
+
+// UnmarshalJoinCommand creates a JoinCommand from Grafana's frontend query.
+func UnmarshalJoinCommand(rn *rawNode) (*JoinCommand, error) {
+	q := JoinQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the join command: %w", err)
+	}
+	return NewJoinCommand(rn.RefID, q.Expressions, q.Mode, q.Fill)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (jc *JoinCommand) NeedsVars() []string {
+	return jc.VarsToJoin
+}
+
+// Execute runs the command and returns the series of all the variables, aligned on the same timestamps.
+// A variable without data is ignored by the outer join, and makes the inner join return no data.
+func (jc *JoinCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer, _ *metrics.ExprMetrics) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteJoin")
+	span.SetAttributes(attribute.String("mode", string(jc.Mode)), attribute.String("fill", string(jc.Fill)))
+	defer span.End()
+
+	newRes := mathexp.Results{}
+	var series []mathexp.Series
+	for _, name := range jc.VarsToJoin {
+		res := vars[name]
+		if res.IsNoData() {
+			if jc.Mode == mathexp.JoinInner {
+				newRes.Values = append(newRes.Values, mathexp.NewNoData())
+				return newRes, nil
+			}
+			continue
+		}
+		for _, val := range res.Values {
+			if val == nil {
+				continue
+			}
+			s, ok := val.(mathexp.Series)
+			if !ok {
+				return newRes, fmt.Errorf("can only join type series, got type %v in %s", val.Type(), name)
+			}
+			series = append(series, s)
+		}
+	}
+
+	if len(series) == 0 {
+		newRes.Values = append(newRes.Values, mathexp.NewNoData())
+		return newRes, nil
+	}
+
+	joined, err := mathexp.JoinSeries(jc.refID, series, jc.Mode, jc.Fill)
+	if err != nil {
+		return newRes, err
+	}
+	for _, s := range joined {
+		newRes.Values = append(newRes.Values, s)
+	}
+	return newRes, nil
+}
+
+func (jc *JoinCommand) Type() string {
+	return TypeJoin.String()
+}
@@ -0,0 +1,122 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestUnmarshalJoinCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected *JoinCommand
+		isError  bool
+	}{
+		{
+			name:     "should default to an outer join with null fill",
+			query:    `{"expressions": ["$A", "B"]}`,
+			expected: &JoinCommand{VarsToJoin: []string{"A", "B"}, Mode: mathexp.JoinOuter, Fill: mathexp.FillNull, refID: "C"},
+		},
+		{
+			name:     "should parse the mode and the fill policy",
+			query:    `{"expressions": ["$A", "$B"], "mode": "inner", "fill": "linear"}`,
+			expected: &JoinCommand{VarsToJoin: []string{"A", "B"}, Mode: mathexp.JoinInner, Fill: mathexp.FillLinear, refID: "C"},
+		},
+		{
+			name:    "should fail with a single expression",
+			query:   `{"expressions": ["$A"]}`,
+			isError: true,
+		},
+		{
+			name:    "should fail on empty expression",
+			query:   `{"expressions": ["$A", ""]}`,
+			isError: true,
+		},
+		{
+			name:    "should fail on unknown mode",
+			query:   `{"expressions": ["$A", "$B"], "mode": "left"}`,
+			isError: true,
+		},
+		{
+			name:    "should fail on unknown fill policy",
+			query:   `{"expressions": ["$A", "$B"], "fill": "zero"}`,
+			isError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd, err := UnmarshalJoinCommand(&rawNode{RefID: "C", QueryRaw: []byte(test.query)})
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, cmd)
+		})
+	}
+}
+
+func TestJoinCommand_Execute(t *testing.T) {
+	newSeries := func(labels data.Labels, seconds ...int64) mathexp.Series {
+		s := mathexp.NewSeries("", labels, len(seconds))
+		for i, sec := range seconds {
+			v := float64(sec)
+			s.SetPoint(i, time.Unix(sec, 0), &v)
+		}
+		return s
+	}
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{newSeries(data.Labels{"host": "a"}, 0, 10, 20)}},
+		"B": mathexp.Results{Values: mathexp.Values{newSeries(data.Labels{"host": "b"}, 0, 5)}},
+		"N": mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}},
+		"X": mathexp.Results{Values: mathexp.Values{mathexp.NewNumber("", nil)}},
+	}
+
+	execute := func(t *testing.T, mode mathexp.JoinMode, expressions ...string) (mathexp.Results, error) {
+		t.Helper()
+		cmd, err := NewJoinCommand("C", expressions, mode, mathexp.FillPrevious)
+		require.NoError(t, err)
+		return cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest(), nil)
+	}
+
+	t.Run("should align all the series on the same timestamps", func(t *testing.T) {
+		res, err := execute(t, mathexp.JoinOuter, "$A", "$B")
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+		for _, v := range res.Values {
+			s := v.(mathexp.Series)
+			require.Equal(t, 4, s.Len())
+			require.Equal(t, "C", s.GetName())
+		}
+		b := res.Values[1].(mathexp.Series)
+		require.Equal(t, data.Labels{"host": "b"}, b.GetLabels())
+		require.Equal(t, 5.0, *b.GetValue(3))
+	})
+
+	t.Run("outer join should ignore the variables without data", func(t *testing.T) {
+		res, err := execute(t, mathexp.JoinOuter, "$A", "$N")
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.Equal(t, 3, res.Values[0].(mathexp.Series).Len())
+	})
+
+	t.Run("inner join should return no data when a variable has no data", func(t *testing.T) {
+		res, err := execute(t, mathexp.JoinInner, "$A", "$N")
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.Equal(t, parse.TypeNoData, res.Values[0].Type())
+	})
+
+	t.Run("should fail when a variable is not a series", func(t *testing.T) {
+		_, err := execute(t, mathexp.JoinOuter, "$A", "$X")
+		require.Error(t, err)
+	})
+}
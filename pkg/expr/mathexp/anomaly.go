@@ -0,0 +1,207 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// The anomaly detection method
+// +enum
+type AnomalyMethod string
+
+const (
+	// Distance to the mean, in standard deviations
+	AnomalyZScore AnomalyMethod = "zscore"
+
+	// Distance to the median, in median absolute deviations
+	AnomalyMAD AnomalyMethod = "mad"
+
+	// Distance of the residual of a seasonal-trend decomposition to the median residual, in median absolute deviations
+	AnomalySeasonal AnomalyMethod = "seasonal"
+)
+
+// madScale makes the median absolute deviation of a normal distribution equal to its standard deviation
+const madScale = 1.4826
+
+// AnomalyScores returns a series with the anomaly score of every point of the series, the
+// signed distance of the point to the usual values of the series. The null points stay null.
+// seasonLength is the number of points of a season, it is only used by the seasonal method.
+func (s Series) AnomalyScores(refID string, method AnomalyMethod, seasonLength int) (Series, error) {
+	values := make([]*float64, s.Len())
+	for i := range values {
+		if v := s.GetValue(i); v != nil && !math.IsNaN(*v) && !math.IsInf(*v, 0) {
+			values[i] = v
+		}
+	}
+
+	var scores []*float64
+	switch method {
+	case AnomalyZScore:
+		scores = zScores(values)
+	case AnomalyMAD:
+		scores = madScores(values)
+	case AnomalySeasonal:
+		if seasonLength < 2 {
+			return s, fmt.Errorf("a season must have at least 2 points, got %d", seasonLength)
+		}
+		if s.Len() < 2*seasonLength {
+			return s, fmt.Errorf("the seasonal method requires at least two seasons of %d points, got %d points", seasonLength, s.Len())
+		}
+		scores = madScores(seasonalResiduals(values, seasonLength))
+	default:
+		return s, fmt.Errorf("anomaly detection method %q not implemented", method)
+	}
+
+	result := NewSeries(refID, s.GetLabels(), s.Len())
+	for i, score := range scores {
+		result.SetPoint(i, s.GetTime(i), score)
+	}
+	return result, nil
+}
+
+func zScores(values []*float64) []*float64 {
+	var sum, count float64
+	for _, v := range values {
+		if v != nil {
+			sum += *v
+			count++
+		}
+	}
+	mean := sum / count
+
+	var variance float64
+	for _, v := range values {
+		if v != nil {
+			variance += (*v - mean) * (*v - mean)
+		}
+	}
+	stddev := math.Sqrt(variance / count)
+
+	return scale(values, mean, stddev)
+}
+
+func madScores(values []*float64) []*float64 {
+	present := make([]float64, 0, len(values))
+	for _, v := range values {
+		if v != nil {
+			present = append(present, *v)
+		}
+	}
+	med := median(present)
+
+	deviations := make([]float64, len(present))
+	for i, v := range present {
+		deviations[i] = math.Abs(v - med)
+	}
+	mad := median(deviations) * madScale
+	if mad == 0 {
+		// more than half of the points are equal, the mean absolute deviation still
+		// separates the other points
+		var sum float64
+		for _, d := range deviations {
+			sum += d
+		}
+		mad = sum / float64(len(deviations)) * math.Sqrt(math.Pi/2)
+	}
+
+	return scale(values, med, mad)
+}
+
+// scale returns the distance of the values to the center, in units of deviation
+func scale(values []*float64, center, deviation float64) []*float64 {
+	scores := make([]*float64, len(values))
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		score := 0.0
+		if deviation > 0 && !math.IsNaN(deviation) {
+			score = (*v - center) / deviation
+		}
+		scores[i] = &score
+	}
+	return scores
+}
+
+// seasonalResiduals decomposes the values into a trend, a season and a residual, and
+// returns the residuals. The trend is the moving average over a season, and the season
+// is the average distance to the trend of the points at the same position in the season.
+func seasonalResiduals(values []*float64, seasonLength int) []*float64 {
+	n := len(values)
+	trend := make([]*float64, n)
+	for i := range values {
+		// the window is shifted at the edges of the series to always cover a full season
+		start := i - seasonLength/2
+		start = max(0, min(start, n-seasonLength))
+		var sum, count float64
+		for _, v := range values[start : start+seasonLength] {
+			if v != nil {
+				sum += *v
+				count++
+			}
+		}
+		if count > 0 {
+			t := sum / count
+			trend[i] = &t
+		}
+	}
+
+	season := make([]float64, seasonLength)
+	counts := make([]float64, seasonLength)
+	for i, v := range values {
+		if v != nil && trend[i] != nil {
+			season[i%seasonLength] += *v - *trend[i]
+			counts[i%seasonLength]++
+		}
+	}
+	var seasonMean float64
+	for p := range season {
+		if counts[p] > 0 {
+			season[p] /= counts[p]
+		}
+		seasonMean += season[p]
+	}
+	seasonMean /= float64(seasonLength)
+
+	residuals := make([]*float64, n)
+	for i, v := range values {
+		if v != nil && trend[i] != nil {
+			r := *v - *trend[i] - (season[i%seasonLength] - seasonMean)
+			residuals[i] = &r
+		}
+	}
+	return residuals
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// Step returns the median interval between two consecutive points of the series
+func (s Series) Step() (time.Duration, error) {
+	if s.Len() < 2 {
+		return 0, fmt.Errorf("the series must have at least 2 points, got %d", s.Len())
+	}
+	intervals := make([]float64, 0, s.Len()-1)
+	for i := 1; i < s.Len(); i++ {
+		intervals = append(intervals, float64(s.GetTime(i).Sub(s.GetTime(i-1))))
+	}
+	step := time.Duration(median(intervals))
+	if step <= 0 {
+		return 0, fmt.Errorf("the points of the series must be sorted by time")
+	}
+	return step, nil
+}
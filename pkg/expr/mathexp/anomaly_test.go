@@ -0,0 +1,140 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeSeriesFromValues(values ...*float64) Series {
+	points := make([]tp, 0, len(values))
+	for i, v := range values {
+		points = append(points, tp{time.Unix(int64(i)*60, 0), v})
+	}
+	return makeSeries("", nil, points...)
+}
+
+func scoresOf(t *testing.T, s Series) []*float64 {
+	t.Helper()
+	scores := make([]*float64, s.Len())
+	for i := range scores {
+		scores[i] = s.GetValue(i)
+	}
+	return scores
+}
+
+func TestSeriesAnomalyScores(t *testing.T) {
+	t.Run("zscore should be the distance to the mean in standard deviations", func(t *testing.T) {
+		s := makeSeriesFromValues(
+			float64Pointer(1), float64Pointer(1), float64Pointer(1), float64Pointer(1), float64Pointer(1),
+			float64Pointer(1), float64Pointer(1), float64Pointer(1), float64Pointer(1), float64Pointer(10),
+		)
+		res, err := s.AnomalyScores("A", AnomalyZScore, 0)
+		require.NoError(t, err)
+		require.Equal(t, s.Len(), res.Len())
+
+		scores := scoresOf(t, res)
+		for _, score := range scores[:9] {
+			assert.InDelta(t, -1.0/3, *score, 1e-9)
+		}
+		assert.InDelta(t, 3, *scores[9], 1e-9)
+		assert.Equal(t, s.GetTime(9), res.GetTime(9))
+	})
+
+	t.Run("mad should be the distance to the median in median absolute deviations", func(t *testing.T) {
+		s := makeSeriesFromValues(
+			float64Pointer(1), float64Pointer(2), float64Pointer(1), float64Pointer(2), float64Pointer(1),
+			float64Pointer(2), float64Pointer(1), float64Pointer(2), float64Pointer(1), float64Pointer(20),
+		)
+		res, err := s.AnomalyScores("A", AnomalyMAD, 0)
+		require.NoError(t, err)
+
+		scores := scoresOf(t, res)
+		for _, score := range scores[:9] {
+			assert.InDelta(t, 1/madScale, math.Abs(*score), 1e-9)
+		}
+		assert.InDelta(t, 37/madScale, *scores[9], 1e-9)
+	})
+
+	t.Run("mad should still score the outliers when most points are equal", func(t *testing.T) {
+		s := makeSeriesFromValues(
+			float64Pointer(5), float64Pointer(5), float64Pointer(5), float64Pointer(5), float64Pointer(5), float64Pointer(9),
+		)
+		res, err := s.AnomalyScores("A", AnomalyMAD, 0)
+		require.NoError(t, err)
+
+		scores := scoresOf(t, res)
+		assert.Equal(t, 0.0, *scores[0])
+		assert.Greater(t, *scores[5], 3.0)
+	})
+
+	t.Run("null points should stay null", func(t *testing.T) {
+		s := makeSeriesFromValues(float64Pointer(1), nil, float64Pointer(3), float64Pointer(math.NaN()))
+		res, err := s.AnomalyScores("A", AnomalyZScore, 0)
+		require.NoError(t, err)
+
+		scores := scoresOf(t, res)
+		assert.InDelta(t, -1, *scores[0], 1e-9)
+		assert.Nil(t, scores[1])
+		assert.InDelta(t, 1, *scores[2], 1e-9)
+		assert.Nil(t, scores[3])
+	})
+
+	t.Run("seasonal should detect the values unusual for their position in the season", func(t *testing.T) {
+		values := make([]*float64, 0, 16)
+		for i := 0; i < 4; i++ {
+			values = append(values, float64Pointer(0), float64Pointer(10), float64Pointer(0), float64Pointer(-10))
+		}
+		// 10 is a usual value of the series, but not at the start of the season
+		values[8] = float64Pointer(10)
+		s := makeSeriesFromValues(values...)
+
+		res, err := s.AnomalyScores("A", AnomalySeasonal, 4)
+		require.NoError(t, err)
+		for i, score := range scoresOf(t, res) {
+			if i == 8 {
+				assert.Greater(t, *score, 3.0)
+				continue
+			}
+			assert.Less(t, math.Abs(*score), 3.0, "point %d", i)
+		}
+
+		res, err = s.AnomalyScores("A", AnomalyMAD, 0)
+		require.NoError(t, err)
+		for i, score := range scoresOf(t, res) {
+			assert.Less(t, math.Abs(*score), 3.0, "point %d", i)
+		}
+	})
+
+	t.Run("seasonal should require two seasons", func(t *testing.T) {
+		s := makeSeriesFromValues(float64Pointer(1), float64Pointer(2), float64Pointer(3))
+		_, err := s.AnomalyScores("A", AnomalySeasonal, 1)
+		require.ErrorContains(t, err, "at least 2 points")
+		_, err = s.AnomalyScores("A", AnomalySeasonal, 2)
+		require.ErrorContains(t, err, "at least two seasons")
+	})
+
+	t.Run("should fail on unknown method", func(t *testing.T) {
+		s := makeSeriesFromValues(float64Pointer(1))
+		_, err := s.AnomalyScores("A", "foo", 0)
+		require.Error(t, err)
+	})
+}
+
+func TestSeriesStep(t *testing.T) {
+	s := makeSeries("", nil,
+		tp{time.Unix(0, 0), float64Pointer(1)},
+		tp{time.Unix(60, 0), float64Pointer(1)},
+		tp{time.Unix(120, 0), float64Pointer(1)},
+		tp{time.Unix(300, 0), float64Pointer(1)},
+	)
+	step, err := s.Step()
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, step)
+
+	_, err = makeSeriesFromValues(float64Pointer(1)).Step()
+	require.Error(t, err)
+}
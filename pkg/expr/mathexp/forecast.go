@@ -0,0 +1,103 @@
+package mathexp
+
+import (
+	"fmt"
+	"time"
+)
+
+// HoltWintersParams are the smoothing factors of the Holt-Winters forecast, between 0 and 1.
+// The higher the factor, the faster the component follows the recent points.
+type HoltWintersParams struct {
+	// Alpha smooths the level
+	Alpha float64
+	// Beta smooths the trend
+	Beta float64
+	// Gamma smooths the season
+	Gamma float64
+}
+
+// HoltWinters returns the forecast of the next horizon points of the series, using additive
+// Holt-Winters exponential smoothing. A seasonLength of 0 forecasts without season, with
+// Holt's linear trend method. The null points are replaced by their forecast.
+func (s Series) HoltWinters(refID string, params HoltWintersParams, seasonLength, horizon int) (Series, error) {
+	for name, f := range map[string]float64{"alpha": params.Alpha, "beta": params.Beta, "gamma": params.Gamma} {
+		if f < 0 || f > 1 {
+			return s, fmt.Errorf("%s must be between 0 and 1, got %v", name, f)
+		}
+	}
+	if horizon < 1 {
+		return s, fmt.Errorf("the horizon must be at least one point, got %d", horizon)
+	}
+	step, err := s.Step()
+	if err != nil {
+		return s, err
+	}
+
+	n := s.Len()
+	values := make([]*float64, n)
+	for i := range values {
+		values[i] = s.GetValue(i)
+	}
+
+	// the initial level and trend are estimated from the first two seasons, or the first two points
+	period := max(seasonLength, 1)
+	if n < 2*period {
+		return s, fmt.Errorf("the forecast requires at least %d points, got %d", 2*period, n)
+	}
+	first, ok := mean(values[:period])
+	if !ok {
+		return s, fmt.Errorf("the first season of the series has no value")
+	}
+	second, ok := mean(values[period : 2*period])
+	if !ok {
+		return s, fmt.Errorf("the second season of the series has no value")
+	}
+	level := first
+	trend := (second - first) / float64(period)
+	season := make([]float64, period)
+	if seasonLength > 0 {
+		for i := range season {
+			if values[i] != nil {
+				season[i] = *values[i] - first
+			}
+		}
+	}
+
+	for t := period; t < n; t++ {
+		p := t % period
+		v := level + trend + season[p]
+		if values[t] != nil {
+			v = *values[t]
+		}
+
+		lastLevel := level
+		level = params.Alpha*(v-season[p]) + (1-params.Alpha)*(level+trend)
+		trend = params.Beta*(level-lastLevel) + (1-params.Beta)*trend
+		if seasonLength > 0 {
+			season[p] = params.Gamma*(v-level) + (1-params.Gamma)*season[p]
+		}
+	}
+
+	result := NewSeries(refID, s.GetLabels(), horizon)
+	last := s.GetTime(n - 1)
+	for h := 1; h <= horizon; h++ {
+		f := level + float64(h)*trend + season[(n-1+h)%period]
+		result.SetPoint(h-1, last.Add(time.Duration(h)*step), &f)
+	}
+	return result, nil
+}
+
+// mean returns the mean of the non null values
+func mean(values []*float64) (float64, bool) {
+	var sum, count float64
+	for _, v := range values {
+		if v != nil {
+			sum += *v
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return sum / count, true
+}
@@ -0,0 +1,54 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeriesHoltWinters(t *testing.T) {
+	params := HoltWintersParams{Alpha: 0.5, Beta: 0.1, Gamma: 0.1}
+
+	t.Run("should follow the trend without season", func(t *testing.T) {
+		values := make([]*float64, 10)
+		for i := range values {
+			values[i] = float64Pointer(float64(i))
+		}
+		s := makeSeriesFromValues(values...)
+
+		res, err := s.HoltWinters("A", params, 0, 2)
+		require.NoError(t, err)
+		require.Equal(t, 2, res.Len())
+		assert.Equal(t, time.Unix(600, 0), res.GetTime(0))
+		assert.InDelta(t, 10, *res.GetValue(0), 1e-9)
+		assert.Equal(t, time.Unix(660, 0), res.GetTime(1))
+		assert.InDelta(t, 11, *res.GetValue(1), 1e-9)
+	})
+
+	t.Run("should repeat the season", func(t *testing.T) {
+		s := makeSeriesFromValues(
+			float64Pointer(1), float64Pointer(3), float64Pointer(1), float64Pointer(3),
+			float64Pointer(1), nil, float64Pointer(1), float64Pointer(3),
+		)
+
+		res, err := s.HoltWinters("A", params, 2, 3)
+		require.NoError(t, err)
+		require.Equal(t, 3, res.Len())
+		assert.InDelta(t, 1, *res.GetValue(0), 1e-9)
+		assert.InDelta(t, 3, *res.GetValue(1), 1e-9)
+		assert.InDelta(t, 1, *res.GetValue(2), 1e-9)
+	})
+
+	t.Run("should fail on invalid parameters", func(t *testing.T) {
+		s := makeSeriesFromValues(float64Pointer(1), float64Pointer(2), float64Pointer(3), float64Pointer(4))
+
+		_, err := s.HoltWinters("A", HoltWintersParams{Alpha: 2}, 0, 1)
+		require.ErrorContains(t, err, "alpha")
+		_, err = s.HoltWinters("A", params, 0, 0)
+		require.ErrorContains(t, err, "horizon")
+		_, err = s.HoltWinters("A", params, 3, 1)
+		require.ErrorContains(t, err, "at least 6 points")
+	})
+}
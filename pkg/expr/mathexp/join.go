@@ -0,0 +1,137 @@
+package mathexp
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// The join mode
+// +enum
+type JoinMode string
+
+const (
+	// Keep the timestamps present in every series
+	JoinInner JoinMode = "inner"
+
+	// Keep the timestamps present in any series
+	JoinOuter JoinMode = "outer"
+)
+
+// The fill policy of the points missing in a series
+// +enum
+type FillPolicy string
+
+const (
+	// Leave the missing points null
+	FillNull FillPolicy = "null"
+
+	// Use the previous value of the series
+	FillPrevious FillPolicy = "previous"
+
+	// Interpolate linearly between the surrounding values of the series
+	FillLinear FillPolicy = "linear"
+)
+
+// JoinSeries aligns the series on the same timestamps. With the inner mode the result only has
+// the timestamps present in every series, with the outer mode it has the timestamps present in
+// any series, and the points missing in a series are filled with the fill policy.
+// The result has one series per input series, with the same labels, in the same order.
+func JoinSeries(refID string, series []Series, mode JoinMode, fill FillPolicy) ([]Series, error) {
+	switch fill {
+	case FillNull, FillPrevious, FillLinear:
+	default:
+		return nil, fmt.Errorf("fill policy %q not implemented", fill)
+	}
+
+	// the points of every series, sorted by time, the last point wins on duplicated timestamps
+	points := make([]map[int64]*float64, len(series))
+	sorted := make([][]int64, len(series))
+	for i, s := range series {
+		points[i] = make(map[int64]*float64, s.Len())
+		for j := 0; j < s.Len(); j++ {
+			t, v := s.GetPoint(j)
+			if _, ok := points[i][t.UnixNano()]; !ok {
+				sorted[i] = append(sorted[i], t.UnixNano())
+			}
+			points[i][t.UnixNano()] = v
+		}
+		slices.Sort(sorted[i])
+	}
+
+	var times []int64
+	switch mode {
+	case JoinOuter:
+		seen := map[int64]bool{}
+		for _, ts := range sorted {
+			for _, t := range ts {
+				if !seen[t] {
+					seen[t] = true
+					times = append(times, t)
+				}
+			}
+		}
+		slices.Sort(times)
+	case JoinInner:
+		if len(sorted) > 0 {
+		TIMES:
+			for _, t := range sorted[0] {
+				for _, p := range points[1:] {
+					if _, ok := p[t]; !ok {
+						continue TIMES
+					}
+				}
+				times = append(times, t)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("join mode %q not implemented", mode)
+	}
+
+	joined := make([]Series, 0, len(series))
+	for i, s := range series {
+		result := NewSeries(refID, s.GetLabels(), len(times))
+		// idx is the index in sorted[i] of the first timestamp after t
+		idx := 0
+		for j, t := range times {
+			for idx < len(sorted[i]) && sorted[i][idx] <= t {
+				idx++
+			}
+			v, ok := points[i][t]
+			if !ok {
+				v = fillPoint(fill, t, points[i], sorted[i], idx)
+			}
+			result.SetPoint(j, time.Unix(0, t), v)
+		}
+		joined = append(joined, result)
+	}
+	return joined, nil
+}
+
+// fillPoint returns the value of a point missing at t in a series, where next is the index
+// of the first timestamp of the series after t
+func fillPoint(fill FillPolicy, t int64, points map[int64]*float64, sorted []int64, next int) *float64 {
+	prev := next - 1
+	for prev >= 0 && points[sorted[prev]] == nil {
+		prev--
+	}
+	if prev < 0 || fill == FillNull {
+		return nil
+	}
+	before := points[sorted[prev]]
+	if fill == FillPrevious {
+		v := *before
+		return &v
+	}
+
+	for next < len(sorted) && points[sorted[next]] == nil {
+		next++
+	}
+	if next == len(sorted) {
+		return nil
+	}
+	after := points[sorted[next]]
+	ratio := float64(t-sorted[prev]) / float64(sorted[next]-sorted[prev])
+	v := *before + (*after-*before)*ratio
+	return &v
+}
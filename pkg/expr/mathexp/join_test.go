@@ -0,0 +1,141 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoinSeries(t *testing.T) {
+	a := makeSeries("A", data.Labels{"host": "a"},
+		tp{time.Unix(0, 0), float64Pointer(1)},
+		tp{time.Unix(10, 0), float64Pointer(3)},
+		tp{time.Unix(20, 0), float64Pointer(5)},
+	)
+	// the points are not sorted by time
+	b := makeSeries("B", data.Labels{"host": "b"},
+		tp{time.Unix(5, 0), float64Pointer(20)},
+		tp{time.Unix(0, 0), float64Pointer(10)},
+		tp{time.Unix(20, 0), float64Pointer(40)},
+		tp{time.Unix(25, 0), nil},
+	)
+
+	tests := []struct {
+		name     string
+		mode     JoinMode
+		fill     FillPolicy
+		expected []Series
+	}{
+		{
+			name: "outer join should keep the missing points null",
+			mode: JoinOuter,
+			fill: FillNull,
+			expected: []Series{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(5, 0), nil},
+					tp{time.Unix(10, 0), float64Pointer(3)},
+					tp{time.Unix(20, 0), float64Pointer(5)},
+					tp{time.Unix(25, 0), nil},
+				),
+				makeSeries("", data.Labels{"host": "b"},
+					tp{time.Unix(0, 0), float64Pointer(10)},
+					tp{time.Unix(5, 0), float64Pointer(20)},
+					tp{time.Unix(10, 0), nil},
+					tp{time.Unix(20, 0), float64Pointer(40)},
+					tp{time.Unix(25, 0), nil},
+				),
+			},
+		},
+		{
+			name: "outer join should fill the missing points with the previous value",
+			mode: JoinOuter,
+			fill: FillPrevious,
+			expected: []Series{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(5, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), float64Pointer(3)},
+					tp{time.Unix(20, 0), float64Pointer(5)},
+					tp{time.Unix(25, 0), float64Pointer(5)},
+				),
+				makeSeries("", data.Labels{"host": "b"},
+					tp{time.Unix(0, 0), float64Pointer(10)},
+					tp{time.Unix(5, 0), float64Pointer(20)},
+					tp{time.Unix(10, 0), float64Pointer(20)},
+					tp{time.Unix(20, 0), float64Pointer(40)},
+					tp{time.Unix(25, 0), nil},
+				),
+			},
+		},
+		{
+			name: "outer join should interpolate the missing points",
+			mode: JoinOuter,
+			fill: FillLinear,
+			expected: []Series{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(5, 0), float64Pointer(2)},
+					tp{time.Unix(10, 0), float64Pointer(3)},
+					tp{time.Unix(20, 0), float64Pointer(5)},
+					tp{time.Unix(25, 0), nil},
+				),
+				makeSeries("", data.Labels{"host": "b"},
+					tp{time.Unix(0, 0), float64Pointer(10)},
+					tp{time.Unix(5, 0), float64Pointer(20)},
+					tp{time.Unix(10, 0), float64Pointer(20 + 20.0/3)},
+					tp{time.Unix(20, 0), float64Pointer(40)},
+					tp{time.Unix(25, 0), nil},
+				),
+			},
+		},
+		{
+			name: "inner join should only keep the timestamps present in every series",
+			mode: JoinInner,
+			fill: FillLinear,
+			expected: []Series{
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(20, 0), float64Pointer(5)},
+				),
+				makeSeries("", data.Labels{"host": "b"},
+					tp{time.Unix(0, 0), float64Pointer(10)},
+					tp{time.Unix(20, 0), float64Pointer(40)},
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			joined, err := JoinSeries("", []Series{a, b}, tt.mode, tt.fill)
+			require.NoError(t, err)
+			require.Len(t, joined, len(tt.expected))
+			for i, s := range joined {
+				expected := tt.expected[i]
+				require.Equal(t, expected.GetLabels(), s.GetLabels())
+				require.Equal(t, expected.Len(), s.Len())
+				for j := 0; j < s.Len(); j++ {
+					et, ev := expected.GetPoint(j)
+					at, av := s.GetPoint(j)
+					require.True(t, et.Equal(at), "point %d: expected time %v, got %v", j, et, at)
+					if ev == nil {
+						require.Nil(t, av, "point %d", j)
+						continue
+					}
+					require.NotNil(t, av, "point %d", j)
+					require.InDelta(t, *ev, *av, 1e-9, "point %d", j)
+				}
+			}
+		})
+	}
+
+	t.Run("should fail on unknown mode or fill policy", func(t *testing.T) {
+		_, err := JoinSeries("", []Series{a, b}, "left", FillNull)
+		require.Error(t, err)
+		_, err = JoinSeries("", []Series{a, b}, JoinOuter, "zero")
+		require.Error(t, err)
+	})
+}
@@ -12,6 +12,8 @@ type ExprMetrics struct {
 	SqlCommandDuration      *prometheus.HistogramVec
 	SqlCommandCount         *prometheus.CounterVec
 	SqlCommandCellCount     *prometheus.HistogramVec
+
+	ExpressionResultCacheRequests *prometheus.CounterVec
 }
 
 func newExprMetrics(subsystem string) *ExprMetrics {
@@ -59,6 +61,13 @@ func newExprMetrics(subsystem string) *ExprMetrics {
 			},
 			[]string{"status"},
 		),
+
+		ExpressionResultCacheRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: subsystem,
+			Name:      "expression_result_cache_requests_total",
+			Help:      "Number of lookups of expression results in the result cache, by result (hit or miss)",
+		}, []string{"result"}),
 	}
 }
 
@@ -76,6 +85,8 @@ func NewSSEMetrics(reg prometheus.Registerer) *ExprMetrics {
 		SqlCommandCount: newExprMetrics(metricsSubSystem).SqlCommandCount,
 
 		SqlCommandCellCount: newExprMetrics(metricsSubSystem).SqlCommandCellCount,
+
+		ExpressionResultCacheRequests: newExprMetrics(metricsSubSystem).ExpressionResultCacheRequests,
 	}
 
 	if reg != nil {
@@ -85,6 +96,7 @@ func NewSSEMetrics(reg prometheus.Registerer) *ExprMetrics {
 			m.SqlCommandDuration,
 			m.SqlCommandCount,
 			m.SqlCommandCellCount,
+			m.ExpressionResultCacheRequests,
 		)
 	}
 
@@ -105,6 +117,8 @@ func NewQueryServiceExpressionsMetrics(reg prometheus.Registerer) *ExprMetrics {
 		SqlCommandCount: newExprMetrics(metricsSubSystem).SqlCommandCount,
 
 		SqlCommandCellCount: newExprMetrics(metricsSubSystem).SqlCommandCellCount,
+
+		ExpressionResultCacheRequests: newExprMetrics(metricsSubSystem).ExpressionResultCacheRequests,
 	}
 
 	if reg != nil {
@@ -114,6 +128,7 @@ func NewQueryServiceExpressionsMetrics(reg prometheus.Registerer) *ExprMetrics {
 			m.SqlCommandDuration,
 			m.SqlCommandCount,
 			m.SqlCommandCellCount,
+			m.ExpressionResultCacheRequests,
 		)
 	}
 
@@ -79,6 +79,9 @@ type CMDNode struct {
 	baseNode
 	CMDType CommandType
 	Command Command
+
+	// query is the raw query of the expression, used to key the cached results
+	query []byte
 }
 
 // ID returns the id of the node so it can fulfill the gonum's graph Node interface.
@@ -104,7 +107,9 @@ func (gn *CMDNode) NeedsVars() []string {
 // other nodes they must have already been executed and their results must
 // already by in vars.
 func (gn *CMDNode) Execute(ctx context.Context, now time.Time, vars mathexp.Vars, s *Service) (mathexp.Results, error) {
-	return gn.Command.Execute(ctx, now, vars, s.tracer, s.metrics)
+	return s.resultCache.execute(gn, now, vars, func() (mathexp.Results, error) {
+		return gn.Command.Execute(ctx, now, vars, s.tracer, s.metrics)
+	})
 }
 
 func buildCMDNode(ctx context.Context, rn *rawNode, toggles featuremgmt.FeatureToggles, cfg *setting.Cfg) (*CMDNode, error) {
@@ -125,6 +130,7 @@ func buildCMDNode(ctx context.Context, rn *rawNode, toggles featuremgmt.FeatureT
 			refID: rn.RefID,
 		},
 		CMDType: commandType,
+		query:   rn.QueryRaw,
 	}
 
 	if toggles.IsEnabledGlobally(featuremgmt.FlagExpressionParser) {
@@ -165,6 +171,12 @@ func buildCMDNode(ctx context.Context, rn *rawNode, toggles featuremgmt.FeatureT
 		node.Command, err = UnmarshalThresholdCommand(rn)
 	case TypeSQL:
 		node.Command, err = UnmarshalSQLCommand(ctx, rn, cfg)
+	case TypeAnomaly:
+		node.Command, err = UnmarshalAnomalyCommand(rn)
+	case TypeForecast:
+		node.Command, err = UnmarshalForecastCommand(rn)
+	case TypeJoin:
+		node.Command, err = UnmarshalJoinCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in expression '%v' not implemented", commandType, rn.RefID)
 	}
@@ -0,0 +1,87 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// PipelineTrace records how the nodes of a pipeline were executed. It is filled by
+// the execution of a pipeline when it is attached to the context with WithPipelineTrace.
+type PipelineTrace struct {
+	mu    sync.Mutex
+	nodes []NodeTrace
+}
+
+// NodeTrace is the execution of a node of a pipeline.
+type NodeTrace struct {
+	RefID string
+	// NodeType is the type of the node: Expression, Datasource or Machine Learning.
+	NodeType string
+	// Kind is the command type of an expression, or the plugin type of a datasource query.
+	Kind string
+	// Inputs are the refIDs of the nodes the node depends on.
+	Inputs []string
+	// Duration is the time spent executing the node. The datasource queries grouped in a
+	// single request share the duration of the request.
+	Duration time.Duration
+	// Skipped is true when the node was not executed because one of its inputs failed.
+	Skipped bool
+	Error   error
+}
+
+type pipelineTraceKey struct{}
+
+// WithPipelineTrace returns a context recording the execution of the pipelines in trace.
+func WithPipelineTrace(ctx context.Context, trace *PipelineTrace) context.Context {
+	return context.WithValue(ctx, pipelineTraceKey{}, trace)
+}
+
+// PipelineTraceFromContext returns the trace attached to the context, or nil.
+func PipelineTraceFromContext(ctx context.Context) *PipelineTrace {
+	trace, _ := ctx.Value(pipelineTraceKey{}).(*PipelineTrace)
+	return trace
+}
+
+// Nodes returns the executed nodes, in execution order.
+func (t *PipelineTrace) Nodes() []NodeTrace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	nodes := make([]NodeTrace, len(t.nodes))
+	copy(nodes, t.nodes)
+	return nodes
+}
+
+func (t *PipelineTrace) record(node Node, duration time.Duration, res mathexp.Results, skipped bool) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.nodes = append(t.nodes, NodeTrace{
+		RefID:    node.RefID(),
+		NodeType: node.NodeType().String(),
+		Kind:     nodeKind(node),
+		Inputs:   node.NeedsVars(),
+		Duration: duration,
+		Skipped:  skipped,
+		Error:    res.Error,
+	})
+}
+
+func nodeKind(node Node) string {
+	switch n := node.(type) {
+	case *CMDNode:
+		if n.Command != nil {
+			return n.Command.Type()
+		}
+	case *DSNode:
+		return n.String()
+	case *MLNode:
+		return fmt.Sprintf("ml_%s", n.command.Type())
+	}
+	return ""
+}
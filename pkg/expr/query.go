@@ -29,6 +29,15 @@ const (
 
 	// SQL query
 	QueryTypeSQL QueryType = "sql"
+
+	// Detect the anomalies of a series
+	QueryTypeAnomaly QueryType = "anomaly"
+
+	// Forecast the next values of a series
+	QueryTypeForecast QueryType = "forecast"
+
+	// Align series on the same timestamps
+	QueryTypeJoin QueryType = "join"
 )
 
 type MathQuery struct {
@@ -80,6 +89,57 @@ type SQLExpression struct {
 	Format     string `json:"format"`
 }
 
+// QueryType = anomaly
+type AnomalyQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The detection method
+	Method mathexp.AnomalyMethod `json:"method"`
+
+	// The duration of a season, required by the seasonal method
+	Season string `json:"season,omitempty" jsonschema:"example=1d,example=1w"`
+
+	// The score above which a point is an outlier, defaults to 3
+	Threshold *float64 `json:"threshold,omitempty"`
+
+	// Return the anomaly score of every point, instead of 1 for the outliers and 0 for the other points
+	Score bool `json:"score,omitempty"`
+}
+
+// QueryType = forecast
+type ForecastQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// How far the series is forecast
+	Horizon string `json:"horizon" jsonschema:"minLength=1,example=1h,example=1d"`
+
+	// The duration of a season, the series is forecast without season when empty
+	Season string `json:"season,omitempty" jsonschema:"example=1d,example=1w"`
+
+	// The smoothing factor of the level, between 0 and 1, defaults to 0.5
+	Alpha *float64 `json:"alpha,omitempty"`
+
+	// The smoothing factor of the trend, between 0 and 1, defaults to 0.1
+	Beta *float64 `json:"beta,omitempty"`
+
+	// The smoothing factor of the season, between 0 and 1, defaults to 0.1
+	Gamma *float64 `json:"gamma,omitempty"`
+}
+
+// QueryType = join
+type JoinQuery struct {
+	// References to the query results to join
+	Expressions []string `json:"expressions" jsonschema:"minItems=2"`
+
+	// The join mode, defaults to outer
+	Mode mathexp.JoinMode `json:"mode,omitempty"`
+
+	// The fill policy of the points missing in a series, defaults to null
+	Fill mathexp.FillPolicy `json:"fill,omitempty"`
+}
+
 //-------------------------------
 // Non-query commands
 //-------------------------------
@@ -27,6 +27,9 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				reflect.TypeOf(ReduceModeDrop),       // pick an example value (not the root)
 				reflect.TypeOf(ThresholdIsAbove),
 				reflect.TypeOf(classic.ConditionOperatorAnd),
+				reflect.TypeOf(mathexp.AnomalyZScore),
+				reflect.TypeOf(mathexp.JoinOuter),
+				reflect.TypeOf(mathexp.FillNull),
 			},
 		})
 	require.NoError(t, err)
@@ -161,6 +164,48 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				},
 			},
 		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeAnomaly),
+			GoType:         reflect.TypeOf(&AnomalyQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "outliers of A with a daily season",
+					SaveModel: data.AsUnstructured(AnomalyQuery{
+						Expression: "$A",
+						Method:     mathexp.AnomalySeasonal,
+						Season:     "1d",
+					}),
+				},
+			},
+		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeForecast),
+			GoType:         reflect.TypeOf(&ForecastQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "forecast A for the next hour",
+					SaveModel: data.AsUnstructured(ForecastQuery{
+						Expression: "$A",
+						Horizon:    "1h",
+						Season:     "1d",
+					}),
+				},
+			},
+		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeJoin),
+			GoType:         reflect.TypeOf(&JoinQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "align A and B, interpolating the missing points",
+					SaveModel: data.AsUnstructured(JoinQuery{
+						Expressions: []string{"$A", "$B"},
+						Mode:        mathexp.JoinOuter,
+						Fill:        mathexp.FillLinear,
+					}),
+				},
+			},
+		},
 	)
 
 	require.NoError(t, err)
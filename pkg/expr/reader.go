@@ -180,6 +180,36 @@ func (h *ExpressionQueryReader) ReadQuery(
 			}
 		}
 
+	case QueryTypeAnomaly:
+		q := &AnomalyQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
+		}
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = NewAnomalyCommand(common.RefID, referenceVar, q.Method, q.Season, q.Threshold, q.Score)
+		}
+
+	case QueryTypeForecast:
+		q := &ForecastQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
+		}
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = NewForecastCommand(common.RefID, referenceVar, q.Season, q.Horizon, q.Alpha, q.Beta, q.Gamma)
+		}
+
+	case QueryTypeJoin:
+		q := &JoinQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = NewJoinCommand(common.RefID, q.Expressions, q.Mode, q.Fill)
+		}
+
 	default:
 		err = fmt.Errorf("unknown query type (%s)", common.QueryType)
 	}
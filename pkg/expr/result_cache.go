@@ -0,0 +1,98 @@
+package expr
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/metrics"
+)
+
+// resultCache caches the results of the expressions, keyed on the query of the expression and the
+// digest of the frames of its inputs, so the evaluations of the same expression on the same data,
+// for example by alert rules sharing expressions or by dashboard refreshes, are not computed again.
+// The cached results are shared and must not be modified.
+type resultCache struct {
+	entries *expirable.LRU[string, mathexp.Results]
+	metrics *metrics.ExprMetrics
+}
+
+func newResultCache(ttl time.Duration, maxEntries int, m *metrics.ExprMetrics) *resultCache {
+	if ttl <= 0 || maxEntries <= 0 {
+		return nil
+	}
+	return &resultCache{
+		entries: expirable.NewLRU[string, mathexp.Results](maxEntries, nil, ttl),
+		metrics: m,
+	}
+}
+
+// execute returns the cached results of the node, or executes the node and caches its results
+// when they have no error.
+func (c *resultCache) execute(node *CMDNode, now time.Time, vars mathexp.Vars, execute func() (mathexp.Results, error)) (mathexp.Results, error) {
+	key, ok := c.key(node, now, vars)
+	if !ok {
+		return execute()
+	}
+
+	if res, ok := c.entries.Get(key); ok {
+		c.metrics.ExpressionResultCacheRequests.WithLabelValues("hit").Inc()
+		return res, nil
+	}
+	c.metrics.ExpressionResultCacheRequests.WithLabelValues("miss").Inc()
+
+	res, err := execute()
+	if err == nil && res.Error == nil {
+		c.entries.Add(key, res)
+	}
+	return res, err
+}
+
+// key returns the digest of the query of the node and of the frames of its inputs. It returns false
+// for the nodes that can't be cached because their results depend on more than their inputs.
+func (c *resultCache) key(node *CMDNode, now time.Time, vars mathexp.Vars) (string, bool) {
+	if c == nil || node.query == nil {
+		return "", false
+	}
+	if _, ok := node.Command.(*HysteresisCommand); ok {
+		// the results depend on the state of the alert rule
+		return "", false
+	}
+
+	h := sha256.New()
+	writeString(h, node.refID)
+	writeString(h, node.CMDType.String())
+	_, _ = h.Write(node.query)
+	if node.CMDType == TypeResample {
+		// the time range of the resampling is relative to now
+		_ = binary.Write(h, binary.LittleEndian, now.UnixNano())
+	}
+
+	for _, name := range node.NeedsVars() {
+		res, ok := vars[name]
+		if !ok || res.Error != nil {
+			return "", false
+		}
+		writeString(h, name)
+		for _, frame := range res.Values.AsDataFrames(name) {
+			b, err := data.FrameToJSON(frame, data.IncludeAll)
+			if err != nil {
+				return "", false
+			}
+			_, _ = h.Write(b)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// writeString writes the length of the string before the string, so the concatenated strings are not ambiguous.
+func writeString(h hash.Hash, s string) {
+	_ = binary.Write(h, binary.LittleEndian, int64(len(s)))
+	_, _ = h.Write([]byte(s))
+}
@@ -0,0 +1,74 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+func TestResultCache(t *testing.T) {
+	frame := func(v float64) data.Frames {
+		return data.Frames{data.NewFrame("",
+			data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+			data.NewField("value", data.Labels{"test": "label"}, []*float64{fp(v)}),
+		)}
+	}
+	resp := map[string]backend.DataResponse{
+		"A": {Frames: frame(2)},
+	}
+
+	queries := []Query{
+		{
+			RefID:      "A",
+			DataSource: &datasources.DataSource{OrgID: 1, UID: "test", Type: "test"},
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "1" }, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+		},
+		{
+			RefID:      "B",
+			DataSource: dataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$A * 2" }`),
+		},
+	}
+
+	s, req := newMockQueryService(resp, queries)
+	s.resultCache = newResultCache(time.Minute, 10, s.metrics)
+
+	execute := func() float64 {
+		t.Helper()
+		pl, err := s.BuildPipeline(t.Context(), req)
+		require.NoError(t, err)
+		res, err := s.ExecutePipeline(context.Background(), time.Now(), pl)
+		require.NoError(t, err)
+		v, err := res.Responses["B"].Frames[0].Fields[1].NullableFloatAt(0)
+		require.NoError(t, err)
+		return *v
+	}
+	requests := func(result string) float64 {
+		return testutil.ToFloat64(s.metrics.ExpressionResultCacheRequests.WithLabelValues(result))
+	}
+
+	require.Equal(t, 4.0, execute())
+	require.Equal(t, 0.0, requests("hit"))
+	require.Equal(t, 1.0, requests("miss"))
+
+	require.Equal(t, 4.0, execute())
+	require.Equal(t, 1.0, requests("hit"), "the same expression on the same data should be cached")
+	require.Equal(t, 1.0, requests("miss"))
+
+	s.dataService.(*mockEndpoint).Responses["A"] = backend.DataResponse{Frames: frame(3)}
+	require.Equal(t, 6.0, execute())
+	require.Equal(t, 1.0, requests("hit"))
+	require.Equal(t, 2.0, requests("miss"), "the expression should be computed again on other data")
+
+	t.Run("should not cache without ttl", func(t *testing.T) {
+		require.Nil(t, newResultCache(0, 10, s.metrics))
+	})
+}
@@ -69,6 +69,7 @@ type Service struct {
 	tracer                    tracing.Tracer
 	metrics                   *metrics.ExprMetrics
 	qsDatasourceClientBuilder dsquerierclient.QSDatasourceClientBuilder
+	resultCache               *resultCache
 }
 
 type pluginContextProvider interface {
@@ -78,19 +79,21 @@ type pluginContextProvider interface {
 
 func ProvideService(cfg *setting.Cfg, pluginClient plugins.Client, pCtxProvider *plugincontext.Provider,
 	features featuremgmt.FeatureToggles, registerer prometheus.Registerer, tracer tracing.Tracer, builder dsquerierclient.QSDatasourceClientBuilder) *Service {
+	m := metrics.NewSSEMetrics(registerer)
 	return &Service{
 		cfg:           cfg,
 		dataService:   pluginClient,
 		pCtxProvider:  pCtxProvider,
 		features:      features,
 		tracer:        tracer,
-		metrics:       metrics.NewSSEMetrics(registerer),
+		metrics:       m,
 		pluginsClient: pluginClient,
 		converter: &ResultConverter{
 			Features: features,
 			Tracer:   tracer,
 		},
 		qsDatasourceClientBuilder: builder,
+		resultCache:               newResultCache(cfg.ExpressionsResultCacheTTL, cfg.ExpressionsResultCacheMaxEntries, m),
 	}
 }
 
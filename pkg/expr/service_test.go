@@ -148,6 +148,70 @@ func TestDSQueryError(t *testing.T) {
 	require.Equal(t, fp(42), res.Responses["C"].Frames[0].Fields[0].At(0))
 }
 
+func TestPipelineTrace(t *testing.T) {
+	resp := map[string]backend.DataResponse{
+		"A": {Error: fmt.Errorf("womp womp")},
+	}
+
+	queries := []Query{
+		{
+			RefID: "A",
+			DataSource: &datasources.DataSource{
+				OrgID: 1,
+				UID:   "test",
+				Type:  "test",
+			},
+			JSON: json.RawMessage(`{ "datasource": { "uid": "1" }, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+			TimeRange: AbsoluteTimeRange{
+				From: time.Time{},
+				To:   time.Time{},
+			},
+		},
+		{
+			RefID:      "B",
+			DataSource: dataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$A * 2" }`),
+		},
+		{
+			RefID:      "C",
+			DataSource: dataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "42" }`),
+		},
+	}
+
+	s, req := newMockQueryService(resp, queries)
+
+	pl, err := s.BuildPipeline(t.Context(), req)
+	require.NoError(t, err)
+
+	trace := &PipelineTrace{}
+	_, err = s.ExecutePipeline(WithPipelineTrace(context.Background(), trace), time.Now(), pl)
+	require.NoError(t, err)
+
+	nodes := map[string]NodeTrace{}
+	for _, n := range trace.Nodes() {
+		nodes[n.RefID] = n
+	}
+	require.Len(t, nodes, 3)
+
+	require.Equal(t, "Datasource", nodes["A"].NodeType)
+	require.Equal(t, "test", nodes["A"].Kind)
+	require.ErrorContains(t, nodes["A"].Error, "womp womp")
+	require.False(t, nodes["A"].Skipped)
+
+	require.Equal(t, "Expression", nodes["B"].NodeType)
+	require.Equal(t, "math", nodes["B"].Kind)
+	require.Equal(t, []string{"A"}, nodes["B"].Inputs)
+	require.True(t, nodes["B"].Skipped)
+	var utilErr errutil.Error
+	require.ErrorAs(t, nodes["B"].Error, &utilErr)
+	require.ErrorIs(t, utilErr, DependencyError)
+
+	require.NoError(t, nodes["C"].Error)
+	require.False(t, nodes["C"].Skipped)
+	require.Empty(t, nodes["C"].Inputs)
+}
+
 func TestSQLExpressionCellLimitFromConfig(t *testing.T) {
 	tests := []struct {
 		name            string
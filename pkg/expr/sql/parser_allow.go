@@ -190,6 +190,8 @@ func allowedFunction(f *sqlparser.FuncExpr) (b bool) {
 		return
 	case "row_number", "rank", "dense_rank", "lead", "lag":
 		return
+	case "ntile", "percent_rank":
+		return
 	case "first_value", "last_value":
 		return
 
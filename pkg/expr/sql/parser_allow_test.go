@@ -353,5 +353,8 @@ SELECT
   LEAD(val) OVER (ORDER BY val) as lead_val,
   LAG(val) OVER (ORDER BY val) as lag_val,
   FIRST_VALUE(val) OVER (ORDER BY val) as first_val,
-  LAST_VALUE(val) OVER (ORDER BY val ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING) as last_val
+  LAST_VALUE(val) OVER (ORDER BY val ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING) as last_val,
+  NTILE(2) OVER (ORDER BY val) as ntile_val,
+  PERCENT_RANK() OVER (ORDER BY val) as percent_rank_val,
+  SUM(val) OVER (PARTITION BY txt ORDER BY val ROWS BETWEEN 1 PRECEDING AND CURRENT ROW) as moving_sum
 FROM dummy_data;`
@@ -0,0 +1,197 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+// StreamPipeline evaluates the expressions of a pipeline every time frames are received from the
+// stream of one of its datasource queries. The other datasource queries are executed once, when the
+// pipeline is built, and the frames of the stream are kept for a time window.
+type StreamPipeline struct {
+	s        *Service
+	pipeline DataPipeline
+	node     *DSNode
+	window   time.Duration
+
+	mu sync.Mutex
+	// frames are the frames received from the stream, by schema
+	frames map[string]*data.Frame
+	keys   []string
+	// vars are the results of the datasource queries not streamed
+	vars mathexp.Vars
+}
+
+// BuildStreamPipeline builds a pipeline from a request, evaluating its expressions on the frames of the
+// stream of the datasource query refID received during the last window.
+func (s *Service) BuildStreamPipeline(ctx context.Context, now time.Time, req *Request, refID string, window time.Duration) (*StreamPipeline, error) {
+	if s.isDisabled() {
+		return nil, fmt.Errorf("server side expressions are disabled")
+	}
+	if window <= 0 {
+		return nil, fmt.Errorf("the stream window must be positive, got %s", window)
+	}
+
+	pipeline, err := s.buildPipeline(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	sp := &StreamPipeline{
+		s:        s,
+		pipeline: pipeline,
+		window:   window,
+		frames:   map[string]*data.Frame{},
+		vars:     mathexp.Vars{},
+	}
+	var dsNodes []Node
+	for _, node := range pipeline {
+		dn, ok := node.(*DSNode)
+		if !ok {
+			continue
+		}
+		if dn.refID == refID {
+			sp.node = dn
+			continue
+		}
+		dsNodes = append(dsNodes, dn)
+	}
+	if sp.node == nil {
+		return nil, fmt.Errorf("the streaming query %q is not a datasource query of the request", refID)
+	}
+
+	if err := executeNodes(ctx, now, sp.vars, s, dsNodes); err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
+
+// Datasource returns the datasource of the streaming query.
+func (sp *StreamPipeline) Datasource() *datasources.DataSource {
+	return sp.node.datasource
+}
+
+// RefID returns the refID of the streaming query.
+func (sp *StreamPipeline) RefID() string {
+	return sp.node.refID
+}
+
+// Update adds a frame received from the stream and evaluates the expressions of the pipeline on the
+// frames of the window ending at now. It returns the results of all the nodes of the pipeline.
+func (sp *StreamPipeline) Update(ctx context.Context, now time.Time, frame *data.Frame) (*backend.QueryDataResponse, error) {
+	ctx, span := sp.s.tracer.Start(ctx, "SSE.UpdateStreamPipeline")
+	defer span.End()
+
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	sp.add(frame, now.Add(-sp.window))
+	frames := make(data.Frames, 0, len(sp.keys))
+	for _, key := range sp.keys {
+		frames = append(frames, sp.frames[key])
+	}
+
+	vars := make(mathexp.Vars, len(sp.vars)+1)
+	for refID, res := range sp.vars {
+		vars[refID] = res
+	}
+	_, res, err := sp.s.converter.Convert(ctx, sp.node.datasource.Type, frames, sp.node.isInputToSQLExpr)
+	if err != nil {
+		res.Error = makeConversionError(sp.node.refID, err)
+	}
+	vars[sp.node.refID] = res
+
+	nodes := make([]Node, 0, len(sp.pipeline))
+	for _, node := range sp.pipeline {
+		if node.NodeType() != TypeDatasourceNode {
+			nodes = append(nodes, node)
+		}
+	}
+	if err := executeNodes(ctx, now, vars, sp.s, nodes); err != nil {
+		return nil, err
+	}
+
+	resp := backend.NewQueryDataResponse()
+	for refID, val := range vars {
+		resp.Responses[refID] = backend.DataResponse{
+			Frames: val.Values.AsDataFrames(refID),
+			Error:  val.Error,
+		}
+	}
+	return resp, nil
+}
+
+// add appends the rows of the frame to the frame with the same schema, and removes the rows before
+// the start of the window. A frame replaces the frame with the same name and fields of another type.
+func (sp *StreamPipeline) add(frame *data.Frame, start time.Time) {
+	key := streamFrameKey(frame)
+	current, ok := sp.frames[key]
+	if !ok {
+		sp.keys = append(sp.keys, key)
+	}
+	if !ok || !sameFieldTypes(current, frame) {
+		current = frame.EmptyCopy()
+	}
+	rows, _ := frame.RowLen()
+	for i := 0; i < rows; i++ {
+		current.AppendRow(frame.RowCopy(i)...)
+	}
+	current.RefID = sp.node.refID
+	sp.frames[key] = trimFrame(current, start)
+}
+
+func streamFrameKey(frame *data.Frame) string {
+	var b strings.Builder
+	b.WriteString(frame.Name)
+	for _, f := range frame.Fields {
+		b.WriteString("\x00")
+		b.WriteString(f.Name)
+		b.WriteString(f.Labels.String())
+	}
+	return b.String()
+}
+
+func sameFieldTypes(a, b *data.Frame) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Type() != b.Fields[i].Type() {
+			return false
+		}
+	}
+	return true
+}
+
+// trimFrame removes the rows of the frame before start, using the first time field of the frame.
+func trimFrame(frame *data.Frame, start time.Time) *data.Frame {
+	var timeField *data.Field
+	for _, f := range frame.Fields {
+		if f.Type().Time() {
+			timeField = f
+			break
+		}
+	}
+	if timeField == nil {
+		return frame
+	}
+
+	trimmed := frame.EmptyCopy()
+	for i := 0; i < timeField.Len(); i++ {
+		t, ok := timeField.ConcreteAt(i)
+		if !ok || t.(time.Time).Before(start) {
+			continue
+		}
+		trimmed.AppendRow(frame.RowCopy(i)...)
+	}
+	return trimmed
+}
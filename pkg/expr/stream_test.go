@@ -0,0 +1,80 @@
+package expr
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+func TestStreamPipeline(t *testing.T) {
+	resp := map[string]backend.DataResponse{
+		"C": {Frames: data.Frames{data.NewFrame("", data.NewField("value", nil, []*float64{fp(10)}))}},
+	}
+
+	queries := []Query{
+		{
+			RefID:      "A",
+			DataSource: &datasources.DataSource{OrgID: 1, UID: "stream", Type: "test"},
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "stream" }, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+		},
+		{
+			RefID:      "C",
+			DataSource: &datasources.DataSource{OrgID: 1, UID: "test", Type: "test"},
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "test" }, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+		},
+		{
+			RefID:      "B",
+			DataSource: dataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$A + $C" }`),
+		},
+	}
+
+	s, req := newMockQueryService(resp, queries)
+
+	t.Run("should fail when the streaming query is not a datasource query", func(t *testing.T) {
+		_, err := s.BuildStreamPipeline(t.Context(), time.Now(), req, "B", time.Minute)
+		require.Error(t, err)
+	})
+
+	t.Run("should fail without a window", func(t *testing.T) {
+		_, err := s.BuildStreamPipeline(t.Context(), time.Now(), req, "A", 0)
+		require.Error(t, err)
+	})
+
+	sp, err := s.BuildStreamPipeline(t.Context(), time.Now(), req, "A", 10*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, "stream", sp.Datasource().UID)
+	require.Equal(t, "A", sp.RefID())
+
+	update := func(now time.Time, value float64) []float64 {
+		t.Helper()
+		frame := data.NewFrame("stream",
+			data.NewField("time", nil, []time.Time{now}),
+			data.NewField("value", nil, []*float64{fp(value)}),
+		)
+		res, err := sp.Update(t.Context(), now, frame)
+		require.NoError(t, err)
+		require.NoError(t, res.Responses["B"].Error)
+		require.Len(t, res.Responses["B"].Frames, 1)
+
+		values := []float64{}
+		field := res.Responses["B"].Frames[0].Fields[1]
+		for i := 0; i < field.Len(); i++ {
+			v, err := field.NullableFloatAt(i)
+			require.NoError(t, err)
+			values = append(values, *v)
+		}
+		return values
+	}
+
+	start := time.Unix(100, 0)
+	require.Equal(t, []float64{11}, update(start, 1))
+	require.Equal(t, []float64{11, 12}, update(start.Add(5*time.Second), 2))
+	require.Equal(t, []float64{12, 13}, update(start.Add(12*time.Second), 3), "the frames before the window should be removed")
+}
@@ -25,6 +25,10 @@ type DB interface {
 	// through [context.Context] or if that's not present, as non-transactional database
 	// operations.
 	WithDbSession(ctx context.Context, callback sqlstore.DBTransactionFunc) error
+	// WithReadReplicaSession runs read-only database operations on a read replica when
+	// one is configured and fresh enough, and otherwise like [DB.WithDbSession].
+	// Use [ForcePrimary] on the context for the reads which must see the latest writes.
+	WithReadReplicaSession(ctx context.Context, callback sqlstore.DBTransactionFunc) error
 	// GetDialect returns an object that contains information about the peculiarities of
 	// the particular database type available to the runtime.
 	GetDialect() migrator.Dialect
@@ -54,6 +58,7 @@ type InitTestDBOpt = sqlstore.InitTestDBOpt
 var SetupTestDB = sqlstore.SetupTestDB
 var CleanupTestDB = sqlstore.CleanupTestDB
 var ProvideService = sqlstore.ProvideService
+var ForcePrimary = sqlstore.ForcePrimary
 
 func InitTestDB(t sqlutil.ITestDB, opts ...InitTestDBOpt) *sqlstore.SQLStore {
 	db, _ := InitTestDBWithCfg(t, opts...)
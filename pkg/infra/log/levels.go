@@ -0,0 +1,118 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+)
+
+var ErrUnknownLevel = errors.New("unknown log level")
+
+// LevelOverride is the level of a named logger set at runtime. It takes precedence over the level and the filters of
+// the log modes until it's removed or expires.
+type LevelOverride struct {
+	Logger string `json:"logger"`
+	Level  string `json:"level"`
+	// Expires is when the configured level is restored, unset when it's kept until the override is removed
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+type levelOverride struct {
+	LevelOverride
+	option level.Option
+	timer  *time.Timer
+}
+
+// SetLevelOverride sets the level of the named logger, the configured level is restored after the duration when it's
+// positive. The override applies to the loggers created later with the name too.
+func SetLevelOverride(name string, levelName string, duration time.Duration) (LevelOverride, error) {
+	return root.setLevelOverride(name, levelName, duration)
+}
+
+// RemoveLevelOverride restores the configured level of the named logger, it returns false when the level of the logger
+// wasn't overridden.
+func RemoveLevelOverride(name string) bool {
+	return root.removeLevelOverride(name, nil)
+}
+
+// LevelOverrides returns the levels set at runtime sorted by logger name.
+func LevelOverrides() []LevelOverride {
+	root.mutex.RLock()
+	defer root.mutex.RUnlock()
+
+	overrides := make([]LevelOverride, 0, len(root.levelOverrides))
+	for _, override := range root.levelOverrides {
+		overrides = append(overrides, override.LevelOverride)
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		return overrides[i].Logger < overrides[j].Logger
+	})
+	return overrides
+}
+
+// LoggerNames returns the sorted names of the loggers created with New.
+func LoggerNames() []string {
+	root.mutex.RLock()
+	defer root.mutex.RUnlock()
+
+	names := make([]string, 0, len(root.loggersByName))
+	for name := range root.loggersByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (lm *logManager) setLevelOverride(name string, levelName string, duration time.Duration) (LevelOverride, error) {
+	levelName = strings.ToLower(levelName)
+	option, exists := logLevels[levelName]
+	if !exists {
+		return LevelOverride{}, fmt.Errorf("%w: %q", ErrUnknownLevel, levelName)
+	}
+
+	lm.mutex.Lock()
+	defer lm.mutex.Unlock()
+
+	if previous, exists := lm.levelOverrides[name]; exists && previous.timer != nil {
+		previous.timer.Stop()
+	}
+
+	override := &levelOverride{
+		LevelOverride: LevelOverride{Logger: name, Level: levelName},
+		option:        option,
+	}
+	if duration > 0 {
+		expires := now().Add(duration)
+		override.Expires = &expires
+		override.timer = time.AfterFunc(duration, func() {
+			lm.removeLevelOverride(name, override)
+		})
+	}
+	lm.levelOverrides[name] = override
+	lm.swapNamedLogger(name)
+
+	return override.LevelOverride, nil
+}
+
+// removeLevelOverride removes the override of the named logger, when expired is set it's only removed if it wasn't
+// replaced since
+func (lm *logManager) removeLevelOverride(name string, expired *levelOverride) bool {
+	lm.mutex.Lock()
+	defer lm.mutex.Unlock()
+
+	override, exists := lm.levelOverrides[name]
+	if !exists || (expired != nil && override != expired) {
+		return false
+	}
+	if override.timer != nil {
+		override.timer.Stop()
+	}
+
+	delete(lm.levelOverrides, name)
+	lm.swapNamedLogger(name)
+	return true
+}
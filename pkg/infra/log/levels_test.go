@@ -0,0 +1,113 @@
+package log
+
+import (
+	"testing"
+	"time"
+
+	gokitlog "github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelOverrides(t *testing.T) {
+	setup := func(t *testing.T) *[][]any {
+		newLoggerScenario(t)
+
+		loggedArgs := [][]any{}
+		root.initialize([]logWithFilters{
+			{
+				val: gokitlog.LoggerFunc(func(i ...any) error {
+					loggedArgs = append(loggedArgs, i)
+					return nil
+				}),
+				filters:  map[string]level.Option{"filtered": level.AllowError()},
+				maxLevel: level.AllowInfo(),
+			},
+		})
+		return &loggedArgs
+	}
+
+	t.Run("should apply the level to the existing loggers until it's removed", func(t *testing.T) {
+		loggedArgs := setup(t)
+		logger := New("plugins.backend")
+
+		logger.Debug("before")
+		_, err := SetLevelOverride("plugins.backend", "debug", 0)
+		require.NoError(t, err)
+		logger.Debug("during")
+		require.True(t, RemoveLevelOverride("plugins.backend"))
+		logger.Debug("after")
+
+		require.Len(t, *loggedArgs, 1)
+		assert.Contains(t, (*loggedArgs)[0], "during")
+		assert.False(t, RemoveLevelOverride("plugins.backend"))
+	})
+
+	t.Run("should apply the level to the loggers created later", func(t *testing.T) {
+		loggedArgs := setup(t)
+
+		_, err := SetLevelOverride("later", "error", 0)
+		require.NoError(t, err)
+		logger := New("later")
+		logger.Info("info")
+		logger.Error("error")
+
+		require.Len(t, *loggedArgs, 1)
+		assert.Contains(t, (*loggedArgs)[0], "error")
+		assert.Contains(t, LoggerNames(), "later")
+	})
+
+	t.Run("should take precedence over the filters", func(t *testing.T) {
+		loggedArgs := setup(t)
+		logger := New("filtered")
+
+		logger.Info("filtered out")
+		_, err := SetLevelOverride("filtered", "info", 0)
+		require.NoError(t, err)
+		logger.Info("logged")
+
+		require.Len(t, *loggedArgs, 1)
+		assert.Contains(t, (*loggedArgs)[0], "logged")
+	})
+
+	t.Run("should restore the configured level when the override expires", func(t *testing.T) {
+		loggedArgs := setup(t)
+		logger := New("expiring")
+
+		override, err := SetLevelOverride("expiring", "DEBUG", 10*time.Millisecond)
+		require.NoError(t, err)
+		assert.Equal(t, "debug", override.Level)
+		require.NotNil(t, override.Expires)
+		require.Len(t, LevelOverrides(), 1)
+
+		assert.Eventually(t, func() bool {
+			return len(LevelOverrides()) == 0
+		}, time.Second, 5*time.Millisecond)
+		logger.Debug("debug")
+		assert.Empty(t, *loggedArgs)
+	})
+
+	t.Run("should not remove an override replaced before the previous one expires", func(t *testing.T) {
+		setup(t)
+
+		_, err := SetLevelOverride("replaced", "debug", 10*time.Millisecond)
+		require.NoError(t, err)
+		_, err = SetLevelOverride("replaced", "warn", 0)
+		require.NoError(t, err)
+
+		time.Sleep(30 * time.Millisecond)
+		overrides := LevelOverrides()
+		require.Len(t, overrides, 1)
+		assert.Equal(t, "warn", overrides[0].Level)
+		assert.Nil(t, overrides[0].Expires)
+	})
+
+	t.Run("should reject unknown levels", func(t *testing.T) {
+		setup(t)
+
+		_, err := SetLevelOverride("plugins.backend", "verbose", 0)
+		assert.ErrorIs(t, err, ErrUnknownLevel)
+		assert.Empty(t, LevelOverrides())
+	})
+}
@@ -70,13 +70,16 @@ type logManager struct {
 	*ConcreteLogger
 	loggersByName map[string]*ConcreteLogger
 	logFilters    []logWithFilters
-	mutex         sync.RWMutex
+	// levelOverrides are the levels of the named loggers set at runtime
+	levelOverrides map[string]*levelOverride
+	mutex          sync.RWMutex
 }
 
 func newManager(logger gokitlog.Logger) *logManager {
 	return &logManager{
 		ConcreteLogger: newConcreteLogger(logger),
 		loggersByName:  map[string]*ConcreteLogger{},
+		levelOverrides: map[string]*levelOverride{},
 	}
 }
 
@@ -99,23 +102,40 @@ func (lm *logManager) initialize(loggers []logWithFilters) {
 	sort.Strings(loggersByName)
 
 	for _, name := range loggersByName {
-		ctxLoggers := make([]gokitlog.Logger, len(loggers))
-
-		for index, logger := range loggers {
-			ctxLogger := gokitlog.With(logger.val, lm.loggersByName[name].ctx...)
-			if filterLevel, exists := logger.filters[name]; !exists {
-				ctxLoggers[index] = level.NewFilter(ctxLogger, logger.maxLevel)
-			} else {
-				ctxLoggers[index] = level.NewFilter(ctxLogger, filterLevel)
-			}
-		}
-
-		lm.loggersByName[name].Swap(&compositeLogger{loggers: ctxLoggers})
+		lm.swapNamedLogger(name)
 	}
 
 	initAppSDKLogger(lm.ConcreteLogger)
 }
 
+// swapNamedLogger rebuilds the logger of the name from the log modes, the caller must hold the lock
+func (lm *logManager) swapNamedLogger(name string) {
+	namedLogger, exists := lm.loggersByName[name]
+	if !exists || len(lm.logFilters) == 0 {
+		return
+	}
+
+	ctxLoggers := make([]gokitlog.Logger, len(lm.logFilters))
+	for index, logger := range lm.logFilters {
+		ctxLogger := gokitlog.With(logger.val, namedLogger.ctx...)
+		ctxLoggers[index] = level.NewFilter(ctxLogger, lm.levelFor(name, logger))
+	}
+
+	namedLogger.Swap(&compositeLogger{loggers: ctxLoggers})
+}
+
+// levelFor returns the level of the named logger in a log mode, a level set at runtime takes precedence over the
+// filters and the level of the mode
+func (lm *logManager) levelFor(name string, logger logWithFilters) level.Option {
+	if override, exists := lm.levelOverrides[name]; exists {
+		return override.option
+	}
+	if filterLevel, exists := logger.filters[name]; exists {
+		return filterLevel
+	}
+	return logger.maxLevel
+}
+
 func (lm *logManager) New(ctx ...any) *ConcreteLogger {
 	// First key-value could be "logger" and a logger name, that would be handled differently
 	// to allow per-logger filtering. Otherwise a simple concrete logger is returned.
@@ -152,12 +172,7 @@ func (lm *logManager) New(ctx ...any) *ConcreteLogger {
 
 	compositeLogger := newCompositeLogger()
 	for _, logWithFilter := range lm.logFilters {
-		filterLevel, ok := logWithFilter.filters[loggerName]
-		if ok {
-			logWithFilter.val = level.NewFilter(logWithFilter.val, filterLevel)
-		} else {
-			logWithFilter.val = level.NewFilter(logWithFilter.val, logWithFilter.maxLevel)
-		}
+		logWithFilter.val = level.NewFilter(logWithFilter.val, lm.levelFor(loggerName, logWithFilter))
 
 		compositeLogger.loggers = append(compositeLogger.loggers, logWithFilter.val)
 	}
@@ -513,6 +528,9 @@ func ReadLoggingConfig(modes []string, logsPath string, cfg *ini.File) error {
 
 		configLoggers = append(configLoggers, handler)
 	}
+	if handler, enabled := readMemoryLogConfig(cfg, defaultLevelName, defaultFilters); enabled {
+		configLoggers = append(configLoggers, handler)
+	}
 	if len(configLoggers) > 0 {
 		root.initialize(configLoggers)
 	}
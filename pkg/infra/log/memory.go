@@ -0,0 +1,191 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// memory keeps the recent log entries, it's nil when the in-memory log is disabled
+var memory *memoryLogger
+
+// Entry is a log entry kept in memory
+type Entry struct {
+	// ID increases with each entry, it's the cursor to get the entries logged after this one
+	ID      int64             `json:"id"`
+	Time    time.Time         `json:"time"`
+	Level   string            `json:"level,omitempty"`
+	Logger  string            `json:"logger,omitempty"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// EntryQuery filters the entries kept in memory
+type EntryQuery struct {
+	// After is the ID of the last entry already read
+	After int64
+	// Level is the minimum level of the entries
+	Level string
+	// Logger is a prefix of the logger name
+	Logger string
+	// Query is a text the message or a field value contains
+	Query string
+	// Limit is the maximum number of entries, the most recent ones are returned
+	Limit int
+}
+
+// RecentEntries returns the entries kept in memory that match the query, oldest first. It returns false when the
+// in-memory log is disabled.
+func RecentEntries(query EntryQuery) ([]Entry, bool) {
+	m := memory
+	if m == nil {
+		return nil, false
+	}
+	return m.search(query), true
+}
+
+var levelSeverities = map[string]int{
+	"trace":    0,
+	"debug":    0,
+	"info":     1,
+	"warn":     2,
+	"error":    3,
+	"critical": 3,
+}
+
+// memoryLogger keeps the most recent entries in a ring buffer
+type memoryLogger struct {
+	mu      sync.Mutex
+	entries []Entry
+	// next is the index of the next entry of the buffer
+	next   int
+	count  int
+	lastID int64
+}
+
+func newMemoryLogger(size int) *memoryLogger {
+	return &memoryLogger{entries: make([]Entry, size)}
+}
+
+func (m *memoryLogger) Log(keyvals ...any) error {
+	entry := Entry{Time: now()}
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		var value any = "(MISSING)"
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+
+		switch key {
+		case "t":
+			// the entry has the time it was kept at
+		case fmt.Sprint(level.Key()):
+			entry.Level = fmt.Sprint(value)
+		case "logger":
+			entry.Logger = fmt.Sprint(value)
+		case "msg":
+			entry.Message = fmt.Sprint(value)
+		default:
+			if entry.Fields == nil {
+				entry.Fields = map[string]string{}
+			}
+			entry.Fields[key] = formatValue(value)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastID++
+	entry.ID = m.lastID
+	m.entries[m.next] = entry
+	m.next = (m.next + 1) % len(m.entries)
+	if m.count < len(m.entries) {
+		m.count++
+	}
+	return nil
+}
+
+func (m *memoryLogger) search(query EntryQuery) []Entry {
+	minSeverity := levelSeverities[strings.ToLower(query.Level)]
+	text := strings.ToLower(query.Query)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// the entries are read from the most recent one so that the limit keeps the most recent ones
+	entries := []Entry{}
+	for i := 1; i <= m.count; i++ {
+		entry := m.entries[(m.next-i+len(m.entries))%len(m.entries)]
+		if entry.ID <= query.After || (query.Limit > 0 && len(entries) >= query.Limit) {
+			break
+		}
+		if levelSeverities[entry.Level] < minSeverity || !strings.HasPrefix(entry.Logger, query.Logger) {
+			continue
+		}
+		if text != "" && !entry.contains(text) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries
+}
+
+// contains returns true when the message or a field value of the entry contains the lower case text
+func (e Entry) contains(text string) bool {
+	if strings.Contains(strings.ToLower(e.Message), text) {
+		return true
+	}
+	for _, value := range e.Fields {
+		if strings.Contains(strings.ToLower(value), text) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// readMemoryLogConfig returns the log mode keeping the recent entries in memory, it returns false when it's disabled
+func readMemoryLogConfig(cfg *ini.File, defaultLevelName string, defaultFilters map[string]level.Option) (logWithFilters, bool) {
+	sec := cfg.Section("log.memory")
+	maxEntries := sec.Key("max_entries").MustInt(1000)
+	if !sec.Key("enabled").MustBool(true) || maxEntries <= 0 {
+		memory = nil
+		return logWithFilters{}, false
+	}
+
+	_, leveloption := getLogLevelFromConfig("log.memory", defaultLevelName, cfg)
+	modeFilters := getFilters(util.SplitString(sec.Key("filters").String()))
+	for key, value := range defaultFilters {
+		if _, exist := modeFilters[key]; !exist {
+			modeFilters[key] = value
+		}
+	}
+
+	memory = newMemoryLogger(maxEntries)
+	return logWithFilters{
+		val:      memory,
+		filters:  modeFilters,
+		maxLevel: leveloption,
+	}, true
+}
@@ -0,0 +1,67 @@
+package log
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestMemoryLogger(t *testing.T) {
+	t.Run("should keep the most recent entries", func(t *testing.T) {
+		m := newMemoryLogger(3)
+		for _, msg := range []string{"one", "two", "three", "four"} {
+			require.NoError(t, m.Log("logger", "test", "level", "info", "msg", msg))
+		}
+
+		entries := m.search(EntryQuery{})
+		require.Len(t, entries, 3)
+		assert.Equal(t, "two", entries[0].Message)
+		assert.Equal(t, "four", entries[2].Message)
+		assert.Equal(t, int64(4), entries[2].ID)
+	})
+
+	t.Run("should filter the entries", func(t *testing.T) {
+		m := newMemoryLogger(10)
+		require.NoError(t, m.Log("logger", "plugins.backend", "level", "debug", "msg", "Starting plugin", "pluginId", "loki"))
+		require.NoError(t, m.Log("logger", "plugins.backend", "level", "error", "msg", "Plugin failed", "error", errors.New("exit status 1")))
+		require.NoError(t, m.Log("logger", "sqlstore", "level", "warn", "msg", "Slow query"))
+
+		entries := m.search(EntryQuery{Level: "warn"})
+		require.Len(t, entries, 2)
+		assert.Equal(t, "Plugin failed", entries[0].Message)
+		assert.Equal(t, "exit status 1", entries[0].Fields["error"])
+
+		entries = m.search(EntryQuery{Logger: "plugins"})
+		assert.Len(t, entries, 2)
+
+		entries = m.search(EntryQuery{Query: "LOKI"})
+		require.Len(t, entries, 1)
+		assert.Equal(t, "Starting plugin", entries[0].Message)
+
+		entries = m.search(EntryQuery{After: 1, Limit: 1})
+		require.Len(t, entries, 1)
+		assert.Equal(t, "Slow query", entries[0].Message)
+	})
+}
+
+func TestReadMemoryLogConfig(t *testing.T) {
+	t.Cleanup(func() {
+		memory = nil
+	})
+
+	cfg := ini.Empty()
+	_, enabled := readMemoryLogConfig(cfg, "info", nil)
+	assert.True(t, enabled)
+	_, ok := RecentEntries(EntryQuery{})
+	assert.True(t, ok)
+
+	_, err := cfg.Section("log.memory").NewKey("enabled", "false")
+	require.NoError(t, err)
+	_, enabled = readMemoryLogConfig(cfg, "info", nil)
+	assert.False(t, enabled)
+	_, ok = RecentEntries(EntryQuery{})
+	assert.False(t, ok)
+}
@@ -93,6 +93,21 @@ var (
 	// MRenderingQueue is a metric gauge for image rendering queue size
 	MRenderingQueue prometheus.Gauge
 
+	// MRenderingQueueWaiting is a metric gauge for image rendering requests waiting for a slot
+	MRenderingQueueWaiting prometheus.Gauge
+
+	// MRenderingServerUp is a metric gauge for the health of the remote image rendering services
+	MRenderingServerUp *prometheus.GaugeVec
+
+	// MRenderingStrategyUp is a metric gauge for the health of the rendering strategies
+	MRenderingStrategyUp *prometheus.GaugeVec
+
+	// MRenderingStrategyRequestTotal is a metric counter for the renders of each rendering strategy
+	MRenderingStrategyRequestTotal *prometheus.CounterVec
+
+	// MRenderingCacheUsage is a metric counter for the render cache usage
+	MRenderingCacheUsage *prometheus.CounterVec
+
 	// MAccessEvaluationCount is a metric gauge for total number of evaluation requests
 	MAccessEvaluationCount prometheus.Counter
 
@@ -113,6 +128,12 @@ var (
 
 	// MFolderIDsServicesCount is a metric counter for folder ids count in the services package
 	MFolderIDsServiceCount *prometheus.CounterVec
+
+	// MQuotaLimitReached is a metric counter for the requests rejected by a quota
+	MQuotaLimitReached *prometheus.CounterVec
+
+	// MQuotaSoftLimitReached is a metric counter for the notifications of the quotas whose usage reached the soft limit
+	MQuotaSoftLimitReached *prometheus.CounterVec
 )
 
 // Timers
@@ -129,6 +150,15 @@ var (
 	// MRenderingUserLookupSummary is a metric summary for image rendering user lookup duration
 	MRenderingUserLookupSummary *prometheus.SummaryVec
 
+	// MRenderingQueueWaitSummary is a metric summary for the time image rendering requests wait for a slot
+	MRenderingQueueWaitSummary *prometheus.SummaryVec
+
+	// MRenderingServerSummary is a metric summary for the request duration of each remote image rendering service
+	MRenderingServerSummary *prometheus.SummaryVec
+
+	// MRenderingStrategySummary is a metric summary for the render duration of each rendering strategy
+	MRenderingStrategySummary *prometheus.SummaryVec
+
 	// MAccessPermissionsSummary is a metric summary for loading permissions request duration when evaluating access
 	MAccessPermissionsSummary prometheus.Histogram
 
@@ -435,6 +465,69 @@ func init() {
 		Namespace: ExporterName,
 	})
 
+	MRenderingQueueWaiting = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "rendering_queue_waiting",
+		Help:      "number of rendering requests waiting for a slot",
+		Namespace: ExporterName,
+	})
+
+	MRenderingQueueWaitSummary = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Name:       "rendering_queue_wait_duration_milliseconds",
+			Help:       "summary of the time rendering requests wait for a slot",
+			Objectives: objectiveMap,
+			Namespace:  ExporterName,
+		},
+		[]string{"result"},
+	)
+
+	MRenderingServerUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "rendering_server_up",
+		Help:      "1 if the remote rendering service is healthy, 0 otherwise",
+		Namespace: ExporterName,
+	}, []string{"url"})
+
+	MRenderingServerSummary = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Name:       "rendering_server_request_duration_milliseconds",
+			Help:       "summary of rendering request duration by remote rendering service",
+			Objectives: objectiveMap,
+			Namespace:  ExporterName,
+		},
+		[]string{"url", "status"},
+	)
+
+	MRenderingStrategyUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "rendering_strategy_up",
+		Help:      "1 if the rendering strategy is used, 0 if it's skipped after failing",
+		Namespace: ExporterName,
+	}, []string{"strategy"})
+
+	MRenderingStrategyRequestTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:      "rendering_strategy_request_total",
+			Help:      "counter for the renders of each rendering strategy",
+			Namespace: ExporterName,
+		},
+		[]string{"strategy", "status", "type"},
+	)
+
+	MRenderingStrategySummary = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Name:       "rendering_strategy_request_duration_milliseconds",
+			Help:       "summary of rendering request duration by rendering strategy",
+			Objectives: objectiveMap,
+			Namespace:  ExporterName,
+		},
+		[]string{"strategy", "status"},
+	)
+
+	MRenderingCacheUsage = metricutil.NewCounterVecStartingAtZero(prometheus.CounterOpts{
+		Name:      "rendering_cache_usage",
+		Help:      "render cache hit/miss",
+		Namespace: ExporterName,
+	}, []string{"status"}, map[string][]string{"status": {"hit", "miss"}})
+
 	MDataSourceProxyReqTimer = prometheus.NewSummary(prometheus.SummaryOpts{
 		Name:       "api_dataproxy_request_all_milliseconds",
 		Help:       "summary for dataproxy request duration",
@@ -479,6 +572,18 @@ func init() {
 		Namespace: ExporterName,
 	}, []string{"service"}, map[string][]string{"service": folderIDServices})
 
+	MQuotaLimitReached = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "quota_limit_reached_total",
+		Help:      "counter for the requests rejected by a quota labelled by target and scope",
+		Namespace: ExporterName,
+	}, []string{"target", "scope"})
+
+	MQuotaSoftLimitReached = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "quota_soft_limit_reached_total",
+		Help:      "counter for the notifications of the quotas whose usage reached the soft limit labelled by target and scope",
+		Namespace: ExporterName,
+	}, []string{"target", "scope"})
+
 	MStatTotalDashboards = prometheus.NewGauge(prometheus.GaugeOpts{
 		Name:      "stat_totals_dashboard",
 		Help:      "total amount of dashboards",
@@ -745,6 +850,14 @@ func initMetricVars(reg prometheus.Registerer) {
 		MRenderingSummary,
 		MRenderingUserLookupSummary,
 		MRenderingQueue,
+		MRenderingQueueWaiting,
+		MRenderingQueueWaitSummary,
+		MRenderingServerUp,
+		MRenderingServerSummary,
+		MRenderingStrategyUp,
+		MRenderingStrategyRequestTotal,
+		MRenderingStrategySummary,
+		MRenderingCacheUsage,
 		MAccessPermissionsSummary,
 		MAccessEvaluationsSummary,
 		MAccessSearchPermissionsSummary,
@@ -780,5 +893,7 @@ func initMetricVars(reg prometheus.Registerer) {
 		MStatTotalCorrelations,
 		MFolderIDsAPICount,
 		MFolderIDsServiceCount,
+		MQuotaLimitReached,
+		MQuotaSoftLimitReached,
 	)
 }
@@ -0,0 +1,206 @@
+package metrics
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// otherTenant is the label of the organizations and teams over the limits of the settings
+	otherTenant = "other"
+	// noTeam is the team label of the users who aren't a member of a labeled team
+	noTeam = "none"
+)
+
+// TenantMetrics are the metrics of the API requests labeled by organization, and optionally by team, so that the
+// operators of a multi-tenant instance can build per-tenant SLOs. The number of label values is bounded by the
+// settings, the requests of the organizations and teams that aren't labeled are counted as other.
+type TenantMetrics struct {
+	cfg setting.TenantMetricsSettings
+
+	requests    *prometheus.CounterVec
+	errors      *prometheus.CounterVec
+	queries     *prometheus.CounterVec
+	activeUsers *activeUsersCollector
+
+	mu sync.Mutex
+	// orgs are the organizations labeled with their ID when the settings don't list them, in the order of their
+	// first request
+	orgs map[int64]bool
+}
+
+func NewTenantMetrics(cfg setting.TenantMetricsSettings, reg prometheus.Registerer) *TenantMetrics {
+	labels := []string{"org_id"}
+	if len(cfg.Teams) > 0 {
+		labels = append(labels, "team")
+	}
+
+	m := &TenantMetrics{
+		cfg:  cfg,
+		orgs: map[int64]bool{},
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ExporterName,
+			Subsystem: "tenant",
+			Name:      "api_requests_total",
+			Help:      "Number of API requests by organization and status class",
+		}, append(slices.Clone(labels), "status")),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ExporterName,
+			Subsystem: "tenant",
+			Name:      "api_errors_total",
+			Help:      "Number of API requests failing with a 5xx status by organization",
+		}, labels),
+		queries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: ExporterName,
+			Subsystem: "tenant",
+			Name:      "queries_total",
+			Help:      "Number of data source queries by organization",
+		}, labels),
+		activeUsers: &activeUsersCollector{
+			desc: prometheus.NewDesc(prometheus.BuildFQName(ExporterName, "tenant", "active_users"),
+				"Number of users who sent a request during the active users window by organization", []string{"org_id"}, nil),
+			window: cfg.ActiveUsersWindow,
+			seen:   map[string]map[string]time.Time{},
+			now:    time.Now,
+		},
+	}
+
+	if cfg.Metrics[setting.TenantMetricAPIRequests] {
+		reg.MustRegister(m.requests)
+	}
+	if cfg.Metrics[setting.TenantMetricAPIErrors] {
+		reg.MustRegister(m.errors)
+	}
+	if cfg.Metrics[setting.TenantMetricQueries] {
+		reg.MustRegister(m.queries)
+	}
+	if cfg.Metrics[setting.TenantMetricActiveUsers] {
+		reg.MustRegister(m.activeUsers)
+	}
+
+	return m
+}
+
+// TenantRequest is an API request counted by the tenant metrics
+type TenantRequest struct {
+	OrgID int64
+	Teams []int64
+	// UserID is the ID of the user, empty for the anonymous users and the service accounts that aren't counted as
+	// active users
+	UserID string
+	// Query is true for the requests querying a data source
+	Query      bool
+	StatusCode int
+}
+
+// ObserveRequest counts the request in the metrics of its organization and team.
+func (m *TenantMetrics) ObserveRequest(req TenantRequest) {
+	org := m.orgLabel(req.OrgID)
+	labels := prometheus.Labels{"org_id": org}
+	if len(m.cfg.Teams) > 0 {
+		labels["team"] = m.teamLabel(req.Teams)
+	}
+
+	m.requests.MustCurryWith(labels).WithLabelValues(statusClass(req.StatusCode)).Inc()
+	if req.StatusCode >= 500 {
+		m.errors.With(labels).Inc()
+	}
+	if req.Query {
+		m.queries.With(labels).Inc()
+	}
+	if req.UserID != "" {
+		m.activeUsers.observe(org, req.UserID)
+	}
+}
+
+func (m *TenantMetrics) orgLabel(orgID int64) string {
+	if len(m.cfg.Orgs) > 0 {
+		if m.cfg.Orgs[orgID] {
+			return strconv.FormatInt(orgID, 10)
+		}
+		return otherTenant
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.orgs[orgID] {
+		if len(m.orgs) >= m.cfg.MaxOrgs {
+			return otherTenant
+		}
+		m.orgs[orgID] = true
+	}
+	return strconv.FormatInt(orgID, 10)
+}
+
+// teamLabel returns the ID of the labeled team of the user with the lowest ID, so that the requests of a member of
+// several teams are always counted in the same team
+func (m *TenantMetrics) teamLabel(teams []int64) string {
+	label := int64(-1)
+	for _, team := range teams {
+		if m.cfg.Teams[team] && (label == -1 || team < label) {
+			label = team
+		}
+	}
+	if label == -1 {
+		return noTeam
+	}
+	return strconv.FormatInt(label, 10)
+}
+
+func statusClass(status int) string {
+	if status == 0 {
+		status = 200
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
+
+// activeUsersCollector counts the users of each organization who sent a request during the window. The users who
+// haven't sent a request since are removed when the metric is collected.
+type activeUsersCollector struct {
+	desc   *prometheus.Desc
+	window time.Duration
+	now    func() time.Time
+
+	mu sync.Mutex
+	// seen are the times of the last requests of the users by organization label
+	seen map[string]map[string]time.Time
+}
+
+func (c *activeUsersCollector) observe(org, userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	users, ok := c.seen[org]
+	if !ok {
+		users = map[string]time.Time{}
+		c.seen[org] = users
+	}
+	users[userID] = c.now()
+}
+
+func (c *activeUsersCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *activeUsersCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	since := c.now().Add(-c.window)
+	for org, users := range c.seen {
+		for userID, last := range users {
+			if last.Before(since) {
+				delete(users, userID)
+			}
+		}
+		if len(users) == 0 {
+			delete(c.seen, org)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(len(users)), org)
+	}
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestTenantMetrics(t *testing.T) {
+	allMetrics := map[string]bool{
+		setting.TenantMetricAPIRequests: true,
+		setting.TenantMetricAPIErrors:   true,
+		setting.TenantMetricQueries:     true,
+		setting.TenantMetricActiveUsers: true,
+	}
+
+	t.Run("should label the organizations up to the limit", func(t *testing.T) {
+		m := NewTenantMetrics(setting.TenantMetricsSettings{Metrics: allMetrics, MaxOrgs: 1, ActiveUsersWindow: time.Minute}, prometheus.NewRegistry())
+
+		m.ObserveRequest(TenantRequest{OrgID: 1, StatusCode: 200, Query: true})
+		m.ObserveRequest(TenantRequest{OrgID: 2, StatusCode: 502})
+		m.ObserveRequest(TenantRequest{OrgID: 1, StatusCode: 404})
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("1", "2xx")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("1", "4xx")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("other", "5xx")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.errors.WithLabelValues("other")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.queries.WithLabelValues("1")))
+	})
+
+	t.Run("should only label the organizations and teams of the allowlists", func(t *testing.T) {
+		m := NewTenantMetrics(setting.TenantMetricsSettings{
+			Metrics:           allMetrics,
+			Orgs:              map[int64]bool{2: true},
+			Teams:             map[int64]bool{5: true, 7: true},
+			ActiveUsersWindow: time.Minute,
+		}, prometheus.NewRegistry())
+
+		m.ObserveRequest(TenantRequest{OrgID: 1, Teams: []int64{5}, StatusCode: 200})
+		m.ObserveRequest(TenantRequest{OrgID: 2, Teams: []int64{9, 7, 5}, StatusCode: 200})
+		m.ObserveRequest(TenantRequest{OrgID: 2, Teams: []int64{9}, StatusCode: 200})
+
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("other", "5", "2xx")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("2", "5", "2xx")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues("2", "none", "2xx")))
+	})
+
+	t.Run("should count the users active during the window", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		m := NewTenantMetrics(setting.TenantMetricsSettings{Metrics: allMetrics, MaxOrgs: 10, ActiveUsersWindow: time.Minute}, reg)
+		now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+		m.activeUsers.now = func() time.Time { return now }
+
+		m.ObserveRequest(TenantRequest{OrgID: 1, UserID: "user:1", StatusCode: 200})
+		m.ObserveRequest(TenantRequest{OrgID: 1, UserID: "user:1", StatusCode: 200})
+		m.ObserveRequest(TenantRequest{OrgID: 1, UserID: "user:2", StatusCode: 200})
+		m.ObserveRequest(TenantRequest{OrgID: 1, StatusCode: 200})
+
+		require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP grafana_tenant_active_users Number of users who sent a request during the active users window by organization
+# TYPE grafana_tenant_active_users gauge
+grafana_tenant_active_users{org_id="1"} 2
+`), "grafana_tenant_active_users"))
+
+		now = now.Add(2 * time.Minute)
+		assert.Equal(t, 0, testutil.CollectAndCount(m.activeUsers))
+	})
+
+	t.Run("should only register the enabled metrics", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		m := NewTenantMetrics(setting.TenantMetricsSettings{
+			Metrics:           map[string]bool{setting.TenantMetricAPIErrors: true},
+			MaxOrgs:           10,
+			ActiveUsersWindow: time.Minute,
+		}, reg)
+
+		m.ObserveRequest(TenantRequest{OrgID: 1, StatusCode: 500})
+
+		families, err := reg.Gather()
+		require.NoError(t, err)
+		require.Len(t, families, 1)
+		assert.Equal(t, "grafana_tenant_api_errors_total", families[0].GetName())
+	})
+}
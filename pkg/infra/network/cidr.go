@@ -0,0 +1,41 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ParseCIDR parses a CIDR range, a single IPv4 or IPv6 address is parsed as a range containing only that address.
+func ParseCIDR(input string) (*net.IPNet, error) {
+	input = strings.TrimSpace(input)
+	if !strings.Contains(input, "/") {
+		ip := net.ParseIP(input)
+		if ip == nil {
+			return nil, fmt.Errorf("not a valid IP address or CIDR range: %q", input)
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+
+	_, ipNet, err := net.ParseCIDR(input)
+	if err != nil {
+		return nil, fmt.Errorf("not a valid IP address or CIDR range: %q", input)
+	}
+	return ipNet, nil
+}
+
+// ContainsIP returns true if one of the networks contains the IP address.
+func ContainsIP(networks []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, n := range networks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCIDR(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		input  string
+		exp    string
+		expErr string
+	}{
+		{
+			desc:  "IPv4 range",
+			input: "10.0.0.0/8",
+			exp:   "10.0.0.0/8",
+		},
+		{
+			desc:  "IPv4 range is normalized",
+			input: "192.168.1.12/24",
+			exp:   "192.168.1.0/24",
+		},
+		{
+			desc:  "IPv6 range",
+			input: "2001:db8::/32",
+			exp:   "2001:db8::/32",
+		},
+		{
+			desc:  "Single IPv4 address",
+			input: " 192.168.1.12 ",
+			exp:   "192.168.1.12/32",
+		},
+		{
+			desc:  "Single IPv6 address",
+			input: "2001:db8::1",
+			exp:   "2001:db8::1/128",
+		},
+		{
+			desc:   "Invalid range",
+			input:  "10.0.0.0/33",
+			expErr: `not a valid IP address or CIDR range: "10.0.0.0/33"`,
+		},
+		{
+			desc:   "Hostname",
+			input:  "localhost",
+			expErr: `not a valid IP address or CIDR range: "localhost"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ipNet, err := ParseCIDR(tc.input)
+			if tc.expErr != "" {
+				require.EqualError(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.exp, ipNet.String())
+		})
+	}
+}
+
+func TestContainsIP(t *testing.T) {
+	private, err := ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+	single, err := ParseCIDR("2001:db8::1")
+	require.NoError(t, err)
+	networks := []*net.IPNet{private, single}
+
+	assert.True(t, ContainsIP(networks, net.ParseIP("10.1.2.3")))
+	assert.True(t, ContainsIP(networks, net.ParseIP("2001:db8::1")))
+	assert.False(t, ContainsIP(networks, net.ParseIP("2001:db8::2")))
+	assert.False(t, ContainsIP(networks, net.ParseIP("192.168.1.1")))
+	assert.False(t, ContainsIP(networks, nil))
+}
@@ -0,0 +1,84 @@
+package remotecache
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+)
+
+// invalidationChannel is the channel the instances publish the keys they write on, prefixed with the prefix of the
+// keys so that instances sharing a redis server with other prefixes don't invalidate each other
+const invalidationChannel = "remote-cache-invalidations"
+
+// invalidationBus is implemented by the backends which can tell the other instances to invalidate their local cache
+type invalidationBus interface {
+	// Publish sends a message to the subscribers of the channel
+	Publish(ctx context.Context, channel string, message string) error
+	// Subscribe calls the handler with the messages of the channel until the context is done
+	Subscribe(ctx context.Context, channel string, handler func(message string)) error
+}
+
+// localTier keeps the recently used items of the remote cache in the memory of the instance. The items are evicted
+// when they expire, after the ttl at the latest, or when another instance writes them if the backend has an
+// invalidation bus.
+type localTier struct {
+	cache      *localcache.CacheService
+	ttl        time.Duration
+	maxItems   int
+	instanceID string
+}
+
+func newLocalTier(ttl time.Duration, maxItems int, instanceID string) *localTier {
+	return &localTier{
+		cache:      localcache.New(ttl, 2*ttl),
+		ttl:        ttl,
+		maxItems:   maxItems,
+		instanceID: instanceID,
+	}
+}
+
+func (l *localTier) get(key string) ([]byte, bool) {
+	value, ok := l.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return value.([]byte), true
+}
+
+// set caches a copy of the value, the caller may reuse it. Nothing is cached once the cache is full.
+func (l *localTier) set(key string, value []byte, expire time.Duration) {
+	if expire <= 0 || expire > l.ttl {
+		expire = l.ttl
+	}
+	if l.maxItems > 0 && l.cache.ItemCount() >= l.maxItems {
+		l.cache.DeleteExpired()
+		if l.cache.ItemCount() >= l.maxItems {
+			return
+		}
+	}
+
+	stored := make([]byte, len(value))
+	copy(stored, value)
+	l.cache.Set(key, stored, expire)
+}
+
+func (l *localTier) delete(key string) {
+	l.cache.Delete(key)
+}
+
+// invalidationMessage is the message telling the other instances to evict the key from their local cache
+func (l *localTier) invalidationMessage(key string) string {
+	return l.instanceID + " " + key
+}
+
+// handleInvalidation evicts the key of a message sent by another instance, and reports whether it did
+func (l *localTier) handleInvalidation(message string) bool {
+	instanceID, key, ok := strings.Cut(message, " ")
+	if !ok || instanceID == l.instanceID {
+		return false
+	}
+	l.delete(key)
+	return true
+}
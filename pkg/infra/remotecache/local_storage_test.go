@@ -0,0 +1,134 @@
+package remotecache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeInvalidationBus struct {
+	channels []string
+	messages []string
+}
+
+func (b *fakeInvalidationBus) Publish(_ context.Context, channel string, message string) error {
+	b.channels = append(b.channels, channel)
+	b.messages = append(b.messages, message)
+	return nil
+}
+
+func (b *fakeInvalidationBus) Subscribe(ctx context.Context, _ string, _ func(message string)) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func newLocalTierTestCache(backend CacheStorage, bus *fakeInvalidationBus, instanceID string, maxItems int) *RemoteCache {
+	return &RemoteCache{
+		Cfg:     &setting.Cfg{RemoteCacheOptions: &setting.RemoteCacheSettings{}},
+		client:  backend,
+		backend: backend,
+		local:   newLocalTier(time.Minute, maxItems, instanceID),
+		bus:     bus,
+		log:     log.NewNopLogger(),
+		metrics: newMetrics(nil),
+	}
+}
+
+func TestLocalTier(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("serves the items from the local tier until another instance writes them", func(t *testing.T) {
+		backend := NewFakeCacheStorage()
+		bus := &fakeInvalidationBus{}
+		first := newLocalTierTestCache(backend, bus, "first", 0)
+		second := newLocalTierTestCache(backend, bus, "second", 0)
+
+		require.NoError(t, first.Set(ctx, "foo", []byte("bar"), time.Hour))
+		v, err := first.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "bar", string(v))
+
+		// the second instance caches the value it reads
+		v, err = second.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "bar", string(v))
+
+		require.NoError(t, first.Set(ctx, "foo", []byte("baz"), time.Hour))
+		v, err = second.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "bar", string(v), "the local tier serves the previous value until it's invalidated")
+
+		for _, message := range bus.messages {
+			assert.False(t, first.local.handleInvalidation(message), "instances ignore their own messages")
+			second.local.handleInvalidation(message)
+		}
+		v, err = second.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "baz", string(v))
+	})
+
+	t.Run("deletes the items from the local tier", func(t *testing.T) {
+		backend := NewFakeCacheStorage()
+		bus := &fakeInvalidationBus{}
+		cache := newLocalTierTestCache(backend, bus, "first", 0)
+
+		require.NoError(t, cache.Set(ctx, "foo", []byte("bar"), time.Hour))
+		require.NoError(t, cache.Delete(ctx, "foo"))
+
+		_, err := cache.Get(ctx, "foo")
+		assert.ErrorIs(t, err, ErrCacheItemNotFound)
+		assert.Equal(t, []string{"first foo", "first foo"}, bus.messages)
+	})
+
+	t.Run("doesn't cache more than the max items", func(t *testing.T) {
+		cache := newLocalTierTestCache(NewFakeCacheStorage(), &fakeInvalidationBus{}, "first", 1)
+
+		require.NoError(t, cache.Set(ctx, "foo", []byte("bar"), time.Hour))
+		require.NoError(t, cache.Set(ctx, "baz", []byte("qux"), time.Hour))
+
+		_, ok := cache.local.get("foo")
+		assert.True(t, ok)
+		_, ok = cache.local.get("baz")
+		assert.False(t, ok)
+	})
+
+	t.Run("labels the metrics with the usage of the caller", func(t *testing.T) {
+		cache := newLocalTierTestCache(NewFakeCacheStorage(), &fakeInvalidationBus{}, "first", 0)
+		usage := WithUsage(cache, "auth token")
+
+		_, err := usage.Get(ctx, "missing")
+		assert.ErrorIs(t, err, ErrCacheItemNotFound)
+		assert.Equal(t, float64(1), testutil.ToFloat64(cache.metrics.lookups.WithLabelValues("auth token", "remote", "miss")))
+	})
+}
+
+func TestBroadcast(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("prefixes the channel with the prefix of the keys", func(t *testing.T) {
+		bus := &fakeInvalidationBus{}
+		cache := newLocalTierTestCache(NewFakeCacheStorage(), bus, "first", 0)
+		cache.Cfg.RemoteCacheOptions.Prefix = "grafana-"
+
+		require.True(t, cache.CanBroadcast())
+		require.NoError(t, cache.Broadcast(ctx, "channel", "message"))
+		assert.Equal(t, []string{"grafana-channel"}, bus.channels)
+		assert.Equal(t, []string{"message"}, bus.messages)
+	})
+
+	t.Run("fails when the backend can't broadcast", func(t *testing.T) {
+		cache := newLocalTierTestCache(NewFakeCacheStorage(), nil, "first", 0)
+		cache.bus = nil
+
+		assert.False(t, cache.CanBroadcast())
+		assert.ErrorIs(t, cache.Broadcast(ctx, "channel", "message"), ErrBroadcastUnsupported)
+		assert.ErrorIs(t, cache.Subscribe(ctx, "channel", func(string) {}), ErrBroadcastUnsupported)
+	})
+}
@@ -0,0 +1,98 @@
+package remotecache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "grafana"
+	metricsSubSystem = "remote_cache"
+
+	// defaultUsage labels the metrics of the callers that don't tell what they use the cache for
+	defaultUsage = "other"
+)
+
+type metrics struct {
+	lookups            *prometheus.CounterVec
+	duration           *prometheus.HistogramVec
+	localInvalidations prometheus.Counter
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		lookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "lookups_total",
+			Help:      "Number of lookups in the cache by usage, tier (local or remote) and result (hit, miss or error)",
+		}, []string{"usage", "tier", "result"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the requests to the remote cache by usage and operation",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"usage", "operation"}),
+		localInvalidations: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "local_invalidations_total",
+			Help:      "Number of keys of the local cache invalidated by the writes of other instances",
+		}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.lookups, m.duration, m.localInvalidations)
+	}
+
+	return m
+}
+
+func (m *metrics) observeLookup(usage, tier string, err error) {
+	result := "hit"
+	switch {
+	case errors.Is(err, ErrCacheItemNotFound):
+		result = "miss"
+	case err != nil:
+		result = "error"
+	}
+	m.lookups.WithLabelValues(usage, tier, result).Inc()
+}
+
+func (m *metrics) observeRequest(usage, operation string, start time.Time) {
+	m.duration.WithLabelValues(usage, operation).Observe(time.Since(start).Seconds())
+}
+
+type usageKey struct{}
+
+// WithUsage returns a cache storage labelling the metrics of the cache with the usage, such as "auth token" or
+// "query cache", of its caller
+func WithUsage(cache CacheStorage, usage string) CacheStorage {
+	return &usageCacheStorage{cache: cache, usage: usage}
+}
+
+func usageFromContext(ctx context.Context) string {
+	if usage, ok := ctx.Value(usageKey{}).(string); ok {
+		return usage
+	}
+	return defaultUsage
+}
+
+type usageCacheStorage struct {
+	cache CacheStorage
+	usage string
+}
+
+func (ucs *usageCacheStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	return ucs.cache.Get(context.WithValue(ctx, usageKey{}, ucs.usage), key)
+}
+func (ucs *usageCacheStorage) Set(ctx context.Context, key string, value []byte, expire time.Duration) error {
+	return ucs.cache.Set(context.WithValue(ctx, usageKey{}, ucs.usage), key, value, expire)
+}
+func (ucs *usageCacheStorage) Delete(ctx context.Context, key string) error {
+	return ucs.cache.Delete(context.WithValue(ctx, usageKey{}, ucs.usage), key)
+}
@@ -3,8 +3,11 @@ package remotecache
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"net"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -16,19 +19,33 @@ import (
 
 const redisCacheType = "redis"
 
+// The topologies of redis the cache can connect to
+const (
+	redisModeStandalone = "standalone"
+	redisModeCluster    = "cluster"
+	redisModeSentinel   = "sentinel"
+)
+
 type redisStorage struct {
-	c *redis.Client
+	c redis.UniversalClient
+}
+
+// redisConnOptions are the options parsed from the connection string
+type redisConnOptions struct {
+	Mode string
+	*redis.UniversalOptions
 }
 
 // parseRedisConnStr parses k=v pairs in csv and builds a redis Options object
-func parseRedisConnStr(connStr string) (*redis.Options, error) {
+func parseRedisConnStr(connStr string) (*redisConnOptions, error) {
 	keyValueCSV := strings.Split(connStr, ",")
-	options := &redis.Options{Network: "tcp"}
+	options := &redisConnOptions{Mode: redisModeStandalone, UniversalOptions: &redis.UniversalOptions{}}
 	setTLSIsTrue := false
+	var caCertPath, clientCertPath, clientKeyPath string
 	for _, rawKeyValue := range keyValueCSV {
 		keyValueTuple := strings.SplitN(rawKeyValue, "=", 2)
 		if len(keyValueTuple) != 2 {
-			if strings.HasPrefix(rawKeyValue, "password") {
+			if strings.HasPrefix(rawKeyValue, "password") || strings.HasPrefix(rawKeyValue, "sentinel_password") {
 				// don't log the password
 				rawKeyValue = "password" + setting.RedactedPassword
 			}
@@ -37,12 +54,22 @@ func parseRedisConnStr(connStr string) (*redis.Options, error) {
 		connKey := keyValueTuple[0]
 		connVal := keyValueTuple[1]
 		switch connKey {
+		case "mode":
+			if connVal != redisModeStandalone && connVal != redisModeCluster && connVal != redisModeSentinel {
+				return nil, fmt.Errorf("mode must be set to 'standalone', 'cluster', or 'sentinel' when present")
+			}
+			options.Mode = connVal
 		case "addr":
-			options.Addr = connVal
+			// cluster nodes and sentinels are separated by semicolons
+			options.Addrs = strings.Split(connVal, ";")
 		case "username":
 			options.Username = connVal
 		case "password":
 			options.Password = connVal
+		case "master_name":
+			options.MasterName = connVal
+		case "sentinel_password":
+			options.SentinelPassword = connVal
 		case "db":
 			i, err := strconv.Atoi(connVal)
 			if err != nil {
@@ -55,6 +82,12 @@ func parseRedisConnStr(connStr string) (*redis.Options, error) {
 				return nil, fmt.Errorf("%v: %w", "value for pool_size in redis connection string must be a number", err)
 			}
 			options.PoolSize = i
+		case "read_only":
+			b, err := strconv.ParseBool(connVal)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %w", "value for read_only in redis connection string must be a boolean", err)
+			}
+			options.ReadOnly = b
 		case "ssl":
 			if connVal != "true" && connVal != "false" && connVal != "insecure" {
 				return nil, fmt.Errorf("ssl must be set to 'true', 'false', or 'insecure' when present")
@@ -65,27 +98,103 @@ func parseRedisConnStr(connStr string) (*redis.Options, error) {
 			if connVal == "insecure" {
 				options.TLSConfig = &tls.Config{InsecureSkipVerify: true}
 			}
+		case "ca_cert":
+			caCertPath = connVal
+		case "client_cert":
+			clientCertPath = connVal
+		case "client_key":
+			clientKeyPath = connVal
 		default:
 			return nil, fmt.Errorf("unrecognized option '%v' in redis connection string", connKey)
 		}
 	}
-	if setTLSIsTrue {
-		// Get hostname from the Addr property and set it on the configuration for TLS
-		sp := strings.Split(options.Addr, ":")
-		if len(sp) < 1 {
-			return nil, fmt.Errorf("unable to get hostname from the addr field, expected host:port, got '%v'", options.Addr)
+
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+
+	if setTLSIsTrue || caCertPath != "" || clientCertPath != "" {
+		if options.TLSConfig == nil {
+			// Get hostname from the first address and set it on the configuration for TLS
+			host, _, err := net.SplitHostPort(options.Addrs[0])
+			if err != nil {
+				return nil, fmt.Errorf("unable to get hostname from the addr field, expected host:port, got '%v'", options.Addrs[0])
+			}
+			options.TLSConfig = &tls.Config{ServerName: host}
+		}
+		if err := loadRedisTLSFiles(options.TLSConfig, caCertPath, clientCertPath, clientKeyPath); err != nil {
+			return nil, err
 		}
-		options.TLSConfig = &tls.Config{ServerName: sp[0]}
 	}
 	return options, nil
 }
 
+func (o *redisConnOptions) validate() error {
+	if len(o.Addrs) == 0 || o.Addrs[0] == "" {
+		return fmt.Errorf("addr is required in the redis connection string")
+	}
+
+	switch o.Mode {
+	case redisModeStandalone:
+		if len(o.Addrs) > 1 {
+			return fmt.Errorf("only one addr can be set in standalone mode, use mode=cluster or mode=sentinel for several addresses")
+		}
+	case redisModeCluster:
+		if o.DB != 0 {
+			return fmt.Errorf("db can't be set in cluster mode")
+		}
+	case redisModeSentinel:
+		if o.MasterName == "" {
+			return fmt.Errorf("master_name is required in sentinel mode")
+		}
+	}
+	return nil
+}
+
+// loadRedisTLSFiles adds the CA certificate and the client certificate, for mutual TLS, to the TLS configuration
+func loadRedisTLSFiles(config *tls.Config, caCertPath, clientCertPath, clientKeyPath string) error {
+	if caCertPath != "" {
+		// nolint:gosec
+		pem, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return fmt.Errorf("failed to read the CA certificate of redis: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificate found in the CA certificate of redis %s", caCertPath)
+		}
+		config.RootCAs = pool
+	}
+
+	if (clientCertPath == "") != (clientKeyPath == "") {
+		return fmt.Errorf("client_cert and client_key must be set together in the redis connection string")
+	}
+	if clientCertPath != "" {
+		cert, err := tls.LoadX509KeyPair(clientCertPath, clientKeyPath)
+		if err != nil {
+			return fmt.Errorf("failed to load the client certificate of redis: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return nil
+}
+
 func newRedisStorage(opts *setting.RemoteCacheSettings) (*redisStorage, error) {
 	opt, err := parseRedisConnStr(opts.ConnStr)
 	if err != nil {
 		return nil, err
 	}
-	return &redisStorage{c: redis.NewClient(opt)}, nil
+
+	var client redis.UniversalClient
+	switch opt.Mode {
+	case redisModeCluster:
+		client = redis.NewClusterClient(opt.Cluster())
+	case redisModeSentinel:
+		client = redis.NewFailoverClient(opt.Failover())
+	default:
+		client = redis.NewClient(opt.Simple())
+	}
+	return &redisStorage{c: client}, nil
 }
 
 // Set sets value to a given key
@@ -112,3 +221,28 @@ func (s *redisStorage) Delete(ctx context.Context, key string) error {
 	cmd := s.c.Del(ctx, key)
 	return cmd.Err()
 }
+
+// Publish sends a message to the subscribers of the channel
+func (s *redisStorage) Publish(ctx context.Context, channel string, message string) error {
+	return s.c.Publish(ctx, channel, message).Err()
+}
+
+// Subscribe calls the handler with the messages of the channel until the context is done. The client resubscribes
+// on its own when the connection is lost, the messages sent in the meantime are lost.
+func (s *redisStorage) Subscribe(ctx context.Context, channel string, handler func(message string)) error {
+	pubsub := s.c.Subscribe(ctx, channel)
+	defer func() { _ = pubsub.Close() }()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			handler(msg.Payload)
+		}
+	}
+}
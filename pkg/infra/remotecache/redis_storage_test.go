@@ -12,49 +12,95 @@ import (
 func Test_parseRedisConnStr(t *testing.T) {
 	cases := map[string]struct {
 		InputConnStr  string
-		OutputOptions *redis.Options
+		OutputOptions *redisConnOptions
 		ShouldErr     bool
 	}{
 		"all redis options should parse": {
 			"addr=127.0.0.1:6379,pool_size=100,db=1,username=grafana,password=grafanaRocks,ssl=false",
-			&redis.Options{
-				Addr:      "127.0.0.1:6379",
+			&redisConnOptions{Mode: redisModeStandalone, UniversalOptions: &redis.UniversalOptions{
+				Addrs:     []string{"127.0.0.1:6379"},
 				PoolSize:  100,
 				DB:        1,
 				Username:  "grafana",
 				Password:  "grafanaRocks",
-				Network:   "tcp",
 				TLSConfig: nil,
-			},
+			}},
 			false,
 		},
 		"subset of redis options should parse": {
 			"addr=127.0.0.1:6379,pool_size=100",
-			&redis.Options{
-				Addr:     "127.0.0.1:6379",
+			&redisConnOptions{Mode: redisModeStandalone, UniversalOptions: &redis.UniversalOptions{
+				Addrs:    []string{"127.0.0.1:6379"},
 				PoolSize: 100,
-				Network:  "tcp",
-			},
+			}},
 			false,
 		},
 		"ssl set to true should result in default TLS configuration with tls set to addr's host": {
 			"addr=grafana.com:6379,ssl=true",
-			&redis.Options{
-				Addr:      "grafana.com:6379",
-				Network:   "tcp",
+			&redisConnOptions{Mode: redisModeStandalone, UniversalOptions: &redis.UniversalOptions{
+				Addrs:     []string{"grafana.com:6379"},
 				TLSConfig: &tls.Config{ServerName: "grafana.com"},
-			},
+			}},
 			false,
 		},
 		"ssl to insecure should result in TLS configuration with InsecureSkipVerify": {
 			"addr=127.0.0.1:6379,ssl=insecure",
-			&redis.Options{
-				Addr:      "127.0.0.1:6379",
-				Network:   "tcp",
+			&redisConnOptions{Mode: redisModeStandalone, UniversalOptions: &redis.UniversalOptions{
+				Addrs:     []string{"127.0.0.1:6379"},
 				TLSConfig: &tls.Config{InsecureSkipVerify: true},
-			},
+			}},
 			false,
 		},
+		"cluster mode should parse the addresses of the nodes": {
+			"mode=cluster,addr=10.0.0.1:6379;10.0.0.2:6379;10.0.0.3:6379,username=grafana,password=grafanaRocks,read_only=true",
+			&redisConnOptions{Mode: redisModeCluster, UniversalOptions: &redis.UniversalOptions{
+				Addrs:    []string{"10.0.0.1:6379", "10.0.0.2:6379", "10.0.0.3:6379"},
+				Username: "grafana",
+				Password: "grafanaRocks",
+				ReadOnly: true,
+			}},
+			false,
+		},
+		"sentinel mode should parse the master name and the sentinels": {
+			"mode=sentinel,addr=10.0.0.1:26379;10.0.0.2:26379,master_name=mymaster,sentinel_password=sentinelRocks,db=2",
+			&redisConnOptions{Mode: redisModeSentinel, UniversalOptions: &redis.UniversalOptions{
+				Addrs:            []string{"10.0.0.1:26379", "10.0.0.2:26379"},
+				MasterName:       "mymaster",
+				SentinelPassword: "sentinelRocks",
+				DB:               2,
+			}},
+			false,
+		},
+		"invalid mode should err": {
+			"mode=ring,addr=127.0.0.1:6379",
+			nil,
+			true,
+		},
+		"several addresses in standalone mode should err": {
+			"addr=10.0.0.1:6379;10.0.0.2:6379",
+			nil,
+			true,
+		},
+		"db in cluster mode should err": {
+			"mode=cluster,addr=10.0.0.1:6379,db=1",
+			nil,
+			true,
+		},
+		"sentinel mode without master name should err": {
+			"mode=sentinel,addr=10.0.0.1:26379",
+			nil,
+			true,
+		},
+		"client certificate without key should err": {
+			"addr=127.0.0.1:6379,client_cert=/etc/grafana/redis.crt",
+			nil,
+			true,
+		},
+		"missing addr should err": {
+			"pool_size=100",
+			nil,
+			true,
+		},
 		"invalid SSL option should err": {
 			"addr=127.0.0.1:6379,ssl=dragons",
 			nil,
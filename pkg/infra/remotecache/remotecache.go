@@ -5,11 +5,16 @@ import (
 	"errors"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/errgroup"
+
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 var (
@@ -19,6 +24,9 @@ var (
 	// ErrInvalidCacheType is returned if the type is invalid
 	ErrInvalidCacheType = errors.New("invalid remote cache name")
 
+	// ErrBroadcastUnsupported is returned if the cache type can't send messages to the other instances
+	ErrBroadcastUnsupported = errors.New("remote cache type doesn't support broadcasting messages")
+
 	defaultMaxCacheExpiration = time.Hour * 24
 )
 
@@ -27,15 +35,27 @@ const (
 )
 
 func ProvideService(cfg *setting.Cfg, sqlStore db.DB, usageStats usagestats.Service,
-	secretsService secrets.Service) (*RemoteCache, error) {
-	client, err := createClient(cfg.RemoteCacheOptions, sqlStore, secretsService)
+	secretsService secrets.Service, reg prometheus.Registerer) (*RemoteCache, error) {
+	opts := cfg.RemoteCacheOptions
+	backend, err := createBackend(opts, sqlStore)
 	if err != nil {
 		return nil, err
 	}
 	s := &RemoteCache{
 		SQLStore: sqlStore,
 		Cfg:      cfg,
-		client:   client,
+		client:   wrapBackend(backend, opts, secretsService),
+		backend:  backend,
+		log:      log.New("remotecache"),
+		metrics:  newMetrics(reg),
+	}
+
+	s.bus, _ = backend.(invalidationBus)
+	if opts.LocalCacheEnabled {
+		s.local = newLocalTier(opts.LocalCacheTTL, opts.LocalCacheMaxItems, util.GenerateShortUID())
+		if s.bus == nil {
+			s.log.Info("The local cache isn't invalidated by the other instances with this cache type, its items expire after the local cache TTL", "type", opts.Name, "ttl", opts.LocalCacheTTL)
+		}
 	}
 
 	usageStats.RegisterMetricsFunc(s.getUsageStats)
@@ -53,6 +73,12 @@ func (ds *RemoteCache) getUsageStats(ctx context.Context) (map[string]any, error
 
 	stats["stats.remote_cache.encrypt_enabled.count"] = encryptVal
 
+	localVal := 0
+	if ds.Cfg.RemoteCacheOptions.LocalCacheEnabled {
+		localVal = 1
+	}
+	stats["stats.remote_cache.local_enabled.count"] = localVal
+
 	return stats, nil
 }
 
@@ -71,16 +97,43 @@ type CacheStorage interface {
 	Delete(ctx context.Context, key string) error
 }
 
-// RemoteCache allows Grafana to cache data outside its own process
+// RemoteCache allows Grafana to cache data outside its own process. The recently used items can also be kept in
+// the memory of the instance, in a local tier in front of the remote cache.
 type RemoteCache struct {
 	client   CacheStorage
 	SQLStore db.DB
 	Cfg      *setting.Cfg
+
+	// backend is the client of the cache server, without the prefix and the encryption
+	backend CacheStorage
+	// local is nil when the local tier is disabled
+	local *localTier
+	// bus is nil when the backend can't send messages to the other instances
+	bus     invalidationBus
+	log     log.Logger
+	metrics *metrics
 }
 
 // Get returns the cached value as an byte array
 func (ds *RemoteCache) Get(ctx context.Context, key string) ([]byte, error) {
-	return ds.client.Get(ctx, key)
+	usage := usageFromContext(ctx)
+	if ds.local != nil {
+		if value, ok := ds.local.get(key); ok {
+			ds.metrics.observeLookup(usage, "local", nil)
+			return value, nil
+		}
+		ds.metrics.observeLookup(usage, "local", ErrCacheItemNotFound)
+	}
+
+	start := time.Now()
+	value, err := ds.client.Get(ctx, key)
+	ds.metrics.observeRequest(usage, "get", start)
+	ds.metrics.observeLookup(usage, "remote", err)
+
+	if err == nil && ds.local != nil {
+		ds.local.set(key, value, 0)
+	}
+	return value, err
 }
 
 // Set stored the byte array in the cache
@@ -89,27 +142,108 @@ func (ds *RemoteCache) Set(ctx context.Context, key string, value []byte, expire
 		expire = defaultMaxCacheExpiration
 	}
 
-	return ds.client.Set(ctx, key, value, expire)
+	start := time.Now()
+	err := ds.client.Set(ctx, key, value, expire)
+	ds.metrics.observeRequest(usageFromContext(ctx), "set", start)
+	if err != nil {
+		return err
+	}
+
+	if ds.local != nil {
+		ds.local.set(key, value, expire)
+		ds.invalidate(ctx, key)
+	}
+	return nil
 }
 
 // Delete object from cache
 func (ds *RemoteCache) Delete(ctx context.Context, key string) error {
-	return ds.client.Delete(ctx, key)
+	if ds.local != nil {
+		ds.local.delete(key)
+	}
+
+	start := time.Now()
+	err := ds.client.Delete(ctx, key)
+	ds.metrics.observeRequest(usageFromContext(ctx), "delete", start)
+
+	if ds.local != nil {
+		ds.invalidate(ctx, key)
+	}
+	return err
+}
+
+// invalidate tells the other instances to evict the key from their local tier. The local tiers of the instances
+// which miss the message serve the previous value until it expires.
+func (ds *RemoteCache) invalidate(ctx context.Context, key string) {
+	if ds.bus == nil || ds.local == nil {
+		return
+	}
+	if err := ds.bus.Publish(ctx, ds.invalidationChannel(), ds.local.invalidationMessage(key)); err != nil {
+		ds.log.Warn("Failed to invalidate the local cache of the other instances", "error", err)
+	}
+}
+
+func (ds *RemoteCache) invalidationChannel() string {
+	return ds.Cfg.RemoteCacheOptions.Prefix + invalidationChannel
+}
+
+// CanBroadcast reports whether the cache type can send messages to the other instances, only redis can
+func (ds *RemoteCache) CanBroadcast() bool {
+	return ds.bus != nil
+}
+
+// Broadcast sends the message to the subscribers of the channel on every instance sharing the cache, including this
+// one. The delivery isn't guaranteed: the instances which are disconnected from the cache miss the message.
+func (ds *RemoteCache) Broadcast(ctx context.Context, channel string, message string) error {
+	if ds.bus == nil {
+		return ErrBroadcastUnsupported
+	}
+	return ds.bus.Publish(ctx, ds.Cfg.RemoteCacheOptions.Prefix+channel, message)
+}
+
+// Subscribe calls the handler with the messages broadcast on the channel until the context is done
+func (ds *RemoteCache) Subscribe(ctx context.Context, channel string, handler func(message string)) error {
+	if ds.bus == nil {
+		return ErrBroadcastUnsupported
+	}
+	return ds.bus.Subscribe(ctx, ds.Cfg.RemoteCacheOptions.Prefix+channel, handler)
 }
 
 // Run starts the backend processes for cache clients.
 func (ds *RemoteCache) Run(ctx context.Context) error {
+	g, ctx := errgroup.WithContext(ctx)
+
 	// create new interface if more clients need GC jobs
-	backgroundjob, ok := ds.client.(registry.BackgroundService)
-	if ok {
-		return backgroundjob.Run(ctx)
+	if backgroundjob, ok := ds.backend.(registry.BackgroundService); ok {
+		g.Go(func() error { return backgroundjob.Run(ctx) })
 	}
 
-	<-ctx.Done()
-	return ctx.Err()
+	if ds.local != nil && ds.bus != nil {
+		g.Go(func() error {
+			return ds.bus.Subscribe(ctx, ds.invalidationChannel(), func(message string) {
+				if ds.local.handleInvalidation(message) {
+					ds.metrics.localInvalidations.Inc()
+				}
+			})
+		})
+	}
+
+	g.Go(func() error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	return g.Wait()
+}
+
+func createClient(opts *setting.RemoteCacheSettings, sqlstore db.DB, secretsService secrets.Service) (CacheStorage, error) {
+	backend, err := createBackend(opts, sqlstore)
+	if err != nil {
+		return nil, err
+	}
+	return wrapBackend(backend, opts, secretsService), nil
 }
 
-func createClient(opts *setting.RemoteCacheSettings, sqlstore db.DB, secretsService secrets.Service) (cache CacheStorage, err error) {
+func createBackend(opts *setting.RemoteCacheSettings, sqlstore db.DB) (cache CacheStorage, err error) {
 	switch opts.Name {
 	case redisCacheType:
 		cache, err = newRedisStorage(opts)
@@ -121,8 +255,12 @@ func createClient(opts *setting.RemoteCacheSettings, sqlstore db.DB, secretsServ
 		return nil, ErrInvalidCacheType
 	}
 	if err != nil {
-		return cache, err
+		return nil, err
 	}
+	return cache, nil
+}
+
+func wrapBackend(cache CacheStorage, opts *setting.RemoteCacheSettings, secretsService secrets.Service) CacheStorage {
 	if opts.Prefix != "" {
 		cache = &prefixCacheStorage{cache: cache, prefix: opts.Prefix}
 	}
@@ -130,7 +268,7 @@ func createClient(opts *setting.RemoteCacheSettings, sqlstore db.DB, secretsServ
 	if opts.Encryption {
 		cache = &encryptedCacheStorage{cache: cache, secretsService: secretsService}
 	}
-	return cache, nil
+	return cache
 }
 
 type encryptedCacheStorage struct {
@@ -16,6 +16,8 @@ func (usm *UsageStatsMock) RegisterMetricsFunc(fn MetricsFunc) {
 	usm.metricsFuncs = append(usm.metricsFuncs, fn)
 }
 
+func (usm *UsageStatsMock) RegisterCollector(_ Collector) {}
+
 func (usm *UsageStatsMock) GetUsageReport(ctx context.Context) (Report, error) {
 	all := make(map[string]any)
 	for _, fn := range usm.metricsFuncs {
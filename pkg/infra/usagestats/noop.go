@@ -8,6 +8,8 @@ var _ Service = &NoopUsageStats{}
 
 func (usm *NoopUsageStats) RegisterMetricsFunc(_ MetricsFunc) {}
 
+func (usm *NoopUsageStats) RegisterCollector(_ Collector) {}
+
 func (usm *NoopUsageStats) GetUsageReport(_ context.Context) (Report, error) {
 	return Report{}, nil
 }
@@ -2,6 +2,7 @@ package usagestats
 
 import (
 	"context"
+	"time"
 )
 
 type Report struct {
@@ -19,9 +20,32 @@ type MetricsFunc func(context.Context) (map[string]any, error)
 
 type SendReportCallbackFunc func()
 
+// Collector collects additional usage metrics, which are only included in the exported usage reports and are never
+// sent to Grafana Labs. It allows the instances reviewing or ingesting their usage reports to add their own metrics.
+type Collector interface {
+	// Name identifies the metrics of the collector in the export.
+	Name() string
+	Collect(context.Context) (map[string]any, error)
+}
+
+// Export is the usage report Grafana sends, with the metrics of the registered collectors.
+type Export struct {
+	// Report is the anonymous usage report, as Grafana sends it when the reporting is enabled
+	Report Report `json:"report"`
+	// Collectors are the metrics of the registered collectors by name
+	Collectors map[string]map[string]any `json:"collectors"`
+	// Errors are the errors of the collectors which failed by name
+	Errors map[string]string `json:"errors,omitempty"`
+	// ReportingEnabled is false when the report isn't sent to Grafana Labs
+	ReportingEnabled bool      `json:"reportingEnabled"`
+	ExportedAt       time.Time `json:"exportedAt"`
+}
+
 type Service interface {
 	GetUsageReport(context.Context) (Report, error)
 	RegisterMetricsFunc(MetricsFunc)
+	// RegisterCollector adds a collector to the exported usage reports.
+	RegisterCollector(Collector)
 	RegisterSendReportCallback(SendReportCallbackFunc)
 	SetReadyToReport(context.Context)
 }
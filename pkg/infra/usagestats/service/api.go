@@ -1,6 +1,7 @@
 package service
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/api/response"
@@ -16,6 +17,7 @@ func (uss *UsageStats) registerAPIEndpoints() {
 
 	uss.RouteRegister.Group(rootUrl, func(subrouter routing.RouteRegister) {
 		subrouter.Get("/usage-report-preview", authorize(accesscontrol.EvalPermission(accesscontrol.ActionUsageStatsRead)), routing.Wrap(uss.getUsageReportPreview))
+		subrouter.Get("/usage-report/export", authorize(accesscontrol.EvalPermission(accesscontrol.ActionUsageStatsRead)), routing.Wrap(uss.exportUsageReport))
 	})
 }
 
@@ -30,3 +32,21 @@ func (uss *UsageStats) getUsageReportPreview(ctx *contextmodel.ReqContext) respo
 
 	return response.JSON(http.StatusOK, usageReport)
 }
+
+// exportUsageReport returns the usage report Grafana sends with the metrics of the registered collectors, as a file
+// when the download parameter is set, so that it can be reviewed or ingested by the instances not sending it.
+func (uss *UsageStats) exportUsageReport(ctx *contextmodel.ReqContext) response.Response {
+	ctxTracer, span := uss.tracer.Start(ctx.Req.Context(), "usageStats.exportUsageReport")
+	defer span.End()
+
+	export, err := uss.ExportUsageReport(ctxTracer)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to export usage report", err)
+	}
+
+	if ctx.QueryBool("download") {
+		filename := fmt.Sprintf("usage-report-%s.json", export.ExportedAt.Format("20060102-150405"))
+		return response.JSONDownload(http.StatusOK, export, filename)
+	}
+	return response.JSON(http.StatusOK, export)
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/db/dbtest"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
@@ -64,6 +65,45 @@ func TestApi_getUsageStats(t *testing.T) {
 	}
 }
 
+func TestApi_exportUsageReport(t *testing.T) {
+	uss := createService(t, dbtest.NewFakeDB(), false)
+	uss.registerAPIEndpoints()
+	uss.RegisterCollector(&fakeCollector{name: "internal", metrics: map[string]any{"teams.count": 3}})
+
+	t.Run("should return the export", func(t *testing.T) {
+		server := setupTestServer(t, &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{1: {accesscontrol.ActionUsageStatsRead: {}}}}, uss)
+		recorder := exportUsageReport(t, server, "")
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Empty(t, recorder.Header().Get("Content-Disposition"))
+
+		var export usagestats.Export
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&export))
+		require.Contains(t, export.Report.Metrics, "stats.valid_license.count")
+		require.EqualValues(t, 3, export.Collectors["internal"]["teams.count"])
+	})
+
+	t.Run("should return the export as a file", func(t *testing.T) {
+		server := setupTestServer(t, &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{1: {accesscontrol.ActionUsageStatsRead: {}}}}, uss)
+		recorder := exportUsageReport(t, server, "?download=true")
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Contains(t, recorder.Header().Get("Content-Disposition"), `attachment;filename="usage-report-`)
+	})
+
+	t.Run("should return 403 without the permission to read the usage stats", func(t *testing.T) {
+		server := setupTestServer(t, &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{1: {}}}, uss)
+		recorder := exportUsageReport(t, server, "")
+		require.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+}
+
+func exportUsageReport(t *testing.T, server *web.Mux, query string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(http.MethodGet, "/api/admin/usage-report/export"+query, http.NoBody)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, req)
+	return recorder
+}
+
 func getUsageStats(t *testing.T, server *web.Mux) (*stats.SystemStats, *httptest.ResponseRecorder) {
 	req, err := http.NewRequest(http.MethodGet, "/api/admin/usage-report-preview", http.NoBody)
 	require.NoError(t, err)
@@ -26,6 +26,7 @@ type UsageStats struct {
 	tracer tracing.Tracer
 
 	externalMetrics     []usagestats.MetricsFunc
+	collectors          []usagestats.Collector
 	sendReportCallbacks []usagestats.SendReportCallbackFunc
 
 	readyToReport atomic.Bool
@@ -127,6 +127,44 @@ func (uss *UsageStats) RegisterMetricsFunc(fn usagestats.MetricsFunc) {
 	uss.externalMetrics = append(uss.externalMetrics, fn)
 }
 
+func (uss *UsageStats) RegisterCollector(c usagestats.Collector) {
+	uss.collectors = append(uss.collectors, c)
+}
+
+// ExportUsageReport returns the usage report Grafana sends, with the metrics of the registered collectors. The failed
+// collectors don't fail the export, their errors are in the export.
+func (uss *UsageStats) ExportUsageReport(ctx context.Context) (usagestats.Export, error) {
+	ctx, span := uss.tracer.Start(ctx, "UsageStats.Export")
+	defer span.End()
+
+	report, err := uss.GetUsageReport(ctx)
+	if err != nil {
+		return usagestats.Export{}, err
+	}
+
+	export := usagestats.Export{
+		Report:           report,
+		Collectors:       make(map[string]map[string]any, len(uss.collectors)),
+		ReportingEnabled: uss.Cfg.ReportingEnabled,
+		ExportedAt:       time.Now().UTC(),
+	}
+	for _, c := range uss.collectors {
+		ctxWithTimeout, cancel := context.WithTimeout(ctx, collectorTimeoutDuration)
+		metrics, err := c.Collect(ctxWithTimeout)
+		cancel()
+		if err != nil {
+			uss.log.FromContext(ctx).Error("Failed to collect usage stats", "collector", c.Name(), "error", err)
+			if export.Errors == nil {
+				export.Errors = map[string]string{}
+			}
+			export.Errors[c.Name()] = err.Error()
+			continue
+		}
+		export.Collectors[c.Name()] = metrics
+	}
+	return export, nil
+}
+
 func (uss *UsageStats) sendUsageStats(ctx context.Context) (string, error) {
 	if !uss.Cfg.ReportingEnabled {
 		return "", nil
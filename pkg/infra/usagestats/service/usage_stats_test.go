@@ -232,6 +232,50 @@ func TestRegisterMetrics(t *testing.T) {
 	})
 }
 
+type fakeCollector struct {
+	name    string
+	metrics map[string]any
+	err     error
+}
+
+func (c *fakeCollector) Name() string { return c.name }
+
+func (c *fakeCollector) Collect(context.Context) (map[string]any, error) {
+	return c.metrics, c.err
+}
+
+func TestExportUsageReport(t *testing.T) {
+	const metricName = "stats.test_metric.count"
+
+	uss := createService(t, dbtest.NewFakeDB(), false)
+	uss.Cfg.ReportingEnabled = false
+	uss.RegisterMetricsFunc(func(context.Context) (map[string]any, error) {
+		return map[string]any{metricName: 1}, nil
+	})
+	uss.RegisterCollector(&fakeCollector{name: "internal", metrics: map[string]any{"teams.count": 3}})
+	uss.RegisterCollector(&fakeCollector{name: "failing", err: errors.New("some error")})
+
+	export, err := uss.ExportUsageReport(context.Background())
+	require.NoError(t, err)
+
+	t.Run("should include the report that would be sent", func(t *testing.T) {
+		report, err := uss.GetUsageReport(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, report.UsageStatsId, export.Report.UsageStatsId)
+		assert.Equal(t, 1, export.Report.Metrics[metricName])
+		assert.False(t, export.ReportingEnabled)
+	})
+
+	t.Run("should include the metrics of the collectors apart from the report", func(t *testing.T) {
+		assert.Equal(t, map[string]map[string]any{"internal": {"teams.count": 3}}, export.Collectors)
+		assert.NotContains(t, export.Report.Metrics, "teams.count")
+	})
+
+	t.Run("should include the errors of the failed collectors", func(t *testing.T) {
+		assert.Equal(t, map[string]string{"failing": "some error"}, export.Errors)
+	})
+}
+
 type httpResp struct {
 	req            *http.Request
 	responseBuffer *bytes.Buffer
@@ -0,0 +1,52 @@
+package usagereport
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+
+	routeRegister.Get("/api/admin/usage-report", authorize(ac.EvalPermission(ac.ActionUsageStatsRead)), routing.Wrap(s.getUsageReport))
+}
+
+// swagger:route GET /admin/usage-report admin getUsageReport
+//
+// Get the usage of the instance over a time window.
+//
+// Returns the active users by organization, the created and viewed dashboards, the queries by datasource type, the
+// alert evaluations and the storage footprint. The window is a duration between 1h and 366d, 30d by default.
+//
+// Responses:
+// 200: getUsageReportResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) getUsageReport(c *contextmodel.ReqContext) response.Response {
+	report, err := s.GetReport(c.Req.Context(), c.Query("window"))
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get the usage report", err)
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:parameters getUsageReport
+type GetUsageReportParams struct {
+	// A duration such as 24h, 7d or 90d
+	// in:query
+	// required:false
+	// default:30d
+	Window string `json:"window"`
+}
+
+// swagger:response getUsageReportResponse
+type GetUsageReportResponse struct {
+	// in: body
+	Body Report `json:"body"`
+}
@@ -0,0 +1,88 @@
+package usagereport
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+var ErrInvalidWindow = errutil.BadRequest("usagereport.invalid-window", errutil.WithPublicMessage("Invalid time window, expected a duration between 1h and 366d such as 7d"))
+
+// The metrics sampled from the counters of the instance
+const (
+	metricDashboardViews   = "dashboard_views"
+	metricQueries          = "queries"
+	metricAlertEvaluations = "alert_evaluations"
+)
+
+// Report is the usage of the instance over a time window.
+type Report struct {
+	Window           string           `json:"window"`
+	From             time.Time        `json:"from"`
+	To               time.Time        `json:"to"`
+	ActiveUsers      ActiveUsers      `json:"activeUsers"`
+	Dashboards       DashboardUsage   `json:"dashboards"`
+	Queries          QueryUsage       `json:"queries"`
+	AlertEvaluations AlertEvaluations `json:"alertEvaluations"`
+	Storage          Storage          `json:"storage"`
+}
+
+type OrgCount struct {
+	OrgID   int64  `json:"orgId" xorm:"org_id"`
+	OrgName string `json:"orgName" xorm:"org_name"`
+	Count   int64  `json:"count" xorm:"count"`
+}
+
+// ActiveUsers are the users seen during the window.
+type ActiveUsers struct {
+	Total int64      `json:"total"`
+	ByOrg []OrgCount `json:"byOrg"`
+}
+
+type DashboardUsage struct {
+	// Total is the number of dashboards, at the time of the report
+	Total        int64      `json:"total"`
+	Created      int64      `json:"created"`
+	CreatedByOrg []OrgCount `json:"createdByOrg"`
+	Viewed       int64      `json:"viewed"`
+}
+
+type QueryUsage struct {
+	Total            int64            `json:"total"`
+	ByDatasourceType map[string]int64 `json:"byDatasourceType"`
+}
+
+type AlertEvaluations struct {
+	Total int64      `json:"total"`
+	ByOrg []OrgCount `json:"byOrg"`
+}
+
+// Storage is the storage footprint of the instance, at the time of the report.
+type Storage struct {
+	// DatabaseBytes is the size of the database, 0 when the database doesn't report it
+	DatabaseBytes          int64 `json:"databaseBytes"`
+	DashboardsBytes        int64 `json:"dashboardsBytes"`
+	DashboardVersions      int64 `json:"dashboardVersions"`
+	DashboardVersionsBytes int64 `json:"dashboardVersionsBytes"`
+	Annotations            int64 `json:"annotations"`
+}
+
+// counterRow is the value a counter gained during a day, summed over the instances
+type counterRow struct {
+	ID     int64  `xorm:"pk autoincr 'id'"`
+	Day    string `xorm:"day"`
+	Metric string `xorm:"metric"`
+	Label  string `xorm:"label"`
+	OrgID  int64  `xorm:"org_id"`
+	Value  int64  `xorm:"value"`
+}
+
+func (counterRow) TableName() string {
+	return "usage_report_counter"
+}
+
+type counterKey struct {
+	metric string
+	label  string
+	orgID  int64
+}
@@ -0,0 +1,285 @@
+package usagereport
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/usagestats"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+const (
+	sampleInterval = 5 * time.Minute
+	// retention is how long the daily counters are kept, longer than the largest window
+	retention     = 400 * 24 * time.Hour
+	maxWindow     = 366 * 24 * time.Hour
+	defaultWindow = "30d"
+	dayLayout     = "2006-01-02"
+)
+
+// counterSource is a counter of the instance sampled for the report
+type counterSource struct {
+	metric string
+	family string
+	// match are the values the labels of the sampled series must have
+	match map[string]string
+	// label is the label the counter is split by
+	label string
+	// orgLabel is the label holding the org ID of the series
+	orgLabel string
+}
+
+var counterSources = []counterSource{
+	{metric: metricDashboardViews, family: "grafana_api_dashboard_get_milliseconds"},
+	{metric: metricQueries, family: "grafana_plugin_request_total", match: map[string]string{"endpoint": "queryData"}, label: "plugin_id"},
+	{metric: metricAlertEvaluations, family: "grafana_alerting_rule_evaluations_total", orgLabel: "org"},
+}
+
+// Service reports the usage of the instance over time windows. The active users, the created dashboards and the
+// storage come from the database. The dashboard views, the queries and the alert evaluations are sampled from the
+// counters of each instance, and their increases are stored by day.
+type Service struct {
+	store    store
+	gatherer prometheus.Gatherer
+	log      log.Logger
+	now      func() time.Time
+
+	// last are the values of the counters at the last sample
+	last        map[counterKey]float64
+	lastCleanup time.Time
+}
+
+func ProvideService(db db.DB, gatherer prometheus.Gatherer, routeRegister routing.RouteRegister,
+	accessControl ac.AccessControl, usageStats usagestats.Service) *Service {
+	s := &Service{
+		store:    &sqlStore{db: db},
+		gatherer: gatherer,
+		log:      log.New("infra.usagestats.report"),
+		now:      time.Now,
+		last:     map[counterKey]float64{},
+	}
+
+	s.registerAPIEndpoints(routeRegister, accessControl)
+	usageStats.RegisterCollector(s)
+
+	return s
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(sampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.sample(ctx); err != nil {
+				s.log.Error("Failed to sample the usage counters", "error", err)
+			}
+		case <-ctx.Done():
+			// keep the increases since the last sample
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+			if err := s.sample(flushCtx); err != nil {
+				s.log.Warn("Failed to sample the usage counters on shutdown", "error", err)
+			}
+			cancel()
+			return ctx.Err()
+		}
+	}
+}
+
+// sample stores the increases of the counters since the last sample. The counters start at zero with the instance, so
+// the first sample stores their whole value.
+func (s *Service) sample(ctx context.Context) error {
+	families, err := s.gatherer.Gather()
+	if err != nil {
+		// the families which were gathered are still sampled
+		s.log.Debug("Failed to gather some metrics", "error", err)
+	}
+
+	current := readCounters(families)
+	increases := make(map[counterKey]int64, len(current))
+	for key, value := range current {
+		increase := value - s.last[key]
+		if increase < 0 {
+			// the counter was reset
+			increase = value
+		}
+		if rounded := int64(math.Round(increase)); rounded > 0 {
+			increases[key] = rounded
+			continue
+		}
+		// keep the fractions until they add up
+		current[key] = s.last[key]
+	}
+
+	now := s.now().UTC()
+	if len(increases) > 0 {
+		if err := s.store.addCounters(ctx, now.Format(dayLayout), increases); err != nil {
+			return err
+		}
+	}
+	s.last = current
+
+	if now.Sub(s.lastCleanup) > 24*time.Hour {
+		deleted, err := s.store.deleteCountersBefore(ctx, now.Add(-retention).Format(dayLayout))
+		if err != nil {
+			return err
+		}
+		s.lastCleanup = now
+		s.log.Debug("Deleted old usage counters", "rows affected", deleted)
+	}
+	return nil
+}
+
+func readCounters(families []*dto.MetricFamily) map[counterKey]float64 {
+	counters := map[counterKey]float64{}
+	for _, source := range counterSources {
+		for _, family := range families {
+			if family.GetName() != source.family {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				key, ok := source.key(metric)
+				if !ok {
+					continue
+				}
+				switch {
+				case metric.GetCounter() != nil:
+					counters[key] += metric.GetCounter().GetValue()
+				case metric.GetSummary() != nil:
+					counters[key] += float64(metric.GetSummary().GetSampleCount())
+				case metric.GetHistogram() != nil:
+					counters[key] += float64(metric.GetHistogram().GetSampleCount())
+				}
+			}
+		}
+	}
+	return counters
+}
+
+func (c counterSource) key(metric *dto.Metric) (counterKey, bool) {
+	key := counterKey{metric: c.metric}
+	matched := 0
+	for _, pair := range metric.GetLabel() {
+		switch name := pair.GetName(); {
+		case c.match[name] != "":
+			if pair.GetValue() != c.match[name] {
+				return key, false
+			}
+			matched++
+		case name == c.label:
+			key.label = pair.GetValue()
+		case name == c.orgLabel:
+			orgID, err := strconv.ParseInt(pair.GetValue(), 10, 64)
+			if err != nil {
+				return key, false
+			}
+			key.orgID = orgID
+		}
+	}
+	return key, matched == len(c.match)
+}
+
+// GetReport returns the usage of the instance over the window, a duration such as 24h or 7d. The sampled counters are
+// stored by day, their window starts at the beginning of the day of its start.
+func (s *Service) GetReport(ctx context.Context, window string) (*Report, error) {
+	if window == "" {
+		window = defaultWindow
+	}
+	duration, err := gtime.ParseDuration(window)
+	if err != nil || duration < time.Hour || duration > maxWindow {
+		return nil, ErrInvalidWindow.Errorf("invalid window %q", window)
+	}
+
+	to := s.now().UTC()
+	report := &Report{Window: window, From: to.Add(-duration), To: to}
+
+	if report.ActiveUsers, err = s.store.activeUsers(ctx, report.From); err != nil {
+		return nil, err
+	}
+	if report.Dashboards, err = s.store.dashboards(ctx, report.From); err != nil {
+		return nil, err
+	}
+	if report.Storage, err = s.store.storage(ctx); err != nil {
+		return nil, err
+	}
+
+	counters, err := s.store.sumCounters(ctx, report.From.Format(dayLayout))
+	if err != nil {
+		return nil, err
+	}
+	report.Queries.ByDatasourceType = map[string]int64{}
+	alertsByOrg := map[int64]int64{}
+	for key, value := range counters {
+		switch key.metric {
+		case metricDashboardViews:
+			report.Dashboards.Viewed += value
+		case metricQueries:
+			report.Queries.Total += value
+			report.Queries.ByDatasourceType[key.label] += value
+		case metricAlertEvaluations:
+			report.AlertEvaluations.Total += value
+			alertsByOrg[key.orgID] += value
+		}
+	}
+
+	orgIDs := make([]int64, 0, len(alertsByOrg))
+	for orgID := range alertsByOrg {
+		orgIDs = append(orgIDs, orgID)
+	}
+	names, err := s.store.orgNames(ctx, orgIDs)
+	if err != nil {
+		return nil, err
+	}
+	report.AlertEvaluations.ByOrg = make([]OrgCount, 0, len(alertsByOrg))
+	for orgID, count := range alertsByOrg {
+		report.AlertEvaluations.ByOrg = append(report.AlertEvaluations.ByOrg, OrgCount{OrgID: orgID, OrgName: names[orgID], Count: count})
+	}
+	slices.SortFunc(report.AlertEvaluations.ByOrg, func(a, b OrgCount) int {
+		if a.Count != b.Count {
+			return cmp.Compare(b.Count, a.Count)
+		}
+		return cmp.Compare(a.OrgID, b.OrgID)
+	})
+
+	return report, nil
+}
+
+// Name identifies the usage report in the exported usage reports.
+func (s *Service) Name() string {
+	return "usage_report"
+}
+
+// Collect adds the usage of the default window to the exported usage reports.
+func (s *Service) Collect(ctx context.Context) (map[string]any, error) {
+	report, err := s.GetReport(ctx, defaultWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := map[string]any{
+		"active_users":             report.ActiveUsers.Total,
+		"dashboards":               report.Dashboards.Total,
+		"dashboards_created":       report.Dashboards.Created,
+		"dashboards_viewed":        report.Dashboards.Viewed,
+		"queries":                  report.Queries.Total,
+		"alert_evaluations":        report.AlertEvaluations.Total,
+		"database_bytes":           report.Storage.DatabaseBytes,
+		"dashboard_versions_bytes": report.Storage.DashboardVersionsBytes,
+	}
+	for dsType, count := range report.Queries.ByDatasourceType {
+		metrics["queries_by_type."+dsType] = count
+	}
+	return metrics, nil
+}
@@ -0,0 +1,149 @@
+package usagereport
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func setupService(t *testing.T) (*Service, *sqlStore, *prometheus.Registry) {
+	t.Helper()
+
+	registry := prometheus.NewRegistry()
+	store := &sqlStore{db: db.InitTestDB(t)}
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	return &Service{
+		store:    store,
+		gatherer: registry,
+		log:      log.NewNopLogger(),
+		now:      func() time.Time { return now },
+		last:     map[counterKey]float64{},
+	}, store, registry
+}
+
+func TestIntegrationSample(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	s, store, registry := setupService(t)
+
+	queries := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "grafana_plugin_request_total"}, []string{"plugin_id", "endpoint"})
+	views := prometheus.NewSummary(prometheus.SummaryOpts{Name: "grafana_api_dashboard_get_milliseconds"})
+	evaluations := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "grafana_alerting_rule_evaluations_total"}, []string{"org"})
+	registry.MustRegister(queries, views, evaluations)
+
+	queries.WithLabelValues("prometheus", "queryData").Add(5)
+	queries.WithLabelValues("prometheus", "callResource").Add(7)
+	queries.WithLabelValues("loki", "queryData").Add(2)
+	views.Observe(10)
+	evaluations.WithLabelValues("2").Add(4)
+
+	stored := func(t *testing.T) map[counterKey]int64 {
+		t.Helper()
+		counters, err := store.sumCounters(ctx, "2025-05-01")
+		require.NoError(t, err)
+		return counters
+	}
+
+	require.NoError(t, s.sample(ctx))
+	assert.Equal(t, map[counterKey]int64{
+		{metric: metricQueries, label: "prometheus"}: 5,
+		{metric: metricQueries, label: "loki"}:       2,
+		{metric: metricDashboardViews}:               1,
+		{metric: metricAlertEvaluations, orgID: 2}:   4,
+	}, stored(t))
+
+	t.Run("stores the increases since the last sample", func(t *testing.T) {
+		queries.WithLabelValues("prometheus", "queryData").Add(3)
+		views.Observe(10)
+
+		require.NoError(t, s.sample(ctx))
+		counters := stored(t)
+		assert.Equal(t, int64(8), counters[counterKey{metric: metricQueries, label: "prometheus"}])
+		assert.Equal(t, int64(2), counters[counterKey{metric: metricQueries, label: "loki"}])
+		assert.Equal(t, int64(2), counters[counterKey{metric: metricDashboardViews}])
+	})
+
+	t.Run("stores the whole value of a counter which was reset", func(t *testing.T) {
+		s.last[counterKey{metric: metricAlertEvaluations, orgID: 2}] = 100
+
+		require.NoError(t, s.sample(ctx))
+		assert.Equal(t, int64(8), stored(t)[counterKey{metric: metricAlertEvaluations, orgID: 2}])
+	})
+}
+
+func TestIntegrationGetReport(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	s, store, _ := setupService(t)
+
+	now := s.now()
+	mainOrg := &org.Org{Name: "Main Org.", Created: now, Updated: now}
+	support := &org.Org{Name: "Support", Created: now, Updated: now}
+	err := store.db.WithDbSession(ctx, func(sess *db.Session) error {
+		for _, o := range []*org.Org{mainOrg, support} {
+			if _, err := sess.Insert(o); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, store.addCounters(ctx, "2025-04-20", map[counterKey]int64{
+		{metric: metricQueries, label: "prometheus"}: 100,
+	}))
+	require.NoError(t, store.addCounters(ctx, "2025-04-24", map[counterKey]int64{
+		{metric: metricQueries, label: "prometheus"}:        30,
+		{metric: metricDashboardViews}:                      4,
+		{metric: metricAlertEvaluations, orgID: support.ID}: 9,
+	}))
+	require.NoError(t, store.addCounters(ctx, "2025-05-01", map[counterKey]int64{
+		{metric: metricQueries, label: "prometheus"}:        10,
+		{metric: metricQueries, label: "loki"}:              2,
+		{metric: metricDashboardViews}:                      3,
+		{metric: metricAlertEvaluations, orgID: mainOrg.ID}: 5,
+	}))
+
+	report, err := s.GetReport(ctx, "7d")
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2025, 4, 24, 12, 0, 0, 0, time.UTC), report.From)
+	assert.Zero(t, report.ActiveUsers.Total)
+	assert.Zero(t, report.Dashboards.Total)
+	assert.Equal(t, int64(7), report.Dashboards.Viewed)
+	assert.Equal(t, int64(42), report.Queries.Total, "the counters of the day of the start are included")
+	assert.Equal(t, map[string]int64{"prometheus": 40, "loki": 2}, report.Queries.ByDatasourceType)
+	assert.Equal(t, int64(14), report.AlertEvaluations.Total)
+	assert.Equal(t, []OrgCount{{OrgID: support.ID, OrgName: "Support", Count: 9}, {OrgID: mainOrg.ID, OrgName: "Main Org.", Count: 5}}, report.AlertEvaluations.ByOrg)
+
+	t.Run("defaults to 30 days", func(t *testing.T) {
+		report, err := s.GetReport(ctx, "")
+		require.NoError(t, err)
+		assert.Equal(t, "30d", report.Window)
+		assert.Equal(t, int64(142), report.Queries.Total)
+	})
+
+	t.Run("rejects invalid windows", func(t *testing.T) {
+		for _, window := range []string{"abc", "10m", "400d"} {
+			_, err := s.GetReport(ctx, window)
+			require.ErrorIs(t, err, ErrInvalidWindow, window)
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package usagereport
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+type store interface {
+	addCounters(ctx context.Context, day string, counters map[counterKey]int64) error
+	deleteCountersBefore(ctx context.Context, day string) (int64, error)
+	sumCounters(ctx context.Context, sinceDay string) (map[counterKey]int64, error)
+	activeUsers(ctx context.Context, since time.Time) (ActiveUsers, error)
+	dashboards(ctx context.Context, since time.Time) (DashboardUsage, error)
+	orgNames(ctx context.Context, orgIDs []int64) (map[int64]string, error)
+	storage(ctx context.Context) (Storage, error)
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+// addCounters adds the values to the counters of the day. The instances sample their own counters, the insert of a
+// counter can race with another instance and is retried as an update.
+func (s *sqlStore) addCounters(ctx context.Context, day string, counters map[counterKey]int64) error {
+	add := func() error {
+		return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+			for key, value := range counters {
+				result, err := sess.Exec("UPDATE usage_report_counter SET value = value + ? WHERE day = ? AND metric = ? AND label = ? AND org_id = ?",
+					value, day, key.metric, key.label, key.orgID)
+				if err != nil {
+					return err
+				}
+				if updated, err := result.RowsAffected(); err != nil {
+					return err
+				} else if updated > 0 {
+					continue
+				}
+				if _, err := sess.Insert(&counterRow{Day: day, Metric: key.metric, Label: key.label, OrgID: key.orgID, Value: value}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	err := add()
+	if err != nil && s.db.GetDialect().IsUniqueConstraintViolation(err) {
+		err = add()
+	}
+	return err
+}
+
+func (s *sqlStore) deleteCountersBefore(ctx context.Context, day string) (int64, error) {
+	var deleted int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		result, err := sess.Exec("DELETE FROM usage_report_counter WHERE day < ?", day)
+		if err != nil {
+			return err
+		}
+		deleted, err = result.RowsAffected()
+		return err
+	})
+	return deleted, err
+}
+
+func (s *sqlStore) sumCounters(ctx context.Context, sinceDay string) (map[counterKey]int64, error) {
+	var rows []counterRow
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL("SELECT metric, label, org_id, SUM(value) AS value FROM usage_report_counter WHERE day >= ? GROUP BY metric, label, org_id", sinceDay).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	counters := make(map[counterKey]int64, len(rows))
+	for _, row := range rows {
+		counters[counterKey{metric: row.Metric, label: row.Label, orgID: row.OrgID}] = row.Value
+	}
+	return counters, nil
+}
+
+func (s *sqlStore) activeUsers(ctx context.Context, since time.Time) (ActiveUsers, error) {
+	dialect := s.db.GetDialect()
+	result := ActiveUsers{ByOrg: []OrgCount{}}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		notServiceAccount := `u.is_service_account = ` + dialect.BooleanStr(false)
+
+		if _, err := sess.SQL(`SELECT COUNT(*) FROM `+dialect.Quote("user")+` u WHERE u.last_seen_at >= ? AND `+notServiceAccount, since).Get(&result.Total); err != nil {
+			return err
+		}
+
+		return sess.SQL(`SELECT org_user.org_id, org.name AS org_name, COUNT(DISTINCT org_user.user_id) AS count
+			FROM org_user
+			INNER JOIN `+dialect.Quote("user")+` u ON u.id = org_user.user_id
+			INNER JOIN org ON org.id = org_user.org_id
+			WHERE u.last_seen_at >= ? AND `+notServiceAccount+`
+			GROUP BY org_user.org_id, org.name
+			ORDER BY count DESC`, since).Find(&result.ByOrg)
+	})
+	return result, err
+}
+
+func (s *sqlStore) dashboards(ctx context.Context, since time.Time) (DashboardUsage, error) {
+	dialect := s.db.GetDialect()
+	result := DashboardUsage{CreatedByOrg: []OrgCount{}}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		isDashboard := `dashboard.is_folder = ` + dialect.BooleanStr(false) + ` AND dashboard.deleted IS NULL`
+
+		if _, err := sess.SQL(`SELECT COUNT(*) FROM dashboard WHERE ` + isDashboard).Get(&result.Total); err != nil {
+			return err
+		}
+
+		if err := sess.SQL(`SELECT dashboard.org_id, org.name AS org_name, COUNT(*) AS count
+			FROM dashboard
+			INNER JOIN org ON org.id = dashboard.org_id
+			WHERE dashboard.created >= ? AND `+isDashboard+`
+			GROUP BY dashboard.org_id, org.name
+			ORDER BY count DESC`, since).Find(&result.CreatedByOrg); err != nil {
+			return err
+		}
+		for _, org := range result.CreatedByOrg {
+			result.Created += org.Count
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (s *sqlStore) orgNames(ctx context.Context, orgIDs []int64) (map[int64]string, error) {
+	names := make(map[int64]string, len(orgIDs))
+	if len(orgIDs) == 0 {
+		return names, nil
+	}
+
+	var orgs []struct {
+		ID   int64  `xorm:"id"`
+		Name string `xorm:"name"`
+	}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("org").Cols("id", "name").In("id", orgIDs).Find(&orgs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, org := range orgs {
+		names[org.ID] = org.Name
+	}
+	return names, nil
+}
+
+func (s *sqlStore) storage(ctx context.Context) (Storage, error) {
+	dialect := s.db.GetDialect()
+	length := "LENGTH"
+	if dialect.DriverName() == migrator.Postgres {
+		length = "OCTET_LENGTH"
+	}
+
+	var result Storage
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.SQL(`SELECT COALESCE(SUM(` + length + `(data)), 0) FROM dashboard`).Get(&result.DashboardsBytes); err != nil {
+			return err
+		}
+		if _, err := sess.SQL(`SELECT COUNT(*) FROM dashboard_version`).Get(&result.DashboardVersions); err != nil {
+			return err
+		}
+		if _, err := sess.SQL(`SELECT COALESCE(SUM(` + length + `(data)), 0) FROM dashboard_version`).Get(&result.DashboardVersionsBytes); err != nil {
+			return err
+		}
+		if _, err := sess.SQL(`SELECT COUNT(*) FROM annotation`).Get(&result.Annotations); err != nil {
+			return err
+		}
+
+		var sizeSQL string
+		switch dialect.DriverName() {
+		case migrator.SQLite:
+			sizeSQL = `SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size()`
+		case migrator.Postgres:
+			sizeSQL = `SELECT pg_database_size(current_database())`
+		case migrator.MySQL:
+			sizeSQL = `SELECT COALESCE(SUM(data_length + index_length), 0) FROM information_schema.tables WHERE table_schema = DATABASE()`
+		default:
+			return nil
+		}
+		// the size isn't reported when the database user isn't allowed to read it
+		if _, err := sess.SQL(sizeSQL).Get(&result.DatabaseBytes); err != nil {
+			result.DatabaseBytes = 0
+		}
+		return nil
+	})
+	return result, err
+}
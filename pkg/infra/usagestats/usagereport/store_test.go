@@ -0,0 +1,150 @@
+package usagereport
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	store := &sqlStore{db: db.InitTestDB(t)}
+
+	t.Run("should add to the counters of the day", func(t *testing.T) {
+		prometheus := counterKey{metric: metricQueries, label: "prometheus"}
+		views := counterKey{metric: metricDashboardViews}
+		alerts := counterKey{metric: metricAlertEvaluations, orgID: 2}
+
+		require.NoError(t, store.addCounters(ctx, "2025-04-01", map[counterKey]int64{prometheus: 3}))
+		require.NoError(t, store.addCounters(ctx, "2025-04-30", map[counterKey]int64{prometheus: 5, views: 1}))
+		require.NoError(t, store.addCounters(ctx, "2025-04-30", map[counterKey]int64{prometheus: 2, alerts: 4}))
+		require.NoError(t, store.addCounters(ctx, "2025-05-01", map[counterKey]int64{views: 6}))
+
+		counters, err := store.sumCounters(ctx, "2025-04-30")
+		require.NoError(t, err)
+		assert.Equal(t, map[counterKey]int64{prometheus: 7, views: 7, alerts: 4}, counters)
+
+		counters, err = store.sumCounters(ctx, "2025-01-01")
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), counters[prometheus])
+	})
+
+	t.Run("should delete the counters before a day", func(t *testing.T) {
+		deleted, err := store.deleteCountersBefore(ctx, "2025-04-30")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		counters, err := store.sumCounters(ctx, "2025-01-01")
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), counters[counterKey{metric: metricQueries, label: "prometheus"}])
+	})
+
+	now := time.Now().UTC().Truncate(time.Second)
+	since := now.Add(-24 * time.Hour)
+	mainOrg := &org.Org{Name: "Main", Created: now, Updated: now}
+	otherOrg := &org.Org{Name: "Other", Created: now, Updated: now}
+	err := store.db.WithDbSession(ctx, func(sess *db.Session) error {
+		for _, o := range []*org.Org{mainOrg, otherOrg} {
+			if _, err := sess.Insert(o); err != nil {
+				return err
+			}
+		}
+
+		users := []struct {
+			login          string
+			lastSeen       time.Time
+			serviceAccount bool
+			orgIDs         []int64
+		}{
+			{login: "active", lastSeen: now, orgIDs: []int64{mainOrg.ID, otherOrg.ID}},
+			{login: "active-main", lastSeen: now.Add(-time.Hour), orgIDs: []int64{mainOrg.ID}},
+			{login: "inactive", lastSeen: now.Add(-48 * time.Hour), orgIDs: []int64{mainOrg.ID}},
+			{login: "service-account", lastSeen: now, serviceAccount: true, orgIDs: []int64{otherOrg.ID}},
+		}
+		for _, u := range users {
+			row := &user.User{
+				UID: u.login, Login: u.login, Email: u.login + "@example.com", OrgID: u.orgIDs[0],
+				IsServiceAccount: u.serviceAccount, LastSeenAt: u.lastSeen, Created: now, Updated: now,
+			}
+			if _, err := sess.Insert(row); err != nil {
+				return err
+			}
+			for _, orgID := range u.orgIDs {
+				if _, err := sess.Insert(&org.OrgUser{OrgID: orgID, UserID: row.ID, Role: org.RoleViewer, Created: now, Updated: now}); err != nil {
+					return err
+				}
+			}
+		}
+
+		dashes := []*dashboards.Dashboard{
+			{UID: "new", OrgID: mainOrg.ID, Title: "New", Created: now},
+			{UID: "new-other", OrgID: otherOrg.ID, Title: "New other", Created: now},
+			{UID: "new-main", OrgID: mainOrg.ID, Title: "New main", Created: now.Add(-time.Hour)},
+			{UID: "old", OrgID: mainOrg.ID, Title: "Old", Created: now.Add(-48 * time.Hour)},
+			{UID: "folder", OrgID: mainOrg.ID, Title: "Folder", Created: now, IsFolder: true},
+			{UID: "deleted", OrgID: otherOrg.ID, Title: "Deleted", Created: now, Deleted: now},
+		}
+		for _, dash := range dashes {
+			dash.Slug = dash.UID
+			dash.Updated = dash.Created
+			dash.Data = simplejson.NewFromAny(map[string]any{"uid": dash.UID, "title": dash.Title})
+			if _, err := sess.Insert(dash); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	t.Run("should count the active users which aren't service accounts", func(t *testing.T) {
+		active, err := store.activeUsers(ctx, since)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), active.Total)
+		assert.Equal(t, []OrgCount{
+			{OrgID: mainOrg.ID, OrgName: "Main", Count: 2},
+			{OrgID: otherOrg.ID, OrgName: "Other", Count: 1},
+		}, active.ByOrg)
+	})
+
+	t.Run("should count the dashboards which aren't folders nor deleted", func(t *testing.T) {
+		usage, err := store.dashboards(ctx, since)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), usage.Total)
+		assert.Equal(t, int64(3), usage.Created)
+		assert.Equal(t, []OrgCount{
+			{OrgID: mainOrg.ID, OrgName: "Main", Count: 2},
+			{OrgID: otherOrg.ID, OrgName: "Other", Count: 1},
+		}, usage.CreatedByOrg)
+	})
+
+	t.Run("should get the names of the orgs", func(t *testing.T) {
+		names, err := store.orgNames(ctx, []int64{mainOrg.ID, otherOrg.ID, 999})
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]string{mainOrg.ID: "Main", otherOrg.ID: "Other"}, names)
+
+		names, err = store.orgNames(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, names)
+	})
+
+	t.Run("should measure the storage", func(t *testing.T) {
+		storage, err := store.storage(ctx)
+		require.NoError(t, err)
+		assert.Positive(t, storage.DashboardsBytes)
+		assert.Zero(t, storage.DashboardVersions)
+		assert.Zero(t, storage.Annotations)
+		assert.GreaterOrEqual(t, storage.DatabaseBytes, int64(0))
+	})
+}
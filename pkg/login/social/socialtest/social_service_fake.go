@@ -7,10 +7,11 @@ import (
 )
 
 type FakeSocialService struct {
-	ExpectedAuthInfoProvider *social.OAuthInfo
-	ExpectedOAuthProviders   map[string]bool
-	ExpectedConnector        social.SocialConnector
-	ExpectedHttpClient       *http.Client
+	ExpectedAuthInfoProvider  *social.OAuthInfo
+	ExpectedAuthInfoProviders map[string]*social.OAuthInfo
+	ExpectedOAuthProviders    map[string]bool
+	ExpectedConnector         social.SocialConnector
+	ExpectedHttpClient        *http.Client
 }
 
 func (fss *FakeSocialService) GetOAuthProviders() map[string]bool {
@@ -30,5 +31,5 @@ func (fss *FakeSocialService) GetOAuthInfoProvider(string) *social.OAuthInfo {
 }
 
 func (fss *FakeSocialService) GetOAuthInfoProviders() map[string]*social.OAuthInfo {
-	panic("not implemented")
+	return fss.ExpectedAuthInfoProviders
 }
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// AuditLog records the requests that change Grafana once they have been handled.
+// Only the requests to the routes of an audited category are recorded.
+func AuditLog(auditLog auditlog.Service) web.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			action := auditlog.ActionForMethod(r.Method)
+			if action == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rw := web.Rw(w, r)
+			next.ServeHTTP(w, r)
+
+			// TODO: do not depend on web.Context from the future
+			route, ok := RouteOperationName(web.FromContext(r.Context()).Req)
+			if !ok {
+				return
+			}
+			category := auditlog.CategoryForRoute(route)
+			if category == "" {
+				return
+			}
+
+			c := contexthandler.FromContext(r.Context())
+			if c == nil || c.SignedInUser == nil {
+				return
+			}
+
+			status := rw.Status()
+			event := &auditlog.Event{
+				Timestamp:  time.Now(),
+				OrgID:      c.GetOrgID(),
+				ActorLogin: c.GetLogin(),
+				Category:   category,
+				Action:     action,
+				Route:      route,
+				Method:     r.Method,
+				Path:       r.URL.Path,
+				StatusCode: status,
+				Result:     auditlog.ResultSuccess,
+				ClientIP:   c.RemoteAddr(),
+				UserAgent:  r.UserAgent(),
+			}
+			if c.IsSignedIn || c.IsAnonymous {
+				event.ActorID = c.GetID()
+			}
+			if c.Impersonator != nil {
+				event.ImpersonatorLogin = c.Impersonator.Login
+			}
+			if status >= http.StatusBadRequest {
+				event.Result = auditlog.ResultFailure
+			}
+
+			auditLog.Record(r.Context(), event)
+		})
+	}
+}
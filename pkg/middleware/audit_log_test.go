@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	claims "github.com/grafana/authlib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/authn/authntest"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type fakeAuditLog struct {
+	events []*auditlog.Event
+}
+
+func (f *fakeAuditLog) Record(_ context.Context, event *auditlog.Event) {
+	f.events = append(f.events, event)
+}
+
+func (f *fakeAuditLog) Search(_ context.Context, _ *auditlog.SearchQuery) (*auditlog.SearchResult, error) {
+	return &auditlog.SearchResult{}, nil
+}
+
+func TestAuditLog(t *testing.T) {
+	setup := func(t *testing.T) (*fakeAuditLog, *web.Mux) {
+		auditLog := &fakeAuditLog{}
+		id := &authn.Identity{ID: "1", Type: claims.TypeUser, OrgID: 1, Login: "admin"}
+
+		m := web.New()
+		m.UseMiddleware(getContextHandler(t, setting.NewCfg(), &authntest.FakeService{ExpectedIdentity: id}).Middleware)
+		m.UseMiddleware(AuditLog(auditLog))
+		return auditLog, m
+	}
+	send := func(m *web.Mux, method, url string) {
+		req, err := http.NewRequest(method, url, nil)
+		require.NoError(t, err)
+		m.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	t.Run("should record changes to audited routes", func(t *testing.T) {
+		auditLog, m := setup(t)
+		m.Post("/api/dashboards/db", ProvideRouteOperationName("/api/dashboards/db"), func(c *contextmodel.ReqContext) {
+			c.Resp.WriteHeader(http.StatusForbidden)
+		})
+
+		send(m, http.MethodPost, "/api/dashboards/db")
+
+		require.Len(t, auditLog.events, 1)
+		event := auditLog.events[0]
+		assert.Equal(t, "user:1", event.ActorID)
+		assert.Equal(t, "admin", event.ActorLogin)
+		assert.Equal(t, int64(1), event.OrgID)
+		assert.Equal(t, auditlog.CategoryDashboards, event.Category)
+		assert.Equal(t, auditlog.ActionCreate, event.Action)
+		assert.Equal(t, "/api/dashboards/db", event.Route)
+		assert.Equal(t, http.StatusForbidden, event.StatusCode)
+		assert.Equal(t, auditlog.ResultFailure, event.Result)
+	})
+
+	t.Run("should not record reads", func(t *testing.T) {
+		auditLog, m := setup(t)
+		m.Get("/api/dashboards/uid/:uid", ProvideRouteOperationName("/api/dashboards/uid/:uid"), func(c *contextmodel.ReqContext) {})
+
+		send(m, http.MethodGet, "/api/dashboards/uid/abc")
+
+		assert.Empty(t, auditLog.events)
+	})
+
+	t.Run("should not record routes that are not audited", func(t *testing.T) {
+		auditLog, m := setup(t)
+		m.Post("/api/ds/query", ProvideRouteOperationName("/api/ds/query"), func(c *contextmodel.ReqContext) {})
+
+		send(m, http.MethodPost, "/api/ds/query")
+
+		assert.Empty(t, auditLog.events)
+	})
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/ratelimit"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// RateLimit rejects the requests to the HTTP API over the rate limits of their user, token, organization or route
+// group with a 429 Too Many Requests error. The RateLimit headers of the responses describe the limit with the fewest
+// remaining requests.
+func RateLimit(rateLimiter ratelimit.Service) web.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c := contexthandler.FromContext(r.Context())
+			if !strings.HasPrefix(r.URL.Path, "/api/") || c == nil || (!c.IsSignedIn && !c.IsAnonymous) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			usage, allowed := rateLimiter.Allow(r.Context(), c.SignedInUser, r)
+			if usage != nil {
+				reset := int64(math.Ceil(time.Until(usage.Reset).Seconds()))
+				w.Header().Set("RateLimit-Limit", strconv.FormatInt(usage.Limit, 10))
+				w.Header().Set("RateLimit-Remaining", strconv.FormatInt(usage.Remaining, 10))
+				w.Header().Set("RateLimit-Reset", strconv.FormatInt(reset, 10))
+				if !allowed {
+					w.Header().Set("Retry-After", strconv.FormatInt(reset, 10))
+				}
+			}
+			if !allowed {
+				c.WriteErr(ratelimit.ErrRateLimited.Errorf("rate limit %s exceeded", usage.Name))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	claims "github.com/grafana/authlib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/authn/authntest"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ratelimit"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type fakeRateLimiter struct {
+	usage   *ratelimit.Usage
+	allowed bool
+	paths   []string
+}
+
+func (f *fakeRateLimiter) Allow(_ context.Context, _ identity.Requester, req *http.Request) (*ratelimit.Usage, bool) {
+	f.paths = append(f.paths, req.URL.Path)
+	return f.usage, f.allowed
+}
+
+func (f *fakeRateLimiter) Usage(_ context.Context, _ identity.Requester, _ *http.Request) ([]ratelimit.Usage, error) {
+	return nil, nil
+}
+
+func TestRateLimit(t *testing.T) {
+	setup := func(t *testing.T, rateLimiter *fakeRateLimiter) *web.Mux {
+		id := &authn.Identity{ID: "1", Type: claims.TypeUser, OrgID: 1, Login: "admin"}
+
+		m := web.New()
+		m.UseMiddleware(getContextHandler(t, setting.NewCfg(), &authntest.FakeService{ExpectedIdentity: id}).Middleware)
+		m.UseMiddleware(RateLimit(rateLimiter))
+		m.Get("/api/search", func(c *contextmodel.ReqContext) {})
+		m.Get("/public/build/app.js", func(c *contextmodel.ReqContext) {})
+		return m
+	}
+	send := func(m *web.Mux, url string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		m.ServeHTTP(recorder, req)
+		return recorder
+	}
+	usage := func(remaining int64) *ratelimit.Usage {
+		return &ratelimit.Usage{Name: "user", Limit: 10, Used: 10 - remaining, Remaining: remaining, Reset: time.Now().Add(30 * time.Second)}
+	}
+
+	t.Run("should set the rate limit headers of allowed requests", func(t *testing.T) {
+		m := setup(t, &fakeRateLimiter{usage: usage(4), allowed: true})
+
+		resp := send(m, "/api/search")
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "10", resp.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "4", resp.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "30", resp.Header().Get("RateLimit-Reset"))
+		assert.Empty(t, resp.Header().Get("Retry-After"))
+	})
+
+	t.Run("should reject requests over the limit", func(t *testing.T) {
+		m := setup(t, &fakeRateLimiter{usage: usage(0), allowed: false})
+
+		resp := send(m, "/api/search")
+
+		assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+		assert.Equal(t, "0", resp.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "30", resp.Header().Get("Retry-After"))
+	})
+
+	t.Run("should not limit requests outside of the API", func(t *testing.T) {
+		rateLimiter := &fakeRateLimiter{usage: usage(0), allowed: false}
+		m := setup(t, rateLimiter)
+
+		resp := send(m, "/public/build/app.js")
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Empty(t, rateLimiter.paths)
+	})
+}
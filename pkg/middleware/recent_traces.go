@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/recenttraces"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// RecentTraces records the traces of the API requests once they have been handled. Only the sampled traces are
+// recorded since the others can't be found in the tracing backend.
+func RecentTraces(recentTraces recenttraces.Service) web.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			traceID := tracing.TraceIDFromContext(r.Context(), true)
+			if traceID == "" || !strings.HasPrefix(r.URL.Path, "/api/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rw := web.Rw(w, r)
+			start := time.Now()
+			next.ServeHTTP(w, r)
+
+			trace := &recenttraces.Trace{
+				TraceID:    traceID,
+				Timestamp:  start,
+				Method:     r.Method,
+				Path:       r.URL.Path,
+				StatusCode: rw.Status(),
+				Duration:   time.Since(start),
+			}
+			if trace.StatusCode == 0 {
+				trace.StatusCode = http.StatusOK
+			}
+			// TODO: do not depend on web.Context from the future
+			if route, ok := RouteOperationName(web.FromContext(r.Context()).Req); ok {
+				trace.Route = route
+			}
+
+			recentTraces.Record(r.Context(), trace)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/services/recenttraces"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type fakeRecentTraces struct {
+	traces []*recenttraces.Trace
+}
+
+func (f *fakeRecentTraces) Record(_ context.Context, trace *recenttraces.Trace) {
+	f.traces = append(f.traces, trace)
+}
+
+func (f *fakeRecentTraces) Recent(_ context.Context, _ *recenttraces.Query) []*recenttraces.Trace {
+	return f.traces
+}
+
+func TestRecentTraces(t *testing.T) {
+	traceID := trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	setup := func(sampled bool) (*fakeRecentTraces, *web.Mux) {
+		recentTraces := &fakeRecentTraces{}
+		spanContext := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: trace.SpanID{1}})
+		if sampled {
+			spanContext = spanContext.WithTraceFlags(trace.FlagsSampled)
+		}
+
+		m := web.New()
+		m.UseMiddleware(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				next.ServeHTTP(w, r.WithContext(trace.ContextWithSpanContext(r.Context(), spanContext)))
+			})
+		})
+		m.UseMiddleware(RecentTraces(recentTraces))
+		m.Get("/api/ds/query", ProvideRouteOperationName("/api/ds/query"), func(c *web.Context) {
+			c.Resp.WriteHeader(http.StatusBadGateway)
+		})
+		return recentTraces, m
+	}
+	send := func(m *web.Mux, url string) {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		m.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	t.Run("should record the traces of the API requests", func(t *testing.T) {
+		recentTraces, m := setup(true)
+
+		send(m, "/api/ds/query")
+
+		require.Len(t, recentTraces.traces, 1)
+		recorded := recentTraces.traces[0]
+		assert.Equal(t, traceID.String(), recorded.TraceID)
+		assert.Equal(t, "/api/ds/query", recorded.Route)
+		assert.Equal(t, http.StatusBadGateway, recorded.StatusCode)
+	})
+
+	t.Run("should not record traces that are not sampled", func(t *testing.T) {
+		recentTraces, m := setup(false)
+
+		send(m, "/api/ds/query")
+
+		assert.Empty(t, recentTraces.traces)
+	})
+}
@@ -116,68 +116,77 @@ func RequestMetrics(features featuremgmt.FeatureToggles, cfg *setting.Cfg, promR
 
 			elapsedTime := time.Since(now).Seconds()
 
+			var exemplar prometheus.Labels
 			if traceID := tracing.TraceIDFromContext(r.Context(), true); traceID != "" {
+				exemplar = prometheus.Labels{"traceID": traceID}
 				// Need to type-convert the Observer to an
 				// ExemplarObserver. This will always work for a
 				// HistogramVec.
-				histogram.(prometheus.ExemplarObserver).ObserveWithExemplar(
-					elapsedTime, prometheus.Labels{"traceID": traceID},
-				)
+				histogram.(prometheus.ExemplarObserver).ObserveWithExemplar(elapsedTime, exemplar)
 			} else {
 				histogram.Observe(elapsedTime)
 			}
 
 			switch {
 			case strings.HasPrefix(r.RequestURI, "/api/datasources/proxy"):
-				countProxyRequests(status)
+				countProxyRequests(status, exemplar)
 			case strings.HasPrefix(r.RequestURI, "/api/"):
-				countApiRequests(status)
+				countApiRequests(status, exemplar)
 			default:
-				countPageRequests(status)
+				countPageRequests(status, exemplar)
 			}
 		})
 	}
 }
 
-func countApiRequests(status int) {
+func countApiRequests(status int, exemplar prometheus.Labels) {
 	switch status {
 	case 200:
-		metrics.MApiStatus.WithLabelValues("200").Inc()
+		incWithExemplar(metrics.MApiStatus.WithLabelValues("200"), exemplar)
 	case 404:
-		metrics.MApiStatus.WithLabelValues("404").Inc()
+		incWithExemplar(metrics.MApiStatus.WithLabelValues("404"), exemplar)
 	case 500:
-		metrics.MApiStatus.WithLabelValues("500").Inc()
+		incWithExemplar(metrics.MApiStatus.WithLabelValues("500"), exemplar)
 	default:
-		metrics.MApiStatus.WithLabelValues("unknown").Inc()
+		incWithExemplar(metrics.MApiStatus.WithLabelValues("unknown"), exemplar)
 	}
 }
 
-func countPageRequests(status int) {
+func countPageRequests(status int, exemplar prometheus.Labels) {
 	switch status {
 	case 200:
-		metrics.MPageStatus.WithLabelValues("200").Inc()
+		incWithExemplar(metrics.MPageStatus.WithLabelValues("200"), exemplar)
 	case 404:
-		metrics.MPageStatus.WithLabelValues("404").Inc()
+		incWithExemplar(metrics.MPageStatus.WithLabelValues("404"), exemplar)
 	case 500:
-		metrics.MPageStatus.WithLabelValues("500").Inc()
+		incWithExemplar(metrics.MPageStatus.WithLabelValues("500"), exemplar)
 	default:
-		metrics.MPageStatus.WithLabelValues("unknown").Inc()
+		incWithExemplar(metrics.MPageStatus.WithLabelValues("unknown"), exemplar)
 	}
 }
 
-func countProxyRequests(status int) {
+func countProxyRequests(status int, exemplar prometheus.Labels) {
 	switch status {
 	case 200:
-		metrics.MProxyStatus.WithLabelValues("200").Inc()
+		incWithExemplar(metrics.MProxyStatus.WithLabelValues("200"), exemplar)
 	case 404:
-		metrics.MProxyStatus.WithLabelValues("400").Inc()
+		incWithExemplar(metrics.MProxyStatus.WithLabelValues("400"), exemplar)
 	case 500:
-		metrics.MProxyStatus.WithLabelValues("500").Inc()
+		incWithExemplar(metrics.MProxyStatus.WithLabelValues("500"), exemplar)
 	default:
-		metrics.MProxyStatus.WithLabelValues("unknown").Inc()
+		incWithExemplar(metrics.MProxyStatus.WithLabelValues("unknown"), exemplar)
 	}
 }
 
+// incWithExemplar increments the counter with the exemplar of the trace of the request, if it was sampled.
+func incWithExemplar(counter prometheus.Counter, exemplar prometheus.Labels) {
+	if adder, ok := counter.(prometheus.ExemplarAdder); ok && exemplar != nil {
+		adder.AddWithExemplar(1, exemplar)
+		return
+	}
+	counter.Inc()
+}
+
 // If the wrapped http.Handler has not set a status code, i.e. the value is
 // currently 0, sanitizeCode will return 200, for consistency with behavior in
 // the stdlib.
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	claims "github.com/grafana/authlib/types"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// tenantQueryPaths are the prefixes of the paths of the requests counted as data source queries
+var tenantQueryPaths = []string{"/api/ds/query", "/api/datasources/proxy/"}
+
+// TenantMetrics counts the API requests of the signed in users in the metrics of their organization.
+func TenantMetrics(cfg *setting.Cfg, promRegister prometheus.Registerer) web.Middleware {
+	tenantMetrics := metrics.NewTenantMetrics(cfg.TenantMetrics, promRegister)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c := contexthandler.FromContext(r.Context())
+			if !strings.HasPrefix(r.URL.Path, "/api/") || c == nil || !c.IsSignedIn || c.GetOrgID() <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rw := web.Rw(w, r)
+			next.ServeHTTP(w, r)
+
+			req := metrics.TenantRequest{
+				OrgID:      c.GetOrgID(),
+				Teams:      c.GetTeams(),
+				StatusCode: rw.Status(),
+			}
+			if c.IsIdentityType(claims.TypeUser) {
+				req.UserID = c.GetID()
+			}
+			for _, prefix := range tenantQueryPaths {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					req.Query = true
+				}
+			}
+			tenantMetrics.ObserveRequest(req)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package annotation
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/registry/rest"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/kube-openapi/pkg/common"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	annotation "github.com/grafana/grafana/pkg/apis/annotation/v0alpha1"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/apiserver/builder"
+)
+
+var _ builder.APIGroupBuilder = (*AnnotationAPIBuilder)(nil)
+
+// AnnotationAPIBuilder exposes the annotations stored in unified storage, so they can be managed with
+// the same tooling as the dashboards and folders
+type AnnotationAPIBuilder struct {
+	accessControl accesscontrol.AccessControl
+}
+
+func RegisterAPIService(apiregistration builder.APIRegistrar, accessControl accesscontrol.AccessControl) *AnnotationAPIBuilder {
+	builder := &AnnotationAPIBuilder{
+		accessControl: accessControl,
+	}
+	apiregistration.RegisterAPI(builder)
+	return builder
+}
+
+func (b *AnnotationAPIBuilder) GetGroupVersion() schema.GroupVersion {
+	return annotation.SchemeGroupVersion
+}
+
+func (b *AnnotationAPIBuilder) InstallSchema(scheme *runtime.Scheme) error {
+	gv := annotation.SchemeGroupVersion
+	err := annotation.AddToScheme(scheme)
+	if err != nil {
+		return err
+	}
+
+	// Accept the field selectors on the spec fields
+	gvk := annotation.AnnotationResourceInfo.GroupVersionKind()
+	err = scheme.AddFieldLabelConversionFunc(gvk, func(label, value string) (string, string, error) {
+		if label == "metadata.name" || label == "metadata.namespace" {
+			return label, value, nil
+		}
+		for _, field := range annotation.SelectableFields {
+			if field == label {
+				return label, value, nil
+			}
+		}
+		return "", "", fmt.Errorf("field label not supported for %s: %s", gvk, label)
+	})
+	if err != nil {
+		return err
+	}
+
+	metav1.AddToGroupVersion(scheme, gv)
+	return scheme.SetVersionPriority(gv)
+}
+
+// AllowedV0Alpha1Resources returns no resources, the annotations are only available with the experimental APIs
+func (b *AnnotationAPIBuilder) AllowedV0Alpha1Resources() []string {
+	return nil
+}
+
+func (b *AnnotationAPIBuilder) UpdateAPIGroupInfo(apiGroupInfo *genericapiserver.APIGroupInfo, opts builder.APIGroupOptions) error {
+	resourceInfo := annotation.AnnotationResourceInfo
+	storage := map[string]rest.Storage{}
+
+	store, err := newStorage(opts.Scheme, opts.OptsGetter)
+	if err != nil {
+		return err
+	}
+	storage[resourceInfo.StoragePath()] = store
+
+	apiGroupInfo.VersionedResourcesStorageMap[annotation.VERSION] = storage
+	return nil
+}
+
+func (b *AnnotationAPIBuilder) GetOpenAPIDefinitions() common.GetOpenAPIDefinitions {
+	return annotation.GetOpenAPIDefinitions
+}
+
+func (b *AnnotationAPIBuilder) GetAuthorizer() authorizer.Authorizer {
+	return authorizer.AuthorizerFunc(
+		func(ctx context.Context, attr authorizer.Attributes) (authorized authorizer.Decision, reason string, err error) {
+			if !attr.IsResourceRequest() {
+				return authorizer.DecisionNoOpinion, "", nil
+			}
+			user, err := identity.GetRequester(ctx)
+			if err != nil {
+				return authorizer.DecisionDeny, "valid user is required", err
+			}
+
+			action := ""
+			switch attr.GetVerb() {
+			case "get", "list", "watch":
+				action = accesscontrol.ActionAnnotationsRead
+			case "create":
+				action = accesscontrol.ActionAnnotationsCreate
+			case "update", "patch":
+				action = accesscontrol.ActionAnnotationsWrite
+			case "delete", "deletecollection":
+				action = accesscontrol.ActionAnnotationsDelete
+			default:
+				return authorizer.DecisionDeny, "unsupported verb", nil
+			}
+
+			// The annotation permissions are scoped by annotation type, any scope is enough
+			ok, err := b.accessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(action))
+			if !ok || err != nil {
+				return authorizer.DecisionDeny, fmt.Sprintf("unable to %s", action), err
+			}
+			return authorizer.DecisionAllow, "", nil
+		})
+}
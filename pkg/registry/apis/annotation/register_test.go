@@ -0,0 +1,96 @@
+package annotation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	annotation "github.com/grafana/grafana/pkg/apis/annotation/v0alpha1"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+)
+
+func TestSelectors(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, (&AnnotationAPIBuilder{}).InstallSchema(scheme))
+
+	gvk := annotation.AnnotationResourceInfo.GroupVersionKind()
+	_, _, err := scheme.ConvertFieldLabel(gvk, "spec.dashboardUID", "abc")
+	require.NoError(t, err)
+	_, _, err = scheme.ConvertFieldLabel(gvk, "spec.text", "abc")
+	require.Error(t, err)
+
+	a := &annotation.Annotation{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default", Labels: map[string]string{"team": "sre"}},
+		Spec:       annotation.AnnotationSpec{Text: "deploy", Time: 1000, DashboardUID: "abc", PanelID: 2},
+	}
+	for _, tc := range []struct {
+		label   string
+		field   string
+		matches bool
+	}{
+		{label: "team=sre", matches: true},
+		{label: "team=dev", matches: false},
+		{field: "spec.dashboardUID=abc,spec.panelID=2", matches: true},
+		{field: "spec.dashboardUID=abc,spec.panelID=3", matches: false},
+		{label: "team=sre", field: "spec.dashboardUID!=abc", matches: false},
+	} {
+		label, err := labels.Parse(tc.label)
+		require.NoError(t, err)
+		field, err := fields.ParseSelector(tc.field)
+		require.NoError(t, err)
+
+		ok, err := matcher(label, field).Matches(a)
+		require.NoError(t, err)
+		require.Equal(t, tc.matches, ok, "label: %s, field: %s", tc.label, tc.field)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := annotation.AnnotationSpec{Text: "deploy", Time: 1000, TimeEnd: 2000, DashboardUID: "abc", PanelID: 2}
+	require.Empty(t, validate(&annotation.Annotation{Spec: valid}))
+
+	for name, spec := range map[string]annotation.AnnotationSpec{
+		"missing time":            {Text: "deploy"},
+		"end before time":         {Text: "deploy", Time: 2000, TimeEnd: 1000},
+		"panel without dashboard": {Text: "deploy", Time: 1000, PanelID: 2},
+	} {
+		require.NotEmpty(t, validate(&annotation.Annotation{Spec: spec}), name)
+	}
+}
+
+func TestAuthorizer(t *testing.T) {
+	b := &AnnotationAPIBuilder{accessControl: acimpl.ProvideAccessControl(featuremgmt.WithFeatures())}
+	user := &identity.StaticRequester{
+		OrgID: 1,
+		Permissions: map[int64]map[string][]string{
+			1: {accesscontrol.ActionAnnotationsRead: {accesscontrol.ScopeAnnotationsTypeDashboard}},
+		},
+	}
+	ctx := identity.WithRequester(context.Background(), user)
+
+	for verb, decision := range map[string]authorizer.Decision{
+		"get":    authorizer.DecisionAllow,
+		"list":   authorizer.DecisionAllow,
+		"watch":  authorizer.DecisionAllow,
+		"create": authorizer.DecisionDeny,
+		"update": authorizer.DecisionDeny,
+		"delete": authorizer.DecisionDeny,
+	} {
+		d, _, _ := b.GetAuthorizer().Authorize(ctx, authorizer.AttributesRecord{
+			Verb:            verb,
+			APIGroup:        annotation.GROUP,
+			Resource:        annotation.AnnotationResourceInfo.GroupResource().Resource,
+			ResourceRequest: true,
+		})
+		require.Equal(t, decision, d, verb)
+	}
+}
@@ -0,0 +1,70 @@
+package annotation
+
+import (
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/generic"
+	genericregistry "k8s.io/apiserver/pkg/registry/generic/registry"
+	"k8s.io/apiserver/pkg/storage"
+
+	annotation "github.com/grafana/grafana/pkg/apis/annotation/v0alpha1"
+	grafanaregistry "github.com/grafana/grafana/pkg/apiserver/registry/generic"
+	grafanarest "github.com/grafana/grafana/pkg/apiserver/rest"
+)
+
+var _ grafanarest.Storage = (*annotationStorage)(nil)
+
+type annotationStorage struct {
+	*genericregistry.Store
+}
+
+func newStorage(scheme *runtime.Scheme, optsGetter generic.RESTOptionsGetter) (*annotationStorage, error) {
+	resourceInfo := annotation.AnnotationResourceInfo
+	strategy := newStrategy(scheme, resourceInfo.GroupVersion())
+
+	store := &genericregistry.Store{
+		NewFunc:                   resourceInfo.NewFunc,
+		NewListFunc:               resourceInfo.NewListFunc,
+		KeyRootFunc:               grafanaregistry.KeyRootFunc(resourceInfo.GroupResource()),
+		KeyFunc:                   grafanaregistry.NamespaceKeyFunc(resourceInfo.GroupResource()),
+		PredicateFunc:             matcher,
+		DefaultQualifiedResource:  resourceInfo.GroupResource(),
+		SingularQualifiedResource: resourceInfo.SingularGroupResource(),
+		TableConvertor:            resourceInfo.TableConverter(),
+		CreateStrategy:            strategy,
+		UpdateStrategy:            strategy,
+		DeleteStrategy:            strategy,
+	}
+	options := &generic.StoreOptions{RESTOptions: optsGetter, AttrFunc: getAttrs}
+	if err := store.CompleteWithOptions(options); err != nil {
+		return nil, err
+	}
+	return &annotationStorage{Store: store}, nil
+}
+
+// matcher returns a generic.SelectionPredicate that matches on label and field selectors, including the spec fields
+func matcher(label labels.Selector, field fields.Selector) storage.SelectionPredicate {
+	return storage.SelectionPredicate{
+		Label:    label,
+		Field:    field,
+		GetAttrs: getAttrs,
+	}
+}
+
+func getAttrs(obj runtime.Object) (labels.Set, fields.Set, error) {
+	a, ok := obj.(*annotation.Annotation)
+	if !ok {
+		return nil, nil, fmt.Errorf("expected annotation")
+	}
+	fieldsSet := fields.Set{
+		"metadata.name":      a.Name,
+		"metadata.namespace": a.Namespace,
+		"spec.dashboardUID":  a.Spec.DashboardUID,
+		"spec.panelID":       strconv.FormatInt(a.Spec.PanelID, 10),
+	}
+	return labels.Set(a.Labels), fieldsSet, nil
+}
@@ -0,0 +1,56 @@
+package annotation
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	annotation "github.com/grafana/grafana/pkg/apis/annotation/v0alpha1"
+	grafanaregistry "github.com/grafana/grafana/pkg/apiserver/registry/generic"
+)
+
+type genericStrategy interface {
+	rest.RESTCreateStrategy
+	rest.RESTUpdateStrategy
+	rest.RESTDeleteStrategy
+}
+
+type annotationStrategy struct {
+	genericStrategy
+}
+
+func newStrategy(typer runtime.ObjectTyper, gv schema.GroupVersion) *annotationStrategy {
+	return &annotationStrategy{grafanaregistry.NewStrategy(typer, gv)}
+}
+
+func (s *annotationStrategy) Validate(ctx context.Context, obj runtime.Object) field.ErrorList {
+	return validate(obj)
+}
+
+func (s *annotationStrategy) ValidateUpdate(ctx context.Context, obj, old runtime.Object) field.ErrorList {
+	return validate(obj)
+}
+
+func validate(obj runtime.Object) field.ErrorList {
+	a, ok := obj.(*annotation.Annotation)
+	if !ok {
+		return field.ErrorList{field.InternalError(nil, fmt.Errorf("expected annotation"))}
+	}
+
+	path := field.NewPath("spec")
+	errs := field.ErrorList{}
+	if a.Spec.Time <= 0 {
+		errs = append(errs, field.Required(path.Child("time"), "the time must be set"))
+	}
+	if a.Spec.TimeEnd != 0 && a.Spec.TimeEnd < a.Spec.Time {
+		errs = append(errs, field.Invalid(path.Child("timeEnd"), a.Spec.TimeEnd, "the end time must not be before the time"))
+	}
+	if a.Spec.PanelID != 0 && a.Spec.DashboardUID == "" {
+		errs = append(errs, field.Required(path.Child("dashboardUID"), "the dashboard must be set for panel annotations"))
+	}
+	return errs
+}
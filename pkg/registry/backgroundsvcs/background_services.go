@@ -7,45 +7,71 @@ import (
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	uss "github.com/grafana/grafana/pkg/infra/usagestats/service"
 	"github.com/grafana/grafana/pkg/infra/usagestats/statscollector"
+	"github.com/grafana/grafana/pkg/infra/usagestats/usagereport"
 	"github.com/grafana/grafana/pkg/registry"
 	apiregistry "github.com/grafana/grafana/pkg/registry/apis"
 	appregistry "github.com/grafana/grafana/pkg/registry/apps"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/customroles"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/dualwrite"
 	"github.com/grafana/grafana/pkg/services/anonymous/anonimpl"
 	grafanaapiserver "github.com/grafana/grafana/pkg/services/apiserver"
 	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/auth/jwt"
 	"github.com/grafana/grafana/pkg/services/authn/authnimpl"
+	"github.com/grafana/grafana/pkg/services/authpolicy/authpolicyimpl"
+	"github.com/grafana/grafana/pkg/services/cacheinvalidation/cacheinvalidationimpl"
+	"github.com/grafana/grafana/pkg/services/capabilitytoken/capabilitytokenimpl"
 	"github.com/grafana/grafana/pkg/services/cleanup"
 	"github.com/grafana/grafana/pkg/services/cloudmigration"
+	"github.com/grafana/grafana/pkg/services/correlations"
 	"github.com/grafana/grafana/pkg/services/dashboards/service"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/featuremgmt/runtimetoggles"
 	"github.com/grafana/grafana/pkg/services/grpcserver"
+	"github.com/grafana/grafana/pkg/services/instancesync"
+	"github.com/grafana/grafana/pkg/services/ipallowlist/ipallowlistimpl"
 	ldapapi "github.com/grafana/grafana/pkg/services/ldap/api"
+	"github.com/grafana/grafana/pkg/services/ldap/ldapsync"
 	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/live/pushhttp"
 	"github.com/grafana/grafana/pkg/services/loginattempt/loginattemptimpl"
 	"github.com/grafana/grafana/pkg/services/ngalert"
 	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org/orglifecycle"
+	"github.com/grafana/grafana/pkg/services/playlist/playlistv2"
 	plugindashboardsservice "github.com/grafana/grafana/pkg/services/plugindashboards/service"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/angulardetectorsprovider"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/keyretriever/dynamic"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginexternal"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugininstaller"
 	pluginStore "github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/profiling/profilingimpl"
 	"github.com/grafana/grafana/pkg/services/provisioning"
 	publicdashboardsmetric "github.com/grafana/grafana/pkg/services/publicdashboards/metric"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/rendering/rendercache"
+	"github.com/grafana/grafana/pkg/services/resourcewatch"
+	"github.com/grafana/grafana/pkg/services/savedsearch/savedsearchimpl"
+	"github.com/grafana/grafana/pkg/services/scim"
 	"github.com/grafana/grafana/pkg/services/searchV2"
+	"github.com/grafana/grafana/pkg/services/secretaccess/secretaccessimpl"
 	secretsMigrations "github.com/grafana/grafana/pkg/services/secrets/kvstore/migrations"
 	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	samanager "github.com/grafana/grafana/pkg/services/serviceaccounts/manager"
+	"github.com/grafana/grafana/pkg/services/sqlstore/dbcopy"
+	"github.com/grafana/grafana/pkg/services/sqlstore/outbox"
+	"github.com/grafana/grafana/pkg/services/sqlstore/sqlitebackup"
 	"github.com/grafana/grafana/pkg/services/ssosettings"
 	"github.com/grafana/grafana/pkg/services/ssosettings/ssosettingsimpl"
 	"github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/services/supportbundles/supportbundlesimpl"
 	"github.com/grafana/grafana/pkg/services/team/teamapi"
+	"github.com/grafana/grafana/pkg/services/teamsync"
+	"github.com/grafana/grafana/pkg/services/tokenusage/tokenusageimpl"
 	"github.com/grafana/grafana/pkg/services/updatemanager"
+	"github.com/grafana/grafana/pkg/services/useractivity/useractivityimpl"
+	"github.com/grafana/grafana/pkg/services/webhooks/webhooksimpl"
 )
 
 func ProvideBackgroundServiceRegistry(
@@ -69,12 +95,31 @@ func ProvideBackgroundServiceRegistry(
 	appRegistry *appregistry.Service,
 	pluginDashboardUpdater *plugindashboardsservice.DashboardUpdater,
 	dashboardServiceImpl *service.DashboardServiceImpl,
+	ldapSync *ldapsync.Service,
+	jwtAuthService *jwt.AuthService,
+	auditLog *auditlogimpl.Service,
+	customRoles *customroles.Service,
+	tokenUsage *tokenusageimpl.Service,
+	savedSearch *savedsearchimpl.Service,
+	dbCopy *dbcopy.Service,
+	sqliteBackup *sqlitebackup.Service,
+	eventOutbox *outbox.Service,
+	instanceSync *instancesync.Service,
+	secretAccess *secretaccessimpl.Service,
+	renderCache *rendercache.Service,
+	webhooks *webhooksimpl.Service,
+	profiling *profilingimpl.Service,
+	teamSync *teamsync.Service,
+	correlationSuggestions *correlations.CorrelationsService,
 	// Need to make sure these are initialized, is there a better place to put them?
 	_ dashboardsnapshots.Service,
 	_ serviceaccounts.Service,
 	_ *grpcserver.HealthService, _ *grpcserver.ReflectionService,
-	_ *ldapapi.Service, _ *apiregistry.Service, _ auth.IDService, _ *teamapi.TeamAPI, _ ssosettings.Service,
-	_ cloudmigration.Service, _ authnimpl.Registration,
+	_ *ldapapi.Service, _ *apiregistry.Service, _ auth.IDService, _ *teamapi.TeamAPI, _ *scim.API, _ ssosettings.Service,
+	_ cloudmigration.Service, _ authnimpl.Registration, _ *ipallowlistimpl.Service, _ *capabilitytokenimpl.Service,
+	_ *authpolicyimpl.Service, _ *resourcewatch.Service, _ *orglifecycle.Service, _ *playlistv2.Service,
+	cacheInvalidation *cacheinvalidationimpl.Service, runtimeToggles *runtimetoggles.Service,
+	usageReport *usagereport.Service, userActivity *useractivityimpl.Service,
 ) *BackgroundServiceRegistry {
 	return NewBackgroundServiceRegistry(
 		httpServer,
@@ -115,6 +160,26 @@ func ProvideBackgroundServiceRegistry(
 		appRegistry,
 		pluginDashboardUpdater,
 		dashboardServiceImpl,
+		ldapSync,
+		jwtAuthService,
+		auditLog,
+		customRoles,
+		tokenUsage,
+		savedSearch,
+		dbCopy,
+		sqliteBackup,
+		eventOutbox,
+		instanceSync,
+		secretAccess,
+		renderCache,
+		webhooks,
+		profiling,
+		teamSync,
+		correlationSuggestions,
+		cacheInvalidation,
+		runtimeToggles,
+		usageReport,
+		userActivity,
 	)
 }
 
@@ -185,7 +185,16 @@ func (s *ModuleServer) Run() error {
 		if err != nil {
 			return nil, err
 		}
-		return sql.ProvideUnifiedStorageGrpcService(s.cfg, s.features, nil, s.log, s.registerer, docBuilders, s.storageMetrics, s.indexMetrics, s.searchServerRing, s.MemberlistKVConfig)
+		svc, err := sql.ProvideUnifiedStorageGrpcService(s.cfg, s.features, nil, s.log, s.registerer, docBuilders, s.storageMetrics, s.indexMetrics, s.searchServerRing, s.MemberlistKVConfig)
+		if err != nil {
+			return nil, err
+		}
+		if s.httpServerRouter != nil {
+			s.httpServerRouter.Path("/search/consistency").Methods("GET").Handler(svc.IndexConsistencyHandler())
+			s.httpServerRouter.Path("/storage/usage").Methods("GET").Handler(svc.NamespaceUsageHandler())
+			s.httpServerRouter.Path("/storage/encryption").Methods("GET", "POST").Handler(svc.ReEncryptionHandler())
+		}
+		return svc, nil
 	})
 
 	m.RegisterModule(modules.ZanzanaServer, func() (services.Service, error) {
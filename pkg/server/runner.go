@@ -4,8 +4,10 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/team"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 
@@ -21,13 +23,16 @@ type Runner struct {
 	SecretsService              *manager.SecretsService
 	SecretsMigrator             secrets.Migrator
 	UserService                 user.Service
+	OrgService                  org.Service
+	TeamService                 team.Service
 	SecretsConsolidationService contracts.ConsolidationService
 }
 
 func NewRunner(cfg *setting.Cfg, sqlStore db.DB, settingsProvider setting.Provider,
 	encryptionService encryption.Internal, features featuremgmt.FeatureToggles,
 	secretsService *manager.SecretsService, secretsMigrator secrets.Migrator,
-	userService user.Service, secretsConsolidationService contracts.ConsolidationService,
+	userService user.Service, orgService org.Service, teamService team.Service,
+	secretsConsolidationService contracts.ConsolidationService,
 ) Runner {
 	return Runner{
 		Cfg:                         cfg,
@@ -38,6 +43,8 @@ func NewRunner(cfg *setting.Cfg, sqlStore db.DB, settingsProvider setting.Provid
 		SecretsMigrator:             secretsMigrator,
 		Features:                    features,
 		UserService:                 userService,
+		OrgService:                  orgService,
+		TeamService:                 teamService,
 		SecretsConsolidationService: secretsConsolidationService,
 	}
 }
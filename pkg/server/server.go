@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"path/filepath"
@@ -179,6 +180,25 @@ func (s *Server) Run() error {
 	return s.childRoutines.Wait()
 }
 
+// PlanProvisioning initializes the server and writes the changes the provisioners would make to w,
+// without applying them nor starting the background services.
+func (s *Server) PlanProvisioning(ctx context.Context, w io.Writer) error {
+	if err := s.Init(); err != nil {
+		return err
+	}
+
+	plans, err := s.provisioningService.Plan(ctx)
+	if err != nil {
+		return err
+	}
+	for _, p := range plans {
+		if err := p.WriteText(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Shutdown initiates Grafana graceful shutdown. This shuts down all
 // running background services. Since Run blocks Shutdown supposed to
 // be run from a separate goroutine.
@@ -33,6 +33,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	uss "github.com/grafana/grafana/pkg/infra/usagestats/service"
 	"github.com/grafana/grafana/pkg/infra/usagestats/statscollector"
+	"github.com/grafana/grafana/pkg/infra/usagestats/usagereport"
 	"github.com/grafana/grafana/pkg/infra/usagestats/validator"
 	"github.com/grafana/grafana/pkg/login/social"
 	"github.com/grafana/grafana/pkg/login/social/connectors"
@@ -53,21 +54,33 @@ import (
 	appregistry "github.com/grafana/grafana/pkg/registry/apps"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/customroles"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/dualwrite"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/ossaccesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/permreg"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
 	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationingest"
 	"github.com/grafana/grafana/pkg/services/annotations/annotationsimpl"
 	"github.com/grafana/grafana/pkg/services/anonymous/anonimpl/anonstore"
 	"github.com/grafana/grafana/pkg/services/apikey/apikeyimpl"
 	grafanaapiserver "github.com/grafana/grafana/pkg/services/apiserver"
 	"github.com/grafana/grafana/pkg/services/apiserver/standalone"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/services/auditlog/auditlogimpl"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/auth/idimpl"
 	"github.com/grafana/grafana/pkg/services/auth/jwt"
 	"github.com/grafana/grafana/pkg/services/authn/authnimpl"
+	"github.com/grafana/grafana/pkg/services/authpolicy"
+	"github.com/grafana/grafana/pkg/services/authpolicy/authpolicyimpl"
 	"github.com/grafana/grafana/pkg/services/authz"
+	"github.com/grafana/grafana/pkg/services/branding"
+	"github.com/grafana/grafana/pkg/services/branding/brandingimpl"
+	"github.com/grafana/grafana/pkg/services/cacheinvalidation"
+	"github.com/grafana/grafana/pkg/services/cacheinvalidation/cacheinvalidationimpl"
+	"github.com/grafana/grafana/pkg/services/capabilitytoken"
+	"github.com/grafana/grafana/pkg/services/capabilitytoken/capabilitytokenimpl"
 	"github.com/grafana/grafana/pkg/services/cleanup"
 	"github.com/grafana/grafana/pkg/services/cloudmigration/cloudmigrationimpl"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
@@ -87,16 +100,27 @@ import (
 	"github.com/grafana/grafana/pkg/services/dsquerierclient"
 	"github.com/grafana/grafana/pkg/services/encryption"
 	encryptionservice "github.com/grafana/grafana/pkg/services/encryption/service"
+	"github.com/grafana/grafana/pkg/services/exploresession"
+	"github.com/grafana/grafana/pkg/services/exploresession/exploresessionimpl"
 	"github.com/grafana/grafana/pkg/services/extsvcauth"
 	extsvcreg "github.com/grafana/grafana/pkg/services/extsvcauth/registry"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/featuremgmt/runtimetoggles"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/folder/folderimpl"
 	"github.com/grafana/grafana/pkg/services/grpcserver"
 	grpccontext "github.com/grafana/grafana/pkg/services/grpcserver/context"
 	"github.com/grafana/grafana/pkg/services/grpcserver/interceptors"
+	"github.com/grafana/grafana/pkg/services/health"
+	"github.com/grafana/grafana/pkg/services/health/healthimpl"
 	"github.com/grafana/grafana/pkg/services/hooks"
+	"github.com/grafana/grafana/pkg/services/impersonation"
+	"github.com/grafana/grafana/pkg/services/impersonation/impersonationimpl"
+	"github.com/grafana/grafana/pkg/services/instancesync"
+	"github.com/grafana/grafana/pkg/services/ipallowlist"
+	"github.com/grafana/grafana/pkg/services/ipallowlist/ipallowlistimpl"
 	ldapapi "github.com/grafana/grafana/pkg/services/ldap/api"
+	"github.com/grafana/grafana/pkg/services/ldap/ldapsync"
 	ldapservice "github.com/grafana/grafana/pkg/services/ldap/service"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/librarypanels"
@@ -106,6 +130,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/login/authinfoimpl"
 	"github.com/grafana/grafana/pkg/services/loginattempt"
 	"github.com/grafana/grafana/pkg/services/loginattempt/loginattemptimpl"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/mfa/mfaimpl"
 	"github.com/grafana/grafana/pkg/services/navtree/navtreeimpl"
 	"github.com/grafana/grafana/pkg/services/ngalert"
 	ngimage "github.com/grafana/grafana/pkg/services/ngalert/image"
@@ -115,13 +141,17 @@ import (
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
 	"github.com/grafana/grafana/pkg/services/oauthtoken/oauthtokentest"
 	"github.com/grafana/grafana/pkg/services/org/orgimpl"
+	"github.com/grafana/grafana/pkg/services/org/orglifecycle"
 	"github.com/grafana/grafana/pkg/services/playlist/playlistimpl"
+	"github.com/grafana/grafana/pkg/services/playlist/playlistv2"
 	"github.com/grafana/grafana/pkg/services/plugindashboards"
 	plugindashboardsservice "github.com/grafana/grafana/pkg/services/plugindashboards/service"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration"
 	pluginDashboards "github.com/grafana/grafana/pkg/services/pluginsintegration/dashboards"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginaccesscontrol"
 	"github.com/grafana/grafana/pkg/services/preference/prefimpl"
+	"github.com/grafana/grafana/pkg/services/profiling"
+	"github.com/grafana/grafana/pkg/services/profiling/profilingimpl"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	publicdashboardsApi "github.com/grafana/grafana/pkg/services/publicdashboards/api"
 	publicdashboardsStore "github.com/grafana/grafana/pkg/services/publicdashboards/database"
@@ -130,10 +160,21 @@ import (
 	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
 	"github.com/grafana/grafana/pkg/services/quota/quotaimpl"
+	"github.com/grafana/grafana/pkg/services/ratelimit"
+	"github.com/grafana/grafana/pkg/services/ratelimit/ratelimitimpl"
+	"github.com/grafana/grafana/pkg/services/recenttraces"
+	"github.com/grafana/grafana/pkg/services/recenttraces/recenttracesimpl"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/rendering/rendercache"
+	"github.com/grafana/grafana/pkg/services/resourcewatch"
+	"github.com/grafana/grafana/pkg/services/savedsearch"
+	"github.com/grafana/grafana/pkg/services/savedsearch/savedsearchimpl"
+	"github.com/grafana/grafana/pkg/services/scim"
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/services/search/sort"
 	"github.com/grafana/grafana/pkg/services/searchV2"
+	"github.com/grafana/grafana/pkg/services/secretaccess"
+	"github.com/grafana/grafana/pkg/services/secretaccess/secretaccessimpl"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	secretsDatabase "github.com/grafana/grafana/pkg/services/secrets/database"
 	secretsStore "github.com/grafana/grafana/pkg/services/secrets/kvstore"
@@ -149,6 +190,9 @@ import (
 	"github.com/grafana/grafana/pkg/services/signingkeys"
 	"github.com/grafana/grafana/pkg/services/signingkeys/signingkeysimpl"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/dbcopy"
+	"github.com/grafana/grafana/pkg/services/sqlstore/outbox"
+	"github.com/grafana/grafana/pkg/services/sqlstore/sqlitebackup"
 	"github.com/grafana/grafana/pkg/services/sqlstore/sqlutil"
 	"github.com/grafana/grafana/pkg/services/ssosettings"
 	ssoSettingsImpl "github.com/grafana/grafana/pkg/services/ssosettings/ssosettingsimpl"
@@ -164,11 +208,18 @@ import (
 	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
 	"github.com/grafana/grafana/pkg/services/team/teamapi"
 	"github.com/grafana/grafana/pkg/services/team/teamimpl"
+	"github.com/grafana/grafana/pkg/services/teamsync"
 	tempuser "github.com/grafana/grafana/pkg/services/temp_user"
 	"github.com/grafana/grafana/pkg/services/temp_user/tempuserimpl"
+	"github.com/grafana/grafana/pkg/services/tokenusage"
+	"github.com/grafana/grafana/pkg/services/tokenusage/tokenusageimpl"
 	"github.com/grafana/grafana/pkg/services/updatemanager"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/services/user/userimpl"
+	"github.com/grafana/grafana/pkg/services/useractivity"
+	"github.com/grafana/grafana/pkg/services/useractivity/useractivityimpl"
+	"github.com/grafana/grafana/pkg/services/webhooks"
+	"github.com/grafana/grafana/pkg/services/webhooks/webhooksimpl"
 	"github.com/grafana/grafana/pkg/setting"
 	legacydualwrite "github.com/grafana/grafana/pkg/storage/legacysql/dualwrite"
 	secretdatabase "github.com/grafana/grafana/pkg/storage/secret/database"
@@ -219,6 +270,7 @@ var wireBasicSet = wire.NewSet(
 	wire.Bind(new(bus.Bus), new(*bus.InProcBus)),
 	rendering.ProvideService,
 	wire.Bind(new(rendering.Service), new(*rendering.RenderingService)),
+	rendercache.ProvideService,
 	routing.ProvideRegister,
 	wire.Bind(new(routing.RouteRegister), new(*routing.RouteRegisterImpl)),
 	hooks.ProvideService,
@@ -291,6 +343,11 @@ var wireBasicSet = wire.NewSet(
 	withOTelSet,
 	testdatasource.ProvideService,
 	ldapapi.ProvideService,
+	ldapsync.ProvideService,
+	teamsync.ProvideService,
+	instancesync.ProvideService,
+	orglifecycle.ProvideService,
+	playlistv2.ProvideService,
 	opentsdb.ProvideService,
 	socialimpl.ProvideService,
 	influxdb.ProvideService,
@@ -351,6 +408,7 @@ var wireBasicSet = wire.NewSet(
 	secretsStore.ProvideService,
 	avatar.ProvideAvatarCacheServer,
 	statscollector.ProvideService,
+	usagereport.ProvideService,
 	csrf.ProvideCSRFFilter,
 	wire.Bind(new(csrf.Service), new(*csrf.CSRF)),
 	ossaccesscontrol.ProvideTeamPermissions,
@@ -382,9 +440,53 @@ var wireBasicSet = wire.NewSet(
 	resolver.ProvideEntityReferenceResolver,
 	teamimpl.ProvideService,
 	teamapi.ProvideTeamAPI,
+	scim.ProvideAPI,
 	tempuserimpl.ProvideService,
 	loginattemptimpl.ProvideService,
 	wire.Bind(new(loginattempt.Service), new(*loginattemptimpl.Service)),
+	mfaimpl.ProvideService,
+	wire.Bind(new(mfa.Service), new(*mfaimpl.Service)),
+	impersonationimpl.ProvideService,
+	wire.Bind(new(impersonation.Service), new(*impersonationimpl.Service)),
+	ipallowlistimpl.ProvideService,
+	wire.Bind(new(ipallowlist.Service), new(*ipallowlistimpl.Service)),
+	capabilitytokenimpl.ProvideService,
+	wire.Bind(new(capabilitytoken.Service), new(*capabilitytokenimpl.Service)),
+	authpolicyimpl.ProvideService,
+	wire.Bind(new(authpolicy.Service), new(*authpolicyimpl.Service)),
+	tokenusageimpl.ProvideService,
+	wire.Bind(new(tokenusage.Service), new(*tokenusageimpl.Service)),
+	secretaccessimpl.ProvideService,
+	wire.Bind(new(secretaccess.Service), new(*secretaccessimpl.Service)),
+	webhooksimpl.ProvideService,
+	wire.Bind(new(webhooks.Service), new(*webhooksimpl.Service)),
+	savedsearchimpl.ProvideService,
+	wire.Bind(new(savedsearch.Service), new(*savedsearchimpl.Service)),
+	dbcopy.ProvideService,
+	sqlitebackup.ProvideService,
+	outbox.ProvideService,
+	resourcewatch.ProvideService,
+	cacheinvalidationimpl.ProvideService,
+	wire.Bind(new(cacheinvalidation.Service), new(*cacheinvalidationimpl.Service)),
+	runtimetoggles.ProvideService,
+	auditlogimpl.ProvideService,
+	wire.Bind(new(auditlog.Service), new(*auditlogimpl.Service)),
+	useractivityimpl.ProvideService,
+	wire.Bind(new(useractivity.Service), new(*useractivityimpl.Service)),
+	ratelimitimpl.ProvideService,
+	wire.Bind(new(ratelimit.Service), new(*ratelimitimpl.Service)),
+	recenttracesimpl.ProvideService,
+	wire.Bind(new(recenttraces.Service), new(*recenttracesimpl.Service)),
+	profilingimpl.ProvideService,
+	wire.Bind(new(profiling.Service), new(*profilingimpl.Service)),
+	healthimpl.ProvideService,
+	wire.Bind(new(health.Service), new(*healthimpl.Service)),
+	annotationingest.ProvideService,
+	exploresessionimpl.ProvideService,
+	wire.Bind(new(exploresession.Service), new(*exploresessionimpl.Service)),
+	brandingimpl.ProvideService,
+	wire.Bind(new(branding.Service), new(*brandingimpl.Service)),
+	customroles.ProvideService,
 	secretsMigrations.ProvideDataSourceMigrationService,
 	secretsMigrations.ProvideSecretMigrationProvider,
 	wire.Bind(new(secretsMigrations.SecretMigrationProvider), new(*secretsMigrations.SecretMigrationProviderImpl)),
@@ -90,6 +90,20 @@ func (a *AccessControl) RegisterScopeAttributeResolver(prefix string, resolver a
 	a.resolvers.AddScopeAttributeResolver(prefix, resolver)
 }
 
+// ResolveScope returns the scopes the scope resolves to with the registered attribute resolvers, e.g. the
+// dashboard and folder uid scopes of a dashboard id scope followed by the scopes of its parent folders.
+// It returns nil when no resolver is registered for the scope.
+func (a *AccessControl) ResolveScope(ctx context.Context, orgID int64, scope string) ([]string, error) {
+	scopes, err := a.resolvers.GetScopeAttributeMutator(orgID)(ctx, scope)
+	if err != nil {
+		if errors.Is(err, accesscontrol.ErrResolverNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return scopes, nil
+}
+
 func (a *AccessControl) WithoutResolvers() accesscontrol.AccessControl {
 	return &AccessControl{
 		features:  a.features,
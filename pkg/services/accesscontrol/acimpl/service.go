@@ -48,7 +48,7 @@ var SharedWithMeFolderPermission = accesscontrol.Permission{
 	Scope:  dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.SharedWithMeFolderUID),
 }
 
-var OSSRolesPrefixes = []string{accesscontrol.ManagedRolePrefix, accesscontrol.ExternalServiceRolePrefix}
+var OSSRolesPrefixes = []string{accesscontrol.ManagedRolePrefix, accesscontrol.ExternalServiceRolePrefix, accesscontrol.CustomRolePrefix, accesscontrol.TemporaryRolePrefix}
 
 func ProvideService(
 	cfg *setting.Cfg, db db.DB, routeRegister routing.RouteRegister, cache *localcache.CacheService,
@@ -453,6 +453,43 @@ func (s *Service) RegisterFixedRoles(ctx context.Context) error {
 	return nil
 }
 
+// GetFixedRoles returns the fixed roles declared to the service, with their permissions
+func (s *Service) GetFixedRoles() []accesscontrol.RoleDTO {
+	roles := make([]accesscontrol.RoleDTO, 0)
+	s.registrations.Range(func(registration accesscontrol.RoleRegistration) bool {
+		if registration.Role.IsFixed() {
+			role := registration.Role
+			if role.UID == "" {
+				role.UID = accesscontrol.PrefixedRoleUID(role.Name)
+			}
+			roles = append(roles, role)
+		}
+		return true
+	})
+	return roles
+}
+
+// GetBasicRoleGrants returns the fixed and plugin roles granted to the basic role, directly or through
+// a basic role it inherits from
+func (s *Service) GetBasicRoleGrants(basicRole string) []accesscontrol.RoleDTO {
+	roles := make([]accesscontrol.RoleDTO, 0)
+	s.registrations.Range(func(registration accesscontrol.RoleRegistration) bool {
+		for br := range accesscontrol.BuiltInRolesWithParents(registration.Grants) {
+			if br != basicRole {
+				continue
+			}
+			role := registration.Role
+			if role.UID == "" {
+				role.UID = accesscontrol.PrefixedRoleUID(role.Name)
+			}
+			roles = append(roles, role)
+			break
+		}
+		return true
+	})
+	return roles
+}
+
 // DeclarePluginRoles allow the caller to declare, to the service, plugin roles and their assignments
 // to organization roles ("Viewer", "Editor", "Admin") or "Grafana Admin"
 func (s *Service) DeclarePluginRoles(ctx context.Context, ID, name string, regs []plugins.RoleRegistration) error {
@@ -0,0 +1,47 @@
+package accesscontrol
+
+import "strings"
+
+// Conditions scope permissions on an attribute of the resources instead of their identifiers,
+// e.g. `dashboards:tag:team-a` grants an action on every dashboard tagged with `team-a` and
+// `datasources:type:prometheus` on every Prometheus data source.
+//
+// Condition scopes are stored with the role permissions like any other scope. They are evaluated
+// server-side once the scope of the resource is resolved by its ScopeAttributeResolver, which adds
+// the condition scopes matched by the resource to the resolved scopes.
+const (
+	ConditionAttributeTag  = "tag"
+	ConditionAttributeType = "type"
+)
+
+// conditionAttributes lists the condition attributes supported by each kind of resource
+var conditionAttributes = map[string][]string{
+	"dashboards":  {ConditionAttributeTag},
+	"datasources": {ConditionAttributeType},
+}
+
+// ConditionScopes returns the condition scopes matched by a resource, one per value of the attribute
+func ConditionScopes(kind, attribute string, values ...string) []string {
+	scopes := make([]string, 0, len(values))
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		scopes = append(scopes, Scope(kind, attribute, value))
+	}
+	return scopes
+}
+
+// IsConditionScope returns true if the scope grants access based on an attribute of the resources
+func IsConditionScope(scope string) bool {
+	kind, attribute, identifier := SplitScope(scope)
+	if identifier == "" || strings.Count(scope, ":") < 2 {
+		return false
+	}
+	for _, a := range conditionAttributes[kind] {
+		if a == attribute {
+			return true
+		}
+	}
+	return false
+}
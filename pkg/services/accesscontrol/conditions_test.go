@@ -0,0 +1,43 @@
+package accesscontrol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionScopes(t *testing.T) {
+	assert.Equal(t, []string{"dashboards:tag:team-a", "dashboards:tag:prod"}, ConditionScopes("dashboards", ConditionAttributeTag, "team-a", "", "prod"))
+	assert.Empty(t, ConditionScopes("dashboards", ConditionAttributeTag))
+}
+
+func TestIsConditionScope(t *testing.T) {
+	tests := []struct {
+		scope    string
+		expected bool
+	}{
+		{scope: "dashboards:tag:team-a", expected: true},
+		{scope: "datasources:type:prometheus", expected: true},
+		{scope: "dashboards:uid:abc", expected: false},
+		{scope: "datasources:tag:team-a", expected: false},
+		{scope: "dashboards:*", expected: false},
+		{scope: "*", expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsConditionScope(tt.scope))
+		})
+	}
+}
+
+func TestEvaluator_Conditions(t *testing.T) {
+	permissions := map[string][]string{"dashboards:read": {"dashboards:tag:team-a"}}
+	// the dashboard resolver adds the tags of the dashboard to the resolved scopes
+	tagged := []string{"dashboards:uid:abc", "folders:uid:general", "dashboards:tag:team-a"}
+	untagged := []string{"dashboards:uid:def", "folders:uid:general"}
+
+	assert.False(t, EvalPermission("dashboards:read", "dashboards:uid:abc").Evaluate(permissions))
+	assert.True(t, EvalPermission("dashboards:read", tagged...).Evaluate(permissions))
+	assert.False(t, EvalPermission("dashboards:read", untagged...).Evaluate(permissions))
+	assert.False(t, EvalPermission("dashboards:write", tagged...).Evaluate(permissions))
+}
@@ -0,0 +1,580 @@
+package customroles
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+
+	router.Group("/api/access-control", func(r routing.RouteRegister) {
+		r.Get("/roles", authorize(ac.EvalPermission(ac.ActionRolesRead, ac.ScopeRolesAll)), routing.Wrap(s.listRoles))
+		r.Get("/roles/:roleUID", authorize(ac.EvalPermission(ac.ActionRolesRead, ac.ScopeRolesUID)), routing.Wrap(s.getRole))
+		r.Post("/roles", authorize(ac.EvalPermission(ac.ActionRolesWrite, ac.ScopePermissionsDelegate)), routing.Wrap(s.createRole))
+		r.Put("/roles/:roleUID", authorize(ac.EvalPermission(ac.ActionRolesWrite, ac.ScopePermissionsDelegate)), routing.Wrap(s.updateRole))
+		r.Delete("/roles/:roleUID", authorize(ac.EvalPermission(ac.ActionRolesDelete, ac.ScopePermissionsDelegate)), routing.Wrap(s.deleteRole))
+
+		// Service accounts are assigned roles through the user endpoints
+		r.Get("/users/:userId/roles", authorize(ac.EvalPermission(ac.ActionUsersRolesRead, ac.ScopeUsersID)), routing.Wrap(s.getUserRoles))
+		r.Post("/users/:userId/roles", authorize(ac.EvalPermission(ac.ActionUsersRolesAdd, ac.ScopePermissionsDelegate)), routing.Wrap(s.addUserRole))
+		r.Put("/users/:userId/roles", authorize(ac.EvalAll(
+			ac.EvalPermission(ac.ActionUsersRolesAdd, ac.ScopePermissionsDelegate),
+			ac.EvalPermission(ac.ActionUsersRolesRemove, ac.ScopePermissionsDelegate),
+		)), routing.Wrap(s.setUserRoles))
+		r.Delete("/users/:userId/roles/:roleUID", authorize(ac.EvalPermission(ac.ActionUsersRolesRemove, ac.ScopePermissionsDelegate)), routing.Wrap(s.removeUserRole))
+
+		r.Get("/teams/:teamId/roles", authorize(ac.EvalPermission(ac.ActionTeamsRolesRead, ac.ScopeTeamsID)), routing.Wrap(s.getTeamRoles))
+		r.Post("/teams/:teamId/roles", authorize(ac.EvalPermission(ac.ActionTeamsRolesAdd, ac.ScopePermissionsDelegate)), routing.Wrap(s.addTeamRole))
+		r.Put("/teams/:teamId/roles", authorize(ac.EvalAll(
+			ac.EvalPermission(ac.ActionTeamsRolesAdd, ac.ScopePermissionsDelegate),
+			ac.EvalPermission(ac.ActionTeamsRolesRemove, ac.ScopePermissionsDelegate),
+		)), routing.Wrap(s.setTeamRoles))
+		r.Delete("/teams/:teamId/roles/:roleUID", authorize(ac.EvalPermission(ac.ActionTeamsRolesRemove, ac.ScopePermissionsDelegate)), routing.Wrap(s.removeTeamRole))
+
+		r.Post("/check", routing.Wrap(s.check))
+		r.Post("/explain", routing.Wrap(s.explain))
+
+		r.Get("/grants", authorize(ac.EvalPermission(ac.ActionRolesGrantsRead)), routing.Wrap(s.listGrants))
+		r.Get("/grants/:grantUID", authorize(ac.EvalPermission(ac.ActionRolesGrantsRead)), routing.Wrap(s.getGrant))
+		r.Post("/grants", routing.Wrap(s.requestGrant))
+		r.Post("/grants/:grantUID/approve", authorize(ac.EvalPermission(ac.ActionRolesGrantsApprove, ac.ScopePermissionsDelegate)), routing.Wrap(s.approveGrant))
+		r.Post("/grants/:grantUID/reject", authorize(ac.EvalPermission(ac.ActionRolesGrantsApprove, ac.ScopePermissionsDelegate)), routing.Wrap(s.rejectGrant))
+		r.Post("/grants/:grantUID/revoke", authorize(ac.EvalPermission(ac.ActionRolesGrantsApprove, ac.ScopePermissionsDelegate)), routing.Wrap(s.revokeGrant))
+	}, middleware.ReqSignedIn, requestmeta.SetOwner(requestmeta.TeamAuth))
+}
+
+// swagger:route GET /access-control/roles access_control listRoles
+//
+// Get all roles.
+//
+// Returns the custom roles of the current organization followed by the fixed roles.
+//
+// Responses:
+// 200: listRolesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) listRoles(c *contextmodel.ReqContext) response.Response {
+	roles, err := s.ListRoles(c.Req.Context(), c.GetOrgID(), c.QueryBool("includeHidden"))
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list roles", err)
+	}
+	return response.JSON(http.StatusOK, roles)
+}
+
+// swagger:route GET /access-control/roles/{roleUID} access_control getRole
+//
+// Get a role.
+//
+// Responses:
+// 200: getRoleResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) getRole(c *contextmodel.ReqContext) response.Response {
+	role, err := s.GetRole(c.Req.Context(), c.GetOrgID(), web.Params(c.Req)[":roleUID"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get role", err)
+	}
+	return response.JSON(http.StatusOK, role)
+}
+
+// swagger:route POST /access-control/roles access_control createRole
+//
+// Create a new custom role.
+//
+// The name of the role must start with `custom:`. The permissions of the listed fixed roles are copied to the role,
+// the signed in user must have every permission of the role.
+//
+// Responses:
+// 201: getRoleResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) createRole(c *contextmodel.ReqContext) response.Response {
+	cmd := CreateRoleCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	role, err := s.CreateRole(c.Req.Context(), c.SignedInUser, cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create role", err)
+	}
+	return response.JSON(http.StatusCreated, role)
+}
+
+// swagger:route PUT /access-control/roles/{roleUID} access_control updateRole
+//
+// Update a custom role.
+//
+// The version of the role must be incremented, the permissions of the role are replaced.
+//
+// Responses:
+// 200: getRoleResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) updateRole(c *contextmodel.ReqContext) response.Response {
+	cmd := UpdateRoleCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	role, err := s.UpdateRole(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":roleUID"], cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update role", err)
+	}
+	return response.JSON(http.StatusOK, role)
+}
+
+// swagger:route DELETE /access-control/roles/{roleUID} access_control deleteRole
+//
+// Delete a custom role.
+//
+// An assigned role is only deleted, along with its assignments, when force is set.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) deleteRole(c *contextmodel.ReqContext) response.Response {
+	if err := s.DeleteRole(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":roleUID"], c.QueryBool("force")); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete role", err)
+	}
+	return response.Success("Role deleted")
+}
+
+// swagger:route GET /access-control/users/{userId}/roles access_control listUserRoles
+//
+// List the custom roles assigned to a user or a service account.
+//
+// Responses:
+// 200: listRolesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) getUserRoles(c *contextmodel.ReqContext) response.Response {
+	userID, errResp := paramID(c, ":userId")
+	if errResp != nil {
+		return errResp
+	}
+
+	roles, err := s.GetUserRoles(c.Req.Context(), c.GetOrgID(), userID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list user roles", err)
+	}
+	return response.JSON(http.StatusOK, roles)
+}
+
+// swagger:route POST /access-control/users/{userId}/roles access_control addUserRole
+//
+// Assign a custom role to a user or a service account.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) addUserRole(c *contextmodel.ReqContext) response.Response {
+	userID, errResp := paramID(c, ":userId")
+	if errResp != nil {
+		return errResp
+	}
+	cmd := AddRoleAssignmentCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := s.AddUserRole(c.Req.Context(), c.SignedInUser, userID, cmd.RoleUID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to add user role", err)
+	}
+	return response.Success("Role added to the user")
+}
+
+// swagger:route PUT /access-control/users/{userId}/roles access_control setUserRoles
+//
+// Replace the custom roles assigned to a user or a service account.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) setUserRoles(c *contextmodel.ReqContext) response.Response {
+	userID, errResp := paramID(c, ":userId")
+	if errResp != nil {
+		return errResp
+	}
+	cmd := SetRoleAssignmentsCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := s.SetUserRoles(c.Req.Context(), c.SignedInUser, userID, cmd.RoleUIDs); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to set user roles", err)
+	}
+	return response.Success("User roles have been updated")
+}
+
+// swagger:route DELETE /access-control/users/{userId}/roles/{roleUID} access_control removeUserRole
+//
+// Remove a custom role from a user or a service account.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) removeUserRole(c *contextmodel.ReqContext) response.Response {
+	userID, errResp := paramID(c, ":userId")
+	if errResp != nil {
+		return errResp
+	}
+
+	if err := s.RemoveUserRole(c.Req.Context(), c.SignedInUser, userID, web.Params(c.Req)[":roleUID"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to remove user role", err)
+	}
+	return response.Success("Role removed from the user")
+}
+
+// swagger:route GET /access-control/teams/{teamId}/roles access_control listTeamRoles
+//
+// List the custom roles assigned to a team.
+//
+// Responses:
+// 200: listRolesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) getTeamRoles(c *contextmodel.ReqContext) response.Response {
+	teamID, errResp := paramID(c, ":teamId")
+	if errResp != nil {
+		return errResp
+	}
+
+	roles, err := s.GetTeamRoles(c.Req.Context(), c.GetOrgID(), teamID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list team roles", err)
+	}
+	return response.JSON(http.StatusOK, roles)
+}
+
+// swagger:route POST /access-control/teams/{teamId}/roles access_control addTeamRole
+//
+// Assign a custom role to a team.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) addTeamRole(c *contextmodel.ReqContext) response.Response {
+	teamID, errResp := paramID(c, ":teamId")
+	if errResp != nil {
+		return errResp
+	}
+	cmd := AddRoleAssignmentCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := s.AddTeamRole(c.Req.Context(), c.SignedInUser, teamID, cmd.RoleUID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to add team role", err)
+	}
+	return response.Success("Role added to the team")
+}
+
+// swagger:route PUT /access-control/teams/{teamId}/roles access_control setTeamRoles
+//
+// Replace the custom roles assigned to a team.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) setTeamRoles(c *contextmodel.ReqContext) response.Response {
+	teamID, errResp := paramID(c, ":teamId")
+	if errResp != nil {
+		return errResp
+	}
+	cmd := SetRoleAssignmentsCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := s.SetTeamRoles(c.Req.Context(), c.SignedInUser, teamID, cmd.RoleUIDs); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to set team roles", err)
+	}
+	return response.Success("Team roles have been updated")
+}
+
+// swagger:route DELETE /access-control/teams/{teamId}/roles/{roleUID} access_control removeTeamRole
+//
+// Remove a custom role from a team.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) removeTeamRole(c *contextmodel.ReqContext) response.Response {
+	teamID, errResp := paramID(c, ":teamId")
+	if errResp != nil {
+		return errResp
+	}
+
+	if err := s.RemoveTeamRole(c.Req.Context(), c.SignedInUser, teamID, web.Params(c.Req)[":roleUID"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to remove team role", err)
+	}
+	return response.Success("Role removed from the team")
+}
+
+// swagger:route POST /access-control/check access_control checkPermission
+//
+// Check a permission.
+//
+// Evaluates whether the signed in user, or the given user of the current organization, has the action on the scope.
+// Checking the permissions of another user requires `users.permissions:read` on that user.
+//
+// Responses:
+// 200: checkPermissionResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) check(c *contextmodel.ReqContext) response.Response {
+	cmd := CheckCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	result, err := s.Check(c.Req.Context(), c.SignedInUser, cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to check permission", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route POST /access-control/explain access_control explainPermission
+//
+// Explain a permission.
+//
+// Returns the evaluation trace of the action on the scope for the signed in user, or the given user of the current organization:
+// the grants of the roles bound to the user directly, through their teams and through their basic roles, the folders the scope
+// inherits permissions from and the grant that decides the evaluation.
+// Explaining the permissions of another user requires `users.permissions:read` on that user.
+//
+// Responses:
+// 200: explainPermissionResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) explain(c *contextmodel.ReqContext) response.Response {
+	cmd := ExplainCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	result, err := s.Explain(c.Req.Context(), c.SignedInUser, cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to explain permission", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func paramID(c *contextmodel.ReqContext, name string) (int64, response.Response) {
+	id, err := strconv.ParseInt(web.Params(c.Req)[name], 10, 64)
+	if err != nil {
+		return 0, response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	return id, nil
+}
+
+// swagger:parameters listRoles
+type ListRolesParams struct {
+	// in:query
+	// required:false
+	IncludeHidden bool `json:"includeHidden"`
+}
+
+// swagger:parameters getRole
+type GetRoleParams struct {
+	// in:path
+	// required:true
+	RoleUID string `json:"roleUID"`
+}
+
+// swagger:parameters createRole
+type CreateRoleParams struct {
+	// in:body
+	// required:true
+	Body CreateRoleCommand `json:"body"`
+}
+
+// swagger:parameters updateRole
+type UpdateRoleParams struct {
+	// in:path
+	// required:true
+	RoleUID string `json:"roleUID"`
+	// in:body
+	// required:true
+	Body UpdateRoleCommand `json:"body"`
+}
+
+// swagger:parameters deleteRole
+type DeleteRoleParams struct {
+	// in:path
+	// required:true
+	RoleUID string `json:"roleUID"`
+	// in:query
+	// required:false
+	Force bool `json:"force"`
+}
+
+// swagger:parameters listUserRoles
+type ListUserRolesParams struct {
+	// in:path
+	// required:true
+	UserID int64 `json:"userId"`
+}
+
+// swagger:parameters addUserRole
+type AddUserRoleParams struct {
+	// in:path
+	// required:true
+	UserID int64 `json:"userId"`
+	// in:body
+	// required:true
+	Body AddRoleAssignmentCommand `json:"body"`
+}
+
+// swagger:parameters setUserRoles
+type SetUserRolesParams struct {
+	// in:path
+	// required:true
+	UserID int64 `json:"userId"`
+	// in:body
+	// required:true
+	Body SetRoleAssignmentsCommand `json:"body"`
+}
+
+// swagger:parameters removeUserRole
+type RemoveUserRoleParams struct {
+	// in:path
+	// required:true
+	UserID int64 `json:"userId"`
+	// in:path
+	// required:true
+	RoleUID string `json:"roleUID"`
+}
+
+// swagger:parameters listTeamRoles
+type ListTeamRolesParams struct {
+	// in:path
+	// required:true
+	TeamID int64 `json:"teamId"`
+}
+
+// swagger:parameters addTeamRole
+type AddTeamRoleParams struct {
+	// in:path
+	// required:true
+	TeamID int64 `json:"teamId"`
+	// in:body
+	// required:true
+	Body AddRoleAssignmentCommand `json:"body"`
+}
+
+// swagger:parameters setTeamRoles
+type SetTeamRolesParams struct {
+	// in:path
+	// required:true
+	TeamID int64 `json:"teamId"`
+	// in:body
+	// required:true
+	Body SetRoleAssignmentsCommand `json:"body"`
+}
+
+// swagger:parameters removeTeamRole
+type RemoveTeamRoleParams struct {
+	// in:path
+	// required:true
+	TeamID int64 `json:"teamId"`
+	// in:path
+	// required:true
+	RoleUID string `json:"roleUID"`
+}
+
+// swagger:parameters checkPermission
+type CheckPermissionParams struct {
+	// in:body
+	// required:true
+	Body CheckCommand `json:"body"`
+}
+
+// swagger:parameters explainPermission
+type ExplainPermissionParams struct {
+	// in:body
+	// required:true
+	Body ExplainCommand `json:"body"`
+}
+
+// swagger:response listRolesResponse
+type ListRolesResponse struct {
+	// in: body
+	Body []ac.RoleDTO `json:"body"`
+}
+
+// swagger:response getRoleResponse
+type GetRoleResponse struct {
+	// in: body
+	Body ac.RoleDTO `json:"body"`
+}
+
+// swagger:response checkPermissionResponse
+type CheckPermissionResponse struct {
+	// in: body
+	Body CheckResult `json:"body"`
+}
+
+// swagger:response explainPermissionResponse
+type ExplainPermissionResponse struct {
+	// in: body
+	Body ExplainResult `json:"body"`
+}
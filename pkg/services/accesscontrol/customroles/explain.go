@@ -0,0 +1,185 @@
+package customroles
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+// grantSourcePriority orders the grants when picking the decisive one, the most specific binding first
+var grantSourcePriority = map[string]int{
+	GrantSourceUser:      0,
+	GrantSourceTeam:      1,
+	GrantSourceBasicRole: 2,
+}
+
+// Explain returns the evaluation trace of the permission for the requester, or for another user of the org
+// when cmd.UserID is set: the grants of the roles bound to the user, the scopes the requested scope
+// resolves to and the grant that decides the evaluation.
+func (s *Service) Explain(ctx context.Context, requester identity.Requester, cmd ExplainCommand) (*ExplainResult, error) {
+	ctx, span := s.tracer.Start(ctx, "customroles.Explain")
+	defer span.End()
+
+	if cmd.Action == "" {
+		return nil, ErrInvalidCheck.Errorf("action is required")
+	}
+
+	orgID := requester.GetOrgID()
+	if cmd.UserID == 0 {
+		self, err := requester.GetInternalID()
+		if err != nil {
+			return nil, err
+		}
+		cmd.UserID = self
+	}
+
+	permissions, err := s.getPermissions(ctx, requester, cmd.UserID, cmd.Action)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err := s.store.GetExplainSubject(ctx, orgID, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ExplainResult{
+		UserID:         cmd.UserID,
+		Login:          subject.Login,
+		BasicRoles:     subject.BasicRoles,
+		Teams:          subject.Teams,
+		Action:         cmd.Action,
+		Scope:          cmd.Scope,
+		ResolvedScopes: []string{},
+		FolderChain:    []string{},
+	}
+
+	result.Allowed, err = s.evaluate(ctx, orgID, cmd.Action, cmd.Scope, ac.GroupScopesByAction(permissions)[cmd.Action])
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.Scope != "" {
+		resolved, err := s.scopeResolver.ResolveScope(ctx, orgID, cmd.Scope)
+		if err != nil {
+			return nil, err
+		}
+		for _, scope := range resolved {
+			if scope == cmd.Scope {
+				continue
+			}
+			result.ResolvedScopes = append(result.ResolvedScopes, scope)
+			if strings.HasPrefix(scope, dashboards.ScopeFoldersPrefix) {
+				result.FolderChain = append(result.FolderChain, scope)
+			}
+		}
+	}
+
+	result.Grants, err = s.getGrants(ctx, orgID, cmd.UserID, subject, cmd.Action)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]*ExplainGrant, 0, len(result.Grants))
+	for i := range result.Grants {
+		grant := &result.Grants[i]
+		matchGrant(grant, cmd.Action, cmd.Scope, result.ResolvedScopes)
+		if grant.Matched {
+			matched = append(matched, grant)
+		}
+	}
+
+	if result.Allowed && len(matched) > 0 {
+		sort.SliceStable(matched, func(i, j int) bool {
+			if matched[i].Inherited != matched[j].Inherited {
+				return !matched[i].Inherited
+			}
+			return grantSourcePriority[matched[i].Source] < grantSourcePriority[matched[j].Source]
+		})
+		decisive := *matched[0]
+		result.Decisive = &decisive
+	}
+
+	return result, nil
+}
+
+// getGrants lists the permissions for the action, and the action sets including it, of the fixed roles granted
+// to the basic roles of the user and of the roles stored for the user, their teams and their basic roles
+func (s *Service) getGrants(ctx context.Context, orgID, userID int64, subject *explainSubject, action string) ([]ExplainGrant, error) {
+	actions := append([]string{action}, s.actionResolver.ResolveAction(action)...)
+	isRequested := func(a string) bool {
+		for _, requested := range actions {
+			if a == requested {
+				return true
+			}
+		}
+		return false
+	}
+
+	grants := make([]ExplainGrant, 0)
+	for _, basicRole := range subject.BasicRoles {
+		for _, role := range s.fixedRoles.GetBasicRoleGrants(basicRole) {
+			for _, p := range role.Permissions {
+				if isRequested(p.Action) {
+					grants = append(grants, ExplainGrant{
+						Source:    GrantSourceBasicRole,
+						BasicRole: basicRole,
+						RoleUID:   role.UID,
+						RoleName:  role.Name,
+						Action:    p.Action,
+						Scope:     p.Scope,
+					})
+				}
+			}
+		}
+	}
+
+	teamIDs := make([]int64, 0, len(subject.Teams))
+	teamNames := make(map[int64]string, len(subject.Teams))
+	for _, team := range subject.Teams {
+		teamIDs = append(teamIDs, team.ID)
+		teamNames[team.ID] = team.Name
+	}
+
+	stored, err := s.store.GetPermissionGrants(ctx, grantsQuery{
+		OrgID:      orgID,
+		UserID:     userID,
+		TeamIDs:    teamIDs,
+		BasicRoles: subject.BasicRoles,
+		Actions:    actions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, grant := range stored {
+		if grant.Source == GrantSourceTeam {
+			grant.TeamName = teamNames[grant.TeamID]
+		}
+		grants = append(grants, grant)
+	}
+
+	return grants, nil
+}
+
+// matchGrant marks the grant as matched when its scope covers the requested scope, or one of the scopes it resolves to
+func matchGrant(grant *ExplainGrant, action, scope string, resolved []string) {
+	granted := map[string][]string{action: {grant.Scope}}
+	if scope == "" {
+		grant.Matched = ac.EvalPermission(action).Evaluate(granted)
+		return
+	}
+	if ac.EvalPermission(action, scope).Evaluate(granted) {
+		grant.Matched, grant.MatchedScope = true, scope
+		return
+	}
+	for _, r := range resolved {
+		if ac.EvalPermission(action, r).Evaluate(granted) {
+			grant.Matched, grant.MatchedScope, grant.Inherited = true, r, true
+			return
+		}
+	}
+}
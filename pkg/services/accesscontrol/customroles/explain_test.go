@@ -0,0 +1,107 @@
+package customroles
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
+)
+
+func TestService_Explain(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T, permissions []ac.Permission, grants []ExplainGrant) *Service {
+		s, store := setupTestService(t)
+		s.acService = actest.FakeService{ExpectedPermissions: permissions}
+		s.scopeResolver = fakeScopeResolver{
+			"dashboards:uid:1": {"dashboards:uid:1", "folders:uid:child", "folders:uid:parent"},
+		}
+		store.subject = &explainSubject{
+			Login:      "viewer",
+			BasicRoles: []string{"Viewer"},
+			Teams:      []ExplainTeam{{ID: 3, Name: "editors"}},
+		}
+		store.explain = grants
+		return s
+	}
+
+	t.Run("should pick the direct grant over the inherited ones", func(t *testing.T) {
+		s := setup(t, []ac.Permission{
+			{Action: "dashboards:write", Scope: "folders:uid:parent"},
+			{Action: "dashboards:write", Scope: "dashboards:uid:1"},
+		}, []ExplainGrant{
+			{Source: GrantSourceTeam, TeamID: 3, RoleUID: "managed_team", RoleName: "managed:teams:3:permissions", Action: "dashboards:write", Scope: "folders:uid:parent"},
+			{Source: GrantSourceUser, RoleUID: "editor", RoleName: "custom:editor", Action: "dashboards:write", Scope: "dashboards:uid:1"},
+			{Source: GrantSourceUser, RoleUID: "other", RoleName: "custom:other", Action: "dashboards:write", Scope: "dashboards:uid:2"},
+		})
+
+		result, err := s.Explain(ctx, newRequester(nil), ExplainCommand{Action: "dashboards:write", Scope: "dashboards:uid:1"})
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, int64(1), result.UserID)
+		assert.Equal(t, []string{"folders:uid:child", "folders:uid:parent"}, result.ResolvedScopes)
+		assert.Equal(t, []string{"folders:uid:child", "folders:uid:parent"}, result.FolderChain)
+
+		require.Len(t, result.Grants, 3)
+		assert.Equal(t, "editors", result.Grants[0].TeamName)
+		assert.True(t, result.Grants[0].Matched)
+		assert.True(t, result.Grants[0].Inherited)
+		assert.Equal(t, "folders:uid:parent", result.Grants[0].MatchedScope)
+		assert.False(t, result.Grants[2].Matched)
+
+		require.NotNil(t, result.Decisive)
+		assert.Equal(t, "custom:editor", result.Decisive.RoleName)
+		assert.False(t, result.Decisive.Inherited)
+	})
+
+	t.Run("should attribute basic role grants to fixed roles", func(t *testing.T) {
+		s := setup(t, []ac.Permission{{Action: "dashboards:read", Scope: "dashboards:*"}}, nil)
+
+		result, err := s.Explain(ctx, newRequester(nil), ExplainCommand{Action: "dashboards:read", Scope: "dashboards:uid:1"})
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		require.NotNil(t, result.Decisive)
+		assert.Equal(t, GrantSourceBasicRole, result.Decisive.Source)
+		assert.Equal(t, "Viewer", result.Decisive.BasicRole)
+		assert.Equal(t, dashboardsReader.Name, result.Decisive.RoleName)
+	})
+
+	t.Run("should include the action sets of the action", func(t *testing.T) {
+		s := setup(t, []ac.Permission{{Action: "dashboards:write", Scope: "folders:uid:child"}}, []ExplainGrant{
+			{Source: GrantSourceBasicRole, BasicRole: "Viewer", RoleUID: "basic_viewer", RoleName: "managed:builtins:viewer:permissions", Action: "folders:edit", Scope: "folders:uid:child"},
+		})
+		s.actionResolver = &resourcepermissions.FakeActionSetSvc{ExpectedActionSets: []string{"folders:edit"}}
+
+		result, err := s.Explain(ctx, newRequester(nil), ExplainCommand{Action: "dashboards:write", Scope: "dashboards:uid:1"})
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		require.NotNil(t, result.Decisive)
+		assert.Equal(t, "folders:edit", result.Decisive.Action)
+		assert.Equal(t, "folders:uid:child", result.Decisive.MatchedScope)
+		assert.True(t, result.Decisive.Inherited)
+	})
+
+	t.Run("should not have a decisive grant when denied", func(t *testing.T) {
+		s := setup(t, nil, []ExplainGrant{
+			{Source: GrantSourceUser, RoleUID: "other", RoleName: "custom:other", Action: "dashboards:delete", Scope: "dashboards:uid:2"},
+		})
+
+		result, err := s.Explain(ctx, newRequester(nil), ExplainCommand{Action: "dashboards:delete", Scope: "dashboards:uid:1"})
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Len(t, result.Grants, 1)
+		assert.Nil(t, result.Decisive)
+	})
+
+	t.Run("should require users.permissions:read to explain another user", func(t *testing.T) {
+		s := setup(t, nil, nil)
+
+		_, err := s.Explain(ctx, newRequester(nil), ExplainCommand{UserID: 2, Action: "dashboards:read"})
+		assert.ErrorIs(t, err, ErrCheckForbidden)
+	})
+}
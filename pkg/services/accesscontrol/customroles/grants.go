@@ -0,0 +1,212 @@
+package customroles
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// Run revokes the temporary grants once they expire
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.RBAC.TemporaryGrantsRevokeInterval)
+	defer ticker.Stop()
+
+	for {
+		s.expireGrants(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) expireGrants(ctx context.Context) {
+	ctx, span := s.tracer.Start(ctx, "customroles.expireGrants")
+	defer span.End()
+
+	expired, err := s.store.ExpireGrants(ctx, time.Now())
+	if err != nil {
+		s.log.Error("Failed to revoke expired temporary grants", "error", err)
+		return
+	}
+	for _, grant := range expired {
+		s.log.Info("Revoked expired temporary grant", "orgId", grant.OrgID, "uid", grant.UID, "userId", grant.UserID)
+		s.clearUserCache(grant.OrgID, grant.UserID)
+	}
+}
+
+func (s *Service) ListGrants(ctx context.Context, query ListGrantsQuery) ([]*TemporaryGrant, error) {
+	return s.store.ListGrants(ctx, query)
+}
+
+func (s *Service) GetGrant(ctx context.Context, orgID int64, uid string) (*TemporaryGrant, error) {
+	return s.store.GetGrant(ctx, orgID, uid)
+}
+
+// RequestGrant records a temporary grant for the requester, or for another user of the org when cmd.UserID is set.
+// The grant is active right away when approvals aren't required and the requester is allowed to approve it,
+// it is pending otherwise.
+func (s *Service) RequestGrant(ctx context.Context, requester identity.Requester, cmd CreateGrantCommand) (*TemporaryGrant, error) {
+	ctx, span := s.tracer.Start(ctx, "customroles.RequestGrant")
+	defer span.End()
+
+	requesterID, err := requester.GetInternalID()
+	if err != nil {
+		return nil, err
+	}
+	if cmd.UserID == 0 {
+		cmd.UserID = requesterID
+	}
+	if cmd.UserID != requesterID {
+		// Requesting a grant for another user requires the same permission as assigning them a role
+		allowed, err := s.accessControl.Evaluate(ctx, requester, ac.EvalPermission(ac.ActionUsersRolesAdd, ac.ScopePermissionsDelegate))
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, ErrPermissionEscalation.Errorf("not allowed to request a temporary grant for user %d", cmd.UserID)
+		}
+	}
+
+	now := time.Now()
+	if !cmd.Expires.After(now) {
+		return nil, ErrInvalidGrant.Errorf("expires must be in the future")
+	}
+	if cmd.Expires.Sub(now) > s.cfg.RBAC.TemporaryGrantsMaxDuration {
+		return nil, ErrInvalidGrant.Errorf("temporary grants can't last longer than %s", s.cfg.RBAC.TemporaryGrantsMaxDuration)
+	}
+
+	permissions, err := s.grantPermissions(ctx, requester.GetOrgID(), cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	grant := &TemporaryGrant{
+		OrgID:       requester.GetOrgID(),
+		UID:         util.GenerateShortUID(),
+		UserID:      cmd.UserID,
+		RoleUID:     cmd.RoleUID,
+		Permissions: permissions,
+		Reason:      cmd.Reason,
+		State:       GrantStatePending,
+		RequestedBy: requesterID,
+		Expires:     cmd.Expires,
+		Created:     now,
+		Updated:     now,
+	}
+	if err := s.store.CreateGrant(ctx, grant); err != nil {
+		return nil, err
+	}
+
+	if !s.cfg.RBAC.TemporaryGrantsApprovalRequired && s.canApprove(ctx, requester, grant) == nil {
+		if err := s.store.ActivateGrant(ctx, grant, requesterID); err != nil {
+			return nil, err
+		}
+		s.clearUserCache(grant.OrgID, grant.UserID)
+	}
+
+	return grant, nil
+}
+
+// ApproveGrant activates a pending grant, the approver must hold the permissions of the grant
+func (s *Service) ApproveGrant(ctx context.Context, requester identity.Requester, uid string) (*TemporaryGrant, error) {
+	ctx, span := s.tracer.Start(ctx, "customroles.ApproveGrant")
+	defer span.End()
+
+	grant, err := s.store.GetGrant(ctx, requester.GetOrgID(), uid)
+	if err != nil {
+		return nil, err
+	}
+	if grant.State != GrantStatePending {
+		return nil, ErrGrantState.Errorf("temporary grant %s is %s", uid, grant.State)
+	}
+	if !grant.Expires.After(time.Now()) {
+		return nil, ErrGrantState.Errorf("temporary grant %s has expired", uid)
+	}
+
+	approverID, err := requester.GetInternalID()
+	if err != nil {
+		return nil, err
+	}
+	if s.cfg.RBAC.TemporaryGrantsApprovalRequired && (grant.RequestedBy == approverID || grant.UserID == approverID) {
+		return nil, ErrSelfApproval.Errorf("user %d can't approve temporary grant %s", approverID, uid)
+	}
+	if err := s.canApprove(ctx, requester, grant); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.ActivateGrant(ctx, grant, approverID); err != nil {
+		return nil, err
+	}
+	s.clearUserCache(grant.OrgID, grant.UserID)
+	return grant, nil
+}
+
+// RejectGrant rejects a pending grant
+func (s *Service) RejectGrant(ctx context.Context, requester identity.Requester, uid string) (*TemporaryGrant, error) {
+	grant, err := s.store.GetGrant(ctx, requester.GetOrgID(), uid)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewerID, err := requester.GetInternalID()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.UpdateGrantState(ctx, grant, GrantStatePending, GrantStateRejected, reviewerID); err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+// RevokeGrant revokes an active grant before it expires
+func (s *Service) RevokeGrant(ctx context.Context, requester identity.Requester, uid string) (*TemporaryGrant, error) {
+	grant, err := s.store.GetGrant(ctx, requester.GetOrgID(), uid)
+	if err != nil {
+		return nil, err
+	}
+
+	// the approver of the grant is kept, revocations are recorded by the audit log
+	if err := s.store.UpdateGrantState(ctx, grant, GrantStateActive, GrantStateRevoked, grant.ReviewedBy); err != nil {
+		return nil, err
+	}
+	s.clearUserCache(grant.OrgID, grant.UserID)
+	return grant, nil
+}
+
+// grantPermissions returns the permissions of the role of the grant, or its explicit permissions
+func (s *Service) grantPermissions(ctx context.Context, orgID int64, cmd CreateGrantCommand) ([]ac.Permission, error) {
+	if (cmd.RoleUID == "") == (len(cmd.Permissions) == 0) {
+		return nil, ErrInvalidGrant.Errorf("either a role or permissions must be granted")
+	}
+
+	if cmd.RoleUID != "" {
+		role, err := s.GetRole(ctx, orgID, cmd.RoleUID)
+		if err != nil {
+			return nil, err
+		}
+		if len(role.Permissions) == 0 {
+			return nil, ErrInvalidGrant.Errorf("role %s has no permissions", cmd.RoleUID)
+		}
+		return role.Permissions, nil
+	}
+
+	return s.resolvePermissions(nil, cmd.Permissions)
+}
+
+// canApprove checks the requester is allowed to approve grants and holds the permissions of the grant
+func (s *Service) canApprove(ctx context.Context, requester identity.Requester, grant *TemporaryGrant) error {
+	allowed, err := s.accessControl.Evaluate(ctx, requester, ac.EvalPermission(ac.ActionRolesGrantsApprove, ac.ScopePermissionsDelegate))
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrPermissionEscalation.Errorf("missing permission %s", ac.ActionRolesGrantsApprove)
+	}
+	return s.checkDelegation(ctx, requester, grant.Permissions)
+}
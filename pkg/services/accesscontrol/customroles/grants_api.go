@@ -0,0 +1,177 @@
+package customroles
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /access-control/grants access_control listTemporaryGrants
+//
+// Get temporary grants.
+//
+// Returns the temporary grants of the current organization, most recent first.
+// Filter on the `active` state to list the ongoing elevations.
+//
+// Responses:
+// 200: listTemporaryGrantsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) listGrants(c *contextmodel.ReqContext) response.Response {
+	grants, err := s.ListGrants(c.Req.Context(), ListGrantsQuery{
+		OrgID:  c.GetOrgID(),
+		UserID: c.QueryInt64("userId"),
+		State:  c.Query("state"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list temporary grants", err)
+	}
+	return response.JSON(http.StatusOK, grants)
+}
+
+// swagger:route GET /access-control/grants/{grantUID} access_control getTemporaryGrant
+//
+// Get a temporary grant.
+//
+// Responses:
+// 200: temporaryGrantResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) getGrant(c *contextmodel.ReqContext) response.Response {
+	grant, err := s.GetGrant(c.Req.Context(), c.GetOrgID(), web.Params(c.Req)[":grantUID"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get temporary grant", err)
+	}
+	return response.JSON(http.StatusOK, grant)
+}
+
+// swagger:route POST /access-control/grants access_control requestTemporaryGrant
+//
+// Request a temporary grant.
+//
+// Requests a role, or a set of permissions, for the signed in user or another user of the current organization until the grant expires.
+// The grant is active right away when approvals are not required and the signed in user is allowed to approve it, it is pending otherwise.
+// Requesting a grant for another user requires `users.roles:add`.
+//
+// Responses:
+// 200: temporaryGrantResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) requestGrant(c *contextmodel.ReqContext) response.Response {
+	cmd := CreateGrantCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	grant, err := s.RequestGrant(c.Req.Context(), c.SignedInUser, cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to request temporary grant", err)
+	}
+	return response.JSON(http.StatusOK, grant)
+}
+
+// swagger:route POST /access-control/grants/{grantUID}/approve access_control approveTemporaryGrant
+//
+// Approve a temporary grant.
+//
+// Activates a pending grant. The approver must hold the permissions of the grant.
+//
+// Responses:
+// 200: temporaryGrantResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) approveGrant(c *contextmodel.ReqContext) response.Response {
+	grant, err := s.ApproveGrant(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":grantUID"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to approve temporary grant", err)
+	}
+	return response.JSON(http.StatusOK, grant)
+}
+
+// swagger:route POST /access-control/grants/{grantUID}/reject access_control rejectTemporaryGrant
+//
+// Reject a temporary grant.
+//
+// Responses:
+// 200: temporaryGrantResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) rejectGrant(c *contextmodel.ReqContext) response.Response {
+	grant, err := s.RejectGrant(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":grantUID"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to reject temporary grant", err)
+	}
+	return response.JSON(http.StatusOK, grant)
+}
+
+// swagger:route POST /access-control/grants/{grantUID}/revoke access_control revokeTemporaryGrant
+//
+// Revoke a temporary grant.
+//
+// Removes the permissions of an active grant before it expires.
+//
+// Responses:
+// 200: temporaryGrantResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) revokeGrant(c *contextmodel.ReqContext) response.Response {
+	grant, err := s.RevokeGrant(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":grantUID"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to revoke temporary grant", err)
+	}
+	return response.JSON(http.StatusOK, grant)
+}
+
+// swagger:parameters listTemporaryGrants
+type ListTemporaryGrantsParams struct {
+	// in:query
+	// required:false
+	UserID int64 `json:"userId"`
+	// in:query
+	// required:false
+	// enum: pending,active,rejected,expired,revoked
+	State string `json:"state"`
+}
+
+// swagger:parameters getTemporaryGrant approveTemporaryGrant rejectTemporaryGrant revokeTemporaryGrant
+type TemporaryGrantParams struct {
+	// in:path
+	// required:true
+	GrantUID string `json:"grantUID"`
+}
+
+// swagger:parameters requestTemporaryGrant
+type RequestTemporaryGrantParams struct {
+	// in:body
+	// required:true
+	Body CreateGrantCommand `json:"body"`
+}
+
+// swagger:response listTemporaryGrantsResponse
+type ListTemporaryGrantsResponse struct {
+	// in: body
+	Body []*TemporaryGrant `json:"body"`
+}
+
+// swagger:response temporaryGrantResponse
+type TemporaryGrantResponse struct {
+	// in: body
+	Body TemporaryGrant `json:"body"`
+}
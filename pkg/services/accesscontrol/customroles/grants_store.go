@@ -0,0 +1,233 @@
+package customroles
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+// temporaryGrant is the stored form of a TemporaryGrant, its permissions are encoded as JSON
+type temporaryGrant struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	OrgID         int64  `xorm:"org_id"`
+	UID           string `xorm:"uid"`
+	UserID        int64  `xorm:"user_id"`
+	RoleUID       string `xorm:"role_uid"`
+	Permissions   string
+	Reason        string
+	State         string
+	RequestedBy   int64 `xorm:"requested_by"`
+	ReviewedBy    int64 `xorm:"reviewed_by"`
+	GrantedRoleID int64 `xorm:"granted_role_id"`
+	Expires       time.Time
+	Created       time.Time
+	Updated       time.Time
+}
+
+func (g *temporaryGrant) toGrant() (*TemporaryGrant, error) {
+	grant := &TemporaryGrant{
+		ID:            g.ID,
+		OrgID:         g.OrgID,
+		UID:           g.UID,
+		UserID:        g.UserID,
+		RoleUID:       g.RoleUID,
+		Reason:        g.Reason,
+		State:         g.State,
+		RequestedBy:   g.RequestedBy,
+		ReviewedBy:    g.ReviewedBy,
+		GrantedRoleID: g.GrantedRoleID,
+		Expires:       g.Expires,
+		Created:       g.Created,
+		Updated:       g.Updated,
+	}
+	if err := json.Unmarshal([]byte(g.Permissions), &grant.Permissions); err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+func (s *xormStore) CreateGrant(ctx context.Context, grant *TemporaryGrant) error {
+	permissions, err := json.Marshal(grant.Permissions)
+	if err != nil {
+		return err
+	}
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if err := checkUserInOrg(sess, grant.OrgID, grant.UserID); err != nil {
+			return err
+		}
+
+		stored := temporaryGrant{
+			OrgID:       grant.OrgID,
+			UID:         grant.UID,
+			UserID:      grant.UserID,
+			RoleUID:     grant.RoleUID,
+			Permissions: string(permissions),
+			Reason:      grant.Reason,
+			State:       grant.State,
+			RequestedBy: grant.RequestedBy,
+			Expires:     grant.Expires,
+			Created:     grant.Created,
+			Updated:     grant.Updated,
+		}
+		if _, err := sess.Table("temporary_grant").Insert(&stored); err != nil {
+			return err
+		}
+		grant.ID = stored.ID
+		return nil
+	})
+}
+
+func (s *xormStore) GetGrant(ctx context.Context, orgID int64, uid string) (*TemporaryGrant, error) {
+	var grant *TemporaryGrant
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var stored temporaryGrant
+		has, err := sess.Table("temporary_grant").Where("org_id = ? AND uid = ?", orgID, uid).Get(&stored)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrGrantNotFound.Errorf("temporary grant %s not found", uid)
+		}
+		grant, err = stored.toGrant()
+		return err
+	})
+	return grant, err
+}
+
+func (s *xormStore) ListGrants(ctx context.Context, query ListGrantsQuery) ([]*TemporaryGrant, error) {
+	grants := make([]*TemporaryGrant, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table("temporary_grant").Where("org_id = ?", query.OrgID)
+		if query.UserID != 0 {
+			q = q.And("user_id = ?", query.UserID)
+		}
+		if query.State != "" {
+			q = q.And("state = ?", query.State)
+		}
+
+		stored := make([]temporaryGrant, 0)
+		if err := q.Desc("created").Find(&stored); err != nil {
+			return err
+		}
+		for i := range stored {
+			grant, err := stored[i].toGrant()
+			if err != nil {
+				return err
+			}
+			grants = append(grants, grant)
+		}
+		return nil
+	})
+	return grants, err
+}
+
+func (s *xormStore) ActivateGrant(ctx context.Context, grant *TemporaryGrant, reviewedBy int64) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		now := time.Now()
+		role := ac.Role{
+			OrgID:       grant.OrgID,
+			Version:     1,
+			UID:         "temporary_" + grant.UID,
+			Name:        ac.TemporaryRolePrefix + grant.UID,
+			Description: grant.Reason,
+			Hidden:      true,
+			Created:     now,
+			Updated:     now,
+		}
+		if _, err := sess.Table("role").Insert(&role); err != nil {
+			return err
+		}
+		if err := insertPermissions(sess, role.ID, grant.Permissions, now); err != nil {
+			return err
+		}
+		if err := addUserRole(sess, grant.OrgID, grant.UserID, role.ID); err != nil {
+			return err
+		}
+
+		if err := updateGrantState(sess, grant, GrantStatePending, GrantStateActive, reviewedBy, role.ID, now); err != nil {
+			return err
+		}
+		grant.GrantedRoleID = role.ID
+		return nil
+	})
+}
+
+func (s *xormStore) UpdateGrantState(ctx context.Context, grant *TemporaryGrant, from, to string, reviewedBy int64) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if from == GrantStateActive {
+			if err := deleteGrantedRole(sess, grant.GrantedRoleID); err != nil {
+				return err
+			}
+		}
+		return updateGrantState(sess, grant, from, to, reviewedBy, 0, time.Now())
+	})
+}
+
+func (s *xormStore) ExpireGrants(ctx context.Context, now time.Time) ([]*TemporaryGrant, error) {
+	expired := make([]*TemporaryGrant, 0)
+	err := s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		active := make([]temporaryGrant, 0)
+		if err := sess.Table("temporary_grant").Where("state = ? AND expires <= ?", GrantStateActive, now).Find(&active); err != nil {
+			return err
+		}
+		for i := range active {
+			grant, err := active[i].toGrant()
+			if err != nil {
+				return err
+			}
+			if err := deleteGrantedRole(sess, grant.GrantedRoleID); err != nil {
+				return err
+			}
+			if err := updateGrantState(sess, grant, GrantStateActive, GrantStateExpired, grant.ReviewedBy, 0, now); err != nil {
+				return err
+			}
+			expired = append(expired, grant)
+		}
+
+		_, err := sess.Exec("UPDATE temporary_grant SET state = ?, updated = ? WHERE state = ? AND expires <= ?",
+			GrantStateExpired, now, GrantStatePending, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
+
+// updateGrantState moves the grant to the new state, it fails when the grant isn't in the expected state anymore
+func updateGrantState(sess *db.Session, grant *TemporaryGrant, from, to string, reviewedBy, grantedRoleID int64, now time.Time) error {
+	affected, err := sess.Table("temporary_grant").
+		Where("id = ? AND state = ?", grant.ID, from).
+		Cols("state", "reviewed_by", "granted_role_id", "updated").
+		Update(&temporaryGrant{State: to, ReviewedBy: reviewedBy, GrantedRoleID: grantedRoleID, Updated: now})
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrGrantState.Errorf("temporary grant %s is not %s", grant.UID, from)
+	}
+
+	grant.State = to
+	grant.ReviewedBy = reviewedBy
+	grant.Updated = now
+	return nil
+}
+
+func deleteGrantedRole(sess *db.Session, roleID int64) error {
+	if roleID == 0 {
+		return nil
+	}
+	for _, q := range []string{
+		"DELETE FROM user_role WHERE role_id = ?",
+		"DELETE FROM permission WHERE role_id = ?",
+		"DELETE FROM role WHERE id = ?",
+	} {
+		if _, err := sess.Exec(q, roleID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
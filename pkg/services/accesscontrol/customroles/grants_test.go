@@ -0,0 +1,115 @@
+package customroles
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+func TestService_RequestGrant(t *testing.T) {
+	ctx := context.Background()
+	expires := time.Now().Add(30 * time.Minute)
+	approver := newRequester(map[string][]string{
+		ac.ActionRolesGrantsApprove: {ac.ScopePermissionsDelegate},
+		"dashboards:read":           {"dashboards:*"},
+		"folders:read":              {"folders:*"},
+	})
+
+	setup := func(t *testing.T) (*Service, *fakeStore) {
+		s, store := setupTestService(t)
+		s.cfg.RBAC.TemporaryGrantsMaxDuration = time.Hour
+		return s, store
+	}
+
+	t.Run("should activate the grant when the requester can approve it", func(t *testing.T) {
+		s, _ := setup(t)
+
+		grant, err := s.RequestGrant(ctx, approver, CreateGrantCommand{RoleUID: dashboardsReader.UID, Expires: expires})
+		require.NoError(t, err)
+		assert.Equal(t, GrantStateActive, grant.State)
+		assert.Equal(t, int64(1), grant.UserID)
+		assert.Equal(t, int64(1), grant.ReviewedBy)
+		assert.ElementsMatch(t, dashboardsReader.Permissions, grant.Permissions)
+	})
+
+	t.Run("should keep the grant pending when approvals are required", func(t *testing.T) {
+		s, _ := setup(t)
+		s.cfg.RBAC.TemporaryGrantsApprovalRequired = true
+
+		grant, err := s.RequestGrant(ctx, approver, CreateGrantCommand{RoleUID: dashboardsReader.UID, Expires: expires})
+		require.NoError(t, err)
+		assert.Equal(t, GrantStatePending, grant.State)
+
+		_, err = s.ApproveGrant(ctx, approver, grant.UID)
+		assert.ErrorIs(t, err, ErrSelfApproval)
+
+		other := newRequester(approver.Permissions[1])
+		other.UserID = 2
+		grant, err = s.ApproveGrant(ctx, other, grant.UID)
+		require.NoError(t, err)
+		assert.Equal(t, GrantStateActive, grant.State)
+		assert.Equal(t, int64(2), grant.ReviewedBy)
+	})
+
+	t.Run("should require the approver to hold the permissions", func(t *testing.T) {
+		s, _ := setup(t)
+
+		grant, err := s.RequestGrant(ctx, newRequester(nil), CreateGrantCommand{
+			Permissions: []ac.Permission{{Action: "dashboards:write", Scope: "dashboards:*"}},
+			Expires:     expires,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, GrantStatePending, grant.State)
+
+		_, err = s.ApproveGrant(ctx, approver, grant.UID)
+		assert.ErrorIs(t, err, ErrPermissionEscalation)
+
+		grant, err = s.RejectGrant(ctx, approver, grant.UID)
+		require.NoError(t, err)
+		assert.Equal(t, GrantStateRejected, grant.State)
+	})
+
+	t.Run("should require users.roles:add to request a grant for another user", func(t *testing.T) {
+		s, _ := setup(t)
+
+		_, err := s.RequestGrant(ctx, newRequester(nil), CreateGrantCommand{UserID: 2, RoleUID: dashboardsReader.UID, Expires: expires})
+		assert.ErrorIs(t, err, ErrPermissionEscalation)
+	})
+
+	t.Run("should validate the grant", func(t *testing.T) {
+		s, _ := setup(t)
+		requester := newRequester(nil)
+
+		_, err := s.RequestGrant(ctx, requester, CreateGrantCommand{RoleUID: dashboardsReader.UID, Expires: time.Now().Add(-time.Minute)})
+		assert.ErrorIs(t, err, ErrInvalidGrant)
+
+		_, err = s.RequestGrant(ctx, requester, CreateGrantCommand{RoleUID: dashboardsReader.UID, Expires: time.Now().Add(2 * time.Hour)})
+		assert.ErrorIs(t, err, ErrInvalidGrant)
+
+		_, err = s.RequestGrant(ctx, requester, CreateGrantCommand{
+			RoleUID:     dashboardsReader.UID,
+			Permissions: []ac.Permission{{Action: "dashboards:read", Scope: "dashboards:*"}},
+			Expires:     expires,
+		})
+		assert.ErrorIs(t, err, ErrInvalidGrant)
+	})
+
+	t.Run("should only revoke active grants", func(t *testing.T) {
+		s, _ := setup(t)
+
+		grant, err := s.RequestGrant(ctx, approver, CreateGrantCommand{RoleUID: dashboardsReader.UID, Expires: expires})
+		require.NoError(t, err)
+
+		grant, err = s.RevokeGrant(ctx, approver, grant.UID)
+		require.NoError(t, err)
+		assert.Equal(t, GrantStateRevoked, grant.State)
+
+		_, err = s.RevokeGrant(ctx, approver, grant.UID)
+		assert.ErrorIs(t, err, ErrGrantState)
+	})
+}
@@ -0,0 +1,184 @@
+package customroles
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+var (
+	ErrRoleNotFound      = errutil.NotFound("accesscontrol.role-not-found", errutil.WithPublicMessage("Role not found"))
+	ErrInvalidRoleName   = errutil.BadRequest("accesscontrol.invalid-role-name", errutil.WithPublicMessage("Custom role names must start with '"+ac.CustomRolePrefix+"'"))
+	ErrInvalidRole       = errutil.BadRequest("accesscontrol.invalid-role")
+	ErrRoleAlreadyExists = errutil.Conflict("accesscontrol.role-already-exists", errutil.WithPublicMessage("A role with the same name or uid already exists"))
+	ErrRoleVersion       = errutil.Conflict("accesscontrol.role-version-mismatch", errutil.WithPublicMessage("The version of the role must be incremented on update"))
+	ErrRoleAssigned      = errutil.BadRequest("accesscontrol.role-assigned", errutil.WithPublicMessage("The role is assigned, delete it with force=true to remove its assignments"))
+	ErrFixedRoleNotFound = errutil.BadRequest("accesscontrol.fixed-role-not-found")
+	// ErrPermissionEscalation is returned when a user tries to create, update or assign a role with permissions they don't have
+	ErrPermissionEscalation = errutil.Forbidden("accesscontrol.permission-escalation")
+	ErrInvalidCheck         = errutil.BadRequest("accesscontrol.invalid-check")
+	ErrCheckForbidden       = errutil.Forbidden("accesscontrol.check-forbidden")
+	ErrUserNotFound         = errutil.NotFound("accesscontrol.user-not-found", errutil.WithPublicMessage("User not found in the organization"))
+	ErrGrantNotFound        = errutil.NotFound("accesscontrol.grant-not-found", errutil.WithPublicMessage("Temporary grant not found"))
+	ErrInvalidGrant         = errutil.BadRequest("accesscontrol.invalid-grant")
+	// ErrGrantState is returned when a grant is approved, rejected or revoked from the wrong state
+	ErrGrantState = errutil.Conflict("accesscontrol.grant-state")
+	// ErrSelfApproval is returned when approvals are required and a user approves a grant they requested or receive
+	ErrSelfApproval = errutil.Forbidden("accesscontrol.grant-self-approval", errutil.WithPublicMessage("Temporary grants must be approved by another user"))
+)
+
+// CreateRoleCommand creates a custom role in the organization of the signed in user.
+type CreateRoleCommand struct {
+	// UID is generated when empty
+	UID         string `json:"uid"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	Description string `json:"description"`
+	Group       string `json:"group"`
+	Hidden      bool   `json:"hidden"`
+	Global      bool   `json:"global"`
+	// FixedRoles are the names or UIDs of the fixed roles whose permissions are copied to the role
+	FixedRoles  []string        `json:"fixedRoles"`
+	Permissions []ac.Permission `json:"permissions"`
+}
+
+// UpdateRoleCommand replaces the attributes and the permissions of a custom role.
+type UpdateRoleCommand struct {
+	// Version must be greater than the current version of the role
+	Version     int64           `json:"version"`
+	Name        string          `json:"name"`
+	DisplayName string          `json:"displayName"`
+	Description string          `json:"description"`
+	Group       string          `json:"group"`
+	Hidden      bool            `json:"hidden"`
+	Global      bool            `json:"global"`
+	FixedRoles  []string        `json:"fixedRoles"`
+	Permissions []ac.Permission `json:"permissions"`
+}
+
+type AddRoleAssignmentCommand struct {
+	RoleUID string `json:"roleUid"`
+}
+
+// SetRoleAssignmentsCommand replaces the custom roles assigned to a user or a team.
+type SetRoleAssignmentsCommand struct {
+	RoleUIDs []string `json:"roleUids"`
+}
+
+// CheckCommand evaluates whether a user has a permission.
+type CheckCommand struct {
+	// UserID defaults to the signed in user
+	UserID int64  `json:"userId"`
+	Action string `json:"action"`
+	Scope  string `json:"scope"`
+}
+
+type CheckResult struct {
+	Allowed bool `json:"allowed"`
+	// Permissions are the scopes of the action granted to the user, from all of their roles
+	Permissions []string `json:"permissions"`
+}
+
+// ExplainCommand requests the evaluation trace of a permission for a user.
+type ExplainCommand struct {
+	// UserID defaults to the signed in user
+	UserID int64  `json:"userId"`
+	Action string `json:"action"`
+	Scope  string `json:"scope"`
+}
+
+const (
+	GrantSourceBasicRole = "basic_role"
+	GrantSourceUser      = "user"
+	GrantSourceTeam      = "team"
+)
+
+// ExplainGrant is a permission of one of the roles bound to the user, directly, through a team or through a basic role.
+type ExplainGrant struct {
+	// Source is how the role is bound to the user: basic_role, user or team
+	Source    string `json:"source"`
+	BasicRole string `json:"basicRole,omitempty"`
+	TeamID    int64  `json:"teamId,omitempty"`
+	TeamName  string `json:"teamName,omitempty"`
+	RoleUID   string `json:"roleUid"`
+	RoleName  string `json:"roleName"`
+	// Action is the granted action, it is an action set such as folders:edit when the requested action belongs to it
+	Action string `json:"action"`
+	Scope  string `json:"scope"`
+	// Matched is set when the grant covers the requested scope
+	Matched bool `json:"matched"`
+	// MatchedScope is the requested scope, or the scope of the parent folder, the grant matched
+	MatchedScope string `json:"matchedScope,omitempty"`
+	// Inherited is set when the grant matched through a parent of the requested scope
+	Inherited bool `json:"inherited"`
+}
+
+type ExplainTeam struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type ExplainResult struct {
+	Allowed    bool          `json:"allowed"`
+	UserID     int64         `json:"userId"`
+	Login      string        `json:"login"`
+	BasicRoles []string      `json:"basicRoles"`
+	Teams      []ExplainTeam `json:"teams"`
+	Action     string        `json:"action"`
+	Scope      string        `json:"scope"`
+	// ResolvedScopes are the scopes the requested scope resolves to, in resolution order
+	ResolvedScopes []string `json:"resolvedScopes"`
+	// FolderChain are the folder scopes the requested scope inherits permissions from, closest first
+	FolderChain []string `json:"folderChain"`
+	// Grants are the permissions of the user's roles for the action, whether they match the scope or not
+	Grants []ExplainGrant `json:"grants"`
+	// Decisive is the matching grant that allows the action, direct grants win over inherited ones
+	Decisive *ExplainGrant `json:"decisive,omitempty"`
+}
+
+// States of temporary grants
+const (
+	GrantStatePending  = "pending"
+	GrantStateActive   = "active"
+	GrantStateRejected = "rejected"
+	GrantStateExpired  = "expired"
+	GrantStateRevoked  = "revoked"
+)
+
+// TemporaryGrant gives a user a role, or a set of permissions, until it expires.
+// The permissions of the role are copied when the grant is requested so approvers review what is granted.
+type TemporaryGrant struct {
+	ID          int64           `json:"-"`
+	OrgID       int64           `json:"orgId"`
+	UID         string          `json:"uid"`
+	UserID      int64           `json:"userId"`
+	RoleUID     string          `json:"roleUid,omitempty"`
+	Permissions []ac.Permission `json:"permissions"`
+	Reason      string          `json:"reason,omitempty"`
+	State       string          `json:"state"`
+	RequestedBy int64           `json:"requestedBy"`
+	ReviewedBy  int64           `json:"reviewedBy,omitempty"`
+	// GrantedRoleID is the id of the temporary role assigned to the user while the grant is active
+	GrantedRoleID int64     `json:"-"`
+	Expires       time.Time `json:"expires"`
+	Created       time.Time `json:"created"`
+	Updated       time.Time `json:"updated"`
+}
+
+// CreateGrantCommand requests a temporary grant of a role, or of permissions, for a user of the organization.
+type CreateGrantCommand struct {
+	// UserID defaults to the signed in user
+	UserID int64 `json:"userId"`
+	// RoleUID is the uid of a custom or a fixed role, it can't be combined with permissions
+	RoleUID     string          `json:"roleUid"`
+	Permissions []ac.Permission `json:"permissions"`
+	Reason      string          `json:"reason"`
+	Expires     time.Time       `json:"expires"`
+}
+
+type ListGrantsQuery struct {
+	OrgID  int64
+	UserID int64
+	State  string
+}
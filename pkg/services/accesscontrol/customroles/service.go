@@ -0,0 +1,477 @@
+package customroles
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/permreg"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// fixedRoleSource lists the fixed roles custom roles can be composed from
+type fixedRoleSource interface {
+	GetFixedRoles() []ac.RoleDTO
+	GetBasicRoleGrants(basicRole string) []ac.RoleDTO
+}
+
+// scopeResolver resolves a scope to the scopes it inherits permissions from
+type scopeResolver interface {
+	ResolveScope(ctx context.Context, orgID int64, scope string) ([]string, error)
+}
+
+func ProvideService(
+	cfg *setting.Cfg, sqlStore db.DB, router routing.RouteRegister, accessControl *acimpl.AccessControl,
+	acService *acimpl.Service, actionResolver ac.ActionResolver, permRegistry permreg.PermissionRegistry, cache *localcache.CacheService, tracer trace.Tracer,
+) *Service {
+	s := &Service{
+		cfg:            cfg,
+		db:             sqlStore,
+		store:          &xormStore{db: sqlStore},
+		accessControl:  accessControl,
+		scopeResolver:  accessControl,
+		acService:      acService,
+		actionResolver: actionResolver,
+		fixedRoles:     acService,
+		permRegistry:   permRegistry,
+		cache:          cache,
+		log:            log.New("accesscontrol.customroles"),
+		tracer:         tracer,
+	}
+
+	s.registerRoutes(router, accessControl)
+
+	return s
+}
+
+// Service manages the custom roles of organizations, the roles are stored next to
+// the managed and basic roles so the access control service resolves them for their assignees.
+type Service struct {
+	cfg            *setting.Cfg
+	db             db.DB
+	store          store
+	accessControl  ac.AccessControl
+	scopeResolver  scopeResolver
+	acService      ac.Service
+	actionResolver ac.ActionResolver
+	fixedRoles     fixedRoleSource
+	permRegistry   permreg.PermissionRegistry
+	cache          *localcache.CacheService
+	log            log.Logger
+	tracer         trace.Tracer
+}
+
+// ListRoles returns the custom roles of the org followed by the fixed roles
+func (s *Service) ListRoles(ctx context.Context, orgID int64, includeHidden bool) ([]ac.RoleDTO, error) {
+	roles, err := s.store.ListRoles(ctx, orgID, includeHidden)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, role := range s.fixedRoles.GetFixedRoles() {
+		if role.Hidden && !includeHidden {
+			continue
+		}
+		role.Permissions = nil
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+// GetRole returns a custom or a fixed role with its permissions
+func (s *Service) GetRole(ctx context.Context, orgID int64, uid string) (*ac.RoleDTO, error) {
+	if role := s.getFixedRole(uid); role != nil {
+		return role, nil
+	}
+	return s.store.GetRole(ctx, orgID, uid)
+}
+
+func (s *Service) CreateRole(ctx context.Context, requester identity.Requester, cmd CreateRoleCommand) (*ac.RoleDTO, error) {
+	ctx, span := s.tracer.Start(ctx, "customroles.CreateRole")
+	defer span.End()
+
+	if err := validateRole(cmd.Name, cmd.Global); err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.resolvePermissions(cmd.FixedRoles, cmd.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDelegation(ctx, requester, permissions); err != nil {
+		return nil, err
+	}
+
+	role := &ac.RoleDTO{
+		OrgID:       requester.GetOrgID(),
+		Version:     1,
+		UID:         cmd.UID,
+		Name:        cmd.Name,
+		DisplayName: cmd.DisplayName,
+		Description: cmd.Description,
+		Group:       cmd.Group,
+		Hidden:      cmd.Hidden,
+		Permissions: permissions,
+	}
+	if role.UID == "" {
+		if role.UID, err = generateRoleUID(ctx, s.db, role.OrgID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.store.CreateRole(ctx, role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (s *Service) UpdateRole(ctx context.Context, requester identity.Requester, uid string, cmd UpdateRoleCommand) (*ac.RoleDTO, error) {
+	ctx, span := s.tracer.Start(ctx, "customroles.UpdateRole")
+	defer span.End()
+
+	if err := validateRole(cmd.Name, cmd.Global); err != nil {
+		return nil, err
+	}
+
+	role, err := s.store.GetRole(ctx, requester.GetOrgID(), uid)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Version <= role.Version {
+		return nil, ErrRoleVersion.Errorf("version %d of role %s is not greater than %d", cmd.Version, uid, role.Version)
+	}
+
+	permissions, err := s.resolvePermissions(cmd.FixedRoles, cmd.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	// The requester must hold the permissions they remove as well as the ones they add
+	if err := s.checkDelegation(ctx, requester, append(permissions, role.Permissions...)); err != nil {
+		return nil, err
+	}
+
+	role.Version = cmd.Version
+	role.Name = cmd.Name
+	role.DisplayName = cmd.DisplayName
+	role.Description = cmd.Description
+	role.Group = cmd.Group
+	role.Hidden = cmd.Hidden
+	role.Permissions = permissions
+
+	if err := s.store.UpdateRole(ctx, role); err != nil {
+		return nil, err
+	}
+
+	s.clearRoleAssigneesCache(ctx, role)
+	return role, nil
+}
+
+func (s *Service) DeleteRole(ctx context.Context, requester identity.Requester, uid string, force bool) error {
+	ctx, span := s.tracer.Start(ctx, "customroles.DeleteRole")
+	defer span.End()
+
+	role, err := s.store.GetRole(ctx, requester.GetOrgID(), uid)
+	if err != nil {
+		return err
+	}
+	if err := s.checkDelegation(ctx, requester, role.Permissions); err != nil {
+		return err
+	}
+
+	// Clear the cache of the assignees before the assignments are removed
+	s.clearRoleAssigneesCache(ctx, role)
+	return s.store.DeleteRole(ctx, role.OrgID, uid, force)
+}
+
+func (s *Service) GetUserRoles(ctx context.Context, orgID, userID int64) ([]ac.RoleDTO, error) {
+	return s.store.GetUserRoles(ctx, orgID, userID)
+}
+
+func (s *Service) AddUserRole(ctx context.Context, requester identity.Requester, userID int64, roleUID string) error {
+	roles, err := s.getAssignableRoles(ctx, requester, []string{roleUID})
+	if err != nil {
+		return err
+	}
+	if err := s.store.AddUserRole(ctx, requester.GetOrgID(), userID, roles[0].ID); err != nil {
+		return err
+	}
+	s.clearUserCache(requester.GetOrgID(), userID)
+	return nil
+}
+
+func (s *Service) RemoveUserRole(ctx context.Context, requester identity.Requester, userID int64, roleUID string) error {
+	roles, err := s.getAssignableRoles(ctx, requester, []string{roleUID})
+	if err != nil {
+		return err
+	}
+	if err := s.store.RemoveUserRole(ctx, requester.GetOrgID(), userID, roles[0].ID); err != nil {
+		return err
+	}
+	s.clearUserCache(requester.GetOrgID(), userID)
+	return nil
+}
+
+func (s *Service) SetUserRoles(ctx context.Context, requester identity.Requester, userID int64, roleUIDs []string) error {
+	current, err := s.store.GetUserRoles(ctx, requester.GetOrgID(), userID)
+	if err != nil {
+		return err
+	}
+	roles, err := s.getAssignableRoles(ctx, requester, append(append([]string{}, roleUIDs...), uidsOf(current)...))
+	if err != nil {
+		return err
+	}
+	if err := s.store.SetUserRoles(ctx, requester.GetOrgID(), userID, roleIDs(roles[:len(roleUIDs)])); err != nil {
+		return err
+	}
+	s.clearUserCache(requester.GetOrgID(), userID)
+	return nil
+}
+
+func (s *Service) GetTeamRoles(ctx context.Context, orgID, teamID int64) ([]ac.RoleDTO, error) {
+	return s.store.GetTeamRoles(ctx, orgID, teamID)
+}
+
+func (s *Service) AddTeamRole(ctx context.Context, requester identity.Requester, teamID int64, roleUID string) error {
+	roles, err := s.getAssignableRoles(ctx, requester, []string{roleUID})
+	if err != nil {
+		return err
+	}
+	if err := s.store.AddTeamRole(ctx, requester.GetOrgID(), teamID, roles[0].ID); err != nil {
+		return err
+	}
+	s.cache.Delete(ac.GetTeamPermissionCacheKey(teamID, requester.GetOrgID()))
+	return nil
+}
+
+func (s *Service) RemoveTeamRole(ctx context.Context, requester identity.Requester, teamID int64, roleUID string) error {
+	roles, err := s.getAssignableRoles(ctx, requester, []string{roleUID})
+	if err != nil {
+		return err
+	}
+	if err := s.store.RemoveTeamRole(ctx, requester.GetOrgID(), teamID, roles[0].ID); err != nil {
+		return err
+	}
+	s.cache.Delete(ac.GetTeamPermissionCacheKey(teamID, requester.GetOrgID()))
+	return nil
+}
+
+func (s *Service) SetTeamRoles(ctx context.Context, requester identity.Requester, teamID int64, roleUIDs []string) error {
+	current, err := s.store.GetTeamRoles(ctx, requester.GetOrgID(), teamID)
+	if err != nil {
+		return err
+	}
+	roles, err := s.getAssignableRoles(ctx, requester, append(append([]string{}, roleUIDs...), uidsOf(current)...))
+	if err != nil {
+		return err
+	}
+	if err := s.store.SetTeamRoles(ctx, requester.GetOrgID(), teamID, roleIDs(roles[:len(roleUIDs)])); err != nil {
+		return err
+	}
+	s.cache.Delete(ac.GetTeamPermissionCacheKey(teamID, requester.GetOrgID()))
+	return nil
+}
+
+// Check evaluates the permission for the requester, or for another user of the org when cmd.UserID is set
+func (s *Service) Check(ctx context.Context, requester identity.Requester, cmd CheckCommand) (*CheckResult, error) {
+	ctx, span := s.tracer.Start(ctx, "customroles.Check")
+	defer span.End()
+
+	if cmd.Action == "" {
+		return nil, ErrInvalidCheck.Errorf("action is required")
+	}
+
+	permissions, err := s.getPermissions(ctx, requester, cmd.UserID, cmd.Action)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := ac.GroupScopesByAction(permissions)[cmd.Action]
+	allowed, err := s.evaluate(ctx, requester.GetOrgID(), cmd.Action, cmd.Scope, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CheckResult{
+		Allowed:     allowed,
+		Permissions: scopes,
+	}
+	if result.Permissions == nil {
+		result.Permissions = []string{}
+	}
+	return result, nil
+}
+
+// getPermissions returns the permissions of the requester, or the permissions for the action of another
+// user of the org when the requester is allowed to read them
+func (s *Service) getPermissions(ctx context.Context, requester identity.Requester, userID int64, action string) ([]ac.Permission, error) {
+	if self, _ := requester.GetInternalID(); userID == 0 || userID == self {
+		return s.acService.GetUserPermissions(ctx, requester, ac.Options{})
+	}
+
+	// Reading the permissions of another user requires the same permission as the user permissions API
+	evaluator := ac.EvalPermission(ac.ActionUsersPermissionsRead, ac.Scope("users", "id", strconv.FormatInt(userID, 10)))
+	allowed, err := s.accessControl.Evaluate(ctx, requester, evaluator)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrCheckForbidden.Errorf("not allowed to read the permissions of user %d", userID)
+	}
+	return s.acService.SearchUserPermissions(ctx, requester.GetOrgID(), ac.SearchOptions{UserID: userID, Action: action})
+}
+
+// evaluate checks the scopes granted for the action against the scope, and the scopes it resolves to
+func (s *Service) evaluate(ctx context.Context, orgID int64, action, scope string, granted []string) (bool, error) {
+	permissions := map[string][]string{action: granted}
+	if scope == "" {
+		return ac.EvalPermission(action).Evaluate(permissions), nil
+	}
+	if ac.EvalPermission(action, scope).Evaluate(permissions) {
+		return true, nil
+	}
+
+	resolved, err := s.scopeResolver.ResolveScope(ctx, orgID, scope)
+	if err != nil || len(resolved) == 0 {
+		return false, err
+	}
+	return ac.EvalPermission(action, resolved...).Evaluate(permissions), nil
+}
+
+// resolvePermissions merges the permissions of the fixed roles with the explicit ones and validates them
+func (s *Service) resolvePermissions(fixedRoles []string, explicit []ac.Permission) ([]ac.Permission, error) {
+	seen := map[string]bool{}
+	permissions := make([]ac.Permission, 0, len(explicit))
+	add := func(p ac.Permission) {
+		key := p.Action + "|" + p.Scope
+		if !seen[key] {
+			seen[key] = true
+			permissions = append(permissions, ac.Permission{Action: p.Action, Scope: p.Scope})
+		}
+	}
+
+	for _, name := range fixedRoles {
+		role := s.getFixedRole(name)
+		if role == nil {
+			return nil, ErrFixedRoleNotFound.Errorf("fixed role %s not found", name)
+		}
+		for _, p := range role.Permissions {
+			add(p)
+		}
+	}
+
+	for _, p := range explicit {
+		if p.Action == "" {
+			return nil, ErrInvalidRole.Errorf("permission action is required")
+		}
+		if s.cfg.RBAC.PermissionValidationEnabled {
+			if err := s.permRegistry.IsPermissionValid(p.Action, p.Scope); err != nil {
+				return nil, err
+			}
+		}
+		add(p)
+	}
+
+	if len(permissions) == 0 {
+		return nil, ErrInvalidRole.Errorf("role must have at least one permission")
+	}
+	return permissions, nil
+}
+
+// checkDelegation prevents privilege escalation, the requester must hold every permission of the role
+func (s *Service) checkDelegation(ctx context.Context, requester identity.Requester, permissions []ac.Permission) error {
+	for _, p := range permissions {
+		evaluator := ac.EvalPermission(p.Action)
+		if p.Scope != "" {
+			evaluator = ac.EvalPermission(p.Action, p.Scope)
+		}
+		allowed, err := s.accessControl.Evaluate(ctx, requester, evaluator)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return ErrPermissionEscalation.Errorf("missing permission %s on %s", p.Action, p.Scope)
+		}
+	}
+	return nil
+}
+
+// getAssignableRoles returns the custom roles in the order of the uids once the requester is confirmed to hold their permissions
+func (s *Service) getAssignableRoles(ctx context.Context, requester identity.Requester, uids []string) ([]*ac.RoleDTO, error) {
+	roles := make([]*ac.RoleDTO, 0, len(uids))
+	for _, uid := range uids {
+		role, err := s.store.GetRole(ctx, requester.GetOrgID(), uid)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkDelegation(ctx, requester, role.Permissions); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+func (s *Service) getFixedRole(nameOrUID string) *ac.RoleDTO {
+	for _, role := range s.fixedRoles.GetFixedRoles() {
+		if role.UID == nameOrUID || role.Name == nameOrUID {
+			return &role
+		}
+	}
+	return nil
+}
+
+func (s *Service) clearRoleAssigneesCache(ctx context.Context, role *ac.RoleDTO) {
+	userIDs, teamIDs, err := s.store.GetRoleAssignees(ctx, role.ID)
+	if err != nil {
+		s.log.Warn("Failed to clear the permission cache of the role assignees", "role", role.UID, "error", err)
+		return
+	}
+	for _, id := range userIDs {
+		s.clearUserCache(role.OrgID, id)
+	}
+	for _, id := range teamIDs {
+		s.cache.Delete(ac.GetTeamPermissionCacheKey(id, role.OrgID))
+	}
+}
+
+// clearUserCache clears the cached permissions of the user, the id can belong to a user or a service account
+func (s *Service) clearUserCache(orgID, userID int64) {
+	s.acService.ClearUserPermissionCache(&user.SignedInUser{OrgID: orgID, UserID: userID})
+	s.acService.ClearUserPermissionCache(&user.SignedInUser{OrgID: orgID, UserID: userID, IsServiceAccount: true})
+}
+
+func validateRole(name string, global bool) error {
+	if !strings.HasPrefix(name, ac.CustomRolePrefix) || len(name) == len(ac.CustomRolePrefix) {
+		return ErrInvalidRoleName.Errorf("invalid role name %q", name)
+	}
+	if global {
+		return ErrInvalidRole.Errorf("global custom roles are not supported")
+	}
+	return nil
+}
+
+func uidsOf(roles []ac.RoleDTO) []string {
+	uids := make([]string, 0, len(roles))
+	for _, role := range roles {
+		uids = append(uids, role.UID)
+	}
+	return uids
+}
+
+func roleIDs(roles []*ac.RoleDTO) []int64 {
+	ids := make([]int64, 0, len(roles))
+	for _, role := range roles {
+		ids = append(ids, role.ID)
+	}
+	return ids
+}
@@ -0,0 +1,293 @@
+package customroles
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/permreg"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var dashboardsReader = ac.RoleDTO{
+	UID:  "fixed_dashboards_reader",
+	Name: "fixed:dashboards:reader",
+	Permissions: []ac.Permission{
+		{Action: "dashboards:read", Scope: "dashboards:*"},
+		{Action: "folders:read", Scope: "folders:*"},
+	},
+}
+
+func TestService_CreateRole(t *testing.T) {
+	ctx := context.Background()
+	requester := newRequester(map[string][]string{
+		"dashboards:read": {"dashboards:*"},
+		"folders:read":    {"folders:*"},
+	})
+
+	t.Run("should copy the permissions of fixed roles", func(t *testing.T) {
+		s, store := setupTestService(t)
+
+		role, err := s.CreateRole(ctx, requester, CreateRoleCommand{
+			UID:         "viewer",
+			Name:        "custom:viewer",
+			FixedRoles:  []string{"fixed:dashboards:reader"},
+			Permissions: []ac.Permission{{Action: "dashboards:read", Scope: "dashboards:*"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), role.Version)
+		assert.Equal(t, int64(1), role.OrgID)
+		assert.ElementsMatch(t, dashboardsReader.Permissions, store.roles["viewer"].Permissions)
+	})
+
+	t.Run("should require the custom prefix", func(t *testing.T) {
+		s, store := setupTestService(t)
+
+		_, err := s.CreateRole(ctx, requester, CreateRoleCommand{UID: "viewer", Name: "viewer", FixedRoles: []string{"fixed:dashboards:reader"}})
+		assert.ErrorIs(t, err, ErrInvalidRoleName)
+		assert.Empty(t, store.roles)
+	})
+
+	t.Run("should reject global roles", func(t *testing.T) {
+		s, _ := setupTestService(t)
+
+		_, err := s.CreateRole(ctx, requester, CreateRoleCommand{UID: "viewer", Name: "custom:viewer", Global: true, FixedRoles: []string{"fixed:dashboards:reader"}})
+		assert.ErrorIs(t, err, ErrInvalidRole)
+	})
+
+	t.Run("should reject unknown fixed roles", func(t *testing.T) {
+		s, _ := setupTestService(t)
+
+		_, err := s.CreateRole(ctx, requester, CreateRoleCommand{UID: "viewer", Name: "custom:viewer", FixedRoles: []string{"fixed:unknown:reader"}})
+		assert.ErrorIs(t, err, ErrFixedRoleNotFound)
+	})
+
+	t.Run("should not grant permissions the requester doesn't have", func(t *testing.T) {
+		s, store := setupTestService(t)
+
+		_, err := s.CreateRole(ctx, requester, CreateRoleCommand{
+			UID:         "writer",
+			Name:        "custom:writer",
+			Permissions: []ac.Permission{{Action: "dashboards:write", Scope: "dashboards:*"}},
+		})
+		assert.ErrorIs(t, err, ErrPermissionEscalation)
+		assert.Empty(t, store.roles)
+	})
+}
+
+func TestService_UpdateRole(t *testing.T) {
+	ctx := context.Background()
+	requester := newRequester(map[string][]string{
+		"dashboards:read": {"dashboards:*"},
+		"folders:read":    {"folders:*"},
+	})
+
+	t.Run("should require a greater version", func(t *testing.T) {
+		s, _ := setupTestService(t)
+		_, err := s.CreateRole(ctx, requester, CreateRoleCommand{UID: "viewer", Name: "custom:viewer", FixedRoles: []string{"fixed:dashboards:reader"}})
+		require.NoError(t, err)
+
+		_, err = s.UpdateRole(ctx, requester, "viewer", UpdateRoleCommand{Version: 1, Name: "custom:viewer", FixedRoles: []string{"fixed:dashboards:reader"}})
+		assert.ErrorIs(t, err, ErrRoleVersion)
+
+		role, err := s.UpdateRole(ctx, requester, "viewer", UpdateRoleCommand{
+			Version:     2,
+			Name:        "custom:viewer",
+			Permissions: []ac.Permission{{Action: "folders:read", Scope: "folders:*"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []ac.Permission{{Action: "folders:read", Scope: "folders:*"}}, role.Permissions)
+	})
+
+	t.Run("should not remove permissions the requester doesn't have", func(t *testing.T) {
+		s, store := setupTestService(t)
+		store.roles["admin"] = &ac.RoleDTO{
+			ID: 1, OrgID: 1, Version: 1, UID: "admin", Name: "custom:admin",
+			Permissions: []ac.Permission{{Action: "users:write", Scope: "global.users:*"}},
+		}
+
+		_, err := s.UpdateRole(ctx, requester, "admin", UpdateRoleCommand{Version: 2, Name: "custom:admin", FixedRoles: []string{"fixed:dashboards:reader"}})
+		assert.ErrorIs(t, err, ErrPermissionEscalation)
+	})
+}
+
+func TestService_Check(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should evaluate the permissions of the requester", func(t *testing.T) {
+		s, _ := setupTestService(t)
+		s.acService = actest.FakeService{ExpectedPermissions: []ac.Permission{
+			{Action: "dashboards:read", Scope: "dashboards:uid:1"},
+			{Action: "dashboards:read", Scope: "folders:uid:2"},
+		}}
+		requester := newRequester(nil)
+
+		result, err := s.Check(ctx, requester, CheckCommand{Action: "dashboards:read", Scope: "dashboards:uid:1"})
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, []string{"dashboards:uid:1", "folders:uid:2"}, result.Permissions)
+
+		result, err = s.Check(ctx, requester, CheckCommand{Action: "dashboards:write"})
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Empty(t, result.Permissions)
+	})
+
+	t.Run("should require users.permissions:read to check another user", func(t *testing.T) {
+		s, _ := setupTestService(t)
+		s.acService = actest.FakeService{ExpectedFilteredUserPermissions: []ac.Permission{{Action: "dashboards:read", Scope: "dashboards:*"}}}
+
+		_, err := s.Check(ctx, newRequester(nil), CheckCommand{UserID: 2, Action: "dashboards:read"})
+		assert.ErrorIs(t, err, ErrCheckForbidden)
+
+		requester := newRequester(map[string][]string{ac.ActionUsersPermissionsRead: {"users:id:2"}})
+		result, err := s.Check(ctx, requester, CheckCommand{UserID: 2, Action: "dashboards:read", Scope: "dashboards:uid:1"})
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+}
+
+func newRequester(permissions map[string][]string) *user.SignedInUser {
+	return &user.SignedInUser{UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{1: permissions}}
+}
+
+func setupTestService(t *testing.T) (*Service, *fakeStore) {
+	t.Helper()
+
+	store := &fakeStore{roles: map[string]*ac.RoleDTO{}}
+	accessControl := acimpl.ProvideAccessControlTest()
+	return &Service{
+		cfg:            setting.NewCfg(),
+		store:          store,
+		accessControl:  accessControl,
+		scopeResolver:  accessControl,
+		acService:      actest.FakeService{},
+		actionResolver: &resourcepermissions.FakeActionSetSvc{},
+		fixedRoles:     fakeFixedRoles{dashboardsReader},
+		permRegistry:   permreg.ProvidePermissionRegistry(),
+		cache:          localcache.New(0, 0),
+		log:            log.NewNopLogger(),
+		tracer:         tracing.InitializeTracerForTest(),
+	}, store
+}
+
+// fakeFixedRoles grants all of its roles to the Viewer basic role
+type fakeFixedRoles []ac.RoleDTO
+
+func (f fakeFixedRoles) GetFixedRoles() []ac.RoleDTO {
+	return f
+}
+
+func (f fakeFixedRoles) GetBasicRoleGrants(basicRole string) []ac.RoleDTO {
+	if basicRole != "Viewer" {
+		return nil
+	}
+	return f
+}
+
+type fakeScopeResolver map[string][]string
+
+func (f fakeScopeResolver) ResolveScope(_ context.Context, _ int64, scope string) ([]string, error) {
+	return f[scope], nil
+}
+
+// fakeStore keeps the roles of org 1 by uid, assignments aren't tracked
+type fakeStore struct {
+	store
+	roles   map[string]*ac.RoleDTO
+	subject *explainSubject
+	explain []ExplainGrant
+	grants  map[string]*TemporaryGrant
+}
+
+func (f *fakeStore) GetRole(_ context.Context, _ int64, uid string) (*ac.RoleDTO, error) {
+	role, ok := f.roles[uid]
+	if !ok {
+		return nil, ErrRoleNotFound.Errorf("role %s not found", uid)
+	}
+	copied := *role
+	return &copied, nil
+}
+
+func (f *fakeStore) CreateRole(_ context.Context, role *ac.RoleDTO) error {
+	if _, ok := f.roles[role.UID]; ok {
+		return ErrRoleAlreadyExists.Errorf("role %s already exists", role.UID)
+	}
+	role.ID = int64(len(f.roles) + 1)
+	copied := *role
+	f.roles[role.UID] = &copied
+	return nil
+}
+
+func (f *fakeStore) UpdateRole(_ context.Context, role *ac.RoleDTO) error {
+	copied := *role
+	f.roles[role.UID] = &copied
+	return nil
+}
+
+func (f *fakeStore) GetRoleAssignees(_ context.Context, _ int64) ([]int64, []int64, error) {
+	return nil, nil, nil
+}
+
+func (f *fakeStore) GetExplainSubject(_ context.Context, _, userID int64) (*explainSubject, error) {
+	if f.subject == nil {
+		return nil, ErrUserNotFound.Errorf("user %d not found", userID)
+	}
+	return f.subject, nil
+}
+
+func (f *fakeStore) GetPermissionGrants(_ context.Context, query grantsQuery) ([]ExplainGrant, error) {
+	grants := make([]ExplainGrant, 0)
+	for _, grant := range f.explain {
+		for _, action := range query.Actions {
+			if grant.Action == action {
+				grants = append(grants, grant)
+			}
+		}
+	}
+	return grants, nil
+}
+
+func (f *fakeStore) CreateGrant(_ context.Context, grant *TemporaryGrant) error {
+	if f.grants == nil {
+		f.grants = map[string]*TemporaryGrant{}
+	}
+	grant.ID = int64(len(f.grants) + 1)
+	f.grants[grant.UID] = grant
+	return nil
+}
+
+func (f *fakeStore) GetGrant(_ context.Context, _ int64, uid string) (*TemporaryGrant, error) {
+	grant, ok := f.grants[uid]
+	if !ok {
+		return nil, ErrGrantNotFound.Errorf("temporary grant %s not found", uid)
+	}
+	return grant, nil
+}
+
+func (f *fakeStore) ActivateGrant(ctx context.Context, grant *TemporaryGrant, reviewedBy int64) error {
+	if err := f.UpdateGrantState(ctx, grant, GrantStatePending, GrantStateActive, reviewedBy); err != nil {
+		return err
+	}
+	grant.GrantedRoleID = grant.ID
+	return nil
+}
+
+func (f *fakeStore) UpdateGrantState(_ context.Context, grant *TemporaryGrant, from, to string, reviewedBy int64) error {
+	if grant.State != from {
+		return ErrGrantState.Errorf("temporary grant %s is not %s", grant.UID, from)
+	}
+	grant.State = to
+	grant.ReviewedBy = reviewedBy
+	return nil
+}
@@ -0,0 +1,490 @@
+package customroles
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const customRoleFilter = "role.name LIKE '" + ac.CustomRolePrefix + "%'"
+
+type store interface {
+	ListRoles(ctx context.Context, orgID int64, includeHidden bool) ([]ac.RoleDTO, error)
+	GetRole(ctx context.Context, orgID int64, uid string) (*ac.RoleDTO, error)
+	CreateRole(ctx context.Context, role *ac.RoleDTO) error
+	UpdateRole(ctx context.Context, role *ac.RoleDTO) error
+	// DeleteRole deletes the role, its permissions and, with force, its assignments
+	DeleteRole(ctx context.Context, orgID int64, uid string, force bool) error
+	// GetRoleAssignees returns the ids of the users and the teams the role is assigned to
+	GetRoleAssignees(ctx context.Context, roleID int64) ([]int64, []int64, error)
+
+	GetUserRoles(ctx context.Context, orgID, userID int64) ([]ac.RoleDTO, error)
+	AddUserRole(ctx context.Context, orgID, userID, roleID int64) error
+	RemoveUserRole(ctx context.Context, orgID, userID, roleID int64) error
+	// SetUserRoles replaces the custom roles assigned to the user
+	SetUserRoles(ctx context.Context, orgID, userID int64, roleIDs []int64) error
+
+	GetTeamRoles(ctx context.Context, orgID, teamID int64) ([]ac.RoleDTO, error)
+	AddTeamRole(ctx context.Context, orgID, teamID, roleID int64) error
+	RemoveTeamRole(ctx context.Context, orgID, teamID, roleID int64) error
+	// SetTeamRoles replaces the custom roles assigned to the team
+	SetTeamRoles(ctx context.Context, orgID, teamID int64, roleIDs []int64) error
+
+	// GetExplainSubject returns the login, basic roles and teams of a user of the org
+	GetExplainSubject(ctx context.Context, orgID, userID int64) (*explainSubject, error)
+	// GetPermissionGrants returns the permissions for the actions of the roles bound to the user,
+	// directly, through their teams or through their basic roles
+	GetPermissionGrants(ctx context.Context, query grantsQuery) ([]ExplainGrant, error)
+
+	CreateGrant(ctx context.Context, grant *TemporaryGrant) error
+	GetGrant(ctx context.Context, orgID int64, uid string) (*TemporaryGrant, error)
+	ListGrants(ctx context.Context, query ListGrantsQuery) ([]*TemporaryGrant, error)
+	// ActivateGrant assigns the permissions of the pending grant to the user through a temporary role
+	ActivateGrant(ctx context.Context, grant *TemporaryGrant, reviewedBy int64) error
+	// UpdateGrantState moves the grant from one state to another, the temporary role is removed when it leaves the active state
+	UpdateGrantState(ctx context.Context, grant *TemporaryGrant, from, to string, reviewedBy int64) error
+	// ExpireGrants expires the grants past their expiry and returns the ones that were active
+	ExpireGrants(ctx context.Context, now time.Time) ([]*TemporaryGrant, error)
+}
+
+type explainSubject struct {
+	Login      string
+	BasicRoles []string
+	Teams      []ExplainTeam
+}
+
+type grantsQuery struct {
+	OrgID      int64
+	UserID     int64
+	TeamIDs    []int64
+	BasicRoles []string
+	Actions    []string
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (s *xormStore) ListRoles(ctx context.Context, orgID int64, includeHidden bool) ([]ac.RoleDTO, error) {
+	roles := make([]ac.RoleDTO, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table("role").Where("org_id = ?", orgID).And(customRoleFilter)
+		if !includeHidden {
+			q = q.And("role.hidden = " + s.db.GetDialect().BooleanStr(false))
+		}
+		return q.Asc("name").Find(&roles)
+	})
+	return roles, err
+}
+
+func (s *xormStore) GetRole(ctx context.Context, orgID int64, uid string) (*ac.RoleDTO, error) {
+	var role *ac.RoleDTO
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		role, err = getRole(sess, orgID, uid)
+		if err != nil {
+			return err
+		}
+		return sess.Table("permission").Where("role_id = ?", role.ID).Asc("action", "scope").Find(&role.Permissions)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (s *xormStore) CreateRole(ctx context.Context, role *ac.RoleDTO) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Table("role").Where("org_id = ? AND (name = ? OR uid = ?)", role.OrgID, role.Name, role.UID).Exist()
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrRoleAlreadyExists.Errorf("role %s already exists", role.Name)
+		}
+
+		now := time.Now()
+		role.Created = now
+		role.Updated = now
+		stored := role.Role()
+		if _, err := sess.Table("role").Insert(&stored); err != nil {
+			return err
+		}
+		role.ID = stored.ID
+
+		return insertPermissions(sess, role.ID, role.Permissions, now)
+	})
+}
+
+func (s *xormStore) UpdateRole(ctx context.Context, role *ac.RoleDTO) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Table("role").Where("org_id = ? AND name = ? AND id <> ?", role.OrgID, role.Name, role.ID).Exist()
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrRoleAlreadyExists.Errorf("role %s already exists", role.Name)
+		}
+
+		now := time.Now()
+		role.Updated = now
+		stored := role.Role()
+		if _, err := sess.Table("role").ID(role.ID).Cols("version", "name", "display_name", "description", "group_name", "hidden", "updated").Update(&stored); err != nil {
+			return err
+		}
+
+		if _, err := sess.Exec("DELETE FROM permission WHERE role_id = ?", role.ID); err != nil {
+			return err
+		}
+		return insertPermissions(sess, role.ID, role.Permissions, now)
+	})
+}
+
+func (s *xormStore) DeleteRole(ctx context.Context, orgID int64, uid string, force bool) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		role, err := getRole(sess, orgID, uid)
+		if err != nil {
+			return err
+		}
+
+		if !force {
+			users, err := sess.Table("user_role").Where("role_id = ?", role.ID).Count()
+			if err != nil {
+				return err
+			}
+			teams, err := sess.Table("team_role").Where("role_id = ?", role.ID).Count()
+			if err != nil {
+				return err
+			}
+			if users+teams > 0 {
+				return ErrRoleAssigned.Errorf("role %s is assigned to %d users and %d teams", role.UID, users, teams)
+			}
+		}
+
+		deletes := []string{
+			"DELETE FROM user_role WHERE role_id = ?",
+			"DELETE FROM team_role WHERE role_id = ?",
+			"DELETE FROM permission WHERE role_id = ?",
+			"DELETE FROM role WHERE id = ?",
+		}
+		for _, q := range deletes {
+			if _, err := sess.Exec(q, role.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *xormStore) GetRoleAssignees(ctx context.Context, roleID int64) ([]int64, []int64, error) {
+	var userIDs, teamIDs []int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		if err := sess.Table("user_role").Where("role_id = ?", roleID).Cols("user_id").Find(&userIDs); err != nil {
+			return err
+		}
+		return sess.Table("team_role").Where("role_id = ?", roleID).Cols("team_id").Find(&teamIDs)
+	})
+	return userIDs, teamIDs, err
+}
+
+func (s *xormStore) GetUserRoles(ctx context.Context, orgID, userID int64) ([]ac.RoleDTO, error) {
+	roles := make([]ac.RoleDTO, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("role").
+			Join("INNER", "user_role", "user_role.role_id = role.id").
+			Where("user_role.org_id = ? AND user_role.user_id = ?", orgID, userID).
+			And(customRoleFilter).
+			Asc("role.name").
+			Cols("role.*").
+			Find(&roles)
+	})
+	return roles, err
+}
+
+func (s *xormStore) AddUserRole(ctx context.Context, orgID, userID, roleID int64) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if err := checkUserInOrg(sess, orgID, userID); err != nil {
+			return err
+		}
+		return addUserRole(sess, orgID, userID, roleID)
+	})
+}
+
+func (s *xormStore) RemoveUserRole(ctx context.Context, orgID, userID, roleID int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM user_role WHERE org_id = ? AND user_id = ? AND role_id = ?", orgID, userID, roleID)
+		return err
+	})
+}
+
+func (s *xormStore) SetUserRoles(ctx context.Context, orgID, userID int64, roleIDs []int64) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if err := checkUserInOrg(sess, orgID, userID); err != nil {
+			return err
+		}
+
+		q := "DELETE FROM user_role WHERE org_id = ? AND user_id = ? AND role_id IN (SELECT id FROM role WHERE " + customRoleFilter + ")"
+		if _, err := sess.Exec(q, orgID, userID); err != nil {
+			return err
+		}
+		for _, roleID := range roleIDs {
+			if err := addUserRole(sess, orgID, userID, roleID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *xormStore) GetTeamRoles(ctx context.Context, orgID, teamID int64) ([]ac.RoleDTO, error) {
+	roles := make([]ac.RoleDTO, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("role").
+			Join("INNER", "team_role", "team_role.role_id = role.id").
+			Where("team_role.org_id = ? AND team_role.team_id = ?", orgID, teamID).
+			And(customRoleFilter).
+			Asc("role.name").
+			Cols("role.*").
+			Find(&roles)
+	})
+	return roles, err
+}
+
+func (s *xormStore) AddTeamRole(ctx context.Context, orgID, teamID, roleID int64) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if err := checkTeamInOrg(sess, orgID, teamID); err != nil {
+			return err
+		}
+		return addTeamRole(sess, orgID, teamID, roleID)
+	})
+}
+
+func (s *xormStore) RemoveTeamRole(ctx context.Context, orgID, teamID, roleID int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM team_role WHERE org_id = ? AND team_id = ? AND role_id = ?", orgID, teamID, roleID)
+		return err
+	})
+}
+
+func (s *xormStore) SetTeamRoles(ctx context.Context, orgID, teamID int64, roleIDs []int64) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if err := checkTeamInOrg(sess, orgID, teamID); err != nil {
+			return err
+		}
+
+		q := "DELETE FROM team_role WHERE org_id = ? AND team_id = ? AND role_id IN (SELECT id FROM role WHERE " + customRoleFilter + ")"
+		if _, err := sess.Exec(q, orgID, teamID); err != nil {
+			return err
+		}
+		for _, roleID := range roleIDs {
+			if err := addTeamRole(sess, orgID, teamID, roleID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func getRole(sess *db.Session, orgID int64, uid string) (*ac.RoleDTO, error) {
+	role := &ac.RoleDTO{}
+	has, err := sess.Table("role").Where("org_id = ? AND uid = ?", orgID, uid).And(customRoleFilter).Get(role)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrRoleNotFound.Errorf("custom role %s not found in org %d", uid, orgID)
+	}
+	return role, nil
+}
+
+func insertPermissions(sess *db.Session, roleID int64, permissions []ac.Permission, now time.Time) error {
+	for _, p := range permissions {
+		permission := ac.Permission{RoleID: roleID, Action: p.Action, Scope: p.Scope, Created: now, Updated: now}
+		permission.Kind, permission.Attribute, permission.Identifier = permission.SplitScope()
+		if _, err := sess.Table("permission").Insert(&permission); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *xormStore) GetExplainSubject(ctx context.Context, orgID, userID int64) (*explainSubject, error) {
+	subject := &explainSubject{Teams: make([]ExplainTeam, 0)}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var member struct {
+			Login   string
+			IsAdmin bool
+			Role    string
+		}
+		has, err := sess.SQL(
+			"SELECT u.login, u.is_admin, org_user.role FROM org_user INNER JOIN "+s.db.GetDialect().Quote("user")+" AS u ON u.id = org_user.user_id WHERE org_user.org_id = ? AND org_user.user_id = ?",
+			orgID, userID,
+		).Get(&member)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrUserNotFound.Errorf("user %d not found in org %d", userID, orgID)
+		}
+
+		subject.Login = member.Login
+		subject.BasicRoles = []string{member.Role}
+		if member.IsAdmin {
+			subject.BasicRoles = append(subject.BasicRoles, ac.RoleGrafanaAdmin)
+		}
+
+		return sess.SQL(
+			"SELECT team.id, team.name FROM team INNER JOIN team_member ON team_member.team_id = team.id WHERE team.org_id = ? AND team_member.user_id = ? ORDER BY team.name",
+			orgID, userID,
+		).Find(&subject.Teams)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subject, nil
+}
+
+type grantRow struct {
+	RoleUID   string `xorm:"role_uid"`
+	RoleName  string `xorm:"role_name"`
+	Action    string `xorm:"action"`
+	Scope     string `xorm:"scope"`
+	TeamID    int64  `xorm:"team_id"`
+	BasicRole string `xorm:"basic_role"`
+}
+
+func (s *xormStore) GetPermissionGrants(ctx context.Context, query grantsQuery) ([]ExplainGrant, error) {
+	if len(query.Actions) == 0 {
+		return []ExplainGrant{}, nil
+	}
+
+	const selectGrants = "SELECT role.uid AS role_uid, role.name AS role_name, permission.action, permission.scope"
+	prefixFilter, prefixParams := ac.RolePrefixesFilter(acimpl.OSSRolesPrefixes)
+	actionFilter := " AND permission.action IN (?" + strings.Repeat(",?", len(query.Actions)-1) + ")"
+	where := func(params ...any) (string, []any) {
+		args := append([]any{}, prefixParams...)
+		for _, action := range query.Actions {
+			args = append(args, action)
+		}
+		return prefixFilter + actionFilter, append(args, params...)
+	}
+
+	grants := make([]ExplainGrant, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		rows := make([]grantRow, 0)
+		filter, args := where(query.UserID, query.OrgID, ac.GlobalOrgID)
+		if err := sess.SQL(selectGrants+
+			" FROM permission INNER JOIN role ON role.id = permission.role_id INNER JOIN user_role ON user_role.role_id = role.id"+
+			filter+" AND user_role.user_id = ? AND user_role.org_id IN (?, ?) ORDER BY role.name, permission.scope", args...).Find(&rows); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			grants = append(grants, ExplainGrant{Source: GrantSourceUser, RoleUID: row.RoleUID, RoleName: row.RoleName, Action: row.Action, Scope: row.Scope})
+		}
+
+		if len(query.TeamIDs) > 0 {
+			rows = rows[:0]
+			teamIDs := make([]any, 0, len(query.TeamIDs))
+			for _, id := range query.TeamIDs {
+				teamIDs = append(teamIDs, id)
+			}
+			filter, args := where(append([]any{query.OrgID}, teamIDs...)...)
+			if err := sess.SQL(selectGrants+", team_role.team_id"+
+				" FROM permission INNER JOIN role ON role.id = permission.role_id INNER JOIN team_role ON team_role.role_id = role.id"+
+				filter+" AND team_role.org_id = ? AND team_role.team_id IN (?"+strings.Repeat(",?", len(teamIDs)-1)+") ORDER BY role.name, permission.scope", args...).Find(&rows); err != nil {
+				return err
+			}
+			for _, row := range rows {
+				grants = append(grants, ExplainGrant{Source: GrantSourceTeam, TeamID: row.TeamID, RoleUID: row.RoleUID, RoleName: row.RoleName, Action: row.Action, Scope: row.Scope})
+			}
+		}
+
+		if len(query.BasicRoles) > 0 {
+			rows = rows[:0]
+			roles := make([]any, 0, len(query.BasicRoles))
+			for _, role := range query.BasicRoles {
+				roles = append(roles, role)
+			}
+			filter, args := where(append(roles, query.OrgID, ac.GlobalOrgID)...)
+			if err := sess.SQL(selectGrants+", builtin_role.role AS basic_role"+
+				" FROM permission INNER JOIN role ON role.id = permission.role_id INNER JOIN builtin_role ON builtin_role.role_id = role.id"+
+				filter+" AND builtin_role.role IN (?"+strings.Repeat(",?", len(roles)-1)+") AND builtin_role.org_id IN (?, ?) ORDER BY role.name, permission.scope", args...).Find(&rows); err != nil {
+				return err
+			}
+			for _, row := range rows {
+				grants = append(grants, ExplainGrant{Source: GrantSourceBasicRole, BasicRole: row.BasicRole, RoleUID: row.RoleUID, RoleName: row.RoleName, Action: row.Action, Scope: row.Scope})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+func addUserRole(sess *db.Session, orgID, userID, roleID int64) error {
+	exists, err := sess.Table("user_role").Where("org_id = ? AND user_id = ? AND role_id = ?", orgID, userID, roleID).Exist()
+	if err != nil || exists {
+		return err
+	}
+	_, err = sess.Table("user_role").Insert(&ac.UserRole{OrgID: orgID, UserID: userID, RoleID: roleID, Created: time.Now()})
+	return err
+}
+
+func addTeamRole(sess *db.Session, orgID, teamID, roleID int64) error {
+	exists, err := sess.Table("team_role").Where("org_id = ? AND team_id = ? AND role_id = ?", orgID, teamID, roleID).Exist()
+	if err != nil || exists {
+		return err
+	}
+	_, err = sess.Table("team_role").Insert(&ac.TeamRole{OrgID: orgID, TeamID: teamID, RoleID: roleID, Created: time.Now()})
+	return err
+}
+
+// checkUserInOrg confirms the user, or service account, is a member of the org
+func checkUserInOrg(sess *db.Session, orgID, userID int64) error {
+	exists, err := sess.Table("org_user").Where("org_id = ? AND user_id = ?", orgID, userID).Exist()
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ac.ErrAssignmentEntityNotFound.Build(ac.ErrAssignmentEntityNotFoundData(fmt.Sprintf("user %d", userID)))
+	}
+	return nil
+}
+
+func checkTeamInOrg(sess *db.Session, orgID, teamID int64) error {
+	exists, err := sess.Table("team").Where("org_id = ? AND id = ?", orgID, teamID).Exist()
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ac.ErrAssignmentEntityNotFound.Build(ac.ErrAssignmentEntityNotFoundData(fmt.Sprintf("team %d", teamID)))
+	}
+	return nil
+}
+
+func generateRoleUID(ctx context.Context, sqlStore db.DB, orgID int64) (string, error) {
+	for i := 0; i < 3; i++ {
+		uid := util.GenerateShortUID()
+		var exists bool
+		err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			var err error
+			exists, err = sess.Table("role").Where("org_id = ? AND uid = ?", orgID, uid).Exist()
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return uid, nil
+		}
+	}
+	return "", fmt.Errorf("failed to generate uid")
+}
@@ -0,0 +1,134 @@
+package customroles
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	sqlStore := db.InitTestDB(t)
+	store := &xormStore{db: sqlStore}
+
+	now := time.Now()
+	teamRow := &team.Team{UID: "devs", OrgID: 1, Name: "devs", Created: now, Updated: now}
+	err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Insert(&org.OrgUser{OrgID: 1, UserID: 2, Role: org.RoleViewer, Created: now, Updated: now}); err != nil {
+			return err
+		}
+		_, err := sess.Insert(teamRow)
+		return err
+	})
+	require.NoError(t, err)
+
+	reader := &ac.RoleDTO{
+		OrgID: 1, Version: 1, UID: "reader", Name: ac.CustomRolePrefix + "reader", DisplayName: "Reader",
+		Permissions: []ac.Permission{{Action: "dashboards:read", Scope: "dashboards:*"}},
+	}
+	writer := &ac.RoleDTO{
+		OrgID: 1, Version: 1, UID: "writer", Name: ac.CustomRolePrefix + "writer", Hidden: true,
+		Permissions: []ac.Permission{{Action: "dashboards:write", Scope: "folders:uid:abc"}},
+	}
+	require.NoError(t, store.CreateRole(ctx, reader))
+	require.NoError(t, store.CreateRole(ctx, writer))
+
+	t.Run("should create, list and update the roles", func(t *testing.T) {
+		err := store.CreateRole(ctx, &ac.RoleDTO{OrgID: 1, UID: "other", Name: reader.Name})
+		assert.ErrorIs(t, err, ErrRoleAlreadyExists)
+
+		roles, err := store.ListRoles(ctx, 1, false)
+		require.NoError(t, err)
+		require.Len(t, roles, 1, "the hidden roles are left out")
+		assert.Equal(t, "reader", roles[0].UID)
+
+		roles, err = store.ListRoles(ctx, 1, true)
+		require.NoError(t, err)
+		assert.Len(t, roles, 2)
+
+		roles, err = store.ListRoles(ctx, 2, true)
+		require.NoError(t, err)
+		assert.Empty(t, roles)
+
+		update := *reader
+		update.Version = 2
+		update.Description = "Reads dashboards and folders"
+		update.Permissions = []ac.Permission{{Action: "dashboards:read", Scope: "dashboards:*"}, {Action: "folders:read", Scope: "folders:*"}}
+		require.NoError(t, store.UpdateRole(ctx, &update))
+
+		got, err := store.GetRole(ctx, 1, "reader")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), got.Version)
+		assert.Equal(t, "Reads dashboards and folders", got.Description)
+		require.Len(t, got.Permissions, 2)
+		assert.Equal(t, "folders:read", got.Permissions[1].Action)
+		assert.Equal(t, "folders", got.Permissions[1].Kind)
+
+		_, err = store.GetRole(ctx, 2, "reader")
+		assert.ErrorIs(t, err, ErrRoleNotFound)
+	})
+
+	t.Run("should assign the roles to the users and teams of the org", func(t *testing.T) {
+		require.NoError(t, store.AddUserRole(ctx, 1, 2, reader.ID))
+		require.NoError(t, store.AddUserRole(ctx, 1, 2, reader.ID), "assigning a role twice is a no-op")
+		assert.ErrorIs(t, store.AddUserRole(ctx, 1, 3, reader.ID), ac.ErrAssignmentEntityNotFound)
+
+		require.NoError(t, store.SetTeamRoles(ctx, 1, teamRow.ID, []int64{reader.ID, writer.ID}))
+		assert.ErrorIs(t, store.AddTeamRole(ctx, 2, teamRow.ID, reader.ID), ac.ErrAssignmentEntityNotFound)
+
+		roles, err := store.GetUserRoles(ctx, 1, 2)
+		require.NoError(t, err)
+		require.Len(t, roles, 1)
+		assert.Equal(t, "reader", roles[0].UID)
+
+		roles, err = store.GetTeamRoles(ctx, 1, teamRow.ID)
+		require.NoError(t, err)
+		assert.Len(t, roles, 2)
+
+		users, teams, err := store.GetRoleAssignees(ctx, reader.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{2}, users)
+		assert.Equal(t, []int64{teamRow.ID}, teams)
+
+		require.NoError(t, store.SetUserRoles(ctx, 1, 2, []int64{writer.ID}))
+		roles, err = store.GetUserRoles(ctx, 1, 2)
+		require.NoError(t, err)
+		require.Len(t, roles, 1)
+		assert.Equal(t, "writer", roles[0].UID)
+
+		require.NoError(t, store.RemoveTeamRole(ctx, 1, teamRow.ID, writer.ID))
+		roles, err = store.GetTeamRoles(ctx, 1, teamRow.ID)
+		require.NoError(t, err)
+		assert.Len(t, roles, 1)
+	})
+
+	t.Run("should only delete the assigned roles with force", func(t *testing.T) {
+		assert.ErrorIs(t, store.DeleteRole(ctx, 1, "reader", false), ErrRoleAssigned)
+
+		require.NoError(t, store.DeleteRole(ctx, 1, "reader", true))
+		_, err := store.GetRole(ctx, 1, "reader")
+		assert.ErrorIs(t, err, ErrRoleNotFound)
+
+		roles, err := store.GetTeamRoles(ctx, 1, teamRow.ID)
+		require.NoError(t, err)
+		assert.Empty(t, roles, "the assignments are deleted with the role")
+
+		assert.ErrorIs(t, store.DeleteRole(ctx, 1, "reader", true), ErrRoleNotFound)
+	})
+}
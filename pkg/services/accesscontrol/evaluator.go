@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -287,3 +288,27 @@ func (a anyEvaluator) GoString() string {
 
 	return fmt.Sprintf("any(%s)", strings.Join(permissions, " "))
 }
+
+// EvaluatorActions returns the actions checked by the evaluator, in the order they're checked and without duplicates
+func EvaluatorActions(evaluator Evaluator) []string {
+	var actions []string
+	var walk func(e Evaluator)
+	walk = func(e Evaluator) {
+		switch e := e.(type) {
+		case permissionEvaluator:
+			if !slices.Contains(actions, e.Action) {
+				actions = append(actions, e.Action)
+			}
+		case allEvaluator:
+			for _, inner := range e.allOf {
+				walk(inner)
+			}
+		case anyEvaluator:
+			for _, inner := range e.anyOf {
+				walk(inner)
+			}
+		}
+	}
+	walk(evaluator)
+	return actions
+}
@@ -456,3 +456,12 @@ func TestEval_MutateScopes(t *testing.T) {
 		assert.True(t, hasAccess)
 	})
 }
+
+func TestEvaluatorActions(t *testing.T) {
+	evaluator := EvalAll(
+		EvalPermission("dashboards:read", "dashboards:uid:1"),
+		EvalAny(EvalPermission("dashboards:write"), EvalPermission("dashboards:read")),
+	)
+	assert.Equal(t, []string{"dashboards:read", "dashboards:write"}, EvaluatorActions(evaluator))
+	assert.Empty(t, EvaluatorActions(EvalAll()))
+}
@@ -27,9 +27,25 @@ import (
 	"github.com/grafana/grafana/pkg/web"
 )
 
+// AuthorizeHandler is the handler returned by Middleware, its evaluator documents the permissions required by the
+// route
+type AuthorizeHandler struct {
+	evaluator Evaluator
+	handler   web.Handler
+}
+
+func (h *AuthorizeHandler) Unwrap() web.Handler {
+	return h.handler
+}
+
+// Evaluator returns the permissions the requests must have
+func (h *AuthorizeHandler) Evaluator() Evaluator {
+	return h.evaluator
+}
+
 func Middleware(ac AccessControl) func(Evaluator) web.Handler {
 	return func(evaluator Evaluator) web.Handler {
-		return func(c *contextmodel.ReqContext) {
+		handler := func(c *contextmodel.ReqContext) {
 			ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.Middleware")
 			defer span.End()
 			c.Req = c.Req.WithContext(ctx)
@@ -60,6 +76,7 @@ func Middleware(ac AccessControl) func(Evaluator) web.Handler {
 
 			authorize(c, ac, c.SignedInUser, evaluator)
 		}
+		return &AuthorizeHandler{evaluator: evaluator, handler: handler}
 	}
 }
 
@@ -233,7 +250,7 @@ type OrgIDGetter func(c *contextmodel.ReqContext) (int64, error)
 
 func AuthorizeInOrgMiddleware(ac AccessControl, authnService authn.Service) func(OrgIDGetter, Evaluator) web.Handler {
 	return func(getTargetOrg OrgIDGetter, evaluator Evaluator) web.Handler {
-		return func(c *contextmodel.ReqContext) {
+		handler := func(c *contextmodel.ReqContext) {
 			ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.AuthorizeInOrgMiddleware")
 			defer span.End()
 			c.Req = c.Req.WithContext(ctx)
@@ -271,6 +288,7 @@ func AuthorizeInOrgMiddleware(ac AccessControl, authnService authn.Service) func
 			}
 			c.Permissions[orgUser.GetOrgID()] = orgUser.GetPermissions()
 		}
+		return &AuthorizeHandler{evaluator: evaluator, handler: handler}
 	}
 }
 
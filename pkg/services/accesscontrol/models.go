@@ -353,9 +353,13 @@ const (
 	ActionUsersDisable           = "users:disable"
 	ActionUsersPermissionsUpdate = "users.permissions:write"
 	ActionUsersLogout            = "users:logout"
+	ActionUsersImpersonate       = "users:impersonate"
 	ActionUsersQuotasList        = "users.quotas:read"
 	ActionUsersQuotasUpdate      = "users.quotas:write"
 	ActionUsersPermissionsRead   = "users.permissions:read"
+	ActionUsersRolesRead         = "users.roles:read"
+	ActionUsersRolesAdd          = "users.roles:add"
+	ActionUsersRolesRemove       = "users.roles:remove"
 
 	// Org actions
 	ActionOrgsRead             = "orgs:read"
@@ -380,6 +384,15 @@ const (
 
 	// Server actions
 	ActionServerStatsRead = "server.stats:read"
+	ActionServerAuditRead = "server.audit:read"
+
+	// Roles actions
+	ActionRolesRead   = "roles:read"
+	ActionRolesWrite  = "roles:write"
+	ActionRolesDelete = "roles:delete"
+	// Temporary grants actions
+	ActionRolesGrantsRead    = "roles.grants:read"
+	ActionRolesGrantsApprove = "roles.grants:approve"
 
 	// Settings actions
 	ActionSettingsRead  = "settings:read"
@@ -398,6 +411,11 @@ const (
 	ScopeUsersAll    = "users:*"
 	ScopeUsersPrefix = "users:id:"
 
+	// Roles scopes
+	ScopeRolesAll = "roles:*"
+	// ScopePermissionsDelegate restricts role management to the permissions the signed in user has
+	ScopePermissionsDelegate = "permissions:type:delegate"
+
 	// Settings scope
 	ScopeSettingsAll  = "settings:*"
 	ScopeSettingsSAML = "settings:auth.saml:*"
@@ -409,6 +427,9 @@ const (
 	ActionTeamsWrite            = "teams:write"
 	ActionTeamsPermissionsRead  = "teams.permissions:read"
 	ActionTeamsPermissionsWrite = "teams.permissions:write"
+	ActionTeamsRolesRead        = "teams.roles:read"
+	ActionTeamsRolesAdd         = "teams.roles:add"
+	ActionTeamsRolesRemove      = "teams.roles:remove"
 
 	// Team related scopes
 	ScopeTeamsAll = "teams:*"
@@ -509,6 +530,12 @@ var (
 	// Team scope
 	ScopeTeamsID = Scope("teams", "id", Parameter(":teamId"))
 
+	// User scope
+	ScopeUsersID = Scope("users", "id", Parameter(":userId"))
+
+	// Role scope
+	ScopeRolesUID = Scope("roles", "uid", Parameter(":roleUID"))
+
 	ScopeSettingsOAuth = func(provider string) string {
 		return Scope("settings", "auth."+provider, "*")
 	}
@@ -66,7 +66,9 @@ var _ PermissionRegistry = &permissionRegistry{}
 type permissionRegistry struct {
 	actionScopePrefixes map[string]PrefixSet // TODO use thread safe map
 	kindScopePrefix     map[string]string
-	logger              log.Logger
+	// kindConditionPrefixes maps the condition scope prefixes accepted for the actions on a kind
+	kindConditionPrefixes map[string][]string
+	logger                log.Logger
 }
 
 func ProvidePermissionRegistry() PermissionRegistry {
@@ -97,10 +99,16 @@ func newPermissionRegistry() *permissionRegistry {
 		"secret.securevalues": "secret.securevalues:uid:",
 		"secret.keepers":      "secret.keepers:uid:",
 	}
+	// defaultKindConditions maps the condition scope prefixes (permissions on an attribute of the resources) for a given kind
+	defaultKindConditions := map[string][]string{
+		"dashboards":  {"dashboards:tag:"},
+		"datasources": {"datasources:type:"},
+	}
 	return &permissionRegistry{
-		actionScopePrefixes: make(map[string]PrefixSet, 200),
-		kindScopePrefix:     defaultKindScopes,
-		logger:              log.New("accesscontrol.permreg"),
+		actionScopePrefixes:   make(map[string]PrefixSet, 200),
+		kindScopePrefix:       defaultKindScopes,
+		kindConditionPrefixes: defaultKindConditions,
+		logger:                log.New("accesscontrol.permreg"),
 	}
 }
 
@@ -165,12 +173,26 @@ func (pr *permissionRegistry) IsPermissionValid(action, scope string) error {
 		return nil
 	}
 
-	if !isScopeValid(scope, validScopePrefixes) {
+	if !isScopeValid(scope, validScopePrefixes) && !pr.isConditionValid(scope, validScopePrefixes) {
 		return ErrInvalidScope(scope, action, validScopePrefixes)
 	}
 	return nil
 }
 
+// isConditionValid checks the scope is a condition on a kind of resources the action applies to
+func (pr *permissionRegistry) isConditionValid(scope string, validScopePrefixes PrefixSet) bool {
+	kind := strings.Split(scope, ":")[0]
+	if !validScopePrefixes[pr.kindScopePrefix[kind]] {
+		return false
+	}
+	for _, conditionPrefix := range pr.kindConditionPrefixes[kind] {
+		if strings.HasPrefix(scope, conditionPrefix) && len(scope) > len(conditionPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func isScopeValid(scope string, validScopePrefixes PrefixSet) bool {
 	// Super wildcard scope
 	if scope == "*" {
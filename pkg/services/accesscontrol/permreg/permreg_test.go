@@ -148,6 +148,24 @@ func Test_permissionRegistry_IsPermissionValid(t *testing.T) {
 			scope:   "folders:uid:my_team_folder",
 			wantErr: false,
 		},
+		{
+			name:    "valid dashboards read with tag condition",
+			action:  "dashboards:read",
+			scope:   "dashboards:tag:team-a",
+			wantErr: false,
+		},
+		{
+			name:    "invalid folders read with dashboard tag condition",
+			action:  "folders:read",
+			scope:   "dashboards:tag:team-a",
+			wantErr: true,
+		},
+		{
+			name:    "invalid dashboards read with empty tag condition",
+			action:  "dashboards:read",
+			scope:   "dashboards:tag:",
+			wantErr: true,
+		},
 		{
 			name:    "valid folders read with super wildcard",
 			action:  "folders:read",
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -296,6 +297,16 @@ func (s *store) setResourcePermission(
 		return nil, err
 	}
 
+	if len(remove) > 0 || len(missing) > 0 {
+		sess.PublishAfterCommit(&events.PermissionsChanged{
+			Timestamp:  time.Now(),
+			OrgID:      orgID,
+			Resource:   cmd.Resource,
+			ResourceID: cmd.ResourceID,
+			RoleName:   roleName,
+		})
+	}
+
 	permission := flatPermissionsToResourcePermission(scope, permissions)
 	if permission == nil {
 		return &accesscontrol.ResourcePermission{}, nil
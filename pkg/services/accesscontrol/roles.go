@@ -16,6 +16,10 @@ const (
 	BasicRolePrefix    = "basic:"
 	BasicRoleUIDPrefix = "basic_"
 
+	CustomRolePrefix = "custom:"
+	// TemporaryRolePrefix names the roles holding the permissions of temporary grants
+	TemporaryRolePrefix = "temporary:"
+
 	ExternalServiceRolePrefix    = "extsvc:"
 	ExternalServiceRoleUIDPrefix = "extsvc_"
 
@@ -122,6 +126,71 @@ var (
 		},
 	}
 
+	rolesReaderRole = RoleDTO{
+		Name:        "fixed:roles:reader",
+		DisplayName: "Reader",
+		Description: "Read all access control roles, roles and permissions assigned to users, teams, and temporary grants.",
+		Group:       "Role-based access control",
+		Permissions: []Permission{
+			{
+				Action: ActionRolesRead,
+				Scope:  ScopeRolesAll,
+			},
+			{
+				Action: ActionTeamsRolesRead,
+				Scope:  ScopeTeamsAll,
+			},
+			{
+				Action: ActionUsersRolesRead,
+				Scope:  ScopeUsersAll,
+			},
+			{
+				Action: ActionUsersPermissionsRead,
+				Scope:  ScopeUsersAll,
+			},
+			{
+				Action: ActionRolesGrantsRead,
+			},
+		},
+	}
+
+	rolesWriterRole = RoleDTO{
+		Name:        "fixed:roles:writer",
+		DisplayName: "Writer",
+		Description: "Create, read, update, or delete all roles, assign or unassign roles to users, teams, approve or revoke temporary grants.",
+		Group:       "Role-based access control",
+		Permissions: ConcatPermissions(rolesReaderRole.Permissions, []Permission{
+			{
+				Action: ActionRolesWrite,
+				Scope:  ScopePermissionsDelegate,
+			},
+			{
+				Action: ActionRolesDelete,
+				Scope:  ScopePermissionsDelegate,
+			},
+			{
+				Action: ActionTeamsRolesAdd,
+				Scope:  ScopePermissionsDelegate,
+			},
+			{
+				Action: ActionTeamsRolesRemove,
+				Scope:  ScopePermissionsDelegate,
+			},
+			{
+				Action: ActionUsersRolesAdd,
+				Scope:  ScopePermissionsDelegate,
+			},
+			{
+				Action: ActionUsersRolesRemove,
+				Scope:  ScopePermissionsDelegate,
+			},
+			{
+				Action: ActionRolesGrantsApprove,
+				Scope:  ScopePermissionsDelegate,
+			},
+		}),
+	}
+
 	statsReaderRole = RoleDTO{
 		Name:        "fixed:stats:reader",
 		DisplayName: "Reader",
@@ -134,6 +203,18 @@ var (
 		},
 	}
 
+	auditReaderRole = RoleDTO{
+		Name:        "fixed:audit:reader",
+		DisplayName: "Audit log reader",
+		Description: "Read the audit log of the Grafana instance.",
+		Group:       "Statistics",
+		Permissions: []Permission{
+			{
+				Action: ActionServerAuditRead,
+			},
+		},
+	}
+
 	usersReaderRole = RoleDTO{
 		Name:        "fixed:users:reader",
 		DisplayName: "Reader (global)",
@@ -203,6 +284,19 @@ var (
 		}),
 	}
 
+	usersImpersonatorRole = RoleDTO{
+		Name:        "fixed:users:impersonator",
+		DisplayName: "Impersonator",
+		Description: "Sign in as other users of the organization, when impersonation is enabled.",
+		Group:       "User administration",
+		Permissions: []Permission{
+			{
+				Action: ActionUsersImpersonate,
+				Scope:  ScopeGlobalUsersAll,
+			},
+		},
+	}
+
 	authenticationConfigWriterRole = RoleDTO{
 		Name:        "fixed:authentication.config:writer",
 		DisplayName: "Authentication config writer",
@@ -326,10 +420,22 @@ func DeclareFixedRoles(service Service, cfg *setting.Cfg) error {
 		Role:   SettingsReaderRole,
 		Grants: []string{RoleGrafanaAdmin},
 	}
+	rolesReader := RoleRegistration{
+		Role:   rolesReaderRole,
+		Grants: []string{RoleGrafanaAdmin},
+	}
+	rolesWriter := RoleRegistration{
+		Role:   rolesWriterRole,
+		Grants: []string{RoleGrafanaAdmin},
+	}
 	statsReader := RoleRegistration{
 		Role:   statsReaderRole,
 		Grants: []string{RoleGrafanaAdmin},
 	}
+	auditReader := RoleRegistration{
+		Role:   auditReaderRole,
+		Grants: []string{RoleGrafanaAdmin},
+	}
 	usersReader := RoleRegistration{
 		Role:   usersReaderRole,
 		Grants: []string{RoleGrafanaAdmin},
@@ -338,6 +444,10 @@ func DeclareFixedRoles(service Service, cfg *setting.Cfg) error {
 		Role:   usersWriterRole,
 		Grants: []string{RoleGrafanaAdmin},
 	}
+	usersImpersonator := RoleRegistration{
+		Role:   usersImpersonatorRole,
+		Grants: []string{RoleGrafanaAdmin},
+	}
 	generalAuthConfigWriter := RoleRegistration{
 		Role:   generalAuthConfigWriterRole,
 		Grants: []string{RoleGrafanaAdmin},
@@ -355,7 +465,7 @@ func DeclareFixedRoles(service Service, cfg *setting.Cfg) error {
 
 	return service.DeclareFixedRoles(
 		ldapReader, ldapWriter, orgUsersReader, orgUsersWriter,
-		settingsReader, statsReader, usersReader, usersWriter,
+		settingsReader, rolesReader, rolesWriter, statsReader, auditReader, usersReader, usersWriter, usersImpersonator,
 		authenticationConfigWriter, generalAuthConfigWriter, usageStatsReader,
 	)
 }
@@ -0,0 +1,210 @@
+package annotationingest
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	ErrIntegrationNotFound = errutil.NotFound(
+		"annotations.ingest.not-found", errutil.WithPublicMessage("Annotation integration not found"))
+	ErrInvalidSignature = errutil.Unauthorized(
+		"annotations.ingest.invalid-signature", errutil.WithPublicMessage("Invalid signature"))
+	ErrInvalidEvent = errutil.BadRequest("annotations.ingest.invalid-event")
+)
+
+// MaxBodySize is the maximum size of the body of the events
+const MaxBodySize = 1 << 20
+
+// kvNamespace is the namespace of the annotation IDs of the dedup keys, followed by the name of the integration
+const kvNamespace = "annotation-ingest."
+
+// Statuses of the ingested events
+const (
+	StatusCreated = "created"
+	StatusUpdated = "updated"
+	StatusIgnored = "ignored"
+)
+
+// Result is the outcome of an ingested event
+type Result struct {
+	// Status is created, updated, or ignored when the condition of the integration skips the event
+	Status string `json:"status"`
+	// AnnotationID is the ID of the annotation created or updated for the event
+	AnnotationID int64 `json:"annotationId,omitempty"`
+}
+
+type integration struct {
+	setting.AnnotationIngestIntegration
+	mapping *mapping
+}
+
+// Service maps the events posted by external systems, such as deployments and incidents, to annotations. Each
+// integration is configured in a [annotations.ingest.<name>] section with the secret signing its events and the
+// templates of its annotations. The events with the same dedup key update the annotation of the first one.
+type Service struct {
+	integrations map[string]*integration
+	repo         annotations.Repository
+	kv           kvstore.KVStore
+	events       *prometheus.CounterVec
+	// dedupMu prevents two events with the same dedup key from both creating an annotation
+	dedupMu sync.Mutex
+	log     log.Logger
+	now     func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, repo annotations.Repository, kv kvstore.KVStore, reg prometheus.Registerer) (*Service, error) {
+	s := &Service{
+		integrations: make(map[string]*integration, len(cfg.AnnotationIngestIntegrations)),
+		repo:         repo,
+		kv:           kv,
+		events: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Name:      "annotation_ingest_events_total",
+			Help:      "Number of events received by the annotation integrations, by status",
+		}, []string{"integration", "status"}),
+		log: log.New("annotations.ingest"),
+		now: time.Now,
+	}
+	for _, i := range cfg.AnnotationIngestIntegrations {
+		m, err := newMapping(i)
+		if err != nil {
+			return nil, err
+		}
+		s.integrations[i.Name] = &integration{AnnotationIngestIntegration: i, mapping: m}
+	}
+	return s, nil
+}
+
+// Ingest validates the signature of an event posted to an integration and creates or updates its annotation
+func (s *Service) Ingest(ctx context.Context, name string, header http.Header, body []byte) (*Result, error) {
+	i, ok := s.integrations[name]
+	if !ok {
+		return nil, ErrIntegrationNotFound.Errorf("annotation integration %s not found", name)
+	}
+
+	result, err := s.ingest(ctx, i, header, body)
+	switch {
+	case err == nil:
+		s.events.WithLabelValues(name, result.Status).Inc()
+	case errors.Is(err, ErrInvalidSignature):
+		s.events.WithLabelValues(name, "rejected").Inc()
+	default:
+		s.events.WithLabelValues(name, "failed").Inc()
+	}
+	return result, err
+}
+
+func (s *Service) ingest(ctx context.Context, i *integration, header http.Header, body []byte) (*Result, error) {
+	if !verifySignature(i.Secret, sources[i.Source], header, body) {
+		return nil, ErrInvalidSignature.Errorf("invalid signature of an event of the annotation integration %s", i.Name)
+	}
+
+	var payload any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// the numbers are kept as they're sent, the IDs and timestamps would be printed in scientific notation
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, ErrInvalidEvent.Errorf("the event isn't valid JSON: %w", err)
+	}
+	a, err := i.mapping.render(header, payload, s.now())
+	if err != nil {
+		return nil, ErrInvalidEvent.Errorf("failed to map the event to an annotation: %w", err)
+	}
+	if a == nil {
+		return &Result{Status: StatusIgnored}, nil
+	}
+
+	item := &annotations.Item{
+		OrgID:    i.OrgID,
+		Epoch:    a.epoch,
+		EpochEnd: a.epochEnd,
+		Text:     a.text,
+		Tags:     a.tags,
+	}
+	if a.dedupKey == "" {
+		if err := s.repo.Save(ctx, item); err != nil {
+			return nil, err
+		}
+		return &Result{Status: StatusCreated, AnnotationID: item.ID}, nil
+	}
+
+	s.dedupMu.Lock()
+	defer s.dedupMu.Unlock()
+
+	// the keys are hashed to fit in the key column whatever their length
+	kv := kvstore.WithNamespace(s.kv, i.OrgID, kvNamespace+i.Name)
+	hash := sha256.Sum256([]byte(a.dedupKey))
+	key := hex.EncodeToString(hash[:])
+	value, ok, err := kv.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		// the annotation keeps the time of the first event
+		item.ID = id
+		item.Epoch = 0
+		err = s.repo.Update(ctx, item)
+		if err == nil {
+			return &Result{Status: StatusUpdated, AnnotationID: id}, nil
+		}
+		if !errors.Is(err, annotations.ErrAnnotationNotFound) {
+			return nil, err
+		}
+		// the annotation was deleted, the event creates a new one
+		s.log.Debug("Annotation of a dedup key not found", "integration", i.Name, "annotationID", id)
+		item.ID = 0
+		item.Epoch = a.epoch
+	}
+
+	if err := s.repo.Save(ctx, item); err != nil {
+		return nil, err
+	}
+	if err := kv.Set(ctx, key, strconv.FormatInt(item.ID, 10)); err != nil {
+		return nil, err
+	}
+	return &Result{Status: StatusCreated, AnnotationID: item.ID}, nil
+}
+
+// verifySignature checks the hex encoded HMAC-SHA256 of the body in the signature header of the source, the header
+// can have several comma separated signatures while the secret is rotated
+func verifySignature(secret string, src source, header http.Header, body []byte) bool {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	expected := mac.Sum(nil)
+
+	for _, signature := range strings.Split(header.Get(src.signatureHeader), ",") {
+		signature, ok := strings.CutPrefix(strings.TrimSpace(signature), src.signaturePrefix)
+		if !ok {
+			continue
+		}
+		decoded, err := hex.DecodeString(signature)
+		if err == nil && hmac.Equal(decoded, expected) {
+			return true
+		}
+	}
+	return false
+}
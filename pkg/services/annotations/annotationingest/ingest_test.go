@@ -0,0 +1,247 @@
+package annotationingest
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+const githubDeployment = `{
+	"deployment_status": {"state": "%s"},
+	"deployment": {"id": 1234567890123, "ref": "v1.2.0", "environment": "production", "created_at": "2024-06-01T11:58:00Z"},
+	"repository": {"full_name": "grafana/app"}
+}`
+
+func TestService_Ingest(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should map a GitHub deployment to an annotation and update it with the next statuses", func(t *testing.T) {
+		s, repo := setupTestService(t, setting.AnnotationIngestIntegration{Name: "deploys", OrgID: 2, Source: "github", Secret: "secret"})
+
+		body := []byte(fmt.Sprintf(githubDeployment, "in_progress"))
+		result, err := s.Ingest(ctx, "deploys", githubHeader("secret", "deployment_status", body), body)
+		require.NoError(t, err)
+		assert.Equal(t, StatusCreated, result.Status)
+
+		item := repo.items[result.AnnotationID]
+		assert.EqualValues(t, 2, item.OrgID)
+		assert.Equal(t, "Deployment of v1.2.0 to production: in_progress", item.Text)
+		assert.Equal(t, []string{"github", "deployment", "grafana/app", "production"}, item.Tags)
+		assert.Equal(t, time.Date(2024, 6, 1, 11, 58, 0, 0, time.UTC).UnixMilli(), item.Epoch)
+
+		body = []byte(fmt.Sprintf(githubDeployment, "success"))
+		result, err = s.Ingest(ctx, "deploys", githubHeader("secret", "deployment_status", body), body)
+		require.NoError(t, err)
+		assert.Equal(t, StatusUpdated, result.Status)
+		require.Len(t, repo.items, 1)
+		assert.Equal(t, "Deployment of v1.2.0 to production: success", repo.items[result.AnnotationID].Text)
+	})
+
+	t.Run("should ignore the events skipped by the condition", func(t *testing.T) {
+		s, repo := setupTestService(t, setting.AnnotationIngestIntegration{Name: "deploys", OrgID: 1, Source: "github", Secret: "secret"})
+
+		body := []byte(`{"zen": "Keep it logically awesome."}`)
+		result, err := s.Ingest(ctx, "deploys", githubHeader("secret", "ping", body), body)
+		require.NoError(t, err)
+		assert.Equal(t, StatusIgnored, result.Status)
+		assert.Empty(t, repo.items)
+	})
+
+	t.Run("should reject the events with an invalid signature", func(t *testing.T) {
+		s, repo := setupTestService(t, setting.AnnotationIngestIntegration{Name: "deploys", OrgID: 1, Source: "github", Secret: "secret"})
+
+		body := []byte(fmt.Sprintf(githubDeployment, "success"))
+		_, err := s.Ingest(ctx, "deploys", githubHeader("other", "deployment_status", body), body)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+		_, err = s.Ingest(ctx, "deploys", http.Header{"X-Github-Event": {"deployment_status"}}, body)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+		assert.Empty(t, repo.items)
+	})
+
+	t.Run("should return an error for an unknown integration", func(t *testing.T) {
+		s, _ := setupTestService(t)
+
+		_, err := s.Ingest(ctx, "deploys", http.Header{}, []byte(`{}`))
+		assert.ErrorIs(t, err, ErrIntegrationNotFound)
+	})
+
+	t.Run("should set the end of a PagerDuty incident when it's resolved", func(t *testing.T) {
+		s, repo := setupTestService(t, setting.AnnotationIngestIntegration{Name: "incidents", OrgID: 1, Source: "pagerduty", Secret: "secret"})
+		event := `{"event": {"event_type": "incident.%s", "occurred_at": "%s",
+			"data": {"id": "PGR0VU2", "title": "Checkout is down", "status": "%s", "urgency": "high", "service": {"summary": "checkout"}}}}`
+
+		body := []byte(fmt.Sprintf(event, "triggered", "2024-06-01T11:00:00Z", "triggered"))
+		result, err := s.Ingest(ctx, "incidents", http.Header{"X-Pagerduty-Signature": {"v1=" + sign("old", body) + ",v1=" + sign("secret", body)}}, body)
+		require.NoError(t, err)
+		assert.Equal(t, StatusCreated, result.Status)
+		assert.Equal(t, []string{"pagerduty", "incident", "checkout", "high"}, repo.items[result.AnnotationID].Tags)
+
+		body = []byte(fmt.Sprintf(event, "resolved", "2024-06-01T11:30:00Z", "resolved"))
+		result, err = s.Ingest(ctx, "incidents", http.Header{"X-Pagerduty-Signature": {"v1=" + sign("secret", body)}}, body)
+		require.NoError(t, err)
+		assert.Equal(t, StatusUpdated, result.Status)
+		item := repo.items[result.AnnotationID]
+		assert.Equal(t, "Checkout is down: resolved", item.Text)
+		assert.Equal(t, time.Date(2024, 6, 1, 11, 0, 0, 0, time.UTC).UnixMilli(), item.Epoch)
+		assert.Equal(t, time.Date(2024, 6, 1, 11, 30, 0, 0, time.UTC).UnixMilli(), item.EpochEnd)
+	})
+
+	t.Run("should create a new annotation when the annotation of the dedup key was deleted", func(t *testing.T) {
+		s, repo := setupTestService(t, setting.AnnotationIngestIntegration{Name: "builds", OrgID: 1, Source: "jenkins", Secret: "secret"})
+
+		body := []byte(`{"name": "app", "build": {"number": 42, "phase": "STARTED"}}`)
+		result, err := s.Ingest(ctx, "builds", http.Header{"X-Grafana-Annotation-Signature": {"sha256=" + sign("secret", body)}}, body)
+		require.NoError(t, err)
+		assert.Equal(t, "app build 42 STARTED", repo.items[result.AnnotationID].Text)
+		assert.Equal(t, now.UnixMilli(), repo.items[result.AnnotationID].Epoch)
+		delete(repo.items, result.AnnotationID)
+
+		body = []byte(`{"name": "app", "build": {"number": 42, "phase": "COMPLETED", "status": "SUCCESS"}}`)
+		result, err = s.Ingest(ctx, "builds", http.Header{"X-Grafana-Annotation-Signature": {"sha256=" + sign("secret", body)}}, body)
+		require.NoError(t, err)
+		assert.Equal(t, StatusCreated, result.Status)
+		assert.Equal(t, "app build 42 COMPLETED: SUCCESS", repo.items[result.AnnotationID].Text)
+	})
+
+	t.Run("should map the events of a generic integration with its templates", func(t *testing.T) {
+		s, repo := setupTestService(t, setting.AnnotationIngestIntegration{
+			Name:   "releases",
+			OrgID:  1,
+			Source: "generic",
+			Secret: "secret",
+			Text:   `Release {{ .version }} by {{ default "unknown" .author }}`,
+			Tags:   []string{"release", "{{ lower .service }}", "{{ header \"X-Region\" }}"},
+			Time:   `{{ .timestamp }}`,
+		})
+
+		body := []byte(`{"version": "2.0", "service": "API", "timestamp": 1717236000}`)
+		header := http.Header{"X-Grafana-Annotation-Signature": {"sha256=" + sign("secret", body)}, "X-Region": {"eu-west-1"}}
+		result, err := s.Ingest(ctx, "releases", header, body)
+		require.NoError(t, err)
+		item := repo.items[result.AnnotationID]
+		assert.Equal(t, "Release 2.0 by unknown", item.Text)
+		assert.Equal(t, []string{"release", "api", "eu-west-1"}, item.Tags)
+		assert.EqualValues(t, 1717236000000, item.Epoch)
+	})
+
+	t.Run("should return an error when the event can't be mapped", func(t *testing.T) {
+		s, _ := setupTestService(t, setting.AnnotationIngestIntegration{Name: "releases", OrgID: 1, Source: "generic", Secret: "secret", Text: "{{ .version }}", Time: "{{ .time }}"})
+
+		for name, body := range map[string]string{
+			"invalid JSON": `{"version"`,
+			"invalid time": `{"version": "2.0", "time": "yesterday"}`,
+		} {
+			_, err := s.Ingest(ctx, "releases", http.Header{"X-Grafana-Annotation-Signature": {"sha256=" + sign("secret", []byte(body))}}, []byte(body))
+			assert.ErrorIs(t, err, ErrInvalidEvent, name)
+		}
+	})
+}
+
+func TestProvideService(t *testing.T) {
+	t.Run("should fail with an invalid template", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.AnnotationIngestIntegrations = []setting.AnnotationIngestIntegration{{Name: "releases", OrgID: 1, Source: "generic", Secret: "secret", Text: "{{ .version"}}
+		_, err := ProvideService(cfg, newFakeRepo(), kvstore.NewFakeKVStore(), prometheus.NewRegistry())
+		assert.ErrorContains(t, err, "[annotations.ingest.releases] invalid text template")
+	})
+
+	t.Run("should fail when a generic integration has no text", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.AnnotationIngestIntegrations = []setting.AnnotationIngestIntegration{{Name: "releases", OrgID: 1, Source: "generic", Secret: "secret"}}
+		_, err := ProvideService(cfg, newFakeRepo(), kvstore.NewFakeKVStore(), prometheus.NewRegistry())
+		assert.ErrorContains(t, err, "[annotations.ingest.releases] text must be set")
+	})
+}
+
+func TestParseTime(t *testing.T) {
+	for value, expected := range map[string]int64{
+		"":                         0,
+		"1717236000":               1717236000000,
+		"1717236000123":            1717236000123,
+		"2024-06-01T10:00:00Z":     1717236000000,
+		"2024-06-01T12:00:00.5+02": -1,
+		"2024-06-01T10:00:00.123Z": 1717236000123,
+	} {
+		epoch, err := parseTime(value)
+		if expected < 0 {
+			assert.Error(t, err, value)
+			continue
+		}
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, epoch, value)
+	}
+}
+
+func setupTestService(t *testing.T, integrations ...setting.AnnotationIngestIntegration) (*Service, *fakeRepo) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.AnnotationIngestIntegrations = integrations
+	repo := newFakeRepo()
+	s, err := ProvideService(cfg, repo, kvstore.NewFakeKVStore(), prometheus.NewRegistry())
+	require.NoError(t, err)
+	s.now = func() time.Time { return now }
+	return s, repo
+}
+
+func githubHeader(secret, event string, body []byte) http.Header {
+	return http.Header{
+		"X-Github-Event":      {event},
+		"X-Hub-Signature-256": {"sha256=" + sign(secret, body)},
+	}
+}
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type fakeRepo struct {
+	annotations.Repository
+	items  map[int64]annotations.Item
+	nextID int64
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{items: map[int64]annotations.Item{}}
+}
+
+func (f *fakeRepo) Save(_ context.Context, item *annotations.Item) error {
+	f.nextID++
+	item.ID = f.nextID
+	f.items[item.ID] = *item
+	return nil
+}
+
+func (f *fakeRepo) Update(_ context.Context, item *annotations.Item) error {
+	existing, ok := f.items[item.ID]
+	if !ok {
+		return annotations.ErrAnnotationNotFound.Errorf("annotation %d not found", item.ID)
+	}
+	existing.Text = item.Text
+	existing.Tags = item.Tags
+	if item.Epoch != 0 {
+		existing.Epoch = item.Epoch
+	}
+	if item.EpochEnd != 0 {
+		existing.EpochEnd = item.EpochEnd
+	}
+	f.items[item.ID] = existing
+	return nil
+}
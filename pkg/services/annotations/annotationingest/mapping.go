@@ -0,0 +1,240 @@
+package annotationingest
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// source is how an external system signs its events and the default templates of its integrations
+type source struct {
+	// signatureHeader is the header of the hex encoded HMAC-SHA256 of the body
+	signatureHeader string
+	// signaturePrefix is the prefix of the signatures in the header, which can have several comma separated signatures
+	signaturePrefix string
+	condition       string
+	text            string
+	tags            []string
+	time            string
+	timeEnd         string
+	dedupKey        string
+}
+
+var sources = map[string]source{
+	// GitHub sends a deployment_status event when a deployment is created and each time its state changes
+	setting.AnnotationIngestSourceGitHub: {
+		signatureHeader: "X-Hub-Signature-256",
+		signaturePrefix: "sha256=",
+		condition:       `{{ eq (header "X-GitHub-Event") "deployment_status" }}`,
+		text:            `Deployment of {{ .deployment.ref }} to {{ .deployment.environment }}: {{ .deployment_status.state }}`,
+		tags:            []string{"github", "deployment", "{{ .repository.full_name }}", "{{ .deployment.environment }}"},
+		time:            `{{ .deployment.created_at }}`,
+		dedupKey:        `{{ .repository.full_name }}/deployments/{{ .deployment.id }}`,
+	},
+	// the Jenkins Notification plugin sends an event when a build starts, completes and is finalized
+	setting.AnnotationIngestSourceJenkins: {
+		signatureHeader: "X-Grafana-Annotation-Signature",
+		signaturePrefix: "sha256=",
+		text:            `{{ .name }} build {{ .build.number }} {{ .build.phase }}{{ with .build.status }}: {{ . }}{{ end }}`,
+		tags:            []string{"jenkins", "build", "{{ .name }}"},
+		dedupKey:        `{{ .name }}/builds/{{ .build.number }}`,
+	},
+	// PagerDuty V3 webhooks send an event when an incident is triggered, acknowledged and resolved
+	setting.AnnotationIngestSourcePagerDuty: {
+		signatureHeader: "X-PagerDuty-Signature",
+		signaturePrefix: "v1=",
+		condition:       `{{ and .event (hasPrefix .event.event_type "incident.") }}`,
+		text:            `{{ .event.data.title }}: {{ .event.data.status }}`,
+		tags:            []string{"pagerduty", "incident", "{{ .event.data.service.summary }}", "{{ .event.data.urgency }}"},
+		time:            `{{ .event.occurred_at }}`,
+		timeEnd:         `{{ if eq .event.event_type "incident.resolved" }}{{ .event.occurred_at }}{{ end }}`,
+		dedupKey:        `{{ .event.data.id }}`,
+	},
+	setting.AnnotationIngestSourceGeneric: {
+		signatureHeader: "X-Grafana-Annotation-Signature",
+		signaturePrefix: "sha256=",
+	},
+}
+
+// mapping is the parsed templates of an integration
+type mapping struct {
+	condition *template.Template
+	text      *template.Template
+	tags      []*template.Template
+	time      *template.Template
+	timeEnd   *template.Template
+	dedupKey  *template.Template
+}
+
+// annotation is an annotation rendered by a mapping
+type annotation struct {
+	text     string
+	tags     []string
+	epoch    int64
+	epochEnd int64
+	dedupKey string
+}
+
+// templateFuncs are the functions of the templates, header is replaced by the headers of each event
+var templateFuncs = template.FuncMap{
+	"header":     func(string) string { return "" },
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"hasPrefix":  strings.HasPrefix,
+	"trimPrefix": strings.TrimPrefix,
+	"default": func(fallback string, value any) any {
+		if value == nil || value == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+// newMapping parses the templates of the integration, the empty ones are the defaults of its source
+func newMapping(integration setting.AnnotationIngestIntegration) (*mapping, error) {
+	src := sources[integration.Source]
+	or := func(value, fallback string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	}
+	parse := func(name, text string) (*template.Template, error) {
+		if text == "" {
+			return nil, nil
+		}
+		tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("[annotations.ingest.%s] invalid %s template: %w", integration.Name, name, err)
+		}
+		return tmpl, nil
+	}
+
+	m := &mapping{}
+	var err error
+	if m.condition, err = parse("condition", or(integration.Condition, src.condition)); err != nil {
+		return nil, err
+	}
+	if m.text, err = parse("text", or(integration.Text, src.text)); err != nil {
+		return nil, err
+	}
+	if m.text == nil {
+		return nil, fmt.Errorf("[annotations.ingest.%s] text must be set", integration.Name)
+	}
+	if m.time, err = parse("time", or(integration.Time, src.time)); err != nil {
+		return nil, err
+	}
+	if m.timeEnd, err = parse("time_end", or(integration.TimeEnd, src.timeEnd)); err != nil {
+		return nil, err
+	}
+	if m.dedupKey, err = parse("dedup_key", or(integration.DedupKey, src.dedupKey)); err != nil {
+		return nil, err
+	}
+	tags := integration.Tags
+	if len(tags) == 0 {
+		tags = src.tags
+	}
+	for _, tag := range tags {
+		tmpl, err := parse("tags", tag)
+		if err != nil {
+			return nil, err
+		}
+		m.tags = append(m.tags, tmpl)
+	}
+	return m, nil
+}
+
+// render returns the annotation of an event, or nil when the condition skips the event
+func (m *mapping) render(header http.Header, payload any, now time.Time) (*annotation, error) {
+	execute := func(tmpl *template.Template) (string, error) {
+		if tmpl == nil {
+			return "", nil
+		}
+		tmpl, err := tmpl.Clone()
+		if err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Funcs(template.FuncMap{"header": header.Get}).Execute(&buf, payload); err != nil {
+			return "", err
+		}
+		// the fields missing from the event are printed as <no value>, they're left empty instead
+		return strings.TrimSpace(strings.ReplaceAll(buf.String(), "<no value>", "")), nil
+	}
+
+	if m.condition != nil {
+		condition, err := execute(m.condition)
+		if err != nil {
+			return nil, err
+		}
+		if condition != "true" {
+			return nil, nil
+		}
+	}
+
+	a := &annotation{}
+	var err error
+	if a.text, err = execute(m.text); err != nil {
+		return nil, err
+	}
+	if a.text == "" {
+		return nil, fmt.Errorf("the text of the annotation is empty")
+	}
+	for _, tmpl := range m.tags {
+		tag, err := execute(tmpl)
+		if err != nil {
+			return nil, err
+		}
+		if tag != "" && !slices.Contains(a.tags, tag) {
+			a.tags = append(a.tags, tag)
+		}
+	}
+	if a.dedupKey, err = execute(m.dedupKey); err != nil {
+		return nil, err
+	}
+
+	rendered, err := execute(m.time)
+	if err != nil {
+		return nil, err
+	}
+	if a.epoch, err = parseTime(rendered); err != nil {
+		return nil, err
+	}
+	if a.epoch == 0 {
+		a.epoch = now.UnixMilli()
+	}
+	if rendered, err = execute(m.timeEnd); err != nil {
+		return nil, err
+	}
+	if a.epochEnd, err = parseTime(rendered); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// parseTime returns the Unix time in milliseconds of an RFC 3339 time or a Unix time in seconds or milliseconds, 0
+// when the time is empty
+func parseTime(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+		// the Unix times in milliseconds are above 1e11 since 1973
+		if epoch < 100_000_000_000 {
+			return epoch * 1000, nil
+		}
+		return epoch, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, must be an RFC 3339 time or a Unix time", value)
+	}
+	return t.UnixMilli(), nil
+}
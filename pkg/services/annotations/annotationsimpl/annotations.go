@@ -2,6 +2,8 @@ package annotationsimpl
 
 import (
 	"context"
+	"slices"
+	"sort"
 
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -17,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/tag"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 type RepositoryImpl struct {
@@ -81,9 +84,17 @@ func (r *RepositoryImpl) Find(ctx context.Context, query *annotations.ItemQuery)
 		query.Limit = 100
 	}
 
+	var after []int64
+	if query.After != nil {
+		var err error
+		if after, err = cursorValues(query.After); err != nil {
+			return nil, err
+		}
+	}
+
 	// Search without dashboard UID filter is expensive, so check without access control first
 	// nolint: staticcheck
-	if query.DashboardID == 0 && query.DashboardUID == "" {
+	if query.DashboardID == 0 && query.DashboardUID == "" && len(query.Scopes) == 0 {
 		// Return early if no annotations found, it's not necessary to perform expensive access control filtering
 		res, err := r.reader.Get(ctx, *query, &accesscontrol.AccessResources{
 			SkipAccessControlFilter: true,
@@ -121,9 +132,47 @@ func (r *RepositoryImpl) Find(ctx context.Context, query *annotations.ItemQuery)
 		}
 	}
 
+	// the annotations of the pages of dashboards are sorted separately
+	sort.Sort(annotations.SortedItems(results))
+	if after != nil {
+		// the annotations of the alert state history aren't filtered by the cursor
+		results = slices.DeleteFunc(results, func(item *annotations.ItemDTO) bool {
+			return !sortedAfter(item, after)
+		})
+	}
+	if len(results) > int(query.Limit) {
+		results = results[:query.Limit]
+	}
+
 	return results, nil
 }
 
+// cursorValues returns the values of the sort keys of the cursor
+func cursorValues(cursor *pagination.Cursor) ([]int64, error) {
+	if len(cursor.Keys) != len(annotations.SortKeys) {
+		return nil, pagination.ErrInvalidCursor.Errorf("cursor has %d keys instead of %d", len(cursor.Keys), len(annotations.SortKeys))
+	}
+	values := make([]int64, 0, len(cursor.Keys))
+	for _, key := range cursor.Keys {
+		v, ok := key.(int64)
+		if !ok {
+			return nil, pagination.ErrInvalidCursor.Errorf("cursor key %v isn't an integer", key)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// sortedAfter reports whether the annotation is sorted after the values of the cursor, the most recent first
+func sortedAfter(item *annotations.ItemDTO, after []int64) bool {
+	for i, v := range []int64{item.TimeEnd, item.Time, item.ID} {
+		if v != after[i] {
+			return v < after[i]
+		}
+	}
+	return false
+}
+
 func (r *RepositoryImpl) Delete(ctx context.Context, params *annotations.DeleteParams) error {
 	return r.writer.Delete(ctx, params)
 }
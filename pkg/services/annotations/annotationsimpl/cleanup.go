@@ -2,21 +2,49 @@ package annotationsimpl
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 // CleanupServiceImpl is responsible for cleaning old annotations.
 type CleanupServiceImpl struct {
-	store store
+	store      store
+	partitions *partitionManager
+	serverLock *serverlock.ServerLockService
+	metrics    *cleanupMetrics
+	log        log.Logger
 }
 
-func ProvideCleanupService(db db.DB, cfg *setting.Cfg) *CleanupServiceImpl {
-	return &CleanupServiceImpl{
-		store: NewXormStore(cfg, log.New("annotations"), db, nil),
+func ProvideCleanupService(db db.DB, cfg *setting.Cfg, serverLock *serverlock.ServerLockService, reg prometheus.Registerer) *CleanupServiceImpl {
+	l := log.New("annotations")
+	m := newCleanupMetrics(reg)
+
+	xormStore := NewXormStore(cfg, l, db, nil)
+	xormStore.cleanupMetrics = m
+
+	cs := &CleanupServiceImpl{
+		store:      xormStore,
+		serverLock: serverLock,
+		metrics:    m,
+		log:        l,
 	}
+	if cfg.AnnotationPartitioning.Enabled {
+		cs.partitions = newPartitionManager(db, cfg.AnnotationPartitioning, l)
+		if cs.partitions == nil {
+			l.Warn("Annotation partitioning isn't supported by the database, old annotations are deleted in batches", "type", db.GetDBType())
+		}
+	}
+	return cs
 }
 
 const (
@@ -25,34 +53,218 @@ const (
 	apiAnnotationType       = "alert_id = 0 AND dashboard_id = 0"
 )
 
+// annotationTypeConditions maps the annotation types of the retention policies to their conditions
+var annotationTypeConditions = map[string]string{
+	setting.AnnotationTypeAlert:     alertAnnotationType,
+	setting.AnnotationTypeDashboard: dashboardAnnotationType,
+	setting.AnnotationTypeAPI:       apiAnnotationType,
+}
+
 // Run deletes old annotations created by alert rules, API
 // requests and human made in the UI. It subsequently deletes orphaned rows
 // from the annotation_tag table. Cleanup actions are performed in batches
 // so that no query takes too long to complete.
 //
+// The cleanup is tiered: when the annotation table is partitioned, the
+// partitions older than the longest retention are dropped first, which
+// neither scans nor locks the rest of the table. The retention policies of
+// the organizations are then applied, followed by the retention of each
+// annotation type for the other organizations.
+//
 // Returns the number of annotation and annotation_tag rows deleted. If an
 // error occurs, it returns the number of rows affected so far.
 func (cs *CleanupServiceImpl) Run(ctx context.Context, cfg *setting.Cfg) (int64, int64, error) {
+	start := time.Now()
+	cs.metrics.running.Set(1)
+	defer cs.metrics.running.Set(0)
+
 	var totalCleanedAnnotations int64
-	affected, err := cs.store.CleanAnnotations(ctx, cfg.AlertingAnnotationCleanupSetting, alertAnnotationType)
-	totalCleanedAnnotations += affected
-	if err != nil {
-		return totalCleanedAnnotations, 0, err
+	if cs.partitions != nil {
+		// dropping partitions is an optimization, the annotations of the partitions which couldn't be
+		// dropped are deleted in batches below
+		dropped, err := cs.cleanPartitions(ctx, cfg)
+		totalCleanedAnnotations += dropped
+		if err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return totalCleanedAnnotations, 0, err
+			}
+			cs.log.Error("Failed to clean annotation partitions", "error", err)
+		}
 	}
 
-	affected, err = cs.store.CleanAnnotations(ctx, cfg.APIAnnotationCleanupSettings, apiAnnotationType)
-	totalCleanedAnnotations += affected
-	if err != nil {
-		return totalCleanedAnnotations, 0, err
+	for _, tier := range retentionTiers(cfg) {
+		affected, err := cs.store.CleanAnnotations(ctx, tier.settings, tier.condition)
+		totalCleanedAnnotations += affected
+		if err != nil {
+			return totalCleanedAnnotations, 0, err
+		}
 	}
 
-	affected, err = cs.store.CleanAnnotations(ctx, cfg.DashboardAnnotationCleanupSettings, dashboardAnnotationType)
-	totalCleanedAnnotations += affected
-	if err != nil {
-		return totalCleanedAnnotations, 0, err
-	}
+	var affected int64
+	var err error
 	if totalCleanedAnnotations > 0 {
 		affected, err = cs.store.CleanOrphanedAnnotationTags(ctx)
 	}
+	if err == nil {
+		cs.metrics.lastSuccess.SetToCurrentTime()
+	}
+	cs.metrics.lastDuration.Set(time.Since(start).Seconds())
 	return totalCleanedAnnotations, affected, err
 }
+
+// cleanPartitions creates the next partitions of the annotation table, and drops the partitions holding
+// only annotations older than every retention. It returns the number of annotations dropped.
+func (cs *CleanupServiceImpl) cleanPartitions(ctx context.Context, cfg *setting.Cfg) (int64, error) {
+	var deleted int64
+	var cleanErr error
+	clean := func(ctx context.Context) {
+		count, err := cs.partitions.ensure(ctx, timeNow())
+		if err != nil {
+			cleanErr = err
+			return
+		}
+
+		maxAge, ok := longestRetention(cfg)
+		if ok {
+			var dropped int
+			dropped, deleted, err = cs.partitions.dropBefore(ctx, timeNow().Add(-maxAge))
+			count -= dropped
+			cs.metrics.deleted.WithLabelValues("annotation", "drop_partition").Add(float64(deleted))
+			if err != nil {
+				cleanErr = err
+			}
+		}
+		cs.metrics.partitions.Set(float64(count))
+	}
+
+	if cs.serverLock == nil {
+		clean(ctx)
+		return deleted, cleanErr
+	}
+	// only one instance changes the partitions at a time, the others delete the annotations in batches
+	if err := cs.serverLock.LockExecuteAndRelease(ctx, "annotation partitions", time.Minute, clean); err != nil {
+		return 0, err
+	}
+	return deleted, cleanErr
+}
+
+// longestRetention returns the longest max age of the annotations, it returns false if some annotations
+// are kept regardless of their age, whose partitions can't be dropped
+func longestRetention(cfg *setting.Cfg) (time.Duration, bool) {
+	var longest time.Duration
+	settings := []setting.AnnotationCleanupSettings{
+		cfg.AlertingAnnotationCleanupSetting,
+		cfg.DashboardAnnotationCleanupSettings,
+		cfg.APIAnnotationCleanupSettings,
+	}
+	for _, policy := range cfg.AnnotationRetentionPolicies {
+		settings = append(settings, policy.AnnotationCleanupSettings)
+	}
+	for _, s := range settings {
+		if s.MaxAge <= 0 {
+			return 0, false
+		}
+		longest = max(longest, s.MaxAge)
+	}
+	return longest, true
+}
+
+type retentionTier struct {
+	settings  setting.AnnotationCleanupSettings
+	condition string
+}
+
+// retentionTiers returns the retention of the annotations of the organizations with a retention policy,
+// followed by the retention of each annotation type for the other organizations
+func retentionTiers(cfg *setting.Cfg) []retentionTier {
+	tiers := make([]retentionTier, 0, len(cfg.AnnotationRetentionPolicies)+3)
+	overridden := map[string][]string{}
+	for _, policy := range cfg.AnnotationRetentionPolicies {
+		cond, ok := annotationTypeConditions[policy.Type]
+		if !ok {
+			continue
+		}
+		tiers = append(tiers, retentionTier{
+			settings:  policy.AnnotationCleanupSettings,
+			condition: fmt.Sprintf("%s AND org_id = %d", cond, policy.OrgID),
+		})
+		overridden[policy.Type] = append(overridden[policy.Type], fmt.Sprint(policy.OrgID))
+	}
+
+	for _, t := range []struct {
+		annotationType string
+		settings       setting.AnnotationCleanupSettings
+	}{
+		{setting.AnnotationTypeAlert, cfg.AlertingAnnotationCleanupSetting},
+		{setting.AnnotationTypeAPI, cfg.APIAnnotationCleanupSettings},
+		{setting.AnnotationTypeDashboard, cfg.DashboardAnnotationCleanupSettings},
+	} {
+		cond := annotationTypeConditions[t.annotationType]
+		if orgs := overridden[t.annotationType]; len(orgs) > 0 {
+			cond = fmt.Sprintf("%s AND org_id NOT IN (%s)", cond, strings.Join(orgs, ", "))
+		}
+		tiers = append(tiers, retentionTier{settings: t.settings, condition: cond})
+	}
+	return tiers
+}
+
+// cleanupMetrics reports the progress of the annotation cleanup, the deleted rows are counted after each batch
+type cleanupMetrics struct {
+	deleted      *prometheus.CounterVec
+	batches      *prometheus.CounterVec
+	running      prometheus.Gauge
+	lastDuration prometheus.Gauge
+	lastSuccess  prometheus.Gauge
+	partitions   prometheus.Gauge
+}
+
+func newCleanupMetrics(reg prometheus.Registerer) *cleanupMetrics {
+	const namespace, subsystem = "grafana", "annotations_cleanup"
+	return &cleanupMetrics{
+		deleted: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "deleted_rows_total",
+			Help:      "Total number of rows deleted by the annotation cleanup, by table and method: delete or drop_partition",
+		}, []string{"table", "method"}),
+		batches: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "batches_total",
+			Help:      "Total number of batches deleted by the annotation cleanup, by table",
+		}, []string{"table"}),
+		running: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "running",
+			Help:      "1 while the annotation cleanup is running",
+		}),
+		lastDuration: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "last_duration_seconds",
+			Help:      "Duration of the last annotation cleanup",
+		}),
+		lastSuccess: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "last_success_timestamp_seconds",
+			Help:      "Time of the last successful annotation cleanup",
+		}),
+		partitions: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "partitions",
+			Help:      "Number of partitions of the annotation table",
+		}),
+	}
+}
+
+// observeBatch is a no-op on a nil receiver, ie: for the stores which don't clean annotations
+func (m *cleanupMetrics) observeBatch(table string, affected int64) {
+	if m == nil || affected == 0 {
+		return
+	}
+	m.batches.WithLabelValues(table).Inc()
+	m.deleted.WithLabelValues(table, "delete").Add(float64(affected))
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -129,7 +130,7 @@ func TestIntegrationAnnotationCleanUp(t *testing.T) {
 
 			cfg := setting.NewCfg()
 			cfg.AnnotationCleanupJobBatchSize = int64(test.annotationCleanupJobBatchSize)
-			cleaner := ProvideCleanupService(fakeSQL, cfg)
+			cleaner := ProvideCleanupService(fakeSQL, cfg, nil, prometheus.NewRegistry())
 			affectedAnnotations, affectedAnnotationTags, err := cleaner.Run(context.Background(), test.cfg)
 			require.NoError(t, err)
 
@@ -298,3 +299,33 @@ func createTestAnnotations(t *testing.T, store db.DB, expectedCount int, oldAnno
 func settingsFn(maxAge time.Duration, maxCount int64) setting.AnnotationCleanupSettings {
 	return setting.AnnotationCleanupSettings{MaxAge: maxAge, MaxCount: maxCount}
 }
+
+func TestRetentionTiers(t *testing.T) {
+	cfg := &setting.Cfg{
+		AlertingAnnotationCleanupSetting:   setting.AnnotationCleanupSettings{MaxAge: time.Hour},
+		DashboardAnnotationCleanupSettings: setting.AnnotationCleanupSettings{MaxCount: 10},
+		APIAnnotationCleanupSettings:       setting.AnnotationCleanupSettings{MaxAge: 2 * time.Hour},
+		AnnotationRetentionPolicies: []setting.AnnotationRetentionPolicy{
+			{Name: "a", OrgID: 2, Type: setting.AnnotationTypeAlert, AnnotationCleanupSettings: setting.AnnotationCleanupSettings{MaxAge: 24 * time.Hour}},
+			{Name: "b", OrgID: 3, Type: setting.AnnotationTypeAlert, AnnotationCleanupSettings: setting.AnnotationCleanupSettings{MaxCount: 5}},
+		},
+	}
+
+	require.Equal(t, []retentionTier{
+		{settings: cfg.AnnotationRetentionPolicies[0].AnnotationCleanupSettings, condition: alertAnnotationType + " AND org_id = 2"},
+		{settings: cfg.AnnotationRetentionPolicies[1].AnnotationCleanupSettings, condition: alertAnnotationType + " AND org_id = 3"},
+		{settings: cfg.AlertingAnnotationCleanupSetting, condition: alertAnnotationType + " AND org_id NOT IN (2, 3)"},
+		{settings: cfg.APIAnnotationCleanupSettings, condition: apiAnnotationType},
+		{settings: cfg.DashboardAnnotationCleanupSettings, condition: dashboardAnnotationType},
+	}, retentionTiers(cfg))
+
+	// the dashboard annotations and the second policy are kept regardless of their age
+	_, ok := longestRetention(cfg)
+	require.False(t, ok)
+
+	cfg.DashboardAnnotationCleanupSettings.MaxAge = 3 * time.Hour
+	cfg.AnnotationRetentionPolicies = cfg.AnnotationRetentionPolicies[:1]
+	maxAge, ok := longestRetention(cfg)
+	require.True(t, ok)
+	require.Equal(t, 24*time.Hour, maxAge)
+}
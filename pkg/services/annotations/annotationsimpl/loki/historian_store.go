@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"time"
 
@@ -81,7 +82,8 @@ func (r *LokiHistorianStore) Type() string {
 }
 
 func (r *LokiHistorianStore) Get(ctx context.Context, query annotations.ItemQuery, accessResources *accesscontrol.AccessResources) ([]*annotations.ItemDTO, error) {
-	if query.Type == "annotation" {
+	// the alert state history has no regions
+	if query.Type == "annotation" || query.Type == "region" {
 		return make([]*annotations.ItemDTO, 0), nil
 	}
 
@@ -140,6 +142,11 @@ func (r *LokiHistorianStore) Get(ctx context.Context, query annotations.ItemQuer
 			items = append(items, r.annotationsFromStream(stream, *accessResources)...)
 		}
 	}
+	if len(query.Scopes) > 0 {
+		items = slices.DeleteFunc(items, func(item *annotations.ItemDTO) bool {
+			return !annotations.InScopes(query.Scopes, *item.DashboardUID, item.PanelID)
+		})
+	}
 	sort.Sort(annotations.SortedItems(items))
 	return items, err
 }
@@ -0,0 +1,401 @@
+package annotationsimpl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// partitionPrefix starts the names of the partitions, which are followed by the dates of the range
+	// of the partition, eg: p20240501_20240601. The partition holding the annotations created before the
+	// table was partitioned starts at partitionLegacyFrom.
+	partitionPrefix     = "p"
+	partitionLegacyFrom = "00000000"
+	partitionDateLayout = "20060102"
+	// partitionMax holds the annotations created after the last partition on MySQL
+	partitionMax = "pmax"
+)
+
+// partition of the annotation table holding the annotations created in [from, to), from is zero for the
+// partition holding the annotations created before the table was partitioned
+type partition struct {
+	name     string
+	from, to time.Time
+}
+
+func newPartition(from, to time.Time) partition {
+	name := partitionPrefix + partitionLegacyFrom + "_" + to.Format(partitionDateLayout)
+	if !from.IsZero() {
+		name = partitionPrefix + from.Format(partitionDateLayout) + "_" + to.Format(partitionDateLayout)
+	}
+	return partition{name: name, from: from, to: to}
+}
+
+// parsePartition parses the name of a partition, the partitions which aren't named by Grafana are ignored
+func parsePartition(name string) (partition, bool) {
+	from, to, ok := strings.Cut(strings.TrimPrefix(name, partitionPrefix), "_")
+	if !ok || !strings.HasPrefix(name, partitionPrefix) {
+		return partition{}, false
+	}
+	end, err := time.Parse(partitionDateLayout, to)
+	if err != nil {
+		return partition{}, false
+	}
+	if from == partitionLegacyFrom {
+		return partition{name: name, to: end}, true
+	}
+	start, err := time.Parse(partitionDateLayout, from)
+	if err != nil || !start.Before(end) {
+		return partition{}, false
+	}
+	return partition{name: name, from: start, to: end}, true
+}
+
+// partitionStart returns the start of the partition holding the annotations created at t
+func partitionStart(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case setting.AnnotationPartitionDay:
+		return day
+	case setting.AnnotationPartitionWeek:
+		// the weeks start on Monday
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	default:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+func nextPartitionStart(start time.Time, interval string) time.Time {
+	switch interval {
+	case setting.AnnotationPartitionDay:
+		return start.AddDate(0, 0, 1)
+	case setting.AnnotationPartitionWeek:
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 1, 0)
+	}
+}
+
+// partitionDialect runs the statements partitioning the annotation table by the created column
+type partitionDialect interface {
+	// partitioned reports whether the annotation table is partitioned
+	partitioned(ctx context.Context, sess *db.Session) (bool, error)
+	// convert partitions the annotation table, its annotations are kept in the legacy partition
+	convert(ctx context.Context, sess *db.Session, legacy partition) error
+	list(ctx context.Context, sess *db.Session) ([]string, error)
+	create(ctx context.Context, sess *db.Session, p partition) error
+	count(ctx context.Context, sess *db.Session, p partition) (int64, error)
+	drop(ctx context.Context, sess *db.Session, p partition) error
+}
+
+// partitionManager partitions the annotation table by creation time, so that the annotations older than
+// the longest retention are removed by dropping their partitions instead of deleting their rows
+type partitionManager struct {
+	db       db.DB
+	dialect  partitionDialect
+	interval string
+	ahead    int
+	log      log.Logger
+}
+
+// newPartitionManager returns nil when the database doesn't support partitioning, ie: SQLite, whose
+// annotations are cleaned by batched deletes
+func newPartitionManager(database db.DB, cfg setting.AnnotationPartitioningSettings, l log.Logger) *partitionManager {
+	var dialect partitionDialect
+	switch database.GetDBType() {
+	case migrator.Postgres:
+		dialect = postgresPartitions{}
+	case migrator.MySQL:
+		dialect = mysqlPartitions{}
+	default:
+		return nil
+	}
+	return &partitionManager{db: database, dialect: dialect, interval: cfg.Interval, ahead: cfg.Ahead, log: l}
+}
+
+// ensure partitions the annotation table if it isn't, and creates the partitions of the next intervals.
+// It returns the number of partitions.
+func (m *partitionManager) ensure(ctx context.Context, now time.Time) (int, error) {
+	var count int
+	err := m.db.WithDbSession(ctx, func(sess *db.Session) error {
+		partitioned, err := m.dialect.partitioned(ctx, sess)
+		if err != nil {
+			return err
+		}
+		if !partitioned {
+			if err := m.convert(ctx, sess, now); err != nil {
+				return fmt.Errorf("failed to partition the annotation table: %w", err)
+			}
+		}
+
+		partitions, err := m.list(ctx, sess)
+		if err != nil {
+			return err
+		}
+		last := partitionStart(now, m.interval)
+		if len(partitions) > 0 {
+			last = partitions[len(partitions)-1].to
+		}
+
+		horizon := partitionStart(now, m.interval)
+		for i := 0; i < m.ahead; i++ {
+			horizon = nextPartitionStart(horizon, m.interval)
+		}
+		for from := last; from.Before(horizon); {
+			to := nextPartitionStart(partitionStart(from, m.interval), m.interval)
+			p := newPartition(from, to)
+			if err := m.dialect.create(ctx, sess, p); err != nil {
+				return fmt.Errorf("failed to create annotation partition %s: %w", p.name, err)
+			}
+			m.log.Info("Created annotation partition", "partition", p.name)
+			partitions = append(partitions, p)
+			from = to
+		}
+
+		count = len(partitions)
+		return nil
+	})
+	return count, err
+}
+
+func (m *partitionManager) convert(ctx context.Context, sess *db.Session, now time.Time) error {
+	// the legacy partition holds all the existing annotations, including the ones created in the future
+	var maxCreated int64
+	if _, err := sess.SQL("SELECT COALESCE(MAX(created), 0) FROM annotation").Get(&maxCreated); err != nil {
+		return err
+	}
+	latest := now
+	if created := time.UnixMilli(maxCreated); created.After(latest) {
+		latest = created
+	}
+	legacy := newPartition(time.Time{}, nextPartitionStart(partitionStart(latest, m.interval), m.interval))
+
+	m.log.Info("Partitioning the annotation table", "legacyPartition", legacy.name)
+	start := time.Now()
+	if err := m.dialect.convert(ctx, sess, legacy); err != nil {
+		return err
+	}
+	m.log.Info("Partitioned the annotation table", "duration", time.Since(start))
+	return nil
+}
+
+// list returns the partitions named by Grafana, sorted by their start
+func (m *partitionManager) list(ctx context.Context, sess *db.Session) ([]partition, error) {
+	names, err := m.dialect.list(ctx, sess)
+	if err != nil {
+		return nil, err
+	}
+	partitions := make([]partition, 0, len(names))
+	for _, name := range names {
+		if p, ok := parsePartition(name); ok {
+			partitions = append(partitions, p)
+		}
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].to.Before(partitions[j].to) })
+	return partitions, nil
+}
+
+// dropBefore drops the partitions holding only annotations created before the cutoff. It returns the
+// number of dropped partitions and of the annotations they held.
+func (m *partitionManager) dropBefore(ctx context.Context, cutoff time.Time) (int, int64, error) {
+	var dropped int
+	var deleted int64
+	err := m.db.WithDbSession(ctx, func(sess *db.Session) error {
+		partitions, err := m.list(ctx, sess)
+		if err != nil {
+			return err
+		}
+		for _, p := range partitions {
+			if p.to.After(cutoff) {
+				break
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			rows, err := m.dialect.count(ctx, sess, p)
+			if err != nil {
+				return err
+			}
+			if err := m.dialect.drop(ctx, sess, p); err != nil {
+				return fmt.Errorf("failed to drop annotation partition %s: %w", p.name, err)
+			}
+			m.log.Info("Dropped annotation partition", "partition", p.name, "annotations", rows)
+			dropped++
+			deleted += rows
+		}
+		return nil
+	})
+	return dropped, deleted, err
+}
+
+// postgresPartitions partitions the annotation table with the declarative partitioning of Postgres, each
+// partition is a table named annotation_<partition>
+type postgresPartitions struct{}
+
+func (postgresPartitions) table(p partition) string {
+	return "annotation_" + p.name
+}
+
+func (postgresPartitions) partitioned(ctx context.Context, sess *db.Session) (bool, error) {
+	var count int64
+	_, err := sess.SQL(`SELECT COUNT(*) FROM pg_partitioned_table pt
+		JOIN pg_class c ON c.oid = pt.partrelid
+		WHERE c.relname = 'annotation' AND pg_table_is_visible(c.oid)`).Get(&count)
+	return count > 0, err
+}
+
+// convert renames the annotation table to the legacy partition, and attaches it to a new partitioned
+// annotation table. The rows aren't copied, but the legacy partition is scanned to check its range, and
+// indexed for the primary key which must include the partition column.
+func (pp postgresPartitions) convert(ctx context.Context, sess *db.Session, legacy partition) error {
+	legacyTable := pp.table(legacy)
+
+	var sequence string
+	if _, err := sess.SQL("SELECT COALESCE(pg_get_serial_sequence('annotation', 'id'), '')").Get(&sequence); err != nil {
+		return err
+	}
+	type index struct {
+		Name       string `xorm:"indexname"`
+		Definition string `xorm:"indexdef"`
+	}
+	var indexes []index
+	if err := sess.SQL(`SELECT indexname, indexdef FROM pg_indexes
+		WHERE tablename = 'annotation' AND schemaname = current_schema() AND indexdef NOT LIKE 'CREATE UNIQUE%'`).Find(&indexes); err != nil {
+		return err
+	}
+
+	statements := []string{
+		"LOCK TABLE annotation IN ACCESS EXCLUSIVE MODE",
+		"UPDATE annotation SET created = 0 WHERE created IS NULL",
+		fmt.Sprintf(`ALTER TABLE annotation RENAME TO %q`, legacyTable),
+		fmt.Sprintf(`CREATE TABLE annotation (LIKE %q INCLUDING DEFAULTS) PARTITION BY RANGE (created)`, legacyTable),
+		fmt.Sprintf(`ALTER TABLE %q ALTER COLUMN created SET NOT NULL`, legacyTable),
+		"ALTER TABLE annotation ALTER COLUMN created SET NOT NULL",
+		"ALTER TABLE annotation ADD PRIMARY KEY (id, created)",
+	}
+	if sequence != "" {
+		statements = append(statements, fmt.Sprintf("ALTER SEQUENCE %s OWNED BY annotation.id", sequence))
+	}
+	// the indexes of the legacy partition are renamed, and their definitions now target the partitioned
+	// table, the legacy indexes are attached to them with the partition
+	for _, idx := range indexes {
+		statements = append(statements,
+			fmt.Sprintf(`ALTER INDEX %q RENAME TO %q`, idx.Name, legacyTable+"_"+idx.Name),
+			idx.Definition,
+		)
+	}
+	statements = append(statements,
+		fmt.Sprintf(`ALTER TABLE annotation ATTACH PARTITION %q FOR VALUES FROM (MINVALUE) TO (%d)`, legacyTable, legacy.to.UnixMilli()),
+		`CREATE TABLE annotation_pdefault PARTITION OF annotation DEFAULT`,
+	)
+
+	return inTransaction(sess, statements)
+}
+
+func (pp postgresPartitions) list(ctx context.Context, sess *db.Session) ([]string, error) {
+	var tables []string
+	err := sess.SQL(`SELECT c.relname FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class p ON p.oid = i.inhparent
+		WHERE p.relname = 'annotation' AND pg_table_is_visible(p.oid)`).Find(&tables)
+	names := make([]string, 0, len(tables))
+	for _, table := range tables {
+		if name, ok := strings.CutPrefix(table, "annotation_"); ok {
+			names = append(names, name)
+		}
+	}
+	return names, err
+}
+
+func (pp postgresPartitions) create(ctx context.Context, sess *db.Session, p partition) error {
+	_, err := sess.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %q PARTITION OF annotation FOR VALUES FROM (%d) TO (%d)`,
+		pp.table(p), p.from.UnixMilli(), p.to.UnixMilli()))
+	return err
+}
+
+func (pp postgresPartitions) count(ctx context.Context, sess *db.Session, p partition) (int64, error) {
+	var count int64
+	_, err := sess.SQL(fmt.Sprintf(`SELECT COUNT(*) FROM %q`, pp.table(p))).Get(&count)
+	return count, err
+}
+
+func (pp postgresPartitions) drop(ctx context.Context, sess *db.Session, p partition) error {
+	_, err := sess.Exec(fmt.Sprintf(`DROP TABLE IF EXISTS %q`, pp.table(p)))
+	return err
+}
+
+// mysqlPartitions partitions the annotation table with the range partitioning of MySQL, the annotations
+// created after the last partition are held by the pmax partition
+type mysqlPartitions struct{}
+
+func (mysqlPartitions) partitioned(ctx context.Context, sess *db.Session) (bool, error) {
+	var count int64
+	_, err := sess.SQL(`SELECT COUNT(*) FROM information_schema.PARTITIONS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'annotation' AND PARTITION_NAME IS NOT NULL`).Get(&count)
+	return count > 0, err
+}
+
+// convert rebuilds the annotation table with its partitions, the table is locked while it's rebuilt
+func (mysqlPartitions) convert(ctx context.Context, sess *db.Session, legacy partition) error {
+	// MySQL commits the statements changing the schema, they can't run in a transaction
+	for _, statement := range []string{
+		"UPDATE annotation SET created = 0 WHERE created IS NULL",
+		"ALTER TABLE annotation MODIFY created BIGINT NOT NULL DEFAULT 0, DROP PRIMARY KEY, ADD PRIMARY KEY (id, created)",
+		fmt.Sprintf("ALTER TABLE annotation PARTITION BY RANGE (created) (PARTITION %s VALUES LESS THAN (%d), PARTITION %s VALUES LESS THAN MAXVALUE)",
+			legacy.name, legacy.to.UnixMilli(), partitionMax),
+	} {
+		if _, err := sess.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (mysqlPartitions) list(ctx context.Context, sess *db.Session) ([]string, error) {
+	var names []string
+	err := sess.SQL(`SELECT PARTITION_NAME FROM information_schema.PARTITIONS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'annotation' AND PARTITION_NAME IS NOT NULL`).Find(&names)
+	return names, err
+}
+
+// create splits the pmax partition, which holds no annotations as long as the partitions are created in advance
+func (mysqlPartitions) create(ctx context.Context, sess *db.Session, p partition) error {
+	_, err := sess.Exec(fmt.Sprintf("ALTER TABLE annotation REORGANIZE PARTITION %s INTO (PARTITION %s VALUES LESS THAN (%d), PARTITION %s VALUES LESS THAN MAXVALUE)",
+		partitionMax, p.name, p.to.UnixMilli(), partitionMax))
+	return err
+}
+
+func (mysqlPartitions) count(ctx context.Context, sess *db.Session, p partition) (int64, error) {
+	var count int64
+	_, err := sess.SQL(fmt.Sprintf("SELECT COUNT(*) FROM annotation PARTITION (%s)", p.name)).Get(&count)
+	return count, err
+}
+
+func (mysqlPartitions) drop(ctx context.Context, sess *db.Session, p partition) error {
+	_, err := sess.Exec(fmt.Sprintf("ALTER TABLE annotation DROP PARTITION %s", p.name))
+	return err
+}
+
+func inTransaction(sess *db.Session, statements []string) error {
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	for _, statement := range statements {
+		if _, err := sess.Exec(statement); err != nil {
+			if rollbackErr := sess.Rollback(); rollbackErr != nil {
+				return fmt.Errorf("%w, rollback failed: %s", err, rollbackErr)
+			}
+			return err
+		}
+	}
+	return sess.Commit()
+}
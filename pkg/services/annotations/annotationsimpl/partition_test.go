@@ -0,0 +1,57 @@
+package annotationsimpl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestPartitionStart(t *testing.T) {
+	// a Thursday
+	at := time.Date(2024, 5, 16, 13, 45, 0, 0, time.FixedZone("CEST", 2*3600))
+
+	tests := []struct {
+		interval string
+		start    time.Time
+		next     time.Time
+	}{
+		{setting.AnnotationPartitionDay, time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 17, 0, 0, 0, 0, time.UTC)},
+		{setting.AnnotationPartitionWeek, time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)},
+		{setting.AnnotationPartitionMonth, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		t.Run(test.interval, func(t *testing.T) {
+			start := partitionStart(at, test.interval)
+			require.Equal(t, test.start, start)
+			require.Equal(t, test.next, nextPartitionStart(start, test.interval))
+		})
+	}
+
+	// a Sunday belongs to the week started on the previous Monday
+	require.Equal(t, time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC), partitionStart(time.Date(2024, 5, 19, 23, 0, 0, 0, time.UTC), setting.AnnotationPartitionWeek))
+}
+
+func TestParsePartition(t *testing.T) {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	p := newPartition(from, to)
+	require.Equal(t, "p20240501_20240601", p.name)
+	parsed, ok := parsePartition(p.name)
+	require.True(t, ok)
+	require.Equal(t, p, parsed)
+
+	legacy := newPartition(time.Time{}, to)
+	require.Equal(t, "p00000000_20240601", legacy.name)
+	parsed, ok = parsePartition(legacy.name)
+	require.True(t, ok)
+	require.Equal(t, legacy, parsed)
+
+	for _, name := range []string{partitionMax, "pdefault", "p20240601_20240501", "p2024_20240601", "x20240501_20240601"} {
+		_, ok := parsePartition(name)
+		require.False(t, ok, name)
+	}
+}
@@ -3,7 +3,6 @@ package annotationsimpl
 import (
 	"bytes"
 	"context"
-	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -19,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/tag"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 // Update the item so that EpochEnd >= Epoch
@@ -43,6 +43,8 @@ type xormRepositoryImpl struct {
 	db         db.DB
 	log        log.Logger
 	tagService tag.Service
+	// cleanupMetrics counts the rows deleted by each batch of the cleanup, it's nil unless the store cleans annotations
+	cleanupMetrics *cleanupMetrics
 }
 
 func NewXormStore(cfg *setting.Cfg, l log.Logger, db db.DB, tagService tag.Service) *xormRepositoryImpl {
@@ -160,7 +162,7 @@ func (r *xormRepositoryImpl) update(ctx context.Context, item *annotations.Item)
 			return err
 		}
 		if !isExist {
-			return errors.New("annotation not found")
+			return annotations.ErrAnnotationNotFound.Errorf("annotation %d not found", item.ID)
 		}
 
 		existing.Updated = timeNow().UnixNano() / int64(time.Millisecond)
@@ -256,7 +258,7 @@ func (r *xormRepositoryImpl) Get(ctx context.Context, query annotations.ItemQuer
 	var sql bytes.Buffer
 	params := make([]interface{}, 0)
 	items := make([]*annotations.ItemDTO, 0)
-	err := r.db.WithDbSession(ctx, func(sess *db.Session) error {
+	err := r.db.WithReadReplicaSession(ctx, func(sess *db.Session) error {
 		sql.WriteString(`
 			SELECT
 				annotation.id,
@@ -317,6 +319,20 @@ func (r *xormRepositoryImpl) Get(ctx context.Context, query annotations.ItemQuer
 			params = append(params, query.PanelID)
 		}
 
+		if len(query.Scopes) > 0 {
+			scopeFilters := make([]string, 0, len(query.Scopes))
+			for _, scope := range query.Scopes {
+				if scope.PanelID != 0 {
+					scopeFilters = append(scopeFilters, "(a.dashboard_uid = ? AND a.panel_id = ?)")
+					params = append(params, scope.DashboardUID, scope.PanelID)
+				} else {
+					scopeFilters = append(scopeFilters, "a.dashboard_uid = ?")
+					params = append(params, scope.DashboardUID)
+				}
+			}
+			sql.WriteString(" AND (" + strings.Join(scopeFilters, " OR ") + ")")
+		}
+
 		if query.UserID != 0 {
 			sql.WriteString(` AND a.user_id = ?`)
 			params = append(params, query.UserID)
@@ -332,6 +348,8 @@ func (r *xormRepositoryImpl) Get(ctx context.Context, query annotations.ItemQuer
 			sql.WriteString(` AND a.alert_id > 0`)
 		case "annotation":
 			sql.WriteString(` AND a.alert_id = 0`)
+		case "region":
+			sql.WriteString(` AND a.epoch_end > a.epoch`)
 		}
 
 		if len(query.Tags) > 0 {
@@ -372,8 +390,17 @@ func (r *xormRepositoryImpl) Get(ctx context.Context, query annotations.ItemQuer
 		}
 		params = append(params, acParams...)
 
+		if query.After != nil {
+			where, args, err := pagination.Where(annotations.SortKeys, query.After)
+			if err != nil {
+				return err
+			}
+			sql.WriteString(" AND " + where)
+			params = append(params, args...)
+		}
+
 		// order of ORDER BY arguments match the order of a sql index for performance
-		orderBy := " ORDER BY a.org_id, a.epoch_end DESC, a.epoch DESC"
+		orderBy := " ORDER BY a.org_id, " + pagination.OrderBy(annotations.SortKeys)
 		if query.Limit > 0 {
 			orderBy += r.db.GetDialect().Limit(query.Limit)
 		}
@@ -467,7 +494,7 @@ func (r *xormRepositoryImpl) Delete(ctx context.Context, params *annotations.Del
 
 func (r *xormRepositoryImpl) GetTags(ctx context.Context, query annotations.TagsQuery) (annotations.FindTagsResult, error) {
 	var items []*annotations.Tag
-	err := r.db.WithDbSession(ctx, func(dbSession *db.Session) error {
+	err := r.db.WithReadReplicaSession(ctx, func(dbSession *db.Session) error {
 		if query.Limit == 0 {
 			query.Limit = 100
 		}
@@ -562,14 +589,14 @@ func (r *xormRepositoryImpl) CleanAnnotations(ctx context.Context, cfg setting.A
 		// This may under-delete when concurrent inserts happen, but any such annotations will simply be cleaned on the next cycle.
 		//
 		// We execute the following batched operation repeatedly until either we run out of objects, the context is cancelled, or there is an error.
-		affected, err := untilDoneOrCancelled(ctx, func() (int64, error) {
+		affected, err := untilDoneOrCancelled(ctx, r.cfg.AnnotationCleanupJobBatchPause, func() (int64, error) {
 			cond := fmt.Sprintf(`%s AND created < %v ORDER BY id DESC %s`, annotationType, cutoffDate, r.db.GetDialect().Limit(r.cfg.AnnotationCleanupJobBatchSize))
 			ids, err := r.fetchIDs(ctx, "annotation", cond)
 			if err != nil {
 				return 0, err
 			}
 
-			return r.deleteByIDs(ctx, "annotation", ids)
+			return r.deleteBatch(ctx, "annotation", ids)
 		})
 		totalAffected += affected
 		if err != nil {
@@ -579,14 +606,14 @@ func (r *xormRepositoryImpl) CleanAnnotations(ctx context.Context, cfg setting.A
 
 	if cfg.MaxCount > 0 {
 		// Similar strategy as the above cleanup process, to avoid deadlocks.
-		affected, err := untilDoneOrCancelled(ctx, func() (int64, error) {
+		affected, err := untilDoneOrCancelled(ctx, r.cfg.AnnotationCleanupJobBatchPause, func() (int64, error) {
 			cond := fmt.Sprintf(`%s ORDER BY id DESC %s`, annotationType, r.db.GetDialect().LimitOffset(r.cfg.AnnotationCleanupJobBatchSize, cfg.MaxCount))
 			ids, err := r.fetchIDs(ctx, "annotation", cond)
 			if err != nil {
 				return 0, err
 			}
 
-			return r.deleteByIDs(ctx, "annotation", ids)
+			return r.deleteBatch(ctx, "annotation", ids)
 		})
 		totalAffected += affected
 		if err != nil {
@@ -598,17 +625,24 @@ func (r *xormRepositoryImpl) CleanAnnotations(ctx context.Context, cfg setting.A
 }
 
 func (r *xormRepositoryImpl) CleanOrphanedAnnotationTags(ctx context.Context) (int64, error) {
-	return untilDoneOrCancelled(ctx, func() (int64, error) {
+	return untilDoneOrCancelled(ctx, r.cfg.AnnotationCleanupJobBatchPause, func() (int64, error) {
 		cond := fmt.Sprintf(`NOT EXISTS (SELECT 1 FROM annotation a WHERE annotation_id = a.id) %s`, r.db.GetDialect().Limit(r.cfg.AnnotationCleanupJobBatchSize))
 		ids, err := r.fetchIDs(ctx, "annotation_tag", cond)
 		if err != nil {
 			return 0, err
 		}
 
-		return r.deleteByIDs(ctx, "annotation_tag", ids)
+		return r.deleteBatch(ctx, "annotation_tag", ids)
 	})
 }
 
+// deleteBatch deletes a batch of the cleanup, and reports its progress
+func (r *xormRepositoryImpl) deleteBatch(ctx context.Context, table string, ids []int64) (int64, error) {
+	affected, err := r.deleteByIDs(ctx, table, ids)
+	r.cleanupMetrics.observeBatch(table, affected)
+	return affected, err
+}
+
 func (r *xormRepositoryImpl) fetchIDs(ctx context.Context, table, condition string) ([]int64, error) {
 	sql := fmt.Sprintf(`SELECT id FROM %s`, table)
 	if condition == "" {
@@ -668,7 +702,8 @@ func asAny(vs []int64) []any {
 // untilDoneOrCancelled repeatedly executes batched work until that work is either done (i.e., returns zero affected objects),
 // a batch produces an error, or the provided context is cancelled.
 // The work to be done is given as a callback that returns the number of affected objects for each batch, plus that batch's errors.
-func untilDoneOrCancelled(ctx context.Context, batchWork func() (int64, error)) (int64, error) {
+// The batches are separated by the pause, which lets the concurrent writes acquire the locks released by each batch.
+func untilDoneOrCancelled(ctx context.Context, pause time.Duration, batchWork func() (int64, error)) (int64, error) {
 	var totalAffected int64
 	for {
 		select {
@@ -685,6 +720,14 @@ func untilDoneOrCancelled(ctx context.Context, batchWork func() (int64, error))
 				return totalAffected, nil
 			}
 		}
+
+		if pause > 0 {
+			select {
+			case <-ctx.Done():
+				return totalAffected, ctx.Err()
+			case <-time.After(pause):
+			}
+		}
 	}
 }
 
@@ -187,6 +187,51 @@ func TestIntegrationAnnotations(t *testing.T) {
 			assert.Equal(t, items[0].Updated, items[0].Created)
 		})
 
+		t.Run("Can query for annotations by dashboard and panel scopes", func(t *testing.T) {
+			accRes := &annotation_ac.AccessResources{
+				Dashboards:               map[string]int64{dashboard.UID: dashboard.ID, dashboard2.UID: dashboard2.ID},
+				CanAccessDashAnnotations: true,
+			}
+			items, err := store.Get(context.Background(), annotations.ItemQuery{
+				OrgID:        1,
+				Scopes:       []annotations.Scope{{DashboardUID: dashboard.UID}, {DashboardUID: dashboard2.UID}},
+				From:         0,
+				To:           25,
+				SignedInUser: testUser,
+			}, accRes)
+			require.NoError(t, err)
+			assert.Len(t, items, 2)
+
+			items, err = store.Get(context.Background(), annotations.ItemQuery{
+				OrgID:        1,
+				Scopes:       []annotations.Scope{{DashboardUID: dashboard.UID}, {DashboardUID: dashboard2.UID, PanelID: 3}},
+				From:         0,
+				To:           25,
+				SignedInUser: testUser,
+			}, accRes)
+			require.NoError(t, err)
+			require.Len(t, items, 1)
+			assert.Equal(t, annotation.ID, items[0].ID)
+		})
+
+		t.Run("Can query for region annotations", func(t *testing.T) {
+			items, err := store.Get(context.Background(), annotations.ItemQuery{
+				OrgID:        1,
+				From:         0,
+				To:           25,
+				Type:         "region",
+				SignedInUser: testUser,
+			}, &annotation_ac.AccessResources{
+				Dashboards:               map[string]int64{dashboard.UID: dashboard.ID, dashboard2.UID: dashboard2.ID},
+				CanAccessDashAnnotations: true,
+				CanAccessOrgAnnotations:  true,
+			})
+			require.NoError(t, err)
+			require.Len(t, items, 1)
+			assert.Equal(t, annotation2.ID, items[0].ID)
+			assert.Equal(t, int64(21), items[0].TimeEnd)
+		})
+
 		badAnnotation := &annotations.Item{
 			OrgID:  1,
 			UserID: 1,
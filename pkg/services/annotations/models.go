@@ -3,6 +3,7 @@ package annotations
 import (
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 type ItemQuery struct {
@@ -22,8 +23,40 @@ type ItemQuery struct {
 	MatchAny     bool     `json:"matchAny"`
 	SignedInUser identity.Requester
 
+	// Scopes select the annotations of any of the dashboards or panels
+	Scopes []Scope `json:"scopes"`
+
 	Limit int64 `json:"limit"`
 	Page  int64
+	// After selects the annotations sorted after the cursor, see SortKeys
+	After *pagination.Cursor `json:"-"`
+}
+
+// Scope is a dashboard, or a panel of a dashboard when the panel ID isn't 0
+type Scope struct {
+	DashboardUID string `json:"dashboardUID"`
+	PanelID      int64  `json:"panelId"`
+}
+
+// InScopes returns true when there are no scopes or when the annotation of the dashboard and panel is in one of them
+func InScopes(scopes []Scope, dashboardUID string, panelID int64) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	for _, scope := range scopes {
+		if scope.DashboardUID == dashboardUID && (scope.PanelID == 0 || scope.PanelID == panelID) {
+			return true
+		}
+	}
+	return false
+}
+
+// SortKeys are the keys the annotations are sorted by, the most recent first
+var SortKeys = []pagination.Key{{Column: "a.epoch_end", Desc: true}, {Column: "a.epoch", Desc: true}, {Column: "a.id", Desc: true}}
+
+// SortValues returns the values of the sort keys of the annotation
+func (i *ItemDTO) SortValues() []any {
+	return []any{i.TimeEnd, i.Time, i.ID}
 }
 
 // TagsQuery is the query for a tags search.
@@ -144,7 +177,10 @@ func (s SortedItems) Less(i, j int) bool {
 	if s[i].TimeEnd != s[j].TimeEnd {
 		return s[i].TimeEnd > s[j].TimeEnd
 	}
-	return s[i].Time > s[j].Time
+	if s[i].Time != s[j].Time {
+		return s[i].Time > s[j].Time
+	}
+	return s[i].ID > s[j].ID
 }
 
 func (s SortedItems) Swap(i, j int) {
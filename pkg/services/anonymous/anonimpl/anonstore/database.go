@@ -3,6 +3,7 @@ package anonstore
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -27,6 +28,7 @@ type AnonDBStore struct {
 
 type Device struct {
 	ID        int64     `json:"-" xorm:"pk autoincr 'id'" db:"id"`
+	OrgID     int64     `json:"orgId" xorm:"org_id" db:"org_id"`
 	DeviceID  string    `json:"deviceId" xorm:"device_id" db:"device_id"`
 	ClientIP  string    `json:"clientIp" xorm:"client_ip" db:"client_ip"`
 	UserAgent string    `json:"userAgent" xorm:"user_agent" db:"user_agent"`
@@ -59,7 +61,7 @@ type SearchDeviceQuery struct {
 }
 
 func (a *Device) CacheKey() string {
-	return strings.Join([]string{cacheKeyPrefix, a.DeviceID}, ":")
+	return strings.Join([]string{cacheKeyPrefix, strconv.FormatInt(a.OrgID, 10), a.DeviceID}, ":")
 }
 
 type AnonStore interface {
@@ -75,6 +77,15 @@ type AnonStore interface {
 	DeleteDevicesOlderThan(ctx context.Context, olderThan time.Time) error
 	// SearchDevices searches for devices within the 30 days active.
 	SearchDevices(ctx context.Context, query *SearchDeviceQuery) (*SearchDeviceQueryResult, error)
+	// ListOrgDevices returns the devices of an org that have been updated between the given times.
+	ListOrgDevices(ctx context.Context, orgID int64, from time.Time, to time.Time) ([]*Device, error)
+	// CountOrgDevices returns the number of devices of an org that have been updated between the given times.
+	CountOrgDevices(ctx context.Context, orgID int64, from time.Time, to time.Time) (int64, error)
+	// UpdateOrgDevice updates a device of the org, it returns ErrDeviceLimitReached if the device isn't in the org.
+	UpdateOrgDevice(ctx context.Context, device *Device) error
+	// GetOrgSettings returns nil when the org has no anonymous access settings.
+	GetOrgSettings(ctx context.Context, orgID int64) (*OrgSettings, error)
+	SaveOrgSettings(ctx context.Context, settings *OrgSettings) error
 }
 
 func ProvideAnonDBStore(sqlStore db.DB, deviceLimit int64) *AnonDBStore {
@@ -98,16 +109,22 @@ func (s *AnonDBStore) ListDevices(ctx context.Context, from *time.Time, to *time
 }
 
 // updateDevice updates a device if it exists and has been updated between the given times.
-func (s *AnonDBStore) updateDevice(ctx context.Context, device *Device) error {
-	const query = `UPDATE anon_device SET
+// When sameOrg is set, only devices already in the org of the device are updated.
+func (s *AnonDBStore) updateDevice(ctx context.Context, device *Device, sameOrg bool) error {
+	query := `UPDATE anon_device SET
+org_id = ?,
 client_ip = ?,
 user_agent = ?,
 updated_at = ?
 WHERE device_id = ? AND updated_at BETWEEN ? AND ?`
 
-	args := []interface{}{device.ClientIP, device.UserAgent, device.UpdatedAt.UTC(), device.DeviceID,
+	args := []interface{}{device.OrgID, device.ClientIP, device.UserAgent, device.UpdatedAt.UTC(), device.DeviceID,
 		device.UpdatedAt.UTC().Add(-anonymousDeviceExpiration), device.UpdatedAt.UTC().Add(time.Minute),
 	}
+	if sameOrg {
+		query += " AND org_id = ?"
+		args = append(args, device.OrgID)
+	}
 	err := s.sqlStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
 		args = append([]interface{}{query}, args...)
 		result, err := dbSession.Exec(args...)
@@ -141,7 +158,7 @@ func (s *AnonDBStore) CreateOrUpdateDevice(ctx context.Context, device *Device)
 		}
 
 		if count >= s.deviceLimit {
-			return s.updateDevice(ctx, device)
+			return s.updateDevice(ctx, device, false)
 		}
 	}
 
@@ -152,30 +169,33 @@ func (s *AnonDBStore) CreateOrUpdateDevice(ctx context.Context, device *Device)
 		created = time.Now()
 	}
 
-	args := []any{device.DeviceID, device.ClientIP, device.UserAgent, created.UTC(), device.UpdatedAt.UTC()}
+	args := []any{device.DeviceID, device.ClientIP, device.UserAgent, created.UTC(), device.UpdatedAt.UTC(), device.OrgID}
 	switch s.sqlStore.GetDBType() {
 	case migrator.Postgres:
-		query = `INSERT INTO anon_device (device_id, client_ip, user_agent, created_at, updated_at)
-					VALUES ($1, $2, $3, $4, $5)
+		query = `INSERT INTO anon_device (device_id, client_ip, user_agent, created_at, updated_at, org_id)
+					VALUES ($1, $2, $3, $4, $5, $6)
 					ON CONFLICT (device_id) DO UPDATE SET
 					client_ip = $2,
 					user_agent = $3,
-					updated_at = $5
+					updated_at = $5,
+					org_id = $6
 					RETURNING id`
 	case migrator.MySQL:
-		query = `INSERT INTO anon_device (device_id, client_ip, user_agent, created_at, updated_at)
-					VALUES (?, ?, ?, ?, ?)
+		query = `INSERT INTO anon_device (device_id, client_ip, user_agent, created_at, updated_at, org_id)
+					VALUES (?, ?, ?, ?, ?, ?)
 					ON DUPLICATE KEY UPDATE
 					client_ip = VALUES(client_ip),
 					user_agent = VALUES(user_agent),
-					updated_at = VALUES(updated_at)`
+					updated_at = VALUES(updated_at),
+					org_id = VALUES(org_id)`
 	case migrator.SQLite:
-		query = `INSERT INTO anon_device (device_id, client_ip, user_agent, created_at, updated_at)
-					VALUES (?, ?, ?, ?, ?)
+		query = `INSERT INTO anon_device (device_id, client_ip, user_agent, created_at, updated_at, org_id)
+					VALUES (?, ?, ?, ?, ?, ?)
 					ON CONFLICT (device_id) DO UPDATE SET
 					client_ip = excluded.client_ip,
 					user_agent = excluded.user_agent,
-					updated_at = excluded.updated_at`
+					updated_at = excluded.updated_at,
+					org_id = excluded.org_id`
 	default:
 		return fmt.Errorf("unsupported database driver: %s", s.sqlStore.GetDBType())
 	}
@@ -199,6 +219,30 @@ func (s *AnonDBStore) CountDevices(ctx context.Context, from time.Time, to time.
 	return count, err
 }
 
+func (s *AnonDBStore) ListOrgDevices(ctx context.Context, orgID int64, from time.Time, to time.Time) ([]*Device, error) {
+	devices := []*Device{}
+	err := s.sqlStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		return dbSession.SQL("SELECT * FROM anon_device WHERE org_id = ? AND updated_at BETWEEN ? AND ? ORDER BY updated_at DESC",
+			orgID, from.UTC(), to.UTC()).Find(&devices)
+	})
+
+	return devices, err
+}
+
+func (s *AnonDBStore) CountOrgDevices(ctx context.Context, orgID int64, from time.Time, to time.Time) (int64, error) {
+	var count int64
+	err := s.sqlStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		_, err := dbSession.SQL("SELECT COUNT(*) FROM anon_device WHERE org_id = ? AND updated_at BETWEEN ? AND ?", orgID, from.UTC(), to.UTC()).Get(&count)
+		return err
+	})
+
+	return count, err
+}
+
+func (s *AnonDBStore) UpdateOrgDevice(ctx context.Context, device *Device) error {
+	return s.updateDevice(ctx, device, true)
+}
+
 func (s *AnonDBStore) DeleteDevice(ctx context.Context, deviceID string) error {
 	err := s.sqlStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
 		_, err := dbSession.Exec("DELETE FROM anon_device WHERE device_id = ?", deviceID)
@@ -112,3 +112,61 @@ func TestIntegrationAnonStore_DeleteDevice(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 0, len(devices))
 }
+
+func TestIntegrationAnonStore_OrgSettings(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	store := db.InitTestDB(t)
+	anonDBStore := ProvideAnonDBStore(store, 0)
+	ctx := context.Background()
+
+	settings, err := anonDBStore.GetOrgSettings(ctx, 1)
+	require.NoError(t, err)
+	assert.Nil(t, settings)
+
+	err = anonDBStore.SaveOrgSettings(ctx, &OrgSettings{OrgID: 1, Enabled: true, DeviceLimit: 10, FolderUIDs: []string{"a"}})
+	require.NoError(t, err)
+	err = anonDBStore.SaveOrgSettings(ctx, &OrgSettings{OrgID: 1, Enabled: true, DeviceLimit: 20})
+	require.NoError(t, err)
+
+	settings, err = anonDBStore.GetOrgSettings(ctx, 1)
+	require.NoError(t, err)
+	require.NotNil(t, settings)
+	assert.True(t, settings.Enabled)
+	assert.Equal(t, int64(20), settings.DeviceLimit)
+	assert.Empty(t, settings.FolderUIDs)
+}
+
+func TestIntegrationAnonStore_OrgDevices(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	store := db.InitTestDB(t)
+	anonDBStore := ProvideAnonDBStore(store, 0)
+	ctx := context.Background()
+
+	for _, device := range []*Device{
+		{OrgID: 1, DeviceID: "a", UpdatedAt: time.Now()},
+		{OrgID: 1, DeviceID: "b", UpdatedAt: time.Now()},
+		{OrgID: 2, DeviceID: "c", UpdatedAt: time.Now()},
+	} {
+		require.NoError(t, anonDBStore.CreateOrUpdateDevice(ctx, device))
+	}
+
+	from, to := time.Now().Add(-time.Hour), time.Now().Add(time.Minute)
+	count, err := anonDBStore.CountOrgDevices(ctx, 1, from, to)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	devices, err := anonDBStore.ListOrgDevices(ctx, 2, from, to)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "c", devices[0].DeviceID)
+
+	// devices of other orgs aren't moved once the limit of the org is reached
+	err = anonDBStore.UpdateOrgDevice(ctx, &Device{OrgID: 2, DeviceID: "a", UpdatedAt: time.Now()})
+	assert.ErrorIs(t, err, ErrDeviceLimitReached)
+	err = anonDBStore.UpdateOrgDevice(ctx, &Device{OrgID: 2, DeviceID: "c", UpdatedAt: time.Now()})
+	require.NoError(t, err)
+}
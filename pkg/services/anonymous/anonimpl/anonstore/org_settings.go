@@ -0,0 +1,91 @@
+package anonstore
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// OrgSettings are the anonymous access settings of an org
+type OrgSettings struct {
+	OrgID int64 `json:"orgId"`
+	// Enabled allows anonymous users in the org, only the org configured in [auth.anonymous] is enabled by default
+	Enabled bool `json:"enabled"`
+	// DeviceLimit is the number of anonymous devices allowed in the org, 0 means no limit
+	DeviceLimit int64 `json:"deviceLimit"`
+	// FolderUIDs restrict anonymous users to the dashboards of these folders, empty allows every folder
+	FolderUIDs []string  `json:"folderUids"`
+	Updated    time.Time `json:"updated,omitempty"`
+}
+
+// anonOrgSettings is the stored form of the settings, the folder UIDs are encoded as JSON
+type anonOrgSettings struct {
+	ID          int64  `xorm:"pk autoincr 'id'"`
+	OrgID       int64  `xorm:"org_id"`
+	Enabled     bool   `xorm:"enabled"`
+	DeviceLimit int64  `xorm:"device_limit"`
+	FolderUIDs  string `xorm:"folder_uids"`
+	Updated     time.Time
+}
+
+func (anonOrgSettings) TableName() string {
+	return "anon_org_settings"
+}
+
+func (s *AnonDBStore) GetOrgSettings(ctx context.Context, orgID int64) (*OrgSettings, error) {
+	var settings *OrgSettings
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var stored anonOrgSettings
+		has, err := sess.Where("org_id = ?", orgID).Get(&stored)
+		if err != nil || !has {
+			return err
+		}
+
+		settings = &OrgSettings{
+			OrgID:       stored.OrgID,
+			Enabled:     stored.Enabled,
+			DeviceLimit: stored.DeviceLimit,
+			FolderUIDs:  []string{},
+			Updated:     stored.Updated,
+		}
+		if stored.FolderUIDs != "" {
+			return json.Unmarshal([]byte(stored.FolderUIDs), &settings.FolderUIDs)
+		}
+		return nil
+	})
+	return settings, err
+}
+
+func (s *AnonDBStore) SaveOrgSettings(ctx context.Context, settings *OrgSettings) error {
+	stored := anonOrgSettings{
+		OrgID:       settings.OrgID,
+		Enabled:     settings.Enabled,
+		DeviceLimit: settings.DeviceLimit,
+		Updated:     settings.Updated,
+	}
+	if len(settings.FolderUIDs) > 0 {
+		encoded, err := json.Marshal(settings.FolderUIDs)
+		if err != nil {
+			return err
+		}
+		stored.FolderUIDs = string(encoded)
+	}
+
+	return s.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing := &anonOrgSettings{}
+		has, err := sess.Where("org_id = ?", settings.OrgID).Get(existing)
+		if err != nil {
+			return err
+		}
+		if has {
+			stored.ID = existing.ID
+			_, err = sess.ID(stored.ID).AllCols().Update(&stored)
+			return err
+		}
+
+		_, err = sess.Insert(&stored)
+		return err
+	})
+}
@@ -9,11 +9,13 @@ import (
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/anonymous"
 	"github.com/grafana/grafana/pkg/services/anonymous/anonimpl/anonstore"
 	"github.com/grafana/grafana/pkg/services/anonymous/sortopts"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
 )
 
 const anonymousDeviceExpiration = 30 * 24 * time.Hour
@@ -27,6 +29,7 @@ type deviceDTO struct {
 type AnonDeviceServiceAPI struct {
 	cfg            *setting.Cfg
 	store          anonstore.AnonStore
+	service        anonymous.Service
 	accesscontrol  accesscontrol.AccessControl
 	RouterRegister routing.RouteRegister
 	log            log.Logger
@@ -35,12 +38,14 @@ type AnonDeviceServiceAPI struct {
 func NewAnonDeviceServiceAPI(
 	cfg *setting.Cfg,
 	anonstore anonstore.AnonStore,
+	service anonymous.Service,
 	accesscontrol accesscontrol.AccessControl,
 	routerRegister routing.RouteRegister,
 ) *AnonDeviceServiceAPI {
 	return &AnonDeviceServiceAPI{
 		cfg:            cfg,
 		store:          anonstore,
+		service:        service,
 		accesscontrol:  accesscontrol,
 		RouterRegister: routerRegister,
 		log:            log.New("anon.api"),
@@ -53,6 +58,11 @@ func (api *AnonDeviceServiceAPI) RegisterAPIEndpoints() {
 		anonRoutes.Get("/devices", auth(accesscontrol.EvalPermission(accesscontrol.ActionUsersRead)), routing.Wrap(api.ListDevices))
 		anonRoutes.Get("/search", auth(accesscontrol.EvalPermission(accesscontrol.ActionUsersRead)), routing.Wrap(api.SearchDevices))
 	})
+	api.RouterRegister.Group("/api/org/anonymous", func(orgRoutes routing.RouteRegister) {
+		orgRoutes.Get("/", auth(accesscontrol.EvalPermission(accesscontrol.ActionOrgsRead)), routing.Wrap(api.GetOrgSettings))
+		orgRoutes.Put("/", auth(accesscontrol.EvalPermission(accesscontrol.ActionOrgsWrite)), routing.Wrap(api.UpdateOrgSettings))
+		orgRoutes.Get("/devices", auth(accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersRead)), routing.Wrap(api.ListOrgDevices))
+	})
 }
 
 // swagger:route GET /anonymous/devices devices listDevices
@@ -137,6 +147,134 @@ func (api *AnonDeviceServiceAPI) SearchDevices(c *contextmodel.ReqContext) respo
 	return response.JSON(http.StatusOK, results)
 }
 
+// swagger:route GET /org/anonymous devices getOrgAnonymousSettings
+//
+// # Get the anonymous access settings of the current organization
+//
+// Produces:
+// - application/json
+//
+// Responses:
+//
+//	200: orgAnonymousSettingsResponse
+//	401: unauthorisedError
+//	403: forbiddenError
+//	500: internalServerError
+func (api *AnonDeviceServiceAPI) GetOrgSettings(c *contextmodel.ReqContext) response.Response {
+	settings, err := api.service.GetOrgSettings(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get anonymous access settings", err)
+	}
+	return response.JSON(http.StatusOK, settings)
+}
+
+// swagger:route PUT /org/anonymous devices updateOrgAnonymousSettings
+//
+// # Update the anonymous access settings of the current organization
+//
+// Anonymous access must be enabled in the configuration for the settings to apply.
+//
+// Produces:
+// - application/json
+//
+// Responses:
+//
+//	200: orgAnonymousSettingsResponse
+//	400: badRequestError
+//	401: unauthorisedError
+//	403: forbiddenError
+//	500: internalServerError
+func (api *AnonDeviceServiceAPI) UpdateOrgSettings(c *contextmodel.ReqContext) response.Response {
+	cmd := UpdateOrgSettingsCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	settings := &anonstore.OrgSettings{
+		OrgID:       c.SignedInUser.GetOrgID(),
+		Enabled:     cmd.Enabled,
+		DeviceLimit: cmd.DeviceLimit,
+		FolderUIDs:  cmd.FolderUIDs,
+	}
+	if err := api.service.SetOrgSettings(c.Req.Context(), settings); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update anonymous access settings", err)
+	}
+	return response.JSON(http.StatusOK, settings)
+}
+
+// swagger:route GET /org/anonymous/devices devices listOrgDevices
+//
+// # Lists the anonymous devices of the current organization within the last 30 days
+//
+// Produces:
+// - application/json
+//
+// Responses:
+//
+//	200: orgDevicesResponse
+//	401: unauthorisedError
+//	403: forbiddenError
+//	500: internalServerError
+func (api *AnonDeviceServiceAPI) ListOrgDevices(c *contextmodel.ReqContext) response.Response {
+	orgID := c.SignedInUser.GetOrgID()
+	settings, err := api.service.GetOrgSettings(c.Req.Context(), orgID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list devices", err)
+	}
+
+	devices, err := api.store.ListOrgDevices(c.Req.Context(), orgID, time.Now().Add(-anonymousDeviceExpiration), time.Now().Add(time.Minute))
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list devices", err)
+	}
+
+	result := OrgDevicesDTO{
+		Devices:     make([]*deviceDTO, 0, len(devices)),
+		TotalCount:  int64(len(devices)),
+		DeviceLimit: settings.DeviceLimit,
+	}
+	for _, device := range devices {
+		result.Devices = append(result.Devices, &deviceDTO{
+			Device:     *device,
+			LastSeenAt: util.GetAgeString(device.UpdatedAt),
+			AvatarUrl:  dtos.GetGravatarUrl(api.cfg, device.DeviceID),
+		})
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+type UpdateOrgSettingsCommand struct {
+	Enabled     bool     `json:"enabled"`
+	DeviceLimit int64    `json:"deviceLimit"`
+	FolderUIDs  []string `json:"folderUids"`
+}
+
+type OrgDevicesDTO struct {
+	Devices    []*deviceDTO `json:"devices"`
+	TotalCount int64        `json:"totalCount"`
+	// DeviceLimit is the device limit of the org, 0 means no limit
+	DeviceLimit int64 `json:"deviceLimit"`
+}
+
+// swagger:parameters updateOrgAnonymousSettings
+type UpdateOrgAnonymousSettingsParams struct {
+	// in:body
+	// required:true
+	Body UpdateOrgSettingsCommand `json:"body"`
+}
+
+// swagger:response orgAnonymousSettingsResponse
+type OrgAnonymousSettingsResponse struct {
+	// in:body
+	Body anonstore.OrgSettings `json:"body"`
+}
+
+// swagger:response orgDevicesResponse
+type OrgDevicesResponse struct {
+	// in:body
+	Body OrgDevicesDTO `json:"body"`
+}
+
 // swagger:response devicesResponse
 type DevicesResponse struct {
 	// in:body
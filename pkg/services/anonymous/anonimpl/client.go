@@ -20,6 +20,7 @@ var (
 	errInvalidOrg  = errutil.Unauthorized("anonymous.invalid-org")
 	errInvalidID   = errutil.Unauthorized("anonymous.invalid-id")
 	errDeviceLimit = errutil.Unauthorized("anonymous.device-limit-reached", errutil.WithPublicMessage("Anonymous device limit reached. Contact Administrator"))
+	errOrgDisabled = errutil.Unauthorized("anonymous.org-disabled", errutil.WithPublicMessage("Anonymous access is disabled for this organization"))
 )
 
 var (
@@ -39,9 +40,8 @@ func (a *Anonymous) Name() string {
 }
 
 func (a *Anonymous) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error) {
-	o, err := a.orgService.GetByName(ctx, &org.GetOrgByNameQuery{Name: a.cfg.Anonymous.OrgName})
+	o, err := a.resolveOrg(ctx, r.OrgID)
 	if err != nil {
-		a.log.FromContext(ctx).Error("Failed to find organization", "name", a.cfg.Anonymous.OrgName, "error", err)
 		return nil, err
 	}
 
@@ -52,7 +52,7 @@ func (a *Anonymous) Authenticate(ctx context.Context, r *authn.Request) (*authn.
 		httpReqCopy.RemoteAddr = r.HTTPRequest.RemoteAddr
 	}
 
-	if err := a.anonDeviceService.TagDevice(ctx, httpReqCopy, anonymous.AnonDeviceUI); err != nil {
+	if err := a.anonDeviceService.TagDevice(ctx, httpReqCopy, anonymous.AnonDeviceUI, o.ID); err != nil {
 		if errors.Is(err, anonstore.ErrDeviceLimitReached) {
 			return nil, errDeviceLimit.Errorf("limit reached for anonymous devices: %w", err)
 		}
@@ -77,7 +77,7 @@ func (a *Anonymous) IdentityType() claims.IdentityType {
 }
 
 func (a *Anonymous) ResolveIdentity(ctx context.Context, orgID int64, typ claims.IdentityType, id string) (*authn.Identity, error) {
-	o, err := a.orgService.GetByName(ctx, &org.GetOrgByNameQuery{Name: a.cfg.Anonymous.OrgName})
+	o, err := a.resolveOrg(ctx, orgID)
 	if err != nil {
 		return nil, err
 	}
@@ -110,6 +110,35 @@ func (a *Anonymous) Priority() uint {
 	return 100
 }
 
+// resolveOrg returns the org anonymous users access. Requests for another org than the one configured
+// in [auth.anonymous] use that org if it enabled anonymous access, the configured org otherwise.
+func (a *Anonymous) resolveOrg(ctx context.Context, orgID int64) (*org.Org, error) {
+	defaultOrg, err := a.orgService.GetByName(ctx, &org.GetOrgByNameQuery{Name: a.cfg.Anonymous.OrgName})
+	if err != nil {
+		a.log.FromContext(ctx).Error("Failed to find organization", "name", a.cfg.Anonymous.OrgName, "error", err)
+		return nil, err
+	}
+
+	if orgID > 0 && orgID != defaultOrg.ID {
+		settings, err := a.anonDeviceService.GetOrgSettings(ctx, orgID)
+		if err != nil {
+			return nil, err
+		}
+		if settings.Enabled {
+			return a.orgService.GetByID(ctx, &org.GetOrgByIDQuery{ID: orgID})
+		}
+	}
+
+	settings, err := a.anonDeviceService.GetOrgSettings(ctx, defaultOrg.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.Enabled {
+		return nil, errOrgDisabled.Errorf("anonymous access is disabled in org %d", defaultOrg.ID)
+	}
+	return defaultOrg, nil
+}
+
 func (a *Anonymous) newAnonymousIdentity(o *org.Org) *authn.Identity {
 	return &authn.Identity{
 		ID:           "0",
@@ -10,6 +10,7 @@ import (
 
 	claims "github.com/grafana/authlib/types"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/anonymous/anonimpl/anonstore"
 	"github.com/grafana/grafana/pkg/services/anonymous/anontest"
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/org"
@@ -73,6 +74,70 @@ func TestAnonymous_Authenticate(t *testing.T) {
 	}
 }
 
+func TestAnonymous_Authenticate_OrgSettings(t *testing.T) {
+	cfg := &setting.Cfg{
+		Anonymous: setting.AnonymousSettings{
+			OrgRole: "Viewer",
+			OrgName: "some org",
+		},
+	}
+
+	t.Run("should use the requested org when it enabled anonymous access", func(t *testing.T) {
+		c := Anonymous{
+			cfg: cfg,
+			log: log.NewNopLogger(),
+			orgService: &orgtest.FakeOrgService{
+				ExpectedOrg:  &org.Org{ID: 2, Name: "other org"},
+				ExpectedOrgs: []*org.OrgDTO{{ID: 1, Name: "some org"}},
+			},
+			anonDeviceService: anontest.NewFakeService(),
+		}
+
+		user, err := c.Authenticate(context.Background(), &authn.Request{OrgID: 2})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), user.OrgID)
+	})
+
+	t.Run("should use the configured org when the requested org didn't enable anonymous access", func(t *testing.T) {
+		c := Anonymous{
+			cfg: cfg,
+			log: log.NewNopLogger(),
+			orgService: &orgtest.FakeOrgService{
+				ExpectedOrgs: []*org.OrgDTO{{ID: 1, Name: "some org"}},
+			},
+			anonDeviceService: &fakeOrgSettingsService{FakeService: anontest.NewFakeService(), enabled: map[int64]bool{1: true}},
+		}
+
+		user, err := c.Authenticate(context.Background(), &authn.Request{OrgID: 2})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), user.OrgID)
+	})
+
+	t.Run("should fail when the configured org disabled anonymous access", func(t *testing.T) {
+		c := Anonymous{
+			cfg: cfg,
+			log: log.NewNopLogger(),
+			orgService: &orgtest.FakeOrgService{
+				ExpectedOrgs: []*org.OrgDTO{{ID: 1, Name: "some org"}},
+			},
+			anonDeviceService: &fakeOrgSettingsService{FakeService: anontest.NewFakeService(), enabled: map[int64]bool{}},
+		}
+
+		user, err := c.Authenticate(context.Background(), &authn.Request{})
+		assert.ErrorIs(t, err, errOrgDisabled)
+		assert.Nil(t, user)
+	})
+}
+
+type fakeOrgSettingsService struct {
+	*anontest.FakeService
+	enabled map[int64]bool
+}
+
+func (f *fakeOrgSettingsService) GetOrgSettings(ctx context.Context, orgID int64) (*anonstore.OrgSettings, error) {
+	return &anonstore.OrgSettings{OrgID: orgID, Enabled: f.enabled[orgID]}, nil
+}
+
 func TestAnonymous_ResolveIdentity(t *testing.T) {
 	type TestCase struct {
 		desc        string
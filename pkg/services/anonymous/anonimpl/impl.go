@@ -4,8 +4,14 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	claims "github.com/grafana/authlib/types"
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/localcache"
@@ -19,6 +25,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/anonymous/anonimpl/api"
 	"github.com/grafana/grafana/pkg/services/anonymous/validator"
 	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
@@ -27,6 +34,8 @@ import (
 const thirtyDays = 30 * 24 * time.Hour
 const deviceIDHeader = "X-Grafana-Device-Id"
 const keepFor = time.Hour * 24 * 61
+const orgSettingsCacheTTL = time.Minute
+const limitWarningInterval = time.Hour
 
 type AnonDeviceService struct {
 	log            log.Logger
@@ -35,12 +44,16 @@ type AnonDeviceService struct {
 	serverLock     *serverlock.ServerLockService
 	cfg            *setting.Cfg
 	limitValidator validator.AnonUserLimitValidator
+	orgService     org.Service
+	// orgSettings caches the stored settings of orgs, orgs without settings are cached as nil
+	orgSettings *localcache.CacheService
+	metrics     *metrics
 }
 
 func ProvideAnonymousDeviceService(usageStats usagestats.Service, authBroker authn.Service,
 	sqlStore db.DB, cfg *setting.Cfg, orgService org.Service,
 	serverLockService *serverlock.ServerLockService, accesscontrol accesscontrol.AccessControl, routeRegister routing.RouteRegister,
-	validator validator.AnonUserLimitValidator,
+	validator validator.AnonUserLimitValidator, reg prometheus.Registerer,
 ) *AnonDeviceService {
 	a := &AnonDeviceService{
 		log:            log.New("anonymous-session-service"),
@@ -49,6 +62,9 @@ func ProvideAnonymousDeviceService(usageStats usagestats.Service, authBroker aut
 		serverLock:     serverLockService,
 		cfg:            cfg,
 		limitValidator: validator,
+		orgService:     orgService,
+		orgSettings:    localcache.New(orgSettingsCacheTTL, 2*orgSettingsCacheTTL),
+		metrics:        newMetrics(reg),
 	}
 
 	usageStats.RegisterMetricsFunc(a.usageStatFn)
@@ -63,9 +79,11 @@ func ProvideAnonymousDeviceService(usageStats usagestats.Service, authBroker aut
 	if cfg.Anonymous.Enabled {
 		authBroker.RegisterClient(anonClient)
 		authBroker.RegisterPostLoginHook(a.untagDevice, 100)
+		// The hook runs once the permissions of the anonymous users are synced
+		authBroker.RegisterPostAuthHook(a.restrictFoldersHook, 125)
 	}
 
-	anonAPI := api.NewAnonDeviceServiceAPI(cfg, a.anonStore, accesscontrol, routeRegister)
+	anonAPI := api.NewAnonDeviceServiceAPI(cfg, a.anonStore, a, accesscontrol, routeRegister)
 	anonAPI.RegisterAPIEndpoints()
 
 	return a
@@ -105,7 +123,7 @@ func (a *AnonDeviceService) tagDeviceUI(ctx context.Context, device *anonstore.D
 		a.log.Debug("Tagging device for UI", "deviceID", device.DeviceID, "device", device, "key", key)
 	}
 
-	if err := a.anonStore.CreateOrUpdateDevice(ctx, device); err != nil {
+	if err := a.storeDevice(ctx, device); err != nil {
 		if errors.Is(err, anonstore.ErrDeviceLimitReached) {
 			a.localCache.SetDefault(key, false)
 			return err
@@ -118,6 +136,55 @@ func (a *AnonDeviceService) tagDeviceUI(ctx context.Context, device *anonstore.D
 	return nil
 }
 
+// storeDevice enforces the device limit of the org of the device, the store enforces the global device limit
+func (a *AnonDeviceService) storeDevice(ctx context.Context, device *anonstore.Device) error {
+	settings, err := a.storedOrgSettings(ctx, device.OrgID)
+	if err != nil {
+		return err
+	}
+	if settings == nil || settings.DeviceLimit <= 0 {
+		return a.anonStore.CreateOrUpdateDevice(ctx, device)
+	}
+
+	count, err := a.anonStore.CountOrgDevices(ctx, device.OrgID, time.Now().Add(-thirtyDays), time.Now().Add(time.Minute))
+	if err != nil {
+		return err
+	}
+
+	// once the limit is reached, only the devices already in the org are allowed
+	if count >= settings.DeviceLimit {
+		err := a.anonStore.UpdateOrgDevice(ctx, device)
+		if errors.Is(err, anonstore.ErrDeviceLimitReached) {
+			a.metrics.limitReached.Inc()
+			a.log.FromContext(ctx).Warn("Anonymous device denied by the device limit of the org", "orgId", device.OrgID, "limit", settings.DeviceLimit)
+		}
+		return err
+	}
+
+	if err := a.anonStore.CreateOrUpdateDevice(ctx, device); err != nil {
+		return err
+	}
+	a.warnDeviceLimit(ctx, device.OrgID, count+1, settings.DeviceLimit)
+	return nil
+}
+
+// warnDeviceLimit warns at most once per interval when the devices of an org reach the warning threshold of the limit
+func (a *AnonDeviceService) warnDeviceLimit(ctx context.Context, orgID, count, limit int64) {
+	threshold := a.cfg.Anonymous.DeviceLimitWarningThreshold
+	if threshold <= 0 || count*100 < limit*threshold {
+		return
+	}
+
+	key := "device-limit-warning:" + strconv.FormatInt(orgID, 10)
+	if _, ok := a.localCache.Get(key); ok {
+		return
+	}
+	a.localCache.Set(key, true, limitWarningInterval)
+
+	a.metrics.limitWarnings.Inc()
+	a.log.FromContext(ctx).Warn("Anonymous devices of the org are close to the device limit", "orgId", orgID, "devices", count, "limit", limit)
+}
+
 func (a *AnonDeviceService) untagDevice(ctx context.Context, _ *authn.Identity, r *authn.Request, err error) {
 	if err != nil {
 		return
@@ -134,7 +201,7 @@ func (a *AnonDeviceService) untagDevice(ctx context.Context, _ *authn.Identity,
 	}
 }
 
-func (a *AnonDeviceService) TagDevice(ctx context.Context, httpReq *http.Request, kind anonymous.DeviceKind) error {
+func (a *AnonDeviceService) TagDevice(ctx context.Context, httpReq *http.Request, kind anonymous.DeviceKind, orgID int64) error {
 	deviceID := httpReq.Header.Get(deviceIDHeader)
 	if deviceID == "" {
 		return nil
@@ -153,6 +220,7 @@ func (a *AnonDeviceService) TagDevice(ctx context.Context, httpReq *http.Request
 	}
 
 	taggedDevice := &anonstore.Device{
+		OrgID:     orgID,
 		DeviceID:  deviceID,
 		ClientIP:  clientIPStr,
 		UserAgent: httpReq.UserAgent(),
@@ -197,6 +265,104 @@ func (a *AnonDeviceService) SearchDevices(ctx context.Context, query *anonstore.
 	return a.anonStore.SearchDevices(ctx, query)
 }
 
+// GetOrgSettings returns the anonymous access settings of the org. Orgs without settings only allow anonymous
+// users in the org configured in [auth.anonymous].
+func (a *AnonDeviceService) GetOrgSettings(ctx context.Context, orgID int64) (*anonstore.OrgSettings, error) {
+	settings, err := a.storedOrgSettings(ctx, orgID)
+	if err != nil || settings != nil {
+		return settings, err
+	}
+
+	defaultOrg, err := a.orgService.GetByName(ctx, &org.GetOrgByNameQuery{Name: a.cfg.Anonymous.OrgName})
+	if err != nil && !errors.Is(err, org.ErrOrgNotFound) {
+		return nil, err
+	}
+
+	return &anonstore.OrgSettings{
+		OrgID:      orgID,
+		Enabled:    defaultOrg != nil && defaultOrg.ID == orgID,
+		FolderUIDs: []string{},
+	}, nil
+}
+
+func (a *AnonDeviceService) SetOrgSettings(ctx context.Context, settings *anonstore.OrgSettings) error {
+	if settings.DeviceLimit < 0 {
+		return anonymous.ErrInvalidOrgSettings.Errorf("device limit can't be negative")
+	}
+
+	folderUIDs := make([]string, 0, len(settings.FolderUIDs))
+	for _, uid := range settings.FolderUIDs {
+		uid = strings.TrimSpace(uid)
+		if uid == "" {
+			return anonymous.ErrInvalidOrgSettings.Errorf("folder UIDs can't be empty")
+		}
+		if !slices.Contains(folderUIDs, uid) {
+			folderUIDs = append(folderUIDs, uid)
+		}
+	}
+	settings.FolderUIDs = folderUIDs
+	settings.Updated = time.Now()
+
+	if err := a.anonStore.SaveOrgSettings(ctx, settings); err != nil {
+		return err
+	}
+	a.orgSettings.Delete(orgSettingsCacheKey(settings.OrgID))
+	return nil
+}
+
+func (a *AnonDeviceService) storedOrgSettings(ctx context.Context, orgID int64) (*anonstore.OrgSettings, error) {
+	key := orgSettingsCacheKey(orgID)
+	if cached, ok := a.orgSettings.Get(key); ok {
+		return cached.(*anonstore.OrgSettings), nil
+	}
+
+	settings, err := a.anonStore.GetOrgSettings(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	a.orgSettings.SetDefault(key, settings)
+	return settings, nil
+}
+
+// restrictFoldersHook limits the dashboard and folder permissions of anonymous users to the folder allowlist of the org
+func (a *AnonDeviceService) restrictFoldersHook(ctx context.Context, id *authn.Identity, _ *authn.Request) error {
+	if !id.IsIdentityType(claims.TypeAnonymous) {
+		return nil
+	}
+
+	settings, err := a.storedOrgSettings(ctx, id.GetOrgID())
+	if err != nil || settings == nil || len(settings.FolderUIDs) == 0 {
+		return err
+	}
+
+	allowed := make([]string, 0, len(settings.FolderUIDs))
+	for _, uid := range settings.FolderUIDs {
+		allowed = append(allowed, dashboards.ScopeFoldersProvider.GetResourceScopeUID(uid))
+	}
+
+	permissions := id.Permissions[id.GetOrgID()]
+	for action, scopes := range permissions {
+		restricted := make([]string, 0, len(scopes))
+		for _, scope := range scopes {
+			if !isDashboardScope(scope) {
+				restricted = append(restricted, scope)
+			}
+		}
+		if len(restricted) != len(scopes) {
+			permissions[action] = append(restricted, allowed...)
+		}
+	}
+	return nil
+}
+
+func isDashboardScope(scope string) bool {
+	return strings.HasPrefix(scope, dashboards.ScopeDashboardsRoot+":") || strings.HasPrefix(scope, dashboards.ScopeFoldersRoot+":")
+}
+
+func orgSettingsCacheKey(orgID int64) string {
+	return "org-settings:" + strconv.FormatInt(orgID, 10)
+}
+
 func (a *AnonDeviceService) Run(ctx context.Context) error {
 	ticker := time.NewTicker(2 * time.Hour)
 
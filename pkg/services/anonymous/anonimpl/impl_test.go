@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	claims "github.com/grafana/authlib/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -18,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/anonymous/validator"
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/authn/authntest"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/org/orgtest"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tests/testsuite"
@@ -73,6 +76,7 @@ func TestIntegrationDeviceService_tag(t *testing.T) {
 			expectedAnonUICount: 1,
 			expectedKey:         "ui-anon-session:32mdo31deeqwes",
 			expectedDevice: &anonstore.Device{
+				OrgID:     1,
 				DeviceID:  "32mdo31deeqwes",
 				ClientIP:  "10.30.30.1",
 				UserAgent: "test"},
@@ -150,11 +154,11 @@ func TestIntegrationDeviceService_tag(t *testing.T) {
 
 			anonService := ProvideAnonymousDeviceService(
 				&usagestats.UsageStatsMock{}, &authntest.FakeService{}, store, cfg, orgtest.NewOrgServiceFake(),
-				nil, actest.FakeAccessControl{}, &routing.RouteRegisterImpl{}, validator.FakeAnonUserLimitValidator{},
+				nil, actest.FakeAccessControl{}, &routing.RouteRegisterImpl{}, validator.FakeAnonUserLimitValidator{}, nil,
 			)
 
 			for _, req := range tc.req {
-				err := anonService.TagDevice(ctx, req.httpReq, req.kind)
+				err := anonService.TagDevice(ctx, req.httpReq, req.kind, 1)
 				require.NoError(t, err)
 
 				t.Cleanup(func() {
@@ -213,7 +217,7 @@ func TestIntegrationAnonDeviceService_localCacheSafety(t *testing.T) {
 	}
 	store := db.InitTestDB(t)
 	anonService := ProvideAnonymousDeviceService(&usagestats.UsageStatsMock{},
-		&authntest.FakeService{}, store, setting.NewCfg(), orgtest.NewOrgServiceFake(), nil, actest.FakeAccessControl{}, &routing.RouteRegisterImpl{}, validator.FakeAnonUserLimitValidator{})
+		&authntest.FakeService{}, store, setting.NewCfg(), orgtest.NewOrgServiceFake(), nil, actest.FakeAccessControl{}, &routing.RouteRegisterImpl{}, validator.FakeAnonUserLimitValidator{}, nil)
 
 	req := &http.Request{
 		Header: http.Header{
@@ -224,6 +228,7 @@ func TestIntegrationAnonDeviceService_localCacheSafety(t *testing.T) {
 	}
 
 	anonDevice := &anonstore.Device{
+		OrgID:     1,
 		DeviceID:  "32mdo31deeqwes",
 		ClientIP:  "10.30.30.2",
 		UserAgent: "test",
@@ -233,7 +238,7 @@ func TestIntegrationAnonDeviceService_localCacheSafety(t *testing.T) {
 	key := anonDevice.CacheKey()
 	anonService.localCache.SetDefault(key, true)
 
-	err := anonService.TagDevice(context.Background(), req, anonymous.AnonDeviceUI)
+	err := anonService.TagDevice(context.Background(), req, anonymous.AnonDeviceUI, 1)
 	require.NoError(t, err)
 
 	stats, err := anonService.usageStatFn(context.Background())
@@ -331,7 +336,7 @@ func TestIntegrationDeviceService_SearchDevice(t *testing.T) {
 	store := db.InitTestDB(t)
 	cfg := setting.NewCfg()
 	cfg.Anonymous.Enabled = true
-	anonService := ProvideAnonymousDeviceService(&usagestats.UsageStatsMock{}, &authntest.FakeService{}, store, cfg, orgtest.NewOrgServiceFake(), nil, actest.FakeAccessControl{}, &routing.RouteRegisterImpl{}, validator.FakeAnonUserLimitValidator{})
+	anonService := ProvideAnonymousDeviceService(&usagestats.UsageStatsMock{}, &authntest.FakeService{}, store, cfg, orgtest.NewOrgServiceFake(), nil, actest.FakeAccessControl{}, &routing.RouteRegisterImpl{}, validator.FakeAnonUserLimitValidator{}, nil)
 
 	for _, tc := range testCases {
 		err := store.Reset()
@@ -373,6 +378,7 @@ func TestIntegrationAnonDeviceService_DeviceLimitWithCache(t *testing.T) {
 		actest.FakeAccessControl{},
 		&routing.RouteRegisterImpl{},
 		validator.FakeAnonUserLimitValidator{},
+		nil,
 	)
 
 	// Define test cases
@@ -430,7 +436,7 @@ func TestIntegrationAnonDeviceService_DeviceLimitWithCache(t *testing.T) {
 	// Run test cases
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			err := anonService.TagDevice(context.Background(), tc.httpReq, anonymous.AnonDeviceUI)
+			err := anonService.TagDevice(context.Background(), tc.httpReq, anonymous.AnonDeviceUI, 1)
 			if tc.expectedErr != nil {
 				require.Error(t, err)
 				assert.Equal(t, tc.expectedErr, err)
@@ -440,3 +446,121 @@ func TestIntegrationAnonDeviceService_DeviceLimitWithCache(t *testing.T) {
 		})
 	}
 }
+
+func TestIntegrationAnonDeviceService_OrgSettings(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode")
+	}
+	ctx := context.Background()
+
+	store := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.Anonymous.Enabled = true
+	cfg.Anonymous.OrgName = "Main Org."
+	orgService := &orgtest.FakeOrgService{ExpectedOrg: &org.Org{ID: 1, Name: "Main Org."}}
+	anonService := ProvideAnonymousDeviceService(&usagestats.UsageStatsMock{}, &authntest.FakeService{}, store, cfg, orgService,
+		nil, actest.FakeAccessControl{}, &routing.RouteRegisterImpl{}, validator.FakeAnonUserLimitValidator{}, nil)
+
+	t.Run("should only enable the configured org by default", func(t *testing.T) {
+		settings, err := anonService.GetOrgSettings(ctx, 1)
+		require.NoError(t, err)
+		assert.True(t, settings.Enabled)
+
+		settings, err = anonService.GetOrgSettings(ctx, 2)
+		require.NoError(t, err)
+		assert.False(t, settings.Enabled)
+	})
+
+	t.Run("should store the settings of the org", func(t *testing.T) {
+		err := anonService.SetOrgSettings(ctx, &anonstore.OrgSettings{OrgID: 2, Enabled: true, DeviceLimit: 5, FolderUIDs: []string{" a ", "a", "b"}})
+		require.NoError(t, err)
+
+		settings, err := anonService.GetOrgSettings(ctx, 2)
+		require.NoError(t, err)
+		assert.True(t, settings.Enabled)
+		assert.Equal(t, int64(5), settings.DeviceLimit)
+		assert.Equal(t, []string{"a", "b"}, settings.FolderUIDs)
+	})
+
+	t.Run("should reject invalid settings", func(t *testing.T) {
+		err := anonService.SetOrgSettings(ctx, &anonstore.OrgSettings{OrgID: 2, DeviceLimit: -1})
+		assert.ErrorIs(t, err, anonymous.ErrInvalidOrgSettings)
+
+		err = anonService.SetOrgSettings(ctx, &anonstore.OrgSettings{OrgID: 2, FolderUIDs: []string{" "}})
+		assert.ErrorIs(t, err, anonymous.ErrInvalidOrgSettings)
+	})
+}
+
+func TestIntegrationAnonDeviceService_OrgDeviceLimit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode")
+	}
+	ctx := context.Background()
+
+	store := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.Anonymous.Enabled = true
+	cfg.Anonymous.DeviceLimitWarningThreshold = 50
+	anonService := ProvideAnonymousDeviceService(&usagestats.UsageStatsMock{}, &authntest.FakeService{}, store, cfg, orgtest.NewOrgServiceFake(),
+		nil, actest.FakeAccessControl{}, &routing.RouteRegisterImpl{}, validator.FakeAnonUserLimitValidator{}, nil)
+	require.NoError(t, anonService.SetOrgSettings(ctx, &anonstore.OrgSettings{OrgID: 2, Enabled: true, DeviceLimit: 2}))
+
+	newReq := func(deviceID string) *http.Request {
+		return &http.Request{Header: http.Header{
+			"User-Agent":                            []string{"test"},
+			"X-Forwarded-For":                       []string{"10.30.30.1"},
+			http.CanonicalHeaderKey(deviceIDHeader): []string{deviceID},
+		}}
+	}
+
+	require.NoError(t, anonService.TagDevice(ctx, newReq("device1"), anonymous.AnonDeviceUI, 2))
+	assert.Equal(t, float64(1), testutil.ToFloat64(anonService.metrics.limitWarnings))
+	require.NoError(t, anonService.TagDevice(ctx, newReq("device2"), anonymous.AnonDeviceUI, 2))
+	// warnings are only emitted once per interval
+	assert.Equal(t, float64(1), testutil.ToFloat64(anonService.metrics.limitWarnings))
+
+	err := anonService.TagDevice(ctx, newReq("device3"), anonymous.AnonDeviceUI, 2)
+	assert.ErrorIs(t, err, anonstore.ErrDeviceLimitReached)
+	assert.Equal(t, float64(1), testutil.ToFloat64(anonService.metrics.limitReached))
+
+	// other orgs aren't limited
+	require.NoError(t, anonService.TagDevice(ctx, newReq("device3"), anonymous.AnonDeviceUI, 1))
+
+	devices, err := anonService.anonStore.ListOrgDevices(ctx, 2, time.Now().Add(-time.Hour), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, devices, 2)
+}
+
+func TestIntegrationAnonDeviceService_restrictFoldersHook(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode")
+	}
+	ctx := context.Background()
+
+	store := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.Anonymous.Enabled = true
+	anonService := ProvideAnonymousDeviceService(&usagestats.UsageStatsMock{}, &authntest.FakeService{}, store, cfg, orgtest.NewOrgServiceFake(),
+		nil, actest.FakeAccessControl{}, &routing.RouteRegisterImpl{}, validator.FakeAnonUserLimitValidator{}, nil)
+	require.NoError(t, anonService.SetOrgSettings(ctx, &anonstore.OrgSettings{OrgID: 1, Enabled: true, FolderUIDs: []string{"a"}}))
+
+	newIdentity := func(typ claims.IdentityType) *authn.Identity {
+		return &authn.Identity{ID: "0", Type: typ, OrgID: 1, Permissions: map[int64]map[string][]string{1: {
+			"dashboards:read":   {"dashboards:*", "folders:*"},
+			"folders:read":      {"folders:*"},
+			"datasources:query": {"datasources:*"},
+		}}}
+	}
+
+	id := newIdentity(claims.TypeAnonymous)
+	require.NoError(t, anonService.restrictFoldersHook(ctx, id, &authn.Request{}))
+	assert.Equal(t, map[string][]string{
+		"dashboards:read":   {"folders:uid:a"},
+		"folders:read":      {"folders:uid:a"},
+		"datasources:query": {"datasources:*"},
+	}, id.Permissions[1])
+
+	user := newIdentity(claims.TypeUser)
+	require.NoError(t, anonService.restrictFoldersHook(ctx, user, &authn.Request{}))
+	assert.Equal(t, []string{"dashboards:*", "folders:*"}, user.Permissions[1]["dashboards:read"])
+}
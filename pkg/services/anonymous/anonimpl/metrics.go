@@ -0,0 +1,38 @@
+package anonimpl
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "grafana"
+	metricsSubSystem = "anonymous"
+)
+
+type metrics struct {
+	limitReached  prometheus.Counter
+	limitWarnings prometheus.Counter
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		limitReached: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "org_device_limit_reached_total",
+			Help:      "Number of anonymous devices denied by the device limit of their org",
+		}),
+		limitWarnings: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "org_device_limit_warnings_total",
+			Help:      "Number of times an org reached the warning threshold of its anonymous device limit",
+		}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.limitReached, m.limitWarnings)
+	}
+
+	return m
+}
@@ -12,14 +12,16 @@ import (
 type FakeService struct {
 	ExpectedCountDevices int64
 	ExpectedListDevices  []*anonstore.Device
-	ExpectedError        error
+	// ExpectedOrgSettings defaults to enabled settings without limits
+	ExpectedOrgSettings *anonstore.OrgSettings
+	ExpectedError       error
 }
 
 func NewFakeService() *FakeService {
 	return &FakeService{}
 }
 
-func (f *FakeService) TagDevice(ctx context.Context, httpReq *http.Request, kind anonymous.DeviceKind) error {
+func (f *FakeService) TagDevice(ctx context.Context, httpReq *http.Request, kind anonymous.DeviceKind, orgID int64) error {
 	return f.ExpectedError
 }
 
@@ -30,3 +32,14 @@ func (f *FakeService) CountDevices(ctx context.Context, from time.Time, to time.
 func (f *FakeService) ListDevices(ctx context.Context, from *time.Time, to *time.Time) ([]*anonstore.Device, error) {
 	return f.ExpectedListDevices, f.ExpectedError
 }
+
+func (f *FakeService) GetOrgSettings(ctx context.Context, orgID int64) (*anonstore.OrgSettings, error) {
+	if f.ExpectedOrgSettings != nil {
+		return f.ExpectedOrgSettings, nil
+	}
+	return &anonstore.OrgSettings{OrgID: orgID, Enabled: true, FolderUIDs: []string{}}, nil
+}
+
+func (f *FakeService) SetOrgSettings(ctx context.Context, settings *anonstore.OrgSettings) error {
+	return f.ExpectedError
+}
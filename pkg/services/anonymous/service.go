@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/services/anonymous/anonimpl/anonstore"
 )
 
@@ -14,8 +15,14 @@ const (
 	AnonDeviceUI DeviceKind = "ui-anon-session"
 )
 
+var ErrInvalidOrgSettings = errutil.BadRequest("anonymous.invalid-org-settings")
+
 type Service interface {
-	TagDevice(context.Context, *http.Request, DeviceKind) error
+	// TagDevice tracks the device of an anonymous request in the org
+	TagDevice(ctx context.Context, httpReq *http.Request, kind DeviceKind, orgID int64) error
 	CountDevices(ctx context.Context, from time.Time, to time.Time) (int64, error)
 	ListDevices(ctx context.Context, from *time.Time, to *time.Time) ([]*anonstore.Device, error)
+	// GetOrgSettings returns the anonymous access settings of the org, or the defaults when the org has none
+	GetOrgSettings(ctx context.Context, orgID int64) (*anonstore.OrgSettings, error)
+	SetOrgSettings(ctx context.Context, settings *anonstore.OrgSettings) error
 }
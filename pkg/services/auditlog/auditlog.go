@@ -0,0 +1,143 @@
+package auditlog
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+var ErrInvalidSearch = errutil.BadRequest("auditlog.invalid-search")
+
+// Categories of audit events
+const (
+	CategoryAuth            = "auth"
+	CategoryPermissions     = "permissions"
+	CategoryDashboards      = "dashboards"
+	CategoryFolders         = "folders"
+	CategoryDatasources     = "datasources"
+	CategoryAlerting        = "alerting"
+	CategoryAdmin           = "admin"
+	CategoryOrgs            = "orgs"
+	CategoryUsers           = "users"
+	CategoryTeams           = "teams"
+	CategoryServiceAccounts = "serviceaccounts"
+)
+
+// Actions of audit events
+const (
+	ActionLogin  = "login"
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+type Service interface {
+	// Record queues the event to be stored and sent to the sinks, it doesn't wait for either.
+	// Events are dropped when the audit log is disabled or its buffer is full.
+	Record(ctx context.Context, event *Event)
+	Search(ctx context.Context, query *SearchQuery) (*SearchResult, error)
+}
+
+type Event struct {
+	ID        int64     `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	OrgID     int64     `json:"orgId"`
+	// ActorID is the typed id of the identity, for example user:1
+	ActorID    string `json:"actorId"`
+	ActorLogin string `json:"actorLogin"`
+	// ImpersonatorLogin is set for requests made while impersonating the actor
+	ImpersonatorLogin string `json:"impersonatorLogin,omitempty"`
+	Category          string `json:"category"`
+	Action            string `json:"action"`
+	// Route is the route pattern of the request, or the authentication module of logins
+	Route      string `json:"route,omitempty"`
+	Method     string `json:"method,omitempty"`
+	Path       string `json:"path,omitempty"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Result     string `json:"result"`
+	ClientIP   string `json:"clientIp,omitempty"`
+	UserAgent  string `json:"userAgent,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
+
+type SearchQuery struct {
+	From     time.Time
+	To       time.Time
+	OrgID    int64
+	Actor    string
+	Category string
+	Action   string
+	Result   string
+	Page     int
+	Limit    int
+}
+
+type SearchResult struct {
+	TotalCount int64    `json:"totalCount"`
+	Events     []*Event `json:"events"`
+	Page       int      `json:"page"`
+	PerPage    int      `json:"perPage"`
+}
+
+var routeCategories = []struct {
+	prefix   string
+	category string
+}{
+	{prefix: "/api/access-control", category: CategoryPermissions},
+	{prefix: "/api/dashboards", category: CategoryDashboards},
+	{prefix: "/api/snapshots", category: CategoryDashboards},
+	{prefix: "/api/public-dashboards", category: CategoryDashboards},
+	{prefix: "/api/folders", category: CategoryFolders},
+	{prefix: "/api/datasources", category: CategoryDatasources},
+	{prefix: "/api/ruler", category: CategoryAlerting},
+	{prefix: "/api/alertmanager", category: CategoryAlerting},
+	{prefix: "/api/v1/provisioning", category: CategoryAlerting},
+	{prefix: "/api/alert-notifications", category: CategoryAlerting},
+	{prefix: "/api/admin", category: CategoryAdmin},
+	{prefix: "/api/orgs", category: CategoryOrgs},
+	{prefix: "/api/org", category: CategoryOrgs},
+	{prefix: "/api/users", category: CategoryUsers},
+	{prefix: "/api/user", category: CategoryUsers},
+	{prefix: "/api/teams", category: CategoryTeams},
+	{prefix: "/api/serviceaccounts", category: CategoryServiceAccounts},
+}
+
+// CategoryForRoute returns the category of requests to the route pattern, an empty string if they are not audited
+func CategoryForRoute(route string) string {
+	if strings.Contains(route, "/permissions") {
+		return CategoryPermissions
+	}
+	// Requests forwarded to data sources query them rather than change them
+	if strings.HasPrefix(route, "/api/datasources/") && (strings.Contains(route, "/proxy") || strings.Contains(route, "/resources")) {
+		return ""
+	}
+
+	for _, rc := range routeCategories {
+		if route == rc.prefix || strings.HasPrefix(route, rc.prefix+"/") {
+			return rc.category
+		}
+	}
+	return ""
+}
+
+// ActionForMethod returns the action of requests with the HTTP method, an empty string for methods that don't change Grafana
+func ActionForMethod(method string) string {
+	switch method {
+	case http.MethodPost:
+		return ActionCreate
+	case http.MethodPut, http.MethodPatch:
+		return ActionUpdate
+	case http.MethodDelete:
+		return ActionDelete
+	default:
+		return ""
+	}
+}
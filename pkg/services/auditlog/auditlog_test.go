@@ -0,0 +1,43 @@
+package auditlog
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoryForRoute(t *testing.T) {
+	testCases := []struct {
+		route string
+		exp   string
+	}{
+		{route: "/api/dashboards/db/", exp: CategoryDashboards},
+		{route: "/api/dashboards/uid/:uid/permissions", exp: CategoryPermissions},
+		{route: "/api/access-control/users/:userId/roles", exp: CategoryPermissions},
+		{route: "/api/folders/:uid", exp: CategoryFolders},
+		{route: "/api/datasources/uid/:uid", exp: CategoryDatasources},
+		{route: "/api/datasources/proxy/uid/:uid/*", exp: ""},
+		{route: "/api/datasources/uid/:uid/resources/*", exp: ""},
+		{route: "/api/v1/provisioning/alert-rules", exp: CategoryAlerting},
+		{route: "/api/admin/users/:id/password", exp: CategoryAdmin},
+		{route: "/api/org/users/:userId", exp: CategoryOrgs},
+		{route: "/api/orgs/:orgId", exp: CategoryOrgs},
+		{route: "/api/user/password", exp: CategoryUsers},
+		{route: "/api/user-preferences", exp: ""},
+		{route: "/api/serviceaccounts/:serviceAccountId/tokens", exp: CategoryServiceAccounts},
+		{route: "/api/ds/query", exp: ""},
+		{route: "/api/frontend-metrics", exp: ""},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.exp, CategoryForRoute(tc.route), tc.route)
+	}
+}
+
+func TestActionForMethod(t *testing.T) {
+	assert.Equal(t, ActionCreate, ActionForMethod(http.MethodPost))
+	assert.Equal(t, ActionUpdate, ActionForMethod(http.MethodPut))
+	assert.Equal(t, ActionUpdate, ActionForMethod(http.MethodPatch))
+	assert.Equal(t, ActionDelete, ActionForMethod(http.MethodDelete))
+	assert.Equal(t, "", ActionForMethod(http.MethodGet))
+}
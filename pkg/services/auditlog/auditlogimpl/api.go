@@ -0,0 +1,107 @@
+package auditlogimpl
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+
+	router.Group("/api/admin", func(adminRoute routing.RouteRegister) {
+		adminRoute.Get("/audit", authorize(ac.EvalPermission(ac.ActionServerAuditRead)), routing.Wrap(s.SearchAuditEvents))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /admin/audit admin searchAuditEvents
+//
+// Search the audit log.
+//
+// Returns the audit events matching the filters, the most recent first.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: searchAuditEventsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) SearchAuditEvents(c *contextmodel.ReqContext) response.Response {
+	query := &auditlog.SearchQuery{
+		OrgID:    c.QueryInt64("orgId"),
+		Actor:    c.Query("actor"),
+		Category: c.Query("category"),
+		Action:   c.Query("action"),
+		Result:   c.Query("result"),
+		Page:     c.QueryIntWithDefault("page", 1),
+		Limit:    c.QueryIntWithDefault("perpage", defaultPerPage),
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.UnixMilli(from)
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.UnixMilli(to)
+	}
+
+	result, err := s.Search(c.Req.Context(), query)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to search audit events", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:parameters searchAuditEvents
+type SearchAuditEventsParams struct {
+	// Only return events recorded at or after this time, in epoch milliseconds.
+	// in:query
+	// required:false
+	From int64 `json:"from"`
+	// Only return events recorded at or before this time, in epoch milliseconds.
+	// in:query
+	// required:false
+	To int64 `json:"to"`
+	// in:query
+	// required:false
+	OrgID int64 `json:"orgId"`
+	// Login or id of the user, for example user:1, that caused the events.
+	// in:query
+	// required:false
+	Actor string `json:"actor"`
+	// in:query
+	// required:false
+	// enum: auth,permissions,dashboards,folders,datasources,alerting,admin,orgs,users,teams,serviceaccounts
+	Category string `json:"category"`
+	// in:query
+	// required:false
+	// enum: login,create,update,delete
+	Action string `json:"action"`
+	// in:query
+	// required:false
+	// enum: success,failure
+	Result string `json:"result"`
+	// in:query
+	// required:false
+	// default:1
+	Page int `json:"page"`
+	// Limit the number of events per page, at most 1000.
+	// in:query
+	// required:false
+	// default:100
+	PerPage int `json:"perpage"`
+}
+
+// swagger:response searchAuditEventsResponse
+type SearchAuditEventsResponse struct {
+	// in:body
+	Body auditlog.SearchResult `json:"body"`
+}
@@ -0,0 +1,45 @@
+package auditlogimpl
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "grafana"
+	metricsSubSystem = "audit_log"
+)
+
+type metrics struct {
+	dropped      prometheus.Counter
+	written      *prometheus.CounterVec
+	sinkFailures *prometheus.CounterVec
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		dropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "dropped_events_total",
+			Help:      "Number of audit events dropped because the buffer was full",
+		}),
+		written: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "events_written_total",
+			Help:      "Number of audit events written to the database and each sink",
+		}, []string{"sink"}),
+		sinkFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "sink_failures_total",
+			Help:      "Number of batches of audit events that failed to be written to the database or a sink",
+		}, []string{"sink"}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.dropped, m.written, m.sinkFailures)
+	}
+
+	return m
+}
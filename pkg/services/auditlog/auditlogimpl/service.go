@@ -0,0 +1,215 @@
+package auditlogimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+var _ auditlog.Service = (*Service)(nil)
+
+const (
+	batchSize         = 100
+	flushInterval     = time.Second
+	retentionInterval = time.Hour
+)
+
+func ProvideService(
+	cfg *setting.Cfg, sqlStore db.DB, router routing.RouteRegister, accessControl ac.AccessControl,
+	authnService authn.Service, reg prometheus.Registerer, tracer trace.Tracer,
+) (*Service, error) {
+	s := &Service{
+		cfg:     cfg.AuditLog,
+		store:   &xormStore{db: sqlStore},
+		events:  make(chan *auditlog.Event, cfg.AuditLog.BufferSize),
+		metrics: newMetrics(reg),
+		log:     log.New("auditlog"),
+		tracer:  tracer,
+		now:     time.Now,
+	}
+
+	if !cfg.AuditLog.Enabled {
+		return s, nil
+	}
+
+	sinks, err := newSinks(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.sinks = sinks
+
+	authnService.RegisterPostLoginHook(s.loginHook, 150)
+	s.registerRoutes(router, accessControl)
+
+	return s, nil
+}
+
+// Service stores audit events in the database and forwards them to the configured sinks.
+// Events are written in batches by Run so recording them never blocks a request.
+type Service struct {
+	cfg     setting.AuditLogSettings
+	store   store
+	sinks   []sink
+	events  chan *auditlog.Event
+	metrics *metrics
+	log     log.Logger
+	tracer  trace.Tracer
+	now     func() time.Time
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Enabled
+}
+
+func (s *Service) Record(ctx context.Context, event *auditlog.Event) {
+	if !s.cfg.Enabled {
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = s.now()
+	}
+
+	select {
+	case s.events <- event:
+	default:
+		s.metrics.dropped.Inc()
+		s.log.FromContext(ctx).Warn("Audit log buffer is full, dropping event", "category", event.Category, "action", event.Action, "actor", event.ActorLogin)
+	}
+}
+
+func (s *Service) Search(ctx context.Context, query *auditlog.SearchQuery) (*auditlog.SearchResult, error) {
+	ctx, span := s.tracer.Start(ctx, "auditlog.Search")
+	defer span.End()
+
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		return nil, auditlog.ErrInvalidSearch.Errorf("to must not be before from")
+	}
+	return s.store.Search(ctx, query)
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	flush := time.NewTicker(flushInterval)
+	defer flush.Stop()
+	retention := time.NewTicker(retentionInterval)
+	defer retention.Stop()
+
+	s.deleteExpired(ctx)
+
+	batch := make([]*auditlog.Event, 0, batchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			// the events still in the buffer are written with a fresh context, the one of the server is already canceled
+			batch = s.drain(batch)
+			flushCtx, cancel := context.WithTimeout(context.Background(), s.cfg.SinkTimeout)
+			s.write(flushCtx, batch)
+			cancel()
+			return ctx.Err()
+		case event := <-s.events:
+			batch = append(batch, event)
+			if len(batch) >= batchSize {
+				s.write(ctx, batch)
+				batch = make([]*auditlog.Event, 0, batchSize)
+			}
+		case <-flush.C:
+			if len(batch) > 0 {
+				s.write(ctx, batch)
+				batch = make([]*auditlog.Event, 0, batchSize)
+			}
+		case <-retention.C:
+			s.deleteExpired(ctx)
+		}
+	}
+}
+
+func (s *Service) drain(batch []*auditlog.Event) []*auditlog.Event {
+	for {
+		select {
+		case event := <-s.events:
+			batch = append(batch, event)
+		default:
+			return batch
+		}
+	}
+}
+
+// write stores the events and sends them to every sink, a failing sink doesn't prevent the others from receiving them
+func (s *Service) write(ctx context.Context, events []*auditlog.Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	if err := s.store.Insert(ctx, events); err != nil {
+		s.metrics.sinkFailures.WithLabelValues(sinkDatabase).Inc()
+		s.log.Error("Failed to store audit events", "count", len(events), "error", err)
+	} else {
+		s.metrics.written.WithLabelValues(sinkDatabase).Add(float64(len(events)))
+	}
+
+	for _, sk := range s.sinks {
+		sinkCtx, cancel := context.WithTimeout(ctx, s.cfg.SinkTimeout)
+		err := sk.write(sinkCtx, events)
+		cancel()
+		if err != nil {
+			s.metrics.sinkFailures.WithLabelValues(sk.name()).Inc()
+			s.log.Error("Failed to send audit events", "sink", sk.name(), "count", len(events), "error", err)
+			continue
+		}
+		s.metrics.written.WithLabelValues(sk.name()).Add(float64(len(events)))
+	}
+}
+
+func (s *Service) deleteExpired(ctx context.Context) {
+	if s.cfg.Retention <= 0 {
+		return
+	}
+
+	deleted, err := s.store.DeleteBefore(ctx, s.now().Add(-s.cfg.Retention))
+	if err != nil {
+		s.log.Error("Failed to delete expired audit events", "error", err)
+		return
+	}
+	if deleted > 0 {
+		s.log.Debug("Deleted expired audit events", "count", deleted)
+	}
+}
+
+// loginHook records successful and failed logins
+func (s *Service) loginHook(ctx context.Context, id *authn.Identity, r *authn.Request, err error) {
+	event := &auditlog.Event{
+		Category: auditlog.CategoryAuth,
+		Action:   auditlog.ActionLogin,
+		Result:   auditlog.ResultSuccess,
+	}
+	if r.HTTPRequest != nil {
+		event.Method = r.HTTPRequest.Method
+		event.Path = r.HTTPRequest.URL.Path
+		event.ClientIP = web.RemoteAddr(r.HTTPRequest)
+		event.UserAgent = r.HTTPRequest.UserAgent()
+	}
+
+	if err != nil {
+		event.Result = auditlog.ResultFailure
+		event.ActorLogin = r.GetMeta(authn.MetaKeyUsername)
+		event.Message = err.Error()
+	}
+	if id != nil {
+		event.OrgID = id.GetOrgID()
+		event.ActorID = id.GetID()
+		event.ActorLogin = id.GetLogin()
+		event.Route = id.GetAuthenticatedBy()
+	}
+
+	s.Record(ctx, event)
+}
@@ -0,0 +1,264 @@
+package auditlogimpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	claims "github.com/grafana/authlib/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService_Record(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should drop events when the buffer is full", func(t *testing.T) {
+		s, _ := setupTestService(t, setting.AuditLogSettings{Enabled: true, BufferSize: 1})
+
+		s.Record(ctx, &auditlog.Event{Category: auditlog.CategoryDashboards})
+		s.Record(ctx, &auditlog.Event{Category: auditlog.CategoryDashboards})
+
+		assert.Len(t, s.events, 1)
+		assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.dropped))
+	})
+
+	t.Run("should not record events when disabled", func(t *testing.T) {
+		s, _ := setupTestService(t, setting.AuditLogSettings{Enabled: false, BufferSize: 1})
+
+		s.Record(ctx, &auditlog.Event{Category: auditlog.CategoryDashboards})
+
+		assert.Empty(t, s.events)
+	})
+}
+
+func TestService_Run(t *testing.T) {
+	t.Run("should write buffered events to the store and sinks on shutdown", func(t *testing.T) {
+		s, store := setupTestService(t, setting.AuditLogSettings{Enabled: true, BufferSize: 10})
+		failing := &fakeSink{err: errors.New("unavailable")}
+		working := &fakeSink{}
+		s.sinks = []sink{failing, working}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		s.Record(ctx, &auditlog.Event{Category: auditlog.CategoryDashboards, Action: auditlog.ActionCreate})
+		s.Record(ctx, &auditlog.Event{Category: auditlog.CategoryDashboards, Action: auditlog.ActionDelete})
+
+		require.ErrorIs(t, s.Run(ctx), context.Canceled)
+
+		assert.Len(t, store.events, 2)
+		assert.Len(t, working.events, 2)
+		assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.sinkFailures.WithLabelValues("fake")))
+		assert.Equal(t, 2.0, testutil.ToFloat64(s.metrics.written.WithLabelValues(sinkDatabase)))
+	})
+
+	t.Run("should delete events older than the retention", func(t *testing.T) {
+		s, store := setupTestService(t, setting.AuditLogSettings{Enabled: true, BufferSize: 10, Retention: 24 * time.Hour})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.ErrorIs(t, s.Run(ctx), context.Canceled)
+
+		assert.Equal(t, s.now().Add(-24*time.Hour), store.deletedBefore)
+	})
+}
+
+func TestService_Search(t *testing.T) {
+	s, _ := setupTestService(t, setting.AuditLogSettings{Enabled: true, BufferSize: 10})
+
+	_, err := s.Search(context.Background(), &auditlog.SearchQuery{From: time.Unix(100, 0), To: time.Unix(10, 0)})
+	assert.ErrorIs(t, err, auditlog.ErrInvalidSearch)
+}
+
+func TestService_loginHook(t *testing.T) {
+	ctx := context.Background()
+	req := &authn.Request{HTTPRequest: httptest.NewRequest(http.MethodPost, "/login", nil)}
+
+	t.Run("should record successful logins", func(t *testing.T) {
+		s, _ := setupTestService(t, setting.AuditLogSettings{Enabled: true, BufferSize: 10})
+
+		id := &authn.Identity{ID: "1", Type: claims.TypeUser, OrgID: 2, Login: "admin", AuthenticatedBy: "password"}
+		s.loginHook(ctx, id, req, nil)
+
+		require.Len(t, s.events, 1)
+		event := <-s.events
+		assert.Equal(t, auditlog.CategoryAuth, event.Category)
+		assert.Equal(t, auditlog.ResultSuccess, event.Result)
+		assert.Equal(t, "admin", event.ActorLogin)
+		assert.Equal(t, "user:1", event.ActorID)
+		assert.Equal(t, int64(2), event.OrgID)
+		assert.Equal(t, "password", event.Route)
+	})
+
+	t.Run("should record failed logins with the attempted username", func(t *testing.T) {
+		s, _ := setupTestService(t, setting.AuditLogSettings{Enabled: true, BufferSize: 10})
+
+		failed := &authn.Request{HTTPRequest: req.HTTPRequest}
+		failed.SetMeta(authn.MetaKeyUsername, "mallory")
+		s.loginHook(ctx, nil, failed, errors.New("invalid username or password"))
+
+		require.Len(t, s.events, 1)
+		event := <-s.events
+		assert.Equal(t, auditlog.ResultFailure, event.Result)
+		assert.Equal(t, "mallory", event.ActorLogin)
+		assert.Equal(t, "invalid username or password", event.Message)
+	})
+}
+
+func TestSinks(t *testing.T) {
+	ctx := context.Background()
+	events := []*auditlog.Event{
+		{Timestamp: time.Unix(10, 0), Category: auditlog.CategoryDashboards, Action: auditlog.ActionCreate, ActorLogin: "admin"},
+		{Timestamp: time.Unix(20, 0), Category: auditlog.CategoryAuth, Action: auditlog.ActionLogin, ActorLogin: "admin"},
+	}
+
+	t.Run("file sink should append JSON lines", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.log")
+		s := &fileSink{path: path}
+
+		require.NoError(t, s.write(ctx, events[:1]))
+		require.NoError(t, s.write(ctx, events[1:]))
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+		require.Len(t, lines, 2)
+
+		var event auditlog.Event
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &event))
+		assert.Equal(t, auditlog.CategoryAuth, event.Category)
+	})
+
+	t.Run("loki sink should push a stream per category", func(t *testing.T) {
+		var push lokiPushRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/loki/api/v1/push", r.URL.Path)
+			assert.Equal(t, "tenant", r.Header.Get("X-Scope-OrgID"))
+			user, password, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "user", user)
+			assert.Equal(t, "secret", password)
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&push))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		t.Cleanup(server.Close)
+
+		cfg := setting.NewCfg()
+		cfg.AuditLog = setting.AuditLogSettings{Sinks: []string{sinkLoki}, SinkTimeout: time.Second, LokiURL: server.URL + "/", LokiUser: "user", LokiPassword: "secret", LokiTenantID: "tenant"}
+		sinks, err := newSinks(cfg)
+		require.NoError(t, err)
+		require.Len(t, sinks, 1)
+
+		require.NoError(t, sinks[0].write(ctx, events))
+		require.Len(t, push.Streams, 2)
+		assert.Equal(t, auditlog.CategoryDashboards, push.Streams[0].Stream["category"])
+		assert.Equal(t, "10000000000", push.Streams[0].Values[0][0])
+	})
+
+	t.Run("webhook sink should sign the payload", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, sign("secret", body), r.Header.Get(signatureHeader))
+
+			var payload webhookPayload
+			assert.NoError(t, json.Unmarshal(body, &payload))
+			assert.Len(t, payload.Events, 2)
+		}))
+		t.Cleanup(server.Close)
+
+		s := &webhookSink{client: server.Client(), url: server.URL, secret: "secret"}
+		require.NoError(t, s.write(ctx, events))
+	})
+
+	t.Run("webhook sink should fail on error responses", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		t.Cleanup(server.Close)
+
+		s := &webhookSink{client: server.Client(), url: server.URL}
+		require.Error(t, s.write(ctx, events))
+	})
+
+	t.Run("should require the url of http sinks", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.AuditLog = setting.AuditLogSettings{Sinks: []string{sinkWebhook}}
+		_, err := newSinks(cfg)
+		require.Error(t, err)
+	})
+}
+
+func setupTestService(t *testing.T, settings setting.AuditLogSettings) (*Service, *fakeStore) {
+	t.Helper()
+
+	if settings.SinkTimeout == 0 {
+		settings.SinkTimeout = time.Second
+	}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeStore{}
+	return &Service{
+		cfg:     settings,
+		store:   store,
+		events:  make(chan *auditlog.Event, settings.BufferSize),
+		metrics: newMetrics(nil),
+		log:     log.NewNopLogger(),
+		tracer:  tracing.InitializeTracerForTest(),
+		now:     func() time.Time { return now },
+	}, store
+}
+
+type fakeStore struct {
+	events        []*auditlog.Event
+	deletedBefore time.Time
+}
+
+func (f *fakeStore) Insert(_ context.Context, events []*auditlog.Event) error {
+	f.events = append(f.events, events...)
+	return nil
+}
+
+func (f *fakeStore) Search(_ context.Context, _ *auditlog.SearchQuery) (*auditlog.SearchResult, error) {
+	return &auditlog.SearchResult{Events: f.events, TotalCount: int64(len(f.events))}, nil
+}
+
+func (f *fakeStore) DeleteBefore(_ context.Context, before time.Time) (int64, error) {
+	f.deletedBefore = before
+	return 0, nil
+}
+
+type fakeSink struct {
+	mu     sync.Mutex
+	events []*auditlog.Event
+	err    error
+}
+
+func (f *fakeSink) name() string {
+	return "fake"
+}
+
+func (f *fakeSink) write(_ context.Context, events []*auditlog.Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.events = append(f.events, events...)
+	return nil
+}
@@ -0,0 +1,221 @@
+package auditlogimpl
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	sinkDatabase = "database"
+	sinkFile     = "file"
+	sinkLoki     = "loki"
+	sinkWebhook  = "webhook"
+
+	signatureHeader = "X-Grafana-Audit-Signature"
+)
+
+// sink is a destination of audit events in addition to the database
+type sink interface {
+	name() string
+	write(ctx context.Context, events []*auditlog.Event) error
+}
+
+func newSinks(cfg *setting.Cfg) ([]sink, error) {
+	settings := cfg.AuditLog
+	client := &http.Client{Timeout: settings.SinkTimeout}
+
+	sinks := make([]sink, 0, len(settings.Sinks))
+	for _, name := range settings.Sinks {
+		switch name {
+		case sinkFile:
+			path := settings.FilePath
+			if path == "" {
+				path = filepath.Join(cfg.LogsPath, "audit.log")
+			}
+			sinks = append(sinks, &fileSink{path: path})
+		case sinkLoki:
+			if settings.LokiURL == "" {
+				return nil, fmt.Errorf("the loki audit sink requires url in [audit.loki]")
+			}
+			sinks = append(sinks, &lokiSink{
+				client:   client,
+				url:      strings.TrimSuffix(settings.LokiURL, "/") + "/loki/api/v1/push",
+				user:     settings.LokiUser,
+				password: settings.LokiPassword,
+				tenantID: settings.LokiTenantID,
+			})
+		case sinkWebhook:
+			if settings.WebhookURL == "" {
+				return nil, fmt.Errorf("the webhook audit sink requires url in [audit.webhook]")
+			}
+			sinks = append(sinks, &webhookSink{client: client, url: settings.WebhookURL, secret: settings.WebhookSecret})
+		default:
+			return nil, fmt.Errorf("unknown audit sink %q", name)
+		}
+	}
+	return sinks, nil
+}
+
+// fileSink appends the events to a file as JSON lines
+type fileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+func (s *fileSink) name() string {
+	return sinkFile
+}
+
+func (s *fileSink) write(_ context.Context, events []*auditlog.Event) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the file is opened for every batch so it can be rotated by external tools
+	// #nosec G304 -- the path comes from the configuration
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// lokiSink pushes the events to Loki, in a stream per category
+type lokiSink struct {
+	client   *http.Client
+	url      string
+	user     string
+	password string
+	tenantID string
+}
+
+type lokiPushRequest struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (s *lokiSink) name() string {
+	return sinkLoki
+}
+
+func (s *lokiSink) write(ctx context.Context, events []*auditlog.Event) error {
+	streams := map[string]*lokiStream{}
+	order := make([]string, 0)
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		stream, ok := streams[event.Category]
+		if !ok {
+			stream = &lokiStream{Stream: map[string]string{"source": "grafana", "type": "audit", "category": event.Category}}
+			streams[event.Category] = stream
+			order = append(order, event.Category)
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(event.Timestamp.UnixNano(), 10), string(line)})
+	}
+
+	push := lokiPushRequest{Streams: make([]lokiStream, 0, len(order))}
+	for _, category := range order {
+		push.Streams = append(push.Streams, *streams[category])
+	}
+	body, err := json.Marshal(push)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.user != "" {
+		req.SetBasicAuth(s.user, s.password)
+	}
+	if s.tenantID != "" {
+		req.Header.Set("X-Scope-OrgID", s.tenantID)
+	}
+	return send(s.client, req)
+}
+
+// webhookSink posts the events as JSON, signed with HMAC-SHA256 when a secret is configured
+type webhookSink struct {
+	client *http.Client
+	url    string
+	secret string
+}
+
+type webhookPayload struct {
+	Events []*auditlog.Event `json:"events"`
+}
+
+func (s *webhookSink) name() string {
+	return sinkWebhook
+}
+
+func (s *webhookSink) write(ctx context.Context, events []*auditlog.Event) error {
+	body, err := json.Marshal(webhookPayload{Events: events})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.secret != "" {
+		req.Header.Set(signatureHeader, sign(s.secret, body))
+	}
+	return send(s.client, req)
+}
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func send(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, req.URL.Host)
+	}
+	return nil
+}
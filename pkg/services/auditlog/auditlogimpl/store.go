@@ -0,0 +1,178 @@
+package auditlogimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/util/xorm"
+)
+
+const (
+	defaultPerPage = 100
+	maxPerPage     = 1000
+)
+
+type auditLogEntry struct {
+	ID                int64     `xorm:"pk autoincr 'id'"`
+	Created           time.Time `xorm:"created"`
+	OrgID             int64     `xorm:"org_id"`
+	ActorID           string    `xorm:"actor_id"`
+	ActorLogin        string    `xorm:"actor_login"`
+	ImpersonatorLogin string    `xorm:"impersonator_login"`
+	Category          string    `xorm:"category"`
+	Action            string    `xorm:"action"`
+	Route             string    `xorm:"route"`
+	Method            string    `xorm:"method"`
+	Path              string    `xorm:"path"`
+	StatusCode        int       `xorm:"status_code"`
+	Result            string    `xorm:"result"`
+	ClientIP          string    `xorm:"client_ip"`
+	UserAgent         string    `xorm:"user_agent"`
+	Message           string    `xorm:"message"`
+}
+
+func (auditLogEntry) TableName() string {
+	return "audit_log"
+}
+
+func newEntry(event *auditlog.Event) *auditLogEntry {
+	return &auditLogEntry{
+		Created:           event.Timestamp,
+		OrgID:             event.OrgID,
+		ActorID:           event.ActorID,
+		ActorLogin:        event.ActorLogin,
+		ImpersonatorLogin: event.ImpersonatorLogin,
+		Category:          event.Category,
+		Action:            event.Action,
+		Route:             event.Route,
+		Method:            event.Method,
+		Path:              event.Path,
+		StatusCode:        event.StatusCode,
+		Result:            event.Result,
+		ClientIP:          event.ClientIP,
+		UserAgent:         event.UserAgent,
+		Message:           event.Message,
+	}
+}
+
+func (e *auditLogEntry) toEvent() *auditlog.Event {
+	return &auditlog.Event{
+		ID:                e.ID,
+		Timestamp:         e.Created,
+		OrgID:             e.OrgID,
+		ActorID:           e.ActorID,
+		ActorLogin:        e.ActorLogin,
+		ImpersonatorLogin: e.ImpersonatorLogin,
+		Category:          e.Category,
+		Action:            e.Action,
+		Route:             e.Route,
+		Method:            e.Method,
+		Path:              e.Path,
+		StatusCode:        e.StatusCode,
+		Result:            e.Result,
+		ClientIP:          e.ClientIP,
+		UserAgent:         e.UserAgent,
+		Message:           e.Message,
+	}
+}
+
+type store interface {
+	Insert(ctx context.Context, events []*auditlog.Event) error
+	Search(ctx context.Context, query *auditlog.SearchQuery) (*auditlog.SearchResult, error)
+	// DeleteBefore deletes the events recorded before the time and returns how many were deleted
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (s *xormStore) Insert(ctx context.Context, events []*auditlog.Event) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for _, event := range events {
+			entry := newEntry(event)
+			if _, err := sess.Insert(entry); err != nil {
+				return err
+			}
+			event.ID = entry.ID
+		}
+		return nil
+	})
+}
+
+func (s *xormStore) Search(ctx context.Context, query *auditlog.SearchQuery) (*auditlog.SearchResult, error) {
+	perPage := query.Limit
+	if perPage <= 0 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+	page := query.Page
+	if page <= 0 {
+		page = 1
+	}
+
+	result := &auditlog.SearchResult{Events: make([]*auditlog.Event, 0), Page: page, PerPage: perPage}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		filter := func() *xorm.Session {
+			q := sess.Table("audit_log")
+			if !query.From.IsZero() {
+				q = q.Where("created >= ?", query.From)
+			}
+			if !query.To.IsZero() {
+				q = q.Where("created <= ?", query.To)
+			}
+			if query.OrgID != 0 {
+				q = q.Where("org_id = ?", query.OrgID)
+			}
+			if query.Actor != "" {
+				q = q.Where("(actor_login = ? OR actor_id = ?)", query.Actor, query.Actor)
+			}
+			if query.Category != "" {
+				q = q.Where("category = ?", query.Category)
+			}
+			if query.Action != "" {
+				q = q.Where("action = ?", query.Action)
+			}
+			if query.Result != "" {
+				q = q.Where("result = ?", query.Result)
+			}
+			return q
+		}
+
+		count, err := filter().Count(&auditLogEntry{})
+		if err != nil {
+			return err
+		}
+		result.TotalCount = count
+
+		entries := make([]*auditLogEntry, 0)
+		if err := filter().Desc("created", "id").Limit(perPage, (page-1)*perPage).Find(&entries); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			result.Events = append(result.Events, entry.toEvent())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *xormStore) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM audit_log WHERE created < ?", before)
+		if err != nil {
+			return err
+		}
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	return deleted, err
+}
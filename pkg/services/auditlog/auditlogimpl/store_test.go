@@ -0,0 +1,94 @@
+package auditlogimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	store := &xormStore{db: db.InitTestDB(t)}
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	events := []*auditlog.Event{
+		{Timestamp: start, OrgID: 1, ActorID: "user:1", ActorLogin: "admin", Category: auditlog.CategoryAuth,
+			Action: "login", Result: auditlog.ResultSuccess, StatusCode: 200, ClientIP: "10.0.0.1"},
+		{Timestamp: start.Add(time.Minute), OrgID: 1, ActorID: "user:2", ActorLogin: "editor", Category: auditlog.CategoryDashboards,
+			Action: "save", Result: auditlog.ResultFailure, StatusCode: 403},
+		{Timestamp: start.Add(2 * time.Minute), OrgID: 2, ActorID: "user:1", ActorLogin: "admin", ImpersonatorLogin: "support",
+			Category: auditlog.CategoryDashboards, Action: "delete", Result: auditlog.ResultSuccess, StatusCode: 200},
+	}
+	require.NoError(t, store.Insert(ctx, events))
+	for _, event := range events {
+		assert.NotZero(t, event.ID, "the ids are set on insert")
+	}
+
+	t.Run("should return the newest events first", func(t *testing.T) {
+		result, err := store.Search(ctx, &auditlog.SearchQuery{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), result.TotalCount)
+		require.Len(t, result.Events, 3)
+		assert.Equal(t, "delete", result.Events[0].Action)
+		assert.Equal(t, "support", result.Events[0].ImpersonatorLogin)
+		assert.Equal(t, "10.0.0.1", result.Events[2].ClientIP)
+	})
+
+	t.Run("should filter the events", func(t *testing.T) {
+		for _, tc := range []struct {
+			desc    string
+			query   auditlog.SearchQuery
+			actions []string
+		}{
+			{"by org", auditlog.SearchQuery{OrgID: 1}, []string{"save", "login"}},
+			{"by actor login", auditlog.SearchQuery{Actor: "admin"}, []string{"delete", "login"}},
+			{"by actor id", auditlog.SearchQuery{Actor: "user:2"}, []string{"save"}},
+			{"by category", auditlog.SearchQuery{Category: auditlog.CategoryDashboards}, []string{"delete", "save"}},
+			{"by result", auditlog.SearchQuery{Result: auditlog.ResultFailure}, []string{"save"}},
+			{"by time range", auditlog.SearchQuery{From: start.Add(time.Minute), To: start.Add(time.Minute)}, []string{"save"}},
+		} {
+			t.Run(tc.desc, func(t *testing.T) {
+				result, err := store.Search(ctx, &tc.query)
+				require.NoError(t, err)
+				actions := make([]string, 0, len(result.Events))
+				for _, event := range result.Events {
+					actions = append(actions, event.Action)
+				}
+				assert.Equal(t, tc.actions, actions)
+				assert.Equal(t, int64(len(tc.actions)), result.TotalCount)
+			})
+		}
+	})
+
+	t.Run("should paginate the events", func(t *testing.T) {
+		result, err := store.Search(ctx, &auditlog.SearchQuery{Page: 2, Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), result.TotalCount)
+		require.Len(t, result.Events, 1)
+		assert.Equal(t, "login", result.Events[0].Action)
+	})
+
+	t.Run("should delete the old events", func(t *testing.T) {
+		deleted, err := store.DeleteBefore(ctx, start.Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		result, err := store.Search(ctx, &auditlog.SearchQuery{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), result.TotalCount)
+	})
+}
@@ -45,6 +45,24 @@ func (_m *MockUserAuthTokenService) ActiveTokenCount(ctx context.Context, userID
 	return r0, r1
 }
 
+// BatchRevokeAllUserTokens provides a mock function with given fields: ctx, userIDs
+func (_m *MockUserAuthTokenService) BatchRevokeAllUserTokens(ctx context.Context, userIDs []int64) error {
+	ret := _m.Called(ctx, userIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BatchRevokeAllUserTokens")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) error); ok {
+		r0 = rf(ctx, userIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateToken provides a mock function with given fields: ctx, cmd
 func (_m *MockUserAuthTokenService) CreateToken(ctx context.Context, cmd *auth.CreateTokenCommand) (*usertoken.UserToken, error) {
 	ret := _m.Called(ctx, cmd)
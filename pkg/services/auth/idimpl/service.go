@@ -38,7 +38,7 @@ func ProvideService(
 ) *Service {
 	s := &Service{
 		cfg: cfg, logger: log.New("id-service"),
-		signer: signer, cache: cache,
+		signer: signer, cache: remotecache.WithUsage(cache, "id token"),
 		metrics:  newMetrics(reg),
 		nsMapper: request.GetNamespaceMapper(cfg),
 		tracer:   tracer,
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/go-jose/go-jose/v3/jwt"
 
@@ -15,6 +16,8 @@ import (
 
 const ServiceName = "AuthService"
 
+var ErrUnknownIssuer = errors.New("no key set is configured for the token issuer")
+
 func ProvideService(cfg *setting.Cfg, remoteCache *remotecache.RemoteCache) (*AuthService, error) {
 	s := newService(cfg, remoteCache)
 	if err := s.init(); err != nil {
@@ -43,6 +46,10 @@ func (s *AuthService) init() error {
 	if err := s.initKeySet(); err != nil {
 		return err
 	}
+	// the mapping is used by the JWT client, it is loaded here to refuse to start with an invalid file
+	if _, err := LoadClaimsMapping(s.Cfg.JWTAuth.ClaimsMappingFile); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -52,6 +59,7 @@ type AuthService struct {
 	RemoteCache *remotecache.RemoteCache
 
 	keySet           keySet
+	issuerKeySets    map[string]keySet
 	log              log.Logger
 	expect           map[string]any
 	expectRegistered jwt.Expected
@@ -74,7 +82,12 @@ func (s *AuthService) Verify(ctx context.Context, strToken string) (map[string]a
 		return nil, err
 	}
 
-	keys, err := s.keySet.Key(ctx, token.Headers[0].KeyID)
+	ks, err := s.keySetFor(token)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := ks.Key(ctx, token.Headers[0].KeyID)
 	if err != nil {
 		return nil, err
 	}
@@ -103,6 +116,68 @@ func (s *AuthService) Verify(ctx context.Context, strToken string) (map[string]a
 	return claims, nil
 }
 
+// keySetFor returns the key set of the token issuer, or the default key set
+// when no key set is configured for the issuer. The issuer is read before the
+// signature is verified, the signature must then match one of the keys of its key set.
+func (s *AuthService) keySetFor(token *jwt.JSONWebToken) (keySet, error) {
+	if len(s.issuerKeySets) > 0 {
+		var claims jwt.Claims
+		if err := token.UnsafeClaimsWithoutVerification(&claims); err != nil {
+			return nil, err
+		}
+		if ks, ok := s.issuerKeySets[claims.Issuer]; ok {
+			return ks, nil
+		}
+	}
+
+	if s.keySet == nil {
+		return nil, ErrUnknownIssuer
+	}
+	return s.keySet, nil
+}
+
+// IsDisabled returns true when there is no remote key set to refresh in the background
+func (s *AuthService) IsDisabled() bool {
+	return !s.Cfg.JWTAuth.Enabled || s.Cfg.JWTAuth.JWKSetRefreshInterval <= 0 || len(s.remoteKeySets()) == 0
+}
+
+// Run refreshes the remote key sets periodically so rotated keys are known
+// before the cached key sets expire
+func (s *AuthService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.Cfg.JWTAuth.JWKSetRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.refreshKeySets(ctx)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *AuthService) refreshKeySets(ctx context.Context) {
+	for _, ks := range s.remoteKeySets() {
+		if err := ks.refresh(ctx); err != nil {
+			s.log.Warn("Failed to refresh key set", "url", ks.url, "err", err)
+		}
+	}
+}
+
+func (s *AuthService) remoteKeySets() []*keySetHTTP {
+	remote := []*keySetHTTP{}
+	if ks, ok := s.keySet.(*keySetHTTP); ok {
+		remote = append(remote, ks)
+	}
+	for _, ks := range s.issuerKeySets {
+		if ks, ok := ks.(*keySetHTTP); ok {
+			remote = append(remote, ks)
+		}
+	}
+	return remote
+}
+
 // HasSubClaim checks if the provided JWT token contains a non-empty "sub" claim.
 // Returns true if it contains, otherwise returns false.
 func HasSubClaim(jwtToken string) bool {
@@ -169,6 +169,69 @@ func TestIntegrationVerifyUsingJWKSetURL(t *testing.T) {
 	})
 }
 
+func TestIntegrationVerifyUsingIssuerKeySets(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	const issuer = "https://issuer.example.com"
+
+	configure := func(t *testing.T, cfg *setting.Cfg) {
+		t.Helper()
+
+		cfg.JWTAuth.KeySets = []setting.AuthJWTKeySetSettings{{
+			Name:       "example",
+			Issuer:     issuer,
+			JWKSetFile: writeJWKSetFile(t, jose.JSONWebKeySet{Keys: jwksPublic.Keys[1:]}),
+		}}
+	}
+
+	scenario(t, "verifies a token with the keys of its issuer", func(t *testing.T, sc scenarioContext) {
+		token := sign(t, &jwKeys[1], jwt.Claims{Subject: subject, Issuer: issuer}, nil)
+		verifiedClaims, err := sc.authJWTSvc.Verify(sc.ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, verifiedClaims["sub"], subject)
+	}, configure)
+
+	scenario(t, "rejects a token signed with a key of another issuer", func(t *testing.T, sc scenarioContext) {
+		token := sign(t, &jwKeys[0], jwt.Claims{Subject: subject, Issuer: issuer}, nil)
+		_, err := sc.authJWTSvc.Verify(sc.ctx, token)
+		require.Error(t, err)
+	}, configure)
+
+	scenario(t, "rejects a token of an unknown issuer without default key set", func(t *testing.T, sc scenarioContext) {
+		token := sign(t, &jwKeys[1], jwt.Claims{Subject: subject, Issuer: "https://other.example.com"}, nil)
+		_, err := sc.authJWTSvc.Verify(sc.ctx, token)
+		require.ErrorIs(t, err, ErrUnknownIssuer)
+	}, configure)
+
+	scenario(t, "verifies a token of an unknown issuer with the default key set", func(t *testing.T, sc scenarioContext) {
+		token := sign(t, rsaKeys[0], jwt.Claims{Subject: subject, Issuer: "https://other.example.com"}, nil)
+		verifiedClaims, err := sc.authJWTSvc.Verify(sc.ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, verifiedClaims["sub"], subject)
+	}, configure, configurePKIXPublicKeyFile)
+
+	t.Run("should refuse to start with an invalid key set", func(t *testing.T) {
+		_, err := initAuthService(t, func(t *testing.T, cfg *setting.Cfg) {
+			cfg.JWTAuth.KeySets = []setting.AuthJWTKeySetSettings{{Name: "example", JWKSetURL: "https://example.com/.well-known/jwks.json"}}
+		})
+		require.ErrorIs(t, err, ErrKeySetIssuerMissing)
+
+		_, err = initAuthService(t, func(t *testing.T, cfg *setting.Cfg) {
+			cfg.JWTAuth.KeySets = []setting.AuthJWTKeySetSettings{
+				{Name: "a", Issuer: issuer, JWKSetURL: "https://a.example.com/.well-known/jwks.json"},
+				{Name: "b", Issuer: issuer, JWKSetURL: "https://b.example.com/.well-known/jwks.json"},
+			}
+		})
+		require.ErrorIs(t, err, ErrKeySetIssuerDuplicated)
+
+		_, err = initAuthService(t, func(t *testing.T, cfg *setting.Cfg) {
+			cfg.JWTAuth.KeySets = []setting.AuthJWTKeySetSettings{{Name: "example", Issuer: issuer}}
+		})
+		require.ErrorIs(t, err, ErrKeySetIsNotConfigured)
+	})
+}
+
 func TestIntegrationCachingJWKHTTPResponse(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test in short mode")
@@ -465,3 +528,20 @@ func configurePKIXPublicKeyFile(t *testing.T, cfg *setting.Cfg) {
 
 	cfg.JWTAuth.KeyFile = file.Name()
 }
+
+func writeJWKSetFile(t *testing.T, jwks jose.JSONWebKeySet) string {
+	t.Helper()
+
+	file, err := os.CreateTemp(os.TempDir(), "jwk-*.json")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := os.Remove(file.Name()); err != nil {
+			panic(err)
+		}
+	})
+
+	require.NoError(t, json.NewEncoder(file).Encode(jwks))
+	require.NoError(t, file.Close())
+
+	return file.Name()
+}
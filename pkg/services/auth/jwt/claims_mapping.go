@@ -0,0 +1,161 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jmespath-community/go-jmespath"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+var ErrInvalidClaimsMapping = errors.New("invalid claims mapping")
+
+// ClaimsMapping maps the claims of a token to org roles, team memberships and RBAC roles.
+// It is loaded from a YAML file such as:
+//
+//	rules:
+//	  - when: "contains(groups, 'sre')"
+//	    org_id: 1
+//	    role: Editor
+//	    teams: ["SRE"]
+//	    roles: ["fixed:alerting:writer"]
+type ClaimsMapping struct {
+	Rules []*ClaimsMappingRule `yaml:"rules"`
+}
+
+// ClaimsMappingRule applies to a token when its JMESPath expression evaluates to a truthy value
+type ClaimsMappingRule struct {
+	When  string       `yaml:"when"`
+	OrgID int64        `yaml:"org_id"`
+	Role  org.RoleType `yaml:"role"`
+	Teams []string     `yaml:"teams"`
+	Roles []string     `yaml:"roles"`
+
+	when jmespath.JMESPath
+}
+
+// ClaimsMappingResult is the outcome of the evaluation of a mapping.
+// Teams and Roles list every team and RBAC role the mapping manages, per org:
+// true when the user should have it, false when it should be removed.
+type ClaimsMappingResult struct {
+	OrgRoles map[int64]org.RoleType    `json:"orgRoles"`
+	Teams    map[int64]map[string]bool `json:"teams"`
+	Roles    map[int64]map[string]bool `json:"roles"`
+}
+
+// LoadClaimsMapping reads and compiles the mapping file, it returns nil if path is empty
+func LoadClaimsMapping(path string) (*ClaimsMapping, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `path` comes from grafana configuration file
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseClaimsMapping(data)
+}
+
+// ParseClaimsMapping parses and compiles a YAML claims mapping
+func ParseClaimsMapping(data []byte) (*ClaimsMapping, error) {
+	var mapping ClaimsMapping
+	if err := yaml.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidClaimsMapping, err)
+	}
+
+	for i, rule := range mapping.Rules {
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("%w: rule %d: %v", ErrInvalidClaimsMapping, i, err)
+		}
+	}
+
+	return &mapping, nil
+}
+
+func (r *ClaimsMappingRule) compile() error {
+	if r.When == "" {
+		return errors.New("when is required")
+	}
+	if r.OrgID <= 0 {
+		return errors.New("org_id is required")
+	}
+	if r.Role != "" && !r.Role.IsValid() {
+		return fmt.Errorf("invalid role %q", r.Role)
+	}
+	if r.Role == "" && len(r.Teams) == 0 && len(r.Roles) == 0 {
+		return errors.New("one of role, teams or roles is required")
+	}
+
+	expr, err := jmespath.Compile(r.When)
+	if err != nil {
+		return fmt.Errorf("failed to compile %q: %v", r.When, err)
+	}
+	r.when = expr
+
+	return nil
+}
+
+// Evaluate applies the rules to the claims of a verified token. When several
+// rules grant a role in the same org the highest role wins.
+func (m *ClaimsMapping) Evaluate(claims map[string]any) (*ClaimsMappingResult, error) {
+	result := &ClaimsMappingResult{
+		OrgRoles: map[int64]org.RoleType{},
+		Teams:    map[int64]map[string]bool{},
+		Roles:    map[int64]map[string]bool{},
+	}
+
+	for _, rule := range m.Rules {
+		value, err := rule.when.Search(claims)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate %q: %w", rule.When, err)
+		}
+		matched := isTruthy(value)
+
+		if matched && rule.Role != "" {
+			if current, ok := result.OrgRoles[rule.OrgID]; !ok || !current.Includes(rule.Role) {
+				result.OrgRoles[rule.OrgID] = rule.Role
+			}
+		}
+		addManaged(result.Teams, rule.OrgID, rule.Teams, matched)
+		addManaged(result.Roles, rule.OrgID, rule.Roles, matched)
+	}
+
+	return result, nil
+}
+
+func addManaged(managed map[int64]map[string]bool, orgID int64, names []string, matched bool) {
+	if len(names) == 0 {
+		return
+	}
+	if managed[orgID] == nil {
+		managed[orgID] = map[string]bool{}
+	}
+	for _, name := range names {
+		managed[orgID][name] = managed[orgID][name] || matched
+	}
+}
+
+// isTruthy follows the JMESPath definition of truthiness: false, null and
+// empty strings, arrays and objects are false, everything else is true
+func isTruthy(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case []any:
+		return len(v) > 0
+	case map[string]any:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
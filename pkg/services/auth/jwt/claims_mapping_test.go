@@ -0,0 +1,86 @@
+package jwt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+func TestParseClaimsMapping(t *testing.T) {
+	testCases := []struct {
+		name string
+		data string
+	}{
+		{name: "invalid yaml", data: "rules: {"},
+		{name: "missing expression", data: "rules:\n  - org_id: 1\n    role: Viewer"},
+		{name: "missing org", data: "rules:\n  - when: admin\n    role: Viewer"},
+		{name: "invalid role", data: "rules:\n  - when: admin\n    org_id: 1\n    role: Owner"},
+		{name: "nothing to map", data: "rules:\n  - when: admin\n    org_id: 1"},
+		{name: "invalid expression", data: "rules:\n  - when: \"contains(groups\"\n    org_id: 1\n    role: Viewer"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseClaimsMapping([]byte(tc.data))
+			assert.ErrorIs(t, err, ErrInvalidClaimsMapping)
+		})
+	}
+}
+
+func TestClaimsMapping_Evaluate(t *testing.T) {
+	mapping, err := ParseClaimsMapping([]byte(`
+rules:
+  - when: "contains(groups, 'dev')"
+    org_id: 1
+    role: Editor
+    teams: ["Developers"]
+  - when: "contains(groups, 'admin')"
+    org_id: 1
+    role: Admin
+    roles: ["fixed:users:writer"]
+  - when: "contains(groups, 'sre')"
+    org_id: 2
+    role: Viewer
+    teams: ["SRE", "On call"]
+    roles: ["fixed:alerting:writer"]
+  - when: "department == 'support'"
+    org_id: 2
+    teams: ["On call"]
+`))
+	require.NoError(t, err)
+
+	t.Run("should keep the highest role and list the managed teams and roles", func(t *testing.T) {
+		result, err := mapping.Evaluate(map[string]any{"groups": []any{"admin", "dev"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[int64]org.RoleType{1: org.RoleAdmin}, result.OrgRoles)
+		assert.Equal(t, map[int64]map[string]bool{
+			1: {"Developers": true},
+			2: {"SRE": false, "On call": false},
+		}, result.Teams)
+		assert.Equal(t, map[int64]map[string]bool{
+			1: {"fixed:users:writer": true},
+			2: {"fixed:alerting:writer": false},
+		}, result.Roles)
+	})
+
+	t.Run("should add a team matched by any of the rules", func(t *testing.T) {
+		result, err := mapping.Evaluate(map[string]any{"groups": []any{}, "department": "support"})
+		require.NoError(t, err)
+
+		assert.Empty(t, result.OrgRoles)
+		assert.Equal(t, map[string]bool{"SRE": false, "On call": true}, result.Teams[2])
+	})
+}
+
+func TestIsTruthy(t *testing.T) {
+	for _, value := range []any{true, "a", 0.0, []any{1}, map[string]any{"a": 1}} {
+		assert.True(t, isTruthy(value), "%v", value)
+	}
+	for _, value := range []any{nil, false, "", []any{}, map[string]any{}} {
+		assert.False(t, isTruthy(value), "%v", value)
+	}
+}
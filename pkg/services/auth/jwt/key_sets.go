@@ -16,6 +16,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	jose "github.com/go-jose/go-jose/v3"
@@ -29,6 +30,12 @@ var ErrFailedToParsePemFile = errors.New("failed to parse pem-encoded file")
 var ErrKeySetIsNotConfigured = errors.New("key set for jwt verification is not configured")
 var ErrKeySetConfigurationAmbiguous = errors.New("key set configuration is ambiguous: you should set either key_file, jwk_set_file or jwk_set_url")
 var ErrJWTSetURLMustHaveHTTPSScheme = errors.New("jwt_set_url must have https scheme")
+var ErrKeySetIssuerMissing = errors.New("key set must set an issuer")
+var ErrKeySetIssuerDuplicated = errors.New("issuer is used by more than one key set")
+
+// forcedRefreshInterval is the minimum time between two refreshes of a remote key set
+// triggered by tokens signed with an unknown key
+const forcedRefreshInterval = time.Minute
 
 type keySet interface {
 	Key(ctx context.Context, kid string) ([]jose.JSONWebKey, error)
@@ -45,17 +52,26 @@ type keySetHTTP struct {
 	cache           *remotecache.RemoteCache
 	cacheKey        string
 	cacheExpiration time.Duration
+	// refreshOnUnknownKey fetches the key set again when a token is signed with a key it doesn't contain
+	refreshOnUnknownKey bool
+	// maxStaleAge is how long the last fetched key set is used when the endpoint can't be reached
+	maxStaleAge time.Duration
+
+	mu            sync.Mutex
+	lastKeySet    *keySetJWKS
+	lastFetchedAt time.Time
+	lastForcedAt  time.Time
 }
 
-func (s *AuthService) checkKeySetConfiguration() error {
+func checkKeySetConfiguration(ksCfg setting.AuthJWTKeySetSettings) error {
 	var count int
-	if s.Cfg.JWTAuth.KeyFile != "" {
+	if ksCfg.KeyFile != "" {
 		count++
 	}
-	if s.Cfg.JWTAuth.JWKSetFile != "" {
+	if ksCfg.JWKSetFile != "" {
 		count++
 	}
-	if s.Cfg.JWTAuth.JWKSetURL != "" {
+	if ksCfg.JWKSetURL != "" {
 		count++
 	}
 
@@ -70,17 +86,55 @@ func (s *AuthService) checkKeySetConfiguration() error {
 	return nil
 }
 
+// initKeySet sets up the default key set and the key sets of the configured issuers.
+// The default key set is optional when at least one issuer key set is configured.
 func (s *AuthService) initKeySet() error {
-	if err := s.checkKeySetConfiguration(); err != nil {
+	s.issuerKeySets = make(map[string]keySet, len(s.Cfg.JWTAuth.KeySets))
+	for _, ksCfg := range s.Cfg.JWTAuth.KeySets {
+		if ksCfg.Issuer == "" {
+			return fmt.Errorf("key set %q: %w", ksCfg.Name, ErrKeySetIssuerMissing)
+		}
+		if _, ok := s.issuerKeySets[ksCfg.Issuer]; ok {
+			return fmt.Errorf("key set %q: %w", ksCfg.Name, ErrKeySetIssuerDuplicated)
+		}
+
+		ks, err := s.newKeySet(ksCfg)
+		if err != nil {
+			return fmt.Errorf("key set %q: %w", ksCfg.Name, err)
+		}
+		s.issuerKeySets[ksCfg.Issuer] = ks
+	}
+
+	defaultCfg := setting.AuthJWTKeySetSettings{
+		JWKSetURL:  s.Cfg.JWTAuth.JWKSetURL,
+		JWKSetFile: s.Cfg.JWTAuth.JWKSetFile,
+		KeyFile:    s.Cfg.JWTAuth.KeyFile,
+		KeyID:      s.Cfg.JWTAuth.KeyID,
+	}
+	if errors.Is(checkKeySetConfiguration(defaultCfg), ErrKeySetIsNotConfigured) && len(s.issuerKeySets) > 0 {
+		return nil
+	}
+
+	ks, err := s.newKeySet(defaultCfg)
+	if err != nil {
 		return err
 	}
+	s.keySet = ks
 
-	if keyFilePath := s.Cfg.JWTAuth.KeyFile; keyFilePath != "" {
+	return nil
+}
+
+func (s *AuthService) newKeySet(ksCfg setting.AuthJWTKeySetSettings) (keySet, error) {
+	if err := checkKeySetConfiguration(ksCfg); err != nil {
+		return nil, err
+	}
+
+	if keyFilePath := ksCfg.KeyFile; keyFilePath != "" {
 		// nolint:gosec
 		// We can ignore the gosec G304 warning on this one because `fileName` comes from grafana configuration file
 		file, err := os.Open(keyFilePath)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		defer func() {
 			if err := file.Close(); err != nil {
@@ -90,50 +144,52 @@ func (s *AuthService) initKeySet() error {
 
 		data, err := io.ReadAll(file)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		block, _ := pem.Decode(data)
 		if block == nil {
-			return ErrFailedToParsePemFile
+			return nil, ErrFailedToParsePemFile
 		}
 
 		var key any
 		switch block.Type {
 		case "PUBLIC KEY":
 			if key, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
-				return err
+				return nil, err
 			}
 		case "PRIVATE KEY":
 			if key, err = x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
-				return err
+				return nil, err
 			}
 		case "RSA PUBLIC KEY":
 			if key, err = x509.ParsePKCS1PublicKey(block.Bytes); err != nil {
-				return err
+				return nil, err
 			}
 		case "RSA PRIVATE KEY":
 			if key, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
-				return err
+				return nil, err
 			}
 		case "EC PRIVATE KEY":
 			if key, err = x509.ParseECPrivateKey(block.Bytes); err != nil {
-				return err
+				return nil, err
 			}
 		default:
-			return fmt.Errorf("unknown pem block type %q", block.Type)
+			return nil, fmt.Errorf("unknown pem block type %q", block.Type)
 		}
 
-		s.keySet = &keySetJWKS{
+		return &keySetJWKS{
 			jose.JSONWebKeySet{
-				Keys: []jose.JSONWebKey{{Key: key, KeyID: s.Cfg.JWTAuth.KeyID}},
+				Keys: []jose.JSONWebKey{{Key: key, KeyID: ksCfg.KeyID}},
 			},
-		}
-	} else if keyFilePath := s.Cfg.JWTAuth.JWKSetFile; keyFilePath != "" {
+		}, nil
+	}
+
+	if keyFilePath := ksCfg.JWKSetFile; keyFilePath != "" {
 		// nolint:gosec
 		// We can ignore the gosec G304 warning on this one because `fileName` comes from grafana configuration file
 		file, err := os.Open(keyFilePath)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		defer func() {
 			if err := file.Close(); err != nil {
@@ -143,46 +199,47 @@ func (s *AuthService) initKeySet() error {
 
 		var jwks jose.JSONWebKeySet
 		if err := json.NewDecoder(file).Decode(&jwks); err != nil {
-			return err
+			return nil, err
 		}
 
-		s.keySet = &keySetJWKS{jwks}
-	} else if urlStr := s.Cfg.JWTAuth.JWKSetURL; urlStr != "" {
-		urlParsed, err := url.Parse(urlStr)
-		if err != nil {
-			return err
-		}
-		if urlParsed.Scheme != "https" && s.Cfg.Env != setting.Dev {
-			return ErrJWTSetURLMustHaveHTTPSScheme
-		}
-		s.keySet = &keySetHTTP{
-			url: urlStr,
-			log: s.log,
-			client: &http.Client{
-				Transport: &http.Transport{
-					TLSClientConfig: &tls.Config{
-						Renegotiation:      tls.RenegotiateFreelyAsClient,
-						InsecureSkipVerify: s.Cfg.JWTAuth.TlsSkipVerify,
-					},
-					Proxy: http.ProxyFromEnvironment,
-					DialContext: (&net.Dialer{
-						Timeout:   time.Second * 30,
-						KeepAlive: 15 * time.Second,
-					}).DialContext,
-					TLSHandshakeTimeout:   10 * time.Second,
-					ExpectContinueTimeout: 1 * time.Second,
-					MaxIdleConns:          100,
-					IdleConnTimeout:       30 * time.Second,
-				},
-				Timeout: time.Second * 30,
-			},
-			cacheKey:        fmt.Sprintf("auth-jwt:jwk-%s", urlStr),
-			cacheExpiration: s.Cfg.JWTAuth.CacheTTL,
-			cache:           s.RemoteCache,
-		}
+		return &keySetJWKS{jwks}, nil
 	}
 
-	return nil
+	urlStr := ksCfg.JWKSetURL
+	urlParsed, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, err
+	}
+	if urlParsed.Scheme != "https" && s.Cfg.Env != setting.Dev {
+		return nil, ErrJWTSetURLMustHaveHTTPSScheme
+	}
+	return &keySetHTTP{
+		url: urlStr,
+		log: s.log,
+		client: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					Renegotiation:      tls.RenegotiateFreelyAsClient,
+					InsecureSkipVerify: s.Cfg.JWTAuth.TlsSkipVerify,
+				},
+				Proxy: http.ProxyFromEnvironment,
+				DialContext: (&net.Dialer{
+					Timeout:   time.Second * 30,
+					KeepAlive: 15 * time.Second,
+				}).DialContext,
+				TLSHandshakeTimeout:   10 * time.Second,
+				ExpectContinueTimeout: 1 * time.Second,
+				MaxIdleConns:          100,
+				IdleConnTimeout:       30 * time.Second,
+			},
+			Timeout: time.Second * 30,
+		},
+		cacheKey:            fmt.Sprintf("auth-jwt:jwk-%s", urlStr),
+		cacheExpiration:     s.Cfg.JWTAuth.CacheTTL,
+		cache:               s.RemoteCache,
+		refreshOnUnknownKey: s.Cfg.JWTAuth.JWKSetRefreshInterval > 0,
+		maxStaleAge:         s.Cfg.JWTAuth.JWKSetMaxStaleAge,
+	}, nil
 }
 
 func (ks *keySetJWKS) Key(ctx context.Context, keyID string) ([]jose.JSONWebKey, error) {
@@ -203,6 +260,22 @@ func (ks *keySetHTTP) getJWKS(ctx context.Context) (keySetJWKS, error) {
 		}
 	}
 
+	jwks, err := ks.fetch(ctx)
+	if err != nil {
+		if stale, ok := ks.staleKeySet(); ok {
+			ks.log.Warn("Failed to get key set from endpoint, using the last fetched key set", "url", ks.url, "err", err)
+			return stale, nil
+		}
+		return jwks, err
+	}
+	return jwks, nil
+}
+
+// fetch gets the key set from the endpoint, bypassing the cache, and stores it
+// in the cache and as the last known key set
+func (ks *keySetHTTP) fetch(ctx context.Context) (keySetJWKS, error) {
+	var jwks keySetJWKS
+
 	ks.log.Debug("Getting key set from endpoint", "url", ks.url)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.url, nil)
@@ -220,11 +293,20 @@ func (ks *keySetHTTP) getJWKS(ctx context.Context) (keySetJWKS, error) {
 		}
 	}()
 
+	if resp.StatusCode != http.StatusOK {
+		return jwks, fmt.Errorf("unexpected status code %d from key set endpoint", resp.StatusCode)
+	}
+
 	var jsonBuf bytes.Buffer
 	if err := json.NewDecoder(io.TeeReader(resp.Body, &jsonBuf)).Decode(&jwks); err != nil {
 		return jwks, err
 	}
 
+	ks.mu.Lock()
+	ks.lastKeySet = &jwks
+	ks.lastFetchedAt = time.Now()
+	ks.mu.Unlock()
+
 	if ks.cacheExpiration > 0 {
 		cacheExpiration := ks.getCacheExpiration(resp.Header.Get("cache-control"))
 
@@ -235,6 +317,39 @@ func (ks *keySetHTTP) getJWKS(ctx context.Context) (keySetJWKS, error) {
 	return jwks, err
 }
 
+// refresh fetches the key set from the endpoint ahead of the expiry of the cached one
+func (ks *keySetHTTP) refresh(ctx context.Context) error {
+	_, err := ks.fetch(ctx)
+	return err
+}
+
+// staleKeySet returns the last fetched key set as long as it is younger than the max stale age
+func (ks *keySetHTTP) staleKeySet() (keySetJWKS, bool) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if ks.lastKeySet == nil || time.Since(ks.lastFetchedAt) > ks.maxStaleAge {
+		return keySetJWKS{}, false
+	}
+	return *ks.lastKeySet, true
+}
+
+// shouldForceRefresh rate limits the refreshes triggered by unknown keys
+func (ks *keySetHTTP) shouldForceRefresh() bool {
+	if !ks.refreshOnUnknownKey {
+		return false
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if time.Since(ks.lastForcedAt) < forcedRefreshInterval {
+		return false
+	}
+	ks.lastForcedAt = time.Now()
+	return true
+}
+
 func (ks *keySetHTTP) getCacheExpiration(cacheControl string) time.Duration {
 	cacheDuration := ks.cacheExpiration
 	if cacheControl == "" {
@@ -260,10 +375,21 @@ func (ks *keySetHTTP) getCacheExpiration(cacheControl string) time.Duration {
 	return cacheDuration
 }
 
-func (ks keySetHTTP) Key(ctx context.Context, kid string) ([]jose.JSONWebKey, error) {
+func (ks *keySetHTTP) Key(ctx context.Context, kid string) ([]jose.JSONWebKey, error) {
 	jwks, err := ks.getJWKS(ctx)
 	if err != nil {
 		return nil, err
 	}
+
+	keys, err := jwks.Key(ctx, kid)
+	if err != nil || len(keys) > 0 || kid == "" || !ks.shouldForceRefresh() {
+		return keys, err
+	}
+
+	// the issuer may have rotated its keys since the key set was cached
+	ks.log.Debug("Refreshing key set for unknown key", "url", ks.url, "kid", kid)
+	if jwks, err = ks.fetch(ctx); err != nil {
+		return nil, err
+	}
 	return jwks.Key(ctx, kid)
 }
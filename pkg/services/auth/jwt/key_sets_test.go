@@ -1,10 +1,20 @@
 package jwt
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	jose "github.com/go-jose/go-jose/v3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 )
 
 func TestGetCacheExpiration(t *testing.T) {
@@ -52,3 +62,66 @@ func TestGetCacheExpiration(t *testing.T) {
 		})
 	}
 }
+
+func TestKeySetHTTP(t *testing.T) {
+	t.Run("should use the last fetched key set while the endpoint is unavailable", func(t *testing.T) {
+		var unavailable atomic.Bool
+		ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if unavailable.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			require.NoError(t, json.NewEncoder(w).Encode(jwksPublic))
+		}))
+		t.Cleanup(ts.Close)
+
+		ks := &keySetHTTP{url: ts.URL, log: log.NewNopLogger(), client: ts.Client(), maxStaleAge: time.Hour}
+
+		keys, err := ks.Key(context.Background(), jwKeys[0].KeyID)
+		require.NoError(t, err)
+		assert.Len(t, keys, 1)
+
+		unavailable.Store(true)
+		keys, err = ks.Key(context.Background(), jwKeys[0].KeyID)
+		require.NoError(t, err)
+		assert.Len(t, keys, 1)
+
+		ks.lastFetchedAt = time.Now().Add(-2 * time.Hour)
+		_, err = ks.Key(context.Background(), jwKeys[0].KeyID)
+		assert.Error(t, err)
+	})
+
+	t.Run("should refresh the key set once per interval for unknown keys", func(t *testing.T) {
+		var reqCount atomic.Int32
+		ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// the second key is published after the first request, as if the issuer rotated its keys
+			jwks := jose.JSONWebKeySet{Keys: jwksPublic.Keys[:reqCount.Add(1)]}
+			require.NoError(t, json.NewEncoder(w).Encode(jwks))
+		}))
+		t.Cleanup(ts.Close)
+
+		ks := &keySetHTTP{
+			url:                 ts.URL,
+			log:                 log.NewNopLogger(),
+			client:              ts.Client(),
+			cache:               remotecache.NewFakeStore(t),
+			cacheKey:            "auth-jwt:jwk-test",
+			cacheExpiration:     time.Hour,
+			refreshOnUnknownKey: true,
+		}
+
+		keys, err := ks.Key(context.Background(), jwKeys[0].KeyID)
+		require.NoError(t, err)
+		assert.Len(t, keys, 1)
+
+		keys, err = ks.Key(context.Background(), jwKeys[1].KeyID)
+		require.NoError(t, err)
+		assert.Len(t, keys, 1)
+		assert.Equal(t, int32(2), reqCount.Load())
+
+		keys, err = ks.Key(context.Background(), jwKeys[2].KeyID)
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+		assert.Equal(t, int32(2), reqCount.Load())
+	})
+}
@@ -19,25 +19,30 @@ import (
 )
 
 const (
-	ClientAPIKey       = "auth.client.api-key" // #nosec G101
-	ClientAnonymous    = "auth.client.anonymous"
-	ClientBasic        = "auth.client.basic"
-	ClientJWT          = "auth.client.jwt"
-	ClientExtendedJWT  = "auth.client.extended-jwt"
-	ClientRender       = "auth.client.render"
-	ClientSession      = "auth.client.session"
-	ClientForm         = "auth.client.form"
-	ClientProxy        = "auth.client.proxy"
-	ClientSAML         = "auth.client.saml"
-	ClientPasswordless = "auth.client.passwordless"
-	ClientLDAP         = "ldap"
-	ClientProvisioning = "auth.client.apiserver.provisioning"
+	ClientAPIKey          = "auth.client.api-key" // #nosec G101
+	ClientAnonymous       = "auth.client.anonymous"
+	ClientBasic           = "auth.client.basic"
+	ClientJWT             = "auth.client.jwt"
+	ClientExtendedJWT     = "auth.client.extended-jwt"
+	ClientRender          = "auth.client.render"
+	ClientSession         = "auth.client.session"
+	ClientForm            = "auth.client.form"
+	ClientProxy           = "auth.client.proxy"
+	ClientMTLS            = "auth.client.mtls"
+	ClientSAML            = "auth.client.saml"
+	ClientPasswordless    = "auth.client.passwordless"
+	ClientLDAP            = "ldap"
+	ClientProvisioning    = "auth.client.apiserver.provisioning"
+	ClientCapabilityToken = "auth.client.capability-token" // #nosec G101
+	ClientKioskToken      = "auth.client.kiosk-token"      // #nosec G101
 )
 
 const (
 	MetaKeyUsername            = "username"
 	MetaKeyAuthModule          = "authModule"
 	MetaKeyIsLogin             = "isLogin"
+	MetaKeyMFACode             = "mfaCode"
+	MetaKeyAPIKeyID            = "keyID"
 	defaultRedirectToCookieKey = "redirect_to"
 )
 
@@ -33,7 +33,6 @@ var (
 )
 
 const (
-	metaKeyID           = "keyID"
 	metaKeySkipLastUsed = "keySkipLastUsed"
 )
 
@@ -75,7 +74,7 @@ func (s *APIKey) Authenticate(ctx context.Context, r *authn.Request) (*authn.Ide
 	}
 
 	// Set keyID so we can use it in last used hook
-	r.SetMeta(metaKeyID, strconv.FormatInt(key.ID, 10))
+	r.SetMeta(authn.MetaKeyAPIKeyID, strconv.FormatInt(key.ID, 10))
 	if !shouldUpdateLastUsedAt(key) {
 		// Hack to just have some value, we will check this key in the hook
 		// and if its not an empty string we will not update last used.
@@ -180,7 +179,7 @@ func (s *APIKey) Hook(ctx context.Context, identity *authn.Identity, r *authn.Re
 			s.log.Warn("Failed to update last used date for api key", "id", keyID, "err", err)
 			return
 		}
-	}(r.GetMeta(metaKeyID))
+	}(r.GetMeta(authn.MetaKeyAPIKeyID))
 
 	return nil
 }
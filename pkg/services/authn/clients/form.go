@@ -23,6 +23,7 @@ type Form struct {
 type loginForm struct {
 	Username string `json:"user" binding:"Required"`
 	Password string `json:"password" binding:"Required"`
+	MFACode  string `json:"mfaCode"`
 }
 
 func (c *Form) Name() string {
@@ -34,6 +35,9 @@ func (c *Form) Authenticate(ctx context.Context, r *authn.Request) (*authn.Ident
 	if err := web.Bind(r.HTTPRequest, &form); err != nil {
 		return nil, errBadForm.Errorf("failed to parse request: %w", err)
 	}
+	if form.MFACode != "" {
+		r.SetMeta(authn.MetaKeyMFACode, form.MFACode)
+	}
 	return c.client.AuthenticatePassword(ctx, r, form.Username, form.Password)
 }
 
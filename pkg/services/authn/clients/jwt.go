@@ -2,26 +2,47 @@ package clients
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	claims "github.com/grafana/authlib/types"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/login/social/connectors"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/auth"
 	authJWT "github.com/grafana/grafana/pkg/services/auth/jwt"
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
 
-const authQueryParamName = "auth_token"
+const (
+	authQueryParamName = "auth_token"
+	// metaKeyJWTClaimsMapping carries the result of the claims mapping from Authenticate to Hook
+	metaKeyJWTClaimsMapping = "jwt.claims_mapping"
+	// claimsMappingSyncTTL is how long a synchronized mapping result is not applied again for the same user
+	claimsMappingSyncTTL = 5 * time.Minute
+)
 
-var _ authn.ContextAwareClient = new(JWT)
+var (
+	_ authn.ContextAwareClient = new(JWT)
+	_ authn.HookClient         = new(JWT)
+)
 
 var (
 	errJWTInvalid = errutil.Unauthorized(
@@ -32,24 +53,43 @@ var (
 		"jwt.invalid_role", errutil.WithPublicMessage("Invalid Role in claim"))
 )
 
-func ProvideJWT(jwtService auth.JWTVerifierService, orgRoleMapper *connectors.OrgRoleMapper, cfg *setting.Cfg, tracer trace.Tracer) *JWT {
+func ProvideJWT(jwtService auth.JWTVerifierService, orgRoleMapper *connectors.OrgRoleMapper, cfg *setting.Cfg, tracer trace.Tracer,
+	teamService team.Service, teamPermissionsService accesscontrol.TeamPermissionsService, accessControlService accesscontrol.Service) *JWT {
+	logger := log.New(authn.ClientJWT)
+
+	// the mapping file is validated when the JWT service starts, a failure here can only come from a change of the file
+	claimsMapping, err := authJWT.LoadClaimsMapping(cfg.JWTAuth.ClaimsMappingFile)
+	if err != nil {
+		logger.Error("Failed to load claims mapping, claims will not be mapped", "path", cfg.JWTAuth.ClaimsMappingFile, "err", err)
+	}
+
 	return &JWT{
-		cfg:           cfg,
-		log:           log.New(authn.ClientJWT),
-		jwtService:    jwtService,
-		orgRoleMapper: orgRoleMapper,
-		orgMappingCfg: orgRoleMapper.ParseOrgMappingSettings(context.Background(), cfg.JWTAuth.OrgMapping, cfg.JWTAuth.RoleAttributeStrict),
-		tracer:        tracer,
+		cfg:                    cfg,
+		log:                    logger,
+		jwtService:             jwtService,
+		orgRoleMapper:          orgRoleMapper,
+		orgMappingCfg:          orgRoleMapper.ParseOrgMappingSettings(context.Background(), cfg.JWTAuth.OrgMapping, cfg.JWTAuth.RoleAttributeStrict),
+		claimsMapping:          claimsMapping,
+		syncedMappings:         localcache.New(claimsMappingSyncTTL, 2*claimsMappingSyncTTL),
+		teamService:            teamService,
+		teamPermissionsService: teamPermissionsService,
+		accessControlService:   accessControlService,
+		tracer:                 tracer,
 	}
 }
 
 type JWT struct {
-	cfg           *setting.Cfg
-	orgRoleMapper *connectors.OrgRoleMapper
-	orgMappingCfg connectors.MappingConfiguration
-	log           log.Logger
-	jwtService    auth.JWTVerifierService
-	tracer        trace.Tracer
+	cfg                    *setting.Cfg
+	orgRoleMapper          *connectors.OrgRoleMapper
+	orgMappingCfg          connectors.MappingConfiguration
+	claimsMapping          *authJWT.ClaimsMapping
+	syncedMappings         *localcache.CacheService
+	log                    log.Logger
+	jwtService             auth.JWTVerifierService
+	teamService            team.Service
+	teamPermissionsService accesscontrol.TeamPermissionsService
+	accessControlService   accesscontrol.Service
+	tracer                 trace.Tracer
 }
 
 func (s *JWT) Name() string {
@@ -119,6 +159,16 @@ func (s *JWT) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identi
 		return nil, err
 	}
 
+	var mapped *authJWT.ClaimsMappingResult
+	if s.claimsMapping != nil {
+		if mapped, err = s.claimsMapping.Evaluate(claims); err != nil {
+			return nil, errJWTInvalid.Errorf("failed to map JWT claims: %w", err)
+		}
+		if err := s.setClaimsMappingMeta(r, mapped); err != nil {
+			return nil, err
+		}
+	}
+
 	if !s.cfg.JWTAuth.SkipOrgRoleSync {
 		role, grafanaAdmin := s.extractRoleAndAdmin(claims)
 
@@ -133,6 +183,12 @@ func (s *JWT) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identi
 		}
 
 		id.OrgRoles = s.orgRoleMapper.MapOrgRoles(s.orgMappingCfg, externalOrgs, role)
+		if mapped != nil && len(mapped.OrgRoles) > 0 {
+			if id.OrgRoles == nil {
+				id.OrgRoles = map[int64]org.RoleType{}
+			}
+			mergeOrgRoles(id.OrgRoles, mapped.OrgRoles)
+		}
 		if s.cfg.JWTAuth.RoleAttributeStrict && len(id.OrgRoles) == 0 {
 			return nil, errJWTInvalidRole.Errorf("could not evaluate any valid roles using IdP provided data")
 		}
@@ -147,6 +203,180 @@ func (s *JWT) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identi
 	return id, nil
 }
 
+// Hook applies the team memberships and RBAC roles of the claims mapping once the user is synced.
+// Only the teams and roles mentioned by the mapping are managed, in the orgs the user is a member of.
+func (s *JWT) Hook(ctx context.Context, id *authn.Identity, r *authn.Request) error {
+	raw := r.GetMeta(metaKeyJWTClaimsMapping)
+	if raw == "" || !id.IsIdentityType(claims.TypeUser) {
+		return nil
+	}
+
+	ctx, span := s.tracer.Start(ctx, "authn.jwt.Hook")
+	defer span.End()
+
+	userID, err := id.GetInternalID()
+	if err != nil {
+		return err
+	}
+
+	// the mapping result rarely changes between two requests of a user, skip the sync when it was already applied
+	sum := sha256.Sum256([]byte(raw))
+	cacheKey := fmt.Sprintf("%d:%s", userID, hex.EncodeToString(sum[:]))
+	if _, ok := s.syncedMappings.Get(cacheKey); ok {
+		return nil
+	}
+
+	var mapped authJWT.ClaimsMappingResult
+	if err := json.Unmarshal([]byte(raw), &mapped); err != nil {
+		return err
+	}
+
+	for _, orgID := range sortedOrgIDs(mapped.Teams) {
+		if !s.isOrgMember(id, orgID) {
+			continue
+		}
+		if err := s.syncTeams(ctx, orgID, userID, mapped.Teams[orgID]); err != nil {
+			return err
+		}
+	}
+
+	for _, orgID := range sortedOrgIDs(mapped.Roles) {
+		if !s.isOrgMember(id, orgID) {
+			continue
+		}
+		cmd := accesscontrol.SyncUserRolesCommand{UserID: userID, RolesToAdd: []string{}, RolesToRemove: []string{}}
+		for _, name := range sortedNames(mapped.Roles[orgID]) {
+			if mapped.Roles[orgID][name] {
+				cmd.RolesToAdd = append(cmd.RolesToAdd, name)
+			} else {
+				cmd.RolesToRemove = append(cmd.RolesToRemove, name)
+			}
+		}
+		if err := s.accessControlService.SyncUserRoles(ctx, orgID, cmd); err != nil {
+			return err
+		}
+	}
+
+	s.syncedMappings.Set(cacheKey, true, claimsMappingSyncTTL)
+	return nil
+}
+
+func (s *JWT) setClaimsMappingMeta(r *authn.Request, mapped *authJWT.ClaimsMappingResult) error {
+	if len(mapped.Teams) == 0 && len(mapped.Roles) == 0 {
+		return nil
+	}
+
+	raw, err := json.Marshal(mapped)
+	if err != nil {
+		return err
+	}
+	r.SetMeta(metaKeyJWTClaimsMapping, string(raw))
+	return nil
+}
+
+// isOrgMember returns false for orgs the mapping doesn't give the user access to,
+// unless org roles are not synced by Grafana
+func (s *JWT) isOrgMember(id *authn.Identity, orgID int64) bool {
+	if s.cfg.JWTAuth.SkipOrgRoleSync {
+		return true
+	}
+	_, ok := id.OrgRoles[orgID]
+	return ok
+}
+
+func (s *JWT) syncTeams(ctx context.Context, orgID, userID int64, wanted map[string]bool) error {
+	memberships, err := s.teamService.GetUserTeamMemberships(ctx, orgID, userID, true)
+	if err != nil {
+		return err
+	}
+	external := make(map[int64]bool, len(memberships))
+	for _, m := range memberships {
+		external[m.TeamID] = true
+	}
+
+	for _, name := range sortedNames(wanted) {
+		t, err := s.findTeam(ctx, orgID, name)
+		if err != nil {
+			return err
+		}
+		if t == nil {
+			s.log.FromContext(ctx).Debug("Team of the claims mapping not found", "orgId", orgID, "team", name)
+			continue
+		}
+
+		permission := ""
+		if wanted[name] {
+			if external[t.ID] {
+				continue
+			}
+			isMember, err := s.teamService.IsTeamMember(ctx, orgID, t.ID, userID)
+			if err != nil {
+				return err
+			}
+			if isMember {
+				continue
+			}
+			permission = team.PermissionTypeMember.String()
+		} else if !external[t.ID] {
+			// memberships added by hand are left untouched
+			continue
+		}
+
+		if _, err := s.teamPermissionsService.SetUserPermission(ctx, orgID, accesscontrol.User{ID: userID, IsExternal: true}, strconv.FormatInt(t.ID, 10), permission); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *JWT) findTeam(ctx context.Context, orgID int64, name string) (*team.TeamDTO, error) {
+	result, err := s.teamService.SearchTeams(ctx, &team.SearchTeamsQuery{
+		OrgID: orgID,
+		Name:  name,
+		Limit: 1,
+		SignedInUser: &user.SignedInUser{
+			Login:       "jwt-claims-mapping",
+			OrgID:       orgID,
+			Permissions: map[int64]map[string][]string{orgID: {accesscontrol.ActionTeamsRead: {accesscontrol.ScopeTeamsAll}}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Teams) == 0 {
+		return nil, nil
+	}
+	return result.Teams[0], nil
+}
+
+// mergeOrgRoles adds the mapped roles to the roles, keeping the highest role of each org
+func mergeOrgRoles(roles, mapped map[int64]org.RoleType) {
+	for orgID, role := range mapped {
+		if current, ok := roles[orgID]; !ok || !current.Includes(role) {
+			roles[orgID] = role
+		}
+	}
+}
+
+func sortedOrgIDs[T any](m map[int64]T) []int64 {
+	ids := make([]int64, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func sortedNames(m map[string]bool) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (s *JWT) IsEnabled() bool {
 	return s.cfg.JWTAuth.Enabled
 }
@@ -5,19 +5,26 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 
+	claims "github.com/grafana/authlib/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/login/social/connectors"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
 	"github.com/grafana/grafana/pkg/services/auth/jwt"
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamtest"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -263,7 +270,7 @@ func TestAuthenticateJWT(t *testing.T) {
 			jwtClient := ProvideJWT(jwtService,
 				connectors.ProvideOrgRoleMapper(tc.cfg,
 					&orgtest.FakeOrgService{ExpectedOrgs: []*org.OrgDTO{{ID: 4, Name: "Org4"}, {ID: 5, Name: "Org5"}}}),
-				tc.cfg, tracing.InitializeTracerForTest())
+				tc.cfg, tracing.InitializeTracerForTest(), nil, nil, nil)
 			validHTTPReq := &http.Request{
 				Header: map[string][]string{
 					jwtHeaderName: {"sample-token"}},
@@ -381,7 +388,7 @@ func TestJWTClaimConfig(t *testing.T) {
 			}
 			jwtClient := ProvideJWT(jwtService, connectors.ProvideOrgRoleMapper(cfg,
 				&orgtest.FakeOrgService{ExpectedOrgs: []*org.OrgDTO{{ID: 4, Name: "Org4"}, {ID: 5, Name: "Org5"}}}),
-				cfg, tracing.InitializeTracerForTest())
+				cfg, tracing.InitializeTracerForTest(), nil, nil, nil)
 			_, err := jwtClient.Authenticate(context.Background(), &authn.Request{
 				OrgID:       1,
 				HTTPRequest: httpReq,
@@ -494,7 +501,7 @@ func TestJWTTest(t *testing.T) {
 			jwtClient := ProvideJWT(jwtService,
 				connectors.ProvideOrgRoleMapper(cfg,
 					&orgtest.FakeOrgService{ExpectedOrgs: []*org.OrgDTO{{ID: 4, Name: "Org4"}, {ID: 5, Name: "Org5"}}}),
-				cfg, tracing.InitializeTracerForTest())
+				cfg, tracing.InitializeTracerForTest(), nil, nil, nil)
 			httpReq := &http.Request{
 				URL: &url.URL{RawQuery: "auth_token=" + tc.token},
 				Header: map[string][]string{
@@ -550,7 +557,7 @@ func TestJWTStripParam(t *testing.T) {
 	jwtClient := ProvideJWT(jwtService,
 		connectors.ProvideOrgRoleMapper(cfg,
 			&orgtest.FakeOrgService{ExpectedOrgs: []*org.OrgDTO{{ID: 4, Name: "Org4"}, {ID: 5, Name: "Org5"}}}),
-		cfg, tracing.InitializeTracerForTest())
+		cfg, tracing.InitializeTracerForTest(), nil, nil, nil)
 	_, err := jwtClient.Authenticate(context.Background(), &authn.Request{
 		OrgID:       1,
 		HTTPRequest: httpReq,
@@ -609,7 +616,7 @@ func TestJWTSubClaimsConfig(t *testing.T) {
 	jwtClient := ProvideJWT(jwtService,
 		connectors.ProvideOrgRoleMapper(cfg,
 			&orgtest.FakeOrgService{ExpectedOrgs: []*org.OrgDTO{{ID: 4, Name: "Org4"}, {ID: 5, Name: "Org5"}}}),
-		cfg, tracing.InitializeTracerForTest())
+		cfg, tracing.InitializeTracerForTest(), nil, nil, nil)
 	identity, err := jwtClient.Authenticate(context.Background(), &authn.Request{
 		OrgID:       1,
 		HTTPRequest: httpReq,
@@ -619,3 +626,114 @@ func TestJWTSubClaimsConfig(t *testing.T) {
 	require.Equal(t, "name_of_the_user", identity.Name)
 	fmt.Println("identity.Email", identity.Email)
 }
+
+func TestJWTClaimsMapping(t *testing.T) {
+	mappingFile := filepath.Join(t.TempDir(), "claims_mapping.yaml")
+	require.NoError(t, os.WriteFile(mappingFile, []byte(`
+rules:
+  - when: "contains(groups, 'dev')"
+    org_id: 1
+    role: Editor
+    teams: ["Developers"]
+  - when: "contains(groups, 'sre')"
+    org_id: 2
+    role: Viewer
+    roles: ["fixed:alerting:writer"]
+  - when: "contains(groups, 'admin')"
+    org_id: 2
+    teams: ["Admins"]
+`), 0o600))
+
+	jwtHeaderName := "X-Forwarded-User"
+	cfg := &setting.Cfg{
+		JWTAuth: setting.AuthJWTSettings{
+			Enabled:           true,
+			HeaderName:        jwtHeaderName,
+			UsernameClaim:     "preferred_username",
+			RoleAttributePath: "role",
+			ClaimsMappingFile: mappingFile,
+		},
+	}
+	jwtService := &jwt.FakeJWTService{
+		VerifyProvider: func(context.Context, string) (map[string]any, error) {
+			return map[string]any{
+				"sub":                "1234567890",
+				"preferred_username": "jdoe",
+				"role":               "Viewer",
+				"groups":             []any{"dev", "sre"},
+			}, nil
+		},
+	}
+
+	teams := &fakeTeamService{
+		FakeService: &teamtest.FakeService{
+			ExpectedMembers: []*team.TeamMemberDTO{{OrgID: 2, TeamID: 20, External: true}},
+		},
+		teams: map[string]*team.TeamDTO{"Developers": {ID: 10, OrgID: 1}, "Admins": {ID: 20, OrgID: 2}},
+	}
+	teamPermissions := &fakeTeamPermissionsService{}
+	accessControl := acmock.New()
+	var synced []accesscontrol.SyncUserRolesCommand
+	accessControl.SyncUserRolesFunc = func(_ context.Context, orgID int64, cmd accesscontrol.SyncUserRolesCommand) error {
+		assert.Equal(t, int64(2), orgID)
+		synced = append(synced, cmd)
+		return nil
+	}
+
+	jwtClient := ProvideJWT(jwtService, connectors.ProvideOrgRoleMapper(cfg, &orgtest.FakeOrgService{}),
+		cfg, tracing.InitializeTracerForTest(), teams, teamPermissions, accessControl)
+
+	req := &authn.Request{
+		OrgID:       1,
+		HTTPRequest: &http.Request{Header: map[string][]string{jwtHeaderName: {"sample-token"}}},
+	}
+	id, err := jwtClient.Authenticate(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]org.RoleType{1: org.RoleEditor, 2: org.RoleViewer}, id.OrgRoles)
+
+	id.ID = "3"
+	id.Type = claims.TypeUser
+	require.NoError(t, jwtClient.Hook(context.Background(), id, req))
+
+	assert.Equal(t, []teamPermissionChange{
+		{orgID: 1, userID: 3, teamID: "10", permission: "Member"},
+		{orgID: 2, userID: 3, teamID: "20", permission: ""},
+	}, teamPermissions.changes)
+	assert.Equal(t, []accesscontrol.SyncUserRolesCommand{
+		{UserID: 3, RolesToAdd: []string{"fixed:alerting:writer"}, RolesToRemove: []string{}},
+	}, synced)
+
+	// the same mapping result is not applied twice
+	require.NoError(t, jwtClient.Hook(context.Background(), id, req))
+	assert.Len(t, teamPermissions.changes, 2)
+	assert.Len(t, synced, 1)
+}
+
+type fakeTeamService struct {
+	*teamtest.FakeService
+	teams map[string]*team.TeamDTO
+}
+
+func (f *fakeTeamService) SearchTeams(_ context.Context, query *team.SearchTeamsQuery) (team.SearchTeamQueryResult, error) {
+	if t, ok := f.teams[query.Name]; ok && t.OrgID == query.OrgID {
+		return team.SearchTeamQueryResult{TotalCount: 1, Teams: []*team.TeamDTO{t}}, nil
+	}
+	return team.SearchTeamQueryResult{}, nil
+}
+
+type teamPermissionChange struct {
+	orgID      int64
+	userID     int64
+	teamID     string
+	permission string
+}
+
+type fakeTeamPermissionsService struct {
+	accesscontrol.TeamPermissionsService
+	changes []teamPermissionChange
+}
+
+func (f *fakeTeamPermissionsService) SetUserPermission(_ context.Context, orgID int64, user accesscontrol.User, teamID, permission string) (*accesscontrol.ResourcePermission, error) {
+	f.changes = append(f.changes, teamPermissionChange{orgID: orgID, userID: user.ID, teamID: teamID, permission: permission})
+	return &accesscontrol.ResourcePermission{}, nil
+}
@@ -0,0 +1,192 @@
+package clients
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/login/social/connectors"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var _ authn.ContextAwareClient = new(MTLS)
+
+var (
+	errMTLSInvalidCertificate = errutil.Unauthorized(
+		"mtls.invalid-certificate", errutil.WithPublicMessage("Invalid client certificate"))
+	errMTLSRevokedCertificate = errutil.Unauthorized(
+		"mtls.revoked-certificate", errutil.WithPublicMessage("Client certificate has been revoked"))
+	errMTLSMissingAttribute = errutil.Unauthorized(
+		"mtls.missing-attribute", errutil.WithPublicMessage("Missing login and email in client certificate"))
+	errMTLSInvalidRole = errutil.Forbidden(
+		"mtls.invalid-role", errutil.WithPublicMessage("Invalid role in client certificate"))
+)
+
+var (
+	oidEmailAddress = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 1}
+	oidUserID       = asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 1}
+)
+
+func ProvideMTLS(cfg *setting.Cfg, orgRoleMapper *connectors.OrgRoleMapper, tracer trace.Tracer) *MTLS {
+	logger := log.New(authn.ClientMTLS)
+	for _, attr := range []string{cfg.MTLSAuth.LoginAttribute, cfg.MTLSAuth.EmailAttribute, cfg.MTLSAuth.NameAttribute} {
+		if !isValidCertAttribute(attr) {
+			logger.Warn("Unknown client certificate attribute", "attribute", attr)
+		}
+	}
+
+	return &MTLS{
+		cfg:           cfg,
+		log:           logger,
+		orgRoleMapper: orgRoleMapper,
+		orgMappingCfg: orgRoleMapper.ParseOrgMappingSettings(context.Background(), cfg.MTLSAuth.OrgMapping, cfg.MTLSAuth.RoleAttributeStrict),
+		revocation:    newRevocationChecker(cfg.MTLSAuth, logger),
+		tracer:        tracer,
+	}
+}
+
+// MTLS authenticates users from the TLS client certificate they presented. Certificates are verified against
+// the configured CA bundle during the TLS handshake, see [setting.AuthMTLSSettings].
+type MTLS struct {
+	cfg           *setting.Cfg
+	log           log.Logger
+	orgRoleMapper *connectors.OrgRoleMapper
+	orgMappingCfg connectors.MappingConfiguration
+	revocation    *revocationChecker
+	tracer        trace.Tracer
+}
+
+func (c *MTLS) Name() string {
+	return authn.ClientMTLS
+}
+
+func (c *MTLS) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error) {
+	ctx, span := c.tracer.Start(ctx, "authn.mtls.Authenticate")
+	defer span.End()
+
+	chain := verifiedChain(r)
+	if len(chain) == 0 {
+		return nil, errMTLSInvalidCertificate.Errorf("client certificate was not verified")
+	}
+	cert := chain[0]
+
+	if err := c.revocation.check(ctx, chain); err != nil {
+		c.log.FromContext(ctx).Warn("Rejected client certificate", "subject", cert.Subject.String(), "serial", cert.SerialNumber.String(), "error", err)
+		return nil, err
+	}
+
+	id := &authn.Identity{
+		AuthenticatedBy: login.MTLSAuthModule,
+		AuthID:          cert.Subject.String(),
+		Login:           certAttribute(cert, c.cfg.MTLSAuth.LoginAttribute),
+		Email:           certAttribute(cert, c.cfg.MTLSAuth.EmailAttribute),
+		Name:            certAttribute(cert, c.cfg.MTLSAuth.NameAttribute),
+		OrgRoles:        map[int64]org.RoleType{},
+		ClientParams: authn.ClientParams{
+			SyncUser:        true,
+			FetchSyncedUser: true,
+			SyncPermissions: true,
+			SyncOrgRoles:    !c.cfg.MTLSAuth.SkipOrgRoleSync,
+			AllowSignUp:     c.cfg.MTLSAuth.AutoSignUp,
+		},
+	}
+
+	if id.Login == "" && id.Email == "" {
+		return nil, errMTLSMissingAttribute.Errorf("missing login and email in client certificate %q", cert.Subject.String())
+	}
+	if id.Login != "" {
+		id.ClientParams.LookUpParams.Login = &id.Login
+	}
+	if id.Email != "" {
+		id.ClientParams.LookUpParams.Email = &id.Email
+	}
+
+	if !c.cfg.MTLSAuth.SkipOrgRoleSync {
+		id.OrgRoles = c.orgRoleMapper.MapOrgRoles(c.orgMappingCfg, certOrgs(cert, c.cfg.MTLSAuth.OrgAttribute), "")
+		if c.cfg.MTLSAuth.RoleAttributeStrict && len(id.OrgRoles) == 0 {
+			return nil, errMTLSInvalidRole.Errorf("could not evaluate any valid roles from client certificate %q", cert.Subject.String())
+		}
+	}
+
+	return id, nil
+}
+
+func (c *MTLS) IsEnabled() bool {
+	return c.cfg.MTLSAuth.Enabled
+}
+
+func (c *MTLS) Test(ctx context.Context, r *authn.Request) bool {
+	return len(verifiedChain(r)) > 0
+}
+
+func (c *MTLS) Priority() uint {
+	return 25
+}
+
+// verifiedChain returns the certificate chain verified during the TLS handshake, starting with the client certificate
+func verifiedChain(r *authn.Request) []*x509.Certificate {
+	if r.HTTPRequest == nil || r.HTTPRequest.TLS == nil || len(r.HTTPRequest.TLS.VerifiedChains) == 0 {
+		return nil
+	}
+	return r.HTTPRequest.TLS.VerifiedChains[0]
+}
+
+func isValidCertAttribute(attr string) bool {
+	switch attr {
+	case "", "cn", "email", "uid", "serial", "dns", "uri":
+		return true
+	}
+	return false
+}
+
+func certAttribute(cert *x509.Certificate, attr string) string {
+	switch attr {
+	case "cn":
+		return cert.Subject.CommonName
+	case "email":
+		if len(cert.EmailAddresses) > 0 {
+			return cert.EmailAddresses[0]
+		}
+		return subjectAttribute(cert, oidEmailAddress)
+	case "uid":
+		return subjectAttribute(cert, oidUserID)
+	case "serial":
+		return cert.Subject.SerialNumber
+	case "dns":
+		if len(cert.DNSNames) > 0 {
+			return cert.DNSNames[0]
+		}
+	case "uri":
+		if len(cert.URIs) > 0 {
+			return cert.URIs[0].String()
+		}
+	}
+	return ""
+}
+
+func subjectAttribute(cert *x509.Certificate, oid asn1.ObjectIdentifier) string {
+	for _, name := range cert.Subject.Names {
+		if name.Type.Equal(oid) {
+			return fmt.Sprint(name.Value)
+		}
+	}
+	return ""
+}
+
+func certOrgs(cert *x509.Certificate, attr string) []string {
+	switch attr {
+	case "o":
+		return cert.Subject.Organization
+	case "ou":
+		return cert.Subject.OrganizationalUnit
+	}
+	return []string{}
+}
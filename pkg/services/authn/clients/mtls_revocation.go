@@ -0,0 +1,245 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// crlReloadInterval is how often the CRL file is checked for changes
+	crlReloadInterval = time.Minute
+	// ocspMaxCacheTTL caps how long OCSP responses are cached, so revocations are picked up even when responders
+	// announce their next update far in the future
+	ocspMaxCacheTTL  = time.Hour
+	ocspMaxBodyBytes = 1 << 20
+)
+
+// revocationChecker checks client certificates against the configured CRL and OCSP responders
+type revocationChecker struct {
+	cfg    setting.AuthMTLSSettings
+	log    log.Logger
+	client *http.Client
+
+	crlMu        sync.Mutex
+	crls         []*x509.RevocationList
+	crlModTime   time.Time
+	crlCheckedAt time.Time
+
+	ocspCache *localcache.CacheService
+}
+
+func newRevocationChecker(cfg setting.AuthMTLSSettings, logger log.Logger) *revocationChecker {
+	return &revocationChecker{
+		cfg:       cfg,
+		log:       logger,
+		client:    &http.Client{Timeout: cfg.OCSPTimeout},
+		ocspCache: localcache.New(ocspMaxCacheTTL, 2*ocspMaxCacheTTL),
+	}
+}
+
+// check returns an error if the client certificate of the verified chain is revoked, or its status can't be determined
+func (c *revocationChecker) check(ctx context.Context, chain []*x509.Certificate) error {
+	cert, issuer := chain[0], chain[0]
+	if len(chain) > 1 {
+		issuer = chain[1]
+	}
+
+	if c.cfg.CRLFile != "" {
+		revoked, err := c.isRevokedByCRL(cert, issuer)
+		if err != nil {
+			return errMTLSInvalidCertificate.Errorf("failed to check certificate revocation list: %w", err)
+		}
+		if revoked {
+			return errMTLSRevokedCertificate.Errorf("certificate %s is listed in the certificate revocation list", cert.SerialNumber)
+		}
+	}
+
+	if c.cfg.OCSPEnabled {
+		revoked, err := c.isRevokedByOCSP(ctx, cert, issuer)
+		if err != nil {
+			if !c.cfg.OCSPFailOpen {
+				return errMTLSInvalidCertificate.Errorf("failed to check certificate status: %w", err)
+			}
+			c.log.FromContext(ctx).Warn("Failed to check certificate status, accepting certificate", "serial", cert.SerialNumber.String(), "error", err)
+		}
+		if revoked {
+			return errMTLSRevokedCertificate.Errorf("certificate %s is revoked according to OCSP", cert.SerialNumber)
+		}
+	}
+
+	return nil
+}
+
+func (c *revocationChecker) isRevokedByCRL(cert, issuer *x509.Certificate) (bool, error) {
+	crls, err := c.loadCRLs()
+	if err != nil {
+		return false, err
+	}
+
+	for _, crl := range crls {
+		if !bytes.Equal(crl.RawIssuer, cert.RawIssuer) {
+			continue
+		}
+		if err := crl.CheckSignatureFrom(issuer); err != nil {
+			return false, fmt.Errorf("invalid signature on certificate revocation list: %w", err)
+		}
+		for _, entry := range crl.RevokedCertificateEntries {
+			if entry.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// loadCRLs returns the revocation lists of the CRL file, reloading them when the file changed
+func (c *revocationChecker) loadCRLs() ([]*x509.RevocationList, error) {
+	c.crlMu.Lock()
+	defer c.crlMu.Unlock()
+
+	if c.crls != nil && time.Since(c.crlCheckedAt) < crlReloadInterval {
+		return c.crls, nil
+	}
+
+	info, err := os.Stat(c.cfg.CRLFile)
+	if err != nil {
+		return nil, err
+	}
+	c.crlCheckedAt = time.Now()
+
+	if c.crls != nil && info.ModTime().Equal(c.crlModTime) {
+		return c.crls, nil
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because the path comes from the [auth.mtls] config.
+	data, err := os.ReadFile(c.cfg.CRLFile)
+	if err != nil {
+		return nil, err
+	}
+
+	crls, err := parseCRLs(data)
+	if err != nil {
+		return nil, err
+	}
+
+	c.crls, c.crlModTime = crls, info.ModTime()
+	return crls, nil
+}
+
+// parseCRLs parses PEM encoded revocation lists, or a single DER encoded one
+func parseCRLs(data []byte) ([]*x509.RevocationList, error) {
+	crls := make([]*x509.RevocationList, 0)
+	rest := data
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "X509 CRL" {
+			continue
+		}
+		crl, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		crls = append(crls, crl)
+	}
+
+	if len(crls) > 0 {
+		return crls, nil
+	}
+
+	crl, err := x509.ParseRevocationList(data)
+	if err != nil {
+		return nil, err
+	}
+	return []*x509.RevocationList{crl}, nil
+}
+
+func (c *revocationChecker) isRevokedByOCSP(ctx context.Context, cert, issuer *x509.Certificate) (bool, error) {
+	cacheKey := fmt.Sprintf("%s/%s", issuer.Subject.String(), cert.SerialNumber.String())
+	if revoked, ok := c.ocspCache.Get(cacheKey); ok {
+		return revoked.(bool), nil
+	}
+
+	responder := c.cfg.OCSPResponderURL
+	if responder == "" && len(cert.OCSPServer) > 0 {
+		responder = cert.OCSPServer[0]
+	}
+	if responder == "" {
+		return false, errors.New("no OCSP responder configured or listed in the certificate")
+	}
+
+	body, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responder, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/ocsp-request")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			c.log.Warn("Failed to close OCSP response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("OCSP responder returned status %d", resp.StatusCode)
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, ocspMaxBodyBytes))
+	if err != nil {
+		return false, err
+	}
+
+	ocspResp, err := ocsp.ParseResponseForCert(raw, cert, issuer)
+	if err != nil {
+		return false, err
+	}
+
+	var revoked bool
+	switch ocspResp.Status {
+	case ocsp.Good:
+		revoked = false
+	case ocsp.Revoked:
+		revoked = true
+	default:
+		return false, errors.New("OCSP responder does not know the certificate")
+	}
+
+	ttl := ocspMaxCacheTTL
+	if !ocspResp.NextUpdate.IsZero() {
+		ttl = min(ttl, time.Until(ocspResp.NextUpdate))
+	}
+	if ttl > 0 {
+		c.ocspCache.Set(cacheKey, revoked, ttl)
+	}
+
+	return revoked, nil
+}
@@ -0,0 +1,256 @@
+package clients
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/login/social/connectors"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestMTLS_Authenticate(t *testing.T) {
+	ca := newTestCA(t)
+	cert := ca.issue(t, 2, pkix.Name{
+		CommonName:         "John Doe",
+		OrganizationalUnit: []string{"Ops"},
+	}, "john@example.com")
+
+	newClient := func(mtlsCfg setting.AuthMTLSSettings) *MTLS {
+		cfg := setting.NewCfg()
+		cfg.MTLSAuth = mtlsCfg
+		return ProvideMTLS(cfg, connectors.ProvideOrgRoleMapper(cfg,
+			&orgtest.FakeOrgService{ExpectedOrgs: []*org.OrgDTO{{ID: 4, Name: "Org4"}}}), tracing.InitializeTracerForTest())
+	}
+
+	defaultCfg := setting.AuthMTLSSettings{
+		Enabled:        true,
+		LoginAttribute: "cn",
+		EmailAttribute: "email",
+		NameAttribute:  "cn",
+		OrgAttribute:   "ou",
+		OrgMapping:     []string{"Ops:4:Editor"},
+		AutoSignUp:     true,
+	}
+
+	t.Run("should not handle requests without verified certificate", func(t *testing.T) {
+		c := newClient(defaultCfg)
+		assert.False(t, c.Test(context.Background(), &authn.Request{HTTPRequest: &http.Request{}}))
+		assert.False(t, c.Test(context.Background(), &authn.Request{HTTPRequest: &http.Request{
+			TLS: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}},
+		}}))
+		assert.True(t, c.Test(context.Background(), mtlsRequest(cert, ca.cert)))
+	})
+
+	t.Run("should map the certificate to an identity", func(t *testing.T) {
+		c := newClient(defaultCfg)
+
+		id, err := c.Authenticate(context.Background(), mtlsRequest(cert, ca.cert))
+		require.NoError(t, err)
+
+		assert.Equal(t, login.MTLSAuthModule, id.AuthenticatedBy)
+		assert.Equal(t, cert.Subject.String(), id.AuthID)
+		assert.Equal(t, "John Doe", id.Login)
+		assert.Equal(t, "john@example.com", id.Email)
+		assert.Equal(t, "John Doe", id.Name)
+		assert.Equal(t, map[int64]org.RoleType{4: org.RoleEditor}, id.OrgRoles)
+		assert.True(t, id.ClientParams.AllowSignUp)
+		assert.True(t, id.ClientParams.SyncOrgRoles)
+		require.NotNil(t, id.ClientParams.LookUpParams.Email)
+		assert.Equal(t, "john@example.com", *id.ClientParams.LookUpParams.Email)
+	})
+
+	t.Run("should reject certificates without login and email", func(t *testing.T) {
+		mtlsCfg := defaultCfg
+		mtlsCfg.LoginAttribute = "uid"
+		mtlsCfg.EmailAttribute = "dns"
+		c := newClient(mtlsCfg)
+
+		_, err := c.Authenticate(context.Background(), mtlsRequest(cert, ca.cert))
+		assert.ErrorIs(t, err, errMTLSMissingAttribute)
+	})
+
+	t.Run("should reject certificates without role when role mapping is strict", func(t *testing.T) {
+		mtlsCfg := defaultCfg
+		mtlsCfg.OrgAttribute = "o"
+		mtlsCfg.RoleAttributeStrict = true
+		c := newClient(mtlsCfg)
+
+		_, err := c.Authenticate(context.Background(), mtlsRequest(cert, ca.cert))
+		assert.ErrorIs(t, err, errMTLSInvalidRole)
+	})
+
+	t.Run("should reject certificates listed in the CRL", func(t *testing.T) {
+		mtlsCfg := defaultCfg
+		mtlsCfg.CRLFile = filepath.Join(t.TempDir(), "crl.pem")
+		require.NoError(t, os.WriteFile(mtlsCfg.CRLFile, ca.crl(t, 2), 0o600))
+		c := newClient(mtlsCfg)
+
+		_, err := c.Authenticate(context.Background(), mtlsRequest(cert, ca.cert))
+		assert.ErrorIs(t, err, errMTLSRevokedCertificate)
+
+		other := ca.issue(t, 3, pkix.Name{CommonName: "Jane Doe"}, "")
+		_, err = c.Authenticate(context.Background(), mtlsRequest(other, ca.cert))
+		assert.NoError(t, err)
+	})
+
+	t.Run("should check certificate status with OCSP", func(t *testing.T) {
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			req, err := ocsp.ParseRequest(body)
+			require.NoError(t, err)
+
+			status := ocsp.Good
+			if req.SerialNumber.Int64() == 2 {
+				status = ocsp.Revoked
+			}
+			resp, err := ocsp.CreateResponse(ca.cert, ca.cert, ocsp.Response{
+				Status:       status,
+				SerialNumber: req.SerialNumber,
+				ThisUpdate:   time.Now().Add(-time.Minute),
+				NextUpdate:   time.Now().Add(time.Hour),
+				RevokedAt:    time.Now().Add(-time.Minute),
+			}, ca.key)
+			require.NoError(t, err)
+			_, _ = w.Write(resp)
+		}))
+		t.Cleanup(server.Close)
+
+		mtlsCfg := defaultCfg
+		mtlsCfg.OCSPEnabled = true
+		mtlsCfg.OCSPResponderURL = server.URL
+		mtlsCfg.OCSPTimeout = time.Second
+		c := newClient(mtlsCfg)
+
+		_, err := c.Authenticate(context.Background(), mtlsRequest(cert, ca.cert))
+		assert.ErrorIs(t, err, errMTLSRevokedCertificate)
+
+		other := ca.issue(t, 3, pkix.Name{CommonName: "Jane Doe"}, "")
+		_, err = c.Authenticate(context.Background(), mtlsRequest(other, ca.cert))
+		require.NoError(t, err)
+
+		// responses are cached
+		_, err = c.Authenticate(context.Background(), mtlsRequest(other, ca.cert))
+		require.NoError(t, err)
+		assert.Equal(t, 2, requests)
+	})
+
+	t.Run("should accept certificates when OCSP responder is unreachable and fail open is enabled", func(t *testing.T) {
+		mtlsCfg := defaultCfg
+		mtlsCfg.OCSPEnabled = true
+		mtlsCfg.OCSPResponderURL = "http://127.0.0.1:0"
+		mtlsCfg.OCSPTimeout = time.Second
+
+		_, err := newClient(mtlsCfg).Authenticate(context.Background(), mtlsRequest(cert, ca.cert))
+		assert.ErrorIs(t, err, errMTLSInvalidCertificate)
+
+		mtlsCfg.OCSPFailOpen = true
+		_, err = newClient(mtlsCfg).Authenticate(context.Background(), mtlsRequest(cert, ca.cert))
+		assert.NoError(t, err)
+	})
+}
+
+func mtlsRequest(chain ...*x509.Certificate) *authn.Request {
+	return &authn.Request{HTTPRequest: &http.Request{
+		TLS: &tls.ConnectionState{
+			PeerCertificates: chain[:1],
+			VerifiedChains:   [][]*x509.Certificate{chain},
+		},
+	}}
+}
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) issue(t *testing.T, serial int64, subject pkix.Name, email string) *x509.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if email != "" {
+		template.EmailAddresses = []string{email}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert
+}
+
+func (ca *testCA) crl(t *testing.T, serials ...int64) []byte {
+	t.Helper()
+
+	entries := make([]x509.RevocationListEntry, 0, len(serials))
+	for _, serial := range serials {
+		entries = append(entries, x509.RevocationListEntry{SerialNumber: big.NewInt(serial), RevocationTime: time.Now()})
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:                    big.NewInt(1),
+		ThisUpdate:                time.Now().Add(-time.Minute),
+		NextUpdate:                time.Now().Add(time.Hour),
+		RevokedCertificateEntries: entries,
+	}, ca.cert, ca.key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der})
+}
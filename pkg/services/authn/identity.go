@@ -78,6 +78,8 @@ type Identity struct {
 	IDToken string
 	// ExternalUID is the unique identifier for the entity in the external system.
 	ExternalUID string
+	// Impersonator is set when the session token was issued to another user impersonating the entity.
+	Impersonator *user.Impersonator
 
 	IDTokenClaims     *authn.Claims[authn.IDTokenClaims]
 	AccessTokenClaims *authn.Claims[authn.AccessTokenClaims]
@@ -305,6 +307,7 @@ func (i *Identity) SignedInUser() *user.SignedInUser {
 		IDToken:           i.IDToken,
 		IDTokenClaims:     i.IDTokenClaims,
 		AccessTokenClaims: i.AccessTokenClaims,
+		Impersonator:      i.Impersonator,
 		FallbackType:      i.Type,
 	}
 
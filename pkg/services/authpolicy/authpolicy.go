@@ -0,0 +1,118 @@
+package authpolicy
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+var (
+	ErrInvalidPolicy = errutil.BadRequest("authpolicy.invalid")
+	ErrSelfLockout   = errutil.BadRequest(
+		"authpolicy.self-lockout", errutil.WithPublicMessage("The policy would prevent you from accessing the organization"))
+	ErrAuthModuleNotAllowed = errutil.Forbidden(
+		"authpolicy.auth-module-not-allowed", errutil.WithPublicMessage("This sign in method is not allowed in the organization"))
+	ErrRoleNotSynced = errutil.Forbidden(
+		"authpolicy.role-not-synced", errutil.WithPublicMessage("The organization requires a role synced from an identity provider"))
+	ErrSessionExpired = errutil.Unauthorized(
+		"authpolicy.session-expired", errutil.WithPublicMessage("Your session has expired for this organization, sign in again"))
+)
+
+// AuthModules are the sign in methods a policy can allow
+var AuthModules = []string{
+	login.PasswordAuthModule,
+	login.PasswordlessAuthModule,
+	login.LDAPAuthModule,
+	login.SAMLAuthModule,
+	login.AuthProxyAuthModule,
+	login.JWTModule,
+	login.MTLSAuthModule,
+	login.AzureADAuthModule,
+	login.GoogleAuthModule,
+	login.GitLabAuthModule,
+	login.GithubAuthModule,
+	login.GenericOAuthModule,
+	login.GrafanaComAuthModule,
+	login.OktaAuthModule,
+}
+
+type Service interface {
+	// GetPolicy returns the policy of the org, orgs without policy get an empty one
+	GetPolicy(ctx context.Context, orgID int64) (*Policy, error)
+	SetPolicy(ctx context.Context, cmd *SetPolicyCommand) error
+}
+
+// Policy restricts how the users of an organization authenticate. The zero value applies the global settings.
+type Policy struct {
+	OrgID int64 `json:"orgId"`
+	// AllowedAuthModules are the sign in methods of the users of the org, empty allows every method
+	AllowedAuthModules []string `json:"allowedAuthModules"`
+	// SessionMaxLifetimeSeconds shortens login_maximum_lifetime_duration for the org, zero keeps it
+	SessionMaxLifetimeSeconds int64 `json:"sessionMaxLifetimeSeconds"`
+	// SessionMaxInactiveSeconds shortens login_maximum_inactive_lifetime_duration for the org, zero keeps it
+	SessionMaxInactiveSeconds int64 `json:"sessionMaxInactiveSeconds"`
+	// StrictRoleMapping requires the role of the users in the org to be synced from an identity provider
+	StrictRoleMapping bool `json:"strictRoleMapping"`
+	// AutoSignupDomains are the email domains of the users added to the org when they sign in
+	AutoSignupDomains []string `json:"autoSignupDomains"`
+	// AutoSignupRole is the role of the users added to the org
+	AutoSignupRole org.RoleType `json:"autoSignupRole,omitempty"`
+	Updated        time.Time    `json:"updated,omitempty"`
+}
+
+// AllowsAuthModule returns whether users signed in with the module can access the org
+func (p *Policy) AllowsAuthModule(module string) bool {
+	if len(p.AllowedAuthModules) == 0 {
+		return true
+	}
+	for _, allowed := range p.AllowedAuthModules {
+		if allowed == module {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsAutoSignup returns whether users with the email are added to the org when they sign in
+func (p *Policy) AllowsAutoSignup(email string) bool {
+	domain := EmailDomain(email)
+	if domain == "" {
+		return false
+	}
+	for _, allowed := range p.AutoSignupDomains {
+		if allowed == domain {
+			return true
+		}
+	}
+	return false
+}
+
+type SetPolicyCommand struct {
+	Policy
+	// AuthModule is the sign in method of the user updating the policy, the update is rejected if it would block them
+	AuthModule string `json:"-"`
+	// IsGrafanaAdmin users aren't subject to the policies of the orgs
+	IsGrafanaAdmin bool `json:"-"`
+}
+
+// EmailDomain returns the lowercased domain of the email, or an empty string when it has none
+func EmailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 || at == len(email)-1 {
+		return ""
+	}
+	return strings.ToLower(email[at+1:])
+}
+
+// IsExternalAuthModule returns whether the module authenticates users with an identity provider
+func IsExternalAuthModule(module string) bool {
+	switch module {
+	case "", login.PasswordAuthModule, login.PasswordlessAuthModule:
+		return false
+	}
+	return true
+}
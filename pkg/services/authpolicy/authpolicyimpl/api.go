@@ -0,0 +1,84 @@
+package authpolicyimpl
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authpolicy"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+
+	router.Group("/api/org", func(orgRoute routing.RouteRegister) {
+		orgRoute.Get("/auth-policy", authorize(ac.EvalPermission(ac.ActionOrgsRead)), routing.Wrap(s.GetCurrentOrgAuthPolicy))
+		orgRoute.Put("/auth-policy", authorize(ac.EvalPermission(ac.ActionOrgsWrite)), routing.Wrap(s.UpdateCurrentOrgAuthPolicy))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /org/auth-policy org getCurrentOrgAuthPolicy
+//
+// Get the authentication policy of the current organization.
+//
+// Responses:
+// 200: orgAuthPolicyResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) GetCurrentOrgAuthPolicy(c *contextmodel.ReqContext) response.Response {
+	policy, err := s.GetPolicy(c.Req.Context(), c.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get authentication policy", err)
+	}
+
+	return response.JSON(http.StatusOK, policy)
+}
+
+// swagger:route PUT /org/auth-policy org updateCurrentOrgAuthPolicy
+//
+// Update the authentication policy of the current organization.
+//
+// The policy can only restrict the global settings: session lifetimes can't exceed the global ones.
+// The update is rejected if the policy would block the sign in method of the caller, unless they are a Grafana server admin.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) UpdateCurrentOrgAuthPolicy(c *contextmodel.ReqContext) response.Response {
+	form := authpolicy.Policy{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	form.OrgID = c.GetOrgID()
+
+	if err := s.SetPolicy(c.Req.Context(), &authpolicy.SetPolicyCommand{
+		Policy:         form,
+		AuthModule:     c.SignedInUser.GetAuthenticatedBy(),
+		IsGrafanaAdmin: c.SignedInUser.GetIsGrafanaAdmin(),
+	}); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update authentication policy", err)
+	}
+
+	return response.Success("Authentication policy updated")
+}
+
+// swagger:parameters updateCurrentOrgAuthPolicy
+type UpdateCurrentOrgAuthPolicyParams struct {
+	// in:body
+	// required:true
+	Body authpolicy.Policy `json:"body"`
+}
+
+// swagger:response orgAuthPolicyResponse
+type OrgAuthPolicyResponse struct {
+	// in: body
+	Body authpolicy.Policy `json:"body"`
+}
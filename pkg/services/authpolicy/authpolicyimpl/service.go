@@ -0,0 +1,278 @@
+package authpolicyimpl
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	claims "github.com/grafana/authlib/types"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/authpolicy"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var _ authpolicy.Service = (*Service)(nil)
+
+// cacheTTL bounds how long an instance enforces a policy after it was updated on another instance
+const cacheTTL = time.Minute
+
+func ProvideService(
+	cfg *setting.Cfg, sqlStore db.DB, router routing.RouteRegister, accessControl ac.AccessControl,
+	authnService authn.Service, orgService org.Service, tracer trace.Tracer,
+) *Service {
+	s := &Service{
+		cfg:        cfg,
+		store:      &xormStore{db: sqlStore},
+		orgService: orgService,
+		policies:   localcache.New(cacheTTL, 2*cacheTTL),
+		now:        time.Now,
+		log:        log.New("authpolicy"),
+		tracer:     tracer,
+	}
+
+	if cfg.OrgAuthPolicy.Enabled {
+		// Sign up has to be allowed before the user is synced, the user is added to the orgs
+		// once it exists and before the org roles are synced.
+		authnService.RegisterPostAuthHook(s.allowSignUpHook, 5)
+		authnService.RegisterPostAuthHook(s.autoSignupHook, 35)
+		// The policy of the org is enforced once the signed in user is fetched so the org of the request is known
+		authnService.RegisterPostAuthHook(s.enforceHook, 106)
+		s.registerRoutes(router, accessControl)
+	}
+
+	return s
+}
+
+type Service struct {
+	cfg        *setting.Cfg
+	store      store
+	orgService org.Service
+	// policies caches the policies of the orgs
+	policies *localcache.CacheService
+	now      func() time.Time
+	log      log.Logger
+	tracer   trace.Tracer
+}
+
+func (s *Service) GetPolicy(ctx context.Context, orgID int64) (*authpolicy.Policy, error) {
+	if cached, ok := s.policies.Get(cacheKey(orgID)); ok {
+		return cached.(*authpolicy.Policy), nil
+	}
+
+	policy, err := s.store.GetPolicy(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	s.policies.Set(cacheKey(orgID), policy, 0)
+	return policy, nil
+}
+
+func (s *Service) SetPolicy(ctx context.Context, cmd *authpolicy.SetPolicyCommand) error {
+	ctx, span := s.tracer.Start(ctx, "authpolicy.SetPolicy")
+	defer span.End()
+
+	policy := cmd.Policy
+	if err := s.validate(&policy); err != nil {
+		return err
+	}
+
+	if !cmd.IsGrafanaAdmin {
+		module := authModule(cmd.AuthModule)
+		if !policy.AllowsAuthModule(module) {
+			return authpolicy.ErrSelfLockout.Errorf("sign in method %s is not allowed by the policy", module)
+		}
+		if policy.StrictRoleMapping && !authpolicy.IsExternalAuthModule(module) {
+			return authpolicy.ErrSelfLockout.Errorf("sign in method %s doesn't sync org roles", module)
+		}
+	}
+
+	policy.Updated = s.now()
+	if err := s.store.SavePolicy(ctx, &policy); err != nil {
+		return err
+	}
+	s.policies.Delete(cacheKey(policy.OrgID))
+	return nil
+}
+
+// validate normalizes the policy and checks it can only restrict the global settings
+func (s *Service) validate(policy *authpolicy.Policy) error {
+	modules := make([]string, 0, len(policy.AllowedAuthModules))
+	for _, module := range policy.AllowedAuthModules {
+		if !slices.Contains(authpolicy.AuthModules, module) {
+			return authpolicy.ErrInvalidPolicy.Errorf("unknown sign in method %q", module)
+		}
+		if !slices.Contains(modules, module) {
+			modules = append(modules, module)
+		}
+	}
+	policy.AllowedAuthModules = modules
+
+	if policy.SessionMaxLifetimeSeconds < 0 || time.Duration(policy.SessionMaxLifetimeSeconds)*time.Second > s.cfg.LoginMaxLifetime {
+		return authpolicy.ErrInvalidPolicy.Errorf("sessionMaxLifetimeSeconds must be between 0 and %d", int64(s.cfg.LoginMaxLifetime.Seconds()))
+	}
+	if policy.SessionMaxInactiveSeconds < 0 || time.Duration(policy.SessionMaxInactiveSeconds)*time.Second > s.cfg.LoginMaxInactiveLifetime {
+		return authpolicy.ErrInvalidPolicy.Errorf("sessionMaxInactiveSeconds must be between 0 and %d", int64(s.cfg.LoginMaxInactiveLifetime.Seconds()))
+	}
+
+	domains := make([]string, 0, len(policy.AutoSignupDomains))
+	for _, domain := range policy.AutoSignupDomains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain == "" || strings.ContainsAny(domain, "@ ") {
+			return authpolicy.ErrInvalidPolicy.Errorf("invalid auto signup domain %q", domain)
+		}
+		if !slices.Contains(domains, domain) {
+			domains = append(domains, domain)
+		}
+	}
+	policy.AutoSignupDomains = domains
+
+	if len(domains) == 0 {
+		policy.AutoSignupRole = ""
+		return nil
+	}
+	if policy.AutoSignupRole == "" {
+		policy.AutoSignupRole = org.RoleViewer
+	}
+	if !policy.AutoSignupRole.IsValid() {
+		return authpolicy.ErrInvalidPolicy.Errorf("invalid auto signup role %q", policy.AutoSignupRole)
+	}
+	return nil
+}
+
+// allowSignUpHook lets users sign up when their email domain is allowed by an org
+func (s *Service) allowSignUpHook(ctx context.Context, id *authn.Identity, _ *authn.Request) error {
+	if !id.ClientParams.SyncUser || id.ClientParams.AllowSignUp || id.Email == "" {
+		return nil
+	}
+
+	policies, err := s.autoSignupPolicies(ctx, id.Email)
+	if err != nil {
+		return err
+	}
+	if len(policies) > 0 {
+		id.ClientParams.AllowSignUp = true
+	}
+	return nil
+}
+
+// autoSignupHook adds the users to the orgs allowing their email domain. When the org roles are synced from
+// the identity provider, the orgs are added to the synced roles so the users aren't removed from them.
+func (s *Service) autoSignupHook(ctx context.Context, id *authn.Identity, _ *authn.Request) error {
+	if !id.ClientParams.SyncUser || id.Email == "" || !id.IsIdentityType(claims.TypeUser) {
+		return nil
+	}
+
+	ctx, span := s.tracer.Start(ctx, "authpolicy.autoSignupHook")
+	defer span.End()
+
+	policies, err := s.autoSignupPolicies(ctx, id.Email)
+	if err != nil || len(policies) == 0 {
+		return err
+	}
+
+	userID, err := id.GetInternalID()
+	if err != nil {
+		return err
+	}
+
+	for _, policy := range policies {
+		if id.ClientParams.SyncOrgRoles {
+			if id.OrgRoles == nil {
+				id.OrgRoles = map[int64]org.RoleType{}
+			}
+			if _, ok := id.OrgRoles[policy.OrgID]; !ok {
+				id.OrgRoles[policy.OrgID] = policy.AutoSignupRole
+			}
+			continue
+		}
+
+		err := s.orgService.AddOrgUser(ctx, &org.AddOrgUserCommand{OrgID: policy.OrgID, UserID: userID, Role: policy.AutoSignupRole})
+		if errors.Is(err, org.ErrOrgUserAlreadyAdded) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		s.log.FromContext(ctx).Info("Added user to organization allowing their email domain", "userId", userID, "orgId", policy.OrgID, "role", policy.AutoSignupRole)
+	}
+	return nil
+}
+
+func (s *Service) autoSignupPolicies(ctx context.Context, email string) ([]*authpolicy.Policy, error) {
+	if authpolicy.EmailDomain(email) == "" {
+		return nil, nil
+	}
+
+	policies, err := s.store.ListAutoSignupPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matching := make([]*authpolicy.Policy, 0)
+	for _, policy := range policies {
+		if policy.AllowsAutoSignup(email) {
+			matching = append(matching, policy)
+		}
+	}
+	return matching, nil
+}
+
+// enforceHook rejects the requests and logins of users that don't comply with the policy of the org
+func (s *Service) enforceHook(ctx context.Context, id *authn.Identity, _ *authn.Request) error {
+	if !id.IsIdentityType(claims.TypeUser) || id.GetIsGrafanaAdmin() {
+		return nil
+	}
+
+	ctx, span := s.tracer.Start(ctx, "authpolicy.enforceHook")
+	defer span.End()
+
+	policy, err := s.GetPolicy(ctx, id.GetOrgID())
+	if err != nil {
+		return err
+	}
+
+	module := authModule(id.AuthenticatedBy)
+	if !policy.AllowsAuthModule(module) {
+		return authpolicy.ErrAuthModuleNotAllowed.Errorf("sign in method %s is not allowed in org %d", module, policy.OrgID)
+	}
+	// The clients authenticating with an identity provider tell whether they sync the org roles,
+	// sessions only tell which provider the user signed in with.
+	if policy.StrictRoleMapping && (!authpolicy.IsExternalAuthModule(module) || (id.ClientParams.SyncUser && !id.ClientParams.SyncOrgRoles)) {
+		return authpolicy.ErrRoleNotSynced.Errorf("org %d requires roles synced from an identity provider", policy.OrgID)
+	}
+
+	if id.SessionToken == nil {
+		return nil
+	}
+	now := s.now()
+	if maxLifetime := time.Duration(policy.SessionMaxLifetimeSeconds) * time.Second; maxLifetime > 0 && now.Sub(time.Unix(id.SessionToken.CreatedAt, 0)) > maxLifetime {
+		return authpolicy.ErrSessionExpired.Errorf("session is older than the max lifetime of org %d", policy.OrgID)
+	}
+	if maxInactive := time.Duration(policy.SessionMaxInactiveSeconds) * time.Second; maxInactive > 0 && now.Sub(time.Unix(id.SessionToken.RotatedAt, 0)) > maxInactive {
+		return authpolicy.ErrSessionExpired.Errorf("session has been inactive longer than allowed by org %d", policy.OrgID)
+	}
+	return nil
+}
+
+// authModule returns the sign in method of users, users without auth info signed in with a password
+func authModule(module string) string {
+	if module == "" {
+		return login.PasswordAuthModule
+	}
+	return module
+}
+
+func cacheKey(orgID int64) string {
+	return "org-" + strconv.FormatInt(orgID, 10)
+}
@@ -0,0 +1,238 @@
+package authpolicyimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	claims "github.com/grafana/authlib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/models/usertoken"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/authpolicy"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService_SetPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should store the normalized policy", func(t *testing.T) {
+		s, store := setupTestService(t)
+
+		err := s.SetPolicy(ctx, &authpolicy.SetPolicyCommand{
+			Policy: authpolicy.Policy{
+				OrgID:              1,
+				AllowedAuthModules: []string{login.OktaAuthModule, login.OktaAuthModule},
+				AutoSignupDomains:  []string{" Example.com "},
+			},
+			AuthModule: login.OktaAuthModule,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{login.OktaAuthModule}, store.policies[1].AllowedAuthModules)
+		assert.Equal(t, []string{"example.com"}, store.policies[1].AutoSignupDomains)
+		assert.Equal(t, org.RoleViewer, store.policies[1].AutoSignupRole)
+	})
+
+	t.Run("should only shorten the global session lifetimes", func(t *testing.T) {
+		s, _ := setupTestService(t)
+
+		err := s.SetPolicy(ctx, &authpolicy.SetPolicyCommand{Policy: authpolicy.Policy{OrgID: 1, SessionMaxLifetimeSeconds: int64((31 * 24 * time.Hour).Seconds())}})
+		assert.ErrorIs(t, err, authpolicy.ErrInvalidPolicy)
+
+		err = s.SetPolicy(ctx, &authpolicy.SetPolicyCommand{Policy: authpolicy.Policy{OrgID: 1, SessionMaxInactiveSeconds: -1}})
+		assert.ErrorIs(t, err, authpolicy.ErrInvalidPolicy)
+	})
+
+	t.Run("should reject unknown sign in methods", func(t *testing.T) {
+		s, _ := setupTestService(t)
+
+		err := s.SetPolicy(ctx, &authpolicy.SetPolicyCommand{Policy: authpolicy.Policy{OrgID: 1, AllowedAuthModules: []string{"kerberos"}}})
+		assert.ErrorIs(t, err, authpolicy.ErrInvalidPolicy)
+	})
+
+	t.Run("should not let users lock themselves out", func(t *testing.T) {
+		s, store := setupTestService(t)
+
+		err := s.SetPolicy(ctx, &authpolicy.SetPolicyCommand{Policy: authpolicy.Policy{OrgID: 1, AllowedAuthModules: []string{login.OktaAuthModule}}})
+		assert.ErrorIs(t, err, authpolicy.ErrSelfLockout)
+
+		err = s.SetPolicy(ctx, &authpolicy.SetPolicyCommand{Policy: authpolicy.Policy{OrgID: 1, StrictRoleMapping: true}, AuthModule: login.PasswordAuthModule})
+		assert.ErrorIs(t, err, authpolicy.ErrSelfLockout)
+		assert.Empty(t, store.policies)
+
+		err = s.SetPolicy(ctx, &authpolicy.SetPolicyCommand{Policy: authpolicy.Policy{OrgID: 1, StrictRoleMapping: true}, IsGrafanaAdmin: true})
+		require.NoError(t, err)
+	})
+}
+
+func TestService_enforceHook(t *testing.T) {
+	ctx := context.Background()
+	newIdentity := func(module string) *authn.Identity {
+		return &authn.Identity{ID: "1", Type: claims.TypeUser, OrgID: 1, AuthenticatedBy: module}
+	}
+
+	t.Run("should allow every sign in method without policy", func(t *testing.T) {
+		s, _ := setupTestService(t)
+
+		assert.NoError(t, s.enforceHook(ctx, newIdentity(""), &authn.Request{}))
+	})
+
+	t.Run("should reject sign in methods not allowed by the org", func(t *testing.T) {
+		s, store := setupTestService(t)
+		store.policies[1] = &authpolicy.Policy{OrgID: 1, AllowedAuthModules: []string{login.OktaAuthModule}}
+
+		assert.NoError(t, s.enforceHook(ctx, newIdentity(login.OktaAuthModule), &authn.Request{}))
+		assert.ErrorIs(t, s.enforceHook(ctx, newIdentity(""), &authn.Request{}), authpolicy.ErrAuthModuleNotAllowed)
+
+		admin := newIdentity("")
+		isAdmin := true
+		admin.IsGrafanaAdmin = &isAdmin
+		assert.NoError(t, s.enforceHook(ctx, admin, &authn.Request{}))
+	})
+
+	t.Run("should require synced org roles when strict", func(t *testing.T) {
+		s, store := setupTestService(t)
+		store.policies[1] = &authpolicy.Policy{OrgID: 1, StrictRoleMapping: true}
+
+		synced := newIdentity(login.OktaAuthModule)
+		synced.ClientParams = authn.ClientParams{SyncUser: true, SyncOrgRoles: true}
+		assert.NoError(t, s.enforceHook(ctx, synced, &authn.Request{}))
+
+		notSynced := newIdentity(login.OktaAuthModule)
+		notSynced.ClientParams = authn.ClientParams{SyncUser: true}
+		assert.ErrorIs(t, s.enforceHook(ctx, notSynced, &authn.Request{}), authpolicy.ErrRoleNotSynced)
+
+		assert.ErrorIs(t, s.enforceHook(ctx, newIdentity(login.PasswordAuthModule), &authn.Request{}), authpolicy.ErrRoleNotSynced)
+	})
+
+	t.Run("should expire sessions past the lifetimes of the org", func(t *testing.T) {
+		s, store := setupTestService(t)
+		store.policies[1] = &authpolicy.Policy{OrgID: 1, SessionMaxLifetimeSeconds: 3600, SessionMaxInactiveSeconds: 600}
+		now := s.now()
+
+		id := newIdentity(login.PasswordAuthModule)
+		id.SessionToken = &usertoken.UserToken{CreatedAt: now.Add(-30 * time.Minute).Unix(), RotatedAt: now.Add(-5 * time.Minute).Unix()}
+		assert.NoError(t, s.enforceHook(ctx, id, &authn.Request{}))
+
+		id.SessionToken.RotatedAt = now.Add(-15 * time.Minute).Unix()
+		assert.ErrorIs(t, s.enforceHook(ctx, id, &authn.Request{}), authpolicy.ErrSessionExpired)
+
+		id.SessionToken = &usertoken.UserToken{CreatedAt: now.Add(-2 * time.Hour).Unix(), RotatedAt: now.Unix()}
+		assert.ErrorIs(t, s.enforceHook(ctx, id, &authn.Request{}), authpolicy.ErrSessionExpired)
+	})
+}
+
+func TestService_autoSignupHooks(t *testing.T) {
+	ctx := context.Background()
+	newIdentity := func(email string, syncOrgRoles bool) *authn.Identity {
+		return &authn.Identity{
+			ID:           "1",
+			Type:         claims.TypeUser,
+			Email:        email,
+			OrgRoles:     map[int64]org.RoleType{1: org.RoleEditor},
+			ClientParams: authn.ClientParams{SyncUser: true, SyncOrgRoles: syncOrgRoles},
+		}
+	}
+
+	setup := func(t *testing.T) (*Service, *fakeOrgService) {
+		s, store := setupTestService(t)
+		store.policies[2] = &authpolicy.Policy{OrgID: 2, AutoSignupDomains: []string{"example.com"}, AutoSignupRole: org.RoleViewer}
+		orgService := &fakeOrgService{}
+		s.orgService = orgService
+		return s, orgService
+	}
+
+	t.Run("should allow sign up for allowed domains", func(t *testing.T) {
+		s, _ := setup(t)
+
+		id := newIdentity("jane@Example.com", false)
+		require.NoError(t, s.allowSignUpHook(ctx, id, &authn.Request{}))
+		assert.True(t, id.ClientParams.AllowSignUp)
+
+		id = newIdentity("jane@other.com", false)
+		require.NoError(t, s.allowSignUpHook(ctx, id, &authn.Request{}))
+		assert.False(t, id.ClientParams.AllowSignUp)
+	})
+
+	t.Run("should add the org to the synced org roles", func(t *testing.T) {
+		s, orgService := setup(t)
+
+		id := newIdentity("jane@example.com", true)
+		require.NoError(t, s.autoSignupHook(ctx, id, &authn.Request{}))
+		assert.Equal(t, map[int64]org.RoleType{1: org.RoleEditor, 2: org.RoleViewer}, id.OrgRoles)
+		assert.Empty(t, orgService.added)
+	})
+
+	t.Run("should add the user to the org when org roles aren't synced", func(t *testing.T) {
+		s, orgService := setup(t)
+
+		require.NoError(t, s.autoSignupHook(ctx, newIdentity("jane@example.com", false), &authn.Request{}))
+		require.Len(t, orgService.added, 1)
+		assert.Equal(t, &org.AddOrgUserCommand{OrgID: 2, UserID: 1, Role: org.RoleViewer}, orgService.added[0])
+	})
+}
+
+func setupTestService(t *testing.T) (*Service, *fakeStore) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.OrgAuthPolicy = setting.OrgAuthPolicySettings{Enabled: true}
+	cfg.LoginMaxLifetime = 30 * 24 * time.Hour
+	cfg.LoginMaxInactiveLifetime = 7 * 24 * time.Hour
+
+	now := time.Now()
+	store := &fakeStore{policies: map[int64]*authpolicy.Policy{}}
+	return &Service{
+		cfg:        cfg,
+		store:      store,
+		orgService: &orgtest.FakeOrgService{},
+		policies:   localcache.New(0, 0),
+		now:        func() time.Time { return now },
+		log:        log.NewNopLogger(),
+		tracer:     tracing.InitializeTracerForTest(),
+	}, store
+}
+
+type fakeStore struct {
+	policies map[int64]*authpolicy.Policy
+}
+
+func (f *fakeStore) GetPolicy(ctx context.Context, orgID int64) (*authpolicy.Policy, error) {
+	if policy, ok := f.policies[orgID]; ok {
+		return policy, nil
+	}
+	return &authpolicy.Policy{OrgID: orgID}, nil
+}
+
+func (f *fakeStore) SavePolicy(ctx context.Context, policy *authpolicy.Policy) error {
+	f.policies[policy.OrgID] = policy
+	return nil
+}
+
+func (f *fakeStore) ListAutoSignupPolicies(ctx context.Context) ([]*authpolicy.Policy, error) {
+	policies := make([]*authpolicy.Policy, 0)
+	for _, policy := range f.policies {
+		if len(policy.AutoSignupDomains) > 0 {
+			policies = append(policies, policy)
+		}
+	}
+	return policies, nil
+}
+
+type fakeOrgService struct {
+	orgtest.FakeOrgService
+	added []*org.AddOrgUserCommand
+}
+
+func (f *fakeOrgService) AddOrgUser(ctx context.Context, cmd *org.AddOrgUserCommand) error {
+	f.added = append(f.added, cmd)
+	return nil
+}
@@ -0,0 +1,147 @@
+package authpolicyimpl
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/authpolicy"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+// orgAuthPolicy is the stored form of a policy, its lists are encoded as JSON and empty lists as empty strings
+type orgAuthPolicy struct {
+	ID                 int64  `xorm:"pk autoincr 'id'"`
+	OrgID              int64  `xorm:"org_id"`
+	AllowedAuthModules string `xorm:"allowed_auth_modules"`
+	SessionMaxLifetime int64  `xorm:"session_max_lifetime"`
+	SessionMaxInactive int64  `xorm:"session_max_inactive"`
+	StrictRoleMapping  bool   `xorm:"strict_role_mapping"`
+	AutoSignupDomains  string `xorm:"auto_signup_domains"`
+	AutoSignupRole     string `xorm:"auto_signup_role"`
+	Updated            time.Time
+}
+
+func (orgAuthPolicy) TableName() string {
+	return "org_auth_policy"
+}
+
+func (p *orgAuthPolicy) toPolicy() (*authpolicy.Policy, error) {
+	policy := &authpolicy.Policy{
+		OrgID:                     p.OrgID,
+		SessionMaxLifetimeSeconds: p.SessionMaxLifetime,
+		SessionMaxInactiveSeconds: p.SessionMaxInactive,
+		StrictRoleMapping:         p.StrictRoleMapping,
+		AutoSignupRole:            org.RoleType(p.AutoSignupRole),
+		Updated:                   p.Updated,
+	}
+	var err error
+	if policy.AllowedAuthModules, err = decodeList(p.AllowedAuthModules); err != nil {
+		return nil, err
+	}
+	if policy.AutoSignupDomains, err = decodeList(p.AutoSignupDomains); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+type store interface {
+	// GetPolicy returns an empty policy when the org doesn't have one
+	GetPolicy(ctx context.Context, orgID int64) (*authpolicy.Policy, error)
+	SavePolicy(ctx context.Context, policy *authpolicy.Policy) error
+	// ListAutoSignupPolicies returns the policies with auto signup domains
+	ListAutoSignupPolicies(ctx context.Context) ([]*authpolicy.Policy, error)
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (s *xormStore) GetPolicy(ctx context.Context, orgID int64) (*authpolicy.Policy, error) {
+	policy := &authpolicy.Policy{OrgID: orgID, AllowedAuthModules: []string{}, AutoSignupDomains: []string{}}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var stored orgAuthPolicy
+		has, err := sess.Where("org_id = ?", orgID).Get(&stored)
+		if err != nil || !has {
+			return err
+		}
+		policy, err = stored.toPolicy()
+		return err
+	})
+	return policy, err
+}
+
+func (s *xormStore) SavePolicy(ctx context.Context, policy *authpolicy.Policy) error {
+	modules, err := encodeList(policy.AllowedAuthModules)
+	if err != nil {
+		return err
+	}
+	domains, err := encodeList(policy.AutoSignupDomains)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		stored := orgAuthPolicy{
+			OrgID:              policy.OrgID,
+			AllowedAuthModules: modules,
+			SessionMaxLifetime: policy.SessionMaxLifetimeSeconds,
+			SessionMaxInactive: policy.SessionMaxInactiveSeconds,
+			StrictRoleMapping:  policy.StrictRoleMapping,
+			AutoSignupDomains:  domains,
+			AutoSignupRole:     string(policy.AutoSignupRole),
+			Updated:            policy.Updated,
+		}
+
+		existing := &orgAuthPolicy{}
+		has, err := sess.Where("org_id = ?", policy.OrgID).Get(existing)
+		if err != nil {
+			return err
+		}
+		if has {
+			stored.ID = existing.ID
+			_, err = sess.ID(stored.ID).AllCols().Update(&stored)
+			return err
+		}
+
+		_, err = sess.Insert(&stored)
+		return err
+	})
+}
+
+func (s *xormStore) ListAutoSignupPolicies(ctx context.Context) ([]*authpolicy.Policy, error) {
+	policies := make([]*authpolicy.Policy, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		stored := make([]orgAuthPolicy, 0)
+		if err := sess.Where("auto_signup_domains <> ''").Find(&stored); err != nil {
+			return err
+		}
+		for i := range stored {
+			policy, err := stored[i].toPolicy()
+			if err != nil {
+				return err
+			}
+			policies = append(policies, policy)
+		}
+		return nil
+	})
+	return policies, err
+}
+
+func encodeList(values []string) (string, error) {
+	if len(values) == 0 {
+		return "", nil
+	}
+	encoded, err := json.Marshal(values)
+	return string(encoded), err
+}
+
+func decodeList(encoded string) ([]string, error) {
+	values := []string{}
+	if encoded == "" {
+		return values, nil
+	}
+	err := json.Unmarshal([]byte(encoded), &values)
+	return values, err
+}
@@ -0,0 +1,68 @@
+package authpolicyimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/authpolicy"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	store := &xormStore{db: db.InitTestDB(t)}
+	updated := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("should return an empty policy when the org doesn't have one", func(t *testing.T) {
+		policy, err := store.GetPolicy(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), policy.OrgID)
+		assert.Empty(t, policy.AllowedAuthModules)
+		assert.NotNil(t, policy.AutoSignupDomains)
+	})
+
+	t.Run("should save and update the policy of an org", func(t *testing.T) {
+		require.NoError(t, store.SavePolicy(ctx, &authpolicy.Policy{
+			OrgID: 1, AllowedAuthModules: []string{"oauth_github"}, SessionMaxLifetimeSeconds: 3600, Updated: updated,
+		}))
+		require.NoError(t, store.SavePolicy(ctx, &authpolicy.Policy{
+			OrgID: 1, AllowedAuthModules: []string{"oauth_github", "saml"}, SessionMaxInactiveSeconds: 600,
+			StrictRoleMapping: true, Updated: updated.Add(time.Hour),
+		}))
+
+		policy, err := store.GetPolicy(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"oauth_github", "saml"}, policy.AllowedAuthModules)
+		assert.Zero(t, policy.SessionMaxLifetimeSeconds, "every column is replaced")
+		assert.Equal(t, int64(600), policy.SessionMaxInactiveSeconds)
+		assert.True(t, policy.StrictRoleMapping)
+		assert.Empty(t, policy.AutoSignupDomains)
+	})
+
+	t.Run("should only list the policies with auto signup domains", func(t *testing.T) {
+		require.NoError(t, store.SavePolicy(ctx, &authpolicy.Policy{
+			OrgID: 2, AutoSignupDomains: []string{"example.com", "example.org"}, AutoSignupRole: org.RoleEditor, Updated: updated,
+		}))
+		require.NoError(t, store.SavePolicy(ctx, &authpolicy.Policy{OrgID: 3, AutoSignupDomains: []string{}, Updated: updated}))
+
+		policies, err := store.ListAutoSignupPolicies(ctx)
+		require.NoError(t, err)
+		require.Len(t, policies, 1)
+		assert.Equal(t, int64(2), policies[0].OrgID)
+		assert.Equal(t, []string{"example.com", "example.org"}, policies[0].AutoSignupDomains)
+		assert.Equal(t, org.RoleEditor, policies[0].AutoSignupRole)
+	})
+}
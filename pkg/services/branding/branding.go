@@ -0,0 +1,98 @@
+package branding
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+var (
+	ErrNotFound = errutil.NotFound(
+		"branding.not-found", errutil.WithPublicMessage("Branding not found"))
+	ErrInvalidImage = errutil.BadRequest(
+		"branding.invalid-image", errutil.WithPublicMessage("Images must be a Grafana path, an http(s) URL or a base64 data URI of a PNG, JPEG, GIF, WebP or SVG image of at most 1 MiB"))
+	ErrInvalidFooterLink = errutil.BadRequest(
+		"branding.invalid-footer-link", errutil.WithPublicMessage("Footer links require a text, a Grafana path or an http(s) URL, and a target of _blank or _self"))
+	ErrTooManyFooterLinks = errutil.BadRequest(
+		"branding.too-many-footer-links", errutil.WithPublicMessage("At most 10 footer links are allowed"))
+	ErrTextTooLong = errutil.BadRequest(
+		"branding.text-too-long", errutil.WithPublicMessage("Titles must be at most 190 characters"))
+	ErrInvalidEmailTemplate = errutil.BadRequest(
+		"branding.invalid-email-template", errutil.WithPublicMessage("Invalid email template"))
+	ErrOrgEmailTemplates = errutil.BadRequest(
+		"branding.org-email-templates", errutil.WithPublicMessage("Email templates can only be customized for the whole instance"))
+)
+
+// The images of a branding, named as in the image URLs
+const (
+	ImageLoginLogo       = "loginLogo"
+	ImageLoginBackground = "loginBackground"
+	ImageMenuLogo        = "menuLogo"
+	ImageLoadingLogo     = "loadingLogo"
+)
+
+// Service stores the branding of the instance, and of the organizations when they're allowed to override it
+type Service interface {
+	// GetBranding returns the branding stored for the organization, or for the instance when orgID is 0
+	GetBranding(ctx context.Context, orgID int64) (*Branding, error)
+	// GetResolvedBranding returns the branding of the instance with the overrides of the organization
+	GetResolvedBranding(ctx context.Context, orgID int64) (*Branding, error)
+	UpdateBranding(ctx context.Context, cmd *UpdateBrandingCommand) (*Branding, error)
+	// DeleteBranding restores the default branding of the organization, or of the instance when orgID is 0
+	DeleteBranding(ctx context.Context, orgID int64) error
+	// GetEmailTemplate returns the customized source of the email template, such as reset_password.html
+	GetEmailTemplate(ctx context.Context, name string) (string, bool, error)
+}
+
+// Branding customizes the look of Grafana. The empty fields keep the defaults, or the values of the instance for an
+// organization.
+type Branding struct {
+	OrgID         int64  `json:"orgId"`
+	AppTitle      string `json:"appTitle,omitempty"`
+	LoginTitle    string `json:"loginTitle,omitempty"`
+	LoginSubtitle string `json:"loginSubtitle,omitempty"`
+	// The images are either a Grafana path, an http(s) URL, or a base64 data URI served by /api/branding/images
+	LoginLogo       string       `json:"loginLogo,omitempty"`
+	LoginBackground string       `json:"loginBackground,omitempty"`
+	MenuLogo        string       `json:"menuLogo,omitempty"`
+	LoadingLogo     string       `json:"loadingLogo,omitempty"`
+	FooterLinks     []FooterLink `json:"footerLinks,omitempty"`
+	// EmailTemplates replaces the email templates by name, such as reset_password.html. Only the instance can set them.
+	EmailTemplates map[string]string `json:"emailTemplates,omitempty"`
+	// Version changes with every update, the URLs of the images include it
+	Version int64     `json:"version,omitempty"`
+	Updated time.Time `json:"updated,omitempty"`
+}
+
+type FooterLink struct {
+	Text   string `json:"text"`
+	URL    string `json:"url"`
+	Icon   string `json:"icon,omitempty"`
+	Target string `json:"target,omitempty"`
+}
+
+// Images returns the images of the branding by name
+func (b *Branding) Images() map[string]string {
+	return map[string]string{
+		ImageLoginLogo:       b.LoginLogo,
+		ImageLoginBackground: b.LoginBackground,
+		ImageMenuLogo:        b.MenuLogo,
+		ImageLoadingLogo:     b.LoadingLogo,
+	}
+}
+
+// UpdateBrandingCommand replaces the branding of the organization, or of the instance when OrgID is 0
+type UpdateBrandingCommand struct {
+	OrgID           int64             `json:"-"`
+	UserID          int64             `json:"-"`
+	AppTitle        string            `json:"appTitle"`
+	LoginTitle      string            `json:"loginTitle"`
+	LoginSubtitle   string            `json:"loginSubtitle"`
+	LoginLogo       string            `json:"loginLogo"`
+	LoginBackground string            `json:"loginBackground"`
+	MenuLogo        string            `json:"menuLogo"`
+	LoadingLogo     string            `json:"loadingLogo"`
+	FooterLinks     []FooterLink      `json:"footerLinks"`
+	EmailTemplates  map[string]string `json:"emailTemplates"`
+}
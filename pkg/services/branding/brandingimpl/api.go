@@ -0,0 +1,241 @@
+package brandingimpl
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/branding"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// imageCSP prevents the scripts of the SVG images from running when an image is opened directly
+const imageCSP = "default-src 'none'; style-src 'unsafe-inline'; sandbox"
+
+var scopeSettingsBranding = ac.Scope("settings", "branding", "*")
+
+func (s *Service) registerRoutes(router routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+
+	router.Group("/api/admin/branding", func(brandingRoute routing.RouteRegister) {
+		brandingRoute.Get("/", authorize(ac.EvalPermission(ac.ActionSettingsRead, scopeSettingsBranding)), routing.Wrap(s.GetInstanceBranding))
+		brandingRoute.Put("/", authorize(ac.EvalPermission(ac.ActionSettingsWrite, scopeSettingsBranding)), routing.Wrap(s.UpdateInstanceBranding))
+		brandingRoute.Delete("/", authorize(ac.EvalPermission(ac.ActionSettingsWrite, scopeSettingsBranding)), routing.Wrap(s.DeleteInstanceBranding))
+	}, middleware.ReqSignedIn)
+
+	if s.cfg.Branding.AllowOrgBranding {
+		router.Group("/api/org/branding", func(brandingRoute routing.RouteRegister) {
+			brandingRoute.Get("/", authorize(ac.EvalPermission(ac.ActionOrgsPreferencesRead)), routing.Wrap(s.GetOrgBranding))
+			brandingRoute.Put("/", authorize(ac.EvalPermission(ac.ActionOrgsPreferencesWrite)), routing.Wrap(s.UpdateOrgBranding))
+			brandingRoute.Delete("/", authorize(ac.EvalPermission(ac.ActionOrgsPreferencesWrite)), routing.Wrap(s.DeleteOrgBranding))
+		}, middleware.ReqSignedIn)
+	}
+
+	// The images are shown on the login page, before the user signs in
+	router.Get("/api/branding/images/:orgId/:name", routing.Wrap(s.GetBrandingImage))
+}
+
+// swagger:route GET /admin/branding admin getInstanceBranding
+//
+// Get the branding of the instance.
+//
+// Returns the branding stored for the whole instance, with its uploaded images and email templates.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getBrandingResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) GetInstanceBranding(c *contextmodel.ReqContext) response.Response {
+	return s.getBranding(c, 0)
+}
+
+// swagger:route PUT /admin/branding admin updateInstanceBranding
+//
+// Update the branding of the instance.
+//
+// Replaces the branding of the whole instance. The empty fields keep the defaults of Grafana.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getBrandingResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) UpdateInstanceBranding(c *contextmodel.ReqContext) response.Response {
+	return s.updateBranding(c, 0)
+}
+
+// swagger:route DELETE /admin/branding admin deleteInstanceBranding
+//
+// Delete the branding of the instance.
+//
+// Restores the defaults of Grafana. The brandings of the organizations are kept.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) DeleteInstanceBranding(c *contextmodel.ReqContext) response.Response {
+	return s.deleteBranding(c, 0)
+}
+
+// swagger:route GET /org/branding org getOrgBranding
+//
+// Get the branding of the current organization.
+//
+// Returns the overrides of the branding of the instance stored for the organization. Requires
+// [branding] allow_org_branding to be set.
+//
+// Responses:
+// 200: getBrandingResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) GetOrgBranding(c *contextmodel.ReqContext) response.Response {
+	return s.getBranding(c, c.GetOrgID())
+}
+
+// swagger:route PUT /org/branding org updateOrgBranding
+//
+// Update the branding of the current organization.
+//
+// Replaces the overrides of the organization. The empty fields keep the values of the branding of the instance.
+// The email templates can't be customized for an organization.
+//
+// Responses:
+// 200: getBrandingResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) UpdateOrgBranding(c *contextmodel.ReqContext) response.Response {
+	return s.updateBranding(c, c.GetOrgID())
+}
+
+// swagger:route DELETE /org/branding org deleteOrgBranding
+//
+// Delete the branding of the current organization.
+//
+// Restores the branding of the instance for the organization.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) DeleteOrgBranding(c *contextmodel.ReqContext) response.Response {
+	return s.deleteBranding(c, c.GetOrgID())
+}
+
+// swagger:route GET /branding/images/{orgId}/{name} branding getBrandingImage
+//
+// Get an image of a branding.
+//
+// Serves an image uploaded as a data URI, the org is 0 for the images of the instance. The image URLs of the
+// frontend settings include the version of the branding, so the images are cached by the browsers.
+//
+// Responses:
+// 200: getBrandingImageResponse
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) GetBrandingImage(c *contextmodel.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil || orgID != 0 && !s.cfg.Branding.AllowOrgBranding {
+		return response.Error(http.StatusNotFound, "Image not found", nil)
+	}
+
+	b, err := s.GetBranding(c.Req.Context(), orgID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get the branding", err)
+	}
+	image := b.Images()[web.Params(c.Req)[":name"]]
+	if !isDataURI(image) {
+		return response.Error(http.StatusNotFound, "Image not found", nil)
+	}
+	contentType, content, err := decodeDataURI(image)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to decode the image", err)
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Content-Security-Policy", imageCSP)
+	return response.CreateNormalResponse(header, content, http.StatusOK)
+}
+
+func (s *Service) getBranding(c *contextmodel.ReqContext, orgID int64) response.Response {
+	b, err := s.GetBranding(c.Req.Context(), orgID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get the branding", err)
+	}
+	return response.JSON(http.StatusOK, b)
+}
+
+func (s *Service) updateBranding(c *contextmodel.ReqContext, orgID int64) response.Response {
+	cmd := branding.UpdateBrandingCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = orgID
+	cmd.UserID = c.UserID
+
+	b, err := s.UpdateBranding(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update the branding", err)
+	}
+	return response.JSON(http.StatusOK, b)
+}
+
+func (s *Service) deleteBranding(c *contextmodel.ReqContext, orgID int64) response.Response {
+	if err := s.DeleteBranding(c.Req.Context(), orgID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete the branding", err)
+	}
+	return response.Success("Branding deleted")
+}
+
+// swagger:parameters updateInstanceBranding updateOrgBranding
+type UpdateBrandingParams struct {
+	// in:body
+	// required:true
+	Body branding.UpdateBrandingCommand
+}
+
+// swagger:parameters getBrandingImage
+type GetBrandingImageParams struct {
+	// in:path
+	// required:true
+	OrgID int64 `json:"orgId"`
+	// in:path
+	// required:true
+	// enum: loginLogo,loginBackground,menuLogo,loadingLogo
+	Name string `json:"name"`
+}
+
+// swagger:response getBrandingResponse
+type GetBrandingResponse struct {
+	// in:body
+	Body branding.Branding `json:"body"`
+}
+
+// swagger:response getBrandingImageResponse
+type GetBrandingImageResponse struct {
+	// in: body
+	Body []byte `json:"body"`
+}
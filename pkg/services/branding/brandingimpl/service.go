@@ -0,0 +1,218 @@
+// Package brandingimpl stores the branding of the instance and of the organizations. The brandings are cached by
+// every instance, the cached copies are evicted through the cache invalidation when they're changed.
+package brandingimpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/branding"
+	"github.com/grafana/grafana/pkg/services/cacheinvalidation"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var _ branding.Service = (*Service)(nil)
+
+// cacheTTL bounds how long a branding changed on another instance is served when its invalidation was lost
+const cacheTTL = time.Minute
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, router routing.RouteRegister, accessControl ac.AccessControl,
+	cacheService *localcache.CacheService, invalidations cacheinvalidation.Service) *Service {
+	s := &Service{
+		cfg:           cfg,
+		store:         &xormStore{db: sqlStore},
+		cache:         cacheService,
+		invalidations: invalidations,
+		log:           log.New("branding"),
+		now:           time.Now,
+	}
+
+	invalidations.Register("branding", []string{cacheinvalidation.KindBranding + "/"}, s.handleInvalidation)
+	s.registerRoutes(router, accessControl)
+	return s
+}
+
+// Service stores the branding of the instance, and of the organizations when [branding] allow_org_branding is set
+type Service struct {
+	cfg           *setting.Cfg
+	store         store
+	cache         *localcache.CacheService
+	invalidations cacheinvalidation.Service
+	log           log.Logger
+	now           func() time.Time
+}
+
+func (s *Service) GetBranding(ctx context.Context, orgID int64) (*branding.Branding, error) {
+	if cached, ok := s.cache.Get(cacheKey(orgID)); ok {
+		b := *cached.(*branding.Branding)
+		return &b, nil
+	}
+
+	b := &branding.Branding{OrgID: orgID}
+	row, err := s.store.Get(ctx, orgID)
+	if err != nil && !errors.Is(err, branding.ErrNotFound) {
+		return nil, err
+	}
+	if row != nil {
+		if err := json.Unmarshal([]byte(row.Data), b); err != nil {
+			return nil, err
+		}
+		b.OrgID = row.OrgID
+		b.Version = row.Version
+		b.Updated = row.Updated
+	}
+
+	s.cache.Set(cacheKey(orgID), b, cacheTTL)
+	copied := *b
+	return &copied, nil
+}
+
+func (s *Service) GetResolvedBranding(ctx context.Context, orgID int64) (*branding.Branding, error) {
+	instance, err := s.GetBranding(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	resolved := s.withImageURLs(instance)
+	resolved.OrgID = orgID
+	if orgID == 0 || !s.cfg.Branding.AllowOrgBranding {
+		return resolved, nil
+	}
+
+	org, err := s.GetBranding(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	org = s.withImageURLs(org)
+	override(&resolved.AppTitle, org.AppTitle)
+	override(&resolved.LoginTitle, org.LoginTitle)
+	override(&resolved.LoginSubtitle, org.LoginSubtitle)
+	override(&resolved.LoginLogo, org.LoginLogo)
+	override(&resolved.LoginBackground, org.LoginBackground)
+	override(&resolved.MenuLogo, org.MenuLogo)
+	override(&resolved.LoadingLogo, org.LoadingLogo)
+	if len(org.FooterLinks) > 0 {
+		resolved.FooterLinks = org.FooterLinks
+	}
+	if org.Version > resolved.Version {
+		resolved.Version = org.Version
+		resolved.Updated = org.Updated
+	}
+	return resolved, nil
+}
+
+func (s *Service) UpdateBranding(ctx context.Context, cmd *branding.UpdateBrandingCommand) (*branding.Branding, error) {
+	if err := validate(cmd); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	b := &branding.Branding{
+		OrgID:           cmd.OrgID,
+		AppTitle:        strings.TrimSpace(cmd.AppTitle),
+		LoginTitle:      strings.TrimSpace(cmd.LoginTitle),
+		LoginSubtitle:   strings.TrimSpace(cmd.LoginSubtitle),
+		LoginLogo:       cmd.LoginLogo,
+		LoginBackground: cmd.LoginBackground,
+		MenuLogo:        cmd.MenuLogo,
+		LoadingLogo:     cmd.LoadingLogo,
+		FooterLinks:     cmd.FooterLinks,
+		EmailTemplates:  cmd.EmailTemplates,
+		// The version is part of the image URLs, so it must change even when a branding is deleted and saved again
+		Version: now.UnixMilli(),
+		Updated: now,
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+
+	row := &brandingRow{
+		OrgID:     cmd.OrgID,
+		Data:      string(data),
+		Version:   b.Version,
+		UpdatedBy: cmd.UserID,
+		Updated:   now,
+	}
+	if err := s.store.Save(ctx, row); err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx, cmd.OrgID, "BrandingUpdated")
+	return b, nil
+}
+
+func (s *Service) DeleteBranding(ctx context.Context, orgID int64) error {
+	if err := s.store.Delete(ctx, orgID); err != nil {
+		return err
+	}
+	s.invalidate(ctx, orgID, "BrandingDeleted")
+	return nil
+}
+
+func (s *Service) GetEmailTemplate(ctx context.Context, name string) (string, bool, error) {
+	instance, err := s.GetBranding(ctx, 0)
+	if err != nil {
+		return "", false, err
+	}
+	source, ok := instance.EmailTemplates[name]
+	return source, ok, nil
+}
+
+func (s *Service) invalidate(ctx context.Context, orgID int64, reason string) {
+	s.cache.Delete(cacheKey(orgID))
+	s.invalidations.Invalidate(ctx, cacheinvalidation.Invalidation{
+		Key:    cacheinvalidation.OrgPrefix(cacheinvalidation.KindBranding, orgID),
+		Reason: reason,
+	})
+}
+
+// handleInvalidation evicts the brandings changed on the other instances
+func (s *Service) handleInvalidation(_ context.Context, inv cacheinvalidation.Invalidation) {
+	if !inv.Remote {
+		return
+	}
+	_, orgID, _, ok := cacheinvalidation.ParseKey(inv.Key)
+	if !ok {
+		s.log.Warn("Ignoring invalid branding invalidation", "key", inv.Key)
+		return
+	}
+	s.cache.Delete(cacheKey(orgID))
+}
+
+// withImageURLs returns a copy of the branding without the email templates, where the images uploaded as data URIs
+// are replaced by the URLs serving them
+func (s *Service) withImageURLs(b *branding.Branding) *branding.Branding {
+	resolved := *b
+	resolved.EmailTemplates = nil
+	for name, image := range map[string]*string{
+		branding.ImageLoginLogo:       &resolved.LoginLogo,
+		branding.ImageLoginBackground: &resolved.LoginBackground,
+		branding.ImageMenuLogo:        &resolved.MenuLogo,
+		branding.ImageLoadingLogo:     &resolved.LoadingLogo,
+	} {
+		if isDataURI(*image) {
+			*image = fmt.Sprintf("%s/api/branding/images/%d/%s?v=%d", s.cfg.AppSubURL, b.OrgID, name, b.Version)
+		} else if strings.HasPrefix(*image, "/") {
+			*image = s.cfg.AppSubURL + *image
+		}
+	}
+	return &resolved
+}
+
+func override(value *string, org string) {
+	if org != "" {
+		*value = org
+	}
+}
+
+func cacheKey(orgID int64) string {
+	return fmt.Sprintf("branding-%d", orgID)
+}
@@ -0,0 +1,215 @@
+package brandingimpl
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/branding"
+	"github.com/grafana/grafana/pkg/services/cacheinvalidation"
+	"github.com/grafana/grafana/pkg/services/cacheinvalidation/cacheinvalidationtest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+var pngLogo = "data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte("\x89PNG logo"))
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestService_UpdateBranding(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should save and return the branding", func(t *testing.T) {
+		s, store, invalidations := setupTestService(t, false)
+
+		result, err := s.UpdateBranding(ctx, &branding.UpdateBrandingCommand{
+			UserID: 1, AppTitle: " Acme ", LoginLogo: pngLogo,
+			FooterLinks:    []branding.FooterLink{{Text: "Support", URL: "https://acme.com/support", Target: "_blank"}},
+			EmailTemplates: map[string]string{"welcome_on_signup.html": `{{Subject .Subject "Welcome"}}Hello`},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Acme", result.AppTitle)
+		assert.Equal(t, now.UnixMilli(), result.Version)
+		require.Contains(t, store.rows, int64(0))
+		assert.Equal(t, int64(1), store.rows[0].UpdatedBy)
+		require.Len(t, invalidations.Invalidations, 1)
+		assert.Equal(t, "branding/0/", invalidations.Invalidations[0].Key)
+
+		saved, err := s.GetBranding(ctx, 0)
+		require.NoError(t, err)
+		assert.Equal(t, "Acme", saved.AppTitle)
+		assert.Equal(t, pngLogo, saved.LoginLogo)
+		assert.Equal(t, "Support", saved.FooterLinks[0].Text)
+
+		source, ok, err := s.GetEmailTemplate(ctx, "welcome_on_signup.html")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Contains(t, source, "Hello")
+	})
+
+	t.Run("should validate the branding", func(t *testing.T) {
+		s, _, _ := setupTestService(t, false)
+
+		for _, tc := range []struct {
+			name string
+			cmd  branding.UpdateBrandingCommand
+			err  error
+		}{
+			{"title too long", branding.UpdateBrandingCommand{LoginTitle: strings.Repeat("a", 191)}, branding.ErrTextTooLong},
+			{"protocol-relative image", branding.UpdateBrandingCommand{MenuLogo: "//evil.com/logo.png"}, branding.ErrInvalidImage},
+			{"javascript image", branding.UpdateBrandingCommand{MenuLogo: "javascript:alert(1)"}, branding.ErrInvalidImage},
+			{"html data URI", branding.UpdateBrandingCommand{LoginLogo: "data:image/html;base64,PGh0bWw+"}, branding.ErrInvalidImage},
+			{"image too large", branding.UpdateBrandingCommand{LoginBackground: "data:image/png;base64," + base64.StdEncoding.EncodeToString(make([]byte, maxImageBytes+1))}, branding.ErrInvalidImage},
+			{"footer link without text", branding.UpdateBrandingCommand{FooterLinks: []branding.FooterLink{{URL: "/help"}}}, branding.ErrInvalidFooterLink},
+			{"footer link with invalid target", branding.UpdateBrandingCommand{FooterLinks: []branding.FooterLink{{Text: "Help", URL: "/help", Target: "_top"}}}, branding.ErrInvalidFooterLink},
+			{"too many footer links", branding.UpdateBrandingCommand{FooterLinks: make([]branding.FooterLink, 11)}, branding.ErrTooManyFooterLinks},
+			{"invalid email template name", branding.UpdateBrandingCommand{EmailTemplates: map[string]string{"../signup.html": "Hello"}}, branding.ErrInvalidEmailTemplate},
+			{"invalid email template", branding.UpdateBrandingCommand{EmailTemplates: map[string]string{"signup_started.html": "{{ .Name "}}, branding.ErrInvalidEmailTemplate},
+			{"org email templates", branding.UpdateBrandingCommand{OrgID: 2, EmailTemplates: map[string]string{"signup_started.html": "Hello"}}, branding.ErrOrgEmailTemplates},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := s.UpdateBranding(ctx, &tc.cmd)
+				assert.ErrorIs(t, err, tc.err)
+			})
+		}
+	})
+}
+
+func TestService_GetResolvedBranding(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should return an empty branding by default", func(t *testing.T) {
+		s, _, _ := setupTestService(t, true)
+
+		result, err := s.GetResolvedBranding(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, &branding.Branding{OrgID: 1}, result)
+	})
+
+	t.Run("should override the instance with the org and serve the uploaded images", func(t *testing.T) {
+		s, _, _ := setupTestService(t, true)
+		_, err := s.UpdateBranding(ctx, &branding.UpdateBrandingCommand{
+			AppTitle: "Acme", LoginTitle: "Welcome to Acme", LoginLogo: pngLogo, MenuLogo: "/public/img/acme.svg",
+			EmailTemplates: map[string]string{"welcome_on_signup.html": "Hello"},
+		})
+		require.NoError(t, err)
+		_, err = s.UpdateBranding(ctx, &branding.UpdateBrandingCommand{OrgID: 2, LoginTitle: "Welcome to the team"})
+		require.NoError(t, err)
+
+		result, err := s.GetResolvedBranding(ctx, 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), result.OrgID)
+		assert.Equal(t, "Acme", result.AppTitle)
+		assert.Equal(t, "Welcome to the team", result.LoginTitle)
+		assert.Equal(t, "/grafana/api/branding/images/0/loginLogo?v="+strconv.FormatInt(now.UnixMilli(), 10), result.LoginLogo)
+		assert.Equal(t, "/grafana/public/img/acme.svg", result.MenuLogo)
+		assert.Nil(t, result.EmailTemplates)
+
+		result, err = s.GetResolvedBranding(ctx, 3)
+		require.NoError(t, err)
+		assert.Equal(t, "Welcome to Acme", result.LoginTitle)
+	})
+
+	t.Run("should ignore the org when org branding isn't allowed", func(t *testing.T) {
+		s, _, _ := setupTestService(t, false)
+		_, err := s.UpdateBranding(ctx, &branding.UpdateBrandingCommand{OrgID: 2, LoginTitle: "Welcome to the team"})
+		require.NoError(t, err)
+
+		result, err := s.GetResolvedBranding(ctx, 2)
+		require.NoError(t, err)
+		assert.Empty(t, result.LoginTitle)
+	})
+
+	t.Run("should evict the branding changed on another instance", func(t *testing.T) {
+		s, store, _ := setupTestService(t, true)
+		_, err := s.GetBranding(ctx, 0)
+		require.NoError(t, err)
+
+		store.rows[0] = &brandingRow{OrgID: 0, Data: `{"appTitle":"Acme"}`}
+		s.handleInvalidation(ctx, cacheinvalidation.Invalidation{Key: "branding/0/", Remote: true})
+
+		result, err := s.GetResolvedBranding(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Acme", result.AppTitle)
+	})
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	store := &xormStore{db: db.InitTestDB(t)}
+
+	_, err := store.Get(ctx, 1)
+	require.ErrorIs(t, err, branding.ErrNotFound)
+
+	for _, data := range []string{`{"appTitle":"Acme"}`, `{"appTitle":"Acme Corp"}`} {
+		err := store.Save(ctx, &brandingRow{OrgID: 1, Data: data, Version: 1, UpdatedBy: 2, Updated: time.Now()})
+		require.NoError(t, err)
+	}
+	row, err := store.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, `{"appTitle":"Acme Corp"}`, row.Data)
+	assert.Equal(t, int64(2), row.UpdatedBy)
+
+	require.NoError(t, store.Delete(ctx, 1))
+	_, err = store.Get(ctx, 1)
+	require.ErrorIs(t, err, branding.ErrNotFound)
+}
+
+func setupTestService(t *testing.T, allowOrgBranding bool) (*Service, *fakeStore, *cacheinvalidationtest.FakeService) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.AppSubURL = "/grafana"
+	cfg.Branding.AllowOrgBranding = allowOrgBranding
+
+	store := &fakeStore{rows: map[int64]*brandingRow{}}
+	invalidations := cacheinvalidationtest.NewFakeService()
+	s := &Service{
+		cfg:           cfg,
+		store:         store,
+		cache:         localcache.New(cacheTTL, time.Hour),
+		invalidations: invalidations,
+		log:           log.NewNopLogger(),
+		now:           func() time.Time { return now },
+	}
+	invalidations.Register("branding", []string{cacheinvalidation.KindBranding + "/"}, s.handleInvalidation)
+	return s, store, invalidations
+}
+
+type fakeStore struct {
+	rows map[int64]*brandingRow
+}
+
+func (f *fakeStore) Get(_ context.Context, orgID int64) (*brandingRow, error) {
+	row, ok := f.rows[orgID]
+	if !ok {
+		return nil, branding.ErrNotFound.Errorf("branding of org %d not found", orgID)
+	}
+	return row, nil
+}
+
+func (f *fakeStore) Save(_ context.Context, row *brandingRow) error {
+	f.rows[row.OrgID] = row
+	return nil
+}
+
+func (f *fakeStore) Delete(_ context.Context, orgID int64) error {
+	delete(f.rows, orgID)
+	return nil
+}
@@ -0,0 +1,70 @@
+package brandingimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/branding"
+)
+
+type brandingRow struct {
+	ID int64 `xorm:"pk autoincr 'id'"`
+	// OrgID is 0 for the branding of the instance
+	OrgID int64 `xorm:"org_id"`
+	// Data is the JSON of the branding
+	Data      string    `xorm:"data"`
+	Version   int64     `xorm:"version"`
+	UpdatedBy int64     `xorm:"updated_by"`
+	Updated   time.Time `xorm:"updated"`
+}
+
+func (brandingRow) TableName() string {
+	return "branding"
+}
+
+type store interface {
+	Get(ctx context.Context, orgID int64) (*brandingRow, error)
+	// Save replaces the branding of the organization
+	Save(ctx context.Context, row *brandingRow) error
+	Delete(ctx context.Context, orgID int64) error
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (s *xormStore) Get(ctx context.Context, orgID int64) (*brandingRow, error) {
+	var row brandingRow
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("org_id = ?", orgID).Get(&row)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return branding.ErrNotFound.Errorf("branding of org %d not found", orgID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+func (s *xormStore) Save(ctx context.Context, row *brandingRow) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("DELETE FROM branding WHERE org_id = ?", row.OrgID); err != nil {
+			return err
+		}
+		_, err := sess.Insert(row)
+		return err
+	})
+}
+
+func (s *xormStore) Delete(ctx context.Context, orgID int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM branding WHERE org_id = ?", orgID)
+		return err
+	})
+}
@@ -0,0 +1,123 @@
+package brandingimpl
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana/pkg/services/branding"
+	"github.com/grafana/grafana/pkg/services/notifications"
+)
+
+const (
+	maxTextLength   = 190
+	maxFooterLinks  = 10
+	maxImageBytes   = 1 << 20
+	dataURIPrefix   = "data:image/"
+	dataURIEncoding = ";base64,"
+)
+
+var (
+	imageTypes = map[string]bool{
+		"image/png":     true,
+		"image/jpeg":    true,
+		"image/gif":     true,
+		"image/webp":    true,
+		"image/svg+xml": true,
+	}
+	emailTemplateNamePattern = regexp.MustCompile(`^[a-z_]+\.(html|txt)$`)
+
+	errInvalidDataURI = errors.New("not a base64 data URI of a PNG, JPEG, GIF, WebP or SVG image")
+	errImageTooLarge  = errors.New("image larger than 1 MiB")
+)
+
+func validate(cmd *branding.UpdateBrandingCommand) error {
+	for _, text := range []string{cmd.AppTitle, cmd.LoginTitle, cmd.LoginSubtitle} {
+		if utf8.RuneCountInString(strings.TrimSpace(text)) > maxTextLength {
+			return branding.ErrTextTooLong.Errorf("titles must be at most %d characters", maxTextLength)
+		}
+	}
+
+	for name, image := range map[string]string{
+		branding.ImageLoginLogo:       cmd.LoginLogo,
+		branding.ImageLoginBackground: cmd.LoginBackground,
+		branding.ImageMenuLogo:        cmd.MenuLogo,
+		branding.ImageLoadingLogo:     cmd.LoadingLogo,
+	} {
+		if image == "" {
+			continue
+		}
+		if isDataURI(image) {
+			if _, _, err := decodeDataURI(image); err != nil {
+				return branding.ErrInvalidImage.Errorf("invalid image %s: %w", name, err)
+			}
+		} else if !isValidURL(image) {
+			return branding.ErrInvalidImage.Errorf("invalid image %s", name)
+		}
+	}
+
+	if len(cmd.FooterLinks) > maxFooterLinks {
+		return branding.ErrTooManyFooterLinks.Errorf("at most %d footer links are allowed", maxFooterLinks)
+	}
+	for i, link := range cmd.FooterLinks {
+		if strings.TrimSpace(link.Text) == "" || utf8.RuneCountInString(link.Text) > maxTextLength {
+			return branding.ErrInvalidFooterLink.Errorf("footer link %d requires a text of at most %d characters", i, maxTextLength)
+		}
+		if !isValidURL(link.URL) {
+			return branding.ErrInvalidFooterLink.Errorf("footer link %d requires a Grafana path or an http(s) URL", i)
+		}
+		if link.Target != "" && link.Target != "_blank" && link.Target != "_self" {
+			return branding.ErrInvalidFooterLink.Errorf("footer link %d has an invalid target %q", i, link.Target)
+		}
+	}
+
+	if len(cmd.EmailTemplates) > 0 && cmd.OrgID != 0 {
+		return branding.ErrOrgEmailTemplates.Errorf("email templates can't be customized for org %d", cmd.OrgID)
+	}
+	for name, source := range cmd.EmailTemplates {
+		if !emailTemplateNamePattern.MatchString(name) {
+			return branding.ErrInvalidEmailTemplate.Errorf("invalid email template name %q", name)
+		}
+		if _, err := notifications.ParseTemplate(name, source); err != nil {
+			return branding.ErrInvalidEmailTemplate.Errorf("invalid email template %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func isValidURL(u string) bool {
+	if strings.HasPrefix(u, "/") {
+		return !strings.HasPrefix(u, "//")
+	}
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+func isDataURI(image string) bool {
+	return strings.HasPrefix(image, dataURIPrefix)
+}
+
+// decodeDataURI returns the content type and the content of a base64 data URI of an image
+func decodeDataURI(image string) (string, []byte, error) {
+	contentType, data, ok := strings.Cut(strings.TrimPrefix(image, "data:"), dataURIEncoding)
+	if !ok || !imageTypes[contentType] {
+		return "", nil, errInvalidDataURI
+	}
+	if base64.StdEncoding.DecodedLen(len(data)) > maxImageBytes+2 {
+		return "", nil, errImageTooLarge
+	}
+	content, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(content) > maxImageBytes {
+		return "", nil, errImageTooLarge
+	}
+	return contentType, content, nil
+}
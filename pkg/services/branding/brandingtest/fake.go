@@ -0,0 +1,46 @@
+package brandingtest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/branding"
+)
+
+var _ branding.Service = (*FakeService)(nil)
+
+type FakeService struct {
+	ExpectedBranding *branding.Branding
+	ExpectedError    error
+}
+
+func NewFakeService() *FakeService {
+	return &FakeService{}
+}
+
+func (f *FakeService) GetBranding(ctx context.Context, orgID int64) (*branding.Branding, error) {
+	return f.branding(orgID), f.ExpectedError
+}
+
+func (f *FakeService) GetResolvedBranding(ctx context.Context, orgID int64) (*branding.Branding, error) {
+	return f.branding(orgID), f.ExpectedError
+}
+
+func (f *FakeService) UpdateBranding(ctx context.Context, cmd *branding.UpdateBrandingCommand) (*branding.Branding, error) {
+	return f.branding(cmd.OrgID), f.ExpectedError
+}
+
+func (f *FakeService) DeleteBranding(ctx context.Context, orgID int64) error {
+	return f.ExpectedError
+}
+
+func (f *FakeService) GetEmailTemplate(ctx context.Context, name string) (string, bool, error) {
+	source, ok := f.branding(0).EmailTemplates[name]
+	return source, ok, f.ExpectedError
+}
+
+func (f *FakeService) branding(orgID int64) *branding.Branding {
+	if f.ExpectedBranding != nil {
+		return f.ExpectedBranding
+	}
+	return &branding.Branding{OrgID: orgID}
+}
@@ -0,0 +1,85 @@
+package cacheinvalidation
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The kinds of resources the keys are built for. A key is the kind, the id of the organization and the identifier of
+// the resource separated by slashes, such as dashboards/1/abc, so that the caches can register interest in every
+// resource of a kind or of an organization with a prefix.
+const (
+	KindDashboards  = "dashboards"
+	KindFolders     = "folders"
+	KindDataSources = "datasources"
+	KindPermissions = "permissions"
+	// KindFeatureToggles keys are the values of the feature toggles changed at runtime, the org is 0 for the global
+	// values
+	KindFeatureToggles = "featuretoggles"
+	// KindBranding keys are the brandings of the organizations, the org is 0 for the branding of the instance
+	KindBranding = "branding"
+)
+
+// Service tells the caches of every instance that the cached copies of a resource are stale. The mutations of
+// dashboards, folders, data sources and managed permissions are invalidated as they're saved, other services can
+// invalidate their own keys with Invalidate.
+type Service interface {
+	// Register calls the handler on every instance with the invalidations matching one of the prefixes. The name
+	// identifies the handler in the logs and metrics, such as "search index" or "rbac cache".
+	Register(name string, prefixes []string, handler Handler)
+	// Invalidate calls the handlers of this instance and sends the invalidation to the other instances
+	Invalidate(ctx context.Context, inv Invalidation)
+}
+
+// Handler evicts the cached copies of the invalidated key. It's called synchronously, it must not block.
+type Handler func(ctx context.Context, inv Invalidation)
+
+// Invalidation tells that the cached copies of a key, or of every key with a prefix when the key ends with a slash,
+// are stale.
+type Invalidation struct {
+	Key string `json:"key"`
+	// Reason is the name of the event which caused the invalidation, such as DashboardSaved
+	Reason string `json:"reason"`
+	// Remote is true when the invalidation was sent by another instance
+	Remote bool `json:"-"`
+}
+
+// Matches reports whether the handlers registered with the prefix are interested in the invalidation: the key
+// starts with the prefix, or the invalidated prefix contains the keys with the prefix.
+func (inv Invalidation) Matches(prefix string) bool {
+	if strings.HasPrefix(inv.Key, prefix) {
+		return true
+	}
+	return strings.HasSuffix(inv.Key, "/") && strings.HasPrefix(prefix, inv.Key)
+}
+
+// Key returns the key of the resource of the kind in the organization
+func Key(kind string, orgID int64, id string) string {
+	return fmt.Sprintf("%s/%d/%s", kind, orgID, id)
+}
+
+// OrgPrefix returns the prefix of the keys of the resources of the kind in the organization
+func OrgPrefix(kind string, orgID int64) string {
+	return fmt.Sprintf("%s/%d/", kind, orgID)
+}
+
+// ParseKey returns the kind, the organization and the identifier of the resource of a key. The identifier is empty
+// for the prefix of the keys of an organization.
+func ParseKey(key string) (kind string, orgID int64, id string, ok bool) {
+	parts := strings.SplitN(key, "/", 3)
+	if len(parts) != 3 {
+		return "", 0, "", false
+	}
+	orgID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", 0, "", false
+	}
+	return parts[0], orgID, parts[2], true
+}
+
+// PermissionsKey returns the key of the managed permissions on a resource, such as the dashboards or the folders
+func PermissionsKey(orgID int64, resource, resourceID string) string {
+	return Key(KindPermissions, orgID, resource+"/"+resourceID)
+}
@@ -0,0 +1,71 @@
+package cacheinvalidationimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/services/cacheinvalidation"
+)
+
+// addEventListeners invalidates the keys of the resources mutated on this instance. The events are only published
+// on the instance which saved the resource, the other instances are told by the broadcast.
+func (s *Service) addEventListeners(b bus.Bus) {
+	b.AddEventListener(s.handleDashboardSaved)
+	b.AddEventListener(s.handleDashboardDeleted)
+	b.AddEventListener(s.handleFolderSaved)
+	b.AddEventListener(s.handleFolderDeleted)
+	b.AddEventListener(s.handleFolderFullPathUpdated)
+	b.AddEventListener(s.handleDataSourceUpdated)
+	b.AddEventListener(s.handleDataSourceDeleted)
+	b.AddEventListener(s.handlePermissionsChanged)
+}
+
+func (s *Service) handleDashboardSaved(ctx context.Context, evt *events.DashboardSaved) error {
+	s.invalidate(ctx, "DashboardSaved", cacheinvalidation.Key(cacheinvalidation.KindDashboards, evt.OrgID, evt.UID))
+	return nil
+}
+
+func (s *Service) handleDashboardDeleted(ctx context.Context, evt *events.DashboardDeleted) error {
+	s.invalidate(ctx, "DashboardDeleted", cacheinvalidation.Key(cacheinvalidation.KindDashboards, evt.OrgID, evt.UID))
+	return nil
+}
+
+func (s *Service) handleFolderSaved(ctx context.Context, evt *events.FolderSaved) error {
+	s.invalidate(ctx, "FolderSaved", cacheinvalidation.Key(cacheinvalidation.KindFolders, evt.OrgID, evt.UID))
+	return nil
+}
+
+// handleFolderDeleted invalidates the dashboards of the organization too, the dashboards of the folder are deleted
+// with it and the event doesn't list them
+func (s *Service) handleFolderDeleted(ctx context.Context, evt *events.FolderDeleted) error {
+	s.invalidate(ctx, "FolderDeleted", cacheinvalidation.Key(cacheinvalidation.KindFolders, evt.OrgID, evt.UID))
+	s.invalidate(ctx, "FolderDeleted", cacheinvalidation.OrgPrefix(cacheinvalidation.KindDashboards, evt.OrgID))
+	return nil
+}
+
+func (s *Service) handleFolderFullPathUpdated(ctx context.Context, evt *events.FolderFullPathUpdated) error {
+	for _, uid := range evt.UIDs {
+		s.invalidate(ctx, "FolderFullPathUpdated", cacheinvalidation.Key(cacheinvalidation.KindFolders, evt.OrgID, uid))
+	}
+	return nil
+}
+
+func (s *Service) handleDataSourceUpdated(ctx context.Context, evt *events.DataSourceUpdated) error {
+	s.invalidate(ctx, "DataSourceUpdated", cacheinvalidation.Key(cacheinvalidation.KindDataSources, evt.OrgID, evt.UID))
+	return nil
+}
+
+func (s *Service) handleDataSourceDeleted(ctx context.Context, evt *events.DataSourceDeleted) error {
+	s.invalidate(ctx, "DataSourceDeleted", cacheinvalidation.Key(cacheinvalidation.KindDataSources, evt.OrgID, evt.UID))
+	return nil
+}
+
+func (s *Service) handlePermissionsChanged(ctx context.Context, evt *events.PermissionsChanged) error {
+	s.invalidate(ctx, "PermissionsChanged", cacheinvalidation.PermissionsKey(evt.OrgID, evt.Resource, evt.ResourceID))
+	return nil
+}
+
+func (s *Service) invalidate(ctx context.Context, reason, key string) {
+	s.Invalidate(ctx, cacheinvalidation.Invalidation{Key: key, Reason: reason})
+}
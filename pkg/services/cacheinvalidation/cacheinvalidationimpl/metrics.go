@@ -0,0 +1,45 @@
+package cacheinvalidationimpl
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "grafana"
+	metricsSubSystem = "cache_invalidation"
+)
+
+type metrics struct {
+	invalidations     *prometheus.CounterVec
+	handled           *prometheus.CounterVec
+	broadcastFailures prometheus.Counter
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		invalidations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "invalidations_total",
+			Help:      "Number of invalidations by reason and origin (local or remote)",
+		}, []string{"reason", "origin"}),
+		handled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "handled_total",
+			Help:      "Number of invalidations handled by handler",
+		}, []string{"handler"}),
+		broadcastFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "broadcast_failures_total",
+			Help:      "Number of invalidations which couldn't be sent to the other instances",
+		}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.invalidations, m.handled, m.broadcastFailures)
+	}
+
+	return m
+}
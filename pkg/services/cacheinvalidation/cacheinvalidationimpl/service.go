@@ -0,0 +1,136 @@
+package cacheinvalidationimpl
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/cacheinvalidation"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var _ cacheinvalidation.Service = (*Service)(nil)
+
+// channel is the channel of the remote cache the invalidations are broadcast on
+const channel = "cache-invalidations"
+
+// broadcaster sends the invalidations to the other instances, it's implemented by the remote cache
+type broadcaster interface {
+	CanBroadcast() bool
+	Broadcast(ctx context.Context, channel string, message string) error
+	Subscribe(ctx context.Context, channel string, handler func(message string)) error
+}
+
+func ProvideService(bus bus.Bus, cache *remotecache.RemoteCache, reg prometheus.Registerer) *Service {
+	s := newService(cache, reg)
+	s.addEventListeners(bus)
+	return s
+}
+
+func newService(b broadcaster, reg prometheus.Registerer) *Service {
+	return &Service{
+		broadcaster: b,
+		instanceID:  util.GenerateShortUID(),
+		log:         log.New("cacheinvalidation"),
+		metrics:     newMetrics(reg),
+	}
+}
+
+// Service broadcasts the invalidations through the pub/sub of the remote cache. The invalidations reach the other
+// instances only when the remote cache is redis, the caches of the other instances rely on their expiry otherwise.
+// The instances which are disconnected from redis miss the invalidations sent in the meantime.
+type Service struct {
+	broadcaster broadcaster
+	instanceID  string
+	log         log.Logger
+	metrics     *metrics
+
+	mu            sync.RWMutex
+	registrations []registration
+}
+
+type registration struct {
+	name     string
+	prefixes []string
+	handler  cacheinvalidation.Handler
+}
+
+// message is an invalidation sent to the other instances
+type message struct {
+	Instance string `json:"instance"`
+	cacheinvalidation.Invalidation
+}
+
+func (s *Service) Register(name string, prefixes []string, handler cacheinvalidation.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.registrations = append(s.registrations, registration{name: name, prefixes: prefixes, handler: handler})
+}
+
+func (s *Service) Invalidate(ctx context.Context, inv cacheinvalidation.Invalidation) {
+	inv.Remote = false
+	s.dispatch(ctx, inv)
+
+	if !s.broadcaster.CanBroadcast() {
+		return
+	}
+	payload, err := json.Marshal(message{Instance: s.instanceID, Invalidation: inv})
+	if err != nil {
+		s.log.Error("Failed to encode the invalidation", "key", inv.Key, "error", err)
+		return
+	}
+	if err := s.broadcaster.Broadcast(ctx, channel, string(payload)); err != nil {
+		s.metrics.broadcastFailures.Inc()
+		s.log.Warn("Failed to send the invalidation to the other instances", "key", inv.Key, "reason", inv.Reason, "error", err)
+	}
+}
+
+// Run receives the invalidations of the other instances
+func (s *Service) Run(ctx context.Context) error {
+	if !s.broadcaster.CanBroadcast() {
+		s.log.Debug("The remote cache type can't broadcast, the caches of the other instances aren't invalidated")
+		return nil
+	}
+
+	return s.broadcaster.Subscribe(ctx, channel, func(payload string) {
+		var msg message
+		if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+			s.log.Warn("Ignoring an invalid invalidation", "error", err)
+			return
+		}
+		if msg.Instance == s.instanceID {
+			return
+		}
+		msg.Invalidation.Remote = true
+		s.dispatch(ctx, msg.Invalidation)
+	})
+}
+
+// dispatch calls the handlers registered with a prefix matching the invalidation, once per handler
+func (s *Service) dispatch(ctx context.Context, inv cacheinvalidation.Invalidation) {
+	s.mu.RLock()
+	registrations := s.registrations
+	s.mu.RUnlock()
+
+	origin := "local"
+	if inv.Remote {
+		origin = "remote"
+	}
+	s.metrics.invalidations.WithLabelValues(inv.Reason, origin).Inc()
+
+	for _, r := range registrations {
+		for _, prefix := range r.prefixes {
+			if inv.Matches(prefix) {
+				s.log.Debug("Invalidating", "handler", r.name, "key", inv.Key, "reason", inv.Reason, "origin", origin)
+				r.handler(ctx, inv)
+				s.metrics.handled.WithLabelValues(r.name).Inc()
+				break
+			}
+		}
+	}
+}
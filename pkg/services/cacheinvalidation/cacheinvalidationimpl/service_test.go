@@ -0,0 +1,133 @@
+package cacheinvalidationimpl
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/cacheinvalidation"
+)
+
+// fakeBroadcaster delivers the messages to the subscribers of every instance synchronously
+type fakeBroadcaster struct {
+	mu          sync.Mutex
+	subscribers []func(message string)
+}
+
+func (b *fakeBroadcaster) CanBroadcast() bool { return true }
+
+func (b *fakeBroadcaster) Broadcast(_ context.Context, _ string, message string) error {
+	b.mu.Lock()
+	subscribers := b.subscribers
+	b.mu.Unlock()
+	for _, subscriber := range subscribers {
+		subscriber(message)
+	}
+	return nil
+}
+
+func (b *fakeBroadcaster) Subscribe(ctx context.Context, _ string, handler func(message string)) error {
+	b.mu.Lock()
+	b.subscribers = append(b.subscribers, handler)
+	b.mu.Unlock()
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (b *fakeBroadcaster) subscribed() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers)
+}
+
+type recorder struct {
+	mu            sync.Mutex
+	invalidations []cacheinvalidation.Invalidation
+}
+
+func (r *recorder) handle(_ context.Context, inv cacheinvalidation.Invalidation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.invalidations = append(r.invalidations, inv)
+}
+
+func (r *recorder) keys() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keys := make([]string, 0, len(r.invalidations))
+	for _, inv := range r.invalidations {
+		keys = append(keys, inv.Key)
+	}
+	return keys
+}
+
+func TestService(t *testing.T) {
+	t.Run("calls the handlers registered with a matching prefix", func(t *testing.T) {
+		s := newService(&fakeBroadcaster{}, nil)
+		dashboards, org1, datasources := &recorder{}, &recorder{}, &recorder{}
+		s.Register("dashboards", []string{cacheinvalidation.KindDashboards + "/"}, dashboards.handle)
+		s.Register("org 1", []string{cacheinvalidation.OrgPrefix(cacheinvalidation.KindDashboards, 1), cacheinvalidation.OrgPrefix(cacheinvalidation.KindDataSources, 1)}, org1.handle)
+		s.Register("datasources", []string{cacheinvalidation.KindDataSources + "/"}, datasources.handle)
+
+		s.Invalidate(context.Background(), cacheinvalidation.Invalidation{Key: "dashboards/1/abc"})
+		s.Invalidate(context.Background(), cacheinvalidation.Invalidation{Key: "dashboards/2/abc"})
+		s.Invalidate(context.Background(), cacheinvalidation.Invalidation{Key: "dashboards/"})
+
+		assert.Equal(t, []string{"dashboards/1/abc", "dashboards/2/abc", "dashboards/"}, dashboards.keys())
+		assert.Equal(t, []string{"dashboards/1/abc", "dashboards/"}, org1.keys(), "the handlers are called once per invalidation")
+		assert.Empty(t, datasources.keys())
+	})
+
+	t.Run("maps the resource events to keys", func(t *testing.T) {
+		b := bus.ProvideBus(tracing.InitializeTracerForTest())
+		s := newService(&fakeBroadcaster{}, nil)
+		s.addEventListeners(b)
+		r := &recorder{}
+		s.Register("all", []string{""}, r.handle)
+
+		ctx := context.Background()
+		require.NoError(t, b.Publish(ctx, &events.DashboardSaved{OrgID: 1, UID: "dash"}))
+		require.NoError(t, b.Publish(ctx, &events.FolderDeleted{OrgID: 1, UID: "folder"}))
+		require.NoError(t, b.Publish(ctx, &events.DataSourceUpdated{OrgID: 2, UID: "ds"}))
+		require.NoError(t, b.Publish(ctx, &events.PermissionsChanged{OrgID: 1, Resource: "dashboards", ResourceID: "dash"}))
+
+		assert.Equal(t, []string{
+			"dashboards/1/dash",
+			"folders/1/folder",
+			"dashboards/1/",
+			"datasources/2/ds",
+			"permissions/1/dashboards/dash",
+		}, r.keys())
+		assert.Equal(t, "DataSourceUpdated", r.invalidations[3].Reason)
+	})
+
+	t.Run("broadcasts the invalidations to the other instances", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		broadcaster := &fakeBroadcaster{}
+		first, second := newService(broadcaster, nil), newService(broadcaster, nil)
+		firstRecorder, secondRecorder := &recorder{}, &recorder{}
+		first.Register("cache", []string{"dashboards/"}, firstRecorder.handle)
+		second.Register("cache", []string{"dashboards/"}, secondRecorder.handle)
+
+		go func() { _ = first.Run(ctx) }()
+		go func() { _ = second.Run(ctx) }()
+		require.Eventually(t, func() bool { return broadcaster.subscribed() == 2 }, time.Second, 10*time.Millisecond)
+
+		first.Invalidate(ctx, cacheinvalidation.Invalidation{Key: "dashboards/1/abc", Reason: "DashboardSaved"})
+
+		require.Len(t, firstRecorder.invalidations, 1, "the instance ignores its own broadcast")
+		assert.False(t, firstRecorder.invalidations[0].Remote)
+		require.Len(t, secondRecorder.invalidations, 1)
+		assert.True(t, secondRecorder.invalidations[0].Remote)
+		assert.Equal(t, "DashboardSaved", secondRecorder.invalidations[0].Reason)
+	})
+}
@@ -0,0 +1,48 @@
+package cacheinvalidationtest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/services/cacheinvalidation"
+)
+
+var _ cacheinvalidation.Service = (*FakeService)(nil)
+
+// FakeService calls the registered handlers with the invalidations, without sending them to other instances
+type FakeService struct {
+	mu            sync.Mutex
+	registrations []registration
+	Invalidations []cacheinvalidation.Invalidation
+}
+
+type registration struct {
+	prefixes []string
+	handler  cacheinvalidation.Handler
+}
+
+func NewFakeService() *FakeService {
+	return &FakeService{}
+}
+
+func (f *FakeService) Register(_ string, prefixes []string, handler cacheinvalidation.Handler) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.registrations = append(f.registrations, registration{prefixes: prefixes, handler: handler})
+}
+
+func (f *FakeService) Invalidate(ctx context.Context, inv cacheinvalidation.Invalidation) {
+	f.mu.Lock()
+	f.Invalidations = append(f.Invalidations, inv)
+	registrations := f.registrations
+	f.mu.Unlock()
+
+	for _, r := range registrations {
+		for _, prefix := range r.prefixes {
+			if inv.Matches(prefix) {
+				r.handler(ctx, inv)
+				break
+			}
+		}
+	}
+}
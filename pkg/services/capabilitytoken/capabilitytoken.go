@@ -0,0 +1,67 @@
+package capabilitytoken
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+var (
+	ErrTokenNotFound = errutil.NotFound(
+		"capabilitytoken.not-found", errutil.WithPublicMessage("Capability token not found"))
+	ErrInvalidToken = errutil.Unauthorized(
+		"capabilitytoken.invalid", errutil.WithPublicMessage("Invalid capability token"))
+	ErrInvalidName       = errutil.BadRequest("capabilitytoken.invalid-name")
+	ErrInvalidPermission = errutil.BadRequest("capabilitytoken.invalid-permission")
+	ErrInvalidTTL        = errutil.BadRequest("capabilitytoken.invalid-ttl")
+	// ErrPermissionEscalation is returned when a user mints a token with permissions they don't have
+	ErrPermissionEscalation = errutil.Forbidden("capabilitytoken.permission-escalation")
+)
+
+const (
+	ActionRead  = "capabilitytokens:read"
+	ActionWrite = "capabilitytokens:write"
+)
+
+// TokenPrefix starts every capability token so they can be told apart from other credentials
+const TokenPrefix = "glct_"
+
+type Service interface {
+	// CreateToken mints a signed token granting the permissions of the command until it expires.
+	// The requester must hold every permission of the token.
+	CreateToken(ctx context.Context, requester identity.Requester, cmd *CreateTokenCommand) (*CreateTokenResult, error)
+	ListTokens(ctx context.Context, orgID int64) ([]*Token, error)
+	RevokeToken(ctx context.Context, orgID int64, uid string) error
+	// ValidateToken verifies the signature and the expiry of the token, and that it wasn't revoked
+	ValidateToken(ctx context.Context, token string) (*Token, error)
+}
+
+type Token struct {
+	ID          int64           `json:"-"`
+	OrgID       int64           `json:"orgId"`
+	UID         string          `json:"uid"`
+	Name        string          `json:"name"`
+	Permissions []ac.Permission `json:"permissions"`
+	CreatedBy   int64           `json:"createdBy"`
+	Expires     time.Time       `json:"expires"`
+	Revoked     bool            `json:"revoked"`
+	Created     time.Time       `json:"created"`
+}
+
+// CreateTokenCommand mints a token for the organization of the signed in user.
+type CreateTokenCommand struct {
+	Name string `json:"name"`
+	// Permissions granted by the token, their scopes must target a single resource
+	Permissions []ac.Permission `json:"permissions"`
+	// SecondsToLive is the lifetime of the token, it defaults to and can't exceed the configured max_ttl
+	SecondsToLive int64 `json:"secondsToLive"`
+}
+
+type CreateTokenResult struct {
+	*Token
+	// Key is the signed token, it is only returned when the token is created
+	Key string `json:"key"`
+}
@@ -0,0 +1,114 @@
+package capabilitytokenimpl
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/capabilitytoken"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+
+	router.Group("/api/capability-tokens", func(tokenRoute routing.RouteRegister) {
+		tokenRoute.Get("/", authorize(ac.EvalPermission(capabilitytoken.ActionRead)), routing.Wrap(s.listTokens))
+		tokenRoute.Post("/", authorize(ac.EvalPermission(capabilitytoken.ActionWrite)), routing.Wrap(s.createToken))
+		tokenRoute.Delete("/:uid", authorize(ac.EvalPermission(capabilitytoken.ActionWrite)), routing.Wrap(s.revokeToken))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /capability-tokens capability_tokens listCapabilityTokens
+//
+// Get the capability tokens of the current organization.
+//
+// Returns the tokens most recent first, including the expired and revoked ones. The signed tokens aren't returned.
+//
+// Responses:
+// 200: listCapabilityTokensResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) listTokens(c *contextmodel.ReqContext) response.Response {
+	tokens, err := s.ListTokens(c.Req.Context(), c.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list capability tokens", err)
+	}
+	return response.JSON(http.StatusOK, tokens)
+}
+
+// swagger:route POST /capability-tokens capability_tokens createCapabilityToken
+//
+// Create a capability token.
+//
+// Mints a signed token granting the given permissions until it expires. Only the actions listed in
+// `allowed_actions` can be granted, every scope must target a single resource and the signed in user
+// must hold the permissions. The token is only returned in this response.
+//
+// Responses:
+// 200: createCapabilityTokenResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) createToken(c *contextmodel.ReqContext) response.Response {
+	cmd := capabilitytoken.CreateTokenCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	result, err := s.CreateToken(c.Req.Context(), c.SignedInUser, &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create capability token", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route DELETE /capability-tokens/{uid} capability_tokens revokeCapabilityToken
+//
+// Revoke a capability token.
+//
+// Requests authenticated with the token are rejected once revoked, other instances can accept it for up to a minute.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) revokeToken(c *contextmodel.ReqContext) response.Response {
+	if err := s.RevokeToken(c.Req.Context(), c.GetOrgID(), web.Params(c.Req)[":uid"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to revoke capability token", err)
+	}
+	return response.Success("Capability token revoked")
+}
+
+// swagger:parameters createCapabilityToken
+type CreateCapabilityTokenParams struct {
+	// in:body
+	// required:true
+	Body capabilitytoken.CreateTokenCommand `json:"body"`
+}
+
+// swagger:parameters revokeCapabilityToken
+type RevokeCapabilityTokenParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:response listCapabilityTokensResponse
+type ListCapabilityTokensResponse struct {
+	// in: body
+	Body []*capabilitytoken.Token `json:"body"`
+}
+
+// swagger:response createCapabilityTokenResponse
+type CreateCapabilityTokenResponse struct {
+	// in: body
+	Body capabilitytoken.CreateTokenResult `json:"body"`
+}
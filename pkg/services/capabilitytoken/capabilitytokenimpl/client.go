@@ -0,0 +1,81 @@
+package capabilitytokenimpl
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	claims "github.com/grafana/authlib/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/capabilitytoken"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	tokenHeaderName = "X-Grafana-Capability-Token"
+	tokenQueryName  = "capabilityToken"
+)
+
+var _ authn.Client = new(Client)
+
+// Client authenticates requests carrying a capability token. The identity only holds the permissions of the token,
+// it doesn't belong to a user and has no basic role.
+type Client struct {
+	cfg     *setting.Cfg
+	service capabilitytoken.Service
+	log     log.Logger
+}
+
+func (c *Client) Name() string {
+	return authn.ClientCapabilityToken
+}
+
+func (c *Client) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error) {
+	token, err := c.service.ValidateToken(ctx, getToken(r))
+	if err != nil {
+		return nil, err
+	}
+
+	return &authn.Identity{
+		ID:              "0",
+		UID:             "0",
+		Type:            claims.TypeAnonymous,
+		OrgID:           token.OrgID,
+		OrgRoles:        map[int64]org.RoleType{token.OrgID: org.RoleNone},
+		Name:            token.Name,
+		AuthID:          token.UID,
+		AuthenticatedBy: login.CapabilityTokenModule,
+		Permissions:     map[int64]map[string][]string{token.OrgID: ac.GroupScopesByAction(token.Permissions)},
+		LastSeenAt:      time.Now(),
+	}, nil
+}
+
+func (c *Client) IsEnabled() bool {
+	return c.cfg.CapabilityTokens.Enabled
+}
+
+func (c *Client) Test(ctx context.Context, r *authn.Request) bool {
+	if r.HTTPRequest == nil {
+		return false
+	}
+	return strings.HasPrefix(getToken(r), capabilitytoken.TokenPrefix)
+}
+
+func (c *Client) Priority() uint {
+	return 25
+}
+
+func getToken(r *authn.Request) string {
+	if token := r.HTTPRequest.Header.Get(tokenHeaderName); token != "" {
+		return token
+	}
+	if r.HTTPRequest.URL != nil {
+		return r.HTTPRequest.URL.Query().Get(tokenQueryName)
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package capabilitytokenimpl
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	claims "github.com/grafana/authlib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/capabilitytoken"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+func TestClient_Test(t *testing.T) {
+	c := &Client{}
+
+	tests := []struct {
+		desc     string
+		header   string
+		query    string
+		expected bool
+	}{
+		{desc: "should accept the header", header: capabilitytoken.TokenPrefix + "token", expected: true},
+		{desc: "should accept the query parameter", query: tokenQueryName + "=" + capabilitytoken.TokenPrefix + "token", expected: true},
+		{desc: "should ignore other tokens", header: "glsa_token", expected: false},
+		{desc: "should ignore requests without token", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			req := &http.Request{Header: http.Header{}, URL: &url.URL{RawQuery: tt.query}}
+			if tt.header != "" {
+				req.Header.Set(tokenHeaderName, tt.header)
+			}
+			assert.Equal(t, tt.expected, c.Test(context.Background(), &authn.Request{HTTPRequest: req}))
+		})
+	}
+}
+
+func TestClient_Authenticate(t *testing.T) {
+	ctx := context.Background()
+	s, _ := setupTestService(t)
+	c := &Client{cfg: s.cfg, service: s, log: log.NewNopLogger()}
+
+	result, err := s.CreateToken(ctx, newRequester(map[string][]string{"dashboards:read": {"dashboards:*"}}), &capabilitytoken.CreateTokenCommand{
+		Name:        "share",
+		Permissions: []ac.Permission{dashboardRead},
+	})
+	require.NoError(t, err)
+
+	req := &http.Request{Header: http.Header{}, URL: &url.URL{}}
+	req.Header.Set(tokenHeaderName, result.Key)
+	id, err := c.Authenticate(ctx, &authn.Request{HTTPRequest: req})
+	require.NoError(t, err)
+
+	assert.Equal(t, claims.TypeAnonymous, id.Type)
+	assert.Equal(t, int64(1), id.OrgID)
+	assert.Equal(t, org.RoleNone, id.OrgRoles[1])
+	assert.Equal(t, result.UID, id.AuthID)
+	assert.Equal(t, login.CapabilityTokenModule, id.AuthenticatedBy)
+	assert.Equal(t, map[string][]string{"dashboards:read": {"dashboards:uid:1"}}, id.Permissions[1])
+
+	req.Header.Set(tokenHeaderName, capabilitytoken.TokenPrefix+"invalid")
+	_, err = c.Authenticate(ctx, &authn.Request{HTTPRequest: req})
+	assert.ErrorIs(t, err, capabilitytoken.ErrInvalidToken)
+}
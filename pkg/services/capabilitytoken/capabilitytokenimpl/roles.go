@@ -0,0 +1,45 @@
+package capabilitytokenimpl
+
+import (
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/capabilitytoken"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+var (
+	tokensReaderRole = ac.RoleDTO{
+		Name:        "fixed:capabilitytokens:reader",
+		DisplayName: "Reader",
+		Description: "List the capability tokens of the organization",
+		Group:       "Capability tokens",
+		Permissions: []ac.Permission{
+			{Action: capabilitytoken.ActionRead},
+		},
+	}
+
+	tokensWriterRole = ac.RoleDTO{
+		Name:        "fixed:capabilitytokens:writer",
+		DisplayName: "Writer",
+		Description: "Create, list and revoke the capability tokens of the organization",
+		Group:       "Capability tokens",
+		Permissions: []ac.Permission{
+			{Action: capabilitytoken.ActionRead},
+			{Action: capabilitytoken.ActionWrite},
+		},
+	}
+)
+
+func declareFixedRoles(service ac.Service) error {
+	grants := []string{string(org.RoleAdmin)}
+
+	tokensReader := ac.RoleRegistration{
+		Role:   tokensReaderRole,
+		Grants: grants,
+	}
+	tokensWriter := ac.RoleRegistration{
+		Role:   tokensWriterRole,
+		Grants: grants,
+	}
+
+	return service.DeclareFixedRoles(tokensReader, tokensWriter)
+}
@@ -0,0 +1,196 @@
+package capabilitytokenimpl
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/capabilitytoken"
+	"github.com/grafana/grafana/pkg/services/signingkeys"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var _ capabilitytoken.Service = (*Service)(nil)
+
+// cacheTTL bounds how long an instance accepts a token after it was revoked on another instance
+const cacheTTL = time.Minute
+
+func ProvideService(
+	cfg *setting.Cfg, sqlStore db.DB, router routing.RouteRegister, accessControl ac.AccessControl,
+	acService ac.Service, authnService authn.Service, keyService signingkeys.Service, tracer trace.Tracer,
+) (*Service, error) {
+	s := &Service{
+		cfg:           cfg,
+		store:         &xormStore{db: sqlStore},
+		signer:        &signer{keyService: keyService, issuer: cfg.AppURL},
+		accessControl: accessControl,
+		tokens:        localcache.New(cacheTTL, 2*cacheTTL),
+		now:           time.Now,
+		log:           log.New("capabilitytoken"),
+		tracer:        tracer,
+	}
+
+	if !cfg.CapabilityTokens.Enabled {
+		return s, nil
+	}
+
+	if err := declareFixedRoles(acService); err != nil {
+		return nil, err
+	}
+	authnService.RegisterClient(&Client{cfg: cfg, service: s, log: log.New("authn.capabilitytoken")})
+	s.registerRoutes(router, accessControl)
+
+	return s, nil
+}
+
+// Service mints capability tokens: signed tokens granting a few permissions on single resources until they expire.
+// Tokens are stored so they can be listed and revoked before they expire.
+type Service struct {
+	cfg           *setting.Cfg
+	store         store
+	signer        *signer
+	accessControl ac.AccessControl
+	// tokens caches the stored tokens of validated requests
+	tokens *localcache.CacheService
+	now    func() time.Time
+	log    log.Logger
+	tracer trace.Tracer
+}
+
+func (s *Service) CreateToken(ctx context.Context, requester identity.Requester, cmd *capabilitytoken.CreateTokenCommand) (*capabilitytoken.CreateTokenResult, error) {
+	ctx, span := s.tracer.Start(ctx, "capabilitytoken.CreateToken")
+	defer span.End()
+
+	if strings.TrimSpace(cmd.Name) == "" {
+		return nil, capabilitytoken.ErrInvalidName.Errorf("name is required")
+	}
+
+	ttl := s.cfg.CapabilityTokens.MaxTTL
+	if cmd.SecondsToLive != 0 {
+		ttl = time.Duration(cmd.SecondsToLive) * time.Second
+	}
+	if ttl <= 0 || ttl > s.cfg.CapabilityTokens.MaxTTL {
+		return nil, capabilitytoken.ErrInvalidTTL.Errorf("secondsToLive must be between 1 and %d", int64(s.cfg.CapabilityTokens.MaxTTL.Seconds()))
+	}
+
+	if err := s.validatePermissions(cmd.Permissions); err != nil {
+		return nil, err
+	}
+	for _, p := range cmd.Permissions {
+		allowed, err := s.accessControl.Evaluate(ctx, requester, ac.EvalPermission(p.Action, p.Scope))
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, capabilitytoken.ErrPermissionEscalation.Errorf("missing permission %s on %s", p.Action, p.Scope)
+		}
+	}
+
+	createdBy, err := requester.GetInternalID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	token := &capabilitytoken.Token{
+		OrgID:       requester.GetOrgID(),
+		UID:         util.GenerateShortUID(),
+		Name:        cmd.Name,
+		Permissions: cmd.Permissions,
+		CreatedBy:   createdBy,
+		Expires:     now.Add(ttl),
+		Created:     now,
+	}
+
+	key, err := s.signer.sign(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.CreateToken(ctx, token); err != nil {
+		return nil, err
+	}
+
+	return &capabilitytoken.CreateTokenResult{Token: token, Key: key}, nil
+}
+
+func (s *Service) ListTokens(ctx context.Context, orgID int64) ([]*capabilitytoken.Token, error) {
+	return s.store.ListTokens(ctx, orgID)
+}
+
+func (s *Service) RevokeToken(ctx context.Context, orgID int64, uid string) error {
+	if err := s.store.RevokeToken(ctx, orgID, uid); err != nil {
+		return err
+	}
+	s.tokens.Delete(uid)
+	return nil
+}
+
+func (s *Service) ValidateToken(ctx context.Context, key string) (*capabilitytoken.Token, error) {
+	ctx, span := s.tracer.Start(ctx, "capabilitytoken.ValidateToken")
+	defer span.End()
+
+	now := s.now()
+	claims, err := s.signer.verify(ctx, key, now)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.getToken(ctx, claims.ID)
+	if err != nil {
+		if errors.Is(err, capabilitytoken.ErrTokenNotFound) {
+			return nil, capabilitytoken.ErrInvalidToken.Errorf("token %s not found", claims.ID)
+		}
+		return nil, err
+	}
+	if token.OrgID != claims.OrgID {
+		return nil, capabilitytoken.ErrInvalidToken.Errorf("token %s doesn't belong to org %d", token.UID, claims.OrgID)
+	}
+	if token.Revoked {
+		return nil, capabilitytoken.ErrInvalidToken.Errorf("token %s was revoked", token.UID)
+	}
+	if !token.Expires.After(now) {
+		return nil, capabilitytoken.ErrInvalidToken.Errorf("token %s has expired", token.UID)
+	}
+	return token, nil
+}
+
+func (s *Service) getToken(ctx context.Context, uid string) (*capabilitytoken.Token, error) {
+	if cached, ok := s.tokens.Get(uid); ok {
+		return cached.(*capabilitytoken.Token), nil
+	}
+
+	token, err := s.store.GetToken(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	s.tokens.Set(uid, token, cacheTTL)
+	return token, nil
+}
+
+// validatePermissions checks the actions are allowed for capability tokens and the scopes target a single resource
+func (s *Service) validatePermissions(permissions []ac.Permission) error {
+	if len(permissions) == 0 {
+		return capabilitytoken.ErrInvalidPermission.Errorf("at least one permission is required")
+	}
+	for _, p := range permissions {
+		if !slices.Contains(s.cfg.CapabilityTokens.AllowedActions, p.Action) {
+			return capabilitytoken.ErrInvalidPermission.Errorf("action %s can't be granted by capability tokens", p.Action)
+		}
+		if p.Scope == "" || strings.Contains(p.Scope, "*") {
+			return capabilitytoken.ErrInvalidPermission.Errorf("scope of %s must target a single resource", p.Action)
+		}
+	}
+	return nil
+}
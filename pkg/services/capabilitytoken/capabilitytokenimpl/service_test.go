@@ -0,0 +1,197 @@
+package capabilitytokenimpl
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/capabilitytoken"
+	"github.com/grafana/grafana/pkg/services/signingkeys/signingkeystest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var dashboardRead = ac.Permission{Action: "dashboards:read", Scope: "dashboards:uid:1"}
+
+func TestService_CreateToken(t *testing.T) {
+	ctx := context.Background()
+	requester := newRequester(map[string][]string{"dashboards:read": {"dashboards:*"}})
+
+	t.Run("should mint a token valid until its expiry", func(t *testing.T) {
+		s, store := setupTestService(t)
+
+		result, err := s.CreateToken(ctx, requester, &capabilitytoken.CreateTokenCommand{Name: "share", Permissions: []ac.Permission{dashboardRead}, SecondsToLive: 600})
+		require.NoError(t, err)
+		assert.Contains(t, store.tokens, result.UID)
+		assert.Equal(t, s.now().Add(10*time.Minute), result.Expires)
+
+		token, err := s.ValidateToken(ctx, result.Key)
+		require.NoError(t, err)
+		assert.Equal(t, result.UID, token.UID)
+		assert.Equal(t, []ac.Permission{dashboardRead}, token.Permissions)
+
+		s.now = func() time.Time { return result.Expires.Add(time.Second) }
+		_, err = s.ValidateToken(ctx, result.Key)
+		assert.ErrorIs(t, err, capabilitytoken.ErrInvalidToken)
+	})
+
+	t.Run("should default to the max ttl", func(t *testing.T) {
+		s, _ := setupTestService(t)
+
+		result, err := s.CreateToken(ctx, requester, &capabilitytoken.CreateTokenCommand{Name: "share", Permissions: []ac.Permission{dashboardRead}})
+		require.NoError(t, err)
+		assert.Equal(t, s.now().Add(time.Hour), result.Expires)
+
+		_, err = s.CreateToken(ctx, requester, &capabilitytoken.CreateTokenCommand{Name: "share", Permissions: []ac.Permission{dashboardRead}, SecondsToLive: 7200})
+		assert.ErrorIs(t, err, capabilitytoken.ErrInvalidTTL)
+	})
+
+	t.Run("should only grant allowed actions on single resources", func(t *testing.T) {
+		s, _ := setupTestService(t)
+
+		_, err := s.CreateToken(ctx, requester, &capabilitytoken.CreateTokenCommand{Name: "share", Permissions: []ac.Permission{{Action: "dashboards:write", Scope: "dashboards:uid:1"}}})
+		assert.ErrorIs(t, err, capabilitytoken.ErrInvalidPermission)
+
+		_, err = s.CreateToken(ctx, requester, &capabilitytoken.CreateTokenCommand{Name: "share", Permissions: []ac.Permission{{Action: "dashboards:read", Scope: "dashboards:*"}}})
+		assert.ErrorIs(t, err, capabilitytoken.ErrInvalidPermission)
+
+		_, err = s.CreateToken(ctx, requester, &capabilitytoken.CreateTokenCommand{Name: "share"})
+		assert.ErrorIs(t, err, capabilitytoken.ErrInvalidPermission)
+	})
+
+	t.Run("should require the requester to hold the permissions", func(t *testing.T) {
+		s, store := setupTestService(t)
+
+		_, err := s.CreateToken(ctx, newRequester(nil), &capabilitytoken.CreateTokenCommand{Name: "share", Permissions: []ac.Permission{dashboardRead}})
+		assert.ErrorIs(t, err, capabilitytoken.ErrPermissionEscalation)
+		assert.Empty(t, store.tokens)
+	})
+}
+
+func TestService_ValidateToken(t *testing.T) {
+	ctx := context.Background()
+	requester := newRequester(map[string][]string{"dashboards:read": {"dashboards:*"}})
+
+	t.Run("should reject revoked tokens", func(t *testing.T) {
+		s, _ := setupTestService(t)
+
+		result, err := s.CreateToken(ctx, requester, &capabilitytoken.CreateTokenCommand{Name: "share", Permissions: []ac.Permission{dashboardRead}})
+		require.NoError(t, err)
+		_, err = s.ValidateToken(ctx, result.Key)
+		require.NoError(t, err)
+
+		require.NoError(t, s.RevokeToken(ctx, 1, result.UID))
+		_, err = s.ValidateToken(ctx, result.Key)
+		assert.ErrorIs(t, err, capabilitytoken.ErrInvalidToken)
+	})
+
+	t.Run("should reject tokens signed by another key", func(t *testing.T) {
+		s, _ := setupTestService(t)
+		other, _ := setupTestService(t)
+		other.store = s.store
+
+		result, err := other.CreateToken(ctx, requester, &capabilitytoken.CreateTokenCommand{Name: "share", Permissions: []ac.Permission{dashboardRead}})
+		require.NoError(t, err)
+
+		_, err = s.ValidateToken(ctx, result.Key)
+		assert.ErrorIs(t, err, capabilitytoken.ErrInvalidToken)
+	})
+
+	t.Run("should reject malformed tokens", func(t *testing.T) {
+		s, _ := setupTestService(t)
+
+		_, err := s.ValidateToken(ctx, capabilitytoken.TokenPrefix+"invalid")
+		assert.ErrorIs(t, err, capabilitytoken.ErrInvalidToken)
+
+		_, err = s.ValidateToken(ctx, "invalid")
+		assert.ErrorIs(t, err, capabilitytoken.ErrInvalidToken)
+	})
+}
+
+func newRequester(permissions map[string][]string) *user.SignedInUser {
+	return &user.SignedInUser{UserID: 1, OrgID: 1, Permissions: map[int64]map[string][]string{1: permissions}}
+}
+
+func setupTestService(t *testing.T) (*Service, *fakeStore) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	keyService := &signingkeystest.FakeSigningKeysService{
+		ExpectedKeyID:  "capability-key",
+		ExpectedSigner: key,
+		ExpectedJSONWebKeySet: jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: key.Public(), KeyID: "capability-key", Algorithm: string(jose.ES256), Use: "sig"},
+		}},
+	}
+
+	cfg := setting.NewCfg()
+	cfg.AppURL = "http://localhost:3000/"
+	cfg.CapabilityTokens = setting.CapabilityTokensSettings{
+		Enabled:        true,
+		MaxTTL:         time.Hour,
+		AllowedActions: []string{"dashboards:read", "datasources:query"},
+	}
+
+	now := time.Now().Truncate(time.Second)
+	store := &fakeStore{tokens: map[string]*capabilitytoken.Token{}}
+	return &Service{
+		cfg:           cfg,
+		store:         store,
+		signer:        &signer{keyService: keyService, issuer: cfg.AppURL},
+		accessControl: acimpl.ProvideAccessControlTest(),
+		tokens:        localcache.New(0, 0),
+		now:           func() time.Time { return now },
+		log:           log.NewNopLogger(),
+		tracer:        tracing.InitializeTracerForTest(),
+	}, store
+}
+
+type fakeStore struct {
+	tokens map[string]*capabilitytoken.Token
+}
+
+func (f *fakeStore) CreateToken(ctx context.Context, token *capabilitytoken.Token) error {
+	f.tokens[token.UID] = token
+	return nil
+}
+
+func (f *fakeStore) GetToken(ctx context.Context, uid string) (*capabilitytoken.Token, error) {
+	token, ok := f.tokens[uid]
+	if !ok {
+		return nil, capabilitytoken.ErrTokenNotFound.Errorf("capability token %s not found", uid)
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (f *fakeStore) ListTokens(ctx context.Context, orgID int64) ([]*capabilitytoken.Token, error) {
+	tokens := make([]*capabilitytoken.Token, 0)
+	for _, token := range f.tokens {
+		if token.OrgID == orgID {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (f *fakeStore) RevokeToken(ctx context.Context, orgID int64, uid string) error {
+	token, ok := f.tokens[uid]
+	if !ok || token.OrgID != orgID {
+		return capabilitytoken.ErrTokenNotFound.Errorf("capability token %s not found", uid)
+	}
+	token.Revoked = true
+	return nil
+}
@@ -0,0 +1,100 @@
+package capabilitytokenimpl
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+
+	"github.com/grafana/grafana/pkg/services/capabilitytoken"
+	"github.com/grafana/grafana/pkg/services/signingkeys"
+)
+
+const (
+	keyPrefix   = "capability"
+	headerKeyID = "kid"
+	// audience keeps capability tokens from being accepted where other tokens signed by the instance are
+	audience = "grafana-capability-token"
+)
+
+type tokenClaims struct {
+	jwt.Claims
+	OrgID int64 `json:"org_id"`
+}
+
+// signer signs capability tokens with the ES256 key of the instance, verifiers use the public keys of the JWKS
+type signer struct {
+	keyService signingkeys.Service
+	issuer     string
+}
+
+func (s *signer) sign(ctx context.Context, token *capabilitytoken.Token) (string, error) {
+	id, key, err := s.keyService.GetOrCreatePrivateKey(ctx, keyPrefix, jose.ES256)
+	if err != nil {
+		return "", err
+	}
+
+	joseSigner, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, &jose.SignerOptions{
+		ExtraHeaders: map[jose.HeaderKey]any{
+			headerKeyID:     id,
+			jose.HeaderType: "jwt",
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signed, err := jwt.Signed(joseSigner).Claims(tokenClaims{
+		Claims: jwt.Claims{
+			ID:       token.UID,
+			Issuer:   s.issuer,
+			Audience: jwt.Audience{audience},
+			IssuedAt: jwt.NewNumericDate(token.Created),
+			Expiry:   jwt.NewNumericDate(token.Expires),
+		},
+		OrgID: token.OrgID,
+	}).CompactSerialize()
+	if err != nil {
+		return "", err
+	}
+	return capabilitytoken.TokenPrefix + signed, nil
+}
+
+// verify checks the signature and the expiry of the token and returns its claims
+func (s *signer) verify(ctx context.Context, token string, now time.Time) (*tokenClaims, error) {
+	raw, ok := strings.CutPrefix(token, capabilitytoken.TokenPrefix)
+	if !ok {
+		return nil, capabilitytoken.ErrInvalidToken.Errorf("missing token prefix")
+	}
+
+	parsed, err := jwt.ParseSigned(raw)
+	if err != nil {
+		return nil, capabilitytoken.ErrInvalidToken.Errorf("failed to parse token: %w", err)
+	}
+	if len(parsed.Headers) != 1 || parsed.Headers[0].Algorithm != string(jose.ES256) {
+		return nil, capabilitytoken.ErrInvalidToken.Errorf("unexpected token algorithm")
+	}
+
+	jwks, err := s.keyService.GetJWKS(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys := jwks.Key(parsed.Headers[0].KeyID)
+	if len(keys) == 0 {
+		return nil, capabilitytoken.ErrInvalidToken.Errorf("unknown signing key %q", parsed.Headers[0].KeyID)
+	}
+
+	claims := &tokenClaims{}
+	if err := parsed.Claims(keys[0].Key, claims); err != nil {
+		return nil, capabilitytoken.ErrInvalidToken.Errorf("invalid signature: %w", err)
+	}
+	if err := claims.ValidateWithLeeway(jwt.Expected{Issuer: s.issuer, Audience: jwt.Audience{audience}, Time: now}, 0); err != nil {
+		return nil, capabilitytoken.ErrInvalidToken.Errorf("invalid claims: %w", err)
+	}
+	if claims.ID == "" || claims.OrgID == 0 {
+		return nil, capabilitytoken.ErrInvalidToken.Errorf("missing claims in token")
+	}
+	return claims, nil
+}
@@ -0,0 +1,128 @@
+package capabilitytokenimpl
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/capabilitytoken"
+)
+
+// capabilityToken is the stored form of a capabilitytoken.Token, its permissions are encoded as JSON
+type capabilityToken struct {
+	ID          int64  `xorm:"pk autoincr 'id'"`
+	OrgID       int64  `xorm:"org_id"`
+	UID         string `xorm:"uid"`
+	Name        string
+	Permissions string
+	CreatedBy   int64 `xorm:"created_by"`
+	Expires     time.Time
+	Revoked     bool
+	Created     time.Time
+}
+
+func (capabilityToken) TableName() string {
+	return "capability_token"
+}
+
+func (t *capabilityToken) toToken() (*capabilitytoken.Token, error) {
+	token := &capabilitytoken.Token{
+		ID:        t.ID,
+		OrgID:     t.OrgID,
+		UID:       t.UID,
+		Name:      t.Name,
+		CreatedBy: t.CreatedBy,
+		Expires:   t.Expires,
+		Revoked:   t.Revoked,
+		Created:   t.Created,
+	}
+	if err := json.Unmarshal([]byte(t.Permissions), &token.Permissions); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+type store interface {
+	CreateToken(ctx context.Context, token *capabilitytoken.Token) error
+	// GetToken looks the token up by uid only, the org of the token is checked against its claims
+	GetToken(ctx context.Context, uid string) (*capabilitytoken.Token, error)
+	ListTokens(ctx context.Context, orgID int64) ([]*capabilitytoken.Token, error)
+	RevokeToken(ctx context.Context, orgID int64, uid string) error
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (s *xormStore) CreateToken(ctx context.Context, token *capabilitytoken.Token) error {
+	permissions, err := json.Marshal(token.Permissions)
+	if err != nil {
+		return err
+	}
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		stored := capabilityToken{
+			OrgID:       token.OrgID,
+			UID:         token.UID,
+			Name:        token.Name,
+			Permissions: string(permissions),
+			CreatedBy:   token.CreatedBy,
+			Expires:     token.Expires,
+			Created:     token.Created,
+		}
+		if _, err := sess.Insert(&stored); err != nil {
+			return err
+		}
+		token.ID = stored.ID
+		return nil
+	})
+}
+
+func (s *xormStore) GetToken(ctx context.Context, uid string) (*capabilitytoken.Token, error) {
+	var token *capabilitytoken.Token
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var stored capabilityToken
+		has, err := sess.Where("uid = ?", uid).Get(&stored)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return capabilitytoken.ErrTokenNotFound.Errorf("capability token %s not found", uid)
+		}
+		token, err = stored.toToken()
+		return err
+	})
+	return token, err
+}
+
+func (s *xormStore) ListTokens(ctx context.Context, orgID int64) ([]*capabilitytoken.Token, error) {
+	tokens := make([]*capabilitytoken.Token, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		stored := make([]capabilityToken, 0)
+		if err := sess.Where("org_id = ?", orgID).Desc("created").Find(&stored); err != nil {
+			return err
+		}
+		for i := range stored {
+			token, err := stored[i].toToken()
+			if err != nil {
+				return err
+			}
+			tokens = append(tokens, token)
+		}
+		return nil
+	})
+	return tokens, err
+}
+
+func (s *xormStore) RevokeToken(ctx context.Context, orgID int64, uid string) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Cols("revoked").Update(&capabilityToken{Revoked: true})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return capabilitytoken.ErrTokenNotFound.Errorf("capability token %s not found", uid)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,69 @@
+package capabilitytokenimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/capabilitytoken"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	store := &xormStore{db: db.InitTestDB(t)}
+	created := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, uid := range []string{"first", "second"} {
+		token := &capabilitytoken.Token{
+			OrgID: 1, UID: uid, Name: uid, CreatedBy: 2,
+			Permissions: []ac.Permission{{Action: "dashboards:read", Scope: "dashboards:uid:abc"}},
+			Expires:     created.Add(time.Hour), Created: created.Add(time.Duration(i) * time.Minute),
+		}
+		require.NoError(t, store.CreateToken(ctx, token))
+		assert.NotZero(t, token.ID)
+	}
+
+	t.Run("should get a token by uid with its permissions", func(t *testing.T) {
+		token, err := store.GetToken(ctx, "first")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), token.OrgID)
+		assert.Equal(t, []ac.Permission{{Action: "dashboards:read", Scope: "dashboards:uid:abc"}}, token.Permissions)
+		assert.False(t, token.Revoked)
+
+		_, err = store.GetToken(ctx, "missing")
+		assert.ErrorIs(t, err, capabilitytoken.ErrTokenNotFound)
+	})
+
+	t.Run("should list the tokens of an org, the newest first", func(t *testing.T) {
+		tokens, err := store.ListTokens(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, tokens, 2)
+		assert.Equal(t, "second", tokens[0].UID)
+
+		tokens, err = store.ListTokens(ctx, 2)
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+
+	t.Run("should revoke a token of the org", func(t *testing.T) {
+		assert.ErrorIs(t, store.RevokeToken(ctx, 2, "first"), capabilitytoken.ErrTokenNotFound)
+
+		require.NoError(t, store.RevokeToken(ctx, 1, "first"))
+		token, err := store.GetToken(ctx, "first")
+		require.NoError(t, err)
+		assert.True(t, token.Revoked)
+	})
+}
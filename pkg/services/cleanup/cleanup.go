@@ -21,6 +21,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/ngalert/image"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	tempuser "github.com/grafana/grafana/pkg/services/temp_user"
@@ -46,12 +47,14 @@ type CleanUpService struct {
 	annotationCleaner         annotations.Cleaner
 	dashboardService          dashboards.DashboardService
 	alertRuleService          AlertRuleService
+	publicDashboardService    publicdashboards.Service
 }
 
 func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockService,
 	shortURLService shorturls.Service, sqlstore db.DB, queryHistoryService queryhistory.Service,
 	dashboardVersionService dashver.Service, dashSnapSvc dashboardsnapshots.Service, deleteExpiredImageService *image.DeleteExpiredService,
-	tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner, dashboardService dashboards.DashboardService, service AlertRuleService) *CleanUpService {
+	tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner, dashboardService dashboards.DashboardService, service AlertRuleService,
+	publicDashboardService publicdashboards.Service) *CleanUpService {
 	s := &CleanUpService{
 		Cfg:                       cfg,
 		ServerLockService:         serverLockService,
@@ -67,6 +70,7 @@ func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockSe
 		annotationCleaner:         annotationCleaner,
 		dashboardService:          dashboardService,
 		alertRuleService:          service,
+		publicDashboardService:    publicDashboardService,
 	}
 	return s
 }
@@ -83,6 +87,10 @@ func (j cleanUpJob) String() string {
 func (srv *CleanUpService) Run(ctx context.Context) error {
 	srv.cleanUpTmpFiles(ctx)
 
+	// the annotations are cleaned on their own schedule, a long cleanup of a large annotation table
+	// neither delays nor is cancelled by the timeout of the other cleanup jobs
+	go srv.runAnnotationCleanup(ctx)
+
 	ticker := time.NewTicker(time.Minute * 10)
 	for {
 		select {
@@ -107,10 +115,12 @@ func (srv *CleanUpService) clean(ctx context.Context) {
 		{"delete expired snapshots", srv.deleteExpiredSnapshots},
 		{"delete expired dashboard versions", srv.deleteExpiredDashboardVersions},
 		{"delete expired images", srv.deleteExpiredImages},
-		{"cleanup old annotations", srv.cleanUpOldAnnotations},
 		{"expire old user invites", srv.expireOldUserInvites},
 		{"delete stale query history", srv.deleteStaleQueryHistory},
 		{"expire old email verifications", srv.expireOldVerifications},
+		{"delete expired public dashboard sessions", srv.deleteExpiredPublicDashboardSessions},
+		{"delete old public dashboard query logs", srv.deleteOldPublicDashboardQueryLogs},
+		{"delete expired short URLs", srv.deleteExpiredShortURLs},
 	}
 
 	if srv.Cfg.ShortLinkExpiration > 0 {
@@ -137,10 +147,25 @@ func (srv *CleanUpService) clean(ctx context.Context) {
 	logger.Info("Completed cleanup jobs", "duration", time.Since(start))
 }
 
+func (srv *CleanUpService) runAnnotationCleanup(ctx context.Context) {
+	ticker := time.NewTicker(srv.Cfg.AnnotationCleanupJobInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ctx, span := srv.tracer.Start(ctx, "cleanup old annotations")
+			srv.cleanUpOldAnnotations(ctx)
+			span.End()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 func (srv *CleanUpService) cleanUpOldAnnotations(ctx context.Context) {
 	logger := srv.log.FromContext(ctx)
 	affected, affectedTags, err := srv.annotationCleaner.Run(ctx, srv.Cfg)
-	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
 		logger.Error("failed to clean up old annotations", "error", err)
 	} else {
 		logger.Debug("Deleted excess annotations", "annotations affected", affected, "annotation tags affected", affectedTags)
@@ -270,6 +295,26 @@ func (srv *CleanUpService) expireOldVerifications(ctx context.Context) {
 	}
 }
 
+func (srv *CleanUpService) deleteExpiredPublicDashboardSessions(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	rowsCount, err := srv.publicDashboardService.DeleteExpiredSessions(ctx)
+	if err != nil {
+		logger.Error("Problem deleting expired public dashboard sessions", "error", err.Error())
+	} else {
+		logger.Debug("Deleted expired public dashboard sessions", "rows affected", rowsCount)
+	}
+}
+
+func (srv *CleanUpService) deleteOldPublicDashboardQueryLogs(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	rowsCount, err := srv.publicDashboardService.DeleteOldQueryLogs(ctx)
+	if err != nil {
+		logger.Error("Problem deleting old public dashboard query logs", "error", err.Error())
+	} else {
+		logger.Debug("Deleted old public dashboard query logs", "rows affected", rowsCount)
+	}
+}
+
 func (srv *CleanUpService) deleteStaleShortURLs(ctx context.Context) {
 	logger := srv.log.FromContext(ctx)
 	cmd := shorturls.DeleteShortUrlCommand{
@@ -282,6 +327,16 @@ func (srv *CleanUpService) deleteStaleShortURLs(ctx context.Context) {
 	}
 }
 
+func (srv *CleanUpService) deleteExpiredShortURLs(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	cmd := shorturls.DeleteShortUrlCommand{Expired: true}
+	if err := srv.ShortURLService.DeleteStaleShortURLs(ctx, &cmd); err != nil {
+		logger.Error("Problem deleting expired short urls", "error", err.Error())
+	} else {
+		logger.Debug("Deleted expired short urls", "rows affected", cmd.NumDeleted)
+	}
+}
+
 func (srv *CleanUpService) deleteStaleQueryHistory(ctx context.Context) {
 	logger := srv.log.FromContext(ctx)
 	// Delete query history from 14+ days ago with exception of starred queries
@@ -3,6 +3,7 @@ package contexthandler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -22,6 +23,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ipallowlist"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
@@ -95,6 +97,13 @@ func (h *ContextHandler) Middleware(next http.Handler) http.Handler {
 		// Preserve the original span so the setRequestContext span doesn't get propagated as a parent of the rest of the request
 		ctx = trace.ContextWithSpan(ctx, span)
 
+		// Requests from outside of an IP allowlist are rejected here so that routes
+		// which don't require a signed in user don't serve them either
+		if reqContext := FromContext(ctx); reqContext != nil && errors.Is(reqContext.LookupTokenErr, ipallowlist.ErrBlocked) {
+			reqContext.WriteErr(reqContext.LookupTokenErr)
+			return
+		}
+
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -144,6 +153,13 @@ func (h *ContextHandler) setRequestContext(ctx context.Context) context.Context
 	h.excludeSensitiveHeadersFromRequest(reqContext.Req)
 
 	reqContext.Logger = reqContext.Logger.New("userId", reqContext.UserID, "orgId", reqContext.OrgID, "uname", reqContext.Login)
+	if reqContext.Impersonator != nil {
+		reqContext.Logger = reqContext.Logger.New(
+			"impersonatorId", reqContext.Impersonator.UserID,
+			"impersonatorLogin", reqContext.Impersonator.Login,
+			"impersonationUid", reqContext.Impersonator.SessionUID,
+		)
+	}
 	span.AddEvent("user", trace.WithAttributes(
 		attribute.String("uname", reqContext.Login),
 		attribute.Int64("orgId", reqContext.OrgID),
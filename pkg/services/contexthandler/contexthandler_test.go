@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ipallowlist"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
@@ -45,6 +46,25 @@ func TestContextHandler(t *testing.T) {
 		require.NoError(t, res.Body.Close())
 	})
 
+	t.Run("should respond with forbidden if the client IP is not allowed", func(t *testing.T) {
+		handler := contexthandler.ProvideService(
+			setting.NewCfg(),
+			&authntest.FakeService{ExpectedErr: ipallowlist.ErrBlocked.Errorf("blocked")},
+			featuremgmt.WithFeatures(),
+		)
+
+		server := webtest.NewServer(t, routing.NewRouteRegister())
+		server.Mux.Use(handler.Middleware)
+		server.Mux.Get("/api/handler", func(c *contextmodel.ReqContext) {
+			t.Fatal("handler should not be called")
+		})
+
+		res, err := server.Send(server.NewGetRequest("/api/handler"))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
 	t.Run("should set identity on successful authentication", func(t *testing.T) {
 		id := &authn.Identity{ID: "1", Type: claims.TypeUser, OrgID: 1}
 		handler := contexthandler.ProvideService(
@@ -0,0 +1,259 @@
+package correlations
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+const (
+	signalMetrics = "metrics"
+	signalLogs    = "logs"
+	signalTraces  = "traces"
+
+	// minLabelOverlap is the share of the labels of the data source with the fewest labels both data sources
+	// must have in common to be suggested
+	minLabelOverlap = 0.3
+	// maxSelectorLabels bounds the labels of the selectors of the suggested targets
+	maxSelectorLabels = 3
+)
+
+// The signals of the data source types the analyzer understands
+var signalsByType = map[string]string{
+	datasources.DS_PROMETHEUS: signalMetrics,
+	datasources.DS_LOKI:       signalLogs,
+	datasources.DS_ES:         signalLogs,
+	datasources.DS_TEMPO:      signalTraces,
+	datasources.DS_JAEGER:     signalTraces,
+	datasources.DS_ZIPKIN:     signalTraces,
+}
+
+var (
+	selectorRegex   = regexp.MustCompile(`\{([^{}]*)\}`)
+	matcherRegex    = regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!~|!=|=)\s*"((?:[^"\\]|\\.)*)"`)
+	traceFieldRegex = regexp.MustCompile(`(?i)\b(trace_?id|span_?id)\b`)
+)
+
+// dataSourceProfile sums up the queries of a data source
+type dataSourceProfile struct {
+	dataSource *datasources.DataSource
+	signal     string
+	queries    int
+	// labels are the label names of the selectors of the queries, with the counts of their values
+	labels map[string]map[string]int
+	// traceFields are the trace and span ID fields the queries use, with their counts
+	traceFields map[string]int
+}
+
+func newProfiles(dataSources []*datasources.DataSource) map[string]*dataSourceProfile {
+	profiles := make(map[string]*dataSourceProfile, len(dataSources))
+	for _, ds := range dataSources {
+		signal, ok := signalsByType[ds.Type]
+		if !ok {
+			continue
+		}
+		profiles[ds.UID] = &dataSourceProfile{
+			dataSource:  ds,
+			signal:      signal,
+			labels:      map[string]map[string]int{},
+			traceFields: map[string]int{},
+		}
+	}
+	return profiles
+}
+
+// addHistoryQueries adds the queries of an entry of the query history. The queries of an entry of a mixed data
+// source carry their own data source.
+func addHistoryQueries(profiles map[string]*dataSourceProfile, dataSourceUID string, raw string) {
+	var queries []map[string]any
+	if err := json.Unmarshal([]byte(raw), &queries); err != nil {
+		return
+	}
+
+	for _, q := range queries {
+		uid := dataSourceUID
+		if ds, ok := q["datasource"].(map[string]any); ok {
+			if dsUID, ok := ds["uid"].(string); ok && dsUID != "" {
+				uid = dsUID
+			}
+		}
+		profile, ok := profiles[uid]
+		if !ok {
+			continue
+		}
+		for _, key := range []string{"expr", "query", "expression"} {
+			if expr, ok := q[key].(string); ok && expr != "" {
+				profile.add(expr)
+				break
+			}
+		}
+	}
+}
+
+func (p *dataSourceProfile) add(expr string) {
+	p.queries++
+
+	for _, selector := range selectorRegex.FindAllStringSubmatch(expr, -1) {
+		for _, matcher := range matcherRegex.FindAllStringSubmatch(selector[1], -1) {
+			name, op, value := matcher[1], matcher[2], matcher[3]
+			if name == "__name__" {
+				continue
+			}
+			if p.labels[name] == nil {
+				p.labels[name] = map[string]int{}
+			}
+			// only the equality matchers tell the values the data sources have in common
+			if op == "=" && value != "" && !strings.HasPrefix(value, "$") {
+				p.labels[name][value]++
+			}
+		}
+	}
+
+	for _, field := range traceFieldRegex.FindAllString(expr, -1) {
+		p.traceFields[field]++
+	}
+}
+
+// sharedLabels returns the labels of the two profiles which have values in common, the most used first
+func sharedLabels(a, b *dataSourceProfile) []string {
+	counts := map[string]int{}
+	for name, values := range a.labels {
+		other, ok := b.labels[name]
+		if !ok {
+			continue
+		}
+		for value, count := range values {
+			if otherCount, ok := other[value]; ok {
+				counts[name] += count + otherCount
+			}
+		}
+	}
+
+	shared := make([]string, 0, len(counts))
+	for name := range counts {
+		shared = append(shared, name)
+	}
+	sort.Slice(shared, func(i, j int) bool {
+		if counts[shared[i]] != counts[shared[j]] {
+			return counts[shared[i]] > counts[shared[j]]
+		}
+		return shared[i] < shared[j]
+	})
+	return shared
+}
+
+// analyze suggests correlations between the profiles which have at least minQueries queries. The suggestions are
+// sorted by source, target and kind.
+func analyze(profiles map[string]*dataSourceProfile, minQueries int) []CorrelationSuggestion {
+	var suggestions []CorrelationSuggestion
+	for _, source := range profiles {
+		if source.queries < minQueries {
+			continue
+		}
+		for _, target := range profiles {
+			if target == source || target.queries < minQueries {
+				continue
+			}
+			if s, ok := suggestTraceCorrelation(source, target); ok {
+				suggestions = append(suggestions, s)
+			}
+			if s, ok := suggestLabelCorrelation(source, target); ok {
+				suggestions = append(suggestions, s)
+			}
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.SourceUID != b.SourceUID {
+			return a.SourceUID < b.SourceUID
+		}
+		if a.TargetUID != b.TargetUID {
+			return a.TargetUID < b.TargetUID
+		}
+		return a.Kind < b.Kind
+	})
+	return suggestions
+}
+
+// suggestTraceCorrelation links the logs using trace or span IDs to a tracing data source
+func suggestTraceCorrelation(source, target *dataSourceProfile) (CorrelationSuggestion, bool) {
+	if source.signal != signalLogs || target.signal != signalTraces || len(source.traceFields) == 0 {
+		return CorrelationSuggestion{}, false
+	}
+
+	field, references := "", 0
+	for name, count := range source.traceFields {
+		if count > references || (count == references && name < field) {
+			field, references = name, count
+		}
+	}
+
+	queryTarget := map[string]any{"query": "${__value.raw}"}
+	if target.dataSource.Type == datasources.DS_TEMPO {
+		queryTarget["queryType"] = "traceql"
+	}
+
+	return CorrelationSuggestion{
+		SourceUID:   source.dataSource.UID,
+		TargetUID:   target.dataSource.UID,
+		Kind:        SuggestionTraceID,
+		Label:       fmt.Sprintf("Trace in %s", target.dataSource.Name),
+		Description: fmt.Sprintf("The queries of %s use the %s field", source.dataSource.Name, field),
+		Config:      CorrelationConfig{Field: field, Target: queryTarget},
+		Score:       min(1, float64(references)/float64(source.queries)),
+		Evidence:    []string{fmt.Sprintf("%d of %d queries use %s", references, source.queries, field)},
+	}, true
+}
+
+// suggestLabelCorrelation links two data sources of logs or metrics whose queries select the same labels
+func suggestLabelCorrelation(source, target *dataSourceProfile) (CorrelationSuggestion, bool) {
+	labelSignals := []string{signalLogs, signalMetrics}
+	if !slices.Contains(labelSignals, source.signal) || !slices.Contains(labelSignals, target.signal) {
+		return CorrelationSuggestion{}, false
+	}
+	// only loki and prometheus queries have label selectors
+	if source.dataSource.Type == datasources.DS_ES || target.dataSource.Type == datasources.DS_ES {
+		return CorrelationSuggestion{}, false
+	}
+
+	shared := sharedLabels(source, target)
+	if len(shared) == 0 {
+		return CorrelationSuggestion{}, false
+	}
+	score := float64(len(shared)) / float64(min(len(source.labels), len(target.labels)))
+	if score < minLabelOverlap {
+		return CorrelationSuggestion{}, false
+	}
+
+	kind := SuggestionSharedLabels
+	if source.signal != target.signal {
+		kind = SuggestionLogMetric
+	}
+
+	selectorLabels := shared[:min(len(shared), maxSelectorLabels)]
+	matchers := make([]string, 0, len(selectorLabels))
+	evidence := make([]string, 0, len(shared))
+	for _, name := range selectorLabels {
+		matchers = append(matchers, fmt.Sprintf(`%s="${%s}"`, name, name))
+	}
+	for _, name := range shared {
+		evidence = append(evidence, fmt.Sprintf("both data sources select the %s label", name))
+	}
+
+	return CorrelationSuggestion{
+		SourceUID:   source.dataSource.UID,
+		TargetUID:   target.dataSource.UID,
+		Kind:        kind,
+		Label:       fmt.Sprintf("%s with the same %s", target.dataSource.Name, strings.Join(selectorLabels, ", ")),
+		Description: fmt.Sprintf("The queries of %s and %s share the labels %s", source.dataSource.Name, target.dataSource.Name, strings.Join(shared, ", ")),
+		Config:      CorrelationConfig{Field: shared[0], Target: map[string]any{"expr": "{" + strings.Join(matchers, ", ") + "}"}},
+		Score:       min(1, score),
+		Evidence:    evidence,
+	}, true
+}
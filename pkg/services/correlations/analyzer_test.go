@@ -0,0 +1,91 @@
+package correlations
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+func TestAnalyze(t *testing.T) {
+	newTestProfiles := func() map[string]*dataSourceProfile {
+		return newProfiles([]*datasources.DataSource{
+			{UID: "loki", Name: "Logs", Type: datasources.DS_LOKI},
+			{UID: "prom", Name: "Metrics", Type: datasources.DS_PROMETHEUS},
+			{UID: "tempo", Name: "Traces", Type: datasources.DS_TEMPO},
+			{UID: "graphite", Name: "Graphite", Type: datasources.DS_GRAPHITE},
+		})
+	}
+	addQueries := func(profiles map[string]*dataSourceProfile, uid string, key string, expr string, count int) {
+		for i := 0; i < count; i++ {
+			addHistoryQueries(profiles, uid, fmt.Sprintf(`[{"refId":"A",%q:%q}]`, key, expr))
+		}
+	}
+
+	t.Run("Ignores the data sources the analyzer doesn't understand", func(t *testing.T) {
+		profiles := newTestProfiles()
+		require.Len(t, profiles, 3)
+		assert.NotContains(t, profiles, "graphite")
+	})
+
+	t.Run("Reads the data source of the queries of mixed entries", func(t *testing.T) {
+		profiles := newTestProfiles()
+		addHistoryQueries(profiles, "-- Mixed --", `[
+			{"refId":"A","datasource":{"uid":"loki"},"expr":"{app=\"api\"}"},
+			{"refId":"B","datasource":{"uid":"prom"},"expr":"up{app=\"api\", job=~\"node.*\"}"},
+			{"refId":"C","datasource":{"uid":"unknown"},"expr":"{app=\"api\"}"}
+		]`)
+		addHistoryQueries(profiles, "loki", `not json`)
+
+		assert.Equal(t, 1, profiles["loki"].queries)
+		assert.Equal(t, 1, profiles["prom"].queries)
+		assert.Equal(t, map[string]map[string]int{"app": {"api": 1}, "job": {}}, profiles["prom"].labels)
+	})
+
+	t.Run("Suggests label and trace correlations", func(t *testing.T) {
+		profiles := newTestProfiles()
+		addQueries(profiles, "loki", "expr", `{app="api", namespace="prod"} |= "error" | logfmt | traceID != ""`, 5)
+		addQueries(profiles, "prom", "expr", `rate(http_requests_total{app="api", namespace="prod"}[5m])`, 5)
+		addQueries(profiles, "tempo", "query", `4bf92f3577b34da6a3ce929d0e0e4736`, 5)
+
+		suggestions := analyze(profiles, 5)
+		require.Len(t, suggestions, 3)
+
+		assert.Equal(t, "loki", suggestions[0].SourceUID)
+		assert.Equal(t, "prom", suggestions[0].TargetUID)
+		assert.Equal(t, SuggestionLogMetric, suggestions[0].Kind)
+		assert.Equal(t, "app", suggestions[0].Config.Field)
+		assert.Equal(t, map[string]any{"expr": `{app="${app}", namespace="${namespace}"}`}, suggestions[0].Config.Target)
+		assert.Equal(t, 1.0, suggestions[0].Score)
+
+		assert.Equal(t, "loki", suggestions[1].SourceUID)
+		assert.Equal(t, "tempo", suggestions[1].TargetUID)
+		assert.Equal(t, SuggestionTraceID, suggestions[1].Kind)
+		assert.Equal(t, "traceID", suggestions[1].Config.Field)
+		assert.Equal(t, map[string]any{"query": "${__value.raw}", "queryType": "traceql"}, suggestions[1].Config.Target)
+
+		assert.Equal(t, "prom", suggestions[2].SourceUID)
+		assert.Equal(t, "loki", suggestions[2].TargetUID)
+		assert.Equal(t, SuggestionLogMetric, suggestions[2].Kind)
+	})
+
+	t.Run("Ignores the data sources with too few queries", func(t *testing.T) {
+		profiles := newTestProfiles()
+		addQueries(profiles, "loki", "expr", `{app="api"} | traceID != ""`, 5)
+		addQueries(profiles, "prom", "expr", `up{app="api"}`, 4)
+		addQueries(profiles, "tempo", "query", `4bf92f3577b34da6a3ce929d0e0e4736`, 4)
+
+		assert.Empty(t, analyze(profiles, 5))
+	})
+
+	t.Run("Ignores labels without values in common", func(t *testing.T) {
+		profiles := newTestProfiles()
+		addQueries(profiles, "loki", "expr", `{app="api"}`, 5)
+		addQueries(profiles, "prom", "expr", `up{app="web"}`, 5)
+
+		assert.Empty(t, analyze(profiles, 5))
+	})
+}
@@ -29,6 +29,20 @@ func (s *CorrelationsService) registerAPIEndpoints() {
 			entities.Patch("/", authorize(ac.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(s.updateHandler))
 		})
 	}, middleware.ReqSignedIn)
+
+	s.RouteRegister.Group("/api/datasources/correlations", func(entities routing.RouteRegister) {
+		entities.Get("/suggestions", authorize(ac.EvalPermission(datasources.ActionRead)), routing.Wrap(s.getSuggestionsHandler))
+		entities.Post("/suggestions/analyze", authorize(ac.EvalPermission(datasources.ActionWrite)), routing.Wrap(s.analyzeHandler))
+		entities.Post("/suggestions/:suggestionUID/accept", authorize(ac.EvalPermission(datasources.ActionRead)), routing.Wrap(s.acceptSuggestionHandler))
+		entities.Post("/suggestions/:suggestionUID/dismiss", authorize(ac.EvalPermission(datasources.ActionRead)), routing.Wrap(s.dismissSuggestionHandler))
+
+		entities.Get("/transformation-chains", authorize(ac.EvalPermission(datasources.ActionRead)), routing.Wrap(s.getTransformationChainsHandler))
+		entities.Post("/transformation-chains", authorize(ac.EvalPermission(datasources.ActionWrite)), routing.Wrap(s.createTransformationChainHandler))
+		entities.Get("/transformation-chains/:chainUID", authorize(ac.EvalPermission(datasources.ActionRead)), routing.Wrap(s.getTransformationChainHandler))
+		entities.Put("/transformation-chains/:chainUID", authorize(ac.EvalPermission(datasources.ActionWrite)), routing.Wrap(s.updateTransformationChainHandler))
+		entities.Delete("/transformation-chains/:chainUID", authorize(ac.EvalPermission(datasources.ActionWrite)), routing.Wrap(s.deleteTransformationChainHandler))
+		entities.Post("/transformations/test", authorize(ac.EvalPermission(datasources.ActionRead)), routing.Wrap(s.testTransformationsHandler))
+	}, middleware.ReqSignedIn)
 }
 
 // swagger:route POST /datasources/uid/{sourceUID}/correlations correlations createCorrelation
@@ -55,6 +69,9 @@ func (s *CorrelationsService) createHandler(c *contextmodel.ReqContext) response
 		if errors.Is(err, ErrSourceDataSourceDoesNotExists) || errors.Is(err, ErrTargetDataSourceDoesNotExists) {
 			return response.Error(http.StatusNotFound, "Data source not found", err)
 		}
+		if errors.Is(err, ErrTransformationChainNotFound) {
+			return response.Error(http.StatusBadRequest, "Transformation chain not found", err)
+		}
 		return response.Error(http.StatusInternalServerError, "Failed to add correlation", err)
 	}
 
@@ -169,6 +186,10 @@ func (s *CorrelationsService) updateHandler(c *contextmodel.ReqContext) response
 			return response.Error(http.StatusForbidden, "Correlation can only be edited via provisioning", err)
 		}
 
+		if errors.Is(err, ErrTransformationChainNotFound) {
+			return response.Error(http.StatusBadRequest, "Transformation chain not found", err)
+		}
+
 		return response.Error(http.StatusInternalServerError, "Failed to update correlation", err)
 	}
 
@@ -352,3 +373,357 @@ type GetCorrelationsResponse struct {
 	// in: body
 	Body []Correlation `json:"body"`
 }
+
+// swagger:route GET /datasources/correlations/suggestions correlations getCorrelationSuggestions
+//
+// Gets the correlations suggested by the analysis of the query history.
+//
+// Responses:
+// 200: getCorrelationSuggestionsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *CorrelationsService) getSuggestionsHandler(c *contextmodel.ReqContext) response.Response {
+	state := SuggestionState(c.Query("state"))
+	switch state {
+	case "", SuggestionPending, SuggestionAccepted, SuggestionDismissed:
+	default:
+		return response.Error(http.StatusBadRequest, "Invalid suggestion state", nil)
+	}
+
+	suggestions, err := s.GetSuggestions(c.Req.Context(), GetSuggestionsQuery{OrgId: c.GetOrgID(), State: state})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get correlation suggestions", err)
+	}
+
+	return response.JSON(http.StatusOK, suggestions)
+}
+
+// swagger:parameters getCorrelationSuggestions
+type GetCorrelationSuggestionsParams struct {
+	// State of the suggestions
+	// in:query
+	// required:false
+	// default:pending
+	// enum: pending,accepted,dismissed
+	State string `json:"state"`
+}
+
+// swagger:response getCorrelationSuggestionsResponse
+type GetCorrelationSuggestionsResponse struct {
+	// in: body
+	Body []CorrelationSuggestion `json:"body"`
+}
+
+// swagger:route POST /datasources/correlations/suggestions/analyze correlations analyzeCorrelationQueries
+//
+// Analyzes the query history of the organization now instead of waiting for the next analysis.
+//
+// Responses:
+// 200: analyzeCorrelationQueriesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *CorrelationsService) analyzeHandler(c *contextmodel.ReqContext) response.Response {
+	saved, err := s.AnalyzeQueries(c.Req.Context(), c.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to analyze queries", err)
+	}
+
+	return response.JSON(http.StatusOK, AnalyzeResponseBody{Suggestions: saved, Message: "Queries analyzed"})
+}
+
+// swagger:response analyzeCorrelationQueriesResponse
+type AnalyzeCorrelationQueriesResponse struct {
+	// in: body
+	Body AnalyzeResponseBody `json:"body"`
+}
+
+// swagger:route POST /datasources/correlations/suggestions/{suggestionUID}/accept correlations acceptCorrelationSuggestion
+//
+// Creates the correlation of a suggestion.
+//
+// Responses:
+// 200: createCorrelationResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *CorrelationsService) acceptSuggestionHandler(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":suggestionUID"]
+	if resp := s.authorizeSuggestion(c, uid); resp != nil {
+		return resp
+	}
+
+	correlation, err := s.AcceptSuggestion(c.Req.Context(), c.GetOrgID(), uid)
+	if err != nil {
+		if errors.Is(err, ErrSuggestionNotPending) {
+			return response.Error(http.StatusBadRequest, "Suggestion is not pending", err)
+		}
+		if errors.Is(err, ErrSourceDataSourceDoesNotExists) || errors.Is(err, ErrTargetDataSourceDoesNotExists) {
+			return response.Error(http.StatusNotFound, "Data source not found", err)
+		}
+		if errors.Is(err, ErrCorrelationsQuotaReached) {
+			return response.Error(http.StatusForbidden, "Correlations quota reached", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to accept suggestion", err)
+	}
+
+	return response.JSON(http.StatusOK, CreateCorrelationResponseBody{Result: correlation, Message: "Correlation created"})
+}
+
+// swagger:route POST /datasources/correlations/suggestions/{suggestionUID}/dismiss correlations dismissCorrelationSuggestion
+//
+// Dismisses a suggestion, it won't be suggested again.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *CorrelationsService) dismissSuggestionHandler(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":suggestionUID"]
+	if resp := s.authorizeSuggestion(c, uid); resp != nil {
+		return resp
+	}
+
+	if err := s.DismissSuggestion(c.Req.Context(), c.GetOrgID(), uid); err != nil {
+		if errors.Is(err, ErrSuggestionNotPending) {
+			return response.Error(http.StatusBadRequest, "Suggestion is not pending", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to dismiss suggestion", err)
+	}
+
+	return response.Success("Suggestion dismissed")
+}
+
+// authorizeSuggestion checks the user can write the source data source of the suggestion, as creating its
+// correlation requires
+func (s *CorrelationsService) authorizeSuggestion(c *contextmodel.ReqContext, uid string) response.Response {
+	suggestion, err := s.GetSuggestion(c.Req.Context(), c.GetOrgID(), uid)
+	if err != nil {
+		if errors.Is(err, ErrSuggestionNotFound) {
+			return response.Error(http.StatusNotFound, "Suggestion not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get suggestion", err)
+	}
+
+	evaluator := ac.EvalPermission(datasources.ActionWrite, datasources.ScopeProvider.GetResourceScopeUID(suggestion.SourceUID))
+	allowed, err := s.AccessControl.Evaluate(c.Req.Context(), c.SignedInUser, evaluator)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+	}
+	if !allowed {
+		return response.Error(http.StatusForbidden, "Permission denied", nil)
+	}
+	return nil
+}
+
+// swagger:parameters acceptCorrelationSuggestion dismissCorrelationSuggestion
+type CorrelationSuggestionParams struct {
+	// in:path
+	// required:true
+	SuggestionUID string `json:"suggestionUID"`
+}
+
+// swagger:route GET /datasources/correlations/transformation-chains correlations getTransformationChains
+//
+// Gets the transformation chains of the organization.
+//
+// Responses:
+// 200: getTransformationChainsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *CorrelationsService) getTransformationChainsHandler(c *contextmodel.ReqContext) response.Response {
+	chains, err := s.GetTransformationChains(c.Req.Context(), c.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get transformation chains", err)
+	}
+
+	return response.JSON(http.StatusOK, chains)
+}
+
+// swagger:response getTransformationChainsResponse
+type GetTransformationChainsResponse struct {
+	// in: body
+	Body []TransformationChain `json:"body"`
+}
+
+// swagger:route POST /datasources/correlations/transformation-chains correlations createTransformationChain
+//
+// Add a transformation chain.
+//
+// Responses:
+// 200: transformationChainResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *CorrelationsService) createTransformationChainHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := CreateTransformationChainCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgId = c.GetOrgID()
+
+	chain, err := s.CreateTransformationChain(c.Req.Context(), cmd)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to add transformation chain", err)
+	}
+
+	return response.JSON(http.StatusOK, chain)
+}
+
+// swagger:parameters createTransformationChain
+type CreateTransformationChainParams struct {
+	// in:body
+	// required:true
+	Body CreateTransformationChainCommand `json:"body"`
+}
+
+// swagger:response transformationChainResponse
+type TransformationChainResponse struct {
+	// in: body
+	Body TransformationChain `json:"body"`
+}
+
+// swagger:route GET /datasources/correlations/transformation-chains/{chainUID} correlations getTransformationChain
+//
+// Gets a transformation chain.
+//
+// Responses:
+// 200: transformationChainResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *CorrelationsService) getTransformationChainHandler(c *contextmodel.ReqContext) response.Response {
+	chain, err := s.GetTransformationChain(c.Req.Context(), c.GetOrgID(), web.Params(c.Req)[":chainUID"])
+	if err != nil {
+		if errors.Is(err, ErrTransformationChainNotFound) {
+			return response.Error(http.StatusNotFound, "Transformation chain not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get transformation chain", err)
+	}
+
+	return response.JSON(http.StatusOK, chain)
+}
+
+// swagger:route PUT /datasources/correlations/transformation-chains/{chainUID} correlations updateTransformationChain
+//
+// Updates a transformation chain and the correlations using it.
+//
+// Responses:
+// 200: transformationChainResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *CorrelationsService) updateTransformationChainHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := CreateTransformationChainCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.UID = web.Params(c.Req)[":chainUID"]
+	cmd.OrgId = c.GetOrgID()
+
+	chain, err := s.UpdateTransformationChain(c.Req.Context(), cmd)
+	if err != nil {
+		if errors.Is(err, ErrTransformationChainNotFound) {
+			return response.Error(http.StatusNotFound, "Transformation chain not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to update transformation chain", err)
+	}
+
+	return response.JSON(http.StatusOK, chain)
+}
+
+// swagger:parameters updateTransformationChain
+type UpdateTransformationChainParams struct {
+	// in:path
+	// required:true
+	ChainUID string `json:"chainUID"`
+	// in:body
+	// required:true
+	Body CreateTransformationChainCommand `json:"body"`
+}
+
+// swagger:route DELETE /datasources/correlations/transformation-chains/{chainUID} correlations deleteTransformationChain
+//
+// Deletes a transformation chain which isn't used by correlations.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *CorrelationsService) deleteTransformationChainHandler(c *contextmodel.ReqContext) response.Response {
+	err := s.DeleteTransformationChain(c.Req.Context(), c.GetOrgID(), web.Params(c.Req)[":chainUID"])
+	if err != nil {
+		if errors.Is(err, ErrTransformationChainNotFound) {
+			return response.Error(http.StatusNotFound, "Transformation chain not found", err)
+		}
+		if errors.Is(err, ErrTransformationChainInUse) {
+			return response.Error(http.StatusConflict, "Transformation chain is used by correlations", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to delete transformation chain", err)
+	}
+
+	return response.Success("Transformation chain deleted")
+}
+
+// swagger:parameters getTransformationChain deleteTransformationChain
+type TransformationChainParams struct {
+	// in:path
+	// required:true
+	ChainUID string `json:"chainUID"`
+}
+
+// swagger:route POST /datasources/correlations/transformations/test correlations testCorrelationTransformations
+//
+// Applies transformations to the values of a row and returns the variables they set.
+//
+// Responses:
+// 200: testCorrelationTransformationsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *CorrelationsService) testTransformationsHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := TestTransformationsCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgId = c.GetOrgID()
+
+	result, err := s.TestTransformations(c.Req.Context(), cmd)
+	if err != nil {
+		if errors.Is(err, ErrTransformationChainNotFound) {
+			return response.Error(http.StatusBadRequest, "Transformation chain not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to test transformations", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:parameters testCorrelationTransformations
+type TestCorrelationTransformationsParams struct {
+	// in:body
+	// required:true
+	Body TestTransformationsCommand `json:"body"`
+}
+
+// swagger:response testCorrelationTransformationsResponse
+type TestCorrelationTransformationsResponse struct {
+	// in: body
+	Body TestTransformationsResponseBody `json:"body"`
+}
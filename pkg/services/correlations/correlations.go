@@ -8,6 +8,7 @@ import (
 	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/quota"
@@ -19,6 +20,7 @@ var (
 )
 
 func ProvideService(sqlStore db.DB, routeRegister routing.RouteRegister, ds datasources.DataSourceService, ac accesscontrol.AccessControl, bus bus.Bus, qs quota.Service, cfg *setting.Cfg,
+	serverLock *serverlock.ServerLockService,
 ) (*CorrelationsService, error) {
 	s := &CorrelationsService{
 		SQLStore:          sqlStore,
@@ -27,6 +29,8 @@ func ProvideService(sqlStore db.DB, routeRegister routing.RouteRegister, ds data
 		DataSourceService: ds,
 		AccessControl:     ac,
 		QuotaService:      qs,
+		Cfg:               cfg,
+		ServerLock:        serverLock,
 	}
 
 	s.registerAPIEndpoints()
@@ -64,6 +68,8 @@ type CorrelationsService struct {
 	DataSourceService datasources.DataSourceService
 	AccessControl     accesscontrol.AccessControl
 	QuotaService      quota.Service
+	Cfg               *setting.Cfg
+	ServerLock        *serverlock.ServerLockService
 }
 
 func (s CorrelationsService) CreateCorrelation(ctx context.Context, cmd CreateCorrelationCommand) (Correlation, error) {
@@ -127,7 +133,7 @@ func (s CorrelationsService) handleDatasourceDeletion(ctx context.Context, event
 			return err
 		}
 
-		return nil
+		return s.deleteSuggestionsByDataSource(ctx, event.OrgID, event.UID)
 	})
 }
 
@@ -54,6 +54,10 @@ func (s CorrelationsService) createCorrelation(ctx context.Context, cmd CreateCo
 			}
 		}
 
+		if err := applyTransformationChain(session, cmd.OrgId, &correlation.Config); err != nil {
+			return err
+		}
+
 		_, err = session.Insert(correlation)
 		if err != nil {
 			return err
@@ -154,6 +158,12 @@ func (s CorrelationsService) updateCorrelation(ctx context.Context, cmd UpdateCo
 			if cmd.Config.Transformations != nil {
 				correlation.Config.Transformations = cmd.Config.Transformations
 			}
+			if cmd.Config.TransformationChain != nil {
+				correlation.Config.TransformationChain = *cmd.Config.TransformationChain
+			}
+			if err := applyTransformationChain(session, cmd.OrgId, &correlation.Config); err != nil {
+				return err
+			}
 		}
 
 		updateCount, err := session.Where("uid = ? AND source_uid = ?", correlation.UID, correlation.SourceUID).Limit(1).Update(correlation)
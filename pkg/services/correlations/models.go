@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
+	"slices"
 
 	"github.com/grafana/grafana/pkg/services/quota"
 )
@@ -21,6 +23,13 @@ var (
 	ErrCorrelationsQuotaFailed       = errors.New("error getting correlations quota")
 	ErrCorrelationsQuotaReached      = errors.New("correlations quota reached")
 	ErrInvalidConfigType             = errors.New("correlation contains non default value in config.type")
+	ErrTransformationInvalidRegex    = errors.New("invalid transformation expression")
+	ErrTransformationExtractNoGroup  = errors.New("extract transformations require a named capture group")
+	ErrTransformationChainNotFound   = errors.New("transformation chain not found")
+	ErrTransformationChainInUse      = errors.New("transformation chain is used by correlations")
+	ErrTransformationChainEmptyName  = errors.New("transformation chain name is required")
+	ErrSuggestionNotFound            = errors.New("correlation suggestion not found")
+	ErrSuggestionNotPending          = errors.New("correlation suggestion was already accepted or dismissed")
 )
 
 const (
@@ -38,7 +47,7 @@ const (
 )
 
 type Transformation struct {
-	//Enum: regex,logfmt
+	//Enum: regex,logfmt,extract
 	Type       string `json:"type"`
 	Expression string `json:"expression,omitempty"`
 	Field      string `json:"field,omitempty"`
@@ -54,10 +63,26 @@ func (t CorrelationType) Validate() error {
 
 func (t Transformations) Validate() error {
 	for _, v := range t {
-		if v.Type != "regex" && v.Type != "logfmt" {
+		switch v.Type {
+		case "logfmt":
+		case "regex":
+			if len(v.Expression) == 0 {
+				return fmt.Errorf("%s: \"%s\"", ErrTransformationRegexReqExp, t)
+			}
+			if _, err := regexp.Compile(v.Expression); err != nil {
+				return fmt.Errorf("%w: %s", ErrTransformationInvalidRegex, err)
+			}
+		case "extract":
+			// extract transformations set a variable per named capture group of the expression
+			re, err := regexp.Compile(v.Expression)
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrTransformationInvalidRegex, err)
+			}
+			if !slices.ContainsFunc(re.SubexpNames(), func(name string) bool { return name != "" }) {
+				return fmt.Errorf("%w: \"%s\"", ErrTransformationExtractNoGroup, v.Expression)
+			}
+		default:
 			return fmt.Errorf("%s: \"%s\"", ErrInvalidTransformationType, t)
-		} else if v.Type == "regex" && len(v.Expression) == 0 {
-			return fmt.Errorf("%s: \"%s\"", ErrTransformationRegexReqExp, t)
 		}
 	}
 	return nil
@@ -83,6 +108,10 @@ type CorrelationConfig struct {
 	// required:false
 	// example: [{"type":"logfmt"}]
 	Transformations Transformations `json:"transformations,omitempty"`
+	// UID of the transformation chain of the correlation. The transformations of the chain replace the source data
+	// transformations, and are kept in sync when the chain is updated.
+	// required:false
+	TransformationChain string `json:"transformationChain,omitempty"`
 }
 
 func (c CorrelationConfig) MarshalJSON() ([]byte, error) {
@@ -92,13 +121,15 @@ func (c CorrelationConfig) MarshalJSON() ([]byte, error) {
 		target = map[string]any{}
 	}
 	return json.Marshal(struct {
-		Field           string          `json:"field"`
-		Target          map[string]any  `json:"target"`
-		Transformations Transformations `json:"transformations,omitempty"`
+		Field               string          `json:"field"`
+		Target              map[string]any  `json:"target"`
+		Transformations     Transformations `json:"transformations,omitempty"`
+		TransformationChain string          `json:"transformationChain,omitempty"`
 	}{
-		Field:           c.Field,
-		Target:          target,
-		Transformations: transformations,
+		Field:               c.Field,
+		Target:              target,
+		Transformations:     transformations,
+		TransformationChain: c.TransformationChain,
 	})
 }
 
@@ -215,6 +246,8 @@ type CorrelationConfigUpdateDTO struct {
 	// Source data transformations
 	// example: [{"type": "logfmt"},{"type":"regex","expression":"(Superman|Batman)", "variable":"name"}]
 	Transformations []Transformation `json:"transformations"`
+	// UID of the transformation chain, an empty string removes the chain
+	TransformationChain *string `json:"transformationChain"`
 }
 
 // UpdateCorrelationCommand is the command for updating a correlation
@@ -238,10 +271,15 @@ type UpdateCorrelationCommand struct {
 }
 
 func (c UpdateCorrelationCommand) Validate() error {
-	if c.Label == nil && c.Description == nil && c.Type == nil && (c.Config == nil || (c.Config.Field == nil && c.Config.Target == nil)) {
+	if c.Label == nil && c.Description == nil && c.Type == nil && (c.Config == nil || (c.Config.Field == nil && c.Config.Target == nil && c.Config.TransformationChain == nil)) {
 		return ErrUpdateCorrelationEmptyParams
 	}
 
+	if c.Config != nil {
+		if err := Transformations(c.Config.Transformations).Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,303 @@
+package correlations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// maxAnalyzedQueries bounds the entries of the query history read for an organization
+const maxAnalyzedQueries = 10000
+
+// the kind of a suggestion, which tells what the queries of the data sources have in common
+// +enum
+type SuggestionKind string
+
+const (
+	// SuggestionSharedLabels links two data sources of the same signal whose queries select the same labels
+	SuggestionSharedLabels SuggestionKind = "shared_labels"
+	// SuggestionLogMetric links logs and metrics whose queries select the same labels
+	SuggestionLogMetric SuggestionKind = "log_metric"
+	// SuggestionTraceID links logs using trace or span IDs to a tracing data source
+	SuggestionTraceID SuggestionKind = "trace_id"
+)
+
+// +enum
+type SuggestionState string
+
+const (
+	SuggestionPending   SuggestionState = "pending"
+	SuggestionAccepted  SuggestionState = "accepted"
+	SuggestionDismissed SuggestionState = "dismissed"
+)
+
+// CorrelationSuggestion is a correlation the analyzer of the query history suggests
+// swagger:model
+type CorrelationSuggestion struct {
+	ID int64 `json:"-" xorm:"pk autoincr 'id'"`
+	// Unique identifier of the suggestion
+	// example: 2Zm7xPqGz
+	UID   string `json:"uid" xorm:"uid"`
+	OrgID int64  `json:"orgId" xorm:"org_id"`
+	// UID of the data source the suggested correlation originates from
+	SourceUID string `json:"sourceUID" xorm:"source_uid"`
+	// UID of the data source the suggested correlation points to
+	TargetUID   string         `json:"targetUID" xorm:"target_uid"`
+	Kind        SuggestionKind `json:"kind"`
+	Label       string         `json:"label"`
+	Description string         `json:"description"`
+	// Configuration of the suggested correlation
+	Config CorrelationConfig `json:"config" xorm:"jsonb config"`
+	// Confidence of the suggestion, between 0 and 1
+	Score float64 `json:"score"`
+	// What the analyzer found in the queries
+	Evidence []string        `json:"evidence" xorm:"jsonb evidence"`
+	State    SuggestionState `json:"state"`
+	// UID of the correlation created when the suggestion was accepted
+	CorrelationUID string    `json:"correlationUID,omitempty" xorm:"correlation_uid"`
+	Created        time.Time `json:"created"`
+	Updated        time.Time `json:"updated"`
+}
+
+func (CorrelationSuggestion) TableName() string {
+	return "correlation_suggestion"
+}
+
+// GetSuggestionsQuery is the query to retrieve the suggestions of an organization
+type GetSuggestionsQuery struct {
+	OrgId int64
+	// pending by default
+	State SuggestionState
+}
+
+// swagger:model
+type AnalyzeResponseBody struct {
+	// Number of suggestions created or updated by the analysis
+	Suggestions int `json:"suggestions"`
+	// example: Queries analyzed
+	Message string `json:"message"`
+}
+
+// Run analyzes the query history of the organizations at every interval
+func (s *CorrelationsService) Run(ctx context.Context) error {
+	if !s.Cfg.Correlations.SuggestionsEnabled {
+		return nil
+	}
+
+	ticker := time.NewTicker(s.Cfg.Correlations.AnalysisInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		// the lock interval is shorter than the analysis interval to leave room for clock drift between instances
+		err := s.ServerLock.LockAndExecute(ctx, "correlation suggestions", s.Cfg.Correlations.AnalysisInterval/2, func(ctx context.Context) {
+			orgIDs, err := s.getAnalyzedOrgs(ctx)
+			if err != nil {
+				s.log.Error("Failed to list the organizations with queries", "error", err)
+				return
+			}
+			for _, orgID := range orgIDs {
+				if _, err := s.AnalyzeQueries(ctx, orgID); err != nil {
+					s.log.Error("Failed to analyze queries", "orgId", orgID, "error", err)
+				}
+			}
+		})
+		if err != nil {
+			s.log.Error("Failed to acquire lock for the analysis of queries", "error", err)
+		}
+	}
+}
+
+// AnalyzeQueries suggests correlations from the recent queries of the query history of the organization. The
+// pending suggestions are updated, the accepted and dismissed ones are left alone.
+func (s CorrelationsService) AnalyzeQueries(ctx context.Context, orgID int64) (int, error) {
+	dataSources, err := s.DataSourceService.GetDataSources(ctx, &datasources.GetDataSourcesQuery{OrgID: orgID})
+	if err != nil {
+		return 0, err
+	}
+	profiles := newProfiles(dataSources)
+	if len(profiles) < 2 {
+		return 0, nil
+	}
+
+	since := time.Now().Add(-s.Cfg.Correlations.AnalysisLookback).Unix()
+	var entries []struct {
+		DatasourceUID string `xorm:"datasource_uid"`
+		Queries       string `xorm:"queries"`
+	}
+	err = s.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+		return session.Table("query_history").Cols("datasource_uid", "queries").
+			Where("org_id = ? AND created_at >= ?", orgID, since).
+			OrderBy("created_at DESC").Limit(maxAnalyzedQueries).Find(&entries)
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, entry := range entries {
+		addHistoryQueries(profiles, entry.DatasourceUID, entry.Queries)
+	}
+
+	suggestions := analyze(profiles, s.Cfg.Correlations.MinQueries)
+	saved := 0
+	err = s.SQLStore.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		var existing []Correlation
+		if err := session.Where("org_id = ?", orgID).Cols("uid", "source_uid", "target_uid").Find(&existing); err != nil {
+			return err
+		}
+		correlated := map[string]bool{}
+		for _, c := range existing {
+			if c.TargetUID != nil {
+				correlated[c.SourceUID+"/"+*c.TargetUID] = true
+			}
+		}
+
+		now := time.Now()
+		for _, suggestion := range suggestions {
+			if correlated[suggestion.SourceUID+"/"+suggestion.TargetUID] {
+				continue
+			}
+
+			var stored CorrelationSuggestion
+			found, err := session.Where("org_id = ? AND source_uid = ? AND target_uid = ? AND kind = ?",
+				orgID, suggestion.SourceUID, suggestion.TargetUID, suggestion.Kind).Get(&stored)
+			if err != nil {
+				return err
+			}
+
+			if !found {
+				suggestion.UID = util.GenerateShortUID()
+				suggestion.OrgID = orgID
+				suggestion.State = SuggestionPending
+				suggestion.Created, suggestion.Updated = now, now
+				if _, err := session.Insert(&suggestion); err != nil {
+					return err
+				}
+				saved++
+				continue
+			}
+			if stored.State != SuggestionPending {
+				continue
+			}
+
+			stored.Label, stored.Description = suggestion.Label, suggestion.Description
+			stored.Config, stored.Score, stored.Evidence = suggestion.Config, suggestion.Score, suggestion.Evidence
+			stored.Updated = now
+			if _, err := session.ID(stored.ID).Cols("label", "description", "config", "score", "evidence", "updated").Update(&stored); err != nil {
+				return err
+			}
+			saved++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.log.Debug("Analyzed queries", "orgId", orgID, "queries", len(entries), "suggestions", saved)
+	return saved, nil
+}
+
+func (s CorrelationsService) GetSuggestions(ctx context.Context, query GetSuggestionsQuery) ([]CorrelationSuggestion, error) {
+	state := query.State
+	if state == "" {
+		state = SuggestionPending
+	}
+
+	suggestions := make([]CorrelationSuggestion, 0)
+	err := s.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+		return session.Where("org_id = ? AND state = ?", query.OrgId, state).OrderBy("score DESC, id ASC").Find(&suggestions)
+	})
+	return suggestions, err
+}
+
+func (s CorrelationsService) GetSuggestion(ctx context.Context, orgID int64, uid string) (CorrelationSuggestion, error) {
+	var suggestion CorrelationSuggestion
+	err := s.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+		found, err := session.Where("org_id = ? AND uid = ?", orgID, uid).Get(&suggestion)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return ErrSuggestionNotFound
+		}
+		return nil
+	})
+	return suggestion, err
+}
+
+// AcceptSuggestion creates the correlation of a pending suggestion
+func (s CorrelationsService) AcceptSuggestion(ctx context.Context, orgID int64, uid string) (Correlation, error) {
+	suggestion, err := s.GetSuggestion(ctx, orgID, uid)
+	if err != nil {
+		return Correlation{}, err
+	}
+	if suggestion.State != SuggestionPending {
+		return Correlation{}, ErrSuggestionNotPending
+	}
+
+	targetUID := suggestion.TargetUID
+	correlation, err := s.CreateCorrelation(ctx, CreateCorrelationCommand{
+		SourceUID:   suggestion.SourceUID,
+		OrgId:       orgID,
+		TargetUID:   &targetUID,
+		Label:       suggestion.Label,
+		Description: suggestion.Description,
+		Config:      suggestion.Config,
+		Type:        query,
+	})
+	if err != nil {
+		return Correlation{}, err
+	}
+
+	suggestion.CorrelationUID = correlation.UID
+	if err := s.setSuggestionState(ctx, &suggestion, SuggestionAccepted); err != nil {
+		return Correlation{}, fmt.Errorf("correlation %s was created but the suggestion wasn't updated: %w", correlation.UID, err)
+	}
+	return correlation, nil
+}
+
+// DismissSuggestion dismisses a pending suggestion, the analyzer doesn't suggest it again
+func (s CorrelationsService) DismissSuggestion(ctx context.Context, orgID int64, uid string) error {
+	suggestion, err := s.GetSuggestion(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+	if suggestion.State != SuggestionPending {
+		return ErrSuggestionNotPending
+	}
+	return s.setSuggestionState(ctx, &suggestion, SuggestionDismissed)
+}
+
+func (s CorrelationsService) setSuggestionState(ctx context.Context, suggestion *CorrelationSuggestion, state SuggestionState) error {
+	suggestion.State = state
+	suggestion.Updated = time.Now()
+	return s.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+		_, err := session.ID(suggestion.ID).Cols("state", "correlation_uid", "updated").Update(suggestion)
+		return err
+	})
+}
+
+func (s CorrelationsService) getAnalyzedOrgs(ctx context.Context) ([]int64, error) {
+	since := time.Now().Add(-s.Cfg.Correlations.AnalysisLookback).Unix()
+	var orgIDs []int64
+	err := s.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+		return session.SQL("SELECT DISTINCT org_id FROM query_history WHERE created_at >= ?", since).Find(&orgIDs)
+	})
+	return orgIDs, err
+}
+
+func (s CorrelationsService) deleteSuggestionsByDataSource(ctx context.Context, orgID int64, uid string) error {
+	return s.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+		_, err := session.Where("org_id = ? AND (source_uid = ? OR target_uid = ?)", orgID, uid, uid).Delete(&CorrelationSuggestion{})
+		return err
+	})
+}
@@ -0,0 +1,284 @@
+package correlations
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-logfmt/logfmt"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// TransformationChain is a named list of transformations shared by correlations. The correlations using a chain
+// are updated with the chain.
+// swagger:model
+type TransformationChain struct {
+	ID int64 `json:"-" xorm:"pk autoincr 'id'"`
+	// Unique identifier of the chain
+	// example: 7hbRvNpGz
+	UID   string `json:"uid" xorm:"uid"`
+	OrgID int64  `json:"orgId" xorm:"org_id"`
+	// example: Trace ID from log lines
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// example: [{"type":"extract","expression":"traceID=(?P<traceId>\\w+)"}]
+	Transformations Transformations `json:"transformations" xorm:"jsonb transformations"`
+	Created         time.Time       `json:"created"`
+	Updated         time.Time       `json:"updated"`
+}
+
+func (TransformationChain) TableName() string {
+	return "correlation_transformation_chain"
+}
+
+// CreateTransformationChainCommand is the command for creating or updating a transformation chain
+// swagger:model
+type CreateTransformationChainCommand struct {
+	UID   string `json:"-"`
+	OrgId int64  `json:"-"`
+	// required:true
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// required:true
+	Transformations Transformations `json:"transformations"`
+}
+
+func (c CreateTransformationChainCommand) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return ErrTransformationChainEmptyName
+	}
+	return c.Transformations.Validate()
+}
+
+// TestTransformationsCommand applies transformations to the values of a row, as the links of a correlation do
+// swagger:model
+type TestTransformationsCommand struct {
+	OrgId int64 `json:"-"`
+	// Field of the correlation
+	// example: message
+	Field string `json:"field"`
+	// Values of the fields of the row
+	// example: {"message":"level=error traceID=4bf92f3577b34da6"}
+	Values map[string]string `json:"values"`
+	// Transformations to apply, ignored when transformationChain is set
+	Transformations Transformations `json:"transformations"`
+	// UID of a transformation chain to apply
+	TransformationChain string `json:"transformationChain"`
+}
+
+func (c TestTransformationsCommand) Validate() error {
+	return c.Transformations.Validate()
+}
+
+// swagger:model
+type TestTransformationsResponseBody struct {
+	// Variables set by the transformations
+	// example: {"traceID":"4bf92f3577b34da6"}
+	Variables map[string]string `json:"variables"`
+}
+
+// Apply returns the variables the transformations set from the values of a row. The transformations read the value
+// of their field, or of the field of the correlation.
+func (t Transformations) Apply(field string, values map[string]string) map[string]string {
+	vars := map[string]string{}
+	for _, transformation := range t {
+		name := field
+		if transformation.Field != "" {
+			name = transformation.Field
+		}
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+
+		switch transformation.Type {
+		case "logfmt":
+			decoder := logfmt.NewDecoder(strings.NewReader(value))
+			for decoder.ScanRecord() {
+				for decoder.ScanKeyval() {
+					vars[string(decoder.Key())] = string(decoder.Value())
+				}
+			}
+		case "regex":
+			re, err := regexp.Compile(transformation.Expression)
+			if err != nil {
+				continue
+			}
+			if match := re.FindStringSubmatch(value); len(match) > 1 && match[1] != "" {
+				variable := name
+				if transformation.MapValue != "" {
+					variable = transformation.MapValue
+				}
+				vars[variable] = match[1]
+			}
+		case "extract":
+			re, err := regexp.Compile(transformation.Expression)
+			if err != nil {
+				continue
+			}
+			match := re.FindStringSubmatch(value)
+			for i, group := range re.SubexpNames() {
+				if group != "" && i < len(match) && match[i] != "" {
+					vars[group] = match[i]
+				}
+			}
+		}
+	}
+	return vars
+}
+
+func (s CorrelationsService) CreateTransformationChain(ctx context.Context, cmd CreateTransformationChainCommand) (TransformationChain, error) {
+	now := time.Now()
+	chain := TransformationChain{
+		UID:             util.GenerateShortUID(),
+		OrgID:           cmd.OrgId,
+		Name:            cmd.Name,
+		Description:     cmd.Description,
+		Transformations: cmd.Transformations,
+		Created:         now,
+		Updated:         now,
+	}
+
+	err := s.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+		_, err := session.Insert(&chain)
+		return err
+	})
+	return chain, err
+}
+
+func (s CorrelationsService) GetTransformationChains(ctx context.Context, orgID int64) ([]TransformationChain, error) {
+	chains := make([]TransformationChain, 0)
+	err := s.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+		return session.Where("org_id = ?", orgID).OrderBy("name ASC").Find(&chains)
+	})
+	return chains, err
+}
+
+func (s CorrelationsService) GetTransformationChain(ctx context.Context, orgID int64, uid string) (TransformationChain, error) {
+	var chain TransformationChain
+	err := s.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+		var err error
+		chain, err = getTransformationChain(session, orgID, uid)
+		return err
+	})
+	return chain, err
+}
+
+// UpdateTransformationChain updates the chain and the transformations of the correlations using it
+func (s CorrelationsService) UpdateTransformationChain(ctx context.Context, cmd CreateTransformationChainCommand) (TransformationChain, error) {
+	var chain TransformationChain
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		var err error
+		chain, err = getTransformationChain(session, cmd.OrgId, cmd.UID)
+		if err != nil {
+			return err
+		}
+
+		chain.Name = cmd.Name
+		chain.Description = cmd.Description
+		chain.Transformations = cmd.Transformations
+		chain.Updated = time.Now()
+		if _, err := session.ID(chain.ID).MustCols("description").Update(&chain); err != nil {
+			return err
+		}
+
+		correlations, err := findCorrelationsUsingChain(session, chain.OrgID, chain.UID)
+		if err != nil {
+			return err
+		}
+		for _, correlation := range correlations {
+			correlation.Config.Transformations = chain.Transformations
+			if _, err := session.Where("uid = ? AND source_uid = ? AND org_id = ?", correlation.UID, correlation.SourceUID, correlation.OrgID).
+				Cols("config").Update(&correlation); err != nil {
+				return err
+			}
+		}
+		if len(correlations) > 0 {
+			s.log.Debug("Updated the correlations of a transformation chain", "chain", chain.UID, "correlations", len(correlations))
+		}
+		return nil
+	})
+	if err != nil {
+		return TransformationChain{}, err
+	}
+	return chain, nil
+}
+
+// DeleteTransformationChain deletes a chain which isn't used by correlations
+func (s CorrelationsService) DeleteTransformationChain(ctx context.Context, orgID int64, uid string) error {
+	return s.SQLStore.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		chain, err := getTransformationChain(session, orgID, uid)
+		if err != nil {
+			return err
+		}
+
+		correlations, err := findCorrelationsUsingChain(session, orgID, uid)
+		if err != nil {
+			return err
+		}
+		if len(correlations) > 0 {
+			return fmt.Errorf("%w: %d correlations", ErrTransformationChainInUse, len(correlations))
+		}
+
+		_, err = session.ID(chain.ID).Delete(&TransformationChain{})
+		return err
+	})
+}
+
+// TestTransformations applies the transformations, or the transformation chain, of the command to its values
+func (s CorrelationsService) TestTransformations(ctx context.Context, cmd TestTransformationsCommand) (TestTransformationsResponseBody, error) {
+	transformations := cmd.Transformations
+	if cmd.TransformationChain != "" {
+		chain, err := s.GetTransformationChain(ctx, cmd.OrgId, cmd.TransformationChain)
+		if err != nil {
+			return TestTransformationsResponseBody{}, err
+		}
+		transformations = chain.Transformations
+	}
+	return TestTransformationsResponseBody{Variables: transformations.Apply(cmd.Field, cmd.Values)}, nil
+}
+
+// applyTransformationChain replaces the transformations of the config with the ones of its chain
+func applyTransformationChain(session *db.Session, orgID int64, config *CorrelationConfig) error {
+	if config.TransformationChain == "" {
+		return nil
+	}
+	chain, err := getTransformationChain(session, orgID, config.TransformationChain)
+	if err != nil {
+		return err
+	}
+	config.Transformations = chain.Transformations
+	return nil
+}
+
+func getTransformationChain(session *db.Session, orgID int64, uid string) (TransformationChain, error) {
+	var chain TransformationChain
+	found, err := session.Where("org_id = ? AND uid = ?", orgID, uid).Get(&chain)
+	if err != nil {
+		return TransformationChain{}, err
+	}
+	if !found {
+		return TransformationChain{}, ErrTransformationChainNotFound
+	}
+	return chain, nil
+}
+
+func findCorrelationsUsingChain(session *db.Session, orgID int64, uid string) ([]Correlation, error) {
+	var candidates []Correlation
+	// the config is stored as text, the candidates are narrowed down before the configs are decoded
+	if err := session.Where("org_id = ? AND config LIKE ?", orgID, "%"+uid+"%").Find(&candidates); err != nil {
+		return nil, err
+	}
+
+	correlations := make([]Correlation, 0, len(candidates))
+	for _, c := range candidates {
+		if c.Config.TransformationChain == uid {
+			correlations = append(correlations, c)
+		}
+	}
+	return correlations, nil
+}
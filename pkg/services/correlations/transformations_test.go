@@ -0,0 +1,46 @@
+package correlations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransformations(t *testing.T) {
+	t.Run("Validate", func(t *testing.T) {
+		require.NoError(t, Transformations{
+			{Type: "logfmt"},
+			{Type: "regex", Expression: `traceID=(\w+)`},
+			{Type: "extract", Expression: `traceID=(?P<traceId>\w+)`},
+		}.Validate())
+
+		err := Transformations{{Type: "regex", Expression: `traceID=(\w+`}}.Validate()
+		require.ErrorIs(t, err, ErrTransformationInvalidRegex)
+
+		err = Transformations{{Type: "extract", Expression: `traceID=(\w+)`}}.Validate()
+		require.ErrorIs(t, err, ErrTransformationExtractNoGroup)
+	})
+
+	t.Run("Apply", func(t *testing.T) {
+		values := map[string]string{
+			"message": "level=error traceID=4bf92f3577b34da6 duration=12ms",
+			"path":    "/api/users/42/orders",
+		}
+
+		vars := Transformations{{Type: "logfmt"}}.Apply("message", values)
+		assert.Equal(t, map[string]string{"level": "error", "traceID": "4bf92f3577b34da6", "duration": "12ms"}, vars)
+
+		vars = Transformations{
+			{Type: "regex", Expression: `duration=(\d+)ms`},
+			{Type: "regex", Field: "path", Expression: `/users/(\d+)`, MapValue: "userId"},
+		}.Apply("message", values)
+		assert.Equal(t, map[string]string{"message": "12", "userId": "42"}, vars)
+
+		vars = Transformations{
+			{Type: "extract", Field: "path", Expression: `/api/(?P<resource>\w+)/(?P<id>\d+)`},
+			{Type: "extract", Field: "missing", Expression: `(?P<other>.+)`},
+		}.Apply("message", values)
+		assert.Equal(t, map[string]string{"resource": "users", "id": "42"}, vars)
+	})
+}
@@ -43,6 +43,11 @@ func (api *ImportDashboardAPI) RegisterAPIEndpoints(routeRegister routing.RouteR
 			authorize(accesscontrol.EvalPermission(dashboards.ActionDashboardsCreate)),
 			routing.Wrap(api.ImportDashboard),
 		)
+		route.Post(
+			"/import/plan",
+			authorize(accesscontrol.EvalPermission(dashboards.ActionDashboardsCreate)),
+			routing.Wrap(api.PlanImportDashboard),
+		)
 	}, middleware.ReqSignedIn)
 }
 
@@ -88,6 +93,39 @@ func (api *ImportDashboardAPI) ImportDashboard(c *contextmodel.ReqContext) respo
 	return response.JSON(http.StatusOK, resp)
 }
 
+// swagger:route POST /dashboards/import/plan dashboards planImportDashboard
+//
+// Plan the import of a dashboard.
+//
+// Lists the datasources, variables and library panels the dashboard references, resolves them with the mapping and
+// the resources of the organization, and returns the dashboard with the resolved references remapped.
+//
+// Responses:
+// 200: planImportDashboardResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 422: unprocessableEntityError
+// 500: internalServerError
+func (api *ImportDashboardAPI) PlanImportDashboard(c *contextmodel.ReqContext) response.Response {
+	req := dashboardimport.ImportDashboardPlanRequest{}
+	if err := web.Bind(c.Req, &req); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if req.Dashboard == nil {
+		return response.Error(http.StatusUnprocessableEntity, "Dashboard must be set", nil)
+	}
+
+	req.User = c.SignedInUser
+	resp, err := api.dashboardImportService.PlanImport(c.Req.Context(), &req)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to plan dashboard import", err)
+	}
+
+	return response.JSON(http.StatusOK, resp)
+}
+
 type QuotaService interface {
 	QuotaReached(c *contextmodel.ReqContext, target quota.TargetSrv) (bool, error)
 }
@@ -110,3 +148,16 @@ type ImportDashboardResponse struct {
 	// in: body
 	Body dashboardimport.ImportDashboardResponse `json:"body"`
 }
+
+// swagger:parameters planImportDashboard
+type PlanImportDashboardParams struct {
+	// in:body
+	// required:true
+	Body dashboardimport.ImportDashboardPlanRequest
+}
+
+// swagger:response planImportDashboardResponse
+type PlanImportDashboardResponse struct {
+	// in: body
+	Body dashboardimport.ImportDashboardPlanResponse `json:"body"`
+}
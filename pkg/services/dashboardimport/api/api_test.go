@@ -161,6 +161,7 @@ func TestImportDashboardAPI(t *testing.T) {
 
 type serviceMock struct {
 	importDashboardFunc func(ctx context.Context, req *dashboardimport.ImportDashboardRequest) (*dashboardimport.ImportDashboardResponse, error)
+	planImportFunc      func(ctx context.Context, req *dashboardimport.ImportDashboardPlanRequest) (*dashboardimport.ImportDashboardPlanResponse, error)
 }
 
 func (s *serviceMock) ImportDashboard(ctx context.Context, req *dashboardimport.ImportDashboardRequest) (*dashboardimport.ImportDashboardResponse, error) {
@@ -171,6 +172,14 @@ func (s *serviceMock) ImportDashboard(ctx context.Context, req *dashboardimport.
 	return nil, nil
 }
 
+func (s *serviceMock) PlanImport(ctx context.Context, req *dashboardimport.ImportDashboardPlanRequest) (*dashboardimport.ImportDashboardPlanResponse, error) {
+	if s.planImportFunc != nil {
+		return s.planImportFunc(ctx, req)
+	}
+
+	return nil, nil
+}
+
 func quotaReached(c *contextmodel.ReqContext, target quota.TargetSrv) (bool, error) {
 	return true, nil
 }
@@ -49,7 +49,109 @@ type ImportDashboardResponse struct {
 	Removed          bool   `json:"removed"`
 }
 
+// ImportDashboardMapping maps the references of a dashboard to the resources of the org it's imported in.
+type ImportDashboardMapping struct {
+	// Datasources maps the datasource references, by UID, name or input name, to the UIDs of the datasources of the org.
+	Datasources map[string]string `json:"datasources,omitempty"`
+	// Variables sets the values of the constant and textbox variables and of the constant inputs, by name.
+	Variables map[string]string `json:"variables,omitempty"`
+	// LibraryPanels maps the library panel UIDs to the UIDs of the library panels of the org.
+	LibraryPanels map[string]string `json:"libraryPanels,omitempty"`
+}
+
+// ImportDashboardPlanRequest request object for planning the import of a dashboard.
+type ImportDashboardPlanRequest struct {
+	Dashboard *simplejson.Json       `json:"dashboard"`
+	Mapping   ImportDashboardMapping `json:"mapping"`
+
+	User identity.Requester `json:"-"`
+}
+
+// MappingStatus is the status of a reference of the imported dashboard.
+type MappingStatus string
+
+const (
+	// MappingStatusMapped the reference is mapped by the mapping document
+	MappingStatusMapped MappingStatus = "mapped"
+	// MappingStatusMatched the reference was matched to a resource of the org
+	MappingStatusMatched MappingStatus = "matched"
+	// MappingStatusIncluded the library panel is included in the dashboard and created by the import
+	MappingStatusIncluded MappingStatus = "included"
+	// MappingStatusDefault the variable keeps the value of the dashboard
+	MappingStatusDefault MappingStatus = "default"
+	// MappingStatusInvalid the mapping document maps the reference to a resource which doesn't exist
+	MappingStatusInvalid MappingStatus = "invalid"
+	// MappingStatusUnmapped the reference needs a mapping
+	MappingStatusUnmapped MappingStatus = "unmapped"
+)
+
+// Resolved checks if the reference can be imported.
+func (s MappingStatus) Resolved() bool {
+	return s != MappingStatusInvalid && s != MappingStatusUnmapped
+}
+
+// DatasourceReference a datasource referenced by the imported dashboard.
+type DatasourceReference struct {
+	// Key is the UID, the name or the input name of the datasource, used as the key of the mapping.
+	Key   string `json:"key"`
+	UID   string `json:"uid,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Type  string `json:"type,omitempty"`
+	Input string `json:"input,omitempty"`
+	// Paths are the paths of the references in the dashboard, such as panels[0].targets[1].datasource.
+	Paths  []string          `json:"paths"`
+	Status MappingStatus     `json:"status"`
+	Target *DatasourceTarget `json:"target,omitempty"`
+}
+
+// DatasourceTarget the datasource of the org a reference is mapped to.
+type DatasourceTarget struct {
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// VariableReference a variable or an input of the imported dashboard whose value depends on the instance.
+type VariableReference struct {
+	Name string `json:"name"`
+	// Type is the type of the variable, constant or textbox, or input for the constant inputs.
+	Type   string        `json:"type"`
+	Value  string        `json:"value"`
+	Status MappingStatus `json:"status"`
+	Target string        `json:"target,omitempty"`
+}
+
+// LibraryPanelReference a library panel used by the imported dashboard.
+type LibraryPanelReference struct {
+	UID   string   `json:"uid"`
+	Name  string   `json:"name"`
+	Paths []string `json:"paths"`
+	// Included is true when the model of the library panel is included in the dashboard.
+	Included bool                `json:"included"`
+	Status   MappingStatus       `json:"status"`
+	Target   *LibraryPanelTarget `json:"target,omitempty"`
+}
+
+// LibraryPanelTarget the library panel of the org a reference is mapped to.
+type LibraryPanelTarget struct {
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+}
+
+// ImportDashboardPlanResponse response object returned when planning the import of a dashboard.
+type ImportDashboardPlanResponse struct {
+	Datasources   []*DatasourceReference   `json:"datasources"`
+	Variables     []*VariableReference     `json:"variables"`
+	LibraryPanels []*LibraryPanelReference `json:"libraryPanels"`
+	// Complete is true when every reference is resolved, the dashboard can then be imported as is.
+	Complete bool `json:"complete"`
+	// Dashboard is the dashboard with the resolved references remapped.
+	Dashboard *simplejson.Json `json:"dashboard"`
+}
+
 // Service service interface for importing dashboards.
 type Service interface {
 	ImportDashboard(ctx context.Context, req *ImportDashboardRequest) (*ImportDashboardResponse, error)
+	// PlanImport lists the references of the dashboard to the resources of the org, and remaps them with the mapping.
+	PlanImport(ctx context.Context, req *ImportDashboardPlanRequest) (*ImportDashboardPlanResponse, error)
 }
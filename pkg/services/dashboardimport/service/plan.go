@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	"github.com/grafana/grafana/pkg/services/dashboardimport/utils"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+)
+
+// PlanImport lists the references of the dashboard and resolves them: with the mapping document first, then with the
+// resources of the org which have the same UID or name, then with the only datasource of the same type. The
+// dashboard is returned with the resolved references remapped.
+func (s *ImportDashboardService) PlanImport(ctx context.Context, req *dashboardimport.ImportDashboardPlanRequest) (*dashboardimport.ImportDashboardPlanResponse, error) {
+	refs := utils.FindDashboardReferences(req.Dashboard)
+
+	orgDatasources, err := s.queryableDatasources(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range refs.Datasources {
+		resolveDatasource(ref, req.Mapping.Datasources, orgDatasources)
+	}
+
+	for _, ref := range refs.LibraryPanels {
+		if err := s.resolveLibraryPanel(ctx, req, ref); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, ref := range refs.Variables {
+		ref.Status = dashboardimport.MappingStatusDefault
+		if value, ok := req.Mapping.Variables[ref.Name]; ok {
+			ref.Status = dashboardimport.MappingStatusMapped
+			ref.Target = value
+		} else if ref.Type == "input" && ref.Value == "" {
+			ref.Status = dashboardimport.MappingStatusUnmapped
+		}
+	}
+
+	remapped, err := utils.RemapDashboard(req.Dashboard, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dashboardimport.ImportDashboardPlanResponse{
+		Datasources:   refs.Datasources,
+		Variables:     refs.Variables,
+		LibraryPanels: refs.LibraryPanels,
+		Complete:      true,
+		Dashboard:     remapped,
+	}
+	for _, ref := range refs.Datasources {
+		resp.Complete = resp.Complete && ref.Status.Resolved()
+	}
+	for _, ref := range refs.Variables {
+		resp.Complete = resp.Complete && ref.Status.Resolved()
+	}
+	for _, ref := range refs.LibraryPanels {
+		resp.Complete = resp.Complete && ref.Status.Resolved()
+	}
+	return resp, nil
+}
+
+// queryableDatasources returns the datasources of the org the user can query, the only ones the dashboard can be
+// mapped to
+func (s *ImportDashboardService) queryableDatasources(ctx context.Context, req *dashboardimport.ImportDashboardPlanRequest) ([]*datasources.DataSource, error) {
+	all, err := s.dataSourceService.GetDataSources(ctx, &datasources.GetDataSourcesQuery{OrgID: req.User.GetOrgID()})
+	if err != nil {
+		return nil, err
+	}
+
+	queryable := make([]*datasources.DataSource, 0, len(all))
+	for _, ds := range all {
+		evaluator := accesscontrol.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(ds.UID))
+		ok, err := s.ac.Evaluate(ctx, req.User, evaluator)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			queryable = append(queryable, ds)
+		}
+	}
+	return queryable, nil
+}
+
+func resolveDatasource(ref *dashboardimport.DatasourceReference, mapping map[string]string, orgDatasources []*datasources.DataSource) {
+	if targetUID, ok := mapping[ref.Key]; ok {
+		ref.Status = dashboardimport.MappingStatusInvalid
+		for _, ds := range orgDatasources {
+			if ds.UID == targetUID {
+				ref.Status = dashboardimport.MappingStatusMapped
+				ref.Target = datasourceTarget(ds)
+				return
+			}
+		}
+		return
+	}
+
+	var sameType []*datasources.DataSource
+	for _, ds := range orgDatasources {
+		if ref.Input == "" && (ref.UID != "" && ds.UID == ref.UID || ref.UID == "" && ds.Name == ref.Name) {
+			ref.Status = dashboardimport.MappingStatusMatched
+			ref.Target = datasourceTarget(ds)
+			return
+		}
+		if ref.Type != "" && ds.Type == ref.Type {
+			sameType = append(sameType, ds)
+		}
+	}
+	if len(sameType) == 1 {
+		ref.Status = dashboardimport.MappingStatusMatched
+		ref.Target = datasourceTarget(sameType[0])
+		return
+	}
+	ref.Status = dashboardimport.MappingStatusUnmapped
+}
+
+func datasourceTarget(ds *datasources.DataSource) *dashboardimport.DatasourceTarget {
+	return &dashboardimport.DatasourceTarget{UID: ds.UID, Name: ds.Name, Type: ds.Type}
+}
+
+func (s *ImportDashboardService) resolveLibraryPanel(ctx context.Context, req *dashboardimport.ImportDashboardPlanRequest, ref *dashboardimport.LibraryPanelReference) error {
+	uid, mapped := req.Mapping.LibraryPanels[ref.UID]
+	if !mapped {
+		uid = ref.UID
+	}
+
+	element, err := s.libraryElementService.GetElement(ctx, req.User, model.GetLibraryElementCommand{UID: uid, FolderName: dashboards.RootFolderName})
+	switch {
+	case err == nil:
+		ref.Status = dashboardimport.MappingStatusMatched
+		if mapped {
+			ref.Status = dashboardimport.MappingStatusMapped
+		}
+		ref.Target = &dashboardimport.LibraryPanelTarget{UID: element.UID, Name: element.Name}
+	case !errors.Is(err, model.ErrLibraryElementNotFound):
+		return err
+	case mapped:
+		ref.Status = dashboardimport.MappingStatusInvalid
+	case ref.Included:
+		ref.Status = dashboardimport.MappingStatusIncluded
+	default:
+		ref.Status = dashboardimport.MappingStatusUnmapped
+	}
+	return nil
+}
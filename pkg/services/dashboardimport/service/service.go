@@ -12,8 +12,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboardimport/api"
 	"github.com/grafana/grafana/pkg/services/dashboardimport/utils"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/librarypanels"
 	"github.com/grafana/grafana/pkg/services/plugindashboards"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
@@ -25,6 +27,7 @@ func ProvideService(routeRegister routing.RouteRegister,
 	pluginDashboardService plugindashboards.Service, pluginStore pluginstore.Store,
 	libraryPanelService librarypanels.Service, dashboardService dashboards.DashboardService,
 	ac accesscontrol.AccessControl, folderService folder.Service, features featuremgmt.FeatureToggles,
+	dataSourceService datasources.DataSourceService, libraryElementService libraryelements.Service,
 ) *ImportDashboardService {
 	s := &ImportDashboardService{
 		pluginDashboardService: pluginDashboardService,
@@ -32,6 +35,9 @@ func ProvideService(routeRegister routing.RouteRegister,
 		libraryPanelService:    libraryPanelService,
 		folderService:          folderService,
 		features:               features,
+		dataSourceService:      dataSourceService,
+		libraryElementService:  libraryElementService,
+		ac:                     ac,
 	}
 
 	dashboardImportAPI := api.New(s, quotaService, pluginStore, ac)
@@ -46,6 +52,9 @@ type ImportDashboardService struct {
 	libraryPanelService    librarypanels.Service
 	folderService          folder.Service
 	features               featuremgmt.FeatureToggles
+	dataSourceService      datasources.DataSourceService
+	libraryElementService  libraryelements.Service
+	ac                     accesscontrol.AccessControl
 }
 
 func (s *ImportDashboardService) ImportDashboard(ctx context.Context, req *dashboardimport.ImportDashboardRequest) (*dashboardimport.ImportDashboardResponse, error) {
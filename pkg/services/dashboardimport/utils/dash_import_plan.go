@@ -0,0 +1,366 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+)
+
+var inputRefRegex = regexp.MustCompile(`^\$\{(.+)\}$`)
+
+// The datasource references which don't point to a datasource of the org
+var builtInDatasources = map[string]bool{
+	"-- Grafana --":       true,
+	"-- Mixed --":         true,
+	"-- Dashboard --":     true,
+	"grafana":             true,
+	expr.DatasourceUID:    true,
+	expr.OldDatasourceUID: true,
+	expr.MLDatasourceUID:  true,
+}
+
+// DashboardReferences the references of a dashboard to the resources of an org.
+type DashboardReferences struct {
+	Datasources   []*dashboardimport.DatasourceReference
+	Variables     []*dashboardimport.VariableReference
+	LibraryPanels []*dashboardimport.LibraryPanelReference
+}
+
+// FindDashboardReferences lists the datasources, the constant and textbox variables, the inputs and the library panels
+// referenced by the dashboard, in the order they appear in it.
+func FindDashboardReferences(dash *simplejson.Json) *DashboardReferences {
+	refs := &DashboardReferences{}
+	parser := newDatasourceRefParser(dash)
+	datasources := map[string]*dashboardimport.DatasourceReference{}
+	libraryPanels := map[string]*dashboardimport.LibraryPanelReference{}
+
+	for _, input := range dash.Get("__inputs").MustArray() {
+		inputJson := simplejson.NewFromAny(input)
+		name := inputJson.Get("name").MustString()
+		switch inputJson.Get("type").MustString() {
+		case "datasource":
+			if inputJson.Get("pluginId").MustString() == expr.DatasourceType {
+				continue
+			}
+			ref := &dashboardimport.DatasourceReference{
+				Key:   name,
+				Name:  inputJson.Get("label").MustString(),
+				Type:  inputJson.Get("pluginId").MustString(),
+				Input: name,
+				Paths: []string{},
+			}
+			datasources[name] = ref
+			refs.Datasources = append(refs.Datasources, ref)
+		case "constant":
+			refs.Variables = append(refs.Variables, &dashboardimport.VariableReference{
+				Name:  name,
+				Type:  "input",
+				Value: inputJson.Get("value").MustString(),
+			})
+		}
+	}
+
+	elements := dash.Get("__elements")
+	walkReferences(dash.MustMap(), referenceVisitor{
+		datasource: func(path string, value any) any {
+			parsed, ok := parser.parse(value)
+			if !ok {
+				return value
+			}
+			ref, ok := datasources[parsed.key()]
+			if !ok {
+				ref = &dashboardimport.DatasourceReference{
+					Key:  parsed.key(),
+					UID:  parsed.uid,
+					Name: parsed.name,
+					Type: parsed.typ,
+				}
+				datasources[parsed.key()] = ref
+				refs.Datasources = append(refs.Datasources, ref)
+			}
+			if ref.Type == "" {
+				ref.Type = parsed.typ
+			}
+			ref.Paths = append(ref.Paths, path)
+			return value
+		},
+		libraryPanel: func(path string, libraryPanel map[string]any) {
+			uid, _ := libraryPanel["uid"].(string)
+			if uid == "" {
+				return
+			}
+			ref, ok := libraryPanels[uid]
+			if !ok {
+				name, _ := libraryPanel["name"].(string)
+				ref = &dashboardimport.LibraryPanelReference{
+					UID:      uid,
+					Name:     name,
+					Included: libraryElementIncluded(elements, uid),
+				}
+				libraryPanels[uid] = ref
+				refs.LibraryPanels = append(refs.LibraryPanels, ref)
+			}
+			ref.Paths = append(ref.Paths, path)
+		},
+		variable: func(variable map[string]any) {
+			typ, _ := variable["type"].(string)
+			if typ != "constant" && typ != "textbox" {
+				return
+			}
+			name, _ := variable["name"].(string)
+			query, _ := variable["query"].(string)
+			refs.Variables = append(refs.Variables, &dashboardimport.VariableReference{
+				Name:  name,
+				Type:  typ,
+				Value: query,
+			})
+		},
+	})
+
+	return refs
+}
+
+// RemapDashboard returns a copy of the dashboard with the resolved references replaced with their targets and the
+// variables set to their mapped values. The inputs are evaluated once they are all resolved, the dashboard can then be
+// imported without inputs.
+func RemapDashboard(dash *simplejson.Json, refs *DashboardReferences) (*simplejson.Json, error) {
+	data, err := dash.Encode()
+	if err != nil {
+		return nil, err
+	}
+	remapped, err := simplejson.NewJson(data)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := newDatasourceRefParser(remapped)
+	datasources := map[string]*dashboardimport.DatasourceReference{}
+	for _, ref := range refs.Datasources {
+		datasources[ref.Key] = ref
+	}
+	libraryPanels := map[string]*dashboardimport.LibraryPanelReference{}
+	for _, ref := range refs.LibraryPanels {
+		libraryPanels[ref.UID] = ref
+	}
+	variables := map[string]*dashboardimport.VariableReference{}
+	for _, ref := range refs.Variables {
+		if ref.Type != "input" {
+			variables[ref.Name] = ref
+		}
+	}
+
+	walkReferences(remapped.MustMap(), referenceVisitor{
+		datasource: func(_ string, value any) any {
+			parsed, ok := parser.parse(value)
+			if !ok || parsed.input != "" {
+				// the inputs are replaced by the template evaluator
+				return value
+			}
+			ref, ok := datasources[parsed.key()]
+			if !ok || ref.Target == nil {
+				return value
+			}
+			return map[string]any{"type": ref.Target.Type, "uid": ref.Target.UID}
+		},
+		libraryPanel: func(_ string, libraryPanel map[string]any) {
+			uid, _ := libraryPanel["uid"].(string)
+			ref, ok := libraryPanels[uid]
+			if !ok || ref.Status != dashboardimport.MappingStatusMapped || ref.Target == nil {
+				return
+			}
+			libraryPanel["uid"] = ref.Target.UID
+			libraryPanel["name"] = ref.Target.Name
+		},
+		variable: func(variable map[string]any) {
+			name, _ := variable["name"].(string)
+			ref, ok := variables[name]
+			if !ok || ref.Status != dashboardimport.MappingStatusMapped {
+				return
+			}
+			variable["query"] = ref.Target
+			variable["current"] = map[string]any{"text": ref.Target, "value": ref.Target}
+			if _, ok := variable["options"]; ok {
+				variable["options"] = []any{map[string]any{"text": ref.Target, "value": ref.Target, "selected": true}}
+			}
+		},
+	})
+
+	if len(remapped.Get("__inputs").MustArray()) == 0 {
+		return remapped, nil
+	}
+
+	var inputs []dashboardimport.ImportDashboardInput
+	for _, ref := range refs.Datasources {
+		if ref.Input == "" {
+			continue
+		}
+		if ref.Target == nil {
+			return remapped, nil
+		}
+		inputs = append(inputs, dashboardimport.ImportDashboardInput{
+			Name:     ref.Input,
+			Type:     "datasource",
+			PluginId: ref.Target.Type,
+			Value:    ref.Target.UID,
+		})
+	}
+	for _, ref := range refs.Variables {
+		if ref.Type != "input" {
+			continue
+		}
+		value := ref.Value
+		if ref.Status == dashboardimport.MappingStatusMapped {
+			value = ref.Target
+		}
+		if !ref.Status.Resolved() {
+			return remapped, nil
+		}
+		inputs = append(inputs, dashboardimport.ImportDashboardInput{
+			Name:  ref.Name,
+			Type:  "constant",
+			Value: value,
+		})
+	}
+
+	return NewDashTemplateEvaluator(remapped, inputs).Eval()
+}
+
+type referenceVisitor struct {
+	// datasource is called with every datasource reference, which is replaced with the returned value
+	datasource   func(path string, value any) any
+	libraryPanel func(path string, libraryPanel map[string]any)
+	variable     func(variable map[string]any)
+}
+
+// walkReferences visits the references of the panels, including the panels of the collapsed rows, of their queries,
+// of the annotations and of the variables
+func walkReferences(dash map[string]any, v referenceVisitor) {
+	walkPanels(dash["panels"], "panels", v)
+
+	if annotations, ok := dash["annotations"].(map[string]any); ok {
+		for i, annotation := range asMaps(annotations["list"]) {
+			visitDatasource(annotation, fmt.Sprintf("annotations.list[%d]", i), v)
+		}
+	}
+
+	if templating, ok := dash["templating"].(map[string]any); ok {
+		for i, variable := range asMaps(templating["list"]) {
+			visitDatasource(variable, fmt.Sprintf("templating.list[%d]", i), v)
+			v.variable(variable)
+		}
+	}
+}
+
+func walkPanels(panels any, path string, v referenceVisitor) {
+	for i, panel := range asMaps(panels) {
+		panelPath := fmt.Sprintf("%s[%d]", path, i)
+		visitDatasource(panel, panelPath, v)
+		for j, target := range asMaps(panel["targets"]) {
+			visitDatasource(target, fmt.Sprintf("%s.targets[%d]", panelPath, j), v)
+		}
+		if libraryPanel, ok := panel["libraryPanel"].(map[string]any); ok {
+			v.libraryPanel(panelPath+".libraryPanel", libraryPanel)
+		}
+		walkPanels(panel["panels"], panelPath+".panels", v)
+	}
+}
+
+func visitDatasource(container map[string]any, path string, v referenceVisitor) {
+	value, ok := container["datasource"]
+	if !ok || value == nil {
+		return
+	}
+	container["datasource"] = v.datasource(path+".datasource", value)
+}
+
+func asMaps(value any) []map[string]any {
+	list, _ := value.([]any)
+	maps := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		if m, ok := item.(map[string]any); ok {
+			maps = append(maps, m)
+		}
+	}
+	return maps
+}
+
+// libraryElementIncluded checks if the model of the library panel is in the __elements of the dashboard, which are
+// either a map by UID or an array in the older exports
+func libraryElementIncluded(elements *simplejson.Json, uid string) bool {
+	if _, ok := elements.CheckGet(uid); ok {
+		return true
+	}
+	for _, element := range elements.MustArray() {
+		if simplejson.NewFromAny(element).Get("uid").MustString() == uid {
+			return true
+		}
+	}
+	return false
+}
+
+type datasourceRef struct {
+	uid   string
+	name  string
+	typ   string
+	input string
+}
+
+// key is the key of the reference in the mapping: the input name, the UID, or the name of the legacy references
+func (r datasourceRef) key() string {
+	switch {
+	case r.input != "":
+		return r.input
+	case r.uid != "":
+		return r.uid
+	default:
+		return r.name
+	}
+}
+
+type datasourceRefParser struct {
+	inputs map[string]string
+}
+
+func newDatasourceRefParser(dash *simplejson.Json) *datasourceRefParser {
+	p := &datasourceRefParser{inputs: map[string]string{}}
+	for _, input := range dash.Get("__inputs").MustArray() {
+		inputJson := simplejson.NewFromAny(input)
+		if inputJson.Get("type").MustString() == "datasource" && inputJson.Get("pluginId").MustString() != expr.DatasourceType {
+			p.inputs[inputJson.Get("name").MustString()] = inputJson.Get("pluginId").MustString()
+		}
+	}
+	return p
+}
+
+// parse parses a reference to a datasource of the org. The built-in datasources, the datasource variables and the
+// default datasource aren't references to a datasource of the org.
+func (p *datasourceRefParser) parse(value any) (datasourceRef, bool) {
+	var ref datasourceRef
+	switch v := value.(type) {
+	case string:
+		ref.name = v
+	case map[string]any:
+		ref.uid, _ = v["uid"].(string)
+		ref.typ, _ = v["type"].(string)
+	default:
+		return ref, false
+	}
+
+	id := ref.uid
+	if id == "" {
+		id = ref.name
+	}
+	if match := inputRefRegex.FindStringSubmatch(id); match != nil {
+		if typ, ok := p.inputs[match[1]]; ok {
+			return datasourceRef{input: match[1], typ: typ}, true
+		}
+	}
+	if id == "" || builtInDatasources[id] || strings.HasPrefix(id, "$") || ref.typ == expr.DatasourceType {
+		return ref, false
+	}
+	return ref, true
+}
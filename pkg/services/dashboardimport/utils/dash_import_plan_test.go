@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+)
+
+func TestDashboardReferences(t *testing.T) {
+	dash, err := simplejson.NewJson([]byte(`{
+		"__inputs": [
+			{"name": "DS_PROM", "label": "Prometheus", "type": "datasource", "pluginId": "prometheus"},
+			{"name": "VAR_ENV", "type": "constant", "value": "prod"}
+		],
+		"__elements": {
+			"lib-1": {"uid": "lib-1", "name": "Shared panel", "model": {}}
+		},
+		"annotations": {
+			"list": [{"datasource": {"type": "grafana", "uid": "-- Grafana --"}}]
+		},
+		"panels": [
+			{"id": 1, "datasource": {"type": "prometheus", "uid": "${DS_PROM}"}, "targets": [
+				{"expr": "up{env=\"${VAR_ENV}\"}", "datasource": {"type": "prometheus", "uid": "${DS_PROM}"}}
+			]},
+			{"id": 2, "type": "row", "panels": [
+				{"id": 3, "datasource": {"type": "loki", "uid": "loki-uid"}},
+				{"id": 4, "datasource": "Legacy Graphite"},
+				{"id": 5, "datasource": {"type": "loki", "uid": "${logs}"}}
+			]},
+			{"id": 6, "libraryPanel": {"uid": "lib-1", "name": "Shared panel"}}
+		],
+		"templating": {
+			"list": [
+				{"name": "logs", "type": "datasource", "query": "loki"},
+				{"name": "cluster", "type": "constant", "query": "eu-west"}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+
+	refs := FindDashboardReferences(dash)
+
+	require.Len(t, refs.Datasources, 3)
+	assert.Equal(t, "DS_PROM", refs.Datasources[0].Key)
+	assert.Equal(t, "prometheus", refs.Datasources[0].Type)
+	assert.Equal(t, []string{"panels[0].datasource", "panels[0].targets[0].datasource"}, refs.Datasources[0].Paths)
+	assert.Equal(t, "loki-uid", refs.Datasources[1].Key)
+	assert.Equal(t, []string{"panels[1].panels[0].datasource"}, refs.Datasources[1].Paths)
+	assert.Equal(t, "Legacy Graphite", refs.Datasources[2].Key)
+
+	require.Len(t, refs.Variables, 2)
+	assert.Equal(t, dashboardimport.VariableReference{Name: "VAR_ENV", Type: "input", Value: "prod"}, *refs.Variables[0])
+	assert.Equal(t, dashboardimport.VariableReference{Name: "cluster", Type: "constant", Value: "eu-west"}, *refs.Variables[1])
+
+	require.Len(t, refs.LibraryPanels, 1)
+	assert.True(t, refs.LibraryPanels[0].Included)
+
+	t.Run("remaps the resolved references", func(t *testing.T) {
+		refs.Datasources[0].Status = dashboardimport.MappingStatusMatched
+		refs.Datasources[0].Target = &dashboardimport.DatasourceTarget{UID: "prom-uid", Type: "prometheus"}
+		refs.Datasources[1].Status = dashboardimport.MappingStatusMapped
+		refs.Datasources[1].Target = &dashboardimport.DatasourceTarget{UID: "other-loki", Type: "loki"}
+		refs.Datasources[2].Status = dashboardimport.MappingStatusUnmapped
+		refs.Variables[0].Status = dashboardimport.MappingStatusDefault
+		refs.Variables[1].Status = dashboardimport.MappingStatusMapped
+		refs.Variables[1].Target = "us-east"
+		refs.LibraryPanels[0].Status = dashboardimport.MappingStatusMapped
+		refs.LibraryPanels[0].Target = &dashboardimport.LibraryPanelTarget{UID: "lib-2", Name: "Other panel"}
+
+		remapped, err := RemapDashboard(dash, refs)
+		require.NoError(t, err)
+
+		panels := remapped.Get("panels")
+		assert.Equal(t, "prom-uid", panels.GetIndex(0).GetPath("datasource", "uid").MustString())
+		assert.Equal(t, `up{env="prod"}`, panels.GetIndex(0).Get("targets").GetIndex(0).Get("expr").MustString())
+		assert.Equal(t, "other-loki", panels.GetIndex(1).Get("panels").GetIndex(0).GetPath("datasource", "uid").MustString())
+		assert.Equal(t, "Legacy Graphite", panels.GetIndex(1).Get("panels").GetIndex(1).Get("datasource").MustString())
+		assert.Equal(t, "${logs}", panels.GetIndex(1).Get("panels").GetIndex(2).GetPath("datasource", "uid").MustString())
+		assert.Equal(t, "lib-2", panels.GetIndex(2).GetPath("libraryPanel", "uid").MustString())
+		assert.Equal(t, "us-east", remapped.Get("templating").Get("list").GetIndex(1).Get("query").MustString())
+		assert.Nil(t, remapped.Get("__inputs").Interface(), "the inputs are evaluated")
+
+		assert.Equal(t, "${DS_PROM}", dash.Get("panels").GetIndex(0).GetPath("datasource", "uid").MustString(), "the dashboard isn't modified")
+	})
+}
@@ -24,6 +24,8 @@ const (
 
 	ScopeDashboardsRoot   = "dashboards"
 	ScopeDashboardsPrefix = "dashboards:uid:"
+	// ScopeDashboardsTagPrefix is the prefix of the condition scopes granting access to the dashboards with a tag
+	ScopeDashboardsTagPrefix = ScopeDashboardsRoot + ":" + ac.ConditionAttributeTag + ":"
 
 	ActionDashboardsCreate           = "dashboards:create"
 	ActionDashboardsRead             = "dashboards:read"
@@ -187,6 +189,11 @@ func resolveDashboardScope(ctx context.Context, orgID int64, dashboard *Dashboar
 		result...,
 	)
 
+	// Permissions can be conditioned on the tags of the dashboard
+	if dashboard.Data != nil {
+		result = append(result, ac.ConditionScopes(ScopeDashboardsRoot, ac.ConditionAttributeTag, dashboard.GetTags()...)...)
+	}
+
 	return result, nil
 }
 
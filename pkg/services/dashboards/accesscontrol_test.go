@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/folder/foldertest"
 )
@@ -82,4 +83,17 @@ func TestNewDashboardUIDScopeResolver(t *testing.T) {
 		_, err := resolver.Resolve(context.Background(), rand.Int63(), "dashboards:id:123")
 		require.ErrorIs(t, err, ac.ErrInvalidScope)
 	})
+
+	t.Run("resolver should add the tag conditions of the dashboard", func(t *testing.T) {
+		dashSvc := &FakeDashboardService{}
+		dashSvc.On("GetDashboard", mock.Anything, mock.Anything).Return(&Dashboard{
+			UID:  "abc",
+			Data: simplejson.NewFromAny(map[string]any{"tags": []any{"team-a", "prod"}}),
+		}, nil)
+		_, resolver := NewDashboardUIDScopeResolver(dashSvc, foldertest.NewFakeService())
+
+		resolved, err := resolver.Resolve(context.Background(), rand.Int63(), "dashboards:uid:abc")
+		require.NoError(t, err)
+		require.Equal(t, []string{"dashboards:uid:abc", "folders:uid:general", "dashboards:tag:team-a", "dashboards:tag:prod"}, resolved)
+	})
 }
@@ -10,6 +10,7 @@ import (
 
 	claims "github.com/grafana/authlib/types"
 
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
@@ -501,6 +502,24 @@ func (d *dashboardStore) saveDashboard(ctx context.Context, sess *db.Session, cm
 			return dash, err
 		}
 	}
+	if dash.IsFolder {
+		sess.PublishAfterCommit(&events.FolderSaved{
+			Timestamp: dash.Updated,
+			ID:        dash.ID,
+			UID:       dash.UID,
+			OrgID:     dash.OrgID,
+			ParentUID: dash.FolderUID,
+		})
+	} else {
+		sess.PublishAfterCommit(&events.DashboardSaved{
+			Timestamp: dash.Updated,
+			ID:        dash.ID,
+			UID:       dash.UID,
+			OrgID:     dash.OrgID,
+			FolderUID: dash.FolderUID,
+			Version:   dash.Version,
+		})
+	}
 	return dash, nil
 }
 
@@ -624,6 +643,21 @@ func (d *dashboardStore) deleteDashboard(cmd *dashboards.DeleteDashboardCommand,
 			return err
 		}
 	}
+	if dashboard.IsFolder {
+		sess.PublishAfterCommit(&events.FolderDeleted{
+			Timestamp: time.Now(),
+			ID:        dashboard.ID,
+			UID:       dashboard.UID,
+			OrgID:     dashboard.OrgID,
+		})
+	} else {
+		sess.PublishAfterCommit(&events.DashboardDeleted{
+			Timestamp: time.Now(),
+			ID:        dashboard.ID,
+			UID:       dashboard.UID,
+			OrgID:     dashboard.OrgID,
+		})
+	}
 	return nil
 }
 
@@ -748,7 +782,7 @@ func (d *dashboardStore) GetDashboard(ctx context.Context, query *dashboards.Get
 	defer span.End()
 
 	var queryResult *dashboards.Dashboard
-	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
+	err := d.store.WithReadReplicaSession(ctx, func(sess *db.Session) error {
 		metrics.MFolderIDsServiceCount.WithLabelValues(metrics.Dashboard).Inc()
 		// nolint:staticcheck
 		if query.ID == 0 && len(query.UID) == 0 && (query.Title == nil || (query.FolderID == nil && query.FolderUID == nil)) {
@@ -870,7 +904,7 @@ func (d *dashboardStore) FindDashboards(ctx context.Context, query *dashboards.F
 
 	sql, params := sb.ToSQL(limit, page)
 
-	err = d.store.WithDbSession(ctx, func(sess *db.Session) error {
+	err = d.store.WithReadReplicaSession(ctx, func(sess *db.Session) error {
 		return sess.SQL(sql, params...).Find(&res)
 	})
 
@@ -921,7 +955,7 @@ func (d *dashboardStore) GetDashboardTags(ctx context.Context, query *dashboards
 	defer span.End()
 
 	queryResult := make([]*dashboards.DashboardTagCloudItem, 0)
-	err := d.store.WithDbSession(ctx, func(dbSession *db.Session) error {
+	err := d.store.WithReadReplicaSession(ctx, func(dbSession *db.Session) error {
 		sql := `SELECT
 					  COUNT(*) as count,
 						term
@@ -14,11 +14,12 @@ import (
 
 var (
 	excludedFields = map[string]string{
-		resource.SEARCH_FIELD_EXPLAIN: "",
-		resource.SEARCH_FIELD_SCORE:   "",
-		resource.SEARCH_FIELD_TITLE:   "",
-		resource.SEARCH_FIELD_FOLDER:  "",
-		resource.SEARCH_FIELD_TAGS:    "",
+		resource.SEARCH_FIELD_EXPLAIN:   "",
+		resource.SEARCH_FIELD_SCORE:     "",
+		resource.SEARCH_FIELD_HIGHLIGHT: "",
+		resource.SEARCH_FIELD_TITLE:     "",
+		resource.SEARCH_FIELD_FOLDER:    "",
+		resource.SEARCH_FIELD_TAGS:      "",
 	}
 
 	IncludeFields = []string{
@@ -52,6 +53,7 @@ func ParseResults(result *resourcepb.ResourceSearchResponse, offset int64) (v0al
 	tagsIDX := -1
 	scoreIDX := -1
 	explainIDX := -1
+	highlightIDX := -1
 
 	for i, v := range result.Results.Columns {
 		switch v.Name {
@@ -59,6 +61,8 @@ func ParseResults(result *resourcepb.ResourceSearchResponse, offset int64) (v0al
 			explainIDX = i
 		case resource.SEARCH_FIELD_SCORE:
 			scoreIDX = i
+		case resource.SEARCH_FIELD_HIGHLIGHT:
+			highlightIDX = i
 		case resource.SEARCH_FIELD_TITLE:
 			titleIDX = i
 		case resource.SEARCH_FIELD_FOLDER:
@@ -119,6 +123,9 @@ func ParseResults(result *resourcepb.ResourceSearchResponse, offset int64) (v0al
 		if explainIDX >= 0 && row.Cells[explainIDX] != nil {
 			_ = json.Unmarshal(row.Cells[explainIDX], &hit.Explain)
 		}
+		if highlightIDX >= 0 && row.Cells[highlightIDX] != nil {
+			_ = json.Unmarshal(row.Cells[highlightIDX], &hit.Highlight)
+		}
 		if scoreIDX >= 0 && row.Cells[scoreIDX] != nil {
 			_, _ = binary.Decode(row.Cells[scoreIDX], binary.BigEndian, &hit.Score)
 		}
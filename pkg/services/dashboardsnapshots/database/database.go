@@ -2,36 +2,63 @@ package database
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	claims "github.com/grafana/authlib/types"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
+// deleteBatchSize is the number of snapshots deleted at once by DeleteSnapshots
+const deleteBatchSize = 500
+
 type DashboardSnapshotStore struct {
 	store db.DB
+	// objects stores the dashboards of the snapshots when the storage is object, they're stored in the
+	// dashboard_encrypted column otherwise
+	objects *objectStorage
+	log     log.Logger
 }
 
 // DashboardStore implements the Store interface
 var _ dashboardsnapshots.Store = (*DashboardSnapshotStore)(nil)
 
-func ProvideStore(db db.DB, cfg *setting.Cfg) *DashboardSnapshotStore {
+func ProvideStore(db db.DB, cfg *setting.Cfg) (*DashboardSnapshotStore, error) {
 	// nolint:staticcheck
-	return NewStore(db)
+	store := NewStore(db)
+	if cfg.SnapshotStorage == setting.SnapshotStorageObject {
+		objects, err := openObjectStorage(context.Background(), cfg.SnapshotStorageURL)
+		if err != nil {
+			return nil, err
+		}
+		store.objects = objects
+	}
+	return store, nil
 }
 
 func NewStore(db db.DB) *DashboardSnapshotStore {
-	return &DashboardSnapshotStore{store: db}
+	return &DashboardSnapshotStore{store: db, log: log.New("dashboardsnapshots.store")}
 }
 
 // DeleteExpiredSnapshots removes snapshots with old expiry dates.
 // SnapShotRemoveExpired is deprecated and should be removed in the future.
 // Snapshot expiry is decided by the user when they share the snapshot.
 func (d *DashboardSnapshotStore) DeleteExpiredSnapshots(ctx context.Context, cmd *dashboardsnapshots.DeleteExpiredSnapshotsCommand) error {
+	if d.objects != nil {
+		// the dashboards of the snapshots are deleted with them
+		deleteCmd := &dashboardsnapshots.DeleteSnapshotsCommand{
+			SnapshotsFilter: dashboardsnapshots.SnapshotsFilter{ExpiresBefore: time.Now()},
+		}
+		err := d.DeleteSnapshots(ctx, deleteCmd)
+		cmd.DeletedRows = deleteCmd.DeletedRows
+		return err
+	}
+
 	return d.store.WithDbSession(ctx, func(sess *db.Session) error {
 		deleteExpiredSQL := "DELETE FROM dashboard_snapshot WHERE expires < ?"
 		expiredResponse, err := sess.Exec(deleteExpiredSQL, time.Now())
@@ -45,6 +72,14 @@ func (d *DashboardSnapshotStore) DeleteExpiredSnapshots(ctx context.Context, cmd
 }
 
 func (d *DashboardSnapshotStore) CreateDashboardSnapshot(ctx context.Context, cmd *dashboardsnapshots.CreateDashboardSnapshotCommand) (*dashboardsnapshots.DashboardSnapshot, error) {
+	// the dashboard is written before the snapshot, a snapshot is never found without its dashboard
+	storedInObjects := d.objects != nil && !cmd.External && len(cmd.DashboardEncrypted) > 0
+	if storedInObjects {
+		if err := d.objects.put(ctx, cmd.Key, cmd.DashboardEncrypted); err != nil {
+			return nil, err
+		}
+	}
+
 	var result *dashboardsnapshots.DashboardSnapshot
 	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
 		var expires = time.Now().Add(time.Hour * 24 * 365 * 50)
@@ -67,23 +102,149 @@ func (d *DashboardSnapshotStore) CreateDashboardSnapshot(ctx context.Context, cm
 			Created:            time.Now(),
 			Updated:            time.Now(),
 		}
+		if storedInObjects {
+			snapshot.DashboardEncrypted = nil
+		}
 		_, err := sess.Insert(snapshot)
+		snapshot.DashboardEncrypted = cmd.DashboardEncrypted
 		result = snapshot
 
 		return err
 	})
 	if err != nil {
+		if storedInObjects {
+			d.deleteObject(ctx, cmd.Key)
+		}
 		return nil, err
 	}
 	return result, nil
 }
 
 func (d *DashboardSnapshotStore) DeleteDashboardSnapshot(ctx context.Context, cmd *dashboardsnapshots.DeleteDashboardSnapshotCommand) error {
-	return d.store.WithDbSession(ctx, func(sess *db.Session) error {
+	var key string
+	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		if d.objects != nil {
+			snapshot := dashboardsnapshots.DashboardSnapshot{DeleteKey: cmd.DeleteKey}
+			if _, err := sess.Cols("key", "external").Get(&snapshot); err != nil {
+				return err
+			}
+			if !snapshot.External {
+				key = snapshot.Key
+			}
+		}
+
 		var rawSQL = "DELETE FROM dashboard_snapshot WHERE delete_key=?"
 		_, err := sess.Exec(rawSQL, cmd.DeleteKey)
 		return err
 	})
+	if err != nil {
+		return err
+	}
+	if key != "" {
+		d.deleteObject(ctx, key)
+	}
+	return nil
+}
+
+// DeleteSnapshots removes the snapshots of the filter and their dashboards, in batches
+func (d *DashboardSnapshotStore) DeleteSnapshots(ctx context.Context, cmd *dashboardsnapshots.DeleteSnapshotsCommand) error {
+	if cmd.IsEmpty() {
+		return fmt.Errorf("the snapshots to delete must be filtered")
+	}
+
+	for {
+		var snapshots []*dashboardsnapshots.DashboardSnapshot
+		var deleted int64
+		err := d.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+			if err := filterSnapshots(sess, cmd.SnapshotsFilter).Cols("id", "key", "external").Asc("id").Limit(deleteBatchSize).Find(&snapshots); err != nil {
+				return err
+			}
+			if len(snapshots) == 0 {
+				return nil
+			}
+
+			ids := make([]int64, 0, len(snapshots))
+			for _, snapshot := range snapshots {
+				ids = append(ids, snapshot.ID)
+			}
+			var err error
+			deleted, err = sess.In("id", ids).Delete(&dashboardsnapshots.DashboardSnapshot{})
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		cmd.DeletedRows += deleted
+
+		if d.objects != nil {
+			for _, snapshot := range snapshots {
+				if !snapshot.External {
+					d.deleteObject(ctx, snapshot.Key)
+				}
+			}
+		}
+		if len(snapshots) < deleteBatchSize {
+			return nil
+		}
+	}
+}
+
+// FindSnapshots returns a page of the snapshots of the filter in all the organizations, the oldest first
+func (d *DashboardSnapshotStore) FindSnapshots(ctx context.Context, query *dashboardsnapshots.FindSnapshotsQuery) (*dashboardsnapshots.FindSnapshotsResult, error) {
+	if query.PerPage <= 0 {
+		query.PerPage = 1000
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+
+	result := &dashboardsnapshots.FindSnapshotsResult{
+		Snapshots: make([]*dashboardsnapshots.DashboardSnapshotAdminDTO, 0),
+		Page:      query.Page,
+		PerPage:   query.PerPage,
+	}
+	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		result.TotalCount, err = filterSnapshots(sess, query.SnapshotsFilter).Count()
+		if err != nil {
+			return err
+		}
+
+		offset := query.PerPage * (query.Page - 1)
+		return filterSnapshots(sess, query.SnapshotsFilter).Asc("created", "id").Limit(query.PerPage, offset).Find(&result.Snapshots)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// filterSnapshots adds the conditions of the filter to the next query of the session
+func filterSnapshots(sess *db.Session, filter dashboardsnapshots.SnapshotsFilter) *db.Session {
+	sess.Table("dashboard_snapshot")
+	if filter.OrgID > 0 {
+		sess.Where("org_id = ?", filter.OrgID)
+	}
+	if len(filter.ExcludeOrgIDs) > 0 {
+		sess.NotIn("org_id", filter.ExcludeOrgIDs)
+	}
+	if filter.UserID > 0 {
+		sess.Where("user_id = ?", filter.UserID)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		sess.Where("created < ?", filter.CreatedBefore)
+	}
+	if !filter.ExpiresBefore.IsZero() {
+		sess.Where("expires < ?", filter.ExpiresBefore)
+	}
+	return sess
+}
+
+// deleteObject removes the dashboard of a deleted snapshot, a failure only leaves an unused object in the bucket
+func (d *DashboardSnapshotStore) deleteObject(ctx context.Context, key string) {
+	if err := d.objects.delete(ctx, key); err != nil {
+		d.log.Warn("Failed to delete the dashboard of a snapshot from the object storage", "key", key, "error", err)
+	}
 }
 
 func (d *DashboardSnapshotStore) GetDashboardSnapshot(ctx context.Context, query *dashboardsnapshots.GetDashboardSnapshotQuery) (*dashboardsnapshots.DashboardSnapshot, error) {
@@ -104,6 +265,14 @@ func (d *DashboardSnapshotStore) GetDashboardSnapshot(ctx context.Context, query
 	if err != nil {
 		return nil, err
 	}
+
+	// the snapshots created before the storage was object still have their dashboard in the database
+	if d.objects != nil && !queryResult.External && len(queryResult.DashboardEncrypted) == 0 {
+		queryResult.DashboardEncrypted, err = d.objects.get(ctx, queryResult.Key)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return queryResult, nil
 }
 
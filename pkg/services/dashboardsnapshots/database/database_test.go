@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	_ "gocloud.dev/blob/memblob"
 
 	common "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
 	dashboardsnapshot "github.com/grafana/grafana/pkg/apis/dashboardsnapshot/v0alpha1"
@@ -31,7 +32,8 @@ func TestIntegrationDashboardSnapshotDBAccess(t *testing.T) {
 	}
 	sqlstore := db.InitTestDB(t)
 	cfg := setting.NewCfg()
-	dashStore := ProvideStore(sqlstore, cfg)
+	dashStore, err := ProvideStore(sqlstore, cfg)
+	require.NoError(t, err)
 
 	origSecret := cfg.SecretKey
 	cfg.SecretKey = "dashboard_snapshot_testing"
@@ -199,6 +201,153 @@ func TestIntegrationDeleteExpiredSnapshots(t *testing.T) {
 	})
 }
 
+func TestIntegrationDeleteSnapshots(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlstore := db.InitTestDB(t)
+	dashStore := NewStore(sqlstore)
+	ctx := context.Background()
+
+	createSnapshot := func(key string, orgID, userID int64, age time.Duration) {
+		_, err := dashStore.CreateDashboardSnapshot(ctx, &dashboardsnapshots.CreateDashboardSnapshotCommand{
+			Key:       key,
+			DeleteKey: "delete" + key,
+			OrgID:     orgID,
+			UserID:    userID,
+		})
+		require.NoError(t, err)
+		err = dashStore.store.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Exec("UPDATE dashboard_snapshot SET created = ? WHERE delete_key = ?", time.Now().Add(-age), "delete"+key)
+			return err
+		})
+		require.NoError(t, err)
+	}
+	createSnapshot("old-1", 1, 10, 48*time.Hour)
+	createSnapshot("old-2", 2, 20, 72*time.Hour)
+	createSnapshot("recent-1", 1, 20, time.Hour)
+	createSnapshot("recent-2", 2, 10, time.Hour)
+
+	findKeys := func(filter dashboardsnapshots.SnapshotsFilter) []string {
+		result, err := dashStore.FindSnapshots(ctx, &dashboardsnapshots.FindSnapshotsQuery{SnapshotsFilter: filter})
+		require.NoError(t, err)
+		keys := make([]string, 0, len(result.Snapshots))
+		for _, snapshot := range result.Snapshots {
+			keys = append(keys, snapshot.Key)
+		}
+		assert.EqualValues(t, len(keys), result.TotalCount)
+		return keys
+	}
+
+	t.Run("should find the snapshots of the filter, the oldest first", func(t *testing.T) {
+		assert.Equal(t, []string{"old-2", "old-1", "recent-1", "recent-2"}, findKeys(dashboardsnapshots.SnapshotsFilter{}))
+		assert.Equal(t, []string{"old-2", "old-1"}, findKeys(dashboardsnapshots.SnapshotsFilter{CreatedBefore: time.Now().Add(-24 * time.Hour)}))
+		assert.Equal(t, []string{"old-1", "recent-2"}, findKeys(dashboardsnapshots.SnapshotsFilter{UserID: 10}))
+		assert.Equal(t, []string{"old-2", "recent-2"}, findKeys(dashboardsnapshots.SnapshotsFilter{ExcludeOrgIDs: []int64{1}}))
+	})
+
+	t.Run("should return the pages of the snapshots", func(t *testing.T) {
+		result, err := dashStore.FindSnapshots(ctx, &dashboardsnapshots.FindSnapshotsQuery{Page: 2, PerPage: 3})
+		require.NoError(t, err)
+		assert.EqualValues(t, 4, result.TotalCount)
+		require.Len(t, result.Snapshots, 1)
+		assert.Equal(t, "recent-2", result.Snapshots[0].Key)
+	})
+
+	t.Run("should not delete all the snapshots without a filter", func(t *testing.T) {
+		err := dashStore.DeleteSnapshots(ctx, &dashboardsnapshots.DeleteSnapshotsCommand{})
+		require.Error(t, err)
+		assert.Len(t, findKeys(dashboardsnapshots.SnapshotsFilter{}), 4)
+	})
+
+	t.Run("should delete the snapshots of the filter", func(t *testing.T) {
+		cmd := &dashboardsnapshots.DeleteSnapshotsCommand{
+			SnapshotsFilter: dashboardsnapshots.SnapshotsFilter{OrgID: 1, CreatedBefore: time.Now().Add(-24 * time.Hour)},
+		}
+		err := dashStore.DeleteSnapshots(ctx, cmd)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, cmd.DeletedRows)
+		assert.Equal(t, []string{"old-2", "recent-1", "recent-2"}, findKeys(dashboardsnapshots.SnapshotsFilter{}))
+	})
+}
+
+func TestIntegrationSnapshotObjectStorage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlstore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.SnapshotStorage = setting.SnapshotStorageObject
+	cfg.SnapshotStorageURL = "mem://"
+	dashStore, err := ProvideStore(sqlstore, cfg)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	createSnapshot := func(key string) {
+		_, err := dashStore.CreateDashboardSnapshot(ctx, &dashboardsnapshots.CreateDashboardSnapshotCommand{
+			Key:                key,
+			DeleteKey:          "delete" + key,
+			DashboardEncrypted: []byte("encrypted " + key),
+			OrgID:              1,
+		})
+		require.NoError(t, err)
+	}
+	objectExists := func(key string) bool {
+		exists, err := dashStore.objects.bucket.Exists(ctx, key)
+		require.NoError(t, err)
+		return exists
+	}
+
+	t.Run("should store the dashboard in the bucket", func(t *testing.T) {
+		createSnapshot("stored")
+
+		stored := dashboardsnapshots.DashboardSnapshot{DeleteKey: "deletestored"}
+		err := dashStore.store.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Get(&stored)
+			return err
+		})
+		require.NoError(t, err)
+		assert.Empty(t, stored.DashboardEncrypted)
+
+		snapshot, err := dashStore.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: "stored"})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("encrypted stored"), snapshot.DashboardEncrypted)
+	})
+
+	t.Run("should delete the dashboard with the snapshot", func(t *testing.T) {
+		createSnapshot("deleted")
+		require.True(t, objectExists("deleted"))
+
+		err := dashStore.DeleteDashboardSnapshot(ctx, &dashboardsnapshots.DeleteDashboardSnapshotCommand{DeleteKey: "deletedeleted"})
+		require.NoError(t, err)
+		assert.False(t, objectExists("deleted"))
+	})
+
+	t.Run("should delete the dashboards of the expired snapshots", func(t *testing.T) {
+		createSnapshot("expired")
+		err := dashStore.store.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Exec("UPDATE dashboard_snapshot SET expires = ? WHERE delete_key = ?", time.Now().Add(-time.Hour), "deleteexpired")
+			return err
+		})
+		require.NoError(t, err)
+
+		cmd := &dashboardsnapshots.DeleteExpiredSnapshotsCommand{}
+		err = dashStore.DeleteExpiredSnapshots(ctx, cmd)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, cmd.DeletedRows)
+		assert.False(t, objectExists("expired"))
+		assert.True(t, objectExists("stored"))
+	})
+
+	t.Run("should return not found when the dashboard is missing from the bucket", func(t *testing.T) {
+		createSnapshot("missing")
+		require.NoError(t, dashStore.objects.bucket.Delete(ctx, "missing"))
+
+		_, err := dashStore.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: "missing"})
+		assert.ErrorIs(t, err, dashboardsnapshots.ErrBaseNotFound)
+	})
+}
+
 func createTestSnapshot(t *testing.T, dashStore *DashboardSnapshotStore, key string, expires int64) *dashboardsnapshots.DashboardSnapshot {
 	cmd := dashboardsnapshots.CreateDashboardSnapshotCommand{
 		Key:       key,
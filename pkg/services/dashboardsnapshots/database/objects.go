@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"gocloud.dev/blob"
+	_ "gocloud.dev/blob/fileblob"
+	_ "gocloud.dev/blob/s3blob"
+	"gocloud.dev/gcerrors"
+
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+)
+
+// objectStorage stores the encrypted dashboards of the snapshots in a bucket, named by the keys of the snapshots
+type objectStorage struct {
+	bucket *blob.Bucket
+}
+
+// openObjectStorage opens the bucket of a URL like s3://bucket?region=us-east-1&prefix=snapshots/, the
+// credentials of S3 are read from the environment
+func openObjectStorage(ctx context.Context, bucketURL string) (*objectStorage, error) {
+	bucket, err := blob.OpenBucket(ctx, bucketURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the bucket of the snapshots: %w", err)
+	}
+	return &objectStorage{bucket: bucket}, nil
+}
+
+func (o *objectStorage) put(ctx context.Context, key string, data []byte) error {
+	return o.bucket.WriteAll(ctx, key, data, &blob.WriterOptions{ContentType: "application/octet-stream"})
+}
+
+func (o *objectStorage) get(ctx context.Context, key string) ([]byte, error) {
+	data, err := o.bucket.ReadAll(ctx, key)
+	if gcerrors.Code(err) == gcerrors.NotFound {
+		return nil, dashboardsnapshots.ErrBaseNotFound.Errorf("dashboard of the snapshot not found in the object storage")
+	}
+	return data, err
+}
+
+// delete removes the dashboard of a snapshot, a missing dashboard isn't an error
+func (o *objectStorage) delete(ctx context.Context, key string) error {
+	if err := o.bucket.Delete(ctx, key); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+		return err
+	}
+	return nil
+}
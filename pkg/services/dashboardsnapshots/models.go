@@ -77,6 +77,55 @@ type DeleteExpiredSnapshotsCommand struct {
 	DeletedRows int64
 }
 
+// SnapshotsFilter selects the snapshots of all the organizations matching all its set fields
+type SnapshotsFilter struct {
+	OrgID         int64
+	ExcludeOrgIDs []int64
+	UserID        int64
+	CreatedBefore time.Time
+	ExpiresBefore time.Time
+}
+
+// IsEmpty is true when the filter selects all the snapshots
+func (f SnapshotsFilter) IsEmpty() bool {
+	return f.OrgID == 0 && len(f.ExcludeOrgIDs) == 0 && f.UserID == 0 && f.CreatedBefore.IsZero() && f.ExpiresBefore.IsZero()
+}
+
+// DeleteSnapshotsCommand deletes the snapshots of the filter, for the retention policies and the server admins
+type DeleteSnapshotsCommand struct {
+	SnapshotsFilter
+	DeletedRows int64
+}
+
+// FindSnapshotsQuery finds the snapshots of the filter, the oldest first
+type FindSnapshotsQuery struct {
+	SnapshotsFilter
+	Page    int
+	PerPage int
+}
+
+type FindSnapshotsResult struct {
+	TotalCount int64                        `json:"totalCount"`
+	Snapshots  []*DashboardSnapshotAdminDTO `json:"snapshots"`
+	Page       int                          `json:"page"`
+	PerPage    int                          `json:"perPage"`
+}
+
+// DashboardSnapshotAdminDTO is a snapshot of any organization, without dashboard map
+type DashboardSnapshotAdminDTO struct {
+	ID          int64  `json:"id" xorm:"id"`
+	Name        string `json:"name"`
+	Key         string `json:"key"`
+	OrgID       int64  `json:"orgId" xorm:"org_id"`
+	UserID      int64  `json:"userId" xorm:"user_id"`
+	External    bool   `json:"external"`
+	ExternalURL string `json:"externalUrl" xorm:"external_url"`
+
+	Expires time.Time `json:"expires"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
 type GetDashboardSnapshotQuery struct {
 	Key       string
 	DeleteKey string
@@ -0,0 +1,140 @@
+package dashboardsnapshots
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// SanitizeKeysRule is the rule of the keys of the sanitize_keys setting in the removed values
+const SanitizeKeysRule = "keys"
+
+// userKeys are the keys removed by the users rule
+var userKeys = []string{"createdBy", "updatedBy", "login", "email", "user", "username", "userName"}
+
+// variableValueTypes are the types of the template variables whose query is their value
+var variableValueTypes = []string{"custom", "textbox", "constant"}
+
+// SanitizedValue is a value removed from the dashboard of a snapshot
+type SanitizedValue struct {
+	// Path of the value in the dashboard, like panels[0].datasource.uid
+	Path string `json:"path"`
+	// Rule removing the value
+	Rule string `json:"rule"`
+}
+
+// Sanitizer removes the sensitive values from the dashboards of the snapshots before they're stored or published
+type Sanitizer struct {
+	variables   bool
+	datasources bool
+	// keys are the keys removed wherever they're found, with their rule
+	keys map[string]string
+}
+
+func NewSanitizer(rules []string, keys []string) *Sanitizer {
+	s := &Sanitizer{
+		variables:   slices.Contains(rules, setting.SnapshotSanitizeVariables),
+		datasources: slices.Contains(rules, setting.SnapshotSanitizeDatasources),
+		keys:        map[string]string{},
+	}
+	if slices.Contains(rules, setting.SnapshotSanitizeUsers) {
+		for _, key := range userKeys {
+			s.keys[key] = setting.SnapshotSanitizeUsers
+		}
+	}
+	if slices.Contains(rules, setting.SnapshotSanitizeLinks) {
+		s.keys["links"] = setting.SnapshotSanitizeLinks
+	}
+	for _, key := range keys {
+		s.keys[key] = SanitizeKeysRule
+	}
+	return s
+}
+
+// Sanitize removes the values of the rules from the dashboard and returns them
+func (s *Sanitizer) Sanitize(dashboard map[string]any) []SanitizedValue {
+	removed := make([]SanitizedValue, 0)
+	if s.variables {
+		removed = append(removed, sanitizeVariables(dashboard)...)
+	}
+	if s.datasources || len(s.keys) > 0 {
+		removed = s.walk(dashboard, "", removed)
+	}
+	return removed
+}
+
+// walk removes the keys and the data source references from the value and its children
+func (s *Sanitizer) walk(value any, path string, removed []SanitizedValue) []SanitizedValue {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			keyPath := joinPath(path, key)
+			if rule, ok := s.keys[key]; ok {
+				delete(v, key)
+				removed = append(removed, SanitizedValue{Path: keyPath, Rule: rule})
+				continue
+			}
+			if key == "datasource" && s.datasources {
+				switch ref := v[key].(type) {
+				case map[string]any:
+					if _, ok := ref["uid"]; ok {
+						delete(ref, "uid")
+						removed = append(removed, SanitizedValue{Path: keyPath + ".uid", Rule: setting.SnapshotSanitizeDatasources})
+					}
+				case string:
+					// the data sources used to be referenced by name
+					delete(v, key)
+					removed = append(removed, SanitizedValue{Path: keyPath, Rule: setting.SnapshotSanitizeDatasources})
+					continue
+				}
+			}
+			removed = s.walk(v[key], keyPath, removed)
+		}
+	case []any:
+		for i, item := range v {
+			removed = s.walk(item, fmt.Sprintf("%s[%d]", path, i), removed)
+		}
+	}
+	return removed
+}
+
+// sanitizeVariables removes the selected values and the options of the template variables, and the query of the
+// variables whose query is their value
+func sanitizeVariables(dashboard map[string]any) []SanitizedValue {
+	templating, _ := dashboard["templating"].(map[string]any)
+	list, _ := templating["list"].([]any)
+
+	var removed []SanitizedValue
+	for i, item := range list {
+		variable, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		keys := []string{"current", "options", "filters"}
+		if variableType, _ := variable["type"].(string); slices.Contains(variableValueTypes, variableType) {
+			keys = append(keys, "query")
+		}
+		for _, key := range keys {
+			if _, ok := variable[key]; ok {
+				delete(variable, key)
+				removed = append(removed, SanitizedValue{Path: fmt.Sprintf("templating.list[%d].%s", i, key), Rule: setting.SnapshotSanitizeVariables})
+			}
+		}
+	}
+	return removed
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,81 @@
+package dashboardsnapshots
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const sanitizeTestDashboard = `{
+	"uid": "abc",
+	"title": "Checkout",
+	"links": [{"title": "Runbook", "url": "https://wiki.example.com/checkout"}],
+	"templating": {"list": [
+		{"name": "env", "type": "custom", "query": "prod,staging", "current": {"text": "prod", "value": "prod"}, "options": [{"text": "prod", "value": "prod"}]},
+		{"name": "host", "type": "query", "query": "label_values(host)", "datasource": {"type": "prometheus", "uid": "prom-1"}, "current": {"text": "web-1", "value": "web-1"}}
+	]},
+	"panels": [
+		{"id": 1, "datasource": {"type": "prometheus", "uid": "prom-1"}, "targets": [{"refId": "A", "datasource": "Prometheus"}]},
+		{"id": 2, "createdBy": "admin", "fieldConfig": {"defaults": {"links": [{"url": "https://internal"}]}}, "options": {"apiKey": "secret"}}
+	]
+}`
+
+func TestSanitizer_Sanitize(t *testing.T) {
+	sanitize := func(t *testing.T, s *Sanitizer) (map[string]any, []SanitizedValue) {
+		t.Helper()
+		var dashboard map[string]any
+		require.NoError(t, json.Unmarshal([]byte(sanitizeTestDashboard), &dashboard))
+		return dashboard, s.Sanitize(dashboard)
+	}
+
+	t.Run("should not change the dashboard without rules", func(t *testing.T) {
+		dashboard, removed := sanitize(t, NewSanitizer(nil, nil))
+		assert.Empty(t, removed)
+
+		var expected map[string]any
+		require.NoError(t, json.Unmarshal([]byte(sanitizeTestDashboard), &expected))
+		assert.Equal(t, expected, dashboard)
+	})
+
+	t.Run("should remove the values of the template variables", func(t *testing.T) {
+		dashboard, removed := sanitize(t, NewSanitizer([]string{setting.SnapshotSanitizeVariables}, nil))
+		assert.Equal(t, []SanitizedValue{
+			{Path: "templating.list[0].current", Rule: "variables"},
+			{Path: "templating.list[0].options", Rule: "variables"},
+			{Path: "templating.list[0].query", Rule: "variables"},
+			{Path: "templating.list[1].current", Rule: "variables"},
+		}, removed)
+
+		variables := dashboard["templating"].(map[string]any)["list"].([]any)
+		assert.Equal(t, map[string]any{"name": "env", "type": "custom"}, variables[0])
+		assert.Equal(t, "label_values(host)", variables[1].(map[string]any)["query"])
+	})
+
+	t.Run("should remove the data source references", func(t *testing.T) {
+		dashboard, removed := sanitize(t, NewSanitizer([]string{setting.SnapshotSanitizeDatasources}, nil))
+		assert.Equal(t, []SanitizedValue{
+			{Path: "panels[0].datasource.uid", Rule: "datasources"},
+			{Path: "panels[0].targets[0].datasource", Rule: "datasources"},
+			{Path: "templating.list[1].datasource.uid", Rule: "datasources"},
+		}, removed)
+
+		panel := dashboard["panels"].([]any)[0].(map[string]any)
+		assert.Equal(t, map[string]any{"type": "prometheus"}, panel["datasource"])
+	})
+
+	t.Run("should remove the users, the links and the configured keys", func(t *testing.T) {
+		dashboard, removed := sanitize(t, NewSanitizer([]string{setting.SnapshotSanitizeUsers, setting.SnapshotSanitizeLinks}, []string{"apiKey"}))
+		assert.Equal(t, []SanitizedValue{
+			{Path: "links", Rule: "links"},
+			{Path: "panels[1].createdBy", Rule: "users"},
+			{Path: "panels[1].fieldConfig.defaults.links", Rule: "links"},
+			{Path: "panels[1].options.apiKey", Rule: "keys"},
+		}, removed)
+		assert.NotContains(t, dashboard, "links")
+		assert.Equal(t, "Checkout", dashboard["title"])
+	})
+}
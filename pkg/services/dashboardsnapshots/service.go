@@ -26,7 +26,10 @@ type Service interface {
 	CreateDashboardSnapshot(context.Context, *CreateDashboardSnapshotCommand) (*DashboardSnapshot, error)
 	DeleteDashboardSnapshot(context.Context, *DeleteDashboardSnapshotCommand) error
 	DeleteExpiredSnapshots(context.Context, *DeleteExpiredSnapshotsCommand) error
+	DeleteSnapshots(context.Context, *DeleteSnapshotsCommand) error
+	FindSnapshots(context.Context, *FindSnapshotsQuery) (*FindSnapshotsResult, error)
 	GetDashboardSnapshot(context.Context, *GetDashboardSnapshotQuery) (*DashboardSnapshot, error)
+	SanitizeDashboard(*common.Unstructured) []SanitizedValue
 	SearchDashboardSnapshots(context.Context, *GetDashboardSnapshotsQuery) (DashboardSnapshotsList, error)
 	ValidateDashboardExists(context.Context, int64, string) error
 }
@@ -63,6 +66,9 @@ func CreateDashboardSnapshot(c *contextmodel.ReqContext, cfg dashboardsnapshot.S
 		cmd.Name = "Unnamed snapshot"
 	}
 
+	// the sensitive values are removed before the dashboard is stored or published to the external snapshot server
+	svc.SanitizeDashboard(cmd.Dashboard)
+
 	var snapshotUrl string
 	cmd.ExternalURL = ""
 	cmd.OrgID = user.GetOrgID()
@@ -121,6 +127,10 @@ func CreateDashboardSnapshot(c *contextmodel.ReqContext, cfg dashboardsnapshot.S
 
 	result, err := svc.CreateDashboardSnapshot(c.Req.Context(), &cmd)
 	if err != nil {
+		if errors.Is(err, ErrTooLarge) {
+			c.JsonApiErr(http.StatusRequestEntityTooLarge, "Snapshot is too large", err)
+			return
+		}
 		c.JsonApiErr(http.StatusInternalServerError, "Failed to create snapshot", err)
 		return
 	}
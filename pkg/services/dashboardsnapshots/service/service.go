@@ -2,27 +2,39 @@ package service
 
 import (
 	"context"
+	"time"
 
+	common "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 type ServiceImpl struct {
+	cfg              *setting.Cfg
 	store            dashboardsnapshots.Store
 	secretsService   secrets.Service
 	dashboardService dashboards.DashboardService
+	sanitizer        *dashboardsnapshots.Sanitizer
+	log              log.Logger
+	now              func() time.Time
 }
 
 // ServiceImpl implements the dashboardsnapshots Service interface
 var _ dashboardsnapshots.Service = (*ServiceImpl)(nil)
 
-func ProvideService(store dashboardsnapshots.Store, secretsService secrets.Service, dashboardService dashboards.DashboardService) *ServiceImpl {
+func ProvideService(cfg *setting.Cfg, store dashboardsnapshots.Store, secretsService secrets.Service, dashboardService dashboards.DashboardService) *ServiceImpl {
 	s := &ServiceImpl{
+		cfg:              cfg,
 		store:            store,
 		secretsService:   secretsService,
 		dashboardService: dashboardService,
+		sanitizer:        dashboardsnapshots.NewSanitizer(cfg.SnapshotSanitizeRules, cfg.SnapshotSanitizeKeys),
+		log:              log.New("dashboardsnapshots"),
+		now:              time.Now,
 	}
 
 	return s
@@ -38,6 +50,9 @@ func (s *ServiceImpl) CreateDashboardSnapshot(ctx context.Context, cmd *dashboar
 	if err != nil {
 		return nil, err
 	}
+	if s.cfg.SnapshotMaxSize > 0 && int64(len(marshalledData)) > s.cfg.SnapshotMaxSize {
+		return nil, dashboardsnapshots.ErrTooLarge.Errorf("the dashboard of the snapshot is %d bytes, the maximum is %d bytes", len(marshalledData), s.cfg.SnapshotMaxSize)
+	}
 
 	encryptedDashboard, err := s.secretsService.Encrypt(ctx, marshalledData, secrets.WithoutScope())
 	if err != nil {
@@ -72,6 +87,14 @@ func (s *ServiceImpl) GetDashboardSnapshot(ctx context.Context, query *dashboard
 	return queryResult, err
 }
 
+// SanitizeDashboard removes the values of the configured sanitize rules from the dashboard of a snapshot
+func (s *ServiceImpl) SanitizeDashboard(dashboard *common.Unstructured) []dashboardsnapshots.SanitizedValue {
+	if dashboard == nil {
+		return []dashboardsnapshots.SanitizedValue{}
+	}
+	return s.sanitizer.Sanitize(dashboard.Object)
+}
+
 func (s *ServiceImpl) DeleteDashboardSnapshot(ctx context.Context, cmd *dashboardsnapshots.DeleteDashboardSnapshotCommand) error {
 	return s.store.DeleteDashboardSnapshot(ctx, cmd)
 }
@@ -80,6 +103,52 @@ func (s *ServiceImpl) SearchDashboardSnapshots(ctx context.Context, query *dashb
 	return s.store.SearchDashboardSnapshots(ctx, query)
 }
 
+// DeleteExpiredSnapshots removes the expired snapshots, and the snapshots older than the max age of their
+// organization even if they haven't expired
 func (s *ServiceImpl) DeleteExpiredSnapshots(ctx context.Context, cmd *dashboardsnapshots.DeleteExpiredSnapshotsCommand) error {
-	return s.store.DeleteExpiredSnapshots(ctx, cmd)
+	if err := s.store.DeleteExpiredSnapshots(ctx, cmd); err != nil {
+		return err
+	}
+
+	now := s.now()
+	orgIDs := make([]int64, 0, len(s.cfg.SnapshotRetentionPolicies))
+	for _, policy := range s.cfg.SnapshotRetentionPolicies {
+		orgIDs = append(orgIDs, policy.OrgID)
+		if policy.MaxAge <= 0 {
+			continue
+		}
+		deleted, err := s.deleteOlderThan(ctx, dashboardsnapshots.SnapshotsFilter{OrgID: policy.OrgID}, now.Add(-policy.MaxAge))
+		if err != nil {
+			return err
+		}
+		if deleted > 0 {
+			s.log.Debug("Deleted the snapshots of a retention policy", "policy", policy.Name, "orgID", policy.OrgID, "count", deleted)
+		}
+		cmd.DeletedRows += deleted
+	}
+
+	if s.cfg.SnapshotMaxAge > 0 {
+		// the organizations with a retention policy only apply their own max age
+		deleted, err := s.deleteOlderThan(ctx, dashboardsnapshots.SnapshotsFilter{ExcludeOrgIDs: orgIDs}, now.Add(-s.cfg.SnapshotMaxAge))
+		if err != nil {
+			return err
+		}
+		cmd.DeletedRows += deleted
+	}
+	return nil
+}
+
+func (s *ServiceImpl) deleteOlderThan(ctx context.Context, filter dashboardsnapshots.SnapshotsFilter, createdBefore time.Time) (int64, error) {
+	filter.CreatedBefore = createdBefore
+	cmd := &dashboardsnapshots.DeleteSnapshotsCommand{SnapshotsFilter: filter}
+	err := s.store.DeleteSnapshots(ctx, cmd)
+	return cmd.DeletedRows, err
+}
+
+func (s *ServiceImpl) DeleteSnapshots(ctx context.Context, cmd *dashboardsnapshots.DeleteSnapshotsCommand) error {
+	return s.store.DeleteSnapshots(ctx, cmd)
+}
+
+func (s *ServiceImpl) FindSnapshots(ctx context.Context, query *dashboardsnapshots.FindSnapshotsQuery) (*dashboardsnapshots.FindSnapshotsResult, error) {
+	return s.store.FindSnapshots(ctx, query)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -29,10 +30,11 @@ func TestIntegrationDashboardSnapshotsService(t *testing.T) {
 	}
 	sqlStore := db.InitTestDB(t)
 	cfg := setting.NewCfg()
-	dsStore := dashsnapdb.ProvideStore(sqlStore, cfg)
+	dsStore, err := dashsnapdb.ProvideStore(sqlStore, cfg)
+	require.NoError(t, err)
 	fakeDashboardService := &dashboards.FakeDashboardService{}
 	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
-	s := ProvideService(dsStore, secretsService, fakeDashboardService)
+	s := ProvideService(cfg, dsStore, secretsService, fakeDashboardService)
 
 	origSecret := cfg.SecretKey
 	cfg.SecretKey = "dashboard_snapshot_service_test"
@@ -44,7 +46,7 @@ func TestIntegrationDashboardSnapshotsService(t *testing.T) {
 
 	dashboard := &common.Unstructured{}
 	rawDashboard := []byte(`{"id":123}`)
-	err := json.Unmarshal(rawDashboard, dashboard)
+	err = json.Unmarshal(rawDashboard, dashboard)
 	require.NoError(t, err)
 
 	t.Run("create dashboard snapshot should encrypt the dashboard", func(t *testing.T) {
@@ -82,4 +84,74 @@ func TestIntegrationDashboardSnapshotsService(t *testing.T) {
 
 		require.Equal(t, rawDashboard, decrypted)
 	})
+	t.Run("create dashboard snapshot should fail when the dashboard is too large", func(t *testing.T) {
+		cfg.SnapshotMaxSize = 5
+		t.Cleanup(func() {
+			cfg.SnapshotMaxSize = 0
+		})
+
+		cmd := dashboardsnapshots.CreateDashboardSnapshotCommand{
+			Key:       "too-large",
+			DeleteKey: "too-large",
+			DashboardCreateCommand: dashboardsnapshot.DashboardCreateCommand{
+				Dashboard: dashboard,
+			},
+		}
+
+		_, err := s.CreateDashboardSnapshot(context.Background(), &cmd)
+		require.ErrorIs(t, err, dashboardsnapshots.ErrTooLarge)
+	})
+}
+
+func TestDeleteExpiredSnapshots(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	setup := func(cfg *setting.Cfg) (*ServiceImpl, *fakeStore) {
+		store := &fakeStore{}
+		s := ProvideService(cfg, store, nil, nil)
+		s.now = func() time.Time { return now }
+		return s, store
+	}
+
+	t.Run("should only delete the expired snapshots without max age", func(t *testing.T) {
+		s, store := setup(setting.NewCfg())
+
+		cmd := &dashboardsnapshots.DeleteExpiredSnapshotsCommand{}
+		require.NoError(t, s.DeleteExpiredSnapshots(context.Background(), cmd))
+		require.EqualValues(t, 2, cmd.DeletedRows)
+		require.Empty(t, store.deleted)
+	})
+
+	t.Run("should apply the retention policies and the max age to the other organizations", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.SnapshotMaxAge = 30 * 24 * time.Hour
+		cfg.SnapshotRetentionPolicies = []setting.SnapshotRetentionPolicy{
+			{Name: "short", OrgID: 2, MaxAge: 24 * time.Hour},
+			{Name: "forever", OrgID: 3},
+		}
+		s, store := setup(cfg)
+
+		cmd := &dashboardsnapshots.DeleteExpiredSnapshotsCommand{}
+		require.NoError(t, s.DeleteExpiredSnapshots(context.Background(), cmd))
+		require.EqualValues(t, 2+1+1, cmd.DeletedRows)
+		require.Equal(t, []dashboardsnapshots.SnapshotsFilter{
+			{OrgID: 2, CreatedBefore: now.Add(-24 * time.Hour)},
+			{ExcludeOrgIDs: []int64{2, 3}, CreatedBefore: now.Add(-30 * 24 * time.Hour)},
+		}, store.deleted)
+	})
+}
+
+type fakeStore struct {
+	dashboardsnapshots.Store
+	deleted []dashboardsnapshots.SnapshotsFilter
+}
+
+func (f *fakeStore) DeleteExpiredSnapshots(_ context.Context, cmd *dashboardsnapshots.DeleteExpiredSnapshotsCommand) error {
+	cmd.DeletedRows = 2
+	return nil
+}
+
+func (f *fakeStore) DeleteSnapshots(_ context.Context, cmd *dashboardsnapshots.DeleteSnapshotsCommand) error {
+	f.deleted = append(f.deleted, cmd.SnapshotsFilter)
+	cmd.DeletedRows = 1
+	return nil
 }
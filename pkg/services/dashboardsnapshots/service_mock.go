@@ -6,6 +6,8 @@ import (
 	context "context"
 
 	mock "github.com/stretchr/testify/mock"
+
+	v0alpha1 "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
 )
 
 // MockService is an autogenerated mock type for the Service type
@@ -79,6 +81,54 @@ func (_m *MockService) DeleteExpiredSnapshots(_a0 context.Context, _a1 *DeleteEx
 	return r0
 }
 
+// DeleteSnapshots provides a mock function with given fields: _a0, _a1
+func (_m *MockService) DeleteSnapshots(_a0 context.Context, _a1 *DeleteSnapshotsCommand) error {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSnapshots")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *DeleteSnapshotsCommand) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindSnapshots provides a mock function with given fields: _a0, _a1
+func (_m *MockService) FindSnapshots(_a0 context.Context, _a1 *FindSnapshotsQuery) (*FindSnapshotsResult, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindSnapshots")
+	}
+
+	var r0 *FindSnapshotsResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *FindSnapshotsQuery) (*FindSnapshotsResult, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *FindSnapshotsQuery) *FindSnapshotsResult); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*FindSnapshotsResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *FindSnapshotsQuery) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDashboardSnapshot provides a mock function with given fields: _a0, _a1
 func (_m *MockService) GetDashboardSnapshot(_a0 context.Context, _a1 *GetDashboardSnapshotQuery) (*DashboardSnapshot, error) {
 	ret := _m.Called(_a0, _a1)
@@ -109,6 +159,26 @@ func (_m *MockService) GetDashboardSnapshot(_a0 context.Context, _a1 *GetDashboa
 	return r0, r1
 }
 
+// SanitizeDashboard provides a mock function with given fields: _a0
+func (_m *MockService) SanitizeDashboard(_a0 *v0alpha1.Unstructured) []SanitizedValue {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SanitizeDashboard")
+	}
+
+	var r0 []SanitizedValue
+	if rf, ok := ret.Get(0).(func(*v0alpha1.Unstructured) []SanitizedValue); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]SanitizedValue)
+		}
+	}
+
+	return r0
+}
+
 // SearchDashboardSnapshots provides a mock function with given fields: _a0, _a1
 func (_m *MockService) SearchDashboardSnapshots(_a0 context.Context, _a1 *GetDashboardSnapshotsQuery) (DashboardSnapshotsList, error) {
 	ret := _m.Called(_a0, _a1)
@@ -8,6 +8,8 @@ type Store interface {
 	CreateDashboardSnapshot(context.Context, *CreateDashboardSnapshotCommand) (*DashboardSnapshot, error)
 	DeleteDashboardSnapshot(context.Context, *DeleteDashboardSnapshotCommand) error
 	DeleteExpiredSnapshots(context.Context, *DeleteExpiredSnapshotsCommand) error
+	DeleteSnapshots(context.Context, *DeleteSnapshotsCommand) error
+	FindSnapshots(context.Context, *FindSnapshotsQuery) (*FindSnapshotsResult, error)
 	GetDashboardSnapshot(context.Context, *GetDashboardSnapshotQuery) (*DashboardSnapshot, error)
 	SearchDashboardSnapshots(context.Context, *GetDashboardSnapshotsQuery) (DashboardSnapshotsList, error)
 }
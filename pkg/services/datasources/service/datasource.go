@@ -28,6 +28,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/secretaccess"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/secrets/kvstore"
 	"github.com/grafana/grafana/pkg/setting"
@@ -51,6 +52,8 @@ type Service struct {
 	pluginStore               pluginstore.Store
 	pluginClient              plugins.Client
 	basePluginContextProvider plugincontext.BasePluginContextProvider
+	secretAccess              secretaccess.Service
+	secretReferences          *secretReferences
 
 	ptc proxyTransportCache
 }
@@ -63,16 +66,22 @@ type proxyTransportCache struct {
 type cachedRoundTripper struct {
 	updated      time.Time
 	roundTripper http.RoundTripper
+	// expires is when the referenced secrets may have been rotated, zero when the data source has no references
+	expires time.Time
 }
 
 func ProvideService(
 	db db.DB, secretsService secrets.Service, secretsStore kvstore.SecretsKVStore, cfg *setting.Cfg,
 	features featuremgmt.FeatureToggles, ac accesscontrol.AccessControl, datasourcePermissionsService accesscontrol.DatasourcePermissionsService,
 	quotaService quota.Service, pluginStore pluginstore.Store, pluginClient plugins.Client,
-	basePluginContextProvider plugincontext.BasePluginContextProvider,
+	basePluginContextProvider plugincontext.BasePluginContextProvider, secretAccess secretaccess.Service,
 ) (*Service, error) {
 	dslogger := log.New("datasources")
 	store := &SqlStore{db: db, logger: dslogger, features: features}
+	secretReferenceCacheTTL := time.Duration(0)
+	if cfg != nil {
+		secretReferenceCacheTTL = cfg.DataSourceSecretReferenceCacheTTL
+	}
 	s := &Service{
 		SQLStore:       store,
 		SecretsStore:   secretsStore,
@@ -89,10 +98,13 @@ func ProvideService(
 		pluginStore:               pluginStore,
 		pluginClient:              pluginClient,
 		basePluginContextProvider: basePluginContextProvider,
+		secretAccess:              secretAccess,
+		secretReferences:          newSecretReferences(secretReferenceCacheTTL, dslogger),
 	}
 
 	ac.RegisterScopeAttributeResolver(NewNameScopeResolver(store))
 	ac.RegisterScopeAttributeResolver(NewIDScopeResolver(store))
+	ac.RegisterScopeAttributeResolver(NewUIDScopeResolver(store))
 
 	defaultLimits, err := readQuotaConfig(cfg)
 	if err != nil {
@@ -106,6 +118,8 @@ func ProvideService(
 	}); err != nil {
 		return nil, err
 	}
+
+	secretAccess.RegisterSource(secretaccess.KindDataSource, s.listSecrets)
 	return s, nil
 }
 
@@ -120,7 +134,7 @@ type DataSourceRetriever interface {
 }
 
 // NewNameScopeResolver provides an ScopeAttributeResolver able to
-// translate a scope prefixed with "datasources:name:" into an uid based scope.
+// translate a scope prefixed with "datasources:name:" into an uid based scope and the type condition scope.
 func NewNameScopeResolver(db DataSourceRetriever) (string, accesscontrol.ScopeAttributeResolver) {
 	prefix := datasources.ScopeProvider.GetResourceScopeName("")
 	return prefix, accesscontrol.ScopeAttributeResolverFunc(func(ctx context.Context, orgID int64, initialScope string) ([]string, error) {
@@ -139,12 +153,12 @@ func NewNameScopeResolver(db DataSourceRetriever) (string, accesscontrol.ScopeAt
 			return nil, err
 		}
 
-		return []string{datasources.ScopeProvider.GetResourceScopeUID(dataSource.UID)}, nil
+		return resolveDataSourceScopes(dataSource), nil
 	})
 }
 
 // NewIDScopeResolver provides an ScopeAttributeResolver able to
-// translate a scope prefixed with "datasources:id:" into an uid based scope.
+// translate a scope prefixed with "datasources:id:" into an uid based scope and the type condition scope.
 func NewIDScopeResolver(db DataSourceRetriever) (string, accesscontrol.ScopeAttributeResolver) {
 	prefix := datasources.ScopeProvider.GetResourceScope("")
 	return prefix, accesscontrol.ScopeAttributeResolverFunc(func(ctx context.Context, orgID int64, initialScope string) ([]string, error) {
@@ -168,10 +182,46 @@ func NewIDScopeResolver(db DataSourceRetriever) (string, accesscontrol.ScopeAttr
 			return nil, err
 		}
 
-		return []string{datasources.ScopeProvider.GetResourceScopeUID(dataSource.UID)}, nil
+		return resolveDataSourceScopes(dataSource), nil
+	})
+}
+
+// NewUIDScopeResolver provides an ScopeAttributeResolver able to
+// add the type condition scope of the data source to a scope prefixed with "datasources:uid:".
+func NewUIDScopeResolver(db DataSourceRetriever) (string, accesscontrol.ScopeAttributeResolver) {
+	prefix := datasources.ScopeProvider.GetResourceScopeUID("")
+	return prefix, accesscontrol.ScopeAttributeResolverFunc(func(ctx context.Context, orgID int64, initialScope string) ([]string, error) {
+		if !strings.HasPrefix(initialScope, prefix) {
+			return nil, accesscontrol.ErrInvalidScope
+		}
+
+		uid := initialScope[len(prefix):]
+		if uid == "" {
+			return nil, accesscontrol.ErrInvalidScope
+		}
+
+		query := datasources.GetDataSourceQuery{UID: uid, OrgID: orgID}
+		dataSource, err := db.GetDataSource(ctx, &query)
+		if err != nil {
+			// Keep the scope unchanged, access to an unknown data source is denied as before the resolver existed
+			if errors.Is(err, datasources.ErrDataSourceNotFound) {
+				return []string{initialScope}, nil
+			}
+			return nil, err
+		}
+
+		return resolveDataSourceScopes(dataSource), nil
 	})
 }
 
+// resolveDataSourceScopes returns the uid scope of the data source followed by its condition scopes
+func resolveDataSourceScopes(dataSource *datasources.DataSource) []string {
+	return append(
+		[]string{datasources.ScopeProvider.GetResourceScopeUID(dataSource.UID)},
+		accesscontrol.ConditionScopes(datasources.ScopeRoot, accesscontrol.ConditionAttributeType, dataSource.Type)...,
+	)
+}
+
 func (s *Service) GetDataSource(ctx context.Context, query *datasources.GetDataSourceQuery) (*datasources.DataSource, error) {
 	return s.SQLStore.GetDataSource(ctx, query)
 }
@@ -594,7 +644,8 @@ func (s *Service) GetHTTPTransport(ctx context.Context, ds *datasources.DataSour
 	s.ptc.Lock()
 	defer s.ptc.Unlock()
 
-	if t, present := s.ptc.cache[ds.ID]; present && ds.Updated.Equal(t.updated) {
+	if t, present := s.ptc.cache[ds.ID]; present && ds.Updated.Equal(t.updated) &&
+		(t.expires.IsZero() || time.Now().Before(t.expires)) {
 		return t.roundTripper, nil
 	}
 
@@ -613,12 +664,33 @@ func (s *Service) GetHTTPTransport(ctx context.Context, ds *datasources.DataSour
 	s.ptc.cache[ds.ID] = cachedRoundTripper{
 		roundTripper: rt,
 		updated:      ds.Updated,
+		expires:      s.secretReferences.transportExpiry(ds.ID),
 	}
 
 	return rt, nil
 }
 
 func (s *Service) DecryptedValues(ctx context.Context, ds *datasources.DataSource) (map[string]string, error) {
+	decryptedValues, err := s.decryptStoredValues(ctx, ds)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.secretReferences.resolve(ctx, ds, decryptedValues); err != nil {
+		return nil, err
+	}
+
+	if len(decryptedValues) > 0 {
+		s.secretAccess.Record(ctx, secretaccess.Access{
+			Secret:  secretaccess.Secret{OrgID: ds.OrgID, Kind: secretaccess.KindDataSource, UID: ds.UID, Name: ds.Name},
+			Service: "datasources",
+		})
+	}
+	return decryptedValues, nil
+}
+
+// decryptStoredValues returns the secure settings as they are stored, with the secret references unresolved
+func (s *Service) decryptStoredValues(ctx context.Context, ds *datasources.DataSource) (map[string]string, error) {
 	decryptedValues := make(map[string]string)
 	secret, exist, err := s.SecretsStore.Get(ctx, ds.OrgID, ds.Name, kvstore.DataSourceSecretType)
 	if err != nil {
@@ -642,6 +714,32 @@ func (s *Service) DecryptedValues(ctx context.Context, ds *datasources.DataSourc
 	return decryptedValues, nil
 }
 
+// listSecrets returns the data sources of all organizations which have secure settings
+func (s *Service) listSecrets(ctx context.Context) ([]*secretaccess.Secret, error) {
+	dataSources, err := s.SQLStore.GetAllDataSources(ctx, &datasources.GetAllDataSourcesQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make([]*secretaccess.Secret, 0)
+	for _, ds := range dataSources {
+		hasSecrets := len(ds.SecureJsonData) > 0
+		if !hasSecrets {
+			// the secrets are stored even when empty, so their value has to be checked
+			secret, exist, err := s.SecretsStore.Get(ctx, ds.OrgID, ds.Name, kvstore.DataSourceSecretType)
+			if err != nil {
+				return nil, err
+			}
+			values := make(map[string]string)
+			hasSecrets = exist && json.Unmarshal([]byte(secret), &values) == nil && len(values) > 0
+		}
+		if hasSecrets {
+			secrets = append(secrets, &secretaccess.Secret{OrgID: ds.OrgID, Kind: secretaccess.KindDataSource, UID: ds.UID, Name: ds.Name})
+		}
+	}
+	return secrets, nil
+}
+
 func (s *Service) decryptLegacySecrets(ctx context.Context, ds *datasources.DataSource) (map[string]string, error) {
 	secureJsonData := make(map[string]string)
 	for k, v := range ds.SecureJsonData {
@@ -931,7 +1029,8 @@ func awsServiceNamespace(dsType string, jsonData *simplejson.Json) string {
 }
 
 func (s *Service) fillWithSecureJSONData(ctx context.Context, cmd *datasources.UpdateDataSourceCommand, ds *datasources.DataSource) error {
-	decrypted, err := s.DecryptedValues(ctx, ds)
+	// the secret references are kept, not the secrets they resolve to
+	decrypted, err := s.decryptStoredValues(ctx, ds)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+// secretReferenceRegex matches the secure settings which reference a secret of an external secret manager
+// instead of storing it, like $__vault{kv/data/prometheus#password} or $__awssm{prod/prometheus#password}
+var secretReferenceRegex = regexp.MustCompile(`^\$__(\w+)\{(.+)\}$`)
+
+// secretReferences resolves the secret references of the secure settings when they are decrypted, so the
+// secrets rotated in the secret manager are used without updating the data sources. The secrets are cached
+// for the ttl, and the last secret is used while the secret manager can't be reached.
+type secretReferences struct {
+	ttl    time.Duration
+	now    func() time.Time
+	logger log.Logger
+
+	mu      sync.Mutex
+	secrets map[string]cachedSecret
+	// dataSources are the ids of the data sources with references, their HTTP transport expires with the secrets
+	dataSources map[int64]bool
+}
+
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+func newSecretReferences(ttl time.Duration, logger log.Logger) *secretReferences {
+	return &secretReferences{
+		ttl:         ttl,
+		now:         time.Now,
+		logger:      logger,
+		secrets:     map[string]cachedSecret{},
+		dataSources: map[int64]bool{},
+	}
+}
+
+// resolve replaces the references of the decrypted secure settings of the data source by the secrets
+func (r *secretReferences) resolve(ctx context.Context, ds *datasources.DataSource, decrypted map[string]string) error {
+	hasReferences := false
+	for key, value := range decrypted {
+		match := secretReferenceRegex.FindStringSubmatch(value)
+		if match == nil {
+			continue
+		}
+		// a value which looks like a reference of a secret manager which isn't configured is a secret
+		resolver, ok := values.GetSecretResolver(match[1])
+		if !ok {
+			continue
+		}
+
+		secret, err := r.get(ctx, value, func(ctx context.Context) (string, error) {
+			return resolver.Resolve(ctx, match[2])
+		})
+		if err != nil {
+			return fmt.Errorf("failed to resolve the secret reference of %s: %w", key, err)
+		}
+		decrypted[key] = secret
+		hasReferences = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if hasReferences {
+		r.dataSources[ds.ID] = true
+	} else {
+		delete(r.dataSources, ds.ID)
+	}
+	return nil
+}
+
+func (r *secretReferences) get(ctx context.Context, ref string, resolve func(ctx context.Context) (string, error)) (string, error) {
+	r.mu.Lock()
+	cached, ok := r.secrets[ref]
+	r.mu.Unlock()
+	if ok && r.now().Before(cached.expires) {
+		return cached.value, nil
+	}
+
+	secret, err := resolve(ctx)
+	if err != nil {
+		if !ok {
+			return "", err
+		}
+		r.logger.Warn("Failed to resolve secret reference, using the last resolved secret", "reference", redactReference(ref), "error", err)
+		return cached.value, nil
+	}
+
+	r.mu.Lock()
+	r.secrets[ref] = cachedSecret{value: secret, expires: r.now().Add(r.ttl)}
+	r.mu.Unlock()
+	return secret, nil
+}
+
+// transportExpiry returns when the HTTP transport of the data source has to be created again to use the
+// rotated secrets, or the zero time when the data source doesn't reference secrets
+func (r *secretReferences) transportExpiry(dsID int64) time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.dataSources[dsID] {
+		return time.Time{}
+	}
+	return r.now().Add(r.ttl)
+}
+
+// redactReference returns the reference without the key of the secret
+func redactReference(ref string) string {
+	if path, _, ok := strings.Cut(ref, "#"); ok {
+		return path + "}"
+	}
+	return ref
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+type fakeSecretResolver struct {
+	secrets map[string]string
+	err     error
+	calls   int
+}
+
+func (f *fakeSecretResolver) Resolve(_ context.Context, ref string) (string, error) {
+	f.calls++
+	if f.err != nil {
+		return "", f.err
+	}
+	secret, ok := f.secrets[ref]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+	return secret, nil
+}
+
+func TestSecretReferences_resolve(t *testing.T) {
+	ctx := context.Background()
+	ds := &datasources.DataSource{ID: 1}
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	setup := func(t *testing.T) (*secretReferences, *fakeSecretResolver) {
+		resolver := &fakeSecretResolver{secrets: map[string]string{"kv/data/prometheus#password": "rotated"}}
+		values.RegisterSecretResolver("fake", resolver)
+		t.Cleanup(func() { values.UnregisterSecretResolver("fake") })
+
+		refs := newSecretReferences(5*time.Minute, log.NewNopLogger())
+		refs.now = func() time.Time { return now }
+		return refs, resolver
+	}
+
+	t.Run("should replace the references by the secrets", func(t *testing.T) {
+		refs, _ := setup(t)
+
+		decrypted := map[string]string{
+			"password":          "$__fake{kv/data/prometheus#password}",
+			"basicAuthPassword": "plain",
+			"token":             "$__unknown{kv/data/prometheus#password}",
+		}
+		require.NoError(t, refs.resolve(ctx, ds, decrypted))
+		assert.Equal(t, map[string]string{
+			"password":          "rotated",
+			"basicAuthPassword": "plain",
+			"token":             "$__unknown{kv/data/prometheus#password}",
+		}, decrypted)
+		assert.Equal(t, now.Add(5*time.Minute), refs.transportExpiry(ds.ID))
+		assert.True(t, refs.transportExpiry(2).IsZero())
+	})
+
+	t.Run("should cache the secrets for the ttl", func(t *testing.T) {
+		refs, resolver := setup(t)
+		resolve := func() string {
+			decrypted := map[string]string{"password": "$__fake{kv/data/prometheus#password}"}
+			require.NoError(t, refs.resolve(ctx, ds, decrypted))
+			return decrypted["password"]
+		}
+
+		assert.Equal(t, "rotated", resolve())
+		assert.Equal(t, "rotated", resolve())
+		assert.Equal(t, 1, resolver.calls)
+
+		now = now.Add(6 * time.Minute)
+		resolver.secrets["kv/data/prometheus#password"] = "rotated-again"
+		assert.Equal(t, "rotated-again", resolve())
+		assert.Equal(t, 2, resolver.calls)
+
+		now = now.Add(6 * time.Minute)
+		resolver.err = errors.New("vault is sealed")
+		assert.Equal(t, "rotated-again", resolve(), "the last secret is used when the secret manager fails")
+	})
+
+	t.Run("should fail when a secret can't be resolved", func(t *testing.T) {
+		refs, _ := setup(t)
+
+		err := refs.resolve(ctx, ds, map[string]string{"password": "$__fake{kv/data/missing#password}"})
+		assert.ErrorContains(t, err, "failed to resolve the secret reference of password")
+	})
+}
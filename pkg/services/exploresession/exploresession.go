@@ -0,0 +1,120 @@
+package exploresession
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var (
+	ErrNotFound = errutil.NotFound(
+		"exploresession.not-found", errutil.WithPublicMessage("Explore session not found"))
+	ErrNameRequired = errutil.BadRequest(
+		"exploresession.name-required", errutil.WithPublicMessage("Explore session name is required"))
+	ErrNameTooLong = errutil.BadRequest(
+		"exploresession.name-too-long", errutil.WithPublicMessage("Explore session name must be at most 190 characters"))
+	ErrInvalidState = errutil.BadRequest(
+		"exploresession.invalid-state", errutil.WithPublicMessage("Invalid Explore session, expected 1 to 10 panes each with a data source, queries and a time range"))
+	ErrStateTooLarge = errutil.BadRequest(
+		"exploresession.state-too-large", errutil.WithPublicMessage("Explore session is too large"))
+	ErrUnsupportedVersion = errutil.BadRequest(
+		"exploresession.unsupported-version", errutil.WithPublicMessage("Explore session was saved by a newer version of Grafana"))
+	ErrNotOwner = errutil.Forbidden(
+		"exploresession.not-owner", errutil.WithPublicMessage("Only the owner of the Explore session can change it"))
+)
+
+// SchemaVersion is the version of the session state written by this version of Grafana. The sessions saved with
+// older versions are migrated when they are read.
+const SchemaVersion = 1
+
+type Service interface {
+	CreateSession(ctx context.Context, cmd *CreateSessionCommand) (*Session, error)
+	// UpdateSession replaces the name, the sharing and the state of a session of the user
+	UpdateSession(ctx context.Context, cmd *UpdateSessionCommand) (*Session, error)
+	// DeleteSession deletes a session of the user, org admins can also delete the shared sessions
+	DeleteSession(ctx context.Context, cmd *DeleteSessionCommand) error
+	// GetSession returns a session of the user or a session shared with the org
+	GetSession(ctx context.Context, query *GetSessionQuery) (*Session, error)
+	// ListSessions returns the sessions of the user and the sessions shared with the org, without their state
+	ListSessions(ctx context.Context, query *ListSessionsQuery) ([]*Session, error)
+}
+
+// Session is a named state of Explore which can be shared with the org
+type Session struct {
+	UID    string `json:"uid"`
+	OrgID  int64  `json:"orgId"`
+	Name   string `json:"name"`
+	Shared bool   `json:"shared"`
+	// State is omitted from the listed sessions
+	State *State `json:"state,omitempty"`
+	// URL is the permalink restoring the session in Explore
+	URL       string    `json:"url"`
+	CreatedBy int64     `json:"createdBy"`
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+}
+
+// State is the state of the panes of Explore
+type State struct {
+	// SchemaVersion is the version of the state, the current version when it isn't set
+	SchemaVersion int    `json:"schemaVersion"`
+	Panes         []Pane `json:"panes"`
+}
+
+// Pane is a pane of Explore, the panes are ordered from left to right
+type Pane struct {
+	DatasourceUID string             `json:"datasourceUid"`
+	Queries       []*simplejson.Json `json:"queries"`
+	Range         TimeRange          `json:"range"`
+	// PanelsState is the state of the visualizations of the pane, such as the opened trace or the logs options
+	PanelsState *simplejson.Json `json:"panelsState,omitempty"`
+	Compact     bool             `json:"compact,omitempty"`
+}
+
+// TimeRange is the time range of a pane, absolute or relative such as now-1h
+type TimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type CreateSessionCommand struct {
+	OrgID  int64  `json:"-"`
+	UserID int64  `json:"-"`
+	Name   string `json:"name"`
+	Shared bool   `json:"shared"`
+	State  State  `json:"state"`
+}
+
+type UpdateSessionCommand struct {
+	UID    string `json:"-"`
+	OrgID  int64  `json:"-"`
+	UserID int64  `json:"-"`
+	Name   string `json:"name"`
+	Shared bool   `json:"shared"`
+	State  State  `json:"state"`
+}
+
+type DeleteSessionCommand struct {
+	UID        string
+	OrgID      int64
+	UserID     int64
+	IsOrgAdmin bool
+}
+
+type GetSessionQuery struct {
+	UID    string
+	OrgID  int64
+	UserID int64
+}
+
+type ListSessionsQuery struct {
+	OrgID  int64
+	UserID int64
+	// Query filters the sessions by name
+	Query string
+	// SharedOnly excludes the sessions of the user which aren't shared
+	SharedOnly bool
+	Limit      int
+}
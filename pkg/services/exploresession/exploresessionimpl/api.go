@@ -0,0 +1,206 @@
+package exploresessionimpl
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/exploresession"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+	router.Group("/api/explore-sessions", func(sessionRoute routing.RouteRegister) {
+		sessionRoute.Get("/", routing.Wrap(s.listSessions))
+		sessionRoute.Post("/", routing.Wrap(s.createSession))
+		sessionRoute.Get("/:uid", routing.Wrap(s.getSession))
+		sessionRoute.Put("/:uid", routing.Wrap(s.updateSession))
+		sessionRoute.Delete("/:uid", routing.Wrap(s.deleteSession))
+	}, middleware.ReqSignedIn, authorize(ac.EvalPermission(ac.ActionDatasourcesExplore)))
+}
+
+// swagger:route GET /explore-sessions explore_sessions listExploreSessions
+//
+// # List Explore sessions
+//
+// Returns the Explore sessions of the signed in user and the sessions shared with the organization, most recently
+// updated first. The state of the sessions is omitted.
+//
+// Responses:
+// 200: listExploreSessionsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) listSessions(c *contextmodel.ReqContext) response.Response {
+	sessions, err := s.ListSessions(c.Req.Context(), &exploresession.ListSessionsQuery{
+		OrgID:      c.GetOrgID(),
+		UserID:     c.SignedInUser.UserID,
+		Query:      c.Query("query"),
+		SharedOnly: c.QueryBool("sharedOnly"),
+		Limit:      c.QueryInt("limit"),
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list Explore sessions", err)
+	}
+	return response.JSON(http.StatusOK, sessions)
+}
+
+// swagger:route POST /explore-sessions explore_sessions createExploreSession
+//
+// # Create an Explore session
+//
+// Saves the panes of Explore, with their data sources, queries and time ranges. The session is owned by the signed in
+// user, and can be opened by all the users of the organization when shared is set.
+//
+// Responses:
+// 200: exploreSessionResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) createSession(c *contextmodel.ReqContext) response.Response {
+	cmd := exploresession.CreateSessionCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.GetOrgID()
+	cmd.UserID = c.SignedInUser.UserID
+
+	result, err := s.CreateSession(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create Explore session", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route GET /explore-sessions/{uid} explore_sessions getExploreSession
+//
+// # Get an Explore session
+//
+// Returns the session with its state, migrated to the current schema version, to restore it in Explore.
+//
+// Responses:
+// 200: exploreSessionResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) getSession(c *contextmodel.ReqContext) response.Response {
+	result, err := s.GetSession(c.Req.Context(), &exploresession.GetSessionQuery{
+		UID:    web.Params(c.Req)[":uid"],
+		OrgID:  c.GetOrgID(),
+		UserID: c.SignedInUser.UserID,
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get Explore session", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route PUT /explore-sessions/{uid} explore_sessions updateExploreSession
+//
+// # Update an Explore session
+//
+// Replaces the name, the sharing and the state of a session of the signed in user.
+//
+// Responses:
+// 200: exploreSessionResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) updateSession(c *contextmodel.ReqContext) response.Response {
+	cmd := exploresession.UpdateSessionCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.UID = web.Params(c.Req)[":uid"]
+	cmd.OrgID = c.GetOrgID()
+	cmd.UserID = c.SignedInUser.UserID
+
+	result, err := s.UpdateSession(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update Explore session", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route DELETE /explore-sessions/{uid} explore_sessions deleteExploreSession
+//
+// # Delete an Explore session
+//
+// Deletes a session of the signed in user. Organization admins can also delete the shared sessions.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) deleteSession(c *contextmodel.ReqContext) response.Response {
+	err := s.DeleteSession(c.Req.Context(), &exploresession.DeleteSessionCommand{
+		UID:        web.Params(c.Req)[":uid"],
+		OrgID:      c.GetOrgID(),
+		UserID:     c.SignedInUser.UserID,
+		IsOrgAdmin: c.SignedInUser.HasRole(org.RoleAdmin),
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete Explore session", err)
+	}
+	return response.Success("Explore session deleted")
+}
+
+// swagger:parameters listExploreSessions
+type ListExploreSessionsParams struct {
+	// Filters the sessions by name
+	// in:query
+	// required:false
+	Query string `json:"query"`
+	// Only returns the shared sessions
+	// in:query
+	// required:false
+	SharedOnly bool `json:"sharedOnly"`
+	// in:query
+	// required:false
+	// default:100
+	Limit int64 `json:"limit"`
+}
+
+// swagger:parameters getExploreSession updateExploreSession deleteExploreSession
+type ExploreSessionUIDParam struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters createExploreSession
+type CreateExploreSessionParams struct {
+	// in:body
+	// required:true
+	Body exploresession.CreateSessionCommand `json:"body"`
+}
+
+// swagger:parameters updateExploreSession
+type UpdateExploreSessionParams struct {
+	// in:body
+	// required:true
+	Body exploresession.UpdateSessionCommand `json:"body"`
+}
+
+// swagger:response exploreSessionResponse
+type ExploreSessionResponse struct {
+	// in:body
+	Body *exploresession.Session `json:"body"`
+}
+
+// swagger:response listExploreSessionsResponse
+type ListExploreSessionsResponse struct {
+	// in:body
+	Body []*exploresession.Session `json:"body"`
+}
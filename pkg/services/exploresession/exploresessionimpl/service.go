@@ -0,0 +1,239 @@
+package exploresessionimpl
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/exploresession"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var _ exploresession.Service = (*Service)(nil)
+
+const (
+	maxNameLength = 190
+	maxPanes      = 10
+	// maxStateBytes bounds the size of the JSON state of a session
+	maxStateBytes    = 1 << 20
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, router routing.RouteRegister, accessControl ac.AccessControl) *Service {
+	s := &Service{
+		cfg:   cfg,
+		store: &xormStore{db: sqlStore},
+		now:   time.Now,
+	}
+
+	// Register routes only when Explore is enabled
+	if cfg.ExploreEnabled {
+		s.registerRoutes(router, accessControl)
+	}
+	return s
+}
+
+// Service stores the Explore sessions, which are restored from their permalink instead of from a URL encoding
+// the whole state of Explore.
+type Service struct {
+	cfg   *setting.Cfg
+	store store
+	now   func() time.Time
+}
+
+func (s *Service) CreateSession(ctx context.Context, cmd *exploresession.CreateSessionCommand) (*exploresession.Session, error) {
+	state, err := validate(cmd.Name, &cmd.State)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	row := &exploreSession{
+		UID:           util.GenerateShortUID(),
+		OrgID:         cmd.OrgID,
+		Name:          strings.TrimSpace(cmd.Name),
+		Shared:        cmd.Shared,
+		SchemaVersion: exploresession.SchemaVersion,
+		State:         state,
+		CreatedBy:     cmd.UserID,
+		Created:       now,
+		Updated:       now,
+	}
+	if err := s.store.Insert(ctx, row); err != nil {
+		return nil, err
+	}
+	return s.toDTO(row, true)
+}
+
+func (s *Service) UpdateSession(ctx context.Context, cmd *exploresession.UpdateSessionCommand) (*exploresession.Session, error) {
+	state, err := validate(cmd.Name, &cmd.State)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := s.get(ctx, cmd.OrgID, cmd.UID, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if row.CreatedBy != cmd.UserID {
+		return nil, exploresession.ErrNotOwner.Errorf("user %d doesn't own explore session %s", cmd.UserID, cmd.UID)
+	}
+
+	row.Name = strings.TrimSpace(cmd.Name)
+	row.Shared = cmd.Shared
+	row.SchemaVersion = exploresession.SchemaVersion
+	row.State = state
+	row.Updated = s.now()
+	if err := s.store.Update(ctx, row); err != nil {
+		return nil, err
+	}
+	return s.toDTO(row, true)
+}
+
+func (s *Service) DeleteSession(ctx context.Context, cmd *exploresession.DeleteSessionCommand) error {
+	row, err := s.get(ctx, cmd.OrgID, cmd.UID, cmd.UserID)
+	if err != nil {
+		return err
+	}
+	if row.CreatedBy != cmd.UserID && !(row.Shared && cmd.IsOrgAdmin) {
+		return exploresession.ErrNotOwner.Errorf("user %d doesn't own explore session %s", cmd.UserID, cmd.UID)
+	}
+	return s.store.Delete(ctx, cmd.OrgID, cmd.UID)
+}
+
+func (s *Service) GetSession(ctx context.Context, query *exploresession.GetSessionQuery) (*exploresession.Session, error) {
+	row, err := s.get(ctx, query.OrgID, query.UID, query.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toDTO(row, true)
+}
+
+func (s *Service) ListSessions(ctx context.Context, query *exploresession.ListSessionsQuery) ([]*exploresession.Session, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultListLimit
+	}
+	if query.Limit > maxListLimit {
+		query.Limit = maxListLimit
+	}
+
+	rows, err := s.store.List(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*exploresession.Session, 0, len(rows))
+	for _, row := range rows {
+		dto, err := s.toDTO(row, false)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, dto)
+	}
+	return sessions, nil
+}
+
+// get returns the session when it is owned by the user or shared, the other sessions aren't found
+func (s *Service) get(ctx context.Context, orgID int64, uid string, userID int64) (*exploreSession, error) {
+	row, err := s.store.Get(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if !row.Shared && row.CreatedBy != userID {
+		return nil, exploresession.ErrNotFound.Errorf("explore session %s not found", uid)
+	}
+	return row, nil
+}
+
+// validate checks the name and the state of a session, and returns the state to store
+func validate(name string, state *exploresession.State) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", exploresession.ErrNameRequired.Errorf("explore session name is empty")
+	}
+	if utf8.RuneCountInString(name) > maxNameLength {
+		return "", exploresession.ErrNameTooLong.Errorf("explore session name is longer than %d characters", maxNameLength)
+	}
+
+	if state.SchemaVersion > exploresession.SchemaVersion {
+		return "", exploresession.ErrUnsupportedVersion.Errorf("unsupported explore session version %d", state.SchemaVersion)
+	}
+	if state.SchemaVersion == 0 {
+		state.SchemaVersion = exploresession.SchemaVersion
+	}
+	if err := migrateState(state); err != nil {
+		return "", err
+	}
+
+	if len(state.Panes) == 0 || len(state.Panes) > maxPanes {
+		return "", exploresession.ErrInvalidState.Errorf("explore session has %d panes", len(state.Panes))
+	}
+	for i, pane := range state.Panes {
+		if pane.DatasourceUID == "" || len(pane.Queries) == 0 || pane.Range.From == "" || pane.Range.To == "" {
+			return "", exploresession.ErrInvalidState.Errorf("pane %d of the explore session is incomplete", i)
+		}
+	}
+
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	if len(encoded) > maxStateBytes {
+		return "", exploresession.ErrStateTooLarge.Errorf("explore session state is %d bytes", len(encoded))
+	}
+	return string(encoded), nil
+}
+
+// stateMigrations upgrade a state from the version of their key to the next version, a migration is added when the
+// schema of the state changes
+var stateMigrations = map[int]func(state *exploresession.State) error{}
+
+// migrateState upgrades a state saved by an older version of Grafana to the current schema version, so the sessions
+// keep being restored as Explore evolves.
+func migrateState(state *exploresession.State) error {
+	for state.SchemaVersion < exploresession.SchemaVersion {
+		migrate, ok := stateMigrations[state.SchemaVersion]
+		if !ok {
+			return exploresession.ErrUnsupportedVersion.Errorf("no migration from explore session version %d", state.SchemaVersion)
+		}
+		if err := migrate(state); err != nil {
+			return err
+		}
+		state.SchemaVersion++
+	}
+	return nil
+}
+
+func (s *Service) toDTO(row *exploreSession, withState bool) (*exploresession.Session, error) {
+	dto := &exploresession.Session{
+		UID:       row.UID,
+		OrgID:     row.OrgID,
+		Name:      row.Name,
+		Shared:    row.Shared,
+		URL:       s.cfg.AppSubURL + "/explore?session=" + row.UID,
+		CreatedBy: row.CreatedBy,
+		Created:   row.Created,
+		Updated:   row.Updated,
+	}
+	if !withState {
+		return dto, nil
+	}
+
+	state := &exploresession.State{}
+	if err := json.Unmarshal([]byte(row.State), state); err != nil {
+		return nil, err
+	}
+	state.SchemaVersion = row.SchemaVersion
+	if err := migrateState(state); err != nil {
+		return nil, err
+	}
+	dto.State = state
+	return dto, nil
+}
@@ -0,0 +1,255 @@
+package exploresessionimpl
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/exploresession"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func TestService_CreateSession(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should save the panes of a session", func(t *testing.T) {
+		s, store := setupTestService(t)
+
+		result, err := s.CreateSession(ctx, &exploresession.CreateSessionCommand{
+			OrgID: 1, UserID: 2, Name: " Incident ", Shared: true,
+			State: exploresession.State{Panes: []exploresession.Pane{testPane("prom"), testPane("loki")}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Incident", result.Name)
+		assert.True(t, result.Shared)
+		assert.Equal(t, "/grafana/explore?session="+result.UID, result.URL)
+		require.NotNil(t, result.State)
+		assert.Equal(t, exploresession.SchemaVersion, result.State.SchemaVersion)
+		require.Len(t, result.State.Panes, 2)
+		assert.Equal(t, "loki", result.State.Panes[1].DatasourceUID)
+		assert.Equal(t, "up", result.State.Panes[0].Queries[0].Get("expr").MustString())
+		require.Len(t, store.sessions, 1)
+		assert.Equal(t, exploresession.SchemaVersion, store.sessions[0].SchemaVersion)
+	})
+
+	t.Run("should validate the name and the state", func(t *testing.T) {
+		s, _ := setupTestService(t)
+		valid := exploresession.State{Panes: []exploresession.Pane{testPane("prom")}}
+
+		_, err := s.CreateSession(ctx, &exploresession.CreateSessionCommand{OrgID: 1, UserID: 2, Name: " ", State: valid})
+		assert.ErrorIs(t, err, exploresession.ErrNameRequired)
+
+		_, err = s.CreateSession(ctx, &exploresession.CreateSessionCommand{OrgID: 1, UserID: 2, Name: strings.Repeat("a", 191), State: valid})
+		assert.ErrorIs(t, err, exploresession.ErrNameTooLong)
+
+		_, err = s.CreateSession(ctx, &exploresession.CreateSessionCommand{OrgID: 1, UserID: 2, Name: "Empty"})
+		assert.ErrorIs(t, err, exploresession.ErrInvalidState)
+
+		noRange := testPane("prom")
+		noRange.Range = exploresession.TimeRange{}
+		_, err = s.CreateSession(ctx, &exploresession.CreateSessionCommand{
+			OrgID: 1, UserID: 2, Name: "No range", State: exploresession.State{Panes: []exploresession.Pane{noRange}},
+		})
+		assert.ErrorIs(t, err, exploresession.ErrInvalidState)
+
+		_, err = s.CreateSession(ctx, &exploresession.CreateSessionCommand{
+			OrgID: 1, UserID: 2, Name: "Newer", State: exploresession.State{SchemaVersion: exploresession.SchemaVersion + 1, Panes: valid.Panes},
+		})
+		assert.ErrorIs(t, err, exploresession.ErrUnsupportedVersion)
+	})
+}
+
+func TestService_access(t *testing.T) {
+	ctx := context.Background()
+	s, _ := setupTestService(t)
+	state := exploresession.State{Panes: []exploresession.Pane{testPane("prom")}}
+
+	private, err := s.CreateSession(ctx, &exploresession.CreateSessionCommand{OrgID: 1, UserID: 2, Name: "Private", State: state})
+	require.NoError(t, err)
+	shared, err := s.CreateSession(ctx, &exploresession.CreateSessionCommand{OrgID: 1, UserID: 2, Name: "Shared", Shared: true, State: state})
+	require.NoError(t, err)
+
+	t.Run("should list the sessions of the user and the shared sessions", func(t *testing.T) {
+		sessions, err := s.ListSessions(ctx, &exploresession.ListSessionsQuery{OrgID: 1, UserID: 2})
+		require.NoError(t, err)
+		require.Len(t, sessions, 2)
+		assert.Nil(t, sessions[0].State, "the state isn't listed")
+
+		sessions, err = s.ListSessions(ctx, &exploresession.ListSessionsQuery{OrgID: 1, UserID: 5})
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, shared.UID, sessions[0].UID)
+	})
+
+	t.Run("should restore the shared sessions of other users", func(t *testing.T) {
+		result, err := s.GetSession(ctx, &exploresession.GetSessionQuery{OrgID: 1, UID: shared.UID, UserID: 5})
+		require.NoError(t, err)
+		assert.Len(t, result.State.Panes, 1)
+
+		_, err = s.GetSession(ctx, &exploresession.GetSessionQuery{OrgID: 1, UID: private.UID, UserID: 5})
+		assert.ErrorIs(t, err, exploresession.ErrNotFound)
+
+		_, err = s.GetSession(ctx, &exploresession.GetSessionQuery{OrgID: 2, UID: shared.UID, UserID: 2})
+		assert.ErrorIs(t, err, exploresession.ErrNotFound)
+	})
+
+	t.Run("should only let the owner update a session", func(t *testing.T) {
+		_, err := s.UpdateSession(ctx, &exploresession.UpdateSessionCommand{OrgID: 1, UID: shared.UID, UserID: 5, Name: "Renamed", State: state})
+		assert.ErrorIs(t, err, exploresession.ErrNotOwner)
+
+		result, err := s.UpdateSession(ctx, &exploresession.UpdateSessionCommand{OrgID: 1, UID: shared.UID, UserID: 2, Name: "Renamed", State: state})
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", result.Name)
+		assert.False(t, result.Shared)
+	})
+
+	t.Run("should let org admins delete the shared sessions", func(t *testing.T) {
+		err := s.DeleteSession(ctx, &exploresession.DeleteSessionCommand{OrgID: 1, UID: shared.UID, UserID: 5, IsOrgAdmin: true})
+		assert.ErrorIs(t, err, exploresession.ErrNotFound, "the session isn't shared anymore")
+
+		_, err = s.UpdateSession(ctx, &exploresession.UpdateSessionCommand{OrgID: 1, UID: shared.UID, UserID: 2, Name: "Shared", Shared: true, State: state})
+		require.NoError(t, err)
+
+		err = s.DeleteSession(ctx, &exploresession.DeleteSessionCommand{OrgID: 1, UID: shared.UID, UserID: 5})
+		assert.ErrorIs(t, err, exploresession.ErrNotOwner)
+
+		require.NoError(t, s.DeleteSession(ctx, &exploresession.DeleteSessionCommand{OrgID: 1, UID: shared.UID, UserID: 5, IsOrgAdmin: true}))
+		require.NoError(t, s.DeleteSession(ctx, &exploresession.DeleteSessionCommand{OrgID: 1, UID: private.UID, UserID: 2}))
+	})
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	store := &xormStore{db: db.InitTestDB(t)}
+	now := time.Now().Truncate(time.Second)
+
+	for i, session := range []*exploreSession{
+		{UID: "a", OrgID: 1, Name: "Latency", CreatedBy: 1, Updated: now.Add(-2 * time.Minute)},
+		{UID: "b", OrgID: 1, Name: "Errors", CreatedBy: 2, Shared: true, Updated: now.Add(-1 * time.Minute)},
+		{UID: "c", OrgID: 1, Name: "Private errors", CreatedBy: 2, Updated: now},
+		{UID: "d", OrgID: 2, Name: "Other org", CreatedBy: 1, Shared: true, Updated: now},
+	} {
+		session.State = `{"schemaVersion":1,"panes":[]}`
+		session.SchemaVersion = 1
+		session.Created = now.Add(-time.Duration(i) * time.Hour)
+		require.NoError(t, store.Insert(ctx, session))
+	}
+
+	t.Run("should list the sessions of the user and the shared sessions", func(t *testing.T) {
+		sessions, err := store.List(ctx, &exploresession.ListSessionsQuery{OrgID: 1, UserID: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, sessions, 2)
+		assert.Equal(t, "b", sessions[0].UID)
+		assert.Equal(t, "a", sessions[1].UID)
+		assert.Empty(t, sessions[0].State)
+
+		sessions, err = store.List(ctx, &exploresession.ListSessionsQuery{OrgID: 1, UserID: 2, Query: "errors", Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, sessions, 2)
+
+		sessions, err = store.List(ctx, &exploresession.ListSessionsQuery{OrgID: 1, UserID: 2, SharedOnly: true, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, "b", sessions[0].UID)
+	})
+
+	t.Run("should update and delete a session", func(t *testing.T) {
+		session, err := store.Get(ctx, 1, "a")
+		require.NoError(t, err)
+		session.Shared = true
+		session.State = `{"schemaVersion":1,"panes":[{"datasourceUid":"prom"}]}`
+		require.NoError(t, store.Update(ctx, session))
+
+		session, err = store.Get(ctx, 1, "a")
+		require.NoError(t, err)
+		assert.True(t, session.Shared)
+		assert.Contains(t, session.State, "prom")
+
+		require.NoError(t, store.Delete(ctx, 1, "a"))
+		_, err = store.Get(ctx, 1, "a")
+		assert.ErrorIs(t, err, exploresession.ErrNotFound)
+	})
+}
+
+func testPane(datasourceUID string) exploresession.Pane {
+	return exploresession.Pane{
+		DatasourceUID: datasourceUID,
+		Queries:       []*simplejson.Json{simplejson.NewFromAny(map[string]any{"refId": "A", "expr": "up"})},
+		Range:         exploresession.TimeRange{From: "now-1h", To: "now"},
+	}
+}
+
+func setupTestService(t *testing.T) (*Service, *fakeStore) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.AppSubURL = "/grafana"
+
+	store := &fakeStore{}
+	return &Service{
+		cfg:   cfg,
+		store: store,
+		now:   func() time.Time { return now },
+	}, store
+}
+
+type fakeStore struct {
+	sessions []*exploreSession
+}
+
+func (f *fakeStore) Insert(_ context.Context, session *exploreSession) error {
+	session.ID = int64(len(f.sessions) + 1)
+	f.sessions = append(f.sessions, session)
+	return nil
+}
+
+func (f *fakeStore) Update(_ context.Context, session *exploreSession) error {
+	for i, s := range f.sessions {
+		if s.ID == session.ID {
+			f.sessions[i] = session
+		}
+	}
+	return nil
+}
+
+func (f *fakeStore) Delete(_ context.Context, orgID int64, uid string) error {
+	sessions := f.sessions[:0]
+	for _, s := range f.sessions {
+		if s.OrgID != orgID || s.UID != uid {
+			sessions = append(sessions, s)
+		}
+	}
+	f.sessions = sessions
+	return nil
+}
+
+func (f *fakeStore) Get(_ context.Context, orgID int64, uid string) (*exploreSession, error) {
+	for _, s := range f.sessions {
+		if s.OrgID == orgID && s.UID == uid {
+			copied := *s
+			return &copied, nil
+		}
+	}
+	return nil, exploresession.ErrNotFound.Errorf("explore session %s not found", uid)
+}
+
+func (f *fakeStore) List(_ context.Context, query *exploresession.ListSessionsQuery) ([]*exploreSession, error) {
+	sessions := make([]*exploreSession, 0)
+	for _, s := range f.sessions {
+		if s.OrgID == query.OrgID && (s.Shared || !query.SharedOnly && s.CreatedBy == query.UserID) {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions, nil
+}
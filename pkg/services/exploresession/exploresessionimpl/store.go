@@ -0,0 +1,100 @@
+package exploresessionimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/exploresession"
+)
+
+type exploreSession struct {
+	ID     int64  `xorm:"pk autoincr 'id'"`
+	UID    string `xorm:"uid"`
+	OrgID  int64  `xorm:"org_id"`
+	Name   string `xorm:"name"`
+	Shared bool   `xorm:"shared"`
+	// SchemaVersion is the version of the state, the state is migrated when it is read
+	SchemaVersion int       `xorm:"schema_version"`
+	State         string    `xorm:"state"`
+	CreatedBy     int64     `xorm:"created_by"`
+	Created       time.Time `xorm:"created"`
+	Updated       time.Time `xorm:"updated"`
+}
+
+func (exploreSession) TableName() string {
+	return "explore_session"
+}
+
+type store interface {
+	Insert(ctx context.Context, session *exploreSession) error
+	Update(ctx context.Context, session *exploreSession) error
+	Delete(ctx context.Context, orgID int64, uid string) error
+	Get(ctx context.Context, orgID int64, uid string) (*exploreSession, error)
+	// List returns the sessions of the user and the shared sessions of the org, most recently updated first.
+	// The state of the sessions isn't read.
+	List(ctx context.Context, query *exploresession.ListSessionsQuery) ([]*exploreSession, error)
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (s *xormStore) Insert(ctx context.Context, session *exploreSession) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(session)
+		return err
+	})
+}
+
+func (s *xormStore) Update(ctx context.Context, session *exploreSession) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(session.ID).Cols("name", "shared", "schema_version", "state", "updated").Update(session)
+		return err
+	})
+}
+
+func (s *xormStore) Delete(ctx context.Context, orgID int64, uid string) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM explore_session WHERE org_id = ? AND uid = ?", orgID, uid)
+		return err
+	})
+}
+
+func (s *xormStore) Get(ctx context.Context, orgID int64, uid string) (*exploreSession, error) {
+	var session exploreSession
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&session)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return exploresession.ErrNotFound.Errorf("explore session %s not found", uid)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (s *xormStore) List(ctx context.Context, query *exploresession.ListSessionsQuery) ([]*exploreSession, error) {
+	sessions := make([]*exploreSession, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table("explore_session").
+			Cols("id", "uid", "org_id", "name", "shared", "schema_version", "created_by", "created", "updated").
+			Where("org_id = ?", query.OrgID)
+		if query.SharedOnly {
+			q = q.And("shared = ?", true)
+		} else {
+			q = q.And("(shared = ? OR created_by = ?)", true, query.UserID)
+		}
+		if query.Query != "" {
+			sql, arg := s.db.GetDialect().LikeOperator("name", true, query.Query, true)
+			q = q.And(sql, arg)
+		}
+		return q.Desc("updated").Limit(query.Limit).Find(&sessions)
+	})
+	return sessions, err
+}
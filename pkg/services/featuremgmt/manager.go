@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sync"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/setting"
@@ -20,10 +21,18 @@ type FeatureManager struct {
 	Settings setting.FeatureMgmtSettings
 
 	flags    map[string]*FeatureFlag
-	enabled  map[string]bool   // only the "on" values
 	startup  map[string]bool   // the explicit values registered at startup
 	warnings map[string]string // potential warnings about the flag
 	log      log.Logger
+
+	// mu guards the values which change at runtime
+	mu       sync.RWMutex
+	enabled  map[string]bool           // only the "on" values
+	runtime  map[string]bool           // the values set at runtime, they win over the startup values
+	orgs     map[int64]map[string]bool // the values set at runtime for an organization, they win over the other values
+	watchers []toggleWatcher
+
+	provider *providerBridge // evaluates the flags with an external OpenFeature provider, nil with the static toggles
 }
 
 // This will merge the flags with the current configuration
@@ -89,8 +98,11 @@ func (fm *FeatureManager) update() {
 		// Update the registry
 		track := 0.0
 
-		startup, ok := fm.startup[flag.Name]
-		if startup || (!ok && flag.Expression == "true") {
+		value, ok := fm.runtime[flag.Name]
+		if !ok {
+			value, ok = fm.startup[flag.Name]
+		}
+		if value || (!ok && flag.Expression == "true") {
 			track = 1
 			enabled[flag.Name] = true
 		}
@@ -101,24 +113,65 @@ func (fm *FeatureManager) update() {
 	fm.enabled = enabled
 }
 
-// IsEnabled checks if a feature is enabled
+// IsEnabled checks if a feature is enabled, for the organization of the requester of the context when the flag is
+// overridden for it. With an external provider, the provider decides for the requester of the context.
 func (fm *FeatureManager) IsEnabled(ctx context.Context, flag string) bool {
-	return fm.enabled[flag]
+	fm.mu.RLock()
+	value, ok := fm.orgOverrides(ctx)[flag]
+	if !ok {
+		value = fm.enabled[flag]
+	}
+	fm.mu.RUnlock()
+
+	if fm.evaluatesWithProvider(flag) {
+		return fm.provider.evaluate(ctx, flag, value)
+	}
+	return value
 }
 
 // IsEnabledGlobally checks if a feature is for all tenants
 func (fm *FeatureManager) IsEnabledGlobally(flag string) bool {
-	return fm.enabled[flag]
+	fm.mu.RLock()
+	value := fm.enabled[flag]
+	fm.mu.RUnlock()
+
+	if fm.evaluatesWithProvider(flag) {
+		return fm.provider.evaluate(context.Background(), flag, value)
+	}
+	return value
 }
 
 // GetEnabled returns a map containing only the features that are enabled
 func (fm *FeatureManager) GetEnabled(ctx context.Context) map[string]bool {
+	fm.mu.RLock()
 	enabled := make(map[string]bool, len(fm.enabled))
 	for key, val := range fm.enabled {
 		if val {
 			enabled[key] = true
 		}
 	}
+	for key, val := range fm.orgOverrides(ctx) {
+		if val {
+			enabled[key] = true
+		} else {
+			delete(enabled, key)
+		}
+	}
+	fm.mu.RUnlock()
+
+	if fm.provider == nil {
+		return enabled
+	}
+	for key := range fm.flags {
+		if !fm.evaluatesWithProvider(key) {
+			continue
+		}
+		if fm.provider.evaluate(ctx, key, enabled[key]) {
+			enabled[key] = true
+		} else {
+			delete(enabled, key)
+		}
+	}
 	return enabled
 }
 
@@ -140,6 +140,9 @@ type FeatureFlag struct {
 
 	// The server must be initialized with the value
 	RequiresRestart bool `json:"requiresRestart,omitempty"`
+
+	// The flag is checked with the requester of the request, so that it can have a different value in an organization
+	AllowOrgOverride bool `json:"allowOrgOverride,omitempty"`
 }
 
 type FeatureToggleWebhookPayload struct {
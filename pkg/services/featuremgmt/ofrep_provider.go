@@ -0,0 +1,14 @@
+package featuremgmt
+
+import (
+	"net/http"
+
+	"github.com/open-feature/go-sdk-contrib/providers/ofrep"
+	"github.com/open-feature/go-sdk/openfeature"
+)
+
+// newOFREPProvider evaluates the flags with a service implementing the OpenFeature Remote Evaluation Protocol, such
+// as flagd, or the relay proxy of ConfigCat, LaunchDarkly and the other flag management services
+func newOFREPProvider(url string, client *http.Client) (openfeature.FeatureProvider, error) {
+	return ofrep.NewProvider(url, ofrep.WithClient(client)), nil
+}
@@ -24,7 +24,8 @@ func InitOpenFeatureWithCfg(cfg *setting.Cfg) error {
 	}
 
 	var httpcli *http.Client
-	if cfg.OpenFeature.ProviderType == setting.GOFFProviderType {
+	switch cfg.OpenFeature.ProviderType {
+	case setting.GOFFProviderType:
 		m, err := clientauthmiddleware.NewTokenExchangeMiddleware(cfg)
 		if err != nil {
 			return fmt.Errorf("failed to create token exchange middleware: %w", err)
@@ -34,6 +35,11 @@ func InitOpenFeatureWithCfg(cfg *setting.Cfg) error {
 		if err != nil {
 			return err
 		}
+	case setting.OFREPProviderType:
+		httpcli, err = ofrepHTTPClient()
+		if err != nil {
+			return err
+		}
 	}
 
 	err = initOpenFeature(cfg.OpenFeature.ProviderType, cfg.OpenFeature.URL, confFlags, httpcli)
@@ -69,15 +75,20 @@ func createProvider(
 	staticFlags map[string]bool,
 	httpClient *http.Client,
 ) (openfeature.FeatureProvider, error) {
-	if providerType != setting.GOFFProviderType {
+	switch providerType {
+	case setting.GOFFProviderType:
+		if u.String() == "" {
+			return nil, fmt.Errorf("feature provider url is required for GOFFProviderType")
+		}
+		return newGOFFProvider(u.String(), httpClient)
+	case setting.OFREPProviderType:
+		if u.String() == "" {
+			return nil, fmt.Errorf("feature provider url is required for OFREPProviderType")
+		}
+		return newOFREPProvider(u.String(), httpClient)
+	default:
 		return newStaticProvider(staticFlags)
 	}
-
-	if u.String() == "" {
-		return nil, fmt.Errorf("feature provider url is required for GOFFProviderType")
-	}
-
-	return newGOFFProvider(u.String(), httpClient)
 }
 
 func goffHTTPClient(m *clientauthmiddleware.TokenExchangeMiddleware) (*http.Client, error) {
@@ -97,3 +108,17 @@ func goffHTTPClient(m *clientauthmiddleware.TokenExchangeMiddleware) (*http.Clie
 
 	return httpcli, nil
 }
+
+func ofrepHTTPClient() (*http.Client, error) {
+	httpcli, err := sdkhttpclient.NewProvider().New(sdkhttpclient.Options{
+		Timeouts: &sdkhttpclient.TimeoutOptions{
+			Timeout: 5 * time.Second,
+		},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http client for openfeature: %w", err)
+	}
+
+	return httpcli, nil
+}
@@ -12,6 +12,7 @@ import (
 
 	authlib "github.com/grafana/authlib/authn"
 	gofeatureflag "github.com/open-feature/go-sdk-contrib/providers/go-feature-flag/pkg"
+	"github.com/open-feature/go-sdk-contrib/providers/ofrep"
 	"github.com/open-feature/go-sdk/openfeature"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -60,6 +61,15 @@ func TestCreateProvider(t *testing.T) {
 			expectedProvider: setting.GOFFProviderType,
 			failSigning:      true,
 		},
+		{
+			name: "ofrep provider",
+			cfg: setting.OpenFeatureSettings{
+				ProviderType: setting.OFREPProviderType,
+				URL:          u,
+				TargetingKey: "grafana",
+			},
+			expectedProvider: setting.OFREPProviderType,
+		},
 		{
 			name: "invalid provider",
 			cfg: setting.OpenFeatureSettings{
@@ -109,6 +119,9 @@ func TestCreateProvider(t *testing.T) {
 				assert.True(t, ok, "expected provider to be of type goff.Provider")
 
 				testGoFFProvider(t, tc.failSigning)
+			} else if tc.expectedProvider == setting.OFREPProviderType {
+				_, ok := provider.(*ofrep.Provider)
+				assert.True(t, ok, "expected provider to be of type ofrep.Provider")
 			} else {
 				_, ok := provider.(*inMemoryBulkProvider)
 				assert.True(t, ok, "expected provider to be of type memprovider.InMemoryProvider")
@@ -0,0 +1,158 @@
+package featuremgmt
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/open-feature/go-sdk/openfeature"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// maxCachedEvaluations bounds the number of cached values, there is one for each flag and user
+const maxCachedEvaluations = 10000
+
+var (
+	providerEvaluations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name:      "feature_toggles_provider_evaluations_total",
+		Help:      "number of feature toggles evaluated by the external OpenFeature provider, by result",
+		Namespace: "grafana",
+	}, []string{"result"})
+)
+
+// booleanEvaluator is the part of the OpenFeature client used to evaluate the toggles
+type booleanEvaluator interface {
+	BooleanValueDetails(ctx context.Context, flag string, defaultValue bool, evalCtx openfeature.EvaluationContext, options ...openfeature.Option) (openfeature.BooleanEvaluationDetails, error)
+}
+
+// providerBridge evaluates the feature toggles with the external OpenFeature provider. The local value, from the
+// configuration and the values set at runtime, is the default value: it applies to the flags the provider doesn't
+// know and while the provider is unavailable.
+type providerBridge struct {
+	client   booleanEvaluator
+	ttl      time.Duration
+	instance string
+	log      log.Logger
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[evaluationKey]cachedEvaluation
+	// unavailableUntil skips the provider after it failed, so that the requests don't wait for it for each flag
+	unavailableUntil time.Time
+}
+
+type evaluationKey struct {
+	flag  string
+	orgID int64
+	user  string
+}
+
+type cachedEvaluation struct {
+	value   bool
+	expires time.Time
+}
+
+func newProviderBridge(cfg setting.OpenFeatureSettings, client booleanEvaluator) *providerBridge {
+	return &providerBridge{
+		client:   client,
+		ttl:      cfg.CacheTTL,
+		instance: cfg.TargetingKey,
+		log:      log.New("featuremgmt.openfeature"),
+		now:      time.Now,
+		cache:    make(map[evaluationKey]cachedEvaluation),
+	}
+}
+
+// evaluate returns the value of the flag for the requester of the context, or for the instance without requester
+func (b *providerBridge) evaluate(ctx context.Context, flag string, fallback bool) bool {
+	evalCtx, key := b.evaluationContext(ctx, flag)
+	now := b.now()
+
+	b.mu.Lock()
+	cached, ok := b.cache[key]
+	unavailable := now.Before(b.unavailableUntil)
+	b.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.value
+	}
+	if unavailable {
+		providerEvaluations.WithLabelValues("unavailable").Inc()
+		return fallback
+	}
+
+	value := fallback
+	details, err := b.client.BooleanValueDetails(ctx, flag, fallback, evalCtx)
+	switch {
+	case err == nil:
+		providerEvaluations.WithLabelValues("success").Inc()
+		value = details.Value
+	case details.ErrorCode == openfeature.FlagNotFoundCode:
+		providerEvaluations.WithLabelValues("not_found").Inc()
+	default:
+		providerEvaluations.WithLabelValues("error").Inc()
+		b.log.Warn("Failed to evaluate the feature toggle, using the local values", "flag", flag, "error", err)
+		b.mu.Lock()
+		b.unavailableUntil = now.Add(b.ttl)
+		b.mu.Unlock()
+		return fallback
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.cache) >= maxCachedEvaluations {
+		b.prune(now)
+	}
+	b.cache[key] = cachedEvaluation{value: value, expires: now.Add(b.ttl)}
+	return value
+}
+
+// evaluationContext returns the context of the evaluation: the instance, which is the targeting key without
+// requester, and the organization and the user of the requester of the context
+func (b *providerBridge) evaluationContext(ctx context.Context, flag string) (openfeature.EvaluationContext, evaluationKey) {
+	key := evaluationKey{flag: flag}
+	targetingKey := b.instance
+	attrs := map[string]any{
+		"instance": b.instance,
+	}
+
+	if requester, err := identity.GetRequester(ctx); err == nil {
+		key.orgID = requester.GetOrgID()
+		key.user = requester.GetUID()
+		attrs["org_id"] = key.orgID
+		attrs["org_role"] = string(requester.GetOrgRole())
+		if key.user != "" {
+			targetingKey = key.user
+			attrs["user_uid"] = key.user
+			attrs["user_login"] = requester.GetLogin()
+		}
+	}
+
+	return openfeature.NewEvaluationContext(targetingKey, attrs), key
+}
+
+// prune removes the expired values, or every value when none expired. b.mu must be held.
+func (b *providerBridge) prune(now time.Time) {
+	for key, cached := range b.cache {
+		if !now.Before(cached.expires) {
+			delete(b.cache, key)
+		}
+	}
+	if len(b.cache) >= maxCachedEvaluations {
+		b.cache = make(map[evaluationKey]cachedEvaluation)
+	}
+}
+
+// evaluatesWithProvider checks if the value of the flag comes from the external provider. The flags read once at
+// startup keep their local value, since a different value from the provider wouldn't apply until a restart.
+func (fm *FeatureManager) evaluatesWithProvider(key string) bool {
+	if fm.provider == nil {
+		return false
+	}
+	flag, ok := fm.flags[key]
+	return ok && !flag.RequiresRestart && !flag.RequiresDevMode
+}
@@ -0,0 +1,120 @@
+package featuremgmt
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/open-feature/go-sdk/openfeature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeEvaluator struct {
+	// values are the values of the flags, by targeting key
+	values   map[string]map[string]bool
+	err      error
+	calls    int
+	contexts []openfeature.EvaluationContext
+}
+
+func (f *fakeEvaluator) BooleanValueDetails(_ context.Context, flag string, defaultValue bool, evalCtx openfeature.EvaluationContext, _ ...openfeature.Option) (openfeature.BooleanEvaluationDetails, error) {
+	f.calls++
+	f.contexts = append(f.contexts, evalCtx)
+
+	details := openfeature.BooleanEvaluationDetails{Value: defaultValue}
+	if f.err != nil {
+		details.ErrorCode = openfeature.GeneralCode
+		return details, f.err
+	}
+	value, ok := f.values[evalCtx.TargetingKey()][flag]
+	if !ok {
+		details.ErrorCode = openfeature.FlagNotFoundCode
+		return details, errors.New("flag not found")
+	}
+	details.Value = value
+	return details, nil
+}
+
+func setupProviderBridge(t *testing.T, evaluator *fakeEvaluator) (*FeatureManager, *time.Time) {
+	t.Helper()
+	fm := WithFeatureManager(setting.FeatureMgmtSettings{}, []*FeatureFlag{
+		{Name: "remote", Stage: FeatureStagePublicPreview},
+		{Name: "local", Stage: FeatureStageGeneralAvailability},
+		{Name: "restart", Stage: FeatureStageGeneralAvailability, RequiresRestart: true},
+	}, "remote")
+
+	now := time.Now()
+	fm.provider = newProviderBridge(setting.OpenFeatureSettings{TargetingKey: "grafana", CacheTTL: time.Minute}, evaluator)
+	fm.provider.now = func() time.Time { return now }
+	return fm, &now
+}
+
+func TestProviderBridge(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("evaluates the flags with the provider and falls back on the local values", func(t *testing.T) {
+		evaluator := &fakeEvaluator{values: map[string]map[string]bool{
+			"grafana": {"remote": true, "restart": false},
+		}}
+		fm, _ := setupProviderBridge(t, evaluator)
+
+		assert.True(t, fm.IsEnabledGlobally("remote"))
+		assert.True(t, fm.IsEnabledGlobally("local"), "the provider doesn't know the flag")
+		assert.True(t, fm.IsEnabledGlobally("restart"), "the flags requiring a restart keep their local value")
+		assert.Equal(t, map[string]bool{"remote": true, "local": true, "restart": true}, fm.GetEnabled(ctx))
+	})
+
+	t.Run("evaluates the flags for the requester", func(t *testing.T) {
+		evaluator := &fakeEvaluator{values: map[string]map[string]bool{
+			"grafana":     {"remote": false},
+			"user:user-1": {"remote": true},
+		}}
+		fm, _ := setupProviderBridge(t, evaluator)
+
+		userCtx := identity.WithRequester(ctx, &user.SignedInUser{UserUID: "user-1", Login: "admin", OrgID: 2})
+		assert.True(t, fm.IsEnabled(userCtx, "remote"))
+		assert.False(t, fm.IsEnabled(ctx, "remote"))
+
+		evalCtx := evaluator.contexts[0]
+		assert.Equal(t, "user:user-1", evalCtx.TargetingKey())
+		assert.Equal(t, int64(2), evalCtx.Attribute("org_id"))
+		assert.Equal(t, "admin", evalCtx.Attribute("user_login"))
+		assert.Equal(t, "grafana", evalCtx.Attribute("instance"))
+	})
+
+	t.Run("caches the values", func(t *testing.T) {
+		evaluator := &fakeEvaluator{values: map[string]map[string]bool{
+			"grafana": {"remote": true},
+		}}
+		fm, now := setupProviderBridge(t, evaluator)
+
+		assert.True(t, fm.IsEnabledGlobally("remote"))
+		evaluator.values["grafana"]["remote"] = false
+		assert.True(t, fm.IsEnabledGlobally("remote"))
+		require.Equal(t, 1, evaluator.calls)
+
+		*now = now.Add(2 * time.Minute)
+		assert.False(t, fm.IsEnabledGlobally("remote"))
+		assert.Equal(t, 2, evaluator.calls)
+	})
+
+	t.Run("uses the local values while the provider is unavailable", func(t *testing.T) {
+		evaluator := &fakeEvaluator{err: errors.New("connection refused")}
+		fm, now := setupProviderBridge(t, evaluator)
+
+		assert.True(t, fm.IsEnabledGlobally("local"))
+		assert.False(t, fm.IsEnabledGlobally("remote"))
+		assert.Equal(t, 1, evaluator.calls, "the provider isn't called again while it's unavailable")
+
+		evaluator.err = nil
+		evaluator.values = map[string]map[string]bool{"grafana": {"remote": true}}
+		*now = now.Add(2 * time.Minute)
+		assert.True(t, fm.IsEnabledGlobally("remote"))
+	})
+}
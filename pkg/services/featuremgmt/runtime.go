@@ -0,0 +1,184 @@
+package featuremgmt
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+)
+
+var (
+	_ ToggleWatcher = (*FeatureManager)(nil)
+)
+
+// ToggleChange is a change of the value of a feature toggle at runtime
+type ToggleChange struct {
+	Flag string
+	// OrgID is the organization of an override, 0 when the value of every organization without override changed
+	OrgID   int64
+	Enabled bool
+}
+
+// ToggleWatcher is implemented by the feature managers whose toggles can change without a restart
+type ToggleWatcher interface {
+	// Watch calls the handler each time the value of one of the flags changes, globally or for an organization. The
+	// handler is called for every flag when no flag is given.
+	Watch(handler func(ctx context.Context, change ToggleChange), flags ...string)
+}
+
+type toggleWatcher struct {
+	flags   map[string]bool
+	handler func(ctx context.Context, change ToggleChange)
+}
+
+// Watch calls the handler after the runtime values of the flags change
+func (fm *FeatureManager) Watch(handler func(ctx context.Context, change ToggleChange), flags ...string) {
+	w := toggleWatcher{handler: handler}
+	if len(flags) > 0 {
+		w.flags = make(map[string]bool, len(flags))
+		for _, flag := range flags {
+			w.flags[flag] = true
+		}
+	}
+
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	fm.watchers = append(fm.watchers, w)
+}
+
+// IsEditableAtRuntime checks if the value of the flag can change without a restart
+func (fm *FeatureManager) IsEditableAtRuntime(key string) bool {
+	flag, ok := fm.flags[key]
+	if !ok || !fm.Settings.RuntimeEditing || flag.Name == FlagFeatureToggleAdminPage {
+		return false
+	}
+	if _, readOnly := fm.Settings.ReadOnlyToggles[key]; readOnly {
+		return false
+	}
+	return !flag.RequiresRestart && !flag.RequiresDevMode && flag.Stage != FeatureStageUnknown
+}
+
+// IsOverridableByOrg checks if the flag can have a different value in an organization. Only the flags checked with
+// the requester of the request, which are the frontend flags and the flags allowing it explicitly, can.
+func (fm *FeatureManager) IsOverridableByOrg(key string) bool {
+	if !fm.IsEditableAtRuntime(key) {
+		return false
+	}
+	flag := fm.flags[key]
+	return flag.FrontendOnly || flag.AllowOrgOverride
+}
+
+// GetRuntimeValues returns the values set at runtime, globally and by organization
+func (fm *FeatureManager) GetRuntimeValues() (map[string]bool, map[int64]map[string]bool) {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+
+	global := make(map[string]bool, len(fm.runtime))
+	for k, v := range fm.runtime {
+		global[k] = v
+	}
+	orgs := make(map[int64]map[string]bool, len(fm.orgs))
+	for orgID, values := range fm.orgs {
+		orgs[orgID] = make(map[string]bool, len(values))
+		for k, v := range values {
+			orgs[orgID][k] = v
+		}
+	}
+	return global, orgs
+}
+
+// SetRuntimeValues replaces the values set at runtime and tells the watchers about the values which changed. The
+// values of the flags which can't be edited at runtime are ignored.
+func (fm *FeatureManager) SetRuntimeValues(ctx context.Context, global map[string]bool, orgs map[int64]map[string]bool) {
+	runtime := make(map[string]bool, len(global))
+	for k, v := range global {
+		if fm.IsEditableAtRuntime(k) {
+			runtime[k] = v
+		}
+	}
+	orgValues := make(map[int64]map[string]bool, len(orgs))
+	for orgID, values := range orgs {
+		for k, v := range values {
+			if !fm.IsOverridableByOrg(k) {
+				continue
+			}
+			if orgValues[orgID] == nil {
+				orgValues[orgID] = map[string]bool{}
+			}
+			orgValues[orgID][k] = v
+		}
+	}
+
+	fm.mu.Lock()
+	previous, previousOrgs := fm.enabled, fm.orgs
+	fm.runtime = runtime
+	fm.orgs = orgValues
+	fm.update()
+	changes := fm.changes(previous, previousOrgs)
+	watchers := fm.watchers
+	fm.mu.Unlock()
+
+	for _, change := range changes {
+		if fm.log != nil {
+			fm.log.Info("Feature toggle changed at runtime", "flag", change.Flag, "orgId", change.OrgID, "enabled", change.Enabled)
+		}
+		for _, w := range watchers {
+			if w.flags == nil || w.flags[change.Flag] {
+				w.handler(ctx, change)
+			}
+		}
+	}
+}
+
+// changes returns the values which differ from the previous values, globally and in the organizations with an
+// override. fm.mu must be held.
+func (fm *FeatureManager) changes(previous map[string]bool, previousOrgs map[int64]map[string]bool) []ToggleChange {
+	var changes []ToggleChange
+	for name := range fm.flags {
+		if previous[name] != fm.enabled[name] {
+			changes = append(changes, ToggleChange{Flag: name, Enabled: fm.enabled[name]})
+		}
+	}
+
+	orgIDs := make(map[int64]bool, len(fm.orgs)+len(previousOrgs))
+	for orgID := range fm.orgs {
+		orgIDs[orgID] = true
+	}
+	for orgID := range previousOrgs {
+		orgIDs[orgID] = true
+	}
+	for orgID := range orgIDs {
+		names := make(map[string]bool)
+		for name := range fm.orgs[orgID] {
+			names[name] = true
+		}
+		for name := range previousOrgs[orgID] {
+			names[name] = true
+		}
+		for name := range names {
+			before, ok := previousOrgs[orgID][name]
+			if !ok {
+				before = previous[name]
+			}
+			after, ok := fm.orgs[orgID][name]
+			if !ok {
+				after = fm.enabled[name]
+			}
+			if before != after {
+				changes = append(changes, ToggleChange{Flag: name, OrgID: orgID, Enabled: after})
+			}
+		}
+	}
+	return changes
+}
+
+// orgOverrides returns the values set for the organization of the requester of the context. fm.mu must be held.
+func (fm *FeatureManager) orgOverrides(ctx context.Context) map[string]bool {
+	if len(fm.orgs) == 0 {
+		return nil
+	}
+	requester, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil
+	}
+	return fm.orgs[requester.GetOrgID()]
+}
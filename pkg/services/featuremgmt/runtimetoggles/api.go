@@ -0,0 +1,223 @@
+package runtimetoggles
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+	canRead := authorize(ac.EvalPermission(ac.ActionFeatureManagementRead))
+	canWrite := authorize(ac.EvalPermission(ac.ActionFeatureManagementWrite))
+
+	router.Group("/api/admin/feature-toggles", func(toggleRoute routing.RouteRegister) {
+		toggleRoute.Get("/", canRead, routing.Wrap(s.GetFeatureToggles))
+		toggleRoute.Get("/history", canRead, routing.Wrap(s.GetFeatureToggleHistory))
+		toggleRoute.Put("/:name", canWrite, routing.Wrap(s.SetFeatureToggle))
+		toggleRoute.Delete("/:name", canWrite, routing.Wrap(s.ResetFeatureToggle))
+		toggleRoute.Put("/:name/orgs/:orgId", canWrite, routing.Wrap(s.SetFeatureToggle))
+		toggleRoute.Delete("/:name/orgs/:orgId", canWrite, routing.Wrap(s.ResetFeatureToggle))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /admin/feature-toggles admin getRuntimeFeatureToggles
+//
+// Get the feature toggles which can be changed at runtime.
+//
+// Returns the toggles which don't require a restart, with their startup value, the value set at runtime and the
+// values set for organizations.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getRuntimeFeatureTogglesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+func (s *Service) GetFeatureToggles(c *contextmodel.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, s.GetToggles(c.Req.Context()))
+}
+
+// swagger:route GET /admin/feature-toggles/history admin getRuntimeFeatureToggleHistory
+//
+// Get the changes of the feature toggles made at runtime.
+//
+// Returns who changed the toggles, when, and their previous values, the most recent first.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getRuntimeFeatureToggleHistoryResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) GetFeatureToggleHistory(c *contextmodel.ReqContext) response.Response {
+	query := HistoryQuery{
+		Name:  c.Query("name"),
+		OrgID: -1,
+		Limit: c.QueryInt("limit"),
+	}
+	if c.Query("orgId") != "" {
+		query.OrgID = c.QueryInt64("orgId")
+	}
+
+	changes, err := s.GetHistory(c.Req.Context(), query)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get the feature toggle history", err)
+	}
+	return response.JSON(http.StatusOK, changes)
+}
+
+// swagger:route PUT /admin/feature-toggles/{name} admin setRuntimeFeatureToggle
+//
+// Change a feature toggle at runtime.
+//
+// Sets the value of the toggle on every instance without a restart. The value wins over the value of the
+// configuration until it's reset.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route PUT /admin/feature-toggles/{name}/orgs/{orgId} admin setRuntimeOrgFeatureToggle
+//
+// Override a feature toggle for an organization at runtime.
+//
+// Only the frontend toggles and the toggles allowing it can be overridden for an organization.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) SetFeatureToggle(c *contextmodel.ReqContext) response.Response {
+	cmd := SetToggleCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if cmd.Enabled == nil {
+		return response.Error(http.StatusBadRequest, "enabled is required", nil)
+	}
+	return s.setToggle(c, &cmd, "Feature toggle updated")
+}
+
+// swagger:route DELETE /admin/feature-toggles/{name} admin resetRuntimeFeatureToggle
+//
+// Reset a feature toggle to the value of the configuration.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+
+// swagger:route DELETE /admin/feature-toggles/{name}/orgs/{orgId} admin resetRuntimeOrgFeatureToggle
+//
+// Remove the override of a feature toggle for an organization.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) ResetFeatureToggle(c *contextmodel.ReqContext) response.Response {
+	return s.setToggle(c, &SetToggleCommand{}, "Feature toggle reset")
+}
+
+func (s *Service) setToggle(c *contextmodel.ReqContext, cmd *SetToggleCommand, message string) response.Response {
+	cmd.Name = web.Params(c.Req)[":name"]
+	cmd.User = c.SignedInUser
+	if orgID, ok := web.Params(c.Req)[":orgId"]; ok {
+		id, err := strconv.ParseInt(orgID, 10, 64)
+		if err != nil || id <= 0 {
+			return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+		}
+		cmd.OrgID = id
+	}
+
+	if err := s.SetToggle(c.Req.Context(), cmd); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update the feature toggle", err)
+	}
+	return response.Success(message)
+}
+
+// swagger:parameters setRuntimeFeatureToggle setRuntimeOrgFeatureToggle
+type SetRuntimeFeatureToggleParams struct {
+	// in:path
+	// required:true
+	Name string `json:"name"`
+	// in:body
+	// required:true
+	Body SetToggleCommand `json:"body"`
+}
+
+// swagger:parameters resetRuntimeFeatureToggle resetRuntimeOrgFeatureToggle
+type ResetRuntimeFeatureToggleParams struct {
+	// in:path
+	// required:true
+	Name string `json:"name"`
+}
+
+// swagger:parameters setRuntimeOrgFeatureToggle resetRuntimeOrgFeatureToggle
+type RuntimeOrgFeatureToggleParams struct {
+	// in:path
+	// required:true
+	OrgID int64 `json:"orgId"`
+}
+
+// swagger:parameters getRuntimeFeatureToggleHistory
+type GetRuntimeFeatureToggleHistoryParams struct {
+	// in:query
+	// required:false
+	Name string `json:"name"`
+	// Only the changes of the organization, 0 for the global values
+	// in:query
+	// required:false
+	OrgID int64 `json:"orgId"`
+	// in:query
+	// required:false
+	// default:100
+	Limit int `json:"limit"`
+}
+
+// swagger:response getRuntimeFeatureTogglesResponse
+type GetRuntimeFeatureTogglesResponse struct {
+	// in:body
+	Body []Toggle `json:"body"`
+}
+
+// swagger:response getRuntimeFeatureToggleHistoryResponse
+type GetRuntimeFeatureToggleHistoryResponse struct {
+	// in:body
+	Body []*Change `json:"body"`
+}
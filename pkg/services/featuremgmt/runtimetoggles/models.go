@@ -0,0 +1,86 @@
+package runtimetoggles
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+)
+
+var (
+	ErrToggleNotFound       = errutil.NotFound("runtimetoggles.notFound", errutil.WithPublicMessage("Feature toggle not found"))
+	ErrToggleNotEditable    = errutil.BadRequest("runtimetoggles.notEditable", errutil.WithPublicMessage("The feature toggle can't be changed without a restart"))
+	ErrOrgOverrideForbidden = errutil.BadRequest("runtimetoggles.orgOverrideForbidden", errutil.WithPublicMessage("The feature toggle can't be overridden for an organization"))
+)
+
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+// Toggle is the state of a feature toggle which can be changed at runtime
+type Toggle struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Stage       string `json:"stage"`
+	// Enabled is the value of the toggle in the organizations without override
+	Enabled bool `json:"enabled"`
+	// Startup is the value from the configuration, or the default value, which applies without a runtime value
+	Startup bool `json:"startup"`
+	// Runtime is the value set at runtime, nil when the startup value applies
+	Runtime        *bool `json:"runtime,omitempty"`
+	OrgOverridable bool  `json:"orgOverridable"`
+	// OrgOverrides are the values set for organizations, by org id
+	OrgOverrides map[int64]bool `json:"orgOverrides,omitempty"`
+}
+
+// SetToggleCommand sets the value of a toggle globally, or for an organization
+type SetToggleCommand struct {
+	Name string `json:"-"`
+	// OrgID is 0 to set the global value
+	OrgID int64 `json:"-"`
+	// Enabled is nil to remove the value set at runtime
+	Enabled *bool              `json:"enabled"`
+	User    identity.Requester `json:"-"`
+}
+
+// HistoryQuery filters the changes of the toggles, the most recent first
+type HistoryQuery struct {
+	Name string
+	// OrgID is -1 to return the changes of every organization and of the global values
+	OrgID int64
+	Limit int
+}
+
+// Change is a change of a feature toggle made at runtime
+type Change struct {
+	ID    int64  `xorm:"pk autoincr 'id'" json:"id"`
+	OrgID int64  `xorm:"org_id" json:"orgId"`
+	Name  string `xorm:"name" json:"name"`
+	// Previous is the value before the change
+	Previous bool `xorm:"previous" json:"previous"`
+	// Value is the value after the change
+	Value bool `xorm:"value" json:"value"`
+	// Reset is true when the value set at runtime was removed
+	Reset     bool      `xorm:"reset" json:"reset"`
+	UserID    int64     `xorm:"user_id" json:"userId"`
+	UserLogin string    `xorm:"user_login" json:"userLogin"`
+	Created   time.Time `xorm:"created" json:"created"`
+}
+
+func (Change) TableName() string {
+	return "feature_toggle_history"
+}
+
+type override struct {
+	ID        int64     `xorm:"pk autoincr 'id'"`
+	OrgID     int64     `xorm:"org_id"`
+	Name      string    `xorm:"name"`
+	Enabled   bool      `xorm:"enabled"`
+	UpdatedBy int64     `xorm:"updated_by"`
+	Updated   time.Time `xorm:"updated"`
+}
+
+func (override) TableName() string {
+	return "feature_toggle_override"
+}
@@ -0,0 +1,240 @@
+// Package runtimetoggles changes the feature toggles which don't require a restart at runtime, globally or for an
+// organization, and records who changed them. The values are stored in the database and applied by every instance.
+package runtimetoggles
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/cacheinvalidation"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// reloadInterval is how often the instances read the values from the database, for the changes they weren't told
+// about by the cache invalidation
+const reloadInterval = time.Minute
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, features *featuremgmt.FeatureManager, router routing.RouteRegister,
+	accessControl ac.AccessControl, invalidations cacheinvalidation.Service) (*Service, error) {
+	s := &Service{
+		enabled:       cfg.FeatureManagement.RuntimeEditing,
+		store:         &xormStore{db: sqlStore},
+		features:      features,
+		invalidations: invalidations,
+		log:           log.New("featuremgmt.runtime"),
+	}
+
+	if !s.enabled {
+		return s, nil
+	}
+
+	if err := s.reload(context.Background()); err != nil {
+		return nil, err
+	}
+	invalidations.Register("feature toggles", []string{cacheinvalidation.KindFeatureToggles + "/"}, s.handleInvalidation)
+	s.registerRoutes(router, accessControl)
+
+	return s, nil
+}
+
+type Service struct {
+	enabled       bool
+	store         store
+	features      *featuremgmt.FeatureManager
+	invalidations cacheinvalidation.Service
+	log           log.Logger
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	if !s.enabled {
+		return nil
+	}
+
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := s.reload(ctx); err != nil {
+				s.log.Error("Failed to reload the feature toggles changed at runtime", "error", err)
+			}
+		}
+	}
+}
+
+// GetToggles returns the toggles which can be changed at runtime, by name
+func (s *Service) GetToggles(ctx context.Context) []Toggle {
+	global, orgs := s.features.GetRuntimeValues()
+
+	toggles := []Toggle{}
+	for _, flag := range s.features.GetFlags() {
+		if !s.features.IsEditableAtRuntime(flag.Name) || s.features.IsHiddenFromAdminPage(flag.Name, true) {
+			continue
+		}
+		toggle := Toggle{
+			Name:           flag.Name,
+			Description:    flag.Description,
+			Stage:          flag.Stage.String(),
+			Enabled:        s.value(flag.Name, 0, global, orgs),
+			Startup:        s.startupValue(flag.Name),
+			OrgOverridable: s.features.IsOverridableByOrg(flag.Name),
+		}
+		if v, ok := global[flag.Name]; ok {
+			toggle.Runtime = &v
+		}
+		for orgID, values := range orgs {
+			if v, ok := values[flag.Name]; ok {
+				if toggle.OrgOverrides == nil {
+					toggle.OrgOverrides = map[int64]bool{}
+				}
+				toggle.OrgOverrides[orgID] = v
+			}
+		}
+		toggles = append(toggles, toggle)
+	}
+
+	sort.Slice(toggles, func(i, j int) bool { return toggles[i].Name < toggles[j].Name })
+	return toggles
+}
+
+// SetToggle changes the value of a toggle on every instance and records the change
+func (s *Service) SetToggle(ctx context.Context, cmd *SetToggleCommand) error {
+	if !s.isKnown(cmd.Name) {
+		return ErrToggleNotFound.Errorf("SetToggle: unknown feature toggle %s", cmd.Name)
+	}
+	if !s.features.IsEditableAtRuntime(cmd.Name) {
+		return ErrToggleNotEditable.Errorf("SetToggle: feature toggle %s can't be changed at runtime", cmd.Name)
+	}
+	if cmd.OrgID > 0 && !s.features.IsOverridableByOrg(cmd.Name) {
+		return ErrOrgOverrideForbidden.Errorf("SetToggle: feature toggle %s can't be overridden for an organization", cmd.Name)
+	}
+
+	global, orgs := s.features.GetRuntimeValues()
+	previous := s.value(cmd.Name, cmd.OrgID, global, orgs)
+
+	values := global
+	if cmd.OrgID > 0 {
+		if orgs[cmd.OrgID] == nil {
+			orgs[cmd.OrgID] = map[string]bool{}
+		}
+		values = orgs[cmd.OrgID]
+	}
+	current, isSet := values[cmd.Name]
+	if cmd.Enabled == nil && !isSet || cmd.Enabled != nil && isSet && current == *cmd.Enabled {
+		return nil
+	}
+	if cmd.Enabled == nil {
+		delete(values, cmd.Name)
+	} else {
+		values[cmd.Name] = *cmd.Enabled
+	}
+
+	change := &Change{
+		OrgID:    cmd.OrgID,
+		Name:     cmd.Name,
+		Previous: previous,
+		Value:    s.value(cmd.Name, cmd.OrgID, global, orgs),
+		Reset:    cmd.Enabled == nil,
+		Created:  time.Now(),
+	}
+	var userID int64
+	if cmd.User != nil {
+		userID, _ = cmd.User.GetInternalID()
+		change.UserID = userID
+		change.UserLogin = cmd.User.GetLogin()
+	}
+	if err := s.store.Set(ctx, cmd.OrgID, cmd.Name, cmd.Enabled, userID, change); err != nil {
+		return err
+	}
+
+	s.features.SetRuntimeValues(ctx, global, orgs)
+	s.invalidations.Invalidate(ctx, cacheinvalidation.Invalidation{
+		Key:    cacheinvalidation.Key(cacheinvalidation.KindFeatureToggles, cmd.OrgID, cmd.Name),
+		Reason: "FeatureToggleChanged",
+	})
+	return nil
+}
+
+// GetHistory returns the changes made at runtime, the most recent first
+func (s *Service) GetHistory(ctx context.Context, query HistoryQuery) ([]*Change, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultHistoryLimit
+	}
+	if query.Limit > maxHistoryLimit {
+		query.Limit = maxHistoryLimit
+	}
+	return s.store.History(ctx, query)
+}
+
+// handleInvalidation applies the changes made on the other instances
+func (s *Service) handleInvalidation(ctx context.Context, inv cacheinvalidation.Invalidation) {
+	if !inv.Remote {
+		return
+	}
+	if err := s.reload(ctx); err != nil {
+		s.log.Error("Failed to reload the feature toggles changed at runtime", "key", inv.Key, "error", err)
+	}
+}
+
+// reload applies the values stored in the database
+func (s *Service) reload(ctx context.Context) error {
+	overrides, err := s.store.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	global := map[string]bool{}
+	orgs := map[int64]map[string]bool{}
+	for _, o := range overrides {
+		if o.OrgID == 0 {
+			global[o.Name] = o.Enabled
+			continue
+		}
+		if orgs[o.OrgID] == nil {
+			orgs[o.OrgID] = map[string]bool{}
+		}
+		orgs[o.OrgID][o.Name] = o.Enabled
+	}
+	s.features.SetRuntimeValues(ctx, global, orgs)
+	return nil
+}
+
+// value returns the value of the toggle in the organization, or the global value when orgID is 0
+func (s *Service) value(name string, orgID int64, global map[string]bool, orgs map[int64]map[string]bool) bool {
+	if v, ok := orgs[orgID][name]; ok && orgID > 0 {
+		return v
+	}
+	if v, ok := global[name]; ok {
+		return v
+	}
+	return s.startupValue(name)
+}
+
+func (s *Service) startupValue(name string) bool {
+	if v, ok := s.features.GetStartupFlags()[name]; ok {
+		return v
+	}
+	for _, flag := range s.features.GetFlags() {
+		if flag.Name == name {
+			return flag.Expression == "true"
+		}
+	}
+	return false
+}
+
+func (s *Service) isKnown(name string) bool {
+	for _, flag := range s.features.GetFlags() {
+		if flag.Name == name {
+			return true
+		}
+	}
+	return false
+}
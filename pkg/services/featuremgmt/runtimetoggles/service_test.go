@@ -0,0 +1,115 @@
+package runtimetoggles
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/cacheinvalidation/cacheinvalidationtest"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func setupService(t *testing.T) (*Service, *featuremgmt.FeatureManager, *xormStore) {
+	t.Helper()
+	features := featuremgmt.WithFeatureManager(setting.FeatureMgmtSettings{RuntimeEditing: true}, []*featuremgmt.FeatureFlag{
+		{Name: "backend", Stage: featuremgmt.FeatureStageGeneralAvailability},
+		{Name: "frontend", Stage: featuremgmt.FeatureStagePublicPreview, FrontendOnly: true},
+		{Name: "restart", Stage: featuremgmt.FeatureStageGeneralAvailability, RequiresRestart: true},
+	}, "backend", "frontend")
+	store := &xormStore{db: db.InitTestDB(t)}
+	s := &Service{
+		enabled:       true,
+		store:         store,
+		features:      features,
+		invalidations: cacheinvalidationtest.NewFakeService(),
+		log:           log.NewNopLogger(),
+	}
+	return s, features, store
+}
+
+func TestIntegrationService(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	admin := &user.SignedInUser{UserID: 1, Login: "admin", OrgID: 1}
+	enabled, disabled := true, false
+
+	t.Run("changes the toggles at runtime and records the changes", func(t *testing.T) {
+		s, features, store := setupService(t)
+		var changes []featuremgmt.ToggleChange
+		features.Watch(func(_ context.Context, change featuremgmt.ToggleChange) {
+			changes = append(changes, change)
+		}, "backend")
+
+		require.NoError(t, s.SetToggle(ctx, &SetToggleCommand{Name: "backend", Enabled: &enabled, User: admin}))
+		assert.True(t, features.IsEnabledGlobally("backend"))
+		assert.Equal(t, []featuremgmt.ToggleChange{{Flag: "backend", Enabled: true}}, changes)
+
+		require.NoError(t, s.SetToggle(ctx, &SetToggleCommand{Name: "backend", User: admin}))
+		assert.False(t, features.IsEnabledGlobally("backend"), "the startup value applies once the toggle is reset")
+
+		history, err := store.History(ctx, HistoryQuery{OrgID: -1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.Equal(t, Change{ID: history[1].ID, Name: "backend", Previous: false, Value: true, UserID: 1, UserLogin: "admin", Created: history[1].Created}, *history[1])
+		assert.True(t, history[0].Reset)
+		assert.False(t, history[0].Value)
+	})
+
+	t.Run("doesn't record the changes which don't change anything", func(t *testing.T) {
+		s, _, store := setupService(t)
+
+		require.NoError(t, s.SetToggle(ctx, &SetToggleCommand{Name: "backend", User: admin}))
+		require.NoError(t, s.SetToggle(ctx, &SetToggleCommand{Name: "backend", Enabled: &disabled, User: admin}))
+		require.NoError(t, s.SetToggle(ctx, &SetToggleCommand{Name: "backend", Enabled: &disabled, User: admin}))
+		history, err := store.History(ctx, HistoryQuery{OrgID: -1, Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, history, 1)
+	})
+
+	t.Run("overrides the frontend toggles for an organization", func(t *testing.T) {
+		s, features, _ := setupService(t)
+
+		require.NoError(t, s.SetToggle(ctx, &SetToggleCommand{Name: "frontend", OrgID: 2, Enabled: &enabled, User: admin}))
+
+		org1 := identity.WithRequester(ctx, &user.SignedInUser{OrgID: 1})
+		org2 := identity.WithRequester(ctx, &user.SignedInUser{OrgID: 2})
+		assert.False(t, features.IsEnabled(org1, "frontend"))
+		assert.True(t, features.IsEnabled(org2, "frontend"))
+		assert.True(t, features.GetEnabled(org2)["frontend"])
+		assert.False(t, features.IsEnabledGlobally("frontend"))
+
+		toggles := s.GetToggles(ctx)
+		require.Len(t, toggles, 2)
+		assert.Equal(t, map[int64]bool{2: true}, toggles[1].OrgOverrides)
+	})
+
+	t.Run("rejects the toggles which can't be changed at runtime", func(t *testing.T) {
+		s, _, _ := setupService(t)
+
+		assert.ErrorIs(t, s.SetToggle(ctx, &SetToggleCommand{Name: "restart", Enabled: &enabled}), ErrToggleNotEditable)
+		assert.ErrorIs(t, s.SetToggle(ctx, &SetToggleCommand{Name: "unknown", Enabled: &enabled}), ErrToggleNotFound)
+		assert.ErrorIs(t, s.SetToggle(ctx, &SetToggleCommand{Name: "backend", OrgID: 2, Enabled: &enabled}), ErrOrgOverrideForbidden)
+	})
+
+	t.Run("applies the values changed by other instances", func(t *testing.T) {
+		s, features, store := setupService(t)
+		require.NoError(t, store.Set(ctx, 0, "backend", &enabled, 1, &Change{Name: "backend", Value: true}))
+
+		require.NoError(t, s.reload(ctx))
+		assert.True(t, features.IsEnabledGlobally("backend"))
+	})
+}
@@ -0,0 +1,64 @@
+package runtimetoggles
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+type store interface {
+	// List returns the values set at runtime of every organization
+	List(ctx context.Context) ([]*override, error)
+	// Set sets or, when enabled is nil, removes the value of the toggle and records the change in the same
+	// transaction
+	Set(ctx context.Context, orgID int64, name string, enabled *bool, userID int64, change *Change) error
+	History(ctx context.Context, query HistoryQuery) ([]*Change, error)
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (s *xormStore) List(ctx context.Context) ([]*override, error) {
+	var overrides []*override
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Find(&overrides)
+	})
+	return overrides, err
+}
+
+func (s *xormStore) Set(ctx context.Context, orgID int64, name string, enabled *bool, userID int64, change *Change) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("DELETE FROM feature_toggle_override WHERE org_id = ? AND name = ?", orgID, name); err != nil {
+			return err
+		}
+		if enabled != nil {
+			if _, err := sess.Insert(&override{
+				OrgID:     orgID,
+				Name:      name,
+				Enabled:   *enabled,
+				UpdatedBy: userID,
+				Updated:   time.Now(),
+			}); err != nil {
+				return err
+			}
+		}
+		_, err := sess.Insert(change)
+		return err
+	})
+}
+
+func (s *xormStore) History(ctx context.Context, query HistoryQuery) ([]*Change, error) {
+	var changes []*Change
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		if query.Name != "" {
+			sess.Where("name = ?", query.Name)
+		}
+		if query.OrgID >= 0 {
+			sess.Where("org_id = ?", query.OrgID)
+		}
+		return sess.Desc("id").Limit(query.Limit).Find(&changes)
+	})
+	return changes, err
+}
@@ -0,0 +1,64 @@
+package runtimetoggles
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	store := &xormStore{db: db.InitTestDB(t)}
+	enabled, disabled := true, false
+
+	t.Run("should set, replace and remove the values", func(t *testing.T) {
+		require.NoError(t, store.Set(ctx, 0, "backend", &enabled, 1, &Change{Name: "backend", Value: true, UserID: 1}))
+		require.NoError(t, store.Set(ctx, 0, "backend", &disabled, 1, &Change{Name: "backend", Previous: true, UserID: 1}))
+		require.NoError(t, store.Set(ctx, 2, "frontend", &enabled, 1, &Change{OrgID: 2, Name: "frontend", Value: true, UserID: 1}))
+		require.NoError(t, store.Set(ctx, 3, "frontend", &enabled, 1, &Change{OrgID: 3, Name: "frontend", Value: true, UserID: 1}))
+		require.NoError(t, store.Set(ctx, 3, "frontend", nil, 1, &Change{OrgID: 3, Name: "frontend", Reset: true, UserID: 1}))
+
+		overrides, err := store.List(ctx)
+		require.NoError(t, err)
+		values := map[int64]map[string]bool{}
+		for _, o := range overrides {
+			if values[o.OrgID] == nil {
+				values[o.OrgID] = map[string]bool{}
+			}
+			values[o.OrgID][o.Name] = o.Enabled
+			assert.Equal(t, int64(1), o.UpdatedBy)
+		}
+		assert.Equal(t, map[int64]map[string]bool{0: {"backend": false}, 2: {"frontend": true}}, values,
+			"a toggle has one value by org and the removed values are deleted")
+	})
+
+	t.Run("should filter the history, the most recent first", func(t *testing.T) {
+		history, err := store.History(ctx, HistoryQuery{OrgID: -1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, history, 5)
+		assert.True(t, history[0].Reset)
+		assert.Equal(t, "backend", history[4].Name)
+		assert.False(t, history[4].Created.IsZero())
+
+		history, err = store.History(ctx, HistoryQuery{Name: "backend", OrgID: 0, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.True(t, history[0].Previous)
+		assert.True(t, history[1].Value)
+
+		history, err = store.History(ctx, HistoryQuery{Name: "frontend", OrgID: 3, Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, history, 2)
+
+		history, err = store.History(ctx, HistoryQuery{OrgID: -1, Limit: 2})
+		require.NoError(t, err)
+		assert.Len(t, history, 2)
+	})
+}
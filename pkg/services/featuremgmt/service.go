@@ -3,6 +3,7 @@ package featuremgmt
 import (
 	"sort"
 
+	"github.com/open-feature/go-sdk/openfeature"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"golang.org/x/exp/maps"
@@ -54,6 +55,12 @@ func ProvideManagerService(cfg *setting.Cfg) (*FeatureManager, error) {
 	// update the values
 	mgmt.update()
 
+	// Evaluate the flags with the provider set up by InitOpenFeatureWithCfg
+	if cfg.OpenFeature.IsExternalProvider() {
+		mgmt.provider = newProviderBridge(cfg.OpenFeature, openfeature.GetApiInstance().GetClient())
+		mgmt.log.Info("Evaluating the feature toggles with an external provider", "provider", cfg.OpenFeature.ProviderType)
+	}
+
 	// Log the enabled feature toggles at startup
 	enabled := sort.StringSlice(maps.Keys(mgmt.enabled))
 	logctx := make([]any, len(enabled)*2)
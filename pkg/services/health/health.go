@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// The components checked by default
+const (
+	ComponentDatabase          = "database"
+	ComponentRemoteCache       = "remote_cache"
+	ComponentPlugins           = "plugins"
+	ComponentRenderer          = "renderer"
+	ComponentUnifiedStorage    = "unified_storage"
+	ComponentAlertingScheduler = "alerting_scheduler"
+)
+
+type Status string
+
+const (
+	StatusOK      Status = "ok"
+	StatusFailing Status = "failing"
+	// StatusDisabled is the status of the components that aren't enabled on the instance
+	StatusDisabled Status = "disabled"
+	// StatusDegraded is the status of the instance when components that don't fail its readiness are failing
+	StatusDegraded Status = "degraded"
+)
+
+// ErrDisabled is returned by the checks of the components that aren't enabled on the instance
+var ErrDisabled = errors.New("component is disabled")
+
+// CheckFunc checks the health of a component, it returns ErrDisabled when the component isn't enabled.
+type CheckFunc func(ctx context.Context) error
+
+// Service checks the health of the components Grafana depends on, so that load balancers only send requests to the
+// instances able to handle them and operators can see which component is failing.
+type Service interface {
+	// Register adds a component to the checked components.
+	Register(name string, check CheckFunc)
+	// Check returns the health of the components, the results of the checks are reused for a few seconds.
+	Check(ctx context.Context) *Report
+}
+
+// Report is the health of the instance and of its components.
+type Report struct {
+	// Status is failing when a component required for the readiness is failing, degraded when another component is
+	Status     Status      `json:"status"`
+	Components []Component `json:"components"`
+}
+
+// Ready returns true when the components required for the readiness of the instance aren't failing.
+func (r *Report) Ready() bool {
+	return r.Status != StatusFailing
+}
+
+// Component returns the health of the named component.
+func (r *Report) Component(name string) (Component, bool) {
+	for _, component := range r.Components {
+		if component.Name == name {
+			return component, true
+		}
+	}
+	return Component{}, false
+}
+
+// Component is the health of a component.
+type Component struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	// Required is true when the component fails the readiness of the instance
+	Required bool `json:"required"`
+	// LatencyMs is the duration of the check in milliseconds
+	LatencyMs int64     `json:"latencyMs"`
+	CheckedAt time.Time `json:"checkedAt"`
+	// Error is the reason the component is failing
+	Error string `json:"error,omitempty"`
+}
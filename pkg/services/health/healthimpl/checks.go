@@ -0,0 +1,104 @@
+package healthimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/manager/registry"
+	"github.com/grafana/grafana/pkg/services/health"
+	"github.com/grafana/grafana/pkg/services/ngalert"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+// missedTicks is the number of ticks the alerting scheduler can miss before it's failing
+const missedTicks = 3
+
+func checkDatabase(sqlStore db.DB) health.CheckFunc {
+	return func(ctx context.Context) error {
+		return sqlStore.WithDbSession(ctx, func(session *db.Session) error {
+			_, err := session.Query("SELECT 1")
+			return err
+		})
+	}
+}
+
+func checkRemoteCache(cache remotecache.CacheStorage) health.CheckFunc {
+	return func(ctx context.Context) error {
+		_, err := cache.Get(ctx, "health-check")
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return nil
+		}
+		return err
+	}
+}
+
+// checkPlugins fails when the process of an external backend plugin exited
+func checkPlugins(pluginRegistry registry.Service) health.CheckFunc {
+	return func(ctx context.Context) error {
+		exited := []string{}
+		for _, p := range pluginRegistry.Plugins(ctx) {
+			if p.Backend && p.Target() == backendplugin.TargetLocal && !p.IsDecommissioned() && p.Exited() {
+				exited = append(exited, p.ID)
+			}
+		}
+		if len(exited) > 0 {
+			return fmt.Errorf("backend plugins aren't running: %s", strings.Join(exited, ", "))
+		}
+		return nil
+	}
+}
+
+func checkRenderer(renderService rendering.Service) health.CheckFunc {
+	return func(ctx context.Context) error {
+		if !renderService.IsAvailable(ctx) {
+			return health.ErrDisabled
+		}
+		// the version is read from the renderer when it's started or reachable
+		if renderService.Version() == "" {
+			return errors.New("the version of the renderer is unknown, it may be unreachable")
+		}
+		return nil
+	}
+}
+
+func checkUnifiedStorage(resourceClient resource.ResourceClient) health.CheckFunc {
+	return func(ctx context.Context) error {
+		if resourceClient == nil {
+			return health.ErrDisabled
+		}
+		resp, err := resourceClient.IsHealthy(ctx, &resourcepb.HealthCheckRequest{})
+		if err != nil {
+			return err
+		}
+		if resp.Status != resourcepb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("unified storage is %s", resp.Status)
+		}
+		return nil
+	}
+}
+
+// checkAlertingScheduler fails when the scheduler of the alert rules missed ticks
+func checkAlertingScheduler(cfg *setting.Cfg, alertNG *ngalert.AlertNG) health.CheckFunc {
+	return func(ctx context.Context) error {
+		if alertNG == nil || alertNG.IsDisabled() || !cfg.UnifiedAlerting.ExecuteAlerts {
+			return health.ErrDisabled
+		}
+		lastTick := alertNG.SchedulerLastTick()
+		if lastTick.IsZero() {
+			return errors.New("the scheduler hasn't evaluated the alert rules yet")
+		}
+		if behind := time.Since(lastTick); behind > missedTicks*cfg.UnifiedAlerting.BaseInterval {
+			return fmt.Errorf("the scheduler last evaluated the alert rules %s ago", behind.Round(time.Second))
+		}
+		return nil
+	}
+}
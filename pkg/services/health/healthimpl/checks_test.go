@@ -0,0 +1,39 @@
+package healthimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationChecks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+
+	t.Run("should query the database", func(t *testing.T) {
+		check := checkDatabase(db.InitTestDB(t))
+		assert.NoError(t, check(ctx))
+	})
+
+	t.Run("should read from the remote cache, the health item doesn't need to exist", func(t *testing.T) {
+		cache := remotecache.NewFakeStore(t)
+		check := checkRemoteCache(cache)
+		assert.NoError(t, check(ctx))
+
+		require.NoError(t, cache.Set(ctx, "health-check", []byte("ok"), time.Minute))
+		assert.NoError(t, check(ctx))
+	})
+}
@@ -0,0 +1,128 @@
+package healthimpl
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/plugins/manager/registry"
+	"github.com/grafana/grafana/pkg/services/health"
+	"github.com/grafana/grafana/pkg/services/ngalert"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+var _ health.Service = (*Service)(nil)
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, remoteCache remotecache.CacheStorage, pluginRegistry registry.Service,
+	renderService rendering.Service, resourceClient resource.ResourceClient, alertNG *ngalert.AlertNG) *Service {
+	s := &Service{
+		cfg: cfg.Health,
+		log: log.New("health"),
+		now: time.Now,
+	}
+
+	s.Register(health.ComponentDatabase, checkDatabase(sqlStore))
+	s.Register(health.ComponentRemoteCache, checkRemoteCache(remoteCache))
+	s.Register(health.ComponentPlugins, checkPlugins(pluginRegistry))
+	s.Register(health.ComponentRenderer, checkRenderer(renderService))
+	s.Register(health.ComponentUnifiedStorage, checkUnifiedStorage(resourceClient))
+	s.Register(health.ComponentAlertingScheduler, checkAlertingScheduler(cfg, alertNG))
+
+	return s
+}
+
+// Service runs the checks of the components concurrently, and reuses their results for the configured TTL so that
+// frequent probes don't load the components.
+type Service struct {
+	cfg setting.HealthSettings
+	log log.Logger
+	now func() time.Time
+
+	mu         sync.Mutex
+	components []component
+	report     *health.Report
+	reportedAt time.Time
+}
+
+type component struct {
+	name  string
+	check health.CheckFunc
+}
+
+func (s *Service) Register(name string, check health.CheckFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.components = append(s.components, component{name: name, check: check})
+	s.report = nil
+}
+
+func (s *Service) Check(ctx context.Context) *health.Report {
+	// the lock is kept during the checks, so that the concurrent probes wait for the same results
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.report != nil && s.now().Sub(s.reportedAt) < s.cfg.CacheTTL {
+		return s.report
+	}
+
+	report := &health.Report{
+		Status:     health.StatusOK,
+		Components: make([]health.Component, len(s.components)),
+	}
+	var wg sync.WaitGroup
+	for i, c := range s.components {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Components[i] = s.checkComponent(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	for _, c := range report.Components {
+		if c.Status != health.StatusFailing {
+			continue
+		}
+		if c.Required {
+			report.Status = health.StatusFailing
+		} else if report.Status == health.StatusOK {
+			report.Status = health.StatusDegraded
+		}
+	}
+
+	s.report = report
+	s.reportedAt = s.now()
+	return report
+}
+
+func (s *Service) checkComponent(ctx context.Context, c component) health.Component {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.CheckTimeout)
+	defer cancel()
+
+	start := s.now()
+	err := c.check(ctx)
+	result := health.Component{
+		Name:      c.name,
+		Status:    health.StatusOK,
+		Required:  s.cfg.ReadyComponents[c.name],
+		LatencyMs: s.now().Sub(start).Milliseconds(),
+		CheckedAt: start,
+	}
+
+	switch {
+	case errors.Is(err, health.ErrDisabled):
+		result.Status = health.StatusDisabled
+	case err != nil:
+		result.Status = health.StatusFailing
+		result.Error = err.Error()
+		s.log.Warn("Component is unhealthy", "component", c.name, "required", result.Required, "error", err)
+	}
+	return result
+}
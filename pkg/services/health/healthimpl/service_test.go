@@ -0,0 +1,102 @@
+package healthimpl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/health"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService_Check(t *testing.T) {
+	ctx := context.Background()
+	ok := func(context.Context) error { return nil }
+	failing := func(context.Context) error { return errors.New("connection refused") }
+	disabled := func(context.Context) error { return health.ErrDisabled }
+
+	t.Run("should be ok when the components are ok or disabled", func(t *testing.T) {
+		s := setupTestService(setting.HealthSettings{ReadyComponents: map[string]bool{health.ComponentDatabase: true}})
+		s.Register(health.ComponentDatabase, ok)
+		s.Register(health.ComponentRenderer, disabled)
+
+		report := s.Check(ctx)
+		assert.Equal(t, health.StatusOK, report.Status)
+		assert.True(t, report.Ready())
+		renderer, found := report.Component(health.ComponentRenderer)
+		require.True(t, found)
+		assert.Equal(t, health.StatusDisabled, renderer.Status)
+		database, found := report.Component(health.ComponentDatabase)
+		require.True(t, found)
+		assert.True(t, database.Required)
+	})
+
+	t.Run("should be degraded when a component not required for the readiness is failing", func(t *testing.T) {
+		s := setupTestService(setting.HealthSettings{ReadyComponents: map[string]bool{health.ComponentDatabase: true}})
+		s.Register(health.ComponentDatabase, ok)
+		s.Register(health.ComponentRemoteCache, failing)
+
+		report := s.Check(ctx)
+		assert.Equal(t, health.StatusDegraded, report.Status)
+		assert.True(t, report.Ready())
+		cache, _ := report.Component(health.ComponentRemoteCache)
+		assert.Equal(t, health.StatusFailing, cache.Status)
+		assert.Equal(t, "connection refused", cache.Error)
+	})
+
+	t.Run("should be failing when a component required for the readiness is failing", func(t *testing.T) {
+		s := setupTestService(setting.HealthSettings{ReadyComponents: map[string]bool{health.ComponentDatabase: true}})
+		s.Register(health.ComponentDatabase, failing)
+		s.Register(health.ComponentRemoteCache, failing)
+
+		report := s.Check(ctx)
+		assert.Equal(t, health.StatusFailing, report.Status)
+		assert.False(t, report.Ready())
+	})
+
+	t.Run("should fail the checks over the timeout", func(t *testing.T) {
+		s := setupTestService(setting.HealthSettings{})
+		s.cfg.CheckTimeout = 10 * time.Millisecond
+		s.Register(health.ComponentUnifiedStorage, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		component, _ := s.Check(ctx).Component(health.ComponentUnifiedStorage)
+		assert.Equal(t, health.StatusFailing, component.Status)
+		assert.Equal(t, context.DeadlineExceeded.Error(), component.Error)
+	})
+
+	t.Run("should reuse the results until the cache TTL is over", func(t *testing.T) {
+		s := setupTestService(setting.HealthSettings{CacheTTL: 5 * time.Second})
+		checks := 0
+		s.Register(health.ComponentDatabase, func(context.Context) error {
+			checks++
+			return nil
+		})
+
+		s.Check(ctx)
+		s.Check(ctx)
+		assert.Equal(t, 1, checks)
+
+		s.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 5, 0, time.UTC) }
+		s.Check(ctx)
+		assert.Equal(t, 2, checks)
+	})
+}
+
+func setupTestService(cfg setting.HealthSettings) *Service {
+	if cfg.CheckTimeout == 0 {
+		cfg.CheckTimeout = time.Second
+	}
+	return &Service{
+		cfg: cfg,
+		log: log.NewNopLogger(),
+		now: func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) },
+	}
+}
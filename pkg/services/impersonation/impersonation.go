@@ -0,0 +1,105 @@
+package impersonation
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/models/usertoken"
+)
+
+var (
+	ErrDisabledForOrg = errutil.Forbidden(
+		"impersonation.disabled-for-org", errutil.WithPublicMessage("Impersonation is disabled for this organization"))
+	ErrTargetNotAllowed = errutil.BadRequest(
+		"impersonation.target-not-allowed", errutil.WithPublicMessage("This user cannot be impersonated"))
+	ErrNested = errutil.BadRequest(
+		"impersonation.nested", errutil.WithPublicMessage("Cannot impersonate a user while impersonating another one"))
+	ErrNotImpersonating = errutil.BadRequest(
+		"impersonation.not-impersonating", errutil.WithPublicMessage("The session is not an impersonation session"))
+	ErrSessionNotFound = errutil.NotFound(
+		"impersonation.not-found", errutil.WithPublicMessage("Impersonation session not found"))
+	ErrSessionExpired = errutil.Unauthorized(
+		"impersonation.expired", errutil.WithPublicMessage("The impersonation session has ended"))
+	ErrOrgMismatch = errutil.Forbidden(
+		"impersonation.org-mismatch", errutil.WithPublicMessage("Impersonation sessions are limited to the organization they were started in"))
+)
+
+// Reasons recorded when an impersonation session ends
+const (
+	EndReasonStopped    = "stopped"
+	EndReasonExpired    = "expired"
+	EndReasonTerminated = "terminated"
+	EndReasonOrgOptOut  = "org_disabled"
+)
+
+type Service interface {
+	// Start issues a session token for the target user on behalf of the impersonator.
+	// The current session of the impersonator is revoked, it is restored when the impersonation stops.
+	Start(ctx context.Context, cmd *StartCommand) (*Session, *usertoken.UserToken, error)
+	// Stop ends the impersonation session and issues a new session token for the impersonator
+	Stop(ctx context.Context, cmd *StopCommand) (*usertoken.UserToken, error)
+	// Terminate ends the impersonation session without signing the impersonator back in
+	Terminate(ctx context.Context, uid string) error
+	Search(ctx context.Context, query *SearchQuery) ([]*Session, error)
+	GetOrgSettings(ctx context.Context, orgID int64) (*OrgSettings, error)
+	// SetOrgSettings updates the settings of the org, disabling impersonation ends the active sessions of the org
+	SetOrgSettings(ctx context.Context, settings *OrgSettings) error
+}
+
+type Session struct {
+	UID               string    `json:"uid"`
+	OrgID             int64     `json:"orgId"`
+	ImpersonatorID    int64     `json:"impersonatorId"`
+	ImpersonatorLogin string    `json:"impersonatorLogin"`
+	TargetUserID      int64     `json:"targetUserId"`
+	TargetLogin       string    `json:"targetLogin"`
+	Reason            string    `json:"reason"`
+	Created           time.Time `json:"created"`
+	Expires           time.Time `json:"expires"`
+	// Ended is nil while the session is active
+	Ended     *time.Time `json:"ended,omitempty"`
+	EndReason string     `json:"endReason,omitempty"`
+}
+
+func (s *Session) IsActive(now time.Time) bool {
+	return s.Ended == nil && now.Before(s.Expires)
+}
+
+type StartCommand struct {
+	OrgID             int64
+	ImpersonatorID    int64
+	ImpersonatorLogin string
+	// ImpersonatorToken is the current session token of the impersonator
+	ImpersonatorToken *usertoken.UserToken
+	TargetUserID      int64
+	Reason            string
+	ClientIP          net.IP
+	UserAgent         string
+}
+
+type StopCommand struct {
+	UID       string
+	ClientIP  net.IP
+	UserAgent string
+}
+
+type SearchQuery struct {
+	OrgID          int64
+	ImpersonatorID int64
+	TargetUserID   int64
+	ActiveOnly     bool
+	Limit          int
+}
+
+type OrgSettings struct {
+	OrgID int64 `json:"-"`
+	// Enabled allows impersonating the members of the organization
+	Enabled bool `json:"enabled"`
+}
+
+type StartImpersonationForm struct {
+	// Reason is recorded in the audit trail of the session
+	Reason string `json:"reason" binding:"Required"`
+}
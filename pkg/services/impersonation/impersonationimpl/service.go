@@ -0,0 +1,365 @@
+package impersonationimpl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models/usertoken"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/impersonation"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var _ impersonation.Service = (*Service)(nil)
+
+// sessionCacheTTL bounds how long an instance keeps resolving a session token to its impersonation
+// session, ending a session revokes its token so the cache never extends it.
+const sessionCacheTTL = time.Minute
+
+func ProvideService(
+	cfg *setting.Cfg, sqlStore db.DB, sessionService auth.UserTokenService, userService user.Service,
+	orgService org.Service, authnService authn.Service, tracer trace.Tracer,
+) *Service {
+	s := &Service{
+		cfg:            cfg,
+		store:          &xormStore{db: sqlStore, now: time.Now},
+		sessionService: sessionService,
+		userService:    userService,
+		orgService:     orgService,
+		sessions:       localcache.New(sessionCacheTTL, 2*sessionCacheTTL),
+		log:            log.New("impersonation"),
+		audit:          log.New("impersonation.audit"),
+		tracer:         tracer,
+		now:            time.Now,
+	}
+
+	// The hook is registered even when impersonation is disabled so sessions started
+	// before it was disabled are ended instead of turning into regular sessions.
+	// It runs before the signed in user is fetched, so it can pin the org of the session.
+	authnService.RegisterPostAuthHook(s.impersonationHook, 95)
+
+	return s
+}
+
+type Service struct {
+	cfg            *setting.Cfg
+	store          store
+	sessionService auth.UserTokenService
+	userService    user.Service
+	orgService     org.Service
+	// sessions caches the impersonation session of session tokens, nil for regular sessions
+	sessions *localcache.CacheService
+	log      log.Logger
+	audit    log.Logger
+	tracer   trace.Tracer
+	now      func() time.Time
+}
+
+func (s *Service) Start(ctx context.Context, cmd *impersonation.StartCommand) (*impersonation.Session, *usertoken.UserToken, error) {
+	ctx, span := s.tracer.Start(ctx, "impersonation.Start")
+	defer span.End()
+
+	if cmd.ImpersonatorToken != nil {
+		current, err := s.sessionForToken(ctx, cmd.ImpersonatorToken.Id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if current != nil {
+			return nil, nil, impersonation.ErrNested.Errorf("session %s is an impersonation session", current.UID)
+		}
+	}
+
+	settings, err := s.GetOrgSettings(ctx, cmd.OrgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !settings.Enabled {
+		return nil, nil, impersonation.ErrDisabledForOrg.Errorf("impersonation is disabled for org %d", cmd.OrgID)
+	}
+
+	target, err := s.checkTarget(ctx, cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token, err := s.sessionService.CreateToken(ctx, &auth.CreateTokenCommand{User: target, ClientIP: cmd.ClientIP, UserAgent: cmd.UserAgent})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := s.now()
+	session := &userImpersonation{
+		UID:               util.GenerateShortUID(),
+		OrgID:             cmd.OrgID,
+		ImpersonatorID:    cmd.ImpersonatorID,
+		ImpersonatorLogin: cmd.ImpersonatorLogin,
+		TargetUserID:      target.ID,
+		TargetLogin:       target.Login,
+		Reason:            cmd.Reason,
+		UserTokenID:       token.Id,
+		Created:           now,
+		Expires:           now.Add(s.cfg.ImpersonationAuth.MaxDuration),
+	}
+	if err := s.store.Insert(ctx, session); err != nil {
+		if revokeErr := s.sessionService.RevokeToken(ctx, token, false); revokeErr != nil {
+			s.log.FromContext(ctx).Error("Failed to revoke impersonation token", "error", revokeErr)
+		}
+		return nil, nil, err
+	}
+
+	// The impersonator gets a new session when the impersonation stops
+	if cmd.ImpersonatorToken != nil {
+		if err := s.sessionService.RevokeToken(ctx, cmd.ImpersonatorToken, false); err != nil {
+			s.log.FromContext(ctx).Error("Failed to revoke session of impersonator", "impersonatorId", cmd.ImpersonatorID, "error", err)
+		}
+	}
+
+	s.audit.FromContext(ctx).Info("Impersonation started",
+		"impersonationUid", session.UID,
+		"orgId", session.OrgID,
+		"impersonatorId", session.ImpersonatorID,
+		"impersonatorLogin", session.ImpersonatorLogin,
+		"userId", session.TargetUserID,
+		"uname", session.TargetLogin,
+		"reason", session.Reason,
+		"expires", session.Expires,
+	)
+
+	return session.toSession(), token, nil
+}
+
+func (s *Service) checkTarget(ctx context.Context, cmd *impersonation.StartCommand) (*user.User, error) {
+	if cmd.TargetUserID == cmd.ImpersonatorID {
+		return nil, impersonation.ErrTargetNotAllowed.Errorf("user %d cannot impersonate themselves", cmd.ImpersonatorID)
+	}
+
+	target, err := s.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: cmd.TargetUserID})
+	if err != nil {
+		return nil, err
+	}
+
+	// Impersonating a server admin would grant permissions the impersonator may not have
+	if target.IsAdmin || target.IsServiceAccount || target.IsDisabled {
+		return nil, impersonation.ErrTargetNotAllowed.Errorf("user %d is an admin, a service account or disabled", target.ID)
+	}
+
+	orgs, err := s.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: target.ID})
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range orgs {
+		if o.OrgID == cmd.OrgID {
+			return target, nil
+		}
+	}
+	return nil, impersonation.ErrTargetNotAllowed.Errorf("user %d is not a member of org %d", target.ID, cmd.OrgID)
+}
+
+func (s *Service) Stop(ctx context.Context, cmd *impersonation.StopCommand) (*usertoken.UserToken, error) {
+	ctx, span := s.tracer.Start(ctx, "impersonation.Stop")
+	defer span.End()
+
+	session, err := s.getActive(ctx, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.end(ctx, session, impersonation.EndReasonStopped); err != nil {
+		return nil, err
+	}
+
+	impersonator, err := s.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: session.ImpersonatorID})
+	if err != nil {
+		return nil, err
+	}
+	return s.sessionService.CreateToken(ctx, &auth.CreateTokenCommand{User: impersonator, ClientIP: cmd.ClientIP, UserAgent: cmd.UserAgent})
+}
+
+func (s *Service) Terminate(ctx context.Context, uid string) error {
+	ctx, span := s.tracer.Start(ctx, "impersonation.Terminate")
+	defer span.End()
+
+	session, err := s.getActive(ctx, uid)
+	if err != nil {
+		return err
+	}
+	return s.end(ctx, session, impersonation.EndReasonTerminated)
+}
+
+func (s *Service) Search(ctx context.Context, query *impersonation.SearchQuery) ([]*impersonation.Session, error) {
+	found, err := s.store.Search(ctx, query, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*impersonation.Session, 0, len(found))
+	for _, session := range found {
+		sessions = append(sessions, session.toSession())
+	}
+	return sessions, nil
+}
+
+func (s *Service) GetOrgSettings(ctx context.Context, orgID int64) (*impersonation.OrgSettings, error) {
+	settings, err := s.store.GetOrgSettings(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return &impersonation.OrgSettings{OrgID: orgID, Enabled: settings.Enabled}, nil
+}
+
+func (s *Service) SetOrgSettings(ctx context.Context, settings *impersonation.OrgSettings) error {
+	ctx, span := s.tracer.Start(ctx, "impersonation.SetOrgSettings")
+	defer span.End()
+
+	if err := s.store.SaveOrgSettings(ctx, &orgImpersonationSettings{OrgID: settings.OrgID, Enabled: settings.Enabled}); err != nil {
+		return err
+	}
+	if settings.Enabled {
+		return nil
+	}
+
+	active, err := s.store.Search(ctx, &impersonation.SearchQuery{OrgID: settings.OrgID, ActiveOnly: true}, s.now())
+	if err != nil {
+		return err
+	}
+	for _, session := range active {
+		if err := s.end(ctx, session, impersonation.EndReasonOrgOptOut); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// impersonationHook pins impersonation sessions to the org and lifetime they were started with,
+// and records the impersonator on the identity so every request carries both users.
+func (s *Service) impersonationHook(ctx context.Context, id *authn.Identity, r *authn.Request) error {
+	if id.SessionToken == nil {
+		return nil
+	}
+
+	session, err := s.sessionForToken(ctx, id.SessionToken.Id)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return nil
+	}
+
+	ctx, span := s.tracer.Start(ctx, "impersonation.impersonationHook")
+	defer span.End()
+
+	if !s.cfg.ImpersonationAuth.Enabled || !session.toSession().IsActive(s.now()) {
+		if err := s.end(ctx, session, impersonation.EndReasonExpired); err != nil {
+			s.log.FromContext(ctx).Error("Failed to end impersonation session", "impersonationUid", session.UID, "error", err)
+		}
+		return impersonation.ErrSessionExpired.Errorf("impersonation session %s has ended", session.UID)
+	}
+
+	if r.OrgID == 0 {
+		r.OrgID = session.OrgID
+	} else if r.OrgID != session.OrgID {
+		return impersonation.ErrOrgMismatch.Errorf("impersonation session %s is limited to org %d", session.UID, session.OrgID)
+	}
+
+	id.Impersonator = &user.Impersonator{
+		UserID:     session.ImpersonatorID,
+		Login:      session.ImpersonatorLogin,
+		SessionUID: session.UID,
+	}
+
+	if r.HTTPRequest != nil && isAction(r.HTTPRequest.Method) {
+		s.audit.FromContext(ctx).Info("Impersonated request",
+			"impersonationUid", session.UID,
+			"orgId", session.OrgID,
+			"impersonatorId", session.ImpersonatorID,
+			"impersonatorLogin", session.ImpersonatorLogin,
+			"userId", session.TargetUserID,
+			"uname", session.TargetLogin,
+			"method", r.HTTPRequest.Method,
+			"path", r.HTTPRequest.URL.Path,
+		)
+	}
+
+	return nil
+}
+
+// isAction returns true for requests that can change the state of Grafana
+func isAction(method string) bool {
+	return method != http.MethodGet && method != http.MethodHead && method != http.MethodOptions
+}
+
+// sessionForToken returns the active or ended impersonation session of the token, nil for regular sessions
+func (s *Service) sessionForToken(ctx context.Context, userTokenID int64) (*userImpersonation, error) {
+	key := strconv.FormatInt(userTokenID, 10)
+	if cached, ok := s.sessions.Get(key); ok {
+		return cached.(*userImpersonation), nil
+	}
+
+	session, err := s.store.GetByTokenID(ctx, userTokenID)
+	if err != nil && !errors.Is(err, errNotFound) {
+		return nil, err
+	}
+	s.sessions.Set(key, session, 0)
+	return session, nil
+}
+
+func (s *Service) getActive(ctx context.Context, uid string) (*userImpersonation, error) {
+	session, err := s.store.GetByUID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			return nil, impersonation.ErrSessionNotFound.Errorf("impersonation session %s not found", uid)
+		}
+		return nil, err
+	}
+	if !session.toSession().IsActive(s.now()) {
+		return nil, impersonation.ErrSessionExpired.Errorf("impersonation session %s has ended", uid)
+	}
+	return session, nil
+}
+
+// end revokes the session token issued for the impersonation and records why the session ended
+func (s *Service) end(ctx context.Context, session *userImpersonation, reason string) error {
+	token, err := s.sessionService.GetUserToken(ctx, session.TargetUserID, session.UserTokenID)
+	if err != nil && !errors.Is(err, auth.ErrUserTokenNotFound) {
+		return err
+	}
+	if token != nil {
+		if err := s.sessionService.RevokeToken(ctx, token, false); err != nil && !errors.Is(err, auth.ErrUserTokenNotFound) {
+			return err
+		}
+	}
+
+	if session.Ended == nil {
+		ended := s.now()
+		if err := s.store.End(ctx, session.ID, ended, reason); err != nil {
+			return err
+		}
+		session.Ended = &ended
+		session.EndReason = reason
+
+		s.audit.FromContext(ctx).Info("Impersonation ended",
+			"impersonationUid", session.UID,
+			"orgId", session.OrgID,
+			"impersonatorId", session.ImpersonatorID,
+			"impersonatorLogin", session.ImpersonatorLogin,
+			"userId", session.TargetUserID,
+			"uname", session.TargetLogin,
+			"reason", reason,
+		)
+	}
+
+	s.sessions.Delete(strconv.FormatInt(session.UserTokenID, 10))
+	return nil
+}
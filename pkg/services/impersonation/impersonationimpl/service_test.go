@@ -0,0 +1,261 @@
+package impersonationimpl
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/auth/authtest"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/impersonation"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationService_Start(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	impersonatorToken := &auth.UserToken{Id: 10, UserId: 1}
+	newCommand := func() *impersonation.StartCommand {
+		return &impersonation.StartCommand{
+			OrgID:             1,
+			ImpersonatorID:    1,
+			ImpersonatorLogin: "admin",
+			ImpersonatorToken: impersonatorToken,
+			TargetUserID:      2,
+			Reason:            "support ticket 42",
+		}
+	}
+
+	t.Run("should start a session for the target and revoke the session of the impersonator", func(t *testing.T) {
+		env := setupTestEnv(t)
+
+		session, token, err := env.service.Start(ctx, newCommand())
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(2), token.UserId)
+		assert.Equal(t, []int64{impersonatorToken.Id}, env.revoked)
+		assert.Equal(t, "viewer", session.TargetLogin)
+		assert.Equal(t, "support ticket 42", session.Reason)
+		assert.Equal(t, env.now.Add(30*time.Minute), session.Expires)
+
+		stored, err := env.store.GetByTokenID(ctx, token.Id)
+		require.NoError(t, err)
+		assert.Equal(t, session.UID, stored.UID)
+	})
+
+	t.Run("should not start when the org opted out", func(t *testing.T) {
+		env := setupTestEnv(t)
+		require.NoError(t, env.store.SaveOrgSettings(ctx, &orgImpersonationSettings{OrgID: 1, Enabled: false}))
+
+		_, _, err := env.service.Start(ctx, newCommand())
+		assert.ErrorIs(t, err, impersonation.ErrDisabledForOrg)
+	})
+
+	t.Run("should not impersonate server admins", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.users.users[2].IsAdmin = true
+
+		_, _, err := env.service.Start(ctx, newCommand())
+		assert.ErrorIs(t, err, impersonation.ErrTargetNotAllowed)
+	})
+
+	t.Run("should not impersonate users of other orgs", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.orgs.ExpectedUserOrgDTO = []*org.UserOrgDTO{{OrgID: 2, Role: org.RoleViewer}}
+
+		_, _, err := env.service.Start(ctx, newCommand())
+		assert.ErrorIs(t, err, impersonation.ErrTargetNotAllowed)
+	})
+
+	t.Run("should not impersonate from an impersonation session", func(t *testing.T) {
+		env := setupTestEnv(t)
+		require.NoError(t, env.store.Insert(ctx, &userImpersonation{UID: "existing", UserTokenID: impersonatorToken.Id, Expires: env.now.Add(time.Minute)}))
+
+		_, _, err := env.service.Start(ctx, newCommand())
+		assert.ErrorIs(t, err, impersonation.ErrNested)
+	})
+}
+
+func TestIntegrationService_Stop(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	env := setupTestEnv(t)
+
+	session, token, err := env.service.Start(ctx, &impersonation.StartCommand{OrgID: 1, ImpersonatorID: 1, ImpersonatorLogin: "admin", TargetUserID: 2})
+	require.NoError(t, err)
+
+	restored, err := env.service.Stop(ctx, &impersonation.StopCommand{UID: session.UID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), restored.UserId)
+	assert.Equal(t, []int64{token.Id}, env.revoked)
+
+	stored, err := env.store.GetByUID(ctx, session.UID)
+	require.NoError(t, err)
+	assert.Equal(t, impersonation.EndReasonStopped, stored.EndReason)
+
+	_, err = env.service.Stop(ctx, &impersonation.StopCommand{UID: session.UID})
+	assert.ErrorIs(t, err, impersonation.ErrSessionExpired)
+}
+
+func TestIntegrationService_SetOrgSettings(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	env := setupTestEnv(t)
+
+	session, token, err := env.service.Start(ctx, &impersonation.StartCommand{OrgID: 1, ImpersonatorID: 1, ImpersonatorLogin: "admin", TargetUserID: 2})
+	require.NoError(t, err)
+
+	require.NoError(t, env.service.SetOrgSettings(ctx, &impersonation.OrgSettings{OrgID: 1, Enabled: false}))
+
+	settings, err := env.service.GetOrgSettings(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, settings.Enabled)
+	assert.Equal(t, []int64{token.Id}, env.revoked)
+
+	stored, err := env.store.GetByUID(ctx, session.UID)
+	require.NoError(t, err)
+	assert.Equal(t, impersonation.EndReasonOrgOptOut, stored.EndReason)
+}
+
+func TestIntegrationService_impersonationHook(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+
+	t.Run("should ignore regular sessions", func(t *testing.T) {
+		env := setupTestEnv(t)
+		id := &authn.Identity{SessionToken: &auth.UserToken{Id: 99, UserId: 2}}
+
+		require.NoError(t, env.service.impersonationHook(ctx, id, &authn.Request{}))
+		assert.Nil(t, id.Impersonator)
+	})
+
+	t.Run("should record the impersonator and pin the org of the session", func(t *testing.T) {
+		env := setupTestEnv(t)
+		session, token, err := env.service.Start(ctx, &impersonation.StartCommand{OrgID: 1, ImpersonatorID: 1, ImpersonatorLogin: "admin", TargetUserID: 2})
+		require.NoError(t, err)
+
+		id := &authn.Identity{SessionToken: token}
+		r := &authn.Request{HTTPRequest: &http.Request{Method: http.MethodGet}}
+		require.NoError(t, env.service.impersonationHook(ctx, id, r))
+		assert.Equal(t, int64(1), r.OrgID)
+		assert.Equal(t, &user.Impersonator{UserID: 1, Login: "admin", SessionUID: session.UID}, id.Impersonator)
+
+		err = env.service.impersonationHook(ctx, &authn.Identity{SessionToken: token}, &authn.Request{OrgID: 2})
+		assert.ErrorIs(t, err, impersonation.ErrOrgMismatch)
+	})
+
+	t.Run("should end expired sessions", func(t *testing.T) {
+		env := setupTestEnv(t)
+		session, token, err := env.service.Start(ctx, &impersonation.StartCommand{OrgID: 1, ImpersonatorID: 1, ImpersonatorLogin: "admin", TargetUserID: 2})
+		require.NoError(t, err)
+
+		env.now = env.now.Add(31 * time.Minute)
+		err = env.service.impersonationHook(ctx, &authn.Identity{SessionToken: token}, &authn.Request{})
+		assert.ErrorIs(t, err, impersonation.ErrSessionExpired)
+		assert.Equal(t, []int64{token.Id}, env.revoked)
+
+		stored, err := env.store.GetByUID(ctx, session.UID)
+		require.NoError(t, err)
+		assert.Equal(t, impersonation.EndReasonExpired, stored.EndReason)
+	})
+}
+
+type testEnv struct {
+	service *Service
+	store   *xormStore
+	users   *fakeUserService
+	orgs    *orgtest.FakeOrgService
+	revoked []int64
+	now     time.Time
+}
+
+func setupTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.ImpersonationAuth = setting.AuthImpersonationSettings{Enabled: true, MaxDuration: 30 * time.Minute}
+
+	env := &testEnv{
+		users: &fakeUserService{FakeUserService: usertest.NewUserServiceFake(), users: map[int64]*user.User{
+			1: {ID: 1, Login: "admin"},
+			2: {ID: 2, Login: "viewer"},
+		}},
+		orgs: orgtest.NewOrgServiceFake(),
+		now:  time.Now(),
+	}
+	env.orgs.ExpectedUserOrgDTO = []*org.UserOrgDTO{{OrgID: 1, Role: org.RoleViewer}}
+	env.store = &xormStore{db: db.InitTestDB(t), now: func() time.Time { return env.now }}
+
+	var lastTokenID int64
+	tokens := map[int64]*auth.UserToken{}
+	sessions := authtest.NewFakeUserAuthTokenService()
+	sessions.CreateTokenProvider = func(_ context.Context, cmd *auth.CreateTokenCommand) (*auth.UserToken, error) {
+		lastTokenID++
+		token := &auth.UserToken{Id: lastTokenID, UserId: cmd.User.ID}
+		tokens[token.Id] = token
+		return token, nil
+	}
+	sessions.GetUserTokenProvider = func(_ context.Context, _, userTokenID int64) (*auth.UserToken, error) {
+		if token, ok := tokens[userTokenID]; ok {
+			return token, nil
+		}
+		return nil, auth.ErrUserTokenNotFound
+	}
+	sessions.RevokeTokenProvider = func(_ context.Context, token *auth.UserToken, _ bool) error {
+		env.revoked = append(env.revoked, token.Id)
+		return nil
+	}
+
+	env.service = &Service{
+		cfg:            cfg,
+		store:          env.store,
+		sessionService: sessions,
+		userService:    env.users,
+		orgService:     env.orgs,
+		sessions:       localcache.New(sessionCacheTTL, 2*sessionCacheTTL),
+		log:            log.NewNopLogger(),
+		audit:          log.NewNopLogger(),
+		tracer:         tracing.InitializeTracerForTest(),
+		now:            func() time.Time { return env.now },
+	}
+
+	return env
+}
+
+type fakeUserService struct {
+	*usertest.FakeUserService
+	users map[int64]*user.User
+}
+
+func (f *fakeUserService) GetByID(_ context.Context, query *user.GetUserByIDQuery) (*user.User, error) {
+	if usr, ok := f.users[query.ID]; ok {
+		return usr, nil
+	}
+	return nil, user.ErrUserNotFound
+}
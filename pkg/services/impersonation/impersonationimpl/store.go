@@ -0,0 +1,182 @@
+package impersonationimpl
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/impersonation"
+)
+
+var errNotFound = errors.New("impersonation session not found")
+
+const defaultSearchLimit = 100
+
+type userImpersonation struct {
+	ID                int64  `xorm:"pk autoincr 'id'"`
+	UID               string `xorm:"uid"`
+	OrgID             int64  `xorm:"org_id"`
+	ImpersonatorID    int64  `xorm:"impersonator_id"`
+	ImpersonatorLogin string `xorm:"impersonator_login"`
+	TargetUserID      int64  `xorm:"target_user_id"`
+	TargetLogin       string `xorm:"target_login"`
+	Reason            string `xorm:"reason"`
+	// UserTokenID is the id of the session token issued to the impersonator, it doesn't change when the token is rotated
+	UserTokenID int64      `xorm:"user_token_id"`
+	Created     time.Time  `xorm:"created"`
+	Expires     time.Time  `xorm:"expires"`
+	Ended       *time.Time `xorm:"ended"`
+	EndReason   string     `xorm:"end_reason"`
+}
+
+func (userImpersonation) TableName() string {
+	return "user_impersonation"
+}
+
+func (i *userImpersonation) toSession() *impersonation.Session {
+	return &impersonation.Session{
+		UID:               i.UID,
+		OrgID:             i.OrgID,
+		ImpersonatorID:    i.ImpersonatorID,
+		ImpersonatorLogin: i.ImpersonatorLogin,
+		TargetUserID:      i.TargetUserID,
+		TargetLogin:       i.TargetLogin,
+		Reason:            i.Reason,
+		Created:           i.Created,
+		Expires:           i.Expires,
+		Ended:             i.Ended,
+		EndReason:         i.EndReason,
+	}
+}
+
+type orgImpersonationSettings struct {
+	ID      int64     `xorm:"pk autoincr 'id'"`
+	OrgID   int64     `xorm:"org_id"`
+	Enabled bool      `xorm:"enabled"`
+	Updated time.Time `xorm:"updated"`
+}
+
+func (orgImpersonationSettings) TableName() string {
+	return "org_impersonation_settings"
+}
+
+type store interface {
+	Insert(ctx context.Context, session *userImpersonation) error
+	GetByUID(ctx context.Context, uid string) (*userImpersonation, error)
+	// GetByTokenID returns the session the token was issued for, whether it has ended or not
+	GetByTokenID(ctx context.Context, userTokenID int64) (*userImpersonation, error)
+	End(ctx context.Context, id int64, ended time.Time, reason string) error
+	Search(ctx context.Context, query *impersonation.SearchQuery, now time.Time) ([]*userImpersonation, error)
+	GetOrgSettings(ctx context.Context, orgID int64) (*orgImpersonationSettings, error)
+	SaveOrgSettings(ctx context.Context, settings *orgImpersonationSettings) error
+}
+
+type xormStore struct {
+	db  db.DB
+	now func() time.Time
+}
+
+func (s *xormStore) Insert(ctx context.Context, session *userImpersonation) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(session)
+		return err
+	})
+}
+
+func (s *xormStore) GetByUID(ctx context.Context, uid string) (*userImpersonation, error) {
+	return s.get(ctx, "uid = ?", uid)
+}
+
+func (s *xormStore) GetByTokenID(ctx context.Context, userTokenID int64) (*userImpersonation, error) {
+	return s.get(ctx, "user_token_id = ?", userTokenID)
+}
+
+func (s *xormStore) get(ctx context.Context, cond string, arg any) (*userImpersonation, error) {
+	session := &userImpersonation{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where(cond, arg).Get(session)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return errNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (s *xormStore) End(ctx context.Context, id int64, ended time.Time, reason string) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE user_impersonation SET ended = ?, end_reason = ? WHERE id = ? AND ended IS NULL", ended, reason, id)
+		return err
+	})
+}
+
+func (s *xormStore) Search(ctx context.Context, query *impersonation.SearchQuery, now time.Time) ([]*userImpersonation, error) {
+	sessions := make([]*userImpersonation, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table("user_impersonation")
+		if query.OrgID != 0 {
+			q = q.Where("org_id = ?", query.OrgID)
+		}
+		if query.ImpersonatorID != 0 {
+			q = q.Where("impersonator_id = ?", query.ImpersonatorID)
+		}
+		if query.TargetUserID != 0 {
+			q = q.Where("target_user_id = ?", query.TargetUserID)
+		}
+		if query.ActiveOnly {
+			q = q.Where("ended IS NULL AND expires > ?", now)
+		}
+
+		limit := query.Limit
+		if limit <= 0 {
+			limit = defaultSearchLimit
+		}
+		return q.Desc("created").Limit(limit).Find(&sessions)
+	})
+	return sessions, err
+}
+
+func (s *xormStore) GetOrgSettings(ctx context.Context, orgID int64) (*orgImpersonationSettings, error) {
+	settings := &orgImpersonationSettings{}
+	var has bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		has, err = sess.Where("org_id = ?", orgID).Get(settings)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		// impersonation is allowed unless the org opted out
+		return &orgImpersonationSettings{OrgID: orgID, Enabled: true}, nil
+	}
+	return settings, nil
+}
+
+func (s *xormStore) SaveOrgSettings(ctx context.Context, settings *orgImpersonationSettings) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		settings.Updated = s.now()
+
+		existing := &orgImpersonationSettings{}
+		has, err := sess.Where("org_id = ?", settings.OrgID).Get(existing)
+		if err != nil {
+			return err
+		}
+		if has {
+			settings.ID = existing.ID
+			_, err = sess.ID(settings.ID).AllCols().Update(settings)
+			return err
+		}
+
+		_, err = sess.Insert(settings)
+		return err
+	})
+}
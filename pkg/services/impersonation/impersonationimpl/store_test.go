@@ -0,0 +1,111 @@
+package impersonationimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/impersonation"
+)
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	sqlStore := db.InitTestDB(t)
+	store := &xormStore{db: sqlStore, now: func() time.Time { return now }}
+
+	newSession := func(uid string, orgID, impersonatorID, targetUserID, tokenID int64, expires time.Time) *userImpersonation {
+		return &userImpersonation{
+			UID: uid, OrgID: orgID, ImpersonatorID: impersonatorID, ImpersonatorLogin: "admin", TargetUserID: targetUserID,
+			TargetLogin: "viewer", Reason: "support", UserTokenID: tokenID, Created: now, Expires: expires,
+		}
+	}
+	sessions := []*userImpersonation{
+		newSession("active", 1, 1, 2, 1, now.Add(time.Hour)),
+		newSession("ended", 1, 1, 2, 2, now.Add(time.Hour)),
+		newSession("expired", 1, 1, 3, 3, now.Add(-time.Hour)),
+		newSession("other-impersonator", 1, 4, 3, 4, now.Add(time.Hour)),
+		newSession("other-org", 2, 1, 2, 5, now.Add(time.Hour)),
+	}
+	for _, session := range sessions {
+		require.NoError(t, store.Insert(ctx, session))
+	}
+
+	t.Run("should get the sessions by uid and by token", func(t *testing.T) {
+		session, err := store.GetByUID(ctx, "active")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), session.UserTokenID)
+		assert.Nil(t, session.Ended)
+
+		session, err = store.GetByTokenID(ctx, 3)
+		require.NoError(t, err)
+		assert.Equal(t, "expired", session.UID)
+
+		_, err = store.GetByUID(ctx, "missing")
+		assert.ErrorIs(t, err, errNotFound)
+		_, err = store.GetByTokenID(ctx, 99)
+		assert.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("should end a session once", func(t *testing.T) {
+		ended := now.Add(-time.Minute)
+		require.NoError(t, store.End(ctx, sessions[1].ID, ended, impersonation.EndReasonStopped))
+		require.NoError(t, store.End(ctx, sessions[1].ID, now, impersonation.EndReasonExpired))
+
+		session, err := store.GetByUID(ctx, "ended")
+		require.NoError(t, err)
+		require.NotNil(t, session.Ended)
+		assert.True(t, ended.Equal(*session.Ended), "the first end is kept")
+		assert.Equal(t, impersonation.EndReasonStopped, session.EndReason)
+	})
+
+	t.Run("should search the sessions", func(t *testing.T) {
+		search := func(query *impersonation.SearchQuery) []string {
+			found, err := store.Search(ctx, query, now)
+			require.NoError(t, err)
+			uids := make([]string, 0, len(found))
+			for _, session := range found {
+				uids = append(uids, session.UID)
+			}
+			return uids
+		}
+
+		assert.ElementsMatch(t, []string{"active", "ended", "expired", "other-impersonator"}, search(&impersonation.SearchQuery{OrgID: 1}))
+		assert.ElementsMatch(t, []string{"active", "other-impersonator"}, search(&impersonation.SearchQuery{OrgID: 1, ActiveOnly: true}),
+			"the ended and expired sessions aren't active")
+		assert.ElementsMatch(t, []string{"active", "other-org"}, search(&impersonation.SearchQuery{ImpersonatorID: 1, TargetUserID: 2, ActiveOnly: true}))
+		assert.ElementsMatch(t, []string{"other-impersonator"}, search(&impersonation.SearchQuery{ImpersonatorID: 4}))
+		assert.Len(t, search(&impersonation.SearchQuery{Limit: 2}), 2)
+	})
+
+	t.Run("should create and update the settings of an org", func(t *testing.T) {
+		settings, err := store.GetOrgSettings(ctx, 1)
+		require.NoError(t, err)
+		assert.True(t, settings.Enabled, "impersonation is allowed by default")
+
+		require.NoError(t, store.SaveOrgSettings(ctx, &orgImpersonationSettings{OrgID: 1, Enabled: false}))
+		settings, err = store.GetOrgSettings(ctx, 1)
+		require.NoError(t, err)
+		assert.False(t, settings.Enabled)
+
+		require.NoError(t, store.SaveOrgSettings(ctx, &orgImpersonationSettings{OrgID: 1, Enabled: true}))
+		settings, err = store.GetOrgSettings(ctx, 1)
+		require.NoError(t, err)
+		assert.True(t, settings.Enabled)
+
+		other, err := store.GetOrgSettings(ctx, 2)
+		require.NoError(t, err)
+		assert.True(t, other.Enabled)
+
+		count, err := sqlStore.GetEngine().Table("org_impersonation_settings").Count()
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count, "the settings of an org are updated in place")
+	})
+}
@@ -0,0 +1,87 @@
+package instancesync
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister) {
+	router.Group("/api/admin/instance-sync", func(syncRoute routing.RouteRegister) {
+		syncRoute.Post("/", routing.Wrap(s.PostInstanceSync))
+		syncRoute.Get("/report", routing.Wrap(s.GetInstanceSyncReport))
+	}, middleware.ReqGrafanaAdmin)
+}
+
+// swagger:route POST /admin/instance-sync admin_instance_sync postInstanceSync
+//
+// Pushes the configured resources to the target instance.
+//
+// With `dryRun=true` nothing is written to the target, the report lists the changes that would be applied.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: instanceSyncReportResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) PostInstanceSync(c *contextmodel.ReqContext) response.Response {
+	report, err := s.Sync(c.Req.Context(), TriggerManual, c.QueryBool("dryRun"))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrSyncDisabled):
+			return response.Error(http.StatusBadRequest, ErrSyncDisabled.Error(), nil)
+		case errors.Is(err, ErrSyncInProgress):
+			return response.Error(http.StatusConflict, ErrSyncInProgress.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to synchronize the target instance", err)
+	}
+
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:route GET /admin/instance-sync/report admin_instance_sync getInstanceSyncReport
+//
+// Returns the report of the last synchronization of the target instance.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: instanceSyncReportResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) GetInstanceSyncReport(c *contextmodel.ReqContext) response.Response {
+	report, err := s.LastReport(c.Req.Context())
+	if err != nil {
+		if errors.Is(err, ErrReportNotFound) {
+			return response.Error(http.StatusNotFound, ErrReportNotFound.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get instance sync report", err)
+	}
+
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:parameters postInstanceSync
+type PostInstanceSyncParams struct {
+	// in:query
+	// required:false
+	DryRun bool `json:"dryRun"`
+}
+
+// swagger:response instanceSyncReportResponse
+type InstanceSyncReportResponse struct {
+	// in:body
+	Body *Report `json:"body"`
+}
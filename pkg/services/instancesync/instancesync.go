@@ -0,0 +1,234 @@
+package instancesync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/folder"
+	ngstore "github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const kvNamespace = "instance.sync"
+
+var (
+	ErrSyncDisabled   = errors.New("instance sync is not enabled")
+	ErrSyncInProgress = errors.New("instance sync already in progress")
+	ErrReportNotFound = errors.New("instance sync report not found")
+)
+
+// Service pushes the resources of one org to another Grafana instance, for
+// example to keep a disaster recovery standby up to date or to promote the
+// dashboards of a development instance to production.
+//
+// The target is only written through its HTTP API, so it can run any version
+// of Grafana supporting those APIs and doesn't need access to this instance.
+type Service struct {
+	cfg              setting.InstanceSyncSettings
+	dashboardService dashboards.DashboardService
+	folderService    folder.Service
+	dsService        datasources.DataSourceService
+	ruleStore        RuleStore
+	serverLock       *serverlock.ServerLockService
+	kv               *kvstore.NamespacedKVStore
+	target           *targetClient
+	log              log.Logger
+
+	// syncMu prevents a manual synchronization from overlapping a scheduled one
+	syncMu sync.Mutex
+}
+
+func ProvideService(
+	cfg *setting.Cfg, router routing.RouteRegister,
+	dashboardService dashboards.DashboardService, folderService folder.Service,
+	dsService datasources.DataSourceService, ruleStore *ngstore.DBstore,
+	serverLock *serverlock.ServerLockService, kv kvstore.KVStore,
+) *Service {
+	s := &Service{
+		cfg:              cfg.InstanceSync,
+		dashboardService: dashboardService,
+		folderService:    folderService,
+		dsService:        dsService,
+		ruleStore:        ruleStore,
+		serverLock:       serverLock,
+		kv:               kvstore.WithNamespace(kv, cfg.InstanceSync.OrgID, kvNamespace),
+		target:           newTargetClient(cfg.InstanceSync),
+		log:              log.New("instance.sync"),
+	}
+
+	s.registerRoutes(router)
+
+	return s
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Enabled || s.cfg.TargetURL == ""
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	if s.cfg.SyncInterval <= 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	ticker := time.NewTicker(s.cfg.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		// the lock interval is shorter than the sync interval to leave room for clock drift between instances
+		err := s.serverLock.LockAndExecute(ctx, "instance sync", s.cfg.SyncInterval/2, func(ctx context.Context) {
+			if _, err := s.Sync(ctx, TriggerSchedule, false); err != nil {
+				s.log.Error("Failed to synchronize the target instance", "error", err)
+			}
+		})
+		if err != nil {
+			s.log.Error("Failed to acquire lock for instance synchronization", "error", err)
+		}
+	}
+}
+
+// Sync pushes the configured resources to the target instance and stores the
+// report of the changes. A dry run only reports the changes it would apply.
+func (s *Service) Sync(ctx context.Context, trigger Trigger, dryRun bool) (*Report, error) {
+	if s.IsDisabled() {
+		return nil, ErrSyncDisabled
+	}
+
+	if !s.syncMu.TryLock() {
+		return nil, ErrSyncInProgress
+	}
+	defer s.syncMu.Unlock()
+
+	report := &Report{
+		Trigger:   trigger,
+		DryRun:    dryRun,
+		StartedAt: time.Now(),
+		Items:     []Item{},
+	}
+
+	// the parents are pushed before their children: folders before the
+	// dashboards and alert rules they contain, and data sources before the
+	// dashboards and alert rules querying them
+	for _, kind := range []Kind{KindFolder, KindDataSource, KindDashboard, KindAlertRule} {
+		if !s.isSynced(kind) {
+			continue
+		}
+
+		resources, err := s.collect(ctx, kind)
+		if err != nil {
+			report.Error = fmt.Sprintf("failed to list %s: %s", kind, err)
+			break
+		}
+
+		for _, r := range resources {
+			report.add(s.syncResource(ctx, r, dryRun))
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	s.log.Info("Synchronized the target instance", "trigger", trigger, "dryRun", dryRun,
+		"created", report.Created, "updated", report.Updated, "unchanged", report.Unchanged,
+		"conflicts", report.Conflicts, "failed", report.Failed)
+
+	if !dryRun {
+		if err := s.storeReport(ctx, report); err != nil {
+			return nil, fmt.Errorf("failed to store instance sync report: %w", err)
+		}
+	}
+
+	return report, nil
+}
+
+func (s *Service) isSynced(kind Kind) bool {
+	for _, r := range s.cfg.Resources {
+		if Kind(r) == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// syncResource creates or updates a single resource in the target
+func (s *Service) syncResource(ctx context.Context, r *resource, dryRun bool) Item {
+	item := Item{Kind: r.Kind, UID: r.UID, Title: r.Title}
+	fail := func(err error) Item {
+		s.log.Warn("Failed to synchronize resource", "kind", r.Kind, "uid", r.UID, "error", err)
+		item.Action = ActionFailed
+		item.Error = err.Error()
+		return item
+	}
+
+	current, err := s.target.get(ctx, r.Kind, r.UID)
+	if err != nil {
+		return fail(err)
+	}
+	state, err := s.getState(ctx, r)
+	if err != nil {
+		return fail(err)
+	}
+
+	item.Action = decide(s.cfg.ConflictPolicy, r, state, current)
+	if dryRun || item.Action == ActionUnchanged || item.Action == ActionConflict {
+		return item
+	}
+
+	if err := s.target.put(ctx, r, current); err != nil {
+		return fail(err)
+	}
+
+	// the version of the target is stored to detect the changes made in the
+	// target since this synchronization
+	current, err = s.target.get(ctx, r.Kind, r.UID)
+	if err == nil && current == nil {
+		err = fmt.Errorf("%s not found after being written", r.Kind)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	if err := s.setState(ctx, r, &syncState{Hash: r.hash, TargetVersion: current.version()}); err != nil {
+		return fail(err)
+	}
+
+	return item
+}
+
+// decide returns the action needed to push a resource to the target. A resource
+// modified in the target since it was last pushed is a conflict resolved by the
+// configured policy.
+func decide(policy string, r *resource, state *syncState, current *targetObject) Action {
+	if current == nil {
+		return ActionCreate
+	}
+
+	if state != nil && state.TargetVersion == current.version() {
+		if state.Hash == r.hash {
+			return ActionUnchanged
+		}
+		return ActionUpdate
+	}
+
+	switch policy {
+	case setting.InstanceSyncOverwrite:
+		return ActionUpdate
+	case setting.InstanceSyncNewest:
+		// the data sources don't have an updated time in the target, so they are always older
+		if r.Updated.After(current.updated()) {
+			return ActionUpdate
+		}
+	}
+	return ActionConflict
+}
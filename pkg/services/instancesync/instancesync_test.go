@@ -0,0 +1,294 @@
+package instancesync
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService_Sync(t *testing.T) {
+	updated := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	dashs := []*dashboards.Dashboard{
+		{UID: "parent", Title: "Parent", IsFolder: true},
+		{UID: "child", Title: "Child", IsFolder: true},
+		{UID: "dash", Title: "Dashboard", FolderUID: "child", Updated: updated,
+			Data: simplejson.NewFromAny(map[string]any{"id": 12, "version": 3, "title": "Dashboard"})},
+	}
+	folders := foldertest.NewFakeService()
+	folders.ExpectedFolders = []*folder.Folder{
+		{OrgID: 1, UID: "child", Title: "Child", ParentUID: "parent", FullpathUIDs: "parent/child", Updated: updated},
+		{OrgID: 1, UID: "parent", Title: "Parent", FullpathUIDs: "parent", Updated: updated},
+	}
+	dsService := &fakes.FakeDataSourceService{DataSources: []*datasources.DataSource{{
+		OrgID: 1, UID: "ds", Name: "Prometheus", Type: "prometheus", Access: datasources.DS_ACCESS_PROXY,
+		SecureJsonData: map[string][]byte{"password": []byte("encrypted")}, Updated: updated,
+	}}}
+	rules := fakeRuleStore{{UID: "rule", OrgID: 1, Title: "Rule", NamespaceUID: "child", RuleGroup: "group", Updated: updated}}
+
+	setup := func(t *testing.T, policy string) (*Service, *fakeTarget) {
+		t.Helper()
+
+		target := newFakeTarget(t)
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardService.On("GetAllDashboardsByOrgId", mock.Anything, int64(1)).Return(dashs, nil).Maybe()
+
+		cfg := setting.InstanceSyncSettings{
+			Enabled:         true,
+			TargetURL:       target.server.URL,
+			TargetToken:     "token",
+			OrgID:           1,
+			Resources:       []string{"folders", "dashboards", "datasources", "alert_rules"},
+			ConflictPolicy:  policy,
+			PauseAlertRules: true,
+			RequestTimeout:  time.Second,
+		}
+		return &Service{
+			cfg:              cfg,
+			dashboardService: dashboardService,
+			folderService:    folders,
+			dsService:        dsService,
+			ruleStore:        rules,
+			kv:               kvstore.WithNamespace(kvstore.NewFakeKVStore(), 1, kvNamespace),
+			target:           newTargetClient(cfg),
+			log:              log.NewNopLogger(),
+		}, target
+	}
+
+	t.Run("should create the resources missing in the target", func(t *testing.T) {
+		s, target := setup(t, setting.InstanceSyncOverwrite)
+
+		report, err := s.Sync(context.Background(), TriggerManual, false)
+		require.NoError(t, err)
+		assert.Empty(t, report.Error)
+		assert.Equal(t, 5, report.Created)
+		assert.Equal(t, 0, report.Failed)
+
+		// the parents are created first
+		assert.Equal(t, []string{
+			"POST /api/folders parent",
+			"POST /api/folders child",
+			"POST /api/datasources ds",
+			"POST /api/dashboards/db dash",
+			"POST /api/v1/provisioning/alert-rules rule",
+		}, target.writes)
+
+		dash := target.objects["dashboards/dash"].body["dashboard"].(map[string]any)
+		assert.NotContains(t, dash, "id")
+		assert.Equal(t, "child", target.objects["dashboards/dash"].body["folderUid"])
+		assert.Nil(t, target.objects["datasources/ds"].body["secureJsonData"])
+		assert.Equal(t, true, target.objects["alert_rules/rule"].body["isPaused"])
+
+		last, err := s.LastReport(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 5, last.Created)
+	})
+
+	t.Run("should not write the unchanged resources", func(t *testing.T) {
+		s, target := setup(t, setting.InstanceSyncOverwrite)
+		_, err := s.Sync(context.Background(), TriggerManual, false)
+		require.NoError(t, err)
+		target.writes = nil
+
+		report, err := s.Sync(context.Background(), TriggerSchedule, false)
+		require.NoError(t, err)
+		assert.Equal(t, 5, report.Unchanged)
+		assert.Empty(t, report.Items)
+		assert.Empty(t, target.writes)
+	})
+
+	t.Run("should apply the conflict policy to the resources changed in the target", func(t *testing.T) {
+		s, target := setup(t, setting.InstanceSyncSkip)
+		_, err := s.Sync(context.Background(), TriggerManual, false)
+		require.NoError(t, err)
+		target.touch("dashboards/dash")
+		target.writes = nil
+
+		report, err := s.Sync(context.Background(), TriggerManual, false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Conflicts)
+		assert.Equal(t, []Item{{Kind: KindDashboard, UID: "dash", Title: "Dashboard", Action: ActionConflict}}, report.Items)
+		assert.Empty(t, target.writes)
+
+		s.cfg.ConflictPolicy = setting.InstanceSyncOverwrite
+		report, err = s.Sync(context.Background(), TriggerManual, false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, []string{"POST /api/dashboards/db dash"}, target.writes)
+	})
+
+	t.Run("should not write anything on dry run", func(t *testing.T) {
+		s, target := setup(t, setting.InstanceSyncOverwrite)
+
+		report, err := s.Sync(context.Background(), TriggerManual, true)
+		require.NoError(t, err)
+		assert.Equal(t, 5, report.Created)
+		assert.Empty(t, target.writes)
+
+		_, err = s.LastReport(context.Background())
+		assert.ErrorIs(t, err, ErrReportNotFound)
+	})
+
+	t.Run("should not run two synchronizations at once", func(t *testing.T) {
+		s, _ := setup(t, setting.InstanceSyncOverwrite)
+		s.syncMu.Lock()
+		defer s.syncMu.Unlock()
+
+		_, err := s.Sync(context.Background(), TriggerManual, false)
+		assert.ErrorIs(t, err, ErrSyncInProgress)
+	})
+}
+
+func TestDecide(t *testing.T) {
+	now := time.Now()
+	r := &resource{Kind: KindDashboard, UID: "dash", Updated: now, hash: "a"}
+	target := &targetObject{Version: 2, Updated: now.Add(-time.Hour)}
+	synced := &syncState{Hash: "a", TargetVersion: target.version()}
+
+	assert.Equal(t, ActionCreate, decide(setting.InstanceSyncSkip, r, nil, nil))
+	assert.Equal(t, ActionUnchanged, decide(setting.InstanceSyncSkip, r, synced, target))
+	assert.Equal(t, ActionUpdate, decide(setting.InstanceSyncSkip, r, &syncState{Hash: "b", TargetVersion: target.version()}, target))
+
+	// the resource was changed in the target since it was pushed
+	changed := &syncState{Hash: "a", TargetVersion: "1/"}
+	assert.Equal(t, ActionUpdate, decide(setting.InstanceSyncOverwrite, r, changed, target))
+	assert.Equal(t, ActionConflict, decide(setting.InstanceSyncSkip, r, changed, target))
+	assert.Equal(t, ActionUpdate, decide(setting.InstanceSyncNewest, r, changed, target))
+	assert.Equal(t, ActionConflict, decide(setting.InstanceSyncNewest, r, changed, &targetObject{Version: 2, Updated: now.Add(time.Hour)}))
+
+	// a resource existing in the target before the first synchronization is a conflict
+	assert.Equal(t, ActionConflict, decide(setting.InstanceSyncSkip, r, nil, target))
+}
+
+type fakeRuleStore []*ngmodels.AlertRule
+
+func (f fakeRuleStore) ListAlertRules(_ context.Context, _ *ngmodels.ListAlertRulesQuery) (ngmodels.RulesGroup, error) {
+	return ngmodels.RulesGroup(f), nil
+}
+
+type fakeObject struct {
+	version int
+	updated time.Time
+	body    map[string]any
+}
+
+// fakeTarget implements the subset of the Grafana HTTP API used by the synchronization
+type fakeTarget struct {
+	server  *httptest.Server
+	mu      sync.Mutex
+	objects map[string]*fakeObject
+	writes  []string
+}
+
+func newFakeTarget(t *testing.T) *fakeTarget {
+	f := &fakeTarget{objects: map[string]*fakeObject{}}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if r.Method == http.MethodGet {
+			f.get(w, r.URL.Path)
+			return
+		}
+
+		body := map[string]any{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		kind, uid := f.route(r.URL.Path, body)
+		f.writes = append(f.writes, r.Method+" "+r.URL.Path+" "+uid)
+		obj, ok := f.objects[kind+"/"+uid]
+		if !ok {
+			obj = &fakeObject{}
+			f.objects[kind+"/"+uid] = obj
+		}
+		obj.version++
+		obj.updated = time.Now()
+		obj.body = body
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// route returns the kind and uid of the resource written by a request
+func (f *fakeTarget) route(path string, body map[string]any) (string, string) {
+	for prefix, kind := range map[string]Kind{
+		"/api/folders":                     KindFolder,
+		"/api/dashboards":                  KindDashboard,
+		"/api/datasources":                 KindDataSource,
+		"/api/v1/provisioning/alert-rules": KindAlertRule,
+	} {
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		if kind == KindDashboard {
+			return string(kind), body["dashboard"].(map[string]any)["uid"].(string)
+		}
+		uid, _ := body["uid"].(string)
+		if rest := strings.TrimPrefix(strings.TrimPrefix(path, prefix+"/"), "uid/"); rest != path && rest != "" {
+			uid = rest
+		}
+		return string(kind), uid
+	}
+	return "", ""
+}
+
+func (f *fakeTarget) get(w http.ResponseWriter, path string) {
+	var key string
+	for prefix, kind := range map[string]Kind{
+		"/api/folders/":                     KindFolder,
+		"/api/dashboards/uid/":              KindDashboard,
+		"/api/datasources/uid/":             KindDataSource,
+		"/api/v1/provisioning/alert-rules/": KindAlertRule,
+	} {
+		if strings.HasPrefix(path, prefix) {
+			key = string(kind) + "/" + strings.TrimPrefix(path, prefix)
+		}
+	}
+
+	obj, ok := f.objects[key]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	rsp := map[string]any{"version": obj.version, "updated": obj.updated, "parentUid": obj.body["parentUid"]}
+	if strings.HasPrefix(key, string(KindDashboard)) {
+		rsp = map[string]any{"dashboard": map[string]any{"version": obj.version}, "meta": map[string]any{"updated": obj.updated}}
+	}
+	_ = json.NewEncoder(w).Encode(rsp)
+}
+
+// touch simulates a change made directly in the target
+func (f *fakeTarget) touch(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[key].version++
+	f.objects[key].updated = time.Now()
+}
@@ -0,0 +1,136 @@
+package instancesync
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const reportKey = "report"
+
+type Kind string
+
+const (
+	KindFolder     Kind = "folders"
+	KindDashboard  Kind = "dashboards"
+	KindDataSource Kind = "datasources"
+	KindAlertRule  Kind = "alert_rules"
+)
+
+type Action string
+
+const (
+	ActionCreate    Action = "created"
+	ActionUpdate    Action = "updated"
+	ActionUnchanged Action = "unchanged"
+	ActionConflict  Action = "conflict"
+	ActionFailed    Action = "failed"
+)
+
+type Trigger string
+
+const (
+	TriggerSchedule Trigger = "schedule"
+	TriggerManual   Trigger = "manual"
+)
+
+// Report lists the resources pushed to the target by one synchronization
+type Report struct {
+	Trigger    Trigger   `json:"trigger"`
+	DryRun     bool      `json:"dryRun"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	Created    int       `json:"created"`
+	Updated    int       `json:"updated"`
+	Unchanged  int       `json:"unchanged"`
+	Conflicts  int       `json:"conflicts"`
+	Failed     int       `json:"failed"`
+	// Items lists the resources that are not unchanged
+	Items []Item `json:"items"`
+	// Error is set when the resources of this instance could not be listed
+	Error string `json:"error,omitempty"`
+}
+
+// Item is a resource created, updated or skipped in the target
+type Item struct {
+	Kind   Kind   `json:"kind"`
+	UID    string `json:"uid"`
+	Title  string `json:"title"`
+	Action Action `json:"action"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (r *Report) add(item Item) {
+	switch item.Action {
+	case ActionCreate:
+		r.Created++
+	case ActionUpdate:
+		r.Updated++
+	case ActionUnchanged:
+		r.Unchanged++
+		return
+	case ActionConflict:
+		r.Conflicts++
+	case ActionFailed:
+		r.Failed++
+	}
+	r.Items = append(r.Items, item)
+}
+
+// syncState is stored for every resource pushed to the target
+type syncState struct {
+	// Hash is the hash of the resource when it was pushed
+	Hash string `json:"hash"`
+	// TargetVersion is the version of the resource in the target after it was pushed
+	TargetVersion string `json:"targetVersion"`
+}
+
+func stateKey(r *resource) string {
+	return string(r.Kind) + "/" + r.UID
+}
+
+func (s *Service) getState(ctx context.Context, r *resource) (*syncState, error) {
+	value, ok, err := s.kv.Get(ctx, stateKey(r))
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	state := &syncState{}
+	if err := json.Unmarshal([]byte(value), state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (s *Service) setState(ctx context.Context, r *resource, state *syncState) error {
+	value, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, stateKey(r), string(value))
+}
+
+// LastReport returns the report of the last synchronization that was not a dry run
+func (s *Service) LastReport(ctx context.Context) (*Report, error) {
+	value, ok, err := s.kv.Get(ctx, reportKey)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrReportNotFound
+	}
+
+	report := &Report{}
+	if err := json.Unmarshal([]byte(value), report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *Service) storeReport(ctx context.Context, report *Report) error {
+	value, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, reportKey, string(value))
+}
@@ -0,0 +1,222 @@
+package instancesync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/compat"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// RuleStore lists the alert rules pushed to the target
+type RuleStore interface {
+	ListAlertRules(ctx context.Context, query *ngmodels.ListAlertRulesQuery) (ngmodels.RulesGroup, error)
+}
+
+// resource is a resource of this instance with the payload of the target API creating it
+type resource struct {
+	Kind    Kind
+	UID     string
+	Title   string
+	Updated time.Time
+	Body    any
+
+	// hash of the body, to detect the changes since the last synchronization
+	hash string
+}
+
+type folderBody struct {
+	UID         string `json:"uid"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	ParentUID   string `json:"parentUid,omitempty"`
+}
+
+type dashboardBody struct {
+	Dashboard map[string]any `json:"dashboard"`
+	FolderUID string         `json:"folderUid,omitempty"`
+	Overwrite bool           `json:"overwrite"`
+	Message   string         `json:"message"`
+}
+
+func newResource(kind Kind, uid, title string, updated time.Time, body any) (*resource, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s %s: %w", kind, uid, err)
+	}
+	sum := sha256.Sum256(data)
+
+	return &resource{
+		Kind:    kind,
+		UID:     uid,
+		Title:   title,
+		Updated: updated,
+		Body:    body,
+		hash:    hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// collect lists the resources of a kind in the synced org
+func (s *Service) collect(ctx context.Context, kind Kind) ([]*resource, error) {
+	ctx, requester := identity.WithServiceIdentity(ctx, s.cfg.OrgID)
+
+	switch kind {
+	case KindFolder:
+		return s.collectFolders(ctx, requester)
+	case KindDashboard:
+		return s.collectDashboards(ctx)
+	case KindDataSource:
+		return s.collectDataSources(ctx)
+	case KindAlertRule:
+		return s.collectAlertRules(ctx)
+	}
+	return nil, fmt.Errorf("unknown resource kind %q", kind)
+}
+
+func (s *Service) collectFolders(ctx context.Context, requester identity.Requester) ([]*resource, error) {
+	dashs, err := s.dashboardService.GetAllDashboardsByOrgId(ctx, s.cfg.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the folders are fetched by UID to get their parents
+	uids := make([]string, 0)
+	for _, d := range dashs {
+		if d.IsFolder {
+			uids = append(uids, d.UID)
+		}
+	}
+	if len(uids) == 0 {
+		return []*resource{}, nil
+	}
+
+	folders, err := s.folderService.GetFolders(ctx, folder.GetFoldersQuery{
+		UIDs:             uids,
+		SignedInUser:     requester,
+		OrgID:            s.cfg.OrgID,
+		WithFullpathUIDs: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the parents are created before their subfolders
+	sort.SliceStable(folders, func(i, j int) bool {
+		return strings.Count(folders[i].FullpathUIDs, "/") < strings.Count(folders[j].FullpathUIDs, "/")
+	})
+
+	result := make([]*resource, 0, len(folders))
+	for _, f := range folders {
+		r, err := newResource(KindFolder, f.UID, f.Title, f.Updated, folderBody{
+			UID:         f.UID,
+			Title:       f.Title,
+			Description: f.Description,
+			ParentUID:   f.ParentUID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+func (s *Service) collectDashboards(ctx context.Context) ([]*resource, error) {
+	dashs, err := s.dashboardService.GetAllDashboardsByOrgId(ctx, s.cfg.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*resource, 0, len(dashs))
+	for _, d := range dashs {
+		if d.IsFolder || d.Data == nil {
+			continue
+		}
+
+		// the id and version belong to this instance, the target assigns its own
+		data := d.Data.MustMap()
+		model := make(map[string]any, len(data))
+		for k, v := range data {
+			model[k] = v
+		}
+		delete(model, "id")
+		delete(model, "version")
+		model["uid"] = d.UID
+
+		r, err := newResource(KindDashboard, d.UID, d.Title, d.Updated, dashboardBody{
+			Dashboard: model,
+			FolderUID: d.FolderUID,
+			Overwrite: true,
+			Message:   "Synchronized from the source instance",
+		})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+func (s *Service) collectDataSources(ctx context.Context) ([]*resource, error) {
+	dataSources, err := s.dsService.GetDataSources(ctx, &datasources.GetDataSourcesQuery{OrgID: s.cfg.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*resource, 0, len(dataSources))
+	for _, ds := range dataSources {
+		// the secrets are never sent, they must be configured in the target which keeps them on update
+		r, err := newResource(KindDataSource, ds.UID, ds.Name, ds.Updated, datasources.AddDataSourceCommand{
+			Name:            ds.Name,
+			Type:            ds.Type,
+			Access:          ds.Access,
+			URL:             ds.URL,
+			User:            ds.User,
+			Database:        ds.Database,
+			BasicAuth:       ds.BasicAuth,
+			BasicAuthUser:   ds.BasicAuthUser,
+			WithCredentials: ds.WithCredentials,
+			IsDefault:       ds.IsDefault,
+			JsonData:        ds.JsonData,
+			UID:             ds.UID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+func (s *Service) collectAlertRules(ctx context.Context) ([]*resource, error) {
+	rules, err := s.ruleStore.ListAlertRules(ctx, &ngmodels.ListAlertRulesQuery{OrgID: s.cfg.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*resource, 0, len(rules))
+	for _, rule := range rules {
+		body := compat.ProvisionedAlertRuleFromAlertRule(*rule, ngmodels.ProvenanceNone)
+		body.ID = 0
+		body.Updated = time.Time{}
+		if s.cfg.PauseAlertRules {
+			body.IsPaused = true
+		}
+
+		r, err := newResource(KindAlertRule, rule.UID, rule.Title, rule.Updated, body)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
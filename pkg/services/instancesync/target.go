@@ -0,0 +1,181 @@
+package instancesync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// targetClient writes the resources to the target instance through its HTTP API
+type targetClient struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func newTargetClient(cfg setting.InstanceSyncSettings) *targetClient {
+	return &targetClient{
+		url:    cfg.TargetURL,
+		token:  cfg.TargetToken,
+		client: &http.Client{Timeout: cfg.RequestTimeout},
+	}
+}
+
+// targetObject holds the fields of the target API responses identifying the
+// version of a resource
+type targetObject struct {
+	Version   int       `json:"version"`
+	Updated   time.Time `json:"updated"`
+	ParentUID string    `json:"parentUid"`
+
+	// set by the dashboard API
+	Meta *struct {
+		Updated time.Time `json:"updated"`
+	} `json:"meta"`
+	Dashboard *struct {
+		Version int `json:"version"`
+	} `json:"dashboard"`
+}
+
+func (o *targetObject) updated() time.Time {
+	if o.Meta != nil {
+		return o.Meta.Updated
+	}
+	return o.Updated
+}
+
+// version changes every time the resource is written in the target
+func (o *targetObject) version() string {
+	v := o.Version
+	if o.Dashboard != nil {
+		v = o.Dashboard.Version
+	}
+	return strconv.Itoa(v) + "/" + o.updated().UTC().Format(time.RFC3339Nano)
+}
+
+type targetError struct {
+	method string
+	path   string
+	status int
+	body   string
+}
+
+func (e *targetError) Error() string {
+	return fmt.Sprintf("%s %s returned %d: %s", e.method, e.path, e.status, e.body)
+}
+
+// get returns the resource of the target, nil if it doesn't exist
+func (c *targetClient) get(ctx context.Context, kind Kind, uid string) (*targetObject, error) {
+	var path string
+	switch kind {
+	case KindFolder:
+		path = "/api/folders/" + url.PathEscape(uid)
+	case KindDashboard:
+		path = "/api/dashboards/uid/" + url.PathEscape(uid)
+	case KindDataSource:
+		path = "/api/datasources/uid/" + url.PathEscape(uid)
+	case KindAlertRule:
+		path = "/api/v1/provisioning/alert-rules/" + url.PathEscape(uid)
+	default:
+		return nil, fmt.Errorf("unknown resource kind %q", kind)
+	}
+
+	obj := &targetObject{}
+	err := c.do(ctx, http.MethodGet, path, nil, obj)
+	if err != nil {
+		var e *targetError
+		if errors.As(err, &e) && e.status == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return obj, nil
+}
+
+// put creates the resource in the target, or updates it when it already exists
+func (c *targetClient) put(ctx context.Context, r *resource, current *targetObject) error {
+	uid := url.PathEscape(r.UID)
+	switch r.Kind {
+	case KindFolder:
+		if current == nil {
+			return c.do(ctx, http.MethodPost, "/api/folders", r.Body, nil)
+		}
+		body := r.Body.(folderBody)
+		err := c.do(ctx, http.MethodPut, "/api/folders/"+uid, map[string]any{
+			"title":       body.Title,
+			"description": body.Description,
+			"overwrite":   true,
+		}, nil)
+		if err != nil || current.ParentUID == body.ParentUID {
+			return err
+		}
+		return c.do(ctx, http.MethodPost, "/api/folders/"+uid+"/move", map[string]any{"parentUid": body.ParentUID}, nil)
+
+	case KindDashboard:
+		return c.do(ctx, http.MethodPost, "/api/dashboards/db", r.Body, nil)
+
+	case KindDataSource:
+		if current == nil {
+			return c.do(ctx, http.MethodPost, "/api/datasources", r.Body, nil)
+		}
+		return c.do(ctx, http.MethodPut, "/api/datasources/uid/"+uid, r.Body, nil)
+
+	case KindAlertRule:
+		if current == nil {
+			return c.do(ctx, http.MethodPost, "/api/v1/provisioning/alert-rules", r.Body, nil)
+		}
+		return c.do(ctx, http.MethodPut, "/api/v1/provisioning/alert-rules/"+uid, r.Body, nil)
+	}
+	return fmt.Errorf("unknown resource kind %q", r.Kind)
+}
+
+func (c *targetClient) do(ctx context.Context, method, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	// the synced alert rules stay editable in the target
+	req.Header.Set("X-Disable-Provenance", "true")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &targetError{method: method, path: path, status: resp.StatusCode, body: string(bytes.TrimSpace(data))}
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,98 @@
+package ipallowlist
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/network"
+)
+
+var (
+	ErrBlocked = errutil.Forbidden(
+		"ipallowlist.blocked", errutil.WithPublicMessage("Access from your IP address is not allowed"))
+	ErrInvalidCIDR = errutil.BadRequest(
+		"ipallowlist.invalid-cidr", errutil.WithPublicMessage("The allowlist contains an invalid IP address or CIDR range"))
+	ErrSelfLockout = errutil.BadRequest(
+		"ipallowlist.self-lockout", errutil.WithPublicMessage("The allowlist must contain your current IP address"))
+	ErrTokenNotFound = errutil.NotFound(
+		"ipallowlist.token-not-found", errutil.WithPublicMessage("Service account token not found"))
+)
+
+type Service interface {
+	GetOrgAllowlist(ctx context.Context, orgID int64) (*Allowlist, error)
+	// SetOrgAllowlist replaces the allowlist of the org, an empty list allows every network
+	SetOrgAllowlist(ctx context.Context, cmd *SetOrgAllowlistCommand) error
+	GetTokenAllowlist(ctx context.Context, orgID, tokenID int64) (*Allowlist, error)
+	// SetTokenAllowlist replaces the allowlist of the service account token, an empty list allows every network
+	SetTokenAllowlist(ctx context.Context, cmd *SetTokenAllowlistCommand) error
+}
+
+// Allowlist is a list of IP addresses and CIDR ranges, an empty list allows every network
+type Allowlist struct {
+	CIDRs []string `json:"cidrs"`
+}
+
+type SetOrgAllowlistCommand struct {
+	OrgID int64
+	CIDRs []string
+	// ClientIP is the address of the user updating the allowlist, the update is rejected if it would block it
+	ClientIP net.IP
+}
+
+type SetTokenAllowlistCommand struct {
+	OrgID   int64
+	TokenID int64
+	CIDRs   []string
+}
+
+// ParseCIDRs parses the IP addresses and CIDR ranges of an allowlist
+func ParseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		ipNet, err := network.ParseCIDR(cidr)
+		if err != nil {
+			return nil, ErrInvalidCIDR.Errorf("%w", err)
+		}
+		networks = append(networks, ipNet)
+	}
+	return networks, nil
+}
+
+// ClientIP returns the address of the client that sent the request.
+// The X-Forwarded-For and X-Real-IP headers are only used for requests sent by a trusted proxy,
+// the address of the closest client that is not a trusted proxy is returned.
+// It returns nil when the forwarded addresses cannot be parsed.
+func ClientIP(req *http.Request, trustedProxies []*net.IPNet) net.IP {
+	ip, err := network.GetIPFromAddress(req.RemoteAddr)
+	if err != nil {
+		return nil
+	}
+	if !network.ContainsIP(trustedProxies, ip) {
+		return ip
+	}
+
+	var hops []string
+	for _, header := range req.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	if len(hops) == 0 {
+		if realIP := req.Header.Get("X-Real-IP"); realIP != "" {
+			hops = []string{realIP}
+		}
+	}
+
+	// Walk the proxies from the closest one, each hop was added by the previous one
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip, err = network.GetIPFromAddress(strings.TrimSpace(hops[i]))
+		if err != nil {
+			return nil
+		}
+		if !network.ContainsIP(trustedProxies, ip) {
+			return ip
+		}
+	}
+	return ip
+}
@@ -0,0 +1,77 @@
+package ipallowlist
+
+import (
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIP(t *testing.T) {
+	trustedProxies, err := ParseCIDRs([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc       string
+		remoteAddr string
+		headers    map[string]string
+		exp        net.IP
+	}{
+		{
+			desc:       "should use the peer address without proxy",
+			remoteAddr: "203.0.113.5:51234",
+			exp:        net.ParseIP("203.0.113.5"),
+		},
+		{
+			desc:       "should ignore forwarded addresses set by untrusted clients",
+			remoteAddr: "203.0.113.5:51234",
+			headers:    map[string]string{"X-Forwarded-For": "10.1.1.1", "X-Real-IP": "10.1.1.1"},
+			exp:        net.ParseIP("203.0.113.5"),
+		},
+		{
+			desc:       "should use the closest untrusted forwarded address",
+			remoteAddr: "10.0.0.2:51234",
+			headers:    map[string]string{"X-Forwarded-For": "198.51.100.7, 203.0.113.5, 10.0.0.3"},
+			exp:        net.ParseIP("203.0.113.5"),
+		},
+		{
+			desc:       "should use the real IP header set by a trusted proxy",
+			remoteAddr: "10.0.0.2:51234",
+			headers:    map[string]string{"X-Real-IP": "203.0.113.5"},
+			exp:        net.ParseIP("203.0.113.5"),
+		},
+		{
+			desc:       "should use the farthest proxy when all hops are trusted",
+			remoteAddr: "10.0.0.2:51234",
+			headers:    map[string]string{"X-Forwarded-For": "10.0.0.4, 10.0.0.3"},
+			exp:        net.ParseIP("10.0.0.4"),
+		},
+		{
+			desc:       "should not resolve invalid forwarded addresses",
+			remoteAddr: "10.0.0.2:51234",
+			headers:    map[string]string{"X-Forwarded-For": "unknown"},
+			exp:        nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := &http.Request{RemoteAddr: tc.remoteAddr, Header: http.Header{}}
+			for name, value := range tc.headers {
+				req.Header.Set(name, value)
+			}
+			assert.True(t, tc.exp.Equal(ClientIP(req, trustedProxies)), "expected %s", tc.exp)
+		})
+	}
+}
+
+func TestParseCIDRs(t *testing.T) {
+	networks, err := ParseCIDRs([]string{"192.168.0.0/16", "2001:db8::1"})
+	require.NoError(t, err)
+	require.Len(t, networks, 2)
+	assert.Equal(t, "2001:db8::1/128", networks[1].String())
+
+	_, err = ParseCIDRs([]string{"192.168.0.0/16", "example.com"})
+	assert.ErrorIs(t, err, ErrInvalidCIDR)
+}
@@ -0,0 +1,208 @@
+package ipallowlistimpl
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ipallowlist"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+	saUIDResolver := serviceaccounts.MiddlewareServiceAccountUIDResolver(s.saService, ":serviceAccountId")
+
+	router.Group("/api/org", func(orgRoute routing.RouteRegister) {
+		orgRoute.Get("/ip-allowlist", authorize(ac.EvalPermission(ac.ActionOrgsRead)), routing.Wrap(s.GetCurrentOrgIPAllowlist))
+		orgRoute.Put("/ip-allowlist", authorize(ac.EvalPermission(ac.ActionOrgsWrite)), routing.Wrap(s.UpdateCurrentOrgIPAllowlist))
+	}, middleware.ReqSignedIn)
+
+	router.Group("/api/serviceaccounts", func(saRoute routing.RouteRegister) {
+		saRoute.Get("/:serviceAccountId/tokens/:tokenId/ip-allowlist", saUIDResolver, authorize(ac.EvalPermission(serviceaccounts.ActionRead, serviceaccounts.ScopeID)), routing.Wrap(s.GetTokenIPAllowlist))
+		saRoute.Put("/:serviceAccountId/tokens/:tokenId/ip-allowlist", saUIDResolver, authorize(ac.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), routing.Wrap(s.UpdateTokenIPAllowlist))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /org/ip-allowlist org getCurrentOrgIPAllowlist
+//
+// Get the IP allowlist of the current organization.
+//
+// Responses:
+// 200: ipAllowlistResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) GetCurrentOrgIPAllowlist(c *contextmodel.ReqContext) response.Response {
+	allowlist, err := s.GetOrgAllowlist(c.Req.Context(), c.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get IP allowlist", err)
+	}
+
+	return response.JSON(http.StatusOK, allowlist)
+}
+
+// swagger:route PUT /org/ip-allowlist org updateCurrentOrgIPAllowlist
+//
+// Update the IP allowlist of the current organization.
+//
+// Logins and requests from addresses outside of the allowlist are rejected, an empty list allows every address.
+// The allowlist must contain the address of the caller.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) UpdateCurrentOrgIPAllowlist(c *contextmodel.ReqContext) response.Response {
+	form := ipallowlist.Allowlist{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := s.SetOrgAllowlist(c.Req.Context(), &ipallowlist.SetOrgAllowlistCommand{
+		OrgID:    c.GetOrgID(),
+		CIDRs:    form.CIDRs,
+		ClientIP: ipallowlist.ClientIP(c.Req, s.cfg.IPAllowlist.TrustedProxies),
+	}); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update IP allowlist", err)
+	}
+
+	return response.Success("IP allowlist updated")
+}
+
+// swagger:route GET /serviceaccounts/{serviceAccountId}/tokens/{tokenId}/ip-allowlist service_accounts getTokenIPAllowlist
+//
+// # Get the IP allowlist of a service account token
+//
+// Required permissions (See note in the [introduction](https://grafana.com/docs/grafana/latest/developers/http_api/serviceaccount/#service-account-api) for an explanation):
+// action: `serviceaccounts:read` scope: `serviceaccounts:id:1` (single service account)
+//
+// Responses:
+// 200: ipAllowlistResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) GetTokenIPAllowlist(c *contextmodel.ReqContext) response.Response {
+	tokenID, errResp := s.resolveToken(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	allowlist, err := s.GetTokenAllowlist(c.Req.Context(), c.GetOrgID(), tokenID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get IP allowlist", err)
+	}
+
+	return response.JSON(http.StatusOK, allowlist)
+}
+
+// swagger:route PUT /serviceaccounts/{serviceAccountId}/tokens/{tokenId}/ip-allowlist service_accounts updateTokenIPAllowlist
+//
+// # Update the IP allowlist of a service account token
+//
+// Requests authenticated with the token from addresses outside of the allowlist are rejected, an empty list allows every address.
+// Rotating the token copies its allowlist to the new token.
+//
+// Required permissions (See note in the [introduction](https://grafana.com/docs/grafana/latest/developers/http_api/serviceaccount/#service-account-api) for an explanation):
+// action: `serviceaccounts:write` scope: `serviceaccounts:id:1` (single service account)
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) UpdateTokenIPAllowlist(c *contextmodel.ReqContext) response.Response {
+	tokenID, errResp := s.resolveToken(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	form := ipallowlist.Allowlist{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := s.SetTokenAllowlist(c.Req.Context(), &ipallowlist.SetTokenAllowlistCommand{
+		OrgID:   c.GetOrgID(),
+		TokenID: tokenID,
+		CIDRs:   form.CIDRs,
+	}); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update IP allowlist", err)
+	}
+
+	return response.Success("IP allowlist updated")
+}
+
+// resolveToken returns the id of the token in the request once it is confirmed to belong to the service account
+func (s *Service) resolveToken(c *contextmodel.ReqContext) (int64, response.Response) {
+	saID, err := strconv.ParseInt(web.Params(c.Req)[":serviceAccountId"], 10, 64)
+	if err != nil {
+		return 0, response.Error(http.StatusBadRequest, "Service Account ID is invalid", err)
+	}
+	tokenID, err := strconv.ParseInt(web.Params(c.Req)[":tokenId"], 10, 64)
+	if err != nil {
+		return 0, response.Error(http.StatusBadRequest, "Token ID is invalid", err)
+	}
+
+	if err := s.checkTokenOwner(c.Req.Context(), c.GetOrgID(), saID, tokenID); err != nil {
+		return 0, response.ErrOrFallback(http.StatusInternalServerError, "Failed to get service account token", err)
+	}
+	return tokenID, nil
+}
+
+func (s *Service) checkTokenOwner(ctx context.Context, orgID, saID, tokenID int64) error {
+	tokens, err := s.saService.ListTokens(ctx, &serviceaccounts.GetSATokensQuery{OrgID: &orgID, ServiceAccountID: &saID})
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if token.ID == tokenID {
+			return nil
+		}
+	}
+	return ipallowlist.ErrTokenNotFound.Errorf("token %d not found for service account %d", tokenID, saID)
+}
+
+// swagger:parameters updateCurrentOrgIPAllowlist
+type UpdateCurrentOrgIPAllowlistParams struct {
+	// in:body
+	// required:true
+	Body ipallowlist.Allowlist `json:"body"`
+}
+
+// swagger:parameters getTokenIPAllowlist
+type GetTokenIPAllowlistParams struct {
+	// in:path
+	TokenId int64 `json:"tokenId"`
+	// in:path
+	ServiceAccountId int64 `json:"serviceAccountId"`
+}
+
+// swagger:parameters updateTokenIPAllowlist
+type UpdateTokenIPAllowlistParams struct {
+	// in:path
+	TokenId int64 `json:"tokenId"`
+	// in:path
+	ServiceAccountId int64 `json:"serviceAccountId"`
+	// in:body
+	// required:true
+	Body ipallowlist.Allowlist `json:"body"`
+}
+
+// swagger:response ipAllowlistResponse
+type IPAllowlistResponse struct {
+	// in:body
+	Body ipallowlist.Allowlist `json:"body"`
+}
@@ -0,0 +1,34 @@
+package ipallowlistimpl
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "grafana"
+	metricsSubSystem = "ip_allowlist"
+
+	scopeOrg   = "org"
+	scopeToken = "token"
+)
+
+type metrics struct {
+	blocked *prometheus.CounterVec
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		blocked: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "blocked_requests_total",
+			Help:      "Number of requests and logins blocked by an IP allowlist",
+		}, []string{"scope"}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.blocked)
+	}
+
+	return m
+}
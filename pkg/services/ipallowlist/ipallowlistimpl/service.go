@@ -0,0 +1,200 @@
+package ipallowlistimpl
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	claims "github.com/grafana/authlib/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/network"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/ipallowlist"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var _ ipallowlist.Service = (*Service)(nil)
+
+// cacheTTL bounds how long an instance enforces an allowlist after it was updated on another instance
+const cacheTTL = time.Minute
+
+func ProvideService(
+	cfg *setting.Cfg, sqlStore db.DB, router routing.RouteRegister, accessControl ac.AccessControl,
+	saService serviceaccounts.Service, authnService authn.Service, reg prometheus.Registerer, tracer trace.Tracer,
+) *Service {
+	s := &Service{
+		cfg:       cfg,
+		store:     &xormStore{db: sqlStore, now: time.Now},
+		saService: saService,
+		networks:  localcache.New(cacheTTL, 2*cacheTTL),
+		metrics:   newMetrics(reg),
+		log:       log.New("ipallowlist"),
+		tracer:    tracer,
+	}
+
+	if cfg.IPAllowlist.Enabled {
+		// The hook runs once the signed in user is fetched so the org of the request is known,
+		// it also runs on login so users can't sign in from outside of the allowlist of their org.
+		authnService.RegisterPostAuthHook(s.enforceHook, 105)
+		s.registerRoutes(router, accessControl)
+	}
+
+	return s
+}
+
+type Service struct {
+	cfg       *setting.Cfg
+	store     store
+	saService serviceaccounts.Service
+	// networks caches the parsed allowlists of orgs and tokens
+	networks *localcache.CacheService
+	metrics  *metrics
+	log      log.Logger
+	tracer   trace.Tracer
+}
+
+func (s *Service) GetOrgAllowlist(ctx context.Context, orgID int64) (*ipallowlist.Allowlist, error) {
+	cidrs, err := s.store.GetOrgAllowlist(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return &ipallowlist.Allowlist{CIDRs: cidrs}, nil
+}
+
+func (s *Service) SetOrgAllowlist(ctx context.Context, cmd *ipallowlist.SetOrgAllowlistCommand) error {
+	ctx, span := s.tracer.Start(ctx, "ipallowlist.SetOrgAllowlist")
+	defer span.End()
+
+	networks, err := ipallowlist.ParseCIDRs(cmd.CIDRs)
+	if err != nil {
+		return err
+	}
+	if cmd.ClientIP != nil && len(networks) > 0 && !network.ContainsIP(networks, cmd.ClientIP) {
+		return ipallowlist.ErrSelfLockout.Errorf("allowlist of org %d doesn't contain %s", cmd.OrgID, cmd.ClientIP)
+	}
+
+	if err := s.store.SaveOrgAllowlist(ctx, cmd.OrgID, normalize(networks)); err != nil {
+		return err
+	}
+	s.networks.Delete(orgCacheKey(cmd.OrgID))
+	return nil
+}
+
+func (s *Service) GetTokenAllowlist(ctx context.Context, orgID, tokenID int64) (*ipallowlist.Allowlist, error) {
+	cidrs, err := s.store.GetTokenAllowlist(ctx, orgID, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	return &ipallowlist.Allowlist{CIDRs: cidrs}, nil
+}
+
+func (s *Service) SetTokenAllowlist(ctx context.Context, cmd *ipallowlist.SetTokenAllowlistCommand) error {
+	ctx, span := s.tracer.Start(ctx, "ipallowlist.SetTokenAllowlist")
+	defer span.End()
+
+	networks, err := ipallowlist.ParseCIDRs(cmd.CIDRs)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.SaveTokenAllowlist(ctx, cmd.OrgID, cmd.TokenID, normalize(networks)); err != nil {
+		return err
+	}
+	s.networks.Delete(tokenCacheKey(cmd.OrgID, cmd.TokenID))
+	return nil
+}
+
+// enforceHook rejects requests and logins from outside of the allowlist of the org
+// and, for service account tokens, from outside of the allowlist of the token.
+func (s *Service) enforceHook(ctx context.Context, id *authn.Identity, r *authn.Request) error {
+	if r.HTTPRequest == nil || id.IsAuthenticatedBy(login.RenderModule) ||
+		!id.IsIdentityType(claims.TypeUser, claims.TypeServiceAccount, claims.TypeAPIKey, claims.TypeAnonymous) {
+		return nil
+	}
+
+	ctx, span := s.tracer.Start(ctx, "ipallowlist.enforceHook")
+	defer span.End()
+
+	clientIP := ipallowlist.ClientIP(r.HTTPRequest, s.cfg.IPAllowlist.TrustedProxies)
+
+	orgID := id.GetOrgID()
+	networks, err := s.getNetworks(ctx, orgCacheKey(orgID), func(ctx context.Context) ([]string, error) {
+		return s.store.GetOrgAllowlist(ctx, orgID)
+	})
+	if err != nil {
+		return err
+	}
+	if len(networks) > 0 && !network.ContainsIP(networks, clientIP) {
+		return s.block(ctx, scopeOrg, id, clientIP)
+	}
+
+	keyID := r.GetMeta(authn.MetaKeyAPIKeyID)
+	if keyID == "" {
+		return nil
+	}
+	tokenID, err := strconv.ParseInt(keyID, 10, 64)
+	if err != nil {
+		return err
+	}
+	networks, err = s.getNetworks(ctx, tokenCacheKey(orgID, tokenID), func(ctx context.Context) ([]string, error) {
+		return s.store.GetTokenAllowlist(ctx, orgID, tokenID)
+	})
+	if err != nil {
+		return err
+	}
+	if len(networks) > 0 && !network.ContainsIP(networks, clientIP) {
+		return s.block(ctx, scopeToken, id, clientIP, "tokenId", tokenID)
+	}
+	return nil
+}
+
+func (s *Service) block(ctx context.Context, scope string, id *authn.Identity, clientIP net.IP, args ...any) error {
+	s.metrics.blocked.WithLabelValues(scope).Inc()
+	s.log.FromContext(ctx).Warn("Blocked request from outside of the IP allowlist",
+		append([]any{"scope", scope, "id", id.GetID(), "orgId", id.GetOrgID(), "clientIP", clientIP.String()}, args...)...)
+	return ipallowlist.ErrBlocked.Errorf("ip address %s is not in the %s allowlist of org %d", clientIP, scope, id.GetOrgID())
+}
+
+func (s *Service) getNetworks(ctx context.Context, key string, load func(ctx context.Context) ([]string, error)) ([]*net.IPNet, error) {
+	if cached, ok := s.networks.Get(key); ok {
+		return cached.([]*net.IPNet), nil
+	}
+
+	cidrs, err := load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	networks, err := ipallowlist.ParseCIDRs(cidrs)
+	if err != nil {
+		return nil, err
+	}
+	s.networks.Set(key, networks, 0)
+	return networks, nil
+}
+
+// normalize returns the canonical form of the networks, so stored allowlists are easy to compare
+func normalize(networks []*net.IPNet) []string {
+	cidrs := make([]string, 0, len(networks))
+	for _, n := range networks {
+		cidrs = append(cidrs, n.String())
+	}
+	return cidrs
+}
+
+func orgCacheKey(orgID int64) string {
+	return "org-" + strconv.FormatInt(orgID, 10)
+}
+
+func tokenCacheKey(orgID, tokenID int64) string {
+	return "token-" + strconv.FormatInt(orgID, 10) + "-" + strconv.FormatInt(tokenID, 10)
+}
@@ -0,0 +1,167 @@
+package ipallowlistimpl
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	claims "github.com/grafana/authlib/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/ipallowlist"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService_SetOrgAllowlist(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should store normalized ranges", func(t *testing.T) {
+		s, store := setupTestService(t)
+
+		err := s.SetOrgAllowlist(ctx, &ipallowlist.SetOrgAllowlistCommand{OrgID: 1, CIDRs: []string{"192.168.1.12/24", "203.0.113.5"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"192.168.1.0/24", "203.0.113.5/32"}, store.orgs[1])
+	})
+
+	t.Run("should reject invalid ranges", func(t *testing.T) {
+		s, store := setupTestService(t)
+
+		err := s.SetOrgAllowlist(ctx, &ipallowlist.SetOrgAllowlistCommand{OrgID: 1, CIDRs: []string{"192.168.1.0/33"}})
+		assert.ErrorIs(t, err, ipallowlist.ErrInvalidCIDR)
+		assert.Empty(t, store.orgs)
+	})
+
+	t.Run("should not let users block themselves", func(t *testing.T) {
+		s, store := setupTestService(t)
+
+		err := s.SetOrgAllowlist(ctx, &ipallowlist.SetOrgAllowlistCommand{OrgID: 1, CIDRs: []string{"10.0.0.0/8"}, ClientIP: net.ParseIP("203.0.113.5")})
+		assert.ErrorIs(t, err, ipallowlist.ErrSelfLockout)
+		assert.Empty(t, store.orgs)
+	})
+}
+
+func TestService_enforceHook(t *testing.T) {
+	ctx := context.Background()
+	newRequest := func(remoteAddr string) *authn.Request {
+		return &authn.Request{HTTPRequest: &http.Request{RemoteAddr: remoteAddr, Header: http.Header{}}}
+	}
+	userIdentity := func() *authn.Identity {
+		return &authn.Identity{ID: "1", Type: claims.TypeUser, OrgID: 1}
+	}
+
+	t.Run("should allow every address without allowlist", func(t *testing.T) {
+		s, _ := setupTestService(t)
+
+		require.NoError(t, s.enforceHook(ctx, userIdentity(), newRequest("203.0.113.5:1234")))
+	})
+
+	t.Run("should block addresses outside of the org allowlist", func(t *testing.T) {
+		s, _ := setupTestService(t)
+		require.NoError(t, s.SetOrgAllowlist(ctx, &ipallowlist.SetOrgAllowlistCommand{OrgID: 1, CIDRs: []string{"10.0.0.0/8"}}))
+
+		require.NoError(t, s.enforceHook(ctx, userIdentity(), newRequest("10.1.2.3:1234")))
+
+		err := s.enforceHook(ctx, userIdentity(), newRequest("203.0.113.5:1234"))
+		assert.ErrorIs(t, err, ipallowlist.ErrBlocked)
+		assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.blocked.WithLabelValues(scopeOrg)))
+	})
+
+	t.Run("should only apply the allowlist of the org of the request", func(t *testing.T) {
+		s, _ := setupTestService(t)
+		require.NoError(t, s.SetOrgAllowlist(ctx, &ipallowlist.SetOrgAllowlistCommand{OrgID: 2, CIDRs: []string{"10.0.0.0/8"}}))
+
+		require.NoError(t, s.enforceHook(ctx, userIdentity(), newRequest("203.0.113.5:1234")))
+	})
+
+	t.Run("should block addresses outside of the token allowlist", func(t *testing.T) {
+		s, _ := setupTestService(t)
+		require.NoError(t, s.SetTokenAllowlist(ctx, &ipallowlist.SetTokenAllowlistCommand{OrgID: 1, TokenID: 3, CIDRs: []string{"198.51.100.0/24"}}))
+		id := &authn.Identity{ID: "2", Type: claims.TypeServiceAccount, OrgID: 1}
+
+		r := newRequest("203.0.113.5:1234")
+		require.NoError(t, s.enforceHook(ctx, id, r), "the allowlist applies to the token only")
+
+		r.SetMeta(authn.MetaKeyAPIKeyID, "3")
+		err := s.enforceHook(ctx, id, r)
+		assert.ErrorIs(t, err, ipallowlist.ErrBlocked)
+		assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.blocked.WithLabelValues(scopeToken)))
+
+		r = newRequest("198.51.100.9:1234")
+		r.SetMeta(authn.MetaKeyAPIKeyID, "3")
+		require.NoError(t, s.enforceHook(ctx, id, r))
+	})
+
+	t.Run("should not block the image renderer", func(t *testing.T) {
+		s, _ := setupTestService(t)
+		require.NoError(t, s.SetOrgAllowlist(ctx, &ipallowlist.SetOrgAllowlistCommand{OrgID: 1, CIDRs: []string{"10.0.0.0/8"}}))
+		id := userIdentity()
+		id.AuthenticatedBy = login.RenderModule
+
+		require.NoError(t, s.enforceHook(ctx, id, newRequest("203.0.113.5:1234")))
+	})
+
+	t.Run("should apply updates immediately", func(t *testing.T) {
+		s, _ := setupTestService(t)
+		require.NoError(t, s.SetOrgAllowlist(ctx, &ipallowlist.SetOrgAllowlistCommand{OrgID: 1, CIDRs: []string{"10.0.0.0/8"}}))
+		require.Error(t, s.enforceHook(ctx, userIdentity(), newRequest("203.0.113.5:1234")))
+
+		require.NoError(t, s.SetOrgAllowlist(ctx, &ipallowlist.SetOrgAllowlistCommand{OrgID: 1}))
+		require.NoError(t, s.enforceHook(ctx, userIdentity(), newRequest("203.0.113.5:1234")))
+	})
+}
+
+func setupTestService(t *testing.T) (*Service, *fakeStore) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.IPAllowlist = setting.IPAllowlistSettings{Enabled: true}
+
+	store := &fakeStore{orgs: map[int64][]string{}, tokens: map[int64][]string{}}
+	return &Service{
+		cfg:      cfg,
+		store:    store,
+		networks: localcache.New(cacheTTL, 2*cacheTTL),
+		metrics:  newMetrics(nil),
+		log:      log.NewNopLogger(),
+		tracer:   tracing.InitializeTracerForTest(),
+	}, store
+}
+
+type fakeStore struct {
+	orgs   map[int64][]string
+	tokens map[int64][]string
+}
+
+func (f *fakeStore) GetOrgAllowlist(_ context.Context, orgID int64) ([]string, error) {
+	return f.orgs[orgID], nil
+}
+
+func (f *fakeStore) SaveOrgAllowlist(_ context.Context, orgID int64, cidrs []string) error {
+	if len(cidrs) == 0 {
+		delete(f.orgs, orgID)
+		return nil
+	}
+	f.orgs[orgID] = cidrs
+	return nil
+}
+
+func (f *fakeStore) GetTokenAllowlist(_ context.Context, _, tokenID int64) ([]string, error) {
+	return f.tokens[tokenID], nil
+}
+
+func (f *fakeStore) SaveTokenAllowlist(_ context.Context, _, tokenID int64, cidrs []string) error {
+	if len(cidrs) == 0 {
+		delete(f.tokens, tokenID)
+		return nil
+	}
+	f.tokens[tokenID] = cidrs
+	return nil
+}
@@ -0,0 +1,142 @@
+package ipallowlistimpl
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+type orgIPAllowlist struct {
+	ID      int64     `xorm:"pk autoincr 'id'"`
+	OrgID   int64     `xorm:"org_id"`
+	CIDRs   string    `xorm:"cidrs"`
+	Updated time.Time `xorm:"updated"`
+}
+
+func (orgIPAllowlist) TableName() string {
+	return "org_ip_allowlist"
+}
+
+type apiKeyIPAllowlist struct {
+	ID       int64     `xorm:"pk autoincr 'id'"`
+	OrgID    int64     `xorm:"org_id"`
+	APIKeyID int64     `xorm:"api_key_id"`
+	CIDRs    string    `xorm:"cidrs"`
+	Updated  time.Time `xorm:"updated"`
+}
+
+func (apiKeyIPAllowlist) TableName() string {
+	return "api_key_ip_allowlist"
+}
+
+type store interface {
+	// GetOrgAllowlist returns an empty list when the org doesn't have an allowlist
+	GetOrgAllowlist(ctx context.Context, orgID int64) ([]string, error)
+	// SaveOrgAllowlist replaces the allowlist of the org, an empty list removes it
+	SaveOrgAllowlist(ctx context.Context, orgID int64, cidrs []string) error
+	// GetTokenAllowlist returns an empty list when the token doesn't have an allowlist
+	GetTokenAllowlist(ctx context.Context, orgID, tokenID int64) ([]string, error)
+	// SaveTokenAllowlist replaces the allowlist of the token, an empty list removes it
+	SaveTokenAllowlist(ctx context.Context, orgID, tokenID int64, cidrs []string) error
+}
+
+type xormStore struct {
+	db  db.DB
+	now func() time.Time
+}
+
+func (s *xormStore) GetOrgAllowlist(ctx context.Context, orgID int64) ([]string, error) {
+	allowlist := &orgIPAllowlist{}
+	var has bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		has, err = sess.Where("org_id = ?", orgID).Get(allowlist)
+		return err
+	})
+	if err != nil || !has {
+		return []string{}, err
+	}
+	return decodeCIDRs(allowlist.CIDRs)
+}
+
+func (s *xormStore) SaveOrgAllowlist(ctx context.Context, orgID int64, cidrs []string) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if len(cidrs) == 0 {
+			_, err := sess.Exec("DELETE FROM org_ip_allowlist WHERE org_id = ?", orgID)
+			return err
+		}
+
+		encoded, err := json.Marshal(cidrs)
+		if err != nil {
+			return err
+		}
+		allowlist := &orgIPAllowlist{OrgID: orgID, CIDRs: string(encoded), Updated: s.now()}
+
+		existing := &orgIPAllowlist{}
+		has, err := sess.Where("org_id = ?", orgID).Get(existing)
+		if err != nil {
+			return err
+		}
+		if has {
+			allowlist.ID = existing.ID
+			_, err = sess.ID(allowlist.ID).AllCols().Update(allowlist)
+			return err
+		}
+
+		_, err = sess.Insert(allowlist)
+		return err
+	})
+}
+
+func (s *xormStore) GetTokenAllowlist(ctx context.Context, orgID, tokenID int64) ([]string, error) {
+	allowlist := &apiKeyIPAllowlist{}
+	var has bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		has, err = sess.Where("org_id = ? AND api_key_id = ?", orgID, tokenID).Get(allowlist)
+		return err
+	})
+	if err != nil || !has {
+		return []string{}, err
+	}
+	return decodeCIDRs(allowlist.CIDRs)
+}
+
+func (s *xormStore) SaveTokenAllowlist(ctx context.Context, orgID, tokenID int64, cidrs []string) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if len(cidrs) == 0 {
+			_, err := sess.Exec("DELETE FROM api_key_ip_allowlist WHERE org_id = ? AND api_key_id = ?", orgID, tokenID)
+			return err
+		}
+
+		encoded, err := json.Marshal(cidrs)
+		if err != nil {
+			return err
+		}
+		allowlist := &apiKeyIPAllowlist{OrgID: orgID, APIKeyID: tokenID, CIDRs: string(encoded), Updated: s.now()}
+
+		existing := &apiKeyIPAllowlist{}
+		has, err := sess.Where("api_key_id = ?", tokenID).Get(existing)
+		if err != nil {
+			return err
+		}
+		if has {
+			allowlist.ID = existing.ID
+			_, err = sess.ID(allowlist.ID).AllCols().Update(allowlist)
+			return err
+		}
+
+		_, err = sess.Insert(allowlist)
+		return err
+	})
+}
+
+func decodeCIDRs(encoded string) ([]string, error) {
+	cidrs := []string{}
+	if err := json.Unmarshal([]byte(encoded), &cidrs); err != nil {
+		return nil, err
+	}
+	return cidrs, nil
+}
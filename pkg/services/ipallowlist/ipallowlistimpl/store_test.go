@@ -0,0 +1,70 @@
+package ipallowlistimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	store := &xormStore{db: db.InitTestDB(t), now: time.Now}
+
+	t.Run("should replace and remove the allowlist of an org", func(t *testing.T) {
+		cidrs, err := store.GetOrgAllowlist(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, cidrs)
+
+		require.NoError(t, store.SaveOrgAllowlist(ctx, 1, []string{"10.0.0.0/8"}))
+		require.NoError(t, store.SaveOrgAllowlist(ctx, 1, []string{"10.0.0.0/8", "192.168.1.0/24"}))
+		require.NoError(t, store.SaveOrgAllowlist(ctx, 2, []string{"172.16.0.0/12"}))
+
+		cidrs, err = store.GetOrgAllowlist(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.0/24"}, cidrs)
+
+		require.NoError(t, store.SaveOrgAllowlist(ctx, 1, nil))
+		cidrs, err = store.GetOrgAllowlist(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, cidrs)
+
+		cidrs, err = store.GetOrgAllowlist(ctx, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"172.16.0.0/12"}, cidrs, "the allowlists of the other orgs are kept")
+	})
+
+	t.Run("should replace and remove the allowlist of a token", func(t *testing.T) {
+		cidrs, err := store.GetTokenAllowlist(ctx, 1, 10)
+		require.NoError(t, err)
+		assert.Empty(t, cidrs)
+
+		require.NoError(t, store.SaveTokenAllowlist(ctx, 1, 10, []string{"10.0.0.1/32"}))
+		require.NoError(t, store.SaveTokenAllowlist(ctx, 1, 10, []string{"10.0.0.2/32"}))
+
+		cidrs, err = store.GetTokenAllowlist(ctx, 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.2/32"}, cidrs)
+
+		cidrs, err = store.GetTokenAllowlist(ctx, 2, 10)
+		require.NoError(t, err)
+		assert.Empty(t, cidrs, "the allowlist is scoped to the org of the token")
+
+		require.NoError(t, store.SaveTokenAllowlist(ctx, 1, 10, []string{}))
+		cidrs, err = store.GetTokenAllowlist(ctx, 1, 10)
+		require.NoError(t, err)
+		assert.Empty(t, cidrs)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/kmsproviders"
 	grafana "github.com/grafana/grafana/pkg/services/kmsproviders/defaultprovider"
+	"github.com/grafana/grafana/pkg/services/kmsproviders/vaultprovider"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -24,7 +25,10 @@ func ProvideService(enc encryption.Internal, cfg *setting.Cfg, features featurem
 }
 
 func (s Service) Provide() (map[secrets.ProviderID]secrets.Provider, error) {
-	return map[secrets.ProviderID]secrets.Provider{
-		kmsproviders.Default: grafana.New(s.cfg, s.enc),
-	}, nil
+	providers, err := vaultprovider.ProvideProviders(s.cfg)
+	if err != nil {
+		return nil, err
+	}
+	providers[kmsproviders.Default] = grafana.New(s.cfg, s.enc)
+	return providers, nil
 }
@@ -0,0 +1,111 @@
+package vaultprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// authResponse is the response of the AppRole login and of the token renewal.
+type authResponse struct {
+	Auth struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int64  `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+}
+
+func (p *Provider) currentToken() string {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.token
+}
+
+func (p *Provider) ensureToken(ctx context.Context) error {
+	if p.currentToken() != "" {
+		return nil
+	}
+	return p.login(ctx)
+}
+
+// authenticate logs in with the AppRole, or looks up the TTL of the configured token.
+func (p *Provider) authenticate(ctx context.Context) error {
+	if p.roleID != "" {
+		return p.login(ctx)
+	}
+
+	var resp struct {
+		Data struct {
+			TTL       int64 `json:"ttl"`
+			Renewable bool  `json:"renewable"`
+		} `json:"data"`
+	}
+	if err := p.send(ctx, http.MethodGet, "auth/token/lookup-self", p.currentToken(), nil, &resp); err != nil {
+		return fmt.Errorf("failed to look up the vault token: %w", err)
+	}
+	p.setToken(p.currentToken(), resp.Data.TTL, resp.Data.Renewable)
+	return nil
+}
+
+func (p *Provider) login(ctx context.Context) error {
+	if p.roleID == "" {
+		return errors.New("the vault token is missing")
+	}
+	var resp authResponse
+	body := map[string]string{"role_id": p.roleID, "secret_id": p.secretID}
+	if err := p.send(ctx, http.MethodPost, "auth/"+p.approleMount+"/login", "", body, &resp); err != nil {
+		return fmt.Errorf("failed to log in to vault with the AppRole: %w", err)
+	}
+	p.setToken(resp.Auth.ClientToken, resp.Auth.LeaseDuration, resp.Auth.Renewable)
+	p.log.Debug("Logged in to vault with the AppRole", "ttl", resp.Auth.LeaseDuration)
+	return nil
+}
+
+// renewToken extends the TTL of the token. When the token can't be renewed anymore, which happens when it reached
+// its max TTL, a new AppRole login is done.
+func (p *Provider) renewToken(ctx context.Context) error {
+	p.mtx.Lock()
+	renewable := p.renewable
+	p.mtx.Unlock()
+
+	if renewable {
+		var resp authResponse
+		err := p.send(ctx, http.MethodPost, "auth/token/renew-self", p.currentToken(), map[string]string{}, &resp)
+		if err == nil && resp.Auth.LeaseDuration > 0 {
+			p.setToken(p.currentToken(), resp.Auth.LeaseDuration, resp.Auth.Renewable)
+			p.log.Debug("Renewed the vault token", "ttl", resp.Auth.LeaseDuration)
+			return nil
+		}
+		if p.roleID == "" {
+			if err == nil {
+				err = errors.New("the token can't be renewed anymore")
+			}
+			return err
+		}
+	}
+	if p.roleID != "" {
+		return p.login(ctx)
+	}
+	return nil
+}
+
+func (p *Provider) setToken(token string, ttlSeconds int64, renewable bool) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.token = token
+	p.tokenTTL = time.Duration(ttlSeconds) * time.Second
+	p.renewable = renewable
+}
+
+// renewalInterval is half of the TTL of the token, so that a failed renewal is retried before it expires. The
+// tokens without TTL, like the root tokens, never expire and the renewal is a no-op for them.
+func (p *Provider) renewalInterval() time.Duration {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.tokenTTL <= 0 {
+		return time.Hour
+	}
+	return p.tokenTTL / 2
+}
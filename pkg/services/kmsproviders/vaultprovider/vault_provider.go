@@ -0,0 +1,277 @@
+package vaultprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// Kind is the kind of the providers, configured in the [security.encryption.vault.<name>]
+	// sections and identified as vault.<name>.
+	Kind = "vault"
+
+	sectionPrefix = "security.encryption." + Kind + "."
+)
+
+// Provider encrypts the data keys with a key of the transit secrets engine of HashiCorp Vault, which
+// never leaves Vault. The token is renewed, or the AppRole login is done again, in the background.
+type Provider struct {
+	address      string
+	namespace    string
+	transitPath  string
+	keyName      string
+	approleMount string
+	roleID       string
+	secretID     string
+
+	keyCheckInterval time.Duration
+	client           *http.Client
+	log              log.Logger
+
+	mtx        sync.Mutex
+	token      string
+	tokenTTL   time.Duration
+	renewable  bool
+	keyVersion int
+	onRotation func(ctx context.Context) error
+}
+
+// ProvideProviders returns a provider for each [security.encryption.vault.<name>] section of the configuration.
+func ProvideProviders(cfg *setting.Cfg) (map[secrets.ProviderID]secrets.Provider, error) {
+	providers := make(map[secrets.ProviderID]secrets.Provider)
+	for _, section := range cfg.Raw.Sections() {
+		name, ok := strings.CutPrefix(section.Name(), sectionPrefix)
+		if !ok || name == "" {
+			continue
+		}
+		p, err := New(name, cfg.SectionWithEnvOverrides(section.Name()))
+		if err != nil {
+			return nil, err
+		}
+		providers[secrets.ProviderID(Kind+"."+name)] = p
+	}
+	return providers, nil
+}
+
+// New creates the provider from its configuration section, authenticated with either a token or an AppRole.
+func New(name string, section *setting.DynamicSection) (*Provider, error) {
+	p := &Provider{
+		address:          strings.TrimSuffix(section.Key("url").String(), "/"),
+		namespace:        section.Key("namespace").String(),
+		transitPath:      strings.Trim(section.Key("transit_path").MustString("transit"), "/"),
+		keyName:          section.Key("key_name").String(),
+		approleMount:     strings.Trim(section.Key("approle_mount_path").MustString("approle"), "/"),
+		roleID:           section.Key("approle_role_id").String(),
+		secretID:         section.Key("approle_secret_id").String(),
+		token:            section.Key("token").String(),
+		keyCheckInterval: section.Key("key_check_interval").MustDuration(10 * time.Minute),
+		client:           &http.Client{Timeout: section.Key("timeout").MustDuration(10 * time.Second)},
+		log:              log.New("encryption.vault", "name", name),
+	}
+
+	if p.address == "" {
+		return nil, fmt.Errorf("missing url for vault encryption provider %s", name)
+	}
+	if p.keyName == "" {
+		return nil, fmt.Errorf("missing key_name for vault encryption provider %s", name)
+	}
+	if p.token == "" && (p.roleID == "" || p.secretID == "") {
+		return nil, fmt.Errorf("vault encryption provider %s requires either a token or an approle_role_id and approle_secret_id", name)
+	}
+	if p.keyCheckInterval <= 0 {
+		return nil, fmt.Errorf("key_check_interval of vault encryption provider %s must be positive", name)
+	}
+	if p.token != "" && p.roleID != "" {
+		return nil, fmt.Errorf("vault encryption provider %s can't use both a token and an AppRole", name)
+	}
+	return p, nil
+}
+
+func (p *Provider) Encrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	body := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(blob)}
+	if err := p.do(ctx, http.MethodPost, p.transitPath+"/encrypt/"+url.PathEscape(p.keyName), body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to encrypt with vault: %w", err)
+	}
+	return []byte(resp.Data.Ciphertext), nil
+}
+
+func (p *Provider) Decrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	body := map[string]string{"ciphertext": string(blob)}
+	if err := p.do(ctx, http.MethodPost, p.transitPath+"/decrypt/"+url.PathEscape(p.keyName), body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decrypt with vault: %w", err)
+	}
+	return base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+}
+
+// OnKeyRotation registers the function called when a new version of the transit key is detected, so that the
+// data keys are encrypted with it. The older versions still decrypt the data keys until they're re-encrypted.
+func (p *Provider) OnKeyRotation(fn func(ctx context.Context) error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.onRotation = fn
+}
+
+// CheckHealth checks that Vault is unsealed, and that the key can be used with the token.
+func (p *Provider) CheckHealth(ctx context.Context) error {
+	if err := p.do(ctx, http.MethodGet, "sys/health?standbyok=true&perfstandbyok=true", nil, nil); err != nil {
+		return err
+	}
+	_, err := p.Encrypt(ctx, []byte("health"))
+	return err
+}
+
+// Run renews the token before it expires, and checks periodically whether the transit key was rotated.
+func (p *Provider) Run(ctx context.Context) error {
+	if err := p.authenticate(ctx); err != nil {
+		p.log.Error("Failed to authenticate to vault", "error", err)
+	}
+	p.checkKeyVersion(ctx)
+
+	renew := time.NewTimer(p.renewalInterval())
+	keyCheck := time.NewTicker(p.keyCheckInterval)
+	defer renew.Stop()
+	defer keyCheck.Stop()
+
+	for {
+		select {
+		case <-renew.C:
+			if err := p.renewToken(ctx); err != nil {
+				p.log.Error("Failed to renew the vault token", "error", err)
+			}
+			renew.Reset(p.renewalInterval())
+		case <-keyCheck.C:
+			p.checkKeyVersion(ctx)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// checkKeyVersion compares the latest version of the transit key with the one seen before, which requires the
+// read capability on the key. The data keys are re-encrypted when the key was rotated.
+func (p *Provider) checkKeyVersion(ctx context.Context) {
+	var resp struct {
+		Data struct {
+			LatestVersion int `json:"latest_version"`
+		} `json:"data"`
+	}
+	if err := p.do(ctx, http.MethodGet, p.transitPath+"/keys/"+url.PathEscape(p.keyName), nil, &resp); err != nil {
+		p.log.Warn("Failed to read the vault transit key version", "error", err)
+		return
+	}
+
+	p.mtx.Lock()
+	previous := p.keyVersion
+	p.keyVersion = resp.Data.LatestVersion
+	onRotation := p.onRotation
+	p.mtx.Unlock()
+
+	if previous == 0 || resp.Data.LatestVersion <= previous {
+		return
+	}
+	p.log.Info("Vault transit key was rotated", "previous version", previous, "version", resp.Data.LatestVersion)
+	if onRotation == nil {
+		return
+	}
+	if err := onRotation(ctx); err != nil {
+		p.log.Error("Failed to re-encrypt the data keys with the new vault key version", "error", err)
+	}
+}
+
+type vaultError struct {
+	statusCode int
+	errors     []string
+}
+
+func (e *vaultError) Error() string {
+	if len(e.errors) == 0 {
+		return fmt.Sprintf("vault returned status code %d", e.statusCode)
+	}
+	return fmt.Sprintf("vault returned status code %d: %s", e.statusCode, strings.Join(e.errors, ", "))
+}
+
+// do sends the request to the HTTP API of Vault, with the body encoded as JSON, and decodes the JSON response to
+// out when it's not nil. When the token was revoked or expired, an AppRole login is done again before retrying.
+func (p *Provider) do(ctx context.Context, method, path string, body any, out any) error {
+	if err := p.ensureToken(ctx); err != nil {
+		return err
+	}
+	err := p.send(ctx, method, path, p.currentToken(), body, out)
+	var vaultErr *vaultError
+	if errors.As(err, &vaultErr) && vaultErr.statusCode == http.StatusForbidden && p.roleID != "" {
+		if err := p.login(ctx); err != nil {
+			return err
+		}
+		return p.send(ctx, method, path, p.currentToken(), body, out)
+	}
+	return err
+}
+
+func (p *Provider) send(ctx context.Context, method, path, token string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.address+"/v1/"+path, reader)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		vaultErr := &vaultError{statusCode: resp.StatusCode}
+		var errResp struct {
+			Errors []string `json:"errors"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&errResp) == nil {
+			vaultErr.errors = errResp.Errors
+		}
+		return vaultErr
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode the vault response: %w", err)
+	}
+	return nil
+}
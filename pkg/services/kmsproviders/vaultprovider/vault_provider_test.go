@@ -0,0 +1,267 @@
+package vaultprovider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// fakeVault implements the transit secrets engine with a key whose versions prefix the ciphertexts, the token
+// auth method and the AppRole auth method.
+type fakeVault struct {
+	mtx        sync.Mutex
+	tokens     map[string]bool
+	keyVersion int
+	sealed     bool
+	logins     int
+	renewals   int
+}
+
+func newFakeVault(t *testing.T) (*fakeVault, string) {
+	t.Helper()
+	f := &fakeVault{tokens: map[string]bool{"static-token": true}, keyVersion: 1}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server.URL
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	var body map[string]string
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	switch r.URL.Path {
+	case "/v1/sys/health":
+		if f.sealed {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		return
+	case "/v1/auth/approle/login":
+		if body["role_id"] != "role" || body["secret_id"] != "secret" {
+			writeVaultError(w, http.StatusBadRequest, "invalid role or secret ID")
+			return
+		}
+		f.logins++
+		token := "approle-token-" + strconv.Itoa(f.logins)
+		f.tokens[token] = true
+		writeJSON(w, map[string]any{"auth": map[string]any{"client_token": token, "lease_duration": 60, "renewable": true}})
+		return
+	}
+
+	token := r.Header.Get("X-Vault-Token")
+	if !f.tokens[token] {
+		writeVaultError(w, http.StatusForbidden, "permission denied")
+		return
+	}
+
+	switch r.URL.Path {
+	case "/v1/auth/token/lookup-self":
+		writeJSON(w, map[string]any{"data": map[string]any{"ttl": 0, "renewable": false}})
+	case "/v1/auth/token/renew-self":
+		f.renewals++
+		writeJSON(w, map[string]any{"auth": map[string]any{"client_token": token, "lease_duration": 60, "renewable": true}})
+	case "/v1/transit/keys/grafana":
+		writeJSON(w, map[string]any{"data": map[string]any{"latest_version": f.keyVersion}})
+	case "/v1/transit/encrypt/grafana":
+		ciphertext := "vault:v" + strconv.Itoa(f.keyVersion) + ":" + body["plaintext"]
+		writeJSON(w, map[string]any{"data": map[string]any{"ciphertext": ciphertext}})
+	case "/v1/transit/decrypt/grafana":
+		parts := strings.SplitN(body["ciphertext"], ":", 3)
+		if len(parts) != 3 || parts[0] != "vault" {
+			writeVaultError(w, http.StatusBadRequest, "invalid ciphertext")
+			return
+		}
+		writeJSON(w, map[string]any{"data": map[string]any{"plaintext": parts[2]}})
+	default:
+		writeVaultError(w, http.StatusNotFound, "unsupported path")
+	}
+}
+
+func (f *fakeVault) counts() (logins, renewals int) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.logins, f.renewals
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeVaultError(w http.ResponseWriter, statusCode int, message string) {
+	w.WriteHeader(statusCode)
+	writeJSON(w, map[string]any{"errors": []string{message}})
+}
+
+func newTestProvider(t *testing.T, config string) *Provider {
+	t.Helper()
+	cfg, err := setting.NewCfgFromBytes([]byte(config))
+	require.NoError(t, err)
+	providers, err := ProvideProviders(cfg)
+	require.NoError(t, err)
+	require.Len(t, providers, 1)
+	return providers["vault.test"].(*Provider)
+}
+
+func TestProvideProviders(t *testing.T) {
+	cfg, err := setting.NewCfgFromBytes([]byte(`
+[security.encryption.vault.v1]
+url = http://vault:8200
+key_name = grafana
+token = token
+
+[security.encryption.vault.v2]
+url = http://vault:8200
+key_name = grafana
+approle_role_id = role
+approle_secret_id = secret
+
+[security.encryption.other.v1]
+key = value
+`))
+	require.NoError(t, err)
+	providers, err := ProvideProviders(cfg)
+	require.NoError(t, err)
+	require.Len(t, providers, 2)
+	require.Contains(t, providers, secrets.ProviderID("vault.v1"))
+	require.Contains(t, providers, secrets.ProviderID("vault.v2"))
+}
+
+func TestNew_InvalidConfiguration(t *testing.T) {
+	testCases := map[string]string{
+		"missing url":           "key_name = grafana\ntoken = token",
+		"missing key":           "url = http://vault:8200\ntoken = token",
+		"missing auth":          "url = http://vault:8200\nkey_name = grafana",
+		"missing secret id":     "url = http://vault:8200\nkey_name = grafana\napprole_role_id = role",
+		"token and AppRole set": "url = http://vault:8200\nkey_name = grafana\ntoken = token\napprole_role_id = role\napprole_secret_id = secret",
+	}
+	for name, section := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := setting.NewCfgFromBytes([]byte("[security.encryption.vault.test]\n" + section))
+			require.NoError(t, err)
+			_, err = ProvideProviders(cfg)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestProvider_EncryptDecrypt(t *testing.T) {
+	_, address := newFakeVault(t)
+	p := newTestProvider(t, `
+[security.encryption.vault.test]
+url = `+address+`
+key_name = grafana
+token = static-token
+`)
+
+	ctx := context.Background()
+	encrypted, err := p.Encrypt(ctx, []byte("data key"))
+	require.NoError(t, err)
+	require.Equal(t, "vault:v1:"+base64.StdEncoding.EncodeToString([]byte("data key")), string(encrypted))
+
+	decrypted, err := p.Decrypt(ctx, encrypted)
+	require.NoError(t, err)
+	require.Equal(t, []byte("data key"), decrypted)
+
+	_, err = p.Decrypt(ctx, []byte("invalid"))
+	require.ErrorContains(t, err, "invalid ciphertext")
+}
+
+func TestProvider_AppRole(t *testing.T) {
+	f, address := newFakeVault(t)
+	p := newTestProvider(t, `
+[security.encryption.vault.test]
+url = `+address+`
+key_name = grafana
+approle_role_id = role
+approle_secret_id = secret
+`)
+
+	ctx := context.Background()
+	_, err := p.Encrypt(ctx, []byte("data key"))
+	require.NoError(t, err)
+	logins, _ := f.counts()
+	require.Equal(t, 1, logins)
+	require.Equal(t, "approle-token-1", p.currentToken())
+
+	t.Run("renews the token", func(t *testing.T) {
+		require.NoError(t, p.renewToken(ctx))
+		logins, renewals := f.counts()
+		require.Equal(t, 1, renewals)
+		require.Equal(t, 1, logins)
+		require.Equal(t, "approle-token-1", p.currentToken())
+	})
+
+	t.Run("logs in again when the token was revoked", func(t *testing.T) {
+		f.mtx.Lock()
+		delete(f.tokens, "approle-token-1")
+		f.mtx.Unlock()
+
+		_, err := p.Encrypt(ctx, []byte("data key"))
+		require.NoError(t, err)
+		logins, _ := f.counts()
+		require.Equal(t, 2, logins)
+		require.Equal(t, "approle-token-2", p.currentToken())
+	})
+}
+
+func TestProvider_KeyRotation(t *testing.T) {
+	f, address := newFakeVault(t)
+	p := newTestProvider(t, `
+[security.encryption.vault.test]
+url = `+address+`
+key_name = grafana
+token = static-token
+`)
+
+	rotations := 0
+	p.OnKeyRotation(func(ctx context.Context) error {
+		rotations++
+		return nil
+	})
+
+	ctx := context.Background()
+	p.checkKeyVersion(ctx)
+	p.checkKeyVersion(ctx)
+	require.Equal(t, 0, rotations)
+
+	f.mtx.Lock()
+	f.keyVersion = 2
+	f.mtx.Unlock()
+	p.checkKeyVersion(ctx)
+	require.Equal(t, 1, rotations)
+
+	encrypted, err := p.Encrypt(ctx, []byte("data key"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(encrypted), "vault:v2:"))
+}
+
+func TestProvider_CheckHealth(t *testing.T) {
+	f, address := newFakeVault(t)
+	p := newTestProvider(t, `
+[security.encryption.vault.test]
+url = `+address+`
+key_name = grafana
+token = static-token
+`)
+
+	ctx := context.Background()
+	require.NoError(t, p.CheckHealth(ctx))
+
+	f.mtx.Lock()
+	f.sealed = true
+	f.mtx.Unlock()
+	require.ErrorContains(t, p.CheckHealth(ctx), "status code 503")
+}
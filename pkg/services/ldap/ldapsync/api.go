@@ -0,0 +1,124 @@
+package ldapsync
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+
+	router.Group("/api/admin/ldap", func(ldapRoute routing.RouteRegister) {
+		ldapRoute.Post("/sync", authorize(ac.EvalPermission(ac.ActionLDAPUsersSync)), routing.Wrap(s.PostSyncAllUsersWithLDAP))
+		ldapRoute.Get("/sync-reports", authorize(ac.EvalPermission(ac.ActionLDAPStatusRead)), routing.Wrap(s.GetLDAPSyncReports))
+		ldapRoute.Get("/sync-reports/:uid", authorize(ac.EvalPermission(ac.ActionLDAPStatusRead)), routing.Wrap(s.GetLDAPSyncReport))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route POST /admin/ldap/sync admin_ldap postSyncAllUsersWithLDAP
+//
+// Synchronizes the org roles and team memberships of all users that have logged in with LDAP.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `ldap.user:sync`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: ldapSyncReportResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) PostSyncAllUsersWithLDAP(c *contextmodel.ReqContext) response.Response {
+	if !s.cfg.Enabled {
+		return response.Error(http.StatusBadRequest, "LDAP is not enabled", nil)
+	}
+
+	report, err := s.SyncAll(c.Req.Context(), TriggerManual)
+	if err != nil {
+		if errors.Is(err, ErrSyncInProgress) {
+			return response.Error(http.StatusConflict, ErrSyncInProgress.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to synchronize LDAP users", err)
+	}
+
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:route GET /admin/ldap/sync-reports admin_ldap getLDAPSyncReports
+//
+// Lists the reports of the latest LDAP synchronizations, most recent first.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `ldap.status:read`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: ldapSyncReportsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) GetLDAPSyncReports(c *contextmodel.ReqContext) response.Response {
+	reports, err := s.reports.list(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get LDAP synchronization reports", err)
+	}
+
+	return response.JSON(http.StatusOK, reports)
+}
+
+// swagger:route GET /admin/ldap/sync-reports/{report_uid} admin_ldap getLDAPSyncReport
+//
+// Returns the report of a single LDAP synchronization.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `ldap.status:read`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: ldapSyncReportResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) GetLDAPSyncReport(c *contextmodel.ReqContext) response.Response {
+	report, err := s.reports.get(c.Req.Context(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		if errors.Is(err, ErrReportNotFound) {
+			return response.Error(http.StatusNotFound, ErrReportNotFound.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get LDAP synchronization report", err)
+	}
+
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:parameters getLDAPSyncReport
+type GetLDAPSyncReportParams struct {
+	// in:path
+	// required:true
+	ReportUID string `json:"report_uid"`
+}
+
+// swagger:response ldapSyncReportResponse
+type LDAPSyncReportResponse struct {
+	// in:body
+	Body *Report `json:"body"`
+}
+
+// swagger:response ldapSyncReportsResponse
+type LDAPSyncReportsResponse struct {
+	// in:body
+	Body []*Report `json:"body"`
+}
@@ -0,0 +1,411 @@
+package ldapsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/ldap"
+	"github.com/grafana/grafana/pkg/services/ldap/multildap"
+	"github.com/grafana/grafana/pkg/services/ldap/service"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// minSyncInterval is the shortest time allowed between two scheduled synchronizations
+	minSyncInterval = 10 * time.Minute
+	usersPageSize   = 500
+)
+
+var (
+	ErrSyncInProgress = errors.New("LDAP synchronization already in progress")
+	ErrReportNotFound = errors.New("LDAP synchronization report not found")
+)
+
+// Service periodically synchronizes the org roles and team memberships of
+// every user that has logged in with LDAP, and keeps a report of the changes
+// applied by each run.
+type Service struct {
+	cfg                    *ldap.Config
+	adminUser              string
+	ldapService            service.LDAP
+	ldapGroups             ldap.Groups
+	identitySynchronizer   authn.IdentitySynchronizer
+	userService            user.Service
+	orgService             org.Service
+	teamService            team.Service
+	teamPermissionsService ac.TeamPermissionsService
+	sessionService         auth.UserTokenService
+	serverLock             *serverlock.ServerLockService
+	reports                *reportStore
+	log                    log.Logger
+
+	// syncMu prevents a manual synchronization from overlapping a scheduled one
+	syncMu sync.Mutex
+}
+
+func ProvideService(
+	cfg *setting.Cfg, router routing.RouteRegister, accessControl ac.AccessControl,
+	ldapService service.LDAP, ldapGroups ldap.Groups, identitySynchronizer authn.IdentitySynchronizer,
+	userService user.Service, orgService org.Service, teamService team.Service,
+	teamPermissionsService ac.TeamPermissionsService, sessionService auth.UserTokenService,
+	serverLock *serverlock.ServerLockService, kv kvstore.KVStore,
+) *Service {
+	s := &Service{
+		cfg:                    ldap.GetLDAPConfig(cfg),
+		adminUser:              cfg.AdminUser,
+		ldapService:            ldapService,
+		ldapGroups:             ldapGroups,
+		identitySynchronizer:   identitySynchronizer,
+		userService:            userService,
+		orgService:             orgService,
+		teamService:            teamService,
+		teamPermissionsService: teamPermissionsService,
+		sessionService:         sessionService,
+		serverLock:             serverLock,
+		reports:                newReportStore(kv),
+		log:                    log.New("ldap.sync"),
+	}
+
+	s.registerRoutes(router, accessControl)
+
+	return s
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Enabled || !s.cfg.ActiveSyncEnabled
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	schedule, err := cron.ParseStandard(s.cfg.SyncCron)
+	if err != nil {
+		s.log.Error("Invalid LDAP synchronization schedule, active sync is disabled", "syncCron", s.cfg.SyncCron, "error", err)
+		return nil
+	}
+
+	var last time.Time
+	for {
+		next := schedule.Next(time.Now())
+		for next.Sub(last) < minSyncInterval {
+			next = schedule.Next(next)
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		last = next
+
+		// the lock interval is shorter than the sync interval to leave room for clock drift between instances
+		err := s.serverLock.LockAndExecute(ctx, "ldap active sync", minSyncInterval/2, func(ctx context.Context) {
+			if _, err := s.SyncAll(ctx, TriggerSchedule); err != nil {
+				s.log.Error("Failed to synchronize LDAP users", "error", err)
+			}
+		})
+		if err != nil {
+			s.log.Error("Failed to acquire lock for LDAP synchronization", "error", err)
+		}
+	}
+}
+
+// SyncAll synchronizes every user that has logged in with LDAP and stores the
+// report of the changes applied.
+func (s *Service) SyncAll(ctx context.Context, trigger Trigger) (*Report, error) {
+	if !s.cfg.Enabled {
+		return nil, service.ErrLDAPNotEnabled
+	}
+
+	if !s.syncMu.TryLock() {
+		return nil, ErrSyncInProgress
+	}
+	defer s.syncMu.Unlock()
+
+	report := &Report{
+		UID:       util.GenerateShortUID(),
+		Trigger:   trigger,
+		StartedAt: time.Now(),
+		Changes:   []Change{},
+		Failures:  []UserFailure{},
+	}
+
+	for page := 1; ; page++ {
+		result, err := s.userService.Search(ctx, &user.SearchUsersQuery{
+			SignedInUser: &user.SignedInUser{
+				Login:          "ldap-sync",
+				IsGrafanaAdmin: true,
+				Permissions:    map[int64]map[string][]string{ac.GlobalOrgID: {ac.ActionUsersRead: {ac.ScopeGlobalUsersAll}}},
+			},
+			AuthModule: login.LDAPAuthModule,
+			Page:       page,
+			Limit:      usersPageSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list LDAP users: %w", err)
+		}
+
+		for _, usr := range result.Users {
+			changes, err := s.syncUser(ctx, usr)
+			report.Changes = append(report.Changes, changes...)
+			if err != nil {
+				s.log.Warn("Failed to synchronize LDAP user", "userID", usr.ID, "login", usr.Login, "error", err)
+				report.Failures = append(report.Failures, UserFailure{UserID: usr.ID, Login: usr.Login, Error: err.Error()})
+				continue
+			}
+			report.UsersSynced++
+		}
+
+		if len(result.Users) < usersPageSize {
+			break
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	s.log.Info("Synchronized LDAP users", "users", report.UsersSynced, "changes", len(report.Changes), "failures", len(report.Failures))
+
+	if err := s.reports.add(ctx, report); err != nil {
+		return nil, fmt.Errorf("failed to store LDAP synchronization report: %w", err)
+	}
+
+	return report, nil
+}
+
+func (s *Service) syncUser(ctx context.Context, usr *user.UserSearchHitDTO) ([]Change, error) {
+	info, err := s.ldapService.User(usr.Login)
+	if errors.Is(err, multildap.ErrDidNotFindUser) {
+		return s.disableUser(ctx, usr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := s.userOrgRoles(ctx, usr.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.identitySynchronizer.SyncIdentity(ctx, s.identityFromLDAPInfo(info)); err != nil {
+		return nil, err
+	}
+
+	after, err := s.userOrgRoles(ctx, usr.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := diffOrgRoles(usr, before, after)
+	teamChanges, err := s.syncTeams(ctx, usr, info, after)
+	return append(changes, teamChanges...), err
+}
+
+// disableUser disables users that have been removed from LDAP and logs them out
+func (s *Service) disableUser(ctx context.Context, usr *user.UserSearchHitDTO) ([]Change, error) {
+	if usr.IsDisabled {
+		return nil, nil
+	}
+
+	if usr.Login == s.adminUser {
+		return nil, fmt.Errorf("refusing to disable grafana super admin %q", usr.Login)
+	}
+
+	isDisabled := true
+	if err := s.userService.Update(ctx, &user.UpdateUserCommand{UserID: usr.ID, IsDisabled: &isDisabled}); err != nil {
+		return nil, err
+	}
+
+	change := Change{Type: ChangeUserDisabled, UserID: usr.ID, Login: usr.Login}
+	if err := s.sessionService.RevokeAllUserTokens(ctx, usr.ID); err != nil {
+		return []Change{change}, err
+	}
+
+	return []Change{change}, nil
+}
+
+// syncTeams adds the user to the teams mapped to their LDAP groups and removes
+// the external memberships that are no longer mapped. Memberships added
+// manually are left untouched.
+func (s *Service) syncTeams(ctx context.Context, usr *user.UserSearchHitDTO, info *login.ExternalUserInfo, orgRoles map[int64]org.RoleType) ([]Change, error) {
+	orgIDs := make([]int64, 0, len(orgRoles))
+	for orgID := range orgRoles {
+		orgIDs = append(orgIDs, orgID)
+	}
+	sort.Slice(orgIDs, func(i, j int) bool { return orgIDs[i] < orgIDs[j] })
+
+	mappings, err := s.ldapGroups.GetTeams(info.Groups, orgIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := map[int64]*team.TeamDTO{}
+	for _, mapping := range mappings {
+		t, err := s.findTeam(ctx, mapping, orgRoles)
+		if err != nil {
+			return nil, err
+		}
+		if t != nil {
+			wanted[t.ID] = t
+		}
+	}
+
+	changes := []Change{}
+	for _, orgID := range orgIDs {
+		memberships, err := s.teamService.GetUserTeamMemberships(ctx, orgID, usr.ID, true)
+		if err != nil {
+			return changes, err
+		}
+
+		for _, m := range memberships {
+			if _, ok := wanted[m.TeamID]; ok {
+				delete(wanted, m.TeamID)
+				continue
+			}
+
+			if err := s.setTeamPermission(ctx, orgID, usr.ID, m.TeamID, ""); err != nil {
+				return changes, err
+			}
+			changes = append(changes, Change{Type: ChangeTeamRemoved, UserID: usr.ID, Login: usr.Login, OrgID: orgID, TeamID: m.TeamID, TeamUID: m.TeamUID})
+		}
+	}
+
+	teams := make([]*team.TeamDTO, 0, len(wanted))
+	for _, t := range wanted {
+		teams = append(teams, t)
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].ID < teams[j].ID })
+
+	for _, t := range teams {
+		isMember, err := s.teamService.IsTeamMember(ctx, t.OrgID, t.ID, usr.ID)
+		if err != nil {
+			return changes, err
+		}
+		if isMember {
+			continue
+		}
+
+		if err := s.setTeamPermission(ctx, t.OrgID, usr.ID, t.ID, team.PermissionTypeMember.String()); err != nil {
+			return changes, err
+		}
+		changes = append(changes, Change{Type: ChangeTeamAdded, UserID: usr.ID, Login: usr.Login, OrgID: t.OrgID, TeamID: t.ID, TeamUID: t.UID, TeamName: t.Name})
+	}
+
+	return changes, nil
+}
+
+// findTeam resolves a team mapping, it returns nil if the team doesn't exist
+// or belongs to an organization the user isn't a member of
+func (s *Service) findTeam(ctx context.Context, mapping ldap.TeamOrgGroupDTO, orgRoles map[int64]org.RoleType) (*team.TeamDTO, error) {
+	o, err := s.orgService.GetByName(ctx, &org.GetOrgByNameQuery{Name: mapping.OrgName})
+	if errors.Is(err, org.ErrOrgNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := orgRoles[o.ID]; !ok {
+		return nil, nil
+	}
+
+	result, err := s.teamService.SearchTeams(ctx, &team.SearchTeamsQuery{
+		OrgID: o.ID,
+		Name:  mapping.TeamName,
+		Limit: 1,
+		SignedInUser: &user.SignedInUser{
+			Login:       "ldap-sync",
+			OrgID:       o.ID,
+			Permissions: map[int64]map[string][]string{o.ID: {ac.ActionTeamsRead: {ac.ScopeTeamsAll}}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Teams) == 0 {
+		s.log.Debug("Team mapped to LDAP group not found", "team", mapping.TeamName, "org", mapping.OrgName)
+		return nil, nil
+	}
+
+	return result.Teams[0], nil
+}
+
+func (s *Service) setTeamPermission(ctx context.Context, orgID, userID, teamID int64, permission string) error {
+	_, err := s.teamPermissionsService.SetUserPermission(ctx, orgID, ac.User{ID: userID, IsExternal: true}, strconv.FormatInt(teamID, 10), permission)
+	return err
+}
+
+func (s *Service) userOrgRoles(ctx context.Context, userID int64) (map[int64]org.RoleType, error) {
+	orgs, err := s.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make(map[int64]org.RoleType, len(orgs))
+	for _, o := range orgs {
+		roles[o.OrgID] = o.Role
+	}
+	return roles, nil
+}
+
+func (s *Service) identityFromLDAPInfo(info *login.ExternalUserInfo) *authn.Identity {
+	return &authn.Identity{
+		OrgRoles:        info.OrgRoles,
+		Login:           info.Login,
+		Name:            info.Name,
+		Email:           info.Email,
+		IsGrafanaAdmin:  info.IsGrafanaAdmin,
+		AuthenticatedBy: info.AuthModule,
+		AuthID:          info.AuthId,
+		Groups:          info.Groups,
+		ClientParams: authn.ClientParams{
+			SyncUser:     true,
+			EnableUser:   true,
+			SyncOrgRoles: !s.cfg.SkipOrgRoleSync,
+			LookUpParams: login.UserLookupParams{
+				Login: &info.Login,
+				Email: &info.Email,
+			},
+		},
+	}
+}
+
+// diffOrgRoles returns the org role changes between two snapshots of the user's memberships
+func diffOrgRoles(usr *user.UserSearchHitDTO, before, after map[int64]org.RoleType) []Change {
+	changes := []Change{}
+	for orgID, newRole := range after {
+		oldRole, ok := before[orgID]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Type: ChangeOrgRoleAdded, UserID: usr.ID, Login: usr.Login, OrgID: orgID, NewRole: newRole})
+		case oldRole != newRole:
+			changes = append(changes, Change{Type: ChangeOrgRoleUpdated, UserID: usr.ID, Login: usr.Login, OrgID: orgID, OldRole: oldRole, NewRole: newRole})
+		}
+	}
+
+	for orgID, oldRole := range before {
+		if _, ok := after[orgID]; !ok {
+			changes = append(changes, Change{Type: ChangeOrgRoleRemoved, UserID: usr.ID, Login: usr.Login, OrgID: orgID, OldRole: oldRole})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].OrgID < changes[j].OrgID })
+	return changes
+}
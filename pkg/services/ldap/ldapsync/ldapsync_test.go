@@ -0,0 +1,216 @@
+package ldapsync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth/authtest"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/authn/authntest"
+	"github.com/grafana/grafana/pkg/services/ldap"
+	"github.com/grafana/grafana/pkg/services/ldap/multildap"
+	"github.com/grafana/grafana/pkg/services/ldap/service"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamtest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+)
+
+func TestService_SyncAll(t *testing.T) {
+	ldapUser := &user.UserSearchHitDTO{ID: 2, Login: "ldap-user"}
+
+	t.Run("should disable users removed from LDAP and revoke their sessions", func(t *testing.T) {
+		env := setupTestEnv(t, ldapUser)
+		env.ldap.ExpectedError = multildap.ErrDidNotFindUser
+
+		var revoked int64
+		env.sessions.RevokeAllUserTokensProvider = func(_ context.Context, userID int64) error {
+			revoked = userID
+			return nil
+		}
+
+		report, err := env.service.SyncAll(context.Background(), TriggerManual)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, report.UsersSynced)
+		assert.Equal(t, []Change{{Type: ChangeUserDisabled, UserID: 2, Login: "ldap-user"}}, report.Changes)
+		require.NotNil(t, env.users.updated)
+		assert.True(t, *env.users.updated.IsDisabled)
+		assert.Equal(t, int64(2), revoked)
+	})
+
+	t.Run("should refuse to disable the Grafana admin", func(t *testing.T) {
+		env := setupTestEnv(t, &user.UserSearchHitDTO{ID: 1, Login: "admin"})
+		env.ldap.ExpectedError = multildap.ErrDidNotFindUser
+
+		report, err := env.service.SyncAll(context.Background(), TriggerManual)
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, report.UsersSynced)
+		assert.Empty(t, report.Changes)
+		require.Len(t, report.Failures, 1)
+		assert.Equal(t, "admin", report.Failures[0].Login)
+		assert.Nil(t, env.users.updated)
+	})
+
+	t.Run("should report the org roles and team memberships changed by the sync", func(t *testing.T) {
+		env := setupTestEnv(t, ldapUser)
+		env.ldap.ExpectedUser = &login.ExternalUserInfo{
+			AuthModule: login.LDAPAuthModule,
+			Login:      "ldap-user",
+			OrgRoles:   map[int64]org.RoleType{1: org.RoleEditor},
+		}
+		env.orgs.ExpectedUserOrgDTO = []*org.UserOrgDTO{
+			{OrgID: 1, Role: org.RoleViewer},
+			{OrgID: 2, Role: org.RoleAdmin},
+		}
+		env.teams.ExpectedMembers = []*team.TeamMemberDTO{{OrgID: 1, TeamID: 10, TeamUID: "team-10", External: true}}
+
+		var synced *authn.Identity
+		env.synchronizer.SyncIdentityFunc = func(_ context.Context, id *authn.Identity) error {
+			synced = id
+			env.orgs.ExpectedUserOrgDTO = []*org.UserOrgDTO{{OrgID: 1, Role: org.RoleEditor}}
+			return nil
+		}
+
+		report, err := env.service.SyncAll(context.Background(), TriggerSchedule)
+		require.NoError(t, err)
+
+		require.NotNil(t, synced)
+		assert.True(t, synced.ClientParams.SyncOrgRoles)
+		assert.False(t, synced.ClientParams.AllowSignUp)
+
+		assert.Equal(t, []Change{
+			{Type: ChangeOrgRoleUpdated, UserID: 2, Login: "ldap-user", OrgID: 1, OldRole: org.RoleViewer, NewRole: org.RoleEditor},
+			{Type: ChangeOrgRoleRemoved, UserID: 2, Login: "ldap-user", OrgID: 2, OldRole: org.RoleAdmin},
+			{Type: ChangeTeamRemoved, UserID: 2, Login: "ldap-user", OrgID: 1, TeamID: 10, TeamUID: "team-10"},
+		}, report.Changes)
+		assert.Equal(t, []ac.User{{ID: 2, IsExternal: true}}, env.teamPermissions.users)
+
+		reports, err := env.service.reports.list(context.Background())
+		require.NoError(t, err)
+		require.Len(t, reports, 1)
+		assert.Equal(t, report.UID, reports[0].UID)
+		assert.Equal(t, TriggerSchedule, reports[0].Trigger)
+	})
+
+	t.Run("should not run two synchronizations at once", func(t *testing.T) {
+		env := setupTestEnv(t, ldapUser)
+		env.service.syncMu.Lock()
+		defer env.service.syncMu.Unlock()
+
+		_, err := env.service.SyncAll(context.Background(), TriggerManual)
+		assert.ErrorIs(t, err, ErrSyncInProgress)
+	})
+}
+
+func TestDiffOrgRoles(t *testing.T) {
+	usr := &user.UserSearchHitDTO{ID: 2, Login: "ldap-user"}
+
+	assert.Empty(t, diffOrgRoles(usr, map[int64]org.RoleType{1: org.RoleViewer}, map[int64]org.RoleType{1: org.RoleViewer}))
+	assert.Equal(t, []Change{
+		{Type: ChangeOrgRoleRemoved, UserID: 2, Login: "ldap-user", OrgID: 1, OldRole: org.RoleViewer},
+		{Type: ChangeOrgRoleUpdated, UserID: 2, Login: "ldap-user", OrgID: 2, OldRole: org.RoleEditor, NewRole: org.RoleAdmin},
+		{Type: ChangeOrgRoleAdded, UserID: 2, Login: "ldap-user", OrgID: 3, NewRole: org.RoleViewer},
+	}, diffOrgRoles(usr,
+		map[int64]org.RoleType{1: org.RoleViewer, 2: org.RoleEditor},
+		map[int64]org.RoleType{2: org.RoleAdmin, 3: org.RoleViewer},
+	))
+}
+
+func TestReportStore(t *testing.T) {
+	store := newReportStore(kvstore.NewFakeKVStore())
+	ctx := context.Background()
+
+	reports, err := store.list(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, reports)
+
+	for i := 0; i < maxReports+5; i++ {
+		require.NoError(t, store.add(ctx, &Report{UID: string(rune('a' + i))}))
+	}
+
+	reports, err = store.list(ctx)
+	require.NoError(t, err)
+	require.Len(t, reports, maxReports)
+	assert.Equal(t, string(rune('a'+maxReports+4)), reports[0].UID)
+
+	report, err := store.get(ctx, reports[3].UID)
+	require.NoError(t, err)
+	assert.Equal(t, reports[3].UID, report.UID)
+
+	_, err = store.get(ctx, "a")
+	assert.ErrorIs(t, err, ErrReportNotFound)
+}
+
+type testEnv struct {
+	service         *Service
+	ldap            *service.LDAPFakeService
+	users           *fakeUserService
+	orgs            *orgtest.FakeOrgService
+	teams           *teamtest.FakeService
+	teamPermissions *fakeTeamPermissionsService
+	sessions        *authtest.FakeUserAuthTokenService
+	synchronizer    *authntest.MockService
+}
+
+func setupTestEnv(t *testing.T, users ...*user.UserSearchHitDTO) *testEnv {
+	t.Helper()
+
+	env := &testEnv{
+		ldap:            service.NewLDAPFakeService(),
+		users:           &fakeUserService{FakeUserService: usertest.NewUserServiceFake()},
+		orgs:            orgtest.NewOrgServiceFake(),
+		teams:           teamtest.NewFakeService(),
+		teamPermissions: &fakeTeamPermissionsService{},
+		sessions:        authtest.NewFakeUserAuthTokenService(),
+		synchronizer:    &authntest.MockService{},
+	}
+	env.users.ExpectedSearchUsers = user.SearchUserQueryResult{Users: users}
+
+	env.service = &Service{
+		cfg:                    &ldap.Config{Enabled: true, ActiveSyncEnabled: true, SyncCron: "0 1 * * *"},
+		adminUser:              "admin",
+		ldapService:            env.ldap,
+		ldapGroups:             ldap.ProvideGroupsService(),
+		identitySynchronizer:   env.synchronizer,
+		userService:            env.users,
+		orgService:             env.orgs,
+		teamService:            env.teams,
+		teamPermissionsService: env.teamPermissions,
+		sessionService:         env.sessions,
+		reports:                newReportStore(kvstore.NewFakeKVStore()),
+		log:                    log.NewNopLogger(),
+	}
+
+	return env
+}
+
+type fakeUserService struct {
+	*usertest.FakeUserService
+	updated *user.UpdateUserCommand
+}
+
+func (f *fakeUserService) Update(_ context.Context, cmd *user.UpdateUserCommand) error {
+	f.updated = cmd
+	return nil
+}
+
+type fakeTeamPermissionsService struct {
+	ac.TeamPermissionsService
+	users []ac.User
+}
+
+func (f *fakeTeamPermissionsService) SetUserPermission(_ context.Context, _ int64, user ac.User, _, _ string) (*ac.ResourcePermission, error) {
+	f.users = append(f.users, user)
+	return &ac.ResourcePermission{}, nil
+}
@@ -0,0 +1,120 @@
+package ldapsync
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+const (
+	kvNamespace = "ldap.sync"
+	kvKey       = "reports"
+	// maxReports is the number of reports kept, older reports are discarded
+	maxReports = 20
+)
+
+type ChangeType string
+
+const (
+	ChangeOrgRoleAdded   ChangeType = "org_role_added"
+	ChangeOrgRoleUpdated ChangeType = "org_role_updated"
+	ChangeOrgRoleRemoved ChangeType = "org_role_removed"
+	ChangeTeamAdded      ChangeType = "team_membership_added"
+	ChangeTeamRemoved    ChangeType = "team_membership_removed"
+	ChangeUserDisabled   ChangeType = "user_disabled"
+)
+
+type Trigger string
+
+const (
+	TriggerSchedule Trigger = "schedule"
+	TriggerManual   Trigger = "manual"
+)
+
+// Report lists the changes applied by one synchronization of all LDAP users
+type Report struct {
+	UID         string        `json:"uid"`
+	Trigger     Trigger       `json:"trigger"`
+	StartedAt   time.Time     `json:"startedAt"`
+	FinishedAt  time.Time     `json:"finishedAt"`
+	UsersSynced int           `json:"usersSynced"`
+	Changes     []Change      `json:"changes"`
+	Failures    []UserFailure `json:"failures"`
+}
+
+// Change is a single difference between Grafana and LDAP that has been corrected
+type Change struct {
+	Type     ChangeType   `json:"type"`
+	UserID   int64        `json:"userId"`
+	Login    string       `json:"login"`
+	OrgID    int64        `json:"orgId,omitempty"`
+	OldRole  org.RoleType `json:"oldRole,omitempty"`
+	NewRole  org.RoleType `json:"newRole,omitempty"`
+	TeamID   int64        `json:"teamId,omitempty"`
+	TeamUID  string       `json:"teamUid,omitempty"`
+	TeamName string       `json:"teamName,omitempty"`
+}
+
+// UserFailure is a user that could not be synchronized
+type UserFailure struct {
+	UserID int64  `json:"userId"`
+	Login  string `json:"login"`
+	Error  string `json:"error"`
+}
+
+type reportStore struct {
+	kv *kvstore.NamespacedKVStore
+}
+
+func newReportStore(kv kvstore.KVStore) *reportStore {
+	return &reportStore{kv: kvstore.WithNamespace(kv, 0, kvNamespace)}
+}
+
+// list returns the stored reports, most recent first
+func (s *reportStore) list(ctx context.Context) ([]*Report, error) {
+	value, ok, err := s.kv.Get(ctx, kvKey)
+	if err != nil || !ok {
+		return []*Report{}, err
+	}
+
+	reports := []*Report{}
+	if err := json.Unmarshal([]byte(value), &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+func (s *reportStore) get(ctx context.Context, uid string) (*Report, error) {
+	reports, err := s.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range reports {
+		if r.UID == uid {
+			return r, nil
+		}
+	}
+	return nil, ErrReportNotFound
+}
+
+func (s *reportStore) add(ctx context.Context, report *Report) error {
+	reports, err := s.list(ctx)
+	if err != nil {
+		return err
+	}
+
+	reports = append([]*Report{report}, reports...)
+	if len(reports) > maxReports {
+		reports = reports[:maxReports]
+	}
+
+	value, err := json.Marshal(reports)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, kvKey, string(value))
+}
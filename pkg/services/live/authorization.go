@@ -0,0 +1,140 @@
+package live
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/live/model"
+)
+
+const (
+	ActionChannelsSubscribe = "live.channels:subscribe"
+	ActionChannelsPublish   = "live.channels:publish"
+)
+
+var (
+	ScopeChannelsAll = accesscontrol.Scope("live", "channels", "*")
+)
+
+// ScopeChannel returns the scope of a channel (without the org ID), e.g. live:channels:ds/<uid>/<path>.
+// Scopes of a channel scope or namespace can be granted with wildcards like live:channels:ds/<uid>/*.
+func ScopeChannel(channel string) string {
+	return accesscontrol.Scope("live", "channels", channel)
+}
+
+const (
+	authorizationPolicyRBAC         = "rbac"
+	authorizationPolicyPluginPrefix = "plugin:"
+)
+
+// RegisterChannelAuthorizer sets the authorizer checking the channels of a scope,
+// replacing the one configured in the [live.authorization] section.
+func (g *GrafanaLive) RegisterChannelAuthorizer(scope string, authorizer model.ChannelAuthorizer) {
+	g.channelAuthorizersMu.Lock()
+	defer g.channelAuthorizersMu.Unlock()
+	g.channelAuthorizers[scope] = authorizer
+}
+
+func (g *GrafanaLive) registerConfiguredChannelAuthorizers() {
+	for scope, policy := range g.Cfg.LiveAuthorization {
+		switch {
+		case policy == authorizationPolicyRBAC:
+			g.RegisterChannelAuthorizer(scope, &rbacChannelAuthorizer{accessControl: g.accessControl})
+		case strings.HasPrefix(policy, authorizationPolicyPluginPrefix):
+			g.RegisterChannelAuthorizer(scope, &pluginChannelAuthorizer{
+				pluginID: strings.TrimPrefix(policy, authorizationPolicyPluginPrefix),
+				live:     g,
+			})
+		}
+	}
+}
+
+// authorizeChannel checks the channel (without the org ID) with the authorizer of its scope.
+// Channels of scopes without authorizer, and invalid channels which are rejected later, are allowed.
+func (g *GrafanaLive) authorizeChannel(ctx context.Context, user identity.Requester, channel string, publish bool) (bool, error) {
+	addr, err := live.ParseChannel(channel)
+	if err != nil {
+		return true, nil
+	}
+
+	g.channelAuthorizersMu.RLock()
+	authorizer, ok := g.channelAuthorizers[addr.Scope]
+	g.channelAuthorizersMu.RUnlock()
+	if !ok {
+		return true, nil
+	}
+
+	if publish {
+		return authorizer.CanPublish(ctx, user, addr)
+	}
+	return authorizer.CanSubscribe(ctx, user, addr)
+}
+
+// rbacChannelAuthorizer requires the live.channels RBAC actions on the channel.
+type rbacChannelAuthorizer struct {
+	accessControl accesscontrol.AccessControl
+}
+
+func (a *rbacChannelAuthorizer) CanSubscribe(ctx context.Context, user identity.Requester, channel live.Channel) (bool, error) {
+	return a.accessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(ActionChannelsSubscribe, ScopeChannel(channel.String())))
+}
+
+func (a *rbacChannelAuthorizer) CanPublish(ctx context.Context, user identity.Requester, channel live.Channel) (bool, error) {
+	return a.accessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(ActionChannelsPublish, ScopeChannel(channel.String())))
+}
+
+// pluginChannelAuthorizer asks a plugin with its SubscribeStream and PublishStream
+// handlers, called with the channel as path. Calls go through the plugin client
+// middlewares like any other stream call.
+type pluginChannelAuthorizer struct {
+	pluginID string
+	live     *GrafanaLive
+}
+
+func (a *pluginChannelAuthorizer) CanSubscribe(ctx context.Context, user identity.Requester, channel live.Channel) (bool, error) {
+	handler, pCtx, err := a.pluginHandler(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	resp, err := handler.SubscribeStream(ctx, &backend.SubscribeStreamRequest{
+		PluginContext: pCtx,
+		Path:          channel.String(),
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp.Status == backend.SubscribeStreamStatusOK, nil
+}
+
+func (a *pluginChannelAuthorizer) CanPublish(ctx context.Context, user identity.Requester, channel live.Channel) (bool, error) {
+	handler, pCtx, err := a.pluginHandler(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	resp, err := handler.PublishStream(ctx, &backend.PublishStreamRequest{
+		PluginContext: pCtx,
+		Path:          channel.String(),
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp.Status == backend.PublishStreamStatusOK, nil
+}
+
+func (a *pluginChannelAuthorizer) pluginHandler(ctx context.Context, user identity.Requester) (backend.StreamHandler, backend.PluginContext, error) {
+	handler, err := a.live.getStreamPlugin(ctx, a.pluginID)
+	if err != nil {
+		return nil, backend.PluginContext{}, fmt.Errorf("error getting channel authorizer plugin: %w", err)
+	}
+	pCtx, err := a.live.contextGetter.GetPluginContext(ctx, user, a.pluginID, "", false)
+	if err != nil {
+		return nil, backend.PluginContext{}, fmt.Errorf("error getting channel authorizer plugin context: %w", err)
+	}
+	return handler, pCtx, nil
+}
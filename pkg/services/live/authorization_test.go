@@ -0,0 +1,72 @@
+package live
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/live/model"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeChannelAuthorizer struct {
+	subscribe, publish bool
+}
+
+func (a *fakeChannelAuthorizer) CanSubscribe(_ context.Context, _ identity.Requester, _ live.Channel) (bool, error) {
+	return a.subscribe, nil
+}
+
+func (a *fakeChannelAuthorizer) CanPublish(_ context.Context, _ identity.Requester, _ live.Channel) (bool, error) {
+	return a.publish, nil
+}
+
+func TestGrafanaLive_authorizeChannel(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.LiveAuthorization = map[string]string{live.ScopeDatasource: authorizationPolicyRBAC}
+	g := &GrafanaLive{
+		Cfg:                cfg,
+		channelAuthorizers: map[string]model.ChannelAuthorizer{},
+		accessControl:      acimpl.ProvideAccessControl(featuremgmt.WithFeatures()),
+	}
+	g.registerConfiguredChannelAuthorizers()
+	g.RegisterChannelAuthorizer(live.ScopeStream, &fakeChannelAuthorizer{subscribe: true})
+
+	user := &identity.StaticRequester{
+		OrgID: 1,
+		Permissions: map[int64]map[string][]string{
+			1: {
+				ActionChannelsSubscribe: {ScopeChannel("ds/allowed/*")},
+				ActionChannelsPublish:   {ScopeChannel("ds/allowed/path")},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		channel  string
+		publish  bool
+		expected bool
+	}{
+		{name: "rbac subscribe allowed by wildcard", channel: "ds/allowed/other", expected: true},
+		{name: "rbac subscribe denied", channel: "ds/denied/path", expected: false},
+		{name: "rbac publish allowed", channel: "ds/allowed/path", publish: true, expected: true},
+		{name: "rbac publish denied", channel: "ds/allowed/other", publish: true, expected: false},
+		{name: "registered authorizer allows subscribe", channel: "stream/telegraf/cpu", expected: true},
+		{name: "registered authorizer denies publish", channel: "stream/telegraf/cpu", publish: true, expected: false},
+		{name: "scope without authorizer", channel: "grafana/dashboard/uid/abc", publish: true, expected: true},
+		{name: "invalid channel is left to the handlers", channel: "invalid", expected: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			allowed, err := g.authorizeChannel(context.Background(), user, tc.channel, tc.publish)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, allowed)
+		})
+	}
+}
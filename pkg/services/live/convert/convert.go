@@ -5,12 +5,30 @@ import (
 	"fmt"
 
 	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/otlp"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/prometheus"
 	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
 )
 
+// Input formats accepted by the Converter.
+const (
+	InputFormatInflux                = "influx"
+	InputFormatOTLP                  = "otlp"
+	InputFormatOTLPJSON              = "otlp_json"
+	InputFormatPrometheusRemoteWrite = "prometheus_remote_write"
+)
+
 type Converter struct {
 	telegrafConverterWide         *telegraf.Converter
 	telegrafConverterLabelsColumn *telegraf.Converter
+
+	otlpConverterWide             *otlp.Converter
+	otlpConverterLabelsColumn     *otlp.Converter
+	otlpJSONConverterWide         *otlp.Converter
+	otlpJSONConverterLabelsColumn *otlp.Converter
+
+	prometheusConverterWide         *prometheus.Converter
+	prometheusConverterLabelsColumn *prometheus.Converter
 }
 
 func NewConverter() *Converter {
@@ -22,18 +40,56 @@ func NewConverter() *Converter {
 			telegraf.WithUseLabelsColumn(true),
 			telegraf.WithFloat64Numbers(true),
 		),
+		otlpConverterWide: otlp.NewConverter(),
+		otlpConverterLabelsColumn: otlp.NewConverter(
+			otlp.WithUseLabelsColumn(true),
+		),
+		otlpJSONConverterWide: otlp.NewConverter(
+			otlp.WithJSONEncoding(true),
+		),
+		otlpJSONConverterLabelsColumn: otlp.NewConverter(
+			otlp.WithJSONEncoding(true),
+			otlp.WithUseLabelsColumn(true),
+		),
+		prometheusConverterWide: prometheus.NewConverter(),
+		prometheusConverterLabelsColumn: prometheus.NewConverter(
+			prometheus.WithUseLabelsColumn(true),
+		),
 	}
 }
 
-var ErrUnsupportedFrameFormat = errors.New("unsupported frame format")
+var (
+	ErrUnsupportedFrameFormat = errors.New("unsupported frame format")
+	ErrUnsupportedInputFormat = errors.New("unsupported input format")
+)
 
+// Convert converts Influx line protocol data to frames.
 func (c *Converter) Convert(data []byte, frameFormat string) ([]telemetry.FrameWrapper, error) {
+	return c.ConvertInput(data, InputFormatInflux, frameFormat)
+}
+
+// ConvertInput converts data in one of the input formats to frames.
+func (c *Converter) ConvertInput(data []byte, inputFormat string, frameFormat string) ([]telemetry.FrameWrapper, error) {
+	var wide, labelsColumn telemetry.Converter
+	switch inputFormat {
+	case InputFormatInflux:
+		wide, labelsColumn = c.telegrafConverterWide, c.telegrafConverterLabelsColumn
+	case InputFormatOTLP:
+		wide, labelsColumn = c.otlpConverterWide, c.otlpConverterLabelsColumn
+	case InputFormatOTLPJSON:
+		wide, labelsColumn = c.otlpJSONConverterWide, c.otlpJSONConverterLabelsColumn
+	case InputFormatPrometheusRemoteWrite:
+		wide, labelsColumn = c.prometheusConverterWide, c.prometheusConverterLabelsColumn
+	default:
+		return nil, ErrUnsupportedInputFormat
+	}
+
 	var converter telemetry.Converter
 	switch frameFormat {
 	case "wide":
-		converter = c.telegrafConverterWide
+		converter = wide
 	case "labels_column":
-		converter = c.telegrafConverterLabelsColumn
+		converter = labelsColumn
 	default:
 		return nil, ErrUnsupportedFrameFormat
 	}
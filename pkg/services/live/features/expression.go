@@ -0,0 +1,262 @@
+package features
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/live/model"
+)
+
+const defaultExpressionStreamWindow = 5 * time.Minute
+
+// ExpressionStreamBuilder builds the pipelines evaluating expressions on the frames of a stream.
+type ExpressionStreamBuilder interface {
+	NewExpressionStream(ctx context.Context, user identity.Requester, reqDTO dtos.MetricRequest, streamRefID string, window time.Duration) (*expr.StreamPipeline, error)
+}
+
+// StreamHandlerGetter returns the stream handler of a plugin.
+type StreamHandlerGetter func(ctx context.Context, pluginID string) (backend.StreamHandler, error)
+
+// ExpressionStreamRequest is the data sent when subscribing to a `grafana/expr/*` channel.
+type ExpressionStreamRequest struct {
+	dtos.MetricRequest
+	// Stream is the stream of the datasource query evaluated continuously
+	Stream struct {
+		RefID string          `json:"refId"`
+		Path  string          `json:"path"`
+		Data  json.RawMessage `json:"data,omitempty"`
+	} `json:"stream"`
+	// Window is the duration of the frames of the stream kept for the evaluation, 5m by default
+	Window string `json:"window,omitempty"`
+}
+
+// ExpressionRunner evaluates expressions every time the stream of a datasource query sends frames,
+// and publishes the results to `grafana/expr/*` channels.
+type ExpressionRunner struct {
+	publisher           model.ChannelPublisher
+	clientCount         model.ChannelClientCount
+	streamBuilder       ExpressionStreamBuilder
+	streamHandlerGetter StreamHandlerGetter
+	pluginContextGetter PluginContextGetter
+
+	runningMu sync.Mutex
+	running   map[string]*expressionStream
+}
+
+func NewExpressionRunner(publisher model.ChannelPublisher, clientCount model.ChannelClientCount, streamBuilder ExpressionStreamBuilder,
+	streamHandlerGetter StreamHandlerGetter, pluginContextGetter PluginContextGetter) *ExpressionRunner {
+	return &ExpressionRunner{
+		publisher:           publisher,
+		clientCount:         clientCount,
+		streamBuilder:       streamBuilder,
+		streamHandlerGetter: streamHandlerGetter,
+		pluginContextGetter: pluginContextGetter,
+		running:             make(map[string]*expressionStream),
+	}
+}
+
+func (r *ExpressionRunner) GetHandlerForPath(_ string) (model.ChannelHandler, error) {
+	return r, nil // all the pipelines share the same handler
+}
+
+// Valid paths look like: {name}/{user.uid}, the pipelines are not shared across users
+// * cpu-threshold/u12345
+func (r *ExpressionRunner) OnSubscribe(ctx context.Context, u identity.Requester, e model.SubscribeEvent) (model.SubscribeReply, backend.SubscribeStreamStatus, error) {
+	userID := u.GetIdentifier()
+	if userID == "" {
+		return model.SubscribeReply{}, backend.SubscribeStreamStatusPermissionDenied, fmt.Errorf("missing user identity")
+	}
+	parts := strings.Split(e.Path, "/")
+	if len(parts) != 2 || parts[1] != userID {
+		return model.SubscribeReply{}, backend.SubscribeStreamStatusPermissionDenied, fmt.Errorf("path must be {name}/%s", userID)
+	}
+
+	r.runningMu.Lock()
+	defer r.runningMu.Unlock()
+
+	if current, ok := r.running[e.Channel]; ok && !current.isDone() {
+		return model.SubscribeReply{Presence: true}, backend.SubscribeStreamStatusOK, nil
+	}
+
+	var req ExpressionStreamRequest
+	if err := json.Unmarshal(e.Data, &req); err != nil {
+		return model.SubscribeReply{}, backend.SubscribeStreamStatusNotFound, fmt.Errorf("invalid expression stream request: %w", err)
+	}
+	if req.Stream.RefID == "" || req.Stream.Path == "" {
+		return model.SubscribeReply{}, backend.SubscribeStreamStatusNotFound, fmt.Errorf("the stream refId and path are required")
+	}
+	window := defaultExpressionStreamWindow
+	if req.Window != "" {
+		var err error
+		window, err = gtime.ParseDuration(req.Window)
+		if err != nil {
+			return model.SubscribeReply{}, backend.SubscribeStreamStatusNotFound, fmt.Errorf("invalid window %q: %w", req.Window, err)
+		}
+	}
+
+	pipeline, err := r.streamBuilder.NewExpressionStream(ctx, u, req.MetricRequest, req.Stream.RefID, window)
+	if err != nil {
+		return model.SubscribeReply{}, backend.SubscribeStreamStatusNotFound, err
+	}
+
+	ds := pipeline.Datasource()
+	handler, err := r.streamHandlerGetter(ctx, ds.Type)
+	if err != nil {
+		return model.SubscribeReply{}, backend.SubscribeStreamStatusNotFound, err
+	}
+	pCtx, err := r.pluginContextGetter.GetPluginContext(ctx, u, ds.Type, ds.UID, false)
+	if err != nil {
+		return model.SubscribeReply{}, 0, err
+	}
+	resp, err := handler.SubscribeStream(ctx, &backend.SubscribeStreamRequest{
+		PluginContext: pCtx,
+		Path:          req.Stream.Path,
+		Data:          req.Stream.Data,
+	})
+	if err != nil {
+		return model.SubscribeReply{}, 0, err
+	}
+	if resp.Status != backend.SubscribeStreamStatusOK {
+		return model.SubscribeReply{}, resp.Status, nil
+	}
+
+	streamCtx, cancel := context.WithCancel(context.Background())
+	current := &expressionStream{
+		orgID:       u.GetOrgID(),
+		channel:     e.Channel,
+		publisher:   r.publisher,
+		clientCount: r.clientCount,
+		pipeline:    pipeline,
+		cancel:      cancel,
+	}
+	r.running[e.Channel] = current
+	go current.run(streamCtx, handler, &backend.RunStreamRequest{
+		PluginContext: pCtx,
+		Path:          req.Stream.Path,
+		Data:          req.Stream.Data,
+	})
+
+	return model.SubscribeReply{Presence: true}, backend.SubscribeStreamStatusOK, nil
+}
+
+// OnPublish is called when a client wants to broadcast on the websocket
+func (r *ExpressionRunner) OnPublish(_ context.Context, _ identity.Requester, _ model.PublishEvent) (model.PublishReply, backend.PublishStreamStatus, error) {
+	return model.PublishReply{}, backend.PublishStreamStatusPermissionDenied, fmt.Errorf("expression streams do not support publish")
+}
+
+var errNoSubscribers = errors.New("no subscribers")
+
+// expressionStream runs the stream of a pipeline, and publishes the results of the pipeline
+// every time the stream sends a frame. It stops when the channel has no subscribers.
+type expressionStream struct {
+	orgID       int64
+	channel     string
+	publisher   model.ChannelPublisher
+	clientCount model.ChannelClientCount
+	pipeline    *expr.StreamPipeline
+	cancel      context.CancelFunc
+
+	mu     sync.Mutex
+	done   bool
+	schema json.RawMessage
+}
+
+func (s *expressionStream) isDone() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done
+}
+
+func (s *expressionStream) run(ctx context.Context, handler backend.StreamHandler, req *backend.RunStreamRequest) {
+	defer func() {
+		s.mu.Lock()
+		s.done = true
+		s.mu.Unlock()
+		s.cancel()
+	}()
+
+	err := handler.RunStream(ctx, req, backend.NewStreamSender(&expressionPacketSender{ctx: ctx, stream: s}))
+	if err != nil && !errors.Is(err, errNoSubscribers) && !errors.Is(ctx.Err(), context.Canceled) {
+		logger.Error("Expression stream error", "channel", s.channel, "path", req.Path, "error", err)
+		return
+	}
+	logger.Debug("Expression stream finished", "channel", s.channel, "path", req.Path)
+}
+
+// update evaluates the pipeline on a frame sent by the stream, and publishes the results.
+func (s *expressionStream) update(ctx context.Context, packet *backend.StreamPacket) error {
+	frame, err := s.decodeFrame(packet.Data)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.pipeline.Update(ctx, time.Now(), frame)
+	if err != nil {
+		return err
+	}
+	msg, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	if err := s.publisher(s.orgID, s.channel, msg); err != nil {
+		return err
+	}
+
+	count, err := s.clientCount(s.orgID, s.channel)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errNoSubscribers
+	}
+	return nil
+}
+
+// decodeFrame decodes a frame sent by the stream. The frames without a schema have the schema of
+// the last frame with one.
+func (s *expressionStream) decodeFrame(raw json.RawMessage) (*data.Frame, error) {
+	var msg struct {
+		Schema json.RawMessage `json:"schema,omitempty"`
+		Data   json.RawMessage `json:"data,omitempty"`
+	}
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return nil, fmt.Errorf("invalid stream frame: %w", err)
+	}
+	if len(msg.Schema) > 0 {
+		s.schema = msg.Schema
+	} else if s.schema == nil {
+		return nil, fmt.Errorf("received a stream frame without schema")
+	}
+	msg.Schema = s.schema
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	frame := &data.Frame{}
+	if err := json.Unmarshal(b, frame); err != nil {
+		return nil, fmt.Errorf("invalid stream frame: %w", err)
+	}
+	return frame, nil
+}
+
+type expressionPacketSender struct {
+	ctx    context.Context
+	stream *expressionStream
+}
+
+func (p *expressionPacketSender) Send(packet *backend.StreamPacket) error {
+	return p.stream.update(p.ctx, packet)
+}
@@ -0,0 +1,37 @@
+package features
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpressionStreamDecodeFrame(t *testing.T) {
+	frame := data.NewFrame("stream",
+		data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+		data.NewField("value", nil, []float64{1}),
+	)
+	full, err := data.FrameToJSON(frame, data.IncludeAll)
+	require.NoError(t, err)
+	frame.Fields[1].Set(0, 2.0)
+	dataOnly, err := data.FrameToJSON(frame, data.IncludeDataOnly)
+	require.NoError(t, err)
+
+	s := &expressionStream{}
+
+	_, err = s.decodeFrame(dataOnly)
+	require.Error(t, err, "a frame without schema should fail before the first schema")
+
+	decoded, err := s.decodeFrame(full)
+	require.NoError(t, err)
+	require.Equal(t, "stream", decoded.Name)
+	require.Equal(t, 1.0, decoded.Fields[1].At(0))
+
+	decoded, err = s.decodeFrame(dataOnly)
+	require.NoError(t, err)
+	require.Equal(t, "stream", decoded.Name)
+	require.Len(t, decoded.Fields, 2)
+	require.Equal(t, 2.0, decoded.Fields[1].At(0))
+}
@@ -0,0 +1,82 @@
+package history
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobwas/glob"
+)
+
+type rule struct {
+	pattern glob.Glob
+	opts    Options
+}
+
+// Policy decides which channels keep a history and with which limits.
+type Policy struct {
+	defaults Options
+	rules    []rule
+}
+
+// NewPolicy creates a policy from the default options and the channel rules.
+// A rule is a channel pattern (without the org ID) optionally followed by the
+// size and TTL of the history, e.g. "plugin/testdata/*", "stream/**:50" or
+// "plugin/*/random:20:1m". Patterns are globs where * matches a path segment
+// and ** any number of segments. Without rules all channels use the defaults.
+func NewPolicy(defaults Options, rules []string) (*Policy, error) {
+	p := &Policy{defaults: defaults}
+	for _, r := range rules {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		parsed, err := parseRule(r, defaults)
+		if err != nil {
+			return nil, err
+		}
+		p.rules = append(p.rules, parsed)
+	}
+	return p, nil
+}
+
+func parseRule(r string, defaults Options) (rule, error) {
+	parts := strings.Split(r, ":")
+	if len(parts) > 3 {
+		return rule{}, fmt.Errorf("invalid live history rule %q: expected pattern[:size[:ttl]]", r)
+	}
+
+	pattern, err := glob.Compile(parts[0], '/')
+	if err != nil {
+		return rule{}, fmt.Errorf("invalid live history rule %q: %w", r, err)
+	}
+	opts := defaults
+	if len(parts) > 1 {
+		opts.Size, err = strconv.Atoi(parts[1])
+		if err != nil || opts.Size < 0 {
+			return rule{}, fmt.Errorf("invalid live history rule %q: size must be a positive number", r)
+		}
+	}
+	if len(parts) > 2 {
+		opts.TTL, err = time.ParseDuration(parts[2])
+		if err != nil || opts.TTL <= 0 {
+			return rule{}, fmt.Errorf("invalid live history rule %q: ttl must be a positive duration", r)
+		}
+	}
+	return rule{pattern: pattern, opts: opts}, nil
+}
+
+// Options returns the history options of the channel (without the org ID)
+// and whether the channel keeps a history. The first matching rule wins.
+func (p *Policy) Options(channel string) (Options, bool) {
+	if len(p.rules) == 0 {
+		return p.defaults, p.defaults.Size > 0 && p.defaults.TTL > 0
+	}
+	for _, r := range p.rules {
+		if r.pattern.Match(channel) {
+			return r.opts, r.opts.Size > 0 && r.opts.TTL > 0
+		}
+	}
+	return Options{}, false
+}
@@ -0,0 +1,63 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy(t *testing.T) {
+	defaults := Options{Size: 10, TTL: time.Minute}
+
+	t.Run("all channels use the defaults without rules", func(t *testing.T) {
+		p, err := NewPolicy(defaults, nil)
+		require.NoError(t, err)
+
+		opts, ok := p.Options("plugin/testdata/random-2s-stream")
+		require.True(t, ok)
+		require.Equal(t, defaults, opts)
+	})
+
+	t.Run("disabled with zero size", func(t *testing.T) {
+		p, err := NewPolicy(Options{TTL: time.Minute}, nil)
+		require.NoError(t, err)
+
+		_, ok := p.Options("stream/telegraf/cpu")
+		require.False(t, ok)
+	})
+
+	t.Run("rules select channels and override options", func(t *testing.T) {
+		p, err := NewPolicy(defaults, []string{"plugin/testdata/*", " stream/**:50 ", "grafana/dashboard/*/*:0", "ds/*/*:5:30s"})
+		require.NoError(t, err)
+
+		opts, ok := p.Options("plugin/testdata/random-2s-stream")
+		require.True(t, ok)
+		require.Equal(t, defaults, opts)
+
+		// * does not match several path segments
+		_, ok = p.Options("plugin/testdata/random/stream")
+		require.False(t, ok)
+
+		opts, ok = p.Options("stream/telegraf/cpu")
+		require.True(t, ok)
+		require.Equal(t, Options{Size: 50, TTL: time.Minute}, opts)
+
+		opts, ok = p.Options("ds/abc/path")
+		require.True(t, ok)
+		require.Equal(t, Options{Size: 5, TTL: 30 * time.Second}, opts)
+
+		_, ok = p.Options("grafana/dashboard/uid/abc")
+		require.False(t, ok)
+
+		_, ok = p.Options("grafana/broadcast/test")
+		require.False(t, ok)
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		for _, r := range []string{"stream/*:abc", "stream/*:-1", "stream/*:10:abc", "stream/*:10:0s", "stream/*:1:1m:1"} {
+			_, err := NewPolicy(defaults, []string{r})
+			require.Error(t, err, r)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+)
+
+var logger = log.New("live.history")
+
+// Recorder records the publications of the channels selected by the policy.
+// A nil Recorder records nothing.
+//
+// Publications sent through the broker are kept in the shared storage, which
+// is Redis when using the Redis HA engine. Publications only sent to the
+// subscribers of the instance, like plugin streams which run on every
+// instance having subscribers, are always kept in memory. Without Redis the
+// local storage is used as the shared one.
+type Recorder struct {
+	policy *Policy
+	shared Storage
+	local  *MemoryStorage
+}
+
+func NewRecorder(policy *Policy, shared Storage, local *MemoryStorage) *Recorder {
+	return &Recorder{policy: policy, shared: shared, local: local}
+}
+
+// Add records a publication sent through the broker. The channel includes the org ID.
+func (r *Recorder) Add(ctx context.Context, channel string, data []byte) {
+	r.add(ctx, r.shared, channel, data)
+}
+
+// AddLocal records a publication only sent to the subscribers of this instance.
+func (r *Recorder) AddLocal(ctx context.Context, channel string, data []byte) {
+	r.add(ctx, r.local, channel, data)
+}
+
+func (r *Recorder) add(ctx context.Context, storage Storage, channel string, data []byte) {
+	if r == nil {
+		return
+	}
+	opts, ok := r.options(channel)
+	if !ok {
+		return
+	}
+	if err := storage.Add(ctx, channel, data, opts); err != nil {
+		logger.Warn("Failed to add publication to history", "channel", channel, "error", err)
+	}
+}
+
+// Get returns the recent publications of the channel, oldest first.
+func (r *Recorder) Get(ctx context.Context, channel string) ([]json.RawMessage, error) {
+	if r == nil {
+		return nil, nil
+	}
+	opts, ok := r.options(channel)
+	if !ok {
+		return nil, nil
+	}
+	publications, err := r.local.Get(ctx, channel, opts.Size)
+	if err != nil || len(publications) > 0 || r.shared == Storage(r.local) {
+		return publications, err
+	}
+	return r.shared.Get(ctx, channel, opts.Size)
+}
+
+// Run removes the expired publications kept in memory until the context is done.
+func (r *Recorder) Run(ctx context.Context) error {
+	return r.local.Run(ctx)
+}
+
+func (r *Recorder) options(channel string) (Options, bool) {
+	_, channelID, err := orgchannel.StripOrgID(channel)
+	if err != nil {
+		return Options{}, false
+	}
+	return r.policy.Options(channelID)
+}
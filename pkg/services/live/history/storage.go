@@ -0,0 +1,37 @@
+// Package history keeps the recent publications of Live channels, so clients
+// subscribing after data was pushed can render it right away instead of
+// waiting for the next push.
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Options limit the history of a channel.
+type Options struct {
+	// Size is the maximum number of publications kept.
+	Size int
+	// TTL is how long a publication is kept.
+	TTL time.Duration
+}
+
+// Storage keeps the history of channels. Channels are full channel IDs including the org ID.
+type Storage interface {
+	// Add appends a publication to the channel history and drops the ones
+	// beyond the options limits.
+	Add(ctx context.Context, channel string, data []byte, opts Options) error
+	// Get returns at most limit publications of the channel which have not
+	// expired, oldest first.
+	Get(ctx context.Context, channel string, limit int) ([]json.RawMessage, error)
+}
+
+type entry struct {
+	Data    json.RawMessage `json:"d"`
+	Expires int64           `json:"e"`
+}
+
+func (e entry) expired(now time.Time) bool {
+	return e.Expires <= now.UnixMilli()
+}
@@ -0,0 +1,100 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// pruneInterval is how often channels without live publications are removed from memory.
+const pruneInterval = time.Minute
+
+// MemoryStorage keeps the history of channels in memory, so it is only
+// available on the instance the publications were sent from.
+type MemoryStorage struct {
+	mu       sync.RWMutex
+	channels map[string][]entry
+	now      func() time.Time
+}
+
+var _ Storage = (*MemoryStorage)(nil)
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		channels: map[string][]entry{},
+		now:      time.Now,
+	}
+}
+
+func (s *MemoryStorage) Add(_ context.Context, channel string, data []byte, opts Options) error {
+	if opts.Size <= 0 {
+		return nil
+	}
+	now := s.now()
+	e := entry{
+		Data:    append(json.RawMessage(nil), data...),
+		Expires: now.Add(opts.TTL).UnixMilli(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := append(s.channels[channel], e)
+	if len(entries) > opts.Size {
+		entries = append([]entry(nil), entries[len(entries)-opts.Size:]...)
+	}
+	s.channels[channel] = entries
+	return nil
+}
+
+func (s *MemoryStorage) Get(_ context.Context, channel string, limit int) ([]json.RawMessage, error) {
+	now := s.now()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entries := s.channels[channel]
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	result := make([]json.RawMessage, 0, len(entries))
+	for _, e := range entries {
+		if !e.expired(now) {
+			result = append(result, e.Data)
+		}
+	}
+	return result, nil
+}
+
+// Run periodically removes the expired publications until the context is done.
+func (s *MemoryStorage) Run(ctx context.Context) error {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.prune()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *MemoryStorage) prune() {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for channel, entries := range s.channels {
+		live := entries[:0]
+		for _, e := range entries {
+			if !e.expired(now) {
+				live = append(live, e)
+			}
+		}
+		if len(live) == 0 {
+			delete(s.channels, channel)
+			continue
+		}
+		s.channels[channel] = live
+	}
+}
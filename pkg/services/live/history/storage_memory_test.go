@@ -0,0 +1,92 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testStorage(t *testing.T, s Storage, now *time.Time) {
+	ctx := context.Background()
+	opts := Options{Size: 2, TTL: time.Minute}
+
+	publications, err := s.Get(ctx, "1/stream/test", 10)
+	require.NoError(t, err)
+	require.Empty(t, publications)
+
+	for _, data := range []string{`{"v":1}`, `{"v":2}`, `{"v":3}`} {
+		require.NoError(t, s.Add(ctx, "1/stream/test", []byte(data), opts))
+	}
+
+	// only the last publications are kept, oldest first
+	publications, err = s.Get(ctx, "1/stream/test", 10)
+	require.NoError(t, err)
+	require.Equal(t, []json.RawMessage{json.RawMessage(`{"v":2}`), json.RawMessage(`{"v":3}`)}, publications)
+
+	publications, err = s.Get(ctx, "1/stream/test", 1)
+	require.NoError(t, err)
+	require.Equal(t, []json.RawMessage{json.RawMessage(`{"v":3}`)}, publications)
+
+	// channels are isolated
+	publications, err = s.Get(ctx, "2/stream/test", 10)
+	require.NoError(t, err)
+	require.Empty(t, publications)
+
+	// zero size keeps nothing
+	require.NoError(t, s.Add(ctx, "1/stream/other", []byte(`{}`), Options{TTL: time.Minute}))
+	publications, err = s.Get(ctx, "1/stream/other", 10)
+	require.NoError(t, err)
+	require.Empty(t, publications)
+
+	// expired publications are not returned
+	*now = now.Add(2 * time.Minute)
+	publications, err = s.Get(ctx, "1/stream/test", 10)
+	require.NoError(t, err)
+	require.Empty(t, publications)
+}
+
+func TestMemoryStorage(t *testing.T) {
+	now := time.Now()
+	s := NewMemoryStorage()
+	s.now = func() time.Time { return now }
+	testStorage(t, s, &now)
+
+	s.prune()
+	require.Empty(t, s.channels)
+}
+
+func TestRecorder(t *testing.T) {
+	ctx := context.Background()
+	policy, err := NewPolicy(Options{Size: 10, TTL: time.Minute}, []string{"stream/**"})
+	require.NoError(t, err)
+	shared := NewMemoryStorage()
+	local := NewMemoryStorage()
+	r := NewRecorder(policy, shared, local)
+
+	r.Add(ctx, "1/stream/shared", []byte(`{"v":1}`))
+	r.AddLocal(ctx, "1/stream/local", []byte(`{"v":2}`))
+	r.Add(ctx, "1/plugin/testdata/random", []byte(`{"v":3}`))
+
+	publications, err := r.Get(ctx, "1/stream/shared")
+	require.NoError(t, err)
+	require.Equal(t, []json.RawMessage{json.RawMessage(`{"v":1}`)}, publications)
+
+	publications, err = r.Get(ctx, "1/stream/local")
+	require.NoError(t, err)
+	require.Equal(t, []json.RawMessage{json.RawMessage(`{"v":2}`)}, publications)
+
+	// channels not matching the policy keep no history
+	publications, err = r.Get(ctx, "1/plugin/testdata/random")
+	require.NoError(t, err)
+	require.Empty(t, publications)
+	require.Empty(t, shared.channels["1/plugin/testdata/random"])
+
+	var nilRecorder *Recorder
+	nilRecorder.Add(ctx, "1/stream/shared", []byte(`{}`))
+	publications, err = nilRecorder.Get(ctx, "1/stream/shared")
+	require.NoError(t, err)
+	require.Empty(t, publications)
+}
@@ -0,0 +1,79 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisStorage keeps the history of channels in Redis lists, so it is shared
+// by all the instances using the Redis HA engine.
+type RedisStorage struct {
+	redisClient *redis.Client
+	keyPrefix   string
+	now         func() time.Time
+}
+
+var _ Storage = (*RedisStorage)(nil)
+
+func NewRedisStorage(redisClient *redis.Client, keyPrefix string) *RedisStorage {
+	return &RedisStorage{
+		redisClient: redisClient,
+		keyPrefix:   keyPrefix,
+		now:         time.Now,
+	}
+}
+
+func (s *RedisStorage) Add(ctx context.Context, channel string, data []byte, opts Options) error {
+	if opts.Size <= 0 {
+		return nil
+	}
+	value, err := json.Marshal(entry{
+		Data:    data,
+		Expires: s.now().Add(opts.TTL).UnixMilli(),
+	})
+	if err != nil {
+		return err
+	}
+
+	key := s.getKey(channel)
+	pipe := s.redisClient.TxPipeline()
+	defer func() { _ = pipe.Close() }()
+
+	pipe.RPush(ctx, key, value)
+	pipe.LTrim(ctx, key, int64(-opts.Size), -1)
+	// the list expires when its last publication does
+	pipe.PExpire(ctx, key, opts.TTL)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (s *RedisStorage) Get(ctx context.Context, channel string, limit int) ([]json.RawMessage, error) {
+	start := int64(0)
+	if limit > 0 {
+		start = int64(-limit)
+	}
+	values, err := s.redisClient.LRange(ctx, s.getKey(channel), start, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	result := make([]json.RawMessage, 0, len(values))
+	for _, value := range values {
+		var e entry
+		if err := json.Unmarshal([]byte(value), &e); err != nil {
+			return nil, err
+		}
+		if !e.expired(now) {
+			result = append(result, e.Data)
+		}
+	}
+	return result, nil
+}
+
+func (s *RedisStorage) getKey(channel string) string {
+	return s.keyPrefix + ".history." + channel
+}
@@ -0,0 +1,50 @@
+package history
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegrationRedisStorage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	u, ok := os.LookupEnv("REDIS_URL")
+	if !ok || u == "" {
+		t.Skip("No redis URL supplied")
+	}
+
+	addr := u
+	db := 0
+	parsed, err := redis.ParseURL(u)
+	if err == nil {
+		addr = parsed.Addr
+		db = parsed.DB
+	}
+
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: addr,
+		DB:   db,
+	})
+	prefix := uuid.New().String()
+
+	t.Cleanup(func() {
+		keys, err := redisClient.Keys(redisClient.Context(), prefix+"*").Result()
+		require.NoError(t, err)
+		for _, key := range keys {
+			_, err := redisClient.Del(redisClient.Context(), key).Result()
+			require.NoError(t, err)
+		}
+	})
+
+	now := time.Now()
+	s := NewRedisStorage(redisClient, prefix)
+	s.now = func() time.Time { return now }
+	testStorage(t, s, &now)
+}
@@ -47,10 +47,12 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/live/database"
 	"github.com/grafana/grafana/pkg/services/live/features"
+	"github.com/grafana/grafana/pkg/services/live/history"
 	"github.com/grafana/grafana/pkg/services/live/livecontext"
 	"github.com/grafana/grafana/pkg/services/live/liveplugin"
 	"github.com/grafana/grafana/pkg/services/live/managedstream"
 	"github.com/grafana/grafana/pkg/services/live/model"
+	"github.com/grafana/grafana/pkg/services/live/natsbroker"
 	"github.com/grafana/grafana/pkg/services/live/orgchannel"
 	"github.com/grafana/grafana/pkg/services/live/pipeline"
 	"github.com/grafana/grafana/pkg/services/live/pushws"
@@ -99,6 +101,8 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		SecretsService:        secretsService,
 		queryDataService:      queryDataService,
 		channels:              make(map[string]model.ChannelHandler),
+		channelAuthorizers:    make(map[string]model.ChannelAuthorizer),
+		accessControl:         accessControl,
 		GrafanaScope: CoreGrafanaScope{
 			Features: make(map[string]model.ChannelHandlerFactory),
 		},
@@ -134,53 +138,63 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	}
 	g.node = node
 
-	redisHealthy := false
+	var frameCache managedstream.FrameCache = managedstream.NewMemoryFrameCache()
 	if g.IsHA() {
-		// Configure HA with Redis. In this case Centrifuge nodes
-		// will be connected over Redis PUB/SUB. Presence will work
-		// globally since kept inside Redis.
-		err := setupRedisLiveEngine(g, node)
+		// Configure HA with Redis or NATS. In this case Centrifuge nodes
+		// will be connected over the engine PUB/SUB and presence will work
+		// globally.
+		cache, err := setupLiveEngine(g, node)
 		if err != nil {
-			logger.Error("failed to setup redis live engine", "error", err)
+			logger.Error("failed to setup live HA engine, proceeding without live ha", "engine", g.Cfg.LiveHAEngine, "error", err)
 		} else {
-			redisHealthy = true
+			frameCache = cache
 		}
 	}
 
-	channelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, nil)
-
-	var managedStreamRunner *managedstream.Runner
-	var redisClient *redis.Client
-	if g.IsHA() && redisHealthy {
-		redisClient = redis.NewClient(&redis.Options{
-			Addr:     g.Cfg.LiveHAEngineAddress,
-			Password: g.Cfg.LiveHAEnginePassword,
-		})
-		cmd := redisClient.Ping(context.Background())
-		if _, err := cmd.Result(); err != nil {
-			logger.Error("live engine failed to ping redis, proceeding without live ha", "error", err)
-			redisClient = nil
-		}
+	historyPolicy, err := history.NewPolicy(history.Options{
+		Size: cfg.LiveHistorySize,
+		TTL:  cfg.LiveHistoryTTL,
+	}, cfg.LiveHistoryChannels)
+	if err != nil {
+		return nil, err
 	}
-
-	if redisClient != nil {
-		managedStreamRunner = managedstream.NewRunner(
-			g.Publish,
-			channelLocalPublisher,
-			managedstream.NewRedisFrameCache(redisClient, g.keyPrefix),
-		)
-	} else {
-		managedStreamRunner = managedstream.NewRunner(
-			g.Publish,
-			channelLocalPublisher,
-			managedstream.NewMemoryFrameCache(),
-		)
+	localHistory := history.NewMemoryStorage()
+	var sharedHistory history.Storage = localHistory
+	if g.historyStorage != nil {
+		sharedHistory = g.historyStorage
 	}
+	g.history = history.NewRecorder(historyPolicy, sharedHistory, localHistory)
+
+	channelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, nil, g.history)
+
+	managedStreamRunner := managedstream.NewRunner(
+		g.Publish,
+		channelLocalPublisher,
+		frameCache,
+	)
 
 	g.ManagedStreamRunner = managedStreamRunner
 
+	if cfg.LivePipelineEnabled {
+		storage := pipeline.NewSQLStorage(sqlStore, g.SecretsService)
+		g.pipelineStorage = storage
+		g.channelRuleCache = pipeline.NewCacheSegmentedTree(&pipeline.StorageRuleBuilder{
+			Node:                 node,
+			ManagedStream:        g.ManagedStreamRunner,
+			FrameStorage:         pipeline.NewFrameStorage(),
+			Storage:              storage,
+			ChannelHandlerGetter: g,
+			SecretsService:       g.SecretsService,
+		})
+		g.Pipeline, err = pipeline.New(g.channelRuleCache)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	g.contextGetter = liveplugin.NewContextGetter(g.PluginContextProvider, g.DataSourceCache)
-	pipelinedChannelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, g.Pipeline)
+	g.registerConfiguredChannelAuthorizers()
+	pipelinedChannelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, g.Pipeline, g.history)
 	numLocalSubscribersGetter := liveplugin.NewNumLocalSubscribersGetter(node)
 	g.runStreamManager = runstream.NewManager(pipelinedChannelLocalPublisher, numLocalSubscribersGetter, g.contextGetter)
 
@@ -196,6 +210,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	g.GrafanaScope.Dashboards = dash
 	g.GrafanaScope.Features["dashboard"] = dash
 	g.GrafanaScope.Features["broadcast"] = features.NewBroadcastRunner(g.storage)
+	g.GrafanaScope.Features["expr"] = features.NewExpressionRunner(g.Publish, g.ClientCount, queryDataService, g.getStreamPlugin, g.contextGetter)
 
 	// Testing watch with just the provisioning support -- this will be removed when it is well validated
 	if toggles.IsEnabledGlobally(featuremgmt.FlagProvisioning) {
@@ -419,12 +434,38 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		group.Get("/pipeline/push/*", g.pushPipelineWebsocketHandler)
 	}, middleware.ReqOrgAdmin, requestmeta.SetSLOGroup(requestmeta.SLOGroupNone))
 
+	if g.Pipeline != nil {
+		g.RouteRegister.Group("/api/live", func(group routing.RouteRegister) {
+			group.Get("/channel-rules", routing.Wrap(g.HandleChannelRulesListHTTP))
+			group.Post("/channel-rules", routing.Wrap(g.HandleChannelRulesPostHTTP))
+			group.Put("/channel-rules", routing.Wrap(g.HandleChannelRulesPutHTTP))
+			group.Delete("/channel-rules", routing.Wrap(g.HandleChannelRulesDeleteHTTP))
+			group.Post("/pipeline-convert-test", routing.Wrap(g.HandlePipelineConvertTestHTTP))
+			group.Get("/pipeline-entities", routing.Wrap(g.HandlePipelineEntitiesListHTTP))
+			group.Get("/write-configs", routing.Wrap(g.HandleWriteConfigsListHTTP))
+			group.Post("/write-configs", routing.Wrap(g.HandleWriteConfigsPostHTTP))
+			group.Put("/write-configs", routing.Wrap(g.HandleWriteConfigsPutHTTP))
+			group.Delete("/write-configs", routing.Wrap(g.HandleWriteConfigsDeleteHTTP))
+		}, middleware.ReqOrgAdmin)
+	}
+
 	g.registerUsageMetrics()
 
 	return g, nil
 }
 
-func setupRedisLiveEngine(g *GrafanaLive, node *centrifuge.Node) error {
+const haEngineNATS = "nats"
+
+// setupLiveEngine configures the broker and presence manager of the node for
+// the HA engine and returns the frame cache shared by all the instances.
+func setupLiveEngine(g *GrafanaLive, node *centrifuge.Node) (managedstream.FrameCache, error) {
+	if g.Cfg.LiveHAEngine == haEngineNATS {
+		return setupNATSLiveEngine(g, node)
+	}
+	return setupRedisLiveEngine(g, node)
+}
+
+func setupRedisLiveEngine(g *GrafanaLive, node *centrifuge.Node) (managedstream.FrameCache, error) {
 	redisAddress := g.Cfg.LiveHAEngineAddress
 	redisPassword := g.Cfg.LiveHAEnginePassword
 	redisShardConfigs := []centrifuge.RedisShardConfig{
@@ -435,7 +476,7 @@ func setupRedisLiveEngine(g *GrafanaLive, node *centrifuge.Node) error {
 	for _, redisConf := range redisShardConfigs {
 		redisShard, err := centrifuge.NewRedisShard(node, redisConf)
 		if err != nil {
-			return fmt.Errorf("error connecting to Live Redis: %v", err)
+			return nil, fmt.Errorf("error connecting to Live Redis: %v", err)
 		}
 
 		redisShards = append(redisShards, redisShard)
@@ -446,7 +487,7 @@ func setupRedisLiveEngine(g *GrafanaLive, node *centrifuge.Node) error {
 		Shards: redisShards,
 	})
 	if err != nil {
-		return fmt.Errorf("error creating Live Redis broker: %v", err)
+		return nil, fmt.Errorf("error creating Live Redis broker: %v", err)
 	}
 
 	node.SetBroker(broker)
@@ -456,12 +497,42 @@ func setupRedisLiveEngine(g *GrafanaLive, node *centrifuge.Node) error {
 		Shards: redisShards,
 	})
 	if err != nil {
-		return fmt.Errorf("error creating Live Redis presence manager: %v", err)
+		return nil, fmt.Errorf("error creating Live Redis presence manager: %v", err)
 	}
 
 	node.SetPresenceManager(presenceManager)
 
-	return nil
+	redisClient := redis.NewClient(&redis.Options{
+		Addr:     redisAddress,
+		Password: redisPassword,
+	})
+	cmd := redisClient.Ping(context.Background())
+	if _, err := cmd.Result(); err != nil {
+		return nil, fmt.Errorf("live engine failed to ping redis: %w", err)
+	}
+
+	g.historyStorage = history.NewRedisStorage(redisClient, g.keyPrefix)
+	return managedstream.NewRedisFrameCache(redisClient, g.keyPrefix), nil
+}
+
+func setupNATSLiveEngine(g *GrafanaLive, node *centrifuge.Node) (managedstream.FrameCache, error) {
+	engine, err := natsbroker.New(node, natsbroker.Config{
+		Address:  g.Cfg.LiveHAEngineAddress,
+		Password: g.Cfg.LiveHAEnginePassword,
+		Prefix:   g.keyPrefix,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	frameCache, err := managedstream.NewNATSFrameCache(engine.Conn, g.keyPrefix, node.ID())
+	if err != nil {
+		engine.Conn.Close()
+		return nil, fmt.Errorf("error creating Live NATS frame cache: %v", err)
+	}
+
+	g.natsEngine = engine
+	return frameCache, nil
 }
 
 // GrafanaLive manages live real-time connections to Grafana (over WebSocket at this moment).
@@ -485,6 +556,9 @@ type GrafanaLive struct {
 
 	keyPrefix string
 
+	// natsEngine is set when the NATS HA engine is used.
+	natsEngine *natsbroker.Engine
+
 	node         *centrifuge.Node
 	surveyCaller *survey.Caller
 
@@ -497,17 +571,27 @@ type GrafanaLive struct {
 	channels   map[string]model.ChannelHandler
 	channelsMu sync.RWMutex
 
+	// channelAuthorizers check the channels of a scope before their handlers.
+	channelAuthorizers   map[string]model.ChannelAuthorizer
+	channelAuthorizersMu sync.RWMutex
+	accessControl        accesscontrol.AccessControl
+
 	// The core internal features
 	GrafanaScope CoreGrafanaScope
 
 	ManagedStreamRunner *managedstream.Runner
 	Pipeline            *pipeline.Pipeline
 	pipelineStorage     pipeline.Storage
+	channelRuleCache    *pipeline.CacheSegmentedTree
 
 	contextGetter    *liveplugin.ContextGetter
 	runStreamManager *runstream.Manager
 	storage          *database.Storage
 
+	// history keeps the recent publications of channels for new subscribers.
+	history        *history.Recorder
+	historyStorage history.Storage
+
 	usageStatsService usagestats.Service
 	usageStats        usageStats
 }
@@ -556,6 +640,18 @@ func (g *GrafanaLive) Run(ctx context.Context) error {
 		}
 	})
 
+	if g.natsEngine != nil {
+		eGroup.Go(func() error {
+			return g.natsEngine.Run(eCtx)
+		})
+	}
+
+	if g.history != nil {
+		eGroup.Go(func() error {
+			return g.history.Run(eCtx)
+		})
+	}
+
 	if g.runStreamManager != nil {
 		// Only run stream manager if GrafanaLive properly initialized.
 		eGroup.Go(func() error {
@@ -749,6 +845,17 @@ func (g *GrafanaLive) handleOnSubscribe(clientContextWithSpan context.Context, c
 		return centrifuge.SubscribeReply{}, centrifuge.ErrorPermissionDenied
 	}
 
+	allowed, err := g.authorizeChannel(clientContextWithSpan, user, channel, false)
+	if err != nil {
+		logger.Error("Error checking subscribe permissions", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
+		return centrifuge.SubscribeReply{}, centrifuge.ErrorInternal
+	}
+	if !allowed {
+		// using HTTP error codes for WS errors too.
+		code, text := subscribeStatusToHTTPError(backend.SubscribeStreamStatusPermissionDenied)
+		return centrifuge.SubscribeReply{}, &centrifuge.Error{Code: uint32(code), Message: text}
+	}
+
 	var reply model.SubscribeReply
 	var status backend.SubscribeStreamStatus
 	var ruleFound bool
@@ -817,6 +924,11 @@ func (g *GrafanaLive) handleOnSubscribe(clientContextWithSpan context.Context, c
 		logger.Debug("Return custom subscribe error", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "code", code)
 		return centrifuge.SubscribeReply{}, &centrifuge.Error{Code: uint32(code), Message: text}
 	}
+	data, err := g.subscribeData(clientContextWithSpan, e.Channel, reply)
+	if err != nil {
+		logger.Error("Error getting channel history", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
+		return centrifuge.SubscribeReply{}, centrifuge.ErrorInternal
+	}
 	logger.Debug("Client subscribed", "user", client.UserID(), "client", client.ID(), "channel", e.Channel)
 	return centrifuge.SubscribeReply{
 		Options: centrifuge.SubscribeOptions{
@@ -824,11 +936,32 @@ func (g *GrafanaLive) handleOnSubscribe(clientContextWithSpan context.Context, c
 			EmitJoinLeave:  reply.JoinLeave,
 			PushJoinLeave:  reply.JoinLeave,
 			EnableRecovery: reply.Recover,
-			Data:           reply.Data,
+			Data:           data,
 		},
 	}, nil
 }
 
+// subscribeData returns the data sent to the client once subscribed. When the
+// handler has no initial data, the recent publications of the channel are
+// replayed so the client does not wait for the next one to render.
+func (g *GrafanaLive) subscribeData(ctx context.Context, channel string, reply model.SubscribeReply) (json.RawMessage, error) {
+	if reply.Data != nil {
+		return reply.Data, nil
+	}
+	publications := reply.History
+	if publications == nil {
+		var err error
+		publications, err = g.history.Get(ctx, channel)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(publications) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(model.SubscribeHistory{History: publications})
+}
+
 func (g *GrafanaLive) handleOnPublish(clientCtxWithSpan context.Context, client *centrifuge.Client, e centrifuge.PublishEvent) (centrifuge.PublishReply, error) {
 	logger.Debug("Client wants to publish", "user", client.UserID(), "client", client.ID(), "channel", e.Channel)
 
@@ -855,6 +988,17 @@ func (g *GrafanaLive) handleOnPublish(clientCtxWithSpan context.Context, client
 		return centrifuge.PublishReply{}, centrifuge.ErrorPermissionDenied
 	}
 
+	allowed, err := g.authorizeChannel(clientCtxWithSpan, user, channel, true)
+	if err != nil {
+		logger.Error("Error checking publish permissions", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
+		return centrifuge.PublishReply{}, centrifuge.ErrorInternal
+	}
+	if !allowed {
+		// using HTTP error codes for WS errors too.
+		code, text := publishStatusToHTTPError(backend.PublishStreamStatusPermissionDenied)
+		return centrifuge.PublishReply{}, &centrifuge.Error{Code: uint32(code), Message: text}
+	}
+
 	if g.Pipeline != nil {
 		rule, ok, err := g.Pipeline.Get(user.GetOrgID(), channel)
 		if err != nil {
@@ -931,6 +1075,9 @@ func (g *GrafanaLive) handleOnPublish(clientCtxWithSpan context.Context, client
 			return centrifuge.PublishReply{}, centrifuge.ErrorInternal
 		}
 		centrifugeReply.Result = &result
+		g.history.Add(clientCtxWithSpan, e.Channel, reply.Data)
+	} else {
+		g.history.Add(clientCtxWithSpan, e.Channel, e.Data)
 	}
 	logger.Debug("Publication successful", "user", client.UserID(), "client", client.ID(), "channel", e.Channel)
 	return centrifugeReply, nil
@@ -1071,8 +1218,12 @@ func (g *GrafanaLive) handleDatasourceScope(ctx context.Context, user identity.R
 
 // Publish sends the data to the channel without checking permissions etc.
 func (g *GrafanaLive) Publish(orgID int64, channel string, data []byte) error {
-	_, err := g.node.Publish(orgchannel.PrependOrgID(orgID, channel), data)
-	return err
+	ch := orgchannel.PrependOrgID(orgID, channel)
+	if _, err := g.node.Publish(ch, data); err != nil {
+		return err
+	}
+	g.history.Add(context.Background(), ch, data)
+	return nil
 }
 
 // ClientCount returns the number of clients.
@@ -1098,6 +1249,15 @@ func (g *GrafanaLive) HandleHTTPPublish(ctx *contextmodel.ReqContext) response.R
 	user := ctx.SignedInUser
 	channel := cmd.Channel
 
+	allowed, err := g.authorizeChannel(ctx.Req.Context(), user, channel, true)
+	if err != nil {
+		logger.Error("Error checking publish permissions", "user", user, "channel", channel, "error", err)
+		return response.Error(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), nil)
+	}
+	if !allowed {
+		return response.Error(http.StatusForbidden, http.StatusText(http.StatusForbidden), nil)
+	}
+
 	if g.Pipeline != nil {
 		rule, ok, err := g.Pipeline.Get(user.GetOrgID(), channel)
 		if err != nil {
@@ -1309,8 +1469,9 @@ func (g *GrafanaLive) HandleChannelRulesPostHTTP(c *contextmodel.ReqContext) res
 	}
 	rule, err := g.pipelineStorage.CreateChannelRule(c.Req.Context(), c.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to create channel rule", err)
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create channel rule", err)
 	}
+	g.reloadChannelRules(c.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{
 		"rule": rule,
 	})
@@ -1332,8 +1493,9 @@ func (g *GrafanaLive) HandleChannelRulesPutHTTP(c *contextmodel.ReqContext) resp
 	}
 	rule, err := g.pipelineStorage.UpdateChannelRule(c.Req.Context(), c.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to update channel rule", err)
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update channel rule", err)
 	}
+	g.reloadChannelRules(c.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{
 		"rule": rule,
 	})
@@ -1355,11 +1517,23 @@ func (g *GrafanaLive) HandleChannelRulesDeleteHTTP(c *contextmodel.ReqContext) r
 	}
 	err = g.pipelineStorage.DeleteChannelRule(c.Req.Context(), c.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to delete channel rule", err)
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete channel rule", err)
 	}
+	g.reloadChannelRules(c.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{})
 }
 
+// reloadChannelRules applies the changes to the channel rules of an organization on this
+// instance, the other instances pick them up with the periodic update of the rules.
+func (g *GrafanaLive) reloadChannelRules(orgID int64) {
+	if g.channelRuleCache == nil {
+		return
+	}
+	if err := g.channelRuleCache.Reload(orgID); err != nil {
+		logger.Error("Failed to reload channel rules", "orgId", orgID, "error", err)
+	}
+}
+
 // HandlePipelineEntitiesListHTTP ...
 func (g *GrafanaLive) HandlePipelineEntitiesListHTTP(_ *contextmodel.ReqContext) response.Response {
 	return response.JSON(http.StatusOK, util.DynMap{
@@ -1399,8 +1573,9 @@ func (g *GrafanaLive) HandleWriteConfigsPostHTTP(c *contextmodel.ReqContext) res
 	}
 	result, err := g.pipelineStorage.CreateWriteConfig(c.Req.Context(), c.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to create write config", err)
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create write config", err)
 	}
+	g.reloadChannelRules(c.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{
 		"writeConfig": pipeline.WriteConfigToDto(result),
 	})
@@ -1443,8 +1618,9 @@ func (g *GrafanaLive) HandleWriteConfigsPutHTTP(c *contextmodel.ReqContext) resp
 	}
 	result, err := g.pipelineStorage.UpdateWriteConfig(c.Req.Context(), c.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to update write config", err)
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update write config", err)
 	}
+	g.reloadChannelRules(c.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{
 		"writeConfig": pipeline.WriteConfigToDto(result),
 	})
@@ -1466,8 +1642,9 @@ func (g *GrafanaLive) HandleWriteConfigsDeleteHTTP(c *contextmodel.ReqContext) r
 	}
 	err = g.pipelineStorage.DeleteWriteConfig(c.Req.Context(), c.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to delete write config", err)
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete write config", err)
 	}
+	g.reloadChannelRules(c.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{})
 }
 
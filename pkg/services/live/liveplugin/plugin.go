@@ -9,6 +9,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/live/history"
 	"github.com/grafana/grafana/pkg/services/live/orgchannel"
 	"github.com/grafana/grafana/pkg/services/live/pipeline"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
@@ -17,10 +18,11 @@ import (
 type ChannelLocalPublisher struct {
 	node     *centrifuge.Node
 	pipeline *pipeline.Pipeline
+	history  *history.Recorder
 }
 
-func NewChannelLocalPublisher(node *centrifuge.Node, pipeline *pipeline.Pipeline) *ChannelLocalPublisher {
-	return &ChannelLocalPublisher{node: node, pipeline: pipeline}
+func NewChannelLocalPublisher(node *centrifuge.Node, pipeline *pipeline.Pipeline, history *history.Recorder) *ChannelLocalPublisher {
+	return &ChannelLocalPublisher{node: node, pipeline: pipeline, history: history}
 }
 
 func (p *ChannelLocalPublisher) PublishLocal(channel string, data []byte) error {
@@ -45,6 +47,7 @@ func (p *ChannelLocalPublisher) PublishLocal(channel string, data []byte) error
 	if err != nil {
 		return fmt.Errorf("error publishing %s: %w", string(data), err)
 	}
+	p.history.AddLocal(context.Background(), channel, data)
 	return nil
 }
 
@@ -0,0 +1,188 @@
+package managedstream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/nats-io/nats.go"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+// natsFrameRequestTimeout is how long to wait for another node to return a frame missing locally.
+const natsFrameRequestTimeout = 250 * time.Millisecond
+
+type natsFrame struct {
+	Schema json.RawMessage `json:"schema"`
+	Frame  json.RawMessage `json:"frame"`
+}
+
+type natsFrameMessage struct {
+	NodeID  string          `json:"node"`
+	OrgID   int64           `json:"orgId"`
+	Channel string          `json:"channel"`
+	Schema  json.RawMessage `json:"schema,omitempty"`
+	Frame   json.RawMessage `json:"frame,omitempty"`
+}
+
+// NATSFrameCache keeps frames in memory and replicates updates to the
+// other nodes over NATS, since NATS has no storage.
+type NATSFrameCache struct {
+	mu        sync.RWMutex
+	nc        *nats.Conn
+	frames    map[int64]map[string]natsFrame
+	keyPrefix string
+	nodeID    string
+	log       log.Logger
+}
+
+// NewNATSFrameCache creates a frame cache and subscribes to the updates and requests of the other nodes.
+func NewNATSFrameCache(nc *nats.Conn, keyPrefix string, nodeID string) (*NATSFrameCache, error) {
+	c := &NATSFrameCache{
+		nc:        nc,
+		frames:    map[int64]map[string]natsFrame{},
+		keyPrefix: keyPrefix,
+		nodeID:    nodeID,
+		log:       log.New("live.natsframecache"),
+	}
+	if _, err := nc.Subscribe(c.updateSubject(), c.handleUpdate); err != nil {
+		return nil, err
+	}
+	if _, err := nc.Subscribe(c.requestSubject(), c.handleRequest); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *NATSFrameCache) GetActiveChannels(orgID int64) (map[string]json.RawMessage, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	frames, ok := c.frames[orgID]
+	if !ok {
+		return nil, nil
+	}
+	info := make(map[string]json.RawMessage, len(frames))
+	for k, v := range frames {
+		info[k] = v.Schema
+	}
+	return info, nil
+}
+
+func (c *NATSFrameCache) GetFrame(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error) {
+	if frame, ok := c.get(orgID, channel); ok {
+		return frame.Frame, true, nil
+	}
+
+	// the frame may have been published before this node started
+	request, err := json.Marshal(natsFrameMessage{NodeID: c.nodeID, OrgID: orgID, Channel: channel})
+	if err != nil {
+		return nil, false, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, natsFrameRequestTimeout)
+	defer cancel()
+	reply, err := c.nc.RequestWithContext(ctx, c.requestSubject(), request)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, nats.ErrTimeout) || errors.Is(err, nats.ErrNoResponders) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	var msg natsFrameMessage
+	if err := json.Unmarshal(reply.Data, &msg); err != nil {
+		return nil, false, err
+	}
+	c.set(orgID, channel, natsFrame{Schema: msg.Schema, Frame: msg.Frame})
+	return msg.Frame, true, nil
+}
+
+func (c *NATSFrameCache) Update(ctx context.Context, orgID int64, channel string, jsonFrame data.FrameJSONCache) (bool, error) {
+	frame := natsFrame{
+		Schema: jsonFrame.Bytes(data.IncludeSchemaOnly),
+		Frame:  jsonFrame.Bytes(data.IncludeAll),
+	}
+	previous, exists := c.get(orgID, channel)
+	c.set(orgID, channel, frame)
+
+	msg, err := json.Marshal(natsFrameMessage{
+		NodeID:  c.nodeID,
+		OrgID:   orgID,
+		Channel: channel,
+		Schema:  frame.Schema,
+		Frame:   frame.Frame,
+	})
+	if err != nil {
+		return false, err
+	}
+	if err := c.nc.Publish(c.updateSubject(), msg); err != nil {
+		return false, err
+	}
+
+	return !exists || !bytes.Equal(previous.Schema, frame.Schema), nil
+}
+
+func (c *NATSFrameCache) handleUpdate(m *nats.Msg) {
+	var msg natsFrameMessage
+	if err := json.Unmarshal(m.Data, &msg); err != nil {
+		c.log.Error("Failed to decode frame update", "error", err)
+		return
+	}
+	if msg.NodeID == c.nodeID {
+		return
+	}
+	c.set(msg.OrgID, msg.Channel, natsFrame{Schema: msg.Schema, Frame: msg.Frame})
+}
+
+func (c *NATSFrameCache) handleRequest(m *nats.Msg) {
+	var msg natsFrameMessage
+	if err := json.Unmarshal(m.Data, &msg); err != nil {
+		c.log.Error("Failed to decode frame request", "error", err)
+		return
+	}
+	frame, ok := c.get(msg.OrgID, msg.Channel)
+	// only the nodes having the frame reply, the first reply wins
+	if msg.NodeID == c.nodeID || !ok {
+		return
+	}
+
+	msg.NodeID = c.nodeID
+	msg.Schema = frame.Schema
+	msg.Frame = frame.Frame
+	reply, err := json.Marshal(msg)
+	if err != nil {
+		c.log.Error("Failed to encode frame", "error", err)
+		return
+	}
+	if err := m.Respond(reply); err != nil {
+		c.log.Error("Failed to reply with frame", "orgId", msg.OrgID, "channel", msg.Channel, "error", err)
+	}
+}
+
+func (c *NATSFrameCache) get(orgID int64, channel string) (natsFrame, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	frame, ok := c.frames[orgID][channel]
+	return frame, ok
+}
+
+func (c *NATSFrameCache) set(orgID int64, channel string, frame natsFrame) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.frames[orgID]; !ok {
+		c.frames[orgID] = map[string]natsFrame{}
+	}
+	c.frames[orgID][channel] = frame
+}
+
+func (c *NATSFrameCache) updateSubject() string {
+	return c.keyPrefix + ".managed_stream.update"
+}
+
+func (c *NATSFrameCache) requestSubject() string {
+	return c.keyPrefix + ".managed_stream.get"
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/live"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 )
@@ -29,6 +30,16 @@ type SubscribeReply struct {
 	JoinLeave bool
 	Recover   bool
 	Data      json.RawMessage
+	// History are the recent publications replayed to the client once
+	// subscribed when Data is empty. When nil, Grafana Live replays the
+	// channel history kept according to the [live] history settings.
+	History []json.RawMessage
+}
+
+// SubscribeHistory is the data sent to a client once subscribed when
+// recent publications of the channel are replayed.
+type SubscribeHistory struct {
+	History []json.RawMessage `json:"history"`
 }
 
 // PublishEvent contains publication data.
@@ -60,6 +71,14 @@ type ChannelHandler interface {
 	OnPublish(ctx context.Context, user identity.Requester, e PublishEvent) (PublishReply, backend.PublishStreamStatus, error)
 }
 
+// ChannelAuthorizer checks channel subscriptions and publications of a scope
+// before they reach the channel handler, so access can be restricted beyond the
+// checks of the handlers. Channels are passed without the org ID.
+type ChannelAuthorizer interface {
+	CanSubscribe(ctx context.Context, user identity.Requester, channel live.Channel) (bool, error)
+	CanPublish(ctx context.Context, user identity.Requester, channel live.Channel) (bool, error)
+}
+
 // ChannelHandlerFactory should be implemented by all core features.
 type ChannelHandlerFactory interface {
 	// GetHandlerForPath gets a ChannelHandler for a path.
@@ -0,0 +1,228 @@
+package natsbroker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/nats-io/nats.go"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+var logger = log.New("live.natsbroker")
+
+type messageType string
+
+const (
+	messageTypePublication messageType = "publication"
+	messageTypeJoin        messageType = "join"
+	messageTypeLeave       messageType = "leave"
+)
+
+// message is the payload sent over NATS for channel events.
+type message struct {
+	Type messageType       `json:"type"`
+	Data []byte            `json:"data,omitempty"`
+	Info *clientInfo       `json:"info,omitempty"`
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+type clientInfo struct {
+	ClientID string `json:"client"`
+	UserID   string `json:"user"`
+	ConnInfo []byte `json:"connInfo,omitempty"`
+	ChanInfo []byte `json:"chanInfo,omitempty"`
+}
+
+func toClientInfo(info *centrifuge.ClientInfo) *clientInfo {
+	if info == nil {
+		return nil
+	}
+	return &clientInfo{
+		ClientID: info.ClientID,
+		UserID:   info.UserID,
+		ConnInfo: info.ConnInfo,
+		ChanInfo: info.ChanInfo,
+	}
+}
+
+func (i *clientInfo) centrifuge() *centrifuge.ClientInfo {
+	if i == nil {
+		return nil
+	}
+	return &centrifuge.ClientInfo{
+		ClientID: i.ClientID,
+		UserID:   i.UserID,
+		ConnInfo: i.ConnInfo,
+		ChanInfo: i.ChanInfo,
+	}
+}
+
+// Broker is a centrifuge broker which fans out publications, join and leave
+// messages and control messages to all Grafana instances over NATS.
+//
+// NATS doesn't persist messages so the broker doesn't keep a stream history.
+type Broker struct {
+	nc     *nats.Conn
+	prefix string
+	nodeID string
+
+	eventHandler centrifuge.BrokerEventHandler
+
+	mu   sync.Mutex
+	subs map[string]*nats.Subscription
+}
+
+var _ centrifuge.Broker = (*Broker)(nil)
+
+// NewBroker creates a broker publishing under the subject prefix.
+func NewBroker(nc *nats.Conn, prefix string, nodeID string) *Broker {
+	return &Broker{
+		nc:     nc,
+		prefix: prefix,
+		nodeID: nodeID,
+		subs:   map[string]*nats.Subscription{},
+	}
+}
+
+// RegisterBrokerEventHandler is called once when the node starts.
+func (b *Broker) RegisterBrokerEventHandler(h centrifuge.BrokerEventHandler) error {
+	b.eventHandler = h
+	return nil
+}
+
+// RegisterControlEventHandler subscribes to the control messages sent to all nodes
+// and to this node.
+func (b *Broker) RegisterControlEventHandler(h centrifuge.ControlEventHandler) error {
+	handle := func(msg *nats.Msg) {
+		if err := h.HandleControl(msg.Data); err != nil {
+			logger.Error("Failed to handle control message", "error", err)
+		}
+	}
+	if _, err := b.nc.Subscribe(b.controlSubject(""), handle); err != nil {
+		return err
+	}
+	_, err := b.nc.Subscribe(b.controlSubject(b.nodeID), handle)
+	return err
+}
+
+// PublishControl sends a control message to a node or to all nodes when nodeID is empty.
+func (b *Broker) PublishControl(data []byte, nodeID, _ string) error {
+	return b.nc.Publish(b.controlSubject(nodeID), data)
+}
+
+// Subscribe starts receiving the messages of a channel.
+func (b *Broker) Subscribe(ch string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subs[ch]; ok {
+		return nil
+	}
+	sub, err := b.nc.Subscribe(b.channelSubject(ch), func(msg *nats.Msg) {
+		b.handleMessage(ch, msg.Data)
+	})
+	if err != nil {
+		return err
+	}
+	b.subs[ch] = sub
+	return nil
+}
+
+// Unsubscribe stops receiving the messages of a channel.
+func (b *Broker) Unsubscribe(ch string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub, ok := b.subs[ch]
+	if !ok {
+		return nil
+	}
+	delete(b.subs, ch)
+	return sub.Unsubscribe()
+}
+
+// Publish sends data to all the nodes subscribed to the channel.
+func (b *Broker) Publish(ch string, data []byte, opts centrifuge.PublishOptions) (centrifuge.StreamPosition, bool, error) {
+	return centrifuge.StreamPosition{}, false, b.publish(ch, message{
+		Type: messageTypePublication,
+		Data: data,
+		Info: toClientInfo(opts.ClientInfo),
+		Tags: opts.Tags,
+	})
+}
+
+// PublishJoin sends a join message to all the nodes subscribed to the channel.
+func (b *Broker) PublishJoin(ch string, info *centrifuge.ClientInfo) error {
+	return b.publish(ch, message{Type: messageTypeJoin, Info: toClientInfo(info)})
+}
+
+// PublishLeave sends a leave message to all the nodes subscribed to the channel.
+func (b *Broker) PublishLeave(ch string, info *centrifuge.ClientInfo) error {
+	return b.publish(ch, message{Type: messageTypeLeave, Info: toClientInfo(info)})
+}
+
+// History is not available since NATS doesn't persist messages.
+func (b *Broker) History(_ string, _ centrifuge.HistoryOptions) ([]*centrifuge.Publication, centrifuge.StreamPosition, error) {
+	return nil, centrifuge.StreamPosition{}, centrifuge.ErrorNotAvailable
+}
+
+// RemoveHistory is a no-op since no history is kept.
+func (b *Broker) RemoveHistory(_ string) error {
+	return nil
+}
+
+func (b *Broker) publish(ch string, msg message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return b.nc.Publish(b.channelSubject(ch), data)
+}
+
+func (b *Broker) handleMessage(ch string, data []byte) {
+	if b.eventHandler == nil {
+		return
+	}
+
+	var msg message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		logger.Error("Failed to decode message", "channel", ch, "error", err)
+		return
+	}
+
+	var err error
+	switch msg.Type {
+	case messageTypePublication:
+		err = b.eventHandler.HandlePublication(ch, &centrifuge.Publication{
+			Data: msg.Data,
+			Info: msg.Info.centrifuge(),
+			Tags: msg.Tags,
+		}, centrifuge.StreamPosition{}, false, nil)
+	case messageTypeJoin:
+		err = b.eventHandler.HandleJoin(ch, msg.Info.centrifuge())
+	case messageTypeLeave:
+		err = b.eventHandler.HandleLeave(ch, msg.Info.centrifuge())
+	default:
+		err = fmt.Errorf("unknown message type %q", msg.Type)
+	}
+	if err != nil {
+		logger.Error("Failed to handle message", "channel", ch, "type", msg.Type, "error", err)
+	}
+}
+
+// channelSubject encodes the channel since channel names can contain
+// characters that have a meaning in NATS subjects.
+func (b *Broker) channelSubject(ch string) string {
+	return b.prefix + ".channel." + base64.RawURLEncoding.EncodeToString([]byte(ch))
+}
+
+func (b *Broker) controlSubject(nodeID string) string {
+	if nodeID == "" {
+		return b.prefix + ".control"
+	}
+	return b.prefix + ".control.node." + nodeID
+}
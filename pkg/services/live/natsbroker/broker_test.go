@@ -0,0 +1,16 @@
+package natsbroker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroker_channelSubject(t *testing.T) {
+	b := NewBroker(nil, "gf_live", "node-1")
+
+	require.Equal(t, "gf_live.channel.MS9ncmFmYW5hL2Rhc2hib2FyZC91aWQvYWJj", b.channelSubject("1/grafana/dashboard/uid/abc"))
+	require.NotContains(t, b.channelSubject("1/stream/a.b*>"), "*")
+	require.Equal(t, "gf_live.control", b.controlSubject(""))
+	require.Equal(t, "gf_live.control.node.node-1", b.controlSubject("node-1"))
+}
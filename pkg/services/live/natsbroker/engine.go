@@ -0,0 +1,117 @@
+// Package natsbroker implements a Grafana Live HA engine on top of NATS.
+// Publications, join and leave messages and control messages are fanned out
+// to all Grafana instances over NATS subjects, presence is gathered from all
+// instances on demand.
+package natsbroker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/nats-io/nats.go"
+)
+
+// Config is the configuration of the NATS engine.
+type Config struct {
+	// Address is a comma-separated list of NATS server URLs.
+	Address string
+	// Password is the token used to authenticate with NATS, credentials
+	// can also be set in the URLs.
+	Password string
+	// Prefix is prepended to all the subjects, so several Grafana
+	// clusters can share the same NATS servers.
+	Prefix string
+}
+
+// Engine holds the NATS connection shared by the broker and the presence manager.
+type Engine struct {
+	Conn     *nats.Conn
+	Broker   *Broker
+	Presence *PresenceManager
+
+	prefix string
+	nodeID string
+	peers  *peers
+}
+
+// New connects to NATS and sets the broker and presence manager of the node.
+func New(node *centrifuge.Node, cfg Config) (*Engine, error) {
+	opts := []nats.Option{
+		nats.Name("grafana-live-" + node.ID()),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				logger.Warn("Disconnected from NATS", "error", err)
+			}
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			logger.Info("Reconnected to NATS", "url", nc.ConnectedUrlRedacted())
+		}),
+	}
+	if cfg.Password != "" {
+		opts = append(opts, nats.Token(cfg.Password))
+	}
+
+	nc, err := nats.Connect(strings.ReplaceAll(cfg.Address, " ", ""), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to Live NATS: %w", err)
+	}
+
+	e := &Engine{
+		Conn:   nc,
+		Broker: NewBroker(nc, cfg.Prefix, node.ID()),
+		prefix: cfg.Prefix,
+		nodeID: node.ID(),
+		peers:  newPeers(node.ID()),
+	}
+
+	if _, err := nc.Subscribe(e.heartbeatSubject(), func(msg *nats.Msg) {
+		e.peers.heartbeat(string(msg.Data))
+	}); err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("error subscribing to Live NATS heartbeats: %w", err)
+	}
+
+	e.Presence, err = newPresenceManager(node, nc, cfg.Prefix, e.peers)
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("error creating Live NATS presence manager: %w", err)
+	}
+
+	node.SetBroker(e.Broker)
+	node.SetPresenceManager(e.Presence)
+
+	return e, nil
+}
+
+// Run sends heartbeats so the other nodes know this one is alive, and closes
+// the connection when the context is done.
+func (e *Engine) Run(ctx context.Context) error {
+	defer e.Conn.Close()
+
+	e.sendHeartbeat()
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			e.sendHeartbeat()
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (e *Engine) sendHeartbeat() {
+	if err := e.Conn.Publish(e.heartbeatSubject(), []byte(e.nodeID)); err != nil {
+		logger.Warn("Failed to send heartbeat", "error", err)
+	}
+}
+
+func (e *Engine) heartbeatSubject() string {
+	return e.prefix + ".nodes"
+}
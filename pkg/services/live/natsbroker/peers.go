@@ -0,0 +1,54 @@
+package natsbroker
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	heartbeatInterval = 5 * time.Second
+	// peerTTL is how long a node is considered alive after its last heartbeat.
+	peerTTL = 3 * heartbeatInterval
+)
+
+// peers tracks the other nodes from their heartbeats, so requests sent to all
+// nodes know how many replies to wait for.
+type peers struct {
+	nodeID string
+	now    func() time.Time
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+func newPeers(nodeID string) *peers {
+	return &peers{
+		nodeID: nodeID,
+		now:    time.Now,
+		seen:   map[string]time.Time{},
+	}
+}
+
+func (p *peers) heartbeat(nodeID string) {
+	if nodeID == p.nodeID {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.seen[nodeID] = p.now()
+}
+
+// count returns the number of other nodes alive and forgets the ones that stopped.
+func (p *peers) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	for id, last := range p.seen {
+		if now.Sub(last) > peerTTL {
+			delete(p.seen, id)
+		}
+	}
+	return len(p.seen)
+}
@@ -0,0 +1,28 @@
+package natsbroker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeers(t *testing.T) {
+	now := time.Now()
+	p := newPeers("node-1")
+	p.now = func() time.Time { return now }
+
+	p.heartbeat("node-1")
+	require.Equal(t, 0, p.count(), "the node itself is not a peer")
+
+	p.heartbeat("node-2")
+	p.heartbeat("node-3")
+	require.Equal(t, 2, p.count())
+
+	now = now.Add(peerTTL)
+	p.heartbeat("node-3")
+	require.Equal(t, 2, p.count())
+
+	now = now.Add(time.Second)
+	require.Equal(t, 1, p.count(), "node-2 stopped sending heartbeats")
+}
@@ -0,0 +1,166 @@
+package natsbroker
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/nats-io/nats.go"
+)
+
+// presenceTimeout is how long to wait for the other nodes to reply with their presence.
+const presenceTimeout = time.Second
+
+type presenceRequest struct {
+	NodeID  string `json:"node"`
+	Channel string `json:"channel"`
+}
+
+type presenceReply struct {
+	Clients map[string]*clientInfo `json:"clients"`
+}
+
+// PresenceManager keeps the presence of the clients connected to this node in memory
+// and asks the other nodes for theirs, so presence is shared without a storage.
+type PresenceManager struct {
+	*centrifuge.MemoryPresenceManager
+
+	nc     *nats.Conn
+	prefix string
+	nodeID string
+	peers  *peers
+}
+
+// newPresenceManager creates a presence manager and starts replying to the presence requests of the other nodes.
+func newPresenceManager(node *centrifuge.Node, nc *nats.Conn, prefix string, peers *peers) (*PresenceManager, error) {
+	local, err := centrifuge.NewMemoryPresenceManager(node, centrifuge.MemoryPresenceManagerConfig{})
+	if err != nil {
+		return nil, err
+	}
+
+	m := &PresenceManager{
+		MemoryPresenceManager: local,
+		nc:                    nc,
+		prefix:                prefix,
+		nodeID:                node.ID(),
+		peers:                 peers,
+	}
+	if _, err := nc.Subscribe(m.subject(), m.handleRequest); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Presence returns the clients subscribed to the channel on all nodes.
+func (m *PresenceManager) Presence(ch string) (map[string]*centrifuge.ClientInfo, error) {
+	local, err := m.MemoryPresenceManager.Presence(ch)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*centrifuge.ClientInfo, len(local))
+	for id, info := range local {
+		result[id] = info
+	}
+
+	replies, err := m.gather(ch)
+	if err != nil {
+		return nil, err
+	}
+	for _, reply := range replies {
+		for id, info := range reply.Clients {
+			result[id] = info.centrifuge()
+		}
+	}
+	return result, nil
+}
+
+// PresenceStats returns the number of clients and users subscribed to the channel on all nodes.
+func (m *PresenceManager) PresenceStats(ch string) (centrifuge.PresenceStats, error) {
+	presence, err := m.Presence(ch)
+	if err != nil {
+		return centrifuge.PresenceStats{}, err
+	}
+
+	users := make(map[string]struct{}, len(presence))
+	for _, info := range presence {
+		users[info.UserID] = struct{}{}
+	}
+	return centrifuge.PresenceStats{NumClients: len(presence), NumUsers: len(users)}, nil
+}
+
+// gather asks the other nodes for their presence and waits until the known nodes replied.
+func (m *PresenceManager) gather(ch string) ([]presenceReply, error) {
+	expected := m.peers.count()
+	if expected == 0 {
+		return nil, nil
+	}
+
+	request, err := json.Marshal(presenceRequest{NodeID: m.nodeID, Channel: ch})
+	if err != nil {
+		return nil, err
+	}
+
+	inbox := nats.NewInbox()
+	sub, err := m.nc.SubscribeSync(inbox)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = sub.Unsubscribe() }()
+
+	if err := m.nc.PublishRequest(m.subject(), inbox, request); err != nil {
+		return nil, err
+	}
+
+	replies := make([]presenceReply, 0, expected)
+	deadline := time.Now().Add(presenceTimeout)
+	for len(replies) < expected {
+		msg, err := sub.NextMsg(time.Until(deadline))
+		if err != nil {
+			// nodes that left since their last heartbeat don't reply
+			logger.Debug("Presence replies missing", "channel", ch, "expected", expected, "received", len(replies), "error", err)
+			break
+		}
+		var reply presenceReply
+		if err := json.Unmarshal(msg.Data, &reply); err != nil {
+			logger.Error("Failed to decode presence reply", "channel", ch, "error", err)
+			continue
+		}
+		replies = append(replies, reply)
+	}
+	return replies, nil
+}
+
+func (m *PresenceManager) handleRequest(msg *nats.Msg) {
+	var request presenceRequest
+	if err := json.Unmarshal(msg.Data, &request); err != nil {
+		logger.Error("Failed to decode presence request", "error", err)
+		return
+	}
+	if request.NodeID == m.nodeID {
+		return
+	}
+
+	local, err := m.MemoryPresenceManager.Presence(request.Channel)
+	if err != nil {
+		logger.Error("Failed to get presence", "channel", request.Channel, "error", err)
+		return
+	}
+
+	reply := presenceReply{Clients: make(map[string]*clientInfo, len(local))}
+	for id, info := range local {
+		reply.Clients[id] = toClientInfo(info)
+	}
+	data, err := json.Marshal(reply)
+	if err != nil {
+		logger.Error("Failed to encode presence reply", "channel", request.Channel, "error", err)
+		return
+	}
+	if err := msg.Respond(data); err != nil {
+		logger.Error("Failed to reply with presence", "channel", request.Channel, "error", err)
+	}
+}
+
+func (m *PresenceManager) subject() string {
+	return m.prefix + ".presence"
+}
@@ -40,6 +40,9 @@ type ConverterConfig struct {
 	ExactJsonConverterConfig  *ExactJsonConverterConfig  `json:"jsonExact,omitempty"`
 	AutoInfluxConverterConfig *AutoInfluxConverterConfig `json:"influxAuto,omitempty"`
 	JsonFrameConverterConfig  *JsonFrameConverterConfig  `json:"jsonFrame,omitempty"`
+
+	AutoOTLPConverterConfig       *AutoOTLPConverterConfig       `json:"otlpAuto,omitempty"`
+	AutoPrometheusConverterConfig *AutoPrometheusConverterConfig `json:"prometheusAuto,omitempty"`
 }
 
 type DropFieldsFrameProcessorConfig struct {
@@ -152,4 +155,16 @@ type AutoInfluxConverterConfig struct {
 
 type JsonFrameConverterConfig struct{}
 
+// AutoOTLPConverterConfig ...
+type AutoOTLPConverterConfig struct {
+	FrameFormat string `json:"frameFormat"`
+	// Encoding of the OTLP requests, protobuf (default) or json.
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// AutoPrometheusConverterConfig ...
+type AutoPrometheusConverterConfig struct {
+	FrameFormat string `json:"frameFormat"`
+}
+
 type ManagedStreamOutputConfig struct{}
@@ -0,0 +1,72 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/live/convert"
+)
+
+// AutoOTLPConverter decodes OTLP metrics export requests and transforms them
+// to several ChannelFrame objects where Channel is constructed from original
+// channel + / + <metric_name>.
+type AutoOTLPConverter struct {
+	config    AutoOTLPConverterConfig
+	converter *convert.Converter
+}
+
+// NewAutoOTLPConverter creates new AutoOTLPConverter.
+func NewAutoOTLPConverter(config AutoOTLPConverterConfig) *AutoOTLPConverter {
+	return &AutoOTLPConverter{config: config, converter: convert.NewConverter()}
+}
+
+const ConverterTypeOTLPAuto = "otlpAuto"
+
+func (c *AutoOTLPConverter) Type() string {
+	return ConverterTypeOTLPAuto
+}
+
+func (c *AutoOTLPConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	inputFormat := convert.InputFormatOTLP
+	if c.config.Encoding == "json" {
+		inputFormat = convert.InputFormatOTLPJSON
+	}
+	return convertMetrics(c.converter, vars, body, inputFormat, c.config.FrameFormat)
+}
+
+// AutoPrometheusConverter decodes Prometheus remote write requests and transforms
+// them to several ChannelFrame objects where Channel is constructed from original
+// channel + / + <metric_name>.
+type AutoPrometheusConverter struct {
+	config    AutoPrometheusConverterConfig
+	converter *convert.Converter
+}
+
+// NewAutoPrometheusConverter creates new AutoPrometheusConverter.
+func NewAutoPrometheusConverter(config AutoPrometheusConverterConfig) *AutoPrometheusConverter {
+	return &AutoPrometheusConverter{config: config, converter: convert.NewConverter()}
+}
+
+const ConverterTypePrometheusAuto = "prometheusAuto"
+
+func (c *AutoPrometheusConverter) Type() string {
+	return ConverterTypePrometheusAuto
+}
+
+func (c *AutoPrometheusConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	return convertMetrics(c.converter, vars, body, convert.InputFormatPrometheusRemoteWrite, c.config.FrameFormat)
+}
+
+func convertMetrics(converter *convert.Converter, vars Vars, body []byte, inputFormat string, frameFormat string) ([]*ChannelFrame, error) {
+	frameWrappers, err := converter.ConvertInput(body, inputFormat, frameFormat)
+	if err != nil {
+		return nil, err
+	}
+	channelFrames := make([]*ChannelFrame, 0, len(frameWrappers))
+	for _, fw := range frameWrappers {
+		channelFrames = append(channelFrames, &ChannelFrame{
+			Channel: vars.Channel + "/" + fw.Key(),
+			Frame:   fw.Frame(),
+		})
+	}
+	return channelFrames, nil
+}
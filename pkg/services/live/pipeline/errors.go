@@ -0,0 +1,27 @@
+package pipeline
+
+import "github.com/grafana/grafana/pkg/apimachinery/errutil"
+
+var (
+	ErrChannelRuleNotFound = errutil.NotFound("live.channelRuleNotFound", errutil.WithPublicMessage("Channel rule not found"))
+	ErrChannelRuleExists   = errutil.Conflict("live.channelRuleExists", errutil.WithPublicMessage("A channel rule with this pattern already exists"))
+	ErrWriteConfigNotFound = errutil.NotFound("live.writeConfigNotFound", errutil.WithPublicMessage("Write config not found"))
+	ErrWriteConfigExists   = errutil.Conflict("live.writeConfigExists", errutil.WithPublicMessage("A write config with this UID already exists"))
+
+	errInvalidChannelRuleBase = errutil.ValidationFailed("live.invalidChannelRule")
+	errInvalidWriteConfigBase = errutil.ValidationFailed("live.invalidWriteConfig")
+)
+
+// ErrInvalidChannelRule returns a validation error with the reason shown to the user.
+func ErrInvalidChannelRule(reason string) error {
+	err := errInvalidChannelRuleBase.Errorf("invalid channel rule: %s", reason)
+	err.PublicMessage = "Invalid channel rule: " + reason
+	return err
+}
+
+// ErrInvalidWriteConfig returns a validation error with the reason shown to the user.
+func ErrInvalidWriteConfig(reason string) error {
+	err := errInvalidWriteConfigBase.Errorf("invalid write config: %s", reason)
+	err.PublicMessage = "Invalid write config: " + reason
+	return err
+}
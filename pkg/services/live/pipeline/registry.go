@@ -66,6 +66,20 @@ var ConvertersRegistry = []EntityInfo{
 		Type:        ConverterTypeJsonFrame,
 		Description: "JSON-encoded Grafana data frame",
 	},
+	{
+		Type:        ConverterTypeOTLPAuto,
+		Description: "accept OTLP metrics",
+		Example: AutoOTLPConverterConfig{
+			FrameFormat: "labels_column",
+		},
+	},
+	{
+		Type:        ConverterTypePrometheusAuto,
+		Description: "accept Prometheus remote write requests",
+		Example: AutoPrometheusConverterConfig{
+			FrameFormat: "labels_column",
+		},
+	},
 }
 
 var FrameProcessorsRegistry = []EntityInfo{
@@ -59,6 +59,12 @@ func (s *CacheSegmentedTree) fillOrg(orgID int64) error {
 	return nil
 }
 
+// Reload rebuilds the rules of the organization, so changes apply without waiting
+// for the periodic update.
+func (s *CacheSegmentedTree) Reload(orgID int64) error {
+	return s.fillOrg(orgID)
+}
+
 func (s *CacheSegmentedTree) Get(orgID int64, channel string) (*LiveChannelRule, bool, error) {
 	s.radixMu.RLock()
 	_, ok := s.radix[orgID]
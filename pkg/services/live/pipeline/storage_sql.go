@@ -0,0 +1,322 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type channelRuleRecord struct {
+	ID       int64     `xorm:"pk autoincr 'id'"`
+	OrgID    int64     `xorm:"org_id"`
+	Pattern  string    `xorm:"pattern"`
+	Settings string    `xorm:"settings"`
+	Created  time.Time `xorm:"created"`
+	Updated  time.Time `xorm:"updated"`
+}
+
+func (channelRuleRecord) TableName() string { return "live_channel_rule" }
+
+func (r channelRuleRecord) toChannelRule() (ChannelRule, error) {
+	rule := ChannelRule{OrgId: r.OrgID, Pattern: r.Pattern}
+	if err := json.Unmarshal([]byte(r.Settings), &rule.Settings); err != nil {
+		return ChannelRule{}, fmt.Errorf("can't unmarshal settings of channel rule %s: %w", r.Pattern, err)
+	}
+	return rule, nil
+}
+
+type writeConfigRecord struct {
+	ID             int64     `xorm:"pk autoincr 'id'"`
+	OrgID          int64     `xorm:"org_id"`
+	UID            string    `xorm:"uid"`
+	Settings       string    `xorm:"settings"`
+	SecureSettings string    `xorm:"secure_settings"`
+	Created        time.Time `xorm:"created"`
+	Updated        time.Time `xorm:"updated"`
+}
+
+func (writeConfigRecord) TableName() string { return "live_write_config" }
+
+func (r writeConfigRecord) toWriteConfig() (WriteConfig, error) {
+	config := WriteConfig{OrgId: r.OrgID, UID: r.UID}
+	if err := json.Unmarshal([]byte(r.Settings), &config.Settings); err != nil {
+		return WriteConfig{}, fmt.Errorf("can't unmarshal settings of write config %s: %w", r.UID, err)
+	}
+	if r.SecureSettings != "" {
+		if err := json.Unmarshal([]byte(r.SecureSettings), &config.SecureSettings); err != nil {
+			return WriteConfig{}, fmt.Errorf("can't unmarshal secure settings of write config %s: %w", r.UID, err)
+		}
+	}
+	return config, nil
+}
+
+// SQLStorage keeps channel rules and write configs in the database, scoped by organization.
+type SQLStorage struct {
+	store          db.DB
+	secretsService secrets.Service
+}
+
+var _ Storage = (*SQLStorage)(nil)
+
+func NewSQLStorage(store db.DB, secretsService secrets.Service) *SQLStorage {
+	return &SQLStorage{store: store, secretsService: secretsService}
+}
+
+func (s *SQLStorage) ListChannelRules(ctx context.Context, orgID int64) ([]ChannelRule, error) {
+	var records []channelRuleRecord
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("pattern").Find(&records)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]ChannelRule, 0, len(records))
+	for _, record := range records {
+		rule, err := record.toChannelRule()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (s *SQLStorage) CreateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleCreateCmd) (ChannelRule, error) {
+	rule := ChannelRule{OrgId: orgID, Pattern: cmd.Pattern, Settings: cmd.Settings}
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing, err := getChannelRule(sess, orgID, rule.Pattern)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return ErrChannelRuleExists.Errorf("pattern already exists in org: %s", rule.Pattern)
+		}
+		return s.saveChannelRule(sess, rule, nil)
+	})
+	return rule, err
+}
+
+// UpdateChannelRule replaces the settings of the rule with the pattern, or creates it.
+func (s *SQLStorage) UpdateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleUpdateCmd) (ChannelRule, error) {
+	rule := ChannelRule{OrgId: orgID, Pattern: cmd.Pattern, Settings: cmd.Settings}
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing, err := getChannelRule(sess, orgID, rule.Pattern)
+		if err != nil {
+			return err
+		}
+		return s.saveChannelRule(sess, rule, existing)
+	})
+	return rule, err
+}
+
+func (s *SQLStorage) DeleteChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleDeleteCmd) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND pattern = ?", orgID, cmd.Pattern).Delete(&channelRuleRecord{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrChannelRuleNotFound.Errorf("rule not found: %s", cmd.Pattern)
+		}
+		return nil
+	})
+}
+
+// saveChannelRule validates the rule against the other rules of the organization before saving it.
+func (s *SQLStorage) saveChannelRule(sess *db.Session, rule ChannelRule, existing *channelRuleRecord) error {
+	if ok, reason := rule.Valid(); !ok {
+		return ErrInvalidChannelRule(reason)
+	}
+
+	var records []channelRuleRecord
+	if err := sess.Where("org_id = ?", rule.OrgId).Find(&records); err != nil {
+		return err
+	}
+	rules := []ChannelRule{rule}
+	for _, record := range records {
+		if record.Pattern != rule.Pattern {
+			rules = append(rules, ChannelRule{OrgId: record.OrgID, Pattern: record.Pattern})
+		}
+	}
+	if ok, reason := checkRulesValid(rule.OrgId, rules); !ok {
+		return ErrInvalidChannelRule(reason)
+	}
+
+	settings, err := json.Marshal(rule.Settings)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if existing != nil {
+		existing.Settings = string(settings)
+		existing.Updated = now
+		_, err = sess.ID(existing.ID).Cols("settings", "updated").Update(existing)
+		return err
+	}
+	_, err = sess.Insert(&channelRuleRecord{
+		OrgID:    rule.OrgId,
+		Pattern:  rule.Pattern,
+		Settings: string(settings),
+		Created:  now,
+		Updated:  now,
+	})
+	return err
+}
+
+func getChannelRule(sess *db.Session, orgID int64, pattern string) (*channelRuleRecord, error) {
+	var record channelRuleRecord
+	has, err := sess.Where("org_id = ? AND pattern = ?", orgID, pattern).Get(&record)
+	if err != nil || !has {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (s *SQLStorage) ListWriteConfigs(ctx context.Context, orgID int64) ([]WriteConfig, error) {
+	var records []writeConfigRecord
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("uid").Find(&records)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	configs := make([]WriteConfig, 0, len(records))
+	for _, record := range records {
+		config, err := record.toWriteConfig()
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, config)
+	}
+	return configs, nil
+}
+
+func (s *SQLStorage) GetWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigGetCmd) (WriteConfig, bool, error) {
+	var record *writeConfigRecord
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		record, err = getWriteConfig(sess, orgID, cmd.UID)
+		return err
+	})
+	if err != nil || record == nil {
+		return WriteConfig{}, false, err
+	}
+	config, err := record.toWriteConfig()
+	return config, err == nil, err
+}
+
+func (s *SQLStorage) CreateWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigCreateCmd) (WriteConfig, error) {
+	if cmd.UID == "" {
+		cmd.UID = util.GenerateShortUID()
+	}
+	config, err := s.newWriteConfig(ctx, orgID, cmd.UID, cmd.Settings, cmd.SecureSettings)
+	if err != nil {
+		return WriteConfig{}, err
+	}
+
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing, err := getWriteConfig(sess, orgID, config.UID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return ErrWriteConfigExists.Errorf("write config already exists in org: %s", config.UID)
+		}
+		return saveWriteConfig(sess, config, nil)
+	})
+	return config, err
+}
+
+// UpdateWriteConfig replaces the write config with the UID, or creates it.
+func (s *SQLStorage) UpdateWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigUpdateCmd) (WriteConfig, error) {
+	config, err := s.newWriteConfig(ctx, orgID, cmd.UID, cmd.Settings, cmd.SecureSettings)
+	if err != nil {
+		return WriteConfig{}, err
+	}
+
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing, err := getWriteConfig(sess, orgID, config.UID)
+		if err != nil {
+			return err
+		}
+		return saveWriteConfig(sess, config, existing)
+	})
+	return config, err
+}
+
+func (s *SQLStorage) DeleteWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigDeleteCmd) error {
+	return s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Delete(&writeConfigRecord{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrWriteConfigNotFound.Errorf("write config not found: %s", cmd.UID)
+		}
+		return nil
+	})
+}
+
+func (s *SQLStorage) newWriteConfig(ctx context.Context, orgID int64, uid string, settings WriteSettings, secureSettings map[string]string) (WriteConfig, error) {
+	encrypted, err := s.secretsService.EncryptJsonData(ctx, secureSettings, secrets.WithoutScope())
+	if err != nil {
+		return WriteConfig{}, fmt.Errorf("error encrypting data: %w", err)
+	}
+
+	config := WriteConfig{
+		OrgId:          orgID,
+		UID:            uid,
+		Settings:       settings,
+		SecureSettings: encrypted,
+	}
+	if ok, reason := config.Valid(); !ok {
+		return WriteConfig{}, ErrInvalidWriteConfig(reason)
+	}
+	return config, nil
+}
+
+func saveWriteConfig(sess *db.Session, config WriteConfig, existing *writeConfigRecord) error {
+	settings, err := json.Marshal(config.Settings)
+	if err != nil {
+		return err
+	}
+	secureSettings, err := json.Marshal(config.SecureSettings)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if existing != nil {
+		existing.Settings = string(settings)
+		existing.SecureSettings = string(secureSettings)
+		existing.Updated = now
+		_, err = sess.ID(existing.ID).Cols("settings", "secure_settings", "updated").Update(existing)
+		return err
+	}
+	_, err = sess.Insert(&writeConfigRecord{
+		OrgID:          config.OrgId,
+		UID:            config.UID,
+		Settings:       string(settings),
+		SecureSettings: string(secureSettings),
+		Created:        now,
+		Updated:        now,
+	})
+	return err
+}
+
+func getWriteConfig(sess *db.Session, orgID int64, uid string) (*writeConfigRecord, error) {
+	var record writeConfigRecord
+	has, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&record)
+	if err != nil || !has {
+		return nil, err
+	}
+	return &record, nil
+}
@@ -0,0 +1,103 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationSQLStorage_ChannelRules(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	storage := NewSQLStorage(db.InitTestDB(t), fakes.NewFakeSecretsService())
+
+	settings := ChannelRuleSettings{
+		Converter: &ConverterConfig{Type: ConverterTypeJsonAuto},
+	}
+
+	_, err := storage.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{Pattern: "stream/telegraf/cpu", Settings: settings})
+	require.NoError(t, err)
+	_, err = storage.CreateChannelRule(ctx, 2, ChannelRuleCreateCmd{Pattern: "stream/telegraf/cpu", Settings: settings})
+	require.NoError(t, err, "rules are scoped by organization")
+
+	_, err = storage.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{Pattern: "stream/telegraf/cpu", Settings: settings})
+	require.ErrorIs(t, err, ErrChannelRuleExists)
+
+	_, err = storage.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{
+		Pattern:  "stream/telegraf/mem",
+		Settings: ChannelRuleSettings{Converter: &ConverterConfig{Type: "unknown"}},
+	})
+	require.ErrorIs(t, err, errInvalidChannelRuleBase)
+
+	_, err = storage.UpdateChannelRule(ctx, 1, ChannelRuleUpdateCmd{Pattern: "stream/telegraf/cpu"})
+	require.NoError(t, err)
+
+	rules, err := storage.ListChannelRules(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, "stream/telegraf/cpu", rules[0].Pattern)
+	require.Nil(t, rules[0].Settings.Converter)
+
+	require.NoError(t, storage.DeleteChannelRule(ctx, 1, ChannelRuleDeleteCmd{Pattern: "stream/telegraf/cpu"}))
+	require.ErrorIs(t, storage.DeleteChannelRule(ctx, 1, ChannelRuleDeleteCmd{Pattern: "stream/telegraf/cpu"}), ErrChannelRuleNotFound)
+
+	rules, err = storage.ListChannelRules(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+}
+
+func TestIntegrationSQLStorage_WriteConfigs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	storage := NewSQLStorage(db.InitTestDB(t), fakes.NewFakeSecretsService())
+
+	created, err := storage.CreateWriteConfig(ctx, 1, WriteConfigCreateCmd{
+		Settings:       WriteSettings{Endpoint: "http://localhost:9090/api/v1/write"},
+		SecureSettings: map[string]string{"basicAuthPassword": "secret"},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, created.UID)
+
+	_, err = storage.CreateWriteConfig(ctx, 1, WriteConfigCreateCmd{UID: created.UID, Settings: created.Settings})
+	require.ErrorIs(t, err, ErrWriteConfigExists)
+
+	_, err = storage.CreateWriteConfig(ctx, 1, WriteConfigCreateCmd{UID: "no-endpoint"})
+	require.ErrorIs(t, err, errInvalidWriteConfigBase)
+
+	config, ok, err := storage.GetWriteConfig(ctx, 1, WriteConfigGetCmd{UID: created.UID})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, created.Settings, config.Settings)
+	require.Contains(t, config.SecureSettings, "basicAuthPassword")
+
+	_, ok, err = storage.GetWriteConfig(ctx, 2, WriteConfigGetCmd{UID: created.UID})
+	require.NoError(t, err)
+	require.False(t, ok, "write configs are scoped by organization")
+
+	_, err = storage.UpdateWriteConfig(ctx, 1, WriteConfigUpdateCmd{
+		UID:      created.UID,
+		Settings: WriteSettings{Endpoint: "http://localhost:9091/api/v1/write"},
+	})
+	require.NoError(t, err)
+
+	configs, err := storage.ListWriteConfigs(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	require.Equal(t, "http://localhost:9091/api/v1/write", configs[0].Settings.Endpoint)
+
+	require.NoError(t, storage.DeleteWriteConfig(ctx, 1, WriteConfigDeleteCmd{UID: created.UID}))
+	require.ErrorIs(t, storage.DeleteWriteConfig(ctx, 1, WriteConfigDeleteCmd{UID: created.UID}), ErrWriteConfigNotFound)
+}
@@ -36,6 +36,9 @@ func (s *MultipleSubscriber) Subscribe(ctx context.Context, vars Vars, data []by
 		if finalReply.Data == nil {
 			finalReply.Data = reply.Data
 		}
+		if finalReply.History == nil {
+			finalReply.History = reply.History
+		}
 		if !finalReply.JoinLeave {
 			finalReply.JoinLeave = reply.JoinLeave
 		}
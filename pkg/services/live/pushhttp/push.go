@@ -1,10 +1,12 @@
 package pushhttp
 
 import (
+	"compress/gzip"
 	"context"
 	"errors"
 	"io"
 	"net/http"
+	"strings"
 
 	liveDto "github.com/grafana/grafana-plugin-sdk-go/live"
 
@@ -58,8 +60,9 @@ func (g *Gateway) Handle(ctx *contextmodel.ReqContext) {
 	// TODO Grafana 8: decide which formats to use or keep all.
 	urlValues := ctx.Req.URL.Query()
 	frameFormat := pushurl.FrameFormatFromValues(urlValues)
+	inputFormat := pushurl.InputFormatFromRequest(urlValues, ctx.Req.Header)
 
-	body, err := io.ReadAll(ctx.Req.Body)
+	body, err := readBody(ctx.Req)
 	if err != nil {
 		logger.Error("Error reading body", "error", err)
 		ctx.Resp.WriteHeader(http.StatusInternalServerError)
@@ -69,13 +72,14 @@ func (g *Gateway) Handle(ctx *contextmodel.ReqContext) {
 		"protocol", "http",
 		"streamId", streamID,
 		"bodyLength", len(body),
+		"inputFormat", inputFormat,
 		"frameFormat", frameFormat,
 	)
 
-	metricFrames, err := g.converter.Convert(body, frameFormat)
+	metricFrames, err := g.converter.ConvertInput(body, inputFormat, frameFormat)
 	if err != nil {
-		logger.Error("Error converting metrics", "error", err, "frameFormat", frameFormat)
-		if errors.Is(err, convert.ErrUnsupportedFrameFormat) {
+		logger.Error("Error converting metrics", "error", err, "inputFormat", inputFormat, "frameFormat", frameFormat)
+		if errors.Is(err, convert.ErrUnsupportedFrameFormat) || errors.Is(err, convert.ErrUnsupportedInputFormat) {
 			ctx.Resp.WriteHeader(http.StatusBadRequest)
 		} else {
 			ctx.Resp.WriteHeader(http.StatusInternalServerError)
@@ -98,10 +102,23 @@ func (g *Gateway) Handle(ctx *contextmodel.ReqContext) {
 	ctx.Resp.WriteHeader(http.StatusOK)
 }
 
+// readBody reads the request body, decompressing it when gzip encoded as OTLP exporters usually do.
+func readBody(req *http.Request) ([]byte, error) {
+	if !strings.EqualFold(req.Header.Get("Content-Encoding"), "gzip") {
+		return io.ReadAll(req.Body)
+	}
+	reader, err := gzip.NewReader(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+	return io.ReadAll(reader)
+}
+
 func (g *Gateway) HandlePipelinePush(ctx *contextmodel.ReqContext) {
 	channelID := web.Params(ctx.Req)["*"]
 
-	body, err := io.ReadAll(ctx.Req.Body)
+	body, err := readBody(ctx.Req)
 	if err != nil {
 		logger.Error("Error reading body", "error", err)
 		ctx.Resp.WriteHeader(http.StatusInternalServerError)
@@ -1,12 +1,16 @@
 package pushurl
 
 import (
+	"net/http"
 	"net/url"
 	"strings"
+
+	"github.com/grafana/grafana/pkg/services/live/convert"
 )
 
 const (
 	frameFormatParam = "gf_live_frame_format"
+	inputFormatParam = "gf_live_input_format"
 )
 
 // FrameFormatFromValues extracts frame format tip from url values.
@@ -17,3 +21,26 @@ func FrameFormatFromValues(values url.Values) string {
 	}
 	return frameFormat
 }
+
+// InputFormatFromRequest extracts the format of the pushed data from url values,
+// or detects it from the request headers sent by OTLP and Prometheus remote
+// write clients. Defaults to Influx line protocol.
+func InputFormatFromRequest(values url.Values, header http.Header) string {
+	if inputFormat := strings.ToLower(values.Get(inputFormatParam)); inputFormat != "" {
+		return inputFormat
+	}
+	if header.Get("X-Prometheus-Remote-Write-Version") != "" {
+		return convert.InputFormatPrometheusRemoteWrite
+	}
+	contentType := strings.ToLower(header.Get("Content-Type"))
+	switch {
+	case strings.HasPrefix(contentType, "application/x-protobuf"):
+		if strings.EqualFold(header.Get("Content-Encoding"), "snappy") {
+			return convert.InputFormatPrometheusRemoteWrite
+		}
+		return convert.InputFormatOTLP
+	case strings.HasPrefix(contentType, "application/json"):
+		return convert.InputFormatOTLPJSON
+	}
+	return convert.InputFormatInflux
+}
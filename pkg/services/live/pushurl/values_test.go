@@ -1,10 +1,13 @@
 package pushurl
 
 import (
+	"net/http"
 	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/convert"
 )
 
 func TestFrameFormatFromValues(t *testing.T) {
@@ -13,3 +16,24 @@ func TestFrameFormatFromValues(t *testing.T) {
 	values.Set(frameFormatParam, "wide")
 	require.Equal(t, "wide", FrameFormatFromValues(values))
 }
+
+func TestInputFormatFromRequest(t *testing.T) {
+	testCases := []struct {
+		name     string
+		values   url.Values
+		header   http.Header
+		expected string
+	}{
+		{name: "influx by default", values: url.Values{}, header: http.Header{"Content-Type": {"text/plain"}}, expected: convert.InputFormatInflux},
+		{name: "remote write version header", values: url.Values{}, header: http.Header{"X-Prometheus-Remote-Write-Version": {"0.1.0"}}, expected: convert.InputFormatPrometheusRemoteWrite},
+		{name: "snappy protobuf", values: url.Values{}, header: http.Header{"Content-Type": {"application/x-protobuf"}, "Content-Encoding": {"snappy"}}, expected: convert.InputFormatPrometheusRemoteWrite},
+		{name: "otlp protobuf", values: url.Values{}, header: http.Header{"Content-Type": {"application/x-protobuf"}}, expected: convert.InputFormatOTLP},
+		{name: "otlp json", values: url.Values{}, header: http.Header{"Content-Type": {"application/json; charset=utf-8"}}, expected: convert.InputFormatOTLPJSON},
+		{name: "url value wins", values: url.Values{inputFormatParam: {"INFLUX"}}, header: http.Header{"Content-Type": {"application/json"}}, expected: convert.InputFormatInflux},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, InputFormatFromRequest(tc.values, tc.header))
+		})
+	}
+}
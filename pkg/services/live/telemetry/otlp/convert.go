@@ -0,0 +1,168 @@
+package otlp
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+)
+
+var _ telemetry.Converter = (*Converter)(nil)
+
+// Converter converts OTLP metrics export requests to Grafana frames.
+type Converter struct {
+	unmarshaler     pmetric.Unmarshaler
+	useLabelsColumn bool
+}
+
+// ConverterOption ...
+type ConverterOption func(*Converter)
+
+// WithUseLabelsColumn ...
+func WithUseLabelsColumn(enabled bool) ConverterOption {
+	return func(c *Converter) {
+		c.useLabelsColumn = enabled
+	}
+}
+
+// WithJSONEncoding accepts OTLP requests encoded in JSON instead of protobuf.
+func WithJSONEncoding(enabled bool) ConverterOption {
+	return func(c *Converter) {
+		if enabled {
+			c.unmarshaler = &pmetric.JSONUnmarshaler{}
+		}
+	}
+}
+
+// NewConverter creates new Converter from OTLP metrics to Grafana Data Frames.
+// Gauges and sums are converted to samples of the metric. Histograms and
+// summaries are converted to the _count and _sum samples of the metric, and
+// summary quantiles to samples with a quantile label, like Prometheus does.
+func NewConverter(opts ...ConverterOption) *Converter {
+	c := &Converter{unmarshaler: &pmetric.ProtoUnmarshaler{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Convert an OTLP metrics export request.
+func (c *Converter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	metrics, err := c.unmarshaler.UnmarshalMetrics(body)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing OTLP metrics: %w", err)
+	}
+
+	var samples []telemetry.Sample
+	resourceMetrics := metrics.ResourceMetrics()
+	for i := 0; i < resourceMetrics.Len(); i++ {
+		rm := resourceMetrics.At(i)
+		resourceLabels := attributesToLabels(rm.Resource().Attributes(), nil)
+		scopeMetrics := rm.ScopeMetrics()
+		for j := 0; j < scopeMetrics.Len(); j++ {
+			ms := scopeMetrics.At(j).Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				samples = appendMetricSamples(samples, ms.At(k), resourceLabels)
+			}
+		}
+	}
+	return telemetry.SamplesToFrames(samples, c.useLabelsColumn), nil
+}
+
+func appendMetricSamples(samples []telemetry.Sample, m pmetric.Metric, resourceLabels data.Labels) []telemetry.Sample {
+	name := m.Name()
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		return appendNumberSamples(samples, name, m.Gauge().DataPoints(), resourceLabels)
+	case pmetric.MetricTypeSum:
+		return appendNumberSamples(samples, name, m.Sum().DataPoints(), resourceLabels)
+	case pmetric.MetricTypeHistogram:
+		dps := m.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			samples = appendCountAndSum(samples, name, attributesToLabels(dp.Attributes(), resourceLabels), dp.Timestamp(), dp.Count(), dp.HasSum(), dp.Sum())
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := m.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			samples = appendCountAndSum(samples, name, attributesToLabels(dp.Attributes(), resourceLabels), dp.Timestamp(), dp.Count(), dp.HasSum(), dp.Sum())
+		}
+	case pmetric.MetricTypeSummary:
+		dps := m.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			labels := attributesToLabels(dp.Attributes(), resourceLabels)
+			samples = appendCountAndSum(samples, name, labels, dp.Timestamp(), dp.Count(), true, dp.Sum())
+			quantiles := dp.QuantileValues()
+			for q := 0; q < quantiles.Len(); q++ {
+				quantileLabels := labels.Copy()
+				quantileLabels["quantile"] = strconv.FormatFloat(quantiles.At(q).Quantile(), 'f', -1, 64)
+				samples = append(samples, telemetry.Sample{
+					Name:   name,
+					Labels: quantileLabels,
+					Time:   dp.Timestamp().AsTime(),
+					Value:  quantiles.At(q).Value(),
+				})
+			}
+		}
+	}
+	return samples
+}
+
+func appendNumberSamples(samples []telemetry.Sample, name string, dps pmetric.NumberDataPointSlice, resourceLabels data.Labels) []telemetry.Sample {
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		var value float64
+		switch dp.ValueType() {
+		case pmetric.NumberDataPointValueTypeInt:
+			value = float64(dp.IntValue())
+		case pmetric.NumberDataPointValueTypeDouble:
+			value = dp.DoubleValue()
+		default:
+			continue
+		}
+		samples = append(samples, telemetry.Sample{
+			Name:   name,
+			Labels: attributesToLabels(dp.Attributes(), resourceLabels),
+			Time:   dp.Timestamp().AsTime(),
+			Value:  value,
+		})
+	}
+	return samples
+}
+
+func appendCountAndSum(samples []telemetry.Sample, name string, labels data.Labels, ts pcommon.Timestamp, count uint64, hasSum bool, sum float64) []telemetry.Sample {
+	samples = append(samples, telemetry.Sample{
+		Name:   name + "_count",
+		Labels: labels,
+		Time:   ts.AsTime(),
+		Value:  float64(count),
+	})
+	if hasSum {
+		samples = append(samples, telemetry.Sample{
+			Name:   name + "_sum",
+			Labels: labels,
+			Time:   ts.AsTime(),
+			Value:  sum,
+		})
+	}
+	return samples
+}
+
+// attributesToLabels converts attributes to labels, on top of the resource labels.
+func attributesToLabels(attributes pcommon.Map, resourceLabels data.Labels) data.Labels {
+	labels := make(data.Labels, attributes.Len()+len(resourceLabels))
+	for k, v := range resourceLabels {
+		labels[k] = v
+	}
+	attributes.Range(func(k string, v pcommon.Value) bool {
+		labels[k] = v.AsString()
+		return true
+	})
+	return labels
+}
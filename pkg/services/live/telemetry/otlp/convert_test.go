@@ -0,0 +1,90 @@
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+var testTime = time.Unix(1700000000, 0).UTC()
+
+func testMetrics() pmetric.Metrics {
+	metrics := pmetric.NewMetrics()
+	rm := metrics.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "api")
+	ms := rm.ScopeMetrics().AppendEmpty().Metrics()
+
+	gauge := ms.AppendEmpty()
+	gauge.SetName("memory.usage")
+	dp := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(testTime))
+	dp.SetIntValue(42)
+	dp.Attributes().PutStr("host", "a")
+
+	sum := ms.AppendEmpty()
+	sum.SetName("requests")
+	dp = sum.SetEmptySum().DataPoints().AppendEmpty()
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(testTime))
+	dp.SetDoubleValue(1.5)
+
+	histogram := ms.AppendEmpty()
+	histogram.SetName("latency")
+	hdp := histogram.SetEmptyHistogram().DataPoints().AppendEmpty()
+	hdp.SetTimestamp(pcommon.NewTimestampFromTime(testTime))
+	hdp.SetCount(3)
+	hdp.SetSum(0.6)
+
+	summary := ms.AppendEmpty()
+	summary.SetName("duration")
+	sdp := summary.SetEmptySummary().DataPoints().AppendEmpty()
+	sdp.SetTimestamp(pcommon.NewTimestampFromTime(testTime))
+	sdp.SetCount(2)
+	sdp.SetSum(4)
+	q := sdp.QuantileValues().AppendEmpty()
+	q.SetQuantile(0.99)
+	q.SetValue(3)
+
+	return metrics
+}
+
+func TestConverter_Convert(t *testing.T) {
+	body, err := (&pmetric.ProtoMarshaler{}).MarshalMetrics(testMetrics())
+	require.NoError(t, err)
+
+	c := NewConverter(WithUseLabelsColumn(true))
+	frameWrappers, err := c.Convert(body)
+	require.NoError(t, err)
+
+	keys := make([]string, 0, len(frameWrappers))
+	for _, fw := range frameWrappers {
+		keys = append(keys, fw.Key())
+	}
+	require.Equal(t, []string{"memory.usage", "requests", "latency_count", "latency_sum", "duration_count", "duration_sum", "duration"}, keys)
+
+	frame := frameWrappers[0].Frame()
+	require.Equal(t, 1, frame.Rows())
+	require.Equal(t, data.Labels{"host": "a", "service.name": "api"}.String(), frame.Fields[0].At(0))
+	require.Equal(t, testTime, frame.Fields[1].At(0).(time.Time).UTC())
+	require.Equal(t, 42.0, frame.Fields[2].At(0))
+
+	require.Equal(t, 3.0, frameWrappers[2].Frame().Fields[2].At(0))
+	require.Equal(t, data.Labels{"quantile": "0.99", "service.name": "api"}.String(), frameWrappers[6].Frame().Fields[0].At(0))
+}
+
+func TestConverter_Convert_JSON(t *testing.T) {
+	body, err := (&pmetric.JSONMarshaler{}).MarshalMetrics(testMetrics())
+	require.NoError(t, err)
+
+	c := NewConverter(WithJSONEncoding(true))
+	frameWrappers, err := c.Convert(body)
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 7)
+
+	frame := frameWrappers[0].Frame()
+	require.Len(t, frame.Fields, 2)
+	require.Equal(t, data.Labels{"host": "a", "service.name": "api"}, frame.Fields[1].Labels)
+}
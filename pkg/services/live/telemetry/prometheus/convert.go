@@ -0,0 +1,81 @@
+package prometheus
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+)
+
+var _ telemetry.Converter = (*Converter)(nil)
+
+// Converter converts Prometheus remote write requests to Grafana frames.
+type Converter struct {
+	useLabelsColumn bool
+}
+
+// ConverterOption ...
+type ConverterOption func(*Converter)
+
+// WithUseLabelsColumn ...
+func WithUseLabelsColumn(enabled bool) ConverterOption {
+	return func(c *Converter) {
+		c.useLabelsColumn = enabled
+	}
+}
+
+// NewConverter creates new Converter from Prometheus remote write format to Grafana Data Frames.
+// This converter generates frames for each metric name, see telemetry.SamplesToFrames.
+func NewConverter(opts ...ConverterOption) *Converter {
+	c := &Converter{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Convert a snappy compressed remote write request.
+func (c *Converter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	decoded, err := snappy.Decode(nil, body)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing remote write request: %w", err)
+	}
+	var req prompb.WriteRequest
+	if err := req.Unmarshal(decoded); err != nil {
+		return nil, fmt.Errorf("error parsing remote write request: %w", err)
+	}
+
+	var samples []telemetry.Sample
+	for _, ts := range req.Timeseries {
+		name := ""
+		labels := make(data.Labels, len(ts.Labels))
+		for _, l := range ts.Labels {
+			if l.Name == "__name__" {
+				name = l.Value
+				continue
+			}
+			labels[l.Name] = l.Value
+		}
+		if name == "" {
+			continue
+		}
+		for _, s := range ts.Samples {
+			if math.IsNaN(s.Value) {
+				// stale markers
+				continue
+			}
+			samples = append(samples, telemetry.Sample{
+				Name:   name,
+				Labels: labels,
+				Time:   time.UnixMilli(s.Timestamp),
+				Value:  s.Value,
+			})
+		}
+	}
+	return telemetry.SamplesToFrames(samples, c.useLabelsColumn), nil
+}
@@ -0,0 +1,79 @@
+package prometheus
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+)
+
+func testWriteRequest(t *testing.T) []byte {
+	t.Helper()
+	req := prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "cpu_usage"}, {Name: "host", Value: "a"}},
+				Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}, {Value: math.NaN(), Timestamp: 2000}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "cpu_usage"}, {Name: "host", Value: "b"}},
+				Samples: []prompb.Sample{{Value: 2, Timestamp: 1000}},
+			},
+			{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "up"}},
+				Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}},
+			},
+			{
+				// series without name are skipped
+				Labels:  []prompb.Label{{Name: "job", Value: "x"}},
+				Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}},
+			},
+		},
+	}
+	b, err := req.Marshal()
+	require.NoError(t, err)
+	return snappy.Encode(nil, b)
+}
+
+func TestConverter_Convert_LabelsColumn(t *testing.T) {
+	c := NewConverter(WithUseLabelsColumn(true))
+	frameWrappers, err := c.Convert(testWriteRequest(t))
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 2)
+
+	require.Equal(t, "cpu_usage", frameWrappers[0].Key())
+	frame := frameWrappers[0].Frame()
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, `{host="a"}`, frame.Fields[0].At(0))
+	require.Equal(t, time.UnixMilli(1000), frame.Fields[1].At(0))
+	require.Equal(t, 1.0, frame.Fields[2].At(0))
+	require.Equal(t, `{host="b"}`, frame.Fields[0].At(1))
+	require.Equal(t, 2.0, frame.Fields[2].At(1))
+
+	require.Equal(t, "up", frameWrappers[1].Key())
+	require.Equal(t, 1, frameWrappers[1].Frame().Rows())
+}
+
+func TestConverter_Convert_Wide(t *testing.T) {
+	c := NewConverter()
+	frameWrappers, err := c.Convert(testWriteRequest(t))
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 2)
+
+	frame := frameWrappers[0].Frame()
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, 1, frame.Rows())
+	require.Equal(t, data.Labels{"host": "a"}, frame.Fields[1].Labels)
+	require.Equal(t, data.Labels{"host": "b"}, frame.Fields[2].Labels)
+	require.Equal(t, 2.0, frame.Fields[2].At(0))
+}
+
+func TestConverter_Convert_Invalid(t *testing.T) {
+	c := NewConverter()
+	_, err := c.Convert([]byte("cpu_usage,host=a value=1"))
+	require.Error(t, err)
+}
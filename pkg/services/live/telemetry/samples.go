@@ -0,0 +1,100 @@
+package telemetry
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Sample is a value of a metric series at a point in time, as sent by
+// metric protocols like OTLP or Prometheus remote write.
+type Sample struct {
+	Name   string
+	Labels data.Labels
+	Time   time.Time
+	Value  float64
+}
+
+var invalidKeyChars = regexp.MustCompile(`[^A-Za-z0-9_\-=.]`)
+
+// sampleFrame is a frame of samples with the same metric name.
+type sampleFrame struct {
+	key    string
+	name   string
+	fields []*data.Field
+	// index of the value field of each series in wide frames.
+	seriesIndex map[string]int
+}
+
+// Key returns a key which describes Frame metrics.
+func (f *sampleFrame) Key() string {
+	return f.key
+}
+
+// Frame allows getting data.Frame.
+func (f *sampleFrame) Frame() *data.Frame {
+	return data.NewFrame(f.name, f.fields...)
+}
+
+// SamplesToFrames converts samples to frames keyed by the metric name, the
+// same way Influx metrics are converted. With useLabelsColumn there is a frame
+// per metric with labels, time and value fields, otherwise a frame per metric
+// and time with a value field per series.
+func SamplesToFrames(samples []Sample, useLabelsColumn bool) []FrameWrapper {
+	// maintain the order of frames as they appear in input.
+	var frameKeyOrder []string
+	frames := make(map[string]*sampleFrame)
+
+	for _, s := range samples {
+		frameKey := s.Name
+		if !useLabelsColumn {
+			frameKey = s.Name + "_" + s.Time.String()
+		}
+		frame, ok := frames[frameKey]
+		if !ok {
+			frame = newSampleFrame(s, useLabelsColumn)
+			frames[frameKey] = frame
+			frameKeyOrder = append(frameKeyOrder, frameKey)
+		}
+		if useLabelsColumn {
+			frame.fields[0].Append(s.Labels.String())
+			frame.fields[1].Append(s.Time)
+			frame.fields[2].Append(s.Value)
+			continue
+		}
+		series := s.Labels.String()
+		if index, ok := frame.seriesIndex[series]; ok {
+			// the last sample of a series wins
+			frame.fields[index].Set(0, s.Value)
+			continue
+		}
+		field := data.NewField("value", s.Labels, []float64{s.Value})
+		frame.fields = append(frame.fields, field)
+		frame.seriesIndex[series] = len(frame.fields) - 1
+	}
+
+	frameWrappers := make([]FrameWrapper, 0, len(frames))
+	for _, key := range frameKeyOrder {
+		frameWrappers = append(frameWrappers, frames[key])
+	}
+	return frameWrappers
+}
+
+func newSampleFrame(s Sample, useLabelsColumn bool) *sampleFrame {
+	f := &sampleFrame{
+		key:  invalidKeyChars.ReplaceAllString(s.Name, "_"),
+		name: s.Name,
+	}
+	if useLabelsColumn {
+		f.fields = []*data.Field{
+			data.NewField("labels", nil, []string{}),
+			data.NewField("time", nil, []time.Time{}),
+			data.NewField("value", nil, []float64{}),
+		}
+		return f
+	}
+	f.fields = []*data.Field{data.NewField("time", nil, []time.Time{s.Time})}
+	f.seriesIndex = map[string]int{}
+	return f
+}
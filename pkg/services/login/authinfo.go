@@ -30,8 +30,11 @@ const (
 	LDAPAuthModule         = "ldap"
 	AuthProxyAuthModule    = "authproxy"
 	JWTModule              = "jwt"
+	MTLSAuthModule         = "mtls"
 	ExtendedJWTModule      = "extendedjwt"
 	RenderModule           = "render"
+	CapabilityTokenModule  = "capabilitytoken"
+	KioskTokenModule       = "kiosktoken"
 	// OAuth provider modules
 	AzureADAuthModule    = "oauth_azuread"
 	GoogleAuthModule     = "oauth_google"
@@ -46,6 +49,7 @@ const (
 	SAMLLabel = "SAML"
 	LDAPLabel = "LDAP"
 	JWTLabel  = "JWT"
+	MTLSLabel = "mTLS"
 	// OAuth provider labels
 	AuthProxyLabel    = "Auth Proxy"
 	AzureADLabel      = "AzureAD"
@@ -79,6 +83,8 @@ func GetAuthProviderLabel(authModule string) string {
 		return LDAPLabel
 	case JWTModule:
 		return JWTLabel
+	case MTLSAuthModule:
+		return MTLSLabel
 	case AuthProxyAuthModule:
 		return AuthProxyLabel
 	case GenericOAuthModule, strings.TrimPrefix(GenericOAuthModule, "oauth_"):
@@ -33,7 +33,7 @@ func ProvideService(authInfoStore login.Store,
 	s := &Service{
 		authInfoStore: authInfoStore,
 		logger:        log.New("login.authinfo"),
-		remoteCache:   remoteCache,
+		remoteCache:   remotecache.WithUsage(remoteCache, "auth info"),
 		secretService: secretService,
 	}
 
@@ -0,0 +1,74 @@
+package mfa
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+var (
+	ErrNotEnrolled = errutil.BadRequest(
+		"mfa.not-enrolled", errutil.WithPublicMessage("Multi-factor authentication is not set up"))
+	ErrAlreadyEnrolled = errutil.Conflict(
+		"mfa.already-enrolled", errutil.WithPublicMessage("Multi-factor authentication is already set up"))
+	ErrInvalidCode = errutil.BadRequest(
+		"mfa.invalid-code", errutil.WithPublicMessage("Invalid authentication code"))
+	ErrRequiredByPolicy = errutil.Forbidden(
+		"mfa.required-by-policy", errutil.WithPublicMessage("Multi-factor authentication is required for your account"))
+	// ErrCodeRequired is returned on login when the user has MFA set up but didn't provide an authentication code
+	ErrCodeRequired = errutil.Unauthorized(
+		"mfa.code-required", errutil.WithPublicMessage("Authentication code required"))
+	// ErrEnrollmentRequired is returned on login when MFA is required for the user but not set up yet.
+	// The public payload of the error contains the Enrollment the user has to confirm with their next login.
+	ErrEnrollmentRequired = errutil.Unauthorized(
+		"mfa.enrollment-required", errutil.WithPublicMessage("Multi-factor authentication must be set up for your account"))
+)
+
+type Service interface {
+	// GetStatus returns the MFA status of the user
+	GetStatus(ctx context.Context, userID int64) (*Status, error)
+	// Enroll generates a new secret and recovery codes for the user, which are only used once confirmed.
+	// Enrolling again before confirming keeps the secret but generates new recovery codes.
+	Enroll(ctx context.Context, userID int64, account string) (*Enrollment, error)
+	// Confirm enables MFA for the user if code is valid for the pending enrollment
+	Confirm(ctx context.Context, userID int64, code string) error
+	// Disable removes MFA for the user if code is valid and MFA isn't required for them
+	Disable(ctx context.Context, userID int64, code string) error
+	// RegenerateRecoveryCodes replaces the recovery codes of the user if code is valid
+	RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error)
+	// Reset removes MFA for the user without verification, so an admin can help users who lost their authenticator
+	Reset(ctx context.Context, userID int64) error
+	// IsRequired returns true if MFA is enforced for all users or required by any org the user is a member of
+	IsRequired(ctx context.Context, userID int64) (bool, error)
+	GetOrgPolicy(ctx context.Context, orgID int64) (*OrgPolicy, error)
+	SetOrgPolicy(ctx context.Context, policy *OrgPolicy) error
+}
+
+type Status struct {
+	// Enabled is true once the user confirmed their enrollment
+	Enabled bool `json:"enabled"`
+	// Pending is true if the user started an enrollment without confirming it
+	Pending bool `json:"pending"`
+	// Required is true if the user has to use MFA
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recoveryCodesRemaining"`
+}
+
+type Enrollment struct {
+	// Secret is the base32 encoded TOTP secret, for authenticator apps that can't scan URL
+	Secret string `json:"secret"`
+	// URL is the otpauth:// URL of the secret, usually shown as a QR code
+	URL           string   `json:"url"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type OrgPolicy struct {
+	OrgID int64 `json:"-"`
+	// Required requires MFA for all members of the organization using the built-in password login
+	Required bool `json:"required"`
+}
+
+type CodeCommand struct {
+	// Code is a TOTP code or, except when confirming an enrollment, a recovery code
+	Code string `json:"code" binding:"Required"`
+}
@@ -0,0 +1,397 @@
+package mfaimpl
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+var _ mfa.Service = (*Service)(nil)
+
+var (
+	errLoginInvalidCode = errutil.Unauthorized(
+		"mfa.login-invalid-code", errutil.WithPublicMessage("Invalid authentication code"))
+	errBasicAuthNotAllowed = errutil.Unauthorized(
+		"mfa.basic-auth-not-allowed",
+		errutil.WithPublicMessage("Basic authentication is not available for users that require multi-factor authentication"),
+	)
+)
+
+func ProvideService(
+	cfg *setting.Cfg, sqlStore db.DB, secretsService secrets.Service,
+	loginAttempts loginattempt.Service, authnService authn.Service, tracer trace.Tracer,
+) *Service {
+	s := &Service{
+		cfg:           cfg,
+		store:         &xormStore{db: sqlStore, now: time.Now},
+		secrets:       secretsService,
+		loginAttempts: loginAttempts,
+		log:           log.New("mfa"),
+		tracer:        tracer,
+		now:           time.Now,
+	}
+
+	if cfg.MFAAuth.Enabled {
+		authnService.RegisterPostAuthHook(s.verifyPasswordLoginHook, 25)
+	}
+
+	return s
+}
+
+type Service struct {
+	cfg           *setting.Cfg
+	store         store
+	secrets       secrets.Service
+	loginAttempts loginattempt.Service
+	log           log.Logger
+	tracer        trace.Tracer
+	now           func() time.Time
+}
+
+func (s *Service) GetStatus(ctx context.Context, userID int64) (*mfa.Status, error) {
+	ctx, span := s.tracer.Start(ctx, "mfa.GetStatus")
+	defer span.End()
+
+	required, err := s.IsRequired(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &mfa.Status{Required: required}
+	m, err := s.get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m != nil {
+		status.Enabled = m.Enabled
+		status.Pending = !m.Enabled
+		if m.Enabled {
+			status.RecoveryCodesRemaining = len(m.recoveryCodeHashes())
+		}
+	}
+
+	return status, nil
+}
+
+func (s *Service) Enroll(ctx context.Context, userID int64, account string) (*mfa.Enrollment, error) {
+	ctx, span := s.tracer.Start(ctx, "mfa.Enroll")
+	defer span.End()
+
+	m, err := s.get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m != nil && m.Enabled {
+		return nil, mfa.ErrAlreadyEnrolled.Errorf("user %d already has mfa enabled", userID)
+	}
+
+	var secret string
+	if m != nil {
+		secret, err = s.decryptSecret(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		secret, err = generateSecret()
+		if err != nil {
+			return nil, err
+		}
+		encrypted, err := s.secrets.Encrypt(ctx, []byte(secret), secrets.WithoutScope())
+		if err != nil {
+			return nil, err
+		}
+		m = &userMFA{UserID: userID, Secret: base64.StdEncoding.EncodeToString(encrypted)}
+	}
+
+	codes, err := s.newRecoveryCodes(m)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Save(ctx, m); err != nil {
+		return nil, err
+	}
+
+	return &mfa.Enrollment{
+		Secret:        secret,
+		URL:           otpauthURL(s.cfg.MFAAuth.Issuer, account, secret),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+func (s *Service) Confirm(ctx context.Context, userID int64, code string) error {
+	ctx, span := s.tracer.Start(ctx, "mfa.Confirm")
+	defer span.End()
+
+	m, err := s.get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if m == nil {
+		return mfa.ErrNotEnrolled.Errorf("user %d has no pending enrollment", userID)
+	}
+	if m.Enabled {
+		return mfa.ErrAlreadyEnrolled.Errorf("user %d already has mfa enabled", userID)
+	}
+
+	return s.confirm(ctx, m, code)
+}
+
+func (s *Service) Disable(ctx context.Context, userID int64, code string) error {
+	ctx, span := s.tracer.Start(ctx, "mfa.Disable")
+	defer span.End()
+
+	required, err := s.IsRequired(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return mfa.ErrRequiredByPolicy.Errorf("mfa is required for user %d", userID)
+	}
+
+	if _, err := s.verifyEnabled(ctx, userID, code); err != nil {
+		return err
+	}
+
+	return s.store.Delete(ctx, userID)
+}
+
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	ctx, span := s.tracer.Start(ctx, "mfa.RegenerateRecoveryCodes")
+	defer span.End()
+
+	m, err := s.verifyEnabled(ctx, userID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := s.newRecoveryCodes(m)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Save(ctx, m); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *Service) Reset(ctx context.Context, userID int64) error {
+	ctx, span := s.tracer.Start(ctx, "mfa.Reset")
+	defer span.End()
+
+	return s.store.Delete(ctx, userID)
+}
+
+func (s *Service) IsRequired(ctx context.Context, userID int64) (bool, error) {
+	if s.cfg.MFAAuth.Enforce {
+		return true, nil
+	}
+
+	count, err := s.store.CountRequiringOrgs(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (s *Service) GetOrgPolicy(ctx context.Context, orgID int64) (*mfa.OrgPolicy, error) {
+	policy, err := s.store.GetOrgPolicy(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return &mfa.OrgPolicy{OrgID: orgID, Required: policy.Required}, nil
+}
+
+func (s *Service) SetOrgPolicy(ctx context.Context, policy *mfa.OrgPolicy) error {
+	return s.store.SaveOrgPolicy(ctx, &orgMFAPolicy{OrgID: policy.OrgID, Required: policy.Required})
+}
+
+// verifyPasswordLoginHook requires users of the built-in password login to provide an authentication code
+// when they have MFA enabled, and to set it up on login when it is required for them.
+func (s *Service) verifyPasswordLoginHook(ctx context.Context, id *authn.Identity, r *authn.Request) error {
+	if id.AuthenticatedBy != login.PasswordAuthModule {
+		return nil
+	}
+
+	ctx, span := s.tracer.Start(ctx, "mfa.verifyPasswordLoginHook")
+	defer span.End()
+
+	userID, err := id.GetInternalID()
+	if err != nil {
+		return err
+	}
+
+	m, err := s.get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	enabled := m != nil && m.Enabled
+
+	required := enabled
+	if !enabled {
+		if required, err = s.IsRequired(ctx, userID); err != nil {
+			return err
+		}
+	}
+	if !required {
+		return nil
+	}
+
+	// Basic auth sends the password with every request, so there is no way to provide an authentication code
+	if r.GetMeta(authn.MetaKeyIsLogin) != "true" {
+		return errBasicAuthNotAllowed.Errorf("user %d requires mfa", userID)
+	}
+
+	code := strings.TrimSpace(r.GetMeta(authn.MetaKeyMFACode))
+	if enabled {
+		if code == "" {
+			return mfa.ErrCodeRequired.Errorf("missing authentication code for user %d", userID)
+		}
+		if err := s.verify(ctx, m, code); err != nil {
+			return s.failLogin(ctx, r, err)
+		}
+		return nil
+	}
+
+	if m != nil && code != "" {
+		if err := s.confirm(ctx, m, code); err != nil {
+			return s.failLogin(ctx, r, err)
+		}
+		s.log.FromContext(ctx).Info("User set up mfa on login", "userID", userID)
+		return nil
+	}
+
+	enrollment, err := s.Enroll(ctx, userID, r.GetMeta(authn.MetaKeyUsername))
+	if err != nil {
+		return err
+	}
+	enrollErr := mfa.ErrEnrollmentRequired.Errorf("user %d has to set up mfa", userID)
+	enrollErr.PublicPayload = map[string]any{
+		"secret":        enrollment.Secret,
+		"url":           enrollment.URL,
+		"recoveryCodes": enrollment.RecoveryCodes,
+	}
+	return enrollErr
+}
+
+// failLogin counts invalid authentication codes as failed login attempts, so they can't be brute forced
+func (s *Service) failLogin(ctx context.Context, r *authn.Request, err error) error {
+	if !errors.Is(err, mfa.ErrInvalidCode) {
+		return err
+	}
+	if addErr := s.loginAttempts.Add(ctx, r.GetMeta(authn.MetaKeyUsername), web.RemoteAddr(r.HTTPRequest)); addErr != nil {
+		return addErr
+	}
+	return errLoginInvalidCode.Errorf("invalid authentication code: %w", err)
+}
+
+func (s *Service) get(ctx context.Context, userID int64) (*userMFA, error) {
+	m, err := s.store.Get(ctx, userID)
+	if errors.Is(err, errNotFound) {
+		return nil, nil
+	}
+	return m, err
+}
+
+func (s *Service) verifyEnabled(ctx context.Context, userID int64, code string) (*userMFA, error) {
+	m, err := s.get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil || !m.Enabled {
+		return nil, mfa.ErrNotEnrolled.Errorf("user %d has no mfa enabled", userID)
+	}
+	if err := s.verify(ctx, m, code); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *Service) confirm(ctx context.Context, m *userMFA, code string) error {
+	secret, err := s.decryptSecret(ctx, m)
+	if err != nil {
+		return err
+	}
+
+	step, ok := validateTOTP(secret, strings.TrimSpace(code), s.now(), m.LastUsedStep)
+	if !ok {
+		return mfa.ErrInvalidCode.Errorf("invalid code for pending enrollment of user %d", m.UserID)
+	}
+
+	m.Enabled = true
+	m.LastUsedStep = step
+	return s.store.Save(ctx, m)
+}
+
+// verify accepts a TOTP code or one of the recovery codes, which is used up
+func (s *Service) verify(ctx context.Context, m *userMFA, code string) error {
+	code = strings.TrimSpace(code)
+
+	if isTOTPCode(code) {
+		secret, err := s.decryptSecret(ctx, m)
+		if err != nil {
+			return err
+		}
+		step, ok := validateTOTP(secret, code, s.now(), m.LastUsedStep)
+		if !ok {
+			return mfa.ErrInvalidCode.Errorf("invalid totp code for user %d", m.UserID)
+		}
+		m.LastUsedStep = step
+		return s.store.Save(ctx, m)
+	}
+
+	hashes := m.recoveryCodeHashes()
+	idx := slices.Index(hashes, hashRecoveryCode(code))
+	if idx < 0 {
+		return mfa.ErrInvalidCode.Errorf("invalid recovery code for user %d", m.UserID)
+	}
+
+	hashes = slices.Delete(hashes, idx, idx+1)
+	m.setRecoveryCodeHashes(hashes)
+	if err := s.store.Save(ctx, m); err != nil {
+		return err
+	}
+	s.log.FromContext(ctx).Info("User authenticated with recovery code", "userID", m.UserID, "remaining", len(hashes))
+	return nil
+}
+
+func (s *Service) newRecoveryCodes(m *userMFA) ([]string, error) {
+	codes, err := generateRecoveryCodes(s.cfg.MFAAuth.RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	m.setRecoveryCodeHashes(hashes)
+	return codes, nil
+}
+
+func (s *Service) decryptSecret(ctx context.Context, m *userMFA) (string, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(m.Secret)
+	if err != nil {
+		return "", err
+	}
+	secret, err := s.secrets.Decrypt(ctx, encrypted)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
@@ -0,0 +1,239 @@
+package mfaimpl
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	claims "github.com/grafana/authlib/types"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/loginattempt/loginattempttest"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService_Enrollment(t *testing.T) {
+	ctx := context.Background()
+	s, now := setupTestService(t, setting.AuthMFASettings{Enabled: true, Issuer: "Grafana", RecoveryCodeCount: 2})
+
+	enrollment, err := s.Enroll(ctx, 1, "admin")
+	require.NoError(t, err)
+	assert.Len(t, enrollment.RecoveryCodes, 2)
+
+	status, err := s.GetStatus(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, &mfa.Status{Pending: true}, status)
+
+	t.Run("should keep the secret when enrolling again", func(t *testing.T) {
+		again, err := s.Enroll(ctx, 1, "admin")
+		require.NoError(t, err)
+		assert.Equal(t, enrollment.Secret, again.Secret)
+		assert.NotEqual(t, enrollment.RecoveryCodes, again.RecoveryCodes)
+		enrollment = again
+	})
+
+	t.Run("should only confirm with a valid totp code", func(t *testing.T) {
+		assert.ErrorIs(t, s.Confirm(ctx, 1, "000000"), mfa.ErrInvalidCode)
+		assert.ErrorIs(t, s.Confirm(ctx, 1, enrollment.RecoveryCodes[0]), mfa.ErrInvalidCode)
+		require.NoError(t, s.Confirm(ctx, 1, codeAt(t, enrollment.Secret, now)))
+
+		status, err := s.GetStatus(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, &mfa.Status{Enabled: true, RecoveryCodesRemaining: 2}, status)
+
+		_, err = s.Enroll(ctx, 1, "admin")
+		assert.ErrorIs(t, err, mfa.ErrAlreadyEnrolled)
+	})
+
+	t.Run("should regenerate recovery codes", func(t *testing.T) {
+		codes, err := s.RegenerateRecoveryCodes(ctx, 1, enrollment.RecoveryCodes[0])
+		require.NoError(t, err)
+
+		_, err = s.RegenerateRecoveryCodes(ctx, 1, enrollment.RecoveryCodes[1])
+		assert.ErrorIs(t, err, mfa.ErrInvalidCode)
+		enrollment.RecoveryCodes = codes
+	})
+
+	t.Run("should not disable when required by an org", func(t *testing.T) {
+		s.store.(*fakeStore).requiringOrgs[1] = 1
+		t.Cleanup(func() { delete(s.store.(*fakeStore).requiringOrgs, 1) })
+
+		assert.ErrorIs(t, s.Disable(ctx, 1, enrollment.RecoveryCodes[0]), mfa.ErrRequiredByPolicy)
+	})
+
+	t.Run("should disable with a valid code", func(t *testing.T) {
+		require.NoError(t, s.Disable(ctx, 1, enrollment.RecoveryCodes[0]))
+
+		status, err := s.GetStatus(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, &mfa.Status{}, status)
+	})
+}
+
+func TestService_verifyPasswordLoginHook(t *testing.T) {
+	ctx := context.Background()
+
+	newLoginRequest := func(code string) *authn.Request {
+		r := &authn.Request{HTTPRequest: &http.Request{}}
+		r.SetMeta(authn.MetaKeyIsLogin, "true")
+		r.SetMeta(authn.MetaKeyUsername, "admin")
+		if code != "" {
+			r.SetMeta(authn.MetaKeyMFACode, code)
+		}
+		return r
+	}
+	passwordIdentity := &authn.Identity{ID: "1", Type: claims.TypeUser, AuthenticatedBy: login.PasswordAuthModule}
+
+	enrolledService := func(t *testing.T) (*Service, *mfa.Enrollment, time.Time) {
+		s, now := setupTestService(t, setting.AuthMFASettings{Enabled: true, Issuer: "Grafana", RecoveryCodeCount: 2})
+		enrollment, err := s.Enroll(ctx, 1, "admin")
+		require.NoError(t, err)
+		// confirm with the code of the previous step, so the current one can be used to log in
+		require.NoError(t, s.Confirm(ctx, 1, codeAt(t, enrollment.Secret, now.Add(-totpPeriod))))
+		return s, enrollment, now
+	}
+
+	t.Run("should skip identities not authenticated by password", func(t *testing.T) {
+		s, _, _ := enrolledService(t)
+		id := &authn.Identity{ID: "1", Type: claims.TypeUser, AuthenticatedBy: login.GenericOAuthModule}
+		assert.NoError(t, s.verifyPasswordLoginHook(ctx, id, newLoginRequest("")))
+	})
+
+	t.Run("should skip users without mfa", func(t *testing.T) {
+		s, _ := setupTestService(t, setting.AuthMFASettings{Enabled: true})
+		assert.NoError(t, s.verifyPasswordLoginHook(ctx, passwordIdentity, newLoginRequest("")))
+	})
+
+	t.Run("should require a code", func(t *testing.T) {
+		s, _, _ := enrolledService(t)
+		assert.ErrorIs(t, s.verifyPasswordLoginHook(ctx, passwordIdentity, newLoginRequest("")), mfa.ErrCodeRequired)
+	})
+
+	t.Run("should reject basic auth", func(t *testing.T) {
+		s, _, _ := enrolledService(t)
+		r := &authn.Request{HTTPRequest: &http.Request{}}
+		assert.ErrorIs(t, s.verifyPasswordLoginHook(ctx, passwordIdentity, r), errBasicAuthNotAllowed)
+	})
+
+	t.Run("should count invalid codes as failed login attempts", func(t *testing.T) {
+		s, _, _ := enrolledService(t)
+		attempts := s.loginAttempts.(*loginattempttest.MockLoginAttemptService)
+
+		err := s.verifyPasswordLoginHook(ctx, passwordIdentity, newLoginRequest("000000"))
+		assert.ErrorIs(t, err, errLoginInvalidCode)
+		assert.True(t, attempts.AddCalled)
+	})
+
+	t.Run("should accept a totp code once", func(t *testing.T) {
+		s, enrollment, now := enrolledService(t)
+		code := codeAt(t, enrollment.Secret, now)
+
+		assert.NoError(t, s.verifyPasswordLoginHook(ctx, passwordIdentity, newLoginRequest(code)))
+		assert.ErrorIs(t, s.verifyPasswordLoginHook(ctx, passwordIdentity, newLoginRequest(code)), errLoginInvalidCode)
+	})
+
+	t.Run("should accept a recovery code once", func(t *testing.T) {
+		s, enrollment, _ := enrolledService(t)
+		code := enrollment.RecoveryCodes[0]
+
+		assert.NoError(t, s.verifyPasswordLoginHook(ctx, passwordIdentity, newLoginRequest(code)))
+		assert.ErrorIs(t, s.verifyPasswordLoginHook(ctx, passwordIdentity, newLoginRequest(code)), errLoginInvalidCode)
+	})
+
+	t.Run("should set up mfa on login when required", func(t *testing.T) {
+		s, now := setupTestService(t, setting.AuthMFASettings{Enabled: true, Enforce: true, Issuer: "Grafana", RecoveryCodeCount: 2})
+
+		err := s.verifyPasswordLoginHook(ctx, passwordIdentity, newLoginRequest(""))
+		require.ErrorIs(t, err, mfa.ErrEnrollmentRequired)
+
+		var enrollErr errutil.Error
+		require.ErrorAs(t, err, &enrollErr)
+		secret, ok := enrollErr.PublicPayload["secret"].(string)
+		require.True(t, ok)
+		assert.Len(t, enrollErr.PublicPayload["recoveryCodes"], 2)
+
+		require.NoError(t, s.verifyPasswordLoginHook(ctx, passwordIdentity, newLoginRequest(codeAt(t, secret, now))))
+
+		status, err := s.GetStatus(ctx, 1)
+		require.NoError(t, err)
+		assert.True(t, status.Enabled)
+		assert.True(t, status.Required)
+	})
+}
+
+func setupTestService(t *testing.T, cfg setting.AuthMFASettings) (*Service, time.Time) {
+	t.Helper()
+
+	now := time.Now()
+	settings := setting.NewCfg()
+	settings.MFAAuth = cfg
+
+	return &Service{
+		cfg:           settings,
+		store:         &fakeStore{mfas: map[int64]*userMFA{}, policies: map[int64]*orgMFAPolicy{}, requiringOrgs: map[int64]int64{}},
+		secrets:       fakes.NewFakeSecretsService(),
+		loginAttempts: &loginattempttest.MockLoginAttemptService{},
+		log:           log.NewNopLogger(),
+		tracer:        tracing.InitializeTracerForTest(),
+		now:           func() time.Time { return now },
+	}, now
+}
+
+func codeAt(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+
+	key, err := secretEncoding.DecodeString(secret)
+	require.NoError(t, err)
+	return totpCode(key, totpStep(at))
+}
+
+type fakeStore struct {
+	mfas          map[int64]*userMFA
+	policies      map[int64]*orgMFAPolicy
+	requiringOrgs map[int64]int64
+}
+
+func (f *fakeStore) Get(ctx context.Context, userID int64) (*userMFA, error) {
+	m, ok := f.mfas[userID]
+	if !ok {
+		return nil, errNotFound
+	}
+	copied := *m
+	return &copied, nil
+}
+
+func (f *fakeStore) Save(ctx context.Context, m *userMFA) error {
+	copied := *m
+	f.mfas[m.UserID] = &copied
+	return nil
+}
+
+func (f *fakeStore) Delete(ctx context.Context, userID int64) error {
+	delete(f.mfas, userID)
+	return nil
+}
+
+func (f *fakeStore) GetOrgPolicy(ctx context.Context, orgID int64) (*orgMFAPolicy, error) {
+	if policy, ok := f.policies[orgID]; ok {
+		return policy, nil
+	}
+	return &orgMFAPolicy{OrgID: orgID}, nil
+}
+
+func (f *fakeStore) SaveOrgPolicy(ctx context.Context, policy *orgMFAPolicy) error {
+	f.policies[policy.OrgID] = policy
+	return nil
+}
+
+func (f *fakeStore) CountRequiringOrgs(ctx context.Context, userID int64) (int64, error) {
+	return f.requiringOrgs[userID], nil
+}
@@ -0,0 +1,153 @@
+package mfaimpl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+var errNotFound = errors.New("mfa not found")
+
+type userMFA struct {
+	ID     int64 `xorm:"pk autoincr 'id'"`
+	UserID int64 `xorm:"user_id"`
+	// Secret is the encrypted TOTP secret
+	Secret string `xorm:"secret"`
+	// RecoveryCodes is a JSON list of hashes of the unused recovery codes
+	RecoveryCodes string `xorm:"recovery_codes"`
+	Enabled       bool   `xorm:"enabled"`
+	// LastUsedStep is the time step of the last accepted TOTP code
+	LastUsedStep int64     `xorm:"last_used_step"`
+	Created      time.Time `xorm:"created"`
+	Updated      time.Time `xorm:"updated"`
+}
+
+func (userMFA) TableName() string {
+	return "user_mfa"
+}
+
+func (m *userMFA) recoveryCodeHashes() []string {
+	hashes := []string{}
+	if m.RecoveryCodes != "" {
+		_ = json.Unmarshal([]byte(m.RecoveryCodes), &hashes)
+	}
+	return hashes
+}
+
+func (m *userMFA) setRecoveryCodeHashes(hashes []string) {
+	data, _ := json.Marshal(hashes)
+	m.RecoveryCodes = string(data)
+}
+
+type orgMFAPolicy struct {
+	ID       int64     `xorm:"pk autoincr 'id'"`
+	OrgID    int64     `xorm:"org_id"`
+	Required bool      `xorm:"required"`
+	Updated  time.Time `xorm:"updated"`
+}
+
+func (orgMFAPolicy) TableName() string {
+	return "org_mfa_policy"
+}
+
+type store interface {
+	Get(ctx context.Context, userID int64) (*userMFA, error)
+	Save(ctx context.Context, m *userMFA) error
+	Delete(ctx context.Context, userID int64) error
+	GetOrgPolicy(ctx context.Context, orgID int64) (*orgMFAPolicy, error)
+	SaveOrgPolicy(ctx context.Context, policy *orgMFAPolicy) error
+	// CountRequiringOrgs returns the number of orgs the user is a member of that require MFA
+	CountRequiringOrgs(ctx context.Context, userID int64) (int64, error)
+}
+
+type xormStore struct {
+	db  db.DB
+	now func() time.Time
+}
+
+func (s *xormStore) Get(ctx context.Context, userID int64) (*userMFA, error) {
+	m := &userMFA{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("user_id = ?", userID).Get(m)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return errNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *xormStore) Save(ctx context.Context, m *userMFA) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		m.Updated = s.now()
+		if m.ID != 0 {
+			_, err := sess.ID(m.ID).AllCols().Update(m)
+			return err
+		}
+
+		m.Created = m.Updated
+		_, err := sess.Insert(m)
+		return err
+	})
+}
+
+func (s *xormStore) Delete(ctx context.Context, userID int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM user_mfa WHERE user_id = ?", userID)
+		return err
+	})
+}
+
+func (s *xormStore) GetOrgPolicy(ctx context.Context, orgID int64) (*orgMFAPolicy, error) {
+	policy := &orgMFAPolicy{OrgID: orgID}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("org_id = ?", orgID).Get(policy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (s *xormStore) SaveOrgPolicy(ctx context.Context, policy *orgMFAPolicy) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		policy.Updated = s.now()
+
+		existing := &orgMFAPolicy{}
+		has, err := sess.Where("org_id = ?", policy.OrgID).Get(existing)
+		if err != nil {
+			return err
+		}
+		if has {
+			policy.ID = existing.ID
+			_, err = sess.ID(policy.ID).AllCols().Update(policy)
+			return err
+		}
+
+		_, err = sess.Insert(policy)
+		return err
+	})
+}
+
+func (s *xormStore) CountRequiringOrgs(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		count, err = sess.Table("org_mfa_policy").
+			Join("INNER", "org_user", "org_user.org_id = org_mfa_policy.org_id").
+			Where("org_user.user_id = ? AND org_mfa_policy.required = ?", userID, s.db.GetDialect().BooleanValue(true)).
+			Count()
+		return err
+	})
+	return count, err
+}
@@ -0,0 +1,87 @@
+package mfaimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	sqlStore := db.InitTestDB(t)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	store := &xormStore{db: sqlStore, now: func() time.Time { return now }}
+
+	t.Run("should save, update and delete the MFA of a user", func(t *testing.T) {
+		_, err := store.Get(ctx, 1)
+		require.ErrorIs(t, err, errNotFound)
+
+		m := &userMFA{UserID: 1, Secret: "encrypted"}
+		m.setRecoveryCodeHashes([]string{"a", "b"})
+		require.NoError(t, store.Save(ctx, m))
+		assert.NotZero(t, m.ID)
+
+		m.Enabled = true
+		m.LastUsedStep = 42
+		m.setRecoveryCodeHashes([]string{"b"})
+		require.NoError(t, store.Save(ctx, m))
+
+		got, err := store.Get(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, m.ID, got.ID)
+		assert.True(t, got.Enabled)
+		assert.Equal(t, int64(42), got.LastUsedStep)
+		assert.Equal(t, []string{"b"}, got.recoveryCodeHashes())
+
+		require.NoError(t, store.Delete(ctx, 1))
+		_, err = store.Get(ctx, 1)
+		require.ErrorIs(t, err, errNotFound)
+	})
+
+	t.Run("should save the org policies and count the orgs requiring MFA", func(t *testing.T) {
+		policy, err := store.GetOrgPolicy(ctx, 1)
+		require.NoError(t, err)
+		assert.False(t, policy.Required, "MFA isn't required by default")
+
+		require.NoError(t, store.SaveOrgPolicy(ctx, &orgMFAPolicy{OrgID: 1, Required: true}))
+		require.NoError(t, store.SaveOrgPolicy(ctx, &orgMFAPolicy{OrgID: 2, Required: true}))
+		require.NoError(t, store.SaveOrgPolicy(ctx, &orgMFAPolicy{OrgID: 2, Required: false}))
+		require.NoError(t, store.SaveOrgPolicy(ctx, &orgMFAPolicy{OrgID: 3, Required: true}))
+
+		policy, err = store.GetOrgPolicy(ctx, 2)
+		require.NoError(t, err)
+		assert.False(t, policy.Required)
+
+		err = sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			for _, orgID := range []int64{1, 2} {
+				if _, err := sess.Insert(&org.OrgUser{OrgID: orgID, UserID: 5, Role: org.RoleViewer, Created: now, Updated: now}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		count, err := store.CountRequiringOrgs(ctx, 5)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count, "only the orgs of the user are counted")
+
+		count, err = store.CountRequiringOrgs(ctx, 6)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+}
@@ -0,0 +1,78 @@
+package mfaimpl
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// test vectors of RFC 6238, truncated to 6 digits
+	key := []byte("12345678901234567890")
+	tests := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, expected := range tests {
+		assert.Equal(t, expected, totpCode(key, totpStep(time.Unix(unix, 0))))
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := generateSecret()
+	require.NoError(t, err)
+	key, err := secretEncoding.DecodeString(secret)
+	require.NoError(t, err)
+
+	now := time.Now()
+	current := totpStep(now)
+
+	t.Run("should accept codes of the current and adjacent time steps", func(t *testing.T) {
+		for _, step := range []int64{current - 1, current, current + 1} {
+			got, ok := validateTOTP(secret, totpCode(key, step), now, 0)
+			assert.True(t, ok)
+			assert.Equal(t, step, got)
+		}
+	})
+
+	t.Run("should reject codes of other time steps", func(t *testing.T) {
+		_, ok := validateTOTP(secret, totpCode(key, current-2), now, 0)
+		assert.False(t, ok)
+		_, ok = validateTOTP(secret, totpCode(key, current+2), now, 0)
+		assert.False(t, ok)
+	})
+
+	t.Run("should reject codes that were already used", func(t *testing.T) {
+		_, ok := validateTOTP(secret, totpCode(key, current), now, current)
+		assert.False(t, ok)
+	})
+}
+
+func TestOtpauthURL(t *testing.T) {
+	u, err := url.Parse(otpauthURL("Grafana", "admin@example.com", "SECRET"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/Grafana:admin@example.com", u.Path)
+	assert.Equal(t, "SECRET", u.Query().Get("secret"))
+	assert.Equal(t, "Grafana", u.Query().Get("issuer"))
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := generateRecoveryCodes(5)
+	require.NoError(t, err)
+	require.Len(t, codes, 5)
+
+	for _, code := range codes {
+		assert.Len(t, code, recoveryCodeLength+1)
+		assert.False(t, isTOTPCode(code))
+	}
+	assert.Equal(t, hashRecoveryCode(codes[0]), hashRecoveryCode(" "+codes[0][:5]+codes[0][6:]+" "))
+}
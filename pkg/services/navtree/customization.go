@@ -0,0 +1,133 @@
+package navtree
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+const (
+	// CustomLinkIDPrefix prefixes the IDs of the custom links in the navigation tree
+	CustomLinkIDPrefix = "custom-"
+
+	maxCustomLinks = 50
+	maxCustomText  = 100
+
+	// customOrderWeight is lower than the weights of all the default nav links,
+	// so the ordered nav links are placed first among their siblings
+	customOrderWeight = WeightHome - 10000
+)
+
+var (
+	ErrHiddenAdministration = errutil.BadRequest(
+		"navtree.hidden-administration", errutil.WithPublicMessage("The administration section can't be hidden"))
+	ErrTooManyCustomLinks = errutil.BadRequest(
+		"navtree.too-many-custom-links", errutil.WithPublicMessage("At most 50 custom links are allowed"))
+	ErrInvalidCustomLink = errutil.BadRequest(
+		"navtree.invalid-custom-link", errutil.WithPublicMessage("Custom links require a unique lowercase id, a text of at most 100 characters, a Grafana path or an http(s) URL, and a target of _blank or _self"))
+
+	customLinkIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+)
+
+// Customization tailors the navigation tree of an organization. It's applied after the
+// plugins contributed their nav links, so plugin sections can be hidden and reordered too.
+type Customization struct {
+	// HiddenIDs are the IDs of the nav links removed from the tree, with their children
+	HiddenIDs []string `json:"hiddenIds"`
+	// Links are added to the tree
+	Links []CustomLink `json:"links"`
+	// Order lists nav link IDs which are placed first among their siblings, in the given order
+	Order []string `json:"order"`
+}
+
+type CustomLink struct {
+	// ID identifies the link, its nav link ID is prefixed by CustomLinkIDPrefix
+	ID   string `json:"id"`
+	Text string `json:"text"`
+	// URL is either a path of Grafana or an absolute http(s) URL
+	URL    string `json:"url"`
+	Icon   string `json:"icon,omitempty"`
+	Target string `json:"target,omitempty"`
+	// ParentID is the ID of the section the link is added to, the link is a section itself when empty
+	ParentID string `json:"parentId,omitempty"`
+}
+
+func (c *Customization) Validate() error {
+	for _, id := range c.HiddenIDs {
+		if id == NavIDCfg {
+			return ErrHiddenAdministration.Errorf("hiding %s is not allowed", id)
+		}
+	}
+
+	if len(c.Links) > maxCustomLinks {
+		return ErrTooManyCustomLinks.Errorf("at most %d custom links are allowed", maxCustomLinks)
+	}
+	ids := make(map[string]bool, len(c.Links))
+	for _, link := range c.Links {
+		if !customLinkIDPattern.MatchString(link.ID) {
+			return ErrInvalidCustomLink.Errorf("invalid custom link id %q", link.ID)
+		}
+		if ids[link.ID] {
+			return ErrInvalidCustomLink.Errorf("duplicate custom link id %q", link.ID)
+		}
+		ids[link.ID] = true
+
+		if text := strings.TrimSpace(link.Text); text == "" || len(text) > maxCustomText {
+			return ErrInvalidCustomLink.Errorf("custom link %q requires a text of at most %d characters", link.ID, maxCustomText)
+		}
+		if !isValidCustomURL(link.URL) {
+			return ErrInvalidCustomLink.Errorf("custom link %q requires a Grafana path or an http(s) URL", link.ID)
+		}
+		if link.Target != "" && link.Target != "_blank" && link.Target != "_self" {
+			return ErrInvalidCustomLink.Errorf("custom link %q has an invalid target %q", link.ID, link.Target)
+		}
+	}
+	return nil
+}
+
+func isValidCustomURL(u string) bool {
+	if strings.HasPrefix(u, "/") {
+		return !strings.HasPrefix(u, "//")
+	}
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// ApplyCustomization adds the custom links, then reorders and hides the nav links of the customization.
+// The custom links of a section which isn't in the tree, because it's hidden or the user can't access it, are omitted.
+func (root *NavTreeRoot) ApplyCustomization(c *Customization, appSubURL string) {
+	for _, link := range c.Links {
+		node := &NavLink{
+			Id:     CustomLinkIDPrefix + link.ID,
+			Text:   strings.TrimSpace(link.Text),
+			Url:    link.URL,
+			Icon:   link.Icon,
+			Target: link.Target,
+		}
+		if strings.HasPrefix(node.Url, "/") {
+			node.Url = appSubURL + node.Url
+		}
+
+		if link.ParentID == "" {
+			node.IsSection = true
+			root.AddSection(node)
+		} else if parent := root.FindById(link.ParentID); parent != nil {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+
+	for i, id := range c.Order {
+		if node := root.FindById(id); node != nil {
+			node.SortWeight = int64(customOrderWeight + i)
+		}
+	}
+
+	for _, id := range c.HiddenIDs {
+		root.RemoveSectionByID(id)
+	}
+}
@@ -0,0 +1,79 @@
+package navtree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomization_Validate(t *testing.T) {
+	valid := CustomLink{ID: "runbooks", Text: "Runbooks", URL: "https://runbooks.example.com"}
+
+	testCases := []struct {
+		name          string
+		customization Customization
+		expectedErr   error
+	}{
+		{name: "valid", customization: Customization{HiddenIDs: []string{NavIDExplore}, Links: []CustomLink{valid, {ID: "home-2", Text: "Team home", URL: "/d/team"}}}},
+		{name: "hidden administration", customization: Customization{HiddenIDs: []string{NavIDCfg}}, expectedErr: ErrHiddenAdministration},
+		{name: "duplicate id", customization: Customization{Links: []CustomLink{valid, valid}}, expectedErr: ErrInvalidCustomLink},
+		{name: "invalid id", customization: Customization{Links: []CustomLink{{ID: "Run books", Text: "Runbooks", URL: "/d/a"}}}, expectedErr: ErrInvalidCustomLink},
+		{name: "empty text", customization: Customization{Links: []CustomLink{{ID: "a", Text: " ", URL: "/d/a"}}}, expectedErr: ErrInvalidCustomLink},
+		{name: "javascript url", customization: Customization{Links: []CustomLink{{ID: "a", Text: "A", URL: "javascript:alert(1)"}}}, expectedErr: ErrInvalidCustomLink},
+		{name: "protocol relative url", customization: Customization{Links: []CustomLink{{ID: "a", Text: "A", URL: "//example.com"}}}, expectedErr: ErrInvalidCustomLink},
+		{name: "invalid target", customization: Customization{Links: []CustomLink{{ID: "a", Text: "A", URL: "/d/a", Target: "_parent"}}}, expectedErr: ErrInvalidCustomLink},
+		{name: "too many links", customization: Customization{Links: make([]CustomLink, maxCustomLinks+1)}, expectedErr: ErrTooManyCustomLinks},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.customization.Validate()
+			if tc.expectedErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestNavTreeRoot_ApplyCustomization(t *testing.T) {
+	treeRoot := NavTreeRoot{
+		Children: []*NavLink{
+			{Id: "home", SortWeight: WeightHome},
+			{Id: NavIDDashboards, SortWeight: WeightDashboard},
+			{Id: NavIDExplore, SortWeight: WeightExplore},
+			{Id: "plugin-page-grafana-k6-app", SortWeight: WeightPlugin, Children: []*NavLink{{Id: "k6-tests"}}},
+			{Id: NavIDCfg, SortWeight: WeightConfig, Children: []*NavLink{{Id: NavIDCfgGeneral}}},
+		},
+	}
+
+	treeRoot.ApplyCustomization(&Customization{
+		HiddenIDs: []string{NavIDExplore, "k6-tests"},
+		Links: []CustomLink{
+			{ID: "runbooks", Text: "Runbooks", URL: "https://runbooks.example.com", Target: "_blank"},
+			{ID: "team", Text: "Team dashboard", URL: "/d/team", ParentID: NavIDDashboards},
+			{ID: "hidden", Text: "Hidden", URL: "/explore", ParentID: "unknown"},
+		},
+		Order: []string{NavIDDashboards, "custom-runbooks"},
+	}, "/grafana")
+	treeRoot.Sort()
+
+	ids := make([]string, 0, len(treeRoot.Children))
+	for _, node := range treeRoot.Children {
+		ids = append(ids, node.Id)
+	}
+	assert.Equal(t, []string{NavIDDashboards, "custom-runbooks", "home", "plugin-page-grafana-k6-app", NavIDCfg}, ids)
+
+	runbooks := treeRoot.FindById("custom-runbooks")
+	assert.True(t, runbooks.IsSection)
+	assert.Equal(t, "_blank", runbooks.Target)
+
+	team := treeRoot.FindById("custom-team")
+	require.NotNil(t, team)
+	assert.Equal(t, "/grafana/d/team", team.Url)
+
+	assert.Nil(t, treeRoot.FindById("custom-hidden"))
+	assert.Nil(t, treeRoot.FindById("k6-tests"))
+}
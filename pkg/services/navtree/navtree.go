@@ -1,10 +1,17 @@
 package navtree
 
 import (
+	"context"
+
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	pref "github.com/grafana/grafana/pkg/services/preference"
 )
 
 type Service interface {
 	GetNavTree(c *contextmodel.ReqContext, prefs *pref.Preference) (*NavTreeRoot, error)
+	// GetCustomization returns the customization of the navigation tree of the organization, empty when it isn't customized
+	GetCustomization(ctx context.Context, orgID int64) (*Customization, error)
+	UpdateCustomization(ctx context.Context, orgID int64, customization *Customization) error
+	// DeleteCustomization restores the default navigation tree of the organization
+	DeleteCustomization(ctx context.Context, orgID int64) error
 }
@@ -0,0 +1,43 @@
+package navtreeimpl
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/navtree"
+)
+
+const (
+	customizationNamespace = "navtree"
+	customizationKey       = "customization"
+)
+
+func (s *ServiceImpl) GetCustomization(ctx context.Context, orgID int64) (*navtree.Customization, error) {
+	customization := &navtree.Customization{HiddenIDs: []string{}, Links: []navtree.CustomLink{}, Order: []string{}}
+
+	value, ok, err := kvstore.WithNamespace(s.kvStore, orgID, customizationNamespace).Get(ctx, customizationKey)
+	if err != nil || !ok {
+		return customization, err
+	}
+	if err := json.Unmarshal([]byte(value), customization); err != nil {
+		return nil, err
+	}
+	return customization, nil
+}
+
+func (s *ServiceImpl) UpdateCustomization(ctx context.Context, orgID int64, customization *navtree.Customization) error {
+	if err := customization.Validate(); err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(customization)
+	if err != nil {
+		return err
+	}
+	return kvstore.WithNamespace(s.kvStore, orgID, customizationNamespace).Set(ctx, customizationKey, string(value))
+}
+
+func (s *ServiceImpl) DeleteCustomization(ctx context.Context, orgID int64) error {
+	return kvstore.WithNamespace(s.kvStore, orgID, customizationNamespace).Del(ctx, customizationKey)
+}
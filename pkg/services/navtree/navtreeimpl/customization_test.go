@@ -0,0 +1,54 @@
+package navtreeimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/navtree"
+)
+
+func TestServiceImpl_Customization(t *testing.T) {
+	ctx := context.Background()
+	service := ServiceImpl{kvStore: kvstore.NewFakeKVStore()}
+
+	t.Run("should return an empty customization by default", func(t *testing.T) {
+		customization, err := service.GetCustomization(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, customization.HiddenIDs)
+		assert.Empty(t, customization.Links)
+	})
+
+	t.Run("should save the customization of the org", func(t *testing.T) {
+		err := service.UpdateCustomization(ctx, 1, &navtree.Customization{
+			HiddenIDs: []string{navtree.NavIDExplore},
+			Links:     []navtree.CustomLink{{ID: "runbooks", Text: "Runbooks", URL: "https://runbooks.example.com"}},
+		})
+		require.NoError(t, err)
+
+		customization, err := service.GetCustomization(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{navtree.NavIDExplore}, customization.HiddenIDs)
+		require.Len(t, customization.Links, 1)
+
+		other, err := service.GetCustomization(ctx, 2)
+		require.NoError(t, err)
+		assert.Empty(t, other.HiddenIDs)
+	})
+
+	t.Run("should reject an invalid customization", func(t *testing.T) {
+		err := service.UpdateCustomization(ctx, 1, &navtree.Customization{HiddenIDs: []string{navtree.NavIDCfg}})
+		require.ErrorIs(t, err, navtree.ErrHiddenAdministration)
+	})
+
+	t.Run("should restore the default navigation", func(t *testing.T) {
+		require.NoError(t, service.DeleteCustomization(ctx, 1))
+
+		customization, err := service.GetCustomization(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, customization.HiddenIDs)
+	})
+}
@@ -200,6 +200,12 @@ func (s *ServiceImpl) GetNavTree(c *contextmodel.ReqContext, prefs *pref.Prefere
 		})
 	}
 
+	customization, err := s.GetCustomization(ctx, c.GetOrgID())
+	if err != nil {
+		return nil, err
+	}
+	treeRoot.ApplyCustomization(customization, s.cfg.AppSubURL)
+
 	return treeRoot, nil
 }
 
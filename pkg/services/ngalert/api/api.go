@@ -25,7 +25,9 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/useractivity"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -81,6 +83,8 @@ type API struct {
 	Tracer               tracing.Tracer
 	AppUrl               *url.URL
 	UserService          user.Service
+	UserActivity         useractivity.Service
+	StarService          star.Service
 
 	// Hooks can be used to replace API handlers for specific paths.
 	Hooks *Hooks
@@ -115,6 +119,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			ac:             api.AccessControl,
 			mam:            api.MultiOrgAlertmanager,
 			featureManager: api.FeatureManager,
+			userActivity:   api.UserActivity,
 			silenceSvc: notifier.NewSilenceService(
 				accesscontrol.NewSilenceService(api.AccessControl, api.RuleStore),
 				api.TransactionManager,
@@ -151,6 +156,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			amRefresher:        api.MultiOrgAlertmanager,
 			featureManager:     api.FeatureManager,
 			userService:        api.UserService,
+			starService:        api.StarService,
 		},
 	), m)
 	api.RegisterTestingApiEndpoints(NewTestingApi(
@@ -22,6 +22,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier/legacy_storage"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/useractivity"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -42,6 +43,7 @@ type AlertmanagerSrv struct {
 	silenceSvc     SilenceService
 	featureManager featuremgmt.FeatureToggles
 	receiverAuthz  receiversAuthz
+	userActivity   useractivity.Service
 }
 
 type UnknownReceiverError struct {
@@ -3,6 +3,8 @@ package api
 import (
 	"context"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/go-openapi/strfmt"
 
@@ -11,6 +13,7 @@ import (
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/useractivity"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -86,6 +89,16 @@ func (srv AlertmanagerSrv) RouteCreateSilence(c *contextmodel.ReqContext, postab
 		return response.ErrOrFallback(http.StatusInternalServerError, "failed to create/update silence", err)
 	}
 
+	// a new silence acknowledges the alerts it matches
+	if postableSilence.ID == "" {
+		useractivity.RecordRequest(srv.userActivity, c, &useractivity.Activity{
+			Type:        useractivity.TypeAlertAck,
+			ResourceUID: silenceID,
+			Title:       silenceMatchers(postableSilence),
+			URL:         "/alerting/silence/" + silenceID + "/edit",
+		})
+	}
+
 	return response.JSON(http.StatusAccepted, apimodels.PostSilencesOKBody{
 		SilenceID: silenceID,
 	})
@@ -110,3 +123,26 @@ func withEmptyMetadata(silences ...*models.Silence) []*models.SilenceWithMetadat
 	}
 	return silencesWithMetadata
 }
+
+// silenceMatchers returns the matchers of the silence, such as alertname="HighCPU", severity=~"warning|critical"
+func silenceMatchers(silence apimodels.PostableSilence) string {
+	matchers := make([]string, 0, len(silence.Matchers))
+	for _, matcher := range silence.Matchers {
+		if matcher == nil || matcher.Name == nil || matcher.Value == nil {
+			continue
+		}
+		isRegex := matcher.IsRegex != nil && *matcher.IsRegex
+		isEqual := matcher.IsEqual == nil || *matcher.IsEqual
+		operator := "="
+		switch {
+		case isRegex && isEqual:
+			operator = "=~"
+		case isRegex:
+			operator = "!~"
+		case !isEqual:
+			operator = "!="
+		}
+		matchers = append(matchers, *matcher.Name+operator+strconv.Quote(*matcher.Value))
+	}
+	return strings.Join(matchers, ", ")
+}
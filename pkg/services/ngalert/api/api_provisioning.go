@@ -23,6 +23,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/etag"
 )
 
 const disableProvenanceHeaderName = "X-Disable-Provenance"
@@ -84,7 +85,7 @@ type AlertRuleService interface {
 }
 
 func (srv *ProvisioningSrv) RouteGetPolicyTree(c *contextmodel.ReqContext) response.Response {
-	policies, _, err := srv.policies.GetPolicyTree(c.Req.Context(), c.GetOrgID())
+	policies, version, err := srv.policies.GetPolicyTree(c.Req.Context(), c.GetOrgID())
 	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
 		return ErrResp(http.StatusNotFound, err, "")
 	}
@@ -92,7 +93,7 @@ func (srv *ProvisioningSrv) RouteGetPolicyTree(c *contextmodel.ReqContext) respo
 		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get notification policy tree", err)
 	}
 
-	return response.JSON(http.StatusOK, policies)
+	return response.JSON(http.StatusOK, policies).SetHeader(etag.Header, etag.FromString(version))
 }
 
 func (srv *ProvisioningSrv) RouteGetPolicyTreeExport(c *contextmodel.ReqContext) response.Response {
@@ -113,8 +114,12 @@ func (srv *ProvisioningSrv) RouteGetPolicyTreeExport(c *contextmodel.ReqContext)
 }
 
 func (srv *ProvisioningSrv) RoutePutPolicyTree(c *contextmodel.ReqContext, tree definitions.Route) response.Response {
+	version, rsp := srv.checkPolicyTreeIfMatch(c)
+	if rsp != nil {
+		return rsp
+	}
 	provenance := determineProvenance(c)
-	_, _, err := srv.policies.UpdatePolicyTree(c.Req.Context(), c.GetOrgID(), tree, alerting_models.Provenance(provenance), "")
+	_, version, err := srv.policies.UpdatePolicyTree(c.Req.Context(), c.GetOrgID(), tree, alerting_models.Provenance(provenance), version)
 	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
 		return ErrResp(http.StatusNotFound, err, "")
 	}
@@ -122,13 +127,35 @@ func (srv *ProvisioningSrv) RoutePutPolicyTree(c *contextmodel.ReqContext, tree
 		return ErrResp(http.StatusBadRequest, err, "")
 	}
 	if err != nil {
-		return response.ErrOrFallback(http.StatusInternalServerError, "failed to update notification policy tree", err)
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to update notification policy tree", ifMatchConflict(c, err))
 	}
 
-	return response.JSON(http.StatusAccepted, util.DynMap{"message": "policies updated"})
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "policies updated"}).SetHeader(etag.Header, etag.FromString(version))
+}
+
+// checkPolicyTreeIfMatch checks the If-Match header of the request against the version of the policy tree, and
+// returns the version to update when the request has one
+func (srv *ProvisioningSrv) checkPolicyTreeIfMatch(c *contextmodel.ReqContext) (string, response.Response) {
+	if etag.IfMatch(c.Req) == nil {
+		return "", nil
+	}
+	_, version, err := srv.policies.GetPolicyTree(c.Req.Context(), c.GetOrgID())
+	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+		return "", ErrResp(http.StatusNotFound, err, "")
+	}
+	if err != nil {
+		return "", response.ErrOrFallback(http.StatusInternalServerError, "failed to get notification policy tree", err)
+	}
+	if err := etag.Check(c.Req, etag.FromString(version)); err != nil {
+		return "", response.Err(err)
+	}
+	return version, nil
 }
 
 func (srv *ProvisioningSrv) RouteResetPolicyTree(c *contextmodel.ReqContext) response.Response {
+	if _, rsp := srv.checkPolicyTreeIfMatch(c); rsp != nil {
+		return rsp
+	}
 	provenance := determineProvenance(c)
 	tree, err := srv.policies.ResetPolicyTree(c.Req.Context(), c.GetOrgID(), alerting_models.Provenance(provenance))
 	if err != nil {
@@ -218,7 +245,7 @@ func (srv *ProvisioningSrv) RouteGetTemplate(c *contextmodel.ReqContext, nameOrU
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
 	}
-	return response.JSON(http.StatusOK, template)
+	return response.JSON(http.StatusOK, template).SetHeader(etag.Header, etag.FromString(template.ResourceVersion))
 }
 
 func (srv *ProvisioningSrv) RoutePutTemplate(c *contextmodel.ReqContext, body definitions.NotificationTemplateContent, name string) response.Response {
@@ -228,28 +255,57 @@ func (srv *ProvisioningSrv) RoutePutTemplate(c *contextmodel.ReqContext, body de
 		Provenance:      determineProvenance(c),
 		ResourceVersion: body.ResourceVersion,
 	}
+	if etag.IfMatch(c.Req) != nil {
+		current, rsp := srv.checkTemplateIfMatch(c, name)
+		if rsp != nil {
+			return rsp
+		}
+		tmpl.ResourceVersion = current.ResourceVersion
+	}
 	modified, err := srv.templates.UpsertTemplate(c.Req.Context(), c.GetOrgID(), tmpl)
 	if err != nil {
-		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
+		return response.ErrOrFallback(http.StatusInternalServerError, "", ifMatchConflict(c, err))
 	}
-	return response.JSON(http.StatusAccepted, modified)
+	return response.JSON(http.StatusAccepted, modified).SetHeader(etag.Header, etag.FromString(modified.ResourceVersion))
 }
 
 func (srv *ProvisioningSrv) RouteDeleteTemplate(c *contextmodel.ReqContext, nameOrUid string) response.Response {
 	version := c.Query("version")
+	if etag.IfMatch(c.Req) != nil {
+		current, rsp := srv.checkTemplateIfMatch(c, nameOrUid)
+		if rsp != nil {
+			return rsp
+		}
+		version = current.ResourceVersion
+	}
 	err := srv.templates.DeleteTemplate(c.Req.Context(), c.GetOrgID(), nameOrUid, determineProvenance(c), version)
 	if err != nil {
-		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
+		return response.ErrOrFallback(http.StatusInternalServerError, "", ifMatchConflict(c, err))
 	}
 	return response.JSON(http.StatusNoContent, nil)
 }
 
+// checkTemplateIfMatch checks the If-Match header of the request against the version of the template
+func (srv *ProvisioningSrv) checkTemplateIfMatch(c *contextmodel.ReqContext, nameOrUid string) (definitions.NotificationTemplate, response.Response) {
+	current, err := srv.templates.GetTemplate(c.Req.Context(), c.GetOrgID(), nameOrUid)
+	if errors.Is(err, provisioning.ErrTemplateNotFound) {
+		return definitions.NotificationTemplate{}, response.Err(etag.ErrPreconditionFailed.Errorf("template not found: %w", err))
+	}
+	if err != nil {
+		return definitions.NotificationTemplate{}, response.ErrOrFallback(http.StatusInternalServerError, "", err)
+	}
+	if err := etag.Check(c.Req, etag.FromString(current.ResourceVersion)); err != nil {
+		return definitions.NotificationTemplate{}, response.Err(err)
+	}
+	return current, nil
+}
+
 func (srv *ProvisioningSrv) RouteGetMuteTiming(c *contextmodel.ReqContext, name string) response.Response {
 	timing, err := srv.muteTimings.GetMuteTiming(c.Req.Context(), name, c.GetOrgID())
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get mute timing by name", err)
 	}
-	return response.JSON(http.StatusOK, timing)
+	return response.JSON(http.StatusOK, timing).SetHeader(etag.Header, etag.FromString(timing.Version))
 }
 
 func (srv *ProvisioningSrv) RouteGetMuteTimingExport(c *contextmodel.ReqContext, name string) response.Response {
@@ -302,22 +358,51 @@ func (srv *ProvisioningSrv) RoutePutMuteTiming(c *contextmodel.ReqContext, mt de
 		mt.UID = name
 	}
 	mt.Provenance = determineProvenance(c)
+	if etag.IfMatch(c.Req) != nil {
+		current, rsp := srv.checkMuteTimingIfMatch(c, name)
+		if rsp != nil {
+			return rsp
+		}
+		mt.Version = current.Version
+	}
 	updated, err := srv.muteTimings.UpdateMuteTiming(c.Req.Context(), mt, c.GetOrgID())
 	if err != nil {
-		return response.ErrOrFallback(http.StatusInternalServerError, "failed to update mute timing", err)
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to update mute timing", ifMatchConflict(c, err))
 	}
-	return response.JSON(http.StatusAccepted, updated)
+	return response.JSON(http.StatusAccepted, updated).SetHeader(etag.Header, etag.FromString(updated.Version))
 }
 
 func (srv *ProvisioningSrv) RouteDeleteMuteTiming(c *contextmodel.ReqContext, name string) response.Response {
 	version := c.Query("version")
+	if etag.IfMatch(c.Req) != nil {
+		current, rsp := srv.checkMuteTimingIfMatch(c, name)
+		if rsp != nil {
+			return rsp
+		}
+		version = current.Version
+	}
 	err := srv.muteTimings.DeleteMuteTiming(c.Req.Context(), name, c.GetOrgID(), determineProvenance(c), version)
 	if err != nil {
-		return response.ErrOrFallback(http.StatusInternalServerError, "failed to delete mute timing", err)
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to delete mute timing", ifMatchConflict(c, err))
 	}
 	return response.JSON(http.StatusNoContent, nil)
 }
 
+// checkMuteTimingIfMatch checks the If-Match header of the request against the version of the mute timing
+func (srv *ProvisioningSrv) checkMuteTimingIfMatch(c *contextmodel.ReqContext, name string) (definitions.MuteTimeInterval, response.Response) {
+	current, err := srv.muteTimings.GetMuteTiming(c.Req.Context(), name, c.GetOrgID())
+	if errors.Is(err, provisioning.ErrTimeIntervalNotFound) {
+		return definitions.MuteTimeInterval{}, response.Err(etag.ErrPreconditionFailed.Errorf("mute timing not found: %w", err))
+	}
+	if err != nil {
+		return definitions.MuteTimeInterval{}, response.ErrOrFallback(http.StatusInternalServerError, "failed to get mute timing by name", err)
+	}
+	if err := etag.Check(c.Req, etag.FromString(current.Version)); err != nil {
+		return definitions.MuteTimeInterval{}, response.Err(err)
+	}
+	return current, nil
+}
+
 func (srv *ProvisioningSrv) RouteGetAlertRules(c *contextmodel.ReqContext) response.Response {
 	rules, provenances, err := srv.alertRules.GetAlertRules(c.Req.Context(), c.SignedInUser)
 	if err != nil {
@@ -334,7 +419,7 @@ func (srv *ProvisioningSrv) RouteRouteGetAlertRule(c *contextmodel.ReqContext, U
 		}
 		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule by UID", err)
 	}
-	return response.JSON(http.StatusOK, ProvisionedAlertRuleFromAlertRule(rule, provenace))
+	return response.JSON(http.StatusOK, ProvisionedAlertRuleFromAlertRule(rule, provenace)).SetHeader(etag.Header, etag.FromVersion(rule.Version))
 }
 
 func (srv *ProvisioningSrv) RoutePostAlertRule(c *contextmodel.ReqContext, ar definitions.ProvisionedAlertRule) response.Response {
@@ -376,6 +461,9 @@ func (srv *ProvisioningSrv) RoutePutAlertRule(c *contextmodel.ReqContext, ar def
 
 	updated.OrgID = c.GetOrgID()
 	updated.UID = UID
+	if rsp := srv.checkAlertRuleIfMatch(c, UID); rsp != nil {
+		return rsp
+	}
 	provenance := determineProvenance(c)
 	updatedAlertRule, err := srv.alertRules.UpdateAlertRule(c.Req.Context(), c.SignedInUser, updated, alerting_models.Provenance(provenance))
 	if errors.Is(err, alerting_models.ErrAlertRuleNotFound) {
@@ -396,6 +484,9 @@ func (srv *ProvisioningSrv) RoutePutAlertRule(c *contextmodel.ReqContext, ar def
 }
 
 func (srv *ProvisioningSrv) RouteDeleteAlertRule(c *contextmodel.ReqContext, UID string) response.Response {
+	if rsp := srv.checkAlertRuleIfMatch(c, UID); rsp != nil {
+		return rsp
+	}
 	provenance := determineProvenance(c)
 	err := srv.alertRules.DeleteAlertRule(c.Req.Context(), c.SignedInUser, UID, alerting_models.Provenance(provenance))
 	if err != nil {
@@ -404,12 +495,58 @@ func (srv *ProvisioningSrv) RouteDeleteAlertRule(c *contextmodel.ReqContext, UID
 	return response.JSON(http.StatusNoContent, "")
 }
 
+// checkAlertRuleIfMatch checks the If-Match header of the request against the version of the alert rule
+func (srv *ProvisioningSrv) checkAlertRuleIfMatch(c *contextmodel.ReqContext, UID string) response.Response {
+	if etag.IfMatch(c.Req) == nil {
+		return nil
+	}
+	rule, _, err := srv.alertRules.GetAlertRule(c.Req.Context(), c.SignedInUser, UID)
+	if errors.Is(err, alerting_models.ErrAlertRuleNotFound) {
+		return response.Err(etag.ErrPreconditionFailed.Errorf("alert rule not found: %w", err))
+	}
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule by UID", err)
+	}
+	if err := etag.Check(c.Req, etag.FromVersion(rule.Version)); err != nil {
+		return response.Err(err)
+	}
+	return nil
+}
+
 func (srv *ProvisioningSrv) RouteGetAlertRuleGroup(c *contextmodel.ReqContext, folder string, group string) response.Response {
 	g, err := srv.alertRules.GetRuleGroup(c.Req.Context(), c.SignedInUser, folder, group)
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
 	}
-	return response.JSON(http.StatusOK, ApiAlertRuleGroupFromAlertRuleGroup(g))
+	apiGroup := ApiAlertRuleGroupFromAlertRuleGroup(g)
+	tag, err := etag.FromContent(apiGroup)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
+	}
+	return response.JSON(http.StatusOK, apiGroup).SetHeader(etag.Header, tag)
+}
+
+// checkAlertRuleGroupIfMatch checks the If-Match header of the request against the content of the rule group, rule
+// groups have no version
+func (srv *ProvisioningSrv) checkAlertRuleGroupIfMatch(c *contextmodel.ReqContext, folderUID string, group string) response.Response {
+	if etag.IfMatch(c.Req) == nil {
+		return nil
+	}
+	g, err := srv.alertRules.GetRuleGroup(c.Req.Context(), c.SignedInUser, folderUID, group)
+	if errors.Is(err, alerting_models.ErrAlertRuleGroupNotFound) {
+		return response.Err(etag.ErrPreconditionFailed.Errorf("rule group not found: %w", err))
+	}
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
+	}
+	tag, err := etag.FromContent(ApiAlertRuleGroupFromAlertRuleGroup(g))
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
+	}
+	if err := etag.Check(c.Req, tag); err != nil {
+		return response.Err(err)
+	}
+	return nil
 }
 
 // RouteGetAlertRulesExport retrieves all alert rules in a format compatible with file provisioning.
@@ -491,6 +628,9 @@ func (srv *ProvisioningSrv) RoutePutAlertRuleGroup(c *contextmodel.ReqContext, a
 	if err != nil {
 		ErrResp(http.StatusBadRequest, err, "")
 	}
+	if rsp := srv.checkAlertRuleGroupIfMatch(c, folderUID, group); rsp != nil {
+		return rsp
+	}
 	provenance := determineProvenance(c)
 	err = srv.alertRules.ReplaceRuleGroup(c.Req.Context(), c.SignedInUser, groupModel, alerting_models.Provenance(provenance))
 	if errors.Is(err, alerting_models.ErrAlertRuleFailedValidation) {
@@ -509,6 +649,9 @@ func (srv *ProvisioningSrv) RoutePutAlertRuleGroup(c *contextmodel.ReqContext, a
 }
 
 func (srv *ProvisioningSrv) RouteDeleteAlertRuleGroup(c *contextmodel.ReqContext, folderUID string, group string) response.Response {
+	if rsp := srv.checkAlertRuleGroupIfMatch(c, folderUID, group); rsp != nil {
+		return rsp
+	}
 	provenance := determineProvenance(c)
 	err := srv.alertRules.DeleteRuleGroup(c.Req.Context(), c.SignedInUser, folderUID, group, alerting_models.Provenance(provenance))
 	if err != nil {
@@ -517,6 +660,15 @@ func (srv *ProvisioningSrv) RouteDeleteAlertRuleGroup(c *contextmodel.ReqContext
 	return response.JSON(http.StatusNoContent, "")
 }
 
+// ifMatchConflict turns the version conflict of a request with an If-Match header into a precondition failure, the
+// resource was changed between the check of the header and the update
+func ifMatchConflict(c *contextmodel.ReqContext, err error) error {
+	if etag.IfMatch(c.Req) != nil && errors.Is(err, provisioning.ErrVersionConflict) {
+		return etag.ErrPreconditionFailed.Errorf("resource was changed since it was checked: %w", err)
+	}
+	return err
+}
+
 func determineProvenance(ctx *contextmodel.ReqContext) definitions.Provenance {
 	if _, disabled := ctx.Req.Header[disableProvenanceHeaderName]; disabled {
 		return definitions.Provenance(alerting_models.ProvenanceNone)
@@ -2135,6 +2135,7 @@ func createProvisioningSrvSutFromEnv(t *testing.T, env *testEnvironment) Provisi
 		env.log,
 		ngalertfakes.NewFakeReceiverPermissionsService(),
 		tracer,
+		nil,
 	)
 	return ProvisioningSrv{
 		log:                 env.log,
@@ -32,6 +32,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
@@ -60,6 +61,7 @@ type RulerSrv struct {
 	conditionValidator ConditionValidator
 	authz              RuleAccessControlService
 	userService        user.Service
+	starService        star.Service
 
 	amConfigStore  AMConfigStore
 	amRefresher    AMRefresher
@@ -217,6 +219,7 @@ func (srv RulerSrv) RouteGetNamespaceRulesConfig(c *contextmodel.ReqContext, nam
 	for groupKey, rules := range ruleGroups {
 		result[namespace.Fullpath] = append(result[namespace.Fullpath], toGettableRuleGroupConfig(groupKey.RuleGroup, rules, provenanceRecords, userUIDmapping))
 	}
+	markStarredRules(srv.getStarredRuleUIDs(c), result[namespace.Fullpath]...)
 
 	return response.JSON(http.StatusAccepted, result)
 }
@@ -257,6 +260,8 @@ func (srv RulerSrv) RouteGetRulesGroupConfig(c *contextmodel.ReqContext, namespa
 		// nolint:staticcheck
 		GettableRuleGroupConfig: toGettableRuleGroupConfig(finalRuleGroup, rules, provenanceRecords, userUIDmapping),
 	}
+	// nolint:staticcheck
+	markStarredRules(srv.getStarredRuleUIDs(c), result.GettableRuleGroupConfig)
 
 	return response.JSON(http.StatusAccepted, result)
 }
@@ -340,6 +345,10 @@ func (srv RulerSrv) RouteGetRulesConfig(c *contextmodel.ReqContext) response.Res
 		}
 		result[folder.Fullpath] = append(result[folder.Fullpath], toGettableRuleGroupConfig(groupKey.RuleGroup, rules, provenanceRecords, userUIDmapping))
 	}
+	starred := srv.getStarredRuleUIDs(c)
+	for _, groups := range result {
+		markStarredRules(starred, groups...)
+	}
 	return response.JSON(http.StatusOK, result)
 }
 
@@ -363,6 +372,7 @@ func (srv RulerSrv) RouteGetRuleByUID(c *contextmodel.ReqContext, ruleUID string
 
 	userUIDmapping := srv.getUserUIDmapping(ctx, []*ngmodels.AlertRule{&rule})
 	result := toGettableExtendedRuleNode(rule, map[string]ngmodels.Provenance{rule.ResourceID(): provenance}, userUIDmapping)
+	result.GrafanaManagedAlert.IsStarred = srv.getStarredRuleUIDs(c)[rule.UID]
 
 	return response.JSON(http.StatusOK, result)
 }
@@ -884,6 +894,34 @@ func (srv RulerSrv) RouteUpdateNamespaceRules(c *contextmodel.ReqContext, body a
 	})
 }
 
+// getStarredRuleUIDs returns the UIDs of the alert rules starred by the user. The stars only decorate the rules,
+// so failing to get them is logged rather than failing the request.
+func (srv RulerSrv) getStarredRuleUIDs(c *contextmodel.ReqContext) map[string]bool {
+	if srv.starService == nil {
+		return nil
+	}
+	stars, err := srv.starService.GetResourcesByUser(c.Req.Context(), &star.GetUserResourceStarsQuery{
+		UserID: c.SignedInUser.UserID,
+		OrgID:  c.GetOrgID(),
+		Kind:   star.KindAlertRule,
+	})
+	if err != nil {
+		srv.log.Warn("Failed to get starred alert rules", "error", err)
+		return nil
+	}
+	return stars.UIDs(star.KindAlertRule)
+}
+
+func markStarredRules(starred map[string]bool, groups ...apimodels.GettableRuleGroupConfig) {
+	for _, group := range groups {
+		for _, rule := range group.Rules {
+			if rule.GrafanaManagedAlert != nil {
+				rule.GrafanaManagedAlert.IsStarred = starred[rule.GrafanaManagedAlert.UID]
+			}
+		}
+	}
+}
+
 // getUserUIDmaping returns a UserUID->UserInfo mapping from the UpdatedBy users in the RulesGroup
 func (srv RulerSrv) getUserUIDmapping(ctx context.Context, rules []*ngmodels.AlertRule) map[ngmodels.UserUID]*apimodels.UserInfo {
 	mapping := map[ngmodels.UserUID]*apimodels.UserInfo{
@@ -32,6 +32,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/star"
+	"github.com/grafana/grafana/pkg/services/star/startest"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/services/user/usertest"
 	"github.com/grafana/grafana/pkg/setting"
@@ -546,6 +548,36 @@ func TestRouteGetRuleByUID(t *testing.T) {
 		require.Equal(t, http.StatusNotFound, response.Status())
 		require.Equal(t, 0, len(fakeUserService.ListUsersByIdOrUidCalls))
 	})
+
+	t.Run("rule starred by the user is marked", func(t *testing.T) {
+		orgID := rand.Int63()
+		folder := randFolder()
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+		groupKey := models.GenerateGroupKey(orgID)
+		groupKey.NamespaceUID = folder.UID
+		gen := models.RuleGen.With(models.RuleGen.WithGroupKey(groupKey))
+
+		createdRules := gen.With(gen.WithUniqueGroupIndex(), gen.WithUniqueID()).GenerateManyRef(2)
+		ruleStore.PutRule(context.Background(), createdRules...)
+
+		perms := createPermissionsForRules(createdRules, orgID)
+		req := createRequestContextWithPerms(orgID, perms, nil)
+		starService := startest.NewStarServiceFake()
+		starService.ExpectedUserResourceStars = &star.GetUserResourceStarsResult{Stars: []*star.ResourceStar{
+			{Kind: star.KindAlertRule, ResourceUID: createdRules[0].UID},
+		}}
+		svc := createService(ruleStore, nil)
+		svc.starService = starService
+
+		for i, expected := range []bool{true, false} {
+			response := svc.RouteGetRuleByUID(req, createdRules[i].UID)
+			require.Equal(t, http.StatusOK, response.Status())
+			result := &apimodels.GettableExtendedRuleNode{}
+			require.NoError(t, json.Unmarshal(response.Body(), result))
+			require.Equal(t, expected, result.GrafanaManagedAlert.IsStarred)
+		}
+	})
 }
 
 func TestRouteGetRuleVersionsByUID(t *testing.T) {
@@ -18,6 +18,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
@@ -164,9 +165,51 @@ func (srv TestingApiSrv) RouteTestRuleConfig(c *contextmodel.ReqContext, body ap
 }
 
 func (srv TestingApiSrv) RouteEvalQueries(c *contextmodel.ReqContext, cmd apimodels.EvalQueriesPayload) response.Response {
+	evalResults, errResp := srv.evalQueries(c.Req.Context(), c, cmd)
+	if errResp != nil {
+		return errResp
+	}
+	return response.JSONStreaming(http.StatusOK, evalResults)
+}
+
+// RouteEvalQueriesDebug evaluates the queries and expressions like RouteEvalQueries, and also returns how every
+// node of the pipeline was executed and the data flow between the nodes.
+func (srv TestingApiSrv) RouteEvalQueriesDebug(c *contextmodel.ReqContext, cmd apimodels.EvalQueriesPayload) response.Response {
+	trace := &expr.PipelineTrace{}
+	evalResults, errResp := srv.evalQueries(expr.WithPipelineTrace(c.Req.Context(), trace), c, cmd)
+	if errResp != nil {
+		return errResp
+	}
+
+	result := apimodels.EvalQueriesDebugResponse{
+		Response: evalResults,
+		Nodes:    []apimodels.EvalDebugNode{},
+		Edges:    []apimodels.EvalDebugEdge{},
+	}
+	for _, node := range trace.Nodes() {
+		debugNode := apimodels.EvalDebugNode{
+			RefID:      node.RefID,
+			Type:       node.NodeType,
+			Kind:       node.Kind,
+			Inputs:     node.Inputs,
+			DurationMs: float64(node.Duration) / float64(time.Millisecond),
+			Skipped:    node.Skipped,
+		}
+		if node.Error != nil {
+			debugNode.Error = node.Error.Error()
+		}
+		result.Nodes = append(result.Nodes, debugNode)
+		for _, input := range node.Inputs {
+			result.Edges = append(result.Edges, apimodels.EvalDebugEdge{Source: input, Target: node.RefID})
+		}
+	}
+	return response.JSONStreaming(http.StatusOK, result)
+}
+
+func (srv TestingApiSrv) evalQueries(ctx context.Context, c *contextmodel.ReqContext, cmd apimodels.EvalQueriesPayload) (*backend.QueryDataResponse, response.Response) {
 	queries := AlertQueriesFromApiAlertQueries(cmd.Data)
-	if err := srv.authz.AuthorizeDatasourceAccessForRule(c.Req.Context(), c.SignedInUser, &ngmodels.AlertRule{Data: queries}); err != nil {
-		return response.ErrOrFallback(http.StatusInternalServerError, "failed to authorize access to data sources", err)
+	if err := srv.authz.AuthorizeDatasourceAccessForRule(ctx, c.SignedInUser, &ngmodels.AlertRule{Data: queries}); err != nil {
+		return nil, response.ErrOrFallback(http.StatusInternalServerError, "failed to authorize access to data sources", err)
 	}
 
 	cond := ngmodels.Condition{
@@ -178,18 +221,18 @@ func (srv TestingApiSrv) RouteEvalQueries(c *contextmodel.ReqContext, cmd apimod
 	}
 
 	var optimizations []store.Optimization
-	if srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingQueryOptimization) {
+	if srv.featureManager.IsEnabled(ctx, featuremgmt.FlagAlertingQueryOptimization) {
 		var err error
 		optimizations, err = store.OptimizeAlertQueries(cond.Data)
 		if err != nil {
-			return ErrResp(http.StatusInternalServerError, err, "Failed to optimize query")
+			return nil, ErrResp(http.StatusInternalServerError, err, "Failed to optimize query")
 		}
 	}
 
-	evaluator, err := srv.evaluator.Create(eval.NewContext(c.Req.Context(), c.SignedInUser), cond)
+	evaluator, err := srv.evaluator.Create(eval.NewContext(ctx, c.SignedInUser), cond)
 
 	if err != nil {
-		return ErrResp(http.StatusBadRequest, err, "Failed to build evaluator for queries and expressions")
+		return nil, ErrResp(http.StatusBadRequest, err, "Failed to build evaluator for queries and expressions")
 	}
 
 	now := cmd.Now
@@ -197,14 +240,14 @@ func (srv TestingApiSrv) RouteEvalQueries(c *contextmodel.ReqContext, cmd apimod
 		now = timeNow()
 	}
 
-	evalResults, err := evaluator.EvaluateRaw(c.Req.Context(), now)
+	evalResults, err := evaluator.EvaluateRaw(ctx, now)
 
 	if err != nil {
-		return ErrResp(http.StatusInternalServerError, err, "Failed to evaluate queries and expressions")
+		return nil, ErrResp(http.StatusInternalServerError, err, "Failed to evaluate queries and expressions")
 	}
 
 	addOptimizedQueryWarnings(evalResults, optimizations)
-	return response.JSONStreaming(http.StatusOK, evalResults)
+	return evalResults, nil
 }
 
 // addOptimizedQueryWarnings adds warnings to the query results for any queries that were optimized.
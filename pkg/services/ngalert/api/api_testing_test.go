@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"testing"
@@ -12,6 +13,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	acMock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
@@ -392,6 +394,54 @@ func TestRouteEvalQueries(t *testing.T) {
 	})
 }
 
+func TestRouteEvalQueriesDebug(t *testing.T) {
+	rc := &contextmodel.ReqContext{
+		Context: &web.Context{
+			Req: &http.Request{},
+		},
+		SignedInUser: &user.SignedInUser{
+			OrgID: 1,
+		},
+	}
+
+	t.Run("should return Forbidden if user cannot query a data source", func(t *testing.T) {
+		data1 := models.GenerateAlertQuery()
+
+		srv := createTestingApiSrv(t, &fakes.FakeCacheService{}, acMock.New(), nil, featuremgmt.WithFeatures(), fakes2.NewRuleStore(t))
+
+		response := srv.RouteEvalQueriesDebug(rc, definitions.EvalQueriesPayload{
+			Data: ApiAlertQueriesFromAlertQueries([]models.AlertQuery{data1}),
+		})
+
+		require.Equal(t, http.StatusForbidden, response.Status())
+	})
+
+	t.Run("should evaluate the queries with a pipeline trace", func(t *testing.T) {
+		data1 := models.GenerateAlertQuery()
+		currentTime := time.Now()
+
+		ac := acMock.New().WithPermissions([]ac.Permission{
+			{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceScopeUID(data1.DatasourceUID)},
+		})
+		ds := &fakes.FakeCacheService{DataSources: []*datasources.DataSource{{UID: data1.DatasourceUID}}}
+
+		evaluator := &eval_mocks.ConditionEvaluatorMock{}
+		evaluator.EXPECT().EvaluateRaw(mock.Anything, mock.Anything).Return(&backend.QueryDataResponse{}, nil)
+
+		srv := createTestingApiSrv(t, ds, ac, eval_mocks.NewEvaluatorFactory(evaluator), featuremgmt.WithFeatures(), fakes2.NewRuleStore(t))
+
+		response := srv.RouteEvalQueriesDebug(rc, definitions.EvalQueriesPayload{
+			Data: ApiAlertQueriesFromAlertQueries([]models.AlertQuery{data1}),
+			Now:  currentTime,
+		})
+
+		require.Equal(t, http.StatusOK, response.Status())
+		evaluator.AssertCalled(t, "EvaluateRaw", mock.MatchedBy(func(ctx context.Context) bool {
+			return expr.PipelineTraceFromContext(ctx) != nil
+		}), currentTime)
+	})
+}
+
 func createTestingApiSrv(t *testing.T, ds *fakes.FakeCacheService, ac *acMock.Mock, evaluator eval.EvaluatorFactory, featureManager featuremgmt.FeatureToggles, ruleStore RuleStore) *TestingApiSrv {
 	if ac == nil {
 		ac = acMock.New()
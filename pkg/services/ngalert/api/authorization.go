@@ -84,7 +84,8 @@ func (api *API) authorize(method, path string) web.Handler {
 	case http.MethodPost + "/api/v1/rule/backtest":
 		// additional authorization is done in the request handler
 		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
-	case http.MethodPost + "/api/v1/eval":
+	case http.MethodPost + "/api/v1/eval",
+		http.MethodPost + "/api/v1/eval/debug":
 		// additional authorization is done in the request handler
 		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
 
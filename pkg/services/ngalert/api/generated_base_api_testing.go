@@ -22,6 +22,7 @@ import (
 type TestingApi interface {
 	BacktestConfig(*contextmodel.ReqContext) response.Response
 	RouteEvalQueries(*contextmodel.ReqContext) response.Response
+	RouteEvalQueriesDebug(*contextmodel.ReqContext) response.Response
 	RouteTestRuleConfig(*contextmodel.ReqContext) response.Response
 	RouteTestRuleGrafanaConfig(*contextmodel.ReqContext) response.Response
 }
@@ -42,6 +43,14 @@ func (f *TestingApiHandler) RouteEvalQueries(ctx *contextmodel.ReqContext) respo
 	}
 	return f.handleRouteEvalQueries(ctx, conf)
 }
+func (f *TestingApiHandler) RouteEvalQueriesDebug(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.EvalQueriesPayload{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRouteEvalQueriesDebug(ctx, conf)
+}
 func (f *TestingApiHandler) RouteTestRuleConfig(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	datasourceUIDParam := web.Params(ctx.Req)[":DatasourceUID"]
@@ -87,6 +96,18 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApi, m *metrics.API) {
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/eval/debug"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/v1/eval/debug"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/eval/debug",
+				api.Hooks.Wrap(srv.RouteEvalQueriesDebug),
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/rule/test/{DatasourceUID}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
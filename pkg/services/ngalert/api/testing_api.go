@@ -29,6 +29,10 @@ func (f *TestingApiHandler) handleRouteEvalQueries(c *contextmodel.ReqContext, b
 	return f.svc.RouteEvalQueries(c, body)
 }
 
+func (f *TestingApiHandler) handleRouteEvalQueriesDebug(c *contextmodel.ReqContext, body apimodels.EvalQueriesPayload) response.Response {
+	return f.svc.RouteEvalQueriesDebug(c, body)
+}
+
 func (f *TestingApiHandler) handleBacktestConfig(ctx *contextmodel.ReqContext, conf apimodels.BacktestConfig) response.Response {
 	return f.svc.BacktestAlertRule(ctx, conf)
 }
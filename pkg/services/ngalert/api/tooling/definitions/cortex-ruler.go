@@ -617,6 +617,8 @@ type GettableGrafanaRule struct {
 	Metadata                    *AlertRuleMetadata             `json:"metadata,omitempty" yaml:"metadata,omitempty"`
 	GUID                        string                         `json:"guid" yaml:"guid"`
 	MissingSeriesEvalsToResolve *int64                         `json:"missing_series_evals_to_resolve,omitempty" yaml:"missing_series_evals_to_resolve,omitempty"`
+	// IsStarred is set when the rule is starred by the user requesting it
+	IsStarred bool `json:"is_starred,omitempty" yaml:"-"`
 }
 
 // UserInfo represents user-related information, including a unique identifier and a name.
@@ -55,6 +55,19 @@ import (
 //     Responses:
 //       200: EvalQueriesResponse
 
+// swagger:route Post /v1/eval/debug testing RouteEvalQueriesDebug
+//
+// Evaluate queries and expressions, and return the result, the duration and the inputs of every node
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: EvalQueriesDebugResponse
+
 // swagger:route Post /v1/rule/backtest testing BacktestConfig
 //
 // Test rule
@@ -126,6 +139,12 @@ type EvalQueriesRequest struct {
 	Body EvalQueriesPayload
 }
 
+// swagger:parameters RouteEvalQueriesDebug
+type EvalQueriesDebugRequest struct {
+	// in:body
+	Body EvalQueriesPayload
+}
+
 // swagger:model
 type EvalQueriesPayload struct {
 	Condition string       `json:"condition"`
@@ -175,6 +194,38 @@ type TestRuleResponse struct {
 // swagger:model
 type EvalQueriesResponse = backend.QueryDataResponse
 
+// swagger:model
+type EvalQueriesDebugResponse struct {
+	// Response holds the frames returned by every query and expression, by refId
+	Response *EvalQueriesResponse `json:"response"`
+	// Nodes are the queries and expressions, in execution order
+	Nodes []EvalDebugNode `json:"nodes"`
+	// Edges are the data flow between the queries and expressions
+	Edges []EvalDebugEdge `json:"edges"`
+}
+
+// swagger:model
+type EvalDebugNode struct {
+	RefID string `json:"refId"`
+	// Type is the type of the node: Expression, Datasource or Machine Learning
+	Type string `json:"type"`
+	// Kind is the command type of an expression, or the plugin type of a datasource query
+	Kind string `json:"kind,omitempty"`
+	// Inputs are the refIds of the nodes the node depends on
+	Inputs []string `json:"inputs,omitempty"`
+	// DurationMs is the time spent executing the node, in milliseconds
+	DurationMs float64 `json:"durationMs"`
+	// Skipped is true when the node was not executed because one of its inputs failed
+	Skipped bool   `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// swagger:model
+type EvalDebugEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
 // swagger:model
 type AlertInstancesResponse struct {
 	// Instances is an array of arrow encoded dataframes
@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/rendering/rendercache"
 	"github.com/grafana/grafana/pkg/services/screenshot"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -80,7 +81,7 @@ func NewScreenshotImageService(
 // NewScreenshotImageServiceFromCfg returns a new ScreenshotImageService
 // from the configuration.
 func NewScreenshotImageServiceFromCfg(cfg *setting.Cfg, db *store.DBstore, ds dashboards.DashboardService,
-	rs rendering.Service, r prometheus.Registerer) (ImageService, error) {
+	rs rendering.Service, renderCache *rendercache.Service, r prometheus.Registerer) (ImageService, error) {
 	var (
 		cache             CacheService                 = &NoOpCacheService{}
 		limiter           screenshot.RateLimiter       = &screenshot.NoOpRateLimiter{}
@@ -93,7 +94,7 @@ func NewScreenshotImageServiceFromCfg(cfg *setting.Cfg, db *store.DBstore, ds da
 	if cfg.UnifiedAlerting.Screenshots.Capture {
 		cache = NewInmemCacheService(screenshotCacheTTL, r)
 		limiter = screenshot.NewTokenRateLimiter(cfg.UnifiedAlerting.Screenshots.MaxConcurrentScreenshots)
-		screenshots = screenshot.NewHeadlessScreenshotService(cfg, ds, rs, r)
+		screenshots = screenshot.NewHeadlessScreenshotService(cfg, ds, rs, renderCache, r)
 		screenshotTimeout = cfg.UnifiedAlerting.Screenshots.CaptureTimeout
 
 		// Image uploading is an optional feature
@@ -172,7 +173,8 @@ func (s *ScreenshotImageService) NewImage(ctx context.Context, r *models.AlertRu
 		}
 
 		logger.Debug("Took screenshot", "path", screenshot.Path)
-		image := models.Image{Path: screenshot.Path}
+		// The URL is signed when the screenshot is in the render cache, an uploaded image replaces it
+		image := models.Image{Path: screenshot.Path, URL: screenshot.URL}
 
 		// Uploading images is optional
 		if s.uploads != nil {
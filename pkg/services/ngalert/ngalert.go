@@ -52,8 +52,12 @@ import (
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/rendering/rendercache"
+	"github.com/grafana/grafana/pkg/services/secretaccess"
 	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/useractivity"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -85,6 +89,10 @@ func ProvideService(
 	pluginContextProvider *plugincontext.Provider,
 	resourcePermissions accesscontrol.ReceiverPermissionsService,
 	userService user.Service,
+	secretAccess secretaccess.Service,
+	renderCache *rendercache.Service,
+	userActivity useractivity.Service,
+	starService star.Service,
 ) (*AlertNG, error) {
 	ng := &AlertNG{
 		Cfg:                   cfg,
@@ -115,6 +123,10 @@ func ProvideService(
 		pluginContextProvider: pluginContextProvider,
 		ResourcePermissions:   resourcePermissions,
 		userService:           userService,
+		secretAccess:          secretAccess,
+		renderCache:           renderCache,
+		userActivity:          userActivity,
+		starService:           starService,
 	}
 
 	if ng.IsDisabled() {
@@ -167,6 +179,10 @@ type AlertNG struct {
 	annotationsRepo      annotations.Repository
 	store                *store.DBstore
 	userService          user.Service
+	secretAccess         secretaccess.Service
+	renderCache          *rendercache.Service
+	userActivity         useractivity.Service
+	starService          star.Service
 
 	bus          bus.Bus
 	pluginsStore pluginstore.Store
@@ -259,6 +275,8 @@ func (ng *AlertNG) init() error {
 		return err
 	}
 
+	opts = append(opts, notifier.WithSecretAccess(ng.secretAccess))
+
 	decryptFn := ng.SecretsService.GetDecryptedValue
 	multiOrgMetrics := ng.Metrics.GetMultiOrgAlertmanagerMetrics()
 	moa, err := notifier.NewMultiOrgAlertmanager(
@@ -282,7 +300,7 @@ func (ng *AlertNG) init() error {
 	}
 	ng.MultiOrgAlertmanager = moa
 
-	imageService, err := image.NewScreenshotImageServiceFromCfg(ng.Cfg, ng.store, ng.dashboardService, ng.renderService, ng.Metrics.Registerer)
+	imageService, err := image.NewScreenshotImageServiceFromCfg(ng.Cfg, ng.store, ng.dashboardService, ng.renderService, ng.renderCache, ng.Metrics.Registerer)
 	if err != nil {
 		return err
 	}
@@ -399,6 +417,7 @@ func (ng *AlertNG) init() error {
 		ng.Log,
 		ng.ResourcePermissions,
 		ng.tracer,
+		ng.secretAccess,
 	)
 	provisioningReceiverService := notifier.NewReceiverService(
 		ac.NewReceiverAccess[*models.Receiver](ng.accesscontrol, true),
@@ -410,6 +429,7 @@ func (ng *AlertNG) init() error {
 		ng.Log,
 		ng.ResourcePermissions,
 		ng.tracer,
+		ng.secretAccess,
 	)
 
 	// Provisioning
@@ -454,6 +474,8 @@ func (ng *AlertNG) init() error {
 		Hooks:                api.NewHooks(ng.Log),
 		Tracer:               ng.tracer,
 		UserService:          ng.userService,
+		UserActivity:         ng.userActivity,
+		StarService:          ng.starService,
 	}
 	ng.Api.RegisterAPIEndpoints(ng.Metrics.GetAPIMetrics())
 
@@ -578,6 +600,15 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	return children.Wait()
 }
 
+// SchedulerLastTick returns when the scheduler last processed a tick, zero when it isn't running or hasn't
+// processed a tick yet.
+func (ng *AlertNG) SchedulerLastTick() time.Time {
+	if ng.schedule == nil {
+		return time.Time{}
+	}
+	return ng.schedule.LastTick()
+}
+
 // IsDisabled returns true if the alerting service is disabled for this instance.
 func (ng *AlertNG) IsDisabled() bool {
 	if ng.Cfg == nil {
@@ -22,6 +22,7 @@ import (
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/secretaccess"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -56,6 +57,8 @@ type alertmanager struct {
 	DefaultConfiguration string
 	decryptFn            alertingNotify.GetDecryptedValueFn
 	crypto               Crypto
+	// secretAccess is nil when the decryptions of the contact point secrets aren't recorded
+	secretAccess secretaccess.Service
 }
 
 // maintenanceOptions represent the options for components that need maintenance on a frequency within the Alertmanager.
@@ -386,6 +389,7 @@ func (am *alertmanager) applyConfig(ctx context.Context, cfg *apimodels.Postable
 	if err != nil {
 		return false, err
 	}
+	am.recordSecretAccess(ctx, receivers)
 
 	am.updateConfigMetrics(cfg, len(rawConfig))
 	return true, nil
@@ -25,6 +25,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/secretaccess"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -109,7 +110,8 @@ type MultiOrgAlertmanager struct {
 	kvStore     kvstore.KVStore
 	factory     OrgAlertmanagerFactory
 
-	decryptFn alertingNotify.GetDecryptedValueFn
+	decryptFn    alertingNotify.GetDecryptedValueFn
+	secretAccess secretaccess.Service
 
 	metrics *metrics.MultiOrgAlertmanager
 	ns      notifications.Service
@@ -169,7 +171,12 @@ func NewMultiOrgAlertmanager(
 	moa.factory = func(ctx context.Context, orgID int64) (Alertmanager, error) {
 		m := metrics.NewAlertmanagerMetrics(moa.metrics.GetOrCreateOrgRegistry(orgID), l)
 		stateStore := NewFileStore(orgID, kvStore)
-		return NewAlertmanager(ctx, orgID, moa.settings, moa.configStore, stateStore, moa.peer, moa.decryptFn, moa.ns, m, featureManager, moa.Crypto, notificationHistorian)
+		am, err := NewAlertmanager(ctx, orgID, moa.settings, moa.configStore, stateStore, moa.peer, moa.decryptFn, moa.ns, m, featureManager, moa.Crypto, notificationHistorian)
+		if err != nil {
+			return nil, err
+		}
+		am.secretAccess = moa.secretAccess
+		return am, nil
 	}
 
 	for _, opt := range opts {
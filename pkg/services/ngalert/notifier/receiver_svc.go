@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier/legacy_storage"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning/validation"
+	"github.com/grafana/grafana/pkg/services/secretaccess"
 	"github.com/grafana/grafana/pkg/services/secrets"
 )
 
@@ -51,6 +52,8 @@ type ReceiverService struct {
 	provenanceValidator    validation.ProvenanceStatusTransitionValidator
 	resourcePermissions    ac.ReceiverPermissionsService
 	tracer                 tracing.Tracer
+	// secretAccess is nil when the decryptions of the contact point secrets aren't recorded
+	secretAccess secretaccess.Service
 }
 
 type alertRuleNotificationSettingsStore interface {
@@ -104,6 +107,7 @@ func NewReceiverService(
 	log log.Logger,
 	resourcePermissions ac.ReceiverPermissionsService,
 	tracer tracing.Tracer,
+	secretAccess secretaccess.Service,
 ) *ReceiverService {
 	return &ReceiverService{
 		authz:                  authz,
@@ -116,6 +120,7 @@ func NewReceiverService(
 		provenanceValidator:    validation.ValidateProvenanceRelaxed,
 		resourcePermissions:    resourcePermissions,
 		tracer:                 tracer,
+		secretAccess:           secretAccess,
 	}
 }
 
@@ -160,6 +165,7 @@ func (rs *ReceiverService) GetReceiver(ctx context.Context, q models.GetReceiver
 	}
 
 	if q.Decrypt {
+		rs.recordSecretAccess(ctx, q.OrgID, rcv)
 		err := rcv.Decrypt(rs.decryptor(ctx))
 		if err != nil {
 			rs.log.FromContext(ctx).Warn("Failed to decrypt secure settings", "name", rcv.Name, "error", err)
@@ -226,6 +232,7 @@ func (rs *ReceiverService) GetReceivers(ctx context.Context, q models.GetReceive
 
 	for _, rcv := range filtered {
 		if q.Decrypt {
+			rs.recordSecretAccess(ctx, q.OrgID, rcv)
 			err := rcv.Decrypt(rs.decryptor(ctx))
 			if err != nil {
 				rs.log.FromContext(ctx).Warn("Failed to decrypt secure settings", "name", rcv.Name, "error", err)
@@ -0,0 +1,79 @@
+package notifier
+
+import (
+	"context"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/secretaccess"
+)
+
+// WithSecretAccess records the decryptions of the contact point secrets by the Alertmanagers, and lists the
+// contact points with secrets of every organization so the ones never decrypted are reported as unused.
+func WithSecretAccess(s secretaccess.Service) Option {
+	return func(moa *MultiOrgAlertmanager) {
+		moa.secretAccess = s
+		s.RegisterSource(secretaccess.KindContactPoint, moa.listSecrets)
+	}
+}
+
+// listSecrets returns the integrations with secure settings of the latest configuration of every organization
+func (moa *MultiOrgAlertmanager) listSecrets(ctx context.Context) ([]*secretaccess.Secret, error) {
+	configs, err := moa.getLatestConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make([]*secretaccess.Secret, 0)
+	for orgID, config := range configs {
+		cfg, err := Load([]byte(config.AlertmanagerConfiguration))
+		if err != nil {
+			moa.logger.Warn("Failed to load the Alertmanager configuration to list its secrets", "org", orgID, "error", err)
+			continue
+		}
+		for _, receiver := range cfg.AlertmanagerConfig.Receivers {
+			for _, integration := range receiver.GrafanaManagedReceivers {
+				if len(integration.SecureSettings) == 0 {
+					continue
+				}
+				secrets = append(secrets, &secretaccess.Secret{OrgID: orgID, Kind: secretaccess.KindContactPoint, UID: integration.UID, Name: receiver.GetName()})
+			}
+		}
+	}
+	return secrets, nil
+}
+
+// recordSecretAccess records the decryption of the secure settings of the integrations when the configuration is applied
+func (am *alertmanager) recordSecretAccess(ctx context.Context, receivers []*alertingNotify.APIReceiver) {
+	if am.secretAccess == nil {
+		return
+	}
+	for _, receiver := range receivers {
+		for _, integration := range receiver.Integrations {
+			if len(integration.SecureSettings) == 0 {
+				continue
+			}
+			am.secretAccess.Record(ctx, secretaccess.Access{
+				Secret:  secretaccess.Secret{OrgID: am.Base.TenantID(), Kind: secretaccess.KindContactPoint, UID: integration.UID, Name: receiver.Name},
+				Service: "alertmanager",
+			})
+		}
+	}
+}
+
+// recordSecretAccess records the decryption of the secure settings of the receiver's integrations by a user
+func (rs *ReceiverService) recordSecretAccess(ctx context.Context, orgID int64, receiver *models.Receiver) {
+	if rs.secretAccess == nil {
+		return
+	}
+	for _, integration := range receiver.Integrations {
+		if len(integration.SecureSettings) == 0 {
+			continue
+		}
+		rs.secretAccess.Record(ctx, secretaccess.Access{
+			Secret:  secretaccess.Secret{OrgID: orgID, Kind: secretaccess.KindContactPoint, UID: integration.UID, Name: receiver.Name},
+			Service: "receivers",
+		})
+	}
+}
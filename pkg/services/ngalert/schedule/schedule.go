@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"sync/atomic"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -29,6 +30,8 @@ type ScheduleService interface {
 	// Run the scheduler until the context is canceled or the scheduler returns
 	// an error. The scheduler is terminated when this function returns.
 	Run(context.Context) error
+	// LastTick returns when the scheduler last processed a tick, zero before the first tick.
+	LastTick() time.Time
 }
 
 // retryDelay represents how long to wait between each failed rule evaluation.
@@ -110,6 +113,9 @@ type schedule struct {
 	tracer          tracing.Tracer
 	featureToggles  featuremgmt.FeatureToggles
 	recordingWriter RecordingWriter
+
+	// lastTick is the Unix time in nanoseconds of the last processed tick
+	lastTick atomic.Int64
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -178,6 +184,14 @@ func (sch *schedule) Run(ctx context.Context) error {
 	return nil
 }
 
+func (sch *schedule) LastTick() time.Time {
+	lastTick := sch.lastTick.Load()
+	if lastTick == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, lastTick)
+}
+
 // Rules fetches the entire set of rules considered for evaluation by the scheduler on the next tick.
 // Such rules are not guaranteed to have been evaluated by the scheduler.
 // Rules returns all supplementary metadata for the rules that is stored by the scheduler - namely, the set of folder titles.
@@ -251,6 +265,7 @@ func (sch *schedule) schedulePeriodic(ctx context.Context, t *ticker.T) error {
 			sch.metrics.BehindSeconds.Set(start.Sub(tick).Seconds())
 
 			sch.processTick(ctx, dispatcherGroup, tick)
+			sch.lastTick.Store(start.UnixNano())
 
 			sch.metrics.SchedulePeriodicDuration.Observe(time.Since(start).Seconds())
 		case <-ctx.Done():
@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/util/xorm"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -44,11 +45,25 @@ func (st DBstore) DeleteAlertRulesByUID(ctx context.Context, orgID int64, user *
 	}
 	logger := st.Logger.New("org_id", orgID, "rule_uids", ruleUID)
 	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var deleted []alertRule
+		if err := sess.Table(alertRule{}).Cols("uid", "title", "namespace_uid").Where("org_id = ?", orgID).In("uid", ruleUID).Find(&deleted); err != nil {
+			return err
+		}
+
 		rows, err := sess.Table(alertRule{}).Where("org_id = ?", orgID).In("uid", ruleUID).Delete(alertRule{})
 		if err != nil {
 			return err
 		}
 		logger.Debug("Deleted alert rules", "count", rows)
+		for _, r := range deleted {
+			sess.PublishAfterCommit(&events.AlertRuleDeleted{
+				Timestamp: TimeNow(),
+				UID:       r.UID,
+				OrgID:     orgID,
+				Title:     r.Title,
+				FolderUID: r.NamespaceUID,
+			})
+		}
 		if rows > 0 {
 			keys := make([]ngmodels.AlertRuleKey, 0, len(ruleUID))
 			for _, uid := range ruleUID {
@@ -382,6 +397,15 @@ func (st DBstore) InsertAlertRules(ctx context.Context, user *ngmodels.UserUID,
 
 				ids = append(ids, ngmodels.AlertRuleKeyWithId{AlertRuleKey: key, ID: r.ID})
 				keys = append(keys, key)
+				sess.PublishAfterCommit(&events.AlertRuleSaved{
+					Timestamp: r.Updated,
+					UID:       r.UID,
+					OrgID:     r.OrgID,
+					Title:     r.Title,
+					FolderUID: r.NamespaceUID,
+					RuleGroup: r.RuleGroup,
+					Version:   r.Version,
+				})
 			}
 		}
 
@@ -448,6 +472,15 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, user *ngmodels.UserUID,
 			}
 
 			keys = append(keys, ngmodels.AlertRuleKey{OrgID: r.New.OrgID, UID: r.New.UID})
+			sess.PublishAfterCommit(&events.AlertRuleSaved{
+				Timestamp: r.New.Updated,
+				UID:       r.New.UID,
+				OrgID:     r.New.OrgID,
+				Title:     r.New.Title,
+				FolderUID: r.New.NamespaceUID,
+				RuleGroup: r.New.RuleGroup,
+				Version:   r.Existing.Version + 1,
+			})
 		}
 		if len(ruleVersions) > 0 {
 			if _, err := sess.Insert(&ruleVersions); err != nil {
@@ -54,7 +54,7 @@ func (ns *NotificationService) Send(ctx context.Context, msg *Message) (int, err
 	return ns.mailer.Send(ctx, messages...)
 }
 
-func (ns *NotificationService) buildEmailMessage(cmd *SendEmailCommand) (*Message, error) {
+func (ns *NotificationService) buildEmailMessage(ctx context.Context, cmd *SendEmailCommand) (*Message, error) {
 	if !ns.Cfg.Smtp.Enabled {
 		return nil, ErrSmtpNotEnabled
 	}
@@ -72,8 +72,12 @@ func (ns *NotificationService) buildEmailMessage(cmd *SendEmailCommand) (*Messag
 		if err != nil {
 			return nil, err
 		}
+		tmpl, err := ns.getTemplate(ctx, cmd.Template+fileExtension)
+		if err != nil {
+			return nil, err
+		}
 		var buffer bytes.Buffer
-		err = mailTemplates.ExecuteTemplate(&buffer, cmd.Template+fileExtension, data)
+		err = tmpl.ExecuteTemplate(&buffer, cmd.Template+fileExtension, data)
 		if err != nil {
 			return nil, err
 		}
@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/branding"
 	tempuser "github.com/grafana/grafana/pkg/services/temp_user"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
@@ -51,7 +52,7 @@ const (
 	tmplVerifyEmail     = "verify_email"
 )
 
-func ProvideService(bus bus.Bus, cfg *setting.Cfg, mailer Mailer, store TempUserStore) (*NotificationService, error) {
+func ProvideService(bus bus.Bus, cfg *setting.Cfg, mailer Mailer, store TempUserStore, brandingService branding.Service) (*NotificationService, error) {
 	ns := &NotificationService{
 		Bus:          bus,
 		Cfg:          cfg,
@@ -60,18 +61,13 @@ func ProvideService(bus bus.Bus, cfg *setting.Cfg, mailer Mailer, store TempUser
 		webhookQueue: make(chan *Webhook, 10),
 		mailer:       mailer,
 		store:        store,
+		branding:     brandingService,
 	}
 
 	ns.Bus.AddEventListener(ns.signUpStartedHandler)
 	ns.Bus.AddEventListener(ns.signUpCompletedHandler)
 
-	mailTemplates = template.New("name")
-	mailTemplates.Funcs(template.FuncMap{
-		"Subject":                 subjectTemplateFunc,
-		"HiddenSubject":           hiddenSubjectTemplateFunc,
-		"__dangerouslyInjectHTML": __dangerouslyInjectHTML,
-	})
-	mailTemplates.Funcs(sprig.FuncMap())
+	mailTemplates = newMailTemplate("name")
 
 	// Parse invalid templates using 'or' logic. Return an error only if no paths are valid.
 	invalidTemplates := make([]string, 0)
@@ -113,6 +109,7 @@ type NotificationService struct {
 	mailer       Mailer
 	log          log.Logger
 	store        TempUserStore
+	branding     branding.Service
 }
 
 func (ns *NotificationService) Run(ctx context.Context) error {
@@ -159,6 +156,38 @@ func (ns *NotificationService) SendWebhookSync(ctx context.Context, cmd *SendWeb
 	})
 }
 
+func newMailTemplate(name string) *template.Template {
+	tmpl := template.New(name)
+	tmpl.Funcs(template.FuncMap{
+		"Subject":                 subjectTemplateFunc,
+		"HiddenSubject":           hiddenSubjectTemplateFunc,
+		"__dangerouslyInjectHTML": __dangerouslyInjectHTML,
+	})
+	tmpl.Funcs(sprig.FuncMap())
+	return tmpl
+}
+
+// ParseTemplate parses an email template customized by the branding, with the functions of the templates on disk
+func ParseTemplate(name, source string) (*template.Template, error) {
+	return newMailTemplate(name).Parse(source)
+}
+
+// getTemplate returns the template customized by the branding when there is one, the templates on disk otherwise
+func (ns *NotificationService) getTemplate(ctx context.Context, name string) (*template.Template, error) {
+	if ns.branding == nil {
+		return mailTemplates, nil
+	}
+	source, ok, err := ns.branding.GetEmailTemplate(ctx, name)
+	if err != nil {
+		ns.log.Warn("Failed to get the customized email template, using the default one", "template", name, "error", err)
+		return mailTemplates, nil
+	}
+	if !ok {
+		return mailTemplates, nil
+	}
+	return ParseTemplate(name, source)
+}
+
 // hiddenSubjectTemplateFunc sets the subject template (value) on the map represented by `.Subject.` (obj) so that it can be compiled and executed later.
 // It returns a blank string, so there will be no resulting value left in place of the template.
 func hiddenSubjectTemplateFunc(obj map[string]any, value string) string {
@@ -200,7 +229,7 @@ func __dangerouslyInjectHTML(s string) template.HTML {
 }
 
 func (ns *NotificationService) SendEmailCommandHandlerSync(ctx context.Context, cmd *SendEmailCommandSync) error {
-	message, err := ns.buildEmailMessage(&SendEmailCommand{
+	message, err := ns.buildEmailMessage(ctx, &SendEmailCommand{
 		Data:             cmd.Data,
 		Info:             cmd.Info,
 		Template:         cmd.Template,
@@ -221,7 +250,7 @@ func (ns *NotificationService) SendEmailCommandHandlerSync(ctx context.Context,
 }
 
 func (ns *NotificationService) SendEmailCommandHandler(ctx context.Context, cmd *SendEmailCommand) error {
-	message, err := ns.buildEmailMessage(cmd)
+	message, err := ns.buildEmailMessage(ctx, cmd)
 	if err != nil {
 		return err
 	}
@@ -10,6 +10,8 @@ import (
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/branding"
+	"github.com/grafana/grafana/pkg/services/branding/brandingtest"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -70,6 +72,30 @@ func TestSendEmailSync(t *testing.T) {
 		require.Equal(t, []string{"asdf@grafana.com"}, sent.To)
 	})
 
+	t.Run("When the branding customizes the email template", func(t *testing.T) {
+		ns, mailer := createSut(t, bus)
+		ns.branding = &brandingtest.FakeService{ExpectedBranding: &branding.Branding{
+			EmailTemplates: map[string]string{
+				"welcome_on_signup.html": `{{Subject .Subject .TemplateData "Welcome to Acme"}}<p>Hello from Acme, {{.Name}}</p>`,
+			},
+		}}
+		cmd := &SendEmailCommandSync{
+			SendEmailCommand: SendEmailCommand{
+				To:       []string{"asdf@grafana.com"},
+				Template: "welcome_on_signup",
+				Data:     map[string]any{"Name": "Ada"},
+			},
+		}
+		err := ns.SendEmailCommandHandlerSync(context.Background(), cmd)
+		require.NoError(t, err)
+
+		require.NotEmpty(t, mailer.Sent)
+		sent := mailer.Sent[len(mailer.Sent)-1]
+		require.Equal(t, "Welcome to Acme", sent.Subject)
+		require.Contains(t, sent.Body["text/html"], "<p>Hello from Acme, Ada</p>")
+		require.NotContains(t, sent.Body["text/plain"], "Acme")
+	})
+
 	t.Run("When using Single Email mode with multiple recipients", func(t *testing.T) {
 		ns, mailer := createSut(t, bus)
 		cmd := &SendEmailCommandSync{
@@ -308,7 +334,7 @@ func createSut(t *testing.T, bus bus.Bus) (*NotificationService, *FakeMailer) {
 
 func createSutWithConfig(t *testing.T, bus bus.Bus, cfg *setting.Cfg) (*NotificationService, *FakeMailer, error) {
 	smtp := NewFakeMailer()
-	ns, err := ProvideService(bus, cfg, smtp, nil)
+	ns, err := ProvideService(bus, cfg, smtp, nil, nil)
 	return ns, smtp, err
 }
 
@@ -317,7 +343,7 @@ func createDisconnectedSut(t *testing.T, bus bus.Bus) *NotificationService {
 
 	cfg := createSmtpConfig()
 	smtp := NewFakeDisconnectedMailer()
-	ns, err := ProvideService(bus, cfg, smtp, nil)
+	ns, err := ProvideService(bus, cfg, smtp, nil, nil)
 	require.NoError(t, err)
 	return ns
 }
@@ -21,7 +21,7 @@ func TestEmailIntegrationTest(t *testing.T) {
 		cfg.Smtp.FromAddress = "from@address.com"
 		cfg.Smtp.FromName = "Grafana Admin"
 		cfg.Smtp.ContentTypes = []string{"text/html", "text/plain"}
-		ns, err := ProvideService(newBus(t), cfg, NewFakeMailer(), nil)
+		ns, err := ProvideService(newBus(t), cfg, NewFakeMailer(), nil, nil)
 		require.NoError(t, err)
 
 		t.Run("When sending reset email password", func(t *testing.T) {
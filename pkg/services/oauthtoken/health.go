@@ -0,0 +1,134 @@
+package oauthtoken
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	claims "github.com/grafana/authlib/types"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+type TokenStatus string
+
+const (
+	// TokenStatusValid means the access token can be forwarded as is
+	TokenStatusValid TokenStatus = "valid"
+	// TokenStatusExpiring means the access token is within the refresh window, or expired but refreshable. Refreshable
+	// tokens are refreshed the next time they're used.
+	TokenStatusExpiring TokenStatus = "expiring"
+	// TokenStatusExpired means the access token expired and can no longer be refreshed
+	TokenStatusExpired TokenStatus = "expired"
+	// TokenStatusMissing means Grafana holds no access token for the provider, for example after a failed refresh
+	TokenStatusMissing TokenStatus = "missing"
+)
+
+// TokenHealth describes the OAuth token Grafana holds for a user and provider
+type TokenHealth struct {
+	Provider string `json:"provider"`
+	// Current is true for the provider the user signed in with
+	Current         bool        `json:"current"`
+	HasAccessToken  bool        `json:"hasAccessToken"`
+	HasRefreshToken bool        `json:"hasRefreshToken"`
+	RefreshEnabled  bool        `json:"refreshEnabled"`
+	ExpiresAt       *time.Time  `json:"expiresAt,omitempty"`
+	Status          TokenStatus `json:"status"`
+}
+
+// GetOAuthTokensHealth returns the health of the tokens the user holds for each enabled OAuth provider they signed in with
+func (o *Service) GetOAuthTokensHealth(ctx context.Context, usr identity.Requester, sessionToken *auth.UserToken) ([]TokenHealth, error) {
+	ctx, span := o.tracer.Start(ctx, "oauthtoken.GetOAuthTokensHealth")
+	defer span.End()
+
+	result := make([]TokenHealth, 0)
+	if usr == nil || usr.IsNil() || !usr.IsIdentityType(claims.TypeUser) {
+		return result, nil
+	}
+
+	userID, err := usr.GetInternalID()
+	if err != nil {
+		return nil, err
+	}
+
+	for name, info := range o.SocialService.GetOAuthInfoProviders() {
+		authModule := "oauth_" + name
+		current := usr.GetAuthenticatedBy() == authModule
+
+		var token *oauth2.Token
+		if current && sessionToken != nil && o.features.IsEnabledGlobally(featuremgmt.FlagImprovedExternalSessionHandling) {
+			externalSession, err := o.sessionService.GetExternalSession(ctx, sessionToken.ExternalSessionId)
+			if err != nil && !errors.Is(err, auth.ErrExternalSessionNotFound) {
+				return nil, err
+			}
+			if externalSession != nil {
+				token = buildOAuthTokenFromExternalSession(externalSession)
+			}
+		} else {
+			authInfo, err := o.AuthInfoService.GetAuthInfo(ctx, &login.GetAuthInfoQuery{UserId: userID, AuthModule: authModule})
+			if err != nil && !errors.Is(err, user.ErrUserNotFound) {
+				return nil, err
+			}
+			if authInfo != nil {
+				token = buildOAuthTokenFromAuthInfo(authInfo)
+			}
+		}
+
+		// Skip providers the user never signed in with
+		if token == nil && !current {
+			continue
+		}
+
+		result = append(result, tokenHealth(name, current, token, info.UseRefreshToken, o.expiryDelta()))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Provider < result[j].Provider
+	})
+
+	return result, nil
+}
+
+func tokenHealth(provider string, current bool, token *oauth2.Token, refreshEnabled bool, expiryDelta time.Duration) TokenHealth {
+	health := TokenHealth{
+		Provider:       provider,
+		Current:        current,
+		RefreshEnabled: refreshEnabled,
+		Status:         TokenStatusMissing,
+	}
+	if token == nil || token.AccessToken == "" {
+		return health
+	}
+
+	health.HasAccessToken = true
+	health.HasRefreshToken = token.RefreshToken != ""
+	health.Status = TokenStatusValid
+
+	// The token expires with the earliest of the access and ID token expiry
+	expiry := token.Expiry
+	if idTokenExp, err := GetIDTokenExpiry(token); err == nil && !idTokenExp.IsZero() {
+		if expiry.IsZero() || idTokenExp.Before(expiry) {
+			expiry = idTokenExp
+		}
+	}
+	if expiry.IsZero() {
+		return health
+	}
+	health.ExpiresAt = &expiry
+
+	refreshable := refreshEnabled && health.HasRefreshToken
+	switch {
+	case expiry.Before(time.Now()) && !refreshable:
+		health.Status = TokenStatusExpired
+	case expiry.Add(-expiryDelta).Before(time.Now()):
+		health.Status = TokenStatusExpiring
+	}
+
+	return health
+}
@@ -0,0 +1,131 @@
+package oauthtoken
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	claims "github.com/grafana/authlib/types"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/login/social"
+	"github.com/grafana/grafana/pkg/login/social/socialtest"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/login/authinfotest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestTokenHealth(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          *oauth2.Token
+		refreshEnabled bool
+		expected       TokenStatus
+	}{
+		{
+			name:     "should be missing without access token",
+			token:    &oauth2.Token{RefreshToken: "refresh"},
+			expected: TokenStatusMissing,
+		},
+		{
+			name:     "should be valid without expiry",
+			token:    &oauth2.Token{AccessToken: "access"},
+			expected: TokenStatusValid,
+		},
+		{
+			name:     "should be valid when expiring after the refresh window",
+			token:    &oauth2.Token{AccessToken: "access", Expiry: time.Now().Add(time.Hour)},
+			expected: TokenStatusValid,
+		},
+		{
+			name:     "should be expiring within the refresh window",
+			token:    &oauth2.Token{AccessToken: "access", Expiry: time.Now().Add(30 * time.Second)},
+			expected: TokenStatusExpiring,
+		},
+		{
+			name:           "should be expiring when expired but refreshable",
+			token:          &oauth2.Token{AccessToken: "access", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)},
+			refreshEnabled: true,
+			expected:       TokenStatusExpiring,
+		},
+		{
+			name:     "should be expired when expired and refresh is disabled",
+			token:    &oauth2.Token{AccessToken: "access", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)},
+			expected: TokenStatusExpired,
+		},
+		{
+			name: "should be expired when the id token expired",
+			token: (&oauth2.Token{AccessToken: "access", Expiry: time.Now().Add(time.Hour)}).WithExtra(map[string]any{
+				"id_token": EXPIRED_ID_TOKEN,
+			}),
+			expected: TokenStatusExpired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health := tokenHealth("generic_oauth", true, tt.token, tt.refreshEnabled, time.Minute)
+			assert.Equal(t, tt.expected, health.Status)
+		})
+	}
+}
+
+func TestService_GetOAuthTokensHealth(t *testing.T) {
+	userIdentity := &authn.Identity{
+		AuthenticatedBy: login.GenericOAuthModule,
+		ID:              "1",
+		Type:            claims.TypeUser,
+	}
+
+	newService := func(authInfoService login.AuthInfoService) *Service {
+		return &Service{
+			Cfg:             setting.NewCfg(),
+			AuthInfoService: authInfoService,
+			SocialService: &socialtest.FakeSocialService{
+				ExpectedAuthInfoProviders: map[string]*social.OAuthInfo{
+					"generic_oauth": {UseRefreshToken: true},
+					"azuread":       {},
+				},
+			},
+			features: featuremgmt.WithFeatures(),
+			tracer:   tracing.InitializeTracerForTest(),
+		}
+	}
+
+	t.Run("should only report the current provider when the user has no tokens", func(t *testing.T) {
+		srv := newService(&authinfotest.FakeService{ExpectedError: user.ErrUserNotFound})
+
+		health, err := srv.GetOAuthTokensHealth(context.Background(), userIdentity, nil)
+		require.NoError(t, err)
+		require.Len(t, health, 1)
+		assert.Equal(t, "generic_oauth", health[0].Provider)
+		assert.True(t, health[0].Current)
+		assert.Equal(t, TokenStatusMissing, health[0].Status)
+	})
+
+	t.Run("should report each provider the user signed in with", func(t *testing.T) {
+		srv := newService(&authinfotest.FakeService{ExpectedUserAuth: &login.UserAuth{
+			OAuthAccessToken:  "access",
+			OAuthRefreshToken: "refresh",
+			OAuthExpiry:       time.Now().Add(time.Hour),
+		}})
+
+		health, err := srv.GetOAuthTokensHealth(context.Background(), userIdentity, nil)
+		require.NoError(t, err)
+		require.Len(t, health, 2)
+
+		assert.Equal(t, "azuread", health[0].Provider)
+		assert.False(t, health[0].Current)
+		assert.False(t, health[0].RefreshEnabled)
+
+		assert.Equal(t, "generic_oauth", health[1].Provider)
+		assert.True(t, health[1].Current)
+		assert.True(t, health[1].HasRefreshToken)
+		assert.Equal(t, TokenStatusValid, health[1].Status)
+	})
+}
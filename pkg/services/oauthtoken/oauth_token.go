@@ -57,6 +57,8 @@ type OAuthTokenService interface {
 	IsOAuthPassThruEnabled(*datasources.DataSource) bool
 	TryTokenRefresh(context.Context, identity.Requester, *auth.UserToken) (*oauth2.Token, error)
 	InvalidateOAuthTokens(context.Context, identity.Requester, *auth.UserToken) error
+	GetOAuthTokenForProvider(ctx context.Context, usr identity.Requester, sessionToken *auth.UserToken, provider string) *oauth2.Token
+	GetOAuthTokensHealth(context.Context, identity.Requester, *auth.UserToken) ([]TokenHealth, error)
 }
 
 func ProvideService(socialService social.Service, authInfoService login.AuthInfoService, cfg *setting.Cfg, registerer prometheus.Registerer,
@@ -139,7 +141,7 @@ func (o *Service) GetCurrentOAuthToken(ctx context.Context, usr identity.Request
 		persistedToken = buildOAuthTokenFromAuthInfo(authInfo)
 	}
 
-	refreshNeeded := needTokenRefresh(ctx, persistedToken)
+	refreshNeeded := needTokenRefresh(ctx, persistedToken, o.expiryDelta())
 	if !refreshNeeded {
 		return persistedToken
 	}
@@ -262,23 +264,10 @@ func (o *Service) TryTokenRefresh(ctx context.Context, usr identity.Requester, s
 		lockKey = fmt.Sprintf("oauth-refresh-token-%d-%d", userID, sessionToken.ExternalSessionId)
 	}
 
-	lockTimeConfig := serverlock.LockTimeConfig{
-		MaxInterval: 30 * time.Second,
-		MinWait:     time.Duration(o.Cfg.OAuthRefreshTokenServerLockMinWaitMs) * time.Millisecond,
-		MaxWait:     time.Duration(o.Cfg.OAuthRefreshTokenServerLockMinWaitMs+500) * time.Millisecond,
-	}
-
-	retryOpt := func(attempts int) error {
-		if attempts < 5 {
-			return nil
-		}
-		return ErrRetriesExhausted
-	}
-
 	var newToken *oauth2.Token
 	var cmdErr error
 
-	lockErr := o.serverLock.LockExecuteAndReleaseWithRetries(ctx, lockKey, lockTimeConfig, func(ctx context.Context) {
+	lockErr := o.serverLock.LockExecuteAndReleaseWithRetries(ctx, lockKey, o.lockTimeConfig(), func(ctx context.Context) {
 		ctx, span := o.tracer.Start(ctx, "oauthtoken server lock",
 			trace.WithAttributes(attribute.Int64("userID", userID)))
 		defer span.End()
@@ -312,7 +301,7 @@ func (o *Service) TryTokenRefresh(ctx context.Context, usr identity.Requester, s
 			persistedToken = buildOAuthTokenFromAuthInfo(authInfo)
 		}
 
-		needRefresh := needTokenRefresh(ctx, persistedToken)
+		needRefresh := needTokenRefresh(ctx, persistedToken, o.expiryDelta())
 		if !needRefresh {
 			// Set the token which is returned by the outer function in case there's no need to refresh the token
 			newToken = persistedToken
@@ -320,7 +309,7 @@ func (o *Service) TryTokenRefresh(ctx context.Context, usr identity.Requester, s
 		}
 
 		newToken, cmdErr = o.tryGetOrRefreshOAuthToken(ctx, persistedToken, usr, sessionToken)
-	}, retryOpt)
+	}, lockRetries)
 	if lockErr != nil {
 		ctxLogger.Error("Failed to obtain token refresh lock", "error", lockErr)
 		return nil, lockErr
@@ -334,6 +323,147 @@ func (o *Service) TryTokenRefresh(ctx context.Context, usr identity.Requester, s
 	return newToken, cmdErr
 }
 
+// GetOAuthTokenForProvider returns the OAuth token the user holds for the given provider, refreshing it if it is about
+// to expire. It allows forwarding the token of a provider other than the one the user signed in with, the tokens of
+// every provider the user has signed in with are kept in their auth info.
+func (o *Service) GetOAuthTokenForProvider(ctx context.Context, usr identity.Requester, sessionToken *auth.UserToken, provider string) *oauth2.Token {
+	ctx, span := o.tracer.Start(ctx, "oauthtoken.GetOAuthTokenForProvider")
+	defer span.End()
+
+	ctxLogger := logger.FromContext(ctx)
+
+	if usr == nil || usr.IsNil() || !usr.IsIdentityType(claims.TypeUser) {
+		ctxLogger.Warn("Can only get OAuth tokens for users")
+		return nil
+	}
+
+	authModule := "oauth_" + strings.TrimPrefix(provider, "oauth_")
+	if usr.GetAuthenticatedBy() == authModule {
+		return o.GetCurrentOAuthToken(ctx, usr, sessionToken)
+	}
+
+	userID, err := usr.GetInternalID()
+	if err != nil {
+		ctxLogger.Error("Failed to convert user id to int", "id", usr.GetID(), "error", err)
+		return nil
+	}
+
+	ctxLogger = ctxLogger.New("userID", userID, "authmodule", authModule)
+	span.SetAttributes(attribute.Int64("userID", userID), attribute.String("authmodule", authModule))
+
+	query := &login.GetAuthInfoQuery{UserId: userID, AuthModule: authModule}
+	authInfo, err := o.AuthInfoService.GetAuthInfo(ctx, query)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			ctxLogger.Debug("User has not signed in with the OAuth provider")
+			return nil
+		}
+		ctxLogger.Error("Failed to fetch oauth token for user", "error", err)
+		return nil
+	}
+
+	if authInfo.OAuthAccessToken == "" {
+		ctxLogger.Debug("No access token found for user")
+		return nil
+	}
+
+	persistedToken := buildOAuthTokenFromAuthInfo(authInfo)
+	if persistedToken.RefreshToken == "" || !needTokenRefresh(ctx, persistedToken, o.expiryDelta()) {
+		return persistedToken
+	}
+
+	oauthInfo := o.SocialService.GetOAuthInfoProvider(authModule)
+	if oauthInfo == nil || !oauthInfo.UseRefreshToken {
+		ctxLogger.Debug("Skipping token refresh")
+		return persistedToken
+	}
+
+	var newToken *oauth2.Token
+	var cmdErr error
+
+	lockKey := fmt.Sprintf("oauth-refresh-token-%d-%s", userID, authModule)
+	lockErr := o.serverLock.LockExecuteAndReleaseWithRetries(ctx, lockKey, o.lockTimeConfig(), func(ctx context.Context) {
+		// The token may have been refreshed by another instance while waiting for the lock
+		authInfo, err := o.AuthInfoService.GetAuthInfo(ctx, query)
+		if err != nil {
+			cmdErr = err
+			return
+		}
+
+		persistedToken := buildOAuthTokenFromAuthInfo(authInfo)
+		if !needTokenRefresh(ctx, persistedToken, o.expiryDelta()) {
+			newToken = persistedToken
+			return
+		}
+
+		newToken, cmdErr = o.refreshAuthInfoToken(ctx, authInfo, persistedToken)
+	}, lockRetries)
+	if lockErr != nil {
+		ctxLogger.Error("Failed to obtain token refresh lock", "error", lockErr)
+		return nil
+	}
+
+	if cmdErr != nil {
+		ctxLogger.Error("Failed to refresh OAuth token", "error", cmdErr)
+		return nil
+	}
+
+	return newToken
+}
+
+// refreshAuthInfoToken refreshes the token stored in the auth info of a user and persists the result
+func (o *Service) refreshAuthInfoToken(ctx context.Context, authInfo *login.UserAuth, persistedToken *oauth2.Token) (*oauth2.Token, error) {
+	ctx, span := o.tracer.Start(ctx, "oauthtoken.refreshAuthInfoToken")
+	defer span.End()
+
+	ctxLogger := logger.FromContext(ctx).New("userID", authInfo.UserId, "authmodule", authInfo.AuthModule)
+
+	connect, err := o.SocialService.GetConnector(authInfo.AuthModule)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := o.SocialService.GetOAuthHttpClient(authInfo.AuthModule)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, client)
+
+	start := time.Now()
+	token, err := connect.TokenSource(ctx, persistedToken).Token()
+	o.tokenRefreshDuration.WithLabelValues(authInfo.AuthModule, fmt.Sprintf("%t", err == nil)).Observe(time.Since(start).Seconds())
+
+	updateAuthCommand := &login.UpdateAuthInfoCommand{
+		UserId:     authInfo.UserId,
+		AuthModule: authInfo.AuthModule,
+		AuthId:     authInfo.AuthId,
+		OAuthToken: token,
+	}
+
+	if err != nil {
+		span.SetAttributes(attribute.Bool("token_refreshed", false))
+
+		// token refresh failed, invalidate the old token so it's no longer forwarded
+		updateAuthCommand.OAuthToken = &oauth2.Token{}
+		if err := o.AuthInfoService.UpdateAuthInfo(ctx, updateAuthCommand); err != nil {
+			ctxLogger.Warn("Failed to invalidate OAuth tokens", "authID", authInfo.AuthId, "error", err)
+		}
+
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Bool("token_refreshed", true))
+
+	if !tokensEq(persistedToken, token) {
+		if err := o.AuthInfoService.UpdateAuthInfo(ctx, updateAuthCommand); err != nil {
+			return nil, err
+		}
+		ctxLogger.Debug("Updated oauth info for user")
+	}
+
+	return token, nil
+}
+
 // InvalidateOAuthTokens invalidates the OAuth tokens (access_token, refresh_token) and sets the Expiry to default/zero
 func (o *Service) InvalidateOAuthTokens(ctx context.Context, usr identity.Requester, sessionToken *auth.UserToken) error {
 	userID, err := usr.GetInternalID()
@@ -385,7 +515,7 @@ func (o *Service) tryGetOrRefreshOAuthToken(ctx context.Context, persistedToken
 		return nil, ErrNoRefreshTokenFound
 	}
 
-	refreshNeeded := needTokenRefresh(ctx, persistedToken)
+	refreshNeeded := needTokenRefresh(ctx, persistedToken, o.expiryDelta())
 	if !refreshNeeded {
 		return persistedToken, nil
 	}
@@ -468,6 +598,38 @@ func IsOAuthPassThruEnabled(ds *datasources.DataSource) bool {
 	return ds.JsonData != nil && ds.JsonData.Get("oauthPassThru").MustBool()
 }
 
+// PassThruProvider returns the OAuth provider whose token is forwarded to the provided data source. An empty
+// provider means the token of the provider the user signed in with.
+func PassThruProvider(ds *datasources.DataSource) string {
+	if ds.JsonData == nil {
+		return ""
+	}
+	return ds.JsonData.Get("oauthPassThruProvider").MustString()
+}
+
+// expiryDelta returns how long before their expiry tokens are refreshed
+func (o *Service) expiryDelta() time.Duration {
+	if o.Cfg.OAuthTokenRefreshWindow > ExpiryDelta {
+		return o.Cfg.OAuthTokenRefreshWindow
+	}
+	return ExpiryDelta
+}
+
+func (o *Service) lockTimeConfig() serverlock.LockTimeConfig {
+	return serverlock.LockTimeConfig{
+		MaxInterval: 30 * time.Second,
+		MinWait:     time.Duration(o.Cfg.OAuthRefreshTokenServerLockMinWaitMs) * time.Millisecond,
+		MaxWait:     time.Duration(o.Cfg.OAuthRefreshTokenServerLockMinWaitMs+500) * time.Millisecond,
+	}
+}
+
+func lockRetries(attempts int) error {
+	if attempts < 5 {
+		return nil
+	}
+	return ErrRetriesExhausted
+}
+
 func newTokenRefreshDurationMetric(registerer prometheus.Registerer) *prometheus.HistogramVec {
 	tokenRefreshDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		Namespace: "grafana",
@@ -495,7 +657,7 @@ func tokensEq(t1, t2 *oauth2.Token) bool {
 		t1IdToken == t2IdToken
 }
 
-func needTokenRefresh(ctx context.Context, persistedToken *oauth2.Token) bool {
+func needTokenRefresh(ctx context.Context, persistedToken *oauth2.Token, expiryDelta time.Duration) bool {
 	var hasAccessTokenExpired, hasIdTokenExpired bool
 
 	ctxLogger := logger.FromContext(ctx)
@@ -505,10 +667,10 @@ func needTokenRefresh(ctx context.Context, persistedToken *oauth2.Token) bool {
 		ctxLogger.Warn("Could not get ID Token expiry", "error", err)
 	}
 	if !persistedToken.Expiry.IsZero() {
-		_, hasAccessTokenExpired = getExpiryWithSkew(persistedToken.Expiry)
+		_, hasAccessTokenExpired = getExpiryWithSkew(persistedToken.Expiry, expiryDelta)
 	}
 	if !idTokenExp.IsZero() {
-		_, hasIdTokenExpired = getExpiryWithSkew(idTokenExp)
+		_, hasIdTokenExpired = getExpiryWithSkew(idTokenExp, expiryDelta)
 	}
 	if !hasAccessTokenExpired && !hasIdTokenExpired {
 		ctxLogger.Debug("Neither access nor id token have expired yet")
@@ -593,8 +755,8 @@ func GetIDTokenExpiry(token *oauth2.Token) (time.Time, error) {
 	return time.Unix(claims.Exp, 0), nil
 }
 
-func getExpiryWithSkew(expiry time.Time) (adjustedExpiry time.Time, hasTokenExpired bool) {
-	adjustedExpiry = expiry.Round(0).Add(-ExpiryDelta)
+func getExpiryWithSkew(expiry time.Time, expiryDelta time.Duration) (adjustedExpiry time.Time, hasTokenExpired bool) {
+	adjustedExpiry = expiry.Round(0).Add(-expiryDelta)
 	hasTokenExpired = adjustedExpiry.Before(time.Now())
 	return
 }
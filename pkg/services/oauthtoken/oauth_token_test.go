@@ -9,6 +9,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/oauth2"
 
 	claims "github.com/grafana/authlib/types"
@@ -25,6 +26,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/login/authinfotest"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tests/testsuite"
 )
@@ -674,9 +676,60 @@ func TestOAuthTokenSync_needTokenRefresh(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			token := buildOAuthTokenFromAuthInfo(tt.usr)
-			needsTokenRefresh := needTokenRefresh(context.Background(), token)
+			needsTokenRefresh := needTokenRefresh(context.Background(), token, ExpiryDelta)
 
 			assert.Equal(t, tt.expectedTokenRefreshFlag, needsTokenRefresh)
 		})
 	}
 }
+
+func TestService_GetOAuthTokenForProvider(t *testing.T) {
+	userIdentity := &authn.Identity{
+		AuthenticatedBy: login.GenericOAuthModule,
+		ID:              "1",
+		Type:            claims.TypeUser,
+	}
+
+	newService := func(authInfoService *authinfotest.FakeService) *Service {
+		return &Service{
+			Cfg:             setting.NewCfg(),
+			AuthInfoService: authInfoService,
+			SocialService:   &socialtest.FakeSocialService{},
+			features:        featuremgmt.WithFeatures(),
+			tracer:          tracing.InitializeTracerForTest(),
+		}
+	}
+
+	t.Run("should return the token of the provider", func(t *testing.T) {
+		expiry := time.Now().Add(time.Hour)
+		srv := newService(&authinfotest.FakeService{ExpectedUserAuth: &login.UserAuth{
+			AuthModule:        login.AzureADAuthModule,
+			OAuthAccessToken:  "access",
+			OAuthRefreshToken: "refresh",
+			OAuthExpiry:       expiry,
+		}})
+
+		token := srv.GetOAuthTokenForProvider(context.Background(), userIdentity, nil, "azuread")
+		require.NotNil(t, token)
+		assert.Equal(t, "access", token.AccessToken)
+		assert.Equal(t, expiry, token.Expiry)
+	})
+
+	t.Run("should return nil when the user has not signed in with the provider", func(t *testing.T) {
+		srv := newService(&authinfotest.FakeService{ExpectedError: user.ErrUserNotFound})
+
+		assert.Nil(t, srv.GetOAuthTokenForProvider(context.Background(), userIdentity, nil, "azuread"))
+	})
+
+	t.Run("should return the expired token when it can't be refreshed", func(t *testing.T) {
+		srv := newService(&authinfotest.FakeService{ExpectedUserAuth: &login.UserAuth{
+			AuthModule:       login.AzureADAuthModule,
+			OAuthAccessToken: "access",
+			OAuthExpiry:      time.Now().Add(-time.Hour),
+		}})
+
+		token := srv.GetOAuthTokenForProvider(context.Background(), userIdentity, nil, "azuread")
+		require.NotNil(t, token)
+		assert.Equal(t, "access", token.AccessToken)
+	})
+}
@@ -8,13 +8,16 @@ import (
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/oauthtoken"
 )
 
 type MockOauthTokenService struct {
-	GetCurrentOauthTokenFunc   func(ctx context.Context, usr identity.Requester, sessionToken *auth.UserToken) *oauth2.Token
-	IsOAuthPassThruEnabledFunc func(ds *datasources.DataSource) bool
-	InvalidateOAuthTokensFunc  func(ctx context.Context, usr identity.Requester, sessionToken *auth.UserToken) error
-	TryTokenRefreshFunc        func(ctx context.Context, usr identity.Requester, sessionToken *auth.UserToken) (*oauth2.Token, error)
+	GetCurrentOauthTokenFunc     func(ctx context.Context, usr identity.Requester, sessionToken *auth.UserToken) *oauth2.Token
+	IsOAuthPassThruEnabledFunc   func(ds *datasources.DataSource) bool
+	InvalidateOAuthTokensFunc    func(ctx context.Context, usr identity.Requester, sessionToken *auth.UserToken) error
+	TryTokenRefreshFunc          func(ctx context.Context, usr identity.Requester, sessionToken *auth.UserToken) (*oauth2.Token, error)
+	GetOAuthTokenForProviderFunc func(ctx context.Context, usr identity.Requester, sessionToken *auth.UserToken, provider string) *oauth2.Token
+	GetOAuthTokensHealthFunc     func(ctx context.Context, usr identity.Requester, sessionToken *auth.UserToken) ([]oauthtoken.TokenHealth, error)
 }
 
 func (m *MockOauthTokenService) GetCurrentOAuthToken(ctx context.Context, usr identity.Requester, sessionToken *auth.UserToken) *oauth2.Token {
@@ -44,3 +47,17 @@ func (m *MockOauthTokenService) TryTokenRefresh(ctx context.Context, usr identit
 	}
 	return nil, nil
 }
+
+func (m *MockOauthTokenService) GetOAuthTokenForProvider(ctx context.Context, usr identity.Requester, sessionToken *auth.UserToken, provider string) *oauth2.Token {
+	if m.GetOAuthTokenForProviderFunc != nil {
+		return m.GetOAuthTokenForProviderFunc(ctx, usr, sessionToken, provider)
+	}
+	return nil
+}
+
+func (m *MockOauthTokenService) GetOAuthTokensHealth(ctx context.Context, usr identity.Requester, sessionToken *auth.UserToken) ([]oauthtoken.TokenHealth, error) {
+	if m.GetOAuthTokensHealthFunc != nil {
+		return m.GetOAuthTokensHealthFunc(ctx, usr, sessionToken)
+	}
+	return nil, nil
+}
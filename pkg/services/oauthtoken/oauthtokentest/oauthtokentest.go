@@ -13,7 +13,8 @@ import (
 
 // Service an OAuth token service suitable for tests.
 type Service struct {
-	Token *oauth2.Token
+	Token  *oauth2.Token
+	Health []oauthtoken.TokenHealth
 }
 
 // ProvideService provides an OAuth token service suitable for tests.
@@ -36,3 +37,11 @@ func (s *Service) TryTokenRefresh(context.Context, identity.Requester, *auth.Use
 func (s *Service) InvalidateOAuthTokens(context.Context, identity.Requester, *auth.UserToken) error {
 	return nil
 }
+
+func (s *Service) GetOAuthTokenForProvider(context.Context, identity.Requester, *auth.UserToken, string) *oauth2.Token {
+	return s.Token
+}
+
+func (s *Service) GetOAuthTokensHealth(context.Context, identity.Requester, *auth.UserToken) ([]oauthtoken.TokenHealth, error) {
+	return s.Health, nil
+}
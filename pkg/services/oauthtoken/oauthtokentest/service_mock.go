@@ -12,6 +12,8 @@ import (
 
 	oauth2 "golang.org/x/oauth2"
 
+	oauthtoken "github.com/grafana/grafana/pkg/services/oauthtoken"
+
 	usertoken "github.com/grafana/grafana/pkg/models/usertoken"
 )
 
@@ -40,6 +42,56 @@ func (_m *MockService) GetCurrentOAuthToken(_a0 context.Context, _a1 identity.Re
 	return r0
 }
 
+// GetOAuthTokenForProvider provides a mock function with given fields: ctx, usr, sessionToken, provider
+func (_m *MockService) GetOAuthTokenForProvider(ctx context.Context, usr identity.Requester, sessionToken *usertoken.UserToken, provider string) *oauth2.Token {
+	ret := _m.Called(ctx, usr, sessionToken, provider)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOAuthTokenForProvider")
+	}
+
+	var r0 *oauth2.Token
+	if rf, ok := ret.Get(0).(func(context.Context, identity.Requester, *usertoken.UserToken, string) *oauth2.Token); ok {
+		r0 = rf(ctx, usr, sessionToken, provider)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*oauth2.Token)
+		}
+	}
+
+	return r0
+}
+
+// GetOAuthTokensHealth provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockService) GetOAuthTokensHealth(_a0 context.Context, _a1 identity.Requester, _a2 *usertoken.UserToken) ([]oauthtoken.TokenHealth, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOAuthTokensHealth")
+	}
+
+	var r0 []oauthtoken.TokenHealth
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, identity.Requester, *usertoken.UserToken) ([]oauthtoken.TokenHealth, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, identity.Requester, *usertoken.UserToken) []oauthtoken.TokenHealth); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]oauthtoken.TokenHealth)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, identity.Requester, *usertoken.UserToken) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // InvalidateOAuthTokens provides a mock function with given fields: _a0, _a1, _a2
 func (_m *MockService) InvalidateOAuthTokens(_a0 context.Context, _a1 identity.Requester, _a2 *usertoken.UserToken) error {
 	ret := _m.Called(_a0, _a1, _a2)
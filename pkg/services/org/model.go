@@ -32,6 +32,9 @@ type Org struct {
 	State    string
 	Country  string
 
+	// Archived organizations are read-only and hidden from the organization switchers
+	Archived bool
+
 	Created time.Time
 	Updated time.Time
 }
@@ -70,6 +73,8 @@ type GetOrgIDForNewUserCommand struct {
 
 type GetUserOrgListQuery struct {
 	UserID int64 `xorm:"user_id"`
+	// ExcludeArchived leaves out the archived organizations
+	ExcludeArchived bool `xorm:"-"`
 }
 
 type UserOrgDTO struct {
@@ -92,8 +97,9 @@ type SearchOrgsQuery struct {
 }
 
 type OrgDTO struct {
-	ID   int64  `json:"id" xorm:"id"`
-	Name string `json:"name"`
+	ID       int64  `json:"id" xorm:"id"`
+	Name     string `json:"name"`
+	Archived bool   `json:"archived"`
 }
 
 type GetOrgByIDQuery struct {
@@ -202,9 +208,10 @@ type SearchOrgUsersQueryResult struct {
 type ByOrgName []*UserOrgDTO
 
 type OrgDetailsDTO struct {
-	ID      int64   `json:"id"`
-	Name    string  `json:"name"`
-	Address Address `json:"address"`
+	ID       int64   `json:"id"`
+	Name     string  `json:"name"`
+	Address  Address `json:"address"`
+	Archived bool    `json:"archived"`
 }
 
 // Len returns the length of an array of organisations.
@@ -116,6 +116,12 @@ func (ss *sqlStore) InsertOrgUser(ctx context.Context, cmd *org.OrgUser) (int64,
 		if _, err = sess.Insert(cmd); err != nil {
 			return err
 		}
+		sess.PublishAfterCommit(&events.OrgUserAdded{
+			Timestamp: cmd.Created,
+			OrgID:     cmd.OrgID,
+			UserID:    cmd.UserID,
+			Role:      string(cmd.Role),
+		})
 		return nil
 	})
 	if err != nil {
@@ -221,6 +227,7 @@ func (ss *sqlStore) Delete(ctx context.Context, cmd *org.DeleteOrgCommand) error
 
 		deletes := []string{
 			"DELETE FROM star WHERE org_id = ?",
+			"DELETE FROM star_resource WHERE org_id = ?",
 			"DELETE FROM playlist_item WHERE playlist_id IN (SELECT id FROM playlist WHERE org_id = ?)",
 			"DELETE FROM playlist WHERE org_id = ?",
 			"DELETE FROM dashboard_tag WHERE org_id = ?",
@@ -245,6 +252,25 @@ func (ss *sqlStore) Delete(ctx context.Context, cmd *org.DeleteOrgCommand) error
 			"DELETE FROM team_role WHERE org_id = ?",
 			"DELETE FROM user_role WHERE org_id = ?",
 			"DELETE FROM builtin_role WHERE org_id = ?",
+			"DELETE FROM org_mfa_policy WHERE org_id = ?",
+			"DELETE FROM service_account_token_policy WHERE org_id = ?",
+			"DELETE FROM org_impersonation_settings WHERE org_id = ?",
+			"DELETE FROM org_ip_allowlist WHERE org_id = ?",
+			"DELETE FROM api_key_ip_allowlist WHERE org_id = ?",
+			"DELETE FROM permission WHERE role_id IN (SELECT id FROM role WHERE org_id = ? AND name LIKE 'custom:%')",
+			"DELETE FROM role WHERE org_id = ? AND name LIKE 'custom:%'",
+			"DELETE FROM permission WHERE role_id IN (SELECT id FROM role WHERE org_id = ? AND name LIKE 'temporary:%')",
+			"DELETE FROM role WHERE org_id = ? AND name LIKE 'temporary:%'",
+			"DELETE FROM temporary_grant WHERE org_id = ?",
+			"DELETE FROM capability_token WHERE org_id = ?",
+			"DELETE FROM org_auth_policy WHERE org_id = ?",
+			"DELETE FROM anon_org_settings WHERE org_id = ?",
+			"DELETE FROM anon_device WHERE org_id = ?",
+			"DELETE FROM api_key_usage WHERE org_id = ?",
+			"DELETE FROM live_channel_rule WHERE org_id = ?",
+			"DELETE FROM live_write_config WHERE org_id = ?",
+			"DELETE FROM saved_search WHERE org_id = ?",
+			"DELETE FROM branding WHERE org_id = ?",
 		}
 
 		// Add registered deletes
@@ -270,6 +296,9 @@ func (ss *sqlStore) GetUserOrgList(ctx context.Context, query *org.GetUserOrgLis
 		sess.Join("INNER", ss.dialect.Quote("user"), fmt.Sprintf("org_user.user_id=%s.id", ss.dialect.Quote("user")))
 		sess.Where("org_user.user_id=?", query.UserID)
 		sess.Where(ss.notServiceAccountFilter())
+		if query.ExcludeArchived {
+			sess.Where("org.archived = " + ss.dialect.BooleanStr(false))
+		}
 		sess.Cols("org.name", "org_user.role", "org_user.org_id")
 		sess.OrderBy("org.name")
 		err := sess.Find(&result)
@@ -307,7 +336,7 @@ func (ss *sqlStore) Search(ctx context.Context, query *org.SearchOrgsQuery) ([]*
 			sess.Limit(query.Limit, query.Limit*query.Page)
 		}
 
-		sess.Cols("id", "name")
+		sess.Cols("id", "name", "archived")
 		err := sess.Find(&result)
 		return err
 	})
@@ -397,6 +426,12 @@ func (ss *sqlStore) AddOrgUser(ctx context.Context, cmd *org.AddOrgUserCommand)
 		if err != nil {
 			return err
 		}
+		sess.PublishAfterCommit(&events.OrgUserAdded{
+			Timestamp: entity.Created,
+			OrgID:     entity.OrgID,
+			UserID:    entity.UserID,
+			Role:      string(entity.Role),
+		})
 
 		var userOrgs []*org.UserOrgDTO
 		sess.Table("org_user")
@@ -823,6 +858,7 @@ func deleteUserAccessControl(sess *db.Session, userID int64) error {
 func (ss *sqlStore) userDeletions() []string {
 	deletes := []string{
 		"DELETE FROM star WHERE user_id = ?",
+		"DELETE FROM star_resource WHERE user_id = ?",
 		"DELETE FROM " + ss.dialect.Quote("user") + " WHERE id = ?",
 		"DELETE FROM org_user WHERE user_id = ?",
 		"DELETE FROM dashboard_acl WHERE user_id = ?",
@@ -831,6 +867,7 @@ func (ss *sqlStore) userDeletions() []string {
 		"DELETE FROM user_auth WHERE user_id = ?",
 		"DELETE FROM user_auth_token WHERE user_id = ?",
 		"DELETE FROM quota WHERE user_id = ?",
+		"DELETE FROM user_mfa WHERE user_id = ?",
 	}
 	return deletes
 }
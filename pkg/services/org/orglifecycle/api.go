@@ -0,0 +1,310 @@
+package orglifecycle
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister) {
+	router.Group("/api/admin/orgs/:orgId", func(orgRoute routing.RouteRegister) {
+		orgRoute.Post("/archive", routing.Wrap(s.PostArchiveOrg))
+		orgRoute.Delete("/archive", routing.Wrap(s.DeleteArchiveOrg))
+		orgRoute.Post("/export", routing.Wrap(s.PostExportOrg))
+		orgRoute.Post("/merge", routing.Wrap(s.PostMergeOrg))
+	}, middleware.ReqGrafanaAdmin)
+
+	router.Group("/api/admin/org-jobs", func(jobsRoute routing.RouteRegister) {
+		jobsRoute.Get("/", routing.Wrap(s.GetOrgJobs))
+		jobsRoute.Get("/:jobId", routing.Wrap(s.GetOrgJob))
+		jobsRoute.Post("/:jobId/resume", routing.Wrap(s.PostResumeOrgJob))
+		jobsRoute.Get("/:jobId/bundle", routing.Wrap(s.DownloadOrgBundle))
+		jobsRoute.Delete("/:jobId", routing.Wrap(s.DeleteOrgJob))
+	}, middleware.ReqGrafanaAdmin)
+}
+
+// swagger:route POST /admin/orgs/{org_id}/archive admin_orgs archiveOrg
+//
+// Archive an organization.
+//
+// Starts a job making the organization read-only and hiding it from the organization switchers. The users whose
+// current organization is the archived organization are switched to another one of their organizations.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 202: orgJobResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+func (s *Service) PostArchiveOrg(c *contextmodel.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+
+	job, err := s.StartArchive(c.Req.Context(), orgID, c.GetLogin())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to archive organization", err)
+	}
+	return response.JSON(http.StatusAccepted, job)
+}
+
+// swagger:route DELETE /admin/orgs/{org_id}/archive admin_orgs unarchiveOrg
+//
+// Unarchive an organization.
+//
+// Makes the organization writable and visible in the organization switchers again.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+func (s *Service) DeleteArchiveOrg(c *contextmodel.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+
+	if err := s.Unarchive(c.Req.Context(), orgID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to unarchive organization", err)
+	}
+	return response.Success("Organization unarchived")
+}
+
+// swagger:route POST /admin/orgs/{org_id}/export admin_orgs exportOrg
+//
+// Export an organization.
+//
+// Starts a job writing the folders, dashboards, data sources, alert rules, teams and users of the organization into
+// a zip bundle. The secrets of the data sources aren't exported. Download the bundle once the job is completed.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 202: orgJobResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+func (s *Service) PostExportOrg(c *contextmodel.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+
+	job, err := s.StartExport(c.Req.Context(), orgID, c.GetLogin())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to export organization", err)
+	}
+	return response.JSON(http.StatusAccepted, job)
+}
+
+// swagger:route POST /admin/orgs/{org_id}/merge admin_orgs mergeOrg
+//
+// Merge an organization into another one.
+//
+// Starts a job copying the users, teams, data sources, folders and dashboards of the organization into the target
+// organization. The collisions of UIDs and the duplicate data sources are resolved with the merge options, and
+// reported in the conflicts of the job.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 202: orgJobResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+func (s *Service) PostMergeOrg(c *contextmodel.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+	cmd := StartMergeCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	job, err := s.StartMerge(c.Req.Context(), orgID, cmd, c.GetLogin())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to merge organization", err)
+	}
+	return response.JSON(http.StatusAccepted, job)
+}
+
+// swagger:route GET /admin/org-jobs admin_orgs getOrgJobs
+//
+// List the organization jobs.
+//
+// Returns the archive, export and merge jobs, the most recent first.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: orgJobsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) GetOrgJobs(c *contextmodel.ReqContext) response.Response {
+	jobs, err := s.ListJobs(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list organization jobs", err)
+	}
+	return response.JSON(http.StatusOK, jobs)
+}
+
+// swagger:route GET /admin/org-jobs/{job_id} admin_orgs getOrgJob
+//
+// Get the progress of an organization job.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: orgJobResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+func (s *Service) GetOrgJob(c *contextmodel.ReqContext) response.Response {
+	job, err := s.GetJob(c.Req.Context(), web.Params(c.Req)[":jobId"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get organization job", err)
+	}
+	return response.JSON(http.StatusOK, job)
+}
+
+// swagger:route POST /admin/org-jobs/{job_id}/resume admin_orgs resumeOrgJob
+//
+// Resume a failed or interrupted organization job.
+//
+// The job runs again from its first step which isn't completed. The resources already copied by a merge aren't
+// copied again.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 202: orgJobResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+func (s *Service) PostResumeOrgJob(c *contextmodel.ReqContext) response.Response {
+	job, err := s.ResumeJob(c.Req.Context(), web.Params(c.Req)[":jobId"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to resume organization job", err)
+	}
+	return response.JSON(http.StatusAccepted, job)
+}
+
+// swagger:route GET /admin/org-jobs/{job_id}/bundle admin_orgs downloadOrgBundle
+//
+// Download the bundle of an organization export.
+//
+// Produces:
+// - application/zip
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: downloadOrgBundleResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+func (s *Service) DownloadOrgBundle(c *contextmodel.ReqContext) response.Response {
+	content, job, err := s.OpenBundle(c.Req.Context(), web.Params(c.Req)[":jobId"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to open bundle", err)
+	}
+	defer func() { _ = content.Close() }()
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to read bundle", err)
+	}
+	return response.Respond(http.StatusOK, data).
+		SetHeader("Content-Type", "application/zip").
+		SetHeader("Content-Disposition", fmt.Sprintf(`attachment;filename="org-%d-%s.zip"`, job.OrgID, job.ID))
+}
+
+// swagger:route DELETE /admin/org-jobs/{job_id} admin_orgs deleteOrgJob
+//
+// Delete an organization job which isn't running, with the bundle of an export.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+func (s *Service) DeleteOrgJob(c *contextmodel.ReqContext) response.Response {
+	if err := s.DeleteJob(c.Req.Context(), web.Params(c.Req)[":jobId"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete organization job", err)
+	}
+	return response.Success("Organization job deleted")
+}
+
+// swagger:parameters archiveOrg unarchiveOrg exportOrg mergeOrg
+type OrgLifecycleOrgIDParam struct {
+	// in:path
+	// required:true
+	OrgID int64 `json:"org_id"`
+}
+
+// swagger:parameters mergeOrg
+type MergeOrgParams struct {
+	// in:body
+	// required:true
+	Body StartMergeCommand `json:"body"`
+}
+
+// swagger:parameters getOrgJob resumeOrgJob downloadOrgBundle deleteOrgJob
+type OrgJobIDParam struct {
+	// in:path
+	// required:true
+	JobID string `json:"job_id"`
+}
+
+// swagger:response orgJobsResponse
+type OrgJobsResponse struct {
+	// in:body
+	Body []*Job `json:"body"`
+}
+
+// swagger:response orgJobResponse
+type OrgJobResponse struct {
+	// in:body
+	Body *Job `json:"body"`
+}
+
+// swagger:response downloadOrgBundleResponse
+type DownloadOrgBundleResponse struct {
+	// in:body
+	Body []byte `json:"body"`
+}
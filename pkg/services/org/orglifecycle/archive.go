@@ -0,0 +1,113 @@
+package orglifecycle
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	claims "github.com/grafana/authlib/types"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+// writablePaths are the API paths which accept changes in archived orgs: the preferences and orgs of
+// the signed in user, the server admin API, and the data source queries which are sent as POST requests
+var writablePaths = []string{
+	"/api/user/",
+	"/api/admin/",
+	"/api/ds/query",
+	"/api/datasources/proxy/",
+	"/api/frontend-metrics",
+	"/api/live/",
+}
+
+func (s *Service) setArchived(ctx context.Context, orgID int64, archived bool) error {
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE org SET archived = ?, updated = ? WHERE id = ?", archived, time.Now(), orgID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.archived.Delete(fmt.Sprintf("org-%d", orgID))
+	s.log.Info("Organization archived state changed", "orgId", orgID, "archived", archived)
+	return nil
+}
+
+// moveUsers switches the users whose current org is the archived org to the first other org they're a
+// member of. The users who aren't members of another org keep a read-only access to the archived org.
+func (s *Service) moveUsers(ctx context.Context, orgID int64) (int, error) {
+	userTable := s.sqlStore.GetDialect().Quote("user")
+
+	var userIDs []int64
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL(fmt.Sprintf("SELECT id FROM %s WHERE org_id = ? AND is_service_account = ?", userTable), orgID, false).Find(&userIDs)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, userID := range userIDs {
+		orgs, err := s.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: userID, ExcludeArchived: true})
+		if err != nil {
+			return moved, err
+		}
+		if len(orgs) == 0 {
+			continue
+		}
+
+		err = s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Exec(fmt.Sprintf("UPDATE %s SET org_id = ? WHERE id = ? AND org_id = ?", userTable), orgs[0].OrgID, userID, orgID)
+			return err
+		})
+		if err != nil {
+			return moved, err
+		}
+		moved++
+	}
+	return moved, nil
+}
+
+// readOnlyHook rejects the API requests changing the resources of archived orgs
+func (s *Service) readOnlyHook(ctx context.Context, id *authn.Identity, r *authn.Request) error {
+	if r.HTTPRequest == nil || !id.IsIdentityType(claims.TypeUser, claims.TypeServiceAccount, claims.TypeAPIKey, claims.TypeAnonymous) {
+		return nil
+	}
+	if !isChange(r.HTTPRequest) {
+		return nil
+	}
+
+	archived, err := s.IsArchived(ctx, id.GetOrgID())
+	if err != nil {
+		return err
+	}
+	if archived {
+		return ErrOrgArchived.Errorf("org %d is archived, %s %s rejected", id.GetOrgID(), r.HTTPRequest.Method, r.HTTPRequest.URL.Path)
+	}
+	return nil
+}
+
+// isChange reports whether the request changes resources of the org
+func isChange(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+
+	path := r.URL.Path
+	if !strings.HasPrefix(path, "/api/") {
+		return false
+	}
+	for _, prefix := range writablePaths {
+		if strings.HasPrefix(path, prefix) || path == strings.TrimSuffix(prefix, "/") {
+			return false
+		}
+	}
+	return true
+}
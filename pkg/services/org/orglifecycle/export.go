@@ -0,0 +1,407 @@
+package orglifecycle
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/compat"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+)
+
+const (
+	bundleVersion = 1
+	// teamsPageSize is the size of the pages of teams read from the orgs
+	teamsPageSize = 500
+)
+
+const (
+	stepFolders     = "folders"
+	stepDashboards  = "dashboards"
+	stepDataSources = "datasources"
+	stepAlertRules  = "alert-rules"
+	stepTeams       = "teams"
+	stepUsers       = "users"
+	stepBundle      = "bundle"
+)
+
+// exportSteps write each kind of resource of the org to a JSON file, then zip the files into the bundle
+var exportSteps = []string{stepFolders, stepDashboards, stepDataSources, stepAlertRules, stepTeams, stepUsers, stepBundle}
+
+// BundleManifest describes the content of an export bundle
+type BundleManifest struct {
+	Version    int            `json:"version"`
+	OrgID      int64          `json:"orgId"`
+	OrgName    string         `json:"orgName"`
+	ExportedAt time.Time      `json:"exportedAt"`
+	Resources  map[string]int `json:"resources"`
+}
+
+type folderEntry struct {
+	UID         string `json:"uid"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	ParentUID   string `json:"parentUid,omitempty"`
+}
+
+type dashboardEntry struct {
+	UID       string         `json:"uid"`
+	Title     string         `json:"title"`
+	FolderUID string         `json:"folderUid,omitempty"`
+	Dashboard map[string]any `json:"dashboard"`
+}
+
+// dataSourceEntry is a data source without its secrets, SecureFields lists the secrets to set on import
+type dataSourceEntry struct {
+	UID             string               `json:"uid"`
+	Name            string               `json:"name"`
+	Type            string               `json:"type"`
+	Access          datasources.DsAccess `json:"access"`
+	URL             string               `json:"url"`
+	User            string               `json:"user,omitempty"`
+	Database        string               `json:"database,omitempty"`
+	BasicAuth       bool                 `json:"basicAuth"`
+	BasicAuthUser   string               `json:"basicAuthUser,omitempty"`
+	WithCredentials bool                 `json:"withCredentials"`
+	IsDefault       bool                 `json:"isDefault"`
+	JsonData        *simplejson.Json     `json:"jsonData,omitempty"`
+	SecureFields    []string             `json:"secureFields,omitempty"`
+}
+
+type teamEntry struct {
+	Name    string            `json:"name"`
+	Email   string            `json:"email,omitempty"`
+	Members []teamMemberEntry `json:"members"`
+}
+
+type teamMemberEntry struct {
+	Login string `json:"login"`
+	Email string `json:"email"`
+	Admin bool   `json:"admin"`
+}
+
+type userEntry struct {
+	Login string `json:"login"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+	Role  string `json:"role"`
+}
+
+func (s *Service) exportDir(job *Job) string {
+	return filepath.Join(s.bundleDir, job.ID)
+}
+
+func (s *Service) bundlePath(job *Job) string {
+	return filepath.Join(s.bundleDir, job.ID+".zip")
+}
+
+// OpenBundle opens the bundle of a completed export
+func (s *Service) OpenBundle(ctx context.Context, jobID string) (*os.File, *Job, error) {
+	job, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Kind != JobKindExport || job.State != JobCompleted {
+		return nil, nil, ErrBundleNotFound.Errorf("job %s isn't a completed export", job.ID)
+	}
+
+	f, err := os.Open(s.bundlePath(job))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, ErrBundleNotFound.Errorf("the bundle of job %s was deleted", job.ID)
+		}
+		return nil, nil, err
+	}
+	return f, job, nil
+}
+
+// export runs a step of an export job
+func (s *Service) export(ctx context.Context, job *Job, step string) (int, error) {
+	if step == stepBundle {
+		return s.writeBundle(ctx, job)
+	}
+
+	ctx, requester := identity.WithServiceIdentity(ctx, job.OrgID)
+	var entries []any
+	switch step {
+	case stepFolders:
+		folders, err := s.listFolders(ctx, requester, job.OrgID)
+		if err != nil {
+			return 0, err
+		}
+		for _, f := range folders {
+			entries = append(entries, folderEntry{UID: f.UID, Title: f.Title, Description: f.Description, ParentUID: f.ParentUID})
+		}
+	case stepDashboards:
+		dashs, err := s.listDashboards(ctx, job.OrgID)
+		if err != nil {
+			return 0, err
+		}
+		for _, d := range dashs {
+			entries = append(entries, dashboardEntry{UID: d.UID, Title: d.Title, FolderUID: d.FolderUID, Dashboard: dashboardModel(d)})
+		}
+	case stepDataSources:
+		dataSources, err := s.dsService.GetDataSources(ctx, &datasources.GetDataSourcesQuery{OrgID: job.OrgID})
+		if err != nil {
+			return 0, err
+		}
+		for _, ds := range dataSources {
+			entries = append(entries, newDataSourceEntry(ds))
+		}
+	case stepAlertRules:
+		rules, err := s.ruleStore.ListAlertRules(ctx, &models.ListAlertRulesQuery{OrgID: job.OrgID})
+		if err != nil {
+			return 0, err
+		}
+		for _, rule := range rules {
+			body := compat.ProvisionedAlertRuleFromAlertRule(*rule, models.ProvenanceNone)
+			body.ID = 0
+			entries = append(entries, body)
+		}
+	case stepTeams:
+		teams, err := s.listTeams(ctx, requester, job.OrgID)
+		if err != nil {
+			return 0, err
+		}
+		for _, t := range teams {
+			members, err := s.teamService.GetTeamMembers(ctx, &team.GetTeamMembersQuery{OrgID: job.OrgID, TeamID: t.ID, SignedInUser: requester})
+			if err != nil {
+				return 0, err
+			}
+			entry := teamEntry{Name: t.Name, Email: t.Email, Members: make([]teamMemberEntry, 0, len(members))}
+			for _, m := range members {
+				entry.Members = append(entry.Members, teamMemberEntry{Login: m.Login, Email: m.Email, Admin: m.Permission == team.PermissionTypeAdmin})
+			}
+			entries = append(entries, entry)
+		}
+	case stepUsers:
+		users, err := s.listOrgUsers(ctx, requester, job.OrgID)
+		if err != nil {
+			return 0, err
+		}
+		for _, u := range users {
+			entries = append(entries, userEntry{Login: u.Login, Email: u.Email, Name: u.Name, Role: u.Role})
+		}
+	default:
+		return 0, fmt.Errorf("unknown export step %q", step)
+	}
+
+	if entries == nil {
+		entries = []any{}
+	}
+	if err := writeJSON(filepath.Join(s.exportDir(job), step+".json"), entries); err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// writeBundle zips the files written by the previous steps with a manifest
+func (s *Service) writeBundle(ctx context.Context, job *Job) (int, error) {
+	o, err := s.orgService.GetByID(ctx, &org.GetOrgByIDQuery{ID: job.OrgID})
+	if err != nil {
+		return 0, err
+	}
+	manifest := BundleManifest{
+		Version:    bundleVersion,
+		OrgID:      o.ID,
+		OrgName:    o.Name,
+		ExportedAt: time.Now().UTC(),
+		Resources:  map[string]int{},
+	}
+	for _, step := range job.Steps {
+		if step.Name != stepBundle {
+			manifest.Resources[step.Name] = step.Items
+		}
+	}
+	dir := s.exportDir(job)
+	if err := writeJSON(filepath.Join(dir, "manifest.json"), manifest); err != nil {
+		return 0, err
+	}
+
+	// the bundle is written next to its final path, so that an interrupted job never leaves a partial bundle
+	tmp := s.bundlePath(job) + ".tmp"
+	if err := zipDir(dir, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return 0, err
+	}
+	if err := os.Rename(tmp, s.bundlePath(job)); err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(s.bundlePath(job))
+	if err != nil {
+		return 0, err
+	}
+	s.updateJob(ctx, job, func() { job.BundleSize = info.Size() })
+	return 1, os.RemoveAll(dir)
+}
+
+func writeJSON(path string, value any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o640)
+}
+
+func zipDir(dir, path string) (err error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	//nolint:gosec
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	w := zip.NewWriter(out)
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		if err := addZipFile(w, filepath.Join(dir, f.Name())); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+func addZipFile(w *zip.Writer, path string) error {
+	//nolint:gosec
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	entry, err := w.Create(filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, in)
+	return err
+}
+
+// listFolders returns the folders of the org, the parents before their subfolders
+func (s *Service) listFolders(ctx context.Context, requester identity.Requester, orgID int64) ([]*folder.Folder, error) {
+	dashs, err := s.dashboardService.GetAllDashboardsByOrgId(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	uids := make([]string, 0)
+	for _, d := range dashs {
+		if d.IsFolder {
+			uids = append(uids, d.UID)
+		}
+	}
+	if len(uids) == 0 {
+		return []*folder.Folder{}, nil
+	}
+
+	folders, err := s.folderService.GetFolders(ctx, folder.GetFoldersQuery{
+		UIDs:             uids,
+		SignedInUser:     requester,
+		OrgID:            orgID,
+		WithFullpathUIDs: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(folders, func(i, j int) bool {
+		return strings.Count(folders[i].FullpathUIDs, "/") < strings.Count(folders[j].FullpathUIDs, "/")
+	})
+	return folders, nil
+}
+
+// listDashboards returns the dashboards of the org, without the folders
+func (s *Service) listDashboards(ctx context.Context, orgID int64) ([]*dashboards.Dashboard, error) {
+	dashs, err := s.dashboardService.GetAllDashboardsByOrgId(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*dashboards.Dashboard, 0, len(dashs))
+	for _, d := range dashs {
+		if !d.IsFolder && d.Data != nil {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (s *Service) listTeams(ctx context.Context, requester identity.Requester, orgID int64) ([]*team.TeamDTO, error) {
+	teams := make([]*team.TeamDTO, 0)
+	for page := 1; ; page++ {
+		result, err := s.teamService.SearchTeams(ctx, &team.SearchTeamsQuery{OrgID: orgID, Limit: teamsPageSize, Page: page, SignedInUser: requester})
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, result.Teams...)
+		if len(result.Teams) < teamsPageSize {
+			return teams, nil
+		}
+	}
+}
+
+func (s *Service) listOrgUsers(ctx context.Context, requester identity.Requester, orgID int64) ([]*org.OrgUserDTO, error) {
+	return s.orgService.GetOrgUsers(ctx, &org.GetOrgUsersQuery{OrgID: orgID, DontEnforceAccessControl: true, User: requester})
+}
+
+// dashboardModel returns the JSON model of the dashboard, without the id and version of this org
+func dashboardModel(d *dashboards.Dashboard) map[string]any {
+	data := d.Data.MustMap()
+	model := make(map[string]any, len(data))
+	for k, v := range data {
+		model[k] = v
+	}
+	delete(model, "id")
+	delete(model, "version")
+	model["uid"] = d.UID
+	return model
+}
+
+func newDataSourceEntry(ds *datasources.DataSource) dataSourceEntry {
+	entry := dataSourceEntry{
+		UID:             ds.UID,
+		Name:            ds.Name,
+		Type:            ds.Type,
+		Access:          ds.Access,
+		URL:             ds.URL,
+		User:            ds.User,
+		Database:        ds.Database,
+		BasicAuth:       ds.BasicAuth,
+		BasicAuthUser:   ds.BasicAuthUser,
+		WithCredentials: ds.WithCredentials,
+		IsDefault:       ds.IsDefault,
+		JsonData:        ds.JsonData,
+	}
+	for field := range ds.SecureJsonData {
+		entry.SecureFields = append(entry.SecureFields, field)
+	}
+	sort.Strings(entry.SecureFields)
+	return entry
+}
@@ -0,0 +1,231 @@
+package orglifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"time"
+)
+
+const (
+	// the running jobs update their state at this interval, so that the jobs of the instances
+	// which stopped are reported as interrupted
+	jobHeartbeatInterval = 30 * time.Second
+	jobStaleAfter        = 4 * jobHeartbeatInterval
+
+	// conflictsLimit bounds the conflicts reported by a merge
+	conflictsLimit = 1000
+)
+
+const (
+	stepArchive   = "archive"
+	stepMoveUsers = "move-users"
+)
+
+// archiveSteps flag the org as archived, then move the users whose current org is the archived org
+// to another one of their orgs
+var archiveSteps = []string{stepArchive, stepMoveUsers}
+
+func (s *Service) GetJob(ctx context.Context, jobID string) (*Job, error) {
+	s.jobMtx.Lock()
+	defer s.jobMtx.Unlock()
+	return s.loadJob(ctx, jobID)
+}
+
+// ListJobs returns the jobs, the most recent first
+func (s *Service) ListJobs(ctx context.Context) ([]*Job, error) {
+	s.jobMtx.Lock()
+	defer s.jobMtx.Unlock()
+	return s.listJobs(ctx)
+}
+
+// DeleteJob deletes a job which isn't running, with the bundle of an export
+func (s *Service) DeleteJob(ctx context.Context, jobID string) error {
+	s.jobMtx.Lock()
+	defer s.jobMtx.Unlock()
+
+	job, err := s.loadJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if job.State == JobRunning {
+		return ErrJobInvalidState.Errorf("the job %s is running", job.ID)
+	}
+
+	if job.Kind == JobKindExport {
+		if err := os.RemoveAll(s.exportDir(job)); err != nil {
+			return err
+		}
+		if err := os.Remove(s.bundlePath(job)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return s.kv.Del(ctx, job.ID)
+}
+
+// loadJob returns the job run by this instance, or the job stored. The running jobs which weren't
+// updated recently were interrupted, since their instance stopped.
+func (s *Service) loadJob(ctx context.Context, jobID string) (*Job, error) {
+	if job, ok := s.jobs[jobID]; ok {
+		return cloneJob(job), nil
+	}
+
+	value, exists, err := s.kv.Get(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrJobNotFound.Errorf("job %s not found", jobID)
+	}
+	return decodeJob(value)
+}
+
+func (s *Service) listJobs(ctx context.Context) ([]*Job, error) {
+	values, err := s.kv.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*Job, 0, len(values[0]))
+	for id, value := range values[0] {
+		if job, ok := s.jobs[id]; ok {
+			jobs = append(jobs, cloneJob(job))
+			continue
+		}
+		job, err := decodeJob(value)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].StartedAt.After(jobs[j].StartedAt)
+	})
+	return jobs, nil
+}
+
+func decodeJob(value string) (*Job, error) {
+	var job Job
+	if err := json.Unmarshal([]byte(value), &job); err != nil {
+		return nil, fmt.Errorf("failed to decode the organization job: %w", err)
+	}
+	if job.State == JobRunning && time.Since(job.UpdatedAt) > jobStaleAfter {
+		job.State = JobInterrupted
+	}
+	return &job, nil
+}
+
+func (s *Service) saveJob(ctx context.Context, job *Job) error {
+	value, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, job.ID, string(value))
+}
+
+func cloneJob(job *Job) *Job {
+	clone := *job
+	clone.Steps = slices.Clone(job.Steps)
+	clone.Conflicts = slices.Clone(job.Conflicts)
+	if job.MergeOptions != nil {
+		options := *job.MergeOptions
+		clone.MergeOptions = &options
+	}
+	if job.Mappings != nil {
+		clone.Mappings = make(map[string]map[string]string, len(job.Mappings))
+		for kind, mapping := range job.Mappings {
+			clone.Mappings[kind] = maps.Clone(mapping)
+		}
+	}
+	return &clone
+}
+
+// runJob runs the pending steps of the job in the background, while updating it regularly to report that
+// it's still running. The job stops at the first failed step, it can be resumed from there.
+func (s *Service) runJob(job *Job) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	// the job is still stored once its context is cancelled by the server stopping
+	saveCtx := context.WithoutCancel(ctx)
+
+	go func() {
+		ticker := time.NewTicker(jobHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.updateJob(ctx, job, func() {})
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var stepErr error
+	for i, step := range job.Steps {
+		if step.State == StepCompleted {
+			continue
+		}
+
+		items, err := s.runStep(ctx, job, step.Name)
+		s.updateJob(saveCtx, job, func() {
+			job.Steps[i].Items = items
+			job.Steps[i].State = StepCompleted
+			if err != nil {
+				job.Steps[i].State, job.Steps[i].Error = StepFailed, err.Error()
+			}
+		})
+		if err != nil {
+			s.log.Warn("Organization job step failed", "id", job.ID, "kind", job.Kind, "step", step.Name, "error", err)
+			stepErr = fmt.Errorf("the %s step failed, resume the job once the error is fixed: %w", step.Name, err)
+			break
+		}
+	}
+
+	s.updateJob(saveCtx, job, func() {
+		now := time.Now().UTC()
+		job.FinishedAt = &now
+		job.State = JobCompleted
+		if stepErr != nil {
+			job.State, job.Error = JobFailed, stepErr.Error()
+			if ctx.Err() != nil {
+				job.State = JobInterrupted
+			}
+		}
+	})
+	s.log.Info("Organization job finished", "id", job.ID, "kind", job.Kind, "orgId", job.OrgID, "state", job.State)
+
+	s.jobMtx.Lock()
+	defer s.jobMtx.Unlock()
+	delete(s.jobs, job.ID)
+}
+
+func (s *Service) updateJob(ctx context.Context, job *Job, update func()) {
+	s.jobMtx.Lock()
+	defer s.jobMtx.Unlock()
+
+	update()
+	job.UpdatedAt = time.Now().UTC()
+	job.Completed = 0
+	for _, step := range job.Steps {
+		if step.State == StepCompleted {
+			job.Completed++
+		}
+	}
+	if err := s.saveJob(ctx, job); err != nil {
+		s.log.Warn("Could not save the organization job", "id", job.ID, "error", err)
+	}
+}
+
+// addConflict reports a resource of the merged org which collided with a resource of the target org, the
+// conflicts already reported by a previous run of the job are ignored. It must be called from an update of the job.
+func addConflict(job *Job, conflict Conflict) {
+	if len(job.Conflicts) < conflictsLimit && !slices.Contains(job.Conflicts, conflict) {
+		job.Conflicts = append(job.Conflicts, conflict)
+	}
+}
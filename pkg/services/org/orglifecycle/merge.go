@@ -0,0 +1,464 @@
+package orglifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// mergeSteps copy the resources of the org into the target org. The users are copied first since they're
+// added to the teams, then the data sources and folders which are referenced by the dashboards.
+var mergeSteps = []string{stepUsers, stepTeams, stepDataSources, stepFolders, stepDashboards}
+
+// the kinds of the mappings of a merge job
+const (
+	mappingTeams           = "team"
+	mappingDataSources     = "datasource"
+	mappingDataSourceNames = "datasourceName"
+	mappingFolders         = "folder"
+	mappingDashboards      = "dashboard"
+)
+
+// mergeRun is a step of a merge job, with the identities reading the merged org and writing the target org
+type mergeRun struct {
+	job        *Job
+	sourceName string
+
+	sourceCtx  context.Context
+	sourceUser identity.Requester
+	targetCtx  context.Context
+	targetUser identity.Requester
+}
+
+// merge runs a step of a merge job
+func (s *Service) merge(ctx context.Context, job *Job, step string) (int, error) {
+	source, err := s.orgService.GetByID(ctx, &org.GetOrgByIDQuery{ID: job.OrgID})
+	if err != nil {
+		return 0, err
+	}
+	run := &mergeRun{job: job, sourceName: source.Name}
+	run.sourceCtx, run.sourceUser = identity.WithServiceIdentity(ctx, job.OrgID)
+	run.targetCtx, run.targetUser = identity.WithServiceIdentity(ctx, job.TargetOrgID)
+
+	switch step {
+	case stepUsers:
+		return s.mergeUsers(run)
+	case stepTeams:
+		return s.mergeTeams(run)
+	case stepDataSources:
+		return s.mergeDataSources(run)
+	case stepFolders:
+		return s.mergeFolders(run)
+	case stepDashboards:
+		return s.mergeDashboards(run)
+	}
+	return 0, fmt.Errorf("unknown merge step %q", step)
+}
+
+// mergeUsers adds the members of the merged org to the target org, the members of both orgs keep their
+// role in the target org
+func (s *Service) mergeUsers(run *mergeRun) (int, error) {
+	job := run.job
+	sourceUsers, err := s.listOrgUsers(run.sourceCtx, run.sourceUser, job.OrgID)
+	if err != nil {
+		return 0, err
+	}
+	targetUsers, err := s.listOrgUsers(run.targetCtx, run.targetUser, job.TargetOrgID)
+	if err != nil {
+		return 0, err
+	}
+	roles := make(map[int64]string, len(targetUsers))
+	for _, u := range targetUsers {
+		roles[u.UserID] = u.Role
+	}
+
+	added := 0
+	for _, u := range sourceUsers {
+		if role, ok := roles[u.UserID]; ok {
+			if role != u.Role {
+				s.updateJob(run.targetCtx, job, func() {
+					addConflict(job, Conflict{Kind: "user", Name: u.Login, Resolution: fmt.Sprintf("kept the %s role of the target org", role)})
+				})
+			}
+			continue
+		}
+
+		if err := s.orgService.AddOrgUser(run.targetCtx, &org.AddOrgUserCommand{
+			LoginOrEmail: u.Login,
+			Role:         org.RoleType(u.Role),
+			OrgID:        job.TargetOrgID,
+			UserID:       u.UserID,
+		}); err != nil {
+			return added, fmt.Errorf("failed to add user %s: %w", u.Login, err)
+		}
+		added++
+	}
+	return added, nil
+}
+
+// mergeTeams copies the teams of the merged org with their members, the teams whose name exists in the
+// target org are merged into them
+func (s *Service) mergeTeams(run *mergeRun) (int, error) {
+	job := run.job
+	sourceTeams, err := s.listTeams(run.sourceCtx, run.sourceUser, job.OrgID)
+	if err != nil {
+		return 0, err
+	}
+	targetTeams, err := s.listTeams(run.targetCtx, run.targetUser, job.TargetOrgID)
+	if err != nil {
+		return 0, err
+	}
+	byName := make(map[string]int64, len(targetTeams))
+	for _, t := range targetTeams {
+		byName[t.Name] = t.ID
+	}
+
+	for _, t := range sourceTeams {
+		key := strconv.FormatInt(t.ID, 10)
+		targetID, mapped := job.Mappings[mappingTeams][key]
+		if !mapped {
+			if existing, ok := byName[t.Name]; ok {
+				targetID = strconv.FormatInt(existing, 10)
+				s.updateJob(run.targetCtx, job, func() {
+					addConflict(job, Conflict{Kind: "team", Name: t.Name, Resolution: "merged into the team of the target org"})
+				})
+			} else {
+				created, err := s.teamService.CreateTeam(run.targetCtx, &team.CreateTeamCommand{Name: t.Name, Email: t.Email, OrgID: job.TargetOrgID})
+				if err != nil {
+					return 0, fmt.Errorf("failed to create team %s: %w", t.Name, err)
+				}
+				targetID = strconv.FormatInt(created.ID, 10)
+			}
+			s.setMapping(run.targetCtx, job, mappingTeams, key, targetID)
+		}
+
+		// adding the members again on resume doesn't change them
+		members, err := s.teamService.GetTeamMembers(run.sourceCtx, &team.GetTeamMembersQuery{OrgID: job.OrgID, TeamID: t.ID, SignedInUser: run.sourceUser})
+		if err != nil {
+			return 0, err
+		}
+		for _, m := range members {
+			permission := team.PermissionTypeMember.String()
+			if m.Permission == team.PermissionTypeAdmin {
+				permission = team.PermissionTypeAdmin.String()
+			}
+			if _, err := s.teamPermissionsService.SetUserPermission(run.targetCtx, job.TargetOrgID, ac.User{ID: m.UserID, IsExternal: m.External}, targetID, permission); err != nil {
+				return 0, fmt.Errorf("failed to add %s to team %s: %w", m.Login, t.Name, err)
+			}
+		}
+	}
+	return len(sourceTeams), nil
+}
+
+// mergeDataSources copies the data sources of the merged org with their secrets. The duplicates of the data
+// sources of the target org are reused or copied with a new name, depending on the merge options.
+func (s *Service) mergeDataSources(run *mergeRun) (int, error) {
+	job := run.job
+	sourceDataSources, err := s.dsService.GetDataSources(run.sourceCtx, &datasources.GetDataSourcesQuery{OrgID: job.OrgID})
+	if err != nil {
+		return 0, err
+	}
+	targetDataSources, err := s.dsService.GetDataSources(run.targetCtx, &datasources.GetDataSourcesQuery{OrgID: job.TargetOrgID})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, ds := range sourceDataSources {
+		if _, mapped := job.Mappings[mappingDataSources][ds.UID]; mapped {
+			continue
+		}
+
+		if job.MergeOptions.DataSourceConflict == DataSourceConflictReuse {
+			if match := findDuplicateDataSource(targetDataSources, ds); match != nil {
+				s.mapDataSource(run, ds, match)
+				s.updateJob(run.targetCtx, job, func() {
+					addConflict(job, Conflict{Kind: "datasource", UID: ds.UID, Name: ds.Name, Resolution: fmt.Sprintf("reused the data source %s of the target org", match.Name)})
+				})
+				continue
+			}
+		}
+
+		secrets, err := s.dsService.DecryptedValues(run.sourceCtx, ds)
+		if err != nil {
+			return 0, fmt.Errorf("failed to decrypt the secrets of data source %s: %w", ds.Name, err)
+		}
+		cmd := &datasources.AddDataSourceCommand{
+			Name:            ds.Name,
+			Type:            ds.Type,
+			Access:          ds.Access,
+			URL:             ds.URL,
+			User:            ds.User,
+			Database:        ds.Database,
+			BasicAuth:       ds.BasicAuth,
+			BasicAuthUser:   ds.BasicAuthUser,
+			WithCredentials: ds.WithCredentials,
+			IsDefault:       ds.IsDefault,
+			JsonData:        ds.JsonData,
+			SecureJsonData:  secrets,
+			UID:             ds.UID,
+			OrgID:           job.TargetOrgID,
+		}
+		for _, existing := range targetDataSources {
+			if existing.IsDefault {
+				// the default data source of the target org is kept
+				cmd.IsDefault = false
+			}
+		}
+		cmd.UID = uniqueUID(cmd.UID, func(uid string) bool {
+			return findDataSource(targetDataSources, func(other *datasources.DataSource) bool { return other.UID == uid }) != nil
+		})
+		cmd.Name = uniqueName(cmd.Name, run.sourceName, func(name string) bool {
+			return findDataSource(targetDataSources, func(other *datasources.DataSource) bool { return other.Name == name }) != nil
+		})
+
+		created, err := s.dsService.AddDataSource(run.targetCtx, cmd)
+		if err != nil {
+			return 0, fmt.Errorf("failed to add data source %s: %w", ds.Name, err)
+		}
+		targetDataSources = append(targetDataSources, created)
+		s.mapDataSource(run, ds, created)
+		if created.UID != ds.UID || created.Name != ds.Name {
+			s.updateJob(run.targetCtx, job, func() {
+				addConflict(job, Conflict{Kind: "datasource", UID: ds.UID, Name: ds.Name, Resolution: fmt.Sprintf("copied as %s with the UID %s", created.Name, created.UID)})
+			})
+		}
+	}
+	return len(sourceDataSources), nil
+}
+
+func (s *Service) mapDataSource(run *mergeRun, from, to *datasources.DataSource) {
+	s.setMapping(run.targetCtx, run.job, mappingDataSourceNames, from.Name, to.Name)
+	s.setMapping(run.targetCtx, run.job, mappingDataSources, from.UID, to.UID)
+}
+
+// findDuplicateDataSource returns the data source with the same name and type, or the same type and URL
+func findDuplicateDataSource(dataSources []*datasources.DataSource, ds *datasources.DataSource) *datasources.DataSource {
+	if match := findDataSource(dataSources, func(other *datasources.DataSource) bool {
+		return other.Name == ds.Name && other.Type == ds.Type
+	}); match != nil {
+		return match
+	}
+	if ds.URL == "" {
+		return nil
+	}
+	return findDataSource(dataSources, func(other *datasources.DataSource) bool {
+		return other.Type == ds.Type && other.URL == ds.URL
+	})
+}
+
+func findDataSource(dataSources []*datasources.DataSource, match func(*datasources.DataSource) bool) *datasources.DataSource {
+	for _, ds := range dataSources {
+		if match(ds) {
+			return ds
+		}
+	}
+	return nil
+}
+
+// mergeFolders copies the folders of the merged org, the parents before their subfolders
+func (s *Service) mergeFolders(run *mergeRun) (int, error) {
+	job := run.job
+	folders, err := s.listFolders(run.sourceCtx, run.sourceUser, job.OrgID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, f := range folders {
+		if _, mapped := job.Mappings[mappingFolders][f.UID]; mapped {
+			continue
+		}
+
+		uid, title := f.UID, f.Title
+		uidTaken, err := s.folderExists(run, uid)
+		if err != nil {
+			return 0, err
+		}
+		if uidTaken {
+			if job.MergeOptions.UIDConflict != UIDConflictRename {
+				// the folders have no content to overwrite, their dashboards are merged into the existing folder
+				s.setMapping(run.targetCtx, job, mappingFolders, f.UID, f.UID)
+				s.updateJob(run.targetCtx, job, func() {
+					addConflict(job, Conflict{Kind: "folder", UID: f.UID, Name: f.Title, Resolution: "merged into the folder of the target org"})
+				})
+				continue
+			}
+			uid = util.GenerateShortUID()
+			title = fmt.Sprintf("%s (%s)", f.Title, run.sourceName)
+		}
+
+		created, err := s.folderService.Create(run.targetCtx, &folder.CreateFolderCommand{
+			UID:          uid,
+			OrgID:        job.TargetOrgID,
+			Title:        title,
+			Description:  f.Description,
+			ParentUID:    job.Mappings[mappingFolders][f.ParentUID],
+			SignedInUser: run.targetUser,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to create folder %s: %w", f.Title, err)
+		}
+		s.setMapping(run.targetCtx, job, mappingFolders, f.UID, created.UID)
+		if uidTaken {
+			s.updateJob(run.targetCtx, job, func() {
+				addConflict(job, Conflict{Kind: "folder", UID: f.UID, Name: f.Title, Resolution: fmt.Sprintf("copied as %s with the UID %s", created.Title, created.UID)})
+			})
+		}
+	}
+	return len(folders), nil
+}
+
+func (s *Service) folderExists(run *mergeRun, uid string) (bool, error) {
+	_, err := s.folderService.Get(run.targetCtx, &folder.GetFolderQuery{UID: &uid, OrgID: run.job.TargetOrgID, SignedInUser: run.targetUser})
+	if err == nil {
+		return true, nil
+	}
+	if isNotFound(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// mergeDashboards copies the dashboards of the merged org, pointing them to the data sources and folders
+// the ones of the merged org were mapped to
+func (s *Service) mergeDashboards(run *mergeRun) (int, error) {
+	job := run.job
+	dashs, err := s.listDashboards(run.sourceCtx, job.OrgID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, d := range dashs {
+		if _, mapped := job.Mappings[mappingDashboards][d.UID]; mapped {
+			continue
+		}
+
+		model := dashboardModel(d)
+		rewriteDataSourceRefs(model, job.Mappings[mappingDataSources], job.Mappings[mappingDataSourceNames])
+
+		var resolution string
+		overwrite := false
+		existing, err := s.dashboardService.GetDashboard(run.targetCtx, &dashboards.GetDashboardQuery{UID: d.UID, OrgID: job.TargetOrgID})
+		switch {
+		case err == nil:
+			switch job.MergeOptions.UIDConflict {
+			case UIDConflictSkip:
+				s.setMapping(run.targetCtx, job, mappingDashboards, d.UID, existing.UID)
+				s.updateJob(run.targetCtx, job, func() {
+					addConflict(job, Conflict{Kind: "dashboard", UID: d.UID, Name: d.Title, Resolution: "kept the dashboard of the target org"})
+				})
+				continue
+			case UIDConflictOverwrite:
+				overwrite = true
+				resolution = "overwrote the dashboard of the target org"
+			default:
+				model["uid"] = util.GenerateShortUID()
+				model["title"] = fmt.Sprintf("%s (%s)", d.Title, run.sourceName)
+				resolution = fmt.Sprintf("copied with the UID %s", model["uid"])
+			}
+		case !isNotFound(err):
+			return 0, err
+		}
+
+		dash := dashboards.NewDashboardFromJson(simplejson.NewFromAny(model))
+		dash.OrgID = job.TargetOrgID
+		if d.FolderUID != "" {
+			dash.FolderUID = job.Mappings[mappingFolders][d.FolderUID]
+		}
+		saved, err := s.dashboardService.SaveDashboard(run.targetCtx, &dashboards.SaveDashboardDTO{
+			OrgID:     job.TargetOrgID,
+			User:      run.targetUser,
+			Message:   fmt.Sprintf("Merged from the organization %s", run.sourceName),
+			Overwrite: overwrite,
+			Dashboard: dash,
+		}, false)
+		if err != nil {
+			return 0, fmt.Errorf("failed to save dashboard %s: %w", d.Title, err)
+		}
+		s.setMapping(run.targetCtx, job, mappingDashboards, d.UID, saved.UID)
+		if resolution != "" {
+			s.updateJob(run.targetCtx, job, func() {
+				addConflict(job, Conflict{Kind: "dashboard", UID: d.UID, Name: d.Title, Resolution: resolution})
+			})
+		}
+	}
+	return len(dashs), nil
+}
+
+// setMapping records the resource of the target org a resource of the merged org was copied to, the job is
+// stored so that a resumed job doesn't copy the resource again
+func (s *Service) setMapping(ctx context.Context, job *Job, kind, from, to string) {
+	s.updateJob(ctx, job, func() {
+		if job.Mappings == nil {
+			job.Mappings = map[string]map[string]string{}
+		}
+		if job.Mappings[kind] == nil {
+			job.Mappings[kind] = map[string]string{}
+		}
+		job.Mappings[kind][from] = to
+	})
+}
+
+// rewriteDataSourceRefs points the data source references of a dashboard model, by UID or by name, to the
+// data sources of the target org
+func rewriteDataSourceRefs(value any, uids, names map[string]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if key == "datasource" {
+				switch ref := child.(type) {
+				case string:
+					if name, ok := names[ref]; ok {
+						v[key] = name
+					}
+				case map[string]any:
+					if uid, ok := ref["uid"].(string); ok {
+						if mapped, ok := uids[uid]; ok {
+							ref["uid"] = mapped
+						}
+					}
+				}
+			}
+			rewriteDataSourceRefs(v[key], uids, names)
+		}
+	case []any:
+		for _, child := range v {
+			rewriteDataSourceRefs(child, uids, names)
+		}
+	}
+}
+
+// uniqueUID returns the UID, or a new one if it's taken
+func uniqueUID(uid string, taken func(string) bool) string {
+	for uid == "" || taken(uid) {
+		uid = util.GenerateShortUID()
+	}
+	return uid
+}
+
+// uniqueName returns the name, or the name suffixed with the name of the merged org if it's taken
+func uniqueName(name, orgName string, taken func(string) bool) string {
+	if !taken(name) {
+		return name
+	}
+	candidate := fmt.Sprintf("%s (%s)", name, orgName)
+	for i := 2; taken(candidate); i++ {
+		candidate = fmt.Sprintf("%s (%s %d)", name, orgName, i)
+	}
+	return candidate
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, folder.ErrFolderNotFound) || errors.Is(err, dashboards.ErrFolderNotFound) ||
+		errors.Is(err, dashboards.ErrDashboardNotFound)
+}
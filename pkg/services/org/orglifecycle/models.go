@@ -0,0 +1,137 @@
+package orglifecycle
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+var (
+	ErrOrgArchived = errutil.Forbidden(
+		"orglifecycle.archived", errutil.WithPublicMessage("The organization is archived and read-only"))
+	ErrJobNotFound = errutil.NotFound(
+		"orglifecycle.job-not-found", errutil.WithPublicMessage("Organization job not found"))
+	ErrJobInvalidState = errutil.Conflict(
+		"orglifecycle.invalid-state", errutil.WithPublicMessage("The organization job can't be run in its current state"))
+	ErrOrgBusy = errutil.Conflict(
+		"orglifecycle.org-busy", errutil.WithPublicMessage("Another job is running on the organization"))
+	ErrInvalidMerge = errutil.BadRequest(
+		"orglifecycle.invalid-merge", errutil.WithPublicMessage("The organizations can't be merged"))
+	ErrBundleNotFound = errutil.NotFound(
+		"orglifecycle.bundle-not-found", errutil.WithPublicMessage("The export bundle isn't available"))
+)
+
+type JobKind string
+
+const (
+	// JobKindArchive makes an org read-only and hides it from the org switchers
+	JobKindArchive JobKind = "archive"
+	// JobKindExport writes the resources of an org into a bundle
+	JobKindExport JobKind = "export"
+	// JobKindMerge copies the resources of an org into another one
+	JobKindMerge JobKind = "merge"
+)
+
+type JobState string
+
+const (
+	JobRunning JobState = "running"
+	// JobInterrupted is the state of the running jobs whose instance stopped, they can be resumed
+	JobInterrupted JobState = "interrupted"
+	// JobFailed is the state of the jobs with a failed step, they can be resumed from that step
+	JobFailed    JobState = "failed"
+	JobCompleted JobState = "completed"
+)
+
+// Resumable reports whether the job can be resumed from its first step which isn't completed
+func (s JobState) Resumable() bool {
+	return s == JobFailed || s == JobInterrupted
+}
+
+type StepState string
+
+const (
+	StepPending   StepState = "pending"
+	StepCompleted StepState = "completed"
+	StepFailed    StepState = "failed"
+)
+
+// Job is a background job archiving, exporting or merging an org. The job is stored after each step, and
+// after each resource copied by a merge, so that it can be resumed where it stopped.
+type Job struct {
+	ID    string   `json:"id"`
+	Kind  JobKind  `json:"kind"`
+	State JobState `json:"state"`
+	OrgID int64    `json:"orgId"`
+	// TargetOrgID is the org the resources are merged into
+	TargetOrgID int64      `json:"targetOrgId,omitempty"`
+	StartedBy   string     `json:"startedBy"`
+	StartedAt   time.Time  `json:"startedAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+	// Total and Completed count the steps of the job
+	Total     int       `json:"total"`
+	Completed int       `json:"completed"`
+	Steps     []JobStep `json:"steps"`
+	Error     string    `json:"error,omitempty"`
+
+	MergeOptions *MergeOptions `json:"mergeOptions,omitempty"`
+	// Mappings maps the UIDs of the resources of the merged org to the ones of the target org, by kind
+	Mappings map[string]map[string]string `json:"mappings,omitempty"`
+	// Conflicts are the resources of the merged org which collided with the ones of the target org
+	Conflicts []Conflict `json:"conflicts,omitempty"`
+	// Bundle is the size in bytes of the bundle of a completed export
+	BundleSize int64 `json:"bundleSize,omitempty"`
+}
+
+type JobStep struct {
+	Name  string    `json:"name"`
+	State StepState `json:"state"`
+	// Items is the number of resources processed by the step
+	Items int    `json:"items"`
+	Error string `json:"error,omitempty"`
+}
+
+type UIDConflictPolicy string
+
+const (
+	// UIDConflictRename copies the resource with a new UID
+	UIDConflictRename UIDConflictPolicy = "rename"
+	// UIDConflictSkip keeps the resource of the target org, the references are pointed to it
+	UIDConflictSkip UIDConflictPolicy = "skip"
+	// UIDConflictOverwrite replaces the resource of the target org
+	UIDConflictOverwrite UIDConflictPolicy = "overwrite"
+)
+
+type DataSourceConflictPolicy string
+
+const (
+	// DataSourceConflictReuse uses the data source of the target org with the same name, or the same type and URL
+	DataSourceConflictReuse DataSourceConflictPolicy = "reuse"
+	// DataSourceConflictRename copies the data source with a new name and UID
+	DataSourceConflictRename DataSourceConflictPolicy = "rename"
+)
+
+type MergeOptions struct {
+	// UIDConflict resolves the folders and dashboards whose UID exists in the target org: rename (default), skip or overwrite
+	UIDConflict UIDConflictPolicy `json:"uidConflict"`
+	// DataSourceConflict resolves the data sources which exist in the target org: reuse (default) or rename
+	DataSourceConflict DataSourceConflictPolicy `json:"dataSourceConflict"`
+	// ArchiveSource archives the merged org once its resources are copied
+	ArchiveSource bool `json:"archiveSource"`
+}
+
+// Conflict is a resource of the merged org which collided with a resource of the target org
+type Conflict struct {
+	Kind       string `json:"kind"`
+	UID        string `json:"uid,omitempty"`
+	Name       string `json:"name"`
+	Resolution string `json:"resolution"`
+}
+
+// swagger:model
+type StartMergeCommand struct {
+	// The org the resources are merged into
+	TargetOrgID int64 `json:"targetOrgId"`
+	MergeOptions
+}
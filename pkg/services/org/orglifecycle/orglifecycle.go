@@ -0,0 +1,287 @@
+package orglifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	ngstore "github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// archivedCacheTTL bounds how long an instance accepts changes to an org after it was archived on another instance
+const archivedCacheTTL = time.Minute
+
+// RuleStore lists the alert rules of the exported orgs
+type RuleStore interface {
+	ListAlertRules(ctx context.Context, query *models.ListAlertRulesQuery) (models.RulesGroup, error)
+}
+
+func ProvideService(
+	cfg *setting.Cfg, router routing.RouteRegister, sqlStore db.DB, kv kvstore.KVStore, authnService authn.Service,
+	orgService org.Service, teamService team.Service, teamPermissionsService ac.TeamPermissionsService,
+	dashboardService dashboards.DashboardService, folderService folder.Service, dsService datasources.DataSourceService,
+	ruleStore *ngstore.DBstore,
+) *Service {
+	s := &Service{
+		sqlStore:               sqlStore,
+		kv:                     kvstore.WithNamespace(kv, 0, "org-lifecycle"),
+		orgService:             orgService,
+		teamService:            teamService,
+		teamPermissionsService: teamPermissionsService,
+		dashboardService:       dashboardService,
+		folderService:          folderService,
+		dsService:              dsService,
+		ruleStore:              ruleStore,
+		bundleDir:              filepath.Join(cfg.DataPath, "org-exports"),
+		archived:               localcache.New(archivedCacheTTL, 2*archivedCacheTTL),
+		log:                    log.New("orglifecycle"),
+		jobs:                   map[string]*Job{},
+	}
+	s.ctx, s.stopJobs = context.WithCancel(context.Background())
+
+	// the hook runs once the org of the request is known, so that the changes to archived orgs are rejected
+	authnService.RegisterPostAuthHook(s.readOnlyHook, 107)
+	s.registerRoutes(router)
+
+	return s
+}
+
+// Service archives, exports and merges orgs. Each operation runs as a background job which can be
+// resumed from its last completed step when it fails or its instance stops.
+type Service struct {
+	sqlStore               db.DB
+	kv                     *kvstore.NamespacedKVStore
+	orgService             org.Service
+	teamService            team.Service
+	teamPermissionsService ac.TeamPermissionsService
+	dashboardService       dashboards.DashboardService
+	folderService          folder.Service
+	dsService              datasources.DataSourceService
+	ruleStore              RuleStore
+	// bundleDir keeps the bundles of the exports
+	bundleDir string
+	// archived caches whether the orgs are archived
+	archived *localcache.CacheService
+	log      log.Logger
+
+	jobMtx sync.Mutex
+	// jobs are the jobs run by this instance
+	jobs map[string]*Job
+	// ctx is the parent of the contexts of the jobs, it's cancelled when the server stops
+	ctx      context.Context
+	stopJobs context.CancelFunc
+}
+
+// Run stops the jobs run by this instance when the server stops, they're reported as interrupted and can be resumed
+func (s *Service) Run(ctx context.Context) error {
+	<-ctx.Done()
+	s.stopJobs()
+	return ctx.Err()
+}
+
+func (s *Service) StartArchive(ctx context.Context, orgID int64, startedBy string) (*Job, error) {
+	if _, err := s.orgService.GetByID(ctx, &org.GetOrgByIDQuery{ID: orgID}); err != nil {
+		return nil, err
+	}
+	return s.startJob(ctx, &Job{Kind: JobKindArchive, OrgID: orgID, StartedBy: startedBy}, archiveSteps)
+}
+
+// Unarchive makes an archived org writable and visible in the org switchers again
+func (s *Service) Unarchive(ctx context.Context, orgID int64) error {
+	if _, err := s.orgService.GetByID(ctx, &org.GetOrgByIDQuery{ID: orgID}); err != nil {
+		return err
+	}
+
+	s.jobMtx.Lock()
+	defer s.jobMtx.Unlock()
+
+	if err := s.checkOrgsIdle(ctx, orgID); err != nil {
+		return err
+	}
+	return s.setArchived(ctx, orgID, false)
+}
+
+func (s *Service) StartExport(ctx context.Context, orgID int64, startedBy string) (*Job, error) {
+	if _, err := s.orgService.GetByID(ctx, &org.GetOrgByIDQuery{ID: orgID}); err != nil {
+		return nil, err
+	}
+	return s.startJob(ctx, &Job{Kind: JobKindExport, OrgID: orgID, StartedBy: startedBy}, exportSteps)
+}
+
+func (s *Service) StartMerge(ctx context.Context, orgID int64, cmd StartMergeCommand, startedBy string) (*Job, error) {
+	options := cmd.MergeOptions
+	switch options.UIDConflict {
+	case "":
+		options.UIDConflict = UIDConflictRename
+	case UIDConflictRename, UIDConflictSkip, UIDConflictOverwrite:
+	default:
+		return nil, ErrInvalidMerge.Errorf("unknown UID conflict policy %q", options.UIDConflict)
+	}
+	switch options.DataSourceConflict {
+	case "":
+		options.DataSourceConflict = DataSourceConflictReuse
+	case DataSourceConflictReuse, DataSourceConflictRename:
+	default:
+		return nil, ErrInvalidMerge.Errorf("unknown data source conflict policy %q", options.DataSourceConflict)
+	}
+	if cmd.TargetOrgID == orgID {
+		return nil, ErrInvalidMerge.Errorf("org %d can't be merged into itself", orgID)
+	}
+
+	if _, err := s.orgService.GetByID(ctx, &org.GetOrgByIDQuery{ID: orgID}); err != nil {
+		return nil, err
+	}
+	target, err := s.orgService.GetByID(ctx, &org.GetOrgByIDQuery{ID: cmd.TargetOrgID})
+	if err != nil {
+		return nil, err
+	}
+	if target.Archived {
+		return nil, ErrInvalidMerge.Errorf("org %d is archived", target.ID)
+	}
+
+	steps := mergeSteps
+	if options.ArchiveSource {
+		steps = append(append([]string{}, mergeSteps...), archiveSteps...)
+	}
+	return s.startJob(ctx, &Job{
+		Kind:         JobKindMerge,
+		OrgID:        orgID,
+		TargetOrgID:  cmd.TargetOrgID,
+		StartedBy:    startedBy,
+		MergeOptions: &options,
+		Mappings:     map[string]map[string]string{},
+	}, steps)
+}
+
+// ResumeJob runs a failed or interrupted job again from its first step which isn't completed
+func (s *Service) ResumeJob(ctx context.Context, jobID string) (*Job, error) {
+	s.jobMtx.Lock()
+	defer s.jobMtx.Unlock()
+
+	job, err := s.loadJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if !job.State.Resumable() {
+		return nil, ErrJobInvalidState.Errorf("the %s job %s can't be resumed", job.State, job.ID)
+	}
+	if err := s.checkOrgsIdle(ctx, job.OrgID, job.TargetOrgID); err != nil {
+		return nil, err
+	}
+
+	job.State, job.Error, job.FinishedAt = JobRunning, "", nil
+	job.UpdatedAt = time.Now().UTC()
+	for i := range job.Steps {
+		if job.Steps[i].State == StepFailed {
+			job.Steps[i].State, job.Steps[i].Error = StepPending, ""
+		}
+	}
+	if err := s.saveJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Organization job resumed", "id", job.ID, "kind", job.Kind, "orgId", job.OrgID, "completed", job.Completed, "total", job.Total)
+	s.jobs[job.ID] = job
+	go s.runJob(job)
+	return cloneJob(job), nil
+}
+
+func (s *Service) startJob(ctx context.Context, job *Job, steps []string) (*Job, error) {
+	s.jobMtx.Lock()
+	defer s.jobMtx.Unlock()
+
+	if err := s.checkOrgsIdle(ctx, job.OrgID, job.TargetOrgID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	job.ID = util.GenerateShortUID()
+	job.State = JobRunning
+	job.StartedAt, job.UpdatedAt = now, now
+	job.Total = len(steps)
+	for _, name := range steps {
+		job.Steps = append(job.Steps, JobStep{Name: name, State: StepPending})
+	}
+	if err := s.saveJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Organization job started", "id", job.ID, "kind", job.Kind, "orgId", job.OrgID, "targetOrgId", job.TargetOrgID)
+	s.jobs[job.ID] = job
+	go s.runJob(job)
+	return cloneJob(job), nil
+}
+
+// checkOrgsIdle fails if a job is running on one of the orgs, as the source or the target of a merge.
+// It must be called with jobMtx held.
+func (s *Service) checkOrgsIdle(ctx context.Context, orgIDs ...int64) error {
+	jobs, err := s.listJobs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if job.State != JobRunning {
+			continue
+		}
+		for _, orgID := range orgIDs {
+			if orgID != 0 && (job.OrgID == orgID || job.TargetOrgID == orgID) {
+				return ErrOrgBusy.Errorf("the %s job %s is running on org %d", job.Kind, job.ID, orgID)
+			}
+		}
+	}
+	return nil
+}
+
+// runStep runs a step of the job and returns the number of resources it processed
+func (s *Service) runStep(ctx context.Context, job *Job, step string) (int, error) {
+	switch step {
+	case stepArchive:
+		return 1, s.setArchived(ctx, job.OrgID, true)
+	case stepMoveUsers:
+		return s.moveUsers(ctx, job.OrgID)
+	}
+
+	switch job.Kind {
+	case JobKindExport:
+		return s.export(ctx, job, step)
+	case JobKindMerge:
+		return s.merge(ctx, job, step)
+	}
+	return 0, fmt.Errorf("unknown step %q of the %s job", step, job.Kind)
+}
+
+// IsArchived reports whether the org is archived, the state is cached for archivedCacheTTL
+func (s *Service) IsArchived(ctx context.Context, orgID int64) (bool, error) {
+	key := fmt.Sprintf("org-%d", orgID)
+	if cached, ok := s.archived.Get(key); ok {
+		return cached.(bool), nil
+	}
+
+	o, err := s.orgService.GetByID(ctx, &org.GetOrgByIDQuery{ID: orgID})
+	if err != nil {
+		if errors.Is(err, org.ErrOrgNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	s.archived.Set(key, o.Archived, archivedCacheTTL)
+	return o.Archived, nil
+}
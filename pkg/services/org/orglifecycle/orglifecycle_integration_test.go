@@ -0,0 +1,141 @@
+package orglifecycle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgimpl"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationService(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	sqlStore, cfg := db.InitTestDBWithCfg(t)
+	orgService, err := orgimpl.ProvideService(sqlStore, cfg, quotatest.New(false, nil))
+	require.NoError(t, err)
+
+	s := &Service{
+		sqlStore:   sqlStore,
+		kv:         kvstore.WithNamespace(kvstore.ProvideService(sqlStore), 0, "org-lifecycle"),
+		orgService: orgService,
+		bundleDir:  t.TempDir(),
+		archived:   localcache.New(archivedCacheTTL, 2*archivedCacheTTL),
+		log:        log.NewNopLogger(),
+		jobs:       map[string]*Job{},
+	}
+	s.ctx, s.stopJobs = context.WithCancel(ctx)
+	t.Cleanup(s.stopJobs)
+
+	now := time.Now()
+	archivedOrg := &org.Org{Name: "archived", Created: now, Updated: now}
+	otherOrg := &org.Org{Name: "other", Created: now, Updated: now}
+	users := map[string]*user.User{}
+	err = sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		for _, o := range []*org.Org{archivedOrg, otherOrg} {
+			if _, err := sess.Insert(o); err != nil {
+				return err
+			}
+		}
+
+		memberships := map[string][]int64{
+			"moved":           {archivedOrg.ID, otherOrg.ID},
+			"single-org":      {archivedOrg.ID},
+			"service-account": {archivedOrg.ID, otherOrg.ID},
+		}
+		for login, orgIDs := range memberships {
+			u := &user.User{
+				UID: login, Login: login, Email: login + "@example.com", OrgID: archivedOrg.ID,
+				IsServiceAccount: login == "service-account", Created: now, Updated: now,
+			}
+			if _, err := sess.Insert(u); err != nil {
+				return err
+			}
+			users[login] = u
+			for _, orgID := range orgIDs {
+				if _, err := sess.Insert(&org.OrgUser{OrgID: orgID, UserID: u.ID, Role: org.RoleViewer, Created: now, Updated: now}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	t.Run("should store, list and delete the jobs", func(t *testing.T) {
+		require.NoError(t, s.saveJob(ctx, &Job{
+			ID: "archive", Kind: JobKindArchive, State: JobCompleted, OrgID: archivedOrg.ID, StartedAt: now,
+			Steps: []JobStep{{Name: stepArchive, State: StepCompleted}, {Name: stepMoveUsers, State: StepCompleted}},
+		}))
+		require.NoError(t, s.saveJob(ctx, &Job{ID: "old", Kind: JobKindArchive, State: JobFailed, OrgID: otherOrg.ID, StartedAt: now.Add(-time.Hour)}))
+
+		job, err := s.GetJob(ctx, "archive")
+		require.NoError(t, err)
+		assert.Equal(t, JobCompleted, job.State)
+		assert.Len(t, job.Steps, 2)
+
+		jobs, err := s.ListJobs(ctx)
+		require.NoError(t, err)
+		require.Len(t, jobs, 2)
+		assert.Equal(t, "archive", jobs[0].ID)
+
+		require.NoError(t, s.DeleteJob(ctx, "old"))
+		_, err = s.GetJob(ctx, "old")
+		assert.ErrorIs(t, err, ErrJobNotFound)
+	})
+
+	t.Run("should archive the org and move its users to their other orgs", func(t *testing.T) {
+		archived, err := s.IsArchived(ctx, archivedOrg.ID)
+		require.NoError(t, err)
+		assert.False(t, archived)
+
+		require.NoError(t, s.setArchived(ctx, archivedOrg.ID, true))
+		archived, err = s.IsArchived(ctx, archivedOrg.ID)
+		require.NoError(t, err)
+		assert.True(t, archived, "the cached state is reset")
+
+		moved, err := s.moveUsers(ctx, archivedOrg.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, moved)
+
+		currentOrgs := map[string]int64{}
+		err = sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			for login, u := range users {
+				var stored user.User
+				if _, err := sess.ID(u.ID).Get(&stored); err != nil {
+					return err
+				}
+				currentOrgs[login] = stored.OrgID
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{
+			"moved":           otherOrg.ID,
+			"single-org":      archivedOrg.ID,
+			"service-account": archivedOrg.ID,
+		}, currentOrgs)
+
+		require.NoError(t, s.Unarchive(ctx, archivedOrg.ID))
+		archived, err = s.IsArchived(ctx, archivedOrg.ID)
+		require.NoError(t, err)
+		assert.False(t, archived)
+	})
+}
@@ -0,0 +1,166 @@
+package orglifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+)
+
+func TestIsChange(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{method: "GET", path: "/api/dashboards/uid/abc", want: false},
+		{method: "POST", path: "/api/dashboards/db", want: true},
+		{method: "DELETE", path: "/api/datasources/uid/abc", want: true},
+		{method: "PUT", path: "/api/user/preferences", want: false},
+		{method: "POST", path: "/api/user/using/2", want: false},
+		{method: "POST", path: "/api/ds/query", want: false},
+		{method: "POST", path: "/api/admin/orgs/2/archive", want: false},
+		{method: "POST", path: "/login", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, isChange(httptest.NewRequest(tt.method, tt.path, nil)))
+		})
+	}
+}
+
+func TestRewriteDataSourceRefs(t *testing.T) {
+	var model map[string]any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"panels": [
+			{"datasource": {"type": "prometheus", "uid": "prom"}, "targets": [{"datasource": {"uid": "prom"}}]},
+			{"datasource": "Loki"},
+			{"datasource": {"uid": "other"}}
+		]
+	}`), &model))
+
+	rewriteDataSourceRefs(model, map[string]string{"prom": "prom-2"}, map[string]string{"Loki": "Loki (Acme)"})
+
+	panels := model["panels"].([]any)
+	assert.Equal(t, "prom-2", panels[0].(map[string]any)["datasource"].(map[string]any)["uid"])
+	target := panels[0].(map[string]any)["targets"].([]any)[0].(map[string]any)
+	assert.Equal(t, "prom-2", target["datasource"].(map[string]any)["uid"])
+	assert.Equal(t, "Loki (Acme)", panels[1].(map[string]any)["datasource"])
+	assert.Equal(t, "other", panels[2].(map[string]any)["datasource"].(map[string]any)["uid"])
+}
+
+func TestUniqueName(t *testing.T) {
+	taken := map[string]bool{"Prometheus": true, "Prometheus (Acme)": true}
+	isTaken := func(name string) bool { return taken[name] }
+
+	assert.Equal(t, "Loki", uniqueName("Loki", "Acme", isTaken))
+	assert.Equal(t, "Prometheus (Acme 2)", uniqueName("Prometheus", "Acme", isTaken))
+}
+
+func TestFindDuplicateDataSource(t *testing.T) {
+	target := []*datasources.DataSource{
+		{Name: "Prometheus", Type: "prometheus", URL: "http://prom:9090"},
+		{Name: "Logs", Type: "loki", URL: "http://loki:3100"},
+	}
+
+	match := findDuplicateDataSource(target, &datasources.DataSource{Name: "Prometheus", Type: "prometheus"})
+	require.NotNil(t, match)
+	assert.Equal(t, "Prometheus", match.Name)
+
+	match = findDuplicateDataSource(target, &datasources.DataSource{Name: "Loki", Type: "loki", URL: "http://loki:3100"})
+	require.NotNil(t, match)
+	assert.Equal(t, "Logs", match.Name)
+
+	assert.Nil(t, findDuplicateDataSource(target, &datasources.DataSource{Name: "Loki", Type: "loki"}))
+	assert.Nil(t, findDuplicateDataSource(target, &datasources.DataSource{Name: "Prometheus", Type: "mimir", URL: "http://mimir"}))
+}
+
+func TestService_Jobs(t *testing.T) {
+	t.Run("should report the running jobs which weren't updated as interrupted", func(t *testing.T) {
+		s := setupTestService(t, &org.Org{ID: 2})
+		stale := &Job{ID: "stale", Kind: JobKindExport, State: JobRunning, OrgID: 2, UpdatedAt: time.Now().Add(-time.Hour)}
+		require.NoError(t, s.saveJob(context.Background(), stale))
+
+		job, err := s.GetJob(context.Background(), "stale")
+		require.NoError(t, err)
+		assert.Equal(t, JobInterrupted, job.State)
+		assert.True(t, job.State.Resumable())
+	})
+
+	t.Run("should reject a job on an org which has a running job", func(t *testing.T) {
+		s := setupTestService(t, &org.Org{ID: 2})
+		running := &Job{ID: "running", Kind: JobKindExport, State: JobRunning, OrgID: 3, TargetOrgID: 2, UpdatedAt: time.Now()}
+		require.NoError(t, s.saveJob(context.Background(), running))
+
+		_, err := s.StartArchive(context.Background(), 2, "admin")
+		require.ErrorIs(t, err, ErrOrgBusy)
+	})
+
+	t.Run("should reject the merge of an org into itself or with an unknown policy", func(t *testing.T) {
+		s := setupTestService(t, &org.Org{ID: 2})
+
+		_, err := s.StartMerge(context.Background(), 2, StartMergeCommand{TargetOrgID: 2}, "admin")
+		require.ErrorIs(t, err, ErrInvalidMerge)
+
+		_, err = s.StartMerge(context.Background(), 2, StartMergeCommand{TargetOrgID: 3, MergeOptions: MergeOptions{UIDConflict: "replace"}}, "admin")
+		require.ErrorIs(t, err, ErrInvalidMerge)
+	})
+
+	t.Run("should not resume or delete a running job", func(t *testing.T) {
+		s := setupTestService(t, &org.Org{ID: 2})
+		running := &Job{ID: "running", Kind: JobKindExport, State: JobRunning, OrgID: 2, UpdatedAt: time.Now()}
+		require.NoError(t, s.saveJob(context.Background(), running))
+
+		_, err := s.ResumeJob(context.Background(), "running")
+		require.ErrorIs(t, err, ErrJobInvalidState)
+		require.ErrorIs(t, s.DeleteJob(context.Background(), "running"), ErrJobInvalidState)
+	})
+
+	t.Run("should list the jobs, the most recent first", func(t *testing.T) {
+		s := setupTestService(t, &org.Org{ID: 2})
+		now := time.Now()
+		require.NoError(t, s.saveJob(context.Background(), &Job{ID: "old", State: JobCompleted, StartedAt: now.Add(-time.Hour)}))
+		require.NoError(t, s.saveJob(context.Background(), &Job{ID: "new", State: JobCompleted, StartedAt: now}))
+
+		jobs, err := s.ListJobs(context.Background())
+		require.NoError(t, err)
+		require.Len(t, jobs, 2)
+		assert.Equal(t, "new", jobs[0].ID)
+		assert.Equal(t, "old", jobs[1].ID)
+	})
+}
+
+func TestAddConflict(t *testing.T) {
+	job := &Job{}
+	conflict := Conflict{Kind: "dashboard", UID: "abc", Name: "Home", Resolution: "kept the dashboard of the target org"}
+
+	addConflict(job, conflict)
+	addConflict(job, conflict)
+
+	assert.Len(t, job.Conflicts, 1)
+}
+
+func setupTestService(t *testing.T, o *org.Org) *Service {
+	t.Helper()
+
+	s := &Service{
+		kv:         kvstore.WithNamespace(kvstore.NewFakeKVStore(), 0, "org-lifecycle"),
+		orgService: &orgtest.FakeOrgService{ExpectedOrg: o},
+		bundleDir:  t.TempDir(),
+		log:        log.NewNopLogger(),
+		jobs:       map[string]*Job{},
+	}
+	s.ctx, s.stopJobs = context.WithCancel(context.Background())
+	t.Cleanup(s.stopJobs)
+	return s
+}
@@ -0,0 +1,361 @@
+package playlistv2
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister) {
+	router.Group("/api/v2/playlists", func(playlistRoute routing.RouteRegister) {
+		playlistRoute.Get("/:uid", routing.Wrap(s.getPlaylistV2))
+		playlistRoute.Get("/:uid/schedule", routing.Wrap(s.getSchedule))
+		playlistRoute.Put("/:uid/schedule", middleware.ReqEditorRole, routing.Wrap(s.setSchedule))
+		playlistRoute.Delete("/:uid/schedule", middleware.ReqEditorRole, routing.Wrap(s.deleteSchedule))
+		playlistRoute.Get("/:uid/kiosk-tokens", middleware.ReqEditorRole, routing.Wrap(s.listTokens))
+		playlistRoute.Post("/:uid/kiosk-tokens", middleware.ReqEditorRole, routing.Wrap(s.createToken))
+		playlistRoute.Delete("/:uid/kiosk-tokens/:tokenUid", middleware.ReqEditorRole, routing.Wrap(s.deleteToken))
+		playlistRoute.Get("/:uid/screens", routing.Wrap(s.listPlaylistScreens))
+	}, middleware.ReqSignedIn)
+	router.Get("/api/v2/playlist-screens", middleware.ReqSignedIn, routing.Wrap(s.listScreens))
+
+	// the kiosk routes are only called by the screens, with their kiosk token
+	router.Group("/api/v2/kiosk", func(kioskRoute routing.RouteRegister) {
+		kioskRoute.Get("/playlist", routing.Wrap(s.getKioskPlaylist))
+		kioskRoute.Post("/heartbeat", routing.Wrap(s.heartbeat))
+	})
+}
+
+// swagger:route GET /v2/playlists/{uid} playlists getPlaylistV2
+//
+// Get a playlist with its schedule.
+//
+// Responses:
+// 200: getPlaylistV2Response
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) getPlaylistV2(c *contextmodel.ReqContext) response.Response {
+	p, err := s.GetPlaylist(c.Req.Context(), c.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get playlist", err)
+	}
+	return response.JSON(http.StatusOK, p)
+}
+
+// swagger:route GET /v2/playlists/{uid}/schedule playlists getPlaylistSchedule
+//
+// Get the schedule of a playlist.
+//
+// A playlist without a schedule is always active and returns an empty schedule.
+//
+// Responses:
+// 200: getPlaylistScheduleResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) getSchedule(c *contextmodel.ReqContext) response.Response {
+	p, err := s.GetPlaylist(c.Req.Context(), c.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get playlist schedule", err)
+	}
+	if p.Schedule == nil {
+		return response.JSON(http.StatusOK, Schedule{Timezone: "UTC", Windows: []ScheduleWindow{}})
+	}
+	return response.JSON(http.StatusOK, p.Schedule)
+}
+
+// swagger:route PUT /v2/playlists/{uid}/schedule playlists setPlaylistSchedule
+//
+// Set the schedule of a playlist.
+//
+// The kiosk screens only cycle the playlist during the windows of the schedule. A schedule without windows makes
+// the playlist always active.
+//
+// Responses:
+// 200: getPlaylistV2Response
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) setSchedule(c *contextmodel.ReqContext) response.Response {
+	schedule := Schedule{}
+	if err := web.Bind(c.Req, &schedule); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	p, err := s.SetSchedule(c.Req.Context(), c.GetOrgID(), web.Params(c.Req)[":uid"], &schedule)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to set playlist schedule", err)
+	}
+	return response.JSON(http.StatusOK, p)
+}
+
+// swagger:route DELETE /v2/playlists/{uid}/schedule playlists deletePlaylistSchedule
+//
+// Delete the schedule of a playlist, making it always active.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) deleteSchedule(c *contextmodel.ReqContext) response.Response {
+	if _, err := s.SetSchedule(c.Req.Context(), c.GetOrgID(), web.Params(c.Req)[":uid"], nil); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete playlist schedule", err)
+	}
+	return response.Success("Playlist schedule deleted")
+}
+
+// swagger:route GET /v2/playlists/{uid}/kiosk-tokens playlists listKioskTokens
+//
+// Get the kiosk tokens of a playlist.
+//
+// The keys of the tokens aren't returned.
+//
+// Responses:
+// 200: listKioskTokensResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) listTokens(c *contextmodel.ReqContext) response.Response {
+	tokens, err := s.ListTokens(c.Req.Context(), c.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list kiosk tokens", err)
+	}
+	return response.JSON(http.StatusOK, tokens)
+}
+
+// swagger:route POST /v2/playlists/{uid}/kiosk-tokens playlists createKioskToken
+//
+// Create a kiosk token for a playlist.
+//
+// A screen authenticated with the token can only view the playlist and its dashboards. The key of the token is only
+// returned in this response.
+//
+// Responses:
+// 200: createKioskTokenResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) createToken(c *contextmodel.ReqContext) response.Response {
+	if !s.cfg.Playlists.KioskTokensEnabled {
+		return response.Error(http.StatusNotFound, "Kiosk tokens are disabled", nil)
+	}
+
+	cmd := CreateKioskTokenCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	result, err := s.CreateToken(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create kiosk token", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route DELETE /v2/playlists/{uid}/kiosk-tokens/{tokenUid} playlists deleteKioskToken
+//
+// Delete a kiosk token.
+//
+// The screens using the token are signed out, other instances can accept it for up to a minute.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) deleteToken(c *contextmodel.ReqContext) response.Response {
+	params := web.Params(c.Req)
+	if err := s.DeleteToken(c.Req.Context(), c.GetOrgID(), params[":uid"], params[":tokenUid"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete kiosk token", err)
+	}
+	return response.Success("Kiosk token deleted")
+}
+
+// swagger:route GET /v2/playlists/{uid}/screens playlists listPlaylistScreens
+//
+// Get the screens of a playlist.
+//
+// Reports the screens using the kiosk tokens of the playlist, whether they're active and the dashboard they show.
+//
+// Responses:
+// 200: listPlaylistScreensResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) listPlaylistScreens(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	if _, err := s.getPlaylist(c.Req.Context(), c.GetOrgID(), uid); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list playlist screens", err)
+	}
+
+	screens, err := s.ListScreens(c.Req.Context(), c.GetOrgID(), uid)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list playlist screens", err)
+	}
+	return response.JSON(http.StatusOK, screens)
+}
+
+// swagger:route GET /v2/playlist-screens playlists listScreens
+//
+// Get the screens of the current organization.
+//
+// Reports the screens using the kiosk tokens of every playlist of the organization, whether they're active and
+// the dashboard they show.
+//
+// Responses:
+// 200: listPlaylistScreensResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) listScreens(c *contextmodel.ReqContext) response.Response {
+	screens, err := s.ListScreens(c.Req.Context(), c.GetOrgID(), "")
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list playlist screens", err)
+	}
+	return response.JSON(http.StatusOK, screens)
+}
+
+// swagger:route GET /v2/kiosk/playlist playlists getKioskPlaylist
+//
+// Get the playlist of the kiosk token of the request.
+//
+// Responses:
+// 200: getPlaylistV2Response
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) getKioskPlaylist(c *contextmodel.ReqContext) response.Response {
+	if c.SignedInUser.GetAuthenticatedBy() != login.KioskTokenModule {
+		return response.Err(ErrNotKiosk.Errorf("request isn't authenticated with a kiosk token"))
+	}
+
+	token, err := s.getToken(c.Req.Context(), c.SignedInUser.GetAuthID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get kiosk playlist", err)
+	}
+	p, err := s.GetPlaylist(c.Req.Context(), token.OrgID, token.PlaylistUID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get kiosk playlist", err)
+	}
+	return response.JSON(http.StatusOK, p)
+}
+
+// swagger:route POST /v2/kiosk/heartbeat playlists kioskHeartbeat
+//
+// Report the dashboard shown by the screen of the kiosk token of the request.
+//
+// Screens are reported active for `screen_active_timeout` after their last heartbeat.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) heartbeat(c *contextmodel.ReqContext) response.Response {
+	if c.SignedInUser.GetAuthenticatedBy() != login.KioskTokenModule {
+		return response.Err(ErrNotKiosk.Errorf("request isn't authenticated with a kiosk token"))
+	}
+
+	cmd := HeartbeatCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := s.Heartbeat(c.Req.Context(), c.SignedInUser.GetAuthID(), &cmd, c.RemoteAddr(), c.Req.UserAgent()); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to record heartbeat", err)
+	}
+	return response.Success("Heartbeat recorded")
+}
+
+// swagger:parameters getPlaylistV2 getPlaylistSchedule deletePlaylistSchedule listKioskTokens listPlaylistScreens
+type PlaylistV2UIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters setPlaylistSchedule
+type SetPlaylistScheduleParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body Schedule `json:"body"`
+}
+
+// swagger:parameters createKioskToken
+type CreateKioskTokenParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body CreateKioskTokenCommand `json:"body"`
+}
+
+// swagger:parameters deleteKioskToken
+type DeleteKioskTokenParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:path
+	// required:true
+	TokenUID string `json:"tokenUid"`
+}
+
+// swagger:parameters kioskHeartbeat
+type KioskHeartbeatParams struct {
+	// in:body
+	// required:true
+	Body HeartbeatCommand `json:"body"`
+}
+
+// swagger:response getPlaylistV2Response
+type GetPlaylistV2Response struct {
+	// in: body
+	Body *PlaylistV2 `json:"body"`
+}
+
+// swagger:response getPlaylistScheduleResponse
+type GetPlaylistScheduleResponse struct {
+	// in: body
+	Body *Schedule `json:"body"`
+}
+
+// swagger:response listKioskTokensResponse
+type ListKioskTokensResponse struct {
+	// in: body
+	Body []*KioskToken `json:"body"`
+}
+
+// swagger:response createKioskTokenResponse
+type CreateKioskTokenResponse struct {
+	// in: body
+	Body CreateKioskTokenResult `json:"body"`
+}
+
+// swagger:response listPlaylistScreensResponse
+type ListPlaylistScreensResponse struct {
+	// in: body
+	Body []*Screen `json:"body"`
+}
@@ -0,0 +1,82 @@
+package playlistv2
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	claims "github.com/grafana/authlib/types"
+
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	tokenHeaderName = "X-Grafana-Kiosk-Token"
+	tokenQueryName  = "kioskToken"
+)
+
+var _ authn.Client = new(Client)
+
+// Client authenticates the screens carrying a kiosk token. The identity only views the playlist of the token and its
+// dashboards, it doesn't belong to a user and has no basic role.
+type Client struct {
+	cfg     *setting.Cfg
+	service *Service
+}
+
+func (c *Client) Name() string {
+	return authn.ClientKioskToken
+}
+
+func (c *Client) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error) {
+	token, err := c.service.ValidateToken(ctx, getToken(r))
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := c.service.playlistPermissions(ctx, token.OrgID, token.PlaylistUID)
+	if err != nil {
+		return nil, ErrInvalidToken.Errorf("failed to resolve the playlist of kiosk token %s: %w", token.UID, err)
+	}
+
+	return &authn.Identity{
+		ID:              "0",
+		UID:             "0",
+		Type:            claims.TypeAnonymous,
+		OrgID:           token.OrgID,
+		OrgRoles:        map[int64]org.RoleType{token.OrgID: org.RoleNone},
+		Name:            token.Name,
+		AuthID:          token.UID,
+		AuthenticatedBy: login.KioskTokenModule,
+		Permissions:     map[int64]map[string][]string{token.OrgID: permissions},
+		LastSeenAt:      time.Now(),
+	}, nil
+}
+
+func (c *Client) IsEnabled() bool {
+	return c.cfg.Playlists.KioskTokensEnabled
+}
+
+func (c *Client) Test(ctx context.Context, r *authn.Request) bool {
+	if r.HTTPRequest == nil {
+		return false
+	}
+	return strings.HasPrefix(getToken(r), TokenPrefix)
+}
+
+func (c *Client) Priority() uint {
+	return 26
+}
+
+func getToken(r *authn.Request) string {
+	if token := r.HTTPRequest.Header.Get(tokenHeaderName); token != "" {
+		return token
+	}
+	if r.HTTPRequest.URL != nil {
+		return r.HTTPRequest.URL.Query().Get(tokenQueryName)
+	}
+	return ""
+}
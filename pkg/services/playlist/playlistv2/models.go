@@ -0,0 +1,105 @@
+package playlistv2
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/playlist"
+)
+
+var (
+	ErrPlaylistNotFound = errutil.NotFound(
+		"playlistv2.not-found", errutil.WithPublicMessage("Playlist not found"))
+	ErrInvalidSchedule = errutil.BadRequest("playlistv2.invalid-schedule")
+	ErrInvalidName     = errutil.BadRequest("playlistv2.invalid-name")
+	ErrTokenNotFound   = errutil.NotFound(
+		"playlistv2.token-not-found", errutil.WithPublicMessage("Kiosk token not found"))
+	ErrInvalidToken = errutil.Unauthorized(
+		"playlistv2.invalid-token", errutil.WithPublicMessage("Invalid kiosk token"))
+	// ErrNotKiosk is returned when the kiosk API is called without a kiosk token
+	ErrNotKiosk = errutil.Forbidden(
+		"playlistv2.not-kiosk", errutil.WithPublicMessage("The request must be authenticated with a kiosk token"))
+)
+
+// TokenPrefix starts every kiosk token so they can be told apart from other credentials
+const TokenPrefix = "glkt_"
+
+// The days of the schedule windows
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Schedule restricts the hours a playlist is cycled on the kiosk screens. A playlist without windows is always active.
+type Schedule struct {
+	// Timezone of the windows, an IANA name. Defaults to UTC.
+	Timezone string           `json:"timezone"`
+	Windows  []ScheduleWindow `json:"windows"`
+}
+
+// ScheduleWindow is a range of hours on some days of the week
+type ScheduleWindow struct {
+	// Days of the window: sun, mon, tue, wed, thu, fri or sat. Every day when empty.
+	Days []string `json:"days"`
+	// Start and End are the hours of the window, as HH:MM. The window spans midnight when End is before Start,
+	// and the whole day when both are empty.
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// PlaylistV2 is a playlist with its schedule
+type PlaylistV2 struct {
+	*playlist.PlaylistDTO
+	Schedule *Schedule `json:"schedule,omitempty"`
+	// Active reports whether the schedule allows the playlist to be cycled now
+	Active bool `json:"active"`
+}
+
+// KioskToken grants a screen the view of a single playlist and of its dashboards
+type KioskToken struct {
+	ID          int64      `json:"-"`
+	OrgID       int64      `json:"orgId"`
+	UID         string     `json:"uid"`
+	PlaylistUID string     `json:"playlistUid"`
+	Name        string     `json:"name"`
+	CreatedBy   int64      `json:"createdBy"`
+	Created     time.Time  `json:"created"`
+	LastSeenAt  *time.Time `json:"lastSeenAt,omitempty"`
+	// LastSeenIP and LastSeenUserAgent identify the screen which last used the token
+	LastSeenIP        string `json:"lastSeenIp,omitempty"`
+	LastSeenUserAgent string `json:"lastSeenUserAgent,omitempty"`
+	// CurrentDashboardUID is the dashboard the screen last reported showing
+	CurrentDashboardUID string `json:"currentDashboardUid,omitempty"`
+}
+
+type CreateKioskTokenCommand struct {
+	// Name of the screen the token is given to
+	Name string `json:"name"`
+}
+
+type CreateKioskTokenResult struct {
+	*KioskToken
+	// Key is the token, it is only returned when the token is created
+	Key string `json:"key"`
+}
+
+// Screen is a kiosk token with the state of the screen using it
+type Screen struct {
+	*KioskToken
+	PlaylistName string `json:"playlistName"`
+	// Active reports whether the screen was seen recently
+	Active bool `json:"active"`
+	// Scheduled reports whether the schedule of the playlist allows it to be cycled now
+	Scheduled bool `json:"scheduled"`
+}
+
+// HeartbeatCommand is sent regularly by the kiosk screens
+type HeartbeatCommand struct {
+	// DashboardUID is the dashboard the screen is showing
+	DashboardUID string `json:"dashboardUid"`
+}
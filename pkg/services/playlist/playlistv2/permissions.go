@@ -0,0 +1,146 @@
+package playlistv2
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+// The datasource references which don't point to a datasource of the org
+var builtInDataSources = map[string]bool{
+	"-- Grafana --":   true,
+	"-- Mixed --":     true,
+	"-- Dashboard --": true,
+	"grafana":         true,
+}
+
+// playlistPermissions returns the permissions of the screens of the playlist: the view of its dashboards and the
+// queries of their datasources. They are cached so the screens don't resolve the playlist on every request.
+func (s *Service) playlistPermissions(ctx context.Context, orgID int64, playlistUID string) (map[string][]string, error) {
+	key := fmt.Sprintf("permissions-%d-%s", orgID, playlistUID)
+	if cached, ok := s.cache.Get(key); ok {
+		return cached.(map[string][]string), nil
+	}
+
+	dto, err := s.getPlaylist(ctx, orgID, playlistUID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the screens have no user, the dashboards are searched as the service
+	ctx, requester := identity.WithServiceIdentity(ctx, orgID)
+	dashboardUIDs := map[string]bool{}
+	for _, item := range dto.Items {
+		switch item.Type {
+		case "dashboard_by_uid":
+			dashboardUIDs[item.Value] = true
+		case "dashboard_by_tag":
+			hits, err := s.dashboardService.FindDashboards(ctx, &dashboards.FindPersistedDashboardsQuery{
+				OrgId:        orgID,
+				SignedInUser: requester,
+				Tags:         []string{item.Value},
+				Type:         "dash-db",
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, hit := range hits {
+				dashboardUIDs[hit.UID] = true
+			}
+		}
+	}
+
+	dataSources, err := s.dataSourceService.GetDataSources(ctx, &datasources.GetDataSourcesQuery{OrgID: orgID})
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string]string{}
+	defaultUID := ""
+	for _, ds := range dataSources {
+		byName[ds.Name] = ds.UID
+		if ds.IsDefault {
+			defaultUID = ds.UID
+		}
+	}
+
+	permissions := map[string][]string{}
+	dataSourceUIDs := map[string]bool{}
+	for uid := range dashboardUIDs {
+		dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: uid, OrgID: orgID})
+		if err != nil {
+			s.log.Warn("Failed to get a dashboard of a playlist", "playlist", playlistUID, "dashboard", uid, "error", err)
+			continue
+		}
+		permissions[dashboards.ActionDashboardsRead] = append(permissions[dashboards.ActionDashboardsRead],
+			dashboards.ScopeDashboardsProvider.GetResourceScopeUID(uid))
+		collectDataSources(dash.Data, byName, defaultUID, dataSourceUIDs)
+	}
+	for uid := range dataSourceUIDs {
+		permissions[datasources.ActionQuery] = append(permissions[datasources.ActionQuery], datasources.ScopeProvider.GetResourceScopeUID(uid))
+	}
+
+	s.cache.Set(key, permissions, cacheTTL)
+	return permissions, nil
+}
+
+// collectDataSources adds the uids of the datasources referenced by the panels and the queries of the dashboard.
+// The references by name are resolved with byName and a missing reference is the default datasource.
+func collectDataSources(data *simplejson.Json, byName map[string]string, defaultUID string, uids map[string]bool) {
+	if data == nil {
+		return
+	}
+	var walk func(value any)
+	walk = func(value any) {
+		switch v := value.(type) {
+		case map[string]any:
+			for key, child := range v {
+				if key == "datasource" {
+					addDataSource(child, byName, defaultUID, uids)
+					continue
+				}
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(data.Interface())
+	for _, panel := range data.Get("panels").MustArray() {
+		if p, ok := panel.(map[string]any); ok {
+			if _, ok := p["datasource"]; !ok && defaultUID != "" {
+				uids[defaultUID] = true
+			}
+		}
+	}
+}
+
+func addDataSource(ref any, byName map[string]string, defaultUID string, uids map[string]bool) {
+	var value string
+	switch r := ref.(type) {
+	case nil:
+		if defaultUID != "" {
+			uids[defaultUID] = true
+		}
+		return
+	case string:
+		value = r
+		if uid, ok := byName[r]; ok {
+			value = uid
+		}
+	case map[string]any:
+		value, _ = r["uid"].(string)
+	}
+
+	// templated datasources are resolved by the screen, they can only be queried if they're referenced elsewhere
+	if value == "" || builtInDataSources[value] || strings.HasPrefix(value, "$") {
+		return
+	}
+	uids[value] = true
+}
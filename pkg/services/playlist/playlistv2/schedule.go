@@ -0,0 +1,115 @@
+package playlistv2
+
+import (
+	"strings"
+	"time"
+)
+
+// validateSchedule checks the timezone, the days and the hours of the schedule, and normalizes its days
+func validateSchedule(schedule *Schedule) error {
+	if schedule.Timezone == "" {
+		schedule.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+		return ErrInvalidSchedule.Errorf("unknown timezone %q", schedule.Timezone)
+	}
+
+	for i := range schedule.Windows {
+		w := &schedule.Windows[i]
+		for j, day := range w.Days {
+			day = strings.ToLower(strings.TrimSpace(day))
+			if _, ok := weekdays[day]; !ok {
+				return ErrInvalidSchedule.Errorf("unknown day %q, expected sun, mon, tue, wed, thu, fri or sat", day)
+			}
+			w.Days[j] = day
+		}
+		if (w.Start == "") != (w.End == "") {
+			return ErrInvalidSchedule.Errorf("window %d must set both start and end, or neither", i)
+		}
+		if w.Start == "" {
+			continue
+		}
+		start, err := parseClock(w.Start)
+		if err != nil {
+			return err
+		}
+		end, err := parseClock(w.End)
+		if err != nil {
+			return err
+		}
+		if start == end {
+			return ErrInvalidSchedule.Errorf("window %d starts and ends at %s", i, w.Start)
+		}
+	}
+	return nil
+}
+
+// parseClock returns the minutes since midnight of a HH:MM hour
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, ErrInvalidSchedule.Errorf("invalid hour %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// IsActive reports whether the schedule allows the playlist to be cycled at the time. A nil schedule, or a
+// schedule without windows, is always active.
+func (s *Schedule) IsActive(at time.Time) bool {
+	if s == nil || len(s.Windows) == 0 {
+		return true
+	}
+
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	at = at.In(location)
+	minute := at.Hour()*60 + at.Minute()
+	yesterday := at.AddDate(0, 0, -1).Weekday()
+
+	for _, w := range s.Windows {
+		if w.Start == "" {
+			if w.includes(at.Weekday()) {
+				return true
+			}
+			continue
+		}
+
+		start, err := parseClock(w.Start)
+		if err != nil {
+			continue
+		}
+		end, err := parseClock(w.End)
+		if err != nil {
+			continue
+		}
+
+		if start < end {
+			if w.includes(at.Weekday()) && minute >= start && minute < end {
+				return true
+			}
+			continue
+		}
+		// the window spans midnight, the hours after midnight belong to the window of the day before
+		if w.includes(at.Weekday()) && minute >= start {
+			return true
+		}
+		if w.includes(yesterday) && minute < end {
+			return true
+		}
+	}
+	return false
+}
+
+func (w ScheduleWindow) includes(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if weekdays[d] == day {
+			return true
+		}
+	}
+	return false
+}
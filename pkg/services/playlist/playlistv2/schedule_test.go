@@ -0,0 +1,76 @@
+package playlistv2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSchedule(t *testing.T) {
+	t.Run("should default to UTC and normalize the days", func(t *testing.T) {
+		schedule := &Schedule{Windows: []ScheduleWindow{{Days: []string{" Mon", "FRI"}, Start: "08:00", End: "18:00"}}}
+		require.NoError(t, validateSchedule(schedule))
+		assert.Equal(t, "UTC", schedule.Timezone)
+		assert.Equal(t, []string{"mon", "fri"}, schedule.Windows[0].Days)
+	})
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+	}{
+		{name: "unknown timezone", schedule: Schedule{Timezone: "Mars/Olympus"}},
+		{name: "unknown day", schedule: Schedule{Windows: []ScheduleWindow{{Days: []string{"monday"}}}}},
+		{name: "start without end", schedule: Schedule{Windows: []ScheduleWindow{{Start: "08:00"}}}},
+		{name: "invalid hour", schedule: Schedule{Windows: []ScheduleWindow{{Start: "8h", End: "18:00"}}}},
+		{name: "empty window", schedule: Schedule{Windows: []ScheduleWindow{{Start: "08:00", End: "08:00"}}}},
+	}
+	for _, tt := range tests {
+		t.Run("should reject "+tt.name, func(t *testing.T) {
+			require.ErrorIs(t, validateSchedule(&tt.schedule), ErrInvalidSchedule)
+		})
+	}
+}
+
+func TestSchedule_IsActive(t *testing.T) {
+	// 2024-01-01 is a monday
+	at := func(day int, hour, minute int) time.Time {
+		return time.Date(2024, 1, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	t.Run("should always be active without windows", func(t *testing.T) {
+		var schedule *Schedule
+		assert.True(t, schedule.IsActive(at(1, 3, 0)))
+		assert.True(t, (&Schedule{Timezone: "UTC"}).IsActive(at(1, 3, 0)))
+	})
+
+	t.Run("should be active during the hours of the days of a window", func(t *testing.T) {
+		schedule := &Schedule{Timezone: "UTC", Windows: []ScheduleWindow{{Days: []string{"mon", "tue"}, Start: "08:00", End: "18:00"}}}
+		assert.True(t, schedule.IsActive(at(1, 8, 0)))
+		assert.True(t, schedule.IsActive(at(2, 17, 59)))
+		assert.False(t, schedule.IsActive(at(1, 18, 0)))
+		assert.False(t, schedule.IsActive(at(3, 12, 0)))
+	})
+
+	t.Run("should be active the whole day of a window without hours", func(t *testing.T) {
+		schedule := &Schedule{Timezone: "UTC", Windows: []ScheduleWindow{{Days: []string{"sat", "sun"}}}}
+		assert.True(t, schedule.IsActive(at(6, 0, 0)))
+		assert.True(t, schedule.IsActive(at(7, 23, 59)))
+		assert.False(t, schedule.IsActive(at(8, 0, 0)))
+	})
+
+	t.Run("should give the hours after midnight to the window of the day before", func(t *testing.T) {
+		schedule := &Schedule{Timezone: "UTC", Windows: []ScheduleWindow{{Days: []string{"fri"}, Start: "22:00", End: "02:00"}}}
+		assert.True(t, schedule.IsActive(at(5, 23, 0)))
+		assert.True(t, schedule.IsActive(at(6, 1, 0)))
+		assert.False(t, schedule.IsActive(at(5, 1, 0)))
+		assert.False(t, schedule.IsActive(at(6, 22, 30)))
+	})
+
+	t.Run("should use the timezone of the schedule", func(t *testing.T) {
+		schedule := &Schedule{Timezone: "America/New_York", Windows: []ScheduleWindow{{Start: "09:00", End: "17:00"}}}
+		assert.True(t, schedule.IsActive(at(1, 15, 0)))
+		assert.False(t, schedule.IsActive(at(1, 10, 0)))
+	})
+}
@@ -0,0 +1,254 @@
+package playlistv2
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/playlist"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// cacheTTL bounds how long an instance accepts a token after it was deleted on another instance, and how long
+	// the dashboards of a playlist are cached for its screens
+	cacheTTL = time.Minute
+	// lastSeenInterval throttles the writes of the heartbeats of a screen which keeps showing the same dashboard
+	lastSeenInterval = 30 * time.Second
+)
+
+func ProvideService(
+	cfg *setting.Cfg, sqlStore db.DB, router routing.RouteRegister, authnService authn.Service,
+	playlistService playlist.Service, dashboardService dashboards.DashboardService,
+	dataSourceService datasources.DataSourceService,
+) *Service {
+	s := &Service{
+		cfg:               cfg,
+		store:             &xormStore{db: sqlStore},
+		playlistService:   playlistService,
+		dashboardService:  dashboardService,
+		dataSourceService: dataSourceService,
+		cache:             localcache.New(cacheTTL, 2*cacheTTL),
+		now:               time.Now,
+		log:               log.New("playlistv2"),
+	}
+
+	if cfg.Playlists.KioskTokensEnabled {
+		authnService.RegisterClient(&Client{cfg: cfg, service: s})
+	}
+	s.registerRoutes(router)
+
+	return s
+}
+
+// Service adds schedules to the playlists, and kiosk tokens letting wall displays cycle a playlist without a user
+type Service struct {
+	cfg               *setting.Cfg
+	store             store
+	playlistService   playlist.Service
+	dashboardService  dashboards.DashboardService
+	dataSourceService datasources.DataSourceService
+	// cache holds the stored tokens of the validated requests and the permissions of their playlists
+	cache *localcache.CacheService
+	now   func() time.Time
+	log   log.Logger
+}
+
+func (s *Service) GetPlaylist(ctx context.Context, orgID int64, uid string) (*PlaylistV2, error) {
+	dto, err := s.getPlaylist(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := s.store.GetSchedule(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	return &PlaylistV2{PlaylistDTO: dto, Schedule: schedule, Active: schedule.IsActive(s.now())}, nil
+}
+
+// SetSchedule replaces the schedule of the playlist, a nil schedule makes the playlist always active
+func (s *Service) SetSchedule(ctx context.Context, orgID int64, uid string, schedule *Schedule) (*PlaylistV2, error) {
+	if schedule != nil {
+		if err := validateSchedule(schedule); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := s.getPlaylist(ctx, orgID, uid); err != nil {
+		return nil, err
+	}
+	if err := s.store.SetSchedule(ctx, orgID, uid, schedule); err != nil {
+		return nil, err
+	}
+	return s.GetPlaylist(ctx, orgID, uid)
+}
+
+// CreateToken creates a kiosk token for the playlist. The key of the token is only returned here.
+func (s *Service) CreateToken(ctx context.Context, requester identity.Requester, playlistUID string, cmd *CreateKioskTokenCommand) (*CreateKioskTokenResult, error) {
+	if strings.TrimSpace(cmd.Name) == "" {
+		return nil, ErrInvalidName.Errorf("name is required")
+	}
+	if _, err := s.getPlaylist(ctx, requester.GetOrgID(), playlistUID); err != nil {
+		return nil, err
+	}
+	createdBy, err := requester.GetInternalID()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := util.GetRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	token := &kioskToken{
+		OrgID:       requester.GetOrgID(),
+		UID:         util.GenerateShortUID(),
+		PlaylistUID: playlistUID,
+		Name:        cmd.Name,
+		SecretHash:  hashSecret(secret),
+		CreatedBy:   createdBy,
+		Created:     s.now(),
+	}
+	if err := s.store.CreateToken(ctx, token); err != nil {
+		return nil, err
+	}
+
+	return &CreateKioskTokenResult{KioskToken: token.toToken(), Key: TokenPrefix + token.UID + "_" + secret}, nil
+}
+
+func (s *Service) ListTokens(ctx context.Context, orgID int64, playlistUID string) ([]*KioskToken, error) {
+	if _, err := s.getPlaylist(ctx, orgID, playlistUID); err != nil {
+		return nil, err
+	}
+	return s.store.ListTokens(ctx, orgID, playlistUID)
+}
+
+func (s *Service) DeleteToken(ctx context.Context, orgID int64, playlistUID, uid string) error {
+	if err := s.store.DeleteToken(ctx, orgID, playlistUID, uid); err != nil {
+		return err
+	}
+	s.cache.Delete(tokenCacheKey(uid))
+	return nil
+}
+
+// ListScreens reports the screens using the kiosk tokens of the org, or of a playlist when playlistUID is set
+func (s *Service) ListScreens(ctx context.Context, orgID int64, playlistUID string) ([]*Screen, error) {
+	tokens, err := s.store.ListTokens(ctx, orgID, playlistUID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	playlists := map[string]*PlaylistV2{}
+	screens := make([]*Screen, 0, len(tokens))
+	for _, token := range tokens {
+		p, ok := playlists[token.PlaylistUID]
+		if !ok {
+			p, err = s.GetPlaylist(ctx, orgID, token.PlaylistUID)
+			if err != nil && !errors.Is(err, ErrPlaylistNotFound) {
+				return nil, err
+			}
+			playlists[token.PlaylistUID] = p
+		}
+
+		screen := &Screen{
+			KioskToken: token,
+			Active:     token.LastSeenAt != nil && now.Sub(*token.LastSeenAt) < s.cfg.Playlists.ScreenActiveTimeout,
+		}
+		if p != nil {
+			screen.PlaylistName = p.Name
+			screen.Scheduled = p.Active
+		}
+		screens = append(screens, screen)
+	}
+	return screens, nil
+}
+
+// ValidateToken checks the key of a kiosk token and returns the token
+func (s *Service) ValidateToken(ctx context.Context, key string) (*KioskToken, error) {
+	uid, secret, ok := strings.Cut(strings.TrimPrefix(key, TokenPrefix), "_")
+	if !ok || uid == "" || secret == "" {
+		return nil, ErrInvalidToken.Errorf("malformed kiosk token")
+	}
+
+	token, err := s.getToken(ctx, uid)
+	if err != nil {
+		if errors.Is(err, ErrTokenNotFound) {
+			return nil, ErrInvalidToken.Errorf("kiosk token %s not found", uid)
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(token.SecretHash), []byte(hashSecret(secret))) != 1 {
+		return nil, ErrInvalidToken.Errorf("kiosk token %s has a wrong secret", uid)
+	}
+	return token.toToken(), nil
+}
+
+// Heartbeat records the screen using the kiosk token and the dashboard it shows
+func (s *Service) Heartbeat(ctx context.Context, tokenUID string, cmd *HeartbeatCommand, ip, userAgent string) error {
+	token, err := s.getToken(ctx, tokenUID)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	if token.LastSeenAt != nil && now.Sub(*token.LastSeenAt) < lastSeenInterval &&
+		token.CurrentDashboardUID == cmd.DashboardUID && token.LastSeenIP == ip {
+		return nil
+	}
+
+	seen := &KioskToken{LastSeenAt: &now, LastSeenIP: ip, LastSeenUserAgent: userAgent, CurrentDashboardUID: cmd.DashboardUID}
+	if err := s.store.UpdateLastSeen(ctx, tokenUID, seen); err != nil {
+		return err
+	}
+	// the cached token is shared by the concurrent requests of the screen, so it is replaced rather than updated
+	updated := *token
+	updated.LastSeenAt, updated.LastSeenIP, updated.LastSeenUserAgent, updated.CurrentDashboardUID = &now, ip, userAgent, cmd.DashboardUID
+	s.cache.Set(tokenCacheKey(tokenUID), &updated, cacheTTL)
+	return nil
+}
+
+func (s *Service) getPlaylist(ctx context.Context, orgID int64, uid string) (*playlist.PlaylistDTO, error) {
+	dto, err := s.playlistService.Get(ctx, &playlist.GetPlaylistByUidQuery{UID: uid, OrgId: orgID})
+	if err != nil {
+		if errors.Is(err, playlist.ErrPlaylistNotFound) {
+			return nil, ErrPlaylistNotFound.Errorf("playlist %s not found", uid)
+		}
+		return nil, err
+	}
+	return dto, nil
+}
+
+func (s *Service) getToken(ctx context.Context, uid string) (*kioskToken, error) {
+	if cached, ok := s.cache.Get(tokenCacheKey(uid)); ok {
+		return cached.(*kioskToken), nil
+	}
+
+	token, err := s.store.GetToken(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(tokenCacheKey(uid), token, cacheTTL)
+	return token, nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func tokenCacheKey(uid string) string {
+	return "token-" + uid
+}
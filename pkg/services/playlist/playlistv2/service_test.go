@@ -0,0 +1,229 @@
+package playlistv2
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/playlist"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService_Tokens(t *testing.T) {
+	ctx := context.Background()
+	requester := &user.SignedInUser{UserID: 1, OrgID: 1}
+
+	t.Run("should create a token which validates with its key only", func(t *testing.T) {
+		s, store := setupTestService(t)
+
+		result, err := s.CreateToken(ctx, requester, "lobby", &CreateKioskTokenCommand{Name: "Lobby TV"})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(result.Key, TokenPrefix+result.UID+"_"))
+		assert.NotContains(t, store.tokens[result.UID].SecretHash, strings.TrimPrefix(result.Key, TokenPrefix+result.UID+"_"))
+
+		token, err := s.ValidateToken(ctx, result.Key)
+		require.NoError(t, err)
+		assert.Equal(t, "lobby", token.PlaylistUID)
+
+		_, err = s.ValidateToken(ctx, result.Key+"x")
+		assert.ErrorIs(t, err, ErrInvalidToken)
+		_, err = s.ValidateToken(ctx, TokenPrefix+"unknown_secret")
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("should reject a token of an unknown playlist", func(t *testing.T) {
+		s, _ := setupTestService(t)
+
+		_, err := s.CreateToken(ctx, requester, "unknown", &CreateKioskTokenCommand{Name: "Lobby TV"})
+		assert.ErrorIs(t, err, ErrPlaylistNotFound)
+		_, err = s.CreateToken(ctx, requester, "lobby", &CreateKioskTokenCommand{Name: " "})
+		assert.ErrorIs(t, err, ErrInvalidName)
+	})
+
+	t.Run("should reject a deleted token", func(t *testing.T) {
+		s, _ := setupTestService(t)
+
+		result, err := s.CreateToken(ctx, requester, "lobby", &CreateKioskTokenCommand{Name: "Lobby TV"})
+		require.NoError(t, err)
+		_, err = s.ValidateToken(ctx, result.Key)
+		require.NoError(t, err)
+
+		require.NoError(t, s.DeleteToken(ctx, 1, "lobby", result.UID))
+		_, err = s.ValidateToken(ctx, result.Key)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+func TestService_ListScreens(t *testing.T) {
+	ctx := context.Background()
+	s, store := setupTestService(t)
+	now := s.now()
+	recently, longAgo := now.Add(-time.Minute), now.Add(-time.Hour)
+	store.tokens["a"] = &kioskToken{OrgID: 1, UID: "a", PlaylistUID: "lobby", Name: "Lobby TV", LastSeenAt: &recently}
+	store.tokens["b"] = &kioskToken{OrgID: 1, UID: "b", PlaylistUID: "lobby", Name: "Kitchen TV", LastSeenAt: &longAgo}
+	store.tokens["c"] = &kioskToken{OrgID: 1, UID: "c", PlaylistUID: "lobby", Name: "New TV"}
+	store.schedules["lobby"] = &Schedule{Timezone: "UTC", Windows: []ScheduleWindow{{Start: "08:00", End: "09:00"}}}
+
+	screens, err := s.ListScreens(ctx, 1, "")
+	require.NoError(t, err)
+	require.Len(t, screens, 3)
+
+	active := map[string]bool{}
+	for _, screen := range screens {
+		assert.Equal(t, "Lobby", screen.PlaylistName)
+		assert.True(t, screen.Scheduled)
+		active[screen.UID] = screen.Active
+	}
+	assert.Equal(t, map[string]bool{"a": true, "b": false, "c": false}, active)
+}
+
+func TestService_Heartbeat(t *testing.T) {
+	ctx := context.Background()
+	s, store := setupTestService(t)
+	store.tokens["a"] = &kioskToken{OrgID: 1, UID: "a", PlaylistUID: "lobby"}
+
+	require.NoError(t, s.Heartbeat(ctx, "a", &HeartbeatCommand{DashboardUID: "home"}, "10.0.0.1", "tv"))
+	require.NoError(t, s.Heartbeat(ctx, "a", &HeartbeatCommand{DashboardUID: "home"}, "10.0.0.1", "tv"))
+	assert.Equal(t, 1, store.lastSeenUpdates)
+	assert.Equal(t, "home", store.tokens["a"].CurrentDashboardUID)
+
+	require.NoError(t, s.Heartbeat(ctx, "a", &HeartbeatCommand{DashboardUID: "sales"}, "10.0.0.1", "tv"))
+	assert.Equal(t, 2, store.lastSeenUpdates)
+	assert.Equal(t, "sales", store.tokens["a"].CurrentDashboardUID)
+}
+
+func TestService_PlaylistPermissions(t *testing.T) {
+	s, _ := setupTestService(t)
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("FindDashboards", mock.Anything, mock.Anything).Return([]dashboards.DashboardSearchProjection{{UID: "tagged"}}, nil)
+	dashboardService.On("GetDashboard", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardQuery) bool { return q.UID == "home" })).
+		Return(&dashboards.Dashboard{UID: "home", Data: simplejson.NewFromAny(map[string]any{
+			"panels": []any{
+				map[string]any{"datasource": map[string]any{"uid": "prom"}},
+				map[string]any{"datasource": "Logs", "targets": []any{map[string]any{"datasource": map[string]any{"uid": "${ds}"}}}},
+			},
+		})}, nil)
+	dashboardService.On("GetDashboard", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardQuery) bool { return q.UID == "tagged" })).
+		Return(&dashboards.Dashboard{UID: "tagged", Data: simplejson.NewFromAny(map[string]any{
+			"panels": []any{map[string]any{"title": "default"}},
+		})}, nil)
+	s.dashboardService = dashboardService
+
+	permissions, err := s.playlistPermissions(context.Background(), 1, "lobby")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"dashboards:uid:home", "dashboards:uid:tagged"}, permissions[dashboards.ActionDashboardsRead])
+	assert.ElementsMatch(t, []string{"datasources:uid:prom", "datasources:uid:loki", "datasources:uid:default"}, permissions[datasources.ActionQuery])
+}
+
+func setupTestService(t *testing.T) (*Service, *fakeStore) {
+	t.Helper()
+
+	store := &fakeStore{tokens: map[string]*kioskToken{}, schedules: map[string]*Schedule{}}
+	cfg := setting.NewCfg()
+	cfg.Playlists = setting.PlaylistSettings{KioskTokensEnabled: true, ScreenActiveTimeout: 5 * time.Minute}
+	now := time.Date(2024, 1, 1, 8, 30, 0, 0, time.UTC)
+
+	s := &Service{
+		cfg:   cfg,
+		store: store,
+		playlistService: &fakePlaylistService{playlists: map[string]*playlist.PlaylistDTO{
+			"lobby": {Uid: "lobby", Name: "Lobby", Items: []playlist.PlaylistItemDTO{
+				{Type: "dashboard_by_uid", Value: "home"},
+				{Type: "dashboard_by_tag", Value: "tv"},
+			}},
+		}},
+		dataSourceService: &fakes.FakeDataSourceService{DataSources: []*datasources.DataSource{
+			{OrgID: 1, UID: "prom", Name: "Prometheus"},
+			{OrgID: 1, UID: "loki", Name: "Logs"},
+			{OrgID: 1, UID: "default", Name: "Default", IsDefault: true},
+		}},
+		cache: localcache.New(cacheTTL, 2*cacheTTL),
+		now:   func() time.Time { return now },
+		log:   log.NewNopLogger(),
+	}
+	return s, store
+}
+
+type fakePlaylistService struct {
+	playlist.Service
+	playlists map[string]*playlist.PlaylistDTO
+}
+
+func (f *fakePlaylistService) Get(ctx context.Context, q *playlist.GetPlaylistByUidQuery) (*playlist.PlaylistDTO, error) {
+	p, ok := f.playlists[q.UID]
+	if !ok {
+		return nil, playlist.ErrPlaylistNotFound
+	}
+	return p, nil
+}
+
+type fakeStore struct {
+	tokens          map[string]*kioskToken
+	schedules       map[string]*Schedule
+	lastSeenUpdates int
+}
+
+func (f *fakeStore) GetSchedule(ctx context.Context, orgID int64, playlistUID string) (*Schedule, error) {
+	return f.schedules[playlistUID], nil
+}
+
+func (f *fakeStore) SetSchedule(ctx context.Context, orgID int64, playlistUID string, schedule *Schedule) error {
+	if schedule == nil {
+		delete(f.schedules, playlistUID)
+		return nil
+	}
+	f.schedules[playlistUID] = schedule
+	return nil
+}
+
+func (f *fakeStore) CreateToken(ctx context.Context, token *kioskToken) error {
+	f.tokens[token.UID] = token
+	return nil
+}
+
+func (f *fakeStore) GetToken(ctx context.Context, uid string) (*kioskToken, error) {
+	token, ok := f.tokens[uid]
+	if !ok {
+		return nil, ErrTokenNotFound.Errorf("kiosk token %s not found", uid)
+	}
+	return token, nil
+}
+
+func (f *fakeStore) ListTokens(ctx context.Context, orgID int64, playlistUID string) ([]*KioskToken, error) {
+	var tokens []*KioskToken
+	for _, token := range f.tokens {
+		if token.OrgID == orgID && (playlistUID == "" || token.PlaylistUID == playlistUID) {
+			tokens = append(tokens, token.toToken())
+		}
+	}
+	return tokens, nil
+}
+
+func (f *fakeStore) DeleteToken(ctx context.Context, orgID int64, playlistUID, uid string) error {
+	token, ok := f.tokens[uid]
+	if !ok || token.OrgID != orgID || token.PlaylistUID != playlistUID {
+		return ErrTokenNotFound.Errorf("kiosk token %s not found", uid)
+	}
+	delete(f.tokens, uid)
+	return nil
+}
+
+func (f *fakeStore) UpdateLastSeen(ctx context.Context, uid string, seen *KioskToken) error {
+	token := f.tokens[uid]
+	token.LastSeenAt, token.LastSeenIP, token.LastSeenUserAgent, token.CurrentDashboardUID =
+		seen.LastSeenAt, seen.LastSeenIP, seen.LastSeenUserAgent, seen.CurrentDashboardUID
+	f.lastSeenUpdates++
+	return nil
+}
@@ -0,0 +1,186 @@
+package playlistv2
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// playlistSchedule is the stored form of a Schedule
+type playlistSchedule struct {
+	ID          int64  `xorm:"pk autoincr 'id'"`
+	OrgID       int64  `xorm:"org_id"`
+	PlaylistUID string `xorm:"playlist_uid"`
+	Schedule    string
+	Updated     time.Time
+}
+
+func (playlistSchedule) TableName() string {
+	return "playlist_schedule"
+}
+
+// kioskToken is the stored form of a KioskToken, only the hash of its secret is stored
+type kioskToken struct {
+	ID                  int64  `xorm:"pk autoincr 'id'"`
+	OrgID               int64  `xorm:"org_id"`
+	UID                 string `xorm:"uid"`
+	PlaylistUID         string `xorm:"playlist_uid"`
+	Name                string
+	SecretHash          string     `xorm:"secret_hash"`
+	CreatedBy           int64      `xorm:"created_by"`
+	Created             time.Time  `xorm:"created"`
+	LastSeenAt          *time.Time `xorm:"last_seen_at"`
+	LastSeenIP          string     `xorm:"last_seen_ip"`
+	LastSeenUserAgent   string     `xorm:"last_seen_user_agent"`
+	CurrentDashboardUID string     `xorm:"current_dashboard_uid"`
+}
+
+func (kioskToken) TableName() string {
+	return "playlist_kiosk_token"
+}
+
+func (t *kioskToken) toToken() *KioskToken {
+	return &KioskToken{
+		ID:                  t.ID,
+		OrgID:               t.OrgID,
+		UID:                 t.UID,
+		PlaylistUID:         t.PlaylistUID,
+		Name:                t.Name,
+		CreatedBy:           t.CreatedBy,
+		Created:             t.Created,
+		LastSeenAt:          t.LastSeenAt,
+		LastSeenIP:          t.LastSeenIP,
+		LastSeenUserAgent:   t.LastSeenUserAgent,
+		CurrentDashboardUID: t.CurrentDashboardUID,
+	}
+}
+
+type store interface {
+	GetSchedule(ctx context.Context, orgID int64, playlistUID string) (*Schedule, error)
+	// SetSchedule replaces the schedule of the playlist, a nil schedule deletes it
+	SetSchedule(ctx context.Context, orgID int64, playlistUID string, schedule *Schedule) error
+	CreateToken(ctx context.Context, token *kioskToken) error
+	// GetToken looks the token up by uid only, the secret of the token is checked by the caller
+	GetToken(ctx context.Context, uid string) (*kioskToken, error)
+	// ListTokens returns the tokens of the org, or of a playlist of the org when playlistUID is set
+	ListTokens(ctx context.Context, orgID int64, playlistUID string) ([]*KioskToken, error)
+	DeleteToken(ctx context.Context, orgID int64, playlistUID, uid string) error
+	UpdateLastSeen(ctx context.Context, uid string, seen *KioskToken) error
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (s *xormStore) GetSchedule(ctx context.Context, orgID int64, playlistUID string) (*Schedule, error) {
+	var row playlistSchedule
+	var exists bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		exists, err = sess.Where("org_id = ? AND playlist_uid = ?", orgID, playlistUID).Get(&row)
+		return err
+	})
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	var schedule Schedule
+	if err := json.Unmarshal([]byte(row.Schedule), &schedule); err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (s *xormStore) SetSchedule(ctx context.Context, orgID int64, playlistUID string, schedule *Schedule) error {
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			if _, err := sess.Where("org_id = ? AND playlist_uid = ?", orgID, playlistUID).Delete(&playlistSchedule{}); err != nil {
+				return err
+			}
+			if schedule == nil {
+				return nil
+			}
+
+			value, err := json.Marshal(schedule)
+			if err != nil {
+				return err
+			}
+			_, err = sess.Insert(&playlistSchedule{OrgID: orgID, PlaylistUID: playlistUID, Schedule: string(value), Updated: time.Now()})
+			return err
+		})
+	})
+}
+
+func (s *xormStore) CreateToken(ctx context.Context, token *kioskToken) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(token)
+		return err
+	})
+}
+
+func (s *xormStore) GetToken(ctx context.Context, uid string) (*kioskToken, error) {
+	var token kioskToken
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("uid = ?", uid).Get(&token)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrTokenNotFound.Errorf("kiosk token %s not found", uid)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (s *xormStore) ListTokens(ctx context.Context, orgID int64, playlistUID string) ([]*KioskToken, error) {
+	var rows []*kioskToken
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Where("org_id = ?", orgID)
+		if playlistUID != "" {
+			q = q.And("playlist_uid = ?", playlistUID)
+		}
+		return q.OrderBy("name ASC").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*KioskToken, 0, len(rows))
+	for _, row := range rows {
+		tokens = append(tokens, row.toToken())
+	}
+	return tokens, nil
+}
+
+func (s *xormStore) DeleteToken(ctx context.Context, orgID int64, playlistUID, uid string) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND playlist_uid = ? AND uid = ?", orgID, playlistUID, uid).Delete(&kioskToken{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrTokenNotFound.Errorf("kiosk token %s not found", uid)
+		}
+		return nil
+	})
+}
+
+func (s *xormStore) UpdateLastSeen(ctx context.Context, uid string, seen *KioskToken) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("uid = ?", uid).
+			Cols("last_seen_at", "last_seen_ip", "last_seen_user_agent", "current_dashboard_uid").
+			Update(&kioskToken{
+				LastSeenAt:          seen.LastSeenAt,
+				LastSeenIP:          seen.LastSeenIP,
+				LastSeenUserAgent:   seen.LastSeenUserAgent,
+				CurrentDashboardUID: seen.CurrentDashboardUID,
+			})
+		return err
+	})
+}
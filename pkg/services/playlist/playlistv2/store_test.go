@@ -0,0 +1,110 @@
+package playlistv2
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	store := &xormStore{db: db.InitTestDB(t)}
+	created := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("should replace and delete the schedule of a playlist", func(t *testing.T) {
+		schedule, err := store.GetSchedule(ctx, 1, "lobby")
+		require.NoError(t, err)
+		assert.Nil(t, schedule)
+
+		require.NoError(t, store.SetSchedule(ctx, 1, "lobby", &Schedule{Timezone: "UTC", Windows: []ScheduleWindow{{Start: "08:00", End: "18:00"}}}))
+		business := &Schedule{Timezone: "Europe/Paris", Windows: []ScheduleWindow{{Days: []string{"mon", "fri"}, Start: "09:00", End: "17:00"}}}
+		require.NoError(t, store.SetSchedule(ctx, 1, "lobby", business))
+
+		schedule, err = store.GetSchedule(ctx, 1, "lobby")
+		require.NoError(t, err)
+		assert.Equal(t, business, schedule)
+
+		schedule, err = store.GetSchedule(ctx, 2, "lobby")
+		require.NoError(t, err)
+		assert.Nil(t, schedule, "the schedule is scoped to the org of the playlist")
+
+		require.NoError(t, store.SetSchedule(ctx, 1, "lobby", nil))
+		schedule, err = store.GetSchedule(ctx, 1, "lobby")
+		require.NoError(t, err)
+		assert.Nil(t, schedule)
+	})
+
+	tokens := []*kioskToken{
+		{OrgID: 1, UID: "hall", PlaylistUID: "lobby", Name: "b-hall", SecretHash: "hash", CreatedBy: 2, Created: created},
+		{OrgID: 1, UID: "desk", PlaylistUID: "lobby", Name: "a-desk", SecretHash: "hash", CreatedBy: 2, Created: created},
+		{OrgID: 1, UID: "ops", PlaylistUID: "ops", Name: "c-ops", SecretHash: "hash", CreatedBy: 2, Created: created},
+	}
+	for _, token := range tokens {
+		require.NoError(t, store.CreateToken(ctx, token))
+	}
+
+	t.Run("should get and list the kiosk tokens", func(t *testing.T) {
+		token, err := store.GetToken(ctx, "hall")
+		require.NoError(t, err)
+		assert.Equal(t, "hash", token.SecretHash)
+		assert.Nil(t, token.LastSeenAt)
+
+		_, err = store.GetToken(ctx, "missing")
+		assert.ErrorIs(t, err, ErrTokenNotFound)
+
+		list, err := store.ListTokens(ctx, 1, "")
+		require.NoError(t, err)
+		uids := make([]string, 0, len(list))
+		for _, token := range list {
+			uids = append(uids, token.UID)
+		}
+		assert.Equal(t, []string{"desk", "hall", "ops"}, uids, "the tokens are ordered by name")
+
+		list, err = store.ListTokens(ctx, 1, "ops")
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, "ops", list[0].UID)
+
+		list, err = store.ListTokens(ctx, 2, "")
+		require.NoError(t, err)
+		assert.Empty(t, list)
+	})
+
+	t.Run("should update when the token was last seen", func(t *testing.T) {
+		seenAt := created.Add(time.Hour)
+		require.NoError(t, store.UpdateLastSeen(ctx, "hall", &KioskToken{
+			LastSeenAt: &seenAt, LastSeenIP: "10.0.0.1", LastSeenUserAgent: "kiosk", CurrentDashboardUID: "abc",
+		}))
+
+		token, err := store.GetToken(ctx, "hall")
+		require.NoError(t, err)
+		require.NotNil(t, token.LastSeenAt)
+		assert.True(t, seenAt.Equal(*token.LastSeenAt))
+		assert.Equal(t, "10.0.0.1", token.LastSeenIP)
+		assert.Equal(t, "kiosk", token.LastSeenUserAgent)
+		assert.Equal(t, "abc", token.CurrentDashboardUID)
+		assert.Equal(t, "b-hall", token.Name, "the other columns are kept")
+	})
+
+	t.Run("should delete a token of the playlist", func(t *testing.T) {
+		assert.ErrorIs(t, store.DeleteToken(ctx, 1, "ops", "hall"), ErrTokenNotFound)
+		assert.ErrorIs(t, store.DeleteToken(ctx, 2, "lobby", "hall"), ErrTokenNotFound)
+
+		require.NoError(t, store.DeleteToken(ctx, 1, "lobby", "hall"))
+		_, err := store.GetToken(ctx, "hall")
+		assert.ErrorIs(t, err, ErrTokenNotFound)
+	})
+}
@@ -0,0 +1,115 @@
+package clientmiddleware
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/plugins/instrumentationutils"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// attributionOther is the dashboard of the requests attributed once the maximum number of series is reached
+const attributionOther = "other"
+
+// requestAttributionMetrics contains the prometheus metrics used by the RequestAttributionMiddleware.
+type requestAttributionMetrics struct {
+	requestCounter  *prometheus.CounterVec
+	requestDuration *prometheus.CounterVec
+}
+
+// requestAttributionSeries bounds the number of attributed series. It is shared by the middlewares, like the metrics.
+type requestAttributionSeries struct {
+	mu        sync.Mutex
+	seen      map[string]struct{}
+	maxSeries int
+}
+
+// RequestAttributionMiddleware is a middleware that attributes the plugin query requests to the dashboards and the
+// panels which issued them. Only counters are used, since their cardinality is the number of attributed series.
+type RequestAttributionMiddleware struct {
+	backend.BaseHandler
+	requestAttributionMetrics
+	series *requestAttributionSeries
+	mode   string
+}
+
+func newRequestAttributionMiddleware(promRegisterer prometheus.Registerer, mode string, maxSeries int) *RequestAttributionMiddleware {
+	requestCounter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "plugin_request_attributed_total",
+		Help:      "The total amount of plugin query requests by dashboard and panel",
+	}, []string{"plugin_id", "dashboard_uid", "panel_id", "status"})
+	requestDuration := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "plugin_request_attributed_duration_seconds_total",
+		Help:      "The total duration of the plugin query requests by dashboard and panel",
+	}, []string{"plugin_id", "dashboard_uid", "panel_id"})
+	promRegisterer.MustRegister(requestCounter, requestDuration)
+
+	return &RequestAttributionMiddleware{
+		requestAttributionMetrics: requestAttributionMetrics{
+			requestCounter:  requestCounter,
+			requestDuration: requestDuration,
+		},
+		series: &requestAttributionSeries{seen: map[string]struct{}{}, maxSeries: maxSeries},
+		mode:   mode,
+	}
+}
+
+// NewRequestAttributionMiddleware returns a new RequestAttributionMiddleware. The mode is one of
+// setting.PluginRequestAttributionDashboard and setting.PluginRequestAttributionPanel.
+func NewRequestAttributionMiddleware(promRegisterer prometheus.Registerer, mode string, maxSeries int) backend.HandlerMiddleware {
+	m := newRequestAttributionMiddleware(promRegisterer, mode, maxSeries)
+	return backend.HandlerMiddlewareFunc(func(next backend.Handler) backend.Handler {
+		return &RequestAttributionMiddleware{
+			BaseHandler:               backend.NewBaseHandler(next),
+			requestAttributionMetrics: m.requestAttributionMetrics,
+			series:                    m.series,
+			mode:                      m.mode,
+		}
+	})
+}
+
+// labels returns the dashboard and the panel labels of the attribution. The dashboards, or the dashboards and the
+// panels, which are seen once the maximum number of series is reached are labelled as other.
+func (m *RequestAttributionMiddleware) labels(attribution query.RequestAttribution) (string, string) {
+	dashboardUID, panelID := attribution.DashboardUID, ""
+	if m.mode == setting.PluginRequestAttributionPanel && attribution.PanelID > 0 {
+		panelID = strconv.FormatInt(attribution.PanelID, 10)
+	}
+
+	series := dashboardUID + "/" + panelID
+	m.series.mu.Lock()
+	defer m.series.mu.Unlock()
+	if _, ok := m.series.seen[series]; !ok {
+		if len(m.series.seen) >= m.series.maxSeries {
+			return attributionOther, ""
+		}
+		m.series.seen[series] = struct{}{}
+	}
+	return dashboardUID, panelID
+}
+
+func (m *RequestAttributionMiddleware) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	attribution, ok := query.RequestAttributionFromContext(ctx)
+	if !ok {
+		return m.BaseHandler.QueryData(ctx, req)
+	}
+
+	start := time.Now()
+	resp, err := m.BaseHandler.QueryData(ctx, req)
+	elapsed := time.Since(start)
+
+	status := instrumentationutils.RequestStatusFromQueryDataResponse(resp, err)
+	dashboardUID, panelID := m.labels(attribution)
+	m.requestCounter.WithLabelValues(req.PluginContext.PluginID, dashboardUID, panelID, status.String()).Inc()
+	m.requestDuration.WithLabelValues(req.PluginContext.PluginID, dashboardUID, panelID).Add(elapsed.Seconds())
+
+	return resp, err
+}
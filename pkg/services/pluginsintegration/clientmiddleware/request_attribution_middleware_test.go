@@ -0,0 +1,83 @@
+package clientmiddleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/handlertest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/plugins/instrumentationutils"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const metricRequestAttributedTotal = "grafana_plugin_request_attributed_total"
+
+func TestRequestAttributionMiddleware(t *testing.T) {
+	pCtx := backend.PluginContext{PluginID: pluginID}
+	ok := instrumentationutils.RequestStatusOK.String()
+
+	setup := func(t *testing.T, mode string, maxSeries int) (*RequestAttributionMiddleware, *handlertest.HandlerMiddlewareTest, *prometheus.Registry) {
+		promRegistry := prometheus.NewRegistry()
+		mw := newRequestAttributionMiddleware(promRegistry, mode, maxSeries)
+		cdt := handlertest.NewHandlerMiddlewareTest(t, handlertest.WithMiddlewares(
+			backend.HandlerMiddlewareFunc(func(next backend.Handler) backend.Handler {
+				mw.BaseHandler = backend.NewBaseHandler(next)
+				return mw
+			}),
+		))
+		return mw, cdt, promRegistry
+	}
+
+	queryData := func(t *testing.T, cdt *handlertest.HandlerMiddlewareTest, attribution *query.RequestAttribution) {
+		ctx := context.Background()
+		if attribution != nil {
+			ctx = query.WithRequestAttribution(ctx, *attribution)
+		}
+		_, err := cdt.MiddlewareHandler.QueryData(ctx, &backend.QueryDataRequest{PluginContext: pCtx})
+		require.NoError(t, err)
+	}
+
+	t.Run("should not instrument requests without attribution", func(t *testing.T) {
+		_, cdt, promRegistry := setup(t, setting.PluginRequestAttributionPanel, 10)
+
+		queryData(t, cdt, nil)
+
+		require.Equal(t, 0, testutil.CollectAndCount(promRegistry, metricRequestAttributedTotal))
+	})
+
+	t.Run("should attribute requests to the dashboard", func(t *testing.T) {
+		mw, cdt, _ := setup(t, setting.PluginRequestAttributionDashboard, 10)
+
+		queryData(t, cdt, &query.RequestAttribution{DashboardUID: "dash-1", PanelID: 2})
+		queryData(t, cdt, &query.RequestAttribution{DashboardUID: "dash-1", PanelID: 3})
+
+		require.Equal(t, 2.0, testutil.ToFloat64(mw.requestCounter.WithLabelValues(pluginID, "dash-1", "", ok)))
+	})
+
+	t.Run("should attribute requests to the panel", func(t *testing.T) {
+		mw, cdt, promRegistry := setup(t, setting.PluginRequestAttributionPanel, 10)
+
+		queryData(t, cdt, &query.RequestAttribution{DashboardUID: "dash-1", PanelID: 2})
+		queryData(t, cdt, &query.RequestAttribution{DashboardUID: "dash-1", PanelID: 3})
+
+		require.Equal(t, 2, testutil.CollectAndCount(promRegistry, metricRequestAttributedTotal))
+		require.Equal(t, 1.0, testutil.ToFloat64(mw.requestCounter.WithLabelValues(pluginID, "dash-1", "2", ok)))
+		require.Equal(t, 1.0, testutil.ToFloat64(mw.requestCounter.WithLabelValues(pluginID, "dash-1", "3", ok)))
+	})
+
+	t.Run("should attribute requests to other once the maximum number of series is reached", func(t *testing.T) {
+		mw, cdt, _ := setup(t, setting.PluginRequestAttributionDashboard, 1)
+
+		queryData(t, cdt, &query.RequestAttribution{DashboardUID: "dash-1"})
+		queryData(t, cdt, &query.RequestAttribution{DashboardUID: "dash-2"})
+		queryData(t, cdt, &query.RequestAttribution{DashboardUID: "dash-1"})
+
+		require.Equal(t, 2.0, testutil.ToFloat64(mw.requestCounter.WithLabelValues(pluginID, "dash-1", "", ok)))
+		require.Equal(t, 1.0, testutil.ToFloat64(mw.requestCounter.WithLabelValues(pluginID, attributionOther, "", ok)))
+	})
+}
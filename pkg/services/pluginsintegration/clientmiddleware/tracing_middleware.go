@@ -60,13 +60,24 @@ func (m *TracingMiddleware) traceWrap(
 		span.SetAttributes(attribute.String("user", u.Login))
 	}
 
+	// The dashboard and the panel the request is attributed to, they take precedence over the http headers
+	attribution, attributed := query.RequestAttributionFromContext(ctx)
+	if attributed {
+		span.SetAttributes(attribute.String("dashboard_uid", attribution.DashboardUID))
+		if attribution.PanelID > 0 {
+			span.SetAttributes(attribute.Int64("panel_id", attribution.PanelID))
+		}
+	}
+
 	// Additional attributes from http headers
 	if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil && reqCtx.Req != nil && len(reqCtx.Req.Header) > 0 {
-		if v, err := strconv.Atoi(reqCtx.Req.Header.Get(query.HeaderPanelID)); err == nil {
-			span.SetAttributes(attribute.Int("panel_id", v))
-		}
 		setSpanAttributeFromHTTPHeader(reqCtx.Req.Header, span, "query_group_id", query.HeaderQueryGroupID)
-		setSpanAttributeFromHTTPHeader(reqCtx.Req.Header, span, "dashboard_uid", query.HeaderDashboardUID)
+		if !attributed {
+			if v, err := strconv.Atoi(reqCtx.Req.Header.Get(query.HeaderPanelID)); err == nil {
+				span.SetAttributes(attribute.Int("panel_id", v))
+			}
+			setSpanAttributeFromHTTPHeader(reqCtx.Req.Header, span, "dashboard_uid", query.HeaderDashboardUID)
+		}
 	}
 
 	// Return ctx with span + cleanup func
@@ -189,6 +189,10 @@ func CreateMiddlewares(cfg *setting.Cfg, oAuthTokenService oauthtoken.OAuthToken
 		clientmiddleware.NewContextualLoggerMiddleware(),
 	}
 
+	if cfg.PluginRequestAttribution != "" && cfg.PluginRequestAttribution != setting.PluginRequestAttributionOff {
+		middlewares = append(middlewares, clientmiddleware.NewRequestAttributionMiddleware(promRegisterer, cfg.PluginRequestAttribution, cfg.PluginRequestAttributionMaxSeries))
+	}
+
 	if cfg.PluginLogBackendRequests {
 		middlewares = append(middlewares, clientmiddleware.NewLoggerMiddleware(log.New("plugin.instrumentation"), registry))
 	}
@@ -16,6 +16,42 @@ var ErrUnknownCookieType = errutil.BadRequest(
 	"preferences.unknownCookieType",
 	errutil.WithPublicMessage("Got an unknown cookie preference type. Expected a set containing one or more of 'functional', 'performance', or 'analytics'}"),
 )
+var ErrPreferenceLocked = errutil.Forbidden(
+	"preferences.locked",
+	errutil.WithPublicMessage("The preference is locked by the organization"),
+)
+var ErrUnknownLockablePreference = errutil.BadRequest(
+	"preferences.unknownLockablePreference",
+	errutil.WithPublicMessage("Got an unknown preference to lock. Expected one or more of 'theme', 'timezone', 'weekStart', 'homeDashboard', 'language', or 'regionalFormat'"),
+)
+
+// The preferences which are cascaded from the org to the teams and the users, and which can be locked by the org
+const (
+	PreferenceTheme          = "theme"
+	PreferenceTimezone       = "timezone"
+	PreferenceWeekStart      = "weekStart"
+	PreferenceHomeDashboard  = "homeDashboard"
+	PreferenceLanguage       = "language"
+	PreferenceRegionalFormat = "regionalFormat"
+)
+
+var LockablePreferences = []string{
+	PreferenceTheme, PreferenceTimezone, PreferenceWeekStart, PreferenceHomeDashboard, PreferenceLanguage, PreferenceRegionalFormat,
+}
+
+// PreferenceLevel is the level a preference is set at
+type PreferenceLevel string
+
+const (
+	LevelDefault PreferenceLevel = "default"
+	LevelOrg     PreferenceLevel = "org"
+	LevelTeam    PreferenceLevel = "team"
+	LevelUser    PreferenceLevel = "user"
+)
+
+// Precedence lists the preference levels from the lowest to the highest precedence. The teams of a user
+// are applied by ascending ID, and the preferences locked by the org take precedence over all the levels.
+var Precedence = []PreferenceLevel{LevelDefault, LevelOrg, LevelTeam, LevelUser}
 
 type Preference struct {
 	ID      int64   `xorm:"pk autoincr 'id'" db:"id"`
@@ -98,6 +134,36 @@ type PreferenceJSONData struct {
 	QueryHistory      QueryHistoryPreference `json:"queryHistory"`
 	CookiePreferences map[string]struct{}    `json:"cookiePreferences"`
 	Navbar            NavbarPreference       `json:"navbar"`
+	// LockedPreferences are the preferences of an org which the teams and the users can't override
+	LockedPreferences []string `json:"lockedPreferences,omitempty"`
+}
+
+// ResolvedPreference is the effective value of a preference with the level which set it
+type ResolvedPreference struct {
+	Value  string          `json:"value"`
+	Source PreferenceLevel `json:"source"`
+	// TeamID is the team whose preferences set the value, when the source is a team
+	TeamID int64 `json:"teamId,omitempty"`
+	// Locked reports whether the value is enforced by the org
+	Locked bool `json:"locked"`
+}
+
+// swagger:model
+type ResolvedPreferences struct {
+	// Precedence lists the levels from the lowest to the highest precedence
+	Precedence  []PreferenceLevel             `json:"precedence"`
+	Preferences map[string]ResolvedPreference `json:"preferences"`
+}
+
+// swagger:model
+type LockedPreferences struct {
+	// Enum: theme,timezone,weekStart,homeDashboard,language,regionalFormat
+	Preferences []string `json:"preferences"`
+}
+
+type SetLockedPreferencesCommand struct {
+	OrgID       int64
+	Preferences []string
 }
 
 type QueryHistoryPreference struct {
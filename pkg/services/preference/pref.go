@@ -6,9 +6,14 @@ import (
 
 type Service interface {
 	GetWithDefaults(context.Context, *GetPreferenceWithDefaultsQuery) (*Preference, error)
+	// GetResolved returns the effective preferences of a user with the level which set each of them
+	GetResolved(context.Context, *GetPreferenceWithDefaultsQuery) (*ResolvedPreferences, error)
 	Get(context.Context, *GetPreferenceQuery) (*Preference, error)
 	Save(context.Context, *SavePreferenceCommand) error
 	Patch(context.Context, *PatchPreferenceCommand) error
 	GetDefaults() *Preference
 	DeleteByUser(context.Context, int64) error
+	// GetLocked returns the preferences the org enforces on its teams and users
+	GetLocked(ctx context.Context, orgID int64) ([]string, error)
+	SetLocked(context.Context, *SetLockedPreferencesCommand) error
 }
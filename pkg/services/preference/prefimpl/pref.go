@@ -3,6 +3,7 @@ package prefimpl
 import (
 	"context"
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
@@ -38,27 +39,82 @@ func prefsFromConfig(cfg *setting.Cfg) pref.Preference {
 }
 
 func (s *Service) GetWithDefaults(ctx context.Context, query *pref.GetPreferenceWithDefaultsQuery) (*pref.Preference, error) {
+	res, _, err := s.resolve(ctx, query)
+	return res, err
+}
+
+func (s *Service) GetResolved(ctx context.Context, query *pref.GetPreferenceWithDefaultsQuery) (*pref.ResolvedPreferences, error) {
+	res, sources, err := s.resolve(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{
+		pref.PreferenceTheme:          res.Theme,
+		pref.PreferenceTimezone:       res.Timezone,
+		pref.PreferenceHomeDashboard:  res.HomeDashboardUID,
+		pref.PreferenceLanguage:       res.JSONData.Language,
+		pref.PreferenceRegionalFormat: res.JSONData.RegionalFormat,
+	}
+	if res.WeekStart != nil {
+		values[pref.PreferenceWeekStart] = *res.WeekStart
+	}
+
+	resolved := &pref.ResolvedPreferences{
+		Precedence:  pref.Precedence,
+		Preferences: make(map[string]pref.ResolvedPreference, len(pref.LockablePreferences)),
+	}
+	for _, key := range pref.LockablePreferences {
+		source := sources[key]
+		source.Value = values[key]
+		if source.Source == "" {
+			source.Source = pref.LevelDefault
+		}
+		resolved.Preferences[key] = source
+	}
+	return resolved, nil
+}
+
+// resolve cascades the preferences of the org, the teams and the user over the defaults, then enforces
+// the preferences locked by the org. It returns the levels which set the lockable preferences.
+func (s *Service) resolve(ctx context.Context, query *pref.GetPreferenceWithDefaultsQuery) (*pref.Preference, map[string]pref.ResolvedPreference, error) {
 	listQuery := &pref.Preference{
 		Teams:  query.Teams,
 		OrgID:  query.OrgID,
 		UserID: query.UserID,
 	}
 
+	// the preferences are ordered by precedence: the org, the teams, then the user
 	prefs, err := s.store.List(ctx, listQuery)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	res := s.GetDefaults()
+	sources := map[string]pref.ResolvedPreference{}
+	var orgPrefs *pref.Preference
 	for _, p := range prefs {
+		source := pref.ResolvedPreference{Source: pref.LevelOrg}
+		switch {
+		case p.UserID != 0:
+			source.Source = pref.LevelUser
+		case p.TeamID != 0:
+			source = pref.ResolvedPreference{Source: pref.LevelTeam, TeamID: p.TeamID}
+		default:
+			orgPrefs = p
+		}
+
 		if p.Theme != "" {
 			res.Theme = p.Theme
+			sources[pref.PreferenceTheme] = source
 		}
 		if p.Timezone != "" {
 			res.Timezone = p.Timezone
+			sources[pref.PreferenceTimezone] = source
 		}
 		if p.WeekStart != nil && *p.WeekStart != "" {
 			res.WeekStart = p.WeekStart
+			sources[pref.PreferenceWeekStart] = source
 		}
 		// nolint: staticcheck
 		if p.HomeDashboardID != 0 {
@@ -66,14 +122,17 @@ func (s *Service) GetWithDefaults(ctx context.Context, query *pref.GetPreference
 		}
 		if p.HomeDashboardUID != "" {
 			res.HomeDashboardUID = p.HomeDashboardUID
+			sources[pref.PreferenceHomeDashboard] = source
 		}
 		if p.JSONData != nil {
 			if p.JSONData.Language != "" {
 				res.JSONData.Language = p.JSONData.Language
+				sources[pref.PreferenceLanguage] = source
 			}
 
 			if p.JSONData.RegionalFormat != "" {
 				res.JSONData.RegionalFormat = p.JSONData.RegionalFormat
+				sources[pref.PreferenceRegionalFormat] = source
 			}
 
 			if p.JSONData.QueryHistory.HomeTab != "" {
@@ -90,7 +149,55 @@ func (s *Service) GetWithDefaults(ctx context.Context, query *pref.GetPreference
 		}
 	}
 
-	return res, err
+	if orgPrefs != nil && orgPrefs.JSONData != nil {
+		s.enforceLocked(res, sources, orgPrefs)
+	}
+	return res, sources, nil
+}
+
+// enforceLocked sets the preferences locked by the org to the values of the org, or to the defaults
+// when the org doesn't set them
+func (s *Service) enforceLocked(res *pref.Preference, sources map[string]pref.ResolvedPreference, orgPrefs *pref.Preference) {
+	defaults := s.GetDefaults()
+	for _, key := range orgPrefs.JSONData.LockedPreferences {
+		source := pref.ResolvedPreference{Source: pref.LevelOrg, Locked: true}
+		switch key {
+		case pref.PreferenceTheme:
+			res.Theme = orgPrefs.Theme
+			if res.Theme == "" {
+				res.Theme, source.Source = defaults.Theme, pref.LevelDefault
+			}
+		case pref.PreferenceTimezone:
+			res.Timezone = orgPrefs.Timezone
+			if res.Timezone == "" {
+				res.Timezone, source.Source = defaults.Timezone, pref.LevelDefault
+			}
+		case pref.PreferenceWeekStart:
+			res.WeekStart = orgPrefs.WeekStart
+			if res.WeekStart == nil || *res.WeekStart == "" {
+				res.WeekStart, source.Source = defaults.WeekStart, pref.LevelDefault
+			}
+		case pref.PreferenceHomeDashboard:
+			res.HomeDashboardID = orgPrefs.HomeDashboardID // nolint:staticcheck
+			res.HomeDashboardUID = orgPrefs.HomeDashboardUID
+			if res.HomeDashboardUID == "" {
+				source.Source = pref.LevelDefault
+			}
+		case pref.PreferenceLanguage:
+			res.JSONData.Language = orgPrefs.JSONData.Language
+			if res.JSONData.Language == "" {
+				res.JSONData.Language, source.Source = defaults.JSONData.Language, pref.LevelDefault
+			}
+		case pref.PreferenceRegionalFormat:
+			res.JSONData.RegionalFormat = orgPrefs.JSONData.RegionalFormat
+			if res.JSONData.RegionalFormat == "" {
+				source.Source = pref.LevelDefault
+			}
+		default:
+			continue
+		}
+		sources[key] = source
+	}
 }
 
 func (s *Service) Get(ctx context.Context, query *pref.GetPreferenceQuery) (*pref.Preference, error) {
@@ -115,6 +222,17 @@ func (s *Service) Save(ctx context.Context, cmd *pref.SavePreferenceCommand) err
 		return err
 	}
 
+	if err := s.checkLocked(ctx, cmd.OrgID, cmd.UserID, cmd.TeamID, map[string]*string{
+		pref.PreferenceTheme:          &cmd.Theme,
+		pref.PreferenceTimezone:       &cmd.Timezone,
+		pref.PreferenceWeekStart:      &cmd.WeekStart,
+		pref.PreferenceHomeDashboard:  cmd.HomeDashboardUID,
+		pref.PreferenceLanguage:       &cmd.Language,
+		pref.PreferenceRegionalFormat: &cmd.RegionalFormat,
+	}); err != nil {
+		return err
+	}
+
 	preference, err := s.store.Get(ctx, &pref.Preference{
 		OrgID:  cmd.OrgID,
 		UserID: cmd.UserID,
@@ -157,12 +275,27 @@ func (s *Service) Save(ctx context.Context, cmd *pref.SavePreferenceCommand) err
 	if cmd.HomeDashboardUID != nil {
 		preference.HomeDashboardUID = *cmd.HomeDashboardUID
 	}
+	if preference.JSONData != nil {
+		// the locks of the org are set with SetLocked
+		jsonData.LockedPreferences = preference.JSONData.LockedPreferences
+	}
 	preference.JSONData = jsonData
 
 	return s.store.Update(ctx, preference)
 }
 
 func (s *Service) Patch(ctx context.Context, cmd *pref.PatchPreferenceCommand) error {
+	if err := s.checkLocked(ctx, cmd.OrgID, cmd.UserID, cmd.TeamID, map[string]*string{
+		pref.PreferenceTheme:          cmd.Theme,
+		pref.PreferenceTimezone:       cmd.Timezone,
+		pref.PreferenceWeekStart:      cmd.WeekStart,
+		pref.PreferenceHomeDashboard:  cmd.HomeDashboardUID,
+		pref.PreferenceLanguage:       cmd.Language,
+		pref.PreferenceRegionalFormat: cmd.RegionalFormat,
+	}); err != nil {
+		return err
+	}
+
 	var exists bool
 	preference, err := s.store.Get(ctx, &pref.Preference{
 		OrgID:  cmd.OrgID,
@@ -276,6 +409,96 @@ func (s *Service) DeleteByUser(ctx context.Context, userID int64) error {
 	return s.store.DeleteByUser(ctx, userID)
 }
 
+func (s *Service) GetLocked(ctx context.Context, orgID int64) ([]string, error) {
+	orgPrefs, err := s.store.Get(ctx, &pref.Preference{OrgID: orgID})
+	if errors.Is(err, pref.ErrPrefNotFound) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if orgPrefs.JSONData == nil || orgPrefs.JSONData.LockedPreferences == nil {
+		return []string{}, nil
+	}
+	return orgPrefs.JSONData.LockedPreferences, nil
+}
+
+func (s *Service) SetLocked(ctx context.Context, cmd *pref.SetLockedPreferencesCommand) error {
+	for _, key := range cmd.Preferences {
+		if !slices.Contains(pref.LockablePreferences, key) {
+			return pref.ErrUnknownLockablePreference.Errorf("'%s' can't be locked", key)
+		}
+	}
+	// the locked preferences are stored in a stable order, without duplicates
+	locked := make([]string, 0, len(cmd.Preferences))
+	for _, key := range pref.LockablePreferences {
+		if slices.Contains(cmd.Preferences, key) {
+			locked = append(locked, key)
+		}
+	}
+
+	exists := true
+	orgPrefs, err := s.store.Get(ctx, &pref.Preference{OrgID: cmd.OrgID})
+	if errors.Is(err, pref.ErrPrefNotFound) {
+		exists = false
+		orgPrefs = &pref.Preference{OrgID: cmd.OrgID, Created: time.Now()}
+	} else if err != nil {
+		return err
+	}
+
+	if orgPrefs.JSONData == nil {
+		orgPrefs.JSONData = &pref.PreferenceJSONData{}
+	}
+	orgPrefs.JSONData.LockedPreferences = locked
+	orgPrefs.Updated = time.Now()
+	orgPrefs.Version += 1
+
+	if exists {
+		return s.store.Update(ctx, orgPrefs)
+	}
+	_, err = s.store.Insert(ctx, orgPrefs)
+	return err
+}
+
+// checkLocked rejects the changes of the preferences of a team or a user which are locked by the org. The
+// values which are cleared or unchanged are accepted.
+func (s *Service) checkLocked(ctx context.Context, orgID, userID, teamID int64, changes map[string]*string) error {
+	if userID == 0 && teamID == 0 {
+		return nil
+	}
+
+	locked, err := s.GetLocked(ctx, orgID)
+	if err != nil || len(locked) == 0 {
+		return err
+	}
+
+	current, err := s.store.Get(ctx, &pref.Preference{OrgID: orgID, UserID: userID, TeamID: teamID})
+	if err != nil && !errors.Is(err, pref.ErrPrefNotFound) {
+		return err
+	}
+	values := map[string]string{}
+	if current != nil {
+		values[pref.PreferenceTheme] = current.Theme
+		values[pref.PreferenceTimezone] = current.Timezone
+		values[pref.PreferenceHomeDashboard] = current.HomeDashboardUID
+		if current.WeekStart != nil {
+			values[pref.PreferenceWeekStart] = *current.WeekStart
+		}
+		if current.JSONData != nil {
+			values[pref.PreferenceLanguage] = current.JSONData.Language
+			values[pref.PreferenceRegionalFormat] = current.JSONData.RegionalFormat
+		}
+	}
+
+	for _, key := range locked {
+		value := changes[key]
+		if value != nil && *value != "" && *value != values[key] {
+			return pref.ErrPreferenceLocked.Errorf("the %s preference is locked by org %d", key, orgID)
+		}
+	}
+	return nil
+}
+
 func parseCookiePreferences(prefs []pref.CookieType) (map[string]struct{}, error) {
 	allowed := map[pref.CookieType]struct{}{
 		"analytics":   {},
@@ -445,6 +445,111 @@ func TestSave(t *testing.T) {
 	})
 }
 
+func TestGetResolved(t *testing.T) {
+	weekStart := "1"
+	prefService := &Service{
+		store:    newFake(),
+		defaults: prefsFromConfig(setting.NewCfg()),
+	}
+	insertPrefs(t, prefService.store,
+		pref.Preference{OrgID: 1, Theme: "light", Timezone: "browser", WeekStart: &weekStart},
+		pref.Preference{OrgID: 1, TeamID: 2, Timezone: "UTC", HomeDashboardUID: "team-home"},
+		pref.Preference{OrgID: 1, UserID: 3, Theme: "dark"},
+	)
+
+	resolved, err := prefService.GetResolved(context.Background(), &pref.GetPreferenceWithDefaultsQuery{OrgID: 1, UserID: 3, Teams: []int64{2}})
+	require.NoError(t, err)
+
+	assert.Equal(t, pref.Precedence, resolved.Precedence)
+	assert.Equal(t, pref.ResolvedPreference{Value: "dark", Source: pref.LevelUser}, resolved.Preferences[pref.PreferenceTheme])
+	assert.Equal(t, pref.ResolvedPreference{Value: "UTC", Source: pref.LevelTeam, TeamID: 2}, resolved.Preferences[pref.PreferenceTimezone])
+	assert.Equal(t, pref.ResolvedPreference{Value: "team-home", Source: pref.LevelTeam, TeamID: 2}, resolved.Preferences[pref.PreferenceHomeDashboard])
+	assert.Equal(t, pref.ResolvedPreference{Value: "1", Source: pref.LevelOrg}, resolved.Preferences[pref.PreferenceWeekStart])
+	assert.Equal(t, pref.LevelDefault, resolved.Preferences[pref.PreferenceLanguage].Source)
+}
+
+func TestLockedPreferences(t *testing.T) {
+	setup := func(t *testing.T) *Service {
+		prefService := &Service{
+			store:    newFake(),
+			defaults: prefsFromConfig(setting.NewCfg()),
+		}
+		insertPrefs(t, prefService.store,
+			pref.Preference{OrgID: 1, Theme: "light", HomeDashboardUID: "org-home"},
+			pref.Preference{OrgID: 1, TeamID: 2, HomeDashboardUID: "team-home"},
+			pref.Preference{OrgID: 1, UserID: 3, Theme: "dark", Timezone: "UTC"},
+		)
+		require.NoError(t, prefService.SetLocked(context.Background(), &pref.SetLockedPreferencesCommand{
+			OrgID:       1,
+			Preferences: []string{pref.PreferenceHomeDashboard, pref.PreferenceTheme, pref.PreferenceTheme},
+		}))
+		return prefService
+	}
+
+	t.Run("should store the locked preferences without duplicates", func(t *testing.T) {
+		prefService := setup(t)
+
+		locked, err := prefService.GetLocked(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{pref.PreferenceTheme, pref.PreferenceHomeDashboard}, locked)
+	})
+
+	t.Run("should reject unknown preferences", func(t *testing.T) {
+		prefService := setup(t)
+
+		err := prefService.SetLocked(context.Background(), &pref.SetLockedPreferencesCommand{OrgID: 1, Preferences: []string{"navbar"}})
+		require.ErrorIs(t, err, pref.ErrUnknownLockablePreference)
+	})
+
+	t.Run("should enforce the values of the org over the teams and the users", func(t *testing.T) {
+		prefService := setup(t)
+
+		preference, err := prefService.GetWithDefaults(context.Background(), &pref.GetPreferenceWithDefaultsQuery{OrgID: 1, UserID: 3, Teams: []int64{2}})
+		require.NoError(t, err)
+		assert.Equal(t, "light", preference.Theme)
+		assert.Equal(t, "org-home", preference.HomeDashboardUID)
+		assert.Equal(t, "UTC", preference.Timezone)
+
+		resolved, err := prefService.GetResolved(context.Background(), &pref.GetPreferenceWithDefaultsQuery{OrgID: 1, UserID: 3, Teams: []int64{2}})
+		require.NoError(t, err)
+		assert.Equal(t, pref.ResolvedPreference{Value: "org-home", Source: pref.LevelOrg, Locked: true}, resolved.Preferences[pref.PreferenceHomeDashboard])
+		assert.False(t, resolved.Preferences[pref.PreferenceTimezone].Locked)
+	})
+
+	t.Run("should reject the changes of locked preferences by the teams and the users", func(t *testing.T) {
+		prefService := setup(t)
+
+		theme := "light"
+		err := prefService.Patch(context.Background(), &pref.PatchPreferenceCommand{OrgID: 1, UserID: 3, Theme: &theme})
+		require.ErrorIs(t, err, pref.ErrPreferenceLocked)
+
+		home := "other-home"
+		err = prefService.Save(context.Background(), &pref.SavePreferenceCommand{OrgID: 1, TeamID: 2, HomeDashboardUID: &home})
+		require.ErrorIs(t, err, pref.ErrPreferenceLocked)
+	})
+
+	t.Run("should accept unchanged or cleared locked preferences", func(t *testing.T) {
+		prefService := setup(t)
+
+		err := prefService.Save(context.Background(), &pref.SavePreferenceCommand{OrgID: 1, UserID: 3, Theme: "dark", Timezone: "browser"})
+		require.NoError(t, err)
+
+		err = prefService.Save(context.Background(), &pref.SavePreferenceCommand{OrgID: 1, UserID: 3, Timezone: "browser"})
+		require.NoError(t, err)
+	})
+
+	t.Run("should keep the locks when the org preferences are saved", func(t *testing.T) {
+		prefService := setup(t)
+
+		err := prefService.Save(context.Background(), &pref.SavePreferenceCommand{OrgID: 1, Theme: "dark"})
+		require.NoError(t, err)
+
+		locked, err := prefService.GetLocked(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{pref.PreferenceTheme, pref.PreferenceHomeDashboard}, locked)
+	})
+}
+
 func insertPrefs(t testing.TB, store store, preferences ...pref.Preference) {
 	t.Helper()
 	for _, p := range preferences {
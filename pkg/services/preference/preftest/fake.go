@@ -8,6 +8,8 @@ import (
 
 type FakePreferenceService struct {
 	ExpectedPreference *pref.Preference
+	ExpectedResolved   *pref.ResolvedPreferences
+	ExpectedLocked     []string
 	ExpectedError      error
 }
 
@@ -38,3 +40,15 @@ func (f *FakePreferenceService) Patch(ctx context.Context, cmd *pref.PatchPrefer
 func (f *FakePreferenceService) DeleteByUser(context.Context, int64) error {
 	return f.ExpectedError
 }
+
+func (f *FakePreferenceService) GetResolved(ctx context.Context, query *pref.GetPreferenceWithDefaultsQuery) (*pref.ResolvedPreferences, error) {
+	return f.ExpectedResolved, f.ExpectedError
+}
+
+func (f *FakePreferenceService) GetLocked(ctx context.Context, orgID int64) ([]string, error) {
+	return f.ExpectedLocked, f.ExpectedError
+}
+
+func (f *FakePreferenceService) SetLocked(ctx context.Context, cmd *pref.SetLockedPreferencesCommand) error {
+	return f.ExpectedError
+}
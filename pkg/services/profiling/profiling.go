@@ -0,0 +1,88 @@
+package profiling
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+var (
+	ErrCaptureNotFound = errutil.NotFound("profiling.capture-not-found")
+	ErrCaptureNotDone  = errutil.Conflict("profiling.capture-not-done",
+		errutil.WithPublicMessage("The capture isn't done, retry once it's done"))
+	ErrInvalidCapture = errutil.BadRequest("profiling.invalid-capture")
+	ErrProfilerBusy   = errutil.Conflict("profiling.busy",
+		errutil.WithPublicMessage("A capture of the same type is running, retry once it's done"))
+	ErrTooManyCaptures = errutil.BadRequest("profiling.too-many-captures",
+		errutil.WithPublicMessage("Too many captures, delete some of them or wait for them to expire"))
+)
+
+type Type string
+
+const (
+	TypeCPU       Type = "cpu"
+	TypeHeap      Type = "heap"
+	TypeAllocs    Type = "allocs"
+	TypeGoroutine Type = "goroutine"
+	TypeBlock     Type = "block"
+	TypeMutex     Type = "mutex"
+	// TypeTrace is a runtime execution trace, read with go tool trace
+	TypeTrace Type = "trace"
+)
+
+// Timed reports whether the type is captured for a duration, the other types are snapshots.
+func (t Type) Timed() bool {
+	return t == TypeCPU || t == TypeTrace
+}
+
+func (t Type) Valid() bool {
+	switch t {
+	case TypeCPU, TypeHeap, TypeAllocs, TypeGoroutine, TypeBlock, TypeMutex, TypeTrace:
+		return true
+	}
+	return false
+}
+
+type Status string
+
+const (
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+// Service captures profiles and runtime traces of the Grafana instance on demand, and keeps them temporarily.
+type Service interface {
+	// Capture starts a capture, it runs in the background for the duration of the timed types.
+	Capture(ctx context.Context, cmd *CaptureCommand) (*Capture, error)
+	// Captures returns the captures, the most recent first.
+	Captures(ctx context.Context) []*Capture
+	GetCapture(ctx context.Context, id string) (*Capture, error)
+	// Open returns the content of a done capture, the caller closes it.
+	Open(ctx context.Context, id string) (io.ReadCloser, *Capture, error)
+	DeleteCapture(ctx context.Context, id string) error
+}
+
+type CaptureCommand struct {
+	Type Type `json:"type"`
+	// DurationSeconds is the duration of the timed types, 30 seconds for a CPU profile and 5 seconds for a trace by
+	// default
+	DurationSeconds int `json:"durationSeconds"`
+	// CreatedBy is the login of the user starting the capture
+	CreatedBy string `json:"-"`
+}
+
+type Capture struct {
+	ID              string    `json:"id"`
+	Type            Type      `json:"type"`
+	Status          Status    `json:"status"`
+	DurationSeconds int       `json:"durationSeconds,omitempty"`
+	Created         time.Time `json:"created"`
+	// Expires is when the capture is deleted, set once it's done or failed
+	Expires   *time.Time `json:"expires,omitempty"`
+	Size      int64      `json:"size"`
+	Error     string     `json:"error,omitempty"`
+	CreatedBy string     `json:"createdBy"`
+}
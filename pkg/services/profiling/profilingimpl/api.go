@@ -0,0 +1,180 @@
+package profilingimpl
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/profiling"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister) {
+	router.Group("/api/admin/profiling", func(profilingRoute routing.RouteRegister) {
+		profilingRoute.Get("/captures", routing.Wrap(s.GetCaptures))
+		profilingRoute.Post("/captures", routing.Wrap(s.CreateCapture))
+		profilingRoute.Get("/captures/:id", routing.Wrap(s.GetCaptureByID))
+		profilingRoute.Get("/captures/:id/download", routing.Wrap(s.DownloadCapture))
+		profilingRoute.Delete("/captures/:id", routing.Wrap(s.DeleteCaptureByID))
+	}, middleware.ReqGrafanaAdmin)
+}
+
+// swagger:route GET /admin/profiling/captures admin getProfilingCaptures
+//
+// List the captured profiles.
+//
+// Returns the captures of the instance, the most recent first.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getProfilingCapturesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+func (s *Service) GetCaptures(c *contextmodel.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, s.Captures(c.Req.Context()))
+}
+
+// swagger:route POST /admin/profiling/captures admin createProfilingCapture
+//
+// Capture a profile or a runtime trace.
+//
+// The CPU profiles and the runtime traces are captured in the background for the duration of the capture, the other
+// profiles are snapshots. Download the capture once its status is done.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 202: profilingCaptureResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+func (s *Service) CreateCapture(c *contextmodel.ReqContext) response.Response {
+	cmd := profiling.CaptureCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.CreatedBy = c.GetLogin()
+
+	capture, err := s.Capture(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to start capture", err)
+	}
+	return response.JSON(http.StatusAccepted, capture)
+}
+
+// swagger:route GET /admin/profiling/captures/{id} admin getProfilingCapture
+//
+// Get a capture.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: profilingCaptureResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+func (s *Service) GetCaptureByID(c *contextmodel.ReqContext) response.Response {
+	capture, err := s.GetCapture(c.Req.Context(), web.Params(c.Req)[":id"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get capture", err)
+	}
+	return response.JSON(http.StatusOK, capture)
+}
+
+// swagger:route GET /admin/profiling/captures/{id}/download admin downloadProfilingCapture
+//
+// Download a capture.
+//
+// Returns the profile in the pprof format, read with go tool pprof, or the runtime trace, read with go tool trace.
+//
+// Produces:
+// - application/octet-stream
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: downloadProfilingCaptureResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+func (s *Service) DownloadCapture(c *contextmodel.ReqContext) response.Response {
+	content, capture, err := s.Open(c.Req.Context(), web.Params(c.Req)[":id"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to open capture", err)
+	}
+	defer func() { _ = content.Close() }()
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to read capture", err)
+	}
+	extension := "pprof"
+	if capture.Type == profiling.TypeTrace {
+		extension = "trace"
+	}
+	return response.Respond(http.StatusOK, data).
+		SetHeader("Content-Type", "application/octet-stream").
+		SetHeader("Content-Disposition", fmt.Sprintf(`attachment;filename="%s-%s.%s"`, capture.Type, capture.ID, extension))
+}
+
+// swagger:route DELETE /admin/profiling/captures/{id} admin deleteProfilingCapture
+//
+// Delete a capture.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+func (s *Service) DeleteCaptureByID(c *contextmodel.ReqContext) response.Response {
+	if err := s.DeleteCapture(c.Req.Context(), web.Params(c.Req)[":id"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete capture", err)
+	}
+	return response.Success("Capture deleted")
+}
+
+// swagger:parameters createProfilingCapture
+type CreateProfilingCaptureParams struct {
+	// in:body
+	// required:true
+	Body profiling.CaptureCommand `json:"body"`
+}
+
+// swagger:parameters getProfilingCapture downloadProfilingCapture deleteProfilingCapture
+type ProfilingCaptureIDParam struct {
+	// in:path
+	// required:true
+	ID string `json:"id"`
+}
+
+// swagger:response getProfilingCapturesResponse
+type GetProfilingCapturesResponse struct {
+	// in:body
+	Body []*profiling.Capture `json:"body"`
+}
+
+// swagger:response profilingCaptureResponse
+type ProfilingCaptureResponse struct {
+	// in:body
+	Body *profiling.Capture `json:"body"`
+}
+
+// swagger:response downloadProfilingCaptureResponse
+type DownloadProfilingCaptureResponse struct {
+	// in:body
+	Body []byte `json:"body"`
+}
@@ -0,0 +1,150 @@
+package profilingimpl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/pyroscope-go/godeltaprof"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// pyroscopePusher profiles the CPU continuously and pushes the CPU and heap profiles of each interval to the ingest
+// API of Pyroscope.
+type pyroscopePusher struct {
+	cfg    setting.PyroscopeSettings
+	name   string
+	client *http.Client
+	heap   *godeltaprof.HeapProfiler
+	// cpu is held while the CPU profiler runs, the intervals during which an on-demand CPU capture runs are skipped
+	cpu *sync.Mutex
+	log log.Logger
+}
+
+func newPyroscopePusher(cfg setting.PyroscopeSettings, version string, cpu *sync.Mutex, logger log.Logger) *pyroscopePusher {
+	tags := map[string]string{"version": version}
+	for key, value := range cfg.Tags {
+		tags[key] = value
+	}
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	labels := make([]string, 0, len(keys))
+	for _, key := range keys {
+		labels = append(labels, key+"="+tags[key])
+	}
+
+	return &pyroscopePusher{
+		cfg:    cfg,
+		name:   cfg.ApplicationName + "{" + strings.Join(labels, ",") + "}",
+		client: &http.Client{Timeout: 10 * time.Second},
+		heap:   godeltaprof.NewHeapProfiler(),
+		cpu:    cpu,
+		log:    logger.New("pusher", "pyroscope"),
+	}
+}
+
+func (p *pyroscopePusher) run(ctx context.Context) {
+	p.log.Info("Pushing profiles to Pyroscope", "url", p.cfg.ServerURL, "interval", p.cfg.PushInterval)
+	for {
+		from := time.Now()
+		cpu := &bytes.Buffer{}
+		profiling := p.cpu.TryLock()
+		if profiling {
+			if err := pprof.StartCPUProfile(cpu); err != nil {
+				p.log.Warn("Failed to start the CPU profiler", "error", err)
+				p.cpu.Unlock()
+				profiling = false
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if profiling {
+				pprof.StopCPUProfile()
+				p.cpu.Unlock()
+			}
+			return
+		case <-time.After(p.cfg.PushInterval):
+		}
+
+		if profiling {
+			pprof.StopCPUProfile()
+			p.cpu.Unlock()
+		}
+		until := time.Now()
+
+		if profiling {
+			p.push(ctx, "cpu", cpu, from, until)
+		}
+		heap := &bytes.Buffer{}
+		if err := p.heap.Profile(heap); err != nil {
+			p.log.Warn("Failed to profile the heap", "error", err)
+			continue
+		}
+		p.push(ctx, "heap", heap, from, until)
+	}
+}
+
+func (p *pyroscopePusher) push(ctx context.Context, profile string, content io.Reader, from, until time.Time) {
+	if err := p.upload(ctx, content, from, until); err != nil {
+		p.log.Warn("Failed to push profile", "profile", profile, "error", err)
+	}
+}
+
+func (p *pyroscopePusher) upload(ctx context.Context, content io.Reader, from, until time.Time) error {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("profile", "profile.pprof")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, content); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	query := url.Values{
+		"name":    {p.name},
+		"from":    {strconv.FormatInt(from.Unix(), 10)},
+		"until":   {strconv.FormatInt(until.Unix(), 10)},
+		"spyName": {"gospy"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.ServerURL+"/ingest?"+query.Encode(), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if p.cfg.BasicAuthUser != "" {
+		req.SetBasicAuth(p.cfg.BasicAuthUser, p.cfg.BasicAuthPassword)
+	}
+	if p.cfg.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", p.cfg.TenantID)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package profilingimpl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"runtime/trace"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/profiling"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var _ profiling.Service = (*Service)(nil)
+
+const (
+	defaultCPUDuration   = 30 * time.Second
+	defaultTraceDuration = 5 * time.Second
+	// cleanupInterval is the interval between two deletions of the expired captures
+	cleanupInterval = time.Minute
+)
+
+func ProvideService(cfg *setting.Cfg, router routing.RouteRegister) (*Service, error) {
+	s := &Service{
+		cfg:      cfg.Profiling,
+		captures: map[string]*profiling.Capture{},
+		running:  map[profiling.Type]bool{},
+		log:      log.New("profiling"),
+		now:      time.Now,
+	}
+
+	if cfg.Profiling.Enabled {
+		// the captures of the previous runs aren't listed anymore
+		if err := os.RemoveAll(cfg.Profiling.StoragePath); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(cfg.Profiling.StoragePath, 0o750); err != nil {
+			return nil, err
+		}
+		s.registerRoutes(router)
+	}
+	if cfg.Profiling.Pyroscope.ServerURL != "" {
+		s.pusher = newPyroscopePusher(cfg.Profiling.Pyroscope, cfg.BuildVersion, &s.cpu, s.log)
+	}
+
+	return s, nil
+}
+
+// Service keeps the list of the captures in memory and their content in files of the storage path, they are deleted
+// once they expire. At most one capture of each type runs at a time since the CPU profiler and the execution tracer
+// can't be started twice.
+type Service struct {
+	cfg setting.ProfilingSettings
+	// cpu is held while the CPU profiler runs, it's shared with the continuous profiling
+	cpu    sync.Mutex
+	pusher *pyroscopePusher
+	log    log.Logger
+	now    func() time.Time
+
+	mu       sync.Mutex
+	captures map[string]*profiling.Capture
+	running  map[profiling.Type]bool
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Enabled && s.pusher == nil
+}
+
+// Run deletes the expired captures and pushes the profiles to Pyroscope when it's configured.
+func (s *Service) Run(ctx context.Context) error {
+	if s.pusher != nil {
+		go s.pusher.run(ctx)
+	}
+
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			s.deleteExpired()
+		}
+	}
+}
+
+func (s *Service) Capture(_ context.Context, cmd *profiling.CaptureCommand) (*profiling.Capture, error) {
+	if !cmd.Type.Valid() {
+		return nil, profiling.ErrInvalidCapture.Errorf("unknown type %q", cmd.Type)
+	}
+	duration := time.Duration(cmd.DurationSeconds) * time.Second
+	if cmd.Type.Timed() {
+		if duration == 0 {
+			duration = defaultCPUDuration
+			if cmd.Type == profiling.TypeTrace {
+				duration = defaultTraceDuration
+			}
+		}
+		if duration < 0 || duration > s.cfg.MaxDuration {
+			return nil, profiling.ErrInvalidCapture.Errorf("duration must be between 1 and %d seconds", int(s.cfg.MaxDuration.Seconds()))
+		}
+	} else {
+		duration = 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running[cmd.Type] {
+		return nil, profiling.ErrProfilerBusy.Errorf("a %s capture is running", cmd.Type)
+	}
+	if len(s.captures) >= s.cfg.MaxCaptures {
+		return nil, profiling.ErrTooManyCaptures.Errorf("at most %d captures are kept", s.cfg.MaxCaptures)
+	}
+
+	capture := &profiling.Capture{
+		ID:              util.GenerateShortUID(),
+		Type:            cmd.Type,
+		Status:          profiling.StatusRunning,
+		DurationSeconds: int(duration.Seconds()),
+		Created:         s.now(),
+		CreatedBy:       cmd.CreatedBy,
+	}
+	s.captures[capture.ID] = capture
+	s.running[cmd.Type] = true
+	go s.capture(*capture, duration)
+
+	result := *capture
+	return &result, nil
+}
+
+// capture writes the profile to the file of the capture and updates its status once it's done
+func (s *Service) capture(capture profiling.Capture, duration time.Duration) {
+	path := s.path(capture.ID)
+	size, err := s.writeProfile(path, capture.Type, duration)
+	if err != nil {
+		s.log.Warn("Failed to capture profile", "type", capture.Type, "error", err)
+		_ = os.Remove(path)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, capture.Type)
+	current, ok := s.captures[capture.ID]
+	if !ok {
+		// deleted while running
+		_ = os.Remove(path)
+		return
+	}
+	expires := s.now().Add(s.cfg.Retention)
+	current.Expires = &expires
+	current.Size = size
+	current.Status = profiling.StatusDone
+	if err != nil {
+		current.Status = profiling.StatusFailed
+		current.Error = err.Error()
+	}
+}
+
+func (s *Service) writeProfile(path string, typ profiling.Type, duration time.Duration) (int64, error) {
+	// nolint:gosec
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = f.Close() }()
+
+	switch typ {
+	case profiling.TypeCPU:
+		s.cpu.Lock()
+		defer s.cpu.Unlock()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return 0, err
+		}
+		time.Sleep(duration)
+		pprof.StopCPUProfile()
+	case profiling.TypeTrace:
+		if err := trace.Start(f); err != nil {
+			return 0, err
+		}
+		time.Sleep(duration)
+		trace.Stop()
+	default:
+		profile := pprof.Lookup(string(typ))
+		if profile == nil {
+			return 0, fmt.Errorf("unknown profile %s", typ)
+		}
+		if err := profile.WriteTo(f, 0); err != nil {
+			return 0, err
+		}
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), f.Close()
+}
+
+func (s *Service) Captures(_ context.Context) []*profiling.Capture {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	captures := make([]*profiling.Capture, 0, len(s.captures))
+	for _, capture := range s.captures {
+		result := *capture
+		captures = append(captures, &result)
+	}
+	sort.Slice(captures, func(i, j int) bool {
+		return captures[i].Created.After(captures[j].Created)
+	})
+	return captures
+}
+
+func (s *Service) GetCapture(_ context.Context, id string) (*profiling.Capture, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	capture, ok := s.captures[id]
+	if !ok {
+		return nil, profiling.ErrCaptureNotFound.Errorf("capture %s not found", id)
+	}
+	result := *capture
+	return &result, nil
+}
+
+func (s *Service) Open(ctx context.Context, id string) (io.ReadCloser, *profiling.Capture, error) {
+	capture, err := s.GetCapture(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if capture.Status != profiling.StatusDone {
+		return nil, nil, profiling.ErrCaptureNotDone.Errorf("capture %s is %s", id, capture.Status)
+	}
+
+	f, err := os.Open(s.path(id))
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, capture, nil
+}
+
+func (s *Service) DeleteCapture(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	capture, ok := s.captures[id]
+	if !ok {
+		return profiling.ErrCaptureNotFound.Errorf("capture %s not found", id)
+	}
+	delete(s.captures, id)
+	// the file of a running capture is removed once it's done
+	if capture.Status != profiling.StatusRunning {
+		return s.remove(id)
+	}
+	return nil
+}
+
+func (s *Service) deleteExpired() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for id, capture := range s.captures {
+		if capture.Expires == nil || capture.Expires.After(now) {
+			continue
+		}
+		delete(s.captures, id)
+		if err := s.remove(id); err != nil {
+			s.log.Warn("Failed to delete expired capture", "id", id, "error", err)
+		}
+	}
+}
+
+func (s *Service) remove(id string) error {
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *Service) path(id string) string {
+	return filepath.Join(s.cfg.StoragePath, id)
+}
@@ -0,0 +1,125 @@
+package profilingimpl
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/services/profiling"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService_Capture(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should capture a snapshot", func(t *testing.T) {
+		s := setupTestService(t)
+
+		capture, err := s.Capture(ctx, &profiling.CaptureCommand{Type: profiling.TypeHeap, DurationSeconds: 10, CreatedBy: "admin"})
+		require.NoError(t, err)
+		assert.Equal(t, 0, capture.DurationSeconds, "snapshots have no duration")
+		capture = waitForCapture(t, s, capture.ID)
+		assert.Equal(t, profiling.StatusDone, capture.Status)
+		assert.Equal(t, "admin", capture.CreatedBy)
+		require.NotNil(t, capture.Expires)
+
+		content, opened, err := s.Open(ctx, capture.ID)
+		require.NoError(t, err)
+		defer func() { _ = content.Close() }()
+		data, err := io.ReadAll(content)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(data)), opened.Size)
+		assert.NotEmpty(t, data)
+	})
+
+	t.Run("should capture a CPU profile for its duration", func(t *testing.T) {
+		s := setupTestService(t)
+
+		capture, err := s.Capture(ctx, &profiling.CaptureCommand{Type: profiling.TypeCPU, DurationSeconds: 1})
+		require.NoError(t, err)
+		assert.Equal(t, profiling.StatusRunning, capture.Status)
+
+		_, err = s.Capture(ctx, &profiling.CaptureCommand{Type: profiling.TypeCPU, DurationSeconds: 1})
+		assert.ErrorIs(t, err, profiling.ErrProfilerBusy)
+		_, _, err = s.Open(ctx, capture.ID)
+		assert.ErrorIs(t, err, profiling.ErrCaptureNotDone)
+
+		assert.Equal(t, profiling.StatusDone, waitForCapture(t, s, capture.ID).Status)
+	})
+
+	t.Run("should validate the capture", func(t *testing.T) {
+		s := setupTestService(t)
+
+		_, err := s.Capture(ctx, &profiling.CaptureCommand{Type: "threadcreate"})
+		assert.ErrorIs(t, err, profiling.ErrInvalidCapture)
+		_, err = s.Capture(ctx, &profiling.CaptureCommand{Type: profiling.TypeTrace, DurationSeconds: 3600})
+		assert.ErrorIs(t, err, profiling.ErrInvalidCapture)
+	})
+
+	t.Run("should limit the number of captures", func(t *testing.T) {
+		s := setupTestService(t)
+		s.cfg.MaxCaptures = 1
+
+		capture, err := s.Capture(ctx, &profiling.CaptureCommand{Type: profiling.TypeGoroutine})
+		require.NoError(t, err)
+		_, err = s.Capture(ctx, &profiling.CaptureCommand{Type: profiling.TypeHeap})
+		assert.ErrorIs(t, err, profiling.ErrTooManyCaptures)
+
+		waitForCapture(t, s, capture.ID)
+		require.NoError(t, s.DeleteCapture(ctx, capture.ID))
+		assert.Empty(t, s.Captures(ctx))
+		_, err = os.Stat(s.path(capture.ID))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestService_deleteExpired(t *testing.T) {
+	ctx := context.Background()
+	s := setupTestService(t)
+
+	capture, err := s.Capture(ctx, &profiling.CaptureCommand{Type: profiling.TypeGoroutine})
+	require.NoError(t, err)
+	waitForCapture(t, s, capture.ID)
+
+	s.deleteExpired()
+	assert.Len(t, s.Captures(ctx), 1)
+
+	s.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	s.deleteExpired()
+	_, err = s.GetCapture(ctx, capture.ID)
+	assert.ErrorIs(t, err, profiling.ErrCaptureNotFound)
+	_, err = os.Stat(s.path(capture.ID))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func setupTestService(t *testing.T) *Service {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.Profiling = setting.ProfilingSettings{
+		Enabled:     true,
+		StoragePath: t.TempDir(),
+		MaxDuration: time.Minute,
+		Retention:   time.Hour,
+		MaxCaptures: 10,
+	}
+	s, err := ProvideService(cfg, routing.NewRouteRegister())
+	require.NoError(t, err)
+	return s
+}
+
+func waitForCapture(t *testing.T, s *Service, id string) *profiling.Capture {
+	t.Helper()
+	var capture *profiling.Capture
+	require.Eventually(t, func() bool {
+		var err error
+		capture, err = s.GetCapture(context.Background(), id)
+		return err == nil && capture.Status != profiling.StatusRunning
+	}, 10*time.Second, 10*time.Millisecond)
+	return capture
+}
@@ -11,6 +11,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 )
 
 type rulesConfigReader struct {
@@ -41,13 +42,13 @@ func (cr *rulesConfigReader) readConfig(ctx context.Context, path string) ([]*Al
 		}
 		alertFileV1, err := cr.parseConfig(path, file)
 		if err != nil {
-			return nil, fmt.Errorf("failure to parse file %s: %w", file.Name(), err)
+			return nil, fmt.Errorf("failure to parse file %s: %w", file.Name(), utils.NewFileError(file.Name(), err))
 		}
 		if alertFileV1 != nil {
 			alertFileV1.Filename = file.Name()
 			alertFile, err := alertFileV1.MapToModel()
 			if err != nil {
-				return nil, fmt.Errorf("failure to map file %s: %w", alertFileV1.Filename, err)
+				return nil, fmt.Errorf("failure to map file %s: %w", alertFileV1.Filename, utils.NewFileError(file.Name(), err))
 			}
 			alertFiles = append(alertFiles, &alertFile)
 		}
@@ -0,0 +1,331 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	alert_models "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/provisioning/plan"
+)
+
+// Plan reports the changes the provisioning of the alerting files would make, without applying them.
+// The folders of the rules are not compared, and the secure settings of the contact points are redacted,
+// so changes of their values are not reported.
+func Plan(ctx context.Context, cfg ProvisionerConfig) (*plan.Plan, error) {
+	logger := log.New("provisioning.alerting")
+	files, err := newRulesConfigReader(logger).readConfig(ctx, cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	planner := &alertingPlanner{cfg: cfg, plan: plan.New("alerting")}
+	steps := []struct {
+		name string
+		fn   func(context.Context, []*AlertingFile) error
+	}{
+		{"contact points", planner.planContactPoints},
+		{"mute times", planner.planMuteTimes},
+		{"text templates", planner.planTemplates},
+		{"notification policies", planner.planPolicies},
+		{"alert rules", planner.planRules},
+	}
+	for _, step := range steps {
+		if err := step.fn(ctx, files); err != nil {
+			return nil, fmt.Errorf("%s: %w", step.name, err)
+		}
+	}
+
+	planner.plan.Sort()
+	return planner.plan, nil
+}
+
+type alertingPlanner struct {
+	cfg  ProvisionerConfig
+	plan *plan.Plan
+}
+
+func (p *alertingPlanner) add(action plan.Action, kind string, orgID int64, name, uid string, current, desired any) error {
+	change := plan.Change{Action: action, Kind: kind, OrgID: orgID, Name: name, UID: uid}
+	if action == plan.ActionUpdate {
+		diff, err := plan.Diff(current, desired)
+		if err != nil {
+			return err
+		}
+		if diff == "" {
+			return nil
+		}
+		change.Diff = diff
+	}
+	p.plan.Add(change)
+	return nil
+}
+
+func (p *alertingPlanner) planContactPoints(ctx context.Context, files []*AlertingFile) error {
+	cache := map[int64]map[string]definitions.EmbeddedContactPoint{}
+	get := func(orgID int64) (map[string]definitions.EmbeddedContactPoint, error) {
+		if cps, ok := cache[orgID]; ok {
+			return cps, nil
+		}
+		cps, err := p.cfg.ContactPointService.GetContactPoints(ctx, provisioning.ContactPointQuery{OrgID: orgID}, provisionerUser(orgID))
+		if err != nil {
+			return nil, err
+		}
+		cache[orgID] = make(map[string]definitions.EmbeddedContactPoint, len(cps))
+		for _, cp := range cps {
+			cache[orgID][cp.UID] = cp
+		}
+		return cache[orgID], nil
+	}
+
+	for _, file := range files {
+		for _, config := range file.ContactPoints {
+			existing, err := get(config.OrgID)
+			if err != nil {
+				return err
+			}
+			for _, cp := range config.ContactPoints {
+				current, ok := existing[cp.UID]
+				if !ok {
+					if err := p.add(plan.ActionCreate, "contactPoint", config.OrgID, cp.Name, cp.UID, nil, nil); err != nil {
+						return err
+					}
+					continue
+				}
+				if err := p.add(plan.ActionUpdate, "contactPoint", config.OrgID, cp.Name, cp.UID, current, redactContactPoint(cp, current)); err != nil {
+					return err
+				}
+			}
+		}
+		for _, cp := range file.DeleteContactPoints {
+			existing, err := get(cp.OrgID)
+			if err != nil {
+				return err
+			}
+			if current, ok := existing[cp.UID]; ok {
+				if err := p.add(plan.ActionDelete, "contactPoint", cp.OrgID, current.Name, cp.UID, nil, nil); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// redactContactPoint returns the provisioned contact point with the settings redacted in the current
+// contact point redacted as well, so the secure settings are not reported as changed or leaked in diffs.
+func redactContactPoint(cp, current definitions.EmbeddedContactPoint) definitions.EmbeddedContactPoint {
+	if cp.Settings == nil || current.Settings == nil {
+		return cp
+	}
+	settings, err := cp.Settings.Map()
+	if err != nil {
+		return cp
+	}
+	redacted := cp
+	redacted.Settings = cp.Settings.DeepCopy()
+	for k := range settings {
+		if current.Settings.Get(k).MustString() == definitions.RedactedValue {
+			redacted.Settings.Set(k, definitions.RedactedValue)
+		}
+	}
+	return redacted
+}
+
+func (p *alertingPlanner) planMuteTimes(ctx context.Context, files []*AlertingFile) error {
+	cache := map[int64]map[string]definitions.MuteTimeInterval{}
+	get := func(orgID int64) (map[string]definitions.MuteTimeInterval, error) {
+		if intervals, ok := cache[orgID]; ok {
+			return intervals, nil
+		}
+		intervals, err := p.cfg.MuteTimingService.GetMuteTimings(ctx, orgID)
+		if err != nil {
+			return nil, err
+		}
+		cache[orgID] = make(map[string]definitions.MuteTimeInterval, len(intervals))
+		for _, interval := range intervals {
+			cache[orgID][interval.Name] = interval
+		}
+		return cache[orgID], nil
+	}
+
+	for _, file := range files {
+		for _, muteTiming := range file.MuteTimes {
+			existing, err := get(muteTiming.OrgID)
+			if err != nil {
+				return err
+			}
+			action := plan.ActionCreate
+			current, ok := existing[muteTiming.MuteTime.Name]
+			if ok {
+				action = plan.ActionUpdate
+			}
+			if err := p.add(action, "muteTiming", muteTiming.OrgID, muteTiming.MuteTime.Name, current.UID, current.MuteTimeInterval, muteTiming.MuteTime.MuteTimeInterval); err != nil {
+				return err
+			}
+		}
+		for _, deleteMuteTime := range file.DeleteMuteTimes {
+			existing, err := get(deleteMuteTime.OrgID)
+			if err != nil {
+				return err
+			}
+			if current, ok := existing[deleteMuteTime.Name]; ok {
+				if err := p.add(plan.ActionDelete, "muteTiming", deleteMuteTime.OrgID, deleteMuteTime.Name, current.UID, nil, nil); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (p *alertingPlanner) planTemplates(ctx context.Context, files []*AlertingFile) error {
+	cache := map[int64]map[string]definitions.NotificationTemplate{}
+	get := func(orgID int64) (map[string]definitions.NotificationTemplate, error) {
+		if templates, ok := cache[orgID]; ok {
+			return templates, nil
+		}
+		templates, err := p.cfg.TemplateService.GetTemplates(ctx, orgID)
+		if err != nil {
+			return nil, err
+		}
+		cache[orgID] = make(map[string]definitions.NotificationTemplate, len(templates))
+		for _, template := range templates {
+			cache[orgID][template.Name] = template
+		}
+		return cache[orgID], nil
+	}
+
+	type planTemplate struct {
+		Template string `json:"template"`
+	}
+	for _, file := range files {
+		for _, template := range file.Templates {
+			existing, err := get(template.OrgID)
+			if err != nil {
+				return err
+			}
+			action := plan.ActionCreate
+			current, ok := existing[template.Data.Name]
+			if ok {
+				action = plan.ActionUpdate
+			}
+			if err := p.add(action, "template", template.OrgID, template.Data.Name, current.UID,
+				planTemplate{Template: current.Template}, planTemplate{Template: template.Data.Template}); err != nil {
+				return err
+			}
+		}
+		for _, deleteTemplate := range file.DeleteTemplates {
+			existing, err := get(deleteTemplate.OrgID)
+			if err != nil {
+				return err
+			}
+			if current, ok := existing[deleteTemplate.Name]; ok {
+				if err := p.add(plan.ActionDelete, "template", deleteTemplate.OrgID, deleteTemplate.Name, current.UID, nil, nil); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (p *alertingPlanner) planPolicies(ctx context.Context, files []*AlertingFile) error {
+	for _, file := range files {
+		for _, np := range file.Policies {
+			current, _, err := p.cfg.NotificiationPolicyService.GetPolicyTree(ctx, np.OrgID)
+			if err != nil {
+				return fmt.Errorf("%s: %w", file.Filename, err)
+			}
+			if err := p.add(plan.ActionUpdate, "notificationPolicy", np.OrgID, "", "", current, np.Policy); err != nil {
+				return err
+			}
+		}
+		for _, orgID := range file.ResetPolicies {
+			if err := p.add(plan.ActionDelete, "notificationPolicy", int64(orgID), "", "", nil, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// planRule is the subset of an alert rule set by the provisioning, compared to report the updates.
+type planRule struct {
+	Title                string                              `json:"title"`
+	Condition            string                              `json:"condition"`
+	Data                 []alert_models.AlertQuery           `json:"data"`
+	RuleGroup            string                              `json:"ruleGroup"`
+	IntervalSeconds      int64                               `json:"intervalSeconds"`
+	NoDataState          alert_models.NoDataState            `json:"noDataState"`
+	ExecErrState         alert_models.ExecutionErrorState    `json:"execErrState"`
+	For                  time.Duration                       `json:"for"`
+	KeepFiringFor        time.Duration                       `json:"keepFiringFor"`
+	Annotations          map[string]string                   `json:"annotations"`
+	Labels               map[string]string                   `json:"labels"`
+	IsPaused             bool                                `json:"isPaused"`
+	Record               *alert_models.Record                `json:"record,omitempty"`
+	NotificationSettings []alert_models.NotificationSettings `json:"notificationSettings,omitempty"`
+}
+
+func newPlanRule(rule alert_models.AlertRule) planRule {
+	return planRule{
+		Title:                rule.Title,
+		Condition:            rule.Condition,
+		Data:                 rule.Data,
+		RuleGroup:            rule.RuleGroup,
+		IntervalSeconds:      rule.IntervalSeconds,
+		NoDataState:          rule.NoDataState,
+		ExecErrState:         rule.ExecErrState,
+		For:                  rule.For,
+		KeepFiringFor:        rule.KeepFiringFor,
+		Annotations:          rule.Annotations,
+		Labels:               rule.Labels,
+		IsPaused:             rule.IsPaused,
+		Record:               rule.Record,
+		NotificationSettings: rule.NotificationSettings,
+	}
+}
+
+func (p *alertingPlanner) planRules(ctx context.Context, files []*AlertingFile) error {
+	for _, file := range files {
+		for _, group := range file.Groups {
+			ctx, u := identity.WithServiceIdentity(ctx, group.OrgID)
+			for _, rule := range group.Rules {
+				rule.RuleGroup = group.Title
+				rule.IntervalSeconds = group.Interval
+				current, _, err := p.cfg.RuleService.GetAlertRule(ctx, u, rule.UID)
+				if err != nil {
+					if !errors.Is(err, alert_models.ErrAlertRuleNotFound) {
+						return err
+					}
+					if err := p.add(plan.ActionCreate, "alertRule", group.OrgID, rule.Title, rule.UID, nil, nil); err != nil {
+						return err
+					}
+					continue
+				}
+				if err := p.add(plan.ActionUpdate, "alertRule", group.OrgID, rule.Title, rule.UID, newPlanRule(current), newPlanRule(rule)); err != nil {
+					return err
+				}
+			}
+		}
+		for _, deleteRule := range file.DeleteRules {
+			current, _, err := p.cfg.RuleService.GetAlertRule(ctx, provisionerUser(deleteRule.OrgID), deleteRule.UID)
+			if err != nil {
+				if errors.Is(err, alert_models.ErrAlertRuleNotFound) {
+					continue
+				}
+				return err
+			}
+			if err := p.add(plan.ActionDelete, "alertRule", deleteRule.OrgID, current.Title, deleteRule.UID, nil, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -39,7 +39,7 @@ func ReadDashboardConfig(dir string) ([]*DashboardProvisioning, error) {
 
 		parsedDashboards, err := cr.parseConfigs(file)
 		if err != nil {
-			return nil, fmt.Errorf("could not parse provisioning config file: %s error: %v", file.Name(), err)
+			return nil, fmt.Errorf("could not parse provisioning config file: %s error: %w", file.Name(), utils.NewFileError(file.Name(), err))
 		}
 
 		for _, config := range parsedDashboards {
@@ -113,7 +113,7 @@ func (cr *configReader) readConfig(ctx context.Context) ([]*config, error) {
 
 		parsedDashboards, err := cr.parseConfigs(file)
 		if err != nil {
-			return nil, fmt.Errorf("could not parse provisioning config file: %s error: %v", file.Name(), err)
+			return nil, fmt.Errorf("could not parse provisioning config file: %s error: %w", file.Name(), utils.NewFileError(file.Name(), err))
 		}
 
 		if len(parsedDashboards) > 0 {
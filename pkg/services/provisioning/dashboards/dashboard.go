@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/provisioning/plan"
 	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 	"github.com/grafana/grafana/pkg/storage/legacysql/dualwrite"
 )
@@ -24,6 +25,7 @@ type DashboardProvisioner interface {
 	GetProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
 	CleanUpOrphanedDashboards(ctx context.Context)
+	Plan(ctx context.Context) (*plan.Plan, error)
 }
 
 // DashboardProvisionerFactory creates DashboardProvisioners based on input
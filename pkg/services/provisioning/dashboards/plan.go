@@ -0,0 +1,140 @@
+package dashboards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/provisioning/plan"
+)
+
+const planKind = "dashboard"
+
+// Plan scans the disk for dashboards and reports the changes the provisioning
+// would make to the database, without applying them.
+func (provider *Provisioner) Plan(ctx context.Context) (*plan.Plan, error) {
+	p := plan.New("dashboards")
+	for _, reader := range provider.fileReaders {
+		if err := reader.planChanges(ctx, p); err != nil {
+			if os.IsNotExist(err) {
+				// the folder can appear after the startup, like when provisioning
+				provider.log.Warn("Failed to plan config", "name", reader.Cfg.Name, "error", err)
+				continue
+			}
+			return nil, fmt.Errorf("failed to plan config %v: %w", reader.Cfg.Name, err)
+		}
+	}
+	p.Sort()
+	return p, nil
+}
+
+// planChanges adds to the plan the changes walkDisk would make. The folders of the dashboards are not
+// created, the dashboards are only compared on the checksums of their files and their contents.
+func (fr *FileReader) planChanges(ctx context.Context, p *plan.Plan) error {
+	resolvedPath := fr.resolvedPath()
+	if _, err := os.Stat(resolvedPath); err != nil {
+		return err
+	}
+
+	provisionedDashboardRefs, err := fr.getProvisionedDashboardsByPath(ctx, fr.dashboardProvisioningService, fr.Cfg.Name)
+	if err != nil {
+		return err
+	}
+
+	filesFoundOnDisk := map[string]os.FileInfo{}
+	if err := filepath.Walk(resolvedPath, createWalkFn(filesFoundOnDisk)); err != nil {
+		return err
+	}
+
+	ctx, _ = identity.WithServiceIdentity(ctx, fr.Cfg.OrgID)
+
+	missing := make([]string, 0)
+	for path := range provisionedDashboardRefs {
+		if _, existsOnDisk := filesFoundOnDisk[path]; !existsOnDisk {
+			missing = append(missing, path)
+		}
+	}
+	sort.Strings(missing)
+	for _, path := range missing {
+		change := plan.Change{Action: plan.ActionDelete, Kind: planKind, OrgID: fr.Cfg.OrgID, Name: path}
+		if fr.Cfg.DisableDeletion {
+			change.Action = plan.ActionUnprovision
+		}
+		existing, err := fr.getDashboard(ctx, provisionedDashboardRefs[path].DashboardID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			change.Name = existing.Title
+			change.UID = existing.UID
+		}
+		p.Add(change)
+	}
+
+	for path, fileInfo := range filesFoundOnDisk {
+		change, err := fr.planDashboard(ctx, path, fileInfo, provisionedDashboardRefs[path])
+		if err != nil {
+			return fmt.Errorf("failed to plan dashboard %s: %w", path, err)
+		}
+		if change != nil {
+			p.Add(*change)
+		}
+	}
+	return nil
+}
+
+// planDashboard returns the change provisioning the dashboard file at path, or nil when the dashboard is up to date.
+func (fr *FileReader) planDashboard(ctx context.Context, path string, fileInfo os.FileInfo, provisionedData *dashboards.DashboardProvisioning) (*plan.Change, error) {
+	resolvedFileInfo, err := resolveSymlink(fileInfo, path)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonFile, err := fr.readDashboardFromFile(path, resolvedFileInfo.ModTime(), 0, "")
+	if err != nil {
+		return nil, err
+	}
+	dash := jsonFile.dashboard.Dashboard
+	change := &plan.Change{Action: plan.ActionCreate, Kind: planKind, OrgID: fr.Cfg.OrgID, Name: dash.Title, UID: dash.UID}
+	if provisionedData == nil {
+		return change, nil
+	}
+	if jsonFile.checkSum == provisionedData.CheckSum {
+		return nil, nil
+	}
+
+	change.Action = plan.ActionUpdate
+	existing, err := fr.getDashboard(ctx, provisionedData.DashboardID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil || existing.Data == nil {
+		return change, nil
+	}
+
+	provisioned := dash.Data.MustMap()
+	delete(provisioned, "id")
+	delete(provisioned, "version")
+	change.Diff, err = plan.Diff(existing.Data.MustMap(), provisioned)
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// getDashboard returns the provisioned dashboard with the id, or nil when it doesn't exist anymore.
+func (fr *FileReader) getDashboard(ctx context.Context, id int64) (*dashboards.Dashboard, error) {
+	dash, err := fr.dashboardStore.GetDashboard(ctx, &dashboards.GetDashboardQuery{ID: id, OrgID: fr.Cfg.OrgID})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return dash, nil
+}
@@ -34,7 +34,7 @@ func (cr *configReader) readConfig(ctx context.Context, path string) ([]*configs
 		if strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml") {
 			datasource, err := cr.parseDatasourceConfig(path, file)
 			if err != nil {
-				return nil, err
+				return nil, utils.NewFileError(file.Name(), err)
 			}
 
 			if datasource != nil {
@@ -0,0 +1,180 @@
+package datasources
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/provisioning/plan"
+)
+
+const planKind = "datasource"
+
+// Plan scans a directory for provisioning config files and reports the changes
+// the provisioning of the datasources in those files would make, without applying them.
+func Plan(ctx context.Context, configDirectory string, dsService BaseDataSourceService, orgService org.Service) (*plan.Plan, error) {
+	dc := newDatasourceProvisioner(log.New("provisioning.datasources"), dsService, nil, orgService)
+	return dc.planChanges(ctx, configDirectory)
+}
+
+// planDataSource is the subset of a datasource set by the provisioning, compared to report the updates.
+// The secure JSON data is encrypted, only the names of its keys are compared.
+type planDataSource struct {
+	UID             string         `json:"uid"`
+	Type            string         `json:"type"`
+	Access          string         `json:"access"`
+	URL             string         `json:"url"`
+	User            string         `json:"user"`
+	Database        string         `json:"database"`
+	BasicAuth       bool           `json:"basicAuth"`
+	BasicAuthUser   string         `json:"basicAuthUser"`
+	WithCredentials bool           `json:"withCredentials"`
+	IsDefault       bool           `json:"isDefault"`
+	JSONData        map[string]any `json:"jsonData"`
+	SecureJSONData  []string       `json:"secureJsonFields"`
+	Editable        bool           `json:"editable"`
+}
+
+func (dc *DatasourceProvisioner) planChanges(ctx context.Context, configPath string) (*plan.Plan, error) {
+	p := plan.New("datasources")
+	configs, err := dc.cfgProvider.readConfig(ctx, configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := map[DataSourceMapKey]bool{}
+	willExistAfterProvisioning := map[DataSourceMapKey]bool{}
+	for _, cfg := range configs {
+		for _, ds := range cfg.DeleteDatasources {
+			willExistAfterProvisioning[DataSourceMapKey{Name: ds.Name, OrgId: ds.OrgID}] = false
+		}
+		for _, ds := range cfg.Datasources {
+			willExistAfterProvisioning[DataSourceMapKey{Name: ds.Name, OrgId: ds.OrgID}] = true
+		}
+	}
+
+	prunableProvisionedDataSources, err := dc.dsService.GetPrunableProvisionedDataSources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, ds := range prunableProvisionedDataSources {
+		key := DataSourceMapKey{OrgId: ds.OrgID, Name: ds.Name}
+		if _, ok := willExistAfterProvisioning[key]; !ok {
+			p.Add(plan.Change{Action: plan.ActionDelete, Kind: planKind, OrgID: ds.OrgID, Name: ds.Name, UID: ds.UID})
+			deleted[key] = true
+		}
+	}
+
+	for _, cfg := range configs {
+		for _, ds := range cfg.DeleteDatasources {
+			key := DataSourceMapKey{Name: ds.Name, OrgId: ds.OrgID}
+			if deleted[key] {
+				continue
+			}
+			existing, err := dc.dsService.GetDataSource(ctx, &datasources.GetDataSourceQuery{OrgID: ds.OrgID, Name: ds.Name})
+			if err != nil {
+				if errors.Is(err, datasources.ErrDataSourceNotFound) {
+					continue
+				}
+				return nil, err
+			}
+			deleted[key] = true
+			// the datasources deleted and provisioned again are reported as updates
+			if !willExistAfterProvisioning[key] {
+				p.Add(plan.Change{Action: plan.ActionDelete, Kind: planKind, OrgID: ds.OrgID, Name: ds.Name, UID: existing.UID})
+			}
+		}
+
+		for _, ds := range cfg.Datasources {
+			change, err := dc.planDataSource(ctx, ds, deleted[DataSourceMapKey{Name: ds.Name, OrgId: ds.OrgID}])
+			if err != nil {
+				return nil, err
+			}
+			if change != nil {
+				p.Add(*change)
+			}
+		}
+	}
+
+	p.Sort()
+	return p, nil
+}
+
+// planDataSource returns the change provisioning the datasource, or nil when the datasource is up to date.
+func (dc *DatasourceProvisioner) planDataSource(ctx context.Context, ds *upsertDataSourceFromConfig, recreated bool) (*plan.Change, error) {
+	existing, err := dc.dsService.GetDataSource(ctx, &datasources.GetDataSourceQuery{OrgID: ds.OrgID, Name: ds.Name})
+	if err != nil && !errors.Is(err, datasources.ErrDataSourceNotFound) {
+		return nil, err
+	}
+
+	desired := newPlanDataSource(ds)
+	if errors.Is(err, datasources.ErrDataSourceNotFound) {
+		return &plan.Change{Action: plan.ActionCreate, Kind: planKind, OrgID: ds.OrgID, Name: ds.Name, UID: desired.UID}, nil
+	}
+
+	if ds.UID == "" {
+		// the uid of existing datasources is kept when none is provisioned
+		desired.UID = existing.UID
+	}
+	diff, err := plan.Diff(existingPlanDataSource(existing), desired)
+	if err != nil {
+		return nil, err
+	}
+	if diff == "" && !recreated {
+		return nil, nil
+	}
+	return &plan.Change{Action: plan.ActionUpdate, Kind: planKind, OrgID: ds.OrgID, Name: ds.Name, UID: desired.UID, Diff: diff}, nil
+}
+
+func newPlanDataSource(ds *upsertDataSourceFromConfig) planDataSource {
+	cmd := createInsertCommand(ds)
+	return planDataSource{
+		UID:             cmd.UID,
+		Type:            cmd.Type,
+		Access:          string(cmd.Access),
+		URL:             cmd.URL,
+		User:            cmd.User,
+		Database:        cmd.Database,
+		BasicAuth:       cmd.BasicAuth,
+		BasicAuthUser:   cmd.BasicAuthUser,
+		WithCredentials: cmd.WithCredentials,
+		IsDefault:       cmd.IsDefault,
+		JSONData:        cmd.JsonData.MustMap(),
+		SecureJSONData:  sortedKeys(ds.SecureJSONData),
+		Editable:        ds.Editable,
+	}
+}
+
+func existingPlanDataSource(ds *datasources.DataSource) planDataSource {
+	jsonData := map[string]any{}
+	if ds.JsonData != nil {
+		jsonData = ds.JsonData.MustMap()
+	}
+	return planDataSource{
+		UID:             ds.UID,
+		Type:            ds.Type,
+		Access:          string(ds.Access),
+		URL:             ds.URL,
+		User:            ds.User,
+		Database:        ds.Database,
+		BasicAuth:       ds.BasicAuth,
+		BasicAuthUser:   ds.BasicAuthUser,
+		WithCredentials: ds.WithCredentials,
+		IsDefault:       ds.IsDefault,
+		JSONData:        jsonData,
+		SecureJSONData:  sortedKeys(ds.SecureJsonData),
+		Editable:        !ds.ReadOnly,
+	}
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
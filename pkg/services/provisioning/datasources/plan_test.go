@@ -0,0 +1,54 @@
+package datasources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/provisioning/plan"
+)
+
+func TestPlan(t *testing.T) {
+	store := &spyStore{
+		items: []*datasources.DataSource{
+			{Name: "Graphite", OrgID: 1, ID: 1, UID: "graphite", Type: "graphite", Access: "proxy", URL: "http://localhost:8081", ReadOnly: true},
+			{Name: "Old", OrgID: 1, ID: 2, UID: "old", Type: "graphite", IsPrunable: true},
+		},
+	}
+	orgFake := &orgtest.FakeOrgService{ExpectedOrg: &org.Org{ID: 1}}
+
+	p, err := Plan(context.Background(), twoDatasourcesConfig, store, orgFake)
+	require.NoError(t, err)
+
+	require.Equal(t, "datasources", p.Provisioner)
+	require.Len(t, p.Changes, 3)
+
+	require.Equal(t, plan.ActionUpdate, p.Changes[0].Action)
+	require.Equal(t, "Graphite", p.Changes[0].Name)
+	require.Equal(t, "graphite", p.Changes[0].UID, "the uid of the existing datasource should be kept")
+	require.Contains(t, p.Changes[0].Diff, "http://localhost:8081")
+	require.Contains(t, p.Changes[0].Diff, "http://localhost:8080")
+
+	require.Equal(t, plan.Change{Action: plan.ActionDelete, Kind: "datasource", OrgID: 1, Name: "Old", UID: "old"}, p.Changes[1])
+
+	require.Equal(t, plan.ActionCreate, p.Changes[2].Action)
+	require.Equal(t, "Prometheus", p.Changes[2].Name)
+
+	require.Empty(t, store.inserted, "planning should not insert datasources")
+	require.Empty(t, store.updated, "planning should not update datasources")
+	require.Empty(t, store.deleted, "planning should not delete datasources")
+
+	t.Run("should not report up to date datasources", func(t *testing.T) {
+		store.items[0].URL = "http://localhost:8080"
+		store.items[1].IsPrunable = false
+
+		p, err := Plan(context.Background(), twoDatasourcesConfig, store, orgFake)
+		require.NoError(t, err)
+		require.Len(t, p.Changes, 1)
+		require.Equal(t, "Prometheus", p.Changes[0].Name)
+	})
+}
@@ -0,0 +1,141 @@
+// Package plan describes the changes the provisioners would make to Grafana without applying them,
+// so the provisioning configuration can be validated before it is rolled out.
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	diff "github.com/yudai/gojsondiff"
+	"github.com/yudai/gojsondiff/formatter"
+)
+
+// Action is what the provisioning does to a resource.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+	// ActionUnprovision removes the provisioning metadata of a resource, the resource itself is kept
+	ActionUnprovision Action = "unprovision"
+)
+
+// Change is a change the provisioning would make to a resource.
+type Change struct {
+	Action Action `json:"action"`
+	// Kind is the kind of the resource, for example datasource, dashboard or alertRule
+	Kind  string `json:"kind"`
+	OrgID int64  `json:"orgId"`
+	Name  string `json:"name,omitempty"`
+	UID   string `json:"uid,omitempty"`
+	// Diff is the difference between the current and the provisioned resource, for the updates
+	Diff string `json:"diff,omitempty"`
+}
+
+// Plan is the changes a provisioner would make.
+type Plan struct {
+	Provisioner string   `json:"provisioner"`
+	Changes     []Change `json:"changes"`
+}
+
+// New returns an empty plan of a provisioner.
+func New(provisioner string) *Plan {
+	return &Plan{Provisioner: provisioner, Changes: []Change{}}
+}
+
+// Add adds a change to the plan.
+func (p *Plan) Add(c Change) {
+	p.Changes = append(p.Changes, c)
+}
+
+// HasChanges returns true if the plan has changes.
+func (p *Plan) HasChanges() bool {
+	return len(p.Changes) > 0
+}
+
+// Sort sorts the changes by organization, kind and name, so the plans are stable across runs.
+func (p *Plan) Sort() {
+	sort.SliceStable(p.Changes, func(i, j int) bool {
+		a, b := p.Changes[i], p.Changes[j]
+		if a.OrgID != b.OrgID {
+			return a.OrgID < b.OrgID
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.UID < b.UID
+	})
+}
+
+// WriteText writes a human readable summary of the plan.
+func (p *Plan) WriteText(w io.Writer) error {
+	if !p.HasChanges() {
+		_, err := fmt.Fprintf(w, "%s: no changes\n", p.Provisioner)
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "%s: %d change(s)\n", p.Provisioner, len(p.Changes)); err != nil {
+		return err
+	}
+	for _, c := range p.Changes {
+		id := c.Name
+		if c.UID != "" {
+			id = fmt.Sprintf("%s (uid=%s)", c.Name, c.UID)
+		}
+		if _, err := fmt.Fprintf(w, "  %s %s %s in org %d\n", c.Action, c.Kind, strings.TrimSpace(id), c.OrgID); err != nil {
+			return err
+		}
+		if c.Diff == "" {
+			continue
+		}
+		for _, line := range strings.Split(strings.TrimRight(c.Diff, "\n"), "\n") {
+			if _, err := fmt.Fprintf(w, "      %s\n", line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Diff returns the difference between the JSON encodings of the current and the provisioned resource,
+// or an empty string when they are the same. The top level fields of the current resource missing
+// from the provisioned one, like ids and versions, are not reported.
+func Diff(current, provisioned any) (string, error) {
+	left, err := toJSONObject(current)
+	if err != nil {
+		return "", err
+	}
+	right, err := toJSONObject(provisioned)
+	if err != nil {
+		return "", err
+	}
+	for k := range left {
+		if _, ok := right[k]; !ok {
+			delete(left, k)
+		}
+	}
+
+	d := diff.New().CompareObjects(left, right)
+	if !d.Modified() {
+		return "", nil
+	}
+	return formatter.NewAsciiFormatter(left, formatter.AsciiFormatterConfig{}).Format(d)
+}
+
+func toJSONObject(v any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	obj := map[string]any{}
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return nil, fmt.Errorf("a diff can only be computed between objects: %w", err)
+	}
+	return obj, nil
+}
@@ -0,0 +1,42 @@
+package plan
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	diff, err := Diff(map[string]any{"url": "http://a", "version": 3}, map[string]any{"url": "http://a"})
+	require.NoError(t, err)
+	require.Empty(t, diff, "the fields missing from the provisioned resource should be ignored")
+
+	diff, err = Diff(map[string]any{"url": "http://a"}, map[string]any{"url": "http://b"})
+	require.NoError(t, err)
+	require.Contains(t, diff, "http://a")
+	require.Contains(t, diff, "http://b")
+
+	_, err = Diff([]string{"a"}, map[string]any{})
+	require.Error(t, err)
+}
+
+func TestPlanWriteText(t *testing.T) {
+	p := New("datasources")
+	var buf bytes.Buffer
+	require.NoError(t, p.WriteText(&buf))
+	require.Equal(t, "datasources: no changes\n", buf.String())
+
+	p.Add(Change{Action: ActionUpdate, Kind: "datasource", OrgID: 2, Name: "B", Diff: "-a\n+b\n"})
+	p.Add(Change{Action: ActionCreate, Kind: "datasource", OrgID: 1, Name: "A", UID: "a"})
+	p.Sort()
+
+	buf.Reset()
+	require.NoError(t, p.WriteText(&buf))
+	require.Equal(t, `datasources: 2 change(s)
+  create datasource A (uid=a) in org 1
+  update datasource B in org 2
+      -a
+      +b
+`, buf.String())
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 )
 
 type configReader interface {
@@ -43,7 +44,7 @@ func (cr *configReaderImpl) readConfig(ctx context.Context, path string) ([]*plu
 			cr.log.Debug("Parsing plugin provisioning file", "path", path, "file.Name", file.Name())
 			app, err := cr.parsePluginConfig(path, file)
 			if err != nil {
-				return nil, err
+				return nil, utils.NewFileError(file.Name(), err)
 			}
 
 			if app != nil {
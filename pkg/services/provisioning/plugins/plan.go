@@ -0,0 +1,105 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginsettings"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/provisioning/plan"
+)
+
+const planKind = "plugin"
+
+// Plan scans a directory for provisioning config files and reports the changes
+// the provisioning of the apps in those files would make, without applying them.
+func Plan(ctx context.Context, configDirectory string, pluginStore pluginstore.Store, pluginSettings pluginsettings.Service, orgService org.Service) (*plan.Plan, error) {
+	logger := log.New("provisioning.plugins")
+	ap := PluginProvisioner{
+		log:            logger,
+		cfgProvider:    newConfigReader(logger, pluginStore),
+		pluginSettings: pluginSettings,
+		orgService:     orgService,
+		pluginStore:    pluginStore,
+	}
+	return ap.planChanges(ctx, configDirectory)
+}
+
+// planPluginSetting is the subset of the settings of a plugin set by the provisioning, compared to report
+// the updates. The secure JSON data is encrypted, only the names of its keys are compared.
+type planPluginSetting struct {
+	Enabled        bool           `json:"enabled"`
+	Pinned         bool           `json:"pinned"`
+	JSONData       map[string]any `json:"jsonData"`
+	SecureJSONData []string       `json:"secureJsonFields"`
+}
+
+func (ap *PluginProvisioner) planChanges(ctx context.Context, configPath string) (*plan.Plan, error) {
+	p := plan.New("plugins")
+	configs, err := ap.cfgProvider.readConfig(ctx, configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cfg := range configs {
+		for _, app := range cfg.Apps {
+			if err := ap.resolve(ctx, app); err != nil {
+				return nil, err
+			}
+
+			desired := planPluginSetting{
+				Enabled:        app.Enabled,
+				Pinned:         app.Pinned,
+				JSONData:       orEmpty(app.JSONData),
+				SecureJSONData: sortedKeys(app.SecureJSONData),
+			}
+			ps, err := ap.pluginSettings.GetPluginSettingByPluginID(ctx, &pluginsettings.GetByPluginIDArgs{
+				OrgID:    app.OrgID,
+				PluginID: app.PluginID,
+			})
+			if err != nil {
+				if !errors.Is(err, pluginsettings.ErrPluginSettingNotFound) {
+					return nil, err
+				}
+				p.Add(plan.Change{Action: plan.ActionCreate, Kind: planKind, OrgID: app.OrgID, Name: app.PluginID})
+				continue
+			}
+
+			diff, err := plan.Diff(planPluginSetting{
+				Enabled:        ps.Enabled,
+				Pinned:         ps.Pinned,
+				JSONData:       orEmpty(ps.JSONData),
+				SecureJSONData: sortedKeys(ps.SecureJSONData),
+			}, desired)
+			if err != nil {
+				return nil, err
+			}
+			if diff != "" {
+				p.Add(plan.Change{Action: plan.ActionUpdate, Kind: planKind, OrgID: app.OrgID, Name: app.PluginID, Diff: diff})
+			}
+		}
+	}
+
+	p.Sort()
+	return p, nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// orEmpty returns an empty map for a nil map, so missing and empty JSON data are not reported as changes.
+func orEmpty(m map[string]any) map[string]any {
+	if m == nil {
+		return map[string]any{}
+	}
+	return m
+}
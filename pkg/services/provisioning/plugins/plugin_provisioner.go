@@ -36,23 +36,8 @@ type PluginProvisioner struct {
 
 func (ap *PluginProvisioner) apply(ctx context.Context, cfg *pluginsAsConfig) error {
 	for _, app := range cfg.Apps {
-		if app.OrgID == 0 && app.OrgName != "" {
-			getOrgQuery := &org.GetOrgByNameQuery{Name: app.OrgName}
-			res, err := ap.orgService.GetByName(ctx, getOrgQuery)
-			if err != nil {
-				return err
-			}
-			app.OrgID = res.ID
-		} else if app.OrgID < 0 {
-			app.OrgID = 1
-		}
-
-		p, found := ap.pluginStore.Plugin(ctx, app.PluginID)
-		if !found {
-			return errors.New("plugin not found")
-		}
-		if p.AutoEnabled && !app.Enabled {
-			return errors.New("plugin is auto enabled and cannot be disabled")
+		if err := ap.resolve(ctx, app); err != nil {
+			return err
 		}
 
 		ps, err := ap.pluginSettings.GetPluginSettingByPluginID(ctx, &pluginsettings.GetByPluginIDArgs{
@@ -84,6 +69,29 @@ func (ap *PluginProvisioner) apply(ctx context.Context, cfg *pluginsAsConfig) er
 	return nil
 }
 
+// resolve sets the organization of the app, and checks the plugin of the app can be provisioned.
+func (ap *PluginProvisioner) resolve(ctx context.Context, app *appFromConfig) error {
+	if app.OrgID == 0 && app.OrgName != "" {
+		getOrgQuery := &org.GetOrgByNameQuery{Name: app.OrgName}
+		res, err := ap.orgService.GetByName(ctx, getOrgQuery)
+		if err != nil {
+			return err
+		}
+		app.OrgID = res.ID
+	} else if app.OrgID < 0 {
+		app.OrgID = 1
+	}
+
+	p, found := ap.pluginStore.Plugin(ctx, app.PluginID)
+	if !found {
+		return errors.New("plugin not found")
+	}
+	if p.AutoEnabled && !app.Enabled {
+		return errors.New("plugin is auto enabled and cannot be disabled")
+	}
+	return nil
+}
+
 func (ap *PluginProvisioner) applyChanges(ctx context.Context, configPath string) error {
 	configs, err := ap.cfgProvider.readConfig(ctx, configPath)
 	if err != nil {
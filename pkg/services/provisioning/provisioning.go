@@ -4,8 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -30,7 +30,11 @@ import (
 	prov_alerting "github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
+	"github.com/grafana/grafana/pkg/services/provisioning/plan"
 	"github.com/grafana/grafana/pkg/services/provisioning/plugins"
+	"github.com/grafana/grafana/pkg/services/provisioning/remote"
+	"github.com/grafana/grafana/pkg/services/provisioning/secretresolver"
+	"github.com/grafana/grafana/pkg/services/provisioning/status"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/searchV2"
 	"github.com/grafana/grafana/pkg/services/secrets"
@@ -60,6 +64,14 @@ func ProvideService(
 	tracer tracing.Tracer,
 	dual dualwrite.Service,
 ) (*ProvisioningServiceImpl, error) {
+	remoteSources, err := remote.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := secretresolver.Register(cfg); err != nil {
+		return nil, err
+	}
+
 	s := &ProvisioningServiceImpl{
 		Cfg:                          cfg,
 		SQLStore:                     sqlStore,
@@ -85,6 +97,8 @@ func ProvideService(
 		folderService:                folderService,
 		resourcePermissions:          resourcePermissions,
 		tracer:                       tracer,
+		remoteSources:                remoteSources,
+		statusStore:                  status.NewStore(sqlStore),
 	}
 
 	if err := s.setDashboardProvisioner(); err != nil {
@@ -95,7 +109,7 @@ func ProvideService(
 }
 
 func (ps *ProvisioningServiceImpl) setDashboardProvisioner() error {
-	dashboardPath := filepath.Join(ps.Cfg.ProvisioningPath, "dashboards")
+	dashboardPath := ps.remoteSources.Dir("dashboards")
 	dashProvisioner, err := ps.newDashboardProvisioner(context.Background(), dashboardPath, ps.dashboardProvisioningService, ps.orgService, ps.dashboardService, ps.folderService, ps.dual)
 	if err != nil {
 		return fmt.Errorf("%v: %w", "Failed to create provisioner", err)
@@ -111,6 +125,12 @@ type ProvisioningService interface {
 	ProvisionPlugins(ctx context.Context) error
 	ProvisionDashboards(ctx context.Context) error
 	ProvisionAlerting(ctx context.Context) error
+	PlanDatasources(ctx context.Context) (*plan.Plan, error)
+	PlanPlugins(ctx context.Context) (*plan.Plan, error)
+	PlanDashboards(ctx context.Context) (*plan.Plan, error)
+	PlanAlerting(ctx context.Context) (*plan.Plan, error)
+	Plan(ctx context.Context) ([]*plan.Plan, error)
+	GetStatus(ctx context.Context) ([]status.Status, error)
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
 }
@@ -122,13 +142,20 @@ func newProvisioningServiceImpl(
 	provisionPlugins func(context.Context, string, pluginstore.Store, pluginsettings.Service, org.Service) error,
 	searchService searchV2.SearchService,
 ) (*ProvisioningServiceImpl, error) {
+	cfg := setting.NewCfg()
+	remoteSources, err := remote.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	s := &ProvisioningServiceImpl{
 		log:                     log.New("provisioning"),
 		newDashboardProvisioner: newDashboardProvisioner,
 		provisionDatasources:    provisionDatasources,
 		provisionPlugins:        provisionPlugins,
-		Cfg:                     setting.NewCfg(),
+		Cfg:                     cfg,
 		searchService:           searchService,
+		remoteSources:           remoteSources,
 	}
 
 	if err := s.setDashboardProvisioner(); err != nil {
@@ -168,6 +195,9 @@ type ProvisioningServiceImpl struct {
 	tracer                       tracing.Tracer
 	dual                         dualwrite.Service
 	onceInitProvisioners         sync.Once
+	remoteSources                *remote.Syncer
+	// statusStore records the status of the runs of the provisioners, it is nil in the tests
+	statusStore *status.Store
 }
 
 func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) error {
@@ -221,6 +251,8 @@ func (ps *ProvisioningServiceImpl) Run(ctx context.Context) error {
 		ps.searchService.TriggerReIndex()
 	}
 
+	go ps.remoteSources.Run(ctx, ps.provisionRemoteChanges)
+
 	for {
 		// Wait for unlock. This is tied to new dashboardProvisioner to be instantiated before we start polling.
 		ps.mutex.Lock()
@@ -243,10 +275,20 @@ func (ps *ProvisioningServiceImpl) Run(ctx context.Context) error {
 	}
 }
 
-func (ps *ProvisioningServiceImpl) ProvisionDatasources(ctx context.Context) error {
+func (ps *ProvisioningServiceImpl) ProvisionDatasources(ctx context.Context) (err error) {
 	ps.mutex.Lock()
 	defer ps.mutex.Unlock()
-	datasourcePath := filepath.Join(ps.Cfg.ProvisioningPath, "datasources")
+	start := time.Now()
+	p := ps.planForStatus(ctx, "datasources", ps.PlanDatasources)
+	defer func() {
+		ps.recordStatus(ctx, "datasources", start, p, err)
+	}()
+
+	if err := ps.remoteSources.Sync(ctx, "datasources"); err != nil {
+		ps.log.Error("Failed to provision data sources", "error", err)
+		return err
+	}
+	datasourcePath := ps.remoteSources.Dir("datasources")
 	if err := ps.provisionDatasources(ctx, datasourcePath, ps.datasourceService, ps.correlationsService, ps.orgService); err != nil {
 		err = fmt.Errorf("%v: %w", "Datasource provisioning error", err)
 		ps.log.Error("Failed to provision data sources", "error", err)
@@ -255,10 +297,20 @@ func (ps *ProvisioningServiceImpl) ProvisionDatasources(ctx context.Context) err
 	return nil
 }
 
-func (ps *ProvisioningServiceImpl) ProvisionPlugins(ctx context.Context) error {
+func (ps *ProvisioningServiceImpl) ProvisionPlugins(ctx context.Context) (err error) {
 	ps.mutex.Lock()
 	defer ps.mutex.Unlock()
-	appPath := filepath.Join(ps.Cfg.ProvisioningPath, "plugins")
+	start := time.Now()
+	p := ps.planForStatus(ctx, "plugins", ps.PlanPlugins)
+	defer func() {
+		ps.recordStatus(ctx, "plugins", start, p, err)
+	}()
+
+	if err := ps.remoteSources.Sync(ctx, "plugins"); err != nil {
+		ps.log.Error("Failed to provision plugins", "error", err)
+		return err
+	}
+	appPath := ps.remoteSources.Dir("plugins")
 	if err := ps.provisionPlugins(ctx, appPath, ps.pluginStore, ps.pluginsSettings, ps.orgService); err != nil {
 		err = fmt.Errorf("%v: %w", "app provisioning error", err)
 		ps.log.Error("Failed to provision plugins", "error", err)
@@ -267,8 +319,18 @@ func (ps *ProvisioningServiceImpl) ProvisionPlugins(ctx context.Context) error {
 	return nil
 }
 
-func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) error {
-	err := ps.setDashboardProvisioner()
+func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) (err error) {
+	start := time.Now()
+	p := ps.planForStatus(ctx, "dashboards", ps.PlanDashboards)
+	defer func() {
+		ps.recordStatus(ctx, "dashboards", start, p, err)
+	}()
+
+	if err := ps.remoteSources.Sync(ctx, "dashboards"); err != nil {
+		return err
+	}
+
+	err = ps.setDashboardProvisioner()
 	if err != nil {
 		return fmt.Errorf("%v: %w", "Failed to create provisioner", err)
 	}
@@ -288,8 +350,71 @@ func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) erro
 	return nil
 }
 
-func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error {
-	alertingPath := filepath.Join(ps.Cfg.ProvisioningPath, "alerting")
+func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) (err error) {
+	start := time.Now()
+	p := ps.planForStatus(ctx, "alerting", ps.PlanAlerting)
+	defer func() {
+		ps.recordStatus(ctx, "alerting", start, p, err)
+	}()
+
+	if err := ps.remoteSources.Sync(ctx, "alerting"); err != nil {
+		return err
+	}
+	return ps.provisionAlerting(ctx, ps.alertingProvisionerConfig())
+}
+
+// planForStatus returns the changes the provisioner is about to apply, recorded in its status after the run.
+// The provisioning doesn't fail when the plan fails, the status is recorded without the resources.
+func (ps *ProvisioningServiceImpl) planForStatus(ctx context.Context, provisioner string, planner func(context.Context) (*plan.Plan, error)) *plan.Plan {
+	if ps.statusStore == nil {
+		return nil
+	}
+	p, err := planner(ctx)
+	if err != nil {
+		ps.log.Debug("Failed to plan the provisioning status", "provisioner", provisioner, "error", err)
+		return nil
+	}
+	return p
+}
+
+// recordStatus stores the status of a run of the provisioner, the errors are only logged.
+func (ps *ProvisioningServiceImpl) recordStatus(ctx context.Context, provisioner string, start time.Time, p *plan.Plan, err error) {
+	if ps.statusStore == nil {
+		return
+	}
+	if err := ps.statusStore.Save(ctx, status.New(provisioner, start, p, err)); err != nil {
+		ps.log.Warn("Failed to save the provisioning status", "provisioner", provisioner, "error", err)
+	}
+}
+
+// GetStatus returns the status of the last run of each provisioner.
+func (ps *ProvisioningServiceImpl) GetStatus(ctx context.Context) ([]status.Status, error) {
+	if ps.statusStore == nil {
+		return []status.Status{}, nil
+	}
+	return ps.statusStore.List(ctx)
+}
+
+// provisionRemoteChanges runs the provisioner again after the files of one of its remote sources changed.
+func (ps *ProvisioningServiceImpl) provisionRemoteChanges(ctx context.Context, provisioner string) {
+	var err error
+	switch provisioner {
+	case "datasources":
+		err = ps.ProvisionDatasources(ctx)
+	case "plugins":
+		err = ps.ProvisionPlugins(ctx)
+	case "alerting":
+		err = ps.ProvisionAlerting(ctx)
+	case "dashboards":
+		err = ps.ProvisionDashboards(ctx)
+	}
+	if err != nil {
+		ps.log.Error("Failed to provision the changes of the remote sources", "provisioner", provisioner, "error", err)
+	}
+}
+
+func (ps *ProvisioningServiceImpl) alertingProvisionerConfig() prov_alerting.ProvisionerConfig {
+	alertingPath := ps.remoteSources.Dir("alerting")
 	ruleService := provisioning.NewAlertRuleService(
 		ps.alertingStore,
 		ps.alertingStore,
@@ -315,6 +440,7 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		ps.log,
 		ps.resourcePermissions,
 		ps.tracer,
+		nil,
 	)
 	contactPointService := provisioning.NewContactPointService(configStore, ps.secretService,
 		ps.alertingStore, ps.SQLStore, receiverSvc, ps.log, ps.alertingStore, ps.resourcePermissions)
@@ -322,7 +448,7 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		ps.alertingStore, ps.SQLStore, ps.Cfg.UnifiedAlerting, ps.log)
 	mutetimingsService := provisioning.NewMuteTimingService(configStore, ps.alertingStore, ps.alertingStore, ps.log, ps.alertingStore)
 	templateService := provisioning.NewTemplateService(configStore, ps.alertingStore, ps.alertingStore, ps.log)
-	cfg := prov_alerting.ProvisionerConfig{
+	return prov_alerting.ProvisionerConfig{
 		Path:                       alertingPath,
 		RuleService:                *ruleService,
 		FolderService:              ps.folderService,
@@ -332,7 +458,73 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		MuteTimingService:          *mutetimingsService,
 		TemplateService:            *templateService,
 	}
-	return ps.provisionAlerting(ctx, cfg)
+}
+
+// PlanDatasources reports the changes ProvisionDatasources would make, without applying them.
+func (ps *ProvisioningServiceImpl) PlanDatasources(ctx context.Context) (*plan.Plan, error) {
+	if err := ps.remoteSources.Sync(ctx, "datasources"); err != nil {
+		return nil, err
+	}
+	datasourcePath := ps.remoteSources.Dir("datasources")
+	p, err := datasources.Plan(ctx, datasourcePath, ps.datasourceService, ps.orgService)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "Datasource provisioning error", err)
+	}
+	return p, nil
+}
+
+// PlanPlugins reports the changes ProvisionPlugins would make, without applying them.
+func (ps *ProvisioningServiceImpl) PlanPlugins(ctx context.Context) (*plan.Plan, error) {
+	if err := ps.remoteSources.Sync(ctx, "plugins"); err != nil {
+		return nil, err
+	}
+	appPath := ps.remoteSources.Dir("plugins")
+	p, err := plugins.Plan(ctx, appPath, ps.pluginStore, ps.pluginsSettings, ps.orgService)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "app provisioning error", err)
+	}
+	return p, nil
+}
+
+// PlanDashboards reports the changes ProvisionDashboards would make, without applying them.
+// The configuration is read again, the running dashboard provisioner is left unchanged.
+func (ps *ProvisioningServiceImpl) PlanDashboards(ctx context.Context) (*plan.Plan, error) {
+	if err := ps.remoteSources.Sync(ctx, "dashboards"); err != nil {
+		return nil, err
+	}
+	dashboardPath := ps.remoteSources.Dir("dashboards")
+	dashProvisioner, err := ps.newDashboardProvisioner(ctx, dashboardPath, ps.dashboardProvisioningService, ps.orgService, ps.dashboardService, ps.folderService, ps.dual)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", "Failed to create provisioner", err)
+	}
+	return dashProvisioner.Plan(ctx)
+}
+
+// PlanAlerting reports the changes ProvisionAlerting would make, without applying them.
+func (ps *ProvisioningServiceImpl) PlanAlerting(ctx context.Context) (*plan.Plan, error) {
+	if err := ps.remoteSources.Sync(ctx, "alerting"); err != nil {
+		return nil, err
+	}
+	return prov_alerting.Plan(ctx, ps.alertingProvisionerConfig())
+}
+
+// Plan reports the changes of all the provisioners, in the order they are run.
+func (ps *ProvisioningServiceImpl) Plan(ctx context.Context) ([]*plan.Plan, error) {
+	planners := []func(context.Context) (*plan.Plan, error){
+		ps.PlanDatasources,
+		ps.PlanPlugins,
+		ps.PlanAlerting,
+		ps.PlanDashboards,
+	}
+	plans := make([]*plan.Plan, 0, len(planners))
+	for _, planner := range planners {
+		p, err := planner(ctx)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, p)
+	}
+	return plans, nil
 }
 
 func (ps *ProvisioningServiceImpl) GetDashboardProvisionerResolvedPath(name string) string {
@@ -1,6 +1,11 @@
 package provisioning
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/provisioning/plan"
+	"github.com/grafana/grafana/pkg/services/provisioning/status"
+)
 
 type Calls struct {
 	RunInitProvisioners                 []any
@@ -8,6 +13,12 @@ type Calls struct {
 	ProvisionPlugins                    []any
 	ProvisionDashboards                 []any
 	ProvisionAlerting                   []any
+	PlanDatasources                     []any
+	PlanPlugins                         []any
+	PlanDashboards                      []any
+	PlanAlerting                        []any
+	Plan                                []any
+	GetStatus                           []any
 	GetDashboardProvisionerResolvedPath []any
 	GetAllowUIUpdatesFromConfig         []any
 	Run                                 []any
@@ -19,6 +30,8 @@ type ProvisioningServiceMock struct {
 	ProvisionDatasourcesFunc                func(ctx context.Context) error
 	ProvisionPluginsFunc                    func() error
 	ProvisionDashboardsFunc                 func() error
+	PlanFunc                                func(provisioner string) (*plan.Plan, error)
+	GetStatusFunc                           func() ([]status.Status, error)
 	GetDashboardProvisionerResolvedPathFunc func(name string) string
 	GetAllowUIUpdatesFromConfigFunc         func(name string) bool
 	RunFunc                                 func(ctx context.Context) error
@@ -67,6 +80,54 @@ func (mock *ProvisioningServiceMock) ProvisionAlerting(ctx context.Context) erro
 	return nil
 }
 
+func (mock *ProvisioningServiceMock) PlanDatasources(ctx context.Context) (*plan.Plan, error) {
+	mock.Calls.PlanDatasources = append(mock.Calls.PlanDatasources, nil)
+	return mock.plan("datasources")
+}
+
+func (mock *ProvisioningServiceMock) PlanPlugins(ctx context.Context) (*plan.Plan, error) {
+	mock.Calls.PlanPlugins = append(mock.Calls.PlanPlugins, nil)
+	return mock.plan("plugins")
+}
+
+func (mock *ProvisioningServiceMock) PlanDashboards(ctx context.Context) (*plan.Plan, error) {
+	mock.Calls.PlanDashboards = append(mock.Calls.PlanDashboards, nil)
+	return mock.plan("dashboards")
+}
+
+func (mock *ProvisioningServiceMock) PlanAlerting(ctx context.Context) (*plan.Plan, error) {
+	mock.Calls.PlanAlerting = append(mock.Calls.PlanAlerting, nil)
+	return mock.plan("alerting")
+}
+
+func (mock *ProvisioningServiceMock) Plan(ctx context.Context) ([]*plan.Plan, error) {
+	mock.Calls.Plan = append(mock.Calls.Plan, nil)
+	plans := []*plan.Plan{}
+	for _, provisioner := range []string{"datasources", "plugins", "alerting", "dashboards"} {
+		p, err := mock.plan(provisioner)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, p)
+	}
+	return plans, nil
+}
+
+func (mock *ProvisioningServiceMock) plan(provisioner string) (*plan.Plan, error) {
+	if mock.PlanFunc != nil {
+		return mock.PlanFunc(provisioner)
+	}
+	return plan.New(provisioner), nil
+}
+
+func (mock *ProvisioningServiceMock) GetStatus(ctx context.Context) ([]status.Status, error) {
+	mock.Calls.GetStatus = append(mock.Calls.GetStatus, nil)
+	if mock.GetStatusFunc != nil {
+		return mock.GetStatusFunc()
+	}
+	return []status.Status{}, nil
+}
+
 func (mock *ProvisioningServiceMock) GetDashboardProvisionerResolvedPath(name string) string {
 	mock.Calls.GetDashboardProvisionerResolvedPath = append(mock.Calls.GetDashboardProvisionerResolvedPath, name)
 	if mock.GetDashboardProvisionerResolvedPathFunc != nil {
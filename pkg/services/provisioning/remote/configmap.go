@@ -0,0 +1,177 @@
+package remote
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// directoryFetcher reads the files of a config map mounted in a directory. The kubelet updates the files
+// through the ..data symlink, the hidden entries are skipped.
+type directoryFetcher struct {
+	source setting.ProvisioningSource
+	digest string
+}
+
+func newDirectoryFetcher(src setting.ProvisioningSource) *directoryFetcher {
+	return &directoryFetcher{source: src}
+}
+
+func (f *directoryFetcher) fetch(_ context.Context) (map[string][]byte, error) {
+	entries, err := os.ReadDir(f.source.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, "..") || !isConfigFile(name) {
+			continue
+		}
+		path := filepath.Join(f.source.Directory, name)
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		// nolint:gosec
+		// We can ignore the gosec G304 warning on this one because `path` comes from the provisioning source configuration
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = data
+	}
+
+	digest := digestFiles(files)
+	if digest == f.digest {
+		return nil, errNotModified
+	}
+	f.digest = digest
+	return files, nil
+}
+
+func digestFiles(files map[string][]byte) string {
+	hash := sha256.New()
+	for _, name := range sortedNames(files) {
+		_, _ = fmt.Fprintf(hash, "%s:%d\n", name, len(files[name]))
+		_, _ = hash.Write(files[name])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// configMapFetcher reads a config map from the Kubernetes API, with the in-cluster configuration.
+// The config map is watched, so its changes are synced without waiting for the sync interval.
+type configMapFetcher struct {
+	source          setting.ProvisioningSource
+	namespace       string
+	client          kubernetes.Interface
+	resourceVersion string
+	log             log.Logger
+}
+
+func newConfigMapFetcher(src setting.ProvisioningSource) (*configMapFetcher, error) {
+	restConfig, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the in-cluster configuration: %w", err)
+	}
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := src.Namespace
+	if namespace == "" {
+		namespace = "default"
+		if data, err := os.ReadFile(serviceAccountNamespaceFile); err == nil {
+			namespace = strings.TrimSpace(string(data))
+		}
+	}
+	return &configMapFetcher{
+		source:    src,
+		namespace: namespace,
+		client:    client,
+		log:       log.New("provisioning.remote"),
+	}, nil
+}
+
+func (f *configMapFetcher) fetch(ctx context.Context) (map[string][]byte, error) {
+	cm, err := f.client.CoreV1().ConfigMaps(f.namespace).Get(ctx, f.source.ConfigMap, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if cm.ResourceVersion == f.resourceVersion {
+		return nil, errNotModified
+	}
+
+	files := make(map[string][]byte, len(cm.Data)+len(cm.BinaryData))
+	for name, data := range cm.Data {
+		if isConfigFile(name) {
+			files[name] = []byte(data)
+		}
+	}
+	for name, data := range cm.BinaryData {
+		if isConfigFile(name) {
+			files[name] = data
+		}
+	}
+	f.resourceVersion = cm.ResourceVersion
+	return files, nil
+}
+
+func (f *configMapFetcher) watch(ctx context.Context) <-chan struct{} {
+	events := make(chan struct{}, 1)
+	go func() {
+		for {
+			f.watchOnce(ctx, events)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(10 * time.Second):
+			}
+		}
+	}()
+	return events
+}
+
+// watchOnce forwards the events of a watch of the config map until it ends.
+func (f *configMapFetcher) watchOnce(ctx context.Context, events chan<- struct{}) {
+	w, err := f.client.CoreV1().ConfigMaps(f.namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", f.source.ConfigMap).String(),
+	})
+	if err != nil {
+		f.log.Warn("Failed to watch config map", "source", f.source.Name, "error", err)
+		return
+	}
+	defer w.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-w.ResultChan():
+			if !ok {
+				return
+			}
+			select {
+			case events <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
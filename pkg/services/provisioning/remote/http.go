@@ -0,0 +1,90 @@
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// httpFetcher fetches a single file from a HTTP(S) URL. The ETag of the response is sent back
+// in the If-None-Match header, so unchanged files aren't downloaded again. The content is compared
+// as well, for the servers not sending ETags.
+type httpFetcher struct {
+	source setting.ProvisioningSource
+	client *http.Client
+	name   string
+	etag   string
+	digest string
+}
+
+func newHTTPFetcher(src setting.ProvisioningSource) (*httpFetcher, error) {
+	u, err := url.Parse(src.URL)
+	if err != nil {
+		return nil, err
+	}
+	name := path.Base(u.Path)
+	if !isConfigFile(name) {
+		name = src.Name + ".yaml"
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if src.TLSSkipVerify {
+		// nolint:gosec
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &httpFetcher{
+		source: src,
+		client: &http.Client{Transport: transport, Timeout: 30 * time.Second},
+		name:   name,
+	}, nil
+}
+
+func (f *httpFetcher) fetch(ctx context.Context) (map[string][]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.source.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case f.source.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+f.source.BearerToken)
+	case f.source.BasicAuthUser != "":
+		req.SetBasicAuth(f.source.BasicAuthUser, f.source.BasicAuthPassword)
+	}
+	if f.etag != "" {
+		req.Header.Set("If-None-Match", f.etag)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, errNotModified
+	default:
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.etag = resp.Header.Get("ETag")
+	files := map[string][]byte{f.name: data}
+	digest := digestFiles(files)
+	if digest == f.digest {
+		return nil, errNotModified
+	}
+	f.digest = digest
+	return files, nil
+}
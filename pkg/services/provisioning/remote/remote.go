@@ -0,0 +1,298 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// errNotModified is returned by the fetchers when the files of a source didn't change since the last fetch.
+var errNotModified = errors.New("not modified")
+
+type fetcher interface {
+	// fetch returns the files of the source by file name.
+	fetch(ctx context.Context) (map[string][]byte, error)
+}
+
+// watcher is implemented by the fetchers notified of the changes of their source, on top of the polling.
+type watcher interface {
+	watch(ctx context.Context) <-chan struct{}
+}
+
+type source struct {
+	setting.ProvisioningSource
+	fetcher fetcher
+	files   map[string][]byte
+	fetched bool
+}
+
+// Syncer fetches the provisioning files of the remote sources, and stages them in the data path along the
+// local files of their provisioner. The provisioners with remote sources read the staged files instead of
+// the files of the provisioning path.
+type Syncer struct {
+	provisioningPath string
+	stagingPath      string
+	sources          []*source
+	log              log.Logger
+
+	mu sync.Mutex
+}
+
+func New(cfg *setting.Cfg) (*Syncer, error) {
+	s := &Syncer{
+		provisioningPath: cfg.ProvisioningPath,
+		stagingPath:      filepath.Join(cfg.DataPath, "provisioning"),
+		log:              log.New("provisioning.remote"),
+	}
+	for _, src := range cfg.ProvisioningSources {
+		f, err := newFetcher(src)
+		if err != nil {
+			return nil, fmt.Errorf("provisioning source %q: %w", src.Name, err)
+		}
+		s.sources = append(s.sources, &source{ProvisioningSource: src, fetcher: f})
+	}
+	return s, nil
+}
+
+func newFetcher(src setting.ProvisioningSource) (fetcher, error) {
+	switch src.Type {
+	case "http":
+		return newHTTPFetcher(src)
+	case "s3":
+		return newS3Fetcher(src), nil
+	case "configmap":
+		if src.Directory != "" {
+			return newDirectoryFetcher(src), nil
+		}
+		return newConfigMapFetcher(src)
+	default:
+		return nil, fmt.Errorf("unknown source type %q", src.Type)
+	}
+}
+
+// Dir returns the directory the provisioner reads its files from.
+func (s *Syncer) Dir(provisioner string) string {
+	if s.hasSources(provisioner) {
+		return filepath.Join(s.stagingPath, provisioner)
+	}
+	return filepath.Join(s.provisioningPath, provisioner)
+}
+
+func (s *Syncer) hasSources(provisioner string) bool {
+	for _, src := range s.sources {
+		if src.Provisioner == provisioner {
+			return true
+		}
+	}
+	return false
+}
+
+// Sync fetches the remote sources of the provisioner and stages their files. It returns an error when a source
+// was never fetched, so the provisioner doesn't run without its remote files. Later failures are logged, and
+// the files of the last successful fetch are kept.
+func (s *Syncer) Sync(ctx context.Context, provisioner string) error {
+	if !s.hasSources(provisioner) {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, src := range s.sources {
+		if src.Provisioner != provisioner {
+			continue
+		}
+		if _, err := s.fetch(ctx, src); err != nil {
+			return err
+		}
+	}
+	return s.stage(provisioner)
+}
+
+// Run polls the remote sources at their sync interval, and watches the ones supporting it, until the context
+// is canceled. onChange is called with the provisioner of a source after its files changed and were staged.
+func (s *Syncer) Run(ctx context.Context, onChange func(ctx context.Context, provisioner string)) {
+	var wg sync.WaitGroup
+	for _, src := range s.sources {
+		wg.Add(1)
+		go func(src *source) {
+			defer wg.Done()
+			s.runSource(ctx, src, onChange)
+		}(src)
+	}
+	wg.Wait()
+}
+
+func (s *Syncer) runSource(ctx context.Context, src *source, onChange func(ctx context.Context, provisioner string)) {
+	var events <-chan struct{}
+	if w, ok := src.fetcher.(watcher); ok {
+		events = w.watch(ctx)
+	}
+
+	ticker := time.NewTicker(src.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-events:
+		}
+
+		changed, err := s.syncSource(ctx, src)
+		if err != nil {
+			s.log.Error("Failed to sync provisioning source", "source", src.Name, "error", err)
+			continue
+		}
+		if changed {
+			s.log.Info("Provisioning source changed", "source", src.Name, "provisioner", src.Provisioner)
+			onChange(ctx, src.Provisioner)
+		}
+	}
+}
+
+func (s *Syncer) syncSource(ctx context.Context, src *source) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed, err := s.fetch(ctx, src)
+	if err != nil || !changed {
+		return false, err
+	}
+	return true, s.stage(src.Provisioner)
+}
+
+// fetch updates the files of the source, and returns whether they changed.
+func (s *Syncer) fetch(ctx context.Context, src *source) (bool, error) {
+	files, err := src.fetcher.fetch(ctx)
+	if errors.Is(err, errNotModified) {
+		return false, nil
+	}
+	if err != nil {
+		if !src.fetched {
+			return false, fmt.Errorf("failed to fetch provisioning source %q: %w", src.Name, err)
+		}
+		s.log.Warn("Failed to fetch provisioning source, using the last fetched files", "source", src.Name, "error", err)
+		return false, nil
+	}
+
+	src.files = files
+	src.fetched = true
+	return true, nil
+}
+
+// stage rebuilds the staging directory of the provisioner from its local files and the files of its sources.
+// The files of the sources with a path are written to that path instead.
+func (s *Syncer) stage(provisioner string) error {
+	dir := filepath.Join(s.stagingPath, provisioner)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	if err := copyFiles(filepath.Join(s.provisioningPath, provisioner), dir); err != nil {
+		return err
+	}
+
+	for _, src := range s.sources {
+		if src.Provisioner != provisioner {
+			continue
+		}
+		target := dir
+		if src.Path != "" {
+			target = src.Path
+			if err := removeSourceFiles(target, src.Name); err != nil {
+				return err
+			}
+		}
+		if err := os.MkdirAll(target, 0o750); err != nil {
+			return err
+		}
+		for name, data := range src.files {
+			if err := os.WriteFile(filepath.Join(target, stagedName(src.Name, name)), data, 0o640); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// stagedName prefixes the file name with the source, so the files of different sources don't collide.
+func stagedName(sourceName, name string) string {
+	return sourceName + "-" + filepath.Base(name)
+}
+
+// removeSourceFiles removes the files written by a previous stage of the source to dir.
+func removeSourceFiles(dir, sourceName string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), sourceName+"-") {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// copyFiles copies the top level files of src to dst, the provisioners don't read the subdirectories.
+func copyFiles(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		info, err := os.Stat(filepath.Join(src, entry.Name()))
+		if err != nil || info.IsDir() {
+			continue
+		}
+		// nolint:gosec
+		// We can ignore the gosec G304 warning on this one because the path comes from the provisioning path
+		data, err := os.ReadFile(filepath.Join(src, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dst, entry.Name()), data, 0o640); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isConfigFile returns whether the provisioners read the file, by its extension.
+func isConfigFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// sortedNames returns the names of the files in a stable order, to compute signatures.
+func sortedNames(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,127 @@
+package remote
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestSyncer(t *testing.T) {
+	content := "apiVersion: 1\n"
+	requests, notModified := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		etag := strconv.Quote(strings.TrimSpace(content))
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := setting.NewCfg()
+	cfg.ProvisioningPath = t.TempDir()
+	cfg.DataPath = t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(cfg.ProvisioningPath, "datasources"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.ProvisioningPath, "datasources", "local.yaml"), []byte("local"), 0o600))
+
+	mounted := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(mounted, "dashboards.yaml"), []byte("providers: []"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(mounted, "..data"), []byte("ignored"), 0o600))
+
+	cfg.ProvisioningSources = []setting.ProvisioningSource{
+		{Name: "remote", Type: "http", Provisioner: "datasources", URL: server.URL + "/datasources.yaml", BearerToken: "token"},
+		{Name: "mounted", Type: "configmap", Provisioner: "dashboards", Directory: mounted},
+	}
+	syncer, err := New(cfg)
+	require.NoError(t, err)
+
+	require.Equal(t, filepath.Join(cfg.ProvisioningPath, "plugins"), syncer.Dir("plugins"), "the provisioners without sources should read the provisioning path")
+	datasourcesDir := syncer.Dir("datasources")
+	require.Equal(t, filepath.Join(cfg.DataPath, "provisioning", "datasources"), datasourcesDir)
+
+	ctx := context.Background()
+	require.NoError(t, syncer.Sync(ctx, "datasources"))
+	requireFile(t, filepath.Join(datasourcesDir, "local.yaml"), "local")
+	requireFile(t, filepath.Join(datasourcesDir, "remote-datasources.yaml"), "apiVersion: 1\n")
+
+	t.Run("should not download unchanged files again", func(t *testing.T) {
+		changed, err := syncer.syncSource(ctx, syncer.sources[0])
+		require.NoError(t, err)
+		require.False(t, changed)
+		require.Equal(t, 1, notModified)
+	})
+
+	t.Run("should stage the changed files", func(t *testing.T) {
+		content = "apiVersion: 2\n"
+		changed, err := syncer.syncSource(ctx, syncer.sources[0])
+		require.NoError(t, err)
+		require.True(t, changed)
+		requireFile(t, filepath.Join(datasourcesDir, "remote-datasources.yaml"), "apiVersion: 2\n")
+	})
+
+	t.Run("should keep the last fetched files when the source fails", func(t *testing.T) {
+		syncer.sources[0].fetcher.(*httpFetcher).source.BearerToken = ""
+		require.NoError(t, syncer.Sync(ctx, "datasources"))
+		requireFile(t, filepath.Join(datasourcesDir, "remote-datasources.yaml"), "apiVersion: 2\n")
+	})
+
+	t.Run("should skip the hidden files of mounted config maps", func(t *testing.T) {
+		require.NoError(t, syncer.Sync(ctx, "dashboards"))
+		entries, err := os.ReadDir(syncer.Dir("dashboards"))
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		requireFile(t, filepath.Join(syncer.Dir("dashboards"), "mounted-dashboards.yaml"), "providers: []")
+	})
+
+	require.Greater(t, requests, 0)
+}
+
+func TestSyncerFailsUntilFetched(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := setting.NewCfg()
+	cfg.ProvisioningPath = t.TempDir()
+	cfg.DataPath = t.TempDir()
+	cfg.ProvisioningSources = []setting.ProvisioningSource{
+		{Name: "remote", Type: "http", Provisioner: "alerting", URL: server.URL},
+	}
+	syncer, err := New(cfg)
+	require.NoError(t, err)
+
+	require.Error(t, syncer.Sync(context.Background(), "alerting"))
+	require.NoError(t, syncer.Sync(context.Background(), "plugins"))
+}
+
+func TestSplitBucketURL(t *testing.T) {
+	bucketURL, prefix, err := splitBucketURL("s3://bucket/provisioning/datasources?region=us-east-1")
+	require.NoError(t, err)
+	require.Equal(t, "s3://bucket?region=us-east-1", bucketURL)
+	require.Equal(t, "provisioning/datasources/", prefix)
+}
+
+func requireFile(t *testing.T, path, content string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, content, string(data))
+}
@@ -0,0 +1,90 @@
+package remote
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"gocloud.dev/blob"
+	_ "gocloud.dev/blob/s3blob"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// s3Fetcher fetches the files at the top level of a bucket prefix. The files are only downloaded
+// again when the signature of the listed objects changes.
+type s3Fetcher struct {
+	source    setting.ProvisioningSource
+	signature string
+}
+
+func newS3Fetcher(src setting.ProvisioningSource) *s3Fetcher {
+	return &s3Fetcher{source: src}
+}
+
+func (f *s3Fetcher) fetch(ctx context.Context) (map[string][]byte, error) {
+	bucketURL, prefix, err := splitBucketURL(f.source.URL)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := blob.OpenBucket(ctx, bucketURL)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = bucket.Close() }()
+	if prefix != "" {
+		bucket = blob.PrefixedBucket(bucket, prefix)
+	}
+
+	var keys []string
+	hash := sha256.New()
+	iter := bucket.List(&blob.ListOptions{Delimiter: "/"})
+	for {
+		obj, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if obj.IsDir || !isConfigFile(obj.Key) {
+			continue
+		}
+		keys = append(keys, obj.Key)
+		_, _ = fmt.Fprintf(hash, "%s:%d:%d:%x\n", obj.Key, obj.ModTime.UnixNano(), obj.Size, obj.MD5)
+	}
+
+	signature := hex.EncodeToString(hash.Sum(nil))
+	if signature == f.signature {
+		return nil, errNotModified
+	}
+
+	files := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		data, err := bucket.ReadAll(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", key, err)
+		}
+		files[key] = data
+	}
+	f.signature = signature
+	return files, nil
+}
+
+// splitBucketURL splits a URL like s3://bucket/prefix?region=us-east-1 in the URL of the bucket and the prefix.
+func splitBucketURL(rawURL string) (string, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", err
+	}
+	prefix := strings.Trim(u.Path, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	u.Path = ""
+	return u.String(), prefix, nil
+}
@@ -0,0 +1,53 @@
+package secretresolver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+)
+
+// awsSecretsManagerResolver resolves the references like $__awssm{grafana/db} or $__awssm{grafana/db#password}
+// with AWS Secrets Manager. The secret string is returned, or the key after the # of the JSON secret string.
+type awsSecretsManagerResolver struct {
+	client *secretsmanager.SecretsManager
+}
+
+func newAWSSecretsManagerResolver(region string) (*awsSecretsManagerResolver, error) {
+	cfg := aws.NewConfig()
+	if region != "" {
+		cfg = cfg.WithRegion(region)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *cfg,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &awsSecretsManagerResolver{client: secretsmanager.New(sess)}, nil
+}
+
+func (r *awsSecretsManagerResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	name, key, hasKey := strings.Cut(ref, "#")
+	out, err := r.client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(name)})
+	if err != nil {
+		return "", err
+	}
+	if out.SecretString == nil {
+		return "", fmt.Errorf("the secret %s is binary, only secret strings are supported", name)
+	}
+	if !hasKey {
+		return *out.SecretString, nil
+	}
+
+	var secret map[string]any
+	if err := json.Unmarshal([]byte(*out.SecretString), &secret); err != nil {
+		return "", fmt.Errorf("the secret %s is not a JSON object: %w", name, err)
+	}
+	return secretKey(secret, key)
+}
@@ -0,0 +1,54 @@
+// Package secretresolver provides the resolvers of the references to the secrets of external secret managers
+// in the provisioning files.
+package secretresolver
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	vaultName = "vault"
+	awsSMName = "awssm"
+)
+
+// Register registers the resolvers enabled in the settings, and unregisters the others.
+func Register(cfg *setting.Cfg) error {
+	settings := cfg.ProvisioningSecrets
+
+	if settings.VaultAddress != "" {
+		values.RegisterSecretResolver(vaultName, newVaultResolver(settings.VaultAddress, settings.VaultToken, settings.VaultNamespace))
+	} else {
+		values.UnregisterSecretResolver(vaultName)
+	}
+
+	if settings.AWSSecretsManagerEnabled {
+		resolver, err := newAWSSecretsManagerResolver(settings.AWSSecretsManagerRegion)
+		if err != nil {
+			return fmt.Errorf("failed to create the AWS Secrets Manager resolver: %w", err)
+		}
+		values.RegisterSecretResolver(awsSMName, resolver)
+	} else {
+		values.UnregisterSecretResolver(awsSMName)
+	}
+	return nil
+}
+
+// secretKey returns the value of the key in the secret, the values which aren't strings are returned as JSON.
+func secretKey(secret map[string]any, key string) (string, error) {
+	value, ok := secret[key]
+	if !ok {
+		return "", fmt.Errorf("key %q not found in the secret", key)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,70 @@
+package secretresolver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// vaultResolver resolves the references like $__vault{kv/data/grafana#db_password} with the HTTP API of Vault.
+// The path is read with the token, and the key after the # is returned from the data of the secret, for
+// the version 1 and 2 of the KV secrets engine.
+type vaultResolver struct {
+	address   string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+func newVaultResolver(address, token, namespace string) *vaultResolver {
+	return &vaultResolver{
+		address:   strings.TrimSuffix(address, "/"),
+		token:     token,
+		namespace: namespace,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (r *vaultResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	path, key, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || key == "" {
+		return "", fmt.Errorf("the reference must look like <path>#<key>")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.address+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", r.token)
+	if r.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", r.namespace)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned status code %d for %s", resp.StatusCode, path)
+	}
+
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode the vault response: %w", err)
+	}
+
+	data := body.Data
+	// the version 2 of the KV secrets engine nests the secret in data, along its metadata
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+	return secretKey(data, key)
+}
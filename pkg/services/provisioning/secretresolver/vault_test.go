@@ -0,0 +1,56 @@
+package secretresolver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultResolver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/kv/data/grafana":
+			_, _ = w.Write([]byte(`{"data": {"data": {"db_password": "secret", "port": 5432}, "metadata": {"version": 3}}}`))
+		case "/v1/secret/grafana":
+			_, _ = w.Write([]byte(`{"data": {"db_password": "v1-secret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	resolver := newVaultResolver(server.URL+"/", "token", "")
+	ctx := context.Background()
+
+	secret, err := resolver.Resolve(ctx, "kv/data/grafana#db_password")
+	require.NoError(t, err)
+	require.Equal(t, "secret", secret)
+
+	secret, err = resolver.Resolve(ctx, "kv/data/grafana#port")
+	require.NoError(t, err)
+	require.Equal(t, "5432", secret)
+
+	secret, err = resolver.Resolve(ctx, "secret/grafana#db_password")
+	require.NoError(t, err)
+	require.Equal(t, "v1-secret", secret)
+
+	_, err = resolver.Resolve(ctx, "kv/data/grafana#missing")
+	require.ErrorContains(t, err, `key "missing" not found`)
+
+	_, err = resolver.Resolve(ctx, "kv/data/other#db_password")
+	require.ErrorContains(t, err, "status code 404")
+
+	_, err = resolver.Resolve(ctx, "kv/data/grafana")
+	require.Error(t, err)
+
+	_, err = newVaultResolver(server.URL, "wrong", "").Resolve(ctx, "kv/data/grafana#db_password")
+	require.ErrorContains(t, err, "status code 403")
+}
@@ -0,0 +1,138 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/provisioning/plan"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+// Status is the result of the last run of a provisioner.
+//
+// swagger:model ProvisioningStatus
+type Status struct {
+	Provisioner string    `json:"provisioner"`
+	LastRun     time.Time `json:"lastRun"`
+	DurationMs  int64     `json:"durationMs"`
+	Success     bool      `json:"success"`
+	// Resources are the resources created, updated or deleted by the run, without their diffs
+	Resources []plan.Change `json:"resources"`
+	Error     string        `json:"error,omitempty"`
+	// File and Line locate the error in the provisioning files, when it comes from one of them
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+}
+
+// New returns the status of a run of the provisioner started at start, which applied the changes of the plan
+// when it succeeded. The plan can be nil when it couldn't be computed.
+func New(provisioner string, start time.Time, p *plan.Plan, err error) Status {
+	s := Status{
+		Provisioner: provisioner,
+		LastRun:     start,
+		DurationMs:  time.Since(start).Milliseconds(),
+		Success:     err == nil,
+		Resources:   []plan.Change{},
+	}
+	if err != nil {
+		s.Error = err.Error()
+		var fileErr *utils.FileError
+		if errors.As(err, &fileErr) {
+			s.File = fileErr.File
+			s.Line = fileErr.Line
+		}
+		return s
+	}
+	if p != nil {
+		for _, change := range p.Changes {
+			change.Diff = ""
+			s.Resources = append(s.Resources, change)
+		}
+	}
+	return s
+}
+
+type statusRow struct {
+	ID          int64     `xorm:"pk autoincr 'id'"`
+	Provisioner string    `xorm:"provisioner"`
+	LastRun     time.Time `xorm:"last_run"`
+	DurationMs  int64     `xorm:"duration_ms"`
+	Success     bool      `xorm:"success"`
+	Resources   string    `xorm:"resources"`
+	Error       string    `xorm:"error"`
+	File        string    `xorm:"file"`
+	Line        int       `xorm:"line"`
+}
+
+func (statusRow) TableName() string {
+	return "provisioning_status"
+}
+
+// Store keeps the status of the last run of each provisioner in the database.
+type Store struct {
+	db db.DB
+}
+
+func NewStore(db db.DB) *Store {
+	return &Store{db: db}
+}
+
+// Save replaces the status of the provisioner.
+func (s *Store) Save(ctx context.Context, status Status) error {
+	resources, err := json.Marshal(status.Resources)
+	if err != nil {
+		return err
+	}
+	row := &statusRow{
+		Provisioner: status.Provisioner,
+		LastRun:     status.LastRun,
+		DurationMs:  status.DurationMs,
+		Success:     status.Success,
+		Resources:   string(resources),
+		Error:       status.Error,
+		File:        status.File,
+		Line:        status.Line,
+	}
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			if _, err := sess.Exec("DELETE FROM provisioning_status WHERE provisioner = ?", status.Provisioner); err != nil {
+				return err
+			}
+			_, err := sess.Insert(row)
+			return err
+		})
+	})
+}
+
+// List returns the status of the provisioners which ran, ordered by provisioner.
+func (s *Store) List(ctx context.Context) ([]Status, error) {
+	var rows []statusRow
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.OrderBy("provisioner").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(rows))
+	for _, row := range rows {
+		status := Status{
+			Provisioner: row.Provisioner,
+			LastRun:     row.LastRun,
+			DurationMs:  row.DurationMs,
+			Success:     row.Success,
+			Error:       row.Error,
+			File:        row.File,
+			Line:        row.Line,
+		}
+		if err := json.Unmarshal([]byte(row.Resources), &status.Resources); err != nil {
+			return nil, fmt.Errorf("failed to decode the resources of the %s provisioner: %w", row.Provisioner, err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
@@ -0,0 +1,73 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/provisioning/plan"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestNew(t *testing.T) {
+	start := time.Now()
+	p := plan.New("datasources")
+	p.Add(plan.Change{Action: plan.ActionUpdate, Kind: "datasource", OrgID: 1, Name: "Prometheus", Diff: "-a\n+b\n"})
+
+	s := New("datasources", start, p, nil)
+	require.True(t, s.Success)
+	require.Equal(t, start, s.LastRun)
+	require.Equal(t, []plan.Change{{Action: plan.ActionUpdate, Kind: "datasource", OrgID: 1, Name: "Prometheus"}}, s.Resources)
+
+	err := fmt.Errorf("Datasource provisioning error: %w", &utils.FileError{File: "datasources.yaml", Line: 3, Err: errors.New("yaml: line 3: did not find expected key")})
+	s = New("datasources", start, p, err)
+	require.False(t, s.Success)
+	require.Equal(t, "Datasource provisioning error: yaml: line 3: did not find expected key", s.Error)
+	require.Equal(t, "datasources.yaml", s.File)
+	require.Equal(t, 3, s.Line)
+	require.Empty(t, s.Resources, "the resources of failed runs shouldn't be recorded")
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	store := NewStore(db.InitTestDB(t))
+	ctx := context.Background()
+
+	statuses, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Empty(t, statuses)
+
+	lastRun := time.Now().Truncate(time.Second)
+	require.NoError(t, store.Save(ctx, Status{Provisioner: "plugins", LastRun: lastRun, Error: "failed", Resources: []plan.Change{}}))
+	require.NoError(t, store.Save(ctx, Status{Provisioner: "dashboards", LastRun: lastRun, Success: true, Resources: []plan.Change{}}))
+	require.NoError(t, store.Save(ctx, Status{
+		Provisioner: "plugins",
+		LastRun:     lastRun,
+		DurationMs:  12,
+		Success:     true,
+		Resources:   []plan.Change{{Action: plan.ActionCreate, Kind: "plugin", OrgID: 1, Name: "test-app"}},
+	}))
+
+	statuses, err = store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	require.Equal(t, "dashboards", statuses[0].Provisioner)
+	require.Equal(t, "plugins", statuses[1].Provisioner)
+	require.True(t, statuses[1].Success, "the status should be replaced")
+	require.Empty(t, statuses[1].Error)
+	require.Equal(t, int64(12), statuses[1].DurationMs)
+	require.True(t, lastRun.Equal(statuses[1].LastRun))
+	require.Equal(t, []plan.Change{{Action: plan.ActionCreate, Kind: "plugin", OrgID: 1, Name: "test-app"}}, statuses[1].Resources)
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+)
+
+// yamlLineRegexp matches the line of the YAML syntax and unmarshal errors, like "yaml: line 3: did not find expected key"
+var yamlLineRegexp = regexp.MustCompile(`\bline (\d+):`)
+
+// FileError is an error of a provisioning file, with the line of the error when it is known.
+type FileError struct {
+	File string
+	Line int
+	Err  error
+}
+
+// NewFileError wraps the error of the file, the line is read from the YAML errors. The error is returned
+// as it is when it is nil or already a FileError.
+func NewFileError(file string, err error) error {
+	var fileErr *FileError
+	if err == nil || errors.As(err, &fileErr) {
+		return err
+	}
+	fileErr = &FileError{File: file, Err: err}
+	if match := yamlLineRegexp.FindStringSubmatch(err.Error()); match != nil {
+		fileErr.Line, _ = strconv.Atoi(match[1])
+	}
+	return fileErr
+}
+
+func (e *FileError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
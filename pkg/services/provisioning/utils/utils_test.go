@@ -1 +1,26 @@
 package utils
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestNewFileError(t *testing.T) {
+	var v map[string]int
+	yamlErr := yaml.Unmarshal([]byte("a: 1\nb: c\n"), &v)
+	require.Error(t, yamlErr)
+
+	err := fmt.Errorf("failed: %w", NewFileError("datasources.yaml", yamlErr))
+	var fileErr *FileError
+	require.True(t, errors.As(err, &fileErr))
+	require.Equal(t, "datasources.yaml", fileErr.File)
+	require.Equal(t, 2, fileErr.Line)
+	require.Equal(t, yamlErr.Error(), fileErr.Error())
+
+	require.Equal(t, fileErr, NewFileError("other.yaml", fileErr), "the file of the error should be kept")
+	require.NoError(t, NewFileError("datasources.yaml", nil))
+}
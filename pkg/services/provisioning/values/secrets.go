@@ -0,0 +1,89 @@
+package values
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// secretResolveTimeout is the timeout of the resolution of a secret, the values are unmarshaled without context
+const secretResolveTimeout = 10 * time.Second
+
+// SecretResolver resolves the references to the secrets of an external secret manager. The values of the
+// provisioning files like $__<name>{<ref>} are replaced by the secret the resolver registered with the name
+// returns for ref.
+type SecretResolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+var (
+	secretResolversMu sync.RWMutex
+	secretResolvers   = map[string]SecretResolver{}
+)
+
+// RegisterSecretResolver registers the resolver of the $__<name>{<ref>} references, replacing the resolver
+// registered with the same name.
+func RegisterSecretResolver(name string, resolver SecretResolver) {
+	secretResolversMu.Lock()
+	defer secretResolversMu.Unlock()
+	secretResolvers[name] = resolver
+}
+
+// UnregisterSecretResolver removes the resolver registered with the name.
+func UnregisterSecretResolver(name string) {
+	secretResolversMu.Lock()
+	defer secretResolversMu.Unlock()
+	delete(secretResolvers, name)
+}
+
+// GetSecretResolver returns the resolver registered with the name. The secure settings of the data sources
+// reference the secrets of the same external secret managers as the provisioning files.
+func GetSecretResolver(name string) (SecretResolver, bool) {
+	secretResolversMu.RLock()
+	defer secretResolversMu.RUnlock()
+	resolver, ok := secretResolvers[name]
+	return resolver, ok
+}
+
+// expandWithSecrets expands s with expand, except the references to the secrets of the registered resolvers,
+// which are replaced by the secrets as they are, so the secrets aren't expanded like environment variables.
+// It returns whether s had references to secrets.
+func expandWithSecrets(s string, expand func(string) (string, error)) (string, bool, error) {
+	var b strings.Builder
+	hasSecrets := false
+	last := 0
+	for _, match := range setting.GetExpanderRegex().FindAllStringSubmatchIndex(s, -1) {
+		name := s[match[2]:match[3]]
+		resolver, ok := GetSecretResolver(strings.TrimPrefix(name, "__"))
+		if name == "" || !ok {
+			continue
+		}
+
+		expanded, err := expand(s[last:match[0]])
+		if err != nil {
+			return "", false, err
+		}
+		b.WriteString(expanded)
+
+		ctx, cancel := context.WithTimeout(context.Background(), secretResolveTimeout)
+		secret, err := resolver.Resolve(ctx, s[match[4]:match[5]])
+		cancel()
+		if err != nil {
+			return "", false, fmt.Errorf("failed to resolve secret %s: %w", s[match[0]:match[1]], err)
+		}
+		b.WriteString(secret)
+		hasSecrets = true
+		last = match[1]
+	}
+
+	expanded, err := expand(s[last:])
+	if err != nil {
+		return "", false, err
+	}
+	b.WriteString(expanded)
+	return b.String(), hasSecrets, nil
+}
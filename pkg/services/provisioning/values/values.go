@@ -1,5 +1,5 @@
 // Package values is a set of value types to use in provisioning. They add custom unmarshaling logic that puts the string values
-// through os.ExpandEnv.
+// through os.ExpandEnv, and resolves the references to the secrets of the registered secret resolvers.
 // Usage:
 //
 //	type Data struct {
@@ -286,12 +286,12 @@ func interpolateIfaceValue(val string) (interface{}, string, error) {
 	if len(parts) > 1 {
 		return interpolateValue(val)
 	}
-	expanded, err := setting.ExpandVar(val)
+	expandedEnv, hasSecrets, err := expandWithSecrets(val, expandVar)
 	if err != nil {
 		return val, val, fmt.Errorf("failed to interpolate value '%s': %w", val, err)
 	}
-	expandedEnv := os.ExpandEnv(expanded)
-	if expandedEnv != val {
+	// The secrets are kept as strings, even when they look like numbers
+	if expandedEnv != val && !hasSecrets {
 		// If the value is an environment variable, consider it may not be a string
 		intV, err := strconv.ParseInt(expandedEnv, 10, 64)
 		if err == nil {
@@ -310,22 +310,30 @@ func interpolateIfaceValue(val string) (interface{}, string, error) {
 }
 
 // interpolateValue returns the final value after interpolation. In addition to environment variable interpolation,
-// expanders available for the settings file are expanded here.
+// expanders available for the settings file and the registered secret resolvers are expanded here.
 // For a literal '$', '$$' can be used to avoid interpolation.
 func interpolateValue(val string) (string, string, error) {
 	parts := strings.Split(val, "$$")
 	interpolated := make([]string, len(parts))
 	for i, v := range parts {
-		expanded, err := setting.ExpandVar(v)
+		expanded, _, err := expandWithSecrets(v, expandVar)
 		if err != nil {
 			return val, val, fmt.Errorf("failed to interpolate value '%s': %w", val, err)
 		}
-		v = expanded
-		interpolated[i] = os.ExpandEnv(v)
+		interpolated[i] = expanded
 	}
 	return strings.Join(interpolated, "$"), val, nil
 }
 
+// expandVar expands the expanders available for the settings file and the environment variables.
+func expandVar(s string) (string, error) {
+	expanded, err := setting.ExpandVar(s)
+	if err != nil {
+		return "", err
+	}
+	return os.ExpandEnv(expanded), nil
+}
+
 type interpolated struct {
 	value string
 	raw   string
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/middleware/cookies"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route POST /public/dashboards/{accessToken}/request-access dashboard_public requestPublicDashboardAccess
+//
+//	Request access to a public dashboard with an access policy. A verification link is sent to the email if it is allowed.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) RequestPublicDashboardAccess(c *contextmodel.ReqContext) response.Response {
+	accessToken := web.Params(c.Req)[":accessToken"]
+	if !validation.IsValidAccessToken(accessToken) {
+		return response.Err(ErrInvalidAccessToken.Errorf("RequestPublicDashboardAccess: invalid access token"))
+	}
+
+	dto := RequestAccessDTO{}
+	if err := web.Bind(c.Req, &dto); err != nil {
+		return response.Err(ErrBadRequest.Errorf("RequestPublicDashboardAccess: bad request data %v", err))
+	}
+
+	if err := api.PublicDashboardService.RequestAccess(c.Req.Context(), accessToken, dto.Email); err != nil {
+		return response.Err(err)
+	}
+
+	return response.Success("If the email is allowed to view this dashboard, a verification link has been sent")
+}
+
+// swagger:route POST /public/dashboards/{accessToken}/verify dashboard_public verifyPublicDashboardAccess
+//
+//	Verify the code sent by email and start a viewer session for a public dashboard with an access policy
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) VerifyPublicDashboardAccess(c *contextmodel.ReqContext) response.Response {
+	accessToken := web.Params(c.Req)[":accessToken"]
+	if !validation.IsValidAccessToken(accessToken) {
+		return response.Err(ErrInvalidAccessToken.Errorf("VerifyPublicDashboardAccess: invalid access token"))
+	}
+
+	dto := VerifyAccessDTO{}
+	if err := web.Bind(c.Req, &dto); err != nil {
+		return response.Err(ErrBadRequest.Errorf("VerifyPublicDashboardAccess: bad request data %v", err))
+	}
+
+	session, err := api.PublicDashboardService.VerifyAccess(c.Req.Context(), accessToken, dto.Code)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	// the cookie is scoped to the api of this public dashboard only
+	cookies.WriteCookie(c.Resp, SessionCookieName, session.Token, int(time.Until(session.ExpiresAt).Seconds()), func() cookies.CookieOptions {
+		options := cookies.NewCookieOptions()
+		options.Path = api.cfg.AppSubURL + "/api/public/dashboards/" + accessToken
+		return options
+	})
+
+	return response.JSON(http.StatusOK, map[string]any{
+		"email":     session.Email,
+		"expiresAt": session.ExpiresAt,
+	})
+}
+
+// swagger:route GET /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/analytics dashboard_public getPublicDashboardAnalytics
+//
+//	Get view analytics of a public dashboard
+//
+// Responses:
+// 200: getPublicDashboardAnalyticsResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) GetPublicDashboardAnalytics(c *contextmodel.ReqContext) response.Response {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("GetPublicDashboardAnalytics: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("GetPublicDashboardAnalytics: invalid Uid %s", uid))
+	}
+
+	analytics, err := api.PublicDashboardService.GetAnalytics(c.Req.Context(), uid, dashboardUid)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, analytics)
+}
+
+// swagger:route POST /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/revoke dashboard_public revokePublicDashboard
+//
+//	Revoke access to a public dashboard. Without an email the access token is rotated and all viewer sessions
+//	are revoked. With an email only the sessions of that viewer are revoked.
+//
+// Responses:
+// 200: revokePublicDashboardResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) RevokePublicDashboard(c *contextmodel.ReqContext) response.Response {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("RevokePublicDashboard: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("RevokePublicDashboard: invalid Uid %s", uid))
+	}
+
+	dto := &RevokePublicDashboardDTO{}
+	if c.Req.ContentLength > 0 {
+		if err := web.Bind(c.Req, dto); err != nil {
+			return response.Err(ErrBadRequest.Errorf("RevokePublicDashboard: bad request data %v", err))
+		}
+	}
+
+	// Always set the orgID and userID from the session
+	dto.Uid = uid
+	dto.DashboardUid = dashboardUid
+	dto.OrgID = c.GetOrgID()
+	dto.UserId = c.UserID
+
+	pd, err := api.PublicDashboardService.Revoke(c.Req.Context(), c.SignedInUser, dto)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, pd)
+}
+
+// swagger:parameters requestPublicDashboardAccess
+type RequestPublicDashboardAccessParams struct {
+	// in:path
+	// required:true
+	AccessToken string `json:"accessToken"`
+	// in:body
+	// required:true
+	Body RequestAccessDTO
+}
+
+// swagger:parameters verifyPublicDashboardAccess
+type VerifyPublicDashboardAccessParams struct {
+	// in:path
+	// required:true
+	AccessToken string `json:"accessToken"`
+	// in:body
+	// required:true
+	Body VerifyAccessDTO
+}
+
+// swagger:parameters getPublicDashboardAnalytics
+type GetPublicDashboardAnalyticsParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+}
+
+// swagger:response getPublicDashboardAnalyticsResponse
+type GetPublicDashboardAnalyticsResponse struct {
+	// in: body
+	Body PublicDashboardAnalytics `json:"body"`
+}
+
+// swagger:parameters revokePublicDashboard
+type RevokePublicDashboardParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+	// in:body
+	Body RevokePublicDashboardDTO
+}
+
+// swagger:response revokePublicDashboardResponse
+type RevokePublicDashboardResponse struct {
+	// in: body
+	Body PublicDashboard `json:"body"`
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestAPIRequiresVerifiedEmail(t *testing.T) {
+	gatedPubdash := &PublicDashboard{Uid: "abc1234", AccessPolicy: &AccessPolicy{AllowedDomains: []string{"grafana.com"}}}
+	dashboardResult := &dtos.DashboardFullWithMeta{Dashboard: simplejson.New()}
+
+	t.Run("returns 401 when the viewer has no session", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("FindByAccessToken", mock.Anything, validAccessToken).Return(gatedPubdash, nil)
+		service.On("ValidateSession", mock.Anything, gatedPubdash, "").
+			Return("", ErrEmailVerificationRequired.Errorf(""))
+
+		testServer := setupTestServer(t, nil, service, anonymousUser)
+		response := callAPI(testServer, http.MethodGet, fmt.Sprintf("/api/public/dashboards/%s", validAccessToken), nil, t)
+
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+		service.AssertNotCalled(t, "GetPublicDashboardForView", mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns the dashboard and records the view with the verified email", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("FindByAccessToken", mock.Anything, validAccessToken).Return(gatedPubdash, nil)
+		service.On("ValidateSession", mock.Anything, gatedPubdash, "session-token").Return("viewer@grafana.com", nil)
+		service.On("GetPublicDashboardForView", mock.Anything, validAccessToken).Return(dashboardResult, nil)
+		service.On("RecordView", mock.Anything, validAccessToken, "viewer@grafana.com").Return(nil)
+
+		testServer := setupTestServer(t, nil, service, anonymousUser)
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/public/dashboards/%s", validAccessToken), nil)
+		require.NoError(t, err)
+		req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: "session-token"})
+		response := httptest.NewRecorder()
+		testServer.ServeHTTP(response, req)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("does not check the session when the public dashboard has no access policy", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("FindByAccessToken", mock.Anything, validAccessToken).Return(&PublicDashboard{Uid: "abc1234"}, nil)
+		service.On("GetPublicDashboardForView", mock.Anything, validAccessToken).Return(dashboardResult, nil)
+		service.On("RecordView", mock.Anything, validAccessToken, "").Return(nil)
+
+		testServer := setupTestServer(t, nil, service, anonymousUser)
+		response := callAPI(testServer, http.MethodGet, fmt.Sprintf("/api/public/dashboards/%s", validAccessToken), nil, t)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+		service.AssertNotCalled(t, "ValidateSession", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAPIVerifyPublicDashboardAccess(t *testing.T) {
+	t.Run("sets the session cookie scoped to the public dashboard", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("VerifyAccess", mock.Anything, validAccessToken, "the-code").Return(&PublicDashboardSessionToken{
+			Token:     "session-token",
+			Email:     "viewer@grafana.com",
+			ExpiresAt: time.Now().Add(time.Hour),
+		}, nil)
+
+		testServer := setupTestServer(t, nil, service, anonymousUser)
+		response := callAPI(testServer, http.MethodPost, fmt.Sprintf("/api/public/dashboards/%s/verify", validAccessToken), strings.NewReader(`{"code":"the-code"}`), t)
+
+		require.Equal(t, http.StatusOK, response.Code)
+		cookies := response.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, SessionCookieName, cookies[0].Name)
+		assert.Equal(t, "session-token", cookies[0].Value)
+		assert.Equal(t, "/api/public/dashboards/"+validAccessToken, cookies[0].Path)
+		assert.True(t, cookies[0].HttpOnly)
+	})
+
+	t.Run("returns 401 when the code is invalid", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("VerifyAccess", mock.Anything, validAccessToken, "the-code").Return(nil, ErrInvalidVerificationCode.Errorf(""))
+
+		testServer := setupTestServer(t, nil, service, anonymousUser)
+		response := callAPI(testServer, http.MethodPost, fmt.Sprintf("/api/public/dashboards/%s/verify", validAccessToken), strings.NewReader(`{"code":"the-code"}`), t)
+
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+		assert.Empty(t, response.Result().Cookies())
+	})
+}
+
+func TestAPIRequestPublicDashboardAccess(t *testing.T) {
+	service := publicdashboards.NewFakePublicDashboardService(t)
+	service.On("RequestAccess", mock.Anything, validAccessToken, "viewer@grafana.com").Return(nil)
+
+	testServer := setupTestServer(t, nil, service, anonymousUser)
+	response := callAPI(testServer, http.MethodPost, fmt.Sprintf("/api/public/dashboards/%s/request-access", validAccessToken), strings.NewReader(`{"email":"viewer@grafana.com"}`), t)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+}
+
+func TestAPIRevokePublicDashboard(t *testing.T) {
+	dashboardUid := "abc1234"
+	publicDashboardUid := "1234asdfasdf"
+	userEditorPublicDashboard := &user.SignedInUser{UserID: 4, OrgID: 1, OrgRole: org.RoleEditor, Login: "testEditorUser", Permissions: map[int64]map[string][]string{1: {dashboards.ActionDashboardsPublicWrite: {fmt.Sprintf("dashboards:uid:%s", dashboardUid)}}}}
+
+	testCases := []struct {
+		Name                 string
+		User                 *user.SignedInUser
+		Body                 string
+		ExpectedEmail        string
+		ExpectedHttpResponse int
+		ShouldCallService    bool
+	}{
+		{
+			Name:                 "User viewer cannot revoke public dashboard",
+			User:                 userViewer,
+			ExpectedHttpResponse: http.StatusForbidden,
+			ShouldCallService:    false,
+		},
+		{
+			Name:                 "User editor can rotate the access token",
+			User:                 userEditorPublicDashboard,
+			ExpectedHttpResponse: http.StatusOK,
+			ShouldCallService:    true,
+		},
+		{
+			Name:                 "User editor can revoke a single viewer",
+			User:                 userEditorPublicDashboard,
+			Body:                 `{"email":"viewer@grafana.com"}`,
+			ExpectedEmail:        "viewer@grafana.com",
+			ExpectedHttpResponse: http.StatusOK,
+			ShouldCallService:    true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			service := publicdashboards.NewFakePublicDashboardService(t)
+
+			if test.ShouldCallService {
+				service.On("Revoke", mock.Anything, mock.Anything, mock.MatchedBy(func(dto *RevokePublicDashboardDTO) bool {
+					return dto.Uid == publicDashboardUid && dto.DashboardUid == dashboardUid && dto.Email == test.ExpectedEmail
+				})).Return(&PublicDashboard{Uid: publicDashboardUid}, nil)
+			}
+
+			testServer := setupTestServer(t, nil, service, test.User)
+			response := callAPI(testServer, http.MethodPost,
+				fmt.Sprintf("/api/dashboards/uid/%s/public-dashboards/%s/revoke", dashboardUid, publicDashboardUid),
+				strings.NewReader(test.Body), t)
+
+			assert.Equal(t, test.ExpectedHttpResponse, response.Code)
+		})
+	}
+}
@@ -68,10 +68,16 @@ func (api *Api) RegisterAPIEndpoints() {
 	// Anonymous access to public dashboard route is configured in pkg/api/api.go
 	// because it is deeply dependent on the HTTPServer.Index() method and would result in a
 	// circular dependency
+	requiresVerifiedEmail := RequiresVerifiedEmail(api.PublicDashboardService)
+	rejectBannedClients := RejectBannedClients(api.PublicDashboardService, api.cfg)
 	api.routeRegister.Group("/api/public/dashboards/:accessToken", func(apiRoute routing.RouteRegister) {
-		apiRoute.Get("/", routing.Wrap(api.ViewPublicDashboard))
-		apiRoute.Get("/annotations", routing.Wrap(api.GetPublicAnnotations))
-		apiRoute.Post("/panels/:panelId/query", routing.Wrap(api.QueryPublicDashboard))
+		apiRoute.Get("/", rejectBannedClients, requiresVerifiedEmail, routing.Wrap(api.ViewPublicDashboard))
+		apiRoute.Get("/annotations", rejectBannedClients, requiresVerifiedEmail, routing.Wrap(api.GetPublicAnnotations))
+		apiRoute.Post("/panels/:panelId/query", requiresVerifiedEmail, routing.Wrap(api.QueryPublicDashboard))
+		apiRoute.Post("/request-access", routing.Wrap(api.RequestPublicDashboardAccess))
+		apiRoute.Post("/verify", routing.Wrap(api.VerifyPublicDashboardAccess))
+		apiRoute.Get("/challenge", rejectBannedClients, routing.Wrap(api.GetPublicDashboardChallenge))
+		apiRoute.Post("/challenge", rejectBannedClients, routing.Wrap(api.SolvePublicDashboardChallenge))
 	}, api.Middleware.HandleApi)
 
 	// Auth endpoints
@@ -99,6 +105,32 @@ func (api *Api) RegisterAPIEndpoints() {
 	api.routeRegister.Delete("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid",
 		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
 		routing.Wrap(api.DeletePublicDashboard))
+
+	// Get public dashboard view analytics
+	api.routeRegister.Get("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/analytics",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, uidScope)),
+		routing.Wrap(api.GetPublicDashboardAnalytics))
+
+	// Revoke public dashboard access
+	api.routeRegister.Post("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/revoke",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.RevokePublicDashboard))
+
+	// Get the query log of a public dashboard
+	api.routeRegister.Get("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/queries",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.GetPublicDashboardQueryLog))
+
+	// Manage the bans of a public dashboard
+	api.routeRegister.Get("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/bans",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.ListPublicDashboardBans))
+	api.routeRegister.Post("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/bans",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.CreatePublicDashboardBan))
+	api.routeRegister.Delete("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/bans/:banId",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.DeletePublicDashboardBan))
 }
 
 // swagger:route GET /dashboards/public-dashboards dashboard_public listPublicDashboards
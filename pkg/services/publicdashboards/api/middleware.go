@@ -1,15 +1,25 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ipallowlist"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 )
 
+// SessionCookieName is the cookie holding the session of a verified viewer of an email-gated public dashboard
+const SessionCookieName = "grafana_public_dashboard_session"
+
+type viewerEmailKey struct{}
+
 // SetPublicDashboardOrgIdOnContext Adds orgId to context based on org of public dashboard
 func SetPublicDashboardOrgIdOnContext(publicDashboardService publicdashboards.Service) func(c *contextmodel.ReqContext) {
 	return func(c *contextmodel.ReqContext) {
@@ -62,6 +72,63 @@ func RequiresExistingAccessToken(publicDashboardService publicdashboards.Service
 	}
 }
 
+// RequiresVerifiedEmail Middleware to enforce that viewers of a public dashboard with an access policy have verified
+// their email. The verified email is stored in the request context so views can be attributed to it.
+func RequiresVerifiedEmail(publicDashboardService publicdashboards.Service) func(c *contextmodel.ReqContext) {
+	return func(c *contextmodel.ReqContext) {
+		accessToken, ok := web.Params(c.Req)[":accessToken"]
+		if !ok || !validation.IsValidAccessToken(accessToken) {
+			// handlers respond with the proper error
+			return
+		}
+
+		pubdash, err := publicDashboardService.FindByAccessToken(c.Req.Context(), accessToken)
+		if err != nil || !pubdash.RequiresEmailVerification() {
+			return
+		}
+
+		email, err := publicDashboardService.ValidateSession(c.Req.Context(), pubdash, c.GetCookie(SessionCookieName))
+		if err != nil {
+			c.WriteErr(err)
+			return
+		}
+
+		c.Req = c.Req.WithContext(context.WithValue(c.Req.Context(), viewerEmailKey{}, email))
+	}
+}
+
+// RejectBannedClients Middleware to reject the clients banned from a public dashboard
+func RejectBannedClients(publicDashboardService publicdashboards.Service, cfg *setting.Cfg) func(c *contextmodel.ReqContext) {
+	return func(c *contextmodel.ReqContext) {
+		accessToken, ok := web.Params(c.Req)[":accessToken"]
+		if !ok || !validation.IsValidAccessToken(accessToken) {
+			// handlers respond with the proper error
+			return
+		}
+
+		err := publicDashboardService.CheckBan(c.Req.Context(), accessToken, clientIP(c, cfg))
+		if errors.Is(err, ErrClientBanned) {
+			c.WriteErr(err)
+		}
+	}
+}
+
+// clientIP returns the address the bans, rate limits and challenges of the client are keyed on. The forwarding
+// headers are only trusted from the proxies of security.ip_allowlist.trusted_proxies, any client can set them.
+func clientIP(c *contextmodel.ReqContext, cfg *setting.Cfg) string {
+	ip := ipallowlist.ClientIP(c.Req, cfg.IPAllowlist.TrustedProxies)
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
+
+// viewerEmail returns the verified email of the viewer, or an empty string for anonymous viewers
+func viewerEmail(c *contextmodel.ReqContext) string {
+	email, _ := c.Req.Context().Value(viewerEmailKey{}).(string)
+	return email
+}
+
 func CountPublicDashboardRequest() func(c *contextmodel.ReqContext) {
 	return func(c *contextmodel.ReqContext) {
 		metrics.MPublicDashboardRequestCount.Inc()
@@ -0,0 +1,315 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /public/dashboards/{accessToken}/challenge dashboard_public getPublicDashboardChallenge
+//
+//	Get the challenge anonymous viewers must solve when a public dashboard receives too many queries
+//
+// Responses:
+// 200: getPublicDashboardChallengeResponse
+// 400: badRequestPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) GetPublicDashboardChallenge(c *contextmodel.ReqContext) response.Response {
+	accessToken := web.Params(c.Req)[":accessToken"]
+	if !validation.IsValidAccessToken(accessToken) {
+		return response.Err(ErrInvalidAccessToken.Errorf("GetPublicDashboardChallenge: invalid access token"))
+	}
+
+	challenge, err := api.PublicDashboardService.GetChallenge(c.Req.Context(), accessToken)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, challenge)
+}
+
+// swagger:route POST /public/dashboards/{accessToken}/challenge dashboard_public solvePublicDashboardChallenge
+//
+//	Solve the challenge of a public dashboard. The viewer can query the public dashboard again.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) SolvePublicDashboardChallenge(c *contextmodel.ReqContext) response.Response {
+	accessToken := web.Params(c.Req)[":accessToken"]
+	if !validation.IsValidAccessToken(accessToken) {
+		return response.Err(ErrInvalidAccessToken.Errorf("SolvePublicDashboardChallenge: invalid access token"))
+	}
+
+	dto := SolveChallengeDTO{}
+	if err := web.Bind(c.Req, &dto); err != nil {
+		return response.Err(ErrBadRequest.Errorf("SolvePublicDashboardChallenge: bad request data %v", err))
+	}
+
+	if err := api.PublicDashboardService.SolveChallenge(c.Req.Context(), accessToken, clientIP(c, api.cfg), dto.Response); err != nil {
+		return response.Err(err)
+	}
+
+	return response.Success("Challenge solved")
+}
+
+// swagger:route GET /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/queries dashboard_public getPublicDashboardQueryLog
+//
+//	Get the queries run on a public dashboard, and the ones its protections rejected
+//
+// Responses:
+// 200: getPublicDashboardQueryLogResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) GetPublicDashboardQueryLog(c *contextmodel.ReqContext) response.Response {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("GetPublicDashboardQueryLog: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("GetPublicDashboardQueryLog: invalid Uid %s", uid))
+	}
+
+	perPage := c.QueryInt("perpage")
+	if perPage <= 0 {
+		perPage = 100
+	}
+	page := c.QueryInt("page")
+	if page < 1 {
+		page = 1
+	}
+
+	result, err := api.PublicDashboardService.FindQueryLog(c.Req.Context(), &PublicDashboardQueryLogQuery{
+		Uid:          uid,
+		DashboardUid: dashboardUid,
+		Status:       c.Query("status"),
+		ClientIp:     c.Query("clientIp"),
+		Page:         page,
+		Limit:        perPage,
+	})
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route GET /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/bans dashboard_public listPublicDashboardBans
+//
+//	Get the IP ranges banned from a public dashboard
+//
+// Responses:
+// 200: listPublicDashboardBansResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) ListPublicDashboardBans(c *contextmodel.ReqContext) response.Response {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("ListPublicDashboardBans: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("ListPublicDashboardBans: invalid Uid %s", uid))
+	}
+
+	bans, err := api.PublicDashboardService.FindBans(c.Req.Context(), uid, dashboardUid)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, bans)
+}
+
+// swagger:route POST /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/bans dashboard_public createPublicDashboardBan
+//
+//	Ban an IP range from a public dashboard
+//
+// Responses:
+// 200: createPublicDashboardBanResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) CreatePublicDashboardBan(c *contextmodel.ReqContext) response.Response {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("CreatePublicDashboardBan: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("CreatePublicDashboardBan: invalid Uid %s", uid))
+	}
+
+	dto := &CreatePublicDashboardBanDTO{}
+	if err := web.Bind(c.Req, dto); err != nil {
+		return response.Err(ErrBadRequest.Errorf("CreatePublicDashboardBan: bad request data %v", err))
+	}
+
+	// Always set the orgID and userID from the session
+	dto.Uid = uid
+	dto.DashboardUid = dashboardUid
+	dto.OrgID = c.GetOrgID()
+	dto.UserId = c.UserID
+
+	ban, err := api.PublicDashboardService.CreateBan(c.Req.Context(), c.SignedInUser, dto)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, ban)
+}
+
+// swagger:route DELETE /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/bans/{banId} dashboard_public deletePublicDashboardBan
+//
+//	Lift a ban of a public dashboard
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) DeletePublicDashboardBan(c *contextmodel.ReqContext) response.Response {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("DeletePublicDashboardBan: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("DeletePublicDashboardBan: invalid Uid %s", uid))
+	}
+
+	banId, err := strconv.ParseInt(web.Params(c.Req)[":banId"], 10, 64)
+	if err != nil {
+		return response.Err(ErrBadRequest.Errorf("DeletePublicDashboardBan: invalid ban id: %v", err))
+	}
+
+	if err := api.PublicDashboardService.DeleteBan(c.Req.Context(), uid, dashboardUid, banId); err != nil {
+		return response.Err(err)
+	}
+
+	return response.Success("Ban deleted")
+}
+
+// swagger:parameters getPublicDashboardChallenge
+type GetPublicDashboardChallengeParams struct {
+	// in:path
+	// required:true
+	AccessToken string `json:"accessToken"`
+}
+
+// swagger:response getPublicDashboardChallengeResponse
+type GetPublicDashboardChallengeResponse struct {
+	// in: body
+	Body PublicDashboardChallenge `json:"body"`
+}
+
+// swagger:parameters solvePublicDashboardChallenge
+type SolvePublicDashboardChallengeParams struct {
+	// in:path
+	// required:true
+	AccessToken string `json:"accessToken"`
+	// in:body
+	// required:true
+	Body SolveChallengeDTO
+}
+
+// swagger:parameters getPublicDashboardQueryLog
+type GetPublicDashboardQueryLogParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+	// in:query
+	// required:false
+	Page int `json:"page"`
+	// in:query
+	// required:false
+	PerPage int `json:"perpage"`
+	// Filter by status: success, failure, rate_limited, banned or challenge_required
+	// in:query
+	// required:false
+	Status string `json:"status"`
+	// in:query
+	// required:false
+	ClientIp string `json:"clientIp"`
+}
+
+// swagger:response getPublicDashboardQueryLogResponse
+type GetPublicDashboardQueryLogResponse struct {
+	// in: body
+	Body PublicDashboardQueryLogResult `json:"body"`
+}
+
+// swagger:parameters listPublicDashboardBans
+type ListPublicDashboardBansParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+}
+
+// swagger:response listPublicDashboardBansResponse
+type ListPublicDashboardBansResponse struct {
+	// in: body
+	Body []*PublicDashboardBan `json:"body"`
+}
+
+// swagger:parameters createPublicDashboardBan
+type CreatePublicDashboardBanParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+	// in:body
+	// required:true
+	Body CreatePublicDashboardBanDTO
+}
+
+// swagger:response createPublicDashboardBanResponse
+type CreatePublicDashboardBanResponse struct {
+	// in: body
+	Body PublicDashboardBan `json:"body"`
+}
+
+// swagger:parameters deletePublicDashboardBan
+type DeletePublicDashboardBanParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+	// in:path
+	// required:true
+	BanId int64 `json:"banId"`
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
@@ -36,6 +37,10 @@ func (api *Api) ViewPublicDashboard(c *contextmodel.ReqContext) response.Respons
 		return response.Err(err)
 	}
 
+	if err := api.PublicDashboardService.RecordView(c.Req.Context(), accessToken, viewerEmail(c)); err != nil {
+		api.log.Warn("Failed to record public dashboard view", "error", err)
+	}
+
 	return response.JSON(http.StatusOK, dto)
 }
 
@@ -50,6 +55,7 @@ func (api *Api) ViewPublicDashboard(c *contextmodel.ReqContext) response.Respons
 // 404: panelNotFoundPublicError
 // 404: notFoundPublicError
 // 403: forbiddenPublicError
+// 429: tooManyRequestsPublicError
 // 500: internalServerPublicError
 func (api *Api) QueryPublicDashboard(c *contextmodel.ReqContext) response.Response {
 	accessToken := web.Params(c.Req)[":accessToken"]
@@ -67,7 +73,26 @@ func (api *Api) QueryPublicDashboard(c *contextmodel.ReqContext) response.Respon
 		return response.Err(ErrBadRequest.Errorf("QueryPublicDashboard: error parsing request: %v", err))
 	}
 
+	client := QueryClient{IP: clientIP(c, api.cfg), Email: viewerEmail(c)}
+	if err := api.PublicDashboardService.AuthorizeQuery(c.Req.Context(), accessToken, panelId, client); err != nil {
+		return response.Err(err)
+	}
+
+	start := time.Now()
 	resp, err := api.PublicDashboardService.GetQueryDataResponse(c.Req.Context(), c.SkipDSCache, reqDTO, panelId, accessToken)
+	entry := &PublicDashboardQueryLog{
+		PanelId:    panelId,
+		ClientIp:   client.IP,
+		Email:      client.Email,
+		Status:     QuerySuccess,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		entry.Status = QueryFailure
+	}
+	if err := api.PublicDashboardService.RecordQuery(c.Req.Context(), accessToken, entry); err != nil {
+		api.log.Warn("Failed to record public dashboard query", "error", err)
+	}
 	if err != nil {
 		return response.Err(err)
 	}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
@@ -22,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -81,6 +84,12 @@ func TestAPIViewPublicDashboard(t *testing.T) {
 			service := publicdashboards.NewFakePublicDashboardService(t)
 			service.On("GetPublicDashboardForView", mock.Anything, mock.AnythingOfType("string")).
 				Return(test.DashboardResult, test.Err).Maybe()
+			service.On("FindByAccessToken", mock.Anything, mock.AnythingOfType("string")).
+				Return(&PublicDashboard{}, nil).Maybe()
+			service.On("RecordView", mock.Anything, mock.AnythingOfType("string"), "").
+				Return(nil).Maybe()
+			service.On("CheckBan", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+				Return(nil).Maybe()
 
 			testServer := setupTestServer(t, nil, service, anonymousUser)
 
@@ -171,6 +180,12 @@ func TestAPIQueryPublicDashboard(t *testing.T) {
 
 	setup := func(_ bool) (*web.Mux, *publicdashboards.FakePublicDashboardService) {
 		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("FindByAccessToken", mock.Anything, mock.AnythingOfType("string")).
+			Return(&PublicDashboard{}, nil).Maybe()
+		service.On("AuthorizeQuery", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("int64"), mock.Anything).
+			Return(nil).Maybe()
+		service.On("RecordQuery", mock.Anything, mock.AnythingOfType("string"), mock.Anything).
+			Return(nil).Maybe()
 		testServer := setupTestServer(t, nil, service, anonymousUser)
 
 		return testServer, service
@@ -227,6 +242,49 @@ func TestAPIQueryPublicDashboard(t *testing.T) {
 	})
 }
 
+func TestAPIPublicDashboardClientIP(t *testing.T) {
+	send := func(t *testing.T, server *web.Mux, method, path string) int {
+		req, err := http.NewRequest(method, path, strings.NewReader("{}"))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", "203.0.113.1")
+		req.Header.Set("X-Real-IP", "203.0.113.2")
+		req.RemoteAddr = "198.51.100.7:51234"
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	for _, tc := range []struct {
+		desc           string
+		trustedProxies []string
+		expectedIP     string
+	}{
+		{desc: "should ignore the forwarding headers sent by any client", expectedIP: "198.51.100.7"},
+		{desc: "should read the forwarding headers sent by the trusted proxies", trustedProxies: []string{"198.51.100.0/24"}, expectedIP: "203.0.113.1"},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			cfg := setting.NewCfg()
+			cfg.PublicDashboardsEnabled = true
+			for _, proxy := range tc.trustedProxies {
+				_, ipNet, err := net.ParseCIDR(proxy)
+				require.NoError(t, err)
+				cfg.IPAllowlist.TrustedProxies = append(cfg.IPAllowlist.TrustedProxies, ipNet)
+			}
+
+			service := publicdashboards.NewFakePublicDashboardService(t)
+			service.On("FindByAccessToken", mock.Anything, validAccessToken).Return(&PublicDashboard{}, nil).Maybe()
+			service.On("CheckBan", mock.Anything, validAccessToken, tc.expectedIP).Return(ErrClientBanned.Errorf("banned")).Once()
+			service.On("AuthorizeQuery", mock.Anything, validAccessToken, int64(2), QueryClient{IP: tc.expectedIP}).
+				Return(ErrQueryRateLimited.Errorf("rate limited")).Once()
+			server := setupTestServer(t, cfg, service, anonymousUser)
+
+			assert.Equal(t, http.StatusForbidden, send(t, server, http.MethodGet, "/api/public/dashboards/"+validAccessToken))
+			assert.Equal(t, http.StatusTooManyRequests, send(t, server, http.MethodPost, getValidQueryPath(validAccessToken)))
+		})
+	}
+}
+
 func getValidQueryPath(accessToken string) string {
 	return fmt.Sprintf("/api/public/dashboards/%s/panels/2/query", accessToken)
 }
@@ -276,6 +334,10 @@ func TestAPIGetAnnotations(t *testing.T) {
 	for _, test := range testCases {
 		t.Run(test.Name, func(t *testing.T) {
 			service := publicdashboards.NewFakePublicDashboardService(t)
+			service.On("FindByAccessToken", mock.Anything, mock.AnythingOfType("string")).
+				Return(&PublicDashboard{}, nil).Maybe()
+			service.On("CheckBan", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+				Return(nil).Maybe()
 
 			if test.ExpectedServiceCalled {
 				service.On("FindAnnotations", mock.Anything, mock.Anything, mock.AnythingOfType("string")).
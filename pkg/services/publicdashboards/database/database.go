@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -13,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/xorm"
 )
 
 // Define the storage implementation. We're generating the mock implementation
@@ -179,9 +181,9 @@ func (d *PublicDashboardStoreImpl) ExistsEnabledByDashboardUid(ctx context.Conte
 func (d *PublicDashboardStoreImpl) ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error) {
 	hasPublicDashboard := false
 	err := d.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
-		sql := "SELECT COUNT(*) FROM dashboard_public WHERE access_token=? AND is_enabled=true"
+		sql := "SELECT COUNT(*) FROM dashboard_public WHERE access_token=? AND is_enabled=true AND (expires_at IS NULL OR expires_at > ?)"
 
-		result, err := dbSession.SQL(sql, accessToken).Count()
+		result, err := dbSession.SQL(sql, accessToken, time.Now().UTC()).Count()
 		if err != nil {
 			return err
 		}
@@ -235,12 +237,38 @@ func (d *PublicDashboardStoreImpl) Update(ctx context.Context, cmd SavePublicDas
 			return err
 		}
 
-		sqlResult, err := sess.Exec("UPDATE dashboard_public SET is_enabled = ?, annotations_enabled = ?, time_selection_enabled = ?, share = ?, time_settings = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
+		var accessPolicyJSON any
+		if cmd.PublicDashboard.AccessPolicy != nil {
+			b, err := json.Marshal(cmd.PublicDashboard.AccessPolicy)
+			if err != nil {
+				return err
+			}
+			accessPolicyJSON = string(b)
+		}
+
+		var queryProtectionJSON any
+		if cmd.PublicDashboard.QueryProtection != nil {
+			b, err := json.Marshal(cmd.PublicDashboard.QueryProtection)
+			if err != nil {
+				return err
+			}
+			queryProtectionJSON = string(b)
+		}
+
+		var expiresAt any
+		if cmd.PublicDashboard.ExpiresAt != nil {
+			expiresAt = cmd.PublicDashboard.ExpiresAt.UTC()
+		}
+
+		sqlResult, err := sess.Exec("UPDATE dashboard_public SET is_enabled = ?, annotations_enabled = ?, time_selection_enabled = ?, share = ?, time_settings = ?, expires_at = ?, access_policy = ?, query_protection = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
 			cmd.PublicDashboard.IsEnabled,
 			cmd.PublicDashboard.AnnotationsEnabled,
 			cmd.PublicDashboard.TimeSelectionEnabled,
 			cmd.PublicDashboard.Share,
 			string(timeSettingsJSON),
+			expiresAt,
+			accessPolicyJSON,
+			queryProtectionJSON,
 			cmd.PublicDashboard.UpdatedBy,
 			cmd.PublicDashboard.UpdatedAt.UTC(),
 			cmd.PublicDashboard.Uid)
@@ -257,15 +285,23 @@ func (d *PublicDashboardStoreImpl) Update(ctx context.Context, cmd SavePublicDas
 	return affectedRows, err
 }
 
-// Delete deletes a public dashboard
+// Delete deletes a public dashboard along with its viewer sessions and view analytics
 func (d *PublicDashboardStoreImpl) Delete(ctx context.Context, uid string) (int64, error) {
 	dashboard := &PublicDashboard{Uid: uid}
 	var affectedRows int64
-	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		var err error
 		affectedRows, err = sess.Delete(dashboard)
+		if err != nil {
+			return err
+		}
 
-		return err
+		for _, table := range []string{"dashboard_public_session", "dashboard_public_view", "dashboard_public_query_log", "dashboard_public_ban"} {
+			if _, err := sess.Exec(fmt.Sprintf("DELETE FROM %s WHERE public_dashboard_uid = ?", table), uid); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
 
 	return affectedRows, err
@@ -277,19 +313,26 @@ func (d *PublicDashboardStoreImpl) DeleteByDashboardUIDs(ctx context.Context, or
 		return nil
 	}
 
-	return d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
-		s := strings.Builder{}
-		s.WriteString("DELETE FROM dashboard_public WHERE org_id = ? AND ")
-		s.WriteString(fmt.Sprintf("dashboard_uid IN (%s)", strings.Repeat("?,", len(dashboardUIDs)-1)+"?"))
-		sql := s.String()
-		args := make([]any, 0, len(dashboardUIDs)+2)
-		args = append(args, sql)
-		args = append(args, orgId)
+	return d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		inClause := fmt.Sprintf("dashboard_uid IN (%s)", strings.Repeat("?,", len(dashboardUIDs)-1)+"?")
+		params := make([]any, 0, len(dashboardUIDs)+1)
+		params = append(params, orgId)
 		for _, dashboardUID := range dashboardUIDs {
-			args = append(args, dashboardUID)
+			params = append(params, dashboardUID)
+		}
+
+		// clean up dependent rows first, they are looked up through the public dashboards being deleted
+		for _, table := range []string{"dashboard_public_session", "dashboard_public_view", "dashboard_public_query_log", "dashboard_public_ban"} {
+			sql := fmt.Sprintf("DELETE FROM %s WHERE public_dashboard_uid IN (SELECT uid FROM dashboard_public WHERE org_id = ? AND %s)", table, inClause)
+			if _, err := sess.Exec(append([]any{sql}, params...)...); err != nil {
+				return err
+			}
 		}
 
-		_, err := sess.Exec(args...)
+		s := strings.Builder{}
+		s.WriteString("DELETE FROM dashboard_public WHERE org_id = ? AND ")
+		s.WriteString(inClause)
+		_, err := sess.Exec(append([]any{s.String()}, params...)...)
 
 		return err
 	})
@@ -308,3 +351,222 @@ func (d *PublicDashboardStoreImpl) GetMetrics(ctx context.Context) (*Metrics, er
 
 	return metrics, nil
 }
+
+// UpdateAccessToken replaces the access token of a public dashboard
+func (d *PublicDashboardStoreImpl) UpdateAccessToken(ctx context.Context, uid string, accessToken string, updatedBy int64, updatedAt time.Time) (int64, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		sqlResult, err := sess.Exec("UPDATE dashboard_public SET access_token = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
+			accessToken, updatedBy, updatedAt.UTC(), uid)
+		if err != nil {
+			return err
+		}
+
+		affectedRows, err = sqlResult.RowsAffected()
+		return err
+	})
+
+	return affectedRows, err
+}
+
+// CreateSession stores a pending email verification for a public dashboard
+func (d *PublicDashboardStoreImpl) CreateSession(ctx context.Context, session *PublicDashboardSession) error {
+	return d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(session)
+		return err
+	})
+}
+
+// FindSessionByCodeHash returns the pending email verification matching the code hash or nil if not found
+func (d *PublicDashboardStoreImpl) FindSessionByCodeHash(ctx context.Context, publicDashboardUid string, codeHash string) (*PublicDashboardSession, error) {
+	return d.findSession(ctx, &PublicDashboardSession{PublicDashboardUid: publicDashboardUid, CodeHash: codeHash})
+}
+
+// FindSessionByTokenHash returns the verified session matching the token hash or nil if not found
+func (d *PublicDashboardStoreImpl) FindSessionByTokenHash(ctx context.Context, publicDashboardUid string, tokenHash string) (*PublicDashboardSession, error) {
+	return d.findSession(ctx, &PublicDashboardSession{PublicDashboardUid: publicDashboardUid, TokenHash: tokenHash})
+}
+
+func (d *PublicDashboardStoreImpl) findSession(ctx context.Context, query *PublicDashboardSession) (*PublicDashboardSession, error) {
+	var found bool
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		found, err = sess.Get(query)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return query, nil
+}
+
+// VerifySession marks a pending email verification as verified and attaches the session token to it
+func (d *PublicDashboardStoreImpl) VerifySession(ctx context.Context, id int64, tokenHash string, verifiedAt time.Time, expiresAt time.Time) (int64, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		sqlResult, err := sess.Exec("UPDATE dashboard_public_session SET token_hash = ?, verified_at = ?, expires_at = ? WHERE id = ? AND verified_at IS NULL",
+			tokenHash, verifiedAt.UTC(), expiresAt.UTC(), id)
+		if err != nil {
+			return err
+		}
+
+		affectedRows, err = sqlResult.RowsAffected()
+		return err
+	})
+
+	return affectedRows, err
+}
+
+// DeleteSessions deletes the viewer sessions of a public dashboard. If email is empty all sessions are deleted
+func (d *PublicDashboardStoreImpl) DeleteSessions(ctx context.Context, publicDashboardUid string, email string) (int64, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		q := sess.Where("public_dashboard_uid = ?", publicDashboardUid)
+		if email != "" {
+			q = q.And("email = ?", email)
+		}
+		affectedRows, err = q.Delete(&PublicDashboardSession{})
+		return err
+	})
+
+	return affectedRows, err
+}
+
+// DeleteExpiredSessions deletes all sessions and pending verifications that expired before the given time
+func (d *PublicDashboardStoreImpl) DeleteExpiredSessions(ctx context.Context, before time.Time) (int64, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		affectedRows, err = sess.Where("expires_at < ?", before.UTC()).Delete(&PublicDashboardSession{})
+		return err
+	})
+
+	return affectedRows, err
+}
+
+// CreateView records a view of a public dashboard
+func (d *PublicDashboardStoreImpl) CreateView(ctx context.Context, view *PublicDashboardView) error {
+	return d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(view)
+		return err
+	})
+}
+
+// GetAnalytics returns the view statistics of a public dashboard grouped by viewer
+func (d *PublicDashboardStoreImpl) GetAnalytics(ctx context.Context, publicDashboardUid string) (*PublicDashboardAnalytics, error) {
+	analytics := &PublicDashboardAnalytics{
+		Viewers: []*PublicDashboardViewerStat{},
+	}
+
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL("SELECT COALESCE(email, '') AS email, COUNT(*) AS views, MAX(viewed_at) AS last_viewed_at FROM dashboard_public_view WHERE public_dashboard_uid = ? GROUP BY email ORDER BY views DESC",
+			publicDashboardUid).Find(&analytics.Viewers)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, viewer := range analytics.Viewers {
+		analytics.TotalViews += viewer.Views
+		if viewer.Email != "" {
+			analytics.UniqueViewers++
+		}
+		if analytics.LastViewedAt == nil || viewer.LastViewedAt.After(*analytics.LastViewedAt) {
+			lastViewedAt := viewer.LastViewedAt
+			analytics.LastViewedAt = &lastViewedAt
+		}
+	}
+
+	return analytics, nil
+}
+
+// CreateQueryLog records a query run on a public dashboard
+func (d *PublicDashboardStoreImpl) CreateQueryLog(ctx context.Context, entry *PublicDashboardQueryLog) error {
+	return d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(entry)
+		return err
+	})
+}
+
+// FindQueryLog returns the recorded queries of a public dashboard, the most recent first
+func (d *PublicDashboardStoreImpl) FindQueryLog(ctx context.Context, query *PublicDashboardQueryLogQuery) (*PublicDashboardQueryLogResult, error) {
+	result := &PublicDashboardQueryLogResult{
+		Queries: make([]*PublicDashboardQueryLog, 0),
+		Page:    query.Page,
+		PerPage: query.Limit,
+	}
+
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		filter := func() *xorm.Session {
+			q := sess.Where("public_dashboard_uid = ?", query.Uid)
+			if query.Status != "" {
+				q = q.And("status = ?", query.Status)
+			}
+			if query.ClientIp != "" {
+				q = q.And("client_ip = ?", query.ClientIp)
+			}
+			return q
+		}
+
+		var err error
+		result.TotalCount, err = filter().Count(&PublicDashboardQueryLog{})
+		if err != nil {
+			return err
+		}
+		return filter().Desc("id").Limit(query.Limit, (query.Page-1)*query.Limit).Find(&result.Queries)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteQueryLogs deletes the queries recorded before the given time
+func (d *PublicDashboardStoreImpl) DeleteQueryLogs(ctx context.Context, before time.Time) (int64, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		affectedRows, err = sess.Where("queried_at < ?", before.UTC()).Delete(&PublicDashboardQueryLog{})
+		return err
+	})
+
+	return affectedRows, err
+}
+
+// FindBans returns the bans of a public dashboard, including the expired ones
+func (d *PublicDashboardStoreImpl) FindBans(ctx context.Context, publicDashboardUid string) ([]*PublicDashboardBan, error) {
+	bans := make([]*PublicDashboardBan, 0)
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("public_dashboard_uid = ?", publicDashboardUid).Asc("id").Find(&bans)
+	})
+
+	return bans, err
+}
+
+// CreateBan bans an IP range from a public dashboard
+func (d *PublicDashboardStoreImpl) CreateBan(ctx context.Context, ban *PublicDashboardBan) error {
+	return d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(ban)
+		return err
+	})
+}
+
+// DeleteBan deletes a ban of a public dashboard
+func (d *PublicDashboardStoreImpl) DeleteBan(ctx context.Context, publicDashboardUid string, id int64) (int64, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		affectedRows, err = sess.Where("public_dashboard_uid = ? AND id = ?", publicDashboardUid, id).Delete(&PublicDashboardBan{})
+		return err
+	})
+
+	return affectedRows, err
+}
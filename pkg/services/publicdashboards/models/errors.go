@@ -8,6 +8,7 @@ var (
 	ErrPublicDashboardNotFound = errutil.NotFound("publicdashboards.notFound", errutil.WithPublicMessage("Dashboard not found"))
 	ErrDashboardNotFound       = errutil.NotFound("publicdashboards.dashboardNotFound", errutil.WithPublicMessage("Dashboard not found"))
 	ErrPanelNotFound           = errutil.NotFound("publicdashboards.panelNotFound", errutil.WithPublicMessage("Dashboard panel not found"))
+	ErrBanNotFound             = errutil.NotFound("publicdashboards.banNotFound", errutil.WithPublicMessage("Ban not found"))
 
 	ErrBadRequest                          = errutil.BadRequest("publicdashboards.badRequest")
 	ErrPanelQueriesNotFound                = errutil.BadRequest("publicdashboards.panelQueriesNotFound", errutil.WithPublicMessage("Failed to extract queries from panel"))
@@ -23,6 +24,22 @@ var (
 	ErrDashboardIsPublic                   = errutil.BadRequest("publicdashboards.dashboardIsPublic", errutil.WithPublicMessage("Dashboard is already public"))
 	ErrPublicDashboardUidExists            = errutil.BadRequest("publicdashboards.uidExists", errutil.WithPublicMessage("Dashboard Uid already exists"))
 	ErrPublicDashboardAccessTokenExists    = errutil.BadRequest("publicdashboards.accessTokenExists", errutil.WithPublicMessage("Dashboard Access Token already exists"))
+	ErrInvalidExpiry                       = errutil.BadRequest("publicdashboards.invalidExpiry", errutil.WithPublicMessage("Expiry date must be in the future"))
+	ErrInvalidAccessPolicy                 = errutil.BadRequest("publicdashboards.invalidAccessPolicy", errutil.WithPublicMessage("Invalid access policy"))
+	ErrInvalidEmail                        = errutil.BadRequest("publicdashboards.invalidEmail", errutil.WithPublicMessage("Invalid email"))
+	ErrInvalidQueryProtection              = errutil.BadRequest("publicdashboards.invalidQueryProtection", errutil.WithPublicMessage("Invalid query protection"))
+	ErrInvalidBan                          = errutil.BadRequest("publicdashboards.invalidBan", errutil.WithPublicMessage("Invalid IP range"))
+	ErrChallengeDisabled                   = errutil.BadRequest("publicdashboards.challengeDisabled", errutil.WithPublicMessage("Challenges are disabled"))
+	ErrInvalidChallenge                    = errutil.BadRequest("publicdashboards.invalidChallenge", errutil.WithPublicMessage("Invalid challenge response"))
+
+	ErrEmailVerificationRequired = errutil.Unauthorized("publicdashboards.emailVerificationRequired", errutil.WithPublicMessage("Email verification required"))
+	ErrInvalidVerificationCode   = errutil.Unauthorized("publicdashboards.invalidVerificationCode", errutil.WithPublicMessage("Invalid or expired verification code"))
 
 	ErrPublicDashboardNotEnabled = errutil.Forbidden("publicdashboards.notEnabled", errutil.WithPublicMessage("Dashboard paused"))
+	ErrPublicDashboardExpired    = errutil.Forbidden("publicdashboards.expired", errutil.WithPublicMessage("Dashboard link expired"))
+	ErrEmailNotAllowed           = errutil.Forbidden("publicdashboards.emailNotAllowed", errutil.WithPublicMessage("Email is not allowed to access this dashboard"))
+	ErrClientBanned              = errutil.Forbidden("publicdashboards.clientBanned", errutil.WithPublicMessage("Access denied"))
+	ErrChallengeRequired         = errutil.Forbidden("publicdashboards.challengeRequired", errutil.WithPublicMessage("Challenge required"))
+
+	ErrQueryRateLimited = errutil.TooManyRequests("publicdashboards.queryRateLimited", errutil.WithPublicMessage("Too many queries, retry in a minute"))
 )
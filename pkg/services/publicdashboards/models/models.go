@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/kinds/dashboard"
@@ -26,6 +27,9 @@ func (e PublicDashboardErr) Error() string {
 const (
 	QuerySuccess                                  = "success"
 	QueryFailure                                  = "failure"
+	QueryRateLimited                              = "rate_limited"
+	QueryBanned                                   = "banned"
+	QueryChallengeRequired                        = "challenge_required"
 	EmailShareType                      ShareType = "email"
 	PublicShareType                     ShareType = "public"
 	FeaturePublicDashboardsEmailSharing           = "publicDashboardsEmailSharing"
@@ -54,6 +58,80 @@ type PublicDashboard struct {
 	AnnotationsEnabled   bool          `json:"annotationsEnabled" xorm:"annotations_enabled"`
 	Share                ShareType     `json:"share" xorm:"share"`
 	Recipients           []EmailDTO    `json:"recipients,omitempty" xorm:"-"`
+	ExpiresAt            *time.Time    `json:"expiresAt,omitempty" xorm:"expires_at"`
+	AccessPolicy         *AccessPolicy `json:"accessPolicy,omitempty" xorm:"access_policy"`
+	// QueryProtection overrides the limits of the server on the queries of the public dashboard
+	QueryProtection *QueryProtection `json:"queryProtection,omitempty" xorm:"query_protection"`
+}
+
+// IsExpired returns true if the public dashboard has an expiry date and it is in the past
+func (pd PublicDashboard) IsExpired(now time.Time) bool {
+	return pd.ExpiresAt != nil && !pd.ExpiresAt.IsZero() && !now.Before(*pd.ExpiresAt)
+}
+
+// RequiresEmailVerification returns true if viewers have to verify their email before accessing the public dashboard
+func (pd PublicDashboard) RequiresEmailVerification() bool {
+	return pd.AccessPolicy != nil && pd.AccessPolicy.IsRestricted()
+}
+
+// AccessPolicy restricts who can view a public dashboard. When it is set, viewers must verify an email address
+// that is either explicitly allowed or belongs to one of the allowed domains.
+type AccessPolicy struct {
+	AllowedEmails  []string `json:"allowedEmails,omitempty"`
+	AllowedDomains []string `json:"allowedDomains,omitempty"`
+}
+
+func (ap *AccessPolicy) FromDB(data []byte) error {
+	return json.Unmarshal(data, ap)
+}
+
+func (ap *AccessPolicy) ToDB() ([]byte, error) {
+	return json.Marshal(ap)
+}
+
+// IsRestricted returns true if the policy contains at least one allowed email or domain
+func (ap *AccessPolicy) IsRestricted() bool {
+	return len(ap.AllowedEmails) > 0 || len(ap.AllowedDomains) > 0
+}
+
+// IsEmailAllowed checks an email against the allowlist and the domain list. Comparison is case-insensitive.
+func (ap *AccessPolicy) IsEmailAllowed(email string) bool {
+	email = strings.ToLower(strings.TrimSpace(email))
+	for _, allowed := range ap.AllowedEmails {
+		if strings.ToLower(strings.TrimSpace(allowed)) == email {
+			return true
+		}
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := email[at+1:]
+	for _, allowed := range ap.AllowedDomains {
+		if strings.ToLower(strings.TrimPrefix(strings.TrimSpace(allowed), "@")) == domain {
+			return true
+		}
+	}
+	return false
+}
+
+// QueryProtection limits the queries run on a public dashboard. Zero uses the limit of the server and -1 disables
+// the limit.
+type QueryProtection struct {
+	// RateLimit is the number of queries per minute a client can run
+	RateLimit int64 `json:"rateLimit"`
+	// ChallengeThreshold is the number of queries per minute of all the anonymous viewers over which they must
+	// solve a challenge
+	ChallengeThreshold int64 `json:"challengeThreshold"`
+}
+
+func (qp *QueryProtection) FromDB(data []byte) error {
+	return json.Unmarshal(data, qp)
+}
+
+func (qp *QueryProtection) ToDB() ([]byte, error) {
+	return json.Marshal(qp)
 }
 
 type PublicDashboardDTO struct {
@@ -63,6 +141,10 @@ type PublicDashboardDTO struct {
 	IsEnabled            *bool     `json:"isEnabled"`
 	AnnotationsEnabled   *bool     `json:"annotationsEnabled"`
 	Share                ShareType `json:"share"`
+	// ExpiresAt is a unix timestamp in milliseconds. Zero removes the expiry
+	ExpiresAt       *int64           `json:"expiresAt"`
+	AccessPolicy    *AccessPolicy    `json:"accessPolicy"`
+	QueryProtection *QueryProtection `json:"queryProtection"`
 }
 
 type EmailDTO struct {
@@ -162,6 +244,157 @@ type AnnotationsQueryDTO struct {
 	To   int64
 }
 
+// PublicDashboardSession is an email verification for a public dashboard viewer. It is created with a
+// verification code when access is requested and turns into a session once the code is confirmed.
+type PublicDashboardSession struct {
+	Id                 int64      `xorm:"pk autoincr 'id'"`
+	PublicDashboardUid string     `xorm:"public_dashboard_uid"`
+	OrgId              int64      `xorm:"org_id"`
+	Email              string     `xorm:"email"`
+	CodeHash           string     `xorm:"code_hash"`
+	TokenHash          string     `xorm:"token_hash"`
+	CreatedAt          time.Time  `xorm:"created_at"`
+	ExpiresAt          time.Time  `xorm:"expires_at"`
+	VerifiedAt         *time.Time `xorm:"verified_at"`
+}
+
+func (s PublicDashboardSession) TableName() string {
+	return "dashboard_public_session"
+}
+
+// PublicDashboardSessionToken is returned to the viewer once the email is verified
+type PublicDashboardSessionToken struct {
+	Token     string
+	Email     string
+	ExpiresAt time.Time
+}
+
+// PublicDashboardView is a single view of a public dashboard
+type PublicDashboardView struct {
+	Id                 int64     `xorm:"pk autoincr 'id'"`
+	PublicDashboardUid string    `xorm:"public_dashboard_uid"`
+	OrgId              int64     `xorm:"org_id"`
+	Email              string    `xorm:"email"`
+	ViewedAt           time.Time `xorm:"viewed_at"`
+}
+
+func (v PublicDashboardView) TableName() string {
+	return "dashboard_public_view"
+}
+
+type PublicDashboardAnalytics struct {
+	TotalViews    int64                        `json:"totalViews"`
+	UniqueViewers int64                        `json:"uniqueViewers"`
+	LastViewedAt  *time.Time                   `json:"lastViewedAt,omitempty"`
+	Viewers       []*PublicDashboardViewerStat `json:"viewers"`
+}
+
+type PublicDashboardViewerStat struct {
+	// Email is empty for views of public dashboards that do not require email verification
+	Email        string    `json:"email" xorm:"email"`
+	Views        int64     `json:"views" xorm:"views"`
+	LastViewedAt time.Time `json:"lastViewedAt" xorm:"last_viewed_at"`
+}
+
+type RequestAccessDTO struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type VerifyAccessDTO struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type RevokePublicDashboardDTO struct {
+	Uid          string `json:"-"`
+	DashboardUid string `json:"-"`
+	OrgID        int64  `json:"-"`
+	UserId       int64  `json:"-"`
+	// Email revokes only the sessions of a single viewer. When empty, the access token is rotated
+	// and all viewer sessions are revoked.
+	Email string `json:"email"`
+}
+
+// QueryClient is the client running a query on a public dashboard
+type QueryClient struct {
+	IP string
+	// Email is empty for anonymous viewers
+	Email string
+}
+
+// PublicDashboardQueryLog is a query run on a public dashboard, or rejected by its protections
+type PublicDashboardQueryLog struct {
+	Id                 int64     `json:"id" xorm:"pk autoincr 'id'"`
+	PublicDashboardUid string    `json:"publicDashboardUid" xorm:"public_dashboard_uid"`
+	OrgId              int64     `json:"-" xorm:"org_id"`
+	PanelId            int64     `json:"panelId" xorm:"panel_id"`
+	ClientIp           string    `json:"clientIp" xorm:"client_ip"`
+	Email              string    `json:"email,omitempty" xorm:"email"`
+	Status             string    `json:"status" xorm:"status"`
+	DurationMs         int64     `json:"durationMs" xorm:"duration_ms"`
+	QueriedAt          time.Time `json:"queriedAt" xorm:"queried_at"`
+}
+
+func (l PublicDashboardQueryLog) TableName() string {
+	return "dashboard_public_query_log"
+}
+
+type PublicDashboardQueryLogQuery struct {
+	Uid          string
+	DashboardUid string
+	// Status filters the entries by status when set
+	Status   string
+	ClientIp string
+	Page     int
+	Limit    int
+}
+
+type PublicDashboardQueryLogResult struct {
+	Queries    []*PublicDashboardQueryLog `json:"queries"`
+	TotalCount int64                      `json:"totalCount"`
+	Page       int                        `json:"page"`
+	PerPage    int                        `json:"perPage"`
+}
+
+// PublicDashboardBan bans the clients of an IP range from a public dashboard
+type PublicDashboardBan struct {
+	Id                 int64  `json:"id" xorm:"pk autoincr 'id'"`
+	PublicDashboardUid string `json:"publicDashboardUid" xorm:"public_dashboard_uid"`
+	OrgId              int64  `json:"-" xorm:"org_id"`
+	// Cidr is the banned IP range. A single address is stored as a range of one address
+	Cidr      string     `json:"cidr" xorm:"cidr"`
+	Reason    string     `json:"reason" xorm:"reason"`
+	CreatedBy int64      `json:"createdBy" xorm:"created_by"`
+	CreatedAt time.Time  `json:"createdAt" xorm:"created_at"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" xorm:"expires_at"`
+}
+
+func (b PublicDashboardBan) TableName() string {
+	return "dashboard_public_ban"
+}
+
+type CreatePublicDashboardBanDTO struct {
+	Uid          string `json:"-"`
+	DashboardUid string `json:"-"`
+	OrgID        int64  `json:"-"`
+	UserId       int64  `json:"-"`
+	// Cidr is an IP range, such as 203.0.113.0/24, or an IP address
+	Cidr   string `json:"cidr" binding:"required"`
+	Reason string `json:"reason"`
+	// ExpiresAt is a unix timestamp in milliseconds. The ban never expires when it's empty
+	ExpiresAt *int64 `json:"expiresAt"`
+}
+
+// PublicDashboardChallenge is what viewers need to render the challenge of a public dashboard
+type PublicDashboardChallenge struct {
+	Provider string `json:"provider"`
+	SiteKey  string `json:"siteKey"`
+}
+
+type SolveChallengeDTO struct {
+	// Response is the response of the provider to the solved challenge
+	Response string `json:"response" binding:"required"`
+}
+
 //
 // COMMANDS
 //
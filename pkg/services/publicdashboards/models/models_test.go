@@ -2,6 +2,7 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -9,3 +10,34 @@ import (
 func TestPublicDashboardTableName(t *testing.T) {
 	assert.Equal(t, "dashboard_public", PublicDashboard{}.TableName())
 }
+
+func TestAccessPolicyIsEmailAllowed(t *testing.T) {
+	policy := &AccessPolicy{
+		AllowedEmails:  []string{"Viewer@Example.org"},
+		AllowedDomains: []string{"grafana.com", "@corp.example.com"},
+	}
+
+	assert.True(t, policy.IsEmailAllowed("viewer@example.org"))
+	assert.True(t, policy.IsEmailAllowed(" someone@grafana.com "))
+	assert.True(t, policy.IsEmailAllowed("someone@corp.example.com"))
+	assert.False(t, policy.IsEmailAllowed("other@example.org"))
+	assert.False(t, policy.IsEmailAllowed("someone@notgrafana.com"))
+	assert.False(t, policy.IsEmailAllowed("grafana.com"))
+}
+
+func TestPublicDashboardIsExpired(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	assert.False(t, PublicDashboard{}.IsExpired(now))
+	assert.False(t, PublicDashboard{ExpiresAt: &future}.IsExpired(now))
+	assert.True(t, PublicDashboard{ExpiresAt: &past}.IsExpired(now))
+	assert.True(t, PublicDashboard{ExpiresAt: &now}.IsExpired(now))
+}
+
+func TestPublicDashboardRequiresEmailVerification(t *testing.T) {
+	assert.False(t, PublicDashboard{}.RequiresEmailVerification())
+	assert.False(t, PublicDashboard{AccessPolicy: &AccessPolicy{}}.RequiresEmailVerification())
+	assert.True(t, PublicDashboard{AccessPolicy: &AccessPolicy{AllowedDomains: []string{"grafana.com"}}}.RequiresEmailVerification())
+}
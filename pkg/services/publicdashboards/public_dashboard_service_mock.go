@@ -23,6 +23,42 @@ type FakePublicDashboardService struct {
 	mock.Mock
 }
 
+// AuthorizeQuery provides a mock function with given fields: ctx, accessToken, panelId, client
+func (_m *FakePublicDashboardService) AuthorizeQuery(ctx context.Context, accessToken string, panelId int64, client models.QueryClient) error {
+	ret := _m.Called(ctx, accessToken, panelId, client)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AuthorizeQuery")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, models.QueryClient) error); ok {
+		r0 = rf(ctx, accessToken, panelId, client)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CheckBan provides a mock function with given fields: ctx, accessToken, clientIP
+func (_m *FakePublicDashboardService) CheckBan(ctx context.Context, accessToken string, clientIP string) error {
+	ret := _m.Called(ctx, accessToken, clientIP)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckBan")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, accessToken, clientIP)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Create provides a mock function with given fields: ctx, u, dto
 func (_m *FakePublicDashboardService) Create(ctx context.Context, u *user.SignedInUser, dto *models.SavePublicDashboardDTO) (*models.PublicDashboard, error) {
 	ret := _m.Called(ctx, u, dto)
@@ -53,6 +89,36 @@ func (_m *FakePublicDashboardService) Create(ctx context.Context, u *user.Signed
 	return r0, r1
 }
 
+// CreateBan provides a mock function with given fields: ctx, u, dto
+func (_m *FakePublicDashboardService) CreateBan(ctx context.Context, u *user.SignedInUser, dto *models.CreatePublicDashboardBanDTO) (*models.PublicDashboardBan, error) {
+	ret := _m.Called(ctx, u, dto)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateBan")
+	}
+
+	var r0 *models.PublicDashboardBan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, *models.CreatePublicDashboardBanDTO) (*models.PublicDashboardBan, error)); ok {
+		return rf(ctx, u, dto)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, *models.CreatePublicDashboardBanDTO) *models.PublicDashboardBan); ok {
+		r0 = rf(ctx, u, dto)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboardBan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, *models.CreatePublicDashboardBanDTO) error); ok {
+		r1 = rf(ctx, u, dto)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Delete provides a mock function with given fields: ctx, uid, dashboardUid
 func (_m *FakePublicDashboardService) Delete(ctx context.Context, uid string, dashboardUid string) error {
 	ret := _m.Called(ctx, uid, dashboardUid)
@@ -71,6 +137,80 @@ func (_m *FakePublicDashboardService) Delete(ctx context.Context, uid string, da
 	return r0
 }
 
+// DeleteBan provides a mock function with given fields: ctx, uid, dashboardUid, id
+func (_m *FakePublicDashboardService) DeleteBan(ctx context.Context, uid string, dashboardUid string, id int64) error {
+	ret := _m.Called(ctx, uid, dashboardUid, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteBan")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64) error); ok {
+		r0 = rf(ctx, uid, dashboardUid, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteExpiredSessions provides a mock function with given fields: ctx
+func (_m *FakePublicDashboardService) DeleteExpiredSessions(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredSessions")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteOldQueryLogs provides a mock function with given fields: ctx
+func (_m *FakePublicDashboardService) DeleteOldQueryLogs(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteOldQueryLogs")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ExistsEnabledByAccessToken provides a mock function with given fields: ctx, accessToken
 func (_m *FakePublicDashboardService) ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error) {
 	ret := _m.Called(ctx, accessToken)
@@ -217,6 +357,36 @@ func (_m *FakePublicDashboardService) FindAnnotations(ctx context.Context, reqDT
 	return r0, r1
 }
 
+// FindBans provides a mock function with given fields: ctx, uid, dashboardUid
+func (_m *FakePublicDashboardService) FindBans(ctx context.Context, uid string, dashboardUid string) ([]*models.PublicDashboardBan, error) {
+	ret := _m.Called(ctx, uid, dashboardUid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindBans")
+	}
+
+	var r0 []*models.PublicDashboardBan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]*models.PublicDashboardBan, error)); ok {
+		return rf(ctx, uid, dashboardUid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*models.PublicDashboardBan); ok {
+		r0 = rf(ctx, uid, dashboardUid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.PublicDashboardBan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, uid, dashboardUid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindByAccessToken provides a mock function with given fields: ctx, accessToken
 func (_m *FakePublicDashboardService) FindByAccessToken(ctx context.Context, accessToken string) (*models.PublicDashboard, error) {
 	ret := _m.Called(ctx, accessToken)
@@ -385,6 +555,96 @@ func (_m *FakePublicDashboardService) FindPublicDashboardAndDashboardByAccessTok
 	return r0, r1, r2
 }
 
+// FindQueryLog provides a mock function with given fields: ctx, query
+func (_m *FakePublicDashboardService) FindQueryLog(ctx context.Context, query *models.PublicDashboardQueryLogQuery) (*models.PublicDashboardQueryLogResult, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindQueryLog")
+	}
+
+	var r0 *models.PublicDashboardQueryLogResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PublicDashboardQueryLogQuery) (*models.PublicDashboardQueryLogResult, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PublicDashboardQueryLogQuery) *models.PublicDashboardQueryLogResult); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboardQueryLogResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.PublicDashboardQueryLogQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAnalytics provides a mock function with given fields: ctx, uid, dashboardUid
+func (_m *FakePublicDashboardService) GetAnalytics(ctx context.Context, uid string, dashboardUid string) (*models.PublicDashboardAnalytics, error) {
+	ret := _m.Called(ctx, uid, dashboardUid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAnalytics")
+	}
+
+	var r0 *models.PublicDashboardAnalytics
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.PublicDashboardAnalytics, error)); ok {
+		return rf(ctx, uid, dashboardUid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.PublicDashboardAnalytics); ok {
+		r0 = rf(ctx, uid, dashboardUid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboardAnalytics)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, uid, dashboardUid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetChallenge provides a mock function with given fields: ctx, accessToken
+func (_m *FakePublicDashboardService) GetChallenge(ctx context.Context, accessToken string) (*models.PublicDashboardChallenge, error) {
+	ret := _m.Called(ctx, accessToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChallenge")
+	}
+
+	var r0 *models.PublicDashboardChallenge
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.PublicDashboardChallenge, error)); ok {
+		return rf(ctx, accessToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.PublicDashboardChallenge); ok {
+		r0 = rf(ctx, accessToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboardChallenge)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, accessToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMetricRequest provides a mock function with given fields: ctx, dashboard, publicDashboard, panelId, reqDTO
 func (_m *FakePublicDashboardService) GetMetricRequest(ctx context.Context, dashboard *dashboards.Dashboard, publicDashboard *models.PublicDashboard, panelId int64, reqDTO models.PublicDashboardQueryDTO) (dtos.MetricRequest, error) {
 	ret := _m.Called(ctx, dashboard, publicDashboard, panelId, reqDTO)
@@ -557,6 +817,108 @@ func (_m *FakePublicDashboardService) NewPublicDashboardUid(ctx context.Context)
 	return r0, r1
 }
 
+// RecordQuery provides a mock function with given fields: ctx, accessToken, entry
+func (_m *FakePublicDashboardService) RecordQuery(ctx context.Context, accessToken string, entry *models.PublicDashboardQueryLog) error {
+	ret := _m.Called(ctx, accessToken, entry)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordQuery")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *models.PublicDashboardQueryLog) error); ok {
+		r0 = rf(ctx, accessToken, entry)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RecordView provides a mock function with given fields: ctx, accessToken, email
+func (_m *FakePublicDashboardService) RecordView(ctx context.Context, accessToken string, email string) error {
+	ret := _m.Called(ctx, accessToken, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordView")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, accessToken, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RequestAccess provides a mock function with given fields: ctx, accessToken, email
+func (_m *FakePublicDashboardService) RequestAccess(ctx context.Context, accessToken string, email string) error {
+	ret := _m.Called(ctx, accessToken, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequestAccess")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, accessToken, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Revoke provides a mock function with given fields: ctx, u, dto
+func (_m *FakePublicDashboardService) Revoke(ctx context.Context, u *user.SignedInUser, dto *models.RevokePublicDashboardDTO) (*models.PublicDashboard, error) {
+	ret := _m.Called(ctx, u, dto)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revoke")
+	}
+
+	var r0 *models.PublicDashboard
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, *models.RevokePublicDashboardDTO) (*models.PublicDashboard, error)); ok {
+		return rf(ctx, u, dto)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, *models.RevokePublicDashboardDTO) *models.PublicDashboard); ok {
+		r0 = rf(ctx, u, dto)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboard)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, *models.RevokePublicDashboardDTO) error); ok {
+		r1 = rf(ctx, u, dto)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SolveChallenge provides a mock function with given fields: ctx, accessToken, clientIP, response
+func (_m *FakePublicDashboardService) SolveChallenge(ctx context.Context, accessToken string, clientIP string, response string) error {
+	ret := _m.Called(ctx, accessToken, clientIP, response)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SolveChallenge")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, accessToken, clientIP, response)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, u, dto
 func (_m *FakePublicDashboardService) Update(ctx context.Context, u *user.SignedInUser, dto *models.SavePublicDashboardDTO) (*models.PublicDashboard, error) {
 	ret := _m.Called(ctx, u, dto)
@@ -587,6 +949,64 @@ func (_m *FakePublicDashboardService) Update(ctx context.Context, u *user.Signed
 	return r0, r1
 }
 
+// ValidateSession provides a mock function with given fields: ctx, pubdash, token
+func (_m *FakePublicDashboardService) ValidateSession(ctx context.Context, pubdash *models.PublicDashboard, token string) (string, error) {
+	ret := _m.Called(ctx, pubdash, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateSession")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PublicDashboard, string) (string, error)); ok {
+		return rf(ctx, pubdash, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PublicDashboard, string) string); ok {
+		r0 = rf(ctx, pubdash, token)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.PublicDashboard, string) error); ok {
+		r1 = rf(ctx, pubdash, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VerifyAccess provides a mock function with given fields: ctx, accessToken, code
+func (_m *FakePublicDashboardService) VerifyAccess(ctx context.Context, accessToken string, code string) (*models.PublicDashboardSessionToken, error) {
+	ret := _m.Called(ctx, accessToken, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyAccess")
+	}
+
+	var r0 *models.PublicDashboardSessionToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.PublicDashboardSessionToken, error)); ok {
+		return rf(ctx, accessToken, code)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.PublicDashboardSessionToken); ok {
+		r0 = rf(ctx, accessToken, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboardSessionToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, accessToken, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewFakePublicDashboardService creates a new instance of FakePublicDashboardService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewFakePublicDashboardService(t interface {
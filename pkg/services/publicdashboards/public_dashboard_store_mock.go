@@ -7,6 +7,8 @@ import (
 
 	models "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // FakePublicDashboardStore is an autogenerated mock type for the Store type
@@ -42,6 +44,78 @@ func (_m *FakePublicDashboardStore) Create(ctx context.Context, cmd models.SaveP
 	return r0, r1
 }
 
+// CreateBan provides a mock function with given fields: ctx, ban
+func (_m *FakePublicDashboardStore) CreateBan(ctx context.Context, ban *models.PublicDashboardBan) error {
+	ret := _m.Called(ctx, ban)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateBan")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PublicDashboardBan) error); ok {
+		r0 = rf(ctx, ban)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateQueryLog provides a mock function with given fields: ctx, entry
+func (_m *FakePublicDashboardStore) CreateQueryLog(ctx context.Context, entry *models.PublicDashboardQueryLog) error {
+	ret := _m.Called(ctx, entry)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateQueryLog")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PublicDashboardQueryLog) error); ok {
+		r0 = rf(ctx, entry)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateSession provides a mock function with given fields: ctx, session
+func (_m *FakePublicDashboardStore) CreateSession(ctx context.Context, session *models.PublicDashboardSession) error {
+	ret := _m.Called(ctx, session)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PublicDashboardSession) error); ok {
+		r0 = rf(ctx, session)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateView provides a mock function with given fields: ctx, view
+func (_m *FakePublicDashboardStore) CreateView(ctx context.Context, view *models.PublicDashboardView) error {
+	ret := _m.Called(ctx, view)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateView")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PublicDashboardView) error); ok {
+		r0 = rf(ctx, view)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Delete provides a mock function with given fields: ctx, uid
 func (_m *FakePublicDashboardStore) Delete(ctx context.Context, uid string) (int64, error) {
 	ret := _m.Called(ctx, uid)
@@ -70,6 +144,34 @@ func (_m *FakePublicDashboardStore) Delete(ctx context.Context, uid string) (int
 	return r0, r1
 }
 
+// DeleteBan provides a mock function with given fields: ctx, publicDashboardUid, id
+func (_m *FakePublicDashboardStore) DeleteBan(ctx context.Context, publicDashboardUid string, id int64) (int64, error) {
+	ret := _m.Called(ctx, publicDashboardUid, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteBan")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) (int64, error)); ok {
+		return rf(ctx, publicDashboardUid, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) int64); ok {
+		r0 = rf(ctx, publicDashboardUid, id)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = rf(ctx, publicDashboardUid, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteByDashboardUIDs provides a mock function with given fields: ctx, orgId, dashboardUIDs
 func (_m *FakePublicDashboardStore) DeleteByDashboardUIDs(ctx context.Context, orgId int64, dashboardUIDs []string) error {
 	ret := _m.Called(ctx, orgId, dashboardUIDs)
@@ -88,6 +190,90 @@ func (_m *FakePublicDashboardStore) DeleteByDashboardUIDs(ctx context.Context, o
 	return r0
 }
 
+// DeleteExpiredSessions provides a mock function with given fields: ctx, before
+func (_m *FakePublicDashboardStore) DeleteExpiredSessions(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredSessions")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteQueryLogs provides a mock function with given fields: ctx, before
+func (_m *FakePublicDashboardStore) DeleteQueryLogs(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteQueryLogs")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteSessions provides a mock function with given fields: ctx, publicDashboardUid, email
+func (_m *FakePublicDashboardStore) DeleteSessions(ctx context.Context, publicDashboardUid string, email string) (int64, error) {
+	ret := _m.Called(ctx, publicDashboardUid, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSessions")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (int64, error)); ok {
+		return rf(ctx, publicDashboardUid, email)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int64); ok {
+		r0 = rf(ctx, publicDashboardUid, email)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, publicDashboardUid, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ExistsEnabledByAccessToken provides a mock function with given fields: ctx, accessToken
 func (_m *FakePublicDashboardStore) ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error) {
 	ret := _m.Called(ctx, accessToken)
@@ -204,6 +390,36 @@ func (_m *FakePublicDashboardStore) FindAll(ctx context.Context, query *models.P
 	return r0, r1
 }
 
+// FindBans provides a mock function with given fields: ctx, publicDashboardUid
+func (_m *FakePublicDashboardStore) FindBans(ctx context.Context, publicDashboardUid string) ([]*models.PublicDashboardBan, error) {
+	ret := _m.Called(ctx, publicDashboardUid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindBans")
+	}
+
+	var r0 []*models.PublicDashboardBan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*models.PublicDashboardBan, error)); ok {
+		return rf(ctx, publicDashboardUid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*models.PublicDashboardBan); ok {
+		r0 = rf(ctx, publicDashboardUid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.PublicDashboardBan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, publicDashboardUid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindByAccessToken provides a mock function with given fields: ctx, accessToken
 func (_m *FakePublicDashboardStore) FindByAccessToken(ctx context.Context, accessToken string) (*models.PublicDashboard, error) {
 	ret := _m.Called(ctx, accessToken)
@@ -264,6 +480,126 @@ func (_m *FakePublicDashboardStore) FindByDashboardUid(ctx context.Context, orgI
 	return r0, r1
 }
 
+// FindQueryLog provides a mock function with given fields: ctx, query
+func (_m *FakePublicDashboardStore) FindQueryLog(ctx context.Context, query *models.PublicDashboardQueryLogQuery) (*models.PublicDashboardQueryLogResult, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindQueryLog")
+	}
+
+	var r0 *models.PublicDashboardQueryLogResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PublicDashboardQueryLogQuery) (*models.PublicDashboardQueryLogResult, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PublicDashboardQueryLogQuery) *models.PublicDashboardQueryLogResult); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboardQueryLogResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.PublicDashboardQueryLogQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindSessionByCodeHash provides a mock function with given fields: ctx, publicDashboardUid, codeHash
+func (_m *FakePublicDashboardStore) FindSessionByCodeHash(ctx context.Context, publicDashboardUid string, codeHash string) (*models.PublicDashboardSession, error) {
+	ret := _m.Called(ctx, publicDashboardUid, codeHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindSessionByCodeHash")
+	}
+
+	var r0 *models.PublicDashboardSession
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.PublicDashboardSession, error)); ok {
+		return rf(ctx, publicDashboardUid, codeHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.PublicDashboardSession); ok {
+		r0 = rf(ctx, publicDashboardUid, codeHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboardSession)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, publicDashboardUid, codeHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindSessionByTokenHash provides a mock function with given fields: ctx, publicDashboardUid, tokenHash
+func (_m *FakePublicDashboardStore) FindSessionByTokenHash(ctx context.Context, publicDashboardUid string, tokenHash string) (*models.PublicDashboardSession, error) {
+	ret := _m.Called(ctx, publicDashboardUid, tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindSessionByTokenHash")
+	}
+
+	var r0 *models.PublicDashboardSession
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.PublicDashboardSession, error)); ok {
+		return rf(ctx, publicDashboardUid, tokenHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.PublicDashboardSession); ok {
+		r0 = rf(ctx, publicDashboardUid, tokenHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboardSession)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, publicDashboardUid, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAnalytics provides a mock function with given fields: ctx, publicDashboardUid
+func (_m *FakePublicDashboardStore) GetAnalytics(ctx context.Context, publicDashboardUid string) (*models.PublicDashboardAnalytics, error) {
+	ret := _m.Called(ctx, publicDashboardUid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAnalytics")
+	}
+
+	var r0 *models.PublicDashboardAnalytics
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.PublicDashboardAnalytics, error)); ok {
+		return rf(ctx, publicDashboardUid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.PublicDashboardAnalytics); ok {
+		r0 = rf(ctx, publicDashboardUid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboardAnalytics)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, publicDashboardUid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMetrics provides a mock function with given fields: ctx
 func (_m *FakePublicDashboardStore) GetMetrics(ctx context.Context) (*models.Metrics, error) {
 	ret := _m.Called(ctx)
@@ -350,6 +686,62 @@ func (_m *FakePublicDashboardStore) Update(ctx context.Context, cmd models.SaveP
 	return r0, r1
 }
 
+// UpdateAccessToken provides a mock function with given fields: ctx, uid, accessToken, updatedBy, updatedAt
+func (_m *FakePublicDashboardStore) UpdateAccessToken(ctx context.Context, uid string, accessToken string, updatedBy int64, updatedAt time.Time) (int64, error) {
+	ret := _m.Called(ctx, uid, accessToken, updatedBy, updatedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateAccessToken")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64, time.Time) (int64, error)); ok {
+		return rf(ctx, uid, accessToken, updatedBy, updatedAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64, time.Time) int64); ok {
+		r0 = rf(ctx, uid, accessToken, updatedBy, updatedAt)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int64, time.Time) error); ok {
+		r1 = rf(ctx, uid, accessToken, updatedBy, updatedAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VerifySession provides a mock function with given fields: ctx, id, tokenHash, verifiedAt, expiresAt
+func (_m *FakePublicDashboardStore) VerifySession(ctx context.Context, id int64, tokenHash string, verifiedAt time.Time, expiresAt time.Time) (int64, error) {
+	ret := _m.Called(ctx, id, tokenHash, verifiedAt, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifySession")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, time.Time, time.Time) (int64, error)); ok {
+		return rf(ctx, id, tokenHash, verifiedAt, expiresAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, time.Time, time.Time) int64); ok {
+		r0 = rf(ctx, id, tokenHash, verifiedAt, expiresAt)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, id, tokenHash, verifiedAt, expiresAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewFakePublicDashboardStore creates a new instance of FakePublicDashboardStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewFakePublicDashboardStore(t interface {
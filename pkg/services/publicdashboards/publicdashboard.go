@@ -2,6 +2,7 @@ package publicdashboards
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/api/dtos"
@@ -37,6 +38,25 @@ type Service interface {
 
 	ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error)
 	ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error)
+
+	RequestAccess(ctx context.Context, accessToken string, email string) error
+	VerifyAccess(ctx context.Context, accessToken string, code string) (*PublicDashboardSessionToken, error)
+	ValidateSession(ctx context.Context, pubdash *PublicDashboard, token string) (string, error)
+	DeleteExpiredSessions(ctx context.Context) (int64, error)
+	RecordView(ctx context.Context, accessToken string, email string) error
+	GetAnalytics(ctx context.Context, uid string, dashboardUid string) (*PublicDashboardAnalytics, error)
+	Revoke(ctx context.Context, u *user.SignedInUser, dto *RevokePublicDashboardDTO) (*PublicDashboard, error)
+
+	CheckBan(ctx context.Context, accessToken string, clientIP string) error
+	AuthorizeQuery(ctx context.Context, accessToken string, panelId int64, client QueryClient) error
+	RecordQuery(ctx context.Context, accessToken string, entry *PublicDashboardQueryLog) error
+	FindQueryLog(ctx context.Context, query *PublicDashboardQueryLogQuery) (*PublicDashboardQueryLogResult, error)
+	DeleteOldQueryLogs(ctx context.Context) (int64, error)
+	GetChallenge(ctx context.Context, accessToken string) (*PublicDashboardChallenge, error)
+	SolveChallenge(ctx context.Context, accessToken string, clientIP string, response string) error
+	FindBans(ctx context.Context, uid string, dashboardUid string) ([]*PublicDashboardBan, error)
+	CreateBan(ctx context.Context, u *user.SignedInUser, dto *CreatePublicDashboardBanDTO) (*PublicDashboardBan, error)
+	DeleteBan(ctx context.Context, uid string, dashboardUid string, id int64) error
 }
 
 // ServiceWrapper these methods have different behavior between OSS and Enterprise. The latter would call the OSS service first
@@ -63,6 +83,32 @@ type Store interface {
 	ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error)
 	ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error)
 	GetMetrics(ctx context.Context) (*Metrics, error)
+	UpdateAccessToken(ctx context.Context, uid string, accessToken string, updatedBy int64, updatedAt time.Time) (int64, error)
+
+	CreateSession(ctx context.Context, session *PublicDashboardSession) error
+	FindSessionByCodeHash(ctx context.Context, publicDashboardUid string, codeHash string) (*PublicDashboardSession, error)
+	FindSessionByTokenHash(ctx context.Context, publicDashboardUid string, tokenHash string) (*PublicDashboardSession, error)
+	VerifySession(ctx context.Context, id int64, tokenHash string, verifiedAt time.Time, expiresAt time.Time) (int64, error)
+	DeleteSessions(ctx context.Context, publicDashboardUid string, email string) (int64, error)
+	DeleteExpiredSessions(ctx context.Context, before time.Time) (int64, error)
+
+	CreateView(ctx context.Context, view *PublicDashboardView) error
+	GetAnalytics(ctx context.Context, publicDashboardUid string) (*PublicDashboardAnalytics, error)
+
+	CreateQueryLog(ctx context.Context, entry *PublicDashboardQueryLog) error
+	FindQueryLog(ctx context.Context, query *PublicDashboardQueryLogQuery) (*PublicDashboardQueryLogResult, error)
+	DeleteQueryLogs(ctx context.Context, before time.Time) (int64, error)
+	FindBans(ctx context.Context, publicDashboardUid string) ([]*PublicDashboardBan, error)
+	CreateBan(ctx context.Context, ban *PublicDashboardBan) error
+	DeleteBan(ctx context.Context, publicDashboardUid string, id int64) (int64, error)
+}
+
+// ChallengeVerifier verifies the responses to the challenges, such as CAPTCHAs, anonymous viewers solve when the
+// queries of a public dashboard burst
+type ChallengeVerifier interface {
+	// Provider is the name of the provider of the challenges, returned to the viewers to render them
+	Provider() string
+	Verify(ctx context.Context, response string, clientIP string) (bool, error)
 }
 
 //go:generate mockery --name Middleware --structname FakePublicDashboardMiddleware --inpackage --filename public_dashboard_middleware_mock.go
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/notifications"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const tmplVerifyEmail = "public_dashboard_verify_email"
+
+// RequestAccess sends a verification link to the viewer if the email is allowed by the access policy of the
+// public dashboard. Requests for emails that are not allowed succeed without sending anything, so the
+// allowlist cannot be enumerated.
+func (pd *PublicDashboardServiceImpl) RequestAccess(ctx context.Context, accessToken string, email string) error {
+	ctx, span := tracer.Start(ctx, "publicdashboards.RequestAccess")
+	defer span.End()
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !util.IsEmail(email) {
+		return ErrInvalidEmail.Errorf("RequestAccess: invalid email")
+	}
+
+	pubdash, dash, err := pd.FindEnabledPublicDashboardAndDashboardByAccessToken(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	if !pubdash.RequiresEmailVerification() {
+		return ErrBadRequest.Errorf("RequestAccess: public dashboard %s does not require email verification", pubdash.Uid)
+	}
+
+	if !pubdash.AccessPolicy.IsEmailAllowed(email) {
+		pd.log.Info("Public dashboard access requested by email not in access policy", "publicDashboardUid", pubdash.Uid)
+		return nil
+	}
+
+	code, err := util.GetRandomString(32)
+	if err != nil {
+		return ErrInternalServerError.Errorf("RequestAccess: failed to generate verification code: %w", err)
+	}
+
+	now := time.Now()
+	err = pd.store.CreateSession(ctx, &PublicDashboardSession{
+		PublicDashboardUid: pubdash.Uid,
+		OrgId:              pubdash.OrgId,
+		Email:              email,
+		CodeHash:           pd.hashSecret(code),
+		CreatedAt:          now,
+		ExpiresAt:          now.Add(pd.cfg.PublicDashboardsEmailVerificationCodeLifetime),
+	})
+	if err != nil {
+		return ErrInternalServerError.Errorf("RequestAccess: failed to store verification: %w", err)
+	}
+
+	err = pd.notificationService.SendEmailCommandHandler(ctx, &notifications.SendEmailCommand{
+		To:       []string{email},
+		Template: tmplVerifyEmail,
+		Data: map[string]any{
+			"DashboardTitle":   dash.Title,
+			"AccessToken":      pubdash.AccessToken,
+			"Code":             code,
+			"ExpiresInMinutes": int64(pd.cfg.PublicDashboardsEmailVerificationCodeLifetime.Minutes()),
+		},
+	})
+	if err != nil {
+		return ErrInternalServerError.Errorf("RequestAccess: failed to send verification email: %w", err)
+	}
+
+	return nil
+}
+
+// VerifyAccess exchanges a verification code for a session token. A code can only be used once.
+func (pd *PublicDashboardServiceImpl) VerifyAccess(ctx context.Context, accessToken string, code string) (*PublicDashboardSessionToken, error) {
+	ctx, span := tracer.Start(ctx, "publicdashboards.VerifyAccess")
+	defer span.End()
+
+	pubdash, _, err := pd.FindEnabledPublicDashboardAndDashboardByAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := pd.store.FindSessionByCodeHash(ctx, pubdash.Uid, pd.hashSecret(code))
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("VerifyAccess: failed to find verification: %w", err)
+	}
+
+	now := time.Now()
+	if session == nil || session.VerifiedAt != nil || !now.Before(session.ExpiresAt) {
+		return nil, ErrInvalidVerificationCode.Errorf("VerifyAccess: invalid verification code for public dashboard %s", pubdash.Uid)
+	}
+
+	// the policy may have changed since the code was sent
+	if !pubdash.RequiresEmailVerification() || !pubdash.AccessPolicy.IsEmailAllowed(session.Email) {
+		return nil, ErrEmailNotAllowed.Errorf("VerifyAccess: email no longer allowed for public dashboard %s", pubdash.Uid)
+	}
+
+	token, err := util.GetRandomString(32)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("VerifyAccess: failed to generate session token: %w", err)
+	}
+
+	expiresAt := now.Add(pd.cfg.PublicDashboardsEmailSessionLifetime)
+	if pubdash.ExpiresAt != nil && pubdash.ExpiresAt.Before(expiresAt) {
+		expiresAt = *pubdash.ExpiresAt
+	}
+
+	affectedRows, err := pd.store.VerifySession(ctx, session.Id, pd.hashSecret(token), now, expiresAt)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("VerifyAccess: failed to verify session: %w", err)
+	}
+
+	// the code was used concurrently
+	if affectedRows == 0 {
+		return nil, ErrInvalidVerificationCode.Errorf("VerifyAccess: verification code already used for public dashboard %s", pubdash.Uid)
+	}
+
+	pd.log.Info("Public dashboard viewer verified", "publicDashboardUid", pubdash.Uid, "dashboardUid", pubdash.DashboardUid)
+
+	return &PublicDashboardSessionToken{
+		Token:     token,
+		Email:     session.Email,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// ValidateSession checks the session token of a viewer and returns the verified email
+func (pd *PublicDashboardServiceImpl) ValidateSession(ctx context.Context, pubdash *PublicDashboard, token string) (string, error) {
+	ctx, span := tracer.Start(ctx, "publicdashboards.ValidateSession")
+	defer span.End()
+
+	if token == "" {
+		return "", ErrEmailVerificationRequired.Errorf("ValidateSession: no session token for public dashboard %s", pubdash.Uid)
+	}
+
+	session, err := pd.store.FindSessionByTokenHash(ctx, pubdash.Uid, pd.hashSecret(token))
+	if err != nil {
+		return "", ErrInternalServerError.Errorf("ValidateSession: failed to find session: %w", err)
+	}
+
+	if session == nil || session.VerifiedAt == nil || !time.Now().Before(session.ExpiresAt) {
+		return "", ErrEmailVerificationRequired.Errorf("ValidateSession: invalid session token for public dashboard %s", pubdash.Uid)
+	}
+
+	if !pubdash.RequiresEmailVerification() || !pubdash.AccessPolicy.IsEmailAllowed(session.Email) {
+		return "", ErrEmailNotAllowed.Errorf("ValidateSession: email no longer allowed for public dashboard %s", pubdash.Uid)
+	}
+
+	return session.Email, nil
+}
+
+// DeleteExpiredSessions deletes viewer sessions and unused verification codes that have expired
+func (pd *PublicDashboardServiceImpl) DeleteExpiredSessions(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "publicdashboards.DeleteExpiredSessions")
+	defer span.End()
+	return pd.store.DeleteExpiredSessions(ctx, time.Now())
+}
+
+// RecordView stores a view of a public dashboard for analytics. Email is empty for anonymous viewers.
+func (pd *PublicDashboardServiceImpl) RecordView(ctx context.Context, accessToken string, email string) error {
+	ctx, span := tracer.Start(ctx, "publicdashboards.RecordView")
+	defer span.End()
+
+	pubdash, err := pd.FindByAccessToken(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	err = pd.store.CreateView(ctx, &PublicDashboardView{
+		PublicDashboardUid: pubdash.Uid,
+		OrgId:              pubdash.OrgId,
+		Email:              email,
+		ViewedAt:           time.Now(),
+	})
+	if err != nil {
+		return ErrInternalServerError.Errorf("RecordView: failed to record view: %w", err)
+	}
+
+	return nil
+}
+
+// GetAnalytics returns view analytics for a public dashboard
+func (pd *PublicDashboardServiceImpl) GetAnalytics(ctx context.Context, uid string, dashboardUid string) (*PublicDashboardAnalytics, error) {
+	ctx, span := tracer.Start(ctx, "publicdashboards.GetAnalytics")
+	defer span.End()
+
+	if _, err := pd.findForDashboard(ctx, uid, dashboardUid); err != nil {
+		return nil, err
+	}
+
+	analytics, err := pd.store.GetAnalytics(ctx, uid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("GetAnalytics: failed to get analytics for public dashboard %s: %w", uid, err)
+	}
+
+	return analytics, nil
+}
+
+// Revoke revokes access to a public dashboard. When an email is given only the sessions of that viewer are
+// revoked. Otherwise the access token is rotated, which invalidates every link shared so far, and all viewer
+// sessions are revoked.
+func (pd *PublicDashboardServiceImpl) Revoke(ctx context.Context, u *user.SignedInUser, dto *RevokePublicDashboardDTO) (*PublicDashboard, error) {
+	ctx, span := tracer.Start(ctx, "publicdashboards.Revoke")
+	defer span.End()
+
+	pubdash, err := pd.findForDashboard(ctx, dto.Uid, dto.DashboardUid)
+	if err != nil {
+		return nil, err
+	}
+
+	if dto.Email != "" {
+		if _, err := pd.store.DeleteSessions(ctx, pubdash.Uid, strings.ToLower(strings.TrimSpace(dto.Email))); err != nil {
+			return nil, ErrInternalServerError.Errorf("Revoke: failed to revoke sessions: %w", err)
+		}
+		pd.log.Info("Public dashboard viewer revoked", "publicDashboardUid", pubdash.Uid, "dashboardUid", pubdash.DashboardUid, "user", u.Login)
+		return pubdash, nil
+	}
+
+	accessToken, err := pd.NewPublicDashboardAccessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	affectedRows, err := pd.store.UpdateAccessToken(ctx, pubdash.Uid, accessToken, dto.UserId, time.Now())
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("Revoke: failed to rotate access token: %w", err)
+	}
+	if affectedRows == 0 {
+		return nil, ErrPublicDashboardNotFound.Errorf("Revoke: public dashboard not found by uid: %s", pubdash.Uid)
+	}
+
+	if _, err := pd.store.DeleteSessions(ctx, pubdash.Uid, ""); err != nil {
+		return nil, ErrInternalServerError.Errorf("Revoke: failed to revoke sessions: %w", err)
+	}
+
+	pd.log.Info("Public dashboard access token revoked", "publicDashboardUid", pubdash.Uid, "dashboardUid", pubdash.DashboardUid, "user", u.Login)
+
+	newPubdash, err := pd.store.Find(ctx, pubdash.Uid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("Revoke: failed to find public dashboard by uid: %s: %w", pubdash.Uid, err)
+	}
+
+	return newPubdash, nil
+}
+
+// findForDashboard returns the public dashboard by uid and validates that it belongs to the dashboard
+func (pd *PublicDashboardServiceImpl) findForDashboard(ctx context.Context, uid string, dashboardUid string) (*PublicDashboard, error) {
+	pubdash, err := pd.store.Find(ctx, uid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("failed to find public dashboard by uid: %s: %w", uid, err)
+	}
+	if pubdash == nil {
+		return nil, ErrPublicDashboardNotFound.Errorf("public dashboard not found by uid: %s", uid)
+	}
+	if pubdash.DashboardUid != dashboardUid {
+		return nil, ErrInvalidUid.Errorf("the public dashboard does not belong to the dashboard")
+	}
+	return pubdash, nil
+}
+
+// hashSecret hashes verification codes and session tokens before they are stored
+func (pd *PublicDashboardServiceImpl) hashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret + pd.cfg.SecretKey))
+	return hex.EncodeToString(hash[:])
+}
+
+// newExpiresAt converts the expiry from the api, a unix timestamp in milliseconds, into a time. Zero clears the expiry.
+func newExpiresAt(expiresAtMs *int64, current *time.Time) (*time.Time, error) {
+	if expiresAtMs == nil {
+		return current, nil
+	}
+	if *expiresAtMs == 0 {
+		return nil, nil
+	}
+
+	expiresAt := time.UnixMilli(*expiresAtMs).UTC()
+	if !expiresAt.After(time.Now()) {
+		return nil, ErrInvalidExpiry.Errorf("expiry date %s is in the past", expiresAt)
+	}
+	return &expiresAt, nil
+}
+
+// newAccessPolicy normalizes the access policy from the api. An empty policy removes the restriction.
+func newAccessPolicy(policy *AccessPolicy, current *AccessPolicy) (*AccessPolicy, error) {
+	if policy == nil {
+		return current, nil
+	}
+
+	if err := validation.ValidateAccessPolicy(policy); err != nil {
+		return nil, err
+	}
+
+	normalized := &AccessPolicy{}
+	for _, email := range policy.AllowedEmails {
+		normalized.AllowedEmails = append(normalized.AllowedEmails, strings.ToLower(strings.TrimSpace(email)))
+	}
+	for _, domain := range policy.AllowedDomains {
+		normalized.AllowedDomains = append(normalized.AllowedDomains, strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@")))
+	}
+
+	if !normalized.IsRestricted() {
+		return nil, nil
+	}
+	return normalized, nil
+}
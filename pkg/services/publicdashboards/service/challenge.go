@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// The verification endpoints of the supported providers. They share the same protocol, any provider implementing it
+// can be used by setting challenge_verify_url.
+var challengeVerifyURLs = map[string]string{
+	"turnstile": "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+	"hcaptcha":  "https://api.hcaptcha.com/siteverify",
+	"recaptcha": "https://www.google.com/recaptcha/api/siteverify",
+}
+
+// siteVerifyClient verifies the challenges with the siteverify endpoint of the provider
+type siteVerifyClient struct {
+	provider  string
+	verifyURL string
+	secretKey string
+	client    *http.Client
+}
+
+var _ publicdashboards.ChallengeVerifier = (*siteVerifyClient)(nil)
+
+// newChallengeVerifier returns the verifier of the configured provider, nil when the challenges are disabled
+func newChallengeVerifier(cfg *setting.Cfg) (publicdashboards.ChallengeVerifier, error) {
+	provider := strings.ToLower(strings.TrimSpace(cfg.PublicDashboardsChallengeProvider))
+	if provider == "" {
+		return nil, nil
+	}
+
+	verifyURL := cfg.PublicDashboardsChallengeVerifyURL
+	if verifyURL == "" {
+		var ok bool
+		if verifyURL, ok = challengeVerifyURLs[provider]; !ok {
+			return nil, fmt.Errorf("unknown challenge provider %q, set challenge_verify_url to use it", provider)
+		}
+	}
+	if cfg.PublicDashboardsChallengeSecretKey == "" {
+		return nil, fmt.Errorf("challenge_secret_key is required by the challenge provider %q", provider)
+	}
+
+	return &siteVerifyClient{
+		provider:  provider,
+		verifyURL: verifyURL,
+		secretKey: cfg.PublicDashboardsChallengeSecretKey,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (c *siteVerifyClient) Provider() string {
+	return c.provider
+}
+
+func (c *siteVerifyClient) Verify(ctx context.Context, response string, clientIP string) (bool, error) {
+	form := url.Values{"secret": {c.secretKey}, "response": {response}}
+	if clientIP != "" {
+		form.Set("remoteip", clientIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("challenge verification returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, err
+	}
+	return result.Success, nil
+}
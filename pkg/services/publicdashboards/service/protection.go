@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/netip"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const (
+	// queryWindow is the window the queries are counted in for the rate limits and the challenge thresholds
+	queryWindow = time.Minute
+	// banCacheTTL is how long the bans of a public dashboard are reused. Bans created on another instance apply
+	// once they expire.
+	banCacheTTL = time.Minute
+	// counterLockCount is the number of locks the counters are spread over
+	counterLockCount = 64
+)
+
+// CheckBan rejects the clients banned from the public dashboard
+func (pd *PublicDashboardServiceImpl) CheckBan(ctx context.Context, accessToken string, clientIP string) error {
+	ctx, span := tracer.Start(ctx, "publicdashboards.CheckBan")
+	defer span.End()
+
+	pubdash, err := pd.FindByAccessToken(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	if pd.isBanned(ctx, pubdash, clientIP) {
+		return ErrClientBanned.Errorf("CheckBan: client %s is banned from public dashboard %s", clientIP, pubdash.Uid)
+	}
+	return nil
+}
+
+// AuthorizeQuery applies the bans, the rate limit and the challenge threshold of the public dashboard to a query.
+// The rejected queries are recorded in the query log.
+func (pd *PublicDashboardServiceImpl) AuthorizeQuery(ctx context.Context, accessToken string, panelId int64, client QueryClient) error {
+	ctx, span := tracer.Start(ctx, "publicdashboards.AuthorizeQuery")
+	defer span.End()
+
+	pubdash, err := pd.FindByAccessToken(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	status := ""
+	switch {
+	case pd.isBanned(ctx, pubdash, client.IP):
+		status = QueryBanned
+		err = ErrClientBanned.Errorf("AuthorizeQuery: client %s is banned from public dashboard %s", client.IP, pubdash.Uid)
+	case pd.isRateLimited(ctx, pubdash, client.IP):
+		status = QueryRateLimited
+		err = ErrQueryRateLimited.Errorf("AuthorizeQuery: client %s exceeded the rate limit of public dashboard %s", client.IP, pubdash.Uid)
+	case client.Email == "" && pd.requiresChallenge(ctx, pubdash, client.IP):
+		status = QueryChallengeRequired
+		err = ErrChallengeRequired.Errorf("AuthorizeQuery: client %s must solve a challenge for public dashboard %s", client.IP, pubdash.Uid)
+	default:
+		return nil
+	}
+
+	pd.recordQuery(ctx, pubdash, &PublicDashboardQueryLog{
+		PanelId:  panelId,
+		ClientIp: client.IP,
+		Email:    client.Email,
+		Status:   status,
+	})
+	return err
+}
+
+// RecordQuery records a query run on a public dashboard in its query log
+func (pd *PublicDashboardServiceImpl) RecordQuery(ctx context.Context, accessToken string, entry *PublicDashboardQueryLog) error {
+	ctx, span := tracer.Start(ctx, "publicdashboards.RecordQuery")
+	defer span.End()
+
+	if !pd.cfg.PublicDashboardsQueryAuditEnabled {
+		return nil
+	}
+
+	pubdash, err := pd.FindByAccessToken(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	entry.PublicDashboardUid = pubdash.Uid
+	entry.OrgId = pubdash.OrgId
+	entry.QueriedAt = time.Now()
+	if err := pd.store.CreateQueryLog(ctx, entry); err != nil {
+		return ErrInternalServerError.Errorf("RecordQuery: failed to record query: %w", err)
+	}
+	return nil
+}
+
+// FindQueryLog returns the recorded queries of a public dashboard
+func (pd *PublicDashboardServiceImpl) FindQueryLog(ctx context.Context, query *PublicDashboardQueryLogQuery) (*PublicDashboardQueryLogResult, error) {
+	ctx, span := tracer.Start(ctx, "publicdashboards.FindQueryLog")
+	defer span.End()
+
+	if _, err := pd.findForDashboard(ctx, query.Uid, query.DashboardUid); err != nil {
+		return nil, err
+	}
+
+	result, err := pd.store.FindQueryLog(ctx, query)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("FindQueryLog: failed to find the queries of public dashboard %s: %w", query.Uid, err)
+	}
+	return result, nil
+}
+
+// DeleteOldQueryLogs deletes the recorded queries older than the retention
+func (pd *PublicDashboardServiceImpl) DeleteOldQueryLogs(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "publicdashboards.DeleteOldQueryLogs")
+	defer span.End()
+	return pd.store.DeleteQueryLogs(ctx, time.Now().Add(-pd.cfg.PublicDashboardsQueryAuditRetention))
+}
+
+// GetChallenge returns what viewers need to render the challenge of the public dashboard
+func (pd *PublicDashboardServiceImpl) GetChallenge(ctx context.Context, accessToken string) (*PublicDashboardChallenge, error) {
+	ctx, span := tracer.Start(ctx, "publicdashboards.GetChallenge")
+	defer span.End()
+
+	if pd.challengeVerifier == nil {
+		return nil, ErrChallengeDisabled.Errorf("GetChallenge: no challenge provider configured")
+	}
+	if _, err := pd.FindByAccessToken(ctx, accessToken); err != nil {
+		return nil, err
+	}
+
+	return &PublicDashboardChallenge{
+		Provider: pd.challengeVerifier.Provider(),
+		SiteKey:  pd.cfg.PublicDashboardsChallengeSiteKey,
+	}, nil
+}
+
+// SolveChallenge verifies the response to a challenge. The client isn't challenged again by the public dashboard
+// for the lifetime of the challenges.
+func (pd *PublicDashboardServiceImpl) SolveChallenge(ctx context.Context, accessToken string, clientIP string, response string) error {
+	ctx, span := tracer.Start(ctx, "publicdashboards.SolveChallenge")
+	defer span.End()
+
+	if pd.challengeVerifier == nil {
+		return ErrChallengeDisabled.Errorf("SolveChallenge: no challenge provider configured")
+	}
+	pubdash, err := pd.FindByAccessToken(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	ok, err := pd.challengeVerifier.Verify(ctx, response, clientIP)
+	if err != nil {
+		return ErrInternalServerError.Errorf("SolveChallenge: failed to verify challenge: %w", err)
+	}
+	if !ok {
+		return ErrInvalidChallenge.Errorf("SolveChallenge: invalid challenge response for public dashboard %s", pubdash.Uid)
+	}
+
+	if err := pd.cache.Set(ctx, challengeKey(pubdash.Uid, clientIP), []byte("1"), pd.cfg.PublicDashboardsChallengeLifetime); err != nil {
+		return ErrInternalServerError.Errorf("SolveChallenge: failed to store solved challenge: %w", err)
+	}
+	return nil
+}
+
+// FindBans returns the bans of a public dashboard
+func (pd *PublicDashboardServiceImpl) FindBans(ctx context.Context, uid string, dashboardUid string) ([]*PublicDashboardBan, error) {
+	ctx, span := tracer.Start(ctx, "publicdashboards.FindBans")
+	defer span.End()
+
+	if _, err := pd.findForDashboard(ctx, uid, dashboardUid); err != nil {
+		return nil, err
+	}
+
+	bans, err := pd.store.FindBans(ctx, uid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("FindBans: failed to find the bans of public dashboard %s: %w", uid, err)
+	}
+	return bans, nil
+}
+
+// CreateBan bans an IP range from a public dashboard
+func (pd *PublicDashboardServiceImpl) CreateBan(ctx context.Context, u *user.SignedInUser, dto *CreatePublicDashboardBanDTO) (*PublicDashboardBan, error) {
+	ctx, span := tracer.Start(ctx, "publicdashboards.CreateBan")
+	defer span.End()
+
+	pubdash, err := pd.findForDashboard(ctx, dto.Uid, dto.DashboardUid)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := validation.ParseBanRange(dto.Cidr)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt, err := newExpiresAt(dto.ExpiresAt, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ban := &PublicDashboardBan{
+		PublicDashboardUid: pubdash.Uid,
+		OrgId:              pubdash.OrgId,
+		Cidr:               prefix.String(),
+		Reason:             dto.Reason,
+		CreatedBy:          dto.UserId,
+		CreatedAt:          time.Now(),
+		ExpiresAt:          expiresAt,
+	}
+	if err := pd.store.CreateBan(ctx, ban); err != nil {
+		return nil, ErrInternalServerError.Errorf("CreateBan: failed to create ban: %w", err)
+	}
+	pd.banCache.Delete(pubdash.Uid)
+
+	pd.log.Info("Public dashboard IP range banned", "publicDashboardUid", pubdash.Uid, "cidr", ban.Cidr, "user", u.Login)
+	return ban, nil
+}
+
+// DeleteBan lifts a ban of a public dashboard
+func (pd *PublicDashboardServiceImpl) DeleteBan(ctx context.Context, uid string, dashboardUid string, id int64) error {
+	ctx, span := tracer.Start(ctx, "publicdashboards.DeleteBan")
+	defer span.End()
+
+	if _, err := pd.findForDashboard(ctx, uid, dashboardUid); err != nil {
+		return err
+	}
+
+	affectedRows, err := pd.store.DeleteBan(ctx, uid, id)
+	if err != nil {
+		return ErrInternalServerError.Errorf("DeleteBan: failed to delete ban: %w", err)
+	}
+	if affectedRows == 0 {
+		return ErrBanNotFound.Errorf("DeleteBan: ban %d not found for public dashboard %s", id, uid)
+	}
+	pd.banCache.Delete(uid)
+	return nil
+}
+
+// isBanned reports whether a ban of the public dashboard covers the client. The client isn't banned when the bans
+// can't be read.
+func (pd *PublicDashboardServiceImpl) isBanned(ctx context.Context, pubdash *PublicDashboard, clientIP string) bool {
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	var bans []*PublicDashboardBan
+	if cached, ok := pd.banCache.Get(pubdash.Uid); ok {
+		bans = cached.([]*PublicDashboardBan)
+	} else {
+		bans, err = pd.store.FindBans(ctx, pubdash.Uid)
+		if err != nil {
+			pd.log.Warn("Failed to find the bans of a public dashboard", "publicDashboardUid", pubdash.Uid, "error", err)
+			return false
+		}
+		pd.banCache.Set(pubdash.Uid, bans, banCacheTTL)
+	}
+
+	now := time.Now()
+	for _, ban := range bans {
+		if ban.ExpiresAt != nil && !now.Before(*ban.ExpiresAt) {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(ban.Cidr)
+		if err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRateLimited counts the query of the client and reports whether it exceeds the rate limit of the public dashboard
+func (pd *PublicDashboardServiceImpl) isRateLimited(ctx context.Context, pubdash *PublicDashboard, clientIP string) bool {
+	limit := pd.cfg.PublicDashboardsQueryRateLimit
+	if pubdash.QueryProtection != nil && pubdash.QueryProtection.RateLimit != 0 {
+		limit = pubdash.QueryProtection.RateLimit
+	}
+	if limit <= 0 {
+		return false
+	}
+
+	start := time.Now().Truncate(queryWindow)
+	count, err := pd.incrementCounter(ctx, fmt.Sprintf("publicdashboards-queries-%s-%s-%d", pubdash.Uid, clientIP, start.Unix()))
+	if err != nil {
+		pd.log.Warn("Failed to count public dashboard query", "publicDashboardUid", pubdash.Uid, "error", err)
+		return false
+	}
+	return count > limit
+}
+
+// requiresChallenge counts the anonymous query and reports whether the client must solve a challenge: the anonymous
+// queries of the public dashboard exceed its threshold and the client didn't solve a challenge recently.
+func (pd *PublicDashboardServiceImpl) requiresChallenge(ctx context.Context, pubdash *PublicDashboard, clientIP string) bool {
+	if pd.challengeVerifier == nil {
+		return false
+	}
+	threshold := pd.cfg.PublicDashboardsChallengeThreshold
+	if pubdash.QueryProtection != nil && pubdash.QueryProtection.ChallengeThreshold != 0 {
+		threshold = pubdash.QueryProtection.ChallengeThreshold
+	}
+	if threshold <= 0 {
+		return false
+	}
+
+	start := time.Now().Truncate(queryWindow)
+	count, err := pd.incrementCounter(ctx, fmt.Sprintf("publicdashboards-anonymous-queries-%s-%d", pubdash.Uid, start.Unix()))
+	if err != nil {
+		pd.log.Warn("Failed to count anonymous public dashboard query", "publicDashboardUid", pubdash.Uid, "error", err)
+		return false
+	}
+	if count <= threshold {
+		return false
+	}
+
+	_, err = pd.cache.Get(ctx, challengeKey(pubdash.Uid, clientIP))
+	return errors.Is(err, remotecache.ErrCacheItemNotFound)
+}
+
+// incrementCounter increments a counter of the current window in the remote cache, so that the instances sharing
+// the cache share the counts, and returns its new value
+func (pd *PublicDashboardServiceImpl) incrementCounter(ctx context.Context, key string) (int64, error) {
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(key))
+	lock := &pd.counterLocks[hasher.Sum32()%counterLockCount]
+	lock.Lock()
+	defer lock.Unlock()
+
+	var count int64
+	value, err := pd.cache.Get(ctx, key)
+	switch {
+	case errors.Is(err, remotecache.ErrCacheItemNotFound):
+	case err != nil:
+		return 0, err
+	default:
+		if count, err = strconv.ParseInt(string(value), 10, 64); err != nil {
+			return 0, err
+		}
+	}
+
+	count++
+	if err := pd.cache.Set(ctx, key, []byte(strconv.FormatInt(count, 10)), queryWindow+time.Second); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// recordQuery records a rejected query, the failures are logged since the query is rejected anyway
+func (pd *PublicDashboardServiceImpl) recordQuery(ctx context.Context, pubdash *PublicDashboard, entry *PublicDashboardQueryLog) {
+	if !pd.cfg.PublicDashboardsQueryAuditEnabled {
+		return
+	}
+
+	entry.PublicDashboardUid = pubdash.Uid
+	entry.OrgId = pubdash.OrgId
+	entry.QueriedAt = time.Now()
+	if err := pd.store.CreateQueryLog(ctx, entry); err != nil {
+		pd.log.Warn("Failed to record rejected public dashboard query", "publicDashboardUid", pubdash.Uid, "error", err)
+	}
+}
+
+// newQueryProtection validates the query protection from the api. Zero limits remove the overrides.
+func newQueryProtection(protection *QueryProtection, current *QueryProtection) (*QueryProtection, error) {
+	if protection == nil {
+		return current, nil
+	}
+	if err := validation.ValidateQueryProtection(protection); err != nil {
+		return nil, err
+	}
+	if protection.RateLimit == 0 && protection.ChallengeThreshold == 0 {
+		return nil, nil
+	}
+	return &QueryProtection{RateLimit: protection.RateLimit, ChallengeThreshold: protection.ChallengeThreshold}, nil
+}
+
+func challengeKey(publicDashboardUid string, clientIP string) string {
+	return fmt.Sprintf("publicdashboards-challenge-%s-%s", publicDashboardUid, clientIP)
+}
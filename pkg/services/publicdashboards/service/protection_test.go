@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeChallengeVerifier struct {
+	valid string
+}
+
+func (v *fakeChallengeVerifier) Provider() string {
+	return "turnstile"
+}
+
+func (v *fakeChallengeVerifier) Verify(_ context.Context, response string, _ string) (bool, error) {
+	return response == v.valid, nil
+}
+
+func newProtectedService(t *testing.T, pubdash *PublicDashboard, bans []*PublicDashboardBan) (*PublicDashboardServiceImpl, *FakePublicDashboardStore) {
+	t.Helper()
+
+	store := NewFakePublicDashboardStore(t)
+	store.On("FindByAccessToken", mock.Anything, pubdash.AccessToken).Return(pubdash, nil).Maybe()
+	store.On("FindBans", mock.Anything, pubdash.Uid).Return(bans, nil).Maybe()
+	store.On("CreateQueryLog", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	cfg := setting.NewCfg()
+	cfg.PublicDashboardsQueryAuditEnabled = true
+	cfg.PublicDashboardsChallengeLifetime = time.Hour
+
+	return &PublicDashboardServiceImpl{
+		log:      log.New("test.logger"),
+		cfg:      cfg,
+		store:    store,
+		cache:    remotecache.NewFakeCacheStorage(),
+		banCache: localcache.New(banCacheTTL, 2*banCacheTTL),
+	}, store
+}
+
+func TestAuthorizeQuery(t *testing.T) {
+	client := QueryClient{IP: "203.0.113.7"}
+
+	t.Run("rejects the queries over the rate limit of the client", func(t *testing.T) {
+		pubdash := &PublicDashboard{Uid: "pubdash", AccessToken: "token", OrgId: 1}
+		pd, store := newProtectedService(t, pubdash, nil)
+		pd.cfg.PublicDashboardsQueryRateLimit = 2
+
+		require.NoError(t, pd.AuthorizeQuery(context.Background(), "token", 1, client))
+		require.NoError(t, pd.AuthorizeQuery(context.Background(), "token", 1, client))
+		err := pd.AuthorizeQuery(context.Background(), "token", 1, client)
+		assert.ErrorIs(t, err, ErrQueryRateLimited)
+
+		// the rate limit applies per client
+		require.NoError(t, pd.AuthorizeQuery(context.Background(), "token", 1, QueryClient{IP: "203.0.113.8"}))
+
+		store.AssertNumberOfCalls(t, "CreateQueryLog", 1)
+		store.AssertCalled(t, "CreateQueryLog", mock.Anything, mock.MatchedBy(func(entry *PublicDashboardQueryLog) bool {
+			return entry.Status == QueryRateLimited && entry.ClientIp == client.IP && entry.PublicDashboardUid == "pubdash"
+		}))
+	})
+
+	t.Run("the rate limit of the public dashboard overrides the one of the server", func(t *testing.T) {
+		pubdash := &PublicDashboard{Uid: "pubdash", AccessToken: "token", QueryProtection: &QueryProtection{RateLimit: -1}}
+		pd, _ := newProtectedService(t, pubdash, nil)
+		pd.cfg.PublicDashboardsQueryRateLimit = 1
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, pd.AuthorizeQuery(context.Background(), "token", 1, client))
+		}
+	})
+
+	t.Run("rejects the banned clients", func(t *testing.T) {
+		pubdash := &PublicDashboard{Uid: "pubdash", AccessToken: "token"}
+		expired := time.Now().Add(-time.Hour)
+		pd, _ := newProtectedService(t, pubdash, []*PublicDashboardBan{
+			{Cidr: "203.0.113.0/24"},
+			{Cidr: "198.51.100.0/24", ExpiresAt: &expired},
+		})
+
+		assert.ErrorIs(t, pd.AuthorizeQuery(context.Background(), "token", 1, client), ErrClientBanned)
+		assert.ErrorIs(t, pd.CheckBan(context.Background(), "token", "203.0.113.200"), ErrClientBanned)
+		assert.NoError(t, pd.CheckBan(context.Background(), "token", "198.51.100.1"))
+		assert.NoError(t, pd.CheckBan(context.Background(), "token", "192.0.2.1"))
+	})
+
+	t.Run("challenges the anonymous viewers over the threshold until they solve it", func(t *testing.T) {
+		pubdash := &PublicDashboard{Uid: "pubdash", AccessToken: "token", QueryProtection: &QueryProtection{ChallengeThreshold: 1}}
+		pd, _ := newProtectedService(t, pubdash, nil)
+		pd.challengeVerifier = &fakeChallengeVerifier{valid: "solved"}
+
+		require.NoError(t, pd.AuthorizeQuery(context.Background(), "token", 1, client))
+		assert.ErrorIs(t, pd.AuthorizeQuery(context.Background(), "token", 1, client), ErrChallengeRequired)
+
+		// viewers with a verified email aren't challenged
+		require.NoError(t, pd.AuthorizeQuery(context.Background(), "token", 1, QueryClient{IP: client.IP, Email: "viewer@example.com"}))
+
+		assert.ErrorIs(t, pd.SolveChallenge(context.Background(), "token", client.IP, "wrong"), ErrInvalidChallenge)
+		require.NoError(t, pd.SolveChallenge(context.Background(), "token", client.IP, "solved"))
+		require.NoError(t, pd.AuthorizeQuery(context.Background(), "token", 1, client))
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,14 +15,17 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/licensing"
+	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/service/intervalv2"
@@ -36,17 +40,22 @@ import (
 // PublicDashboardServiceImpl Define the Service Implementation. We're generating mock implementation
 // automatically
 type PublicDashboardServiceImpl struct {
-	log                log.Logger
-	cfg                *setting.Cfg
-	features           featuremgmt.FeatureToggles
-	store              publicdashboards.Store
-	intervalCalculator intervalv2.Calculator
-	QueryDataService   query.Service
-	AnnotationsRepo    annotations.Repository
-	ac                 accesscontrol.AccessControl
-	serviceWrapper     publicdashboards.ServiceWrapper
-	dashboardService   dashboards.DashboardService
-	license            licensing.Licensing
+	log                 log.Logger
+	cfg                 *setting.Cfg
+	features            featuremgmt.FeatureToggles
+	store               publicdashboards.Store
+	intervalCalculator  intervalv2.Calculator
+	QueryDataService    query.Service
+	AnnotationsRepo     annotations.Repository
+	ac                  accesscontrol.AccessControl
+	serviceWrapper      publicdashboards.ServiceWrapper
+	dashboardService    dashboards.DashboardService
+	license             licensing.Licensing
+	notificationService notifications.EmailSender
+	cache               remotecache.CacheStorage
+	challengeVerifier   publicdashboards.ChallengeVerifier
+	banCache            *localcache.CacheService
+	counterLocks        [counterLockCount]sync.Mutex
 }
 
 var LogPrefix = "publicdashboards.service"
@@ -68,19 +77,31 @@ func ProvideService(
 	serviceWrapper publicdashboards.ServiceWrapper,
 	dashboardService dashboards.DashboardService,
 	license licensing.Licensing,
+	notificationService notifications.EmailSender,
+	cache remotecache.CacheStorage,
 ) *PublicDashboardServiceImpl {
+	logger := log.New(LogPrefix)
+	challengeVerifier, err := newChallengeVerifier(cfg)
+	if err != nil {
+		logger.Error("Public dashboard challenges are disabled", "error", err)
+	}
+
 	return &PublicDashboardServiceImpl{
-		log:                log.New(LogPrefix),
-		cfg:                cfg,
-		features:           features,
-		store:              store,
-		intervalCalculator: intervalv2.NewCalculator(),
-		QueryDataService:   qds,
-		AnnotationsRepo:    anno,
-		ac:                 ac,
-		serviceWrapper:     serviceWrapper,
-		dashboardService:   dashboardService,
-		license:            license,
+		log:                 logger,
+		cfg:                 cfg,
+		features:            features,
+		store:               store,
+		intervalCalculator:  intervalv2.NewCalculator(),
+		QueryDataService:    qds,
+		AnnotationsRepo:     anno,
+		ac:                  ac,
+		serviceWrapper:      serviceWrapper,
+		dashboardService:    dashboardService,
+		license:             license,
+		notificationService: notificationService,
+		cache:               remotecache.WithUsage(cache, "public dashboards"),
+		challengeVerifier:   challengeVerifier,
+		banCache:            localcache.New(banCacheTTL, 2*banCacheTTL),
 	}
 }
 
@@ -185,6 +206,10 @@ func (pd *PublicDashboardServiceImpl) FindEnabledPublicDashboardAndDashboardByAc
 		return nil, nil, ErrPublicDashboardNotEnabled.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: Public dashboard is not enabled accessToken: %s", accessToken)
 	}
 
+	if pubdash.IsExpired(time.Now()) {
+		return nil, nil, ErrPublicDashboardExpired.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: Public dashboard is expired accessToken: %s", accessToken)
+	}
+
 	if !pd.license.FeatureEnabled(FeaturePublicDashboardsEmailSharing) && pubdash.Share == EmailShareType {
 		return nil, nil, ErrPublicDashboardNotFound.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: Dashboard not found accessToken: %s", accessToken)
 	}
@@ -301,7 +326,10 @@ func (pd *PublicDashboardServiceImpl) Update(ctx context.Context, u *user.Signed
 		return nil, ErrInvalidUid.Errorf("Update: the public dashboard does not belong to the dashboard")
 	}
 
-	publicDashboard := newUpdatePublicDashboard(dto, existingPubdash)
+	publicDashboard, err := newUpdatePublicDashboard(dto, existingPubdash)
+	if err != nil {
+		return nil, err
+	}
 
 	// set values to update
 	cmd := SavePublicDashboardCommand{
@@ -572,6 +600,21 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 		share = PublicShareType
 	}
 
+	expiresAt, err := newExpiresAt(dto.PublicDashboard.ExpiresAt, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	accessPolicy, err := newAccessPolicy(dto.PublicDashboard.AccessPolicy, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	queryProtection, err := newQueryProtection(dto.PublicDashboard.QueryProtection, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 
 	return &PublicDashboard{
@@ -588,10 +631,13 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 		UpdatedBy:            dto.UserId,
 		UpdatedAt:            now,
 		AccessToken:          accessToken,
+		ExpiresAt:            expiresAt,
+		AccessPolicy:         accessPolicy,
+		QueryProtection:      queryProtection,
 	}, nil
 }
 
-func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard) *PublicDashboard {
+func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard) (*PublicDashboard, error) {
 	pubdashDTO := dto.PublicDashboard
 	timeSelectionEnabled := returnValueOrDefault(pubdashDTO.TimeSelectionEnabled, pd.TimeSelectionEnabled)
 	isEnabled := returnValueOrDefault(pubdashDTO.IsEnabled, pd.IsEnabled)
@@ -602,6 +648,21 @@ func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard)
 		share = pd.Share
 	}
 
+	expiresAt, err := newExpiresAt(pubdashDTO.ExpiresAt, pd.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	accessPolicy, err := newAccessPolicy(pubdashDTO.AccessPolicy, pd.AccessPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	queryProtection, err := newQueryProtection(pubdashDTO.QueryProtection, pd.QueryProtection)
+	if err != nil {
+		return nil, err
+	}
+
 	return &PublicDashboard{
 		Uid:                  pd.Uid,
 		IsEnabled:            isEnabled,
@@ -609,9 +670,12 @@ func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard)
 		TimeSelectionEnabled: timeSelectionEnabled,
 		TimeSettings:         pd.TimeSettings,
 		Share:                share,
+		ExpiresAt:            expiresAt,
+		AccessPolicy:         accessPolicy,
+		QueryProtection:      queryProtection,
 		UpdatedBy:            dto.UserId,
 		UpdatedAt:            time.Now(),
-	}
+	}, nil
 }
 
 func returnValueOrDefault(value *bool, defaultValue bool) bool {
@@ -1,6 +1,9 @@
 package validation
 
 import (
+	"net/netip"
+	"strings"
+
 	"github.com/google/uuid"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
@@ -61,3 +64,51 @@ func IsValidShareType(shareType ShareType) bool {
 	}
 	return false
 }
+
+// ValidateAccessPolicy checks that allowed emails are valid emails and allowed domains look like domain names
+func ValidateAccessPolicy(policy *AccessPolicy) error {
+	for _, email := range policy.AllowedEmails {
+		if !util.IsEmail(strings.TrimSpace(email)) {
+			return ErrInvalidAccessPolicy.Errorf("ValidateAccessPolicy: invalid email %q", email)
+		}
+	}
+
+	for _, domain := range policy.AllowedDomains {
+		domain = strings.TrimPrefix(strings.TrimSpace(domain), "@")
+		if domain == "" || !strings.Contains(domain, ".") || strings.ContainsAny(domain, " @/") {
+			return ErrInvalidAccessPolicy.Errorf("ValidateAccessPolicy: invalid domain %q", domain)
+		}
+	}
+
+	return nil
+}
+
+// ValidateQueryProtection checks that the limits are -1, 0 or positive
+func ValidateQueryProtection(protection *QueryProtection) error {
+	if protection.RateLimit < -1 {
+		return ErrInvalidQueryProtection.Errorf("ValidateQueryProtection: invalid rate limit %d", protection.RateLimit)
+	}
+	if protection.ChallengeThreshold < -1 {
+		return ErrInvalidQueryProtection.Errorf("ValidateQueryProtection: invalid challenge threshold %d", protection.ChallengeThreshold)
+	}
+	return nil
+}
+
+// ParseBanRange parses an IP range or an IP address, which is a range of one address
+func ParseBanRange(cidr string) (netip.Prefix, error) {
+	cidr = strings.TrimSpace(cidr)
+	if strings.Contains(cidr, "/") {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return netip.Prefix{}, ErrInvalidBan.Errorf("ParseBanRange: invalid IP range %q", cidr)
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(cidr)
+	if err != nil {
+		return netip.Prefix{}, ErrInvalidBan.Errorf("ParseBanRange: invalid IP address %q", cidr)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
@@ -170,3 +170,50 @@ func TestValidUid(t *testing.T) {
 		assert.False(t, IsValidShortUID("afqrz7j%%"))
 	})
 }
+
+func TestValidateAccessPolicy(t *testing.T) {
+	t.Run("valid emails and domains", func(t *testing.T) {
+		err := ValidateAccessPolicy(&AccessPolicy{
+			AllowedEmails:  []string{"viewer@example.org"},
+			AllowedDomains: []string{"grafana.com", "@corp.example.com"},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid email", func(t *testing.T) {
+		err := ValidateAccessPolicy(&AccessPolicy{AllowedEmails: []string{"not-an-email"}})
+		assert.ErrorIs(t, err, ErrInvalidAccessPolicy)
+	})
+
+	t.Run("invalid domain", func(t *testing.T) {
+		for _, domain := range []string{"", "localhost", "example.org/path", "user@example.org"} {
+			err := ValidateAccessPolicy(&AccessPolicy{AllowedDomains: []string{domain}})
+			assert.ErrorIs(t, err, ErrInvalidAccessPolicy, domain)
+		}
+	})
+}
+
+func TestParseBanRange(t *testing.T) {
+	t.Run("ranges are masked", func(t *testing.T) {
+		prefix, err := ParseBanRange("203.0.113.42/24")
+		require.NoError(t, err)
+		assert.Equal(t, "203.0.113.0/24", prefix.String())
+	})
+
+	t.Run("addresses are ranges of one address", func(t *testing.T) {
+		prefix, err := ParseBanRange(" 203.0.113.42 ")
+		require.NoError(t, err)
+		assert.Equal(t, "203.0.113.42/32", prefix.String())
+
+		prefix, err = ParseBanRange("2001:db8::1")
+		require.NoError(t, err)
+		assert.Equal(t, "2001:db8::1/128", prefix.String())
+	})
+
+	t.Run("invalid ranges", func(t *testing.T) {
+		for _, cidr := range []string{"", "203.0.113", "203.0.113.0/33", "example.org"} {
+			_, err := ParseBanRange(cidr)
+			assert.ErrorIs(t, err, ErrInvalidBan, cidr)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package query
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+type requestAttributionKey struct{}
+
+// RequestAttribution identifies the dashboard and the panel which issued a query, so that the load on the datasources
+// can be attributed to them.
+type RequestAttribution struct {
+	DashboardUID string
+	PanelID      int64
+}
+
+// WithRequestAttribution returns a context carrying the attribution of the queries run with it, down to the plugin
+// client.
+func WithRequestAttribution(ctx context.Context, attribution RequestAttribution) context.Context {
+	if attribution.DashboardUID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestAttributionKey{}, attribution)
+}
+
+// RequestAttributionFromContext returns the attribution of the queries run with the context, if any.
+func RequestAttributionFromContext(ctx context.Context) (RequestAttribution, bool) {
+	attribution, ok := ctx.Value(requestAttributionKey{}).(RequestAttribution)
+	return attribution, ok
+}
+
+// RequestAttributionFromHeaders returns the attribution sent by the frontend with the X-Dashboard-Uid and X-Panel-Id
+// headers. The panel ID is 0 when it's missing or invalid.
+func RequestAttributionFromHeaders(headers http.Header) RequestAttribution {
+	attribution := RequestAttribution{DashboardUID: headers.Get(HeaderDashboardUID)}
+	if panelID, err := strconv.ParseInt(headers.Get(HeaderPanelID), 10, 64); err == nil && panelID > 0 {
+		attribution.PanelID = panelID
+	}
+	return attribution
+}
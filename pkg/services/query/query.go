@@ -25,6 +25,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dsquerierclient"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/services/transformations"
 	"github.com/grafana/grafana/pkg/services/validations"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/grafanads"
@@ -73,6 +74,9 @@ type Service interface {
 
 	// this is more "forward compatible", for example supports per-query time ranges
 	QueryDataNew(ctx context.Context, user identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest) (*backend.QueryDataResponse, error)
+
+	// NewExpressionStream builds a pipeline evaluating the expressions of the request on the frames of the stream of the query streamRefID
+	NewExpressionStream(ctx context.Context, user identity.Requester, reqDTO dtos.MetricRequest, streamRefID string, window time.Duration) (*expr.StreamPipeline, error)
 }
 
 // Gives us compile time error if the service does not adhere to the contract of the interface
@@ -99,6 +103,27 @@ func (s *ServiceImpl) Run(ctx context.Context) error {
 
 // QueryData processes queries and returns query responses. It handles queries to single or mixed datasources, as well as expressions.
 func (s *ServiceImpl) queryData(ctx context.Context, user identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest, supportLocaltimeRange bool) (*backend.QueryDataResponse, error) {
+	if err := transformations.Validate(reqDTO.Transformations); err != nil {
+		return nil, ErrInvalidTransformation.Errorf("%w", err)
+	}
+
+	resp, err := s.executeQueries(ctx, user, skipDSCache, reqDTO, supportLocaltimeRange)
+	if err != nil || len(reqDTO.Transformations) == 0 {
+		return resp, err
+	}
+
+	// Apply the transformations to the frames of all the queries, like the panel making the request
+	refIDs := make([]string, 0, len(reqDTO.Queries))
+	for _, query := range reqDTO.Queries {
+		refIDs = append(refIDs, query.Get("refId").MustString("A"))
+	}
+	if err := transformations.ApplyToResponse(resp, refIDs, reqDTO.Transformations); err != nil {
+		return nil, ErrInvalidTransformation.Errorf("%w", err)
+	}
+	return resp, nil
+}
+
+func (s *ServiceImpl) executeQueries(ctx context.Context, user identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest, supportLocaltimeRange bool) (*backend.QueryDataResponse, error) {
 	fromAlert := false
 	for header, val := range s.headers {
 		if header == models.FromAlertHeaderName && val == "true" {
@@ -239,7 +264,40 @@ func QueryData(ctx context.Context, log log.Logger, dscache datasources.CacheSer
 
 // handleExpressions handles queries when there is an expression.
 func (s *ServiceImpl) handleExpressions(ctx context.Context, user identity.Requester, parsedReq *parsedRequest) (*backend.QueryDataResponse, error) {
-	exprReq := expr.Request{
+	exprReq, err := buildExpressionRequest(user, parsedReq)
+	if err != nil {
+		return nil, err
+	}
+
+	qdr, err := s.expressionService.TransformData(ctx, time.Now(), exprReq) // use time now because all queries have absolute time range
+	if err != nil {
+		return nil, fmt.Errorf("expression request error: %w", err)
+	}
+	return qdr, nil
+}
+
+// NewExpressionStream builds a pipeline evaluating the expressions of the request on the frames received from the stream
+// of the query streamRefID during the last window.
+func (s *ServiceImpl) NewExpressionStream(ctx context.Context, user identity.Requester, reqDTO dtos.MetricRequest, streamRefID string, window time.Duration) (*expr.StreamPipeline, error) {
+	parsedReq, err := s.parseMetricRequest(ctx, user, false, reqDTO, false)
+	if err != nil {
+		return nil, err
+	}
+
+	exprReq, err := buildExpressionRequest(user, parsedReq)
+	if err != nil {
+		return nil, err
+	}
+
+	sp, err := s.expressionService.BuildStreamPipeline(ctx, time.Now(), exprReq, streamRefID, window)
+	if err != nil {
+		return nil, fmt.Errorf("expression stream request error: %w", err)
+	}
+	return sp, nil
+}
+
+func buildExpressionRequest(user identity.Requester, parsedReq *parsedRequest) (*expr.Request, error) {
+	exprReq := &expr.Request{
 		Queries: []expr.Query{},
 	}
 
@@ -271,11 +329,7 @@ func (s *ServiceImpl) handleExpressions(ctx context.Context, user identity.Reque
 		})
 	}
 
-	qdr, err := s.expressionService.TransformData(ctx, time.Now(), &exprReq) // use time now because all queries have absolute time range
-	if err != nil {
-		return nil, fmt.Errorf("expression request error: %w", err)
-	}
-	return qdr, nil
+	return exprReq, nil
 }
 
 // handleQuerySingleDatasource handles one or more queries to a single datasource
@@ -9,9 +9,13 @@ import (
 
 	dtos "github.com/grafana/grafana/pkg/api/dtos"
 
+	expr "github.com/grafana/grafana/pkg/expr"
+
 	identity "github.com/grafana/grafana/pkg/apimachinery/identity"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // FakeQueryService is an autogenerated mock type for the Service type
@@ -19,6 +23,36 @@ type FakeQueryService struct {
 	mock.Mock
 }
 
+// NewExpressionStream provides a mock function with given fields: ctx, user, reqDTO, streamRefID, window
+func (_m *FakeQueryService) NewExpressionStream(ctx context.Context, user identity.Requester, reqDTO dtos.MetricRequest, streamRefID string, window time.Duration) (*expr.StreamPipeline, error) {
+	ret := _m.Called(ctx, user, reqDTO, streamRefID, window)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NewExpressionStream")
+	}
+
+	var r0 *expr.StreamPipeline
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, identity.Requester, dtos.MetricRequest, string, time.Duration) (*expr.StreamPipeline, error)); ok {
+		return rf(ctx, user, reqDTO, streamRefID, window)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, identity.Requester, dtos.MetricRequest, string, time.Duration) *expr.StreamPipeline); ok {
+		r0 = rf(ctx, user, reqDTO, streamRefID, window)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*expr.StreamPipeline)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, identity.Requester, dtos.MetricRequest, string, time.Duration) error); ok {
+		r1 = rf(ctx, user, reqDTO, streamRefID, window)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // QueryData provides a mock function with given fields: ctx, user, skipDSCache, reqDTO
 func (_m *FakeQueryService) QueryData(ctx context.Context, user identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest) (*backend.QueryDataResponse, error) {
 	ret := _m.Called(ctx, user, skipDSCache, reqDTO)
@@ -10,6 +10,7 @@ import (
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/useractivity"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -59,6 +60,13 @@ func (s *QueryHistoryService) createHandler(c *contextmodel.ReqContext) response
 		return response.Error(http.StatusInternalServerError, "Failed to create query history", err)
 	}
 
+	// Explore adds the queries it runs to the query history
+	useractivity.RecordRequest(s.userActivity, c, &useractivity.Activity{
+		Type:        useractivity.TypeExploreQuery,
+		ResourceUID: cmd.DatasourceUID,
+		Title:       queryTitle(cmd.Queries, cmd.DatasourceUID),
+	})
+
 	return response.JSON(http.StatusOK, QueryHistoryResponse{Result: query})
 }
 
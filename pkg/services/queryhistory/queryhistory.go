@@ -5,14 +5,16 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/useractivity"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
-func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, accessControl ac.AccessControl) *QueryHistoryService {
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, accessControl ac.AccessControl, userActivity useractivity.Service) *QueryHistoryService {
 	s := &QueryHistoryService{
 		store:         sqlStore,
 		Cfg:           cfg,
@@ -20,6 +22,7 @@ func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.Rout
 		log:           log.New("query-history"),
 		now:           time.Now,
 		accessControl: accessControl,
+		userActivity:  userActivity,
 	}
 
 	// Register routes only when query history is enabled
@@ -48,6 +51,7 @@ type QueryHistoryService struct {
 	log           log.Logger
 	now           func() time.Time
 	accessControl ac.AccessControl
+	userActivity  useractivity.Service
 }
 
 func (s QueryHistoryService) CreateQueryInQueryHistory(ctx context.Context, user *user.SignedInUser, cmd CreateQueryInQueryHistoryCommand) (QueryHistoryDTO, error) {
@@ -81,3 +85,19 @@ func (s QueryHistoryService) DeleteStaleQueriesInQueryHistory(ctx context.Contex
 func (s QueryHistoryService) EnforceRowLimitInQueryHistory(ctx context.Context, limit int, starredQueries bool) (int, error) {
 	return s.enforceQueryHistoryRowLimit(ctx, limit, starredQueries)
 }
+
+// queryTitle returns the text of the first query with one, or the fallback
+func queryTitle(queries *simplejson.Json, fallback string) string {
+	if queries == nil {
+		return fallback
+	}
+	for i := range len(queries.MustArray()) {
+		query := queries.GetIndex(i)
+		for _, key := range []string{"expr", "query", "rawSql", "target"} {
+			if text := query.Get(key).MustString(); text != "" {
+				return text
+			}
+		}
+	}
+	return fallback
+}
@@ -17,6 +17,7 @@ var ErrUsageFoundForTarget = errutil.NotFound("quota.missing-target-usage")
 var ErrTargetSrvConflict = errutil.BadRequest("quota.target-srv-conflict")
 var ErrDisabled = errutil.Forbidden("quota.disabled", errutil.WithPublicMessage("Quotas not enabled"))
 var ErrInvalidTagFormat = errutil.Internal("quota.invalid-invalid-tag-format")
+var ErrInvalidSoftLimit = errutil.BadRequest("quota.invalid-soft-limit", errutil.WithPublicMessage("The soft limit must be -1, 0 or lower than the limit"))
 
 type ScopeParameters struct {
 	OrgID  int64
@@ -181,23 +182,38 @@ func (m *Map) Targets() (map[Target]struct{}, error) {
 }
 
 type Quota struct {
-	Id      int64
-	OrgId   int64
-	UserId  int64
-	Target  string
-	Limit   int64
-	Created time.Time
-	Updated time.Time
+	Id     int64
+	OrgId  int64
+	UserId int64
+	Target string
+	Limit  int64
+	// SoftLimit is the usage at which the quota warns, 0 is the configured percentage of the limit and -1 disables it
+	SoftLimit int64
+	Created   time.Time
+	Updated   time.Time
 }
 
+// Status of the usage of a quota
+type Status string
+
+const (
+	StatusOK Status = "ok"
+	// StatusWarning is the status of the quotas whose usage reached the soft limit
+	StatusWarning Status = "warning"
+	StatusReached Status = "reached"
+)
+
 type QuotaDTO struct {
-	OrgId   int64  `json:"org_id,omitempty"`
-	UserId  int64  `json:"user_id,omitempty"`
-	Target  string `json:"target"`
-	Limit   int64  `json:"limit"`
-	Used    int64  `json:"used"`
-	Service string `json:"-"`
-	Scope   string `json:"-"`
+	OrgId  int64  `json:"org_id,omitempty"`
+	UserId int64  `json:"user_id,omitempty"`
+	Target string `json:"target"`
+	Limit  int64  `json:"limit"`
+	// SoftLimit is the usage at which the quota warns, -1 when it doesn't warn
+	SoftLimit int64  `json:"soft_limit"`
+	Used      int64  `json:"used"`
+	Status    Status `json:"status"`
+	Service   string `json:"-"`
+	Scope     string `json:"-"`
 }
 
 func (dto QuotaDTO) Tag() (Tag, error) {
@@ -207,8 +223,11 @@ func (dto QuotaDTO) Tag() (Tag, error) {
 type UpdateQuotaCmd struct {
 	Target string `json:"target"`
 	Limit  int64  `json:"limit"`
-	OrgID  int64  `json:"-"`
-	UserID int64  `json:"-"`
+	// SoftLimit is the usage at which the quota warns, 0 is the configured percentage of the limit and -1 disables
+	// it. The soft limit is kept when it's not set.
+	SoftLimit *int64 `json:"soft_limit,omitempty"`
+	OrgID     int64  `json:"-"`
+	UserID    int64  `json:"-"`
 }
 
 type NewUsageReporter struct {
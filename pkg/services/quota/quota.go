@@ -17,8 +17,11 @@ type Service interface {
 	Update(ctx context.Context, cmd *UpdateQuotaCmd) error
 	// QuotaReached is called by the quota middleware for applying quota enforcement to API handlers
 	QuotaReached(c *contextmodel.ReqContext, targetSrv TargetSrv) (bool, error)
-	// CheckQuotaReached checks if the quota limitations have been reached for a specific service
+	// CheckQuotaReached checks if the quota limitations have been reached for a specific service.
+	// It publishes an events.QuotaSoftLimitReached event when the usage of a quota reached its soft limit.
 	CheckQuotaReached(ctx context.Context, targetSrv TargetSrv, scopeParams *ScopeParameters) (bool, error)
+	// GetLimit returns the limit of the tag for the scope parameters, the custom limit when it's overridden
+	GetLimit(ctx context.Context, tag Tag, scopeParams *ScopeParameters) (int64, error)
 	// DeleteQuotaForUser deletes custom quota limitations for the user
 	DeleteQuotaForUser(ctx context.Context, userID int64) error
 	// DeleteByOrg(ctx context.Context, orgID int64) error
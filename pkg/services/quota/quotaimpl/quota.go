@@ -2,14 +2,19 @@ package quotaimpl
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	"go.opentelemetry.io/otel"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/configprovider"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/quota"
 )
@@ -18,6 +23,9 @@ import (
 // initialized tracer from the opentelemetry package.
 var tracer = otel.Tracer("github.com/grafana/grafana/pkg/services/quota/quotaimpl/service")
 
+// softLimitNotificationInterval is the interval between two notifications of a quota whose usage reached its soft limit
+const softLimitNotificationInterval = 24 * time.Hour
+
 type serviceDisabled struct{}
 
 func (s *serviceDisabled) QuotaReached(c *contextmodel.ReqContext, targetSrv quota.TargetSrv) (bool, error) {
@@ -36,6 +44,10 @@ func (s *serviceDisabled) CheckQuotaReached(ctx context.Context, targetSrv quota
 	return false, nil
 }
 
+func (s *serviceDisabled) GetLimit(ctx context.Context, tag quota.Tag, scopeParams *quota.ScopeParameters) (int64, error) {
+	return 0, quota.ErrDisabled
+}
+
 func (s *serviceDisabled) DeleteQuotaForUser(ctx context.Context, userID int64) error {
 	return nil
 }
@@ -47,7 +59,9 @@ func (s *serviceDisabled) RegisterQuotaReporter(e *quota.NewUsageReporter) error
 type service struct {
 	store  store
 	cfg    configprovider.ConfigProvider
+	bus    bus.Bus
 	logger log.Logger
+	now    func() time.Time
 
 	mutex     sync.RWMutex
 	reporters map[quota.TargetSrv]quota.UsageReporterFunc
@@ -55,17 +69,24 @@ type service struct {
 	defaultLimits *quota.Map
 
 	targetToSrv *quota.TargetToSrv
+
+	// notified is when the quotas whose usage reached their soft limit were last notified
+	notifiedMutex sync.Mutex
+	notified      map[string]time.Time
 }
 
-func ProvideService(ctx context.Context, db db.DB, configProvider configprovider.ConfigProvider) quota.Service {
+func ProvideService(ctx context.Context, db db.DB, configProvider configprovider.ConfigProvider, bus bus.Bus) quota.Service {
 	logger := log.New("quota_service")
 	s := service{
 		store:         &sqlStore{db: db, logger: logger},
 		cfg:           configProvider,
+		bus:           bus,
 		logger:        logger,
+		now:           time.Now,
 		reporters:     make(map[quota.TargetSrv]quota.UsageReporterFunc),
 		defaultLimits: &quota.Map{},
 		targetToSrv:   quota.NewTargetToSrv(),
+		notified:      make(map[string]time.Time),
 	}
 
 	if s.IsDisabled(ctx) {
@@ -116,10 +137,11 @@ func (s *service) GetQuotasByScope(ctx context.Context, scope quota.Scope, id in
 	}
 
 	c := quota.FromContext(ctx, s.targetToSrv)
-	customLimits, err := s.store.Get(c, &scopeParams)
+	customLimits, customSoftLimits, err := s.store.Get(c, &scopeParams)
 	if err != nil {
 		return nil, err
 	}
+	softLimitPercent := s.cfg.Get(ctx).Quota.SoftLimitPercent
 
 	u, err := s.getUsage(ctx, &scopeParams)
 	if err != nil {
@@ -138,9 +160,12 @@ func (s *service) GetQuotasByScope(ctx context.Context, scope quota.Scope, id in
 			continue
 		}
 
+		var customSoftLimit int64
 		if targetCustomLimit, ok := customLimits.Get(item.Tag); ok {
 			limit = targetCustomLimit
+			customSoftLimit, _ = customSoftLimits.Get(item.Tag)
 		}
+		softLimit := effectiveSoftLimit(limit, customSoftLimit, softLimitPercent)
 
 		target, err := item.Tag.GetTarget()
 		if err != nil {
@@ -154,13 +179,15 @@ func (s *service) GetQuotasByScope(ctx context.Context, scope quota.Scope, id in
 
 		used, _ := u.Get(item.Tag)
 		q = append(q, quota.QuotaDTO{
-			Target:  string(target),
-			Limit:   limit,
-			OrgId:   scopeParams.OrgID,
-			UserId:  scopeParams.UserID,
-			Used:    used,
-			Service: string(srv),
-			Scope:   string(scope),
+			Target:    string(target),
+			Limit:     limit,
+			SoftLimit: softLimit,
+			OrgId:     scopeParams.OrgID,
+			UserId:    scopeParams.UserID,
+			Used:      used,
+			Status:    usageStatus(limit, softLimit, used),
+			Service:   string(srv),
+			Scope:     string(scope),
 		})
 	}
 
@@ -184,6 +211,9 @@ func (s *service) Update(ctx context.Context, cmd *quota.UpdateQuotaCmd) error {
 	if !targetFound {
 		return quota.ErrInvalidTarget.Errorf("unknown quota target: %s", cmd.Target)
 	}
+	if cmd.SoftLimit != nil && (*cmd.SoftLimit < -1 || (*cmd.SoftLimit > 0 && *cmd.SoftLimit >= cmd.Limit)) {
+		return quota.ErrInvalidSoftLimit.Errorf("invalid soft limit %d for the limit %d", *cmd.SoftLimit, cmd.Limit)
+	}
 
 	c := quota.FromContext(ctx, s.targetToSrv)
 	return s.store.Update(c, cmd)
@@ -207,11 +237,13 @@ func (s *service) CheckQuotaReached(ctx context.Context, targetSrv quota.TargetS
 		return false, err
 	}
 
+	softLimitPercent := s.cfg.Get(ctx).Quota.SoftLimitPercent
 	for t, limit := range targetSrvLimits {
 		switch {
-		case limit < 0:
+		case limit.value < 0:
 			continue
-		case limit == 0:
+		case limit.value == 0:
+			observeLimitReached(t)
 			return true, nil
 		default:
 			scope, err := t.GetScope()
@@ -233,14 +265,109 @@ func (s *service) CheckQuotaReached(ctx context.Context, targetSrv quota.TargetS
 			if !ok {
 				return false, quota.ErrUsageFoundForTarget.Errorf("no usage for target:%s", t)
 			}
-			if u >= limit {
+			if u >= limit.value {
+				observeLimitReached(t)
 				return true, nil
 			}
+			if softLimit := effectiveSoftLimit(limit.value, limit.soft, softLimitPercent); softLimit > 0 && u >= softLimit {
+				s.notifySoftLimitReached(ctx, t, scopeParams, limit.value, softLimit, u)
+			}
 		}
 	}
 	return false, nil
 }
 
+func (s *service) GetLimit(ctx context.Context, tag quota.Tag, scopeParams *quota.ScopeParameters) (int64, error) {
+	ctx, span := tracer.Start(ctx, "quota-service.GetLimit")
+	defer span.End()
+
+	c := quota.FromContext(ctx, s.targetToSrv)
+	customLimits, _, err := s.store.Get(c, scopeParams)
+	if err != nil {
+		return 0, err
+	}
+	if limit, ok := customLimits.Get(tag); ok {
+		return limit, nil
+	}
+	if limit, ok := s.defaultLimits.Get(tag); ok {
+		return limit, nil
+	}
+	return 0, quota.ErrInvalidTarget.Errorf("unknown quota tag: %s", tag)
+}
+
+// notifySoftLimitReached publishes the event of a quota whose usage reached its soft limit. A quota is notified once
+// per interval, so that every check of a quota close to its limit doesn't notify the subscribers.
+func (s *service) notifySoftLimitReached(ctx context.Context, t quota.Tag, scopeParams *quota.ScopeParameters, limit, softLimit, used int64) {
+	target, _ := t.GetTarget()
+	scope, _ := t.GetScope()
+	evt := &events.QuotaSoftLimitReached{
+		Timestamp: s.now(),
+		Target:    string(target),
+		Scope:     string(scope),
+		Limit:     limit,
+		SoftLimit: softLimit,
+		Used:      used,
+	}
+	switch scope {
+	case quota.OrgScope:
+		evt.OrgID = scopeParams.OrgID
+	case quota.UserScope:
+		evt.UserID = scopeParams.UserID
+	}
+
+	key := fmt.Sprintf("%s:%d:%d", t, evt.OrgID, evt.UserID)
+	s.notifiedMutex.Lock()
+	if last, ok := s.notified[key]; ok && evt.Timestamp.Sub(last) < softLimitNotificationInterval {
+		s.notifiedMutex.Unlock()
+		return
+	}
+	s.notified[key] = evt.Timestamp
+	s.notifiedMutex.Unlock()
+
+	metrics.MQuotaSoftLimitReached.WithLabelValues(evt.Target, evt.Scope).Inc()
+	s.logger.Warn("Quota soft limit reached", "target", evt.Target, "scope", evt.Scope, "orgID", evt.OrgID, "userID", evt.UserID,
+		"limit", limit, "softLimit", softLimit, "used", used)
+	// the tests that don't need the events don't set the bus
+	if s.bus == nil {
+		return
+	}
+	if err := s.bus.Publish(ctx, evt); err != nil {
+		s.logger.Error("Failed to publish the quota soft limit event", "target", evt.Target, "error", err)
+	}
+}
+
+func observeLimitReached(t quota.Tag) {
+	target, _ := t.GetTarget()
+	scope, _ := t.GetScope()
+	metrics.MQuotaLimitReached.WithLabelValues(string(target), string(scope)).Inc()
+}
+
+// effectiveSoftLimit returns the usage at which a quota warns, -1 when it doesn't warn. The custom soft limit is 0
+// when it's the percentage of the limit and -1 when it's disabled.
+func effectiveSoftLimit(limit, customSoftLimit, percent int64) int64 {
+	switch {
+	case limit <= 0 || customSoftLimit < 0:
+		return -1
+	case customSoftLimit > 0 && customSoftLimit < limit:
+		return customSoftLimit
+	case percent > 0 && percent < 100 && limit*percent/100 > 0:
+		return limit * percent / 100
+	default:
+		return -1
+	}
+}
+
+func usageStatus(limit, softLimit, used int64) quota.Status {
+	switch {
+	case limit == 0 || (limit > 0 && used >= limit):
+		return quota.StatusReached
+	case softLimit > 0 && used >= softLimit:
+		return quota.StatusWarning
+	default:
+		return quota.StatusOK
+	}
+}
+
 func (s *service) DeleteQuotaForUser(ctx context.Context, userID int64) error {
 	ctx, span := tracer.Start(ctx, "quota-service.DeleteQuotaForUser")
 	defer span.End()
@@ -304,13 +431,19 @@ func (s *service) getReporters() <-chan reporter {
 	return ch
 }
 
-func (s *service) getOverriddenLimits(ctx context.Context, targetSrv quota.TargetSrv, scopeParams *quota.ScopeParameters) (map[quota.Tag]int64, error) {
+// targetLimit is the limit of a target and its custom soft limit
+type targetLimit struct {
+	value int64
+	soft  int64
+}
+
+func (s *service) getOverriddenLimits(ctx context.Context, targetSrv quota.TargetSrv, scopeParams *quota.ScopeParameters) (map[quota.Tag]targetLimit, error) {
 	ctx, span := tracer.Start(ctx, "quota-service.getOverriddenLimits")
 	defer span.End()
-	targetSrvLimits := make(map[quota.Tag]int64)
+	targetSrvLimits := make(map[quota.Tag]targetLimit)
 
 	c := quota.FromContext(ctx, s.targetToSrv)
-	customLimits, err := s.store.Get(c, scopeParams)
+	customLimits, customSoftLimits, err := s.store.Get(c, scopeParams)
 	if err != nil {
 		return targetSrvLimits, err
 	}
@@ -328,9 +461,10 @@ func (s *service) getOverriddenLimits(ctx context.Context, targetSrv quota.Targe
 		defaultLimit := item.Value
 
 		if customLimit, ok := customLimits.Get(item.Tag); ok {
-			targetSrvLimits[item.Tag] = customLimit
+			customSoftLimit, _ := customSoftLimits.Get(item.Tag)
+			targetSrvLimits[item.Tag] = targetLimit{value: customLimit, soft: customSoftLimit}
 		} else {
-			targetSrvLimits[item.Tag] = defaultLimit
+			targetSrvLimits[item.Tag] = targetLimit{value: defaultLimit}
 		}
 	}
 
@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/configprovider"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
@@ -49,6 +50,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/quota/quotatest"
 	"github.com/grafana/grafana/pkg/services/search/sort"
+	"github.com/grafana/grafana/pkg/services/secretaccess/secretaccesstest"
 	"github.com/grafana/grafana/pkg/services/secrets/fakes"
 	secretskvs "github.com/grafana/grafana/pkg/services/secrets/kvstore"
 	secretsmng "github.com/grafana/grafana/pkg/services/secrets/manager"
@@ -75,6 +77,38 @@ func TestQuotaService(t *testing.T) {
 	})
 }
 
+func TestEffectiveSoftLimit(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		limit           int64
+		customSoftLimit int64
+		percent         int64
+		expected        int64
+	}{
+		{desc: "should be the percentage of the limit", limit: 100, percent: 80, expected: 80},
+		{desc: "should be the custom soft limit", limit: 100, customSoftLimit: 50, percent: 80, expected: 50},
+		{desc: "should be disabled by the custom soft limit", limit: 100, customSoftLimit: -1, percent: 80, expected: -1},
+		{desc: "should ignore a custom soft limit that isn't lower than the limit", limit: 10, customSoftLimit: 10, percent: 50, expected: 5},
+		{desc: "should be disabled when the percentage is 0", limit: 100, expected: -1},
+		{desc: "should be disabled when the percentage of the limit is 0", limit: 1, percent: 80, expected: -1},
+		{desc: "should be disabled for unlimited quotas", limit: -1, percent: 80, expected: -1},
+		{desc: "should be disabled for zero quotas", limit: 0, customSoftLimit: 1, percent: 80, expected: -1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.expected, effectiveSoftLimit(tc.limit, tc.customSoftLimit, tc.percent))
+		})
+	}
+}
+
+func TestUsageStatus(t *testing.T) {
+	require.Equal(t, quota.StatusOK, usageStatus(-1, -1, 1000))
+	require.Equal(t, quota.StatusOK, usageStatus(10, 8, 7))
+	require.Equal(t, quota.StatusWarning, usageStatus(10, 8, 8))
+	require.Equal(t, quota.StatusReached, usageStatus(10, 8, 10))
+	require.Equal(t, quota.StatusReached, usageStatus(0, -1, 0))
+}
+
 func TestIntegrationQuotaCommandsAndQueries(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
@@ -108,7 +142,7 @@ func TestIntegrationQuotaCommandsAndQueries(t *testing.T) {
 	b := bus.ProvideBus(tracing.InitializeTracerForTest())
 	cfgProvider, err := configprovider.ProvideService(cfg)
 	require.NoError(t, err)
-	quotaService := ProvideService(context.Background(), sqlStore, cfgProvider)
+	quotaService := ProvideService(context.Background(), sqlStore, cfgProvider, b)
 	orgService, err := orgimpl.ProvideService(sqlStore, cfg, quotaService)
 	require.NoError(t, err)
 	userService, err := userimpl.ProvideService(
@@ -252,7 +286,7 @@ func TestIntegrationQuotaCommandsAndQueries(t *testing.T) {
 
 			cfgProvider, err := configprovider.ProvideService(cfg)
 			require.NoError(t, err)
-			quotaSrv := ProvideService(context.Background(), sqlStore, cfgProvider)
+			quotaSrv := ProvideService(context.Background(), sqlStore, cfgProvider, b)
 			q, err := getQuotaBySrvTargetScope(t, quotaSrv, ngalertmodels.QuotaTargetSrv, ngalertmodels.QuotaTarget, quota.OrgScope, &quota.ScopeParameters{OrgID: o.ID})
 
 			require.NoError(t, err)
@@ -302,6 +336,60 @@ func TestIntegrationQuotaCommandsAndQueries(t *testing.T) {
 		})
 	})
 
+	t.Run("Given saved org quota with a soft limit", func(t *testing.T) {
+		var softLimit int64 = 1
+		orgCmd := quota.UpdateQuotaCmd{
+			OrgID:     o.ID,
+			Target:    org.OrgUserQuotaTarget,
+			Limit:     5,
+			SoftLimit: &softLimit,
+		}
+		err := quotaService.Update(context.Background(), &orgCmd)
+		require.NoError(t, err)
+
+		t.Run("Should report the warning status when the usage reached the soft limit", func(t *testing.T) {
+			q, err := getQuotaBySrvTargetScope(t, quotaService, quota.TargetSrv(org.QuotaTargetSrv), quota.Target(org.OrgUserQuotaTarget), quota.OrgScope, &quota.ScopeParameters{OrgID: o.ID})
+			require.NoError(t, err)
+			require.Equal(t, int64(5), q.Limit)
+			require.Equal(t, softLimit, q.SoftLimit)
+			require.Equal(t, int64(1), q.Used)
+			require.Equal(t, quota.StatusWarning, q.Status)
+		})
+
+		t.Run("Should publish the soft limit event once when the usage reached the soft limit", func(t *testing.T) {
+			received := 0
+			b.AddEventListener(func(ctx context.Context, evt *events.QuotaSoftLimitReached) error {
+				if evt.OrgID == o.ID && evt.Target == org.OrgUserQuotaTarget {
+					received++
+				}
+				return nil
+			})
+
+			for i := 0; i < 2; i++ {
+				reached, err := quotaService.CheckQuotaReached(context.Background(), quota.TargetSrv(org.QuotaTargetSrv), &quota.ScopeParameters{OrgID: o.ID})
+				require.NoError(t, err)
+				require.False(t, reached)
+			}
+			require.Equal(t, 1, received)
+		})
+
+		t.Run("Should keep the soft limit when the limit is updated without it", func(t *testing.T) {
+			err := quotaService.Update(context.Background(), &quota.UpdateQuotaCmd{OrgID: o.ID, Target: org.OrgUserQuotaTarget, Limit: 6})
+			require.NoError(t, err)
+
+			q, err := getQuotaBySrvTargetScope(t, quotaService, quota.TargetSrv(org.QuotaTargetSrv), quota.Target(org.OrgUserQuotaTarget), quota.OrgScope, &quota.ScopeParameters{OrgID: o.ID})
+			require.NoError(t, err)
+			require.Equal(t, int64(6), q.Limit)
+			require.Equal(t, softLimit, q.SoftLimit)
+		})
+
+		t.Run("Should not update a soft limit that isn't lower than the limit", func(t *testing.T) {
+			invalid := int64(6)
+			err := quotaService.Update(context.Background(), &quota.UpdateQuotaCmd{OrgID: o.ID, Target: org.OrgUserQuotaTarget, Limit: 6, SoftLimit: &invalid})
+			require.ErrorIs(t, err, quota.ErrInvalidSoftLimit)
+		})
+	})
+
 	t.Run("Given saved user quota for org", func(t *testing.T) {
 		// update quota for the created user and limit orgs to 1
 		var customUserOrgsLimit int64 = 1
@@ -521,7 +609,7 @@ func setupEnv(t *testing.T, sqlStore db.DB, cfg *setting.Cfg, b bus.Bus, quotaSe
 	secretsStore := secretskvs.NewSQLSecretsKVStore(sqlStore, secretsService, log.New("test.logger"))
 	_, err = dsservice.ProvideService(sqlStore, secretsService, secretsStore, cfg, featuremgmt.WithFeatures(), acmock.New(), acmock.NewMockedPermissionsService(),
 		quotaService, &pluginstore.FakePluginStore{}, &pluginfakes.FakePluginClient{}, plugincontext.
-			ProvideBaseService(cfg, pluginconfig.NewFakePluginRequestConfigProvider()))
+			ProvideBaseService(cfg, pluginconfig.NewFakePluginRequestConfigProvider()), secretaccesstest.NewFakeService())
 	require.NoError(t, err)
 	m := metrics.NewNGAlert(prometheus.NewRegistry())
 
@@ -531,7 +619,7 @@ func setupEnv(t *testing.T, sqlStore db.DB, cfg *setting.Cfg, b bus.Bus, quotaSe
 	_, err = ngalert.ProvideService(
 		cfg, featuremgmt.WithFeatures(), nil, nil, routing.NewRouteRegister(), sqlStore, ngalertfakes.NewFakeKVStore(t), nil, nil, quotaService,
 		secretsService, nil, m, &foldertest.FakeService{}, &acmock.Mock{}, &dashboards.FakeDashboardService{}, nil, b, &acmock.Mock{},
-		annotationstest.NewFakeAnnotationsRepo(), &pluginstore.FakePluginStore{}, tracer, ruleStore, httpclient.NewProvider(), nil, ngalertfakes.NewFakeReceiverPermissionsService(), usertest.NewUserServiceFake(),
+		annotationstest.NewFakeAnnotationsRepo(), &pluginstore.FakePluginStore{}, tracer, ruleStore, httpclient.NewProvider(), nil, ngalertfakes.NewFakeReceiverPermissionsService(), usertest.NewUserServiceFake(), secretaccesstest.NewFakeService(), nil, nil, nil,
 	)
 	require.NoError(t, err)
 	_, err = storesrv.ProvideService(sqlStore, featuremgmt.WithFeatures(), cfg, quotaService, storesrv.ProvideSystemUsersService())
@@ -10,7 +10,8 @@ import (
 )
 
 type store interface {
-	Get(ctx quota.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error)
+	// Get returns the custom limits and soft limits of the scope parameters
+	Get(ctx quota.Context, scopeParams *quota.ScopeParameters) (*quota.Map, *quota.Map, error)
 	Update(ctx quota.Context, cmd *quota.UpdateQuotaCmd) error
 	DeleteByUser(quota.Context, int64) error
 }
@@ -28,29 +29,32 @@ func (ss *sqlStore) DeleteByUser(ctx quota.Context, userID int64) error {
 	})
 }
 
-func (ss *sqlStore) Get(ctx quota.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
+func (ss *sqlStore) Get(ctx quota.Context, scopeParams *quota.ScopeParameters) (*quota.Map, *quota.Map, error) {
 	limits := quota.Map{}
+	softLimits := quota.Map{}
 	if scopeParams == nil {
-		return &limits, nil
+		return &limits, &softLimits, nil
 	}
 
 	if scopeParams.OrgID != 0 {
-		orgLimits, err := ss.getOrgScopeQuota(ctx, scopeParams.OrgID)
+		orgLimits, orgSoftLimits, err := ss.getOrgScopeQuota(ctx, scopeParams.OrgID)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		limits.Merge(orgLimits)
+		softLimits.Merge(orgSoftLimits)
 	}
 
 	if scopeParams.UserID != 0 {
-		userLimits, err := ss.getUserScopeQuota(ctx, scopeParams.UserID)
+		userLimits, userSoftLimits, err := ss.getUserScopeQuota(ctx, scopeParams.UserID)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		limits.Merge(userLimits)
+		softLimits.Merge(userSoftLimits)
 	}
 
-	return &limits, nil
+	return &limits, &softLimits, nil
 }
 
 func (ss *sqlStore) Update(ctx quota.Context, cmd *quota.UpdateQuotaCmd) error {
@@ -67,6 +71,9 @@ func (ss *sqlStore) Update(ctx quota.Context, cmd *quota.UpdateQuotaCmd) error {
 		}
 		quota.Updated = time.Now()
 		quota.Limit = cmd.Limit
+		if cmd.SoftLimit != nil {
+			quota.SoftLimit = *cmd.SoftLimit
+		}
 		if !has {
 			quota.Created = time.Now()
 			// No quota in the DB for this target, so create a new one.
@@ -75,7 +82,7 @@ func (ss *sqlStore) Update(ctx quota.Context, cmd *quota.UpdateQuotaCmd) error {
 			}
 		} else {
 			// update existing quota entry in the DB.
-			_, err := sess.ID(quota.Id).Update(&quota)
+			_, err := sess.ID(quota.Id).MustCols("soft_limit").Update(&quota)
 			if err != nil {
 				return err
 			}
@@ -85,8 +92,9 @@ func (ss *sqlStore) Update(ctx quota.Context, cmd *quota.UpdateQuotaCmd) error {
 	})
 }
 
-func (ss *sqlStore) getUserScopeQuota(ctx quota.Context, userID int64) (*quota.Map, error) {
+func (ss *sqlStore) getUserScopeQuota(ctx quota.Context, userID int64) (*quota.Map, *quota.Map, error) {
 	r := quota.Map{}
+	soft := quota.Map{}
 	err := ss.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
 		quotas := make([]*quota.Quota, 0)
 		if err := sess.Table("quota").Where("user_id=? AND org_id=0", userID).Find(&quotas); err != nil {
@@ -103,14 +111,16 @@ func (ss *sqlStore) getUserScopeQuota(ctx quota.Context, userID int64) (*quota.M
 				return err
 			}
 			r.Set(tag, q.Limit)
+			soft.Set(tag, q.SoftLimit)
 		}
 		return nil
 	})
-	return &r, err
+	return &r, &soft, err
 }
 
-func (ss *sqlStore) getOrgScopeQuota(ctx quota.Context, OrgID int64) (*quota.Map, error) {
+func (ss *sqlStore) getOrgScopeQuota(ctx quota.Context, OrgID int64) (*quota.Map, *quota.Map, error) {
 	r := quota.Map{}
+	soft := quota.Map{}
 	err := ss.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
 		quotas := make([]*quota.Quota, 0)
 		if err := sess.Table("quota").Where("user_id=0 AND org_id=?", OrgID).Find(&quotas); err != nil {
@@ -127,8 +137,9 @@ func (ss *sqlStore) getOrgScopeQuota(ctx quota.Context, OrgID int64) (*quota.Map
 				return err
 			}
 			r.Set(tag, q.Limit)
+			soft.Set(tag, q.SoftLimit)
 		}
 		return nil
 	})
-	return &r, err
+	return &r, &soft, err
 }
@@ -32,6 +32,10 @@ func (f *FakeQuotaService) CheckQuotaReached(c context.Context, target quota.Tar
 	return f.reached, f.err
 }
 
+func (f *FakeQuotaService) GetLimit(c context.Context, tag quota.Tag, params *quota.ScopeParameters) (int64, error) {
+	return -1, f.err
+}
+
 func (f *FakeQuotaService) DeleteQuotaForUser(c context.Context, userID int64) error {
 	return f.err
 }
@@ -48,8 +52,8 @@ func (f *FakeQuotaStore) DeleteByUser(ctx quota.Context, userID int64) error {
 	return f.ExpectedError
 }
 
-func (f *FakeQuotaStore) Get(ctx quota.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
-	return nil, f.ExpectedError
+func (f *FakeQuotaStore) Get(ctx quota.Context, scopeParams *quota.ScopeParameters) (*quota.Map, *quota.Map, error) {
+	return nil, nil, f.ExpectedError
 }
 
 func (f *FakeQuotaStore) Update(ctx quota.Context, cmd *quota.UpdateQuotaCmd) error {
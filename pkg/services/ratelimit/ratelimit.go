@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/quota"
+)
+
+// The requests of an organization in a window are limited by the api_request quota of the organization, which defaults
+// to the org limit.
+const (
+	QuotaTargetSrv quota.TargetSrv = "api_request"
+	QuotaTarget    quota.Target    = "api_request"
+)
+
+var ErrRateLimited = errutil.TooManyRequests("ratelimit.exceeded",
+	errutil.WithPublicMessage("Too many requests, retry once the rate limit is reset"))
+
+// Service limits the rate of the requests to the HTTP API by user, token, organization and route group.
+type Service interface {
+	// Allow counts the request of the requester and reports whether it's under all the limits that apply to it. The
+	// usage is the usage of the limit with the fewest remaining requests, nil when no limit applies.
+	Allow(ctx context.Context, requester identity.Requester, req *http.Request) (*Usage, bool)
+	// Usage returns the usage of the limits of the requester without counting a request. The request identifies
+	// the client of the anonymous requesters.
+	Usage(ctx context.Context, requester identity.Requester, req *http.Request) ([]Usage, error)
+}
+
+// Usage is the consumption of a limit in the current window.
+type Usage struct {
+	// Name is user, token, org or the name of the route group
+	Name      string `json:"name"`
+	Limit     int64  `json:"limit"`
+	Used      int64  `json:"used"`
+	Remaining int64  `json:"remaining"`
+	// Reset is the end of the current window
+	Reset time.Time `json:"reset"`
+	// Paths are the path prefixes of the routes of a route group
+	Paths []string `json:"paths,omitempty"`
+}
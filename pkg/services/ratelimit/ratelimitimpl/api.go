@@ -0,0 +1,40 @@
+package ratelimitimpl
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ratelimit"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister) {
+	router.Get("/api/rate-limits", middleware.ReqSignedIn, routing.Wrap(s.GetRateLimits))
+}
+
+// swagger:route GET /rate-limits rate_limits getRateLimits
+//
+// Get the rate limits of the signed in user.
+//
+// Returns the limits that apply to the requests of the signed in user or token, with the number of requests sent in
+// the current window. Getting the rate limits is counted as a request.
+//
+// Responses:
+// 200: getRateLimitsResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *Service) GetRateLimits(c *contextmodel.ReqContext) response.Response {
+	usages, err := s.Usage(c.Req.Context(), c.SignedInUser, c.Req)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get rate limits", err)
+	}
+	return response.JSON(http.StatusOK, usages)
+}
+
+// swagger:response getRateLimitsResponse
+type GetRateLimitsResponse struct {
+	// in:body
+	Body []ratelimit.Usage `json:"body"`
+}
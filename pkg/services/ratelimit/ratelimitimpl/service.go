@@ -0,0 +1,299 @@
+package ratelimitimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	claims "github.com/grafana/authlib/types"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ipallowlist"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/ratelimit"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var _ ratelimit.Service = (*Service)(nil)
+
+const (
+	// orgLimitTTL is how long the org limits read from the quotas are reused
+	orgLimitTTL = time.Minute
+)
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, router routing.RouteRegister, quotaService quota.Service) (*Service, error) {
+	s := &Service{
+		cfg:            cfg.RateLimit,
+		trustedProxies: cfg.IPAllowlist.TrustedProxies,
+		store:          &xormStore{db: sqlStore},
+		quota:          quotaService,
+		orgLimits:      map[int64]orgLimit{},
+		log:            log.New("ratelimit"),
+		now:            time.Now,
+	}
+
+	if !cfg.RateLimit.Enabled {
+		return s, nil
+	}
+
+	s.registerRoutes(router)
+
+	// the api_request quota is only reported when the requests are counted
+	defaultLimits, err := readQuotaConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := quotaService.RegisterQuotaReporter(&quota.NewUsageReporter{
+		TargetSrv:     ratelimit.QuotaTargetSrv,
+		DefaultLimits: defaultLimits,
+		Reporter:      s.QuotaUsage,
+	}); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Service counts the requests of each window in the database, so that the instances share the limits. The anonymous
+// requests are counted by client address, the address is read from the forwarding headers of the trusted proxies only.
+type Service struct {
+	cfg            setting.RateLimitSettings
+	trustedProxies []*net.IPNet
+	store          store
+	quota          quota.Service
+	log            log.Logger
+	now            func() time.Time
+
+	orgLimitsMu sync.Mutex
+	orgLimits   map[int64]orgLimit
+
+	// cleanedWindow is the start of the window the expired counters were last deleted in
+	cleanedMu     sync.Mutex
+	cleanedWindow time.Time
+}
+
+// orgLimit is the limit of the requests of an organization read from its api_request quota
+type orgLimit struct {
+	limit   int64
+	expires time.Time
+}
+
+// limit is a limit that applies to the requests of a requester
+type limit struct {
+	name string
+	// key identifies the requests counted together
+	key   string
+	limit int64
+	// prefixes are the path prefixes of a route group, the other limits apply to all the paths
+	prefixes []string
+}
+
+func (l limit) applies(path string) bool {
+	if l.prefixes == nil {
+		return true
+	}
+	for _, prefix := range l.prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) Allow(ctx context.Context, requester identity.Requester, req *http.Request) (*ratelimit.Usage, bool) {
+	start, reset := s.window()
+	s.deleteExpired(ctx, start)
+
+	var closest *ratelimit.Usage
+	allowed := true
+	for _, l := range s.limits(ctx, requester, req) {
+		if !l.applies(req.URL.Path) {
+			continue
+		}
+		// rejected requests are counted too, clients retrying right away stay rejected until the reset
+		used, err := s.store.Increment(ctx, s.counterKey(l, start), reset.Add(time.Second))
+		if err != nil {
+			// the requests are allowed when the limits can't be checked
+			s.log.Warn("Failed to count request", "limit", l.name, "key", l.key, "error", err)
+			continue
+		}
+		usage := newUsage(l, used, reset)
+		if used > l.limit {
+			allowed = false
+		}
+		if closest == nil || usage.Remaining < closest.Remaining {
+			closest = &usage
+		}
+	}
+	return closest, allowed
+}
+
+func (s *Service) Usage(ctx context.Context, requester identity.Requester, req *http.Request) ([]ratelimit.Usage, error) {
+	start, reset := s.window()
+
+	limits := s.limits(ctx, requester, req)
+	usages := make([]ratelimit.Usage, 0, len(limits))
+	for _, l := range limits {
+		used, err := s.store.Count(ctx, s.counterKey(l, start))
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, newUsage(l, used, reset))
+	}
+	return usages, nil
+}
+
+// QuotaUsage reports the requests of the organization in the current window as the usage of its api_request quota
+func (s *Service) QuotaUsage(ctx context.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
+	usage := &quota.Map{}
+	if scopeParams == nil || scopeParams.OrgID == 0 {
+		return usage, nil
+	}
+
+	tag, err := quota.NewTag(ratelimit.QuotaTargetSrv, ratelimit.QuotaTarget, quota.OrgScope)
+	if err != nil {
+		return nil, err
+	}
+	start, _ := s.window()
+	used, err := s.store.Count(ctx, s.counterKey(limit{name: "org", key: strconv.FormatInt(scopeParams.OrgID, 10)}, start))
+	if err != nil {
+		return nil, err
+	}
+	usage.Set(tag, used)
+	return usage, nil
+}
+
+// limits returns the limits that apply to the requests of the requester
+func (s *Service) limits(ctx context.Context, requester identity.Requester, req *http.Request) []limit {
+	if !s.cfg.Enabled || requester == nil || requester.IsNil() {
+		return nil
+	}
+
+	limits := make([]limit, 0, len(s.cfg.Groups)+2)
+	subject := requester.GetID()
+	if requester.IsIdentityType(claims.TypeAnonymous) {
+		// the anonymous users share an identity, each client gets its own limits
+		subject = "anonymous-" + s.clientIP(req)
+	}
+	if requester.IsAuthenticatedBy(login.APIKeyAuthModule) {
+		if s.cfg.TokenLimit > 0 {
+			limits = append(limits, limit{name: "token", key: subject, limit: s.cfg.TokenLimit})
+		}
+	} else if s.cfg.UserLimit > 0 {
+		limits = append(limits, limit{name: "user", key: subject, limit: s.cfg.UserLimit})
+	}
+	if orgID := requester.GetOrgID(); orgID > 0 {
+		if value := s.orgLimit(ctx, orgID); value >= 0 {
+			limits = append(limits, limit{name: "org", key: strconv.FormatInt(orgID, 10), limit: value})
+		}
+	}
+	for _, group := range s.cfg.Groups {
+		limits = append(limits, limit{name: group.Name, key: subject, limit: group.Limit, prefixes: group.Prefixes})
+	}
+	return limits
+}
+
+// orgLimit returns the limit of the requests of the organization, -1 when they aren't limited. It's the api_request
+// quota of the organization, or the configured org limit when quotas are disabled.
+func (s *Service) orgLimit(ctx context.Context, orgID int64) int64 {
+	s.orgLimitsMu.Lock()
+	cached, ok := s.orgLimits[orgID]
+	s.orgLimitsMu.Unlock()
+	if ok && s.now().Before(cached.expires) {
+		return cached.limit
+	}
+
+	var value int64
+	tag, err := quota.NewTag(ratelimit.QuotaTargetSrv, ratelimit.QuotaTarget, quota.OrgScope)
+	if err == nil {
+		value, err = s.quota.GetLimit(ctx, tag, &quota.ScopeParameters{OrgID: orgID})
+	}
+	if err != nil {
+		if !errors.Is(err, quota.ErrDisabled) {
+			s.log.Warn("Failed to get the api_request quota of the organization", "orgID", orgID, "error", err)
+		}
+		value = defaultOrgLimit(s.cfg)
+	}
+
+	s.orgLimitsMu.Lock()
+	s.orgLimits[orgID] = orgLimit{limit: value, expires: s.now().Add(orgLimitTTL)}
+	s.orgLimitsMu.Unlock()
+	return value
+}
+
+// defaultOrgLimit returns the configured org limit, -1 when it's unlimited
+func defaultOrgLimit(cfg setting.RateLimitSettings) int64 {
+	if cfg.OrgLimit > 0 {
+		return cfg.OrgLimit
+	}
+	return -1
+}
+
+func readQuotaConfig(cfg *setting.Cfg) (*quota.Map, error) {
+	limits := &quota.Map{}
+
+	orgQuotaTag, err := quota.NewTag(ratelimit.QuotaTargetSrv, ratelimit.QuotaTarget, quota.OrgScope)
+	if err != nil {
+		return limits, err
+	}
+
+	limits.Set(orgQuotaTag, defaultOrgLimit(cfg.RateLimit))
+	return limits, nil
+}
+
+// window returns the start and the end of the current window
+func (s *Service) window() (time.Time, time.Time) {
+	start := s.now().Truncate(s.cfg.Window)
+	return start, start.Add(s.cfg.Window)
+}
+
+func (s *Service) counterKey(l limit, start time.Time) string {
+	return fmt.Sprintf("ratelimit-%s-%s-%d", l.name, l.key, start.Unix())
+}
+
+// clientIP returns the address of the client, empty when it can't be read
+func (s *Service) clientIP(req *http.Request) string {
+	if req == nil {
+		return ""
+	}
+	ip := ipallowlist.ClientIP(req, s.trustedProxies)
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
+
+// deleteExpired deletes the counters of the previous windows, once per window
+func (s *Service) deleteExpired(ctx context.Context, start time.Time) {
+	s.cleanedMu.Lock()
+	defer s.cleanedMu.Unlock()
+	if !s.cleanedWindow.Before(start) {
+		return
+	}
+	s.cleanedWindow = start
+
+	if _, err := s.store.DeleteExpired(ctx, start); err != nil {
+		s.log.Warn("Failed to delete the expired rate limit counters", "error", err)
+	}
+}
+
+func newUsage(l limit, used int64, reset time.Time) ratelimit.Usage {
+	return ratelimit.Usage{
+		Name:      l.name,
+		Limit:     l.limit,
+		Used:      used,
+		Remaining: max(l.limit-used, 0),
+		Reset:     reset,
+		Paths:     l.prefixes,
+	}
+}
@@ -0,0 +1,288 @@
+package ratelimitimpl
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	claims "github.com/grafana/authlib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/ratelimit"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationService_Allow(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	user := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1}
+
+	t.Run("should reject the requests over the user limit until the window is reset", func(t *testing.T) {
+		s := setupTestService(t, setting.RateLimitSettings{Enabled: true, Window: time.Minute, UserLimit: 2})
+
+		for i := 0; i < 2; i++ {
+			usage, allowed := s.Allow(ctx, user, newRequest("/api/dashboards/uid/abc"))
+			require.True(t, allowed)
+			assert.Equal(t, "user", usage.Name)
+			assert.Equal(t, int64(1-i), usage.Remaining)
+		}
+		usage, allowed := s.Allow(ctx, user, newRequest("/api/dashboards/uid/abc"))
+		assert.False(t, allowed)
+		assert.Equal(t, int64(0), usage.Remaining)
+		assert.Equal(t, time.Date(2024, 3, 1, 12, 1, 0, 0, time.UTC), usage.Reset)
+
+		s.now = func() time.Time { return time.Date(2024, 3, 1, 12, 1, 0, 0, time.UTC) }
+		_, allowed = s.Allow(ctx, user, newRequest("/api/dashboards/uid/abc"))
+		assert.True(t, allowed)
+	})
+
+	t.Run("should count the requests of the API keys apart from the users", func(t *testing.T) {
+		s := setupTestService(t, setting.RateLimitSettings{Enabled: true, Window: time.Minute, UserLimit: 1, TokenLimit: 5})
+		token := &identity.StaticRequester{Type: claims.TypeServiceAccount, UserID: 2, OrgID: 1, AuthenticatedBy: login.APIKeyAuthModule}
+
+		_, allowed := s.Allow(ctx, user, newRequest("/api/search"))
+		require.True(t, allowed)
+		usage, allowed := s.Allow(ctx, token, newRequest("/api/search"))
+		require.True(t, allowed)
+		assert.Equal(t, "token", usage.Name)
+		assert.Equal(t, int64(4), usage.Remaining)
+	})
+
+	t.Run("should share the org limit between the users of the org", func(t *testing.T) {
+		s := setupTestService(t, setting.RateLimitSettings{Enabled: true, Window: time.Minute, UserLimit: 10, OrgLimit: 1})
+		other := &identity.StaticRequester{Type: claims.TypeUser, UserID: 3, OrgID: 1}
+
+		_, allowed := s.Allow(ctx, user, newRequest("/api/search"))
+		require.True(t, allowed)
+		usage, allowed := s.Allow(ctx, other, newRequest("/api/search"))
+		assert.False(t, allowed)
+		assert.Equal(t, "org", usage.Name)
+	})
+
+	t.Run("should only apply the group limits to the paths of the group", func(t *testing.T) {
+		s := setupTestService(t, setting.RateLimitSettings{Enabled: true, Window: time.Minute, Groups: []setting.RateLimitGroup{
+			{Name: "query", Prefixes: []string{"/api/ds/query"}, Limit: 1},
+		}})
+
+		usage, allowed := s.Allow(ctx, user, newRequest("/api/search"))
+		assert.True(t, allowed)
+		assert.Nil(t, usage)
+
+		_, allowed = s.Allow(ctx, user, newRequest("/api/ds/query"))
+		require.True(t, allowed)
+		usage, allowed = s.Allow(ctx, user, newRequest("/api/ds/query"))
+		assert.False(t, allowed)
+		assert.Equal(t, "query", usage.Name)
+	})
+
+	t.Run("should allow every request when disabled", func(t *testing.T) {
+		s := setupTestService(t, setting.RateLimitSettings{Enabled: false, Window: time.Minute, UserLimit: 1})
+
+		for i := 0; i < 3; i++ {
+			usage, allowed := s.Allow(ctx, user, newRequest("/api/search"))
+			assert.True(t, allowed)
+			assert.Nil(t, usage)
+		}
+	})
+}
+
+func TestIntegrationService_Anonymous(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	anonymous := &identity.StaticRequester{Type: claims.TypeAnonymous, OrgID: 1}
+	fromClient := func(remoteAddr, forwardedFor string) *http.Request {
+		req := newRequest("/api/search")
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		return req
+	}
+
+	t.Run("should count the anonymous requests of each client apart", func(t *testing.T) {
+		s := setupTestService(t, setting.RateLimitSettings{Enabled: true, Window: time.Minute, UserLimit: 1})
+
+		_, allowed := s.Allow(ctx, anonymous, fromClient("198.51.100.1:1234", ""))
+		require.True(t, allowed)
+		_, allowed = s.Allow(ctx, anonymous, fromClient("198.51.100.2:1234", ""))
+		assert.True(t, allowed, "another client has its own limit")
+		_, allowed = s.Allow(ctx, anonymous, fromClient("198.51.100.1:4321", ""))
+		assert.False(t, allowed)
+	})
+
+	t.Run("should ignore the forwarding headers of the clients which aren't trusted proxies", func(t *testing.T) {
+		s := setupTestService(t, setting.RateLimitSettings{Enabled: true, Window: time.Minute, UserLimit: 1})
+
+		_, allowed := s.Allow(ctx, anonymous, fromClient("198.51.100.1:1234", "203.0.113.1"))
+		require.True(t, allowed)
+		_, allowed = s.Allow(ctx, anonymous, fromClient("198.51.100.1:1234", "203.0.113.2"))
+		assert.False(t, allowed, "a spoofed header doesn't reset the limit")
+	})
+
+	t.Run("should read the client address from the trusted proxies", func(t *testing.T) {
+		s := setupTestService(t, setting.RateLimitSettings{Enabled: true, Window: time.Minute, UserLimit: 1})
+		_, proxy, err := net.ParseCIDR("10.0.0.0/8")
+		require.NoError(t, err)
+		s.trustedProxies = []*net.IPNet{proxy}
+
+		_, allowed := s.Allow(ctx, anonymous, fromClient("10.0.0.1:1234", "203.0.113.1"))
+		require.True(t, allowed)
+		_, allowed = s.Allow(ctx, anonymous, fromClient("10.0.0.2:1234", "203.0.113.2"))
+		assert.True(t, allowed)
+		_, allowed = s.Allow(ctx, anonymous, fromClient("10.0.0.2:1234", "203.0.113.1"))
+		assert.False(t, allowed)
+	})
+}
+
+func TestIntegrationService_Instances(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	user := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1}
+	cfg := setting.RateLimitSettings{Enabled: true, Window: time.Minute, UserLimit: 1000}
+	sqlStore := db.InitTestDB(t)
+	instances := []*Service{newTestService(&xormStore{db: sqlStore}, cfg), newTestService(&xormStore{db: sqlStore}, cfg)}
+
+	const requests = 20
+	var wg sync.WaitGroup
+	for _, s := range instances {
+		for i := 0; i < requests; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = s.Allow(ctx, user, newRequest("/api/search"))
+			}()
+		}
+	}
+	wg.Wait()
+
+	for _, s := range instances {
+		usages, err := s.Usage(ctx, user, newRequest("/api/rate-limits"))
+		require.NoError(t, err)
+		require.Len(t, usages, 1)
+		assert.Equal(t, int64(2*requests), usages[0].Used, "the concurrent requests to the instances are all counted")
+	}
+}
+
+func TestIntegrationService_Usage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	user := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1}
+	s := setupTestService(t, setting.RateLimitSettings{Enabled: true, Window: time.Minute, UserLimit: 10, OrgLimit: 100, Groups: []setting.RateLimitGroup{
+		{Name: "query", Prefixes: []string{"/api/ds/query"}, Limit: 5},
+	}})
+
+	_, _ = s.Allow(ctx, user, newRequest("/api/ds/query"))
+	_, _ = s.Allow(ctx, user, newRequest("/api/search"))
+
+	usages, err := s.Usage(ctx, user, newRequest("/api/rate-limits"))
+	require.NoError(t, err)
+	require.Len(t, usages, 3)
+	assert.Equal(t, "user", usages[0].Name)
+	assert.Equal(t, int64(2), usages[0].Used)
+	assert.Equal(t, int64(8), usages[0].Remaining)
+	assert.Equal(t, "org", usages[1].Name)
+	assert.Equal(t, int64(2), usages[1].Used)
+	assert.Equal(t, "query", usages[2].Name)
+	assert.Equal(t, int64(1), usages[2].Used)
+	assert.Equal(t, []string{"/api/ds/query"}, usages[2].Paths)
+}
+
+func TestIntegrationService_OrgQuota(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	user := &identity.StaticRequester{Type: claims.TypeUser, UserID: 1, OrgID: 1}
+	other := &identity.StaticRequester{Type: claims.TypeUser, UserID: 2, OrgID: 2}
+
+	t.Run("should limit the requests of an org to its api_request quota", func(t *testing.T) {
+		s := setupTestService(t, setting.RateLimitSettings{Enabled: true, Window: time.Minute, OrgLimit: 5})
+		s.quota = &fakeQuotaService{FakeQuotaService: quotatest.New(false, nil), limits: map[int64]int64{1: 1, 2: -1}}
+
+		_, allowed := s.Allow(ctx, user, newRequest("/api/search"))
+		require.True(t, allowed)
+		usage, allowed := s.Allow(ctx, user, newRequest("/api/search"))
+		assert.False(t, allowed)
+		assert.Equal(t, "org", usage.Name)
+		assert.Equal(t, int64(1), usage.Limit)
+
+		for i := 0; i < 10; i++ {
+			_, allowed = s.Allow(ctx, other, newRequest("/api/search"))
+			assert.True(t, allowed)
+		}
+	})
+
+	t.Run("should fall back to the org limit when quotas are disabled", func(t *testing.T) {
+		s := setupTestService(t, setting.RateLimitSettings{Enabled: true, Window: time.Minute, OrgLimit: 5})
+
+		usage, allowed := s.Allow(ctx, user, newRequest("/api/search"))
+		require.True(t, allowed)
+		assert.Equal(t, int64(5), usage.Limit)
+	})
+
+	t.Run("should report the requests of the org in the window as the quota usage", func(t *testing.T) {
+		s := setupTestService(t, setting.RateLimitSettings{Enabled: true, Window: time.Minute, OrgLimit: 5})
+		for i := 0; i < 3; i++ {
+			_, _ = s.Allow(ctx, user, newRequest("/api/search"))
+		}
+
+		usage, err := s.QuotaUsage(ctx, &quota.ScopeParameters{OrgID: 1})
+		require.NoError(t, err)
+		tag, err := quota.NewTag(ratelimit.QuotaTargetSrv, ratelimit.QuotaTarget, quota.OrgScope)
+		require.NoError(t, err)
+		used, ok := usage.Get(tag)
+		require.True(t, ok)
+		assert.Equal(t, int64(3), used)
+	})
+}
+
+type fakeQuotaService struct {
+	*quotatest.FakeQuotaService
+	limits map[int64]int64
+}
+
+func (f *fakeQuotaService) GetLimit(ctx context.Context, tag quota.Tag, scopeParams *quota.ScopeParameters) (int64, error) {
+	return f.limits[scopeParams.OrgID], nil
+}
+
+func setupTestService(t *testing.T, cfg setting.RateLimitSettings) *Service {
+	t.Helper()
+	return newTestService(&xormStore{db: db.InitTestDB(t)}, cfg)
+}
+
+func newTestService(store store, cfg setting.RateLimitSettings) *Service {
+	return &Service{
+		cfg:       cfg,
+		store:     store,
+		quota:     quotatest.New(false, quota.ErrDisabled),
+		orgLimits: map[int64]orgLimit{},
+		log:       log.NewNopLogger(),
+		now:       func() time.Time { return time.Date(2024, 3, 1, 12, 0, 30, 0, time.UTC) },
+	}
+}
+
+func newRequest(path string) *http.Request {
+	return httptest.NewRequest(http.MethodGet, path, nil)
+}
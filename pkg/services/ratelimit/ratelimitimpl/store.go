@@ -0,0 +1,91 @@
+package ratelimitimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+type rateLimitCounter struct {
+	ID    int64  `xorm:"pk autoincr 'id'"`
+	Key   string `xorm:"counter_key"`
+	Count int64  `xorm:"request_count"`
+	// Expires is the unix time after which the counter can be deleted
+	Expires int64 `xorm:"expires"`
+}
+
+func (rateLimitCounter) TableName() string {
+	return "rate_limit_counter"
+}
+
+type store interface {
+	// Increment increments the counter of the key and returns its new value. The counter is incremented in the
+	// database, so that the instances sharing it count every request.
+	Increment(ctx context.Context, key string, expires time.Time) (int64, error)
+	// Count returns the value of the counter of the key, 0 when it doesn't exist
+	Count(ctx context.Context, key string) (int64, error)
+	// DeleteExpired deletes the counters expired before the time and returns how many were deleted
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (s *xormStore) Increment(ctx context.Context, key string, expires time.Time) (int64, error) {
+	var count int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		updated, err := s.increment(sess, key)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			_, insertErr := sess.Insert(&rateLimitCounter{Key: key, Count: 1, Expires: expires.Unix()})
+			if insertErr != nil {
+				// another instance may have inserted the counter in the meantime
+				if updated, err = s.increment(sess, key); err != nil {
+					return err
+				}
+				if !updated {
+					return insertErr
+				}
+			}
+		}
+
+		_, err = sess.Table("rate_limit_counter").Where("counter_key = ?", key).Cols("request_count").Get(&count)
+		return err
+	})
+	return count, err
+}
+
+func (s *xormStore) increment(sess *db.Session, key string) (bool, error) {
+	res, err := sess.Exec("UPDATE rate_limit_counter SET request_count = request_count + 1 WHERE counter_key = ?", key)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+func (s *xormStore) Count(ctx context.Context, key string) (int64, error) {
+	var count int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Table("rate_limit_counter").Where("counter_key = ?", key).Cols("request_count").Get(&count)
+		return err
+	})
+	return count, err
+}
+
+func (s *xormStore) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM rate_limit_counter WHERE expires < ?", before.Unix())
+		if err != nil {
+			return err
+		}
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	return deleted, err
+}
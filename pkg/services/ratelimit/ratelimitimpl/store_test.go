@@ -0,0 +1,48 @@
+package ratelimitimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	store := &xormStore{db: db.InitTestDB(t)}
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	count, err := store.Count(ctx, "ratelimit-user-1")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	for i := int64(1); i <= 3; i++ {
+		count, err = store.Increment(ctx, "ratelimit-user-1", now.Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, i, count)
+	}
+	_, err = store.Increment(ctx, "ratelimit-user-2", now.Add(2*time.Minute))
+	require.NoError(t, err)
+
+	count, err = store.Count(ctx, "ratelimit-user-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	deleted, err := store.DeleteExpired(ctx, now.Add(90*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	count, err = store.Count(ctx, "ratelimit-user-1")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	count, err = store.Count(ctx, "ratelimit-user-2")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
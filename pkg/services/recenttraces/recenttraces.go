@@ -0,0 +1,43 @@
+package recenttraces
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	ReasonError = "error"
+	ReasonSlow  = "slow"
+)
+
+// Service keeps the traces of the recent slow and failed API requests, so that a user complaint can be followed to
+// the trace of the request in the tracing backend.
+type Service interface {
+	// Record keeps the trace when the request failed or was slow, it's dropped otherwise.
+	Record(ctx context.Context, trace *Trace)
+	// Recent returns the recent traces matching the query, the most recent first.
+	Recent(ctx context.Context, query *Query) []*Trace
+}
+
+// Trace is the trace of an API request.
+type Trace struct {
+	TraceID    string        `json:"traceId"`
+	Timestamp  time.Time     `json:"timestamp"`
+	Method     string        `json:"method"`
+	Route      string        `json:"route,omitempty"`
+	Path       string        `json:"path"`
+	StatusCode int           `json:"statusCode"`
+	Duration   time.Duration `json:"-"`
+	// DurationMs is the duration of the request in milliseconds
+	DurationMs int64 `json:"durationMs"`
+	// Reason is error when the request failed with a 5xx status, slow otherwise
+	Reason string `json:"reason"`
+	// URL is the URL of the trace in the tracing backend, empty when it isn't configured
+	URL string `json:"url,omitempty"`
+}
+
+type Query struct {
+	// Reason filters the traces by reason when set
+	Reason string
+	Limit  int
+}
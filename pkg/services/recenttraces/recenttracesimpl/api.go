@@ -0,0 +1,67 @@
+package recenttracesimpl
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/recenttraces"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+
+	router.Group("/api/admin/traces", func(tracesRoute routing.RouteRegister) {
+		tracesRoute.Get("/recent", authorize(ac.EvalPermission(ac.ActionServerStatsRead)), routing.Wrap(s.GetRecentTraces))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /admin/traces/recent admin getRecentTraces
+//
+// List the traces of the recent slow and failed API requests.
+//
+// Returns the traces of the requests handled by the instance, the most recent first. Only sampled traces are kept.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getRecentTracesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+func (s *Service) GetRecentTraces(c *contextmodel.ReqContext) response.Response {
+	query := &recenttraces.Query{
+		Reason: c.Query("reason"),
+		Limit:  c.QueryInt("limit"),
+	}
+	switch query.Reason {
+	case "", recenttraces.ReasonError, recenttraces.ReasonSlow:
+	default:
+		return response.Error(http.StatusBadRequest, "reason must be error or slow", nil)
+	}
+
+	return response.JSON(http.StatusOK, s.Recent(c.Req.Context(), query))
+}
+
+// swagger:parameters getRecentTraces
+type GetRecentTracesParams struct {
+	// Only return the traces of the failed (error) or slow (slow) requests.
+	// in:query
+	// required:false
+	// enum: error,slow
+	Reason string `json:"reason"`
+	// Maximum number of traces to return, all of them by default.
+	// in:query
+	// required:false
+	Limit int `json:"limit"`
+}
+
+// swagger:response getRecentTracesResponse
+type GetRecentTracesResponse struct {
+	// in:body
+	Body []*recenttraces.Trace `json:"body"`
+}
@@ -0,0 +1,84 @@
+package recenttracesimpl
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/recenttraces"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var _ recenttraces.Service = (*Service)(nil)
+
+func ProvideService(cfg *setting.Cfg, router routing.RouteRegister, accessControl ac.AccessControl) *Service {
+	s := &Service{
+		cfg:    cfg.RecentTraces,
+		traces: make([]*recenttraces.Trace, cfg.RecentTraces.Size),
+	}
+
+	if cfg.RecentTraces.Size > 0 {
+		s.registerRoutes(router, accessControl)
+	}
+
+	return s
+}
+
+// Service keeps the recent traces in memory, in a ring buffer overwriting the oldest trace once it's full. Each
+// instance lists the requests it handled.
+type Service struct {
+	cfg setting.RecentTracesSettings
+
+	mu     sync.Mutex
+	traces []*recenttraces.Trace
+	// next is the index of the next trace in traces
+	next int
+}
+
+func (s *Service) Record(_ context.Context, trace *recenttraces.Trace) {
+	if len(s.traces) == 0 || trace.TraceID == "" {
+		return
+	}
+
+	switch {
+	case trace.StatusCode >= http.StatusInternalServerError:
+		trace.Reason = recenttraces.ReasonError
+	case trace.Duration >= s.cfg.SlowThreshold:
+		trace.Reason = recenttraces.ReasonSlow
+	default:
+		return
+	}
+	trace.DurationMs = trace.Duration.Milliseconds()
+	if s.cfg.TraceURL != "" {
+		trace.URL = strings.ReplaceAll(s.cfg.TraceURL, "{traceId}", trace.TraceID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traces[s.next] = trace
+	s.next = (s.next + 1) % len(s.traces)
+}
+
+func (s *Service) Recent(_ context.Context, query *recenttraces.Query) []*recenttraces.Trace {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]*recenttraces.Trace, 0)
+	for i := 1; i <= len(s.traces); i++ {
+		trace := s.traces[(s.next-i+len(s.traces))%len(s.traces)]
+		if trace == nil {
+			break
+		}
+		if query.Reason != "" && trace.Reason != query.Reason {
+			continue
+		}
+		result = append(result, trace)
+		if query.Limit > 0 && len(result) == query.Limit {
+			break
+		}
+	}
+	return result
+}
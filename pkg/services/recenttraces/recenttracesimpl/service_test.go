@@ -0,0 +1,69 @@
+package recenttracesimpl
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/recenttraces"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService_Record(t *testing.T) {
+	ctx := context.Background()
+	setup := func(size int) *Service {
+		cfg := setting.NewCfg()
+		cfg.RecentTraces = setting.RecentTracesSettings{
+			Size:          size,
+			SlowThreshold: time.Second,
+			TraceURL:      "https://tempo.example.com/explore?traceId={traceId}",
+		}
+		return ProvideService(cfg, nil, nil)
+	}
+
+	t.Run("should only keep the failed and slow requests", func(t *testing.T) {
+		s := setup(10)
+
+		s.Record(ctx, &recenttraces.Trace{TraceID: "a", StatusCode: http.StatusOK, Duration: time.Millisecond})
+		s.Record(ctx, &recenttraces.Trace{TraceID: "b", StatusCode: http.StatusBadRequest, Duration: time.Millisecond})
+		s.Record(ctx, &recenttraces.Trace{TraceID: "c", StatusCode: http.StatusBadGateway, Duration: time.Millisecond})
+		s.Record(ctx, &recenttraces.Trace{TraceID: "d", StatusCode: http.StatusOK, Duration: 2 * time.Second})
+
+		traces := s.Recent(ctx, &recenttraces.Query{})
+		require.Len(t, traces, 2)
+		assert.Equal(t, "d", traces[0].TraceID)
+		assert.Equal(t, recenttraces.ReasonSlow, traces[0].Reason)
+		assert.Equal(t, int64(2000), traces[0].DurationMs)
+		assert.Equal(t, "c", traces[1].TraceID)
+		assert.Equal(t, recenttraces.ReasonError, traces[1].Reason)
+		assert.Equal(t, "https://tempo.example.com/explore?traceId=c", traces[1].URL)
+
+		assert.Len(t, s.Recent(ctx, &recenttraces.Query{Reason: recenttraces.ReasonError}), 1)
+		assert.Len(t, s.Recent(ctx, &recenttraces.Query{Limit: 1}), 1)
+	})
+
+	t.Run("should overwrite the oldest traces once full", func(t *testing.T) {
+		s := setup(2)
+
+		for _, id := range []string{"a", "b", "c"} {
+			s.Record(ctx, &recenttraces.Trace{TraceID: id, StatusCode: http.StatusInternalServerError})
+		}
+
+		traces := s.Recent(ctx, &recenttraces.Query{})
+		require.Len(t, traces, 2)
+		assert.Equal(t, "c", traces[0].TraceID)
+		assert.Equal(t, "b", traces[1].TraceID)
+	})
+
+	t.Run("should not keep traces when disabled", func(t *testing.T) {
+		s := setup(0)
+
+		s.Record(ctx, &recenttraces.Trace{TraceID: "a", StatusCode: http.StatusInternalServerError})
+
+		assert.Empty(t, s.Recent(ctx, &recenttraces.Query{}))
+	})
+}
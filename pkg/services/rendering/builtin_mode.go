@@ -0,0 +1,181 @@
+package rendering
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+const builtinPadding = 8
+
+var (
+	htmlTagPattern      = regexp.MustCompile(`<[^>]*>`)
+	markdownLinkPattern = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	markdownLinePattern = regexp.MustCompile(`^\s*(#{1,6}\s+|>\s*|[-*+]\s+|\d+\.\s+)`)
+)
+
+type builtinTheme struct {
+	background color.Color
+	border     color.Color
+	title      color.Color
+	text       color.Color
+}
+
+var builtinThemes = map[models.Theme]builtinTheme{
+	models.ThemeDark: {
+		background: color.RGBA{R: 0x18, G: 0x1b, B: 0x1f, A: 0xff},
+		border:     color.RGBA{R: 0x2d, G: 0x30, B: 0x36, A: 0xff},
+		title:      color.RGBA{R: 0xcc, G: 0xcc, B: 0xdc, A: 0xff},
+		text:       color.RGBA{R: 0xa5, G: 0xa7, B: 0xb5, A: 0xff},
+	},
+	models.ThemeLight: {
+		background: color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		border:     color.RGBA{R: 0xdc, G: 0xdc, B: 0xe0, A: 0xff},
+		title:      color.RGBA{R: 0x24, G: 0x29, B: 0x2e, A: 0xff},
+		text:       color.RGBA{R: 0x46, G: 0x4c, B: 0x54, A: 0xff},
+	},
+}
+
+// builtinSupports checks if the builtin renderer can draw the render: the PNG images of the panels of the
+// builtin_panel_types setting
+func (rs *RenderingService) builtinSupports(renderType RenderType, opts Opts) bool {
+	return renderType == RenderPNG && opts.Panel != nil && slices.Contains(rs.Cfg.RendererBuiltinPanelTypes, opts.Panel.Type)
+}
+
+// renderViaBuiltin draws the panel without a browser. It doesn't run the queries: the text panels are drawn with
+// their content, the other panels with their title only.
+func (rs *RenderingService) renderViaBuiltin(ctx context.Context, renderType RenderType, _ string, opts Opts) (*RenderResult, error) {
+	if !rs.builtinSupports(renderType, opts) {
+		return nil, ErrRenderNotSupported
+	}
+
+	filePath, err := rs.getNewFilePath(renderType)
+	if err != nil {
+		return nil, err
+	}
+
+	img := drawPanel(opts)
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning since the path is generated by getNewFilePath
+	out, err := os.Create(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := png.Encode(out, img); err != nil {
+		_ = out.Close()
+		return nil, err
+	}
+	if err := out.Close(); err != nil {
+		return nil, err
+	}
+
+	rs.log.FromContext(ctx).Debug("Drew panel with the builtin renderer", "path", opts.Path, "type", opts.Panel.Type)
+	return &RenderResult{FilePath: filePath}, nil
+}
+
+func drawPanel(opts Opts) *image.RGBA {
+	width, height := opts.Width, opts.Height
+	if width <= 0 {
+		width = 1000
+	}
+	if height <= 0 {
+		height = 500
+	}
+	scaleFactor := opts.DeviceScaleFactor
+	if scaleFactor <= 0 || math.IsInf(scaleFactor, 0) || math.IsNaN(scaleFactor) {
+		scaleFactor = 1
+	}
+	width, height = int(float64(width)*scaleFactor), int(float64(height)*scaleFactor)
+	scale := max(1, int(math.Round(2*scaleFactor)))
+
+	theme, ok := builtinThemes[opts.Theme]
+	if !ok {
+		theme = builtinThemes[models.ThemeDark]
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: theme.border}, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(1, 1, width-1, height-1), &image.Uniform{C: theme.background}, image.Point{}, draw.Src)
+
+	padding := builtinPadding * scale / 2
+	columns := max(1, (width-2*padding)/(glyphAdvance*scale))
+	y := padding
+	for _, line := range wrapText(opts.Panel.Title, columns) {
+		drawText(img, padding, y, line, theme.title, scale)
+		y += lineAdvance * scale
+	}
+	if opts.Panel.Type != "text" {
+		return img
+	}
+
+	y += lineAdvance * scale / 2
+	for _, line := range wrapText(textPanelContent(opts.Panel.Options), columns) {
+		if y+glyphHeight*scale > height-padding {
+			break
+		}
+		drawText(img, padding, y, line, theme.text, scale)
+		y += lineAdvance * scale
+	}
+	return img
+}
+
+// textPanelContent returns the content of a text panel without the HTML tags and the Markdown markup
+func textPanelContent(options map[string]any) string {
+	content, _ := options["content"].(string)
+	mode, _ := options["mode"].(string)
+	if mode == "code" {
+		return content
+	}
+
+	content = htmlTagPattern.ReplaceAllString(content, "")
+	if mode == "html" {
+		return content
+	}
+
+	content = markdownLinkPattern.ReplaceAllString(content, "$1")
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		line = markdownLinePattern.ReplaceAllString(line, "")
+		lines[i] = strings.NewReplacer("**", "", "__", "", "`", "").Replace(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrapText splits the text in lines of at most columns characters, breaking the lines between the words when possible
+func wrapText(text string, columns int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			for len([]rune(word)) > columns {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				lines = append(lines, string([]rune(word)[:columns]))
+				word = string([]rune(word)[columns:])
+			}
+			switch {
+			case line == "":
+				line = word
+			case len([]rune(line))+1+len([]rune(word)) <= columns:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -41,12 +41,7 @@ var (
 
 // renderViaHTTP renders PNG or PDF via HTTP
 func (rs *RenderingService) renderViaHTTP(ctx context.Context, renderType RenderType, renderKey string, opts Opts) (*RenderResult, error) {
-	imageRendererURL, err := rs.generateImageRendererURL(renderType, opts, renderKey)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := rs.doRequestAndWriteToFile(ctx, renderType, imageRendererURL, opts.TimeoutOpts, opts.Headers)
+	result, err := rs.doRendererRequest(ctx, rs.rendererEndpoint(), renderType, opts, renderKey)
 	if err != nil {
 		return nil, err
 	}
@@ -58,12 +53,7 @@ func (rs *RenderingService) renderViaHTTP(ctx context.Context, renderType Render
 func (rs *RenderingService) renderCSVViaHTTP(ctx context.Context, renderKey string, csvOpts CSVOpts) (*RenderCSVResult, error) {
 	opts := Opts{CommonOpts: csvOpts.CommonOpts}
 
-	imageRendererURL, err := rs.generateImageRendererURL(RenderCSV, opts, renderKey)
-	if err != nil {
-		return nil, err
-	}
-
-	result, err := rs.doRequestAndWriteToFile(ctx, RenderCSV, imageRendererURL, opts.TimeoutOpts, opts.Headers)
+	result, err := rs.doRendererRequest(ctx, rs.rendererEndpoint(), RenderCSV, opts, renderKey)
 	if err != nil {
 		return nil, err
 	}
@@ -71,8 +61,7 @@ func (rs *RenderingService) renderCSVViaHTTP(ctx context.Context, renderKey stri
 	return &RenderCSVResult{FilePath: result.FilePath, FileName: result.FileName}, nil
 }
 
-func (rs *RenderingService) generateImageRendererURL(renderType RenderType, opts Opts, renderKey string) (*url.URL, error) {
-	rendererUrl := rs.Cfg.RendererServerUrl
+func (rs *RenderingService) generateImageRendererURL(rendererUrl string, renderType RenderType, opts Opts, renderKey string) (*url.URL, error) {
 	if renderType == RenderCSV {
 		rendererUrl += "/csv"
 	}
@@ -248,13 +237,17 @@ func (rs *RenderingService) getRemotePluginVersionWithRetry(callback func(string
 }
 
 func (rs *RenderingService) getRemotePluginVersion() (string, error) {
-	rendererURL, err := url.Parse(rs.Cfg.RendererServerUrl + "/version")
+	return rs.getRendererVersion(context.Background(), rs.rendererEndpoint().url)
+}
+
+func (rs *RenderingService) getRendererVersion(ctx context.Context, serverURL string) (string, error) {
+	rendererURL, err := url.Parse(serverURL + "/version")
 	if err != nil {
 		return "", err
 	}
 
 	headers := make(map[string][]string)
-	resp, err := rs.doRequest(context.Background(), rendererURL, headers)
+	resp, err := rs.doRequest(ctx, rendererURL, headers)
 	if err != nil {
 		return "", err
 	}
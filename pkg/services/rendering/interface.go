@@ -15,6 +15,7 @@ var ErrConcurrentLimitReached = errors.New("rendering concurrent limit reached")
 var ErrRenderUnavailable = errors.New("rendering plugin not available")
 var ErrServerTimeout = errutil.NewBase(errutil.StatusUnknown, "rendering.serverTimeout", errutil.WithPublicMessage("error trying to connect to image-renderer service"))
 var ErrTooManyRequests = errutil.NewBase(errutil.StatusTooManyRequests, "rendering.tooManyRequests", errutil.WithPublicMessage("trying to send too many requests to image-renderer service"))
+var ErrRenderNotSupported = errors.New("no rendering strategy supports the render")
 
 type RenderType string
 
@@ -63,6 +64,16 @@ type Opts struct {
 	Height            int
 	DeviceScaleFactor float64
 	Theme             models.Theme
+	// Panel describes the panel of a single panel render, it lets the builtin renderer draw simple panels without a
+	// browser. It's nil when unknown.
+	Panel *PanelInfo
+}
+
+// PanelInfo is the panel of a single panel render
+type PanelInfo struct {
+	Type    string
+	Title   string
+	Options map[string]any
 }
 
 type ErrorOpts struct {
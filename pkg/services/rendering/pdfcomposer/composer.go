@@ -0,0 +1,225 @@
+// Package pdfcomposer composes a paginated PDF from the PNG images of rendered panels, so the dashboards can be
+// exported to PDF without the PDF rendering of the image renderer.
+package pdfcomposer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/grafana/gofpdf"
+)
+
+type Orientation string
+
+const (
+	Portrait  Orientation = "portrait"
+	Landscape Orientation = "landscape"
+)
+
+const (
+	// the lengths are in millimeters
+	margin       = 15.0
+	headerHeight = 10.0
+	footerHeight = 10.0
+	titleHeight  = 8.0
+	lineHeight   = 6.0
+	panelSpacing = 6.0
+
+	fontFamily = "Helvetica"
+)
+
+var ErrNoPanels = errors.New("no panels to compose")
+
+// Document is a dashboard and the images of its panels, in the order they appear in the PDF
+type Document struct {
+	Title       string
+	Description string
+	// TimeRange is the time range of the dashboard as displayed, for example "Last 24 hours"
+	TimeRange string
+	Variables []Variable
+	// GeneratedAt is printed in the footer, the current time when zero
+	GeneratedAt time.Time
+	// PageSize is A4, A3, Letter or Legal, A4 when empty
+	PageSize    string
+	Orientation Orientation
+	// OnePanelPerPage starts every panel on a new page, the panels are stacked on the pages otherwise
+	OnePanelPerPage bool
+	// TableOfContents adds the list of the panels with their page after the dashboard details on the first page
+	TableOfContents bool
+	Panels          []Panel
+}
+
+type Variable struct {
+	Name  string
+	Value string
+}
+
+type Panel struct {
+	Title string
+	// Image is the panel rendered as PNG
+	Image []byte
+}
+
+// Compose writes the document as a PDF. The first page has the details of the dashboard and the table of
+// contents, the panels are scaled to the width of the pages and follow on the next pages, with the title of the
+// dashboard in the header and the page number in the footer.
+func Compose(w io.Writer, doc *Document) error {
+	pdf, err := compose(doc)
+	if err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+func compose(doc *Document) (*gofpdf.Fpdf, error) {
+	if len(doc.Panels) == 0 {
+		return nil, ErrNoPanels
+	}
+
+	orientation := "P"
+	if doc.Orientation == Landscape {
+		orientation = "L"
+	}
+	pageSize := doc.PageSize
+	if pageSize == "" {
+		pageSize = "A4"
+	}
+	generatedAt := doc.GeneratedAt
+	if generatedAt.IsZero() {
+		generatedAt = time.Now()
+	}
+
+	pdf := gofpdf.New(orientation, "mm", pageSize, "")
+	// the core fonts are encoded in cp1252
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle(doc.Title, true)
+	pdf.SetCreator("Grafana", true)
+	pdf.SetCreationDate(generatedAt)
+	pdf.SetMargins(margin, margin+headerHeight, margin)
+	pdf.SetAutoPageBreak(true, margin+footerHeight)
+	pdf.AliasNbPages("")
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	contentWidth := pageWidth - 2*margin
+	contentTop := margin + headerHeight
+	contentBottom := pageHeight - margin - footerHeight
+
+	// the position is reset to the top of the content after the header
+	pdf.SetHeaderFuncMode(func() {
+		if pdf.PageNo() == 1 {
+			return
+		}
+		pdf.SetY(margin)
+		pdf.SetFont(fontFamily, "B", 10)
+		pdf.CellFormat(contentWidth/2, lineHeight, tr(doc.Title), "", 0, "L", false, 0, "")
+		pdf.SetFont(fontFamily, "", 9)
+		pdf.CellFormat(contentWidth/2, lineHeight, tr(doc.TimeRange), "", 1, "R", false, 0, "")
+		pdf.Line(margin, margin+lineHeight+1, pageWidth-margin, margin+lineHeight+1)
+	}, true)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(pageHeight - margin - lineHeight)
+		pdf.SetFont(fontFamily, "", 8)
+		pdf.CellFormat(contentWidth/2, lineHeight, tr("Generated "+generatedAt.Format("2006-01-02 15:04:05 MST")), "", 0, "L", false, 0, "")
+		pdf.CellFormat(contentWidth/2, lineHeight, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	// the links and the page numbers of the table of contents are set once the panels are laid out
+	links := make([]int, len(doc.Panels))
+	for i := range doc.Panels {
+		links[i] = pdf.AddLink()
+	}
+
+	pdf.AddPage()
+	writeDetails(pdf, tr, doc, contentWidth)
+	if doc.TableOfContents {
+		writeTableOfContents(pdf, tr, doc, links, contentWidth)
+	}
+
+	pdf.AddPage()
+	for i, panel := range doc.Panels {
+		info := pdf.RegisterImageOptionsReader(fmt.Sprintf("panel-%d", i), gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(panel.Image))
+		if pdf.Err() {
+			return nil, fmt.Errorf("failed to read the image of the panel %q: %w", panel.Title, pdf.Error())
+		}
+
+		// the image is scaled to the width of the page, or down to the height of the page for the tall panels
+		width := contentWidth
+		height := width * info.Height() / info.Width()
+		if maxHeight := contentBottom - contentTop - titleHeight; height > maxHeight {
+			height = maxHeight
+			width = height * info.Width() / info.Height()
+		}
+
+		y := pdf.GetY()
+		if i > 0 && (doc.OnePanelPerPage || y+panelSpacing+titleHeight+height > contentBottom) {
+			pdf.AddPage()
+			y = pdf.GetY()
+		} else if i > 0 {
+			y += panelSpacing
+		}
+
+		pdf.SetLink(links[i], y, -1)
+		pdf.RegisterAlias(pageAlias(i), fmt.Sprintf("%d", pdf.PageNo()))
+
+		pdf.SetXY(margin, y)
+		pdf.SetFont(fontFamily, "B", 11)
+		pdf.CellFormat(contentWidth, titleHeight, tr(panel.Title), "", 1, "L", false, 0, "")
+		pdf.ImageOptions(fmt.Sprintf("panel-%d", i), margin+(contentWidth-width)/2, y+titleHeight, width, height, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+		pdf.SetY(y + titleHeight + height)
+	}
+
+	if pdf.Err() {
+		return nil, pdf.Error()
+	}
+	return pdf, nil
+}
+
+func writeDetails(pdf *gofpdf.Fpdf, tr func(string) string, doc *Document, contentWidth float64) {
+	pdf.SetY(margin)
+	pdf.SetFont(fontFamily, "B", 20)
+	pdf.MultiCell(contentWidth, 10, tr(doc.Title), "", "L", false)
+
+	if doc.Description != "" {
+		pdf.Ln(2)
+		pdf.SetFont(fontFamily, "", 11)
+		pdf.MultiCell(contentWidth, lineHeight, tr(doc.Description), "", "L", false)
+	}
+
+	pdf.Ln(4)
+	pdf.SetFont(fontFamily, "", 10)
+	if doc.TimeRange != "" {
+		writeDetail(pdf, tr, "Time range", doc.TimeRange, contentWidth)
+	}
+	for _, variable := range doc.Variables {
+		writeDetail(pdf, tr, variable.Name, variable.Value, contentWidth)
+	}
+}
+
+func writeDetail(pdf *gofpdf.Fpdf, tr func(string) string, name, value string, contentWidth float64) {
+	const nameWidth = 45.0
+	pdf.SetFont(fontFamily, "B", 10)
+	pdf.CellFormat(nameWidth, lineHeight, tr(name), "", 0, "L", false, 0, "")
+	pdf.SetFont(fontFamily, "", 10)
+	pdf.MultiCell(contentWidth-nameWidth, lineHeight, tr(value), "", "L", false)
+}
+
+func writeTableOfContents(pdf *gofpdf.Fpdf, tr func(string) string, doc *Document, links []int, contentWidth float64) {
+	const pageWidth = 20.0
+
+	pdf.Ln(6)
+	pdf.SetFont(fontFamily, "B", 14)
+	pdf.CellFormat(contentWidth, 10, "Contents", "", 1, "L", false, 0, "")
+	pdf.SetFont(fontFamily, "", 10)
+	for i, panel := range doc.Panels {
+		pdf.CellFormat(contentWidth-pageWidth, lineHeight, tr(panel.Title), "", 0, "L", false, links[i], "")
+		pdf.CellFormat(pageWidth, lineHeight, pageAlias(i), "", 1, "R", false, links[i], "")
+	}
+}
+
+// pageAlias is replaced by the page of the panel when the PDF is written
+func pageAlias(panel int) string {
+	return fmt.Sprintf("{p%d}", panel)
+}
@@ -0,0 +1,81 @@
+package pdfcomposer
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompose(t *testing.T) {
+	newDocument := func(t *testing.T, panels int) *Document {
+		doc := &Document{
+			Title:       "Service overview",
+			Description: "Latency and errors of the checkout service",
+			TimeRange:   "Last 24 hours",
+			Variables:   []Variable{{Name: "cluster", Value: "prod-eu-west"}},
+			GeneratedAt: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		}
+		for i := 0; i < panels; i++ {
+			doc.Panels = append(doc.Panels, Panel{Title: "Requests", Image: newPNG(t, 1000, 500)})
+		}
+		return doc
+	}
+
+	t.Run("should write a PDF", func(t *testing.T) {
+		doc := newDocument(t, 3)
+		doc.TableOfContents = true
+
+		var out bytes.Buffer
+		require.NoError(t, Compose(&out, doc))
+		assert.True(t, bytes.HasPrefix(out.Bytes(), []byte("%PDF-")))
+		assert.NotContains(t, out.String(), "{p0}", "the page numbers of the table of contents are replaced")
+	})
+
+	t.Run("should stack the panels on the pages", func(t *testing.T) {
+		pdf, err := compose(newDocument(t, 3))
+		require.NoError(t, err)
+		// the first page has the details, an A4 page fits two panels in portrait
+		assert.Equal(t, 3, pdf.PageCount())
+	})
+
+	t.Run("should start each panel on a new page", func(t *testing.T) {
+		doc := newDocument(t, 3)
+		doc.OnePanelPerPage = true
+		doc.Orientation = Landscape
+
+		pdf, err := compose(doc)
+		require.NoError(t, err)
+		assert.Equal(t, 4, pdf.PageCount())
+	})
+
+	t.Run("should fail without panels", func(t *testing.T) {
+		err := Compose(&bytes.Buffer{}, &Document{Title: "Empty"})
+		assert.ErrorIs(t, err, ErrNoPanels)
+	})
+
+	t.Run("should fail when an image isn't a PNG", func(t *testing.T) {
+		doc := newDocument(t, 1)
+		doc.Panels[0].Image = []byte("not an image")
+
+		err := Compose(&bytes.Buffer{}, doc)
+		assert.ErrorContains(t, err, `failed to read the image of the panel "Requests"`)
+	})
+}
+
+func newPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, height/2, color.RGBA{R: 115, G: 191, B: 105, A: 255})
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
@@ -0,0 +1,123 @@
+package rendering
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const rendererHealthCheckTimeout = 10 * time.Second
+
+// rendererEndpoint is a remote image renderer service of the pool
+type rendererEndpoint struct {
+	url        string
+	healthy    atomic.Bool
+	inProgress atomic.Int32
+}
+
+// rendererPool spreads the renders over the remote image renderer services of the server_url setting. A render is
+// sent to the healthy service with the fewest renders in progress. The services which can't be reached are
+// skipped until they pass a health check again.
+type rendererPool struct {
+	endpoints []*rendererEndpoint
+	next      atomic.Uint32
+}
+
+func newRendererPool(serverURL string) *rendererPool {
+	pool := &rendererPool{}
+	for _, u := range util.SplitString(serverURL) {
+		endpoint := &rendererEndpoint{url: u}
+		endpoint.healthy.Store(true)
+		metrics.MRenderingServerUp.WithLabelValues(u).Set(1)
+		pool.endpoints = append(pool.endpoints, endpoint)
+	}
+	return pool
+}
+
+// pick returns the healthy service with the fewest renders in progress, or any service when none is healthy
+func (p *rendererPool) pick() *rendererEndpoint {
+	// starting from the next service spreads the renders over the services with the same load
+	start := int(p.next.Add(1) % uint32(len(p.endpoints)))
+
+	var picked *rendererEndpoint
+	for i := range p.endpoints {
+		endpoint := p.endpoints[(start+i)%len(p.endpoints)]
+		if !endpoint.healthy.Load() {
+			continue
+		}
+		if picked == nil || endpoint.inProgress.Load() < picked.inProgress.Load() {
+			picked = endpoint
+		}
+	}
+	if picked == nil {
+		picked = p.endpoints[start]
+	}
+	return picked
+}
+
+// rendererEndpoint returns the service to send the render to
+func (rs *RenderingService) rendererEndpoint() *rendererEndpoint {
+	if rs.pool == nil || len(rs.pool.endpoints) == 0 {
+		return &rendererEndpoint{url: rs.Cfg.RendererServerUrl}
+	}
+	return rs.pool.pick()
+}
+
+func (rs *RenderingService) setRendererHealth(endpoint *rendererEndpoint, healthy bool, err error) {
+	if endpoint.healthy.Swap(healthy) == healthy {
+		return
+	}
+	if healthy {
+		rs.log.Info("Remote rendering service is healthy again", "url", endpoint.url)
+		metrics.MRenderingServerUp.WithLabelValues(endpoint.url).Set(1)
+		return
+	}
+	rs.log.Warn("Remote rendering service is unhealthy, skipping it until it passes a health check", "url", endpoint.url, "error", err)
+	metrics.MRenderingServerUp.WithLabelValues(endpoint.url).Set(0)
+}
+
+// checkRenderersHealth requests the version of every service of the pool, the services responding are healthy
+func (rs *RenderingService) checkRenderersHealth(ctx context.Context) {
+	if rs.pool == nil {
+		return
+	}
+	for _, endpoint := range rs.pool.endpoints {
+		checkCtx, cancel := context.WithTimeout(ctx, rendererHealthCheckTimeout)
+		_, err := rs.getRendererVersion(checkCtx, endpoint.url)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		rs.setRendererHealth(endpoint, err == nil, err)
+	}
+}
+
+// doRendererRequest sends the render to the service, and marks the service unhealthy when it can't be reached
+func (rs *RenderingService) doRendererRequest(ctx context.Context, endpoint *rendererEndpoint, renderType RenderType, opts Opts, renderKey string) (*Result, error) {
+	imageRendererURL, err := rs.generateImageRendererURL(endpoint.url, renderType, opts, renderKey)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint.inProgress.Add(1)
+	defer endpoint.inProgress.Add(-1)
+
+	start := time.Now()
+	result, err := rs.doRequestAndWriteToFile(ctx, renderType, imageRendererURL, opts.TimeoutOpts, opts.Headers)
+	status := "success"
+	if err != nil {
+		status = "failure"
+	}
+	metrics.MRenderingServerSummary.WithLabelValues(endpoint.url, status).Observe(float64(time.Since(start).Milliseconds()))
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && !urlErr.Timeout() && ctx.Err() == nil {
+		rs.setRendererHealth(endpoint, false, err)
+	}
+	return result, err
+}
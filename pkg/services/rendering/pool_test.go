@@ -0,0 +1,58 @@
+package rendering
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRendererPool(t *testing.T) {
+	t.Run("should pick the healthy renderer with the fewest renders in progress", func(t *testing.T) {
+		pool := newRendererPool("http://renderer-1/render, http://renderer-2/render,http://renderer-3/render")
+		pool.endpoints[0].inProgress.Store(2)
+		pool.endpoints[1].healthy.Store(false)
+		pool.endpoints[2].inProgress.Store(1)
+
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, "http://renderer-3/render", pool.pick().url)
+		}
+
+		pool.endpoints[2].healthy.Store(false)
+		assert.Equal(t, "http://renderer-1/render", pool.pick().url)
+	})
+
+	t.Run("should pick a renderer when none is healthy", func(t *testing.T) {
+		pool := newRendererPool("http://renderer-1/render")
+		pool.endpoints[0].healthy.Store(false)
+
+		assert.Equal(t, "http://renderer-1/render", pool.pick().url)
+	})
+
+	t.Run("should mark the renderers failing the health check unhealthy", func(t *testing.T) {
+		healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"version": "3.10.0"}`))
+		}))
+		t.Cleanup(healthy.Close)
+		unhealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		t.Cleanup(unhealthy.Close)
+
+		rs := &RenderingService{
+			Cfg:  &setting.Cfg{},
+			log:  log.NewNopLogger(),
+			pool: newRendererPool(healthy.URL + "," + unhealthy.URL),
+		}
+		rs.checkRenderersHealth(context.Background())
+
+		assert.True(t, rs.pool.endpoints[0].healthy.Load())
+		assert.False(t, rs.pool.endpoints[1].healthy.Load())
+		assert.Equal(t, healthy.URL, rs.rendererEndpoint().url)
+	})
+}
@@ -0,0 +1,131 @@
+package rendering
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+)
+
+// queuedRender is a render waiting for the concurrent limits to allow it to start
+type queuedRender struct {
+	orgID int64
+	limit int
+	// started is closed when the render is allowed to start
+	started chan struct{}
+}
+
+// acquireRenderSlot returns once the render of the organization can start without exceeding the concurrent
+// limits. Once a limit is reached, the render waits in a bounded queue and the renders start in the order they
+// arrived, as long as the limit of their organization allows it. The returned function releases the slot.
+func (rs *RenderingService) acquireRenderSlot(ctx context.Context, orgID int64, limit int) (func(), error) {
+	release := func() { rs.releaseRenderSlot(orgID) }
+
+	rs.queueMtx.Lock()
+	if len(rs.queue) == 0 && rs.canStartRender(orgID, limit) {
+		rs.startRender(orgID)
+		rs.queueMtx.Unlock()
+		metrics.MRenderingQueueWaitSummary.WithLabelValues("started").Observe(0)
+		return release, nil
+	}
+	if len(rs.queue) >= rs.Cfg.RendererQueueSize {
+		rs.queueMtx.Unlock()
+		metrics.MRenderingQueueWaitSummary.WithLabelValues("rejected").Observe(0)
+		return nil, ErrConcurrentLimitReached
+	}
+	render := &queuedRender{orgID: orgID, limit: limit, started: make(chan struct{})}
+	rs.queue = append(rs.queue, render)
+	metrics.MRenderingQueueWaiting.Set(float64(len(rs.queue)))
+	// the renders ahead may be waiting for the limit of their organization only
+	rs.startQueuedRenders()
+	rs.queueMtx.Unlock()
+
+	waitStart := time.Now()
+	timer := time.NewTimer(rs.Cfg.RendererQueueTimeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-render.started:
+	case <-timer.C:
+		err = ErrConcurrentLimitReached
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	elapsed := float64(time.Since(waitStart).Milliseconds())
+
+	if err != nil {
+		rs.queueMtx.Lock()
+		removed := rs.removeQueuedRender(render)
+		rs.queueMtx.Unlock()
+		// the render may have started while timing out
+		if removed {
+			metrics.MRenderingQueueWaitSummary.WithLabelValues("timeout").Observe(elapsed)
+			return nil, err
+		}
+	}
+
+	metrics.MRenderingQueueWaitSummary.WithLabelValues("started").Observe(elapsed)
+	return release, nil
+}
+
+func (rs *RenderingService) releaseRenderSlot(orgID int64) {
+	rs.queueMtx.Lock()
+	defer rs.queueMtx.Unlock()
+
+	metrics.MRenderingQueue.Set(float64(atomic.AddInt32(&rs.inProgressCount, -1)))
+	if rs.orgInProgress[orgID] <= 1 {
+		delete(rs.orgInProgress, orgID)
+	} else {
+		rs.orgInProgress[orgID]--
+	}
+	rs.startQueuedRenders()
+}
+
+// canStartRender keeps the semantics of the concurrent limit, which is reached when more renders than the
+// limit are in progress
+func (rs *RenderingService) canStartRender(orgID int64, limit int) bool {
+	if int(atomic.LoadInt32(&rs.inProgressCount)) > limit {
+		return false
+	}
+	orgLimit := rs.Cfg.RendererConcurrentRequestLimitPerOrg
+	return orgLimit <= 0 || rs.orgInProgress[orgID] < orgLimit
+}
+
+func (rs *RenderingService) startRender(orgID int64) {
+	if rs.orgInProgress == nil {
+		rs.orgInProgress = map[int64]int{}
+	}
+	rs.orgInProgress[orgID]++
+	metrics.MRenderingQueue.Set(float64(atomic.AddInt32(&rs.inProgressCount, 1)))
+}
+
+// startQueuedRenders starts the queued renders which the limits allow, in the order they arrived
+func (rs *RenderingService) startQueuedRenders() {
+	waiting := rs.queue[:0]
+	for _, render := range rs.queue {
+		if rs.canStartRender(render.orgID, render.limit) {
+			rs.startRender(render.orgID)
+			close(render.started)
+			continue
+		}
+		waiting = append(waiting, render)
+	}
+	for i := len(waiting); i < len(rs.queue); i++ {
+		rs.queue[i] = nil
+	}
+	rs.queue = waiting
+	metrics.MRenderingQueueWaiting.Set(float64(len(rs.queue)))
+}
+
+func (rs *RenderingService) removeQueuedRender(render *queuedRender) bool {
+	for i, r := range rs.queue {
+		if r == render {
+			rs.queue = append(rs.queue[:i], rs.queue[i+1:]...)
+			metrics.MRenderingQueueWaiting.Set(float64(len(rs.queue)))
+			return true
+		}
+	}
+	return false
+}
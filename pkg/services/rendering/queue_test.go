@@ -0,0 +1,89 @@
+package rendering
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestAcquireRenderSlot(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should reject renders over the limit without a queue", func(t *testing.T) {
+		rs := &RenderingService{Cfg: &setting.Cfg{}}
+
+		release, err := rs.acquireRenderSlot(ctx, 1, 0)
+		require.NoError(t, err)
+		_, err = rs.acquireRenderSlot(ctx, 1, 0)
+		assert.ErrorIs(t, err, ErrConcurrentLimitReached)
+
+		release()
+		release, err = rs.acquireRenderSlot(ctx, 1, 0)
+		require.NoError(t, err)
+		release()
+		assert.Equal(t, int32(0), rs.inProgressCount)
+		assert.Empty(t, rs.orgInProgress)
+	})
+
+	t.Run("should limit the renders of each organization", func(t *testing.T) {
+		rs := &RenderingService{Cfg: &setting.Cfg{RendererConcurrentRequestLimitPerOrg: 1}}
+
+		release, err := rs.acquireRenderSlot(ctx, 1, 10)
+		require.NoError(t, err)
+		defer release()
+
+		_, err = rs.acquireRenderSlot(ctx, 1, 10)
+		assert.ErrorIs(t, err, ErrConcurrentLimitReached)
+
+		releaseOther, err := rs.acquireRenderSlot(ctx, 2, 10)
+		require.NoError(t, err)
+		releaseOther()
+	})
+
+	t.Run("should start the queued renders in order when slots are released", func(t *testing.T) {
+		rs := &RenderingService{Cfg: &setting.Cfg{RendererQueueSize: 2, RendererQueueTimeout: time.Minute}}
+
+		release, err := rs.acquireRenderSlot(ctx, 1, 0)
+		require.NoError(t, err)
+
+		started := make(chan int, 2)
+		for i := 1; i <= 2; i++ {
+			go func() {
+				release, err := rs.acquireRenderSlot(ctx, 1, 0)
+				if err == nil {
+					started <- i
+					release()
+				}
+			}()
+			require.Eventually(t, func() bool {
+				rs.queueMtx.Lock()
+				defer rs.queueMtx.Unlock()
+				return len(rs.queue) == i
+			}, time.Second, 10*time.Millisecond)
+		}
+
+		_, err = rs.acquireRenderSlot(ctx, 1, 0)
+		assert.ErrorIs(t, err, ErrConcurrentLimitReached, "the queue is full")
+
+		release()
+		assert.Equal(t, 1, <-started)
+		assert.Equal(t, 2, <-started)
+	})
+
+	t.Run("should reject queued renders after the timeout", func(t *testing.T) {
+		rs := &RenderingService{Cfg: &setting.Cfg{RendererQueueSize: 1, RendererQueueTimeout: 10 * time.Millisecond}}
+
+		release, err := rs.acquireRenderSlot(ctx, 1, 0)
+		require.NoError(t, err)
+		defer release()
+
+		_, err = rs.acquireRenderSlot(ctx, 1, 0)
+		assert.ErrorIs(t, err, ErrConcurrentLimitReached)
+		assert.Empty(t, rs.queue)
+	})
+}
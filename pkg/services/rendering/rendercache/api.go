@@ -0,0 +1,41 @@
+package rendercache
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister) {
+	// the signature authorizes the request, the URLs are opened from notifications without a session
+	router.Get("/"+signedURLPath+":name", s.serveImage)
+}
+
+func (s *Service) serveImage(c *contextmodel.ReqContext) {
+	name := web.Params(c.Req)[":name"]
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		c.JsonApiErr(http.StatusNotFound, "Image not found", nil)
+		return
+	}
+
+	if !s.verify(name, c.Query("expires"), c.Query("signature"), time.Now()) {
+		c.JsonApiErr(http.StatusForbidden, "Invalid or expired signature", nil)
+		return
+	}
+
+	path := filepath.Join(s.cfg.ImagesDir, name)
+	if _, err := os.Stat(path); err != nil {
+		c.JsonApiErr(http.StatusNotFound, "Image not found", nil)
+		return
+	}
+
+	c.Resp.Header().Set("Content-Type", "image/png")
+	c.Resp.Header().Set("Cache-Control", "private")
+	http.ServeFile(c.Resp, c.Req, path)
+}
@@ -0,0 +1,264 @@
+// Package rendercache caches the rendered panel images, so that the renders of the same dashboard version, panel,
+// time range and variables, for example by the notifications of several alert rules on the same panel, reuse the
+// image instead of rendering it again. The cached images can be linked with time-limited signed URLs.
+package rendercache
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/cacheinvalidation"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	pruneInterval = 5 * time.Minute
+
+	signedURLPath = "api/rendered-images/"
+)
+
+// Key identifies the renders producing identical images
+type Key struct {
+	OrgID            int64
+	DashboardUID     string
+	DashboardVersion int
+	PanelID          int64
+	From             string
+	To               string
+	// Variables are the template variables of the render, the var- query parameters
+	Variables         map[string][]string
+	Width             int
+	Height            int
+	DeviceScaleFactor float64
+	Theme             models.Theme
+}
+
+func (k Key) hash() string {
+	h := sha256.New()
+	write := func(v string) {
+		_, _ = h.Write([]byte(v))
+		_, _ = h.Write([]byte{0})
+	}
+	write(strconv.FormatInt(k.OrgID, 10))
+	write(k.DashboardUID)
+	write(strconv.Itoa(k.DashboardVersion))
+	write(strconv.FormatInt(k.PanelID, 10))
+	write(k.From)
+	write(k.To)
+	names := make([]string, 0, len(k.Variables))
+	for name := range k.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		write(name)
+		for _, value := range k.Variables[name] {
+			write(value)
+		}
+	}
+	write(strconv.Itoa(k.Width))
+	write(strconv.Itoa(k.Height))
+	write(strconv.FormatFloat(k.DeviceScaleFactor, 'f', -1, 64))
+	write(string(k.Theme))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Image is a cached render
+type Image struct {
+	Path string
+	// URL is a signed URL of the image, valid for the configured expiry, empty when the image isn't in the images
+	// directory
+	URL string
+}
+
+type entry struct {
+	orgID        int64
+	dashboardUID string
+	path         string
+	expires      time.Time
+}
+
+type Service struct {
+	cfg *setting.Cfg
+	log log.Logger
+
+	mtx     sync.Mutex
+	entries map[string]*entry
+}
+
+func ProvideService(cfg *setting.Cfg, invalidations cacheinvalidation.Service, routeRegister routing.RouteRegister) *Service {
+	s := &Service{
+		cfg:     cfg,
+		log:     log.New("rendering.cache"),
+		entries: make(map[string]*entry),
+	}
+
+	invalidations.Register("render cache", []string{cacheinvalidation.KindDashboards + "/"}, s.handleInvalidation)
+	s.registerRoutes(routeRegister)
+
+	return s
+}
+
+// IsEnabled returns true when the render_cache_ttl setting is set
+func (s *Service) IsEnabled() bool {
+	return s.cfg.RendererCacheTTL > 0
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	if !s.IsEnabled() {
+		return nil
+	}
+
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			s.prune(time.Now())
+		}
+	}
+}
+
+// Get returns the cached image of the render, or false when the render isn't cached or its image was deleted
+func (s *Service) Get(ctx context.Context, key Key) (*Image, bool) {
+	if !s.IsEnabled() {
+		return nil, false
+	}
+
+	hash := key.hash()
+	s.mtx.Lock()
+	e, ok := s.entries[hash]
+	if ok && time.Now().After(e.expires) {
+		delete(s.entries, hash)
+		ok = false
+	}
+	s.mtx.Unlock()
+
+	if ok {
+		if _, err := os.Stat(e.path); err != nil {
+			s.log.FromContext(ctx).Debug("Cached image is missing", "path", e.path, "error", err)
+			s.mtx.Lock()
+			delete(s.entries, hash)
+			s.mtx.Unlock()
+			ok = false
+		}
+	}
+
+	if !ok {
+		metrics.MRenderingCacheUsage.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+
+	metrics.MRenderingCacheUsage.WithLabelValues("hit").Inc()
+	return &Image{Path: e.path, URL: s.SignedURL(e.path)}, true
+}
+
+// Set caches the image rendered at the path, the image is kept on disk until it's deleted with the temporary files.
+// The image is returned without URL when the cache is disabled.
+func (s *Service) Set(_ context.Context, key Key, path string) *Image {
+	if !s.IsEnabled() {
+		return &Image{Path: path}
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.entries[key.hash()] = &entry{
+		orgID:        key.OrgID,
+		dashboardUID: key.DashboardUID,
+		path:         path,
+		expires:      time.Now().Add(s.cfg.RendererCacheTTL),
+	}
+	return &Image{Path: path, URL: s.SignedURL(path)}
+}
+
+// Invalidate removes the cached images of the dashboard, the signed URLs already given out remain valid
+func (s *Service) Invalidate(orgID int64, dashboardUID string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for hash, e := range s.entries {
+		if e.orgID == orgID && e.dashboardUID == dashboardUID {
+			delete(s.entries, hash)
+		}
+	}
+}
+
+func (s *Service) invalidateOrg(orgID int64) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for hash, e := range s.entries {
+		if e.orgID == orgID {
+			delete(s.entries, hash)
+		}
+	}
+}
+
+func (s *Service) prune(now time.Time) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for hash, e := range s.entries {
+		if now.After(e.expires) {
+			delete(s.entries, hash)
+		}
+	}
+}
+
+// handleInvalidation evicts the images of the saved or deleted dashboards, on every instance
+func (s *Service) handleInvalidation(_ context.Context, inv cacheinvalidation.Invalidation) {
+	_, orgID, uid, ok := cacheinvalidation.ParseKey(inv.Key)
+	if !ok {
+		return
+	}
+	if uid == "" {
+		s.invalidateOrg(orgID)
+		return
+	}
+	s.Invalidate(orgID, uid)
+}
+
+// SignedURL returns a URL of the image at the path which can be opened without signing in until the configured
+// expiry, or an empty string when the image isn't in the images directory
+func (s *Service) SignedURL(path string) string {
+	if filepath.Dir(filepath.Clean(path)) != filepath.Clean(s.cfg.ImagesDir) {
+		return ""
+	}
+
+	name := filepath.Base(path)
+	expires := strconv.FormatInt(time.Now().Add(s.cfg.RendererCacheURLExpiry).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(name, expires))
+	return fmt.Sprintf("%s%s%s?%s", s.cfg.AppURL, signedURLPath, url.PathEscape(name), query.Encode())
+}
+
+func (s *Service) sign(name, expires string) string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.SecretKey))
+	_, _ = mac.Write([]byte(name))
+	_, _ = mac.Write([]byte{0})
+	_, _ = mac.Write([]byte(expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify returns true when the signature of the image name is valid and hasn't expired
+func (s *Service) verify(name, expires, signature string, now time.Time) bool {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.After(time.Unix(unix, 0)) {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(s.sign(name, expires)))
+}
@@ -0,0 +1,133 @@
+package rendercache
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/services/cacheinvalidation"
+	"github.com/grafana/grafana/pkg/services/cacheinvalidation/cacheinvalidationtest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*Service, *cacheinvalidationtest.FakeService) {
+		cfg := setting.NewCfg()
+		cfg.AppURL = "http://localhost:3000/"
+		cfg.SecretKey = "secret"
+		cfg.ImagesDir = t.TempDir()
+		cfg.RendererCacheTTL = time.Hour
+		cfg.RendererCacheURLExpiry = time.Hour
+		invalidations := cacheinvalidationtest.NewFakeService()
+		return ProvideService(cfg, invalidations, routing.NewRouteRegister()), invalidations
+	}
+	render := func(t *testing.T, s *Service, name string) string {
+		path := filepath.Join(s.cfg.ImagesDir, name)
+		require.NoError(t, os.WriteFile(path, []byte("png"), 0600))
+		return path
+	}
+	key := Key{
+		OrgID:            1,
+		DashboardUID:     "dash",
+		DashboardVersion: 3,
+		PanelID:          2,
+		From:             "now-6h",
+		To:               "now",
+		Variables:        map[string][]string{"cluster": {"eu"}, "namespace": {"a", "b"}},
+		Width:            1000,
+		Height:           500,
+	}
+
+	t.Run("should reuse the image of the same render", func(t *testing.T) {
+		s, _ := setup(t)
+		path := render(t, s, "panel.png")
+
+		image := s.Set(ctx, key, path)
+		assert.NotEmpty(t, image.URL)
+
+		cached, ok := s.Get(ctx, key)
+		require.True(t, ok)
+		assert.Equal(t, path, cached.Path)
+
+		other := key
+		other.DashboardVersion = 4
+		_, ok = s.Get(ctx, other)
+		assert.False(t, ok, "another dashboard version is rendered again")
+
+		other = key
+		other.Variables = map[string][]string{"cluster": {"us"}, "namespace": {"a", "b"}}
+		_, ok = s.Get(ctx, other)
+		assert.False(t, ok, "other variables are rendered again")
+	})
+
+	t.Run("should invalidate the images of a dashboard when it's saved", func(t *testing.T) {
+		s, invalidations := setup(t)
+		s.Set(ctx, key, render(t, s, "panel.png"))
+		otherDashboard := key
+		otherDashboard.DashboardUID = "other"
+		s.Set(ctx, otherDashboard, render(t, s, "other.png"))
+
+		invalidations.Invalidate(ctx, cacheinvalidation.Invalidation{
+			Key:    cacheinvalidation.Key(cacheinvalidation.KindDashboards, 1, "dash"),
+			Reason: "DashboardSaved",
+		})
+
+		_, ok := s.Get(ctx, key)
+		assert.False(t, ok)
+		_, ok = s.Get(ctx, otherDashboard)
+		assert.True(t, ok)
+	})
+
+	t.Run("should not return expired or deleted images", func(t *testing.T) {
+		s, _ := setup(t)
+		path := render(t, s, "panel.png")
+		s.Set(ctx, key, path)
+
+		s.prune(time.Now().Add(2 * time.Hour))
+		_, ok := s.Get(ctx, key)
+		assert.False(t, ok)
+
+		s.Set(ctx, key, path)
+		require.NoError(t, os.Remove(path))
+		_, ok = s.Get(ctx, key)
+		assert.False(t, ok)
+		assert.Empty(t, s.entries)
+	})
+
+	t.Run("should not cache when disabled", func(t *testing.T) {
+		s, _ := setup(t)
+		s.cfg.RendererCacheTTL = 0
+
+		image := s.Set(ctx, key, render(t, s, "panel.png"))
+		assert.Empty(t, image.URL)
+		_, ok := s.Get(ctx, key)
+		assert.False(t, ok)
+	})
+
+	t.Run("should sign the URLs of the images", func(t *testing.T) {
+		s, _ := setup(t)
+		signedURL := s.SignedURL(render(t, s, "panel.png"))
+		require.True(t, strings.HasPrefix(signedURL, "http://localhost:3000/api/rendered-images/panel.png?"))
+
+		u, err := url.Parse(signedURL)
+		require.NoError(t, err)
+		expires, signature := u.Query().Get("expires"), u.Query().Get("signature")
+
+		assert.True(t, s.verify("panel.png", expires, signature, time.Now()))
+		assert.False(t, s.verify("other.png", expires, signature, time.Now()), "the signature is for another image")
+		assert.False(t, s.verify("panel.png", expires, signature, time.Now().Add(2*time.Hour)), "the signature expired")
+		assert.False(t, s.verify("panel.png", expires+"0", signature, time.Now()), "the expiry was changed")
+
+		assert.Empty(t, s.SignedURL(filepath.Join(t.TempDir(), "panel.png")), "the image isn't in the images directory")
+	})
+}
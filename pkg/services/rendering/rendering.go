@@ -29,10 +29,14 @@ var _ Service = (*RenderingService)(nil)
 type RenderingService struct {
 	log                 log.Logger
 	plugin              Plugin
-	renderAction        renderFunc
-	renderCSVAction     renderCSVFunc
+	pluginStarted       atomic.Bool
+	strategies          []*renderStrategy
 	domain              string
 	inProgressCount     int32
+	queueMtx            sync.Mutex
+	orgInProgress       map[int64]int
+	queue               []*queuedRender
+	pool                *rendererPool
 	version             string
 	versionMutex        sync.RWMutex
 	capabilities        []Capability
@@ -144,7 +148,9 @@ func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, remot
 		domain:                domain,
 		pluginAvailable:       exists,
 		rendererCallbackURL:   rendererCallbackURL,
+		pool:                  newRendererPool(cfg.RendererServerUrl),
 	}
+	s.strategies = s.newRenderStrategies()
 
 	gob.Register(&RenderUser{})
 
@@ -167,42 +173,70 @@ func (rs *RenderingService) Run(ctx context.Context) error {
 
 			rs.version = version
 		})
-		rs.renderAction = rs.renderViaHTTP
-		rs.renderCSVAction = rs.renderCSVViaHTTP
-
-		refreshTicker := time.NewTicker(remoteVersionRefreshInterval)
-
-		for {
-			select {
-			case <-refreshTicker.C:
-				go rs.refreshRemotePluginVersion()
-			case <-ctx.Done():
-				rs.log.Debug("Grafana is shutting down - stopping image-renderer version refresh")
-				refreshTicker.Stop()
-				return nil
+	}
+
+	// The plugin is the renderer without remote renderer, and a fallback of the remote renderer when the strategies
+	// have both
+	if rs.RendererPluginManager != nil && (!rs.remoteAvailable() || rs.hasStrategy(StrategyPlugin)) {
+		if rp, exists := rs.RendererPluginManager.Renderer(ctx); exists {
+			if err := rs.startPlugin(ctx, rp); err != nil {
+				if !rs.remoteAvailable() {
+					return err
+				}
+				rs.log.Error("Failed to start the renderer plugin, rendering via external http server only", "error", err)
 			}
 		}
 	}
 
-	if rp, exists := rs.RendererPluginManager.Renderer(ctx); exists {
-		rs.log = rs.log.New("renderer", "plugin")
-		rs.plugin = rp
-		if err := rs.plugin.Start(ctx); err != nil {
-			return err
+	if !rs.remoteAvailable() {
+		if !rs.pluginStarted.Load() && !rs.hasStrategy(StrategyBuiltin) {
+			rs.log.Debug("No image renderer found/installed. " +
+				"For image rendering support please install the grafana-image-renderer plugin. " +
+				"Read more at https://grafana.com/docs/grafana/latest/administration/image_rendering/")
 		}
-		rs.version = rp.Version()
-		rs.renderAction = rs.renderViaPlugin
-		rs.renderCSVAction = rs.renderCSVViaPlugin
-		<-ctx.Done()
 
+		<-ctx.Done()
 		return nil
 	}
 
-	rs.log.Debug("No image renderer found/installed. " +
-		"For image rendering support please install the grafana-image-renderer plugin. " +
-		"Read more at https://grafana.com/docs/grafana/latest/administration/image_rendering/")
+	refreshTicker := time.NewTicker(remoteVersionRefreshInterval)
+
+	var healthCheck <-chan time.Time
+	if rs.Cfg.RendererHealthCheckInterval > 0 {
+		healthTicker := time.NewTicker(rs.Cfg.RendererHealthCheckInterval)
+		defer healthTicker.Stop()
+		healthCheck = healthTicker.C
+	}
+
+	for {
+		select {
+		case <-refreshTicker.C:
+			go rs.refreshRemotePluginVersion()
+		case <-healthCheck:
+			rs.checkRenderersHealth(ctx)
+		case <-ctx.Done():
+			rs.log.Debug("Grafana is shutting down - stopping image-renderer version refresh")
+			refreshTicker.Stop()
+			return nil
+		}
+	}
+}
 
-	<-ctx.Done()
+func (rs *RenderingService) startPlugin(ctx context.Context, rp Plugin) error {
+	if !rs.remoteAvailable() {
+		rs.log = rs.log.New("renderer", "plugin")
+	}
+	rs.plugin = rp
+	if err := rp.Start(ctx); err != nil {
+		return err
+	}
+	// the capabilities are the ones of the remote renderer when there is one
+	if !rs.remoteAvailable() {
+		rs.versionMutex.Lock()
+		rs.version = rp.Version()
+		rs.versionMutex.Unlock()
+	}
+	rs.pluginStarted.Store(true)
 	return nil
 }
 
@@ -211,7 +245,7 @@ func (rs *RenderingService) remoteAvailable() bool {
 }
 
 func (rs *RenderingService) IsAvailable(ctx context.Context) bool {
-	return rs.remoteAvailable() || rs.pluginAvailable
+	return rs.remoteAvailable() || rs.pluginAvailable || rs.hasStrategy(StrategyBuiltin)
 }
 
 func (rs *RenderingService) Version() string {
@@ -279,8 +313,12 @@ func (rs *RenderingService) render(ctx context.Context, renderType RenderType, o
 		return rs.renderUnavailableImage(), nil
 	}
 
-	if int(atomic.LoadInt32(&rs.inProgressCount)) > opts.ConcurrentLimit {
-		logger.Warn("Could not render image, hit the currency limit", "concurrencyLimit", opts.ConcurrentLimit, "path", opts.Path)
+	release, err := rs.acquireRenderSlot(ctx, opts.OrgID, opts.ConcurrentLimit)
+	if err != nil {
+		if !errors.Is(err, ErrConcurrentLimitReached) {
+			return nil, err
+		}
+		logger.Warn("Could not render image, hit the currency limit", "concurrencyLimit", opts.ConcurrentLimit, "orgID", opts.OrgID, "path", opts.Path)
 		if opts.ErrorConcurrentLimitReached {
 			return nil, ErrConcurrentLimitReached
 		}
@@ -295,10 +333,7 @@ func (rs *RenderingService) render(ctx context.Context, renderType RenderType, o
 		}, nil
 	}
 
-	defer func() {
-		metrics.MRenderingQueue.Set(float64(atomic.AddInt32(&rs.inProgressCount, -1)))
-	}()
-	metrics.MRenderingQueue.Set(float64(atomic.AddInt32(&rs.inProgressCount, 1)))
+	defer release()
 
 	if renderType == RenderPDF {
 		if !rs.features.IsEnabled(ctx, featuremgmt.FlagNewPDFRendering) {
@@ -321,7 +356,12 @@ func (rs *RenderingService) render(ctx context.Context, renderType RenderType, o
 
 	defer renderKeyProvider.afterRequest(ctx, opts.AuthOpts, renderKey)
 
-	res, err := rs.renderAction(ctx, renderType, renderKey, opts)
+	var res *RenderResult
+	err = rs.renderWithFailover(ctx, renderType, opts, func(strategy *renderStrategy) error {
+		var err error
+		res, err = strategy.render(ctx, renderType, renderKey, opts)
+		return err
+	})
 	if err != nil {
 		logger.Error("Failed to render image", "path", opts.Path, "error", err)
 		return nil, err
@@ -353,9 +393,11 @@ func (rs *RenderingService) renderCSV(ctx context.Context, opts CSVOpts, renderK
 		return nil, ErrRenderUnavailable
 	}
 
-	if int(atomic.LoadInt32(&rs.inProgressCount)) > opts.ConcurrentLimit {
-		return nil, ErrConcurrentLimitReached
+	release, err := rs.acquireRenderSlot(ctx, opts.OrgID, opts.ConcurrentLimit)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
 	logger.Info("Rendering", "path", opts.Path)
 	renderKey, err := renderKeyProvider.get(ctx, opts.AuthOpts)
@@ -365,12 +407,13 @@ func (rs *RenderingService) renderCSV(ctx context.Context, opts CSVOpts, renderK
 
 	defer renderKeyProvider.afterRequest(ctx, opts.AuthOpts, renderKey)
 
-	defer func() {
-		metrics.MRenderingQueue.Set(float64(atomic.AddInt32(&rs.inProgressCount, -1)))
-	}()
-
-	metrics.MRenderingQueue.Set(float64(atomic.AddInt32(&rs.inProgressCount, 1)))
-	return rs.renderCSVAction(ctx, renderKey, opts)
+	var res *RenderCSVResult
+	err = rs.renderWithFailover(ctx, RenderCSV, Opts{CommonOpts: opts.CommonOpts}, func(strategy *renderStrategy) error {
+		var err error
+		res, err = strategy.renderCSV(ctx, renderKey, opts)
+		return err
+	})
+	return res, err
 }
 
 func (rs *RenderingService) getNewFilePath(rt RenderType) (string, error) {
@@ -0,0 +1,186 @@
+package rendering
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+)
+
+const (
+	// StrategyRemote renders with the remote image renderer services of the server_url setting
+	StrategyRemote = "remote"
+	// StrategyPlugin renders with the grafana-image-renderer plugin
+	StrategyPlugin = "plugin"
+	// StrategyBuiltin draws simple panels without a browser
+	StrategyBuiltin = "builtin"
+)
+
+var defaultStrategies = []string{StrategyRemote, StrategyPlugin}
+
+// renderStrategy is a renderer of the chain. A render is sent to the first strategy which supports it, and fails over
+// to the next strategy when the renderer fails.
+type renderStrategy struct {
+	name      string
+	render    renderFunc
+	renderCSV renderCSVFunc // nil when the strategy can't render CSV
+	// supports checks if the strategy can render the render, nil when it supports every PNG and PDF render
+	supports func(renderType RenderType, opts Opts) bool
+	// ready checks if the renderer can be called, nil when it always can
+	ready func() bool
+	// healthy checks if the renderer is expected to succeed, nil when it always is
+	healthy func() bool
+
+	failures      atomic.Int32
+	cooldownUntil atomic.Int64
+}
+
+// newRenderStrategies returns the strategies of the strategies setting which are configured or installed
+func (rs *RenderingService) newRenderStrategies() []*renderStrategy {
+	names := rs.Cfg.RendererStrategies
+	if len(names) == 0 {
+		names = defaultStrategies
+	}
+
+	var strategies []*renderStrategy
+	for _, name := range names {
+		var strategy *renderStrategy
+		switch name {
+		case StrategyRemote:
+			if !rs.remoteAvailable() {
+				continue
+			}
+			strategy = &renderStrategy{
+				render:    rs.renderViaHTTP,
+				renderCSV: rs.renderCSVViaHTTP,
+				healthy:   rs.remoteHealthy,
+			}
+		case StrategyPlugin:
+			if !rs.pluginAvailable {
+				continue
+			}
+			strategy = &renderStrategy{
+				render:    rs.renderViaPlugin,
+				renderCSV: rs.renderCSVViaPlugin,
+				ready:     rs.pluginStarted.Load,
+			}
+		case StrategyBuiltin:
+			strategy = &renderStrategy{
+				render:   rs.renderViaBuiltin,
+				supports: rs.builtinSupports,
+			}
+		default:
+			rs.log.Warn("Unknown rendering strategy, skipping it", "strategy", name)
+			continue
+		}
+		strategy.name = name
+		metrics.MRenderingStrategyUp.WithLabelValues(name).Set(1)
+		strategies = append(strategies, strategy)
+	}
+	return strategies
+}
+
+func (rs *RenderingService) hasStrategy(name string) bool {
+	for _, strategy := range rs.strategies {
+		if strategy.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// candidates returns the strategies which can render the render: the healthy ones in the configured order, then the
+// unhealthy ones, which are only tried when the healthy ones fail
+func (rs *RenderingService) candidates(renderType RenderType, opts Opts) []*renderStrategy {
+	now := time.Now()
+	var healthy, unhealthy []*renderStrategy
+	for _, strategy := range rs.strategies {
+		if renderType == RenderCSV && strategy.renderCSV == nil ||
+			strategy.supports != nil && !strategy.supports(renderType, opts) ||
+			strategy.ready != nil && !strategy.ready() {
+			continue
+		}
+		if now.UnixNano() < strategy.cooldownUntil.Load() || strategy.healthy != nil && !strategy.healthy() {
+			unhealthy = append(unhealthy, strategy)
+			continue
+		}
+		healthy = append(healthy, strategy)
+	}
+	return append(healthy, unhealthy...)
+}
+
+// renderWithFailover calls the strategies in order until one succeeds. The timeouts and the cancellations don't fail
+// over, since the next strategy would most likely time out too.
+func (rs *RenderingService) renderWithFailover(ctx context.Context, renderType RenderType, opts Opts, call func(strategy *renderStrategy) error) error {
+	logger := rs.log.FromContext(ctx)
+
+	err := ErrRenderNotSupported
+	for _, strategy := range rs.candidates(renderType, opts) {
+		start := time.Now()
+		err = call(strategy)
+		rs.observeStrategy(strategy, renderType, time.Since(start), err)
+		if err == nil || ctx.Err() != nil || errors.Is(err, ErrTimeout) {
+			return err
+		}
+		logger.Warn("Renderer failed, trying the next rendering strategy", "strategy", strategy.name, "path", opts.Path, "error", err)
+	}
+	return err
+}
+
+// observeStrategy records the result of the render, and skips the strategy for the cooldown once it failed too many
+// times in a row
+func (rs *RenderingService) observeStrategy(strategy *renderStrategy, renderType RenderType, elapsed time.Duration, err error) {
+	status := "success"
+	switch {
+	case errors.Is(err, ErrTimeout):
+		status = "timeout"
+	case err != nil:
+		status = "failure"
+	}
+	metrics.MRenderingStrategyRequestTotal.WithLabelValues(strategy.name, status, string(renderType)).Inc()
+	metrics.MRenderingStrategySummary.WithLabelValues(strategy.name, status).Observe(float64(elapsed.Milliseconds()))
+
+	if err == nil {
+		if strategy.failures.Swap(0) >= int32(rs.failoverThreshold()) {
+			rs.log.Info("Rendering strategy is healthy again", "strategy", strategy.name)
+			metrics.MRenderingStrategyUp.WithLabelValues(strategy.name).Set(1)
+		}
+		strategy.cooldownUntil.Store(0)
+		return
+	}
+	if status == "timeout" {
+		return
+	}
+
+	failures := strategy.failures.Add(1)
+	if failures < int32(rs.failoverThreshold()) {
+		return
+	}
+	strategy.cooldownUntil.Store(time.Now().Add(rs.Cfg.RendererFailoverCooldown).UnixNano())
+	if failures == int32(rs.failoverThreshold()) {
+		rs.log.Warn("Rendering strategy failed too many times, skipping it", "strategy", strategy.name, "cooldown", rs.Cfg.RendererFailoverCooldown, "error", err)
+		metrics.MRenderingStrategyUp.WithLabelValues(strategy.name).Set(0)
+	}
+}
+
+func (rs *RenderingService) failoverThreshold() int {
+	if rs.Cfg.RendererFailoverThreshold <= 0 {
+		return 1
+	}
+	return rs.Cfg.RendererFailoverThreshold
+}
+
+// remoteHealthy checks if one of the remote image renderer services passed the last health check
+func (rs *RenderingService) remoteHealthy() bool {
+	if rs.pool == nil || len(rs.pool.endpoints) == 0 {
+		return true
+	}
+	for _, endpoint := range rs.pool.endpoints {
+		if endpoint.healthy.Load() {
+			return true
+		}
+	}
+	return false
+}
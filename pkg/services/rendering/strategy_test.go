@@ -0,0 +1,145 @@
+package rendering
+
+import (
+	"context"
+	"errors"
+	"image/png"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeRenderKeyProvider struct{}
+
+func (fakeRenderKeyProvider) get(_ context.Context, _ AuthOpts) (string, error) {
+	return "key", nil
+}
+
+func (fakeRenderKeyProvider) afterRequest(_ context.Context, _ AuthOpts, _ string) {}
+
+type fakeRenderer struct {
+	err   error
+	calls int
+}
+
+func (f *fakeRenderer) strategy(name string) *renderStrategy {
+	return &renderStrategy{
+		name: name,
+		render: func(_ context.Context, _ RenderType, _ string, _ Opts) (*RenderResult, error) {
+			f.calls++
+			if f.err != nil {
+				return nil, f.err
+			}
+			return &RenderResult{FilePath: name + ".png"}, nil
+		},
+	}
+}
+
+func setupStrategies(t *testing.T, strategies ...*renderStrategy) *RenderingService {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.RendererServerUrl = "http://localhost:8081/render"
+	cfg.RendererFailoverThreshold = 2
+	cfg.RendererFailoverCooldown = time.Minute
+	cfg.ImagesDir = t.TempDir()
+	return &RenderingService{
+		Cfg:                         cfg,
+		log:                         log.NewNopLogger(),
+		strategies:                  strategies,
+		perRequestRenderKeyProvider: fakeRenderKeyProvider{},
+	}
+}
+
+func TestRenderStrategies(t *testing.T) {
+	ctx := context.Background()
+	opts := Opts{CommonOpts: CommonOpts{ConcurrentLimit: 10}}
+
+	t.Run("fails over to the next strategy when a renderer fails", func(t *testing.T) {
+		remote, plugin := &fakeRenderer{err: ErrServerTimeout.Errorf("connection refused")}, &fakeRenderer{}
+		rs := setupStrategies(t, remote.strategy(StrategyRemote), plugin.strategy(StrategyPlugin))
+
+		result, err := rs.Render(ctx, RenderPNG, opts, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "plugin.png", result.FilePath)
+		assert.Equal(t, 1, remote.calls)
+	})
+
+	t.Run("doesn't fail over when the render times out", func(t *testing.T) {
+		remote, plugin := &fakeRenderer{err: ErrTimeout}, &fakeRenderer{}
+		rs := setupStrategies(t, remote.strategy(StrategyRemote), plugin.strategy(StrategyPlugin))
+
+		_, err := rs.Render(ctx, RenderPNG, opts, nil)
+		assert.ErrorIs(t, err, ErrTimeout)
+		assert.Equal(t, 0, plugin.calls)
+	})
+
+	t.Run("skips a strategy which failed too many times", func(t *testing.T) {
+		remote, plugin := &fakeRenderer{err: errors.New("failed")}, &fakeRenderer{}
+		rs := setupStrategies(t, remote.strategy(StrategyRemote), plugin.strategy(StrategyPlugin))
+
+		for i := 0; i < 3; i++ {
+			_, err := rs.Render(ctx, RenderPNG, opts, nil)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, remote.calls, "the remote renderer is skipped after the threshold")
+
+		remote.err = nil
+		rs.strategies[0].cooldownUntil.Store(time.Now().Add(-time.Second).UnixNano())
+		result, err := rs.Render(ctx, RenderPNG, opts, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "remote.png", result.FilePath, "the remote renderer is used again after the cooldown")
+	})
+
+	t.Run("tries the unhealthy strategies last", func(t *testing.T) {
+		remote, plugin := &fakeRenderer{}, &fakeRenderer{err: errors.New("failed")}
+		unhealthy := remote.strategy(StrategyRemote)
+		unhealthy.healthy = func() bool { return false }
+		rs := setupStrategies(t, unhealthy, plugin.strategy(StrategyPlugin))
+
+		result, err := rs.Render(ctx, RenderPNG, opts, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "remote.png", result.FilePath)
+		assert.Equal(t, 1, plugin.calls)
+	})
+
+	t.Run("draws the panels with the builtin renderer", func(t *testing.T) {
+		remote := &fakeRenderer{err: errors.New("failed")}
+		rs := setupStrategies(t, remote.strategy(StrategyRemote))
+		rs.Cfg.RendererBuiltinPanelTypes = []string{"text"}
+		rs.strategies = append(rs.strategies, &renderStrategy{name: StrategyBuiltin, render: rs.renderViaBuiltin, supports: rs.builtinSupports})
+
+		panelOpts := opts
+		panelOpts.Width, panelOpts.Height, panelOpts.Theme = 400, 200, models.ThemeLight
+		panelOpts.Panel = &PanelInfo{Type: "text", Title: "Runbook", Options: map[string]any{"content": "# Restart\nRun `make restart`"}}
+		result, err := rs.Render(ctx, RenderPNG, panelOpts, nil)
+		require.NoError(t, err)
+
+		f, err := os.Open(result.FilePath)
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+		img, err := png.Decode(f)
+		require.NoError(t, err)
+		assert.Equal(t, 400, img.Bounds().Dx())
+
+		panelOpts.Panel.Type = "timeseries"
+		_, err = rs.Render(ctx, RenderPNG, panelOpts, nil)
+		assert.Error(t, err, "the builtin renderer doesn't draw the other panel types")
+	})
+}
+
+func TestTextPanelContent(t *testing.T) {
+	content := textPanelContent(map[string]any{
+		"mode":    "markdown",
+		"content": "## Title\n- **bold** item\n[link](http://grafana.com)",
+	})
+	assert.Equal(t, "Title\nbold item\nlink", content)
+
+	assert.Equal(t, []string{"a b", "cde", "fg", "", "h"}, wrapText("a b cdefg\n\nh", 3))
+}
@@ -0,0 +1,125 @@
+package resourcewatch
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister) {
+	router.Group("/api/resources", func(watchRoute routing.RouteRegister) {
+		watchRoute.Get("/kinds", routing.Wrap(s.listKinds))
+		watchRoute.Get("/watch", requestmeta.SetSLOGroup(requestmeta.SLOGroupNone), routing.Wrap(s.watch))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /resources/kinds resources listWatchedKinds
+//
+// # List the resource kinds which can be watched
+//
+// Responses:
+// 200: listWatchedKindsResponse
+// 401: unauthorisedError
+func (s *Service) listKinds(c *contextmodel.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, Kinds)
+}
+
+// swagger:route GET /resources/watch resources watchResources
+//
+// # Watch the changes of resources
+//
+// Streams the changes of the dashboards, folders and playlists the signed in user can read, as newline
+// delimited JSON events. Each event has a resume token: passing the resume token of the last received event
+// resumes the watch after this event, the changes made in between are sent first. Only the latest change of
+// each resource is sent when a watch resumes. The stream ends with an ERROR event when the client reads the
+// events slower than they happen, or the storage fails; the client resumes the watch from the resume token
+// of this event.
+//
+// Produces:
+// - application/x-ndjson
+//
+// Responses:
+// 200: watchResourcesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) watch(c *contextmodel.ReqContext) response.Response {
+	query := WatchQuery{
+		Group:       c.Query("group"),
+		Kind:        c.Query("kind"),
+		Namespace:   c.Query("namespace"),
+		ResumeToken: c.Query("resumeToken"),
+		Initial:     c.QueryBool("initial"),
+	}
+	if query.Namespace == "" {
+		query.Namespace = request.GetNamespaceMapper(s.cfg)(c.GetOrgID())
+	}
+
+	ctx := c.Req.Context()
+	w, err := s.Watch(ctx, query)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to watch resources", err)
+	}
+
+	c.Resp.Header().Set("Content-Type", "application/x-ndjson")
+	c.Resp.Header().Set("Cache-Control", "no-cache")
+	c.Resp.WriteHeader(http.StatusOK)
+	c.Resp.Flush()
+
+	enc := json.NewEncoder(c.Resp)
+	err = w.Run(ctx, func(e *Event) error {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+		c.Resp.Flush()
+		return nil
+	})
+	if err != nil {
+		s.log.FromContext(ctx).Debug("Resource watch ended", "error", err)
+	}
+	// the response is already written
+	return nil
+}
+
+// swagger:parameters watchResources
+type WatchResourcesParams struct {
+	// Group of the resources, eg: dashboard.grafana.app. All the groups are watched when it's empty.
+	// in:query
+	// required:false
+	Group string `json:"group"`
+	// Kind of the resources, eg: Dashboard. All the kinds are watched when it's empty.
+	// in:query
+	// required:false
+	Kind string `json:"kind"`
+	// Namespace of the resources, the namespace of the organization of the user by default
+	// in:query
+	// required:false
+	Namespace string `json:"namespace"`
+	// Resume token of the last event received
+	// in:query
+	// required:false
+	ResumeToken string `json:"resumeToken"`
+	// Send the existing resources as ADDED events before the changes, followed by a BOOKMARK event
+	// in:query
+	// required:false
+	Initial bool `json:"initial"`
+}
+
+// swagger:response watchResourcesResponse
+type WatchResourcesResponse struct {
+	// in:body
+	Body Event `json:"body"`
+}
+
+// swagger:response listWatchedKindsResponse
+type ListWatchedKindsResponse struct {
+	// in:body
+	Body []Kind `json:"body"`
+}
@@ -0,0 +1,344 @@
+package resourcewatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dashboardv1 "github.com/grafana/grafana/apps/dashboard/pkg/apis/dashboard/v1beta1"
+	folderv1 "github.com/grafana/grafana/apps/folder/pkg/apis/folder/v1beta1"
+	playlistv0alpha1 "github.com/grafana/grafana/apps/playlist/pkg/apis/playlist/v0alpha1"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+const (
+	// bufferSize is the number of events buffered for a client
+	bufferSize = 100
+	// slowClientTimeout is the time after which a client whose buffer stays full is disconnected,
+	// it resumes the watch from the resume token of the last event it received
+	slowClientTimeout = 10 * time.Second
+)
+
+const (
+	EventAdded    = "ADDED"
+	EventModified = "MODIFIED"
+	EventDeleted  = "DELETED"
+	// EventBookmark marks the end of the initial events of a kind
+	EventBookmark = "BOOKMARK"
+	// EventError ends the stream, the client resumes the watch from the resume token of the event
+	EventError = "ERROR"
+)
+
+var eventTypes = map[resourcepb.WatchEvent_Type]string{
+	resourcepb.WatchEvent_ADDED:    EventAdded,
+	resourcepb.WatchEvent_MODIFIED: EventModified,
+	resourcepb.WatchEvent_DELETED:  EventDeleted,
+}
+
+var (
+	ErrNoWatchedKind = errutil.BadRequest("resourcewatch.no-kind",
+		errutil.WithPublicMessage("No watchable resource matches the group and kind"))
+	ErrInvalidResumeToken = errutil.BadRequest("resourcewatch.invalid-resume-token",
+		errutil.WithPublicMessage("Invalid resume token"))
+
+	// errSlowClient ends the watch of a client which doesn't read the events fast enough
+	errSlowClient = errors.New("the events are read slower than they happen")
+)
+
+// Kind is a unified storage resource which can be watched
+type Kind struct {
+	Group    string `json:"group"`
+	Kind     string `json:"kind"`
+	Resource string `json:"resource"`
+}
+
+func (k Kind) key() string {
+	return k.Group + "/" + k.Resource
+}
+
+// Kinds are the resources which can be watched
+var Kinds = []Kind{
+	{
+		Group:    dashboardv1.DashboardResourceInfo.GroupResource().Group,
+		Kind:     dashboardv1.DashboardResourceInfo.GroupVersionKind().Kind,
+		Resource: dashboardv1.DashboardResourceInfo.GroupResource().Resource,
+	},
+	{
+		Group:    folderv1.FolderResourceInfo.GroupResource().Group,
+		Kind:     folderv1.FolderResourceInfo.GroupVersionKind().Kind,
+		Resource: folderv1.FolderResourceInfo.GroupResource().Resource,
+	},
+	{
+		Group:    playlistv0alpha1.PlaylistKind().Group(),
+		Kind:     playlistv0alpha1.PlaylistKind().Kind(),
+		Resource: playlistv0alpha1.PlaylistKind().Plural(),
+	},
+}
+
+// WatchQuery selects the resources to watch, the empty fields match any value
+type WatchQuery struct {
+	Group     string
+	Kind      string
+	Namespace string
+	// ResumeToken is the resume token of the last event received by the client
+	ResumeToken string
+	// Initial sends the existing resources as added events, before the changes, for the kinds the
+	// resume token has no position of
+	Initial bool
+}
+
+// Event is a change of a watched resource
+type Event struct {
+	Type            string `json:"type"`
+	Group           string `json:"group,omitempty"`
+	Kind            string `json:"kind,omitempty"`
+	Namespace       string `json:"namespace,omitempty"`
+	Name            string `json:"name,omitempty"`
+	ResourceVersion int64  `json:"resourceVersion,omitempty"`
+	// Object is the resource, or its last state when it's deleted
+	Object json.RawMessage `json:"object,omitempty"`
+	// ResumeToken resumes the watch after this event
+	ResumeToken string `json:"resumeToken"`
+	Error       string `json:"error,omitempty"`
+}
+
+func ProvideService(cfg *setting.Cfg, client resource.ResourceClient, router routing.RouteRegister) *Service {
+	s := &Service{
+		cfg:    cfg,
+		client: client,
+		log:    log.New("resourcewatch"),
+	}
+	s.registerRoutes(router)
+	return s
+}
+
+// Service streams the changes of unified storage resources to external consumers, which resume their watch
+// from the resume token of the last event they received.
+type Service struct {
+	cfg    *setting.Cfg
+	client resource.ResourceClient
+	log    log.Logger
+}
+
+// kindEvent is an event received from the storage watch of a kind
+type kindEvent struct {
+	kind  Kind
+	event *Event
+	// position is the resource version the watch of the kind resumes from after the event, zero while the
+	// initial events are sent as they aren't sorted by resource version
+	position int64
+}
+
+// Watch is the watch of a client, started by Service.Watch and run by Run
+type Watch struct {
+	token  resumeToken
+	events chan kindEvent
+	errs   chan error
+	cancel context.CancelFunc
+	log    log.Logger
+}
+
+// Watch starts watching the resources matching the query, from the positions of the resume token.
+// Run must be called to send the events and stop the watch.
+func (s *Service) Watch(ctx context.Context, query WatchQuery) (*Watch, error) {
+	kinds := filterKinds(query.Group, query.Kind)
+	if len(kinds) == 0 {
+		return nil, ErrNoWatchedKind.Errorf("no kind matches group %q and kind %q", query.Group, query.Kind)
+	}
+	token, err := parseResumeToken(query.ResumeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	w := &Watch{
+		token:  token,
+		events: make(chan kindEvent, bufferSize),
+		errs:   make(chan error, len(kinds)),
+		cancel: cancel,
+		log:    s.log,
+	}
+	for _, kind := range kinds {
+		if err := s.watchKind(ctx, w, kind, query); err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+func (s *Service) watchKind(ctx context.Context, w *Watch, kind Kind, query WatchQuery) error {
+	key := &resourcepb.ResourceKey{
+		Namespace: query.Namespace,
+		Group:     kind.Group,
+		Resource:  kind.Resource,
+	}
+	req := &resourcepb.WatchRequest{
+		Since:               w.token[kind.key()],
+		SendInitialEvents:   w.token[kind.key()] == 0 && query.Initial,
+		AllowWatchBookmarks: true,
+		Options:             &resourcepb.ListOptions{Key: key},
+	}
+	if req.Since == 0 && !req.SendInitialEvents {
+		// the watch starts from the current resource version, which the client must be able to resume from
+		rv, err := s.currentResourceVersion(ctx, key)
+		if err != nil {
+			return err
+		}
+		req.Since = rv
+	}
+	w.token[kind.key()] = req.Since
+
+	stream, err := s.client.Watch(ctx, req)
+	if err != nil {
+		return err
+	}
+	go func() {
+		w.errs <- receive(stream, kind, req.SendInitialEvents, func(e kindEvent) bool {
+			return push(ctx, w.events, e)
+		})
+	}()
+	return nil
+}
+
+// Run sends the events of the watch to send, until the context is done or an error ends the watch.
+// The errors of the storage watches end the watch with an error event, the client resumes the watch
+// from the resume token of this event.
+func (w *Watch) Run(ctx context.Context, send func(*Event) error) error {
+	defer w.cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-w.errs:
+			if ctx.Err() != nil {
+				return nil
+			}
+			// the events received before the error are sent first
+			for range len(w.events) {
+				if err := w.send(<-w.events, send); err != nil {
+					return err
+				}
+			}
+			if errors.Is(err, errSlowClient) {
+				w.log.Debug("Closing the watch of a slow client")
+			} else {
+				w.log.Warn("Watch of unified storage failed", "error", err)
+			}
+			return send(&Event{Type: EventError, Error: err.Error(), ResumeToken: w.token.String()})
+		case e := <-w.events:
+			if err := w.send(e, send); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// send moves the position of the watch to the event and sends it
+func (w *Watch) send(e kindEvent, send func(*Event) error) error {
+	if e.position > 0 {
+		w.token[e.kind.key()] = e.position
+	}
+	e.event.ResumeToken = w.token.String()
+	return send(e.event)
+}
+
+// push buffers the event, it returns false when the buffer stays full for the slow client timeout.
+// The storage watch isn't read while the buffer is full, which slows down the storage sending the events.
+func push(ctx context.Context, events chan<- kindEvent, e kindEvent) bool {
+	select {
+	case events <- e:
+		return true
+	default:
+	}
+
+	timer := time.NewTimer(slowClientTimeout)
+	defer timer.Stop()
+	select {
+	case events <- e:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// currentResourceVersion returns the latest resource version of the resources matching the key
+func (s *Service) currentResourceVersion(ctx context.Context, key *resourcepb.ResourceKey) (int64, error) {
+	rsp, err := s.client.List(ctx, &resourcepb.ListRequest{
+		Options: &resourcepb.ListOptions{Key: key},
+		Limit:   1,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if rsp.Error != nil {
+		return 0, resource.GetError(rsp.Error)
+	}
+	return rsp.ResourceVersion, nil
+}
+
+// receive passes the events of the storage watch of the kind to push, until push fails or the watch ends
+func receive(stream resourcepb.ResourceStore_WatchClient, kind Kind, initial bool, push func(kindEvent) bool) error {
+	for {
+		we, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return errors.New("the watch was closed")
+			}
+			return err
+		}
+
+		e := kindEvent{kind: kind, event: &Event{Group: kind.Group, Kind: kind.Kind}}
+		switch we.Type {
+		case resourcepb.WatchEvent_BOOKMARK:
+			initial = false
+			e.event.Type = EventBookmark
+			e.event.ResourceVersion = we.Resource.Version
+		case resourcepb.WatchEvent_ADDED, resourcepb.WatchEvent_MODIFIED, resourcepb.WatchEvent_DELETED:
+			e.event.Type = eventTypes[we.Type]
+			e.event.ResourceVersion = we.Resource.Version
+			e.event.Object = we.Resource.Value
+			if we.Type == resourcepb.WatchEvent_DELETED && we.Previous != nil {
+				e.event.Object = we.Previous.Value
+			}
+			if len(e.event.Object) > 0 {
+				meta := metav1.PartialObjectMetadata{}
+				if err := json.Unmarshal(e.event.Object, &meta); err != nil {
+					return err
+				}
+				e.event.Namespace = meta.Namespace
+				e.event.Name = meta.Name
+			}
+		default:
+			continue
+		}
+		if !initial {
+			e.position = e.event.ResourceVersion
+		}
+		if !push(e) {
+			return errSlowClient
+		}
+	}
+}
+
+func filterKinds(group, kind string) []Kind {
+	var kinds []Kind
+	for _, k := range Kinds {
+		if (group == "" || group == k.Group) && (kind == "" || kind == k.Kind) {
+			kinds = append(kinds, k)
+		}
+	}
+	return kinds
+}
@@ -0,0 +1,171 @@
+package resourcewatch
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+const dashboardsKey = "dashboard.grafana.app/dashboards"
+
+type fakeClient struct {
+	resource.ResourceClient
+	rv       int64
+	events   []*resourcepb.WatchEvent
+	requests []*resourcepb.WatchRequest
+}
+
+func (c *fakeClient) List(ctx context.Context, req *resourcepb.ListRequest, opts ...grpc.CallOption) (*resourcepb.ListResponse, error) {
+	return &resourcepb.ListResponse{ResourceVersion: c.rv}, nil
+}
+
+func (c *fakeClient) Watch(ctx context.Context, req *resourcepb.WatchRequest, opts ...grpc.CallOption) (resourcepb.ResourceStore_WatchClient, error) {
+	c.requests = append(c.requests, req)
+	return &fakeStream{events: c.events}, nil
+}
+
+// fakeStream sends the events and ends the watch
+type fakeStream struct {
+	grpc.ClientStream
+	events []*resourcepb.WatchEvent
+}
+
+func (s *fakeStream) Recv() (*resourcepb.WatchEvent, error) {
+	if len(s.events) == 0 {
+		return nil, io.EOF
+	}
+	e := s.events[0]
+	s.events = s.events[1:]
+	return e, nil
+}
+
+func watchEvent(t resourcepb.WatchEvent_Type, name string, rv int64) *resourcepb.WatchEvent {
+	return &resourcepb.WatchEvent{
+		Type: t,
+		Resource: &resourcepb.WatchEvent_Resource{
+			Value:   []byte(`{"metadata":{"name":"` + name + `","namespace":"default"}}`),
+			Version: rv,
+		},
+	}
+}
+
+func runWatch(t *testing.T, s *Service, query WatchQuery) []*Event {
+	w, err := s.Watch(context.Background(), query)
+	require.NoError(t, err)
+	var events []*Event
+	require.NoError(t, w.Run(context.Background(), func(e *Event) error {
+		events = append(events, e)
+		return nil
+	}))
+	return events
+}
+
+func TestWatch(t *testing.T) {
+	client := &fakeClient{rv: 100}
+	s := &Service{client: client, log: log.NewNopLogger()}
+
+	t.Run("invalid queries", func(t *testing.T) {
+		_, err := s.Watch(context.Background(), WatchQuery{Kind: "Alert"})
+		require.ErrorIs(t, err, ErrNoWatchedKind)
+
+		_, err = s.Watch(context.Background(), WatchQuery{ResumeToken: "not a token"})
+		require.ErrorIs(t, err, ErrInvalidResumeToken)
+	})
+
+	t.Run("the watch starts from the current resource version", func(t *testing.T) {
+		client.requests = nil
+		client.events = []*resourcepb.WatchEvent{
+			watchEvent(resourcepb.WatchEvent_MODIFIED, "a", 120),
+			{
+				Type:     resourcepb.WatchEvent_DELETED,
+				Resource: &resourcepb.WatchEvent_Resource{Version: 130},
+				Previous: &resourcepb.WatchEvent_Resource{Value: []byte(`{"metadata":{"name":"b"}}`), Version: 110},
+			},
+		}
+		events := runWatch(t, s, WatchQuery{Kind: "Dashboard", Namespace: "default"})
+
+		require.Len(t, client.requests, 1)
+		require.Equal(t, int64(100), client.requests[0].Since)
+		require.False(t, client.requests[0].SendInitialEvents)
+		require.Equal(t, "default", client.requests[0].Options.Key.Namespace)
+
+		require.Len(t, events, 3)
+		require.Equal(t, EventModified, events[0].Type)
+		require.Equal(t, "Dashboard", events[0].Kind)
+		require.Equal(t, "a", events[0].Name)
+		require.Equal(t, "default", events[0].Namespace)
+		require.Equal(t, resumeToken{dashboardsKey: 120}.String(), events[0].ResumeToken)
+		// the deleted resource is the previous object
+		require.Equal(t, EventDeleted, events[1].Type)
+		require.Equal(t, "b", events[1].Name)
+		// the storage watch ended
+		require.Equal(t, EventError, events[2].Type)
+		require.Equal(t, resumeToken{dashboardsKey: 130}.String(), events[2].ResumeToken)
+	})
+
+	t.Run("the watch resumes from the resume token", func(t *testing.T) {
+		client.requests, client.events = nil, nil
+		runWatch(t, s, WatchQuery{
+			Kind:        "Dashboard",
+			ResumeToken: resumeToken{dashboardsKey: 130}.String(),
+			Initial:     true,
+		})
+
+		require.Len(t, client.requests, 1)
+		require.Equal(t, int64(130), client.requests[0].Since)
+		require.False(t, client.requests[0].SendInitialEvents)
+	})
+
+	t.Run("the initial events don't move the position", func(t *testing.T) {
+		client.requests = nil
+		client.events = []*resourcepb.WatchEvent{
+			watchEvent(resourcepb.WatchEvent_ADDED, "b", 90),
+			watchEvent(resourcepb.WatchEvent_ADDED, "a", 80),
+			{Type: resourcepb.WatchEvent_BOOKMARK, Resource: &resourcepb.WatchEvent_Resource{Version: 100}},
+		}
+		events := runWatch(t, s, WatchQuery{Group: "dashboard.grafana.app", Initial: true})
+
+		require.Len(t, client.requests, 1)
+		require.True(t, client.requests[0].SendInitialEvents)
+		require.Len(t, events, 4)
+		require.Equal(t, resumeToken{dashboardsKey: 0}.String(), events[0].ResumeToken)
+		require.Equal(t, resumeToken{dashboardsKey: 0}.String(), events[1].ResumeToken)
+		require.Equal(t, EventBookmark, events[2].Type)
+		require.Equal(t, resumeToken{dashboardsKey: 100}.String(), events[2].ResumeToken)
+	})
+
+	t.Run("every kind is watched from its own position", func(t *testing.T) {
+		client.requests, client.events = nil, nil
+		runWatch(t, s, WatchQuery{ResumeToken: resumeToken{dashboardsKey: 130}.String()})
+
+		require.Len(t, client.requests, len(Kinds))
+		for _, req := range client.requests {
+			if req.Options.Key.Group+"/"+req.Options.Key.Resource == dashboardsKey {
+				require.Equal(t, int64(130), req.Since)
+			} else {
+				require.Equal(t, int64(100), req.Since)
+			}
+		}
+	})
+}
+
+func TestResumeToken(t *testing.T) {
+	token := resumeToken{dashboardsKey: 130, "folder.grafana.app/folders": 12}
+	parsed, err := parseResumeToken(token.String())
+	require.NoError(t, err)
+	require.Equal(t, token, parsed)
+
+	parsed, err = parseResumeToken("")
+	require.NoError(t, err)
+	require.Empty(t, parsed)
+
+	_, err = parseResumeToken(resumeToken{dashboardsKey: -1}.String())
+	require.ErrorIs(t, err, ErrInvalidResumeToken)
+}
@@ -0,0 +1,37 @@
+package resourcewatch
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// resumeToken is the position of a watch: the resource version of the last event received for each kind,
+// by group/resource. The resource versions of different kinds aren't comparable, so each kind resumes from
+// its own position.
+type resumeToken map[string]int64
+
+func (t resumeToken) String() string {
+	// the positions are always encoded
+	data, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func parseResumeToken(s string) (resumeToken, error) {
+	token := resumeToken{}
+	if s == "" {
+		return token, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidResumeToken.Errorf("failed to decode resume token: %w", err)
+	}
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, ErrInvalidResumeToken.Errorf("failed to decode resume token: %w", err)
+	}
+	for _, rv := range token {
+		if rv < 0 {
+			return nil, ErrInvalidResumeToken.Errorf("negative resource version %d", rv)
+		}
+	}
+	return token, nil
+}
@@ -0,0 +1,116 @@
+package savedsearch
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/search/model"
+)
+
+var (
+	ErrNotFound = errutil.NotFound(
+		"savedsearch.not-found", errutil.WithPublicMessage("Saved search not found"))
+	ErrNameRequired = errutil.BadRequest(
+		"savedsearch.name-required", errutil.WithPublicMessage("Saved search name is required"))
+	ErrInvalidWebhookURL = errutil.BadRequest(
+		"savedsearch.invalid-webhook-url", errutil.WithPublicMessage("Subscription webhook URL must be an absolute https URL"))
+	ErrNotTeamMember = errutil.Forbidden(
+		"savedsearch.not-team-member", errutil.WithPublicMessage("Only members of the team can manage its saved searches"))
+	ErrSubscriptionsDisabled = errutil.BadRequest(
+		"savedsearch.subscriptions-disabled", errutil.WithPublicMessage("Saved search subscriptions are disabled"))
+)
+
+type Service interface {
+	// CreateSavedSearch saves a search owned by the user, or by a team of the user when TeamID is set
+	CreateSavedSearch(ctx context.Context, cmd *CreateSavedSearchCommand) (*SavedSearch, error)
+	UpdateSavedSearch(ctx context.Context, cmd *UpdateSavedSearchCommand) (*SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, cmd *DeleteSavedSearchCommand) error
+	GetSavedSearch(ctx context.Context, query *GetSavedSearchQuery) (*SavedSearch, error)
+	// ListSavedSearches returns the searches owned by the user and by their teams
+	ListSavedSearches(ctx context.Context, query *ListSavedSearchesQuery) ([]*SavedSearch, error)
+}
+
+// SavedSearch is a named search of dashboards and folders owned by a user or a team
+type SavedSearch struct {
+	UID   string `json:"uid"`
+	OrgID int64  `json:"orgId"`
+	Name  string `json:"name"`
+	// UserID is set for the searches owned by a user
+	UserID int64 `json:"userId,omitempty"`
+	// TeamID is set for the searches owned by a team
+	TeamID       int64         `json:"teamId,omitempty"`
+	Search       SearchQuery   `json:"search"`
+	Subscription *Subscription `json:"subscription,omitempty"`
+	CreatedBy    int64         `json:"createdBy"`
+	Created      time.Time     `json:"created"`
+	Updated      time.Time     `json:"updated"`
+}
+
+// SearchQuery holds the query, filters and sort of a saved search, see the search API for their meaning
+type SearchQuery struct {
+	Query      string   `json:"query,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Type       string   `json:"type,omitempty"`
+	FolderUIDs []string `json:"folderUIDs,omitempty"`
+	Starred    bool     `json:"starred,omitempty"`
+	Sort       string   `json:"sort,omitempty"`
+}
+
+// Subscription posts the resources starting to match the saved search to a webhook
+type Subscription struct {
+	WebhookURL string `json:"webhookUrl"`
+	// LastEvaluated is the last time the matching resources were checked by the background job
+	LastEvaluated *time.Time `json:"lastEvaluated,omitempty"`
+}
+
+type CreateSavedSearchCommand struct {
+	OrgID int64 `json:"-"`
+	// UserID is the user creating the search, which owns it unless TeamID is set
+	UserID    int64       `json:"-"`
+	UserTeams []int64     `json:"-"`
+	TeamID    int64       `json:"teamId,omitempty"`
+	Name      string      `json:"name"`
+	Search    SearchQuery `json:"search"`
+	// WebhookURL subscribes to the resources starting to match the search when set
+	WebhookURL string `json:"webhookUrl,omitempty"`
+}
+
+type UpdateSavedSearchCommand struct {
+	UID        string      `json:"-"`
+	OrgID      int64       `json:"-"`
+	UserID     int64       `json:"-"`
+	UserTeams  []int64     `json:"-"`
+	Name       string      `json:"name"`
+	Search     SearchQuery `json:"search"`
+	WebhookURL string      `json:"webhookUrl,omitempty"`
+}
+
+type DeleteSavedSearchCommand struct {
+	UID       string
+	OrgID     int64
+	UserID    int64
+	UserTeams []int64
+}
+
+type GetSavedSearchQuery struct {
+	UID       string
+	OrgID     int64
+	UserID    int64
+	UserTeams []int64
+}
+
+type ListSavedSearchesQuery struct {
+	OrgID     int64
+	UserID    int64
+	UserTeams []int64
+}
+
+// Notification is posted to the webhook of a subscription when resources start matching the saved search
+type Notification struct {
+	UID      string      `json:"uid"`
+	OrgID    int64       `json:"orgId"`
+	Name     string      `json:"name"`
+	Search   SearchQuery `json:"search"`
+	NewItems []model.Hit `json:"newItems"`
+}
@@ -0,0 +1,180 @@
+package savedsearchimpl
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/savedsearch"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister) {
+	router.Group("/api/saved-searches", func(searchRoute routing.RouteRegister) {
+		searchRoute.Get("/", routing.Wrap(s.listSavedSearches))
+		searchRoute.Post("/", routing.Wrap(s.createSavedSearch))
+		searchRoute.Get("/:uid", routing.Wrap(s.getSavedSearch))
+		searchRoute.Put("/:uid", routing.Wrap(s.updateSavedSearch))
+		searchRoute.Delete("/:uid", routing.Wrap(s.deleteSavedSearch))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /saved-searches saved_searches listSavedSearches
+//
+// # List saved searches
+//
+// Returns the saved searches owned by the signed in user and by their teams.
+//
+// Responses:
+// 200: listSavedSearchesResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *Service) listSavedSearches(c *contextmodel.ReqContext) response.Response {
+	searches, err := s.ListSavedSearches(c.Req.Context(), &savedsearch.ListSavedSearchesQuery{
+		OrgID:     c.GetOrgID(),
+		UserID:    c.SignedInUser.UserID,
+		UserTeams: c.SignedInUser.GetTeams(),
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list saved searches", err)
+	}
+	return response.JSON(http.StatusOK, searches)
+}
+
+// swagger:route POST /saved-searches saved_searches createSavedSearch
+//
+// # Create a saved search
+//
+// Saves a search owned by the signed in user, or by one of their teams when teamId is set.
+// Setting webhookUrl subscribes to the dashboards and folders starting to match the search.
+//
+// Responses:
+// 200: savedSearchResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) createSavedSearch(c *contextmodel.ReqContext) response.Response {
+	cmd := savedsearch.CreateSavedSearchCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.GetOrgID()
+	cmd.UserID = c.SignedInUser.UserID
+	cmd.UserTeams = c.SignedInUser.GetTeams()
+
+	result, err := s.CreateSavedSearch(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create saved search", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route GET /saved-searches/{uid} saved_searches getSavedSearch
+//
+// # Get a saved search
+//
+// Responses:
+// 200: savedSearchResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) getSavedSearch(c *contextmodel.ReqContext) response.Response {
+	result, err := s.GetSavedSearch(c.Req.Context(), &savedsearch.GetSavedSearchQuery{
+		UID:       web.Params(c.Req)[":uid"],
+		OrgID:     c.GetOrgID(),
+		UserID:    c.SignedInUser.UserID,
+		UserTeams: c.SignedInUser.GetTeams(),
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get saved search", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route PUT /saved-searches/{uid} saved_searches updateSavedSearch
+//
+// # Update a saved search
+//
+// Changing the search or the webhookUrl restarts the subscription, the dashboards and folders matching
+// the search at its next evaluation aren't notified.
+//
+// Responses:
+// 200: savedSearchResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) updateSavedSearch(c *contextmodel.ReqContext) response.Response {
+	cmd := savedsearch.UpdateSavedSearchCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.UID = web.Params(c.Req)[":uid"]
+	cmd.OrgID = c.GetOrgID()
+	cmd.UserID = c.SignedInUser.UserID
+	cmd.UserTeams = c.SignedInUser.GetTeams()
+
+	result, err := s.UpdateSavedSearch(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update saved search", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route DELETE /saved-searches/{uid} saved_searches deleteSavedSearch
+//
+// # Delete a saved search
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) deleteSavedSearch(c *contextmodel.ReqContext) response.Response {
+	err := s.DeleteSavedSearch(c.Req.Context(), &savedsearch.DeleteSavedSearchCommand{
+		UID:       web.Params(c.Req)[":uid"],
+		OrgID:     c.GetOrgID(),
+		UserID:    c.SignedInUser.UserID,
+		UserTeams: c.SignedInUser.GetTeams(),
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete saved search", err)
+	}
+	return response.Success("Saved search deleted")
+}
+
+// swagger:parameters getSavedSearch updateSavedSearch deleteSavedSearch
+type SavedSearchUIDParam struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters createSavedSearch
+type CreateSavedSearchParams struct {
+	// in:body
+	// required:true
+	Body savedsearch.CreateSavedSearchCommand `json:"body"`
+}
+
+// swagger:parameters updateSavedSearch
+type UpdateSavedSearchParams struct {
+	// in:body
+	// required:true
+	Body savedsearch.UpdateSavedSearchCommand `json:"body"`
+}
+
+// swagger:response savedSearchResponse
+type SavedSearchResponse struct {
+	// in:body
+	Body *savedsearch.SavedSearch `json:"body"`
+}
+
+// swagger:response listSavedSearchesResponse
+type ListSavedSearchesResponse struct {
+	// in:body
+	Body []*savedsearch.SavedSearch `json:"body"`
+}
@@ -0,0 +1,379 @@
+package savedsearchimpl
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
+	"github.com/grafana/grafana/pkg/services/savedsearch"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var _ savedsearch.Service = (*Service)(nil)
+
+// maxEvaluatedHits bounds the resources matching a subscribed search, like the search API limit
+const maxEvaluatedHits = 5000
+
+func ProvideService(
+	cfg *setting.Cfg, sqlStore db.DB, router routing.RouteRegister, searchService search.Service,
+	userService user.Service, acService ac.Service, serverLock *serverlock.ServerLockService, tracer trace.Tracer,
+) *Service {
+	s := &Service{
+		cfg:           cfg,
+		store:         &xormStore{db: sqlStore},
+		searchService: searchService,
+		userService:   userService,
+		acService:     acService,
+		serverLock:    serverLock,
+		notifier:      newWebhookNotifier(cfg.BuildVersion),
+		log:           log.New("savedsearch"),
+		tracer:        tracer,
+		now:           time.Now,
+	}
+	s.registerRoutes(router)
+	return s
+}
+
+// Service stores the saved searches, and notifies their subscriptions of the resources
+// starting to match them from Run.
+type Service struct {
+	cfg           *setting.Cfg
+	store         store
+	searchService search.Service
+	userService   user.Service
+	acService     ac.Service
+	serverLock    *serverlock.ServerLockService
+	notifier      notifier
+	log           log.Logger
+	tracer        trace.Tracer
+	now           func() time.Time
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Search.SavedSearchSubscriptionsEnabled
+}
+
+func (s *Service) CreateSavedSearch(ctx context.Context, cmd *savedsearch.CreateSavedSearchCommand) (*savedsearch.SavedSearch, error) {
+	ctx, span := s.tracer.Start(ctx, "savedsearch.CreateSavedSearch")
+	defer span.End()
+
+	if err := s.validate(cmd.Name, cmd.WebhookURL); err != nil {
+		return nil, err
+	}
+
+	owner := cmd.UserID
+	if cmd.TeamID != 0 {
+		if !isTeamMember(cmd.TeamID, cmd.UserTeams) {
+			return nil, savedsearch.ErrNotTeamMember.Errorf("user %d is not a member of team %d", cmd.UserID, cmd.TeamID)
+		}
+		owner = 0
+	}
+
+	query, err := json.Marshal(cmd.Search)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	row := &savedSearch{
+		UID:        util.GenerateShortUID(),
+		OrgID:      cmd.OrgID,
+		UserID:     owner,
+		TeamID:     cmd.TeamID,
+		Name:       strings.TrimSpace(cmd.Name),
+		Search:     string(query),
+		WebhookURL: cmd.WebhookURL,
+		CreatedBy:  cmd.UserID,
+		Created:    now,
+		Updated:    now,
+	}
+	if err := s.store.Insert(ctx, row); err != nil {
+		return nil, err
+	}
+	return toDTO(row)
+}
+
+func (s *Service) UpdateSavedSearch(ctx context.Context, cmd *savedsearch.UpdateSavedSearchCommand) (*savedsearch.SavedSearch, error) {
+	ctx, span := s.tracer.Start(ctx, "savedsearch.UpdateSavedSearch")
+	defer span.End()
+
+	if err := s.validate(cmd.Name, cmd.WebhookURL); err != nil {
+		return nil, err
+	}
+
+	row, err := s.get(ctx, cmd.OrgID, cmd.UID, cmd.UserID, cmd.UserTeams)
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := json.Marshal(cmd.Search)
+	if err != nil {
+		return nil, err
+	}
+
+	// The resources matching a changed search are notified from its next evaluation
+	if row.Search != string(query) || row.WebhookURL != cmd.WebhookURL {
+		row.MatchedUIDs = ""
+		row.LastEvaluated = nil
+	}
+	row.Name = strings.TrimSpace(cmd.Name)
+	row.Search = string(query)
+	row.WebhookURL = cmd.WebhookURL
+	row.Updated = s.now()
+	if err := s.store.Update(ctx, row); err != nil {
+		return nil, err
+	}
+	return toDTO(row)
+}
+
+func (s *Service) DeleteSavedSearch(ctx context.Context, cmd *savedsearch.DeleteSavedSearchCommand) error {
+	ctx, span := s.tracer.Start(ctx, "savedsearch.DeleteSavedSearch")
+	defer span.End()
+
+	if _, err := s.get(ctx, cmd.OrgID, cmd.UID, cmd.UserID, cmd.UserTeams); err != nil {
+		return err
+	}
+	return s.store.Delete(ctx, cmd.OrgID, cmd.UID)
+}
+
+func (s *Service) GetSavedSearch(ctx context.Context, query *savedsearch.GetSavedSearchQuery) (*savedsearch.SavedSearch, error) {
+	ctx, span := s.tracer.Start(ctx, "savedsearch.GetSavedSearch")
+	defer span.End()
+
+	row, err := s.get(ctx, query.OrgID, query.UID, query.UserID, query.UserTeams)
+	if err != nil {
+		return nil, err
+	}
+	return toDTO(row)
+}
+
+func (s *Service) ListSavedSearches(ctx context.Context, query *savedsearch.ListSavedSearchesQuery) ([]*savedsearch.SavedSearch, error) {
+	ctx, span := s.tracer.Start(ctx, "savedsearch.ListSavedSearches")
+	defer span.End()
+
+	rows, err := s.store.List(ctx, query.OrgID, query.UserID, query.UserTeams)
+	if err != nil {
+		return nil, err
+	}
+
+	searches := make([]*savedsearch.SavedSearch, 0, len(rows))
+	for _, row := range rows {
+		dto, err := toDTO(row)
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, dto)
+	}
+	return searches, nil
+}
+
+// get returns the search when it can be accessed by the user, searches of other users and teams aren't found
+func (s *Service) get(ctx context.Context, orgID int64, uid string, userID int64, teams []int64) (*savedSearch, error) {
+	row, err := s.store.Get(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if !row.canAccess(userID, teams) {
+		return nil, savedsearch.ErrNotFound.Errorf("saved search %s not found", uid)
+	}
+	return row, nil
+}
+
+func (s *Service) validate(name, webhookURL string) error {
+	if strings.TrimSpace(name) == "" {
+		return savedsearch.ErrNameRequired.Errorf("saved search name is empty")
+	}
+	if webhookURL == "" {
+		return nil
+	}
+	if !s.cfg.Search.SavedSearchSubscriptionsEnabled {
+		return savedsearch.ErrSubscriptionsDisabled.Errorf("saved search subscriptions are disabled")
+	}
+
+	u, err := url.Parse(webhookURL)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && !(u.Scheme == "http" && s.cfg.Env == setting.Dev)) {
+		return savedsearch.ErrInvalidWebhookURL.Errorf("invalid webhook url %q", webhookURL)
+	}
+	return nil
+}
+
+// canAccess returns true when the search is owned by the user or by one of their teams
+func (row *savedSearch) canAccess(userID int64, teams []int64) bool {
+	if row.TeamID == 0 {
+		return row.UserID == userID
+	}
+	return isTeamMember(row.TeamID, teams)
+}
+
+func isTeamMember(teamID int64, teams []int64) bool {
+	for _, id := range teams {
+		if id == teamID {
+			return true
+		}
+	}
+	return false
+}
+
+func toDTO(row *savedSearch) (*savedsearch.SavedSearch, error) {
+	dto := &savedsearch.SavedSearch{
+		UID:       row.UID,
+		OrgID:     row.OrgID,
+		Name:      row.Name,
+		UserID:    row.UserID,
+		TeamID:    row.TeamID,
+		CreatedBy: row.CreatedBy,
+		Created:   row.Created,
+		Updated:   row.Updated,
+	}
+	if err := json.Unmarshal([]byte(row.Search), &dto.Search); err != nil {
+		return nil, err
+	}
+	if row.WebhookURL != "" {
+		dto.Subscription = &savedsearch.Subscription{
+			WebhookURL:    row.WebhookURL,
+			LastEvaluated: row.LastEvaluated,
+		}
+	}
+	return dto, nil
+}
+
+// Run evaluates the subscribed searches periodically, the evaluation runs on a single instance at a time
+func (s *Service) Run(ctx context.Context) error {
+	interval := s.cfg.Search.SavedSearchEvaluationInterval
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			err := s.serverLock.LockAndExecute(ctx, "evaluate saved search subscriptions", interval/2, func(ctx context.Context) {
+				s.evaluateSubscriptions(ctx)
+			})
+			if err != nil {
+				s.log.Error("Failed to lock and execute the evaluation of saved search subscriptions", "error", err)
+			}
+		}
+	}
+}
+
+func (s *Service) evaluateSubscriptions(ctx context.Context) {
+	ctx, span := s.tracer.Start(ctx, "savedsearch.evaluateSubscriptions")
+	defer span.End()
+
+	rows, err := s.store.ListSubscribed(ctx)
+	if err != nil {
+		s.log.Error("Failed to list subscribed saved searches", "error", err)
+		return
+	}
+
+	for _, row := range rows {
+		if err := s.evaluate(ctx, row); err != nil {
+			s.log.Warn("Failed to evaluate saved search subscription", "uid", row.UID, "orgId", row.OrgID, "error", err)
+		}
+	}
+}
+
+// evaluate notifies the resources matching the search which didn't match at the previous evaluation.
+// The first evaluation only records the matching resources. The search runs with the permissions of its creator.
+func (s *Service) evaluate(ctx context.Context, row *savedSearch) error {
+	dto, err := toDTO(row)
+	if err != nil {
+		return err
+	}
+
+	usr, err := s.signedInUser(ctx, row.OrgID, row.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	hits, err := s.searchService.SearchHandler(ctx, &search.Query{
+		Title:        dto.Search.Query,
+		Tags:         dto.Search.Tags,
+		Type:         dto.Search.Type,
+		FolderUIDs:   dto.Search.FolderUIDs,
+		IsStarred:    dto.Search.Starred,
+		Sort:         dto.Search.Sort,
+		OrgId:        row.OrgID,
+		SignedInUser: usr,
+		Limit:        maxEvaluatedHits,
+		Permission:   dashboardaccess.PERMISSION_VIEW,
+	})
+	if err != nil {
+		return err
+	}
+
+	previous := map[string]bool{}
+	if row.MatchedUIDs != "" {
+		var uids []string
+		if err := json.Unmarshal([]byte(row.MatchedUIDs), &uids); err != nil {
+			return err
+		}
+		for _, uid := range uids {
+			previous[uid] = true
+		}
+	}
+
+	uids := make([]string, 0, len(hits))
+	newItems := make([]model.Hit, 0)
+	for _, hit := range hits {
+		uids = append(uids, hit.UID)
+		if !previous[hit.UID] {
+			newItems = append(newItems, *hit)
+		}
+	}
+
+	if row.LastEvaluated != nil && len(newItems) > 0 {
+		// The matches aren't stored when the notification fails, so it is sent again at the next evaluation
+		err := s.notifier.Notify(ctx, row.WebhookURL, &savedsearch.Notification{
+			UID:      row.UID,
+			OrgID:    row.OrgID,
+			Name:     row.Name,
+			Search:   dto.Search,
+			NewItems: newItems,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	matched, err := json.Marshal(uids)
+	if err != nil {
+		return err
+	}
+	return s.store.SetEvaluation(ctx, row.ID, string(matched), s.now())
+}
+
+func (s *Service) signedInUser(ctx context.Context, orgID, userID int64) (*user.SignedInUser, error) {
+	usr, err := s.userService.GetSignedInUser(ctx, &user.GetSignedInUserQuery{OrgID: orgID, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	if usr.Permissions == nil {
+		usr.Permissions = make(map[int64]map[string][]string)
+	}
+	if _, ok := usr.Permissions[orgID]; !ok {
+		permissions, err := s.acService.GetUserPermissions(ctx, usr, ac.Options{})
+		if err != nil {
+			return nil, err
+		}
+		usr.Permissions[orgID] = ac.GroupScopesByActionContext(ctx, permissions)
+	}
+	return usr, nil
+}
@@ -0,0 +1,306 @@
+package savedsearchimpl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/savedsearch"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func TestService_CreateSavedSearch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should save a search owned by the user", func(t *testing.T) {
+		s, store, _, _ := setupTestService(t)
+
+		result, err := s.CreateSavedSearch(ctx, &savedsearch.CreateSavedSearchCommand{
+			OrgID: 1, UserID: 2, Name: " Production ", Search: savedsearch.SearchQuery{Tags: []string{"prod"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Production", result.Name)
+		assert.Equal(t, int64(2), result.UserID)
+		assert.Equal(t, []string{"prod"}, result.Search.Tags)
+		assert.Nil(t, result.Subscription)
+		assert.Len(t, store.searches, 1)
+	})
+
+	t.Run("should save a search owned by a team of the user", func(t *testing.T) {
+		s, _, _, _ := setupTestService(t)
+
+		result, err := s.CreateSavedSearch(ctx, &savedsearch.CreateSavedSearchCommand{
+			OrgID: 1, UserID: 2, UserTeams: []int64{3}, TeamID: 3, Name: "Team",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), result.UserID)
+		assert.Equal(t, int64(3), result.TeamID)
+		assert.Equal(t, int64(2), result.CreatedBy)
+	})
+
+	t.Run("should not save a search for a team the user isn't a member of", func(t *testing.T) {
+		s, _, _, _ := setupTestService(t)
+
+		_, err := s.CreateSavedSearch(ctx, &savedsearch.CreateSavedSearchCommand{
+			OrgID: 1, UserID: 2, UserTeams: []int64{4}, TeamID: 3, Name: "Team",
+		})
+		assert.ErrorIs(t, err, savedsearch.ErrNotTeamMember)
+	})
+
+	t.Run("should validate the name and the webhook url", func(t *testing.T) {
+		s, _, _, _ := setupTestService(t)
+
+		_, err := s.CreateSavedSearch(ctx, &savedsearch.CreateSavedSearchCommand{OrgID: 1, UserID: 2, Name: " "})
+		assert.ErrorIs(t, err, savedsearch.ErrNameRequired)
+
+		for _, webhookURL := range []string{"http://example.com/hook", "https://", "not a url"} {
+			_, err = s.CreateSavedSearch(ctx, &savedsearch.CreateSavedSearchCommand{OrgID: 1, UserID: 2, Name: "Hook", WebhookURL: webhookURL})
+			assert.ErrorIs(t, err, savedsearch.ErrInvalidWebhookURL, webhookURL)
+		}
+
+		s.cfg.Search.SavedSearchSubscriptionsEnabled = false
+		_, err = s.CreateSavedSearch(ctx, &savedsearch.CreateSavedSearchCommand{OrgID: 1, UserID: 2, Name: "Hook", WebhookURL: "https://example.com/hook"})
+		assert.ErrorIs(t, err, savedsearch.ErrSubscriptionsDisabled)
+	})
+}
+
+func TestService_access(t *testing.T) {
+	ctx := context.Background()
+	s, _, _, _ := setupTestService(t)
+
+	own, err := s.CreateSavedSearch(ctx, &savedsearch.CreateSavedSearchCommand{OrgID: 1, UserID: 2, Name: "Own"})
+	require.NoError(t, err)
+	team, err := s.CreateSavedSearch(ctx, &savedsearch.CreateSavedSearchCommand{OrgID: 1, UserID: 2, UserTeams: []int64{3}, TeamID: 3, Name: "Team"})
+	require.NoError(t, err)
+
+	t.Run("should list the searches of the user and of their teams", func(t *testing.T) {
+		searches, err := s.ListSavedSearches(ctx, &savedsearch.ListSavedSearchesQuery{OrgID: 1, UserID: 2, UserTeams: []int64{3}})
+		require.NoError(t, err)
+		assert.Len(t, searches, 2)
+
+		searches, err = s.ListSavedSearches(ctx, &savedsearch.ListSavedSearchesQuery{OrgID: 1, UserID: 5, UserTeams: []int64{3}})
+		require.NoError(t, err)
+		require.Len(t, searches, 1)
+		assert.Equal(t, team.UID, searches[0].UID)
+	})
+
+	t.Run("should not find the searches of other users and teams", func(t *testing.T) {
+		_, err := s.GetSavedSearch(ctx, &savedsearch.GetSavedSearchQuery{OrgID: 1, UID: own.UID, UserID: 5, UserTeams: []int64{3}})
+		assert.ErrorIs(t, err, savedsearch.ErrNotFound)
+
+		err = s.DeleteSavedSearch(ctx, &savedsearch.DeleteSavedSearchCommand{OrgID: 1, UID: team.UID, UserID: 5})
+		assert.ErrorIs(t, err, savedsearch.ErrNotFound)
+	})
+
+	t.Run("should let team members update the searches of the team", func(t *testing.T) {
+		result, err := s.UpdateSavedSearch(ctx, &savedsearch.UpdateSavedSearchCommand{
+			OrgID: 1, UID: team.UID, UserID: 5, UserTeams: []int64{3}, Name: "Renamed",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", result.Name)
+	})
+}
+
+func TestService_evaluate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should notify the resources starting to match the search", func(t *testing.T) {
+		s, store, searchService, notifier := setupTestService(t)
+		_, err := s.CreateSavedSearch(ctx, &savedsearch.CreateSavedSearchCommand{
+			OrgID: 1, UserID: 2, Name: "Hook", Search: savedsearch.SearchQuery{Query: "prod"}, WebhookURL: "https://example.com/hook",
+		})
+		require.NoError(t, err)
+
+		searchService.hits = model.HitList{{UID: "a"}, {UID: "b"}}
+		s.evaluateSubscriptions(ctx)
+		assert.Empty(t, notifier.notifications, "first evaluation only records the matches")
+		assert.Equal(t, "prod", searchService.query.Title)
+		assert.Equal(t, int64(2), searchService.query.SignedInUser.UserID)
+
+		searchService.hits = model.HitList{{UID: "b"}, {UID: "c"}}
+		s.evaluateSubscriptions(ctx)
+		require.Len(t, notifier.notifications, 1)
+		assert.Equal(t, "https://example.com/hook", notifier.urls[0])
+		assert.Equal(t, []model.Hit{{UID: "c"}}, notifier.notifications[0].NewItems)
+
+		s.evaluateSubscriptions(ctx)
+		assert.Len(t, notifier.notifications, 1, "matches are only notified once")
+		assert.Equal(t, `["b","c"]`, store.searches[0].MatchedUIDs)
+	})
+
+	t.Run("should notify again when the notification fails", func(t *testing.T) {
+		s, store, searchService, notifier := setupTestService(t)
+		_, err := s.CreateSavedSearch(ctx, &savedsearch.CreateSavedSearchCommand{
+			OrgID: 1, UserID: 2, Name: "Hook", WebhookURL: "https://example.com/hook",
+		})
+		require.NoError(t, err)
+		s.evaluateSubscriptions(ctx)
+
+		searchService.hits = model.HitList{{UID: "a"}}
+		notifier.err = errors.New("unavailable")
+		require.Error(t, s.evaluate(ctx, store.searches[0]))
+		assert.Equal(t, "[]", store.searches[0].MatchedUIDs)
+
+		notifier.err = nil
+		require.NoError(t, s.evaluate(ctx, store.searches[0]))
+		assert.Len(t, notifier.notifications, 1)
+		assert.Equal(t, `["a"]`, store.searches[0].MatchedUIDs)
+	})
+
+	t.Run("should restart the subscription when the search changes", func(t *testing.T) {
+		s, store, searchService, notifier := setupTestService(t)
+		created, err := s.CreateSavedSearch(ctx, &savedsearch.CreateSavedSearchCommand{
+			OrgID: 1, UserID: 2, Name: "Hook", WebhookURL: "https://example.com/hook",
+		})
+		require.NoError(t, err)
+		s.evaluateSubscriptions(ctx)
+		require.NotNil(t, store.searches[0].LastEvaluated)
+
+		_, err = s.UpdateSavedSearch(ctx, &savedsearch.UpdateSavedSearchCommand{
+			OrgID: 1, UID: created.UID, UserID: 2, Name: "Hook", Search: savedsearch.SearchQuery{Query: "new"}, WebhookURL: "https://example.com/hook",
+		})
+		require.NoError(t, err)
+		assert.Nil(t, store.searches[0].LastEvaluated)
+
+		searchService.hits = model.HitList{{UID: "a"}}
+		s.evaluateSubscriptions(ctx)
+		assert.Empty(t, notifier.notifications)
+	})
+}
+
+func setupTestService(t *testing.T) (*Service, *fakeStore, *fakeSearchService, *fakeNotifier) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.Env = setting.Prod
+	cfg.Search.SavedSearchSubscriptionsEnabled = true
+	cfg.Search.SavedSearchEvaluationInterval = time.Minute
+
+	store := &fakeStore{}
+	searchService := &fakeSearchService{}
+	notifier := &fakeNotifier{}
+	userService := usertest.NewUserServiceFake()
+	userService.GetSignedInUserFn = func(ctx context.Context, query *user.GetSignedInUserQuery) (*user.SignedInUser, error) {
+		return &user.SignedInUser{UserID: query.UserID, OrgID: query.OrgID}, nil
+	}
+
+	return &Service{
+		cfg:           cfg,
+		store:         store,
+		searchService: searchService,
+		userService:   userService,
+		acService:     actest.FakeService{},
+		notifier:      notifier,
+		log:           log.NewNopLogger(),
+		tracer:        tracing.InitializeTracerForTest(),
+		now:           func() time.Time { return now },
+	}, store, searchService, notifier
+}
+
+type fakeStore struct {
+	searches []*savedSearch
+}
+
+func (f *fakeStore) Insert(_ context.Context, search *savedSearch) error {
+	search.ID = int64(len(f.searches) + 1)
+	f.searches = append(f.searches, search)
+	return nil
+}
+
+func (f *fakeStore) Update(_ context.Context, search *savedSearch) error {
+	for i, s := range f.searches {
+		if s.ID == search.ID {
+			f.searches[i] = search
+		}
+	}
+	return nil
+}
+
+func (f *fakeStore) Delete(_ context.Context, orgID int64, uid string) error {
+	searches := f.searches[:0]
+	for _, s := range f.searches {
+		if s.OrgID != orgID || s.UID != uid {
+			searches = append(searches, s)
+		}
+	}
+	f.searches = searches
+	return nil
+}
+
+func (f *fakeStore) Get(_ context.Context, orgID int64, uid string) (*savedSearch, error) {
+	for _, s := range f.searches {
+		if s.OrgID == orgID && s.UID == uid {
+			return s, nil
+		}
+	}
+	return nil, savedsearch.ErrNotFound.Errorf("saved search %s not found", uid)
+}
+
+func (f *fakeStore) List(_ context.Context, orgID, userID int64, teamIDs []int64) ([]*savedSearch, error) {
+	var searches []*savedSearch
+	for _, s := range f.searches {
+		if s.OrgID == orgID && s.canAccess(userID, teamIDs) {
+			searches = append(searches, s)
+		}
+	}
+	return searches, nil
+}
+
+func (f *fakeStore) ListSubscribed(_ context.Context) ([]*savedSearch, error) {
+	var searches []*savedSearch
+	for _, s := range f.searches {
+		if s.WebhookURL != "" {
+			searches = append(searches, s)
+		}
+	}
+	return searches, nil
+}
+
+func (f *fakeStore) SetEvaluation(_ context.Context, id int64, matchedUIDs string, evaluated time.Time) error {
+	for _, s := range f.searches {
+		if s.ID == id {
+			s.MatchedUIDs = matchedUIDs
+			s.LastEvaluated = &evaluated
+		}
+	}
+	return nil
+}
+
+type fakeSearchService struct {
+	search.Service
+	query *search.Query
+	hits  model.HitList
+}
+
+func (f *fakeSearchService) SearchHandler(_ context.Context, query *search.Query) (model.HitList, error) {
+	f.query = query
+	return f.hits, nil
+}
+
+type fakeNotifier struct {
+	err           error
+	urls          []string
+	notifications []*savedsearch.Notification
+}
+
+func (f *fakeNotifier) Notify(_ context.Context, url string, n *savedsearch.Notification) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.urls = append(f.urls, url)
+	f.notifications = append(f.notifications, n)
+	return nil
+}
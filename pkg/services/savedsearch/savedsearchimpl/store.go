@@ -0,0 +1,119 @@
+package savedsearchimpl
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/savedsearch"
+)
+
+type savedSearch struct {
+	ID         int64  `xorm:"pk autoincr 'id'"`
+	UID        string `xorm:"uid"`
+	OrgID      int64  `xorm:"org_id"`
+	UserID     int64  `xorm:"user_id"`
+	TeamID     int64  `xorm:"team_id"`
+	Name       string `xorm:"name"`
+	Search     string `xorm:"search"`
+	WebhookURL string `xorm:"webhook_url"`
+	// MatchedUIDs is the JSON list of the resources matching the search at the last evaluation
+	MatchedUIDs   string     `xorm:"matched_uids"`
+	LastEvaluated *time.Time `xorm:"last_evaluated"`
+	CreatedBy     int64      `xorm:"created_by"`
+	Created       time.Time  `xorm:"created"`
+	Updated       time.Time  `xorm:"updated"`
+}
+
+func (savedSearch) TableName() string {
+	return "saved_search"
+}
+
+type store interface {
+	Insert(ctx context.Context, search *savedSearch) error
+	// Update updates the search, and resets its evaluation
+	Update(ctx context.Context, search *savedSearch) error
+	Delete(ctx context.Context, orgID int64, uid string) error
+	Get(ctx context.Context, orgID int64, uid string) (*savedSearch, error)
+	// List returns the searches owned by the user or by one of the teams, ordered by name
+	List(ctx context.Context, orgID, userID int64, teamIDs []int64) ([]*savedSearch, error)
+	// ListSubscribed returns the searches of all organizations with a subscription
+	ListSubscribed(ctx context.Context) ([]*savedSearch, error)
+	// SetEvaluation stores the resources matching the search at the time of the evaluation
+	SetEvaluation(ctx context.Context, id int64, matchedUIDs string, evaluated time.Time) error
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (s *xormStore) Insert(ctx context.Context, search *savedSearch) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(search)
+		return err
+	})
+}
+
+func (s *xormStore) Update(ctx context.Context, search *savedSearch) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(search.ID).Cols("name", "search", "webhook_url", "matched_uids", "last_evaluated", "updated").Update(search)
+		return err
+	})
+}
+
+func (s *xormStore) Delete(ctx context.Context, orgID int64, uid string) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM saved_search WHERE org_id = ? AND uid = ?", orgID, uid)
+		return err
+	})
+}
+
+func (s *xormStore) Get(ctx context.Context, orgID int64, uid string) (*savedSearch, error) {
+	var search savedSearch
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&search)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return savedsearch.ErrNotFound.Errorf("saved search %s not found", uid)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &search, nil
+}
+
+func (s *xormStore) List(ctx context.Context, orgID, userID int64, teamIDs []int64) ([]*savedSearch, error) {
+	searches := make([]*savedSearch, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		filter := "(team_id = 0 AND user_id = ?)"
+		params := []any{orgID, userID}
+		if len(teamIDs) > 0 {
+			filter += " OR team_id IN (?" + strings.Repeat(",?", len(teamIDs)-1) + ")"
+			for _, teamID := range teamIDs {
+				params = append(params, teamID)
+			}
+		}
+		return sess.Where("org_id = ? AND ("+filter+")", params...).Asc("name").Find(&searches)
+	})
+	return searches, err
+}
+
+func (s *xormStore) ListSubscribed(ctx context.Context) ([]*savedSearch, error) {
+	searches := make([]*savedSearch, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("webhook_url <> ''").Asc("id").Find(&searches)
+	})
+	return searches, err
+}
+
+func (s *xormStore) SetEvaluation(ctx context.Context, id int64, matchedUIDs string, evaluated time.Time) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE saved_search SET matched_uids = ?, last_evaluated = ? WHERE id = ?", matchedUIDs, evaluated, id)
+		return err
+	})
+}
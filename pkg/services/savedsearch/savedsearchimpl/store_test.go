@@ -0,0 +1,106 @@
+package savedsearchimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/savedsearch"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	store := &xormStore{db: db.InitTestDB(t)}
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	searches := []*savedSearch{
+		{UID: "mine", OrgID: 1, UserID: 2, Name: "b-mine", Search: "query=cpu", Created: now, Updated: now},
+		{UID: "team", OrgID: 1, UserID: 3, TeamID: 5, Name: "a-team", Search: "tag=prod", WebhookURL: "https://example.com/hook", Created: now, Updated: now},
+		{UID: "other", OrgID: 1, UserID: 3, Name: "c-other", Search: "query=mem", Created: now, Updated: now},
+		{UID: "elsewhere", OrgID: 2, UserID: 2, Name: "d-elsewhere", Search: "query=cpu", WebhookURL: "https://example.org/hook", Created: now, Updated: now},
+	}
+	for _, search := range searches {
+		require.NoError(t, store.Insert(ctx, search))
+		assert.NotZero(t, search.ID)
+	}
+
+	t.Run("should get a search of the org", func(t *testing.T) {
+		search, err := store.Get(ctx, 1, "mine")
+		require.NoError(t, err)
+		assert.Equal(t, "query=cpu", search.Search)
+
+		_, err = store.Get(ctx, 2, "mine")
+		assert.ErrorIs(t, err, savedsearch.ErrNotFound)
+	})
+
+	t.Run("should list the searches of the user and of their teams by name", func(t *testing.T) {
+		list, err := store.List(ctx, 1, 2, []int64{5})
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		assert.Equal(t, "team", list[0].UID)
+		assert.Equal(t, "mine", list[1].UID)
+
+		list, err = store.List(ctx, 1, 2, nil)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, "mine", list[0].UID)
+
+		list, err = store.List(ctx, 1, 3, nil)
+		require.NoError(t, err)
+		require.Len(t, list, 1, "the team searches are only listed for the members of the team")
+		assert.Equal(t, "other", list[0].UID)
+	})
+
+	t.Run("should list the subscribed searches of every org", func(t *testing.T) {
+		list, err := store.ListSubscribed(ctx)
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		assert.Equal(t, "team", list[0].UID)
+		assert.Equal(t, "elsewhere", list[1].UID)
+	})
+
+	t.Run("should set the evaluation and reset it on update", func(t *testing.T) {
+		team := searches[1]
+		require.NoError(t, store.SetEvaluation(ctx, team.ID, `["abc"]`, now))
+
+		search, err := store.Get(ctx, 1, "team")
+		require.NoError(t, err)
+		assert.Equal(t, `["abc"]`, search.MatchedUIDs)
+		require.NotNil(t, search.LastEvaluated)
+		assert.True(t, now.Equal(*search.LastEvaluated))
+
+		search.Search = "tag=staging"
+		search.MatchedUIDs = ""
+		search.LastEvaluated = nil
+		search.Updated = now.Add(time.Hour)
+		require.NoError(t, store.Update(ctx, search))
+
+		search, err = store.Get(ctx, 1, "team")
+		require.NoError(t, err)
+		assert.Equal(t, "tag=staging", search.Search)
+		assert.Empty(t, search.MatchedUIDs)
+		assert.Nil(t, search.LastEvaluated)
+	})
+
+	t.Run("should delete a search of the org", func(t *testing.T) {
+		require.NoError(t, store.Delete(ctx, 2, "mine"))
+		_, err := store.Get(ctx, 1, "mine")
+		require.NoError(t, err, "the searches of the other orgs are kept")
+
+		require.NoError(t, store.Delete(ctx, 1, "mine"))
+		_, err = store.Get(ctx, 1, "mine")
+		assert.ErrorIs(t, err, savedsearch.ErrNotFound)
+	})
+}
@@ -0,0 +1,78 @@
+package savedsearchimpl
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/savedsearch"
+)
+
+var errInvalidWebhookStatus = errors.New("invalid saved search webhook status code")
+
+type notifier interface {
+	Notify(ctx context.Context, url string, n *savedsearch.Notification) error
+}
+
+type webhookNotifier struct {
+	httpClient *http.Client
+	version    string
+}
+
+func newWebhookNotifier(version string) *webhookNotifier {
+	return &webhookNotifier{
+		version: version,
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					Renegotiation: tls.RenegotiateFreelyAsClient,
+				},
+				Proxy: http.ProxyFromEnvironment,
+				DialContext: (&net.Dialer{
+					Timeout:   4 * time.Second,
+					KeepAlive: 15 * time.Second,
+				}).DialContext,
+				TLSHandshakeTimeout:   10 * time.Second,
+				ExpectContinueTimeout: 1 * time.Second,
+				MaxIdleConns:          100,
+				IdleConnTimeout:       30 * time.Second,
+			},
+			Timeout: time.Second * 30,
+		},
+	}
+}
+
+func (c *webhookNotifier) Notify(ctx context.Context, url string, n *savedsearch.Notification) error {
+	jsonValue, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("%s: %w", "failed to marshal webhook request", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonValue))
+	if err != nil {
+		return fmt.Errorf("%s: %w", "failed to make http request", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "grafana-saved-search-webhook-client/"+c.version)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", "failed to webhook request", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w. status code %s", errInvalidWebhookStatus, resp.Status)
+	}
+
+	return nil
+}
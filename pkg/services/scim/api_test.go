@@ -0,0 +1,375 @@
+package scim
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/auth/authtest"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/login/authinfotest"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamtest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+var (
+	orgUsersWrite  = accesscontrol.Permission{Action: accesscontrol.ActionOrgUsersWrite, Scope: accesscontrol.ScopeUsersAll}
+	orgUsersRemove = accesscontrol.Permission{Action: accesscontrol.ActionOrgUsersRemove, Scope: accesscontrol.ScopeUsersAll}
+	teamsWrite     = []accesscontrol.Permission{
+		{Action: accesscontrol.ActionTeamsWrite, Scope: accesscontrol.ScopeTeamsAll},
+		{Action: accesscontrol.ActionTeamsPermissionsWrite, Scope: accesscontrol.ScopeTeamsAll},
+	}
+)
+
+func TestAPI_UpdateUser(t *testing.T) {
+	rename := `{"Operations":[{"op":"replace","path":"displayName","value":"Johnny"}]}`
+	deactivate := `{"Operations":[{"op":"replace","path":"active","value":false}]}`
+
+	for _, tc := range []struct {
+		desc        string
+		target      user.User
+		orgs        []int64
+		permissions []accesscontrol.Permission
+		body        string
+		dryRun      bool
+		status      int
+		updated     bool
+		revoked     bool
+	}{
+		{
+			desc:        "should update a user of the org alone with the org permission",
+			target:      user.User{ID: 2, UID: "u2", Login: "jdoe", Name: "John Doe", IsProvisioned: true},
+			orgs:        []int64{1},
+			permissions: []accesscontrol.Permission{orgUsersWrite},
+			body:        rename,
+			status:      http.StatusOK,
+			updated:     true,
+		},
+		{
+			desc:        "should not rename a user of another org with the org permission",
+			target:      user.User{ID: 2, UID: "u2", Login: "jdoe", Name: "John Doe", IsProvisioned: true},
+			orgs:        []int64{1, 2},
+			permissions: []accesscontrol.Permission{orgUsersWrite},
+			body:        rename,
+			status:      http.StatusForbidden,
+		},
+		{
+			desc:        "should not rename a Grafana admin with the org permission",
+			target:      user.User{ID: 2, UID: "u2", Login: "admin", Name: "Admin", IsAdmin: true, IsProvisioned: true},
+			orgs:        []int64{1},
+			permissions: []accesscontrol.Permission{orgUsersWrite},
+			body:        rename,
+			status:      http.StatusForbidden,
+		},
+		{
+			desc:        "should not take over a user that wasn't provisioned with the org permission",
+			target:      user.User{ID: 2, UID: "u2", Login: "jdoe", Name: "John Doe"},
+			orgs:        []int64{1, 2},
+			permissions: []accesscontrol.Permission{orgUsersWrite},
+			body:        `{"Operations":[]}`,
+			status:      http.StatusForbidden,
+		},
+		{
+			desc:   "should rename a user of another org with the global permission",
+			target: user.User{ID: 2, UID: "u2", Login: "jdoe", Name: "John Doe", IsProvisioned: true},
+			orgs:   []int64{1, 2},
+			permissions: []accesscontrol.Permission{orgUsersWrite,
+				{Action: accesscontrol.ActionUsersWrite, Scope: accesscontrol.ScopeGlobalUsersAll}},
+			body:    rename,
+			status:  http.StatusOK,
+			updated: true,
+		},
+		{
+			desc:   "should not deactivate a user of another org with users:write only",
+			target: user.User{ID: 2, UID: "u2", Login: "jdoe", Name: "John Doe", IsProvisioned: true},
+			orgs:   []int64{1, 2},
+			permissions: []accesscontrol.Permission{orgUsersWrite,
+				{Action: accesscontrol.ActionUsersWrite, Scope: accesscontrol.ScopeGlobalUsersAll}},
+			body:   deactivate,
+			status: http.StatusForbidden,
+		},
+		{
+			desc:   "should deactivate a user of another org and revoke its sessions with the global permission",
+			target: user.User{ID: 2, UID: "u2", Login: "jdoe", Name: "John Doe", IsProvisioned: true},
+			orgs:   []int64{1, 2},
+			permissions: []accesscontrol.Permission{orgUsersWrite,
+				{Action: accesscontrol.ActionUsersDisable, Scope: accesscontrol.ScopeGlobalUsersAll}},
+			body:    deactivate,
+			status:  http.StatusOK,
+			updated: true,
+			revoked: true,
+		},
+		{
+			desc:        "should not update the user in dry run",
+			target:      user.User{ID: 2, UID: "u2", Login: "jdoe", Name: "John Doe", IsProvisioned: true},
+			orgs:        []int64{1},
+			permissions: []accesscontrol.Permission{orgUsersWrite},
+			body:        deactivate,
+			dryRun:      true,
+			status:      http.StatusOK,
+		},
+		{
+			desc:   "should require the org permission",
+			target: user.User{ID: 2, UID: "u2", Login: "jdoe", Name: "John Doe", IsProvisioned: true},
+			orgs:   []int64{1},
+			permissions: []accesscontrol.Permission{
+				{Action: accesscontrol.ActionUsersWrite, Scope: accesscontrol.ScopeGlobalUsersAll}},
+			body:   rename,
+			status: http.StatusForbidden,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			target := tc.target
+			env := setupTestEnv(t, &target, tc.orgs, tc.dryRun)
+
+			req := webtest.RequestWithSignedInUser(
+				env.server.NewRequest(http.MethodPatch, "/scim/v2/Users/u2", strings.NewReader(tc.body)),
+				authedUserWithPermissions(tc.permissions),
+			)
+			res, err := env.server.SendJSON(req)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			assert.Equal(t, tc.status, res.StatusCode)
+			assert.Equal(t, tc.updated, env.updated != nil)
+			assert.Equal(t, tc.revoked, env.revoked)
+		})
+	}
+}
+
+func TestAPI_DeleteUser(t *testing.T) {
+	for _, tc := range []struct {
+		desc        string
+		orgs        []int64
+		permissions []accesscontrol.Permission
+		dryRun      bool
+		removed     *org.RemoveOrgUserCommand
+		revoked     bool
+	}{
+		{
+			desc:        "should deprovision a user of the org alone",
+			orgs:        []int64{1},
+			permissions: []accesscontrol.Permission{orgUsersRemove},
+			removed:     &org.RemoveOrgUserCommand{UserID: 2, OrgID: 1, ShouldDeleteOrphanedUser: true},
+			revoked:     true,
+		},
+		{
+			desc:        "should only remove the membership of a user of another org",
+			orgs:        []int64{1, 2},
+			permissions: []accesscontrol.Permission{orgUsersRemove},
+			removed:     &org.RemoveOrgUserCommand{UserID: 2, OrgID: 1},
+		},
+		{
+			desc: "should deprovision a user of another org with the global permission",
+			orgs: []int64{1, 2},
+			permissions: []accesscontrol.Permission{orgUsersRemove,
+				{Action: accesscontrol.ActionUsersDelete, Scope: accesscontrol.ScopeGlobalUsersAll}},
+			removed: &org.RemoveOrgUserCommand{UserID: 2, OrgID: 1, ShouldDeleteOrphanedUser: true},
+			revoked: true,
+		},
+		{
+			desc:        "should not remove the user in dry run",
+			orgs:        []int64{1},
+			permissions: []accesscontrol.Permission{orgUsersRemove},
+			dryRun:      true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			env := setupTestEnv(t, &user.User{ID: 2, UID: "u2", Login: "jdoe", IsProvisioned: true}, tc.orgs, tc.dryRun)
+
+			req := webtest.RequestWithSignedInUser(
+				env.server.NewRequest(http.MethodDelete, "/scim/v2/Users/u2", nil),
+				authedUserWithPermissions(tc.permissions),
+			)
+			res, err := env.server.Send(req)
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			assert.Equal(t, http.StatusNoContent, res.StatusCode)
+			assert.Equal(t, tc.removed, env.orgService.removed)
+			assert.Equal(t, tc.revoked, env.revoked)
+		})
+	}
+}
+
+func TestAPI_PatchGroup(t *testing.T) {
+	addMember := `{"Operations":[{"op":"add","path":"members","value":[{"value":"u2"}]}]}`
+
+	t.Run("should add a member of the org", func(t *testing.T) {
+		env := setupTestEnv(t, &user.User{ID: 2, UID: "u2", Login: "jdoe"}, []int64{1}, false)
+
+		res := env.patchGroup(t, addMember)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, map[int64]string{2: team.PermissionTypeMember.String()}, env.teamPermissions.set)
+	})
+
+	t.Run("should reject a user that isn't a member of the org", func(t *testing.T) {
+		env := setupTestEnv(t, &user.User{ID: 2, UID: "u2", Login: "jdoe"}, []int64{2}, false)
+
+		res := env.patchGroup(t, addMember)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Empty(t, env.teamPermissions.set)
+	})
+
+	t.Run("should reject a service account", func(t *testing.T) {
+		env := setupTestEnv(t, &user.User{ID: 2, UID: "u2", Login: "sa-1", IsServiceAccount: true}, []int64{1}, false)
+
+		res := env.patchGroup(t, addMember)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Empty(t, env.teamPermissions.set)
+	})
+
+	t.Run("should remove a member", func(t *testing.T) {
+		env := setupTestEnv(t, &user.User{ID: 2, UID: "u2", Login: "jdoe"}, []int64{1}, false)
+		env.teamService.ExpectedMembers = []*team.TeamMemberDTO{{UserID: 2, UserUID: "u2"}}
+
+		res := env.patchGroup(t, `{"Operations":[{"op":"remove","path":"members[value eq \"u2\"]"}]}`)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, map[int64]string{2: ""}, env.teamPermissions.set)
+	})
+
+	t.Run("should not change the members in dry run", func(t *testing.T) {
+		env := setupTestEnv(t, &user.User{ID: 2, UID: "u2", Login: "jdoe"}, []int64{1}, true)
+
+		res := env.patchGroup(t, addMember)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Empty(t, env.teamPermissions.set)
+	})
+}
+
+type testEnv struct {
+	server          *webtest.Server
+	orgService      *fakeOrgService
+	teamService     *teamtest.FakeService
+	teamPermissions *fakeTeamPermissionsService
+	updated         *user.UpdateUserCommand
+	revoked         bool
+}
+
+// setupTestEnv serves the SCIM API of org 1, where target is the only user
+// and belongs to the given orgs
+func setupTestEnv(t *testing.T, target *user.User, orgs []int64, dryRun bool) *testEnv {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	scimCfg := ReadConfig(cfg)
+	scimCfg.UserSyncEnabled = true
+	scimCfg.GroupSyncEnabled = true
+	scimCfg.DryRun = dryRun
+
+	env := &testEnv{
+		orgService:      &fakeOrgService{FakeOrgService: orgtest.NewOrgServiceFake(), userID: target.ID, orgs: orgs},
+		teamService:     &teamtest.FakeService{ExpectedTeamDTO: &team.TeamDTO{ID: 1, UID: "t1", OrgID: 1, Name: "Team"}},
+		teamPermissions: &fakeTeamPermissionsService{set: map[int64]string{}},
+	}
+
+	userService := usertest.NewUserServiceFake()
+	userService.ExpectedUser = target
+	userService.UpdateFn = func(_ context.Context, cmd *user.UpdateUserCommand) error {
+		env.updated = cmd
+		return nil
+	}
+
+	userTokenService := authtest.NewFakeUserAuthTokenService()
+	userTokenService.RevokeAllUserTokensProvider = func(_ context.Context, userID int64) error {
+		env.revoked = true
+		return nil
+	}
+
+	api := &API{
+		cfg:                    cfg,
+		scimCfg:                scimCfg,
+		accessControl:          acimpl.ProvideAccessControl(featuremgmt.WithFeatures()),
+		acService:              actest.FakeService{},
+		userService:            userService,
+		orgService:             env.orgService,
+		teamService:            env.teamService,
+		teamPermissionsService: env.teamPermissions,
+		authInfoService:        &authinfotest.FakeService{ExpectedUserAuth: &login.UserAuth{UserId: target.ID, ExternalUID: "ext-2"}},
+		userTokenService:       userTokenService,
+		log:                    log.NewNopLogger(),
+	}
+
+	router := routing.NewRouteRegister()
+	api.registerRoutes(router)
+	env.server = webtest.NewServer(t, router)
+	return env
+}
+
+func (env *testEnv) patchGroup(t *testing.T, body string) *http.Response {
+	t.Helper()
+
+	req := webtest.RequestWithSignedInUser(
+		env.server.NewRequest(http.MethodPatch, "/scim/v2/Groups/t1", strings.NewReader(body)),
+		authedUserWithPermissions(teamsWrite),
+	)
+	res, err := env.server.SendJSON(req)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	return res
+}
+
+func authedUserWithPermissions(permissions []accesscontrol.Permission) *user.SignedInUser {
+	return &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleViewer, Permissions: map[int64]map[string][]string{
+		1: accesscontrol.GroupScopesByActionContext(context.Background(), permissions),
+	}}
+}
+
+// fakeOrgService knows the orgs of a single user
+type fakeOrgService struct {
+	*orgtest.FakeOrgService
+	userID  int64
+	orgs    []int64
+	removed *org.RemoveOrgUserCommand
+}
+
+func (f *fakeOrgService) GetUserOrgList(_ context.Context, query *org.GetUserOrgListQuery) ([]*org.UserOrgDTO, error) {
+	result := []*org.UserOrgDTO{}
+	if query.UserID == f.userID {
+		for _, id := range f.orgs {
+			result = append(result, &org.UserOrgDTO{OrgID: id})
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeOrgService) SearchOrgUsers(_ context.Context, query *org.SearchOrgUsersQuery) (*org.SearchOrgUsersQueryResult, error) {
+	result := &org.SearchOrgUsersQueryResult{OrgUsers: []*org.OrgUserDTO{}}
+	for _, id := range f.orgs {
+		if id == query.OrgID && (query.UserID == 0 || query.UserID == f.userID) {
+			result.OrgUsers = append(result.OrgUsers, &org.OrgUserDTO{OrgID: id, UserID: f.userID})
+		}
+	}
+	result.TotalCount = int64(len(result.OrgUsers))
+	return result, nil
+}
+
+func (f *fakeOrgService) RemoveOrgUser(_ context.Context, cmd *org.RemoveOrgUserCommand) error {
+	f.removed = cmd
+	return nil
+}
+
+// fakeTeamPermissionsService records the permissions set for each user
+type fakeTeamPermissionsService struct {
+	actest.FakePermissionsService
+	set map[int64]string
+}
+
+func (f *fakeTeamPermissionsService) SetUserPermission(_ context.Context, _ int64, user accesscontrol.User, _, permission string) (*accesscontrol.ResourcePermission, error) {
+	f.set[user.ID] = permission
+	return &accesscontrol.ResourcePermission{}, nil
+}
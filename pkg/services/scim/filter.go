@@ -0,0 +1,64 @@
+package scim
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var errUnsupportedFilter = errors.New("only filters of the form 'attribute eq \"value\"' are supported")
+
+// Filter is an equality filter, which is the only kind identity providers
+// send when looking up existing users and groups before provisioning them.
+type Filter struct {
+	Attribute string
+	Value     string
+}
+
+// ParseFilter parses an expression such as `userName eq "john"`.
+// Attribute names are case-insensitive, so they are lower-cased.
+func ParseFilter(expr string) (*Filter, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, nil
+	}
+
+	parts := strings.SplitN(expr, " ", 3)
+	if len(parts) != 3 || !strings.EqualFold(parts[1], "eq") {
+		return nil, errUnsupportedFilter
+	}
+
+	value := strings.TrimSpace(parts[2])
+	if strings.HasPrefix(value, `"`) {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return nil, errUnsupportedFilter
+		}
+		value = unquoted
+	}
+
+	return &Filter{Attribute: strings.ToLower(parts[0]), Value: value}, nil
+}
+
+// parsePath splits a PATCH path such as `members[value eq "2819c223"]` into
+// its attribute and an optional value filter.
+func parsePath(path string) (string, *Filter, error) {
+	path = strings.TrimSpace(path)
+	start := strings.Index(path, "[")
+	if start == -1 {
+		return strings.ToLower(path), nil, nil
+	}
+
+	end := strings.LastIndex(path, "]")
+	if end < start {
+		return "", nil, errUnsupportedFilter
+	}
+
+	filter, err := ParseFilter(path[start+1 : end])
+	if err != nil {
+		return "", nil, err
+	}
+
+	// keep a trailing sub-attribute, e.g. emails[type eq "work"].value
+	return strings.ToLower(path[:start] + path[end+1:]), filter, nil
+}
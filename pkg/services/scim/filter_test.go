@@ -0,0 +1,55 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expr     string
+		expected *Filter
+		wantErr  bool
+	}{
+		{name: "empty filter", expr: "", expected: nil},
+		{name: "quoted value", expr: `userName eq "john@example.com"`, expected: &Filter{Attribute: "username", Value: "john@example.com"}},
+		{name: "value with spaces", expr: `displayName eq "Site Reliability"`, expected: &Filter{Attribute: "displayname", Value: "Site Reliability"}},
+		{name: "operator is case-insensitive", expr: `userName EQ "john"`, expected: &Filter{Attribute: "username", Value: "john"}},
+		{name: "unquoted value", expr: `active eq true`, expected: &Filter{Attribute: "active", Value: "true"}},
+		{name: "unsupported operator", expr: `userName co "john"`, wantErr: true},
+		{name: "compound filter", expr: `userName eq "john" and active eq true`, wantErr: true},
+		{name: "missing value", expr: `userName eq`, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := ParseFilter(tc.expr)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, filter)
+		})
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	attr, filter, err := parsePath(`members[value eq "2819c223"]`)
+	require.NoError(t, err)
+	assert.Equal(t, "members", attr)
+	assert.Equal(t, &Filter{Attribute: "value", Value: "2819c223"}, filter)
+
+	attr, filter, err = parsePath(`emails[type eq "work"].value`)
+	require.NoError(t, err)
+	assert.Equal(t, "emails.value", attr)
+	assert.Equal(t, &Filter{Attribute: "type", Value: "work"}, filter)
+
+	attr, filter, err = parsePath("name.givenName")
+	require.NoError(t, err)
+	assert.Equal(t, "name.givenname", attr)
+	assert.Nil(t, filter)
+}
@@ -0,0 +1,348 @@
+package scim
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+var errUnknownMember = errors.New("unknown member")
+
+func (api *API) listGroups(c *contextmodel.ReqContext) response.Response {
+	filter, err := ParseFilter(c.Query("filter"))
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, ErrTypeInvalidFilter, err.Error())
+	}
+	startIndex, count, page := pagination(c)
+
+	query := &team.SearchTeamsQuery{
+		OrgID:        c.GetOrgID(),
+		Page:         page,
+		Limit:        count,
+		SignedInUser: c.SignedInUser,
+	}
+	if filter != nil {
+		if filter.Attribute != "displayname" {
+			return errorResponse(http.StatusBadRequest, ErrTypeInvalidFilter, "groups can only be filtered by displayName")
+		}
+		query.Name = filter.Value
+	}
+
+	result, err := api.teamService.SearchTeams(c.Req.Context(), query)
+	if err != nil {
+		return internalError(c, api.log, "Failed to list groups", err)
+	}
+
+	// members are left out of listings, identity providers fetch them per group
+	resources := make([]any, 0, len(result.Teams))
+	if count > 0 {
+		for _, t := range result.Teams {
+			resources = append(resources, api.toSCIMGroup(t, nil))
+		}
+	}
+
+	return listResponse(result.TotalCount, startIndex, resources)
+}
+
+func (api *API) getGroup(c *contextmodel.ReqContext) response.Response {
+	t, resp := api.getOrgTeam(c)
+	if resp != nil {
+		return resp
+	}
+
+	members, err := api.getMembers(c, t.ID)
+	if err != nil {
+		return internalError(c, api.log, "Failed to get group members", err)
+	}
+
+	return scimJSON(http.StatusOK, api.toSCIMGroup(t, members))
+}
+
+func (api *API) createGroup(c *contextmodel.ReqContext) response.Response {
+	var g Group
+	if resp := bind(c, &g); resp != nil {
+		return resp
+	}
+	if g.DisplayName == "" {
+		return errorResponse(http.StatusBadRequest, ErrTypeInvalidValue, "displayName is required")
+	}
+
+	memberIDs := make([]string, 0, len(g.Members))
+	for _, m := range g.Members {
+		memberIDs = append(memberIDs, m.Value)
+	}
+
+	if api.dryRun(c, "would create team", "name", g.DisplayName, "externalId", g.ExternalID, "members", memberIDs) {
+		return scimJSON(http.StatusCreated, g)
+	}
+
+	created, err := api.teamService.CreateTeam(c.Req.Context(), &team.CreateTeamCommand{
+		Name:          g.DisplayName,
+		ExternalUID:   g.ExternalID,
+		IsProvisioned: true,
+		OrgID:         c.GetOrgID(),
+	})
+	if err != nil {
+		if errors.Is(err, team.ErrTeamNameTaken) {
+			return errorResponse(http.StatusConflict, ErrTypeUniqueness, "a team with this name already exists")
+		}
+		return internalError(c, api.log, "Failed to create team", err)
+	}
+
+	if err := api.syncMembers(c, created.ID, &groupPatch{ReplaceMembers: true, Add: memberIDs}); err != nil {
+		return api.membersError(c, err)
+	}
+
+	api.log.FromContext(c.Req.Context()).Info("Provisioned team", "teamID", created.ID, "name", created.Name, "orgID", c.GetOrgID())
+	return api.getGroupResponse(c, created.ID, http.StatusCreated)
+}
+
+func (api *API) replaceGroup(c *contextmodel.ReqContext) response.Response {
+	t, resp := api.getOrgTeam(c)
+	if resp != nil {
+		return resp
+	}
+
+	var g Group
+	if resp := bind(c, &g); resp != nil {
+		return resp
+	}
+	if g.DisplayName == "" {
+		return errorResponse(http.StatusBadRequest, ErrTypeInvalidValue, "displayName is required")
+	}
+
+	patch := &groupPatch{DisplayName: &g.DisplayName, ExternalID: &g.ExternalID, ReplaceMembers: true}
+	for _, m := range g.Members {
+		patch.Add = append(patch.Add, m.Value)
+	}
+
+	return api.updateGroup(c, t, patch)
+}
+
+func (api *API) patchGroup(c *contextmodel.ReqContext) response.Response {
+	t, resp := api.getOrgTeam(c)
+	if resp != nil {
+		return resp
+	}
+
+	var req PatchRequest
+	if resp := bind(c, &req); resp != nil {
+		return resp
+	}
+
+	patch, err := parseGroupPatch(req.Operations)
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, ErrTypeInvalidValue, err.Error())
+	}
+
+	return api.updateGroup(c, t, patch)
+}
+
+func (api *API) updateGroup(c *contextmodel.ReqContext, t *team.TeamDTO, patch *groupPatch) response.Response {
+	cmd := &team.UpdateTeamCommand{
+		ID:          t.ID,
+		Name:        t.Name,
+		Email:       t.Email,
+		ExternalUID: t.ExternalUID,
+		OrgID:       t.OrgID,
+	}
+	if patch.DisplayName != nil {
+		cmd.Name = *patch.DisplayName
+	}
+	if patch.ExternalID != nil {
+		cmd.ExternalUID = *patch.ExternalID
+	}
+
+	if api.dryRun(c, "would update team", "teamID", t.ID, "name", cmd.Name, "externalId", cmd.ExternalUID,
+		"replaceMembers", patch.ReplaceMembers, "addMembers", patch.Add, "removeMembers", patch.Remove) {
+		return api.getGroupResponse(c, t.ID, http.StatusOK)
+	}
+
+	if cmd.Name != t.Name || cmd.ExternalUID != t.ExternalUID {
+		if err := api.teamService.UpdateTeam(c.Req.Context(), cmd); err != nil {
+			if errors.Is(err, team.ErrTeamNameTaken) {
+				return errorResponse(http.StatusConflict, ErrTypeUniqueness, "a team with this name already exists")
+			}
+			return internalError(c, api.log, "Failed to update team", err)
+		}
+	}
+
+	if err := api.syncMembers(c, t.ID, patch); err != nil {
+		return api.membersError(c, err)
+	}
+
+	return api.getGroupResponse(c, t.ID, http.StatusOK)
+}
+
+func (api *API) deleteGroup(c *contextmodel.ReqContext) response.Response {
+	t, resp := api.getOrgTeam(c)
+	if resp != nil {
+		return resp
+	}
+
+	if api.dryRun(c, "would delete team", "teamID", t.ID, "name", t.Name) {
+		return response.Empty(http.StatusNoContent)
+	}
+
+	if err := api.teamService.DeleteTeam(c.Req.Context(), &team.DeleteTeamCommand{OrgID: t.OrgID, ID: t.ID}); err != nil {
+		return internalError(c, api.log, "Failed to delete team", err)
+	}
+
+	// Clear associated team assignments, managed role and permissions
+	if err := api.acService.DeleteTeamPermissions(c.Req.Context(), t.OrgID, t.ID); err != nil {
+		return internalError(c, api.log, "Failed to delete team permissions", err)
+	}
+
+	api.log.FromContext(c.Req.Context()).Info("Deprovisioned team", "teamID", t.ID, "name", t.Name, "orgID", t.OrgID)
+	return response.Empty(http.StatusNoContent)
+}
+
+// syncMembers applies the membership changes of a patch. Members are
+// identified by their user UID.
+func (api *API) syncMembers(c *contextmodel.ReqContext, teamID int64, patch *groupPatch) error {
+	current, err := api.getMembers(c, teamID)
+	if err != nil {
+		return err
+	}
+
+	currentIDs := make(map[string]int64, len(current))
+	for _, m := range current {
+		currentIDs[m.UserUID] = m.UserID
+	}
+
+	toRemove := make(map[string]int64)
+	if patch.ReplaceMembers {
+		desired := make(map[string]bool, len(patch.Add))
+		for _, uid := range patch.Add {
+			desired[uid] = true
+		}
+		for uid, id := range currentIDs {
+			if !desired[uid] {
+				toRemove[uid] = id
+			}
+		}
+	}
+	for _, uid := range patch.Remove {
+		if id, ok := currentIDs[uid]; ok {
+			toRemove[uid] = id
+		}
+	}
+
+	// the new members are resolved before changing the team, only the users of
+	// the current org can be added
+	toAdd := make(map[string]int64)
+	for _, uid := range patch.Add {
+		if _, ok := currentIDs[uid]; ok {
+			continue
+		}
+		usr, err := api.userService.GetByUID(c.Req.Context(), &user.GetUserByUIDQuery{UID: uid})
+		if err != nil {
+			if errors.Is(err, user.ErrUserNotFound) {
+				return fmt.Errorf("%w: %s", errUnknownMember, uid)
+			}
+			return err
+		}
+		inOrg, err := api.isOrgMember(c, usr.ID)
+		if err != nil {
+			return err
+		}
+		if !inOrg || usr.IsServiceAccount {
+			return fmt.Errorf("%w: %s", errUnknownMember, uid)
+		}
+		toAdd[uid] = usr.ID
+	}
+
+	teamIDString := strconv.FormatInt(teamID, 10)
+	for _, id := range toAdd {
+		if _, err := api.teamPermissionsService.SetUserPermission(c.Req.Context(), c.GetOrgID(), accesscontrol.User{ID: id}, teamIDString, team.PermissionTypeMember.String()); err != nil {
+			return fmt.Errorf("failed adding user %d to team %d: %w", id, teamID, err)
+		}
+	}
+
+	for _, id := range toRemove {
+		if _, err := api.teamPermissionsService.SetUserPermission(c.Req.Context(), c.GetOrgID(), accesscontrol.User{ID: id}, teamIDString, ""); err != nil {
+			return fmt.Errorf("failed removing user %d from team %d: %w", id, teamID, err)
+		}
+	}
+
+	return nil
+}
+
+func (api *API) membersError(c *contextmodel.ReqContext, err error) response.Response {
+	if errors.Is(err, errUnknownMember) {
+		return errorResponse(http.StatusBadRequest, ErrTypeInvalidValue, err.Error())
+	}
+	return internalError(c, api.log, "Failed to update team members", err)
+}
+
+func (api *API) getGroupResponse(c *contextmodel.ReqContext, teamID int64, status int) response.Response {
+	t, err := api.teamService.GetTeamByID(c.Req.Context(), &team.GetTeamByIDQuery{
+		OrgID:        c.GetOrgID(),
+		ID:           teamID,
+		SignedInUser: c.SignedInUser,
+	})
+	if err != nil {
+		return internalError(c, api.log, "Failed to get team", err)
+	}
+
+	members, err := api.getMembers(c, t.ID)
+	if err != nil {
+		return internalError(c, api.log, "Failed to get group members", err)
+	}
+
+	return scimJSON(status, api.toSCIMGroup(t, members))
+}
+
+// getOrgTeam returns the team identified by the :id parameter in the current
+// organization.
+func (api *API) getOrgTeam(c *contextmodel.ReqContext) (*team.TeamDTO, response.Response) {
+	t, err := api.teamService.GetTeamByID(c.Req.Context(), &team.GetTeamByIDQuery{
+		OrgID:        c.GetOrgID(),
+		UID:          web.Params(c.Req)[":id"],
+		SignedInUser: c.SignedInUser,
+	})
+	if err != nil {
+		if errors.Is(err, team.ErrTeamNotFound) {
+			return nil, errorResponse(http.StatusNotFound, ErrTypeNoTarget, "group not found")
+		}
+		return nil, internalError(c, api.log, "Failed to get team", err)
+	}
+	return t, nil
+}
+
+func (api *API) getMembers(c *contextmodel.ReqContext, teamID int64) ([]*team.TeamMemberDTO, error) {
+	return api.teamService.GetTeamMembers(c.Req.Context(), &team.GetTeamMembersQuery{
+		OrgID:        c.GetOrgID(),
+		TeamID:       teamID,
+		SignedInUser: c.SignedInUser,
+	})
+}
+
+func (api *API) toSCIMGroup(t *team.TeamDTO, members []*team.TeamMemberDTO) *Group {
+	g := &Group{
+		Schemas:     []string{SchemaGroup},
+		ID:          t.UID,
+		ExternalID:  t.ExternalUID,
+		DisplayName: t.Name,
+		Meta: &Meta{
+			ResourceType: ResourceTypeGroup,
+			Location:     api.location("Groups", t.UID),
+		},
+	}
+	for _, m := range members {
+		g.Members = append(g.Members, GroupMember{
+			Value:   m.UserUID,
+			Display: m.Login,
+			Ref:     api.location("Users", m.UserUID),
+		})
+	}
+	return g
+}
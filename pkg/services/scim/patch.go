@@ -0,0 +1,223 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var errInvalidPatch = errors.New("invalid patch operation")
+
+// applyUserPatch applies PATCH operations to the SCIM representation of a
+// user. Attributes Grafana doesn't store are ignored rather than rejected, as
+// identity providers send every mapped attribute on each change.
+func applyUserPatch(u *User, ops []PatchOperation) error {
+	for _, op := range ops {
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+		case "remove":
+			// only removing optional attributes is meaningful for users
+			attr, _, err := parsePath(op.Path)
+			if err != nil {
+				return err
+			}
+			switch attr {
+			case "displayname":
+				u.DisplayName = ""
+			case "externalid":
+				u.ExternalID = ""
+			case "name":
+				u.Name = nil
+			}
+			continue
+		default:
+			return fmt.Errorf("%w: unsupported op %q", errInvalidPatch, op.Op)
+		}
+
+		if op.Path == "" {
+			var values map[string]json.RawMessage
+			if err := json.Unmarshal(op.Value, &values); err != nil {
+				return fmt.Errorf("%w: value must be an object when no path is given", errInvalidPatch)
+			}
+			for attr, raw := range values {
+				if err := setUserAttribute(u, strings.ToLower(attr), raw); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		attr, _, err := parsePath(op.Path)
+		if err != nil {
+			return err
+		}
+		if err := setUserAttribute(u, attr, op.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setUserAttribute(u *User, attr string, raw json.RawMessage) error {
+	var target any
+	switch attr {
+	case "active":
+		active, ok := parseBool(raw)
+		if !ok {
+			return fmt.Errorf("%w: active must be a boolean", errInvalidPatch)
+		}
+		u.Active = &active
+		return nil
+	case "username":
+		target = &u.UserName
+	case "displayname":
+		target = &u.DisplayName
+	case "externalid":
+		target = &u.ExternalID
+	case "emails":
+		target = &u.Emails
+	case "name":
+		u.Name = &Name{}
+		target = u.Name
+	case "name.givenname", "name.familyname", "name.formatted":
+		if u.Name == nil {
+			u.Name = &Name{}
+		}
+		switch attr {
+		case "name.givenname":
+			target = &u.Name.GivenName
+		case "name.familyname":
+			target = &u.Name.FamilyName
+		default:
+			target = &u.Name.Formatted
+		}
+	case "emails.value":
+		var email string
+		if err := json.Unmarshal(raw, &email); err != nil {
+			return fmt.Errorf("%w: %s must be a string", errInvalidPatch, attr)
+		}
+		setPrimaryEmail(u, email)
+		return nil
+	default:
+		return nil
+	}
+
+	if err := json.Unmarshal(raw, target); err != nil {
+		return fmt.Errorf("%w: invalid value for %s", errInvalidPatch, attr)
+	}
+	return nil
+}
+
+func setPrimaryEmail(u *User, email string) {
+	for i := range u.Emails {
+		if u.Emails[i].Primary {
+			u.Emails[i].Value = email
+			return
+		}
+	}
+	if len(u.Emails) > 0 {
+		u.Emails[0].Value = email
+		return
+	}
+	u.Emails = []Email{{Value: email, Primary: true}}
+}
+
+// groupPatch is the net effect of the PATCH operations sent for a group
+type groupPatch struct {
+	DisplayName *string
+	ExternalID  *string
+	// ReplaceMembers is set when the member list should become exactly Add
+	ReplaceMembers bool
+	Add            []string
+	Remove         []string
+}
+
+func parseGroupPatch(ops []PatchOperation) (*groupPatch, error) {
+	patch := &groupPatch{}
+	for _, op := range ops {
+		opType := strings.ToLower(op.Op)
+		attr, filter, err := parsePath(op.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		switch opType {
+		case "add", "replace":
+			if attr == "" {
+				var values map[string]json.RawMessage
+				if err := json.Unmarshal(op.Value, &values); err != nil {
+					return nil, fmt.Errorf("%w: value must be an object when no path is given", errInvalidPatch)
+				}
+				for key, raw := range values {
+					if err := patch.set(opType, strings.ToLower(key), raw); err != nil {
+						return nil, err
+					}
+				}
+				continue
+			}
+			if err := patch.set(opType, attr, op.Value); err != nil {
+				return nil, err
+			}
+		case "remove":
+			if attr != "members" {
+				return nil, fmt.Errorf("%w: only members can be removed from a group", errInvalidPatch)
+			}
+			if filter != nil {
+				patch.Remove = append(patch.Remove, filter.Value)
+				continue
+			}
+			if len(op.Value) == 0 {
+				patch.ReplaceMembers = true
+				patch.Add = nil
+				continue
+			}
+			members, err := unmarshalMembers(op.Value)
+			if err != nil {
+				return nil, err
+			}
+			patch.Remove = append(patch.Remove, members...)
+		default:
+			return nil, fmt.Errorf("%w: unsupported op %q", errInvalidPatch, op.Op)
+		}
+	}
+	return patch, nil
+}
+
+func (p *groupPatch) set(opType, attr string, raw json.RawMessage) error {
+	switch attr {
+	case "displayname", "externalid":
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return fmt.Errorf("%w: %s must be a string", errInvalidPatch, attr)
+		}
+		if attr == "displayname" {
+			p.DisplayName = &value
+		} else {
+			p.ExternalID = &value
+		}
+	case "members":
+		members, err := unmarshalMembers(raw)
+		if err != nil {
+			return err
+		}
+		if opType == "replace" {
+			p.ReplaceMembers = true
+			p.Add = nil
+		}
+		p.Add = append(p.Add, members...)
+	}
+	return nil
+}
+
+func unmarshalMembers(raw json.RawMessage) ([]string, error) {
+	var members []GroupMember
+	if err := json.Unmarshal(raw, &members); err != nil {
+		return nil, fmt.Errorf("%w: members must be a list of objects with a value", errInvalidPatch)
+	}
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.Value)
+	}
+	return ids, nil
+}
@@ -0,0 +1,110 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyUserPatch(t *testing.T) {
+	newUser := func() *User {
+		active := true
+		return &User{
+			UserName:    "john",
+			DisplayName: "John",
+			Emails:      []Email{{Value: "john@example.com", Primary: true}},
+			Active:      &active,
+		}
+	}
+
+	t.Run("deactivates with the string booleans sent by Azure AD", func(t *testing.T) {
+		u := newUser()
+		err := applyUserPatch(u, []PatchOperation{{Op: "Replace", Path: "active", Value: json.RawMessage(`"False"`)}})
+		require.NoError(t, err)
+		assert.False(t, u.IsActive())
+	})
+
+	t.Run("applies an operation without path", func(t *testing.T) {
+		u := newUser()
+		err := applyUserPatch(u, []PatchOperation{{Op: "replace", Value: json.RawMessage(`{"active":false,"displayName":"Johnny","externalId":"abc"}`)}})
+		require.NoError(t, err)
+		assert.False(t, u.IsActive())
+		assert.Equal(t, "Johnny", u.DisplayName)
+		assert.Equal(t, "abc", u.ExternalID)
+	})
+
+	t.Run("replaces the primary email through a filtered path", func(t *testing.T) {
+		u := newUser()
+		err := applyUserPatch(u, []PatchOperation{{Op: "replace", Path: `emails[type eq "work"].value`, Value: json.RawMessage(`"j@example.com"`)}})
+		require.NoError(t, err)
+		assert.Equal(t, "j@example.com", u.PrimaryEmail())
+	})
+
+	t.Run("ignores attributes Grafana does not store", func(t *testing.T) {
+		u := newUser()
+		err := applyUserPatch(u, []PatchOperation{{Op: "add", Path: "title", Value: json.RawMessage(`"Engineer"`)}})
+		require.NoError(t, err)
+		assert.Equal(t, newUser(), u)
+	})
+
+	t.Run("rejects unknown operations", func(t *testing.T) {
+		err := applyUserPatch(newUser(), []PatchOperation{{Op: "move", Path: "active"}})
+		require.ErrorIs(t, err, errInvalidPatch)
+	})
+
+	t.Run("rejects invalid active value", func(t *testing.T) {
+		err := applyUserPatch(newUser(), []PatchOperation{{Op: "replace", Path: "active", Value: json.RawMessage(`"maybe"`)}})
+		require.ErrorIs(t, err, errInvalidPatch)
+	})
+}
+
+func TestParseGroupPatch(t *testing.T) {
+	t.Run("adds and removes members", func(t *testing.T) {
+		patch, err := parseGroupPatch([]PatchOperation{
+			{Op: "add", Path: "members", Value: json.RawMessage(`[{"value":"u1"},{"value":"u2"}]`)},
+			{Op: "remove", Path: `members[value eq "u3"]`},
+		})
+		require.NoError(t, err)
+		assert.False(t, patch.ReplaceMembers)
+		assert.Equal(t, []string{"u1", "u2"}, patch.Add)
+		assert.Equal(t, []string{"u3"}, patch.Remove)
+	})
+
+	t.Run("replaces display name and members without path", func(t *testing.T) {
+		patch, err := parseGroupPatch([]PatchOperation{
+			{Op: "replace", Value: json.RawMessage(`{"displayName":"SRE","members":[{"value":"u1"}]}`)},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, patch.DisplayName)
+		assert.Equal(t, "SRE", *patch.DisplayName)
+		assert.True(t, patch.ReplaceMembers)
+		assert.Equal(t, []string{"u1"}, patch.Add)
+	})
+
+	t.Run("removing all members replaces them with none", func(t *testing.T) {
+		patch, err := parseGroupPatch([]PatchOperation{{Op: "remove", Path: "members"}})
+		require.NoError(t, err)
+		assert.True(t, patch.ReplaceMembers)
+		assert.Empty(t, patch.Add)
+	})
+
+	t.Run("rejects removing other attributes", func(t *testing.T) {
+		_, err := parseGroupPatch([]PatchOperation{{Op: "remove", Path: "displayName"}})
+		require.ErrorIs(t, err, errInvalidPatch)
+	})
+}
+
+func TestResolve(t *testing.T) {
+	u := &User{
+		UserName: "jdoe",
+		Name:     &Name{GivenName: "John", FamilyName: "Doe"},
+		Emails:   []Email{{Value: "other@example.com"}, {Value: "john@example.com", Primary: true}},
+	}
+
+	assert.Equal(t, "jdoe", resolve(u, []string{"username"}))
+	assert.Equal(t, "john@example.com", resolve(u, []string{"emails"}))
+	assert.Equal(t, "John Doe", resolve(u, splitAttributes("displayName, name.formatted, name")))
+	assert.Equal(t, "", resolve(u, []string{"externalid"}))
+}
@@ -0,0 +1,257 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Config is read from the [auth.scim] section
+type Config struct {
+	UserSyncEnabled  bool
+	GroupSyncEnabled bool
+	// AuthModule is the login method provisioned users sign in with. The
+	// externalId sent by the identity provider is stored against it, so the
+	// login can be matched to the provisioned user.
+	AuthModule     string
+	DefaultOrgRole org.RoleType
+	// DryRun logs the changes a request would make without applying them
+	DryRun  bool
+	Mapping AttributeMapping
+}
+
+// AttributeMapping lists, for each Grafana user field, the SCIM attributes to
+// read it from. The first non-empty attribute wins.
+type AttributeMapping struct {
+	Login []string
+	Email []string
+	Name  []string
+}
+
+func ReadConfig(cfg *setting.Cfg) Config {
+	section := cfg.Raw.Section("auth.scim")
+
+	role := org.RoleType(section.Key("default_org_role").MustString(string(org.RoleViewer)))
+	if !role.IsValid() {
+		role = org.RoleViewer
+	}
+
+	return Config{
+		UserSyncEnabled:  section.Key("user_sync_enabled").MustBool(false),
+		GroupSyncEnabled: section.Key("group_sync_enabled").MustBool(false),
+		AuthModule:       section.Key("auth_module").MustString(login.SAMLAuthModule),
+		DefaultOrgRole:   role,
+		DryRun:           section.Key("dry_run").MustBool(false),
+		Mapping: AttributeMapping{
+			Login: splitAttributes(section.Key("login_attribute").MustString("userName")),
+			Email: splitAttributes(section.Key("email_attribute").MustString("emails")),
+			Name:  splitAttributes(section.Key("name_attribute").MustString("displayName,name.formatted,name")),
+		},
+	}
+}
+
+func splitAttributes(s string) []string {
+	attrs := make([]string, 0)
+	for _, attr := range strings.Split(s, ",") {
+		if attr = strings.TrimSpace(attr); attr != "" {
+			attrs = append(attrs, strings.ToLower(attr))
+		}
+	}
+	return attrs
+}
+
+// resolve returns the first non-empty value of the given attributes
+func resolve(u *User, attrs []string) string {
+	for _, attr := range attrs {
+		var value string
+		switch attr {
+		case "username":
+			value = u.UserName
+		case "externalid":
+			value = u.ExternalID
+		case "displayname":
+			value = u.DisplayName
+		case "emails", "emails.value":
+			value = u.PrimaryEmail()
+		case "name.formatted":
+			if u.Name != nil {
+				value = u.Name.Formatted
+			}
+		case "name":
+			if u.Name != nil {
+				value = strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName)
+			}
+		}
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// API serves the SCIM 2.0 endpoints identity providers use to provision
+// users and teams into the organization of the calling service account.
+type API struct {
+	cfg                    *setting.Cfg
+	scimCfg                Config
+	accessControl          accesscontrol.AccessControl
+	acService              accesscontrol.Service
+	userService            user.Service
+	orgService             org.Service
+	teamService            team.Service
+	teamPermissionsService accesscontrol.TeamPermissionsService
+	authInfoService        login.AuthInfoService
+	userTokenService       auth.UserTokenService
+	log                    log.Logger
+}
+
+func ProvideAPI(
+	cfg *setting.Cfg,
+	features featuremgmt.FeatureToggles,
+	routeRegister routing.RouteRegister,
+	accessControl accesscontrol.AccessControl,
+	acService accesscontrol.Service,
+	userService user.Service,
+	orgService org.Service,
+	teamService team.Service,
+	teamPermissionsService accesscontrol.TeamPermissionsService,
+	authInfoService login.AuthInfoService,
+	userTokenService auth.UserTokenService,
+) *API {
+	api := &API{
+		cfg:                    cfg,
+		scimCfg:                ReadConfig(cfg),
+		accessControl:          accessControl,
+		acService:              acService,
+		userService:            userService,
+		orgService:             orgService,
+		teamService:            teamService,
+		teamPermissionsService: teamPermissionsService,
+		authInfoService:        authInfoService,
+		userTokenService:       userTokenService,
+		log:                    log.New("scim"),
+	}
+
+	if features.IsEnabledGlobally(featuremgmt.FlagEnableSCIM) {
+		api.registerRoutes(routeRegister)
+	}
+
+	return api
+}
+
+func (api *API) registerRoutes(router routing.RouteRegister) {
+	authorize := accesscontrol.Middleware(api.accessControl)
+
+	router.Group("/scim/v2", func(scimRoute routing.RouteRegister) {
+		if api.scimCfg.UserSyncEnabled {
+			scimRoute.Get("/Users", authorize(accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersRead)), routing.Wrap(api.listUsers))
+			scimRoute.Get("/Users/:id", authorize(accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersRead)), routing.Wrap(api.getUser))
+			scimRoute.Post("/Users", authorize(accesscontrol.EvalAll(
+				accesscontrol.EvalPermission(accesscontrol.ActionUsersCreate),
+				accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersAdd),
+			)), routing.Wrap(api.createUser))
+			scimRoute.Put("/Users/:id", authorize(accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersWrite)), routing.Wrap(api.replaceUser))
+			scimRoute.Patch("/Users/:id", authorize(accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersWrite)), routing.Wrap(api.patchUser))
+			scimRoute.Delete("/Users/:id", authorize(accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersRemove)), routing.Wrap(api.deleteUser))
+		}
+
+		if api.scimCfg.GroupSyncEnabled {
+			scimRoute.Get("/Groups", authorize(accesscontrol.EvalPermission(accesscontrol.ActionTeamsRead)), routing.Wrap(api.listGroups))
+			scimRoute.Get("/Groups/:id", authorize(accesscontrol.EvalPermission(accesscontrol.ActionTeamsRead)), routing.Wrap(api.getGroup))
+			scimRoute.Post("/Groups", authorize(accesscontrol.EvalPermission(accesscontrol.ActionTeamsCreate)), routing.Wrap(api.createGroup))
+			scimRoute.Put("/Groups/:id", authorize(accesscontrol.EvalAll(
+				accesscontrol.EvalPermission(accesscontrol.ActionTeamsWrite),
+				accesscontrol.EvalPermission(accesscontrol.ActionTeamsPermissionsWrite),
+			)), routing.Wrap(api.replaceGroup))
+			scimRoute.Patch("/Groups/:id", authorize(accesscontrol.EvalAll(
+				accesscontrol.EvalPermission(accesscontrol.ActionTeamsWrite),
+				accesscontrol.EvalPermission(accesscontrol.ActionTeamsPermissionsWrite),
+			)), routing.Wrap(api.patchGroup))
+			scimRoute.Delete("/Groups/:id", authorize(accesscontrol.EvalPermission(accesscontrol.ActionTeamsDelete)), routing.Wrap(api.deleteGroup))
+		}
+	}, middleware.ReqSignedIn)
+}
+
+// dryRun logs the change and reports whether it should be skipped
+func (api *API) dryRun(c *contextmodel.ReqContext, msg string, ctx ...any) bool {
+	if !api.scimCfg.DryRun {
+		return false
+	}
+	api.log.FromContext(c.Req.Context()).Info("Dry run: "+msg, append(ctx, "orgID", c.GetOrgID())...)
+	return true
+}
+
+func (api *API) location(resource, id string) string {
+	return fmt.Sprintf("%s/scim/v2/%s/%s", strings.TrimSuffix(api.cfg.AppURL, "/"), resource, id)
+}
+
+func bind(c *contextmodel.ReqContext, v any) response.Response {
+	if err := json.NewDecoder(c.Req.Body).Decode(v); err != nil {
+		return errorResponse(http.StatusBadRequest, ErrTypeInvalidSyntax, "request body is not valid JSON")
+	}
+	return nil
+}
+
+// pagination reads the 1-based startIndex and count query parameters and
+// converts them to Grafana's page and limit.
+func pagination(c *contextmodel.ReqContext) (startIndex, count, page int) {
+	startIndex, err := strconv.Atoi(c.Query("startIndex"))
+	if err != nil || startIndex < 1 {
+		startIndex = 1
+	}
+	count, err = strconv.Atoi(c.Query("count"))
+	if err != nil || count < 0 {
+		count = defaultPageSize
+	}
+	if count > maxPageSize {
+		count = maxPageSize
+	}
+	if count == 0 {
+		return startIndex, 0, 1
+	}
+	return startIndex, count, (startIndex-1)/count + 1
+}
+
+func listResponse(total int64, startIndex int, resources []any) response.Response {
+	return scimJSON(http.StatusOK, ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+func scimJSON(status int, body any) response.Response {
+	return response.JSON(status, body).SetHeader("Content-Type", "application/scim+json")
+}
+
+func errorResponse(status int, scimType, detail string) response.Response {
+	return scimJSON(status, Error{
+		Schemas:  []string{SchemaError},
+		ScimType: scimType,
+		Detail:   detail,
+		Status:   strconv.Itoa(status),
+	})
+}
+
+func internalError(c *contextmodel.ReqContext, l log.Logger, msg string, err error) response.Response {
+	l.FromContext(c.Req.Context()).Error(msg, "error", err)
+	return errorResponse(http.StatusInternalServerError, "", msg)
+}
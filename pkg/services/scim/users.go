@@ -0,0 +1,428 @@
+package scim
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (api *API) listUsers(c *contextmodel.ReqContext) response.Response {
+	filter, err := ParseFilter(c.Query("filter"))
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, ErrTypeInvalidFilter, err.Error())
+	}
+	startIndex, count, page := pagination(c)
+
+	if filter != nil {
+		return api.findUsers(c, filter, startIndex)
+	}
+
+	result, err := api.orgService.SearchOrgUsers(c.Req.Context(), &org.SearchOrgUsersQuery{
+		OrgID:                    c.GetOrgID(),
+		Page:                     page,
+		Limit:                    count,
+		DontEnforceAccessControl: true,
+	})
+	if err != nil {
+		return internalError(c, api.log, "Failed to list users", err)
+	}
+
+	resources := make([]any, 0, len(result.OrgUsers))
+	if count > 0 {
+		for _, ou := range result.OrgUsers {
+			resources = append(resources, api.toSCIMUser(&user.User{
+				UID:        ou.UID,
+				Login:      ou.Login,
+				Email:      ou.Email,
+				Name:       ou.Name,
+				IsDisabled: ou.IsDisabled,
+				Created:    ou.Created,
+				Updated:    ou.Updated,
+			}, ""))
+		}
+	}
+
+	return listResponse(result.TotalCount, startIndex, resources)
+}
+
+// findUsers answers the lookups identity providers make before creating a
+// user, to link to an existing account instead.
+func (api *API) findUsers(c *contextmodel.ReqContext, filter *Filter, startIndex int) response.Response {
+	var (
+		usr *user.User
+		err error
+	)
+	switch filter.Attribute {
+	case "username":
+		usr, err = api.userService.GetByLogin(c.Req.Context(), &user.GetUserByLoginQuery{LoginOrEmail: filter.Value})
+	case "emails", "emails.value":
+		usr, err = api.userService.GetByEmail(c.Req.Context(), &user.GetUserByEmailQuery{Email: filter.Value})
+	default:
+		return errorResponse(http.StatusBadRequest, ErrTypeInvalidFilter, "users can only be filtered by userName or emails")
+	}
+	if errors.Is(err, user.ErrUserNotFound) {
+		return listResponse(0, startIndex, []any{})
+	}
+	if err != nil {
+		return internalError(c, api.log, "Failed to find user", err)
+	}
+
+	inOrg, err := api.isOrgMember(c, usr.ID)
+	if err != nil {
+		return internalError(c, api.log, "Failed to find user", err)
+	}
+	if !inOrg {
+		return listResponse(0, startIndex, []any{})
+	}
+
+	return listResponse(1, startIndex, []any{api.toSCIMUser(usr, api.externalID(c, usr.ID))})
+}
+
+func (api *API) getUser(c *contextmodel.ReqContext) response.Response {
+	usr, resp := api.getOrgUser(c)
+	if resp != nil {
+		return resp
+	}
+	return scimJSON(http.StatusOK, api.toSCIMUser(usr, api.externalID(c, usr.ID)))
+}
+
+func (api *API) createUser(c *contextmodel.ReqContext) response.Response {
+	var su User
+	if resp := bind(c, &su); resp != nil {
+		return resp
+	}
+
+	login := resolve(&su, api.scimCfg.Mapping.Login)
+	if login == "" {
+		return errorResponse(http.StatusBadRequest, ErrTypeInvalidValue, "the attribute mapped to login is empty")
+	}
+
+	cmd := &user.CreateUserCommand{
+		Login:         login,
+		Email:         resolve(&su, api.scimCfg.Mapping.Email),
+		Name:          resolve(&su, api.scimCfg.Mapping.Name),
+		EmailVerified: true,
+		IsDisabled:    !su.IsActive(),
+		SkipOrgSetup:  true,
+		IsProvisioned: true,
+	}
+
+	if api.dryRun(c, "would create user", "login", cmd.Login, "email", cmd.Email, "externalId", su.ExternalID, "active", su.IsActive()) {
+		return scimJSON(http.StatusCreated, api.toSCIMUser(&user.User{
+			Login: cmd.Login, Email: cmd.Email, Name: cmd.Name, IsDisabled: cmd.IsDisabled,
+		}, su.ExternalID))
+	}
+
+	usr, err := api.userService.Create(c.Req.Context(), cmd)
+	if err != nil {
+		if errors.Is(err, user.ErrUserAlreadyExists) {
+			return errorResponse(http.StatusConflict, ErrTypeUniqueness, "a user with this login or email already exists")
+		}
+		return internalError(c, api.log, "Failed to create user", err)
+	}
+
+	// provisioned users are not added to an org on creation
+	if err := api.orgService.AddOrgUser(c.Req.Context(), &org.AddOrgUserCommand{
+		OrgID:  c.GetOrgID(),
+		UserID: usr.ID,
+		Role:   api.scimCfg.DefaultOrgRole,
+	}); err != nil {
+		return internalError(c, api.log, "Failed to add user to organization", err)
+	}
+
+	if err := api.setExternalID(c, usr.ID, su.ExternalID); err != nil {
+		return internalError(c, api.log, "Failed to store externalId", err)
+	}
+
+	api.log.FromContext(c.Req.Context()).Info("Provisioned user", "userID", usr.ID, "login", usr.Login, "orgID", c.GetOrgID())
+	return scimJSON(http.StatusCreated, api.toSCIMUser(usr, su.ExternalID))
+}
+
+func (api *API) replaceUser(c *contextmodel.ReqContext) response.Response {
+	usr, resp := api.getOrgUser(c)
+	if resp != nil {
+		return resp
+	}
+
+	var su User
+	if resp := bind(c, &su); resp != nil {
+		return resp
+	}
+
+	return api.updateUser(c, usr, &su)
+}
+
+func (api *API) patchUser(c *contextmodel.ReqContext) response.Response {
+	usr, resp := api.getOrgUser(c)
+	if resp != nil {
+		return resp
+	}
+
+	var patch PatchRequest
+	if resp := bind(c, &patch); resp != nil {
+		return resp
+	}
+
+	su := api.toSCIMUser(usr, api.externalID(c, usr.ID))
+	if err := applyUserPatch(su, patch.Operations); err != nil {
+		return errorResponse(http.StatusBadRequest, ErrTypeInvalidValue, err.Error())
+	}
+
+	return api.updateUser(c, usr, su)
+}
+
+// updateUser applies the desired SCIM state to an existing user. A user that
+// signed in before being provisioned is taken over by the identity provider.
+func (api *API) updateUser(c *contextmodel.ReqContext, usr *user.User, su *User) response.Response {
+	disabled := !su.IsActive()
+	provisioned := true
+	cmd := &user.UpdateUserCommand{
+		UserID:        usr.ID,
+		Login:         resolve(su, api.scimCfg.Mapping.Login),
+		Email:         resolve(su, api.scimCfg.Mapping.Email),
+		Name:          resolve(su, api.scimCfg.Mapping.Name),
+		IsDisabled:    &disabled,
+		IsProvisioned: &provisioned,
+	}
+
+	// login, email, name and state are shared by every org of the user
+	if isChanged(cmd.Login, usr.Login) || isChanged(cmd.Email, usr.Email) || isChanged(cmd.Name, usr.Name) ||
+		!usr.IsProvisioned || isChanged(su.ExternalID, api.externalID(c, usr.ID)) {
+		if resp := api.authorizeGlobalChange(c, usr, accesscontrol.ActionUsersWrite); resp != nil {
+			return resp
+		}
+	}
+	if disabled != usr.IsDisabled {
+		if resp := api.authorizeGlobalChange(c, usr, accesscontrol.ActionUsersDisable); resp != nil {
+			return resp
+		}
+	}
+
+	if api.dryRun(c, "would update user", "userID", usr.ID, "login", cmd.Login, "email", cmd.Email, "externalId", su.ExternalID, "active", !disabled) {
+		return scimJSON(http.StatusOK, su)
+	}
+
+	if err := api.userService.Update(c.Req.Context(), cmd); err != nil {
+		if errors.Is(err, user.ErrUserAlreadyExists) {
+			return errorResponse(http.StatusConflict, ErrTypeUniqueness, "a user with this login or email already exists")
+		}
+		return internalError(c, api.log, "Failed to update user", err)
+	}
+
+	if disabled && !usr.IsDisabled {
+		if err := api.userTokenService.RevokeAllUserTokens(c.Req.Context(), usr.ID); err != nil {
+			return internalError(c, api.log, "Failed to revoke sessions of deactivated user", err)
+		}
+		api.log.FromContext(c.Req.Context()).Info("Deactivated user", "userID", usr.ID, "orgID", c.GetOrgID())
+	}
+
+	if err := api.setExternalID(c, usr.ID, su.ExternalID); err != nil {
+		return internalError(c, api.log, "Failed to store externalId", err)
+	}
+
+	updated, err := api.userService.GetByID(c.Req.Context(), &user.GetUserByIDQuery{ID: usr.ID})
+	if err != nil {
+		return internalError(c, api.log, "Failed to get user", err)
+	}
+	return scimJSON(http.StatusOK, api.toSCIMUser(updated, su.ExternalID))
+}
+
+// deleteUser removes the user from the organization, deleting it when it no
+// longer belongs to any.
+func (api *API) deleteUser(c *contextmodel.ReqContext) response.Response {
+	usr, resp := api.getOrgUser(c)
+	if resp != nil {
+		return resp
+	}
+
+	// without the global permission only the membership of a user shared with
+	// other orgs is removed, the user and its sessions are kept
+	global, err := api.canChangeGlobally(c, usr, accesscontrol.ActionUsersDelete)
+	if err != nil {
+		return internalError(c, api.log, "Failed to evaluate permissions", err)
+	}
+
+	if api.dryRun(c, "would remove user", "userID", usr.ID, "login", usr.Login, "deleteOrphaned", global) {
+		return response.Empty(http.StatusNoContent)
+	}
+
+	if err := api.orgService.RemoveOrgUser(c.Req.Context(), &org.RemoveOrgUserCommand{
+		UserID:                   usr.ID,
+		OrgID:                    c.GetOrgID(),
+		ShouldDeleteOrphanedUser: global,
+	}); err != nil {
+		return internalError(c, api.log, "Failed to remove user", err)
+	}
+
+	if global {
+		if err := api.userTokenService.RevokeAllUserTokens(c.Req.Context(), usr.ID); err != nil {
+			return internalError(c, api.log, "Failed to revoke sessions of removed user", err)
+		}
+	}
+
+	api.log.FromContext(c.Req.Context()).Info("Deprovisioned user", "userID", usr.ID, "login", usr.Login, "orgID", c.GetOrgID())
+	return response.Empty(http.StatusNoContent)
+}
+
+// getOrgUser returns the user identified by the :id parameter, which must be
+// a member of the current organization.
+func (api *API) getOrgUser(c *contextmodel.ReqContext) (*user.User, response.Response) {
+	notFound := errorResponse(http.StatusNotFound, ErrTypeNoTarget, "user not found")
+
+	usr, err := api.userService.GetByUID(c.Req.Context(), &user.GetUserByUIDQuery{UID: web.Params(c.Req)[":id"]})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil, notFound
+		}
+		return nil, internalError(c, api.log, "Failed to get user", err)
+	}
+
+	inOrg, err := api.isOrgMember(c, usr.ID)
+	if err != nil {
+		return nil, internalError(c, api.log, "Failed to get user", err)
+	}
+	if !inOrg || usr.IsServiceAccount {
+		return nil, notFound
+	}
+
+	return usr, nil
+}
+
+func (api *API) isOrgMember(c *contextmodel.ReqContext, userID int64) (bool, error) {
+	result, err := api.orgService.SearchOrgUsers(c.Req.Context(), &org.SearchOrgUsersQuery{
+		OrgID:                    c.GetOrgID(),
+		UserID:                   userID,
+		Limit:                    1,
+		Page:                     1,
+		DontEnforceAccessControl: true,
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.TotalCount > 0, nil
+}
+
+// authorizeGlobalChange returns a 403 response when the signed in user can't
+// change the attributes of usr shared by all of its orgs.
+func (api *API) authorizeGlobalChange(c *contextmodel.ReqContext, usr *user.User, action string) response.Response {
+	ok, err := api.canChangeGlobally(c, usr, action)
+	if err != nil {
+		return internalError(c, api.log, "Failed to evaluate permissions", err)
+	}
+	if !ok {
+		return errorResponse(http.StatusForbidden, "", "changing a Grafana admin or a user of another organization requires "+action+" on "+accesscontrol.ScopeGlobalUsersAll)
+	}
+	return nil
+}
+
+// canChangeGlobally reports whether the signed in user can apply action to
+// usr globally. It requires the action on every user, unless usr is no Grafana
+// admin and only belongs to the current org, in which case the org scoped
+// permission checked by the route is enough.
+func (api *API) canChangeGlobally(c *contextmodel.ReqContext, usr *user.User, action string) (bool, error) {
+	ok, err := api.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, accesscontrol.EvalPermission(action, accesscontrol.ScopeGlobalUsersAll))
+	if err != nil || ok {
+		return ok, err
+	}
+	if usr.IsAdmin {
+		return false, nil
+	}
+
+	orgs, err := api.orgService.GetUserOrgList(c.Req.Context(), &org.GetUserOrgListQuery{UserID: usr.ID})
+	if err != nil {
+		return false, err
+	}
+	for _, o := range orgs {
+		if o.OrgID != c.GetOrgID() {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// isChanged reports whether a desired value replaces the current one, the
+// empty values are left unchanged by the update.
+func isChanged(desired, current string) bool {
+	return desired != "" && desired != current
+}
+
+func (api *API) externalID(c *contextmodel.ReqContext, userID int64) string {
+	info, err := api.authInfoService.GetAuthInfo(c.Req.Context(), &login.GetAuthInfoQuery{
+		UserId:     userID,
+		AuthModule: api.scimCfg.AuthModule,
+	})
+	if err != nil {
+		if !errors.Is(err, user.ErrUserNotFound) {
+			api.log.FromContext(c.Req.Context()).Warn("Failed to get externalId", "userID", userID, "error", err)
+		}
+		return ""
+	}
+	return info.ExternalUID
+}
+
+// setExternalID links the user to the identity provider, so that signing in
+// through AuthModule resolves to the provisioned user.
+func (api *API) setExternalID(c *contextmodel.ReqContext, userID int64, externalID string) error {
+	if externalID == "" {
+		return nil
+	}
+
+	_, err := api.authInfoService.GetAuthInfo(c.Req.Context(), &login.GetAuthInfoQuery{
+		UserId:     userID,
+		AuthModule: api.scimCfg.AuthModule,
+	})
+	if errors.Is(err, user.ErrUserNotFound) {
+		return api.authInfoService.SetAuthInfo(c.Req.Context(), &login.SetAuthInfoCommand{
+			AuthModule:  api.scimCfg.AuthModule,
+			AuthId:      externalID,
+			UserId:      userID,
+			ExternalUID: externalID,
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	return api.authInfoService.UpdateAuthInfo(c.Req.Context(), &login.UpdateAuthInfoCommand{
+		AuthModule:  api.scimCfg.AuthModule,
+		AuthId:      externalID,
+		UserId:      userID,
+		ExternalUID: externalID,
+	})
+}
+
+func (api *API) toSCIMUser(usr *user.User, externalID string) *User {
+	active := !usr.IsDisabled
+	su := &User{
+		Schemas:     []string{SchemaUser},
+		ID:          usr.UID,
+		ExternalID:  externalID,
+		UserName:    usr.Login,
+		DisplayName: usr.Name,
+		Active:      &active,
+		Meta:        &Meta{ResourceType: ResourceTypeUser},
+	}
+	if usr.Email != "" {
+		su.Emails = []Email{{Value: usr.Email, Type: "work", Primary: true}}
+	}
+	if usr.UID != "" {
+		su.Meta.Location = api.location("Users", usr.UID)
+	}
+	if !usr.Created.IsZero() {
+		su.Meta.Created = timePtr(usr.Created)
+		su.Meta.LastModified = timePtr(usr.Updated)
+	}
+	return su
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -12,9 +12,11 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/rendering/rendercache"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -30,6 +32,8 @@ var (
 // Screenshot represents a path to a screenshot on disk.
 type Screenshot struct {
 	Path string
+	// URL is a signed URL of the screenshot when the render cache is enabled
+	URL string
 }
 
 type screenshotFunc func(ctx context.Context, opts ScreenshotOptions) (*Screenshot, error)
@@ -43,20 +47,24 @@ type ScreenshotService interface {
 
 // HeadlessScreenshotService takes screenshots using a headless browser.
 type HeadlessScreenshotService struct {
-	cfg *setting.Cfg
-	ds  dashboards.DashboardService
-	rs  rendering.Service
+	cfg   *setting.Cfg
+	ds    dashboards.DashboardService
+	rs    rendering.Service
+	cache *rendercache.Service
 
 	duration  prometheus.Histogram
 	failures  *prometheus.CounterVec
 	successes prometheus.Counter
 }
 
-func NewHeadlessScreenshotService(cfg *setting.Cfg, ds dashboards.DashboardService, rs rendering.Service, r prometheus.Registerer) ScreenshotService {
+// NewHeadlessScreenshotService returns a new HeadlessScreenshotService. The screenshots of the same dashboard version,
+// panel and options are reused from the render cache when it isn't nil.
+func NewHeadlessScreenshotService(cfg *setting.Cfg, ds dashboards.DashboardService, rs rendering.Service, cache *rendercache.Service, r prometheus.Registerer) ScreenshotService {
 	return &HeadlessScreenshotService{
-		cfg: cfg,
-		ds:  ds,
-		rs:  rs,
+		cfg:   cfg,
+		ds:    ds,
+		rs:    rs,
+		cache: cache,
 		duration: promauto.With(r).NewHistogram(prometheus.HistogramOpts{
 			Name:      "duration_seconds",
 			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 15},
@@ -96,6 +104,24 @@ func (s *HeadlessScreenshotService) Take(ctx context.Context, opts ScreenshotOpt
 
 	opts = opts.SetDefaults()
 
+	cacheKey := rendercache.Key{
+		OrgID:            dashboard.OrgID,
+		DashboardUID:     dashboard.UID,
+		DashboardVersion: dashboard.Version,
+		PanelID:          opts.PanelID,
+		From:             opts.From,
+		To:               opts.To,
+		Width:            opts.Width,
+		Height:           opts.Height,
+		Theme:            opts.Theme,
+	}
+	if s.cache != nil {
+		if image, ok := s.cache.Get(ctx, cacheKey); ok {
+			s.successes.Inc()
+			return &Screenshot{Path: image.Path, URL: image.URL}, nil
+		}
+	}
+
 	u := url.URL{}
 	u.Path = path.Join("d-solo", dashboard.UID, dashboard.Slug)
 	p := u.Query()
@@ -126,6 +152,7 @@ func (s *HeadlessScreenshotService) Take(ctx context.Context, opts ScreenshotOpt
 		Width:  opts.Width,
 		Height: opts.Height,
 		Theme:  opts.Theme,
+		Panel:  findPanel(dashboard.Data, opts.PanelID),
 	}
 
 	result, err := s.rs.Render(ctx, rendering.RenderPNG, renderOpts, nil)
@@ -136,9 +163,34 @@ func (s *HeadlessScreenshotService) Take(ctx context.Context, opts ScreenshotOpt
 
 	s.successes.Inc()
 	screenshot := Screenshot{Path: result.FilePath}
+	if s.cache != nil {
+		screenshot.URL = s.cache.Set(ctx, cacheKey, result.FilePath).URL
+	}
 	return &screenshot, nil
 }
 
+// findPanel returns the panel of the dashboard, including the panels of the collapsed rows, or nil when the dashboard
+// has no such panel
+func findPanel(data *simplejson.Json, panelID int64) *rendering.PanelInfo {
+	if data == nil {
+		return nil
+	}
+	for _, item := range data.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(item)
+		if panel.Get("id").MustInt64() == panelID {
+			return &rendering.PanelInfo{
+				Type:    panel.Get("type").MustString(),
+				Title:   panel.Get("title").MustString(),
+				Options: panel.Get("options").MustMap(),
+			}
+		}
+		if info := findPanel(panel, panelID); info != nil {
+			return info
+		}
+	}
+	return nil
+}
+
 func (s *HeadlessScreenshotService) instrumentError(err error) {
 	if errors.Is(err, dashboards.ErrDashboardNotFound) {
 		s.failures.With(prometheus.Labels{
@@ -3,7 +3,10 @@ package screenshot
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
@@ -11,9 +14,13 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/rendering/rendercache"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -24,7 +31,7 @@ func TestHeadlessScreenshotService(t *testing.T) {
 	d := dashboards.FakeDashboardService{}
 	r := rendering.NewMockService(c)
 	cfg := setting.NewCfg()
-	s := NewHeadlessScreenshotService(cfg, &d, r, prometheus.NewRegistry())
+	s := NewHeadlessScreenshotService(cfg, &d, r, nil, prometheus.NewRegistry())
 
 	// a non-existent dashboard should return error
 	d.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).Return(nil, dashboards.ErrDashboardNotFound).Once()
@@ -79,6 +86,40 @@ func TestHeadlessScreenshotService(t *testing.T) {
 	assert.Nil(t, screenshot)
 }
 
+func TestHeadlessScreenshotService_RenderCache(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	d := dashboards.FakeDashboardService{}
+	r := rendering.NewMockService(c)
+	cfg := setting.NewCfg()
+	cfg.ImagesDir = t.TempDir()
+	cfg.RendererCacheTTL = time.Hour
+	cache := rendercache.ProvideService(cfg, bus.ProvideBus(tracing.InitializeTracerForTest()), routing.NewRouteRegister())
+	s := NewHeadlessScreenshotService(cfg, &d, r, cache, prometheus.NewRegistry())
+
+	dashboard := &dashboards.Dashboard{ID: 1, UID: "foo", Slug: "bar", OrgID: 2, Version: 1}
+	d.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).Return(dashboard, nil)
+
+	path := filepath.Join(cfg.ImagesDir, "panel.png")
+	require.NoError(t, os.WriteFile(path, []byte("png"), 0600))
+
+	ctx := context.Background()
+	opts := ScreenshotOptions{OrgID: 2, DashboardUID: "foo", PanelID: 4}
+
+	// the panel is rendered once for the dashboard version
+	r.EXPECT().
+		Render(ctx, rendering.RenderPNG, gomock.Any(), nil).
+		Return(&rendering.RenderResult{FilePath: path}, nil).
+		Times(1)
+	for i := 0; i < 2; i++ {
+		screenshot, err := s.Take(ctx, opts)
+		require.NoError(t, err)
+		assert.Equal(t, path, screenshot.Path)
+		assert.Contains(t, screenshot.URL, "/api/rendered-images/panel.png?")
+	}
+}
+
 func TestNoOpScreenshotService(t *testing.T) {
 	s := NoOpScreenshotService{}
 	screenshot, err := s.Take(context.Background(), ScreenshotOptions{})
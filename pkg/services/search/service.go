@@ -79,16 +79,29 @@ func (s *SearchService) SearchHandler(ctx context.Context, query *Query) (model.
 		return nil, err
 	}
 
-	// No starred dashboards will be found
-	if query.IsStarred && len(staredDashIDs.UserStars) == 0 {
+	folderStars, err := s.starService.GetResourcesByUser(ctx, &star.GetUserResourceStarsQuery{
+		UserID: query.SignedInUser.UserID,
+		OrgID:  query.SignedInUser.GetOrgID(),
+		Kind:   star.KindFolder,
+	})
+	if err != nil {
+		return nil, err
+	}
+	staredFolderUIDs := folderStars.UIDs(star.KindFolder)
+
+	// No starred dashboards or folders will be found
+	if query.IsStarred && len(staredDashIDs.UserStars) == 0 && len(staredFolderUIDs) == 0 {
 		return model.HitList{}, nil
 	}
 
-	// filter by starred dashboard IDs when starred dashboards are requested and no UID or ID filters are specified to improve query performance
+	// filter by starred dashboard and folder UIDs when starred dashboards are requested and no UID or ID filters are specified to improve query performance
 	if query.IsStarred && len(query.DashboardIds) == 0 && len(query.DashboardUIDs) == 0 {
 		for uid := range staredDashIDs.UserStars {
 			query.DashboardUIDs = append(query.DashboardUIDs, uid)
 		}
+		for uid := range staredFolderUIDs {
+			query.DashboardUIDs = append(query.DashboardUIDs, uid)
+		}
 	}
 
 	metrics.MFolderIDsServiceCount.WithLabelValues(metrics.Search).Inc()
@@ -120,9 +133,11 @@ func (s *SearchService) SearchHandler(ctx context.Context, query *Query) (model.
 		hits = sortedHits(hits)
 	}
 
-	// set starred dashboards
+	// set starred dashboards and folders
 	for _, dashboard := range hits {
-		if _, ok := staredDashIDs.UserStars[dashboard.UID]; ok {
+		if dashboard.Type == model.DashHitFolder {
+			dashboard.IsStarred = staredFolderUIDs[dashboard.UID]
+		} else if _, ok := staredDashIDs.UserStars[dashboard.UID]; ok {
 			dashboard.IsStarred = true
 		}
 	}
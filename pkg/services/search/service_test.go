@@ -32,6 +32,7 @@ func TestSearch_SortedResults(t *testing.T) {
 	}, nil)
 	us.ExpectedSignedInUser = &user.SignedInUser{IsGrafanaAdmin: true}
 	ss.ExpectedUserStars = &star.GetUserStarsResult{UserStars: map[string]bool{"test2": true, "test7": true}}
+	ss.ExpectedUserResourceStars = &star.GetUserResourceStarsResult{Stars: []*star.ResourceStar{{Kind: star.KindFolder, ResourceUID: "test5"}}}
 	svc := &SearchService{
 		sqlstore:         db,
 		starService:      ss,
@@ -56,6 +57,11 @@ func TestSearch_SortedResults(t *testing.T) {
 	assert.Equal(t, "bbAAa", hits[3].Title)
 	assert.Equal(t, "CCAA", hits[4].Title)
 
+	// Assert starred dashboards and folders are marked.
+	assert.True(t, hits[0].IsStarred)
+	assert.True(t, hits[1].IsStarred)
+	assert.False(t, hits[2].IsStarred)
+
 	// Assert tags are sorted.
 	assert.Equal(t, "AA", hits[3].Tags[0])
 	assert.Equal(t, "BB", hits[3].Tags[1])
@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/searchusers/sortopts"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 type Service interface {
@@ -49,7 +50,7 @@ func (s *OSSService) SearchUsers(c *contextmodel.ReqContext) response.Response {
 		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to fetch users", err)
 	}
 
-	return response.JSON(http.StatusOK, result.Users)
+	return withNextLink(c, response.JSON(http.StatusOK, result.Users), result)
 }
 
 // swagger:route GET /users/search users searchUsersWithPaging
@@ -68,14 +69,23 @@ func (s *OSSService) SearchUsersWithPaging(c *contextmodel.ReqContext) response.
 		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to fetch users", err)
 	}
 
-	return response.JSON(http.StatusOK, result)
+	return withNextLink(c, response.JSON(http.StatusOK, result), result)
+}
+
+// withNextLink links the response to the next page of users
+func withNextLink(c *contextmodel.ReqContext, rsp *response.NormalResponse, result *user.SearchUserQueryResult) response.Response {
+	if result.Next != nil {
+		rsp.SetHeader("Link", pagination.LinkHeader(c.Req.URL, result.Next, result.PerPage))
+	}
+	return rsp
 }
 
 func (s *OSSService) SearchUser(c *contextmodel.ReqContext) (*user.SearchUserQueryResult, error) {
-	perPage := c.QueryInt("perpage")
-	if perPage <= 0 {
-		perPage = 1000
+	paging, err := pagination.FromQuery(c.Req.URL.Query(), pagination.MaxLimit, pagination.MaxLimit)
+	if err != nil {
+		return nil, err
 	}
+	perPage := paging.Limit
 	page := c.QueryInt("page")
 
 	if page < 1 {
@@ -103,6 +113,7 @@ func (s *OSSService) SearchUser(c *contextmodel.ReqContext) (*user.SearchUserQue
 		Filters:      filters,
 		Page:         page,
 		Limit:        perPage,
+		After:        paging.After,
 		SortOpts:     sortOpts,
 	}
 	res, err := s.userService.Search(c.Req.Context(), query)
@@ -0,0 +1,108 @@
+package secretaccess
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+var ErrInvalidSearch = errutil.BadRequest("secretaccess.invalid-search")
+
+// Kinds of secrets
+const (
+	KindDataSource   = "datasource"
+	KindContactPoint = "contactpoint"
+)
+
+type Service interface {
+	// Record counts a decryption of the secret by the identity of the context, or by the service
+	// when there is none. It doesn't wait for the decryption to be stored.
+	Record(ctx context.Context, access Access)
+	// RegisterSource registers the function listing the secrets of the kind, so that the secrets
+	// which are never decrypted are reported as unused too.
+	RegisterSource(kind string, source Source)
+	SearchEvents(ctx context.Context, query *SearchQuery) (*SearchResult, error)
+	// GetUnusedSecrets returns the secrets that weren't decrypted within the period, the ones
+	// decrypted least recently first.
+	GetUnusedSecrets(ctx context.Context, query *UnusedQuery) ([]*UnusedSecret, error)
+}
+
+// Source lists the secrets of all organizations
+type Source func(ctx context.Context) ([]*Secret, error)
+
+type Secret struct {
+	OrgID int64  `json:"orgId"`
+	Kind  string `json:"kind"`
+	// UID is the uid of the data source or of the contact point integration
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+}
+
+type Access struct {
+	Secret
+	// Service is the component decrypting the secret, for example datasources or alertmanager
+	Service string
+}
+
+// Event is the decryptions of a secret by the same actor and service, aggregated over a minute
+type Event struct {
+	ID         int64  `json:"id"`
+	OrgID      int64  `json:"orgId"`
+	Kind       string `json:"kind"`
+	SecretUID  string `json:"secretUid"`
+	SecretName string `json:"secretName"`
+	Service    string `json:"service"`
+	// ActorID is the typed id of the identity, for example user:1, empty for background decryptions
+	ActorID     string    `json:"actorId,omitempty"`
+	ActorLogin  string    `json:"actorLogin,omitempty"`
+	Count       int64     `json:"count"`
+	FirstAccess time.Time `json:"firstAccess"`
+	LastAccess  time.Time `json:"lastAccess"`
+}
+
+type SearchQuery struct {
+	From      time.Time
+	To        time.Time
+	OrgID     int64
+	Kind      string
+	SecretUID string
+	Actor     string
+	Service   string
+	Page      int
+	Limit     int
+}
+
+type SearchResult struct {
+	TotalCount int64    `json:"totalCount"`
+	Events     []*Event `json:"events"`
+	Page       int      `json:"page"`
+	PerPage    int      `json:"perPage"`
+}
+
+type UnusedQuery struct {
+	OrgID int64
+	Kind  string
+	// Period defaults to the configured unused period
+	Period time.Duration
+}
+
+type UnusedSecret struct {
+	Secret
+	// LastUsedAt is nil when the secret wasn't decrypted since it's tracked
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	// TrackedSince is when the secret was first listed or decrypted
+	TrackedSince time.Time `json:"trackedSince"`
+}
+
+// IsUnused returns true when a secret was last used, or tracked since if never used, before the period
+func IsUnused(lastUsedAt *time.Time, trackedSince time.Time, period time.Duration, now time.Time) bool {
+	if period <= 0 {
+		return false
+	}
+	last := trackedSince
+	if lastUsedAt != nil {
+		last = *lastUsedAt
+	}
+	return now.Sub(last) > period
+}
@@ -0,0 +1,166 @@
+package secretaccessimpl
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/secretaccess"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+
+	router.Group("/api/admin/secrets", func(secretsRoute routing.RouteRegister) {
+		secretsRoute.Get("/access", authorize(ac.EvalPermission(ac.ActionServerAuditRead)), routing.Wrap(s.SearchSecretAccessEvents))
+		secretsRoute.Get("/unused", authorize(ac.EvalPermission(ac.ActionServerAuditRead)), routing.Wrap(s.GetUnusedSecretsHandler))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /admin/secrets/access admin searchSecretAccessEvents
+//
+// Search the decryptions of secrets.
+//
+// Returns the decryptions of data source and contact point secrets matching the filters, the most recent first.
+// Decryptions of a secret by the same actor and service are aggregated over a minute.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: searchSecretAccessEventsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) SearchSecretAccessEvents(c *contextmodel.ReqContext) response.Response {
+	query := &secretaccess.SearchQuery{
+		OrgID:     c.QueryInt64("orgId"),
+		Kind:      c.Query("kind"),
+		SecretUID: c.Query("secretUid"),
+		Actor:     c.Query("actor"),
+		Service:   c.Query("service"),
+		Page:      c.QueryIntWithDefault("page", 1),
+		Limit:     c.QueryIntWithDefault("perpage", defaultPerPage),
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.UnixMilli(from)
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.UnixMilli(to)
+	}
+
+	result, err := s.SearchEvents(c.Req.Context(), query)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to search secret access events", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route GET /admin/secrets/unused admin getUnusedSecrets
+//
+// Get the unused secrets.
+//
+// Returns the data source and contact point secrets that weren't decrypted for the number of days, or the configured
+// unused period, as candidates for cleanup. The secrets decrypted least recently are returned first.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getUnusedSecretsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) GetUnusedSecretsHandler(c *contextmodel.ReqContext) response.Response {
+	query := &secretaccess.UnusedQuery{
+		OrgID: c.QueryInt64("orgId"),
+		Kind:  c.Query("kind"),
+	}
+	if days := c.QueryInt("days"); days != 0 {
+		if days < 0 {
+			return response.Error(http.StatusBadRequest, "days must be positive", nil)
+		}
+		query.Period = time.Duration(days) * 24 * time.Hour
+	}
+
+	secrets, err := s.GetUnusedSecrets(c.Req.Context(), query)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get unused secrets", err)
+	}
+
+	return response.JSON(http.StatusOK, secrets)
+}
+
+// swagger:parameters searchSecretAccessEvents
+type SearchSecretAccessEventsParams struct {
+	// Only return decryptions at or after this time, in epoch milliseconds.
+	// in:query
+	// required:false
+	From int64 `json:"from"`
+	// Only return decryptions at or before this time, in epoch milliseconds.
+	// in:query
+	// required:false
+	To int64 `json:"to"`
+	// in:query
+	// required:false
+	OrgID int64 `json:"orgId"`
+	// in:query
+	// required:false
+	// enum: datasource,contactpoint
+	Kind string `json:"kind"`
+	// Uid of the data source or of the contact point integration.
+	// in:query
+	// required:false
+	SecretUID string `json:"secretUid"`
+	// Login or id of the user, for example user:1, that decrypted the secrets.
+	// in:query
+	// required:false
+	Actor string `json:"actor"`
+	// Component that decrypted the secrets, for example datasources or alertmanager.
+	// in:query
+	// required:false
+	Service string `json:"service"`
+	// in:query
+	// required:false
+	// default:1
+	Page int `json:"page"`
+	// Limit the number of events per page, at most 1000.
+	// in:query
+	// required:false
+	// default:100
+	PerPage int `json:"perpage"`
+}
+
+// swagger:response searchSecretAccessEventsResponse
+type SearchSecretAccessEventsResponse struct {
+	// in:body
+	Body secretaccess.SearchResult `json:"body"`
+}
+
+// swagger:parameters getUnusedSecrets
+type GetUnusedSecretsParams struct {
+	// in:query
+	// required:false
+	OrgID int64 `json:"orgId"`
+	// in:query
+	// required:false
+	// enum: datasource,contactpoint
+	Kind string `json:"kind"`
+	// Number of days without decryption after which a secret is unused, defaults to the secret_unused_period setting.
+	// in:query
+	// required:false
+	Days int `json:"days"`
+}
+
+// swagger:response getUnusedSecretsResponse
+type GetUnusedSecretsResponse struct {
+	// in:body
+	Body []*secretaccess.UnusedSecret `json:"body"`
+}
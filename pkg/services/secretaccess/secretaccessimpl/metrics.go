@@ -0,0 +1,38 @@
+package secretaccessimpl
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "grafana"
+	metricsSubSystem = "secret_access"
+)
+
+type metrics struct {
+	recorded *prometheus.CounterVec
+	dropped  prometheus.Counter
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		recorded: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "decryptions_total",
+			Help:      "Number of decryptions of data source and contact point secrets",
+		}, []string{"kind", "service"}),
+		dropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "dropped_total",
+			Help:      "Number of secret decryptions not recorded because the buffer was full",
+		}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.recorded, m.dropped)
+	}
+
+	return m
+}
@@ -0,0 +1,313 @@
+package secretaccessimpl
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/secretaccess"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var _ secretaccess.Service = (*Service)(nil)
+
+const (
+	flushInterval       = time.Minute
+	maintenanceInterval = time.Hour
+	// maxPendingEntries bounds the decryptions kept in memory between two flushes
+	maxPendingEntries = 10000
+	// maxNameLength is the size of the name columns
+	maxNameLength = 190
+)
+
+func ProvideService(
+	cfg *setting.Cfg, sqlStore db.DB, router routing.RouteRegister, accessControl ac.AccessControl,
+	reg prometheus.Registerer, tracer trace.Tracer,
+) *Service {
+	s := &Service{
+		cfg:     cfg,
+		store:   &xormStore{db: sqlStore},
+		sources: map[string]secretaccess.Source{},
+		pending: map[accessKey]*accessCount{},
+		metrics: newMetrics(reg),
+		log:     log.New("secretaccess"),
+		tracer:  tracer,
+		now:     time.Now,
+	}
+
+	if cfg.SecretAccessTrackingEnabled {
+		s.registerRoutes(router, accessControl)
+	}
+
+	return s
+}
+
+// Service records which users and services decrypt the data source and contact point secrets.
+// Decryptions are aggregated in memory and written by Run so recording them never blocks a request.
+type Service struct {
+	cfg        *setting.Cfg
+	store      store
+	sourcesMtx sync.RWMutex
+	sources    map[string]secretaccess.Source
+	mu         sync.Mutex
+	pending    map[accessKey]*accessCount
+	metrics    *metrics
+	log        log.Logger
+	tracer     trace.Tracer
+	now        func() time.Time
+}
+
+type accessKey struct {
+	orgID      int64
+	kind       string
+	uid        string
+	name       string
+	service    string
+	actorID    string
+	actorLogin string
+}
+
+type accessCount struct {
+	count       int64
+	firstAccess time.Time
+	lastAccess  time.Time
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.SecretAccessTrackingEnabled
+}
+
+func (s *Service) Record(ctx context.Context, access secretaccess.Access) {
+	if !s.cfg.SecretAccessTrackingEnabled {
+		return
+	}
+	s.metrics.recorded.WithLabelValues(access.Kind, access.Service).Inc()
+
+	key := accessKey{
+		orgID:   access.OrgID,
+		kind:    access.Kind,
+		uid:     truncate(access.UID),
+		name:    truncate(access.Name),
+		service: access.Service,
+	}
+	if requester, err := identity.GetRequester(ctx); err == nil {
+		key.actorID = truncate(requester.GetID())
+		key.actorLogin = truncate(requester.GetLogin())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if c, ok := s.pending[key]; ok {
+		c.count++
+		c.lastAccess = now
+		return
+	}
+	if len(s.pending) >= maxPendingEntries {
+		s.metrics.dropped.Inc()
+		s.log.FromContext(ctx).Debug("Secret access buffer is full, dropping decryption", "kind", key.kind, "uid", key.uid)
+		return
+	}
+	s.pending[key] = &accessCount{count: 1, firstAccess: now, lastAccess: now}
+}
+
+func (s *Service) RegisterSource(kind string, source secretaccess.Source) {
+	s.sourcesMtx.Lock()
+	defer s.sourcesMtx.Unlock()
+	s.sources[kind] = source
+}
+
+func (s *Service) SearchEvents(ctx context.Context, query *secretaccess.SearchQuery) (*secretaccess.SearchResult, error) {
+	ctx, span := s.tracer.Start(ctx, "secretaccess.SearchEvents")
+	defer span.End()
+
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		return nil, secretaccess.ErrInvalidSearch.Errorf("to must not be before from")
+	}
+	return s.store.Search(ctx, query)
+}
+
+func (s *Service) GetUnusedSecrets(ctx context.Context, query *secretaccess.UnusedQuery) ([]*secretaccess.UnusedSecret, error) {
+	ctx, span := s.tracer.Start(ctx, "secretaccess.GetUnusedSecrets")
+	defer span.End()
+
+	period := query.Period
+	if period == 0 {
+		period = s.cfg.SecretUnusedPeriod
+	}
+	if period < 0 {
+		return nil, secretaccess.ErrInvalidSearch.Errorf("period must not be negative")
+	}
+
+	sources := s.getSources()
+	if query.Kind != "" {
+		source, ok := sources[query.Kind]
+		if !ok {
+			return nil, secretaccess.ErrInvalidSearch.Errorf("unknown kind of secrets %q", query.Kind)
+		}
+		sources = map[string]secretaccess.Source{query.Kind: source}
+	}
+
+	now := s.now()
+	unused := make([]*secretaccess.UnusedSecret, 0)
+	for kind, source := range sources {
+		secrets, err := source(ctx)
+		if err != nil {
+			return nil, err
+		}
+		usage, err := s.store.ListUsage(ctx, kind)
+		if err != nil {
+			return nil, err
+		}
+		usageByUID := make(map[int64]map[string]*secretUsage)
+		for _, u := range usage {
+			if usageByUID[u.OrgID] == nil {
+				usageByUID[u.OrgID] = map[string]*secretUsage{}
+			}
+			usageByUID[u.OrgID][u.SecretUID] = u
+		}
+
+		for _, secret := range secrets {
+			if query.OrgID != 0 && secret.OrgID != query.OrgID {
+				continue
+			}
+			// secrets created since the last sync aren't tracked yet, so they can't be unused
+			u, ok := usageByUID[secret.OrgID][secret.UID]
+			if !ok || !secretaccess.IsUnused(u.LastUsedAt, u.TrackedSince, period, now) {
+				continue
+			}
+			// the same secret can be listed twice, it's only tracked once
+			delete(usageByUID[secret.OrgID], secret.UID)
+
+			unusedSecret := &secretaccess.UnusedSecret{Secret: *secret, LastUsedAt: u.LastUsedAt, TrackedSince: u.TrackedSince}
+			unusedSecret.Kind = kind
+			unused = append(unused, unusedSecret)
+		}
+	}
+
+	sort.SliceStable(unused, func(i, j int) bool {
+		return lastSeen(unused[i]).Before(lastSeen(unused[j]))
+	})
+	return unused, nil
+}
+
+func lastSeen(secret *secretaccess.UnusedSecret) time.Time {
+	if secret.LastUsedAt != nil {
+		return *secret.LastUsedAt
+	}
+	return secret.TrackedSince
+}
+
+func (s *Service) getSources() map[string]secretaccess.Source {
+	s.sourcesMtx.RLock()
+	defer s.sourcesMtx.RUnlock()
+
+	sources := make(map[string]secretaccess.Source, len(s.sources))
+	for kind, source := range s.sources {
+		sources[kind] = source
+	}
+	return sources
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	flush := time.NewTicker(flushInterval)
+	defer flush.Stop()
+	maintenance := time.NewTicker(maintenanceInterval)
+	defer maintenance.Stop()
+
+	s.deleteExpired(ctx)
+	s.syncSources(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			// the pending decryptions are written with a fresh context, the one of the server is already canceled
+			flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			s.flush(flushCtx)
+			cancel()
+			return ctx.Err()
+		case <-flush.C:
+			s.flush(ctx)
+		case <-maintenance.C:
+			s.deleteExpired(ctx)
+			s.syncSources(ctx)
+		}
+	}
+}
+
+func (s *Service) flush(ctx context.Context) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = map[accessKey]*accessCount{}
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	events := make([]*secretAccessEvent, 0, len(pending))
+	for key, c := range pending {
+		events = append(events, &secretAccessEvent{
+			OrgID:       key.orgID,
+			Kind:        key.kind,
+			SecretUID:   key.uid,
+			SecretName:  key.name,
+			Service:     key.service,
+			ActorID:     key.actorID,
+			ActorLogin:  key.actorLogin,
+			Count:       c.count,
+			FirstAccess: c.firstAccess,
+			LastAccess:  c.lastAccess,
+		})
+	}
+	if err := s.store.Add(ctx, events); err != nil {
+		s.log.Error("Failed to store secret decryptions", "count", len(events), "error", err)
+	}
+}
+
+// syncSources tracks the secrets listed by the sources, so the ones never decrypted are reported as unused
+// once the unused period has elapsed
+func (s *Service) syncSources(ctx context.Context) {
+	for kind, source := range s.getSources() {
+		secrets, err := source(ctx)
+		if err != nil {
+			s.log.Error("Failed to list secrets", "kind", kind, "error", err)
+			continue
+		}
+		if err := s.store.Sync(ctx, kind, secrets, s.now()); err != nil {
+			s.log.Error("Failed to track secrets", "kind", kind, "error", err)
+		}
+	}
+}
+
+func (s *Service) deleteExpired(ctx context.Context) {
+	if s.cfg.SecretAccessRetention <= 0 {
+		return
+	}
+
+	deleted, err := s.store.DeleteBefore(ctx, s.now().Add(-s.cfg.SecretAccessRetention))
+	if err != nil {
+		s.log.Error("Failed to delete expired secret access events", "error", err)
+		return
+	}
+	if deleted > 0 {
+		s.log.Debug("Deleted expired secret access events", "count", deleted)
+	}
+}
+
+func truncate(value string) string {
+	if len(value) > maxNameLength {
+		return value[:maxNameLength]
+	}
+	return value
+}
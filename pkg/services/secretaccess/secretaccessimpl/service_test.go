@@ -0,0 +1,180 @@
+package secretaccessimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	claims "github.com/grafana/authlib/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/secretaccess"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func TestService_Record(t *testing.T) {
+	prometheus := secretaccess.Access{
+		Secret:  secretaccess.Secret{OrgID: 1, Kind: secretaccess.KindDataSource, UID: "prom", Name: "Prometheus"},
+		Service: "datasources",
+	}
+
+	t.Run("should aggregate decryptions by actor", func(t *testing.T) {
+		s, store := setupTestService(t)
+		ctx := identity.WithRequester(context.Background(), &identity.StaticRequester{Type: claims.TypeUser, UserID: 2, Login: "editor"})
+
+		s.Record(ctx, prometheus)
+		s.Record(ctx, prometheus)
+		s.Record(context.Background(), prometheus)
+		s.flush(ctx)
+
+		require.Len(t, store.events, 2)
+		for _, event := range store.events {
+			if event.ActorLogin == "editor" {
+				assert.Equal(t, "user:2", event.ActorID)
+				assert.Equal(t, int64(2), event.Count)
+			} else {
+				assert.Empty(t, event.ActorID)
+				assert.Equal(t, int64(1), event.Count)
+			}
+			assert.Equal(t, "prom", event.SecretUID)
+			assert.Equal(t, "datasources", event.Service)
+		}
+
+		s.flush(ctx)
+		assert.Len(t, store.events, 2, "flushed decryptions are not written twice")
+	})
+
+	t.Run("should not record decryptions when disabled", func(t *testing.T) {
+		s, store := setupTestService(t)
+		s.cfg.SecretAccessTrackingEnabled = false
+
+		s.Record(context.Background(), prometheus)
+		s.flush(context.Background())
+		assert.Empty(t, store.events)
+	})
+
+	t.Run("should drop decryptions when the buffer is full", func(t *testing.T) {
+		s, _ := setupTestService(t)
+		for i := 0; i < maxPendingEntries; i++ {
+			s.pending[accessKey{orgID: int64(i)}] = &accessCount{count: 1}
+		}
+
+		s.Record(context.Background(), prometheus)
+		assert.Len(t, s.pending, maxPendingEntries)
+		assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.dropped))
+	})
+}
+
+func TestService_GetUnusedSecrets(t *testing.T) {
+	ctx := context.Background()
+	recently := now.Add(-time.Hour)
+	longAgo := now.Add(-100 * 24 * time.Hour)
+
+	setup := func(t *testing.T) *Service {
+		s, store := setupTestService(t)
+		s.RegisterSource(secretaccess.KindDataSource, func(ctx context.Context) ([]*secretaccess.Secret, error) {
+			return []*secretaccess.Secret{
+				{OrgID: 1, Kind: secretaccess.KindDataSource, UID: "used", Name: "Used"},
+				{OrgID: 1, Kind: secretaccess.KindDataSource, UID: "stale", Name: "Stale"},
+				{OrgID: 1, Kind: secretaccess.KindDataSource, UID: "never", Name: "Never"},
+				{OrgID: 1, Kind: secretaccess.KindDataSource, UID: "new", Name: "New"},
+				{OrgID: 2, Kind: secretaccess.KindDataSource, UID: "other", Name: "Other"},
+				{OrgID: 1, Kind: secretaccess.KindDataSource, UID: "untracked", Name: "Untracked"},
+			}, nil
+		})
+		store.usage = []*secretUsage{
+			{OrgID: 1, Kind: secretaccess.KindDataSource, SecretUID: "used", TrackedSince: longAgo, LastUsedAt: &recently},
+			{OrgID: 1, Kind: secretaccess.KindDataSource, SecretUID: "stale", TrackedSince: longAgo.Add(-2 * time.Hour), LastUsedAt: &longAgo},
+			{OrgID: 1, Kind: secretaccess.KindDataSource, SecretUID: "never", TrackedSince: longAgo.Add(-time.Hour)},
+			{OrgID: 1, Kind: secretaccess.KindDataSource, SecretUID: "new", TrackedSince: recently},
+			{OrgID: 2, Kind: secretaccess.KindDataSource, SecretUID: "other", TrackedSince: longAgo},
+		}
+		return s
+	}
+
+	t.Run("should return the secrets not decrypted within the unused period", func(t *testing.T) {
+		s := setup(t)
+
+		unused, err := s.GetUnusedSecrets(ctx, &secretaccess.UnusedQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, unused, 2)
+		assert.Equal(t, "never", unused[0].UID)
+		assert.Nil(t, unused[0].LastUsedAt)
+		assert.Equal(t, "stale", unused[1].UID)
+		assert.Equal(t, longAgo, *unused[1].LastUsedAt)
+	})
+
+	t.Run("should use the period of the query", func(t *testing.T) {
+		s := setup(t)
+
+		unused, err := s.GetUnusedSecrets(ctx, &secretaccess.UnusedQuery{OrgID: 1, Period: 30 * time.Minute})
+		require.NoError(t, err)
+		assert.Len(t, unused, 4)
+	})
+
+	t.Run("should fail for an unknown kind", func(t *testing.T) {
+		s := setup(t)
+
+		_, err := s.GetUnusedSecrets(ctx, &secretaccess.UnusedQuery{Kind: "unknown"})
+		assert.ErrorIs(t, err, secretaccess.ErrInvalidSearch)
+	})
+}
+
+func setupTestService(t *testing.T) (*Service, *fakeStore) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.SecretAccessTrackingEnabled = true
+	cfg.SecretUnusedPeriod = 90 * 24 * time.Hour
+
+	store := &fakeStore{}
+	return &Service{
+		cfg:     cfg,
+		store:   store,
+		sources: map[string]secretaccess.Source{},
+		pending: map[accessKey]*accessCount{},
+		metrics: newMetrics(nil),
+		log:     log.NewNopLogger(),
+		tracer:  tracing.InitializeTracerForTest(),
+		now:     func() time.Time { return now },
+	}, store
+}
+
+type fakeStore struct {
+	events []*secretAccessEvent
+	usage  []*secretUsage
+}
+
+func (f *fakeStore) Add(_ context.Context, events []*secretAccessEvent) error {
+	f.events = append(f.events, events...)
+	return nil
+}
+
+func (f *fakeStore) Search(_ context.Context, query *secretaccess.SearchQuery) (*secretaccess.SearchResult, error) {
+	return &secretaccess.SearchResult{}, nil
+}
+
+func (f *fakeStore) ListUsage(_ context.Context, kind string) ([]*secretUsage, error) {
+	var usage []*secretUsage
+	for _, u := range f.usage {
+		if u.Kind == kind {
+			usage = append(usage, u)
+		}
+	}
+	return usage, nil
+}
+
+func (f *fakeStore) Sync(_ context.Context, kind string, secrets []*secretaccess.Secret, now time.Time) error {
+	return nil
+}
+
+func (f *fakeStore) DeleteBefore(_ context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
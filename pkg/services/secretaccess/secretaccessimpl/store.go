@@ -0,0 +1,256 @@
+package secretaccessimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/secretaccess"
+	"github.com/grafana/grafana/pkg/util/xorm"
+)
+
+const (
+	defaultPerPage = 100
+	maxPerPage     = 1000
+)
+
+type secretAccessEvent struct {
+	ID          int64     `xorm:"pk autoincr 'id'"`
+	OrgID       int64     `xorm:"org_id"`
+	Kind        string    `xorm:"kind"`
+	SecretUID   string    `xorm:"secret_uid"`
+	SecretName  string    `xorm:"secret_name"`
+	Service     string    `xorm:"service"`
+	ActorID     string    `xorm:"actor_id"`
+	ActorLogin  string    `xorm:"actor_login"`
+	Count       int64     `xorm:"access_count"`
+	FirstAccess time.Time `xorm:"first_access"`
+	LastAccess  time.Time `xorm:"last_access"`
+}
+
+func (secretAccessEvent) TableName() string {
+	return "secret_access_event"
+}
+
+func (e *secretAccessEvent) toEvent() *secretaccess.Event {
+	return &secretaccess.Event{
+		ID:          e.ID,
+		OrgID:       e.OrgID,
+		Kind:        e.Kind,
+		SecretUID:   e.SecretUID,
+		SecretName:  e.SecretName,
+		Service:     e.Service,
+		ActorID:     e.ActorID,
+		ActorLogin:  e.ActorLogin,
+		Count:       e.Count,
+		FirstAccess: e.FirstAccess,
+		LastAccess:  e.LastAccess,
+	}
+}
+
+type secretUsage struct {
+	ID           int64      `xorm:"pk autoincr 'id'"`
+	OrgID        int64      `xorm:"org_id"`
+	Kind         string     `xorm:"kind"`
+	SecretUID    string     `xorm:"secret_uid"`
+	SecretName   string     `xorm:"secret_name"`
+	TrackedSince time.Time  `xorm:"tracked_since"`
+	LastUsedAt   *time.Time `xorm:"last_used_at"`
+}
+
+func (secretUsage) TableName() string {
+	return "secret_usage"
+}
+
+type store interface {
+	// Add stores the events and updates when their secrets were last used
+	Add(ctx context.Context, events []*secretAccessEvent) error
+	Search(ctx context.Context, query *secretaccess.SearchQuery) (*secretaccess.SearchResult, error)
+	// ListUsage returns when the tracked secrets of the kind were last used
+	ListUsage(ctx context.Context, kind string) ([]*secretUsage, error)
+	// Sync starts tracking the secrets of the kind which aren't yet, and stops tracking the ones
+	// which don't exist anymore
+	Sync(ctx context.Context, kind string, secrets []*secretaccess.Secret, now time.Time) error
+	// DeleteBefore deletes the events last seen before the time and returns how many were deleted
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (s *xormStore) Add(ctx context.Context, events []*secretAccessEvent) error {
+	for _, event := range events {
+		err := s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+			if _, err := sess.Insert(event); err != nil {
+				return err
+			}
+			return s.markUsed(sess, event)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *xormStore) markUsed(sess *db.Session, event *secretAccessEvent) error {
+	res, err := sess.Exec(
+		"UPDATE secret_usage SET secret_name = ?, last_used_at = ? WHERE org_id = ? AND kind = ? AND secret_uid = ? AND (last_used_at IS NULL OR last_used_at < ?)",
+		event.SecretName, event.LastAccess, event.OrgID, event.Kind, event.SecretUID, event.LastAccess,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected > 0 {
+		return err
+	}
+
+	exists, err := sess.Exist(&secretUsage{OrgID: event.OrgID, Kind: event.Kind, SecretUID: event.SecretUID})
+	if err != nil || exists {
+		return err
+	}
+	lastUsedAt := event.LastAccess
+	_, err = sess.Insert(&secretUsage{
+		OrgID:        event.OrgID,
+		Kind:         event.Kind,
+		SecretUID:    event.SecretUID,
+		SecretName:   event.SecretName,
+		TrackedSince: event.FirstAccess,
+		LastUsedAt:   &lastUsedAt,
+	})
+	return err
+}
+
+func (s *xormStore) Search(ctx context.Context, query *secretaccess.SearchQuery) (*secretaccess.SearchResult, error) {
+	perPage := query.Limit
+	if perPage <= 0 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+	page := query.Page
+	if page <= 0 {
+		page = 1
+	}
+
+	result := &secretaccess.SearchResult{Events: make([]*secretaccess.Event, 0), Page: page, PerPage: perPage}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		filter := func() *xorm.Session {
+			q := sess.Table("secret_access_event")
+			if !query.From.IsZero() {
+				q = q.Where("last_access >= ?", query.From)
+			}
+			if !query.To.IsZero() {
+				q = q.Where("first_access <= ?", query.To)
+			}
+			if query.OrgID != 0 {
+				q = q.Where("org_id = ?", query.OrgID)
+			}
+			if query.Kind != "" {
+				q = q.Where("kind = ?", query.Kind)
+			}
+			if query.SecretUID != "" {
+				q = q.Where("secret_uid = ?", query.SecretUID)
+			}
+			if query.Actor != "" {
+				q = q.Where("(actor_login = ? OR actor_id = ?)", query.Actor, query.Actor)
+			}
+			if query.Service != "" {
+				q = q.Where("service = ?", query.Service)
+			}
+			return q
+		}
+
+		count, err := filter().Count(&secretAccessEvent{})
+		if err != nil {
+			return err
+		}
+		result.TotalCount = count
+
+		entries := make([]*secretAccessEvent, 0)
+		if err := filter().Desc("last_access", "id").Limit(perPage, (page-1)*perPage).Find(&entries); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			result.Events = append(result.Events, entry.toEvent())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *xormStore) ListUsage(ctx context.Context, kind string) ([]*secretUsage, error) {
+	usage := make([]*secretUsage, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("kind = ?", kind).Find(&usage)
+	})
+	return usage, err
+}
+
+func (s *xormStore) Sync(ctx context.Context, kind string, secrets []*secretaccess.Secret, now time.Time) error {
+	type secretKey struct {
+		orgID int64
+		uid   string
+	}
+
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		tracked := make([]*secretUsage, 0)
+		if err := sess.Where("kind = ?", kind).Find(&tracked); err != nil {
+			return err
+		}
+		existing := make(map[secretKey]*secretUsage, len(tracked))
+		for _, usage := range tracked {
+			existing[secretKey{orgID: usage.OrgID, uid: usage.SecretUID}] = usage
+		}
+
+		// a source can list the same secret twice, for example an integration shared by two receivers
+		seen := make(map[secretKey]bool, len(secrets))
+		for _, secret := range secrets {
+			key := secretKey{orgID: secret.OrgID, uid: secret.UID}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if _, ok := existing[key]; ok {
+				continue
+			}
+			if _, err := sess.Insert(&secretUsage{
+				OrgID:        secret.OrgID,
+				Kind:         kind,
+				SecretUID:    secret.UID,
+				SecretName:   truncate(secret.Name),
+				TrackedSince: now,
+			}); err != nil {
+				return err
+			}
+		}
+
+		for key, usage := range existing {
+			if seen[key] {
+				continue
+			}
+			if _, err := sess.ID(usage.ID).Delete(&secretUsage{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *xormStore) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM secret_access_event WHERE last_access < ?", before)
+		if err != nil {
+			return err
+		}
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	return deleted, err
+}
@@ -0,0 +1,129 @@
+package secretaccessimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/secretaccess"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	store := &xormStore{db: db.InitTestDB(t)}
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	usageByUID := func(t *testing.T) map[string]*secretUsage {
+		usage, err := store.ListUsage(ctx, secretaccess.KindDataSource)
+		require.NoError(t, err)
+		byUID := make(map[string]*secretUsage, len(usage))
+		for _, u := range usage {
+			byUID[u.SecretUID] = u
+		}
+		return byUID
+	}
+
+	t.Run("should start tracking the listed secrets", func(t *testing.T) {
+		require.NoError(t, store.Sync(ctx, secretaccess.KindDataSource, []*secretaccess.Secret{
+			{OrgID: 1, UID: "prometheus", Name: "Prometheus"},
+			{OrgID: 1, UID: "loki", Name: "Loki"},
+			{OrgID: 1, UID: "prometheus", Name: "Prometheus"},
+		}, start))
+
+		usage := usageByUID(t)
+		require.Len(t, usage, 2, "a secret listed twice is tracked once")
+		assert.True(t, start.Equal(usage["loki"].TrackedSince))
+		assert.Nil(t, usage["loki"].LastUsedAt)
+	})
+
+	t.Run("should add the events and mark their secrets as used", func(t *testing.T) {
+		require.NoError(t, store.Add(ctx, []*secretAccessEvent{
+			{OrgID: 1, Kind: secretaccess.KindDataSource, SecretUID: "prometheus", SecretName: "Prometheus", Service: "query",
+				ActorID: "user:1", ActorLogin: "admin", Count: 3, FirstAccess: start, LastAccess: start.Add(time.Minute)},
+			{OrgID: 1, Kind: secretaccess.KindDataSource, SecretUID: "tempo", SecretName: "Tempo", Service: "alerting",
+				ActorID: "service", Count: 1, FirstAccess: start.Add(time.Minute), LastAccess: start.Add(2 * time.Minute)},
+			{OrgID: 2, Kind: secretaccess.KindContactPoint, SecretUID: "slack", SecretName: "Slack", Service: "alerting",
+				ActorID: "service", Count: 1, FirstAccess: start, LastAccess: start},
+			{OrgID: 1, Kind: secretaccess.KindDataSource, SecretUID: "prometheus", SecretName: "Prometheus", Service: "query",
+				ActorID: "user:2", ActorLogin: "editor", Count: 1, FirstAccess: start, LastAccess: start},
+		}))
+
+		usage := usageByUID(t)
+		require.Len(t, usage, 3, "the secrets are tracked from their first use")
+		require.NotNil(t, usage["prometheus"].LastUsedAt)
+		assert.True(t, start.Add(time.Minute).Equal(*usage["prometheus"].LastUsedAt), "an older event doesn't move the last use back")
+		require.NotNil(t, usage["tempo"].LastUsedAt)
+		assert.True(t, start.Add(time.Minute).Equal(usage["tempo"].TrackedSince))
+		assert.Nil(t, usage["loki"].LastUsedAt)
+	})
+
+	t.Run("should search the events, the most recent first", func(t *testing.T) {
+		for _, tc := range []struct {
+			desc  string
+			query secretaccess.SearchQuery
+			uids  []string
+		}{
+			{"without filter", secretaccess.SearchQuery{}, []string{"tempo", "prometheus", "prometheus", "slack"}},
+			{"by org", secretaccess.SearchQuery{OrgID: 2}, []string{"slack"}},
+			{"by kind", secretaccess.SearchQuery{Kind: secretaccess.KindDataSource}, []string{"tempo", "prometheus", "prometheus"}},
+			{"by secret", secretaccess.SearchQuery{SecretUID: "prometheus"}, []string{"prometheus", "prometheus"}},
+			{"by actor login", secretaccess.SearchQuery{Actor: "editor"}, []string{"prometheus"}},
+			{"by actor id", secretaccess.SearchQuery{Actor: "service"}, []string{"tempo", "slack"}},
+			{"by service", secretaccess.SearchQuery{Service: "query"}, []string{"prometheus", "prometheus"}},
+			{"by time range", secretaccess.SearchQuery{From: start.Add(time.Minute), To: start.Add(time.Minute)}, []string{"tempo", "prometheus"}},
+		} {
+			t.Run(tc.desc, func(t *testing.T) {
+				result, err := store.Search(ctx, &tc.query)
+				require.NoError(t, err)
+				uids := make([]string, 0, len(result.Events))
+				for _, event := range result.Events {
+					uids = append(uids, event.SecretUID)
+				}
+				assert.Equal(t, tc.uids, uids)
+				assert.Equal(t, int64(len(tc.uids)), result.TotalCount)
+			})
+		}
+
+		result, err := store.Search(ctx, &secretaccess.SearchQuery{Page: 2, Limit: 3})
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), result.TotalCount)
+		require.Len(t, result.Events, 1)
+		assert.Equal(t, "slack", result.Events[0].SecretUID)
+	})
+
+	t.Run("should stop tracking the secrets which don't exist anymore", func(t *testing.T) {
+		require.NoError(t, store.Sync(ctx, secretaccess.KindDataSource, []*secretaccess.Secret{
+			{OrgID: 1, UID: "prometheus", Name: "Prometheus"},
+		}, start.Add(time.Hour)))
+
+		usage := usageByUID(t)
+		require.Len(t, usage, 1)
+		assert.True(t, start.Equal(usage["prometheus"].TrackedSince), "the tracked secrets are kept as they are")
+
+		contactPoints, err := store.ListUsage(ctx, secretaccess.KindContactPoint)
+		require.NoError(t, err)
+		assert.Len(t, contactPoints, 1, "the secrets of the other kinds are kept")
+	})
+
+	t.Run("should delete the events last seen before a time", func(t *testing.T) {
+		deleted, err := store.DeleteBefore(ctx, start.Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+
+		result, err := store.Search(ctx, &secretaccess.SearchQuery{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), result.TotalCount)
+	})
+}
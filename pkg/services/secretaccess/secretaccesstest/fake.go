@@ -0,0 +1,49 @@
+package secretaccesstest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/services/secretaccess"
+)
+
+type FakeService struct {
+	ExpectedSearchResult  *secretaccess.SearchResult
+	ExpectedUnusedSecrets []*secretaccess.UnusedSecret
+	ExpectedError         error
+
+	mu       sync.Mutex
+	Accesses []secretaccess.Access
+	Sources  map[string]secretaccess.Source
+}
+
+func NewFakeService() *FakeService {
+	return &FakeService{Sources: map[string]secretaccess.Source{}}
+}
+
+func (f *FakeService) Record(ctx context.Context, access secretaccess.Access) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Accesses = append(f.Accesses, access)
+}
+
+func (f *FakeService) RegisterSource(kind string, source secretaccess.Source) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Sources[kind] = source
+}
+
+func (f *FakeService) SearchEvents(ctx context.Context, query *secretaccess.SearchQuery) (*secretaccess.SearchResult, error) {
+	return f.ExpectedSearchResult, f.ExpectedError
+}
+
+func (f *FakeService) GetUnusedSecrets(ctx context.Context, query *secretaccess.UnusedQuery) ([]*secretaccess.UnusedSecret, error) {
+	return f.ExpectedUnusedSecrets, f.ExpectedError
+}
+
+// RecordedAccesses returns a copy of the accesses recorded so far
+func (f *FakeService) RecordedAccesses() []secretaccess.Access {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]secretaccess.Access(nil), f.Accesses...)
+}
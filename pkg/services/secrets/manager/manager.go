@@ -100,6 +100,12 @@ func ProvideSecretsService(
 		s.log.Warn("Changing encryption provider requires enabling envelope encryption feature")
 	}
 
+	// Only the data keys of the current provider are encrypted with new key versions,
+	// so the rotations of the other providers' keys are ignored.
+	if notifier, ok := s.providers[currentProviderID].(secrets.KeyRotationNotifier); ok {
+		notifier.OnKeyRotation(s.ReEncryptDataKeys)
+	}
+
 	s.log.Info("Envelope encryption state", "enabled", enabled, "current provider", currentProviderID)
 
 	s.registerUsageMetrics()
@@ -457,6 +463,20 @@ func (s *SecretsService) GetProviders() map[secrets.ProviderID]secrets.Provider
 	return s.providers
 }
 
+func (s *SecretsService) CurrentProviderID() secrets.ProviderID {
+	return s.currentProviderID
+}
+
+func (s *SecretsService) CheckProvidersHealth(ctx context.Context) map[secrets.ProviderID]error {
+	results := make(map[secrets.ProviderID]error)
+	for id, p := range s.providers {
+		if checked, ok := p.(secrets.HealthCheckedProvider); ok {
+			results[id] = checked.CheckHealth(ctx)
+		}
+	}
+	return results
+}
+
 func (s *SecretsService) RotateDataKeys(ctx context.Context) error {
 	s.log.Info("Data keys rotation triggered, acquiring lock...")
 
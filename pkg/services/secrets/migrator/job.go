@@ -0,0 +1,462 @@
+package migrator
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	rotationJobKey          = "job"
+	rotationBackupKeyPrefix = "backup/"
+
+	// the running jobs update their state at this interval, so that the jobs of the instances
+	// which stopped are reported as interrupted
+	rotationHeartbeatInterval = 30 * time.Second
+	rotationStaleAfter        = 4 * rotationHeartbeatInterval
+)
+
+// backupSource is the column of a table whose secrets are backed up before the rotation job re-encrypts them.
+type backupSource struct {
+	table    string
+	idColumn string
+	column   string
+	where    string
+	binary   bool
+}
+
+func (s backupSource) name() string {
+	return s.table + "." + s.column
+}
+
+// restorableRotator is implemented by the rotators whose secrets can be restored when the rotation job is
+// rolled back.
+type restorableRotator interface {
+	backupSource() backupSource
+}
+
+func (s simpleSecret) backupSource() backupSource {
+	return backupSource{table: s.tableName, idColumn: "id", column: s.columnName, binary: true}
+}
+
+func (s b64Secret) backupSource() backupSource {
+	return backupSource{table: s.tableName, idColumn: "id", column: s.columnName}
+}
+
+func (s jsonSecret) backupSource() backupSource {
+	return backupSource{table: s.tableName, idColumn: "id", column: "secure_json_data"}
+}
+
+func (s alertingSecret) backupSource() backupSource {
+	return backupSource{table: "alert_configuration", idColumn: "id", column: "alertmanager_configuration"}
+}
+
+func (s ssoSettingsSecret) backupSource() backupSource {
+	return backupSource{table: "sso_setting", idColumn: "id", column: "settings"}
+}
+
+func (p provisioningSecrets) backupSource() backupSource {
+	return backupSource{
+		table:    "resource",
+		idColumn: "guid",
+		column:   "value",
+		where:    "`group` = 'provisioning.grafana.app' AND `resource` = 'repositories'",
+	}
+}
+
+func sourceName(r SecretsRotator) string {
+	if restorable, ok := r.(restorableRotator); ok {
+		return restorable.backupSource().name()
+	}
+	return fmt.Sprintf("%T", r)
+}
+
+func (m *SecretsMigrator) StartRotationJob(ctx context.Context) (*secrets.RotationJob, error) {
+	m.jobMtx.Lock()
+	defer m.jobMtx.Unlock()
+
+	current, err := m.loadJob(ctx)
+	if err != nil && !errors.Is(err, secrets.ErrRotationJobNotFound) {
+		return nil, err
+	}
+	if current != nil && !current.State.Finished() {
+		return nil, fmt.Errorf("%w: the %s job must be rolled back or cut over first", secrets.ErrRotationJobInvalidState, current.State)
+	}
+
+	if err := m.initProvidersIfNeeded(); err != nil {
+		return nil, err
+	}
+	// the secrets are re-encrypted with new data keys, encrypted by the current provider
+	if err := m.secretsSrv.RotateDataKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	job := &secrets.RotationJob{
+		ID:        util.GenerateShortUID(),
+		State:     secrets.RotationJobRunning,
+		Provider:  m.secretsSrv.CurrentProviderID(),
+		StartedAt: now,
+		UpdatedAt: now,
+		Total:     len(m.rotators),
+	}
+	for _, r := range m.rotators {
+		_, restorable := r.(restorableRotator)
+		job.Sources = append(job.Sources, secrets.RotationJobSource{
+			Name:       sourceName(r),
+			State:      secrets.RotationSourcePending,
+			Restorable: restorable,
+		})
+	}
+	if err := m.saveJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Secrets rotation job started", "id", job.ID, "provider", job.Provider)
+	m.job = job
+	go m.runJob(job, m.reEncryptSources)
+	return cloneJob(job), nil
+}
+
+func (m *SecretsMigrator) ResumeRotationJob(ctx context.Context) (*secrets.RotationJob, error) {
+	m.jobMtx.Lock()
+	defer m.jobMtx.Unlock()
+
+	job, err := m.loadJob(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if job.State != secrets.RotationJobFailed && job.State != secrets.RotationJobInterrupted {
+		return nil, fmt.Errorf("%w: only the failed or interrupted jobs can be resumed", secrets.ErrRotationJobInvalidState)
+	}
+	if err := m.initProvidersIfNeeded(); err != nil {
+		return nil, err
+	}
+
+	job.State, job.Error, job.FinishedAt = secrets.RotationJobRunning, "", nil
+	job.UpdatedAt = time.Now().UTC()
+	if err := m.saveJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Secrets rotation job resumed", "id", job.ID, "completed", job.Completed, "total", job.Total)
+	m.job = job
+	go m.runJob(job, m.reEncryptSources)
+	return cloneJob(job), nil
+}
+
+func (m *SecretsMigrator) RollBackRotationJob(ctx context.Context) (*secrets.RotationJob, error) {
+	m.jobMtx.Lock()
+	defer m.jobMtx.Unlock()
+
+	job, err := m.loadJob(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch job.State {
+	case secrets.RotationJobCompleted, secrets.RotationJobFailed, secrets.RotationJobInterrupted, secrets.RotationJobRollbackFailed:
+	default:
+		return nil, fmt.Errorf("%w: the %s job can't be rolled back", secrets.ErrRotationJobInvalidState, job.State)
+	}
+
+	job.State, job.Error, job.FinishedAt = secrets.RotationJobRollingBack, "", nil
+	job.UpdatedAt = time.Now().UTC()
+	if err := m.saveJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Secrets rotation job rollback started", "id", job.ID)
+	m.job = job
+	go m.runJob(job, m.restoreSources)
+	return cloneJob(job), nil
+}
+
+func (m *SecretsMigrator) CutOverRotationJob(ctx context.Context) (*secrets.RotationJob, error) {
+	m.jobMtx.Lock()
+	defer m.jobMtx.Unlock()
+
+	job, err := m.loadJob(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if job.State != secrets.RotationJobCompleted {
+		return nil, fmt.Errorf("%w: only the completed jobs can be cut over", secrets.ErrRotationJobInvalidState)
+	}
+
+	if err := m.deleteBackups(ctx, job); err != nil {
+		return nil, err
+	}
+	job.State = secrets.RotationJobCutOver
+	job.UpdatedAt = time.Now().UTC()
+	if err := m.saveJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Secrets rotation job cut over", "id", job.ID)
+	return job, nil
+}
+
+func (m *SecretsMigrator) GetRotationJob(ctx context.Context) (*secrets.RotationJob, error) {
+	m.jobMtx.Lock()
+	defer m.jobMtx.Unlock()
+	return m.loadJob(ctx)
+}
+
+// loadJob returns the job run by this instance, or the latest job stored. The running jobs which weren't
+// updated recently were interrupted, since their instance stopped.
+func (m *SecretsMigrator) loadJob(ctx context.Context) (*secrets.RotationJob, error) {
+	if m.job != nil {
+		return cloneJob(m.job), nil
+	}
+
+	value, exists, err := m.kvStore.Get(ctx, rotationJobKey)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, secrets.ErrRotationJobNotFound
+	}
+	var job secrets.RotationJob
+	if err := json.Unmarshal([]byte(value), &job); err != nil {
+		return nil, fmt.Errorf("failed to decode the secrets rotation job: %w", err)
+	}
+
+	if time.Since(job.UpdatedAt) > rotationStaleAfter {
+		switch job.State {
+		case secrets.RotationJobRunning:
+			job.State = secrets.RotationJobInterrupted
+		case secrets.RotationJobRollingBack:
+			job.State, job.Error = secrets.RotationJobRollbackFailed, "the rollback was interrupted"
+		}
+	}
+	return &job, nil
+}
+
+func (m *SecretsMigrator) saveJob(ctx context.Context, job *secrets.RotationJob) error {
+	value, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return m.kvStore.Set(ctx, rotationJobKey, string(value))
+}
+
+func cloneJob(job *secrets.RotationJob) *secrets.RotationJob {
+	clone := *job
+	clone.Sources = slices.Clone(job.Sources)
+	return &clone
+}
+
+// runJob runs the step of the job in the background, while updating it regularly to report that it's still
+// running. The job is stored after each source, so that it can be resumed from there.
+func (m *SecretsMigrator) runJob(job *secrets.RotationJob, step func(ctx context.Context, job *secrets.RotationJob)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		ticker := time.NewTicker(rotationHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.updateJob(ctx, job, func() {})
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	step(ctx, job)
+
+	m.jobMtx.Lock()
+	defer m.jobMtx.Unlock()
+	m.job = nil
+}
+
+func (m *SecretsMigrator) updateJob(ctx context.Context, job *secrets.RotationJob, update func()) {
+	m.jobMtx.Lock()
+	defer m.jobMtx.Unlock()
+
+	update()
+	job.UpdatedAt = time.Now().UTC()
+	job.Completed = 0
+	for _, s := range job.Sources {
+		if s.State == secrets.RotationSourceCompleted {
+			job.Completed++
+		}
+	}
+	if err := m.saveJob(ctx, job); err != nil {
+		logger.Warn("Could not save the secrets rotation job", "id", job.ID, "error", err)
+	}
+}
+
+func (m *SecretsMigrator) reEncryptSources(ctx context.Context, job *secrets.RotationJob) {
+	sources := make(map[string]int, len(job.Sources))
+	for i, s := range job.Sources {
+		sources[s.Name] = i
+	}
+
+	var anyFailure bool
+	for _, r := range m.rotators {
+		i, ok := sources[sourceName(r)]
+		if !ok || job.Sources[i].State == secrets.RotationSourceCompleted {
+			continue
+		}
+
+		state := secrets.RotationSourceCompleted
+		if restorable, ok := r.(restorableRotator); ok {
+			if err := m.backup(ctx, restorable.backupSource()); err != nil {
+				logger.Warn("Could not back up secrets before re-encrypting them", "source", job.Sources[i].Name, "error", err)
+				state = secrets.RotationSourceFailed
+			}
+		}
+		if state == secrets.RotationSourceCompleted && !r.ReEncrypt(ctx, m.secretsSrv, m.sqlStore) {
+			state = secrets.RotationSourceFailed
+		}
+		if state == secrets.RotationSourceFailed {
+			anyFailure = true
+		}
+		m.updateJob(ctx, job, func() { job.Sources[i].State = state })
+	}
+
+	m.updateJob(ctx, job, func() {
+		now := time.Now().UTC()
+		job.FinishedAt = &now
+		job.State = secrets.RotationJobCompleted
+		if anyFailure {
+			job.State = secrets.RotationJobFailed
+			job.Error = "some secrets couldn't be re-encrypted, check the server logs and resume the job"
+		}
+	})
+	logger.Info("Secrets rotation job finished", "id", job.ID, "state", job.State)
+}
+
+func (m *SecretsMigrator) restoreSources(ctx context.Context, job *secrets.RotationJob) {
+	rotators := make(map[string]restorableRotator, len(m.rotators))
+	for _, r := range m.rotators {
+		if restorable, ok := r.(restorableRotator); ok {
+			rotators[sourceName(r)] = restorable
+		}
+	}
+
+	var anyFailure bool
+	// the sources are restored in the reverse order of their re-encryption
+	for i := len(job.Sources) - 1; i >= 0; i-- {
+		source := job.Sources[i]
+		if source.State == secrets.RotationSourcePending || source.State == secrets.RotationSourceRestored {
+			continue
+		}
+		r, ok := rotators[source.Name]
+		if !ok {
+			logger.Warn("Secrets can't be restored, they're kept re-encrypted", "source", source.Name)
+			continue
+		}
+		if err := m.restore(ctx, r.backupSource()); err != nil {
+			logger.Warn("Could not restore secrets", "source", source.Name, "error", err)
+			anyFailure = true
+			continue
+		}
+		m.updateJob(ctx, job, func() { job.Sources[i].State = secrets.RotationSourceRestored })
+	}
+
+	if !anyFailure {
+		if err := m.deleteBackups(ctx, job); err != nil {
+			logger.Warn("Could not delete the secrets backups", "error", err)
+			anyFailure = true
+		}
+	}
+
+	m.updateJob(ctx, job, func() {
+		now := time.Now().UTC()
+		job.FinishedAt = &now
+		job.State = secrets.RotationJobRolledBack
+		if anyFailure {
+			job.State = secrets.RotationJobRollbackFailed
+			job.Error = "some secrets couldn't be restored, check the server logs and roll the job back again"
+		}
+	})
+	logger.Info("Secrets rotation job rollback finished", "id", job.ID, "state", job.State)
+}
+
+// backup stores the secrets of the source, unless they were already backed up by a previous run of the job,
+// in which case some of them may have been re-encrypted since.
+func (m *SecretsMigrator) backup(ctx context.Context, source backupSource) error {
+	key := rotationBackupKeyPrefix + source.name()
+	if _, exists, err := m.kvStore.Get(ctx, key); err != nil || exists {
+		return err
+	}
+
+	query := fmt.Sprintf("SELECT %s AS row_id, %s AS secret FROM %s", source.idColumn, source.column, source.table)
+	if source.where != "" {
+		query += " WHERE " + source.where
+	}
+	var rows []map[string][]byte
+	if err := m.sqlStore.WithDbSession(ctx, func(sess *db.Session) (err error) {
+		rows, err = sess.Query(query)
+		return err
+	}); err != nil {
+		if source.where != "" {
+			// the table of the optional sources may not exist, like the resource table of unified storage
+			logger.Debug("Could not read secrets to back up", "source", source.name(), "error", err)
+			return nil
+		}
+		return err
+	}
+
+	values := make(map[string]string, len(rows))
+	for _, row := range rows {
+		if len(row["secret"]) > 0 {
+			values[string(row["row_id"])] = base64.StdEncoding.EncodeToString(row["secret"])
+		}
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	return m.kvStore.Set(ctx, key, string(data))
+}
+
+func (m *SecretsMigrator) restore(ctx context.Context, source backupSource) error {
+	value, exists, err := m.kvStore.Get(ctx, rotationBackupKeyPrefix+source.name())
+	if err != nil || !exists {
+		return err
+	}
+	var values map[string]string
+	if err := json.Unmarshal([]byte(value), &values); err != nil {
+		return fmt.Errorf("failed to decode the backup: %w", err)
+	}
+
+	updateSQL := fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s = ?", source.table, source.column, source.idColumn)
+	return m.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for id, encoded := range values {
+			secret, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return err
+			}
+			var arg any = string(secret)
+			if source.binary {
+				arg = secret
+			}
+			if _, err := sess.Exec(updateSQL, arg, id); err != nil {
+				return fmt.Errorf("failed to restore secret %s: %w", id, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (m *SecretsMigrator) deleteBackups(ctx context.Context, job *secrets.RotationJob) error {
+	for _, s := range job.Sources {
+		if err := m.kvStore.Del(ctx, rotationBackupKeyPrefix+s.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package migrator
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/secrets/database"
+	"github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationRotationJob(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	sqlStore := db.InitTestDB(t)
+	secretsStore := database.ProvideSecretsStore(sqlStore)
+	secretsSrv := manager.SetupTestService(t, secretsStore)
+	m := &SecretsMigrator{
+		secretsSrv: secretsSrv,
+		sqlStore:   sqlStore,
+		features:   featuremgmt.WithFeatures(),
+		rotators: []SecretsRotator{
+			b64Secret{simpleSecret: simpleSecret{tableName: "secrets", columnName: "value"}, hasUpdatedColumn: true, encoding: base64.RawStdEncoding},
+		},
+		kvStore: kvstore.WithNamespace(kvstore.ProvideService(sqlStore), 0, "secrets.rotation"),
+	}
+
+	encrypted, err := secretsSrv.Encrypt(ctx, []byte("password"), secrets.WithoutScope())
+	require.NoError(t, err)
+	original := base64.RawStdEncoding.EncodeToString(encrypted)
+	require.NoError(t, sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("INSERT INTO secrets (org_id, namespace, type, value, created, updated) VALUES (1, 'ds', 'datasource', ?, ?, ?)",
+			original, nowInUTC(), nowInUTC())
+		return err
+	}))
+
+	readSecret := func(t *testing.T) (string, string) {
+		t.Helper()
+		var value string
+		require.NoError(t, sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.SQL("SELECT value FROM secrets WHERE namespace = 'ds'").Get(&value)
+			return err
+		}))
+		decoded, err := base64.RawStdEncoding.DecodeString(value)
+		require.NoError(t, err)
+		decrypted, err := secretsSrv.Decrypt(ctx, decoded)
+		require.NoError(t, err)
+		return value, string(decrypted)
+	}
+	waitFor := func(t *testing.T, state secrets.RotationJobState) *secrets.RotationJob {
+		t.Helper()
+		var job *secrets.RotationJob
+		require.Eventually(t, func() bool {
+			current, err := m.GetRotationJob(ctx)
+			if err != nil {
+				return false
+			}
+			job = current
+			return job.State == state
+		}, 10*time.Second, 10*time.Millisecond)
+		return job
+	}
+
+	_, err = m.GetRotationJob(ctx)
+	require.ErrorIs(t, err, secrets.ErrRotationJobNotFound)
+
+	t.Run("re-encrypts the secrets and rolls them back", func(t *testing.T) {
+		job, err := m.StartRotationJob(ctx)
+		require.NoError(t, err)
+		require.Equal(t, secrets.RotationJobRunning, job.State)
+		require.Equal(t, 1, job.Total)
+
+		job = waitFor(t, secrets.RotationJobCompleted)
+		require.Equal(t, 1, job.Completed)
+		require.Equal(t, []secrets.RotationJobSource{{Name: "secrets.value", State: secrets.RotationSourceCompleted, Restorable: true}}, job.Sources)
+		value, decrypted := readSecret(t)
+		require.NotEqual(t, original, value)
+		require.Equal(t, "password", decrypted)
+
+		_, err = m.StartRotationJob(ctx)
+		require.ErrorIs(t, err, secrets.ErrRotationJobInvalidState)
+		_, err = m.ResumeRotationJob(ctx)
+		require.ErrorIs(t, err, secrets.ErrRotationJobInvalidState)
+
+		_, err = m.RollBackRotationJob(ctx)
+		require.NoError(t, err)
+		job = waitFor(t, secrets.RotationJobRolledBack)
+		require.Equal(t, secrets.RotationSourceRestored, job.Sources[0].State)
+		value, decrypted = readSecret(t)
+		require.Equal(t, original, value)
+		require.Equal(t, "password", decrypted)
+
+		_, exists, err := m.kvStore.Get(ctx, rotationBackupKeyPrefix+"secrets.value")
+		require.NoError(t, err)
+		require.False(t, exists)
+	})
+
+	t.Run("can't be rolled back once cut over", func(t *testing.T) {
+		_, err := m.StartRotationJob(ctx)
+		require.NoError(t, err)
+		waitFor(t, secrets.RotationJobCompleted)
+
+		job, err := m.CutOverRotationJob(ctx)
+		require.NoError(t, err)
+		require.Equal(t, secrets.RotationJobCutOver, job.State)
+
+		_, err = m.RollBackRotationJob(ctx)
+		require.ErrorIs(t, err, secrets.ErrRotationJobInvalidState)
+		value, decrypted := readSecret(t)
+		require.NotEqual(t, original, value)
+		require.Equal(t, "password", decrypted)
+	})
+
+	t.Run("resumes an interrupted job", func(t *testing.T) {
+		job := &secrets.RotationJob{
+			ID:        "interrupted",
+			State:     secrets.RotationJobRunning,
+			StartedAt: time.Now().Add(-time.Hour),
+			UpdatedAt: time.Now().Add(-time.Hour),
+			Total:     1,
+			Sources:   []secrets.RotationJobSource{{Name: "secrets.value", State: secrets.RotationSourcePending, Restorable: true}},
+		}
+		data, err := json.Marshal(job)
+		require.NoError(t, err)
+		require.NoError(t, m.kvStore.Set(ctx, rotationJobKey, string(data)))
+
+		job, err = m.GetRotationJob(ctx)
+		require.NoError(t, err)
+		require.Equal(t, secrets.RotationJobInterrupted, job.State)
+
+		_, err = m.ResumeRotationJob(ctx)
+		require.NoError(t, err)
+		job = waitFor(t, secrets.RotationJobCompleted)
+		require.Equal(t, "interrupted", job.ID)
+		require.Equal(t, 1, job.Completed)
+	})
+}
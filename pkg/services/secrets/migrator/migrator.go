@@ -3,12 +3,15 @@ package migrator
 import (
 	"context"
 	"encoding/base64"
+	"sync"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/secrets/manager"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -27,6 +30,11 @@ type SecretsMigrator struct {
 	settings      setting.Provider
 	features      featuremgmt.FeatureToggles
 	rotators      []SecretsRotator
+	kvStore       *kvstore.NamespacedKVStore
+
+	jobMtx sync.Mutex
+	// job is the rotation job run by this instance, if any
+	job *secrets.RotationJob
 }
 
 func ProvideSecretsMigrator(
@@ -35,6 +43,7 @@ func ProvideSecretsMigrator(
 	sqlStore db.DB,
 	settings setting.Provider,
 	features featuremgmt.FeatureToggles,
+	kvStore kvstore.KVStore,
 ) *SecretsMigrator {
 	rotators := []SecretsRotator{
 		simpleSecret{tableName: "dashboard_snapshot", columnName: "dashboard_encrypted"},
@@ -63,6 +72,7 @@ func ProvideSecretsMigrator(
 		settings:      settings,
 		features:      features,
 		rotators:      rotators,
+		kvStore:       kvstore.WithNamespace(kvStore, 0, "secrets.rotation"),
 	}
 }
 
@@ -0,0 +1,71 @@
+package secrets
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrRotationJobNotFound     = errors.New("no secrets rotation job was found")
+	ErrRotationJobInvalidState = errors.New("the secrets rotation job can't be changed in its current state")
+)
+
+// RotationJobState is the state of a secrets rotation job.
+type RotationJobState string
+
+const (
+	// RotationJobRunning is the state of the job while it re-encrypts the secrets.
+	RotationJobRunning RotationJobState = "running"
+	// RotationJobInterrupted is the state of a running job whose instance stopped before it finished.
+	RotationJobInterrupted RotationJobState = "interrupted"
+	// RotationJobFailed is the state of a job that failed to re-encrypt some of the secrets.
+	RotationJobFailed RotationJobState = "failed"
+	// RotationJobCompleted is the state of a job that re-encrypted all the secrets, which can be rolled back
+	// until it's cut over.
+	RotationJobCompleted RotationJobState = "completed"
+	// RotationJobRollingBack is the state of the job while it restores the secrets as they were before it.
+	RotationJobRollingBack RotationJobState = "rolling_back"
+	// RotationJobRollbackFailed is the state of a job that failed to restore some of the secrets.
+	RotationJobRollbackFailed RotationJobState = "rollback_failed"
+	// RotationJobRolledBack is the state of a job whose secrets were restored as they were before it.
+	RotationJobRolledBack RotationJobState = "rolled_back"
+	// RotationJobCutOver is the state of a completed job whose backups were deleted.
+	RotationJobCutOver RotationJobState = "cut_over"
+)
+
+// Finished returns whether a new job can be started after a job in this state.
+func (s RotationJobState) Finished() bool {
+	return s == RotationJobRolledBack || s == RotationJobCutOver
+}
+
+// RotationJob re-encrypts all the secrets with new data keys, encrypted by the current provider.
+type RotationJob struct {
+	ID         string              `json:"id"`
+	State      RotationJobState    `json:"state"`
+	Provider   ProviderID          `json:"provider"`
+	StartedAt  time.Time           `json:"startedAt"`
+	UpdatedAt  time.Time           `json:"updatedAt"`
+	FinishedAt *time.Time          `json:"finishedAt,omitempty"`
+	Total      int                 `json:"total"`
+	Completed  int                 `json:"completed"`
+	Sources    []RotationJobSource `json:"sources"`
+	Error      string              `json:"error,omitempty"`
+}
+
+// RotationSourceState is the state of the re-encryption of a source of secrets.
+type RotationSourceState string
+
+const (
+	RotationSourcePending   RotationSourceState = "pending"
+	RotationSourceCompleted RotationSourceState = "completed"
+	RotationSourceFailed    RotationSourceState = "failed"
+	RotationSourceRestored  RotationSourceState = "restored"
+)
+
+// RotationJobSource is a source of secrets, like a column of a table. The secrets of the restorable
+// sources are backed up before they're re-encrypted, to roll the job back.
+type RotationJobSource struct {
+	Name       string              `json:"name"`
+	State      RotationSourceState `json:"state"`
+	Restorable bool                `json:"restorable"`
+}
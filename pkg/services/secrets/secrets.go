@@ -73,6 +73,24 @@ type BackgroundProvider interface {
 	Run(ctx context.Context) error
 }
 
+// HealthCheckedProvider should be implemented for a provider that depends on an external service, like a remote
+// key management service, so that its availability is reported by the health endpoint.
+type HealthCheckedProvider interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// KeyRotationNotifier should be implemented for a provider whose key can be rotated outside of Grafana. The given
+// function is called when a new key version is detected, to re-encrypt the data keys with it.
+type KeyRotationNotifier interface {
+	OnKeyRotation(fn func(ctx context.Context) error)
+}
+
+// ProvidersHealthChecker is implemented by the secrets services that can check the health of their providers.
+type ProvidersHealthChecker interface {
+	// CheckProvidersHealth returns the result of the health check of each HealthCheckedProvider.
+	CheckProvidersHealth(ctx context.Context) map[ProviderID]error
+}
+
 // Migrator is responsible for secrets migrations like re-encrypting or rolling back secrets.
 type Migrator interface {
 	// ReEncryptSecrets decrypts and re-encrypts the secrets with most recent
@@ -85,4 +103,19 @@ type Migrator interface {
 	// does not stop, but returns false as the first return (success or not)
 	// at the end of the process.
 	RollBackSecrets(ctx context.Context) (bool, error)
+	// StartRotationJob rotates the data keys, and re-encrypts the secrets with the new ones
+	// in the background. A new job can only be started once the previous one is rolled back
+	// or cut over.
+	StartRotationJob(ctx context.Context) (*RotationJob, error)
+	// ResumeRotationJob re-encrypts the secrets of the interrupted or failed job which weren't
+	// re-encrypted yet.
+	ResumeRotationJob(ctx context.Context) (*RotationJob, error)
+	// RollBackRotationJob restores the secrets re-encrypted by the job as they were before it,
+	// in the background.
+	RollBackRotationJob(ctx context.Context) (*RotationJob, error)
+	// CutOverRotationJob deletes the backups of the completed job, after which it can't be
+	// rolled back anymore.
+	CutOverRotationJob(ctx context.Context) (*RotationJob, error)
+	// GetRotationJob returns the latest job, or ErrRotationJobNotFound.
+	GetRotationJob(ctx context.Context) (*RotationJob, error)
 }
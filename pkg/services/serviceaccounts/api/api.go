@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/pagination"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -59,12 +60,15 @@ func (api *ServiceAccountsAPI) RegisterAPIEndpoints() {
 	api.RouterRegister.Group("/api/serviceaccounts", func(serviceAccountsRoute routing.RouteRegister) {
 		serviceAccountsRoute.Get("/search", auth(accesscontrol.EvalPermission(serviceaccounts.ActionRead)), routing.Wrap(api.SearchOrgServiceAccountsWithPaging))
 		serviceAccountsRoute.Post("/", auth(accesscontrol.EvalPermission(serviceaccounts.ActionCreate)), routing.Wrap(api.CreateServiceAccount))
+		serviceAccountsRoute.Get("/token-policy", auth(accesscontrol.EvalPermission(serviceaccounts.ActionRead)), routing.Wrap(api.GetTokenPolicy))
+		serviceAccountsRoute.Put("/token-policy", auth(accesscontrol.EvalPermission(accesscontrol.ActionOrgsWrite)), routing.Wrap(api.UpdateTokenPolicy))
 		serviceAccountsRoute.Get("/:serviceAccountId", saUIDResolver, auth(accesscontrol.EvalPermission(serviceaccounts.ActionRead, serviceaccounts.ScopeID)), routing.Wrap(api.RetrieveServiceAccount))
 		serviceAccountsRoute.Patch("/:serviceAccountId", saUIDResolver, auth(accesscontrol.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), routing.Wrap(api.UpdateServiceAccount))
 		serviceAccountsRoute.Delete("/:serviceAccountId", saUIDResolver, auth(accesscontrol.EvalPermission(serviceaccounts.ActionDelete, serviceaccounts.ScopeID)), routing.Wrap(api.DeleteServiceAccount))
 		serviceAccountsRoute.Get("/:serviceAccountId/tokens", saUIDResolver, auth(accesscontrol.EvalPermission(serviceaccounts.ActionRead, serviceaccounts.ScopeID)), routing.Wrap(api.ListTokens))
 		serviceAccountsRoute.Post("/:serviceAccountId/tokens", saUIDResolver, auth(accesscontrol.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), routing.Wrap(api.CreateToken))
 		serviceAccountsRoute.Delete("/:serviceAccountId/tokens/:tokenId", saUIDResolver, auth(accesscontrol.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), routing.Wrap(api.DeleteToken))
+		serviceAccountsRoute.Post("/:serviceAccountId/tokens/:tokenId/rotate", saUIDResolver, auth(accesscontrol.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), routing.Wrap(api.RotateToken))
 	}, requestmeta.SetOwner(requestmeta.TeamAuth))
 }
 
@@ -251,10 +255,11 @@ func (api *ServiceAccountsAPI) DeleteServiceAccount(ctx *contextmodel.ReqContext
 // 500: internalServerError
 func (api *ServiceAccountsAPI) SearchOrgServiceAccountsWithPaging(c *contextmodel.ReqContext) response.Response {
 	ctx := c.Req.Context()
-	perPage := c.QueryInt("perpage")
-	if perPage <= 0 {
-		perPage = 1000
+	paging, err := pagination.FromQuery(c.Req.URL.Query(), pagination.MaxLimit, pagination.MaxLimit)
+	if err != nil {
+		return response.Err(err)
 	}
+	perPage := paging.Limit
 	page := c.QueryInt("page")
 	if page < 1 {
 		page = 1
@@ -278,13 +283,14 @@ func (api *ServiceAccountsAPI) SearchOrgServiceAccountsWithPaging(c *contextmode
 		Query:        c.Query("query"),
 		Page:         page,
 		Limit:        perPage,
+		After:        paging.After,
 		Filter:       filter,
 		SignedInUser: c.SignedInUser,
 		CountTokens:  true,
 	}
 	serviceAccountSearch, err := api.service.SearchOrgServiceAccounts(ctx, &q)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to get service accounts for current organization", err)
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get service accounts for current organization", err)
 	}
 
 	saIDs := map[string]bool{}
@@ -298,7 +304,11 @@ func (api *ServiceAccountsAPI) SearchOrgServiceAccountsWithPaging(c *contextmode
 		sa.AccessControl = metadata[strconv.FormatInt(sa.Id, 10)]
 	}
 
-	return response.JSON(http.StatusOK, serviceAccountSearch)
+	rsp := response.JSON(http.StatusOK, serviceAccountSearch)
+	if serviceAccountSearch.Next != nil {
+		rsp.SetHeader("Link", pagination.LinkHeader(c.Req.URL, serviceAccountSearch.Next, perPage))
+	}
+	return rsp
 }
 
 func (api *ServiceAccountsAPI) getAccessControlMetadata(c *contextmodel.ReqContext, saIDs map[string]bool) map[string]accesscontrol.Metadata {
@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/satokengen"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/tokenusage"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -36,6 +37,9 @@ type TokenDTO struct {
 	HasExpired bool `json:"hasExpired"`
 	// example: false
 	IsRevoked *bool `json:"isRevoked"`
+	// IsUnused is true when the token hasn't been used within the configured unused period
+	// example: false
+	IsUnused bool `json:"isUnused"`
 }
 
 func hasExpired(expiration *int64) bool {
@@ -78,6 +82,7 @@ func (api *ServiceAccountsAPI) ListTokens(ctx *contextmodel.ReqContext) response
 		return response.Error(http.StatusInternalServerError, "Internal server error", err)
 	}
 
+	now := time.Now()
 	result := make([]TokenDTO, len(saTokens))
 	for i, t := range saTokens {
 		var (
@@ -104,6 +109,7 @@ func (api *ServiceAccountsAPI) ListTokens(ctx *contextmodel.ReqContext) response
 			HasExpired:             isExpired,
 			LastUsedAt:             token.LastUsedAt,
 			IsRevoked:              token.IsRevoked,
+			IsUnused:               tokenusage.IsUnused(token.LastUsedAt, token.Created, api.cfg.SATokenUnusedPeriod, now),
 		}
 	}
 
@@ -147,21 +153,8 @@ func (api *ServiceAccountsAPI) CreateToken(c *contextmodel.ReqContext) response.
 	// Force affected service account to be the one referenced in the URL
 	cmd.OrgId = c.GetOrgID()
 
-	if api.cfg.ApiKeyMaxSecondsToLive != -1 {
-		if cmd.SecondsToLive == 0 {
-			return response.Error(http.StatusBadRequest, "Number of seconds before expiration should be set", nil)
-		}
-		if cmd.SecondsToLive > api.cfg.ApiKeyMaxSecondsToLive {
-			return response.Error(http.StatusBadRequest, "Number of seconds before expiration is greater than the global limit", nil)
-		}
-	}
-
-	if api.cfg.SATokenExpirationDayLimit > 0 {
-		dayExpireLimit := time.Now().Add(time.Duration(api.cfg.SATokenExpirationDayLimit) * time.Hour * 24).Truncate(24 * time.Hour)
-		expirationDate := time.Now().Add(time.Duration(cmd.SecondsToLive) * time.Second).Truncate(24 * time.Hour)
-		if expirationDate.After(dayExpireLimit) {
-			return response.Respond(http.StatusBadRequest, "The expiration date input exceeds the limit for service account access tokens expiration date")
-		}
+	if resp := api.checkTokenLifetime(c, cmd.SecondsToLive); resp != nil {
+		return resp
 	}
 
 	newKeyInfo, err := satokengen.New(ServiceID)
@@ -185,6 +178,37 @@ func (api *ServiceAccountsAPI) CreateToken(c *contextmodel.ReqContext) response.
 	return response.JSON(http.StatusOK, result)
 }
 
+// checkTokenLifetime returns an error response when a token lifetime exceeds
+// the server limits or the token policy of the organization
+func (api *ServiceAccountsAPI) checkTokenLifetime(c *contextmodel.ReqContext, secondsToLive int64) response.Response {
+	if api.cfg.ApiKeyMaxSecondsToLive != -1 {
+		if secondsToLive == 0 {
+			return response.Error(http.StatusBadRequest, "Number of seconds before expiration should be set", nil)
+		}
+		if secondsToLive > api.cfg.ApiKeyMaxSecondsToLive {
+			return response.Error(http.StatusBadRequest, "Number of seconds before expiration is greater than the global limit", nil)
+		}
+	}
+
+	if api.cfg.SATokenExpirationDayLimit > 0 {
+		dayExpireLimit := time.Now().Add(time.Duration(api.cfg.SATokenExpirationDayLimit) * time.Hour * 24).Truncate(24 * time.Hour)
+		expirationDate := time.Now().Add(time.Duration(secondsToLive) * time.Second).Truncate(24 * time.Hour)
+		if expirationDate.After(dayExpireLimit) {
+			return response.Respond(http.StatusBadRequest, "The expiration date input exceeds the limit for service account access tokens expiration date")
+		}
+	}
+
+	policy, err := api.service.GetTokenPolicy(c.Req.Context(), c.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get token policy", err)
+	}
+	if !policy.Allows(secondsToLive) {
+		return response.Err(serviceaccounts.ErrTokenLifetimeExceedsPolicy.Errorf("token lifetime of %d seconds exceeds the maximum of %d seconds", secondsToLive, policy.MaxLifetimeSeconds))
+	}
+
+	return nil
+}
+
 // swagger:route DELETE /serviceaccounts/{serviceAccountId}/tokens/{tokenId} service_accounts deleteToken
 //
 // # DeleteToken deletes service account tokens
@@ -227,6 +251,122 @@ func (api *ServiceAccountsAPI) DeleteToken(c *contextmodel.ReqContext) response.
 	return response.Success("Service account token deleted")
 }
 
+// swagger:route POST /serviceaccounts/{serviceAccountId}/tokens/{tokenId}/rotate service_accounts rotateToken
+//
+// # RotateToken replaces a service account token
+//
+// Adds a new token to the service account and lets the rotated token expire after an overlap period,
+// so that clients can switch to the new token without downtime.
+// The new token keeps the lifetime of the rotated token unless `secondsToLive` is set.
+//
+// Required permissions (See note in the [introduction](https://grafana.com/docs/grafana/latest/developers/http_api/serviceaccount/#service-account-api) for an explanation):
+// action: `serviceaccounts:write` scope: `serviceaccounts:id:1` (single service account)
+//
+// Responses:
+// 200: createTokenResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (api *ServiceAccountsAPI) RotateToken(c *contextmodel.ReqContext) response.Response {
+	saID, err := strconv.ParseInt(web.Params(c.Req)[":serviceAccountId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Service Account ID is invalid", err)
+	}
+
+	tokenID, err := strconv.ParseInt(web.Params(c.Req)[":tokenId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Token ID is invalid", err)
+	}
+
+	cmd := serviceaccounts.RotateServiceAccountTokenCommand{}
+	if err = web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "Bad request data", err)
+	}
+
+	cmd.OrgId = c.GetOrgID()
+
+	// a lifetime of 0 keeps the lifetime of the rotated token, which the service checks against the org policy
+	if cmd.SecondsToLive != 0 {
+		if resp := api.checkTokenLifetime(c, cmd.SecondsToLive); resp != nil {
+			return resp
+		}
+	}
+
+	newKeyInfo, err := satokengen.New(ServiceID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Generating service account token failed", err)
+	}
+
+	cmd.Key = newKeyInfo.HashedKey
+
+	apiKey, err := api.service.RotateServiceAccountToken(c.Req.Context(), saID, tokenID, &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to rotate service account token", err)
+	}
+
+	result := &dtos.NewApiKeyResult{
+		ID:   apiKey.ID,
+		Name: apiKey.Name,
+		Key:  newKeyInfo.ClientSecret,
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route GET /serviceaccounts/token-policy service_accounts getTokenPolicy
+//
+// # Get the service account token policy of the organization
+//
+// Required permissions (See note in the [introduction](https://grafana.com/docs/grafana/latest/developers/http_api/serviceaccount/#service-account-api) for an explanation):
+// action: `serviceaccounts:read` scope: `serviceaccounts:*`
+//
+// Responses:
+// 200: tokenPolicyResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (api *ServiceAccountsAPI) GetTokenPolicy(c *contextmodel.ReqContext) response.Response {
+	policy, err := api.service.GetTokenPolicy(c.Req.Context(), c.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get token policy", err)
+	}
+
+	return response.JSON(http.StatusOK, policy)
+}
+
+// swagger:route PUT /serviceaccounts/token-policy service_accounts updateTokenPolicy
+//
+// # Update the service account token policy of the organization
+//
+// New tokens, including replacements issued by a rotation, can not outlive the maximum lifetime of the policy.
+// Existing tokens are not affected.
+//
+// Required permissions (See note in the [introduction](https://grafana.com/docs/grafana/latest/developers/http_api/serviceaccount/#service-account-api) for an explanation):
+// action: `orgs:write`
+//
+// Responses:
+// 200: tokenPolicyResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (api *ServiceAccountsAPI) UpdateTokenPolicy(c *contextmodel.ReqContext) response.Response {
+	policy := serviceaccounts.TokenPolicy{}
+	if err := web.Bind(c.Req, &policy); err != nil {
+		return response.Error(http.StatusBadRequest, "Bad request data", err)
+	}
+
+	policy.OrgID = c.GetOrgID()
+
+	if err := api.service.UpdateTokenPolicy(c.Req.Context(), &policy); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update token policy", err)
+	}
+
+	return response.JSON(http.StatusOK, policy)
+}
+
 // swagger:parameters listTokens
 type ListTokensParams struct {
 	// in:path
@@ -249,6 +389,28 @@ type DeleteTokenParams struct {
 	ServiceAccountId int64 `json:"serviceAccountId"`
 }
 
+// swagger:parameters rotateToken
+type RotateTokenParams struct {
+	// in:path
+	TokenId int64 `json:"tokenId"`
+	// in:path
+	ServiceAccountId int64 `json:"serviceAccountId"`
+	// in:body
+	Body serviceaccounts.RotateServiceAccountTokenCommand
+}
+
+// swagger:parameters updateTokenPolicy
+type UpdateTokenPolicyParams struct {
+	// in:body
+	Body serviceaccounts.TokenPolicy
+}
+
+// swagger:response tokenPolicyResponse
+type TokenPolicyResponse struct {
+	// in:body
+	Body *serviceaccounts.TokenPolicy
+}
+
 // swagger:response listTokensResponse
 type ListTokensResponse struct {
 	// in:body
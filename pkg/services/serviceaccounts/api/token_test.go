@@ -65,6 +65,7 @@ func TestServiceAccountsAPI_CreateToken(t *testing.T) {
 		body           string
 		permissions    []accesscontrol.Permission
 		tokenTTL       int64
+		policy         *serviceaccounts.TokenPolicy
 		expectedErr    error
 		expectedAPIKey *apikey.APIKey
 		expectedCode   int
@@ -105,6 +106,15 @@ func TestServiceAccountsAPI_CreateToken(t *testing.T) {
 			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
 			expectedCode: http.StatusBadRequest,
 		},
+		{
+			desc:         "should not be able to create token for service account that outlives the org token policy",
+			id:           1,
+			body:         `{"name": "test", "secondsToLive": 7200}`,
+			tokenTTL:     -1,
+			policy:       &serviceaccounts.TokenPolicy{OrgID: 1, MaxLifetimeSeconds: 3600},
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			expectedCode: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -112,8 +122,9 @@ func TestServiceAccountsAPI_CreateToken(t *testing.T) {
 			server := setupTests(t, func(a *ServiceAccountsAPI) {
 				a.cfg.ApiKeyMaxSecondsToLive = tt.tokenTTL
 				a.service = &satests.FakeServiceAccountService{
-					ExpectedErr:    tt.expectedErr,
-					ExpectedAPIKey: tt.expectedAPIKey,
+					ExpectedErr:         tt.expectedErr,
+					ExpectedAPIKey:      tt.expectedAPIKey,
+					ExpectedTokenPolicy: tt.policy,
 				}
 			})
 			req := server.NewRequest(http.MethodPost, fmt.Sprintf("/api/serviceaccounts/%d/tokens", tt.id), strings.NewReader(tt.body))
@@ -178,3 +189,122 @@ func TestServiceAccountsAPI_DeleteToken(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceAccountsAPI_RotateToken(t *testing.T) {
+	type TestCase struct {
+		desc         string
+		saID         int64
+		body         string
+		permissions  []accesscontrol.Permission
+		policy       *serviceaccounts.TokenPolicy
+		expectedErr  error
+		expectedCode int
+	}
+
+	tests := []TestCase{
+		{
+			desc:         "should be able to rotate service account token with correct permission",
+			saID:         1,
+			body:         `{}`,
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "should not be able to rotate service account token with wrong permission",
+			saID:         2,
+			body:         `{}`,
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "should not be able to rotate an expired service account token",
+			saID:         1,
+			body:         `{}`,
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			expectedErr:  serviceaccounts.ErrTokenNotRotatable.Errorf(""),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "should not be able to rotate into a token that outlives the org token policy",
+			saID:         1,
+			body:         `{"secondsToLive": 7200}`,
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			policy:       &serviceaccounts.TokenPolicy{OrgID: 1, MaxLifetimeSeconds: 3600},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := setupTests(t, func(a *ServiceAccountsAPI) {
+				a.service = &satests.FakeServiceAccountService{
+					ExpectedErr:         tt.expectedErr,
+					ExpectedAPIKey:      &apikey.APIKey{ID: 2, Name: "rotated"},
+					ExpectedTokenPolicy: tt.policy,
+				}
+			})
+
+			req := server.NewRequest(http.MethodPost, fmt.Sprintf("/api/serviceaccounts/%d/tokens/1/rotate", tt.saID), strings.NewReader(tt.body))
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{1: accesscontrol.GroupScopesByActionContext(context.Background(), tt.permissions)}})
+			res, err := server.SendJSON(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
+func TestServiceAccountsAPI_TokenPolicy(t *testing.T) {
+	type TestCase struct {
+		desc         string
+		method       string
+		body         string
+		permissions  []accesscontrol.Permission
+		expectedCode int
+	}
+
+	tests := []TestCase{
+		{
+			desc:         "should be able to get the token policy with correct permission",
+			method:       http.MethodGet,
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionRead, Scope: serviceaccounts.ScopeAll}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "should not be able to get the token policy without permission",
+			method:       http.MethodGet,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "should be able to update the token policy with correct permission",
+			method:       http.MethodPut,
+			body:         `{"maxLifetimeSeconds": 3600}`,
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionOrgsWrite}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "should not be able to update the token policy with wrong permission",
+			method:       http.MethodPut,
+			body:         `{"maxLifetimeSeconds": 3600}`,
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: serviceaccounts.ScopeAll}},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := setupTests(t, func(a *ServiceAccountsAPI) {
+				a.service = &satests.FakeServiceAccountService{}
+			})
+
+			req := server.NewRequest(tt.method, "/api/serviceaccounts/token-policy", strings.NewReader(tt.body))
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{1: accesscontrol.GroupScopesByActionContext(context.Background(), tt.permissions)}})
+			res, err := server.SendJSON(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
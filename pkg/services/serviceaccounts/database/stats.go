@@ -35,3 +35,33 @@ func (s *ServiceAccountsStoreImpl) GetUsageMetrics(ctx context.Context) (*servic
 
 	return &sqlStats, nil
 }
+
+// GetTokenExpiryStats counts the non revoked tokens that expire before expiringBefore,
+// the tokens that have expired and the tokens that never expire
+func (s *ServiceAccountsStoreImpl) GetTokenExpiryStats(ctx context.Context, now, expiringBefore int64) (*serviceaccounts.TokenExpiryStats, error) {
+	dialect := s.sqlStore.GetDialect()
+	notRevoked := `AND (is_revoked IS NULL OR is_revoked = ` + dialect.BooleanStr(false) + `) `
+
+	sb := &db.SQLBuilder{}
+	sb.Write("SELECT ")
+	sb.Write(`(SELECT COUNT(*) FROM ` + dialect.Quote("api_key") + ` ` +
+		`WHERE service_account_id IS NOT NULL ` + notRevoked +
+		`AND expires > ? AND expires <= ?) AS expiring,`)
+	sb.Write(`(SELECT COUNT(*) FROM ` + dialect.Quote("api_key") + ` ` +
+		`WHERE service_account_id IS NOT NULL ` + notRevoked +
+		`AND expires <= ?) AS expired,`)
+	sb.Write(`(SELECT COUNT(*) FROM ` + dialect.Quote("api_key") + ` ` +
+		`WHERE service_account_id IS NOT NULL ` + notRevoked +
+		`AND expires IS NULL) AS without_expiry`)
+	sb.AddParams(now, expiringBefore, now)
+
+	var stats serviceaccounts.TokenExpiryStats
+	if err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.SQL(sb.GetSQLString(), sb.GetParams()...).Get(&stats)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 type ServiceAccountsStoreImpl struct {
@@ -153,6 +154,8 @@ func (s *ServiceAccountsStoreImpl) UpdateServiceAccount(
 
 func ServiceAccountDeletions(dialect migrator.Dialect) []string {
 	deletes := []string{
+		"DELETE FROM api_key_ip_allowlist WHERE api_key_id IN (SELECT id FROM api_key WHERE service_account_id = ?)",
+		"DELETE FROM api_key_usage WHERE api_key_id IN (SELECT id FROM api_key WHERE service_account_id = ?)",
 		"DELETE FROM api_key WHERE service_account_id = ?",
 	}
 	deletes = append(deletes, serviceAccountDeletions(dialect)...)
@@ -383,8 +386,21 @@ func (s *ServiceAccountsStoreImpl) SearchOrgServiceAccounts(ctx context.Context,
 		if len(whereConditions) > 0 {
 			sess.Where(strings.Join(whereConditions, " AND "), whereParams...)
 		}
+
+		userTable := s.sqlStore.GetDialect().Quote("user")
+		keys := []pagination.Key{{Column: userTable + ".email"}, {Column: userTable + ".login"}, {Column: "org_user.user_id"}}
+		if query.After != nil {
+			where, args, err := pagination.Where(keys, query.After)
+			if err != nil {
+				return err
+			}
+			sess.Where(where, args...)
+		}
 		if query.Limit > 0 {
-			offset := query.Limit * (query.Page - 1)
+			offset := 0
+			if query.After == nil {
+				offset = query.Limit * (query.Page - 1)
+			}
 			sess.Limit(query.Limit, offset)
 		}
 
@@ -399,10 +415,14 @@ func (s *ServiceAccountsStoreImpl) SearchOrgServiceAccounts(ctx context.Context,
 			"user.last_seen_at",
 			"user.is_disabled",
 		)
-		sess.Asc("user.email", "user.login")
+		sess.OrderBy(pagination.OrderBy(keys))
 		if err := sess.Find(&searchResult.ServiceAccounts); err != nil {
 			return err
 		}
+		searchResult.Next = pagination.Next(len(searchResult.ServiceAccounts), query.Limit, func() []any {
+			last := searchResult.ServiceAccounts[len(searchResult.ServiceAccounts)-1]
+			return []any{last.Email, last.Login, last.Id}
+		})
 
 		// stop here if we don't want to count the number of tokens per service account
 		if !query.CountTokens {
@@ -521,6 +541,7 @@ func (s *ServiceAccountsStoreImpl) CreateServiceAccountFromApikey(ctx context.Co
 func serviceAccountDeletions(dialect migrator.Dialect) []string {
 	deletes := []string{
 		"DELETE FROM star WHERE user_id = ?",
+		"DELETE FROM star_resource WHERE user_id = ?",
 		"DELETE FROM " + dialect.Quote("user") + " WHERE id = ?",
 		"DELETE FROM org_user WHERE user_id = ?",
 		"DELETE FROM preferences WHERE user_id = ?",
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+)
+
+type tokenPolicy struct {
+	ID                 int64     `xorm:"pk autoincr 'id'"`
+	OrgID              int64     `xorm:"org_id"`
+	MaxLifetimeSeconds int64     `xorm:"max_lifetime_seconds"`
+	Updated            time.Time `xorm:"updated"`
+}
+
+func (tokenPolicy) TableName() string {
+	return "service_account_token_policy"
+}
+
+// GetTokenPolicy returns the token policy of an organization, organizations
+// without a stored policy get an empty one
+func (s *ServiceAccountsStoreImpl) GetTokenPolicy(ctx context.Context, orgID int64) (*serviceaccounts.TokenPolicy, error) {
+	policy := &tokenPolicy{}
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("org_id = ?", orgID).Get(policy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &serviceaccounts.TokenPolicy{OrgID: orgID, MaxLifetimeSeconds: policy.MaxLifetimeSeconds}, nil
+}
+
+// SaveTokenPolicy creates or replaces the token policy of an organization
+func (s *ServiceAccountsStoreImpl) SaveTokenPolicy(ctx context.Context, policy *serviceaccounts.TokenPolicy) error {
+	return s.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		row := &tokenPolicy{
+			OrgID:              policy.OrgID,
+			MaxLifetimeSeconds: policy.MaxLifetimeSeconds,
+			Updated:            time.Now(),
+		}
+
+		existing := &tokenPolicy{}
+		has, err := sess.Where("org_id = ?", policy.OrgID).Get(existing)
+		if err != nil {
+			return err
+		}
+		if has {
+			row.ID = existing.ID
+			_, err = sess.ID(row.ID).AllCols().Update(row)
+			return err
+		}
+
+		_, err = sess.Insert(row)
+		return err
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/apikey"
@@ -87,7 +88,16 @@ func (s *ServiceAccountsStoreImpl) DeleteServiceAccountToken(ctx context.Context
 		if affected == 0 {
 			return serviceaccounts.ErrServiceAccountTokenNotFound.Errorf("service account token with id %d not found", tokenId)
 		}
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.Exec("DELETE FROM api_key_ip_allowlist WHERE api_key_id=? and org_id=?", tokenId, orgId)
+		if err != nil {
+			return err
+		}
 
+		_, err = sess.Exec("DELETE FROM api_key_usage WHERE api_key_id=? and org_id=?", tokenId, orgId)
 		return err
 	})
 }
@@ -109,6 +119,49 @@ func (s *ServiceAccountsStoreImpl) RevokeServiceAccountToken(ctx context.Context
 	})
 }
 
+// UpdateServiceAccountTokenExpiry sets the expiry of a service account token as a unix timestamp
+func (s *ServiceAccountsStoreImpl) UpdateServiceAccountTokenExpiry(ctx context.Context, orgId, serviceAccountId, tokenId, expires int64) error {
+	rawSQL := "UPDATE api_key SET expires = ?, updated = ? WHERE id=? and org_id=? and service_account_id=?"
+
+	return s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		result, err := sess.Exec(rawSQL, expires, time.Now(), tokenId, orgId, serviceAccountId)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if affected == 0 {
+			return serviceaccounts.ErrServiceAccountTokenNotFound.Errorf("service account token with id %d not found for service account with id %d", tokenId, serviceAccountId)
+		}
+
+		return err
+	})
+}
+
+// CopyServiceAccountTokenIPAllowlist applies the IP allowlist of a token to another token of the same org
+func (s *ServiceAccountsStoreImpl) CopyServiceAccountTokenIPAllowlist(ctx context.Context, orgID, fromTokenID, toTokenID int64) error {
+	rawSQL := "INSERT INTO api_key_ip_allowlist (org_id, api_key_id, cidrs, updated) " +
+		"SELECT org_id, ?, cidrs, ? FROM api_key_ip_allowlist WHERE api_key_id=? and org_id=?"
+
+	return s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec(rawSQL, toTokenID, time.Now(), fromTokenID, orgID)
+		return err
+	})
+}
+
+// ListExpiringTokens returns the non revoked service account tokens of all organizations
+// that expire after now and no later than before
+func (s *ServiceAccountsStoreImpl) ListExpiringTokens(ctx context.Context, now, before int64) ([]apikey.APIKey, error) {
+	result := make([]apikey.APIKey, 0)
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("service_account_id IS NOT NULL").
+			Where("is_revoked IS NULL OR is_revoked = ?", s.sqlStore.GetDialect().BooleanValue(false)).
+			Where("expires > ? AND expires <= ?", now, before).
+			Asc("expires").
+			Find(&result)
+	})
+	return result, err
+}
+
 // assignApiKeyToServiceAccount sets the API key service account ID
 func (s *ServiceAccountsStoreImpl) assignApiKeyToServiceAccount(ctx context.Context, apiKeyId int64, serviceAccountId int64) error {
 	return s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -188,3 +189,61 @@ func TestIntegration_Store_DeleteServiceAccountToken(t *testing.T) {
 		}
 	}
 }
+
+func TestIntegration_Store_TokenExpiry(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	userToCreate := tests.TestUser{Login: "servicetestwithTeam@admin", IsServiceAccount: true}
+	db, store := setupTestDatabase(t)
+	sa := tests.SetupUserServiceAccount(t, db, store.cfg, userToCreate)
+
+	key, err := apikeygen.New(sa.OrgID, "expiring")
+	require.NoError(t, err)
+	token, err := store.AddServiceAccountToken(context.Background(), sa.ID, &serviceaccounts.AddServiceAccountTokenCommand{
+		Name:          "expiring",
+		OrgId:         sa.OrgID,
+		Key:           key.HashedKey,
+		SecondsToLive: 3600,
+	})
+	require.NoError(t, err)
+
+	now := time.Now().Unix()
+	expiring, err := store.ListExpiringTokens(context.Background(), now, now+60)
+	require.NoError(t, err)
+	require.Empty(t, expiring)
+
+	err = store.UpdateServiceAccountTokenExpiry(context.Background(), sa.OrgID, sa.ID, token.ID, now+30)
+	require.NoError(t, err)
+
+	expiring, err = store.ListExpiringTokens(context.Background(), now, now+60)
+	require.NoError(t, err)
+	require.Len(t, expiring, 1)
+	require.Equal(t, token.ID, expiring[0].ID)
+
+	stats, err := store.GetTokenExpiryStats(context.Background(), now, now+60)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), stats.Expiring)
+	require.Equal(t, int64(0), stats.Expired)
+
+	err = store.UpdateServiceAccountTokenExpiry(context.Background(), sa.OrgID, sa.ID, token.ID+1, now)
+	require.ErrorIs(t, err, serviceaccounts.ErrServiceAccountTokenNotFound)
+}
+
+func TestIntegration_Store_TokenPolicy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	_, store := setupTestDatabase(t)
+
+	policy, err := store.GetTokenPolicy(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, &serviceaccounts.TokenPolicy{OrgID: 1}, policy)
+
+	require.NoError(t, store.SaveTokenPolicy(context.Background(), &serviceaccounts.TokenPolicy{OrgID: 1, MaxLifetimeSeconds: 3600}))
+	require.NoError(t, store.SaveTokenPolicy(context.Background(), &serviceaccounts.TokenPolicy{OrgID: 1, MaxLifetimeSeconds: 7200}))
+
+	policy, err = store.GetTokenPolicy(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(7200), policy.MaxLifetimeSeconds)
+}
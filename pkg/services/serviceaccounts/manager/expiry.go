@@ -0,0 +1,188 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/apikey"
+)
+
+// expiryNotificationsNamespace holds the tokens that have been notified, keyed by token ID
+// with the notified expiry as value, so that every token is only notified once per expiry
+const expiryNotificationsNamespace = "serviceaccounts.token-expiry"
+
+var (
+	errExpiryWebhookURL           = errors.New("token expiry webhook url must be https")
+	errInvalidExpiryWebhookStatus = errors.New("invalid token expiry webhook status code")
+)
+
+type expiryNotifier interface {
+	Notify(ctx context.Context, token *apikey.APIKey) error
+}
+
+// checkTokenExpiry updates the token expiry metrics and notifies the tokens
+// that entered the expiry warning period
+func (sa *ServiceAccountsService) checkTokenExpiry(ctx context.Context, interval time.Duration) {
+	now := time.Now()
+	warnBefore := now.Add(sa.cfg.SATokenExpiryWarningPeriod).Unix()
+
+	stats, err := sa.store.GetTokenExpiryStats(ctx, now.Unix(), warnBefore)
+	if err != nil {
+		sa.backgroundLog.Warn("Failed to get token expiry stats", "error", err.Error())
+	} else {
+		setTokenExpiryStats(stats)
+	}
+
+	if sa.expiryNotifier == nil {
+		return
+	}
+
+	err = sa.serverLock.LockAndExecute(ctx, "notify expiring service account tokens", interval/2, func(ctx context.Context) {
+		if err := sa.notifyExpiringTokens(ctx, now.Unix(), warnBefore); err != nil {
+			sa.backgroundLog.Warn("Failed to notify expiring tokens", "error", err.Error())
+		}
+	})
+	if err != nil {
+		sa.backgroundLog.Error("Failed to lock and execute the notification of expiring tokens", "error", err)
+	}
+}
+
+func (sa *ServiceAccountsService) notifyExpiringTokens(ctx context.Context, now, warnBefore int64) error {
+	tokens, err := sa.store.ListExpiringTokens(ctx, now, warnBefore)
+	if err != nil {
+		return err
+	}
+
+	kv := kvstore.WithNamespace(sa.kvStore, 0, expiryNotificationsNamespace)
+	notified, err := kv.Keys(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	expiring := make(map[string]bool, len(tokens))
+	for i := range tokens {
+		token := &tokens[i]
+		key := strconv.FormatInt(token.ID, 10)
+		expires := strconv.FormatInt(*token.Expires, 10)
+		expiring[key] = true
+
+		value, ok, err := kv.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		if ok && value == expires {
+			continue
+		}
+
+		if err := sa.expiryNotifier.Notify(ctx, token); err != nil {
+			sa.backgroundLog.Warn("Failed to notify expiring token", "tokenId", token.ID, "orgId", token.OrgID, "error", err.Error())
+			continue
+		}
+
+		if err := kv.Set(ctx, key, expires); err != nil {
+			return err
+		}
+	}
+
+	// forget tokens that have expired, been deleted or had their expiry extended
+	for _, k := range notified {
+		if !expiring[k.Key] {
+			if err := kv.Del(ctx, k.Key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// expiryWebhookClient posts a notification for every token entering the expiry warning period.
+type expiryWebhookClient struct {
+	httpClient *http.Client
+	version    string
+	url        string
+}
+
+func newExpiryWebhookClient(url, version string, dev bool) (*expiryWebhookClient, error) {
+	if !strings.HasPrefix(url, "https://") && !dev {
+		return nil, errExpiryWebhookURL
+	}
+
+	return &expiryWebhookClient{
+		version: version,
+		url:     url,
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					Renegotiation: tls.RenegotiateFreelyAsClient,
+				},
+				Proxy: http.ProxyFromEnvironment,
+				DialContext: (&net.Dialer{
+					Timeout:   4 * time.Second,
+					KeepAlive: 15 * time.Second,
+				}).DialContext,
+				TLSHandshakeTimeout:   10 * time.Second,
+				ExpectContinueTimeout: 1 * time.Second,
+				MaxIdleConns:          100,
+				IdleConnTimeout:       30 * time.Second,
+			},
+			Timeout: time.Second * 30,
+		},
+	}, nil
+}
+
+func (c *expiryWebhookClient) Notify(ctx context.Context, token *apikey.APIKey) error {
+	expires := time.Unix(*token.Expires, 0).UTC()
+
+	values := map[string]any{
+		"alert_uid":          uuid.NewString(),
+		"title":              "Grafana service account token expiring",
+		"state":              "alerting",
+		"token_id":           token.ID,
+		"token_name":         token.Name,
+		"org_id":             token.OrgID,
+		"service_account_id": token.ServiceAccountId,
+		"expires":            expires.Format(time.RFC3339),
+		"message": "Service account token with name " + token.Name +
+			" expires on " + expires.Format(time.RFC3339) + ". Rotate the token to keep the service account working.",
+	}
+
+	jsonValue, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("%s: %w", "failed to marshal webhook request", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(jsonValue))
+	if err != nil {
+		return fmt.Errorf("%s: %w", "failed to make http request", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "grafana-token-expiry-webhook-client/"+c.version)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", "failed to webhook request", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w. status code %s", errInvalidExpiryWebhookStatus, resp.Status)
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"time"
 
@@ -26,8 +27,9 @@ import (
 )
 
 const (
-	metricsCollectionInterval = time.Minute * 30
-	defaultSecretScanInterval = time.Minute * 5
+	metricsCollectionInterval       = time.Minute * 30
+	defaultSecretScanInterval       = time.Minute * 5
+	defaultTokenExpiryCheckInterval = time.Hour
 )
 
 type ServiceAccountsService struct {
@@ -42,6 +44,8 @@ type ServiceAccountsService struct {
 	secretScanService secretscan.Checker
 	orgService        org.Service
 	serverLock        *serverlock.ServerLockService
+	kvStore           kvstore.KVStore
+	expiryNotifier    expiryNotifier
 
 	secretScanEnabled  bool
 	secretScanInterval time.Duration
@@ -77,6 +81,7 @@ func ProvideServiceAccountsService(
 		backgroundLog: log.New("serviceaccounts.background"),
 		orgService:    orgService,
 		serverLock:    serverLockService,
+		kvStore:       kvStore,
 	}
 
 	if err := RegisterRoles(acService); err != nil {
@@ -98,6 +103,16 @@ func ProvideServiceAccountsService(
 		}
 	}
 
+	if cfg.SATokenExpiryWebhookURL != "" {
+		notifier, errWebhook := newExpiryWebhookClient(cfg.SATokenExpiryWebhookURL, cfg.BuildVersion, cfg.Env == setting.Dev)
+		if errWebhook != nil {
+			s.log.Warn("Failed to initialize token expiry webhook. expiry notifications are disabled",
+				"error", errWebhook.Error())
+		} else {
+			s.expiryNotifier = notifier
+		}
+	}
+
 	return s, nil
 }
 
@@ -143,6 +158,20 @@ func (sa *ServiceAccountsService) Run(ctx context.Context) error {
 		defer tokenCheckTicker.Stop()
 	}
 
+	// Enforce a minimum interval of 1 minute.
+	expiryCheckInterval := sa.cfg.SATokenExpiryCheckInterval
+	if expiryCheckInterval < time.Minute {
+		sa.backgroundLog.Warn("Token expiry check interval is too low, increasing to " +
+			defaultTokenExpiryCheckInterval.String())
+
+		expiryCheckInterval = defaultTokenExpiryCheckInterval
+	}
+
+	expiryCheckTicker := time.NewTicker(expiryCheckInterval)
+	defer expiryCheckTicker.Stop()
+
+	sa.checkTokenExpiry(ctx, expiryCheckInterval)
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -165,6 +194,10 @@ func (sa *ServiceAccountsService) Run(ctx context.Context) error {
 			if err := sa.secretScanService.CheckTokens(ctx); err != nil {
 				sa.backgroundLog.Warn("Failed to check for leaked tokens", "error", err.Error())
 			}
+		case <-expiryCheckTicker.C:
+			sa.backgroundLog.Debug("Checking for expiring tokens")
+
+			sa.checkTokenExpiry(ctx, expiryCheckInterval)
 		}
 	}
 }
@@ -301,6 +334,120 @@ func (sa *ServiceAccountsService) DeleteServiceAccountToken(ctx context.Context,
 	return sa.store.DeleteServiceAccountToken(ctx, orgID, serviceAccountID, tokenID)
 }
 
+// RotateServiceAccountToken adds a replacement for a token and lets the rotated token
+// expire once the overlap period has passed, so that clients can switch without downtime
+func (sa *ServiceAccountsService) RotateServiceAccountToken(ctx context.Context, serviceAccountID, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	if err := validOrgID(cmd.OrgId); err != nil {
+		return nil, err
+	}
+	if err := validServiceAccountID(serviceAccountID); err != nil {
+		return nil, err
+	}
+	if err := validServiceAccountTokenID(tokenID); err != nil {
+		return nil, err
+	}
+
+	tokens, err := sa.store.ListTokens(ctx, &serviceaccounts.GetSATokensQuery{
+		OrgID:            &cmd.OrgId,
+		ServiceAccountID: &serviceAccountID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rotated *apikey.APIKey
+	for i := range tokens {
+		if tokens[i].ID == tokenID {
+			rotated = &tokens[i]
+			break
+		}
+	}
+	if rotated == nil {
+		return nil, serviceaccounts.ErrServiceAccountTokenNotFound.Errorf("service account token with id %d not found for service account with id %d", tokenID, serviceAccountID)
+	}
+
+	now := time.Now()
+	if (rotated.IsRevoked != nil && *rotated.IsRevoked) || (rotated.Expires != nil && *rotated.Expires <= now.Unix()) {
+		return nil, serviceaccounts.ErrTokenNotRotatable.Errorf("service account token with id %d is expired or revoked", tokenID)
+	}
+
+	// the replacement keeps the lifetime of the rotated token unless a new one is requested
+	secondsToLive := cmd.SecondsToLive
+	if secondsToLive == 0 && rotated.Expires != nil {
+		secondsToLive = *rotated.Expires - rotated.Created.Unix()
+	}
+
+	policy, err := sa.store.GetTokenPolicy(ctx, cmd.OrgId)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.Allows(secondsToLive) {
+		return nil, serviceaccounts.ErrTokenLifetimeExceedsPolicy.Errorf("token lifetime of %d seconds exceeds the maximum of %d seconds", secondsToLive, policy.MaxLifetimeSeconds)
+	}
+
+	overlap := sa.cfg.SATokenRotationOverlap
+	if cmd.OverlapSeconds != nil {
+		if *cmd.OverlapSeconds < 0 {
+			return nil, serviceaccounts.ErrInvalidTokenExpiration.Errorf("invalid overlap value %d", *cmd.OverlapSeconds)
+		}
+		overlap = time.Duration(*cmd.OverlapSeconds) * time.Second
+	}
+
+	// never extend the lifetime of the rotated token
+	rotatedExpires := now.Add(overlap).Unix()
+	if rotated.Expires != nil && *rotated.Expires < rotatedExpires {
+		rotatedExpires = *rotated.Expires
+	}
+
+	name := cmd.Name
+	if name == "" {
+		name = rotatedTokenName(rotated.Name, now)
+	}
+
+	var replacement *apikey.APIKey
+	err = sa.db.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		replacement, err = sa.store.AddServiceAccountToken(ctx, serviceAccountID, &serviceaccounts.AddServiceAccountTokenCommand{
+			Name:          name,
+			OrgId:         cmd.OrgId,
+			Key:           cmd.Key,
+			SecondsToLive: secondsToLive,
+		})
+		if err != nil {
+			return err
+		}
+
+		// the replacement must not be usable from networks the rotated token was not allowed from
+		if err := sa.store.CopyServiceAccountTokenIPAllowlist(ctx, cmd.OrgId, tokenID, replacement.ID); err != nil {
+			return err
+		}
+
+		return sa.store.UpdateServiceAccountTokenExpiry(ctx, cmd.OrgId, serviceAccountID, tokenID, rotatedExpires)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return replacement, nil
+}
+
+func (sa *ServiceAccountsService) GetTokenPolicy(ctx context.Context, orgID int64) (*serviceaccounts.TokenPolicy, error) {
+	if err := validOrgID(orgID); err != nil {
+		return nil, err
+	}
+	return sa.store.GetTokenPolicy(ctx, orgID)
+}
+
+func (sa *ServiceAccountsService) UpdateTokenPolicy(ctx context.Context, policy *serviceaccounts.TokenPolicy) error {
+	if err := validOrgID(policy.OrgID); err != nil {
+		return err
+	}
+	if policy.MaxLifetimeSeconds < 0 {
+		return serviceaccounts.ErrInvalidTokenPolicy.Errorf("invalid maximum token lifetime %d", policy.MaxLifetimeSeconds)
+	}
+	return sa.store.SaveTokenPolicy(ctx, policy)
+}
+
 func (sa *ServiceAccountsService) MigrateApiKeysToServiceAccounts(ctx context.Context, orgID int64) (*serviceaccounts.MigrationResult, error) {
 	if err := validOrgID(orgID); err != nil {
 		return nil, err
@@ -353,6 +500,14 @@ func (sa *ServiceAccountsService) migrateAPIKeysForAllOrgs(ctx context.Context)
 	return nil
 }
 
+var rotatedTokenSuffix = regexp.MustCompile(`-\d{14}$`)
+
+// rotatedTokenName names a replacement token after the token it replaces,
+// swapping the timestamp suffix of tokens that have already been rotated
+func rotatedTokenName(name string, now time.Time) string {
+	return rotatedTokenSuffix.ReplaceAllString(name, "") + now.UTC().Format("-20060102150405")
+}
+
 func validOrgID(orgID int64) error {
 	if orgID == 0 {
 		return serviceaccounts.ErrServiceAccountInvalidOrgID.Errorf("invalid org ID 0 has been specified")
@@ -3,15 +3,19 @@ package manager
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
 	"github.com/grafana/grafana/pkg/services/apikey"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tests/testsuite"
 )
 
@@ -21,11 +25,18 @@ type FakeServiceAccountStore struct {
 	ExpectedServiceAccountProfileDTO        *serviceaccounts.ServiceAccountProfileDTO
 	ExpectedSearchServiceAccountQueryResult *serviceaccounts.SearchOrgServiceAccountsResult
 	ExpectedStats                           *serviceaccounts.Stats
+	ExpectedTokenExpiryStats                *serviceaccounts.TokenExpiryStats
+	ExpectedTokenPolicy                     *serviceaccounts.TokenPolicy
 	expectedMigratedResults                 *serviceaccounts.MigrationResult
 	ExpectedAPIKeys                         []apikey.APIKey
 	ExpectedAPIKey                          *apikey.APIKey
 	ExpectedBoolean                         bool
 	ExpectedError                           error
+
+	addedTokens      []*serviceaccounts.AddServiceAccountTokenCommand
+	tokenExpiries    map[int64]int64
+	savedPolicy      *serviceaccounts.TokenPolicy
+	copiedAllowlists map[int64]int64
 }
 
 var _ store = (*FakeServiceAccountStore)(nil)
@@ -92,6 +103,7 @@ func (f *FakeServiceAccountStore) RevokeServiceAccountToken(ctx context.Context,
 
 // AddServiceAccountToken is a fake adding a service account token.
 func (f *FakeServiceAccountStore) AddServiceAccountToken(ctx context.Context, serviceAccountID int64, cmd *serviceaccounts.AddServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	f.addedTokens = append(f.addedTokens, cmd)
 	return f.ExpectedAPIKey, f.ExpectedError
 }
 
@@ -105,6 +117,48 @@ func (f *FakeServiceAccountStore) GetUsageMetrics(ctx context.Context) (*service
 	return f.ExpectedStats, f.ExpectedError
 }
 
+// GetTokenExpiryStats is a fake getting token expiry stats.
+func (f *FakeServiceAccountStore) GetTokenExpiryStats(ctx context.Context, now, expiringBefore int64) (*serviceaccounts.TokenExpiryStats, error) {
+	return f.ExpectedTokenExpiryStats, f.ExpectedError
+}
+
+// ListExpiringTokens is a fake listing expiring tokens.
+func (f *FakeServiceAccountStore) ListExpiringTokens(ctx context.Context, now, before int64) ([]apikey.APIKey, error) {
+	return f.ExpectedAPIKeys, f.ExpectedError
+}
+
+// CopyServiceAccountTokenIPAllowlist is a fake copying the IP allowlist of a token.
+func (f *FakeServiceAccountStore) CopyServiceAccountTokenIPAllowlist(ctx context.Context, orgID, fromTokenID, toTokenID int64) error {
+	if f.copiedAllowlists == nil {
+		f.copiedAllowlists = map[int64]int64{}
+	}
+	f.copiedAllowlists[toTokenID] = fromTokenID
+	return f.ExpectedError
+}
+
+// UpdateServiceAccountTokenExpiry is a fake updating the expiry of a token.
+func (f *FakeServiceAccountStore) UpdateServiceAccountTokenExpiry(ctx context.Context, orgId, serviceAccountId, tokenId, expires int64) error {
+	if f.tokenExpiries == nil {
+		f.tokenExpiries = map[int64]int64{}
+	}
+	f.tokenExpiries[tokenId] = expires
+	return f.ExpectedError
+}
+
+// GetTokenPolicy is a fake getting the token policy of an org.
+func (f *FakeServiceAccountStore) GetTokenPolicy(ctx context.Context, orgID int64) (*serviceaccounts.TokenPolicy, error) {
+	if f.ExpectedTokenPolicy == nil {
+		return &serviceaccounts.TokenPolicy{OrgID: orgID}, f.ExpectedError
+	}
+	return f.ExpectedTokenPolicy, f.ExpectedError
+}
+
+// SaveTokenPolicy is a fake saving the token policy of an org.
+func (f *FakeServiceAccountStore) SaveTokenPolicy(ctx context.Context, policy *serviceaccounts.TokenPolicy) error {
+	f.savedPolicy = policy
+	return f.ExpectedError
+}
+
 type SecretsCheckerFake struct {
 	ExpectedError error
 }
@@ -158,3 +212,137 @@ func TestIntegrationProvideServiceAccount_DeleteServiceAccount(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestIntegrationServiceAccountsService_RotateServiceAccountToken(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	created := time.Now().Add(-time.Hour)
+	expires := created.Add(30 * 24 * time.Hour).Unix()
+	setup := func(t *testing.T, token apikey.APIKey) (*ServiceAccountsService, *FakeServiceAccountStore) {
+		storeMock := newServiceAccountStoreFake()
+		storeMock.ExpectedAPIKeys = []apikey.APIKey{token}
+		storeMock.ExpectedAPIKey = &apikey.APIKey{ID: 2}
+		return &ServiceAccountsService{
+			cfg:   &setting.Cfg{SATokenRotationOverlap: time.Hour},
+			store: storeMock,
+			db:    db.InitTestDB(t),
+			log:   log.NewNopLogger(),
+		}, storeMock
+	}
+
+	t.Run("should add a replacement and shorten the lifetime of the rotated token", func(t *testing.T) {
+		svc, storeMock := setup(t, apikey.APIKey{ID: 1, Name: "deploy", Created: created, Expires: &expires})
+
+		token, err := svc.RotateServiceAccountToken(context.Background(), 1, 1, &serviceaccounts.RotateServiceAccountTokenCommand{OrgId: 1, Key: "hashed"})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), token.ID)
+
+		require.Len(t, storeMock.addedTokens, 1)
+		added := storeMock.addedTokens[0]
+		assert.Regexp(t, `^deploy-\d{14}$`, added.Name)
+		assert.Equal(t, "hashed", added.Key)
+		assert.Equal(t, expires-created.Unix(), added.SecondsToLive)
+		assert.InDelta(t, time.Now().Add(time.Hour).Unix(), storeMock.tokenExpiries[1], 5)
+		assert.Equal(t, map[int64]int64{2: 1}, storeMock.copiedAllowlists)
+	})
+
+	t.Run("should use the requested overlap", func(t *testing.T) {
+		svc, storeMock := setup(t, apikey.APIKey{ID: 1, Name: "deploy", Created: created})
+		overlap := int64(0)
+
+		_, err := svc.RotateServiceAccountToken(context.Background(), 1, 1, &serviceaccounts.RotateServiceAccountTokenCommand{OrgId: 1, Name: "deploy-v2", OverlapSeconds: &overlap})
+		require.NoError(t, err)
+
+		assert.Equal(t, "deploy-v2", storeMock.addedTokens[0].Name)
+		assert.Equal(t, int64(0), storeMock.addedTokens[0].SecondsToLive)
+		assert.InDelta(t, time.Now().Unix(), storeMock.tokenExpiries[1], 5)
+	})
+
+	t.Run("should enforce the org token policy", func(t *testing.T) {
+		svc, storeMock := setup(t, apikey.APIKey{ID: 1, Name: "deploy", Created: created})
+		storeMock.ExpectedTokenPolicy = &serviceaccounts.TokenPolicy{OrgID: 1, MaxLifetimeSeconds: 3600}
+
+		_, err := svc.RotateServiceAccountToken(context.Background(), 1, 1, &serviceaccounts.RotateServiceAccountTokenCommand{OrgId: 1})
+		require.ErrorIs(t, err, serviceaccounts.ErrTokenLifetimeExceedsPolicy)
+		require.Empty(t, storeMock.addedTokens)
+	})
+
+	t.Run("should not rotate revoked tokens", func(t *testing.T) {
+		revoked := true
+		svc, _ := setup(t, apikey.APIKey{ID: 1, Name: "deploy", Created: created, IsRevoked: &revoked})
+
+		_, err := svc.RotateServiceAccountToken(context.Background(), 1, 1, &serviceaccounts.RotateServiceAccountTokenCommand{OrgId: 1})
+		require.ErrorIs(t, err, serviceaccounts.ErrTokenNotRotatable)
+	})
+
+	t.Run("should return not found for unknown tokens", func(t *testing.T) {
+		svc, _ := setup(t, apikey.APIKey{ID: 1, Name: "deploy", Created: created})
+
+		_, err := svc.RotateServiceAccountToken(context.Background(), 1, 3, &serviceaccounts.RotateServiceAccountTokenCommand{OrgId: 1})
+		require.ErrorIs(t, err, serviceaccounts.ErrServiceAccountTokenNotFound)
+	})
+}
+
+func TestServiceAccountsService_UpdateTokenPolicy(t *testing.T) {
+	storeMock := newServiceAccountStoreFake()
+	svc := ServiceAccountsService{store: storeMock, log: log.NewNopLogger()}
+
+	err := svc.UpdateTokenPolicy(context.Background(), &serviceaccounts.TokenPolicy{OrgID: 1, MaxLifetimeSeconds: -1})
+	require.ErrorIs(t, err, serviceaccounts.ErrInvalidTokenPolicy)
+
+	err = svc.UpdateTokenPolicy(context.Background(), &serviceaccounts.TokenPolicy{OrgID: 1, MaxLifetimeSeconds: 3600})
+	require.NoError(t, err)
+	require.Equal(t, int64(3600), storeMock.savedPolicy.MaxLifetimeSeconds)
+}
+
+func TestRotatedTokenName(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, "deploy-20240501123000", rotatedTokenName("deploy", now))
+	assert.Equal(t, "deploy-20240501123000", rotatedTokenName("deploy-20240101000000", now))
+	assert.Equal(t, "deploy-2024-20240501123000", rotatedTokenName("deploy-2024", now))
+}
+
+type fakeExpiryNotifier struct {
+	notified []int64
+}
+
+func (f *fakeExpiryNotifier) Notify(_ context.Context, token *apikey.APIKey) error {
+	f.notified = append(f.notified, token.ID)
+	return nil
+}
+
+func TestServiceAccountsService_NotifyExpiringTokens(t *testing.T) {
+	expires := time.Now().Add(time.Hour).Unix()
+	storeMock := newServiceAccountStoreFake()
+	storeMock.ExpectedAPIKeys = []apikey.APIKey{{ID: 1, Expires: &expires}, {ID: 2, Expires: &expires}}
+	notifier := &fakeExpiryNotifier{}
+	svc := ServiceAccountsService{
+		store:          storeMock,
+		kvStore:        kvstore.NewFakeKVStore(),
+		expiryNotifier: notifier,
+		log:            log.NewNopLogger(),
+		backgroundLog:  log.NewNopLogger(),
+	}
+	ctx := context.Background()
+
+	require.NoError(t, svc.notifyExpiringTokens(ctx, 0, expires))
+	assert.Equal(t, []int64{1, 2}, notifier.notified)
+
+	// tokens are only notified once per expiry
+	require.NoError(t, svc.notifyExpiringTokens(ctx, 0, expires))
+	assert.Equal(t, []int64{1, 2}, notifier.notified)
+
+	// a changed expiry is notified again and tokens no longer expiring are forgotten
+	rotated := expires - 60
+	storeMock.ExpectedAPIKeys = []apikey.APIKey{{ID: 1, Expires: &rotated}}
+	require.NoError(t, svc.notifyExpiringTokens(ctx, 0, expires))
+	assert.Equal(t, []int64{1, 2, 1}, notifier.notified)
+
+	keys, err := kvstore.WithNamespace(svc.kvStore, 0, expiryNotificationsNamespace).Keys(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "1", keys[0].Key)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 )
 
 const (
@@ -29,6 +31,15 @@ var (
 	// MStatFailedMigratedAPIKeysToSATokens is a metric gauge for total number of failed migrations of API keys to service account tokens
 	MStatFailedMigratedAPIKeysToSATokens prometheus.Gauge
 
+	// MStatTotalServiceAccountTokensExpiring is a metric gauge for total number of service account tokens within the expiry warning period
+	MStatTotalServiceAccountTokensExpiring prometheus.Gauge
+
+	// MStatTotalServiceAccountTokensExpired is a metric gauge for total number of expired service account tokens
+	MStatTotalServiceAccountTokensExpired prometheus.Gauge
+
+	// MStatTotalServiceAccountTokensWithoutExpiry is a metric gauge for total number of service account tokens that never expire
+	MStatTotalServiceAccountTokensWithoutExpiry prometheus.Gauge
+
 	Initialised bool = false
 )
 
@@ -69,6 +80,24 @@ func init() {
 		Namespace: ExporterName,
 	})
 
+	MStatTotalServiceAccountTokensExpiring = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "stat_total_service_account_tokens_expiring",
+		Help:      "total amount of service account tokens expiring within the warning period",
+		Namespace: ExporterName,
+	})
+
+	MStatTotalServiceAccountTokensExpired = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "stat_total_service_account_tokens_expired",
+		Help:      "total amount of expired service account tokens",
+		Namespace: ExporterName,
+	})
+
+	MStatTotalServiceAccountTokensWithoutExpiry = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "stat_total_service_account_tokens_without_expiry",
+		Help:      "total amount of service account tokens without expiry",
+		Namespace: ExporterName,
+	})
+
 	prometheus.MustRegister(
 		MStatTotalServiceAccounts,
 		MStatTotalServiceAccountTokens,
@@ -76,6 +105,9 @@ func init() {
 		MStatTotalMigratedAPIKeysToSATokens,
 		MStatSuccessfullyMigratedAPIKeysToSATokens,
 		MStatFailedMigratedAPIKeysToSATokens,
+		MStatTotalServiceAccountTokensExpiring,
+		MStatTotalServiceAccountTokensExpired,
+		MStatTotalServiceAccountTokensWithoutExpiry,
 	)
 }
 
@@ -117,3 +149,9 @@ func setAPIKeyMigrationStats(total, migrated, failed int) {
 	MStatSuccessfullyMigratedAPIKeysToSATokens.Set(float64(migrated))
 	MStatFailedMigratedAPIKeysToSATokens.Set(float64(failed))
 }
+
+func setTokenExpiryStats(stats *serviceaccounts.TokenExpiryStats) {
+	MStatTotalServiceAccountTokensExpiring.Set(float64(stats.Expiring))
+	MStatTotalServiceAccountTokensExpired.Set(float64(stats.Expired))
+	MStatTotalServiceAccountTokensWithoutExpiry.Set(float64(stats.WithoutExpiry))
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 var (
@@ -42,6 +43,9 @@ var (
 	ErrServiceAccountTokenNotFound       = errutil.NotFound("serviceaccounts.ErrTokenNotFound", errutil.WithPublicMessage("service account token not found"))
 	ErrInvalidTokenExpiration            = errutil.ValidationFailed("serviceaccounts.ErrInvalidInput", errutil.WithPublicMessage("invalid SecondsToLive value"))
 	ErrDuplicateToken                    = errutil.BadRequest("serviceaccounts.ErrTokenAlreadyExists", errutil.WithPublicMessage("service account token with given name already exists in the organization"))
+	ErrTokenLifetimeExceedsPolicy        = errutil.BadRequest("serviceaccounts.ErrTokenLifetimeExceedsPolicy", errutil.WithPublicMessage("token lifetime exceeds the maximum allowed by the organization token policy"))
+	ErrTokenNotRotatable                 = errutil.BadRequest("serviceaccounts.ErrTokenNotRotatable", errutil.WithPublicMessage("expired or revoked tokens can not be rotated"))
+	ErrInvalidTokenPolicy                = errutil.BadRequest("serviceaccounts.ErrInvalidTokenPolicy", errutil.WithPublicMessage("invalid token policy"))
 )
 
 type MigrationResult struct {
@@ -79,6 +83,8 @@ type ServiceAccountDTO struct {
 	Name string `json:"name" xorm:"name"`
 	// example: sa-grafana
 	Login string `json:"login" xorm:"login"`
+	// Email is the first key the service accounts are sorted by
+	Email string `json:"-" xorm:"email"`
 	// example: 1
 	OrgId int64 `json:"orgId" xorm:"org_id"`
 	// example: false
@@ -113,12 +119,42 @@ type AddServiceAccountTokenCommand struct {
 	SecondsToLive int64  `json:"secondsToLive"`
 }
 
+type RotateServiceAccountTokenCommand struct {
+	// Name of the replacement token, defaults to the name of the rotated token with a timestamp suffix
+	Name          string `json:"name"`
+	OrgId         int64  `json:"-"`
+	Key           string `json:"-"`
+	SecondsToLive int64  `json:"secondsToLive"`
+	// Number of seconds the rotated token stays valid, defaults to the server setting
+	OverlapSeconds *int64 `json:"overlapSeconds"`
+}
+
+// TokenPolicy holds the restrictions an organization puts on service account tokens
+// swagger:model
+type TokenPolicy struct {
+	OrgID int64 `json:"-" xorm:"org_id"`
+	// Maximum lifetime of new tokens in seconds, 0 means tokens are only limited by the server settings
+	// example: 7776000
+	MaxLifetimeSeconds int64 `json:"maxLifetimeSeconds" xorm:"max_lifetime_seconds"`
+}
+
+// Allows reports whether a token living secondsToLive seconds complies with the policy,
+// 0 seconds meaning a token that never expires
+func (p *TokenPolicy) Allows(secondsToLive int64) bool {
+	if p.MaxLifetimeSeconds <= 0 {
+		return true
+	}
+	return secondsToLive > 0 && secondsToLive <= p.MaxLifetimeSeconds
+}
+
 type SearchOrgServiceAccountsQuery struct {
-	OrgID        int64
-	Query        string
-	Filter       ServiceAccountFilter
-	Page         int
-	Limit        int
+	OrgID  int64
+	Query  string
+	Filter ServiceAccountFilter
+	Page   int
+	Limit  int
+	// After selects the service accounts sorted after the cursor instead of the page
+	After        *pagination.Cursor
 	CountOnly    bool
 	CountTokens  bool
 	SignedInUser identity.Requester
@@ -138,6 +174,8 @@ type SearchOrgServiceAccountsResult struct {
 	ServiceAccounts []*ServiceAccountDTO `json:"serviceAccounts"`
 	Page            int                  `json:"page"`
 	PerPage         int                  `json:"perPage"`
+	// Next is the cursor of the next page, nil on the last page
+	Next *pagination.Cursor `json:"-"`
 }
 
 // swagger:model
@@ -189,6 +227,13 @@ type Stats struct {
 	ForcedExpiryEnabled       bool  `xorm:"-"`
 }
 
+// TokenExpiryStats counts service account tokens by expiry state
+type TokenExpiryStats struct {
+	Expiring      int64 `xorm:"expiring"`
+	Expired       int64 `xorm:"expired"`
+	WithoutExpiry int64 `xorm:"without_expiry"`
+}
+
 // ExtSvcAccount represents the service account associated to an external service
 type ExtSvcAccount struct {
 	ID         int64
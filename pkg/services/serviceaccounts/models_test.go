@@ -40,3 +40,15 @@ func TestIsExternalServiceAccount(t *testing.T) {
 		})
 	}
 }
+
+func TestTokenPolicy_Allows(t *testing.T) {
+	unrestricted := &TokenPolicy{}
+	require.True(t, unrestricted.Allows(0))
+	require.True(t, unrestricted.Allows(3600))
+
+	policy := &TokenPolicy{MaxLifetimeSeconds: 3600}
+	require.False(t, policy.Allows(0))
+	require.True(t, policy.Allows(60))
+	require.True(t, policy.Allows(3600))
+	require.False(t, policy.Allows(3601))
+}
@@ -123,6 +123,30 @@ func (s *ServiceAccountsProxy) ListTokens(ctx context.Context, query *serviceacc
 	return s.proxiedService.ListTokens(ctx, query)
 }
 
+func (s *ServiceAccountsProxy) RotateServiceAccountToken(ctx context.Context, serviceAccountID, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	if s.isProxyEnabled {
+		sa, err := s.proxiedService.RetrieveServiceAccount(ctx, &serviceaccounts.GetServiceAccountQuery{ID: serviceAccountID, OrgID: cmd.OrgId})
+		if err != nil {
+			return nil, err
+		}
+
+		if serviceaccounts.IsExternalServiceAccount(sa.Login) {
+			s.log.Error("unable to rotate tokens for external service accounts", "serviceAccountID", serviceAccountID)
+			return nil, extsvcaccounts.ErrCannotRotateToken
+		}
+	}
+
+	return s.proxiedService.RotateServiceAccountToken(ctx, serviceAccountID, tokenID, cmd)
+}
+
+func (s *ServiceAccountsProxy) GetTokenPolicy(ctx context.Context, orgID int64) (*serviceaccounts.TokenPolicy, error) {
+	return s.proxiedService.GetTokenPolicy(ctx, orgID)
+}
+
+func (s *ServiceAccountsProxy) UpdateTokenPolicy(ctx context.Context, policy *serviceaccounts.TokenPolicy) error {
+	return s.proxiedService.UpdateTokenPolicy(ctx, policy)
+}
+
 func (s *ServiceAccountsProxy) MigrateApiKeysToServiceAccounts(ctx context.Context, orgID int64) (*serviceaccounts.MigrationResult, error) {
 	return s.proxiedService.MigrateApiKeysToServiceAccounts(ctx, orgID)
 }
@@ -278,6 +278,37 @@ func TestProvideServiceAccount_crudServiceAccount(t *testing.T) {
 		}
 	})
 
+	t.Run("should rotate service account tokens", func(t *testing.T) {
+		testCases := []struct {
+			description            string
+			expectedServiceAccount *sa.ServiceAccountProfileDTO
+			expectedError          error
+		}{
+			{
+				description: "should allow to rotate a service account token",
+				expectedServiceAccount: &sa.ServiceAccountProfileDTO{
+					Login: "my-service-account",
+				},
+				expectedError: nil,
+			},
+			{
+				description: "should not allow to rotate an external service account token",
+				expectedServiceAccount: &sa.ServiceAccountProfileDTO{
+					Login: sa.ExtSvcLoginPrefix(autoAssignOrgID) + "my-service-account",
+				},
+				expectedError: extsvcaccounts.ErrCannotRotateToken,
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.description, func(t *testing.T) {
+				serviceMock.ExpectedServiceAccountProfile = tc.expectedServiceAccount
+				_, err := svc.RotateServiceAccountToken(context.Background(), testServiceAccountId, 1, &sa.RotateServiceAccountTokenCommand{OrgId: autoAssignOrgID})
+				assert.Equal(t, tc.expectedError, err, tc.description)
+			})
+		}
+	})
+
 	t.Run("should identify service account logins for being external or not", func(t *testing.T) {
 		assert.False(t, sa.IsExternalServiceAccount("my-service-account"))
 		assert.False(t, sa.IsExternalServiceAccount("sa-my-service-account"))
@@ -40,6 +40,14 @@ type Service interface {
 		cmd *AddServiceAccountTokenCommand) (*apikey.APIKey, error)
 	DeleteServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) error
 	ListTokens(ctx context.Context, query *GetSATokensQuery) ([]apikey.APIKey, error)
+	// RotateServiceAccountToken issues a replacement token and shortens the
+	// lifetime of the rotated token to the overlap period
+	RotateServiceAccountToken(ctx context.Context, serviceAccountID, tokenID int64,
+		cmd *RotateServiceAccountTokenCommand) (*apikey.APIKey, error)
+
+	// Token policies
+	GetTokenPolicy(ctx context.Context, orgID int64) (*TokenPolicy, error)
+	UpdateTokenPolicy(ctx context.Context, policy *TokenPolicy) error
 
 	MigrateApiKeysToServiceAccounts(ctx context.Context, orgID int64) (*MigrationResult, error)
 }
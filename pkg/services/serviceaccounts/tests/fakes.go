@@ -16,6 +16,7 @@ type FakeServiceAccountService struct {
 	ExpectedServiceAccountID               int64
 	ExpectedServiceAccountProfile          *serviceaccounts.ServiceAccountProfileDTO
 	ExpectedServiceAccountTokens           []apikey.APIKey
+	ExpectedTokenPolicy                    *serviceaccounts.TokenPolicy
 }
 
 var _ serviceaccounts.Service = new(FakeServiceAccountService)
@@ -65,3 +66,20 @@ func (f *FakeServiceAccountService) SearchOrgServiceAccounts(ctx context.Context
 func (f *FakeServiceAccountService) DeleteServiceAccountToken(ctx context.Context, orgID, id, tokenID int64) error {
 	return f.ExpectedErr
 }
+
+func (f *FakeServiceAccountService) RotateServiceAccountToken(ctx context.Context, id, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	return f.ExpectedAPIKey, f.ExpectedErr
+}
+
+// Service account token policies
+
+func (f *FakeServiceAccountService) GetTokenPolicy(ctx context.Context, orgID int64) (*serviceaccounts.TokenPolicy, error) {
+	if f.ExpectedTokenPolicy == nil {
+		return &serviceaccounts.TokenPolicy{OrgID: orgID}, f.ExpectedErr
+	}
+	return f.ExpectedTokenPolicy, f.ExpectedErr
+}
+
+func (f *FakeServiceAccountService) UpdateTokenPolicy(ctx context.Context, policy *serviceaccounts.TokenPolicy) error {
+	return f.ExpectedErr
+}
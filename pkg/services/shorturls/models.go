@@ -13,6 +13,8 @@ var (
 	ErrShortURLInvalidPath  = errutil.ValidationFailed("shorturl.invalid-path", errutil.WithPublicMessage("Invalid short URL path"))
 	ErrShortURLInternal     = errutil.Internal("shorturl.internal")
 	ErrShortURLConflict     = errutil.Conflict("shorturl.conflict")
+	ErrShortURLExpired      = errutil.NotFound("shorturl.expired", errutil.WithPublicMessage("The short URL has expired"))
+	ErrShortURLForbidden    = errutil.Forbidden("shorturl.forbidden", errutil.WithPublicMessage("You can only manage your own short URLs"))
 )
 
 type ShortUrl struct {
@@ -23,11 +25,39 @@ type ShortUrl struct {
 	CreatedBy  int64  `json:"-"`
 	CreatedAt  int64  `json:"-"`
 	LastSeenAt int64  `json:"lastSeenAt"`
+	// ExpiresAt is the unix time after which the short URL can't be used, 0 when it doesn't expire
+	ExpiresAt int64 `json:"expiresAt,omitempty"`
+	// MaxUses is the number of times the short URL can be used, 0 when it's unlimited
+	MaxUses  int64 `json:"maxUses,omitempty"`
+	UseCount int64 `json:"useCount"`
+}
+
+// IsExpired checks if the short URL expired or was used MaxUses times
+func (s *ShortUrl) IsExpired(now time.Time) bool {
+	return (s.ExpiresAt > 0 && now.Unix() >= s.ExpiresAt) || (s.MaxUses > 0 && s.UseCount >= s.MaxUses)
 }
 
 type DeleteShortUrlCommand struct {
-	Uid       string
+	Uid   string
+	OrgId int64
+	// Expired deletes the short URLs which expired or were used MaxUses times, instead of the stale ones
+	Expired   bool
 	OlderThan time.Time
 
 	NumDeleted int64
 }
+
+type SearchShortURLsQuery struct {
+	OrgID int64
+	// CreatedBy only returns the short URLs created by the user, 0 returns the short URLs of every user
+	CreatedBy int64
+	Page      int
+	Limit     int
+}
+
+type SearchShortURLsResult struct {
+	TotalCount int64       `json:"totalCount"`
+	ShortURLs  []*ShortUrl `json:"shortUrls"`
+	Page       int         `json:"page"`
+	PerPage    int         `json:"perPage"`
+}
@@ -11,6 +11,9 @@ type Service interface {
 	GetShortURLByUID(ctx context.Context, user *user.SignedInUser, uid string) (*ShortUrl, error)
 	CreateShortURL(ctx context.Context, user *user.SignedInUser, cmd *dtos.CreateShortURLCmd) (*ShortUrl, error)
 	UpdateLastSeenAt(ctx context.Context, shortURL *ShortUrl) error
+	SearchShortURLs(ctx context.Context, query *SearchShortURLsQuery) (*SearchShortURLsResult, error)
+	// DeleteShortURL deletes a short URL of the user's organization, org admins can delete the short URLs of the other users
+	DeleteShortURL(ctx context.Context, user *user.SignedInUser, uid string) error
 	DeleteStaleShortURLs(ctx context.Context, cmd *DeleteShortUrlCommand) error
 	ConvertShortURLToDTO(shortURL *ShortUrl, appURL string) *dtos.ShortURL
 }
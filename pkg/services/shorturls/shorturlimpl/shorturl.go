@@ -7,7 +7,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -17,6 +20,11 @@ import (
 
 var getTime = time.Now
 
+const (
+	defaultSearchLimit = 100
+	maxSearchLimit     = 1000
+)
+
 type ShortURLService struct {
 	SQLStore store
 }
@@ -30,7 +38,14 @@ func ProvideService(db db.DB) *ShortURLService {
 }
 
 func (s ShortURLService) GetShortURLByUID(ctx context.Context, user *user.SignedInUser, uid string) (*shorturls.ShortUrl, error) {
-	return s.SQLStore.Get(ctx, user, uid)
+	shortURL, err := s.SQLStore.Get(ctx, user, uid)
+	if err != nil {
+		return nil, err
+	}
+	if shortURL.IsExpired(getTime()) {
+		return nil, shorturls.ErrShortURLExpired.Errorf("short URL %s has expired", uid)
+	}
+	return shortURL, nil
 }
 
 func (s ShortURLService) UpdateLastSeenAt(ctx context.Context, shortURL *shorturls.ShortUrl) error {
@@ -47,6 +62,21 @@ func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedIn
 		return nil, shorturls.ErrShortURLInvalidPath.Errorf("path cannot contain '../': %s", relPath)
 	}
 
+	if cmd.MaxUses < 0 {
+		return nil, shorturls.ErrShortURLBadRequest.Errorf("max uses cannot be negative: %d", cmd.MaxUses)
+	}
+	var expiresAt int64
+	if cmd.ExpiresIn != "" {
+		expiresIn, err := gtime.ParseDuration(cmd.ExpiresIn)
+		if err != nil {
+			return nil, shorturls.ErrShortURLBadRequest.Errorf("invalid expiresIn: %w", err)
+		}
+		if expiresIn <= 0 {
+			return nil, shorturls.ErrShortURLBadRequest.Errorf("expiresIn must be positive: %s", cmd.ExpiresIn)
+		}
+		expiresAt = getTime().Add(expiresIn).Unix()
+	}
+
 	uid := cmd.UID
 	if uid == "" {
 		var err error
@@ -59,6 +89,9 @@ func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedIn
 		if !util.IsValidShortUID(uid) {
 			return nil, shorturls.ErrShortURLBadRequest.Errorf("invalid UID: %s", uid)
 		}
+		if util.IsShortUIDTooLong(uid) {
+			return nil, shorturls.ErrShortURLBadRequest.Errorf("UID is longer than %d characters: %s", util.MaxUIDLength, uid)
+		}
 
 		// Check if the UID already exists
 		existingShortURL, err := s.SQLStore.Get(ctx, user, uid)
@@ -80,6 +113,8 @@ func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedIn
 		Path:      relPath,
 		CreatedBy: user.UserID,
 		CreatedAt: now,
+		ExpiresAt: expiresAt,
+		MaxUses:   cmd.MaxUses,
 	}
 
 	if err := s.SQLStore.Insert(ctx, &shortURL); err != nil {
@@ -89,6 +124,35 @@ func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedIn
 	return &shortURL, nil
 }
 
+func (s ShortURLService) SearchShortURLs(ctx context.Context, query *shorturls.SearchShortURLsQuery) (*shorturls.SearchShortURLsResult, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultSearchLimit
+	}
+	if query.Limit > maxSearchLimit {
+		query.Limit = maxSearchLimit
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	return s.SQLStore.Search(ctx, query)
+}
+
+func (s ShortURLService) DeleteShortURL(ctx context.Context, user *user.SignedInUser, uid string) error {
+	shortURL, err := s.SQLStore.Get(ctx, user, uid)
+	if err != nil {
+		return err
+	}
+	if shortURL.CreatedBy != user.UserID && !user.HasRole(identity.RoleAdmin) {
+		return shorturls.ErrShortURLForbidden.Errorf("short URL %s was created by another user", uid)
+	}
+
+	cmd := shorturls.DeleteShortUrlCommand{Uid: uid, OrgId: user.OrgID}
+	if err := s.SQLStore.Delete(ctx, &cmd); err != nil {
+		return shorturls.ErrShortURLInternal.Errorf("failed to delete short URL: %w", err)
+	}
+	return nil
+}
+
 func (s ShortURLService) DeleteStaleShortURLs(ctx context.Context, cmd *shorturls.DeleteShortUrlCommand) error {
 	return s.SQLStore.Delete(ctx, cmd)
 }
@@ -97,7 +161,13 @@ func (s ShortURLService) ConvertShortURLToDTO(shortURL *shorturls.ShortUrl, appU
 	url := fmt.Sprintf("%s/goto/%s?orgId=%d", strings.TrimSuffix(appURL, "/"), shortURL.Uid, shortURL.OrgId)
 
 	return &dtos.ShortURL{
-		UID: shortURL.Uid,
-		URL: url,
+		UID:        shortURL.Uid,
+		URL:        url,
+		Path:       shortURL.Path,
+		CreatedAt:  shortURL.CreatedAt,
+		LastSeenAt: shortURL.LastSeenAt,
+		ExpiresAt:  shortURL.ExpiresAt,
+		MaxUses:    shortURL.MaxUses,
+		UseCount:   shortURL.UseCount,
 	}
 }
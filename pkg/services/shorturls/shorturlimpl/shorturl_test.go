@@ -9,6 +9,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/tests/testsuite"
@@ -181,4 +182,66 @@ func TestIntegrationShortURLService(t *testing.T) {
 		require.ErrorIs(t, err, shorturls.ErrShortURLConflict)
 		require.Nil(t, newShortURL2)
 	})
+
+	t.Run("Short URLs expire after their expiry time or max uses", func(t *testing.T) {
+		service := ShortURLService{SQLStore: &sqlStore{db: store}}
+		ctx := context.Background()
+
+		limited, err := service.CreateShortURL(ctx, user, &dtos.CreateShortURLCmd{Path: "mock/path?test=true", MaxUses: 1})
+		require.NoError(t, err)
+		require.NoError(t, service.UpdateLastSeenAt(ctx, limited))
+		require.ErrorIs(t, service.UpdateLastSeenAt(ctx, limited), shorturls.ErrShortURLExpired)
+
+		_, err = service.GetShortURLByUID(ctx, user, limited.Uid)
+		require.ErrorIs(t, err, shorturls.ErrShortURLExpired)
+
+		expiring, err := service.CreateShortURL(ctx, user, &dtos.CreateShortURLCmd{Path: "mock/path?test=true", ExpiresIn: "1h"})
+		require.NoError(t, err)
+		_, err = service.GetShortURLByUID(ctx, user, expiring.Uid)
+		require.NoError(t, err)
+
+		origGetTime := getTime
+		t.Cleanup(func() {
+			getTime = origGetTime
+		})
+		getTime = func() time.Time {
+			return time.Now().Add(2 * time.Hour)
+		}
+
+		_, err = service.GetShortURLByUID(ctx, user, expiring.Uid)
+		require.ErrorIs(t, err, shorturls.ErrShortURLExpired)
+
+		cmd := shorturls.DeleteShortUrlCommand{Expired: true}
+		require.NoError(t, service.DeleteStaleShortURLs(ctx, &cmd))
+		require.Equal(t, int64(2), cmd.NumDeleted)
+
+		_, err = service.CreateShortURL(ctx, user, &dtos.CreateShortURLCmd{Path: "mock/path?test=true", ExpiresIn: "-1h"})
+		require.ErrorIs(t, err, shorturls.ErrShortURLBadRequest)
+	})
+
+	t.Run("Users can list and delete their short URLs", func(t *testing.T) {
+		service := ShortURLService{SQLStore: &sqlStore{db: store}}
+		ctx := context.Background()
+		owner := &user.SignedInUser{UserID: 10, OrgID: 2, OrgRole: org.RoleEditor}
+		other := &user.SignedInUser{UserID: 11, OrgID: 2, OrgRole: org.RoleEditor}
+
+		for i := 0; i < 3; i++ {
+			_, err := service.CreateShortURL(ctx, owner, &dtos.CreateShortURLCmd{Path: "mock/path?test=true"})
+			require.NoError(t, err)
+		}
+		otherShortURL, err := service.CreateShortURL(ctx, other, &dtos.CreateShortURLCmd{Path: "mock/path?test=true"})
+		require.NoError(t, err)
+
+		result, err := service.SearchShortURLs(ctx, &shorturls.SearchShortURLsQuery{OrgID: 2, CreatedBy: owner.UserID, Limit: 2})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), result.TotalCount)
+		require.Len(t, result.ShortURLs, 2)
+
+		err = service.DeleteShortURL(ctx, owner, otherShortURL.Uid)
+		require.ErrorIs(t, err, shorturls.ErrShortURLForbidden)
+
+		require.NoError(t, service.DeleteShortURL(ctx, other, otherShortURL.Uid))
+		_, err = service.GetShortURLByUID(ctx, other, otherShortURL.Uid)
+		require.ErrorIs(t, err, shorturls.ErrShortURLNotFound)
+	})
 }
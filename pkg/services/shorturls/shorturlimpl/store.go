@@ -12,6 +12,7 @@ type store interface {
 	Get(ctx context.Context, user *user.SignedInUser, uid string) (*shorturls.ShortUrl, error)
 	Update(ctx context.Context, shortURL *shorturls.ShortUrl) error
 	Insert(ctx context.Context, shortURL *shorturls.ShortUrl) error
+	Search(ctx context.Context, query *shorturls.SearchShortURLsQuery) (*shorturls.SearchShortURLsResult, error)
 	Delete(ctx context.Context, cmd *shorturls.DeleteShortUrlCommand) error
 }
 
@@ -39,13 +40,27 @@ func (s sqlStore) Get(ctx context.Context, user *user.SignedInUser, uid string)
 	return &shortURL, nil
 }
 
+// Update records a use of the short URL. The use count is only incremented while it's below the max uses, so that
+// concurrent uses can't exceed the limit.
 func (s sqlStore) Update(ctx context.Context, shortURL *shorturls.ShortUrl) error {
-	shortURL.LastSeenAt = getTime().Unix()
+	lastSeenAt := getTime().Unix()
 	return s.db.WithTransactionalDbSession(ctx, func(dbSession *db.Session) error {
-		_, err := dbSession.ID(shortURL.Id).Update(shortURL)
+		var rawSql = "UPDATE short_url SET last_seen_at = ?, use_count = use_count + 1 WHERE id = ? AND (max_uses = 0 OR use_count < max_uses)"
+
+		result, err := dbSession.Exec(rawSql, lastSeenAt, shortURL.Id)
+		if err != nil {
+			return err
+		}
+		updated, err := result.RowsAffected()
 		if err != nil {
 			return err
 		}
+		if updated == 0 {
+			return shorturls.ErrShortURLExpired.Errorf("short URL reached its max uses")
+		}
+
+		shortURL.LastSeenAt = lastSeenAt
+		shortURL.UseCount++
 		return nil
 	})
 }
@@ -57,13 +72,60 @@ func (s sqlStore) Insert(ctx context.Context, shortURL *shorturls.ShortUrl) erro
 	})
 }
 
+func (s sqlStore) Search(ctx context.Context, query *shorturls.SearchShortURLsQuery) (*shorturls.SearchShortURLsResult, error) {
+	result := shorturls.SearchShortURLsResult{
+		ShortURLs: make([]*shorturls.ShortUrl, 0),
+		Page:      query.Page,
+		PerPage:   query.Limit,
+	}
+	err := s.db.WithDbSession(ctx, func(dbSession *db.Session) error {
+		where, args := "org_id = ?", []any{query.OrgID}
+		if query.CreatedBy > 0 {
+			where += " AND created_by = ?"
+			args = append(args, query.CreatedBy)
+		}
+
+		count, err := dbSession.Where(where, args...).Count(&shorturls.ShortUrl{})
+		if err != nil {
+			return err
+		}
+		result.TotalCount = count
+
+		return dbSession.Where(where, args...).Desc("created_at", "id").Limit(query.Limit, (query.Page-1)*query.Limit).Find(&result.ShortURLs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
 func (s sqlStore) Delete(ctx context.Context, cmd *shorturls.DeleteShortUrlCommand) error {
 	// If a UID is provided, delete that specific short URL
 	if cmd.Uid != "" {
 		return s.db.WithTransactionalDbSession(ctx, func(session *db.Session) error {
 			var rawSql = "DELETE FROM short_url WHERE uid = ?"
+			args := []any{cmd.Uid}
+			if cmd.OrgId > 0 {
+				rawSql += " AND org_id = ?"
+				args = append(args, cmd.OrgId)
+			}
+
+			if result, err := session.Exec(append([]any{rawSql}, args...)...); err != nil {
+				return err
+			} else if cmd.NumDeleted, err = result.RowsAffected(); err != nil {
+				return err
+			}
+			return nil
+		})
+	}
+
+	// Delete the short URLs which expired or were used as many times as allowed
+	if cmd.Expired {
+		return s.db.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+			var rawSql = "DELETE FROM short_url WHERE (expires_at > 0 AND expires_at <= ?) OR (max_uses > 0 AND use_count >= max_uses)"
 
-			if result, err := session.Exec(rawSql, cmd.Uid); err != nil {
+			if result, err := session.Exec(rawSql, getTime().Unix()); err != nil {
 				return err
 			} else if cmd.NumDeleted, err = result.RowsAffected(); err != nil {
 				return err
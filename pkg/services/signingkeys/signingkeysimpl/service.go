@@ -38,7 +38,7 @@ func ProvideEmbeddedSigningKeysService(dbStore db.DB, secretsService secrets.Ser
 		log:            log.New("auth.key_service"),
 		store:          signingkeystore.NewSigningKeyStore(dbStore),
 		secretsService: secretsService,
-		remoteCache:    remoteCache,
+		remoteCache:    remotecache.WithUsage(remoteCache, "signing keys"),
 		localCache:     localcache.New(1*time.Hour, 1*time.Hour),
 	}
 
@@ -8,6 +8,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
 
@@ -48,6 +49,23 @@ type DatabaseConfig struct {
 	QueryRetries int
 	// SQLite only
 	TransactionRetries int
+	// SQLite only, the number of milliseconds a query waits for the locks held by other connections
+	BusyTimeout int
+	// SQLite only, the synchronous mode: off, normal, full or extra
+	Synchronous string
+
+	// Replicas are the read-only copies of the database serving the reads which opted in
+	Replicas []*DatabaseConfig
+	// ReplicaMaxLag is the replication delay above which a replica stops serving reads
+	ReplicaMaxLag time.Duration
+	// ReplicaLagCheckInterval is the interval between the checks of the replication delay
+	ReplicaLagCheckInterval time.Duration
+	// SlowQueryThreshold is the duration above which a query is logged as slow, 0 disables the slow query log
+	SlowQueryThreshold time.Duration
+	// EventOutbox writes the events published after the commit of transactions to the event_outbox table
+	EventOutbox bool
+	// EventOutboxRedeliveryDelay is the delay after which the events which weren't delivered after the commit are redelivered
+	EventOutboxRedeliveryDelay time.Duration
 }
 
 func NewDatabaseConfig(cfg *setting.Cfg, features featuremgmt.FeatureToggles) (*DatabaseConfig, error) {
@@ -64,6 +82,10 @@ func NewDatabaseConfig(cfg *setting.Cfg, features featuremgmt.FeatureToggles) (*
 		return nil, err
 	}
 
+	if err := dbCfg.readReplicas(cfg, features); err != nil {
+		return nil, err
+	}
+
 	return dbCfg, nil
 }
 
@@ -72,25 +94,9 @@ func (dbCfg *DatabaseConfig) readConfig(cfg *setting.Cfg) error {
 
 	cfgURL := sec.Key("url").String()
 	if len(cfgURL) != 0 {
-		dbURL, err := url.Parse(cfgURL)
-		if err != nil {
+		if err := dbCfg.readURL(cfgURL); err != nil {
 			return err
 		}
-		dbCfg.Type = dbURL.Scheme
-		dbCfg.Host = dbURL.Host
-
-		pathSplit := strings.Split(dbURL.Path, "/")
-		if len(pathSplit) > 1 {
-			dbCfg.Name = pathSplit[1]
-		}
-
-		userInfo := dbURL.User
-		if userInfo != nil {
-			dbCfg.User = userInfo.Username()
-			dbCfg.Pwd, _ = userInfo.Password()
-		}
-
-		dbCfg.UrlQueryParams = dbURL.Query()
 	} else {
 		dbCfg.Type = sec.Key("type").String()
 		dbCfg.Host = sec.Key("host").String()
@@ -118,6 +124,8 @@ func (dbCfg *DatabaseConfig) readConfig(cfg *setting.Cfg) error {
 
 	dbCfg.CacheMode = sec.Key("cache_mode").MustString("private")
 	dbCfg.WALEnabled = sec.Key("wal").MustBool(false)
+	dbCfg.BusyTimeout = sec.Key("busy_timeout").MustInt(0)
+	dbCfg.Synchronous = strings.ToLower(sec.Key("synchronous").String())
 	dbCfg.SkipMigrations = sec.Key("skip_migrations").MustBool()
 	dbCfg.EnsureDefaultOrgAndUser = sec.Key("ensure_default_org_and_user").MustBool(true)
 	dbCfg.MigrationLock = sec.Key("migration_locking").MustBool(true)
@@ -128,6 +136,77 @@ func (dbCfg *DatabaseConfig) readConfig(cfg *setting.Cfg) error {
 
 	dbCfg.LogQueries = sec.Key("log_queries").MustBool(false)
 	dbCfg.DeleteAutoGenIDs = sec.Key("delete_auto_gen_ids").MustBool(false)
+	dbCfg.SlowQueryThreshold = sec.Key("slow_query_threshold").MustDuration(0)
+	dbCfg.EventOutbox = sec.Key("event_outbox").MustBool(true)
+	dbCfg.EventOutboxRedeliveryDelay = sec.Key("event_outbox_redelivery_delay").MustDuration(time.Minute)
+
+	dbCfg.ReplicaMaxLag = sec.Key("replica_max_lag").MustDuration(5 * time.Second)
+	dbCfg.ReplicaLagCheckInterval = sec.Key("replica_lag_check_interval").MustDuration(10 * time.Second)
+
+	return nil
+}
+
+func (dbCfg *DatabaseConfig) readURL(cfgURL string) error {
+	dbURL, err := url.Parse(cfgURL)
+	if err != nil {
+		return err
+	}
+	dbCfg.Type = dbURL.Scheme
+	dbCfg.Host = dbURL.Host
+
+	pathSplit := strings.Split(dbURL.Path, "/")
+	if len(pathSplit) > 1 {
+		dbCfg.Name = pathSplit[1]
+	}
+
+	userInfo := dbURL.User
+	if userInfo != nil {
+		dbCfg.User = userInfo.Username()
+		dbCfg.Pwd, _ = userInfo.Password()
+	}
+
+	dbCfg.UrlQueryParams = dbURL.Query()
+	return nil
+}
+
+// readReplicas reads the urls of the read replicas. A replica uses the settings of the primary database,
+// except for the host, name, credentials and parameters set in its url.
+func (dbCfg *DatabaseConfig) readReplicas(cfg *setting.Cfg, features featuremgmt.FeatureToggles) error {
+	urls := util.SplitString(cfg.Raw.Section("database").Key("replica_urls").String())
+	if len(urls) == 0 {
+		return nil
+	}
+
+	if dbCfg.Type == migrator.SQLite {
+		return errors.New("database replicas are not supported with sqlite3")
+	}
+
+	for i, replicaURL := range urls {
+		replica := &DatabaseConfig{
+			SslMode:         dbCfg.SslMode,
+			SSLSNI:          dbCfg.SSLSNI,
+			CaCertPath:      dbCfg.CaCertPath,
+			ClientKeyPath:   dbCfg.ClientKeyPath,
+			ClientCertPath:  dbCfg.ClientCertPath,
+			ServerCertName:  dbCfg.ServerCertName,
+			IsolationLevel:  dbCfg.IsolationLevel,
+			MaxOpenConn:     dbCfg.MaxOpenConn,
+			MaxIdleConn:     dbCfg.MaxIdleConn,
+			ConnMaxLifetime: dbCfg.ConnMaxLifetime,
+			LogQueries:      dbCfg.LogQueries,
+		}
+		// the parse error isn't returned as it contains the url and its password
+		if err := replica.readURL(replicaURL); err != nil {
+			return fmt.Errorf("invalid url of replica %d", i)
+		}
+		if replica.Type != dbCfg.Type {
+			return fmt.Errorf("replica %s is a %s database, expected %s", replica.Host, replica.Type, dbCfg.Type)
+		}
+		if err := replica.buildConnectionString(cfg, features); err != nil {
+			return err
+		}
+		dbCfg.Replicas = append(dbCfg.Replicas, replica)
+	}
 
 	return nil
 }
@@ -208,6 +287,16 @@ func (dbCfg *DatabaseConfig) buildConnectionString(cfg *setting.Cfg, features fe
 		if dbCfg.WALEnabled {
 			cnnstr += "&_journal_mode=WAL"
 		}
+		if dbCfg.BusyTimeout > 0 {
+			cnnstr += fmt.Sprintf("&_busy_timeout=%d", dbCfg.BusyTimeout)
+		}
+		switch dbCfg.Synchronous {
+		case "":
+		case "off", "normal", "full", "extra":
+			cnnstr += "&_synchronous=" + strings.ToUpper(dbCfg.Synchronous)
+		default:
+			return fmt.Errorf("invalid synchronous mode %q for sqlite3, must be off, normal, full or extra", dbCfg.Synchronous)
+		}
 
 		cnnstr += buildExtraConnectionString('&', dbCfg.UrlQueryParams)
 	default:
@@ -0,0 +1,95 @@
+package dbcopy
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister) {
+	router.Group("/api/admin/database/migration", func(migrationRoute routing.RouteRegister) {
+		migrationRoute.Get("/", routing.Wrap(s.getMigration))
+		migrationRoute.Post("/", routing.Wrap(s.startMigration))
+		migrationRoute.Delete("/", routing.Wrap(s.cancelMigration))
+	}, middleware.ReqGrafanaAdmin)
+}
+
+// swagger:route POST /admin/database/migration admin startDatabaseMigration
+//
+// # Start copying the database to another database
+//
+// Creates the schema of the target database, copies all the rows in batches while Grafana is running,
+// and verifies the row counts and checksums of the tables. Only one migration runs at a time.
+//
+// Responses:
+// 202: databaseMigrationResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) startMigration(c *contextmodel.ReqContext) response.Response {
+	opts := Options{}
+	if err := web.Bind(c.Req, &opts); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	progress, err := s.Start(opts)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to start database migration", err)
+	}
+	return response.JSON(http.StatusAccepted, progress)
+}
+
+// swagger:route GET /admin/database/migration admin getDatabaseMigration
+//
+// # Get the progress of the database migration
+//
+// Returns the progress of the last started migration, and the cutover instructions once it completed.
+//
+// Responses:
+// 200: databaseMigrationResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) getMigration(c *contextmodel.ReqContext) response.Response {
+	progress, err := s.Progress()
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get database migration", err)
+	}
+	return response.JSON(http.StatusOK, progress)
+}
+
+// swagger:route DELETE /admin/database/migration admin cancelDatabaseMigration
+//
+// # Cancel the running database migration
+//
+// The target database keeps the rows copied before the cancellation.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) cancelMigration(c *contextmodel.ReqContext) response.Response {
+	s.Cancel()
+	return response.Success("Database migration canceled")
+}
+
+// swagger:parameters startDatabaseMigration
+type StartDatabaseMigrationParams struct {
+	// in:body
+	// required:true
+	Body Options `json:"body"`
+}
+
+// swagger:response databaseMigrationResponse
+type DatabaseMigrationResponse struct {
+	// in:body
+	Body Progress `json:"body"`
+}
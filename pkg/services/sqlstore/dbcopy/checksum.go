@@ -0,0 +1,62 @@
+package dbcopy
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// checksum of the rows of a table which doesn't depend on the order of the rows, as the databases
+// don't sort the strings the same way
+type checksum struct {
+	rows int64
+	sum  uint64
+}
+
+func (c *checksum) addRows(rows [][]any) error {
+	for _, row := range rows {
+		h := sha256.New()
+		for _, value := range row {
+			h.Write([]byte(normalize(value)))
+			h.Write([]byte{0x1f})
+		}
+		c.sum += binary.BigEndian.Uint64(h.Sum(nil)[:8])
+		c.rows++
+	}
+	return nil
+}
+
+func (c checksum) String() string {
+	return fmt.Sprintf("%016x", c.sum)
+}
+
+// normalize formats a value read by any of the drivers the same way: the booleans stored as integers by
+// SQLite and MySQL, the values read as bytes by MySQL and the times without fractional seconds in MySQL
+// are equal to the values read from the other databases
+func normalize(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "\x00"
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case time.Time:
+		return v.UTC().Truncate(time.Second).Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,495 @@
+package dbcopy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/xorm"
+	"github.com/grafana/grafana/pkg/util/xorm/core"
+)
+
+// migrationLogTable is filled by the migrations creating the schema of the target database, it isn't copied
+const migrationLogTable = "migration_log"
+
+// maxInsertParams bounds the parameters of a multi-row insert, SQLite doesn't accept more than 999 before 3.32
+var maxInsertParams = map[string]int{
+	migrator.SQLite:   999,
+	migrator.MySQL:    65535,
+	migrator.Postgres: 65535,
+}
+
+// Copier copies all the rows of the Grafana database to another database while Grafana is running.
+// The tables of the target database are created by the migrations, then the rows are copied table by table
+// in batches, and the row counts and checksums of the tables are compared. The writes made during the copy
+// are reported by the verification, they're copied by running the copy again with Options.Overwrite once
+// Grafana is stopped.
+type Copier struct {
+	cfg        *setting.Cfg
+	source     *xorm.Engine
+	migrations registry.DatabaseMigrator
+	opts       Options
+	log        log.Logger
+
+	mu       sync.Mutex
+	progress Progress
+}
+
+func NewCopier(cfg *setting.Cfg, source *xorm.Engine, migrations registry.DatabaseMigrator, opts Options) (*Copier, error) {
+	if _, ok := maxInsertParams[opts.TargetType]; !ok {
+		return nil, ErrUnsupportedTargetType.Errorf("unsupported target database type %q", opts.TargetType)
+	}
+	if opts.TargetConnectionString == "" {
+		return nil, ErrTargetRequired.Errorf("target database connection string is empty")
+	}
+	if opts.TargetType == source.DriverName() && opts.TargetConnectionString == source.DataSourceName() {
+		return nil, ErrSameDatabase.Errorf("target database is the current database")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+
+	return &Copier{
+		cfg:        cfg,
+		source:     source,
+		migrations: migrations,
+		opts:       opts,
+		log:        log.New("dbcopy"),
+		progress: Progress{
+			State:      StateRunning,
+			Phase:      PhaseSchema,
+			SourceType: source.DriverName(),
+			TargetType: opts.TargetType,
+			StartedAt:  time.Now(),
+		},
+	}, nil
+}
+
+// Progress returns a snapshot of the progress of the copy
+func (c *Copier) Progress() Progress {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p := c.progress
+	p.Tables = append([]TableProgress(nil), c.progress.Tables...)
+	return p
+}
+
+// Run copies the database, it returns once the copy and its verification completed
+func (c *Copier) Run(ctx context.Context) (err error) {
+	defer func() { c.finish(err) }()
+
+	target, err := xorm.NewEngine(c.opts.TargetType, withMySQLParseTime(c.opts.TargetType, c.opts.TargetConnectionString))
+	if err != nil {
+		return fmt.Errorf("failed to connect to the target database: %w", err)
+	}
+	defer func() {
+		if err := target.Close(); err != nil {
+			c.log.Warn("Failed to close the target database", "error", err)
+		}
+	}()
+	target.SetLogger(&xorm.DiscardLogger{})
+	if err := target.Ping(); err != nil {
+		return fmt.Errorf("failed to connect to the target database: %w", err)
+	}
+
+	c.log.Info("Creating the schema of the target database", "targetType", c.opts.TargetType)
+	plans, err := c.createSchema(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	c.setPhase(PhaseCopy)
+	for _, plan := range plans {
+		if err := c.copyTable(ctx, target, plan); err != nil {
+			return fmt.Errorf("failed to copy table %s: %w", plan.name, err)
+		}
+	}
+
+	c.setPhase(PhaseVerify)
+	verified := true
+	for _, plan := range plans {
+		ok, err := c.verifyTable(ctx, target, plan)
+		if err != nil {
+			return fmt.Errorf("failed to verify table %s: %w", plan.name, err)
+		}
+		verified = verified && ok
+	}
+
+	c.mu.Lock()
+	c.progress.Verified = verified
+	c.progress.CutoverInstructions = cutoverInstructions(c.opts.TargetType, verified)
+	c.mu.Unlock()
+	return nil
+}
+
+// tablePlan describes how the rows of a table are copied
+type tablePlan struct {
+	name string
+	// index of the table in Progress.Tables
+	index int
+	// columns are the columns existing in both databases
+	columns []string
+	// blobs are the columns stored as bytes in the target database, the other columns read as bytes are
+	// written as strings
+	blobs map[string]bool
+	// key is the single integer primary key used to read the rows in batches, the rows of the tables
+	// without one are read in the order of orderBy
+	key     string
+	orderBy []string
+	// autoIncrement is the column of the target table whose sequence follows the copied ids
+	autoIncrement string
+}
+
+// createSchema runs the migrations on the target database, creates the tables the migrations don't create,
+// and empties the target tables
+func (c *Copier) createSchema(ctx context.Context, target *xorm.Engine) ([]*tablePlan, error) {
+	existing, err := target.DBMetas()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the schema of the target database: %w", err)
+	}
+	if len(existing) > 0 && !c.opts.Overwrite {
+		return nil, ErrTargetNotEmpty.Errorf("target database has %d tables", len(existing))
+	}
+
+	mg := migrator.NewMigrator(target, c.cfg)
+	c.migrations.AddMigration(mg)
+	if err := mg.RunMigrations(ctx, false, 0); err != nil {
+		return nil, fmt.Errorf("failed to migrate the target database: %w", err)
+	}
+
+	sourceTables, err := c.source.DBMetas()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the schema of the source database: %w", err)
+	}
+	targetTables, err := target.DBMetas()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the schema of the target database: %w", err)
+	}
+	targetByName := make(map[string]*core.Table, len(targetTables))
+	for _, t := range targetTables {
+		targetByName[t.Name] = t
+	}
+	sort.Slice(sourceTables, func(i, j int) bool { return sourceTables[i].Name < sourceTables[j].Name })
+
+	plans := make([]*tablePlan, 0, len(sourceTables))
+	tables := make([]TableProgress, 0, len(sourceTables))
+	for _, st := range sourceTables {
+		if st.Name == migrationLogTable {
+			continue
+		}
+
+		tt, created := targetByName[st.Name], false
+		if tt == nil {
+			if err := createTable(target, st); err != nil {
+				return nil, fmt.Errorf("failed to create table %s: %w", st.Name, err)
+			}
+			tt, created = st, true
+		}
+
+		plan := newTablePlan(st, tt)
+		if len(plan.columns) == 0 {
+			continue
+		}
+		for _, column := range st.ColumnsSeq() {
+			if tt.GetColumn(column) == nil {
+				c.log.Warn("Column doesn't exist in the target database, it isn't copied", "table", st.Name, "column", column)
+			}
+		}
+
+		// the migrations can insert rows, and an overwritten target has the rows of the previous copy
+		if _, err := target.Exec("DELETE FROM " + target.Quote(plan.name)); err != nil {
+			return nil, fmt.Errorf("failed to empty table %s: %w", plan.name, err)
+		}
+
+		rows, err := countRows(ctx, c.source, plan.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count the rows of table %s: %w", plan.name, err)
+		}
+
+		plan.index = len(tables)
+		plans = append(plans, plan)
+		tables = append(tables, TableProgress{Name: plan.name, Created: created, SourceRows: rows})
+	}
+
+	c.mu.Lock()
+	c.progress.Tables = tables
+	c.mu.Unlock()
+	return plans, nil
+}
+
+func createTable(target *xorm.Engine, table *core.Table) error {
+	dialect := target.Dialect()
+	if _, err := target.Exec(dialect.CreateTableSql(table, table.Name, "", "")); err != nil {
+		return err
+	}
+	for _, index := range table.Indexes {
+		if _, err := target.Exec(dialect.CreateIndexSql(table.Name, index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newTablePlan(source, target *core.Table) *tablePlan {
+	plan := &tablePlan{name: source.Name, blobs: map[string]bool{}}
+	for _, col := range source.Columns() {
+		targetCol := target.GetColumn(col.Name)
+		if targetCol == nil {
+			continue
+		}
+		plan.columns = append(plan.columns, col.Name)
+		if targetCol.SQLType.IsBlob() {
+			plan.blobs[col.Name] = true
+		}
+	}
+
+	pk := source.PrimaryKeys
+	switch {
+	case len(pk) == 1 && source.GetColumn(pk[0]).SQLType.IsNumeric():
+		plan.key = pk[0]
+	case len(pk) > 0:
+		plan.orderBy = pk
+	default:
+		plan.orderBy = plan.columns
+	}
+	if target.AutoIncrement != "" && target.GetColumn(target.AutoIncrement) != nil && source.GetColumn(target.AutoIncrement) != nil {
+		plan.autoIncrement = target.AutoIncrement
+	}
+	return plan
+}
+
+func (c *Copier) copyTable(ctx context.Context, target *xorm.Engine, plan *tablePlan) error {
+	c.log.Debug("Copying table", "table", plan.name)
+
+	rowsPerInsert := max(1, min(c.opts.BatchSize, maxInsertParams[c.opts.TargetType]/len(plan.columns)))
+	err := scanTable(ctx, c.source, plan, c.opts.BatchSize, func(rows [][]any) error {
+		for start := 0; start < len(rows); start += rowsPerInsert {
+			if err := insertRows(ctx, target, plan, rows[start:min(start+rowsPerInsert, len(rows))]); err != nil {
+				return err
+			}
+		}
+
+		c.mu.Lock()
+		c.progress.Tables[plan.index].CopiedRows += int64(len(rows))
+		c.mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.opts.TargetType == migrator.Postgres && plan.autoIncrement != "" {
+		return resetSequence(ctx, target, plan)
+	}
+	return nil
+}
+
+func insertRows(ctx context.Context, target *xorm.Engine, plan *tablePlan, rows [][]any) error {
+	quoted := make([]string, len(plan.columns))
+	for i, column := range plan.columns {
+		quoted[i] = target.Quote(column)
+	}
+	placeholders := "(" + strings.TrimSuffix(strings.Repeat("?,", len(plan.columns)), ",") + ")"
+
+	var sql strings.Builder
+	sql.WriteString("INSERT INTO " + target.Quote(plan.name) + " (" + strings.Join(quoted, ", ") + ") VALUES ")
+	args := make([]any, 0, len(rows)*len(plan.columns)+1)
+	args = append(args, "")
+	for i, row := range rows {
+		if i > 0 {
+			sql.WriteString(", ")
+		}
+		sql.WriteString(placeholders)
+		for j, value := range row {
+			// the MySQL driver reads the values as bytes, which are only written as such into blobs
+			if b, ok := value.([]byte); ok && !plan.blobs[plan.columns[j]] {
+				value = string(b)
+			}
+			args = append(args, value)
+		}
+	}
+	args[0] = sql.String()
+
+	sess := target.NewSession().Context(ctx)
+	defer sess.Close()
+	_, err := sess.Exec(args...)
+	return err
+}
+
+// resetSequence moves the sequence of the auto incremented column after the copied ids, as the inserts with
+// explicit ids don't advance it in Postgres
+func resetSequence(ctx context.Context, target *xorm.Engine, plan *tablePlan) error {
+	column := target.Quote(plan.autoIncrement)
+	sql := "SELECT setval(pg_get_serial_sequence(?, ?), COALESCE(MAX(" + column + "), 0) + 1, false) FROM " + target.Quote(plan.name)
+
+	sess := target.NewSession().Context(ctx)
+	defer sess.Close()
+	_, err := sess.Exec(sql, plan.name, plan.autoIncrement)
+	return err
+}
+
+// verifyTable compares the row count and the checksum of the table in both databases
+func (c *Copier) verifyTable(ctx context.Context, target *xorm.Engine, plan *tablePlan) (bool, error) {
+	var source, copied checksum
+	if err := scanTable(ctx, c.source, plan, c.opts.BatchSize, source.addRows); err != nil {
+		return false, err
+	}
+	if err := scanTable(ctx, target, plan, c.opts.BatchSize, copied.addRows); err != nil {
+		return false, err
+	}
+	verified := source == copied
+	if !verified {
+		c.log.Warn("Table differs between the source and the target database", "table", plan.name,
+			"sourceRows", source.rows, "targetRows", copied.rows)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	table := &c.progress.Tables[plan.index]
+	table.TargetRows = copied.rows
+	table.SourceChecksum = source.String()
+	table.TargetChecksum = copied.String()
+	table.Verified = verified
+	return verified, nil
+}
+
+// scanTable reads the rows of the table in batches, the values of a row are in the order of the plan columns
+func scanTable(ctx context.Context, engine *xorm.Engine, plan *tablePlan, batchSize int, fn func(rows [][]any) error) error {
+	quoted := make([]string, len(plan.columns))
+	keyIndex := -1
+	for i, column := range plan.columns {
+		quoted[i] = engine.Quote(column)
+		if column == plan.key {
+			keyIndex = i
+		}
+	}
+	selectSQL := "SELECT " + strings.Join(quoted, ", ") + " FROM " + engine.Quote(plan.name)
+
+	var lastKey any
+	for offset := 0; ; offset += batchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var sql string
+		var args []any
+		switch {
+		case keyIndex >= 0 && lastKey != nil:
+			sql = fmt.Sprintf("%s WHERE %s > ? ORDER BY %s LIMIT %d", selectSQL, quoted[keyIndex], quoted[keyIndex], batchSize)
+			args = []any{lastKey}
+		case keyIndex >= 0:
+			sql = fmt.Sprintf("%s ORDER BY %s LIMIT %d", selectSQL, quoted[keyIndex], batchSize)
+		default:
+			orderBy := make([]string, len(plan.orderBy))
+			for i, column := range plan.orderBy {
+				orderBy[i] = engine.Quote(column)
+			}
+			sql = fmt.Sprintf("%s ORDER BY %s LIMIT %d OFFSET %d", selectSQL, strings.Join(orderBy, ", "), batchSize, offset)
+		}
+
+		results, err := queryRows(ctx, engine, sql, args...)
+		if err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			return nil
+		}
+
+		rows := make([][]any, len(results))
+		for i, result := range results {
+			rows[i] = make([]any, len(plan.columns))
+			for j, column := range plan.columns {
+				rows[i][j] = result[column]
+			}
+		}
+		if err := fn(rows); err != nil {
+			return err
+		}
+		if len(rows) < batchSize {
+			return nil
+		}
+		if keyIndex >= 0 {
+			lastKey = rows[len(rows)-1][keyIndex]
+		}
+	}
+}
+
+func queryRows(ctx context.Context, engine *xorm.Engine, sql string, args ...any) ([]map[string]any, error) {
+	sess := engine.NewSession().Context(ctx)
+	defer sess.Close()
+	return sess.QueryInterface(append([]any{sql}, args...)...)
+}
+
+func countRows(ctx context.Context, engine *xorm.Engine, table string) (int64, error) {
+	sess := engine.NewSession().Context(ctx)
+	defer sess.Close()
+
+	var count int64
+	_, err := sess.SQL("SELECT COUNT(*) FROM " + engine.Quote(table)).Get(&count)
+	return count, err
+}
+
+func (c *Copier) setPhase(phase Phase) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.progress.Phase = phase
+}
+
+func (c *Copier) finish(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.progress.FinishedAt = &now
+	switch {
+	case err == nil:
+		c.progress.State = StateCompleted
+		c.log.Info("Copied the database", "targetType", c.opts.TargetType, "tables", len(c.progress.Tables),
+			"verified", c.progress.Verified, "duration", now.Sub(c.progress.StartedAt))
+	case errors.Is(err, context.Canceled):
+		c.progress.State = StateCanceled
+		c.log.Info("Canceled the copy of the database", "phase", c.progress.Phase)
+	default:
+		c.progress.State = StateFailed
+		c.progress.Error = err.Error()
+		c.log.Error("Failed to copy the database", "phase", c.progress.Phase, "error", err)
+	}
+}
+
+// withMySQLParseTime enables parseTime for MySQL, as Grafana reads the times as time.Time
+func withMySQLParseTime(dbType, connectionString string) string {
+	if dbType != migrator.MySQL || strings.Contains(connectionString, "parseTime=") {
+		return connectionString
+	}
+	if strings.Contains(connectionString, "?") {
+		return connectionString + "&parseTime=true"
+	}
+	return connectionString + "?parseTime=true"
+}
+
+func cutoverInstructions(targetType string, verified bool) []string {
+	connection := "host, name, user and password, or url"
+	if targetType == migrator.SQLite {
+		connection = "path"
+	}
+
+	steps := []string{"Stop all the Grafana instances using the current database."}
+	if verified {
+		steps = append(steps, "Run the migration again with overwrite if Grafana saved changes since the copy started, eg: edited dashboards or alert state.")
+	} else {
+		steps = append(steps, "Some tables changed during the copy, run the migration again with overwrite now that Grafana is stopped, and check it's verified.")
+	}
+	return append(steps,
+		fmt.Sprintf("Set type = %s and the connection settings of the target database (%s) in the [database] section of the configuration.", targetType, connection),
+		"Start Grafana and check its logs, keep the previous database as a backup until the new one is in use.",
+	)
+}
@@ -0,0 +1,138 @@
+package dbcopy
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrations"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+	"github.com/grafana/grafana/pkg/util/xorm"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationCopier(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	source := db.InitTestDB(t)
+	_, err := source.GetEngine().Exec("INSERT INTO tag (`key`, `value`) VALUES ('env', 'prod'), ('env', 'dev'), ('team', '')")
+	require.NoError(t, err)
+
+	targetPath := filepath.Join(t.TempDir(), "target.db")
+	newCopier := func(overwrite bool) *Copier {
+		copier, err := NewCopier(setting.NewCfg(), source.GetEngine(), migrations.ProvideOSSMigrations(featuremgmt.WithFeatures()), Options{
+			TargetType:             migrator.SQLite,
+			TargetConnectionString: "file:" + targetPath + "?cache=shared&mode=rwc",
+			BatchSize:              2,
+			Overwrite:              overwrite,
+		})
+		require.NoError(t, err)
+		return copier
+	}
+
+	copier := newCopier(false)
+	require.NoError(t, copier.Run(context.Background()))
+
+	progress := copier.Progress()
+	require.Equal(t, StateCompleted, progress.State)
+	require.True(t, progress.Verified)
+	require.NotEmpty(t, progress.CutoverInstructions)
+	for _, table := range progress.Tables {
+		require.NotEqual(t, migrationLogTable, table.Name)
+		if table.Name == "tag" {
+			require.Equal(t, TableProgress{
+				Name:           "tag",
+				SourceRows:     3,
+				CopiedRows:     3,
+				TargetRows:     3,
+				SourceChecksum: table.SourceChecksum,
+				TargetChecksum: table.SourceChecksum,
+				Verified:       true,
+			}, table)
+		}
+	}
+
+	target, err := xorm.NewEngine(migrator.SQLite, targetPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = target.Close() })
+	count, err := target.Table("tag").Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(3), count)
+
+	t.Run("refuses a target with tables unless overwritten", func(t *testing.T) {
+		copier := newCopier(false)
+		require.ErrorIs(t, copier.Run(context.Background()), ErrTargetNotEmpty)
+		require.Equal(t, StateFailed, copier.Progress().State)
+
+		copier = newCopier(true)
+		require.NoError(t, copier.Run(context.Background()))
+		require.True(t, copier.Progress().Verified)
+	})
+}
+
+func TestNewCopier(t *testing.T) {
+	engine, err := xorm.NewEngine(migrator.SQLite, "file::memory:")
+	require.NoError(t, err)
+	oss := migrations.ProvideOSSMigrations(featuremgmt.WithFeatures())
+
+	_, err = NewCopier(setting.NewCfg(), engine, oss, Options{TargetType: "mssql", TargetConnectionString: "dsn"})
+	require.ErrorIs(t, err, ErrUnsupportedTargetType)
+
+	_, err = NewCopier(setting.NewCfg(), engine, oss, Options{TargetType: migrator.Postgres})
+	require.ErrorIs(t, err, ErrTargetRequired)
+
+	_, err = NewCopier(setting.NewCfg(), engine, oss, Options{TargetType: migrator.SQLite, TargetConnectionString: "file::memory:"})
+	require.ErrorIs(t, err, ErrSameDatabase)
+
+	copier, err := NewCopier(setting.NewCfg(), engine, oss, Options{TargetType: migrator.MySQL, TargetConnectionString: "grafana:secret@tcp(db:3306)/grafana"})
+	require.NoError(t, err)
+	require.Equal(t, defaultBatchSize, copier.opts.BatchSize)
+	require.Equal(t, Progress{
+		State:      StateRunning,
+		Phase:      PhaseSchema,
+		SourceType: migrator.SQLite,
+		TargetType: migrator.MySQL,
+		StartedAt:  copier.progress.StartedAt,
+	}, copier.Progress())
+}
+
+func TestChecksum(t *testing.T) {
+	at := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+
+	// the values of a row as read by the SQLite, MySQL and Postgres drivers
+	var sqlite, mysql, postgres checksum
+	require.NoError(t, sqlite.addRows([][]any{
+		{int64(1), "prod", int64(1), at.Add(250 * time.Millisecond), nil},
+		{int64(2), "dev", int64(0), at, 0.5},
+	}))
+	require.NoError(t, mysql.addRows([][]any{
+		{[]byte("2"), []byte("dev"), []byte("0"), at, []byte("0.5")},
+		{[]byte("1"), []byte("prod"), []byte("1"), at, nil},
+	}))
+	require.NoError(t, postgres.addRows([][]any{
+		{int64(1), "prod", true, at.In(time.FixedZone("CEST", 2*3600)), nil},
+		{int64(2), "dev", false, at, float64(0.5)},
+	}))
+	require.Equal(t, sqlite, mysql)
+	require.Equal(t, sqlite, postgres)
+	require.Equal(t, int64(2), sqlite.rows)
+
+	var changed checksum
+	require.NoError(t, changed.addRows([][]any{
+		{int64(1), "prod", int64(1), at, nil},
+		{int64(2), "dev", int64(1), at, 0.5},
+	}))
+	require.NotEqual(t, sqlite.String(), changed.String())
+}
@@ -0,0 +1,88 @@
+package dbcopy
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+var (
+	ErrUnsupportedTargetType = errutil.BadRequest(
+		"dbcopy.unsupported-target-type", errutil.WithPublicMessage("Target database type must be sqlite3, mysql or postgres"))
+	ErrTargetRequired = errutil.BadRequest(
+		"dbcopy.target-required", errutil.WithPublicMessage("Target database connection string is required"))
+	ErrSameDatabase = errutil.BadRequest(
+		"dbcopy.same-database", errutil.WithPublicMessage("Target database is the current database"))
+	ErrTargetNotEmpty = errutil.Conflict(
+		"dbcopy.target-not-empty", errutil.WithPublicMessage("Target database already has tables, set overwrite to replace their data"))
+	ErrAlreadyRunning = errutil.Conflict(
+		"dbcopy.already-running", errutil.WithPublicMessage("A database migration is already running"))
+	ErrNotFound = errutil.NotFound(
+		"dbcopy.not-found", errutil.WithPublicMessage("No database migration was started"))
+)
+
+// defaultBatchSize is the number of rows read and written at once when the options don't set it
+const defaultBatchSize = 1000
+
+// Options of a copy of the Grafana database to another database
+type Options struct {
+	// TargetType is the type of the target database: sqlite3, mysql or postgres
+	TargetType string `json:"targetType"`
+	// TargetConnectionString is the DSN of the target database, in the format of its driver
+	TargetConnectionString string `json:"targetConnectionString"`
+	// BatchSize is the number of rows read and written at once
+	BatchSize int `json:"batchSize"`
+	// Overwrite replaces the data of a target database which already has tables, eg: to copy again
+	// the writes made during a previous copy
+	Overwrite bool `json:"overwrite"`
+}
+
+type State string
+
+const (
+	StateRunning   State = "running"
+	StateCompleted State = "completed"
+	StateFailed    State = "failed"
+	StateCanceled  State = "canceled"
+)
+
+type Phase string
+
+const (
+	// PhaseSchema creates the tables of the target database
+	PhaseSchema Phase = "schema"
+	// PhaseCopy copies the rows of the tables in batches
+	PhaseCopy Phase = "copy"
+	// PhaseVerify compares the row counts and the checksums of the tables in both databases
+	PhaseVerify Phase = "verify"
+)
+
+// Progress of a copy, the connection string of the target database is never reported
+type Progress struct {
+	State      State      `json:"state"`
+	Phase      Phase      `json:"phase"`
+	SourceType string     `json:"sourceType"`
+	TargetType string     `json:"targetType"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	// Verified is set when the row counts and the checksums of all the tables match
+	Verified bool            `json:"verified"`
+	Tables   []TableProgress `json:"tables"`
+	Error    string          `json:"error,omitempty"`
+	// CutoverInstructions are the steps to switch Grafana to the target database, set once the copy completed
+	CutoverInstructions []string `json:"cutoverInstructions,omitempty"`
+}
+
+type TableProgress struct {
+	Name string `json:"name"`
+	// Created is set for the tables which aren't created by the migrations, eg: the tables of another
+	// storage, they are created from the schema of the source database
+	Created bool `json:"created"`
+	// SourceRows is the row count of the source table when the copy started
+	SourceRows     int64  `json:"sourceRows"`
+	CopiedRows     int64  `json:"copiedRows"`
+	TargetRows     int64  `json:"targetRows"`
+	SourceChecksum string `json:"sourceChecksum,omitempty"`
+	TargetChecksum string `json:"targetChecksum,omitempty"`
+	Verified       bool   `json:"verified"`
+}
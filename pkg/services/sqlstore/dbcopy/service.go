@@ -0,0 +1,88 @@
+package dbcopy
+
+import (
+	"context"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, migrations registry.DatabaseMigrator, router routing.RouteRegister) *Service {
+	s := &Service{
+		cfg:        cfg,
+		db:         sqlStore,
+		migrations: migrations,
+		log:        log.New("dbcopy"),
+	}
+	s.registerRoutes(router)
+	return s
+}
+
+// Service runs the copies of the database started from the admin API, one at a time
+type Service struct {
+	cfg        *setting.Cfg
+	db         db.DB
+	migrations registry.DatabaseMigrator
+	log        log.Logger
+
+	mu     sync.Mutex
+	copier *Copier
+	cancel context.CancelFunc
+}
+
+// Run cancels the running copy when Grafana stops
+func (s *Service) Run(ctx context.Context) error {
+	<-ctx.Done()
+	s.Cancel()
+	return nil
+}
+
+// Start starts copying the database in the background
+func (s *Service) Start(opts Options) (Progress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.copier != nil && s.copier.Progress().State == StateRunning {
+		return Progress{}, ErrAlreadyRunning.Errorf("a database migration is already running")
+	}
+
+	copier, err := NewCopier(s.cfg, s.db.GetEngine(), s.migrations, opts)
+	if err != nil {
+		return Progress{}, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.copier, s.cancel = copier, cancel
+	go func() {
+		defer cancel()
+		if err := copier.Run(ctx); err != nil {
+			s.log.Debug("Database migration stopped", "error", err)
+		}
+	}()
+	return copier.Progress(), nil
+}
+
+// Progress returns the progress of the last started copy
+func (s *Service) Progress() (Progress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.copier == nil {
+		return Progress{}, ErrNotFound.Errorf("no database migration was started")
+	}
+	return s.copier.Progress(), nil
+}
+
+// Cancel stops the running copy, the target database keeps the rows copied until then
+func (s *Service) Cancel() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
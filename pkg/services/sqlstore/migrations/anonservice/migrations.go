@@ -22,4 +22,29 @@ func AddMigration(mg *migrator.Migrator) {
 	mg.AddMigration("create anon_device table", migrator.NewAddTableMigration(anonV1))
 	mg.AddMigration("add unique index anon_device.device_id", migrator.NewAddIndexMigration(anonV1, anonV1.Indices[0]))
 	mg.AddMigration("add index anon_device.updated_at", migrator.NewAddIndexMigration(anonV1, anonV1.Indices[1]))
+
+	mg.AddMigration("add org_id column to anon_device", migrator.NewAddColumnMigration(anonV1, &migrator.Column{
+		Name: "org_id", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+	mg.AddMigration("add index anon_device.org_id_updated_at", migrator.NewAddIndexMigration(anonV1, &migrator.Index{
+		Cols: []string{"org_id", "updated_at"}, Type: migrator.IndexType,
+	}))
+
+	var anonOrgSettingsV1 = migrator.Table{
+		Name: "anon_org_settings",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "enabled", Type: migrator.DB_Bool, Nullable: false, Default: "0"},
+			{Name: "device_limit", Type: migrator.DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "folder_uids", Type: migrator.DB_Text, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create anon_org_settings table", migrator.NewAddTableMigration(anonOrgSettingsV1))
+	mg.AddMigration("add unique index anon_org_settings.org_id", migrator.NewAddIndexMigration(anonOrgSettingsV1, anonOrgSettingsV1.Indices[0]))
 }
@@ -0,0 +1,26 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addAPIKeyUsageMigrations(mg *Migrator) {
+	apiKeyUsageV1 := Table{
+		Name: "api_key_usage",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "api_key_id", Type: DB_BigInt, Nullable: false},
+			{Name: "kind", Type: DB_NVarchar, Length: 16, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "request_count", Type: DB_BigInt, Nullable: false},
+			{Name: "last_used_at", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"api_key_id", "kind", "name"}, Type: UniqueIndex},
+			{Cols: []string{"org_id"}},
+			{Cols: []string{"last_used_at"}},
+		},
+	}
+
+	mg.AddMigration("create api_key_usage table", NewAddTableMigration(apiKeyUsageV1))
+	addTableIndicesMigrations(mg, "v1", apiKeyUsageV1)
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addAuditLogMigrations(mg *Migrator) {
+	auditLogV1 := Table{
+		Name: "audit_log",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "actor_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "actor_login", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "impersonator_login", Type: DB_NVarchar, Length: 190, Nullable: true},
+			{Name: "category", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "action", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "route", Type: DB_NVarchar, Length: 255, Nullable: true},
+			{Name: "method", Type: DB_NVarchar, Length: 10, Nullable: true},
+			{Name: "path", Type: DB_Text, Nullable: true},
+			{Name: "status_code", Type: DB_Int, Nullable: true},
+			{Name: "result", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "client_ip", Type: DB_NVarchar, Length: 64, Nullable: true},
+			{Name: "user_agent", Type: DB_Text, Nullable: true},
+			{Name: "message", Type: DB_Text, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"created"}},
+			{Cols: []string{"org_id", "created"}},
+			{Cols: []string{"actor_login"}},
+		},
+	}
+
+	mg.AddMigration("create audit_log table", NewAddTableMigration(auditLogV1))
+	addTableIndicesMigrations(mg, "v1", auditLogV1)
+}
@@ -0,0 +1,24 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addBrandingMigrations(mg *Migrator) {
+	brandingV1 := Table{
+		Name: "branding",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			// org_id is 0 for the branding of the instance
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "data", Type: DB_MediumText, Nullable: false},
+			{Name: "version", Type: DB_BigInt, Nullable: false},
+			{Name: "updated_by", Type: DB_BigInt, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create branding table", NewAddTableMigration(brandingV1))
+	addTableIndicesMigrations(mg, "v1", brandingV1)
+}
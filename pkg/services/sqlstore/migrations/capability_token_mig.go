@@ -0,0 +1,28 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addCapabilityTokenMigrations(mg *Migrator) {
+	capabilityTokenV1 := Table{
+		Name: "capability_token",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "permissions", Type: DB_Text, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "expires", Type: DB_DateTime, Nullable: false},
+			{Name: "revoked", Type: DB_Bool, Nullable: false, Default: "0"},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id"}},
+			{Cols: []string{"expires"}},
+		},
+	}
+
+	mg.AddMigration("create capability_token table", NewAddTableMigration(capabilityTokenV1))
+	addTableIndicesMigrations(mg, "v1", capabilityTokenV1)
+}
@@ -67,3 +67,55 @@ func addCorrelationsMigrations(mg *Migrator) {
 		Name: "type", Type: DB_NVarchar, Length: 40, Nullable: false, Default: "'query'",
 	}))
 }
+
+func addCorrelationSuggestionsMigrations(mg *Migrator) {
+	transformationChainV1 := Table{
+		Name: "correlation_transformation_chain",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "description", Type: DB_Text, Nullable: false},
+			{Name: "transformations", Type: DB_Text, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id"}},
+		},
+	}
+
+	mg.AddMigration("create correlation_transformation_chain table v1", NewAddTableMigration(transformationChainV1))
+	addTableIndicesMigrations(mg, "v1", transformationChainV1)
+
+	suggestionV1 := Table{
+		Name: "correlation_suggestion",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "source_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "target_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "kind", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "label", Type: DB_Text, Nullable: false},
+			{Name: "description", Type: DB_Text, Nullable: false},
+			{Name: "config", Type: DB_Text, Nullable: true},
+			{Name: "score", Type: DB_Double, Nullable: false},
+			{Name: "evidence", Type: DB_Text, Nullable: true},
+			{Name: "state", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "correlation_uid", Type: DB_NVarchar, Length: 40, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "source_uid", "target_uid", "kind"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "state"}},
+		},
+	}
+
+	mg.AddMigration("create correlation_suggestion table v1", NewAddTableMigration(suggestionV1))
+	addTableIndicesMigrations(mg, "v1", suggestionV1)
+}
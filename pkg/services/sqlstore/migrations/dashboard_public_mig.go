@@ -96,4 +96,104 @@ func addPublicDashboardMigration(mg *Migrator) {
 	mg.AddMigration("backfill empty share column fields with default of public", NewRawSQLMigration(
 		"UPDATE dashboard_public SET share='public' WHERE share=''",
 	))
+
+	mg.AddMigration("add expires_at column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "expires_at",
+		Type:     DB_DateTime,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add access_policy column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "access_policy",
+		Type:     DB_Text,
+		Nullable: true,
+	}))
+
+	dashboardPublicSessionV1 := Table{
+		Name: "dashboard_public_session",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "public_dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "email", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "code_hash", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "token_hash", Type: DB_NVarchar, Length: 64, Nullable: true},
+			{Name: "created_at", Type: DB_DateTime, Nullable: false},
+			{Name: "expires_at", Type: DB_DateTime, Nullable: false},
+			{Name: "verified_at", Type: DB_DateTime, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"public_dashboard_uid", "code_hash"}, Type: UniqueIndex},
+			{Cols: []string{"token_hash"}},
+			{Cols: []string{"public_dashboard_uid", "email"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard public session table v1", NewAddTableMigration(dashboardPublicSessionV1))
+	addTableIndicesMigrations(mg, "v1", dashboardPublicSessionV1)
+
+	dashboardPublicViewV1 := Table{
+		Name: "dashboard_public_view",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "public_dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "email", Type: DB_NVarchar, Length: 190, Nullable: true},
+			{Name: "viewed_at", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"public_dashboard_uid", "viewed_at"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard public view table v1", NewAddTableMigration(dashboardPublicViewV1))
+	addTableIndicesMigrations(mg, "v1", dashboardPublicViewV1)
+
+	mg.AddMigration("add query_protection column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "query_protection",
+		Type:     DB_Text,
+		Nullable: true,
+	}))
+
+	dashboardPublicQueryLogV1 := Table{
+		Name: "dashboard_public_query_log",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "public_dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "panel_id", Type: DB_BigInt, Nullable: false},
+			{Name: "client_ip", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "email", Type: DB_NVarchar, Length: 190, Nullable: true},
+			{Name: "status", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "duration_ms", Type: DB_BigInt, Nullable: false},
+			{Name: "queried_at", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"public_dashboard_uid", "id"}},
+			{Cols: []string{"queried_at"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard public query log table v1", NewAddTableMigration(dashboardPublicQueryLogV1))
+	addTableIndicesMigrations(mg, "v1", dashboardPublicQueryLogV1)
+
+	dashboardPublicBanV1 := Table{
+		Name: "dashboard_public_ban",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "public_dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "cidr", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "reason", Type: DB_Text, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created_at", Type: DB_DateTime, Nullable: false},
+			{Name: "expires_at", Type: DB_DateTime, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"public_dashboard_uid"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard public ban table v1", NewAddTableMigration(dashboardPublicBanV1))
+	addTableIndicesMigrations(mg, "v1", dashboardPublicBanV1)
 }
@@ -0,0 +1,24 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addEventOutboxMigrations(mg *Migrator) {
+	eventOutboxV1 := Table{
+		Name: "event_outbox",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "type", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "payload", Type: DB_MediumText, Nullable: false},
+			{Name: "attempts", Type: DB_Int, Nullable: false},
+			{Name: "last_error", Type: DB_Text, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "next_attempt", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"next_attempt"}},
+		},
+	}
+
+	mg.AddMigration("create event_outbox table", NewAddTableMigration(eventOutboxV1))
+	addTableIndicesMigrations(mg, "v1", eventOutboxV1)
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addExploreSessionMigrations(mg *Migrator) {
+	exploreSessionV1 := Table{
+		Name: "explore_session",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "shared", Type: DB_Bool, Nullable: false},
+			{Name: "schema_version", Type: DB_Int, Nullable: false},
+			{Name: "state", Type: DB_MediumText, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "created_by"}},
+			{Cols: []string{"org_id", "shared"}},
+		},
+	}
+
+	mg.AddMigration("create explore_session table", NewAddTableMigration(exploreSessionV1))
+	addTableIndicesMigrations(mg, "v1", exploreSessionV1)
+}
@@ -0,0 +1,46 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addFeatureToggleMigrations(mg *Migrator) {
+	overrideV1 := Table{
+		Name: "feature_toggle_override",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			// org_id is 0 for the global values
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "updated_by", Type: DB_BigInt, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "name"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create feature_toggle_override table", NewAddTableMigration(overrideV1))
+	addTableIndicesMigrations(mg, "v1", overrideV1)
+
+	historyV1 := Table{
+		Name: "feature_toggle_history",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "previous", Type: DB_Bool, Nullable: false},
+			{Name: "value", Type: DB_Bool, Nullable: false},
+			{Name: "reset", Type: DB_Bool, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_login", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"name", "created"}},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create feature_toggle_history table", NewAddTableMigration(historyV1))
+	addTableIndicesMigrations(mg, "v1", historyV1)
+}
@@ -0,0 +1,48 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addImpersonationMigrations(mg *Migrator) {
+	userImpersonationV1 := Table{
+		Name: "user_impersonation",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "impersonator_id", Type: DB_BigInt, Nullable: false},
+			{Name: "impersonator_login", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "target_user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "target_login", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "reason", Type: DB_Text, Nullable: false},
+			{Name: "user_token_id", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "expires", Type: DB_DateTime, Nullable: false},
+			{Name: "ended", Type: DB_DateTime, Nullable: true},
+			{Name: "end_reason", Type: DB_NVarchar, Length: 40, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"uid"}, Type: UniqueIndex},
+			{Cols: []string{"user_token_id"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "created"}},
+		},
+	}
+
+	mg.AddMigration("create user_impersonation table", NewAddTableMigration(userImpersonationV1))
+	addTableIndicesMigrations(mg, "v1", userImpersonationV1)
+
+	orgImpersonationSettingsV1 := Table{
+		Name: "org_impersonation_settings",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "enabled", Type: DB_Bool, Nullable: false, Default: "1"},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create org_impersonation_settings table", NewAddTableMigration(orgImpersonationSettingsV1))
+	addTableIndicesMigrations(mg, "v1", orgImpersonationSettingsV1)
+}
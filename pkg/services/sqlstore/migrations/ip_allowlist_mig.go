@@ -0,0 +1,39 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addIPAllowlistMigrations(mg *Migrator) {
+	orgIPAllowlistV1 := Table{
+		Name: "org_ip_allowlist",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "cidrs", Type: DB_Text, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create org_ip_allowlist table", NewAddTableMigration(orgIPAllowlistV1))
+	addTableIndicesMigrations(mg, "v1", orgIPAllowlistV1)
+
+	apiKeyIPAllowlistV1 := Table{
+		Name: "api_key_ip_allowlist",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "api_key_id", Type: DB_BigInt, Nullable: false},
+			{Name: "cidrs", Type: DB_Text, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"api_key_id"}, Type: UniqueIndex},
+			{Cols: []string{"org_id"}},
+		},
+	}
+
+	mg.AddMigration("create api_key_ip_allowlist table", NewAddTableMigration(apiKeyIPAllowlistV1))
+	addTableIndicesMigrations(mg, "v1", apiKeyIPAllowlistV1)
+}
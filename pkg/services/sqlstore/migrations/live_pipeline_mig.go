@@ -0,0 +1,42 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addLivePipelineMigrations(mg *Migrator) {
+	liveChannelRuleV1 := Table{
+		Name: "live_channel_rule",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "pattern", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "settings", Type: DB_Text, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "pattern"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create live_channel_rule table", NewAddTableMigration(liveChannelRuleV1))
+	addTableIndicesMigrations(mg, "v1", liveChannelRuleV1)
+
+	liveWriteConfigV1 := Table{
+		Name: "live_write_config",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "settings", Type: DB_Text, Nullable: false},
+			{Name: "secure_settings", Type: DB_Text, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create live_write_config table", NewAddTableMigration(liveWriteConfigV1))
+	addTableIndicesMigrations(mg, "v1", liveWriteConfigV1)
+}
@@ -0,0 +1,41 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addMFAMigrations(mg *Migrator) {
+	userMFAV1 := Table{
+		Name: "user_mfa",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "secret", Type: DB_Text, Nullable: false},
+			{Name: "recovery_codes", Type: DB_Text, Nullable: true},
+			{Name: "enabled", Type: DB_Bool, Nullable: false, Default: "0"},
+			{Name: "last_used_step", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create user_mfa table", NewAddTableMigration(userMFAV1))
+	addTableIndicesMigrations(mg, "v1", userMFAV1)
+
+	orgMFAPolicyV1 := Table{
+		Name: "org_mfa_policy",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "required", Type: DB_Bool, Nullable: false, Default: "0"},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create org_mfa_policy table", NewAddTableMigration(orgMFAPolicyV1))
+	addTableIndicesMigrations(mg, "v1", orgMFAPolicyV1)
+}
@@ -155,4 +155,37 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	ualert.DropTitleUniqueIndexMigration(mg)
 
 	ualert.AddStateFiredAtColumn(mg)
+
+	addMFAMigrations(mg)
+
+	addServiceAccountTokenPolicyMigrations(mg)
+
+	addImpersonationMigrations(mg)
+
+	addIPAllowlistMigrations(mg)
+
+	addAuditLogMigrations(mg)
+
+	addTemporaryGrantMigrations(mg)
+
+	addCapabilityTokenMigrations(mg)
+
+	addOrgAuthPolicyMigrations(mg)
+	addAPIKeyUsageMigrations(mg)
+	addLivePipelineMigrations(mg)
+	addSavedSearchMigrations(mg)
+	addEventOutboxMigrations(mg)
+	addProvisioningStatusMigrations(mg)
+	addSecretAccessMigrations(mg)
+	addWebhookMigrations(mg)
+	addTeamSyncRuleMigrations(mg)
+	addPlaylistKioskMigrations(mg)
+	addCorrelationSuggestionsMigrations(mg)
+	addFeatureToggleMigrations(mg)
+	addUsageReportMigrations(mg)
+	addUserActivityMigrations(mg)
+	addExploreSessionMigrations(mg)
+	addStarResourceMigrations(mg)
+	addBrandingMigrations(mg)
+	addRateLimitMigrations(mg)
 }
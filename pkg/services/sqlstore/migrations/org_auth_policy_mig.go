@@ -0,0 +1,26 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addOrgAuthPolicyMigrations(mg *Migrator) {
+	orgAuthPolicyV1 := Table{
+		Name: "org_auth_policy",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "allowed_auth_modules", Type: DB_Text, Nullable: false},
+			{Name: "session_max_lifetime", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "session_max_inactive", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "strict_role_mapping", Type: DB_Bool, Nullable: false, Default: "0"},
+			{Name: "auto_signup_domains", Type: DB_Text, Nullable: false},
+			{Name: "auto_signup_role", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create org_auth_policy table", NewAddTableMigration(orgAuthPolicyV1))
+	addTableIndicesMigrations(mg, "v1", orgAuthPolicyV1)
+}
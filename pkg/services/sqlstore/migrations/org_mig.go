@@ -66,4 +66,9 @@ func addOrgMigrations(mg *Migrator) {
 
 	const migrateReadOnlyViewersToViewers = `UPDATE org_user SET role = 'Viewer' WHERE role = 'Read Only Editor'`
 	mg.AddMigration("Migrate all Read Only Viewers to Viewers", NewRawSQLMigration(migrateReadOnlyViewersToViewers))
+
+	// archived organizations are read-only and hidden from the organization switchers
+	mg.AddMigration("Add archived column to org table", NewAddColumnMigration(orgV1, &Column{
+		Name: "archived", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
 }
@@ -0,0 +1,47 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addPlaylistKioskMigrations(mg *Migrator) {
+	playlistScheduleV1 := Table{
+		Name: "playlist_schedule",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "playlist_uid", Type: DB_NVarchar, Length: 80, Nullable: false},
+			{Name: "schedule", Type: DB_Text, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "playlist_uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create playlist_schedule table", NewAddTableMigration(playlistScheduleV1))
+	addTableIndicesMigrations(mg, "v1", playlistScheduleV1)
+
+	playlistKioskTokenV1 := Table{
+		Name: "playlist_kiosk_token",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "playlist_uid", Type: DB_NVarchar, Length: 80, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "secret_hash", Type: DB_NVarchar, Length: 128, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "last_seen_at", Type: DB_DateTime, Nullable: true},
+			{Name: "last_seen_ip", Type: DB_NVarchar, Length: 64, Nullable: true},
+			{Name: "last_seen_user_agent", Type: DB_NVarchar, Length: 255, Nullable: true},
+			{Name: "current_dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "playlist_uid"}},
+		},
+	}
+
+	mg.AddMigration("create playlist_kiosk_token table", NewAddTableMigration(playlistKioskTokenV1))
+	addTableIndicesMigrations(mg, "v1", playlistKioskTokenV1)
+}
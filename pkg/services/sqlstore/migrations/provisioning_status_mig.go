@@ -0,0 +1,26 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addProvisioningStatusMigrations(mg *Migrator) {
+	provisioningStatusV1 := Table{
+		Name: "provisioning_status",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "provisioner", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "last_run", Type: DB_DateTime, Nullable: false},
+			{Name: "duration_ms", Type: DB_BigInt, Nullable: false},
+			{Name: "success", Type: DB_Bool, Nullable: false},
+			{Name: "resources", Type: DB_MediumText, Nullable: false},
+			{Name: "error", Type: DB_Text, Nullable: false},
+			{Name: "file", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "line", Type: DB_Int, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"provisioner"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create provisioning_status table", NewAddTableMigration(provisioningStatusV1))
+	addTableIndicesMigrations(mg, "v1", provisioningStatusV1)
+}
@@ -28,4 +28,8 @@ func addQuotaMigration(mg *Migrator) {
 	mg.AddMigration("Update quota table charset", NewTableCharsetMigration("quota", []*Column{
 		{Name: "target", Type: DB_NVarchar, Length: 190, Nullable: false},
 	}))
+
+	mg.AddMigration("Add column soft_limit to quota", NewAddColumnMigration(quotaV1, &Column{
+		Name: "soft_limit", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
 }
@@ -0,0 +1,22 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addRateLimitMigrations(mg *Migrator) {
+	rateLimitCounterV1 := Table{
+		Name: "rate_limit_counter",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "counter_key", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "request_count", Type: DB_BigInt, Nullable: false},
+			{Name: "expires", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"counter_key"}, Type: UniqueIndex},
+			{Cols: []string{"expires"}},
+		},
+	}
+
+	mg.AddMigration("create rate_limit_counter table", NewAddTableMigration(rateLimitCounterV1))
+	addTableIndicesMigrations(mg, "v1", rateLimitCounterV1)
+}
@@ -0,0 +1,32 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addSavedSearchMigrations(mg *Migrator) {
+	savedSearchV1 := Table{
+		Name: "saved_search",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "team_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "search", Type: DB_Text, Nullable: false},
+			{Name: "webhook_url", Type: DB_NVarchar, Length: 2048, Nullable: false},
+			{Name: "matched_uids", Type: DB_MediumText, Nullable: false},
+			{Name: "last_evaluated", Type: DB_DateTime, Nullable: true},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "user_id"}},
+			{Cols: []string{"org_id", "team_id"}},
+		},
+	}
+
+	mg.AddMigration("create saved_search table", NewAddTableMigration(savedSearchV1))
+	addTableIndicesMigrations(mg, "v1", savedSearchV1)
+}
@@ -0,0 +1,49 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addSecretAccessMigrations(mg *Migrator) {
+	secretAccessEventV1 := Table{
+		Name: "secret_access_event",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "kind", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "secret_uid", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "secret_name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "service", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "actor_id", Type: DB_NVarchar, Length: 190, Nullable: true},
+			{Name: "actor_login", Type: DB_NVarchar, Length: 190, Nullable: true},
+			{Name: "access_count", Type: DB_BigInt, Nullable: false},
+			{Name: "first_access", Type: DB_DateTime, Nullable: false},
+			{Name: "last_access", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"last_access"}},
+			{Cols: []string{"org_id", "last_access"}},
+			{Cols: []string{"kind", "secret_uid"}},
+		},
+	}
+
+	mg.AddMigration("create secret_access_event table", NewAddTableMigration(secretAccessEventV1))
+	addTableIndicesMigrations(mg, "v1", secretAccessEventV1)
+
+	secretUsageV1 := Table{
+		Name: "secret_usage",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "kind", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "secret_uid", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "secret_name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "tracked_since", Type: DB_DateTime, Nullable: false},
+			{Name: "last_used_at", Type: DB_DateTime, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "kind", "secret_uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create secret_usage table", NewAddTableMigration(secretUsageV1))
+	addTableIndicesMigrations(mg, "v1", secretUsageV1)
+}
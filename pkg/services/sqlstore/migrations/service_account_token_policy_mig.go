@@ -0,0 +1,21 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addServiceAccountTokenPolicyMigrations(mg *Migrator) {
+	tokenPolicyV1 := Table{
+		Name: "service_account_token_policy",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "max_lifetime_seconds", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create service_account_token_policy table", NewAddTableMigration(tokenPolicyV1))
+	addTableIndicesMigrations(mg, "v1", tokenPolicyV1)
+}
@@ -28,4 +28,20 @@ func addShortURLMigrations(mg *Migrator) {
 	mg.AddMigration("alter table short_url alter column created_by type to bigint", NewRawSQLMigration("").
 		Mysql("ALTER TABLE short_url MODIFY created_by BIGINT;").
 		Postgres("ALTER TABLE short_url ALTER COLUMN created_by TYPE BIGINT;"))
+
+	mg.AddMigration("add column expires_at to short_url", NewAddColumnMigration(shortURLV1, &Column{
+		Name: "expires_at", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add column max_uses to short_url", NewAddColumnMigration(shortURLV1, &Column{
+		Name: "max_uses", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add column use_count to short_url", NewAddColumnMigration(shortURLV1, &Column{
+		Name: "use_count", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add index short_url.org_id-created_by", NewAddIndexMigration(shortURLV1, &Index{
+		Cols: []string{"org_id", "created_by"},
+	}))
 }
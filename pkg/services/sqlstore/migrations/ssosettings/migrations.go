@@ -16,4 +16,22 @@ func AddMigration(mg *migrator.Migrator) {
 	}
 
 	mg.AddMigration("create sso_setting table", migrator.NewAddTableMigration(ssoSettingV1))
+
+	var ssoSettingHistoryV1 = migrator.Table{
+		Name: "sso_setting_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "provider", Type: migrator.DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "settings", Type: migrator.DB_Text, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "created_by", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"provider", "version"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create sso_setting_history table", migrator.NewAddTableMigration(ssoSettingHistoryV1))
+	mg.AddMigration("add unique index sso_setting_history.provider_version", migrator.NewAddIndexMigration(ssoSettingHistoryV1, ssoSettingHistoryV1.Indices[0]))
 }
@@ -39,6 +39,29 @@ func addStarMigrations(mg *Migrator) {
 		}))
 }
 
+// the stars of the folders, data sources and alert rules, the dashboards are starred in the star table
+func addStarResourceMigrations(mg *Migrator) {
+	starResourceV1 := Table{
+		Name: "star_resource",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "kind", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "resource_uid", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "user_id", "kind", "resource_uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "kind", "resource_uid"}},
+			{Cols: []string{"user_id"}},
+		},
+	}
+
+	mg.AddMigration("create star_resource table", NewAddTableMigration(starResourceV1))
+	addTableIndicesMigrations(mg, "v1", starResourceV1)
+}
+
 // relies on the dashboard table existing & must be run after the dashboard migrations are run
 func addDashboardUIDStarMigrations(mg *Migrator) {
 	mg.AddMigration("Add missing dashboard_uid and org_id to star", &FillDashbordUIDMigration{})
@@ -55,3 +55,23 @@ func addTestDataMigrations(mg *Migrator) {
 	// create table
 	mg.AddMigration("create test_data table", NewAddTableMigration(testData))
 }
+
+func addUsageReportMigrations(mg *Migrator) {
+	usageReportCounterV1 := Table{
+		Name: "usage_report_counter",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "day", Type: DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "metric", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "label", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "value", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"day", "metric", "label", "org_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create usage_report_counter table", NewAddTableMigration(usageReportCounterV1))
+	addTableIndicesMigrations(mg, "v1", usageReportCounterV1)
+}
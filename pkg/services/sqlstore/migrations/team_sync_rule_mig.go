@@ -0,0 +1,24 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addTeamSyncRuleMigrations(mg *Migrator) {
+	teamSyncRuleV1 := Table{
+		Name: "team_sync_rule",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "team_id", Type: DB_BigInt, Nullable: false},
+			{Name: "provider", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "group_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "team_id", "provider", "group_id"}, Type: UniqueIndex},
+			{Cols: []string{"provider"}},
+		},
+	}
+
+	mg.AddMigration("create team_sync_rule table", NewAddTableMigration(teamSyncRuleV1))
+	addTableIndicesMigrations(mg, "v1", teamSyncRuleV1)
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addTemporaryGrantMigrations(mg *Migrator) {
+	temporaryGrantV1 := Table{
+		Name: "temporary_grant",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "role_uid", Type: DB_NVarchar, Length: 40, Nullable: true},
+			{Name: "permissions", Type: DB_Text, Nullable: false},
+			{Name: "reason", Type: DB_Text, Nullable: true},
+			{Name: "state", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "requested_by", Type: DB_BigInt, Nullable: false},
+			{Name: "reviewed_by", Type: DB_BigInt, Nullable: true},
+			{Name: "granted_role_id", Type: DB_BigInt, Nullable: true},
+			{Name: "expires", Type: DB_DateTime, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "user_id"}},
+			{Cols: []string{"state", "expires"}},
+		},
+	}
+
+	mg.AddMigration("create temporary_grant table", NewAddTableMigration(temporaryGrantV1))
+	addTableIndicesMigrations(mg, "v1", temporaryGrantV1)
+}
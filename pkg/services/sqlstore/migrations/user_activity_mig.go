@@ -0,0 +1,27 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addUserActivityMigrations(mg *Migrator) {
+	userActivityV1 := Table{
+		Name: "user_activity",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "type", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "resource_uid", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "title", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "url", Type: DB_Text, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"created"}},
+			{Cols: []string{"org_id", "user_id", "created"}},
+			{Cols: []string{"org_id", "user_id", "type", "resource_uid"}},
+		},
+	}
+
+	mg.AddMigration("create user_activity table", NewAddTableMigration(userActivityV1))
+	addTableIndicesMigrations(mg, "v1", userActivityV1)
+}
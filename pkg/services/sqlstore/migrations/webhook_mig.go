@@ -0,0 +1,57 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addWebhookMigrations(mg *Migrator) {
+	webhookV1 := Table{
+		Name: "webhook",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "url", Type: DB_Text, Nullable: false},
+			{Name: "events", Type: DB_Text, Nullable: false},
+			{Name: "filter", Type: DB_Text, Nullable: false},
+			{Name: "secret", Type: DB_Text, Nullable: false},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create webhook table", NewAddTableMigration(webhookV1))
+	addTableIndicesMigrations(mg, "v1", webhookV1)
+
+	webhookDeliveryV1 := Table{
+		Name: "webhook_delivery",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "webhook_id", Type: DB_BigInt, Nullable: false},
+			{Name: "webhook_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "event_id", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "event_type", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "payload", Type: DB_MediumText, Nullable: false},
+			{Name: "status", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "attempts", Type: DB_Int, Nullable: false},
+			{Name: "response_code", Type: DB_Int, Nullable: false},
+			{Name: "last_error", Type: DB_Text, Nullable: true},
+			{Name: "next_attempt", Type: DB_DateTime, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"webhook_id", "event_id"}, Type: UniqueIndex},
+			{Cols: []string{"status", "next_attempt"}},
+			{Cols: []string{"org_id", "webhook_uid", "created"}},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create webhook_delivery table", NewAddTableMigration(webhookDeliveryV1))
+	addTableIndicesMigrations(mg, "v1", webhookDeliveryV1)
+}
@@ -0,0 +1,121 @@
+package sqlstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/util/xorm"
+)
+
+// OutboxEvent is an event published with PublishAfterCommit, written to the event_outbox table in the transaction
+// of the change it describes. The row is deleted once the event is delivered, the outbox dispatcher redelivers
+// the events which weren't delivered after the commit, eg: because Grafana stopped.
+type OutboxEvent struct {
+	ID int64 `xorm:"pk autoincr 'id'"`
+	// Type is the name of the event type, which the bus uses to find the listeners of the event
+	Type    string
+	Payload string
+	// Attempts is the number of failed deliveries
+	Attempts    int
+	LastError   string
+	Created     time.Time
+	NextAttempt time.Time
+}
+
+func (OutboxEvent) TableName() string {
+	return "event_outbox"
+}
+
+// outboxEventTypes are the event types written to the outbox, by name
+var outboxEventTypes = map[string]reflect.Type{}
+
+func init() {
+	RegisterOutboxEvent(
+		&events.DashboardSaved{},
+		&events.DashboardDeleted{},
+		&events.FolderSaved{},
+		&events.FolderDeleted{},
+		&events.FolderFullPathUpdated{},
+		&events.DataSourceCreated{},
+		&events.DataSourceUpdated{},
+		&events.DataSourceDeleted{},
+		&events.AlertRuleSaved{},
+		&events.AlertRuleDeleted{},
+		&events.OrgUserAdded{},
+		&events.PermissionsChanged{},
+	)
+}
+
+// RegisterOutboxEvent makes the events of the types of msgs published with PublishAfterCommit delivered at least once:
+// they are written to the outbox in the transaction of the session, and redelivered until a delivery succeeds.
+// The listeners of these events must be idempotent. The msgs must be pointers to structs marshaled to JSON,
+// RegisterOutboxEvent must be called from an init function.
+func RegisterOutboxEvent(msgs ...any) {
+	for _, msg := range msgs {
+		t := reflect.TypeOf(msg).Elem()
+		if registered, ok := outboxEventTypes[t.Name()]; ok && registered != t {
+			panic(fmt.Sprintf("outbox event %s is already registered by %s", t.Name(), registered.PkgPath()))
+		}
+		outboxEventTypes[t.Name()] = t
+	}
+}
+
+// DecodeOutboxEvent returns the event stored in the outbox row
+func DecodeOutboxEvent(row *OutboxEvent) (any, error) {
+	t, ok := outboxEventTypes[row.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown outbox event type %s", row.Type)
+	}
+	msg := reflect.New(t).Interface()
+	if err := json.Unmarshal([]byte(row.Payload), msg); err != nil {
+		return nil, fmt.Errorf("failed to decode outbox event %d: %w", row.ID, err)
+	}
+	return msg, nil
+}
+
+// writeOutbox writes the events of the session with an outbox event type to the outbox, in the transaction of the session
+func (ss *SQLStore) writeOutbox(sess *DBSession) error {
+	if !ss.dbCfg.EventOutbox {
+		return nil
+	}
+
+	now := time.Now()
+	sess.outboxIDs = make([]int64, len(sess.events))
+	for i, msg := range sess.events {
+		name := reflect.TypeOf(msg).Elem().Name()
+		if _, ok := outboxEventTypes[name]; !ok {
+			continue
+		}
+		payload, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("failed to encode outbox event %s: %w", name, err)
+		}
+		row := &OutboxEvent{
+			Type:    name,
+			Payload: string(payload),
+			Created: now,
+			// the event is published right after the commit, it's only redelivered if it's still in the outbox later
+			NextAttempt: now.Add(ss.dbCfg.EventOutboxRedeliveryDelay),
+		}
+		if _, err := sess.Insert(row); err != nil {
+			return fmt.Errorf("failed to write outbox event %s: %w", name, err)
+		}
+		sess.outboxIDs[i] = row.ID
+	}
+	return nil
+}
+
+// acknowledgeOutbox deletes the delivered events from the outbox
+func (ss *SQLStore) acknowledgeOutbox(ctx context.Context, engine *xorm.Engine, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return ss.withDbSession(ctx, engine, func(sess *DBSession) error {
+		_, err := sess.In("id", ids).Delete(&OutboxEvent{})
+		return err
+	})
+}
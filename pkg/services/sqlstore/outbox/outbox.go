@@ -0,0 +1,209 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	batchSize = 100
+	// maxBackoff is the longest delay between two deliveries of an event
+	maxBackoff = time.Hour
+	// lockTimeout is the duration after which another instance can dispatch the events if the dispatch doesn't complete
+	lockTimeout = 5 * time.Minute
+)
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, bus bus.Bus, serverLock *serverlock.ServerLockService, reg prometheus.Registerer) *Service {
+	sec := cfg.Raw.Section("database")
+	return &Service{
+		db:          sqlStore,
+		bus:         bus,
+		serverLock:  serverLock,
+		enabled:     sec.Key("event_outbox").MustBool(true),
+		interval:    sec.Key("event_outbox_dispatch_interval").MustDuration(30 * time.Second),
+		retryDelay:  sec.Key("event_outbox_redelivery_delay").MustDuration(time.Minute),
+		maxAttempts: sec.Key("event_outbox_max_attempts").MustInt(10),
+		metrics:     newMetrics(reg),
+		log:         log.New("sqlstore.outbox"),
+	}
+}
+
+// Service redelivers the events left in the outbox, which weren't delivered after the commit of their transaction.
+// The events are published on the bus of the instance dispatching the outbox, a single instance dispatches it at once.
+type Service struct {
+	db          db.DB
+	bus         bus.Bus
+	serverLock  *serverlock.ServerLockService
+	enabled     bool
+	interval    time.Duration
+	retryDelay  time.Duration
+	maxAttempts int
+	metrics     *metrics
+	log         log.Logger
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.enabled
+}
+
+// Run dispatches the outbox until Grafana stops
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			err := s.serverLock.LockExecuteAndRelease(ctx, "event outbox dispatch", lockTimeout, func(ctx context.Context) {
+				if _, err := s.Dispatch(ctx); err != nil {
+					s.log.Error("Failed to dispatch the event outbox", "error", err)
+				}
+			})
+			if err != nil {
+				s.log.Debug("Skipped the event outbox dispatch", "error", err)
+			}
+		}
+	}
+}
+
+// Dispatch redelivers the events which are due, until the outbox holds no due event. Each failed delivery delays the
+// next one exponentially, the events failing max attempts times are kept in the outbox but no longer delivered.
+// It returns the number of delivered events.
+func (s *Service) Dispatch(ctx context.Context) (int, error) {
+	delivered := 0
+	for {
+		var rows []*sqlstore.OutboxEvent
+		err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Where("next_attempt <= ? AND attempts < ?", time.Now(), s.maxAttempts).
+				OrderBy("id").Limit(batchSize).Find(&rows)
+		})
+		if err != nil {
+			return delivered, err
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		var ids []int64
+		for _, row := range rows {
+			if err := s.deliver(ctx, row); err != nil {
+				s.log.Warn("Failed to redeliver an outbox event", "id", row.ID, "type", row.Type, "attempts", row.Attempts+1, "error", err)
+				s.metrics.failures.WithLabelValues(row.Type).Inc()
+				if err := s.retryLater(ctx, row, err); err != nil {
+					return delivered, err
+				}
+				continue
+			}
+			s.metrics.redelivered.WithLabelValues(row.Type).Inc()
+			ids = append(ids, row.ID)
+		}
+
+		if len(ids) > 0 {
+			err = s.db.WithDbSession(ctx, func(sess *db.Session) error {
+				_, err := sess.In("id", ids).Delete(&sqlstore.OutboxEvent{})
+				return err
+			})
+			if err != nil {
+				return delivered, err
+			}
+			delivered += len(ids)
+		}
+		if len(rows) < batchSize || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return delivered, s.updateGauges(ctx)
+}
+
+func (s *Service) deliver(ctx context.Context, row *sqlstore.OutboxEvent) error {
+	msg, err := sqlstore.DecodeOutboxEvent(row)
+	if err != nil {
+		return err
+	}
+	return s.bus.Publish(ctx, msg)
+}
+
+// retryLater records the failed delivery of the event and delays its next delivery
+func (s *Service) retryLater(ctx context.Context, row *sqlstore.OutboxEvent, deliveryErr error) error {
+	row.Attempts++
+	row.LastError = deliveryErr.Error()
+	row.NextAttempt = time.Now().Add(backoff(s.retryDelay, row.Attempts))
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(row.ID).Cols("attempts", "last_error", "next_attempt").Update(row)
+		return err
+	})
+}
+
+// backoff returns the delay before the next delivery of an event which failed attempts times
+func backoff(delay time.Duration, attempts int) time.Duration {
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxBackoff)
+}
+
+func (s *Service) updateGauges(ctx context.Context) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		pending, err := sess.Where("attempts < ?", s.maxAttempts).Count(&sqlstore.OutboxEvent{})
+		if err != nil {
+			return err
+		}
+		failed, err := sess.Where("attempts >= ?", s.maxAttempts).Count(&sqlstore.OutboxEvent{})
+		if err != nil {
+			return err
+		}
+		s.metrics.pending.Set(float64(pending))
+		s.metrics.failed.Set(float64(failed))
+		return nil
+	})
+}
+
+type metrics struct {
+	redelivered *prometheus.CounterVec
+	failures    *prometheus.CounterVec
+	pending     prometheus.Gauge
+	failed      prometheus.Gauge
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	const namespace, subsystem = "grafana", "event_outbox"
+	return &metrics{
+		redelivered: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "redelivered_total",
+			Help:      "Total number of events redelivered from the outbox, by event type",
+		}, []string{"type"}),
+		failures: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "delivery_failures_total",
+			Help:      "Total number of failed redeliveries of events from the outbox, by event type",
+		}, []string{"type"}),
+		pending: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "pending",
+			Help:      "Number of events waiting in the outbox to be delivered, measured by the last dispatch",
+		}),
+		failed: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "failed",
+			Help:      "Number of events in the outbox which failed the max attempts and are no longer delivered, measured by the last dispatch",
+		}),
+	}
+}
@@ -0,0 +1,129 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, time.Minute, backoff(time.Minute, 1))
+	assert.Equal(t, 2*time.Minute, backoff(time.Minute, 2))
+	assert.Equal(t, 8*time.Minute, backoff(time.Minute, 4))
+	assert.Equal(t, maxBackoff, backoff(time.Minute, 10))
+	assert.Equal(t, maxBackoff, backoff(time.Minute, 1000))
+}
+
+func TestIntegrationDispatch(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	store := db.InitTestDB(t)
+	tracer := tracing.InitializeTracerForTest()
+	inProcBus := bus.ProvideBus(tracer)
+	cfg := setting.NewCfg()
+	cfg.Raw.Section("database").Key("event_outbox_max_attempts").SetValue("2")
+	s := ProvideService(cfg, store, inProcBus, serverlock.ProvideService(store, tracer), prometheus.NewRegistry())
+
+	var delivered []*events.DashboardDeleted
+	var listenerErr error
+	inProcBus.AddEventListener(func(ctx context.Context, e *events.DashboardDeleted) error {
+		if listenerErr != nil {
+			return listenerErr
+		}
+		delivered = append(delivered, e)
+		return nil
+	})
+
+	insert := func(t *testing.T, rows ...*sqlstore.OutboxEvent) {
+		require.NoError(t, store.WithDbSession(context.Background(), func(sess *db.Session) error {
+			for _, row := range rows {
+				row.Created = time.Now().Add(-time.Hour)
+				if _, err := sess.Insert(row); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+	}
+	outbox := func(t *testing.T) []*sqlstore.OutboxEvent {
+		var rows []*sqlstore.OutboxEvent
+		require.NoError(t, store.WithDbSession(context.Background(), func(sess *db.Session) error {
+			return sess.OrderBy("id").Find(&rows)
+		}))
+		return rows
+	}
+
+	insert(t,
+		&sqlstore.OutboxEvent{Type: "DashboardDeleted", Payload: `{"uid":"a","org_id":1}`, NextAttempt: time.Now().Add(-time.Minute)},
+		&sqlstore.OutboxEvent{Type: "DashboardDeleted", Payload: `{"uid":"b","org_id":1}`, NextAttempt: time.Now().Add(time.Hour)},
+		&sqlstore.OutboxEvent{Type: "Unknown", Payload: `{}`, NextAttempt: time.Now().Add(-time.Minute)},
+	)
+
+	t.Run("due events are redelivered", func(t *testing.T) {
+		n, err := s.Dispatch(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Len(t, delivered, 1)
+		require.Equal(t, "a", delivered[0].UID)
+
+		rows := outbox(t)
+		require.Len(t, rows, 2)
+		require.Equal(t, `{"uid":"b","org_id":1}`, rows[0].Payload)
+		require.Zero(t, rows[0].Attempts)
+		// the unknown event failed and is delayed
+		require.Equal(t, "Unknown", rows[1].Type)
+		require.Equal(t, 1, rows[1].Attempts)
+		require.Contains(t, rows[1].LastError, "unknown outbox event type")
+		require.True(t, rows[1].NextAttempt.After(time.Now()))
+	})
+
+	t.Run("failed deliveries are retried until the max attempts", func(t *testing.T) {
+		delivered, listenerErr = nil, errors.New("listener failed")
+		makeDue := func() {
+			require.NoError(t, store.WithDbSession(context.Background(), func(sess *db.Session) error {
+				_, err := sess.Exec("UPDATE event_outbox SET next_attempt = ?", time.Now().Add(-time.Minute))
+				return err
+			}))
+		}
+
+		makeDue()
+		n, err := s.Dispatch(context.Background())
+		require.NoError(t, err)
+		require.Zero(t, n)
+		rows := outbox(t)
+		require.Equal(t, 1, rows[0].Attempts)
+		require.Equal(t, "listener failed", rows[0].LastError)
+		require.Equal(t, 2, rows[1].Attempts)
+
+		// the unknown event failed the max attempts and is no longer delivered
+		listenerErr = nil
+		makeDue()
+		n, err = s.Dispatch(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Len(t, delivered, 1)
+		rows = outbox(t)
+		require.Len(t, rows, 1)
+		require.Equal(t, "Unknown", rows[0].Type)
+		require.Equal(t, 2, rows[0].Attempts)
+	})
+}
@@ -0,0 +1,81 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestIntegrationEventOutbox(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ss, _ := InitTestDB(t)
+	require.True(t, ss.dbCfg.EventOutbox)
+
+	inProcBus := bus.ProvideBus(tracing.InitializeTracerForTest())
+	storeBus := ss.bus
+	ss.bus = inProcBus
+	t.Cleanup(func() { ss.bus = storeBus })
+
+	var delivered []*events.DataSourceCreated
+	var listenerErr error
+	inProcBus.AddEventListener(func(ctx context.Context, e *events.DataSourceCreated) error {
+		if listenerErr != nil {
+			return listenerErr
+		}
+		delivered = append(delivered, e)
+		return nil
+	})
+
+	outbox := func(t *testing.T) []*OutboxEvent {
+		var rows []*OutboxEvent
+		require.NoError(t, ss.WithDbSession(context.Background(), func(sess *DBSession) error {
+			return sess.OrderBy("id").Find(&rows)
+		}))
+		return rows
+	}
+	publish := func(callbackErr error) error {
+		return ss.WithTransactionalDbSession(context.Background(), func(sess *DBSession) error {
+			sess.PublishAfterCommit(&events.DataSourceCreated{UID: "ds", OrgID: 1})
+			// not an outbox event
+			sess.PublishAfterCommit(&events.OrgCreated{Id: 1})
+			return callbackErr
+		})
+	}
+
+	t.Run("delivered events are removed from the outbox", func(t *testing.T) {
+		delivered, listenerErr = nil, nil
+		require.NoError(t, publish(nil))
+		require.Len(t, delivered, 1)
+		require.Empty(t, outbox(t))
+	})
+
+	t.Run("events aren't written when the transaction is rolled back", func(t *testing.T) {
+		delivered, listenerErr = nil, nil
+		require.Error(t, publish(errors.New("rollback")))
+		require.Empty(t, delivered)
+		require.Empty(t, outbox(t))
+	})
+
+	t.Run("undelivered events are kept in the outbox", func(t *testing.T) {
+		delivered, listenerErr = nil, errors.New("listener failed")
+		require.NoError(t, publish(nil))
+
+		rows := outbox(t)
+		require.Len(t, rows, 1)
+		require.Equal(t, "DataSourceCreated", rows[0].Type)
+		require.Zero(t, rows[0].Attempts)
+		require.True(t, rows[0].NextAttempt.After(rows[0].Created))
+
+		msg, err := DecodeOutboxEvent(rows[0])
+		require.NoError(t, err)
+		require.Equal(t, &events.DataSourceCreated{UID: "ds", OrgID: 1}, msg)
+	})
+}
@@ -80,6 +80,12 @@ func (f *accessControlDashboardPermissionFilterNoFolderSubquery) buildClauses(di
 				}
 			}
 
+			// Include the dashboards matching a tag condition of the user's permissions
+			if tags := getAllowedUIDs(f.dashboardAction, f.user, dashboards.ScopeDashboardsTagPrefix); len(tags) > 0 {
+				builder.WriteString(" OR (dashboard.id IN (SELECT dashboard_id FROM dashboard_tag WHERE term IN (?" + strings.Repeat(", ?", len(tags)-1) + ")) AND NOT dashboard.is_folder)")
+				args = append(args, tags...)
+			}
+
 			builder.WriteString(" OR ")
 
 			if !useSelfContainedPermissions {
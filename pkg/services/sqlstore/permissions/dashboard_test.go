@@ -84,6 +84,24 @@ func TestIntegration_DashboardPermissionFilter(t *testing.T) {
 			},
 			expectedResult: 6,
 		},
+		{
+			desc:       "Should be able to view the dashboards matching a tag condition",
+			permission: dashboardaccess.PERMISSION_VIEW,
+			permissions: []accesscontrol.Permission{
+				{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:tag:team-a"},
+				{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:13"},
+			},
+			expectedResult: 12,
+		},
+		{
+			desc:       "Should not be able to edit the dashboards matching a tag condition of another action",
+			permission: dashboardaccess.PERMISSION_EDIT,
+			permissions: []accesscontrol.Permission{
+				{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:tag:team-a"},
+				{Action: dashboards.ActionDashboardsWrite, Scope: "dashboards:uid:13"},
+			},
+			expectedResult: 1,
+		},
 		{
 			desc:       "Should be able to view all folders with folder wildcard",
 			permission: dashboardaccess.PERMISSION_VIEW,
@@ -720,11 +738,18 @@ func setupTest(t *testing.T, numFolders, numDashboards int, permissions []access
 			folderID = i % (numFolders + 1)
 		}
 
+		// every tenth dashboard is tagged to test tag conditions
+		tags := []any{}
+		if i%10 == 0 {
+			tags = append(tags, "team-a")
+		}
+
 		cmd := dashboards.SaveDashboardCommand{
 			OrgID: 1,
 			Dashboard: simplejson.NewFromAny(map[string]any{
 				"title": str,
 				"uid":   str,
+				"tags":  tags,
 			}),
 			IsFolder: false,
 		}
@@ -0,0 +1,260 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+var (
+	ErrQueryStatsDisabled = errutil.BadRequest("sqlstore.query-stats-disabled",
+		errutil.WithPublicMessage("Query statistics are disabled, enable instrument_queries or set slow_query_threshold in the [database] section"))
+	ErrInvalidQueryStatsSort = errutil.BadRequest("sqlstore.query-stats-invalid-sort")
+)
+
+// Orders of the query statistics returned by QueryStats
+const (
+	QueryStatsSortTotal = "total"
+	QueryStatsSortMax   = "max"
+	QueryStatsSortCount = "count"
+	QueryStatsSortRows  = "rows"
+	QueryStatsSortSlow  = "slow"
+)
+
+// maxSlowestQueryLength is the length above which the SQL of the slowest query of a call site is truncated
+const maxSlowestQueryLength = 2048
+
+var (
+	databaseCallHistogram *prometheus.HistogramVec
+	databaseRowsHistogram *prometheus.HistogramVec
+)
+
+func init() {
+	databaseCallHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "grafana",
+		Subsystem: "database",
+		Name:      "call_duration_seconds",
+		Help:      "Duration of the database queries, by store function, operation and status",
+		Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+	}, []string{"call", "operation", "status"})
+	databaseRowsHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "grafana",
+		Subsystem: "database",
+		Name:      "call_rows",
+		Help:      "Number of rows read or affected by the database queries, by store function and operation",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 10),
+	}, []string{"call", "operation"})
+
+	prometheus.MustRegister(databaseCallHistogram, databaseRowsHistogram)
+}
+
+// QueryStatsProvider summarizes the queries run by each store function
+type QueryStatsProvider interface {
+	// QueryStats returns the statistics of at most limit store functions, sorted by total, max, count, rows or slow
+	QueryStats(limit int, sortBy string) ([]CallStats, error)
+}
+
+// CallStats are the statistics of the queries run by a store function since Grafana started
+type CallStats struct {
+	// Call is the store function, eg: database.(*dashboardStore).GetDashboard
+	Call    string `json:"call"`
+	Queries int64  `json:"queries"`
+	Errors  int64  `json:"errors"`
+	// SlowQueries is the number of queries slower than the slow query threshold
+	SlowQueries int64 `json:"slowQueries"`
+	// Rows is the number of rows read or affected by the queries
+	Rows int64 `json:"rows"`
+	// TotalDuration, MeanDuration and MaxDuration are in milliseconds
+	TotalDuration float64 `json:"totalDuration"`
+	MeanDuration  float64 `json:"meanDuration"`
+	MaxDuration   float64 `json:"maxDuration"`
+	// SlowestQuery is the SQL of the slowest query, without its parameters
+	SlowestQuery string `json:"slowestQuery"`
+}
+
+// contextCallSiteKey is used as key to save the store function running the queries in `context.Context`
+type contextCallSiteKey struct{}
+
+// withCallSite stores the store function calling the session function calling withCallSite in the context
+func (ss *SQLStore) withCallSite(ctx context.Context) context.Context {
+	if ss.queryStats == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, contextCallSiteKey{}, caller(2))
+}
+
+// QueryStats implements QueryStatsProvider
+func (ss *SQLStore) QueryStats(limit int, sortBy string) ([]CallStats, error) {
+	if ss.queryStats == nil {
+		return nil, ErrQueryStatsDisabled.Errorf("query statistics are disabled")
+	}
+	return ss.queryStats.top(limit, sortBy)
+}
+
+// queryStats records the duration and rows of the queries run by each store function, and logs the slow queries
+type queryStats struct {
+	threshold time.Duration
+	log       log.Logger
+
+	mu    sync.Mutex
+	calls map[string]*CallStats
+}
+
+func newQueryStats(threshold time.Duration) *queryStats {
+	return &queryStats{
+		threshold: threshold,
+		log:       log.New("sqlstore.slowqueries"),
+		calls:     map[string]*CallStats{},
+	}
+}
+
+// observe implements xorm.QueryObserver
+func (s *queryStats) observe(ctx context.Context, query string, args []any, rows int64, took time.Duration, err error) {
+	call, ok := ctx.Value(contextCallSiteKey{}).(string)
+	if !ok {
+		call = "unknown"
+	}
+	operation := queryOperation(query)
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	databaseCallHistogram.WithLabelValues(call, operation, status).Observe(took.Seconds())
+	if err == nil {
+		databaseRowsHistogram.WithLabelValues(call, operation).Observe(float64(rows))
+	}
+
+	slow := s.threshold > 0 && took >= s.threshold
+	if slow {
+		// the parameters may hold secrets or personal data, only their types are logged
+		s.log.FromContext(ctx).Warn("Slow query", "call", call, "duration", took, "rows", rows, "sql", query, "args", redactArgs(args), "error", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats, ok := s.calls[call]
+	if !ok {
+		stats = &CallStats{Call: call}
+		s.calls[call] = stats
+	}
+	ms := float64(took) / float64(time.Millisecond)
+	stats.Queries++
+	stats.Rows += rows
+	stats.TotalDuration += ms
+	if err != nil {
+		stats.Errors++
+	}
+	if slow {
+		stats.SlowQueries++
+	}
+	if ms > stats.MaxDuration || stats.Queries == 1 {
+		stats.MaxDuration = ms
+		stats.SlowestQuery = truncateQuery(query)
+	}
+}
+
+// top returns the statistics of at most limit store functions, sorted in descending order
+func (s *queryStats) top(limit int, sortBy string) ([]CallStats, error) {
+	var before func(a, b CallStats) bool
+	switch sortBy {
+	case QueryStatsSortTotal, "":
+		before = func(a, b CallStats) bool { return a.TotalDuration > b.TotalDuration }
+	case QueryStatsSortMax:
+		before = func(a, b CallStats) bool { return a.MaxDuration > b.MaxDuration }
+	case QueryStatsSortCount:
+		before = func(a, b CallStats) bool { return a.Queries > b.Queries }
+	case QueryStatsSortRows:
+		before = func(a, b CallStats) bool { return a.Rows > b.Rows }
+	case QueryStatsSortSlow:
+		before = func(a, b CallStats) bool { return a.SlowQueries > b.SlowQueries }
+	default:
+		return nil, ErrInvalidQueryStatsSort.Errorf("invalid sort %q", sortBy)
+	}
+
+	s.mu.Lock()
+	calls := make([]CallStats, 0, len(s.calls))
+	for _, stats := range s.calls {
+		c := *stats
+		c.MeanDuration = c.TotalDuration / float64(c.Queries)
+		calls = append(calls, c)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(calls, func(i, j int) bool {
+		if before(calls[i], calls[j]) {
+			return true
+		}
+		if before(calls[j], calls[i]) {
+			return false
+		}
+		return calls[i].Call < calls[j].Call
+	})
+	if limit > 0 && len(calls) > limit {
+		calls = calls[:limit]
+	}
+	return calls, nil
+}
+
+// queryOperation returns the kind of the query from its first keyword: select, insert, update, delete or other
+func queryOperation(query string) string {
+	keyword := strings.TrimLeftFunc(query, func(r rune) bool { return unicode.IsSpace(r) || r == '(' })
+	if i := strings.IndexFunc(keyword, unicode.IsSpace); i >= 0 {
+		keyword = keyword[:i]
+	}
+	switch keyword = strings.ToLower(keyword); keyword {
+	case "select", "insert", "update", "delete":
+		return keyword
+	case "with":
+		// common table expressions mostly read
+		return "select"
+	default:
+		return "other"
+	}
+}
+
+// redactArgs replaces the bound parameters of a query with their types
+func redactArgs(args []any) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		redacted[i] = fmt.Sprintf("%T", arg)
+	}
+	return redacted
+}
+
+func truncateQuery(query string) string {
+	if len(query) <= maxSlowestQueryLength {
+		return query
+	}
+	return query[:maxSlowestQueryLength] + "..."
+}
+
+// caller returns the function skip frames above the function calling caller, eg: database.(*dashboardStore).GetDashboard
+func caller(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "unknown"
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown"
+	}
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	// the queries in closures are attributed to the function declaring them
+	if i := strings.Index(name, ".func"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
@@ -0,0 +1,124 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryStats_top(t *testing.T) {
+	s := newQueryStats(time.Second)
+	ctx := func(call string) context.Context {
+		return context.WithValue(context.Background(), contextCallSiteKey{}, call)
+	}
+
+	s.observe(ctx("store.GetA"), "SELECT * FROM a WHERE id = ?", []any{1}, 1, 10*time.Millisecond, nil)
+	s.observe(ctx("store.GetA"), "SELECT * FROM a", nil, 100, 2*time.Second, nil)
+	s.observe(ctx("store.UpdateB"), "UPDATE b SET x = ?", []any{"secret"}, 3, 500*time.Millisecond, nil)
+	s.observe(ctx("store.UpdateB"), "UPDATE b SET x = ?", []any{"secret"}, 0, 600*time.Millisecond, errors.New("locked"))
+	s.observe(context.Background(), "DELETE FROM c", nil, 0, time.Millisecond, nil)
+
+	stats, err := s.top(0, QueryStatsSortTotal)
+	require.NoError(t, err)
+	require.Len(t, stats, 3)
+	assert.Equal(t, CallStats{
+		Call:          "store.GetA",
+		Queries:       2,
+		SlowQueries:   1,
+		Rows:          101,
+		TotalDuration: 2010,
+		MeanDuration:  1005,
+		MaxDuration:   2000,
+		SlowestQuery:  "SELECT * FROM a",
+	}, stats[0])
+	assert.Equal(t, CallStats{
+		Call:          "store.UpdateB",
+		Queries:       2,
+		Errors:        1,
+		Rows:          3,
+		TotalDuration: 1100,
+		MeanDuration:  550,
+		MaxDuration:   600,
+		SlowestQuery:  "UPDATE b SET x = ?",
+	}, stats[1])
+	assert.Equal(t, "unknown", stats[2].Call)
+
+	stats, err = s.top(1, QueryStatsSortCount)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	// the ties are sorted by name
+	assert.Equal(t, "store.GetA", stats[0].Call)
+
+	stats, err = s.top(2, QueryStatsSortMax)
+	require.NoError(t, err)
+	assert.Equal(t, "store.UpdateB", stats[1].Call)
+
+	_, err = s.top(10, "name")
+	require.ErrorIs(t, err, ErrInvalidQueryStatsSort)
+}
+
+func TestQueryOperation(t *testing.T) {
+	for query, operation := range map[string]string{
+		"SELECT 1":                             "select",
+		"\n\tselect\n* FROM a":                 "select",
+		"(SELECT 1) UNION (SELECT 2)":          "select",
+		"WITH RECURSIVE t AS (SELECT 1) TABLE": "select",
+		"INSERT INTO a VALUES (?)":             "insert",
+		"update a SET x = 1":                   "update",
+		"DELETE FROM a":                        "delete",
+		"PRAGMA journal_mode":                  "other",
+		"":                                     "other",
+	} {
+		assert.Equal(t, operation, queryOperation(query), query)
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	assert.Equal(t, []string{"string", "int64", "<nil>", "time.Time"}, redactArgs([]any{"password", int64(1), nil, time.Now()}))
+	assert.Empty(t, redactArgs(nil))
+}
+
+func TestIntegrationQueryStats(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	store, _ := InitTestDB(t)
+	// the test database is shared, the statistics are only recorded during the test
+	store.queryStats = newQueryStats(0)
+	store.engine.SetQueryObserver(store.queryStats.observe)
+	t.Cleanup(func() {
+		store.engine.SetQueryObserver(nil)
+		store.queryStats = nil
+	})
+
+	err := store.WithDbSession(context.Background(), func(sess *DBSession) error {
+		_, err := sess.Exec("INSERT INTO tag (`key`, `value`) VALUES ('env', 'prod'), ('env', 'dev')")
+		return err
+	})
+	require.NoError(t, err)
+	err = store.WithTransactionalDbSession(context.Background(), func(sess *DBSession) error {
+		_, err := sess.Table("tag").Where("`key` = ?", "env").Count()
+		return err
+	})
+	require.NoError(t, err)
+	var count int64
+	err = store.WithReadReplicaSession(context.Background(), func(sess *DBSession) error {
+		var err error
+		count, err = sess.Table("tag").Count()
+		return err
+	})
+	require.NoError(t, err)
+
+	stats, err := store.QueryStats(10, QueryStatsSortCount)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, "sqlstore.TestIntegrationQueryStats", stats[0].Call)
+	assert.Equal(t, int64(3), stats[0].Queries)
+	// the insert affected two rows and each count read one row
+	assert.Equal(t, int64(4), stats[0].Rows)
+	assert.Zero(t, stats[0].Errors)
+}
@@ -0,0 +1,246 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/util/xorm"
+)
+
+// contextPrimaryKey is used as key to opt out of the read replicas in `context.Context`
+type contextPrimaryKey struct{}
+
+// ForcePrimary returns a context whose reads are served by the primary database, even when they could be served
+// by a read replica. Use it for the reads which must see the writes done just before, eg: reading back a saved resource.
+func ForcePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextPrimaryKey{}, true)
+}
+
+// Reasons for which a read is served by the primary database
+const (
+	readReasonReplica     = ""
+	readReasonOptOut      = "opt_out"
+	readReasonTransaction = "transaction"
+	readReasonStale       = "stale"
+)
+
+// WithReadReplicaSession calls the callback with a session of a read replica, the callback must not write.
+// The session of the primary database is used when no replica is configured, when the replicas lag behind
+// the primary more than the max lag, when the context holds a transaction, or when the context opted out with ForcePrimary.
+func (ss *SQLStore) WithReadReplicaSession(ctx context.Context, callback DBTransactionFunc) error {
+	ctx = ss.withCallSite(ctx)
+	if ss.replicas == nil {
+		return ss.withDbSession(ctx, ss.engine, callback)
+	}
+
+	engine, reason := ss.replicas.engine(ctx)
+	if engine == nil {
+		engine = ss.engine
+	}
+	ss.replicas.metrics.reads.WithLabelValues(caller(1), targetName(reason), reason).Inc()
+
+	return ss.withDbSession(ctx, engine, callback)
+}
+
+// replica is a read-only copy of the primary database
+type replica struct {
+	name   string
+	dbType string
+	engine *xorm.Engine
+	// fresh is set when the last check measured a lag below the max lag
+	fresh atomic.Bool
+}
+
+type replicaSet struct {
+	replicas      []*replica
+	next          atomic.Uint64
+	maxLag        time.Duration
+	checkInterval time.Duration
+	metrics       *replicaMetrics
+	log           log.Logger
+}
+
+// engine returns the engine of the next fresh replica, round robin, and nil with the reason when the read
+// must be served by the primary database
+func (rs *replicaSet) engine(ctx context.Context) (*xorm.Engine, string) {
+	if _, ok := ctx.Value(ContextSessionKey{}).(*DBSession); ok {
+		return nil, readReasonTransaction
+	}
+	if primary, _ := ctx.Value(contextPrimaryKey{}).(bool); primary {
+		return nil, readReasonOptOut
+	}
+
+	n := uint64(len(rs.replicas))
+	start := rs.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		r := rs.replicas[(start+i)%n]
+		if r.fresh.Load() {
+			return r.engine, readReasonReplica
+		}
+	}
+	return nil, readReasonStale
+}
+
+func (rs *replicaSet) run(ctx context.Context) {
+	ticker := time.NewTicker(rs.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rs.checkLag(ctx)
+		}
+	}
+}
+
+// checkLag measures the replication delay of the replicas, the replicas which can't be checked stop serving reads
+func (rs *replicaSet) checkLag(ctx context.Context) {
+	for _, r := range rs.replicas {
+		lag, err := measureReplicaLag(ctx, r)
+		if err != nil {
+			if r.fresh.Swap(false) {
+				rs.log.Warn("Failed to check the lag of database replica, reads are served by the primary", "replica", r.name, "error", err)
+			}
+			continue
+		}
+
+		rs.metrics.lag.WithLabelValues(r.name).Set(lag.Seconds())
+		fresh := lag <= rs.maxLag
+		if r.fresh.Swap(fresh) && !fresh {
+			rs.log.Warn("Database replica lags behind the primary, reads are served by the primary", "replica", r.name, "lag", lag, "maxLag", rs.maxLag)
+		}
+	}
+}
+
+const postgresReplicaLagQuery = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END`
+
+func measureReplicaLag(ctx context.Context, r *replica) (time.Duration, error) {
+	sess := r.engine.NewSession().Context(ctx)
+	defer sess.Close()
+
+	switch r.dbType {
+	case migrator.Postgres:
+		var lag float64
+		if _, err := sess.SQL(postgresReplicaLagQuery).Get(&lag); err != nil {
+			return 0, err
+		}
+		return time.Duration(lag * float64(time.Second)), nil
+	case migrator.MySQL:
+		// SHOW SLAVE STATUS is for MySQL < 8.0.22 and MariaDB
+		rows, err := sess.QueryString("SHOW REPLICA STATUS")
+		if err != nil {
+			if rows, err = sess.QueryString("SHOW SLAVE STATUS"); err != nil {
+				return 0, err
+			}
+		}
+		if len(rows) == 0 {
+			return 0, errors.New("replication is not configured")
+		}
+		for _, column := range []string{"Seconds_Behind_Source", "Seconds_Behind_Master"} {
+			if value, ok := rows[0][column]; ok {
+				// the value is NULL when the replication is stopped
+				seconds, err := strconv.ParseInt(value, 10, 64)
+				if err != nil {
+					return 0, errors.New("replication is stopped")
+				}
+				return time.Duration(seconds) * time.Second, nil
+			}
+		}
+		return 0, errors.New("replication lag not reported")
+	default:
+		return 0, fmt.Errorf("replicas are not supported with %s", r.dbType)
+	}
+}
+
+// initReplicas connects to the read replicas, and starts checking their lag
+func (ss *SQLStore) initReplicas() error {
+	if len(ss.dbCfg.Replicas) == 0 {
+		return nil
+	}
+
+	rs := &replicaSet{
+		maxLag:        ss.dbCfg.ReplicaMaxLag,
+		checkInterval: ss.dbCfg.ReplicaLagCheckInterval,
+		metrics:       newReplicaMetrics(),
+		log:           log.New("sqlstore.replicas"),
+	}
+	if err := prometheus.Register(rs.metrics); err != nil {
+		rs.log.Warn("Failed to register database replica metrics", "error", err)
+	}
+
+	for _, replicaCfg := range ss.dbCfg.Replicas {
+		// ss.dbCfg.Type is the driver wrapped with the query hooks when the queries are instrumented
+		engine, err := xorm.NewEngine(ss.dbCfg.Type, withMySQLParseTime(replicaCfg.Type, replicaCfg.ConnectionString))
+		if err != nil {
+			return fmt.Errorf("failed to connect to replica %s: %w", replicaCfg.Host, err)
+		}
+		ss.configureEngine(engine, replicaCfg)
+
+		rs.replicas = append(rs.replicas, &replica{
+			name:   replicaCfg.Host + "/" + replicaCfg.Name,
+			dbType: replicaCfg.Type,
+			engine: engine,
+		})
+	}
+
+	rs.checkLag(context.Background())
+	go rs.run(context.Background())
+
+	ss.replicas = rs
+	ss.log.Info("Connected to database replicas", "count", len(rs.replicas))
+	return nil
+}
+
+func targetName(reason string) string {
+	if reason == readReasonReplica {
+		return "replica"
+	}
+	return "primary"
+}
+
+type replicaMetrics struct {
+	lag   *prometheus.GaugeVec
+	reads *prometheus.CounterVec
+}
+
+func newReplicaMetrics() *replicaMetrics {
+	return &replicaMetrics{
+		lag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "database",
+			Name:      "replica_lag_seconds",
+			Help:      "Replication delay of the read replicas measured by the last check",
+		}, []string{"replica"}),
+		reads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "database",
+			Name:      "replica_reads_total",
+			Help:      "Number of sessions opened for the reads which can be served by the read replicas, by store function and served database",
+		}, []string{"call", "target", "reason"}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *replicaMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.lag.Describe(ch)
+	m.reads.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *replicaMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.lag.Collect(ch)
+	m.reads.Collect(ch)
+}
@@ -0,0 +1,51 @@
+package sqlstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grafana/grafana/pkg/util/xorm"
+)
+
+func TestReplicaSet_engine(t *testing.T) {
+	replica1 := &replica{name: "replica1", engine: &xorm.Engine{}}
+	replica2 := &replica{name: "replica2", engine: &xorm.Engine{}}
+	rs := &replicaSet{replicas: []*replica{replica1, replica2}}
+
+	t.Run("should use the primary when no replica is fresh", func(t *testing.T) {
+		engine, reason := rs.engine(context.Background())
+		assert.Nil(t, engine)
+		assert.Equal(t, readReasonStale, reason)
+	})
+
+	t.Run("should balance the reads between the fresh replicas", func(t *testing.T) {
+		replica1.fresh.Store(true)
+		replica2.fresh.Store(true)
+
+		first, reason := rs.engine(context.Background())
+		assert.Equal(t, readReasonReplica, reason)
+		second, _ := rs.engine(context.Background())
+		assert.NotSame(t, first, second)
+
+		replica1.fresh.Store(false)
+		for i := 0; i < 3; i++ {
+			engine, _ := rs.engine(context.Background())
+			assert.Same(t, replica2.engine, engine)
+		}
+	})
+
+	t.Run("should use the primary when the context opted out", func(t *testing.T) {
+		engine, reason := rs.engine(ForcePrimary(context.Background()))
+		assert.Nil(t, engine)
+		assert.Equal(t, readReasonOptOut, reason)
+	})
+
+	t.Run("should use the primary within a transaction", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), ContextSessionKey{}, &DBSession{transactionOpen: true})
+		engine, reason := rs.engine(ctx)
+		assert.Nil(t, engine)
+		assert.Equal(t, readReasonTransaction, reason)
+	})
+}
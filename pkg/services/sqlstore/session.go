@@ -27,6 +27,8 @@ type DBSession struct {
 	*xorm.Session
 	transactionOpen bool
 	events          []any
+	// outboxIDs are the ids of the outbox rows of the events, 0 for the events which aren't written to the outbox
+	outboxIDs []int64
 }
 
 type DBTransactionFunc func(sess *DBSession) error
@@ -77,7 +79,7 @@ func startSessionOrUseExisting(ctx context.Context, engine *xorm.Engine, beginTr
 // A session is stored in the context if sqlstore.InTransaction() has been previously called with the same context (and it's not committed/rolledback yet).
 // In case of retryable errors, callback will be retried at most five times before giving up.
 func (ss *SQLStore) WithDbSession(ctx context.Context, callback DBTransactionFunc) error {
-	return ss.withDbSession(ctx, ss.engine, callback)
+	return ss.withDbSession(ss.withCallSite(ctx), ss.engine, callback)
 }
 
 func (ss *SQLStore) retryOnLocks(ctx context.Context, callback DBTransactionFunc, sess *DBSession, retry int, dialect core.Dialect) func() (retryer.RetrySignal, error) {
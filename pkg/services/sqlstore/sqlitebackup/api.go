@@ -0,0 +1,42 @@
+package sqlitebackup
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister) {
+	router.Post("/api/admin/backup", middleware.ReqGrafanaAdmin, routing.Wrap(s.backup))
+}
+
+// swagger:route POST /admin/backup admin backupDatabase
+//
+// # Back up the SQLite database
+//
+// Writes a consistent snapshot of the SQLite database to the backup directory of the Grafana server,
+// while Grafana keeps serving reads and writes. Only the newest backups are kept.
+//
+// Responses:
+// 200: databaseBackupResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) backup(c *contextmodel.ReqContext) response.Response {
+	backup, err := s.Backup(c.Req.Context())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to back up the database", err)
+	}
+	return response.JSON(http.StatusOK, backup)
+}
+
+// swagger:response databaseBackupResponse
+type DatabaseBackupResponse struct {
+	// in:body
+	Body Backup `json:"body"`
+}
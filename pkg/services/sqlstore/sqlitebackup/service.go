@@ -0,0 +1,181 @@
+package sqlitebackup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	backupPrefix     = "grafana-"
+	backupExtension  = ".db"
+	backupTimeLayout = "20060102-150405.000"
+)
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, replicator Replicator, router routing.RouteRegister) *Service {
+	sec := cfg.Raw.Section("database")
+	dir := sec.Key("backup_path").MustString("backups")
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(cfg.DataPath, dir)
+	}
+
+	s := &Service{
+		db:         sqlStore,
+		replicator: replicator,
+		dir:        dir,
+		maxFiles:   sec.Key("backup_max_files").MustInt(5),
+		log:        log.New("sqlitebackup"),
+	}
+	s.registerRoutes(router)
+	return s
+}
+
+// Service takes online backups of the SQLite database, and runs the replicator of its write-ahead log
+type Service struct {
+	db         db.DB
+	replicator Replicator
+	dir        string
+	maxFiles   int
+	log        log.Logger
+
+	// mu is held while a backup is running
+	mu sync.Mutex
+}
+
+// IsDisabled disables the background service unless a replicator replicates the SQLite database
+func (s *Service) IsDisabled() bool {
+	return s.db.GetDBType() != migrator.SQLite || !s.replicator.Enabled()
+}
+
+// Run replicates the write-ahead log of the database until Grafana stops
+func (s *Service) Run(ctx context.Context) error {
+	path, journalMode, err := s.databaseFile(ctx)
+	if err != nil {
+		return err
+	}
+	if journalMode != "wal" {
+		s.log.Warn("SQLite replication requires the write-ahead log, set wal = true in the [database] section", "journalMode", journalMode)
+		return nil
+	}
+
+	s.log.Info("Replicating the SQLite database", "path", path)
+	if err := s.replicator.Replicate(ctx, path); err != nil && !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("failed to replicate the SQLite database: %w", err)
+	}
+	return nil
+}
+
+// Backup writes a consistent snapshot of the database to the backup directory while Grafana keeps
+// serving reads and writes. Only the newest backups are kept.
+func (s *Service) Backup(ctx context.Context) (Backup, error) {
+	if s.db.GetDBType() != migrator.SQLite {
+		return Backup{}, ErrUnsupportedDatabase.Errorf("online backups aren't supported with %s", s.db.GetDBType())
+	}
+	if !s.mu.TryLock() {
+		return Backup{}, ErrAlreadyRunning.Errorf("a backup is already running")
+	}
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return Backup{}, fmt.Errorf("failed to create the backup directory: %w", err)
+	}
+
+	start := time.Now()
+	path := filepath.Join(s.dir, backupPrefix+start.UTC().Format(backupTimeLayout)+backupExtension)
+	tmp := path + ".tmp"
+	// VACUUM INTO reads the database in a single read transaction, the snapshot is consistent
+	// even though the writes continue while it's written
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("VACUUM INTO ?", tmp)
+		return err
+	})
+	if err != nil {
+		_ = os.Remove(tmp)
+		return Backup{}, fmt.Errorf("failed to back up the database: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return Backup{}, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return Backup{}, err
+	}
+	backup := Backup{
+		Path:      path,
+		Size:      info.Size(),
+		Duration:  time.Since(start).Milliseconds(),
+		CreatedAt: start,
+	}
+	s.log.Info("Backed up the database", "path", path, "size", backup.Size, "duration", time.Since(start))
+
+	if s.replicator.Enabled() {
+		if err := s.replicator.Snapshot(ctx, backup); err != nil {
+			s.log.Error("Failed to replicate the backup", "path", path, "error", err)
+		}
+	}
+	s.prune()
+
+	return backup, nil
+}
+
+// prune removes the oldest backups beyond the maximum number of backups
+func (s *Service) prune() {
+	if s.maxFiles <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		s.log.Warn("Failed to list the backups", "error", err)
+		return
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), backupPrefix) && strings.HasSuffix(entry.Name(), backupExtension) {
+			backups = append(backups, entry.Name())
+		}
+	}
+	// the names sort by creation time
+	sort.Strings(backups)
+	for len(backups) > s.maxFiles {
+		if err := os.Remove(filepath.Join(s.dir, backups[0])); err != nil {
+			s.log.Warn("Failed to remove an old backup", "backup", backups[0], "error", err)
+		}
+		backups = backups[1:]
+	}
+}
+
+// databaseFile returns the path of the main database file and its journal mode
+func (s *Service) databaseFile(ctx context.Context) (string, string, error) {
+	var path, journalMode string
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		databases, err := sess.QueryString("PRAGMA database_list")
+		if err != nil {
+			return err
+		}
+		for _, database := range databases {
+			if database["name"] == "main" {
+				path = database["file"]
+			}
+		}
+		_, err = sess.SQL("PRAGMA journal_mode").Get(&journalMode)
+		return err
+	})
+	if err == nil && path == "" {
+		err = errors.New("the SQLite database is in memory")
+	}
+	return path, strings.ToLower(journalMode), err
+}
@@ -0,0 +1,80 @@
+package sqlitebackup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+	"github.com/grafana/grafana/pkg/util/xorm"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+type fakeReplicator struct {
+	OSSReplicator
+	snapshots []Backup
+}
+
+func (r *fakeReplicator) Enabled() bool {
+	return true
+}
+
+func (r *fakeReplicator) Snapshot(ctx context.Context, backup Backup) error {
+	r.snapshots = append(r.snapshots, backup)
+	return nil
+}
+
+func TestIntegrationBackup(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	store := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.DataPath = t.TempDir()
+	cfg.Raw.Section("database").Key("backup_max_files").SetValue("1")
+	replicator := &fakeReplicator{}
+	s := ProvideService(cfg, store, replicator, routing.NewRouteRegister())
+
+	if store.GetDBType() != migrator.SQLite {
+		_, err := s.Backup(context.Background())
+		require.ErrorIs(t, err, ErrUnsupportedDatabase)
+		return
+	}
+
+	_, err := store.GetEngine().Exec("INSERT INTO tag (`key`, `value`) VALUES ('env', 'prod'), ('env', 'dev')")
+	require.NoError(t, err)
+
+	first, err := s.Backup(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(cfg.DataPath, "backups"), filepath.Dir(first.Path))
+	require.Positive(t, first.Size)
+
+	backup, err := xorm.NewEngine(migrator.SQLite, first.Path)
+	require.NoError(t, err)
+	count, err := backup.Table("tag").Count()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+	require.NoError(t, backup.Close())
+
+	// only the newest backup is kept
+	second, err := s.Backup(context.Background())
+	require.NoError(t, err)
+	require.NotEqual(t, first.Path, second.Path)
+	entries, err := os.ReadDir(filepath.Dir(second.Path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, filepath.Base(second.Path), entries[0].Name())
+
+	require.Equal(t, []Backup{first, second}, replicator.snapshots)
+}
@@ -0,0 +1,62 @@
+package sqlitebackup
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+var (
+	ErrUnsupportedDatabase = errutil.BadRequest(
+		"sqlitebackup.unsupported-database", errutil.WithPublicMessage("Online backups are only supported with the sqlite3 database"))
+	ErrAlreadyRunning = errutil.Conflict(
+		"sqlitebackup.already-running", errutil.WithPublicMessage("A backup is already running"))
+)
+
+// Backup is a consistent snapshot of the SQLite database, which can replace the database file while Grafana is stopped
+type Backup struct {
+	// Path of the snapshot on the Grafana server
+	Path string `json:"path"`
+	// Size of the snapshot in bytes
+	Size int64 `json:"size"`
+	// Duration of the backup in milliseconds
+	Duration  int64     `json:"duration"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Replicator streams the changes of the SQLite database to a remote storage, eg: the frames of its
+// write-ahead log to object storage, so that the database can be restored at any point in time.
+//
+// SQLite checkpoints the write-ahead log into the database file automatically, so the replicator
+// must keep the frames it hasn't shipped yet from being checkpointed, eg: by holding a read
+// transaction and running the checkpoints itself, like Litestream does.
+type Replicator interface {
+	// Enabled reports whether the replicator is configured, the database is only replicated by an
+	// enabled replicator
+	Enabled() bool
+	// Replicate streams the write-ahead log of the database file at path until the context is done
+	Replicate(ctx context.Context, path string) error
+	// Snapshot is called with each backup, eg: to upload it as the base of a new generation of the
+	// replicated write-ahead log
+	Snapshot(ctx context.Context, backup Backup) error
+}
+
+// OSSReplicator doesn't replicate the database
+type OSSReplicator struct{}
+
+func ProvideOSSReplicator() *OSSReplicator {
+	return &OSSReplicator{}
+}
+
+func (*OSSReplicator) Enabled() bool {
+	return false
+}
+
+func (*OSSReplicator) Replicate(ctx context.Context, path string) error {
+	return nil
+}
+
+func (*OSSReplicator) Snapshot(ctx context.Context, backup Backup) error {
+	return nil
+}
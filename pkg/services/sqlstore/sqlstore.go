@@ -51,6 +51,10 @@ type SQLStore struct {
 	tracer                       tracing.Tracer
 	recursiveQueriesAreSupported *bool
 	recursiveQueriesMu           sync.Mutex
+	// replicas serve the reads of WithReadReplicaSession, nil when no replica is configured
+	replicas *replicaSet
+	// queryStats records the queries of each store function, nil when neither instrument_queries nor slow_query_threshold is set
+	queryStats *queryStats
 }
 
 func ProvideService(cfg *setting.Cfg,
@@ -246,6 +250,9 @@ func (ss *SQLStore) initEngine(engine *xorm.Engine) error {
 	if ss.cfg.DatabaseInstrumentQueries {
 		ss.dbCfg.Type = WrapDatabaseDriverWithHooks(ss.dbCfg.Type, ss.tracer)
 	}
+	if ss.cfg.DatabaseInstrumentQueries || ss.dbCfg.SlowQueryThreshold > 0 {
+		ss.queryStats = newQueryStats(ss.dbCfg.SlowQueryThreshold)
+	}
 
 	ss.log.Info("Connecting to DB", "dbtype", ss.dbCfg.Type)
 	if ss.dbCfg.Type == migrator.SQLite && strings.HasPrefix(ss.dbCfg.ConnectionString, "file:") &&
@@ -278,14 +285,7 @@ func (ss *SQLStore) initEngine(engine *xorm.Engine) error {
 		}
 	}
 	if engine == nil {
-		// Ensure that parseTime is enabled for MySQL
-		if strings.Contains(ss.dbCfg.Type, migrator.MySQL) && !strings.Contains(ss.dbCfg.ConnectionString, "parseTime=") {
-			if strings.Contains(ss.dbCfg.ConnectionString, "?") {
-				ss.dbCfg.ConnectionString += "&parseTime=true"
-			} else {
-				ss.dbCfg.ConnectionString += "?parseTime=true"
-			}
-		}
+		ss.dbCfg.ConnectionString = withMySQLParseTime(ss.dbCfg.Type, ss.dbCfg.ConnectionString)
 
 		var err error
 		engine, err = xorm.NewEngine(ss.dbCfg.Type, ss.dbCfg.ConnectionString)
@@ -302,20 +302,7 @@ func (ss *SQLStore) initEngine(engine *xorm.Engine) error {
 		}
 	}
 
-	engine.SetMaxOpenConns(ss.dbCfg.MaxOpenConn)
-	engine.SetMaxIdleConns(ss.dbCfg.MaxIdleConn)
-	engine.SetConnMaxLifetime(time.Second * time.Duration(ss.dbCfg.ConnMaxLifetime))
-
-	// configure sql logging
-	debugSQL := ss.cfg.Raw.Section("database").Key("log_queries").MustBool(false)
-	if !debugSQL {
-		engine.SetLogger(&xorm.DiscardLogger{})
-	} else {
-		// add stack to database calls to be able to see what repository initiated queries. Top 7 items from the stack as they are likely in the xorm library.
-		engine.SetLogger(NewXormLogger(log.LvlInfo, log.WithSuffix(log.New("sqlstore.xorm"), log.CallerContextKey, log.StackCaller(log.DefaultCallerDepth))))
-		engine.ShowSQL(true)
-		engine.ShowExecTime(true)
-	}
+	ss.configureEngine(engine, ss.dbCfg)
 
 	// initialize and register metrics wrapper around the *sql.DB
 	db := engine.DB().DB
@@ -331,7 +318,39 @@ func (ss *SQLStore) initEngine(engine *xorm.Engine) error {
 	}
 
 	ss.engine = engine
-	return nil
+	return ss.initReplicas()
+}
+
+// configureEngine sets the connection pool, the query logging and the query statistics of the engine
+func (ss *SQLStore) configureEngine(engine *xorm.Engine, dbCfg *DatabaseConfig) {
+	engine.SetMaxOpenConns(dbCfg.MaxOpenConn)
+	engine.SetMaxIdleConns(dbCfg.MaxIdleConn)
+	engine.SetConnMaxLifetime(time.Second * time.Duration(dbCfg.ConnMaxLifetime))
+	if ss.queryStats != nil {
+		engine.SetQueryObserver(ss.queryStats.observe)
+	}
+
+	// configure sql logging
+	debugSQL := ss.cfg.Raw.Section("database").Key("log_queries").MustBool(false)
+	if !debugSQL {
+		engine.SetLogger(&xorm.DiscardLogger{})
+	} else {
+		// add stack to database calls to be able to see what repository initiated queries. Top 7 items from the stack as they are likely in the xorm library.
+		engine.SetLogger(NewXormLogger(log.LvlInfo, log.WithSuffix(log.New("sqlstore.xorm"), log.CallerContextKey, log.StackCaller(log.DefaultCallerDepth))))
+		engine.ShowSQL(true)
+		engine.ShowExecTime(true)
+	}
+}
+
+// withMySQLParseTime ensures that parseTime is enabled for MySQL
+func withMySQLParseTime(dbType, connectionString string) string {
+	if !strings.Contains(dbType, migrator.MySQL) || strings.Contains(connectionString, "parseTime=") {
+		return connectionString
+	}
+	if strings.Contains(connectionString, "?") {
+		return connectionString + "&parseTime=true"
+	}
+	return connectionString + "?parseTime=true"
 }
 
 // The transaction_isolation system variable isn't compatible with MySQL < 5.7.20 or MariaDB. If we get an error saying this
@@ -15,7 +15,7 @@ var tsclogger = log.New("sqlstore.transactions")
 
 // WithTransactionalDbSession calls the callback with a session within a transaction.
 func (ss *SQLStore) WithTransactionalDbSession(ctx context.Context, callback DBTransactionFunc) error {
-	return ss.inTransactionWithRetryCtx(ctx, ss.engine, ss.bus, callback, 0)
+	return ss.inTransactionWithRetryCtx(ss.withCallSite(ctx), ss.engine, ss.bus, callback, 0)
 }
 
 // InTransaction starts a transaction and calls the fn
@@ -74,6 +74,9 @@ func (ss *SQLStore) inTransactionWithRetryCtx(ctx context.Context, engine *xorm.
 		}
 	}
 
+	if err == nil {
+		err = ss.writeOutbox(sess)
+	}
 	if err != nil {
 		if rollErr := sess.Rollback(); rollErr != nil {
 			return fmt.Errorf("rolling back transaction due to error failed: %s: %w", rollErr, err)
@@ -84,11 +87,20 @@ func (ss *SQLStore) inTransactionWithRetryCtx(ctx context.Context, engine *xorm.
 		return err
 	}
 
-	for _, e := range sess.events {
+	var delivered []int64
+	for i, e := range sess.events {
 		if err = bus.Publish(ctx, e); err != nil {
 			ctxLogger.Error("Failed to publish event after commit.", "error", err)
+			continue
+		}
+		if i < len(sess.outboxIDs) && sess.outboxIDs[i] != 0 {
+			delivered = append(delivered, sess.outboxIDs[i])
 		}
 	}
+	// the events left in the outbox are redelivered by the outbox dispatcher
+	if err := ss.acknowledgeOutbox(ctx, engine, delivered); err != nil {
+		ctxLogger.Warn("Failed to delete delivered events from the outbox", "error", err)
+	}
 
 	return nil
 }
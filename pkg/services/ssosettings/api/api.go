@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"hash/fnv"
 	"net/http"
+	"strconv"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
@@ -72,6 +73,9 @@ func (api *Api) RegisterAPIEndpoints() {
 		router.Get("/:key", auth(ac.EvalPermission(ac.ActionSettingsRead, settingsScope)), routing.Wrap(api.getProviderSettings))
 		router.Put("/:key", reqWriteAccess, routing.Wrap(api.updateProviderSettings))
 		router.Delete("/:key", reqWriteAccess, routing.Wrap(api.removeProviderSettings))
+		router.Post("/:key/validate", reqWriteAccess, routing.Wrap(api.validateProviderSettings))
+		router.Get("/:key/history", auth(ac.EvalPermission(ac.ActionSettingsRead, settingsScope)), routing.Wrap(api.listProviderSettingsVersions))
+		router.Post("/:key/history/:version/rollback", reqWriteAccess, routing.Wrap(api.rollbackProviderSettings))
 	})
 }
 
@@ -215,6 +219,108 @@ func (api *Api) removeProviderSettings(c *contextmodel.ReqContext) response.Resp
 	return response.Empty(http.StatusNoContent)
 }
 
+// swagger:route POST /v1/sso-settings/{key}/validate sso_settings validateProviderSettings
+//
+// # Validate SSO Settings
+//
+// Validates the SSO Settings for a provider without saving them. Besides the validation done on update,
+// it fetches the OpenID Connect discovery document or the SAML IdP metadata, checks the configured certificates
+// and returns the URL users would be redirected to when signing in.
+//
+// You need to have a permission with action `settings:write` and scope `settings:auth.<provider>:*`.
+//
+// Responses:
+// 200: validateSSOSettingsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (api *Api) validateProviderSettings(c *contextmodel.ReqContext) response.Response {
+	key, ok := web.Params(c.Req)[":key"]
+	if !ok {
+		return response.Error(http.StatusBadRequest, "Missing key", nil)
+	}
+
+	var settings models.SSOSettings
+	if err := web.Bind(c.Req, &settings); err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to parse request body", err)
+	}
+
+	settings.Provider = key
+
+	result, err := api.SSOSettingsService.Validate(c.Req.Context(), &settings, c.SignedInUser)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to validate provider settings", err)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route GET /v1/sso-settings/{key}/history sso_settings listProviderSettingsVersions
+//
+// # List the previous versions of SSO Settings
+//
+// Returns the previous versions of the SSO Settings for a provider, newest first. Secrets are redacted.
+//
+// You need to have a permission with action `settings:read` with scope `settings:auth.<provider>:*`.
+//
+// Responses:
+// 200: listSSOSettingsVersionsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (api *Api) listProviderSettingsVersions(c *contextmodel.ReqContext) response.Response {
+	key, ok := web.Params(c.Req)[":key"]
+	if !ok {
+		return response.Error(http.StatusBadRequest, "Missing key", nil)
+	}
+
+	versions, err := api.SSOSettingsService.ListVersions(c.Req.Context(), key)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list provider settings versions", err)
+	}
+
+	return response.JSON(http.StatusOK, versions)
+}
+
+// swagger:route POST /v1/sso-settings/{key}/history/{version}/rollback sso_settings rollbackProviderSettings
+//
+// # Roll back SSO Settings
+//
+// Restores a previous version of the SSO Settings for a provider. The restored settings are validated
+// like an update and recorded as a new version.
+//
+// You need to have a permission with action `settings:write` and scope `settings:auth.<provider>:*`.
+//
+// Responses:
+// 204: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (api *Api) rollbackProviderSettings(c *contextmodel.ReqContext) response.Response {
+	key, ok := web.Params(c.Req)[":key"]
+	if !ok {
+		return response.Error(http.StatusBadRequest, "Missing key", nil)
+	}
+
+	version, err := strconv.ParseInt(web.Params(c.Req)[":version"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Version is invalid", err)
+	}
+
+	err = api.SSOSettingsService.Rollback(c.Req.Context(), key, version, c.SignedInUser)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to roll back provider settings", err)
+	}
+
+	return response.Empty(http.StatusNoContent)
+}
+
 // swagger:parameters listAllProvidersSettings
 type ListAllProvidersSettingsParams struct {
 }
@@ -268,3 +374,52 @@ type GetSSOSettingsResponse struct {
 		Source   string         `json:"source"`
 	} `json:"body"`
 }
+
+// swagger:parameters validateProviderSettings
+type ValidateProviderSettingsParams struct {
+	// in:path
+	// required:true
+	Provider string `json:"key"`
+	// in:body
+	// required:true
+	Body struct {
+		ID       string         `json:"id"`
+		Provider string         `json:"provider"`
+		Settings map[string]any `json:"settings"`
+	} `json:"body"`
+}
+
+// swagger:parameters listProviderSettingsVersions
+type ListProviderSettingsVersionsParams struct {
+	// in:path
+	// required:true
+	Provider string `json:"key"`
+}
+
+// swagger:parameters rollbackProviderSettings
+type RollbackProviderSettingsParams struct {
+	// in:path
+	// required:true
+	Provider string `json:"key"`
+	// in:path
+	// required:true
+	Version int64 `json:"version"`
+}
+
+// swagger:response validateSSOSettingsResponse
+type ValidateSSOSettingsResponse struct {
+	// in: body
+	Body models.ValidationResult `json:"body"`
+}
+
+// swagger:response listSSOSettingsVersionsResponse
+type ListSSOSettingsVersionsResponse struct {
+	// in: body
+	Body []struct {
+		Provider  string         `json:"provider"`
+		Version   int64          `json:"version"`
+		Settings  map[string]any `json:"settings"`
+		Created   string         `json:"created"`
+		CreatedBy string         `json:"createdBy"`
+	} `json:"body"`
+}
@@ -549,6 +549,148 @@ func TestSSOSettingsAPI_List(t *testing.T) {
 	}
 }
 
+func TestSSOSettingsAPI_Validate(t *testing.T) {
+	type TestCase struct {
+		desc                string
+		action              string
+		scope               string
+		expectedError       error
+		expectedServiceCall bool
+		expectedStatusCode  int
+	}
+
+	tests := []TestCase{
+		{
+			desc:                "successfully validates SSO settings",
+			action:              "settings:write",
+			scope:               "settings:auth.azuread:*",
+			expectedServiceCall: true,
+			expectedStatusCode:  http.StatusOK,
+		},
+		{
+			desc:                "fails when action doesn't match",
+			action:              "settings:read",
+			scope:               "settings:auth.azuread:*",
+			expectedServiceCall: false,
+			expectedStatusCode:  http.StatusForbidden,
+		},
+		{
+			desc:                "fails with not found when the provider is not configurable",
+			action:              "settings:write",
+			scope:               "settings:auth.azuread:*",
+			expectedError:       ssosettings.ErrNotConfigurable,
+			expectedServiceCall: true,
+			expectedStatusCode:  http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			expected := &models.ValidationResult{
+				Valid:   true,
+				Checks:  []models.ValidationCheck{{Name: "settings", Status: models.ValidationPassed}},
+				AuthURL: "https://login.microsoftonline.com/tenant/oauth2/v2.0/authorize?client_id=grafana",
+			}
+			service := ssosettingstests.NewMockService(t)
+			if tt.expectedServiceCall {
+				service.On("Validate", mock.Anything, mock.MatchedBy(func(settings *models.SSOSettings) bool {
+					return settings.Provider == social.AzureADProviderName && settings.Settings["client_id"] == "grafana"
+				}), mock.Anything).Return(expected, tt.expectedError).Once()
+			}
+			server := setupTests(t, service)
+
+			body := `{"settings": {"clientId": "grafana"}}`
+			req := server.NewRequest(http.MethodPost, "/api/v1/sso-settings/azuread/validate", bytes.NewBufferString(body))
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{
+				OrgRole:     org.RoleEditor,
+				OrgID:       1,
+				Permissions: getPermissionsForActionAndScope(tt.action, tt.scope),
+			})
+			res, err := server.SendJSON(req)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.expectedStatusCode, res.StatusCode)
+			if tt.expectedStatusCode == http.StatusOK {
+				var actual models.ValidationResult
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&actual))
+				require.Equal(t, *expected, actual)
+			}
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
+func TestSSOSettingsAPI_Rollback(t *testing.T) {
+	type TestCase struct {
+		desc                string
+		version             string
+		action              string
+		scope               string
+		expectedError       error
+		expectedServiceCall bool
+		expectedStatusCode  int
+	}
+
+	tests := []TestCase{
+		{
+			desc:                "successfully rolls back SSO settings",
+			version:             "3",
+			action:              "settings:write",
+			scope:               "settings:auth.github:*",
+			expectedServiceCall: true,
+			expectedStatusCode:  http.StatusNoContent,
+		},
+		{
+			desc:                "fails when action doesn't match",
+			version:             "3",
+			action:              "settings:read",
+			scope:               "settings:auth.github:*",
+			expectedServiceCall: false,
+			expectedStatusCode:  http.StatusForbidden,
+		},
+		{
+			desc:                "fails with bad request when the version is invalid",
+			version:             "latest",
+			action:              "settings:write",
+			scope:               "settings:auth.github:*",
+			expectedServiceCall: false,
+			expectedStatusCode:  http.StatusBadRequest,
+		},
+		{
+			desc:                "fails with not found when the version doesn't exist",
+			version:             "3",
+			action:              "settings:write",
+			scope:               "settings:auth.github:*",
+			expectedError:       ssosettings.ErrVersionNotFound.Errorf("not found"),
+			expectedServiceCall: true,
+			expectedStatusCode:  http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			service := ssosettingstests.NewMockService(t)
+			if tt.expectedServiceCall {
+				service.On("Rollback", mock.Anything, social.GitHubProviderName, int64(3), mock.Anything).Return(tt.expectedError).Once()
+			}
+			server := setupTests(t, service)
+
+			path := fmt.Sprintf("/api/v1/sso-settings/github/history/%s/rollback", tt.version)
+			req := server.NewRequest(http.MethodPost, path, nil)
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{
+				OrgRole:     org.RoleEditor,
+				OrgID:       1,
+				Permissions: getPermissionsForActionAndScope(tt.action, tt.scope),
+			})
+			res, err := server.SendJSON(req)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.expectedStatusCode, res.StatusCode)
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
 func getPermissionsForActionAndScope(action, scope string) map[int64]map[string][]string {
 	return map[int64]map[string][]string{
 		1: accesscontrol.GroupScopesByActionContext(context.Background(), []accesscontrol.Permission{{
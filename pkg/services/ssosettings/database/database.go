@@ -151,3 +151,68 @@ func (s *SSOSettingsStore) Delete(ctx context.Context, provider string) error {
 		return err
 	})
 }
+
+func (s *SSOSettingsStore) AddVersion(ctx context.Context, version *models.SSOSettingsVersion, limit int) error {
+	if version.Provider == "" {
+		return ssosettings.ErrNotFound
+	}
+
+	return s.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		latest := &models.SSOSettingsVersion{}
+		found, err := sess.Where("provider = ?", version.Provider).Desc("version").Get(latest)
+		if err != nil {
+			return err
+		}
+
+		version.Version = 1
+		if found {
+			version.Version = latest.Version + 1
+		}
+		version.Created = time.Now().UTC()
+
+		if _, err := sess.Insert(version); err != nil {
+			return err
+		}
+
+		if limit <= 0 {
+			return nil
+		}
+		_, err = sess.Exec("DELETE FROM sso_setting_history WHERE provider = ? AND version <= ?", version.Provider, version.Version-int64(limit))
+		return err
+	})
+}
+
+func (s *SSOSettingsStore) ListVersions(ctx context.Context, provider string) ([]*models.SSOSettingsVersion, error) {
+	result := make([]*models.SSOSettingsVersion, 0)
+
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("provider = ?", provider).Desc("version").Find(&result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *SSOSettingsStore) GetVersion(ctx context.Context, provider string, version int64) (*models.SSOSettingsVersion, error) {
+	result := &models.SSOSettingsVersion{}
+
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		found, err := sess.Where("provider = ? AND version = ?", provider, version).Get(result)
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			return ssosettings.ErrVersionNotFound.Errorf("version %d of provider %s not found", version, provider)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
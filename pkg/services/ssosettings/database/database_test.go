@@ -457,6 +457,63 @@ func TestIntegrationDeleteSSOSettings(t *testing.T) {
 	})
 }
 
+func TestIntegrationSSOSettingsVersions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	var ssoSettingsStore *SSOSettingsStore
+
+	setup := func() {
+		ssoSettingsStore = ProvideStore(db.InitTestDB(t))
+	}
+
+	addVersions := func(t *testing.T, provider string, count int, limit int) {
+		for i := 0; i < count; i++ {
+			err := ssoSettingsStore.AddVersion(context.Background(), &models.SSOSettingsVersion{
+				Provider:  provider,
+				Settings:  map[string]any{"client_id": provider + "-" + string(rune('a'+i))},
+				CreatedBy: "admin",
+			}, limit)
+			require.NoError(t, err)
+		}
+	}
+
+	t.Run("numbers the versions of each provider", func(t *testing.T) {
+		setup()
+
+		addVersions(t, "github", 3, 0)
+		addVersions(t, "okta", 1, 0)
+
+		versions, err := ssoSettingsStore.ListVersions(context.Background(), "github")
+		require.NoError(t, err)
+		require.Len(t, versions, 3)
+		require.EqualValues(t, 3, versions[0].Version)
+		require.Equal(t, "github-c", versions[0].Settings["client_id"])
+		require.Equal(t, "admin", versions[0].CreatedBy)
+		require.WithinDuration(t, time.Now().UTC(), versions[0].Created, withinDuration)
+
+		version, err := ssoSettingsStore.GetVersion(context.Background(), "okta", 1)
+		require.NoError(t, err)
+		require.Equal(t, "okta-a", version.Settings["client_id"])
+	})
+
+	t.Run("removes the versions beyond the limit", func(t *testing.T) {
+		setup()
+
+		addVersions(t, "github", 5, 2)
+
+		versions, err := ssoSettingsStore.ListVersions(context.Background(), "github")
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		require.EqualValues(t, 5, versions[0].Version)
+		require.EqualValues(t, 4, versions[1].Version)
+
+		_, err = ssoSettingsStore.GetVersion(context.Background(), "github", 1)
+		require.ErrorIs(t, err, ssosettings.ErrVersionNotFound)
+	})
+}
+
 func populateSSOSettings(sqlStore db.DB, template models.SSOSettings, providers ...string) error {
 	return sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
 		for _, provider := range providers {
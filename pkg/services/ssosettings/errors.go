@@ -21,4 +21,6 @@ var (
 	ErrInvalidProvider = errutil.ValidationFailed("sso.invalidProvider", errutil.WithPublicMessage("Provider is invalid"))
 	ErrInvalidSettings = errutil.ValidationFailed("sso.settings", errutil.WithPublicMessage("Settings field is invalid"))
 	ErrEmptyClientId   = errutil.ValidationFailed("sso.emptyClientId", errutil.WithPublicMessage("ClientId cannot be empty"))
+
+	ErrVersionNotFound = errutil.NotFound("sso.versionNotFound", errutil.WithPublicMessage("The settings version was not found."))
 )
@@ -97,3 +97,67 @@ func (s *SSOSettings) UnmarshalJSON(data []byte) error {
 	s.Settings = settings
 	return nil
 }
+
+// SSOSettingsVersion is a previous version of the settings of a provider, secrets are stored encrypted
+type SSOSettingsVersion struct {
+	ID        int64          `xorm:"pk autoincr 'id'" json:"-"`
+	Provider  string         `xorm:"provider" json:"provider"`
+	Version   int64          `xorm:"version" json:"version"`
+	Settings  map[string]any `xorm:"settings" json:"settings"`
+	Created   time.Time      `xorm:"created" json:"created"`
+	CreatedBy string         `xorm:"created_by" json:"createdBy"`
+}
+
+// TableName returns the table name (needed for Xorm)
+func (v SSOSettingsVersion) TableName() string {
+	return "sso_setting_history"
+}
+
+// MarshalJSON implements the json.Marshaler interface and converts the v.Settings from map[string]any in snake_case to map[string]any in camelCase
+func (v SSOSettingsVersion) MarshalJSON() ([]byte, error) {
+	type Alias SSOSettingsVersion
+	aux := &struct {
+		*Alias
+	}{
+		Alias: (*Alias)(&v),
+	}
+
+	settings := make(map[string]any)
+	for k, val := range aux.Settings {
+		settings[strcase.ToLowerCamel(k)] = val
+	}
+
+	aux.Settings = settings
+	return json.Marshal(aux)
+}
+
+type ValidationStatus string
+
+const (
+	ValidationPassed  ValidationStatus = "passed"
+	ValidationWarning ValidationStatus = "warning"
+	ValidationFailed  ValidationStatus = "failed"
+)
+
+// ValidationResult is the outcome of validating the settings of a provider without saving them
+type ValidationResult struct {
+	// Valid is false when at least one check failed
+	Valid  bool              `json:"valid"`
+	Checks []ValidationCheck `json:"checks"`
+	// AuthURL is the URL users would be redirected to when signing in with the settings
+	AuthURL string `json:"authUrl,omitempty"`
+}
+
+type ValidationCheck struct {
+	Name    string           `json:"name"`
+	Status  ValidationStatus `json:"status"`
+	Message string           `json:"message,omitempty"`
+}
+
+// AddCheck adds the check to the result and marks the result invalid when the check failed
+func (r *ValidationResult) AddCheck(name string, status ValidationStatus, message string) {
+	r.Checks = append(r.Checks, ValidationCheck{Name: name, Status: status, Message: message})
+	if status == ValidationFailed {
+		r.Valid = false
+	}
+}
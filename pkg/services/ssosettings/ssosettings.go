@@ -34,6 +34,12 @@ type Service interface {
 	RegisterReloadable(provider string, reloadable Reloadable)
 	// Reload reloads the settings for a given provider
 	Reload(ctx context.Context, provider string)
+	// Validate checks the SSO settings for a given provider without saving them, it reaches out to the identity provider
+	Validate(ctx context.Context, settings *models.SSOSettings, requester identity.Requester) (*models.ValidationResult, error)
+	// ListVersions returns the previous versions of the SSO settings for a given provider with secret values redacted, newest first
+	ListVersions(ctx context.Context, provider string) ([]*models.SSOSettingsVersion, error)
+	// Rollback restores a previous version of the SSO settings for a given provider, the restored settings are stored as a new version
+	Rollback(ctx context.Context, provider string, version int64, requester identity.Requester) error
 }
 
 // Reloadable is an interface that can be implemented by a provider to allow it to be validated and reloaded
@@ -61,6 +67,10 @@ type Store interface {
 	List(ctx context.Context) ([]*models.SSOSettings, error)
 	Upsert(ctx context.Context, settings *models.SSOSettings) error
 	Delete(ctx context.Context, provider string) error
+	// AddVersion stores the settings as the next version of the provider and removes the versions beyond the limit
+	AddVersion(ctx context.Context, version *models.SSOSettingsVersion, limit int) error
+	ListVersions(ctx context.Context, provider string) ([]*models.SSOSettingsVersion, error)
+	GetVersion(ctx context.Context, provider string, version int64) (*models.SSOSettingsVersion, error)
 }
 
 type ValidateFunc[T any] func(input *T, requester identity.Requester) error
@@ -0,0 +1,61 @@
+package ssosettingsimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/ssosettings"
+	"github.com/grafana/grafana/pkg/services/ssosettings/models"
+)
+
+// addVersion records the stored settings in the history of the provider, the secrets are already encrypted.
+// The settings are saved at this point so a failure is only logged.
+func (s *Service) addVersion(ctx context.Context, settings *models.SSOSettings, requester identity.Requester) {
+	version := &models.SSOSettingsVersion{
+		Provider: settings.Provider,
+		Settings: settings.Settings,
+	}
+	if requester != nil {
+		version.CreatedBy = requester.GetLogin()
+	}
+
+	if err := s.store.AddVersion(ctx, version, s.cfg.SSOSettingsHistoryLimit); err != nil {
+		s.logger.FromContext(ctx).Warn("Failed to record the SSO settings version", "provider", settings.Provider, "error", err)
+	}
+}
+
+func (s *Service) ListVersions(ctx context.Context, provider string) ([]*models.SSOSettingsVersion, error) {
+	if !s.isProviderConfigurable(provider) {
+		return nil, ssosettings.ErrNotConfigurable
+	}
+
+	versions, err := s.store.ListVersions(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, version := range versions {
+		version.Settings = removeSecrets(version.Settings)
+	}
+
+	return versions, nil
+}
+
+func (s *Service) Rollback(ctx context.Context, provider string, version int64, requester identity.Requester) error {
+	if !s.isProviderConfigurable(provider) {
+		return ssosettings.ErrNotConfigurable
+	}
+
+	stored, err := s.store.GetVersion(ctx, provider, version)
+	if err != nil {
+		return err
+	}
+
+	settings, err := s.decryptSecrets(ctx, deepCopyMap(stored.Settings))
+	if err != nil {
+		return err
+	}
+
+	// the restored settings go through the same validation as an update and are recorded as a new version
+	return s.Upsert(ctx, &models.SSOSettings{Provider: provider, Settings: settings}, requester)
+}
@@ -0,0 +1,110 @@
+package ssosettingsimpl
+
+import (
+	"context"
+	"encoding/base64"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/login/social"
+	"github.com/grafana/grafana/pkg/services/ssosettings"
+	"github.com/grafana/grafana/pkg/services/ssosettings/models"
+	"github.com/grafana/grafana/pkg/services/ssosettings/ssosettingstests"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService_ListVersions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns the versions with redacted secrets", func(t *testing.T) {
+		t.Parallel()
+
+		env := setupTestEnv(t, false, false, false)
+		env.store.ActualVersions = []*models.SSOSettingsVersion{
+			{Provider: social.AzureADProviderName, Version: 1, Settings: map[string]any{"client_id": "first", "client_secret": "encrypted"}},
+			{Provider: social.GitHubProviderName, Version: 2, Settings: map[string]any{"client_id": "github"}},
+			{Provider: social.AzureADProviderName, Version: 3, Settings: map[string]any{"client_id": "second", "client_secret": "encrypted"}},
+		}
+
+		versions, err := env.service.ListVersions(context.Background(), social.AzureADProviderName)
+		require.NoError(t, err)
+
+		require.Len(t, versions, 2)
+		require.Equal(t, int64(3), versions[0].Version)
+		require.Equal(t, "second", versions[0].Settings["client_id"])
+		require.Equal(t, setting.RedactedPassword, versions[0].Settings["client_secret"])
+		require.Equal(t, int64(1), versions[1].Version)
+	})
+
+	t.Run("fails for providers that are not configurable", func(t *testing.T) {
+		t.Parallel()
+
+		env := setupTestEnv(t, false, false, false)
+
+		_, err := env.service.ListVersions(context.Background(), social.SAMLProviderName)
+		require.ErrorIs(t, err, ssosettings.ErrNotConfigurable)
+	})
+}
+
+func TestService_Rollback(t *testing.T) {
+	t.Parallel()
+
+	t.Run("restores the settings of a version and records it as a new version", func(t *testing.T) {
+		t.Parallel()
+
+		env := setupTestEnv(t, false, false, false)
+
+		provider := social.AzureADProviderName
+		env.store.ActualVersions = []*models.SSOSettingsVersion{
+			{
+				Provider: provider,
+				Version:  1,
+				Settings: map[string]any{
+					"client_id":     "previous-client-id",
+					"client_secret": base64.RawStdEncoding.EncodeToString([]byte("encrypted-previous-client-secret")),
+					"enabled":       true,
+				},
+			},
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+
+		reloadable := ssosettingstests.NewMockReloadable(t)
+		reloadable.On("Validate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		reloadable.On("Reload", mock.Anything, mock.MatchedBy(func(settings models.SSOSettings) bool {
+			defer wg.Done()
+			return settings.Settings["client_secret"] == "previous-client-secret"
+		})).Return(nil).Once()
+		env.reloadables[provider] = reloadable
+		env.secrets.On("Decrypt", mock.Anything, []byte("encrypted-previous-client-secret"), mock.Anything).Return([]byte("previous-client-secret"), nil).Once()
+		env.secrets.On("Encrypt", mock.Anything, []byte("previous-client-secret"), mock.Anything).Return([]byte("encrypted-client-secret"), nil).Once()
+
+		err := env.service.Rollback(context.Background(), provider, 1, &user.SignedInUser{Login: "admin"})
+		require.NoError(t, err)
+
+		wg.Wait()
+
+		require.Equal(t, "previous-client-id", env.store.ActualSSOSettings.Settings["client_id"])
+		require.Equal(t, base64.RawStdEncoding.EncodeToString([]byte("encrypted-client-secret")), env.store.ActualSSOSettings.Settings["client_secret"])
+
+		require.Len(t, env.store.ActualVersions, 2)
+		require.Equal(t, int64(2), env.store.ActualVersions[1].Version)
+		require.Equal(t, "admin", env.store.ActualVersions[1].CreatedBy)
+		// the stored version still references the encrypted secret
+		require.Equal(t, base64.RawStdEncoding.EncodeToString([]byte("encrypted-previous-client-secret")), env.store.ActualVersions[0].Settings["client_secret"])
+	})
+
+	t.Run("fails when the version doesn't exist", func(t *testing.T) {
+		t.Parallel()
+
+		env := setupTestEnv(t, false, false, false)
+
+		err := env.service.Rollback(context.Background(), social.AzureADProviderName, 1, &user.SignedInUser{})
+		require.ErrorIs(t, err, ssosettings.ErrVersionNotFound)
+	})
+}
@@ -219,6 +219,8 @@ func (s *Service) Upsert(ctx context.Context, settings *models.SSOSettings, requ
 		return err
 	}
 
+	s.addVersion(ctx, settings, requester)
+
 	// make a copy of current settings for reload operation and apply overrides
 	reloadSettings := *settings
 	reloadSettings.Settings = overrideMaps(storedSettings.Settings, settingsWithSecrets)
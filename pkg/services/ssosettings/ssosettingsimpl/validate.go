@@ -0,0 +1,451 @@
+package ssosettingsimpl
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/login/social"
+	"github.com/grafana/grafana/pkg/login/social/connectors"
+	"github.com/grafana/grafana/pkg/services/ssosettings"
+	"github.com/grafana/grafana/pkg/services/ssosettings/models"
+)
+
+const (
+	validationTimeout = 10 * time.Second
+	// certificateExpiryWarning is how long before their expiry certificates are reported
+	certificateExpiryWarning = 30 * 24 * time.Hour
+	// maxDiscoveryAttempts bounds the number of discovery document locations tried for an auth URL
+	maxDiscoveryAttempts = 4
+	maxResponseSize      = 1 << 20
+
+	checkSettings     = "settings"
+	checkTLS          = "tls"
+	checkDiscovery    = "discovery"
+	checkAuthURL      = "auth_url"
+	checkCertificate  = "certificate"
+	checkIdPMetadata  = "idp_metadata"
+	checkIdPSignature = "idp_certificate"
+)
+
+// Validate runs the validation of the provider and checks the settings against the identity provider:
+// it fetches the discovery document or the IdP metadata, checks the certificates and generates the URL
+// users would be redirected to when signing in. Nothing is saved or reloaded.
+func (s *Service) Validate(ctx context.Context, settings *models.SSOSettings, requester identity.Requester) (*models.ValidationResult, error) {
+	if !s.isProviderConfigurable(settings.Provider) {
+		return nil, ssosettings.ErrNotConfigurable
+	}
+
+	reloadable, ok := s.reloadables[settings.Provider]
+	if !ok {
+		return nil, ssosettings.ErrInvalidProvider.Errorf("provider %s not found in reloadables", settings.Provider)
+	}
+
+	storedSettings, err := s.GetForProvider(ctx, settings.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	settingsWithSecrets, err := mergeSecrets(settings.Settings, storedSettings.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ValidationResult{Valid: true, Checks: []models.ValidationCheck{}}
+
+	candidate := *settings
+	candidate.Settings = settingsWithSecrets
+	if err := reloadable.Validate(ctx, candidate, *storedSettings, requester); err != nil {
+		result.AddCheck(checkSettings, models.ValidationFailed, publicMessage(err))
+		return result, nil
+	}
+	result.AddCheck(checkSettings, models.ValidationPassed, "")
+
+	// the same settings as the ones applied on reload
+	effective := overrideMaps(storedSettings.Settings, settingsWithSecrets)
+
+	switch settings.Provider {
+	case social.LDAPProviderName:
+	case social.SAMLProviderName:
+		s.validateSAML(ctx, effective, result)
+	default:
+		s.validateOAuth(ctx, settings.Provider, effective, result)
+	}
+
+	return result, nil
+}
+
+func (s *Service) validateOAuth(ctx context.Context, provider string, settings map[string]any, result *models.ValidationResult) {
+	info, err := connectors.CreateOAuthInfoFromKeyValues(settings)
+	if err != nil {
+		result.AddCheck(checkSettings, models.ValidationFailed, err.Error())
+		return
+	}
+
+	tlsConfig, ok := validateOAuthTLS(info, result)
+	if !ok {
+		return
+	}
+	client := newValidationClient(tlsConfig)
+
+	// GitHub doesn't implement OpenID Connect
+	if provider != social.GitHubProviderName && info.AuthUrl != "" {
+		s.validateDiscovery(ctx, client, info, result)
+	}
+
+	if info.AuthUrl == "" {
+		result.AddCheck(checkAuthURL, models.ValidationFailed, "The auth URL is not set")
+		return
+	}
+	authURL, err := url.Parse(info.AuthUrl)
+	if err != nil || authURL.Host == "" {
+		result.AddCheck(checkAuthURL, models.ValidationFailed, fmt.Sprintf("The auth URL %q is invalid", info.AuthUrl))
+		return
+	}
+
+	config := oauth2.Config{
+		ClientID:    info.ClientId,
+		Endpoint:    oauth2.Endpoint{AuthURL: info.AuthUrl, TokenURL: info.TokenUrl},
+		RedirectURL: strings.TrimSuffix(s.cfg.AppURL, "/") + social.SocialBaseUrl + provider,
+		Scopes:      info.Scopes,
+	}
+	opts := []oauth2.AuthCodeOption{}
+	if info.UsePKCE {
+		opts = append(opts, oauth2.S256ChallengeOption(oauth2.GenerateVerifier()))
+	}
+	result.AuthURL = config.AuthCodeURL("dry-run", opts...)
+
+	if authURL.Scheme != "https" {
+		result.AddCheck(checkAuthURL, models.ValidationWarning, "The auth URL doesn't use https")
+		return
+	}
+	result.AddCheck(checkAuthURL, models.ValidationPassed, "")
+}
+
+// validateOAuthTLS checks the client certificate and the CA used to reach the identity provider,
+// it returns false when the TLS settings are unusable.
+func validateOAuthTLS(info *social.OAuthInfo, result *models.ValidationResult) (*tls.Config, bool) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: info.TlsSkipVerify} // #nosec G402 -- mirrors the setting of the provider
+	if info.TlsClientCert == "" && info.TlsClientKey == "" && info.TlsClientCa == "" {
+		if info.TlsSkipVerify {
+			result.AddCheck(checkTLS, models.ValidationWarning, "TLS certificate verification is disabled")
+		}
+		return tlsConfig, true
+	}
+
+	if info.TlsClientCert != "" || info.TlsClientKey != "" {
+		pair, err := tls.LoadX509KeyPair(info.TlsClientCert, info.TlsClientKey)
+		if err != nil {
+			result.AddCheck(checkTLS, models.ValidationFailed, fmt.Sprintf("Failed to load the TLS client certificate: %s", err))
+			return nil, false
+		}
+		cert, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			result.AddCheck(checkTLS, models.ValidationFailed, fmt.Sprintf("Failed to parse the TLS client certificate: %s", err))
+			return nil, false
+		}
+		if status, message := checkExpiry("TLS client certificate", cert); status != models.ValidationPassed {
+			result.AddCheck(checkTLS, status, message)
+			if status == models.ValidationFailed {
+				return nil, false
+			}
+		}
+		tlsConfig.Certificates = []tls.Certificate{pair}
+	}
+
+	if info.TlsClientCa != "" {
+		// #nosec G304 -- the path is set by an administrator
+		caPEM, err := os.ReadFile(info.TlsClientCa)
+		if err != nil {
+			result.AddCheck(checkTLS, models.ValidationFailed, fmt.Sprintf("Failed to read the TLS CA: %s", err))
+			return nil, false
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			result.AddCheck(checkTLS, models.ValidationFailed, "The TLS CA doesn't contain a PEM encoded certificate")
+			return nil, false
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if info.TlsSkipVerify {
+		result.AddCheck(checkTLS, models.ValidationWarning, "TLS certificate verification is disabled")
+	} else {
+		result.AddCheck(checkTLS, models.ValidationPassed, "")
+	}
+	return tlsConfig, true
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// validateDiscovery fetches the OpenID Connect discovery document of the issuer of the auth URL
+// and compares its endpoints with the configured ones
+func (s *Service) validateDiscovery(ctx context.Context, client *http.Client, info *social.OAuthInfo, result *models.ValidationResult) {
+	for _, location := range discoveryURLs(info.AuthUrl) {
+		body, err := fetch(ctx, client, location)
+		if err != nil {
+			s.logger.FromContext(ctx).Debug("Discovery document not found", "url", location, "error", err)
+			continue
+		}
+		doc := discoveryDocument{}
+		if err := json.Unmarshal(body, &doc); err != nil || doc.AuthorizationEndpoint == "" {
+			continue
+		}
+
+		var mismatches []string
+		if doc.AuthorizationEndpoint != info.AuthUrl {
+			mismatches = append(mismatches, fmt.Sprintf("the auth URL doesn't match the authorization endpoint %s", doc.AuthorizationEndpoint))
+		}
+		if info.TokenUrl != "" && doc.TokenEndpoint != "" && doc.TokenEndpoint != info.TokenUrl {
+			mismatches = append(mismatches, fmt.Sprintf("the token URL doesn't match the token endpoint %s", doc.TokenEndpoint))
+		}
+		if len(mismatches) > 0 {
+			result.AddCheck(checkDiscovery, models.ValidationWarning, fmt.Sprintf("Found the discovery document at %s but %s", location, strings.Join(mismatches, " and ")))
+			return
+		}
+		result.AddCheck(checkDiscovery, models.ValidationPassed, fmt.Sprintf("Found the discovery document at %s", location))
+		return
+	}
+
+	result.AddCheck(checkDiscovery, models.ValidationWarning, "No OpenID Connect discovery document was found for the auth URL")
+}
+
+// discoveryURLs returns the possible locations of the discovery document of an auth URL,
+// issuers are a prefix of the path of their authorization endpoint.
+func discoveryURLs(authURL string) []string {
+	u, err := url.Parse(authURL)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	// the last segment is the authorization endpoint itself
+	if len(segments) > 0 {
+		segments = segments[:len(segments)-1]
+	}
+
+	urls := make([]string, 0, maxDiscoveryAttempts)
+	for i := len(segments); i >= 0 && len(urls) < maxDiscoveryAttempts; i-- {
+		prefix := strings.Join(segments[:i], "/")
+		if prefix != "" {
+			prefix = "/" + prefix
+		}
+		urls = append(urls, u.Scheme+"://"+u.Host+prefix+"/.well-known/openid-configuration")
+	}
+	return urls
+}
+
+type idpMetadata struct {
+	XMLName          xml.Name `xml:"EntityDescriptor"`
+	EntityID         string   `xml:"entityID,attr"`
+	IDPSSODescriptor *struct {
+		KeyDescriptors []struct {
+			Use         string `xml:"use,attr"`
+			Certificate string `xml:"KeyInfo>X509Data>X509Certificate"`
+		} `xml:"KeyDescriptor"`
+		SingleSignOnServices []struct {
+			Binding  string `xml:"Binding,attr"`
+			Location string `xml:"Location,attr"`
+		} `xml:"SingleSignOnService"`
+	} `xml:"IDPSSODescriptor"`
+}
+
+const samlRedirectBinding = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+
+func (s *Service) validateSAML(ctx context.Context, settings map[string]any, result *models.ValidationResult) {
+	validateSAMLCertificate(settings, result)
+
+	metadata, ok := s.loadIdPMetadata(ctx, settings, result)
+	if !ok {
+		return
+	}
+
+	for _, key := range metadata.IDPSSODescriptor.KeyDescriptors {
+		if key.Use == "encryption" {
+			continue
+		}
+		der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(key.Certificate), ""))
+		if err != nil {
+			result.AddCheck(checkIdPSignature, models.ValidationFailed, fmt.Sprintf("Failed to decode the IdP signing certificate: %s", err))
+			continue
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			result.AddCheck(checkIdPSignature, models.ValidationFailed, fmt.Sprintf("Failed to parse the IdP signing certificate: %s", err))
+			continue
+		}
+		status, message := checkExpiry("IdP signing certificate", cert)
+		result.AddCheck(checkIdPSignature, status, message)
+	}
+
+	// the SSO URL of the IdP is where users are sent to sign in, the SAML request is added at login
+	for _, sso := range metadata.IDPSSODescriptor.SingleSignOnServices {
+		if result.AuthURL == "" || sso.Binding == samlRedirectBinding {
+			result.AuthURL = sso.Location
+		}
+	}
+	if result.AuthURL == "" {
+		result.AddCheck(checkAuthURL, models.ValidationFailed, "The IdP metadata doesn't contain a single sign-on service")
+		return
+	}
+	result.AddCheck(checkAuthURL, models.ValidationPassed, "")
+}
+
+// validateSAMLCertificate checks that the service provider certificate is valid and matches its private key
+func validateSAMLCertificate(settings map[string]any, result *models.ValidationResult) {
+	certPEM, err := loadPEM(settings, "certificate")
+	if err != nil {
+		result.AddCheck(checkCertificate, models.ValidationFailed, fmt.Sprintf("Failed to load the certificate: %s", err))
+		return
+	}
+	keyPEM, err := loadPEM(settings, "private_key")
+	if err != nil {
+		result.AddCheck(checkCertificate, models.ValidationFailed, fmt.Sprintf("Failed to load the private key: %s", err))
+		return
+	}
+	if certPEM == nil && keyPEM == nil {
+		return
+	}
+
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		result.AddCheck(checkCertificate, models.ValidationFailed, fmt.Sprintf("The certificate and private key are invalid: %s", err))
+		return
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		result.AddCheck(checkCertificate, models.ValidationFailed, fmt.Sprintf("Failed to parse the certificate: %s", err))
+		return
+	}
+	status, message := checkExpiry("certificate", cert)
+	result.AddCheck(checkCertificate, status, message)
+}
+
+func (s *Service) loadIdPMetadata(ctx context.Context, settings map[string]any, result *models.ValidationResult) (*idpMetadata, bool) {
+	var (
+		raw    []byte
+		source string
+		err    error
+	)
+	switch {
+	case stringSetting(settings, "idp_metadata") != "":
+		source = "the IdP metadata"
+		raw, err = base64.StdEncoding.DecodeString(stringSetting(settings, "idp_metadata"))
+	case stringSetting(settings, "idp_metadata_path") != "":
+		source = stringSetting(settings, "idp_metadata_path")
+		// #nosec G304 -- the path is set by an administrator
+		raw, err = os.ReadFile(source)
+	case stringSetting(settings, "idp_metadata_url") != "":
+		source = stringSetting(settings, "idp_metadata_url")
+		raw, err = fetch(ctx, newValidationClient(nil), source)
+	default:
+		result.AddCheck(checkIdPMetadata, models.ValidationFailed, "The IdP metadata is not set")
+		return nil, false
+	}
+	if err != nil {
+		result.AddCheck(checkIdPMetadata, models.ValidationFailed, fmt.Sprintf("Failed to load %s: %s", source, err))
+		return nil, false
+	}
+
+	metadata := &idpMetadata{}
+	if err := xml.Unmarshal(raw, metadata); err != nil {
+		result.AddCheck(checkIdPMetadata, models.ValidationFailed, fmt.Sprintf("Failed to parse %s: %s", source, err))
+		return nil, false
+	}
+	if metadata.IDPSSODescriptor == nil {
+		result.AddCheck(checkIdPMetadata, models.ValidationFailed, fmt.Sprintf("%s doesn't describe an identity provider", source))
+		return nil, false
+	}
+	result.AddCheck(checkIdPMetadata, models.ValidationPassed, fmt.Sprintf("Loaded the metadata of %s", metadata.EntityID))
+	return metadata, true
+}
+
+// checkExpiry reports certificates that are not valid yet, expired or about to expire
+func checkExpiry(name string, cert *x509.Certificate) (models.ValidationStatus, string) {
+	now := time.Now()
+	switch {
+	case now.Before(cert.NotBefore):
+		return models.ValidationFailed, fmt.Sprintf("The %s is not valid before %s", name, cert.NotBefore.UTC().Format(time.RFC3339))
+	case now.After(cert.NotAfter):
+		return models.ValidationFailed, fmt.Sprintf("The %s expired on %s", name, cert.NotAfter.UTC().Format(time.RFC3339))
+	case now.Add(certificateExpiryWarning).After(cert.NotAfter):
+		return models.ValidationWarning, fmt.Sprintf("The %s expires on %s", name, cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+	return models.ValidationPassed, ""
+}
+
+// loadPEM returns the PEM block of a SAML setting, which is either set as base64 encoded PEM or as a path.
+// It returns nil when neither is set.
+func loadPEM(settings map[string]any, key string) ([]byte, error) {
+	if value := stringSetting(settings, key); value != "" {
+		if block, _ := pem.Decode([]byte(value)); block != nil {
+			return []byte(value), nil
+		}
+		return base64.StdEncoding.DecodeString(value)
+	}
+	if path := stringSetting(settings, key+"_path"); path != "" {
+		// #nosec G304 -- the path is set by an administrator
+		return os.ReadFile(path)
+	}
+	return nil, nil
+}
+
+func stringSetting(settings map[string]any, key string) string {
+	value, _ := settings[key].(string)
+	return value
+}
+
+func newValidationClient(tlsConfig *tls.Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	return &http.Client{Transport: transport, Timeout: validationTimeout}
+}
+
+func fetch(ctx context.Context, client *http.Client, location string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+}
+
+// publicMessage returns the message of the error that can be shown to the user
+func publicMessage(err error) string {
+	var grafanaErr errutil.Error
+	if errors.As(err, &grafanaErr) && grafanaErr.PublicMessage != "" {
+		return grafanaErr.PublicMessage
+	}
+	return err.Error()
+}
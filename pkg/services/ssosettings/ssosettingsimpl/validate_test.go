@@ -0,0 +1,214 @@
+package ssosettingsimpl
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/login/social"
+	"github.com/grafana/grafana/pkg/services/ssosettings"
+	"github.com/grafana/grafana/pkg/services/ssosettings/models"
+	"github.com/grafana/grafana/pkg/services/ssosettings/ssosettingstests"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestService_Validate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("validates OAuth settings against the discovery document", func(t *testing.T) {
+		t.Parallel()
+
+		var issuer string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/realms/grafana/.well-known/openid-configuration" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(discoveryDocument{
+				Issuer:                issuer,
+				AuthorizationEndpoint: issuer + "/protocol/openid-connect/auth",
+				TokenEndpoint:         issuer + "/protocol/openid-connect/token",
+			})
+		}))
+		t.Cleanup(server.Close)
+		issuer = server.URL + "/realms/grafana"
+
+		env := setupTestEnv(t, false, false, false)
+		env.cfg.AppURL = "https://grafana.example.com/"
+		env.reloadables[social.GenericOAuthProviderName] = validReloadable(t)
+
+		result, err := env.service.Validate(context.Background(), &models.SSOSettings{
+			Provider: social.GenericOAuthProviderName,
+			Settings: map[string]any{
+				"client_id": "grafana",
+				"auth_url":  issuer + "/protocol/openid-connect/auth",
+				"token_url": issuer + "/protocol/openid-connect/other-token",
+				"scopes":    "openid email",
+			},
+		}, &user.SignedInUser{})
+		require.NoError(t, err)
+
+		assert.True(t, result.Valid)
+		assert.Equal(t, models.ValidationPassed, checkStatus(result, checkSettings))
+		assert.Equal(t, models.ValidationWarning, checkStatus(result, checkDiscovery), "the token URL doesn't match the discovery document")
+		assert.Equal(t, models.ValidationWarning, checkStatus(result, checkAuthURL), "the test server doesn't use https")
+		assert.Contains(t, result.AuthURL, issuer+"/protocol/openid-connect/auth?")
+		assert.Contains(t, result.AuthURL, "client_id=grafana")
+		assert.Contains(t, result.AuthURL, "redirect_uri=https%3A%2F%2Fgrafana.example.com%2Flogin%2Fgeneric_oauth")
+		assert.Contains(t, result.AuthURL, "scope=openid+email")
+	})
+
+	t.Run("stops when the provider rejects the settings", func(t *testing.T) {
+		t.Parallel()
+
+		env := setupTestEnv(t, false, false, false)
+		reloadable := ssosettingstests.NewMockReloadable(t)
+		reloadable.On("Validate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(ssosettings.ErrInvalidOAuthConfig("Auth URL is invalid"))
+		env.reloadables[social.GenericOAuthProviderName] = reloadable
+
+		result, err := env.service.Validate(context.Background(), &models.SSOSettings{
+			Provider: social.GenericOAuthProviderName,
+			Settings: map[string]any{"client_id": "grafana", "auth_url": "invalid"},
+		}, &user.SignedInUser{})
+		require.NoError(t, err)
+
+		assert.False(t, result.Valid)
+		require.Len(t, result.Checks, 1)
+		assert.Equal(t, "Auth URL is invalid", result.Checks[0].Message)
+		assert.Empty(t, result.AuthURL)
+	})
+
+	t.Run("validates the SAML certificates and IdP metadata", func(t *testing.T) {
+		t.Parallel()
+
+		env := setupTestEnv(t, true, false, false)
+		env.reloadables[social.SAMLProviderName] = validReloadable(t)
+
+		certPEM, keyPEM := generateCertificate(t, time.Now().Add(365*24*time.Hour))
+		idpCertPEM, _ := generateCertificate(t, time.Now().Add(10*24*time.Hour))
+		block, _ := pem.Decode(idpCertPEM)
+		metadata := fmt.Sprintf(`<EntityDescriptor xmlns="urn:oasis:names:tc:SAML:2.0:metadata" entityID="https://idp.example.com">
+  <IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <KeyDescriptor use="signing"><KeyInfo xmlns="http://www.w3.org/2000/09/xmldsig#"><X509Data><X509Certificate>%s</X509Certificate></X509Data></KeyInfo></KeyDescriptor>
+    <SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="https://idp.example.com/sso/post"/>
+    <SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="https://idp.example.com/sso/redirect"/>
+  </IDPSSODescriptor>
+</EntityDescriptor>`, base64.StdEncoding.EncodeToString(block.Bytes))
+
+		result, err := env.service.Validate(context.Background(), &models.SSOSettings{
+			Provider: social.SAMLProviderName,
+			Settings: map[string]any{
+				"certificate":  base64.StdEncoding.EncodeToString(certPEM),
+				"private_key":  base64.StdEncoding.EncodeToString(keyPEM),
+				"idp_metadata": base64.StdEncoding.EncodeToString([]byte(metadata)),
+			},
+		}, &user.SignedInUser{})
+		require.NoError(t, err)
+
+		assert.True(t, result.Valid)
+		assert.Equal(t, models.ValidationPassed, checkStatus(result, checkCertificate))
+		assert.Equal(t, models.ValidationPassed, checkStatus(result, checkIdPMetadata))
+		assert.Equal(t, models.ValidationWarning, checkStatus(result, checkIdPSignature), "the IdP certificate expires within 30 days")
+		assert.Equal(t, "https://idp.example.com/sso/redirect", result.AuthURL)
+	})
+
+	t.Run("fails when the SAML certificate doesn't match the private key", func(t *testing.T) {
+		t.Parallel()
+
+		env := setupTestEnv(t, true, false, false)
+		env.reloadables[social.SAMLProviderName] = validReloadable(t)
+
+		certPEM, _ := generateCertificate(t, time.Now().Add(365*24*time.Hour))
+		_, otherKeyPEM := generateCertificate(t, time.Now().Add(365*24*time.Hour))
+
+		result, err := env.service.Validate(context.Background(), &models.SSOSettings{
+			Provider: social.SAMLProviderName,
+			Settings: map[string]any{
+				"certificate": base64.StdEncoding.EncodeToString(certPEM),
+				"private_key": base64.StdEncoding.EncodeToString(otherKeyPEM),
+			},
+		}, &user.SignedInUser{})
+		require.NoError(t, err)
+
+		assert.False(t, result.Valid)
+		assert.Equal(t, models.ValidationFailed, checkStatus(result, checkCertificate))
+		assert.Equal(t, models.ValidationFailed, checkStatus(result, checkIdPMetadata))
+	})
+
+	t.Run("fails for providers that are not configurable", func(t *testing.T) {
+		t.Parallel()
+
+		env := setupTestEnv(t, false, false, false)
+
+		_, err := env.service.Validate(context.Background(), &models.SSOSettings{Provider: social.SAMLProviderName}, &user.SignedInUser{})
+		require.ErrorIs(t, err, ssosettings.ErrNotConfigurable)
+	})
+}
+
+func TestDiscoveryURLs(t *testing.T) {
+	assert.Equal(t, []string{
+		"https://idp.example.com/realms/grafana/protocol/openid-connect/.well-known/openid-configuration",
+		"https://idp.example.com/realms/grafana/protocol/.well-known/openid-configuration",
+		"https://idp.example.com/realms/grafana/.well-known/openid-configuration",
+		"https://idp.example.com/realms/.well-known/openid-configuration",
+	}, discoveryURLs("https://idp.example.com/realms/grafana/protocol/openid-connect/auth"))
+
+	assert.Equal(t, []string{
+		"https://accounts.example.com/.well-known/openid-configuration",
+	}, discoveryURLs("https://accounts.example.com/authorize"))
+
+	assert.Empty(t, discoveryURLs("invalid"))
+}
+
+func validReloadable(t *testing.T) *ssosettingstests.MockReloadable {
+	reloadable := ssosettingstests.NewMockReloadable(t)
+	reloadable.On("Validate", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	return reloadable
+}
+
+func checkStatus(result *models.ValidationResult, name string) models.ValidationStatus {
+	for _, check := range result.Checks {
+		if check.Name == name {
+			return check.Status
+		}
+	}
+	return ""
+}
+
+func generateCertificate(t *testing.T, notAfter time.Time) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "grafana"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+}
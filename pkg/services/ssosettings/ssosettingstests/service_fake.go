@@ -15,11 +15,14 @@ type FakeService struct {
 	ExpectedSSOSettings         []*models.SSOSettings
 	ExpectedError               error
 	ExpectedReloadablesRegistry map[string]ssosettings.Reloadable
+	ExpectedValidationResult    *models.ValidationResult
+	ExpectedVersions            []*models.SSOSettingsVersion
 
 	ActualSSOSettings models.SSOSettings
 	ActualPatchData   map[string]any
 	ActualProvider    string
 	ActualRequester   identity.Requester
+	ActualVersion     int64
 
 	ListFn                              func(ctx context.Context) ([]*models.SSOSettings, error)
 	ListWithRedactedSecretsFn           func(ctx context.Context) ([]*models.SSOSettings, error)
@@ -30,6 +33,9 @@ type FakeService struct {
 	PatchFn                             func(ctx context.Context, provider string, data map[string]any) error
 	RegisterReloadableFn                func(provider string, reloadable ssosettings.Reloadable)
 	ReloadFn                            func(ctx context.Context, provider string)
+	ValidateFn                          func(ctx context.Context, settings *models.SSOSettings, requester identity.Requester) (*models.ValidationResult, error)
+	ListVersionsFn                      func(ctx context.Context, provider string) ([]*models.SSOSettingsVersion, error)
+	RollbackFn                          func(ctx context.Context, provider string, version int64, requester identity.Requester) error
 }
 
 func NewFakeService() *FakeService {
@@ -117,3 +123,36 @@ func (f *FakeService) Reload(ctx context.Context, provider string) {
 
 	f.ActualProvider = provider
 }
+
+func (f *FakeService) Validate(ctx context.Context, settings *models.SSOSettings, requester identity.Requester) (*models.ValidationResult, error) {
+	if f.ValidateFn != nil {
+		return f.ValidateFn(ctx, settings, requester)
+	}
+
+	f.ActualSSOSettings = *settings
+	f.ActualRequester = requester
+
+	return f.ExpectedValidationResult, f.ExpectedError
+}
+
+func (f *FakeService) ListVersions(ctx context.Context, provider string) ([]*models.SSOSettingsVersion, error) {
+	if f.ListVersionsFn != nil {
+		return f.ListVersionsFn(ctx, provider)
+	}
+
+	f.ActualProvider = provider
+
+	return f.ExpectedVersions, f.ExpectedError
+}
+
+func (f *FakeService) Rollback(ctx context.Context, provider string, version int64, requester identity.Requester) error {
+	if f.RollbackFn != nil {
+		return f.RollbackFn(ctx, provider, version, requester)
+	}
+
+	f.ActualProvider = provider
+	f.ActualVersion = version
+	f.ActualRequester = requester
+
+	return f.ExpectedError
+}
@@ -126,6 +126,36 @@ func (_m *MockService) List(ctx context.Context) ([]*models.SSOSettings, error)
 	return r0, r1
 }
 
+// ListVersions provides a mock function with given fields: ctx, provider
+func (_m *MockService) ListVersions(ctx context.Context, provider string) ([]*models.SSOSettingsVersion, error) {
+	ret := _m.Called(ctx, provider)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListVersions")
+	}
+
+	var r0 []*models.SSOSettingsVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*models.SSOSettingsVersion, error)); ok {
+		return rf(ctx, provider)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*models.SSOSettingsVersion); ok {
+		r0 = rf(ctx, provider)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.SSOSettingsVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, provider)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListWithRedactedSecrets provides a mock function with given fields: ctx
 func (_m *MockService) ListWithRedactedSecrets(ctx context.Context) ([]*models.SSOSettings, error) {
 	ret := _m.Called(ctx)
@@ -184,6 +214,24 @@ func (_m *MockService) Reload(ctx context.Context, provider string) {
 	_m.Called(ctx, provider)
 }
 
+// Rollback provides a mock function with given fields: ctx, provider, version, requester
+func (_m *MockService) Rollback(ctx context.Context, provider string, version int64, requester identity.Requester) error {
+	ret := _m.Called(ctx, provider, version, requester)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Rollback")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, identity.Requester) error); ok {
+		r0 = rf(ctx, provider, version, requester)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Upsert provides a mock function with given fields: ctx, settings, requester
 func (_m *MockService) Upsert(ctx context.Context, settings *models.SSOSettings, requester identity.Requester) error {
 	ret := _m.Called(ctx, settings, requester)
@@ -202,6 +250,36 @@ func (_m *MockService) Upsert(ctx context.Context, settings *models.SSOSettings,
 	return r0
 }
 
+// Validate provides a mock function with given fields: ctx, settings, requester
+func (_m *MockService) Validate(ctx context.Context, settings *models.SSOSettings, requester identity.Requester) (*models.ValidationResult, error) {
+	ret := _m.Called(ctx, settings, requester)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Validate")
+	}
+
+	var r0 *models.ValidationResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SSOSettings, identity.Requester) (*models.ValidationResult, error)); ok {
+		return rf(ctx, settings, requester)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SSOSettings, identity.Requester) *models.ValidationResult); ok {
+		r0 = rf(ctx, settings, requester)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ValidationResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.SSOSettings, identity.Requester) error); ok {
+		r1 = rf(ctx, settings, requester)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewMockService creates a new instance of MockService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockService(t interface {
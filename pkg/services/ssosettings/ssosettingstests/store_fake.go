@@ -15,6 +15,7 @@ type FakeStore struct {
 	ExpectedError       error
 
 	ActualSSOSettings models.SSOSettings
+	ActualVersions    []*models.SSOSettingsVersion
 
 	GetFn    func(ctx context.Context, provider string) (*models.SSOSettings, error)
 	UpsertFn func(ctx context.Context, settings *models.SSOSettings) error
@@ -52,3 +53,34 @@ func (f *FakeStore) Patch(ctx context.Context, provider string, data map[string]
 func (f *FakeStore) Delete(ctx context.Context, provider string) error {
 	return f.ExpectedError
 }
+
+func (f *FakeStore) AddVersion(ctx context.Context, version *models.SSOSettingsVersion, limit int) error {
+	version.Version = 1
+	if n := len(f.ActualVersions); n > 0 {
+		version.Version = f.ActualVersions[n-1].Version + 1
+	}
+	f.ActualVersions = append(f.ActualVersions, version)
+	if limit > 0 && len(f.ActualVersions) > limit {
+		f.ActualVersions = f.ActualVersions[len(f.ActualVersions)-limit:]
+	}
+	return f.ExpectedError
+}
+
+func (f *FakeStore) ListVersions(ctx context.Context, provider string) ([]*models.SSOSettingsVersion, error) {
+	result := make([]*models.SSOSettingsVersion, 0, len(f.ActualVersions))
+	for i := len(f.ActualVersions) - 1; i >= 0; i-- {
+		if f.ActualVersions[i].Provider == provider {
+			result = append(result, f.ActualVersions[i])
+		}
+	}
+	return result, f.ExpectedError
+}
+
+func (f *FakeStore) GetVersion(ctx context.Context, provider string, version int64) (*models.SSOSettingsVersion, error) {
+	for _, v := range f.ActualVersions {
+		if v.Provider == provider && v.Version == version {
+			return v, f.ExpectedError
+		}
+	}
+	return nil, ssosettings.ErrVersionNotFound.Errorf("version %d of provider %s not found", version, provider)
+}
@@ -14,6 +14,24 @@ type MockStore struct {
 	mock.Mock
 }
 
+// AddVersion provides a mock function with given fields: ctx, version, limit
+func (_m *MockStore) AddVersion(ctx context.Context, version *models.SSOSettingsVersion, limit int) error {
+	ret := _m.Called(ctx, version, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddVersion")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SSOSettingsVersion, int) error); ok {
+		r0 = rf(ctx, version, limit)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Delete provides a mock function with given fields: ctx, provider
 func (_m *MockStore) Delete(ctx context.Context, provider string) error {
 	ret := _m.Called(ctx, provider)
@@ -62,6 +80,36 @@ func (_m *MockStore) Get(ctx context.Context, provider string) (*models.SSOSetti
 	return r0, r1
 }
 
+// GetVersion provides a mock function with given fields: ctx, provider, version
+func (_m *MockStore) GetVersion(ctx context.Context, provider string, version int64) (*models.SSOSettingsVersion, error) {
+	ret := _m.Called(ctx, provider, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetVersion")
+	}
+
+	var r0 *models.SSOSettingsVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) (*models.SSOSettingsVersion, error)); ok {
+		return rf(ctx, provider, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) *models.SSOSettingsVersion); ok {
+		r0 = rf(ctx, provider, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.SSOSettingsVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64) error); ok {
+		r1 = rf(ctx, provider, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // List provides a mock function with given fields: ctx
 func (_m *MockStore) List(ctx context.Context) ([]*models.SSOSettings, error) {
 	ret := _m.Called(ctx)
@@ -92,6 +140,36 @@ func (_m *MockStore) List(ctx context.Context) ([]*models.SSOSettings, error) {
 	return r0, r1
 }
 
+// ListVersions provides a mock function with given fields: ctx, provider
+func (_m *MockStore) ListVersions(ctx context.Context, provider string) ([]*models.SSOSettingsVersion, error) {
+	ret := _m.Called(ctx, provider)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListVersions")
+	}
+
+	var r0 []*models.SSOSettingsVersion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*models.SSOSettingsVersion, error)); ok {
+		return rf(ctx, provider)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*models.SSOSettingsVersion); ok {
+		r0 = rf(ctx, provider)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.SSOSettingsVersion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, provider)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Upsert provides a mock function with given fields: ctx, settings
 func (_m *MockStore) Upsert(ctx context.Context, settings *models.SSOSettings) error {
 	ret := _m.Called(ctx, settings)
@@ -9,27 +9,49 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	ngstore "github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/star"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 )
 
+// alertRuleGetter gets the starred alert rules
+type alertRuleGetter interface {
+	GetAlertRuleByUID(ctx context.Context, query *ngmodels.GetAlertRuleByUIDQuery) (*ngmodels.AlertRule, error)
+}
+
 type API struct {
-	starService      star.Service
-	dashboardService dashboards.DashboardService
-	logger           log.Logger
+	cfg               *setting.Cfg
+	starService       star.Service
+	dashboardService  dashboards.DashboardService
+	dataSourceService datasources.DataSourceService
+	alertRules        alertRuleGetter
+	accessControl     ac.AccessControl
+	logger            log.Logger
 }
 
 func ProvideApi(
+	cfg *setting.Cfg,
 	starService star.Service,
 	dashboardService dashboards.DashboardService,
+	dataSourceService datasources.DataSourceService,
+	ruleStore *ngstore.DBstore,
+	accessControl ac.AccessControl,
 ) *API {
 	starLogger := log.New("stars.api")
 	api := &API{
-		starService:      starService,
-		dashboardService: dashboardService,
-		logger:           starLogger,
+		cfg:               cfg,
+		starService:       starService,
+		dashboardService:  dashboardService,
+		dataSourceService: dataSourceService,
+		alertRules:        ruleStore,
+		accessControl:     accessControl,
+		logger:            starLogger,
 	}
 	return api
 }
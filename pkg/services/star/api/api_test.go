@@ -1,21 +1,30 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/services/star/startest"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 )
 
 func TestStarDashboardID(t *testing.T) {
-	api := ProvideApi(startest.NewStarServiceFake(), dashboards.NewFakeDashboardService(t))
+	api := ProvideApi(setting.NewCfg(), startest.NewStarServiceFake(), dashboards.NewFakeDashboardService(t), nil, nil, nil)
 
 	testCases := []struct {
 		name           string
@@ -87,7 +96,7 @@ func TestStarDashboardID(t *testing.T) {
 func TestStarDashboardUID(t *testing.T) {
 	svc := dashboards.NewFakeDashboardService(t)
 	svc.On("GetDashboard", mock.Anything, mock.Anything).Return(&dashboards.Dashboard{UID: "test", OrgID: 1}, nil)
-	api := ProvideApi(startest.NewStarServiceFake(), svc)
+	api := ProvideApi(setting.NewCfg(), startest.NewStarServiceFake(), svc, nil, nil, nil)
 
 	testCases := []struct {
 		name           string
@@ -155,3 +164,58 @@ func TestStarDashboardUID(t *testing.T) {
 		})
 	}
 }
+
+func TestStarResource(t *testing.T) {
+	dsService := &fakes.FakeDataSourceService{DataSources: []*datasources.DataSource{{UID: "ds", OrgID: 1, Name: "Prometheus", Type: "prometheus"}}}
+	signedInUser := &user.SignedInUser{UserID: 1, OrgID: 1}
+
+	testCases := []struct {
+		name           string
+		uid            string
+		canRead        bool
+		expectedStatus int
+	}{
+		{name: "Star data source", uid: "ds", canRead: true, expectedStatus: 200},
+		{name: "Star unknown data source", uid: "unknown", canRead: true, expectedStatus: 404},
+		{name: "Star data source without access", uid: "ds", canRead: false, expectedStatus: 404},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			api := ProvideApi(setting.NewCfg(), startest.NewStarServiceFake(), dashboards.NewFakeDashboardService(t), dsService, nil, actest.FakeAccessControl{ExpectedEvaluate: tc.canRead})
+			req := web.SetURLParams(&http.Request{}, map[string]string{":uid": tc.uid})
+			c := &contextmodel.ReqContext{SignedInUser: signedInUser, Context: &web.Context{Req: req}}
+			resp := api.StarResource(star.KindDatasource)(c)
+			assert.Equal(t, tc.expectedStatus, resp.Status())
+		})
+	}
+}
+
+func TestGetStarredResources(t *testing.T) {
+	starService := startest.NewStarServiceFake()
+	starService.ExpectedUserStars = &star.GetUserStarsResult{UserStars: map[string]bool{"dash": true}}
+	starService.ExpectedUserResourceStars = &star.GetUserResourceStarsResult{Stars: []*star.ResourceStar{
+		{Kind: star.KindDatasource, ResourceUID: "deleted"},
+		{Kind: star.KindFolder, ResourceUID: "folder"},
+		{Kind: star.KindDatasource, ResourceUID: "ds"},
+	}}
+
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("SearchDashboards", mock.Anything, mock.Anything).Return(model.HitList{
+		{UID: "dash", Title: "Dashboard", URL: "/d/dash", Type: model.DashHitDB, FolderUID: "folder"},
+		{UID: "folder", Title: "Folder", URL: "/dashboards/f/folder", Type: model.DashHitFolder},
+	}, nil)
+	dsService := &fakes.FakeDataSourceService{DataSources: []*datasources.DataSource{{UID: "ds", OrgID: 1, Name: "Prometheus", Type: "prometheus"}}}
+
+	api := ProvideApi(setting.NewCfg(), starService, dashboardService, dsService, nil, actest.FakeAccessControl{ExpectedEvaluate: true})
+	c := &contextmodel.ReqContext{SignedInUser: &user.SignedInUser{UserID: 1, OrgID: 1}, Context: &web.Context{Req: &http.Request{}}}
+	resp := api.GetStarredResources(c)
+	require.Equal(t, 200, resp.Status())
+
+	var result StarredResources
+	require.NoError(t, json.Unmarshal(resp.(*response.NormalResponse).Body(), &result))
+	assert.Equal(t, []StarredResource{{UID: "dash", Title: "Dashboard", URL: "/d/dash", FolderUID: "folder"}}, result.Dashboards)
+	assert.Equal(t, []StarredResource{{UID: "folder", Title: "Folder", URL: "/dashboards/f/folder"}}, result.Folders)
+	assert.Equal(t, []StarredResource{{UID: "ds", Title: "Prometheus", URL: "/connections/datasources/edit/ds", Type: "prometheus"}}, result.Datasources)
+	assert.Empty(t, result.AlertRules)
+}
@@ -0,0 +1,292 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/star"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// maxStarredDashboards bounds the starred dashboards and folders returned by GetStarredResources
+const maxStarredDashboards = 1000
+
+// StarredResources are the resources starred by the user which they can still access
+type StarredResources struct {
+	Dashboards []StarredResource `json:"dashboards"`
+	// Folders, Datasources and AlertRules are ordered by most recently starred first
+	Folders     []StarredResource `json:"folders"`
+	Datasources []StarredResource `json:"datasources"`
+	AlertRules  []StarredResource `json:"alertRules"`
+}
+
+type StarredResource struct {
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	// Type is the plugin type of a data source
+	Type string `json:"type,omitempty"`
+	// FolderUID is the folder of a dashboard or of an alert rule
+	FolderUID string `json:"folderUid,omitempty"`
+}
+
+// swagger:route GET /user/stars/resources signed_in_user getStarredResources
+//
+// Get the starred resources.
+//
+// Returns the dashboards, folders, data sources and alert rules starred by the actual user in the current organization,
+// with their titles and URLs. The resources which were deleted or can't be accessed by the user anymore are omitted.
+//
+// Responses:
+// 200: getStarredResourcesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (api *API) GetStarredResources(c *contextmodel.ReqContext) response.Response {
+	ctx := c.Req.Context()
+	result := StarredResources{
+		Dashboards:  []StarredResource{},
+		Folders:     []StarredResource{},
+		Datasources: []StarredResource{},
+		AlertRules:  []StarredResource{},
+	}
+
+	dashboardStars, err := api.starService.GetByUser(ctx, &star.GetUserStarsQuery{UserID: c.UserID})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get user stars", err)
+	}
+	resourceStars, err := api.starService.GetResourcesByUser(ctx, &star.GetUserResourceStarsQuery{UserID: c.UserID, OrgID: c.GetOrgID()})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get user stars", err)
+	}
+
+	folderUIDs := resourceStars.UIDs(star.KindFolder)
+	uids := make([]string, 0, len(dashboardStars.UserStars)+len(folderUIDs))
+	for uid := range dashboardStars.UserStars {
+		uids = append(uids, uid)
+	}
+	for uid := range folderUIDs {
+		uids = append(uids, uid)
+	}
+	hits, err := api.searchDashboards(ctx, c.SignedInUser, uids, "")
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get starred dashboards", err)
+	}
+
+	folders := map[string]StarredResource{}
+	for _, hit := range hits {
+		resource := StarredResource{UID: hit.UID, Title: hit.Title, URL: hit.URL, FolderUID: hit.FolderUID}
+		switch {
+		case hit.Type == model.DashHitFolder && folderUIDs[hit.UID]:
+			resource.FolderUID = ""
+			folders[hit.UID] = resource
+		case hit.Type == model.DashHitDB && dashboardStars.UserStars[hit.UID]:
+			result.Dashboards = append(result.Dashboards, resource)
+		}
+	}
+
+	for _, s := range resourceStars.Stars {
+		var resource *StarredResource
+		switch s.Kind {
+		case star.KindFolder:
+			if folder, ok := folders[s.ResourceUID]; ok {
+				result.Folders = append(result.Folders, folder)
+			}
+			continue
+		case star.KindDatasource:
+			resource, err = api.getDatasource(ctx, c.SignedInUser, s.ResourceUID)
+		case star.KindAlertRule:
+			resource, err = api.getAlertRule(ctx, c.SignedInUser, s.ResourceUID)
+		default:
+			continue
+		}
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to get starred resources", err)
+		}
+		if resource == nil {
+			continue
+		}
+		if s.Kind == star.KindDatasource {
+			result.Datasources = append(result.Datasources, *resource)
+		} else {
+			result.AlertRules = append(result.AlertRules, *resource)
+		}
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route POST /user/stars/{kind}/uid/{uid} signed_in_user starResource
+//
+// Star a folder, a data source or an alert rule.
+//
+// Stars the given resource for the actual user. The kind is one of folder, datasource and alertrule.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (api *API) StarResource(kind string) func(c *contextmodel.ReqContext) response.Response {
+	return func(c *contextmodel.ReqContext) response.Response {
+		uid := web.Params(c.Req)[":uid"]
+		if uid == "" {
+			return response.Error(http.StatusBadRequest, "Invalid resource UID", nil)
+		}
+
+		userID, err := identity.UserIdentifier(c.GetID())
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "Only users and service accounts can star resources", nil)
+		}
+
+		resource, err := api.getResource(c.Req.Context(), c.SignedInUser, kind, uid)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to star resource", err)
+		}
+		if resource == nil {
+			return response.Error(http.StatusNotFound, "Resource not found", nil)
+		}
+
+		cmd := star.StarResourceCommand{UserID: userID, OrgID: c.GetOrgID(), Kind: kind, ResourceUID: uid, Updated: time.Now()}
+		if err := api.starService.AddResource(c.Req.Context(), &cmd); err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to star resource", err)
+		}
+
+		return response.Success("Resource starred!")
+	}
+}
+
+// swagger:route DELETE /user/stars/{kind}/uid/{uid} signed_in_user unstarResource
+//
+// Unstar a folder, a data source or an alert rule.
+//
+// Deletes the starring of the given resource for the actual user. The kind is one of folder, datasource and alertrule.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (api *API) UnstarResource(kind string) func(c *contextmodel.ReqContext) response.Response {
+	return func(c *contextmodel.ReqContext) response.Response {
+		uid := web.Params(c.Req)[":uid"]
+		if uid == "" {
+			return response.Error(http.StatusBadRequest, "Invalid resource UID", nil)
+		}
+
+		userID, err := identity.UserIdentifier(c.GetID())
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "Only users and service accounts can star resources", nil)
+		}
+
+		cmd := star.UnstarResourceCommand{UserID: userID, OrgID: c.GetOrgID(), Kind: kind, ResourceUID: uid}
+		if err := api.starService.DeleteResource(c.Req.Context(), &cmd); err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to unstar resource", err)
+		}
+
+		return response.Success("Resource unstarred")
+	}
+}
+
+// getResource returns the resource when it exists and can be read by the user, nil otherwise
+func (api *API) getResource(ctx context.Context, user identity.Requester, kind, uid string) (*StarredResource, error) {
+	switch kind {
+	case star.KindFolder:
+		hits, err := api.searchDashboards(ctx, user, []string{uid}, model.DashHitFolder)
+		if err != nil || len(hits) == 0 {
+			return nil, err
+		}
+		return &StarredResource{UID: hits[0].UID, Title: hits[0].Title, URL: hits[0].URL}, nil
+	case star.KindDatasource:
+		return api.getDatasource(ctx, user, uid)
+	case star.KindAlertRule:
+		return api.getAlertRule(ctx, user, uid)
+	}
+	return nil, nil
+}
+
+func (api *API) searchDashboards(ctx context.Context, user identity.Requester, uids []string, hitType model.HitType) (model.HitList, error) {
+	if len(uids) == 0 {
+		return model.HitList{}, nil
+	}
+	return api.dashboardService.SearchDashboards(ctx, &dashboards.FindPersistedDashboardsQuery{
+		SignedInUser:  user,
+		DashboardUIDs: uids,
+		Type:          string(hitType),
+		Limit:         maxStarredDashboards,
+		Permission:    dashboardaccess.PERMISSION_VIEW,
+	})
+}
+
+func (api *API) getDatasource(ctx context.Context, user identity.Requester, uid string) (*StarredResource, error) {
+	ds, err := api.dataSourceService.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: uid, OrgID: user.GetOrgID()})
+	if errors.Is(err, datasources.ErrDataSourceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	evaluator := ac.EvalPermission(datasources.ActionRead, datasources.ScopeProvider.GetResourceScopeUID(ds.UID))
+	if ok, err := api.accessControl.Evaluate(ctx, user, evaluator); err != nil || !ok {
+		return nil, err
+	}
+	return &StarredResource{
+		UID:   ds.UID,
+		Title: ds.Name,
+		URL:   api.cfg.AppSubURL + "/connections/datasources/edit/" + ds.UID,
+		Type:  ds.Type,
+	}, nil
+}
+
+func (api *API) getAlertRule(ctx context.Context, user identity.Requester, uid string) (*StarredResource, error) {
+	rule, err := api.alertRules.GetAlertRuleByUID(ctx, &ngmodels.GetAlertRuleByUIDQuery{UID: uid, OrgID: user.GetOrgID()})
+	if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	evaluator := ac.EvalPermission(ac.ActionAlertingRuleRead, dashboards.ScopeFoldersProvider.GetResourceScopeUID(rule.NamespaceUID))
+	if ok, err := api.accessControl.Evaluate(ctx, user, evaluator); err != nil || !ok {
+		return nil, err
+	}
+	return &StarredResource{
+		UID:       rule.UID,
+		Title:     rule.Title,
+		URL:       api.cfg.AppSubURL + "/alerting/grafana/" + rule.UID + "/view",
+		FolderUID: rule.NamespaceUID,
+	}, nil
+}
+
+// swagger:parameters starResource unstarResource
+type StarResourceParams struct {
+	// in:path
+	// required:true
+	// enum: folder,datasource,alertrule
+	Kind string `json:"kind"`
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:response getStarredResourcesResponse
+type GetStarredResourcesResponse struct {
+	// in:body
+	Body StarredResources `json:"body"`
+}
@@ -70,3 +70,91 @@ type IsStarredByUserQuery struct {
 type GetUserStarsResult struct {
 	UserStars map[string]bool
 }
+
+// ----------------------
+// RESOURCES
+
+// The kinds of the resources which can be starred besides dashboards, the dashboards are starred with
+// StarDashboardCommand
+const (
+	KindFolder     = "folder"
+	KindDatasource = "datasource"
+	KindAlertRule  = "alertrule"
+)
+
+var ResourceKinds = []string{KindFolder, KindDatasource, KindAlertRule}
+
+func IsResourceKind(kind string) bool {
+	for _, k := range ResourceKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// ResourceStar is a folder, a data source or an alert rule starred by a user
+type ResourceStar struct {
+	ID          int64     `xorm:"pk autoincr 'id'" db:"id"`
+	OrgID       int64     `xorm:"org_id" db:"org_id"`
+	UserID      int64     `xorm:"user_id" db:"user_id"`
+	Kind        string    `xorm:"kind" db:"kind"`
+	ResourceUID string    `xorm:"resource_uid" db:"resource_uid"`
+	Updated     time.Time `xorm:"updated" db:"updated"`
+}
+
+func (ResourceStar) TableName() string {
+	return "star_resource"
+}
+
+type StarResourceCommand struct {
+	UserID      int64
+	OrgID       int64
+	Kind        string
+	ResourceUID string
+	Updated     time.Time
+}
+
+func (cmd *StarResourceCommand) Validate() error {
+	if cmd.UserID == 0 || cmd.OrgID == 0 || cmd.ResourceUID == "" || !IsResourceKind(cmd.Kind) {
+		return ErrCommandValidationFailed
+	}
+	return nil
+}
+
+type UnstarResourceCommand struct {
+	UserID      int64
+	OrgID       int64
+	Kind        string
+	ResourceUID string
+}
+
+func (cmd *UnstarResourceCommand) Validate() error {
+	if cmd.UserID == 0 || cmd.OrgID == 0 || cmd.ResourceUID == "" || !IsResourceKind(cmd.Kind) {
+		return ErrCommandValidationFailed
+	}
+	return nil
+}
+
+type GetUserResourceStarsQuery struct {
+	UserID int64
+	OrgID  int64
+	// Kind only returns the stars of the resources of the kind when set
+	Kind string
+}
+
+type GetUserResourceStarsResult struct {
+	// Stars are the starred resources, most recently starred first
+	Stars []*ResourceStar
+}
+
+// UIDs returns the UIDs of the starred resources of the kind
+func (r *GetUserResourceStarsResult) UIDs(kind string) map[string]bool {
+	uids := make(map[string]bool)
+	for _, s := range r.Stars {
+		if s.Kind == kind {
+			uids[s.ResourceUID] = true
+		}
+	}
+	return uids
+}
@@ -10,4 +10,9 @@ type Service interface {
 	DeleteByUser(context.Context, int64) error
 	IsStarredByUser(context.Context, *IsStarredByUserQuery) (bool, error)
 	GetByUser(context.Context, *GetUserStarsQuery) (*GetUserStarsResult, error)
+	// AddResource stars a folder, a data source or an alert rule
+	AddResource(context.Context, *StarResourceCommand) error
+	DeleteResource(context.Context, *UnstarResourceCommand) error
+	// GetResourcesByUser returns the folders, data sources and alert rules starred by the user in the org
+	GetResourcesByUser(context.Context, *GetUserResourceStarsQuery) (*GetUserResourceStarsResult, error)
 }
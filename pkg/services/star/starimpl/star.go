@@ -3,6 +3,8 @@ package starimpl
 import (
 	"context"
 
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrations"
@@ -14,7 +16,7 @@ type Service struct {
 	logger log.Logger
 }
 
-func ProvideService(db db.DB) star.Service {
+func ProvideService(db db.DB, bus bus.Bus) star.Service {
 	starLogger := log.New("stars")
 	// fill out dashboard_uid, org_id and updated columns for stars
 	// need to run this at startup in case any downgrade happened after
@@ -23,12 +25,18 @@ func ProvideService(db db.DB) star.Service {
 	if err != nil {
 		starLogger.Error("Failed to run star migrations", "err", err)
 	}
-	return &Service{
+	s := &Service{
 		store: &sqlStore{
 			db: db,
 		},
 		logger: starLogger,
 	}
+
+	// the stars of the deleted resources are deleted with them
+	bus.AddEventListener(s.handleFolderDeleted)
+	bus.AddEventListener(s.handleDataSourceDeleted)
+	bus.AddEventListener(s.handleAlertRuleDeleted)
+	return s
 }
 
 func (s *Service) Add(ctx context.Context, cmd *star.StarDashboardCommand) error {
@@ -56,3 +64,33 @@ func (s *Service) GetByUser(ctx context.Context, cmd *star.GetUserStarsQuery) (*
 func (s *Service) DeleteByUser(ctx context.Context, userID int64) error {
 	return s.store.DeleteByUser(ctx, userID)
 }
+
+func (s *Service) AddResource(ctx context.Context, cmd *star.StarResourceCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+	return s.store.InsertResource(ctx, cmd)
+}
+
+func (s *Service) DeleteResource(ctx context.Context, cmd *star.UnstarResourceCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+	return s.store.DeleteResource(ctx, cmd)
+}
+
+func (s *Service) GetResourcesByUser(ctx context.Context, query *star.GetUserResourceStarsQuery) (*star.GetUserResourceStarsResult, error) {
+	return s.store.ListResources(ctx, query)
+}
+
+func (s *Service) handleFolderDeleted(ctx context.Context, evt *events.FolderDeleted) error {
+	return s.store.DeleteByResource(ctx, evt.OrgID, star.KindFolder, evt.UID)
+}
+
+func (s *Service) handleDataSourceDeleted(ctx context.Context, evt *events.DataSourceDeleted) error {
+	return s.store.DeleteByResource(ctx, evt.OrgID, star.KindDatasource, evt.UID)
+}
+
+func (s *Service) handleAlertRuleDeleted(ctx context.Context, evt *events.AlertRuleDeleted) error {
+	return s.store.DeleteByResource(ctx, evt.OrgID, star.KindAlertRule, evt.UID)
+}
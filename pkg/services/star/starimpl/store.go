@@ -12,4 +12,9 @@ type store interface {
 	Delete(context.Context, *star.UnstarDashboardCommand) error
 	DeleteByUser(context.Context, int64) error
 	List(context.Context, *star.GetUserStarsQuery) (*star.GetUserStarsResult, error)
+	InsertResource(context.Context, *star.StarResourceCommand) error
+	DeleteResource(context.Context, *star.UnstarResourceCommand) error
+	// DeleteByResource deletes the stars of a deleted resource
+	DeleteByResource(ctx context.Context, orgID int64, kind, uid string) error
+	ListResources(context.Context, *star.GetUserResourceStarsQuery) (*star.GetUserResourceStarsResult, error)
 }
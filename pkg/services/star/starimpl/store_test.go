@@ -145,6 +145,63 @@ func testIntegrationUserStarsDataAccess(t *testing.T, fn getStore) {
 			require.NoError(t, err)
 			require.Equal(t, 1, len(res.UserStars))
 		})
+
+		t.Run("Given starred resources", func(t *testing.T) {
+			for i, cmd := range []star.StarResourceCommand{
+				{UserID: 12, OrgID: 1, Kind: star.KindFolder, ResourceUID: "folder"},
+				{UserID: 12, OrgID: 1, Kind: star.KindDatasource, ResourceUID: "ds"},
+				{UserID: 12, OrgID: 1, Kind: star.KindAlertRule, ResourceUID: "rule"},
+				{UserID: 12, OrgID: 2, Kind: star.KindFolder, ResourceUID: "folder"},
+				{UserID: 11, OrgID: 1, Kind: star.KindDatasource, ResourceUID: "ds"},
+			} {
+				cmd.Updated = time.Now().Add(time.Duration(i) * time.Minute)
+				err := starStore.InsertResource(context.Background(), &cmd)
+				require.NoError(t, err)
+			}
+
+			t.Run("Insert should ignore a resource starred twice", func(t *testing.T) {
+				cmd := star.StarResourceCommand{UserID: 12, OrgID: 1, Kind: star.KindFolder, ResourceUID: "folder"}
+				err := starStore.InsertResource(context.Background(), &cmd)
+				require.NoError(t, err)
+			})
+
+			t.Run("ListResources should return the stars of the user in the org", func(t *testing.T) {
+				res, err := starStore.ListResources(context.Background(), &star.GetUserResourceStarsQuery{UserID: 12, OrgID: 1})
+				require.NoError(t, err)
+				require.Equal(t, 3, len(res.Stars))
+				require.Equal(t, "rule", res.Stars[0].ResourceUID)
+				require.Equal(t, map[string]bool{"ds": true}, res.UIDs(star.KindDatasource))
+
+				res, err = starStore.ListResources(context.Background(), &star.GetUserResourceStarsQuery{UserID: 12, OrgID: 1, Kind: star.KindFolder})
+				require.NoError(t, err)
+				require.Equal(t, 1, len(res.Stars))
+			})
+
+			t.Run("DeleteResource should remove the star", func(t *testing.T) {
+				cmd := star.UnstarResourceCommand{UserID: 12, OrgID: 1, Kind: star.KindAlertRule, ResourceUID: "rule"}
+				err := starStore.DeleteResource(context.Background(), &cmd)
+				require.NoError(t, err)
+				res, err := starStore.ListResources(context.Background(), &star.GetUserResourceStarsQuery{UserID: 12, OrgID: 1, Kind: star.KindAlertRule})
+				require.NoError(t, err)
+				require.Equal(t, 0, len(res.Stars))
+			})
+
+			t.Run("DeleteByResource should remove the stars of all the users", func(t *testing.T) {
+				err := starStore.DeleteByResource(context.Background(), 1, star.KindDatasource, "ds")
+				require.NoError(t, err)
+				res, err := starStore.ListResources(context.Background(), &star.GetUserResourceStarsQuery{UserID: 11, OrgID: 1})
+				require.NoError(t, err)
+				require.Equal(t, 0, len(res.Stars))
+			})
+
+			t.Run("DeleteByUser should remove the starred resources", func(t *testing.T) {
+				err := starStore.DeleteByUser(context.Background(), 12)
+				require.NoError(t, err)
+				res, err := starStore.ListResources(context.Background(), &star.GetUserResourceStarsQuery{UserID: 12, OrgID: 2})
+				require.NoError(t, err)
+				require.Equal(t, 0, len(res.Stars))
+			})
+		})
 	})
 }
 
@@ -80,7 +80,10 @@ func (s *sqlStore) Delete(ctx context.Context, cmd *star.UnstarDashboardCommand)
 func (s *sqlStore) DeleteByUser(ctx context.Context, userID int64) error {
 	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		var rawSQL = "DELETE FROM star WHERE user_id = ?"
-		_, err := sess.Exec(rawSQL, userID)
+		if _, err := sess.Exec(rawSQL, userID); err != nil {
+			return err
+		}
+		_, err := sess.Exec("DELETE FROM star_resource WHERE user_id = ?", userID)
 		return err
 	})
 }
@@ -97,3 +100,49 @@ func (s *sqlStore) List(ctx context.Context, query *star.GetUserStarsQuery) (*st
 	})
 	return &star.GetUserStarsResult{UserStars: userStars}, err
 }
+
+func (s *sqlStore) InsertResource(ctx context.Context, cmd *star.StarResourceCommand) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		entity := star.ResourceStar{
+			OrgID:       cmd.OrgID,
+			UserID:      cmd.UserID,
+			Kind:        cmd.Kind,
+			ResourceUID: cmd.ResourceUID,
+			Updated:     cmd.Updated,
+		}
+
+		_, err := sess.Insert(&entity)
+		if s.db.GetDialect().IsUniqueConstraintViolation(err) {
+			return nil
+		}
+		return err
+	})
+}
+
+func (s *sqlStore) DeleteResource(ctx context.Context, cmd *star.UnstarResourceCommand) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var rawSQL = "DELETE FROM star_resource WHERE org_id = ? AND user_id = ? AND kind = ? AND resource_uid = ?"
+		_, err := sess.Exec(rawSQL, cmd.OrgID, cmd.UserID, cmd.Kind, cmd.ResourceUID)
+		return err
+	})
+}
+
+func (s *sqlStore) DeleteByResource(ctx context.Context, orgID int64, kind, uid string) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var rawSQL = "DELETE FROM star_resource WHERE org_id = ? AND kind = ? AND resource_uid = ?"
+		_, err := sess.Exec(rawSQL, orgID, kind, uid)
+		return err
+	})
+}
+
+func (s *sqlStore) ListResources(ctx context.Context, query *star.GetUserResourceStarsQuery) (*star.GetUserResourceStarsResult, error) {
+	stars := make([]*star.ResourceStar, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Where("org_id = ? AND user_id = ?", query.OrgID, query.UserID)
+		if query.Kind != "" {
+			q = q.And("kind = ?", query.Kind)
+		}
+		return q.Desc("updated").Find(&stars)
+	})
+	return &star.GetUserResourceStarsResult{Stars: stars}, err
+}
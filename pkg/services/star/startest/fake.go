@@ -7,9 +7,10 @@ import (
 )
 
 type FakeStarService struct {
-	ExpectedStars     *star.Star
-	ExpectedError     error
-	ExpectedUserStars *star.GetUserStarsResult
+	ExpectedStars             *star.Star
+	ExpectedError             error
+	ExpectedUserStars         *star.GetUserStarsResult
+	ExpectedUserResourceStars *star.GetUserResourceStarsResult
 }
 
 func NewStarServiceFake() *FakeStarService {
@@ -35,3 +36,18 @@ func (f *FakeStarService) DeleteByUser(ctx context.Context, userID int64) error
 func (f *FakeStarService) GetByUser(ctx context.Context, query *star.GetUserStarsQuery) (*star.GetUserStarsResult, error) {
 	return f.ExpectedUserStars, f.ExpectedError
 }
+
+func (f *FakeStarService) AddResource(ctx context.Context, cmd *star.StarResourceCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeStarService) DeleteResource(ctx context.Context, cmd *star.UnstarResourceCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeStarService) GetResourcesByUser(ctx context.Context, query *star.GetUserResourceStarsQuery) (*star.GetUserResourceStarsResult, error) {
+	if f.ExpectedUserResourceStars == nil {
+		return &star.GetUserResourceStarsResult{}, f.ExpectedError
+	}
+	return f.ExpectedUserResourceStars, f.ExpectedError
+}
@@ -482,6 +482,7 @@ func readpanelInfo(iter *jsoniter.Iterator, lookup DatasourceLookup) PanelSummar
 	}
 
 	panel.Datasource = targets.GetDatasourceInfo()
+	panel.Queries = targets.queries
 
 	return panel
 }
@@ -114,6 +114,32 @@ func TestReadDashboard(t *testing.T) {
 	}
 }
 
+func TestReadDashboardQueries(t *testing.T) {
+	dash, err := ReadDashboard(strings.NewReader(`{
+		"panels": [
+			{
+				"id": 1,
+				"targets": [
+					{"refId": "A", "expr": "rate(http_requests_total[5m])"},
+					{"refId": "B", "rawSql": "SELECT 1"},
+					{"refId": "C", "query": {"not": "text"}},
+					{"refId": "D", "expr": ""}
+				]
+			},
+			{
+				"type": "row",
+				"panels": [
+					{"id": 2, "targets": [{"refId": "A", "target": "a.b.c"}]}
+				]
+			}
+		]
+	}`), dsLookupForTests())
+	require.NoError(t, err)
+	require.Len(t, dash.Panels, 2)
+	require.Equal(t, []string{"rate(http_requests_total[5m])", "SELECT 1"}, dash.Panels[0].Queries)
+	require.Equal(t, []string{"a.b.c"}, dash.Panels[1].Collapsed[0].Queries)
+}
+
 // assure consistent ordering of datasources to prevent random failures of `assert.JSONEq`
 func sortDatasources(dash *DashboardSummaryInfo) {
 	sort.Slice(dash.Datasource, func(i, j int) bool {
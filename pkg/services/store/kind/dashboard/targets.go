@@ -5,8 +5,9 @@ import (
 )
 
 type targetInfo struct {
-	lookup DatasourceLookup
-	uids   map[string]*DataSourceRef
+	lookup  DatasourceLookup
+	uids    map[string]*DataSourceRef
+	queries []string
 }
 
 func newTargetInfo(lookup DatasourceLookup) targetInfo {
@@ -75,6 +76,16 @@ func (s *targetInfo) addTarget(iter *jsoniter.Iterator) {
 		case "refId":
 			iter.Skip()
 
+		// raw query text of the common data sources (PromQL/LogQL, Elasticsearch/Influx/Tempo, SQL, Graphite)
+		case "expr", "query", "rawSql", "target":
+			if iter.WhatIsNext() != jsoniter.StringValue {
+				iter.Skip()
+				continue
+			}
+			if q := iter.ReadString(); q != "" {
+				s.queries = append(s.queries, q)
+			}
+
 		default:
 			v := iter.Read()
 			logf("[Panel.TARGET] %s=%v\n", l1Field, v)
@@ -6,6 +6,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 // Typed errors
@@ -82,10 +83,12 @@ type GetTeamsByUserQuery struct {
 }
 
 type SearchTeamsQuery struct {
-	Query        string
-	Name         string
-	Limit        int
-	Page         int
+	Query string
+	Name  string
+	Limit int
+	Page  int
+	// After selects the teams sorted after the cursor instead of the page
+	After        *pagination.Cursor
 	OrgID        int64 `xorm:"org_id"`
 	SortOpts     []model.SortOption
 	TeamIds      []int64
@@ -126,6 +129,8 @@ type SearchTeamQueryResult struct {
 	Teams      []*TeamDTO `json:"teams"`
 	Page       int        `json:"page"`
 	PerPage    int        `json:"perPage"`
+	// Next is the cursor of the next page, nil on the last page
+	Next *pagination.Cursor `json:"-"`
 }
 
 // TeamMember model
@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/team"
 	"github.com/grafana/grafana/pkg/services/team/sortopts"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/pagination"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -159,10 +160,11 @@ func (tapi *TeamAPI) deleteTeamByID(c *contextmodel.ReqContext) response.Respons
 // 403: forbiddenError
 // 500: internalServerError
 func (tapi *TeamAPI) searchTeams(c *contextmodel.ReqContext) response.Response {
-	perPage := c.QueryInt("perpage")
-	if perPage <= 0 {
-		perPage = 1000
+	paging, err := pagination.FromQuery(c.Req.URL.Query(), pagination.MaxLimit, pagination.MaxLimit)
+	if err != nil {
+		return response.Err(err)
 	}
+	perPage := paging.Limit
 	page := c.QueryInt("page")
 	if page < 1 {
 		page = 1
@@ -189,6 +191,7 @@ func (tapi *TeamAPI) searchTeams(c *contextmodel.ReqContext) response.Response {
 		TeamIds:      queryTeamIDs,
 		Page:         page,
 		Limit:        perPage,
+		After:        paging.After,
 		SignedInUser: c.SignedInUser,
 		HiddenUsers:  tapi.cfg.HiddenUsers,
 		SortOpts:     sortOpts,
@@ -196,7 +199,7 @@ func (tapi *TeamAPI) searchTeams(c *contextmodel.ReqContext) response.Response {
 
 	queryResult, err := tapi.teamService.SearchTeams(c.Req.Context(), &query)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to search Teams", err)
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to search Teams", err)
 	}
 
 	teamIDs := map[string]bool{}
@@ -215,7 +218,11 @@ func (tapi *TeamAPI) searchTeams(c *contextmodel.ReqContext) response.Response {
 	queryResult.Page = page
 	queryResult.PerPage = perPage
 
-	return response.JSON(http.StatusOK, queryResult)
+	rsp := response.JSON(http.StatusOK, queryResult)
+	if queryResult.Next != nil {
+		rsp.SetHeader("Link", pagination.LinkHeader(c.Req.URL, queryResult.Next, perPage))
+	}
+	return rsp
 }
 
 // swagger:route GET /teams/{team_id} teams getTeamByID
@@ -11,9 +11,12 @@ import (
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/db"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/search/model"
 	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/sortopts"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 type store interface {
@@ -224,26 +227,47 @@ func (ss *xormStore) Search(ctx context.Context, query *team.SearchTeamsQuery) (
 		sql.WriteString(` and` + acFilter.Where)
 		params = append(params, acFilter.Args...)
 
-		if len(query.SortOpts) > 0 {
+		keys, keysetSort := searchSortKeys(query.SortOpts)
+		if query.After != nil {
+			if !keysetSort {
+				return pagination.ErrUnsupportedSort.Errorf("teams can't be paged with a cursor in this sort order")
+			}
+			where, args, err := pagination.Where(keys, query.After)
+			if err != nil {
+				return err
+			}
+			sql.WriteString(` and ` + where)
+			params = append(params, args...)
+		}
+
+		if keysetSort {
+			sql.WriteString(` order by ` + pagination.OrderBy(keys))
+		} else {
 			orderBy := ` order by `
 			for i := range query.SortOpts {
 				for j := range query.SortOpts[i].Filter {
 					orderBy += query.SortOpts[i].Filter[j].OrderBy() + ","
 				}
 			}
-			sql.WriteString(orderBy[:len(orderBy)-1])
-		} else {
-			sql.WriteString(` order by team.name asc`)
+			sql.WriteString(orderBy + `team.id asc`)
 		}
 
 		if query.Limit != 0 {
-			offset := query.Limit * (query.Page - 1)
+			offset := 0
+			if query.After == nil {
+				offset = query.Limit * (query.Page - 1)
+			}
 			sql.WriteString(ss.db.GetDialect().LimitOffset(int64(query.Limit), int64(offset)))
 		}
 
 		if err := sess.SQL(sql.String(), params...).Find(&queryResult.Teams); err != nil {
 			return err
 		}
+		if keysetSort {
+			queryResult.Next = pagination.Next(len(queryResult.Teams), query.Limit, func() []any {
+				return searchSortValues(keys, queryResult.Teams[len(queryResult.Teams)-1])
+			})
+		}
 
 		t := team.Team{}
 		countSess := sess.Table("team")
@@ -272,6 +296,50 @@ func (ss *xormStore) Search(ctx context.Context, query *team.SearchTeamsQuery) (
 	return queryResult, nil
 }
 
+// searchSortKeys returns the keys the teams are sorted by, ending with their id so that the order is stable. The teams
+// can't be paged with a cursor when they're sorted by their member count, which isn't a column.
+func searchSortKeys(sortOpts []model.SortOption) ([]pagination.Key, bool) {
+	if len(sortOpts) == 0 {
+		return []pagination.Key{{Column: "team.name"}, {Column: "team.id"}}, true
+	}
+
+	keys := make([]pagination.Key, 0, len(sortOpts)+1)
+	for _, opt := range sortOpts {
+		for _, filter := range opt.Filter {
+			sorter, ok := filter.(sortopts.Sorter)
+			if !ok || !sorter.WithTableName {
+				return nil, false
+			}
+			column := "team." + sorter.Field
+			if sorter.LowerCase {
+				column = "LOWER(" + column + ")"
+			}
+			keys = append(keys, pagination.Key{Column: column, Desc: sorter.Descending})
+		}
+	}
+	return append(keys, pagination.Key{Column: "team.id"}), true
+}
+
+// searchSortValues returns the values of the sort keys of the team
+func searchSortValues(keys []pagination.Key, t *team.TeamDTO) []any {
+	values := make([]any, 0, len(keys))
+	for _, key := range keys {
+		switch key.Column {
+		case "team.name":
+			values = append(values, t.Name)
+		case "LOWER(team.name)":
+			values = append(values, strings.ToLower(t.Name))
+		case "team.email":
+			values = append(values, t.Email)
+		case "LOWER(team.email)":
+			values = append(values, strings.ToLower(t.Email))
+		case "team.id":
+			values = append(values, t.ID)
+		}
+	}
+	return values
+}
+
 func (ss *xormStore) GetByID(ctx context.Context, query *team.GetTeamByIDQuery) (*team.TeamDTO, error) {
 	var queryResult *team.TeamDTO
 
@@ -0,0 +1,247 @@
+package teamsync
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const maxGroupLength = 190
+
+func (s *Service) registerRoutes(router routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+	teamResolver := team.MiddlewareTeamUIDResolver(s.teamService, ":teamId")
+
+	router.Group("/api/teams/:teamId/sync-rules", func(rulesRoute routing.RouteRegister) {
+		rulesRoute.Get("/", teamResolver, authorize(ac.EvalPermission(ac.ActionTeamsPermissionsRead, ac.ScopeTeamsID)), routing.Wrap(s.GetTeamSyncRules))
+		rulesRoute.Post("/", teamResolver, authorize(ac.EvalPermission(ac.ActionTeamsPermissionsWrite, ac.ScopeTeamsID)), routing.Wrap(s.AddTeamSyncRule))
+		rulesRoute.Delete("/:ruleId", teamResolver, authorize(ac.EvalPermission(ac.ActionTeamsPermissionsWrite, ac.ScopeTeamsID)), routing.Wrap(s.DeleteTeamSyncRule))
+	}, middleware.ReqSignedIn)
+
+	router.Post("/api/admin/team-sync", middleware.ReqGrafanaAdmin, routing.Wrap(s.PostSyncTeams))
+}
+
+// swagger:route GET /teams/{team_id}/sync-rules teams getTeamSyncRules
+//
+// Lists the sync rules of a team.
+//
+// Responses:
+// 200: teamSyncRulesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) GetTeamSyncRules(c *contextmodel.ReqContext) response.Response {
+	teamID, errRsp := s.resolveTeam(c)
+	if errRsp != nil {
+		return errRsp
+	}
+
+	rules, err := s.store.List(c.Req.Context(), c.SignedInUser.GetOrgID(), teamID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get team sync rules", err)
+	}
+	return response.JSON(http.StatusOK, rules)
+}
+
+// swagger:route POST /teams/{team_id}/sync-rules teams addTeamSyncRule
+//
+// Maps the members of a group of an identity provider to a team.
+//
+// The members of the group are added to the team by the next synchronization, or when they log in.
+//
+// Responses:
+// 200: teamSyncRuleResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) AddTeamSyncRule(c *contextmodel.ReqContext) response.Response {
+	teamID, errRsp := s.resolveTeam(c)
+	if errRsp != nil {
+		return errRsp
+	}
+
+	cmd := AddTeamSyncRuleCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.Group = strings.TrimSpace(cmd.Group)
+	if cmd.Group == "" || len(cmd.Group) > maxGroupLength {
+		return response.Error(http.StatusBadRequest, "group must be set and at most 190 characters long", nil)
+	}
+	if _, ok := s.provider(cmd.Provider); !ok {
+		return response.Error(http.StatusBadRequest, ErrUnknownProvider.Error(), nil)
+	}
+	if cmd.Provider == setting.TeamSyncGitHubProvider {
+		if _, _, err := parseGitHubTeam(cmd.Group); err != nil {
+			return response.Error(http.StatusBadRequest, err.Error(), nil)
+		}
+	}
+
+	rule := &Rule{
+		OrgID:    c.SignedInUser.GetOrgID(),
+		TeamID:   teamID,
+		Provider: cmd.Provider,
+		Group:    cmd.Group,
+		Created:  time.Now(),
+	}
+	if err := s.store.Insert(c.Req.Context(), rule); err != nil {
+		if errors.Is(err, ErrRuleExists) {
+			return response.Error(http.StatusConflict, ErrRuleExists.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to add team sync rule", err)
+	}
+	return response.JSON(http.StatusOK, rule)
+}
+
+// swagger:route DELETE /teams/{team_id}/sync-rules/{rule_id} teams deleteTeamSyncRule
+//
+// Deletes a sync rule of a team.
+//
+// The external members of the group are removed by the next synchronization, unless another rule maps them to the team.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) DeleteTeamSyncRule(c *contextmodel.ReqContext) response.Response {
+	teamID, errRsp := s.resolveTeam(c)
+	if errRsp != nil {
+		return errRsp
+	}
+	ruleID, err := strconv.ParseInt(web.Params(c.Req)[":ruleId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "ruleId is invalid", err)
+	}
+
+	if err := s.store.Delete(c.Req.Context(), c.SignedInUser.GetOrgID(), teamID, ruleID); err != nil {
+		if errors.Is(err, ErrRuleNotFound) {
+			return response.Error(http.StatusNotFound, ErrRuleNotFound.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to delete team sync rule", err)
+	}
+	return response.Success("Team sync rule deleted")
+}
+
+// swagger:route POST /admin/team-sync admin postSyncTeams
+//
+// Synchronizes the members of all the teams with sync rules.
+//
+// Only works with Basic Authentication (username and password). See introduction for an explanation.
+//
+// Responses:
+// 200: teamSyncReportResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) PostSyncTeams(c *contextmodel.ReqContext) response.Response {
+	if !s.cfg.Enabled {
+		return response.Error(http.StatusBadRequest, "Team sync is not enabled", nil)
+	}
+
+	report, err := s.SyncAll(c.Req.Context())
+	if err != nil {
+		if errors.Is(err, ErrSyncInProgress) {
+			return response.Error(http.StatusConflict, ErrSyncInProgress.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to synchronize teams", err)
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// resolveTeam returns the ID of the team of the request, the team resolver has replaced its UID by its ID
+func (s *Service) resolveTeam(c *contextmodel.ReqContext) (int64, response.Response) {
+	teamID, err := strconv.ParseInt(web.Params(c.Req)[":teamId"], 10, 64)
+	if err != nil {
+		return 0, response.Error(http.StatusBadRequest, "teamId is invalid", err)
+	}
+
+	orgID := c.SignedInUser.GetOrgID()
+	if _, err := s.teamService.GetTeamByID(c.Req.Context(), &team.GetTeamByIDQuery{
+		OrgID:        orgID,
+		ID:           teamID,
+		SignedInUser: teamReader(orgID),
+	}); err != nil {
+		if errors.Is(err, team.ErrTeamNotFound) {
+			return 0, response.Error(http.StatusNotFound, team.ErrTeamNotFound.Error(), nil)
+		}
+		return 0, response.Error(http.StatusInternalServerError, "Failed to get team", err)
+	}
+	return teamID, nil
+}
+
+func teamReader(orgID int64) *user.SignedInUser {
+	return &user.SignedInUser{
+		Login:       "team-sync",
+		OrgID:       orgID,
+		Permissions: map[int64]map[string][]string{orgID: {ac.ActionTeamsRead: {ac.ScopeTeamsAll}}},
+	}
+}
+
+// swagger:model
+type AddTeamSyncRuleCommand struct {
+	// The provider of the group: oidc or github
+	Provider string `json:"provider"`
+	// The group claimed by the OAuth provider, or the GitHub team in the form @org/team
+	Group string `json:"group"`
+}
+
+// swagger:parameters getTeamSyncRules addTeamSyncRule deleteTeamSyncRule
+type TeamSyncTeamParam struct {
+	// in:path
+	// required:true
+	TeamID string `json:"team_id"`
+}
+
+// swagger:parameters addTeamSyncRule
+type AddTeamSyncRuleParams struct {
+	// in:body
+	// required:true
+	Body AddTeamSyncRuleCommand `json:"body"`
+}
+
+// swagger:parameters deleteTeamSyncRule
+type DeleteTeamSyncRuleParams struct {
+	// in:path
+	// required:true
+	RuleID int64 `json:"rule_id"`
+}
+
+// swagger:response teamSyncRulesResponse
+type TeamSyncRulesResponse struct {
+	// in:body
+	Body []*Rule `json:"body"`
+}
+
+// swagger:response teamSyncRuleResponse
+type TeamSyncRuleResponse struct {
+	// in:body
+	Body *Rule `json:"body"`
+}
+
+// swagger:response teamSyncReportResponse
+type TeamSyncReportResponse struct {
+	// in:body
+	Body *Report `json:"body"`
+}
@@ -0,0 +1,209 @@
+package teamsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	groupsNamespacePrefix = "team-sync."
+	githubPageSize        = 100
+)
+
+// Provider resolves the members of the groups of an identity provider
+type Provider interface {
+	// Name of the provider in the sync rules
+	Name() string
+	// AuthModules are the login modules whose groups are recorded for the provider when the users log in
+	AuthModules() []string
+	// Members returns the IDs of the Grafana users in the group
+	Members(ctx context.Context, group string) ([]int64, error)
+}
+
+// groupStore keeps the groups of the users recorded at their last login, by provider
+type groupStore struct {
+	kv kvstore.KVStore
+}
+
+func (g *groupStore) set(ctx context.Context, provider string, userID int64, groups []string) error {
+	data, err := json.Marshal(groups)
+	if err != nil {
+		return err
+	}
+	return g.kv.Set(ctx, 0, groupsNamespacePrefix+provider, strconv.FormatInt(userID, 10), string(data))
+}
+
+// members returns the users whose recorded groups contain the group
+func (g *groupStore) members(ctx context.Context, provider string, group string) ([]int64, error) {
+	all, err := g.kv.GetAll(ctx, 0, groupsNamespacePrefix+provider)
+	if err != nil {
+		return nil, err
+	}
+
+	members := []int64{}
+	for key, value := range all[0] {
+		var groups []string
+		if err := json.Unmarshal([]byte(value), &groups); err != nil {
+			return nil, fmt.Errorf("invalid groups recorded for user %s: %w", key, err)
+		}
+		if !slices.Contains(groups, group) {
+			continue
+		}
+		userID, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID %q: %w", key, err)
+		}
+		members = append(members, userID)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i] < members[j] })
+	return members, nil
+}
+
+// oidcProvider maps the groups claimed by the OAuth and OpenID Connect providers, they're only known
+// when the users log in
+type oidcProvider struct {
+	authModules []string
+	groups      *groupStore
+}
+
+func (p *oidcProvider) Name() string {
+	return setting.TeamSyncOIDCProvider
+}
+
+func (p *oidcProvider) AuthModules() []string {
+	return p.authModules
+}
+
+func (p *oidcProvider) Members(ctx context.Context, group string) ([]int64, error) {
+	return p.groups.members(ctx, p.Name(), group)
+}
+
+// githubProvider maps the GitHub teams, with a token their members are read from the GitHub API,
+// otherwise the teams recorded when the users logged in with GitHub are used
+type githubProvider struct {
+	apiURL          string
+	token           string
+	client          *http.Client
+	authInfoService login.AuthInfoService
+	groups          *groupStore
+}
+
+func (p *githubProvider) Name() string {
+	return setting.TeamSyncGitHubProvider
+}
+
+func (p *githubProvider) AuthModules() []string {
+	return []string{login.GithubAuthModule}
+}
+
+func (p *githubProvider) Members(ctx context.Context, group string) ([]int64, error) {
+	if p.token == "" {
+		return p.groups.members(ctx, p.Name(), group)
+	}
+
+	org, slug, err := parseGitHubTeam(group)
+	if err != nil {
+		return nil, err
+	}
+
+	members := []int64{}
+	for page := 1; ; page++ {
+		accounts, err := p.teamMembers(ctx, org, slug, page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, account := range accounts {
+			info, err := p.authInfoService.GetAuthInfo(ctx, &login.GetAuthInfoQuery{
+				AuthModule: login.GithubAuthModule,
+				AuthId:     strconv.FormatInt(account.ID, 10),
+			})
+			if errors.Is(err, user.ErrUserNotFound) {
+				// the member never logged in to Grafana
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, info.UserId)
+		}
+
+		if len(accounts) < githubPageSize {
+			break
+		}
+	}
+	return members, nil
+}
+
+type githubAccount struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+}
+
+func (p *githubProvider) teamMembers(ctx context.Context, org, slug string, page int) ([]githubAccount, error) {
+	u := fmt.Sprintf("%s/orgs/%s/teams/%s/members?per_page=%d&page=%d", p.apiURL, url.PathEscape(org), url.PathEscape(slug), githubPageSize, page)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+p.token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to read the members of the GitHub team @%s/%s: %s", org, slug, resp.Status)
+	}
+
+	var accounts []githubAccount
+	if err := json.NewDecoder(resp.Body).Decode(&accounts); err != nil {
+		return nil, fmt.Errorf("invalid members of the GitHub team @%s/%s: %w", org, slug, err)
+	}
+	return accounts, nil
+}
+
+// parseGitHubTeam parses the groups of the GitHub teams, in the form @org/team or
+// https://github.com/orgs/org/teams/team
+func parseGitHubTeam(group string) (string, string, error) {
+	var org, slug string
+	if name, ok := strings.CutPrefix(group, "@"); ok {
+		org, slug, _ = strings.Cut(name, "/")
+	} else if u, err := url.Parse(group); err == nil && u.Host != "" {
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(parts) == 4 && parts[0] == "orgs" && parts[2] == "teams" {
+			org, slug = parts[1], parts[3]
+		}
+	}
+	if org == "" || slug == "" || strings.Contains(slug, "/") {
+		return "", "", fmt.Errorf("%w: %q isn't a GitHub team, the teams are written @org/team", ErrInvalidGroup, group)
+	}
+	return org, slug, nil
+}
+
+func newGitHubProvider(cfg setting.TeamSyncSettings, authInfoService login.AuthInfoService, groups *groupStore) *githubProvider {
+	return &githubProvider{
+		apiURL:          cfg.GitHubAPIURL,
+		token:           cfg.GitHubToken,
+		client:          &http.Client{Timeout: 30 * time.Second},
+		authInfoService: authInfoService,
+		groups:          groups,
+	}
+}
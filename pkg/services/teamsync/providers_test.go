@@ -0,0 +1,78 @@
+package teamsync
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/login/authinfotest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestGitHubProvider_Members(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/orgs/grafana/teams/devs/members":
+			_, _ = fmt.Fprint(w, `[{"id": 100, "login": "alice"}, {"id": 200, "login": "bob"}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	authInfo := &fakeAuthInfoService{users: map[string]int64{"100": 2}}
+	provider := newGitHubProvider(setting.TeamSyncSettings{GitHubAPIURL: server.URL, GitHubToken: "token"}, authInfo, nil)
+
+	t.Run("should return the users who logged in with the GitHub accounts of the members", func(t *testing.T) {
+		members, err := provider.Members(context.Background(), "@grafana/devs")
+		require.NoError(t, err)
+		assert.Equal(t, []int64{2}, members)
+
+		members, err = provider.Members(context.Background(), "https://github.com/orgs/grafana/teams/devs")
+		require.NoError(t, err)
+		assert.Equal(t, []int64{2}, members)
+	})
+
+	t.Run("should fail if the team can't be read", func(t *testing.T) {
+		_, err := provider.Members(context.Background(), "@grafana/ops")
+		assert.Error(t, err)
+	})
+}
+
+func TestParseGitHubTeam(t *testing.T) {
+	for _, group := range []string{"@grafana/devs", "https://github.com/orgs/grafana/teams/devs"} {
+		org, slug, err := parseGitHubTeam(group)
+		require.NoError(t, err, group)
+		assert.Equal(t, "grafana", org)
+		assert.Equal(t, "devs", slug)
+	}
+
+	for _, group := range []string{"devs", "@grafana", "@grafana/", "@grafana/devs/ops", "https://github.com/grafana"} {
+		_, _, err := parseGitHubTeam(group)
+		assert.ErrorIs(t, err, ErrInvalidGroup, group)
+	}
+}
+
+type fakeAuthInfoService struct {
+	authinfotest.FakeService
+	users map[string]int64
+}
+
+func (f *fakeAuthInfoService) GetAuthInfo(_ context.Context, query *login.GetAuthInfoQuery) (*login.UserAuth, error) {
+	userID, ok := f.users[query.AuthId]
+	if !ok || query.AuthModule != login.GithubAuthModule {
+		return nil, user.ErrUserNotFound
+	}
+	return &login.UserAuth{UserId: userID, AuthModule: query.AuthModule, AuthId: query.AuthId}, nil
+}
@@ -0,0 +1,88 @@
+package teamsync
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// Rule maps the members of a group of a provider to a team
+type Rule struct {
+	ID       int64     `xorm:"pk autoincr 'id'" json:"id"`
+	OrgID    int64     `xorm:"org_id" json:"orgId"`
+	TeamID   int64     `xorm:"team_id" json:"teamId"`
+	Provider string    `xorm:"provider" json:"provider"`
+	Group    string    `xorm:"group_id" json:"group"`
+	Created  time.Time `xorm:"created" json:"created"`
+}
+
+func (Rule) TableName() string {
+	return "team_sync_rule"
+}
+
+type store interface {
+	// List returns the rules of a team, or of every team if teamID is 0
+	List(ctx context.Context, orgID, teamID int64) ([]*Rule, error)
+	ListAll(ctx context.Context) ([]*Rule, error)
+	ListByProvider(ctx context.Context, provider string) ([]*Rule, error)
+	Insert(ctx context.Context, rule *Rule) error
+	Delete(ctx context.Context, orgID, teamID, ruleID int64) error
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (s *xormStore) List(ctx context.Context, orgID, teamID int64) ([]*Rule, error) {
+	rules := make([]*Rule, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Where("org_id = ?", orgID)
+		if teamID != 0 {
+			q = q.Where("team_id = ?", teamID)
+		}
+		return q.Asc("id").Find(&rules)
+	})
+	return rules, err
+}
+
+func (s *xormStore) ListAll(ctx context.Context) ([]*Rule, error) {
+	rules := make([]*Rule, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Asc("org_id", "team_id", "id").Find(&rules)
+	})
+	return rules, err
+}
+
+func (s *xormStore) ListByProvider(ctx context.Context, provider string) ([]*Rule, error) {
+	rules := make([]*Rule, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("provider = ?", provider).Asc("org_id", "team_id", "id").Find(&rules)
+	})
+	return rules, err
+}
+
+func (s *xormStore) Insert(ctx context.Context, rule *Rule) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Insert(rule); err != nil {
+			if s.db.GetDialect().IsUniqueConstraintViolation(err) {
+				return ErrRuleExists
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+func (s *xormStore) Delete(ctx context.Context, orgID, teamID, ruleID int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		deleted, err := sess.Where("org_id = ? AND team_id = ? AND id = ?", orgID, teamID, ruleID).Delete(&Rule{})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return ErrRuleNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,83 @@
+package teamsync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	s := &xormStore{db: db.InitTestDB(t)}
+	ctx := context.Background()
+
+	require.NoError(t, s.Insert(ctx, &Rule{OrgID: 1, TeamID: 10, Provider: "oidc", Group: "devs", Created: time.Now()}))
+	require.NoError(t, s.Insert(ctx, &Rule{OrgID: 1, TeamID: 10, Provider: "github", Group: "@grafana/devs", Created: time.Now()}))
+	require.NoError(t, s.Insert(ctx, &Rule{OrgID: 1, TeamID: 11, Provider: "oidc", Group: "ops", Created: time.Now()}))
+
+	t.Run("should not add the same rule twice", func(t *testing.T) {
+		err := s.Insert(ctx, &Rule{OrgID: 1, TeamID: 10, Provider: "oidc", Group: "devs", Created: time.Now()})
+		assert.ErrorIs(t, err, ErrRuleExists)
+	})
+
+	t.Run("should list the rules of a team", func(t *testing.T) {
+		rules, err := s.List(ctx, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, rules, 2)
+		assert.Equal(t, "devs", rules[0].Group)
+		assert.Equal(t, "@grafana/devs", rules[1].Group)
+
+		rules, err = s.ListByProvider(ctx, "oidc")
+		require.NoError(t, err)
+		require.Len(t, rules, 2)
+		assert.Equal(t, int64(11), rules[1].TeamID)
+	})
+
+	t.Run("should delete a rule of a team", func(t *testing.T) {
+		rules, err := s.List(ctx, 1, 11)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+
+		assert.ErrorIs(t, s.Delete(ctx, 1, 10, rules[0].ID), ErrRuleNotFound)
+		require.NoError(t, s.Delete(ctx, 1, 11, rules[0].ID))
+
+		rules, err = s.ListAll(ctx)
+		require.NoError(t, err)
+		assert.Len(t, rules, 2)
+	})
+}
+
+func TestIntegrationGroupStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	groups := &groupStore{kv: kvstore.ProvideService(db.InitTestDB(t))}
+	ctx := context.Background()
+
+	require.NoError(t, groups.set(ctx, "oidc", 2, []string{"devs", "ops"}))
+	require.NoError(t, groups.set(ctx, "oidc", 3, []string{"devs"}))
+	require.NoError(t, groups.set(ctx, "github", 4, []string{"devs"}))
+
+	members, err := groups.members(ctx, "oidc", "devs")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 3}, members)
+
+	// the groups are replaced at each login
+	require.NoError(t, groups.set(ctx, "oidc", 2, []string{"ops"}))
+	members, err = groups.members(ctx, "oidc", "devs")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3}, members)
+}
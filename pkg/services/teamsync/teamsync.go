@@ -0,0 +1,368 @@
+package teamsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	claims "github.com/grafana/authlib/types"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	ErrSyncInProgress  = errors.New("team synchronization already in progress")
+	ErrRuleExists      = errors.New("team sync rule already exists")
+	ErrRuleNotFound    = errors.New("team sync rule not found")
+	ErrUnknownProvider = errors.New("unknown team sync provider")
+	ErrInvalidGroup    = errors.New("invalid team sync group")
+)
+
+type ChangeType string
+
+const (
+	ChangeMemberAdded   ChangeType = "member_added"
+	ChangeMemberRemoved ChangeType = "member_removed"
+)
+
+// Change is a team membership added or removed by a synchronization
+type Change struct {
+	Type   ChangeType `json:"type"`
+	OrgID  int64      `json:"orgId"`
+	TeamID int64      `json:"teamId"`
+	UserID int64      `json:"userId"`
+}
+
+// TeamFailure is a team whose members couldn't be synchronized, its memberships are left untouched
+type TeamFailure struct {
+	OrgID  int64  `json:"orgId"`
+	TeamID int64  `json:"teamId"`
+	Error  string `json:"error"`
+}
+
+type Report struct {
+	StartedAt   time.Time     `json:"startedAt"`
+	FinishedAt  time.Time     `json:"finishedAt"`
+	TeamsSynced int           `json:"teamsSynced"`
+	Changes     []Change      `json:"changes"`
+	Failures    []TeamFailure `json:"failures"`
+}
+
+// Service keeps the members of the teams with sync rules in lockstep with the groups of the identity
+// providers. The memberships it manages are external, the members added manually are left untouched.
+type Service struct {
+	cfg                    setting.TeamSyncSettings
+	store                  store
+	groups                 *groupStore
+	orgService             org.Service
+	teamService            team.Service
+	teamPermissionsService ac.TeamPermissionsService
+	serverLock             *serverlock.ServerLockService
+	log                    log.Logger
+
+	providersMu sync.RWMutex
+	providers   map[string]Provider
+
+	// syncMu prevents a manual synchronization from overlapping a scheduled one
+	syncMu sync.Mutex
+}
+
+func ProvideService(
+	cfg *setting.Cfg, router routing.RouteRegister, accessControl ac.AccessControl, sqlStore db.DB,
+	kv kvstore.KVStore, authnService authn.Service, authInfoService login.AuthInfoService,
+	orgService org.Service, teamService team.Service, teamPermissionsService ac.TeamPermissionsService,
+	serverLock *serverlock.ServerLockService,
+) *Service {
+	s := &Service{
+		cfg:                    cfg.TeamSync,
+		store:                  &xormStore{db: sqlStore},
+		groups:                 &groupStore{kv: kv},
+		orgService:             orgService,
+		teamService:            teamService,
+		teamPermissionsService: teamPermissionsService,
+		serverLock:             serverLock,
+		log:                    log.New("teamsync"),
+		providers:              map[string]Provider{},
+	}
+
+	for _, name := range cfg.TeamSync.Providers {
+		switch name {
+		case setting.TeamSyncOIDCProvider:
+			s.RegisterProvider(&oidcProvider{authModules: cfg.TeamSync.OIDCAuthModules, groups: s.groups})
+		case setting.TeamSyncGitHubProvider:
+			s.RegisterProvider(newGitHubProvider(cfg.TeamSync, authInfoService, s.groups))
+		}
+	}
+
+	teamService.RegisterDelete("DELETE FROM team_sync_rule WHERE org_id = ? AND team_id = ?")
+
+	if s.cfg.Enabled {
+		authnService.RegisterPostLoginHook(s.loginHook, 160)
+	}
+
+	s.registerRoutes(router, accessControl)
+
+	return s
+}
+
+// RegisterProvider adds a provider of groups, replacing the provider of the same name
+func (s *Service) RegisterProvider(provider Provider) {
+	s.providersMu.Lock()
+	defer s.providersMu.Unlock()
+	s.providers[provider.Name()] = provider
+}
+
+func (s *Service) provider(name string) (Provider, bool) {
+	s.providersMu.RLock()
+	defer s.providersMu.RUnlock()
+	provider, ok := s.providers[name]
+	return provider, ok
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Enabled
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		// the lock interval is shorter than the sync interval to leave room for clock drift between instances
+		err := s.serverLock.LockAndExecute(ctx, "team sync", s.cfg.SyncInterval/2, func(ctx context.Context) {
+			if _, err := s.SyncAll(ctx); err != nil {
+				s.log.Error("Failed to synchronize teams", "error", err)
+			}
+		})
+		if err != nil {
+			s.log.Error("Failed to acquire lock for team synchronization", "error", err)
+		}
+	}
+}
+
+// SyncAll reconciles the members of every team with sync rules with the members of their groups
+func (s *Service) SyncAll(ctx context.Context) (*Report, error) {
+	if !s.syncMu.TryLock() {
+		return nil, ErrSyncInProgress
+	}
+	defer s.syncMu.Unlock()
+
+	rules, err := s.store.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list team sync rules: %w", err)
+	}
+
+	report := &Report{StartedAt: time.Now(), Changes: []Change{}, Failures: []TeamFailure{}}
+	orgs := &userOrgs{orgService: s.orgService, orgs: map[int64]map[int64]bool{}}
+
+	// the rules are sorted by team
+	for start := 0; start < len(rules); {
+		end := start + 1
+		for end < len(rules) && rules[end].OrgID == rules[start].OrgID && rules[end].TeamID == rules[start].TeamID {
+			end++
+		}
+		teamRules := rules[start:end]
+		start = end
+
+		changes, err := s.syncTeam(ctx, teamRules[0].OrgID, teamRules[0].TeamID, teamRules, orgs)
+		report.Changes = append(report.Changes, changes...)
+		if err != nil {
+			s.log.Warn("Failed to synchronize team", "orgID", teamRules[0].OrgID, "teamID", teamRules[0].TeamID, "error", err)
+			report.Failures = append(report.Failures, TeamFailure{OrgID: teamRules[0].OrgID, TeamID: teamRules[0].TeamID, Error: err.Error()})
+			continue
+		}
+		report.TeamsSynced++
+	}
+
+	report.FinishedAt = time.Now()
+	s.log.Info("Synchronized teams", "teams", report.TeamsSynced, "changes", len(report.Changes), "failures", len(report.Failures))
+	return report, nil
+}
+
+// syncTeam adds the members of the groups of the rules to the team and removes the external members
+// who aren't in any of the groups anymore. The team is left untouched if a group can't be resolved.
+func (s *Service) syncTeam(ctx context.Context, orgID, teamID int64, rules []*Rule, orgs *userOrgs) ([]Change, error) {
+	wanted := map[int64]bool{}
+	for _, rule := range rules {
+		provider, ok := s.provider(rule.Provider)
+		if !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownProvider, rule.Provider)
+		}
+		members, err := provider.Members(ctx, rule.Group)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve group %q of provider %s: %w", rule.Group, rule.Provider, err)
+		}
+		for _, userID := range members {
+			wanted[userID] = true
+		}
+	}
+
+	members, err := s.teamService.GetTeamMembers(ctx, &team.GetTeamMembersQuery{
+		OrgID:  orgID,
+		TeamID: teamID,
+		SignedInUser: &user.SignedInUser{
+			Login:       "team-sync",
+			OrgID:       orgID,
+			Permissions: map[int64]map[string][]string{orgID: {ac.ActionOrgUsersRead: {ac.ScopeUsersAll}}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []Change{}
+	for _, m := range members {
+		if wanted[m.UserID] {
+			delete(wanted, m.UserID)
+			continue
+		}
+		if !m.External {
+			continue
+		}
+
+		if err := s.setTeamPermission(ctx, orgID, m.UserID, teamID, ""); err != nil {
+			return changes, err
+		}
+		changes = append(changes, Change{Type: ChangeMemberRemoved, OrgID: orgID, TeamID: teamID, UserID: m.UserID})
+	}
+
+	userIDs := make([]int64, 0, len(wanted))
+	for userID := range wanted {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+
+	for _, userID := range userIDs {
+		isOrgMember, err := orgs.isMember(ctx, userID, orgID)
+		if err != nil {
+			return changes, err
+		}
+		if !isOrgMember {
+			continue
+		}
+
+		if err := s.setTeamPermission(ctx, orgID, userID, teamID, team.PermissionTypeMember.String()); err != nil {
+			return changes, err
+		}
+		changes = append(changes, Change{Type: ChangeMemberAdded, OrgID: orgID, TeamID: teamID, UserID: userID})
+	}
+
+	return changes, nil
+}
+
+// loginHook records the groups of the users who log in with a provider and adds them to the teams of their
+// groups right away. The memberships of the groups they left are removed by the next synchronization.
+func (s *Service) loginHook(ctx context.Context, id *authn.Identity, _ *authn.Request, err error) {
+	if err != nil || id == nil || !id.IsIdentityType(claims.TypeUser) {
+		return
+	}
+	userID, err := id.GetInternalID()
+	if err != nil {
+		return
+	}
+
+	s.providersMu.RLock()
+	providers := make([]Provider, 0, len(s.providers))
+	for _, provider := range s.providers {
+		if slices.Contains(provider.AuthModules(), id.GetAuthenticatedBy()) {
+			providers = append(providers, provider)
+		}
+	}
+	s.providersMu.RUnlock()
+
+	for _, provider := range providers {
+		if err := s.groups.set(ctx, provider.Name(), userID, id.Groups); err != nil {
+			s.log.Warn("Failed to record the groups of the user", "provider", provider.Name(), "userID", userID, "error", err)
+			continue
+		}
+		if err := s.addMemberships(ctx, provider.Name(), userID, id.Groups); err != nil {
+			s.log.Warn("Failed to add the user to the teams of their groups", "provider", provider.Name(), "userID", userID, "error", err)
+		}
+	}
+}
+
+func (s *Service) addMemberships(ctx context.Context, provider string, userID int64, groups []string) error {
+	rules, err := s.store.ListByProvider(ctx, provider)
+	if err != nil {
+		return err
+	}
+
+	orgs := &userOrgs{orgService: s.orgService, orgs: map[int64]map[int64]bool{}}
+	added := map[int64]bool{}
+	for _, rule := range rules {
+		if added[rule.TeamID] || !slices.Contains(groups, rule.Group) {
+			continue
+		}
+		added[rule.TeamID] = true
+
+		isOrgMember, err := orgs.isMember(ctx, userID, rule.OrgID)
+		if err != nil {
+			return err
+		}
+		if !isOrgMember {
+			continue
+		}
+
+		isMember, err := s.teamService.IsTeamMember(ctx, rule.OrgID, rule.TeamID, userID)
+		if err != nil {
+			return err
+		}
+		if isMember {
+			continue
+		}
+
+		if err := s.setTeamPermission(ctx, rule.OrgID, userID, rule.TeamID, team.PermissionTypeMember.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) setTeamPermission(ctx context.Context, orgID, userID, teamID int64, permission string) error {
+	_, err := s.teamPermissionsService.SetUserPermission(ctx, orgID, ac.User{ID: userID, IsExternal: true}, strconv.FormatInt(teamID, 10), permission)
+	return err
+}
+
+// userOrgs caches the organizations of the users during a synchronization
+type userOrgs struct {
+	orgService org.Service
+	orgs       map[int64]map[int64]bool
+}
+
+func (u *userOrgs) isMember(ctx context.Context, userID, orgID int64) (bool, error) {
+	orgs, ok := u.orgs[userID]
+	if !ok {
+		result, err := u.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: userID})
+		if err != nil {
+			return false, err
+		}
+		orgs = make(map[int64]bool, len(result))
+		for _, o := range result {
+			orgs[o.OrgID] = true
+		}
+		u.orgs[userID] = orgs
+	}
+	return orgs[orgID], nil
+}
@@ -0,0 +1,212 @@
+package teamsync
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	claims "github.com/grafana/authlib/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamtest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService_SyncAll(t *testing.T) {
+	rules := []*Rule{{ID: 1, OrgID: 1, TeamID: 10, Provider: "fake", Group: "devs"}}
+
+	t.Run("should add the members of the groups and remove the stale external members", func(t *testing.T) {
+		env := setupTestEnv(t, rules...)
+		env.provider.members["devs"] = []int64{2, 3}
+		env.teams.ExpectedMembers = []*team.TeamMemberDTO{
+			{OrgID: 1, TeamID: 10, UserID: 3, External: true},
+			{OrgID: 1, TeamID: 10, UserID: 4, External: true},
+			{OrgID: 1, TeamID: 10, UserID: 5},
+		}
+
+		report, err := env.service.SyncAll(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, report.TeamsSynced)
+		assert.Empty(t, report.Failures)
+		assert.Equal(t, []Change{
+			{Type: ChangeMemberRemoved, OrgID: 1, TeamID: 10, UserID: 4},
+			{Type: ChangeMemberAdded, OrgID: 1, TeamID: 10, UserID: 2},
+		}, report.Changes)
+		assert.Equal(t, []permissionChange{
+			{userID: 4, external: true, permission: ""},
+			{userID: 2, external: true, permission: team.PermissionTypeMember.String()},
+		}, env.teamPermissions.changes)
+	})
+
+	t.Run("should not add the users who aren't members of the organization", func(t *testing.T) {
+		env := setupTestEnv(t, rules...)
+		env.provider.members["devs"] = []int64{2}
+		env.orgs.ExpectedUserOrgDTO = []*org.UserOrgDTO{{OrgID: 2, Role: org.RoleViewer}}
+
+		report, err := env.service.SyncAll(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, report.Changes)
+	})
+
+	t.Run("should leave the team untouched when a group can't be resolved", func(t *testing.T) {
+		env := setupTestEnv(t, append(rules, &Rule{ID: 2, OrgID: 1, TeamID: 10, Provider: "unknown", Group: "ops"})...)
+		env.teams.ExpectedMembers = []*team.TeamMemberDTO{{OrgID: 1, TeamID: 10, UserID: 4, External: true}}
+
+		report, err := env.service.SyncAll(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, report.TeamsSynced)
+		require.Len(t, report.Failures, 1)
+		assert.Equal(t, int64(10), report.Failures[0].TeamID)
+		assert.Empty(t, env.teamPermissions.changes)
+	})
+
+	t.Run("should not run two synchronizations at once", func(t *testing.T) {
+		env := setupTestEnv(t, rules...)
+		env.service.syncMu.Lock()
+		defer env.service.syncMu.Unlock()
+
+		_, err := env.service.SyncAll(context.Background())
+		assert.ErrorIs(t, err, ErrSyncInProgress)
+	})
+}
+
+func TestService_loginHook(t *testing.T) {
+	rules := []*Rule{
+		{ID: 1, OrgID: 1, TeamID: 10, Provider: setting.TeamSyncOIDCProvider, Group: "devs"},
+		{ID: 2, OrgID: 1, TeamID: 11, Provider: setting.TeamSyncOIDCProvider, Group: "ops"},
+	}
+	identity := &authn.Identity{ID: "2", Type: claims.TypeUser, AuthenticatedBy: login.GenericOAuthModule, Groups: []string{"devs"}}
+
+	t.Run("should record the groups and add the user to the teams of their groups", func(t *testing.T) {
+		env := setupTestEnv(t, rules...)
+		env.service.loginHook(context.Background(), identity, &authn.Request{}, nil)
+
+		assert.Equal(t, `["devs"]`, env.kv.groups["2"])
+		assert.Equal(t, []permissionChange{{userID: 2, external: true, permission: team.PermissionTypeMember.String()}}, env.teamPermissions.changes)
+	})
+
+	t.Run("should ignore the logins of the other modules", func(t *testing.T) {
+		env := setupTestEnv(t, rules...)
+		env.service.loginHook(context.Background(), &authn.Identity{ID: "2", Type: claims.TypeUser, AuthenticatedBy: login.LDAPAuthModule, Groups: []string{"devs"}}, &authn.Request{}, nil)
+
+		assert.Empty(t, env.kv.groups)
+		assert.Empty(t, env.teamPermissions.changes)
+	})
+
+	t.Run("should ignore the failed logins", func(t *testing.T) {
+		env := setupTestEnv(t, rules...)
+		env.service.loginHook(context.Background(), identity, &authn.Request{}, errors.New("invalid credentials"))
+
+		assert.Empty(t, env.kv.groups)
+	})
+}
+
+type testEnv struct {
+	service         *Service
+	provider        *fakeProvider
+	kv              *fakeGroupsKV
+	orgs            *orgtest.FakeOrgService
+	teams           *teamtest.FakeService
+	teamPermissions *fakeTeamPermissionsService
+}
+
+func setupTestEnv(t *testing.T, rules ...*Rule) *testEnv {
+	t.Helper()
+
+	env := &testEnv{
+		provider:        &fakeProvider{members: map[string][]int64{}},
+		kv:              &fakeGroupsKV{groups: map[string]string{}},
+		orgs:            orgtest.NewOrgServiceFake(),
+		teams:           teamtest.NewFakeService(),
+		teamPermissions: &fakeTeamPermissionsService{},
+	}
+	env.orgs.ExpectedUserOrgDTO = []*org.UserOrgDTO{{OrgID: 1, Role: org.RoleViewer}}
+
+	env.service = &Service{
+		cfg:                    setting.TeamSyncSettings{Enabled: true},
+		store:                  &fakeStore{rules: rules},
+		groups:                 &groupStore{kv: env.kv},
+		orgService:             env.orgs,
+		teamService:            env.teams,
+		teamPermissionsService: env.teamPermissions,
+		log:                    log.NewNopLogger(),
+		providers:              map[string]Provider{},
+	}
+	env.service.RegisterProvider(env.provider)
+	env.service.RegisterProvider(&oidcProvider{authModules: []string{login.GenericOAuthModule}, groups: env.service.groups})
+
+	return env
+}
+
+type fakeProvider struct {
+	members map[string][]int64
+}
+
+func (f *fakeProvider) Name() string {
+	return "fake"
+}
+
+func (f *fakeProvider) AuthModules() []string {
+	return nil
+}
+
+func (f *fakeProvider) Members(_ context.Context, group string) ([]int64, error) {
+	return f.members[group], nil
+}
+
+type fakeStore struct {
+	store
+	rules []*Rule
+}
+
+func (f *fakeStore) ListAll(_ context.Context) ([]*Rule, error) {
+	return f.rules, nil
+}
+
+func (f *fakeStore) ListByProvider(_ context.Context, provider string) ([]*Rule, error) {
+	rules := []*Rule{}
+	for _, rule := range f.rules {
+		if rule.Provider == provider {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// fakeGroupsKV keeps the groups of the oidc provider
+type fakeGroupsKV struct {
+	kvstore.KVStore
+	groups map[string]string
+}
+
+func (f *fakeGroupsKV) Set(_ context.Context, _ int64, _ string, key string, value string) error {
+	f.groups[key] = value
+	return nil
+}
+
+type permissionChange struct {
+	userID     int64
+	external   bool
+	permission string
+}
+
+type fakeTeamPermissionsService struct {
+	ac.TeamPermissionsService
+	changes []permissionChange
+}
+
+func (f *fakeTeamPermissionsService) SetUserPermission(_ context.Context, _ int64, user ac.User, _, permission string) (*ac.ResourcePermission, error) {
+	f.changes = append(f.changes, permissionChange{userID: user.ID, external: user.IsExternal, permission: permission})
+	return &ac.ResourcePermission{}, nil
+}
@@ -0,0 +1,53 @@
+package tokenusage
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+var ErrTokenNotFound = errutil.NotFound(
+	"tokenusage.token-not-found", errutil.WithPublicMessage("Service account token not found"))
+
+type Service interface {
+	// GetTokenUsage returns the endpoints called and the source IP addresses of a service account token.
+	// Usage recorded in the last minute may not be included yet.
+	GetTokenUsage(ctx context.Context, query *GetTokenUsageQuery) (*TokenUsage, error)
+}
+
+type GetTokenUsageQuery struct {
+	OrgID            int64
+	ServiceAccountID int64
+	TokenID          int64
+}
+
+type TokenUsage struct {
+	TokenID    int64      `json:"tokenId"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	// TotalCount is the number of requests authenticated with the token within the retention period
+	TotalCount int64 `json:"totalCount"`
+	// IsUnused is true when the token hasn't been used within the configured unused period
+	IsUnused  bool         `json:"isUnused"`
+	Endpoints []UsageEntry `json:"endpoints"`
+	SourceIPs []UsageEntry `json:"sourceIps"`
+}
+
+// UsageEntry is the usage of an endpoint or source IP address, endpoints are formatted as "<method> <route>"
+type UsageEntry struct {
+	Value      string    `json:"value"`
+	Count      int64     `json:"count"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+}
+
+// IsUnused returns true when a token last used, or created if never used, before the period
+func IsUnused(lastUsedAt *time.Time, created time.Time, period time.Duration, now time.Time) bool {
+	if period <= 0 {
+		return false
+	}
+	last := created
+	if lastUsedAt != nil {
+		last = *lastUsedAt
+	}
+	return now.Sub(last) > period
+}
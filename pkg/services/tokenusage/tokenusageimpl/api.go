@@ -0,0 +1,77 @@
+package tokenusageimpl
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/tokenusage"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+	saUIDResolver := serviceaccounts.MiddlewareServiceAccountUIDResolver(s.saService, ":serviceAccountId")
+
+	router.Group("/api/serviceaccounts", func(saRoute routing.RouteRegister) {
+		saRoute.Get("/:serviceAccountId/tokens/:tokenId/usage", saUIDResolver, authorize(ac.EvalPermission(serviceaccounts.ActionRead, serviceaccounts.ScopeID)), routing.Wrap(s.GetServiceAccountTokenUsage))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /serviceaccounts/{serviceAccountId}/tokens/{tokenId}/usage service_accounts getServiceAccountTokenUsage
+//
+// # Get the usage of a service account token
+//
+// Returns the endpoints called and the source IP addresses of the token within the retention period,
+// and whether the token hasn't been used within the configured unused period.
+//
+// Required permissions (See note in the [introduction](https://grafana.com/docs/grafana/latest/developers/http_api/serviceaccount/#service-account-api) for an explanation):
+// action: `serviceaccounts:read` scope: `serviceaccounts:id:1` (single service account)
+//
+// Responses:
+// 200: tokenUsageResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) GetServiceAccountTokenUsage(c *contextmodel.ReqContext) response.Response {
+	saID, err := strconv.ParseInt(web.Params(c.Req)[":serviceAccountId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Service Account ID is invalid", err)
+	}
+	tokenID, err := strconv.ParseInt(web.Params(c.Req)[":tokenId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Token ID is invalid", err)
+	}
+
+	usage, err := s.GetTokenUsage(c.Req.Context(), &tokenusage.GetTokenUsageQuery{
+		OrgID:            c.GetOrgID(),
+		ServiceAccountID: saID,
+		TokenID:          tokenID,
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get token usage", err)
+	}
+
+	return response.JSON(http.StatusOK, usage)
+}
+
+// swagger:parameters getServiceAccountTokenUsage
+type GetServiceAccountTokenUsageParams struct {
+	// in:path
+	TokenId int64 `json:"tokenId"`
+	// in:path
+	ServiceAccountId int64 `json:"serviceAccountId"`
+}
+
+// swagger:response tokenUsageResponse
+type TokenUsageResponse struct {
+	// in:body
+	Body tokenusage.TokenUsage `json:"body"`
+}
@@ -0,0 +1,31 @@
+package tokenusageimpl
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "grafana"
+	metricsSubSystem = "serviceaccounts"
+)
+
+type metrics struct {
+	dropped prometheus.Counter
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		dropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "token_usage_dropped_total",
+			Help:      "Number of token usage entries not recorded because the usage buffer was full",
+		}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.dropped)
+	}
+
+	return m
+}
@@ -0,0 +1,254 @@
+package tokenusageimpl
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/ipallowlist"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/tokenusage"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+var _ tokenusage.Service = (*Service)(nil)
+
+const (
+	flushInterval     = time.Minute
+	retentionInterval = time.Hour
+	// maxPendingEntries bounds the usage kept in memory between two flushes
+	maxPendingEntries = 10000
+	// maxNameLength is the size of the name column
+	maxNameLength = 255
+	unknownRoute  = "unknown"
+)
+
+func ProvideService(
+	cfg *setting.Cfg, sqlStore db.DB, router routing.RouteRegister, accessControl ac.AccessControl,
+	saService serviceaccounts.Service, authnService authn.Service, reg prometheus.Registerer, tracer trace.Tracer,
+) *Service {
+	s := &Service{
+		cfg:       cfg,
+		store:     &xormStore{db: sqlStore},
+		saService: saService,
+		pending:   map[usageKey]*usageCount{},
+		metrics:   newMetrics(reg),
+		log:       log.New("tokenusage"),
+		tracer:    tracer,
+		now:       time.Now,
+	}
+
+	if cfg.SATokenUsageEnabled {
+		// The hook runs after the ip allowlist so blocked requests aren't recorded
+		authnService.RegisterPostAuthHook(s.recordHook, 107)
+		s.registerRoutes(router, accessControl)
+	}
+
+	return s
+}
+
+// Service records the endpoints called and the source IP addresses of service account tokens.
+// Usage is aggregated in memory and written by Run so recording it never blocks a request.
+type Service struct {
+	cfg       *setting.Cfg
+	store     store
+	saService serviceaccounts.Service
+	mu        sync.Mutex
+	pending   map[usageKey]*usageCount
+	metrics   *metrics
+	log       log.Logger
+	tracer    trace.Tracer
+	now       func() time.Time
+}
+
+type usageKey struct {
+	orgID   int64
+	tokenID int64
+	kind    string
+	value   string
+}
+
+type usageCount struct {
+	count      int64
+	lastUsedAt time.Time
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.SATokenUsageEnabled
+}
+
+func (s *Service) GetTokenUsage(ctx context.Context, query *tokenusage.GetTokenUsageQuery) (*tokenusage.TokenUsage, error) {
+	ctx, span := s.tracer.Start(ctx, "tokenusage.GetTokenUsage")
+	defer span.End()
+
+	tokens, err := s.saService.ListTokens(ctx, &serviceaccounts.GetSATokensQuery{OrgID: &query.OrgID, ServiceAccountID: &query.ServiceAccountID})
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range tokens {
+		if token.ID != query.TokenID {
+			continue
+		}
+
+		entries, err := s.store.List(ctx, query.OrgID, query.TokenID)
+		if err != nil {
+			return nil, err
+		}
+
+		usage := &tokenusage.TokenUsage{
+			TokenID:    token.ID,
+			LastUsedAt: token.LastUsedAt,
+			IsUnused:   tokenusage.IsUnused(token.LastUsedAt, token.Created, s.cfg.SATokenUnusedPeriod, s.now()),
+			Endpoints:  []tokenusage.UsageEntry{},
+			SourceIPs:  []tokenusage.UsageEntry{},
+		}
+		for _, e := range entries {
+			entry := tokenusage.UsageEntry{Value: e.Name, Count: e.Count, LastUsedAt: e.LastUsedAt}
+			switch e.Kind {
+			case kindEndpoint:
+				usage.TotalCount += e.Count
+				usage.Endpoints = append(usage.Endpoints, entry)
+			case kindIP:
+				usage.SourceIPs = append(usage.SourceIPs, entry)
+			}
+		}
+		return usage, nil
+	}
+
+	return nil, tokenusage.ErrTokenNotFound.Errorf("token %d not found for service account %d", query.TokenID, query.ServiceAccountID)
+}
+
+// recordHook records the source IP address of requests authenticated with a service account token,
+// the endpoint is recorded once the response is written because the route isn't known before.
+func (s *Service) recordHook(ctx context.Context, id *authn.Identity, r *authn.Request) error {
+	keyID := r.GetMeta(authn.MetaKeyAPIKeyID)
+	if keyID == "" || r.HTTPRequest == nil {
+		return nil
+	}
+	tokenID, err := strconv.ParseInt(keyID, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	orgID := id.GetOrgID()
+	if ip := ipallowlist.ClientIP(r.HTTPRequest, s.cfg.IPAllowlist.TrustedProxies); ip != nil {
+		s.record(ctx, usageKey{orgID: orgID, tokenID: tokenID, kind: kindIP, value: ip.String()})
+	}
+
+	webCtx := web.FromContext(r.HTTPRequest.Context())
+	if webCtx == nil {
+		s.record(ctx, usageKey{orgID: orgID, tokenID: tokenID, kind: kindEndpoint, value: endpoint(r.HTTPRequest)})
+		return nil
+	}
+	webCtx.Resp.Before(func(w web.ResponseWriter) {
+		s.record(ctx, usageKey{orgID: orgID, tokenID: tokenID, kind: kindEndpoint, value: endpoint(webCtx.Req)})
+	})
+	return nil
+}
+
+// endpoint returns the method and route pattern of the request, so requests to the same route are counted together
+func endpoint(req *http.Request) string {
+	route, ok := middleware.RouteOperationName(req)
+	if !ok || route == "" {
+		route = unknownRoute
+	}
+	value := req.Method + " " + route
+	if len(value) > maxNameLength {
+		value = value[:maxNameLength]
+	}
+	return value
+}
+
+func (s *Service) record(ctx context.Context, key usageKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.pending[key]; ok {
+		c.count++
+		c.lastUsedAt = s.now()
+		return
+	}
+	if len(s.pending) >= maxPendingEntries {
+		s.metrics.dropped.Inc()
+		s.log.FromContext(ctx).Debug("Token usage buffer is full, dropping usage", "tokenId", key.tokenID, "kind", key.kind)
+		return
+	}
+	s.pending[key] = &usageCount{count: 1, lastUsedAt: s.now()}
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	flush := time.NewTicker(flushInterval)
+	defer flush.Stop()
+	retention := time.NewTicker(retentionInterval)
+	defer retention.Stop()
+
+	s.deleteExpired(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			// the pending usage is written with a fresh context, the one of the server is already canceled
+			flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			s.flush(flushCtx)
+			cancel()
+			return ctx.Err()
+		case <-flush.C:
+			s.flush(ctx)
+		case <-retention.C:
+			s.deleteExpired(ctx)
+		}
+	}
+}
+
+func (s *Service) flush(ctx context.Context) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = map[usageKey]*usageCount{}
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	entries := make([]*apiKeyUsage, 0, len(pending))
+	for key, c := range pending {
+		entries = append(entries, &apiKeyUsage{
+			OrgID:      key.orgID,
+			APIKeyID:   key.tokenID,
+			Kind:       key.kind,
+			Name:       key.value,
+			Count:      c.count,
+			LastUsedAt: c.lastUsedAt,
+		})
+	}
+	if err := s.store.Add(ctx, entries); err != nil {
+		s.log.Error("Failed to store token usage", "count", len(entries), "error", err)
+	}
+}
+
+func (s *Service) deleteExpired(ctx context.Context) {
+	if s.cfg.SATokenUsageRetention <= 0 {
+		return
+	}
+
+	deleted, err := s.store.DeleteBefore(ctx, s.now().Add(-s.cfg.SATokenUsageRetention))
+	if err != nil {
+		s.log.Error("Failed to delete expired token usage", "error", err)
+		return
+	}
+	if deleted > 0 {
+		s.log.Debug("Deleted expired token usage", "count", deleted)
+	}
+}
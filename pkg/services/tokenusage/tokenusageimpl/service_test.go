@@ -0,0 +1,178 @@
+package tokenusageimpl
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	claims "github.com/grafana/authlib/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts/tests"
+	"github.com/grafana/grafana/pkg/services/tokenusage"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func TestService_recordHook(t *testing.T) {
+	ctx := context.Background()
+	id := &authn.Identity{ID: "2", Type: claims.TypeServiceAccount, OrgID: 1}
+	newRequest := func(remoteAddr string) *authn.Request {
+		return &authn.Request{HTTPRequest: &http.Request{Method: http.MethodGet, RemoteAddr: remoteAddr, Header: http.Header{}}}
+	}
+
+	t.Run("should ignore requests not authenticated with a token", func(t *testing.T) {
+		s, store := setupTestService(t, nil)
+
+		require.NoError(t, s.recordHook(ctx, id, newRequest("203.0.113.5:1234")))
+		s.flush(ctx)
+		assert.Empty(t, store.entries)
+	})
+
+	t.Run("should aggregate endpoints and source addresses", func(t *testing.T) {
+		s, store := setupTestService(t, nil)
+
+		for _, addr := range []string{"203.0.113.5:1234", "203.0.113.5:5678", "198.51.100.9:1234"} {
+			r := newRequest(addr)
+			r.SetMeta(authn.MetaKeyAPIKeyID, "3")
+			require.NoError(t, s.recordHook(ctx, id, r))
+		}
+		s.flush(ctx)
+
+		assert.Equal(t, int64(3), store.count(kindEndpoint, "GET unknown"))
+		assert.Equal(t, int64(2), store.count(kindIP, "203.0.113.5"))
+		assert.Equal(t, int64(1), store.count(kindIP, "198.51.100.9"))
+
+		s.flush(ctx)
+		assert.Equal(t, int64(3), store.count(kindEndpoint, "GET unknown"), "flushed usage is not written twice")
+	})
+
+	t.Run("should drop usage when the buffer is full", func(t *testing.T) {
+		s, _ := setupTestService(t, nil)
+		for i := 0; i < maxPendingEntries; i++ {
+			s.pending[usageKey{tokenID: int64(i)}] = &usageCount{count: 1}
+		}
+
+		r := newRequest("203.0.113.5:1234")
+		r.SetMeta(authn.MetaKeyAPIKeyID, "3")
+		require.NoError(t, s.recordHook(ctx, id, r))
+		assert.Len(t, s.pending, maxPendingEntries)
+		assert.Equal(t, 2.0, testutil.ToFloat64(s.metrics.dropped))
+	})
+}
+
+func TestService_GetTokenUsage(t *testing.T) {
+	ctx := context.Background()
+	lastUsed := now.Add(-time.Hour)
+
+	t.Run("should return the usage of the token", func(t *testing.T) {
+		s, store := setupTestService(t, []apikey.APIKey{{ID: 3, Created: now.Add(-365 * 24 * time.Hour), LastUsedAt: &lastUsed}})
+		store.entries = []*apiKeyUsage{
+			{OrgID: 1, APIKeyID: 3, Kind: kindEndpoint, Name: "GET /api/search", Count: 5, LastUsedAt: lastUsed},
+			{OrgID: 1, APIKeyID: 3, Kind: kindEndpoint, Name: "POST /api/dashboards/db", Count: 2, LastUsedAt: lastUsed},
+			{OrgID: 1, APIKeyID: 3, Kind: kindIP, Name: "203.0.113.5", Count: 7, LastUsedAt: lastUsed},
+			{OrgID: 1, APIKeyID: 4, Kind: kindIP, Name: "198.51.100.9", Count: 1, LastUsedAt: lastUsed},
+		}
+
+		usage, err := s.GetTokenUsage(ctx, &tokenusage.GetTokenUsageQuery{OrgID: 1, ServiceAccountID: 2, TokenID: 3})
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), usage.TotalCount)
+		assert.Len(t, usage.Endpoints, 2)
+		assert.Equal(t, []tokenusage.UsageEntry{{Value: "203.0.113.5", Count: 7, LastUsedAt: lastUsed}}, usage.SourceIPs)
+		assert.False(t, usage.IsUnused)
+	})
+
+	t.Run("should flag tokens not used within the unused period", func(t *testing.T) {
+		lastUsed := now.Add(-100 * 24 * time.Hour)
+		s, _ := setupTestService(t, []apikey.APIKey{
+			{ID: 3, Created: now.Add(-365 * 24 * time.Hour), LastUsedAt: &lastUsed},
+			{ID: 4, Created: now.Add(-120 * 24 * time.Hour)},
+			{ID: 5, Created: now.Add(-24 * time.Hour)},
+		})
+
+		for tokenID, unused := range map[int64]bool{3: true, 4: true, 5: false} {
+			usage, err := s.GetTokenUsage(ctx, &tokenusage.GetTokenUsageQuery{OrgID: 1, ServiceAccountID: 2, TokenID: tokenID})
+			require.NoError(t, err)
+			assert.Equal(t, unused, usage.IsUnused, "token %d", tokenID)
+		}
+	})
+
+	t.Run("should not return tokens of other service accounts", func(t *testing.T) {
+		s, _ := setupTestService(t, []apikey.APIKey{{ID: 3, Created: now}})
+
+		_, err := s.GetTokenUsage(ctx, &tokenusage.GetTokenUsageQuery{OrgID: 1, ServiceAccountID: 2, TokenID: 4})
+		assert.ErrorIs(t, err, tokenusage.ErrTokenNotFound)
+	})
+}
+
+func setupTestService(t *testing.T, tokens []apikey.APIKey) (*Service, *fakeStore) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.SATokenUsageEnabled = true
+	cfg.SATokenUnusedPeriod = 90 * 24 * time.Hour
+
+	store := &fakeStore{}
+	return &Service{
+		cfg:       cfg,
+		store:     store,
+		saService: &tests.FakeServiceAccountService{ExpectedServiceAccountTokens: tokens},
+		pending:   map[usageKey]*usageCount{},
+		metrics:   newMetrics(nil),
+		log:       log.NewNopLogger(),
+		tracer:    tracing.InitializeTracerForTest(),
+		now:       func() time.Time { return now },
+	}, store
+}
+
+type fakeStore struct {
+	entries []*apiKeyUsage
+}
+
+func (f *fakeStore) Add(_ context.Context, entries []*apiKeyUsage) error {
+	for _, entry := range entries {
+		found := false
+		for _, e := range f.entries {
+			if e.APIKeyID == entry.APIKeyID && e.Kind == entry.Kind && e.Name == entry.Name {
+				e.Count += entry.Count
+				e.LastUsedAt = entry.LastUsedAt
+				found = true
+			}
+		}
+		if !found {
+			f.entries = append(f.entries, entry)
+		}
+	}
+	return nil
+}
+
+func (f *fakeStore) List(_ context.Context, orgID, tokenID int64) ([]*apiKeyUsage, error) {
+	var entries []*apiKeyUsage
+	for _, e := range f.entries {
+		if e.OrgID == orgID && e.APIKeyID == tokenID {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func (f *fakeStore) DeleteBefore(_ context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeStore) count(kind, name string) int64 {
+	for _, e := range f.entries {
+		if e.Kind == kind && e.Name == name {
+			return e.Count
+		}
+	}
+	return 0
+}
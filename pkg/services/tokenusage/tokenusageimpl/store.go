@@ -0,0 +1,102 @@
+package tokenusageimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+const (
+	kindEndpoint = "endpoint"
+	kindIP       = "ip"
+)
+
+type apiKeyUsage struct {
+	ID         int64     `xorm:"pk autoincr 'id'"`
+	OrgID      int64     `xorm:"org_id"`
+	APIKeyID   int64     `xorm:"api_key_id"`
+	Kind       string    `xorm:"kind"`
+	Name       string    `xorm:"name"`
+	Count      int64     `xorm:"request_count"`
+	LastUsedAt time.Time `xorm:"last_used_at"`
+}
+
+func (apiKeyUsage) TableName() string {
+	return "api_key_usage"
+}
+
+type store interface {
+	// Add adds the counts of the entries to the stored usage of the tokens
+	Add(ctx context.Context, entries []*apiKeyUsage) error
+	// List returns the usage of the token ordered by the most recently used first
+	List(ctx context.Context, orgID, tokenID int64) ([]*apiKeyUsage, error)
+	// DeleteBefore deletes the usage last seen before the time and returns how many entries were deleted
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (s *xormStore) Add(ctx context.Context, entries []*apiKeyUsage) error {
+	for _, entry := range entries {
+		err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			updated, err := s.increment(sess, entry)
+			if err != nil || updated {
+				return err
+			}
+
+			_, insertErr := sess.Insert(entry)
+			if insertErr == nil {
+				return nil
+			}
+			// another instance may have inserted the entry in the meantime
+			updated, err = s.increment(sess, entry)
+			if err != nil {
+				return err
+			}
+			if !updated {
+				return insertErr
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *xormStore) increment(sess *db.Session, entry *apiKeyUsage) (bool, error) {
+	res, err := sess.Exec(
+		"UPDATE api_key_usage SET request_count = request_count + ?, last_used_at = ? WHERE api_key_id = ? AND kind = ? AND name = ?",
+		entry.Count, entry.LastUsedAt, entry.APIKeyID, entry.Kind, entry.Name,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+func (s *xormStore) List(ctx context.Context, orgID, tokenID int64) ([]*apiKeyUsage, error) {
+	entries := make([]*apiKeyUsage, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND api_key_id = ?", orgID, tokenID).Desc("last_used_at").Find(&entries)
+	})
+	return entries, err
+}
+
+func (s *xormStore) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM api_key_usage WHERE last_used_at < ?", before)
+		if err != nil {
+			return err
+		}
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	return deleted, err
+}
@@ -0,0 +1,60 @@
+package tokenusageimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	store := &xormStore{db: db.InitTestDB(t)}
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("should add up the counts of the entries", func(t *testing.T) {
+		require.NoError(t, store.Add(ctx, []*apiKeyUsage{
+			{OrgID: 1, APIKeyID: 10, Kind: kindEndpoint, Name: "GET /api/search", Count: 2, LastUsedAt: start},
+			{OrgID: 1, APIKeyID: 10, Kind: kindIP, Name: "10.0.0.1", Count: 2, LastUsedAt: start},
+			{OrgID: 1, APIKeyID: 11, Kind: kindIP, Name: "10.0.0.1", Count: 1, LastUsedAt: start},
+		}))
+		require.NoError(t, store.Add(ctx, []*apiKeyUsage{
+			{OrgID: 1, APIKeyID: 10, Kind: kindEndpoint, Name: "GET /api/search", Count: 3, LastUsedAt: start.Add(time.Hour)},
+		}))
+
+		entries, err := store.List(ctx, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, kindEndpoint, entries[0].Kind, "the most recently used entry comes first")
+		assert.Equal(t, int64(5), entries[0].Count)
+		assert.True(t, start.Add(time.Hour).Equal(entries[0].LastUsedAt))
+		assert.Equal(t, int64(2), entries[1].Count)
+
+		entries, err = store.List(ctx, 2, 10)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "the usage is scoped to the org of the token")
+	})
+
+	t.Run("should delete the usage last seen before a time", func(t *testing.T) {
+		deleted, err := store.DeleteBefore(ctx, start.Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+
+		entries, err := store.List(ctx, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, kindEndpoint, entries[0].Kind)
+	})
+}
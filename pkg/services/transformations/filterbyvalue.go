@@ -0,0 +1,210 @@
+package transformations
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// The filter by value types and matches.
+const (
+	filterInclude = "include"
+	filterExclude = "exclude"
+
+	matchAny = "any"
+	matchAll = "all"
+)
+
+type valueMatcherOptions struct {
+	Value any `json:"value"`
+	From  any `json:"from"`
+	To    any `json:"to"`
+}
+
+type valueMatcherConfig struct {
+	ID      string              `json:"id"`
+	Options valueMatcherOptions `json:"options"`
+}
+
+type valueFilter struct {
+	FieldName string             `json:"fieldName"`
+	Config    valueMatcherConfig `json:"config"`
+}
+
+type filterByValueOptions struct {
+	Type    string        `json:"type"`
+	Match   string        `json:"match"`
+	Filters []valueFilter `json:"filters"`
+}
+
+// valueMatcher returns true when the value of a row matches. A null value is nil.
+type valueMatcher func(v any) bool
+
+// filterByValue keeps or removes the rows of every frame matching the filters. The filters on a field missing
+// from a frame are ignored.
+func filterByValue(raw json.RawMessage, frames data.Frames) (data.Frames, error) {
+	options := filterByValueOptions{Type: filterInclude, Match: matchAny}
+	if err := unmarshalOptions(raw, &options); err != nil {
+		return nil, err
+	}
+	if options.Type != filterInclude && options.Type != filterExclude {
+		return nil, fmt.Errorf("unsupported filter type %q", options.Type)
+	}
+	if options.Match != matchAny && options.Match != matchAll {
+		return nil, fmt.Errorf("unsupported filter match %q", options.Match)
+	}
+
+	matchers := make([]valueMatcher, 0, len(options.Filters))
+	for _, filter := range options.Filters {
+		m, err := newValueMatcher(filter.Config)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter on field %q: %w", filter.FieldName, err)
+		}
+		matchers = append(matchers, m)
+	}
+
+	result := make(data.Frames, 0, len(frames))
+	for _, frame := range frames {
+		var fields []*data.Field
+		var fieldMatchers []valueMatcher
+		for i, filter := range options.Filters {
+			for _, f := range frame.Fields {
+				if fieldName(f) == filter.FieldName {
+					fields = append(fields, f)
+					fieldMatchers = append(fieldMatchers, matchers[i])
+					break
+				}
+			}
+		}
+		if len(fields) == 0 {
+			result = append(result, frame)
+			continue
+		}
+
+		var rows []int
+		rowLen, _ := frame.RowLen()
+		for row := 0; row < rowLen; row++ {
+			matched := options.Match == matchAll
+			for i, f := range fields {
+				v, _ := f.ConcreteAt(row)
+				if fieldMatchers[i](v) != (options.Match == matchAll) {
+					matched = !matched
+					break
+				}
+			}
+			if matched == (options.Type == filterInclude) {
+				rows = append(rows, row)
+			}
+		}
+
+		filtered := frame.EmptyCopy()
+		for i, f := range frame.Fields {
+			for _, row := range rows {
+				filtered.Fields[i].Append(f.CopyAt(row))
+			}
+		}
+		result = append(result, filtered)
+	}
+	return result, nil
+}
+
+func newValueMatcher(config valueMatcherConfig) (valueMatcher, error) {
+	o := config.Options
+	switch config.ID {
+	case "isNull":
+		return func(v any) bool { return v == nil }, nil
+	case "isNotNull":
+		return func(v any) bool { return v != nil }, nil
+	case "equal":
+		return func(v any) bool { return v != nil && valuesEqual(v, o.Value) }, nil
+	case "notEqual":
+		return func(v any) bool { return v == nil || !valuesEqual(v, o.Value) }, nil
+	case "greater":
+		return numericMatcher(o.Value, func(v, x float64) bool { return v > x })
+	case "greaterOrEqual":
+		return numericMatcher(o.Value, func(v, x float64) bool { return v >= x })
+	case "lower":
+		return numericMatcher(o.Value, func(v, x float64) bool { return v < x })
+	case "lowerOrEqual":
+		return numericMatcher(o.Value, func(v, x float64) bool { return v <= x })
+	case "between":
+		from, ok := toFloat(o.From)
+		if !ok {
+			return nil, fmt.Errorf("invalid from value %v", o.From)
+		}
+		to, ok := toFloat(o.To)
+		if !ok {
+			return nil, fmt.Errorf("invalid to value %v", o.To)
+		}
+		return func(v any) bool {
+			f, ok := toFloat(v)
+			return ok && f > from && f < to
+		}, nil
+	case "regex":
+		pattern, _ := o.Value.(string)
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", pattern, err)
+		}
+		return func(v any) bool { return v != nil && re.MatchString(fmt.Sprint(v)) }, nil
+	default:
+		return nil, fmt.Errorf("unsupported matcher %q", config.ID)
+	}
+}
+
+func numericMatcher(value any, compare func(v, x float64) bool) (valueMatcher, error) {
+	x, ok := toFloat(value)
+	if !ok {
+		return nil, fmt.Errorf("invalid value %v", value)
+	}
+	return func(v any) bool {
+		f, ok := toFloat(v)
+		return ok && compare(f, x)
+	}, nil
+}
+
+func valuesEqual(v, x any) bool {
+	vf, vok := toFloat(v)
+	xf, xok := toFloat(x)
+	if vok && xok {
+		return vf == xf
+	}
+	return fmt.Sprint(v) == fmt.Sprint(x)
+}
+
+// toFloat converts the numbers and the numeric strings to float64.
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,217 @@
+package transformations
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// The group by operations of a field.
+const (
+	groupByOperation   = "groupby"
+	aggregateOperation = "aggregate"
+)
+
+type groupByFieldOptions struct {
+	Operation    string   `json:"operation"`
+	Aggregations []string `json:"aggregations"`
+}
+
+type groupByOptions struct {
+	Fields map[string]groupByFieldOptions `json:"fields"`
+}
+
+// groupBy groups the rows of every frame by the values of the group by fields, and calculates the aggregations
+// of the aggregated fields for every group. The frames without a group by field are returned unchanged.
+func groupBy(raw json.RawMessage, frames data.Frames) (data.Frames, error) {
+	var options groupByOptions
+	if err := unmarshalOptions(raw, &options); err != nil {
+		return nil, err
+	}
+	for name, o := range options.Fields {
+		if o.Operation != aggregateOperation {
+			continue
+		}
+		for _, calc := range o.Aggregations {
+			if _, ok := reducers[calc]; !ok {
+				return nil, fmt.Errorf("unsupported calculation %q for field %q", calc, name)
+			}
+		}
+	}
+
+	result := make(data.Frames, 0, len(frames))
+	for _, frame := range frames {
+		var keys, aggregated []*data.Field
+		for _, f := range frame.Fields {
+			switch options.Fields[fieldName(f)].Operation {
+			case groupByOperation:
+				keys = append(keys, f)
+			case aggregateOperation:
+				aggregated = append(aggregated, f)
+			}
+		}
+		if len(keys) == 0 {
+			result = append(result, frame)
+			continue
+		}
+
+		// the rows of every group, the groups are in the order of their first row
+		var groups [][]int
+		groupIdx := map[string]int{}
+		rowLen, _ := frame.RowLen()
+		for row := 0; row < rowLen; row++ {
+			key := rowKey(keys, row)
+			idx, ok := groupIdx[key]
+			if !ok {
+				idx = len(groups)
+				groupIdx[key] = idx
+				groups = append(groups, nil)
+			}
+			groups[idx] = append(groups[idx], row)
+		}
+
+		fields := make([]*data.Field, 0, len(keys)+len(aggregated))
+		for _, k := range keys {
+			f := data.NewFieldFromFieldType(k.Type(), len(groups))
+			f.Name = k.Name
+			f.Labels = k.Labels
+			f.Config = k.Config
+			for i, rows := range groups {
+				f.Set(i, k.CopyAt(rows[0]))
+			}
+			fields = append(fields, f)
+		}
+		for _, a := range aggregated {
+			for _, calc := range options.Fields[fieldName(a)].Aggregations {
+				f := reducers[calc](a, groups)
+				f.Name = fmt.Sprintf("%s (%s)", fieldName(a), calc)
+				f.Labels = a.Labels
+				fields = append(fields, f)
+			}
+		}
+
+		grouped := data.NewFrame(frame.Name, fields...)
+		grouped.RefID = frame.RefID
+		grouped.Meta = frame.Meta
+		result = append(result, grouped)
+	}
+	return result, nil
+}
+
+func rowKey(fields []*data.Field, row int) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		v, ok := f.ConcreteAt(row)
+		if !ok {
+			parts[i] = "null"
+			continue
+		}
+		parts[i] = fmt.Sprintf("%T:%v", v, v)
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// reducer calculates a value for every group of rows of a field.
+type reducer func(f *data.Field, groups [][]int) *data.Field
+
+var reducers = map[string]reducer{
+	"sum":          numericReducer(sumOf),
+	"mean":         numericReducer(meanOf),
+	"min":          numericReducer(minOf),
+	"max":          numericReducer(maxOf),
+	"count":        countReducer,
+	"first":        valueReducer(false, false),
+	"last":         valueReducer(true, false),
+	"firstNotNull": valueReducer(false, true),
+	"lastNotNull":  valueReducer(true, true),
+}
+
+// numericReducer returns a reducer calculating a number from the non-null numbers of every group. The result
+// is null for a group without numbers.
+func numericReducer(calc func(values []float64) float64) reducer {
+	return func(f *data.Field, groups [][]int) *data.Field {
+		result := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, len(groups))
+		for i, rows := range groups {
+			values := make([]float64, 0, len(rows))
+			for _, row := range rows {
+				v, err := f.NullableFloatAt(row)
+				if err != nil || v == nil || math.IsNaN(*v) {
+					continue
+				}
+				values = append(values, *v)
+			}
+			if len(values) == 0 {
+				continue
+			}
+			v := calc(values)
+			result.Set(i, &v)
+		}
+		return result
+	}
+}
+
+func countReducer(_ *data.Field, groups [][]int) *data.Field {
+	result := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, len(groups))
+	for i, rows := range groups {
+		v := float64(len(rows))
+		result.Set(i, &v)
+	}
+	return result
+}
+
+// valueReducer returns a reducer taking the first or the last value of every group, optionally ignoring the
+// null values. The result has the type of the field.
+func valueReducer(last, notNull bool) reducer {
+	return func(f *data.Field, groups [][]int) *data.Field {
+		result := data.NewFieldFromFieldType(f.Type().NullableType(), len(groups))
+		result.Config = f.Config
+		for i, rows := range groups {
+			for j := range rows {
+				row := rows[j]
+				if last {
+					row = rows[len(rows)-1-j]
+				}
+				v, ok := f.ConcreteAt(row)
+				if !ok && notNull {
+					continue
+				}
+				if ok {
+					result.SetConcrete(i, v)
+				}
+				break
+			}
+		}
+		return result
+	}
+}
+
+func sumOf(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
+
+func meanOf(values []float64) float64 {
+	return sumOf(values) / float64(len(values))
+}
+
+func minOf(values []float64) float64 {
+	m := values[0]
+	for _, v := range values[1:] {
+		m = math.Min(m, v)
+	}
+	return m
+}
+
+func maxOf(values []float64) float64 {
+	m := values[0]
+	for _, v := range values[1:] {
+		m = math.Max(m, v)
+	}
+	return m
+}
@@ -0,0 +1,163 @@
+package transformations
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// The join modes.
+const (
+	joinOuter = "outer"
+	joinInner = "inner"
+)
+
+type joinByFieldOptions struct {
+	ByField string `json:"byField"`
+	Mode    string `json:"mode"`
+}
+
+// joinByField joins the frames in a single frame on the values of a field, by default the first time field of
+// every frame. The frames without the field are dropped. The outer join keeps the values present in any frame,
+// the inner join the values present in every frame, and the rows are sorted by time or number.
+func joinByField(raw json.RawMessage, frames data.Frames) (data.Frames, error) {
+	var options joinByFieldOptions
+	if err := unmarshalOptions(raw, &options); err != nil {
+		return nil, err
+	}
+	switch options.Mode {
+	case "":
+		options.Mode = joinOuter
+	case joinOuter, joinInner:
+	default:
+		return nil, fmt.Errorf("unsupported join mode %q", options.Mode)
+	}
+	if len(frames) < 2 {
+		return frames, nil
+	}
+
+	type joined struct {
+		frame *data.Frame
+		key   int
+		// the row of every key in the frame, the last row wins on duplicated keys
+		rows map[string]int
+	}
+	var inputs []joined
+	var keyField *data.Field
+	var keys []string
+	keyRows := map[string]struct {
+		input, row int
+	}{}
+	for _, frame := range frames {
+		idx := joinKeyIndex(frame, options.ByField)
+		if idx < 0 {
+			continue
+		}
+		in := joined{frame: frame, key: idx, rows: map[string]int{}}
+		if keyField == nil {
+			keyField = frame.Fields[idx]
+		}
+		for row := 0; row < frame.Fields[idx].Len(); row++ {
+			key := rowKey(frame.Fields[idx:idx+1], row)
+			in.rows[key] = row
+			if _, ok := keyRows[key]; !ok {
+				keyRows[key] = struct{ input, row int }{len(inputs), row}
+				keys = append(keys, key)
+			}
+		}
+		inputs = append(inputs, in)
+	}
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+
+	if options.Mode == joinInner {
+		common := keys[:0]
+	KEYS:
+		for _, key := range keys {
+			for _, in := range inputs {
+				if _, ok := in.rows[key]; !ok {
+					continue KEYS
+				}
+			}
+			common = append(common, key)
+		}
+		keys = common
+	}
+
+	keyAt := func(key string) any {
+		k := keyRows[key]
+		in := inputs[k.input]
+		v, _ := in.frame.Fields[in.key].ConcreteAt(k.row)
+		return v
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return joinKeyLess(keyAt(keys[i]), keyAt(keys[j]))
+	})
+
+	key := data.NewFieldFromFieldType(keyField.Type(), len(keys))
+	key.Name = keyField.Name
+	key.Config = keyField.Config
+	for i, k := range keys {
+		r := keyRows[k]
+		in := inputs[r.input]
+		key.Set(i, in.frame.Fields[in.key].CopyAt(r.row))
+	}
+
+	fields := []*data.Field{key}
+	for _, in := range inputs {
+		for idx, f := range in.frame.Fields {
+			if idx == in.key {
+				continue
+			}
+			field := data.NewFieldFromFieldType(f.Type().NullableType(), len(keys))
+			field.Name = f.Name
+			field.Labels = f.Labels
+			field.Config = f.Config
+			for i, k := range keys {
+				row, ok := in.rows[k]
+				if !ok {
+					continue
+				}
+				if v, ok := f.ConcreteAt(row); ok {
+					field.SetConcrete(i, v)
+				}
+			}
+			fields = append(fields, field)
+		}
+	}
+
+	frame := data.NewFrame("", fields...)
+	frame.RefID = inputs[0].frame.RefID
+	return data.Frames{frame}, nil
+}
+
+// joinKeyIndex returns the index of the join field of the frame, or -1.
+func joinKeyIndex(frame *data.Frame, byField string) int {
+	for i, f := range frame.Fields {
+		if byField == "" && f.Type().Time() || byField != "" && fieldName(f) == byField {
+			return i
+		}
+	}
+	return -1
+}
+
+// joinKeyLess orders the times and the numbers, the other values keep their order.
+func joinKeyLess(a, b any) bool {
+	switch a := a.(type) {
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.Before(b)
+		}
+	default:
+		af, aok := toFloat(a)
+		bf, bok := toFloat(b)
+		if aok && bok {
+			return af < bf
+		}
+	}
+	return false
+}
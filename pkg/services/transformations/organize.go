@@ -0,0 +1,66 @@
+package transformations
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+type organizeOptions struct {
+	ExcludeByName map[string]bool   `json:"excludeByName"`
+	IndexByName   map[string]int    `json:"indexByName"`
+	RenameByName  map[string]string `json:"renameByName"`
+}
+
+// organize removes, orders and renames the fields of every frame. The fields without an index keep their
+// order, after the fields with an index.
+func organize(raw json.RawMessage, frames data.Frames) (data.Frames, error) {
+	var options organizeOptions
+	if err := unmarshalOptions(raw, &options); err != nil {
+		return nil, err
+	}
+
+	result := make(data.Frames, 0, len(frames))
+	for _, frame := range frames {
+		fields := make([]*data.Field, 0, len(frame.Fields))
+		for _, f := range frame.Fields {
+			if !options.ExcludeByName[fieldName(f)] {
+				fields = append(fields, f)
+			}
+		}
+
+		if len(options.IndexByName) > 0 {
+			sort.SliceStable(fields, func(i, j int) bool {
+				return organizeIndex(options.IndexByName, fields[i]) < organizeIndex(options.IndexByName, fields[j])
+			})
+		}
+
+		for i, f := range fields {
+			name, ok := options.RenameByName[fieldName(f)]
+			if !ok || name == "" {
+				continue
+			}
+			renamed := *f
+			renamed.Name = name
+			if f.Config != nil && f.Config.DisplayNameFromDS != "" {
+				config := *f.Config
+				config.DisplayNameFromDS = name
+				renamed.Config = &config
+			}
+			fields[i] = &renamed
+		}
+
+		organized := *frame
+		organized.Fields = fields
+		result = append(result, &organized)
+	}
+	return result, nil
+}
+
+func organizeIndex(indexByName map[string]int, f *data.Field) int {
+	if idx, ok := indexByName[fieldName(f)]; ok {
+		return idx
+	}
+	return len(indexByName) + 1
+}
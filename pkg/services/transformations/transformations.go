@@ -0,0 +1,155 @@
+// Package transformations applies a core set of the dashboard transformations to data frames on the server, so
+// the API consumers get the same data as the panels displaying the frames.
+//
+// The transformations use the configuration saved in the panels, and aim to produce the same output as the
+// frontend implementations.
+package transformations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// The identifiers of the supported transformations, the same as in the frontend.
+const (
+	OrganizeID      = "organize"
+	GroupByID       = "groupBy"
+	JoinByFieldID   = "joinByField"
+	FilterByValueID = "filterByValue"
+)
+
+var ErrUnsupportedTransformation = errors.New("unsupported transformation")
+
+// Config is a transformation of a panel.
+type Config struct {
+	// ID is the transformation identifier, for example "organize"
+	ID string `json:"id"`
+	// Disabled transformations are not applied
+	Disabled bool `json:"disabled,omitempty"`
+	// Options are the options of the transformation, in the format saved by the frontend
+	Options json.RawMessage `json:"options,omitempty"`
+}
+
+type transformer func(options json.RawMessage, frames data.Frames) (data.Frames, error)
+
+var transformers = map[string]transformer{
+	OrganizeID:      organize,
+	GroupByID:       groupBy,
+	JoinByFieldID:   joinByField,
+	FilterByValueID: filterByValue,
+}
+
+// IsSupported returns true when the transformation can be applied on the server.
+func IsSupported(id string) bool {
+	_, ok := transformers[id]
+	return ok
+}
+
+// Validate checks that all the enabled transformations are supported.
+func Validate(configs []Config) error {
+	for _, c := range configs {
+		if !c.Disabled && !IsSupported(c.ID) {
+			return fmt.Errorf("%w: %q", ErrUnsupportedTransformation, c.ID)
+		}
+	}
+	return nil
+}
+
+// Apply applies the transformations to the frames, in order.
+func Apply(frames data.Frames, configs []Config) (data.Frames, error) {
+	if err := Validate(configs); err != nil {
+		return nil, err
+	}
+	for _, c := range configs {
+		if c.Disabled {
+			continue
+		}
+		var err error
+		frames, err = transformers[c.ID](c.Options, frames)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply the %q transformation: %w", c.ID, err)
+		}
+	}
+	return frames, nil
+}
+
+// ApplyToResponse applies the transformations to the frames of all the successful responses, taken in the order
+// of refIDs, like a panel does with the frames of its queries. The transformed frames are returned in the response
+// of their refID, or in the response of the first refID when they don't have one.
+func ApplyToResponse(resp *backend.QueryDataResponse, refIDs []string, configs []Config) error {
+	if resp == nil || len(configs) == 0 {
+		return nil
+	}
+
+	var frames data.Frames
+	successful := make([]string, 0, len(refIDs))
+	for _, refID := range refIDs {
+		r, ok := resp.Responses[refID]
+		if !ok || r.Error != nil {
+			continue
+		}
+		successful = append(successful, refID)
+		for _, f := range r.Frames {
+			if f.RefID == "" {
+				f.RefID = refID
+			}
+			frames = append(frames, f)
+		}
+	}
+	if len(successful) == 0 {
+		return nil
+	}
+
+	transformed, err := Apply(frames, configs)
+	if err != nil {
+		return err
+	}
+
+	byRefID := make(map[string]data.Frames, len(successful))
+	for _, f := range transformed {
+		refID := f.RefID
+		if _, ok := resp.Responses[refID]; !ok || !contains(successful, refID) {
+			refID = successful[0]
+			f.RefID = refID
+		}
+		byRefID[refID] = append(byRefID[refID], f)
+	}
+	for _, refID := range successful {
+		r := resp.Responses[refID]
+		r.Frames = byRefID[refID]
+		resp.Responses[refID] = r
+	}
+	return nil
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func unmarshalOptions(raw json.RawMessage, options any) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, options); err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+	return nil
+}
+
+// fieldName returns the name of a field as the transformations match it: the display name when it is set,
+// the field name otherwise.
+func fieldName(f *data.Field) string {
+	if f.Config != nil && f.Config.DisplayNameFromDS != "" {
+		return f.Config.DisplayNameFromDS
+	}
+	return f.Name
+}
@@ -0,0 +1,200 @@
+package transformations
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func transformation(t *testing.T, id string, options any) Config {
+	t.Helper()
+	raw, err := json.Marshal(options)
+	require.NoError(t, err)
+	return Config{ID: id, Options: raw}
+}
+
+func fieldNames(frame *data.Frame) []string {
+	names := make([]string, 0, len(frame.Fields))
+	for _, f := range frame.Fields {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestApply(t *testing.T) {
+	frames := data.Frames{data.NewFrame("", data.NewField("a", nil, []float64{1}))}
+
+	t.Run("should fail on an unsupported transformation", func(t *testing.T) {
+		_, err := Apply(frames, []Config{{ID: "calculateField"}})
+		require.ErrorIs(t, err, ErrUnsupportedTransformation)
+	})
+
+	t.Run("should skip the disabled transformations", func(t *testing.T) {
+		result, err := Apply(frames, []Config{
+			{ID: "calculateField", Disabled: true},
+			transformation(t, OrganizeID, map[string]any{"renameByName": map[string]string{"a": "b"}}),
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"b"}, fieldNames(result[0]))
+	})
+}
+
+func TestOrganize(t *testing.T) {
+	frame := data.NewFrame("",
+		data.NewField("a", nil, []float64{1}),
+		data.NewField("b", nil, []float64{2}),
+		data.NewField("c", nil, []float64{3}),
+		data.NewField("d", nil, []float64{4}),
+	)
+	result, err := Apply(data.Frames{frame}, []Config{transformation(t, OrganizeID, map[string]any{
+		"excludeByName": map[string]bool{"b": true},
+		"indexByName":   map[string]int{"c": 0, "a": 1},
+		"renameByName":  map[string]string{"c": "renamed"},
+	})})
+	require.NoError(t, err)
+	require.Equal(t, []string{"renamed", "a", "d"}, fieldNames(result[0]))
+	require.Equal(t, []string{"a", "b", "c", "d"}, fieldNames(frame), "the input frame should not be modified")
+}
+
+func TestGroupBy(t *testing.T) {
+	frame := data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b", "a", "b", "a"}),
+		data.NewField("value", nil, []*float64{ptr(1.0), ptr(2.0), nil, ptr(4.0), ptr(5.0)}),
+	)
+	result, err := Apply(data.Frames{frame}, []Config{transformation(t, GroupByID, map[string]any{
+		"fields": map[string]any{
+			"host":  map[string]any{"operation": "groupby"},
+			"value": map[string]any{"operation": "aggregate", "aggregations": []string{"sum", "count", "last", "lastNotNull"}},
+		},
+	})})
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	require.Equal(t, []string{"host", "value (sum)", "value (count)", "value (last)", "value (lastNotNull)"}, fieldNames(result[0]))
+
+	expected := data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b"}),
+		data.NewField("value (sum)", nil, []*float64{ptr(6.0), ptr(6.0)}),
+		data.NewField("value (count)", nil, []*float64{ptr(3.0), ptr(2.0)}),
+		data.NewField("value (last)", nil, []*float64{ptr(5.0), ptr(4.0)}),
+		data.NewField("value (lastNotNull)", nil, []*float64{ptr(5.0), ptr(4.0)}),
+	)
+	for i, f := range expected.Fields {
+		for row := 0; row < f.Len(); row++ {
+			require.Equal(t, f.At(row), result[0].Fields[i].At(row), "%s at %d", f.Name, row)
+		}
+	}
+
+	t.Run("should fail on an unsupported calculation", func(t *testing.T) {
+		_, err := Apply(data.Frames{frame}, []Config{transformation(t, GroupByID, map[string]any{
+			"fields": map[string]any{
+				"value": map[string]any{"operation": "aggregate", "aggregations": []string{"variance"}},
+			},
+		})})
+		require.Error(t, err)
+	})
+}
+
+func TestJoinByField(t *testing.T) {
+	t1, t2, t3 := time.Unix(10, 0), time.Unix(20, 0), time.Unix(30, 0)
+	frames := data.Frames{
+		data.NewFrame("", data.NewField("time", nil, []time.Time{t2, t1}), data.NewField("a", nil, []float64{2, 1})),
+		data.NewFrame("", data.NewField("time", nil, []time.Time{t2, t3}), data.NewField("b", nil, []float64{20, 30})),
+	}
+
+	t.Run("outer", func(t *testing.T) {
+		result, err := Apply(frames, []Config{transformation(t, JoinByFieldID, map[string]any{})})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, []string{"time", "a", "b"}, fieldNames(result[0]))
+		require.Equal(t, []any{t1, t2, t3}, []any{result[0].Fields[0].At(0), result[0].Fields[0].At(1), result[0].Fields[0].At(2)})
+		require.Equal(t, []*float64{ptr(1.0), ptr(2.0), nil}, []*float64{
+			result[0].Fields[1].At(0).(*float64), result[0].Fields[1].At(1).(*float64), result[0].Fields[1].At(2).(*float64),
+		})
+		require.Equal(t, []*float64{nil, ptr(20.0), ptr(30.0)}, []*float64{
+			result[0].Fields[2].At(0).(*float64), result[0].Fields[2].At(1).(*float64), result[0].Fields[2].At(2).(*float64),
+		})
+	})
+
+	t.Run("inner", func(t *testing.T) {
+		result, err := Apply(frames, []Config{transformation(t, JoinByFieldID, map[string]any{"mode": "inner", "byField": "time"})})
+		require.NoError(t, err)
+		require.Equal(t, 1, result[0].Fields[0].Len())
+		require.Equal(t, t2, result[0].Fields[0].At(0))
+		require.Equal(t, ptr(2.0), result[0].Fields[1].At(0))
+		require.Equal(t, ptr(20.0), result[0].Fields[2].At(0))
+	})
+}
+
+func TestFilterByValue(t *testing.T) {
+	frame := data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b", "c"}),
+		data.NewField("value", nil, []*float64{ptr(1.0), nil, ptr(5.0)}),
+	)
+	testCases := []struct {
+		name     string
+		options  map[string]any
+		expected []string
+	}{
+		{
+			name: "include greater",
+			options: map[string]any{"type": "include", "match": "any", "filters": []any{
+				map[string]any{"fieldName": "value", "config": map[string]any{"id": "greater", "options": map[string]any{"value": 2}}},
+			}},
+			expected: []string{"c"},
+		},
+		{
+			name: "exclude null",
+			options: map[string]any{"type": "exclude", "match": "any", "filters": []any{
+				map[string]any{"fieldName": "value", "config": map[string]any{"id": "isNull"}},
+			}},
+			expected: []string{"a", "c"},
+		},
+		{
+			name: "include all",
+			options: map[string]any{"type": "include", "match": "all", "filters": []any{
+				map[string]any{"fieldName": "value", "config": map[string]any{"id": "isNotNull"}},
+				map[string]any{"fieldName": "host", "config": map[string]any{"id": "regex", "options": map[string]any{"value": "^[ab]$"}}},
+			}},
+			expected: []string{"a"},
+		},
+		{
+			name: "include any",
+			options: map[string]any{"type": "include", "match": "any", "filters": []any{
+				map[string]any{"fieldName": "value", "config": map[string]any{"id": "between", "options": map[string]any{"from": 4, "to": 6}}},
+				map[string]any{"fieldName": "host", "config": map[string]any{"id": "equal", "options": map[string]any{"value": "b"}}},
+			}},
+			expected: []string{"b", "c"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Apply(data.Frames{frame}, []Config{transformation(t, FilterByValueID, tc.options)})
+			require.NoError(t, err)
+			hosts := make([]string, 0, result[0].Fields[0].Len())
+			for i := 0; i < result[0].Fields[0].Len(); i++ {
+				hosts = append(hosts, result[0].Fields[0].At(i).(string))
+			}
+			require.Equal(t, tc.expected, hosts)
+		})
+	}
+}
+
+func TestApplyToResponse(t *testing.T) {
+	resp := &backend.QueryDataResponse{Responses: backend.Responses{
+		"A": {Frames: data.Frames{data.NewFrame("", data.NewField("time", nil, []time.Time{time.Unix(10, 0)}), data.NewField("a", nil, []float64{1}))}},
+		"B": {Frames: data.Frames{data.NewFrame("", data.NewField("time", nil, []time.Time{time.Unix(10, 0)}), data.NewField("b", nil, []float64{2}))}},
+	}}
+	err := ApplyToResponse(resp, []string{"A", "B"}, []Config{transformation(t, JoinByFieldID, map[string]any{})})
+	require.NoError(t, err)
+	require.Len(t, resp.Responses["A"].Frames, 1)
+	require.Equal(t, []string{"time", "a", "b"}, fieldNames(resp.Responses["A"].Frames[0]))
+	require.Empty(t, resp.Responses["B"].Frames)
+}
@@ -48,11 +48,20 @@ type SignedInUser struct {
 	IDToken           string                                       `json:"-" xorm:"-"`
 	IDTokenClaims     *authnlib.Claims[authnlib.IDTokenClaims]     `json:"-" xorm:"-"`
 	AccessTokenClaims *authnlib.Claims[authnlib.AccessTokenClaims] `json:"-" xorm:"-"`
+	// Impersonator is set when the session of the user was started by another user impersonating them.
+	Impersonator *Impersonator `json:"-" xorm:"-"`
 
 	// When other settings are not deterministic, this value is used
 	FallbackType claims.IdentityType
 }
 
+// Impersonator is the user acting on behalf of a signed in user during an impersonation session
+type Impersonator struct {
+	UserID     int64
+	Login      string
+	SessionUID string
+}
+
 func (u *SignedInUser) GetID() string {
 	ns, id := u.getTypeAndID()
 	return claims.NewTypeID(ns, id)
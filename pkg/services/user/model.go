@@ -5,6 +5,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 type HelpFlags1 uint64
@@ -117,9 +118,11 @@ type SearchUsersQuery struct {
 	Query        string
 	Page         int
 	Limit        int
-	AuthModule   string
-	SortOpts     []model.SortOption
-	Filters      []Filter
+	// After selects the users sorted after the cursor instead of the page
+	After      *pagination.Cursor
+	AuthModule string
+	SortOpts   []model.SortOption
+	Filters    []Filter
 
 	IsDisabled    *bool
 	IsProvisioned *bool
@@ -130,6 +133,8 @@ type SearchUserQueryResult struct {
 	Users      []*UserSearchHitDTO `json:"users"`
 	Page       int                 `json:"page"`
 	PerPage    int                 `json:"perPage"`
+	// Next is the cursor of the next page, nil on the last page
+	Next *pagination.Cursor `json:"-"`
 }
 
 type UserSearchHitDTO struct {
@@ -11,10 +11,13 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/searchusers/sortopts"
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/pagination"
 )
 
 type store interface {
@@ -552,26 +555,47 @@ func (ss *sqlStore) Search(ctx context.Context, query *user.SearchUsersQuery) (*
 			}
 		}
 
+		keys, keysetSort := searchSortKeys(query.SortOpts)
+		if query.After != nil {
+			if !keysetSort {
+				return pagination.ErrUnsupportedSort.Errorf("users can't be paged with a cursor in this sort order")
+			}
+			where, args, err := pagination.Where(keys, query.After)
+			if err != nil {
+				return err
+			}
+			sess.Where(where, args...)
+		}
+
 		if query.Limit > 0 {
-			offset := query.Limit * (query.Page - 1)
+			offset := 0
+			if query.After == nil {
+				offset = query.Limit * (query.Page - 1)
+			}
 			sess.Limit(query.Limit, offset)
 		}
 
 		sess.Cols("u.id", "u.uid", "u.email", "u.name", "u.login", "u.is_admin", "u.is_disabled", "u.last_seen_at", "user_auth.auth_module", "u.is_provisioned")
 
-		if len(query.SortOpts) > 0 {
+		if keysetSort {
+			sess.OrderBy(pagination.OrderBy(keys))
+		} else {
 			for i := range query.SortOpts {
 				for j := range query.SortOpts[i].Filter {
 					sess.OrderBy(query.SortOpts[i].Filter[j].OrderBy())
 				}
 			}
-		} else {
-			sess.Asc("u.login", "u.email")
+			sess.Asc("u.id")
 		}
 
 		if err := sess.Find(&result.Users); err != nil {
 			return err
 		}
+		if keysetSort {
+			result.Next = pagination.Next(len(result.Users), query.Limit, func() []any {
+				return searchSortValues(keys, result.Users[len(result.Users)-1])
+			})
+		}
 
 		// get total
 		user := user.User{}
@@ -610,6 +634,51 @@ func (ss *sqlStore) Search(ctx context.Context, query *user.SearchUsersQuery) (*
 	return &result, err
 }
 
+// searchSortKeys returns the keys the users are sorted by, ending with their id so that the order is stable. The users
+// can only be paged with a cursor when they're sorted by their columns.
+func searchSortKeys(sortOpts []model.SortOption) ([]pagination.Key, bool) {
+	if len(sortOpts) == 0 {
+		return []pagination.Key{{Column: "u.login"}, {Column: "u.email"}, {Column: "u.id"}}, true
+	}
+
+	keys := make([]pagination.Key, 0, len(sortOpts)+1)
+	for _, opt := range sortOpts {
+		for _, filter := range opt.Filter {
+			sorter, ok := filter.(sortopts.Sorter)
+			if !ok {
+				return nil, false
+			}
+			key := pagination.Key{Column: "u." + sorter.Field, Desc: sorter.Descending, Time: sorter.Field == "last_seen_at"}
+			if sorter.Field == "name" {
+				// the name is optional, rows with a NULL name wouldn't be compared to the cursor
+				key.Column = "COALESCE(u.name, '')"
+			}
+			keys = append(keys, key)
+		}
+	}
+	return append(keys, pagination.Key{Column: "u.id"}), true
+}
+
+// searchSortValues returns the values of the sort keys of the user
+func searchSortValues(keys []pagination.Key, hit *user.UserSearchHitDTO) []any {
+	values := make([]any, 0, len(keys))
+	for _, key := range keys {
+		switch key.Column {
+		case "u.login":
+			values = append(values, hit.Login)
+		case "u.email":
+			values = append(values, hit.Email)
+		case "COALESCE(u.name, '')":
+			values = append(values, hit.Name)
+		case "u.last_seen_at":
+			values = append(values, hit.LastSeenAt)
+		case "u.id":
+			values = append(values, hit.ID)
+		}
+	}
+	return values
+}
+
 func setOptional[T any](v *T, add func(v T)) {
 	if v != nil {
 		add(*v)
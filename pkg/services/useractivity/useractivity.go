@@ -0,0 +1,75 @@
+package useractivity
+
+import (
+	"context"
+	"time"
+
+	claims "github.com/grafana/authlib/types"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+var ErrInvalidSearch = errutil.BadRequest("useractivity.invalid-search")
+
+// Types of activities
+const (
+	TypeDashboardView = "dashboard_view"
+	TypeDashboardEdit = "dashboard_edit"
+	TypeAlertAck      = "alert_ack"
+	TypeExploreQuery  = "explore_query"
+)
+
+// Types are the types of activities which are recorded
+var Types = []string{TypeDashboardView, TypeDashboardEdit, TypeAlertAck, TypeExploreQuery}
+
+type Service interface {
+	// Record queues the activity to be stored, it doesn't wait for it. Activities are dropped when the feed is
+	// disabled, when the user paused it or when its buffer is full.
+	Record(ctx context.Context, activity *Activity)
+	// Search returns the activities of a user, the most recent first.
+	Search(ctx context.Context, query *SearchQuery) ([]*Activity, error)
+	// Clear deletes the activities of a user.
+	Clear(ctx context.Context, orgID, userID int64) error
+	GetSettings(ctx context.Context, orgID, userID int64) (*Settings, error)
+	UpdateSettings(ctx context.Context, orgID, userID int64, settings *Settings) error
+}
+
+// Activity is an action of a user. The views of a dashboard are kept as a single activity, the most recent one, which
+// makes the dashboard views the recently viewed dashboards of the user.
+type Activity struct {
+	ID        int64     `json:"id"`
+	OrgID     int64     `json:"-"`
+	UserID    int64     `json:"-"`
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	// ResourceUID is the UID of the dashboard, the ID of the silence acknowledging alerts or the UID of the data
+	// source queried in Explore
+	ResourceUID string `json:"resourceUid"`
+	Title       string `json:"title"`
+	URL         string `json:"url,omitempty"`
+}
+
+type SearchQuery struct {
+	OrgID  int64
+	UserID int64
+	// Types filters the activities by type when set
+	Types []string
+	Limit int
+}
+
+type Settings struct {
+	// Paused stops recording the activities of the user, the recorded activities are kept
+	Paused bool `json:"paused"`
+}
+
+// RecordRequest records the activity of the signed in user of the request. The activities of service accounts, of
+// anonymous users and of impersonated users aren't recorded.
+func RecordRequest(s Service, c *contextmodel.ReqContext, activity *Activity) {
+	if s == nil || c.SignedInUser == nil || !c.IsIdentityType(claims.TypeUser) || c.Impersonator != nil {
+		return
+	}
+	activity.OrgID = c.GetOrgID()
+	activity.UserID = c.SignedInUser.UserID
+	s.Record(c.Req.Context(), activity)
+}
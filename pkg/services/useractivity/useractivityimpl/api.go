@@ -0,0 +1,131 @@
+package useractivityimpl
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/useractivity"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister) {
+	router.Group("/api/user/activity", func(activityRoute routing.RouteRegister) {
+		activityRoute.Get("/", routing.Wrap(s.getUserActivity))
+		activityRoute.Delete("/", routing.Wrap(s.clearUserActivity))
+		activityRoute.Get("/settings", routing.Wrap(s.getUserActivitySettings))
+		activityRoute.Put("/settings", routing.Wrap(s.updateUserActivitySettings))
+	}, middleware.ReqSignedInNoAnonymous)
+}
+
+// swagger:route GET /user/activity signed_in_user getUserActivity
+//
+// # Get the activity of the signed in user
+//
+// Returns the dashboards viewed and edited, the alerts acknowledged and the Explore queries of the signed in user,
+// the most recent first. A dashboard viewed several times is returned once, with its most recent view.
+//
+// Responses:
+// 200: getUserActivityResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *Service) getUserActivity(c *contextmodel.ReqContext) response.Response {
+	activities, err := s.Search(c.Req.Context(), &useractivity.SearchQuery{
+		OrgID:  c.GetOrgID(),
+		UserID: c.SignedInUser.UserID,
+		Types:  c.QueryStrings("type"),
+		Limit:  c.QueryInt("limit"),
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get the user activity", err)
+	}
+	return response.JSON(http.StatusOK, activities)
+}
+
+// swagger:route DELETE /user/activity signed_in_user clearUserActivity
+//
+// # Clear the activity of the signed in user
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *Service) clearUserActivity(c *contextmodel.ReqContext) response.Response {
+	if err := s.Clear(c.Req.Context(), c.GetOrgID(), c.SignedInUser.UserID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to clear the user activity", err)
+	}
+	return response.Success("User activity cleared")
+}
+
+// swagger:route GET /user/activity/settings signed_in_user getUserActivitySettings
+//
+// # Get the activity settings of the signed in user
+//
+// Responses:
+// 200: userActivitySettingsResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *Service) getUserActivitySettings(c *contextmodel.ReqContext) response.Response {
+	settings, err := s.GetSettings(c.Req.Context(), c.GetOrgID(), c.SignedInUser.UserID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get the user activity settings", err)
+	}
+	return response.JSON(http.StatusOK, settings)
+}
+
+// swagger:route PUT /user/activity/settings signed_in_user updateUserActivitySettings
+//
+// # Update the activity settings of the signed in user
+//
+// Pausing the activity stops recording the activities of the signed in user, the recorded activities are kept until
+// they are cleared or expire.
+//
+// Responses:
+// 200: userActivitySettingsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *Service) updateUserActivitySettings(c *contextmodel.ReqContext) response.Response {
+	settings := &useractivity.Settings{}
+	if err := web.Bind(c.Req, settings); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if err := s.UpdateSettings(c.Req.Context(), c.GetOrgID(), c.SignedInUser.UserID, settings); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update the user activity settings", err)
+	}
+	return response.JSON(http.StatusOK, settings)
+}
+
+// swagger:parameters getUserActivity
+type GetUserActivityParams struct {
+	// Types of the activities: dashboard_view, dashboard_edit, alert_ack or explore_query
+	// in:query
+	// required:false
+	Type []string `json:"type"`
+	// in:query
+	// required:false
+	// default:50
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters updateUserActivitySettings
+type UpdateUserActivitySettingsParams struct {
+	// in:body
+	// required:true
+	Body useractivity.Settings `json:"body"`
+}
+
+// swagger:response getUserActivityResponse
+type GetUserActivityResponse struct {
+	// in: body
+	Body []*useractivity.Activity `json:"body"`
+}
+
+// swagger:response userActivitySettingsResponse
+type UserActivitySettingsResponse struct {
+	// in: body
+	Body useractivity.Settings `json:"body"`
+}
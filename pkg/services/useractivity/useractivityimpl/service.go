@@ -0,0 +1,216 @@
+package useractivityimpl
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/useractivity"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var _ useractivity.Service = (*Service)(nil)
+
+const (
+	batchSize         = 100
+	flushInterval     = time.Second
+	retentionInterval = time.Hour
+
+	defaultLimit   = 50
+	maxLimit       = 500
+	maxTitleLength = 255
+
+	kvNamespace = "user-activity"
+)
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, kv kvstore.KVStore, router routing.RouteRegister) *Service {
+	s := &Service{
+		cfg:        cfg.UserActivity,
+		store:      &xormStore{db: sqlStore},
+		kv:         kv,
+		activities: make(chan *useractivity.Activity, cfg.UserActivity.BufferSize),
+		log:        log.New("useractivity"),
+		now:        time.Now,
+	}
+
+	if cfg.UserActivity.Enabled {
+		s.registerRoutes(router)
+	}
+
+	return s
+}
+
+// Service stores the activities of the users in the database.
+// Activities are written in batches by Run so recording them never blocks a request.
+type Service struct {
+	cfg        setting.UserActivitySettings
+	store      store
+	kv         kvstore.KVStore
+	activities chan *useractivity.Activity
+	log        log.Logger
+	now        func() time.Time
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Enabled
+}
+
+func (s *Service) Record(ctx context.Context, activity *useractivity.Activity) {
+	if !s.cfg.Enabled || activity.UserID <= 0 {
+		return
+	}
+	if activity.Timestamp.IsZero() {
+		activity.Timestamp = s.now()
+	}
+	if runes := []rune(activity.Title); len(runes) > maxTitleLength {
+		activity.Title = string(runes[:maxTitleLength])
+	}
+
+	select {
+	case s.activities <- activity:
+	default:
+		s.log.FromContext(ctx).Debug("User activity buffer is full, dropping activity", "type", activity.Type, "userId", activity.UserID)
+	}
+}
+
+func (s *Service) Search(ctx context.Context, query *useractivity.SearchQuery) ([]*useractivity.Activity, error) {
+	for _, activityType := range query.Types {
+		if !slices.Contains(useractivity.Types, activityType) {
+			return nil, useractivity.ErrInvalidSearch.Errorf("unknown activity type %q", activityType)
+		}
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultLimit
+	}
+	if query.Limit > maxLimit {
+		query.Limit = maxLimit
+	}
+	return s.store.Search(ctx, query)
+}
+
+func (s *Service) Clear(ctx context.Context, orgID, userID int64) error {
+	return s.store.Delete(ctx, orgID, userID)
+}
+
+func (s *Service) GetSettings(ctx context.Context, orgID, userID int64) (*useractivity.Settings, error) {
+	settings := &useractivity.Settings{}
+	value, ok, err := s.kv.Get(ctx, orgID, kvNamespace, strconv.FormatInt(userID, 10))
+	if err != nil || !ok {
+		return settings, err
+	}
+	if err := json.Unmarshal([]byte(value), settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (s *Service) UpdateSettings(ctx context.Context, orgID, userID int64, settings *useractivity.Settings) error {
+	value, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, orgID, kvNamespace, strconv.FormatInt(userID, 10), string(value))
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	flush := time.NewTicker(flushInterval)
+	defer flush.Stop()
+	retention := time.NewTicker(retentionInterval)
+	defer retention.Stop()
+
+	s.deleteExpired(ctx)
+
+	batch := make([]*useractivity.Activity, 0, batchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			// the activities still in the buffer are written with a fresh context, the one of the server is already canceled
+			batch = s.drain(batch)
+			flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			s.write(flushCtx, batch)
+			cancel()
+			return ctx.Err()
+		case activity := <-s.activities:
+			batch = append(batch, activity)
+			if len(batch) >= batchSize {
+				s.write(ctx, batch)
+				batch = make([]*useractivity.Activity, 0, batchSize)
+			}
+		case <-flush.C:
+			if len(batch) > 0 {
+				s.write(ctx, batch)
+				batch = make([]*useractivity.Activity, 0, batchSize)
+			}
+		case <-retention.C:
+			s.deleteExpired(ctx)
+		}
+	}
+}
+
+func (s *Service) drain(batch []*useractivity.Activity) []*useractivity.Activity {
+	for {
+		select {
+		case activity := <-s.activities:
+			batch = append(batch, activity)
+		default:
+			return batch
+		}
+	}
+}
+
+// write stores the activities of the users who didn't pause their activity feed
+func (s *Service) write(ctx context.Context, activities []*useractivity.Activity) {
+	type userKey struct{ orgID, userID int64 }
+	paused := map[userKey]bool{}
+
+	recorded := make([]*useractivity.Activity, 0, len(activities))
+	for _, activity := range activities {
+		key := userKey{orgID: activity.OrgID, userID: activity.UserID}
+		isPaused, ok := paused[key]
+		if !ok {
+			settings, err := s.GetSettings(ctx, activity.OrgID, activity.UserID)
+			if err != nil {
+				s.log.Warn("Failed to get the activity settings of the user", "userId", activity.UserID, "error", err)
+				settings = &useractivity.Settings{}
+			}
+			isPaused = settings.Paused
+			paused[key] = isPaused
+		}
+		if !isPaused {
+			recorded = append(recorded, activity)
+		}
+	}
+	if len(recorded) == 0 {
+		return
+	}
+
+	if err := s.store.Insert(ctx, recorded); err != nil {
+		s.log.Error("Failed to store user activities", "count", len(recorded), "error", err)
+	}
+}
+
+func (s *Service) deleteExpired(ctx context.Context) {
+	if s.cfg.Retention > 0 {
+		deleted, err := s.store.DeleteBefore(ctx, s.now().Add(-s.cfg.Retention))
+		if err != nil {
+			s.log.Error("Failed to delete expired user activities", "error", err)
+		} else if deleted > 0 {
+			s.log.Debug("Deleted expired user activities", "count", deleted)
+		}
+	}
+
+	if s.cfg.MaxPerUser > 0 {
+		deleted, err := s.store.DeleteOverLimit(ctx, s.cfg.MaxPerUser)
+		if err != nil {
+			s.log.Error("Failed to delete the user activities over the limit", "error", err)
+		} else if deleted > 0 {
+			s.log.Debug("Deleted the user activities over the limit", "count", deleted)
+		}
+	}
+}
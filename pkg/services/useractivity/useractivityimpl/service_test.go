@@ -0,0 +1,185 @@
+package useractivityimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/useractivity"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestService_Record(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should drop activities when the buffer is full", func(t *testing.T) {
+		s, _ := setupTestService(t, setting.UserActivitySettings{Enabled: true, BufferSize: 1})
+
+		s.Record(ctx, &useractivity.Activity{UserID: 1, Type: useractivity.TypeDashboardView})
+		s.Record(ctx, &useractivity.Activity{UserID: 1, Type: useractivity.TypeDashboardView})
+
+		assert.Len(t, s.activities, 1)
+	})
+
+	t.Run("should not record activities when disabled", func(t *testing.T) {
+		s, _ := setupTestService(t, setting.UserActivitySettings{Enabled: false, BufferSize: 1})
+
+		s.Record(ctx, &useractivity.Activity{UserID: 1, Type: useractivity.TypeDashboardView})
+
+		assert.Empty(t, s.activities)
+	})
+
+	t.Run("should not record activities without user", func(t *testing.T) {
+		s, _ := setupTestService(t, setting.UserActivitySettings{Enabled: true, BufferSize: 1})
+
+		s.Record(ctx, &useractivity.Activity{Type: useractivity.TypeDashboardView})
+
+		assert.Empty(t, s.activities)
+	})
+}
+
+func TestService_Run(t *testing.T) {
+	t.Run("should write buffered activities on shutdown, except the ones of paused users", func(t *testing.T) {
+		s, store := setupTestService(t, setting.UserActivitySettings{Enabled: true, BufferSize: 10})
+		require.NoError(t, s.UpdateSettings(context.Background(), 1, 2, &useractivity.Settings{Paused: true}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		s.Record(ctx, &useractivity.Activity{OrgID: 1, UserID: 1, Type: useractivity.TypeDashboardView})
+		s.Record(ctx, &useractivity.Activity{OrgID: 1, UserID: 2, Type: useractivity.TypeDashboardView})
+		s.Record(ctx, &useractivity.Activity{OrgID: 2, UserID: 2, Type: useractivity.TypeDashboardEdit})
+
+		require.ErrorIs(t, s.Run(ctx), context.Canceled)
+
+		require.Len(t, store.activities, 2)
+		assert.Equal(t, int64(1), store.activities[0].UserID)
+		assert.Equal(t, int64(2), store.activities[1].OrgID, "the user is only paused in org 1")
+	})
+
+	t.Run("should delete activities older than the retention and over the limit", func(t *testing.T) {
+		s, store := setupTestService(t, setting.UserActivitySettings{Enabled: true, BufferSize: 10, Retention: 24 * time.Hour, MaxPerUser: 10})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.ErrorIs(t, s.Run(ctx), context.Canceled)
+
+		assert.Equal(t, s.now().Add(-24*time.Hour), store.deletedBefore)
+		assert.Equal(t, 10, store.limit)
+	})
+}
+
+func TestService_Search(t *testing.T) {
+	s, store := setupTestService(t, setting.UserActivitySettings{Enabled: true, BufferSize: 10})
+
+	_, err := s.Search(context.Background(), &useractivity.SearchQuery{OrgID: 1, UserID: 1, Types: []string{"unknown"}})
+	require.ErrorIs(t, err, useractivity.ErrInvalidSearch)
+
+	_, err = s.Search(context.Background(), &useractivity.SearchQuery{OrgID: 1, UserID: 1, Limit: 10000})
+	require.NoError(t, err)
+	assert.Equal(t, maxLimit, store.query.Limit)
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	store := &xormStore{db: db.InitTestDB(t)}
+	now := time.Now().Truncate(time.Second)
+
+	err := store.Insert(ctx, []*useractivity.Activity{
+		{OrgID: 1, UserID: 1, Timestamp: now.Add(-3 * time.Minute), Type: useractivity.TypeDashboardView, ResourceUID: "dash-1", Title: "Old title"},
+		{OrgID: 1, UserID: 1, Timestamp: now.Add(-2 * time.Minute), Type: useractivity.TypeDashboardEdit, ResourceUID: "dash-2", Title: "Dashboard 2"},
+		{OrgID: 1, UserID: 1, Timestamp: now.Add(-1 * time.Minute), Type: useractivity.TypeDashboardView, ResourceUID: "dash-1", Title: "Dashboard 1"},
+		{OrgID: 1, UserID: 2, Timestamp: now, Type: useractivity.TypeExploreQuery, ResourceUID: "ds-1", Title: "up"},
+	})
+	require.NoError(t, err)
+
+	t.Run("should keep the most recent view of a dashboard", func(t *testing.T) {
+		activities, err := store.Search(ctx, &useractivity.SearchQuery{OrgID: 1, UserID: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, activities, 2)
+		assert.Equal(t, "Dashboard 1", activities[0].Title)
+		assert.Equal(t, "dash-2", activities[1].ResourceUID)
+
+		views, err := store.Search(ctx, &useractivity.SearchQuery{OrgID: 1, UserID: 1, Types: []string{useractivity.TypeDashboardView}, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, views, 1)
+	})
+
+	t.Run("should delete the oldest activities over the limit", func(t *testing.T) {
+		deleted, err := store.DeleteOverLimit(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		activities, err := store.Search(ctx, &useractivity.SearchQuery{OrgID: 1, UserID: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, activities, 1)
+		assert.Equal(t, "dash-1", activities[0].ResourceUID)
+	})
+
+	t.Run("should clear the activities of a user", func(t *testing.T) {
+		require.NoError(t, store.Delete(ctx, 1, 1))
+
+		activities, err := store.Search(ctx, &useractivity.SearchQuery{OrgID: 1, UserID: 1, Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, activities)
+
+		activities, err = store.Search(ctx, &useractivity.SearchQuery{OrgID: 1, UserID: 2, Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, activities, 1)
+	})
+}
+
+func setupTestService(t *testing.T, settings setting.UserActivitySettings) (*Service, *fakeStore) {
+	t.Helper()
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeStore{}
+	return &Service{
+		cfg:        settings,
+		store:      store,
+		kv:         kvstore.NewFakeKVStore(),
+		activities: make(chan *useractivity.Activity, settings.BufferSize),
+		log:        log.NewNopLogger(),
+		now:        func() time.Time { return now },
+	}, store
+}
+
+type fakeStore struct {
+	activities    []*useractivity.Activity
+	query         *useractivity.SearchQuery
+	deletedBefore time.Time
+	limit         int
+}
+
+func (f *fakeStore) Insert(_ context.Context, activities []*useractivity.Activity) error {
+	f.activities = append(f.activities, activities...)
+	return nil
+}
+
+func (f *fakeStore) Search(_ context.Context, query *useractivity.SearchQuery) ([]*useractivity.Activity, error) {
+	f.query = query
+	return f.activities, nil
+}
+
+func (f *fakeStore) Delete(context.Context, int64, int64) error {
+	f.activities = nil
+	return nil
+}
+
+func (f *fakeStore) DeleteBefore(_ context.Context, before time.Time) (int64, error) {
+	f.deletedBefore = before
+	return 0, nil
+}
+
+func (f *fakeStore) DeleteOverLimit(_ context.Context, limit int) (int64, error) {
+	f.limit = limit
+	return 0, nil
+}
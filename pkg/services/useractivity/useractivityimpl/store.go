@@ -0,0 +1,164 @@
+package useractivityimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/useractivity"
+)
+
+type activityEntry struct {
+	ID          int64     `xorm:"pk autoincr 'id'"`
+	OrgID       int64     `xorm:"org_id"`
+	UserID      int64     `xorm:"user_id"`
+	Created     time.Time `xorm:"created"`
+	Type        string    `xorm:"type"`
+	ResourceUID string    `xorm:"resource_uid"`
+	Title       string    `xorm:"title"`
+	URL         string    `xorm:"url"`
+}
+
+func (activityEntry) TableName() string {
+	return "user_activity"
+}
+
+func (e *activityEntry) toActivity() *useractivity.Activity {
+	return &useractivity.Activity{
+		ID:          e.ID,
+		OrgID:       e.OrgID,
+		UserID:      e.UserID,
+		Timestamp:   e.Created,
+		Type:        e.Type,
+		ResourceUID: e.ResourceUID,
+		Title:       e.Title,
+		URL:         e.URL,
+	}
+}
+
+type store interface {
+	// Insert stores the activities, the view of a dashboard already viewed by the user replaces the previous view
+	Insert(ctx context.Context, activities []*useractivity.Activity) error
+	Search(ctx context.Context, query *useractivity.SearchQuery) ([]*useractivity.Activity, error)
+	Delete(ctx context.Context, orgID, userID int64) error
+	// DeleteBefore deletes the activities recorded before the time and returns how many were deleted
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+	// DeleteOverLimit deletes the oldest activities of the users who have more than limit activities and returns how
+	// many were deleted
+	DeleteOverLimit(ctx context.Context, limit int) (int64, error)
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (s *xormStore) Insert(ctx context.Context, activities []*useractivity.Activity) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for _, activity := range activities {
+			entry := &activityEntry{
+				OrgID:       activity.OrgID,
+				UserID:      activity.UserID,
+				Created:     activity.Timestamp,
+				Type:        activity.Type,
+				ResourceUID: activity.ResourceUID,
+				Title:       activity.Title,
+				URL:         activity.URL,
+			}
+
+			if activity.Type == useractivity.TypeDashboardView {
+				updated, err := sess.Where("org_id = ? AND user_id = ? AND type = ? AND resource_uid = ?",
+					entry.OrgID, entry.UserID, entry.Type, entry.ResourceUID).Cols("created", "title", "url").Update(entry)
+				if err != nil {
+					return err
+				}
+				if updated > 0 {
+					continue
+				}
+			}
+
+			if _, err := sess.Insert(entry); err != nil {
+				return err
+			}
+			activity.ID = entry.ID
+		}
+		return nil
+	})
+}
+
+func (s *xormStore) Search(ctx context.Context, query *useractivity.SearchQuery) ([]*useractivity.Activity, error) {
+	entries := make([]*activityEntry, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Where("org_id = ? AND user_id = ?", query.OrgID, query.UserID)
+		if len(query.Types) > 0 {
+			q = q.In("type", query.Types)
+		}
+		return q.Desc("created", "id").Limit(query.Limit).Find(&entries)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	activities := make([]*useractivity.Activity, 0, len(entries))
+	for _, entry := range entries {
+		activities = append(activities, entry.toActivity())
+	}
+	return activities, nil
+}
+
+func (s *xormStore) Delete(ctx context.Context, orgID, userID int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM user_activity WHERE org_id = ? AND user_id = ?", orgID, userID)
+		return err
+	})
+}
+
+func (s *xormStore) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM user_activity WHERE created < ?", before)
+		if err != nil {
+			return err
+		}
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	return deleted, err
+}
+
+func (s *xormStore) DeleteOverLimit(ctx context.Context, limit int) (int64, error) {
+	var deleted int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var users []struct {
+			OrgID  int64 `xorm:"org_id"`
+			UserID int64 `xorm:"user_id"`
+		}
+		if err := sess.SQL("SELECT org_id, user_id FROM user_activity GROUP BY org_id, user_id HAVING COUNT(*) > ?", limit).Find(&users); err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			// the most recent activity over the limit, it's deleted with the older ones
+			var oldest activityEntry
+			has, err := sess.Where("org_id = ? AND user_id = ?", user.OrgID, user.UserID).Desc("created", "id").Limit(1, limit).Get(&oldest)
+			if err != nil {
+				return err
+			}
+			if !has {
+				continue
+			}
+
+			res, err := sess.Exec("DELETE FROM user_activity WHERE org_id = ? AND user_id = ? AND (created < ? OR (created = ? AND id <= ?))",
+				user.OrgID, user.UserID, oldest.Created, oldest.Created, oldest.ID)
+			if err != nil {
+				return err
+			}
+			count, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			deleted += count
+		}
+		return nil
+	})
+	return deleted, err
+}
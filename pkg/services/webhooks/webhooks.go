@@ -0,0 +1,184 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+var (
+	ErrWebhookNotFound = errutil.NotFound(
+		"webhooks.not-found", errutil.WithPublicMessage("Webhook not found"))
+	ErrDeliveryNotFound = errutil.NotFound(
+		"webhooks.delivery-not-found", errutil.WithPublicMessage("Webhook delivery not found"))
+	ErrInvalidName   = errutil.BadRequest("webhooks.invalid-name")
+	ErrInvalidURL    = errutil.BadRequest("webhooks.invalid-url")
+	ErrInvalidEvents = errutil.BadRequest("webhooks.invalid-events")
+	ErrInvalidSearch = errutil.BadRequest("webhooks.invalid-search")
+	// ErrLimitReached is returned when an organization creates more webhooks than the configured limit
+	ErrLimitReached = errutil.BadRequest("webhooks.limit-reached")
+)
+
+const (
+	ActionRead  = "webhooks:read"
+	ActionWrite = "webhooks:write"
+)
+
+// Events the webhooks can subscribe to
+const (
+	EventDashboardSaved    = "dashboard.saved"
+	EventDashboardDeleted  = "dashboard.deleted"
+	EventAlertRuleSaved    = "alertrule.saved"
+	EventAlertRuleDeleted  = "alertrule.deleted"
+	EventDataSourceCreated = "datasource.created"
+	EventDataSourceDeleted = "datasource.deleted"
+	EventUserAdded         = "user.added"
+	// EventQuotaSoftLimitReached is sent when the usage of an organization quota reaches its soft limit
+	EventQuotaSoftLimitReached = "quota.soft_limit_reached"
+)
+
+// EventTypes are the events the webhooks can subscribe to
+var EventTypes = []string{
+	EventDashboardSaved,
+	EventDashboardDeleted,
+	EventAlertRuleSaved,
+	EventAlertRuleDeleted,
+	EventDataSourceCreated,
+	EventDataSourceDeleted,
+	EventUserAdded,
+	EventQuotaSoftLimitReached,
+}
+
+// Delivery statuses
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+type Service interface {
+	// CreateWebhook creates a webhook in the organization of the command. The secret signing the deliveries is
+	// generated when the command has none, it's only returned when the webhook is created.
+	CreateWebhook(ctx context.Context, cmd *CreateWebhookCommand) (*CreateWebhookResult, error)
+	UpdateWebhook(ctx context.Context, cmd *UpdateWebhookCommand) (*Webhook, error)
+	DeleteWebhook(ctx context.Context, orgID int64, uid string) error
+	GetWebhook(ctx context.Context, orgID int64, uid string) (*Webhook, error)
+	ListWebhooks(ctx context.Context, orgID int64) ([]*Webhook, error)
+	SearchDeliveries(ctx context.Context, query *SearchDeliveriesQuery) (*SearchDeliveriesResult, error)
+	// GetDelivery returns the delivery with its payload
+	GetDelivery(ctx context.Context, orgID int64, webhookUID string, deliveryID int64) (*Delivery, error)
+	// Redeliver schedules the delivery to be sent again, with its attempts reset
+	Redeliver(ctx context.Context, orgID int64, webhookUID string, deliveryID int64) (*Delivery, error)
+}
+
+type Webhook struct {
+	ID      int64     `json:"-"`
+	OrgID   int64     `json:"orgId"`
+	UID     string    `json:"uid"`
+	Name    string    `json:"name"`
+	URL     string    `json:"url"`
+	Events  []string  `json:"events"`
+	Filter  Filter    `json:"filter"`
+	Enabled bool      `json:"enabled"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// Filter narrows the events of a webhook, an empty filter matches every event
+type Filter struct {
+	// FolderUIDs matches the dashboards and alert rules of the folders
+	FolderUIDs []string `json:"folderUids,omitempty"`
+	// ResourceUIDs matches the dashboards, alert rules and data sources by uid
+	ResourceUIDs []string `json:"resourceUids,omitempty"`
+}
+
+// Matches returns true when the event is in the folders and of the resources of the filter
+func (f Filter) Matches(event *Event) bool {
+	if len(f.FolderUIDs) > 0 && !slices.Contains(f.FolderUIDs, event.FolderUID) {
+		return false
+	}
+	if len(f.ResourceUIDs) > 0 && !slices.Contains(f.ResourceUIDs, event.ResourceUID) {
+		return false
+	}
+	return true
+}
+
+// Event is a change of a resource, sent as the body of the deliveries
+type Event struct {
+	// ID identifies the event, the deliveries of the same event to several webhooks have the same id
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	OrgID       int64           `json:"orgId"`
+	Timestamp   time.Time       `json:"timestamp"`
+	ResourceUID string          `json:"resourceUid,omitempty"`
+	FolderUID   string          `json:"folderUid,omitempty"`
+	Data        json.RawMessage `json:"data"`
+}
+
+type CreateWebhookCommand struct {
+	OrgID  int64    `json:"-"`
+	Name   string   `json:"name"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Filter Filter   `json:"filter"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled"`
+	// Secret signs the deliveries, a random secret is generated when empty
+	Secret string `json:"secret"`
+}
+
+type CreateWebhookResult struct {
+	*Webhook
+	// Secret is only returned when the webhook is created
+	Secret string `json:"secret"`
+}
+
+type UpdateWebhookCommand struct {
+	OrgID   int64    `json:"-"`
+	UID     string   `json:"-"`
+	Name    string   `json:"name"`
+	URL     string   `json:"url"`
+	Events  []string `json:"events"`
+	Filter  Filter   `json:"filter"`
+	Enabled bool     `json:"enabled"`
+	// Secret replaces the secret signing the deliveries when set
+	Secret string `json:"secret"`
+}
+
+type Delivery struct {
+	ID         int64  `json:"id"`
+	OrgID      int64  `json:"orgId"`
+	WebhookUID string `json:"webhookUid"`
+	EventID    string `json:"eventId"`
+	EventType  string `json:"eventType"`
+	Status     string `json:"status"`
+	// Attempts is the number of requests sent
+	Attempts int `json:"attempts"`
+	// ResponseCode is the status code of the last response, 0 when the request failed
+	ResponseCode int        `json:"responseCode"`
+	LastError    string     `json:"lastError,omitempty"`
+	NextAttempt  *time.Time `json:"nextAttempt,omitempty"`
+	Created      time.Time  `json:"created"`
+	Updated      time.Time  `json:"updated"`
+	// Payload is the body of the requests, only returned when a single delivery is requested
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type SearchDeliveriesQuery struct {
+	OrgID      int64
+	WebhookUID string
+	Status     string
+	EventType  string
+	Page       int
+	Limit      int
+}
+
+type SearchDeliveriesResult struct {
+	TotalCount int64       `json:"totalCount"`
+	Deliveries []*Delivery `json:"deliveries"`
+	Page       int         `json:"page"`
+	PerPage    int         `json:"perPage"`
+}
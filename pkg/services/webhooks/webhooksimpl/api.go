@@ -0,0 +1,176 @@
+package webhooksimpl
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/openapi"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/webhooks"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerRoutes(router routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+	read := authorize(ac.EvalPermission(webhooks.ActionRead))
+	write := authorize(ac.EvalPermission(webhooks.ActionWrite))
+	validationErrors := []errutil.Base{webhooks.ErrInvalidName, webhooks.ErrInvalidURL, webhooks.ErrInvalidEvents}
+
+	router.Group("/api/webhooks", func(webhookRoute routing.RouteRegister) {
+		webhookRoute.Get("/", read, openapi.Describe(openapi.Operation{
+			Summary:  "Get the webhooks of the current organization.",
+			Response: []*webhooks.Webhook{},
+		}, routing.Wrap(s.listWebhooks)))
+		webhookRoute.Post("/", write, openapi.Describe(openapi.Operation{
+			Summary: "Create a webhook.",
+			Description: "The webhook receives a signed POST request for each event it subscribes to and its filter matches. " +
+				"The secret signing the requests is generated when none is given, it's only returned in this response.",
+			Request:  webhooks.CreateWebhookCommand{},
+			Response: webhooks.CreateWebhookResult{},
+			Errors:   append(validationErrors, webhooks.ErrLimitReached),
+		}, routing.Wrap(s.createWebhook)))
+		webhookRoute.Get("/:uid", read, openapi.Describe(openapi.Operation{
+			Summary:  "Get a webhook.",
+			Response: webhooks.Webhook{},
+			Errors:   []errutil.Base{webhooks.ErrWebhookNotFound},
+		}, routing.Wrap(s.getWebhook)))
+		webhookRoute.Put("/:uid", write, openapi.Describe(openapi.Operation{
+			Summary:     "Update a webhook.",
+			Description: "The secret is kept when none is given.",
+			Request:     webhooks.UpdateWebhookCommand{},
+			Response:    webhooks.Webhook{},
+			Errors:      append(validationErrors, webhooks.ErrWebhookNotFound),
+		}, routing.Wrap(s.updateWebhook)))
+		webhookRoute.Delete("/:uid", write, openapi.Describe(openapi.Operation{
+			Summary:     "Delete a webhook.",
+			Description: "The deliveries of the webhook are deleted with it.",
+			Errors:      []errutil.Base{webhooks.ErrWebhookNotFound},
+		}, routing.Wrap(s.deleteWebhook)))
+		webhookRoute.Get("/:uid/deliveries", read, openapi.Describe(openapi.Operation{
+			Summary:     "Search the deliveries of a webhook.",
+			Description: "Returns the deliveries matching the filters, the most recent first, without their payload.",
+			Query:       searchDeliveriesParams{},
+			Response:    webhooks.SearchDeliveriesResult{},
+			Errors:      []errutil.Base{webhooks.ErrInvalidSearch},
+		}, routing.Wrap(s.searchDeliveries)))
+		webhookRoute.Get("/:uid/deliveries/:id", read, openapi.Describe(openapi.Operation{
+			Summary:  "Get a delivery of a webhook with its payload.",
+			Response: webhooks.Delivery{},
+			Errors:   []errutil.Base{webhooks.ErrDeliveryNotFound},
+		}, routing.Wrap(s.getDelivery)))
+		webhookRoute.Post("/:uid/deliveries/:id/redeliver", write, openapi.Describe(openapi.Operation{
+			Summary:     "Redeliver a webhook delivery.",
+			Description: "Schedules the delivery to be sent again with its attempts reset, whatever its status.",
+			Response:    webhooks.Delivery{},
+			Errors:      []errutil.Base{webhooks.ErrDeliveryNotFound},
+		}, routing.Wrap(s.redeliver)))
+	}, middleware.ReqSignedIn)
+}
+
+// searchDeliveriesParams are the query parameters of the delivery search
+type searchDeliveriesParams struct {
+	Status  string `json:"status"`
+	Event   string `json:"event"`
+	Page    int    `json:"page"`
+	Perpage int    `json:"perpage"`
+}
+
+func (s *Service) listWebhooks(c *contextmodel.ReqContext) response.Response {
+	hooks, err := s.ListWebhooks(c.Req.Context(), c.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list webhooks", err)
+	}
+	return response.JSON(http.StatusOK, hooks)
+}
+
+func (s *Service) createWebhook(c *contextmodel.ReqContext) response.Response {
+	cmd := webhooks.CreateWebhookCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.GetOrgID()
+
+	result, err := s.CreateWebhook(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create webhook", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (s *Service) getWebhook(c *contextmodel.ReqContext) response.Response {
+	hook, err := s.GetWebhook(c.Req.Context(), c.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get webhook", err)
+	}
+	return response.JSON(http.StatusOK, hook)
+}
+
+func (s *Service) updateWebhook(c *contextmodel.ReqContext) response.Response {
+	cmd := webhooks.UpdateWebhookCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.GetOrgID()
+	cmd.UID = web.Params(c.Req)[":uid"]
+
+	hook, err := s.UpdateWebhook(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update webhook", err)
+	}
+	return response.JSON(http.StatusOK, hook)
+}
+
+func (s *Service) deleteWebhook(c *contextmodel.ReqContext) response.Response {
+	if err := s.DeleteWebhook(c.Req.Context(), c.GetOrgID(), web.Params(c.Req)[":uid"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete webhook", err)
+	}
+	return response.Success("Webhook deleted")
+}
+
+func (s *Service) searchDeliveries(c *contextmodel.ReqContext) response.Response {
+	query := &webhooks.SearchDeliveriesQuery{
+		OrgID:      c.GetOrgID(),
+		WebhookUID: web.Params(c.Req)[":uid"],
+		Status:     c.Query("status"),
+		EventType:  c.Query("event"),
+		Page:       c.QueryIntWithDefault("page", 1),
+		Limit:      c.QueryIntWithDefault("perpage", defaultPerPage),
+	}
+
+	result, err := s.SearchDeliveries(c.Req.Context(), query)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to search webhook deliveries", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (s *Service) getDelivery(c *contextmodel.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	delivery, err := s.GetDelivery(c.Req.Context(), c.GetOrgID(), web.Params(c.Req)[":uid"], id)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get webhook delivery", err)
+	}
+	return response.JSON(http.StatusOK, delivery)
+}
+
+func (s *Service) redeliver(c *contextmodel.ReqContext) response.Response {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	delivery, err := s.Redeliver(c.Req.Context(), c.GetOrgID(), web.Params(c.Req)[":uid"], id)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to redeliver webhook delivery", err)
+	}
+	return response.JSON(http.StatusOK, delivery)
+}
@@ -0,0 +1,39 @@
+package webhooksimpl
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "grafana"
+	metricsSubSystem = "webhooks"
+)
+
+type metrics struct {
+	deliveries *prometheus.CounterVec
+	duration   prometheus.Histogram
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		deliveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "delivery_attempts_total",
+			Help:      "Number of webhook delivery attempts by result: success, retry or failure when the delivery is given up",
+		}, []string{"result"}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "delivery_duration_seconds",
+			Help:      "Duration of the webhook delivery requests",
+			Buckets:   prometheus.DefBuckets,
+		}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.deliveries, m.duration)
+	}
+
+	return m
+}
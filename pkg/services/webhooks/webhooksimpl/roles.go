@@ -0,0 +1,45 @@
+package webhooksimpl
+
+import (
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/webhooks"
+)
+
+var (
+	webhooksReaderRole = ac.RoleDTO{
+		Name:        "fixed:webhooks:reader",
+		DisplayName: "Reader",
+		Description: "List the webhooks of the organization and their deliveries",
+		Group:       "Webhooks",
+		Permissions: []ac.Permission{
+			{Action: webhooks.ActionRead},
+		},
+	}
+
+	webhooksWriterRole = ac.RoleDTO{
+		Name:        "fixed:webhooks:writer",
+		DisplayName: "Writer",
+		Description: "Create, update and delete the webhooks of the organization and redeliver their events",
+		Group:       "Webhooks",
+		Permissions: []ac.Permission{
+			{Action: webhooks.ActionRead},
+			{Action: webhooks.ActionWrite},
+		},
+	}
+)
+
+func declareFixedRoles(service ac.Service) error {
+	grants := []string{string(org.RoleAdmin)}
+
+	webhooksReader := ac.RoleRegistration{
+		Role:   webhooksReaderRole,
+		Grants: grants,
+	}
+	webhooksWriter := ac.RoleRegistration{
+		Role:   webhooksWriterRole,
+		Grants: grants,
+	}
+
+	return service.DeclareFixedRoles(webhooksReader, webhooksWriter)
+}
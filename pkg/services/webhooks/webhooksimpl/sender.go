@@ -0,0 +1,107 @@
+package webhooksimpl
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers of the delivery requests
+const (
+	headerEvent     = "X-Grafana-Webhook-Event"
+	headerDelivery  = "X-Grafana-Webhook-Delivery"
+	headerTimestamp = "X-Grafana-Webhook-Timestamp"
+	headerSignature = "X-Grafana-Webhook-Signature"
+)
+
+// maxResponseBody bounds the part of an error response kept as the last error of the delivery
+const maxResponseBody = 512
+
+type request struct {
+	url        string
+	secret     string
+	eventType  string
+	deliveryID int64
+	payload    []byte
+}
+
+type sender interface {
+	// Send posts the payload and returns the status code of the response, or 0 when the request failed
+	Send(ctx context.Context, req *request) (int, error)
+}
+
+type httpSender struct {
+	httpClient *http.Client
+	version    string
+	now        func() time.Time
+}
+
+func newHTTPSender(timeout time.Duration, version string) *httpSender {
+	return &httpSender{
+		version: version,
+		now:     time.Now,
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					Renegotiation: tls.RenegotiateFreelyAsClient,
+				},
+				Proxy: http.ProxyFromEnvironment,
+				DialContext: (&net.Dialer{
+					Timeout:   4 * time.Second,
+					KeepAlive: 15 * time.Second,
+				}).DialContext,
+				TLSHandshakeTimeout:   10 * time.Second,
+				ExpectContinueTimeout: 1 * time.Second,
+				MaxIdleConns:          100,
+				IdleConnTimeout:       30 * time.Second,
+			},
+			Timeout: timeout,
+		},
+	}
+}
+
+func (s *httpSender) Send(ctx context.Context, r *request) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(r.payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to make http request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "grafana-webhook-client/"+s.version)
+	req.Header.Set(headerEvent, r.eventType)
+	req.Header.Set(headerDelivery, strconv.FormatInt(r.deliveryID, 10))
+	req.Header.Set(headerTimestamp, timestamp)
+	req.Header.Set(headerSignature, sign(r.secret, timestamp, r.payload))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+		return resp.StatusCode, fmt.Errorf("unexpected status code %s: %s", resp.Status, body)
+	}
+	return resp.StatusCode, nil
+}
+
+// sign returns the signature of a delivery: the hex encoded HMAC-SHA256 of the timestamp, a dot and the body, keyed
+// with the secret of the webhook. Receivers compare it and reject old timestamps to prevent replays.
+func sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(timestamp))
+	_, _ = mac.Write([]byte("."))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
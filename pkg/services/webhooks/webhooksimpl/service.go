@@ -0,0 +1,589 @@
+package webhooksimpl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/webhooks"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var _ webhooks.Service = (*Service)(nil)
+
+const (
+	// dispatchBatchSize is the number of due deliveries sent by a dispatch
+	dispatchBatchSize = 50
+	// maxRetryDelay caps the exponential backoff between two attempts of a delivery
+	maxRetryDelay = time.Hour
+	// maintenanceInterval is the interval between two deletions of the expired deliveries
+	maintenanceInterval = time.Hour
+	// maxNameLength is the size of the name column
+	maxNameLength = 190
+	// secretLength is the length of the generated secrets
+	secretLength = 32
+)
+
+func ProvideService(
+	cfg *setting.Cfg, sqlStore db.DB, bus bus.Bus, router routing.RouteRegister, accessControl ac.AccessControl,
+	acService ac.Service, secretsService secrets.Service, serverLock *serverlock.ServerLockService,
+	reg prometheus.Registerer, tracer trace.Tracer,
+) (*Service, error) {
+	s := &Service{
+		cfg:        cfg,
+		store:      &xormStore{db: sqlStore},
+		secrets:    secretsService,
+		serverLock: serverLock,
+		sender:     newHTTPSender(cfg.Webhooks.RequestTimeout, cfg.BuildVersion),
+		metrics:    newMetrics(reg),
+		trigger:    make(chan struct{}, 1),
+		log:        log.New("webhooks"),
+		tracer:     tracer,
+		now:        time.Now,
+	}
+
+	if s.IsDisabled() {
+		return s, nil
+	}
+
+	if err := declareFixedRoles(acService); err != nil {
+		return nil, err
+	}
+	s.registerRoutes(router, accessControl)
+
+	bus.AddEventListener(s.handleDashboardSaved)
+	bus.AddEventListener(s.handleDashboardDeleted)
+	bus.AddEventListener(s.handleAlertRuleSaved)
+	bus.AddEventListener(s.handleAlertRuleDeleted)
+	bus.AddEventListener(s.handleDataSourceCreated)
+	bus.AddEventListener(s.handleDataSourceDeleted)
+	bus.AddEventListener(s.handleOrgUserAdded)
+	bus.AddEventListener(s.handleQuotaSoftLimitReached)
+
+	return s, nil
+}
+
+// Service delivers the resource events to the webhooks subscribed to them. Events are stored as pending deliveries
+// when they are published, and Run sends the due deliveries, retrying the failed ones with an exponential backoff.
+// The deliveries are kept as a delivery log until they expire.
+type Service struct {
+	cfg        *setting.Cfg
+	store      store
+	secrets    secrets.Service
+	serverLock *serverlock.ServerLockService
+	sender     sender
+	metrics    *metrics
+	// trigger wakes Run up when deliveries are added
+	trigger chan struct{}
+	log     log.Logger
+	tracer  trace.Tracer
+	now     func() time.Time
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Webhooks.Enabled
+}
+
+func (s *Service) CreateWebhook(ctx context.Context, cmd *webhooks.CreateWebhookCommand) (*webhooks.CreateWebhookResult, error) {
+	ctx, span := s.tracer.Start(ctx, "webhooks.CreateWebhook")
+	defer span.End()
+
+	if err := validate(cmd.Name, cmd.URL, cmd.Events); err != nil {
+		return nil, err
+	}
+
+	count, err := s.store.CountWebhooks(ctx, cmd.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= int64(s.cfg.Webhooks.MaxWebhooksPerOrg) {
+		return nil, webhooks.ErrLimitReached.Errorf("an organization can have at most %d webhooks", s.cfg.Webhooks.MaxWebhooksPerOrg)
+	}
+
+	secret := cmd.Secret
+	if secret == "" {
+		if secret, err = util.GetRandomString(secretLength); err != nil {
+			return nil, err
+		}
+	}
+	encrypted, err := s.encryptSecret(ctx, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	hook := &webhooks.Webhook{
+		OrgID:   cmd.OrgID,
+		UID:     util.GenerateShortUID(),
+		Name:    cmd.Name,
+		URL:     cmd.URL,
+		Events:  cmd.Events,
+		Filter:  cmd.Filter,
+		Enabled: cmd.Enabled == nil || *cmd.Enabled,
+		Created: now,
+		Updated: now,
+	}
+	stored, err := fromWebhook(hook, encrypted)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.CreateWebhook(ctx, stored); err != nil {
+		return nil, err
+	}
+	hook.ID = stored.ID
+
+	return &webhooks.CreateWebhookResult{Webhook: hook, Secret: secret}, nil
+}
+
+func (s *Service) UpdateWebhook(ctx context.Context, cmd *webhooks.UpdateWebhookCommand) (*webhooks.Webhook, error) {
+	ctx, span := s.tracer.Start(ctx, "webhooks.UpdateWebhook")
+	defer span.End()
+
+	if err := validate(cmd.Name, cmd.URL, cmd.Events); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.store.GetWebhook(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+	hook, err := existing.toWebhook()
+	if err != nil {
+		return nil, err
+	}
+	hook.Name = cmd.Name
+	hook.URL = cmd.URL
+	hook.Events = cmd.Events
+	hook.Filter = cmd.Filter
+	hook.Enabled = cmd.Enabled
+	hook.Updated = s.now()
+
+	secret := existing.Secret
+	if cmd.Secret != "" {
+		if secret, err = s.encryptSecret(ctx, cmd.Secret); err != nil {
+			return nil, err
+		}
+	}
+	stored, err := fromWebhook(hook, secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.UpdateWebhook(ctx, stored, cmd.Secret != ""); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+func (s *Service) DeleteWebhook(ctx context.Context, orgID int64, uid string) error {
+	return s.store.DeleteWebhook(ctx, orgID, uid)
+}
+
+func (s *Service) GetWebhook(ctx context.Context, orgID int64, uid string) (*webhooks.Webhook, error) {
+	hook, err := s.store.GetWebhook(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	return hook.toWebhook()
+}
+
+func (s *Service) ListWebhooks(ctx context.Context, orgID int64) ([]*webhooks.Webhook, error) {
+	stored, err := s.store.ListWebhooks(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	hooks := make([]*webhooks.Webhook, 0, len(stored))
+	for _, w := range stored {
+		hook, err := w.toWebhook()
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
+
+func (s *Service) SearchDeliveries(ctx context.Context, query *webhooks.SearchDeliveriesQuery) (*webhooks.SearchDeliveriesResult, error) {
+	if query.Status != "" && !slices.Contains([]string{webhooks.StatusPending, webhooks.StatusSucceeded, webhooks.StatusFailed}, query.Status) {
+		return nil, webhooks.ErrInvalidSearch.Errorf("unknown status %s", query.Status)
+	}
+	if query.EventType != "" && !slices.Contains(webhooks.EventTypes, query.EventType) {
+		return nil, webhooks.ErrInvalidSearch.Errorf("unknown event type %s", query.EventType)
+	}
+	return s.store.SearchDeliveries(ctx, query)
+}
+
+func (s *Service) GetDelivery(ctx context.Context, orgID int64, webhookUID string, deliveryID int64) (*webhooks.Delivery, error) {
+	delivery, err := s.store.GetDelivery(ctx, orgID, webhookUID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	return delivery.toDelivery(true), nil
+}
+
+func (s *Service) Redeliver(ctx context.Context, orgID int64, webhookUID string, deliveryID int64) (*webhooks.Delivery, error) {
+	delivery, err := s.store.GetDelivery(ctx, orgID, webhookUID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	delivery.Status = webhooks.StatusPending
+	delivery.Attempts = 0
+	delivery.NextAttempt = &now
+	delivery.Updated = now
+	if err := s.store.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	s.notify()
+
+	return delivery.toDelivery(false), nil
+}
+
+func validate(name, rawURL string, eventTypes []string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxNameLength {
+		return webhooks.ErrInvalidName.Errorf("name is required and must be at most %d characters", maxNameLength)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return webhooks.ErrInvalidURL.Errorf("url must be an absolute http or https URL")
+	}
+
+	if len(eventTypes) == 0 {
+		return webhooks.ErrInvalidEvents.Errorf("at least one event is required")
+	}
+	for _, eventType := range eventTypes {
+		if !slices.Contains(webhooks.EventTypes, eventType) {
+			return webhooks.ErrInvalidEvents.Errorf("unknown event %s, must be one of %s", eventType, strings.Join(webhooks.EventTypes, ", "))
+		}
+	}
+	return nil
+}
+
+func (s *Service) encryptSecret(ctx context.Context, secret string) (string, error) {
+	encrypted, err := s.secrets.Encrypt(ctx, []byte(secret), secrets.WithoutScope())
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+func (s *Service) decryptSecret(ctx context.Context, secret string) (string, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return "", err
+	}
+	decrypted, err := s.secrets.Decrypt(ctx, encrypted)
+	if err != nil {
+		return "", err
+	}
+	return string(decrypted), nil
+}
+
+// newEvent returns the webhook event of a bus event. Its id is derived from the bus event so that an event
+// redelivered by the event outbox has the same id.
+func newEvent(eventType string, orgID int64, timestamp time.Time, resourceUID, folderUID string, data any) (*webhooks.Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	_, _ = h.Write([]byte(eventType))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write(payload)
+	return &webhooks.Event{
+		ID:          hex.EncodeToString(h.Sum(nil)),
+		Type:        eventType,
+		OrgID:       orgID,
+		Timestamp:   timestamp,
+		ResourceUID: resourceUID,
+		FolderUID:   folderUID,
+		Data:        payload,
+	}, nil
+}
+
+// enqueue stores a pending delivery of the event for each webhook of the organization subscribed to it. The error
+// is returned to the bus so that the event outbox redelivers the event.
+func (s *Service) enqueue(ctx context.Context, event *webhooks.Event) error {
+	ctx, span := s.tracer.Start(ctx, "webhooks.enqueue")
+	defer span.End()
+
+	hooks, err := s.store.ListWebhooks(ctx, event.OrgID)
+	if err != nil {
+		return err
+	}
+
+	var payload []byte
+	now := s.now()
+	deliveries := make([]*webhookDelivery, 0)
+	for _, w := range hooks {
+		hook, err := w.toWebhook()
+		if err != nil {
+			s.log.FromContext(ctx).Error("Failed to read webhook", "uid", w.UID, "error", err)
+			continue
+		}
+		if !hook.Enabled || !slices.Contains(hook.Events, event.Type) || !hook.Filter.Matches(event) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				return err
+			}
+		}
+		deliveries = append(deliveries, &webhookDelivery{
+			OrgID:       event.OrgID,
+			WebhookID:   hook.ID,
+			WebhookUID:  hook.UID,
+			EventID:     event.ID,
+			EventType:   event.Type,
+			Payload:     string(payload),
+			Status:      webhooks.StatusPending,
+			NextAttempt: &now,
+			Created:     now,
+			Updated:     now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := s.store.AddDeliveries(ctx, deliveries); err != nil {
+		return err
+	}
+	s.notify()
+	return nil
+}
+
+func (s *Service) notify() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Service) handleDashboardSaved(ctx context.Context, evt *events.DashboardSaved) error {
+	event, err := newEvent(webhooks.EventDashboardSaved, evt.OrgID, evt.Timestamp, evt.UID, evt.FolderUID, evt)
+	if err != nil {
+		return err
+	}
+	return s.enqueue(ctx, event)
+}
+
+func (s *Service) handleDashboardDeleted(ctx context.Context, evt *events.DashboardDeleted) error {
+	event, err := newEvent(webhooks.EventDashboardDeleted, evt.OrgID, evt.Timestamp, evt.UID, "", evt)
+	if err != nil {
+		return err
+	}
+	return s.enqueue(ctx, event)
+}
+
+func (s *Service) handleAlertRuleSaved(ctx context.Context, evt *events.AlertRuleSaved) error {
+	event, err := newEvent(webhooks.EventAlertRuleSaved, evt.OrgID, evt.Timestamp, evt.UID, evt.FolderUID, evt)
+	if err != nil {
+		return err
+	}
+	return s.enqueue(ctx, event)
+}
+
+func (s *Service) handleAlertRuleDeleted(ctx context.Context, evt *events.AlertRuleDeleted) error {
+	event, err := newEvent(webhooks.EventAlertRuleDeleted, evt.OrgID, evt.Timestamp, evt.UID, evt.FolderUID, evt)
+	if err != nil {
+		return err
+	}
+	return s.enqueue(ctx, event)
+}
+
+func (s *Service) handleDataSourceCreated(ctx context.Context, evt *events.DataSourceCreated) error {
+	event, err := newEvent(webhooks.EventDataSourceCreated, evt.OrgID, evt.Timestamp, evt.UID, "", evt)
+	if err != nil {
+		return err
+	}
+	return s.enqueue(ctx, event)
+}
+
+func (s *Service) handleDataSourceDeleted(ctx context.Context, evt *events.DataSourceDeleted) error {
+	event, err := newEvent(webhooks.EventDataSourceDeleted, evt.OrgID, evt.Timestamp, evt.UID, "", evt)
+	if err != nil {
+		return err
+	}
+	return s.enqueue(ctx, event)
+}
+
+func (s *Service) handleOrgUserAdded(ctx context.Context, evt *events.OrgUserAdded) error {
+	event, err := newEvent(webhooks.EventUserAdded, evt.OrgID, evt.Timestamp, strconv.FormatInt(evt.UserID, 10), "", evt)
+	if err != nil {
+		return err
+	}
+	return s.enqueue(ctx, event)
+}
+
+func (s *Service) handleQuotaSoftLimitReached(ctx context.Context, evt *events.QuotaSoftLimitReached) error {
+	// the quotas of the users and the global quotas aren't sent to the webhooks of an organization
+	if evt.OrgID == 0 {
+		return nil
+	}
+	event, err := newEvent(webhooks.EventQuotaSoftLimitReached, evt.OrgID, evt.Timestamp, evt.Target, "", evt)
+	if err != nil {
+		return err
+	}
+	return s.enqueue(ctx, event)
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	interval := s.cfg.Webhooks.DispatchInterval
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	maintenance := time.NewTicker(maintenanceInterval)
+	defer maintenance.Stop()
+
+	// a dispatch sends the deliveries one after the other, the lock is considered dead once they all could have
+	// timed out
+	lockTimeout := dispatchBatchSize*s.cfg.Webhooks.RequestTimeout + interval
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-s.trigger:
+		case <-maintenance.C:
+			err := s.serverLock.LockAndExecute(ctx, "delete expired webhook deliveries", maintenanceInterval/2, func(ctx context.Context) {
+				s.deleteExpiredDeliveries(ctx)
+			})
+			if err != nil {
+				s.log.Error("Failed to lock and execute the deletion of expired webhook deliveries", "error", err)
+			}
+			continue
+		}
+
+		err := s.serverLock.LockExecuteAndRelease(ctx, "dispatch webhook deliveries", lockTimeout, func(ctx context.Context) {
+			s.dispatch(ctx)
+		})
+		var lockErr *serverlock.ServerLockExistsError
+		if err != nil && !errors.As(err, &lockErr) {
+			s.log.Error("Failed to lock and execute the dispatch of webhook deliveries", "error", err)
+		}
+	}
+}
+
+// dispatch sends the due deliveries, until none is due
+func (s *Service) dispatch(ctx context.Context) {
+	ctx, span := s.tracer.Start(ctx, "webhooks.dispatch")
+	defer span.End()
+
+	for {
+		deliveries, err := s.store.GetDueDeliveries(ctx, s.now(), dispatchBatchSize)
+		if err != nil {
+			s.log.FromContext(ctx).Error("Failed to get the due webhook deliveries", "error", err)
+			return
+		}
+		for _, delivery := range deliveries {
+			if ctx.Err() != nil {
+				return
+			}
+			s.deliver(ctx, delivery)
+		}
+		if len(deliveries) < dispatchBatchSize {
+			return
+		}
+	}
+}
+
+// deliver sends the delivery and records the attempt
+func (s *Service) deliver(ctx context.Context, delivery *webhookDelivery) {
+	logger := s.log.FromContext(ctx).New("webhook", delivery.WebhookUID, "delivery", delivery.ID)
+
+	code, err := s.send(ctx, delivery)
+	now := s.now()
+	delivery.Attempts++
+	delivery.ResponseCode = code
+	delivery.Updated = now
+	switch {
+	case err == nil:
+		delivery.Status = webhooks.StatusSucceeded
+		delivery.LastError = ""
+		delivery.NextAttempt = nil
+		s.metrics.deliveries.WithLabelValues("success").Inc()
+	case delivery.Attempts >= s.cfg.Webhooks.MaxAttempts:
+		logger.Warn("Giving up webhook delivery", "attempts", delivery.Attempts, "error", err)
+		delivery.Status = webhooks.StatusFailed
+		delivery.LastError = err.Error()
+		delivery.NextAttempt = nil
+		s.metrics.deliveries.WithLabelValues("failure").Inc()
+	default:
+		logger.Debug("Webhook delivery failed, retrying", "attempts", delivery.Attempts, "error", err)
+		next := now.Add(backoff(s.cfg.Webhooks.RetryDelay, delivery.Attempts))
+		delivery.LastError = err.Error()
+		delivery.NextAttempt = &next
+		s.metrics.deliveries.WithLabelValues("retry").Inc()
+	}
+
+	if err := s.store.UpdateDelivery(ctx, delivery); err != nil {
+		logger.Error("Failed to update webhook delivery", "error", err)
+	}
+}
+
+func (s *Service) send(ctx context.Context, delivery *webhookDelivery) (int, error) {
+	hook, err := s.store.GetWebhook(ctx, delivery.OrgID, delivery.WebhookUID)
+	if err != nil {
+		return 0, err
+	}
+	if !hook.Enabled {
+		return 0, errors.New("webhook is disabled")
+	}
+	secret, err := s.decryptSecret(ctx, hook.Secret)
+	if err != nil {
+		return 0, err
+	}
+
+	start := s.now()
+	code, err := s.sender.Send(ctx, &request{
+		url:        hook.URL,
+		secret:     secret,
+		eventType:  delivery.EventType,
+		deliveryID: delivery.ID,
+		payload:    []byte(delivery.Payload),
+	})
+	s.metrics.duration.Observe(s.now().Sub(start).Seconds())
+	return code, err
+}
+
+// backoff returns the delay before the next attempt of a delivery failed the given number of times
+func backoff(retryDelay time.Duration, attempts int) time.Duration {
+	delay := retryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+func (s *Service) deleteExpiredDeliveries(ctx context.Context) {
+	deleted, err := s.store.DeleteDeliveriesBefore(ctx, s.now().Add(-s.cfg.Webhooks.DeliveryRetention))
+	if err != nil {
+		s.log.FromContext(ctx).Error("Failed to delete expired webhook deliveries", "error", err)
+		return
+	}
+	if deleted > 0 {
+		s.log.FromContext(ctx).Debug("Deleted expired webhook deliveries", "count", deleted)
+	}
+}
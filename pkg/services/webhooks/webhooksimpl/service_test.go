@@ -0,0 +1,385 @@
+package webhooksimpl
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/services/webhooks"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func TestService_CreateWebhook(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should generate a secret and store it encrypted", func(t *testing.T) {
+		s, store, _ := setupTestService(t)
+
+		result, err := s.CreateWebhook(ctx, &webhooks.CreateWebhookCommand{
+			OrgID:  1,
+			Name:   "ci",
+			URL:    "https://example.com/hook",
+			Events: []string{webhooks.EventDashboardSaved},
+		})
+		require.NoError(t, err)
+		assert.Len(t, result.Secret, secretLength)
+		assert.True(t, result.Enabled)
+
+		require.Len(t, store.webhooks, 1)
+		assert.NotEqual(t, result.Secret, store.webhooks[0].Secret)
+		secret, err := s.decryptSecret(ctx, store.webhooks[0].Secret)
+		require.NoError(t, err)
+		assert.Equal(t, result.Secret, secret)
+	})
+
+	t.Run("should validate the webhook", func(t *testing.T) {
+		s, _, _ := setupTestService(t)
+
+		for name, cmd := range map[string]webhooks.CreateWebhookCommand{
+			"missing name":  {URL: "https://example.com", Events: []string{webhooks.EventUserAdded}},
+			"invalid url":   {Name: "hook", URL: "ftp://example.com", Events: []string{webhooks.EventUserAdded}},
+			"relative url":  {Name: "hook", URL: "/hook", Events: []string{webhooks.EventUserAdded}},
+			"no events":     {Name: "hook", URL: "https://example.com"},
+			"unknown event": {Name: "hook", URL: "https://example.com", Events: []string{"team.added"}},
+		} {
+			_, err := s.CreateWebhook(ctx, &cmd)
+			assert.Error(t, err, name)
+		}
+	})
+
+	t.Run("should limit the webhooks of an organization", func(t *testing.T) {
+		s, _, _ := setupTestService(t)
+		s.cfg.Webhooks.MaxWebhooksPerOrg = 1
+		cmd := &webhooks.CreateWebhookCommand{OrgID: 1, Name: "hook", URL: "https://example.com", Events: []string{webhooks.EventUserAdded}}
+
+		_, err := s.CreateWebhook(ctx, cmd)
+		require.NoError(t, err)
+		_, err = s.CreateWebhook(ctx, cmd)
+		assert.ErrorIs(t, err, webhooks.ErrLimitReached)
+
+		cmd.OrgID = 2
+		_, err = s.CreateWebhook(ctx, cmd)
+		assert.NoError(t, err)
+	})
+}
+
+func TestService_Enqueue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should deliver the events to the subscribed webhooks matching the filter", func(t *testing.T) {
+		s, store, _ := setupTestService(t)
+		all := createWebhook(t, s, 1, []string{webhooks.EventDashboardSaved, webhooks.EventAlertRuleSaved}, webhooks.Filter{})
+		folder := createWebhook(t, s, 1, []string{webhooks.EventDashboardSaved}, webhooks.Filter{FolderUIDs: []string{"team-a"}})
+		createWebhook(t, s, 1, []string{webhooks.EventUserAdded}, webhooks.Filter{})
+		createWebhook(t, s, 2, []string{webhooks.EventDashboardSaved}, webhooks.Filter{})
+
+		require.NoError(t, s.handleDashboardSaved(ctx, &events.DashboardSaved{OrgID: 1, UID: "dash", FolderUID: "team-b", Version: 2}))
+		require.Len(t, store.deliveries, 1)
+		assert.Equal(t, all.UID, store.deliveries[0].WebhookUID)
+
+		require.NoError(t, s.handleDashboardSaved(ctx, &events.DashboardSaved{OrgID: 1, UID: "dash", FolderUID: "team-a", Version: 3}))
+		require.Len(t, store.deliveries, 3)
+		assert.Equal(t, folder.UID, store.deliveries[2].WebhookUID)
+		assert.Equal(t, store.deliveries[1].EventID, store.deliveries[2].EventID, "the deliveries of an event have the same id")
+
+		var event webhooks.Event
+		require.NoError(t, json.Unmarshal([]byte(store.deliveries[2].Payload), &event))
+		assert.Equal(t, webhooks.EventDashboardSaved, event.Type)
+		assert.Equal(t, "dash", event.ResourceUID)
+		assert.Equal(t, "team-a", event.FolderUID)
+		assert.JSONEq(t, `{"timestamp":"0001-01-01T00:00:00Z","id":0,"uid":"dash","org_id":1,"folder_uid":"team-a","version":3}`, string(event.Data))
+	})
+
+	t.Run("should deliver an event redelivered by the outbox once", func(t *testing.T) {
+		s, store, _ := setupTestService(t)
+		createWebhook(t, s, 1, []string{webhooks.EventUserAdded}, webhooks.Filter{})
+
+		evt := &events.OrgUserAdded{OrgID: 1, UserID: 3, Role: "Editor", Timestamp: now}
+		require.NoError(t, s.handleOrgUserAdded(ctx, evt))
+		require.NoError(t, s.handleOrgUserAdded(ctx, evt))
+		assert.Len(t, store.deliveries, 1)
+	})
+
+	t.Run("should return the store errors so the outbox redelivers the event", func(t *testing.T) {
+		s, store, _ := setupTestService(t)
+		createWebhook(t, s, 1, []string{webhooks.EventDataSourceCreated}, webhooks.Filter{})
+		store.err = errors.New("database is locked")
+
+		assert.Error(t, s.handleDataSourceCreated(ctx, &events.DataSourceCreated{OrgID: 1, UID: "prom"}))
+	})
+}
+
+func TestService_Dispatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should mark the delivered events as succeeded", func(t *testing.T) {
+		s, store, sender := setupTestService(t)
+		hook := createWebhook(t, s, 1, []string{webhooks.EventAlertRuleDeleted}, webhooks.Filter{})
+		require.NoError(t, s.handleAlertRuleDeleted(ctx, &events.AlertRuleDeleted{OrgID: 1, UID: "rule"}))
+
+		s.dispatch(ctx)
+
+		require.Len(t, sender.requests, 1)
+		assert.Equal(t, hook.URL, sender.requests[0].url)
+		assert.Equal(t, webhooks.EventAlertRuleDeleted, sender.requests[0].eventType)
+		delivery := store.deliveries[0]
+		assert.Equal(t, webhooks.StatusSucceeded, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Nil(t, delivery.NextAttempt)
+	})
+
+	t.Run("should retry the failed deliveries with backoff and give up after the max attempts", func(t *testing.T) {
+		s, store, sender := setupTestService(t)
+		s.cfg.Webhooks.MaxAttempts = 3
+		createWebhook(t, s, 1, []string{webhooks.EventDataSourceDeleted}, webhooks.Filter{})
+		require.NoError(t, s.handleDataSourceDeleted(ctx, &events.DataSourceDeleted{OrgID: 1, UID: "prom"}))
+		sender.code, sender.err = http.StatusBadGateway, errors.New("bad gateway")
+
+		s.dispatch(ctx)
+		delivery := store.deliveries[0]
+		assert.Equal(t, webhooks.StatusPending, delivery.Status)
+		assert.Equal(t, http.StatusBadGateway, delivery.ResponseCode)
+		assert.Equal(t, "bad gateway", delivery.LastError)
+		assert.Equal(t, now.Add(s.cfg.Webhooks.RetryDelay), *delivery.NextAttempt)
+
+		s.dispatch(ctx)
+		assert.Len(t, sender.requests, 1, "the delivery isn't due")
+
+		s.now = func() time.Time { return now.Add(time.Hour) }
+		s.dispatch(ctx)
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.Equal(t, now.Add(time.Hour+2*s.cfg.Webhooks.RetryDelay), *delivery.NextAttempt)
+
+		s.now = func() time.Time { return now.Add(2 * time.Hour) }
+		s.dispatch(ctx)
+		assert.Equal(t, 3, delivery.Attempts)
+		assert.Equal(t, webhooks.StatusFailed, delivery.Status)
+		assert.Nil(t, delivery.NextAttempt)
+
+		_, err := s.Redeliver(ctx, 1, delivery.WebhookUID, delivery.ID)
+		require.NoError(t, err)
+		assert.Equal(t, webhooks.StatusPending, delivery.Status)
+		assert.Equal(t, 0, delivery.Attempts)
+
+		sender.code, sender.err = http.StatusOK, nil
+		s.dispatch(ctx)
+		assert.Equal(t, webhooks.StatusSucceeded, delivery.Status)
+		assert.Len(t, sender.requests, 4)
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, backoff(30*time.Second, 1))
+	assert.Equal(t, time.Minute, backoff(30*time.Second, 2))
+	assert.Equal(t, 4*time.Minute, backoff(30*time.Second, 4))
+	assert.Equal(t, maxRetryDelay, backoff(30*time.Second, 20))
+	assert.Equal(t, maxRetryDelay, backoff(30*time.Second, 1000))
+}
+
+func TestHTTPSender(t *testing.T) {
+	payload := []byte(`{"id":"abc","type":"dashboard.saved"}`)
+
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		if r.URL.Path == "/fail" {
+			http.Error(w, "nope", http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	s := newHTTPSender(time.Second, "11.0.0")
+	s.now = func() time.Time { return now }
+
+	code, err := s.Send(context.Background(), &request{
+		url: server.URL, secret: "secret", eventType: webhooks.EventDashboardSaved, deliveryID: 7, payload: payload,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, payload, body)
+	assert.Equal(t, webhooks.EventDashboardSaved, received.Header.Get(headerEvent))
+	assert.Equal(t, "7", received.Header.Get(headerDelivery))
+
+	timestamp := received.Header.Get(headerTimestamp)
+	assert.Equal(t, "1717243200", timestamp)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	_, _ = mac.Write([]byte(timestamp + "." + string(payload)))
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), received.Header.Get(headerSignature))
+
+	code, err = s.Send(context.Background(), &request{url: server.URL + "/fail", secret: "secret", payload: payload})
+	assert.ErrorContains(t, err, "nope")
+	assert.Equal(t, http.StatusInternalServerError, code)
+}
+
+func createWebhook(t *testing.T, s *Service, orgID int64, eventTypes []string, filter webhooks.Filter) *webhooks.Webhook {
+	t.Helper()
+
+	result, err := s.CreateWebhook(context.Background(), &webhooks.CreateWebhookCommand{
+		OrgID:  orgID,
+		Name:   "hook",
+		URL:    "https://example.com/hook",
+		Events: eventTypes,
+		Filter: filter,
+	})
+	require.NoError(t, err)
+	return result.Webhook
+}
+
+func setupTestService(t *testing.T) (*Service, *fakeStore, *fakeSender) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.Webhooks = setting.WebhooksSettings{
+		Enabled:           true,
+		DispatchInterval:  10 * time.Second,
+		RequestTimeout:    10 * time.Second,
+		RetryDelay:        30 * time.Second,
+		MaxAttempts:       8,
+		DeliveryRetention: 7 * 24 * time.Hour,
+		MaxWebhooksPerOrg: 20,
+	}
+
+	store := &fakeStore{}
+	sender := &fakeSender{code: http.StatusOK}
+	return &Service{
+		cfg:     cfg,
+		store:   store,
+		secrets: fakes.NewFakeSecretsService(),
+		sender:  sender,
+		metrics: newMetrics(nil),
+		trigger: make(chan struct{}, 1),
+		log:     log.NewNopLogger(),
+		tracer:  tracing.InitializeTracerForTest(),
+		now:     func() time.Time { return now },
+	}, store, sender
+}
+
+type fakeSender struct {
+	requests []*request
+	code     int
+	err      error
+}
+
+func (f *fakeSender) Send(_ context.Context, req *request) (int, error) {
+	f.requests = append(f.requests, req)
+	return f.code, f.err
+}
+
+type fakeStore struct {
+	webhooks   []*webhook
+	deliveries []*webhookDelivery
+	err        error
+}
+
+func (f *fakeStore) CreateWebhook(_ context.Context, hook *webhook) error {
+	hook.ID = int64(len(f.webhooks) + 1)
+	f.webhooks = append(f.webhooks, hook)
+	return nil
+}
+
+func (f *fakeStore) UpdateWebhook(_ context.Context, hook *webhook, _ bool) error {
+	for i, w := range f.webhooks {
+		if w.OrgID == hook.OrgID && w.UID == hook.UID {
+			f.webhooks[i] = hook
+			return nil
+		}
+	}
+	return webhooks.ErrWebhookNotFound.Errorf("webhook %s not found", hook.UID)
+}
+
+func (f *fakeStore) DeleteWebhook(_ context.Context, orgID int64, uid string) error {
+	return nil
+}
+
+func (f *fakeStore) GetWebhook(_ context.Context, orgID int64, uid string) (*webhook, error) {
+	for _, w := range f.webhooks {
+		if w.OrgID == orgID && w.UID == uid {
+			return w, nil
+		}
+	}
+	return nil, webhooks.ErrWebhookNotFound.Errorf("webhook %s not found", uid)
+}
+
+func (f *fakeStore) ListWebhooks(_ context.Context, orgID int64) ([]*webhook, error) {
+	var hooks []*webhook
+	for _, w := range f.webhooks {
+		if orgID == 0 || w.OrgID == orgID {
+			hooks = append(hooks, w)
+		}
+	}
+	return hooks, nil
+}
+
+func (f *fakeStore) CountWebhooks(ctx context.Context, orgID int64) (int64, error) {
+	hooks, err := f.ListWebhooks(ctx, orgID)
+	return int64(len(hooks)), err
+}
+
+func (f *fakeStore) AddDeliveries(_ context.Context, deliveries []*webhookDelivery) error {
+	if f.err != nil {
+		return f.err
+	}
+	for _, delivery := range deliveries {
+		exists := false
+		for _, d := range f.deliveries {
+			if d.WebhookID == delivery.WebhookID && d.EventID == delivery.EventID {
+				exists = true
+			}
+		}
+		if !exists {
+			delivery.ID = int64(len(f.deliveries) + 1)
+			f.deliveries = append(f.deliveries, delivery)
+		}
+	}
+	return nil
+}
+
+func (f *fakeStore) GetDelivery(_ context.Context, orgID int64, webhookUID string, id int64) (*webhookDelivery, error) {
+	for _, d := range f.deliveries {
+		if d.ID == id && d.OrgID == orgID && d.WebhookUID == webhookUID {
+			return d, nil
+		}
+	}
+	return nil, webhooks.ErrDeliveryNotFound.Errorf("webhook delivery %d not found", id)
+}
+
+func (f *fakeStore) SearchDeliveries(_ context.Context, query *webhooks.SearchDeliveriesQuery) (*webhooks.SearchDeliveriesResult, error) {
+	return &webhooks.SearchDeliveriesResult{}, nil
+}
+
+func (f *fakeStore) GetDueDeliveries(_ context.Context, now time.Time, limit int) ([]*webhookDelivery, error) {
+	var due []*webhookDelivery
+	for _, d := range f.deliveries {
+		if d.Status == webhooks.StatusPending && !d.NextAttempt.After(now) && len(due) < limit {
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeStore) UpdateDelivery(_ context.Context, delivery *webhookDelivery) error {
+	return nil
+}
+
+func (f *fakeStore) DeleteDeliveriesBefore(_ context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,342 @@
+package webhooksimpl
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/webhooks"
+	"github.com/grafana/grafana/pkg/util/xorm"
+)
+
+const (
+	defaultPerPage = 100
+	maxPerPage     = 1000
+)
+
+// webhook is the stored form of a webhooks.Webhook, its events and filter are encoded as JSON and its secret is
+// encrypted
+type webhook struct {
+	ID      int64  `xorm:"pk autoincr 'id'"`
+	OrgID   int64  `xorm:"org_id"`
+	UID     string `xorm:"uid"`
+	Name    string
+	URL     string `xorm:"url"`
+	Events  string
+	Filter  string
+	Secret  string
+	Enabled bool
+	Created time.Time
+	Updated time.Time
+}
+
+func (webhook) TableName() string {
+	return "webhook"
+}
+
+func (w *webhook) toWebhook() (*webhooks.Webhook, error) {
+	hook := &webhooks.Webhook{
+		ID:      w.ID,
+		OrgID:   w.OrgID,
+		UID:     w.UID,
+		Name:    w.Name,
+		URL:     w.URL,
+		Enabled: w.Enabled,
+		Created: w.Created,
+		Updated: w.Updated,
+	}
+	if err := json.Unmarshal([]byte(w.Events), &hook.Events); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(w.Filter), &hook.Filter); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+func fromWebhook(hook *webhooks.Webhook, secret string) (*webhook, error) {
+	events, err := json.Marshal(hook.Events)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := json.Marshal(hook.Filter)
+	if err != nil {
+		return nil, err
+	}
+	return &webhook{
+		ID:      hook.ID,
+		OrgID:   hook.OrgID,
+		UID:     hook.UID,
+		Name:    hook.Name,
+		URL:     hook.URL,
+		Events:  string(events),
+		Filter:  string(filter),
+		Secret:  secret,
+		Enabled: hook.Enabled,
+		Created: hook.Created,
+		Updated: hook.Updated,
+	}, nil
+}
+
+type webhookDelivery struct {
+	ID           int64      `xorm:"pk autoincr 'id'"`
+	OrgID        int64      `xorm:"org_id"`
+	WebhookID    int64      `xorm:"webhook_id"`
+	WebhookUID   string     `xorm:"webhook_uid"`
+	EventID      string     `xorm:"event_id"`
+	EventType    string     `xorm:"event_type"`
+	Payload      string     `xorm:"payload"`
+	Status       string     `xorm:"status"`
+	Attempts     int        `xorm:"attempts"`
+	ResponseCode int        `xorm:"response_code"`
+	LastError    string     `xorm:"last_error"`
+	NextAttempt  *time.Time `xorm:"next_attempt"`
+	Created      time.Time  `xorm:"created"`
+	Updated      time.Time  `xorm:"updated"`
+}
+
+func (webhookDelivery) TableName() string {
+	return "webhook_delivery"
+}
+
+func (d *webhookDelivery) toDelivery(withPayload bool) *webhooks.Delivery {
+	delivery := &webhooks.Delivery{
+		ID:           d.ID,
+		OrgID:        d.OrgID,
+		WebhookUID:   d.WebhookUID,
+		EventID:      d.EventID,
+		EventType:    d.EventType,
+		Status:       d.Status,
+		Attempts:     d.Attempts,
+		ResponseCode: d.ResponseCode,
+		LastError:    d.LastError,
+		NextAttempt:  d.NextAttempt,
+		Created:      d.Created,
+		Updated:      d.Updated,
+	}
+	if withPayload {
+		delivery.Payload = json.RawMessage(d.Payload)
+	}
+	return delivery
+}
+
+type store interface {
+	CreateWebhook(ctx context.Context, hook *webhook) error
+	UpdateWebhook(ctx context.Context, hook *webhook, updateSecret bool) error
+	// DeleteWebhook deletes the webhook and its deliveries
+	DeleteWebhook(ctx context.Context, orgID int64, uid string) error
+	GetWebhook(ctx context.Context, orgID int64, uid string) (*webhook, error)
+	// ListWebhooks returns the webhooks of the organization, or of every organization when orgID is 0
+	ListWebhooks(ctx context.Context, orgID int64) ([]*webhook, error)
+	CountWebhooks(ctx context.Context, orgID int64) (int64, error)
+	// AddDeliveries stores the pending deliveries, skipping the ones of an event already delivered to the webhook
+	AddDeliveries(ctx context.Context, deliveries []*webhookDelivery) error
+	GetDelivery(ctx context.Context, orgID int64, webhookUID string, id int64) (*webhookDelivery, error)
+	SearchDeliveries(ctx context.Context, query *webhooks.SearchDeliveriesQuery) (*webhooks.SearchDeliveriesResult, error)
+	// GetDueDeliveries returns the pending deliveries whose next attempt is before the time, the oldest first
+	GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*webhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *webhookDelivery) error
+	// DeleteDeliveriesBefore deletes the deliveries created before the time and returns how many were deleted
+	DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (s *xormStore) CreateWebhook(ctx context.Context, hook *webhook) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(hook)
+		return err
+	})
+}
+
+func (s *xormStore) UpdateWebhook(ctx context.Context, hook *webhook, updateSecret bool) error {
+	cols := []string{"name", "url", "events", "filter", "enabled", "updated"}
+	if updateSecret {
+		cols = append(cols, "secret")
+	}
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", hook.OrgID, hook.UID).Cols(cols...).UseBool("enabled").Update(hook)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return webhooks.ErrWebhookNotFound.Errorf("webhook %s not found", hook.UID)
+		}
+		return nil
+	})
+}
+
+func (s *xormStore) DeleteWebhook(ctx context.Context, orgID int64, uid string) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&webhook{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return webhooks.ErrWebhookNotFound.Errorf("webhook %s not found", uid)
+		}
+		_, err = sess.Where("org_id = ? AND webhook_uid = ?", orgID, uid).Delete(&webhookDelivery{})
+		return err
+	})
+}
+
+func (s *xormStore) GetWebhook(ctx context.Context, orgID int64, uid string) (*webhook, error) {
+	var hook webhook
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&hook)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return webhooks.ErrWebhookNotFound.Errorf("webhook %s not found", uid)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &hook, nil
+}
+
+func (s *xormStore) ListWebhooks(ctx context.Context, orgID int64) ([]*webhook, error) {
+	hooks := make([]*webhook, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Asc("id")
+		if orgID != 0 {
+			q = q.Where("org_id = ?", orgID)
+		}
+		return q.Find(&hooks)
+	})
+	return hooks, err
+}
+
+func (s *xormStore) CountWebhooks(ctx context.Context, orgID int64) (int64, error) {
+	var count int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		count, err = sess.Where("org_id = ?", orgID).Count(&webhook{})
+		return err
+	})
+	return count, err
+}
+
+func (s *xormStore) AddDeliveries(ctx context.Context, deliveries []*webhookDelivery) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for _, delivery := range deliveries {
+			// an event redelivered by the event outbox is delivered to the webhook once
+			exists, err := sess.Exist(&webhookDelivery{WebhookID: delivery.WebhookID, EventID: delivery.EventID})
+			if err != nil {
+				return err
+			}
+			if exists {
+				continue
+			}
+			if _, err := sess.Insert(delivery); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *xormStore) GetDelivery(ctx context.Context, orgID int64, webhookUID string, id int64) (*webhookDelivery, error) {
+	var delivery webhookDelivery
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("id = ? AND org_id = ? AND webhook_uid = ?", id, orgID, webhookUID).Get(&delivery)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return webhooks.ErrDeliveryNotFound.Errorf("webhook delivery %d not found", id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (s *xormStore) SearchDeliveries(ctx context.Context, query *webhooks.SearchDeliveriesQuery) (*webhooks.SearchDeliveriesResult, error) {
+	perPage := query.Limit
+	if perPage <= 0 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+	page := query.Page
+	if page <= 0 {
+		page = 1
+	}
+
+	result := &webhooks.SearchDeliveriesResult{Deliveries: make([]*webhooks.Delivery, 0), Page: page, PerPage: perPage}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		filter := func() *xorm.Session {
+			q := sess.Table("webhook_delivery").Where("org_id = ?", query.OrgID)
+			if query.WebhookUID != "" {
+				q = q.Where("webhook_uid = ?", query.WebhookUID)
+			}
+			if query.Status != "" {
+				q = q.Where("status = ?", query.Status)
+			}
+			if query.EventType != "" {
+				q = q.Where("event_type = ?", query.EventType)
+			}
+			return q
+		}
+
+		count, err := filter().Count(&webhookDelivery{})
+		if err != nil {
+			return err
+		}
+		result.TotalCount = count
+
+		entries := make([]*webhookDelivery, 0)
+		if err := filter().Omit("payload").Desc("created", "id").Limit(perPage, (page-1)*perPage).Find(&entries); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			result.Deliveries = append(result.Deliveries, entry.toDelivery(false))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *xormStore) GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*webhookDelivery, error) {
+	deliveries := make([]*webhookDelivery, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("status = ? AND next_attempt <= ?", webhooks.StatusPending, now).
+			Asc("next_attempt", "id").Limit(limit).Find(&deliveries)
+	})
+	return deliveries, err
+}
+
+func (s *xormStore) UpdateDelivery(ctx context.Context, delivery *webhookDelivery) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(delivery.ID).
+			Cols("status", "attempts", "response_code", "last_error", "next_attempt", "updated").
+			Update(delivery)
+		return err
+	})
+}
+
+func (s *xormStore) DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM webhook_delivery WHERE created < ?", before)
+		if err != nil {
+			return err
+		}
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	return deleted, err
+}
@@ -0,0 +1,155 @@
+package webhooksimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/webhooks"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+	store := &xormStore{db: db.InitTestDB(t)}
+	created := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	hook := &webhook{OrgID: 1, UID: "ci", Name: "ci", URL: "https://example.com/hook", Events: `["dashboard.saved"]`,
+		Filter: `{}`, Secret: "encrypted", Enabled: true, Created: created, Updated: created}
+	require.NoError(t, store.CreateWebhook(ctx, hook))
+	require.NoError(t, store.CreateWebhook(ctx, &webhook{OrgID: 2, UID: "ci", Name: "ci", URL: "https://example.com/other",
+		Events: `[]`, Filter: `{}`, Created: created, Updated: created}))
+
+	t.Run("should get and list the webhooks by org", func(t *testing.T) {
+		got, err := store.GetWebhook(ctx, 1, "ci")
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/hook", got.URL)
+		assert.Equal(t, "encrypted", got.Secret)
+		assert.True(t, got.Enabled)
+
+		_, err = store.GetWebhook(ctx, 3, "ci")
+		assert.ErrorIs(t, err, webhooks.ErrWebhookNotFound)
+
+		hooks, err := store.ListWebhooks(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, hooks, 1)
+		all, err := store.ListWebhooks(ctx, 0)
+		require.NoError(t, err)
+		assert.Len(t, all, 2)
+
+		count, err := store.CountWebhooks(ctx, 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("should only update the secret when asked", func(t *testing.T) {
+		update := *hook
+		update.Name = "deploy"
+		update.Enabled = false
+		update.Secret = "rotated"
+		require.NoError(t, store.UpdateWebhook(ctx, &update, false))
+
+		got, err := store.GetWebhook(ctx, 1, "ci")
+		require.NoError(t, err)
+		assert.Equal(t, "deploy", got.Name)
+		assert.False(t, got.Enabled)
+		assert.Equal(t, "encrypted", got.Secret)
+
+		require.NoError(t, store.UpdateWebhook(ctx, &update, true))
+		got, err = store.GetWebhook(ctx, 1, "ci")
+		require.NoError(t, err)
+		assert.Equal(t, "rotated", got.Secret)
+
+		update.UID = "missing"
+		assert.ErrorIs(t, store.UpdateWebhook(ctx, &update, false), webhooks.ErrWebhookNotFound)
+	})
+
+	t.Run("should store the deliveries of an event once", func(t *testing.T) {
+		due := created.Add(time.Minute)
+		delivery := func(eventID, eventType string) *webhookDelivery {
+			return &webhookDelivery{OrgID: 1, WebhookID: hook.ID, WebhookUID: "ci", EventID: eventID, EventType: eventType,
+				Payload: `{"uid":"abc"}`, Status: webhooks.StatusPending, NextAttempt: &due, Created: created, Updated: created}
+		}
+		require.NoError(t, store.AddDeliveries(ctx, []*webhookDelivery{delivery("1", "dashboard.saved"), delivery("2", "dashboard.deleted")}))
+		require.NoError(t, store.AddDeliveries(ctx, []*webhookDelivery{delivery("1", "dashboard.saved")}))
+
+		result, err := store.SearchDeliveries(ctx, &webhooks.SearchDeliveriesQuery{OrgID: 1, WebhookUID: "ci"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), result.TotalCount)
+		require.Len(t, result.Deliveries, 2)
+		assert.Nil(t, result.Deliveries[0].Payload, "the payloads are left out of the searches")
+
+		result, err = store.SearchDeliveries(ctx, &webhooks.SearchDeliveriesQuery{OrgID: 1, EventType: "dashboard.deleted"})
+		require.NoError(t, err)
+		require.Len(t, result.Deliveries, 1)
+
+		got, err := store.GetDelivery(ctx, 1, "ci", result.Deliveries[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, `{"uid":"abc"}`, got.Payload)
+		_, err = store.GetDelivery(ctx, 2, "ci", result.Deliveries[0].ID)
+		assert.ErrorIs(t, err, webhooks.ErrDeliveryNotFound)
+	})
+
+	t.Run("should return the due deliveries and update them", func(t *testing.T) {
+		pending, err := store.GetDueDeliveries(ctx, created, 10)
+		require.NoError(t, err)
+		assert.Empty(t, pending, "the deliveries aren't due yet")
+
+		pending, err = store.GetDueDeliveries(ctx, created.Add(time.Hour), 10)
+		require.NoError(t, err)
+		require.Len(t, pending, 2)
+
+		pending[0].Status = webhooks.StatusSucceeded
+		pending[0].Attempts = 1
+		pending[0].ResponseCode = 200
+		pending[0].NextAttempt = nil
+		require.NoError(t, store.UpdateDelivery(ctx, pending[0]))
+
+		pending, err = store.GetDueDeliveries(ctx, created.Add(time.Hour), 10)
+		require.NoError(t, err)
+		assert.Len(t, pending, 1)
+
+		result, err := store.SearchDeliveries(ctx, &webhooks.SearchDeliveriesQuery{OrgID: 1, Status: webhooks.StatusSucceeded})
+		require.NoError(t, err)
+		require.Len(t, result.Deliveries, 1)
+		assert.Equal(t, 200, result.Deliveries[0].ResponseCode)
+	})
+
+	t.Run("should delete the old deliveries", func(t *testing.T) {
+		deleted, err := store.DeleteDeliveriesBefore(ctx, created)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), deleted)
+
+		deleted, err = store.DeleteDeliveriesBefore(ctx, created.Add(time.Second))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+	})
+
+	t.Run("should delete a webhook with its deliveries", func(t *testing.T) {
+		due := created
+		require.NoError(t, store.AddDeliveries(ctx, []*webhookDelivery{{OrgID: 1, WebhookID: hook.ID, WebhookUID: "ci", EventID: "3",
+			EventType: "dashboard.saved", Payload: `{}`, Status: webhooks.StatusPending, NextAttempt: &due, Created: created, Updated: created}}))
+
+		require.NoError(t, store.DeleteWebhook(ctx, 1, "ci"))
+		_, err := store.GetWebhook(ctx, 1, "ci")
+		assert.ErrorIs(t, err, webhooks.ErrWebhookNotFound)
+		result, err := store.SearchDeliveries(ctx, &webhooks.SearchDeliveriesQuery{OrgID: 1})
+		require.NoError(t, err)
+		assert.Zero(t, result.TotalCount)
+
+		assert.ErrorIs(t, store.DeleteWebhook(ctx, 1, "ci"), webhooks.ErrWebhookNotFound)
+		_, err = store.GetWebhook(ctx, 2, "ci")
+		assert.NoError(t, err, "the webhooks of the other orgs are kept")
+	})
+}
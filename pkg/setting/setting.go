@@ -116,6 +116,10 @@ type Cfg struct {
 	// Security settings
 	SecretKey             string
 	EmailCodeValidMinutes int
+	// SecretAccessTrackingEnabled records the decryptions of data source and contact point secrets
+	SecretAccessTrackingEnabled bool
+	SecretAccessRetention       time.Duration
+	SecretUnusedPeriod          time.Duration
 
 	// build
 	BuildVersion          string
@@ -132,6 +136,8 @@ type Cfg struct {
 	HomePath                   string
 	ProvisioningPath           string
 	PermittedProvisioningPaths []string
+	ProvisioningSources        []ProvisioningSource
+	ProvisioningSecrets        ProvisioningSecretsSettings
 	// Job History Configuration
 	ProvisioningLokiURL      string
 	ProvisioningLokiUser     string
@@ -157,6 +163,25 @@ type Cfg struct {
 	RendererDefaultImageWidth      int
 	RendererDefaultImageHeight     int
 	RendererDefaultImageScale      float64
+	// RendererConcurrentRequestLimitPerOrg is the number of concurrent renders of an organization, 0 for no limit
+	RendererConcurrentRequestLimitPerOrg int
+	// RendererQueueSize is the number of renders waiting for a slot once the concurrent limits are reached,
+	// 0 to reject them immediately
+	RendererQueueSize           int
+	RendererQueueTimeout        time.Duration
+	RendererHealthCheckInterval time.Duration
+	// RendererCacheTTL is how long a rendered panel is reused for identical renders, 0 disables the cache
+	RendererCacheTTL time.Duration
+	// RendererCacheURLExpiry is how long the signed URLs of the cached renders are valid
+	RendererCacheURLExpiry time.Duration
+	// RendererStrategies are the renderers tried in order, a render fails over to the next one when a renderer fails
+	RendererStrategies []string
+	// RendererFailoverThreshold is the number of consecutive failures after which a renderer is skipped for
+	// RendererFailoverCooldown
+	RendererFailoverThreshold int
+	RendererFailoverCooldown  time.Duration
+	// RendererBuiltinPanelTypes are the panel types the builtin renderer draws without a browser
+	RendererBuiltinPanelTypes []string
 
 	// Security
 	DisableInitAdminCreation             bool
@@ -186,6 +211,19 @@ type Cfg struct {
 	DisableGravatar                 bool
 	DataProxyWhiteList              map[string]bool
 	ActionsAllowPostURL             string
+	IPAllowlist                     IPAllowlistSettings
+	AuditLog                        AuditLogSettings
+	UserActivity                    UserActivitySettings
+	Branding                        BrandingSettings
+	CapabilityTokens                CapabilityTokensSettings
+	Webhooks                        WebhooksSettings
+	RateLimit                       RateLimitSettings
+	RecentTraces                    RecentTracesSettings
+	Profiling                       ProfilingSettings
+	Health                          HealthSettings
+	TeamSync                        TeamSyncSettings
+	Playlists                       PlaylistSettings
+	Correlations                    CorrelationsSettings
 
 	// K8s Dashboard Cleanup
 	K8sDashboardCleanup K8sDashboardCleanupSettings
@@ -211,6 +249,10 @@ type Cfg struct {
 
 	PluginsCDNURLTemplate    string
 	PluginLogBackendRequests bool
+	// PluginRequestAttribution labels the plugin request metrics with the dashboard, or the dashboard and the panel,
+	// of the queries. PluginRequestAttributionMaxSeries bounds the number of attributed series.
+	PluginRequestAttribution          string
+	PluginRequestAttributionMaxSeries int
 
 	PluginUpdateStrategy string
 
@@ -228,6 +270,7 @@ type Cfg struct {
 	MetricsIncludeTeamLabel          bool
 	MetricsTotalStatsIntervalSeconds int
 	MetricsGrafanaEnvironmentInfo    map[string]string
+	TenantMetrics                    TenantMetricsSettings
 
 	// Dashboards
 	DashboardVersionsToKeep     int
@@ -278,15 +321,21 @@ type Cfg struct {
 	OAuthCookieMaxAge                    int
 	OAuthAllowInsecureEmailLookup        bool
 	OAuthRefreshTokenServerLockMinWaitMs int64
+	OAuthTokenRefreshWindow              time.Duration
 
 	JWTAuth    AuthJWTSettings
 	ExtJWTAuth ExtJWTSettings
+	MTLSAuth   AuthMTLSSettings
 
 	PasswordlessMagicLinkAuth AuthPasswordlessMagicLinkSettings
+	MFAAuth                   AuthMFASettings
+	ImpersonationAuth         AuthImpersonationSettings
+	OrgAuthPolicy             OrgAuthPolicySettings
 
 	// SSO Settings Auth
 	SSOSettingsReloadInterval        time.Duration
 	SSOSettingsConfigurableProviders map[string]bool
+	SSOSettingsHistoryLimit          int
 
 	// Dataproxy
 	SendUserHeader                 bool
@@ -328,7 +377,14 @@ type Cfg struct {
 	VerificationEmailMaxLifetime time.Duration
 
 	// Service Accounts
-	SATokenExpirationDayLimit int
+	SATokenExpirationDayLimit  int
+	SATokenExpiryCheckInterval time.Duration
+	SATokenExpiryWarningPeriod time.Duration
+	SATokenExpiryWebhookURL    string
+	SATokenRotationOverlap     time.Duration
+	SATokenUsageEnabled        bool
+	SATokenUsageRetention      time.Duration
+	SATokenUnusedPeriod        time.Duration
 
 	// Annotations
 	AnnotationCleanupJobBatchSize      int64
@@ -336,6 +392,11 @@ type Cfg struct {
 	AlertingAnnotationCleanupSetting   AnnotationCleanupSettings
 	DashboardAnnotationCleanupSettings AnnotationCleanupSettings
 	APIAnnotationCleanupSettings       AnnotationCleanupSettings
+	AnnotationCleanupJobInterval       time.Duration
+	AnnotationCleanupJobBatchPause     time.Duration
+	AnnotationRetentionPolicies        []AnnotationRetentionPolicy
+	AnnotationPartitioning             AnnotationPartitioningSettings
+	AnnotationIngestIntegrations       []AnnotationIngestIntegration
 
 	// GrafanaJavascriptAgent config
 	GrafanaJavascriptAgent GrafanaJavascriptAgent
@@ -350,6 +411,9 @@ type Cfg struct {
 	// Default behavior for the "Allow as recording rules target" toggle when configuring a data source.
 	// It only works if the data source's `jsonData.allowAsRecordingRulesTarget` prop does not contain a previously configured value.
 	DefaultAllowRecordingRulesTargetAlertsUIToggle bool
+	// How long the secrets of the external secret managers referenced by the secure settings of the data sources,
+	// like $__vault{kv/data/prometheus#password}, are cached before being resolved again.
+	DataSourceSecretReferenceCacheTTL time.Duration
 
 	// IP range access control
 	IPRangeACEnabled     bool
@@ -366,6 +430,19 @@ type Cfg struct {
 	ExternalSnapshotUrl  string
 	ExternalSnapshotName string
 	ExternalEnabled      bool
+	// SnapshotStorage is where the dashboards of the snapshots are stored, database or object
+	SnapshotStorage    string
+	SnapshotStorageURL string
+	// SnapshotMaxSize is the maximum size in bytes of the dashboard of a snapshot, 0 for no limit
+	SnapshotMaxSize int64
+	// SnapshotMaxAge is the age after which the snapshots are deleted even if they haven't expired, 0 to keep them
+	// until they expire
+	SnapshotMaxAge            time.Duration
+	SnapshotRetentionPolicies []SnapshotRetentionPolicy
+	// SnapshotSanitizeRules are the rules removing sensitive values from the dashboards of the snapshots
+	SnapshotSanitizeRules []string
+	// SnapshotSanitizeKeys are the keys removed from the dashboards of the snapshots wherever they're found
+	SnapshotSanitizeKeys []string
 
 	// Only used in https://snapshots.raintank.io/
 	SnapshotPublicMode bool
@@ -444,6 +521,12 @@ type Cfg struct {
 	// SQLExpressionTimeoutSeconds is the duration a SQL expression will run before timing out
 	SQLExpressionTimeout time.Duration
 
+	// ExpressionsResultCacheTTL is how long the results of the expressions are cached. 0 disables the cache.
+	ExpressionsResultCacheTTL time.Duration
+
+	// ExpressionsResultCacheMaxEntries is the maximum number of expression results cached.
+	ExpressionsResultCacheMaxEntries int
+
 	ImageUploadProvider string
 
 	// LiveMaxConnections is a maximum number of WebSocket connections to
@@ -455,9 +538,25 @@ type Cfg struct {
 	LiveHAEngine string
 	// LiveHAPRefix is a prefix for HA engine keys.
 	LiveHAPrefix string
-	// LiveHAEngineAddress is a connection address for Live HA engine.
+	// LiveHAEngineAddress is a connection address for Live HA engine, a
+	// Redis address or a comma-separated list of NATS server URLs.
 	LiveHAEngineAddress  string
 	LiveHAEnginePassword string
+	// LivePipelineEnabled enables the Live pipeline, which processes data pushed
+	// to channels with the channel rules stored in the database.
+	LivePipelineEnabled bool
+	// LiveHistorySize is the number of recent publications kept per channel
+	// and sent to new subscribers. Zero disables the history.
+	LiveHistorySize int
+	// LiveHistoryTTL is how long publications are kept in the channel history.
+	LiveHistoryTTL time.Duration
+	// LiveHistoryChannels restricts the history to the channels matching the
+	// patterns, each pattern optionally overriding the history size and TTL.
+	LiveHistoryChannels []string
+	// LiveAuthorization is the authorization policy of each channel scope,
+	// "rbac" or "plugin:<plugin_id>". Scopes without policy only rely on the
+	// checks of the channel handlers.
+	LiveAuthorization map[string]string
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
@@ -526,11 +625,25 @@ type Cfg struct {
 	DatabaseInstrumentQueries bool
 
 	// Public dashboards
-	PublicDashboardsEnabled bool
+	PublicDashboardsEnabled                       bool
+	PublicDashboardsEmailVerificationCodeLifetime time.Duration
+	PublicDashboardsEmailSessionLifetime          time.Duration
+	PublicDashboardsQueryRateLimit                int64
+	PublicDashboardsChallengeProvider             string
+	PublicDashboardsChallengeSiteKey              string
+	PublicDashboardsChallengeSecretKey            string
+	PublicDashboardsChallengeVerifyURL            string
+	PublicDashboardsChallengeThreshold            int64
+	PublicDashboardsChallengeLifetime             time.Duration
+	PublicDashboardsQueryAuditEnabled             bool
+	PublicDashboardsQueryAuditRetention           time.Duration
 
 	// Cloud Migration
 	CloudMigration CloudMigrationSettings
 
+	// Instance sync
+	InstanceSync InstanceSyncSettings
+
 	// Feature Management Settings
 	FeatureManagement FeatureMgmtSettings
 
@@ -820,6 +933,82 @@ func (cfg *Cfg) readAnnotationSettings() error {
 
 	cfg.DashboardAnnotationCleanupSettings = newAnnotationCleanupSettings(dashboardAnnotation, "max_age")
 	cfg.APIAnnotationCleanupSettings = newAnnotationCleanupSettings(apiIAnnotation, "max_age")
+	cfg.AnnotationCleanupJobInterval = section.Key("cleanupjob_interval").MustDuration(10 * time.Minute)
+	if cfg.AnnotationCleanupJobInterval <= 0 {
+		cfg.AnnotationCleanupJobInterval = 10 * time.Minute
+	}
+	cfg.AnnotationCleanupJobBatchPause = section.Key("cleanupjob_batch_pause").MustDuration(0)
+
+	cfg.AnnotationRetentionPolicies = nil
+	for _, policySection := range cfg.Raw.Sections() {
+		name, ok := strings.CutPrefix(policySection.Name(), "annotations.retention.")
+		if !ok {
+			continue
+		}
+		policy := AnnotationRetentionPolicy{
+			Name:                      name,
+			OrgID:                     policySection.Key("org_id").MustInt64(0),
+			Type:                      policySection.Key("type").String(),
+			AnnotationCleanupSettings: newAnnotationCleanupSettings(policySection, "max_age"),
+		}
+		if policy.OrgID <= 0 {
+			return fmt.Errorf("[annotations.retention.%s] org_id must be set", name)
+		}
+		switch policy.Type {
+		case AnnotationTypeAlert, AnnotationTypeDashboard, AnnotationTypeAPI:
+		default:
+			return fmt.Errorf("[annotations.retention.%s] type must be one of %s, %s or %s", name, AnnotationTypeAlert, AnnotationTypeDashboard, AnnotationTypeAPI)
+		}
+		cfg.AnnotationRetentionPolicies = append(cfg.AnnotationRetentionPolicies, policy)
+	}
+
+	cfg.AnnotationIngestIntegrations = nil
+	for _, integrationSection := range cfg.Raw.Sections() {
+		name, ok := strings.CutPrefix(integrationSection.Name(), "annotations.ingest.")
+		if !ok {
+			continue
+		}
+		integration := AnnotationIngestIntegration{
+			Name:      name,
+			OrgID:     integrationSection.Key("org_id").MustInt64(0),
+			Source:    integrationSection.Key("source").MustString(AnnotationIngestSourceGeneric),
+			Secret:    integrationSection.Key("secret").String(),
+			Condition: integrationSection.Key("condition").String(),
+			Text:      integrationSection.Key("text").String(),
+			Time:      integrationSection.Key("time").String(),
+			TimeEnd:   integrationSection.Key("time_end").String(),
+			DedupKey:  integrationSection.Key("dedup_key").String(),
+		}
+		// the tags are split on commas only, the templates can have spaces
+		for _, tag := range strings.Split(integrationSection.Key("tags").String(), ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				integration.Tags = append(integration.Tags, tag)
+			}
+		}
+		if integration.OrgID <= 0 {
+			return fmt.Errorf("[annotations.ingest.%s] org_id must be set", name)
+		}
+		if integration.Secret == "" {
+			return fmt.Errorf("[annotations.ingest.%s] secret must be set", name)
+		}
+		switch integration.Source {
+		case AnnotationIngestSourceGitHub, AnnotationIngestSourceJenkins, AnnotationIngestSourcePagerDuty, AnnotationIngestSourceGeneric:
+		default:
+			return fmt.Errorf("[annotations.ingest.%s] source must be one of %s, %s, %s or %s", name,
+				AnnotationIngestSourceGitHub, AnnotationIngestSourceJenkins, AnnotationIngestSourcePagerDuty, AnnotationIngestSourceGeneric)
+		}
+		cfg.AnnotationIngestIntegrations = append(cfg.AnnotationIngestIntegrations, integration)
+	}
+
+	partitioning := cfg.Raw.Section("annotations.partitioning")
+	cfg.AnnotationPartitioning = AnnotationPartitioningSettings{
+		Enabled:  partitioning.Key("enabled").MustBool(false),
+		Interval: partitioning.Key("interval").In(AnnotationPartitionMonth, []string{AnnotationPartitionDay, AnnotationPartitionWeek, AnnotationPartitionMonth}),
+		Ahead:    partitioning.Key("partitions_ahead").MustInt(2),
+	}
+	if cfg.AnnotationPartitioning.Ahead < 1 {
+		cfg.AnnotationPartitioning.Ahead = 1
+	}
 
 	return nil
 }
@@ -830,6 +1019,8 @@ func (cfg *Cfg) readExpressionsSettings() {
 	cfg.SQLExpressionCellLimit = expressions.Key("sql_expression_cell_limit").MustInt64(100000)
 	cfg.SQLExpressionOutputCellLimit = expressions.Key("sql_expression_output_cell_limit").MustInt64(100000)
 	cfg.SQLExpressionTimeout = expressions.Key("sql_expression_timeout").MustDuration(time.Second * 10)
+	cfg.ExpressionsResultCacheTTL = expressions.Key("result_cache_ttl").MustDuration(0)
+	cfg.ExpressionsResultCacheMaxEntries = expressions.Key("result_cache_max_entries").MustInt(1000)
 }
 
 type AnnotationCleanupSettings struct {
@@ -837,6 +1028,65 @@ type AnnotationCleanupSettings struct {
 	MaxCount int64
 }
 
+// Types of annotations the retention policies apply to
+const (
+	AnnotationTypeAlert     = "alert"
+	AnnotationTypeDashboard = "dashboard"
+	AnnotationTypeAPI       = "api"
+)
+
+// AnnotationRetentionPolicy replaces the cleanup settings of an annotation type for an organization
+type AnnotationRetentionPolicy struct {
+	AnnotationCleanupSettings
+	Name  string
+	OrgID int64
+	Type  string
+}
+
+// Sources of the annotation ingest integrations
+const (
+	AnnotationIngestSourceGitHub    = "github"
+	AnnotationIngestSourceJenkins   = "jenkins"
+	AnnotationIngestSourcePagerDuty = "pagerduty"
+	AnnotationIngestSourceGeneric   = "generic"
+)
+
+// AnnotationIngestIntegration maps the events posted by an external system to annotations of an organization. The
+// templates are executed with the JSON body of the events, the empty ones use the defaults of the source.
+type AnnotationIngestIntegration struct {
+	Name  string
+	OrgID int64
+	// Source sets how the events are signed and the default templates
+	Source string
+	// Secret is the key of the HMAC-SHA256 signature of the events
+	Secret string
+	// Condition skips the events for which it isn't rendered to true
+	Condition string
+	Text      string
+	Tags      []string
+	// Time and TimeEnd are rendered to an RFC 3339 time or a Unix time in seconds or milliseconds
+	Time    string
+	TimeEnd string
+	// DedupKey identifies the events of the same annotation, the annotation is updated by the next events
+	DedupKey string
+}
+
+// Time ranges of the annotation partitions
+const (
+	AnnotationPartitionDay   = "day"
+	AnnotationPartitionWeek  = "week"
+	AnnotationPartitionMonth = "month"
+)
+
+type AnnotationPartitioningSettings struct {
+	// Enabled partitions the annotation table by creation time on Postgres and MySQL
+	Enabled bool
+	// Interval is the time range of a partition: day, week or month
+	Interval string
+	// Ahead is the number of partitions created in advance
+	Ahead int
+}
+
 func EnvKey(sectionName string, keyName string) string {
 	sN := strings.ToUpper(strings.ReplaceAll(sectionName, ".", "_"))
 	sN = strings.ReplaceAll(sN, "-", "_")
@@ -946,6 +1196,28 @@ func (cfg *Cfg) loadSpecifiedConfigFile(configFile string, masterFile *ini.File)
 }
 
 func (cfg *Cfg) loadConfiguration(args CommandLineArgs) (*ini.File, error) {
+	parsedFile, err := cfg.mergeConfiguration(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// update data path and logging config
+	dataPath := valueAsString(parsedFile.Section("paths"), "data", "")
+
+	cfg.DataPath = makeAbsolute(dataPath, cfg.HomePath)
+	err = cfg.initLogging(parsedFile)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Logger.Info(fmt.Sprintf("Starting %s", ApplicationName), "version", BuildVersion, "commit", BuildCommit, "branch", BuildBranch, "compiled", time.Unix(BuildStamp, 0))
+
+	return parsedFile, err
+}
+
+// mergeConfiguration merges the defaults, the command line defaults, the config file, the environment variables
+// and the command line overrides, and expands the values.
+func (cfg *Cfg) mergeConfiguration(args CommandLineArgs) (*ini.File, error) {
 	// load config defaults
 	defaultConfigFile := path.Join(cfg.HomePath, "conf/defaults.ini")
 	cfg.configFiles = append(cfg.configFiles, defaultConfigFile)
@@ -995,18 +1267,7 @@ func (cfg *Cfg) loadConfiguration(args CommandLineArgs) (*ini.File, error) {
 		return nil, err
 	}
 
-	// update data path and logging config
-	dataPath := valueAsString(parsedFile.Section("paths"), "data", "")
-
-	cfg.DataPath = makeAbsolute(dataPath, cfg.HomePath)
-	err = cfg.initLogging(parsedFile)
-	if err != nil {
-		return nil, err
-	}
-
-	cfg.Logger.Info(fmt.Sprintf("Starting %s", ApplicationName), "version", BuildVersion, "commit", BuildCommit, "branch", BuildBranch, "compiled", time.Unix(BuildStamp, 0))
-
-	return parsedFile, err
+	return parsedFile, nil
 }
 
 func pathExists(path string) bool {
@@ -1176,6 +1437,60 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 		return err
 	}
 
+	if err := cfg.readIPAllowlistSettings(); err != nil {
+		return err
+	}
+
+	if err := cfg.readAuditLogSettings(); err != nil {
+		return err
+	}
+
+	if err := cfg.readUserActivitySettings(); err != nil {
+		return err
+	}
+
+	cfg.readBrandingSettings()
+
+	if err := cfg.readCapabilityTokensSettings(); err != nil {
+		return err
+	}
+
+	if err := cfg.readWebhooksSettings(); err != nil {
+		return err
+	}
+
+	if err := cfg.readRateLimitSettings(); err != nil {
+		return err
+	}
+
+	if err := cfg.readRecentTracesSettings(); err != nil {
+		return err
+	}
+
+	if err := cfg.readTenantMetricsSettings(); err != nil {
+		return err
+	}
+
+	if err := cfg.readProfilingSettings(); err != nil {
+		return err
+	}
+
+	if err := cfg.readHealthSettings(); err != nil {
+		return err
+	}
+
+	if err := cfg.readTeamSyncSettings(); err != nil {
+		return err
+	}
+
+	if err := cfg.readPlaylistSettings(); err != nil {
+		return err
+	}
+
+	if err := cfg.readCorrelationsSettings(); err != nil {
+		return err
+	}
+
 	if err := readSnapshotsSettings(cfg, iniFile); err != nil {
 		return err
 	}
@@ -1321,9 +1636,13 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	cfg.readAzureSettings()
 	cfg.readAuthJWTSettings()
 	cfg.readAuthExtJWTSettings()
+	cfg.readAuthMTLSSettings()
 	cfg.readAuthProxySettings()
 	cfg.readSessionConfig()
 	cfg.readPasswordlessMagicLinkSettings()
+	cfg.readAuthMFASettings()
+	cfg.readAuthImpersonationSettings()
+	cfg.readOrgAuthPolicySettings()
 	if err := cfg.readSmtpSettings(); err != nil {
 		return err
 	}
@@ -1408,6 +1727,7 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	cfg.readFeatureManagementConfig()
 	cfg.readPublicDashboardsSettings()
 	cfg.readCloudMigrationSettings()
+	cfg.readInstanceSyncSettings()
 	cfg.readSecretsManagerSettings()
 
 	// read experimental scopes settings.
@@ -1666,6 +1986,10 @@ func readSecuritySettings(iniFile *ini.File, cfg *Cfg) error {
 	cfg.AdminPassword = valueAsString(security, "admin_password", "")
 	cfg.AdminEmail = valueAsString(security, "admin_email", fmt.Sprintf("%s@localhost", cfg.AdminUser))
 
+	cfg.SecretAccessTrackingEnabled = security.Key("secret_access_tracking_enabled").MustBool(true)
+	cfg.SecretAccessRetention = security.Key("secret_access_retention").MustDuration(90 * 24 * time.Hour)
+	cfg.SecretUnusedPeriod = security.Key("secret_unused_period").MustDuration(90 * 24 * time.Hour)
+
 	return nil
 }
 
@@ -1709,6 +2033,7 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 	cfg.OAuthLoginErrorMessage = valueAsString(auth, "oauth_login_error_message", "oauth.login.error")
 	cfg.OAuthCookieMaxAge = auth.Key("oauth_state_cookie_max_age").MustInt(600)
 	cfg.OAuthRefreshTokenServerLockMinWaitMs = auth.Key("oauth_refresh_token_server_lock_min_wait_ms").MustInt64(1000)
+	cfg.OAuthTokenRefreshWindow = auth.Key("oauth_token_refresh_window").MustDuration(time.Minute)
 	cfg.SignoutRedirectUrl = valueAsString(auth, "signout_redirect_url", "")
 
 	// Deprecated
@@ -1750,6 +2075,7 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 	for _, provider := range util.SplitString(providers) {
 		cfg.SSOSettingsConfigurableProviders[provider] = true
 	}
+	cfg.SSOSettingsHistoryLimit = ssoSettings.Key("history_limit").MustInt(20)
 
 	// Managed Service Accounts
 	cfg.ManagedServiceAccountsEnabled = auth.Key("managed_service_accounts_enabled").MustBool(false)
@@ -1848,6 +2174,13 @@ func readUserSettings(iniFile *ini.File, cfg *Cfg) error {
 func readServiceAccountSettings(iniFile *ini.File, cfg *Cfg) error {
 	serviceAccount := iniFile.Section("service_accounts")
 	cfg.SATokenExpirationDayLimit = serviceAccount.Key("token_expiration_day_limit").MustInt(-1)
+	cfg.SATokenExpiryCheckInterval = serviceAccount.Key("token_expiry_check_interval").MustDuration(time.Hour)
+	cfg.SATokenExpiryWarningPeriod = serviceAccount.Key("token_expiry_warning_period").MustDuration(7 * 24 * time.Hour)
+	cfg.SATokenExpiryWebhookURL = serviceAccount.Key("token_expiry_webhook_url").MustString("")
+	cfg.SATokenRotationOverlap = serviceAccount.Key("token_rotation_overlap").MustDuration(24 * time.Hour)
+	cfg.SATokenUsageEnabled = serviceAccount.Key("token_usage_enabled").MustBool(true)
+	cfg.SATokenUsageRetention = serviceAccount.Key("token_usage_retention").MustDuration(90 * 24 * time.Hour)
+	cfg.SATokenUnusedPeriod = serviceAccount.Key("token_unused_period").MustDuration(90 * 24 * time.Hour)
 	return nil
 }
 
@@ -1858,6 +2191,16 @@ func (cfg *Cfg) readRenderingSettings(iniFile *ini.File) {
 	cfg.RendererAuthToken = valueAsString(renderSec, "renderer_token", "-")
 
 	cfg.RendererConcurrentRequestLimit = renderSec.Key("concurrent_render_request_limit").MustInt(30)
+	cfg.RendererConcurrentRequestLimitPerOrg = renderSec.Key("concurrent_render_request_limit_per_org").MustInt(0)
+	cfg.RendererQueueSize = renderSec.Key("render_queue_size").MustInt(0)
+	cfg.RendererQueueTimeout = renderSec.Key("render_queue_timeout").MustDuration(30 * time.Second)
+	cfg.RendererHealthCheckInterval = renderSec.Key("server_health_check_interval").MustDuration(30 * time.Second)
+	cfg.RendererCacheTTL = renderSec.Key("render_cache_ttl").MustDuration(0)
+	cfg.RendererCacheURLExpiry = renderSec.Key("render_cache_url_expiry").MustDuration(24 * time.Hour)
+	cfg.RendererStrategies = util.SplitString(renderSec.Key("strategies").MustString("remote,plugin"))
+	cfg.RendererFailoverThreshold = renderSec.Key("failover_threshold").MustInt(3)
+	cfg.RendererFailoverCooldown = renderSec.Key("failover_cooldown").MustDuration(time.Minute)
+	cfg.RendererBuiltinPanelTypes = util.SplitString(renderSec.Key("builtin_panel_types").MustString("text"))
 	cfg.RendererRenderKeyLifeTime = renderSec.Key("render_key_lifetime").MustDuration(5 * time.Minute)
 	cfg.RendererDefaultImageWidth = renderSec.Key("default_image_width").MustInt(1000)
 	cfg.RendererDefaultImageHeight = renderSec.Key("default_image_height").MustInt(500)
@@ -1889,9 +2232,88 @@ func readSnapshotsSettings(cfg *Cfg, iniFile *ini.File) error {
 	cfg.ExternalEnabled = snapshots.Key("external_enabled").MustBool(true)
 	cfg.SnapshotPublicMode = snapshots.Key("public_mode").MustBool(false)
 
+	cfg.SnapshotStorage = snapshots.Key("storage").In(SnapshotStorageDatabase, []string{SnapshotStorageDatabase, SnapshotStorageObject})
+	cfg.SnapshotStorageURL = valueAsString(snapshots, "storage_url", "")
+	if cfg.SnapshotStorage == SnapshotStorageObject && cfg.SnapshotStorageURL == "" {
+		return fmt.Errorf("[snapshots] storage_url must be set when the storage is %s", SnapshotStorageObject)
+	}
+	cfg.SnapshotMaxSize = snapshots.Key("max_size").MustInt64(0)
+
+	parseMaxAge := func(section *ini.Section) (time.Duration, error) {
+		value := section.Key("max_age").String()
+		if value == "" {
+			return 0, nil
+		}
+		maxAge, err := gtime.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("[%s] invalid max_age: %w", section.Name(), err)
+		}
+		return maxAge, nil
+	}
+	var err error
+	if cfg.SnapshotMaxAge, err = parseMaxAge(snapshots); err != nil {
+		return err
+	}
+
+	cfg.SnapshotSanitizeRules = util.SplitString(snapshots.Key("sanitize_rules").String())
+	for _, rule := range cfg.SnapshotSanitizeRules {
+		switch rule {
+		case SnapshotSanitizeVariables, SnapshotSanitizeDatasources, SnapshotSanitizeUsers, SnapshotSanitizeLinks:
+		default:
+			return fmt.Errorf("[snapshots] invalid sanitize rule %q, must be one of %s, %s, %s or %s", rule,
+				SnapshotSanitizeVariables, SnapshotSanitizeDatasources, SnapshotSanitizeUsers, SnapshotSanitizeLinks)
+		}
+	}
+	cfg.SnapshotSanitizeKeys = util.SplitString(snapshots.Key("sanitize_keys").String())
+
+	cfg.SnapshotRetentionPolicies = nil
+	for _, policySection := range iniFile.Sections() {
+		name, ok := strings.CutPrefix(policySection.Name(), "snapshots.retention.")
+		if !ok {
+			continue
+		}
+		policy := SnapshotRetentionPolicy{
+			Name:  name,
+			OrgID: policySection.Key("org_id").MustInt64(0),
+		}
+		if policy.OrgID <= 0 {
+			return fmt.Errorf("[snapshots.retention.%s] org_id must be set", name)
+		}
+		if policy.MaxAge, err = parseMaxAge(policySection); err != nil {
+			return err
+		}
+		cfg.SnapshotRetentionPolicies = append(cfg.SnapshotRetentionPolicies, policy)
+	}
+
 	return nil
 }
 
+// Storages of the dashboards of the snapshots
+const (
+	SnapshotStorageDatabase = "database"
+	SnapshotStorageObject   = "object"
+)
+
+// Rules of the sanitizer of the dashboards of the snapshots
+const (
+	// SnapshotSanitizeVariables removes the values and options of the template variables
+	SnapshotSanitizeVariables = "variables"
+	// SnapshotSanitizeDatasources removes the UIDs and names of the data sources
+	SnapshotSanitizeDatasources = "datasources"
+	// SnapshotSanitizeUsers removes the logins, emails and names of the users
+	SnapshotSanitizeUsers = "users"
+	// SnapshotSanitizeLinks removes the dashboard, panel and data links
+	SnapshotSanitizeLinks = "links"
+)
+
+// SnapshotRetentionPolicy replaces the max age of the snapshots for an organization, a max age of 0 keeps its
+// snapshots until they expire
+type SnapshotRetentionPolicy struct {
+	Name   string
+	OrgID  int64
+	MaxAge time.Duration
+}
+
 func (cfg *Cfg) readServerSettings(iniFile *ini.File) error {
 	server := iniFile.Section("server")
 	var err error
@@ -1987,6 +2409,7 @@ func (cfg *Cfg) readDataSourcesSettings() {
 	cfg.ConcurrentQueryCount = datasources.Key("concurrent_query_count").MustInt(10)
 	cfg.DefaultDatasourceManageAlertsUIToggle = datasources.Key("default_manage_alerts_ui_toggle").MustBool(true)
 	cfg.DefaultAllowRecordingRulesTargetAlertsUIToggle = datasources.Key("default_allow_recording_rules_target_alerts_ui_toggle").MustBool(true)
+	cfg.DataSourceSecretReferenceCacheTTL = datasources.Key("secret_reference_cache_ttl").MustDuration(5 * time.Minute)
 }
 
 func (cfg *Cfg) readDataSourceSecuritySettings() {
@@ -2040,13 +2463,37 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	}
 	cfg.LiveHAEngine = section.Key("ha_engine").MustString("")
 	switch cfg.LiveHAEngine {
-	case "", "redis":
+	case "", "redis", "nats":
 	default:
 		return fmt.Errorf("unsupported live HA engine type: %s", cfg.LiveHAEngine)
 	}
 	cfg.LiveHAPrefix = section.Key("ha_prefix").MustString("")
 	cfg.LiveHAEngineAddress = section.Key("ha_engine_address").MustString("127.0.0.1:6379")
 	cfg.LiveHAEnginePassword = section.Key("ha_engine_password").MustString("")
+	cfg.LivePipelineEnabled = section.Key("pipeline_enabled").MustBool(false)
+	cfg.LiveHistorySize = section.Key("history_size").MustInt(0)
+	if cfg.LiveHistorySize < 0 {
+		return fmt.Errorf("unexpected value %d for [live] history_size", cfg.LiveHistorySize)
+	}
+	cfg.LiveHistoryTTL = section.Key("history_ttl").MustDuration(10 * time.Minute)
+	if cfg.LiveHistoryTTL <= 0 {
+		return fmt.Errorf("unexpected value %s for [live] history_ttl", cfg.LiveHistoryTTL)
+	}
+	cfg.LiveHistoryChannels = util.SplitString(section.Key("history_channels").MustString(""))
+
+	cfg.LiveAuthorization = map[string]string{}
+	for _, key := range iniFile.Section("live.authorization").Keys() {
+		policy := strings.TrimSpace(key.String())
+		switch {
+		case policy == "" || policy == "default":
+			continue
+		case policy == "rbac":
+		case strings.HasPrefix(policy, "plugin:") && len(policy) > len("plugin:"):
+		default:
+			return fmt.Errorf("unsupported [live.authorization] policy %q for scope %s", policy, key.Name())
+		}
+		cfg.LiveAuthorization[key.Name()] = policy
+	}
 
 	allowedOrigins := section.Key("allowed_origins").MustString("")
 	origins := strings.Split(allowedOrigins, ",")
@@ -2085,6 +2532,13 @@ func (cfg *Cfg) readProvisioningSettings(iniFile *ini.File) error {
 		}
 	}
 
+	sources, err := readProvisioningSources(iniFile, cfg.HomePath)
+	if err != nil {
+		return err
+	}
+	cfg.ProvisioningSources = sources
+	cfg.ProvisioningSecrets = readProvisioningSecretsSettings(iniFile)
+
 	// Read job history configuration
 	cfg.ProvisioningLokiURL = valueAsString(iniFile.Section("provisioning"), "loki_url", "")
 	cfg.ProvisioningLokiUser = valueAsString(iniFile.Section("provisioning"), "loki_user", "")
@@ -2097,6 +2551,17 @@ func (cfg *Cfg) readProvisioningSettings(iniFile *ini.File) error {
 func (cfg *Cfg) readPublicDashboardsSettings() {
 	publicDashboards := cfg.Raw.Section("public_dashboards")
 	cfg.PublicDashboardsEnabled = publicDashboards.Key("enabled").MustBool(true)
+	cfg.PublicDashboardsEmailVerificationCodeLifetime = publicDashboards.Key("email_verification_code_lifetime").MustDuration(15 * time.Minute)
+	cfg.PublicDashboardsEmailSessionLifetime = publicDashboards.Key("email_session_lifetime").MustDuration(24 * time.Hour)
+	cfg.PublicDashboardsQueryRateLimit = publicDashboards.Key("query_rate_limit").MustInt64(120)
+	cfg.PublicDashboardsChallengeProvider = valueAsString(publicDashboards, "challenge_provider", "")
+	cfg.PublicDashboardsChallengeSiteKey = valueAsString(publicDashboards, "challenge_site_key", "")
+	cfg.PublicDashboardsChallengeSecretKey = valueAsString(publicDashboards, "challenge_secret_key", "")
+	cfg.PublicDashboardsChallengeVerifyURL = valueAsString(publicDashboards, "challenge_verify_url", "")
+	cfg.PublicDashboardsChallengeThreshold = publicDashboards.Key("challenge_threshold").MustInt64(600)
+	cfg.PublicDashboardsChallengeLifetime = publicDashboards.Key("challenge_lifetime").MustDuration(time.Hour)
+	cfg.PublicDashboardsQueryAuditEnabled = publicDashboards.Key("query_audit_enabled").MustBool(true)
+	cfg.PublicDashboardsQueryAuditRetention = publicDashboards.Key("query_audit_retention").MustDuration(30 * 24 * time.Hour)
 }
 
 func (cfg *Cfg) DefaultOrgID() int64 {
@@ -6,6 +6,8 @@ type AnonymousSettings struct {
 	OrgRole     string
 	HideVersion bool
 	DeviceLimit int64
+	// DeviceLimitWarningThreshold is the percentage of the device limit of an org that triggers warnings
+	DeviceLimitWarningThreshold int64
 }
 
 func (cfg *Cfg) readAnonymousSettings() {
@@ -22,5 +24,6 @@ func (cfg *Cfg) readAnonymousSettings() {
 	}
 	anonSettings.HideVersion = anonSection.Key("hide_version").MustBool(false)
 	anonSettings.DeviceLimit = anonSection.Key("device_limit").MustInt64(0)
+	anonSettings.DeviceLimitWarningThreshold = anonSection.Key("device_limit_warning_threshold").MustInt64(80)
 	cfg.Anonymous = anonSettings
 }
@@ -0,0 +1,74 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type AuditLogSettings struct {
+	// Enabled records audit events in the database and sends them to the sinks
+	Enabled bool
+	// Retention is how long events are kept in the database, 0 keeps them forever
+	Retention time.Duration
+	// Sinks are the additional destinations of the events
+	Sinks       []string
+	BufferSize  int
+	SinkTimeout time.Duration
+
+	// FilePath defaults to audit.log in the logs directory
+	FilePath string
+
+	LokiURL      string
+	LokiUser     string
+	LokiPassword string
+	LokiTenantID string
+
+	WebhookURL    string
+	WebhookSecret string
+}
+
+func (cfg *Cfg) readAuditLogSettings() error {
+	section := cfg.SectionWithEnvOverrides("audit")
+	auditLog := AuditLogSettings{}
+	auditLog.Enabled = section.Key("enabled").MustBool(false)
+
+	retention, err := gtime.ParseDuration(section.Key("retention").MustString("90d"))
+	if err != nil {
+		return fmt.Errorf("invalid retention in [audit]: %w", err)
+	}
+	auditLog.Retention = retention
+
+	auditLog.Sinks = util.SplitString(section.Key("sinks").MustString(""))
+	for _, sink := range auditLog.Sinks {
+		switch sink {
+		case "file", "loki", "webhook":
+		default:
+			return fmt.Errorf("unknown sink %q in [audit], supported sinks are file, loki and webhook", sink)
+		}
+	}
+
+	auditLog.BufferSize = section.Key("buffer_size").MustInt(10000)
+	if auditLog.BufferSize <= 0 {
+		auditLog.BufferSize = 10000
+	}
+	auditLog.SinkTimeout = section.Key("sink_timeout").MustDuration(10 * time.Second)
+
+	auditLog.FilePath = cfg.SectionWithEnvOverrides("audit.file").Key("path").MustString("")
+
+	loki := cfg.SectionWithEnvOverrides("audit.loki")
+	auditLog.LokiURL = loki.Key("url").MustString("")
+	auditLog.LokiUser = loki.Key("user").MustString("")
+	auditLog.LokiPassword = loki.Key("password").MustString("")
+	auditLog.LokiTenantID = loki.Key("tenant_id").MustString("")
+
+	webhook := cfg.SectionWithEnvOverrides("audit.webhook")
+	auditLog.WebhookURL = webhook.Key("url").MustString("")
+	auditLog.WebhookSecret = webhook.Key("secret").MustString("")
+
+	cfg.AuditLog = auditLog
+	return nil
+}
@@ -0,0 +1,13 @@
+package setting
+
+type BrandingSettings struct {
+	// AllowOrgBranding lets the org admins override the branding of the instance for their organization
+	AllowOrgBranding bool
+}
+
+func (cfg *Cfg) readBrandingSettings() {
+	section := cfg.SectionWithEnvOverrides("branding")
+	cfg.Branding = BrandingSettings{
+		AllowOrgBranding: section.Key("allow_org_branding").MustBool(false),
+	}
+}
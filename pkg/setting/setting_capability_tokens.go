@@ -0,0 +1,31 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type CapabilityTokensSettings struct {
+	// Enabled allows minting capability tokens and authenticating requests with them
+	Enabled bool
+	// MaxTTL is the longest lifetime of a capability token
+	MaxTTL time.Duration
+	// AllowedActions are the actions capability tokens can grant
+	AllowedActions []string
+}
+
+func (cfg *Cfg) readCapabilityTokensSettings() error {
+	section := cfg.SectionWithEnvOverrides("security.capability_tokens")
+	tokens := CapabilityTokensSettings{}
+	tokens.Enabled = section.Key("enabled").MustBool(false)
+	tokens.MaxTTL = section.Key("max_ttl").MustDuration(24 * time.Hour)
+	if tokens.MaxTTL <= 0 {
+		return fmt.Errorf("invalid max_ttl in [security.capability_tokens]: must be positive")
+	}
+	tokens.AllowedActions = util.SplitString(section.Key("allowed_actions").MustString("dashboards:read folders:read datasources:query annotations:read"))
+
+	cfg.CapabilityTokens = tokens
+	return nil
+}
@@ -0,0 +1,40 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+)
+
+type CorrelationsSettings struct {
+	// SuggestionsEnabled runs the analyzer suggesting correlations from the queries of the query history
+	SuggestionsEnabled bool
+
+	// AnalysisInterval is the interval between two analyses of the queries
+	AnalysisInterval time.Duration
+
+	// AnalysisLookback is how far back the analyzer reads the query history
+	AnalysisLookback time.Duration
+
+	// MinQueries is the number of queries a data source needs in the lookback to be analyzed
+	MinQueries int
+}
+
+func (cfg *Cfg) readCorrelationsSettings() error {
+	section := cfg.SectionWithEnvOverrides("correlations")
+	cfg.Correlations = CorrelationsSettings{
+		SuggestionsEnabled: section.Key("suggestions_enabled").MustBool(true),
+		AnalysisInterval:   section.Key("analysis_interval").MustDuration(time.Hour),
+		AnalysisLookback:   section.Key("analysis_lookback").MustDuration(7 * 24 * time.Hour),
+		MinQueries:         section.Key("min_queries").MustInt(5),
+	}
+	if cfg.Correlations.AnalysisInterval < time.Minute {
+		return fmt.Errorf("analysis_interval in [correlations] must be at least 1m")
+	}
+	if cfg.Correlations.AnalysisLookback <= 0 {
+		return fmt.Errorf("analysis_lookback in [correlations] must be positive")
+	}
+	if cfg.Correlations.MinQueries < 1 {
+		return fmt.Errorf("min_queries in [correlations] must be at least 1")
+	}
+	return nil
+}
@@ -10,6 +10,8 @@ type FeatureMgmtSettings struct {
 	AllowEditing       bool
 	UpdateWebhook      string
 	UpdateWebhookToken string
+	// RuntimeEditing allows changing the toggles which don't require a restart through the admin API
+	RuntimeEditing bool
 }
 
 func (cfg *Cfg) readFeatureManagementConfig() {
@@ -35,4 +37,5 @@ func (cfg *Cfg) readFeatureManagementConfig() {
 	cfg.FeatureManagement.AllowEditing = cfg.SectionWithEnvOverrides("feature_management").Key("allow_editing").MustBool(false)
 	cfg.FeatureManagement.UpdateWebhook = cfg.SectionWithEnvOverrides("feature_management").Key("update_webhook").MustString("")
 	cfg.FeatureManagement.UpdateWebhookToken = cfg.SectionWithEnvOverrides("feature_management").Key("update_webhook_token").MustString("")
+	cfg.FeatureManagement.RuntimeEditing = cfg.SectionWithEnvOverrides("feature_management").Key("runtime_editing").MustBool(false)
 }
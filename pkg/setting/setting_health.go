@@ -0,0 +1,41 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type HealthSettings struct {
+	// CheckTimeout is the maximum duration of the check of a component
+	CheckTimeout time.Duration
+	// CacheTTL is how long the results of the checks are reused
+	CacheTTL time.Duration
+	// ReadyComponents are the components that fail the readiness of the instance when they're failing
+	ReadyComponents map[string]bool
+	// ShowErrors adds the errors of the checks to the public health endpoints
+	ShowErrors bool
+}
+
+func (cfg *Cfg) readHealthSettings() error {
+	section := cfg.SectionWithEnvOverrides("health")
+	health := HealthSettings{
+		CheckTimeout:    section.Key("check_timeout").MustDuration(2 * time.Second),
+		CacheTTL:        section.Key("cache_ttl").MustDuration(5 * time.Second),
+		ReadyComponents: map[string]bool{},
+		ShowErrors:      section.Key("show_errors").MustBool(false),
+	}
+	if health.CheckTimeout <= 0 {
+		return fmt.Errorf("check_timeout in [health] must be positive")
+	}
+	if health.CacheTTL < 0 {
+		return fmt.Errorf("cache_ttl in [health] can't be negative")
+	}
+	for _, name := range util.SplitString(section.Key("ready_components").MustString("database")) {
+		health.ReadyComponents[name] = true
+	}
+
+	cfg.Health = health
+	return nil
+}
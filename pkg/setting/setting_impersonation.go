@@ -0,0 +1,21 @@
+package setting
+
+import "time"
+
+type AuthImpersonationSettings struct {
+	// Enabled lets users with the users:impersonate permission sign in as another user
+	Enabled bool
+	// MaxDuration is the lifetime of an impersonation session
+	MaxDuration time.Duration
+}
+
+func (cfg *Cfg) readAuthImpersonationSettings() {
+	section := cfg.SectionWithEnvOverrides("auth.impersonation")
+	impersonation := AuthImpersonationSettings{}
+	impersonation.Enabled = section.Key("enabled").MustBool(false)
+	impersonation.MaxDuration = section.Key("max_duration").MustDuration(30 * time.Minute)
+	if impersonation.MaxDuration <= 0 {
+		impersonation.MaxDuration = 30 * time.Minute
+	}
+	cfg.ImpersonationAuth = impersonation
+}
@@ -0,0 +1,58 @@
+package setting
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// InstanceSyncOverwrite replaces the resources of the target with the resources of this instance.
+	InstanceSyncOverwrite = "overwrite"
+
+	// InstanceSyncSkip keeps the resources which already exist in the target.
+	InstanceSyncSkip = "skip"
+
+	// InstanceSyncNewest replaces the resources of the target which were updated before the resources of this instance.
+	InstanceSyncNewest = "newest"
+)
+
+type InstanceSyncSettings struct {
+	Enabled bool
+
+	// The URL of the target Grafana and the token of a service account of its org
+	TargetURL   string
+	TargetToken string
+
+	// The org of this instance whose resources are pushed to the target
+	OrgID int64
+
+	// The kinds of the synced resources: folders, dashboards, datasources and alert_rules
+	Resources []string
+
+	ConflictPolicy string
+
+	// SyncInterval is the interval between two scheduled synchronizations, zero only syncs on request
+	SyncInterval time.Duration
+
+	// PauseAlertRules pauses the alert rules created in the target, so a standby instance doesn't
+	// send the notifications twice
+	PauseAlertRules bool
+
+	RequestTimeout time.Duration
+}
+
+func (cfg *Cfg) readInstanceSyncSettings() {
+	section := cfg.Raw.Section("instance_sync")
+	cfg.InstanceSync.Enabled = section.Key("enabled").MustBool(false)
+	cfg.InstanceSync.TargetURL = strings.TrimSuffix(section.Key("target_url").MustString(""), "/")
+	cfg.InstanceSync.TargetToken = section.Key("target_token").MustString("")
+	cfg.InstanceSync.OrgID = section.Key("org_id").MustInt64(1)
+	cfg.InstanceSync.Resources = section.Key("resources").Strings(",")
+	if len(cfg.InstanceSync.Resources) == 0 {
+		cfg.InstanceSync.Resources = []string{"folders", "dashboards", "datasources", "alert_rules"}
+	}
+	cfg.InstanceSync.ConflictPolicy = section.Key("conflict_policy").In(InstanceSyncOverwrite, []string{InstanceSyncOverwrite, InstanceSyncSkip, InstanceSyncNewest})
+	cfg.InstanceSync.SyncInterval = section.Key("sync_interval").MustDuration(time.Hour)
+	cfg.InstanceSync.PauseAlertRules = section.Key("pause_alert_rules").MustBool(true)
+	cfg.InstanceSync.RequestTimeout = section.Key("request_timeout").MustDuration(30 * time.Second)
+}
@@ -0,0 +1,33 @@
+package setting
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/grafana/grafana/pkg/infra/network"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type IPAllowlistSettings struct {
+	// Enabled enforces the IP allowlists of organizations and service account tokens
+	Enabled bool
+	// TrustedProxies are the networks allowed to set the client address with the X-Forwarded-For and X-Real-IP headers
+	TrustedProxies []*net.IPNet
+}
+
+func (cfg *Cfg) readIPAllowlistSettings() error {
+	section := cfg.SectionWithEnvOverrides("security.ip_allowlist")
+	allowlist := IPAllowlistSettings{}
+	allowlist.Enabled = section.Key("enabled").MustBool(false)
+
+	for _, proxy := range util.SplitString(section.Key("trusted_proxies").MustString("")) {
+		ipNet, err := network.ParseCIDR(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy in [security.ip_allowlist]: %w", err)
+		}
+		allowlist.TrustedProxies = append(allowlist.TrustedProxies, ipNet)
+	}
+
+	cfg.IPAllowlist = allowlist
+	return nil
+}
@@ -1,6 +1,7 @@
 package setting
 
 import (
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/util"
@@ -8,6 +9,7 @@ import (
 
 const (
 	extJWTAccessTokenExpectAudience = "grafana"
+	authJWTKeySetSectionPrefix      = "auth.jwt.key_set."
 )
 
 type AuthJWTSettings struct {
@@ -34,6 +36,25 @@ type AuthJWTSettings struct {
 	EmailAttributePath      string
 	UsernameAttributePath   string
 	TlsSkipVerify           bool
+	// KeySets are additional key sets, each used to verify the tokens of one issuer
+	KeySets []AuthJWTKeySetSettings
+	// JWKSetRefreshInterval is how often remote key sets are refreshed in the background, 0 disables the refresh
+	JWKSetRefreshInterval time.Duration
+	// JWKSetMaxStaleAge is how long the last fetched remote key set is still used when the endpoint can't be reached
+	JWKSetMaxStaleAge time.Duration
+	// ClaimsMappingFile is the path of the file mapping claims to org roles, teams and RBAC roles
+	ClaimsMappingFile string
+}
+
+// AuthJWTKeySetSettings configures the keys used to verify the tokens of one issuer,
+// read from the [auth.jwt.key_set.<name>] sections
+type AuthJWTKeySetSettings struct {
+	Name       string
+	Issuer     string
+	JWKSetURL  string
+	JWKSetFile string
+	KeyFile    string
+	KeyID      string
 }
 
 type ExtJWTSettings struct {
@@ -79,6 +100,25 @@ func (cfg *Cfg) readAuthJWTSettings() {
 	jwtSettings.TlsSkipVerify = authJWT.Key("tls_skip_verify_insecure").MustBool(false)
 	jwtSettings.OrgAttributePath = valueAsString(authJWT, "org_attribute_path", "")
 	jwtSettings.OrgMapping = util.SplitString(valueAsString(authJWT, "org_mapping", ""))
+	jwtSettings.JWKSetRefreshInterval = authJWT.Key("jwk_set_refresh_interval").MustDuration(0)
+	jwtSettings.JWKSetMaxStaleAge = authJWT.Key("jwk_set_max_stale_age").MustDuration(24 * time.Hour)
+	jwtSettings.ClaimsMappingFile = valueAsString(authJWT, "claims_mapping_file", "")
+
+	for _, section := range cfg.Raw.Sections() {
+		name := strings.TrimPrefix(section.Name(), authJWTKeySetSectionPrefix)
+		if name == section.Name() || name == "" {
+			continue
+		}
+
+		jwtSettings.KeySets = append(jwtSettings.KeySets, AuthJWTKeySetSettings{
+			Name:       name,
+			Issuer:     valueAsString(section, "issuer", ""),
+			JWKSetURL:  valueAsString(section, "jwk_set_url", ""),
+			JWKSetFile: valueAsString(section, "jwk_set_file", ""),
+			KeyFile:    valueAsString(section, "key_file", ""),
+			KeyID:      section.Key("key_id").MustString(""),
+		})
+	}
 
 	cfg.JWTAuth = jwtSettings
 }
@@ -0,0 +1,84 @@
+package setting
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// The tenant metrics that can be enabled with the metrics key of [metrics.tenants]
+const (
+	TenantMetricAPIRequests = "api_requests"
+	TenantMetricAPIErrors   = "api_errors"
+	TenantMetricQueries     = "queries"
+	TenantMetricActiveUsers = "active_users"
+)
+
+type TenantMetricsSettings struct {
+	// Enabled emits the tenant metrics labeled by organization
+	Enabled bool
+	// Metrics are the tenant metrics emitted
+	Metrics map[string]bool
+	// Orgs are the organizations labeled with their ID, the other ones are labeled other. All the organizations are
+	// labeled, up to MaxOrgs, when it's empty.
+	Orgs map[int64]bool
+	// MaxOrgs is the number of organizations labeled with their ID when Orgs is empty
+	MaxOrgs int
+	// Teams are the teams labeled with their ID, the team label is only added when it isn't empty
+	Teams map[int64]bool
+	// ActiveUsersWindow is the period since their last request during which the users are active
+	ActiveUsersWindow time.Duration
+}
+
+func (cfg *Cfg) readTenantMetricsSettings() error {
+	section := cfg.SectionWithEnvOverrides("metrics.tenants")
+	tenantMetrics := TenantMetricsSettings{
+		Enabled:           section.Key("enabled").MustBool(false),
+		Metrics:           map[string]bool{},
+		Orgs:              map[int64]bool{},
+		MaxOrgs:           section.Key("max_orgs").MustInt(100),
+		Teams:             map[int64]bool{},
+		ActiveUsersWindow: section.Key("active_users_window").MustDuration(5 * time.Minute),
+	}
+
+	metrics := util.SplitString(section.Key("metrics").MustString("api_requests, api_errors, queries, active_users"))
+	for _, metric := range metrics {
+		switch metric {
+		case TenantMetricAPIRequests, TenantMetricAPIErrors, TenantMetricQueries, TenantMetricActiveUsers:
+			tenantMetrics.Metrics[metric] = true
+		default:
+			return fmt.Errorf("unknown metric %q in [metrics.tenants]", metric)
+		}
+	}
+
+	var err error
+	if tenantMetrics.Orgs, err = parseTenantIDs(section.Key("orgs").MustString("")); err != nil {
+		return fmt.Errorf("invalid orgs in [metrics.tenants]: %w", err)
+	}
+	if tenantMetrics.Teams, err = parseTenantIDs(section.Key("teams").MustString("")); err != nil {
+		return fmt.Errorf("invalid teams in [metrics.tenants]: %w", err)
+	}
+	if tenantMetrics.MaxOrgs < 0 {
+		return fmt.Errorf("max_orgs in [metrics.tenants] can't be negative")
+	}
+	if tenantMetrics.ActiveUsersWindow <= 0 {
+		return fmt.Errorf("active_users_window in [metrics.tenants] must be positive")
+	}
+
+	cfg.TenantMetrics = tenantMetrics
+	return nil
+}
+
+func parseTenantIDs(s string) (map[int64]bool, error) {
+	ids := map[int64]bool{}
+	for _, value := range util.SplitString(s) {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, nil
+}
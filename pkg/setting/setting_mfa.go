@@ -0,0 +1,24 @@
+package setting
+
+type AuthMFASettings struct {
+	// Enabled lets users of the built-in password login enroll a TOTP authenticator
+	Enabled bool
+	// Enforce requires every user of the built-in password login to use MFA, regardless of org policies
+	Enforce bool
+	// Issuer is shown next to the account in authenticator apps
+	Issuer            string
+	RecoveryCodeCount int
+}
+
+func (cfg *Cfg) readAuthMFASettings() {
+	authMFA := cfg.SectionWithEnvOverrides("auth.mfa")
+	mfaSettings := AuthMFASettings{}
+	mfaSettings.Enabled = authMFA.Key("enabled").MustBool(false)
+	mfaSettings.Enforce = authMFA.Key("enforce").MustBool(false)
+	mfaSettings.Issuer = authMFA.Key("issuer").MustString("Grafana")
+	mfaSettings.RecoveryCodeCount = authMFA.Key("recovery_codes").MustInt(10)
+	if mfaSettings.RecoveryCodeCount < 1 {
+		mfaSettings.RecoveryCodeCount = 10
+	}
+	cfg.MFAAuth = mfaSettings
+}
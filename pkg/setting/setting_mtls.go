@@ -0,0 +1,58 @@
+package setting
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	MTLSClientAuthOptional = "optional"
+	MTLSClientAuthRequired = "required"
+)
+
+type AuthMTLSSettings struct {
+	Enabled bool
+	// ClientAuth is either optional, to let users without a certificate sign in with other methods, or required
+	ClientAuth          string
+	CACertFile          string
+	LoginAttribute      string
+	EmailAttribute      string
+	NameAttribute       string
+	OrgAttribute        string
+	OrgMapping          []string
+	RoleAttributeStrict bool
+	SkipOrgRoleSync     bool
+	AutoSignUp          bool
+	CRLFile             string
+	OCSPEnabled         bool
+	OCSPResponderURL    string
+	OCSPFailOpen        bool
+	OCSPTimeout         time.Duration
+}
+
+func (cfg *Cfg) readAuthMTLSSettings() {
+	authMTLS := cfg.Raw.Section("auth.mtls")
+	mtlsSettings := AuthMTLSSettings{}
+	mtlsSettings.Enabled = authMTLS.Key("enabled").MustBool(false)
+	mtlsSettings.ClientAuth = valueAsString(authMTLS, "client_auth", MTLSClientAuthOptional)
+	if mtlsSettings.ClientAuth != MTLSClientAuthRequired {
+		mtlsSettings.ClientAuth = MTLSClientAuthOptional
+	}
+	mtlsSettings.CACertFile = valueAsString(authMTLS, "ca_cert_file", "")
+	mtlsSettings.LoginAttribute = valueAsString(authMTLS, "login_attribute", "cn")
+	mtlsSettings.EmailAttribute = valueAsString(authMTLS, "email_attribute", "email")
+	mtlsSettings.NameAttribute = valueAsString(authMTLS, "name_attribute", "cn")
+	mtlsSettings.OrgAttribute = valueAsString(authMTLS, "org_attribute", "ou")
+	mtlsSettings.OrgMapping = util.SplitString(valueAsString(authMTLS, "org_mapping", ""))
+	mtlsSettings.RoleAttributeStrict = authMTLS.Key("role_attribute_strict").MustBool(false)
+	mtlsSettings.SkipOrgRoleSync = authMTLS.Key("skip_org_role_sync").MustBool(false)
+	mtlsSettings.AutoSignUp = authMTLS.Key("auto_sign_up").MustBool(false)
+	mtlsSettings.CRLFile = valueAsString(authMTLS, "crl_file", "")
+	mtlsSettings.OCSPEnabled = authMTLS.Key("ocsp_enabled").MustBool(false)
+	mtlsSettings.OCSPResponderURL = valueAsString(authMTLS, "ocsp_responder_url", "")
+	mtlsSettings.OCSPFailOpen = authMTLS.Key("ocsp_fail_open").MustBool(false)
+	mtlsSettings.OCSPTimeout = authMTLS.Key("ocsp_timeout").MustDuration(5 * time.Second)
+
+	cfg.MTLSAuth = mtlsSettings
+}
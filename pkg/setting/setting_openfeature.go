@@ -3,11 +3,15 @@ package setting
 import (
 	"fmt"
 	"net/url"
+	"time"
 )
 
 const (
 	StaticProviderType = "static"
 	GOFFProviderType   = "goff"
+	// OFREPProviderType evaluates the flags with any service implementing the OpenFeature Remote Evaluation
+	// Protocol, such as flagd or the relay proxies of the flag management services
+	OFREPProviderType = "ofrep"
 )
 
 type OpenFeatureSettings struct {
@@ -16,6 +20,8 @@ type OpenFeatureSettings struct {
 	URL          *url.URL
 	TargetingKey string
 	ContextAttrs map[string]any
+	// CacheTTL is how long the values evaluated by an external provider are reused
+	CacheTTL time.Duration
 }
 
 func (cfg *Cfg) readOpenFeatureSettings() error {
@@ -26,9 +32,11 @@ func (cfg *Cfg) readOpenFeatureSettings() error {
 	cfg.OpenFeature.ProviderType = config.Key("provider").MustString(StaticProviderType)
 	cfg.OpenFeature.TargetingKey = config.Key("targetingKey").MustString(cfg.AppURL)
 
+	cfg.OpenFeature.CacheTTL = config.Key("cache_ttl").MustDuration(time.Minute)
+
 	strURL := config.Key("url").MustString("")
 
-	if strURL != "" && cfg.OpenFeature.ProviderType == GOFFProviderType {
+	if strURL != "" && cfg.OpenFeature.IsExternalProvider() {
 		u, err := url.Parse(strURL)
 		if err != nil {
 			return fmt.Errorf("invalid feature provider url: %w", err)
@@ -51,3 +59,8 @@ func (cfg *Cfg) readOpenFeatureSettings() error {
 	cfg.OpenFeature.ContextAttrs = attrs
 	return nil
 }
+
+// IsExternalProvider checks if the flags are evaluated by a provider outside of Grafana
+func (s OpenFeatureSettings) IsExternalProvider() bool {
+	return s.ProviderType == GOFFProviderType || s.ProviderType == OFREPProviderType
+}
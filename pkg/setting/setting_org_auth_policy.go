@@ -0,0 +1,13 @@
+package setting
+
+type OrgAuthPolicySettings struct {
+	// Enabled lets organization admins restrict the sign in methods, session lifetimes and role mapping of their organization
+	Enabled bool
+}
+
+func (cfg *Cfg) readOrgAuthPolicySettings() {
+	section := cfg.SectionWithEnvOverrides("auth.org_policy")
+	cfg.OrgAuthPolicy = OrgAuthPolicySettings{
+		Enabled: section.Key("enabled").MustBool(false),
+	}
+}
@@ -0,0 +1,26 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+)
+
+type PlaylistSettings struct {
+	// KioskTokensEnabled allows screens to show a playlist with a kiosk token instead of a signed in user
+	KioskTokensEnabled bool
+
+	// ScreenActiveTimeout is how long a screen is reported active after its last heartbeat
+	ScreenActiveTimeout time.Duration
+}
+
+func (cfg *Cfg) readPlaylistSettings() error {
+	section := cfg.SectionWithEnvOverrides("playlists")
+	cfg.Playlists = PlaylistSettings{
+		KioskTokensEnabled:  section.Key("kiosk_tokens_enabled").MustBool(true),
+		ScreenActiveTimeout: section.Key("screen_active_timeout").MustDuration(5 * time.Minute),
+	}
+	if cfg.Playlists.ScreenActiveTimeout < 30*time.Second {
+		return fmt.Errorf("screen_active_timeout in [playlists] must be at least 30s")
+	}
+	return nil
+}
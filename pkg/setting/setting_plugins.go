@@ -15,6 +15,13 @@ const (
 	PluginUpdateStrategyMinor  = "minor"
 )
 
+// The modes of attribution of the plugin request metrics to the dashboards and the panels which issued the queries
+const (
+	PluginRequestAttributionOff       = "off"
+	PluginRequestAttributionDashboard = "dashboard"
+	PluginRequestAttributionPanel     = "panel"
+)
+
 // PluginSettings maps plugin id to map of key/value settings.
 type PluginSettings map[string]map[string]string
 
@@ -176,6 +183,9 @@ func (cfg *Cfg) readPluginSettings(iniFile *ini.File) error {
 	// Plugins CDN settings
 	cfg.PluginsCDNURLTemplate = strings.TrimRight(pluginsSection.Key("cdn_base_url").MustString(""), "/")
 	cfg.PluginLogBackendRequests = pluginsSection.Key("log_backend_requests").MustBool(false)
+	cfg.PluginRequestAttribution = pluginsSection.Key("request_attribution").In(PluginRequestAttributionOff,
+		[]string{PluginRequestAttributionOff, PluginRequestAttributionDashboard, PluginRequestAttributionPanel})
+	cfg.PluginRequestAttributionMaxSeries = pluginsSection.Key("request_attribution_max_series").MustInt(1000)
 
 	cfg.PluginUpdateStrategy = pluginsSection.Key("update_strategy").In(PluginUpdateStrategyLatest, []string{PluginUpdateStrategyLatest, PluginUpdateStrategyMinor})
 
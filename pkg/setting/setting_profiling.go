@@ -0,0 +1,76 @@
+package setting
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type ProfilingSettings struct {
+	// Enabled registers the profiling admin API
+	Enabled bool
+	// StoragePath is the directory of the captured profiles, it's emptied on startup
+	StoragePath string
+	// MaxDuration is the longest duration of a CPU profile or a runtime trace
+	MaxDuration time.Duration
+	// Retention is how long the captured profiles are kept
+	Retention time.Duration
+	// MaxCaptures is the number of captured profiles kept at the same time
+	MaxCaptures int
+	Pyroscope   PyroscopeSettings
+}
+
+// PyroscopeSettings configure the continuous profiling, the profiles are pushed to Pyroscope when ServerURL is set
+type PyroscopeSettings struct {
+	ServerURL         string
+	ApplicationName   string
+	Tags              map[string]string
+	PushInterval      time.Duration
+	BasicAuthUser     string
+	BasicAuthPassword string
+	TenantID          string
+}
+
+func (cfg *Cfg) readProfilingSettings() error {
+	section := cfg.SectionWithEnvOverrides("profiling")
+	profiling := ProfilingSettings{
+		Enabled:     section.Key("enabled").MustBool(false),
+		StoragePath: makeAbsolute(section.Key("storage_path").MustString(filepath.Join(cfg.DataPath, "profiles")), cfg.HomePath),
+		MaxDuration: section.Key("max_duration").MustDuration(time.Minute),
+		Retention:   section.Key("retention").MustDuration(time.Hour),
+		MaxCaptures: section.Key("max_captures").MustInt(20),
+	}
+	if profiling.MaxDuration <= 0 || profiling.Retention <= 0 {
+		return fmt.Errorf("max_duration and retention in [profiling] must be positive")
+	}
+	if profiling.MaxCaptures <= 0 {
+		return fmt.Errorf("max_captures in [profiling] must be positive")
+	}
+
+	pyroscopeSection := cfg.SectionWithEnvOverrides("profiling.pyroscope")
+	profiling.Pyroscope = PyroscopeSettings{
+		ServerURL:         strings.TrimSuffix(pyroscopeSection.Key("server_url").MustString(""), "/"),
+		ApplicationName:   pyroscopeSection.Key("application_name").MustString("grafana"),
+		Tags:              map[string]string{},
+		PushInterval:      pyroscopeSection.Key("push_interval").MustDuration(15 * time.Second),
+		BasicAuthUser:     pyroscopeSection.Key("basic_auth_user").MustString(""),
+		BasicAuthPassword: pyroscopeSection.Key("basic_auth_password").MustString(""),
+		TenantID:          pyroscopeSection.Key("tenant_id").MustString(""),
+	}
+	for _, tag := range util.SplitString(pyroscopeSection.Key("tags").MustString("")) {
+		key, value, ok := strings.Cut(tag, ":")
+		if !ok || key == "" {
+			return fmt.Errorf("tag %q in [profiling.pyroscope] must be in 'key:value' form", tag)
+		}
+		profiling.Pyroscope.Tags[key] = value
+	}
+	if profiling.Pyroscope.PushInterval < time.Second {
+		return fmt.Errorf("push_interval in [profiling.pyroscope] must be at least 1s")
+	}
+
+	cfg.Profiling = profiling
+	return nil
+}
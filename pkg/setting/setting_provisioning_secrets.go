@@ -0,0 +1,29 @@
+package setting
+
+import (
+	"gopkg.in/ini.v1"
+)
+
+// ProvisioningSecretsSettings configures the resolvers of the references to the secrets of external secret
+// managers in the provisioning files, like $__vault{kv/data/grafana#db_password} or $__awssm{grafana/db}.
+type ProvisioningSecretsSettings struct {
+	// VaultAddress enables the $__vault{} references, resolved with the HTTP API of the Vault server at the address
+	VaultAddress   string
+	VaultToken     string
+	VaultNamespace string
+	// AWSSecretsManagerEnabled enables the $__awssm{} references, resolved with the credentials of the environment
+	AWSSecretsManagerEnabled bool
+	AWSSecretsManagerRegion  string
+}
+
+func readProvisioningSecretsSettings(iniFile *ini.File) ProvisioningSecretsSettings {
+	vault := iniFile.Section("provisioning.secrets.vault")
+	awssm := iniFile.Section("provisioning.secrets.awssm")
+	return ProvisioningSecretsSettings{
+		VaultAddress:             valueAsString(vault, "address", ""),
+		VaultToken:               valueAsString(vault, "token", ""),
+		VaultNamespace:           valueAsString(vault, "namespace", ""),
+		AWSSecretsManagerEnabled: awssm.Key("enabled").MustBool(false),
+		AWSSecretsManagerRegion:  valueAsString(awssm, "region", ""),
+	}
+}
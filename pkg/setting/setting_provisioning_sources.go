@@ -0,0 +1,112 @@
+package setting
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+const provisioningSourcePrefix = "provisioning.source."
+
+// ProvisioningSource is a remote source of provisioning files, configured in a section like:
+// [provisioning.source.<name>]
+// type = http
+// provisioner = datasources
+// url = https://config.example.com/datasources.yaml
+type ProvisioningSource struct {
+	Name string
+	// Type is the type of the source: http, s3 or configmap
+	Type string
+	// Provisioner is the provisioner reading the files of the source: datasources, plugins, alerting or dashboards
+	Provisioner string
+	// Path is the directory the files are written to. By default, the files are read along the files
+	// of the provisioner. It can be set to write the dashboards referenced by a dashboard provider.
+	Path string
+	// URL is the URL of the file for http sources, and of the bucket for s3 sources, like s3://bucket/prefix?region=us-east-1
+	URL               string
+	BearerToken       string
+	BasicAuthUser     string
+	BasicAuthPassword string
+	TLSSkipVerify     bool
+	// Namespace and ConfigMap are the config map read from the Kubernetes API for configmap sources
+	Namespace string
+	ConfigMap string
+	// Directory is the directory a config map is mounted in, read instead of the Kubernetes API when set
+	Directory    string
+	SyncInterval time.Duration
+}
+
+var provisioningSourceProvisioners = []string{"datasources", "plugins", "alerting", "dashboards"}
+
+func readProvisioningSources(iniFile *ini.File, homePath string) ([]ProvisioningSource, error) {
+	var sources []ProvisioningSource
+	for _, section := range iniFile.Sections() {
+		if !strings.HasPrefix(section.Name(), provisioningSourcePrefix) {
+			continue
+		}
+
+		source := ProvisioningSource{
+			Name:              strings.TrimPrefix(section.Name(), provisioningSourcePrefix),
+			Type:              valueAsString(section, "type", ""),
+			Provisioner:       valueAsString(section, "provisioner", ""),
+			URL:               valueAsString(section, "url", ""),
+			BearerToken:       valueAsString(section, "bearer_token", ""),
+			BasicAuthUser:     valueAsString(section, "basic_auth_user", ""),
+			BasicAuthPassword: valueAsString(section, "basic_auth_password", ""),
+			TLSSkipVerify:     section.Key("tls_skip_verify_insecure").MustBool(false),
+			Namespace:         valueAsString(section, "namespace", ""),
+			ConfigMap:         valueAsString(section, "configmap", ""),
+			SyncInterval:      section.Key("sync_interval").MustDuration(time.Minute),
+		}
+		if path := valueAsString(section, "path", ""); path != "" {
+			source.Path = makeAbsolute(path, homePath)
+		}
+		if dir := valueAsString(section, "directory", ""); dir != "" {
+			source.Directory = makeAbsolute(dir, homePath)
+		}
+
+		if err := source.validate(); err != nil {
+			return nil, fmt.Errorf("invalid provisioning source %q: %w", source.Name, err)
+		}
+		sources = append(sources, source)
+	}
+
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].Name < sources[j].Name
+	})
+	return sources, nil
+}
+
+func (s ProvisioningSource) validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("the name is required")
+	}
+	if !slices.Contains(provisioningSourceProvisioners, s.Provisioner) {
+		return fmt.Errorf("the provisioner must be one of %s", strings.Join(provisioningSourceProvisioners, ", "))
+	}
+	if s.SyncInterval <= 0 {
+		return fmt.Errorf("the sync interval must be positive")
+	}
+
+	switch s.Type {
+	case "http":
+		if !strings.HasPrefix(s.URL, "http://") && !strings.HasPrefix(s.URL, "https://") {
+			return fmt.Errorf("the url of http sources must be a http(s) URL")
+		}
+	case "s3":
+		if !strings.HasPrefix(s.URL, "s3://") {
+			return fmt.Errorf("the url of s3 sources must look like s3://bucket/prefix")
+		}
+	case "configmap":
+		if s.Directory == "" && s.ConfigMap == "" {
+			return fmt.Errorf("either the directory or the configmap of configmap sources is required")
+		}
+	default:
+		return fmt.Errorf("the type must be one of http, s3, configmap")
+	}
+	return nil
+}
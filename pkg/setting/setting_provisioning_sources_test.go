@@ -0,0 +1,64 @@
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestReadProvisioningSources(t *testing.T) {
+	t.Run("should parse the sources", func(t *testing.T) {
+		iniFile, err := ini.Load([]byte(`
+[provisioning.source.remote]
+type = http
+provisioner = datasources
+url = https://config.example.com/datasources.yaml
+bearer_token = token
+
+[provisioning.source.mounted]
+type = configmap
+provisioner = dashboards
+directory = conf/mounted
+sync_interval = 10s
+`))
+		require.NoError(t, err)
+
+		sources, err := readProvisioningSources(iniFile, "/grafana")
+		require.NoError(t, err)
+		require.Len(t, sources, 2)
+
+		assert.Equal(t, ProvisioningSource{
+			Name:         "mounted",
+			Type:         "configmap",
+			Provisioner:  "dashboards",
+			Directory:    "/grafana/conf/mounted",
+			SyncInterval: 10 * time.Second,
+		}, sources[0])
+		assert.Equal(t, ProvisioningSource{
+			Name:         "remote",
+			Type:         "http",
+			Provisioner:  "datasources",
+			URL:          "https://config.example.com/datasources.yaml",
+			BearerToken:  "token",
+			SyncInterval: time.Minute,
+		}, sources[1])
+	})
+
+	t.Run("should reject invalid sources", func(t *testing.T) {
+		for _, section := range []string{
+			"type = ftp\nprovisioner = datasources",
+			"type = http\nprovisioner = users\nurl = https://config.example.com",
+			"type = s3\nprovisioner = alerting\nurl = https://bucket",
+			"type = configmap\nprovisioner = plugins",
+		} {
+			iniFile, err := ini.Load([]byte("[provisioning.source.invalid]\n" + section))
+			require.NoError(t, err)
+
+			_, err = readProvisioningSources(iniFile, "/grafana")
+			require.Error(t, err, section)
+		}
+	})
+}
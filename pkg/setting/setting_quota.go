@@ -29,12 +29,16 @@ type QuotaSettings struct {
 	Org     OrgQuota
 	User    UserQuota
 	Global  GlobalQuota
+	// SoftLimitPercent is the percentage of the limits at which the quotas without a custom soft limit warn, 0
+	// disables it
+	SoftLimitPercent int64
 }
 
 func (cfg *Cfg) readQuotaSettings() {
 	// set global defaults.
 	quota := cfg.Raw.Section("quota")
 	cfg.Quota.Enabled = quota.Key("enabled").MustBool(false)
+	cfg.Quota.SoftLimitPercent = quota.Key("soft_limit_percent").MustInt64(80)
 
 	// per ORG Limits
 	cfg.Quota.Org = OrgQuota{
@@ -0,0 +1,71 @@
+package setting
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type RateLimitSettings struct {
+	// Enabled limits the rate of the requests to the HTTP API
+	Enabled bool
+	// Window is the period over which the requests are counted
+	Window time.Duration
+	// UserLimit is the number of requests a user can send in a window, 0 is unlimited
+	UserLimit int64
+	// TokenLimit is the number of requests a service account or an API key can send in a window, 0 is unlimited
+	TokenLimit int64
+	// OrgLimit is the number of requests the users of an organization can send in a window, 0 is unlimited
+	OrgLimit int64
+	// Groups limit the requests of a user or a token to a group of routes
+	Groups []RateLimitGroup
+}
+
+// RateLimitGroup is the limit of the requests to the routes whose path starts with one of the prefixes
+type RateLimitGroup struct {
+	Name     string
+	Prefixes []string
+	Limit    int64
+}
+
+func (cfg *Cfg) readRateLimitSettings() error {
+	section := cfg.SectionWithEnvOverrides("rate_limiting")
+	rateLimit := RateLimitSettings{
+		Enabled:    section.Key("enabled").MustBool(false),
+		Window:     section.Key("window").MustDuration(time.Minute),
+		UserLimit:  section.Key("user_limit").MustInt64(0),
+		TokenLimit: section.Key("token_limit").MustInt64(0),
+		OrgLimit:   section.Key("org_limit").MustInt64(0),
+	}
+	if rateLimit.Window < time.Second {
+		return fmt.Errorf("window in [rate_limiting] must be at least 1s")
+	}
+
+	for _, groupSection := range cfg.Raw.Sections() {
+		name, ok := strings.CutPrefix(groupSection.Name(), "rate_limiting.group.")
+		if !ok {
+			continue
+		}
+		switch name {
+		case "user", "token", "org":
+			return fmt.Errorf("[rate_limiting.group.%s] %s is reserved for the %s limit", name, name, name)
+		}
+		group := RateLimitGroup{
+			Name:     name,
+			Prefixes: util.SplitString(groupSection.Key("paths").MustString("")),
+			Limit:    groupSection.Key("limit").MustInt64(0),
+		}
+		if len(group.Prefixes) == 0 {
+			return fmt.Errorf("[rate_limiting.group.%s] paths must be set", name)
+		}
+		if group.Limit <= 0 {
+			return fmt.Errorf("[rate_limiting.group.%s] limit must be positive", name)
+		}
+		rateLimit.Groups = append(rateLimit.Groups, group)
+	}
+
+	cfg.RateLimit = rateLimit
+	return nil
+}
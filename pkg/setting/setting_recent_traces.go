@@ -0,0 +1,33 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+)
+
+type RecentTracesSettings struct {
+	// Size is the number of traces kept, 0 disables the recent traces
+	Size int
+	// SlowThreshold is the duration from which a request is slow
+	SlowThreshold time.Duration
+	// TraceURL is the URL of a trace in the tracing backend, {traceId} is replaced by the trace ID
+	TraceURL string
+}
+
+func (cfg *Cfg) readRecentTracesSettings() error {
+	section := cfg.SectionWithEnvOverrides("tracing.recent")
+	recentTraces := RecentTracesSettings{
+		Size:          section.Key("size").MustInt(100),
+		SlowThreshold: section.Key("slow_threshold").MustDuration(5 * time.Second),
+		TraceURL:      section.Key("trace_url").MustString(""),
+	}
+	if recentTraces.Size < 0 {
+		return fmt.Errorf("size in [tracing.recent] can't be negative")
+	}
+	if recentTraces.SlowThreshold <= 0 {
+		return fmt.Errorf("slow_threshold in [tracing.recent] must be positive")
+	}
+
+	cfg.RecentTraces = recentTraces
+	return nil
+}
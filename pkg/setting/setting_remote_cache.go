@@ -1,10 +1,17 @@
 package setting
 
+import "time"
+
 type RemoteCacheSettings struct {
 	Name       string
 	ConnStr    string
 	Prefix     string
 	Encryption bool
+
+	// LocalCacheEnabled keeps the recently used items in the memory of the instance, in front of the remote cache
+	LocalCacheEnabled  bool
+	LocalCacheTTL      time.Duration
+	LocalCacheMaxItems int
 }
 
 func (cfg *Cfg) readRemoteCacheSettings() {
@@ -14,10 +21,18 @@ func (cfg *Cfg) readRemoteCacheSettings() {
 	prefix := valueAsString(cacheServer, "prefix", "")
 	encryption := cacheServer.Key("encryption").MustBool(false)
 
+	localCacheTTL := cacheServer.Key("local_cache_ttl").MustDuration(10 * time.Second)
+	if localCacheTTL <= 0 {
+		localCacheTTL = 10 * time.Second
+	}
+
 	cfg.RemoteCacheOptions = &RemoteCacheSettings{
-		Name:       dbName,
-		ConnStr:    connStr,
-		Prefix:     prefix,
-		Encryption: encryption,
+		Name:               dbName,
+		ConnStr:            connStr,
+		Prefix:             prefix,
+		Encryption:         encryption,
+		LocalCacheEnabled:  cacheServer.Key("local_cache_enabled").MustBool(false),
+		LocalCacheTTL:      localCacheTTL,
+		LocalCacheMaxItems: cacheServer.Key("local_cache_max_items").MustInt(10000),
 	}
 }
@@ -10,6 +10,10 @@ type SearchSettings struct {
 	FullReindexInterval       time.Duration
 	IndexUpdateInterval       time.Duration
 	DashboardLoadingBatchSize int
+
+	// SavedSearchSubscriptionsEnabled enables the webhook subscriptions of the saved searches
+	SavedSearchSubscriptionsEnabled bool
+	SavedSearchEvaluationInterval   time.Duration
 }
 
 func readSearchSettings(iniFile *ini.File) SearchSettings {
@@ -19,5 +23,10 @@ func readSearchSettings(iniFile *ini.File) SearchSettings {
 	s.DashboardLoadingBatchSize = searchSection.Key("dashboard_loading_batch_size").MustInt(200)
 	s.FullReindexInterval = searchSection.Key("full_reindex_interval").MustDuration(5 * time.Minute)
 	s.IndexUpdateInterval = searchSection.Key("index_update_interval").MustDuration(10 * time.Second)
+	s.SavedSearchSubscriptionsEnabled = searchSection.Key("saved_search_subscriptions_enabled").MustBool(true)
+	s.SavedSearchEvaluationInterval = searchSection.Key("saved_search_evaluation_interval").MustDuration(5 * time.Minute)
+	if s.SavedSearchEvaluationInterval < time.Minute {
+		s.SavedSearchEvaluationInterval = time.Minute
+	}
 	return s
 }
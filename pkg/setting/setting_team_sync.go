@@ -0,0 +1,67 @@
+package setting
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// TeamSyncOIDCProvider maps the groups of the users who logged in with OAuth or OpenID Connect
+	TeamSyncOIDCProvider = "oidc"
+	// TeamSyncGitHubProvider maps the GitHub teams, in the form @org/team
+	TeamSyncGitHubProvider = "github"
+)
+
+type TeamSyncSettings struct {
+	// Enabled keeps the members of the teams with sync rules in lockstep with the groups of the identity providers
+	Enabled bool
+
+	// Providers are the enabled built-in providers
+	Providers []string
+
+	// SyncInterval is the interval between two reconciliations of the teams with sync rules
+	SyncInterval time.Duration
+
+	// OIDCAuthModules are the login modules whose groups are recorded for the oidc provider
+	OIDCAuthModules []string
+
+	GitHubAPIURL string
+	// GitHubToken reads the members of the teams from the GitHub API, without it the groups recorded at login are used
+	GitHubToken string
+}
+
+func (cfg *Cfg) readTeamSyncSettings() error {
+	section := cfg.SectionWithEnvOverrides("team_sync")
+	teamSync := TeamSyncSettings{
+		Enabled:      section.Key("enabled").MustBool(false),
+		Providers:    util.SplitString(section.Key("providers").MustString("oidc,github")),
+		SyncInterval: section.Key("sync_interval").MustDuration(time.Hour),
+	}
+	for _, provider := range teamSync.Providers {
+		switch provider {
+		case TeamSyncOIDCProvider, TeamSyncGitHubProvider:
+		default:
+			return fmt.Errorf("unknown provider %q in [team_sync], supported providers are oidc and github", provider)
+		}
+	}
+	if teamSync.SyncInterval < time.Minute {
+		return fmt.Errorf("sync_interval in [team_sync] must be at least 1m")
+	}
+
+	oidc := cfg.SectionWithEnvOverrides("team_sync.oidc")
+	teamSync.OIDCAuthModules = util.SplitString(oidc.Key("auth_modules").MustString("oauth_generic_oauth,oauth_azuread,oauth_okta,oauth_gitlab,oauth_google"))
+
+	github := cfg.SectionWithEnvOverrides("team_sync.github")
+	teamSync.GitHubAPIURL = strings.TrimSuffix(github.Key("api_url").MustString("https://api.github.com"), "/")
+	if _, err := url.ParseRequestURI(teamSync.GitHubAPIURL); err != nil {
+		return fmt.Errorf("invalid api_url in [team_sync.github]: %w", err)
+	}
+	teamSync.GitHubToken = github.Key("token").MustString("")
+
+	cfg.TeamSync = teamSync
+	return nil
+}
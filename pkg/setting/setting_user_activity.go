@@ -0,0 +1,42 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+type UserActivitySettings struct {
+	// Enabled records the dashboards viewed and edited, the alerts acknowledged and the Explore queries of the users
+	Enabled bool
+	// Retention is how long activities are kept, 0 keeps them forever
+	Retention time.Duration
+	// MaxPerUser is the number of activities kept by user, 0 keeps them all
+	MaxPerUser int
+	BufferSize int
+}
+
+func (cfg *Cfg) readUserActivitySettings() error {
+	section := cfg.SectionWithEnvOverrides("user_activity")
+	userActivity := UserActivitySettings{}
+	userActivity.Enabled = section.Key("enabled").MustBool(true)
+
+	retention, err := gtime.ParseDuration(section.Key("retention").MustString("30d"))
+	if err != nil {
+		return fmt.Errorf("invalid retention in [user_activity]: %w", err)
+	}
+	userActivity.Retention = retention
+
+	userActivity.MaxPerUser = section.Key("max_per_user").MustInt(200)
+	if userActivity.MaxPerUser < 0 {
+		userActivity.MaxPerUser = 0
+	}
+	userActivity.BufferSize = section.Key("buffer_size").MustInt(10000)
+	if userActivity.BufferSize <= 0 {
+		userActivity.BufferSize = 10000
+	}
+
+	cfg.UserActivity = userActivity
+	return nil
+}
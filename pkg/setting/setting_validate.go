@@ -0,0 +1,346 @@
+package setting
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"gopkg.in/ini.v1"
+)
+
+type ConfigIssueSeverity string
+
+const (
+	ConfigIssueError   ConfigIssueSeverity = "error"
+	ConfigIssueWarning ConfigIssueSeverity = "warning"
+)
+
+// ConfigIssue is a problem of a setting, found by ValidateConfig
+type ConfigIssue struct {
+	Severity ConfigIssueSeverity
+	// Source is the config file, environment variable or command line argument setting the value
+	Source  string
+	Section string
+	Key     string
+	Message string
+}
+
+func (i ConfigIssue) String() string {
+	if i.Key == "" {
+		return fmt.Sprintf("%s: %s", i.Source, i.Message)
+	}
+	return fmt.Sprintf("%s: [%s] %s %s", i.Source, i.Section, i.Key, i.Message)
+}
+
+// freeFormSections are the sections whose keys are chosen by the users, and aren't in defaults.ini
+var freeFormSections = []string{
+	"feature_toggles",
+	"metrics.environment_info",
+	"server.custom_response_headers",
+	"recording_rules.custom_headers",
+	"smtp.static_headers",
+	"live.authorization",
+	"navigation.app_sections",
+	"navigation.app_standalone_pages",
+}
+
+// freeFormSectionPrefixes are the prefixes of the sections whose names are chosen by the users
+var freeFormSectionPrefixes = []string{
+	"plugin.",
+	"unified_storage.",
+	"unified_storage_quota.",
+	"annotations.retention.",
+	"annotations.ingest.",
+	"snapshots.retention.",
+	"provisioning.source.",
+	"security.encryption.vault.",
+	authJWTKeySetSectionPrefix,
+	ProviderPrefix,
+}
+
+// deprecatedSettings are the settings which are still read or were removed, with what to use instead
+var deprecatedSettings = map[string]map[string]string{
+	"alerting": {
+		"max_annotations_to_keep": "use max_annotations_to_keep in [unified_alerting.state_history.annotations] instead",
+		"max_annotation_age":      "use max_age in [unified_alerting.state_history.annotations] instead",
+	},
+	"auth": {
+		"oauth_auto_login":                "use auto_login in the sections of the OAuth providers instead",
+		"oauth_skip_org_role_update_sync": "use skip_org_role_sync in the sections of the OAuth providers instead",
+	},
+	"auth.anonymous": {
+		"org_role": "only the Viewer role is supported",
+	},
+	"users": {
+		"viewers_can_edit":       "assign the viewers to the Editor role instead",
+		"editors_can_admin":      "assign the editors to the Admin role instead",
+		"case_insensitive_login": "the logins are always case insensitive",
+	},
+}
+
+// ignoredEnvVariables are the GF_ environment variables which aren't settings, like the ones of the Docker image
+var ignoredEnvVariables = []string{
+	"GF_PATHS_CONFIG",
+	"GF_PATHS_HOME",
+	"GF_INSTALL_PLUGINS",
+	"GF_INSTALL_PLUGINS_FORCE",
+	"GF_INSTALL_IMAGE_RENDERER_PLUGIN",
+}
+
+var (
+	integerRegex  = regexp.MustCompile(`^-?\d+$`)
+	durationRegex = regexp.MustCompile(`^\d+(\.\d+)?(ns|us|µs|ms|s|m|h|d|w|y)$`)
+)
+
+// ValidateConfig checks the settings of the config file, the environment variables and the command line
+// arguments against defaults.ini. It reports the unknown settings and the deprecated settings as warnings,
+// and the values which can't be parsed with the type of their default value as errors.
+func ValidateConfig(args CommandLineArgs) ([]ConfigIssue, error) {
+	cfg := NewCfg()
+	cfg.setHomePath(args)
+	defaults, err := ini.Load(filepath.Join(cfg.HomePath, "conf/defaults.ini"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the config defaults, make sure the homepath is set: %w", err)
+	}
+
+	v := &configValidator{defaults: defaults, sources: map[string]map[string]string{}}
+
+	props := map[string]string{}
+	for _, arg := range args.Args {
+		trimmed, ok := strings.CutPrefix(arg, "cfg:")
+		if !ok {
+			continue
+		}
+		parts := strings.Split(trimmed, "=")
+		if len(parts) != 2 {
+			v.report(ConfigIssueError, arg, "", "", "isn't a valid command line argument, it should be cfg:<section>.<key>=<value>")
+			continue
+		}
+		props[parts[0]] = parts[1]
+	}
+	propNames := slices.Sorted(maps.Keys(props))
+	// the command line defaults are overridden by the config file
+	for _, prop := range propNames {
+		if strings.HasPrefix(prop, "default.") {
+			v.setProperty(prop, defaults)
+		}
+	}
+
+	configFile := args.Config
+	if configFile == "" {
+		configFile = filepath.Join(cfg.HomePath, customInitPath)
+		if !pathExists(configFile) {
+			configFile = ""
+		}
+	}
+	existing := defaults
+	if configFile != "" {
+		userConfig, err := ini.Load(configFile)
+		if err != nil {
+			v.report(ConfigIssueError, configFile, "", "", fmt.Sprintf("failed to parse: %s", err))
+			return v.issues, nil
+		}
+		for _, section := range userConfig.Sections() {
+			for _, key := range section.Keys() {
+				// empty values don't override the defaults
+				if key.Value() != "" {
+					v.set(configFile, section.Name(), key.Name())
+				}
+			}
+		}
+		if existing, err = ini.Load(filepath.Join(cfg.HomePath, "conf/defaults.ini"), configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	// the environment variables and command line arguments only override the existing settings
+	envKeys := map[string][2]string{}
+	for _, section := range existing.Sections() {
+		for _, key := range section.Keys() {
+			envKeys[EnvKey(section.Name(), key.Name())] = [2]string{section.Name(), key.Name()}
+		}
+	}
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(name, "GF_") || slices.Contains(ignoredEnvVariables, name) ||
+			strings.HasPrefix(name, "GF_DIAGNOSTICS_") || strings.HasSuffix(name, "__FILE") {
+			continue
+		}
+		if key, ok := envKeys[name]; ok {
+			v.set(name+" environment variable", key[0], key[1])
+			continue
+		}
+		v.report(ConfigIssueWarning, name+" environment variable", "", "", "doesn't override any setting")
+	}
+
+	for _, prop := range propNames {
+		if !strings.HasPrefix(prop, "default.") {
+			v.setProperty(prop, existing)
+		}
+	}
+
+	if slices.ContainsFunc(v.issues, func(i ConfigIssue) bool { return i.Severity == ConfigIssueError }) {
+		// the configuration can't be loaded
+		return v.issues, nil
+	}
+	merged, err := cfg.mergeConfiguration(args)
+	if err != nil {
+		v.report(ConfigIssueError, "configuration", "", "", err.Error())
+		return v.issues, nil
+	}
+	v.checkTypes(merged)
+	return v.issues, nil
+}
+
+// setProperty records the setting of a command line argument, when it overrides a setting of the file.
+func (v *configValidator) setProperty(prop string, file *ini.File) {
+	source := "cfg:" + prop + " command line argument"
+	name := strings.TrimPrefix(prop, "default.")
+	section, key := ini.DefaultSection, name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		section, key = name[:i], name[i+1:]
+	}
+	if s, err := file.GetSection(section); err == nil && s.HasKey(key) {
+		v.set(source, section, key)
+		return
+	}
+	v.report(ConfigIssueWarning, source, "", "", "doesn't override any setting")
+}
+
+type configValidator struct {
+	defaults *ini.File
+	// sources are the sources of the values which aren't defaults, by section and key
+	sources map[string]map[string]string
+	issues  []ConfigIssue
+}
+
+func (v *configValidator) report(severity ConfigIssueSeverity, source, section, key, message string) {
+	v.issues = append(v.issues, ConfigIssue{Severity: severity, Source: source, Section: section, Key: key, Message: message})
+}
+
+// set records the source of a value, and checks whether the setting exists and is deprecated.
+func (v *configValidator) set(source, section, key string) {
+	if v.sources[section] == nil {
+		v.sources[section] = map[string]string{}
+	}
+	v.sources[section][key] = source
+
+	if message, ok := deprecatedSettings[section][key]; ok {
+		v.report(ConfigIssueWarning, source, section, key, "is deprecated, "+message)
+		return
+	}
+	if isFreeFormSection(section) {
+		return
+	}
+	defaultSection, err := v.defaults.GetSection(section)
+	if err != nil {
+		v.report(ConfigIssueWarning, source, section, key, "is unknown, the section isn't in defaults.ini")
+		return
+	}
+	if !defaultSection.HasKey(key) {
+		v.report(ConfigIssueWarning, source, section, key, "is unknown, the setting isn't in defaults.ini")
+	}
+}
+
+// checkTypes checks that the values which aren't defaults can be parsed like their default values.
+func (v *configValidator) checkTypes(merged *ini.File) {
+	for _, section := range merged.Sections() {
+		for _, key := range section.Keys() {
+			source, ok := v.sources[section.Name()][key.Name()]
+			if !ok {
+				continue
+			}
+			defaultSection, err := v.defaults.GetSection(section.Name())
+			if err != nil || !defaultSection.HasKey(key.Name()) {
+				continue
+			}
+			if message := checkType(defaultSection.Key(key.Name()).Value(), key.Value()); message != "" {
+				v.report(ConfigIssueError, source, section.Name(), key.Name(), message)
+			}
+		}
+	}
+}
+
+// checkType returns why the value can't be parsed with the type of the default value. The numbers are also
+// accepted as durations, since durations often default to 0.
+func checkType(defaultValue, value string) string {
+	switch {
+	case value == "":
+	case defaultValue == "true" || defaultValue == "false":
+		if _, err := parseIniBool(value); err != nil {
+			return fmt.Sprintf("has the invalid value %q, it should be true or false", value)
+		}
+	case integerRegex.MatchString(defaultValue):
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil && !isDuration(value) {
+			return fmt.Sprintf("has the invalid value %q, it should be a number", value)
+		}
+	case durationRegex.MatchString(defaultValue):
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil && !isDuration(value) {
+			return fmt.Sprintf("has the invalid value %q, it should be a duration like 10s, 5m or 1h", value)
+		}
+	}
+	return ""
+}
+
+func parseIniBool(value string) (bool, error) {
+	key, err := ini.Empty().Section("").NewKey("value", value)
+	if err != nil {
+		return false, err
+	}
+	return key.Bool()
+}
+
+func isDuration(value string) bool {
+	if _, err := time.ParseDuration(value); err == nil {
+		return true
+	}
+	_, err := gtime.ParseDuration(value)
+	return err == nil
+}
+
+func isFreeFormSection(section string) bool {
+	if slices.Contains(freeFormSections, section) {
+		return true
+	}
+	for _, prefix := range freeFormSectionPrefixes {
+		if strings.HasPrefix(section, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteEffectiveConfig writes the configuration merged from the defaults, the config file, the environment
+// variables and the command line arguments as an ini file. The sensitive values are redacted when redact is set.
+func WriteEffectiveConfig(w io.Writer, args CommandLineArgs, redact bool) error {
+	cfg := NewCfg()
+	cfg.setHomePath(args)
+	merged, err := cfg.mergeConfiguration(args)
+	if err != nil {
+		return err
+	}
+
+	out := ini.Empty()
+	for _, section := range merged.Sections() {
+		outSection := out.Section(section.Name())
+		for _, key := range section.Keys() {
+			value := key.Value()
+			if redact {
+				value = RedactedValue(EnvKey(section.Name(), key.Name()), value)
+			}
+			if _, err := outSection.NewKey(key.Name(), value); err != nil {
+				return err
+			}
+		}
+	}
+	_, err = out.WriteTo(w)
+	return err
+}
@@ -0,0 +1,90 @@
+package setting
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const validateDefaults = `app_mode = production
+
+[server]
+http_port = 3000
+read_timeout = 0
+enable_gzip = false
+
+[database]
+password =
+max_idle_conn = 2
+conn_max_lifetime = 14400
+
+[users]
+viewers_can_edit = false
+
+[log]
+mode = console
+`
+
+func writeValidateConfig(t *testing.T, custom string) (string, string) {
+	t.Helper()
+	home := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(home, "conf"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(home, "conf/defaults.ini"), []byte(validateDefaults), 0o600))
+	config := filepath.Join(home, "grafana.ini")
+	require.NoError(t, os.WriteFile(config, []byte(custom), 0o600))
+	return home, config
+}
+
+func TestValidateConfig(t *testing.T) {
+	t.Run("should accept a valid configuration", func(t *testing.T) {
+		home, config := writeValidateConfig(t, "[server]\nhttp_port = 8080\nread_timeout = 30s\nenable_gzip = on\n\n[feature_toggles]\nmyFeature = true\n")
+		issues, err := ValidateConfig(CommandLineArgs{HomePath: home, Config: config})
+		require.NoError(t, err)
+		require.Empty(t, issues)
+	})
+
+	t.Run("should report the unknown, invalid and deprecated settings", func(t *testing.T) {
+		home, config := writeValidateConfig(t, "[server]\nhtpp_port = 8080\nenable_gzip = maybe\n\n[users]\nviewers_can_edit = true\n\n[servr]\nhttp_port = 80\n")
+		t.Setenv("GF_DATABASE_MAX_IDLE_CONN", "two")
+		t.Setenv("GF_DATABASE_MAX_IDLE_CONNS", "2")
+		issues, err := ValidateConfig(CommandLineArgs{HomePath: home, Config: config, Args: []string{"cfg:server.http_port=abc", "cfg:server.unknown=1"}})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []ConfigIssue{
+			{Severity: ConfigIssueWarning, Source: config, Section: "server", Key: "htpp_port", Message: "is unknown, the setting isn't in defaults.ini"},
+			{Severity: ConfigIssueWarning, Source: config, Section: "users", Key: "viewers_can_edit", Message: "is deprecated, assign the viewers to the Editor role instead"},
+			{Severity: ConfigIssueWarning, Source: config, Section: "servr", Key: "http_port", Message: "is unknown, the section isn't in defaults.ini"},
+			{Severity: ConfigIssueWarning, Source: "GF_DATABASE_MAX_IDLE_CONNS environment variable", Message: "doesn't override any setting"},
+			{Severity: ConfigIssueWarning, Source: "cfg:server.unknown command line argument", Message: "doesn't override any setting"},
+			{Severity: ConfigIssueError, Source: config, Section: "server", Key: "enable_gzip", Message: `has the invalid value "maybe", it should be true or false`},
+			{Severity: ConfigIssueError, Source: "GF_DATABASE_MAX_IDLE_CONN environment variable", Section: "database", Key: "max_idle_conn", Message: `has the invalid value "two", it should be a number`},
+			{Severity: ConfigIssueError, Source: "cfg:server.http_port command line argument", Section: "server", Key: "http_port", Message: `has the invalid value "abc", it should be a number`},
+		}, issues)
+	})
+
+	t.Run("should report a config file which can't be parsed", func(t *testing.T) {
+		home, config := writeValidateConfig(t, "[server\nhttp_port = 8080\n")
+		issues, err := ValidateConfig(CommandLineArgs{HomePath: home, Config: config})
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		require.Equal(t, ConfigIssueError, issues[0].Severity)
+	})
+}
+
+func TestWriteEffectiveConfig(t *testing.T) {
+	home, config := writeValidateConfig(t, "[database]\npassword = secret\n")
+	t.Setenv("GF_SERVER_HTTP_PORT", "8080")
+	args := CommandLineArgs{HomePath: home, Config: config, Args: []string{"cfg:database.max_idle_conn=5"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteEffectiveConfig(&buf, args, true))
+	require.Regexp(t, `http_port\s+= 8080`, buf.String())
+	require.Regexp(t, `password\s+= \*+\n`, buf.String())
+	require.Regexp(t, `max_idle_conn\s+= 5`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteEffectiveConfig(&buf, args, false))
+	require.Regexp(t, `password\s+= secret`, buf.String())
+}
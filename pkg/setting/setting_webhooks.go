@@ -0,0 +1,48 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+type WebhooksSettings struct {
+	// Enabled allows the organizations to subscribe outgoing webhooks to resource events
+	Enabled bool
+	// DispatchInterval is the interval between two dispatches of the pending deliveries
+	DispatchInterval time.Duration
+	// RequestTimeout is the timeout of a delivery request
+	RequestTimeout time.Duration
+	// RetryDelay is the delay before the first retry of a failed delivery, doubled after each failure
+	RetryDelay time.Duration
+	// MaxAttempts is the number of failed attempts after which a delivery is given up
+	MaxAttempts int
+	// DeliveryRetention is how long the deliveries are kept in the delivery log
+	DeliveryRetention time.Duration
+	// MaxWebhooksPerOrg is the number of webhooks an organization can create
+	MaxWebhooksPerOrg int
+}
+
+func (cfg *Cfg) readWebhooksSettings() error {
+	section := cfg.SectionWithEnvOverrides("webhooks")
+	webhooks := WebhooksSettings{}
+	webhooks.Enabled = section.Key("enabled").MustBool(false)
+	webhooks.DispatchInterval = section.Key("dispatch_interval").MustDuration(10 * time.Second)
+	webhooks.RequestTimeout = section.Key("request_timeout").MustDuration(10 * time.Second)
+	webhooks.RetryDelay = section.Key("retry_delay").MustDuration(30 * time.Second)
+	webhooks.MaxAttempts = section.Key("max_attempts").MustInt(8)
+	if webhooks.MaxAttempts < 1 {
+		return fmt.Errorf("invalid max_attempts in [webhooks]: must be at least 1")
+	}
+	webhooks.MaxWebhooksPerOrg = section.Key("max_webhooks_per_org").MustInt(20)
+
+	retention, err := gtime.ParseDuration(section.Key("delivery_retention").MustString("7d"))
+	if err != nil {
+		return fmt.Errorf("invalid delivery_retention in [webhooks]: %w", err)
+	}
+	webhooks.DeliveryRetention = retention
+
+	cfg.Webhooks = webhooks
+	return nil
+}
@@ -22,6 +22,13 @@ type RBACSettings struct {
 
 	OnlyStoreAccessActionSets bool
 
+	// Require temporary grants to be approved, even when requested by a user allowed to approve them
+	TemporaryGrantsApprovalRequired bool
+	// Longest duration a temporary grant can be requested for
+	TemporaryGrantsMaxDuration time.Duration
+	// How often expired temporary grants are revoked
+	TemporaryGrantsRevokeInterval time.Duration
+
 	// set of resources that should generate managed permissions when created
 	resourcesWithPermissionsOnCreation map[string]struct{}
 
@@ -59,6 +66,13 @@ func (cfg *Cfg) readRBACSettings() {
 		s.ZanzanaReconciliationInterval = 1 * time.Hour
 	}
 
+	s.TemporaryGrantsApprovalRequired = rbac.Key("temporary_grants_approval_required").MustBool(false)
+	s.TemporaryGrantsMaxDuration = rbac.Key("temporary_grants_max_duration").MustDuration(24 * time.Hour)
+	s.TemporaryGrantsRevokeInterval = rbac.Key("temporary_grants_revoke_interval").MustDuration(time.Minute)
+	if s.TemporaryGrantsRevokeInterval <= 0 {
+		s.TemporaryGrantsRevokeInterval = time.Minute
+	}
+
 	cfg.RBAC = s
 }
 
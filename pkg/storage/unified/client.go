@@ -25,6 +25,7 @@ import (
 	secrets "github.com/grafana/grafana/pkg/registry/apis/secret/contracts"
 	"github.com/grafana/grafana/pkg/services/apiserver/options"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	legacysecrets "github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/storage/legacysql"
 	"github.com/grafana/grafana/pkg/storage/unified/federated"
@@ -43,6 +44,9 @@ type Options struct {
 	Authzc       types.AccessClient
 	Docs         resource.DocumentBuilderSupplier
 	SecureValues secrets.InlineSecureValueSupport
+
+	// Encrypts the values of the resources configured in [unified_storage_encryption]
+	SecretsService legacysecrets.Service
 }
 
 type clientMetrics struct {
@@ -64,7 +68,7 @@ func ProvideUnifiedStorageClient(opts *Options,
 		SearchServerAddress: apiserverCfg.Key("search_server_address").MustString(""),
 		BlobStoreURL:        apiserverCfg.Key("blob_url").MustString(""),
 		BlobThresholdBytes:  apiserverCfg.Key("blob_threshold_bytes").MustInt(options.BlobThresholdDefault),
-	}, opts.Cfg, opts.Features, opts.DB, opts.Tracer, opts.Reg, opts.Authzc, opts.Docs, storageMetrics, indexMetrics, opts.SecureValues)
+	}, opts.Cfg, opts.Features, opts.DB, opts.Tracer, opts.Reg, opts.Authzc, opts.Docs, storageMetrics, indexMetrics, opts.SecureValues, opts.SecretsService)
 	if err == nil {
 		// Used to get the folder stats
 		client = federated.NewFederatedClient(
@@ -87,6 +91,7 @@ func newClient(opts options.StorageOptions,
 	storageMetrics *resource.StorageMetrics,
 	indexMetrics *resource.BleveIndexMetrics,
 	secure secrets.InlineSecureValueSupport,
+	secretsService legacysecrets.Service,
 ) (resource.ResourceClient, error) {
 	ctx := context.Background()
 
@@ -170,6 +175,7 @@ func newClient(opts options.StorageOptions,
 			IndexMetrics:   indexMetrics,
 			Features:       features,
 			SecureValues:   secure,
+			SecretsService: secretsService,
 		}
 
 		if cfg.QOSEnabled {
@@ -17,6 +17,8 @@ type BleveIndexMetrics struct {
 	IndexBuilds        *prometheus.CounterVec
 	IndexBuildFailures prometheus.Counter
 	IndexBuildSkipped  prometheus.Counter
+	IndexUpdates       prometheus.Counter
+	IndexLag           *prometheus.GaugeVec
 }
 
 var IndexCreationBuckets = []float64{1, 5, 10, 25, 50, 75, 100, 200, 300, 400, 500, 600, 700, 800, 900, 1000}
@@ -63,6 +65,14 @@ func ProvideIndexMetrics(reg prometheus.Registerer) *BleveIndexMetrics {
 			Name: "index_server_index_build_skipped_total",
 			Help: "Number of times index build has been skipped due to existing valid index being found on disk",
 		}),
+		IndexUpdates: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "index_server_index_updates_total",
+			Help: "Number of times an outdated index found on disk has been updated with the changes since its resource version, instead of being rebuilt",
+		}),
+		IndexLag: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "index_server_index_lag_seconds",
+			Help: "Time (in seconds) between the last write to the resource and the last change applied to its index",
+		}, []string{"resource"}),
 	}
 
 	// Initialize labels.
@@ -0,0 +1,128 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+// BlobCollector is implemented by the blob stores which can list and delete their blobs.
+// The blobs of these stores which no resource version references are deleted by the blob garbage collection.
+type BlobCollector interface {
+	// ListResourceBlobs lists the blobs of the resources of the group resource in the namespace
+	ListResourceBlobs(ctx context.Context, key NamespacedResource) iter.Seq2[*ResourceBlob, error]
+
+	// DeleteResourceBlob deletes a blob returned by ListResourceBlobs
+	DeleteResourceBlob(ctx context.Context, blob *ResourceBlob) error
+}
+
+// ResourceBlob is a blob linked to a resource
+type ResourceBlob struct {
+	Key      *resourcepb.ResourceKey
+	UID      string
+	Size     int64
+	Modified time.Time
+
+	// Path is the location of the blob in the store
+	Path string
+}
+
+func (s *server) runBlobGC(collector BlobCollector) {
+	ticker := time.NewTicker(s.blobGCInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.collectBlobs(s.ctx, collector, time.Now().Add(-s.blobGCMinAge))
+			if err != nil {
+				s.log.Error("blob garbage collection failed", "error", err)
+			}
+			if deleted > 0 {
+				s.log.Info("deleted orphaned blobs", "count", deleted)
+			}
+		}
+	}
+}
+
+// collectBlobs deletes the blobs modified before the given time which no version of their resource references,
+// for example because the resource write failed after the blob was written. It returns the number of deleted blobs.
+func (s *server) collectBlobs(ctx context.Context, collector BlobCollector, before time.Time) (int, error) {
+	stats, err := s.backend.GetResourceStats(ctx, "", 0)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, stat := range stats {
+		// the candidates by resource name
+		candidates := map[string][]*ResourceBlob{}
+		for blob, err := range collector.ListResourceBlobs(ctx, stat.NamespacedResource) {
+			if err != nil {
+				return deleted, err
+			}
+			if blob.Modified.Before(before) {
+				candidates[blob.Key.Name] = append(candidates[blob.Key.Name], blob)
+			}
+		}
+
+		for _, blobs := range candidates {
+			referenced, err := s.referencedBlobs(ctx, blobs[0].Key)
+			if err != nil {
+				return deleted, err
+			}
+			for _, blob := range blobs {
+				if _, ok := referenced[blob.UID]; ok {
+					continue
+				}
+				if err := collector.DeleteResourceBlob(ctx, blob); err != nil {
+					return deleted, err
+				}
+				deleted++
+			}
+		}
+	}
+	return deleted, nil
+}
+
+// referencedBlobs returns the UIDs of the blobs referenced by any version of the resource, including its deletion
+func (s *server) referencedBlobs(ctx context.Context, key *resourcepb.ResourceKey) (map[string]struct{}, error) {
+	referenced := map[string]struct{}{}
+	// the versions not older than the first one include the versions before the latest deletion
+	_, err := s.backend.ListHistory(ctx, &resourcepb.ListRequest{
+		Options:         &resourcepb.ListOptions{Key: key},
+		Source:          resourcepb.ListRequest_HISTORY,
+		ResourceVersion: 1,
+		VersionMatchV2:  resourcepb.ResourceVersionMatchV2_NotOlderThan,
+	}, func(iter ListIterator) error {
+		for iter.Next() {
+			if err := iter.Error(); err != nil {
+				return err
+			}
+			partial := &metav1.PartialObjectMetadata{}
+			if err := json.Unmarshal(iter.Value(), partial); err != nil {
+				return err
+			}
+			obj, err := utils.MetaAccessor(partial)
+			if err != nil {
+				return err
+			}
+			if blob := obj.GetBlob(); blob != nil && blob.UID != "" {
+				referenced[blob.UID] = struct{}{}
+			}
+		}
+		return iter.Error()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return referenced, nil
+}
@@ -0,0 +1,86 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob/memblob"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+func TestBlobGC(t *testing.T) {
+	ctx := context.Background()
+	backend := setupTestStorageBackend(t)
+	blobs, err := NewCDKBlobSupport(ctx, CDKBlobSupportOptions{
+		Bucket: memblob.OpenBucket(nil),
+	})
+	require.NoError(t, err)
+	collector, ok := blobs.(BlobCollector)
+	require.True(t, ok)
+	s := &server{backend: backend, blob: blobs}
+
+	key := func(name string) *resourcepb.ResourceKey {
+		return &resourcepb.ResourceKey{Namespace: "default", Group: "dashboard.grafana.app", Resource: "dashboards", Name: name}
+	}
+	put := func(t *testing.T, key *resourcepb.ResourceKey) string {
+		rsp, err := blobs.PutResourceBlob(ctx, &resourcepb.PutBlobRequest{
+			Resource:    key,
+			Method:      resourcepb.PutBlobRequest_GRPC,
+			ContentType: "application/json",
+			Value:       []byte(`{"panels":[]}`),
+		})
+		require.NoError(t, err)
+		return rsp.Uid
+	}
+	listBlobs := func(t *testing.T) []string {
+		var uids []string
+		for blob, err := range collector.ListResourceBlobs(ctx, NamespacedResource{Namespace: "default", Group: "dashboard.grafana.app", Resource: "dashboards"}) {
+			require.NoError(t, err)
+			uids = append(uids, blob.UID)
+		}
+		return uids
+	}
+
+	referenced := put(t, key("a"))
+	// the dashboard was updated with another blob, but the update failed
+	put(t, key("a"))
+	// the dashboard was never created
+	put(t, key("b"))
+
+	obj, err := createTestObjectWithName("a", "dashboard.grafana.app", "reduced spec")
+	require.NoError(t, err)
+	meta, err := utils.MetaAccessor(obj)
+	require.NoError(t, err)
+	meta.SetBlob(&utils.BlobInfo{UID: referenced})
+	_, err = backend.WriteEvent(ctx, WriteEvent{
+		Type:   resourcepb.WatchEvent_ADDED,
+		Key:    key("a"),
+		Value:  objectToJSONBytes(t, obj),
+		Object: meta,
+	})
+	require.NoError(t, err)
+	require.Len(t, listBlobs(t), 3)
+
+	t.Run("recent blobs are kept", func(t *testing.T) {
+		deleted, err := s.collectBlobs(ctx, collector, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		require.Zero(t, deleted)
+		require.Len(t, listBlobs(t), 3)
+	})
+
+	t.Run("orphaned blobs are deleted", func(t *testing.T) {
+		deleted, err := s.collectBlobs(ctx, collector, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, 2, deleted)
+		require.Equal(t, []string{referenced}, listBlobs(t))
+	})
+}
+
+func TestBlobHash(t *testing.T) {
+	// the hash of the blobs written to the SQL and CDK blob stores
+	require.Equal(t, "49dfdd54b01cbcd2d2ab5e9e5ee6b9b9", blobHash([]byte(`{"hello": "world"}`)))
+}
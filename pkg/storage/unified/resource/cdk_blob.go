@@ -5,7 +5,10 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"iter"
 	"mime"
 	"strings"
 	"time"
@@ -14,6 +17,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.opentelemetry.io/otel/trace/noop"
 	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
 
 	"github.com/grafana/grafana/pkg/apimachinery/utils"
 	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
@@ -86,16 +90,18 @@ type cdkBlobSupport struct {
 	expiration  time.Duration
 }
 
-func (s *cdkBlobSupport) getBlobPath(key *resourcepb.ResourceKey, info *utils.BlobInfo) (string, error) {
-	var buffer bytes.Buffer
-	buffer.WriteString(s.root)
+var _ BlobCollector = (*cdkBlobSupport)(nil)
 
-	if key.Namespace == "" {
-		buffer.WriteString("__cluster__/")
-	} else {
-		buffer.WriteString(key.Namespace)
-		buffer.WriteString("/")
+func (s *cdkBlobSupport) namespacePath(namespace string) string {
+	if namespace == "" {
+		return s.root + "__cluster__/"
 	}
+	return s.root + namespace + "/"
+}
+
+func (s *cdkBlobSupport) getBlobPath(key *resourcepb.ResourceKey, info *utils.BlobInfo) (string, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString(s.namespacePath(key.Namespace))
 
 	if key.Group == "" {
 		return "", fmt.Errorf("missing group")
@@ -195,3 +201,57 @@ func (s *cdkBlobSupport) GetResourceBlob(ctx context.Context, resource *resource
 	})
 	return rsp, err
 }
+
+// ListResourceBlobs implements BlobCollector.
+func (s *cdkBlobSupport) ListResourceBlobs(ctx context.Context, key NamespacedResource) iter.Seq2[*ResourceBlob, error] {
+	return func(yield func(*ResourceBlob, error) bool) {
+		prefix := s.namespacePath(key.Namespace) + key.Group + "/" + key.Resource + "/"
+		it := s.bucket.List(&blob.ListOptions{Prefix: prefix})
+		for {
+			obj, err := it.Next(ctx)
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			// the blobs are stored as {name}/{uid}{ext}
+			name, file, ok := strings.Cut(strings.TrimPrefix(obj.Key, prefix), "/")
+			if !ok || obj.IsDir || strings.Contains(file, "/") {
+				continue
+			}
+			uid, _, _ := strings.Cut(file, ".")
+			if !yield(&ResourceBlob{
+				Key: &resourcepb.ResourceKey{
+					Namespace: key.Namespace,
+					Group:     key.Group,
+					Resource:  key.Resource,
+					Name:      name,
+				},
+				UID:      uid,
+				Size:     obj.Size,
+				Modified: obj.ModTime,
+				Path:     obj.Key,
+			}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// DeleteResourceBlob implements BlobCollector.
+func (s *cdkBlobSupport) DeleteResourceBlob(ctx context.Context, b *ResourceBlob) error {
+	err := s.bucket.Delete(ctx, b.Path)
+	if gcerrors.Code(err) == gcerrors.NotFound {
+		// already deleted by another instance
+		return nil
+	}
+	return err
+}
+
+// blobHash returns the hash of a blob value, the hex encoded MD5 like the hash stored in the blob info
+func blobHash(value []byte) string {
+	sum := md5.Sum(value)
+	return hex.EncodeToString(sum[:])
+}
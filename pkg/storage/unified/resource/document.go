@@ -309,8 +309,9 @@ const SEARCH_FIELD_SOURCE_PATH = "source.path"
 const SEARCH_FIELD_SOURCE_CHECKSUM = "source.checksum"
 const SEARCH_FIELD_SOURCE_TIME = "source.timestampMillis"
 
-const SEARCH_FIELD_SCORE = "_score"     // the match score
-const SEARCH_FIELD_EXPLAIN = "_explain" // score explanation as JSON object
+const SEARCH_FIELD_SCORE = "_score"         // the match score
+const SEARCH_FIELD_EXPLAIN = "_explain"     // score explanation as JSON object
+const SEARCH_FIELD_HIGHLIGHT = "_highlight" // matched text fragments by field as JSON object
 
 var standardSearchFieldsInit sync.Once
 var standardSearchFields SearchableDocumentFields
@@ -398,6 +399,11 @@ func StandardSearchFields() SearchableDocumentFields {
 				Type:        resourcepb.ResourceTableColumnDefinition_DOUBLE,
 				Description: "The search score",
 			},
+			{
+				Name:        SEARCH_FIELD_HIGHLIGHT,
+				Type:        resourcepb.ResourceTableColumnDefinition_OBJECT,
+				Description: "Fragments of the text matching the query, by field",
+			},
 			{
 				Name:        SEARCH_FIELD_LEGACY_ID,
 				Type:        resourcepb.ResourceTableColumnDefinition_INT64,
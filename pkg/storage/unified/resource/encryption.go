@@ -0,0 +1,498 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+// ValueEncrypter encrypts the values of the resources before they are stored
+type ValueEncrypter interface {
+	Encrypt(ctx context.Context, value []byte) ([]byte, error)
+	Decrypt(ctx context.Context, value []byte) ([]byte, error)
+}
+
+// KeyRotator is implemented by the encrypters which can rotate their data keys. The values encrypted with the
+// previous keys can still be decrypted until they are re-encrypted.
+type KeyRotator interface {
+	RotateKeys(ctx context.Context) error
+}
+
+// ValueRewriter is implemented by the storage backends which can rewrite the stored values in place, without
+// writing new resource versions. It is required to re-encrypt the values.
+type ValueRewriter interface {
+	// RewriteValues calls rewrite with every stored value of the group resource in the namespace, including the
+	// history, and stores the returned values which changed. It returns the number of rewritten values.
+	RewriteValues(ctx context.Context, key NamespacedResource, rewrite func(value []byte) ([]byte, error)) (int64, error)
+}
+
+type EncryptionConfig struct {
+	// Encrypter of the values, required when resources are configured
+	Encrypter ValueEncrypter
+
+	// The group resources whose values are encrypted. The blobs of these resources are not encrypted.
+	Resources []schema.GroupResource
+
+	// ReEncryptInterval is the interval between two re-encryptions of the stored values, zero only
+	// re-encrypts them on request
+	ReEncryptInterval time.Duration
+}
+
+// ReEncryptionState is the state of the re-encryption of the stored values
+type ReEncryptionState string
+
+const (
+	ReEncryptionIdle      ReEncryptionState = "idle"
+	ReEncryptionRunning   ReEncryptionState = "running"
+	ReEncryptionCompleted ReEncryptionState = "completed"
+	ReEncryptionFailed    ReEncryptionState = "failed"
+)
+
+// ErrReEncryptionRunning is returned when a re-encryption is started while another one is running
+var ErrReEncryptionRunning = errors.New("re-encryption already running")
+
+// ReEncryptionStatus is the progress of the last re-encryption of the stored values
+type ReEncryptionStatus struct {
+	State    ReEncryptionState `json:"state"`
+	Started  *time.Time        `json:"started,omitempty"`
+	Finished *time.Time        `json:"finished,omitempty"`
+
+	// Number of group resources by namespace to re-encrypt, and the number of them already re-encrypted
+	Total int `json:"total"`
+	Done  int `json:"done"`
+
+	// Number of re-encrypted values, including the history
+	Rewritten int64 `json:"rewritten"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// ReEncrypter re-encrypts the stored values with the current data key
+type ReEncrypter interface {
+	// StartReEncryption starts re-encrypting the stored values in the background. When rotate is true, the data
+	// keys are rotated first.
+	StartReEncryption(ctx context.Context, rotate bool) (ReEncryptionStatus, error)
+
+	// GetReEncryptionStatus returns the progress of the last re-encryption
+	GetReEncryptionStatus(ctx context.Context) (ReEncryptionStatus, error)
+}
+
+var _ ReEncrypter = (*server)(nil)
+
+// StartReEncryption implements ReEncrypter.
+func (s *server) StartReEncryption(ctx context.Context, rotate bool) (ReEncryptionStatus, error) {
+	if err := s.Init(ctx); err != nil {
+		return ReEncryptionStatus{}, err
+	}
+	if s.encryption == nil {
+		return ReEncryptionStatus{}, fmt.Errorf("resource encryption is not configured")
+	}
+	return s.encryption.start(s.ctx, rotate)
+}
+
+// GetReEncryptionStatus implements ReEncrypter.
+func (s *server) GetReEncryptionStatus(ctx context.Context) (ReEncryptionStatus, error) {
+	if err := s.Init(ctx); err != nil {
+		return ReEncryptionStatus{}, err
+	}
+	if s.encryption == nil {
+		return ReEncryptionStatus{}, fmt.Errorf("resource encryption is not configured")
+	}
+	return s.encryption.getStatus(), nil
+}
+
+func (s *server) runReEncryption() {
+	ticker := time.NewTicker(s.encryption.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.encryption.start(s.ctx, false); err != nil && !errors.Is(err, ErrReEncryptionRunning) {
+				s.log.Error("failed to start re-encryption", "error", err)
+			}
+		}
+	}
+}
+
+// NewReEncryptionHandler returns the HTTP handler reporting the progress of the re-encryption of the stored values
+// on GET, and starting it on POST. The rotate query parameter rotates the data keys before re-encrypting.
+func NewReEncryptionHandler(reEncrypter ReEncrypter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			status ReEncryptionStatus
+			err    error
+		)
+		code := http.StatusOK
+		switch r.Method {
+		case http.MethodGet:
+			status, err = reEncrypter.GetReEncryptionStatus(r.Context())
+		case http.MethodPost:
+			status, err = reEncrypter.StartReEncryption(r.Context(), r.URL.Query().Get("rotate") == "true")
+			code = http.StatusAccepted
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if errors.Is(err, ErrReEncryptionRunning) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(status)
+	})
+}
+
+// encryptedValuePrefix marks the encrypted values, the values without it are read as they are stored so the
+// resources written before their encryption was configured can still be read
+var encryptedValuePrefix = []byte("$encrypted$")
+
+func encryptValue(ctx context.Context, encrypter ValueEncrypter, value []byte) ([]byte, error) {
+	encrypted, err := encrypter.Encrypt(ctx, value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt value: %w", err)
+	}
+	// the values are stored as text
+	out := make([]byte, len(encryptedValuePrefix)+base64.StdEncoding.EncodedLen(len(encrypted)))
+	copy(out, encryptedValuePrefix)
+	base64.StdEncoding.Encode(out[len(encryptedValuePrefix):], encrypted)
+	return out, nil
+}
+
+func decryptValue(ctx context.Context, encrypter ValueEncrypter, value []byte) ([]byte, error) {
+	if !bytes.HasPrefix(value, encryptedValuePrefix) {
+		return value, nil
+	}
+	encrypted := make([]byte, base64.StdEncoding.DecodedLen(len(value)-len(encryptedValuePrefix)))
+	n, err := base64.StdEncoding.Decode(encrypted, value[len(encryptedValuePrefix):])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode encrypted value: %w", err)
+	}
+	decrypted, err := encrypter.Decrypt(ctx, encrypted[:n])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return decrypted, nil
+}
+
+// resourceEncryption encrypts the values of the configured resources, and re-encrypts the stored values
+type resourceEncryption struct {
+	encrypter ValueEncrypter
+	resources map[schema.GroupResource]bool
+	interval  time.Duration
+	log       *slog.Logger
+
+	// The backend storing the encrypted values
+	backend StorageBackend
+
+	mu     sync.Mutex
+	status ReEncryptionStatus
+}
+
+func newResourceEncryption(cfg EncryptionConfig, backend StorageBackend, log *slog.Logger) (*resourceEncryption, error) {
+	if len(cfg.Resources) == 0 {
+		return nil, nil
+	}
+	if cfg.Encrypter == nil {
+		return nil, fmt.Errorf("missing encrypter for the encrypted resources")
+	}
+	resources := make(map[schema.GroupResource]bool, len(cfg.Resources))
+	for _, gr := range cfg.Resources {
+		resources[gr] = true
+	}
+	return &resourceEncryption{
+		encrypter: cfg.Encrypter,
+		resources: resources,
+		interval:  cfg.ReEncryptInterval,
+		log:       log,
+		backend:   backend,
+		status:    ReEncryptionStatus{State: ReEncryptionIdle},
+	}, nil
+}
+
+func (e *resourceEncryption) encrypts(key *resourcepb.ResourceKey) bool {
+	return key != nil && e.resources[schema.GroupResource{Group: key.Group, Resource: key.Resource}]
+}
+
+func (e *resourceEncryption) getStatus() ReEncryptionStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.status
+}
+
+func (e *resourceEncryption) updateStatus(update func(status *ReEncryptionStatus)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	update(&e.status)
+}
+
+func (e *resourceEncryption) start(ctx context.Context, rotate bool) (ReEncryptionStatus, error) {
+	rewriter, ok := e.backend.(ValueRewriter)
+	if !ok {
+		return ReEncryptionStatus{}, fmt.Errorf("the storage backend does not support re-encryption")
+	}
+	if _, ok := e.encrypter.(KeyRotator); rotate && !ok {
+		return ReEncryptionStatus{}, fmt.Errorf("the encrypter does not support key rotation")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.status.State == ReEncryptionRunning {
+		return e.status, ErrReEncryptionRunning
+	}
+	started := time.Now()
+	e.status = ReEncryptionStatus{State: ReEncryptionRunning, Started: &started}
+
+	go func() {
+		rewritten, err := e.reEncrypt(ctx, rewriter, rotate)
+		if err != nil {
+			e.log.Error("re-encryption failed", "error", err, "rewritten", rewritten)
+		} else {
+			e.log.Info("re-encrypted the stored values", "rewritten", rewritten)
+		}
+
+		finished := time.Now()
+		e.updateStatus(func(status *ReEncryptionStatus) {
+			status.State = ReEncryptionCompleted
+			status.Finished = &finished
+			if err != nil {
+				status.State = ReEncryptionFailed
+				status.Error = err.Error()
+			}
+		})
+	}()
+	return e.status, nil
+}
+
+// reEncrypt encrypts the stored values of the configured resources with the current data key, including the
+// values stored before their encryption was configured
+func (e *resourceEncryption) reEncrypt(ctx context.Context, rewriter ValueRewriter, rotate bool) (int64, error) {
+	if rotate {
+		if err := e.encrypter.(KeyRotator).RotateKeys(ctx); err != nil {
+			return 0, fmt.Errorf("failed to rotate the data keys: %w", err)
+		}
+	}
+
+	stats, err := e.backend.GetResourceStats(ctx, "", 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get resource stats: %w", err)
+	}
+	keys := make([]NamespacedResource, 0, len(stats))
+	for _, stat := range stats {
+		if e.resources[schema.GroupResource{Group: stat.Group, Resource: stat.Resource}] {
+			keys = append(keys, stat.NamespacedResource)
+		}
+	}
+	e.updateStatus(func(status *ReEncryptionStatus) {
+		status.Total = len(keys)
+	})
+
+	var total int64
+	for _, key := range keys {
+		rewritten, err := rewriter.RewriteValues(ctx, key, func(value []byte) ([]byte, error) {
+			decrypted, err := decryptValue(ctx, e.encrypter, value)
+			if err != nil {
+				return nil, err
+			}
+			return encryptValue(ctx, e.encrypter, decrypted)
+		})
+		total += rewritten
+		e.updateStatus(func(status *ReEncryptionStatus) {
+			status.Rewritten = total
+		})
+		if err != nil {
+			return total, fmt.Errorf("failed to re-encrypt %s: %w", key.String(), err)
+		}
+		e.updateStatus(func(status *ReEncryptionStatus) {
+			status.Done++
+		})
+	}
+	return total, nil
+}
+
+// encryptedBackend encrypts the values of the configured resources written to the backend, and decrypts the
+// values read from it
+type encryptedBackend struct {
+	StorageBackend
+	encryption *resourceEncryption
+}
+
+var _ BulkProcessingBackend = (*encryptedBackend)(nil)
+
+func (b *encryptedBackend) decrypt(ctx context.Context, value []byte) ([]byte, error) {
+	return decryptValue(ctx, b.encryption.encrypter, value)
+}
+
+// WriteEvent implements StorageBackend.
+func (b *encryptedBackend) WriteEvent(ctx context.Context, event WriteEvent) (int64, error) {
+	if event.Value != nil && b.encryption.encrypts(event.Key) {
+		value, err := encryptValue(ctx, b.encryption.encrypter, event.Value)
+		if err != nil {
+			return 0, err
+		}
+		event.Value = value
+	}
+	return b.StorageBackend.WriteEvent(ctx, event)
+}
+
+// ReadResource implements StorageBackend.
+func (b *encryptedBackend) ReadResource(ctx context.Context, req *resourcepb.ReadRequest) *BackendReadResponse {
+	rsp := b.StorageBackend.ReadResource(ctx, req)
+	if rsp.Error == nil {
+		value, err := b.decrypt(ctx, rsp.Value)
+		if err != nil {
+			rsp.Error = AsErrorResult(err)
+		}
+		rsp.Value = value
+	}
+	return rsp
+}
+
+// ListIterator implements StorageBackend.
+func (b *encryptedBackend) ListIterator(ctx context.Context, req *resourcepb.ListRequest, cb func(ListIterator) error) (int64, error) {
+	return b.StorageBackend.ListIterator(ctx, req, func(iter ListIterator) error {
+		return cb(&decryptingIterator{ListIterator: iter, ctx: ctx, backend: b})
+	})
+}
+
+// ListHistory implements StorageBackend.
+func (b *encryptedBackend) ListHistory(ctx context.Context, req *resourcepb.ListRequest, cb func(ListIterator) error) (int64, error) {
+	return b.StorageBackend.ListHistory(ctx, req, func(iter ListIterator) error {
+		return cb(&decryptingIterator{ListIterator: iter, ctx: ctx, backend: b})
+	})
+}
+
+// ListModifiedSince implements StorageBackend.
+func (b *encryptedBackend) ListModifiedSince(ctx context.Context, key NamespacedResource, sinceRv int64) (int64, iter.Seq2[*ModifiedResource, error]) {
+	rv, seq := b.StorageBackend.ListModifiedSince(ctx, key, sinceRv)
+	return rv, func(yield func(*ModifiedResource, error) bool) {
+		for mr, err := range seq {
+			if err == nil && mr != nil {
+				mr.Value, err = b.decrypt(ctx, mr.Value)
+			}
+			if !yield(mr, err) {
+				return
+			}
+		}
+	}
+}
+
+// WatchWriteEvents implements StorageBackend.
+func (b *encryptedBackend) WatchWriteEvents(ctx context.Context) (<-chan *WrittenEvent, error) {
+	events, err := b.StorageBackend.WatchWriteEvents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stream := make(chan *WrittenEvent)
+	go func() {
+		defer close(stream)
+		for event := range events {
+			value, err := b.decrypt(ctx, event.Value)
+			if err != nil {
+				b.encryption.log.Error("failed to decrypt the watch event", "key", SearchID(event.Key), "rv", event.ResourceVersion, "error", err)
+				continue
+			}
+			event.Value = value
+			stream <- event
+		}
+	}()
+	return stream, nil
+}
+
+// ProcessBulk implements BulkProcessingBackend.
+func (b *encryptedBackend) ProcessBulk(ctx context.Context, setting BulkSettings, iter BulkRequestIterator) *resourcepb.BulkResponse {
+	backend, ok := b.StorageBackend.(BulkProcessingBackend)
+	if !ok {
+		return &resourcepb.BulkResponse{
+			Error: &resourcepb.ErrorResult{
+				Message: "The server backend does not support batch processing",
+				Code:    http.StatusNotImplemented,
+			},
+		}
+	}
+
+	runner := &encryptingBulkIterator{BulkRequestIterator: iter, ctx: ctx, backend: b}
+	rsp := backend.ProcessBulk(ctx, setting, runner)
+	if rsp != nil && runner.err != nil {
+		rsp.Error = AsErrorResult(runner.err)
+	}
+	return rsp
+}
+
+type decryptingIterator struct {
+	ListIterator
+	ctx     context.Context
+	backend *encryptedBackend
+
+	value []byte
+	err   error
+}
+
+// Next implements ListIterator.
+func (i *decryptingIterator) Next() bool {
+	if !i.ListIterator.Next() {
+		return false
+	}
+	i.value, i.err = i.backend.decrypt(i.ctx, i.ListIterator.Value())
+	return true
+}
+
+// Error implements ListIterator.
+func (i *decryptingIterator) Error() error {
+	if i.err != nil {
+		return i.err
+	}
+	return i.ListIterator.Error()
+}
+
+// Value implements ListIterator.
+func (i *decryptingIterator) Value() []byte {
+	return i.value
+}
+
+type encryptingBulkIterator struct {
+	BulkRequestIterator
+	ctx     context.Context
+	backend *encryptedBackend
+
+	err error
+}
+
+// Next implements BulkRequestIterator.
+func (i *encryptingBulkIterator) Next() bool {
+	if !i.BulkRequestIterator.Next() {
+		return false
+	}
+	req := i.BulkRequestIterator.Request()
+	// the requests are repeated when a rollback is requested, they must not be encrypted twice
+	if req == nil || len(req.Value) == 0 || bytes.HasPrefix(req.Value, encryptedValuePrefix) || !i.backend.encryption.encrypts(req.Key) {
+		return true
+	}
+	req.Value, i.err = encryptValue(i.ctx, i.backend.encryption.encrypter, req.Value)
+	return true
+}
+
+// RollbackRequested implements BulkRequestIterator.
+func (i *encryptingBulkIterator) RollbackRequested() bool {
+	return i.err != nil || i.BulkRequestIterator.RollbackRequested()
+}
@@ -0,0 +1,186 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	authlib "github.com/grafana/authlib/types"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+// testEncrypter hex encodes the values, prefixed with the version of its data key
+type testEncrypter struct {
+	mu  sync.Mutex
+	key int
+}
+
+func (e *testEncrypter) Encrypt(_ context.Context, value []byte) ([]byte, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return []byte(fmt.Sprintf("%d:%x", e.key, value)), nil
+}
+
+func (e *testEncrypter) Decrypt(_ context.Context, value []byte) ([]byte, error) {
+	_, v, ok := bytes.Cut(value, []byte(":"))
+	if !ok {
+		return nil, fmt.Errorf("invalid value")
+	}
+	return hex.DecodeString(string(v))
+}
+
+func (e *testEncrypter) RotateKeys(_ context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.key++
+	return nil
+}
+
+// rewritingBackend rewrites the latest values in memory
+type rewritingBackend struct {
+	StorageBackend
+
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func (b *rewritingBackend) RewriteValues(ctx context.Context, key NamespacedResource, rewrite func(value []byte) ([]byte, error)) (int64, error) {
+	var rewritten int64
+	_, err := b.ListIterator(ctx, &resourcepb.ListRequest{
+		Limit: 1000,
+		Options: &resourcepb.ListOptions{
+			Key: &resourcepb.ResourceKey{Namespace: key.Namespace, Group: key.Group, Resource: key.Resource},
+		},
+	}, func(iter ListIterator) error {
+		for iter.Next() {
+			if err := iter.Error(); err != nil {
+				return err
+			}
+			value, err := rewrite(iter.Value())
+			if err != nil {
+				return err
+			}
+			b.mu.Lock()
+			b.values[iter.Name()] = value
+			b.mu.Unlock()
+			rewritten++
+		}
+		return iter.Error()
+	})
+	return rewritten, err
+}
+
+func TestResourceEncryption(t *testing.T) {
+	ctx := authlib.WithAuthInfo(context.Background(), &identity.StaticRequester{
+		Type:           authlib.TypeUser,
+		Login:          "testuser",
+		UserID:         123,
+		UserUID:        "u123",
+		OrgRole:        identity.RoleAdmin,
+		IsGrafanaAdmin: true, // can do anything
+	})
+
+	encrypter := &testEncrypter{key: 1}
+	backend := &rewritingBackend{StorageBackend: setupTestStorageBackend(t), values: map[string][]byte{}}
+	server, err := NewResourceServer(ResourceServerOptions{
+		Backend: backend,
+		Encryption: EncryptionConfig{
+			Encrypter: encrypter,
+			Resources: []schema.GroupResource{{Group: "playlist.grafana.app", Resource: "playlists"}},
+		},
+	})
+	require.NoError(t, err)
+
+	key := &resourcepb.ResourceKey{Namespace: "default", Group: "playlist.grafana.app", Resource: "playlists", Name: "a"}
+	created, err := server.Create(ctx, &resourcepb.CreateRequest{
+		Key: key,
+		Value: []byte(`{
+			"apiVersion": "playlist.grafana.app/v0alpha1",
+			"kind": "Playlist",
+			"metadata": {"name": "a", "uid": "a", "namespace": "default"},
+			"spec": {"title": "hello"}
+		}`),
+	})
+	require.NoError(t, err)
+	require.Nil(t, created.Error)
+
+	t.Run("the values are stored encrypted", func(t *testing.T) {
+		stored := backend.ReadResource(ctx, &resourcepb.ReadRequest{Key: key})
+		require.Nil(t, stored.Error)
+		require.True(t, bytes.HasPrefix(stored.Value, encryptedValuePrefix))
+		require.NotContains(t, string(stored.Value), "hello")
+	})
+
+	t.Run("the values are read decrypted", func(t *testing.T) {
+		rsp, err := server.Read(ctx, &resourcepb.ReadRequest{Key: key})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		require.Contains(t, string(rsp.Value), `"title":"hello"`)
+
+		list, err := server.List(ctx, &resourcepb.ListRequest{
+			Options: &resourcepb.ListOptions{
+				Key: &resourcepb.ResourceKey{Namespace: "default", Group: "playlist.grafana.app", Resource: "playlists"},
+			},
+		})
+		require.NoError(t, err)
+		require.Nil(t, list.Error)
+		require.Len(t, list.Items, 1)
+		require.Contains(t, string(list.Items[0].Value), `"title":"hello"`)
+	})
+
+	t.Run("the values stored before the encryption are read as they are", func(t *testing.T) {
+		value, err := decryptValue(ctx, encrypter, []byte(`{"hello":"world"}`))
+		require.NoError(t, err)
+		require.Equal(t, `{"hello":"world"}`, string(value))
+	})
+
+	t.Run("the values are re-encrypted with the rotated key", func(t *testing.T) {
+		status, err := server.StartReEncryption(ctx, true)
+		require.NoError(t, err)
+		require.Equal(t, ReEncryptionRunning, status.State)
+
+		require.Eventually(t, func() bool {
+			return server.encryption.getStatus().State != ReEncryptionRunning
+		}, 5*time.Second, 10*time.Millisecond)
+		status, err = server.GetReEncryptionStatus(ctx)
+		require.NoError(t, err)
+		require.Equal(t, ReEncryptionCompleted, status.State, status.Error)
+		require.Equal(t, 1, status.Total)
+		require.Equal(t, 1, status.Done)
+		require.Equal(t, int64(1), status.Rewritten)
+
+		backend.mu.Lock()
+		value := backend.values["a"]
+		backend.mu.Unlock()
+		require.True(t, bytes.HasPrefix(value, encryptedValuePrefix))
+		encrypted, err := base64.StdEncoding.DecodeString(string(value[len(encryptedValuePrefix):]))
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(encrypted, []byte("2:")))
+
+		rec := httptest.NewRecorder()
+		NewReEncryptionHandler(server).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/storage/encryption", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		var rsp ReEncryptionStatus
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rsp))
+		require.Equal(t, ReEncryptionCompleted, rsp.State)
+	})
+
+	t.Run("the encrypter is required", func(t *testing.T) {
+		_, err := newResourceEncryption(EncryptionConfig{
+			Resources: []schema.GroupResource{{Group: "playlist.grafana.app", Resource: "playlists"}},
+		}, backend, server.log)
+		require.Error(t, err)
+	})
+}
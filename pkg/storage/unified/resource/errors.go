@@ -60,6 +60,14 @@ func NewTooManyRequestsError(msg string) *resourcepb.ErrorResult {
 	}
 }
 
+func NewQuotaExceededError(msg string) *resourcepb.ErrorResult {
+	return &resourcepb.ErrorResult{
+		Message: msg,
+		Code:    http.StatusForbidden,
+		Reason:  string(metav1.StatusReasonForbidden),
+	}
+}
+
 func newInvalidFieldError(
 	obj utils.GrafanaMetaAccessor,
 	detail string,
@@ -0,0 +1,290 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+// NamespaceQuota limits the storage used by a namespace and the rate of its requests. Zero values are unlimited.
+type NamespaceQuota struct {
+	// Maximum number of resources
+	MaxObjects int64
+
+	// Maximum size of the resources in bytes, the blobs are not included
+	MaxBytes int64
+
+	// Maximum rate of the requests, and number of requests which can exceed it at once
+	RequestsPerSecond float64
+	Burst             int
+}
+
+func (q NamespaceQuota) limitsStorage() bool {
+	return q.MaxObjects > 0 || q.MaxBytes > 0
+}
+
+type QuotaConfig struct {
+	// The quota of the namespaces without their own quota
+	Default NamespaceQuota
+
+	// The quota by namespace
+	Namespaces map[string]NamespaceQuota
+
+	// How long the usage read from storage is used before reading it again, so the writes made through
+	// other instances are counted
+	UsageRefreshInterval time.Duration
+}
+
+func (c QuotaConfig) quota(namespace string) NamespaceQuota {
+	if q, ok := c.Namespaces[namespace]; ok {
+		return q
+	}
+	return c.Default
+}
+
+// NamespaceUsage is the storage used by a namespace and its quota
+type NamespaceUsage struct {
+	Namespace string `json:"namespace"`
+	Objects   int64  `json:"objects"`
+	Bytes     int64  `json:"bytes"`
+
+	MaxObjects        int64   `json:"maxObjects,omitempty"`
+	MaxBytes          int64   `json:"maxBytes,omitempty"`
+	RequestsPerSecond float64 `json:"requestsPerSecond,omitempty"`
+
+	// Number of requests rejected by the rate limit since this instance started
+	ThrottledRequests int64 `json:"throttledRequests"`
+}
+
+// NamespaceUsageReporter reports the storage used by the namespaces
+type NamespaceUsageReporter interface {
+	// GetNamespaceUsage reads the usage from storage, for all namespaces when namespace is empty
+	GetNamespaceUsage(ctx context.Context, namespace string) ([]NamespaceUsage, error)
+}
+
+var _ NamespaceUsageReporter = (*server)(nil)
+
+// GetNamespaceUsage implements NamespaceUsageReporter.
+func (s *server) GetNamespaceUsage(ctx context.Context, namespace string) ([]NamespaceUsage, error) {
+	if err := s.Init(ctx); err != nil {
+		return nil, err
+	}
+
+	namespaces := []string{namespace}
+	if namespace == "" {
+		stats, err := s.backend.GetResourceStats(ctx, "", 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get resource stats: %w", err)
+		}
+		seen := map[string]bool{}
+		namespaces = namespaces[:0]
+		for _, stat := range stats {
+			if !seen[stat.Namespace] {
+				seen[stat.Namespace] = true
+				namespaces = append(namespaces, stat.Namespace)
+			}
+		}
+		sort.Strings(namespaces)
+	}
+
+	results := make([]NamespaceUsage, 0, len(namespaces))
+	for _, ns := range namespaces {
+		usage, err := s.quotas.refresh(ctx, ns)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, usage)
+	}
+	return results, nil
+}
+
+// NewNamespaceUsageHandler returns the HTTP handler reporting the storage used by the namespaces and their quotas.
+// The namespace query parameter limits the report to a single namespace.
+func NewNamespaceUsageHandler(reporter NamespaceUsageReporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		results, err := reporter.GetNamespaceUsage(r.Context(), r.URL.Query().Get("namespace"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"namespaces": results,
+		})
+	})
+}
+
+// namespaceQuotas enforces the quotas of the namespaces on this instance. The usage is read from storage and
+// updated with the writes of this instance until it's read again, so the writes made at the same time through
+// several instances can exceed the quota slightly.
+type namespaceQuotas struct {
+	cfg     QuotaConfig
+	backend StorageBackend
+	now     func() time.Time
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+	usage    map[string]*namespaceUsage
+}
+
+type namespaceUsage struct {
+	objects   int64
+	bytes     int64
+	throttled int64
+	read      time.Time
+}
+
+func newNamespaceQuotas(cfg QuotaConfig, backend StorageBackend) *namespaceQuotas {
+	if cfg.UsageRefreshInterval <= 0 {
+		cfg.UsageRefreshInterval = time.Minute
+	}
+	return &namespaceQuotas{
+		cfg:      cfg,
+		backend:  backend,
+		now:      time.Now,
+		limiters: map[string]*rate.Limiter{},
+		usage:    map[string]*namespaceUsage{},
+	}
+}
+
+// allow checks the request rate limit of the namespace
+func (q *namespaceQuotas) allow(namespace string) *resourcepb.ErrorResult {
+	if q == nil {
+		return nil
+	}
+	quota := q.cfg.quota(namespace)
+	if quota.RequestsPerSecond <= 0 {
+		return nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	limiter, ok := q.limiters[namespace]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(quota.RequestsPerSecond), max(quota.Burst, 1))
+		q.limiters[namespace] = limiter
+	}
+	if limiter.Allow() {
+		return nil
+	}
+	q.usageOf(namespace).throttled++
+	return NewTooManyRequestsError(fmt.Sprintf("too many requests in namespace %s, please try again later", namespace))
+}
+
+// checkWrite checks that the namespace stays within its storage quota after adding the objects and bytes
+func (q *namespaceQuotas) checkWrite(ctx context.Context, namespace string, objects, bytes int64) *resourcepb.ErrorResult {
+	if q == nil {
+		return nil
+	}
+	quota := q.cfg.quota(namespace)
+	if !quota.limitsStorage() || (objects <= 0 && bytes <= 0) {
+		return nil
+	}
+
+	q.mu.Lock()
+	usage := q.usageOf(namespace)
+	stale := q.now().Sub(usage.read) > q.cfg.UsageRefreshInterval
+	q.mu.Unlock()
+	if stale {
+		if _, err := q.refresh(ctx, namespace); err != nil {
+			return AsErrorResult(err)
+		}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if quota.MaxObjects > 0 && objects > 0 && usage.objects+objects > quota.MaxObjects {
+		return NewQuotaExceededError(fmt.Sprintf("namespace %s exceeded its quota of %d resources", namespace, quota.MaxObjects))
+	}
+	if quota.MaxBytes > 0 && bytes > 0 && usage.bytes+bytes > quota.MaxBytes {
+		return NewQuotaExceededError(fmt.Sprintf("namespace %s exceeded its quota of %d bytes", namespace, quota.MaxBytes))
+	}
+	return nil
+}
+
+// record adds a successful write to the usage of the namespace
+func (q *namespaceQuotas) record(namespace string, objects, bytes int64) {
+	if q == nil || !q.cfg.quota(namespace).limitsStorage() {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	usage := q.usageOf(namespace)
+	usage.objects += objects
+	usage.bytes += bytes
+}
+
+// refresh reads the usage of the namespace from storage
+func (q *namespaceQuotas) refresh(ctx context.Context, namespace string) (NamespaceUsage, error) {
+	if q == nil {
+		return NamespaceUsage{}, fmt.Errorf("namespace quotas not configured")
+	}
+	read := q.now()
+	stats, err := q.backend.GetResourceStats(ctx, namespace, 0)
+	if err != nil {
+		return NamespaceUsage{}, fmt.Errorf("failed to get resource stats: %w", err)
+	}
+
+	var objects, bytes int64
+	for _, stat := range stats {
+		objects += stat.Count
+		_, err := q.backend.ListIterator(ctx, &resourcepb.ListRequest{
+			Limit: 1000000000000, // big number
+			Options: &resourcepb.ListOptions{
+				Key: &resourcepb.ResourceKey{
+					Namespace: stat.Namespace,
+					Group:     stat.Group,
+					Resource:  stat.Resource,
+				},
+			},
+		}, func(iter ListIterator) error {
+			for iter.Next() {
+				if err := iter.Error(); err != nil {
+					return err
+				}
+				bytes += int64(len(iter.Value()))
+			}
+			return iter.Error()
+		})
+		if err != nil {
+			return NamespaceUsage{}, fmt.Errorf("failed to list %s: %w", stat.String(), err)
+		}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	usage := q.usageOf(namespace)
+	usage.objects = objects
+	usage.bytes = bytes
+	usage.read = read
+
+	quota := q.cfg.quota(namespace)
+	return NamespaceUsage{
+		Namespace:         namespace,
+		Objects:           objects,
+		Bytes:             bytes,
+		MaxObjects:        quota.MaxObjects,
+		MaxBytes:          quota.MaxBytes,
+		RequestsPerSecond: quota.RequestsPerSecond,
+		ThrottledRequests: usage.throttled,
+	}, nil
+}
+
+// usageOf returns the usage of the namespace, the lock must be held
+func (q *namespaceQuotas) usageOf(namespace string) *namespaceUsage {
+	usage, ok := q.usage[namespace]
+	if !ok {
+		usage = &namespaceUsage{}
+		q.usage[namespace] = usage
+	}
+	return usage
+}
@@ -0,0 +1,108 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	authlib "github.com/grafana/authlib/types"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+func TestNamespaceQuotas(t *testing.T) {
+	ctx := authlib.WithAuthInfo(context.Background(), &identity.StaticRequester{
+		Type:           authlib.TypeUser,
+		Login:          "testuser",
+		UserID:         123,
+		UserUID:        "u123",
+		OrgRole:        identity.RoleAdmin,
+		IsGrafanaAdmin: true, // can do anything
+	})
+
+	server, err := NewResourceServer(ResourceServerOptions{
+		Backend: setupTestStorageBackend(t),
+		Quotas: QuotaConfig{
+			Default: NamespaceQuota{MaxObjects: 2},
+			Namespaces: map[string]NamespaceQuota{
+				"stacks-1": {RequestsPerSecond: 0.001, Burst: 2},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	key := func(namespace, name string) *resourcepb.ResourceKey {
+		return &resourcepb.ResourceKey{Namespace: namespace, Group: "playlist.grafana.app", Resource: "playlists", Name: name}
+	}
+	create := func(t *testing.T, key *resourcepb.ResourceKey) *resourcepb.ErrorResult {
+		rsp, err := server.Create(ctx, &resourcepb.CreateRequest{
+			Key: key,
+			Value: []byte(fmt.Sprintf(`{
+				"apiVersion": "playlist.grafana.app/v0alpha1",
+				"kind": "Playlist",
+				"metadata": {"name": %q, "uid": %q, "namespace": %q},
+				"spec": {"title": "hello"}
+			}`, key.Name, key.Name, key.Namespace)),
+		})
+		require.NoError(t, err)
+		return rsp.Error
+	}
+
+	t.Run("the number of resources is limited", func(t *testing.T) {
+		require.Nil(t, create(t, key("default", "a")))
+		require.Nil(t, create(t, key("default", "b")))
+
+		e := create(t, key("default", "c"))
+		require.NotNil(t, e)
+		require.Equal(t, int32(http.StatusForbidden), e.Code)
+
+		// the deleted resources don't count
+		rsp, err := server.Delete(ctx, &resourcepb.DeleteRequest{Key: key("default", "a")})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		require.Nil(t, create(t, key("default", "c")))
+	})
+
+	t.Run("the request rate is limited", func(t *testing.T) {
+		require.Nil(t, create(t, key("stacks-1", "a")))
+		rsp, err := server.Read(ctx, &resourcepb.ReadRequest{Key: key("stacks-1", "a")})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+
+		rsp, err = server.Read(ctx, &resourcepb.ReadRequest{Key: key("stacks-1", "a")})
+		require.NoError(t, err)
+		require.NotNil(t, rsp.Error)
+		require.Equal(t, int32(http.StatusTooManyRequests), rsp.Error.Code)
+
+		// the other namespaces have their own limit
+		rsp, err = server.Read(ctx, &resourcepb.ReadRequest{Key: key("default", "b")})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+	})
+
+	t.Run("the usage is reported by namespace", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewNamespaceUsageHandler(server).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/storage/usage", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var rsp struct {
+			Namespaces []NamespaceUsage `json:"namespaces"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rsp))
+		require.Len(t, rsp.Namespaces, 2)
+
+		require.Equal(t, "default", rsp.Namespaces[0].Namespace)
+		require.Equal(t, int64(2), rsp.Namespaces[0].Objects)
+		require.Equal(t, int64(2), rsp.Namespaces[0].MaxObjects)
+		require.Positive(t, rsp.Namespaces[0].Bytes)
+
+		require.Equal(t, "stacks-1", rsp.Namespaces[1].Namespace)
+		require.Equal(t, int64(1), rsp.Namespaces[1].Objects)
+		require.Equal(t, int64(1), rsp.Namespaces[1].ThrottledRequests)
+	})
+}
@@ -58,7 +58,9 @@ type BulkIndexItem struct {
 }
 
 type BulkIndexRequest struct {
-	Items           []*BulkIndexItem
+	Items []*BulkIndexItem
+	// The resource version of the last change in the request, persisted with the index when greater than
+	// the current one. Zero when the items don't reflect the latest changes (eg: building the index).
 	ResourceVersion int64
 }
 
@@ -79,6 +81,9 @@ type ResourceIndex interface {
 
 	// Get the number of documents in the index
 	DocCount(ctx context.Context, folder string) (int64, error)
+
+	// ResourceVersion returns the resource version of the last change applied to the index
+	ResourceVersion() int64
 }
 
 // SearchBackend contains the technology specific logic to support search
@@ -90,12 +95,17 @@ type SearchBackend interface {
 	// Depending on the size, the backend may choose different options (eg: memory vs disk).
 	// The last known resource version can be used to detect that nothing has changed, and existing on-disk index can be reused.
 	// The builder will write all documents before returning.
-	BuildIndex(ctx context.Context, key NamespacedResource, size int64, resourceVersion int64, nonStandardFields SearchableDocumentFields, indexBuildReason string, builder func(index ResourceIndex) (int64, error)) (ResourceIndex, error)
+	// When set, the updater is used instead of the builder to apply the changes made since sinceRV to an outdated
+	// index that has been persisted. It returns the resource version of the last applied change.
+	BuildIndex(ctx context.Context, key NamespacedResource, size int64, resourceVersion int64, nonStandardFields SearchableDocumentFields, indexBuildReason string, updater IndexUpdater, builder func(index ResourceIndex) (int64, error)) (ResourceIndex, error)
 
 	// TotalDocs returns the total number of documents across all indexes.
 	TotalDocs() int64
 }
 
+// IndexUpdater applies the changes made since sinceRV to the index, and returns the resource version of the last change
+type IndexUpdater func(index ResourceIndex, sinceRV int64) (int64, error)
+
 const tracingPrexfixSearch = "unified_search."
 
 // This supports indexing+search regardless of implementation
@@ -581,6 +591,7 @@ func (s *searchSupport) monitorIndexEvents(ctx context.Context) {
 		}
 		if s.indexMetrics != nil {
 			s.indexMetrics.IndexLatency.WithLabelValues(evt.WrittenEvent.Key.Resource).Observe(evt.Latency.Seconds())
+			s.indexMetrics.IndexLag.WithLabelValues(evt.WrittenEvent.Key.Resource).Set(evt.Latency.Seconds())
 		}
 		if s.clientIndexEventsChan != nil {
 			s.clientIndexEventsChan <- evt
@@ -718,7 +729,11 @@ func (s *searchSupport) build(ctx context.Context, nsr NamespacedResource, size
 	}
 	fields := s.builders.GetFields(nsr)
 
-	index, err := s.search.BuildIndex(ctx, nsr, size, rv, fields, indexBuildReason, func(index ResourceIndex) (int64, error) {
+	updater := func(index ResourceIndex, sinceRV int64) (int64, error) {
+		return s.updateIndex(ctx, nsr, builder, index, sinceRV)
+	}
+
+	index, err := s.search.BuildIndex(ctx, nsr, size, rv, fields, indexBuildReason, updater, func(index ResourceIndex) (int64, error) {
 		span := trace.SpanFromContext(ctx)
 		span.AddEvent("building index", trace.WithAttributes(attribute.Int64("size", size), attribute.Int64("rv", rv), attribute.String("reason", indexBuildReason)))
 
@@ -810,6 +825,67 @@ func (s *searchSupport) build(ctx context.Context, nsr NamespacedResource, size
 	return index, rv, err
 }
 
+// updateIndex applies the changes made since sinceRV to an outdated index, so it does not need to be rebuilt from scratch.
+// Only the latest change of each resource is returned by the storage, so deleted resources are removed from the index.
+func (s *searchSupport) updateIndex(ctx context.Context, nsr NamespacedResource, builder DocumentBuilder, index ResourceIndex, sinceRV int64) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, tracingPrexfixSearch+"UpdateIndex")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("namespace", nsr.Namespace),
+		attribute.String("group", nsr.Group),
+		attribute.String("resource", nsr.Resource),
+		attribute.Int64("since_rv", sinceRV),
+	)
+
+	latestRV, changes := s.storage.ListModifiedSince(ctx, nsr, sinceRV)
+
+	count := 0
+	items := make([]*BulkIndexItem, 0, maxBatchSize)
+	for res, err := range changes {
+		if err != nil {
+			return 0, err
+		}
+
+		if res.Action == resourcepb.WatchEvent_DELETED {
+			items = append(items, &BulkIndexItem{
+				Action: ActionDelete,
+				Key:    &res.Key,
+			})
+		} else {
+			doc, err := builder.BuildDocument(ctx, &res.Key, res.ResourceVersion, res.Value)
+			if err != nil {
+				span.RecordError(err)
+				s.log.Error("error building search document", "key", SearchID(&res.Key), "err", err)
+				continue
+			}
+			items = append(items, &BulkIndexItem{
+				Action: ActionIndex,
+				Doc:    doc,
+			})
+		}
+
+		if len(items) >= maxBatchSize {
+			if err = index.BulkIndex(&BulkIndexRequest{Items: items}); err != nil {
+				return 0, err
+			}
+			count += len(items)
+			items = items[:0]
+		}
+	}
+
+	if len(items) > 0 {
+		if err := index.BulkIndex(&BulkIndexRequest{Items: items}); err != nil {
+			return 0, err
+		}
+		count += len(items)
+	}
+
+	span.AddEvent("index updated", trace.WithAttributes(attribute.Int("count", count), attribute.Int64("rv", latestRV)))
+	s.log.Debug("updated index", "namespace", nsr.Namespace, "group", nsr.Group, "resource", nsr.Resource, "since_rv", sinceRV, "rv", latestRV, "changes", count)
+	return latestRV, nil
+}
+
 // buildEmptyIndex creates an empty index without adding any documents
 func (s *searchSupport) buildEmptyIndex(ctx context.Context, nsr NamespacedResource, rv int64) (ResourceIndex, error) {
 	ctx, span := s.tracer.Start(ctx, tracingPrexfixSearch+"BuildEmptyIndex")
@@ -819,7 +895,7 @@ func (s *searchSupport) buildEmptyIndex(ctx context.Context, nsr NamespacedResou
 	s.log.Debug("Building empty index", "namespace", nsr.Namespace, "group", nsr.Group, "resource", nsr.Resource, "rv", rv)
 
 	// Build an empty index by passing a builder function that doesn't add any documents
-	return s.search.BuildIndex(ctx, nsr, 0, rv, fields, "empty", func(index ResourceIndex) (int64, error) {
+	return s.search.BuildIndex(ctx, nsr, 0, rv, fields, "empty", nil, func(index ResourceIndex) (int64, error) {
 		// Return the resource version without adding any documents to the index
 		return 0, nil
 	})
@@ -0,0 +1,125 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// IndexConsistency compares a search index with the resources in storage
+type IndexConsistency struct {
+	Namespace string `json:"namespace"`
+	Group     string `json:"group"`
+	Resource  string `json:"resource"`
+
+	// Number of resources in storage and documents in the index
+	StorageCount int64 `json:"storageCount"`
+	IndexCount   int64 `json:"indexCount"`
+
+	// Latest resource version in storage, and resource version of the last change applied to the index
+	StorageResourceVersion int64 `json:"storageResourceVersion"`
+	IndexResourceVersion   int64 `json:"indexResourceVersion"`
+
+	// Time between the latest write in storage and the last change applied to the index
+	LagSeconds float64 `json:"lagSeconds"`
+
+	Consistent bool `json:"consistent"`
+}
+
+// IndexConsistencyChecker compares the search indexes with the resources in storage
+type IndexConsistencyChecker interface {
+	// CheckIndexConsistency checks the indexes open on this instance, for all namespaces when namespace is empty
+	CheckIndexConsistency(ctx context.Context, namespace string) ([]IndexConsistency, error)
+}
+
+var _ IndexConsistencyChecker = (*server)(nil)
+
+// CheckIndexConsistency implements IndexConsistencyChecker.
+func (s *server) CheckIndexConsistency(ctx context.Context, namespace string) ([]IndexConsistency, error) {
+	if err := s.Init(ctx); err != nil {
+		return nil, err
+	}
+
+	if s.search == nil {
+		return nil, fmt.Errorf("search index not configured")
+	}
+	return s.search.checkIndexConsistency(ctx, namespace)
+}
+
+// checkIndexConsistency compares the indexes open on this instance with the storage stats.
+// Indexes which have not been built yet (eg: owned by another instance) are skipped.
+func (s *searchSupport) checkIndexConsistency(ctx context.Context, namespace string) ([]IndexConsistency, error) {
+	ctx, span := s.tracer.Start(ctx, tracingPrexfixSearch+"CheckIndexConsistency")
+	defer span.End()
+
+	stats, err := s.storage.GetResourceStats(ctx, namespace, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource stats: %w", err)
+	}
+
+	results := make([]IndexConsistency, 0, len(stats))
+	for _, stat := range stats {
+		idx, err := s.search.GetIndex(ctx, stat.NamespacedResource)
+		if err != nil {
+			return nil, err
+		}
+		if idx == nil {
+			continue
+		}
+
+		count, err := idx.DocCount(ctx, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get doc count for %s: %w", stat.String(), err)
+		}
+
+		// resource versions are timestamps in microseconds
+		rv := idx.ResourceVersion()
+		lag := time.Duration(max(stat.ResourceVersion-rv, 0)) * time.Microsecond
+		if s.indexMetrics != nil {
+			s.indexMetrics.IndexLag.WithLabelValues(stat.Resource).Set(lag.Seconds())
+		}
+
+		result := IndexConsistency{
+			Namespace:              stat.Namespace,
+			Group:                  stat.Group,
+			Resource:               stat.Resource,
+			StorageCount:           stat.Count,
+			IndexCount:             count,
+			StorageResourceVersion: stat.ResourceVersion,
+			IndexResourceVersion:   rv,
+			LagSeconds:             lag.Seconds(),
+			Consistent:             count == stat.Count && rv >= stat.ResourceVersion,
+		}
+		if !result.Consistent {
+			s.log.Warn("search index is not consistent with storage", "namespace", stat.Namespace, "group", stat.Group, "resource", stat.Resource,
+				"storage_count", stat.Count, "index_count", count, "storage_rv", stat.ResourceVersion, "index_rv", rv)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// NewIndexConsistencyHandler returns the HTTP handler reporting the consistency of the search indexes with the storage.
+// The namespace query parameter limits the check to a single namespace.
+func NewIndexConsistencyHandler(checker IndexConsistencyChecker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		results, err := checker.CheckIndexConsistency(r.Context(), r.URL.Query().Get("namespace"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		consistent := true
+		for _, result := range results {
+			consistent = consistent && result.Consistent
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"consistent": consistent,
+			"indexes":    results,
+		})
+	})
+}
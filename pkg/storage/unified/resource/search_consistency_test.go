@@ -0,0 +1,126 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// indexesSearchBackend returns the indexes of the map
+type indexesSearchBackend struct {
+	mockSearchBackend
+	indexes map[NamespacedResource]ResourceIndex
+}
+
+func (m *indexesSearchBackend) GetIndex(ctx context.Context, key NamespacedResource) (ResourceIndex, error) {
+	return m.indexes[key], nil
+}
+
+func newConsistencyTestIndex(count int64, rv int64) *MockResourceIndex {
+	index := &MockResourceIndex{}
+	index.On("DocCount", mock.Anything, "").Return(count, nil)
+	index.On("ResourceVersion").Return(rv)
+	return index
+}
+
+func TestCheckIndexConsistency(t *testing.T) {
+	consistent := NamespacedResource{Namespace: "ns", Group: "group", Resource: "consistent"}
+	behind := NamespacedResource{Namespace: "ns", Group: "group", Resource: "behind"}
+	missing := NamespacedResource{Namespace: "ns", Group: "group", Resource: "missing"}
+
+	storage := &mockStorageBackend{
+		resourceStats: []ResourceStats{
+			{NamespacedResource: consistent, Count: 10, ResourceVersion: 1000000},
+			{NamespacedResource: behind, Count: 5, ResourceVersion: 3000000},
+			{NamespacedResource: missing, Count: 1, ResourceVersion: 1000000},
+		},
+	}
+	search := &indexesSearchBackend{
+		indexes: map[NamespacedResource]ResourceIndex{
+			consistent: newConsistencyTestIndex(10, 1000000),
+			behind:     newConsistencyTestIndex(4, 1000000),
+		},
+	}
+
+	support, err := newSearchSupport(SearchOptions{
+		Backend:   search,
+		Resources: &TestDocumentBuilderSupplier{GroupsResources: map[string]string{"group": "consistent"}},
+	}, storage, nil, nil, noop.NewTracerProvider().Tracer("test"), nil, nil, nil, false)
+	require.NoError(t, err)
+
+	results, err := support.checkIndexConsistency(context.Background(), "ns")
+	require.NoError(t, err)
+	require.Equal(t, []IndexConsistency{
+		{
+			Namespace:              "ns",
+			Group:                  "group",
+			Resource:               "consistent",
+			StorageCount:           10,
+			IndexCount:             10,
+			StorageResourceVersion: 1000000,
+			IndexResourceVersion:   1000000,
+			Consistent:             true,
+		},
+		{
+			Namespace:              "ns",
+			Group:                  "group",
+			Resource:               "behind",
+			StorageCount:           5,
+			IndexCount:             4,
+			StorageResourceVersion: 3000000,
+			IndexResourceVersion:   1000000,
+			LagSeconds:             2,
+			Consistent:             false,
+		},
+	}, results)
+}
+
+type staticConsistencyChecker struct {
+	results []IndexConsistency
+}
+
+func (c *staticConsistencyChecker) CheckIndexConsistency(ctx context.Context, namespace string) ([]IndexConsistency, error) {
+	var results []IndexConsistency
+	for _, result := range c.results {
+		if namespace == "" || result.Namespace == namespace {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+func TestIndexConsistencyHandler(t *testing.T) {
+	handler := NewIndexConsistencyHandler(&staticConsistencyChecker{
+		results: []IndexConsistency{
+			{Namespace: "ns1", Group: "group", Resource: "resource", Consistent: true},
+			{Namespace: "ns2", Group: "group", Resource: "resource", Consistent: false},
+		},
+	})
+
+	for _, tc := range []struct {
+		namespace  string
+		consistent bool
+		indexes    int
+	}{
+		{namespace: "", consistent: false, indexes: 2},
+		{namespace: "ns1", consistent: true, indexes: 1},
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search/consistency?namespace="+tc.namespace, nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var rsp struct {
+			Consistent bool               `json:"consistent"`
+			Indexes    []IndexConsistency `json:"indexes"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rsp))
+		require.Equal(t, tc.consistent, rsp.Consistent)
+		require.Len(t, rsp.Indexes, tc.indexes)
+	}
+}
@@ -135,6 +135,7 @@ func (b *indexQueueProcessor) process(batch []*WrittenEvent) {
 			}
 		}
 		req.Items = append(req.Items, item)
+		req.ResourceVersion = max(req.ResourceVersion, evt.ResourceVersion)
 	}
 
 	b.indexMu.Lock()
@@ -90,7 +90,8 @@ func TestIndexQueueProcessor_BatchProcessing(t *testing.T) {
 	mockIndex.On("BulkIndex", mock.MatchedBy(func(req *BulkIndexRequest) bool {
 		return len(req.Items) == 2 &&
 			req.Items[0].Action == ActionIndex &&
-			req.Items[1].Action == ActionDelete
+			req.Items[1].Action == ActionDelete &&
+			req.ResourceVersion == max(events[0].ResourceVersion, events[1].ResourceVersion)
 	})).Return(nil)
 
 	// Start processor and add events
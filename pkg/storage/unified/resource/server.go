@@ -168,6 +168,14 @@ type BlobConfig struct {
 
 	// Directly implemented blob support
 	Backend BlobSupport
+
+	// GCInterval is the interval between two deletions of the orphaned blobs, zero disables the garbage
+	// collection. Only the blob stores implementing BlobCollector are collected.
+	GCInterval time.Duration
+
+	// GCMinAge is the minimum age of the deleted orphaned blobs, so the blobs written just before their
+	// resource aren't deleted
+	GCMinAge time.Duration
 }
 
 // Passed as input to the constructor
@@ -242,6 +250,12 @@ type ResourceServerOptions struct {
 	// QOSQueue is the quality of service queue used to enqueue
 	QOSQueue QOSEnqueuer
 
+	// Quotas limit the storage and the request rate of the namespaces
+	Quotas QuotaConfig
+
+	// Encryption of the values of the configured resources
+	Encryption EncryptionConfig
+
 	Ring           *ring.Ring
 	RingLifecycler *ring.BasicLifecycler
 
@@ -306,6 +320,15 @@ func NewResourceServer(opts ResourceServerOptions) (*server, error) {
 
 	logger := slog.Default().With("logger", "resource-server")
 
+	// Encrypt the configured resources, the server only sees their decrypted values
+	encryption, err := newResourceEncryption(opts.Encryption, opts.Backend, logger)
+	if err != nil {
+		return nil, err
+	}
+	if encryption != nil {
+		opts.Backend = &encryptedBackend{StorageBackend: opts.Backend, encryption: encryption}
+	}
+
 	// Make this cancelable
 	ctx, cancel := context.WithCancel(context.Background())
 	s := &server{
@@ -326,17 +349,20 @@ func NewResourceServer(opts ResourceServerOptions) (*server, error) {
 		maxPageSizeBytes: opts.MaxPageSizeBytes,
 		reg:              opts.Reg,
 		queue:            opts.QOSQueue,
+		blobGCInterval:   opts.Blob.GCInterval,
+		blobGCMinAge:     opts.Blob.GCMinAge,
+		quotas:           newNamespaceQuotas(opts.Quotas, opts.Backend),
+		encryption:       encryption,
 	}
 
 	if opts.Search.Resources != nil {
-		var err error
 		s.search, err = newSearchSupport(opts.Search, s.backend, s.access, s.blob, opts.Tracer, opts.IndexMetrics, opts.Ring, opts.RingLifecycler, opts.SearchAfterWrite)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	err := s.Init(ctx)
+	err = s.Init(ctx)
 	if err != nil {
 		s.log.Error("resource server init failed", "error", err)
 		return nil, err
@@ -375,6 +401,13 @@ type server struct {
 	maxPageSizeBytes int
 	reg              prometheus.Registerer
 	queue            QOSEnqueuer
+
+	blobGCInterval time.Duration
+	blobGCMinAge   time.Duration
+
+	quotas *namespaceQuotas
+
+	encryption *resourceEncryption
 }
 
 // Init implements ResourceServer.
@@ -398,6 +431,16 @@ func (s *server) Init(ctx context.Context) error {
 			s.initErr = s.initWatcher()
 		}
 
+		// Start deleting the orphaned blobs
+		if collector, ok := s.blob.(BlobCollector); ok && s.initErr == nil && s.blobGCInterval > 0 {
+			go s.runBlobGC(collector)
+		}
+
+		// Start re-encrypting the stored values
+		if s.encryption != nil && s.initErr == nil && s.encryption.interval > 0 {
+			go s.runReEncryption()
+		}
+
 		if s.initErr != nil {
 			s.log.Error("error running resource server init", "error", s.initErr)
 		}
@@ -630,6 +673,10 @@ func (s *server) Create(ctx context.Context, req *resourcepb.CreateRequest) (*re
 		}
 		return rsp, nil
 	}
+	if e := s.quotas.allow(req.Key.Namespace); e != nil {
+		rsp.Error = e
+		return rsp, nil
+	}
 
 	var (
 		res *resourcepb.CreateResponse
@@ -655,6 +702,10 @@ func (s *server) create(ctx context.Context, user claims.AuthInfo, req *resource
 		rsp.Error = e
 		return rsp, nil
 	}
+	if e := s.quotas.checkWrite(ctx, req.Key.Namespace, 1, int64(len(req.Value))); e != nil {
+		rsp.Error = e
+		return rsp, nil
+	}
 
 	// If the resource already exists, the create will return an already exists error that is remapped appropriately by AsErrorResult.
 	// This also benefits from ACID behaviours on our databases, so we avoid race conditions.
@@ -662,6 +713,8 @@ func (s *server) create(ctx context.Context, user claims.AuthInfo, req *resource
 	rsp.ResourceVersion, err = s.backend.WriteEvent(ctx, *event)
 	if err != nil {
 		rsp.Error = AsErrorResult(err)
+	} else {
+		s.quotas.record(req.Key.Namespace, 1, int64(len(req.Value)))
 	}
 	s.log.Debug("server.WriteEvent", "type", event.Type, "rv", rsp.ResourceVersion, "previousRV", event.PreviousRV, "group", event.Key.Group, "namespace", event.Key.Namespace, "name", event.Key.Name, "resource", event.Key.Resource)
 	return rsp, nil
@@ -684,6 +737,10 @@ func (s *server) Update(ctx context.Context, req *resourcepb.UpdateRequest) (*re
 		rsp.Error = AsErrorResult(apierrors.NewBadRequest("update must include the previous version"))
 		return rsp, nil
 	}
+	if e := s.quotas.allow(req.Key.Namespace); e != nil {
+		rsp.Error = e
+		return rsp, nil
+	}
 
 	var (
 		res *resourcepb.UpdateResponse
@@ -727,10 +784,18 @@ func (s *server) update(ctx context.Context, user claims.AuthInfo, req *resource
 	event.Type = resourcepb.WatchEvent_MODIFIED
 	event.PreviousRV = latest.ResourceVersion
 
+	growth := int64(len(req.Value) - len(latest.Value))
+	if e := s.quotas.checkWrite(ctx, req.Key.Namespace, 0, growth); e != nil {
+		rsp.Error = e
+		return rsp, nil
+	}
+
 	var err error
 	rsp.ResourceVersion, err = s.backend.WriteEvent(ctx, *event)
 	if err != nil {
 		rsp.Error = AsErrorResult(err)
+	} else {
+		s.quotas.record(req.Key.Namespace, 0, growth)
 	}
 	return rsp, nil
 }
@@ -751,6 +816,10 @@ func (s *server) Delete(ctx context.Context, req *resourcepb.DeleteRequest) (*re
 		}
 		return rsp, nil
 	}
+	if e := s.quotas.allow(req.Key.Namespace); e != nil {
+		rsp.Error = e
+		return rsp, nil
+	}
 
 	var (
 		res *resourcepb.DeleteResponse
@@ -842,6 +911,8 @@ func (s *server) delete(ctx context.Context, user claims.AuthInfo, req *resource
 	rsp.ResourceVersion, err = s.backend.WriteEvent(ctx, event)
 	if err != nil {
 		rsp.Error = AsErrorResult(err)
+	} else {
+		s.quotas.record(req.Key.Namespace, -1, -int64(len(latest.Value)))
 	}
 	return rsp, nil
 }
@@ -863,6 +934,9 @@ func (s *server) Read(ctx context.Context, req *resourcepb.ReadRequest) (*resour
 	if req.Key.Resource == "" {
 		return &resourcepb.ReadResponse{Error: NewBadRequestError("missing resource")}, nil
 	}
+	if e := s.quotas.allow(req.Key.Namespace); e != nil {
+		return &resourcepb.ReadResponse{Error: e}, nil
+	}
 
 	var (
 		res *resourcepb.ReadResponse
@@ -947,6 +1021,9 @@ func (s *server) List(ctx context.Context, req *resourcepb.ListRequest) (*resour
 	rsp := &resourcepb.ListResponse{}
 
 	key := req.Options.Key
+	if e := s.quotas.allow(key.Namespace); e != nil {
+		return &resourcepb.ListResponse{Error: e}, nil
+	}
 	checker, err := s.access.Compile(ctx, user, claims.ListRequest{
 		Group:     key.Group,
 		Resource:  key.Resource,
@@ -1197,7 +1274,19 @@ func (s *server) Watch(req *resourcepb.WatchRequest, srv resourcepb.ResourceStor
 	case req.Since == 0:
 		since = mostRecentRV
 	default:
-		since = req.Since
+		// Resume the watch: send the changes made since the given resource version before the live events.
+		// Only the latest change of each resource is replayed.
+		rv, modified, err := s.listModifiedSince(ctx, key, req.Since)
+		if err != nil {
+			// Only the live events are sent when the backend can't list the modified resources.
+			s.log.Warn("watch: failed to list the changes since the resource version", "since", req.Since, "err", err)
+			since = req.Since
+			break
+		}
+		if err := replayModified(modified, checker, srv); err != nil {
+			return err
+		}
+		since = rv
 	}
 	for {
 		select {
@@ -1277,7 +1366,11 @@ func (s *server) GetStats(ctx context.Context, req *resourcepb.ResourceStatsRequ
 
 	if s.search == nil {
 		// If the backend implements "GetStats", we can use it
-		srv, ok := s.backend.(resourcepb.ResourceIndexServer)
+		backend := s.backend
+		if encrypted, ok := backend.(*encryptedBackend); ok {
+			backend = encrypted.StorageBackend
+		}
+		srv, ok := backend.(resourcepb.ResourceIndexServer)
 		if ok {
 			return srv.GetStats(ctx, req)
 		}
@@ -1371,6 +1464,15 @@ func (s *server) GetBlob(ctx context.Context, req *resourcepb.GetBlobRequest) (*
 	rsp, err := s.blob.GetResourceBlob(ctx, req.Resource, info, req.MustProxyBytes)
 	if err != nil {
 		rsp.Error = AsErrorResult(err)
+		return rsp, nil
+	}
+	// The hash stored in the resource detects the blobs changed or corrupted in the store
+	if info.Hash != "" && rsp.Value != nil && blobHash(rsp.Value) != info.Hash {
+		rsp.Value = nil
+		rsp.Error = &resourcepb.ErrorResult{
+			Message: "blob hash mismatch",
+			Code:    http.StatusInternalServerError,
+		}
 	}
 	return rsp, nil
 }
@@ -0,0 +1,101 @@
+package resource
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"slices"
+
+	claims "github.com/grafana/authlib/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+// listModifiedSince returns the latest change of each resource matching the key made after the since resource
+// version, in the order they happened, and the resource version the changes were listed at.
+func (s *server) listModifiedSince(ctx context.Context, key *resourcepb.ResourceKey, since int64) (int64, []*ModifiedResource, error) {
+	namespaces := []string{key.Namespace}
+	if key.Namespace == "" {
+		// the modified resources are listed by namespace
+		stats, err := s.backend.GetResourceStats(ctx, "", 0)
+		if err != nil {
+			return 0, nil, err
+		}
+		namespaces = namespaces[:0]
+		for _, stat := range stats {
+			if stat.Group == key.Group && stat.Resource == key.Resource && !slices.Contains(namespaces, stat.Namespace) {
+				namespaces = append(namespaces, stat.Namespace)
+			}
+		}
+	}
+
+	latestRV := since
+	var modified []*ModifiedResource
+	for _, ns := range namespaces {
+		rv, seq := s.backend.ListModifiedSince(ctx, NamespacedResource{
+			Namespace: ns,
+			Group:     key.Group,
+			Resource:  key.Resource,
+		}, since)
+		for mr, err := range seq {
+			if err != nil {
+				return 0, nil, err
+			}
+			if matchesQueryKey(key, &mr.Key) {
+				modified = append(modified, mr)
+			}
+		}
+		latestRV = max(latestRV, rv)
+	}
+
+	slices.SortFunc(modified, func(a, b *ModifiedResource) int {
+		return cmp.Compare(a.ResourceVersion, b.ResourceVersion)
+	})
+	return latestRV, modified, nil
+}
+
+// replayModified sends the modified resources the user can read to the watch, so a client resuming a watch
+// doesn't miss the changes made while it wasn't watching.
+func replayModified(modified []*ModifiedResource, checker claims.ItemChecker, srv resourcepb.ResourceStore_WatchServer) error {
+	for _, mr := range modified {
+		// the access to a deleted resource is checked on the folder it was deleted from
+		if !checker(mr.Key.Name, folderOf(mr.Value)) {
+			continue
+		}
+		event := &resourcepb.WatchEvent{
+			Type: mr.Action,
+			Resource: &resourcepb.WatchEvent_Resource{
+				Value:   mr.Value,
+				Version: mr.ResourceVersion,
+			},
+		}
+		// the delete marker holds the last state of deleted objects, it's sent as the previous object
+		// like the live events send the object read at the previous resource version
+		if mr.Action == resourcepb.WatchEvent_DELETED {
+			event.Resource.Value = []byte{}
+			event.Previous = &resourcepb.WatchEvent_Resource{
+				Value:   mr.Value,
+				Version: mr.ResourceVersion,
+			}
+		}
+		if err := srv.Send(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// folderOf returns the folder of the resource stored in value, or an empty string when it can't be decoded
+func folderOf(value []byte) string {
+	partial := &metav1.PartialObjectMetadata{}
+	if err := json.Unmarshal(value, partial); err != nil {
+		return ""
+	}
+	obj, err := utils.MetaAccessor(partial)
+	if err != nil {
+		return ""
+	}
+	return obj.GetFolder()
+}
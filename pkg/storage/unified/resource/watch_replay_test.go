@@ -0,0 +1,120 @@
+package resource
+
+import (
+	"context"
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+// modifiedSinceBackend lists the modified resources of each namespace
+type modifiedSinceBackend struct {
+	StorageBackend
+	modified map[string][]*ModifiedResource
+	rv       int64
+}
+
+func (b *modifiedSinceBackend) GetResourceStats(ctx context.Context, namespace string, minCount int) ([]ResourceStats, error) {
+	var stats []ResourceStats
+	for ns := range b.modified {
+		stats = append(stats,
+			ResourceStats{NamespacedResource: NamespacedResource{Namespace: ns, Group: "playlist.grafana.app", Resource: "playlists"}},
+			ResourceStats{NamespacedResource: NamespacedResource{Namespace: ns, Group: "folder.grafana.app", Resource: "folders"}},
+		)
+	}
+	return stats, nil
+}
+
+func (b *modifiedSinceBackend) ListModifiedSince(ctx context.Context, key NamespacedResource, sinceRv int64) (int64, iter.Seq2[*ModifiedResource, error]) {
+	return b.rv, func(yield func(*ModifiedResource, error) bool) {
+		for _, mr := range b.modified[key.Namespace] {
+			if mr.ResourceVersion > sinceRv && !yield(mr, nil) {
+				return
+			}
+		}
+	}
+}
+
+type watchServerRecorder struct {
+	resourcepb.ResourceStore_WatchServer
+	events []*resourcepb.WatchEvent
+}
+
+func (r *watchServerRecorder) Send(event *resourcepb.WatchEvent) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestWatchReplay(t *testing.T) {
+	modified := func(ns, name string, action resourcepb.WatchEvent_Type, rv int64, folder string) *ModifiedResource {
+		return &ModifiedResource{
+			Action:          action,
+			Key:             resourcepb.ResourceKey{Namespace: ns, Group: "playlist.grafana.app", Resource: "playlists", Name: name},
+			Value:           []byte(`{"metadata":{"name":"` + name + `","annotations":{"grafana.app/folder":"` + folder + `"}}}`),
+			ResourceVersion: rv,
+		}
+	}
+	s := &server{backend: &modifiedSinceBackend{
+		rv: 500,
+		modified: map[string][]*ModifiedResource{
+			"default": {
+				modified("default", "a", resourcepb.WatchEvent_MODIFIED, 300, ""),
+				modified("default", "b", resourcepb.WatchEvent_DELETED, 100, "private"),
+			},
+			"stacks-1": {
+				modified("stacks-1", "c", resourcepb.WatchEvent_ADDED, 200, ""),
+			},
+		},
+	}}
+	key := &resourcepb.ResourceKey{Group: "playlist.grafana.app", Resource: "playlists"}
+
+	t.Run("the changes of every namespace are listed in order", func(t *testing.T) {
+		rv, changes, err := s.listModifiedSince(context.Background(), key, 50)
+		require.NoError(t, err)
+		require.Equal(t, int64(500), rv)
+		require.Len(t, changes, 3)
+		require.Equal(t, "b", changes[0].Key.Name)
+		require.Equal(t, "c", changes[1].Key.Name)
+		require.Equal(t, "a", changes[2].Key.Name)
+	})
+
+	t.Run("the changes are filtered by namespace and resource version", func(t *testing.T) {
+		_, changes, err := s.listModifiedSince(context.Background(), &resourcepb.ResourceKey{
+			Namespace: "default",
+			Group:     key.Group,
+			Resource:  key.Resource,
+		}, 150)
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		require.Equal(t, "a", changes[0].Key.Name)
+	})
+
+	t.Run("only the changes the user can read are replayed", func(t *testing.T) {
+		_, changes, err := s.listModifiedSince(context.Background(), key, 50)
+		require.NoError(t, err)
+
+		srv := &watchServerRecorder{}
+		err = replayModified(changes, func(name, folder string) bool {
+			return folder != "private"
+		}, srv)
+		require.NoError(t, err)
+		require.Len(t, srv.events, 2)
+		require.Equal(t, resourcepb.WatchEvent_ADDED, srv.events[0].Type)
+		require.Equal(t, int64(200), srv.events[0].Resource.Version)
+		require.Equal(t, resourcepb.WatchEvent_MODIFIED, srv.events[1].Type)
+		require.Equal(t, int64(300), srv.events[1].Resource.Version)
+	})
+
+	t.Run("the delete marker is sent as the previous object", func(t *testing.T) {
+		srv := &watchServerRecorder{}
+		err := replayModified([]*ModifiedResource{modified("default", "b", resourcepb.WatchEvent_DELETED, 100, "")},
+			func(name, folder string) bool { return true }, srv)
+		require.NoError(t, err)
+		require.Len(t, srv.events, 1)
+		require.Empty(t, srv.events[0].Resource.Value)
+		require.Contains(t, string(srv.events[0].Previous.Value), `"name":"b"`)
+	})
+}
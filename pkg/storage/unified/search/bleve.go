@@ -195,6 +195,8 @@ func (b *bleveBackend) updateIndexSizeMetric(indexPath string) {
 // If built successfully, the new index replaces the old index in the cache (if there was any).
 // An index in the file system is considered to be valid if the requested resourceVersion is smaller than or equal to
 // the resourceVersion used to build the index and the number of indexed objects matches the expected size.
+// Otherwise, when "updater" is set, the newest outdated index in the file system is updated with the changes made
+// since its resourceVersion. A new index is built if there is no such index, or if the update fails.
 // The return value of "builder" should be the RV returned from List. This will be stored as the index RV
 //
 //nolint:gocyclo
@@ -205,6 +207,7 @@ func (b *bleveBackend) BuildIndex(
 	resourceVersion int64,
 	fields resource.SearchableDocumentFields,
 	indexBuildReason string,
+	updater resource.IndexUpdater,
 	builder func(index resource.ResourceIndex) (int64, error),
 ) (resource.ResourceIndex, error) {
 	_, span := b.tracer.Start(ctx, tracingPrexfixBleve+"BuildIndex")
@@ -260,6 +263,7 @@ func (b *bleveBackend) BuildIndex(
 	fileIndexName := "" // Name of the file-based index, or empty for in-memory indexes.
 	newIndexType := indexStorageMemory
 	build := true
+	update := false
 
 	if size > b.opts.FileThreshold {
 		newIndexType = indexStorageFile
@@ -269,11 +273,15 @@ func (b *bleveBackend) BuildIndex(
 		// If we do have an unexpired cached index already, we always build a new index from scratch.
 		if cachedIndex == nil && resourceVersion > 0 {
 			index, fileIndexName, indexRV = b.findPreviousFileBasedIndex(resourceDir, resourceVersion, size)
+			if index == nil && updater != nil {
+				index, fileIndexName, indexRV = b.findOutdatedFileBasedIndex(resourceDir, resourceVersion)
+				update = index != nil
+			}
 		}
 
 		if index != nil {
 			build = false
-			logWithDetails.Debug("Existing index found on filesystem", "indexRV", indexRV, "directory", filepath.Join(resourceDir, fileIndexName))
+			logWithDetails.Debug("Existing index found on filesystem", "indexRV", indexRV, "update", update, "directory", filepath.Join(resourceDir, fileIndexName))
 			defer closeIndexOnExit(index, "") // Close index, but don't delete directory.
 		} else {
 			// Building index from scratch. Index name has a time component in it to be unique, but if
@@ -348,6 +356,30 @@ func (b *bleveBackend) BuildIndex(
 		if b.indexMetrics != nil {
 			b.indexMetrics.IndexCreationTime.WithLabelValues().Observe(elapsed.Seconds())
 		}
+	} else if update {
+		logWithDetails.Info("Updating existing index", "indexRV", indexRV)
+
+		start := time.Now()
+		idx.resourceVersion = indexRV
+		rv, err := updater(idx, indexRV)
+		if err == nil {
+			err = idx.updateResourceVersion(rv)
+		}
+		if err != nil {
+			// The outdated index is closed before building a new one, which will remove its directory.
+			logWithDetails.Warn("Failed to update existing index, building a new one", "indexRV", indexRV, "err", err)
+			closeIndex = false
+			if closeErr := index.Close(); closeErr != nil {
+				logWithDetails.Error("Failed to close index after index update failure", "err", closeErr)
+			}
+			return b.BuildIndex(ctx, key, size, resourceVersion, fields, indexBuildReason, nil, builder)
+		}
+
+		logWithDetails.Info("Finished updating index", "elapsed", time.Since(start), "rv", idx.resourceVersion)
+
+		if b.indexMetrics != nil {
+			b.indexMetrics.IndexUpdates.Inc()
+		}
 	} else {
 		logWithDetails.Info("Skipping index build, using existing index")
 
@@ -550,6 +582,54 @@ func (b *bleveBackend) findPreviousFileBasedIndex(resourceDir string, resourceVe
 	return nil, "", 0
 }
 
+// findOutdatedFileBasedIndex returns the index in the file system with the highest resourceVersion below the
+// requested one, which can be updated with the changes made since. The other indexes are left closed.
+func (b *bleveBackend) findOutdatedFileBasedIndex(resourceDir string, resourceVersion int64) (bleve.Index, string, int64) {
+	entries, err := os.ReadDir(resourceDir)
+	if err != nil {
+		return nil, "", 0
+	}
+
+	var found bleve.Index
+	var foundName string
+	var foundRV int64
+	for _, ent := range entries {
+		if !ent.IsDir() {
+			continue
+		}
+
+		indexName := ent.Name()
+		indexDir := filepath.Join(resourceDir, indexName)
+		idx, err := bleve.Open(indexDir)
+		if err != nil {
+			b.log.Debug("error opening index", "indexDir", indexDir, "err", err)
+			continue
+		}
+
+		indexRV, err := getRV(idx)
+		if err != nil {
+			b.log.Error("error getting rv from index", "indexDir", indexDir, "err", err)
+			if !errors.Is(err, bleve.ErrorIndexClosed) {
+				_ = idx.Close()
+			}
+			continue
+		}
+
+		// An index without resourceVersion has not been fully built.
+		if indexRV <= 0 || indexRV >= resourceVersion || indexRV <= foundRV {
+			_ = idx.Close()
+			continue
+		}
+
+		if found != nil {
+			_ = found.Close()
+		}
+		found, foundName, foundRV = idx, indexName, indexRV
+	}
+
+	return found, foundName, foundRV
+}
+
 func (b *bleveBackend) CloseAllIndexes() {
 	b.cacheMx.Lock()
 	defer b.cacheMx.Unlock()
@@ -568,6 +648,7 @@ type bleveIndex struct {
 	key   resource.NamespacedResource
 	index bleve.Index
 
+	rvMx            sync.RWMutex
 	resourceVersion int64
 
 	standard resource.SearchableDocumentFields
@@ -609,7 +690,22 @@ func (b *bleveIndex) BulkIndex(req *resource.BulkIndexRequest) error {
 		}
 	}
 
-	return b.index.Batch(batch)
+	if err := b.index.Batch(batch); err != nil {
+		return err
+	}
+
+	// Persist the RV of the latest change, so the index can be reused or updated after a restart.
+	if req.ResourceVersion > b.ResourceVersion() {
+		return b.updateResourceVersion(req.ResourceVersion)
+	}
+	return nil
+}
+
+// ResourceVersion implements resource.ResourceIndex.
+func (b *bleveIndex) ResourceVersion() int64 {
+	b.rvMx.RLock()
+	defer b.rvMx.RUnlock()
+	return b.resourceVersion
 }
 
 var internalRVKey = []byte("rv")
@@ -619,6 +715,9 @@ func (b *bleveIndex) updateResourceVersion(rv int64) error {
 		return nil
 	}
 
+	b.rvMx.Lock()
+	defer b.rvMx.Unlock()
+
 	if err := setRV(b.index, rv); err != nil {
 		return err
 	}
@@ -986,30 +1085,42 @@ func (b *bleveIndex) toBleveSearchRequest(ctx context.Context, req *resourcepb.R
 		}
 	}
 
-	if len(req.Query) > 1 && strings.Contains(req.Query, "*") {
+	// field-scoped terms like panel:"error rate" are matched on their own fields
+	text, scoped := parseQueryString(req.Query)
+	for _, t := range scoped {
+		queries = append(queries, t.toQuery())
+	}
+
+	if len(text) > 1 && strings.Contains(text, "*") {
 		// wildcard query is expensive - should be used with caution
-		wildcard := bleve.NewWildcardQuery(req.Query)
+		wildcard := bleve.NewWildcardQuery(text)
 		queries = append(queries, wildcard)
 	}
 
-	if req.Query != "" && !strings.Contains(req.Query, "*") {
-		// Add a text query
-		searchrequest.Fields = append(searchrequest.Fields, resource.SEARCH_FIELD_SCORE)
+	if len(scoped) > 0 || (text != "" && !strings.Contains(text, "*")) {
+		searchrequest.Fields = append(searchrequest.Fields, resource.SEARCH_FIELD_SCORE, resource.SEARCH_FIELD_HIGHLIGHT)
+		searchrequest.Highlight = bleve.NewHighlight()
+		for _, f := range highlightFields() {
+			searchrequest.Highlight.AddField(f)
+		}
+	}
 
+	if text != "" && !strings.Contains(text, "*") {
+		// Add a text query
 		// There are multiple ways to match the query string to documents. The following queries are ordered by priority:
 
 		// Query 1: Match the exact query string
-		queryExact := bleve.NewMatchQuery(req.Query)
+		queryExact := bleve.NewMatchQuery(text)
 		queryExact.SetBoost(10.0)
 		queryExact.Analyzer = keyword.Name // don't analyze the query input - treat it as a single token
 
 		// Query 2: Phrase query with standard analyzer
-		queryPhrase := bleve.NewMatchPhraseQuery(req.Query)
+		queryPhrase := bleve.NewMatchPhraseQuery(text)
 		queryExact.SetBoost(5.0)
 		queryPhrase.Analyzer = standard.Name
 
 		// Query 3: Match query with standard analyzer
-		queryAnalyzed := bleve.NewMatchQuery(req.Query)
+		queryAnalyzed := bleve.NewMatchQuery(text)
 		queryAnalyzed.Analyzer = standard.Name
 
 		// At least one of the queries must match
@@ -1324,6 +1435,10 @@ func (b *bleveIndex) hitsToTable(ctx context.Context, selectFields []string, hit
 				if match.Expl != nil {
 					row.Cells[i], err = json.Marshal(match.Expl)
 				}
+			case resource.SEARCH_FIELD_HIGHLIGHT:
+				if len(match.Fragments) > 0 {
+					row.Cells[i], err = json.Marshal(highlightFragments(match.Fragments))
+				}
 			case resource.SEARCH_FIELD_LEGACY_ID:
 				v := match.Fields[resource.SEARCH_FIELD_LABELS+"."+resource.SEARCH_FIELD_LEGACY_ID]
 				if v != nil {
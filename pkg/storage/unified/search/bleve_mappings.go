@@ -147,5 +147,21 @@ func getBleveDocMappings(_ resource.SearchableDocumentFields) *mapping.DocumentM
 	fieldMapper := bleve.NewDocumentMapping()
 	mapper.AddSubDocumentMapping("fields", fieldMapper)
 
+	// full-text fields of the dashboard panels, with term vectors for highlighting
+	for _, name := range []string{DASHBOARD_PANEL_TITLE, DASHBOARD_PANEL_DESCRIPTION} {
+		panelTextMapping := bleve.NewTextFieldMapping()
+		panelTextMapping.Analyzer = standard.Name
+		fieldMapper.AddFieldMappingsAt(name, panelTextMapping)
+	}
+
+	// query text is searched by words, and by substring in the raw mapping
+	queryTextMapping := bleve.NewTextFieldMapping()
+	queryTextMapping.Analyzer = standard.Name
+	queryRawMapping := bleve.NewTextFieldMapping()
+	queryRawMapping.Name = DASHBOARD_QUERY_TEXT_RAW
+	queryRawMapping.Analyzer = LOWERCASE_KEYWORD_ANALYZER
+	queryRawMapping.IncludeInAll = false // stored for highlighting the substring matches
+	fieldMapper.AddFieldMappingsAt(DASHBOARD_QUERY_TEXT, queryTextMapping, queryRawMapping)
+
 	return mapper
 }
@@ -0,0 +1,118 @@
+package search
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/standard"
+	"github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/query"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+// Prefixes scoping a term of the query string to some fields, e.g. panel:"error rate" or query:"rate(http_"
+const (
+	QUERY_SCOPE_TITLE = "title"
+	QUERY_SCOPE_PANEL = "panel"
+	QUERY_SCOPE_QUERY = "query"
+)
+
+// scopedTerm is a term of the query string limited to the fields of a scope
+type scopedTerm struct {
+	scope string
+	value string
+}
+
+// parseQueryString splits the field-scoped terms out of the query string.
+// Values are either a single word, or a phrase in double quotes. Unknown prefixes are
+// kept in the free text, so searching for "a:b" still works.
+func parseQueryString(input string) (string, []scopedTerm) {
+	var text []string
+	var terms []scopedTerm
+
+	for q := strings.TrimSpace(input); q != ""; q = strings.TrimSpace(q) {
+		scope, rest, found := strings.Cut(q, ":")
+		if !found || !isQueryScope(scope) || rest == "" || rest[0] == ' ' {
+			word, next, _ := strings.Cut(q, " ")
+			text = append(text, word)
+			q = next
+			continue
+		}
+
+		var value string
+		if rest[0] == '"' {
+			// the phrase runs until the closing quote, or the end of the query
+			value, q, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, q, _ = strings.Cut(rest, " ")
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			terms = append(terms, scopedTerm{scope: scope, value: value})
+		}
+	}
+
+	if len(terms) == 0 {
+		return input, nil // keep the query as typed for the exact match
+	}
+	return strings.Join(text, " "), terms
+}
+
+func isQueryScope(scope string) bool {
+	switch scope {
+	case QUERY_SCOPE_TITLE, QUERY_SCOPE_PANEL, QUERY_SCOPE_QUERY:
+		return true
+	}
+	return false
+}
+
+// toQuery converts the term to a bleve query on the fields of its scope
+func (t scopedTerm) toQuery() query.Query {
+	switch t.scope {
+	case QUERY_SCOPE_PANEL:
+		return bleve.NewDisjunctionQuery(
+			newPhraseQuery(t.value, resource.SEARCH_FIELD_PREFIX+DASHBOARD_PANEL_TITLE),
+			newPhraseQuery(t.value, resource.SEARCH_FIELD_PREFIX+DASHBOARD_PANEL_DESCRIPTION),
+		)
+	case QUERY_SCOPE_QUERY:
+		// queries are matched by substring, as word boundaries are meaningless in most query languages
+		q := bleve.NewRegexpQuery(".*" + regexp.QuoteMeta(strings.ToLower(t.value)) + ".*")
+		q.SetField(resource.SEARCH_FIELD_PREFIX + DASHBOARD_QUERY_TEXT_RAW)
+		return q
+	default:
+		return newPhraseQuery(t.value, resource.SEARCH_FIELD_TITLE)
+	}
+}
+
+func newPhraseQuery(value string, field string) query.Query {
+	q := bleve.NewMatchPhraseQuery(value)
+	q.SetField(field)
+	q.Analyzer = standard.Name
+	return q
+}
+
+// highlightFields are the text fields returning the matched fragments in the search results
+func highlightFields() []string {
+	return []string{
+		resource.SEARCH_FIELD_TITLE,
+		resource.SEARCH_FIELD_PREFIX + DASHBOARD_PANEL_TITLE,
+		resource.SEARCH_FIELD_PREFIX + DASHBOARD_PANEL_DESCRIPTION,
+		resource.SEARCH_FIELD_PREFIX + DASHBOARD_QUERY_TEXT,
+		resource.SEARCH_FIELD_PREFIX + DASHBOARD_QUERY_TEXT_RAW,
+	}
+}
+
+// highlightFragments returns the fragments by response field name, the substring
+// matches of the raw query text being reported as query text.
+func highlightFragments(fragments search.FieldFragmentMap) map[string][]string {
+	out := make(map[string][]string, len(fragments))
+	for field, f := range fragments {
+		name := strings.TrimPrefix(field, resource.SEARCH_FIELD_PREFIX)
+		if name == DASHBOARD_QUERY_TEXT_RAW {
+			name = DASHBOARD_QUERY_TEXT
+		}
+		out[name] = append(out[name], f...)
+	}
+	return out
+}
@@ -2,6 +2,7 @@ package search_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"testing"
@@ -491,6 +492,110 @@ func TestCanSearchByTitle(t *testing.T) {
 	})
 }
 
+func TestCanSearchPanelsAndQueries(t *testing.T) {
+	key := &resourcepb.ResourceKey{
+		Namespace: "default",
+		Group:     "dashboard.grafana.app",
+		Resource:  "dashboards",
+	}
+
+	index := newTestDashboardsIndex(t, threshold, 2, 2, noop)
+	err := index.BulkIndex(&resource.BulkIndexRequest{
+		Items: []*resource.BulkIndexItem{
+			{
+				Action: resource.ActionIndex,
+				Doc: &resource.IndexableDocument{
+					RV:   1,
+					Name: "name1",
+					Key: &resourcepb.ResourceKey{
+						Name:      "name1",
+						Namespace: key.Namespace,
+						Group:     key.Group,
+						Resource:  key.Resource,
+					},
+					Title: "Service overview",
+					Fields: map[string]any{
+						search.DASHBOARD_PANEL_TITLE:       []string{"Error rate", "Requests"},
+						search.DASHBOARD_PANEL_DESCRIPTION: []string{"Rate of failed requests"},
+						search.DASHBOARD_QUERY_TEXT:        []string{`sum(rate(http_requests_total{status=~"5.."}[5m]))`},
+					},
+				},
+			},
+			{
+				Action: resource.ActionIndex,
+				Doc: &resource.IndexableDocument{
+					RV:   1,
+					Name: "name2",
+					Key: &resourcepb.ResourceKey{
+						Name:      "name2",
+						Namespace: key.Namespace,
+						Group:     key.Group,
+						Resource:  key.Resource,
+					},
+					Title: "Error budget",
+					Fields: map[string]any{
+						search.DASHBOARD_PANEL_TITLE: []string{"Latency"},
+						search.DASHBOARD_QUERY_TEXT:  []string{"histogram_quantile(0.9, rate(http_request_duration_seconds_bucket[5m]))"},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	highlights := func(t *testing.T, res *resourcepb.ResourceSearchResponse, row int) map[string][]string {
+		t.Helper()
+		for i, col := range res.Results.Columns {
+			if col.Name == resource.SEARCH_FIELD_HIGHLIGHT {
+				out := map[string][]string{}
+				require.NoError(t, json.Unmarshal(res.Results.Rows[row].Cells[i], &out))
+				return out
+			}
+		}
+		require.Fail(t, "missing highlight column")
+		return nil
+	}
+
+	t.Run("free text matches panel titles", func(t *testing.T) {
+		res, err := index.Search(context.Background(), nil, newTestQuery("error"), nil)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), res.TotalHits)
+	})
+
+	t.Run("panel scope matches panel titles and descriptions only", func(t *testing.T) {
+		res, err := index.Search(context.Background(), nil, newTestQuery(`panel:"error rate"`), nil)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), res.TotalHits)
+		require.Equal(t, "name1", res.Results.Rows[0].Key.Name)
+		require.Contains(t, highlights(t, res, 0)[search.DASHBOARD_PANEL_TITLE], "<mark>Error</mark> <mark>rate</mark>")
+
+		res, err = index.Search(context.Background(), nil, newTestQuery(`panel:"failed requests"`), nil)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), res.TotalHits)
+
+		res, err = index.Search(context.Background(), nil, newTestQuery(`panel:budget`), nil)
+		require.NoError(t, err)
+		require.Equal(t, int64(0), res.TotalHits)
+	})
+
+	t.Run("query scope matches substrings of the query text", func(t *testing.T) {
+		res, err := index.Search(context.Background(), nil, newTestQuery(`query:"RATE(http_requests"`), nil)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), res.TotalHits)
+		require.Equal(t, "name1", res.Results.Rows[0].Key.Name)
+		require.NotEmpty(t, highlights(t, res, 0)[search.DASHBOARD_QUERY_TEXT])
+
+		res, err = index.Search(context.Background(), nil, newTestQuery(`query:"rate(http_" title:budget`), nil)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), res.TotalHits)
+		require.Equal(t, "name2", res.Results.Rows[0].Key.Name)
+
+		res, err = index.Search(context.Background(), nil, newTestQuery(`query:"[10m]"`), nil)
+		require.NoError(t, err)
+		require.Equal(t, int64(0), res.TotalHits)
+	})
+}
+
 func newTestQuery(query string) *resourcepb.ResourceSearchRequest {
 	return &resourcepb.ResourceSearchRequest{
 		Options: &resourcepb.ListOptions{
@@ -551,7 +656,7 @@ func newTestDashboardsIndex(t testing.TB, threshold int64, size int64, batchSize
 		Namespace: key.Namespace,
 		Group:     key.Group,
 		Resource:  key.Resource,
-	}, size, rv, info.Fields, "test", writer)
+	}, size, rv, info.Fields, "test", nil, writer)
 	require.NoError(t, err)
 
 	return index
@@ -73,7 +73,7 @@ func TestBleveBackend(t *testing.T) {
 			Namespace: key.Namespace,
 			Group:     key.Group,
 			Resource:  key.Resource,
-		}, 2, rv, info.Fields, "test", func(index resource.ResourceIndex) (int64, error) {
+		}, 2, rv, info.Fields, "test", nil, func(index resource.ResourceIndex) (int64, error) {
 			err := index.BulkIndex(&resource.BulkIndexRequest{
 				Items: []*resource.BulkIndexItem{
 					{
@@ -354,7 +354,7 @@ func TestBleveBackend(t *testing.T) {
 			Namespace: key.Namespace,
 			Group:     key.Group,
 			Resource:  key.Resource,
-		}, 2, rv, fields, "test", func(index resource.ResourceIndex) (int64, error) {
+		}, 2, rv, fields, "test", nil, func(index resource.ResourceIndex) (int64, error) {
 			err := index.BulkIndex(&resource.BulkIndexRequest{
 				Items: []*resource.BulkIndexItem{
 					{
@@ -608,6 +608,43 @@ func TestGetSortFields(t *testing.T) {
 	})
 }
 
+func TestParseQueryString(t *testing.T) {
+	tests := []struct {
+		query  string
+		text   string
+		scoped []scopedTerm
+	}{
+		{query: "", text: ""},
+		{query: " cpu  usage ", text: " cpu  usage "},
+		{query: "cpu  panel:usage ", text: "cpu", scoped: []scopedTerm{{scope: QUERY_SCOPE_PANEL, value: "usage"}}},
+		{
+			query:  `panel:"error rate" api`,
+			text:   "api",
+			scoped: []scopedTerm{{scope: QUERY_SCOPE_PANEL, value: "error rate"}},
+		},
+		{
+			query: `title:overview query:"rate(http_"`,
+			scoped: []scopedTerm{
+				{scope: QUERY_SCOPE_TITLE, value: "overview"},
+				{scope: QUERY_SCOPE_QUERY, value: "rate(http_"},
+			},
+		},
+		{
+			query:  `query:"sum by (job)`, // unterminated phrase runs until the end
+			scoped: []scopedTerm{{scope: QUERY_SCOPE_QUERY, value: "sum by (job)"}},
+		},
+		{query: "team:ops panel: x", text: "team:ops panel: x"}, // unknown scope and missing value
+		{query: `panel:""`, text: `panel:""`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			text, scoped := parseQueryString(tt.query)
+			require.Equal(t, tt.text, text)
+			require.Equal(t, tt.scoped, scoped)
+		})
+	}
+}
+
 var _ authlib.AccessClient = (*StubAccessClient)(nil)
 
 func NewStubAccessClient(permissions map[string]bool) *StubAccessClient {
@@ -768,7 +805,7 @@ func TestBleveInMemoryIndexExpiration(t *testing.T) {
 		Resource:  "resource",
 	}
 
-	builtIndex, err := backend.BuildIndex(context.Background(), ns, 1 /* below FileThreshold */, 100, nil, "test", indexTestDocs(ns, 1, 100))
+	builtIndex, err := backend.BuildIndex(context.Background(), ns, 1 /* below FileThreshold */, 100, nil, "test", nil, indexTestDocs(ns, 1, 100))
 	require.NoError(t, err)
 
 	// Wait for index expiration, which is 1ns
@@ -800,7 +837,7 @@ func TestBleveFileIndexExpiration(t *testing.T) {
 	}
 
 	// size=100 is above FileThreshold, this will be file-based index
-	builtIndex, err := backend.BuildIndex(context.Background(), ns, 100, 100, nil, "test", indexTestDocs(ns, 1, 100))
+	builtIndex, err := backend.BuildIndex(context.Background(), ns, 100, 100, nil, "test", nil, indexTestDocs(ns, 1, 100))
 	require.NoError(t, err)
 
 	// Wait for index expiration, which is 1ns
@@ -832,7 +869,7 @@ func TestFileIndexIsReusedOnSameSizeAndRVLessThanIndexRV(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	backend1, reg1 := setupBleveBackend(t, 5, time.Nanosecond, tmpDir)
-	_, err := backend1.BuildIndex(context.Background(), ns, 10 /* file based */, 100, nil, "test", indexTestDocs(ns, 10, 100))
+	_, err := backend1.BuildIndex(context.Background(), ns, 10 /* file based */, 100, nil, "test", nil, indexTestDocs(ns, 10, 100))
 	require.NoError(t, err)
 
 	// Verify one open index.
@@ -855,7 +892,7 @@ func TestFileIndexIsReusedOnSameSizeAndRVLessThanIndexRV(t *testing.T) {
 
 	// We open new backend using same directory, and run indexing with same size (10) and RV (100). This should reuse existing index, and skip indexing.
 	backend2, reg2 := setupBleveBackend(t, 5, time.Nanosecond, tmpDir)
-	idx, err := backend2.BuildIndex(context.Background(), ns, 10 /* file based */, 100, nil, "test", indexTestDocs(ns, 1000, 100))
+	idx, err := backend2.BuildIndex(context.Background(), ns, 10 /* file based */, 100, nil, "test", nil, indexTestDocs(ns, 1000, 100))
 	require.NoError(t, err)
 
 	// Verify that we're reusing existing index and there is only 10 documents in it, not 1000.
@@ -881,7 +918,7 @@ func TestFileIndexIsReusedOnSameSizeAndRVLessThanIndexRV(t *testing.T) {
 
 	// We repeat with backend3 and RV 99. This should also reuse existing index and skip indexing
 	backend3, reg3 := setupBleveBackend(t, 5, time.Nanosecond, tmpDir)
-	idx, err = backend3.BuildIndex(context.Background(), ns, 10 /* file based */, 99, nil, "test", indexTestDocs(ns, 1000, 99))
+	idx, err = backend3.BuildIndex(context.Background(), ns, 10 /* file based */, 99, nil, "test", nil, indexTestDocs(ns, 1000, 99))
 	require.NoError(t, err)
 
 	// Verify that we're reusing existing index and there is only 10 documents in it, not 1000.
@@ -909,13 +946,13 @@ func TestFileIndexIsNotReusedOnDifferentSize(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	backend1, _ := setupBleveBackend(t, 5, time.Nanosecond, tmpDir)
-	_, err := backend1.BuildIndex(context.Background(), ns, 10, 100, nil, "test", indexTestDocs(ns, 10, 100))
+	_, err := backend1.BuildIndex(context.Background(), ns, 10, 100, nil, "test", nil, indexTestDocs(ns, 10, 100))
 	require.NoError(t, err)
 	backend1.CloseAllIndexes()
 
 	// We open new backend using same directory, but with different size. Index should be rebuilt.
 	backend2, _ := setupBleveBackend(t, 5, time.Nanosecond, tmpDir)
-	idx, err := backend2.BuildIndex(context.Background(), ns, 100, 100, nil, "test", indexTestDocs(ns, 100, 100))
+	idx, err := backend2.BuildIndex(context.Background(), ns, 100, 100, nil, "test", nil, indexTestDocs(ns, 100, 100))
 	require.NoError(t, err)
 
 	// Verify that index has updated number of documents.
@@ -934,13 +971,13 @@ func TestFileIndexIsNotReusedOnDifferentRV(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	backend1, _ := setupBleveBackend(t, 5, time.Nanosecond, tmpDir)
-	_, err := backend1.BuildIndex(context.Background(), ns, 10, 100, nil, "test", indexTestDocs(ns, 10, 100))
+	_, err := backend1.BuildIndex(context.Background(), ns, 10, 100, nil, "test", nil, indexTestDocs(ns, 10, 100))
 	require.NoError(t, err)
 	backend1.CloseAllIndexes()
 
 	// We open new backend using same directory, but with different RV. Index should be rebuilt.
 	backend2, _ := setupBleveBackend(t, 5, time.Nanosecond, tmpDir)
-	idx, err := backend2.BuildIndex(context.Background(), ns, 10 /* file based */, 999999, nil, "test", indexTestDocs(ns, 100, 999999))
+	idx, err := backend2.BuildIndex(context.Background(), ns, 10 /* file based */, 999999, nil, "test", nil, indexTestDocs(ns, 100, 999999))
 	require.NoError(t, err)
 
 	// Verify that index has updated number of documents.
@@ -949,6 +986,106 @@ func TestFileIndexIsNotReusedOnDifferentRV(t *testing.T) {
 	require.Equal(t, int64(100), cnt)
 }
 
+func TestFileIndexIsUpdatedOnNewerRV(t *testing.T) {
+	ns := resource.NamespacedResource{
+		Namespace: "test",
+		Group:     "group",
+		Resource:  "resource",
+	}
+
+	tmpDir := t.TempDir()
+
+	backend1, _ := setupBleveBackend(t, 5, time.Nanosecond, tmpDir)
+	idx, err := backend1.BuildIndex(context.Background(), ns, 10, 100, nil, "test", nil, indexTestDocs(ns, 10, 100))
+	require.NoError(t, err)
+	require.Equal(t, int64(100), idx.ResourceVersion())
+
+	// Changes from events are persisted with their RV.
+	err = idx.BulkIndex(&resource.BulkIndexRequest{
+		Items:           []*resource.BulkIndexItem{testIndexItem(ns, "doc10")},
+		ResourceVersion: 150,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(150), idx.ResourceVersion())
+	backend1.CloseAllIndexes()
+
+	// We open new backend using same directory, with a newer RV. Index should be updated with the changes since RV 150.
+	backend2, reg2 := setupBleveBackend(t, 5, time.Nanosecond, tmpDir)
+	idx, err = backend2.BuildIndex(context.Background(), ns, 11, 200, nil, "test", func(index resource.ResourceIndex, sinceRV int64) (int64, error) {
+		require.Equal(t, int64(150), sinceRV)
+		err := index.BulkIndex(&resource.BulkIndexRequest{
+			Items: []*resource.BulkIndexItem{
+				testIndexItem(ns, "doc11"),
+				{
+					Action: resource.ActionDelete,
+					Key:    &resourcepb.ResourceKey{Namespace: ns.Namespace, Group: ns.Group, Resource: ns.Resource, Name: "doc0"},
+				},
+			},
+		})
+		return 200, err
+	}, func(index resource.ResourceIndex) (int64, error) {
+		return 0, fmt.Errorf("index should be updated, not rebuilt")
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(200), idx.ResourceVersion())
+
+	cnt, err := idx.DocCount(context.Background(), "")
+	require.NoError(t, err)
+	require.Equal(t, int64(11), cnt)
+
+	require.NoError(t, testutil.GatherAndCompare(reg2, bytes.NewBufferString(`
+		# HELP index_server_index_updates_total Number of times an outdated index found on disk has been updated with the changes since its resource version, instead of being rebuilt
+		# TYPE index_server_index_updates_total counter
+		index_server_index_updates_total 1
+	`), "index_server_index_updates_total"))
+}
+
+func TestFileIndexIsRebuiltWhenUpdateFails(t *testing.T) {
+	ns := resource.NamespacedResource{
+		Namespace: "test",
+		Group:     "group",
+		Resource:  "resource",
+	}
+
+	tmpDir := t.TempDir()
+
+	backend1, _ := setupBleveBackend(t, 5, time.Nanosecond, tmpDir)
+	_, err := backend1.BuildIndex(context.Background(), ns, 10, 100, nil, "test", nil, indexTestDocs(ns, 10, 100))
+	require.NoError(t, err)
+	backend1.CloseAllIndexes()
+
+	backend2, _ := setupBleveBackend(t, 5, time.Nanosecond, tmpDir)
+	idx, err := backend2.BuildIndex(context.Background(), ns, 20, 200, nil, "test", func(index resource.ResourceIndex, sinceRV int64) (int64, error) {
+		return 0, fmt.Errorf("not implemented")
+	}, indexTestDocs(ns, 20, 200))
+	require.NoError(t, err)
+	require.Equal(t, int64(200), idx.ResourceVersion())
+
+	cnt, err := idx.DocCount(context.Background(), "")
+	require.NoError(t, err)
+	require.Equal(t, int64(20), cnt)
+
+	// Only the rebuilt index is left.
+	entries, err := os.ReadDir(backend2.getResourceDir(ns))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func testIndexItem(ns resource.NamespacedResource, name string) *resource.BulkIndexItem {
+	return &resource.BulkIndexItem{
+		Action: resource.ActionIndex,
+		Doc: &resource.IndexableDocument{
+			Key: &resourcepb.ResourceKey{
+				Namespace: ns.Namespace,
+				Group:     ns.Group,
+				Resource:  ns.Resource,
+				Name:      name,
+			},
+			Title: name,
+		},
+	}
+}
+
 func TestRebuildingIndexClosesPreviousCachedIndex(t *testing.T) {
 	ns := resource.NamespacedResource{
 		Namespace: "test",
@@ -972,7 +1109,7 @@ func TestRebuildingIndexClosesPreviousCachedIndex(t *testing.T) {
 			if testCase.firstInMemory {
 				firstSize = 1
 			}
-			firstIndex, err := backend.BuildIndex(context.Background(), ns, int64(firstSize), 100, nil, "test", indexTestDocs(ns, firstSize, 100))
+			firstIndex, err := backend.BuildIndex(context.Background(), ns, int64(firstSize), 100, nil, "test", nil, indexTestDocs(ns, firstSize, 100))
 			require.NoError(t, err)
 
 			if testCase.firstInMemory {
@@ -988,7 +1125,7 @@ func TestRebuildingIndexClosesPreviousCachedIndex(t *testing.T) {
 				secondSize = 1
 				openInMemoryIndexes = 1
 			}
-			secondIndex, err := backend.BuildIndex(context.Background(), ns, int64(secondSize), 100, nil, "test", indexTestDocs(ns, secondSize, 100))
+			secondIndex, err := backend.BuildIndex(context.Background(), ns, int64(secondSize), 100, nil, "test", nil, indexTestDocs(ns, secondSize, 100))
 			require.NoError(t, err)
 
 			if testCase.secondInMemory {
@@ -1105,12 +1242,12 @@ func testBleveIndexWithFailures(t *testing.T, fileBased bool) {
 		// size=100 is above FileThreshold (5), make it a file-based index.
 		size = 100
 	}
-	_, err := backend.BuildIndex(context.Background(), ns, size, 100, nil, "test", func(index resource.ResourceIndex) (int64, error) {
+	_, err := backend.BuildIndex(context.Background(), ns, size, 100, nil, "test", nil, func(index resource.ResourceIndex) (int64, error) {
 		return 0, fmt.Errorf("fail")
 	})
 	require.Error(t, err)
 
 	// Even though previous build of the index failed, new building of the index should work.
-	_, err = backend.BuildIndex(context.Background(), ns, size, 100, nil, "test", indexTestDocs(ns, int(size), 100))
+	_, err = backend.BuildIndex(context.Background(), ns, size, 100, nil, "test", nil, indexTestDocs(ns, int(size), 100))
 	require.NoError(t, err)
 }
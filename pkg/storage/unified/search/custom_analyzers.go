@@ -5,14 +5,20 @@ import (
 	"github.com/blevesearch/bleve/v2/analysis/token/edgengram"
 	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
 	"github.com/blevesearch/bleve/v2/analysis/token/unique"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/single"
 	"github.com/blevesearch/bleve/v2/analysis/tokenizer/whitespace"
 	"github.com/blevesearch/bleve/v2/mapping"
 )
 
 const TITLE_ANALYZER = "title_analyzer"
+const LOWERCASE_KEYWORD_ANALYZER = "lowercase_keyword_analyzer"
 
 func RegisterCustomAnalyzers(mapper *mapping.IndexMappingImpl) error {
-	return registerTitleAnalyzer(mapper)
+	err := registerTitleAnalyzer(mapper)
+	if err != nil {
+		return err
+	}
+	return registerLowercaseKeywordAnalyzer(mapper)
 }
 
 // The registerTitleAnalyzer function defines a custom analyzer for the title field.
@@ -46,3 +52,14 @@ func registerTitleAnalyzer(mapper *mapping.IndexMappingImpl) error {
 
 	return nil
 }
+
+// The registerLowercaseKeywordAnalyzer function defines an analyzer keeping the whole
+// value as a single lowercased token, for case-insensitive substring (wildcard) search
+// in text where word boundaries are meaningless, like raw queries.
+func registerLowercaseKeywordAnalyzer(mapper *mapping.IndexMappingImpl) error {
+	return mapper.AddCustomAnalyzer(LOWERCASE_KEYWORD_ANALYZER, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     single.Name,
+		"token_filters": []string{lowercase.Name},
+	})
+}
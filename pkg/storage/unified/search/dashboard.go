@@ -25,6 +25,10 @@ const DASHBOARD_PANEL_TYPES = "panel_types"
 const DASHBOARD_DS_TYPES = "ds_types"
 const DASHBOARD_TRANSFORMATIONS = "transformation"
 const DASHBOARD_LIBRARY_PANEL_REFERENCE = "reference.LibraryPanel"
+const DASHBOARD_PANEL_TITLE = "panel_title"
+const DASHBOARD_PANEL_DESCRIPTION = "panel_description"
+const DASHBOARD_QUERY_TEXT = "query_text"
+const DASHBOARD_QUERY_TEXT_RAW = "query_text_raw" // lowercased and not tokenized, for substring search
 
 //------------------------------------------------------------
 // The following fields are added in enterprise
@@ -70,6 +74,27 @@ func DashboardBuilder(namespaced resource.NamespacedDocumentSupplier) (resource.
 				Filterable: true,
 			},
 		},
+		{
+			Name:        DASHBOARD_PANEL_TITLE,
+			Type:        resourcepb.ResourceTableColumnDefinition_STRING,
+			IsArray:     true,
+			Description: "Titles of the panels (including the panels of collapsed rows)",
+			Priority:    20, // full text only, not included in the default columns
+		},
+		{
+			Name:        DASHBOARD_PANEL_DESCRIPTION,
+			Type:        resourcepb.ResourceTableColumnDefinition_STRING,
+			IsArray:     true,
+			Description: "Descriptions of the panels",
+			Priority:    20, // full text only, not included in the default columns
+		},
+		{
+			Name:        DASHBOARD_QUERY_TEXT,
+			Type:        resourcepb.ResourceTableColumnDefinition_STRING,
+			IsArray:     true,
+			Description: "Raw query text of the panel targets",
+			Priority:    20, // full text only, not included in the default columns
+		},
 		{
 			Name:        DASHBOARD_ERRORS_TODAY,
 			Type:        resourcepb.ResourceTableColumnDefinition_INT64,
@@ -278,8 +303,20 @@ func (s *DashboardDocumentBuilder) BuildDocument(ctx context.Context, key *resou
 	panelTypes := []string{}
 	transformations := []string{}
 	dsTypes := []string{}
+	panelTitles := []string{}
+	panelDescriptions := []string{}
+	queryText := []string{}
 
 	for _, p := range summary.Panels {
+		for _, t := range append([]dashboard.PanelSummaryInfo{p}, p.Collapsed...) {
+			if t.Title != "" {
+				panelTitles = append(panelTitles, t.Title)
+			}
+			if t.Description != "" {
+				panelDescriptions = append(panelDescriptions, t.Description)
+			}
+			queryText = append(queryText, t.Queries...)
+		}
 		if p.Type != "" {
 			panelTypes = append(panelTypes, p.Type)
 		}
@@ -327,6 +364,16 @@ func (s *DashboardDocumentBuilder) BuildDocument(ctx context.Context, key *resou
 		sort.Strings(transformations)
 		doc.Fields[DASHBOARD_TRANSFORMATIONS] = transformations
 	}
+	// kept in panel order, so highlights follow the layout of the dashboard
+	if len(panelTitles) > 0 {
+		doc.Fields[DASHBOARD_PANEL_TITLE] = panelTitles
+	}
+	if len(panelDescriptions) > 0 {
+		doc.Fields[DASHBOARD_PANEL_DESCRIPTION] = panelDescriptions
+	}
+	if len(queryText) > 0 {
+		doc.Fields[DASHBOARD_QUERY_TEXT] = queryText
+	}
 
 	// Add the stats fields
 	for k, v := range s.Stats[summary.UID] {
@@ -343,6 +390,9 @@ func DashboardFields() []string {
 		DASHBOARD_PANEL_TYPES,
 		DASHBOARD_DS_TYPES,
 		DASHBOARD_TRANSFORMATIONS,
+		DASHBOARD_PANEL_TITLE,
+		DASHBOARD_PANEL_DESCRIPTION,
+		DASHBOARD_QUERY_TEXT,
 	}
 
 	return append(baseFields, UsageInsightsFields()...)
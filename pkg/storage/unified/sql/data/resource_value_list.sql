@@ -0,0 +1,12 @@
+SELECT
+    {{ .Ident "guid" | .Into .Response.GUID }},
+    {{ .Ident "value" | .Into .Response.Value }}
+    FROM {{ if .History }}{{ .Ident "resource_history" }}{{ else }}{{ .Ident "resource" }}{{ end }}
+    WHERE 1 = 1
+        AND {{ .Ident "namespace" }} = {{ .Arg .Key.Namespace }}
+        AND {{ .Ident "group" }}     = {{ .Arg .Key.Group }}
+        AND {{ .Ident "resource" }}  = {{ .Arg .Key.Resource }}
+        AND {{ .Ident "guid" }}      > {{ .Arg .AfterGUID }}
+    ORDER BY {{ .Ident "guid" }} ASC
+    LIMIT {{ .Arg .Limit }}
+;
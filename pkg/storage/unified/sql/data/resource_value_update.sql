@@ -0,0 +1,4 @@
+UPDATE {{ if .History }}{{ .Ident "resource_history" }}{{ else }}{{ .Ident "resource" }}{{ end }}
+    SET {{ .Ident "value" }} = {{ .Arg .Value }}
+    WHERE {{ .Ident "guid" }} = {{ .Arg .GUID }}
+;
@@ -0,0 +1,110 @@
+package sql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	legacysecrets "github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/db"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/dbutil"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/sqltemplate"
+)
+
+// The number of values read at once by RewriteValues
+const rewriteValuesBatchSize = 100
+
+var _ resource.ValueRewriter = (*backend)(nil)
+
+// RewriteValues implements resource.ValueRewriter.
+func (b *backend) RewriteValues(ctx context.Context, key resource.NamespacedResource, rewrite func(value []byte) ([]byte, error)) (int64, error) {
+	ctx, span := b.tracer.Start(ctx, tracePrefix+"RewriteValues")
+	defer span.End()
+
+	var rewritten int64
+	for _, history := range []bool{false, true} {
+		afterGUID := ""
+		for {
+			var values []*resourceValueResponse
+			err := b.db.WithTx(ctx, ReadCommittedRO, func(ctx context.Context, tx db.Tx) error {
+				var err error
+				values, err = dbutil.Query(ctx, tx, sqlResourceValueList, &sqlResourceValueListRequest{
+					SQLTemplate: sqltemplate.New(b.dialect),
+					Key:         key,
+					History:     history,
+					AfterGUID:   afterGUID,
+					Limit:       rewriteValuesBatchSize,
+					Response:    new(resourceValueResponse),
+				})
+				return err
+			})
+			if err != nil {
+				return rewritten, fmt.Errorf("failed to list values: %w", err)
+			}
+
+			// the values are rewritten outside of the transactions, as the encryption must not be used in them
+			for _, v := range values {
+				afterGUID = v.GUID
+				value, err := rewrite(v.Value)
+				if err != nil {
+					return rewritten, err
+				}
+				if bytes.Equal(value, v.Value) {
+					continue
+				}
+
+				err = b.db.WithTx(ctx, ReadCommitted, func(ctx context.Context, tx db.Tx) error {
+					res, err := dbutil.Exec(ctx, tx, sqlResourceValueUpdate, &sqlResourceValueUpdateRequest{
+						SQLTemplate: sqltemplate.New(b.dialect),
+						History:     history,
+						GUID:        v.GUID,
+						Value:       value,
+					})
+					if err != nil {
+						return err
+					}
+					// no row is updated when the resource was written since it was listed, as every write has
+					// a new guid
+					rows, err := res.RowsAffected()
+					if err != nil {
+						return fmt.Errorf("failed to get rows affected: %w", err)
+					}
+					rewritten += rows
+					return nil
+				})
+				if err != nil {
+					return rewritten, fmt.Errorf("failed to update value: %w", err)
+				}
+			}
+			if len(values) < rewriteValuesBatchSize {
+				break
+			}
+		}
+	}
+	return rewritten, nil
+}
+
+// secretsValueEncrypter encrypts the resource values with the envelope encryption of the secrets service,
+// which uses the configured KMS providers to encrypt its data keys
+type secretsValueEncrypter struct {
+	secrets legacysecrets.Service
+}
+
+var (
+	_ resource.ValueEncrypter = (*secretsValueEncrypter)(nil)
+	_ resource.KeyRotator     = (*secretsValueEncrypter)(nil)
+)
+
+func (e *secretsValueEncrypter) Encrypt(ctx context.Context, value []byte) ([]byte, error) {
+	return e.secrets.Encrypt(ctx, value, legacysecrets.WithoutScope())
+}
+
+func (e *secretsValueEncrypter) Decrypt(ctx context.Context, value []byte) ([]byte, error) {
+	return e.secrets.Decrypt(ctx, value)
+}
+
+// RotateKeys disables the current data keys, so the next values are encrypted with new ones
+func (e *secretsValueEncrypter) RotateKeys(ctx context.Context) error {
+	return e.secrets.RotateDataKeys(ctx)
+}
@@ -49,6 +49,8 @@ var (
 	sqlResourceHistoryPrune             = mustTemplate("resource_history_prune.sql")
 	sqlResourceTrash                    = mustTemplate("resource_trash.sql")
 	sqlResourceInsertFromHistory        = mustTemplate("resource_insert_from_history.sql")
+	sqlResourceValueList                = mustTemplate("resource_value_list.sql")
+	sqlResourceValueUpdate              = mustTemplate("resource_value_update.sql")
 
 	// sqlResourceLabelsInsert = mustTemplate("resource_labels_insert.sql")
 	sqlResourceVersionGet    = mustTemplate("resource_version_get.sql")
@@ -360,6 +362,53 @@ func (r sqlResourceBlobQueryRequest) Validate() error {
 	return nil
 }
 
+// rewrite the stored values
+
+type resourceValueResponse struct {
+	GUID  string
+	Value []byte
+}
+
+type sqlResourceValueListRequest struct {
+	sqltemplate.SQLTemplate
+	Key       resource.NamespacedResource
+	History   bool   // list the values of resource_history instead of resource
+	AfterGUID string // the last listed guid
+	Limit     int64
+	Response  *resourceValueResponse
+}
+
+func (r *sqlResourceValueListRequest) Validate() error {
+	if r.Key.Namespace == "" || r.Key.Group == "" || r.Key.Resource == "" {
+		return fmt.Errorf("missing namespace, group or resource")
+	}
+	if r.Limit <= 0 {
+		return fmt.Errorf("limit must be greater than zero")
+	}
+	return nil
+}
+
+func (r *sqlResourceValueListRequest) Results() (*resourceValueResponse, error) {
+	return &resourceValueResponse{
+		GUID:  r.Response.GUID,
+		Value: r.Response.Value,
+	}, nil
+}
+
+type sqlResourceValueUpdateRequest struct {
+	sqltemplate.SQLTemplate
+	History bool
+	GUID    string
+	Value   []byte
+}
+
+func (r sqlResourceValueUpdateRequest) Validate() error {
+	if r.GUID == "" {
+		return fmt.Errorf("missing guid")
+	}
+	return nil
+}
+
 // update RV
 
 type sqlResourceUpdateRVRequest struct {
@@ -465,6 +465,42 @@ func TestUnifiedStorageQueries(t *testing.T) {
 					},
 				},
 			},
+			sqlResourceValueList: {
+				{
+					Name: "resource",
+					Data: &sqlResourceValueListRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Key:         resource.NamespacedResource{Namespace: "nn", Group: "gg", Resource: "rr"},
+						AfterGUID:   "guid",
+						Limit:       100,
+						Response:    new(resourceValueResponse),
+					},
+				},
+				{
+					Name: "history",
+					Data: &sqlResourceValueListRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Key:         resource.NamespacedResource{Namespace: "nn", Group: "gg", Resource: "rr"},
+						History:     true,
+						AfterGUID:   "guid",
+						Limit:       100,
+						Response:    new(resourceValueResponse),
+					},
+				},
+			},
+
+			sqlResourceValueUpdate: {
+				{
+					Name: "history",
+					Data: &sqlResourceValueUpdateRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						History:     true,
+						GUID:        "guid",
+						Value:       []byte("{}"),
+					},
+				},
+			},
+
 			sqlResourceInsertFromHistory: {
 				{
 					Name: "update",
@@ -2,11 +2,15 @@ package sql
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/ini.v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/grafana/authlib/types"
 	"github.com/grafana/dskit/ring"
@@ -14,6 +18,7 @@ import (
 	infraDB "github.com/grafana/grafana/pkg/infra/db"
 	secrets "github.com/grafana/grafana/pkg/registry/apis/secret/contracts"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	legacysecrets "github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/storage/unified/resource"
@@ -39,6 +44,7 @@ type ServerOptions struct {
 	Features       featuremgmt.FeatureToggles
 	QOSQueue       QOSEnqueueDequeuer
 	SecureValues   secrets.InlineSecureValueSupport
+	SecretsService legacysecrets.Service
 	Ring           *ring.Ring
 	RingLifecycler *ring.BasicLifecycler
 }
@@ -51,7 +57,9 @@ func NewResourceServer(
 	serverOptions := resource.ResourceServerOptions{
 		Tracer: opts.Tracer,
 		Blob: resource.BlobConfig{
-			URL: apiserverCfg.Key("blob_url").MustString(""),
+			URL:        apiserverCfg.Key("blob_url").MustString(""),
+			GCInterval: apiserverCfg.Key("blob_gc_interval").MustDuration(0),
+			GCMinAge:   apiserverCfg.Key("blob_gc_min_age").MustDuration(24 * time.Hour),
 		},
 		Reg:          opts.Reg,
 		SecureValues: opts.SecureValues,
@@ -74,6 +82,12 @@ func NewResourceServer(
 	unifiedStorageCfg := opts.Cfg.SectionWithEnvOverrides("unified_storage")
 	maxPageSizeBytes := unifiedStorageCfg.Key("max_page_size_bytes")
 	serverOptions.MaxPageSizeBytes = maxPageSizeBytes.MustInt(0)
+	serverOptions.Quotas = quotaConfig(opts.Cfg)
+	encryption, err := encryptionConfig(opts.Cfg, opts.SecretsService)
+	if err != nil {
+		return nil, err
+	}
+	serverOptions.Encryption = encryption
 
 	eDB, err := dbimpl.ProvideResourceDB(opts.DB, opts.Cfg, opts.Tracer)
 	if err != nil {
@@ -108,6 +122,60 @@ func NewResourceServer(
 	return resource.NewResourceServer(serverOptions)
 }
 
+// quotaConfig reads the namespace quotas. They look like:
+// [unified_storage_quota]
+// max_objects = 10000
+// requests_per_second = 100
+//
+// [unified_storage_quota.<namespace>]
+// max_objects = 50000
+func quotaConfig(cfg *setting.Cfg) resource.QuotaConfig {
+	readQuota := func(section *ini.Section) resource.NamespaceQuota {
+		return resource.NamespaceQuota{
+			MaxObjects:        section.Key("max_objects").MustInt64(0),
+			MaxBytes:          section.Key("max_bytes").MustInt64(0),
+			RequestsPerSecond: section.Key("requests_per_second").MustFloat64(0),
+			Burst:             section.Key("burst").MustInt(0),
+		}
+	}
+
+	section := cfg.Raw.Section("unified_storage_quota")
+	quotas := resource.QuotaConfig{
+		Default:              readQuota(section),
+		Namespaces:           map[string]resource.NamespaceQuota{},
+		UsageRefreshInterval: section.Key("usage_refresh_interval").MustDuration(time.Minute),
+	}
+	for _, section := range cfg.Raw.Sections() {
+		namespace, ok := strings.CutPrefix(section.Name(), "unified_storage_quota.")
+		if ok && namespace != "" {
+			quotas.Namespaces[namespace] = readQuota(section)
+		}
+	}
+	return quotas
+}
+
+// encryptionConfig reads the resources whose values are encrypted with the secrets service. They look like:
+// [unified_storage_encryption]
+// resources = dashboards.dashboard.grafana.app, folders.folder.grafana.app
+// reencrypt_interval = 24h
+func encryptionConfig(cfg *setting.Cfg, secretsService legacysecrets.Service) (resource.EncryptionConfig, error) {
+	section := cfg.Raw.Section("unified_storage_encryption")
+	config := resource.EncryptionConfig{
+		ReEncryptInterval: section.Key("reencrypt_interval").MustDuration(0),
+	}
+	for _, gr := range section.Key("resources").Strings(",") {
+		config.Resources = append(config.Resources, schema.ParseGroupResource(gr))
+	}
+	if len(config.Resources) == 0 {
+		return config, nil
+	}
+	if secretsService == nil {
+		return config, fmt.Errorf("the secrets service is required to encrypt the resources")
+	}
+	config.Encrypter = &secretsValueEncrypter{secrets: secretsService}
+	return config, nil
+}
+
 // isHighAvailabilityEnabled determines if high availability mode should
 // be enabled based on database configuration. High availability is enabled
 // by default except for SQLite databases.
@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -46,6 +48,15 @@ type UnifiedStorageGrpcService interface {
 
 	// Return the address where this service is running
 	GetAddress() string
+
+	// Return the handler reporting the consistency of the search indexes with the storage
+	IndexConsistencyHandler() http.Handler
+
+	// Return the handler reporting the storage used by the namespaces and their quotas
+	NamespaceUsageHandler() http.Handler
+
+	// Return the handler starting the re-encryption of the encrypted resources and reporting its progress
+	ReEncryptionHandler() http.Handler
 }
 
 type service struct {
@@ -80,6 +91,12 @@ type service struct {
 
 	queue     QOSEnqueueDequeuer
 	scheduler *scheduler.Scheduler
+
+	// Set once the resource server is started
+	serverMu           sync.RWMutex
+	consistencyChecker resource.IndexConsistencyChecker
+	usageReporter      resource.NamespaceUsageReporter
+	reEncrypter        resource.ReEncrypter
 }
 
 func ProvideUnifiedStorageGrpcService(
@@ -234,6 +251,18 @@ func (s *service) starting(ctx context.Context) error {
 		return err
 	}
 
+	s.serverMu.Lock()
+	if checker, ok := server.(resource.IndexConsistencyChecker); ok {
+		s.consistencyChecker = checker
+	}
+	if reporter, ok := server.(resource.NamespaceUsageReporter); ok {
+		s.usageReporter = reporter
+	}
+	if reEncrypter, ok := server.(resource.ReEncrypter); ok {
+		s.reEncrypter = reEncrypter
+	}
+	s.serverMu.Unlock()
+
 	healthService, err := resource.ProvideHealthService(server)
 	if err != nil {
 		return err
@@ -286,6 +315,73 @@ func (s *service) GetAddress() string {
 	return s.handler.GetAddress()
 }
 
+// IndexConsistencyHandler returns the handler reporting the consistency of the search indexes with the storage.
+func (s *service) IndexConsistencyHandler() http.Handler {
+	return resource.NewIndexConsistencyHandler(s)
+}
+
+// CheckIndexConsistency implements resource.IndexConsistencyChecker.
+func (s *service) CheckIndexConsistency(ctx context.Context, namespace string) ([]resource.IndexConsistency, error) {
+	s.serverMu.RLock()
+	checker := s.consistencyChecker
+	s.serverMu.RUnlock()
+
+	if checker == nil {
+		return nil, fmt.Errorf("resource server is not started")
+	}
+	return checker.CheckIndexConsistency(ctx, namespace)
+}
+
+// NamespaceUsageHandler returns the handler reporting the storage used by the namespaces and their quotas.
+func (s *service) NamespaceUsageHandler() http.Handler {
+	return resource.NewNamespaceUsageHandler(s)
+}
+
+// GetNamespaceUsage implements resource.NamespaceUsageReporter.
+func (s *service) GetNamespaceUsage(ctx context.Context, namespace string) ([]resource.NamespaceUsage, error) {
+	s.serverMu.RLock()
+	reporter := s.usageReporter
+	s.serverMu.RUnlock()
+
+	if reporter == nil {
+		return nil, fmt.Errorf("resource server is not started")
+	}
+	return reporter.GetNamespaceUsage(ctx, namespace)
+}
+
+// ReEncryptionHandler returns the handler starting the re-encryption of the encrypted resources and reporting its progress.
+func (s *service) ReEncryptionHandler() http.Handler {
+	return resource.NewReEncryptionHandler(s)
+}
+
+// StartReEncryption implements resource.ReEncrypter.
+func (s *service) StartReEncryption(ctx context.Context, rotate bool) (resource.ReEncryptionStatus, error) {
+	reEncrypter, err := s.getReEncrypter()
+	if err != nil {
+		return resource.ReEncryptionStatus{}, err
+	}
+	return reEncrypter.StartReEncryption(ctx, rotate)
+}
+
+// GetReEncryptionStatus implements resource.ReEncrypter.
+func (s *service) GetReEncryptionStatus(ctx context.Context) (resource.ReEncryptionStatus, error) {
+	reEncrypter, err := s.getReEncrypter()
+	if err != nil {
+		return resource.ReEncryptionStatus{}, err
+	}
+	return reEncrypter.GetReEncryptionStatus(ctx)
+}
+
+func (s *service) getReEncrypter() (resource.ReEncrypter, error) {
+	s.serverMu.RLock()
+	defer s.serverMu.RUnlock()
+
+	if s.reEncrypter == nil {
+		return nil, fmt.Errorf("resource server is not started")
+	}
+	return s.reEncrypter, nil
+}
+
 func (s *service) running(ctx context.Context) error {
 	select {
 	case err := <-s.stoppedCh:
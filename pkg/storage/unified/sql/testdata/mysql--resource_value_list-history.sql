@@ -0,0 +1,12 @@
+SELECT
+    `guid`,
+    `value`
+    FROM `resource_history`
+    WHERE 1 = 1
+        AND `namespace` = 'nn'
+        AND `group`     = 'gg'
+        AND `resource`  = 'rr'
+        AND `guid`      > 'guid'
+    ORDER BY `guid` ASC
+    LIMIT 100
+;
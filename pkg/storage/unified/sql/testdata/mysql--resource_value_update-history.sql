@@ -0,0 +1,4 @@
+UPDATE `resource_history`
+    SET `value` = '[123 125]'
+    WHERE `guid` = 'guid'
+;
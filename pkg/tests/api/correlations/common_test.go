@@ -110,6 +110,24 @@ func (c TestContext) Patch(params PatchParams) *http.Response {
 	return resp
 }
 
+type PutParams struct {
+	url  string
+	body string
+	user User
+}
+
+func (c TestContext) Put(params PutParams) *http.Response {
+	c.t.Helper()
+
+	req, err := http.NewRequest(http.MethodPut, c.getURL(params.url, params.user), bytes.NewBuffer([]byte(params.body)))
+	require.NoError(c.t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(c.t, err)
+
+	return resp
+}
+
 type DeleteParams struct {
 	url  string
 	user User
@@ -147,7 +165,7 @@ func (c TestContext) createOrg(name string) int64 {
 	c.env.Cfg.AutoAssignOrg = false
 	cfgProvider, err := configprovider.ProvideService(c.env.Cfg)
 	require.NoError(c.t, err)
-	quotaService := quotaimpl.ProvideService(context.Background(), store, cfgProvider)
+	quotaService := quotaimpl.ProvideService(context.Background(), store, cfgProvider, nil)
 	orgService, err := orgimpl.ProvideService(store, c.env.Cfg, quotaService)
 	require.NoError(c.t, err)
 	orgId, err := orgService.GetOrCreate(context.Background(), name)
@@ -163,7 +181,7 @@ func (c TestContext) createUser(cmd user.CreateUserCommand) User {
 
 	cfgProvider, err := configprovider.ProvideService(c.env.Cfg)
 	require.NoError(c.t, err)
-	quotaService := quotaimpl.ProvideService(context.Background(), store, cfgProvider)
+	quotaService := quotaimpl.ProvideService(context.Background(), store, cfgProvider, nil)
 	orgService, err := orgimpl.ProvideService(store, c.env.Cfg, quotaService)
 	require.NoError(c.t, err)
 	usrSvc, err := userimpl.ProvideService(
@@ -0,0 +1,218 @@
+package correlations
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/correlations"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/queryhistory"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestIntegrationCorrelationSuggestions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := NewTestEnv(t)
+
+	adminUser := ctx.createUser(user.CreateUserCommand{
+		DefaultOrgRole: string(org.RoleAdmin),
+		Password:       "admin2",
+		Login:          "admin2",
+	})
+
+	editorUser := ctx.createUser(user.CreateUserCommand{
+		DefaultOrgRole: string(org.RoleEditor),
+		Password:       "editor",
+		Login:          "editor",
+		OrgID:          adminUser.User.OrgID,
+	})
+
+	logs := ctx.createDs(&datasources.AddDataSourceCommand{
+		Name:  "logs",
+		Type:  datasources.DS_LOKI,
+		OrgID: adminUser.User.OrgID,
+	})
+	metrics := ctx.createDs(&datasources.AddDataSourceCommand{
+		Name:  "metrics",
+		Type:  datasources.DS_PROMETHEUS,
+		OrgID: adminUser.User.OrgID,
+	})
+
+	// the analyzer needs 5 queries of every data source by default
+	now := time.Now().Unix()
+	err := ctx.env.SQLStore.WithDbSession(t.Context(), func(sess *db.Session) error {
+		for i := 0; i < 5; i++ {
+			for uid, expr := range map[string]string{
+				logs.UID:    `{app="api", namespace="prod"} |= "error"`,
+				metrics.UID: `rate(http_requests_total{app="api", namespace="prod"}[5m])`,
+			} {
+				if _, err := sess.Insert(&queryhistory.QueryHistory{
+					UID:           util.GenerateShortUID(),
+					DatasourceUID: uid,
+					OrgID:         adminUser.User.OrgID,
+					CreatedBy:     adminUser.User.ID,
+					CreatedAt:     now,
+					Queries:       simplejson.NewFromAny([]any{map[string]any{"refId": "A", "expr": expr}}),
+				}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	getSuggestions := func(t *testing.T, state string) []correlations.CorrelationSuggestion {
+		t.Helper()
+		// nolint:gosec
+		res, err := http.Get(ctx.getURL("/api/datasources/correlations/suggestions?state="+state, adminUser))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		responseBody, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+
+		var suggestions []correlations.CorrelationSuggestion
+		require.NoError(t, json.Unmarshal(responseBody, &suggestions))
+		return suggestions
+	}
+
+	analyze := func(t *testing.T) correlations.AnalyzeResponseBody {
+		t.Helper()
+		res := ctx.Post(PostParams{
+			url:  "/api/datasources/correlations/suggestions/analyze",
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		responseBody, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+
+		var response correlations.AnalyzeResponseBody
+		require.NoError(t, json.Unmarshal(responseBody, &response))
+		return response
+	}
+
+	t.Run("non org admin shouldn't be able to analyze the queries", func(t *testing.T) {
+		res := ctx.Post(PostParams{
+			url:  "/api/datasources/correlations/suggestions/analyze",
+			user: editorUser,
+		})
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		responseBody, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		var response errorResponseBody
+		require.NoError(t, json.Unmarshal(responseBody, &response))
+		require.Contains(t, response.Message, "Permissions needed: datasources:write")
+
+		require.NoError(t, res.Body.Close())
+	})
+
+	var logsToMetrics, metricsToLogs correlations.CorrelationSuggestion
+	t.Run("analyzing the query history should store the pending suggestions", func(t *testing.T) {
+		require.Equal(t, 2, analyze(t).Suggestions)
+
+		suggestions := getSuggestions(t, "")
+		require.Len(t, suggestions, 2)
+		for _, suggestion := range suggestions {
+			require.Equal(t, correlations.SuggestionLogMetric, suggestion.Kind)
+			require.Equal(t, correlations.SuggestionPending, suggestion.State)
+			require.Equal(t, adminUser.User.OrgID, suggestion.OrgID)
+			require.Equal(t, "app", suggestion.Config.Field)
+			require.NotEmpty(t, suggestion.Evidence)
+			if suggestion.SourceUID == logs.UID {
+				logsToMetrics = suggestion
+			} else {
+				metricsToLogs = suggestion
+			}
+		}
+		require.Equal(t, metrics.UID, logsToMetrics.TargetUID)
+		require.Equal(t, logs.UID, metricsToLogs.TargetUID)
+
+		require.Equal(t, 2, analyze(t).Suggestions, "the pending suggestions are updated")
+		require.Len(t, getSuggestions(t, "pending"), 2)
+	})
+
+	t.Run("accepting a suggestion should create its correlation", func(t *testing.T) {
+		res := ctx.Post(PostParams{
+			url:  fmt.Sprintf("/api/datasources/correlations/suggestions/%s/accept", logsToMetrics.UID),
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		responseBody, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+
+		var response correlations.CreateCorrelationResponseBody
+		require.NoError(t, json.Unmarshal(responseBody, &response))
+		require.Equal(t, logs.UID, response.Result.SourceUID)
+		require.Equal(t, metrics.UID, *response.Result.TargetUID)
+		require.Equal(t, logsToMetrics.Label, response.Result.Label)
+		require.Equal(t, "app", response.Result.Config.Field)
+
+		res = ctx.Get(GetParams{
+			url:  fmt.Sprintf("/api/datasources/uid/%s/correlations/%s", logs.UID, response.Result.UID),
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+
+		accepted := getSuggestions(t, "accepted")
+		require.Len(t, accepted, 1)
+		require.Equal(t, response.Result.UID, accepted[0].CorrelationUID)
+
+		res = ctx.Post(PostParams{
+			url:  fmt.Sprintf("/api/datasources/correlations/suggestions/%s/accept", logsToMetrics.UID),
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("dismissing a suggestion should keep it from being suggested again", func(t *testing.T) {
+		res := ctx.Post(PostParams{
+			url:  fmt.Sprintf("/api/datasources/correlations/suggestions/%s/dismiss", metricsToLogs.UID),
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+
+		require.Equal(t, 0, analyze(t).Suggestions, "the correlated and dismissed suggestions are left alone")
+		require.Empty(t, getSuggestions(t, "pending"))
+		require.Len(t, getSuggestions(t, "dismissed"), 1)
+	})
+
+	t.Run("inexistent suggestion should result in a 404", func(t *testing.T) {
+		res := ctx.Post(PostParams{
+			url:  "/api/datasources/correlations/suggestions/nonexistent-uid/dismiss",
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("invalid state should result in a 400", func(t *testing.T) {
+		// nolint:gosec
+		res, err := http.Get(ctx.getURL("/api/datasources/correlations/suggestions?state=unknown", adminUser))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+}
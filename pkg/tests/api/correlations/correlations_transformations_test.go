@@ -0,0 +1,222 @@
+package correlations
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/correlations"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestIntegrationCorrelationTransformationChains(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := NewTestEnv(t)
+
+	adminUser := ctx.createUser(user.CreateUserCommand{
+		DefaultOrgRole: string(org.RoleAdmin),
+		Password:       "admin2",
+		Login:          "admin2",
+	})
+
+	editorUser := ctx.createUser(user.CreateUserCommand{
+		DefaultOrgRole: string(org.RoleEditor),
+		Password:       "editor",
+		Login:          "editor",
+		OrgID:          adminUser.User.OrgID,
+	})
+
+	dataSource := ctx.createDs(&datasources.AddDataSourceCommand{
+		Name:  "logs",
+		Type:  datasources.DS_LOKI,
+		OrgID: adminUser.User.OrgID,
+	})
+
+	decode := func(t *testing.T, res *http.Response, v any) {
+		t.Helper()
+		responseBody, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.NoError(t, json.Unmarshal(responseBody, v))
+	}
+
+	getCorrelation := func(t *testing.T, uid string) correlations.Correlation {
+		t.Helper()
+		res := ctx.Get(GetParams{
+			url:  fmt.Sprintf("/api/datasources/uid/%s/correlations/%s", dataSource.UID, uid),
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var correlation correlations.Correlation
+		decode(t, res, &correlation)
+		return correlation
+	}
+
+	t.Run("non org admin shouldn't be able to create transformation chains", func(t *testing.T) {
+		res := ctx.Post(PostParams{
+			url:  "/api/datasources/correlations/transformation-chains",
+			body: `{"name": "trace id", "transformations": [{"type": "logfmt"}]}`,
+			user: editorUser,
+		})
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		var response errorResponseBody
+		decode(t, res, &response)
+		require.Contains(t, response.Message, "Permissions needed: datasources:write")
+	})
+
+	t.Run("invalid transformation chains should result in a 400", func(t *testing.T) {
+		for _, body := range []string{
+			`{"name": "", "transformations": [{"type": "logfmt"}]}`,
+			`{"name": "trace id", "transformations": [{"type": "extract", "expression": "traceID=(\\w+)"}]}`,
+		} {
+			res := ctx.Post(PostParams{
+				url:  "/api/datasources/correlations/transformation-chains",
+				body: body,
+				user: adminUser,
+			})
+			require.Equal(t, http.StatusBadRequest, res.StatusCode, body)
+			require.NoError(t, res.Body.Close())
+		}
+	})
+
+	var chain correlations.TransformationChain
+	t.Run("should create, list and get a transformation chain", func(t *testing.T) {
+		res := ctx.Post(PostParams{
+			url:  "/api/datasources/correlations/transformation-chains",
+			body: `{"name": "trace id", "description": "Trace ID from log lines", "transformations": [{"type": "extract", "expression": "traceID=(?P<traceId>\\w+)"}]}`,
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		decode(t, res, &chain)
+		require.NotEmpty(t, chain.UID)
+		require.Equal(t, adminUser.User.OrgID, chain.OrgID)
+
+		res = ctx.Get(GetParams{
+			url:  "/api/datasources/correlations/transformation-chains",
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		var chains []correlations.TransformationChain
+		decode(t, res, &chains)
+		require.Len(t, chains, 1)
+		require.Equal(t, chain.UID, chains[0].UID)
+
+		res = ctx.Get(GetParams{
+			url:  fmt.Sprintf("/api/datasources/correlations/transformation-chains/%s", chain.UID),
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		var stored correlations.TransformationChain
+		decode(t, res, &stored)
+		require.Equal(t, "trace id", stored.Name)
+		require.Equal(t, "Trace ID from log lines", stored.Description)
+		require.Equal(t, chain.Transformations, stored.Transformations)
+	})
+
+	var correlationUID string
+	t.Run("correlations using the chain should get its transformations", func(t *testing.T) {
+		res := ctx.Post(PostParams{
+			url: fmt.Sprintf("/api/datasources/uid/%s/correlations", dataSource.UID),
+			body: fmt.Sprintf(`{
+				"targetUID": "%s",
+				"label": "trace",
+				"type": "query",
+				"config": {
+					"field": "message",
+					"target": {"expr": "{app=\"api\"}"},
+					"transformations": [{"type": "logfmt"}],
+					"transformationChain": "%s"
+				}
+			}`, dataSource.UID, chain.UID),
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var response correlations.CreateCorrelationResponseBody
+		decode(t, res, &response)
+		correlationUID = response.Result.UID
+
+		correlation := getCorrelation(t, correlationUID)
+		require.Equal(t, chain.UID, correlation.Config.TransformationChain)
+		require.Equal(t, chain.Transformations, correlation.Config.Transformations, "the transformations of the chain replace the ones of the config")
+	})
+
+	t.Run("updating the chain should update the correlations using it", func(t *testing.T) {
+		res := ctx.Put(PutParams{
+			url:  fmt.Sprintf("/api/datasources/correlations/transformation-chains/%s", chain.UID),
+			body: `{"name": "trace id", "transformations": [{"type": "regex", "expression": "trace=(\\w+)", "mapValue": "traceId"}]}`,
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var updated correlations.TransformationChain
+		decode(t, res, &updated)
+		require.Empty(t, updated.Description, "the description is cleared")
+		require.Len(t, updated.Transformations, 1)
+		require.Equal(t, "regex", updated.Transformations[0].Type)
+
+		correlation := getCorrelation(t, correlationUID)
+		require.Equal(t, updated.Transformations, correlation.Config.Transformations)
+
+		res = ctx.Put(PutParams{
+			url:  "/api/datasources/correlations/transformation-chains/nonexistent-uid",
+			body: `{"name": "trace id", "transformations": [{"type": "logfmt"}]}`,
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should test the transformations of the chain", func(t *testing.T) {
+		res := ctx.Post(PostParams{
+			url:  "/api/datasources/correlations/transformations/test",
+			body: fmt.Sprintf(`{"field": "message", "values": {"message": "level=error trace=4bf92f3577b34da6"}, "transformationChain": "%s"}`, chain.UID),
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var response correlations.TestTransformationsResponseBody
+		decode(t, res, &response)
+		require.Equal(t, map[string]string{"traceId": "4bf92f3577b34da6"}, response.Variables)
+
+		res = ctx.Post(PostParams{
+			url:  "/api/datasources/correlations/transformations/test",
+			body: `{"field": "message", "values": {"message": "level=error"}, "transformationChain": "nonexistent-uid"}`,
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should delete the chain once no correlation uses it", func(t *testing.T) {
+		url := fmt.Sprintf("/api/datasources/correlations/transformation-chains/%s", chain.UID)
+		res := ctx.Delete(DeleteParams{url: url, user: adminUser})
+		require.Equal(t, http.StatusConflict, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+
+		res = ctx.Delete(DeleteParams{
+			url:  fmt.Sprintf("/api/datasources/uid/%s/correlations/%s", dataSource.UID, correlationUID),
+			user: adminUser,
+		})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+
+		res = ctx.Delete(DeleteParams{url: url, user: adminUser})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+
+		res = ctx.Get(GetParams{url: url, user: adminUser})
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+}
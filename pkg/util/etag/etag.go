@@ -0,0 +1,76 @@
+// Package etag implements the optimistic concurrency of the HTTP API with the ETag and If-Match headers.
+//
+// The GET requests of a resource return its ETag, the requests changing or deleting it with an If-Match header fail
+// with a 412 Precondition Failed error when the resource was changed since. Requests without an If-Match header
+// aren't checked.
+package etag
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+const (
+	Header        = "ETag"
+	IfMatchHeader = "If-Match"
+)
+
+var ErrPreconditionFailed = errutil.PreconditionFailed("etag.precondition-failed",
+	errutil.WithPublicMessage("The resource was changed since it was read, read it again and retry"))
+
+// FromVersion returns the ETag of a resource with a version incremented on every change
+func FromVersion(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// FromString returns the ETag of a resource with a version string, such as a hash of its content
+func FromString(version string) string {
+	return strconv.Quote(version)
+}
+
+// FromContent returns the ETag of a resource without a version, the hash of its JSON encoding
+func FromContent(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	hasher := fnv.New64a()
+	_, _ = hasher.Write(data)
+	return fmt.Sprintf(`"%x"`, hasher.Sum(nil)), nil
+}
+
+// IfMatch returns the ETags of the If-Match header of the request, nil when it isn't set. The handlers saving the
+// resources with a version check save the current version once the request is checked, so that the resource isn't
+// changed between the check and the save.
+func IfMatch(r *http.Request) []string {
+	header := strings.TrimSpace(r.Header.Get(IfMatchHeader))
+	if header == "" {
+		return nil
+	}
+	tags := strings.Split(header, ",")
+	for i, tag := range tags {
+		tags[i] = strings.TrimSpace(tag)
+	}
+	return tags
+}
+
+// Check returns ErrPreconditionFailed when the request has an If-Match header and none of its ETags is current, the
+// ETag of the resource. Weak ETags never match since the resource must be unchanged.
+func Check(r *http.Request, current string) error {
+	tags := IfMatch(r)
+	if tags == nil {
+		return nil
+	}
+	for _, tag := range tags {
+		if tag == "*" || tag == current {
+			return nil
+		}
+	}
+	return ErrPreconditionFailed.Errorf("ETag %s doesn't match If-Match %s", current, r.Header.Get(IfMatchHeader))
+}
@@ -0,0 +1,55 @@
+package etag
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	current := FromVersion(3)
+	require.Equal(t, `"3"`, current)
+
+	testCases := []struct {
+		desc    string
+		ifMatch string
+		err     bool
+	}{
+		{desc: "no header", ifMatch: ""},
+		{desc: "current ETag", ifMatch: `"3"`},
+		{desc: "any ETag", ifMatch: "*"},
+		{desc: "list with the current ETag", ifMatch: `"1", "3"`},
+		{desc: "previous ETag", ifMatch: `"2"`, err: true},
+		{desc: "weak ETag", ifMatch: `W/"3"`, err: true},
+		{desc: "unquoted ETag", ifMatch: `3`, err: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			r, err := http.NewRequest(http.MethodPut, "/api/dashboards/uid/abc", nil)
+			require.NoError(t, err)
+			if tc.ifMatch != "" {
+				r.Header.Set(IfMatchHeader, tc.ifMatch)
+			}
+
+			err = Check(r, current)
+			if tc.err {
+				assert.ErrorIs(t, err, ErrPreconditionFailed)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFromContent(t *testing.T) {
+	a, err := FromContent(map[string]any{"title": "a"})
+	require.NoError(t, err)
+	b, err := FromContent(map[string]any{"title": "b"})
+	require.NoError(t, err)
+
+	assert.NotEqual(t, a, b)
+	assert.Regexp(t, `^"[0-9a-f]{16}"$`, a)
+	assert.Equal(t, `"abc"`, FromString("abc"))
+}
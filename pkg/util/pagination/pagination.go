@@ -0,0 +1,227 @@
+// Package pagination pages through the lists of the HTTP API with opaque cursors.
+//
+// A cursor holds the values of the sort keys of the last row of a page, the next page is made of the rows sorted after
+// them. Unlike pages selected with an offset, rows aren't skipped or returned twice when rows are added or removed
+// while paging. The last sort key must be unique, usually the id, so that the order is stable.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+const (
+	// MaxLimit is the default maximum number of rows of a page
+	MaxLimit = 1000
+
+	CursorParam = "cursor"
+	LimitParam  = "limit"
+)
+
+var (
+	ErrInvalidCursor   = errutil.BadRequest("pagination.invalid-cursor", errutil.WithPublicMessage("The cursor is invalid"))
+	ErrInvalidLimit    = errutil.BadRequest("pagination.invalid-limit", errutil.WithPublicMessage("The limit must be a positive number"))
+	ErrUnsupportedSort = errutil.BadRequest("pagination.unsupported-sort", errutil.WithPublicMessage("The list can't be paged with a cursor in this sort order"))
+)
+
+// Cursor is the position of a page in a list
+type Cursor struct {
+	// Keys are the values of the sort keys of the last row of the previous page
+	Keys []any `json:"k,omitempty"`
+	// Offset is the number of rows before the page, for the lists whose rows can't be filtered by their sort keys
+	Offset int64 `json:"o,omitempty"`
+}
+
+// String encodes the cursor, clients pass it back as is
+func (c *Cursor) String() string {
+	// the keys are strings, numbers and times which are always encoded
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseCursor decodes a cursor returned by String, numbers are decoded as int64 when they're integers
+func ParseCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor.Errorf("failed to decode cursor: %w", err)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	cursor := &Cursor{}
+	if err := decoder.Decode(cursor); err != nil {
+		return nil, ErrInvalidCursor.Errorf("failed to decode cursor: %w", err)
+	}
+	if cursor.Offset < 0 {
+		return nil, ErrInvalidCursor.Errorf("negative offset %d", cursor.Offset)
+	}
+
+	for i, key := range cursor.Keys {
+		switch v := key.(type) {
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				cursor.Keys[i] = n
+			} else if f, err := v.Float64(); err == nil {
+				cursor.Keys[i] = f
+			} else {
+				return nil, ErrInvalidCursor.Errorf("invalid number %q", v)
+			}
+		case string, bool:
+		default:
+			return nil, ErrInvalidCursor.Errorf("invalid key of type %T", key)
+		}
+	}
+	return cursor, nil
+}
+
+// Page is the page of a list requested by a client
+type Page struct {
+	Limit int
+	// After is the position of the page, nil for the first page
+	After *Cursor
+}
+
+// FromQuery reads the page from the cursor and limit query parameters. The limit defaults to defaultLimit and is
+// capped to maxLimit. The perpage parameter of the lists paged by page number is read when limit isn't set.
+func FromQuery(values url.Values, defaultLimit, maxLimit int) (Page, error) {
+	page := Page{Limit: defaultLimit}
+
+	limit := values.Get(LimitParam)
+	if limit == "" {
+		limit = values.Get("perpage")
+	}
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			return Page{}, ErrInvalidLimit.Errorf("invalid limit %q", limit)
+		}
+		if n > 0 {
+			page.Limit = n
+		}
+	}
+	if page.Limit > maxLimit {
+		page.Limit = maxLimit
+	}
+
+	if cursor := values.Get(CursorParam); cursor != "" {
+		after, err := ParseCursor(cursor)
+		if err != nil {
+			return Page{}, err
+		}
+		page.After = after
+	}
+	return page, nil
+}
+
+// Key is a key the rows of a list are sorted by
+type Key struct {
+	// Column is the column or the expression of the key
+	Column string
+	Desc   bool
+	// Time is set when the key is a date, its value is encoded as a RFC 3339 string in the cursor
+	Time bool
+}
+
+// OrderBy returns the ORDER BY clause of the keys, without the ORDER BY keywords
+func OrderBy(keys []Key) string {
+	clauses := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key.Desc {
+			clauses = append(clauses, key.Column+" DESC")
+		} else {
+			clauses = append(clauses, key.Column+" ASC")
+		}
+	}
+	return strings.Join(clauses, ", ")
+}
+
+// Where returns the condition selecting the rows sorted after the cursor, for the keys (a, b) in ascending order it's
+// (a > ? OR (a = ? AND b > ?))
+func Where(keys []Key, after *Cursor) (string, []any, error) {
+	if len(after.Keys) != len(keys) {
+		return "", nil, ErrInvalidCursor.Errorf("cursor has %d keys instead of %d", len(after.Keys), len(keys))
+	}
+
+	values := make([]any, len(keys))
+	for i, key := range keys {
+		values[i] = after.Keys[i]
+		if !key.Time {
+			continue
+		}
+		s, ok := after.Keys[i].(string)
+		if !ok {
+			return "", nil, ErrInvalidCursor.Errorf("key %d isn't a time", i)
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return "", nil, ErrInvalidCursor.Errorf("key %d isn't a time: %w", i, err)
+		}
+		values[i] = t
+	}
+
+	conditions := make([]string, 0, len(keys))
+	args := make([]any, 0, len(keys)*(len(keys)+1)/2)
+	for i, key := range keys {
+		terms := make([]string, 0, i+1)
+		for _, previous := range keys[:i] {
+			terms = append(terms, previous.Column+" = ?")
+		}
+		args = append(args, values[:i]...)
+
+		operator := ">"
+		if key.Desc {
+			operator = "<"
+		}
+		terms = append(terms, fmt.Sprintf("%s %s ?", key.Column, operator))
+		args = append(args, values[i])
+
+		conditions = append(conditions, "("+strings.Join(terms, " AND ")+")")
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args, nil
+}
+
+// Next returns the cursor of the page after a page of count rows whose last row has the key values, nil when the page
+// isn't full since there are no rows after it
+func Next(count, limit int, last func() []any) *Cursor {
+	if limit <= 0 || count < limit {
+		return nil
+	}
+	keys := last()
+	for i, key := range keys {
+		if t, ok := key.(time.Time); ok {
+			keys[i] = t.UTC().Format(time.RFC3339Nano)
+		}
+	}
+	return &Cursor{Keys: keys}
+}
+
+// NextOffset returns the cursor of the page after a page of count rows starting at the offset of after, nil when the
+// page isn't full
+func NextOffset(count, limit int, after *Cursor) *Cursor {
+	if limit <= 0 || count < limit {
+		return nil
+	}
+	var offset int64
+	if after != nil {
+		offset = after.Offset
+	}
+	return &Cursor{Offset: offset + int64(count)}
+}
+
+// LinkHeader returns the value of the Link header of the next page of the request, the page number of the request
+// is replaced by the cursor
+func LinkHeader(u *url.URL, next *Cursor, limit int) string {
+	query := u.Query()
+	query.Del("page")
+	query.Del("perpage")
+	query.Set(CursorParam, next.String())
+	query.Set(LimitParam, strconv.Itoa(limit))
+	return fmt.Sprintf("<%s?%s>; rel=\"next\"", u.Path, query.Encode())
+}
@@ -0,0 +1,103 @@
+package pagination
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	cursor := &Cursor{Keys: []any{"admin", int64(42), true, 1.5}}
+
+	parsed, err := ParseCursor(cursor.String())
+	require.NoError(t, err)
+	assert.Equal(t, cursor, parsed)
+
+	for _, s := range []string{"not base64!", "bm90IGpzb24", (&Cursor{Keys: []any{[]string{"a"}}}).String(), (&Cursor{Offset: -1}).String()} {
+		_, err := ParseCursor(s)
+		assert.ErrorIs(t, err, ErrInvalidCursor, s)
+	}
+}
+
+func TestFromQuery(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		query string
+		limit int
+		err   error
+	}{
+		{desc: "default limit", query: "", limit: 100},
+		{desc: "limit", query: "limit=10", limit: 10},
+		{desc: "perpage", query: "perpage=20", limit: 20},
+		{desc: "limit over perpage", query: "limit=10&perpage=20", limit: 10},
+		{desc: "capped limit", query: "limit=100000", limit: MaxLimit},
+		{desc: "negative limit", query: "limit=-1", err: ErrInvalidLimit},
+		{desc: "invalid limit", query: "limit=ten", err: ErrInvalidLimit},
+		{desc: "invalid cursor", query: "cursor=invalid!", err: ErrInvalidCursor},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			values, err := url.ParseQuery(tc.query)
+			require.NoError(t, err)
+
+			page, err := FromQuery(values, 100, MaxLimit)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.limit, page.Limit)
+			assert.Nil(t, page.After)
+		})
+	}
+
+	t.Run("cursor", func(t *testing.T) {
+		page, err := FromQuery(url.Values{CursorParam: {(&Cursor{Offset: 10}).String()}}, 100, MaxLimit)
+		require.NoError(t, err)
+		assert.Equal(t, &Cursor{Offset: 10}, page.After)
+	})
+}
+
+func TestWhere(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 0, 0, 500, time.UTC)
+	keys := []Key{{Column: "u.created", Desc: true, Time: true}, {Column: "u.login"}, {Column: "u.id"}}
+
+	next := Next(2, 2, func() []any { return []any{created, "admin", int64(1)} })
+	require.NotNil(t, next)
+	assert.Equal(t, "2024-03-01T12:00:00.0000005Z", next.Keys[0])
+
+	after, err := ParseCursor(next.String())
+	require.NoError(t, err)
+
+	where, args, err := Where(keys, after)
+	require.NoError(t, err)
+	assert.Equal(t, "((u.created < ?) OR (u.created = ? AND u.login > ?) OR (u.created = ? AND u.login = ? AND u.id > ?))", where)
+	assert.Equal(t, []any{created, created, "admin", created, "admin", int64(1)}, args)
+	assert.Equal(t, "u.created DESC, u.login ASC, u.id ASC", OrderBy(keys))
+
+	_, _, err = Where(keys, &Cursor{Keys: []any{"admin", int64(1)}})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	_, _, err = Where(keys, &Cursor{Keys: []any{"yesterday", "admin", int64(1)}})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestNext(t *testing.T) {
+	last := func() []any { return []any{int64(3)} }
+	assert.Nil(t, Next(1, 2, last), "there are no rows after a page that isn't full")
+	assert.Equal(t, &Cursor{Keys: []any{int64(3)}}, Next(2, 2, last))
+
+	assert.Nil(t, NextOffset(1, 2, nil))
+	assert.Equal(t, &Cursor{Offset: 2}, NextOffset(2, 2, nil))
+	assert.Equal(t, &Cursor{Offset: 6}, NextOffset(2, 2, &Cursor{Offset: 4}))
+}
+
+func TestLinkHeader(t *testing.T) {
+	u, err := url.Parse("/api/users/search?query=admin&page=2&perpage=10")
+	require.NoError(t, err)
+
+	next := &Cursor{Offset: 20}
+	assert.Equal(t, "</api/users/search?cursor="+next.String()+"&limit=10&query=admin>; rel=\"next\"", LinkHeader(u, next, 10))
+}
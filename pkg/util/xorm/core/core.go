@@ -287,7 +287,7 @@ func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{
 		}
 		return nil, err
 	}
-	return &Rows{rows, db}, nil
+	return &Rows{Rows: rows, db: db}, nil
 }
 
 // Query overwrites sql.DB.Query
@@ -1039,6 +1039,34 @@ func (p *PK) FromString(content string) error {
 type Rows struct {
 	*sql.Rows
 	db *DB
+
+	// read counts the rows returned by Next
+	read    int64
+	onClose func(read int64, err error)
+}
+
+// Next overwrites sql.Rows.Next to count the rows read
+func (rs *Rows) Next() bool {
+	if rs.Rows.Next() {
+		rs.read++
+		return true
+	}
+	return false
+}
+
+// Close overwrites sql.Rows.Close to call the function set with OnClose
+func (rs *Rows) Close() error {
+	err := rs.Rows.Close()
+	if onClose := rs.onClose; onClose != nil {
+		rs.onClose = nil
+		onClose(rs.read, rs.Rows.Err())
+	}
+	return err
+}
+
+// OnClose sets a function called with the number of rows read and the error of the iteration when the rows are closed
+func (rs *Rows) OnClose(fn func(read int64, err error)) {
+	rs.onClose = fn
 }
 
 func (rs *Rows) ToMapString() ([]map[string]string, error) {
@@ -1483,7 +1511,7 @@ func (s *Stmt) QueryContext(ctx context.Context, args ...interface{}) (*Rows, er
 	if err != nil {
 		return nil, err
 	}
-	return &Rows{rows, s.db}, nil
+	return &Rows{Rows: rows, db: s.db}, nil
 }
 
 func (s *Stmt) Query(args ...interface{}) (*Rows, error) {
@@ -1798,7 +1826,7 @@ func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{
 	if err != nil {
 		return nil, err
 	}
-	return &Rows{rows, tx.db}, nil
+	return &Rows{Rows: rows, db: tx.db}, nil
 }
 
 func (tx *Tx) Query(query string, args ...interface{}) (*Rows, error) {
@@ -45,6 +45,16 @@ type Engine struct {
 	defaultContext    context.Context
 	sequenceGenerator SequenceGenerator // If not nil, this generator is used to generate auto-increment values for inserts.
 	randomIDGen       func() int64
+	queryObserver     QueryObserver // If not nil, it is called after each query run by the sessions.
+}
+
+// QueryObserver is called after each query run by a session, with the number of rows the query read or affected
+// and its duration. The duration of a query returning rows includes reading them.
+type QueryObserver func(ctx context.Context, sql string, args []any, rows int64, took time.Duration, err error)
+
+// SetQueryObserver sets the function called after each query run by the sessions of the engine
+func (engine *Engine) SetQueryObserver(observer QueryObserver) {
+	engine.queryObserver = observer
 }
 
 // CondDeleted returns the conditions whether a record is soft deleted.
@@ -49,6 +49,24 @@ func (session *Session) queryRows(sqlStr string, args ...any) (*core.Rows, error
 		}
 	}
 
+	observer := session.engine.queryObserver
+	if observer == nil {
+		return session.doQueryRows(sqlStr, args...)
+	}
+
+	ctx, begin := session.ctx, time.Now()
+	rows, err := session.doQueryRows(sqlStr, args...)
+	if err != nil {
+		observer(ctx, sqlStr, args, 0, time.Since(begin), err)
+		return nil, err
+	}
+	rows.OnClose(func(read int64, err error) {
+		observer(ctx, sqlStr, args, read, time.Since(begin), err)
+	})
+	return rows, nil
+}
+
+func (session *Session) doQueryRows(sqlStr string, args ...any) (*core.Rows, error) {
 	if session.isAutoCommit {
 		db := session.DB()
 		if session.prepareStmt {
@@ -170,6 +188,23 @@ func (session *Session) exec(sqlStr string, args ...any) (sql.Result, error) {
 		}
 	}
 
+	observer := session.engine.queryObserver
+	if observer == nil {
+		return session.doExec(sqlStr, args...)
+	}
+
+	begin := time.Now()
+	res, err := session.doExec(sqlStr, args...)
+	var affected int64
+	if err == nil {
+		// not every driver reports the affected rows
+		affected, _ = res.RowsAffected()
+	}
+	observer(session.ctx, sqlStr, args, affected, time.Since(begin), err)
+	return res, err
+}
+
+func (session *Session) doExec(sqlStr string, args ...any) (sql.Result, error) {
 	if !session.isAutoCommit {
 		return session.tx.ExecContext(session.ctx, sqlStr, args...)
 	}
@@ -1,8 +1,10 @@
 package xorm
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	_ "github.com/grafana/grafana/pkg/util/sqlite"
 	"github.com/stretchr/testify/require"
@@ -88,3 +90,35 @@ func TestRandomID(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryObserver(t *testing.T) {
+	eng, err := NewEngine("sqlite3", ":memory:")
+	require.NoError(t, err)
+	_, err = eng.Exec("CREATE TABLE test_struct (id int primary key, comment text, json text)")
+	require.NoError(t, err)
+
+	type observed struct {
+		sql  string
+		rows int64
+		err  error
+	}
+	var queries []observed
+	eng.SetQueryObserver(func(ctx context.Context, sql string, args []any, rows int64, took time.Duration, err error) {
+		queries = append(queries, observed{sql: sql, rows: rows, err: err})
+	})
+
+	_, err = eng.Exec("INSERT INTO test_struct (id, comment) VALUES (?, ?), (?, ?)", 1, "first", 2, "second")
+	require.NoError(t, err)
+	var found []TestStruct
+	require.NoError(t, eng.Find(&found))
+	require.Len(t, found, 2)
+	_, err = eng.Exec("UPDATE missing SET id = 1")
+	require.Error(t, err)
+
+	require.Len(t, queries, 3)
+	require.Equal(t, int64(2), queries[0].rows)
+	require.Contains(t, queries[1].sql, "SELECT")
+	require.Equal(t, int64(2), queries[1].rows)
+	require.NoError(t, queries[1].err)
+	require.Error(t, queries[2].err)
+}
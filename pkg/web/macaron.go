@@ -115,7 +115,21 @@ func (m *Macaron) Use(h Handler) {
 	m.mws = append(m.mws, mwFromHandler(h))
 }
 
+// AnnotatedHandler is a handler carrying a description of the route it's registered on, such as the permissions
+// it requires, which is used to document the API. Requests are served by the handler it wraps.
+type AnnotatedHandler interface {
+	Unwrap() Handler
+}
+
 func mwFromHandler(handler Handler) Middleware {
+	for {
+		annotated, ok := handler.(AnnotatedHandler)
+		if !ok {
+			break
+		}
+		handler = annotated.Unwrap()
+	}
+
 	if mw, ok := handler.(Middleware); ok {
 		return mw
 	}
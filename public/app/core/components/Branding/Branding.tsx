@@ -1,19 +1,36 @@
 import { css, cx } from '@emotion/css';
+import { get } from 'lodash';
 import { FC } from 'react';
 
 import { colorManipulator } from '@grafana/data';
-import { useTheme2 } from '@grafana/ui';
+import { config } from '@grafana/runtime';
+import { IconName, useTheme2 } from '@grafana/ui';
 import g8LoginDarkSvg from 'img/g8_login_dark.svg';
 import g8LoginLightSvg from 'img/g8_login_light.svg';
 import grafanaIconSvg from 'img/grafana_icon.svg';
 
+import { FooterLink } from '../Footer/Footer';
+
+/** The branding configured with the branding API, served in the frontend settings */
+interface WhitelabelingSettings {
+  appTitle?: string;
+  loginTitle?: string;
+  loginSubtitle?: string;
+  loginLogo?: string;
+  loginBackground?: string;
+  menuLogo?: string;
+  links?: Array<{ text: string; url: string; icon?: string; blank?: string }>;
+}
+
+const whitelabeling: WhitelabelingSettings | undefined = get(config, 'whitelabeling');
+
 export interface BrandComponentProps {
   className?: string;
   children?: JSX.Element | JSX.Element[];
 }
 
 export const LoginLogo: FC<BrandComponentProps & { logo?: string }> = ({ className, logo }) => {
-  return <img className={className} src={`${logo ? logo : grafanaIconSvg}`} alt="Grafana" />;
+  return <img className={className} src={`${logo || whitelabeling?.loginLogo || grafanaIconSvg}`} alt="Grafana" />;
 };
 
 const LoginBackground: FC<BrandComponentProps> = ({ className, children }) => {
@@ -27,7 +44,7 @@ const LoginBackground: FC<BrandComponentProps> = ({ className, children }) => {
       right: 0,
       bottom: 0,
       top: 0,
-      background: `url(${theme.isDark ? g8LoginDarkSvg : g8LoginLightSvg})`,
+      background: whitelabeling?.loginBackground || `url(${theme.isDark ? g8LoginDarkSvg : g8LoginLightSvg})`,
       backgroundPosition: 'top center',
       backgroundSize: 'auto',
       backgroundRepeat: 'no-repeat',
@@ -46,7 +63,7 @@ const LoginBackground: FC<BrandComponentProps> = ({ className, children }) => {
 };
 
 const MenuLogo: FC<BrandComponentProps> = ({ className }) => {
-  return <img className={className} src={grafanaIconSvg} alt="Grafana" />;
+  return <img className={className} src={whitelabeling?.menuLogo || grafanaIconSvg} alt="Grafana" />;
 };
 
 const LoginBoxBackground = () => {
@@ -62,10 +79,22 @@ export class Branding {
   static LoginBackground = LoginBackground;
   static MenuLogo = MenuLogo;
   static LoginBoxBackground = LoginBoxBackground;
-  static AppTitle = 'Grafana';
-  static LoginTitle = 'Welcome to Grafana';
+  static AppTitle = whitelabeling?.appTitle || 'Grafana';
+  static LoginTitle = whitelabeling?.loginTitle || 'Welcome to Grafana';
   static HideEdition = false;
+  static FooterLinks = whitelabeling?.links?.length
+    ? whitelabeling.links.map(
+        (link, index): FooterLink => ({
+          id: `branding-${index}`,
+          text: link.text,
+          url: link.url,
+          // eslint-disable-next-line @typescript-eslint/consistent-type-assertions
+          icon: (link.icon || undefined) as IconName | undefined,
+          target: link.blank === '_self' ? '_self' : '_blank',
+        })
+      )
+    : null;
   static GetLoginSubTitle = (): null | string => {
-    return null;
+    return whitelabeling?.loginSubtitle || null;
   };
 }
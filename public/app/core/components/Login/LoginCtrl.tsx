@@ -4,7 +4,7 @@ import { t } from '@grafana/i18n';
 import { FetchError, getBackendSrv, isFetchError, locationService } from '@grafana/runtime';
 import config from 'app/core/config';
 
-import { LoginDTO, AuthNRedirectDTO } from './types';
+import { LoginDTO, AuthNRedirectDTO, MFAEnrollmentDTO } from './types';
 
 const isOauthEnabled = () => {
   return !!config.oauth && Object.keys(config.oauth).length > 0;
@@ -19,6 +19,7 @@ export interface FormModel {
   user: string;
   password: string;
   email: string;
+  mfaCode?: string;
 }
 
 export interface PasswordlessFormModel {
@@ -51,6 +52,8 @@ interface Props {
     passwordHint: string;
     showDefaultPasswordWarning: boolean;
     loginErrorMessage: string | undefined;
+    mfaRequired: boolean;
+    mfaEnrollment: MFAEnrollmentDTO | undefined;
   }) => JSX.Element;
 }
 
@@ -59,6 +62,8 @@ interface State {
   isChangingPassword: boolean;
   showDefaultPasswordWarning: boolean;
   loginErrorMessage?: string;
+  mfaRequired: boolean;
+  mfaEnrollment?: MFAEnrollmentDTO;
 }
 
 export class LoginCtrl extends PureComponent<Props, State> {
@@ -70,6 +75,7 @@ export class LoginCtrl extends PureComponent<Props, State> {
       isLoggingIn: false,
       isChangingPassword: false,
       showDefaultPasswordWarning: false,
+      mfaRequired: false,
       // oAuth unauthorized sets the redirect error message in the bootdata, hence we need to check the key here
       loginErrorMessage: getBootDataErrMessage(config.loginError),
     };
@@ -122,6 +128,15 @@ export class LoginCtrl extends PureComponent<Props, State> {
         }
       })
       .catch((err) => {
+        if (isFetchError(err) && isMFAError(err)) {
+          this.setState({
+            isLoggingIn: false,
+            mfaRequired: true,
+            mfaEnrollment: err.data?.extra ?? this.state.mfaEnrollment,
+            loginErrorMessage: err.data?.messageId === 'mfa.login-invalid-code' ? getErrorMessage(err) : undefined,
+          });
+          return;
+        }
         const fetchErrorMessage = isFetchError(err) ? getErrorMessage(err) : undefined;
         this.setState({
           isLoggingIn: false,
@@ -199,7 +214,8 @@ export class LoginCtrl extends PureComponent<Props, State> {
 
   render() {
     const { children } = this.props;
-    const { isLoggingIn, isChangingPassword, showDefaultPasswordWarning, loginErrorMessage } = this.state;
+    const { isLoggingIn, isChangingPassword, showDefaultPasswordWarning, loginErrorMessage, mfaRequired, mfaEnrollment } =
+      this.state;
     const { login, toGrafana, changePassword, passwordlessStart, passwordlessConfirm } = this;
     const { loginHint, passwordHint, disableLoginForm, disableUserSignUp } = config;
 
@@ -221,6 +237,8 @@ export class LoginCtrl extends PureComponent<Props, State> {
           isChangingPassword,
           showDefaultPasswordWarning,
           loginErrorMessage,
+          mfaRequired,
+          mfaEnrollment,
         })}
       </>
     );
@@ -229,7 +247,20 @@ export class LoginCtrl extends PureComponent<Props, State> {
 
 export default LoginCtrl;
 
-function getErrorMessage(err: FetchError<undefined | { messageId?: string; message?: string }>): string | undefined {
+type LoginErrorData = { messageId?: string; message?: string; extra?: MFAEnrollmentDTO };
+
+function isMFAError(err: FetchError<undefined | LoginErrorData>): boolean {
+  switch (err.data?.messageId) {
+    case 'mfa.code-required':
+    case 'mfa.enrollment-required':
+    case 'mfa.login-invalid-code':
+      return true;
+    default:
+      return false;
+  }
+}
+
+function getErrorMessage(err: FetchError<undefined | LoginErrorData>): string | undefined {
   switch (err.data?.messageId) {
     case 'password-auth.empty':
     case 'password-auth.failed':
@@ -240,6 +271,8 @@ function getErrorMessage(err: FetchError<undefined | { messageId?: string; messa
         'login.error.blocked',
         'You have exceeded the number of login attempts for this user. Please try again later.'
       );
+    case 'mfa.login-invalid-code':
+      return t('login.error.invalid-mfa-code', 'Invalid authentication code');
     default:
       return err.data?.message;
   }
@@ -4,12 +4,13 @@ import { useForm } from 'react-hook-form';
 
 import { GrafanaTheme2 } from '@grafana/data';
 import { selectors } from '@grafana/e2e-selectors';
-import { t } from '@grafana/i18n';
-import { Button, Input, Field, useStyles2 } from '@grafana/ui';
+import { Trans, t } from '@grafana/i18n';
+import { Alert, Button, Input, Field, TextLink, useStyles2 } from '@grafana/ui';
 
 import { PasswordField } from '../PasswordField/PasswordField';
 
 import { FormModel } from './LoginCtrl';
+import { MFAEnrollmentDTO } from './types';
 
 interface Props {
   children: ReactElement;
@@ -17,12 +18,23 @@ interface Props {
   isLoggingIn: boolean;
   passwordHint: string;
   loginHint: string;
+  mfaRequired?: boolean;
+  mfaEnrollment?: MFAEnrollmentDTO;
 }
 
-export const LoginForm = ({ children, onSubmit, isLoggingIn, passwordHint, loginHint }: Props) => {
+export const LoginForm = ({
+  children,
+  onSubmit,
+  isLoggingIn,
+  passwordHint,
+  loginHint,
+  mfaRequired,
+  mfaEnrollment,
+}: Props) => {
   const styles = useStyles2(getStyles);
   const usernameId = useId();
   const passwordId = useId();
+  const mfaCodeId = useId();
   const {
     handleSubmit,
     register,
@@ -58,6 +70,45 @@ export const LoginForm = ({ children, onSubmit, isLoggingIn, passwordHint, login
             placeholder={passwordHint || t('login.form.password-placeholder', 'password')}
           />
         </Field>
+        {mfaRequired && mfaEnrollment && (
+          <Alert severity="info" title={t('login.form.mfa-enrollment-title', 'Set up multi-factor authentication')}>
+            <p>
+              <Trans i18nKey="login.form.mfa-enrollment-secret">
+                Add this key to your authenticator app, or <TextLink href={mfaEnrollment.url}>open it</TextLink> on
+                this device, then enter the code it shows.
+              </Trans>
+            </p>
+            <pre className={styles.mfaCodes}>{mfaEnrollment.secret}</pre>
+            <p>
+              <Trans i18nKey="login.form.mfa-enrollment-recovery-codes">
+                Save these recovery codes. Each of them lets you log in once without your authenticator app.
+              </Trans>
+            </p>
+            <pre className={styles.mfaCodes}>{mfaEnrollment.recoveryCodes.join('\n')}</pre>
+          </Alert>
+        )}
+        {mfaRequired && (
+          <Field
+            label={t('login.form.mfa-code-label', 'Authentication code')}
+            description={
+              mfaEnrollment
+                ? undefined
+                : t('login.form.mfa-code-description', 'Enter the code of your authenticator app or a recovery code')
+            }
+            invalid={!!errors.mfaCode}
+            error={errors.mfaCode?.message}
+          >
+            <Input
+              {...register('mfaCode', {
+                required: t('login.form.mfa-code-required', 'Authentication code is required'),
+              })}
+              id={mfaCodeId}
+              autoFocus
+              autoComplete="one-time-code"
+              placeholder={t('login.form.mfa-code-placeholder', 'authentication code')}
+            />
+          </Field>
+        )}
         <Button
           type="submit"
           data-testid={selectors.pages.Login.submit}
@@ -87,5 +138,9 @@ export const getStyles = (theme: GrafanaTheme2) => {
     skipButton: css({
       alignSelf: 'flex-start',
     }),
+
+    mfaCodes: css({
+      userSelect: 'all',
+    }),
   };
 };
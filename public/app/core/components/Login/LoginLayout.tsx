@@ -59,7 +59,7 @@ export const LoginLayout = ({ children, branding, isChangingPassword }: React.Pr
           <div className={loginStyles.loginOuterBox}>{children}</div>
         </div>
       </div>
-      {branding?.hideFooter ? <></> : <Footer hideEdition={hideEdition} customLinks={branding?.footerLinks} />}
+      {branding?.hideFooter ? <></> : <Footer hideEdition={hideEdition} customLinks={branding?.footerLinks ?? Branding.FooterLinks} />}
     </Branding.LoginBackground>
   );
 };
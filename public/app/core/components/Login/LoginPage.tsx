@@ -42,6 +42,8 @@ const LoginPage = () => {
           isChangingPassword,
           showDefaultPasswordWarning,
           loginErrorMessage,
+          mfaRequired,
+          mfaEnrollment,
         }) => (
           <LoginLayout isChangingPassword={isChangingPassword}>
             {!isChangingPassword && !showPasswordlessConfirmation && (
@@ -58,6 +60,8 @@ const LoginPage = () => {
                     loginHint={loginHint}
                     passwordHint={passwordHint}
                     isLoggingIn={isLoggingIn}
+                    mfaRequired={mfaRequired}
+                    mfaEnrollment={mfaEnrollment}
                   >
                     <Stack justifyContent="flex-end">
                       {!config.auth.disableLogin && (
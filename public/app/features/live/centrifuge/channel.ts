@@ -96,6 +96,18 @@ export class CentrifugeLiveChannel<T = any> {
         this.currentStatus.state = LiveChannelConnectionState.Connected;
         delete this.currentStatus.error;
 
+        // recent publications replayed by the server when the channel keeps a history
+        if (Array.isArray(ctx.data?.history)) {
+          this.sendStatus();
+          for (const message of ctx.data.history) {
+            if (message?.schema) {
+              this.lastMessageWithSchema = message;
+            }
+            this.stream.next({ type: LiveChannelEventType.Message, message });
+          }
+          return;
+        }
+
         if (ctx.data?.schema) {
           this.lastMessageWithSchema = ctx.data;
         }
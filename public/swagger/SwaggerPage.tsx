@@ -17,7 +17,7 @@ export const Page = () => {
   const [url, setURL] = useState<SelectableValue<string>>();
   const urls = useAsync(async () => {
     const v2 = { label: 'Grafana API (OpenAPI v2)', key: 'openapi2', value: 'public/api-merged.json' };
-    const v3 = { label: 'Grafana API (OpenAPI v3)', key: 'openapi3', value: 'public/openapi3.json' };
+    const v3 = { label: 'Grafana API (OpenAPI v3)', key: 'openapi3', value: 'api/openapi.json' };
     const urls: Array<SelectableValue<string>> = [v2, v3];
 
     const rsp = await fetch('openapi/v3');